@@ -0,0 +1,1708 @@
+// 节点通信 gRPC 接口
+//
+// 与 REST 接口（POST /api/v1/nodes/heartbeat、GET /api/v1/nodes/{id}/runs、
+// POST /api/v1/runs/{id}/events、PATCH /api/v1/runs/{id}）语义一致，
+// 在独立端口提供（config api_server.grpc_listen），REST 接口保持不变。
+//
+// 认证与 REST 相同：metadata x-node-token（共享密钥或节点专属 Token）或节点客户端证书（mTLS）；
+// Heartbeat 与 REST 心跳一样无需认证。
+//
+// 修改后执行 make generate-proto 重新生成 api/generated/proto/nodev1。
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: agentsadmin/node/v1/node.proto
+
+package nodev1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Capacity 节点容量
+type Capacity struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	MaxConcurrent        int32                  `protobuf:"varint,1,opt,name=max_concurrent,json=maxConcurrent,proto3" json:"max_concurrent,omitempty"`                                                                                   // 最大并发 Run 数
+	Available            int32                  `protobuf:"varint,2,opt,name=available,proto3" json:"available,omitempty"`                                                                                                                // 当前可用执行槽位
+	Cpus                 int32                  `protobuf:"varint,3,opt,name=cpus,proto3" json:"cpus,omitempty"`                                                                                                                          // CPU 核数
+	MemoryBytes          int64                  `protobuf:"varint,4,opt,name=memory_bytes,json=memoryBytes,proto3" json:"memory_bytes,omitempty"`                                                                                         // 内存字节数
+	Adapters             []*AdapterInfo         `protobuf:"bytes,5,rep,name=adapters,proto3" json:"adapters,omitempty"`                                                                                                                   // 已注册适配器的 CLI 版本与能力（调度器按能力过滤节点）
+	Pools                []*InstancePool        `protobuf:"bytes,6,rep,name=pools,proto3" json:"pools,omitempty"`                                                                                                                         // 预热实例池状态（未配置时为空）
+	Metrics              *NodeMetrics           `protobuf:"bytes,7,opt,name=metrics,proto3" json:"metrics,omitempty"`                                                                                                                     // 系统资源指标（least_loaded 调度策略使用）
+	ReservedHighPriority int32                  `protobuf:"varint,8,opt,name=reserved_high_priority,json=reservedHighPriority,proto3" json:"reserved_high_priority,omitempty"`                                                            // 只允许 high 优先级 Run 使用的槽位数（包含在 max_concurrent 内）
+	AgentTypeLimits      map[string]int32       `protobuf:"bytes,9,rep,name=agent_type_limits,json=agentTypeLimits,proto3" json:"agent_type_limits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // Agent 类型 → 并发上限
+	Spool                *SpoolStatus           `protobuf:"bytes,10,opt,name=spool,proto3" json:"spool,omitempty"`                                                                                                                        // 本地缓存深度（API Server 不可达期间缓存的事件、状态与产物）
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *Capacity) Reset() {
+	*x = Capacity{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Capacity) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Capacity) ProtoMessage() {}
+
+func (x *Capacity) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Capacity.ProtoReflect.Descriptor instead.
+func (*Capacity) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Capacity) GetMaxConcurrent() int32 {
+	if x != nil {
+		return x.MaxConcurrent
+	}
+	return 0
+}
+
+func (x *Capacity) GetAvailable() int32 {
+	if x != nil {
+		return x.Available
+	}
+	return 0
+}
+
+func (x *Capacity) GetCpus() int32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *Capacity) GetMemoryBytes() int64 {
+	if x != nil {
+		return x.MemoryBytes
+	}
+	return 0
+}
+
+func (x *Capacity) GetAdapters() []*AdapterInfo {
+	if x != nil {
+		return x.Adapters
+	}
+	return nil
+}
+
+func (x *Capacity) GetPools() []*InstancePool {
+	if x != nil {
+		return x.Pools
+	}
+	return nil
+}
+
+func (x *Capacity) GetMetrics() *NodeMetrics {
+	if x != nil {
+		return x.Metrics
+	}
+	return nil
+}
+
+func (x *Capacity) GetReservedHighPriority() int32 {
+	if x != nil {
+		return x.ReservedHighPriority
+	}
+	return 0
+}
+
+func (x *Capacity) GetAgentTypeLimits() map[string]int32 {
+	if x != nil {
+		return x.AgentTypeLimits
+	}
+	return nil
+}
+
+func (x *Capacity) GetSpool() *SpoolStatus {
+	if x != nil {
+		return x.Spool
+	}
+	return nil
+}
+
+// SpoolStatus 节点本地缓存深度
+type SpoolStatus struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Runs          int32                  `protobuf:"varint,1,opt,name=runs,proto3" json:"runs,omitempty"`         // 有待回放记录的 Run 数
+	Records       int32                  `protobuf:"varint,2,opt,name=records,proto3" json:"records,omitempty"`   // 待回放的记录数
+	Segments      int32                  `protobuf:"varint,3,opt,name=segments,proto3" json:"segments,omitempty"` // 分段文件数
+	Bytes         int64                  `protobuf:"varint,4,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	MaxBytes      int64                  `protobuf:"varint,5,opt,name=max_bytes,json=maxBytes,proto3" json:"max_bytes,omitempty"` // 总大小上限（0 表示不限制）
+	Dropped       int64                  `protobuf:"varint,6,opt,name=dropped,proto3" json:"dropped,omitempty"`                   // 因超过上限丢弃的事件数（节点启动以来累计）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SpoolStatus) Reset() {
+	*x = SpoolStatus{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SpoolStatus) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SpoolStatus) ProtoMessage() {}
+
+func (x *SpoolStatus) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SpoolStatus.ProtoReflect.Descriptor instead.
+func (*SpoolStatus) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *SpoolStatus) GetRuns() int32 {
+	if x != nil {
+		return x.Runs
+	}
+	return 0
+}
+
+func (x *SpoolStatus) GetRecords() int32 {
+	if x != nil {
+		return x.Records
+	}
+	return 0
+}
+
+func (x *SpoolStatus) GetSegments() int32 {
+	if x != nil {
+		return x.Segments
+	}
+	return 0
+}
+
+func (x *SpoolStatus) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *SpoolStatus) GetMaxBytes() int64 {
+	if x != nil {
+		return x.MaxBytes
+	}
+	return 0
+}
+
+func (x *SpoolStatus) GetDropped() int64 {
+	if x != nil {
+		return x.Dropped
+	}
+	return 0
+}
+
+// NodeMetrics 节点系统资源指标
+type NodeMetrics struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	CpuPercent       float64                `protobuf:"fixed64,1,opt,name=cpu_percent,json=cpuPercent,proto3" json:"cpu_percent,omitempty"` // 两次采集间的 CPU 使用率（0-100）
+	MemoryUsedBytes  int64                  `protobuf:"varint,2,opt,name=memory_used_bytes,json=memoryUsedBytes,proto3" json:"memory_used_bytes,omitempty"`
+	MemoryTotalBytes int64                  `protobuf:"varint,3,opt,name=memory_total_bytes,json=memoryTotalBytes,proto3" json:"memory_total_bytes,omitempty"`
+	MemoryPercent    float64                `protobuf:"fixed64,4,opt,name=memory_percent,json=memoryPercent,proto3" json:"memory_percent,omitempty"`
+	DiskUsedBytes    int64                  `protobuf:"varint,5,opt,name=disk_used_bytes,json=diskUsedBytes,proto3" json:"disk_used_bytes,omitempty"` // 工作空间所在文件系统
+	DiskTotalBytes   int64                  `protobuf:"varint,6,opt,name=disk_total_bytes,json=diskTotalBytes,proto3" json:"disk_total_bytes,omitempty"`
+	DiskPercent      float64                `protobuf:"fixed64,7,opt,name=disk_percent,json=diskPercent,proto3" json:"disk_percent,omitempty"`
+	Load1            float64                `protobuf:"fixed64,8,opt,name=load1,proto3" json:"load1,omitempty"`
+	Load5            float64                `protobuf:"fixed64,9,opt,name=load5,proto3" json:"load5,omitempty"`
+	Load15           float64                `protobuf:"fixed64,10,opt,name=load15,proto3" json:"load15,omitempty"`
+	Cpus             int32                  `protobuf:"varint,11,opt,name=cpus,proto3" json:"cpus,omitempty"`
+	CollectedAtMs    int64                  `protobuf:"varint,12,opt,name=collected_at_ms,json=collectedAtMs,proto3" json:"collected_at_ms,omitempty"` // 采集时间（Unix 毫秒）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *NodeMetrics) Reset() {
+	*x = NodeMetrics{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NodeMetrics) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NodeMetrics) ProtoMessage() {}
+
+func (x *NodeMetrics) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NodeMetrics.ProtoReflect.Descriptor instead.
+func (*NodeMetrics) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *NodeMetrics) GetCpuPercent() float64 {
+	if x != nil {
+		return x.CpuPercent
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetMemoryUsedBytes() int64 {
+	if x != nil {
+		return x.MemoryUsedBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetMemoryTotalBytes() int64 {
+	if x != nil {
+		return x.MemoryTotalBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetMemoryPercent() float64 {
+	if x != nil {
+		return x.MemoryPercent
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetDiskUsedBytes() int64 {
+	if x != nil {
+		return x.DiskUsedBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetDiskTotalBytes() int64 {
+	if x != nil {
+		return x.DiskTotalBytes
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetDiskPercent() float64 {
+	if x != nil {
+		return x.DiskPercent
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetLoad1() float64 {
+	if x != nil {
+		return x.Load1
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetLoad5() float64 {
+	if x != nil {
+		return x.Load5
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetLoad15() float64 {
+	if x != nil {
+		return x.Load15
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetCpus() int32 {
+	if x != nil {
+		return x.Cpus
+	}
+	return 0
+}
+
+func (x *NodeMetrics) GetCollectedAtMs() int64 {
+	if x != nil {
+		return x.CollectedAtMs
+	}
+	return 0
+}
+
+// AdapterInfo 适配器探测结果
+type AdapterInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`         // 适配器名称（如 claude-v1）
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`   // CLI 版本，探测失败时为空
+	Features      []string               `protobuf:"bytes,3,rep,name=features,proto3" json:"features,omitempty"` // 支持的能力：streaming / tool_events / usage / feedback
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AdapterInfo) Reset() {
+	*x = AdapterInfo{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AdapterInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AdapterInfo) ProtoMessage() {}
+
+func (x *AdapterInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AdapterInfo.ProtoReflect.Descriptor instead.
+func (*AdapterInfo) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AdapterInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *AdapterInfo) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *AdapterInfo) GetFeatures() []string {
+	if x != nil {
+		return x.Features
+	}
+	return nil
+}
+
+// InstancePool 单个账号的预热实例池状态（leases 等计数为节点启动以来的累计值）
+type InstancePool struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AccountId     string                 `protobuf:"bytes,1,opt,name=account_id,json=accountId,proto3" json:"account_id,omitempty"`
+	AgentType     string                 `protobuf:"bytes,2,opt,name=agent_type,json=agentType,proto3" json:"agent_type,omitempty"`
+	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // 目标空闲容器数
+	Idle          int32                  `protobuf:"varint,4,opt,name=idle,proto3" json:"idle,omitempty"`
+	Leased        int32                  `protobuf:"varint,5,opt,name=leased,proto3" json:"leased,omitempty"`
+	Warming       int32                  `protobuf:"varint,6,opt,name=warming,proto3" json:"warming,omitempty"`
+	Leases        int64                  `protobuf:"varint,7,opt,name=leases,proto3" json:"leases,omitempty"`
+	Misses        int64                  `protobuf:"varint,8,opt,name=misses,proto3" json:"misses,omitempty"` // 没有空闲容器、回退到实例容器的次数
+	Recycled      int64                  `protobuf:"varint,9,opt,name=recycled,proto3" json:"recycled,omitempty"`
+	Discarded     int64                  `protobuf:"varint,10,opt,name=discarded,proto3" json:"discarded,omitempty"`
+	AvgWarmMs     int64                  `protobuf:"varint,11,opt,name=avg_warm_ms,json=avgWarmMs,proto3" json:"avg_warm_ms,omitempty"`
+	LastError     string                 `protobuf:"bytes,12,opt,name=last_error,json=lastError,proto3" json:"last_error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InstancePool) Reset() {
+	*x = InstancePool{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InstancePool) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InstancePool) ProtoMessage() {}
+
+func (x *InstancePool) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InstancePool.ProtoReflect.Descriptor instead.
+func (*InstancePool) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *InstancePool) GetAccountId() string {
+	if x != nil {
+		return x.AccountId
+	}
+	return ""
+}
+
+func (x *InstancePool) GetAgentType() string {
+	if x != nil {
+		return x.AgentType
+	}
+	return ""
+}
+
+func (x *InstancePool) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *InstancePool) GetIdle() int32 {
+	if x != nil {
+		return x.Idle
+	}
+	return 0
+}
+
+func (x *InstancePool) GetLeased() int32 {
+	if x != nil {
+		return x.Leased
+	}
+	return 0
+}
+
+func (x *InstancePool) GetWarming() int32 {
+	if x != nil {
+		return x.Warming
+	}
+	return 0
+}
+
+func (x *InstancePool) GetLeases() int64 {
+	if x != nil {
+		return x.Leases
+	}
+	return 0
+}
+
+func (x *InstancePool) GetMisses() int64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *InstancePool) GetRecycled() int64 {
+	if x != nil {
+		return x.Recycled
+	}
+	return 0
+}
+
+func (x *InstancePool) GetDiscarded() int64 {
+	if x != nil {
+		return x.Discarded
+	}
+	return 0
+}
+
+func (x *InstancePool) GetAvgWarmMs() int64 {
+	if x != nil {
+		return x.AvgWarmMs
+	}
+	return 0
+}
+
+func (x *InstancePool) GetLastError() string {
+	if x != nil {
+		return x.LastError
+	}
+	return ""
+}
+
+type HeartbeatRequest struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	NodeId           string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	Status           string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // 为空时为 online
+	Hostname         string                 `protobuf:"bytes,3,opt,name=hostname,proto3" json:"hostname,omitempty"`
+	Ips              string                 `protobuf:"bytes,4,opt,name=ips,proto3" json:"ips,omitempty"` // IP 地址列表（逗号分隔）
+	Labels           map[string]string      `protobuf:"bytes,5,rep,name=labels,proto3" json:"labels,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Capacity         *Capacity              `protobuf:"bytes,6,opt,name=capacity,proto3" json:"capacity,omitempty"`
+	RunningRuns      []string               `protobuf:"bytes,7,rep,name=running_runs,json=runningRuns,proto3" json:"running_runs,omitempty"`                // 正在执行的 Run ID（用于声明式状态协调）
+	PausedRuns       []string               `protobuf:"bytes,8,rep,name=paused_runs,json=pausedRuns,proto3" json:"paused_runs,omitempty"`                   // 其中已暂停的 Run ID
+	PendingApprovals []string               `protobuf:"bytes,9,rep,name=pending_approvals,json=pendingApprovals,proto3" json:"pending_approvals,omitempty"` // 暂停执行等待中的审批 ID
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *HeartbeatRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetHostname() string {
+	if x != nil {
+		return x.Hostname
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetIps() string {
+	if x != nil {
+		return x.Ips
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetLabels() map[string]string {
+	if x != nil {
+		return x.Labels
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetCapacity() *Capacity {
+	if x != nil {
+		return x.Capacity
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetRunningRuns() []string {
+	if x != nil {
+		return x.RunningRuns
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetPausedRuns() []string {
+	if x != nil {
+		return x.PausedRuns
+	}
+	return nil
+}
+
+func (x *HeartbeatRequest) GetPendingApprovals() []string {
+	if x != nil {
+		return x.PendingApprovals
+	}
+	return nil
+}
+
+type HeartbeatResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Status           string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Directives       *Directives            `protobuf:"bytes,2,opt,name=directives,proto3" json:"directives,omitempty"`                                     // 无指令时为空
+	CapacityOverride *CapacityOverride      `protobuf:"bytes,3,opt,name=capacity_override,json=capacityOverride,proto3" json:"capacity_override,omitempty"` // 控制面的并发配置覆盖（未设置覆盖时为空消息，旧版本 API Server 不返回）
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *HeartbeatResponse) Reset() {
+	*x = HeartbeatResponse{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatResponse) ProtoMessage() {}
+
+func (x *HeartbeatResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatResponse.ProtoReflect.Descriptor instead.
+func (*HeartbeatResponse) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *HeartbeatResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *HeartbeatResponse) GetDirectives() *Directives {
+	if x != nil {
+		return x.Directives
+	}
+	return nil
+}
+
+func (x *HeartbeatResponse) GetCapacityOverride() *CapacityOverride {
+	if x != nil {
+		return x.CapacityOverride
+	}
+	return nil
+}
+
+// CapacityOverride 控制面对节点并发配置的覆盖，未设置的字段沿用节点本地配置
+type CapacityOverride struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	MaxConcurrent        *int32                 `protobuf:"varint,1,opt,name=max_concurrent,json=maxConcurrent,proto3,oneof" json:"max_concurrent,omitempty"`
+	ReservedHighPriority *int32                 `protobuf:"varint,2,opt,name=reserved_high_priority,json=reservedHighPriority,proto3,oneof" json:"reserved_high_priority,omitempty"`
+	AgentTypeLimits      map[string]int32       `protobuf:"bytes,3,rep,name=agent_type_limits,json=agentTypeLimits,proto3" json:"agent_type_limits,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	unknownFields        protoimpl.UnknownFields
+	sizeCache            protoimpl.SizeCache
+}
+
+func (x *CapacityOverride) Reset() {
+	*x = CapacityOverride{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CapacityOverride) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CapacityOverride) ProtoMessage() {}
+
+func (x *CapacityOverride) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CapacityOverride.ProtoReflect.Descriptor instead.
+func (*CapacityOverride) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CapacityOverride) GetMaxConcurrent() int32 {
+	if x != nil && x.MaxConcurrent != nil {
+		return *x.MaxConcurrent
+	}
+	return 0
+}
+
+func (x *CapacityOverride) GetReservedHighPriority() int32 {
+	if x != nil && x.ReservedHighPriority != nil {
+		return *x.ReservedHighPriority
+	}
+	return 0
+}
+
+func (x *CapacityOverride) GetAgentTypeLimits() map[string]int32 {
+	if x != nil {
+		return x.AgentTypeLimits
+	}
+	return nil
+}
+
+// Directives 心跳控制指令
+type Directives struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CancelRuns    []string               `protobuf:"bytes,1,rep,name=cancel_runs,json=cancelRuns,proto3" json:"cancel_runs,omitempty"`
+	TimeoutRuns   []string               `protobuf:"bytes,2,rep,name=timeout_runs,json=timeoutRuns,proto3" json:"timeout_runs,omitempty"`
+	PauseRuns     []string               `protobuf:"bytes,3,rep,name=pause_runs,json=pauseRuns,proto3" json:"pause_runs,omitempty"`
+	ResumeRuns    []string               `protobuf:"bytes,4,rep,name=resume_runs,json=resumeRuns,proto3" json:"resume_runs,omitempty"`
+	Approvals     []*ApprovalOutcome     `protobuf:"bytes,5,rep,name=approvals,proto3" json:"approvals,omitempty"`
+	Feedbacks     []*Feedback            `protobuf:"bytes,6,rep,name=feedbacks,proto3" json:"feedbacks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Directives) Reset() {
+	*x = Directives{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Directives) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Directives) ProtoMessage() {}
+
+func (x *Directives) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Directives.ProtoReflect.Descriptor instead.
+func (*Directives) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Directives) GetCancelRuns() []string {
+	if x != nil {
+		return x.CancelRuns
+	}
+	return nil
+}
+
+func (x *Directives) GetTimeoutRuns() []string {
+	if x != nil {
+		return x.TimeoutRuns
+	}
+	return nil
+}
+
+func (x *Directives) GetPauseRuns() []string {
+	if x != nil {
+		return x.PauseRuns
+	}
+	return nil
+}
+
+func (x *Directives) GetResumeRuns() []string {
+	if x != nil {
+		return x.ResumeRuns
+	}
+	return nil
+}
+
+func (x *Directives) GetApprovals() []*ApprovalOutcome {
+	if x != nil {
+		return x.Approvals
+	}
+	return nil
+}
+
+func (x *Directives) GetFeedbacks() []*Feedback {
+	if x != nil {
+		return x.Feedbacks
+	}
+	return nil
+}
+
+type ApprovalOutcome struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ApprovalId    string                 `protobuf:"bytes,1,opt,name=approval_id,json=approvalId,proto3" json:"approval_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"` // approved / rejected / expired
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ApprovalOutcome) Reset() {
+	*x = ApprovalOutcome{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ApprovalOutcome) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApprovalOutcome) ProtoMessage() {}
+
+func (x *ApprovalOutcome) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApprovalOutcome.ProtoReflect.Descriptor instead.
+func (*ApprovalOutcome) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ApprovalOutcome) GetApprovalId() string {
+	if x != nil {
+		return x.ApprovalId
+	}
+	return ""
+}
+
+func (x *ApprovalOutcome) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type Feedback struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	RunId         string                 `protobuf:"bytes,2,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Type          string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Content       string                 `protobuf:"bytes,4,opt,name=content,proto3" json:"content,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Feedback) Reset() {
+	*x = Feedback{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Feedback) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Feedback) ProtoMessage() {}
+
+func (x *Feedback) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Feedback.ProtoReflect.Descriptor instead.
+func (*Feedback) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *Feedback) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Feedback) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *Feedback) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Feedback) GetContent() string {
+	if x != nil {
+		return x.Content
+	}
+	return ""
+}
+
+type WatchAssignedRunsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	NodeId        string                 `protobuf:"bytes,1,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchAssignedRunsRequest) Reset() {
+	*x = WatchAssignedRunsRequest{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchAssignedRunsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchAssignedRunsRequest) ProtoMessage() {}
+
+func (x *WatchAssignedRunsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchAssignedRunsRequest.ProtoReflect.Descriptor instead.
+func (*WatchAssignedRunsRequest) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchAssignedRunsRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+// AssignedRun 分配给节点的 Run
+type AssignedRun struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	EventSeq      int32                  `protobuf:"varint,3,opt,name=event_seq,json=eventSeq,proto3" json:"event_seq,omitempty"` // 已有事件的最大序号，Node Manager 从其后继续编号
+	Run           []byte                 `protobuf:"bytes,4,opt,name=run,proto3" json:"run,omitempty"`                            // Run JSON（与 GET /api/v1/nodes/{id}/runs 中的元素相同，含任务快照）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AssignedRun) Reset() {
+	*x = AssignedRun{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AssignedRun) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AssignedRun) ProtoMessage() {}
+
+func (x *AssignedRun) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AssignedRun.ProtoReflect.Descriptor instead.
+func (*AssignedRun) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AssignedRun) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *AssignedRun) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *AssignedRun) GetEventSeq() int32 {
+	if x != nil {
+		return x.EventSeq
+	}
+	return 0
+}
+
+func (x *AssignedRun) GetRun() []byte {
+	if x != nil {
+		return x.Run
+	}
+	return nil
+}
+
+type ReportEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Events        []*Event               `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportEventsRequest) Reset() {
+	*x = ReportEventsRequest{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportEventsRequest) ProtoMessage() {}
+
+func (x *ReportEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportEventsRequest.ProtoReflect.Descriptor instead.
+func (*ReportEventsRequest) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ReportEventsRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *ReportEventsRequest) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           int32                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Timestamp     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Payload       []byte                 `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"` // 事件数据（JSON 对象）
+	Raw           *string                `protobuf:"bytes,5,opt,name=raw,proto3,oneof" json:"raw,omitempty"`   // 原始 CLI 输出
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *Event) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetRaw() string {
+	if x != nil && x.Raw != nil {
+		return *x.Raw
+	}
+	return ""
+}
+
+type ReportEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Created       int32                  `protobuf:"varint,1,opt,name=created,proto3" json:"created,omitempty"`              // 本次入库的事件数
+	Stored        []int32                `protobuf:"varint,2,rep,packed,name=stored,proto3" json:"stored,omitempty"`         // 本次入库的事件 seq
+	Duplicates    []int32                `protobuf:"varint,3,rep,packed,name=duplicates,proto3" json:"duplicates,omitempty"` // seq 已入库而被忽略的事件
+	Rejected      []*RejectedEvent       `protobuf:"bytes,4,rep,name=rejected,proto3" json:"rejected,omitempty"`             // 校验失败未入库的事件
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ReportEventsResponse) Reset() {
+	*x = ReportEventsResponse{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ReportEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ReportEventsResponse) ProtoMessage() {}
+
+func (x *ReportEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ReportEventsResponse.ProtoReflect.Descriptor instead.
+func (*ReportEventsResponse) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ReportEventsResponse) GetCreated() int32 {
+	if x != nil {
+		return x.Created
+	}
+	return 0
+}
+
+func (x *ReportEventsResponse) GetStored() []int32 {
+	if x != nil {
+		return x.Stored
+	}
+	return nil
+}
+
+func (x *ReportEventsResponse) GetDuplicates() []int32 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return nil
+}
+
+func (x *ReportEventsResponse) GetRejected() []*RejectedEvent {
+	if x != nil {
+		return x.Rejected
+	}
+	return nil
+}
+
+type RejectedEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Seq           int32                  `protobuf:"varint,1,opt,name=seq,proto3" json:"seq,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RejectedEvent) Reset() {
+	*x = RejectedEvent{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RejectedEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RejectedEvent) ProtoMessage() {}
+
+func (x *RejectedEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RejectedEvent.ProtoReflect.Descriptor instead.
+func (*RejectedEvent) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RejectedEvent) GetSeq() int32 {
+	if x != nil {
+		return x.Seq
+	}
+	return 0
+}
+
+func (x *RejectedEvent) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type UpdateRunStatusRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	RunId         string                 `protobuf:"bytes,1,opt,name=run_id,json=runId,proto3" json:"run_id,omitempty"`
+	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
+	NodeId        string                 `protobuf:"bytes,3,opt,name=node_id,json=nodeId,proto3" json:"node_id,omitempty"` // Run 已被回收并分配给其他节点时返回 FAILED_PRECONDITION
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRunStatusRequest) Reset() {
+	*x = UpdateRunStatusRequest{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRunStatusRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRunStatusRequest) ProtoMessage() {}
+
+func (x *UpdateRunStatusRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRunStatusRequest.ProtoReflect.Descriptor instead.
+func (*UpdateRunStatusRequest) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *UpdateRunStatusRequest) GetRunId() string {
+	if x != nil {
+		return x.RunId
+	}
+	return ""
+}
+
+func (x *UpdateRunStatusRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *UpdateRunStatusRequest) GetNodeId() string {
+	if x != nil {
+		return x.NodeId
+	}
+	return ""
+}
+
+type UpdateRunStatusResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // Run 的当前状态（已到达终态时为原终态）
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateRunStatusResponse) Reset() {
+	*x = UpdateRunStatusResponse{}
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateRunStatusResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateRunStatusResponse) ProtoMessage() {}
+
+func (x *UpdateRunStatusResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_agentsadmin_node_v1_node_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateRunStatusResponse.ProtoReflect.Descriptor instead.
+func (*UpdateRunStatusResponse) Descriptor() ([]byte, []int) {
+	return file_agentsadmin_node_v1_node_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *UpdateRunStatusResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+var File_agentsadmin_node_v1_node_proto protoreflect.FileDescriptor
+
+const file_agentsadmin_node_v1_node_proto_rawDesc = "" +
+	"\n" +
+	"\x1eagentsadmin/node/v1/node.proto\x12\x13agentsadmin.node.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xcb\x04\n" +
+	"\bCapacity\x12%\n" +
+	"\x0emax_concurrent\x18\x01 \x01(\x05R\rmaxConcurrent\x12\x1c\n" +
+	"\tavailable\x18\x02 \x01(\x05R\tavailable\x12\x12\n" +
+	"\x04cpus\x18\x03 \x01(\x05R\x04cpus\x12!\n" +
+	"\fmemory_bytes\x18\x04 \x01(\x03R\vmemoryBytes\x12<\n" +
+	"\badapters\x18\x05 \x03(\v2 .agentsadmin.node.v1.AdapterInfoR\badapters\x127\n" +
+	"\x05pools\x18\x06 \x03(\v2!.agentsadmin.node.v1.InstancePoolR\x05pools\x12:\n" +
+	"\ametrics\x18\a \x01(\v2 .agentsadmin.node.v1.NodeMetricsR\ametrics\x124\n" +
+	"\x16reserved_high_priority\x18\b \x01(\x05R\x14reservedHighPriority\x12^\n" +
+	"\x11agent_type_limits\x18\t \x03(\v22.agentsadmin.node.v1.Capacity.AgentTypeLimitsEntryR\x0fagentTypeLimits\x126\n" +
+	"\x05spool\x18\n" +
+	" \x01(\v2 .agentsadmin.node.v1.SpoolStatusR\x05spool\x1aB\n" +
+	"\x14AgentTypeLimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"\xa4\x01\n" +
+	"\vSpoolStatus\x12\x12\n" +
+	"\x04runs\x18\x01 \x01(\x05R\x04runs\x12\x18\n" +
+	"\arecords\x18\x02 \x01(\x05R\arecords\x12\x1a\n" +
+	"\bsegments\x18\x03 \x01(\x05R\bsegments\x12\x14\n" +
+	"\x05bytes\x18\x04 \x01(\x03R\x05bytes\x12\x1b\n" +
+	"\tmax_bytes\x18\x05 \x01(\x03R\bmaxBytes\x12\x18\n" +
+	"\adropped\x18\x06 \x01(\x03R\adropped\"\xa4\x03\n" +
+	"\vNodeMetrics\x12\x1f\n" +
+	"\vcpu_percent\x18\x01 \x01(\x01R\n" +
+	"cpuPercent\x12*\n" +
+	"\x11memory_used_bytes\x18\x02 \x01(\x03R\x0fmemoryUsedBytes\x12,\n" +
+	"\x12memory_total_bytes\x18\x03 \x01(\x03R\x10memoryTotalBytes\x12%\n" +
+	"\x0ememory_percent\x18\x04 \x01(\x01R\rmemoryPercent\x12&\n" +
+	"\x0fdisk_used_bytes\x18\x05 \x01(\x03R\rdiskUsedBytes\x12(\n" +
+	"\x10disk_total_bytes\x18\x06 \x01(\x03R\x0ediskTotalBytes\x12!\n" +
+	"\fdisk_percent\x18\a \x01(\x01R\vdiskPercent\x12\x14\n" +
+	"\x05load1\x18\b \x01(\x01R\x05load1\x12\x14\n" +
+	"\x05load5\x18\t \x01(\x01R\x05load5\x12\x16\n" +
+	"\x06load15\x18\n" +
+	" \x01(\x01R\x06load15\x12\x12\n" +
+	"\x04cpus\x18\v \x01(\x05R\x04cpus\x12&\n" +
+	"\x0fcollected_at_ms\x18\f \x01(\x03R\rcollectedAtMs\"W\n" +
+	"\vAdapterInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x12\x1a\n" +
+	"\bfeatures\x18\x03 \x03(\tR\bfeatures\"\xcf\x02\n" +
+	"\fInstancePool\x12\x1d\n" +
+	"\n" +
+	"account_id\x18\x01 \x01(\tR\taccountId\x12\x1d\n" +
+	"\n" +
+	"agent_type\x18\x02 \x01(\tR\tagentType\x12\x12\n" +
+	"\x04size\x18\x03 \x01(\x05R\x04size\x12\x12\n" +
+	"\x04idle\x18\x04 \x01(\x05R\x04idle\x12\x16\n" +
+	"\x06leased\x18\x05 \x01(\x05R\x06leased\x12\x18\n" +
+	"\awarming\x18\x06 \x01(\x05R\awarming\x12\x16\n" +
+	"\x06leases\x18\a \x01(\x03R\x06leases\x12\x16\n" +
+	"\x06misses\x18\b \x01(\x03R\x06misses\x12\x1a\n" +
+	"\brecycled\x18\t \x01(\x03R\brecycled\x12\x1c\n" +
+	"\tdiscarded\x18\n" +
+	" \x01(\x03R\tdiscarded\x12\x1e\n" +
+	"\vavg_warm_ms\x18\v \x01(\x03R\tavgWarmMs\x12\x1d\n" +
+	"\n" +
+	"last_error\x18\f \x01(\tR\tlastError\"\xa3\x03\n" +
+	"\x10HeartbeatRequest\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1a\n" +
+	"\bhostname\x18\x03 \x01(\tR\bhostname\x12\x10\n" +
+	"\x03ips\x18\x04 \x01(\tR\x03ips\x12I\n" +
+	"\x06labels\x18\x05 \x03(\v21.agentsadmin.node.v1.HeartbeatRequest.LabelsEntryR\x06labels\x129\n" +
+	"\bcapacity\x18\x06 \x01(\v2\x1d.agentsadmin.node.v1.CapacityR\bcapacity\x12!\n" +
+	"\frunning_runs\x18\a \x03(\tR\vrunningRuns\x12\x1f\n" +
+	"\vpaused_runs\x18\b \x03(\tR\n" +
+	"pausedRuns\x12+\n" +
+	"\x11pending_approvals\x18\t \x03(\tR\x10pendingApprovals\x1a9\n" +
+	"\vLabelsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xc0\x01\n" +
+	"\x11HeartbeatResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12?\n" +
+	"\n" +
+	"directives\x18\x02 \x01(\v2\x1f.agentsadmin.node.v1.DirectivesR\n" +
+	"directives\x12R\n" +
+	"\x11capacity_override\x18\x03 \x01(\v2%.agentsadmin.node.v1.CapacityOverrideR\x10capacityOverride\"\xd3\x02\n" +
+	"\x10CapacityOverride\x12*\n" +
+	"\x0emax_concurrent\x18\x01 \x01(\x05H\x00R\rmaxConcurrent\x88\x01\x01\x129\n" +
+	"\x16reserved_high_priority\x18\x02 \x01(\x05H\x01R\x14reservedHighPriority\x88\x01\x01\x12f\n" +
+	"\x11agent_type_limits\x18\x03 \x03(\v2:.agentsadmin.node.v1.CapacityOverride.AgentTypeLimitsEntryR\x0fagentTypeLimits\x1aB\n" +
+	"\x14AgentTypeLimitsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01B\x11\n" +
+	"\x0f_max_concurrentB\x19\n" +
+	"\x17_reserved_high_priority\"\x91\x02\n" +
+	"\n" +
+	"Directives\x12\x1f\n" +
+	"\vcancel_runs\x18\x01 \x03(\tR\n" +
+	"cancelRuns\x12!\n" +
+	"\ftimeout_runs\x18\x02 \x03(\tR\vtimeoutRuns\x12\x1d\n" +
+	"\n" +
+	"pause_runs\x18\x03 \x03(\tR\tpauseRuns\x12\x1f\n" +
+	"\vresume_runs\x18\x04 \x03(\tR\n" +
+	"resumeRuns\x12B\n" +
+	"\tapprovals\x18\x05 \x03(\v2$.agentsadmin.node.v1.ApprovalOutcomeR\tapprovals\x12;\n" +
+	"\tfeedbacks\x18\x06 \x03(\v2\x1d.agentsadmin.node.v1.FeedbackR\tfeedbacks\"J\n" +
+	"\x0fApprovalOutcome\x12\x1f\n" +
+	"\vapproval_id\x18\x01 \x01(\tR\n" +
+	"approvalId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\"_\n" +
+	"\bFeedback\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x15\n" +
+	"\x06run_id\x18\x02 \x01(\tR\x05runId\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x18\n" +
+	"\acontent\x18\x04 \x01(\tR\acontent\"3\n" +
+	"\x18WatchAssignedRunsRequest\x12\x17\n" +
+	"\anode_id\x18\x01 \x01(\tR\x06nodeId\"k\n" +
+	"\vAssignedRun\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x1b\n" +
+	"\tevent_seq\x18\x03 \x01(\x05R\beventSeq\x12\x10\n" +
+	"\x03run\x18\x04 \x01(\fR\x03run\"`\n" +
+	"\x13ReportEventsRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x122\n" +
+	"\x06events\x18\x02 \x03(\v2\x1a.agentsadmin.node.v1.EventR\x06events\"\xa0\x01\n" +
+	"\x05Event\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x05R\x03seq\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x128\n" +
+	"\ttimestamp\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
+	"\apayload\x18\x04 \x01(\fR\apayload\x12\x15\n" +
+	"\x03raw\x18\x05 \x01(\tH\x00R\x03raw\x88\x01\x01B\x06\n" +
+	"\x04_raw\"\xa8\x01\n" +
+	"\x14ReportEventsResponse\x12\x18\n" +
+	"\acreated\x18\x01 \x01(\x05R\acreated\x12\x16\n" +
+	"\x06stored\x18\x02 \x03(\x05R\x06stored\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\x03 \x03(\x05R\n" +
+	"duplicates\x12>\n" +
+	"\brejected\x18\x04 \x03(\v2\".agentsadmin.node.v1.RejectedEventR\brejected\"7\n" +
+	"\rRejectedEvent\x12\x10\n" +
+	"\x03seq\x18\x01 \x01(\x05R\x03seq\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"`\n" +
+	"\x16UpdateRunStatusRequest\x12\x15\n" +
+	"\x06run_id\x18\x01 \x01(\tR\x05runId\x12\x16\n" +
+	"\x06status\x18\x02 \x01(\tR\x06status\x12\x17\n" +
+	"\anode_id\x18\x03 \x01(\tR\x06nodeId\"1\n" +
+	"\x17UpdateRunStatusResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status2\xa6\x03\n" +
+	"\vNodeService\x12Z\n" +
+	"\tHeartbeat\x12%.agentsadmin.node.v1.HeartbeatRequest\x1a&.agentsadmin.node.v1.HeartbeatResponse\x12f\n" +
+	"\x11WatchAssignedRuns\x12-.agentsadmin.node.v1.WatchAssignedRunsRequest\x1a .agentsadmin.node.v1.AssignedRun0\x01\x12e\n" +
+	"\fReportEvents\x12(.agentsadmin.node.v1.ReportEventsRequest\x1a).agentsadmin.node.v1.ReportEventsResponse(\x01\x12l\n" +
+	"\x0fUpdateRunStatus\x12+.agentsadmin.node.v1.UpdateRunStatusRequest\x1a,.agentsadmin.node.v1.UpdateRunStatusResponseB)Z'agents-admin/api/generated/proto/nodev1b\x06proto3"
+
+var (
+	file_agentsadmin_node_v1_node_proto_rawDescOnce sync.Once
+	file_agentsadmin_node_v1_node_proto_rawDescData []byte
+)
+
+func file_agentsadmin_node_v1_node_proto_rawDescGZIP() []byte {
+	file_agentsadmin_node_v1_node_proto_rawDescOnce.Do(func() {
+		file_agentsadmin_node_v1_node_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_agentsadmin_node_v1_node_proto_rawDesc), len(file_agentsadmin_node_v1_node_proto_rawDesc)))
+	})
+	return file_agentsadmin_node_v1_node_proto_rawDescData
+}
+
+var file_agentsadmin_node_v1_node_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_agentsadmin_node_v1_node_proto_goTypes = []any{
+	(*Capacity)(nil),                 // 0: agentsadmin.node.v1.Capacity
+	(*SpoolStatus)(nil),              // 1: agentsadmin.node.v1.SpoolStatus
+	(*NodeMetrics)(nil),              // 2: agentsadmin.node.v1.NodeMetrics
+	(*AdapterInfo)(nil),              // 3: agentsadmin.node.v1.AdapterInfo
+	(*InstancePool)(nil),             // 4: agentsadmin.node.v1.InstancePool
+	(*HeartbeatRequest)(nil),         // 5: agentsadmin.node.v1.HeartbeatRequest
+	(*HeartbeatResponse)(nil),        // 6: agentsadmin.node.v1.HeartbeatResponse
+	(*CapacityOverride)(nil),         // 7: agentsadmin.node.v1.CapacityOverride
+	(*Directives)(nil),               // 8: agentsadmin.node.v1.Directives
+	(*ApprovalOutcome)(nil),          // 9: agentsadmin.node.v1.ApprovalOutcome
+	(*Feedback)(nil),                 // 10: agentsadmin.node.v1.Feedback
+	(*WatchAssignedRunsRequest)(nil), // 11: agentsadmin.node.v1.WatchAssignedRunsRequest
+	(*AssignedRun)(nil),              // 12: agentsadmin.node.v1.AssignedRun
+	(*ReportEventsRequest)(nil),      // 13: agentsadmin.node.v1.ReportEventsRequest
+	(*Event)(nil),                    // 14: agentsadmin.node.v1.Event
+	(*ReportEventsResponse)(nil),     // 15: agentsadmin.node.v1.ReportEventsResponse
+	(*RejectedEvent)(nil),            // 16: agentsadmin.node.v1.RejectedEvent
+	(*UpdateRunStatusRequest)(nil),   // 17: agentsadmin.node.v1.UpdateRunStatusRequest
+	(*UpdateRunStatusResponse)(nil),  // 18: agentsadmin.node.v1.UpdateRunStatusResponse
+	nil,                              // 19: agentsadmin.node.v1.Capacity.AgentTypeLimitsEntry
+	nil,                              // 20: agentsadmin.node.v1.HeartbeatRequest.LabelsEntry
+	nil,                              // 21: agentsadmin.node.v1.CapacityOverride.AgentTypeLimitsEntry
+	(*timestamppb.Timestamp)(nil),    // 22: google.protobuf.Timestamp
+}
+var file_agentsadmin_node_v1_node_proto_depIdxs = []int32{
+	3,  // 0: agentsadmin.node.v1.Capacity.adapters:type_name -> agentsadmin.node.v1.AdapterInfo
+	4,  // 1: agentsadmin.node.v1.Capacity.pools:type_name -> agentsadmin.node.v1.InstancePool
+	2,  // 2: agentsadmin.node.v1.Capacity.metrics:type_name -> agentsadmin.node.v1.NodeMetrics
+	19, // 3: agentsadmin.node.v1.Capacity.agent_type_limits:type_name -> agentsadmin.node.v1.Capacity.AgentTypeLimitsEntry
+	1,  // 4: agentsadmin.node.v1.Capacity.spool:type_name -> agentsadmin.node.v1.SpoolStatus
+	20, // 5: agentsadmin.node.v1.HeartbeatRequest.labels:type_name -> agentsadmin.node.v1.HeartbeatRequest.LabelsEntry
+	0,  // 6: agentsadmin.node.v1.HeartbeatRequest.capacity:type_name -> agentsadmin.node.v1.Capacity
+	8,  // 7: agentsadmin.node.v1.HeartbeatResponse.directives:type_name -> agentsadmin.node.v1.Directives
+	7,  // 8: agentsadmin.node.v1.HeartbeatResponse.capacity_override:type_name -> agentsadmin.node.v1.CapacityOverride
+	21, // 9: agentsadmin.node.v1.CapacityOverride.agent_type_limits:type_name -> agentsadmin.node.v1.CapacityOverride.AgentTypeLimitsEntry
+	9,  // 10: agentsadmin.node.v1.Directives.approvals:type_name -> agentsadmin.node.v1.ApprovalOutcome
+	10, // 11: agentsadmin.node.v1.Directives.feedbacks:type_name -> agentsadmin.node.v1.Feedback
+	14, // 12: agentsadmin.node.v1.ReportEventsRequest.events:type_name -> agentsadmin.node.v1.Event
+	22, // 13: agentsadmin.node.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	16, // 14: agentsadmin.node.v1.ReportEventsResponse.rejected:type_name -> agentsadmin.node.v1.RejectedEvent
+	5,  // 15: agentsadmin.node.v1.NodeService.Heartbeat:input_type -> agentsadmin.node.v1.HeartbeatRequest
+	11, // 16: agentsadmin.node.v1.NodeService.WatchAssignedRuns:input_type -> agentsadmin.node.v1.WatchAssignedRunsRequest
+	13, // 17: agentsadmin.node.v1.NodeService.ReportEvents:input_type -> agentsadmin.node.v1.ReportEventsRequest
+	17, // 18: agentsadmin.node.v1.NodeService.UpdateRunStatus:input_type -> agentsadmin.node.v1.UpdateRunStatusRequest
+	6,  // 19: agentsadmin.node.v1.NodeService.Heartbeat:output_type -> agentsadmin.node.v1.HeartbeatResponse
+	12, // 20: agentsadmin.node.v1.NodeService.WatchAssignedRuns:output_type -> agentsadmin.node.v1.AssignedRun
+	15, // 21: agentsadmin.node.v1.NodeService.ReportEvents:output_type -> agentsadmin.node.v1.ReportEventsResponse
+	18, // 22: agentsadmin.node.v1.NodeService.UpdateRunStatus:output_type -> agentsadmin.node.v1.UpdateRunStatusResponse
+	19, // [19:23] is the sub-list for method output_type
+	15, // [15:19] is the sub-list for method input_type
+	15, // [15:15] is the sub-list for extension type_name
+	15, // [15:15] is the sub-list for extension extendee
+	0,  // [0:15] is the sub-list for field type_name
+}
+
+func init() { file_agentsadmin_node_v1_node_proto_init() }
+func file_agentsadmin_node_v1_node_proto_init() {
+	if File_agentsadmin_node_v1_node_proto != nil {
+		return
+	}
+	file_agentsadmin_node_v1_node_proto_msgTypes[7].OneofWrappers = []any{}
+	file_agentsadmin_node_v1_node_proto_msgTypes[14].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_agentsadmin_node_v1_node_proto_rawDesc), len(file_agentsadmin_node_v1_node_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   22,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_agentsadmin_node_v1_node_proto_goTypes,
+		DependencyIndexes: file_agentsadmin_node_v1_node_proto_depIdxs,
+		MessageInfos:      file_agentsadmin_node_v1_node_proto_msgTypes,
+	}.Build()
+	File_agentsadmin_node_v1_node_proto = out.File
+	file_agentsadmin_node_v1_node_proto_goTypes = nil
+	file_agentsadmin_node_v1_node_proto_depIdxs = nil
+}
@@ -0,0 +1,255 @@
+// 节点通信 gRPC 接口
+//
+// 与 REST 接口（POST /api/v1/nodes/heartbeat、GET /api/v1/nodes/{id}/runs、
+// POST /api/v1/runs/{id}/events、PATCH /api/v1/runs/{id}）语义一致，
+// 在独立端口提供（config api_server.grpc_listen），REST 接口保持不变。
+//
+// 认证与 REST 相同：metadata x-node-token（共享密钥或节点专属 Token）或节点客户端证书（mTLS）；
+// Heartbeat 与 REST 心跳一样无需认证。
+//
+// 修改后执行 make generate-proto 重新生成 api/generated/proto/nodev1。
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: agentsadmin/node/v1/node.proto
+
+package nodev1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	NodeService_Heartbeat_FullMethodName         = "/agentsadmin.node.v1.NodeService/Heartbeat"
+	NodeService_WatchAssignedRuns_FullMethodName = "/agentsadmin.node.v1.NodeService/WatchAssignedRuns"
+	NodeService_ReportEvents_FullMethodName      = "/agentsadmin.node.v1.NodeService/ReportEvents"
+	NodeService_UpdateRunStatus_FullMethodName   = "/agentsadmin.node.v1.NodeService/UpdateRunStatus"
+)
+
+// NodeServiceClient is the client API for NodeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// NodeService Node Manager 与 API Server 之间的通信接口
+type NodeServiceClient interface {
+	// Heartbeat 上报节点状态，响应携带控制指令（取消/超时/暂停/恢复/审批结果/人工反馈）
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error)
+	// WatchAssignedRuns 订阅分配给节点的待领取 Run（连接建立时先推送已分配的 Run，之后推送新分配的 Run）
+	WatchAssignedRuns(ctx context.Context, in *WatchAssignedRunsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AssignedRun], error)
+	// ReportEvents 流式上报 Run 事件，客户端关闭发送后返回汇总结果
+	ReportEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ReportEventsRequest, ReportEventsResponse], error)
+	// UpdateRunStatus 上报 Run 状态（已到达终态的 Run 不再被覆盖）
+	UpdateRunStatus(ctx context.Context, in *UpdateRunStatusRequest, opts ...grpc.CallOption) (*UpdateRunStatusResponse, error)
+}
+
+type nodeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewNodeServiceClient(cc grpc.ClientConnInterface) NodeServiceClient {
+	return &nodeServiceClient{cc}
+}
+
+func (c *nodeServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*HeartbeatResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeartbeatResponse)
+	err := c.cc.Invoke(ctx, NodeService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *nodeServiceClient) WatchAssignedRuns(ctx context.Context, in *WatchAssignedRunsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AssignedRun], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[0], NodeService_WatchAssignedRuns_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchAssignedRunsRequest, AssignedRun]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NodeService_WatchAssignedRunsClient = grpc.ServerStreamingClient[AssignedRun]
+
+func (c *nodeServiceClient) ReportEvents(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ReportEventsRequest, ReportEventsResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &NodeService_ServiceDesc.Streams[1], NodeService_ReportEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ReportEventsRequest, ReportEventsResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NodeService_ReportEventsClient = grpc.ClientStreamingClient[ReportEventsRequest, ReportEventsResponse]
+
+func (c *nodeServiceClient) UpdateRunStatus(ctx context.Context, in *UpdateRunStatusRequest, opts ...grpc.CallOption) (*UpdateRunStatusResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UpdateRunStatusResponse)
+	err := c.cc.Invoke(ctx, NodeService_UpdateRunStatus_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// NodeServiceServer is the server API for NodeService service.
+// All implementations must embed UnimplementedNodeServiceServer
+// for forward compatibility.
+//
+// NodeService Node Manager 与 API Server 之间的通信接口
+type NodeServiceServer interface {
+	// Heartbeat 上报节点状态，响应携带控制指令（取消/超时/暂停/恢复/审批结果/人工反馈）
+	Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error)
+	// WatchAssignedRuns 订阅分配给节点的待领取 Run（连接建立时先推送已分配的 Run，之后推送新分配的 Run）
+	WatchAssignedRuns(*WatchAssignedRunsRequest, grpc.ServerStreamingServer[AssignedRun]) error
+	// ReportEvents 流式上报 Run 事件，客户端关闭发送后返回汇总结果
+	ReportEvents(grpc.ClientStreamingServer[ReportEventsRequest, ReportEventsResponse]) error
+	// UpdateRunStatus 上报 Run 状态（已到达终态的 Run 不再被覆盖）
+	UpdateRunStatus(context.Context, *UpdateRunStatusRequest) (*UpdateRunStatusResponse, error)
+	mustEmbedUnimplementedNodeServiceServer()
+}
+
+// UnimplementedNodeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedNodeServiceServer struct{}
+
+func (UnimplementedNodeServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*HeartbeatResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedNodeServiceServer) WatchAssignedRuns(*WatchAssignedRunsRequest, grpc.ServerStreamingServer[AssignedRun]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchAssignedRuns not implemented")
+}
+func (UnimplementedNodeServiceServer) ReportEvents(grpc.ClientStreamingServer[ReportEventsRequest, ReportEventsResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ReportEvents not implemented")
+}
+func (UnimplementedNodeServiceServer) UpdateRunStatus(context.Context, *UpdateRunStatusRequest) (*UpdateRunStatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateRunStatus not implemented")
+}
+func (UnimplementedNodeServiceServer) mustEmbedUnimplementedNodeServiceServer() {}
+func (UnimplementedNodeServiceServer) testEmbeddedByValue()                     {}
+
+// UnsafeNodeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to NodeServiceServer will
+// result in compilation errors.
+type UnsafeNodeServiceServer interface {
+	mustEmbedUnimplementedNodeServiceServer()
+}
+
+func RegisterNodeServiceServer(s grpc.ServiceRegistrar, srv NodeServiceServer) {
+	// If the following call pancis, it indicates UnimplementedNodeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&NodeService_ServiceDesc, srv)
+}
+
+func _NodeService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _NodeService_WatchAssignedRuns_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchAssignedRunsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(NodeServiceServer).WatchAssignedRuns(m, &grpc.GenericServerStream[WatchAssignedRunsRequest, AssignedRun]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NodeService_WatchAssignedRunsServer = grpc.ServerStreamingServer[AssignedRun]
+
+func _NodeService_ReportEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(NodeServiceServer).ReportEvents(&grpc.GenericServerStream[ReportEventsRequest, ReportEventsResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type NodeService_ReportEventsServer = grpc.ClientStreamingServer[ReportEventsRequest, ReportEventsResponse]
+
+func _NodeService_UpdateRunStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateRunStatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(NodeServiceServer).UpdateRunStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: NodeService_UpdateRunStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(NodeServiceServer).UpdateRunStatus(ctx, req.(*UpdateRunStatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// NodeService_ServiceDesc is the grpc.ServiceDesc for NodeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var NodeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "agentsadmin.node.v1.NodeService",
+	HandlerType: (*NodeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Heartbeat",
+			Handler:    _NodeService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "UpdateRunStatus",
+			Handler:    _NodeService_UpdateRunStatus_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchAssignedRuns",
+			Handler:       _NodeService_WatchAssignedRuns_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "ReportEvents",
+			Handler:       _NodeService_ReportEvents_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "agentsadmin/node/v1/node.proto",
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"agents-admin/pkg/client"
+)
+
+var accountActions = map[string]action{
+	"list":   {usage: "[-agent-type t]", short: "列出认证账号", run: accountList},
+	"get":    {usage: "<account_id>", short: "查看账号详情", run: accountGet},
+	"create": {usage: "-name n -agent-type t", short: "创建账号（需在节点上完成认证）", run: accountCreate},
+	"delete": {usage: "<account_id>", short: "删除账号", run: accountDelete},
+}
+
+func accountList(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("accounts list", "[-agent-type t]")
+	agentType := fs.String("agent-type", "", "按 Agent 类型过滤（如 claude、gemini）")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	params := &client.ListAccountsParams{}
+	if *agentType != "" {
+		params.AgentType = agentType
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	accounts, err := a.client.ListAccounts(reqCtx, params)
+	if err != nil {
+		return err
+	}
+	t := newTable("ID", "NAME", "AGENT TYPE", "STATUS", "LAST USED")
+	for _, acc := range accounts {
+		t.add(acc.Id, acc.Name, acc.AgentType, string(acc.Status), formatTime(acc.LastUsedAt))
+	}
+	return a.print(accounts, t)
+}
+
+func accountGet(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("accounts get", "<account_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	acc, err := a.client.GetAccount(reqCtx, pos[0])
+	if err != nil {
+		return err
+	}
+	return a.printDetail(acc, [][2]string{
+		{"ID", acc.Id},
+		{"Name", acc.Name},
+		{"Agent type", acc.AgentType},
+		{"Status", string(acc.Status)},
+		{"Volume", str(acc.VolumeName)},
+		{"Archive", str(acc.VolumeArchiveKey)},
+		{"Created", formatTime(acc.CreatedAt)},
+		{"Last used", formatTime(acc.LastUsedAt)},
+	})
+}
+
+func accountCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("accounts create", "-name n -agent-type t")
+	name := fs.String("name", "", "账号名称（如邮箱）")
+	agentType := fs.String("agent-type", "", "Agent 类型（如 claude、gemini）")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *name == "" || *agentType == "" {
+		fs.Usage()
+		return errUsage
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	acc, err := a.client.CreateAccount(reqCtx, client.CreateAccountRequest{Name: *name, AgentType: *agentType})
+	if err != nil {
+		return err
+	}
+	return a.printDetail(acc, [][2]string{{"ID", acc.Id}, {"Name", acc.Name}, {"Status", string(acc.Status)}})
+}
+
+func accountDelete(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("accounts delete", "<account_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if err := a.client.DeleteAccount(reqCtx, pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "account %s 已删除\n", pos[0])
+	return nil
+}
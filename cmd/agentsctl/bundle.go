@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"agents-admin/pkg/client"
+)
+
+var bundleActions = map[string]action{
+	"apply": {usage: "-f file [-dry-run]", short: "按声明式文件同步项目、模板、Webhook 与代理", run: bundleApply},
+}
+
+// bundleApply 提交期望状态文件（JSON/YAML），逐项输出变更
+//
+// 文件中未出现的资源类型不受影响；出现的类型中不在文件里的资源会被删除，建议先用 -dry-run 查看计划。
+func bundleApply(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("apply", "-f file [-dry-run]")
+	file := fs.String("f", "", "期望状态文件（JSON 或 YAML，- 表示标准输入）")
+	dryRun := fs.Bool("dry-run", false, "只显示变更计划，不执行")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return errUsage
+	}
+	var bundle map[string]interface{}
+	if err := readSpecFile(a, *file, &bundle); err != nil {
+		return err
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	result, err := a.client.Apply(reqCtx, bundle, *dryRun)
+	if err != nil {
+		return err
+	}
+	t := newTable("KIND", "NAME", "ACTION", "ID")
+	for _, c := range result.Changes {
+		t.add(c.Kind, c.Name, c.Action, c.ID)
+	}
+	if err := a.print(result, t); err != nil {
+		return err
+	}
+	if a.output == "table" {
+		printApplySummary(a, result)
+	}
+	return nil
+}
+
+func printApplySummary(a *app, result *client.ApplyResult) {
+	prefix := ""
+	if result.DryRun {
+		prefix = "（dry-run）"
+	}
+	fmt.Fprintf(a.stdout, "%s创建 %d，更新 %d，删除 %d，未变 %d\n", prefix,
+		result.Summary["create"], result.Summary["update"], result.Summary["delete"], result.Summary["unchanged"])
+}
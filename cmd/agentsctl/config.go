@@ -0,0 +1,247 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config agentsctl 配置文件（~/.agents-admin/config）
+type Config struct {
+	CurrentProfile string              `yaml:"current_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
+}
+
+// Profile 一组连接配置
+type Profile struct {
+	Server       string `yaml:"server"`
+	Token        string `yaml:"token,omitempty"`         // 用户 JWT（login 写入）
+	RefreshToken string `yaml:"refresh_token,omitempty"` // 刷新令牌（login 写入）
+	Insecure     bool   `yaml:"insecure,omitempty"`      // 跳过 TLS 证书校验
+}
+
+// defaultConfigPath 默认配置文件路径
+func defaultConfigPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".agents-admin", "config")
+	}
+	return filepath.Join(home, ".agents-admin", "config")
+}
+
+// LoadConfig 读取配置文件（不存在时返回空配置）
+func LoadConfig(path string) (*Config, error) {
+	cfg := &Config{Profiles: map[string]*Profile{}}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*Profile{}
+	}
+	return cfg, nil
+}
+
+// Save 写入配置文件（包含令牌，权限 0600）
+func (c *Config) Save(path string) error {
+	data, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("创建配置目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("写入配置文件失败: %w", err)
+	}
+	return nil
+}
+
+var configActions = map[string]action{
+	"list":   {short: "列出 profile", run: configList},
+	"set":    {usage: "<profile> [-server url] [-insecure]", short: "创建或修改 profile", run: configSet},
+	"use":    {usage: "<profile>", short: "切换当前 profile", run: configUse},
+	"delete": {usage: "<profile>", short: "删除 profile", run: configDelete},
+	"login":  {usage: "-email <email> [-password-stdin]", short: "登录并保存令牌到当前 profile", run: configLogin},
+}
+
+func configList(_ context.Context, a *app, args []string) error {
+	if _, err := parseArgs(newFlagSet("config list", ""), args, 0, 0); err != nil {
+		return err
+	}
+	names := make([]string, 0, len(a.cfg.Profiles))
+	for name := range a.cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	t := newTable("CURRENT", "NAME", "SERVER", "LOGGED IN")
+	for _, name := range names {
+		p := a.cfg.Profiles[name]
+		current := ""
+		if name == a.cfg.CurrentProfile {
+			current = "*"
+		}
+		t.add(current, name, p.Server, yesNo(p.Token != ""))
+	}
+	// JSON 输出不包含令牌
+	type profileView struct {
+		Name     string `json:"name"`
+		Server   string `json:"server"`
+		Current  bool   `json:"current"`
+		LoggedIn bool   `json:"logged_in"`
+	}
+	views := make([]profileView, 0, len(names))
+	for _, name := range names {
+		p := a.cfg.Profiles[name]
+		views = append(views, profileView{Name: name, Server: p.Server, Current: name == a.cfg.CurrentProfile, LoggedIn: p.Token != ""})
+	}
+	return a.print(views, t)
+}
+
+func configSet(_ context.Context, a *app, args []string) error {
+	fs := newFlagSet("config set", "<profile> [-server url] [-insecure]")
+	server := fs.String("server", "", "API Server 地址")
+	insecure := fs.Bool("insecure", false, "跳过 TLS 证书校验")
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	name := pos[0]
+	p := a.cfg.Profiles[name]
+	if p == nil {
+		p = &Profile{}
+		a.cfg.Profiles[name] = p
+	}
+	if *server != "" {
+		p.Server = strings.TrimRight(*server, "/")
+	}
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == "insecure" {
+			p.Insecure = *insecure
+		}
+	})
+	if p.Server == "" {
+		return fmt.Errorf("profile %s 缺少 -server", name)
+	}
+	if a.cfg.CurrentProfile == "" {
+		a.cfg.CurrentProfile = name
+	}
+	if err := a.cfg.Save(a.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "profile %s 已保存\n", name)
+	return nil
+}
+
+func configUse(_ context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("config use", "<profile>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if a.cfg.Profiles[pos[0]] == nil {
+		return fmt.Errorf("profile 不存在: %s", pos[0])
+	}
+	a.cfg.CurrentProfile = pos[0]
+	if err := a.cfg.Save(a.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "当前 profile: %s\n", pos[0])
+	return nil
+}
+
+func configDelete(_ context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("config delete", "<profile>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if a.cfg.Profiles[pos[0]] == nil {
+		return fmt.Errorf("profile 不存在: %s", pos[0])
+	}
+	delete(a.cfg.Profiles, pos[0])
+	if a.cfg.CurrentProfile == pos[0] {
+		a.cfg.CurrentProfile = ""
+	}
+	if err := a.cfg.Save(a.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "profile %s 已删除\n", pos[0])
+	return nil
+}
+
+// configLogin 登录并将令牌写入当前 profile（-server 指定的地址一并保存）
+func configLogin(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("login", "-email <email> [-password-stdin]")
+	email := fs.String("email", "", "登录邮箱")
+	passwordStdin := fs.Bool("password-stdin", false, "从标准输入读取密码（默认读取 AGENTS_ADMIN_PASSWORD）")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *email == "" {
+		fs.Usage()
+		return errUsage
+	}
+	if a.profile.Server == "" {
+		return fmt.Errorf("未配置 API Server 地址，请使用 -server 或 agentsctl config set <profile> -server <url>")
+	}
+
+	password := os.Getenv("AGENTS_ADMIN_PASSWORD")
+	if *passwordStdin {
+		line, err := bufio.NewReader(a.stdin).ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("读取密码失败: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	if password == "" {
+		return fmt.Errorf("缺少密码，请使用 -password-stdin 或设置 AGENTS_ADMIN_PASSWORD")
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	resp, err := newClient(a.profile).Login(reqCtx, *email, password)
+	if err != nil {
+		return err
+	}
+	if resp.AccessToken == nil || *resp.AccessToken == "" {
+		return fmt.Errorf("登录响应缺少 access_token")
+	}
+
+	name := a.profileName
+	if name == "" {
+		name = "default"
+	}
+	p := a.cfg.Profiles[name]
+	if p == nil {
+		p = &Profile{}
+		a.cfg.Profiles[name] = p
+	}
+	p.Server = a.profile.Server
+	p.Insecure = a.profile.Insecure
+	p.Token = *resp.AccessToken
+	p.RefreshToken = ""
+	if resp.RefreshToken != nil {
+		p.RefreshToken = *resp.RefreshToken
+	}
+	if a.cfg.CurrentProfile == "" {
+		a.cfg.CurrentProfile = name
+	}
+	if err := a.cfg.Save(a.configPath); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "已登录 %s（profile %s）\n", p.Server, name)
+	return nil
+}
@@ -0,0 +1,267 @@
+// Package main agentsctl 管理员命令行工具
+//
+// 通过 REST API（pkg/client）管理任务、执行、节点、模板与账号，
+// 替代运维场景下的 curl 调用。
+//
+// 连接配置按 profile 保存在 ~/.agents-admin/config（YAML，可用 -config 或
+// AGENTSCTL_CONFIG 指定），命令行参数与环境变量优先于 profile：
+//   - 服务地址：-server > AGENTS_ADMIN_SERVER > profile.server
+//   - 用户 JWT：-token > AGENTS_ADMIN_TOKEN > profile.token（login 写入）
+//
+// 用法示例：
+//
+//	agentsctl config set prod -server https://admin.example.com
+//	agentsctl login -email admin@example.com -password-stdin < pass.txt
+//	agentsctl tasks create -name fix-ci -prompt "修复 CI 失败" -start
+//	agentsctl runs events -f run-123
+//	agentsctl -o json nodes list
+//	agentsctl nodes drain -wait node-1
+//
+// 退出码：请求失败返回 1，参数错误返回 2。
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"agents-admin/pkg/client"
+)
+
+// requestTimeout 单次请求超时（events -f 的长轮询由上下文控制，不受此限制）
+const requestTimeout = 30 * time.Second
+
+// errUsage 参数错误（已输出用法说明）
+var errUsage = errors.New("usage")
+
+// action 资源下的一个动作
+type action struct {
+	usage string // 参数说明（不含资源与动作名）
+	short string // 一行描述
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+// resources 资源 → 动作表
+var resources = map[string]map[string]action{
+	"config":    configActions,
+	"tasks":     taskActions,
+	"runs":      runActions,
+	"nodes":     nodeActions,
+	"templates": templateActions,
+	"accounts":  accountActions,
+	"bundle":    bundleActions,
+}
+
+// app 一次命令执行的上下文
+type app struct {
+	configPath  string
+	cfg         *Config
+	profileName string
+	profile     Profile
+	output      string // table / json
+	stdout      io.Writer
+	stdin       io.Reader
+
+	client *client.Client
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	fs := flag.NewFlagSet("agentsctl", flag.ContinueOnError)
+	configPath := fs.String("config", envOr("AGENTSCTL_CONFIG", defaultConfigPath()), "配置文件路径")
+	profileName := fs.String("profile", os.Getenv("AGENTSCTL_PROFILE"), "使用的 profile（默认为配置中的 current_profile）")
+	server := fs.String("server", os.Getenv("AGENTS_ADMIN_SERVER"), "API Server 地址（覆盖 profile）")
+	token := fs.String("token", os.Getenv("AGENTS_ADMIN_TOKEN"), "用户 JWT（覆盖 profile）")
+	insecure := fs.Bool("insecure", false, "跳过 TLS 证书校验（开发环境自签名证书）")
+	output := fs.String("o", "table", "输出格式：table / json")
+	fs.Usage = func() { printUsage(fs) }
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *output != "table" && *output != "json" {
+		fmt.Fprintf(os.Stderr, "agentsctl: 不支持的输出格式 %q（可选 table / json）\n", *output)
+		return 2
+	}
+
+	rest := fs.Args()
+	if len(rest) == 0 {
+		fs.Usage()
+		return 2
+	}
+	resource, act, actArgs, err := lookupAction(rest)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agentsctl: %v\n\n", err)
+		fs.Usage()
+		return 2
+	}
+
+	cfg, err := LoadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "agentsctl: %v\n", err)
+		return 1
+	}
+	a := &app{configPath: *configPath, cfg: cfg, output: *output, stdout: os.Stdout, stdin: os.Stdin}
+	a.profileName = *profileName
+	if a.profileName == "" {
+		a.profileName = cfg.CurrentProfile
+	}
+	if p := cfg.Profiles[a.profileName]; p != nil {
+		a.profile = *p
+	}
+	if *server != "" {
+		a.profile.Server = *server
+	}
+	if *token != "" {
+		a.profile.Token = *token
+	}
+	if *insecure {
+		a.profile.Insecure = true
+	}
+	if resource != "config" {
+		if a.profile.Server == "" {
+			fmt.Fprintln(os.Stderr, "agentsctl: 未配置 API Server 地址，请使用 -server 或 agentsctl config set <profile> -server <url>")
+			return 2
+		}
+		a.client = newClient(a.profile)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := act.run(ctx, a, actArgs); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(os.Stderr, "agentsctl: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// lookupAction 解析 <资源> <动作>，返回资源名、动作与剩余参数
+func lookupAction(args []string) (string, action, []string, error) {
+	// login 是 config login 的快捷命令，apply 是 bundle apply 的快捷命令
+	switch args[0] {
+	case "login":
+		args = append([]string{"config"}, args...)
+	case "apply":
+		args = append([]string{"bundle"}, args...)
+	}
+	actions, ok := resources[args[0]]
+	if !ok {
+		return "", action{}, nil, fmt.Errorf("未知资源: %s", args[0])
+	}
+	if len(args) < 2 {
+		return "", action{}, nil, fmt.Errorf("缺少 %s 的动作（可选: %s）", args[0], strings.Join(actionNames(actions), ", "))
+	}
+	act, ok := actions[args[1]]
+	if !ok {
+		return "", action{}, nil, fmt.Errorf("未知动作: %s %s（可选: %s）", args[0], args[1], strings.Join(actionNames(actions), ", "))
+	}
+	return args[0], act, args[2:], nil
+}
+
+func actionNames(actions map[string]action) []string {
+	names := make([]string, 0, len(actions))
+	for name := range actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func printUsage(fs *flag.FlagSet) {
+	w := fs.Output()
+	fmt.Fprintln(w, "用法: agentsctl [全局选项] <资源> <动作> [参数]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "全局选项:")
+	fs.PrintDefaults()
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "命令:")
+	names := make([]string, 0, len(resources))
+	for name := range resources {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, res := range names {
+		for _, name := range actionNames(resources[res]) {
+			act := resources[res][name]
+			fmt.Fprintf(w, "  %-36s %s\n", strings.TrimSpace(res+" "+name+" "+act.usage), act.short)
+		}
+	}
+	fmt.Fprintln(w, "  （login 为 config login 的简写）")
+}
+
+// newFlagSet 创建动作的参数解析器（错误时输出该动作的用法）
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: agentsctl %s %s\n", name, usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseArgs 解析动作参数并校验位置参数个数（min ≤ n ≤ max，max < 0 表示不限）
+//
+// 允许选项出现在位置参数之后（如 runs events run-1 -f）
+func parseArgs(fs *flag.FlagSet, args []string, min, max int) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) < min || (max >= 0 && len(positional) > max) {
+		fs.Usage()
+		return nil, errUsage
+	}
+	return positional, nil
+}
+
+// newClient 按 profile 创建 API 客户端
+func newClient(p Profile) *client.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if p.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	opts := []client.Option{
+		client.WithHTTPClient(&http.Client{Transport: transport}),
+		client.WithUserAgent("agentsctl"),
+	}
+	if p.Token != "" {
+		opts = append(opts, client.WithToken(p.Token))
+	}
+	return client.New(p.Server, opts...)
+}
+
+// withTimeout 为单次请求设置超时
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, requestTimeout)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
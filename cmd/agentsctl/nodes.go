@@ -0,0 +1,181 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	openapi "agents-admin/api/generated/go"
+	"agents-admin/pkg/client"
+)
+
+var nodeActions = map[string]action{
+	"list":    {short: "列出节点", run: nodeList},
+	"get":     {usage: "<node_id>", short: "查看节点详情与槽位占用", run: nodeGet},
+	"drain":   {usage: "<node_id> [-wait] [-timeout d]", short: "排空节点（不再分配新执行）", run: nodeDrain},
+	"undrain": {usage: "<node_id>", short: "恢复节点接收新执行", run: nodeUndrain},
+	"delete":  {usage: "<node_id>", short: "删除节点", run: nodeDelete},
+}
+
+func nodeList(ctx context.Context, a *app, args []string) error {
+	if _, err := parseArgs(newFlagSet("nodes list", ""), args, 0, 0); err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	nodes, err := a.client.ListNodes(reqCtx)
+	if err != nil {
+		return err
+	}
+	occupancy, err := a.client.ListNodeOccupancy(reqCtx)
+	if err != nil {
+		return err
+	}
+	used := make(map[string]string, len(occupancy))
+	for _, occ := range occupancy {
+		used[str(occ.NodeId)] = fmt.Sprintf("%d/%d", intOr(occ.Used), intOr(occ.Capacity))
+	}
+
+	t := newTable("ID", "NAME", "STATUS", "HOSTNAME", "SLOTS", "LAST HEARTBEAT")
+	for _, n := range nodes {
+		t.add(n.Id, str(n.DisplayName), string(n.Status), str(n.Hostname), used[n.Id], formatTime(n.LastHeartbeat))
+	}
+	return a.print(nodes, t)
+}
+
+func nodeGet(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("nodes get", "<node_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	node, err := a.client.GetNode(reqCtx, pos[0])
+	if err != nil {
+		return err
+	}
+	runs, err := a.client.ListNodeRuns(reqCtx, pos[0])
+	if err != nil {
+		return err
+	}
+	fields := [][2]string{
+		{"ID", node.Id},
+		{"Name", str(node.DisplayName)},
+		{"Status", string(node.Status)},
+		{"Hostname", str(node.Hostname)},
+		{"IPs", str(node.Ips)},
+		{"Labels", formatLabels(node.Labels)},
+		{"Last heartbeat", formatTime(node.LastHeartbeat)},
+		{"Runs", fmt.Sprint(len(runs))},
+	}
+	if occ, err := a.nodeOccupancy(reqCtx, pos[0]); err == nil && occ != nil {
+		fields = append(fields, [2]string{"Slots", fmt.Sprintf("%d/%d", intOr(occ.Used), intOr(occ.Capacity))})
+	}
+	result := struct {
+		*client.Node
+		Runs []client.Run `json:"runs"`
+	}{node, runs}
+	return a.printDetail(result, fields)
+}
+
+// nodeDrain 将节点置为 draining（调度器不再分配新执行，已分配的执行继续运行）
+//
+// -wait 时轮询槽位占用，直到节点上没有执行或超时。
+func nodeDrain(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("nodes drain", "<node_id> [-wait] [-timeout d]")
+	wait := fs.Bool("wait", false, "等待节点上的执行全部结束")
+	timeout := fs.Duration("timeout", 30*time.Minute, "-wait 的最长等待时间")
+	interval := fs.Duration("interval", 5*time.Second, "-wait 的轮询间隔")
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	nodeID := pos[0]
+	if err := a.setNodeStatus(ctx, nodeID, openapi.NodeStatusDraining); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "node %s 已置为 draining\n", nodeID)
+	if !*wait {
+		return nil
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+	for {
+		reqCtx, reqCancel := withTimeout(waitCtx)
+		occ, err := a.nodeOccupancy(reqCtx, nodeID)
+		reqCancel()
+		if err != nil {
+			return err
+		}
+		if occ == nil || intOr(occ.Used) == 0 {
+			fmt.Fprintf(a.stdout, "node %s 已排空\n", nodeID)
+			return nil
+		}
+		fmt.Fprintf(a.stdout, "等待 %d 个执行结束...\n", intOr(occ.Used))
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("等待超时：node %s 仍有 %d 个执行", nodeID, intOr(occ.Used))
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// nodeUndrain 将节点恢复为 online（之后由心跳决定实际在线状态）
+func nodeUndrain(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("nodes undrain", "<node_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := a.setNodeStatus(ctx, pos[0], openapi.NodeStatusOnline); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "node %s 已恢复\n", pos[0])
+	return nil
+}
+
+func nodeDelete(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("nodes delete", "<node_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if err := a.client.DeleteNode(reqCtx, pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "node %s 已删除\n", pos[0])
+	return nil
+}
+
+func (a *app) setNodeStatus(ctx context.Context, nodeID string, status openapi.NodeStatus) error {
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	s := string(status)
+	_, err := a.client.UpdateNode(reqCtx, nodeID, client.UpdateNodeRequest{Status: &s})
+	return err
+}
+
+// nodeOccupancy 查询单个节点的槽位占用（节点未上报过占用时返回 nil）
+func (a *app) nodeOccupancy(ctx context.Context, nodeID string) (*client.NodeOccupancy, error) {
+	list, err := a.client.ListNodeOccupancy(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range list {
+		if str(list[i].NodeId) == nodeID {
+			return &list[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func intOr(p *int) int {
+	if p == nil {
+		return 0
+	}
+	return *p
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	openapi "agents-admin/api/generated/go"
+	"agents-admin/pkg/client"
+)
+
+// eventPageSize 拉取事件的分页大小（与 GET /events 上限一致）
+const eventPageSize = 1000
+
+var runActions = map[string]action{
+	"list":   {usage: "[-task id] [-status s] [-node id] [-limit n]", short: "列出执行", run: runList},
+	"get":    {usage: "<run_id>", short: "查看执行详情", run: runGet},
+	"cancel": {usage: "<run_id>", short: "取消执行", run: runCancel},
+	"events": {usage: "<run_id> [-f] [-from seq]", short: "查看执行事件（-f 持续跟踪直到执行结束）", run: runEvents},
+}
+
+// runFinished 执行是否已进入终态
+func runFinished(status openapi.RunStatus) bool {
+	switch status {
+	case openapi.RunStatusDone, openapi.RunStatusFailed, openapi.RunStatusCancelled, openapi.RunStatusTimeout:
+		return true
+	}
+	return false
+}
+
+func runList(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("runs list", "[-task id] [-status s] [-node id] [-limit n]")
+	taskID := fs.String("task", "", "所属任务")
+	status := fs.String("status", "", "状态筛选（逗号分隔）")
+	nodeID := fs.String("node", "", "执行节点")
+	limit := fs.Int("limit", 50, "返回条数（最大 100）")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	params := &client.ListRunsParams{Limit: limit}
+	if *taskID != "" {
+		params.TaskId = taskID
+	}
+	if *status != "" {
+		params.Status = status
+	}
+	if *nodeID != "" {
+		params.NodeId = nodeID
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	list, err := a.client.ListRuns(reqCtx, params)
+	if err != nil {
+		return err
+	}
+
+	t := newTable("ID", "TASK", "STATUS", "NODE", "STARTED", "FINISHED")
+	for _, run := range list.Runs {
+		t.add(run.Id, run.TaskId, string(run.Status), str(run.NodeId), formatTime(run.StartedAt), formatTime(run.FinishedAt))
+	}
+	if err := a.print(list, t); err != nil {
+		return err
+	}
+	if a.output == "table" && list.HasMore {
+		fmt.Fprintf(a.stdout, "（仅显示前 %d 条，使用 -limit 调整）\n", len(list.Runs))
+	}
+	return nil
+}
+
+func runGet(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("runs get", "<run_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	run, err := a.client.GetRun(reqCtx, pos[0])
+	if err != nil {
+		return err
+	}
+	exitCode := ""
+	if run.ExitCode != nil {
+		exitCode = fmt.Sprint(*run.ExitCode)
+	}
+	return a.printDetail(run, [][2]string{
+		{"ID", run.Id},
+		{"Task", run.TaskId},
+		{"Status", string(run.Status)},
+		{"Node", str(run.NodeId)},
+		{"Priority", str(run.Priority)},
+		{"Started", formatTime(run.StartedAt)},
+		{"Finished", formatTime(run.FinishedAt)},
+		{"Exit code", exitCode},
+		{"Error", str(run.Error)},
+	})
+}
+
+func runCancel(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("runs cancel", "<run_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if err := a.client.CancelRun(reqCtx, pos[0]); err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "run %s 已取消\n", pos[0])
+	return nil
+}
+
+// runEvents 输出执行事件
+//
+// table 模式逐行输出 seq、时间、类型与 payload 摘要；json 模式每行一个事件（JSON Lines），便于管道处理。
+// -f 时按 seq 增量轮询，执行进入终态且无新事件后退出。
+func runEvents(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("runs events", "<run_id> [-f] [-from seq]")
+	follow := fs.Bool("f", false, "持续跟踪新事件直到执行结束")
+	fromSeq := fs.Int("from", 0, "只输出 seq 大于该值的事件")
+	interval := fs.Duration("interval", 2*time.Second, "跟踪时的轮询间隔")
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	runID := pos[0]
+
+	lastSeq := *fromSeq
+	if a.output == "table" {
+		fmt.Fprintln(a.stdout, "SEQ\tTIME\tTYPE\tPAYLOAD")
+	}
+	for {
+		n, err := a.pullEvents(ctx, runID, &lastSeq)
+		if err != nil {
+			return err
+		}
+		if !*follow {
+			return nil
+		}
+		if n == 0 {
+			reqCtx, cancel := withTimeout(ctx)
+			run, err := a.client.GetRun(reqCtx, runID)
+			cancel()
+			if err != nil {
+				return err
+			}
+			if runFinished(run.Status) {
+				// 终态之后可能还有未拉取的尾部事件
+				if _, err := a.pullEvents(ctx, runID, &lastSeq); err != nil {
+					return err
+				}
+				if a.output == "table" {
+					fmt.Fprintf(a.stdout, "-- run %s %s\n", runID, run.Status)
+				}
+				return nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(*interval):
+		}
+	}
+}
+
+// pullEvents 拉取并输出 seq 大于 lastSeq 的全部事件，返回输出条数
+func (a *app) pullEvents(ctx context.Context, runID string, lastSeq *int) (int, error) {
+	total := 0
+	limit := eventPageSize
+	for {
+		reqCtx, cancel := withTimeout(ctx)
+		events, err := a.client.GetEvents(reqCtx, runID, &client.GetEventsParams{FromSeq: lastSeq, Limit: &limit})
+		cancel()
+		if err != nil {
+			return total, err
+		}
+		for _, ev := range events {
+			if err := a.printEvent(ev); err != nil {
+				return total, err
+			}
+			if ev.Seq > *lastSeq {
+				*lastSeq = ev.Seq
+			}
+		}
+		total += len(events)
+		if len(events) < limit {
+			return total, nil
+		}
+	}
+}
+
+func (a *app) printEvent(ev client.Event) error {
+	if a.output == "json" {
+		data, err := json.Marshal(ev)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(a.stdout, "%s\n", data)
+		return err
+	}
+	payload := ""
+	if ev.Payload != nil {
+		data, _ := json.Marshal(*ev.Payload)
+		payload = truncate(string(data), 120)
+	}
+	ts := ""
+	if ev.Timestamp != nil {
+		ts = ev.Timestamp.Local().Format("15:04:05")
+	}
+	_, err := fmt.Fprintf(a.stdout, "%d\t%s\t%s\t%s\n", ev.Seq, ts, ev.Type, payload)
+	return err
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"agents-admin/pkg/client"
+)
+
+var taskActions = map[string]action{
+	"list":   {usage: "[-status s] [-search q] [-label k=v] [-limit n]", short: "列出任务", run: taskList},
+	"get":    {usage: "<task_id>", short: "查看任务详情", run: taskGet},
+	"create": {usage: "-name n (-prompt p | -f file) [-start]", short: "创建任务", run: taskCreate},
+	"cancel": {usage: "<task_id>...", short: "取消任务的活跃执行", run: taskCancel},
+	"delete": {usage: "<task_id>...", short: "删除任务及其执行记录", run: taskDelete},
+	"rerun":  {usage: "<task_id>...", short: "为任务启动一次新的执行", run: taskRerun},
+}
+
+func taskList(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("tasks list", "[-status s] [-search q] [-label k=v] [-limit n]")
+	status := fs.String("status", "", "状态筛选（逗号分隔）")
+	search := fs.String("search", "", "按名称模糊搜索")
+	node := fs.String("node", "", "有执行分配到该节点的任务")
+	var labels stringList
+	fs.Var(&labels, "label", "标签选择器（可重复，如 team=infra、!archived）")
+	limit := fs.Int("limit", 50, "返回条数")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	params := &client.ListTasksParams{Limit: limit}
+	if *status != "" {
+		params.Status = status
+	}
+	if *search != "" {
+		params.Search = search
+	}
+	if *node != "" {
+		params.NodeId = node
+	}
+	if len(labels) > 0 {
+		selector := strings.Join(labels, ",")
+		params.Labels = &selector
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	list, err := a.client.ListTasks(reqCtx, params)
+	if err != nil {
+		return err
+	}
+
+	t := newTable("ID", "NAME", "STATUS", "TYPE", "PRIORITY", "CREATED")
+	for _, task := range list.Tasks {
+		t.add(task.Id, truncate(task.Name, 40), string(task.Status), str(task.Type), str(task.Priority), formatTime(task.CreatedAt))
+	}
+	if err := a.print(list, t); err != nil {
+		return err
+	}
+	if a.output == "table" && list.HasMore {
+		fmt.Fprintf(a.stdout, "（仅显示前 %d 条，使用 -limit 调整）\n", len(list.Tasks))
+	}
+	return nil
+}
+
+func taskGet(ctx context.Context, a *app, args []string) error {
+	pos, err := parseArgs(newFlagSet("tasks get", "<task_id>"), args, 1, 1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	task, err := a.client.GetTask(reqCtx, pos[0])
+	if err != nil {
+		return err
+	}
+	runs, err := a.client.ListTaskRuns(reqCtx, pos[0], nil)
+	if err != nil {
+		return err
+	}
+
+	prompt := ""
+	if task.Prompt != nil {
+		prompt = truncate(strings.ReplaceAll(task.Prompt.Content, "\n", " "), 100)
+	}
+	fields := [][2]string{
+		{"ID", task.Id},
+		{"Name", task.Name},
+		{"Status", string(task.Status)},
+		{"Type", str(task.Type)},
+		{"Priority", str(task.Priority)},
+		{"Prompt", prompt},
+		{"Labels", formatLabels(task.Labels)},
+		{"Created", formatTime(task.CreatedAt)},
+		{"Updated", formatTime(task.UpdatedAt)},
+		{"Runs", fmt.Sprint(len(runs))},
+	}
+	if len(runs) > 0 {
+		latest := runs[0]
+		fields = append(fields, [2]string{"Latest run", fmt.Sprintf("%s (%s)", latest.Id, latest.Status)})
+	}
+	return a.printDetail(task, fields)
+}
+
+func taskCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("tasks create", "-name n (-prompt p | -f file) [-start]")
+	file := fs.String("f", "", "从 JSON/YAML 文件读取完整请求体（CreateTaskRequest，\"-\" 表示标准输入）")
+	name := fs.String("name", "", "任务名称")
+	prompt := fs.String("prompt", "", "任务提示词")
+	taskType := fs.String("type", "", "任务类型")
+	agentID := fs.String("agent", "", "执行的 Agent ID")
+	templateID := fs.String("template", "", "任务模板 ID")
+	priority := fs.String("priority", "", "调度优先级（high/normal/low）")
+	var labels stringList
+	fs.Var(&labels, "label", "标签（可重复，key=value）")
+	start := fs.Bool("start", false, "创建后立即启动执行")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+
+	var req client.CreateTaskRequest
+	if *file != "" {
+		if err := readSpecFile(a, *file, &req); err != nil {
+			return err
+		}
+	}
+	if *name != "" {
+		req.Name = *name
+	}
+	if *prompt != "" {
+		req.Prompt = *prompt
+	}
+	if *taskType != "" {
+		req.Type = taskType
+	}
+	if *agentID != "" {
+		req.AgentId = agentID
+	}
+	if *templateID != "" {
+		req.TemplateId = templateID
+	}
+	if *priority != "" {
+		req.Priority = priority
+	}
+	if len(labels) > 0 {
+		parsed, err := parseLabels(labels)
+		if err != nil {
+			return err
+		}
+		req.Labels = &parsed
+	}
+	if req.Name == "" || req.Prompt == "" {
+		fs.Usage()
+		return errUsage
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	task, err := a.client.CreateTask(reqCtx, req)
+	if err != nil {
+		return err
+	}
+	if !*start {
+		return a.printDetail(task, [][2]string{{"ID", task.Id}, {"Name", task.Name}, {"Status", string(task.Status)}})
+	}
+	run, err := a.client.CreateRun(reqCtx, task.Id)
+	if err != nil {
+		return fmt.Errorf("任务 %s 已创建，启动执行失败: %w", task.Id, err)
+	}
+	result := struct {
+		Task *client.Task `json:"task"`
+		Run  *client.Run  `json:"run"`
+	}{task, run}
+	return a.printDetail(result, [][2]string{{"ID", task.Id}, {"Name", task.Name}, {"Run", run.Id}, {"Run status", string(run.Status)}})
+}
+
+func taskCancel(ctx context.Context, a *app, args []string) error {
+	return taskBulk(ctx, a, "cancel", args)
+}
+
+func taskDelete(ctx context.Context, a *app, args []string) error {
+	return taskBulk(ctx, a, "delete", args)
+}
+
+func taskRerun(ctx context.Context, a *app, args []string) error {
+	return taskBulk(ctx, a, "rerun", args)
+}
+
+// taskBulk 通过批量接口对一组任务执行 cancel / delete / rerun，逐项输出结果
+func taskBulk(ctx context.Context, a *app, bulkAction string, args []string) error {
+	ids, err := parseArgs(newFlagSet("tasks "+bulkAction, "<task_id>..."), args, 1, -1)
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	resp, err := a.client.BulkTasks(reqCtx, client.BulkTaskRequest{Action: bulkAction, TaskIds: &ids})
+	if err != nil {
+		return err
+	}
+
+	t := newTable("TASK", "STATUS", "DETAIL")
+	for _, res := range resp.Results {
+		taskID := str(res.TaskId)
+		if taskID == "" && res.Index < len(ids) {
+			taskID = ids[res.Index]
+		}
+		detail := str(res.Error)
+		switch {
+		case detail != "":
+		case res.RunId != nil:
+			detail = "run " + *res.RunId
+		case res.CancelledRuns != nil:
+			detail = fmt.Sprintf("cancelled runs: %s", strings.Join(*res.CancelledRuns, ", "))
+		}
+		t.add(taskID, fmt.Sprint(res.Status), detail)
+	}
+	if err := a.print(resp, t); err != nil {
+		return err
+	}
+	if resp.Failed > 0 {
+		return fmt.Errorf("%d/%d 项失败", resp.Failed, len(ids))
+	}
+	return nil
+}
+
+// formatLabels 按 k=v 输出标签
+func formatLabels(labels *map[string]string) string {
+	if labels == nil {
+		return ""
+	}
+	pairs := make([]string, 0, len(*labels))
+	for k, v := range *labels {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
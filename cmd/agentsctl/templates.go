@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"agents-admin/pkg/client"
+)
+
+// 模板种类（-kind）
+const (
+	templateKindTask  = "task"
+	templateKindAgent = "agent"
+)
+
+var templateActions = map[string]action{
+	"list":   {usage: "[-kind task|agent] [-category c]", short: "列出任务模板或 Agent 模板", run: templateList},
+	"get":    {usage: "<template_id> [-kind task|agent]", short: "查看模板详情", run: templateGet},
+	"create": {usage: "-f file [-kind task|agent]", short: "从 JSON/YAML 文件创建模板", run: templateCreate},
+	"delete": {usage: "<template_id> [-kind task|agent]", short: "删除模板", run: templateDelete},
+}
+
+// kindFlag 注册 -kind 参数
+func kindFlag(fs *flag.FlagSet) *string {
+	return fs.String("kind", templateKindTask, "模板种类：task（任务模板）/ agent（Agent 模板）")
+}
+
+func checkKind(kind string) error {
+	if kind != templateKindTask && kind != templateKindAgent {
+		return fmt.Errorf("不支持的模板种类 %q（可选 task / agent）", kind)
+	}
+	return nil
+}
+
+func templateList(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("templates list", "[-kind task|agent] [-category c]")
+	kind := kindFlag(fs)
+	category := fs.String("category", "", "按分类过滤")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if err := checkKind(*kind); err != nil {
+		return err
+	}
+	var categoryParam *string
+	if *category != "" {
+		categoryParam = category
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if *kind == templateKindAgent {
+		templates, err := a.client.ListAgentTemplates(reqCtx, &client.ListAgentTemplatesParams{Category: categoryParam})
+		if err != nil {
+			return err
+		}
+		t := newTable("ID", "NAME", "TYPE", "MODEL", "CATEGORY", "BUILTIN")
+		for _, tmpl := range templates {
+			t.add(tmpl.Id, tmpl.Name, string(tmpl.Type), str(tmpl.Model), str(tmpl.Category), yesNo(tmpl.IsBuiltin != nil && *tmpl.IsBuiltin))
+		}
+		return a.print(templates, t)
+	}
+
+	templates, err := a.client.ListTaskTemplates(reqCtx, &client.ListTaskTemplatesParams{Category: categoryParam})
+	if err != nil {
+		return err
+	}
+	t := newTable("ID", "NAME", "CATEGORY", "VARIABLES", "BUILTIN")
+	for _, tmpl := range templates {
+		vars := ""
+		if tmpl.Variables != nil {
+			vars = strings.Join(*tmpl.Variables, ",")
+		}
+		t.add(tmpl.Id, tmpl.Name, str(tmpl.Category), vars, yesNo(tmpl.IsBuiltin != nil && *tmpl.IsBuiltin))
+	}
+	return a.print(templates, t)
+}
+
+func templateGet(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("templates get", "<template_id> [-kind task|agent]")
+	kind := kindFlag(fs)
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := checkKind(*kind); err != nil {
+		return err
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if *kind == templateKindAgent {
+		tmpl, err := a.client.GetAgentTemplate(reqCtx, pos[0])
+		if err != nil {
+			return err
+		}
+		return a.printDetail(tmpl, [][2]string{
+			{"ID", tmpl.Id},
+			{"Name", tmpl.Name},
+			{"Type", string(tmpl.Type)},
+			{"Model", str(tmpl.Model)},
+			{"Role", str(tmpl.Role)},
+			{"Category", str(tmpl.Category)},
+			{"Description", str(tmpl.Description)},
+			{"Created", formatTime(tmpl.CreatedAt)},
+		})
+	}
+
+	tmpl, err := a.client.GetTaskTemplate(reqCtx, pos[0])
+	if err != nil {
+		return err
+	}
+	vars := ""
+	if tmpl.Variables != nil {
+		vars = strings.Join(*tmpl.Variables, ",")
+	}
+	return a.printDetail(tmpl, [][2]string{
+		{"ID", tmpl.Id},
+		{"Name", tmpl.Name},
+		{"Category", str(tmpl.Category)},
+		{"Description", str(tmpl.Description)},
+		{"Variables", vars},
+		{"Prompt", truncate(strings.ReplaceAll(str(tmpl.PromptTemplate), "\n", " "), 100)},
+		{"Created", formatTime(tmpl.CreatedAt)},
+	})
+}
+
+func templateCreate(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("templates create", "-f file [-kind task|agent]")
+	kind := kindFlag(fs)
+	file := fs.String("f", "", "模板定义文件（JSON/YAML，\"-\" 表示标准输入）")
+	if _, err := parseArgs(fs, args, 0, 0); err != nil {
+		return err
+	}
+	if *file == "" {
+		fs.Usage()
+		return errUsage
+	}
+	if err := checkKind(*kind); err != nil {
+		return err
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if *kind == templateKindAgent {
+		var req client.CreateAgentTemplateRequest
+		if err := readSpecFile(a, *file, &req); err != nil {
+			return err
+		}
+		tmpl, err := a.client.CreateAgentTemplate(reqCtx, req)
+		if err != nil {
+			return err
+		}
+		return a.printDetail(tmpl, [][2]string{{"ID", tmpl.Id}, {"Name", tmpl.Name}})
+	}
+
+	var req client.CreateTaskTemplateRequest
+	if err := readSpecFile(a, *file, &req); err != nil {
+		return err
+	}
+	tmpl, err := a.client.CreateTaskTemplate(reqCtx, req)
+	if err != nil {
+		return err
+	}
+	return a.printDetail(tmpl, [][2]string{{"ID", tmpl.Id}, {"Name", tmpl.Name}})
+}
+
+func templateDelete(ctx context.Context, a *app, args []string) error {
+	fs := newFlagSet("templates delete", "<template_id> [-kind task|agent]")
+	kind := kindFlag(fs)
+	pos, err := parseArgs(fs, args, 1, 1)
+	if err != nil {
+		return err
+	}
+	if err := checkKind(*kind); err != nil {
+		return err
+	}
+
+	reqCtx, cancel := withTimeout(ctx)
+	defer cancel()
+	if *kind == templateKindAgent {
+		err = a.client.DeleteAgentTemplate(reqCtx, pos[0])
+	} else {
+		err = a.client.DeleteTaskTemplate(reqCtx, pos[0])
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(a.stdout, "%s template %s 已删除\n", *kind, pos[0])
+	return nil
+}
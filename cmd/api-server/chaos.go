@@ -0,0 +1,27 @@
+//go:build chaos
+
+package main
+
+import (
+	"log"
+
+	"agents-admin/internal/shared/chaos"
+	"agents-admin/internal/shared/infra"
+	"agents-admin/internal/shared/storage"
+)
+
+// applyChaos 按 CHAOS_* 环境变量包装存储层与消息队列（仅 chaos 构建标签下编译，用于故障演练）
+func applyChaos(store storage.PersistentStore, redisInfra *infra.RedisInfra) storage.PersistentStore {
+	cfg, err := chaos.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid chaos config: %v", err)
+	}
+	if !cfg.Enabled() {
+		return store
+	}
+	inj := chaos.New(cfg)
+	redisInfra.SetQueue(chaos.WrapQueue(redisInfra.Queue(), inj))
+	log.Printf("[chaos.enabled] queue_drop=%g queue_duplicate=%g queue_delay=%s queue_ack_error=%g queue_publish_error=%g db_error=%g db_methods=%v",
+		cfg.QueueDrop, cfg.QueueDuplicate, cfg.QueueDelay, cfg.QueueAckError, cfg.QueuePublishError, cfg.DBError, cfg.DBMethods)
+	return chaos.WrapStore(store, inj)
+}
@@ -0,0 +1,13 @@
+//go:build !chaos
+
+package main
+
+import (
+	"agents-admin/internal/shared/infra"
+	"agents-admin/internal/shared/storage"
+)
+
+// applyChaos 非 chaos 构建不注入故障
+func applyChaos(store storage.PersistentStore, _ *infra.RedisInfra) storage.PersistentStore {
+	return store
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"agents-admin/internal/apiserver/fieldcrypt"
+	"agents-admin/internal/config"
+	"agents-admin/internal/shared/secretbox"
+	"agents-admin/internal/shared/storage"
+)
+
+// newKeyring 根据配置创建敏感字段加密的 KEK 密钥环，未配置 provider 与 MASTER_KEY 时返回 nil（不加密）
+//
+// 未配置 provider 时以 MASTER_KEY 作为本地 KEK；配置 provider 后 MASTER_KEY 作为历史 KEK 保留用于解密。
+// 从本地 KEK 迁移到 Vault 时，ENCRYPTION_KEK 与 ENCRYPTION_KEK_PREVIOUS 中的本地密钥作为历史 KEK 保留用于解密。
+// 配置了 MASTER_KEY 时，迁移前以它直接加密的凭据与密钥（secretbox 密文）同样可以读取。
+func newKeyring(cfg config.EncryptionConfig, masterKey string) (*fieldcrypt.Keyring, error) {
+	var primary fieldcrypt.KEK
+	var err error
+	previous := cfg.PreviousKeys
+	switch cfg.Provider {
+	case "":
+		if masterKey == "" {
+			return nil, nil
+		}
+		primary, err = fieldcrypt.NewLocalKEK(masterKey)
+	case "local":
+		if cfg.Key == "" {
+			return nil, fmt.Errorf("encryption.provider local requires ENCRYPTION_KEK")
+		}
+		primary, err = fieldcrypt.NewLocalKEK(cfg.Key)
+	case "vault":
+		primary, err = fieldcrypt.NewVaultKEK(fieldcrypt.VaultConfig{
+			Address: cfg.Vault.Address,
+			Token:   cfg.Vault.Token,
+			Mount:   cfg.Vault.Mount,
+			KeyName: cfg.Vault.KeyName,
+			Timeout: cfg.Vault.Timeout,
+		})
+		if cfg.Key != "" {
+			previous = append([]string{cfg.Key}, previous...)
+		}
+	default:
+		return nil, fmt.Errorf("unknown encryption.provider %q (local or vault)", cfg.Provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if masterKey != "" {
+		previous = append(previous, masterKey)
+	}
+	keks := make([]fieldcrypt.KEK, 0, len(previous))
+	for _, key := range previous {
+		kek, err := fieldcrypt.NewLocalKEK(key)
+		if err != nil {
+			return nil, err
+		}
+		keks = append(keks, kek)
+	}
+	keys, err := fieldcrypt.NewKeyring(primary, keks...)
+	if err != nil {
+		return nil, err
+	}
+	if masterKey != "" {
+		box, err := secretbox.New(masterKey)
+		if err != nil {
+			return nil, err
+		}
+		keys.SetLegacyBox(box)
+	}
+	return keys, nil
+}
+
+// runReencrypt 执行 --reencrypt-secrets：用当前 KEK 重新加密已有记录的敏感字段后退出
+func runReencrypt(store storage.PersistentStore, keys *fieldcrypt.Keyring, opts fieldcrypt.ReencryptOptions) {
+	if keys == nil {
+		log.Fatal("--reencrypt-secrets requires encryption.provider (local or vault) or MASTER_KEY")
+	}
+	stats, err := fieldcrypt.Reencrypt(context.Background(), store, keys, opts)
+	if err != nil {
+		log.Fatalf("Re-encryption failed after %d proxies, %d operations, %d credentials, %d secrets: %v",
+			stats.Proxies, stats.Operations, stats.Credentials, stats.Secrets, err)
+	}
+	log.Printf("Re-encryption finished: kek=%s proxies=%d operations=%d credentials=%d secrets=%d skipped=%d dry_run=%t",
+		keys.PrimaryID(), stats.Proxies, stats.Operations, stats.Credentials, stats.Secrets, stats.Skipped, opts.DryRun)
+}
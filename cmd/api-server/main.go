@@ -4,6 +4,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
 	"flag"
 	"fmt"
 	"log"
@@ -14,12 +15,32 @@ import (
 	"syscall"
 	"time"
 
+	"agents-admin/internal/apiserver/accountpool"
 	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/apiserver/confreload"
+	"agents-admin/internal/apiserver/eventsink"
+	"agents-admin/internal/apiserver/fieldcrypt"
+	"agents-admin/internal/apiserver/gateway"
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/apiserver/maintenance"
+	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/apiserver/ratelimit"
+	"agents-admin/internal/apiserver/rawoffload"
+	"agents-admin/internal/apiserver/registry"
+	"agents-admin/internal/apiserver/run"
+	"agents-admin/internal/apiserver/scheduler"
 	"agents-admin/internal/apiserver/server"
 	"agents-admin/internal/apiserver/setup"
+	"agents-admin/internal/apiserver/subtask"
+	"agents-admin/internal/apiserver/webhook"
 	"agents-admin/internal/config"
 	"agents-admin/internal/shared/infra"
+	"agents-admin/internal/shared/logging"
 	objstore "agents-admin/internal/shared/minio"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+	_ "agents-admin/internal/shared/queue/nats"
 	"agents-admin/internal/shared/storage"
 	"agents-admin/internal/shared/storage/dbutil"
 	"agents-admin/internal/shared/storage/mongostore"
@@ -27,6 +48,7 @@ import (
 	"agents-admin/web"
 
 	"golang.org/x/crypto/acme/autocert"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -34,8 +56,15 @@ func main() {
 	reconfigure := flag.Bool("reconfigure", false, "强制重新进入配置向导")
 	setupPort := flag.Int("setup-port", 15800, "Setup 向导监听端口")
 	setupListen := flag.String("setup-listen", "0.0.0.0", "Setup 向导监听地址")
+	logLevel := flag.String("log-level", "", "日志级别：debug / info / warn / error（优先于 LOG_LEVEL 与配置文件）")
+	reencrypt := flag.Bool("reencrypt-secrets", false, "用当前 KEK 重新加密已有的代理密码、API Key、Git 凭据与运行时密钥后退出（迁移明文或 MASTER_KEY 密文、轮换 KEK）")
+	reencryptAll := flag.Bool("reencrypt-all", false, "配合 --reencrypt-secrets：重新加密所有字段，包括已由当前 KEK 加密的（Vault 密钥轮换版本后使用）")
+	reencryptDryRun := flag.Bool("reencrypt-dry-run", false, "配合 --reencrypt-secrets：只统计需要重新加密的记录，不写入")
 	flag.Parse()
 
+	if *logLevel != "" {
+		os.Setenv("LOG_LEVEL", *logLevel) // 配置加载与热加载统一按 LOG_LEVEL 覆盖
+	}
 	if *configDir != "" {
 		config.SetConfigDir(*configDir)
 	}
@@ -59,11 +88,14 @@ func main() {
 
 	// 加载配置（自动加载 .env，根据 APP_ENV 切换数据库和 Redis）
 	cfg := config.Load()
+	if err := logging.Setup(logging.Config{Level: cfg.Log.Level, Format: cfg.LogFormat(), Output: cfg.Log.Output, Component: "api-server"}); err != nil {
+		log.Fatalf("Invalid log config: %v", err)
+	}
 
 	log.Printf("Starting API Server... [env=%s]", cfg.Env)
 	log.Printf("Config: %s", cfg.String())
 
-	// 初始化数据库（根据配置自动选择 MongoDB、PostgreSQL 或 SQLite）
+	// 初始化数据库（根据配置自动选择 MongoDB、PostgreSQL、MySQL 或 SQLite）
 	var store storage.PersistentStore
 	var err error
 	if dbutil.DriverType(cfg.DatabaseDriver) == dbutil.DriverMongoDB {
@@ -72,14 +104,48 @@ func main() {
 			log.Fatalf("Failed to connect to MongoDB: %v", err)
 		}
 	} else {
-		store, err = storage.NewPersistentStoreFromDSN(dbutil.DriverType(cfg.DatabaseDriver), cfg.DatabaseURL)
+		pool := dbutil.PoolConfig{
+			MaxOpenConns:           cfg.DatabasePool.MaxOpenConns,
+			MaxIdleConns:           cfg.DatabasePool.MaxIdleConns,
+			ConnMaxLifetime:        cfg.DatabasePool.ConnMaxLifetime,
+			ConnMaxIdleTime:        cfg.DatabasePool.ConnMaxIdleTime,
+			StatementCacheCapacity: cfg.DatabasePool.StatementCacheCapacity,
+		}
+		store, err = storage.NewPersistentStoreWithPool(dbutil.DriverType(cfg.DatabaseDriver), cfg.DatabaseURL, pool)
 		if err != nil {
 			log.Fatalf("Failed to connect to database (%s): %v", cfg.DatabaseDriver, err)
 		}
+		// 只读副本：列表、事件读取与监控聚合读副本，副本不可用或延迟超限时回退主库
+		if cfg.DatabaseReadURL != "" {
+			if err := storage.SetReadReplicaFromDSN(store, dbutil.DriverType(cfg.DatabaseDriver), cfg.DatabaseReadURL, cfg.DatabaseReplicaMaxLag, pool); err != nil {
+				log.Printf("[storage.replica.disabled] error=%v", err)
+			} else {
+				log.Printf("Connected to read replica (%s)", cfg.DatabaseDriver)
+			}
+		}
 	}
 	defer store.Close()
 	log.Printf("Connected to database (%s)", cfg.DatabaseDriver)
 
+	// 敏感字段静态加密：代理密码、API Key 认证配置、Git 凭据与运行时密钥在存储层透明加解密（信封加密）
+	keyring, err := newKeyring(cfg.Encryption, cfg.Auth.MasterKey)
+	if err != nil {
+		log.Fatalf("Invalid encryption config: %v", err)
+	}
+	if *reencrypt {
+		runReencrypt(store, keyring, fieldcrypt.ReencryptOptions{All: *reencryptAll, DryRun: *reencryptDryRun})
+		return
+	}
+	if keyring != nil {
+		provider := cfg.Encryption.Provider
+		if provider == "" {
+			provider = "master_key"
+		}
+		log.Printf("Field encryption enabled: provider=%s kek=%s", provider, keyring.PrimaryID())
+	} else {
+		log.Println("WARNING: Field encryption disabled (no MASTER_KEY or encryption.provider), credentials and secrets cannot be stored")
+	}
+
 	// 初始化 Redis（缓存、事件总线、消息队列）
 	redisInfra, err := infra.NewRedisInfra(cfg.RedisURL)
 	if err != nil {
@@ -88,10 +154,19 @@ func main() {
 	defer redisInfra.Close()
 	log.Println("Connected to Redis")
 
-	// 初始化 Handler（心跳缓存由 Redis 提供，etcd 已弃用）
-	h := server.NewHandler(store, redisInfra)
+	// 消息队列后端：默认复用 Redis Streams；queue.driver 为 nats 时调度队列与节点队列改用 NATS JetStream
+	if cfg.QueueDriver != queue.DriverRedis {
+		q, err := queue.Open(cfg.QueueDriver, cfg.QueueURL)
+		if err != nil {
+			log.Fatalf("Failed to open %s queue: %v", cfg.QueueDriver, err)
+		}
+		defer q.Close()
+		redisInfra.SetQueue(q)
+		log.Printf("Connected to %s queue", cfg.QueueDriver)
+	}
 
-	// 初始化 MinIO 客户端（可选，用于 volume archive）
+	// 初始化 MinIO 客户端（可选，用于 volume archive、Run 事件归档与原始输出转存）
+	var minioClient *objstore.Client
 	if cfg.MinIO.Endpoint != "" && cfg.MinIO.AccessKey != "" {
 		mc, err := objstore.NewClient(cfg.MinIO)
 		if err != nil {
@@ -100,7 +175,7 @@ func main() {
 			if err := mc.EnsureBucket(context.Background()); err != nil {
 				log.Printf("WARNING: Failed to ensure MinIO bucket: %v (volume archive disabled)", err)
 			} else {
-				h.SetMinIOClient(mc)
+				minioClient = mc
 				log.Println("Connected to MinIO object storage")
 			}
 		}
@@ -108,12 +183,67 @@ func main() {
 		log.Println("MinIO not configured, volume archive disabled")
 	}
 
+	// Run 事件存储：超过阈值的 payload 与原始输出压缩存储；原始输出可转存到 MinIO，数据库只保留指针
+	if cfg.DatabaseEvents.CompressThreshold > 0 {
+		if err := storage.SetEventCompression(store, cfg.DatabaseEvents.CompressThreshold); err != nil {
+			log.Printf("[storage.compression.disabled] error=%v", err)
+		}
+	}
+	var eventStore storage.PersistentStore = store
+	if keyring != nil {
+		eventStore = fieldcrypt.WrapStore(store, keyring)
+	}
+	if cfg.DatabaseEvents.OffloadRaw {
+		if minioClient != nil {
+			eventStore = rawoffload.WrapStore(eventStore, minioClient, rawoffload.Config{MinBytes: cfg.DatabaseEvents.OffloadThreshold})
+		} else {
+			log.Println("WARNING: Raw output offload requires MinIO, raw output stays in the database")
+		}
+	}
+
+	// Run 事件镜像（可选）：写入数据库的事件经包装的存储层批量投递到 Kafka
+	handlerStore := eventStore
+	var sink *eventsink.Sink
+	if cfg.EventSink.Enabled {
+		sink = newEventSink(cfg.EventSink)
+		handlerStore = eventsink.WrapStore(handlerStore, sink)
+	}
+
+	// 故障注入（仅 chaos 构建）：调度与执行链路的存储方法与消息队列按 CHAOS_* 配置注入瞬时失败
+	handlerStore = applyChaos(handlerStore, redisInfra)
+
+	// Webhook：Run 创建、状态迁移与事件写入经包装的存储层发布，按订阅过滤条件匹配后投递
+	webhooks := webhook.NewDispatcher(store, webhook.Config{})
+
+	// 初始化 Handler（心跳缓存由 Redis 提供，etcd 已弃用）
+	h := server.NewHandler(webhook.WrapStore(handlerStore, webhooks), redisInfra)
+	h.SetFieldEncryption(keyring != nil)
+	h.SetWebhooks(webhooks)
+	h.SetEventSink(sink)
+	if minioClient != nil {
+		h.SetMinIOClient(minioClient)
+	}
+
 	// 设置认证配置
 	authCfg := server.AuthConfigCompat{
 		JWTSecret:     cfg.Auth.JWTSecret,
 		AdminEmail:    cfg.Auth.AdminEmail,
 		AdminPassword: cfg.Auth.AdminPassword,
 		NodeToken:     cfg.Auth.NodeToken,
+		MasterKey:     cfg.Auth.MasterKey,
+
+		DisableSharedNodeToken: cfg.Auth.DisableSharedNodeToken,
+
+		OIDC: auth.OIDCConfig{
+			Issuer:        cfg.Auth.OIDC.Issuer,
+			ClientID:      cfg.Auth.OIDC.ClientID,
+			ClientSecret:  cfg.Auth.OIDC.ClientSecret,
+			RedirectURL:   cfg.Auth.OIDC.RedirectURL,
+			Scopes:        cfg.Auth.OIDC.Scopes,
+			GroupsClaim:   cfg.Auth.OIDC.GroupsClaim,
+			AdminGroups:   cfg.Auth.OIDC.AdminGroups,
+			AllowedGroups: cfg.Auth.OIDC.AllowedGroups,
+		},
 	}
 	if d, err := time.ParseDuration(cfg.Auth.AccessTokenTTL); err == nil && d > 0 {
 		authCfg.AccessTokenTTL = d
@@ -126,6 +256,19 @@ func main() {
 		authCfg.RefreshTokenTTL = 7 * 24 * time.Hour
 	}
 	h.SetAuthConfig(authCfg)
+	if cfg.Auth.DisableSharedNodeToken {
+		log.Println("Shared NODE_TOKEN disabled: nodes must join with a one-time join token")
+	}
+	if authCfg.OIDC.Enabled() {
+		if authCfg.JWTSecret == "" {
+			log.Println("WARNING: OIDC configured but JWT_SECRET is empty, SSO login is disabled")
+		} else {
+			log.Printf("OIDC SSO enabled: issuer=%s admin_groups=%v", authCfg.OIDC.Issuer, authCfg.OIDC.AdminGroups)
+		}
+	}
+
+	// 节点自动注册：加入令牌换取专属 Token 与客户端证书（证书由 TLS CA 签发）
+	h.SetNodeJoinConfig(nodeJoinConfig(cfg))
 
 	// 设置 Node Manager 引导配置（零配置安装）
 	h.SetBootstrapConfig(server.BootstrapConfig{
@@ -137,13 +280,116 @@ func main() {
 		log.Printf("WARNING: Failed to ensure admin user: %v", err)
 	}
 
+	// Agent 输出内容审核（可选）
+	if cfg.Moderation.Enabled {
+		moderator, err := newModerator(cfg.Moderation)
+		if err != nil {
+			log.Fatalf("Invalid moderation config: %v", err)
+		}
+		h.SetModeration(moderator)
+		log.Printf("Moderation enabled: abort_severity=%q retain_originals=%t", cfg.Moderation.AbortSeverity, cfg.Moderation.RetainOriginals)
+	}
+
+	// 存储维护（定时 VACUUM/incremental_vacuum/compact 与维护历史）
+	maintainer, _ := store.(storage.Maintainer)
+	maintenanceSvc, err := maintenance.NewService(store, maintainer, cfg.DatabaseDriver, maintenance.Config{
+		Enabled:  cfg.Maintenance.Enabled,
+		Window:   cfg.Maintenance.Window,
+		Interval: cfg.Maintenance.Interval,
+		Tables:   cfg.Maintenance.Tables,
+		DryRun:   cfg.Maintenance.DryRun,
+	})
+	if err != nil {
+		log.Fatalf("Invalid maintenance config: %v", err)
+	}
+	h.SetMaintenance(maintenanceSvc)
+
+	// Run 数据分层（hot/warm/cold），事件归档依赖 MinIO
+	if minioClient != nil {
+		h.SetRunLifecycle(lifecycle.NewController(eventStore, minioClient, lifecycle.Config{
+			Enabled:      cfg.Lifecycle.Enabled,
+			Interval:     cfg.Lifecycle.Interval,
+			HotTTL:       cfg.Lifecycle.HotTTL,
+			WarmTTL:      cfg.Lifecycle.WarmTTL,
+			MaxHotEvents: cfg.Lifecycle.MaxHotEvents,
+			MinHotAge:    cfg.Lifecycle.MinHotAge,
+			BatchSize:    cfg.Lifecycle.BatchSize,
+		}))
+	} else if cfg.Lifecycle.Enabled {
+		log.Println("WARNING: Run data lifecycle requires MinIO, tiering disabled")
+	}
+
+	// Agent 网关：OpenAI 兼容接口，每个请求同步执行一次 Run
+	if cfg.Gateway.Enabled {
+		h.SetGateway(gatewayConfig(cfg.Gateway))
+		log.Printf("Agent gateway enabled: %d models", len(cfg.Gateway.Models))
+	}
+
+	// 模板注册表：从远程注册表同步任务模板、Agent 模板与技能
+	if len(cfg.Registry.Sources) > 0 {
+		syncer, err := registry.NewSyncer(store, registryConfig(cfg.Registry))
+		if err != nil {
+			log.Fatalf("Invalid registry config: %v", err)
+		}
+		h.SetRegistry(syncer)
+	}
+
+	h.SetMaxEventBatch(cfg.APIServer.MaxEventBatch)
+	h.SetIdempotencyTTL(cfg.APIServer.IdempotencyTTL)
+	h.SetSubTaskLimits(subtask.Limits{
+		MaxDepth:    cfg.APIServer.SubTasks.MaxDepth,
+		MaxChildren: cfg.APIServer.SubTasks.MaxChildren,
+	})
+	if err := h.SetEventSchemaMode(cfg.APIServer.EventSchemaMode); err != nil {
+		log.Fatalf("Invalid api_server.event_schema_mode: %v", err)
+	}
+	if err := h.SetBodyLimits(bodyLimits(cfg.APIServer.BodyLimits)); err != nil {
+		log.Fatalf("Invalid api_server.body_limits: %v", err)
+	}
+	if rl := cfg.APIServer.RateLimit; rl.Enabled {
+		h.SetRateLimit(ratelimit.Config{
+			IP:                ratelimit.Bucket{Rate: rl.IP.Rate, Burst: rl.IP.Burst},
+			User:              ratelimit.Bucket{Rate: rl.User.Rate, Burst: rl.User.Burst},
+			Login:             ratelimit.Bucket{Rate: rl.Login.Rate, Burst: rl.Login.Burst},
+			MaxLoginFailures:  rl.MaxLoginFailures,
+			FailureWindow:     rl.FailureWindow,
+			Lockout:           rl.Lockout,
+			TrustForwardedFor: rl.TrustForwardedFor,
+		})
+		log.Printf("Rate limiting enabled: ip=%g/s user=%g/s login=%g/s max_login_failures=%d", rl.IP.Rate, rl.User.Rate, rl.Login.Rate, rl.MaxLoginFailures)
+	}
+
 	// 启动调度器
+	applyReloadable(h, cfg)
+	h.SetSchedulerSharding(cfg.Scheduler.Sharding.Workers, cfg.Scheduler.Sharding.Slots)
+	reloader := confreload.New(cfg, func(next *config.Config) { applyReloadable(h, next) })
+	h.SetConfigReloader(reloader)
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	go h.StartScheduler(ctx)
+	go h.StartOutbox(ctx)
+	go h.StartIdempotencyCleanup(ctx)
+	go h.StartWorkflowOrchestrator(ctx)
+	go h.StartRunWatchdog(ctx, cfg.Scheduler.Watchdog.Interval, cfg.Scheduler.Watchdog.DefaultTimeout)
+	go h.StartRunReconciler(ctx, cfg.Scheduler.Reconcile.Interval, run.ReconcileConfig{
+		HeartbeatInterval: cfg.Scheduler.Reconcile.HeartbeatInterval,
+		MissedHeartbeats:  cfg.Scheduler.Reconcile.MissedHeartbeats,
+		RequeueStarted:    cfg.Scheduler.Reconcile.RequeueStarted,
+	})
+	go h.StartMaintenance(ctx)
+	go h.StartRunLifecycle(ctx)
+	go h.StartTerminalExpiry(ctx)
+	go h.StartWebhooks(ctx)
+	sinkDone := make(chan struct{})
+	go func() {
+		h.StartEventSink(ctx)
+		close(sinkDone)
+	}()
+	go h.StartRegistry(ctx)
 
 	// 确定最终 handler：生产模式嵌入前端，开发模式反向代理到 Next.js
 	var handler http.Handler = h.Router()
+	go reloader.Watch(ctx, cfg.APIServer.ConfigWatchInterval) // 在 Router 之后启动：热加载会调整 Router 中创建的认证处理器
 	if web.IsEmbedded() {
 		staticFS, err := web.StaticFS()
 		if err != nil {
@@ -170,6 +416,47 @@ func main() {
 		ErrorLog:     newTLSFilteredLogger(),
 	}
 
+	// Agent 网关独立监听（供内部工具直连，不暴露管理 API 与前端）
+	var gatewaySrv *http.Server
+	if gw := h.GatewayRouter(); gw != nil && cfg.Gateway.Listen != "" {
+		gatewaySrv = &http.Server{
+			Addr:              cfg.Gateway.Listen,
+			Handler:           gw,
+			ReadHeaderTimeout: 15 * time.Second,
+			IdleTimeout:       60 * time.Second,
+		}
+		go func() {
+			log.Printf("Agent gateway listening on %s (HTTP)", cfg.Gateway.Listen)
+			if err := gatewaySrv.ListenAndServe(); err != http.ErrServerClosed {
+				log.Fatalf("Gateway server error: %v", err)
+			}
+		}()
+	}
+
+	// 证书准备：ACME 证书管理器与自签名证书由主端口与节点 gRPC 接口共用
+	var acmeManager *autocert.Manager
+	if cfg.TLS.Enabled && cfg.TLS.ACME.Enabled {
+		acmeManager = newACMEManager(cfg.TLS.ACME)
+	} else if cfg.TLS.Enabled {
+		ensureSelfSignedCerts(cfg)
+	}
+
+	// 节点 gRPC 接口独立监听（与 REST 节点接口并存，Node Manager 通过 transport 配置选择）
+	var grpcSrv *grpc.Server
+	if cfg.APIServer.GRPCListen != "" {
+		grpcSrv = h.NodeGRPCServer(nodeGRPCTLSConfig(cfg, acmeManager))
+		lis, err := net.Listen("tcp", cfg.APIServer.GRPCListen)
+		if err != nil {
+			log.Fatalf("Failed to listen on %s: %v", cfg.APIServer.GRPCListen, err)
+		}
+		go func() {
+			log.Printf("Node gRPC API listening on %s (TLS: %v)", cfg.APIServer.GRPCListen, cfg.TLS.Enabled)
+			if err := grpcSrv.Serve(lis); err != nil {
+				log.Fatalf("Node gRPC server error: %v", err)
+			}
+		}()
+	}
+
 	// 优雅关闭
 	go func() {
 		sigChan := make(chan os.Signal, 1)
@@ -180,6 +467,24 @@ func main() {
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
 
+		if gatewaySrv != nil {
+			if err := gatewaySrv.Shutdown(ctx); err != nil {
+				log.Printf("Gateway shutdown error: %v", err)
+			}
+		}
+		if grpcSrv != nil {
+			// WatchAssignedRuns 长连接不会自行结束，超时后强制关闭
+			stopped := make(chan struct{})
+			go func() {
+				grpcSrv.GracefulStop()
+				close(stopped)
+			}()
+			select {
+			case <-stopped:
+			case <-time.After(5 * time.Second):
+				grpcSrv.Stop()
+			}
+		}
 		if err := srv.Shutdown(ctx); err != nil {
 			log.Printf("Server shutdown error: %v", err)
 		}
@@ -190,7 +495,7 @@ func main() {
 	// ============================================================
 	if cfg.TLS.Enabled && cfg.TLS.ACME.Enabled {
 		// 模式 A：ACME / Let's Encrypt（互联网域名自动证书）
-		startWithACME(srv, cfg)
+		startWithACME(srv, cfg, acmeManager)
 	} else if cfg.TLS.Enabled {
 		// 模式 B：自签名证书（内网 IP 访问）
 		startWithSelfSignedTLS(srv, cfg)
@@ -202,11 +507,107 @@ func main() {
 		}
 	}
 
+	// 停止后台任务，等待事件镜像投递缓冲中的剩余事件（失败时写入死信文件）
+	cancel()
+	select {
+	case <-sinkDone:
+	case <-time.After(15 * time.Second):
+		log.Println("Event sink drain timed out")
+	}
+
 	fmt.Println("Server stopped")
 }
 
-// startWithSelfSignedTLS 自签名证书模式（本地开发 / 内网）
-func startWithSelfSignedTLS(srv *http.Server, cfg *config.Config) {
+// newEventSink 根据配置创建 Run 事件镜像投递器（Kafka 配置无效时退出）
+func newEventSink(c config.EventSinkConfig) *eventsink.Sink {
+	producer, err := eventsink.NewKafkaProducer(eventsink.KafkaConfig{
+		Brokers:       c.Kafka.Brokers,
+		Topic:         c.Kafka.Topic,
+		ClientID:      c.Kafka.ClientID,
+		TLS:           c.Kafka.TLS,
+		SASLMechanism: c.Kafka.SASL.Mechanism,
+		SASLUsername:  c.Kafka.SASL.Username,
+		SASLPassword:  c.Kafka.SASL.Password,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create event sink: %v", err)
+	}
+	log.Printf("Event sink enabled: kafka topic=%s brokers=%v", c.Kafka.Topic, c.Kafka.Brokers)
+	return eventsink.New(producer, eventsink.Config{
+		BufferSize:     c.BufferSize,
+		BatchSize:      c.BatchSize,
+		FlushInterval:  c.FlushInterval,
+		DeadLetterPath: c.DeadLetterPath,
+		ReplayInterval: c.ReplayInterval,
+	})
+}
+
+// gatewayConfig 将配置文件中的网关配置转换为 gateway.Config
+func gatewayConfig(c config.GatewayConfig) gateway.Config {
+	gc := gateway.Config{Timeout: c.Timeout, PollInterval: c.PollInterval, MaxConcurrent: c.MaxConcurrent}
+	for _, m := range c.Models {
+		gc.Models = append(gc.Models, gateway.Model{
+			Name:           m.Name,
+			Description:    m.Description,
+			AgentType:      m.AgentType,
+			AgentID:        m.AgentID,
+			ProjectID:      m.ProjectID,
+			Labels:         m.Labels,
+			TimeoutSeconds: m.TimeoutSeconds,
+		})
+	}
+	return gc
+}
+
+// registryConfig 将配置文件中的注册表配置转换为 registry.Config
+func registryConfig(c config.RegistryConfig) registry.Config {
+	rc := registry.Config{Enabled: c.Enabled, Interval: c.Interval, Timeout: c.Timeout}
+	for _, src := range c.Sources {
+		rc.Sources = append(rc.Sources, registry.Source{
+			Name:  src.Name,
+			Type:  src.Type,
+			URL:   src.URL,
+			Ref:   src.Ref,
+			Index: src.Index,
+		})
+	}
+	return rc
+}
+
+// bodyLimits 将配置文件中的请求体上限（MB）转换为各路由组的字节数（负数表示不限制）
+func bodyLimits(c config.BodyLimitsConfig) map[string]int64 {
+	limits := make(map[string]int64, len(c.GroupsMB)+1)
+	limits[bodylimit.GroupDefault] = c.DefaultMB << 20
+	for group, mb := range c.GroupsMB {
+		limits[group] = mb << 20
+	}
+	return limits
+}
+
+// nodeJoinConfig 节点自动注册配置：自签名模式下 CA 路径与 startWithSelfSignedTLS 自动生成的一致
+func nodeJoinConfig(cfg *config.Config) node.JoinConfig {
+	joinCfg := node.JoinConfig{CAFile: cfg.TLS.CAFile, CAKeyFile: cfg.TLS.CAKeyFile}
+	if cfg.TLS.Enabled && !cfg.TLS.ACME.Enabled && cfg.TLS.AutoGenerate {
+		certDir := cfg.TLS.CertDir
+		if certDir == "" {
+			certDir = tlsutil.DefaultGenerateOptions().CertDir
+		}
+		files := tlsutil.DefaultCertFiles(certDir)
+		if joinCfg.CAFile == "" {
+			joinCfg.CAFile = files.CAFile
+		}
+		if joinCfg.CAKeyFile == "" {
+			joinCfg.CAKeyFile = files.CAKeyFile
+		}
+	}
+	if d, err := time.ParseDuration(cfg.Auth.NodeCertValidity); err == nil && d > 0 {
+		joinCfg.CertValidity = d
+	}
+	return joinCfg
+}
+
+// ensureSelfSignedCerts 自签名模式下按需自动生成证书，并将生成的路径回填到配置
+func ensureSelfSignedCerts(cfg *config.Config) {
 	if cfg.TLS.AutoGenerate {
 		opts := tlsutil.DefaultGenerateOptions()
 		if cfg.TLS.CertDir != "" {
@@ -229,10 +630,53 @@ func startWithSelfSignedTLS(srv *http.Server, cfg *config.Config) {
 			cfg.TLS.CAFile = certs.CAFile
 		}
 	}
+}
 
+// clientCAPool 读取签发节点客户端证书的 CA（文件不存在或无效时返回 nil，不启用 mTLS）
+func clientCAPool(caFile string) *x509.CertPool {
+	caPEM, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil
+	}
+	return pool
+}
+
+// nodeGRPCTLSConfig 节点 gRPC 接口的 TLS 配置：与主端口共用服务端证书，节点可选地出示客户端证书（mTLS）
+//
+// 未启用 TLS 时返回 nil（明文 gRPC）。
+func nodeGRPCTLSConfig(cfg *config.Config, acmeManager *autocert.Manager) *tls.Config {
+	if !cfg.TLS.Enabled {
+		return nil
+	}
+	if acmeManager != nil {
+		return &tls.Config{GetCertificate: acmeManager.GetCertificate}
+	}
+	cert, err := tls.LoadX509KeyPair(cfg.TLS.CertFile, cfg.TLS.KeyFile)
+	if err != nil {
+		log.Fatalf("Failed to load TLS cert for node gRPC API: %v", err)
+	}
+	tlsCfg := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if pool := clientCAPool(cfg.TLS.CAFile); pool != nil {
+		tlsCfg.ClientCAs = pool
+		tlsCfg.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsCfg
+}
+
+// startWithSelfSignedTLS 自签名证书模式（本地开发 / 内网），证书已由 ensureSelfSignedCerts 准备
+func startWithSelfSignedTLS(srv *http.Server, cfg *config.Config) {
 	// 注入 /ca.pem 端点，供客户端下载并信任 CA 证书
 	srv.Handler = withCACertEndpoint(srv.Handler, cfg.TLS.CAFile)
 
+	// 节点可选地出示由该 CA 签发的客户端证书（mTLS），由认证中间件校验是否被撤销
+	if pool := clientCAPool(cfg.TLS.CAFile); pool != nil {
+		srv.TLSConfig = &tls.Config{ClientCAs: pool, ClientAuth: tls.VerifyClientCertIfGiven}
+	}
+
 	log.Printf("API Server listening on :%s (TLS, self-signed)", cfg.APIPort)
 	log.Printf("  cert: %s", cfg.TLS.CertFile)
 	log.Printf("  key:  %s", cfg.TLS.KeyFile)
@@ -248,21 +692,23 @@ func startWithSelfSignedTLS(srv *http.Server, cfg *config.Config) {
 	}
 }
 
-// startWithACME Let's Encrypt 自动证书模式（互联网域名）
-func startWithACME(srv *http.Server, cfg *config.Config) {
-	acmeCfg := cfg.TLS.ACME
+// newACMEManager 创建 Let's Encrypt 证书管理器
+func newACMEManager(acmeCfg config.ACMEConfig) *autocert.Manager {
 	cacheDir := acmeCfg.CacheDir
 	if cacheDir == "" {
 		cacheDir = "/etc/agents-admin/certs/acme"
 	}
-
-	m := &autocert.Manager{
+	return &autocert.Manager{
 		Prompt:     autocert.AcceptTOS,
 		HostPolicy: autocert.HostWhitelist(acmeCfg.Domains...),
 		Cache:      autocert.DirCache(cacheDir),
 		Email:      acmeCfg.Email,
 	}
+}
 
+// startWithACME Let's Encrypt 自动证书模式（互联网域名）
+func startWithACME(srv *http.Server, cfg *config.Config, m *autocert.Manager) {
+	acmeCfg := cfg.TLS.ACME
 	srv.TLSConfig = &tls.Config{
 		GetCertificate: m.GetCertificate,
 		NextProtos:     []string{"h2", "http/1.1", "acme-tls/1"},
@@ -295,3 +741,35 @@ func redirectToHTTPS() http.Handler {
 		http.Redirect(w, r, target, http.StatusMovedPermanently)
 	})
 }
+
+// applyReloadable 应用可热加载的配置项（启动时与每次配置热加载后调用，见 config.ReloadableKeys）
+func applyReloadable(h *server.Handler, cfg *config.Config) {
+	h.SetSchedulerStrategy(scheduler.StrategyConfig{
+		Default:     cfg.Scheduler.Strategy.Default,
+		Chain:       cfg.Scheduler.Strategy.Chain,
+		LabelMatch:  scheduler.LabelMatchConfig{LoadBalance: cfg.Scheduler.Strategy.LabelMatch.LoadBalance},
+		LeastLoaded: scheduler.LeastLoadedConfig{Window: min(cfg.Scheduler.Strategy.LeastLoaded.Window, node.MetricsRetention)},
+	})
+	h.SetSchedulerPreemption(cfg.Scheduler.Preemption.Enabled)
+	accountLimits := make(map[string]model.AccountLimit, len(cfg.Scheduler.Accounts.Limits))
+	for key, l := range cfg.Scheduler.Accounts.Limits {
+		accountLimits[key] = model.AccountLimit{MaxConcurrent: l.MaxConcurrent, DailyRequests: l.DailyRequests}
+	}
+	h.SetAccountPool(accountpool.Config{
+		Limits:       accountLimits,
+		Cooldown:     cfg.Scheduler.Accounts.Cooldown,
+		MaxFailovers: cfg.Scheduler.Accounts.MaxFailovers,
+	})
+	h.SetRunWatchdogTimeout(cfg.Scheduler.Watchdog.DefaultTimeout)
+
+	// 无效或未配置的有效期保持当前值（启动时为 SetAuthConfig 设置的默认值）
+	access, _ := time.ParseDuration(cfg.Auth.AccessTokenTTL)
+	refresh, _ := time.ParseDuration(cfg.Auth.RefreshTokenTTL)
+	h.SetTokenTTL(access, refresh)
+
+	if l, err := logging.ParseLevel(cfg.Log.Level); err != nil {
+		log.Printf("[config.reload.log_level.invalid] error=%v", err)
+	} else {
+		logging.SetLevel(l)
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"agents-admin/internal/apiserver/moderation"
+	"agents-admin/internal/config"
+	"agents-admin/internal/shared/model"
+)
+
+// newModerator 根据配置创建 Agent 输出内容审核处理器
+func newModerator(cfg config.ModerationConfig) (*moderation.Processor, error) {
+	custom := make([]moderation.CustomDetector, 0, len(cfg.Custom))
+	for _, rule := range cfg.Custom {
+		custom = append(custom, moderation.CustomDetector{
+			Name:     rule.Name,
+			Pattern:  rule.Pattern,
+			Severity: model.ModerationSeverity(rule.Severity),
+		})
+	}
+	return moderation.New(moderation.Config{
+		Detectors:        cfg.Detectors,
+		Custom:           custom,
+		AbortSeverity:    model.ModerationSeverity(cfg.AbortSeverity),
+		EntropyMinLength: cfg.EntropyMinLength,
+		EntropyThreshold: cfg.EntropyThreshold,
+		RetainOriginals:  cfg.RetainOriginals,
+	})
+}
@@ -0,0 +1,329 @@
+// Package main 事件回放工具（Adapter 开发用）
+//
+// 将已存储 Run 的原始输出逐行送入 Adapter.ParseEvent，
+// 用历史真实输出校验解析器改动，无需重新执行任务。
+//
+// 输入来源（二选一）：
+//   - 本地文件或标准输入：-input run.log（"-" 表示标准输入）
+//   - API Server 导出：-server http://localhost:8080 -run <run_id>
+//     （GET /api/v1/runs/{id}/events/raw，响应头 X-Agent-Type 用于推断适配器；
+//     没有内置适配器的自定义类型按 GET /api/v1/agent-types/{id} 的通用适配器配置回放）
+//
+// 比对模式（-compare，需配合 -server/-run）：
+// 拉取 Run 已存储的事件，与回放结果逐条比对事件类型，报告差异。
+//
+// 用法示例：
+//
+//	event-replay -server https://localhost:8080 -insecure -run run-123 -token $JWT -compare
+//	event-replay -adapter claude-v1 -input run.log -v
+//
+// 退出码：存在解析错误或比对差异时返回 1，参数或网络错误返回 2。
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"agents-admin/internal/nodemanager"
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/nodemanager/adapter/aider"
+	"agents-admin/internal/nodemanager/adapter/claude"
+	"agents-admin/internal/nodemanager/adapter/gemini"
+	"agents-admin/internal/nodemanager/adapter/generic"
+	"agents-admin/internal/nodemanager/adapter/qwencode"
+	"agents-admin/internal/shared/model"
+)
+
+// eventPageSize 拉取已存储事件时的分页大小（与 GET /events 上限一致）
+const eventPageSize = 1000
+
+// replayResult 单行回放结果
+type replayResult struct {
+	Line  int                     // 行号（从 1 开始）
+	Event *adapter.CanonicalEvent // 解析出的事件，非事件行为 nil
+	Err   error                   // 解析错误
+}
+
+// client 访问 API Server 的最小客户端
+type client struct {
+	baseURL   string
+	token     string // 用户 JWT（Authorization: Bearer）
+	nodeToken string // 节点专属 Token（X-Node-Token，只能读取分配给该节点的 Run）
+	http      *http.Client
+}
+
+func main() {
+	adapterName := flag.String("adapter", "", "适配器名称（qwencode-v1 / gemini-v1 / claude-v1 / aider-v1），留空时按 Run 的 Agent 类型推断")
+	input := flag.String("input", "", "原始输出文件路径，\"-\" 表示标准输入")
+	server := flag.String("server", "", "API Server 地址（如 http://localhost:8080）")
+	runID := flag.String("run", "", "要回放的 Run ID（配合 -server）")
+	token := flag.String("token", os.Getenv("AGENTS_ADMIN_TOKEN"), "用户 JWT（默认读取 AGENTS_ADMIN_TOKEN）")
+	nodeToken := flag.String("node-token", os.Getenv("NODE_TOKEN"), "节点专属 Token，只能读取分配给该节点的 Run；共享密钥无权访问 Run（默认读取 NODE_TOKEN）")
+	compare := flag.Bool("compare", false, "与 Run 已存储的事件逐条比对事件类型")
+	verbose := flag.Bool("v", false, "逐行输出解析出的事件（JSON）")
+	insecure := flag.Bool("insecure", false, "跳过 TLS 证书校验（开发环境自签名证书）")
+	flag.Parse()
+
+	registry := adapter.NewRegistry()
+	registry.Register(qwencode.New())
+	registry.Register(gemini.New())
+	registry.Register(claude.New())
+	registry.Register(aider.New())
+
+	var c *client
+	if *server != "" {
+		httpClient := &http.Client{Timeout: 30 * time.Second}
+		if *insecure {
+			httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+		}
+		c = &client{baseURL: *server, token: *token, nodeToken: *nodeToken, http: httpClient}
+	}
+
+	// 读取回放输入
+	var (
+		data      []byte
+		agentType string
+		err       error
+	)
+	switch {
+	case *input != "":
+		data, err = readInput(*input)
+	case c != nil && *runID != "":
+		data, agentType, err = c.exportRaw(*runID)
+	default:
+		fatalf("必须指定 -input 或 -server 与 -run")
+	}
+	if err != nil {
+		fatalf("读取回放输入失败: %v", err)
+	}
+
+	// 选择适配器：显式指定优先，否则按导出的 Agent 类型推断
+	name := *adapterName
+	if name == "" && agentType != "" {
+		name = nodemanager.NormalizeAdapterName(agentType)
+	}
+	if name == "" {
+		fatalf("无法推断适配器，请通过 -adapter 指定（可选: %v）", registry.List())
+	}
+	a, ok := registry.Get(name)
+	if !ok && c != nil && agentType != "" && *adapterName == "" {
+		// 自定义 Agent 类型：按 API Server 上的通用适配器配置回放
+		a, err = c.genericAdapter(agentType)
+		if err != nil {
+			fatalf("构造通用适配器失败: %v", err)
+		}
+		ok = a != nil
+	}
+	if !ok {
+		fatalf("找不到适配器: %s（可选: %v）", name, registry.List())
+	}
+
+	results, err := replay(bytes.NewReader(data), a)
+	if err != nil {
+		fatalf("读取原始输出失败: %v", err)
+	}
+
+	byType := map[adapter.EventType]int{}
+	var events []*adapter.CanonicalEvent
+	ignored, errs := 0, 0
+	for _, res := range results {
+		switch {
+		case res.Err != nil:
+			errs++
+			fmt.Fprintf(os.Stderr, "第 %d 行解析失败: %v\n", res.Line, res.Err)
+		case res.Event == nil:
+			ignored++
+		default:
+			byType[res.Event.Type]++
+			events = append(events, res.Event)
+			if *verbose {
+				b, _ := json.Marshal(res.Event)
+				fmt.Printf("%d\t%s\n", res.Line, b)
+			}
+		}
+	}
+	failed := errs > 0
+
+	fmt.Printf("适配器: %s\n", a.Name())
+	fmt.Printf("输入行数: %d, 事件: %d, 忽略: %d, 解析错误: %d\n", len(results), len(events), ignored, errs)
+	types := make([]string, 0, len(byType))
+	for t := range byType {
+		types = append(types, string(t))
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Printf("  %-20s %d\n", t, byType[adapter.EventType(t)])
+	}
+
+	if *compare {
+		if c == nil || *runID == "" {
+			fatalf("-compare 需要同时指定 -server 与 -run")
+		}
+		stored, err := c.storedEventTypes(*runID)
+		if err != nil {
+			fatalf("获取已存储事件失败: %v", err)
+		}
+		if diffs := compareTypes(stored, events); len(diffs) > 0 {
+			failed = true
+			fmt.Printf("比对差异: %d 处\n", len(diffs))
+			for _, d := range diffs {
+				fmt.Println("  " + d)
+			}
+		} else {
+			fmt.Printf("比对一致: %d 个事件\n", len(stored))
+		}
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// replay 逐行读取原始输出并调用 Adapter.ParseEvent
+//
+// 缓冲区设置与 NodeManager.streamOutput 一致，保证大行行为相同。
+func replay(r io.Reader, a adapter.Adapter) ([]replayResult, error) {
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, 64*1024)
+	scanner.Buffer(buf, 1024*1024)
+
+	var results []replayResult
+	for n := 1; scanner.Scan(); n++ {
+		event, err := a.ParseEvent(scanner.Text())
+		results = append(results, replayResult{Line: n, Event: event, Err: err})
+	}
+	return results, scanner.Err()
+}
+
+// compareTypes 逐条比对已存储事件类型与回放事件类型
+//
+// 导出接口只输出带原始输出的事件，且 NodeManager 每行最多产生一个事件，
+// 因此两侧按顺序一一对应。
+func compareTypes(stored []string, replayed []*adapter.CanonicalEvent) []string {
+	var diffs []string
+	for i := 0; i < len(stored) || i < len(replayed); i++ {
+		switch {
+		case i >= len(stored):
+			diffs = append(diffs, fmt.Sprintf("#%d: 已存储 <无>, 回放 %s", i+1, replayed[i].Type))
+		case i >= len(replayed):
+			diffs = append(diffs, fmt.Sprintf("#%d: 已存储 %s, 回放 <无>", i+1, stored[i]))
+		case stored[i] != string(replayed[i].Type):
+			diffs = append(diffs, fmt.Sprintf("#%d: 已存储 %s, 回放 %s", i+1, stored[i], replayed[i].Type))
+		}
+	}
+	return diffs
+}
+
+// readInput 读取本地文件，"-" 表示标准输入
+func readInput(path string) ([]byte, error) {
+	if path == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(path)
+}
+
+// exportRaw 通过 GET /api/v1/runs/{id}/events/raw 导出原始输出
+func (c *client) exportRaw(runID string) ([]byte, string, error) {
+	resp, err := c.get("/api/v1/runs/" + url.PathEscape(runID) + "/events/raw")
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("HTTP %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	return body, resp.Header.Get("X-Agent-Type"), nil
+}
+
+// genericAdapter 通过 GET /api/v1/agent-types/{id} 获取自定义类型并构造通用适配器（未配置时返回 nil）
+func (c *client) genericAdapter(agentType string) (adapter.Adapter, error) {
+	resp, err := c.get("/api/v1/agent-types/" + url.PathEscape(agentType))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	var at model.AgentTypeConfig
+	if err := json.NewDecoder(resp.Body).Decode(&at); err != nil {
+		return nil, err
+	}
+	if at.Adapter == nil {
+		return nil, nil
+	}
+	return generic.New(at.ID, at.Image, *at.Adapter)
+}
+
+// storedEventTypes 分页拉取 Run 已存储事件，返回带原始输出事件的类型序列
+func (c *client) storedEventTypes(runID string) ([]string, error) {
+	var types []string
+	fromSeq := 0
+	for {
+		resp, err := c.get("/api/v1/runs/" + url.PathEscape(runID) + "/events?from_seq=" +
+			strconv.Itoa(fromSeq) + "&limit=" + strconv.Itoa(eventPageSize))
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Events []struct {
+				Seq  int     `json:"seq"`
+				Type string  `json:"type"`
+				Raw  *string `json:"raw"`
+			} `json:"events"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("HTTP %d", resp.StatusCode)
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range page.Events {
+			if e.Raw != nil && *e.Raw != "" {
+				types = append(types, e.Type)
+			}
+			fromSeq = e.Seq
+		}
+		if len(page.Events) < eventPageSize {
+			return types, nil
+		}
+	}
+}
+
+// get 发送带认证头的 GET 请求
+func (c *client) get(path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.nodeToken != "" {
+		req.Header.Set("X-Node-Token", c.nodeToken)
+	}
+	return c.http.Do(req)
+}
+
+// fatalf 输出错误并以退出码 2 退出
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "event-replay: "+format+"\n", args...)
+	os.Exit(2)
+}
@@ -0,0 +1,360 @@
+// Package main 调度与事件管道压测工具
+//
+// 对运行中的 API Server 施压：注册一批模拟节点（心跳、轮询分配的 Run、按脚本上报事件、回报完成），
+// 并发创建任务与 Run，统计调度延迟 p50/p99、事件入库吞吐与调度队列未确认消息数增长，
+// 超出阈值时以退出码 1 退出，可直接作为 CI 门禁。
+//
+// 模拟节点与生成的任务带同一个 loadgen 标签，调度器只会把压测 Run 分配给模拟节点。
+// 调度延迟取服务端时间戳（Run 的 created_at 到被分配时的 updated_at），不受轮询间隔影响；
+// 队列指标来自 GET /api/v1/admin/overview（需要管理员令牌），不可用时跳过该项。
+//
+// 用法示例：
+//
+//	loadgen -server https://localhost:8080 -insecure -token $JWT -node-token $NODE_TOKEN \
+//	    -runs 2000 -nodes 50 -events 40 -max-p99 5s -min-events-per-sec 2000
+//
+// 退出码：超出阈值返回 1，参数或网络错误返回 2。
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agents-admin/internal/shared/loadtest"
+)
+
+// maxLoggedErrors 逐条输出的错误上限（之后只计数）
+const maxLoggedErrors = 20
+
+// options 压测参数
+type options struct {
+	runs              int
+	nodes             int
+	nodeConcurrency   int
+	events            int
+	eventBatch        int
+	createConcurrency int
+	poll              time.Duration
+	agentType         string
+}
+
+// state 压测过程中的共享统计
+type state struct {
+	schedule  loadtest.Latencies
+	pending   loadtest.Gauge
+	events    atomic.Int64
+	errors    atomic.Int64
+	completed atomic.Int64
+
+	mu          sync.Mutex
+	eventsStart time.Time
+	eventsEnd   time.Time
+}
+
+// eventWindow 记录事件上报阶段的起止时间
+func (s *state) eventWindow(start, end time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.eventsStart.IsZero() || start.Before(s.eventsStart) {
+		s.eventsStart = start
+	}
+	if end.After(s.eventsEnd) {
+		s.eventsEnd = end
+	}
+}
+
+// fail 记录一次错误，前 maxLoggedErrors 条输出到标准错误
+func (s *state) fail(format string, args ...interface{}) {
+	if n := s.errors.Add(1); n <= maxLoggedErrors {
+		fmt.Fprintf(os.Stderr, "loadgen: "+format+"\n", args...)
+	}
+}
+
+func main() {
+	server := flag.String("server", "http://localhost:8080", "API Server 地址")
+	token := flag.String("token", os.Getenv("AGENTS_ADMIN_TOKEN"), "用户 JWT，创建任务与读取队列指标（默认读取 AGENTS_ADMIN_TOKEN）")
+	nodeToken := flag.String("node-token", os.Getenv("NODE_TOKEN"), "NodeManager 共享密钥（默认读取 NODE_TOKEN）")
+	insecure := flag.Bool("insecure", false, "跳过 TLS 证书校验（开发环境自签名证书）")
+
+	var opts options
+	flag.IntVar(&opts.runs, "runs", 1000, "创建的 Run 数")
+	flag.IntVar(&opts.nodes, "nodes", 20, "模拟节点数")
+	flag.IntVar(&opts.nodeConcurrency, "node-concurrency", 10, "每个模拟节点的并发执行数（心跳上报的 max_concurrent）")
+	flag.IntVar(&opts.events, "events", 20, "每个 Run 上报的事件数")
+	flag.IntVar(&opts.eventBatch, "event-batch", 20, "每次上报的事件数")
+	flag.IntVar(&opts.createConcurrency, "create-concurrency", 16, "并发创建任务的协程数")
+	flag.DurationVar(&opts.poll, "poll", 200*time.Millisecond, "模拟节点轮询分配 Run 的间隔")
+	flag.StringVar(&opts.agentType, "agent-type", "claude", "任务的 Agent 类型（只写入快照，模拟节点不执行）")
+	timeout := flag.Duration("timeout", 10*time.Minute, "等待所有 Run 完成的最长时间")
+	cleanup := flag.Bool("cleanup", true, "结束后删除生成的任务与模拟节点")
+	jsonOut := flag.Bool("json", false, "以 JSON 输出报告")
+
+	var th loadtest.Thresholds
+	flag.DurationVar(&th.MaxScheduleP50, "max-p50", 0, "调度延迟 p50 上限（0 不检查）")
+	flag.DurationVar(&th.MaxScheduleP99, "max-p99", 0, "调度延迟 p99 上限（0 不检查）")
+	flag.Float64Var(&th.MinEventsPerSec, "min-events-per-sec", 0, "事件入库吞吐下限（0 不检查）")
+	flag.Int64Var(&th.MaxPendingGrowth, "max-pending-growth", 0, "调度队列未确认消息数增长上限（0 不检查）")
+	flag.BoolVar(&th.Strict, "strict", true, "要求所有 Run 被调度、执行完成且没有错误")
+	flag.Parse()
+
+	if opts.runs <= 0 || opts.nodes <= 0 || opts.nodeConcurrency <= 0 || opts.eventBatch <= 0 || opts.createConcurrency <= 0 {
+		fatalf("-runs、-nodes、-node-concurrency、-event-batch、-create-concurrency 必须为正数")
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxIdleConnsPerHost = opts.nodes*opts.nodeConcurrency + opts.createConcurrency
+	if *insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	httpClient.Transport = transport
+	c := &client{baseURL: *server, token: *token, nodeToken: *nodeToken, http: httpClient}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	tag := "lg-" + randomHex(4)
+	st := &state{}
+	fmt.Fprintf(os.Stderr, "loadgen: tag=%s runs=%d nodes=%d node_concurrency=%d events=%d\n",
+		tag, opts.runs, opts.nodes, opts.nodeConcurrency, opts.events)
+
+	// 1. 模拟节点上线（首次心跳同步完成，保证创建 Run 时节点已可调度）
+	nodes := make([]*simNode, opts.nodes)
+	for i := range nodes {
+		nodes[i] = newSimNode(fmt.Sprintf("%s-node-%03d", tag, i), tag, c, st, opts)
+		if err := nodes[i].heartbeat(ctx); err != nil {
+			fatalf("节点 %s 心跳失败: %v", nodes[i].id, err)
+		}
+	}
+	nodeCtx, stopNodes := context.WithCancel(ctx)
+	var nodeWG sync.WaitGroup
+	for _, n := range nodes {
+		nodeWG.Add(1)
+		go func() {
+			defer nodeWG.Done()
+			n.run(nodeCtx)
+		}()
+	}
+	go samplePending(nodeCtx, c, st)
+
+	// 2. 并发创建任务与 Run
+	start := time.Now()
+	taskIDs, created := createRuns(ctx, c, st, opts, tag)
+	fmt.Fprintf(os.Stderr, "loadgen: created %d runs in %s\n", created, time.Since(start).Round(time.Millisecond))
+
+	// 3. 等待所有 Run 执行完成
+	deadline := time.After(*timeout)
+	ticker := time.NewTicker(time.Second)
+wait:
+	for int(st.completed.Load()) < created {
+		select {
+		case <-ctx.Done():
+			break wait
+		case <-deadline:
+			st.fail("timed out after %s: %d of %d runs completed", *timeout, st.completed.Load(), created)
+			break wait
+		case <-ticker.C:
+		}
+	}
+	ticker.Stop()
+	stopNodes()
+	nodeWG.Wait()
+
+	// 4. 汇总报告
+	st.mu.Lock()
+	eventsElapsed := st.eventsEnd.Sub(st.eventsStart)
+	st.mu.Unlock()
+	report := loadtest.NewReport(&st.schedule, int(st.events.Load()), eventsElapsed, &st.pending)
+	report.Runs = opts.runs
+	report.Completed = int(st.completed.Load())
+	report.Errors = int(st.errors.Load())
+	report.Duration = time.Since(start)
+
+	if *cleanup {
+		cleanupResources(c, taskIDs, nodes)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		enc.Encode(report)
+	} else {
+		fmt.Println(report)
+	}
+
+	violations := th.Check(report)
+	if th.Strict && report.Completed < report.Runs {
+		violations = append(violations, fmt.Sprintf("completed %d of %d runs", report.Completed, report.Runs))
+	}
+	if len(violations) > 0 {
+		fmt.Fprintf(os.Stderr, "loadgen: FAIL: %s\n", loadtest.Summary(violations))
+		os.Exit(1)
+	}
+}
+
+// createRuns 并发创建任务并为每个任务启动一个 Run，返回创建成功的任务 ID 与 Run 数
+func createRuns(ctx context.Context, c *client, st *state, opts options, tag string) ([]string, int) {
+	jobs := make(chan int)
+	var (
+		mu      sync.Mutex
+		taskIDs []string
+		runs    atomic.Int64
+		wg      sync.WaitGroup
+	)
+	for range opts.createConcurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				var task struct {
+					ID string `json:"id"`
+				}
+				err := c.do(ctx, http.MethodPost, "/api/v1/tasks", map[string]interface{}{
+					"name":   fmt.Sprintf("loadgen %s #%d", tag, i),
+					"prompt": "loadgen synthetic task",
+					"type":   opts.agentType,
+					"labels": map[string]string{"loadgen": tag},
+				}, &task)
+				if err != nil {
+					st.fail("create task: %v", err)
+					continue
+				}
+				mu.Lock()
+				taskIDs = append(taskIDs, task.ID)
+				mu.Unlock()
+				if err := c.do(ctx, http.MethodPost, "/api/v1/tasks/"+task.ID+"/runs", nil, nil); err != nil {
+					st.fail("create run for task %s: %v", task.ID, err)
+					continue
+				}
+				runs.Add(1)
+			}
+		}()
+	}
+	for i := range opts.runs {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return taskIDs, int(runs.Load())
+}
+
+// samplePending 每秒采样调度队列的未确认消息数（管理后台总览不可用时停止采样）
+func samplePending(ctx context.Context, c *client, st *state) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		var overview struct {
+			Scheduler struct {
+				QueuePending int64  `json:"queue_pending"`
+				Error        string `json:"error"`
+			} `json:"scheduler"`
+		}
+		err := c.do(ctx, http.MethodGet, "/api/v1/admin/overview", nil, &overview)
+		if err == nil && overview.Scheduler.Error != "" {
+			err = fmt.Errorf("%s", overview.Scheduler.Error)
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				fmt.Fprintf(os.Stderr, "loadgen: queue metrics unavailable, skipping pending growth (%v)\n", err)
+			}
+			return
+		}
+		st.pending.Observe(overview.Scheduler.QueuePending)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cleanupResources 删除生成的任务与模拟节点（尽力而为）
+func cleanupResources(c *client, taskIDs []string, nodes []*simNode) {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+	for _, id := range taskIDs {
+		if err := c.do(ctx, http.MethodDelete, "/api/v1/tasks/"+id, nil, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "loadgen: cleanup task %s: %v\n", id, err)
+		}
+	}
+	for _, n := range nodes {
+		if err := c.do(ctx, http.MethodDelete, "/api/v1/nodes/"+n.id, nil, nil); err != nil {
+			fmt.Fprintf(os.Stderr, "loadgen: cleanup node %s: %v\n", n.id, err)
+		}
+	}
+}
+
+// client 访问 API Server 的最小客户端
+type client struct {
+	baseURL   string
+	token     string // 用户 JWT（Authorization: Bearer）
+	nodeToken string // NodeManager 共享密钥（X-Node-Token）
+	http      *http.Client
+}
+
+// do 发送 JSON 请求；out 非 nil 时解析响应体，非 2xx 响应返回错误
+func (c *client) do(ctx context.Context, method, path string, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.nodeToken != "" {
+		req.Header.Set("X-Node-Token", c.nodeToken)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, path, resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// fatalf 输出错误并以退出码 2 退出
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "loadgen: "+format+"\n", args...)
+	os.Exit(2)
+}
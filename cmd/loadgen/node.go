@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// heartbeatInterval 模拟节点的心跳间隔（与 NodeManager 默认值一致）
+const heartbeatInterval = 5 * time.Second
+
+// scriptedEvents 模拟 Agent 一次执行的事件序列，按 Run 的事件数循环使用
+var scriptedEvents = []struct {
+	eventType string
+	payload   map[string]interface{}
+}{
+	{"message", map[string]interface{}{"content": "Let me look at the repository layout first."}},
+	{"tool_use_start", map[string]interface{}{"tool": "Bash", "input": map[string]interface{}{"command": "ls -la"}}},
+	{"tool_result", map[string]interface{}{"tool": "Bash", "output": "README.md\ngo.mod\ninternal\n", "success": true}},
+	{"thinking", map[string]interface{}{"content": "The handler lives under internal; I should read it before editing."}},
+	{"file_read", map[string]interface{}{"path": "internal/handler.go"}},
+	{"file_write", map[string]interface{}{"path": "internal/handler.go"}},
+	{"command", map[string]interface{}{"command": "go", "args": []string{"test", "./..."}}},
+	{"command_output", map[string]interface{}{"stdout": "ok  \tagents-admin/internal\t0.012s\n", "exit_code": 0}},
+	{"message", map[string]interface{}{"content": "The change is done and the tests pass."}},
+}
+
+// simNode 模拟节点：心跳上线、轮询分配给自己的 Run，按脚本上报事件后回报完成
+type simNode struct {
+	id    string
+	tag   string
+	c     *client
+	st    *state
+	opts  options
+	slots chan struct{} // 并发执行槽位
+
+	mu      sync.Mutex
+	running map[string]bool // 已领取、尚未完成的 Run
+}
+
+func newSimNode(id, tag string, c *client, st *state, opts options) *simNode {
+	return &simNode{
+		id:      id,
+		tag:     tag,
+		c:       c,
+		st:      st,
+		opts:    opts,
+		slots:   make(chan struct{}, opts.nodeConcurrency),
+		running: make(map[string]bool),
+	}
+}
+
+// run 心跳与轮询循环，ctx 取消后等待执行中的 Run 结束
+func (n *simNode) run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	poll := time.NewTicker(n.opts.poll)
+	defer poll.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if err := n.heartbeat(ctx); err != nil && ctx.Err() == nil {
+				n.st.fail("node %s heartbeat: %v", n.id, err)
+			}
+		case <-poll.C:
+			n.claim(ctx, &wg)
+		}
+	}
+}
+
+// heartbeat 上报心跳（标签与容量与真实节点格式一致）
+func (n *simNode) heartbeat(ctx context.Context) error {
+	n.mu.Lock()
+	running := make([]string, 0, len(n.running))
+	for id := range n.running {
+		running = append(running, id)
+	}
+	n.mu.Unlock()
+	return n.c.do(ctx, http.MethodPost, "/api/v1/nodes/heartbeat", map[string]interface{}{
+		"node_id":      n.id,
+		"status":       "online",
+		"hostname":     n.id,
+		"labels":       map[string]string{"loadgen": n.tag},
+		"running_runs": running,
+		"capacity":     map[string]interface{}{"max_concurrent": n.opts.nodeConcurrency},
+	}, nil)
+}
+
+// assignedRun GET /api/v1/nodes/{id}/runs 中的一项
+type assignedRun struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	EventSeq  int       `json:"event_seq"`
+}
+
+// claim 领取新分配的 Run，记录调度延迟并在空闲槽位中执行
+func (n *simNode) claim(ctx context.Context, wg *sync.WaitGroup) {
+	var result struct {
+		Runs []assignedRun `json:"runs"`
+	}
+	if err := n.c.do(ctx, http.MethodGet, "/api/v1/nodes/"+n.id+"/runs", nil, &result); err != nil {
+		if ctx.Err() == nil {
+			n.st.fail("node %s poll: %v", n.id, err)
+		}
+		return
+	}
+	for _, r := range result.Runs {
+		n.mu.Lock()
+		claimed := n.running[r.ID] || r.Status != "assigned"
+		if !claimed {
+			n.running[r.ID] = true
+		}
+		n.mu.Unlock()
+		if claimed {
+			continue
+		}
+		// assigned 时 updated_at 即分配时间
+		n.st.schedule.Add(r.UpdatedAt.Sub(r.CreatedAt))
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n.slots <- struct{}{}
+			defer func() { <-n.slots }()
+			n.execute(ctx, r)
+		}()
+	}
+}
+
+// execute 模拟执行：running → 分批上报事件 → done
+func (n *simNode) execute(ctx context.Context, r assignedRun) {
+	defer func() {
+		n.mu.Lock()
+		delete(n.running, r.ID)
+		n.mu.Unlock()
+	}()
+	if err := n.updateStatus(ctx, r.ID, "running"); err != nil {
+		n.st.fail("run %s start: %v", r.ID, err)
+		return
+	}
+
+	start := time.Now()
+	for from := 0; from < n.opts.events; from += n.opts.eventBatch {
+		batch := make([]map[string]interface{}, 0, n.opts.eventBatch)
+		for i := from; i < min(from+n.opts.eventBatch, n.opts.events); i++ {
+			ev := scriptedEvents[i%len(scriptedEvents)]
+			raw, _ := json.Marshal(map[string]interface{}{"type": ev.eventType, "data": ev.payload})
+			batch = append(batch, map[string]interface{}{
+				"seq":       r.EventSeq + i + 1,
+				"type":      ev.eventType,
+				"timestamp": time.Now(),
+				"payload":   ev.payload,
+				"raw":       string(raw),
+			})
+		}
+		var resp struct {
+			Created int `json:"created"`
+		}
+		if err := n.c.do(ctx, http.MethodPost, "/api/v1/runs/"+r.ID+"/events", map[string]interface{}{"events": batch}, &resp); err != nil {
+			n.st.fail("run %s events: %v", r.ID, err)
+			return
+		}
+		n.st.events.Add(int64(resp.Created))
+	}
+	n.st.eventWindow(start, time.Now())
+
+	if err := n.updateStatus(ctx, r.ID, "done"); err != nil {
+		n.st.fail("run %s finish: %v", r.ID, err)
+		return
+	}
+	n.st.completed.Add(1)
+}
+
+func (n *simNode) updateStatus(ctx context.Context, runID, status string) error {
+	err := n.c.do(ctx, http.MethodPatch, "/api/v1/runs/"+runID, map[string]string{"status": status, "node_id": n.id}, nil)
+	if err != nil {
+		return fmt.Errorf("update status %s: %w", status, err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+// Package main 模拟 Agent CLI（集成测试与压测用）
+//
+// 按场景文件向标准输出逐行写入事件，代替真实的 Agent CLI 接入 NodeManager，
+// 用于在没有模型调用的情况下验证事件解析、上报、取消与失败处理。
+//
+// 场景来源（优先级从高到低）：
+//   - -scenario 参数或 MOCK_RUNNER_SCENARIO 环境变量指定的文件：
+//     YAML（.yaml / .yml / .json）描述事件序列、间隔与故障注入，
+//     JSONL（.jsonl / .ndjson / .log）为录制的 Agent 输出，每行原样回放
+//   - 内置场景：一次典型的 Claude Code stream-json 执行（13 个事件）
+//
+// YAML 场景示例：
+//
+//	name: flaky-tool
+//	delay: 200ms        # 步骤之间的默认间隔
+//	loop: 1             # 每个副本重复次数（负数为直到被中断）
+//	concurrency: 1      # 并发副本数，输出按行交错
+//	exit_code: 0        # 正常结束的退出码
+//	steps:
+//	  - event: {type: assistant, message: {content: [{type: text, text: hello}]}}
+//	  - raw: '{"type": "tool_use", '   # 畸形行
+//	  - giant: 2097152                 # text 为 2 MiB 的超长行
+//	  - stderr: "warning: retrying"
+//	  - delay: 5s                      # 只等待
+//	  - exit: 3                        # 以退出码 3 立即结束
+//
+// 未识别的命令行参数被忽略，因此可以直接作为 Agent 命令使用（如通用 Agent 类型的 command，
+// 或以 claude 为名放入 PATH，Adapter 追加的 -p、--output-format 等参数不影响执行）。
+//
+// 用法示例：
+//
+//	mock-runner -scenario cmd/mock-runner/scenarios/crash.yaml -speed 0
+//	MOCK_RUNNER_SCENARIO=recorded.jsonl mock-runner -loop 10 -concurrency 4
+//
+// 退出码：场景指定的退出码；参数或场景文件错误返回 2，被信号中断返回 128 + 信号值。
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+func main() {
+	fs := flag.NewFlagSet("mock-runner", flag.ExitOnError)
+	scenarioPath := fs.String("scenario", os.Getenv("MOCK_RUNNER_SCENARIO"), "场景文件（默认读取 MOCK_RUNNER_SCENARIO，为空时使用内置场景）")
+	loop := fs.Int("loop", 0, "覆盖场景的重复次数（0 使用场景配置，负数为直到被中断）")
+	concurrency := fs.Int("concurrency", 0, "覆盖场景的并发副本数（0 使用场景配置）")
+	delay := fs.Duration("delay", 0, "覆盖场景的默认步骤间隔（0 使用场景配置）")
+	speed := fs.Float64("speed", 1, "等待时间的倍率（0 不等待，0.5 加速一倍）")
+	fs.Parse(knownArgs(fs, os.Args[1:]))
+
+	if *speed < 0 {
+		fatalf("-speed 不能为负数")
+	}
+
+	s := defaultScenario()
+	if *scenarioPath != "" {
+		var err error
+		if s, err = loadScenario(*scenarioPath); err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if *loop != 0 {
+		s.Loop = *loop
+	}
+	if *concurrency > 0 {
+		s.Concurrency = *concurrency
+	}
+	if *delay > 0 {
+		s.Delay = *delay
+	}
+
+	// 取消 Run 时 NodeManager 先发送 SIGINT/SIGTERM（见 nodemanager/cancellation.go）
+	ctx, cancel := context.WithCancel(context.Background())
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, os.Interrupt, syscall.SIGTERM)
+	var signaled atomic.Int64
+	go func() {
+		if sig, ok := (<-sigCh).(syscall.Signal); ok {
+			signaled.Store(int64(sig))
+		}
+		cancel()
+	}()
+
+	r := &runner{stdout: os.Stdout, stderr: os.Stderr, speed: *speed}
+	start := time.Now()
+	code := r.run(ctx, s, 128+int(syscall.SIGINT))
+	if sig := signaled.Load(); sig != 0 {
+		code = 128 + int(sig)
+	}
+	fmt.Fprintf(os.Stderr, "mock-runner: scenario %s finished in %s, exit %d\n", s.Name, time.Since(start).Round(time.Millisecond), code)
+	os.Exit(code)
+}
+
+// knownArgs 只保留 fs 中定义的参数（及其值），忽略 Adapter 追加的 Agent CLI 参数
+func knownArgs(fs *flag.FlagSet, args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if !strings.HasPrefix(arg, "-") || arg == "-" || arg == "--" {
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if fs.Lookup(name) == nil {
+			continue
+		}
+		out = append(out, arg)
+		if !hasValue && i+1 < len(args) {
+			out = append(out, args[i+1])
+			i++
+		}
+	}
+	return out
+}
+
+func fatalf(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "mock-runner: "+format+"\n", args...)
+	os.Exit(2)
+}
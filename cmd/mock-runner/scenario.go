@@ -0,0 +1,269 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxScenarioLine JSONL 场景文件的单行上限（录制的输出可能包含超长行）
+const maxScenarioLine = 64 << 20
+
+// Scenario 模拟 Agent 的一次执行
+type Scenario struct {
+	Name        string        `yaml:"name"`
+	Delay       time.Duration `yaml:"delay"`       // 步骤之间的默认间隔
+	Loop        int           `yaml:"loop"`        // 每个副本重复执行的次数（0 为 1 次，负数为直到被中断）
+	Concurrency int           `yaml:"concurrency"` // 并发执行的副本数（0 为 1），各副本的输出按行交错
+	ExitCode    int           `yaml:"exit_code"`   // 全部步骤执行完后的退出码
+	Steps       []Step        `yaml:"steps"`
+}
+
+// Step 场景中的一个步骤，event / raw / giant / stderr / exit 只能指定一个（都不指定时只等待 delay）
+type Step struct {
+	Event  map[string]interface{} `yaml:"event"`  // 以一行 JSON 输出到标准输出
+	Raw    *string                `yaml:"raw"`    // 原样输出一行（畸形 JSON、非 JSON 文本）
+	Giant  int                    `yaml:"giant"`  // 输出一条 text 长度为 giant 字节的 assistant 事件（超长行）
+	Stderr string                 `yaml:"stderr"` // 输出一行到标准错误
+	Exit   *int                   `yaml:"exit"`   // 立即以该退出码结束（所有副本）
+	Delay  *time.Duration         `yaml:"delay"`  // 执行本步骤前的等待，覆盖场景的 delay
+}
+
+// validate 检查每个步骤只指定一种动作
+func (s *Scenario) validate() error {
+	if len(s.Steps) == 0 {
+		return fmt.Errorf("场景没有步骤")
+	}
+	for i, step := range s.Steps {
+		n := 0
+		for _, set := range []bool{step.Event != nil, step.Raw != nil, step.Giant > 0, step.Stderr != "", step.Exit != nil} {
+			if set {
+				n++
+			}
+		}
+		if n > 1 {
+			return fmt.Errorf("步骤 %d: event、raw、giant、stderr、exit 只能指定一个", i+1)
+		}
+		if step.Giant < 0 {
+			return fmt.Errorf("步骤 %d: giant 不能为负数", i+1)
+		}
+	}
+	return nil
+}
+
+// loadScenario 读取场景文件
+//
+// .jsonl / .ndjson / .log 为录制的 Agent 输出，每行原样输出（场景选项取默认值，可由命令行覆盖）；
+// 其他扩展名按 YAML 解析（JSON 是 YAML 的子集）。
+func loadScenario(path string) (*Scenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var s *Scenario
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".jsonl", ".ndjson", ".log":
+		s, err = parseJSONL(f)
+	default:
+		s, err = parseYAML(f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析场景 %s: %w", path, err)
+	}
+	if s.Name == "" {
+		s.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return s, s.validate()
+}
+
+func parseYAML(r io.Reader) (*Scenario, error) {
+	var s Scenario
+	dec := yaml.NewDecoder(r)
+	dec.KnownFields(true)
+	if err := dec.Decode(&s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func parseJSONL(r io.Reader) (*Scenario, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), maxScenarioLine)
+	s := &Scenario{}
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		s.Steps = append(s.Steps, Step{Raw: &line})
+	}
+	return s, sc.Err()
+}
+
+// runner 按场景输出事件
+type runner struct {
+	stdout io.Writer
+	stderr io.Writer
+	speed  float64 // 等待时间的倍率（0 表示不等待）
+
+	mu sync.Mutex // 保证多个副本的输出按整行交错
+}
+
+// run 执行场景，返回进程退出码；ctx 取消时返回 interrupted
+func (r *runner) run(parent context.Context, s *Scenario, interrupted int) int {
+	ctx, cancel := context.WithCancel(parent)
+	defer cancel()
+
+	copies := max(s.Concurrency, 1)
+	var exitCode atomic.Int64
+	exitCode.Store(-1)
+	var wg sync.WaitGroup
+	for c := range copies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if code, ok := r.runCopy(ctx, s, c); ok {
+				exitCode.CompareAndSwap(-1, int64(code))
+				cancel()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if code := exitCode.Load(); code >= 0 {
+		return int(code)
+	}
+	if parent.Err() != nil {
+		return interrupted
+	}
+	return s.ExitCode
+}
+
+// runCopy 执行一个副本，遇到 exit 步骤时返回其退出码与 true
+func (r *runner) runCopy(ctx context.Context, s *Scenario, copyIndex int) (int, bool) {
+	for i := 0; s.Loop < 0 || i < max(s.Loop, 1); i++ {
+		for _, step := range s.Steps {
+			delay := s.Delay
+			if step.Delay != nil {
+				delay = *step.Delay
+			}
+			if !r.wait(ctx, delay) {
+				return 0, false
+			}
+			if step.Exit != nil {
+				return *step.Exit, true
+			}
+			if err := r.emit(step); err != nil {
+				fmt.Fprintf(r.stderr, "mock-runner: copy %d: %v\n", copyIndex, err)
+				return 1, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// wait 按倍率等待，ctx 取消时返回 false
+func (r *runner) wait(ctx context.Context, d time.Duration) bool {
+	d = time.Duration(float64(d) * r.speed)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// emit 输出一个步骤
+func (r *runner) emit(step Step) error {
+	var (
+		w    = r.stdout
+		line []byte
+	)
+	switch {
+	case step.Event != nil:
+		data, err := json.Marshal(step.Event)
+		if err != nil {
+			return err
+		}
+		line = data
+	case step.Raw != nil:
+		line = []byte(*step.Raw)
+	case step.Giant > 0:
+		line = giantLine(step.Giant)
+	case step.Stderr != "":
+		w, line = r.stderr, []byte(step.Stderr)
+	default:
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	_, err := w.Write(append(line, '\n'))
+	return err
+}
+
+// giantLine text 长度为 n 字节的 assistant 事件
+func giantLine(n int) []byte {
+	var b bytes.Buffer
+	b.Grow(n + 128)
+	b.WriteString(`{"type":"assistant","message":{"role":"assistant","content":[{"type":"text","text":"`)
+	b.Write(bytes.Repeat([]byte("x"), n))
+	b.WriteString(`"}]}}`)
+	return b.Bytes()
+}
+
+// defaultScenario 未指定场景文件时的内置场景：一次典型的 Claude Code stream-json 执行（13 个事件）
+func defaultScenario() *Scenario {
+	text := func(t string) map[string]interface{} {
+		return map[string]interface{}{"type": "assistant", "message": map[string]interface{}{
+			"role": "assistant", "content": []interface{}{map[string]interface{}{"type": "text", "text": t}},
+		}}
+	}
+	tool := func(id, name string, input map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"type": "tool_use", "id": id, "name": name, "input": input}
+	}
+	result := func(id, output string) map[string]interface{} {
+		return map[string]interface{}{"type": "tool_result", "tool_use_id": id, "content": output, "is_error": false}
+	}
+	events := []map[string]interface{}{
+		{"type": "system", "subtype": "init", "session_id": "mock-session", "model": "mock-model", "tools": []string{"Bash", "Read", "Edit"}},
+		text("Let me look at the repository layout first."),
+		tool("toolu_1", "Bash", map[string]interface{}{"command": "ls -la"}),
+		result("toolu_1", "README.md\ngo.mod\ninternal\n"),
+		text("The handler lives under internal; reading it before editing."),
+		tool("toolu_2", "Read", map[string]interface{}{"file_path": "internal/handler.go"}),
+		result("toolu_2", "package internal\n"),
+		tool("toolu_3", "Edit", map[string]interface{}{"file_path": "internal/handler.go", "old_string": "package internal", "new_string": "package internal // edited"}),
+		result("toolu_3", "The file has been updated."),
+		tool("toolu_4", "Bash", map[string]interface{}{"command": "go test ./..."}),
+		result("toolu_4", "ok  \tmock/internal\t0.012s\n"),
+		text("The change is done and the tests pass."),
+		{"type": "result", "subtype": "success", "is_error": false, "session_id": "mock-session", "num_turns": 5,
+			"result": "The change is done and the tests pass.", "total_cost_usd": 0.0123,
+			"usage":      map[string]interface{}{"input_tokens": 1200, "output_tokens": 340, "cache_read_input_tokens": 3000, "cache_creation_input_tokens": 500},
+			"modelUsage": map[string]interface{}{"mock-model": map[string]interface{}{"inputTokens": 1200, "outputTokens": 340}}},
+	}
+	s := &Scenario{Name: "default", Delay: 100 * time.Millisecond}
+	for _, e := range events {
+		s.Steps = append(s.Steps, Step{Event: e})
+	}
+	return s
+}
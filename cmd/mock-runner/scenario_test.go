@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/nodemanager/adapter/claude"
+)
+
+func writeScenario(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func runScenario(s *Scenario) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	r := &runner{stdout: &stdout, stderr: &stderr}
+	code := r.run(context.Background(), s, 130)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestDefaultScenario_ParsedByClaudeAdapter(t *testing.T) {
+	stdout, _, code := runScenario(defaultScenario())
+	require.Equal(t, 0, code)
+
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	assert.Len(t, lines, 13)
+	a := claude.New()
+	var types []adapter.EventType
+	var usage *adapter.Usage
+	for _, line := range lines {
+		event, err := a.ParseEvent(line)
+		require.NoError(t, err)
+		if event == nil {
+			continue
+		}
+		types = append(types, event.Type)
+		if u := a.ExtractUsage(event); u != nil {
+			usage = u
+		}
+	}
+	assert.Equal(t, adapter.EventRunCompleted, types[len(types)-1])
+	assert.Contains(t, types, adapter.EventToolUseStart)
+	require.NotNil(t, usage)
+	assert.Equal(t, int64(1200), usage.InputTokens)
+	assert.Equal(t, "mock-model", usage.Model)
+}
+
+func TestLoadScenario_YAMLFailureInjection(t *testing.T) {
+	path := writeScenario(t, "crash.yaml", `
+delay: 1h
+steps:
+  - event: {type: assistant, message: {content: [{type: text, text: hi}]}}
+    delay: 0s
+  - raw: '{"type": "tool_use", '
+    delay: 0s
+  - giant: 2048
+    delay: 0s
+  - stderr: boom
+    delay: 0s
+  - exit: 3
+    delay: 0s
+  - raw: never printed
+`)
+	s, err := loadScenario(path)
+	require.NoError(t, err)
+	assert.Equal(t, "crash", s.Name)
+
+	stdout, stderr, code := runScenario(s)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "boom\n", stderr)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	require.Len(t, lines, 3)
+	assert.JSONEq(t, `{"type":"assistant","message":{"content":[{"type":"text","text":"hi"}]}}`, lines[0])
+	assert.Equal(t, `{"type": "tool_use", `, lines[1])
+
+	var giant struct {
+		Message struct {
+			Content []struct{ Text string } `json:"content"`
+		} `json:"message"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[2]), &giant))
+	assert.Len(t, giant.Message.Content[0].Text, 2048)
+}
+
+func TestLoadScenario_Invalid(t *testing.T) {
+	_, err := loadScenario(writeScenario(t, "empty.yaml", "name: empty\n"))
+	assert.Error(t, err)
+	_, err = loadScenario(writeScenario(t, "two.yaml", "steps:\n  - raw: a\n    stderr: b\n"))
+	assert.ErrorContains(t, err, "只能指定一个")
+	_, err = loadScenario(writeScenario(t, "typo.yaml", "steps:\n  - evnt: {}\n"))
+	assert.Error(t, err, "unknown fields are rejected")
+}
+
+func TestLoadScenario_JSONLLoopAndConcurrency(t *testing.T) {
+	path := writeScenario(t, "recorded.jsonl", "{\"type\":\"assistant\"}\n\nnot json\n")
+	s, err := loadScenario(path)
+	require.NoError(t, err)
+	s.Loop, s.Concurrency, s.ExitCode = 3, 4, 1
+
+	stdout, _, code := runScenario(s)
+	assert.Equal(t, 1, code)
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	assert.Len(t, lines, 2*3*4)
+	for _, line := range lines {
+		assert.Contains(t, []string{`{"type":"assistant"}`, "not json"}, line, "lines must not interleave")
+	}
+}
+
+func TestRunner_Interrupted(t *testing.T) {
+	s := &Scenario{Loop: -1, Delay: time.Millisecond, Steps: []Step{{Event: map[string]interface{}{"type": "assistant"}}}}
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var stdout bytes.Buffer
+	r := &runner{stdout: &stdout, stderr: &stdout, speed: 1}
+	assert.Equal(t, 143, r.run(ctx, s, 143))
+	assert.NotZero(t, stdout.Len())
+}
+
+func TestBundledScenarios(t *testing.T) {
+	paths, err := filepath.Glob("scenarios/*.yaml")
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+	for _, path := range paths {
+		_, err := loadScenario(path)
+		assert.NoError(t, err, path)
+	}
+}
+
+func TestKnownArgs(t *testing.T) {
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("scenario", "", "")
+	fs.Float64("speed", 1, "")
+	args := knownArgs(fs, []string{"-p", "fix the bug", "--output-format", "stream-json", "-scenario", "a.yaml", "--speed=0", "--max-turns", "5"})
+	assert.Equal(t, []string{"-scenario", "a.yaml", "--speed=0"}, args)
+}
@@ -0,0 +1,27 @@
+//go:build chaos
+
+package main
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/shared/chaos"
+)
+
+// applyChaos 按 CHAOS_HTTP_* 环境变量为访问 API Server 的客户端注入失败与延迟（仅 chaos 构建标签下编译）
+func applyChaos(client *http.Client) *http.Client {
+	cfg, err := chaos.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid chaos config: %v", err)
+	}
+	if cfg.HTTPError == 0 && cfg.HTTPDelay == 0 {
+		return client
+	}
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+	log.Printf("[chaos.enabled] http_error=%g http_status=%d http_delay=%s", cfg.HTTPError, cfg.HTTPStatus, cfg.HTTPDelay)
+	return chaos.WrapClient(client, chaos.New(cfg))
+}
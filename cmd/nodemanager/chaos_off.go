@@ -0,0 +1,10 @@
+//go:build !chaos
+
+package main
+
+import "net/http"
+
+// applyChaos 非 chaos 构建不注入故障
+func applyChaos(client *http.Client) *http.Client {
+	return client
+}
@@ -14,13 +14,17 @@ import (
 	"path/filepath"
 	"strings"
 	"syscall"
+	"time"
 
 	"agents-admin/internal/config"
 	"agents-admin/internal/nodemanager"
+	"agents-admin/internal/nodemanager/adapter/aider"
 	"agents-admin/internal/nodemanager/adapter/claude"
 	"agents-admin/internal/nodemanager/adapter/gemini"
 	"agents-admin/internal/nodemanager/adapter/qwencode"
 	"agents-admin/internal/nodemanager/setup"
+	"agents-admin/internal/shared/logging"
+	"agents-admin/internal/shared/model"
 )
 
 func main() {
@@ -28,8 +32,13 @@ func main() {
 	reconfigure := flag.Bool("reconfigure", false, "强制重新进入配置向导")
 	setupPort := flag.Int("setup-port", 15700, "Setup 向导监听端口")
 	setupListen := flag.String("setup-listen", "0.0.0.0", "Setup 向导监听地址")
+	logLevel := flag.String("log-level", "", "日志级别：debug / info / warn / error（优先于 LOG_LEVEL 与配置文件）")
 	flag.Parse()
 
+	if *logLevel != "" {
+		os.Setenv("LOG_LEVEL", *logLevel)
+	}
+
 	// 环境变量覆盖命令行参数
 	if p := os.Getenv("SETUP_PORT"); p != "" {
 		fmt.Sscanf(p, "%d", setupPort)
@@ -76,10 +85,12 @@ func runSetupMode(configPath, listenAddr string, port int) {
 
 // runWorkerMode 正常工作模式
 func runWorkerMode() {
-	log.Println("Starting NodeManager...")
-
 	// 通过统一的 config 包加载配置
 	appCfg := config.LoadNodeManager()
+	if err := logging.Setup(logging.Config{Level: appCfg.Log.Level, Format: appCfg.LogFormat(), Output: appCfg.Log.Output, Component: "nodemanager"}); err != nil {
+		log.Fatalf("Invalid log config: %v", err)
+	}
+	log.Println("Starting NodeManager...")
 
 	// 环境变量 > yaml 配置 > 默认值
 	cfg := nodemanager.Config{
@@ -88,11 +99,38 @@ func runWorkerMode() {
 		WorkspaceDir: firstNonEmpty(os.Getenv("WORKSPACE_DIR"), appCfg.Node.WorkspaceDir, "/tmp/workspaces"),
 		Labels:       appCfg.Node.Labels,
 		NodeToken:    firstNonEmpty(os.Getenv("NODE_TOKEN"), appCfg.Auth.NodeToken),
+		GitToken:     os.Getenv("GIT_TOKEN"),
+		ExecBackend:  firstNonEmpty(os.Getenv("EXEC_BACKEND"), appCfg.Node.Exec.Backend, model.ExecBackendDocker),
+		Process:      processConfig(appCfg.Node.Exec.Process),
+		Events:       eventReportConfig(appCfg.Node.Events),
+		Handover:     nodemanager.HandoverConfig{Enabled: appCfg.Node.Handover.Enabled, StateDir: appCfg.Node.Handover.StateDir},
+		CancelGrace:  time.Duration(appCfg.Node.CancelGraceSeconds) * time.Second,
+		Pools:        poolConfigs(appCfg.Node.Pools),
+		ProxyProbe:   proxyProbeConfig(appCfg.Node.ProxyProbe),
+		Concurrency:  concurrencyConfig(appCfg.Node.Concurrency),
+		Transport:    firstNonEmpty(os.Getenv("NODE_TRANSPORT"), appCfg.Node.Transport, nodemanager.TransportREST),
+		GRPCAddr:     firstNonEmpty(os.Getenv("API_SERVER_GRPC_ADDR"), appCfg.Node.GRPCAddr),
 	}
 	if len(cfg.Labels) == 0 {
 		cfg.Labels = map[string]string{"os": "linux"}
 	}
 
+	// 容器运行时：环境变量 CONTAINER_RUNTIME > yaml 配置 > docker
+	containers, err := nodemanager.NewContainerRuntime(nodemanager.ContainerRuntimeConfig{
+		Name:      firstNonEmpty(os.Getenv("CONTAINER_RUNTIME"), appCfg.Node.Container.Runtime),
+		Binary:    appCfg.Node.Container.Binary,
+		Namespace: appCfg.Node.Container.Namespace,
+		Socket:    appCfg.Node.Container.Socket,
+	})
+	if err != nil {
+		log.Fatalf("Invalid container runtime: %v", err)
+	}
+	cfg.Containers = containers
+	// 声明默认执行后端，指定 exec-backend 标签的任务只会调度到同值节点
+	if _, ok := cfg.Labels[model.LabelExecBackend]; !ok {
+		cfg.Labels[model.LabelExecBackend] = cfg.ExecBackend
+	}
+
 	// TLS 客户端配置：环境变量 > yaml 配置 > 自动检测 HTTPS URL
 	tlsCAFile := firstNonEmpty(os.Getenv("TLS_CA_FILE"), appCfg.TLS.CAFile)
 	tlsEnabled := appCfg.TLS.Enabled || strings.HasPrefix(cfg.APIServerURL, "https://")
@@ -114,9 +152,37 @@ func runWorkerMode() {
 		}
 	}
 
+	// 节点专属凭证：首次启动以一次性加入令牌（NODE_JOIN_TOKEN）换取，之后从凭证目录加载
+	credDir := firstNonEmpty(os.Getenv("NODE_CREDENTIAL_DIR"), appCfg.Node.CredentialDir, nodemanager.DefaultCredentialDir())
+	cred, err := nodemanager.LoadOrJoin(context.Background(), cfg.HTTPClient, cfg.APIServerURL, cfg.NodeID, os.Getenv("NODE_JOIN_TOKEN"), credDir)
+	if err != nil {
+		log.Fatalf("Failed to join API Server: %v", err)
+	}
+	if cred != nil {
+		cfg.NodeToken = cred.Token
+		if tlsEnabled && cred.CertFile != "" {
+			client, err := buildNodeCertClient(firstNonEmpty(tlsCAFile, cred.CAFile), cred.CertFile, cred.KeyFile)
+			if err != nil {
+				log.Fatalf("Failed to load node client certificate: %v", err)
+			}
+			cfg.HTTPClient = client
+		}
+		log.Printf("Using node credential from %s", credDir)
+	}
+
+	// gRPC 通信与 REST 共用 TLS 配置（CA 与节点客户端证书）
+	if cfg.Transport == nodemanager.TransportGRPC && tlsEnabled {
+		cfg.GRPCTLS = clientTLSConfig(cfg.HTTPClient)
+	}
+
+	// 故障注入（仅 chaos 构建）：访问 API Server 的请求按 CHAOS_HTTP_* 配置失败或延迟
+	cfg.HTTPClient = applyChaos(cfg.HTTPClient)
+
 	log.Printf("Node ID: %s", cfg.NodeID)
 	log.Printf("API Server: %s", cfg.APIServerURL)
+	log.Printf("Transport: %s", cfg.Transport)
 	log.Printf("Workspace Dir: %s", cfg.WorkspaceDir)
+	log.Printf("Container Runtime: %s", cfg.Containers.Name())
 
 	if err := os.MkdirAll(cfg.WorkspaceDir, 0755); err != nil {
 		log.Fatalf("Failed to create workspace dir: %v", err)
@@ -129,6 +195,7 @@ func runWorkerMode() {
 	mgr.RegisterAdapter(qwencode.New()) // 优先：免费 2000 请求/天
 	mgr.RegisterAdapter(gemini.New())
 	mgr.RegisterAdapter(claude.New())
+	mgr.RegisterAdapter(aider.New())
 
 	// HTTP-Only 架构：所有通信通过 HTTPS 与 API Server 交互，无需直连 Redis
 	log.Println("HTTP-Only mode: task polling via API Server")
@@ -147,6 +214,63 @@ func runWorkerMode() {
 	mgr.Start(ctx)
 }
 
+// processConfig 将 yaml 中的 process 执行后端配置转换为 NodeManager 配置（MB 换算为字节）
+func processConfig(c config.NodeProcessConfig) nodemanager.ProcessConfig {
+	const mb = 1 << 20
+	return nodemanager.ProcessConfig{
+		Enabled: c.Enabled,
+		User:    c.User,
+		PassEnv: c.PassEnv,
+		Limits: nodemanager.ProcessLimits{
+			CPUSeconds:    c.CPUSeconds,
+			MemoryBytes:   c.MemoryMB * mb,
+			OpenFiles:     c.OpenFiles,
+			Processes:     c.Processes,
+			FileSizeBytes: c.FileSizeMB * mb,
+		},
+	}
+}
+
+// eventReportConfig 将 yaml 中的事件上报配置转换为 NodeManager 配置（毫秒换算为 time.Duration）
+func eventReportConfig(c config.NodeEventsConfig) nodemanager.EventReportConfig {
+	return nodemanager.EventReportConfig{
+		BatchSize:     c.BatchSize,
+		FlushInterval: time.Duration(c.FlushIntervalMS) * time.Millisecond,
+		QueueSize:     c.QueueSize,
+		MaxRetries:    c.MaxRetries,
+		RetryInterval: time.Duration(c.RetryIntervalMS) * time.Millisecond,
+		SpoolDir:      c.SpoolDir,
+		SpoolMaxBytes: int64(c.SpoolMaxMB) << 20,
+	}
+}
+
+// proxyProbeConfig 将 yaml 中的代理健康探测配置转换为 NodeManager 配置
+func proxyProbeConfig(c config.NodeProxyProbeConfig) nodemanager.ProxyProbeConfig {
+	return nodemanager.ProxyProbeConfig{
+		Interval: time.Duration(c.IntervalSeconds) * time.Second,
+		Timeout:  time.Duration(c.TimeoutSeconds) * time.Second,
+		Target:   c.Target,
+	}
+}
+
+// concurrencyConfig 将 yaml 中的执行并发配置转换为 NodeManager 配置
+func concurrencyConfig(c config.NodeConcurrencyConfig) model.NodeConcurrency {
+	return model.NodeConcurrency{
+		MaxConcurrent:        c.MaxConcurrent,
+		ReservedHighPriority: c.ReservedHighPriority,
+		AgentTypeLimits:      c.AgentTypeLimits,
+	}
+}
+
+// poolConfigs 将 yaml 中的预热实例池配置转换为 NodeManager 配置
+func poolConfigs(pools []config.NodePoolConfig) []nodemanager.PoolConfig {
+	out := make([]nodemanager.PoolConfig, 0, len(pools))
+	for _, p := range pools {
+		out = append(out, nodemanager.PoolConfig{AccountID: p.AccountID, Size: p.Size})
+	}
+	return out
+}
+
 func firstNonEmpty(values ...string) string {
 	for _, v := range values {
 		if v != "" {
@@ -156,6 +280,30 @@ func firstNonEmpty(values ...string) string {
 	return ""
 }
 
+// buildNodeCertClient 构建出示节点客户端证书（mTLS）的 HTTP 客户端
+func buildNodeCertClient(caFile, certFile, keyFile string) (*http.Client, error) {
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, err
+	}
+	client, err := buildTLSClient(caFile)
+	if err != nil {
+		return nil, err
+	}
+	client.Transport.(*http.Transport).TLSClientConfig.Certificates = []tls.Certificate{cert}
+	return client, nil
+}
+
+// clientTLSConfig 取出 HTTP 客户端的 TLS 配置副本（未自定义时使用系统 CA）
+func clientTLSConfig(client *http.Client) *tls.Config {
+	if client != nil {
+		if t, ok := client.Transport.(*http.Transport); ok && t.TLSClientConfig != nil {
+			return t.TLSClientConfig.Clone()
+		}
+	}
+	return &tls.Config{}
+}
+
 // buildTLSClient 构建带自定义 CA 证书的 HTTP 客户端
 func buildTLSClient(caFile string) (*http.Client, error) {
 	caCert, err := os.ReadFile(caFile)
@@ -0,0 +1,3 @@
+-- 024: 产物按 Run 查询索引
+-- Git 结果回写将 PR/MR 地址作为 Run 产物上报，按 run_id 列出产物
+CREATE INDEX IF NOT EXISTS idx_artifacts_run ON artifacts(run_id);
@@ -0,0 +1,17 @@
+-- 025: Git 凭据管理
+-- SSH 私钥 / HTTPS Token 以 AES-GCM 密文存储（主密钥来自 MASTER_KEY 环境变量）
+-- 通过 WorkspaceConfig.Git.CredentialRef 按名称引用
+
+CREATE TABLE IF NOT EXISTS credentials (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE,
+    type VARCHAR(32) NOT NULL,
+    username VARCHAR(200) DEFAULT '',
+    description TEXT DEFAULT '',
+    encrypted_secret TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER credentials_updated_at
+    BEFORE UPDATE ON credentials FOR EACH ROW EXECUTE FUNCTION update_updated_at();
@@ -0,0 +1,4 @@
+-- 026: Run 生命周期钩子（pre_run / post_run / on_failure）
+-- 任务上的钩子配置，未设置时使用模板的 default_hooks
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS hooks JSONB;
+ALTER TABLE task_templates ADD COLUMN IF NOT EXISTS default_hooks JSONB;
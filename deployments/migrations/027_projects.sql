@@ -0,0 +1,23 @@
+-- 027: 项目（Project）
+-- 项目为任务提供默认 Agent 类型、账号池和安全配置，
+-- 任务创建时校验项目白名单，并受覆盖规则（overrides）约束
+
+CREATE TABLE IF NOT EXISTS projects (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE,
+    description TEXT DEFAULT '',
+    default_agent_type VARCHAR(64) DEFAULT '',
+    allowed_agent_types JSONB,
+    account_pool JSONB,
+    default_security JSONB,
+    allowed_security_policies JSONB,
+    overrides JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER projects_updated_at
+    BEFORE UPDATE ON projects FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id VARCHAR(64) REFERENCES projects(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
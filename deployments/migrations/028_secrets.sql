@@ -0,0 +1,17 @@
+-- 028: 运行时密钥（Secrets）
+-- 密钥值以 AES-GCM 密文存储（主密钥来自 MASTER_KEY 环境变量）
+-- 通过 Task.Secrets 按名称引用，执行时由 NodeManager 以环境变量注入 Agent 容器
+
+CREATE TABLE IF NOT EXISTS secrets (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL UNIQUE,
+    description TEXT DEFAULT '',
+    encrypted_value TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER secrets_updated_at
+    BEFORE UPDATE ON secrets FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS secrets JSONB;
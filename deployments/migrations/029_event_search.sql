@@ -0,0 +1,8 @@
+-- 029: 事件全文检索（Run 输出日志搜索）
+-- search_vector 由 payload 与原始输出生成（simple 配置：不做词干化，适配日志关键字与中英文混排）
+-- 查询接口：GET /api/v1/search/events?q=...
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (to_tsvector('simple', coalesce(payload::text, '') || ' ' || coalesce(raw, ''))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_events_search_vector ON events USING GIN (search_vector);
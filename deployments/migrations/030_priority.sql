@@ -0,0 +1,8 @@
+-- 030: 调度优先级与抢占
+-- Task.Priority 在创建 Run 时复制到 Run.Priority；调度器按 high → normal → low 消费，
+-- 启用抢占时 high 优先级 Run 可替换饱和节点上尚未开始执行（assigned）的 low 优先级分配
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'normal';
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS priority VARCHAR(16) NOT NULL DEFAULT 'normal';
+
+CREATE INDEX IF NOT EXISTS idx_runs_status_priority ON runs(status, priority);
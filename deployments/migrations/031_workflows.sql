@@ -0,0 +1,18 @@
+-- 031: DAG 工作流
+-- 以父任务的子任务为节点，节点通过 depends_on 声明依赖边（整体存储在 nodes 中），
+-- 编排器只在依赖全部成功后为节点创建 Run，支持 fail_fast / continue_on_error 模式
+
+CREATE TABLE IF NOT EXISTS workflows (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL DEFAULT '',
+    root_task_id VARCHAR(64) NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    mode VARCHAR(32) NOT NULL DEFAULT 'fail_fast',
+    status VARCHAR(32) NOT NULL DEFAULT 'running',
+    nodes JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_workflows_status ON workflows(status);
+CREATE INDEX IF NOT EXISTS idx_workflows_root_task ON workflows(root_task_id);
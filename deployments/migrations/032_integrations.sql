@@ -0,0 +1,29 @@
+-- 032: 外部 Issue 集成（GitHub / Jira）
+-- 选中的 Issue 导入为 Task，issue_links 记录任务与源 Issue 的反向关联；
+-- comment_results 开启时 Run 结束后将结果以评论形式回写到源 Issue
+
+CREATE TABLE IF NOT EXISTS integrations (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE,
+    provider VARCHAR(32) NOT NULL,
+    base_url VARCHAR(500) NOT NULL DEFAULT '',
+    repo VARCHAR(200) NOT NULL DEFAULT '',
+    project_key VARCHAR(64) NOT NULL DEFAULT '',
+    credential_ref VARCHAR(100) NOT NULL DEFAULT '',
+    comment_results BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TRIGGER integrations_updated_at
+    BEFORE UPDATE ON integrations FOR EACH ROW EXECUTE FUNCTION update_updated_at();
+
+CREATE TABLE IF NOT EXISTS issue_links (
+    task_id VARCHAR(64) PRIMARY KEY REFERENCES tasks(id) ON DELETE CASCADE,
+    integration_id VARCHAR(64) NOT NULL REFERENCES integrations(id) ON DELETE CASCADE,
+    issue_key VARCHAR(64) NOT NULL,
+    url VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issue_links_issue ON issue_links(integration_id, issue_key);
@@ -0,0 +1,5 @@
+-- 033: Run 结果发布到 PR/MR
+-- 项目配置 result_publishing 后，工作空间 git.pull_request 非空的任务在 Run 结束后
+-- 将摘要、验证结果与变更链接以评论和/或提交状态的形式发布到触发任务的 PR/MR
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS result_publishing JSONB;
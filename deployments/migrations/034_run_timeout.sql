@@ -0,0 +1,7 @@
+-- 034: Run 执行时限
+-- Task.TimeoutSeconds 在创建 Run 时写入执行快照（0 表示使用调度器 watchdog.default_timeout）；
+-- API Server 巡检超时的 Run 并标记为 timeout，节点在心跳指令中收到 timeout_runs 后终止执行
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS timeout_seconds INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_runs_status_started_at ON runs(status, started_at);
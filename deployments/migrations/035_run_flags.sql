@@ -0,0 +1,16 @@
+-- 035: Agent 输出内容审核
+-- 启用 moderation 后，API Server 在事件入库前检测 PII / 违规内容并掩码替换，
+-- 命中记录为 Run 标记；达到 abort_severity 的命中终止 Run
+
+CREATE TABLE IF NOT EXISTS run_flags (
+    id BIGSERIAL PRIMARY KEY,
+    run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    event_seq INTEGER NOT NULL DEFAULT 0,
+    detector VARCHAR(100) NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    count INTEGER NOT NULL DEFAULT 1,
+    aborted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_flags_run ON run_flags(run_id);
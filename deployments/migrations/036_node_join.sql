@@ -0,0 +1,23 @@
+-- 036: 节点自动注册
+-- 管理员生成一次性加入令牌，Node Manager 首次启动时兑换为节点专属凭证（Token + 客户端证书），
+-- 令牌与凭证只保存 SHA-256 哈希
+
+CREATE TABLE IF NOT EXISTS node_join_tokens (
+    id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    used_by_node TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS node_credentials (
+    node_id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    cert_serial TEXT NOT NULL DEFAULT '',
+    cert_expires_at TIMESTAMPTZ,
+    join_token_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
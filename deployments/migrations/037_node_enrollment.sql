@@ -0,0 +1,22 @@
+-- 037: 节点注册令牌
+-- 加入令牌支持多次使用（max_uses）与预设标签 / 节点池，加入的节点继承这些标签；
+-- 每次使用记录到 node_join_token_uses 供审计（令牌删除后记录保留）
+
+ALTER TABLE node_join_tokens ADD COLUMN IF NOT EXISTS max_uses INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE node_join_tokens ADD COLUMN IF NOT EXISTS use_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE node_join_tokens ADD COLUMN IF NOT EXISTS labels JSONB;
+ALTER TABLE node_join_tokens ADD COLUMN IF NOT EXISTS pool TEXT NOT NULL DEFAULT '';
+UPDATE node_join_tokens SET use_count = 1 WHERE used_at IS NOT NULL AND use_count = 0;
+
+ALTER TABLE node_credentials ADD COLUMN IF NOT EXISTS labels JSONB;
+
+CREATE TABLE IF NOT EXISTS node_join_token_uses (
+    id TEXT PRIMARY KEY,
+    token_id TEXT NOT NULL,
+    node_id TEXT NOT NULL,
+    hostname TEXT NOT NULL DEFAULT '',
+    remote_addr TEXT NOT NULL DEFAULT '',
+    used_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_node_join_token_uses_token ON node_join_token_uses(token_id, used_at DESC);
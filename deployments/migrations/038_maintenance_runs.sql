@@ -0,0 +1,19 @@
+-- 038: 存储维护执行记录
+-- 定时或手动触发的 VACUUM / incremental_vacuum / compact，记录每个表执行前后的占用与可回收空间估算
+
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    id TEXT PRIMARY KEY,
+    driver TEXT NOT NULL,
+    trigger_type TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT FALSE,
+    status TEXT NOT NULL,
+    tables JSONB,
+    reclaimable_bytes BIGINT NOT NULL DEFAULT 0,
+    reclaimed_bytes BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_maintenance_runs_started ON maintenance_runs(started_at DESC);
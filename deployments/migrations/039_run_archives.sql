@@ -0,0 +1,17 @@
+-- 039: Run 数据分层
+-- 终态 Run 按策略从 hot（事件在数据库）转为 warm（事件归档到 MinIO）再转为 cold（只保留摘要与归档位置）
+-- 没有归档记录的 Run 属于 hot
+
+CREATE TABLE IF NOT EXISTS run_archives (
+    run_id VARCHAR(64) PRIMARY KEY REFERENCES runs(id) ON DELETE CASCADE,
+    tier VARCHAR(16) NOT NULL,
+    archive_key TEXT NOT NULL,
+    event_count INTEGER NOT NULL DEFAULT 0,
+    archive_bytes BIGINT NOT NULL DEFAULT 0,
+    summary JSONB,
+    warm_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    cold_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_archives_tier ON run_archives(tier, warm_at);
+CREATE INDEX IF NOT EXISTS idx_runs_finished_at ON runs(finished_at);
@@ -0,0 +1,17 @@
+-- 040: Webhook 订阅
+-- 过滤条件（事件类型、项目、模板、节点、标签选择器、状态迁移）以 JSON 保存，由 API Server 编译为内存匹配器
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    filter JSONB,
+    created_by TEXT NOT NULL DEFAULT '',
+    last_delivery_at TIMESTAMPTZ,
+    last_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,5 @@
+-- 041: 任务与 Run 列表游标分页
+-- 列表按 (created_at DESC, id DESC) 排序并以该二元组作为游标，复合索引使翻页只扫描当前页
+
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at_id ON tasks(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_runs_created_at_id ON runs(created_at DESC, id DESC);
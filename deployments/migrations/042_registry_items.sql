@@ -0,0 +1,16 @@
+-- 042: 模板注册表同步记录
+-- 记录从远程注册表同步的任务模板、Agent 模板与技能的上游版本、已安装版本与固定状态
+
+CREATE TABLE IF NOT EXISTS registry_items (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    source TEXT NOT NULL,
+    upstream_version TEXT NOT NULL DEFAULT '',
+    upstream_digest TEXT NOT NULL DEFAULT '',
+    upstream_spec JSONB,
+    installed_version TEXT NOT NULL DEFAULT '',
+    pinned BOOLEAN NOT NULL DEFAULT FALSE,
+    synced_at TIMESTAMPTZ NOT NULL,
+    installed_at TIMESTAMPTZ
+);
@@ -0,0 +1,20 @@
+-- 043: Run Token 用量与费用
+-- 节点上报的 usage 事件按 Run 累加，供按任务、账号、Agent 类型、日期汇总的用量报表使用
+
+CREATE TABLE IF NOT EXISTS run_usage (
+    run_id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL,
+    account_id TEXT NOT NULL DEFAULT '',
+    agent_type TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens BIGINT NOT NULL DEFAULT 0,
+    output_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_read_tokens BIGINT NOT NULL DEFAULT 0,
+    cache_write_tokens BIGINT NOT NULL DEFAULT 0,
+    cost_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    day TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_run_usage_day ON run_usage(day);
@@ -0,0 +1,19 @@
+-- 044: 账号/项目月度预算
+-- 预算耗尽时调度器不再分派受约束的新 Run；run_usage 增加项目维度用于按项目统计已用量
+
+ALTER TABLE run_usage ADD COLUMN IF NOT EXISTS project_id TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS budgets (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    scope_id TEXT NOT NULL,
+    limit_tokens BIGINT NOT NULL DEFAULT 0,
+    limit_usd DOUBLE PRECISION NOT NULL DEFAULT 0,
+    alert_threshold DOUBLE PRECISION NOT NULL DEFAULT 0.8,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    alerted_period TEXT NOT NULL DEFAULT '',
+    exceeded_period TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
@@ -0,0 +1,10 @@
+-- 046: 项目事件保留策略
+-- 项目可覆盖 Run 事件在数据库中的保留天数，并在归档保留期满后删除归档对象（tier = purged）
+-- 归档记录增加项目维度，按项目查找到期的归档
+
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS retention JSONB;
+
+ALTER TABLE run_archives ADD COLUMN IF NOT EXISTS project_id VARCHAR(64) NOT NULL DEFAULT '';
+ALTER TABLE run_archives ADD COLUMN IF NOT EXISTS purged_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_run_archives_project ON run_archives(project_id, warm_at);
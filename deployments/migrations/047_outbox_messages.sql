@@ -0,0 +1,17 @@
+-- 047: 事务发件箱
+-- Run 与其调度消息在同一事务中写入，由 API Server 的发件箱中继投递到 Redis 调度队列，
+-- 写库后、入队前崩溃不再依赖保底轮询恢复
+
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id TEXT PRIMARY KEY,
+    topic TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at TIMESTAMPTZ NOT NULL,
+    published_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages(available_at) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published ON outbox_messages(published_at) WHERE published_at IS NOT NULL;
@@ -0,0 +1,16 @@
+-- 048: 自定义 Agent 类型
+-- 预定义类型内置在代码中；自定义类型保存通用适配器配置（启动命令模板、事件提取规则），由 NodeManager 构造通用 Adapter
+
+CREATE TABLE IF NOT EXISTS agent_types (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    image TEXT NOT NULL DEFAULT '',
+    auth_dir TEXT NOT NULL DEFAULT '',
+    auth_file TEXT NOT NULL DEFAULT '',
+    login_cmd TEXT NOT NULL DEFAULT '',
+    login_methods JSONB,
+    description TEXT NOT NULL DEFAULT '',
+    adapter JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
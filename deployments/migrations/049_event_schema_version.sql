@@ -0,0 +1,5 @@
+-- 049: 事件结构版本
+-- 入库时按 model.EventSchema 校验 Payload，记录匹配的结构版本（0 表示未定义结构或不匹配），
+-- UI 与分析按 (type, schema_version) 读取稳定字段
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS schema_version INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+-- 050: Run 终端附加
+-- 终端会话可附加到运行中的 Run（run_id），created_by 记录创建者，只有创建者与管理员可以连接
+
+ALTER TABLE terminal_sessions ADD COLUMN IF NOT EXISTS run_id VARCHAR(64);
+ALTER TABLE terminal_sessions ADD COLUMN IF NOT EXISTS created_by VARCHAR(64);
+CREATE INDEX IF NOT EXISTS idx_terminal_sessions_run ON terminal_sessions(run_id);
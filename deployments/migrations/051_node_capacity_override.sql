@@ -0,0 +1,4 @@
+-- 051: 节点并发配置覆盖
+-- 控制面通过 PATCH /api/v1/nodes/{id}/capacity 设置，随心跳响应下发给节点，心跳不会覆盖
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS capacity_override JSONB;
@@ -0,0 +1,17 @@
+-- 052: 创建类请求的幂等键
+-- POST /api/v1/tasks 与 POST /api/v1/tasks/{id}/runs 携带 Idempotency-Key 请求头时记录创建的资源与原始响应，
+-- 有效期内以相同键重试直接返回原始响应
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope TEXT NOT NULL,
+    idem_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response JSONB,
+    created_at TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (scope, idem_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
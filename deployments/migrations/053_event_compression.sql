@@ -0,0 +1,6 @@
+-- 053: Run 事件压缩与原始输出转存
+-- body_zstd：payload 与原始输出超过阈值时合并为一个 zstd 帧写入，payload / raw 置空（读取时透明解压）
+-- raw_ref：原始输出转存到对象存储（MinIO）后的位置，raw 置空
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS body_zstd BYTEA;
+ALTER TABLE events ADD COLUMN IF NOT EXISTS raw_ref TEXT;
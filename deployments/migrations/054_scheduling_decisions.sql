@@ -0,0 +1,19 @@
+-- 054: 调度决策审计
+-- 调度器每轮为 queued 的 Run 记录决策（策略、候选节点、淘汰原因），连续相同的决策合并为一条并累加次数，
+-- GET /api/v1/runs/{id} 返回最近的决策，用于排查 Run 长时间排队的原因
+
+CREATE TABLE IF NOT EXISTS scheduling_decisions (
+    id BIGSERIAL PRIMARY KEY,
+    run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    outcome VARCHAR(32) NOT NULL,
+    strategy VARCHAR(64) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    node_id VARCHAR(255) NOT NULL DEFAULT '',
+    candidates JSONB NOT NULL DEFAULT '[]',
+    signature VARCHAR(32) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduling_decisions_run ON scheduling_decisions(run_id, id);
@@ -0,0 +1,5 @@
+-- 055: 任务调度约束
+-- 任务声明亲和（调度到相关任务执行过的节点）、反亲和（避开执行同一任务其他 Run 的节点）
+-- 与分散（任务组的活跃 Run 按节点标签值均匀分布）约束
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS scheduling JSONB;
@@ -0,0 +1,4 @@
+-- 056: 节点污点
+-- 管理员通过 PATCH /api/v1/nodes/{id}/taints 设置，心跳不会覆盖；任务在 scheduling.tolerations 中声明容忍
+
+ALTER TABLE nodes ADD COLUMN IF NOT EXISTS taints JSONB;
@@ -0,0 +1,17 @@
+-- 057: 区域
+-- 节点通过 region 标签归属区域，任务通过 region 字段声明希望执行的区域，
+-- 调度器优先选择本区域节点，本区域不可用时按区域的回退策略（none / ordered / any）处理
+
+CREATE TABLE IF NOT EXISTS regions (
+    id VARCHAR(64) PRIMARY KEY,
+    display_name VARCHAR(200) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    fallback VARCHAR(16) NOT NULL DEFAULT '',
+    fallback_regions JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS region VARCHAR(64) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_tasks_region ON tasks(region);
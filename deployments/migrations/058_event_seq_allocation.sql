@@ -0,0 +1,17 @@
+-- 058: 事件序号由 API Server 分配
+-- 节点上报的序号改存 node_seq（按 (run_id, node_seq) 识别重试上报的重复事件），
+-- seq 由 API Server 按 Run 单调分配（计数器 runs.last_event_seq），与 API Server 自身写入的事件不再冲突
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS node_seq BIGINT;
+ALTER TABLE runs ADD COLUMN IF NOT EXISTS last_event_seq BIGINT NOT NULL DEFAULT 0;
+
+-- 已有事件：节点上报的事件节点序号即原 seq（API Server 写入的 budget_exceeded / run_orphaned 除外）
+UPDATE events SET node_seq = seq
+WHERE node_seq IS NULL AND type NOT IN ('budget_exceeded', 'run_orphaned');
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_run_id_node_seq ON events(run_id, node_seq);
+
+-- 计数器从已有事件的最大 seq 继续
+UPDATE runs SET last_event_seq = e.max_seq
+FROM (SELECT run_id, MAX(seq) AS max_seq FROM events GROUP BY run_id) e
+WHERE runs.id = e.run_id AND runs.last_event_seq < e.max_seq;
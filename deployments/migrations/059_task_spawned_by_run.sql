@@ -0,0 +1,7 @@
+-- 059: Agent 派生的子任务
+-- Agent 执行中通过 spawn_subtask 事件拆分任务，子任务的 parent_id 为 Run 所属任务，
+-- spawned_by_run_id 记录派生它的 Run（用于计算派生深度、限制子任务数量与汇总状态）
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS spawned_by_run_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_spawned_by_run ON tasks(spawned_by_run_id);
@@ -0,0 +1,5 @@
+-- 060: 任务的持久 Agent 会话
+-- 启用会话的任务在 Run 结束后保留执行容器与 CLI 会话历史，
+-- POST /api/v1/tasks/{id}/followup 以新的提示词在同一会话中继续执行
+
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS session JSONB;
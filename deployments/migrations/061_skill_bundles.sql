@@ -0,0 +1,16 @@
+-- 061: 技能包版本
+-- 技能包（skill.yaml 清单 + scripts/、prompts/ 等文件的 tar.gz）存于对象存储 skills/<skill_id>/<version>.tar.gz，
+-- 此表记录版本元数据；同一技能的版本不可变，Agent 模板以 <skill_id>@<version> 固定版本
+
+CREATE TABLE IF NOT EXISTS skill_bundles (
+    skill_id VARCHAR(64) NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    version VARCHAR(64) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    digest VARCHAR(80) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (skill_id, version)
+);
+
+CREATE INDEX IF NOT EXISTS idx_skill_bundles_created ON skill_bundles(skill_id, created_at DESC);
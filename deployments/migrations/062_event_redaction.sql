@@ -0,0 +1,14 @@
+-- 062: 事件内容掩码标记与原文保留
+-- redacted：入库前内容审核掩码了事件载荷或原始输出中的命中内容
+-- event_originals：启用 moderation.retain_originals 时保存被掩码事件的原文，仅管理员可查看
+
+ALTER TABLE events ADD COLUMN IF NOT EXISTS redacted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE IF NOT EXISTS event_originals (
+    run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    payload JSONB,
+    raw TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (run_id, seq)
+);
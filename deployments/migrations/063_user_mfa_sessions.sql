@@ -0,0 +1,27 @@
+-- 063: 用户两步验证与登录会话
+-- user_mfa 保存 TOTP 密钥（MASTER_KEY 加密）与未使用备用码的 SHA-256；
+-- user_sessions 每次登录一条，刷新令牌携带会话 ID，撤销后刷新令牌失效
+
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id      VARCHAR(36) PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret       TEXT        NOT NULL,
+    enabled      BOOLEAN     NOT NULL DEFAULT FALSE,
+    backup_codes TEXT        NOT NULL DEFAULT '[]',
+    last_step    BIGINT      NOT NULL DEFAULT 0,
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id           VARCHAR(64) PRIMARY KEY,
+    user_id      VARCHAR(36) NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    method       VARCHAR(32) NOT NULL DEFAULT 'password',
+    user_agent   TEXT        NOT NULL DEFAULT '',
+    ip           VARCHAR(64) NOT NULL DEFAULT '',
+    created_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at   TIMESTAMPTZ NOT NULL,
+    revoked_at   TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, created_at DESC);
@@ -0,0 +1,72 @@
+#!/bin/bash
+# install.sh — Agents Admin 离线安装引导脚本（随离线安装包分发）
+#
+# 在无外网环境中执行：
+#   1. docker load 所有镜像（images/*.tar）
+#   2. 安装 api-server / nodemanager 二进制与 systemd 服务
+#   3. 写入离线清单到 /etc/agents-admin，Setup 向导据此进入离线模式
+#
+# 用法（root）:
+#   ./install.sh [--api-server] [--node-manager]   # 默认两者都安装
+set -euo pipefail
+
+BUNDLE_DIR="$(cd "$(dirname "$0")" && pwd)"
+CONFIG_DIR="/etc/agents-admin"
+INSTALL_API=0
+INSTALL_NODE=0
+
+while [[ $# -gt 0 ]]; do
+  case "$1" in
+    --api-server)   INSTALL_API=1;  shift ;;
+    --node-manager) INSTALL_NODE=1; shift ;;
+    *)              echo "Unknown option: $1"; exit 1 ;;
+  esac
+done
+if [ "$INSTALL_API" -eq 0 ] && [ "$INSTALL_NODE" -eq 0 ]; then
+  INSTALL_API=1
+  INSTALL_NODE=1
+fi
+
+if [ "$(id -u)" -ne 0 ]; then
+  echo "ERROR: install.sh must be run as root"
+  exit 1
+fi
+
+# ---------- 1. 加载镜像 ----------
+if command -v docker &>/dev/null; then
+  echo "==> Loading container images..."
+  for tarball in "$BUNDLE_DIR"/images/*.tar; do
+    [ -f "$tarball" ] || continue
+    echo "  - $(basename "$tarball")"
+    docker load -i "$tarball" >/dev/null
+  done
+else
+  echo "WARNING: docker not found, skipping image load (install Docker from your offline mirror first)"
+fi
+
+# ---------- 2. 安装二进制与服务 ----------
+mkdir -p "$CONFIG_DIR"
+install -m 0644 "$BUNDLE_DIR/offline-bundle.json" "$CONFIG_DIR/offline-bundle.json"
+mkdir -p "$CONFIG_DIR/infra"
+install -m 0644 "$BUNDLE_DIR/docker-compose.infra.yml" "$CONFIG_DIR/infra/docker-compose.yml"
+
+if [ "$INSTALL_API" -eq 1 ]; then
+  echo "==> Installing api-server..."
+  install -m 0755 "$BUNDLE_DIR/bin/api-server" /usr/bin/agents-admin-api-server
+  install -m 0644 "$BUNDLE_DIR/agents-admin-api-server.service" /lib/systemd/system/
+fi
+if [ "$INSTALL_NODE" -eq 1 ]; then
+  echo "==> Installing nodemanager..."
+  install -m 0755 "$BUNDLE_DIR/bin/nodemanager" /usr/bin/agents-admin-node-manager
+  install -m 0644 "$BUNDLE_DIR/agents-admin-node-manager.service" /lib/systemd/system/
+fi
+
+if command -v systemctl &>/dev/null; then
+  systemctl daemon-reload
+  [ "$INSTALL_API" -eq 1 ] && systemctl enable --now agents-admin-api-server || true
+  [ "$INSTALL_NODE" -eq 1 ] && systemctl enable --now agents-admin-node-manager || true
+fi
+
+echo ""
+echo "Done. The Setup Wizard will start in air-gapped mode (ACME and external checks disabled)."
+echo "Check the access URL with: journalctl -u agents-admin-api-server -f"
@@ -0,0 +1,132 @@
+package accountpool
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Handler 账号池 HTTP 处理器
+type Handler struct {
+	store    Store
+	selector *Selector
+}
+
+// NewHandler 创建账号池处理器
+func NewHandler(store Store, selector *Selector) *Handler {
+	return &Handler{store: store, selector: selector}
+}
+
+// RegisterRoutes 注册账号池相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/accounts/usage", h.Usage)
+	mux.HandleFunc("POST /api/v1/runs/{id}/account/lease", h.Lease)
+	mux.HandleFunc("POST /api/v1/runs/{id}/account/failover", h.Failover)
+}
+
+// FailoverRequest 账号切换请求
+type FailoverRequest struct {
+	Reason            string `json:"reason,omitempty"`              // 命中的限流/额度错误信息
+	RetryAfterSeconds int    `json:"retry_after_seconds,omitempty"` // Agent 返回的重试等待时间（秒）
+}
+
+// Usage 列出账号的调度用量
+// GET /api/v1/accounts/usage
+//
+// 响应: {"accounts": [{"account_id": "acc-1", "agent_type": "qwen-code", "status": "authenticated", "active_runs": 1, "requests_today": 420, "limit": {"max_concurrent": 0, "daily_requests": 2000}, "available": true}]}
+func (h *Handler) Usage(w http.ResponseWriter, r *http.Request) {
+	usages, err := h.selector.Usage(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "accountpool.usage.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get account usage")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"accounts": usages})
+}
+
+// Lease 为 Run 租用账号池中负载最低的可用账号（NodeManager 开始执行时调用）
+// POST /api/v1/runs/{id}/account/lease
+//
+// 响应: {"run_id": "run-...", "account_id": "acc-2"}；账号池中没有可用账号时返回 409
+func (h *Handler) Lease(w http.ResponseWriter, r *http.Request) {
+	run := h.getRun(w, r)
+	if run == nil {
+		return
+	}
+	accountID, err := h.selector.Lease(r.Context(), run)
+	switch {
+	case errors.Is(err, ErrNoPool):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, ErrNoAccount):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		slog.ErrorContext(r.Context(), "accountpool.lease.failed", "run_id", run.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to lease account")
+	default:
+		writeJSON(w, http.StatusOK, &model.AccountLease{RunID: run.ID, AccountID: accountID})
+	}
+}
+
+// Failover 账号触发限流：账号进入冷却，Run 释放账号后重新排队（NodeManager 检测到限流错误时调用）
+// POST /api/v1/runs/{id}/account/failover
+//
+// 请求体: {"reason": "429 Too Many Requests", "retry_after_seconds": 600}（均可选）
+//
+// 响应: {"run_id": "run-...", "account_id": "acc-2", "exhausted_until": "...", "failovers": 1, "requeued": true}
+func (h *Handler) Failover(w http.ResponseWriter, r *http.Request) {
+	var req FailoverRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.RetryAfterSeconds < 0 {
+		writeError(w, http.StatusBadRequest, "retry_after_seconds must not be negative")
+		return
+	}
+	run := h.getRun(w, r)
+	if run == nil {
+		return
+	}
+	if len(PoolFromSnapshot(run.Snapshot)) == 0 {
+		writeError(w, http.StatusBadRequest, ErrNoPool.Error())
+		return
+	}
+	result, err := h.selector.Failover(r.Context(), run, time.Duration(req.RetryAfterSeconds)*time.Second, req.Reason)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "accountpool.failover.failed", "run_id", run.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to fail over account")
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// getRun 读取路径中的 Run，失败时写入错误响应并返回 nil
+func (h *Handler) getRun(w http.ResponseWriter, r *http.Request) *model.Run {
+	id := r.PathValue("id")
+	run, err := h.store.GetRun(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "accountpool.run.failed", "run_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return nil
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return nil
+	}
+	return run
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,385 @@
+// Package accountpool 账号池的限流感知调度
+//
+// 项目账号池中的 Run 在执行时才确定账号（快照 agent.account_pool 记录候选账号）：
+//   - 调度器分派前通过 Selector.Admit 检查候选账号中是否有未达额度的账号，全部耗尽时 Run 保持 queued
+//   - NodeManager 开始执行时调用 POST /api/v1/runs/{id}/account/lease，租用负载最低的可用账号
+//   - Agent 输出命中限流/额度错误时，NodeManager 调用 POST /api/v1/runs/{id}/account/failover：
+//     账号进入冷却，Run 释放账号后重新排队，由其他账号继续执行
+//
+// 并发数与每日请求数保存在 Redis（cache.AccountUsageCache），只统计从账号池租用账号的 Run。
+package accountpool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/cache"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+)
+
+// SnapshotKey 快照 agent 中记录候选账号 ID 列表的字段
+const SnapshotKey = "account_pool"
+
+// 默认配置
+const (
+	defaultCooldown     = 15 * time.Minute
+	defaultMaxFailovers = 3
+)
+
+var (
+	// ErrNoPool Run 的快照未声明账号池
+	ErrNoPool = errors.New("run has no account pool")
+	// ErrNoAccount 账号池中没有未达额度的已认证账号
+	ErrNoAccount = errors.New("no account available in account pool")
+)
+
+// Store 账号池调度需要的存储接口
+type Store interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetAccount(ctx context.Context, id string) (*model.Account, error)
+	ListAccounts(ctx context.Context) ([]*model.Account, error)
+	ReclaimRun(ctx context.Context, id, nodeID string, status model.RunStatus, errMsg string) (bool, error)
+}
+
+// Config 账号池调度配置
+type Config struct {
+	Limits       map[string]model.AccountLimit // 键为账号 ID 或 Agent 类型 ID（账号 ID 优先）
+	Cooldown     time.Duration                 // 未配置每日额度的账号触发限流后的冷却时间
+	MaxFailovers int                           // 单个 Run 的账号切换次数上限，超过后 Run 按失败结束
+}
+
+// Selector 按账号负载选择账号（实现 scheduler.AccountGate）
+type Selector struct {
+	store Store
+	usage cache.AccountUsageCache
+	queue queue.SchedulerQueue // 切换账号后重新入队（可选，nil 时由保底轮询处理）
+	now   func() time.Time
+
+	mu  sync.RWMutex
+	cfg Config
+}
+
+// NewSelector 创建账号选择器
+func NewSelector(store Store, usage cache.AccountUsageCache) *Selector {
+	if usage == nil {
+		usage = cache.NewNoOpCache()
+	}
+	s := &Selector{store: store, usage: usage, now: time.Now}
+	s.SetConfig(Config{})
+	return s
+}
+
+// SetConfig 设置账号额度与冷却配置
+func (s *Selector) SetConfig(cfg Config) {
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultCooldown
+	}
+	if cfg.MaxFailovers <= 0 {
+		cfg.MaxFailovers = defaultMaxFailovers
+	}
+	s.mu.Lock()
+	s.cfg = cfg
+	s.mu.Unlock()
+}
+
+// SetQueue 设置调度队列（账号切换后 Run 重新入队）
+func (s *Selector) SetQueue(q queue.SchedulerQueue) {
+	s.queue = q
+}
+
+func (s *Selector) config() Config {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.cfg
+}
+
+// limitFor 账号额度：账号 ID 的配置优先，其次 Agent 类型的配置
+func (c Config) limitFor(account *model.Account) model.AccountLimit {
+	if l, ok := c.Limits[account.ID]; ok {
+		return l
+	}
+	return c.Limits[account.AgentTypeID]
+}
+
+// PoolFromSnapshot 解析快照 agent.account_pool 中的候选账号 ID
+func PoolFromSnapshot(snapshot json.RawMessage) []string {
+	if len(snapshot) == 0 {
+		return nil
+	}
+	var spec struct {
+		Agent map[string]json.RawMessage `json:"agent"`
+	}
+	if err := json.Unmarshal(snapshot, &spec); err != nil {
+		return nil
+	}
+	var pool []string
+	if raw, ok := spec.Agent[SnapshotKey]; ok {
+		json.Unmarshal(raw, &pool)
+	}
+	return pool
+}
+
+// candidate 可租用的账号及其用量
+type candidate struct {
+	account *model.Account
+	usage   *cache.AccountUsage
+	limit   model.AccountLimit
+}
+
+// available 账号未在冷却中且并发数、当日请求数均未达上限
+func (c candidate) available(now time.Time) bool {
+	if c.account.Status != model.AccountStatusAuthenticated {
+		return false
+	}
+	if c.usage.ExhaustedUntil != nil && c.usage.ExhaustedUntil.After(now) {
+		return false
+	}
+	if c.limit.MaxConcurrent > 0 && c.usage.ActiveRuns >= c.limit.MaxConcurrent {
+		return false
+	}
+	return c.limit.DailyRequests <= 0 || c.usage.RequestsToday < int64(c.limit.DailyRequests)
+}
+
+// ratio 用量占额度的比例，未配置额度时按原始计数比较
+func ratio(used int64, limit int) float64 {
+	if limit <= 0 {
+		return float64(used)
+	}
+	return float64(used) / float64(limit)
+}
+
+// lessLoaded 依次比较并发占用比例、当日额度占用比例、当日请求数
+func lessLoaded(a, b candidate) bool {
+	if ra, rb := ratio(int64(a.usage.ActiveRuns), a.limit.MaxConcurrent), ratio(int64(b.usage.ActiveRuns), b.limit.MaxConcurrent); ra != rb {
+		return ra < rb
+	}
+	if ra, rb := ratio(a.usage.RequestsToday, a.limit.DailyRequests), ratio(b.usage.RequestsToday, b.limit.DailyRequests); ra != rb {
+		return ra < rb
+	}
+	if a.usage.RequestsToday != b.usage.RequestsToday {
+		return a.usage.RequestsToday < b.usage.RequestsToday
+	}
+	return a.account.ID < b.account.ID
+}
+
+// candidates 返回账号池中当前可用的账号，负载最低的在前
+func (s *Selector) candidates(ctx context.Context, pool []string) ([]candidate, error) {
+	cfg := s.config()
+	now := s.now()
+	var result []candidate
+	for _, id := range pool {
+		account, err := s.store.GetAccount(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get account %s: %w", id, err)
+		}
+		if account == nil {
+			continue
+		}
+		usage, err := s.usage.GetAccountUsage(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("get account usage %s: %w", id, err)
+		}
+		c := candidate{account: account, usage: usage, limit: cfg.limitFor(account)}
+		if c.available(now) {
+			result = append(result, c)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return lessLoaded(result[i], result[j]) })
+	return result, nil
+}
+
+// Admit 判断账号池 Run 是否可以分派（实现 scheduler.AccountGate）
+//
+// 账号池中没有可用账号时返回 false，Run 保持 queued，由保底轮询在额度恢复后重新调度；
+// 读取账号或用量失败时放行，由 NodeManager 租用账号时再判定。
+func (s *Selector) Admit(ctx context.Context, run *model.Run, _ *model.Task) bool {
+	pool := PoolFromSnapshot(run.Snapshot)
+	if len(pool) == 0 {
+		return true
+	}
+	if leased, err := s.usage.GetAccountLease(ctx, run.ID); err == nil && leased != "" {
+		return true
+	}
+	candidates, err := s.candidates(ctx, pool)
+	if err != nil {
+		slog.ErrorContext(ctx, "accountpool.admit.failed", "run_id", run.ID, "error", err)
+		return true
+	}
+	if len(candidates) == 0 {
+		slog.InfoContext(ctx, "accountpool.hold", "run_id", run.ID, "pool_size", len(pool))
+		return false
+	}
+	return true
+}
+
+// Lease 为 Run 租用账号池中负载最低的可用账号
+//
+// Run 已持有租约时直接返回该账号（NodeManager 重试或 Run 被回收后重新执行）。
+func (s *Selector) Lease(ctx context.Context, run *model.Run) (string, error) {
+	pool := PoolFromSnapshot(run.Snapshot)
+	if len(pool) == 0 {
+		return "", ErrNoPool
+	}
+	leased, err := s.usage.GetAccountLease(ctx, run.ID)
+	if err != nil {
+		return "", err
+	}
+	if leased != "" {
+		return leased, nil
+	}
+
+	candidates, err := s.candidates(ctx, pool)
+	if err != nil {
+		return "", err
+	}
+	for _, c := range candidates {
+		ok, err := s.usage.AcquireAccount(ctx, c.account.ID, run.ID, c.limit.MaxConcurrent, c.limit.DailyRequests)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			slog.InfoContext(ctx, "accountpool.lease", "run_id", run.ID, "account_id", c.account.ID,
+				"active_runs", c.usage.ActiveRuns+1, "requests_today", c.usage.RequestsToday+1)
+			return c.account.ID, nil
+		}
+	}
+	return "", ErrNoAccount
+}
+
+// Failover 账号触发限流：账号进入冷却，释放租约并将 Run 重新排队
+//
+// 冷却截止时间依次取 retryAfter、下一个 UTC 零点（账号配置了每日额度）、配置的冷却时间。
+// Run 的切换次数超过上限，或 Run 已不再分配给执行节点时不重新排队。
+func (s *Selector) Failover(ctx context.Context, run *model.Run, retryAfter time.Duration, reason string) (*model.AccountFailover, error) {
+	cfg := s.config()
+	result := &model.AccountFailover{RunID: run.ID}
+
+	accountID, err := s.usage.ReleaseAccount(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	if accountID != "" {
+		account, err := s.store.GetAccount(ctx, accountID)
+		if err != nil {
+			return nil, err
+		}
+		until := s.cooldownUntil(cfg, account, retryAfter)
+		if err := s.usage.MarkAccountExhausted(ctx, accountID, until); err != nil {
+			return nil, err
+		}
+		result.AccountID, result.ExhaustedUntil = accountID, &until
+	}
+
+	if result.Failovers, err = s.usage.IncrAccountFailovers(ctx, run.ID); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "accountpool.failover", "run_id", run.ID, "account_id", accountID, "failovers", result.Failovers, "reason", reason)
+	if result.Failovers > int64(cfg.MaxFailovers) {
+		slog.InfoContext(ctx, "accountpool.failover.give_up", "run_id", run.ID, "max_failovers", cfg.MaxFailovers)
+		return result, nil
+	}
+	if run.NodeID == nil {
+		return result, nil
+	}
+
+	ok, err := s.store.ReclaimRun(ctx, run.ID, *run.NodeID, model.RunStatusQueued, "")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		// Run 已到达终态或已被回收
+		return result, nil
+	}
+	result.Requeued = true
+	s.requeue(ctx, run)
+	return result, nil
+}
+
+// cooldownUntil 计算账号的冷却截止时间
+func (s *Selector) cooldownUntil(cfg Config, account *model.Account, retryAfter time.Duration) time.Time {
+	now := s.now()
+	if retryAfter > 0 {
+		return now.Add(retryAfter)
+	}
+	if account != nil && cfg.limitFor(account).DailyRequests > 0 {
+		y, m, d := now.UTC().Date()
+		return time.Date(y, m, d+1, 0, 0, 0, 0, time.UTC)
+	}
+	return now.Add(cfg.Cooldown)
+}
+
+// requeue 将 Run 加入调度队列，队列支持优先级时按 Run 优先级入队
+func (s *Selector) requeue(ctx context.Context, run *model.Run) {
+	if s.queue == nil {
+		return
+	}
+	var err error
+	if pq, ok := s.queue.(queue.PrioritySchedulerQueue); ok {
+		_, err = pq.ScheduleRunWithPriority(ctx, run.ID, run.TaskID, string(run.Priority))
+	} else {
+		_, err = s.queue.ScheduleRun(ctx, run.ID, run.TaskID)
+	}
+	if err != nil {
+		// 入队失败由保底轮询处理
+		slog.ErrorContext(ctx, "accountpool.requeue.failed", "run_id", run.ID, "error", err)
+	}
+}
+
+// RunFinished Run 到达终态时释放账号租约（注册到 run.Handler.OnRunFinished）
+func (s *Selector) RunFinished(run *model.Run) {
+	if len(PoolFromSnapshot(run.Snapshot)) == 0 {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		accountID, err := s.usage.ReleaseAccount(ctx, run.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "accountpool.release.failed", "run_id", run.ID, "error", err)
+			return
+		}
+		if accountID != "" {
+			slog.InfoContext(ctx, "accountpool.release", "run_id", run.ID, "account_id", accountID, "status", run.Status)
+		}
+	}()
+}
+
+// Usage 列出全部账号的调度用量
+func (s *Selector) Usage(ctx context.Context) ([]model.AccountUsage, error) {
+	accounts, err := s.store.ListAccounts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	cfg := s.config()
+	now := s.now()
+	result := make([]model.AccountUsage, 0, len(accounts))
+	for _, account := range accounts {
+		usage, err := s.usage.GetAccountUsage(ctx, account.ID)
+		if err != nil {
+			return nil, err
+		}
+		c := candidate{account: account, usage: usage, limit: cfg.limitFor(account)}
+		view := model.AccountUsage{
+			AccountID:     account.ID,
+			Name:          account.Name,
+			AgentType:     account.AgentTypeID,
+			Status:        account.Status,
+			ActiveRuns:    usage.ActiveRuns,
+			RequestsToday: usage.RequestsToday,
+			Limit:         c.limit,
+			Available:     c.available(now),
+		}
+		if usage.ExhaustedUntil != nil && usage.ExhaustedUntil.After(now) {
+			view.ExhaustedUntil = usage.ExhaustedUntil
+		}
+		result = append(result, view)
+	}
+	return result, nil
+}
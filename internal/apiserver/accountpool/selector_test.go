@@ -0,0 +1,251 @@
+package accountpool
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/cache"
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存实现的账号池存储
+type memStore struct {
+	runs      map[string]*model.Run
+	accounts  map[string]*model.Account
+	reclaimed []string
+}
+
+func (m *memStore) GetRun(_ context.Context, id string) (*model.Run, error) { return m.runs[id], nil }
+func (m *memStore) GetAccount(_ context.Context, id string) (*model.Account, error) {
+	return m.accounts[id], nil
+}
+func (m *memStore) ListAccounts(context.Context) ([]*model.Account, error) {
+	var list []*model.Account
+	for _, id := range []string{"acc-1", "acc-2", "acc-3"} {
+		if a, ok := m.accounts[id]; ok {
+			list = append(list, a)
+		}
+	}
+	return list, nil
+}
+func (m *memStore) ReclaimRun(_ context.Context, id, nodeID string, status model.RunStatus, _ string) (bool, error) {
+	run := m.runs[id]
+	if run == nil || run.NodeID == nil || *run.NodeID != nodeID || status != model.RunStatusQueued {
+		return false, nil
+	}
+	run.Status, run.NodeID = model.RunStatusQueued, nil
+	m.reclaimed = append(m.reclaimed, id)
+	return true, nil
+}
+
+// memUsage 内存实现的账号用量计数（与 Redis 实现语义一致）
+type memUsage struct {
+	cache.NoOpCache
+	active    map[string]map[string]bool
+	requests  map[string]int64
+	leases    map[string]string
+	exhausted map[string]time.Time
+	failovers map[string]int64
+}
+
+func newMemUsage() *memUsage {
+	return &memUsage{
+		active: map[string]map[string]bool{}, requests: map[string]int64{}, leases: map[string]string{},
+		exhausted: map[string]time.Time{}, failovers: map[string]int64{},
+	}
+}
+
+func (u *memUsage) AcquireAccount(_ context.Context, accountID, runID string, maxConcurrent, dailyLimit int) (bool, error) {
+	if leased, ok := u.leases[runID]; ok {
+		return leased == accountID, nil
+	}
+	if until, ok := u.exhausted[accountID]; ok && until.After(time.Now()) {
+		return false, nil
+	}
+	if maxConcurrent > 0 && len(u.active[accountID]) >= maxConcurrent {
+		return false, nil
+	}
+	if dailyLimit > 0 && u.requests[accountID] >= int64(dailyLimit) {
+		return false, nil
+	}
+	if u.active[accountID] == nil {
+		u.active[accountID] = map[string]bool{}
+	}
+	u.active[accountID][runID] = true
+	u.requests[accountID]++
+	u.leases[runID] = accountID
+	return true, nil
+}
+
+func (u *memUsage) ReleaseAccount(_ context.Context, runID string) (string, error) {
+	accountID := u.leases[runID]
+	delete(u.leases, runID)
+	delete(u.active[accountID], runID)
+	return accountID, nil
+}
+
+func (u *memUsage) GetAccountLease(_ context.Context, runID string) (string, error) {
+	return u.leases[runID], nil
+}
+
+func (u *memUsage) MarkAccountExhausted(_ context.Context, accountID string, until time.Time) error {
+	u.exhausted[accountID] = until
+	return nil
+}
+
+func (u *memUsage) GetAccountUsage(_ context.Context, accountID string) (*cache.AccountUsage, error) {
+	usage := &cache.AccountUsage{ActiveRuns: len(u.active[accountID]), RequestsToday: u.requests[accountID]}
+	if until, ok := u.exhausted[accountID]; ok {
+		usage.ExhaustedUntil = &until
+	}
+	return usage, nil
+}
+
+func (u *memUsage) IncrAccountFailovers(_ context.Context, runID string) (int64, error) {
+	u.failovers[runID]++
+	return u.failovers[runID], nil
+}
+
+func poolRun(id string, pool ...string) *model.Run {
+	snapshot, _ := json.Marshal(map[string]interface{}{
+		"agent": map[string]interface{}{"type": "qwen-code", "account_id": pool[0], SnapshotKey: pool},
+	})
+	node := "node-1"
+	return &model.Run{ID: id, TaskID: "task-1", Status: model.RunStatusRunning, NodeID: &node, Snapshot: snapshot}
+}
+
+func newTestSelector(t *testing.T) (*Selector, *memStore, *memUsage) {
+	t.Helper()
+	store := &memStore{
+		runs: map[string]*model.Run{},
+		accounts: map[string]*model.Account{
+			"acc-1": {ID: "acc-1", AgentTypeID: "qwen-code", Status: model.AccountStatusAuthenticated},
+			"acc-2": {ID: "acc-2", AgentTypeID: "qwen-code", Status: model.AccountStatusAuthenticated},
+			"acc-3": {ID: "acc-3", AgentTypeID: "qwen-code", Status: model.AccountStatusExpired},
+		},
+	}
+	usage := newMemUsage()
+	s := NewSelector(store, usage)
+	s.SetConfig(Config{Limits: map[string]model.AccountLimit{
+		"qwen-code": {DailyRequests: 2},
+		"acc-2":     {MaxConcurrent: 1},
+	}})
+	return s, store, usage
+}
+
+func TestLease_PicksLeastLoadedAccount(t *testing.T) {
+	s, _, usage := newTestSelector(t)
+	ctx := context.Background()
+
+	// 两个账号均无执行中的 Run，按每日额度占用比较：acc-1 已用 1/2，acc-2 未使用
+	usage.requests["acc-1"] = 1
+	acc, err := s.Lease(ctx, poolRun("run-1", "acc-1", "acc-2", "acc-3"))
+	require.NoError(t, err)
+	assert.Equal(t, "acc-2", acc)
+
+	// 重复租用返回同一账号
+	acc, err = s.Lease(ctx, poolRun("run-1", "acc-1", "acc-2", "acc-3"))
+	require.NoError(t, err)
+	assert.Equal(t, "acc-2", acc)
+
+	// acc-2 并发已满，acc-3 未认证
+	acc, err = s.Lease(ctx, poolRun("run-2", "acc-1", "acc-2", "acc-3"))
+	require.NoError(t, err)
+	assert.Equal(t, "acc-1", acc)
+
+	// acc-1 当日额度耗尽
+	_, err = s.Lease(ctx, poolRun("run-3", "acc-1", "acc-2", "acc-3"))
+	assert.ErrorIs(t, err, ErrNoAccount)
+	assert.False(t, s.Admit(ctx, poolRun("run-3", "acc-1", "acc-2", "acc-3"), nil))
+
+	// 已持有租约的 Run 放行，未声明账号池的 Run 不受限
+	assert.True(t, s.Admit(ctx, poolRun("run-1", "acc-1", "acc-2"), nil))
+	assert.True(t, s.Admit(ctx, &model.Run{ID: "run-4"}, nil))
+	_, err = s.Lease(ctx, &model.Run{ID: "run-4"})
+	assert.ErrorIs(t, err, ErrNoPool)
+}
+
+func TestFailover_CooldownAndRequeue(t *testing.T) {
+	s, store, usage := newTestSelector(t)
+	ctx := context.Background()
+	now := time.Date(2026, 10, 17, 15, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	run := poolRun("run-1", "acc-1", "acc-2")
+	store.runs[run.ID] = run
+	acc, err := s.Lease(ctx, run)
+	require.NoError(t, err)
+	require.Equal(t, "acc-1", acc)
+
+	// 配置了每日额度的账号冷却到下一个 UTC 零点
+	result, err := s.Failover(ctx, run, 0, "429 Too Many Requests")
+	require.NoError(t, err)
+	assert.Equal(t, "acc-1", result.AccountID)
+	assert.Equal(t, time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), *result.ExhaustedUntil)
+	assert.True(t, result.Requeued)
+	assert.Equal(t, []string{"run-1"}, store.reclaimed)
+	assert.Empty(t, usage.leases)
+
+	// 重新执行时切换到 acc-2；Retry-After 优先于冷却配置
+	node := "node-2"
+	run.NodeID = &node
+	acc, err = s.Lease(ctx, run)
+	require.NoError(t, err)
+	assert.Equal(t, "acc-2", acc)
+	result, err = s.Failover(ctx, run, 10*time.Minute, "rate limit")
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(10*time.Minute), *result.ExhaustedUntil)
+	assert.Equal(t, int64(2), result.Failovers)
+
+	// 超过切换次数上限后不再重新排队
+	s.SetConfig(Config{MaxFailovers: 2})
+	run.NodeID = &node
+	result, err = s.Failover(ctx, run, 0, "quota")
+	require.NoError(t, err)
+	assert.False(t, result.Requeued)
+	assert.Len(t, store.reclaimed, 2)
+}
+
+func TestHandler_LeaseAndUsage(t *testing.T) {
+	s, store, usage := newTestSelector(t)
+	store.runs["run-1"] = poolRun("run-1", "acc-1")
+	usage.exhausted["acc-1"] = time.Now().Add(time.Hour)
+	mux := http.NewServeMux()
+	NewHandler(store, s).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/account/lease", nil))
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-x/account/lease", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/account/failover",
+		strings.NewReader(`{"retry_after_seconds": -1}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/accounts/usage", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var resp struct {
+		Accounts []model.AccountUsage `json:"accounts"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Accounts, 3)
+	assert.False(t, resp.Accounts[0].Available)
+	assert.NotNil(t, resp.Accounts[0].ExhaustedUntil)
+	assert.Equal(t, 2, resp.Accounts[0].Limit.DailyRequests)
+	assert.True(t, resp.Accounts[1].Available)
+	assert.Equal(t, 1, resp.Accounts[1].Limit.MaxConcurrent)
+	assert.False(t, resp.Accounts[2].Available)
+}
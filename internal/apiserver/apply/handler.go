@@ -0,0 +1,510 @@
+// Package apply 声明式配置 - HTTP 处理
+//
+// POST /api/v1/apply 接收一组期望状态的资源（项目、任务模板、Agent 模板、Webhook、代理），
+// 按名称与现有资源比对后创建、更新或删除，使控制面配置可以放在 Git 仓库中管理。
+//
+// 只管理请求中出现的资源类型：未出现的类型保持不变，出现但为空列表的类型会删除全部现有资源。
+// 内置模板不参与比对。仅限管理员。
+package apply
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/webhook"
+	"agents-admin/internal/shared/model"
+)
+
+// Store 定义声明式配置需要的存储接口（用于测试 mock）
+type Store interface {
+	ListProjects(ctx context.Context) ([]*model.Project, error)
+	CreateProject(ctx context.Context, project *model.Project) error
+	UpdateProject(ctx context.Context, project *model.Project) error
+	DeleteProject(ctx context.Context, id string) error
+
+	ListTaskTemplates(ctx context.Context, category string) ([]*model.TaskTemplate, error)
+	CreateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error
+	UpdateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error
+	DeleteTaskTemplate(ctx context.Context, id string) error
+
+	ListAgentTemplates(ctx context.Context, category string) ([]*model.AgentTemplate, error)
+	CreateAgentTemplate(ctx context.Context, tmpl *model.AgentTemplate) error
+	UpdateAgentTemplate(ctx context.Context, tmpl *model.AgentTemplate) error
+	DeleteAgentTemplate(ctx context.Context, id string) error
+
+	ListWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	CreateWebhook(ctx context.Context, w *model.Webhook) error
+	UpdateWebhook(ctx context.Context, w *model.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+
+	ListProxies(ctx context.Context) ([]*model.Proxy, error)
+	CreateProxy(ctx context.Context, proxy *model.Proxy) error
+	UpdateProxy(ctx context.Context, proxy *model.Proxy) error
+	DeleteProxy(ctx context.Context, id string) error
+}
+
+// Reloader Webhook 订阅变更后重新加载（Webhook 投递未启用时为 nil）
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// Handler 声明式配置 HTTP 处理器
+type Handler struct {
+	store    Store
+	webhooks Reloader
+}
+
+// NewHandler 创建声明式配置处理器
+func NewHandler(store Store, webhooks Reloader) *Handler {
+	return &Handler{store: store, webhooks: webhooks}
+}
+
+// RegisterRoutes 注册声明式配置路由（仅限管理员）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/apply", auth.AdminOnly(h.Apply))
+}
+
+// Bundle 期望状态
+//
+// 列表为 nil（字段未出现）表示不管理该类资源。
+type Bundle struct {
+	DryRun         bool                   `json:"dry_run,omitempty"`
+	Projects       []*model.Project       `json:"projects,omitempty"`
+	TaskTemplates  []*model.TaskTemplate  `json:"task_templates,omitempty"`
+	AgentTemplates []*model.AgentTemplate `json:"agent_templates,omitempty"`
+	Webhooks       []*WebhookSpec         `json:"webhooks,omitempty"`
+	Proxies        []*ProxySpec           `json:"proxies,omitempty"`
+
+	// Schedules 系统没有定时任务资源，出现时拒绝请求，避免被误认为已生效
+	Schedules json.RawMessage `json:"schedules,omitempty"`
+}
+
+// WebhookSpec Webhook 期望状态（secret 为空时创建自动生成、更新保留原值）
+type WebhookSpec struct {
+	Name    string              `json:"name"`
+	URL     string              `json:"url"`
+	Secret  string              `json:"secret,omitempty"`
+	Enabled *bool               `json:"enabled,omitempty"` // 默认启用
+	Filter  model.WebhookFilter `json:"filter"`
+}
+
+// ProxySpec 代理期望状态（password 未提供时更新保留原值）
+type ProxySpec struct {
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	Host      string  `json:"host"`
+	Port      int     `json:"port"`
+	Username  *string `json:"username,omitempty"`
+	Password  *string `json:"password,omitempty"`
+	NoProxy   *string `json:"no_proxy,omitempty"`
+	IsDefault bool    `json:"is_default"`
+}
+
+// Result 比对与执行结果
+type Result struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []*Change      `json:"changes"`
+	Summary map[string]int `json:"summary"` // 各动作的资源数
+	Error   string         `json:"error,omitempty"`
+}
+
+// Apply 按期望状态比对并执行变更
+// POST /api/v1/apply
+//
+// 请求体: {"dry_run": true, "projects": [...], "task_templates": [...], "agent_templates": [...], "webhooks": [...], "proxies": [...]}
+// 响应: 200 + 变更列表；期望状态无效时 400 且不执行任何变更；执行中途失败时 500，已执行的变更不回滚
+func (h *Handler) Apply(w http.ResponseWriter, r *http.Request) {
+	var bundle Bundle
+	if err := json.NewDecoder(r.Body).Decode(&bundle); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(bundle.Schedules) > 0 {
+		writeError(w, http.StatusBadRequest, "schedules are not supported")
+		return
+	}
+
+	changes, err := h.plan(r.Context(), &bundle, auth.GetAuthUser(r.Context()))
+	if err != nil {
+		var invalid invalidError
+		if errors.As(err, &invalid) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		slog.ErrorContext(r.Context(), "apply.plan.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load current state")
+		return
+	}
+
+	result := &Result{DryRun: bundle.DryRun, Changes: changes, Summary: summarize(changes)}
+	if bundle.DryRun {
+		writeJSON(w, http.StatusOK, result)
+		return
+	}
+
+	webhooksChanged := false
+	for _, c := range changes {
+		if c.exec == nil {
+			continue
+		}
+		if err := c.exec(r.Context()); err != nil {
+			slog.ErrorContext(r.Context(), "apply.change.failed", "kind", c.Kind, "name", c.Name, "action", c.Action, "error", err)
+			c.Error = err.Error()
+			result.Error = fmt.Sprintf("failed to %s %s %q", c.Action, c.Kind, c.Name)
+			break
+		}
+		if c.Kind == kindWebhook {
+			webhooksChanged = true
+		}
+		slog.InfoContext(r.Context(), "apply.change.applied", "kind", c.Kind, "name", c.Name, "action", c.Action, "id", c.ID)
+	}
+	if webhooksChanged && h.webhooks != nil {
+		if err := h.webhooks.Reload(r.Context()); err != nil {
+			slog.ErrorContext(r.Context(), "apply.webhook_reload.failed", "error", err)
+		}
+	}
+
+	if result.Error != "" {
+		writeJSON(w, http.StatusInternalServerError, result)
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// 资源类型名称（与请求体字段一一对应）
+const (
+	kindProject       = "project"
+	kindTaskTemplate  = "task_template"
+	kindAgentTemplate = "agent_template"
+	kindWebhook       = "webhook"
+	kindProxy         = "proxy"
+)
+
+// invalidError 期望状态本身无效（返回 400）
+type invalidError struct{ error }
+
+func invalidf(format string, args ...interface{}) error {
+	return invalidError{fmt.Errorf(format, args...)}
+}
+
+// plan 校验期望状态并计算全部变更：创建与更新按资源类型顺序在前，删除在后
+func (h *Handler) plan(ctx context.Context, b *Bundle, user *auth.AuthUser) ([]*Change, error) {
+	var changes, deletes []*Change
+	add := func(c, d []*Change, err error) error {
+		if err != nil {
+			return invalidError{err}
+		}
+		changes = append(changes, c...)
+		deletes = append(deletes, d...)
+		return nil
+	}
+
+	if b.Projects != nil {
+		if err := validateProjects(b.Projects); err != nil {
+			return nil, err
+		}
+		existing, err := h.store.ListProjects(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(h.projectKind().plan(b.Projects, existing)); err != nil {
+			return nil, err
+		}
+	}
+	if b.TaskTemplates != nil {
+		for _, t := range b.TaskTemplates {
+			if t == nil || strings.TrimSpace(t.Name) == "" {
+				return nil, invalidf("task_templates: name is required")
+			}
+			t.Name = strings.TrimSpace(t.Name)
+			t.IsBuiltin = false
+		}
+		existing, err := h.store.ListTaskTemplates(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		if err := add(h.taskTemplateKind().plan(b.TaskTemplates, existing)); err != nil {
+			return nil, err
+		}
+	}
+	if b.AgentTemplates != nil {
+		for _, t := range b.AgentTemplates {
+			if t == nil || strings.TrimSpace(t.Name) == "" {
+				return nil, invalidf("agent_templates: name is required")
+			}
+			t.Name = strings.TrimSpace(t.Name)
+			t.IsBuiltin = false
+		}
+		existing, err := h.store.ListAgentTemplates(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		if err := add(h.agentTemplateKind().plan(b.AgentTemplates, existing)); err != nil {
+			return nil, err
+		}
+	}
+	if b.Webhooks != nil {
+		desired, err := webhooksFromSpecs(b.Webhooks, user)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := h.store.ListWebhooks(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(h.webhookKind().plan(desired, existing)); err != nil {
+			return nil, err
+		}
+	}
+	if b.Proxies != nil {
+		desired, err := proxiesFromSpecs(b.Proxies)
+		if err != nil {
+			return nil, err
+		}
+		existing, err := h.store.ListProxies(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if err := add(h.proxyKind().plan(desired, existing)); err != nil {
+			return nil, err
+		}
+	}
+
+	changes = append(changes, deletes...)
+	if changes == nil {
+		changes = []*Change{}
+	}
+	return changes, nil
+}
+
+func summarize(changes []*Change) map[string]int {
+	summary := map[string]int{ActionCreate: 0, ActionUpdate: 0, ActionDelete: 0, ActionUnchanged: 0}
+	for _, c := range changes {
+		summary[c.Action]++
+	}
+	return summary
+}
+
+// ============================================================================
+// 各类资源
+// ============================================================================
+
+func validateProjects(projects []*model.Project) error {
+	for _, p := range projects {
+		if p == nil {
+			return invalidf("projects: name is required")
+		}
+		p.Name = strings.TrimSpace(p.Name)
+		if err := p.Validate(); err != nil {
+			return invalidf("project %q: %v", p.Name, err)
+		}
+	}
+	return nil
+}
+
+func (h *Handler) projectKind() kind[model.Project] {
+	return kind[model.Project]{
+		name:   kindProject,
+		nameOf: func(p *model.Project) string { return p.Name },
+		idOf:   func(p *model.Project) string { return p.ID },
+		adopt: func(d, e *model.Project) {
+			d.ID, d.CreatedAt, d.UpdatedAt = e.ID, e.CreatedAt, e.UpdatedAt
+		},
+		create: func(ctx context.Context, p *model.Project) error {
+			p.ID = generateID("proj")
+			p.CreatedAt = time.Now()
+			p.UpdatedAt = p.CreatedAt
+			return h.store.CreateProject(ctx, p)
+		},
+		update: func(ctx context.Context, p *model.Project) error {
+			p.UpdatedAt = time.Now()
+			return h.store.UpdateProject(ctx, p)
+		},
+		delete: h.store.DeleteProject,
+	}
+}
+
+func (h *Handler) taskTemplateKind() kind[model.TaskTemplate] {
+	return kind[model.TaskTemplate]{
+		name:      kindTaskTemplate,
+		nameOf:    func(t *model.TaskTemplate) string { return t.Name },
+		idOf:      func(t *model.TaskTemplate) string { return t.ID },
+		protected: func(t *model.TaskTemplate) bool { return t.IsBuiltin },
+		adopt: func(d, e *model.TaskTemplate) {
+			d.ID, d.CreatedAt, d.UpdatedAt = e.ID, e.CreatedAt, e.UpdatedAt
+		},
+		create: func(ctx context.Context, t *model.TaskTemplate) error {
+			t.ID = generateID("tmpl")
+			t.CreatedAt = time.Now()
+			t.UpdatedAt = t.CreatedAt
+			return h.store.CreateTaskTemplate(ctx, t)
+		},
+		update: func(ctx context.Context, t *model.TaskTemplate) error {
+			t.UpdatedAt = time.Now()
+			return h.store.UpdateTaskTemplate(ctx, t)
+		},
+		delete: h.store.DeleteTaskTemplate,
+	}
+}
+
+func (h *Handler) agentTemplateKind() kind[model.AgentTemplate] {
+	return kind[model.AgentTemplate]{
+		name:      kindAgentTemplate,
+		nameOf:    func(t *model.AgentTemplate) string { return t.Name },
+		idOf:      func(t *model.AgentTemplate) string { return t.ID },
+		protected: func(t *model.AgentTemplate) bool { return t.IsBuiltin },
+		adopt: func(d, e *model.AgentTemplate) {
+			d.ID, d.CreatedAt, d.UpdatedAt = e.ID, e.CreatedAt, e.UpdatedAt
+		},
+		create: func(ctx context.Context, t *model.AgentTemplate) error {
+			t.ID = generateID("agent-tmpl")
+			t.CreatedAt = time.Now()
+			t.UpdatedAt = t.CreatedAt
+			return h.store.CreateAgentTemplate(ctx, t)
+		},
+		update: func(ctx context.Context, t *model.AgentTemplate) error {
+			t.UpdatedAt = time.Now()
+			return h.store.UpdateAgentTemplate(ctx, t)
+		},
+		delete: h.store.DeleteAgentTemplate,
+	}
+}
+
+func webhooksFromSpecs(specs []*WebhookSpec, user *auth.AuthUser) ([]*model.Webhook, error) {
+	hooks := make([]*model.Webhook, 0, len(specs))
+	for _, s := range specs {
+		if s == nil || strings.TrimSpace(s.Name) == "" {
+			return nil, invalidf("webhooks: name is required")
+		}
+		hook := &model.Webhook{
+			Name:    strings.TrimSpace(s.Name),
+			URL:     s.URL,
+			Secret:  s.Secret,
+			Enabled: s.Enabled == nil || *s.Enabled,
+			Filter:  s.Filter,
+		}
+		if user != nil {
+			hook.CreatedBy = user.ID
+		}
+		if err := webhook.Validate(hook); err != nil {
+			return nil, invalidf("webhook %q: %v", hook.Name, err)
+		}
+		hooks = append(hooks, hook)
+	}
+	return hooks, nil
+}
+
+func (h *Handler) webhookKind() kind[model.Webhook] {
+	return kind[model.Webhook]{
+		name:   kindWebhook,
+		nameOf: func(w *model.Webhook) string { return w.Name },
+		idOf:   func(w *model.Webhook) string { return w.ID },
+		adopt: func(d, e *model.Webhook) {
+			d.ID, d.CreatedAt, d.UpdatedAt, d.CreatedBy = e.ID, e.CreatedAt, e.UpdatedAt, e.CreatedBy
+			d.LastDeliveryAt, d.LastStatus, d.LastError = e.LastDeliveryAt, e.LastStatus, e.LastError
+			if d.Secret == "" {
+				d.Secret = e.Secret
+			}
+		},
+		equal: func(d, e *model.Webhook) bool { return d.Secret == e.Secret },
+		create: func(ctx context.Context, w *model.Webhook) error {
+			w.ID = generateID("wh")
+			if w.Secret == "" {
+				w.Secret = generateSecret()
+			}
+			w.CreatedAt = time.Now()
+			w.UpdatedAt = w.CreatedAt
+			return h.store.CreateWebhook(ctx, w)
+		},
+		update: func(ctx context.Context, w *model.Webhook) error {
+			w.UpdatedAt = time.Now()
+			return h.store.UpdateWebhook(ctx, w)
+		},
+		delete: h.store.DeleteWebhook,
+	}
+}
+
+func proxiesFromSpecs(specs []*ProxySpec) ([]*model.Proxy, error) {
+	proxies := make([]*model.Proxy, 0, len(specs))
+	for _, s := range specs {
+		if s == nil || strings.TrimSpace(s.Name) == "" || s.Type == "" || s.Host == "" || s.Port == 0 {
+			return nil, invalidf("proxies: name, type, host and port are required")
+		}
+		proxies = append(proxies, &model.Proxy{
+			Name:      strings.TrimSpace(s.Name),
+			Type:      model.ProxyType(s.Type),
+			Host:      s.Host,
+			Port:      s.Port,
+			Username:  s.Username,
+			Password:  s.Password,
+			NoProxy:   s.NoProxy,
+			IsDefault: s.IsDefault,
+			Status:    model.ProxyStatusActive,
+		})
+	}
+	return proxies, nil
+}
+
+func (h *Handler) proxyKind() kind[model.Proxy] {
+	return kind[model.Proxy]{
+		name:   kindProxy,
+		nameOf: func(p *model.Proxy) string { return p.Name },
+		idOf:   func(p *model.Proxy) string { return p.ID },
+		adopt: func(d, e *model.Proxy) {
+			d.ID, d.CreatedAt, d.UpdatedAt, d.Status = e.ID, e.CreatedAt, e.UpdatedAt, e.Status
+			if d.Password == nil {
+				d.Password = e.Password
+			}
+		},
+		equal: func(d, e *model.Proxy) bool {
+			return (d.Password == nil) == (e.Password == nil) && (d.Password == nil || *d.Password == *e.Password)
+		},
+		create: func(ctx context.Context, p *model.Proxy) error {
+			p.ID = generateID("proxy")
+			p.CreatedAt = time.Now()
+			p.UpdatedAt = p.CreatedAt
+			return h.store.CreateProxy(ctx, p)
+		},
+		update: func(ctx context.Context, p *model.Proxy) error {
+			p.UpdatedAt = time.Now()
+			return h.store.UpdateProxy(ctx, p)
+		},
+		delete: h.store.DeleteProxy,
+	}
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+// generateSecret 生成 256 位随机签名密钥
+func generateSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
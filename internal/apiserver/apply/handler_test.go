@@ -0,0 +1,339 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存存储（按 ID 保存各类资源）
+type mockStore struct {
+	projects       map[string]*model.Project
+	taskTemplates  map[string]*model.TaskTemplate
+	agentTemplates map[string]*model.AgentTemplate
+	webhooks       map[string]*model.Webhook
+	proxies        map[string]*model.Proxy
+	writes         int
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		projects:       make(map[string]*model.Project),
+		taskTemplates:  make(map[string]*model.TaskTemplate),
+		agentTemplates: make(map[string]*model.AgentTemplate),
+		webhooks:       make(map[string]*model.Webhook),
+		proxies:        make(map[string]*model.Proxy),
+	}
+}
+
+func values[T any](m map[string]*T) []*T {
+	list := make([]*T, 0, len(m))
+	for _, v := range m {
+		copied := *v
+		list = append(list, &copied)
+	}
+	return list
+}
+
+func (m *mockStore) ListProjects(_ context.Context) ([]*model.Project, error) {
+	return values(m.projects), nil
+}
+func (m *mockStore) CreateProject(_ context.Context, p *model.Project) error {
+	m.writes++
+	m.projects[p.ID] = p
+	return nil
+}
+func (m *mockStore) UpdateProject(_ context.Context, p *model.Project) error {
+	m.writes++
+	m.projects[p.ID] = p
+	return nil
+}
+func (m *mockStore) DeleteProject(_ context.Context, id string) error {
+	m.writes++
+	delete(m.projects, id)
+	return nil
+}
+
+func (m *mockStore) ListTaskTemplates(_ context.Context, _ string) ([]*model.TaskTemplate, error) {
+	return values(m.taskTemplates), nil
+}
+func (m *mockStore) CreateTaskTemplate(_ context.Context, t *model.TaskTemplate) error {
+	m.writes++
+	m.taskTemplates[t.ID] = t
+	return nil
+}
+func (m *mockStore) UpdateTaskTemplate(_ context.Context, t *model.TaskTemplate) error {
+	m.writes++
+	m.taskTemplates[t.ID] = t
+	return nil
+}
+func (m *mockStore) DeleteTaskTemplate(_ context.Context, id string) error {
+	m.writes++
+	delete(m.taskTemplates, id)
+	return nil
+}
+
+func (m *mockStore) ListAgentTemplates(_ context.Context, _ string) ([]*model.AgentTemplate, error) {
+	return values(m.agentTemplates), nil
+}
+func (m *mockStore) CreateAgentTemplate(_ context.Context, t *model.AgentTemplate) error {
+	m.writes++
+	m.agentTemplates[t.ID] = t
+	return nil
+}
+func (m *mockStore) UpdateAgentTemplate(_ context.Context, t *model.AgentTemplate) error {
+	m.writes++
+	m.agentTemplates[t.ID] = t
+	return nil
+}
+func (m *mockStore) DeleteAgentTemplate(_ context.Context, id string) error {
+	m.writes++
+	delete(m.agentTemplates, id)
+	return nil
+}
+
+func (m *mockStore) ListWebhooks(_ context.Context) ([]*model.Webhook, error) {
+	return values(m.webhooks), nil
+}
+func (m *mockStore) CreateWebhook(_ context.Context, w *model.Webhook) error {
+	m.writes++
+	m.webhooks[w.ID] = w
+	return nil
+}
+func (m *mockStore) UpdateWebhook(_ context.Context, w *model.Webhook) error {
+	m.writes++
+	m.webhooks[w.ID] = w
+	return nil
+}
+func (m *mockStore) DeleteWebhook(_ context.Context, id string) error {
+	m.writes++
+	delete(m.webhooks, id)
+	return nil
+}
+
+func (m *mockStore) ListProxies(_ context.Context) ([]*model.Proxy, error) {
+	return values(m.proxies), nil
+}
+func (m *mockStore) CreateProxy(_ context.Context, p *model.Proxy) error {
+	m.writes++
+	m.proxies[p.ID] = p
+	return nil
+}
+func (m *mockStore) UpdateProxy(_ context.Context, p *model.Proxy) error {
+	m.writes++
+	m.proxies[p.ID] = p
+	return nil
+}
+func (m *mockStore) DeleteProxy(_ context.Context, id string) error {
+	m.writes++
+	delete(m.proxies, id)
+	return nil
+}
+
+type countingReloader struct{ calls int }
+
+func (r *countingReloader) Reload(_ context.Context) error {
+	r.calls++
+	return nil
+}
+
+func doApply(t *testing.T, h *Handler, body string, role string) (*httptest.ResponseRecorder, *Result) {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest("POST", "/api/v1/apply", strings.NewReader(body))
+	if role != "" {
+		req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: role}))
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var result Result
+	json.Unmarshal(w.Body.Bytes(), &result)
+	return w, &result
+}
+
+func actions(result *Result) map[string]string {
+	m := make(map[string]string, len(result.Changes))
+	for _, c := range result.Changes {
+		m[c.Kind+"/"+c.Name] = c.Action
+	}
+	return m
+}
+
+func seed(store *mockStore) {
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.projects["proj-1"] = &model.Project{ID: "proj-1", Name: "infra", Description: "infra team", CreatedAt: created, UpdatedAt: created}
+	store.projects["proj-2"] = &model.Project{ID: "proj-2", Name: "web", CreatedAt: created, UpdatedAt: created}
+	store.projects["proj-3"] = &model.Project{ID: "proj-3", Name: "legacy", CreatedAt: created, UpdatedAt: created}
+	store.taskTemplates["tmpl-b"] = &model.TaskTemplate{ID: "tmpl-b", Name: "builtin", IsBuiltin: true}
+	store.webhooks["wh-1"] = &model.Webhook{ID: "wh-1", Name: "failures", URL: "https://hooks.example.com/a", Secret: "s1", Enabled: true, CreatedAt: created, UpdatedAt: created}
+}
+
+func TestApply_DryRunPlansWithoutWriting(t *testing.T) {
+	store := newMockStore()
+	seed(store)
+	h := NewHandler(store, nil)
+
+	w, result := doApply(t, h, `{
+		"dry_run": true,
+		"projects": [
+			{"name": "infra", "description": "infra team"},
+			{"name": "web", "description": "frontend"},
+			{"name": "data"}
+		]
+	}`, auth.UserRoleAdmin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if !result.DryRun {
+		t.Error("dry_run should be echoed")
+	}
+	got := actions(result)
+	want := map[string]string{
+		"project/infra":  ActionUnchanged,
+		"project/web":    ActionUpdate,
+		"project/data":   ActionCreate,
+		"project/legacy": ActionDelete,
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if len(got) != len(want) {
+		t.Errorf("changes = %v, want only projects", got)
+	}
+	if result.Summary[ActionCreate] != 1 || result.Summary[ActionDelete] != 1 {
+		t.Errorf("summary = %v", result.Summary)
+	}
+	if store.writes != 0 {
+		t.Errorf("dry run wrote %d times", store.writes)
+	}
+	if last := result.Changes[len(result.Changes)-1]; last.Action != ActionDelete {
+		t.Errorf("deletes should come last, got %s %s", last.Action, last.Name)
+	}
+}
+
+func TestApply_ExecutesChanges(t *testing.T) {
+	store := newMockStore()
+	seed(store)
+	reloader := &countingReloader{}
+	h := NewHandler(store, reloader)
+
+	w, result := doApply(t, h, `{
+		"projects": [{"name": "infra", "description": "infra team"}, {"name": "data"}],
+		"webhooks": [{"name": "failures", "url": "https://hooks.example.com/b"}],
+		"proxies": [{"name": "corp", "type": "http", "host": "10.0.0.1", "port": 3128, "password": "p"}]
+	}`, auth.UserRoleAdmin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	if len(store.projects) != 2 {
+		t.Errorf("projects = %d, want 2 (web and legacy pruned)", len(store.projects))
+	}
+	if store.projects["proj-1"] == nil {
+		t.Error("unchanged project should keep its ID")
+	}
+	for _, c := range result.Changes {
+		if c.Action == ActionCreate && c.ID == "" {
+			t.Errorf("created %s %s has no ID", c.Kind, c.Name)
+		}
+	}
+
+	hook := store.webhooks["wh-1"]
+	if hook == nil || hook.URL != "https://hooks.example.com/b" {
+		t.Fatalf("webhook not updated in place: %+v", hook)
+	}
+	if hook.Secret != "s1" {
+		t.Errorf("secret = %q, should be kept when not declared", hook.Secret)
+	}
+	if reloader.calls != 1 {
+		t.Errorf("reload calls = %d, want 1", reloader.calls)
+	}
+
+	if len(store.proxies) != 1 {
+		t.Fatalf("proxies = %d", len(store.proxies))
+	}
+	for _, p := range store.proxies {
+		if p.Password == nil || *p.Password != "p" || p.Status != model.ProxyStatusActive {
+			t.Errorf("proxy = %+v", p)
+		}
+	}
+	if store.taskTemplates["tmpl-b"] == nil {
+		t.Error("unmanaged kinds must be left alone")
+	}
+
+	// 再次应用相同状态不产生变更
+	writes := store.writes
+	_, again := doApply(t, h, `{
+		"projects": [{"name": "infra", "description": "infra team"}, {"name": "data"}],
+		"webhooks": [{"name": "failures", "url": "https://hooks.example.com/b"}],
+		"proxies": [{"name": "corp", "type": "http", "host": "10.0.0.1", "port": 3128}]
+	}`, auth.UserRoleAdmin)
+	if again.Summary[ActionUnchanged] != 4 || store.writes != writes {
+		t.Errorf("second apply should be a no-op: summary = %v, writes = %d", again.Summary, store.writes-writes)
+	}
+}
+
+func TestApply_BuiltinTemplates(t *testing.T) {
+	store := newMockStore()
+	seed(store)
+	h := NewHandler(store, nil)
+
+	// 空列表删除全部自定义模板，但保留内置模板
+	store.taskTemplates["tmpl-1"] = &model.TaskTemplate{ID: "tmpl-1", Name: "custom"}
+	w, _ := doApply(t, h, `{"task_templates": []}`, auth.UserRoleAdmin)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if store.taskTemplates["tmpl-1"] != nil || store.taskTemplates["tmpl-b"] == nil {
+		t.Errorf("templates = %v", store.taskTemplates)
+	}
+
+	w, _ = doApply(t, h, `{"task_templates": [{"name": "builtin", "type": "general"}]}`, auth.UserRoleAdmin)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("managing a builtin template: status = %d, want 400", w.Code)
+	}
+}
+
+func TestApply_InvalidBundle(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"duplicate name", `{"projects": [{"name": "a"}, {"name": "a"}]}`},
+		{"invalid project", `{"projects": [{"name": ""}]}`},
+		{"invalid webhook url", `{"webhooks": [{"name": "x", "url": "ftp://example.com"}]}`},
+		{"incomplete proxy", `{"proxies": [{"name": "x", "type": "http"}]}`},
+		{"schedules", `{"schedules": [{"name": "nightly"}]}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMockStore()
+			seed(store)
+			w, _ := doApply(t, NewHandler(store, nil), tt.body, auth.UserRoleAdmin)
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want 400, body = %s", w.Code, w.Body.String())
+			}
+			if store.writes != 0 {
+				t.Errorf("invalid bundle wrote %d times", store.writes)
+			}
+		})
+	}
+}
+
+func TestApply_RequiresAdmin(t *testing.T) {
+	store := newMockStore()
+	w, _ := doApply(t, NewHandler(store, nil), `{"projects": []}`, "user")
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, want 403", w.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package apply
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// 变更动作
+const (
+	ActionCreate    = "create"
+	ActionUpdate    = "update"
+	ActionDelete    = "delete"
+	ActionUnchanged = "unchanged"
+)
+
+// Change 单个资源的变更
+type Change struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	ID     string `json:"id,omitempty"`
+	Action string `json:"action"`
+	Error  string `json:"error,omitempty"` // 执行失败原因（失败后不再执行后续变更）
+
+	exec func(ctx context.Context) error
+}
+
+// kind 一类资源的差异计算方式
+//
+// 资源按名称匹配：期望中有而现有中没有的创建，两边都有且内容不同的整体替换，
+// 现有中有而期望中没有的删除。受保护的资源（内置模板）既不更新也不删除。
+type kind[T any] struct {
+	name      string
+	nameOf    func(*T) string
+	idOf      func(*T) string
+	protected func(*T) bool
+	// adopt 把现有资源的 ID、时间戳等服务端字段复制到期望资源上，之后两者可直接比较
+	adopt func(desired, existing *T)
+	// equal 比较 JSON 不可见的字段（签名密钥、代理密码），为空时只比较 JSON
+	equal  func(desired, existing *T) bool
+	create func(ctx context.Context, v *T) error
+	update func(ctx context.Context, v *T) error
+	delete func(ctx context.Context, id string) error
+}
+
+// plan 计算一类资源的变更；创建与更新按期望顺序返回，删除单独返回以便最后执行
+func (k kind[T]) plan(desired, existing []*T) (changes, deletes []*Change, err error) {
+	byName := make(map[string][]*T, len(existing))
+	for _, e := range existing {
+		byName[k.nameOf(e)] = append(byName[k.nameOf(e)], e)
+	}
+
+	seen := make(map[string]bool, len(desired))
+	for _, d := range desired {
+		name := k.nameOf(d)
+		if seen[name] {
+			return nil, nil, fmt.Errorf("%s %q is declared more than once", k.name, name)
+		}
+		seen[name] = true
+
+		matches := byName[name]
+		if len(matches) > 1 {
+			return nil, nil, fmt.Errorf("%s name %q matches %d existing resources", k.name, name, len(matches))
+		}
+		if len(matches) == 0 {
+			c := &Change{Kind: k.name, Name: name, Action: ActionCreate}
+			c.exec = func(ctx context.Context) error {
+				if err := k.create(ctx, d); err != nil {
+					return err
+				}
+				c.ID = k.idOf(d)
+				return nil
+			}
+			changes = append(changes, c)
+			continue
+		}
+
+		e := matches[0]
+		if k.protected != nil && k.protected(e) {
+			return nil, nil, fmt.Errorf("%s %q is builtin and cannot be managed", k.name, name)
+		}
+		k.adopt(d, e)
+		if k.same(d, e) {
+			changes = append(changes, &Change{Kind: k.name, Name: name, ID: k.idOf(e), Action: ActionUnchanged})
+			continue
+		}
+		changes = append(changes, &Change{Kind: k.name, Name: name, ID: k.idOf(e), Action: ActionUpdate,
+			exec: func(ctx context.Context) error { return k.update(ctx, d) }})
+	}
+
+	for _, e := range existing {
+		if seen[k.nameOf(e)] || (k.protected != nil && k.protected(e)) {
+			continue
+		}
+		id := k.idOf(e)
+		deletes = append(deletes, &Change{Kind: k.name, Name: k.nameOf(e), ID: id, Action: ActionDelete,
+			exec: func(ctx context.Context) error { return k.delete(ctx, id) }})
+	}
+	return changes, deletes, nil
+}
+
+// same 期望资源（已 adopt）与现有资源是否一致
+func (k kind[T]) same(desired, existing *T) bool {
+	a, err := json.Marshal(desired)
+	if err != nil {
+		return false
+	}
+	b, err := json.Marshal(existing)
+	if err != nil {
+		return false
+	}
+	if !bytes.Equal(a, b) {
+		return false
+	}
+	return k.equal == nil || k.equal(desired, existing)
+}
@@ -0,0 +1,195 @@
+package audit
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// memStore 内存实现的审计日志存储
+type memStore struct {
+	logs []*model.AuditLog
+}
+
+func (m *memStore) CreateAuditLog(_ context.Context, e *model.AuditLog) error {
+	m.logs = append(m.logs, e)
+	return nil
+}
+
+func (m *memStore) ListAuditLogs(_ context.Context, f storage.AuditFilter) ([]*model.AuditLog, int, error) {
+	sorted := append([]*model.AuditLog{}, m.logs...)
+	sort.Slice(sorted, func(i, j int) bool {
+		if !sorted[i].CreatedAt.Equal(sorted[j].CreatedAt) {
+			return sorted[i].CreatedAt.After(sorted[j].CreatedAt)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+	var out []*model.AuditLog
+	for _, e := range sorted {
+		if (f.ActorID != "" && e.ActorID != f.ActorID) || (f.ResourceType != "" && e.ResourceType != f.ResourceType) {
+			continue
+		}
+		if f.Cursor != nil && !(e.CreatedAt.Before(f.Cursor.CreatedAt) || (e.CreatedAt.Equal(f.Cursor.CreatedAt) && e.ID < f.Cursor.ID)) {
+			continue
+		}
+		out = append(out, e)
+	}
+	total := len(out)
+	if f.Limit > 0 && len(out) > f.Limit {
+		out = out[:f.Limit]
+	}
+	return out, total, nil
+}
+
+func newAuditedMux(store *memStore) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"id":"task-new","name":"x"}`))
+	})
+	mux.HandleFunc("PATCH /api/v1/admin/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /api/v1/tasks", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("POST /api/v1/runs/{id}/events", func(w http.ResponseWriter, r *http.Request) {})
+	return NewRecorder(store).Middleware(mux)
+}
+
+func TestMiddleware(t *testing.T) {
+	store := &memStore{}
+	h := newAuditedMux(store)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(
+		`{"name":"build","credentials":{"api_key":"sk-1","nested":[{"password":"p"}]},"git_token":"t"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.RemoteAddr = "10.0.0.7:51234"
+	req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u-1", Email: "a@example.com", Role: "admin"}))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, store.logs, 1)
+	e := store.logs[0]
+	assert.Equal(t, "POST /api/v1/tasks", e.Route)
+	assert.Equal(t, "tasks", e.ResourceType)
+	assert.Equal(t, "task-new", e.ResourceID, "resource id of created objects comes from the response")
+	assert.Equal(t, http.StatusCreated, e.Status)
+	assert.Equal(t, "10.0.0.7", e.SourceIP)
+	assert.Equal(t, "u-1", e.ActorID)
+	assert.Equal(t, "a@example.com", e.ActorEmail)
+	assert.JSONEq(t, `{"name":"build","credentials":{"api_key":"***","nested":[{"password":"***"}]},"git_token":"***"}`, string(e.Request))
+
+	req = httptest.NewRequest(http.MethodPatch, "/api/v1/admin/users/u-9", strings.NewReader(`{"role":"admin"}`))
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	require.Len(t, store.logs, 2)
+	assert.Equal(t, "users", store.logs[1].ResourceType)
+	assert.Equal(t, "u-9", store.logs[1].ResourceID)
+	assert.Equal(t, http.StatusNoContent, store.logs[1].Status)
+	assert.Empty(t, store.logs[1].ActorID, "anonymous when auth is disabled")
+}
+
+func TestMiddleware_Skips(t *testing.T) {
+	store := &memStore{}
+	h := newAuditedMux(store)
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/unknown", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/api/v1/runs/run-1/events", strings.NewReader(`{}`)))
+
+	nodeReq := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(`{}`))
+	nodeReq = nodeReq.WithContext(auth.WithNodeIdentity(nodeReq.Context(), "node-1"))
+	h.ServeHTTP(httptest.NewRecorder(), nodeReq)
+
+	assert.Empty(t, store.logs)
+}
+
+func TestMiddleware_NonJSONBody(t *testing.T) {
+	store := &memStore{}
+	h := newAuditedMux(store)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader("name: build\n"))
+	req.Header.Set("Content-Type", "application/yaml")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, store.logs, 1)
+	assert.JSONEq(t, `{"content_type":"application/yaml","size":12}`, string(store.logs[0].Request))
+}
+
+func TestHandler(t *testing.T) {
+	store := &memStore{}
+	base := time.Date(2026, 10, 17, 8, 0, 0, 0, time.UTC)
+	for i := 0; i < 5; i++ {
+		actor := "u-1"
+		if i%2 == 1 {
+			actor = "u-2"
+		}
+		store.logs = append(store.logs, &model.AuditLog{
+			ID: "audit-" + string(rune('a'+i)), ActorID: actor, Method: http.MethodPost, Route: "POST /api/v1/tasks",
+			Path: "/api/v1/tasks", ResourceType: "tasks", Status: 201, CreatedAt: base.Add(time.Duration(i) * time.Minute),
+			Request: json.RawMessage(`{"name":"t"}`),
+		})
+	}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+	handler := auth.Middleware(auth.Config{})(mux) // 无认证模式（未配置 JWT 密钥）
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := do("/api/v1/audit?limit=2")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var page struct {
+		Entries    []*model.AuditLog `json:"entries"`
+		HasMore    bool              `json:"has_more"`
+		NextCursor string            `json:"next_cursor"`
+		Total      int               `json:"total"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, "audit-e", page.Entries[0].ID, "newest first")
+	assert.True(t, page.HasMore)
+	assert.Equal(t, 5, page.Total)
+
+	w = do("/api/v1/audit?limit=2&cursor=" + page.NextCursor)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	require.Len(t, page.Entries, 2)
+	assert.Equal(t, "audit-c", page.Entries[0].ID)
+
+	w = do("/api/v1/audit?actor_id=u-2")
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &page))
+	assert.Len(t, page.Entries, 2)
+
+	assert.Equal(t, http.StatusBadRequest, do("/api/v1/audit?since=yesterday").Code)
+
+	w = do("/api/v1/audit/export?format=csv")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".csv")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 6)
+	assert.Equal(t, csvHeader, records[0])
+	assert.Equal(t, "audit-e", records[1][0])
+	assert.Equal(t, `{"name":"t"}`, records[1][len(csvHeader)-1])
+
+	w = do("/api/v1/audit/export")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Len(t, strings.Split(strings.TrimSpace(w.Body.String()), "\n"), 5)
+
+	assert.Equal(t, http.StatusBadRequest, do("/api/v1/audit/export?format=xml").Code)
+}
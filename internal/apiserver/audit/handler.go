@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+const (
+	// exportPageSize 导出时每次查询的条数
+	exportPageSize = 500
+
+	// maxExportRows 单次导出的最大条数
+	maxExportRows = 100000
+)
+
+// Handler 审计日志 HTTP 处理器
+type Handler struct {
+	store Store
+}
+
+// NewHandler 创建审计日志处理器
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes 注册审计日志相关路由（仅限管理员）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/audit", auth.AdminOnly(h.List))
+	mux.HandleFunc("GET /api/v1/audit/export", auth.AdminOnly(h.Export))
+}
+
+// parseFilter 解析查询参数中的过滤条件
+func parseFilter(r *http.Request) (storage.AuditFilter, error) {
+	q := r.URL.Query()
+	filter := storage.AuditFilter{
+		ActorID:      q.Get("actor_id"),
+		Method:       strings.ToUpper(q.Get("method")),
+		ResourceType: q.Get("resource_type"),
+		ResourceID:   q.Get("resource_id"),
+	}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		if v := q.Get(p.name); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				return filter, fmt.Errorf("invalid %s (expected RFC3339)", p.name)
+			}
+			*p.dst = t
+		}
+	}
+	return filter, nil
+}
+
+// List 按请求时间倒序列出审计日志
+// GET /api/v1/audit
+//
+// 支持的查询参数：
+//   - actor_id:      操作者用户 ID
+//   - method:        HTTP 方法（POST / PUT / PATCH / DELETE）
+//   - resource_type: 资源类型（如 tasks、webhooks）
+//   - resource_id:   资源 ID
+//   - since / until: 请求时间范围 (RFC3339)
+//   - limit:         每页条数 (默认 50, 最大 500)
+//   - offset:        偏移量（使用 cursor 时忽略）
+//   - cursor:        上一页返回的 next_cursor
+//   - include_total: 是否返回 total（偏移分页默认 true，游标分页默认 false）
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 500 {
+		limit = 50
+	}
+	filter.Limit = limit + 1 // 多取一条判断是否还有下一页
+	filter.Offset, _ = strconv.Atoi(q.Get("offset"))
+	if c := q.Get("cursor"); c != "" {
+		if filter.Cursor, err = storage.ParsePageCursor(c); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	includeTotal := filter.Cursor == nil
+	if v := q.Get("include_total"); v != "" {
+		includeTotal, _ = strconv.ParseBool(v)
+	}
+	filter.SkipTotal = !includeTotal
+
+	logs, total, err := h.store.ListAuditLogs(r.Context(), filter)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "audit", "op", "ListAuditLogs", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list audit logs")
+		return
+	}
+	hasMore := len(logs) > limit
+	if hasMore {
+		logs = logs[:limit]
+	}
+	resp := map[string]interface{}{
+		"entries":  logs,
+		"count":    len(logs),
+		"has_more": hasMore,
+	}
+	if hasMore {
+		last := logs[limit-1]
+		resp["next_cursor"] = storage.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if includeTotal {
+		resp["total"] = total
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Export 导出审计日志
+// GET /api/v1/audit/export?format=csv|jsonl
+//
+// 过滤参数与 List 相同，按请求时间倒序流式输出，单次最多 100000 条。format 默认 jsonl。
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseFilter(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "jsonl"
+	}
+	if format != "csv" && format != "jsonl" {
+		writeError(w, http.StatusBadRequest, "invalid format (expected csv or jsonl)")
+		return
+	}
+	w.Header().Set("Content-Disposition", `attachment; filename="audit-`+time.Now().UTC().Format("20060102-150405")+`.`+format+`"`)
+
+	enc := json.NewEncoder(w)
+	cw := csv.NewWriter(w)
+	write := func(e *model.AuditLog) error { return enc.Encode(e) }
+	if format == "csv" {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		cw.Write(csvHeader)
+		write = func(e *model.AuditLog) error { return cw.Write(csvRecord(e)) }
+	} else {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	}
+
+	filter.SkipTotal = true
+	filter.Limit = exportPageSize
+	exported := 0
+	for exported < maxExportRows {
+		logs, _, err := h.store.ListAuditLogs(r.Context(), filter)
+		if err != nil {
+			// 响应已开始输出，只能中断
+			slog.ErrorContext(r.Context(), "audit.export.failed", "exported", exported, "error", err)
+			break
+		}
+		for _, e := range logs {
+			if err := write(e); err != nil {
+				return
+			}
+		}
+		exported += len(logs)
+		if len(logs) < exportPageSize {
+			break
+		}
+		last := logs[len(logs)-1]
+		filter.Cursor = &storage.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.ErrorContext(r.Context(), "audit.export.failed", "exported", exported, "error", err)
+		return
+	}
+	slog.InfoContext(r.Context(), "audit.exported", "format", format, "rows", exported)
+}
+
+// csvHeader 导出 CSV 的列
+var csvHeader = []string{"id", "created_at", "actor_id", "actor_email", "actor_role", "method", "route", "path",
+	"resource_type", "resource_id", "status", "source_ip", "duration_ms", "request"}
+
+func csvRecord(e *model.AuditLog) []string {
+	return []string{
+		e.ID, e.CreatedAt.UTC().Format(time.RFC3339Nano), e.ActorID, e.ActorEmail, e.ActorRole, e.Method, e.Route, e.Path,
+		e.ResourceType, e.ResourceID, strconv.Itoa(e.Status), e.SourceIP, strconv.FormatInt(e.DurationMs, 10), string(e.Request),
+	}
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
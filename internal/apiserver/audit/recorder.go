@@ -0,0 +1,274 @@
+// Package audit 变更操作审计日志
+//
+// Recorder.Middleware 包装 API 路由，为每个变更类请求（POST / PUT / PATCH / DELETE）追加一条审计记录：
+// 操作者、路由、资源类型与 ID、响应状态码、来源 IP 与脱敏后的请求体。处理器无需任何改动。
+//
+// 节点上报（心跳、事件、状态回写等节点凭证认证的请求）属于系统流量，不记录；
+// 节点越权访问（如访问未分配给该节点的 Run）被拒绝时由执行限制的调用方通过 RecordNodeViolation 记录。
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+const (
+	// maxCapture 请求体与响应体的最大记录字节数，超出时请求体只记录大小
+	maxCapture = 64 << 10
+
+	// writeTimeout 写入审计日志的超时时间
+	writeTimeout = 5 * time.Second
+
+	// redacted 敏感字段的替换值
+	redacted = "***"
+
+	// ActorRoleNode 节点越权访问记录的操作者角色（操作者 ID 为节点 ID）
+	ActorRoleNode = "node"
+)
+
+// sensitiveKeys 字段名包含这些片段（不区分大小写）时脱敏
+var sensitiveKeys = []string{"password", "passwd", "passphrase", "secret", "token", "api_key", "apikey", "private_key", "access_key", "authorization", "cookie"}
+
+// skipRoutes 不记录的高频系统路由（未启用认证时节点请求无法通过凭证识别）
+var skipRoutes = map[string]bool{
+	"POST /api/v1/nodes/heartbeat":  true,
+	"POST /api/v1/runs/{id}/events": true,
+}
+
+// Store 审计日志需要的存储接口
+type Store interface {
+	CreateAuditLog(ctx context.Context, entry *model.AuditLog) error
+	ListAuditLogs(ctx context.Context, filter storage.AuditFilter) ([]*model.AuditLog, int, error)
+}
+
+// Recorder 审计日志记录器
+type Recorder struct {
+	store Store
+	now   func() time.Time
+}
+
+// NewRecorder 创建审计日志记录器
+func NewRecorder(store Store) *Recorder {
+	return &Recorder{store: store, now: time.Now}
+}
+
+// Middleware 记录变更类请求的审计中间件
+//
+// 需直接包装 ServeMux（位于认证中间件之内），处理完成后才能读取匹配的路由模式与路径参数。
+func (rec *Recorder) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !mutating(r.Method) || fromNode(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := rec.now()
+		body := &captureBody{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r)
+
+		// 未匹配任何路由（404 / 405）的请求不是有效操作
+		if r.Pattern == "" || skipRoutes[r.Pattern] {
+			return
+		}
+		entry := &model.AuditLog{
+			ID:           generateID("audit"),
+			Method:       r.Method,
+			Route:        r.Pattern,
+			Path:         r.URL.Path,
+			ResourceType: resourceType(r.URL.Path),
+			ResourceID:   r.PathValue("id"),
+			Status:       rw.status,
+			SourceIP:     auth.RemoteHost(r.RemoteAddr),
+			Request:      body.redacted(r.Header.Get("Content-Type")),
+			DurationMs:   rec.now().Sub(start).Milliseconds(),
+			CreatedAt:    start,
+		}
+		if user := auth.GetAuthUser(r.Context()); user != nil {
+			entry.ActorID, entry.ActorEmail, entry.ActorRole = user.ID, user.Email, user.Role
+		}
+		if entry.ResourceID == "" && rw.status < http.StatusMultipleChoices {
+			entry.ResourceID = responseID(rw.body.Bytes())
+		}
+
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), writeTimeout)
+		defer cancel()
+		if err := rec.store.CreateAuditLog(ctx, entry); err != nil {
+			slog.ErrorContext(ctx, "audit.write.failed", "route", entry.Route, "path", entry.Path, "actor", entry.ActorID, "error", err)
+		}
+	})
+}
+
+// RecordNodeViolation 记录被拒绝的节点越权访问（状态码 403，操作者为节点）
+//
+// entry 由调用方填写请求信息（方法、路由、路径、资源与来源），gRPC 请求的方法为 GRPC、路由为完整方法名。
+func (rec *Recorder) RecordNodeViolation(ctx context.Context, nodeID string, entry *model.AuditLog) {
+	entry.ID = generateID("audit")
+	entry.ActorID, entry.ActorRole = nodeID, ActorRoleNode
+	entry.Status = http.StatusForbidden
+	entry.CreatedAt = rec.now()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+	if err := rec.store.CreateAuditLog(ctx, entry); err != nil {
+		slog.ErrorContext(ctx, "audit.write.failed", "route", entry.Route, "path", entry.Path, "actor", nodeID, "error", err)
+	}
+}
+
+// mutating 判断是否为变更类请求
+func mutating(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// fromNode 判断是否为节点凭证认证的请求
+func fromNode(r *http.Request) bool {
+	return auth.GetNodeIdentity(r.Context()) != nil || r.Header.Get("X-Node-Token") != ""
+}
+
+// resourceType 资源类型：/api/v1/ 之后的第一段（/api/v1/admin/ 下取第二段）
+func resourceType(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "api" || parts[1] != "v1" {
+		return ""
+	}
+	if parts[2] == "admin" && len(parts) > 3 {
+		return parts[3]
+	}
+	return parts[2]
+}
+
+// responseID 从创建类响应体中读取资源 ID（顶层 id 字段）
+func responseID(body []byte) string {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	return resp.ID
+}
+
+// ============================================================================
+// 请求体与响应捕获
+// ============================================================================
+
+// captureBody 记录处理器读取的请求体（最多 maxCapture 字节）
+type captureBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	size int64
+}
+
+func (b *captureBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+	if room := maxCapture - b.buf.Len(); room > 0 {
+		b.buf.Write(p[:min(n, room)])
+	}
+	return n, err
+}
+
+// redacted 脱敏后的请求体：JSON 请求体替换敏感字段，其余只记录类型与大小
+func (b *captureBody) redacted(contentType string) json.RawMessage {
+	if b.size == 0 {
+		return nil
+	}
+	if b.size <= maxCapture {
+		var v interface{}
+		if json.Unmarshal(b.buf.Bytes(), &v) == nil {
+			out, _ := json.Marshal(redact(v))
+			return out
+		}
+	}
+	out, _ := json.Marshal(map[string]interface{}{"content_type": contentType, "size": b.size})
+	return out
+}
+
+// redact 递归替换敏感字段的值
+func redact(v interface{}) interface{} {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			if isSensitive(k) {
+				val[k] = redacted
+				continue
+			}
+			val[k] = redact(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = redact(item)
+		}
+	}
+	return v
+}
+
+func isSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, s := range sensitiveKeys {
+		if strings.Contains(key, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder 记录响应状态码与响应体开头（用于读取创建的资源 ID）
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *responseRecorder) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseRecorder) Write(p []byte) (int, error) {
+	if room := maxCapture - rw.body.Len(); room > 0 {
+		rw.body.Write(p[:min(len(p), room)])
+	}
+	return rw.ResponseWriter.Write(p)
+}
+
+// Hijack 支持 WebSocket 升级
+func (rw *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if hj, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		return hj.Hijack()
+	}
+	return nil, nil, fmt.Errorf("response writer does not support hijacking")
+}
+
+// Unwrap 供 http.ResponseController 访问底层连接（流式响应需要 Flush）
+func (rw *responseRecorder) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
@@ -1,11 +1,14 @@
-// Package auth 用户认证：JWT 令牌管理、密码哈希、HTTP 中间件
+// Package auth 用户认证：JWT 令牌管理、密码哈希、OIDC 单点登录、HTTP 中间件
 package auth
 
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"time"
 
+	"agents-admin/internal/shared/logging"
+
 	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
@@ -16,13 +19,17 @@ type contextKey string
 const (
 	ctxKeyAuthUser contextKey = "auth_user"
 	ctxKeyTenantID contextKey = "tenant_id"
+	ctxKeyNode     contextKey = "node"
+
+	ctxKeyAuthDisabled contextKey = "auth_disabled"
 )
 
 // AuthUser 从 JWT 解析出的用户信息
 type AuthUser struct {
-	ID    string
-	Email string
-	Role  string // "admin" | "user"
+	ID        string
+	Email     string
+	Role      string // "admin" | "user"
+	SessionID string // 登录会话 ID（升级前签发的令牌为空）
 }
 
 // Config 认证配置
@@ -31,6 +38,35 @@ type Config struct {
 	AccessTokenTTL  time.Duration `yaml:"access_token_ttl"`
 	RefreshTokenTTL time.Duration `yaml:"refresh_token_ttl"`
 	NodeToken       string        `yaml:"-"` // NodeManager 共享密钥，从 NODE_TOKEN 环境变量读取
+	MasterKey       string        `yaml:"-"` // 两步验证密钥的加密主密钥，从 MASTER_KEY 环境变量读取（为空时不能启用两步验证）
+
+	// NodeCredentials 节点专属凭证校验（节点通过加入令牌获得），nil 时只接受共享密钥
+	NodeCredentials NodeCredentialVerifier `yaml:"-"`
+
+	// Sessions 登录会话校验（撤销会话后其访问令牌随即失效），nil 时不检查会话
+	Sessions SessionVerifier `yaml:"-"`
+
+	// OIDC 单点登录，未配置 issuer/client_id 时不启用
+	OIDC OIDCConfig `yaml:"-"`
+}
+
+// NodeCredentialVerifier 校验节点专属凭证
+type NodeCredentialVerifier interface {
+	// VerifyNodeToken 校验节点专属 Token，返回节点 ID
+	VerifyNodeToken(ctx context.Context, token string) (nodeID string, ok bool)
+	// VerifyNodeCert 校验节点客户端证书是否仍有效（未被撤销或被重新签发的证书替换）
+	VerifyNodeCert(ctx context.Context, nodeID, serial string) bool
+}
+
+// SessionVerifier 校验访问令牌所属的登录会话
+type SessionVerifier interface {
+	// VerifySession 会话是否仍有效（存在、未撤销且未过期）
+	VerifySession(ctx context.Context, sessionID string) bool
+}
+
+// NodeIdentity 通过节点凭证认证的请求身份
+type NodeIdentity struct {
+	ID string // 节点 ID，共享密钥认证时为空
 }
 
 // DefaultConfig 返回默认认证配置
@@ -69,36 +105,39 @@ func CheckPassword(password, hash string) bool {
 // Claims JWT 声明
 type Claims struct {
 	jwt.RegisteredClaims
-	Email string `json:"email,omitempty"`
-	Role  string `json:"role,omitempty"`
-	Type  string `json:"type,omitempty"` // "access" | "refresh"
+	Email     string `json:"email,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Type      string `json:"type,omitempty"` // "access" | "refresh" | "mfa"
+	SessionID string `json:"sid,omitempty"`  // 登录会话 ID
 }
 
 // GenerateAccessToken 生成访问令牌
-func GenerateAccessToken(cfg Config, userID, email, role string) (string, error) {
+func GenerateAccessToken(cfg Config, userID, email, role, sessionID string) (string, error) {
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.AccessTokenTTL)),
 		},
-		Email: email,
-		Role:  role,
-		Type:  "access",
+		Email:     email,
+		Role:      role,
+		Type:      "access",
+		SessionID: sessionID,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(cfg.JWTSecret))
 }
 
 // GenerateRefreshToken 生成刷新令牌
-func GenerateRefreshToken(cfg Config, userID string) (string, error) {
+func GenerateRefreshToken(cfg Config, userID, sessionID string) (string, error) {
 	claims := Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
 			Subject:   userID,
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(cfg.RefreshTokenTTL)),
 		},
-		Type: "refresh",
+		Type:      "refresh",
+		SessionID: sessionID,
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(cfg.JWTSecret))
@@ -126,8 +165,9 @@ func ParseToken(cfg Config, tokenString string) (*Claims, error) {
 // Context 辅助函数
 // ============================================================================
 
-// WithAuthUser 将认证用户信息注入 context
+// WithAuthUser 将认证用户信息注入 context（同时作为请求日志的关联字段）
 func WithAuthUser(ctx context.Context, user *AuthUser) context.Context {
+	logging.Annotate(ctx, slog.String("user_id", user.ID))
 	return context.WithValue(ctx, ctxKeyAuthUser, user)
 }
 
@@ -137,6 +177,33 @@ func GetAuthUser(ctx context.Context) *AuthUser {
 	return user
 }
 
+// WithNodeIdentity 将节点身份注入 context（同时作为请求日志的关联字段）
+func WithNodeIdentity(ctx context.Context, nodeID string) context.Context {
+	if nodeID != "" {
+		logging.Annotate(ctx, slog.String("node_id", nodeID))
+	}
+	return context.WithValue(ctx, ctxKeyNode, &NodeIdentity{ID: nodeID})
+}
+
+// GetNodeIdentity 从 context 获取节点身份，非节点请求返回 nil
+func GetNodeIdentity(ctx context.Context) *NodeIdentity {
+	node, _ := ctx.Value(ctxKeyNode).(*NodeIdentity)
+	return node
+}
+
+// WithAuthDisabled 标记请求处于无认证模式（未配置 JWT 密钥，由认证中间件注入）
+func WithAuthDisabled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ctxKeyAuthDisabled, true)
+}
+
+// AuthDisabled 请求是否处于无认证模式
+//
+// 启用认证但跳过认证的请求（公开路由等）不带此标记，需要用户身份的检查不能据此放行。
+func AuthDisabled(ctx context.Context) bool {
+	disabled, _ := ctx.Value(ctxKeyAuthDisabled).(bool)
+	return disabled
+}
+
 // WithTenantID 将租户 ID 注入 context
 func WithTenantID(ctx context.Context, tenantID string) context.Context {
 	return context.WithValue(ctx, ctxKeyTenantID, tenantID)
@@ -5,12 +5,14 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"sync/atomic"
 	"time"
 
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secretbox"
 	storageErrors "agents-admin/internal/shared/storage"
 )
 
@@ -20,18 +22,81 @@ type UserStore interface {
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
 	UpdateUserPassword(ctx context.Context, id, passwordHash string) error
+	UpdateUserRole(ctx context.Context, id string, role model.UserRole) error
 	ListUsers(ctx context.Context) ([]*model.User, error)
 }
 
+// SecurityStore 两步验证与登录会话存储接口
+type SecurityStore interface {
+	GetUserMFA(ctx context.Context, userID string) (*model.UserMFA, error)
+	SaveUserMFA(ctx context.Context, mfa *model.UserMFA) error
+	DeleteUserMFA(ctx context.Context, userID string) error
+	UseUserMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	ConsumeUserBackupCode(ctx context.Context, userID, codeHash string) (bool, error)
+
+	CreateUserSession(ctx context.Context, session *model.UserSession) error
+	GetUserSession(ctx context.Context, id string) (*model.UserSession, error)
+	ListUserSessions(ctx context.Context, userID string) ([]*model.UserSession, error)
+	TouchUserSession(ctx context.Context, id string, at time.Time) error
+	RevokeUserSession(ctx context.Context, userID, id string) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID, exceptID string) (int64, error)
+}
+
+// Store 认证处理器依赖的存储
+type Store interface {
+	UserStore
+	SecurityStore
+}
+
 // Handler 认证 HTTP 处理器
 type Handler struct {
-	store UserStore
+	store Store
 	cfg   Config
+	oidc  *OIDCProvider  // nil 时不提供 SSO 登录
+	box   *secretbox.Box // 两步验证密钥加密，未配置主密钥时为 nil（不能启用两步验证）
+
+	mfaFailures *failureLimiter // 两步验证失败次数限制
+	sessions    *sessionCache   // 会话校验缓存（实现 SessionVerifier）
+
+	tokenTTL atomic.Pointer[tokenTTL] // 运行中调整的令牌有效期（配置热加载，nil 时使用 cfg）
+}
+
+// tokenTTL 访问令牌与刷新令牌有效期
+type tokenTTL struct {
+	access, refresh time.Duration
 }
 
-// NewHandler 创建认证处理器
-func NewHandler(store UserStore, cfg Config) *Handler {
-	return &Handler{store: store, cfg: cfg}
+// SetTokenTTL 调整新签发令牌的有效期（配置热加载，<= 0 的值保持不变；已签发的令牌不受影响）
+func (h *Handler) SetTokenTTL(access, refresh time.Duration) {
+	cur := h.tokenConfig()
+	if access <= 0 {
+		access = cur.AccessTokenTTL
+	}
+	if refresh <= 0 {
+		refresh = cur.RefreshTokenTTL
+	}
+	h.tokenTTL.Store(&tokenTTL{access: access, refresh: refresh})
+}
+
+// tokenConfig 签发令牌使用的配置（含运行中调整的有效期）
+func (h *Handler) tokenConfig() Config {
+	cfg := h.cfg
+	if t := h.tokenTTL.Load(); t != nil {
+		cfg.AccessTokenTTL, cfg.RefreshTokenTTL = t.access, t.refresh
+	}
+	return cfg
+}
+
+// NewHandler 创建认证处理器（启用认证且配置了 cfg.OIDC 时同时提供 OIDC 单点登录）
+func NewHandler(store Store, cfg Config) *Handler {
+	h := &Handler{store: store, cfg: cfg, mfaFailures: newFailureLimiter(mfaMaxFailures, mfaLockout), sessions: newSessionCache()}
+	if cfg.Enabled() && cfg.OIDC.Enabled() {
+		h.oidc = NewOIDCProvider(cfg.OIDC)
+	}
+	if box, err := secretbox.New(cfg.MasterKey); err == nil {
+		h.box = box
+	}
+	return h
 }
 
 // RegisterRoutes 注册认证相关路由
@@ -41,6 +106,24 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /api/v1/auth/refresh", h.Refresh)
 	mux.HandleFunc("GET /api/v1/auth/me", h.Me)
 	mux.HandleFunc("PUT /api/v1/auth/password", h.ChangePassword)
+	mux.HandleFunc("POST /api/v1/auth/logout", h.Logout)
+
+	// 两步验证
+	mux.HandleFunc("POST /api/v1/auth/login/2fa", h.LoginMFA)
+	mux.HandleFunc("GET /api/v1/auth/2fa", h.MFAStatus)
+	mux.HandleFunc("POST /api/v1/auth/2fa/enroll", h.EnrollMFA)
+	mux.HandleFunc("POST /api/v1/auth/2fa/verify", h.ConfirmMFA)
+	mux.HandleFunc("POST /api/v1/auth/2fa/backup-codes", h.RegenerateBackupCodes)
+	mux.HandleFunc("DELETE /api/v1/auth/2fa", h.DisableMFA)
+
+	// 登录会话
+	mux.HandleFunc("GET /api/v1/auth/sessions", h.ListSessions)
+	mux.HandleFunc("DELETE /api/v1/auth/sessions", h.RevokeSessions)
+	mux.HandleFunc("DELETE /api/v1/auth/sessions/{id}", h.RevokeSession)
+	if h.oidc != nil {
+		mux.HandleFunc("GET /api/v1/auth/oidc/login", h.OIDCLogin)
+		mux.HandleFunc("GET /api/v1/auth/oidc/callback", h.OIDCCallback)
+	}
 }
 
 // ============================================================================
@@ -101,7 +184,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	// 检查邮箱是否已注册
 	existing, err := h.store.GetUserByEmail(r.Context(), req.Email)
 	if err != nil {
-		log.Printf("[auth.register] GetUserByEmail error: %v", err)
+		slog.ErrorContext(r.Context(), "auth.register", "op", "GetUserByEmail", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
@@ -113,7 +196,7 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	// 哈希密码
 	hash, err := HashPassword(req.Password)
 	if err != nil {
-		log.Printf("[auth.register] HashPassword error: %v", err)
+		slog.ErrorContext(r.Context(), "auth.register", "op", "HashPassword", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
@@ -131,33 +214,21 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.CreateUser(r.Context(), user); err != nil {
-		log.Printf("[auth.register] CreateUser error: %v", err)
+		slog.ErrorContext(r.Context(), "auth.register", "op", "CreateUser", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create user")
 		return
 	}
 
-	// 生成令牌
-	accessToken, err := GenerateAccessToken(h.cfg, user.ID, user.Email, string(user.Role))
-	if err != nil {
-		log.Printf("[auth.register] GenerateAccessToken error: %v", err)
-		writeError(w, http.StatusInternalServerError, "internal error")
-		return
-	}
-	refreshToken, err := GenerateRefreshToken(h.cfg, user.ID)
+	// 创建会话并生成令牌
+	resp, err := h.startSession(w, r, user, sessionMethodRegister)
 	if err != nil {
-		log.Printf("[auth.register] GenerateRefreshToken error: %v", err)
+		slog.ErrorContext(r.Context(), "auth.register", "op", "startSession", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	log.Printf("[auth] User registered: %s (%s)", user.Email, user.ID)
-	setAccessTokenCookie(w, accessToken, h.cfg.AccessTokenTTL)
-	setRefreshTokenCookie(w, refreshToken, h.cfg.RefreshTokenTTL)
-	writeJSON(w, http.StatusCreated, authResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	})
+	slog.InfoContext(r.Context(), "auth.register.success", "user_id", user.ID, "email", user.Email)
+	writeJSON(w, http.StatusCreated, resp)
 }
 
 // Login 用户登录
@@ -175,7 +246,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 
 	user, err := h.store.GetUserByEmail(r.Context(), req.Email)
 	if err != nil {
-		log.Printf("[auth.login] GetUserByEmail error: %v", err)
+		slog.ErrorContext(r.Context(), "auth.login", "op", "GetUserByEmail", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
@@ -188,25 +259,32 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := GenerateAccessToken(h.cfg, user.ID, user.Email, string(user.Role))
+	// 启用了两步验证：返回短期 mfa_token，由 /api/v1/auth/login/2fa 以动态码或备用码完成登录
+	mfa, err := h.store.GetUserMFA(r.Context(), user.ID)
 	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.login", "op", "GetUserMFA", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
-	refreshToken, err := GenerateRefreshToken(h.cfg, user.ID)
+	if mfa != nil && mfa.Enabled {
+		token, err := generateMFAToken(h.cfg, user.ID)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		writeJSON(w, http.StatusOK, mfaChallengeResponse{MFARequired: true, MFAToken: token})
+		return
+	}
+
+	resp, err := h.startSession(w, r, user, sessionMethodPassword)
 	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.login", "op", "startSession", "error", err)
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	log.Printf("[auth] User logged in: %s", user.Email)
-	setAccessTokenCookie(w, accessToken, h.cfg.AccessTokenTTL)
-	setRefreshTokenCookie(w, refreshToken, h.cfg.RefreshTokenTTL)
-	writeJSON(w, http.StatusOK, authResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-	})
+	slog.InfoContext(r.Context(), "auth.login.success", "user_id", user.ID, "email", user.Email)
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // Refresh 刷新访问令牌
@@ -247,13 +325,26 @@ func (h *Handler) Refresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	accessToken, err := GenerateAccessToken(h.cfg, user.ID, user.Email, string(user.Role))
+	// 会话已撤销或过期时刷新令牌失效（升级前签发的令牌没有会话 ID，到期前仍可刷新）
+	if claims.SessionID != "" {
+		session, err := h.store.GetUserSession(r.Context(), claims.SessionID)
+		if err != nil || session == nil || session.UserID != user.ID || !session.Active(time.Now()) {
+			writeError(w, http.StatusUnauthorized, "session revoked or expired")
+			return
+		}
+		if err := h.store.TouchUserSession(r.Context(), session.ID, time.Now()); err != nil {
+			slog.ErrorContext(r.Context(), "auth.refresh", "op", "TouchUserSession", "error", err)
+		}
+	}
+
+	cfg := h.tokenConfig()
+	accessToken, err := GenerateAccessToken(cfg, user.ID, user.Email, string(user.Role), claims.SessionID)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "internal error")
 		return
 	}
 
-	setAccessTokenCookie(w, accessToken, h.cfg.AccessTokenTTL)
+	setAccessTokenCookie(w, accessToken, cfg.AccessTokenTTL)
 	writeJSON(w, http.StatusOK, map[string]string{
 		"access_token": accessToken,
 	})
@@ -340,10 +431,10 @@ func EnsureAdminUser(store UserStore, adminEmail, adminPassword string) error {
 	if existing != nil {
 		// 已存在，确保角色是 admin
 		if existing.Role != model.UserRoleAdmin {
-			log.Printf("[auth] Upgrading user %s to admin role", adminEmail)
+			slog.InfoContext(ctx, "auth.admin.upgrade", "email", adminEmail)
 			// 直接更新角色 — 简单做法
 		}
-		log.Printf("[auth] Admin user already exists: %s (%s)", adminEmail, existing.ID)
+		slog.InfoContext(ctx, "auth.admin.exists", "user_id", existing.ID, "email", adminEmail)
 		return nil
 	}
 
@@ -366,7 +457,7 @@ func EnsureAdminUser(store UserStore, adminEmail, adminPassword string) error {
 	if err := store.CreateUser(ctx, user); err != nil {
 		return fmt.Errorf("create admin user: %w", err)
 	}
-	log.Printf("[auth] Created admin user: %s (%s)", adminEmail, user.ID)
+	slog.InfoContext(ctx, "auth.admin.created", "user_id", user.ID, "email", adminEmail)
 	return nil
 }
 
@@ -0,0 +1,408 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ============================================================================
+// 两步验证（TOTP + 备用码）
+// ============================================================================
+//
+// 启用流程：enroll 生成密钥（返回 otpauth:// 地址供验证器扫码）→ verify 以动态码确认后启用，返回一次性展示的备用码。
+// 登录流程：密码校验通过且已启用两步验证时，login 返回 mfa_token（5 分钟有效），
+// 客户端再以 mfa_token + 动态码（或备用码）调用 /api/v1/auth/login/2fa 完成登录。
+// 同一用户连续校验失败 5 次后锁定 5 分钟；同一动态码只能使用一次，备用码使用后作废。
+
+const (
+	mfaTokenTTL    = 5 * time.Minute
+	mfaMaxFailures = 5
+	mfaLockout     = 5 * time.Minute
+)
+
+type mfaCodeRequest struct {
+	Code string `json:"code"`
+}
+
+type loginMFARequest struct {
+	MFAToken string `json:"mfa_token"`
+	Code     string `json:"code"`
+}
+
+type mfaChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+}
+
+type mfaEnrollResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURL string `json:"otpauth_url"`
+}
+
+type mfaStatusResponse struct {
+	Enabled              bool `json:"enabled"`
+	Pending              bool `json:"pending"` // 已生成密钥但尚未确认
+	BackupCodesRemaining int  `json:"backup_codes_remaining"`
+}
+
+// generateMFAToken 生成两步验证阶段的短期令牌（只能用于 /api/v1/auth/login/2fa）
+func generateMFAToken(cfg Config, userID string) (string, error) {
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   userID,
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(mfaTokenTTL)),
+		},
+		Type: "mfa",
+	}
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(cfg.JWTSecret))
+}
+
+// LoginMFA 以 mfa_token 与动态码（或备用码）完成登录
+func (h *Handler) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var req loginMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.MFAToken == "" || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "mfa_token and code are required")
+		return
+	}
+	claims, err := ParseToken(h.cfg, req.MFAToken)
+	if err != nil || claims.Type != "mfa" {
+		writeError(w, http.StatusUnauthorized, "invalid or expired mfa token")
+		return
+	}
+
+	user, err := h.store.GetUserByID(r.Context(), claims.Subject)
+	if err != nil || user == nil {
+		writeError(w, http.StatusUnauthorized, "user not found")
+		return
+	}
+	if user.Status == model.UserStatusDisabled {
+		writeError(w, http.StatusForbidden, "account is disabled")
+		return
+	}
+	mfa, err := h.store.GetUserMFA(r.Context(), user.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.login.2fa", "op", "GetUserMFA", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if mfa == nil || !mfa.Enabled {
+		writeError(w, http.StatusBadRequest, "two-factor authentication is not enabled")
+		return
+	}
+	if !h.checkMFACode(w, r, mfa, req.Code, true) {
+		return
+	}
+
+	resp, err := h.startSession(w, r, user, sessionMethodPasswordMFA)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.login.2fa", "op", "startSession", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.InfoContext(r.Context(), "auth.login.success", "user_id", user.ID, "email", user.Email, "method", "password+2fa")
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// MFAStatus 当前用户的两步验证状态
+func (h *Handler) MFAStatus(w http.ResponseWriter, r *http.Request) {
+	authUser := GetAuthUser(r.Context())
+	if authUser == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	mfa, err := h.store.GetUserMFA(r.Context(), authUser.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	resp := mfaStatusResponse{}
+	if mfa != nil {
+		resp.Enabled = mfa.Enabled
+		resp.Pending = !mfa.Enabled
+		resp.BackupCodesRemaining = len(mfa.BackupCodes)
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// EnrollMFA 生成两步验证密钥（确认前不生效，重复调用会替换未确认的密钥）
+func (h *Handler) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	authUser := GetAuthUser(r.Context())
+	if authUser == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	if h.box == nil {
+		writeError(w, http.StatusServiceUnavailable, "two-factor authentication requires MASTER_KEY")
+		return
+	}
+	existing, err := h.store.GetUserMFA(r.Context(), authUser.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if existing != nil && existing.Enabled {
+		writeError(w, http.StatusConflict, "two-factor authentication is already enabled")
+		return
+	}
+
+	secret := newTOTPSecret()
+	encrypted, err := h.box.Encrypt(secret)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	now := time.Now()
+	if err := h.store.SaveUserMFA(r.Context(), &model.UserMFA{
+		UserID:    authUser.ID,
+		Secret:    encrypted,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}); err != nil {
+		slog.ErrorContext(r.Context(), "auth.2fa", "op", "SaveUserMFA", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, mfaEnrollResponse{Secret: secret, OTPAuthURL: totpURL(secret, authUser.Email)})
+}
+
+// ConfirmMFA 以动态码确认并启用两步验证，返回备用码（只展示这一次）
+func (h *Handler) ConfirmMFA(w http.ResponseWriter, r *http.Request) {
+	authUser := GetAuthUser(r.Context())
+	if authUser == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+	mfa, err := h.store.GetUserMFA(r.Context(), authUser.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if mfa == nil {
+		writeError(w, http.StatusBadRequest, "call /api/v1/auth/2fa/enroll first")
+		return
+	}
+	if mfa.Enabled {
+		writeError(w, http.StatusConflict, "two-factor authentication is already enabled")
+		return
+	}
+	if !h.checkMFACode(w, r, mfa, req.Code, false) {
+		return
+	}
+
+	codes, hashes := newBackupCodes()
+	mfa.Enabled = true
+	mfa.BackupCodes = hashes
+	mfa.UpdatedAt = time.Now()
+	if latest, err := h.store.GetUserMFA(r.Context(), authUser.ID); err == nil && latest != nil {
+		mfa.LastStep = latest.LastStep
+	}
+	if err := h.store.SaveUserMFA(r.Context(), mfa); err != nil {
+		slog.ErrorContext(r.Context(), "auth.2fa", "op", "SaveUserMFA", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.InfoContext(r.Context(), "auth.2fa.enabled", "user_id", authUser.ID, "email", authUser.Email)
+	writeJSON(w, http.StatusOK, map[string]interface{}{"enabled": true, "backup_codes": codes})
+}
+
+// RegenerateBackupCodes 以动态码重新生成备用码（旧备用码全部作废）
+func (h *Handler) RegenerateBackupCodes(w http.ResponseWriter, r *http.Request) {
+	authUser := GetAuthUser(r.Context())
+	if authUser == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+	mfa, err := h.store.GetUserMFA(r.Context(), authUser.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if mfa == nil || !mfa.Enabled {
+		writeError(w, http.StatusBadRequest, "two-factor authentication is not enabled")
+		return
+	}
+	if !h.checkMFACode(w, r, mfa, req.Code, false) {
+		return
+	}
+
+	codes, hashes := newBackupCodes()
+	if latest, err := h.store.GetUserMFA(r.Context(), authUser.ID); err == nil && latest != nil {
+		mfa = latest
+	}
+	mfa.BackupCodes = hashes
+	mfa.UpdatedAt = time.Now()
+	if err := h.store.SaveUserMFA(r.Context(), mfa); err != nil {
+		slog.ErrorContext(r.Context(), "auth.2fa", "op", "SaveUserMFA", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"backup_codes": codes})
+}
+
+// DisableMFA 关闭两步验证
+//
+// 用户关闭自己的两步验证需要动态码或备用码；管理员指定 user_id 时直接重置该用户的两步验证（丢失设备时使用）。
+func (h *Handler) DisableMFA(w http.ResponseWriter, r *http.Request) {
+	authUser := GetAuthUser(r.Context())
+	if authUser == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return
+	}
+	userID := r.URL.Query().Get("user_id")
+	if userID != "" && userID != authUser.ID {
+		if authUser.Role != UserRoleAdmin {
+			writeError(w, http.StatusForbidden, "admin access required")
+			return
+		}
+		if err := h.store.DeleteUserMFA(r.Context(), userID); err != nil {
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		slog.InfoContext(r.Context(), "auth", "detail", "2FA reset by admin", "user", userID, "by", authUser.ID)
+		writeJSON(w, http.StatusOK, map[string]string{"message": "two-factor authentication disabled"})
+		return
+	}
+
+	var req mfaCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Code == "" {
+		writeError(w, http.StatusBadRequest, "code is required")
+		return
+	}
+	mfa, err := h.store.GetUserMFA(r.Context(), authUser.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if mfa == nil || !mfa.Enabled {
+		writeError(w, http.StatusBadRequest, "two-factor authentication is not enabled")
+		return
+	}
+	if !h.checkMFACode(w, r, mfa, req.Code, true) {
+		return
+	}
+	if err := h.store.DeleteUserMFA(r.Context(), authUser.ID); err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	slog.InfoContext(r.Context(), "auth.2fa.disabled", "user_id", authUser.ID, "email", authUser.Email)
+	writeJSON(w, http.StatusOK, map[string]string{"message": "two-factor authentication disabled"})
+}
+
+// checkMFACode 校验动态码（allowBackup 时也接受备用码），失败时写入错误响应并返回 false
+func (h *Handler) checkMFACode(w http.ResponseWriter, r *http.Request, mfa *model.UserMFA, code string, allowBackup bool) bool {
+	if h.mfaFailures.locked(mfa.UserID) {
+		writeError(w, http.StatusTooManyRequests, "too many failed attempts, try again later")
+		return false
+	}
+	ok, err := h.verifyMFACode(r.Context(), mfa, code, allowBackup)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.2fa", "detail", "verify code error", "user", mfa.UserID, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return false
+	}
+	if !ok {
+		h.mfaFailures.fail(mfa.UserID)
+		writeError(w, http.StatusUnauthorized, "invalid verification code")
+		return false
+	}
+	h.mfaFailures.reset(mfa.UserID)
+	return true
+}
+
+// verifyMFACode 校验动态码或备用码（通过的动态码与备用码随即作废）
+func (h *Handler) verifyMFACode(ctx context.Context, mfa *model.UserMFA, code string, allowBackup bool) (bool, error) {
+	if !isTOTPCode(code) {
+		if !allowBackup {
+			return false, nil
+		}
+		return h.store.ConsumeUserBackupCode(ctx, mfa.UserID, hashBackupCode(code))
+	}
+	if h.box == nil {
+		return false, fmt.Errorf("MASTER_KEY not configured")
+	}
+	secret, err := h.box.Decrypt(mfa.Secret)
+	if err != nil {
+		return false, fmt.Errorf("decrypt totp secret: %w", err)
+	}
+	step, ok := matchTOTP(secret, code, time.Now())
+	if !ok || step <= mfa.LastStep {
+		return false, nil
+	}
+	return h.store.UseUserMFAStep(ctx, mfa.UserID, step)
+}
+
+// failureLimiter 按键统计连续失败次数，达到上限后锁定一段时间
+type failureLimiter struct {
+	max     int
+	lockout time.Duration
+
+	mu       sync.Mutex
+	failures map[string]*failureRecord
+}
+
+type failureRecord struct {
+	count int
+	until time.Time // 锁定截止时间
+}
+
+func newFailureLimiter(max int, lockout time.Duration) *failureLimiter {
+	return &failureLimiter{max: max, lockout: lockout, failures: make(map[string]*failureRecord)}
+}
+
+func (l *failureLimiter) locked(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec := l.failures[key]
+	if rec == nil || rec.until.IsZero() {
+		return false
+	}
+	if time.Now().After(rec.until) {
+		delete(l.failures, key)
+		return false
+	}
+	return true
+}
+
+func (l *failureLimiter) fail(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec := l.failures[key]
+	if rec == nil {
+		rec = &failureRecord{}
+		l.failures[key] = rec
+	}
+	rec.count++
+	if rec.count >= l.max {
+		rec.until = time.Now().Add(l.lockout)
+	}
+}
+
+func (l *failureLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
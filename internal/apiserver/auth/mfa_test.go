@@ -0,0 +1,359 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func (m *memUserStore) GetUserMFA(_ context.Context, userID string) (*model.UserMFA, error) {
+	if mfa := m.mfa[userID]; mfa != nil {
+		cp := *mfa
+		cp.BackupCodes = slices.Clone(mfa.BackupCodes)
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (m *memUserStore) SaveUserMFA(_ context.Context, mfa *model.UserMFA) error {
+	if m.mfa == nil {
+		m.mfa = map[string]*model.UserMFA{}
+	}
+	cp := *mfa
+	m.mfa[mfa.UserID] = &cp
+	return nil
+}
+
+func (m *memUserStore) DeleteUserMFA(_ context.Context, userID string) error {
+	delete(m.mfa, userID)
+	return nil
+}
+
+func (m *memUserStore) UseUserMFAStep(_ context.Context, userID string, step int64) (bool, error) {
+	mfa := m.mfa[userID]
+	if mfa == nil || step <= mfa.LastStep {
+		return false, nil
+	}
+	mfa.LastStep = step
+	return true, nil
+}
+
+func (m *memUserStore) ConsumeUserBackupCode(_ context.Context, userID, codeHash string) (bool, error) {
+	mfa := m.mfa[userID]
+	if mfa == nil {
+		return false, nil
+	}
+	i := slices.Index(mfa.BackupCodes, codeHash)
+	if i < 0 {
+		return false, nil
+	}
+	mfa.BackupCodes = slices.Delete(mfa.BackupCodes, i, i+1)
+	return true, nil
+}
+
+func (m *memUserStore) CreateUserSession(_ context.Context, session *model.UserSession) error {
+	if m.sessions == nil {
+		m.sessions = map[string]*model.UserSession{}
+	}
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *memUserStore) GetUserSession(_ context.Context, id string) (*model.UserSession, error) {
+	return m.sessions[id], nil
+}
+
+func (m *memUserStore) ListUserSessions(_ context.Context, userID string) ([]*model.UserSession, error) {
+	var out []*model.UserSession
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.Active(time.Now()) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *memUserStore) TouchUserSession(_ context.Context, id string, at time.Time) error {
+	if s := m.sessions[id]; s != nil {
+		s.LastUsedAt = at
+	}
+	return nil
+}
+
+func (m *memUserStore) RevokeUserSession(_ context.Context, userID, id string) (bool, error) {
+	s := m.sessions[id]
+	if s == nil || s.UserID != userID || s.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	s.RevokedAt = &now
+	return true, nil
+}
+
+func (m *memUserStore) RevokeUserSessions(_ context.Context, userID, exceptID string) (int64, error) {
+	var n int64
+	for _, s := range m.sessions {
+		if s.UserID == userID && s.ID != exceptID && s.RevokedAt == nil {
+			now := time.Now()
+			s.RevokedAt = &now
+			n++
+		}
+	}
+	return n, nil
+}
+
+func TestTOTPCode_RFC6238(t *testing.T) {
+	// RFC 6238 附录 B 的 SHA-1 测试向量（密钥 "12345678901234567890"，取后 6 位）
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	for _, tc := range []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	} {
+		got, err := totpCode(secret, tc.unix/totpPeriod)
+		if err != nil || got != tc.want {
+			t.Errorf("totpCode(t=%d) = %q, %v; want %q", tc.unix, got, err, tc.want)
+		}
+	}
+
+	now := time.Unix(1111111109, 0)
+	if step, ok := matchTOTP(secret, "081804", now.Add(29*time.Second)); !ok || step != 1111111109/totpPeriod {
+		t.Errorf("code from previous step should match: step = %d, ok = %v", step, ok)
+	}
+	if _, ok := matchTOTP(secret, "081804", now.Add(2*time.Minute)); ok {
+		t.Error("stale code should not match")
+	}
+}
+
+func TestHashBackupCode_Normalizes(t *testing.T) {
+	codes, hashes := newBackupCodes()
+	if len(codes) != backupCodeCount || len(hashes) != backupCodeCount {
+		t.Fatalf("got %d codes, %d hashes", len(codes), len(hashes))
+	}
+	if hashBackupCode(codes[0]) != hashes[0] {
+		t.Error("hash of displayed code should match stored hash")
+	}
+	if hashBackupCode("ABCD-EFGH") != hashBackupCode("abcd efgh") {
+		t.Error("hash should ignore case, spaces and dashes")
+	}
+}
+
+// authClient 携带访问令牌调用认证接口
+type authClient struct {
+	t     *testing.T
+	mux   *http.ServeMux
+	token string
+}
+
+func (c *authClient) do(method, path string, body interface{}) (*httptest.ResponseRecorder, map[string]interface{}) {
+	c.t.Helper()
+	var buf bytes.Buffer
+	if body != nil {
+		json.NewEncoder(&buf).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &buf)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	w := httptest.NewRecorder()
+	c.mux.ServeHTTP(w, req)
+	var out map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &out)
+	return w, out
+}
+
+func newMFATestHandler(t *testing.T) (*memUserStore, *http.ServeMux) {
+	cfg := DefaultConfig()
+	cfg.JWTSecret = "test-secret-test-secret-test-secret"
+	cfg.MasterKey = "test-master-key"
+	store := &memUserStore{users: map[string]*model.User{}}
+	hash, _ := HashPassword("password123")
+	store.users["alice@example.com"] = &model.User{
+		ID: "usr-alice", Email: "alice@example.com", PasswordHash: hash,
+		Role: model.UserRoleUser, Status: model.UserStatusActive,
+	}
+
+	mux := http.NewServeMux()
+	h := NewHandler(store, cfg)
+	h.RegisterRoutes(mux)
+	cfg.Sessions = h
+	api := http.NewServeMux()
+	api.Handle("/", Middleware(cfg)(mux))
+	return store, api
+}
+
+func TestLogin_TwoFactorFlow(t *testing.T) {
+	store, mux := newMFATestHandler(t)
+	c := &authClient{t: t, mux: mux}
+
+	w, resp := c.do(http.MethodPost, "/api/v1/auth/login", map[string]string{"email": "alice@example.com", "password": "password123"})
+	if w.Code != http.StatusOK || resp["access_token"] == nil {
+		t.Fatalf("login status = %d, body = %s", w.Code, w.Body.String())
+	}
+	c.token = resp["access_token"].(string)
+
+	// 启用两步验证
+	_, enroll := c.do(http.MethodPost, "/api/v1/auth/2fa/enroll", nil)
+	secret, _ := enroll["secret"].(string)
+	if secret == "" || store.mfa["usr-alice"].Secret == secret {
+		t.Fatalf("enroll should return secret and store it encrypted: %v", enroll)
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/2fa/verify", map[string]string{"code": "000000"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong code: expected 401, got %d", w.Code)
+	}
+	code, _ := totpCode(secret, time.Now().Unix()/totpPeriod)
+	w, confirm := c.do(http.MethodPost, "/api/v1/auth/2fa/verify", map[string]string{"code": code})
+	backup, _ := confirm["backup_codes"].([]interface{})
+	if w.Code != http.StatusOK || len(backup) != backupCodeCount || !store.mfa["usr-alice"].Enabled {
+		t.Fatalf("verify status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	// 密码登录只返回 mfa_token
+	c.token = ""
+	_, resp = c.do(http.MethodPost, "/api/v1/auth/login", map[string]string{"email": "alice@example.com", "password": "password123"})
+	mfaToken, _ := resp["mfa_token"].(string)
+	if resp["mfa_required"] != true || mfaToken == "" || resp["access_token"] != nil {
+		t.Fatalf("expected mfa challenge, got %v", resp)
+	}
+	// mfa_token 不能当作访问令牌使用
+	c.token = mfaToken
+	if w, _ := c.do(http.MethodGet, "/api/v1/auth/me", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("mfa token used as access token: expected 401, got %d", w.Code)
+	}
+	c.token = ""
+
+	// 已用过的动态码不能再次使用，备用码只能用一次
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": code}); w.Code != http.StatusUnauthorized {
+		t.Errorf("replayed code: expected 401, got %d", w.Code)
+	}
+	w, resp = c.do(http.MethodPost, "/api/v1/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": backup[0].(string)})
+	if w.Code != http.StatusOK || resp["access_token"] == nil {
+		t.Fatalf("backup code login status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": backup[0].(string)}); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused backup code: expected 401, got %d", w.Code)
+	}
+	if n := len(store.mfa["usr-alice"].BackupCodes); n != backupCodeCount-1 {
+		t.Errorf("backup codes remaining = %d", n)
+	}
+
+	// 连续失败后锁定
+	for range mfaMaxFailures {
+		c.do(http.MethodPost, "/api/v1/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": "999999"})
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/login/2fa", map[string]string{"mfa_token": mfaToken, "code": backup[1].(string)}); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 after %d failures, got %d", mfaMaxFailures, w.Code)
+	}
+}
+
+func TestSessions_ListAndRevoke(t *testing.T) {
+	store, mux := newMFATestHandler(t)
+	c := &authClient{t: t, mux: mux}
+
+	login := func() (string, string) {
+		_, resp := c.do(http.MethodPost, "/api/v1/auth/login", map[string]string{"email": "alice@example.com", "password": "password123"})
+		return resp["access_token"].(string), resp["refresh_token"].(string)
+	}
+	oldAccess, oldRefresh := login()
+	otherAccess, otherRefresh := login()
+	access, refresh := login()
+	c.token = access
+
+	_, list := c.do(http.MethodGet, "/api/v1/auth/sessions", nil)
+	if list["count"] != float64(3) {
+		t.Fatalf("expected 3 sessions, got %v", list)
+	}
+	claims, _ := ParseToken(Config{JWTSecret: "test-secret-test-secret-test-secret"}, oldRefresh)
+	if store.sessions[claims.SessionID] == nil {
+		t.Fatalf("refresh token should carry session id: %+v", claims)
+	}
+
+	// 撤销单个会话后其刷新令牌失效
+	if w, _ := c.do(http.MethodDelete, "/api/v1/auth/sessions/"+claims.SessionID, nil); w.Code != http.StatusOK {
+		t.Fatalf("revoke status = %d", w.Code)
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/refresh", map[string]string{"refresh_token": oldRefresh}); w.Code != http.StatusUnauthorized {
+		t.Errorf("revoked session refresh: expected 401, got %d", w.Code)
+	}
+	// 访问令牌同样失效
+	if w := meStatus(c, oldAccess); w != http.StatusUnauthorized {
+		t.Errorf("revoked session access token: expected 401, got %d", w)
+	}
+	if w, _ := c.do(http.MethodDelete, "/api/v1/auth/sessions/ses-missing", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing session: expected 404, got %d", w.Code)
+	}
+
+	// 撤销全部会话时保留当前会话
+	if _, resp := c.do(http.MethodDelete, "/api/v1/auth/sessions", nil); resp["revoked"] != float64(1) {
+		t.Errorf("expected 1 revoked, got %v", resp)
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/refresh", map[string]string{"refresh_token": otherRefresh}); w.Code != http.StatusUnauthorized {
+		t.Errorf("other session refresh: expected 401, got %d", w.Code)
+	}
+	if w := meStatus(c, otherAccess); w != http.StatusUnauthorized {
+		t.Errorf("other session access token: expected 401, got %d", w)
+	}
+	if w := meStatus(c, access); w != http.StatusOK {
+		t.Errorf("current session access token: expected 200, got %d", w)
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/refresh", map[string]string{"refresh_token": refresh}); w.Code != http.StatusOK {
+		t.Errorf("current session refresh: expected 200, got %d", w.Code)
+	}
+
+	// 普通用户不能查看其他用户的会话
+	if w, _ := c.do(http.MethodGet, "/api/v1/auth/sessions?user_id=usr-bob", nil); w.Code != http.StatusForbidden {
+		t.Errorf("expected 403, got %d", w.Code)
+	}
+
+	// 登出撤销当前会话
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/logout", nil); w.Code != http.StatusOK {
+		t.Fatalf("logout status = %d", w.Code)
+	}
+	if w, _ := c.do(http.MethodPost, "/api/v1/auth/refresh", map[string]string{"refresh_token": refresh}); w.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout: expected 401, got %d", w.Code)
+	}
+	if w := meStatus(c, access); w != http.StatusUnauthorized {
+		t.Errorf("access token after logout: expected 401, got %d", w)
+	}
+}
+
+// meStatus 以指定访问令牌请求 /api/v1/auth/me，返回状态码
+func meStatus(c *authClient, token string) int {
+	saved := c.token
+	defer func() { c.token = saved }()
+	c.token = token
+	w, _ := c.do(http.MethodGet, "/api/v1/auth/me", nil)
+	return w.Code
+}
+
+func TestMiddleware_RevokedSession(t *testing.T) {
+	store, mux := newMFATestHandler(t)
+	c := &authClient{t: t, mux: mux}
+	_, resp := c.do(http.MethodPost, "/api/v1/auth/login", map[string]string{"email": "alice@example.com", "password": "password123"})
+	access := resp["access_token"].(string)
+	claims, _ := ParseToken(Config{JWTSecret: "test-secret-test-secret-test-secret"}, access)
+
+	// 其他实例撤销的会话（本实例未缓存）
+	now := time.Now()
+	store.sessions[claims.SessionID].RevokedAt = &now
+	if w := meStatus(c, access); w != http.StatusUnauthorized {
+		t.Errorf("revoked session: expected 401, got %d", w)
+	}
+
+	// 会话记录不存在（如已清理）同样拒绝
+	delete(store.sessions, claims.SessionID)
+	if w := meStatus(c, access); w != http.StatusUnauthorized {
+		t.Errorf("missing session: expected 401, got %d", w)
+	}
+}
@@ -1,9 +1,14 @@
 package auth
 
 import (
-	"log"
+	"context"
+	"crypto/tls"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strings"
+
+	"agents-admin/internal/tlsutil"
 )
 
 // 公开路由前缀（无需任何认证）
@@ -11,6 +16,7 @@ var publicPrefixes = []string{
 	"/api/v1/auth/register",
 	"/api/v1/auth/login",
 	"/api/v1/auth/refresh",
+	"/api/v1/auth/oidc/",
 	"/api/v1/node-bootstrap",
 	"/health",
 	"/metrics",
@@ -28,6 +34,10 @@ func isPublicRoute(method, path string) bool {
 	if method == "POST" && path == "/api/v1/nodes/heartbeat" {
 		return true
 	}
+	// 节点加入：以一次性加入令牌换取专属凭证，令牌在处理器中校验
+	if method == "POST" && path == "/api/v1/nodes/join" {
+		return true
+	}
 	return false
 }
 
@@ -40,12 +50,41 @@ func isValidNodeToken(r *http.Request, nodeToken string) bool {
 	return token != "" && token == nodeToken
 }
 
+// authenticateNode 节点认证：共享密钥、节点客户端证书、节点专属 Token，返回节点 ID（共享密钥时为空）
+func authenticateNode(r *http.Request, cfg Config) (string, bool) {
+	return AuthenticateNode(r.Context(), cfg, r.Header.Get("X-Node-Token"), r.TLS)
+}
+
+// AuthenticateNode 以 X-Node-Token 与 TLS 连接状态认证节点（HTTP 中间件与 gRPC 拦截器共用）
+//
+// 返回节点 ID（共享密钥认证时为空）；state 为 nil 时不检查客户端证书。
+func AuthenticateNode(ctx context.Context, cfg Config, token string, state *tls.ConnectionState) (string, bool) {
+	if cfg.NodeToken != "" && token == cfg.NodeToken {
+		return "", true
+	}
+	if cfg.NodeCredentials == nil {
+		return "", false
+	}
+	// 客户端证书由 TLS 层以 CA 校验，这里确认是节点证书且未被撤销
+	if state != nil && len(state.VerifiedChains) > 0 {
+		leaf := state.VerifiedChains[0][0]
+		if slices.Contains(leaf.Subject.OrganizationalUnit, tlsutil.NodeCertOU) &&
+			cfg.NodeCredentials.VerifyNodeCert(ctx, leaf.Subject.CommonName, tlsutil.CertSerial(leaf)) {
+			return leaf.Subject.CommonName, true
+		}
+	}
+	if token != "" {
+		return cfg.NodeCredentials.VerifyNodeToken(ctx, token)
+	}
+	return "", false
+}
+
 // Middleware 创建认证中间件
 //
 // 认证策略（优先级从高到低）：
-//  1. 公开路由（login/register/health/heartbeat）：直接放行
-//  2. X-Node-Token header：NodeManager 共享密钥认证，匹配则放行
-//  3. JWT（Bearer token 或 Cookie）：用户认证
+//  1. 公开路由（login/register/OIDC 登录回调/health/heartbeat）：直接放行，心跳携带有效节点凭证时注入节点身份
+//  2. 节点认证：X-Node-Token 共享密钥、节点客户端证书或节点专属 Token，匹配则放行并注入节点身份
+//  3. JWT（Bearer token 或 Cookie）：用户认证，配置了 cfg.Sessions 时同时校验令牌所属会话未被撤销
 //
 // 如果 cfg.Enabled() == false，直接放行所有请求（无认证模式）
 func Middleware(cfg Config) func(http.Handler) http.Handler {
@@ -53,12 +92,17 @@ func Middleware(cfg Config) func(http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			// 无认证模式：直接放行
 			if !cfg.Enabled() {
-				next.ServeHTTP(w, r)
+				next.ServeHTTP(w, r.WithContext(WithAuthDisabled(r.Context())))
 				return
 			}
 
-			// 公开路由：直接放行
+			// 公开路由：直接放行（心跳携带有效节点凭证时注入节点身份，供节点范围校验 node_id）
 			if isPublicRoute(r.Method, r.URL.Path) {
+				if r.Method == "POST" && r.URL.Path == "/api/v1/nodes/heartbeat" {
+					if nodeID, ok := authenticateNode(r, cfg); ok {
+						r = r.WithContext(WithNodeIdentity(r.Context(), nodeID))
+					}
+				}
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -69,9 +113,9 @@ func Middleware(cfg Config) func(http.Handler) http.Handler {
 				return
 			}
 
-			// NodeManager Token 认证：X-Node-Token header 匹配则放行
-			if isValidNodeToken(r, cfg.NodeToken) {
-				next.ServeHTTP(w, r)
+			// NodeManager 认证：共享密钥或节点专属凭证匹配则放行
+			if nodeID, ok := authenticateNode(r, cfg); ok {
+				next.ServeHTTP(w, r.WithContext(WithNodeIdentity(r.Context(), nodeID)))
 				return
 			}
 
@@ -97,7 +141,7 @@ func Middleware(cfg Config) func(http.Handler) http.Handler {
 			// 解析 JWT
 			claims, err := ParseToken(cfg, tokenString)
 			if err != nil {
-				log.Printf("[auth] token parse error: %v", err)
+				slog.ErrorContext(r.Context(), "auth", "detail", "token parse error", "error", err)
 				http.Error(w, `{"error":"invalid or expired token"}`, http.StatusUnauthorized)
 				return
 			}
@@ -107,11 +151,18 @@ func Middleware(cfg Config) func(http.Handler) http.Handler {
 				return
 			}
 
+			// 会话已撤销（登出、撤销会话）的访问令牌不再可用
+			if cfg.Sessions != nil && claims.SessionID != "" && !cfg.Sessions.VerifySession(r.Context(), claims.SessionID) {
+				http.Error(w, `{"error":"session revoked"}`, http.StatusUnauthorized)
+				return
+			}
+
 			// 注入 auth user 到 context
 			user := &AuthUser{
-				ID:    claims.Subject,
-				Email: claims.Email,
-				Role:  claims.Role,
+				ID:        claims.Subject,
+				Email:     claims.Email,
+				Role:      claims.Role,
+				SessionID: claims.SessionID,
 			}
 			ctx := WithAuthUser(r.Context(), user)
 
@@ -128,16 +179,29 @@ func Middleware(cfg Config) func(http.Handler) http.Handler {
 }
 
 // AdminOnly 管理员专属路由中间件
+//
+// 节点凭证（共享密钥、节点专属 Token 或客户端证书）与非管理员用户返回 403；
+// 没有用户会话的请求只在无认证模式（见 AuthDisabled）下放行，启用认证时同样返回 403。
 func AdminOnly(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		user := GetAuthUser(r.Context())
-		if user == nil || user.Role != string(UserRoleAdmin) {
-			http.Error(w, `{"error":"admin access required"}`, http.StatusForbidden)
+		if !IsAdmin(r.Context()) {
+			writeError(w, http.StatusForbidden, "admin access required")
 			return
 		}
 		next(w, r)
 	}
 }
 
+// IsAdmin 请求是否具有管理员权限：管理员用户，或无认证模式下没有节点凭证的请求
+func IsAdmin(ctx context.Context) bool {
+	if GetNodeIdentity(ctx) != nil {
+		return false
+	}
+	if user := GetAuthUser(ctx); user != nil {
+		return user.Role == UserRoleAdmin
+	}
+	return AuthDisabled(ctx)
+}
+
 // UserRoleAdmin 管理员角色常量（避免 model 包循环引用）
 const UserRoleAdmin = "admin"
@@ -1,7 +1,9 @@
 package auth
 
 import (
+	"context"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -15,8 +17,12 @@ func TestIsPublicRoute(t *testing.T) {
 		// 公开路由
 		{"login", "POST", "/api/v1/auth/login", true},
 		{"register", "POST", "/api/v1/auth/register", true},
+		{"login 2fa", "POST", "/api/v1/auth/login/2fa", true},
+		{"oidc login", "GET", "/api/v1/auth/oidc/login", true},
+		{"oidc callback", "GET", "/api/v1/auth/oidc/callback", true},
 		{"health", "GET", "/health", true},
 		{"heartbeat", "POST", "/api/v1/nodes/heartbeat", true},
+		{"node join", "POST", "/api/v1/nodes/join", true},
 		{"bootstrap", "GET", "/api/v1/node-bootstrap", true},
 		{"metrics", "GET", "/metrics", true},
 		{"ws", "GET", "/ws/monitor", true},
@@ -30,6 +36,7 @@ func TestIsPublicRoute(t *testing.T) {
 		{"patch action needs token", "PATCH", "/api/v1/actions/act-123", false},
 		{"get agent-type needs token", "GET", "/api/v1/agent-types/qwen-code", false},
 		{"get account needs token", "GET", "/api/v1/accounts/acc-1", false},
+		{"join tokens need admin", "POST", "/api/v1/nodes/join-tokens", false},
 
 		// 普通用户路由需要 JWT
 		{"create operation", "POST", "/api/v1/operations", false},
@@ -73,3 +80,140 @@ func TestIsValidNodeToken(t *testing.T) {
 		})
 	}
 }
+
+// fakeNodeCredentials 只接受 node-1 的专属 Token
+type fakeNodeCredentials struct{}
+
+func (fakeNodeCredentials) VerifyNodeToken(_ context.Context, token string) (string, bool) {
+	if token == "node-1-token" {
+		return "node-1", true
+	}
+	return "", false
+}
+
+func (fakeNodeCredentials) VerifyNodeCert(_ context.Context, _, _ string) bool { return false }
+
+func TestMiddleware_NodeCredentials(t *testing.T) {
+	tests := []struct {
+		name       string
+		nodeToken  string // 共享密钥，为空表示已禁用
+		header     string
+		wantStatus int
+		wantNode   string
+	}{
+		{"shared token", "shared", "shared", http.StatusOK, ""},
+		{"per-node token", "shared", "node-1-token", http.StatusOK, "node-1"},
+		{"shared token disabled", "", "shared", http.StatusUnauthorized, ""},
+		{"per-node token without shared", "", "node-1-token", http.StatusOK, "node-1"},
+		{"unknown token", "", "unknown", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{JWTSecret: "test-secret", NodeToken: tt.nodeToken, NodeCredentials: fakeNodeCredentials{}}
+			var got *NodeIdentity
+			handler := Middleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetNodeIdentity(r.Context())
+			}))
+
+			r := httptest.NewRequest("GET", "/api/v1/nodes/node-1/runs", nil)
+			r.Header.Set("X-Node-Token", tt.header)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && (got == nil || got.ID != tt.wantNode) {
+				t.Errorf("node identity = %+v, want %q", got, tt.wantNode)
+			}
+		})
+	}
+}
+
+// TestMiddleware_HeartbeatIdentity 心跳公开，携带有效节点凭证时注入节点身份
+func TestMiddleware_HeartbeatIdentity(t *testing.T) {
+	cfg := Config{JWTSecret: "test-secret", NodeToken: "shared", NodeCredentials: fakeNodeCredentials{}}
+	tests := []struct {
+		name     string
+		header   string
+		wantNode *NodeIdentity
+	}{
+		{"anonymous", "", nil},
+		{"unknown token", "unknown", nil},
+		{"shared token", "shared", &NodeIdentity{}},
+		{"per-node token", "node-1-token", &NodeIdentity{ID: "node-1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got *NodeIdentity
+			handler := Middleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = GetNodeIdentity(r.Context())
+			}))
+			r := httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", nil)
+			if tt.header != "" {
+				r.Header.Set("X-Node-Token", tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want 200", w.Code)
+			}
+			if (got == nil) != (tt.wantNode == nil) || (got != nil && got.ID != tt.wantNode.ID) {
+				t.Errorf("node identity = %+v, want %+v", got, tt.wantNode)
+			}
+		})
+	}
+}
+
+func TestAdminOnly(t *testing.T) {
+	tests := []struct {
+		name string
+		ctx  func(context.Context) context.Context
+		want int
+	}{
+		{"admin", func(ctx context.Context) context.Context {
+			return WithAuthUser(ctx, &AuthUser{ID: "u1", Role: UserRoleAdmin})
+		}, http.StatusOK},
+		{"user", func(ctx context.Context) context.Context { return WithAuthUser(ctx, &AuthUser{ID: "u2", Role: "user"}) }, http.StatusForbidden},
+		{"shared node token", func(ctx context.Context) context.Context { return WithNodeIdentity(ctx, "") }, http.StatusForbidden},
+		{"per-node credential", func(ctx context.Context) context.Context { return WithNodeIdentity(ctx, "node-1") }, http.StatusForbidden},
+		{"no auth mode", WithAuthDisabled, http.StatusOK},
+		{"no user with auth enabled", func(ctx context.Context) context.Context { return ctx }, http.StatusForbidden},
+		{"shared node token in no auth mode", func(ctx context.Context) context.Context {
+			return WithNodeIdentity(WithAuthDisabled(ctx), "")
+		}, http.StatusForbidden},
+	}
+	handler := AdminOnly(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/api/v1/projects", nil)
+			w := httptest.NewRecorder()
+			handler(w, r.WithContext(tt.ctx(r.Context())))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
+
+// TestAdminOnly_Middleware 无认证模式由中间件标记；启用认证时跳过认证的公开路由不能通过 AdminOnly
+func TestAdminOnly_Middleware(t *testing.T) {
+	handler := AdminOnly(func(w http.ResponseWriter, r *http.Request) {})
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+		want int
+	}{
+		{"auth disabled", Config{}, http.StatusOK},
+		{"public route with auth enabled", Config{JWTSecret: "test-secret"}, http.StatusForbidden},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			Middleware(tt.cfg)(handler).ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,524 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ============================================================================
+// OIDC 单点登录
+// ============================================================================
+//
+// 授权码流程（带 PKCE）：
+//  1. GET /api/v1/auth/oidc/login：生成 state/nonce/code_verifier，写入签名的 oidc_state Cookie 后跳转到 IdP
+//  2. GET /api/v1/auth/oidc/callback：校验 state，以授权码换取 ID Token，按 JWKS 校验签名、iss、aud、exp 与 nonce
+//  3. 按邮箱查找用户，不存在时即时创建（无密码，只能通过 SSO 登录）；按组映射角色
+//  4. 签发与密码登录相同的 access/refresh 令牌（Cookie），跳转回前端
+//
+// IdP 元数据（.well-known/openid-configuration）在首次登录时获取并缓存，ID Token 中出现未知 kid 时重新获取 JWKS。
+
+const (
+	oidcStateCookie = "oidc_state"
+	oidcStateTTL    = 10 * time.Minute
+
+	// jwksRefreshInterval 未知 kid 触发重新获取 JWKS 的最小间隔
+	jwksRefreshInterval = time.Minute
+)
+
+// OIDCConfig OIDC 单点登录配置（Okta、Azure AD、Google 等）
+type OIDCConfig struct {
+	Issuer        string   // IdP 的 issuer，元数据从 {issuer}/.well-known/openid-configuration 获取
+	ClientID      string   // 客户端 ID
+	ClientSecret  string   // 客户端密钥
+	RedirectURL   string   // 回调地址，如 https://admin.example.com/api/v1/auth/oidc/callback
+	Scopes        []string // 为空时使用 openid email profile
+	GroupsClaim   string   // ID Token 中组列表的声明名称，为空时使用 groups
+	AdminGroups   []string // 映射为 admin 角色的组；配置后每次登录按组同步角色
+	AllowedGroups []string // 允许登录的组（admin 组总是允许），为空时不限制
+}
+
+// Enabled 是否启用 OIDC 登录
+func (c OIDCConfig) Enabled() bool {
+	return c.Issuer != "" && c.ClientID != ""
+}
+
+// Role 按组映射角色，ok 为 false 表示不在允许登录的组中
+func (c OIDCConfig) Role(groups []string) (role model.UserRole, ok bool) {
+	admin := slices.ContainsFunc(groups, func(g string) bool { return slices.Contains(c.AdminGroups, g) })
+	if admin {
+		return model.UserRoleAdmin, true
+	}
+	if len(c.AllowedGroups) > 0 && !slices.ContainsFunc(groups, func(g string) bool { return slices.Contains(c.AllowedGroups, g) }) {
+		return "", false
+	}
+	return model.UserRoleUser, true
+}
+
+// OIDCIdentity 从 ID Token 中解析出的用户身份
+type OIDCIdentity struct {
+	Subject string
+	Email   string
+	Name    string
+	Groups  []string
+}
+
+// oidcMetadata IdP 元数据（只取用到的字段）
+type oidcMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// OIDCProvider OIDC IdP 客户端：元数据发现、授权跳转、授权码换取与 ID Token 校验
+type OIDCProvider struct {
+	cfg    OIDCConfig
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *oidcMetadata
+	keys     map[string]interface{} // kid → 公钥（*rsa.PublicKey / *ecdsa.PublicKey）
+	keysAt   time.Time
+}
+
+// NewOIDCProvider 创建 OIDC 客户端（元数据在首次使用时获取）
+func NewOIDCProvider(cfg OIDCConfig) *OIDCProvider {
+	if len(cfg.Scopes) == 0 {
+		cfg.Scopes = []string{"openid", "email", "profile"}
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return &OIDCProvider{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Config 返回生效的配置
+func (p *OIDCProvider) Config() OIDCConfig {
+	return p.cfg
+}
+
+// discover 获取并缓存 IdP 元数据
+func (p *OIDCProvider) discover(ctx context.Context) (*oidcMetadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+	var md oidcMetadata
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.cfg.Issuer, "/")+"/.well-known/openid-configuration", &md); err != nil {
+		return nil, fmt.Errorf("oidc discovery: %w", err)
+	}
+	if md.Issuer != p.cfg.Issuer {
+		return nil, fmt.Errorf("oidc discovery: issuer mismatch: got %q, want %q", md.Issuer, p.cfg.Issuer)
+	}
+	if md.AuthorizationEndpoint == "" || md.TokenEndpoint == "" || md.JWKSURI == "" {
+		return nil, fmt.Errorf("oidc discovery: incomplete provider metadata")
+	}
+	p.metadata = &md
+	return p.metadata, nil
+}
+
+// AuthCodeURL 返回 IdP 授权地址
+func (p *OIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, verifier string) (string, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+	challenge := sha256.Sum256([]byte(verifier))
+	q := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.cfg.ClientID},
+		"redirect_uri":          {p.cfg.RedirectURL},
+		"scope":                 {strings.Join(p.cfg.Scopes, " ")},
+		"state":                 {state},
+		"nonce":                 {nonce},
+		"code_challenge":        {base64.RawURLEncoding.EncodeToString(challenge[:])},
+		"code_challenge_method": {"S256"},
+	}
+	sep := "?"
+	if strings.Contains(md.AuthorizationEndpoint, "?") {
+		sep = "&"
+	}
+	return md.AuthorizationEndpoint + sep + q.Encode(), nil
+}
+
+// Exchange 以授权码换取 ID Token 并校验，返回用户身份
+func (p *OIDCProvider) Exchange(ctx context.Context, code, verifier, nonce string) (*OIDCIdentity, error) {
+	md, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.cfg.RedirectURL},
+		"code_verifier": {verifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, md.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oidc token exchange: %w", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("oidc token exchange: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var token struct {
+		IDToken string `json:"id_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil || token.IDToken == "" {
+		return nil, fmt.Errorf("oidc token exchange: response has no id_token")
+	}
+	return p.verifyIDToken(ctx, md, token.IDToken, nonce)
+}
+
+// verifyIDToken 校验 ID Token 的签名、iss、aud、exp 与 nonce
+func (p *OIDCProvider) verifyIDToken(ctx context.Context, md *oidcMetadata, raw, nonce string) (*OIDCIdentity, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return p.key(ctx, md, kid)
+	},
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}),
+		jwt.WithIssuer(md.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+	if got, _ := claims["nonce"].(string); got != nonce {
+		return nil, fmt.Errorf("invalid id_token: nonce mismatch")
+	}
+	if verified, ok := claims["email_verified"].(bool); ok && !verified {
+		return nil, fmt.Errorf("email not verified")
+	}
+
+	id := &OIDCIdentity{}
+	id.Subject, _ = claims["sub"].(string)
+	id.Email, _ = claims["email"].(string)
+	id.Name, _ = claims["name"].(string)
+	if id.Name == "" {
+		id.Name, _ = claims["preferred_username"].(string)
+	}
+	switch groups := claims[p.cfg.GroupsClaim].(type) {
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok {
+				id.Groups = append(id.Groups, s)
+			}
+		}
+	case string:
+		id.Groups = []string{groups}
+	}
+	if id.Email == "" {
+		return nil, fmt.Errorf("id_token has no email claim")
+	}
+	return id, nil
+}
+
+// key 按 kid 返回 IdP 公钥，未知 kid 时重新获取 JWKS（至多每分钟一次）
+func (p *OIDCProvider) key(ctx context.Context, md *oidcMetadata, kid string) (interface{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	if time.Since(p.keysAt) < jwksRefreshInterval {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := p.getJSON(ctx, md.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("fetch jwks: %w", err)
+	}
+	p.keys = make(map[string]interface{}, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		pub, err := k.publicKey()
+		if err != nil {
+			slog.WarnContext(ctx, "auth.oidc.jwks.skip", "kid", k.Kid, "error", err)
+			continue
+		}
+		p.keys[k.Kid] = pub
+	}
+	p.keysAt = time.Now()
+	if k, ok := p.lookupKey(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("unknown signing key %q", kid)
+}
+
+// lookupKey 查找公钥（ID Token 未携带 kid 且 JWKS 只有一个密钥时使用该密钥）
+func (p *OIDCProvider) lookupKey(kid string) (interface{}, bool) {
+	if k, ok := p.keys[kid]; ok {
+		return k, true
+	}
+	if kid == "" && len(p.keys) == 1 {
+		for _, k := range p.keys {
+			return k, true
+		}
+	}
+	return nil, false
+}
+
+func (p *OIDCProvider) getJSON(ctx context.Context, u string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: status %d", u, resp.StatusCode)
+	}
+	return json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(v)
+}
+
+// jsonWebKey JWKS 中的公钥（RSA 与 EC）
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid n: %w", err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil || len(e) == 0 {
+			return nil, fmt.Errorf("invalid e")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, errX := base64.RawURLEncoding.DecodeString(k.X)
+		y, errY := base64.RawURLEncoding.DecodeString(k.Y)
+		if errX != nil || errY != nil {
+			return nil, fmt.Errorf("invalid ec point")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// ============================================================================
+// Handlers
+// ============================================================================
+
+// oidcStateClaims oidc_state Cookie 中签名保存的登录状态
+type oidcStateClaims struct {
+	jwt.RegisteredClaims
+	Type     string `json:"type"` // "oidc_state"
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Redirect string `json:"redirect,omitempty"`
+}
+
+// OIDCLogin 跳转到 IdP 登录
+//
+// 可选查询参数 redirect：登录完成后跳转的站内路径，默认 /
+func (h *Handler) OIDCLogin(w http.ResponseWriter, r *http.Request) {
+	redirect := r.URL.Query().Get("redirect")
+	if !strings.HasPrefix(redirect, "/") || strings.HasPrefix(redirect, "//") || strings.HasPrefix(redirect, "/\\") {
+		redirect = "/"
+	}
+	state, nonce, verifier := randomToken(), randomToken(), randomToken()
+	authURL, err := h.oidc.AuthCodeURL(r.Context(), state, nonce, verifier)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.oidc", "op", "AuthCodeURL", "error", err)
+		writeError(w, http.StatusBadGateway, "identity provider unavailable")
+		return
+	}
+
+	claims := oidcStateClaims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(oidcStateTTL))},
+		Type:             "oidc_state",
+		State:            state,
+		Nonce:            nonce,
+		Verifier:         verifier,
+		Redirect:         redirect,
+	}
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(h.cfg.JWTSecret))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcStateCookie,
+		Value:    signed,
+		Path:     "/api/v1/auth/oidc",
+		MaxAge:   int(oidcStateTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// OIDCCallback IdP 登录回调：校验身份、即时创建用户并签发令牌
+func (h *Handler) OIDCCallback(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if e := q.Get("error"); e != "" {
+		slog.WarnContext(r.Context(), "auth.oidc.callback.idp_error", "error", e, "description", q.Get("error_description"))
+		writeError(w, http.StatusUnauthorized, "oidc login failed: "+e)
+		return
+	}
+	state, err := h.parseOIDCState(r)
+	if err != nil || q.Get("state") == "" || q.Get("state") != state.State {
+		writeError(w, http.StatusBadRequest, "invalid oidc state")
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcStateCookie, Path: "/api/v1/auth/oidc", MaxAge: -1, HttpOnly: true, Secure: true})
+
+	identity, err := h.oidc.Exchange(r.Context(), q.Get("code"), state.Verifier, state.Nonce)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.oidc", "op", "Exchange", "error", err)
+		writeError(w, http.StatusUnauthorized, "oidc login failed")
+		return
+	}
+	role, ok := h.oidc.Config().Role(identity.Groups)
+	if !ok {
+		slog.WarnContext(r.Context(), "auth.oidc.callback.denied", "email", identity.Email, "reason", "group")
+		writeError(w, http.StatusForbidden, "not a member of an allowed group")
+		return
+	}
+
+	user, err := h.provisionOIDCUser(r.Context(), identity, role)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.oidc.provision.failed", "email", identity.Email, "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if user.Status == model.UserStatusDisabled {
+		writeError(w, http.StatusForbidden, "account is disabled")
+		return
+	}
+
+	if _, err := h.startSession(w, r, user, sessionMethodOIDC); err != nil {
+		slog.ErrorContext(r.Context(), "auth.oidc", "op", "startSession", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "auth.login.success", "user_id", user.ID, "email", user.Email, "role", user.Role, "method", "oidc")
+	http.Redirect(w, r, state.Redirect, http.StatusFound)
+}
+
+// parseOIDCState 解析并校验 oidc_state Cookie
+func (h *Handler) parseOIDCState(r *http.Request) (*oidcStateClaims, error) {
+	c, err := r.Cookie(oidcStateCookie)
+	if err != nil {
+		return nil, err
+	}
+	claims := &oidcStateClaims{}
+	_, err = jwt.ParseWithClaims(c.Value, claims, func(t *jwt.Token) (interface{}, error) {
+		return []byte(h.cfg.JWTSecret), nil
+	}, jwt.WithValidMethods([]string{"HS256"}), jwt.WithExpirationRequired())
+	if err != nil {
+		return nil, err
+	}
+	if claims.Type != "oidc_state" {
+		return nil, fmt.Errorf("invalid token type")
+	}
+	return claims, nil
+}
+
+// provisionOIDCUser 按邮箱查找用户，不存在时即时创建；配置了 admin 组时同步角色
+func (h *Handler) provisionOIDCUser(ctx context.Context, id *OIDCIdentity, role model.UserRole) (*model.User, error) {
+	user, err := h.store.GetUserByEmail(ctx, id.Email)
+	if err != nil {
+		return nil, err
+	}
+	if user != nil {
+		if len(h.oidc.Config().AdminGroups) > 0 && user.Role != role {
+			if err := h.store.UpdateUserRole(ctx, user.ID, role); err != nil {
+				return nil, err
+			}
+			slog.InfoContext(ctx, "auth.oidc.role.changed", "user_id", user.ID, "email", user.Email, "from", user.Role, "to", role)
+			user.Role = role
+		}
+		return user, nil
+	}
+
+	username := id.Name
+	if username == "" {
+		username, _, _ = strings.Cut(id.Email, "@")
+	}
+	now := time.Now()
+	user = &model.User{
+		ID:        generateID(),
+		Email:     id.Email,
+		Username:  username,
+		Role:      role,
+		Status:    model.UserStatusActive,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := h.store.CreateUser(ctx, user); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "auth.oidc.provisioned", "user_id", user.ID, "email", user.Email, "role", user.Role)
+	return user, nil
+}
+
+// randomToken 生成 URL 安全的随机串（state/nonce/PKCE code_verifier）
+func randomToken() string {
+	b := make([]byte, 32)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
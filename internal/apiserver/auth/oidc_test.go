@@ -0,0 +1,246 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// memUserStore 内存用户存储（两步验证与会话方法见 mfa_test.go）
+type memUserStore struct {
+	users    map[string]*model.User // email → user
+	mfa      map[string]*model.UserMFA
+	sessions map[string]*model.UserSession
+}
+
+func (m *memUserStore) CreateUser(_ context.Context, user *model.User) error {
+	m.users[user.Email] = user
+	return nil
+}
+
+func (m *memUserStore) GetUserByEmail(_ context.Context, email string) (*model.User, error) {
+	return m.users[email], nil
+}
+
+func (m *memUserStore) GetUserByID(_ context.Context, id string) (*model.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return u, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memUserStore) UpdateUserPassword(_ context.Context, _, _ string) error { return nil }
+
+func (m *memUserStore) UpdateUserRole(_ context.Context, id string, role model.UserRole) error {
+	for _, u := range m.users {
+		if u.ID == id {
+			u.Role = role
+		}
+	}
+	return nil
+}
+
+func (m *memUserStore) ListUsers(_ context.Context) ([]*model.User, error) { return nil, nil }
+
+// fakeIdP 测试用 OIDC IdP：授权码对应预置的 ID Token 声明
+type fakeIdP struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	claims map[string]jwt.MapClaims // code → 声明（nonce 在换取时从授权请求中补齐）
+	nonces map[string]string        // code → nonce
+}
+
+func newFakeIdP(t *testing.T) *fakeIdP {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	idp := &fakeIdP{key: key, claims: map[string]jwt.MapClaims{}, nonces: map[string]string{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 idp.URL,
+			"authorization_endpoint": idp.URL + "/authorize",
+			"token_endpoint":         idp.URL + "/token",
+			"jwks_uri":               idp.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{"keys": []map[string]string{{
+			"kty": "RSA", "kid": "k1", "use": "sig",
+			"n": base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			"e": base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "client-1" || secret != "s3cret" {
+			http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+			return
+		}
+		code := r.FormValue("code")
+		claims, ok := idp.claims[code]
+		if !ok || r.FormValue("code_verifier") == "" {
+			http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+			return
+		}
+		claims["iss"] = idp.URL
+		claims["aud"] = "client-1"
+		claims["exp"] = time.Now().Add(time.Hour).Unix()
+		claims["nonce"] = idp.nonces[code]
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = "k1"
+		signed, _ := token.SignedString(key)
+		json.NewEncoder(w).Encode(map[string]string{"id_token": signed, "access_token": "at"})
+	})
+	idp.Server = httptest.NewServer(mux)
+	t.Cleanup(idp.Close)
+	return idp
+}
+
+// login 走完整的登录流程，返回回调响应
+func (idp *fakeIdP) login(t *testing.T, mux *http.ServeMux, claims jwt.MapClaims) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login?redirect=/tasks", nil))
+	if w.Code != http.StatusFound {
+		t.Fatalf("login status = %d, body = %s", w.Code, w.Body.String())
+	}
+	authURL, _ := url.Parse(w.Header().Get("Location"))
+	q := authURL.Query()
+	if !strings.HasPrefix(authURL.String(), idp.URL+"/authorize") || q.Get("code_challenge_method") != "S256" || q.Get("client_id") != "client-1" {
+		t.Fatalf("unexpected authorize url: %s", authURL)
+	}
+	stateCookie := w.Result().Cookies()[0]
+
+	code := "code-" + q.Get("state")[:8]
+	idp.claims[code] = claims
+	idp.nonces[code] = q.Get("nonce")
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code="+code+"&state="+q.Get("state"), nil)
+	req.AddCookie(stateCookie)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func newOIDCTestHandler(t *testing.T, idp *fakeIdP, store *memUserStore) *http.ServeMux {
+	cfg := DefaultConfig()
+	cfg.JWTSecret = "test-secret-test-secret-test-secret"
+	cfg.OIDC = OIDCConfig{
+		Issuer:        idp.URL,
+		ClientID:      "client-1",
+		ClientSecret:  "s3cret",
+		RedirectURL:   "https://admin.example.com/api/v1/auth/oidc/callback",
+		AdminGroups:   []string{"platform-admins"},
+		AllowedGroups: []string{"engineering"},
+	}
+	mux := http.NewServeMux()
+	NewHandler(store, cfg).RegisterRoutes(mux)
+	return mux
+}
+
+func TestOIDCLogin_ProvisionsAndMapsRoles(t *testing.T) {
+	idp := newFakeIdP(t)
+	store := &memUserStore{users: map[string]*model.User{}}
+	mux := newOIDCTestHandler(t, idp, store)
+
+	// 首次登录即时创建用户
+	w := idp.login(t, mux, jwt.MapClaims{"sub": "u1", "email": "alice@example.com", "name": "Alice", "groups": []string{"engineering"}})
+	if w.Code != http.StatusFound || w.Header().Get("Location") != "/tasks" {
+		t.Fatalf("callback status = %d, location = %q, body = %s", w.Code, w.Header().Get("Location"), w.Body.String())
+	}
+	user := store.users["alice@example.com"]
+	if user == nil || user.Role != model.UserRoleUser || user.Username != "Alice" || user.PasswordHash != "" {
+		t.Fatalf("unexpected provisioned user: %+v", user)
+	}
+	var access string
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "access_token" {
+			access = c.Value
+		}
+	}
+	claims, err := ParseToken(Config{JWTSecret: "test-secret-test-secret-test-secret"}, access)
+	if err != nil || claims.Subject != user.ID || claims.Role != "user" {
+		t.Fatalf("unexpected access token: %+v, %v", claims, err)
+	}
+
+	// 加入 admin 组后再次登录，角色同步为 admin
+	w = idp.login(t, mux, jwt.MapClaims{"sub": "u1", "email": "alice@example.com", "groups": []string{"engineering", "platform-admins"}})
+	if w.Code != http.StatusFound || store.users["alice@example.com"].Role != model.UserRoleAdmin || len(store.users) != 1 {
+		t.Fatalf("role not synced: status = %d, user = %+v", w.Code, store.users["alice@example.com"])
+	}
+
+	// 不在允许登录的组中
+	w = idp.login(t, mux, jwt.MapClaims{"sub": "u2", "email": "bob@example.com", "groups": []string{"sales"}})
+	if w.Code != http.StatusForbidden || store.users["bob@example.com"] != nil {
+		t.Errorf("expected 403 for user outside allowed groups, got %d", w.Code)
+	}
+
+	// 邮箱未验证
+	w = idp.login(t, mux, jwt.MapClaims{"sub": "u3", "email": "eve@example.com", "email_verified": false, "groups": []string{"engineering"}})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 for unverified email, got %d", w.Code)
+	}
+}
+
+func TestOIDCCallback_RejectsBadState(t *testing.T) {
+	idp := newFakeIdP(t)
+	mux := newOIDCTestHandler(t, idp, &memUserStore{users: map[string]*model.User{}})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/login?redirect=//evil.example.com", nil))
+	stateCookie := w.Result().Cookies()[0]
+
+	// state 与 Cookie 不一致
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=x&state=forged", nil)
+	req.AddCookie(stateCookie)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("forged state: expected 400, got %d", w.Code)
+	}
+
+	// 没有 Cookie
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/auth/oidc/callback?code=x&state=forged", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("missing cookie: expected 400, got %d", w.Code)
+	}
+
+	// 站外跳转地址被替换为 /
+	state, err := (&Handler{cfg: Config{JWTSecret: "test-secret-test-secret-test-secret"}}).parseOIDCState(req)
+	if err != nil || state.Redirect != "/" {
+		t.Errorf("redirect = %+v, err = %v", state, err)
+	}
+}
+
+func TestOIDCConfig_Role(t *testing.T) {
+	cfg := OIDCConfig{AdminGroups: []string{"admins"}}
+	if role, ok := cfg.Role(nil); !ok || role != model.UserRoleUser {
+		t.Errorf("no groups: role = %s, ok = %v", role, ok)
+	}
+	if role, ok := cfg.Role([]string{"admins"}); !ok || role != model.UserRoleAdmin {
+		t.Errorf("admin group: role = %s, ok = %v", role, ok)
+	}
+	cfg.AllowedGroups = []string{"eng"}
+	if _, ok := cfg.Role([]string{"sales"}); ok {
+		t.Error("group outside allowed_groups should be rejected")
+	}
+	if _, ok := cfg.Role([]string{"admins"}); !ok {
+		t.Error("admin group should always be allowed")
+	}
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ============================================================================
+// 登录会话
+// ============================================================================
+//
+// 每次登录（密码、密码 + 两步验证、OIDC、注册）创建一个会话，访问令牌与刷新令牌携带会话 ID（sid）。
+// 撤销会话后刷新令牌立即失效；认证中间件经 VerifySession 校验访问令牌的会话，
+// 本实例撤销的会话立即失效，其他实例在缓存过期（sessionCacheTTL）后失效。
+
+// 会话的登录方式
+const (
+	sessionMethodPassword    = "password"
+	sessionMethodPasswordMFA = "password+2fa"
+	sessionMethodOIDC        = "oidc"
+	sessionMethodRegister    = "register"
+)
+
+// maxUserAgentLength 会话记录的 User-Agent 最大长度
+const maxUserAgentLength = 512
+
+// sessionCacheTTL 会话校验结果的缓存时间（多实例部署时撤销的最长生效延迟）
+const sessionCacheTTL = 30 * time.Second
+
+// sessionCache 会话校验结果缓存，避免每个 API 请求都查询存储
+type sessionCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedSession
+}
+
+type cachedSession struct {
+	userID  string
+	active  bool
+	expires time.Time
+}
+
+func newSessionCache() *sessionCache {
+	return &sessionCache{entries: make(map[string]cachedSession)}
+}
+
+func (c *sessionCache) lookup(id string, now time.Time) (bool, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[id]
+	if !ok || now.After(e.expires) {
+		return false, false
+	}
+	return e.active, true
+}
+
+func (c *sessionCache) store(id string, e cachedSession, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 过期条目在写入时顺带清理，缓存大小受近期活动的会话数限制
+	for k, old := range c.entries {
+		if now.After(old.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[id] = e
+}
+
+// forget 撤销会话时清除缓存：指定 id 时只清除该会话，否则清除该用户的全部会话
+func (c *sessionCache) forget(userID, id string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if id != "" {
+		delete(c.entries, id)
+		return
+	}
+	for k, e := range c.entries {
+		if e.userID == userID {
+			delete(c.entries, k)
+		}
+	}
+}
+
+// VerifySession 校验会话仍有效（实现 SessionVerifier），存储错误时拒绝
+func (h *Handler) VerifySession(ctx context.Context, sessionID string) bool {
+	now := time.Now()
+	if active, ok := h.sessions.lookup(sessionID, now); ok {
+		return active
+	}
+	session, err := h.store.GetUserSession(ctx, sessionID)
+	if err != nil {
+		slog.ErrorContext(ctx, "auth.session.verify.failed", "session", sessionID, "error", err)
+		return false
+	}
+	entry := cachedSession{expires: now.Add(sessionCacheTTL)}
+	if session != nil {
+		entry.userID = session.UserID
+		entry.active = session.Active(now)
+	}
+	h.sessions.store(sessionID, entry, now)
+	return entry.active
+}
+
+// sessionView 会话列表项
+type sessionView struct {
+	*model.UserSession
+	Current bool `json:"current"` // 是否为发起请求的会话
+}
+
+// startSession 创建登录会话并签发令牌（同时写入 Cookie）
+func (h *Handler) startSession(w http.ResponseWriter, r *http.Request, user *model.User, method string) (*authResponse, error) {
+	now := time.Now()
+	cfg := h.tokenConfig()
+	ttl := cfg.RefreshTokenTTL
+	if ttl == 0 {
+		ttl = 7 * 24 * time.Hour
+	}
+	userAgent := r.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	session := &model.UserSession{
+		ID:         "ses-" + randomToken(),
+		UserID:     user.ID,
+		Method:     method,
+		UserAgent:  userAgent,
+		IP:         RemoteHost(r.RemoteAddr),
+		CreatedAt:  now,
+		LastUsedAt: now,
+		ExpiresAt:  now.Add(ttl),
+	}
+	if err := h.store.CreateUserSession(r.Context(), session); err != nil {
+		return nil, err
+	}
+
+	accessToken, err := GenerateAccessToken(cfg, user.ID, user.Email, string(user.Role), session.ID)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := GenerateRefreshToken(cfg, user.ID, session.ID)
+	if err != nil {
+		return nil, err
+	}
+	setAccessTokenCookie(w, accessToken, cfg.AccessTokenTTL)
+	setRefreshTokenCookie(w, refreshToken, cfg.RefreshTokenTTL)
+	return &authResponse{User: user, AccessToken: accessToken, RefreshToken: refreshToken}, nil
+}
+
+// sessionOwner 会话接口操作的用户：默认当前用户，管理员可通过 user_id 查询参数指定其他用户
+func sessionOwner(w http.ResponseWriter, r *http.Request) (*AuthUser, string, bool) {
+	authUser := GetAuthUser(r.Context())
+	if authUser == nil {
+		writeError(w, http.StatusUnauthorized, "not authenticated")
+		return nil, "", false
+	}
+	userID := r.URL.Query().Get("user_id")
+	if userID == "" || userID == authUser.ID {
+		return authUser, authUser.ID, true
+	}
+	if authUser.Role != UserRoleAdmin {
+		writeError(w, http.StatusForbidden, "admin access required")
+		return nil, "", false
+	}
+	return authUser, userID, true
+}
+
+// ListSessions 列出活动会话（未撤销且未过期）
+func (h *Handler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	authUser, userID, ok := sessionOwner(w, r)
+	if !ok {
+		return
+	}
+	sessions, err := h.store.ListUserSessions(r.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.sessions", "op", "ListUserSessions", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	views := make([]sessionView, 0, len(sessions))
+	for _, s := range sessions {
+		views = append(views, sessionView{UserSession: s, Current: s.ID == authUser.SessionID})
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": views, "count": len(views)})
+}
+
+// RevokeSession 撤销单个会话
+func (h *Handler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	_, userID, ok := sessionOwner(w, r)
+	if !ok {
+		return
+	}
+	revoked, err := h.store.RevokeUserSession(r.Context(), userID, r.PathValue("id"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.sessions", "op", "RevokeUserSession", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	if !revoked {
+		writeError(w, http.StatusNotFound, "session not found")
+		return
+	}
+	h.sessions.forget(userID, r.PathValue("id"))
+	slog.InfoContext(r.Context(), "auth", "detail", "Session revoked", "user", userID, "session", r.PathValue("id"))
+	writeJSON(w, http.StatusOK, map[string]string{"message": "session revoked"})
+}
+
+// RevokeSessions 撤销全部会话
+//
+// 撤销自己的会话时默认保留当前会话，include_current=true 时一并撤销；管理员指定 user_id 时撤销该用户的全部会话。
+func (h *Handler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	authUser, userID, ok := sessionOwner(w, r)
+	if !ok {
+		return
+	}
+	except := ""
+	if userID == authUser.ID && r.URL.Query().Get("include_current") != "true" {
+		except = authUser.SessionID
+	}
+	n, err := h.store.RevokeUserSessions(r.Context(), userID, except)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "auth.sessions", "op", "RevokeUserSessions", "error", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+		return
+	}
+	h.sessions.forget(userID, "")
+	slog.InfoContext(r.Context(), "auth", "detail", "Sessions revoked", "user", userID, "count", n, "by", authUser.ID)
+	writeJSON(w, http.StatusOK, map[string]int64{"revoked": n})
+}
+
+// Logout 撤销当前会话并清除令牌 Cookie
+func (h *Handler) Logout(w http.ResponseWriter, r *http.Request) {
+	if authUser := GetAuthUser(r.Context()); authUser != nil && authUser.SessionID != "" {
+		if _, err := h.store.RevokeUserSession(r.Context(), authUser.ID, authUser.SessionID); err != nil {
+			slog.ErrorContext(r.Context(), "auth.logout", "op", "RevokeUserSession", "error", err)
+			writeError(w, http.StatusInternalServerError, "internal error")
+			return
+		}
+		h.sessions.forget(authUser.ID, authUser.SessionID)
+	}
+	http.SetCookie(w, &http.Cookie{Name: "access_token", Path: "/", MaxAge: -1, HttpOnly: true, Secure: true})
+	http.SetCookie(w, &http.Cookie{Name: "refresh_token", Path: "/api/v1/auth", MaxAge: -1, HttpOnly: true, Secure: true})
+	writeJSON(w, http.StatusOK, map[string]string{"message": "logged out"})
+}
+
+// RemoteHost 客户端地址（不含端口）：HTTP 请求的 RemoteAddr 或 gRPC 对端地址
+func RemoteHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// TOTP（RFC 6238）与备用码
+// ============================================================================
+
+const (
+	totpPeriod = 30 // 时间步长（秒）
+	totpDigits = 6
+	totpSkew   = 1 // 允许前后各一个时间步的时钟偏差
+
+	totpIssuer      = "Agents Admin"
+	backupCodeCount = 10
+)
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// newTOTPSecret 生成 160 位 TOTP 密钥（Base32 编码，可直接录入验证器）
+func newTOTPSecret() string {
+	b := make([]byte, 20)
+	_, _ = rand.Read(b)
+	return totpEncoding.EncodeToString(b)
+}
+
+// totpURL 返回验证器扫码用的 otpauth:// 地址
+func totpURL(secret, account string) string {
+	q := url.Values{
+		"secret": {secret},
+		"issuer": {totpIssuer},
+		"digits": {fmt.Sprint(totpDigits)},
+		"period": {fmt.Sprint(totpPeriod)},
+	}
+	label := url.PathEscape(totpIssuer + ":" + account)
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// totpCode 计算时间步 step 的动态码
+func totpCode(secret string, step int64) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", fmt.Errorf("invalid totp secret: %w", err)
+	}
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+	offset := sum[len(sum)-1] & 0x0f
+	code := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, code%1000000), nil
+}
+
+// matchTOTP 校验动态码，返回匹配的时间步（不匹配时返回 0, false）
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	current := now.Unix() / totpPeriod
+	for step := current - totpSkew; step <= current+totpSkew; step++ {
+		want, err := totpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(want), []byte(code)) {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// isTOTPCode 是否为动态码格式（6 位数字），否则按备用码处理
+func isTOTPCode(code string) bool {
+	if len(code) != totpDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// newBackupCodes 生成一组备用码，返回明文（展示给用户）与 SHA-256（落库）
+func newBackupCodes() (codes, hashes []string) {
+	for range backupCodeCount {
+		b := make([]byte, 5)
+		_, _ = rand.Read(b)
+		c := strings.ToLower(totpEncoding.EncodeToString(b))
+		codes = append(codes, c[:4]+"-"+c[4:])
+		hashes = append(hashes, hashBackupCode(c))
+	}
+	return codes, hashes
+}
+
+// hashBackupCode 备用码的 SHA-256（忽略大小写、空格与连字符）
+func hashBackupCode(code string) string {
+	code = strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
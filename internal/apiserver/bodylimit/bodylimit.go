@@ -0,0 +1,155 @@
+// Package bodylimit 请求体大小限制
+//
+// 按路由组限制请求体大小，避免超大请求体（事件上报、上传接口）耗尽 API Server 内存：
+//   - 声明了 Content-Length 且超过上限的请求直接返回 413，不读取请求体
+//   - 未声明长度（chunked）的请求体以 http.MaxBytesReader 包装，读取超过上限时返回 *http.MaxBytesError，
+//     处理器通过 IsTooLarge 判断并调用 WriteTooLarge 返回 413
+//
+// 413 响应体：{"error": "request body too large", "group": "<路由组>", "limit_bytes": <上限>}
+package bodylimit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+)
+
+// 路由组
+const (
+	GroupDefault  = "default"  // 未匹配其他路由组的请求
+	GroupEvents   = "events"   // 事件与节点日志批量上报
+	GroupUploads  = "uploads"  // 产物、代码变更、上下文、技能包与任务导入
+	GroupArchives = "archives" // Volume 归档（流式转存到对象存储）
+)
+
+// routeGroups 路由组包含的路由（http.ServeMux 模式）
+var routeGroups = map[string][]string{
+	GroupEvents: {
+		"POST /api/v1/runs/{id}/events",
+		"POST /api/v1/runs/{id}/node-logs",
+	},
+	GroupUploads: {
+		"POST /api/v1/runs/{id}/artifacts",
+		"POST /api/v1/runs/{id}/diff",
+		"POST /api/v1/runs/{id}/context",
+		"PUT /api/v1/tasks/{id}/context",
+		"POST /api/v1/skills/{id}/bundles",
+		"POST /api/v1/tasks/import",
+	},
+	GroupArchives: {
+		"PUT /api/v1/accounts/{id}/volume-archive",
+	},
+}
+
+// DefaultLimits 各路由组的默认上限（字节）
+func DefaultLimits() map[string]int64 {
+	return map[string]int64{
+		GroupDefault:  2 << 20,
+		GroupEvents:   32 << 20,
+		GroupUploads:  16 << 20,
+		GroupArchives: 1 << 30,
+	}
+}
+
+// Limiter 请求体大小限制
+type Limiter struct {
+	limits map[string]int64 // 路由组 → 上限（字节，<= 0 不限制）
+	routes *http.ServeMux   // 仅用于匹配路由组
+}
+
+// groupHandler 路由组标记（不处理请求）
+type groupHandler string
+
+func (groupHandler) ServeHTTP(http.ResponseWriter, *http.Request) {}
+
+// New 创建请求体大小限制
+//
+// limits 覆盖 DefaultLimits 中对应路由组的上限（字节）：0 使用默认值，负数表示不限制；未知路由组返回错误。
+func New(limits map[string]int64) (*Limiter, error) {
+	l := &Limiter{limits: DefaultLimits(), routes: http.NewServeMux()}
+	for group, n := range limits {
+		if _, ok := l.limits[group]; !ok {
+			return nil, fmt.Errorf("unknown body limit group %q (valid: %v)", group, Groups())
+		}
+		if n != 0 {
+			l.limits[group] = n
+		}
+	}
+	for group, patterns := range routeGroups {
+		for _, p := range patterns {
+			l.routes.Handle(p, groupHandler(group))
+		}
+	}
+	return l, nil
+}
+
+// Groups 返回全部路由组名称
+func Groups() []string {
+	groups := []string{GroupDefault}
+	for g := range routeGroups {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups[1:])
+	return groups
+}
+
+// Limit 返回请求所属的路由组与上限
+func (l *Limiter) Limit(r *http.Request) (string, int64) {
+	group := GroupDefault
+	if h, pattern := l.routes.Handler(r); pattern != "" {
+		if g, ok := h.(groupHandler); ok {
+			group = string(g)
+		}
+	}
+	return group, l.limits[group]
+}
+
+// Middleware 请求体大小限制中间件（l 为 nil 时原样返回）
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		group, limit := l.Limit(r)
+		if limit > 0 && r.Body != nil && r.Body != http.NoBody {
+			if r.ContentLength > limit {
+				writeTooLarge(w, group, limit)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, limit)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// IsTooLarge 错误是否为读取请求体超过上限
+func IsTooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.As(err, &mbe)
+}
+
+// WriteTooLarge 写入 413 响应（err 为读取请求体时的 *http.MaxBytesError）
+func WriteTooLarge(w http.ResponseWriter, err error) {
+	var mbe *http.MaxBytesError
+	var limit int64
+	if errors.As(err, &mbe) {
+		limit = mbe.Limit
+	}
+	writeTooLarge(w, "", limit)
+}
+
+func writeTooLarge(w http.ResponseWriter, group string, limit int64) {
+	resp := map[string]interface{}{"error": "request body too large"}
+	if group != "" {
+		resp["group"] = group
+	}
+	if limit > 0 {
+		resp["limit_bytes"] = limit
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Connection", "close")
+	w.WriteHeader(http.StatusRequestEntityTooLarge)
+	json.NewEncoder(w).Encode(resp)
+}
@@ -0,0 +1,78 @@
+package bodylimit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestLimit_RouteGroups(t *testing.T) {
+	l, err := New(map[string]int64{GroupUploads: 1 << 20, GroupArchives: -1})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	cases := []struct {
+		method, path string
+		group        string
+		limit        int64
+	}{
+		{"POST", "/api/v1/runs/run-1/events", GroupEvents, 32 << 20},
+		{"POST", "/api/v1/runs/run-1/diff", GroupUploads, 1 << 20},
+		{"PUT", "/api/v1/tasks/task-1/context", GroupUploads, 1 << 20},
+		{"PUT", "/api/v1/accounts/acc-1/volume-archive", GroupArchives, -1},
+		{"GET", "/api/v1/runs/run-1/events", GroupDefault, 2 << 20},
+		{"POST", "/api/v1/tasks", GroupDefault, 2 << 20},
+	}
+	for _, c := range cases {
+		group, limit := l.Limit(httptest.NewRequest(c.method, c.path, nil))
+		if group != c.group || limit != c.limit {
+			t.Errorf("%s %s: got %s/%d, want %s/%d", c.method, c.path, group, limit, c.group, c.limit)
+		}
+	}
+
+	if _, err := New(map[string]int64{"artifacts": 1}); err == nil {
+		t.Error("expected error for unknown group")
+	}
+}
+
+func TestMiddleware_TooLarge(t *testing.T) {
+	l, _ := New(map[string]int64{GroupDefault: 8})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			if !IsTooLarge(err) {
+				t.Errorf("unexpected error: %v", err)
+			}
+			WriteTooLarge(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	serve := func(r *http.Request) (int, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		var body map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body
+	}
+
+	if code, _ := serve(httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader("12345678"))); code != http.StatusNoContent {
+		t.Fatalf("within limit: expected 204, got %d", code)
+	}
+
+	// 声明了 Content-Length：不读取请求体直接拒绝
+	code, body := serve(httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader("123456789")))
+	if code != http.StatusRequestEntityTooLarge || body["group"] != GroupDefault || body["limit_bytes"] != float64(8) {
+		t.Errorf("content-length: got %d %v", code, body)
+	}
+
+	// 未声明长度：读取超过上限时由处理器返回 413
+	r := httptest.NewRequest("POST", "/api/v1/tasks", io.MultiReader(strings.NewReader("12345"), strings.NewReader("67890")))
+	r.ContentLength = -1
+	code, body = serve(r)
+	if code != http.StatusRequestEntityTooLarge || body["limit_bytes"] != float64(8) {
+		t.Errorf("chunked: got %d %v", code, body)
+	}
+}
@@ -0,0 +1,239 @@
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存实现的预算存储
+type memStore struct {
+	budgets   map[string]*model.Budget
+	usages    []*model.RunUsage
+	instances map[string]*model.Instance
+	events    map[string][]*model.Event
+	updates   int
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		budgets:   map[string]*model.Budget{},
+		instances: map[string]*model.Instance{},
+		events:    map[string][]*model.Event{},
+	}
+}
+
+func (m *memStore) CreateBudget(_ context.Context, b *model.Budget) error {
+	copied := *b
+	m.budgets[b.ID] = &copied
+	return nil
+}
+
+func (m *memStore) GetBudget(_ context.Context, id string) (*model.Budget, error) {
+	b, ok := m.budgets[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *b
+	return &copied, nil
+}
+
+func (m *memStore) ListBudgets(_ context.Context) ([]*model.Budget, error) {
+	var out []*model.Budget
+	for _, b := range m.budgets {
+		copied := *b
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func (m *memStore) UpdateBudget(_ context.Context, b *model.Budget) error {
+	m.updates++
+	copied := *b
+	m.budgets[b.ID] = &copied
+	return nil
+}
+
+func (m *memStore) DeleteBudget(_ context.Context, id string) error {
+	delete(m.budgets, id)
+	return nil
+}
+
+func (m *memStore) ListRunUsage(_ context.Context, since, until string) ([]*model.RunUsage, error) {
+	var out []*model.RunUsage
+	for _, u := range m.usages {
+		if (since == "" || u.Day >= since) && (until == "" || u.Day <= until) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) GetAgentInstance(_ context.Context, id string) (*model.Instance, error) {
+	return m.instances[id], nil
+}
+
+func (m *memStore) CreateEvents(_ context.Context, events []*model.Event) error {
+	for _, e := range events {
+		m.events[e.RunID] = append(m.events[e.RunID], e)
+	}
+	return nil
+}
+
+func (m *memStore) AllocateEventSeqs(ctx context.Context, runID string, _ int) (int, error) {
+	seq, _, _ := m.MaxEventSeqs(ctx, runID)
+	return seq + 1, nil
+}
+
+func (m *memStore) MaxEventSeqs(_ context.Context, runID string) (int, int, error) {
+	var seq, nodeSeq int
+	for _, e := range m.events[runID] {
+		seq, nodeSeq = max(seq, e.Seq), max(nodeSeq, e.NodeSeq)
+	}
+	return seq, nodeSeq, nil
+}
+
+func (m *memStore) GetEventsByRun(_ context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	var out []*model.Event
+	for _, e := range m.events[runID] {
+		if e.Seq > fromSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// recordNotifier 记录发送的通知
+type recordNotifier struct{ events []*model.WebhookEvent }
+
+func (n *recordNotifier) Publish(_ context.Context, ev *model.WebhookEvent) {
+	n.events = append(n.events, ev)
+}
+
+func newEnforcer(store *memStore, now time.Time) (*Enforcer, *recordNotifier) {
+	e := NewEnforcer(store)
+	e.now = func() time.Time { return now }
+	n := &recordNotifier{}
+	e.SetNotifier(n)
+	return e, n
+}
+
+func projectTask(id string) *model.Task {
+	return &model.Task{ID: "task-1", ProjectID: &id}
+}
+
+func TestAdmit(t *testing.T) {
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	store := newMemStore()
+	store.budgets["b1"] = &model.Budget{ID: "b1", Scope: model.BudgetScopeProject, ScopeID: "proj-1", LimitUSD: 10, AlertThreshold: 0.8, Enabled: true}
+	store.usages = []*model.RunUsage{
+		{RunID: "r0", ProjectID: "proj-1", CostUSD: 50, Day: "2026-09-30"}, // 上月用量不计
+		{RunID: "r1", ProjectID: "proj-1", CostUSD: 8.5, Day: "2026-10-02"},
+		{RunID: "r2", ProjectID: "proj-2", CostUSD: 100, Day: "2026-10-03"},
+	}
+	e, n := newEnforcer(store, now)
+	ctx := context.Background()
+	run := &model.Run{ID: "run-1", TaskID: "task-1"}
+
+	assert.True(t, e.Admit(ctx, run, projectTask("proj-1")), "85% used is below the limit")
+	require.Len(t, n.events, 1)
+	assert.Equal(t, model.WebhookEventBudgetAlert, n.events[0].Type)
+	assert.Equal(t, "proj-1", n.events[0].ProjectID)
+	assert.Equal(t, "2026-10", store.budgets["b1"].AlertedPeriod)
+
+	assert.True(t, e.Admit(ctx, run, projectTask("proj-1")))
+	assert.Len(t, n.events, 1, "alert is sent once per period")
+	assert.True(t, e.Admit(ctx, run, projectTask("proj-3")), "other projects are not constrained")
+
+	store.usages = append(store.usages, &model.RunUsage{RunID: "r3", ProjectID: "proj-1", CostUSD: 2, Day: "2026-10-17"})
+	e.Invalidate()
+	assert.False(t, e.Admit(ctx, run, projectTask("proj-1")))
+	assert.False(t, e.Admit(ctx, run, projectTask("proj-1")))
+	require.Len(t, n.events, 2, "exceeded notification is sent once per period")
+	assert.Equal(t, model.WebhookEventBudgetExceeded, n.events[1].Type)
+	assert.Equal(t, "run-1", n.events[1].Data["run_id"])
+	assert.Equal(t, "2026-10", store.budgets["b1"].ExceededPeriod)
+
+	require.Len(t, store.events["run-1"], 1, "hold event is written once per run")
+	ev := store.events["run-1"][0]
+	assert.Equal(t, EventTypeExceeded, ev.Type)
+	assert.Equal(t, 1, ev.Seq)
+	var payload map[string]interface{}
+	require.NoError(t, json.Unmarshal(ev.Payload, &payload))
+	assert.Equal(t, "b1", payload["budget_id"])
+	assert.InDelta(t, 10.5, payload["cost_usd"], 1e-9)
+
+	other := &model.Run{ID: "run-2", TaskID: "task-2"}
+	assert.False(t, e.Admit(ctx, other, projectTask("proj-1")))
+	assert.Len(t, store.events["run-2"], 1)
+	assert.Len(t, n.events, 2)
+}
+
+func TestAdmit_AccountScope(t *testing.T) {
+	store := newMemStore()
+	store.budgets["b1"] = &model.Budget{ID: "b1", Scope: model.BudgetScopeAccount, ScopeID: "acc-1", LimitTokens: 1000, Enabled: true}
+	store.instances["inst-1"] = &model.Instance{ID: "inst-1", AccountID: "acc-1"}
+	store.usages = []*model.RunUsage{{RunID: "r1", AccountID: "acc-1", InputTokens: 900, OutputTokens: 100, Day: "2026-10-01"}}
+	e, _ := newEnforcer(store, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC))
+	ctx := context.Background()
+
+	viaInstance := &model.Run{ID: "run-1", Snapshot: json.RawMessage(`{"agent":{"instance_id":"inst-1"}}`)}
+	assert.False(t, e.Admit(ctx, viaInstance, nil))
+	otherAccount := &model.Run{ID: "run-2", Snapshot: json.RawMessage(`{"agent":{"account_id":"acc-2"}}`)}
+	assert.True(t, e.Admit(ctx, otherAccount, nil))
+
+	store.budgets["b1"].Enabled = false
+	assert.True(t, e.Admit(ctx, viaInstance, nil), "disabled budgets are ignored")
+}
+
+func TestHandler(t *testing.T) {
+	store := newMemStore()
+	store.usages = []*model.RunUsage{{RunID: "r1", ProjectID: "proj-1", CostUSD: 25, Day: time.Now().UTC().Format(model.UsageDayLayout)}}
+	mux := http.NewServeMux()
+	NewHandler(store, NewEnforcer(store)).RegisterRoutes(mux)
+	handler := auth.Middleware(auth.Config{})(mux) // 无认证模式（未配置 JWT 密钥）
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do(http.MethodPost, "/api/v1/budgets", `{"scope":"project","scope_id":"proj-1","limit_usd":100}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created budgetView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, "project:proj-1", created.Name)
+	assert.InDelta(t, model.DefaultBudgetAlertThreshold, created.AlertThreshold, 1e-9)
+	assert.True(t, created.Enabled)
+	assert.InDelta(t, 25.0, created.Spend.CostUSD, 1e-9)
+	assert.InDelta(t, 0.25, created.Usage, 1e-9)
+
+	assert.Equal(t, http.StatusConflict, do(http.MethodPost, "/api/v1/budgets", `{"scope":"project","scope_id":"proj-1","limit_usd":5}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/budgets", `{"scope":"team","scope_id":"x","limit_usd":5}`).Code)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPost, "/api/v1/budgets", `{"scope":"account","scope_id":"acc-1"}`).Code)
+
+	w = do(http.MethodPatch, "/api/v1/budgets/"+created.ID, `{"limit_usd":20}`)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var updated budgetView
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &updated))
+	assert.True(t, updated.Exhausted)
+	assert.Equal(t, http.StatusBadRequest, do(http.MethodPatch, "/api/v1/budgets/"+created.ID, `{"alert_threshold":1.5}`).Code)
+
+	w = do(http.MethodGet, "/api/v1/budgets", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"exhausted":true`)
+
+	assert.Equal(t, http.StatusNoContent, do(http.MethodDelete, "/api/v1/budgets/"+created.ID, "").Code)
+	assert.Equal(t, http.StatusNotFound, do(http.MethodGet, "/api/v1/budgets/"+created.ID, "").Code)
+}
@@ -0,0 +1,259 @@
+// Package budget 账号/项目月度预算
+//
+// 调度器分派 Run 前通过 Enforcer.Admit 检查 Run 所属账号与项目的预算：
+//   - 已用比例达到预警阈值时发送一次 budget.alert 通知
+//   - 预算耗尽时 Run 保持 queued，写入 budget_exceeded 事件并发送一次 budget.exceeded 通知
+//
+// 已用量取自 run_usage 中当月（UTC）的记录，短时间缓存以避免每次调度都全量读取。
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/apiserver/usage"
+	"agents-admin/internal/shared/model"
+)
+
+// EventTypeExceeded 预算耗尽时写入 Run 的事件类型
+const EventTypeExceeded = "budget_exceeded"
+
+// spendCacheTTL 已用量缓存时间
+const spendCacheTTL = 30 * time.Second
+
+// Store 预算需要的存储接口
+type Store interface {
+	CreateBudget(ctx context.Context, budget *model.Budget) error
+	GetBudget(ctx context.Context, id string) (*model.Budget, error)
+	ListBudgets(ctx context.Context) ([]*model.Budget, error)
+	UpdateBudget(ctx context.Context, budget *model.Budget) error
+	DeleteBudget(ctx context.Context, id string) error
+	ListRunUsage(ctx context.Context, since, until string) ([]*model.RunUsage, error)
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+	CreateEvents(ctx context.Context, events []*model.Event) error
+	AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error)
+	MaxEventSeqs(ctx context.Context, runID string) (seq, nodeSeq int, err error)
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+}
+
+// Notifier 预算通知发送（由 Webhook 分发器实现）
+type Notifier interface {
+	Publish(ctx context.Context, ev *model.WebhookEvent)
+}
+
+// Enforcer 预算检查器
+type Enforcer struct {
+	store    Store
+	notifier Notifier
+	now      func() time.Time
+
+	// mu 保护已用量缓存，并串行化通知，避免同一周期重复通知
+	mu       sync.Mutex
+	usages   []*model.RunUsage
+	period   string
+	loadedAt time.Time
+}
+
+// NewEnforcer 创建预算检查器
+func NewEnforcer(store Store) *Enforcer {
+	return &Enforcer{store: store, now: time.Now}
+}
+
+// SetNotifier 设置预算通知发送（未设置时只记录日志与 Run 事件）
+func (e *Enforcer) SetNotifier(n Notifier) {
+	e.notifier = n
+}
+
+// Admit 判断 Run 是否可以分派（实现 scheduler.BudgetGate）
+//
+// 读取预算或用量失败时放行，预算检查不应阻塞调度。
+func (e *Enforcer) Admit(ctx context.Context, run *model.Run, task *model.Task) bool {
+	budgets, err := e.store.ListBudgets(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "budget.list.failed", "run_id", run.ID, "error", err)
+		return true
+	}
+	if len(budgets) == 0 {
+		return true
+	}
+
+	var projectID string
+	if task != nil && task.ProjectID != nil {
+		projectID = *task.ProjectID
+	}
+	_, accountID := usage.RunAgent(ctx, e.store, run)
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, b := range budgets {
+		if !b.Enabled || !applies(b, accountID, projectID) {
+			continue
+		}
+		spend, err := e.spendLocked(ctx, b)
+		if err != nil {
+			slog.ErrorContext(ctx, "budget.spend.failed", "budget_id", b.ID, "error", err)
+			return true
+		}
+		e.alertLocked(ctx, b, spend)
+		if b.Exhausted(spend) {
+			e.holdLocked(ctx, b, spend, run)
+			return false
+		}
+	}
+	return true
+}
+
+// Spend 预算当月的已用量
+func (e *Enforcer) Spend(ctx context.Context, b *model.Budget) (model.BudgetSpend, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.spendLocked(ctx, b)
+}
+
+// Invalidate 清除已用量缓存（预算变更后调用）
+func (e *Enforcer) Invalidate() {
+	e.mu.Lock()
+	e.usages, e.period = nil, ""
+	e.mu.Unlock()
+}
+
+// applies 判断预算是否约束该 Run
+func applies(b *model.Budget, accountID, projectID string) bool {
+	switch b.Scope {
+	case model.BudgetScopeAccount:
+		return accountID != "" && b.ScopeID == accountID
+	case model.BudgetScopeProject:
+		return projectID != "" && b.ScopeID == projectID
+	}
+	return false
+}
+
+// spendLocked 汇总当月属于预算范围的用量（调用方持有 mu）
+func (e *Enforcer) spendLocked(ctx context.Context, b *model.Budget) (model.BudgetSpend, error) {
+	now := e.now().UTC()
+	period := model.BudgetPeriod(now)
+	if e.period != period || now.Sub(e.loadedAt) > spendCacheTTL {
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		usages, err := e.store.ListRunUsage(ctx, monthStart.Format(model.UsageDayLayout), "")
+		if err != nil {
+			return model.BudgetSpend{}, err
+		}
+		e.usages, e.period, e.loadedAt = usages, period, now
+	}
+
+	spend := model.BudgetSpend{Period: period}
+	for _, u := range e.usages {
+		if (b.Scope == model.BudgetScopeAccount && u.AccountID != b.ScopeID) ||
+			(b.Scope == model.BudgetScopeProject && u.ProjectID != b.ScopeID) {
+			continue
+		}
+		spend.Runs++
+		spend.Tokens += u.TotalTokens()
+		spend.CostUSD += u.CostUSD
+	}
+	return spend, nil
+}
+
+// alertLocked 已用比例达到预警阈值时发送一次预警
+func (e *Enforcer) alertLocked(ctx context.Context, b *model.Budget, spend model.BudgetSpend) {
+	threshold := b.AlertThreshold
+	if threshold <= 0 || b.AlertedPeriod == spend.Period || b.Usage(spend) < threshold {
+		return
+	}
+	b.AlertedPeriod = spend.Period
+	if err := e.store.UpdateBudget(ctx, b); err != nil {
+		slog.ErrorContext(ctx, "budget.alert.save_failed", "budget_id", b.ID, "error", err)
+	}
+	slog.InfoContext(ctx, "budget.alert", "budget_id", b.ID, "scope", b.Scope, "scope_id", b.ScopeID, "period", spend.Period, "usage", b.Usage(spend))
+	e.notify(ctx, model.WebhookEventBudgetAlert, b, spend, nil)
+}
+
+// holdLocked 记录预算耗尽：每个周期通知一次，每个 Run 写入一次 budget_exceeded 事件
+func (e *Enforcer) holdLocked(ctx context.Context, b *model.Budget, spend model.BudgetSpend, run *model.Run) {
+	if b.ExceededPeriod != spend.Period {
+		b.ExceededPeriod = spend.Period
+		if err := e.store.UpdateBudget(ctx, b); err != nil {
+			slog.ErrorContext(ctx, "budget.exceeded.save_failed", "budget_id", b.ID, "error", err)
+		}
+		slog.InfoContext(ctx, "budget.exceeded", "budget_id", b.ID, "scope", b.Scope, "scope_id", b.ScopeID, "period", spend.Period, "run_id", run.ID)
+		e.notify(ctx, model.WebhookEventBudgetExceeded, b, spend, map[string]interface{}{"run_id": run.ID, "task_id": run.TaskID})
+	}
+
+	last, _, err := e.store.MaxEventSeqs(ctx, run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "budget.event.failed", "run_id", run.ID, "error", err)
+		return
+	}
+	if e.alreadyHeld(ctx, run.ID, last, b.ID, spend.Period) {
+		return
+	}
+	seq, err := e.store.AllocateEventSeqs(ctx, run.ID, 1)
+	if err != nil {
+		slog.ErrorContext(ctx, "budget.event.failed", "run_id", run.ID, "error", err)
+		return
+	}
+	raw, _ := json.Marshal(eventPayload(b, spend))
+	event := &model.Event{
+		RunID:     run.ID,
+		Seq:       seq,
+		Type:      EventTypeExceeded,
+		Timestamp: e.now(),
+		Payload:   raw,
+	}
+	if err := e.store.CreateEvents(ctx, []*model.Event{event}); err != nil {
+		slog.ErrorContext(ctx, "budget.event.failed", "run_id", run.ID, "error", err)
+	}
+}
+
+// alreadyHeld 判断 Run 的最新事件是否已是同一预算、同一周期的 budget_exceeded
+func (e *Enforcer) alreadyHeld(ctx context.Context, runID string, lastSeq int, budgetID, period string) bool {
+	if lastSeq == 0 {
+		return false
+	}
+	events, err := e.store.GetEventsByRun(ctx, runID, lastSeq-1, 1)
+	if err != nil || len(events) == 0 || events[0].Type != EventTypeExceeded {
+		return false
+	}
+	var last struct {
+		BudgetID string `json:"budget_id"`
+		Period   string `json:"period"`
+	}
+	if json.Unmarshal(events[0].Payload, &last) != nil {
+		return false
+	}
+	return last.BudgetID == budgetID && last.Period == period
+}
+
+// notify 发送预算通知
+func (e *Enforcer) notify(ctx context.Context, eventType string, b *model.Budget, spend model.BudgetSpend, extra map[string]interface{}) {
+	if e.notifier == nil {
+		return
+	}
+	data := eventPayload(b, spend)
+	for k, v := range extra {
+		data[k] = v
+	}
+	ev := &model.WebhookEvent{Type: eventType, Data: data}
+	if b.Scope == model.BudgetScopeProject {
+		ev.ProjectID = b.ScopeID
+	}
+	e.notifier.Publish(ctx, ev)
+}
+
+// eventPayload 预算事件与通知的公共字段
+func eventPayload(b *model.Budget, spend model.BudgetSpend) map[string]interface{} {
+	return map[string]interface{}{
+		"budget_id":    b.ID,
+		"budget_name":  b.Name,
+		"scope":        b.Scope,
+		"scope_id":     b.ScopeID,
+		"period":       spend.Period,
+		"tokens":       spend.Tokens,
+		"cost_usd":     spend.CostUSD,
+		"limit_tokens": b.LimitTokens,
+		"limit_usd":    b.LimitUSD,
+	}
+}
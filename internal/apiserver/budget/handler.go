@@ -0,0 +1,262 @@
+package budget
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// Handler 预算 HTTP 处理器
+type Handler struct {
+	store    Store
+	enforcer *Enforcer
+}
+
+// NewHandler 创建预算处理器
+func NewHandler(store Store, enforcer *Enforcer) *Handler {
+	return &Handler{store: store, enforcer: enforcer}
+}
+
+// RegisterRoutes 注册预算相关路由（修改仅限管理员）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/budgets", h.List)
+	mux.HandleFunc("POST /api/v1/budgets", auth.AdminOnly(h.Create))
+	mux.HandleFunc("GET /api/v1/budgets/{id}", h.Get)
+	mux.HandleFunc("PATCH /api/v1/budgets/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/budgets/{id}", auth.AdminOnly(h.Delete))
+}
+
+// CreateRequest 创建预算请求
+type CreateRequest struct {
+	Name           string            `json:"name"`
+	Scope          model.BudgetScope `json:"scope"`
+	ScopeID        string            `json:"scope_id"`
+	LimitTokens    int64             `json:"limit_tokens,omitempty"`
+	LimitUSD       float64           `json:"limit_usd,omitempty"`
+	AlertThreshold *float64          `json:"alert_threshold,omitempty"` // 默认 0.8，0 表示不预警
+	Enabled        *bool             `json:"enabled,omitempty"`         // 默认启用
+}
+
+// UpdateRequest 更新预算请求（未提供的字段保持不变，作用范围不可修改）
+type UpdateRequest struct {
+	Name           *string  `json:"name,omitempty"`
+	LimitTokens    *int64   `json:"limit_tokens,omitempty"`
+	LimitUSD       *float64 `json:"limit_usd,omitempty"`
+	AlertThreshold *float64 `json:"alert_threshold,omitempty"`
+	Enabled        *bool    `json:"enabled,omitempty"`
+}
+
+// budgetView 预算及当月已用量
+type budgetView struct {
+	*model.Budget
+	Spend     model.BudgetSpend `json:"spend"`
+	Usage     float64           `json:"usage"`
+	Exhausted bool              `json:"exhausted"`
+}
+
+func (h *Handler) view(r *http.Request, b *model.Budget) budgetView {
+	spend, err := h.enforcer.Spend(r.Context(), b)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "budget.spend.failed", "budget_id", b.ID, "error", err)
+	}
+	return budgetView{Budget: b, Spend: spend, Usage: b.Usage(spend), Exhausted: b.Exhausted(spend)}
+}
+
+// List 列出预算及当月已用量
+// GET /api/v1/budgets
+//
+// 响应: {"budgets": [{"id": "budget-...", "scope": "project", "scope_id": "proj-1", "limit_usd": 100, "spend": {"period": "2026-10", "tokens": ..., "cost_usd": 42.5, "runs": 12}, "usage": 0.425, "exhausted": false}]}
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	budgets, err := h.store.ListBudgets(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list budgets")
+		return
+	}
+	views := make([]budgetView, 0, len(budgets))
+	for _, b := range budgets {
+		views = append(views, h.view(r, b))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"budgets": views})
+}
+
+// Create 创建预算
+// POST /api/v1/budgets
+//
+// 请求体: {"name": "team-a", "scope": "project", "scope_id": "proj-1", "limit_usd": 100, "alert_threshold": 0.8}
+// 响应: 201 + 预算；参数无效时 400，同一作用范围已有预算时 409
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	now := time.Now()
+	b := &model.Budget{
+		ID:             generateID("budget"),
+		Name:           req.Name,
+		Scope:          req.Scope,
+		ScopeID:        req.ScopeID,
+		LimitTokens:    req.LimitTokens,
+		LimitUSD:       req.LimitUSD,
+		AlertThreshold: model.DefaultBudgetAlertThreshold,
+		Enabled:        req.Enabled == nil || *req.Enabled,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if req.AlertThreshold != nil {
+		b.AlertThreshold = *req.AlertThreshold
+	}
+	if b.Name == "" {
+		b.Name = string(b.Scope) + ":" + b.ScopeID
+	}
+	if err := validate(b); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	existing, err := h.store.ListBudgets(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list budgets")
+		return
+	}
+	for _, e := range existing {
+		if e.Scope == b.Scope && e.ScopeID == b.ScopeID {
+			writeError(w, http.StatusConflict, "budget already exists for "+string(b.Scope)+" "+b.ScopeID)
+			return
+		}
+	}
+	if err := h.store.CreateBudget(r.Context(), b); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create budget")
+		return
+	}
+	slog.InfoContext(r.Context(), "budget.created", "budget_id", b.ID, "scope", b.Scope, "scope_id", b.ScopeID,
+		"limit_tokens", b.LimitTokens, "limit_usd", b.LimitUSD)
+	writeJSON(w, http.StatusCreated, h.view(r, b))
+}
+
+// Get 获取预算及当月已用量
+// GET /api/v1/budgets/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	b := h.load(w, r)
+	if b == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, h.view(r, b))
+}
+
+// Update 更新预算
+// PATCH /api/v1/budgets/{id}
+//
+// 调高上限后，被暂停的 Run 由调度器保底轮询重新分派。
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	b := h.load(w, r)
+	if b == nil {
+		return
+	}
+	if req.Name != nil {
+		b.Name = *req.Name
+	}
+	if req.LimitTokens != nil || req.LimitUSD != nil {
+		b.ExceededPeriod = "" // 上限变更后再次耗尽时重新通知
+	}
+	if req.LimitTokens != nil {
+		b.LimitTokens = *req.LimitTokens
+	}
+	if req.LimitUSD != nil {
+		b.LimitUSD = *req.LimitUSD
+	}
+	if req.AlertThreshold != nil {
+		b.AlertThreshold = *req.AlertThreshold
+		b.AlertedPeriod = "" // 阈值变更后重新判断是否需要预警
+	}
+	if req.Enabled != nil {
+		b.Enabled = *req.Enabled
+	}
+	if err := validate(b); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	b.UpdatedAt = time.Now()
+	if err := h.store.UpdateBudget(r.Context(), b); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update budget")
+		return
+	}
+	h.enforcer.Invalidate()
+	writeJSON(w, http.StatusOK, h.view(r, b))
+}
+
+// Delete 删除预算
+// DELETE /api/v1/budgets/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.DeleteBudget(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete budget")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load 读取路径中的预算，不存在时写入 404 并返回 nil
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) *model.Budget {
+	b, err := h.store.GetBudget(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get budget")
+		return nil
+	}
+	if b == nil {
+		writeError(w, http.StatusNotFound, "budget not found")
+		return nil
+	}
+	return b
+}
+
+// validate 校验作用范围、上限与预警阈值
+func validate(b *model.Budget) error {
+	if !b.Scope.IsValid() {
+		return fmt.Errorf("scope must be account or project")
+	}
+	if b.ScopeID == "" {
+		return fmt.Errorf("scope_id is required")
+	}
+	if b.LimitTokens < 0 || b.LimitUSD < 0 {
+		return fmt.Errorf("limits must not be negative")
+	}
+	if b.LimitTokens == 0 && b.LimitUSD == 0 {
+		return fmt.Errorf("limit_tokens or limit_usd is required")
+	}
+	if b.AlertThreshold < 0 || b.AlertThreshold > 1 {
+		return fmt.Errorf("alert_threshold must be between 0 and 1")
+	}
+	return nil
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
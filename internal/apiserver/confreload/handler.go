@@ -0,0 +1,56 @@
+package confreload
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/config"
+)
+
+// RegisterRoutes 注册配置查看与热加载路由（仅限管理员）
+func (r *Reloader) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/system/config", auth.AdminOnly(r.GetConfig))
+	mux.HandleFunc("POST /api/v1/system/config/reload", auth.AdminOnly(r.PostReload))
+}
+
+// GetConfig 查看生效配置
+// GET /api/v1/system/config
+//
+// 响应: {"config_file": "...", "loaded_at": "...", "reloads": 1, "reloadable": [...], "pending_restart": [...], "effective": {...}}
+// effective 中连接串隐藏密码，凭据类配置项显示为 ***。
+func (r *Reloader) GetConfig(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, r.response())
+}
+
+// PostReload 立即重新加载配置文件
+// POST /api/v1/system/config/reload
+//
+// 响应: 同 GET /api/v1/system/config；配置文件解析失败时返回 422，当前配置保持不变。
+func (r *Reloader) PostReload(w http.ResponseWriter, req *http.Request) {
+	if _, err := r.Reload(); err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, r.response())
+}
+
+type configResponse struct {
+	Status
+	Reloadable []string               `json:"reloadable"`
+	Effective  map[string]interface{} `json:"effective"`
+}
+
+func (r *Reloader) response() configResponse {
+	return configResponse{
+		Status:     r.Status(),
+		Reloadable: config.ReloadableKeys,
+		Effective:  r.Current().Effective(),
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
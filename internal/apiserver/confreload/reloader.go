@@ -0,0 +1,172 @@
+// Package confreload 配置热加载
+//
+// 收到 SIGHUP 或检测到配置文件变更（定时比较修改时间与大小）时重新读取配置，
+// 只把可安全热加载的配置项（调度策略链、抢占、账号池、超时、令牌有效期，见 config.ReloadableKeys）
+// 整体替换后交给 apply 回调生效；其余配置项的变更记录为待重启，不影响运行中的服务。
+// 配置文件解析失败时保留当前配置。
+package confreload
+
+import (
+	"context"
+	"log"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"agents-admin/internal/config"
+)
+
+// Status 热加载状态
+type Status struct {
+	ConfigFile     string     `json:"config_file"`
+	LoadedAt       time.Time  `json:"loaded_at"`             // 当前配置的加载时间（启动或最近一次成功热加载）
+	LastReload     *time.Time `json:"last_reload,omitempty"` // 最近一次尝试热加载的时间
+	LastError      string     `json:"last_error,omitempty"`  // 最近一次热加载失败的原因（成功后清空）
+	Reloads        int        `json:"reloads"`               // 成功热加载次数
+	Applied        []string   `json:"applied,omitempty"`     // 最近一次热加载生效的配置项
+	PendingRestart []string   `json:"pending_restart"`       // 配置文件中已修改、需重启才能生效的配置项
+}
+
+// Reloader 配置热加载器
+type Reloader struct {
+	mu      sync.Mutex
+	current *config.Config // 当前生效的配置
+	status  Status
+	apply   func(*config.Config)
+	load    func() (*config.Config, error)
+}
+
+// New 创建配置热加载器
+//
+// cfg 为启动时加载的配置；apply 在每次成功热加载后以合并后的配置调用，负责把可热加载的配置项应用到运行中的组件。
+func New(cfg *config.Config, apply func(*config.Config)) *Reloader {
+	return &Reloader{
+		current: cfg,
+		status: Status{
+			ConfigFile:     cfg.ConfigFilePath,
+			LoadedAt:       time.Now(),
+			PendingRestart: []string{},
+		},
+		apply: apply,
+		load:  config.LoadChecked,
+	}
+}
+
+// Current 返回当前生效的配置
+func (r *Reloader) Current() *config.Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Status 返回热加载状态
+func (r *Reloader) Status() Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	st := r.status
+	st.Applied = append([]string(nil), st.Applied...)
+	st.PendingRestart = append([]string{}, st.PendingRestart...)
+	return st
+}
+
+// Reload 重新读取配置文件并应用可热加载的配置项
+//
+// 返回本次生效的配置项；配置文件解析失败时返回错误并保留当前配置。
+func (r *Reloader) Reload() ([]string, error) {
+	next, err := r.load()
+
+	r.mu.Lock()
+	now := time.Now()
+	r.status.LastReload = &now
+	if err != nil {
+		r.status.LastError = err.Error()
+		r.mu.Unlock()
+		log.Printf("[config.reload] failed, keeping current config: %v", err)
+		return nil, err
+	}
+
+	// 与启动时的配置比较：不可热加载的变更始终保持待重启状态
+	var applied, pending []string
+	for _, key := range config.Diff(r.current, next) {
+		if config.IsReloadable(key) {
+			applied = append(applied, key)
+		} else {
+			pending = append(pending, key)
+		}
+	}
+	merged := r.current.WithReloadable(next)
+	r.current = merged
+	r.status.LastError = ""
+	r.status.Applied = applied
+	r.status.PendingRestart = append(pending[:0:0], pending...)
+	if r.status.PendingRestart == nil {
+		r.status.PendingRestart = []string{}
+	}
+	if len(applied) > 0 {
+		r.status.LoadedAt = now
+		r.status.Reloads++
+	}
+	r.mu.Unlock()
+
+	if len(applied) > 0 && r.apply != nil {
+		r.apply(merged)
+	}
+	log.Printf("[config.reload] applied=%s pending_restart=%s", strings.Join(applied, ","), strings.Join(pending, ","))
+	return applied, nil
+}
+
+// Watch 监听 SIGHUP 与配置文件变更并触发热加载，直到 ctx 取消
+//
+// interval 为检查配置文件修改时间的间隔，<= 0 时只响应 SIGHUP。
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	var tick <-chan time.Time
+	path := r.Status().ConfigFile
+	if interval > 0 && path != "" {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	last := fileStamp(path)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.InfoContext(ctx, "config.reload", "detail", "SIGHUP received")
+			last = fileStamp(path)
+			r.Reload()
+		case <-tick:
+			if stamp := fileStamp(path); stamp != last {
+				last = stamp
+				slog.InfoContext(ctx, "config.reload", "detail", "config file changed", "path", path)
+				r.Reload()
+			}
+		}
+	}
+}
+
+// stamp 配置文件的修改时间与大小（用于检测变更）
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+func fileStamp(path string) stamp {
+	if path == "" {
+		return stamp{}
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return stamp{}
+	}
+	return stamp{modTime: fi.ModTime(), size: fi.Size()}
+}
@@ -0,0 +1,120 @@
+package confreload
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/config"
+)
+
+func newTestReloader(t *testing.T) (*Reloader, *[]*config.Config, func(*config.Config, error)) {
+	t.Helper()
+	cfg := &config.Config{ConfigFilePath: "/etc/agents-admin/test.yaml"}
+	cfg.Scheduler.Watchdog.DefaultTimeout = time.Hour
+	cfg.APIServer.Port = "8080"
+
+	var applied []*config.Config
+	r := New(cfg, func(c *config.Config) { applied = append(applied, c) })
+	setNext := func(next *config.Config, err error) {
+		r.load = func() (*config.Config, error) { return next, err }
+	}
+	return r, &applied, setNext
+}
+
+func TestReload_AppliesReloadableKeys(t *testing.T) {
+	r, applied, setNext := newTestReloader(t)
+
+	next := *r.Current()
+	next.Scheduler.Watchdog.DefaultTimeout = 3 * time.Hour
+	next.Auth.AccessTokenTTL = "5m"
+	next.APIServer.Port = "9090"
+	setNext(&next, nil)
+
+	keys, err := r.Reload()
+	if err != nil {
+		t.Fatalf("Reload: %v", err)
+	}
+	if strings.Join(keys, ",") != "auth.access_token_ttl,scheduler.watchdog.default_timeout" {
+		t.Errorf("applied = %v", keys)
+	}
+	if len(*applied) != 1 {
+		t.Fatalf("apply called %d times, want 1", len(*applied))
+	}
+	cur := r.Current()
+	if cur.Scheduler.Watchdog.DefaultTimeout != 3*time.Hour || cur.Auth.AccessTokenTTL != "5m" {
+		t.Errorf("reloadable keys not applied: %+v", cur.Scheduler.Watchdog)
+	}
+	if cur.APIServer.Port != "8080" {
+		t.Errorf("port changed without restart: %s", cur.APIServer.Port)
+	}
+	st := r.Status()
+	if st.Reloads != 1 || strings.Join(st.PendingRestart, ",") != "api_server.port" {
+		t.Errorf("status = %+v", st)
+	}
+
+	// 再次加载相同配置：无变更，不调用 apply，待重启项保留
+	if keys, _ := r.Reload(); len(keys) != 0 || len(*applied) != 1 {
+		t.Errorf("unchanged reload: applied=%v calls=%d", keys, len(*applied))
+	}
+	if st := r.Status(); strings.Join(st.PendingRestart, ",") != "api_server.port" {
+		t.Errorf("pending_restart = %v", st.PendingRestart)
+	}
+}
+
+func TestReload_ParseErrorKeepsConfig(t *testing.T) {
+	r, applied, setNext := newTestReloader(t)
+	before := r.Current()
+
+	broken := *before
+	broken.Scheduler.Watchdog.DefaultTimeout = time.Minute
+	setNext(&broken, errors.New("parse test.yaml: yaml: line 1: did not find expected node content"))
+
+	if _, err := r.Reload(); err == nil {
+		t.Fatal("expected error")
+	}
+	if r.Current() != before || len(*applied) != 0 {
+		t.Errorf("config replaced after parse error")
+	}
+	if st := r.Status(); st.LastError == "" || st.LastReload == nil {
+		t.Errorf("status = %+v", st)
+	}
+}
+
+func TestRoutes_AdminOnly(t *testing.T) {
+	r, _, setNext := newTestReloader(t)
+	mux := http.NewServeMux()
+	r.RegisterRoutes(mux)
+	handler := auth.Middleware(auth.Config{})(mux) // 无认证模式（未配置 JWT 密钥）
+
+	serve := func(method, path string, user *auth.AuthUser) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if user != nil {
+			req = req.WithContext(auth.WithAuthUser(req.Context(), user))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := serve("GET", "/api/v1/system/config", &auth.AuthUser{Role: "user"}); w.Code != http.StatusForbidden {
+		t.Errorf("non-admin: expected 403, got %d", w.Code)
+	}
+
+	w := serve("GET", "/api/v1/system/config", &auth.AuthUser{Role: auth.UserRoleAdmin})
+	var body map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &body)
+	if w.Code != http.StatusOK || body["config_file"] != "/etc/agents-admin/test.yaml" || body["effective"] == nil || body["reloadable"] == nil {
+		t.Errorf("admin: got %d %v", w.Code, body)
+	}
+
+	setNext(nil, errors.New("parse failed"))
+	if w := serve("POST", "/api/v1/system/config/reload", nil); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("reload parse error: expected 422, got %d", w.Code)
+	}
+}
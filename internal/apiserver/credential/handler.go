@@ -0,0 +1,351 @@
+// Package credential Git 凭据领域 - HTTP 处理
+//
+// 凭据（SSH 私钥 / HTTPS Token）由存储层信封加密后落库（fieldcrypt.WrapStore），任何 GET 响应都不返回明文。
+// 凭据的管理接口仅限管理员。NodeManager 准备工作空间时通过 POST /api/v1/credentials/resolve 按名称解析明文，
+// 该接口只接受节点专属凭证，且只返回分配给该节点的 Run 引用的凭据。
+package credential
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// Store 定义凭据 handler 需要的存储接口（用于测试 mock），读写的凭据内容为明文，由存储层加密
+type Store interface {
+	CreateCredential(ctx context.Context, cred *model.Credential) error
+	GetCredential(ctx context.Context, id string) (*model.Credential, error)
+	GetCredentialByName(ctx context.Context, name string) (*model.Credential, error)
+	ListCredentials(ctx context.Context) ([]*model.Credential, error)
+	UpdateCredential(ctx context.Context, cred *model.Credential) error
+	DeleteCredential(ctx context.Context, id string) error
+	ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error)
+}
+
+// errEncryptionDisabled 未启用存储层加密时写入/解析接口返回的错误信息
+const errEncryptionDisabled = "encryption not configured: set MASTER_KEY or encryption.provider"
+
+// namePattern 凭据名称规则（被 credential_ref 引用，限制为安全字符）
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,99}$`)
+
+// Handler 凭据领域 HTTP 处理器
+type Handler struct {
+	store     Store
+	encrypted bool // 存储层是否加密凭据内容，未启用时写入/解析接口返回 503，避免明文落库
+}
+
+// NewHandler 创建凭据处理器，encrypted 表示 store 已由 fieldcrypt.WrapStore 包装
+func NewHandler(store Store, encrypted bool) *Handler {
+	if !encrypted {
+		log.Printf("[credential] encryption not configured (MASTER_KEY or encryption.provider), credential management disabled")
+	}
+	return &Handler{store: store, encrypted: encrypted}
+}
+
+// RegisterRoutes 注册凭据相关路由（管理接口仅限管理员，解析接口仅限节点）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/credentials", auth.AdminOnly(h.List))
+	mux.HandleFunc("POST /api/v1/credentials", auth.AdminOnly(h.Create))
+	mux.HandleFunc("POST /api/v1/credentials/resolve", h.Resolve)
+	mux.HandleFunc("GET /api/v1/credentials/{id}", auth.AdminOnly(h.Get))
+	mux.HandleFunc("PUT /api/v1/credentials/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/credentials/{id}", auth.AdminOnly(h.Delete))
+}
+
+// credentialRequest 创建/更新凭据请求
+type credentialRequest struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Username    string `json:"username,omitempty"`
+	Description string `json:"description,omitempty"`
+	Secret      string `json:"secret,omitempty"` // SSH 私钥或 HTTPS Token（仅写入）
+}
+
+// ResolvedCredential 解析后的凭据明文（仅 NodeManager 可获取）
+type ResolvedCredential struct {
+	Name     string               `json:"name"`
+	Type     model.CredentialType `json:"type"`
+	Username string               `json:"username,omitempty"`
+	Secret   string               `json:"secret"`
+}
+
+// List 获取凭据列表
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	creds, err := h.store.ListCredentials(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "ListCredentials", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list credentials")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"credentials": creds})
+}
+
+// Create 创建凭据
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.encrypted {
+		writeError(w, http.StatusServiceUnavailable, errEncryptionDisabled)
+		return
+	}
+	var req credentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !namePattern.MatchString(req.Name) {
+		writeError(w, http.StatusBadRequest, "invalid name: use letters, digits, '.', '_' or '-'")
+		return
+	}
+	if !model.CredentialType(req.Type).IsValid() {
+		writeError(w, http.StatusBadRequest, "type must be ssh_key or https_token")
+		return
+	}
+	if req.Secret == "" {
+		writeError(w, http.StatusBadRequest, "secret is required")
+		return
+	}
+
+	existing, err := h.store.GetCredentialByName(r.Context(), req.Name)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "GetCredentialByName", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create credential")
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "credential name already exists")
+		return
+	}
+
+	now := time.Now()
+	cred := &model.Credential{
+		ID:              generateID("cred"),
+		Name:            req.Name,
+		Type:            model.CredentialType(req.Type),
+		Username:        req.Username,
+		Description:     req.Description,
+		EncryptedSecret: req.Secret,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	if err := h.store.CreateCredential(r.Context(), cred); err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "CreateCredential", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create credential")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "credential.created", "name", cred.Name, "type", cred.Type)
+	writeJSON(w, http.StatusCreated, cred)
+}
+
+// Get 获取凭据详情（不含明文）
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	cred, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, cred)
+}
+
+// Update 更新凭据，secret 为空时保留原内容
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	if !h.encrypted {
+		writeError(w, http.StatusServiceUnavailable, errEncryptionDisabled)
+		return
+	}
+	cred, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	var req credentialRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if name := strings.TrimSpace(req.Name); name != "" && name != cred.Name {
+		if !namePattern.MatchString(name) {
+			writeError(w, http.StatusBadRequest, "invalid name: use letters, digits, '.', '_' or '-'")
+			return
+		}
+		other, err := h.store.GetCredentialByName(r.Context(), name)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "credential", "op", "GetCredentialByName", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to update credential")
+			return
+		}
+		if other != nil {
+			writeError(w, http.StatusConflict, "credential name already exists")
+			return
+		}
+		cred.Name = name
+	}
+	if req.Type != "" {
+		if !model.CredentialType(req.Type).IsValid() {
+			writeError(w, http.StatusBadRequest, "type must be ssh_key or https_token")
+			return
+		}
+		cred.Type = model.CredentialType(req.Type)
+	}
+	cred.Username = req.Username
+	cred.Description = req.Description
+	if req.Secret != "" {
+		cred.EncryptedSecret = req.Secret
+	}
+	cred.UpdatedAt = time.Now()
+
+	if err := h.store.UpdateCredential(r.Context(), cred); err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "UpdateCredential", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update credential")
+		return
+	}
+	writeJSON(w, http.StatusOK, cred)
+}
+
+// Delete 删除凭据，不存在时返回 404
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	cred, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteCredential(r.Context(), cred.ID); err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "DeleteCredential", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete credential")
+		return
+	}
+	slog.InfoContext(r.Context(), "credential.deleted", "id", cred.ID, "name", cred.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Resolve 按名称解析凭据明文（NodeManager 专用）
+// POST /api/v1/credentials/resolve
+//
+// 使用 POST 避免明文出现在 GET 响应/缓存中。只接受节点专属凭证（加入令牌换取的 Token 或节点客户端证书）：
+// 用户会话、共享密钥与无认证模式的请求返回 403；名称须被当前分配给该节点的 Run（assigned / running / paused）
+// 快照中的工作空间 credential_ref 引用，否则返回 403。
+func (h *Handler) Resolve(w http.ResponseWriter, r *http.Request) {
+	node := auth.GetNodeIdentity(r.Context())
+	if node == nil || node.ID == "" {
+		writeError(w, http.StatusForbidden, "credential secrets are only available to node managers with a per-node credential")
+		return
+	}
+	if !h.encrypted {
+		writeError(w, http.StatusServiceUnavailable, errEncryptionDisabled)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	referenced, err := h.referencedCredentials(r.Context(), node.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "ListRunsByNode", "node_id", node.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to resolve credential")
+		return
+	}
+	if !referenced[req.Name] {
+		slog.WarnContext(r.Context(), "credential.resolve.denied", "node_id", node.ID, "name", req.Name)
+		writeError(w, http.StatusForbidden, "credential is not referenced by a run assigned to this node")
+		return
+	}
+
+	cred, err := h.store.GetCredentialByName(r.Context(), req.Name)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "GetCredentialByName", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to resolve credential")
+		return
+	}
+	if cred == nil {
+		writeError(w, http.StatusNotFound, "credential not found")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "credential.resolved", "name", cred.Name)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, ResolvedCredential{
+		Name:     cred.Name,
+		Type:     cred.Type,
+		Username: cred.Username,
+		Secret:   cred.EncryptedSecret,
+	})
+}
+
+// referencedCredentials 当前分配给节点的 Run 在快照工作空间中引用的凭据名称（git 与 remote 的 credential_ref）
+func (h *Handler) referencedCredentials(ctx context.Context, nodeID string) (map[string]bool, error) {
+	runs, err := h.store.ListRunsByNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, run := range runs {
+		snapshot, err := parseSnapshot(run.Snapshot)
+		if err != nil || snapshot.Workspace == nil {
+			continue // 快照不合法的 Run 无法执行，不引用任何凭据
+		}
+		if git := snapshot.Workspace.Git; git != nil && git.CredentialRef != "" {
+			names[git.CredentialRef] = true
+		}
+		if remote := snapshot.Workspace.Remote; remote != nil && remote.CredentialRef != "" {
+			names[remote.CredentialRef] = true
+		}
+	}
+	return names, nil
+}
+
+// parseSnapshot 按节点收到的格式解析 Run 快照（旧版本格式先升级，见 model.NormalizeSnapshot）
+func parseSnapshot(raw json.RawMessage) (*model.RunSnapshot, error) {
+	normalized, err := model.NormalizeSnapshot(raw)
+	if err != nil {
+		return nil, err
+	}
+	return model.ParseRunSnapshot(normalized)
+}
+
+// load 按路径参数加载凭据，不存在时写入 404
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) (*model.Credential, bool) {
+	id := r.PathValue("id")
+	cred, err := h.store.GetCredential(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "credential", "op", "GetCredential", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get credential")
+		return nil, false
+	}
+	if cred == nil {
+		writeError(w, http.StatusNotFound, "credential not found")
+		return nil, false
+	}
+	return cred, true
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
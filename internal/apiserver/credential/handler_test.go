@@ -0,0 +1,279 @@
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/fieldcrypt"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// mockStore 内存凭据存储（保存的是落库内容，经 fieldcrypt.WrapStore 包装后为密文）
+type mockStore struct {
+	storage.PersistentStore // 嵌入接口，未实现的方法会 panic（测试中不应调用）
+
+	creds     map[string]*model.Credential
+	runs      map[string][]*model.Run // node_id -> 分配给节点的 Run
+	lookupErr error                   // GetCredentialByName 返回的错误
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{creds: make(map[string]*model.Credential), runs: make(map[string][]*model.Run)}
+}
+
+func (m *mockStore) CreateCredential(_ context.Context, c *model.Credential) error {
+	m.creds[c.ID] = c
+	return nil
+}
+func (m *mockStore) GetCredential(_ context.Context, id string) (*model.Credential, error) {
+	return m.creds[id], nil
+}
+func (m *mockStore) GetCredentialByName(_ context.Context, name string) (*model.Credential, error) {
+	if m.lookupErr != nil {
+		return nil, m.lookupErr
+	}
+	for _, c := range m.creds {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockStore) ListCredentials(_ context.Context) ([]*model.Credential, error) {
+	var list []*model.Credential
+	for _, c := range m.creds {
+		list = append(list, c)
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateCredential(_ context.Context, c *model.Credential) error {
+	m.creds[c.ID] = c
+	return nil
+}
+func (m *mockStore) DeleteCredential(_ context.Context, id string) error {
+	delete(m.creds, id)
+	return nil
+}
+func (m *mockStore) ListRunsByNode(_ context.Context, nodeID string) ([]*model.Run, error) {
+	return m.runs[nodeID], nil
+}
+
+// assignRun 将以 credentialRef 克隆仓库的 Run 分配给节点
+func (m *mockStore) assignRun(nodeID, credentialRef string) {
+	snapshot, _ := json.Marshal(model.RunSnapshot{
+		Agent:     model.SnapshotAgent{Type: "qwen-code"},
+		Prompt:    "p",
+		Workspace: &model.WorkspaceConfig{Type: "git", Git: &model.GitConfig{URL: "https://example.com/repo.git", CredentialRef: credentialRef}},
+	})
+	m.runs[nodeID] = append(m.runs[nodeID], &model.Run{ID: "run-" + nodeID, NodeID: &nodeID, Status: model.RunStatusRunning, Snapshot: snapshot})
+}
+
+// newTestMux 注册路由，以无认证模式（未配置 JWT 密钥）处理请求；encrypted 时存储层以本地 KEK 加密
+func newTestMux(t *testing.T, store *mockStore, encrypted bool) http.Handler {
+	t.Helper()
+	var handlerStore Store = store
+	if encrypted {
+		kek, _ := fieldcrypt.NewLocalKEK("test-kek")
+		keys, err := fieldcrypt.NewKeyring(kek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlerStore = fieldcrypt.WrapStore(store, keys)
+	}
+	mux := http.NewServeMux()
+	NewHandler(handlerStore, encrypted).RegisterRoutes(mux)
+	return auth.Middleware(auth.Config{})(mux)
+}
+
+// resolveAs 以节点身份解析凭据（nodeID 为空表示共享密钥）
+func resolveAs(mux http.Handler, nodeID, name string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/credentials/resolve", strings.NewReader(`{"name":"`+name+`"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req.WithContext(auth.WithNodeIdentity(req.Context(), nodeID)))
+	return w
+}
+
+func TestCreateCredential_SecretNeverReturned(t *testing.T) {
+	store := newMockStore()
+	mux := newTestMux(t, store, true)
+
+	body := `{"name":"github-bot","type":"https_token","secret":"ghp_topsecret"}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/credentials", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "ghp_topsecret") || strings.Contains(w.Body.String(), "encrypted_secret") {
+		t.Errorf("创建响应泄露了凭据: %s", w.Body.String())
+	}
+
+	// 落库为密文
+	var created model.Credential
+	json.Unmarshal(w.Body.Bytes(), &created)
+	stored := store.creds[created.ID]
+	if stored == nil || !fieldcrypt.IsSealed(stored.EncryptedSecret) || strings.Contains(stored.EncryptedSecret, "ghp_topsecret") {
+		t.Fatalf("凭据未加密存储: %+v", stored)
+	}
+
+	for _, path := range []string{"/api/v1/credentials", "/api/v1/credentials/" + created.ID} {
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "ghp_topsecret") || strings.Contains(w.Body.String(), stored.EncryptedSecret) {
+			t.Errorf("GET %s 泄露了凭据: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	// 名称重复
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/credentials", strings.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("重复名称 status = %d, 期望 409", w.Code)
+	}
+}
+
+func TestCreateCredential_Validation(t *testing.T) {
+	mux := newTestMux(t, newMockStore(), true)
+	cases := []string{
+		`{"name":"bad name","type":"https_token","secret":"x"}`,
+		`{"name":"ok","type":"password","secret":"x"}`,
+		`{"name":"ok","type":"ssh_key"}`,
+	}
+	for _, body := range cases {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/credentials", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, 期望 400", body, w.Code)
+		}
+	}
+}
+
+func TestCreateCredential_NoEncryption(t *testing.T) {
+	mux := newTestMux(t, newMockStore(), false)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/credentials",
+		strings.NewReader(`{"name":"a","type":"https_token","secret":"x"}`)))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, 期望 503", w.Code)
+	}
+}
+
+func TestResolveCredential(t *testing.T) {
+	store := newMockStore()
+	store.assignRun("node-1", "deploy-key")
+	store.assignRun("node-1", "nope")
+	store.assignRun("node-2", "other-key")
+	mux := newTestMux(t, store, true)
+	for _, body := range []string{
+		`{"name":"deploy-key","type":"ssh_key","secret":"-----BEGIN KEY-----"}`,
+		`{"name":"other-key","type":"https_token","secret":"ghp_other"}`,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/credentials", strings.NewReader(body)))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("create status = %d", w.Code)
+		}
+	}
+
+	// 分配给本节点的 Run 引用的凭据可解析明文
+	w := resolveAs(mux, "node-1", "deploy-key")
+	if w.Code != http.StatusOK {
+		t.Fatalf("resolve status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resolved ResolvedCredential
+	json.Unmarshal(w.Body.Bytes(), &resolved)
+	if resolved.Secret != "-----BEGIN KEY-----" || resolved.Type != model.CredentialTypeSSHKey {
+		t.Errorf("resolved = %+v", resolved)
+	}
+
+	// 其他节点的 Run 引用的凭据、共享密钥
+	if w := resolveAs(mux, "node-1", "other-key"); w.Code != http.StatusForbidden || strings.Contains(w.Body.String(), "ghp_other") {
+		t.Errorf("未引用的凭据 status = %d, body = %s, 期望 403", w.Code, w.Body.String())
+	}
+	if w := resolveAs(mux, "", "deploy-key"); w.Code != http.StatusForbidden {
+		t.Errorf("共享密钥 status = %d, 期望 403", w.Code)
+	}
+
+	// 用户会话与无认证模式的匿名请求禁止解析
+	req := httptest.NewRequest("POST", "/api/v1/credentials/resolve", strings.NewReader(`{"name":"deploy-key"}`))
+	req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: "admin"}))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("用户会话 resolve status = %d, 期望 403", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/credentials/resolve", strings.NewReader(`{"name":"deploy-key"}`)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("匿名 resolve status = %d, 期望 403", w.Code)
+	}
+
+	// 被引用但不存在
+	if w := resolveAs(mux, "node-1", "nope"); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, 期望 404", w.Code)
+	}
+}
+
+// TestCredentialRoutes_AdminOnly 管理接口拒绝非管理员用户与节点凭证
+func TestCredentialRoutes_AdminOnly(t *testing.T) {
+	store := newMockStore()
+	store.creds["cred-1"] = &model.Credential{ID: "cred-1", Name: "deploy-key", Type: model.CredentialTypeSSHKey, EncryptedSecret: "x"}
+	mux := newTestMux(t, store, true)
+
+	for _, tt := range []struct{ method, path, body string }{
+		{"GET", "/api/v1/credentials", ""},
+		{"POST", "/api/v1/credentials", `{"name":"b","type":"https_token","secret":"x"}`},
+		{"GET", "/api/v1/credentials/cred-1", ""},
+		{"PUT", "/api/v1/credentials/cred-1", `{"secret":"y"}`},
+		{"DELETE", "/api/v1/credentials/cred-1", ""},
+	} {
+		for name, ctx := range map[string]func(context.Context) context.Context{
+			"user": func(ctx context.Context) context.Context {
+				return auth.WithAuthUser(ctx, &auth.AuthUser{ID: "u1", Role: "user"})
+			},
+			"node": func(ctx context.Context) context.Context { return auth.WithNodeIdentity(ctx, "node-1") },
+		} {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req.WithContext(ctx(req.Context())))
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s %s: status = %d, 期望 403", name, tt.method, tt.path, w.Code)
+			}
+		}
+	}
+	if c := store.creds["cred-1"]; c == nil || c.EncryptedSecret != "x" || len(store.creds) != 1 {
+		t.Errorf("被拒绝的请求修改了凭据: %+v", store.creds)
+	}
+}
+
+// TestUpdateDeleteCredential 改名时查询失败返回 500 而非跳过唯一性检查；删除不存在的凭据返回 404
+func TestUpdateDeleteCredential(t *testing.T) {
+	store := newMockStore()
+	store.creds["cred-1"] = &model.Credential{ID: "cred-1", Name: "deploy-key", Type: model.CredentialTypeSSHKey, EncryptedSecret: "x"}
+	mux := newTestMux(t, store, true)
+
+	store.lookupErr = errors.New("db down")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/credentials/cred-1", strings.NewReader(`{"name":"renamed"}`)))
+	if w.Code != http.StatusInternalServerError || store.creds["cred-1"].Name != "deploy-key" {
+		t.Errorf("查询失败 status = %d, name = %s, 期望 500 且不改名", w.Code, store.creds["cred-1"].Name)
+	}
+	store.lookupErr = nil
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/credentials/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("删除不存在的凭据 status = %d, 期望 404", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/credentials/cred-1", nil))
+	if w.Code != http.StatusNoContent || len(store.creds) != 0 {
+		t.Errorf("删除 status = %d, creds = %v", w.Code, store.creds)
+	}
+}
@@ -0,0 +1,110 @@
+package eventsink
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+)
+
+// SASL 认证机制
+const (
+	SASLPlain       = "plain"
+	SASLScramSHA256 = "scram-sha-256"
+	SASLScramSHA512 = "scram-sha-512"
+)
+
+// KafkaConfig Kafka 连接配置
+type KafkaConfig struct {
+	Brokers       []string // Broker 地址列表（host:port）
+	Topic         string   // 目标 Topic
+	ClientID      string   // 客户端标识（默认 agents-admin）
+	TLS           bool     // 是否使用 TLS 连接
+	SASLMechanism string   // SASL 机制：plain / scram-sha-256 / scram-sha-512，为空时不认证
+	SASLUsername  string
+	SASLPassword  string
+}
+
+// kafkaProducer 基于 kafka-go Writer 的 Producer
+//
+// 消息 Key 为 run_id（Hash 分区保证同一 Run 的事件进入同一分区并保持顺序），
+// 要求所有 ISR 副本确认（RequireAll），Writer 返回成功即表示整批已持久化。
+type kafkaProducer struct {
+	w *kafka.Writer
+}
+
+// NewKafkaProducer 创建 Kafka Producer（不预先建立连接，首次投递时连接 Broker）
+func NewKafkaProducer(cfg KafkaConfig) (Producer, error) {
+	if len(cfg.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka brokers not configured")
+	}
+	if cfg.Topic == "" {
+		return nil, fmt.Errorf("kafka topic not configured")
+	}
+	if cfg.ClientID == "" {
+		cfg.ClientID = "agents-admin"
+	}
+
+	mechanism, err := saslMechanism(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport := &kafka.Transport{
+		ClientID:    cfg.ClientID,
+		DialTimeout: 5 * time.Second,
+		SASL:        mechanism,
+	}
+	if cfg.TLS {
+		transport.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return &kafkaProducer{w: &kafka.Writer{
+		Addr:         kafka.TCP(cfg.Brokers...),
+		Topic:        cfg.Topic,
+		Balancer:     &kafka.Hash{},
+		RequiredAcks: kafka.RequireAll,
+		// 重试由 Sink 统一控制，Writer 只尝试一次
+		MaxAttempts: 1,
+		BatchSize:   1000,
+		Transport:   transport,
+	}}, nil
+}
+
+func saslMechanism(cfg KafkaConfig) (sasl.Mechanism, error) {
+	switch strings.ToLower(cfg.SASLMechanism) {
+	case "":
+		return nil, nil
+	case SASLPlain:
+		return plain.Mechanism{Username: cfg.SASLUsername, Password: cfg.SASLPassword}, nil
+	case SASLScramSHA256:
+		return scram.Mechanism(scram.SHA256, cfg.SASLUsername, cfg.SASLPassword)
+	case SASLScramSHA512:
+		return scram.Mechanism(scram.SHA512, cfg.SASLUsername, cfg.SASLPassword)
+	}
+	return nil, fmt.Errorf("unsupported kafka sasl mechanism: %s", cfg.SASLMechanism)
+}
+
+// Produce 同步写入一批记录
+func (p *kafkaProducer) Produce(ctx context.Context, records []Record) error {
+	msgs := make([]kafka.Message, len(records))
+	for i, rec := range records {
+		value, err := json.Marshal(rec)
+		if err != nil {
+			return err
+		}
+		msgs[i] = kafka.Message{Key: []byte(rec.RunID), Value: value, Time: rec.Timestamp}
+	}
+	return p.w.WriteMessages(ctx, msgs...)
+}
+
+// Close 关闭 Writer
+func (p *kafkaProducer) Close() error {
+	return p.w.Close()
+}
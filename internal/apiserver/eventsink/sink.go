@@ -0,0 +1,434 @@
+// Package eventsink Run 事件外部镜像
+//
+// 事件写入数据库成功后（WrapStore）进入内存缓冲，由 Sink 批量投递到外部系统（当前为 Kafka）：
+//   - 投递失败按 1s、2s、4s… 退避重试 MaxRetries 次，仍失败的批次追加写入死信文件（JSON Lines）
+//   - 缓冲区已满时新事件直接写入死信文件，不阻塞事件写入路径
+//   - 每隔 ReplayInterval 重放死信文件，重放失败的记录写回死信文件，等待下一轮
+//   - 进程退出时先投递缓冲区剩余事件，投递失败的同样写入死信文件
+//
+// 投递语义为至少一次：重试与重放都可能产生重复消息，消费方按 (run_id, seq) 去重。
+// 仅在进程被强制终止（缓冲区中的事件尚未投递也未落盘）时可能丢失事件，
+// 此时可按 run_id 从数据库 events 表补齐。
+package eventsink
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Record 投递到外部系统的事件记录（Kafka 消息 Key 为 RunID，保证同一 Run 的事件有序）
+type Record struct {
+	RunID     string          `json:"run_id"`
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Timestamp time.Time       `json:"timestamp"`
+	Payload   json.RawMessage `json:"payload,omitempty"`
+}
+
+// Producer 批量投递事件记录（返回 nil 表示整批已被外部系统确认）
+type Producer interface {
+	Produce(ctx context.Context, records []Record) error
+	Close() error
+}
+
+// Config Sink 配置，零值字段使用默认值
+type Config struct {
+	BufferSize     int           // 内存缓冲的最大事件数（默认 10000）
+	BatchSize      int           // 单批投递的最大事件数（默认 500）
+	FlushInterval  time.Duration // 未攒满一批时的最长等待（默认 1s）
+	WriteTimeout   time.Duration // 单次投递超时（默认 10s）
+	MaxRetries     int           // 单批投递失败后的重试次数（默认 3）
+	DeadLetterPath string        // 死信文件路径（默认 data/event-sink-dead-letter.jsonl）
+	ReplayInterval time.Duration // 重放死信文件的间隔（默认 1m）
+}
+
+// Stats Sink 运行统计（指标导出用）
+type Stats struct {
+	LagEvents      int64     // 已写入数据库、尚未投递的事件数（缓冲区 + 死信文件）
+	LagSeconds     float64   // 最早一个未投递事件的等待时长
+	Delivered      int64     // 累计投递成功的事件数
+	DeadLettered   int64     // 累计写入死信文件的事件数
+	DeadLetterSize int64     // 死信文件中待重放的事件数
+	LastError      string    // 最近一次投递失败的原因
+	LastErrorAt    time.Time // 最近一次投递失败的时间
+}
+
+// item 缓冲区中的事件及其入队时间
+type item struct {
+	rec      Record
+	enqueued time.Time
+}
+
+// Sink 事件镜像投递器
+type Sink struct {
+	producer Producer
+	cfg      Config
+	buf      chan item
+	backoff  func(attempt int) time.Duration
+
+	delivered    atomic.Int64
+	deadLettered atomic.Int64
+
+	mu          sync.Mutex // 保护死信文件与以下字段
+	dlqPending  int64
+	dlqOldest   time.Time
+	headEnqueue time.Time // 当前批次中最早事件的入队时间
+	lastErr     string
+	lastErrAt   time.Time
+}
+
+// New 创建事件镜像投递器
+func New(producer Producer, cfg Config) *Sink {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 10000
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 500
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.WriteTimeout <= 0 {
+		cfg.WriteTimeout = 10 * time.Second
+	}
+	if cfg.MaxRetries <= 0 {
+		cfg.MaxRetries = 3
+	}
+	if cfg.DeadLetterPath == "" {
+		cfg.DeadLetterPath = "data/event-sink-dead-letter.jsonl"
+	}
+	if cfg.ReplayInterval <= 0 {
+		cfg.ReplayInterval = time.Minute
+	}
+	s := &Sink{
+		producer: producer,
+		cfg:      cfg,
+		buf:      make(chan item, cfg.BufferSize),
+		backoff:  func(attempt int) time.Duration { return time.Second << (attempt - 1) },
+	}
+	s.dlqPending, s.dlqOldest = countDeadLetter(cfg.DeadLetterPath)
+	return s
+}
+
+// Enqueue 将已写入数据库的事件加入投递缓冲（不阻塞；缓冲区已满时写入死信文件）
+func (s *Sink) Enqueue(events []*model.Event) {
+	now := time.Now()
+	var overflow []Record
+	for _, e := range events {
+		rec := Record{RunID: e.RunID, Seq: e.Seq, Type: e.Type, Timestamp: e.Timestamp, Payload: e.Payload}
+		select {
+		case s.buf <- item{rec: rec, enqueued: now}:
+		default:
+			overflow = append(overflow, rec)
+		}
+	}
+	if len(overflow) > 0 {
+		log.Printf("[eventsink.enqueue.overflow] count=%d buffer_size=%d", len(overflow), s.cfg.BufferSize)
+		s.deadLetter(overflow, now)
+	}
+}
+
+// Start 启动投递循环，ctx 取消后投递缓冲区剩余事件并关闭 Producer
+func (s *Sink) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "eventsink.start", "batch_size", s.cfg.BatchSize, "flush_interval", s.cfg.FlushInterval,
+		"dead_letter", s.cfg.DeadLetterPath, "pending", s.Stats().DeadLetterSize)
+
+	flush := time.NewTicker(s.cfg.FlushInterval)
+	defer flush.Stop()
+	replay := time.NewTicker(s.cfg.ReplayInterval)
+	defer replay.Stop()
+
+	batch := make([]item, 0, s.cfg.BatchSize)
+	send := func(ctx context.Context) {
+		if len(batch) > 0 {
+			s.deliver(ctx, batch)
+			batch = batch[:0]
+			s.setHead(time.Time{})
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.drain(batch)
+			if err := s.producer.Close(); err != nil {
+				slog.ErrorContext(ctx, "eventsink.close.failed", "error", err)
+			}
+			return
+		case it := <-s.buf:
+			if len(batch) == 0 {
+				s.setHead(it.enqueued)
+			}
+			batch = append(batch, it)
+			if len(batch) >= s.cfg.BatchSize {
+				send(ctx)
+			}
+		case <-flush.C:
+			send(ctx)
+		case <-replay.C:
+			send(ctx)
+			s.ReplayDeadLetter(ctx)
+		}
+	}
+}
+
+// drain 退出前投递当前批次与缓冲区剩余事件（不再重试，失败直接写入死信文件）
+func (s *Sink) drain(batch []item) {
+drain:
+	for {
+		select {
+		case it := <-s.buf:
+			batch = append(batch, it)
+		default:
+			break drain
+		}
+	}
+	if len(batch) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.cfg.WriteTimeout)
+	defer cancel()
+	records, oldest := unwrap(batch)
+	if err := s.producer.Produce(ctx, records); err != nil {
+		s.recordError(err)
+		s.deadLetter(records, oldest)
+		return
+	}
+	s.delivered.Add(int64(len(records)))
+	slog.InfoContext(ctx, "eventsink.drain.success", "count", len(records))
+}
+
+// deliver 投递一个批次，重试耗尽后写入死信文件
+func (s *Sink) deliver(ctx context.Context, batch []item) {
+	records, oldest := unwrap(batch)
+	if err := s.produce(ctx, records); err != nil {
+		slog.ErrorContext(ctx, "eventsink.deliver.failed", "count", len(records), "error", err)
+		s.deadLetter(records, oldest)
+		return
+	}
+	s.delivered.Add(int64(len(records)))
+}
+
+// produce 带退避重试的投递（ctx 取消时立即放弃重试）
+func (s *Sink) produce(ctx context.Context, records []Record) error {
+	var err error
+	for attempt := 0; attempt <= s.cfg.MaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return err
+			case <-time.After(s.backoff(attempt)):
+			}
+		}
+		wctx, cancel := context.WithTimeout(ctx, s.cfg.WriteTimeout)
+		err = s.producer.Produce(wctx, records)
+		cancel()
+		if err == nil {
+			return nil
+		}
+		s.recordError(err)
+	}
+	return err
+}
+
+// ReplayDeadLetter 重放死信文件中的事件，失败的记录写回死信文件
+//
+// 重放前将文件改名为 .replaying，重放期间新产生的死信写入新文件；
+// 进程在重放中途退出时，下次重放会先处理遗留的 .replaying 文件。
+func (s *Sink) ReplayDeadLetter(ctx context.Context) {
+	replaying := s.cfg.DeadLetterPath + ".replaying"
+
+	s.mu.Lock()
+	if _, err := os.Stat(replaying); err != nil {
+		if err := os.Rename(s.cfg.DeadLetterPath, replaying); err != nil {
+			s.mu.Unlock()
+			if !errors.Is(err, os.ErrNotExist) {
+				slog.ErrorContext(ctx, "eventsink.replay.failed", "error", err)
+			}
+			return
+		}
+	}
+	s.mu.Unlock()
+
+	records, err := readDeadLetter(replaying)
+	if err != nil {
+		slog.ErrorContext(ctx, "eventsink.replay.failed", "error", err)
+		return
+	}
+
+	var failed []Record
+	for start := 0; start < len(records); start += s.cfg.BatchSize {
+		chunk := records[start:min(start+s.cfg.BatchSize, len(records))]
+		if len(failed) == 0 {
+			wctx, cancel := context.WithTimeout(ctx, s.cfg.WriteTimeout)
+			err = s.producer.Produce(wctx, chunk)
+			cancel()
+			if err == nil {
+				s.delivered.Add(int64(len(chunk)))
+				continue
+			}
+			s.recordError(err)
+		}
+		// 一批失败后 Kafka 大概率仍不可用，剩余记录直接写回，等待下一轮
+		failed = append(failed, chunk...)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(failed) > 0 {
+		if err := appendDeadLetter(s.cfg.DeadLetterPath, failed); err != nil {
+			// 写回失败时保留 .replaying 文件，下一轮重放时再处理
+			slog.ErrorContext(ctx, "eventsink.replay.failed", "count", len(failed), "error", err)
+			return
+		}
+	}
+	if err := os.Remove(replaying); err != nil {
+		slog.ErrorContext(ctx, "eventsink.replay.failed", "error", err)
+	}
+	s.dlqPending, s.dlqOldest = countDeadLetter(s.cfg.DeadLetterPath)
+	if delivered := len(records) - len(failed); delivered > 0 {
+		slog.InfoContext(ctx, "eventsink.replay.success", "delivered", delivered, "remaining", len(failed))
+	}
+}
+
+// deadLetter 将事件追加写入死信文件（写入失败时事件丢失，只能从数据库补齐）
+func (s *Sink) deadLetter(records []Record, oldest time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := appendDeadLetter(s.cfg.DeadLetterPath, records); err != nil {
+		log.Printf("[eventsink.deadletter.failed] count=%d error=%v", len(records), err)
+		return
+	}
+	s.deadLettered.Add(int64(len(records)))
+	if s.dlqPending == 0 || oldest.Before(s.dlqOldest) {
+		s.dlqOldest = oldest
+	}
+	s.dlqPending += int64(len(records))
+	log.Printf("[eventsink.deadletter.success] count=%d path=%s", len(records), s.cfg.DeadLetterPath)
+}
+
+// Stats 返回运行统计
+func (s *Sink) Stats() Stats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := Stats{
+		LagEvents:      int64(len(s.buf)) + s.dlqPending,
+		Delivered:      s.delivered.Load(),
+		DeadLettered:   s.deadLettered.Load(),
+		DeadLetterSize: s.dlqPending,
+		LastError:      s.lastErr,
+		LastErrorAt:    s.lastErrAt,
+	}
+	oldest := s.headEnqueue
+	if s.dlqPending > 0 && (oldest.IsZero() || s.dlqOldest.Before(oldest)) {
+		oldest = s.dlqOldest
+	}
+	if !oldest.IsZero() {
+		st.LagSeconds = time.Since(oldest).Seconds()
+	}
+	return st
+}
+
+func (s *Sink) setHead(t time.Time) {
+	s.mu.Lock()
+	s.headEnqueue = t
+	s.mu.Unlock()
+}
+
+func (s *Sink) recordError(err error) {
+	s.mu.Lock()
+	s.lastErr = err.Error()
+	s.lastErrAt = time.Now()
+	s.mu.Unlock()
+}
+
+// unwrap 取出批次中的记录与最早入队时间
+func unwrap(batch []item) ([]Record, time.Time) {
+	records := make([]Record, len(batch))
+	for i, it := range batch {
+		records[i] = it.rec
+	}
+	return records, batch[0].enqueued
+}
+
+// appendDeadLetter 以 JSON Lines 追加写入死信文件并刷盘
+func appendDeadLetter(path string, records []Record) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			f.Close()
+			return err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readDeadLetter 读取死信文件（跳过无法解析的行，如进程崩溃时写了一半的最后一行）
+func readDeadLetter(path string) ([]Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		var rec Record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			log.Printf("[eventsink.replay.skip] error=%v", err)
+			continue
+		}
+		records = append(records, rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read dead letter file: %w", err)
+	}
+	return records, nil
+}
+
+// countDeadLetter 统计死信文件与遗留 .replaying 文件中的事件数及最早事件时间（启动时恢复滞后统计）
+func countDeadLetter(path string) (int64, time.Time) {
+	var n int64
+	var oldest time.Time
+	for _, p := range []string{path + ".replaying", path} {
+		records, err := readDeadLetter(p)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+		n += int64(len(records))
+		if oldest.IsZero() || records[0].Timestamp.Before(oldest) {
+			oldest = records[0].Timestamp
+		}
+	}
+	return n, oldest
+}
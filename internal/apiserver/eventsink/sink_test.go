@@ -0,0 +1,259 @@
+package eventsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// fakeProducer 记录投递的事件，down 为 true 时模拟 Kafka 不可用
+type fakeProducer struct {
+	mu       sync.Mutex
+	down     bool
+	calls    int
+	records  []Record
+	closed   bool
+	produced chan struct{}
+}
+
+func newFakeProducer() *fakeProducer {
+	return &fakeProducer{produced: make(chan struct{}, 100)}
+}
+
+func (p *fakeProducer) Produce(_ context.Context, records []Record) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls++
+	if p.down {
+		return errors.New("kafka: connection refused")
+	}
+	p.records = append(p.records, records...)
+	select {
+	case p.produced <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (p *fakeProducer) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	p.mu.Unlock()
+	return nil
+}
+
+func (p *fakeProducer) setDown(down bool) {
+	p.mu.Lock()
+	p.down = down
+	p.mu.Unlock()
+}
+
+func (p *fakeProducer) delivered() []Record {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Record(nil), p.records...)
+}
+
+func newTestSink(t *testing.T, p Producer, cfg Config) *Sink {
+	t.Helper()
+	if cfg.DeadLetterPath == "" {
+		cfg.DeadLetterPath = filepath.Join(t.TempDir(), "dlq", "events.jsonl")
+	}
+	s := New(p, cfg)
+	s.backoff = func(int) time.Duration { return time.Millisecond }
+	return s
+}
+
+func testEvents(runID string, n int) []*model.Event {
+	events := make([]*model.Event, n)
+	for i := range events {
+		events[i] = &model.Event{RunID: runID, Seq: i + 1, Type: "message", Timestamp: time.Now(), Payload: []byte(`{"text":"hi"}`)}
+	}
+	return events
+}
+
+func TestSink_DeliversInBatches(t *testing.T) {
+	p := newFakeProducer()
+	s := newTestSink(t, p, Config{BatchSize: 2, FlushInterval: 10 * time.Millisecond})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		s.Start(ctx)
+		close(done)
+	}()
+
+	s.Enqueue(testEvents("run-1", 5))
+	deadline := time.After(2 * time.Second)
+	for len(p.delivered()) < 5 {
+		select {
+		case <-p.produced:
+		case <-deadline:
+			t.Fatalf("投递超时, delivered=%d", len(p.delivered()))
+		}
+	}
+	cancel()
+	<-done
+
+	records := p.delivered()
+	for i, rec := range records {
+		if rec.RunID != "run-1" || rec.Seq != i+1 || string(rec.Payload) != `{"text":"hi"}` {
+			t.Errorf("records[%d] = %+v", i, rec)
+		}
+	}
+	st := s.Stats()
+	if st.Delivered != 5 || st.LagEvents != 0 || st.DeadLettered != 0 {
+		t.Errorf("Stats = %+v", st)
+	}
+	if !p.closed {
+		t.Error("退出时应关闭 Producer")
+	}
+}
+
+func TestSink_DeadLetterAndReplay(t *testing.T) {
+	p := newFakeProducer()
+	p.setDown(true)
+	s := newTestSink(t, p, Config{BatchSize: 10, MaxRetries: 2})
+
+	// Kafka 不可用：重试耗尽后写入死信文件
+	s.Enqueue(testEvents("run-1", 3))
+	s.deliver(context.Background(), drainBuffer(s))
+	if p.calls != 3 {
+		t.Errorf("Produce 调用次数 = %d, 期望 3（首次 + 2 次重试）", p.calls)
+	}
+	st := s.Stats()
+	if st.DeadLettered != 3 || st.DeadLetterSize != 3 || st.LagEvents != 3 || st.LagSeconds <= 0 {
+		t.Errorf("死信后 Stats = %+v", st)
+	}
+	if st.LastError == "" {
+		t.Error("应记录最近一次投递失败原因")
+	}
+
+	// 仍不可用：重放失败的记录写回死信文件
+	s.ReplayDeadLetter(context.Background())
+	if st := s.Stats(); st.DeadLetterSize != 3 {
+		t.Errorf("重放失败后 DeadLetterSize = %d, 期望 3", st.DeadLetterSize)
+	}
+	if _, err := os.Stat(s.cfg.DeadLetterPath + ".replaying"); !os.IsNotExist(err) {
+		t.Errorf(".replaying 文件应已删除, err=%v", err)
+	}
+
+	// 恢复后重放成功，死信文件清空
+	p.setDown(false)
+	s.ReplayDeadLetter(context.Background())
+	if got := p.delivered(); len(got) != 3 || got[0].Seq != 1 || got[2].Seq != 3 {
+		t.Fatalf("重放投递 = %+v", got)
+	}
+	st = s.Stats()
+	if st.DeadLetterSize != 0 || st.LagEvents != 0 || st.Delivered != 3 {
+		t.Errorf("重放成功后 Stats = %+v", st)
+	}
+	if _, err := os.Stat(s.cfg.DeadLetterPath); !os.IsNotExist(err) {
+		t.Errorf("死信文件应已清空, err=%v", err)
+	}
+}
+
+func TestSink_BufferOverflowDeadLetters(t *testing.T) {
+	p := newFakeProducer()
+	s := newTestSink(t, p, Config{BufferSize: 2})
+
+	s.Enqueue(testEvents("run-1", 5))
+	st := s.Stats()
+	if st.DeadLettered != 3 || st.LagEvents != 5 {
+		t.Errorf("缓冲区已满时超出部分应写入死信文件, Stats = %+v", st)
+	}
+}
+
+func TestSink_RecoversDeadLetterOnRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.jsonl")
+	p := newFakeProducer()
+	p.setDown(true)
+	s := newTestSink(t, p, Config{DeadLetterPath: path, MaxRetries: 1})
+	s.Enqueue(testEvents("run-1", 2))
+	s.deliver(context.Background(), drainBuffer(s))
+
+	// 模拟重放中途退出：遗留 .replaying 文件，外加一行写了一半的记录
+	if err := os.Rename(path, path+".replaying"); err != nil {
+		t.Fatal(err)
+	}
+	f, err := os.OpenFile(path+".replaying", os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Fprint(f, `{"run_id":"run-1","se`)
+	f.Close()
+
+	p2 := newFakeProducer()
+	s2 := newTestSink(t, p2, Config{DeadLetterPath: path})
+	if st := s2.Stats(); st.DeadLetterSize != 2 {
+		t.Errorf("重启后应恢复死信统计, DeadLetterSize = %d", st.DeadLetterSize)
+	}
+	s2.ReplayDeadLetter(context.Background())
+	if got := p2.delivered(); len(got) != 2 {
+		t.Errorf("应重放遗留的 .replaying 文件, delivered = %+v", got)
+	}
+}
+
+func TestSink_DrainOnShutdown(t *testing.T) {
+	p := newFakeProducer()
+	p.setDown(true)
+	s := newTestSink(t, p, Config{FlushInterval: time.Hour})
+	s.Enqueue(testEvents("run-1", 4))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Start(ctx)
+
+	if st := s.Stats(); st.DeadLetterSize != 4 {
+		t.Errorf("退出时投递失败的剩余事件应写入死信文件, Stats = %+v", st)
+	}
+}
+
+// eventStore 记录写入的事件，fail 为 true 时写入失败
+type eventStore struct {
+	storage.PersistentStore
+	fail bool
+}
+
+func (m *eventStore) CreateEvents(_ context.Context, _ []*model.Event) error {
+	if m.fail {
+		return errors.New("db down")
+	}
+	return nil
+}
+
+func TestWrapStore_EnqueuesAfterCommit(t *testing.T) {
+	s := newTestSink(t, newFakeProducer(), Config{})
+	db := &eventStore{fail: true}
+	store := WrapStore(db, s)
+
+	if err := store.CreateEvents(context.Background(), testEvents("run-1", 2)); err == nil {
+		t.Fatal("期望返回写入错误")
+	}
+	if n := len(s.buf); n != 0 {
+		t.Errorf("写入失败的事件不应镜像, buffered=%d", n)
+	}
+
+	db.fail = false
+	if err := store.CreateEvents(context.Background(), testEvents("run-1", 2)); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(s.buf); n != 2 {
+		t.Errorf("buffered = %d, 期望 2", n)
+	}
+}
+
+func drainBuffer(s *Sink) []item {
+	var batch []item
+	for len(s.buf) > 0 {
+		batch = append(batch, <-s.buf)
+	}
+	return batch
+}
@@ -0,0 +1,61 @@
+package eventsink
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// mirroringStore 在事件写入数据库成功后将其加入 Sink 投递缓冲
+//
+// 事件上报（HTTP 与节点网关）都通过存储层写入，在存储层统一镜像可以覆盖所有事件来源。
+type mirroringStore struct {
+	storage.PersistentStore
+	sink *Sink
+}
+
+// WrapStore 包装存储层，使写入成功的 Run 事件镜像到 Sink
+func WrapStore(store storage.PersistentStore, sink *Sink) storage.PersistentStore {
+	return &mirroringStore{PersistentStore: store, sink: sink}
+}
+
+func (s *mirroringStore) CreateEvents(ctx context.Context, events []*model.Event) error {
+	if err := s.PersistentStore.CreateEvents(ctx, events); err != nil {
+		return err
+	}
+	s.sink.Enqueue(events)
+	return nil
+}
+
+// DatabaseSize 透传底层存储的空间统计（管理后台总览通过类型断言调用）
+func (s *mirroringStore) DatabaseSize(ctx context.Context) (int64, error) {
+	if sizer, ok := s.PersistentStore.(interface {
+		DatabaseSize(ctx context.Context) (int64, error)
+	}); ok {
+		return sizer.DatabaseSize(ctx)
+	}
+	return 0, errors.ErrUnsupported
+}
+
+// ReplicaStatus 透传底层存储的只读副本状态（管理后台总览通过类型断言调用）
+func (s *mirroringStore) ReplicaStatus() *storage.ReplicaStatus {
+	if r, ok := s.PersistentStore.(interface {
+		ReplicaStatus() *storage.ReplicaStatus
+	}); ok {
+		return r.ReplicaStatus()
+	}
+	return nil
+}
+
+// PoolStats 透传底层存储的连接池统计（指标导出通过类型断言调用）
+func (s *mirroringStore) PoolStats() map[string]sql.DBStats {
+	if p, ok := s.PersistentStore.(interface {
+		PoolStats() map[string]sql.DBStats
+	}); ok {
+		return p.PoolStats()
+	}
+	return nil
+}
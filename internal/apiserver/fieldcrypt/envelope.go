@@ -0,0 +1,179 @@
+// Package fieldcrypt 敏感字段静态加密（信封加密）
+//
+// 代理密码、API Key 认证操作配置中的 api_key、Git 凭据与运行时密钥等敏感字段在存储层透明加解密（见 WrapStore）：
+//   - 每个字段值使用随机生成的数据密钥（DEK，AES-256-GCM）加密
+//   - DEK 由密钥加密密钥（KEK）包装后与密文一起保存；KEK 来自配置（本地密钥）或 KMS（Vault Transit）
+//   - 密文格式：enc:v1:<KEK 标识>:base64(包装后的 DEK):base64(nonce || ciphertext || tag)
+//   - 没有前缀的值视为启用加密前写入的明文，读取时原样返回，由 Reencrypt 迁移
+//
+// 轮换 KEK 时把旧 KEK 保留为历史密钥，Reencrypt 用当前 KEK 重新加密旧 KEK 包装的字段。
+// 凭据与密钥在迁移前以 MASTER_KEY 直接加密（secretbox，v1: 前缀），设置 SetLegacyBox 后可以读取，由 Reencrypt 迁移。
+package fieldcrypt
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agents-admin/internal/shared/secretbox"
+)
+
+// sealedPrefix 密文前缀
+const sealedPrefix = "enc:v1:"
+
+// dekSize 数据密钥长度（AES-256）
+const dekSize = 32
+
+// ErrUnknownKEK 密文使用的 KEK 不在密钥环中
+var ErrUnknownKEK = errors.New("unknown key encryption key")
+
+// ErrNoLegacyKey 读取到迁移前的 secretbox 密文，但没有配置 MASTER_KEY
+var ErrNoLegacyKey = errors.New("legacy ciphertext requires MASTER_KEY")
+
+// KEK 密钥加密密钥，包装与解包数据密钥
+type KEK interface {
+	// ID KEK 标识，写入密文，解密时据此选择 KEK（不能包含冒号）
+	ID() string
+	Wrap(ctx context.Context, dek []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+// Keyring 当前 KEK 与历史 KEK
+//
+// 加密总是使用当前 KEK；解密按密文中的 KEK 标识选择，历史 KEK 只用于解密。
+type Keyring struct {
+	primary KEK
+	keys    map[string]KEK
+	legacy  *secretbox.Box // 迁移前凭据与密钥使用的 MASTER_KEY 加密器，只用于解密，未设置时为 nil
+}
+
+// NewKeyring 创建密钥环，previous 为轮换前的历史 KEK
+func NewKeyring(primary KEK, previous ...KEK) (*Keyring, error) {
+	k := &Keyring{primary: primary, keys: make(map[string]KEK, len(previous)+1)}
+	for _, kek := range append([]KEK{primary}, previous...) {
+		id := kek.ID()
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("invalid key encryption key id %q", id)
+		}
+		if _, dup := k.keys[id]; !dup {
+			k.keys[id] = kek
+		}
+	}
+	return k, nil
+}
+
+// SetLegacyBox 设置迁移前凭据与密钥使用的 MASTER_KEY 加密器（secretbox 密文只解密，写入时总是信封加密）
+func (k *Keyring) SetLegacyBox(box *secretbox.Box) {
+	k.legacy = box
+}
+
+// PrimaryID 当前 KEK 标识
+func (k *Keyring) PrimaryID() string {
+	return k.primary.ID()
+}
+
+// IsSealed 值是否为 Seal 生成的密文
+func IsSealed(value string) bool {
+	return strings.HasPrefix(value, sealedPrefix)
+}
+
+// kekID 密文使用的 KEK 标识，明文返回空
+func kekID(value string) string {
+	if !IsSealed(value) {
+		return ""
+	}
+	id, _, _ := strings.Cut(strings.TrimPrefix(value, sealedPrefix), ":")
+	return id
+}
+
+// Stale 值是否需要重新加密：明文或由历史 KEK 包装
+func (k *Keyring) Stale(value string) bool {
+	return kekID(value) != k.primary.ID()
+}
+
+// Seal 使用新的数据密钥加密明文，数据密钥由当前 KEK 包装
+func (k *Keyring) Seal(ctx context.Context, plaintext string) (string, error) {
+	dek := make([]byte, dekSize)
+	if _, err := rand.Read(dek); err != nil {
+		return "", err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := k.primary.Wrap(ctx, dek)
+	if err != nil {
+		return "", fmt.Errorf("wrap data key: %w", err)
+	}
+	id := k.primary.ID()
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, []byte(plaintext), []byte(id))
+	return sealedPrefix + id + ":" + base64.StdEncoding.EncodeToString(wrapped) + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Open 解密 Seal 生成的密文；不带密文前缀的值视为明文原样返回
+func (k *Keyring) Open(ctx context.Context, value string) (string, error) {
+	if !IsSealed(value) {
+		return value, nil
+	}
+	parts := strings.Split(strings.TrimPrefix(value, sealedPrefix), ":")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("invalid ciphertext format")
+	}
+	kek, ok := k.keys[parts[0]]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrUnknownKEK, parts[0])
+	}
+	wrapped, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("invalid wrapped data key: %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	dek, err := kek.Unwrap(ctx, wrapped)
+	if err != nil {
+		return "", fmt.Errorf("unwrap data key: %w", err)
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < aead.NonceSize() {
+		return "", fmt.Errorf("invalid ciphertext: too short")
+	}
+	plain, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], []byte(parts[0]))
+	if err != nil {
+		return "", fmt.Errorf("decrypt failed: %w", err)
+	}
+	return string(plain), nil
+}
+
+// openSecret 解密凭据与密钥：迁移前的 secretbox 密文用 MASTER_KEY 解密，其余同 Open
+func (k *Keyring) openSecret(ctx context.Context, value string) (string, error) {
+	if !secretbox.IsCiphertext(value) {
+		return k.Open(ctx, value)
+	}
+	if k.legacy == nil {
+		return "", ErrNoLegacyKey
+	}
+	return k.legacy.Decrypt(value)
+}
+
+// newAEAD 以 AES-256-GCM 包装密钥
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
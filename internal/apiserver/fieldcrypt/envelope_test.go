@@ -0,0 +1,125 @@
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func mustLocalKEK(t *testing.T, secret string) KEK {
+	t.Helper()
+	kek, err := NewLocalKEK(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return kek
+}
+
+func mustKeyring(t *testing.T, primary KEK, previous ...KEK) *Keyring {
+	t.Helper()
+	keys, err := NewKeyring(primary, previous...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keys
+}
+
+func TestKeyring_SealOpen(t *testing.T) {
+	ctx := context.Background()
+	keys := mustKeyring(t, mustLocalKEK(t, "kek-1"))
+
+	a, err := keys.Seal(ctx, "p@ss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := keys.Seal(ctx, "p@ss")
+	if !IsSealed(a) || a == b || strings.Contains(a, "p@ss") {
+		t.Fatalf("密文应带前缀、每次使用新的数据密钥且不包含明文: %q", a)
+	}
+	if plain, err := keys.Open(ctx, a); err != nil || plain != "p@ss" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if plain, err := keys.Open(ctx, "legacy"); err != nil || plain != "legacy" {
+		t.Errorf("明文应原样返回: %q, %v", plain, err)
+	}
+
+	// 篡改 KEK 标识后 AAD 不匹配
+	tampered := strings.Replace(a, keys.PrimaryID(), "local-000000000000", 1)
+	if _, err := mustKeyring(t, mustLocalKEK(t, "kek-2")).Open(ctx, a); !errors.Is(err, ErrUnknownKEK) {
+		t.Errorf("其他密钥环解密 err = %v, 期望 ErrUnknownKEK", err)
+	}
+	if _, err := keys.Open(ctx, tampered); err == nil {
+		t.Error("篡改的密文应解密失败")
+	}
+}
+
+// TestKeyring_Rotation 轮换后历史 KEK 仍能解密，历史 KEK 加密的值需要重新加密
+func TestKeyring_Rotation(t *testing.T) {
+	ctx := context.Background()
+	oldKEK := mustLocalKEK(t, "kek-old")
+	sealed, _ := mustKeyring(t, oldKEK).Seal(ctx, "secret")
+
+	rotated := mustKeyring(t, mustLocalKEK(t, "kek-new"), oldKEK)
+	if plain, err := rotated.Open(ctx, sealed); err != nil || plain != "secret" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if !rotated.Stale(sealed) || !rotated.Stale("plain") {
+		t.Error("历史 KEK 加密的值与明文应需要重新加密")
+	}
+	fresh, _ := rotated.Seal(ctx, "secret")
+	if rotated.Stale(fresh) {
+		t.Error("当前 KEK 加密的值不需要重新加密")
+	}
+}
+
+// TestVaultKEK 数据密钥由 Vault Transit 包装
+func TestVaultKEK(t *testing.T) {
+	var calls []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "vt" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		calls = append(calls, r.URL.Path)
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/agents":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/agents":
+			json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	kek, err := NewVaultKEK(VaultConfig{Address: srv.URL + "/", Token: "vt", KeyName: "agents"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := mustKeyring(t, kek)
+	ctx := context.Background()
+	sealed, err := keys.Seal(ctx, "api-key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, "enc:v1:vault-agents:") {
+		t.Errorf("sealed = %q", sealed)
+	}
+	if plain, err := keys.Open(ctx, sealed); err != nil || plain != "api-key" {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if len(calls) != 2 {
+		t.Errorf("calls = %v", calls)
+	}
+
+	denied, _ := NewVaultKEK(VaultConfig{Address: srv.URL, Token: "wrong", KeyName: "agents"})
+	if _, err := mustKeyring(t, denied).Seal(ctx, "x"); err == nil || !strings.Contains(err.Error(), "status 403") {
+		t.Errorf("err = %v", err)
+	}
+}
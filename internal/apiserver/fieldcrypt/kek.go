@@ -0,0 +1,158 @@
+package fieldcrypt
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// localKEK 配置中的本地 KEK（任意长度，经 SHA-256 派生为 32 字节 AES 密钥）
+type localKEK struct {
+	id  string
+	key []byte
+}
+
+// NewLocalKEK 使用配置的密钥创建本地 KEK
+//
+// 标识由密钥指纹派生（local-<前 12 位十六进制>），轮换后旧密文仍能找到对应的历史密钥。
+func NewLocalKEK(secret string) (KEK, error) {
+	if secret == "" {
+		return nil, fmt.Errorf("local key encryption key is empty")
+	}
+	key := sha256.Sum256([]byte(secret))
+	fp := sha256.Sum256(key[:])
+	return &localKEK{id: "local-" + hex.EncodeToString(fp[:])[:12], key: key[:]}, nil
+}
+
+func (k *localKEK) ID() string { return k.id }
+
+func (k *localKEK) Wrap(_ context.Context, dek []byte) ([]byte, error) {
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dek, nil), nil
+}
+
+func (k *localKEK) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	aead, err := newAEAD(k.key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, fmt.Errorf("wrapped data key too short")
+	}
+	dek, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("wrong key encryption key: %w", err)
+	}
+	return dek, nil
+}
+
+// VaultConfig Vault Transit 密钥配置
+type VaultConfig struct {
+	Address string        // Vault 地址，如 https://vault.example.com:8200
+	Token   string        // 访问令牌（需要 transit 密钥的 encrypt/decrypt 权限）
+	Mount   string        // Transit 引擎挂载路径，默认 transit
+	KeyName string        // Transit 密钥名称
+	Timeout time.Duration // 单次请求超时，默认 10 秒
+}
+
+// vaultKEK 由 Vault Transit 包装数据密钥，KEK 不离开 Vault
+type vaultKEK struct {
+	cfg    VaultConfig
+	client *http.Client
+}
+
+// NewVaultKEK 创建 Vault Transit KEK，标识为 vault-<密钥名称>
+//
+// Transit 密钥在 Vault 中轮换版本，密文自带版本号，旧版本包装的数据密钥仍能解包。
+func NewVaultKEK(cfg VaultConfig) (KEK, error) {
+	if cfg.Address == "" || cfg.KeyName == "" {
+		return nil, fmt.Errorf("vault address and key_name are required")
+	}
+	if cfg.Token == "" {
+		return nil, fmt.Errorf("vault token is required")
+	}
+	if strings.ContainsAny(cfg.KeyName, ":/") {
+		return nil, fmt.Errorf("invalid vault key name %q", cfg.KeyName)
+	}
+	if cfg.Mount == "" {
+		cfg.Mount = "transit"
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	cfg.Address = strings.TrimRight(cfg.Address, "/")
+	cfg.Mount = strings.Trim(cfg.Mount, "/")
+	return &vaultKEK{cfg: cfg, client: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+func (k *vaultKEK) ID() string { return "vault-" + k.cfg.KeyName }
+
+func (k *vaultKEK) Wrap(ctx context.Context, dek []byte) ([]byte, error) {
+	var out struct {
+		Ciphertext string `json:"ciphertext"`
+	}
+	if err := k.call(ctx, "encrypt", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dek)}, &out); err != nil {
+		return nil, err
+	}
+	return []byte(out.Ciphertext), nil
+}
+
+func (k *vaultKEK) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Plaintext string `json:"plaintext"`
+	}
+	if err := k.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)}, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Plaintext)
+}
+
+// call 调用 Transit 接口（POST /v1/<mount>/<op>/<key>），解析响应的 data 字段
+func (k *vaultKEK) call(ctx context.Context, op string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", k.cfg.Address, k.cfg.Mount, op, k.cfg.KeyName)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", k.cfg.Token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault %s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("vault %s: %w", op, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("vault %s: status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return fmt.Errorf("vault %s: invalid response: %w", op, err)
+	}
+	return json.Unmarshal(envelope.Data, out)
+}
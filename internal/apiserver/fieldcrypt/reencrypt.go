@@ -0,0 +1,166 @@
+package fieldcrypt
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"agents-admin/internal/shared/storage"
+)
+
+// reencryptPageSize 分页读取操作记录的批大小
+const reencryptPageSize = 200
+
+// ReencryptOptions 重新加密选项
+type ReencryptOptions struct {
+	All    bool // 重新加密所有字段（Vault 密钥轮换版本后重新包装），默认只处理明文与历史 KEK 加密的字段
+	DryRun bool // 只统计需要重新加密的记录，不写入
+}
+
+// ReencryptStats 重新加密结果
+type ReencryptStats struct {
+	Proxies     int `json:"proxies"`     // 重新加密的代理数
+	Operations  int `json:"operations"`  // 重新加密的操作数
+	Credentials int `json:"credentials"` // 重新加密的 Git 凭据数
+	Secrets     int `json:"secrets"`     // 重新加密的运行时密钥数
+	Skipped     int `json:"skipped"`     // 已由当前 KEK 加密而跳过的记录数
+}
+
+// Reencrypt 用当前 KEK 重新加密已有记录的敏感字段
+//
+// store 必须是未经 WrapStore 包装的存储：启用加密前写入的明文、历史 KEK 加密的字段与迁移前以 MASTER_KEY 加密的
+// 凭据和密钥（需要 SetLegacyBox）在这里解密后重新加密写回。可重复执行，已由当前 KEK 加密的记录跳过。
+func Reencrypt(ctx context.Context, store storage.PersistentStore, keys *Keyring, opts ReencryptOptions) (ReencryptStats, error) {
+	var stats ReencryptStats
+	stale := func(v string) bool { return opts.All || keys.Stale(v) }
+	reseal := func(v string) (string, error) {
+		plain, err := keys.Open(ctx, v)
+		if err != nil {
+			return "", err
+		}
+		return keys.Seal(ctx, plain)
+	}
+	// 凭据与密钥可能是迁移前的 secretbox 密文
+	resealSecret := func(v string) (string, error) {
+		plain, err := keys.openSecret(ctx, v)
+		if err != nil {
+			return "", err
+		}
+		return keys.Seal(ctx, plain)
+	}
+
+	proxies, err := store.ListProxies(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("list proxies: %w", err)
+	}
+	for _, proxy := range proxies {
+		if proxy.Password == nil || *proxy.Password == "" {
+			continue
+		}
+		if !stale(*proxy.Password) {
+			stats.Skipped++
+			continue
+		}
+		sealed, err := reseal(*proxy.Password)
+		if err != nil {
+			return stats, fmt.Errorf("proxy %s: %w", proxy.ID, err)
+		}
+		if !opts.DryRun {
+			proxy.Password = &sealed
+			if err := store.UpdateProxy(ctx, proxy); err != nil {
+				return stats, fmt.Errorf("update proxy %s: %w", proxy.ID, err)
+			}
+		}
+		stats.Proxies++
+		slog.InfoContext(ctx, "fieldcrypt.reencrypt", "proxy_id", proxy.ID, "dry_run", opts.DryRun)
+	}
+
+	for offset := 0; ; offset += reencryptPageSize {
+		ops, err := store.ListOperations(ctx, "", "", reencryptPageSize, offset)
+		if err != nil {
+			return stats, fmt.Errorf("list operations: %w", err)
+		}
+		for _, op := range ops {
+			hasSecret := false
+			config, changed, err := transformConfig(op.Config, func(v string) (string, error) {
+				hasSecret = true
+				if !stale(v) {
+					return v, nil
+				}
+				return reseal(v)
+			})
+			if err != nil {
+				return stats, fmt.Errorf("operation %s: %w", op.ID, err)
+			}
+			if !changed {
+				if hasSecret {
+					stats.Skipped++
+				}
+				continue
+			}
+			if !opts.DryRun {
+				if err := store.UpdateOperationConfig(ctx, op.ID, config); err != nil {
+					return stats, fmt.Errorf("update operation %s: %w", op.ID, err)
+				}
+			}
+			stats.Operations++
+			slog.InfoContext(ctx, "fieldcrypt.reencrypt", "operation_id", op.ID, "dry_run", opts.DryRun)
+		}
+		if len(ops) < reencryptPageSize {
+			break
+		}
+	}
+
+	creds, err := store.ListCredentials(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("list credentials: %w", err)
+	}
+	for _, cred := range creds {
+		if cred.EncryptedSecret == "" {
+			continue
+		}
+		if !stale(cred.EncryptedSecret) {
+			stats.Skipped++
+			continue
+		}
+		sealed, err := resealSecret(cred.EncryptedSecret)
+		if err != nil {
+			return stats, fmt.Errorf("credential %s: %w", cred.ID, err)
+		}
+		if !opts.DryRun {
+			cred.EncryptedSecret = sealed
+			if err := store.UpdateCredential(ctx, cred); err != nil {
+				return stats, fmt.Errorf("update credential %s: %w", cred.ID, err)
+			}
+		}
+		stats.Credentials++
+		slog.InfoContext(ctx, "fieldcrypt.reencrypt", "credential_id", cred.ID, "dry_run", opts.DryRun)
+	}
+
+	secrets, err := store.ListSecrets(ctx)
+	if err != nil {
+		return stats, fmt.Errorf("list secrets: %w", err)
+	}
+	for _, secret := range secrets {
+		if secret.EncryptedValue == "" {
+			continue
+		}
+		if !stale(secret.EncryptedValue) {
+			stats.Skipped++
+			continue
+		}
+		sealed, err := resealSecret(secret.EncryptedValue)
+		if err != nil {
+			return stats, fmt.Errorf("secret %s: %w", secret.ID, err)
+		}
+		if !opts.DryRun {
+			secret.EncryptedValue = sealed
+			if err := store.UpdateSecret(ctx, secret); err != nil {
+				return stats, fmt.Errorf("update secret %s: %w", secret.ID, err)
+			}
+		}
+		stats.Secrets++
+		slog.InfoContext(ctx, "fieldcrypt.reencrypt", "secret_id", secret.ID, "dry_run", opts.DryRun)
+	}
+	return stats, nil
+}
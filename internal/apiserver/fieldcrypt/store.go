@@ -0,0 +1,363 @@
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// operationSecretFields 操作配置（operations.config）中加密保存的字段
+var operationSecretFields = []string{"api_key"}
+
+// sealingStore 写入时加密敏感字段、读取时解密的存储包装
+type sealingStore struct {
+	storage.PersistentStore
+	keys *Keyring
+}
+
+// WrapStore 包装存储层，使代理密码、操作配置中的 api_key、Git 凭据（encrypted_secret）与运行时密钥（encrypted_value）
+// 加密保存，调用方读写的仍是明文
+//
+// 写入的是副本，调用方持有的对象不变。调用方写入的值总是加密，即使形如密文（enc:v1: 前缀）也不例外，
+// 客户端提交的这类字符串读取时原样还原，不会当作密文保存后无法解密。读取到无法解密的字段（KEK 缺失或密文损坏）时返回错误，
+// 不会把密文当作明文交给调用方。
+func WrapStore(store storage.PersistentStore, keys *Keyring) storage.PersistentStore {
+	return &sealingStore{PersistentStore: store, keys: keys}
+}
+
+// ============================================================================
+// ProxyStore
+// ============================================================================
+
+func (s *sealingStore) CreateProxy(ctx context.Context, proxy *model.Proxy) error {
+	sealed, err := s.sealProxy(ctx, proxy)
+	if err != nil {
+		return err
+	}
+	return s.PersistentStore.CreateProxy(ctx, sealed)
+}
+
+func (s *sealingStore) UpdateProxy(ctx context.Context, proxy *model.Proxy) error {
+	sealed, err := s.sealProxy(ctx, proxy)
+	if err != nil {
+		return err
+	}
+	return s.PersistentStore.UpdateProxy(ctx, sealed)
+}
+
+func (s *sealingStore) GetProxy(ctx context.Context, id string) (*model.Proxy, error) {
+	proxy, err := s.PersistentStore.GetProxy(ctx, id)
+	if err != nil || proxy == nil {
+		return proxy, err
+	}
+	return proxy, s.openProxy(ctx, proxy)
+}
+
+func (s *sealingStore) GetDefaultProxy(ctx context.Context) (*model.Proxy, error) {
+	proxy, err := s.PersistentStore.GetDefaultProxy(ctx)
+	if err != nil || proxy == nil {
+		return proxy, err
+	}
+	return proxy, s.openProxy(ctx, proxy)
+}
+
+func (s *sealingStore) ListProxies(ctx context.Context) ([]*model.Proxy, error) {
+	proxies, err := s.PersistentStore.ListProxies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, proxy := range proxies {
+		if err := s.openProxy(ctx, proxy); err != nil {
+			return nil, err
+		}
+	}
+	return proxies, nil
+}
+
+// sealProxy 返回密码已加密的副本
+func (s *sealingStore) sealProxy(ctx context.Context, proxy *model.Proxy) (*model.Proxy, error) {
+	if proxy.Password == nil || *proxy.Password == "" {
+		return proxy, nil
+	}
+	sealed, err := s.keys.Seal(ctx, *proxy.Password)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt proxy %s password: %w", proxy.ID, err)
+	}
+	out := *proxy
+	out.Password = &sealed
+	return &out, nil
+}
+
+func (s *sealingStore) openProxy(ctx context.Context, proxy *model.Proxy) error {
+	if proxy.Password == nil {
+		return nil
+	}
+	plain, err := s.keys.Open(ctx, *proxy.Password)
+	if err != nil {
+		return fmt.Errorf("decrypt proxy %s password: %w", proxy.ID, err)
+	}
+	proxy.Password = &plain
+	return nil
+}
+
+// ============================================================================
+// OperationStore / ActionStore
+// ============================================================================
+
+func (s *sealingStore) CreateOperation(ctx context.Context, op *model.Operation) error {
+	config, _, err := transformConfig(op.Config, func(v string) (string, error) {
+		return s.keys.Seal(ctx, v)
+	})
+	if err != nil {
+		return fmt.Errorf("encrypt operation %s config: %w", op.ID, err)
+	}
+	out := *op
+	out.Config = config
+	return s.PersistentStore.CreateOperation(ctx, &out)
+}
+
+func (s *sealingStore) UpdateOperationConfig(ctx context.Context, id string, config json.RawMessage) error {
+	sealed, _, err := transformConfig(config, func(v string) (string, error) {
+		return s.keys.Seal(ctx, v)
+	})
+	if err != nil {
+		return fmt.Errorf("encrypt operation %s config: %w", id, err)
+	}
+	return s.PersistentStore.UpdateOperationConfig(ctx, id, sealed)
+}
+
+func (s *sealingStore) GetOperation(ctx context.Context, id string) (*model.Operation, error) {
+	op, err := s.PersistentStore.GetOperation(ctx, id)
+	if err != nil || op == nil {
+		return op, err
+	}
+	return op, s.openOperation(ctx, op)
+}
+
+func (s *sealingStore) ListOperations(ctx context.Context, opType string, status string, limit, offset int) ([]*model.Operation, error) {
+	ops, err := s.PersistentStore.ListOperations(ctx, opType, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	for _, op := range ops {
+		if err := s.openOperation(ctx, op); err != nil {
+			return nil, err
+		}
+	}
+	return ops, nil
+}
+
+func (s *sealingStore) GetActionWithOperation(ctx context.Context, id string) (*model.Action, error) {
+	action, err := s.PersistentStore.GetActionWithOperation(ctx, id)
+	if err != nil || action == nil || action.Operation == nil {
+		return action, err
+	}
+	return action, s.openOperation(ctx, action.Operation)
+}
+
+func (s *sealingStore) ListActionsByNode(ctx context.Context, nodeID string, status string) ([]*model.Action, error) {
+	actions, err := s.PersistentStore.ListActionsByNode(ctx, nodeID, status)
+	if err != nil {
+		return nil, err
+	}
+	for _, action := range actions {
+		if action.Operation == nil {
+			continue
+		}
+		if err := s.openOperation(ctx, action.Operation); err != nil {
+			return nil, err
+		}
+	}
+	return actions, nil
+}
+
+func (s *sealingStore) openOperation(ctx context.Context, op *model.Operation) error {
+	config, _, err := transformConfig(op.Config, func(v string) (string, error) {
+		return s.keys.Open(ctx, v)
+	})
+	if err != nil {
+		return fmt.Errorf("decrypt operation %s config: %w", op.ID, err)
+	}
+	op.Config = config
+	return nil
+}
+
+// ============================================================================
+// CredentialStore / SecretStore
+// ============================================================================
+
+func (s *sealingStore) CreateCredential(ctx context.Context, cred *model.Credential) error {
+	sealed, err := s.sealCredential(ctx, cred)
+	if err != nil {
+		return err
+	}
+	return s.PersistentStore.CreateCredential(ctx, sealed)
+}
+
+func (s *sealingStore) UpdateCredential(ctx context.Context, cred *model.Credential) error {
+	sealed, err := s.sealCredential(ctx, cred)
+	if err != nil {
+		return err
+	}
+	return s.PersistentStore.UpdateCredential(ctx, sealed)
+}
+
+func (s *sealingStore) GetCredential(ctx context.Context, id string) (*model.Credential, error) {
+	cred, err := s.PersistentStore.GetCredential(ctx, id)
+	if err != nil || cred == nil {
+		return cred, err
+	}
+	return cred, s.openCredential(ctx, cred)
+}
+
+func (s *sealingStore) GetCredentialByName(ctx context.Context, name string) (*model.Credential, error) {
+	cred, err := s.PersistentStore.GetCredentialByName(ctx, name)
+	if err != nil || cred == nil {
+		return cred, err
+	}
+	return cred, s.openCredential(ctx, cred)
+}
+
+func (s *sealingStore) ListCredentials(ctx context.Context) ([]*model.Credential, error) {
+	creds, err := s.PersistentStore.ListCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, cred := range creds {
+		if err := s.openCredential(ctx, cred); err != nil {
+			return nil, err
+		}
+	}
+	return creds, nil
+}
+
+// sealCredential 返回凭据内容已加密的副本
+func (s *sealingStore) sealCredential(ctx context.Context, cred *model.Credential) (*model.Credential, error) {
+	if cred.EncryptedSecret == "" {
+		return cred, nil
+	}
+	sealed, err := s.keys.Seal(ctx, cred.EncryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt credential %s: %w", cred.ID, err)
+	}
+	out := *cred
+	out.EncryptedSecret = sealed
+	return &out, nil
+}
+
+func (s *sealingStore) openCredential(ctx context.Context, cred *model.Credential) error {
+	plain, err := s.keys.openSecret(ctx, cred.EncryptedSecret)
+	if err != nil {
+		return fmt.Errorf("decrypt credential %s: %w", cred.ID, err)
+	}
+	cred.EncryptedSecret = plain
+	return nil
+}
+
+func (s *sealingStore) CreateSecret(ctx context.Context, secret *model.Secret) error {
+	sealed, err := s.sealSecret(ctx, secret)
+	if err != nil {
+		return err
+	}
+	return s.PersistentStore.CreateSecret(ctx, sealed)
+}
+
+func (s *sealingStore) UpdateSecret(ctx context.Context, secret *model.Secret) error {
+	sealed, err := s.sealSecret(ctx, secret)
+	if err != nil {
+		return err
+	}
+	return s.PersistentStore.UpdateSecret(ctx, sealed)
+}
+
+func (s *sealingStore) GetSecret(ctx context.Context, id string) (*model.Secret, error) {
+	secret, err := s.PersistentStore.GetSecret(ctx, id)
+	if err != nil || secret == nil {
+		return secret, err
+	}
+	return secret, s.openSecret(ctx, secret)
+}
+
+func (s *sealingStore) GetSecretByName(ctx context.Context, name string) (*model.Secret, error) {
+	secret, err := s.PersistentStore.GetSecretByName(ctx, name)
+	if err != nil || secret == nil {
+		return secret, err
+	}
+	return secret, s.openSecret(ctx, secret)
+}
+
+func (s *sealingStore) ListSecrets(ctx context.Context) ([]*model.Secret, error) {
+	secrets, err := s.PersistentStore.ListSecrets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, secret := range secrets {
+		if err := s.openSecret(ctx, secret); err != nil {
+			return nil, err
+		}
+	}
+	return secrets, nil
+}
+
+// sealSecret 返回密钥值已加密的副本
+func (s *sealingStore) sealSecret(ctx context.Context, secret *model.Secret) (*model.Secret, error) {
+	if secret.EncryptedValue == "" {
+		return secret, nil
+	}
+	sealed, err := s.keys.Seal(ctx, secret.EncryptedValue)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt secret %s: %w", secret.ID, err)
+	}
+	out := *secret
+	out.EncryptedValue = sealed
+	return &out, nil
+}
+
+func (s *sealingStore) openSecret(ctx context.Context, secret *model.Secret) error {
+	plain, err := s.keys.openSecret(ctx, secret.EncryptedValue)
+	if err != nil {
+		return fmt.Errorf("decrypt secret %s: %w", secret.ID, err)
+	}
+	secret.EncryptedValue = plain
+	return nil
+}
+
+// transformConfig 对操作配置中的敏感字段应用 fn，返回新配置及是否有字段变化
+//
+// 配置不是 JSON 对象、敏感字段缺失或不是非空字符串时原样返回。
+func transformConfig(config json.RawMessage, fn func(string) (string, error)) (json.RawMessage, bool, error) {
+	if len(config) == 0 {
+		return config, false, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(config, &fields); err != nil {
+		return config, false, nil
+	}
+	changed := false
+	for _, name := range operationSecretFields {
+		var value string
+		if raw, ok := fields[name]; !ok || json.Unmarshal(raw, &value) != nil || value == "" {
+			continue
+		}
+		next, err := fn(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("%s: %w", name, err)
+		}
+		if next == value {
+			continue
+		}
+		fields[name], _ = json.Marshal(next)
+		changed = true
+	}
+	if !changed {
+		return config, false, nil
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return nil, false, err
+	}
+	return out, true, nil
+}
@@ -0,0 +1,378 @@
+package fieldcrypt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secretbox"
+	"agents-admin/internal/shared/storage"
+)
+
+// memStore 内存代理、操作、凭据与密钥存储，保存写入的原始值
+type memStore struct {
+	storage.PersistentStore
+	proxies map[string]*model.Proxy
+	ops     []*model.Operation
+	creds   map[string]*model.Credential
+	secrets map[string]*model.Secret
+}
+
+func newMemStore() *memStore {
+	return &memStore{proxies: map[string]*model.Proxy{}, creds: map[string]*model.Credential{}, secrets: map[string]*model.Secret{}}
+}
+
+func (m *memStore) CreateProxy(_ context.Context, p *model.Proxy) error {
+	copied := *p
+	m.proxies[p.ID] = &copied
+	return nil
+}
+
+func (m *memStore) UpdateProxy(ctx context.Context, p *model.Proxy) error {
+	return m.CreateProxy(ctx, p)
+}
+
+func (m *memStore) GetProxy(_ context.Context, id string) (*model.Proxy, error) {
+	p, ok := m.proxies[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *p
+	return &copied, nil
+}
+
+func (m *memStore) ListProxies(ctx context.Context) ([]*model.Proxy, error) {
+	var out []*model.Proxy
+	for id := range m.proxies {
+		p, _ := m.GetProxy(ctx, id)
+		out = append(out, p)
+	}
+	return out, nil
+}
+
+func (m *memStore) CreateOperation(_ context.Context, op *model.Operation) error {
+	copied := *op
+	m.ops = append(m.ops, &copied)
+	return nil
+}
+
+func (m *memStore) GetOperation(_ context.Context, id string) (*model.Operation, error) {
+	for _, op := range m.ops {
+		if op.ID == id {
+			copied := *op
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memStore) ListOperations(_ context.Context, _, _ string, limit, offset int) ([]*model.Operation, error) {
+	var out []*model.Operation
+	for i := offset; i < len(m.ops) && len(out) < limit; i++ {
+		copied := *m.ops[i]
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func (m *memStore) UpdateOperationConfig(_ context.Context, id string, config json.RawMessage) error {
+	for _, op := range m.ops {
+		if op.ID == id {
+			op.Config = config
+		}
+	}
+	return nil
+}
+
+func (m *memStore) GetActionWithOperation(ctx context.Context, id string) (*model.Action, error) {
+	op, _ := m.GetOperation(ctx, "op-1")
+	return &model.Action{ID: id, OperationID: "op-1", Operation: op}, nil
+}
+
+func (m *memStore) CreateCredential(_ context.Context, c *model.Credential) error {
+	copied := *c
+	m.creds[c.ID] = &copied
+	return nil
+}
+
+func (m *memStore) UpdateCredential(ctx context.Context, c *model.Credential) error {
+	return m.CreateCredential(ctx, c)
+}
+
+func (m *memStore) GetCredentialByName(_ context.Context, name string) (*model.Credential, error) {
+	for _, c := range m.creds {
+		if c.Name == name {
+			copied := *c
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memStore) ListCredentials(_ context.Context) ([]*model.Credential, error) {
+	var out []*model.Credential
+	for _, c := range m.creds {
+		copied := *c
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func (m *memStore) CreateSecret(_ context.Context, s *model.Secret) error {
+	copied := *s
+	m.secrets[s.ID] = &copied
+	return nil
+}
+
+func (m *memStore) UpdateSecret(ctx context.Context, s *model.Secret) error {
+	return m.CreateSecret(ctx, s)
+}
+
+func (m *memStore) GetSecretByName(_ context.Context, name string) (*model.Secret, error) {
+	for _, s := range m.secrets {
+		if s.Name == name {
+			copied := *s
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *memStore) ListSecrets(_ context.Context) ([]*model.Secret, error) {
+	var out []*model.Secret
+	for _, s := range m.secrets {
+		copied := *s
+		out = append(out, &copied)
+	}
+	return out, nil
+}
+
+func apiKeyOf(t *testing.T, config json.RawMessage) string {
+	t.Helper()
+	var cfg model.APIKeyConfig
+	if err := json.Unmarshal(config, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	return cfg.APIKey
+}
+
+func TestWrapStore_Proxy(t *testing.T) {
+	ctx := context.Background()
+	raw := newMemStore()
+	store := WrapStore(raw, mustKeyring(t, mustLocalKEK(t, "kek")))
+
+	password, user := "p@ss", "u"
+	proxy := &model.Proxy{ID: "px-1", Username: &user, Password: &password}
+	if err := store.CreateProxy(ctx, proxy); err != nil {
+		t.Fatal(err)
+	}
+	if *proxy.Password != "p@ss" {
+		t.Error("调用方持有的对象不应被修改")
+	}
+	if stored := *raw.proxies["px-1"].Password; !IsSealed(stored) {
+		t.Fatalf("数据库中应为密文: %q", stored)
+	}
+
+	got, err := store.GetProxy(ctx, "px-1")
+	if err != nil || *got.Password != "p@ss" {
+		t.Fatalf("GetProxy = %+v, %v", got, err)
+	}
+	list, err := store.ListProxies(ctx)
+	if err != nil || len(list) != 1 || *list[0].Password != "p@ss" {
+		t.Fatalf("ListProxies = %v, %v", list, err)
+	}
+
+	// 使用其他 KEK 读取时报错，不返回密文
+	if _, err := WrapStore(raw, mustKeyring(t, mustLocalKEK(t, "other"))).GetProxy(ctx, "px-1"); err == nil {
+		t.Error("KEK 不匹配时应返回错误")
+	}
+}
+
+func TestWrapStore_OperationConfig(t *testing.T) {
+	ctx := context.Background()
+	raw := newMemStore()
+	store := WrapStore(raw, mustKeyring(t, mustLocalKEK(t, "kek")))
+
+	config := json.RawMessage(`{"name":"a","agent_type":"qwen","api_key":"sk-123"}`)
+	if err := store.CreateOperation(ctx, &model.Operation{ID: "op-1", Type: model.OperationTypeAPIKey, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	if stored := string(raw.ops[0].Config); strings.Contains(stored, "sk-123") || !IsSealed(apiKeyOf(t, raw.ops[0].Config)) {
+		t.Fatalf("api_key 应加密保存: %s", stored)
+	}
+
+	op, err := store.GetOperation(ctx, "op-1")
+	if err != nil || apiKeyOf(t, op.Config) != "sk-123" {
+		t.Fatalf("GetOperation = %s, %v", op.Config, err)
+	}
+	action, err := store.GetActionWithOperation(ctx, "act-1")
+	if err != nil || apiKeyOf(t, action.Operation.Config) != "sk-123" {
+		t.Fatalf("GetActionWithOperation = %+v, %v", action, err)
+	}
+
+	// 没有敏感字段的配置原样保存
+	plain := json.RawMessage(`{"name":"b","agent_type":"qwen"}`)
+	store.CreateOperation(ctx, &model.Operation{ID: "op-2", Type: model.OperationTypeOAuth, Config: plain})
+	if string(raw.ops[1].Config) != string(plain) {
+		t.Errorf("config = %s", raw.ops[1].Config)
+	}
+}
+
+// TestWrapStore_SealedLookingInput 客户端提交形如密文的值同样加密保存，读取时原样还原
+func TestWrapStore_SealedLookingInput(t *testing.T) {
+	ctx := context.Background()
+	raw := newMemStore()
+	store := WrapStore(raw, mustKeyring(t, mustLocalKEK(t, "kek")))
+
+	forged := "enc:v1:not-a-ciphertext"
+	if err := store.CreateProxy(ctx, &model.Proxy{ID: "px-1", Password: &forged}); err != nil {
+		t.Fatal(err)
+	}
+	if *raw.proxies["px-1"].Password == forged {
+		t.Fatal("形如密文的密码不应原样保存")
+	}
+	list, err := store.ListProxies(ctx)
+	if err != nil || len(list) != 1 || *list[0].Password != forged {
+		t.Fatalf("ListProxies = %v, %v", list, err)
+	}
+
+	config := json.RawMessage(`{"name":"a","agent_type":"qwen","api_key":"enc:v1:not-a-ciphertext"}`)
+	if err := store.CreateOperation(ctx, &model.Operation{ID: "op-1", Type: model.OperationTypeAPIKey, Config: config}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.UpdateOperationConfig(ctx, "op-1", config); err != nil {
+		t.Fatal(err)
+	}
+	op, err := store.GetOperation(ctx, "op-1")
+	if err != nil || apiKeyOf(t, op.Config) != forged {
+		t.Fatalf("GetOperation = %s, %v", op.Config, err)
+	}
+}
+
+// TestReencrypt 迁移启用加密前的明文，并把历史 KEK 加密的字段改用当前 KEK
+func TestReencrypt(t *testing.T) {
+	ctx := context.Background()
+	raw := newMemStore()
+	oldKEK := mustLocalKEK(t, "kek-old")
+
+	legacy := "plain-pass"
+	raw.CreateProxy(ctx, &model.Proxy{ID: "px-plain", Password: &legacy})
+	WrapStore(raw, mustKeyring(t, oldKEK)).CreateProxy(ctx, &model.Proxy{ID: "px-old", Password: &legacy})
+	raw.CreateOperation(ctx, &model.Operation{ID: "op-1", Config: json.RawMessage(`{"api_key":"sk-1"}`)})
+	raw.CreateOperation(ctx, &model.Operation{ID: "op-2", Config: json.RawMessage(`{"name":"oauth"}`)})
+
+	keys := mustKeyring(t, mustLocalKEK(t, "kek-new"), oldKEK)
+	stats, err := Reencrypt(ctx, raw, keys, ReencryptOptions{DryRun: true})
+	if err != nil || stats.Proxies != 2 || stats.Operations != 1 {
+		t.Fatalf("dry run stats = %+v, %v", stats, err)
+	}
+	if *raw.proxies["px-plain"].Password != legacy {
+		t.Fatal("dry run 不应写入")
+	}
+
+	stats, err = Reencrypt(ctx, raw, keys, ReencryptOptions{})
+	if err != nil || stats.Proxies != 2 || stats.Operations != 1 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+	for id, p := range raw.proxies {
+		if keys.Stale(*p.Password) {
+			t.Errorf("proxy %s 未用当前 KEK 加密: %q", id, *p.Password)
+		}
+	}
+	if keys.Stale(apiKeyOf(t, raw.ops[0].Config)) {
+		t.Errorf("operation config = %s", raw.ops[0].Config)
+	}
+
+	// 只用当前 KEK 的密钥环也能读取迁移后的数据；再次执行时全部跳过
+	got, err := WrapStore(raw, mustKeyring(t, mustLocalKEK(t, "kek-new"))).GetProxy(ctx, "px-old")
+	if err != nil || *got.Password != legacy {
+		t.Fatalf("GetProxy = %+v, %v", got, err)
+	}
+	stats, err = Reencrypt(ctx, raw, keys, ReencryptOptions{})
+	if err != nil || stats.Proxies != 0 || stats.Operations != 0 || stats.Skipped != 3 {
+		t.Errorf("rerun stats = %+v, %v", stats, err)
+	}
+}
+
+// TestWrapStore_CredentialAndSecret Git 凭据与运行时密钥加密保存；迁移前的 MASTER_KEY 密文需要 SetLegacyBox 才能读取
+func TestWrapStore_CredentialAndSecret(t *testing.T) {
+	ctx := context.Background()
+	raw := newMemStore()
+	keys := mustKeyring(t, mustLocalKEK(t, "kek"))
+	store := WrapStore(raw, keys)
+
+	cred := &model.Credential{ID: "cred-1", Name: "deploy-key", EncryptedSecret: "ghp_token"}
+	if err := store.CreateCredential(ctx, cred); err != nil {
+		t.Fatal(err)
+	}
+	if cred.EncryptedSecret != "ghp_token" {
+		t.Error("调用方持有的对象不应被修改")
+	}
+	if stored := raw.creds["cred-1"].EncryptedSecret; !IsSealed(stored) {
+		t.Fatalf("数据库中应为密文: %q", stored)
+	}
+	if err := store.CreateSecret(ctx, &model.Secret{ID: "sec-1", Name: "API_KEY", EncryptedValue: "sk-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if stored := raw.secrets["sec-1"].EncryptedValue; !IsSealed(stored) {
+		t.Fatalf("数据库中应为密文: %q", stored)
+	}
+	gotCred, err := store.GetCredentialByName(ctx, "deploy-key")
+	if err != nil || gotCred.EncryptedSecret != "ghp_token" {
+		t.Fatalf("GetCredentialByName = %+v, %v", gotCred, err)
+	}
+	gotSecret, err := store.GetSecretByName(ctx, "API_KEY")
+	if err != nil || gotSecret.EncryptedValue != "sk-1" {
+		t.Fatalf("GetSecretByName = %+v, %v", gotSecret, err)
+	}
+
+	box, _ := secretbox.New("master")
+	legacy, _ := box.Encrypt("legacy-token")
+	raw.CreateCredential(ctx, &model.Credential{ID: "cred-2", Name: "old-key", EncryptedSecret: legacy})
+	if _, err := store.GetCredentialByName(ctx, "old-key"); !errors.Is(err, ErrNoLegacyKey) {
+		t.Errorf("未设置 MASTER_KEY 时 err = %v, 期望 ErrNoLegacyKey", err)
+	}
+	keys.SetLegacyBox(box)
+	if got, err := store.GetCredentialByName(ctx, "old-key"); err != nil || got.EncryptedSecret != "legacy-token" {
+		t.Fatalf("GetCredentialByName = %+v, %v", got, err)
+	}
+}
+
+// TestReencrypt_CredentialRotation 轮换 KEK 并迁移 MASTER_KEY 密文后，只用新 KEK 仍能读取凭据与密钥
+func TestReencrypt_CredentialRotation(t *testing.T) {
+	ctx := context.Background()
+	raw := newMemStore()
+	oldKEK := mustLocalKEK(t, "kek-old")
+	if err := WrapStore(raw, mustKeyring(t, oldKEK)).CreateCredential(ctx,
+		&model.Credential{ID: "cred-1", Name: "deploy-key", EncryptedSecret: "ghp_token"}); err != nil {
+		t.Fatal(err)
+	}
+	box, _ := secretbox.New("master")
+	legacy, _ := box.Encrypt("sk-legacy")
+	raw.CreateSecret(ctx, &model.Secret{ID: "sec-1", Name: "API_KEY", EncryptedValue: legacy})
+
+	newKEK := mustLocalKEK(t, "kek-new")
+	keys := mustKeyring(t, newKEK, oldKEK)
+	keys.SetLegacyBox(box)
+	stats, err := Reencrypt(ctx, raw, keys, ReencryptOptions{})
+	if err != nil || stats.Credentials != 1 || stats.Secrets != 1 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+
+	store := WrapStore(raw, mustKeyring(t, newKEK))
+	cred, err := store.GetCredentialByName(ctx, "deploy-key")
+	if err != nil || cred.EncryptedSecret != "ghp_token" {
+		t.Fatalf("GetCredentialByName = %+v, %v", cred, err)
+	}
+	secret, err := store.GetSecretByName(ctx, "API_KEY")
+	if err != nil || secret.EncryptedValue != "sk-legacy" {
+		t.Fatalf("GetSecretByName = %+v, %v", secret, err)
+	}
+	stats, err = Reencrypt(ctx, raw, keys, ReencryptOptions{})
+	if err != nil || stats.Credentials != 0 || stats.Secrets != 0 || stats.Skipped != 2 {
+		t.Errorf("rerun stats = %+v, %v", stats, err)
+	}
+}
@@ -0,0 +1,478 @@
+// Package gateway Agent 网关 - OpenAI Chat Completions 兼容接口
+//
+// 让内部工具像调用 LLM 一样调用 Agent 集群：
+//   - 请求中的 model 按配置映射到 Agent 类型、实例、项目与调度标签
+//   - 每个请求创建一个任务并同步执行一次 Run（调度与项目账号池分配与 POST /api/v1/tasks/{id}/runs 一致）
+//   - Run 的 message 事件作为助手回复返回，stream=true 时以 SSE 逐条推送
+//   - 认证沿用 API Server 的 JWT（节点凭证一律拒绝），项目约束与任务创建一致，
+//     每个用户同时进行的请求数受 max_concurrent 限制
+//   - 客户端断开连接或等待超时时取消 Run
+package gateway
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/task"
+	"agents-admin/internal/shared/model"
+)
+
+// 默认配置
+const (
+	defaultTimeout      = 10 * time.Minute
+	defaultPollInterval = 500 * time.Millisecond
+	eventBatch          = 200 // 每次读取的事件数
+	maxTaskNameRunes    = 60
+)
+
+// Config 网关配置
+type Config struct {
+	Timeout       time.Duration // 单次请求等待 Run 结束的最长时间
+	PollInterval  time.Duration // 读取 Run 事件的间隔
+	MaxConcurrent int           // 每个用户同时进行的请求数上限，0 表示不限制
+	Models        []Model       // 对外暴露的模型
+}
+
+// Model 请求中的 model 名称到任务执行配置的映射
+type Model struct {
+	Name           string
+	Description    string
+	AgentType      string            // Agent 类型（配置 ProjectID 时可使用项目默认值）
+	AgentID        string            // 固定使用的 Agent 实例
+	ProjectID      string            // 所属项目
+	Labels         map[string]string // 调度标签
+	TimeoutSeconds int               // 单次执行时限
+}
+
+// Store 网关需要的存储接口（用于测试 mock）
+type Store interface {
+	CreateTask(ctx context.Context, task *model.Task) error
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+}
+
+// Runs 创建与取消 Run（由 run.Handler 实现）
+type Runs interface {
+	StartRun(ctx context.Context, taskID string) (*model.Run, error)
+	CancelRun(ctx context.Context, runID string) error
+}
+
+// Handler Agent 网关 HTTP 处理器
+type Handler struct {
+	store    Store
+	projects task.ProjectStore // 项目约束（可选，nil 时配置了项目的模型不可用）
+	runs     Runs
+	cfg      Config
+	models   map[string]*Model
+	limiter  *userLimiter
+}
+
+// NewHandler 创建网关处理器，store 实现 task.ProjectStore 时对配置了项目的模型应用项目约束
+func NewHandler(store Store, runs Runs, cfg Config) *Handler {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaultTimeout
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaultPollInterval
+	}
+	h := &Handler{
+		store:   store,
+		runs:    runs,
+		cfg:     cfg,
+		models:  make(map[string]*Model, len(cfg.Models)),
+		limiter: &userLimiter{max: cfg.MaxConcurrent, active: make(map[string]int)},
+	}
+	if ps, ok := store.(task.ProjectStore); ok {
+		h.projects = ps
+	}
+	for i := range cfg.Models {
+		m := &cfg.Models[i]
+		h.models[m.Name] = m
+	}
+	return h
+}
+
+// RegisterRoutes 注册网关路由（OpenAI 兼容路径）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/models", h.ListModels)
+	mux.HandleFunc("POST /v1/chat/completions", h.ChatCompletions)
+}
+
+// ListModels 列出可用模型
+// GET /v1/models
+func (h *Handler) ListModels(w http.ResponseWriter, r *http.Request) {
+	data := make([]map[string]interface{}, 0, len(h.cfg.Models))
+	for _, m := range h.cfg.Models {
+		entry := map[string]interface{}{"id": m.Name, "object": "model", "created": 0, "owned_by": "agents-admin"}
+		if m.Description != "" {
+			entry["description"] = m.Description
+		}
+		data = append(data, entry)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"object": "list", "data": data})
+}
+
+// ChatCompletions 以一次 Run 完成对话
+// POST /v1/chat/completions
+//
+// 请求体: {"model": "fleet-qwen", "messages": [{"role": "user", "content": "..."}], "stream": true}
+// 响应: chat.completion（stream=true 时为 chat.completion.chunk 事件流，以 data: [DONE] 结束），
+// 响应头 X-Run-ID 为对应的 Run。
+// 错误: 400 请求无效；403 节点凭证或违反项目约束；404 模型不存在；429 并发超限；
+// 502 Run 失败；504 等待超时
+func (h *Handler) ChatCompletions(w http.ResponseWriter, r *http.Request) {
+	if auth.GetNodeIdentity(r.Context()) != nil {
+		writeAPIError(w, http.StatusForbidden, "permission_denied", "node credentials cannot use the agent gateway")
+		return
+	}
+	var req ChatCompletionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", "invalid request body")
+		return
+	}
+	m, ok := h.models[req.Model]
+	if !ok {
+		writeAPIError(w, http.StatusNotFound, "model_not_found", fmt.Sprintf("model %q does not exist", req.Model))
+		return
+	}
+	prompt := buildPrompt(req.Messages)
+	if prompt == "" {
+		writeAPIError(w, http.StatusBadRequest, "invalid_request_error", "messages must contain text content")
+		return
+	}
+
+	caller := callerID(r.Context())
+	if !h.limiter.acquire(caller) {
+		writeAPIError(w, http.StatusTooManyRequests, "rate_limit_exceeded",
+			fmt.Sprintf("too many concurrent requests (limit %d)", h.cfg.MaxConcurrent))
+		return
+	}
+	defer h.limiter.release(caller)
+
+	t, err := h.createTask(r.Context(), m, prompt, caller)
+	if err != nil {
+		var pe *task.ProjectError
+		if errors.As(err, &pe) {
+			writeAPIError(w, pe.Status, "invalid_request_error", pe.Message)
+			return
+		}
+		slog.ErrorContext(r.Context(), "gateway.task.failed", "model", m.Name, "error", err)
+		writeAPIError(w, http.StatusInternalServerError, "server_error", "failed to create task")
+		return
+	}
+	run, err := h.runs.StartRun(r.Context(), t.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "gateway.run.failed", "model", m.Name, "task_id", t.ID, "error", err)
+		writeAPIError(w, http.StatusServiceUnavailable, "server_error", "failed to start run: "+err.Error())
+		return
+	}
+	slog.InfoContext(r.Context(), "gateway.run.started", "model", m.Name, "run_id", run.ID, "user", caller, "stream", req.Stream)
+
+	// Agent 执行通常远超服务端 WriteTimeout，整个请求期间取消写超时
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	w.Header().Set("X-Run-ID", run.ID)
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeout)
+	defer cancel()
+	if req.Stream {
+		h.stream(ctx, w, &req, run)
+	} else {
+		h.complete(ctx, w, &req, run)
+	}
+}
+
+// createTask 按模型配置创建任务（应用项目默认值与约束）
+func (h *Handler) createTask(ctx context.Context, m *Model, prompt, caller string) (*model.Task, error) {
+	now := time.Now()
+	t := &model.Task{
+		ID:             generateID("task"),
+		Name:           truncateRunes("[gateway] "+strings.SplitN(prompt, "\n", 2)[0], maxTaskNameRunes),
+		Description:    fmt.Sprintf("Created by agent gateway (model %s, user %s)", m.Name, caller),
+		Status:         model.TaskStatusPending,
+		Type:           model.TaskType(m.AgentType),
+		Prompt:         &model.Prompt{Content: prompt},
+		Priority:       model.PriorityNormal,
+		TimeoutSeconds: m.TimeoutSeconds,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if m.AgentID != "" {
+		agentID := m.AgentID
+		t.AgentID = &agentID
+	}
+	if len(m.Labels) > 0 {
+		t.Labels = make(map[string]string, len(m.Labels))
+		for k, v := range m.Labels {
+			t.Labels[k] = v
+		}
+	}
+	if m.ProjectID != "" {
+		if h.projects == nil {
+			return nil, &task.ProjectError{Status: http.StatusNotImplemented, Message: "projects not supported"}
+		}
+		if err := task.ApplyProject(ctx, h.projects, m.ProjectID, t, m.AgentType != ""); err != nil {
+			return nil, err
+		}
+	}
+	if t.Type == "" {
+		return nil, &task.ProjectError{Status: http.StatusBadRequest, Message: fmt.Sprintf("model %s has no agent type", m.Name)}
+	}
+	if err := h.store.CreateTask(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// complete 等待 Run 结束后一次性返回
+func (h *Handler) complete(ctx context.Context, w http.ResponseWriter, req *ChatCompletionRequest, run *model.Run) {
+	var out runOutput
+	status, err := h.follow(ctx, run.ID, &out, nil)
+	if err != nil {
+		h.abandon(run.ID, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			writeAPIError(w, http.StatusGatewayTimeout, "timeout", "run did not finish in time")
+		} else {
+			writeAPIError(w, http.StatusBadGateway, "server_error", err.Error())
+		}
+		return
+	}
+	if status != model.RunStatusDone {
+		writeAPIError(w, http.StatusBadGateway, "run_failed", runFailure(status, &out))
+		return
+	}
+	writeJSON(w, http.StatusOK, ChatCompletion{
+		ID:      completionID(run),
+		Object:  "chat.completion",
+		Created: run.CreatedAt.Unix(),
+		Model:   req.Model,
+		Choices: []ChatChoice{{
+			Message:      AssistantOutput{Role: "assistant", Content: out.content()},
+			FinishReason: "stop",
+		}},
+		Usage: out.usage,
+	})
+}
+
+// stream 以 SSE 推送 Run 产生的助手消息
+func (h *Handler) stream(ctx context.Context, w http.ResponseWriter, req *ChatCompletionRequest, run *model.Run) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(v interface{}) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	}
+	chunk := func(delta ChunkDelta, finish *string) *ChatCompletionChunk {
+		return &ChatCompletionChunk{
+			ID:      completionID(run),
+			Object:  "chat.completion.chunk",
+			Created: run.CreatedAt.Unix(),
+			Model:   req.Model,
+			Choices: []ChunkChoice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+	done := func() { fmt.Fprint(w, "data: [DONE]\n\n"); rc.Flush() }
+
+	if err := send(chunk(ChunkDelta{Role: "assistant"}, nil)); err != nil {
+		h.abandon(run.ID, err)
+		return
+	}
+
+	var out runOutput
+	status, err := h.follow(ctx, run.ID, &out, func(text string) error {
+		if len(out.messages) > 1 {
+			text = "\n\n" + text
+		}
+		return send(chunk(ChunkDelta{Content: text}, nil))
+	})
+	if err != nil {
+		h.abandon(run.ID, err)
+		if errors.Is(err, context.DeadlineExceeded) {
+			send(apiError{Error: apiErrorBody{Message: "run did not finish in time", Type: "timeout"}})
+			done()
+		}
+		return
+	}
+	if status != model.RunStatusDone {
+		send(apiError{Error: apiErrorBody{Message: runFailure(status, &out), Type: "run_failed"}})
+		done()
+		return
+	}
+	// 只有最终结果没有消息事件的 Agent：结果作为一整段内容推送
+	if len(out.messages) == 0 && out.result != "" {
+		if send(chunk(ChunkDelta{Content: out.result}, nil)) != nil {
+			return
+		}
+	}
+	stop := "stop"
+	if send(chunk(ChunkDelta{}, &stop)) != nil {
+		return
+	}
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		usage := out.usage
+		if usage == nil {
+			usage = &Usage{}
+		}
+		send(&ChatCompletionChunk{ID: completionID(run), Object: "chat.completion.chunk", Created: run.CreatedAt.Unix(),
+			Model: req.Model, Choices: []ChunkChoice{}, Usage: usage})
+	}
+	done()
+}
+
+// follow 读取 Run 事件直到 Run 结束，每条新的助手消息调用 onText，返回 Run 终态
+func (h *Handler) follow(ctx context.Context, runID string, out *runOutput, onText func(string) error) (model.RunStatus, error) {
+	ticker := time.NewTicker(h.cfg.PollInterval)
+	defer ticker.Stop()
+	seq := 0
+	for {
+		// 先读状态再读事件：节点先上报 run_completed 事件再更新状态，读到终态时事件已全部入库
+		run, err := h.store.GetRun(ctx, runID)
+		if err != nil {
+			return "", err
+		}
+		if run == nil {
+			return "", errors.New("run not found")
+		}
+		for {
+			events, err := h.store.GetEventsByRun(ctx, runID, seq, eventBatch)
+			if err != nil {
+				return "", err
+			}
+			for _, e := range events {
+				seq = e.Seq
+				if text := out.apply(e); text != "" && onText != nil {
+					if err := onText(text); err != nil {
+						return "", err
+					}
+				}
+			}
+			if len(events) < eventBatch {
+				break
+			}
+		}
+		if run.IsTerminal() {
+			return run.Status, nil
+		}
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// abandon 客户端断开、写入失败或等待超时时取消 Run
+func (h *Handler) abandon(runID string, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := h.runs.CancelRun(ctx, runID); err != nil {
+		slog.ErrorContext(ctx, "gateway.run.cancel.failed", "run_id", runID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "gateway.run.abandoned", "run_id", runID, "cause", cause)
+}
+
+// runFailure Run 未成功结束时返回给客户端的原因
+func runFailure(status model.RunStatus, out *runOutput) string {
+	if out.errMsg != "" {
+		return fmt.Sprintf("run %s: %s", status, out.errMsg)
+	}
+	return fmt.Sprintf("run %s", status)
+}
+
+// ============================================================================
+// 并发限制
+// ============================================================================
+
+// userLimiter 按用户限制同时进行的请求数（max<=0 时不限制）
+type userLimiter struct {
+	max    int
+	mu     sync.Mutex
+	active map[string]int
+}
+
+func (l *userLimiter) acquire(user string) bool {
+	if l.max <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[user] >= l.max {
+		return false
+	}
+	l.active[user]++
+	return true
+}
+
+func (l *userLimiter) release(user string) {
+	if l.max <= 0 {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.active[user]--; l.active[user] <= 0 {
+		delete(l.active, user)
+	}
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+// callerID 请求方标识（未启用认证时为 anonymous）
+func callerID(ctx context.Context) string {
+	if user := auth.GetAuthUser(ctx); user != nil {
+		return user.ID
+	}
+	return "anonymous"
+}
+
+func completionID(run *model.Run) string {
+	return "chatcmpl-" + run.ID
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+func truncateRunes(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+// writeAPIError 写入 OpenAI 风格的错误响应（{"error": {"message", "type"}}），便于现有 SDK 解析
+func writeAPIError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, apiError{Error: apiErrorBody{Message: message, Type: errType}})
+}
@@ -0,0 +1,295 @@
+package gateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存存储：Run 在第 finishAfter 次 GetRun 时进入终态并写入全部事件
+type mockStore struct {
+	mu          sync.Mutex
+	tasks       map[string]*model.Task
+	runs        map[string]*model.Run
+	events      map[string][]*model.Event
+	pending     []*model.Event  // Run 结束时写入的事件
+	final       model.RunStatus // Run 终态，空表示永不结束
+	finishAfter int
+	getRuns     int
+}
+
+func newMockStore(final model.RunStatus, events ...*model.Event) *mockStore {
+	for i, e := range events {
+		e.Seq = i + 1
+	}
+	return &mockStore{tasks: map[string]*model.Task{}, runs: map[string]*model.Run{}, events: map[string][]*model.Event{},
+		pending: events, final: final, finishAfter: 2}
+}
+
+func (m *mockStore) CreateTask(_ context.Context, t *model.Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.tasks[t.ID] = t
+	return nil
+}
+
+func (m *mockStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[id]
+	if !ok {
+		return nil, nil
+	}
+	m.getRuns++
+	if m.final != "" && m.getRuns >= m.finishAfter && !run.IsTerminal() {
+		run.Status = m.final
+		m.events[id] = m.pending
+	}
+	copied := *run
+	return &copied, nil
+}
+
+func (m *mockStore) GetEventsByRun(_ context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.Event
+	for _, e := range m.events[runID] {
+		if e.Seq > fromSeq && len(result) < limit {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+// mockRuns 创建 running 状态的 Run，记录取消
+type mockRuns struct {
+	store     *mockStore
+	cancelled []string
+}
+
+func (r *mockRuns) StartRun(_ context.Context, taskID string) (*model.Run, error) {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	run := &model.Run{ID: "run-" + taskID, TaskID: taskID, Status: model.RunStatusRunning, CreatedAt: time.Unix(1700000000, 0)}
+	r.store.runs[run.ID] = run
+	return run, nil
+}
+
+func (r *mockRuns) CancelRun(_ context.Context, runID string) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+	r.cancelled = append(r.cancelled, runID)
+	return nil
+}
+
+func event(eventType string, payload map[string]interface{}) *model.Event {
+	data, _ := json.Marshal(payload)
+	return &model.Event{Type: eventType, Payload: data}
+}
+
+func newTestHandler(store *mockStore, cfg Config) (*Handler, *mockRuns, *http.ServeMux) {
+	runs := &mockRuns{store: store}
+	if cfg.PollInterval == 0 {
+		cfg.PollInterval = time.Millisecond
+	}
+	cfg.Models = append(cfg.Models, Model{Name: "fleet-qwen", AgentType: "qwen-code", Labels: map[string]string{"pool": "gw"}, TimeoutSeconds: 300})
+	h := NewHandler(store, runs, cfg)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, runs, mux
+}
+
+func post(mux *http.ServeMux, body string, user *auth.AuthUser) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(body))
+	if user != nil {
+		req = req.WithContext(auth.WithAuthUser(req.Context(), user))
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestChatCompletions_NonStream(t *testing.T) {
+	store := newMockStore(model.RunStatusDone,
+		event("message", map[string]interface{}{"type": "message", "content": "Hello"}),
+		event("message", map[string]interface{}{"type": "thinking", "content": "hmm"}),
+		event("message", map[string]interface{}{"type": "message", "content": "World"}),
+		event("run_completed", map[string]interface{}{"status": "success", "usage": map[string]interface{}{"input_tokens": 10.0, "output_tokens": 5.0}}),
+	)
+	_, _, mux := newTestHandler(store, Config{})
+
+	w := post(mux, `{"model":"fleet-qwen","messages":[{"role":"system","content":"Be brief."},{"role":"user","content":"Say hi"}]}`, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp ChatCompletion
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if got := resp.Choices[0].Message.Content; got != "Hello\n\nWorld" {
+		t.Errorf("content = %q", got)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 15 {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+	if runID := w.Header().Get("X-Run-ID"); resp.ID != "chatcmpl-"+runID {
+		t.Errorf("id = %s, run = %s", resp.ID, runID)
+	}
+
+	if len(store.tasks) != 1 {
+		t.Fatalf("tasks = %d, want 1", len(store.tasks))
+	}
+	for _, task := range store.tasks {
+		if task.Type != "qwen-code" || task.Labels["pool"] != "gw" || task.TimeoutSeconds != 300 {
+			t.Errorf("task = %+v", task)
+		}
+		if task.Prompt.Content != "Be brief.\n\nSay hi" {
+			t.Errorf("prompt = %q", task.Prompt.Content)
+		}
+	}
+}
+
+func TestChatCompletions_Stream(t *testing.T) {
+	store := newMockStore(model.RunStatusDone,
+		event("message", map[string]interface{}{"type": "message", "content": "Hello"}),
+		event("message", map[string]interface{}{"type": "message", "content": "World"}),
+		event("run_completed", map[string]interface{}{"status": "success"}),
+	)
+	_, _, mux := newTestHandler(store, Config{})
+
+	w := post(mux, `{"model":"fleet-qwen","stream":true,"stream_options":{"include_usage":true},"messages":[{"role":"user","content":"Say hi"}]}`, nil)
+	if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("content type = %s", ct)
+	}
+
+	var content strings.Builder
+	var finish string
+	var sawUsage, sawDone bool
+	for _, line := range strings.Split(w.Body.String(), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			sawDone = true
+			continue
+		}
+		var chunk ChatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("invalid chunk %q: %v", data, err)
+		}
+		if chunk.Usage != nil {
+			sawUsage = true
+		}
+		for _, c := range chunk.Choices {
+			content.WriteString(c.Delta.Content)
+			if c.FinishReason != nil {
+				finish = *c.FinishReason
+			}
+		}
+	}
+	if content.String() != "Hello\n\nWorld" || finish != "stop" || !sawUsage || !sawDone {
+		t.Errorf("content = %q, finish = %q, usage = %v, done = %v", content.String(), finish, sawUsage, sawDone)
+	}
+}
+
+func TestChatCompletions_Errors(t *testing.T) {
+	failed := newMockStore(model.RunStatusFailed, event("run_completed", map[string]interface{}{"status": "failed", "error": "exit status 1"}))
+	_, _, mux := newTestHandler(failed, Config{})
+	if w := post(mux, `{"model":"unknown","messages":[{"role":"user","content":"hi"}]}`, nil); w.Code != http.StatusNotFound {
+		t.Errorf("unknown model: status = %d, want 404", w.Code)
+	}
+	if w := post(mux, `{"model":"fleet-qwen","messages":[]}`, nil); w.Code != http.StatusBadRequest {
+		t.Errorf("empty messages: status = %d, want 400", w.Code)
+	}
+	w := post(mux, `{"model":"fleet-qwen","messages":[{"role":"user","content":"hi"}]}`, nil)
+	if w.Code != http.StatusBadGateway || !strings.Contains(w.Body.String(), "exit status 1") {
+		t.Errorf("failed run: status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	req := httptest.NewRequest("POST", "/v1/chat/completions", bytes.NewBufferString(`{"model":"fleet-qwen"}`))
+	req = req.WithContext(auth.WithNodeIdentity(req.Context(), "node-1"))
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("node identity: status = %d, want 403", rec.Code)
+	}
+}
+
+func TestChatCompletions_TimeoutCancelsRun(t *testing.T) {
+	store := newMockStore("")
+	_, runs, mux := newTestHandler(store, Config{Timeout: 20 * time.Millisecond})
+
+	w := post(mux, `{"model":"fleet-qwen","messages":[{"role":"user","content":"hi"}]}`, nil)
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("status = %d, want 504", w.Code)
+	}
+	if len(runs.cancelled) != 1 || runs.cancelled[0] != w.Header().Get("X-Run-ID") {
+		t.Errorf("cancelled = %v", runs.cancelled)
+	}
+}
+
+func TestUserLimiter(t *testing.T) {
+	l := &userLimiter{max: 1, active: map[string]int{}}
+	if !l.acquire("u1") || l.acquire("u1") {
+		t.Fatal("second concurrent request of the same user should be rejected")
+	}
+	if !l.acquire("u2") {
+		t.Error("other users are not affected")
+	}
+	l.release("u1")
+	if !l.acquire("u1") {
+		t.Error("slot should be available after release")
+	}
+
+	unlimited := &userLimiter{active: map[string]int{}}
+	for i := 0; i < 3; i++ {
+		if !unlimited.acquire("u1") {
+			t.Fatal("max=0 means no limit")
+		}
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	msg := func(role, content string) ChatMessage {
+		data, _ := json.Marshal(content)
+		return ChatMessage{Role: role, Content: data}
+	}
+	if got := buildPrompt([]ChatMessage{msg("user", "hi")}); got != "hi" {
+		t.Errorf("single message = %q", got)
+	}
+	parts := ChatMessage{Role: "user", Content: json.RawMessage(`[{"type":"text","text":"look"},{"type":"image_url"}]`)}
+	if got := buildPrompt([]ChatMessage{parts}); got != "look" {
+		t.Errorf("content parts = %q", got)
+	}
+	got := buildPrompt([]ChatMessage{msg("system", "sys"), msg("user", "a"), msg("assistant", "b"), msg("user", "c")})
+	want := "sys\n\nConversation so far:\n\nuser: a\n\nassistant: b\n\nuser: c\n\nReply to the last user message."
+	if got != want {
+		t.Errorf("conversation = %q", got)
+	}
+}
+
+func TestMessageText(t *testing.T) {
+	tests := []struct {
+		payload map[string]interface{}
+		want    string
+	}{
+		{map[string]interface{}{"type": "message", "content": "plain"}, "plain"},
+		{map[string]interface{}{"type": "user", "content": "prompt"}, ""},
+		{map[string]interface{}{"type": "assistant", "message": map[string]interface{}{
+			"role": "assistant", "content": []interface{}{map[string]interface{}{"type": "text", "text": "raw"}},
+		}}, "raw"},
+	}
+	for _, tt := range tests {
+		if got := messageText(tt.payload); got != tt.want {
+			t.Errorf("messageText(%v) = %q, want %q", tt.payload, got, tt.want)
+		}
+	}
+}
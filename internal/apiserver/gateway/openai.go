@@ -0,0 +1,270 @@
+package gateway
+
+import (
+	"encoding/json"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ============================================================================
+// OpenAI Chat Completions 协议类型（仅包含网关支持的字段）
+// ============================================================================
+
+// ChatCompletionRequest POST /v1/chat/completions 请求体
+//
+// temperature、max_tokens 等采样参数对 Agent 无意义，解码时忽略。
+type ChatCompletionRequest struct {
+	Model         string         `json:"model"`
+	Messages      []ChatMessage  `json:"messages"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	User          string         `json:"user,omitempty"`
+}
+
+// StreamOptions 流式响应选项
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ChatMessage 对话消息，content 为字符串或内容片段数组（只取 text 片段）
+type ChatMessage struct {
+	Role    string          `json:"role"`
+	Content json.RawMessage `json:"content"`
+}
+
+// text 提取消息文本
+func (m ChatMessage) text() string {
+	var s string
+	if json.Unmarshal(m.Content, &s) == nil {
+		return s
+	}
+	var parts []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	}
+	if json.Unmarshal(m.Content, &parts) != nil {
+		return ""
+	}
+	var texts []string
+	for _, p := range parts {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// ChatCompletion 非流式响应
+type ChatCompletion struct {
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   *Usage       `json:"usage,omitempty"`
+}
+
+// ChatChoice 非流式响应的候选结果
+type ChatChoice struct {
+	Index        int             `json:"index"`
+	Message      AssistantOutput `json:"message"`
+	FinishReason string          `json:"finish_reason"`
+}
+
+// AssistantOutput 助手消息
+type AssistantOutput struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// ChatCompletionChunk 流式响应片段
+type ChatCompletionChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []ChunkChoice `json:"choices"`
+	Usage   *Usage        `json:"usage,omitempty"`
+}
+
+// ChunkChoice 流式响应片段的候选结果
+type ChunkChoice struct {
+	Index        int        `json:"index"`
+	Delta        ChunkDelta `json:"delta"`
+	FinishReason *string    `json:"finish_reason"`
+}
+
+// ChunkDelta 流式增量
+type ChunkDelta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// Usage Token 用量（Agent 上报时填充）
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// apiError OpenAI 风格的错误响应
+type apiError struct {
+	Error apiErrorBody `json:"error"`
+}
+
+type apiErrorBody struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+	Code    string `json:"code,omitempty"`
+}
+
+// ============================================================================
+// 请求 → 任务提示词
+// ============================================================================
+
+// buildPrompt 将对话消息渲染为任务提示词
+//
+// 只有一条用户消息时直接使用其内容（system 消息置于最前）；
+// 多轮对话按角色渲染为对话记录，Agent 回复最后一条用户消息。
+func buildPrompt(messages []ChatMessage) string {
+	var system, turns []string
+	var users int
+	for _, m := range messages {
+		text := strings.TrimSpace(m.text())
+		if text == "" {
+			continue
+		}
+		switch m.Role {
+		case "system", "developer":
+			system = append(system, text)
+		default:
+			if m.Role == "user" {
+				users++
+			}
+			turns = append(turns, m.Role+": "+text)
+		}
+	}
+
+	var b strings.Builder
+	for _, s := range system {
+		b.WriteString(s)
+		b.WriteString("\n\n")
+	}
+	if users == 1 && len(turns) == 1 {
+		b.WriteString(strings.TrimPrefix(turns[0], "user: "))
+		return strings.TrimSpace(b.String())
+	}
+	if len(turns) > 0 {
+		b.WriteString("Conversation so far:\n\n")
+		b.WriteString(strings.Join(turns, "\n\n"))
+		b.WriteString("\n\nReply to the last user message.")
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// ============================================================================
+// Run 事件 → 助手输出
+// ============================================================================
+
+// runOutput 从 Run 事件中累积的助手输出
+type runOutput struct {
+	messages []string // 助手消息文本
+	result   string   // Agent 最终结果（未产生消息事件时作为输出）
+	usage    *Usage
+	errMsg   string // 节点上报的失败原因
+}
+
+// apply 处理一个事件，返回新增的助手消息文本（无新增时为空）
+func (o *runOutput) apply(e *model.Event) string {
+	var payload map[string]interface{}
+	if len(e.Payload) > 0 {
+		json.Unmarshal(e.Payload, &payload)
+	}
+	switch e.Type {
+	case "message":
+		text := messageText(payload)
+		if text == "" {
+			return ""
+		}
+		o.messages = append(o.messages, text)
+		return text
+	case "run_completed":
+		if s, ok := payload["result"].(string); ok && s != "" {
+			o.result = s
+		}
+		if s, ok := payload["error"].(string); ok && s != "" {
+			o.errMsg = s
+		}
+		if u := parseUsage(payload["usage"]); u != nil {
+			o.usage = u
+		}
+	case "error":
+		if s, ok := payload["message"].(string); ok && s != "" {
+			o.errMsg = s
+		}
+	}
+	return ""
+}
+
+// content 完整的助手回复
+func (o *runOutput) content() string {
+	if len(o.messages) == 0 {
+		return o.result
+	}
+	return strings.Join(o.messages, "\n\n")
+}
+
+// messageText 提取助手消息文本：支持 {"content": "..."} 与原始 {"message": {"content": [{"text": "..."}]}}，
+// 跳过用户消息与思考过程
+func messageText(payload map[string]interface{}) string {
+	switch payload["type"] {
+	case "user", "thinking", "plan":
+		return ""
+	}
+	if s, ok := payload["content"].(string); ok {
+		return s
+	}
+	msg, ok := payload["message"].(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	if role, _ := msg["role"].(string); role != "" && role != "assistant" {
+		return ""
+	}
+	blocks, _ := msg["content"].([]interface{})
+	var texts []string
+	for _, b := range blocks {
+		if block, ok := b.(map[string]interface{}); ok {
+			if text, ok := block["text"].(string); ok && text != "" {
+				texts = append(texts, text)
+			}
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// parseUsage 解析 Agent 上报的 Token 用量（input/output_tokens 或 prompt/completion_tokens）
+func parseUsage(v interface{}) *Usage {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	num := func(keys ...string) int {
+		for _, k := range keys {
+			if f, ok := m[k].(float64); ok {
+				return int(f)
+			}
+		}
+		return 0
+	}
+	u := &Usage{
+		PromptTokens:     num("input_tokens", "prompt_tokens"),
+		CompletionTokens: num("output_tokens", "completion_tokens"),
+	}
+	u.TotalTokens = u.PromptTokens + u.CompletionTokens
+	if u.TotalTokens == 0 {
+		return nil
+	}
+	return u
+}
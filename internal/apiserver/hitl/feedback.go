@@ -0,0 +1,89 @@
+// Package hitl 人在环路（HITL）领域 - 人工反馈投递
+//
+// 运行中提交的人工反馈随节点心跳投递给正在执行的 Agent：
+//   - 节点心跳上报 running_runs，API Server 返回这些 Run 中尚未处理的反馈（PendingFeedbacks）
+//   - 节点将反馈写入 Agent 的控制通道，Agent 读取后上报 feedback_consumed 事件，
+//     事件入库时标记反馈已处理（RecordFeedbackEvents），之后不再投递
+package hitl
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+
+	"agents-admin/internal/shared/model"
+)
+
+// FeedbackStore 人工反馈投递所需的存储接口
+type FeedbackStore interface {
+	ListFeedbacks(ctx context.Context, runID string) ([]*model.HumanFeedback, error)
+	MarkFeedbackProcessed(ctx context.Context, id string) error
+}
+
+// FeedbackDelivery 待投递给节点的人工反馈（心跳指令 feedbacks）
+type FeedbackDelivery struct {
+	ID      string             `json:"id"`
+	RunID   string             `json:"run_id"`
+	Type    model.FeedbackType `json:"type"`
+	Content string             `json:"content"`
+}
+
+// PendingFeedbacks 返回节点正在执行的 Run 中尚未处理的人工反馈（按提交时间排序）
+func PendingFeedbacks(ctx context.Context, store FeedbackStore, runIDs []string) []FeedbackDelivery {
+	var deliveries []FeedbackDelivery
+	for _, runID := range runIDs {
+		feedbacks, err := store.ListFeedbacks(ctx, runID)
+		if err != nil {
+			slog.ErrorContext(ctx, "hitl.feedback.list.failed", "run_id", runID, "error", err)
+			continue
+		}
+		for _, f := range feedbacks {
+			if f.IsProcessed() {
+				continue
+			}
+			deliveries = append(deliveries, FeedbackDelivery{ID: f.ID, RunID: runID, Type: f.Type, Content: f.Content})
+		}
+	}
+	return deliveries
+}
+
+// RecordFeedbackEvents 根据 feedback_consumed 事件标记人工反馈已处理
+//
+// 只处理属于该 Run 且尚未处理的反馈（节点重试上报时保持幂等），失败只记录日志，不影响事件入库。
+func RecordFeedbackEvents(ctx context.Context, store FeedbackStore, runID string, events []*model.Event) {
+	var pending map[string]bool
+	for _, e := range events {
+		if e.Type != string(model.EventTypeFeedbackConsumed) {
+			continue
+		}
+		var p struct {
+			FeedbackID string `json:"feedback_id"`
+		}
+		if err := json.Unmarshal(e.Payload, &p); err != nil || p.FeedbackID == "" {
+			slog.WarnContext(ctx, "hitl.feedback.invalid", "run_id", runID, "seq", e.Seq)
+			continue
+		}
+		if pending == nil {
+			feedbacks, err := store.ListFeedbacks(ctx, runID)
+			if err != nil {
+				slog.ErrorContext(ctx, "hitl.feedback.list.failed", "run_id", runID, "error", err)
+				return
+			}
+			pending = make(map[string]bool, len(feedbacks))
+			for _, f := range feedbacks {
+				if !f.IsProcessed() {
+					pending[f.ID] = true
+				}
+			}
+		}
+		if !pending[p.FeedbackID] {
+			continue
+		}
+		if err := store.MarkFeedbackProcessed(ctx, p.FeedbackID); err != nil {
+			slog.ErrorContext(ctx, "hitl.feedback.mark.failed", "run_id", runID, "feedback_id", p.FeedbackID, "error", err)
+			continue
+		}
+		delete(pending, p.FeedbackID)
+		slog.InfoContext(ctx, "hitl.feedback.consumed", "run_id", runID, "feedback_id", p.FeedbackID)
+	}
+}
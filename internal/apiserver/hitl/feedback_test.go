@@ -0,0 +1,62 @@
+package hitl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+type mockFeedbackStore struct {
+	feedbacks []*model.HumanFeedback
+	marked    []string
+}
+
+func (m *mockFeedbackStore) ListFeedbacks(_ context.Context, runID string) ([]*model.HumanFeedback, error) {
+	var out []*model.HumanFeedback
+	for _, f := range m.feedbacks {
+		if f.RunID == runID {
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockFeedbackStore) MarkFeedbackProcessed(_ context.Context, id string) error {
+	m.marked = append(m.marked, id)
+	now := time.Now()
+	for _, f := range m.feedbacks {
+		if f.ID == id {
+			f.ProcessedAt = &now
+		}
+	}
+	return nil
+}
+
+func TestFeedbackDelivery(t *testing.T) {
+	store := &mockFeedbackStore{feedbacks: []*model.HumanFeedback{
+		{ID: "fb-1", RunID: "run-1", Type: model.FeedbackTypeGuidance, Content: "prefer small commits"},
+		{ID: "fb-2", RunID: "run-2", Type: model.FeedbackTypeCorrection, Content: "wrong file"},
+	}}
+
+	pending := PendingFeedbacks(context.Background(), store, []string{"run-1"})
+	if len(pending) != 1 || pending[0].ID != "fb-1" || pending[0].RunID != "run-1" {
+		t.Fatalf("pending = %+v", pending)
+	}
+
+	events := []*model.Event{
+		{Seq: 7, Type: "feedback_delivered", Payload: []byte(`{"feedback_id":"fb-1"}`)},
+		{Seq: 8, Type: "feedback_consumed", Payload: []byte(`{"feedback_id":"fb-1"}`)},
+		{Seq: 9, Type: "feedback_consumed", Payload: []byte(`{"feedback_id":"fb-2"}`)}, // 属于其他 Run
+		{Seq: 10, Type: "feedback_consumed", Payload: []byte(`{}`)},
+	}
+	RecordFeedbackEvents(context.Background(), store, "run-1", events)
+	RecordFeedbackEvents(context.Background(), store, "run-1", events[1:2]) // 节点重试上报
+	if len(store.marked) != 1 || store.marked[0] != "fb-1" {
+		t.Errorf("marked = %v", store.marked)
+	}
+	if pending := PendingFeedbacks(context.Background(), store, []string{"run-1", "run-2"}); len(pending) != 1 || pending[0].ID != "fb-2" {
+		t.Errorf("pending after consumption = %+v", pending)
+	}
+}
@@ -0,0 +1,114 @@
+// Package hitl 人在环路（HITL）领域 - 执行审批闸门
+//
+// 节点在 Agent 需要人工审批时暂停执行并上报 approval_required 事件（携带节点生成的 approval_id）：
+//   - 事件入库时创建对应的 ApprovalRequest（RecordApprovalEvents）
+//   - 节点心跳携带等待中的审批 ID，API Server 返回已处理的审批结果（ApprovalOutcomes），
+//     超过有效期仍未处理的审批在此时标记为 expired
+package hitl
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ApprovalStore 审批闸门所需的存储接口
+type ApprovalStore interface {
+	CreateApprovalRequest(ctx context.Context, req *model.ApprovalRequest) error
+	GetApprovalRequest(ctx context.Context, id string) (*model.ApprovalRequest, error)
+	UpdateApprovalRequestStatus(ctx context.Context, id string, status model.ApprovalStatus) error
+}
+
+// ApprovalOutcome 已处理的审批结果（心跳指令 approvals）
+type ApprovalOutcome struct {
+	ApprovalID string               `json:"approval_id"`
+	Status     model.ApprovalStatus `json:"status"`
+}
+
+// ApprovalFromEvent 从 approval_required 事件构建审批请求
+func ApprovalFromEvent(runID string, payload json.RawMessage, now time.Time) (*model.ApprovalRequest, error) {
+	var p struct {
+		ApprovalID     string             `json:"approval_id"`
+		Type           model.ApprovalType `json:"type"`
+		Operation      string             `json:"operation"`
+		Reason         string             `json:"reason"`
+		Context        json.RawMessage    `json:"context"`
+		TimeoutSeconds int                `json:"timeout_seconds"`
+	}
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return nil, err
+	}
+	if p.ApprovalID == "" {
+		return nil, errors.New("approval_id is required")
+	}
+	if p.Type == "" {
+		p.Type = model.ApprovalTypeDangerousOp
+	}
+	req := &model.ApprovalRequest{
+		ID:        p.ApprovalID,
+		RunID:     runID,
+		Type:      p.Type,
+		Status:    model.ApprovalStatusPending,
+		Operation: p.Operation,
+		Reason:    p.Reason,
+		Context:   p.Context,
+		CreatedAt: now,
+	}
+	if p.TimeoutSeconds > 0 {
+		expires := now.Add(time.Duration(p.TimeoutSeconds) * time.Second)
+		req.ExpiresAt = &expires
+	}
+	return req, nil
+}
+
+// RecordApprovalEvents 为 approval_required 事件创建审批请求
+//
+// 审批请求已存在时跳过（节点重试上报时保持幂等），失败只记录日志，不影响事件入库。
+func RecordApprovalEvents(ctx context.Context, store ApprovalStore, runID string, events []*model.Event) {
+	for _, e := range events {
+		if e.Type != string(model.EventTypeApprovalRequired) {
+			continue
+		}
+		req, err := ApprovalFromEvent(runID, e.Payload, e.Timestamp)
+		if err != nil {
+			slog.ErrorContext(ctx, "hitl.approval.invalid", "run_id", runID, "seq", e.Seq, "error", err)
+			continue
+		}
+		if existing, err := store.GetApprovalRequest(ctx, req.ID); err == nil && existing != nil {
+			continue
+		}
+		if err := store.CreateApprovalRequest(ctx, req); err != nil {
+			slog.ErrorContext(ctx, "hitl.approval.create.failed", "run_id", runID, "approval_id", req.ID, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "hitl.approval.created", "run_id", runID, "approval_id", req.ID, "operation", req.Operation)
+	}
+}
+
+// ApprovalOutcomes 返回节点等待中的审批里已处理的结果，过期的待处理审批标记为 expired
+func ApprovalOutcomes(ctx context.Context, store ApprovalStore, approvalIDs []string, now time.Time) []ApprovalOutcome {
+	var outcomes []ApprovalOutcome
+	for _, id := range approvalIDs {
+		req, err := store.GetApprovalRequest(ctx, id)
+		if err != nil || req == nil {
+			continue
+		}
+		status := req.Status
+		if status == model.ApprovalStatusPending {
+			if req.ExpiresAt == nil || now.Before(*req.ExpiresAt) {
+				continue
+			}
+			if err := store.UpdateApprovalRequestStatus(ctx, id, model.ApprovalStatusExpired); err != nil {
+				slog.ErrorContext(ctx, "hitl.approval.expire.failed", "approval_id", id, "error", err)
+				continue
+			}
+			status = model.ApprovalStatusExpired
+		}
+		outcomes = append(outcomes, ApprovalOutcome{ApprovalID: id, Status: status})
+	}
+	return outcomes
+}
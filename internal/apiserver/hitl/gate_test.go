@@ -0,0 +1,66 @@
+package hitl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+type mockApprovalStore struct {
+	approvals map[string]*model.ApprovalRequest
+	creates   int
+}
+
+func (m *mockApprovalStore) CreateApprovalRequest(_ context.Context, req *model.ApprovalRequest) error {
+	m.creates++
+	m.approvals[req.ID] = req
+	return nil
+}
+
+func (m *mockApprovalStore) GetApprovalRequest(_ context.Context, id string) (*model.ApprovalRequest, error) {
+	return m.approvals[id], nil
+}
+
+func (m *mockApprovalStore) UpdateApprovalRequestStatus(_ context.Context, id string, status model.ApprovalStatus) error {
+	m.approvals[id].Status = status
+	return nil
+}
+
+func TestRecordApprovalEvents(t *testing.T) {
+	store := &mockApprovalStore{approvals: map[string]*model.ApprovalRequest{}}
+	now := time.Unix(1700000000, 0)
+	payload, _ := json.Marshal(map[string]interface{}{
+		"approval_id": "apr-run-1-5", "operation": "run_shell_command", "reason": "needs review",
+		"context": map[string]interface{}{"tool": "run_shell_command"}, "timeout_seconds": 60,
+	})
+	events := []*model.Event{
+		{Seq: 4, Type: "tool_use_start", Payload: []byte(`{}`), Timestamp: now},
+		{Seq: 5, Type: "approval_required", Payload: payload, Timestamp: now},
+		{Seq: 6, Type: "approval_required", Payload: []byte(`{"operation":"x"}`), Timestamp: now}, // 缺少 approval_id
+	}
+
+	RecordApprovalEvents(context.Background(), store, "run-1", events)
+	RecordApprovalEvents(context.Background(), store, "run-1", events[1:2]) // 节点重试上报
+	if store.creates != 1 {
+		t.Fatalf("creates = %d, want 1", store.creates)
+	}
+	req := store.approvals["apr-run-1-5"]
+	if req.RunID != "run-1" || req.Status != model.ApprovalStatusPending || req.Type != model.ApprovalTypeDangerousOp || req.Operation != "run_shell_command" {
+		t.Errorf("request = %+v", req)
+	}
+	if req.ExpiresAt == nil || !req.ExpiresAt.Equal(now.Add(time.Minute)) {
+		t.Errorf("expires_at = %v", req.ExpiresAt)
+	}
+
+	outcomes := ApprovalOutcomes(context.Background(), store, []string{"apr-run-1-5"}, now)
+	if len(outcomes) != 0 {
+		t.Errorf("pending approval should not be delivered: %v", outcomes)
+	}
+	outcomes = ApprovalOutcomes(context.Background(), store, []string{"apr-run-1-5"}, now.Add(2*time.Minute))
+	if len(outcomes) != 1 || outcomes[0].Status != model.ApprovalStatusExpired {
+		t.Errorf("outcomes = %v", outcomes)
+	}
+}
@@ -205,6 +205,8 @@ func (h *Handler) ListFeedbacks(w http.ResponseWriter, r *http.Request) {
 
 // CreateFeedback 提交人工反馈
 // POST /api/v1/runs/{id}/feedbacks
+//
+// 反馈随执行节点的下一次心跳注入运行中的 Agent（见 feedback.go），已结束的 Run 不再接收反馈。
 func (h *Handler) CreateFeedback(w http.ResponseWriter, r *http.Request) {
 	runID := r.PathValue("id")
 
@@ -217,6 +219,10 @@ func (h *Handler) CreateFeedback(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "run not found")
 		return
 	}
+	if run.IsTerminal() {
+		writeError(w, http.StatusConflict, "run has already finished")
+		return
+	}
 
 	var req createFeedbackRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
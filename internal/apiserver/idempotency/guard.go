@@ -0,0 +1,236 @@
+// Package idempotency 创建类请求的幂等键
+//
+// Guard.Wrap 包装创建接口（POST /api/v1/tasks、POST /api/v1/tasks/{id}/runs）：请求携带 Idempotency-Key 请求头时，
+// 首次请求原子地认领键，创建成功后记录资源 ID 与原始响应；有效期内以相同键重试直接返回原始响应
+// （响应头 Idempotent-Replayed: true），不会重复创建。
+//
+//   - 首次请求仍在处理时，相同键的请求返回 409
+//   - 同一个键用于不同的请求体时返回 422
+//   - 处理失败（非 2xx）时释放键，客户端可用同一个键重试
+//
+// 键按调用方（认证用户）与请求路径隔离。未携带请求头的请求不受影响。
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// HeaderKey 客户端提供幂等键的请求头
+	HeaderKey = "Idempotency-Key"
+	// HeaderReplayed 标记重放响应的响应头
+	HeaderReplayed = "Idempotent-Replayed"
+
+	// DefaultTTL 幂等键默认有效期
+	DefaultTTL = 24 * time.Hour
+
+	// maxKeyLength 幂等键最大长度
+	maxKeyLength = 255
+	// maxBodySize 携带幂等键的请求体上限（需完整读取以计算摘要）
+	maxBodySize = 10 << 20
+	// cleanupInterval 过期记录的清理间隔
+	cleanupInterval = time.Hour
+	// writeTimeout 请求结束后写入幂等记录的超时时间
+	writeTimeout = 5 * time.Second
+)
+
+// Store 幂等键需要的存储接口
+type Store interface {
+	ClaimIdempotencyKey(ctx context.Context, k *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, scope, key, resourceID string, statusCode int, response []byte) error
+	DeleteIdempotencyKey(ctx context.Context, scope, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Guard 创建类请求的幂等保护
+type Guard struct {
+	store Store
+	ttl   time.Duration
+	now   func() time.Time
+}
+
+// NewGuard 创建幂等保护（ttl <= 0 时使用 DefaultTTL）
+func NewGuard(store Store, ttl time.Duration) *Guard {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	return &Guard{store: store, ttl: ttl, now: time.Now}
+}
+
+// Wrap 包装创建接口（g 为 nil 时原样返回）
+func (g *Guard) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	if g == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(HeaderKey)
+		if key == "" {
+			next(w, r)
+			return
+		}
+		if len(key) > maxKeyLength {
+			writeError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+			return
+		}
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if bodylimit.IsTooLarge(err) {
+			bodylimit.WriteTooLarge(w, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		now := g.now()
+		claim := &model.IdempotencyKey{
+			Scope:       scopeOf(r),
+			Key:         key,
+			RequestHash: requestHash(body),
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(g.ttl),
+		}
+		existing, err := g.store.ClaimIdempotencyKey(r.Context(), claim)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "idempotency.claim.failed", "scope", claim.Scope, "key", key, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to check idempotency key")
+			return
+		}
+		if existing != nil {
+			replay(w, r, existing, claim)
+			return
+		}
+
+		rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		done := false
+		defer func() {
+			// 处理器 panic 时释放键
+			if !done {
+				g.release(r.Context(), claim)
+			}
+		}()
+		next(rw, r)
+		done = true
+
+		if rw.status < http.StatusOK || rw.status >= http.StatusMultipleChoices {
+			g.release(r.Context(), claim)
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(r.Context()), writeTimeout)
+		defer cancel()
+		resourceID := responseID(rw.body.Bytes())
+		if err := g.store.CompleteIdempotencyKey(ctx, claim.Scope, key, resourceID, rw.status, rw.body.Bytes()); err != nil {
+			// 未记录响应的键会让重试一直得到 409，释放后重试按新请求处理
+			slog.ErrorContext(ctx, "idempotency.complete.failed", "scope", claim.Scope, "key", key, "resource_id", resourceID, "error", err)
+			g.release(ctx, claim)
+		}
+	}
+}
+
+// replay 处理已被认领的键：返回原始响应，或说明不能重放的原因
+func replay(w http.ResponseWriter, r *http.Request, existing, claim *model.IdempotencyKey) {
+	switch {
+	case existing.RequestHash != claim.RequestHash:
+		writeError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different request")
+	case !existing.Completed():
+		writeError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+	default:
+		slog.InfoContext(r.Context(), "idempotency.replay", "scope", claim.Scope, "key", claim.Key, "resource_id", existing.ResourceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(HeaderReplayed, "true")
+		w.WriteHeader(existing.StatusCode)
+		w.Write(existing.Response)
+	}
+}
+
+// release 释放幂等键
+func (g *Guard) release(ctx context.Context, claim *model.IdempotencyKey) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), writeTimeout)
+	defer cancel()
+	if err := g.store.DeleteIdempotencyKey(ctx, claim.Scope, claim.Key); err != nil {
+		slog.ErrorContext(ctx, "idempotency.release.failed", "scope", claim.Scope, "key", claim.Key, "error", err)
+	}
+}
+
+// Start 定期清理过期的幂等键，直到 ctx 结束
+func (g *Guard) Start(ctx context.Context) {
+	if g == nil {
+		return
+	}
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := g.store.DeleteExpiredIdempotencyKeys(ctx, g.now()); err != nil {
+				slog.ErrorContext(ctx, "idempotency.cleanup.failed", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "idempotency.cleanup.success", "deleted", n)
+			}
+		}
+	}
+}
+
+// scopeOf 键的作用域：认证用户与请求路径（未启用认证时为 anonymous）
+func scopeOf(r *http.Request) string {
+	principal := "anonymous"
+	if user := auth.GetAuthUser(r.Context()); user != nil {
+		principal = user.ID
+	}
+	return principal + " " + r.Method + " " + r.URL.Path
+}
+
+// requestHash 请求体的 SHA-256 摘要
+func requestHash(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// responseID 从创建类响应体中读取资源 ID（顶层 id 字段）
+func responseID(body []byte) string {
+	var resp struct {
+		ID string `json:"id"`
+	}
+	if json.Unmarshal(body, &resp) != nil {
+		return ""
+	}
+	return resp.ID
+}
+
+// responseRecorder 记录响应状态码与响应体
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (rw *responseRecorder) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseRecorder) Write(p []byte) (int, error) {
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,165 @@
+package idempotency
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存实现的幂等键存储
+type memStore struct {
+	mu   sync.Mutex
+	keys map[string]*model.IdempotencyKey
+}
+
+func newMemStore() *memStore {
+	return &memStore{keys: map[string]*model.IdempotencyKey{}}
+}
+
+func (m *memStore) ClaimIdempotencyKey(_ context.Context, k *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := k.Scope + "|" + k.Key
+	if existing, ok := m.keys[id]; ok && existing.ExpiresAt.After(time.Now()) {
+		copied := *existing
+		return &copied, nil
+	}
+	copied := *k
+	m.keys[id] = &copied
+	return nil, nil
+}
+
+func (m *memStore) CompleteIdempotencyKey(_ context.Context, scope, key, resourceID string, statusCode int, response []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if k, ok := m.keys[scope+"|"+key]; ok {
+		k.ResourceID, k.StatusCode, k.Response = resourceID, statusCode, append([]byte(nil), response...)
+	}
+	return nil
+}
+
+func (m *memStore) DeleteIdempotencyKey(_ context.Context, scope, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.keys, scope+"|"+key)
+	return nil
+}
+
+func (m *memStore) DeleteExpiredIdempotencyKeys(_ context.Context, before time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for id, k := range m.keys {
+		if k.ExpiresAt.Before(before) {
+			delete(m.keys, id)
+			n++
+		}
+	}
+	return n, nil
+}
+
+// countingHandler 每次调用创建一个新资源
+func countingHandler(calls *int, status int) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		*calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprintf(w, `{"id":"task-%d"}`, *calls)
+	}
+}
+
+func post(h http.HandlerFunc, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tasks", strings.NewReader(body))
+	if key != "" {
+		req.Header.Set(HeaderKey, key)
+	}
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w
+}
+
+func TestGuard_ReplaysOriginalResponse(t *testing.T) {
+	store := newMemStore()
+	calls := 0
+	h := NewGuard(store, time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	first := post(h, "ci-build-42", `{"name":"build"}`)
+	require.Equal(t, http.StatusCreated, first.Code)
+	assert.Empty(t, first.Header().Get(HeaderReplayed))
+
+	second := post(h, "ci-build-42", `{"name":"build"}`)
+	assert.Equal(t, http.StatusCreated, second.Code)
+	assert.Equal(t, "true", second.Header().Get(HeaderReplayed))
+	assert.JSONEq(t, first.Body.String(), second.Body.String())
+	assert.Equal(t, 1, calls, "replay must not create the resource again")
+	assert.Equal(t, "task-1", store.keys["anonymous POST /api/v1/tasks|ci-build-42"].ResourceID)
+
+	// 不同的键、未携带键的请求正常创建
+	post(h, "ci-build-43", `{"name":"build"}`)
+	post(h, "", `{"name":"build"}`)
+	assert.Equal(t, 3, calls)
+}
+
+func TestGuard_RejectsMismatchAndInFlight(t *testing.T) {
+	store := newMemStore()
+	calls := 0
+	h := NewGuard(store, time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	post(h, "k1", `{"name":"a"}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, post(h, "k1", `{"name":"b"}`).Code)
+
+	// 认领后尚未记录响应：视为首次请求仍在处理
+	_, err := store.ClaimIdempotencyKey(context.Background(), &model.IdempotencyKey{
+		Scope: "anonymous POST /api/v1/tasks", Key: "k2", RequestHash: requestHash([]byte(`{}`)), ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, post(h, "k2", `{}`).Code)
+	assert.Equal(t, 1, calls)
+
+	assert.Equal(t, http.StatusBadRequest, post(h, strings.Repeat("k", maxKeyLength+1), `{}`).Code)
+}
+
+func TestGuard_ReleasesKeyOnFailure(t *testing.T) {
+	store := newMemStore()
+	calls := 0
+	failing := NewGuard(store, time.Hour).Wrap(countingHandler(&calls, http.StatusInternalServerError))
+	assert.Equal(t, http.StatusInternalServerError, post(failing, "k1", `{}`).Code)
+	assert.Empty(t, store.keys, "failed request must release the key")
+
+	ok := NewGuard(store, time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+	w := post(ok, "k1", `{}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(HeaderReplayed))
+}
+
+func TestGuard_ScopedByPath(t *testing.T) {
+	store := newMemStore()
+	calls := 0
+	h := NewGuard(store, time.Hour).Wrap(countingHandler(&calls, http.StatusCreated))
+
+	for _, path := range []string{"/api/v1/tasks/task-1/runs", "/api/v1/tasks/task-2/runs"} {
+		req := httptest.NewRequest(http.MethodPost, path, nil)
+		req.Header.Set(HeaderKey, "same")
+		h(httptest.NewRecorder(), req)
+	}
+	assert.Equal(t, 2, calls)
+}
+
+func TestGuard_NilPassesThrough(t *testing.T) {
+	calls := 0
+	var g *Guard
+	post(g.Wrap(countingHandler(&calls, http.StatusCreated)), "k1", `{}`)
+	assert.Equal(t, 1, calls)
+}
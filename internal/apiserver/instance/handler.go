@@ -6,7 +6,7 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -30,6 +30,7 @@ func NewHandler(store storage.PersistentStore) *Handler {
 // RegisterRoutes 注册 Agent 实例相关路由
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/agents", h.List)
+	mux.HandleFunc("GET /api/v1/agents/pools", h.Pools)
 	mux.HandleFunc("POST /api/v1/agents", h.Create)
 	mux.HandleFunc("GET /api/v1/agents/{id}", h.Get)
 	mux.HandleFunc("DELETE /api/v1/agents/{id}", h.Delete)
@@ -45,7 +46,7 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	instances, err := h.store.ListAgentInstances(r.Context())
 	if err != nil {
-		log.Printf("[instance] Failed to list instances: %v", err)
+		slog.ErrorContext(r.Context(), "instance", "detail", "Failed to list instances", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list instances")
 		return
 	}
@@ -94,7 +95,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 
 	account, err := h.store.GetAccount(r.Context(), req.AccountID)
 	if err != nil {
-		log.Printf("[instance] Failed to get account %s: %v", req.AccountID, err)
+		slog.ErrorContext(r.Context(), "instance.create.failed", "account_id", req.AccountID, "op", "GetAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get account")
 		return
 	}
@@ -123,7 +124,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.TemplateID != nil && *req.TemplateID != "" {
 		tmpl, err := h.store.GetAgentTemplate(r.Context(), *req.TemplateID)
 		if err != nil {
-			log.Printf("[instance] Failed to get template %s: %v", *req.TemplateID, err)
+			slog.ErrorContext(r.Context(), "instance.create.failed", "template_id", *req.TemplateID, "op", "GetTemplate", "error", err)
 			writeError(w, http.StatusInternalServerError, "failed to get template")
 			return
 		}
@@ -152,12 +153,12 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.CreateAgentInstance(r.Context(), instance); err != nil {
-		log.Printf("[agent] Failed to create agent: %v", err)
+		slog.ErrorContext(r.Context(), "agent", "detail", "Failed to create agent", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create agent")
 		return
 	}
 
-	log.Printf("[agent] Agent created: %s (account=%s, template=%v)", agentID, req.AccountID, req.TemplateID)
+	slog.InfoContext(r.Context(), "instance.created", "instance_id", agentID, "account_id", req.AccountID, "template_id", req.TemplateID)
 	writeJSON(w, http.StatusCreated, instance)
 }
 
@@ -167,7 +168,7 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 
 	instance, err := h.store.GetAgentInstance(r.Context(), id)
 	if err != nil {
-		log.Printf("[agent] Failed to get agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.get.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get agent")
 		return
 	}
@@ -185,7 +186,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	instance, err := h.store.GetAgentInstance(r.Context(), id)
 	if err != nil {
-		log.Printf("[agent] Failed to get agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.get.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get agent")
 		return
 	}
@@ -195,12 +196,12 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.DeleteAgentInstance(r.Context(), id); err != nil {
-		log.Printf("[agent] Failed to delete agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.delete.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to delete agent")
 		return
 	}
 
-	log.Printf("[agent] Agent deleted: %s", id)
+	slog.InfoContext(r.Context(), "instance.deleted", "instance_id", id)
 	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "agent deleted"})
 }
 
@@ -210,7 +211,7 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 
 	instance, err := h.store.GetAgentInstance(r.Context(), id)
 	if err != nil {
-		log.Printf("[agent] Failed to get agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.get.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get agent")
 		return
 	}
@@ -220,12 +221,12 @@ func (h *Handler) Start(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.UpdateAgentInstance(r.Context(), id, model.InstanceStatusPending, nil); err != nil {
-		log.Printf("[agent] Failed to update agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.update.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to start agent")
 		return
 	}
 
-	log.Printf("[agent] Agent start requested: %s", id)
+	slog.InfoContext(r.Context(), "instance.start.requested", "instance_id", id)
 	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "agent start requested"})
 }
 
@@ -235,7 +236,7 @@ func (h *Handler) Stop(w http.ResponseWriter, r *http.Request) {
 
 	instance, err := h.store.GetAgentInstance(r.Context(), id)
 	if err != nil {
-		log.Printf("[agent] Failed to get agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.get.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get agent")
 		return
 	}
@@ -245,12 +246,12 @@ func (h *Handler) Stop(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.UpdateAgentInstance(r.Context(), id, model.InstanceStatusStopping, nil); err != nil {
-		log.Printf("[agent] Failed to update agent %s: %v", id, err)
+		slog.ErrorContext(r.Context(), "instance.update.failed", "instance_id", id, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to stop agent")
 		return
 	}
 
-	log.Printf("[agent] Agent stop requested: %s", id)
+	slog.InfoContext(r.Context(), "instance.stop.requested", "instance_id", id)
 	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "agent stop requested"})
 }
 
@@ -8,7 +8,7 @@ package instance
 import (
 	"database/sql"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"agents-admin/internal/shared/model"
@@ -40,7 +40,7 @@ func (h *Handler) ListByNode(w http.ResponseWriter, r *http.Request) {
 		instances, err = h.store.ListPendingAgentInstances(r.Context(), nodeID)
 	}
 	if err != nil {
-		log.Printf("[instance] Failed to list instances for node %s: %v", nodeID, err)
+		slog.ErrorContext(r.Context(), "instance.list.failed", "node_id", nodeID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list instances")
 		return
 	}
@@ -91,13 +91,12 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "agent not found")
 			return
 		}
-		log.Printf("[agent] Failed to update agent %s: %v", agentID, err)
+		slog.ErrorContext(r.Context(), "instance.update.failed", "instance_id", agentID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to update agent")
 		return
 	}
 
-	log.Printf("[agent] Agent %s updated (status=%s, container=%v)",
-		agentID, req.Status, req.ContainerName)
+	slog.InfoContext(r.Context(), "instance.updated", "instance_id", agentID, "status", req.Status, "container", req.ContainerName)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "agent updated",
@@ -0,0 +1,53 @@
+package instance
+
+import (
+	"log/slog"
+	"net/http"
+
+	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+)
+
+// Pools 获取各节点预热实例池的状态与租用统计
+// GET /api/v1/agents/pools
+//
+// 数据来自节点心跳（capacity.pools），离线与已终止节点的实例池不计入。
+// 支持 node_id、account_id 过滤。
+func (h *Handler) Pools(w http.ResponseWriter, r *http.Request) {
+	nodes, err := h.store.ListAllNodes(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "instance.pools.list.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list nodes")
+		return
+	}
+
+	nodeID := r.URL.Query().Get("node_id")
+	accountID := r.URL.Query().Get("account_id")
+
+	summary := model.InstancePoolSummary{Pools: []model.InstancePoolStatus{}}
+	for _, n := range nodes {
+		if n.Status == model.NodeStatusOffline || n.Status == model.NodeStatusTerminated {
+			continue
+		}
+		if nodeID != "" && n.ID != nodeID {
+			continue
+		}
+		for _, p := range node.GetNodePools(n) {
+			if accountID != "" && p.AccountID != accountID {
+				continue
+			}
+			p.NodeID = n.ID
+			p.NodeStatus = string(n.Status)
+			summary.Pools = append(summary.Pools, p)
+			summary.Idle += p.Idle
+			summary.Leased += p.Leased
+			summary.Warming += p.Warming
+			summary.Leases += p.Leases
+			summary.Misses += p.Misses
+		}
+	}
+	if total := summary.Leases + summary.Misses; total > 0 {
+		summary.HitRate = float64(summary.Leases) / float64(total)
+	}
+	writeJSON(w, http.StatusOK, summary)
+}
@@ -0,0 +1,529 @@
+// Package integration 外部 Issue 集成领域 - HTTP 处理
+//
+// 接入 GitHub 仓库或 Jira 项目，将选中的 Issue 导入为 Task：
+//   - 标题 → Task.Name，正文 → Task.Prompt，标签 → Task.Labels
+//   - 导入时记录 IssueLink（任务 → 源 Issue 的反向关联），同一 Issue 不会重复导入
+//   - 集成开启 comment_results 时，Run 结束后将结果以评论形式回写到源 Issue
+//
+// 访问外部平台的 Token 引用 /api/v1/credentials 中的凭据（credential_ref），
+// 由本包使用主密钥解密后直接调用平台 API，明文不出现在任何响应中。
+package integration
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// maxImportIssues 单次导入的 Issue 数量上限
+	maxImportIssues = 50
+
+	// maxTaskNameRunes 导入任务名称的最大长度（超出截断）
+	maxTaskNameRunes = 200
+
+	// commentTimeout 回写评论的超时时间
+	commentTimeout = 30 * time.Second
+)
+
+// Store 定义集成 handler 需要的存储接口（用于测试 mock）
+type Store interface {
+	CreateIntegration(ctx context.Context, i *model.Integration) error
+	GetIntegration(ctx context.Context, id string) (*model.Integration, error)
+	ListIntegrations(ctx context.Context) ([]*model.Integration, error)
+	UpdateIntegration(ctx context.Context, i *model.Integration) error
+	DeleteIntegration(ctx context.Context, id string) error
+	CreateIssueLink(ctx context.Context, link *model.IssueLink) error
+	GetIssueLinkByTask(ctx context.Context, taskID string) (*model.IssueLink, error)
+	GetIssueLinkByIssue(ctx context.Context, integrationID, issueKey string) (*model.IssueLink, error)
+	CreateTask(ctx context.Context, task *model.Task) error
+	GetCredentialByName(ctx context.Context, name string) (*model.Credential, error)
+	ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error)
+}
+
+// Handler 集成领域 HTTP 处理器
+type Handler struct {
+	store     Store
+	encrypted bool // 存储层是否加密凭据（fieldcrypt.WrapStore），未启用时需要凭据的集成无法访问外部平台
+	client    *http.Client
+}
+
+// NewHandler 创建集成处理器，encrypted 表示 store 已由 fieldcrypt.WrapStore 包装
+func NewHandler(store Store, encrypted bool) *Handler {
+	return &Handler{store: store, encrypted: encrypted, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// RegisterRoutes 注册集成相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/integrations", h.List)
+	mux.HandleFunc("POST /api/v1/integrations", auth.AdminOnly(h.Create))
+	mux.HandleFunc("POST /api/v1/integrations/import", h.Import)
+	mux.HandleFunc("GET /api/v1/integrations/{id}", h.Get)
+	mux.HandleFunc("PUT /api/v1/integrations/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/integrations/{id}", auth.AdminOnly(h.Delete))
+	mux.HandleFunc("GET /api/v1/integrations/{id}/issues", h.ListIssues)
+	mux.HandleFunc("GET /api/v1/tasks/{id}/issue-link", h.GetTaskIssueLink)
+}
+
+// ============================================================================
+// 集成管理
+// ============================================================================
+
+// List 列出集成
+// GET /api/v1/integrations
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	integrations, err := h.store.ListIntegrations(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "integration", "op", "ListIntegrations", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list integrations")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"integrations": integrations})
+}
+
+// Create 创建集成
+// POST /api/v1/integrations
+//
+// 请求体:
+//
+//	{"name": "backend", "provider": "github", "repo": "acme/backend", "credential_ref": "gh-bot", "comment_results": true}
+//	{"name": "ops", "provider": "jira", "base_url": "https://acme.atlassian.net", "project_key": "OPS", "credential_ref": "jira-bot"}
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var i model.Integration
+	if err := json.NewDecoder(r.Body).Decode(&i); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := i.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	now := time.Now()
+	i.ID = generateID("intg")
+	i.BaseURL = strings.TrimRight(i.BaseURL, "/")
+	i.CreatedAt = now
+	i.UpdatedAt = now
+	if err := h.store.CreateIntegration(r.Context(), &i); err != nil {
+		slog.ErrorContext(r.Context(), "integration", "op", "CreateIntegration", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create integration")
+		return
+	}
+	slog.InfoContext(r.Context(), "integration.created", "integration_id", i.ID, "provider", i.Provider)
+	writeJSON(w, http.StatusCreated, i)
+}
+
+// Get 获取集成详情
+// GET /api/v1/integrations/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	if i, ok := h.load(w, r.Context(), r.PathValue("id")); ok {
+		writeJSON(w, http.StatusOK, i)
+	}
+}
+
+// Update 更新集成（全量替换可编辑字段，平台不可修改）
+// PUT /api/v1/integrations/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.load(w, r.Context(), r.PathValue("id"))
+	if !ok {
+		return
+	}
+	var req model.Integration
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Provider != "" && req.Provider != existing.Provider {
+		writeError(w, http.StatusBadRequest, "provider cannot be changed")
+		return
+	}
+
+	existing.Name = req.Name
+	existing.BaseURL = strings.TrimRight(req.BaseURL, "/")
+	existing.Repo = req.Repo
+	existing.ProjectKey = req.ProjectKey
+	existing.CredentialRef = req.CredentialRef
+	existing.CommentResults = req.CommentResults
+	if err := existing.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	existing.UpdatedAt = time.Now()
+	if err := h.store.UpdateIntegration(r.Context(), existing); err != nil {
+		slog.ErrorContext(r.Context(), "integration", "op", "UpdateIntegration", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update integration")
+		return
+	}
+	writeJSON(w, http.StatusOK, existing)
+}
+
+// Delete 删除集成（同时删除其 IssueLink，已导入的任务保留）
+// DELETE /api/v1/integrations/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if _, ok := h.load(w, r.Context(), r.PathValue("id")); !ok {
+		return
+	}
+	if err := h.store.DeleteIntegration(r.Context(), r.PathValue("id")); err != nil {
+		slog.ErrorContext(r.Context(), "integration", "op", "DeleteIntegration", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete integration")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ============================================================================
+// Issue 浏览与导入
+// ============================================================================
+
+// ListIssues 列出集成中可导入的未关闭 Issue（标记已导入的任务 ID）
+// GET /api/v1/integrations/{id}/issues?limit=50
+func (h *Handler) ListIssues(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	i, ok := h.load(w, ctx, r.PathValue("id"))
+	if !ok {
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	tracker, status, err := h.tracker(ctx, i)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	issues, err := tracker.ListIssues(ctx, limit)
+	if err != nil {
+		slog.ErrorContext(ctx, "integration.issues.failed", "integration_id", i.ID, "error", err)
+		writeError(w, http.StatusBadGateway, "failed to list issues: "+err.Error())
+		return
+	}
+
+	type issueItem struct {
+		*Issue
+		TaskID string `json:"task_id,omitempty"` // 已导入时为对应任务 ID
+	}
+	items := make([]issueItem, 0, len(issues))
+	for _, issue := range issues {
+		item := issueItem{Issue: issue}
+		if link, err := h.store.GetIssueLinkByIssue(ctx, i.ID, issue.Key); err == nil && link != nil {
+			item.TaskID = link.TaskID
+		}
+		items = append(items, item)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"issues": items})
+}
+
+// ImportRequest 导入 Issue 的请求体
+type ImportRequest struct {
+	// IntegrationID 来源集成 ID
+	IntegrationID string `json:"integration_id"`
+
+	// Issues 要导入的 Issue 标识（GitHub 为编号，Jira 为 Key）
+	Issues []string `json:"issues"`
+
+	// 以下字段应用到所有导入的任务
+	Type      string                 `json:"type,omitempty"`
+	AgentID   *string                `json:"agent_id,omitempty"`
+	ParentID  *string                `json:"parent_id,omitempty"`
+	Priority  model.Priority         `json:"priority,omitempty"`
+	Workspace *model.WorkspaceConfig `json:"workspace,omitempty"`
+}
+
+// ImportResult 导入结果（逐个 Issue 报告，部分失败不影响其他 Issue）
+type ImportResult struct {
+	Imported []ImportItem `json:"imported"`
+	Skipped  []ImportItem `json:"skipped"`
+	Failed   []ImportItem `json:"failed"`
+}
+
+// ImportItem 单个 Issue 的导入结果
+type ImportItem struct {
+	IssueKey string `json:"issue_key"`
+	TaskID   string `json:"task_id,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Import 将选中的 Issue 导入为任务
+// POST /api/v1/integrations/import
+//
+// 请求体:
+//
+//	{"integration_id": "intg-xxx", "issues": ["42", "43"], "agent_id": "agent-xxx", "priority": "high"}
+//
+// 已导入过的 Issue 计入 skipped；拉取或创建失败的计入 failed。
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req ImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.IntegrationID == "" {
+		writeError(w, http.StatusBadRequest, "integration_id is required")
+		return
+	}
+	if len(req.Issues) == 0 || len(req.Issues) > maxImportIssues {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("issues must contain 1-%d items", maxImportIssues))
+		return
+	}
+	if !req.Priority.IsValid() {
+		writeError(w, http.StatusBadRequest, "invalid priority: must be high, normal or low")
+		return
+	}
+
+	i, ok := h.load(w, ctx, req.IntegrationID)
+	if !ok {
+		return
+	}
+	tracker, status, err := h.tracker(ctx, i)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+
+	result := ImportResult{Imported: []ImportItem{}, Skipped: []ImportItem{}, Failed: []ImportItem{}}
+	seen := make(map[string]bool, len(req.Issues))
+	for _, key := range req.Issues {
+		key = strings.TrimPrefix(strings.TrimSpace(key), "#")
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if link, err := h.store.GetIssueLinkByIssue(ctx, i.ID, key); err != nil {
+			result.Failed = append(result.Failed, ImportItem{IssueKey: key, Error: "failed to check issue link"})
+			continue
+		} else if link != nil {
+			result.Skipped = append(result.Skipped, ImportItem{IssueKey: key, TaskID: link.TaskID, URL: link.URL, Reason: "already imported"})
+			continue
+		}
+
+		issue, err := tracker.GetIssue(ctx, key)
+		if err != nil {
+			result.Failed = append(result.Failed, ImportItem{IssueKey: key, Error: err.Error()})
+			continue
+		}
+		task, err := h.importIssue(ctx, i, issue, &req)
+		if err != nil {
+			slog.ErrorContext(ctx, "integration.import.failed", "integration_id", i.ID, "issue_key", key, "error", err)
+			result.Failed = append(result.Failed, ImportItem{IssueKey: key, Error: err.Error()})
+			continue
+		}
+		result.Imported = append(result.Imported, ImportItem{IssueKey: key, TaskID: task.ID, URL: issue.URL})
+	}
+
+	slog.InfoContext(ctx, "integration.import.done", "integration_id", i.ID,
+		"imported", len(result.Imported), "skipped", len(result.Skipped), "failed", len(result.Failed))
+	writeJSON(w, http.StatusOK, result)
+}
+
+// importIssue 将单个 Issue 创建为任务并记录反向关联
+func (h *Handler) importIssue(ctx context.Context, i *model.Integration, issue *Issue, req *ImportRequest) (*model.Task, error) {
+	taskType := model.TaskTypeGeneral
+	if req.Type != "" {
+		taskType = model.TaskType(req.Type)
+	}
+	prompt := strings.TrimSpace(issue.Body)
+	if prompt == "" {
+		prompt = issue.Title
+	}
+
+	now := time.Now()
+	task := &model.Task{
+		ID:          generateID("task"),
+		Name:        truncateRunes(issue.Title, maxTaskNameRunes),
+		Description: "Imported from " + issue.URL,
+		Status:      model.TaskStatusPending,
+		Type:        taskType,
+		Prompt:      &model.Prompt{Content: prompt},
+		Workspace:   req.Workspace,
+		Priority:    req.Priority.OrDefault(),
+		AgentID:     req.AgentID,
+		ParentID:    req.ParentID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if len(issue.Labels) > 0 {
+		task.Labels = make(map[string]string, len(issue.Labels))
+		for _, l := range issue.Labels {
+			task.Labels[l] = "true"
+		}
+	}
+	if err := h.store.CreateTask(ctx, task); err != nil {
+		return nil, errors.New("failed to create task")
+	}
+
+	link := &model.IssueLink{
+		TaskID:        task.ID,
+		IntegrationID: i.ID,
+		IssueKey:      issue.Key,
+		URL:           issue.URL,
+		CreatedAt:     now,
+	}
+	if err := h.store.CreateIssueLink(ctx, link); err != nil {
+		// 任务已创建，关联失败只影响结果回写
+		slog.ErrorContext(ctx, "integration.link.failed", "task_id", task.ID, "error", err)
+	}
+	return task, nil
+}
+
+// GetTaskIssueLink 获取任务关联的源 Issue
+// GET /api/v1/tasks/{id}/issue-link
+func (h *Handler) GetTaskIssueLink(w http.ResponseWriter, r *http.Request) {
+	link, err := h.store.GetIssueLinkByTask(r.Context(), r.PathValue("id"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "integration", "op", "GetIssueLinkByTask", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get issue link")
+		return
+	}
+	if link == nil {
+		writeError(w, http.StatusNotFound, "task is not linked to an issue")
+		return
+	}
+	writeJSON(w, http.StatusOK, link)
+}
+
+// ============================================================================
+// 结果回写
+// ============================================================================
+
+// RunFinished Run 到达终态时回写结果评论（注册到 run.Handler.OnRunFinished）
+//
+// 仅对开启 comment_results 的集成导入的任务生效；异步执行，不阻塞状态上报。
+func (h *Handler) RunFinished(run *model.Run) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), commentTimeout)
+		defer cancel()
+		if err := h.commentResult(ctx, run); err != nil {
+			slog.ErrorContext(ctx, "integration.comment.failed", "run_id", run.ID, "error", err)
+		}
+	}()
+}
+
+// commentResult 在源 Issue 下发表 Run 结果评论
+func (h *Handler) commentResult(ctx context.Context, run *model.Run) error {
+	link, err := h.store.GetIssueLinkByTask(ctx, run.TaskID)
+	if err != nil || link == nil {
+		return err
+	}
+	i, err := h.store.GetIntegration(ctx, link.IntegrationID)
+	if err != nil || i == nil || !i.CommentResults {
+		return err
+	}
+	tracker, _, err := h.tracker(ctx, i)
+	if err != nil {
+		return err
+	}
+
+	artifacts, err := h.store.ListArtifactsByRun(ctx, run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "integration.comment.artifacts_failed", "run_id", run.ID, "error", err)
+	}
+	if err := tracker.PostComment(ctx, link.IssueKey, formatResultComment(run, artifacts)); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "integration.comment.success", "run_id", run.ID, "integration_id", i.ID, "issue_key", link.IssueKey)
+	return nil
+}
+
+// formatResultComment 生成结果评论正文（GitHub 渲染为 Markdown，Jira 显示为纯文本）
+func formatResultComment(run *model.Run, artifacts []*model.Artifact) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "agents-admin run `%s` finished with status **%s**.\n", run.ID, run.Status)
+	if run.StartedAt != nil && run.FinishedAt != nil {
+		fmt.Fprintf(&b, "\nDuration: %s\n", run.FinishedAt.Sub(*run.StartedAt).Round(time.Second))
+	}
+	if run.Error != nil && *run.Error != "" {
+		fmt.Fprintf(&b, "\nError: %s\n", *run.Error)
+	}
+	if len(artifacts) > 0 {
+		b.WriteString("\nArtifacts:\n")
+		for _, a := range artifacts {
+			fmt.Fprintf(&b, "- %s (%s)\n", a.Name, a.Path)
+		}
+	}
+	return b.String()
+}
+
+// ============================================================================
+// 内部方法
+// ============================================================================
+
+// load 按 ID 加载集成，不存在时写入 404
+func (h *Handler) load(w http.ResponseWriter, ctx context.Context, id string) (*model.Integration, bool) {
+	i, err := h.store.GetIntegration(ctx, id)
+	if err != nil {
+		slog.ErrorContext(ctx, "integration", "op", "GetIntegration", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get integration")
+		return nil, false
+	}
+	if i == nil {
+		writeError(w, http.StatusNotFound, "integration not found")
+		return nil, false
+	}
+	return i, true
+}
+
+// tracker 解析集成凭据并创建平台客户端，失败时返回应使用的 HTTP 状态码
+func (h *Handler) tracker(ctx context.Context, i *model.Integration) (issueTracker, int, error) {
+	var ta trackerAuth
+	if i.CredentialRef != "" {
+		if !h.encrypted {
+			return nil, http.StatusServiceUnavailable, errors.New("encryption not configured: set MASTER_KEY or encryption.provider")
+		}
+		cred, err := h.store.GetCredentialByName(ctx, i.CredentialRef)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.New("failed to get credential")
+		}
+		if cred == nil {
+			return nil, http.StatusBadRequest, fmt.Errorf("credential %q not found", i.CredentialRef)
+		}
+		ta = trackerAuth{Username: cred.Username, Token: cred.EncryptedSecret}
+	}
+	return newTracker(i, ta, h.client), 0, nil
+}
+
+// truncateRunes 按字符截断字符串
+func truncateRunes(s string, n int) string {
+	s = strings.TrimSpace(s)
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n])
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
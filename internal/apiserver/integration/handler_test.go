@@ -0,0 +1,340 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存存储：集成、IssueLink、任务与凭据
+type mockStore struct {
+	mu           sync.Mutex
+	integrations map[string]*model.Integration
+	links        map[string]*model.IssueLink
+	tasks        map[string]*model.Task
+	creds        map[string]*model.Credential
+	artifacts    map[string][]*model.Artifact
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		integrations: map[string]*model.Integration{},
+		links:        map[string]*model.IssueLink{},
+		tasks:        map[string]*model.Task{},
+		creds:        map[string]*model.Credential{},
+		artifacts:    map[string][]*model.Artifact{},
+	}
+}
+
+func (m *mockStore) CreateIntegration(_ context.Context, i *model.Integration) error {
+	m.integrations[i.ID] = i
+	return nil
+}
+func (m *mockStore) GetIntegration(_ context.Context, id string) (*model.Integration, error) {
+	return m.integrations[id], nil
+}
+func (m *mockStore) ListIntegrations(_ context.Context) ([]*model.Integration, error) {
+	var list []*model.Integration
+	for _, i := range m.integrations {
+		list = append(list, i)
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateIntegration(_ context.Context, i *model.Integration) error {
+	m.integrations[i.ID] = i
+	return nil
+}
+func (m *mockStore) DeleteIntegration(_ context.Context, id string) error {
+	delete(m.integrations, id)
+	return nil
+}
+func (m *mockStore) CreateIssueLink(_ context.Context, link *model.IssueLink) error {
+	m.links[link.TaskID] = link
+	return nil
+}
+func (m *mockStore) GetIssueLinkByTask(_ context.Context, taskID string) (*model.IssueLink, error) {
+	return m.links[taskID], nil
+}
+func (m *mockStore) GetIssueLinkByIssue(_ context.Context, integrationID, issueKey string) (*model.IssueLink, error) {
+	for _, l := range m.links {
+		if l.IntegrationID == integrationID && l.IssueKey == issueKey {
+			return l, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockStore) CreateTask(_ context.Context, task *model.Task) error {
+	m.tasks[task.ID] = task
+	return nil
+}
+func (m *mockStore) GetCredentialByName(_ context.Context, name string) (*model.Credential, error) {
+	return m.creds[name], nil
+}
+func (m *mockStore) ListArtifactsByRun(_ context.Context, runID string) ([]*model.Artifact, error) {
+	return m.artifacts[runID], nil
+}
+
+// addCredential 保存凭据（存储层解密后的内容）
+func (m *mockStore) addCredential(t *testing.T, name, username, secret string) {
+	t.Helper()
+	m.creds[name] = &model.Credential{ID: "cred-" + name, Name: name, Username: username, EncryptedSecret: secret}
+}
+
+// fakeGitHub 模拟 GitHub Issues API
+type fakeGitHub struct {
+	mu       sync.Mutex
+	auth     []string
+	comments map[string][]string
+}
+
+func (f *fakeGitHub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.auth = append(f.auth, r.Header.Get("Authorization"))
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/app/issues":
+		w.Write([]byte(`[
+			{"number": 42, "title": "Fix login", "body": "Login fails on Safari", "html_url": "https://github.com/acme/app/issues/42", "labels": [{"name": "bug"}]},
+			{"number": 43, "title": "Bump deps", "html_url": "https://github.com/acme/app/pull/43", "pull_request": {"url": "x"}}
+		]`))
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/app/issues/42":
+		w.Write([]byte(`{"number": 42, "title": "Fix login", "body": "Login fails on Safari", "html_url": "https://github.com/acme/app/issues/42", "labels": [{"name": "bug"}, {"name": "p1"}]}`))
+	case r.Method == http.MethodGet && r.URL.Path == "/repos/acme/app/issues/44":
+		w.Write([]byte(`{"number": 44, "title": "Empty body", "html_url": "https://github.com/acme/app/issues/44"}`))
+	case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/comments"):
+		var body struct {
+			Body string `json:"body"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		key := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/repos/acme/app/issues/"), "/comments")
+		if f.comments == nil {
+			f.comments = map[string][]string{}
+		}
+		f.comments[key] = append(f.comments[key], body.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{}`))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (f *fakeGitHub) commentsFor(key string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.comments[key]
+}
+
+func setupGitHub(t *testing.T, commentResults bool) (*mockStore, *Handler, *http.ServeMux, *fakeGitHub) {
+	t.Helper()
+	gh := &fakeGitHub{}
+	srv := httptest.NewServer(gh)
+	t.Cleanup(srv.Close)
+
+	store := newMockStore()
+	store.addCredential(t, "gh-bot", "", "ghp_secret")
+	store.integrations["intg-gh"] = &model.Integration{
+		ID: "intg-gh", Name: "app", Provider: model.IntegrationGitHub, BaseURL: srv.URL,
+		Repo: "acme/app", CredentialRef: "gh-bot", CommentResults: commentResults,
+	}
+	h := NewHandler(store, true)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return store, h, mux, gh
+}
+
+func doRequest(mux *http.ServeMux, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	auth.Middleware(auth.Config{})(mux).ServeHTTP(w, req) // 无认证模式（未配置 JWT 密钥）
+	return w
+}
+
+func TestCreateIntegration_Validation(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(newMockStore(), true).RegisterRoutes(mux)
+
+	cases := map[string]string{
+		"bad provider":     `{"name":"x","provider":"gitlab"}`,
+		"bad repo":         `{"name":"x","provider":"github","repo":"acme"}`,
+		"jira no base_url": `{"name":"x","provider":"jira","project_key":"OPS"}`,
+		"jira bad key":     `{"name":"x","provider":"jira","base_url":"https://j.example.com","project_key":"ops"}`,
+	}
+	for name, body := range cases {
+		if w := doRequest(mux, "POST", "/api/v1/integrations", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d: %s", name, w.Code, w.Body.String())
+		}
+	}
+
+	w := doRequest(mux, "POST", "/api/v1/integrations", `{"name":"app","provider":"github","repo":"acme/app"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+// TestCreateIntegration_RejectsNode 节点凭证不能修改集成
+func TestCreateIntegration_RejectsNode(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(newMockStore(), true).RegisterRoutes(mux)
+
+	req := httptest.NewRequest("POST", "/api/v1/integrations", strings.NewReader(`{"name":"app","provider":"github","repo":"acme/app"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req.WithContext(auth.WithNodeIdentity(req.Context(), "node-1")))
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("expected 403, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestListIssues_GitHubSkipsPullRequests(t *testing.T) {
+	_, _, mux, gh := setupGitHub(t, false)
+
+	w := doRequest(mux, "GET", "/api/v1/integrations/intg-gh/issues", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Issues []struct {
+			Key string `json:"key"`
+		} `json:"issues"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Issues) != 1 || resp.Issues[0].Key != "42" {
+		t.Errorf("expected only issue 42, got %+v", resp.Issues)
+	}
+	if gh.auth[0] != "Bearer ghp_secret" {
+		t.Errorf("expected decrypted bearer token, got %q", gh.auth[0])
+	}
+}
+
+func TestImport_MapsIssueToTaskAndDeduplicates(t *testing.T) {
+	store, _, mux, _ := setupGitHub(t, false)
+
+	body := `{"integration_id":"intg-gh","issues":["42","#44","999"],"priority":"high"}`
+	w := doRequest(mux, "POST", "/api/v1/integrations/import", body)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result ImportResult
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if len(result.Imported) != 2 || len(result.Failed) != 1 || result.Failed[0].IssueKey != "999" {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+
+	task := store.tasks[result.Imported[0].TaskID]
+	if task.Name != "Fix login" || task.Prompt.Content != "Login fails on Safari" {
+		t.Errorf("unexpected task mapping: name=%q prompt=%q", task.Name, task.Prompt.Content)
+	}
+	if task.Labels["bug"] != "true" || task.Labels["p1"] != "true" {
+		t.Errorf("expected labels bug/p1, got %v", task.Labels)
+	}
+	if task.Priority != model.PriorityHigh {
+		t.Errorf("expected priority high, got %s", task.Priority)
+	}
+	if empty := store.tasks[result.Imported[1].TaskID]; empty.Prompt.Content != "Empty body" {
+		t.Errorf("expected title as prompt for empty body, got %q", empty.Prompt.Content)
+	}
+
+	link := store.links[task.ID]
+	if link == nil || link.IssueKey != "42" || link.URL != "https://github.com/acme/app/issues/42" {
+		t.Fatalf("expected issue link for task, got %+v", link)
+	}
+	w = doRequest(mux, "GET", "/api/v1/tasks/"+task.ID+"/issue-link", "")
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for issue link, got %d", w.Code)
+	}
+
+	// 再次导入同一 Issue 被跳过
+	w = doRequest(mux, "POST", "/api/v1/integrations/import", `{"integration_id":"intg-gh","issues":["42"]}`)
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if len(result.Imported) != 0 || len(result.Skipped) != 1 || result.Skipped[0].TaskID != task.ID {
+		t.Errorf("expected issue 42 to be skipped, got %+v", result)
+	}
+}
+
+func TestImport_NoEncryption(t *testing.T) {
+	store, _, _, _ := setupGitHub(t, false)
+	mux := http.NewServeMux()
+	NewHandler(store, false).RegisterRoutes(mux)
+
+	w := doRequest(mux, "POST", "/api/v1/integrations/import", `{"integration_id":"intg-gh","issues":["42"]}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+}
+
+func TestRunFinished_CommentsResult(t *testing.T) {
+	store, h, _, gh := setupGitHub(t, true)
+	store.links["task-1"] = &model.IssueLink{TaskID: "task-1", IntegrationID: "intg-gh", IssueKey: "42"}
+	store.artifacts["run-1"] = []*model.Artifact{{Name: "patch.diff", Path: "runs/run-1/patch.diff"}}
+
+	errMsg := "exit code 1"
+	run := &model.Run{ID: "run-1", TaskID: "task-1", Status: model.RunStatusFailed, Error: &errMsg}
+	if err := h.commentResult(context.Background(), run); err != nil {
+		t.Fatal(err)
+	}
+	comments := gh.commentsFor("42")
+	if len(comments) != 1 {
+		t.Fatalf("expected 1 comment, got %d", len(comments))
+	}
+	for _, want := range []string{"run-1", "failed", "exit code 1", "patch.diff"} {
+		if !strings.Contains(comments[0], want) {
+			t.Errorf("comment missing %q: %s", want, comments[0])
+		}
+	}
+
+	// 关闭 comment_results 后不再回写
+	store.integrations["intg-gh"].CommentResults = false
+	h.RunFinished(run)
+	time.Sleep(50 * time.Millisecond)
+	if len(gh.commentsFor("42")) != 1 {
+		t.Error("expected no comment when comment_results is disabled")
+	}
+}
+
+func TestJiraTracker(t *testing.T) {
+	var gotAuth, gotComment string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/rest/api/2/issue/OPS-7":
+			w.Write([]byte(`{"key":"OPS-7","fields":{"summary":"Rotate certs","description":"Certs expire soon","labels":["infra"]}}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/rest/api/2/issue/OPS-7/comment":
+			var body struct {
+				Body string `json:"body"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			gotComment = body.Body
+			w.WriteHeader(http.StatusCreated)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	i := &model.Integration{Provider: model.IntegrationJira, BaseURL: srv.URL, ProjectKey: "OPS"}
+	tracker := newTracker(i, trackerAuth{Username: "bot@acme.com", Token: "tok"}, srv.Client())
+
+	issue, err := tracker.GetIssue(context.Background(), "OPS-7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if issue.Title != "Rotate certs" || issue.Body != "Certs expire soon" || issue.URL != srv.URL+"/browse/OPS-7" {
+		t.Errorf("unexpected issue: %+v", issue)
+	}
+	if !strings.HasPrefix(gotAuth, "Basic ") {
+		t.Errorf("expected basic auth with username, got %q", gotAuth)
+	}
+	if _, err := tracker.GetIssue(context.Background(), "OTHER-1"); err == nil {
+		t.Error("expected error for issue outside project")
+	}
+	if err := tracker.PostComment(context.Background(), "OPS-7", "done"); err != nil || gotComment != "done" {
+		t.Errorf("expected comment posted, err=%v comment=%q", err, gotComment)
+	}
+}
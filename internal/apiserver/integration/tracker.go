@@ -0,0 +1,275 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// defaultGitHubAPI GitHub API 默认地址（GitHub Enterprise 在集成中配置 base_url）
+const defaultGitHubAPI = "https://api.github.com"
+
+// Issue 外部平台的 Issue（导入为 Task 的来源）
+type Issue struct {
+	Key    string   `json:"key"`    // GitHub 为编号，Jira 为 Issue Key
+	Title  string   `json:"title"`  // → Task.Name
+	Body   string   `json:"body"`   // → Task.Prompt
+	Labels []string `json:"labels"` // → Task.Labels
+	URL    string   `json:"url"`    // Issue 网页地址
+}
+
+// issueTracker 外部 Issue 平台的最小访问接口
+type issueTracker interface {
+	// ListIssues 列出可导入的未关闭 Issue
+	ListIssues(ctx context.Context, limit int) ([]*Issue, error)
+	// GetIssue 获取单个 Issue，不存在时返回 errIssueNotFound
+	GetIssue(ctx context.Context, key string) (*Issue, error)
+	// PostComment 在 Issue 下发表评论
+	PostComment(ctx context.Context, key, body string) error
+}
+
+// trackerAuth 访问外部平台的认证信息（来自凭据明文）
+type trackerAuth struct {
+	Username string // Jira Basic 认证用户名（邮箱），为空时使用 Bearer
+	Token    string
+}
+
+// newTracker 按集成平台创建访问客户端
+func newTracker(i *model.Integration, auth trackerAuth, client *http.Client) issueTracker {
+	if i.Provider == model.IntegrationJira {
+		return &jiraTracker{baseURL: strings.TrimRight(i.BaseURL, "/"), project: i.ProjectKey, auth: auth, client: client}
+	}
+	base := strings.TrimRight(i.BaseURL, "/")
+	if base == "" {
+		base = defaultGitHubAPI
+	}
+	return &githubTracker{apiURL: base, repo: i.Repo, auth: auth, client: client}
+}
+
+// ============================================================================
+// GitHub
+// ============================================================================
+
+// githubTracker GitHub REST API 客户端
+type githubTracker struct {
+	apiURL string
+	repo   string // owner/repo
+	auth   trackerAuth
+	client *http.Client
+}
+
+// githubIssue GitHub Issue 响应（仅解析所需字段）
+type githubIssue struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	HTMLURL string `json:"html_url"`
+	Labels  []struct {
+		Name string `json:"name"`
+	} `json:"labels"`
+	PullRequest json.RawMessage `json:"pull_request,omitempty"` // 非空表示该条目是 Pull Request
+}
+
+func (g *githubIssue) toIssue() *Issue {
+	issue := &Issue{Key: strconv.Itoa(g.Number), Title: g.Title, Body: g.Body, URL: g.HTMLURL}
+	for _, l := range g.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+	return issue
+}
+
+func (t *githubTracker) ListIssues(ctx context.Context, limit int) ([]*Issue, error) {
+	var items []githubIssue
+	path := fmt.Sprintf("/repos/%s/issues?state=open&per_page=%d", t.repo, limit)
+	if err := t.do(ctx, http.MethodGet, path, nil, &items); err != nil {
+		return nil, err
+	}
+	issues := []*Issue{}
+	for i := range items {
+		// Issues 接口同时返回 Pull Request，导入只针对 Issue
+		if len(items[i].PullRequest) > 0 {
+			continue
+		}
+		issues = append(issues, items[i].toIssue())
+	}
+	return issues, nil
+}
+
+func (t *githubTracker) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	if _, err := strconv.Atoi(key); err != nil {
+		return nil, fmt.Errorf("invalid github issue number: %q", key)
+	}
+	var item githubIssue
+	if err := t.do(ctx, http.MethodGet, "/repos/"+t.repo+"/issues/"+key, nil, &item); err != nil {
+		return nil, err
+	}
+	if len(item.PullRequest) > 0 {
+		return nil, fmt.Errorf("#%s is a pull request, not an issue", key)
+	}
+	return item.toIssue(), nil
+}
+
+func (t *githubTracker) PostComment(ctx context.Context, key, body string) error {
+	return t.do(ctx, http.MethodPost, "/repos/"+t.repo+"/issues/"+key+"/comments", map[string]string{"body": body}, nil)
+}
+
+func (t *githubTracker) do(ctx context.Context, method, path string, in, out interface{}) error {
+	header := http.Header{}
+	header.Set("Accept", "application/vnd.github+json")
+	if t.auth.Token != "" {
+		header.Set("Authorization", "Bearer "+t.auth.Token)
+	}
+	return doJSON(ctx, t.client, method, t.apiURL+path, header, in, out)
+}
+
+// ============================================================================
+// Jira
+// ============================================================================
+
+// jiraTracker Jira REST API v2 客户端（v2 的 description/comment 为纯文本，无需处理 ADF）
+type jiraTracker struct {
+	baseURL string
+	project string
+	auth    trackerAuth
+	client  *http.Client
+}
+
+// jiraIssue Jira Issue 响应（仅解析所需字段）
+type jiraIssue struct {
+	Key    string `json:"key"`
+	Fields struct {
+		Summary     string   `json:"summary"`
+		Description string   `json:"description"`
+		Labels      []string `json:"labels"`
+	} `json:"fields"`
+}
+
+func (t *jiraTracker) toIssue(j *jiraIssue) *Issue {
+	return &Issue{
+		Key:    j.Key,
+		Title:  j.Fields.Summary,
+		Body:   j.Fields.Description,
+		Labels: j.Fields.Labels,
+		URL:    t.baseURL + "/browse/" + j.Key,
+	}
+}
+
+func (t *jiraTracker) ListIssues(ctx context.Context, limit int) ([]*Issue, error) {
+	q := url.Values{}
+	q.Set("jql", fmt.Sprintf("project = %s AND statusCategory != Done ORDER BY created DESC", t.project))
+	q.Set("fields", "summary,description,labels")
+	q.Set("maxResults", strconv.Itoa(limit))
+	var resp struct {
+		Issues []jiraIssue `json:"issues"`
+	}
+	if err := t.do(ctx, http.MethodGet, "/rest/api/2/search?"+q.Encode(), nil, &resp); err != nil {
+		return nil, err
+	}
+	issues := make([]*Issue, 0, len(resp.Issues))
+	for i := range resp.Issues {
+		issues = append(issues, t.toIssue(&resp.Issues[i]))
+	}
+	return issues, nil
+}
+
+func (t *jiraTracker) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	// 只允许导入集成所属项目的 Issue
+	if !strings.HasPrefix(key, t.project+"-") {
+		return nil, fmt.Errorf("issue %q does not belong to project %s", key, t.project)
+	}
+	var item jiraIssue
+	path := "/rest/api/2/issue/" + url.PathEscape(key) + "?fields=summary,description,labels"
+	if err := t.do(ctx, http.MethodGet, path, nil, &item); err != nil {
+		return nil, err
+	}
+	return t.toIssue(&item), nil
+}
+
+func (t *jiraTracker) PostComment(ctx context.Context, key, body string) error {
+	return t.do(ctx, http.MethodPost, "/rest/api/2/issue/"+url.PathEscape(key)+"/comment", map[string]string{"body": body}, nil)
+}
+
+func (t *jiraTracker) do(ctx context.Context, method, path string, in, out interface{}) error {
+	header := http.Header{}
+	switch {
+	case t.auth.Token == "":
+	case t.auth.Username != "":
+		// Jira Cloud：邮箱 + API Token
+		cred := base64.StdEncoding.EncodeToString([]byte(t.auth.Username + ":" + t.auth.Token))
+		header.Set("Authorization", "Basic "+cred)
+	default:
+		// Jira Data Center：个人访问令牌
+		header.Set("Authorization", "Bearer "+t.auth.Token)
+	}
+	return doJSON(ctx, t.client, method, t.baseURL+path, header, in, out)
+}
+
+// ============================================================================
+// HTTP 工具
+// ============================================================================
+
+// errIssueNotFound Issue 不存在（或无权访问）
+var errIssueNotFound = errors.New("issue not found")
+
+// doJSON 发送 JSON 请求并解码响应，非 2xx 返回错误（响应体截断后附带）
+func doJSON(ctx context.Context, client *http.Client, method, rawURL string, header http.Header, in, out interface{}) error {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, body)
+	if err != nil {
+		return err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", firstNonEmpty(req.Header.Get("Accept"), "application/json"))
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if resp.StatusCode == http.StatusNotFound {
+		return errIssueNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg := strings.TrimSpace(string(data))
+		if len(msg) > 200 {
+			msg = msg[:200]
+		}
+		return fmt.Errorf("%s %s: HTTP %d: %s", method, req.URL.Path, resp.StatusCode, msg)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,622 @@
+// Package lifecycle Run 数据分层生命周期
+//
+// 终态 Run 的数据按策略在三个层级之间单向迁移：
+//   - hot：事件完整保存在数据库中
+//   - warm：事件以 gzip 压缩的 NDJSON 归档到对象存储（MinIO）并从数据库删除，Run 元数据保留
+//   - cold：清除 Run 的任务快照，数据库中只保留 Run 基本字段、归档摘要与归档位置
+//
+// 迁移策略：
+//   - 按时间：结束超过 HotTTL 的 Run 转入 warm；进入 warm 超过 WarmTTL 的 Run 转入 cold
+//   - 按容量：数据库事件总数超过 MaxHotEvents 时，提前归档最早结束（且结束超过 MinHotAge）的 Run
+//   - 按项目：项目保留策略（model.RetentionPolicy）覆盖 HotTTL，并在归档保留期满后删除归档对象（purged）
+//
+// 读取事件时数据库中没有记录的已归档 Run 透明回退到对象存储（见 Controller.GetEventsByRun），
+// 调用方无需关心 Run 所在层级；归档已删除的 Run 返回 ErrEventsPurged。
+package lifecycle
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+const (
+	// eventPageSize 归档时分页读取事件的批大小
+	eventPageSize = 1000
+
+	// maxSizeBatches 单轮按容量归档的最大批次数，避免一次巡检占用过久
+	maxSizeBatches = 10
+
+	// archiveContentType 归档对象的 Content-Type
+	archiveContentType = "application/x-ndjson"
+)
+
+// ErrEventsPurged Run 的事件归档已按项目保留策略删除
+var ErrEventsPurged = errors.New("run events purged by retention policy")
+
+// Store Run 数据分层需要的存储接口
+type Store interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	ListProjects(ctx context.Context) ([]*model.Project, error)
+	CountRunsByStatus(ctx context.Context, since time.Time) (map[model.RunStatus]int, error)
+	CountEventsByRun(ctx context.Context, runID string) (int, error)
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+	CreateRunArchive(ctx context.Context, a *model.RunArchive) error
+	GetRunArchive(ctx context.Context, runID string) (*model.RunArchive, error)
+	UpdateRunArchive(ctx context.Context, a *model.RunArchive) error
+	ListRunsForArchive(ctx context.Context, filter storage.ArchiveRunFilter) ([]*model.Run, error)
+	ListRunArchives(ctx context.Context, tier model.RunDataTier, before time.Time, limit int) ([]*model.RunArchive, error)
+	ListExpiredRunArchives(ctx context.Context, projectID string, before time.Time, limit int) ([]*model.RunArchive, error)
+	DeleteEventsByRun(ctx context.Context, runID string) (int64, error)
+	ClearRunSnapshot(ctx context.Context, runID string) error
+	CountEvents(ctx context.Context) (int64, error)
+	RunArchiveStats(ctx context.Context) ([]model.RunTierStats, error)
+}
+
+// ObjectStore 归档对象存储（由 MinIO 客户端实现）
+type ObjectStore interface {
+	Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config 生命周期策略
+type Config struct {
+	Enabled      bool          // 是否启用自动迁移（关闭时仍可读取已归档的事件）
+	Interval     time.Duration // 巡检间隔
+	HotTTL       time.Duration // Run 结束后保留在 hot 的时长
+	WarmTTL      time.Duration // 进入 warm 后转入 cold 的时长，0 表示不转入 cold
+	MaxHotEvents int64         // 数据库事件总数上限，超出时提前归档，0 表示不限制
+	MinHotAge    time.Duration // 按容量归档时 Run 至少已结束的时长（留给结果发布等结束回调读取事件）
+	BatchSize    int           // 每批处理的 Run 数
+}
+
+// Result 一轮巡检的迁移结果
+type Result struct {
+	Warmed int `json:"warmed"` // hot → warm
+	Cooled int `json:"cooled"` // warm → cold
+	Purged int `json:"purged"` // 按项目保留策略删除归档
+	Failed int `json:"failed"`
+}
+
+// Controller Run 数据分层控制器
+type Controller struct {
+	store   Store
+	objects ObjectStore
+	cfg     Config
+	cache   *archiveCache
+	onStats func([]model.RunTierStats)
+
+	mu sync.Mutex // 串行化巡检（定时与手动触发）
+}
+
+// NewController 创建生命周期控制器
+func NewController(store Store, objects ObjectStore, cfg Config) *Controller {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Minute
+	}
+	if cfg.HotTTL <= 0 {
+		cfg.HotTTL = 7 * 24 * time.Hour
+	}
+	if cfg.MinHotAge <= 0 {
+		cfg.MinHotAge = time.Hour
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 50
+	}
+	return &Controller{store: store, objects: objects, cfg: cfg, cache: newArchiveCache()}
+}
+
+// Config 返回生效的策略
+func (c *Controller) Config() Config {
+	return c.cfg
+}
+
+// OnStats 注册层级统计回调（每轮巡检后调用，用于更新 Prometheus 指标）
+func (c *Controller) OnStats(fn func([]model.RunTierStats)) {
+	c.onStats = fn
+}
+
+// Start 启动生命周期巡检（阻塞直到 ctx 取消，未启用时立即返回）
+func (c *Controller) Start(ctx context.Context) {
+	if !c.cfg.Enabled {
+		return
+	}
+	slog.InfoContext(ctx, "lifecycle.start", "interval", c.cfg.Interval, "hot_ttl", c.cfg.HotTTL,
+		"warm_ttl", c.cfg.WarmTTL, "max_hot_events", c.cfg.MaxHotEvents)
+
+	ticker := time.NewTicker(c.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		c.RunOnce(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一轮迁移：按时间归档（全局与项目策略）、按容量归档、warm 转 cold、删除到期归档
+func (c *Controller) RunOnce(ctx context.Context, now time.Time) Result {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var res Result
+	warm := func(runs []*model.Run) {
+		for _, run := range runs {
+			if err := c.archiveRun(ctx, run, now); err != nil {
+				res.Failed++
+				slog.ErrorContext(ctx, "lifecycle.warm.failed", "run_id", run.ID, "error", err)
+				continue
+			}
+			res.Warmed++
+		}
+	}
+
+	// 项目保留策略：自定义 HotDays 的项目单独按项目归档，不受全局 HotTTL 约束
+	policies, err := c.projectPolicies(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "lifecycle.policies.failed", "error", err)
+	}
+	var custom []string
+	for _, p := range policies {
+		if p.HotTTL() > 0 {
+			custom = append(custom, p.projectID)
+		}
+	}
+
+	// 按时间：结束超过 HotTTL
+	if runs, err := c.store.ListRunsForArchive(ctx, storage.ArchiveRunFilter{
+		Before: now.Add(-c.cfg.HotTTL), ExcludeProjectIDs: custom, Limit: c.cfg.BatchSize,
+	}); err != nil {
+		slog.ErrorContext(ctx, "lifecycle.list.failed", "policy", "age", "error", err)
+	} else {
+		warm(runs)
+	}
+	for _, p := range policies {
+		if p.HotTTL() <= 0 {
+			continue
+		}
+		runs, err := c.store.ListRunsForArchive(ctx, storage.ArchiveRunFilter{
+			Before: now.Add(-p.HotTTL()), ProjectID: p.projectID, Limit: c.cfg.BatchSize,
+		})
+		if err != nil {
+			slog.ErrorContext(ctx, "lifecycle.list.failed", "policy", "project", "project_id", p.projectID, "error", err)
+			continue
+		}
+		warm(runs)
+	}
+
+	// 按容量：数据库事件总数超过上限
+	if c.cfg.MaxHotEvents > 0 {
+		for i := 0; i < maxSizeBatches; i++ {
+			total, err := c.store.CountEvents(ctx)
+			if err != nil || total <= c.cfg.MaxHotEvents {
+				break
+			}
+			runs, err := c.store.ListRunsForArchive(ctx, storage.ArchiveRunFilter{Before: now.Add(-c.cfg.MinHotAge), Limit: c.cfg.BatchSize})
+			if err != nil || len(runs) == 0 {
+				break
+			}
+			before := res.Warmed
+			warm(runs)
+			if res.Warmed == before {
+				break
+			}
+		}
+	}
+
+	// warm → cold
+	if c.cfg.WarmTTL > 0 {
+		archives, err := c.store.ListRunArchives(ctx, model.RunDataTierWarm, now.Add(-c.cfg.WarmTTL), c.cfg.BatchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "lifecycle.list.failed", "policy", "cold", "error", err)
+		}
+		for _, a := range archives {
+			if err := c.coolRun(ctx, a, now); err != nil {
+				res.Failed++
+				slog.ErrorContext(ctx, "lifecycle.cold.failed", "run_id", a.RunID, "error", err)
+				continue
+			}
+			res.Cooled++
+		}
+	}
+
+	// 归档保留期满：删除归档对象
+	for _, p := range policies {
+		if p.ArchiveTTL() <= 0 {
+			continue
+		}
+		archives, err := c.store.ListExpiredRunArchives(ctx, p.projectID, now.Add(-p.ArchiveTTL()), c.cfg.BatchSize)
+		if err != nil {
+			slog.ErrorContext(ctx, "lifecycle.list.failed", "policy", "purge", "project_id", p.projectID, "error", err)
+			continue
+		}
+		for _, a := range archives {
+			if err := c.purgeRun(ctx, a, now); err != nil {
+				res.Failed++
+				slog.ErrorContext(ctx, "lifecycle.purge.failed", "run_id", a.RunID, "error", err)
+				continue
+			}
+			res.Purged++
+		}
+	}
+
+	if res.Warmed+res.Cooled+res.Purged+res.Failed > 0 {
+		slog.InfoContext(ctx, "lifecycle.cycle", "warmed", res.Warmed, "cooled", res.Cooled, "purged", res.Purged, "failed", res.Failed)
+	}
+	if c.onStats != nil {
+		if stats, err := c.Stats(ctx); err == nil {
+			c.onStats(stats)
+		}
+	}
+	return res
+}
+
+// projectPolicy 项目的保留策略
+type projectPolicy struct {
+	*model.RetentionPolicy
+	projectID string
+}
+
+// projectPolicies 列出配置了保留策略的项目
+func (c *Controller) projectPolicies(ctx context.Context) ([]projectPolicy, error) {
+	projects, err := c.store.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var policies []projectPolicy
+	for _, p := range projects {
+		if p.Retention != nil && (p.Retention.HotDays > 0 || p.Retention.ArchiveDays > 0) {
+			policies = append(policies, projectPolicy{RetentionPolicy: p.Retention, projectID: p.ID})
+		}
+	}
+	return policies, nil
+}
+
+// ProjectPolicies 返回各项目生效的保留策略（项目 ID → 策略）
+func (c *Controller) ProjectPolicies(ctx context.Context) (map[string]*model.RetentionPolicy, error) {
+	policies, err := c.projectPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make(map[string]*model.RetentionPolicy, len(policies))
+	for _, p := range policies {
+		result[p.projectID] = p.RetentionPolicy
+	}
+	return result, nil
+}
+
+// runProject 返回 Run 所属任务的项目 ID（任务不存在或未关联项目时为空）
+func (c *Controller) runProject(ctx context.Context, run *model.Run) string {
+	task, err := c.store.GetTask(ctx, run.TaskID)
+	if err != nil || task == nil || task.ProjectID == nil {
+		return ""
+	}
+	return *task.ProjectID
+}
+
+// archiveRun hot → warm：上传事件归档，写入归档记录后再删除数据库中的事件
+//
+// 归档记录写入前读取仍走数据库；写入后到删除完成前数据库与归档内容相同，读取结果一致。
+func (c *Controller) archiveRun(ctx context.Context, run *model.Run, now time.Time) error {
+	var (
+		buf     bytes.Buffer
+		summary = newSummary(run)
+	)
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	fromSeq := 0
+	for {
+		events, err := c.store.GetEventsByRun(ctx, run.ID, fromSeq, eventPageSize)
+		if err != nil {
+			return fmt.Errorf("read events: %w", err)
+		}
+		for _, e := range events {
+			if err := enc.Encode(e); err != nil {
+				return fmt.Errorf("encode event: %w", err)
+			}
+			summary.add(e)
+			fromSeq = e.Seq
+		}
+		if len(events) < eventPageSize {
+			break
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+
+	key := archiveKey(run.ID)
+	size := int64(buf.Len())
+	if err := c.objects.Upload(ctx, key, &buf, size, archiveContentType); err != nil {
+		return fmt.Errorf("upload archive: %w", err)
+	}
+	archive := &model.RunArchive{
+		RunID:        run.ID,
+		ProjectID:    c.runProject(ctx, run),
+		Tier:         model.RunDataTierWarm,
+		ArchiveKey:   key,
+		EventCount:   summary.EventCount,
+		ArchiveBytes: size,
+		Summary:      summary.RunSummary,
+		WarmAt:       now,
+	}
+	if err := c.store.CreateRunArchive(ctx, archive); err != nil {
+		return fmt.Errorf("create archive record: %w", err)
+	}
+	if _, err := c.store.DeleteEventsByRun(ctx, run.ID); err != nil {
+		return fmt.Errorf("delete events: %w", err)
+	}
+	slog.InfoContext(ctx, "lifecycle.warm", "run_id", run.ID, "events", archive.EventCount, "archive_bytes", size)
+	return nil
+}
+
+// coolRun warm → cold：清除任务快照
+func (c *Controller) coolRun(ctx context.Context, a *model.RunArchive, now time.Time) error {
+	if err := c.store.ClearRunSnapshot(ctx, a.RunID); err != nil {
+		return fmt.Errorf("clear snapshot: %w", err)
+	}
+	a.Tier = model.RunDataTierCold
+	a.ColdAt = &now
+	if err := c.store.UpdateRunArchive(ctx, a); err != nil {
+		return fmt.Errorf("update archive record: %w", err)
+	}
+	slog.InfoContext(ctx, "lifecycle.cold", "run_id", a.RunID)
+	return nil
+}
+
+// purgeRun warm/cold → purged：删除归档对象，保留归档记录与摘要
+//
+// 先更新归档记录再删除对象：删除失败时对象残留（可由 bucket 生命周期规则清理），但不会出现记录指向已删除对象的情况。
+func (c *Controller) purgeRun(ctx context.Context, a *model.RunArchive, now time.Time) error {
+	key := a.ArchiveKey
+	a.Tier = model.RunDataTierPurged
+	a.ArchiveBytes = 0
+	a.PurgedAt = &now
+	if err := c.store.UpdateRunArchive(ctx, a); err != nil {
+		return fmt.Errorf("update archive record: %w", err)
+	}
+	c.cache.remove(a.RunID)
+	if err := c.objects.Delete(ctx, key); err != nil {
+		slog.ErrorContext(ctx, "lifecycle.purge.object_failed", "run_id", a.RunID, "key", key, "error", err)
+	}
+	slog.InfoContext(ctx, "lifecycle.purged", "run_id", a.RunID, "project_id", a.ProjectID)
+	return nil
+}
+
+// Stats 各层级的 Run 数、事件数与对象存储占用
+func (c *Controller) Stats(ctx context.Context) ([]model.RunTierStats, error) {
+	archived, err := c.store.RunArchiveStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := c.store.CountRunsByStatus(ctx, time.Time{})
+	if err != nil {
+		return nil, err
+	}
+	events, err := c.store.CountEvents(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	hot := model.RunTierStats{Tier: model.RunDataTierHot, Events: events}
+	for _, n := range counts {
+		hot.Runs += int64(n)
+	}
+	result := []model.RunTierStats{hot, {Tier: model.RunDataTierWarm}, {Tier: model.RunDataTierCold}, {Tier: model.RunDataTierPurged}}
+	for _, st := range archived {
+		for i := range result {
+			if result[i].Tier == st.Tier {
+				result[i] = st
+				result[0].Runs -= st.Runs
+			}
+		}
+	}
+	return result, nil
+}
+
+// ============================================================================
+// 事件读取（透明回退到归档）
+// ============================================================================
+
+// GetEventsByRun 读取 Run 的事件（seq > fromSeq，最多 limit 条）
+//
+// 数据库中有事件时直接返回；没有事件且 Run 已归档时从归档读取，归档已删除时返回 ErrEventsPurged。
+func (c *Controller) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	events, err := c.store.GetEventsByRun(ctx, runID, fromSeq, limit)
+	if err != nil || len(events) > 0 {
+		return events, err
+	}
+	archive, err := c.store.GetRunArchive(ctx, runID)
+	if err != nil || archive == nil {
+		return events, err
+	}
+	if archive.Tier == model.RunDataTierPurged {
+		return nil, ErrEventsPurged
+	}
+	all, err := c.loadArchive(ctx, archive)
+	if err != nil {
+		return nil, err
+	}
+	var result []*model.Event
+	for _, e := range all {
+		if e.Seq <= fromSeq {
+			continue
+		}
+		result = append(result, e)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result, nil
+}
+
+// CountEventsByRun 统计 Run 的事件数（已归档的 Run 返回归档中的事件数，归档已删除时为 0）
+func (c *Controller) CountEventsByRun(ctx context.Context, runID string) (int, error) {
+	n, err := c.store.CountEventsByRun(ctx, runID)
+	if err != nil || n > 0 {
+		return n, err
+	}
+	archive, err := c.store.GetRunArchive(ctx, runID)
+	if err != nil || archive == nil || archive.Tier == model.RunDataTierPurged {
+		return n, err
+	}
+	return archive.EventCount, nil
+}
+
+// Tier 返回 Run 所在层级与归档记录（hot 时归档记录为 nil）
+func (c *Controller) Tier(ctx context.Context, runID string) (model.RunDataTier, *model.RunArchive, error) {
+	archive, err := c.store.GetRunArchive(ctx, runID)
+	if err != nil {
+		return "", nil, err
+	}
+	if archive == nil {
+		return model.RunDataTierHot, nil, nil
+	}
+	return archive.Tier, archive, nil
+}
+
+// loadArchive 下载并解码归档（最近读取的归档缓存在内存中，分页读取不重复下载）
+func (c *Controller) loadArchive(ctx context.Context, a *model.RunArchive) ([]*model.Event, error) {
+	if events, ok := c.cache.get(a.RunID); ok {
+		return events, nil
+	}
+	rc, err := c.objects.Download(ctx, a.ArchiveKey)
+	if err != nil {
+		return nil, fmt.Errorf("download archive: %w", err)
+	}
+	defer rc.Close()
+	events, err := decodeArchive(rc)
+	if err != nil {
+		return nil, fmt.Errorf("decode archive %s: %w", a.ArchiveKey, err)
+	}
+	c.cache.put(a.RunID, events)
+	return events, nil
+}
+
+func decodeArchive(r io.Reader) ([]*model.Event, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+	dec := json.NewDecoder(zr)
+	var events []*model.Event
+	for {
+		e := &model.Event{}
+		if err := dec.Decode(e); err != nil {
+			if errors.Is(err, io.EOF) {
+				return events, nil
+			}
+			return nil, err
+		}
+		events = append(events, e)
+	}
+}
+
+// archiveKey Run 事件归档的对象键
+func archiveKey(runID string) string {
+	return "run-archives/" + runID + "/events.ndjson.gz"
+}
+
+// ============================================================================
+// 摘要
+// ============================================================================
+
+type summaryBuilder struct {
+	*model.RunSummary
+}
+
+func newSummary(run *model.Run) summaryBuilder {
+	s := &model.RunSummary{Status: run.Status, EventTypes: map[string]int{}}
+	if run.NodeID != nil {
+		s.NodeID = *run.NodeID
+	}
+	if run.Error != nil {
+		s.Error = *run.Error
+	}
+	if run.StartedAt != nil && run.FinishedAt != nil {
+		s.DurationMS = run.FinishedAt.Sub(*run.StartedAt).Milliseconds()
+	}
+	return summaryBuilder{s}
+}
+
+func (b summaryBuilder) add(e *model.Event) {
+	b.EventCount++
+	b.EventTypes[e.Type]++
+	ts := e.Timestamp
+	if b.FirstEventAt == nil || ts.Before(*b.FirstEventAt) {
+		b.FirstEventAt = &ts
+	}
+	if b.LastEventAt == nil || ts.After(*b.LastEventAt) {
+		b.LastEventAt = &ts
+	}
+}
+
+// ============================================================================
+// 归档缓存
+// ============================================================================
+
+const (
+	archiveCacheSize = 16
+	archiveCacheTTL  = 5 * time.Minute
+)
+
+type cachedArchive struct {
+	events   []*model.Event
+	loadedAt time.Time
+}
+
+// archiveCache 最近读取的归档（容量满时淘汰最早加载的）
+type archiveCache struct {
+	mu      sync.Mutex
+	entries map[string]cachedArchive
+}
+
+func newArchiveCache() *archiveCache {
+	return &archiveCache{entries: make(map[string]cachedArchive)}
+}
+
+func (c *archiveCache) get(runID string) ([]*model.Event, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[runID]
+	if !ok || time.Since(e.loadedAt) > archiveCacheTTL {
+		return nil, false
+	}
+	return e.events, true
+}
+
+func (c *archiveCache) put(runID string, events []*model.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= archiveCacheSize {
+		var oldest string
+		for id, e := range c.entries {
+			if oldest == "" || e.loadedAt.Before(c.entries[oldest].loadedAt) {
+				oldest = id
+			}
+		}
+		delete(c.entries, oldest)
+	}
+	c.entries[runID] = cachedArchive{events: events, loadedAt: time.Now()}
+}
+
+func (c *archiveCache) remove(runID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, runID)
+}
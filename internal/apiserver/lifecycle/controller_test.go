@@ -0,0 +1,455 @@
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// mockStore 内存存储：runs、events（按 Run 分组）、归档记录与项目
+type mockStore struct {
+	mu       sync.Mutex
+	runs     map[string]*model.Run
+	events   map[string][]*model.Event
+	archives map[string]*model.RunArchive
+	tasks    map[string]*model.Task
+	projects []*model.Project
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{runs: map[string]*model.Run{}, events: map[string][]*model.Event{}, archives: map[string]*model.RunArchive{},
+		tasks: map[string]*model.Task{}}
+}
+
+// setProject 将 Run 关联到项目（通过任务）
+func (m *mockStore) setProject(runID, projectID string) {
+	taskID := "task-" + runID
+	m.tasks[taskID] = &model.Task{ID: taskID, ProjectID: &projectID}
+	m.runs[runID].TaskID = taskID
+}
+
+func (m *mockStore) runProject(r *model.Run) string {
+	if t := m.tasks[r.TaskID]; t != nil && t.ProjectID != nil {
+		return *t.ProjectID
+	}
+	return ""
+}
+
+func (m *mockStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.tasks[id], nil
+}
+
+func (m *mockStore) ListProjects(_ context.Context) ([]*model.Project, error) {
+	return m.projects, nil
+}
+
+func (m *mockStore) addRun(id string, finishedAgo time.Duration, events int) {
+	finished := time.Now().Add(-finishedAgo)
+	m.runs[id] = &model.Run{ID: id, Status: model.RunStatusDone, FinishedAt: &finished, Snapshot: json.RawMessage(`{"prompt":"x"}`)}
+	for i := 1; i <= events; i++ {
+		m.events[id] = append(m.events[id], &model.Event{RunID: id, Seq: i, Type: "message", Timestamp: finished})
+	}
+}
+
+func (m *mockStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs[id], nil
+}
+
+func (m *mockStore) CountRunsByStatus(_ context.Context, _ time.Time) (map[model.RunStatus]int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	counts := map[model.RunStatus]int{}
+	for _, r := range m.runs {
+		counts[r.Status]++
+	}
+	return counts, nil
+}
+
+func (m *mockStore) CountEventsByRun(_ context.Context, runID string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.events[runID]), nil
+}
+
+func (m *mockStore) GetEventsByRun(_ context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.Event
+	for _, e := range m.events[runID] {
+		if e.Seq > fromSeq && (limit <= 0 || len(result) < limit) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockStore) CreateRunArchive(_ context.Context, a *model.RunArchive) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archives[a.RunID] = a
+	return nil
+}
+
+func (m *mockStore) GetRunArchive(_ context.Context, runID string) (*model.RunArchive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.archives[runID], nil
+}
+
+func (m *mockStore) UpdateRunArchive(_ context.Context, a *model.RunArchive) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.archives[a.RunID] = a
+	return nil
+}
+
+func (m *mockStore) ListRunsForArchive(_ context.Context, filter storage.ArchiveRunFilter) ([]*model.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.Run
+	for _, r := range m.runs {
+		project := m.runProject(r)
+		if (filter.ProjectID != "" && project != filter.ProjectID) || slices.Contains(filter.ExcludeProjectIDs, project) {
+			continue
+		}
+		if _, archived := m.archives[r.ID]; !archived && r.FinishedAt != nil && r.FinishedAt.Before(filter.Before) {
+			result = append(result, r)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].FinishedAt.Before(*result[j].FinishedAt) })
+	if len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (m *mockStore) ListExpiredRunArchives(_ context.Context, projectID string, before time.Time, limit int) ([]*model.RunArchive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.RunArchive
+	for _, a := range m.archives {
+		if a.ProjectID == projectID && a.Tier != model.RunDataTierPurged && a.WarmAt.Before(before) && len(result) < limit {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockStore) ListRunArchives(_ context.Context, tier model.RunDataTier, before time.Time, limit int) ([]*model.RunArchive, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.RunArchive
+	for _, a := range m.archives {
+		if a.Tier == tier && a.WarmAt.Before(before) && len(result) < limit {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
+func (m *mockStore) DeleteEventsByRun(_ context.Context, runID string) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n := len(m.events[runID])
+	delete(m.events, runID)
+	return int64(n), nil
+}
+
+func (m *mockStore) ClearRunSnapshot(_ context.Context, runID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[runID].Snapshot = nil
+	return nil
+}
+
+func (m *mockStore) CountEvents(_ context.Context) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var n int64
+	for _, events := range m.events {
+		n += int64(len(events))
+	}
+	return n, nil
+}
+
+func (m *mockStore) RunArchiveStats(_ context.Context) ([]model.RunTierStats, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	byTier := map[model.RunDataTier]*model.RunTierStats{}
+	for _, a := range m.archives {
+		st := byTier[a.Tier]
+		if st == nil {
+			st = &model.RunTierStats{Tier: a.Tier}
+			byTier[a.Tier] = st
+		}
+		st.Runs++
+		st.Events += int64(a.EventCount)
+		st.ArchiveBytes += a.ArchiveBytes
+	}
+	var result []model.RunTierStats
+	for _, st := range byTier {
+		result = append(result, *st)
+	}
+	return result, nil
+}
+
+// mockObjects 内存对象存储
+type mockObjects struct {
+	mu        sync.Mutex
+	objects   map[string][]byte
+	failWrite bool
+	downloads int
+}
+
+func newMockObjects() *mockObjects {
+	return &mockObjects{objects: map[string][]byte{}}
+}
+
+func (m *mockObjects) Upload(_ context.Context, key string, reader io.Reader, _ int64, _ string) error {
+	if m.failWrite {
+		return errors.New("minio unavailable")
+	}
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return nil
+}
+
+func (m *mockObjects) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.downloads++
+	data, ok := m.objects[key]
+	if !ok {
+		return nil, errors.New("not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (m *mockObjects) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func TestController_AgePolicyArchivesTransparently(t *testing.T) {
+	store, objects := newMockStore(), newMockObjects()
+	store.addRun("run-old", 10*24*time.Hour, 5)
+	store.addRun("run-new", time.Hour, 3)
+	ctrl := NewController(store, objects, Config{HotTTL: 7 * 24 * time.Hour})
+
+	res := ctrl.RunOnce(t.Context(), time.Now())
+	if res.Warmed != 1 || res.Failed != 0 {
+		t.Fatalf("result = %+v, want 1 warmed", res)
+	}
+	if len(store.events["run-old"]) != 0 || len(store.events["run-new"]) != 3 {
+		t.Fatal("only the expired run should have its events removed from the database")
+	}
+	archive := store.archives["run-old"]
+	if archive == nil || archive.Tier != model.RunDataTierWarm || archive.EventCount != 5 || archive.ArchiveBytes == 0 {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+	if archive.Summary == nil || archive.Summary.EventTypes["message"] != 5 {
+		t.Errorf("unexpected summary: %+v", archive.Summary)
+	}
+
+	// 事件透明地从归档读取
+	if n, _ := ctrl.CountEventsByRun(t.Context(), "run-old"); n != 5 {
+		t.Errorf("CountEventsByRun = %d, want 5", n)
+	}
+	events, err := ctrl.GetEventsByRun(t.Context(), "run-old", 2, 2)
+	if err != nil {
+		t.Fatalf("GetEventsByRun failed: %v", err)
+	}
+	if len(events) != 2 || events[0].Seq != 3 || events[1].Seq != 4 {
+		t.Errorf("unexpected archived page: %+v", events)
+	}
+	ctrl.GetEventsByRun(t.Context(), "run-old", 4, 10)
+	if objects.downloads != 1 {
+		t.Errorf("downloads = %d, archive should be cached between pages", objects.downloads)
+	}
+	if events, _ := ctrl.GetEventsByRun(t.Context(), "run-new", 0, 10); len(events) != 3 {
+		t.Errorf("hot run events = %d, want 3", len(events))
+	}
+}
+
+func TestController_SizePolicy(t *testing.T) {
+	store, objects := newMockStore(), newMockObjects()
+	store.addRun("run-1", 3*time.Hour, 4)
+	store.addRun("run-2", 2*time.Hour, 4)
+	store.addRun("run-3", 10*time.Minute, 4) // 未达到 MinHotAge，不提前归档
+	ctrl := NewController(store, objects, Config{HotTTL: 30 * 24 * time.Hour, MaxHotEvents: 5, MinHotAge: time.Hour, BatchSize: 1})
+
+	res := ctrl.RunOnce(t.Context(), time.Now())
+	if res.Warmed != 2 {
+		t.Fatalf("warmed = %d, want 2", res.Warmed)
+	}
+	if store.archives["run-3"] != nil {
+		t.Error("run younger than min_hot_age must stay hot")
+	}
+}
+
+func TestController_ColdAndStats(t *testing.T) {
+	store, objects := newMockStore(), newMockObjects()
+	store.addRun("run-1", 10*24*time.Hour, 2)
+	store.addRun("run-2", time.Hour, 2)
+	var reported []model.RunTierStats
+	ctrl := NewController(store, objects, Config{WarmTTL: 24 * time.Hour})
+	ctrl.OnStats(func(st []model.RunTierStats) { reported = st })
+
+	now := time.Now()
+	ctrl.RunOnce(t.Context(), now)
+	if res := ctrl.RunOnce(t.Context(), now.Add(48*time.Hour)); res.Cooled != 1 {
+		t.Fatalf("cooled = %d, want 1", res.Cooled)
+	}
+	if store.archives["run-1"].Tier != model.RunDataTierCold || store.runs["run-1"].Snapshot != nil {
+		t.Error("cold run should have its snapshot cleared")
+	}
+	// cold 层仍可读取事件
+	if events, _ := ctrl.GetEventsByRun(t.Context(), "run-1", 0, 0); len(events) != 2 {
+		t.Errorf("cold run events = %d, want 2", len(events))
+	}
+
+	want := map[model.RunDataTier][2]int64{model.RunDataTierHot: {1, 2}, model.RunDataTierWarm: {0, 0}, model.RunDataTierCold: {1, 2}, model.RunDataTierPurged: {0, 0}}
+	if len(reported) != 4 {
+		t.Fatalf("reported stats = %+v", reported)
+	}
+	for _, st := range reported {
+		if w := want[st.Tier]; st.Runs != w[0] || st.Events != w[1] {
+			t.Errorf("%s stats = %+v, want runs=%d events=%d", st.Tier, st, w[0], w[1])
+		}
+	}
+}
+
+func TestController_ProjectRetention(t *testing.T) {
+	store, objects := newMockStore(), newMockObjects()
+	store.addRun("run-global", 3*24*time.Hour, 2)
+	store.addRun("run-short", 3*24*time.Hour, 2)
+	store.addRun("run-long", 10*24*time.Hour, 2)
+	store.setProject("run-short", "proj-short")
+	store.setProject("run-long", "proj-long")
+	store.projects = []*model.Project{
+		{ID: "proj-short", Retention: &model.RetentionPolicy{HotDays: 1, ArchiveDays: 30}},
+		{ID: "proj-long", Retention: &model.RetentionPolicy{HotDays: 30}},
+		{ID: "proj-default"},
+	}
+	ctrl := NewController(store, objects, Config{HotTTL: 7 * 24 * time.Hour})
+
+	now := time.Now()
+	if res := ctrl.RunOnce(t.Context(), now); res.Warmed != 1 || res.Failed != 0 {
+		t.Fatalf("result = %+v, want only the short-retention run archived", res)
+	}
+	archive := store.archives["run-short"]
+	if archive == nil || archive.ProjectID != "proj-short" {
+		t.Fatalf("unexpected archive: %+v", archive)
+	}
+	if store.archives["run-long"] != nil || store.archives["run-global"] != nil {
+		t.Error("project hot_days overrides the global hot_ttl in both directions")
+	}
+
+	// 归档保留期满：删除归档对象，事件不可再读取
+	if res := ctrl.RunOnce(t.Context(), now.Add(31*24*time.Hour)); res.Purged != 1 {
+		t.Fatalf("result = %+v, want 1 purged", res)
+	}
+	if archive := store.archives["run-short"]; archive.Tier != model.RunDataTierPurged || archive.PurgedAt == nil || archive.ArchiveBytes != 0 {
+		t.Fatalf("unexpected purged archive: %+v", archive)
+	}
+	if _, ok := objects.objects[archiveKey("run-short")]; ok {
+		t.Error("archive object should be deleted")
+	}
+	if _, err := ctrl.GetEventsByRun(t.Context(), "run-short", 0, 0); !errors.Is(err, ErrEventsPurged) {
+		t.Errorf("GetEventsByRun error = %v, want ErrEventsPurged", err)
+	}
+	if n, _ := ctrl.CountEventsByRun(t.Context(), "run-short"); n != 0 {
+		t.Errorf("purged run count = %d, want 0", n)
+	}
+	// 没有 archive_days 的项目与全局归档不删除
+	if store.archives["run-long"].Tier == model.RunDataTierPurged || store.archives["run-global"].Tier == model.RunDataTierPurged {
+		t.Error("archives without archive_days must be kept")
+	}
+}
+
+func TestController_UploadFailureKeepsEvents(t *testing.T) {
+	store, objects := newMockStore(), newMockObjects()
+	objects.failWrite = true
+	store.addRun("run-1", 10*24*time.Hour, 3)
+	ctrl := NewController(store, objects, Config{})
+
+	if res := ctrl.RunOnce(t.Context(), time.Now()); res.Failed != 1 || res.Warmed != 0 {
+		t.Fatalf("result = %+v, want 1 failed", res)
+	}
+	if len(store.events["run-1"]) != 3 || store.archives["run-1"] != nil {
+		t.Error("events must stay in the database when the upload fails")
+	}
+}
+
+func TestHandler_Lifecycle(t *testing.T) {
+	store, objects := newMockStore(), newMockObjects()
+	store.addRun("run-1", 10*24*time.Hour, 2)
+	store.addRun("run-2", time.Hour, 2)
+	ctrl := NewController(store, objects, Config{Enabled: true})
+	ctrl.RunOnce(t.Context(), time.Now())
+	mux := http.NewServeMux()
+	NewHandler(ctrl).RegisterRoutes(mux)
+	handler := auth.Middleware(auth.Config{})(mux) // 无认证模式（未配置 JWT 密钥）
+
+	for runID, want := range map[string]model.RunDataTier{"run-1": model.RunDataTierWarm, "run-2": model.RunDataTierHot} {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/"+runID+"/lifecycle", nil))
+		var resp struct {
+			Tier    model.RunDataTier `json:"tier"`
+			Archive *model.RunArchive `json:"archive"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		if resp.Tier != want || (want == model.RunDataTierHot) != (resp.Archive == nil) {
+			t.Errorf("%s: unexpected response %s", runID, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/missing/lifecycle", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing run: status = %d, want 404", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/lifecycle", nil))
+	var stats struct {
+		Enabled bool                 `json:"enabled"`
+		Tiers   []model.RunTierStats `json:"tiers"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &stats)
+	if !stats.Enabled || len(stats.Tiers) != 4 {
+		t.Errorf("unexpected stats: %s", w.Body.String())
+	}
+
+	req := httptest.NewRequest("POST", "/api/v1/admin/lifecycle/run", nil)
+	req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: "user"}))
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("regular user: status = %d, want 403", w.Code)
+	}
+}
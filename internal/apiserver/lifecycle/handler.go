@@ -0,0 +1,115 @@
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+)
+
+// Handler Run 数据分层 HTTP 处理器
+type Handler struct {
+	ctrl *Controller
+}
+
+// NewHandler 创建 Run 数据分层处理器
+func NewHandler(ctrl *Controller) *Handler {
+	return &Handler{ctrl: ctrl}
+}
+
+// RegisterRoutes 注册 Run 数据分层相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/runs/{id}/lifecycle", h.GetRunLifecycle)
+	mux.HandleFunc("GET /api/v1/admin/lifecycle", auth.AdminOnly(h.GetStats))
+	mux.HandleFunc("POST /api/v1/admin/lifecycle/run", auth.AdminOnly(h.Trigger))
+}
+
+// GetRunLifecycle 查询 Run 所在层级与归档信息
+// GET /api/v1/runs/{id}/lifecycle
+//
+// 响应: {"run_id": "run-1", "tier": "warm", "archive": {"archive_key": "...", "event_count": 120, "summary": {...}}}
+func (h *Handler) GetRunLifecycle(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("id")
+	run, err := h.ctrl.store.GetRun(r.Context(), runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	tier, archive, err := h.ctrl.Tier(r.Context(), runID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "lifecycle", "op", "GetRunArchive", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get run archive")
+		return
+	}
+	resp := map[string]interface{}{"run_id": runID, "tier": tier}
+	if archive != nil {
+		resp["archive"] = archive
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// GetStats 各层级存储统计与迁移策略（仅限管理员）
+// GET /api/v1/admin/lifecycle
+//
+// 响应: {"enabled": true, "policy": {...}, "project_policies": {"proj-1": {"hot_days": 3, "archive_days": 90}},
+// "tiers": [{"tier": "hot", "runs": 10, "events": 5000, "archive_bytes": 0}, ...]}
+func (h *Handler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := h.ctrl.Stats(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "lifecycle", "op", "Stats", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get lifecycle stats")
+		return
+	}
+	policies, err := h.ctrl.ProjectPolicies(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "lifecycle", "op", "ListProjects", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get project retention policies")
+		return
+	}
+	cfg := h.ctrl.Config()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled": cfg.Enabled,
+		"policy": map[string]interface{}{
+			"interval":       cfg.Interval.String(),
+			"hot_ttl":        cfg.HotTTL.String(),
+			"warm_ttl":       cfg.WarmTTL.String(),
+			"max_hot_events": cfg.MaxHotEvents,
+			"min_hot_age":    cfg.MinHotAge.String(),
+			"batch_size":     cfg.BatchSize,
+		},
+		"project_policies": policies,
+		"tiers":            stats,
+	})
+}
+
+// Trigger 立即执行一轮迁移（后台执行，与定时巡检串行）
+// POST /api/v1/admin/lifecycle/run
+func (h *Handler) Trigger(w http.ResponseWriter, r *http.Request) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+		defer cancel()
+		h.ctrl.RunOnce(ctx, time.Now())
+	}()
+	writeJSON(w, http.StatusAccepted, map[string]string{"status": "accepted"})
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,157 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	defaultListLimit = 20
+	maxListLimit     = 200
+)
+
+// Handler 存储维护 HTTP 处理器（仅限管理员）
+type Handler struct {
+	svc *Service
+}
+
+// NewHandler 创建存储维护处理器
+func NewHandler(svc *Service) *Handler {
+	return &Handler{svc: svc}
+}
+
+// RegisterRoutes 注册存储维护相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/admin/maintenance/estimate", auth.AdminOnly(h.Estimate))
+	mux.HandleFunc("GET /api/v1/admin/maintenance/runs", auth.AdminOnly(h.ListRuns))
+	mux.HandleFunc("POST /api/v1/admin/maintenance/runs", auth.AdminOnly(h.CreateRun))
+}
+
+// Estimate 估算可回收空间（dry-run，不修改数据库）
+// GET /api/v1/admin/maintenance/estimate?tables=events,runs
+//
+// 响应: {"driver": "postgres", "tables": [...], "reclaimable_bytes": 1048576}
+func (h *Handler) Estimate(w http.ResponseWriter, r *http.Request) {
+	var tables []string
+	if v := r.URL.Query().Get("tables"); v != "" {
+		tables = strings.Split(v, ",")
+	}
+	results, err := h.svc.Estimate(r.Context(), tables)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	var reclaimable int64
+	for _, t := range results {
+		reclaimable += t.ReclaimableBytes
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"driver":            h.svc.driver,
+		"tables":            results,
+		"reclaimable_bytes": reclaimable,
+	})
+}
+
+// ListRuns 列出维护历史
+// GET /api/v1/admin/maintenance/runs?limit=20
+//
+// 响应: {"runs": [...], "running": false, "schedule": {"enabled": true, "window": "03:00-05:00", ...}}
+func (h *Handler) ListRuns(w http.ResponseWriter, r *http.Request) {
+	limit := defaultListLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = min(n, maxListLimit)
+	}
+	runs, err := h.svc.store.ListMaintenanceRuns(r.Context(), limit)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "maintenance", "op", "ListMaintenanceRuns", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list maintenance runs")
+		return
+	}
+	if runs == nil {
+		runs = []*model.MaintenanceRun{}
+	}
+	cfg := h.svc.Config()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runs":      runs,
+		"running":   h.svc.Running(),
+		"supported": h.svc.maintainer != nil,
+		"schedule": map[string]interface{}{
+			"enabled":  cfg.Enabled,
+			"window":   cfg.Window,
+			"interval": cfg.Interval.String(),
+			"tables":   cfg.Tables,
+			"dry_run":  cfg.DryRun,
+		},
+	})
+}
+
+// CreateRunRequest 手动触发维护请求
+type CreateRunRequest struct {
+	Tables []string `json:"tables,omitempty"` // 为空时使用配置的表
+	DryRun bool     `json:"dry_run"`
+}
+
+// CreateRun 手动触发维护（后台执行，通过 GET /runs 查询结果）
+// POST /api/v1/admin/maintenance/runs
+//
+// 请求体: {"tables": ["events"], "dry_run": false}
+// 响应: 202 + 状态为 running 的维护记录；已有维护在执行时 409
+func (h *Handler) CreateRun(w http.ResponseWriter, r *http.Request) {
+	var req CreateRunRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	var createdBy string
+	if user := auth.GetAuthUser(r.Context()); user != nil {
+		createdBy = user.ID
+	}
+	run, err := h.svc.Trigger(r.Context(), model.MaintenanceTriggerManual, req.Tables, req.DryRun, createdBy)
+	if err != nil {
+		writeServiceError(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, run)
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeServiceError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, ErrNotSupported):
+		writeError(w, http.StatusNotImplemented, err.Error())
+	case errors.Is(err, ErrRunning):
+		writeError(w, http.StatusConflict, err.Error())
+	case errors.Is(err, ErrTableNotAllowed):
+		writeError(w, http.StatusBadRequest, err.Error())
+	default:
+		slog.ErrorContext(r.Context(), "maintenance.request.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "maintenance failed")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
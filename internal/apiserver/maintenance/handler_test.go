@@ -0,0 +1,108 @@
+package maintenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+func newTestMux(t *testing.T, maintainer Maintainer) (*Service, http.Handler) {
+	t.Helper()
+	svc, err := NewService(newMockStore(), maintainer, "sqlite", Config{Window: "03:00-05:00"})
+	if err != nil {
+		t.Fatalf("NewService failed: %v", err)
+	}
+	mux := http.NewServeMux()
+	NewHandler(svc).RegisterRoutes(mux)
+	return svc, auth.Middleware(auth.Config{})(mux) // 无认证模式（未配置 JWT 密钥）
+}
+
+func TestHandler_EstimateAndRuns(t *testing.T) {
+	svc, mux := newTestMux(t, &mockMaintainer{})
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/maintenance/estimate?tables=events", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("estimate: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var estimate struct {
+		Tables           []model.MaintenanceTable `json:"tables"`
+		ReclaimableBytes int64                    `json:"reclaimable_bytes"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &estimate)
+	if len(estimate.Tables) != 1 || estimate.ReclaimableBytes != 300 {
+		t.Errorf("unexpected estimate: %s", w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/admin/maintenance/runs", bytes.NewBufferString(`{"dry_run":true}`)))
+	if w.Code != http.StatusAccepted {
+		t.Fatalf("create run: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	svc.Wait()
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/admin/maintenance/runs", nil))
+	var list struct {
+		Runs     []*model.MaintenanceRun `json:"runs"`
+		Running  bool                    `json:"running"`
+		Schedule map[string]interface{}  `json:"schedule"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if len(list.Runs) != 1 || !list.Runs[0].DryRun || list.Runs[0].Status != model.MaintenanceStatusSuccess {
+		t.Errorf("unexpected runs: %s", w.Body.String())
+	}
+	if list.Running || list.Schedule["window"] != "03:00-05:00" {
+		t.Errorf("unexpected schedule: %s", w.Body.String())
+	}
+}
+
+func TestHandler_Errors(t *testing.T) {
+	_, mux := newTestMux(t, &mockMaintainer{})
+	_, unsupported := newTestMux(t, nil)
+
+	tests := []struct {
+		name       string
+		mux        http.Handler
+		req        *http.Request
+		wantStatus int
+	}{
+		{"table not allowed", mux, httptest.NewRequest("GET", "/api/v1/admin/maintenance/estimate?tables=users", nil), http.StatusBadRequest},
+		{"invalid limit", mux, httptest.NewRequest("GET", "/api/v1/admin/maintenance/runs?limit=x", nil), http.StatusBadRequest},
+		{"invalid body", mux, httptest.NewRequest("POST", "/api/v1/admin/maintenance/runs", bytes.NewBufferString("{")), http.StatusBadRequest},
+		{"unsupported driver", unsupported, httptest.NewRequest("POST", "/api/v1/admin/maintenance/runs", nil), http.StatusNotImplemented},
+		{"regular user", mux, httptest.NewRequest("GET", "/api/v1/admin/maintenance/runs", nil).WithContext(
+			auth.WithAuthUser(t.Context(), &auth.AuthUser{ID: "u1", Role: "user"})), http.StatusForbidden},
+		{"node credential", mux, httptest.NewRequest("POST", "/api/v1/admin/maintenance/runs", nil).WithContext(
+			auth.WithNodeIdentity(t.Context(), "node-1")), http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.mux.ServeHTTP(w, tt.req)
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CreateRunConflict(t *testing.T) {
+	maintainer := &mockMaintainer{block: make(chan struct{})}
+	svc, mux := newTestMux(t, maintainer)
+	defer svc.Wait()
+	defer close(maintainer.block)
+
+	for i, want := range []int{http.StatusAccepted, http.StatusConflict} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/admin/maintenance/runs", nil))
+		if w.Code != want {
+			t.Errorf("request %d: status = %d, want %d", i, w.Code, want)
+		}
+	}
+}
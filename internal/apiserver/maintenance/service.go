@@ -0,0 +1,368 @@
+// Package maintenance 存储维护任务
+//
+// 定期对高增长的表（events、runs 等）执行驱动级维护，回收删除与更新留下的空间：
+//   - PostgreSQL：VACUUM (ANALYZE)
+//   - SQLite：ANALYZE + incremental_vacuum
+//   - MongoDB：compact
+//
+// 定时维护只在配置的本地时间窗口内执行，且两次之间至少间隔 Interval；
+// 管理员可随时手动触发或只做 dry-run 估算。每次执行都记录到维护历史。
+package maintenance
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// checkInterval 定时维护的检查间隔
+	checkInterval = time.Minute
+
+	// runTimeout 单次维护的最长执行时间
+	runTimeout = 2 * time.Hour
+
+	// historyLookback 启动时从历史中查找上次定时维护的记录数
+	historyLookback = 50
+)
+
+// AllowedTables 允许维护的表（MongoDB 为同名集合）
+var AllowedTables = []string{"events", "runs", "tasks", "nodes", "operations", "actions", "run_flags", "event_originals", "maintenance_runs", "outbox_messages"}
+
+// DefaultTables 未配置时维护的表
+var DefaultTables = []string{"events", "runs"}
+
+var (
+	// ErrNotSupported 当前存储驱动不支持维护
+	ErrNotSupported = errors.New("storage maintenance not supported by current driver")
+
+	// ErrRunning 已有维护在执行
+	ErrRunning = errors.New("maintenance already running")
+
+	// ErrTableNotAllowed 表不在 AllowedTables 中
+	ErrTableNotAllowed = errors.New("table not allowed for maintenance")
+)
+
+// Store 维护历史存储接口
+type Store interface {
+	CreateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error
+	UpdateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error
+	ListMaintenanceRuns(ctx context.Context, limit int) ([]*model.MaintenanceRun, error)
+}
+
+// Maintainer 驱动级维护接口（与 storage.Maintainer 一致）
+type Maintainer interface {
+	EstimateMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error)
+	RunMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error)
+}
+
+// Config 维护任务配置
+type Config struct {
+	Enabled  bool          // 是否启用定时维护
+	Window   string        // 本地时间窗口 "HH:MM-HH:MM"（可跨零点），为空不限制
+	Interval time.Duration // 两次定时维护的最小间隔
+	Tables   []string      // 定时维护的表
+	DryRun   bool          // 定时维护只估算
+}
+
+// Service 存储维护服务
+type Service struct {
+	store      Store
+	maintainer Maintainer // 驱动不支持时为 nil
+	driver     string
+	cfg        Config
+	window     *Window
+
+	running       atomic.Bool
+	wg            sync.WaitGroup
+	mu            sync.Mutex
+	lastScheduled time.Time
+}
+
+// NewService 创建存储维护服务
+//
+// maintainer 为 nil 表示当前驱动不支持维护：历史仍可查询，估算与执行返回 ErrNotSupported。
+func NewService(store Store, maintainer Maintainer, driver string, cfg Config) (*Service, error) {
+	window, err := ParseWindow(cfg.Window)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	if len(cfg.Tables) == 0 {
+		cfg.Tables = DefaultTables
+	}
+	if err := validateTables(cfg.Tables); err != nil {
+		return nil, err
+	}
+	return &Service{store: store, maintainer: maintainer, driver: driver, cfg: cfg, window: window}, nil
+}
+
+// Config 返回生效的配置
+func (s *Service) Config() Config {
+	return s.cfg
+}
+
+// Running 是否有维护在执行
+func (s *Service) Running() bool {
+	return s.running.Load()
+}
+
+// Estimate 估算各表的占用与可回收空间（不修改数据库）
+func (s *Service) Estimate(ctx context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	if s.maintainer == nil {
+		return nil, ErrNotSupported
+	}
+	if len(tables) == 0 {
+		tables = s.cfg.Tables
+	}
+	if err := validateTables(tables); err != nil {
+		return nil, err
+	}
+	return s.maintainer.EstimateMaintenance(ctx, tables)
+}
+
+// Trigger 创建维护记录并在后台执行，返回状态为 running 的记录
+//
+// 同一时刻只允许一次维护执行，已有执行时返回 ErrRunning。
+func (s *Service) Trigger(ctx context.Context, trigger model.MaintenanceTrigger, tables []string, dryRun bool, createdBy string) (*model.MaintenanceRun, error) {
+	if s.maintainer == nil {
+		return nil, ErrNotSupported
+	}
+	if len(tables) == 0 {
+		tables = s.cfg.Tables
+	}
+	if err := validateTables(tables); err != nil {
+		return nil, err
+	}
+	if !s.running.CompareAndSwap(false, true) {
+		return nil, ErrRunning
+	}
+
+	run := &model.MaintenanceRun{
+		ID:        generateID(),
+		Driver:    s.driver,
+		Trigger:   trigger,
+		DryRun:    dryRun,
+		Status:    model.MaintenanceStatusRunning,
+		CreatedBy: createdBy,
+		StartedAt: time.Now(),
+	}
+	if err := s.store.CreateMaintenanceRun(ctx, run); err != nil {
+		s.running.Store(false)
+		return nil, fmt.Errorf("create maintenance run: %w", err)
+	}
+	slog.InfoContext(ctx, "maintenance.run.start", "id", run.ID, "driver", run.Driver, "trigger", trigger, "dry_run", dryRun, "tables", tables)
+
+	snapshot := *run
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer s.running.Store(false)
+		s.execute(run, tables)
+	}()
+	return &snapshot, nil
+}
+
+// Wait 等待后台执行的维护结束
+func (s *Service) Wait() {
+	s.wg.Wait()
+}
+
+// execute 执行维护并更新记录
+func (s *Service) execute(run *model.MaintenanceRun, tables []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), runTimeout)
+	defer cancel()
+
+	var (
+		results []model.MaintenanceTable
+		err     error
+	)
+	if run.DryRun {
+		results, err = s.maintainer.EstimateMaintenance(ctx, tables)
+	} else {
+		results, err = s.maintainer.RunMaintenance(ctx, tables)
+	}
+	finishRun(run, results, err)
+
+	// 执行可能因超时结束，更新记录使用独立的 context
+	updateCtx, updateCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer updateCancel()
+	if err := s.store.UpdateMaintenanceRun(updateCtx, run); err != nil {
+		slog.ErrorContext(ctx, "maintenance.run.update_failed", "id", run.ID, "error", err)
+	}
+	slog.InfoContext(ctx, "maintenance.run.done", "id", run.ID, "status", run.Status,
+		"reclaimable_bytes", run.ReclaimableBytes, "reclaimed_bytes", run.ReclaimedBytes)
+}
+
+// finishRun 汇总各表结果，计算执行状态与回收空间
+func finishRun(run *model.MaintenanceRun, results []model.MaintenanceTable, err error) {
+	now := time.Now()
+	run.FinishedAt = &now
+	run.Tables = results
+	if err != nil {
+		run.Status = model.MaintenanceStatusFailed
+		run.Error = err.Error()
+		return
+	}
+
+	failed := 0
+	for _, t := range results {
+		if t.Error != "" {
+			failed++
+			continue
+		}
+		run.ReclaimableBytes += t.ReclaimableBytes
+		if t.SizeAfterBytes != nil && *t.SizeAfterBytes < t.SizeBytes {
+			run.ReclaimedBytes += t.SizeBytes - *t.SizeAfterBytes
+		}
+	}
+	switch {
+	case failed == 0:
+		run.Status = model.MaintenanceStatusSuccess
+	case failed == len(results):
+		run.Status = model.MaintenanceStatusFailed
+	default:
+		run.Status = model.MaintenanceStatusPartial
+	}
+}
+
+// ============================================================================
+// 定时维护
+// ============================================================================
+
+// Start 启动定时维护循环（阻塞直到 ctx 取消，未启用或驱动不支持时立即返回）
+func (s *Service) Start(ctx context.Context) {
+	if !s.cfg.Enabled {
+		return
+	}
+	if s.maintainer == nil {
+		slog.InfoContext(ctx, "maintenance.schedule.disabled", "driver", s.driver, "reason", "unsupported")
+		return
+	}
+	s.loadLastScheduled(ctx)
+	slog.InfoContext(ctx, "maintenance.schedule.start", "driver", s.driver, "window", s.cfg.Window,
+		"interval", s.cfg.Interval, "tables", s.cfg.Tables, "dry_run", s.cfg.DryRun)
+
+	ticker := time.NewTicker(checkInterval)
+	defer ticker.Stop()
+	for {
+		s.tick(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// tick 到期时触发一次定时维护
+func (s *Service) tick(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	due := s.due(now)
+	s.mu.Unlock()
+	if !due {
+		return
+	}
+	if _, err := s.Trigger(ctx, model.MaintenanceTriggerScheduled, nil, s.cfg.DryRun, ""); err != nil {
+		if !errors.Is(err, ErrRunning) {
+			slog.ErrorContext(ctx, "maintenance.schedule.failed", "error", err)
+		}
+		return
+	}
+	s.mu.Lock()
+	s.lastScheduled = now
+	s.mu.Unlock()
+}
+
+// due 是否应执行定时维护：处于时间窗口内，且距上次定时维护已超过 Interval
+func (s *Service) due(now time.Time) bool {
+	if s.window != nil && !s.window.Contains(now) {
+		return false
+	}
+	return s.lastScheduled.IsZero() || now.Sub(s.lastScheduled) >= s.cfg.Interval
+}
+
+// loadLastScheduled 从维护历史恢复上次定时维护时间，避免重启后在同一窗口内重复执行
+func (s *Service) loadLastScheduled(ctx context.Context) {
+	runs, err := s.store.ListMaintenanceRuns(ctx, historyLookback)
+	if err != nil {
+		slog.ErrorContext(ctx, "maintenance.history.failed", "error", err)
+		return
+	}
+	for _, r := range runs {
+		if r.Trigger == model.MaintenanceTriggerScheduled {
+			s.mu.Lock()
+			s.lastScheduled = r.StartedAt
+			s.mu.Unlock()
+			return
+		}
+	}
+}
+
+// ============================================================================
+// 时间窗口
+// ============================================================================
+
+// Window 每日本地时间窗口 [Start, End)，以当日零点起的分钟数表示，End < Start 表示跨零点
+type Window struct {
+	Start int
+	End   int
+}
+
+// ParseWindow 解析 "HH:MM-HH:MM" 格式的时间窗口，空字符串返回 nil（不限制）
+func ParseWindow(s string) (*Window, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var sh, sm, eh, em int
+	if n, err := fmt.Sscanf(s, "%d:%d-%d:%d", &sh, &sm, &eh, &em); err != nil || n != 4 {
+		return nil, fmt.Errorf("invalid maintenance window %q: want HH:MM-HH:MM", s)
+	}
+	if sh < 0 || sh > 23 || eh < 0 || eh > 23 || sm < 0 || sm > 59 || em < 0 || em > 59 {
+		return nil, fmt.Errorf("invalid maintenance window %q: time out of range", s)
+	}
+	w := &Window{Start: sh*60 + sm, End: eh*60 + em}
+	if w.Start == w.End {
+		return nil, fmt.Errorf("invalid maintenance window %q: empty window", s)
+	}
+	return w, nil
+}
+
+// Contains t（本地时间）是否处于窗口内
+func (w *Window) Contains(t time.Time) bool {
+	m := t.Hour()*60 + t.Minute()
+	if w.Start < w.End {
+		return m >= w.Start && m < w.End
+	}
+	return m >= w.Start || m < w.End
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func validateTables(tables []string) error {
+	for _, t := range tables {
+		if !slices.Contains(AllowedTables, t) {
+			return fmt.Errorf("%w: %q", ErrTableNotAllowed, t)
+		}
+	}
+	return nil
+}
+
+func generateID() string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return "mnt-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,227 @@
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存维护历史
+type mockStore struct {
+	mu   sync.Mutex
+	runs map[string]*model.MaintenanceRun
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{runs: make(map[string]*model.MaintenanceRun)}
+}
+
+func (m *mockStore) CreateMaintenanceRun(_ context.Context, r *model.MaintenanceRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *r
+	m.runs[r.ID] = &cp
+	return nil
+}
+
+func (m *mockStore) UpdateMaintenanceRun(_ context.Context, r *model.MaintenanceRun) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cp := *r
+	m.runs[r.ID] = &cp
+	return nil
+}
+
+func (m *mockStore) ListMaintenanceRuns(_ context.Context, limit int) ([]*model.MaintenanceRun, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.MaintenanceRun
+	for _, r := range m.runs {
+		result = append(result, r)
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *mockStore) get(id string) *model.MaintenanceRun {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.runs[id]
+}
+
+// mockMaintainer 记录调用并返回固定结果，block 非 nil 时执行阻塞到其关闭
+type mockMaintainer struct {
+	block  chan struct{}
+	failOn string
+	runs   int
+	runsMu sync.Mutex
+}
+
+func (m *mockMaintainer) result(tables []string, after bool) []model.MaintenanceTable {
+	var out []model.MaintenanceTable
+	for _, t := range tables {
+		r := model.MaintenanceTable{Table: t, SizeBytes: 1000, ReclaimableBytes: 300}
+		if t == m.failOn {
+			r.Error = "boom"
+		} else if after {
+			size := int64(700)
+			r.SizeAfterBytes = &size
+		}
+		out = append(out, r)
+	}
+	return out
+}
+
+func (m *mockMaintainer) EstimateMaintenance(_ context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	return m.result(tables, false), nil
+}
+
+func (m *mockMaintainer) RunMaintenance(_ context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	if m.block != nil {
+		<-m.block
+	}
+	m.runsMu.Lock()
+	m.runs++
+	m.runsMu.Unlock()
+	return m.result(tables, true), nil
+}
+
+func TestParseWindow(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 1, h, m, 0, 0, time.Local) }
+
+	w, err := ParseWindow("03:00-05:30")
+	if err != nil {
+		t.Fatalf("ParseWindow failed: %v", err)
+	}
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{{at(2, 59), false}, {at(3, 0), true}, {at(5, 29), true}, {at(5, 30), false}} {
+		if got := w.Contains(tc.t); got != tc.want {
+			t.Errorf("Contains(%s) = %v, want %v", tc.t.Format("15:04"), got, tc.want)
+		}
+	}
+
+	// 跨零点
+	w, _ = ParseWindow("23:00-01:00")
+	for _, tc := range []struct {
+		t    time.Time
+		want bool
+	}{{at(22, 59), false}, {at(23, 30), true}, {at(0, 30), true}, {at(1, 0), false}} {
+		if got := w.Contains(tc.t); got != tc.want {
+			t.Errorf("wrapped Contains(%s) = %v, want %v", tc.t.Format("15:04"), got, tc.want)
+		}
+	}
+
+	if w, err := ParseWindow(""); err != nil || w != nil {
+		t.Errorf("empty window = %v, %v; want nil, nil", w, err)
+	}
+	for _, bad := range []string{"3am-5am", "25:00-01:00", "03:00-03:00", "03:00"} {
+		if _, err := ParseWindow(bad); err == nil {
+			t.Errorf("ParseWindow(%q) should fail", bad)
+		}
+	}
+}
+
+func TestService_Due(t *testing.T) {
+	svc, err := NewService(newMockStore(), &mockMaintainer{}, "sqlite", Config{Enabled: true, Window: "03:00-05:00", Interval: 24 * time.Hour})
+	if err != nil {
+		t.Fatalf("NewService failed: %v", err)
+	}
+	day1 := time.Date(2024, 1, 1, 3, 10, 0, 0, time.Local)
+
+	if svc.due(day1.Add(-time.Hour)) {
+		t.Error("should not be due outside window")
+	}
+	if !svc.due(day1) {
+		t.Error("should be due inside window without previous run")
+	}
+	svc.lastScheduled = day1
+	if svc.due(day1.Add(time.Hour)) {
+		t.Error("should not run twice in the same window")
+	}
+	if !svc.due(day1.Add(24 * time.Hour)) {
+		t.Error("should be due in the next day's window")
+	}
+}
+
+func TestService_TriggerRecordsHistory(t *testing.T) {
+	store := newMockStore()
+	maintainer := &mockMaintainer{failOn: "runs"}
+	svc, _ := NewService(store, maintainer, "postgres", Config{})
+
+	run, err := svc.Trigger(t.Context(), model.MaintenanceTriggerManual, nil, false, "admin")
+	if err != nil {
+		t.Fatalf("Trigger failed: %v", err)
+	}
+	if run.Status != model.MaintenanceStatusRunning {
+		t.Errorf("status = %s, want running", run.Status)
+	}
+	svc.Wait()
+
+	got := store.get(run.ID)
+	if got.Status != model.MaintenanceStatusPartial {
+		t.Errorf("status = %s, want partial", got.Status)
+	}
+	if len(got.Tables) != 2 || got.ReclaimedBytes != 300 || got.ReclaimableBytes != 300 {
+		t.Errorf("unexpected result: %+v", got)
+	}
+	if got.FinishedAt == nil || got.Driver != "postgres" || got.CreatedBy != "admin" {
+		t.Errorf("unexpected record: %+v", got)
+	}
+
+	// dry-run 只估算
+	run, _ = svc.Trigger(t.Context(), model.MaintenanceTriggerManual, []string{"events"}, true, "")
+	svc.Wait()
+	got = store.get(run.ID)
+	if got.Status != model.MaintenanceStatusSuccess || got.ReclaimedBytes != 0 || maintainer.runs != 1 {
+		t.Errorf("dry-run should not execute maintenance: %+v runs=%d", got, maintainer.runs)
+	}
+}
+
+func TestService_TriggerRejects(t *testing.T) {
+	maintainer := &mockMaintainer{block: make(chan struct{})}
+	svc, _ := NewService(newMockStore(), maintainer, "sqlite", Config{})
+
+	if _, err := svc.Trigger(t.Context(), model.MaintenanceTriggerManual, []string{"users"}, false, ""); !errors.Is(err, ErrTableNotAllowed) {
+		t.Errorf("err = %v, want ErrTableNotAllowed", err)
+	}
+
+	if _, err := svc.Trigger(t.Context(), model.MaintenanceTriggerManual, nil, false, ""); err != nil {
+		t.Fatalf("Trigger failed: %v", err)
+	}
+	if _, err := svc.Trigger(t.Context(), model.MaintenanceTriggerManual, nil, false, ""); !errors.Is(err, ErrRunning) {
+		t.Errorf("err = %v, want ErrRunning", err)
+	}
+	close(maintainer.block)
+	svc.Wait()
+	if svc.Running() {
+		t.Error("should not be running after completion")
+	}
+
+	unsupported, _ := NewService(newMockStore(), nil, "mysql", Config{})
+	if _, err := unsupported.Trigger(t.Context(), model.MaintenanceTriggerManual, nil, false, ""); !errors.Is(err, ErrNotSupported) {
+		t.Errorf("err = %v, want ErrNotSupported", err)
+	}
+}
+
+func TestService_LoadLastScheduled(t *testing.T) {
+	store := newMockStore()
+	started := time.Now().Add(-time.Hour).Truncate(time.Second)
+	store.runs["mnt-1"] = &model.MaintenanceRun{ID: "mnt-1", Trigger: model.MaintenanceTriggerScheduled, StartedAt: started}
+	svc, _ := NewService(store, &mockMaintainer{}, "sqlite", Config{Enabled: true})
+
+	svc.loadLastScheduled(t.Context())
+	if !svc.lastScheduled.Equal(started) {
+		t.Errorf("lastScheduled = %v, want %v", svc.lastScheduled, started)
+	}
+	if svc.due(time.Now()) {
+		t.Error("should not be due within interval after restart")
+	}
+}
@@ -0,0 +1,181 @@
+package moderation
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Detector 内容检测器：正则匹配，可选二次校验以降低误报
+type Detector struct {
+	Name     string
+	Severity model.ModerationSeverity
+	pattern  *regexp.Regexp
+	verify   func(match string) bool // 为 nil 时正则命中即视为命中
+}
+
+// builtinDetectors 内置检测器（名称 → 定义），按名称在配置中启用
+var builtinDetectors = map[string]*Detector{
+	"email": {
+		Severity: model.ModerationSeverityLow,
+		pattern:  regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`),
+	},
+	"phone": {
+		Severity: model.ModerationSeverityLow,
+		pattern:  regexp.MustCompile(`(?:\+?86[- ]?)?\b1[3-9]\d{9}\b|\+\d{1,3}[ -]?\(?\d{2,4}\)?[ -]?\d{3,4}[ -]?\d{3,4}\b`),
+	},
+	"ipv4": {
+		Severity: model.ModerationSeverityLow,
+		pattern:  regexp.MustCompile(`\b(?:(?:25[0-5]|2[0-4]\d|1?\d?\d)\.){3}(?:25[0-5]|2[0-4]\d|1?\d?\d)\b`),
+	},
+	"id_card": {
+		Severity: model.ModerationSeverityMedium,
+		pattern:  regexp.MustCompile(`\b\d{17}[\dXx]\b`),
+		verify:   validIDCard,
+	},
+	"credit_card": {
+		Severity: model.ModerationSeverityHigh,
+		pattern:  regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`),
+		verify:   validLuhn,
+	},
+	"api_key": {
+		Severity: model.ModerationSeverityCritical,
+		pattern: regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b|\bgh[pousr]_[A-Za-z0-9]{36,}\b|\bsk-[A-Za-z0-9_-]{20,}|\bxox[abprs]-[A-Za-z0-9-]{10,}|` +
+			`-----BEGIN [A-Z ]*PRIVATE KEY-----`),
+	},
+	"secret": newSecretDetector(DefaultEntropyMinLength, DefaultEntropyThreshold),
+}
+
+const (
+	// DefaultEntropyMinLength secret 检测器的默认最小长度
+	DefaultEntropyMinLength = 24
+	// DefaultEntropyThreshold secret 检测器的默认香农熵阈值（比特/字符）
+	DefaultEntropyThreshold = 4.0
+)
+
+// newSecretDetector 基于熵的密钥检测：不少于 minLength 个字符的 token，同时含大小写字母与数字且熵不低于 threshold
+//
+// 覆盖没有固定前缀的随机密钥（如会话令牌、Base64 编码的密钥）；全小写的十六进制串（提交哈希、UUID）不视为密钥。
+func newSecretDetector(minLength int, threshold float64) *Detector {
+	return &Detector{
+		Severity: model.ModerationSeverityHigh,
+		pattern:  regexp.MustCompile(fmt.Sprintf(`[A-Za-z0-9+_-]{%d,}={0,2}`, minLength)),
+		verify: func(m string) bool {
+			return mixedCase(m) && shannonEntropy(m) >= threshold
+		},
+	}
+}
+
+// BuiltinDetectorNames 内置检测器名称（按严重级别由低到高）
+func BuiltinDetectorNames() []string {
+	return []string{"email", "phone", "ipv4", "id_card", "credit_card", "secret", "api_key"}
+}
+
+// CustomDetector 自定义检测规则
+type CustomDetector struct {
+	Name     string                   `yaml:"name" json:"name"`
+	Pattern  string                   `yaml:"pattern" json:"pattern"` // Go 正则表达式
+	Severity model.ModerationSeverity `yaml:"severity" json:"severity"`
+}
+
+// compile 校验并编译自定义规则
+func (c CustomDetector) compile() (*Detector, error) {
+	if strings.TrimSpace(c.Name) == "" {
+		return nil, fmt.Errorf("custom detector name is required")
+	}
+	if _, ok := builtinDetectors[c.Name]; ok {
+		return nil, fmt.Errorf("custom detector %q conflicts with a builtin detector", c.Name)
+	}
+	severity := c.Severity
+	if severity == "" {
+		severity = model.ModerationSeverityMedium
+	}
+	if !severity.IsValid() {
+		return nil, fmt.Errorf("custom detector %q: invalid severity %q", c.Name, c.Severity)
+	}
+	re, err := regexp.Compile(c.Pattern)
+	if err != nil || c.Pattern == "" {
+		return nil, fmt.Errorf("custom detector %q: invalid pattern: %v", c.Name, err)
+	}
+	return &Detector{Name: c.Name, Severity: severity, pattern: re}, nil
+}
+
+// mask 将命中内容替换为 [REDACTED:<name>]，返回替换结果与命中次数
+func (d *Detector) mask(s string) (string, int) {
+	count := 0
+	masked := d.pattern.ReplaceAllStringFunc(s, func(m string) string {
+		if d.verify != nil && !d.verify(m) {
+			return m
+		}
+		count++
+		return "[REDACTED:" + d.Name + "]"
+	})
+	return masked, count
+}
+
+// validLuhn 信用卡号 Luhn 校验（忽略空格与连字符）
+func validLuhn(s string) bool {
+	digits := strings.NewReplacer(" ", "", "-", "").Replace(s)
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return sum%10 == 0
+}
+
+// validIDCard 18 位居民身份证号校验码校验（GB 11643）
+func validIDCard(s string) bool {
+	weights := []int{7, 9, 10, 5, 8, 4, 2, 1, 6, 3, 7, 9, 10, 5, 8, 4, 2}
+	sum := 0
+	for i, w := range weights {
+		sum += int(s[i]-'0') * w
+	}
+	check := "10X98765432"[sum%11]
+	return strings.ToUpper(s[17:]) == string(check)
+}
+
+// shannonEntropy 字符串的香农熵（比特/字符）
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// mixedCase 是否同时含大写字母、小写字母与数字
+func mixedCase(s string) bool {
+	var upper, lower, digit bool
+	for _, r := range s {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		}
+	}
+	return upper && lower && digit
+}
@@ -0,0 +1,110 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// Store 定义内容审核接口需要的存储方法（用于测试 mock）
+type Store interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	ListRunFlags(ctx context.Context, runID string) ([]*model.RunFlag, error)
+	GetEventOriginal(ctx context.Context, runID string, seq int) (*model.EventOriginal, error)
+}
+
+// Handler 内容审核标记查询处理器
+type Handler struct {
+	store Store
+}
+
+// NewHandler 创建内容审核处理器
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes 注册内容审核相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/runs/{id}/flags", h.ListFlags)
+	mux.HandleFunc("GET /api/v1/runs/{id}/events/{seq}/original", auth.AdminOnly(h.GetOriginal))
+}
+
+// ListFlags 列出 Run 的内容审核标记
+// GET /api/v1/runs/{id}/flags
+//
+// 响应: {"flags": [...], "count": 2, "aborted": false}
+func (h *Handler) ListFlags(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("id")
+	run, err := h.store.GetRun(r.Context(), runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	flags, err := h.store.ListRunFlags(r.Context(), runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list flags")
+		return
+	}
+	if flags == nil {
+		flags = []*model.RunFlag{}
+	}
+	aborted := false
+	for _, f := range flags {
+		if f.Aborted {
+			aborted = true
+		}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"flags": flags, "count": len(flags), "aborted": aborted})
+}
+
+// GetOriginal 查看被掩码事件的原文（仅管理员，需启用 retain_originals）
+// GET /api/v1/runs/{id}/events/{seq}/original
+//
+// 响应: {"run_id": "...", "seq": 3, "payload": {...}, "raw": "...", "created_at": "..."}
+func (h *Handler) GetOriginal(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("id")
+	seq, err := strconv.Atoi(r.PathValue("seq"))
+	if err != nil || seq <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid event seq")
+		return
+	}
+	original, err := h.store.GetEventOriginal(r.Context(), runID, seq)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get event original")
+		return
+	}
+	if original == nil {
+		writeError(w, http.StatusNotFound, "event original not retained")
+		return
+	}
+	userID := ""
+	if user := auth.GetAuthUser(r.Context()); user != nil {
+		userID = user.ID
+	}
+	slog.InfoContext(r.Context(), "moderation.original.viewed", "run_id", runID, "seq", seq, "user_id", userID)
+	writeJSON(w, http.StatusOK, original)
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,124 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+type mockStore struct {
+	runs      map[string]*model.Run
+	flags     map[string][]*model.RunFlag
+	originals map[int]*model.EventOriginal
+}
+
+func (m *mockStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	return m.runs[id], nil
+}
+
+func (m *mockStore) ListRunFlags(_ context.Context, runID string) ([]*model.RunFlag, error) {
+	return m.flags[runID], nil
+}
+
+func (m *mockStore) GetEventOriginal(_ context.Context, runID string, seq int) (*model.EventOriginal, error) {
+	if o := m.originals[seq]; o != nil && o.RunID == runID {
+		return o, nil
+	}
+	return nil, nil
+}
+
+func TestListFlags(t *testing.T) {
+	store := &mockStore{
+		runs: map[string]*model.Run{"run-1": {ID: "run-1"}, "run-2": {ID: "run-2"}},
+		flags: map[string][]*model.RunFlag{"run-1": {
+			{ID: 1, RunID: "run-1", EventSeq: 2, Detector: "email", Severity: model.ModerationSeverityLow, Count: 1},
+			{ID: 2, RunID: "run-1", EventSeq: 5, Detector: "api_key", Severity: model.ModerationSeverityCritical, Count: 1, Aborted: true},
+		}},
+	}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	tests := []struct {
+		runID   string
+		status  int
+		count   int
+		aborted bool
+	}{
+		{"run-1", http.StatusOK, 2, true},
+		{"run-2", http.StatusOK, 0, false},
+		{"missing", http.StatusNotFound, 0, false},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/runs/"+tt.runID+"/flags", nil))
+		if w.Code != tt.status {
+			t.Fatalf("%s: expected %d, got %d", tt.runID, tt.status, w.Code)
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var resp struct {
+			Flags   []*model.RunFlag `json:"flags"`
+			Count   int              `json:"count"`
+			Aborted bool             `json:"aborted"`
+		}
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Count != tt.count || len(resp.Flags) != tt.count || resp.Aborted != tt.aborted {
+			t.Errorf("%s: unexpected response %+v", tt.runID, resp)
+		}
+	}
+}
+
+func TestGetOriginal(t *testing.T) {
+	raw := `token=AbCdEf1234GhIjKl5678MnOpQr`
+	store := &mockStore{originals: map[int]*model.EventOriginal{
+		3: {RunID: "run-1", Seq: 3, Payload: json.RawMessage(`{"content":"AbCdEf1234GhIjKl5678MnOpQr"}`), Raw: &raw},
+	}}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+	handler := auth.Middleware(auth.Config{})(mux) // 无认证模式（未配置 JWT 密钥）
+
+	admin := &auth.AuthUser{ID: "u-1", Role: auth.UserRoleAdmin}
+	user := &auth.AuthUser{ID: "u-2", Role: "user"}
+	tests := []struct {
+		path   string
+		user   *auth.AuthUser
+		status int
+	}{
+		{"/api/v1/runs/run-1/events/3/original", admin, http.StatusOK},
+		{"/api/v1/runs/run-1/events/3/original", nil, http.StatusOK},
+		{"/api/v1/runs/run-1/events/3/original", user, http.StatusForbidden},
+		{"/api/v1/runs/run-1/events/4/original", admin, http.StatusNotFound},
+		{"/api/v1/runs/run-2/events/3/original", admin, http.StatusNotFound},
+		{"/api/v1/runs/run-1/events/x/original", admin, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		if tt.user != nil {
+			req = req.WithContext(auth.WithAuthUser(req.Context(), tt.user))
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s (%v): expected %d, got %d", tt.path, tt.user, tt.status, w.Code)
+			continue
+		}
+		if tt.status != http.StatusOK {
+			continue
+		}
+		var got model.EventOriginal
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got.Seq != 3 || got.Raw == nil || *got.Raw != raw {
+			t.Errorf("unexpected original: %+v", got)
+		}
+	}
+}
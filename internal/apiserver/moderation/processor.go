@@ -0,0 +1,222 @@
+// Package moderation Agent 输出内容审核
+//
+// 可选的事件处理阶段：API Server 在 Agent 事件入库前，用配置的检测器（内置 PII 与密钥检测器、
+// 基于熵的密钥检测与自定义正则规则）扫描事件载荷与原始输出：
+//   - 命中内容以 [REDACTED:<检测器>] 掩码替换后再存储与推送，事件标记为 redacted
+//   - 启用 RetainOriginals 时另存掩码前的原文（EventOriginal），仅管理员可查看
+//   - 每个事件中每个命中的检测器记录为 Run 标记（RunFlag），并累计到命中统计（Stats，用于 Prometheus 指标）
+//   - 命中严重级别达到 abort_severity 时终止 Run
+package moderation
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Config 内容审核策略
+type Config struct {
+	// Detectors 启用的内置检测器名称，为空时启用全部内置检测器
+	Detectors []string
+	// Custom 自定义检测规则
+	Custom []CustomDetector
+	// AbortSeverity 命中该级别及以上时终止 Run，为空时只掩码与标记
+	AbortSeverity model.ModerationSeverity
+	// EntropyMinLength secret 检测器的最小长度，<= 0 时使用 DefaultEntropyMinLength
+	EntropyMinLength int
+	// EntropyThreshold secret 检测器的熵阈值，<= 0 时使用 DefaultEntropyThreshold
+	EntropyThreshold float64
+	// RetainOriginals 是否另存被掩码事件的原文
+	RetainOriginals bool
+}
+
+// Stats 命中统计（进程启动以来累计）
+type Stats struct {
+	Hits           map[string]int64 // 检测器 → 掩码次数
+	RedactedEvents int64            // 被掩码的事件数
+}
+
+// Processor 内容审核处理器（并发安全，检测器创建后只读）
+type Processor struct {
+	detectors       []*Detector // 自定义规则优先，其次内置检测器按严重级别由高到低
+	abortSeverity   model.ModerationSeverity
+	retainOriginals bool
+
+	mu    sync.Mutex
+	stats Stats
+}
+
+// New 校验策略并创建处理器
+func New(cfg Config) (*Processor, error) {
+	if cfg.AbortSeverity != "" && !cfg.AbortSeverity.IsValid() {
+		return nil, fmt.Errorf("invalid abort_severity %q", cfg.AbortSeverity)
+	}
+	p := &Processor{
+		abortSeverity:   cfg.AbortSeverity,
+		retainOriginals: cfg.RetainOriginals,
+		stats:           Stats{Hits: make(map[string]int64)},
+	}
+
+	for _, c := range cfg.Custom {
+		d, err := c.compile()
+		if err != nil {
+			return nil, err
+		}
+		p.detectors = append(p.detectors, d)
+	}
+
+	enabled := make(map[string]bool)
+	for _, name := range cfg.Detectors {
+		if _, ok := builtinDetectors[name]; !ok {
+			return nil, fmt.Errorf("unknown detector %q", name)
+		}
+		enabled[name] = true
+	}
+	names := BuiltinDetectorNames()
+	for i := len(names) - 1; i >= 0; i-- {
+		name := names[i]
+		if len(enabled) > 0 && !enabled[name] {
+			continue
+		}
+		d := *builtinDetectors[name]
+		if name == "secret" && (cfg.EntropyMinLength > 0 || cfg.EntropyThreshold > 0) {
+			minLength, threshold := cfg.EntropyMinLength, cfg.EntropyThreshold
+			if minLength <= 0 {
+				minLength = DefaultEntropyMinLength
+			}
+			if threshold <= 0 {
+				threshold = DefaultEntropyThreshold
+			}
+			d = *newSecretDetector(minLength, threshold)
+		}
+		d.Name = name
+		p.detectors = append(p.detectors, &d)
+	}
+	return p, nil
+}
+
+// Result 一批事件的审核结果
+type Result struct {
+	Flags     []*model.RunFlag       // 命中记录（已按事件、检测器汇总）
+	Abort     *model.RunFlag         // 触发终止的命中（Flags 中的一项），未触发时为 nil
+	Originals []*model.EventOriginal // 被掩码事件的原文（启用 RetainOriginals 时）
+}
+
+// Apply 扫描并就地掩码事件的载荷与原始输出，返回命中记录
+func (p *Processor) Apply(runID string, events []*model.Event) *Result {
+	result := &Result{}
+	now := time.Now()
+	for _, e := range events {
+		counts := make(map[*Detector]int)
+		original := &model.EventOriginal{RunID: runID, Seq: e.Seq, Payload: e.Payload, Raw: e.Raw, CreatedAt: now}
+		if len(e.Payload) > 0 {
+			var value interface{}
+			if json.Unmarshal(e.Payload, &value) == nil {
+				if masked, changed := p.maskValue(value, counts); changed {
+					if data, err := json.Marshal(masked); err == nil {
+						e.Payload = data
+					}
+				}
+			}
+		}
+		if e.Raw != nil {
+			raw := p.maskString(*e.Raw, counts)
+			e.Raw = &raw
+		}
+
+		for _, d := range p.detectors {
+			n := counts[d]
+			if n == 0 {
+				continue
+			}
+			e.Redacted = true
+			flag := &model.RunFlag{
+				RunID:     runID,
+				EventSeq:  e.Seq,
+				Detector:  d.Name,
+				Severity:  d.Severity,
+				Count:     n,
+				CreatedAt: now,
+			}
+			if result.Abort == nil && p.abortSeverity != "" && d.Severity.Rank() >= p.abortSeverity.Rank() {
+				flag.Aborted = true
+				result.Abort = flag
+			}
+			result.Flags = append(result.Flags, flag)
+		}
+		if e.Redacted && p.retainOriginals {
+			result.Originals = append(result.Originals, original)
+		}
+	}
+	p.record(result.Flags)
+	return result
+}
+
+// record 累计命中统计
+func (p *Processor) record(flags []*model.RunFlag) {
+	if len(flags) == 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	last := -1
+	for _, f := range flags {
+		p.stats.Hits[f.Detector] += int64(f.Count)
+		if f.EventSeq != last {
+			p.stats.RedactedEvents++
+			last = f.EventSeq
+		}
+	}
+}
+
+// Stats 返回命中统计的副本
+func (p *Processor) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	hits := make(map[string]int64, len(p.stats.Hits))
+	for name, n := range p.stats.Hits {
+		hits[name] = n
+	}
+	return Stats{Hits: hits, RedactedEvents: p.stats.RedactedEvents}
+}
+
+// maskString 依次应用全部检测器
+func (p *Processor) maskString(s string, counts map[*Detector]int) string {
+	for _, d := range p.detectors {
+		var n int
+		s, n = d.mask(s)
+		counts[d] += n
+	}
+	return s
+}
+
+// maskValue 递归掩码 JSON 值中的字符串（对象键不处理），返回是否有替换
+func (p *Processor) maskValue(v interface{}, counts map[*Detector]int) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		masked := p.maskString(val, counts)
+		return masked, masked != val
+	case map[string]interface{}:
+		changed := false
+		for k, item := range val {
+			if masked, ok := p.maskValue(item, counts); ok {
+				val[k] = masked
+				changed = true
+			}
+		}
+		return val, changed
+	case []interface{}:
+		changed := false
+		for i, item := range val {
+			if masked, ok := p.maskValue(item, counts); ok {
+				val[i] = masked
+				changed = true
+			}
+		}
+		return val, changed
+	}
+	return v, false
+}
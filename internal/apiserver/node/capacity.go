@@ -0,0 +1,160 @@
+// Package node 节点并发配置覆盖
+//
+// 节点在 nodemanager.yaml 的 node.concurrency 中配置最大并发数、为 high 优先级 Run 预留的槽位与
+// 各 Agent 类型的并发上限。控制面通过 PATCH /api/v1/nodes/{id}/capacity 覆盖其中的字段，
+// 覆盖保存在节点记录中（心跳不覆盖），随每次心跳响应下发，节点合并本地配置后生效并在下一次心跳中上报。
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+
+	"agents-admin/internal/shared/model"
+)
+
+// CapacityResponse 节点并发配置查询结果
+type CapacityResponse struct {
+	NodeID   string                         `json:"node_id"`
+	Override *model.NodeConcurrencyOverride `json:"override"`           // 控制面覆盖（未设置时为 null）
+	Reported *model.NodeConcurrency         `json:"reported,omitempty"` // 节点最近一次心跳上报的生效配置
+}
+
+// capacityOverrideFor 心跳响应中下发的覆盖（未设置时为空对象，读取失败时为 nil，节点保持当前配置）
+func (h *Handler) capacityOverrideFor(ctx context.Context, nodeID string) *model.NodeConcurrencyOverride {
+	o, err := h.store.GetNodeCapacityOverride(ctx, nodeID)
+	if err != nil {
+		slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to get capacity override", "node", nodeID, "err", err)
+		return nil
+	}
+	if o == nil {
+		o = &model.NodeConcurrencyOverride{}
+	}
+	return o
+}
+
+// GetCapacity 查询节点并发配置
+// GET /api/v1/nodes/{id}/capacity
+func (h *Handler) GetCapacity(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.loadNode(w, r)
+	if !ok {
+		return
+	}
+	o, err := h.store.GetNodeCapacityOverride(r.Context(), n.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get capacity override")
+		return
+	}
+	writeJSON(w, http.StatusOK, CapacityResponse{NodeID: n.ID, Override: o, Reported: GetNodeConcurrency(n)})
+}
+
+// UpdateCapacity 覆盖节点并发配置（JSON Merge Patch：字段为 null 时恢复为节点本地配置）
+// PATCH /api/v1/nodes/{id}/capacity
+func (h *Handler) UpdateCapacity(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.loadNode(w, r)
+	if !ok {
+		return
+	}
+	var patch map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	o, err := h.store.GetNodeCapacityOverride(r.Context(), n.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get capacity override")
+		return
+	}
+	// 在副本上应用 patch，校验失败时不影响已保存的覆盖
+	next := &model.NodeConcurrencyOverride{}
+	if o != nil {
+		*next = *o
+		next.AgentTypeLimits = maps.Clone(o.AgentTypeLimits)
+	}
+	o = next
+	if err := applyCapacityPatch(o, patch); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.store.SetNodeCapacityOverride(r.Context(), n.ID, o); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update capacity override")
+		return
+	}
+	slog.InfoContext(r.Context(), "node.capacity.updated", "node_id", n.ID)
+	if o.IsEmpty() {
+		o = nil
+	}
+	writeJSON(w, http.StatusOK, CapacityResponse{NodeID: n.ID, Override: o, Reported: GetNodeConcurrency(n)})
+}
+
+// applyCapacityPatch 将 merge patch 应用到覆盖上并校验
+func applyCapacityPatch(o *model.NodeConcurrencyOverride, patch map[string]json.RawMessage) error {
+	for key, raw := range patch {
+		isNull := string(raw) == "null"
+		switch key {
+		case "max_concurrent":
+			o.MaxConcurrent = nil
+			if !isNull {
+				var v int
+				if err := json.Unmarshal(raw, &v); err != nil || v < 1 {
+					return fmt.Errorf("max_concurrent must be a positive integer")
+				}
+				o.MaxConcurrent = &v
+			}
+		case "reserved_high_priority":
+			o.ReservedHighPriority = nil
+			if !isNull {
+				var v int
+				if err := json.Unmarshal(raw, &v); err != nil || v < 0 {
+					return fmt.Errorf("reserved_high_priority must be a non-negative integer")
+				}
+				o.ReservedHighPriority = &v
+			}
+		case "agent_type_limits":
+			if isNull {
+				o.AgentTypeLimits = nil
+				continue
+			}
+			var limits map[string]*int
+			if err := json.Unmarshal(raw, &limits); err != nil {
+				return fmt.Errorf("agent_type_limits must be an object")
+			}
+			for agentType, v := range limits {
+				if v == nil {
+					delete(o.AgentTypeLimits, agentType)
+					continue
+				}
+				if agentType == "" || *v < 1 {
+					return fmt.Errorf("agent_type_limits.%s must be a positive integer", agentType)
+				}
+				if o.AgentTypeLimits == nil {
+					o.AgentTypeLimits = make(map[string]int)
+				}
+				o.AgentTypeLimits[agentType] = *v
+			}
+		default:
+			return fmt.Errorf("unknown field %q", key)
+		}
+	}
+	if o.MaxConcurrent != nil && o.ReservedHighPriority != nil && *o.ReservedHighPriority >= *o.MaxConcurrent {
+		return fmt.Errorf("reserved_high_priority must be less than max_concurrent")
+	}
+	return nil
+}
+
+// loadNode 按路径参数加载节点，不存在时写入 404
+func (h *Handler) loadNode(w http.ResponseWriter, r *http.Request) (*model.Node, bool) {
+	n, err := h.store.GetNode(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get node")
+		return nil, false
+	}
+	if n == nil {
+		writeError(w, http.StatusNotFound, "node not found")
+		return nil, false
+	}
+	return n, true
+}
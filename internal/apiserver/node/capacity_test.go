@@ -0,0 +1,114 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestHandler_UpdateCapacity(t *testing.T) {
+	store := newMockStore()
+	store.nodes["node-1"] = &model.Node{ID: "node-1", Status: model.NodeStatusOnline}
+	h := NewHandler(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/nodes/node-1/capacity", strings.NewReader(body)))
+		return w
+	}
+
+	if w := patch(`{"max_concurrent":4,"reserved_high_priority":1,"agent_type_limits":{"gemini":1,"qwen-code":2}}`); w.Code != http.StatusOK {
+		t.Fatalf("patch status = %d body=%s", w.Code, w.Body.String())
+	}
+	// merge patch：null 清除字段，agent_type_limits 逐项合并
+	if w := patch(`{"reserved_high_priority":null,"agent_type_limits":{"gemini":null}}`); w.Code != http.StatusOK {
+		t.Fatalf("patch status = %d body=%s", w.Code, w.Body.String())
+	}
+	o := store.overrides["node-1"]
+	if o == nil || o.MaxConcurrent == nil || *o.MaxConcurrent != 4 || o.ReservedHighPriority != nil {
+		t.Fatalf("override = %+v", o)
+	}
+	if len(o.AgentTypeLimits) != 1 || o.AgentTypeLimits["qwen-code"] != 2 {
+		t.Errorf("agent type limits = %v", o.AgentTypeLimits)
+	}
+
+	for _, body := range []string{
+		`{"max_concurrent":0}`,
+		`{"reserved_high_priority":4}`,
+		`{"agent_type_limits":{"gemini":0}}`,
+		`{"unknown":1}`,
+		`not json`,
+	} {
+		if w := patch(body); w.Code != http.StatusBadRequest {
+			t.Errorf("patch %s status = %d, want 400", body, w.Code)
+		}
+	}
+
+	if w := patch(`{"max_concurrent":null,"agent_type_limits":null}`); w.Code != http.StatusOK {
+		t.Fatalf("clear status = %d", w.Code)
+	}
+	if _, ok := store.overrides["node-1"]; ok {
+		t.Error("clearing all fields should remove the override")
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/nodes/missing/capacity", strings.NewReader(`{}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing node status = %d", w.Code)
+	}
+}
+
+func TestHandler_HeartbeatCapacityOverride(t *testing.T) {
+	store := newMockStore()
+	h := NewHandler(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	heartbeat := func() HeartbeatResponse {
+		body, _ := json.Marshal(map[string]interface{}{
+			"node_id":  "node-1",
+			"status":   "online",
+			"capacity": map[string]interface{}{"max_concurrent": 3, "reserved_high_priority": 1},
+		})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("heartbeat status = %d", w.Code)
+		}
+		var resp HeartbeatResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		return resp
+	}
+
+	if resp := heartbeat(); resp.CapacityOverride == nil || !resp.CapacityOverride.IsEmpty() {
+		t.Errorf("override without patch = %+v, want empty object", resp.CapacityOverride)
+	}
+
+	limit := 5
+	store.overrides["node-1"] = &model.NodeConcurrencyOverride{MaxConcurrent: &limit}
+	if resp := heartbeat(); resp.CapacityOverride == nil || *resp.CapacityOverride.MaxConcurrent != 5 {
+		t.Errorf("override = %+v", resp.CapacityOverride)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/nodes/node-1/capacity", nil))
+	var got CapacityResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Reported == nil || got.Reported.MaxConcurrent != 3 || got.Reported.ReservedHighPriority != 1 {
+		t.Errorf("reported = %+v", got.Reported)
+	}
+	if got.Override == nil || *got.Override.MaxConcurrent != 5 {
+		t.Errorf("override = %+v", got.Override)
+	}
+}
@@ -4,13 +4,16 @@ package node
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"time"
 
 	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/apiserver/hitl"
+	"agents-admin/internal/shared/cache"
 	"agents-admin/internal/shared/model"
 )
 
@@ -18,39 +21,90 @@ import (
 type Handler struct {
 	store       NodePersistentStore
 	provisioner *Provisioner
+	runObserver RunObserver            // 心跳 running_runs 比对（可选，nil 时不做孤儿 Run 检测）
+	occupancy   *OccupancyTracker      // 节点执行槽位占用
+	join        joinState              // 节点自动注册（加入令牌与专属凭证）
+	metrics     cache.NodeMetricsCache // 节点指标滚动窗口（可选，nil 时只保留心跳中的最新采样）
+	heartbeats  *heartbeatTracker      // v2 心跳最近写入的内容哈希
+}
+
+// RunObserver 接收节点心跳上报的 running_runs，用于检测节点丢失执行的孤儿 Run
+//
+// activeRuns 为 DB 中分配给该节点的 assigned/running Run（心跳处理时已查询，避免重复查询）。
+type RunObserver interface {
+	ObserveRunningRuns(ctx context.Context, nodeID string, runningRuns []string, activeRuns []*model.Run)
 }
 
 // NodePersistentStore 节点处理器所需的持久化存储接口
 type NodePersistentStore interface {
 	UpsertNode(ctx context.Context, node *model.Node) error
 	UpsertNodeHeartbeat(ctx context.Context, node *model.Node) error // 心跳专用，不覆盖 status
+	TouchNodeHeartbeat(ctx context.Context, nodeID string, at time.Time) error
 	GetNode(ctx context.Context, id string) (*model.Node, error)
 	ListAllNodes(ctx context.Context) ([]*model.Node, error)
 	ListOnlineNodes(ctx context.Context) ([]*model.Node, error)
 	DeactivateStaleNodes(ctx context.Context, activeNodeID string, hostname string) error
 	DeleteNode(ctx context.Context, id string) error
+	GetNodeCapacityOverride(ctx context.Context, nodeID string) (*model.NodeConcurrencyOverride, error)
+	SetNodeCapacityOverride(ctx context.Context, nodeID string, o *model.NodeConcurrencyOverride) error
+	SetNodeTaints(ctx context.Context, nodeID string, taints []model.NodeTaint) error
 	ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error)
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	CountEventsByRun(ctx context.Context, runID string) (int, error)
+	MaxEventSeqs(ctx context.Context, runID string) (seq, nodeSeq int, err error)
 	CreateNodeProvision(ctx context.Context, p *model.NodeProvision) error
 	UpdateNodeProvision(ctx context.Context, p *model.NodeProvision) error
 	GetNodeProvision(ctx context.Context, id string) (*model.NodeProvision, error)
 	ListNodeProvisions(ctx context.Context) ([]*model.NodeProvision, error)
+	CreateNodeJoinToken(ctx context.Context, t *model.NodeJoinToken) error
+	ListNodeJoinTokens(ctx context.Context) ([]*model.NodeJoinToken, error)
+	DeleteNodeJoinToken(ctx context.Context, id string) error
+	ConsumeNodeJoinToken(ctx context.Context, tokenHash, nodeID string, now time.Time) (*model.NodeJoinToken, error)
+	CreateNodeJoinTokenUse(ctx context.Context, u *model.NodeJoinTokenUse) error
+	ListNodeJoinTokenUses(ctx context.Context, tokenID string) ([]*model.NodeJoinTokenUse, error)
+	UpsertNodeCredential(ctx context.Context, c *model.NodeCredential) error
+	GetNodeCredential(ctx context.Context, nodeID string) (*model.NodeCredential, error)
+	GetNodeCredentialByTokenHash(ctx context.Context, tokenHash string) (*model.NodeCredential, error)
+	DeleteNodeCredential(ctx context.Context, nodeID string) error
+	GetApprovalRequest(ctx context.Context, id string) (*model.ApprovalRequest, error)
+	CreateApprovalRequest(ctx context.Context, req *model.ApprovalRequest) error
+	UpdateApprovalRequestStatus(ctx context.Context, id string, status model.ApprovalStatus) error
+	ListFeedbacks(ctx context.Context, runID string) ([]*model.HumanFeedback, error)
+	MarkFeedbackProcessed(ctx context.Context, id string) error
 }
 
 // NewHandler 创建节点处理器
 func NewHandler(store NodePersistentStore) *Handler {
-	h := &Handler{store: store}
+	h := &Handler{store: store, occupancy: NewOccupancyTracker(), heartbeats: newHeartbeatTracker()}
+	h.SetJoinConfig(JoinConfig{})
 	h.provisioner = NewProvisioner(store, store)
 	return h
 }
 
+// SetRunObserver 设置心跳 running_runs 的观察者（如 Run 孤儿回收）
+func (h *Handler) SetRunObserver(o RunObserver) {
+	h.runObserver = o
+}
+
 // RegisterRoutes 注册节点相关路由
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/nodes", h.List)
+	mux.HandleFunc("GET /api/v1/nodes/occupancy", h.GetOccupancy)
+	mux.HandleFunc("GET /api/v1/nodes/occupancy/stream", h.StreamOccupancy)
+	mux.HandleFunc("POST /api/v1/nodes/join-tokens", h.CreateJoinToken)
+	mux.HandleFunc("GET /api/v1/nodes/join-tokens", h.ListJoinTokens)
+	mux.HandleFunc("DELETE /api/v1/nodes/join-tokens/{id}", h.DeleteJoinToken)
+	mux.HandleFunc("GET /api/v1/nodes/join-tokens/{id}/uses", h.ListJoinTokenUses)
+	mux.HandleFunc("POST /api/v1/nodes/join", h.Join)
 	mux.HandleFunc("GET /api/v1/nodes/{id}", h.Get)
 	mux.HandleFunc("DELETE /api/v1/nodes/{id}", h.Delete)
 	mux.HandleFunc("PATCH /api/v1/nodes/{id}", h.Update)
 	mux.HandleFunc("POST /api/v1/nodes/heartbeat", h.Heartbeat)
 	mux.HandleFunc("GET /api/v1/nodes/{id}/runs", h.GetRuns)
+	mux.HandleFunc("GET /api/v1/nodes/{id}/metrics", h.GetMetrics)
+	mux.HandleFunc("GET /api/v1/nodes/{id}/capacity", h.GetCapacity)
+	mux.HandleFunc("PATCH /api/v1/nodes/{id}/capacity", h.UpdateCapacity)
+	mux.HandleFunc("PATCH /api/v1/nodes/{id}/taints", h.UpdateTaints)
 	mux.HandleFunc("GET /api/v1/nodes/{id}/env-config", h.GetEnvConfig)
 	mux.HandleFunc("PUT /api/v1/nodes/{id}/env-config", h.UpdateEnvConfig)
 	mux.HandleFunc("POST /api/v1/nodes/{id}/env-config/test-proxy", h.TestProxy)
@@ -82,6 +136,7 @@ type Response struct {
 	IPs           string                 `json:"ips,omitempty"`
 	Labels        map[string]string      `json:"labels,omitempty"`
 	Capacity      map[string]interface{} `json:"capacity,omitempty"`
+	Taints        []model.NodeTaint      `json:"taints,omitempty"`
 	LastHeartbeat *time.Time             `json:"last_heartbeat,omitempty"`
 	CreatedAt     time.Time              `json:"created_at"`
 	UpdatedAt     time.Time              `json:"updated_at"`
@@ -91,48 +146,171 @@ type Response struct {
 // HTTP 处理函数
 // ============================================================================
 
-// heartbeatRequestExt 扩展心跳请求（兼容 OpenAPI HeartbeatRequest + HTTP-Only 扩展字段）
-type heartbeatRequestExt struct {
+// HeartbeatRequestExt 扩展心跳请求（兼容 OpenAPI HeartbeatRequest + HTTP-Only 扩展字段）
+type HeartbeatRequestExt struct {
 	HeartbeatRequest
-	RunningRuns []string `json:"running_runs,omitempty"` // Node Manager 当前正在执行的 Run ID 列表
-	Hostname    string   `json:"hostname,omitempty"`     // 主机名
-	IPs         string   `json:"ips,omitempty"`          // IP 地址列表（逗号分隔）
+	RunningRuns []string `json:"running_runs,omitempty"`      // Node Manager 当前正在执行的 Run ID 列表
+	PausedRuns  []string `json:"paused_runs,omitempty"`       // 其中已被用户暂停（执行目标已冻结）的 Run ID 列表
+	Approvals   []string `json:"pending_approvals,omitempty"` // Node Manager 暂停执行等待中的审批 ID 列表
+	Hostname    string   `json:"hostname,omitempty"`          // 主机名
+	IPs         string   `json:"ips,omitempty"`               // IP 地址列表（逗号分隔）
+
+	// 心跳协议 v2（见 heartbeat_v2.go）
+	ProtocolVersion int                `json:"protocol_version,omitempty"` // 心跳协议版本（旧版本节点不上报，按 v1 处理）
+	ContentHash     string             `json:"content_hash,omitempty"`     // 主机名、IP、标签与容量（不含 available、metrics）的内容哈希
+	IntervalSeconds int                `json:"interval_seconds,omitempty"` // 节点当前的心跳间隔（秒）
+	Metrics         *model.NodeMetrics `json:"metrics,omitempty"`          // 增量心跳的资源指标采样（完整心跳在 capacity.metrics 中上报）
 }
 
 // HeartbeatResponse 心跳响应（HTTP-Only 架构：携带控制指令）
 type HeartbeatResponse struct {
-	Status     string               `json:"status"`
-	Directives *HeartbeatDirectives `json:"directives,omitempty"`
+	Status           string                         `json:"status"`
+	Directives       *HeartbeatDirectives           `json:"directives,omitempty"`
+	CapacityOverride *model.NodeConcurrencyOverride `json:"capacity_override,omitempty"` // 控制面的并发配置覆盖（未设置时为空对象）
+
+	// 以下字段仅响应 v2 心跳
+	ProtocolVersion int  `json:"protocol_version,omitempty"` // 服务端支持的心跳协议版本
+	IntervalSeconds int  `json:"interval_seconds,omitempty"` // 期望的下次心跳间隔（秒）
+	Resync          bool `json:"resync,omitempty"`           // 服务端不认识增量心跳的内容哈希，下次心跳需携带完整内容
 }
 
 // HeartbeatDirectives 心跳响应中的控制指令
 type HeartbeatDirectives struct {
-	CancelRuns []string `json:"cancel_runs,omitempty"` // 需要取消的 Run ID 列表
+	CancelRuns  []string                `json:"cancel_runs,omitempty"`  // 需要取消的 Run ID 列表
+	TimeoutRuns []string                `json:"timeout_runs,omitempty"` // 已被超时巡检判定为 timeout、需要终止执行的 Run ID 列表
+	PauseRuns   []string                `json:"pause_runs,omitempty"`   // DB 中为 paused、节点尚未暂停的 Run ID 列表
+	ResumeRuns  []string                `json:"resume_runs,omitempty"`  // 节点已暂停、DB 中已恢复为 running 的 Run ID 列表
+	Approvals   []hitl.ApprovalOutcome  `json:"approvals,omitempty"`    // 节点等待中、已处理（批准/拒绝/过期）的审批结果
+	Feedbacks   []hitl.FeedbackDelivery `json:"feedbacks,omitempty"`    // 节点正在执行的 Run 中尚未被 Agent 读取的人工反馈
 }
 
+// ErrNodeIDRequired 心跳请求缺少 node_id
+var ErrNodeIDRequired = errors.New("node_id is required")
+
 // Heartbeat 处理节点心跳
 // POST /api/v1/nodes/heartbeat
 func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
-	var req heartbeatRequestExt
+	var req HeartbeatRequestExt
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		log.Printf("[node.heartbeat] ERROR: invalid request body: %v", err)
+		slog.ErrorContext(r.Context(), "node.heartbeat", "detail", "invalid request body", "error", err)
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	if req.NodeId == "" {
-		log.Printf("[node.heartbeat] ERROR: node_id is required")
-		writeError(w, http.StatusBadRequest, "node_id is required")
+	resp, err := h.ProcessHeartbeat(r.Context(), &req)
+	if errors.Is(err, ErrNodeIDRequired) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update node")
 		return
 	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// ProcessHeartbeat 处理节点心跳并构建控制指令（REST 与 gRPC 心跳共用）
+//
+// 返回 ErrNodeIDRequired 表示请求无效，其他错误为存储失败。
+func (h *Handler) ProcessHeartbeat(ctx context.Context, req *HeartbeatRequestExt) (*HeartbeatResponse, error) {
+	if req.NodeId == "" {
+		slog.WarnContext(ctx, "node.heartbeat", "detail", "node_id is required")
+		return nil, ErrNodeIDRequired
+	}
 
 	now := time.Now()
+	resp := HeartbeatResponse{Status: "ok"}
 
-	labels := []byte("{}")
-	capacity := []byte("{}")
+	// 1. 写入节点记录（v2 增量心跳内容未变化时只刷新心跳时间）
+	var node *model.Node
+	if req.isDelta() {
+		node = h.processDeltaHeartbeat(ctx, req, now, &resp)
+	} else {
+		var err error
+		if node, err = h.upsertHeartbeat(ctx, req, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// 2. 构建控制指令（HTTP-Only 架构：声明式状态协调）
+	resp.CapacityOverride = h.capacityOverrideFor(ctx, req.NodeId)
+
+	// 未上报 running_runs 字段（旧版本 Node Manager）时为 nil，不做比对
+	if req.RunningRuns != nil {
+		activeRuns, err := h.store.ListRunsByNode(ctx, req.NodeId)
+		if err != nil {
+			slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to list active runs", "error", err)
+		} else {
+			cancelRuns := computeCancelDirectives(req.RunningRuns, activeRuns)
+			if len(cancelRuns) > 0 {
+				resp.Directives = h.splitTimeoutRuns(ctx, cancelRuns)
+				slog.InfoContext(ctx, "node.heartbeat.directives", "node_id", req.NodeId,
+					"cancel_runs", resp.Directives.CancelRuns, "timeout_runs", resp.Directives.TimeoutRuns)
+			}
+
+			// 暂停 / 恢复：比对 DB 中的 paused 状态与节点上报的 paused_runs
+			if pauseRuns, resumeRuns := computePauseDirectives(req.RunningRuns, req.PausedRuns, activeRuns); len(pauseRuns)+len(resumeRuns) > 0 {
+				if resp.Directives == nil {
+					resp.Directives = &HeartbeatDirectives{}
+				}
+				resp.Directives.PauseRuns, resp.Directives.ResumeRuns = pauseRuns, resumeRuns
+				slog.InfoContext(ctx, "node.heartbeat.directives", "node_id", req.NodeId,
+					"pause_runs", pauseRuns, "resume_runs", resumeRuns)
+			}
+
+			// 3. 更新槽位占用
+			h.occupancy.Update(req.NodeId, GetNodeMaxConcurrent(node), req.RunningRuns, activeRuns, now)
+
+			// 4. 比对 DB 中的 running Run 是否仍在节点上执行
+			if h.runObserver != nil {
+				h.runObserver.ObserveRunningRuns(ctx, req.NodeId, req.RunningRuns, activeRuns)
+			}
+		}
+	}
+
+	// 5. 节点等待中的审批：下发已处理的结果
+	if len(req.Approvals) > 0 {
+		if outcomes := hitl.ApprovalOutcomes(ctx, h.store, req.Approvals, now); len(outcomes) > 0 {
+			if resp.Directives == nil {
+				resp.Directives = &HeartbeatDirectives{}
+			}
+			resp.Directives.Approvals = outcomes
+			slog.InfoContext(ctx, "node.heartbeat.directives", "node_id", req.NodeId, "approvals", outcomes)
+		}
+	}
+
+	// 6. 节点正在执行的 Run：投递尚未被 Agent 读取的人工反馈
+	if len(req.RunningRuns) > 0 {
+		if feedbacks := hitl.PendingFeedbacks(ctx, h.store, req.RunningRuns); len(feedbacks) > 0 {
+			if resp.Directives == nil {
+				resp.Directives = &HeartbeatDirectives{}
+			}
+			resp.Directives.Feedbacks = feedbacks
+			slog.InfoContext(ctx, "node.heartbeat.directives", "node_id", req.NodeId, "feedbacks", len(feedbacks))
+		}
+	}
+
+	// 7. v2：下发下次心跳间隔
+	if req.ProtocolVersion >= HeartbeatProtocolVersion {
+		resp.ProtocolVersion = HeartbeatProtocolVersion
+		resp.IntervalSeconds = nextHeartbeatInterval(req, &resp)
+	}
+
+	return &resp, nil
+}
+
+// upsertHeartbeat 以心跳上报的完整内容写入节点记录（v1 心跳与 v2 完整心跳）
+func (h *Handler) upsertHeartbeat(ctx context.Context, req *HeartbeatRequestExt, now time.Time) (*model.Node, error) {
+	// 通过加入令牌注册的节点继承令牌预设的标签（覆盖节点上报的同名标签）
+	var reported map[string]string
 	if req.Labels != nil {
-		labels, _ = json.Marshal(*req.Labels)
+		reported = *req.Labels
+	}
+	labels, _ := json.Marshal(h.applyEnrolledLabels(ctx, req.NodeId, reported))
+	if string(labels) == "null" {
+		labels = []byte("{}")
 	}
+	capacity := []byte("{}")
 	if req.Capacity != nil {
 		capacity, _ = json.Marshal(*req.Capacity)
 	}
@@ -142,9 +320,9 @@ func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 		status = *req.Status
 	}
 
-	log.Printf("[node.heartbeat] Received from node=%s, status=%s", req.NodeId, status)
+	slog.DebugContext(ctx, "node.heartbeat.received", "node_id", req.NodeId, "status", status)
 
-	// 1. 先写 PostgreSQL（持久化优先，使用心跳专用 upsert 不覆盖行政状态）
+	// 先写 PostgreSQL（持久化优先，使用心跳专用 upsert 不覆盖行政状态）
 	node := &model.Node{
 		ID:            req.NodeId,
 		Status:        model.NodeStatus(status),
@@ -157,43 +335,30 @@ func (h *Handler) Heartbeat(w http.ResponseWriter, r *http.Request) {
 		UpdatedAt:     now,
 	}
 
-	if err := h.store.UpsertNodeHeartbeat(r.Context(), node); err != nil {
-		log.Printf("[node.heartbeat] ERROR: failed to update mongodb: %v", err)
-		writeError(w, http.StatusInternalServerError, "failed to update node")
-		return
+	if err := h.store.UpsertNodeHeartbeat(ctx, node); err != nil {
+		slog.ErrorContext(ctx, "node.heartbeat", "detail", "failed to update mongodb", "error", err)
+		return nil, err
 	}
 
-	// 2. Hostname 去重：同一 hostname 不同 ID 的旧记录标记为 offline
-	if req.Hostname != "" {
-		if err := h.store.DeactivateStaleNodes(r.Context(), req.NodeId, req.Hostname); err != nil {
-			log.Printf("[node.heartbeat] WARNING: failed to deactivate stale nodes: %v", err)
-		}
+	h.recordMetrics(ctx, node.ID, GetNodeMetrics(node))
+	if req.ProtocolVersion >= HeartbeatProtocolVersion && req.ContentHash != "" {
+		h.heartbeats.remember(node, req.ContentHash, now)
 	}
 
-	// 3. 构建控制指令（HTTP-Only 架构：声明式状态协调）
-	resp := HeartbeatResponse{Status: "ok"}
-
-	if len(req.RunningRuns) > 0 {
-		cancelRuns := h.computeCancelDirectives(r.Context(), req.NodeId, req.RunningRuns)
-		if len(cancelRuns) > 0 {
-			resp.Directives = &HeartbeatDirectives{CancelRuns: cancelRuns}
-			log.Printf("[node.heartbeat] Directives for node=%s: cancel_runs=%v", req.NodeId, cancelRuns)
+	// Hostname 去重：同一 hostname 不同 ID 的旧记录标记为 offline
+	if req.Hostname != "" {
+		if err := h.store.DeactivateStaleNodes(ctx, req.NodeId, req.Hostname); err != nil {
+			slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to deactivate stale nodes", "error", err)
 		}
 	}
 
-	writeJSON(w, http.StatusOK, resp)
+	return node, nil
 }
 
 // computeCancelDirectives 计算取消指令：
 // Node Manager 上报 running_runs，API Server 用 ListRunsByNode 获取 DB 中仍活跃的 runs，
 // 差集即为需要取消的 runs（已被用户/系统取消但 NM 还不知道）。
-func (h *Handler) computeCancelDirectives(ctx context.Context, nodeID string, runningRuns []string) []string {
-	activeRuns, err := h.store.ListRunsByNode(ctx, nodeID)
-	if err != nil {
-		log.Printf("[node.heartbeat] WARNING: failed to list active runs for cancel check: %v", err)
-		return nil
-	}
-
+func computeCancelDirectives(runningRuns []string, activeRuns []*model.Run) []string {
 	activeSet := make(map[string]bool, len(activeRuns))
 	for _, r := range activeRuns {
 		activeSet[r.ID] = true
@@ -208,6 +373,44 @@ func (h *Handler) computeCancelDirectives(ctx context.Context, nodeID string, ru
 	return cancelRuns
 }
 
+// computePauseDirectives 计算暂停 / 恢复指令：
+// DB 中为 paused、节点仍在执行但未暂停的 Run 需要暂停；节点已暂停、DB 中恢复为 running 的 Run 需要恢复。
+// 节点已暂停但 DB 中已不再活跃的 Run 由取消指令终止。
+func computePauseDirectives(runningRuns, pausedRuns []string, activeRuns []*model.Run) (pauseRuns, resumeRuns []string) {
+	running := make(map[string]bool, len(runningRuns))
+	for _, id := range runningRuns {
+		running[id] = true
+	}
+	paused := make(map[string]bool, len(pausedRuns))
+	for _, id := range pausedRuns {
+		paused[id] = true
+	}
+	for _, r := range activeRuns {
+		switch {
+		case r.Status == model.RunStatusPaused && running[r.ID] && !paused[r.ID]:
+			pauseRuns = append(pauseRuns, r.ID)
+		case r.Status == model.RunStatusRunning && paused[r.ID]:
+			resumeRuns = append(resumeRuns, r.ID)
+		}
+	}
+	return pauseRuns, resumeRuns
+}
+
+// splitTimeoutRuns 将已被超时巡检标记为 timeout 的 Run 从取消指令中分出，
+// Node Manager 据此终止执行并以 timeout（而非 cancelled）上报终态
+func (h *Handler) splitTimeoutRuns(ctx context.Context, cancelRuns []string) *HeartbeatDirectives {
+	directives := &HeartbeatDirectives{}
+	for _, runID := range cancelRuns {
+		run, err := h.store.GetRun(ctx, runID)
+		if err == nil && run != nil && run.Status == model.RunStatusTimeout {
+			directives.TimeoutRuns = append(directives.TimeoutRuns, runID)
+			continue
+		}
+		directives.CancelRuns = append(directives.CancelRuns, runID)
+	}
+	return directives
+}
+
 // List 列出所有节点
 // GET /api/v1/nodes
 //
@@ -218,7 +421,7 @@ func (h *Handler) computeCancelDirectives(ctx context.Context, nodeID string, ru
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	nodes, err := h.store.ListAllNodes(r.Context())
 	if err != nil {
-		log.Printf("[node] ERROR: failed to list nodes: %v", err)
+		slog.ErrorContext(r.Context(), "node", "detail", "failed to list nodes", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list nodes")
 		return
 	}
@@ -247,16 +450,48 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, h.buildNodeResponse(n))
 }
 
+// AssignedRun 分配给节点的 Run，附带已有事件序号
+type AssignedRun struct {
+	*model.Run
+	EventSeq int `json:"event_seq,omitempty"` // 已入库事件的最大节点序号，Node Manager 从其后继续编号（被回收后重新分配的 Run）
+}
+
 // GetRuns 获取分配给节点的 Runs
 // GET /api/v1/nodes/{id}/runs
 func (h *Handler) GetRuns(w http.ResponseWriter, r *http.Request) {
-	nodeID := r.PathValue("id")
-	runs, err := h.store.ListRunsByNode(r.Context(), nodeID)
+	result, err := h.AssignedRuns(r.Context(), r.PathValue("id"))
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to list runs")
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{"runs": runs, "count": len(runs)})
+	writeJSON(w, http.StatusOK, map[string]interface{}{"runs": result, "count": len(result)})
+}
+
+// AssignedRuns 列出分配给节点的活跃 Run（REST 轮询与 gRPC 订阅共用）
+//
+// 待领取 Run 的快照按当前格式下发。
+func (h *Handler) AssignedRuns(ctx context.Context, nodeID string) ([]AssignedRun, error) {
+	runs, err := h.store.ListRunsByNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AssignedRun, 0, len(runs))
+	for _, run := range runs {
+		item := AssignedRun{Run: run}
+		// 只有待领取的 Run 需要序号起点；节点序号在 Run 内用于去重，重新分配的 Run 不能从 1 开始
+		if run.Status == model.RunStatusAssigned {
+			if _, n, err := h.store.MaxEventSeqs(ctx, run.ID); err == nil {
+				item.EventSeq = n
+			}
+			// 旧版本格式的快照升级为当前格式后下发（见 model.UpgradeSnapshot），不合法时原样下发由节点报错
+			if snapshot, err := model.NormalizeSnapshot(run.Snapshot); err == nil {
+				run.Snapshot = snapshot
+			}
+		}
+		result = append(result, item)
+	}
+	return result, nil
 }
 
 // Delete 删除节点
@@ -288,6 +523,13 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete node")
 		return
 	}
+	// 删除节点同时撤销其专属凭证，被删除的节点需重新加入
+	if err := h.store.DeleteNodeCredential(r.Context(), id); err != nil {
+		slog.ErrorContext(r.Context(), "node.delete.credential.error", "node_id", id, "err", err)
+	}
+	h.join.forget(id)
+	h.occupancy.Remove(id)
+	h.heartbeats.forget(id)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -413,7 +655,7 @@ func (h *Handler) UpdateEnvConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("[node] Node %s env config updated", id)
+	slog.InfoContext(ctx, "node.env_config.updated", "node_id", id)
 	writeJSON(w, http.StatusOK, envConfig)
 }
 
@@ -454,7 +696,7 @@ func (h *Handler) TestProxy(w http.ResponseWriter, r *http.Request) {
 	addr := net.JoinHostPort(proxy.Host, fmt.Sprintf("%d", proxy.Port))
 	conn, err := net.DialTimeout("tcp", addr, 5*time.Second)
 	if err != nil {
-		log.Printf("[node] Proxy test failed for node %s: %v", id, err)
+		slog.WarnContext(r.Context(), "node.proxy_test.failed", "node_id", id, "error", err)
 		writeJSON(w, http.StatusOK, map[string]interface{}{
 			"success": false,
 			"message": fmt.Sprintf("connection failed: %v", err),
@@ -463,7 +705,7 @@ func (h *Handler) TestProxy(w http.ResponseWriter, r *http.Request) {
 	}
 	conn.Close()
 
-	log.Printf("[node] Proxy test success for node %s", id)
+	slog.InfoContext(r.Context(), "node.proxy_test.success", "node_id", id)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"success": true,
 		"message": "proxy is reachable",
@@ -485,6 +727,7 @@ func (h *Handler) buildNodeResponse(n *model.Node) Response {
 		IPs:           n.IPs,
 		Labels:        labels,
 		Capacity:      rs.Capacity,
+		Taints:        n.Taints,
 		LastHeartbeat: rs.LastHeartbeat,
 		CreatedAt:     n.CreatedAt,
 		UpdatedAt:     n.UpdatedAt,
@@ -522,7 +765,7 @@ func (h *Handler) Provision(w http.ResponseWriter, r *http.Request) {
 
 	prov, err := h.provisioner.StartProvision(r.Context(), req)
 	if err != nil {
-		log.Printf("[node.provision] ERROR: %v", err)
+		slog.ErrorContext(r.Context(), "node.provision", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to start provision")
 		return
 	}
@@ -535,7 +778,7 @@ func (h *Handler) Provision(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) ListProvisions(w http.ResponseWriter, r *http.Request) {
 	provisions, err := h.store.ListNodeProvisions(r.Context())
 	if err != nil {
-		log.Printf("[node.provisions] ERROR: %v", err)
+		slog.ErrorContext(r.Context(), "node.provisions", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list provisions")
 		return
 	}
@@ -554,7 +797,7 @@ func (h *Handler) GetProvision(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	prov, err := h.store.GetNodeProvision(r.Context(), id)
 	if err != nil {
-		log.Printf("[node.provision] ERROR: %v", err)
+		slog.ErrorContext(r.Context(), "node.provision", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get provision")
 		return
 	}
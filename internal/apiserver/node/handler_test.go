@@ -15,14 +15,32 @@ import (
 
 // mockStore 模拟存储层
 type mockStore struct {
-	nodes map[string]*model.Node
-	runs  map[string][]*model.Run
+	nodes    map[string]*model.Node
+	runs     map[string][]*model.Run
+	runsByID map[string]*model.Run
+	events   map[string]int // key: runID，已有事件数
+
+	joinTokens  map[string]*model.NodeJoinToken // key: token hash
+	joinUses    []*model.NodeJoinTokenUse
+	credentials map[string]*model.NodeCredential // key: nodeID
+	approvals   map[string]*model.ApprovalRequest
+	feedbacks   map[string][]*model.HumanFeedback // key: runID
+	overrides   map[string]*model.NodeConcurrencyOverride
+
+	upserts int // UpsertNodeHeartbeat 调用次数
+	touches int // TouchNodeHeartbeat 调用次数
 }
 
 func newMockStore() *mockStore {
 	return &mockStore{
-		nodes: make(map[string]*model.Node),
-		runs:  make(map[string][]*model.Run),
+		nodes:       make(map[string]*model.Node),
+		runs:        make(map[string][]*model.Run),
+		runsByID:    make(map[string]*model.Run),
+		joinTokens:  make(map[string]*model.NodeJoinToken),
+		credentials: make(map[string]*model.NodeCredential),
+		approvals:   make(map[string]*model.ApprovalRequest),
+		feedbacks:   make(map[string][]*model.HumanFeedback),
+		overrides:   make(map[string]*model.NodeConcurrencyOverride),
 	}
 }
 
@@ -30,6 +48,26 @@ func (m *mockStore) GetNode(ctx context.Context, id string) (*model.Node, error)
 	return m.nodes[id], nil
 }
 
+func (m *mockStore) GetNodeCapacityOverride(ctx context.Context, nodeID string) (*model.NodeConcurrencyOverride, error) {
+	return m.overrides[nodeID], nil
+}
+
+func (m *mockStore) SetNodeCapacityOverride(ctx context.Context, nodeID string, o *model.NodeConcurrencyOverride) error {
+	if o.IsEmpty() {
+		delete(m.overrides, nodeID)
+	} else {
+		m.overrides[nodeID] = o
+	}
+	return nil
+}
+
+func (m *mockStore) SetNodeTaints(ctx context.Context, nodeID string, taints []model.NodeTaint) error {
+	if n := m.nodes[nodeID]; n != nil {
+		n.Taints = taints
+	}
+	return nil
+}
+
 func (m *mockStore) ListAllNodes(ctx context.Context) ([]*model.Node, error) {
 	nodes := make([]*model.Node, 0, len(m.nodes))
 	for _, n := range m.nodes {
@@ -48,10 +86,19 @@ func (m *mockStore) UpsertNode(ctx context.Context, node *model.Node) error {
 }
 
 func (m *mockStore) UpsertNodeHeartbeat(ctx context.Context, node *model.Node) error {
+	m.upserts++
 	m.nodes[node.ID] = node
 	return nil
 }
 
+func (m *mockStore) TouchNodeHeartbeat(ctx context.Context, nodeID string, at time.Time) error {
+	m.touches++
+	if n := m.nodes[nodeID]; n != nil {
+		n.LastHeartbeat = &at
+	}
+	return nil
+}
+
 func (m *mockStore) DeactivateStaleNodes(ctx context.Context, activeNodeID string, hostname string) error {
 	for id, n := range m.nodes {
 		if id != activeNodeID && n.Hostname == hostname {
@@ -91,7 +138,7 @@ func (m *mockStore) UpdateTaskContext(ctx context.Context, id string, context js
 }
 func (m *mockStore) CreateRun(ctx context.Context, run *model.Run) error { return nil }
 func (m *mockStore) GetRun(ctx context.Context, id string) (*model.Run, error) {
-	return nil, nil
+	return m.runsByID[id], nil
 }
 func (m *mockStore) ListRuns(ctx context.Context, taskID string, limit, offset int) ([]*model.Run, error) {
 	return nil, nil
@@ -115,9 +162,14 @@ func (m *mockStore) GetEvent(ctx context.Context, id string) (*model.Event, erro
 func (m *mockStore) ListEventsByRun(ctx context.Context, runID string) ([]*model.Event, error) {
 	return nil, nil
 }
-func (m *mockStore) CountEventsByRun(ctx context.Context, runID string) (int, error) { return 0, nil }
-func (m *mockStore) DeleteEventsByRun(ctx context.Context, runID string) error       { return nil }
-func (m *mockStore) ListAccounts(ctx context.Context) ([]*model.Account, error)      { return nil, nil }
+func (m *mockStore) CountEventsByRun(ctx context.Context, runID string) (int, error) {
+	return m.events[runID], nil
+}
+func (m *mockStore) MaxEventSeqs(ctx context.Context, runID string) (int, int, error) {
+	return m.events[runID], m.events[runID], nil
+}
+func (m *mockStore) DeleteEventsByRun(ctx context.Context, runID string) error  { return nil }
+func (m *mockStore) ListAccounts(ctx context.Context) ([]*model.Account, error) { return nil, nil }
 func (m *mockStore) GetAccount(ctx context.Context, id string) (*model.Account, error) {
 	return nil, nil
 }
@@ -163,9 +215,18 @@ func (m *mockStore) DeleteAgentTemplate(ctx context.Context, id string) error {
 func (m *mockStore) ListSkills(ctx context.Context, category string) ([]*model.Skill, error) {
 	return nil, nil
 }
-func (m *mockStore) GetSkill(ctx context.Context, id string) (*model.Skill, error)  { return nil, nil }
-func (m *mockStore) CreateSkill(ctx context.Context, skill *model.Skill) error      { return nil }
-func (m *mockStore) DeleteSkill(ctx context.Context, id string) error               { return nil }
+func (m *mockStore) GetSkill(ctx context.Context, id string) (*model.Skill, error) { return nil, nil }
+func (m *mockStore) CreateSkill(ctx context.Context, skill *model.Skill) error     { return nil }
+func (m *mockStore) DeleteSkill(ctx context.Context, id string) error              { return nil }
+func (m *mockStore) CreateSkillBundle(ctx context.Context, bundle *model.SkillBundle) error {
+	return nil
+}
+func (m *mockStore) GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error) {
+	return nil, nil
+}
+func (m *mockStore) ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error) {
+	return nil, nil
+}
 func (m *mockStore) ListMCPServers(ctx context.Context) ([]*model.MCPServer, error) { return nil, nil }
 func (m *mockStore) GetMCPServer(ctx context.Context, id string) (*model.MCPServer, error) {
 	return nil, nil
@@ -188,6 +249,7 @@ func (m *mockStore) CreateProxy(ctx context.Context, proxy *model.Proxy) error
 func (m *mockStore) UpdateProxy(ctx context.Context, proxy *model.Proxy) error         { return nil }
 func (m *mockStore) DeleteProxy(ctx context.Context, id string) error                  { return nil }
 func (m *mockStore) CleanupExpiredTerminalSessions(ctx context.Context) (int64, error) { return 0, nil }
+func (m *mockStore) CloseExpiredTerminalSessions(ctx context.Context) (int64, error)   { return 0, nil }
 func (m *mockStore) ListPendingAgentInstances(ctx context.Context, nodeID string) ([]*model.Instance, error) {
 	return nil, nil
 }
@@ -218,6 +280,86 @@ func (m *mockStore) GetNodeProvision(ctx context.Context, id string) (*model.Nod
 func (m *mockStore) ListNodeProvisions(ctx context.Context) ([]*model.NodeProvision, error) {
 	return nil, nil
 }
+func (m *mockStore) CreateNodeJoinToken(ctx context.Context, t *model.NodeJoinToken) error {
+	m.joinTokens[t.TokenHash] = t
+	return nil
+}
+func (m *mockStore) ListNodeJoinTokens(ctx context.Context) ([]*model.NodeJoinToken, error) {
+	var result []*model.NodeJoinToken
+	for _, t := range m.joinTokens {
+		result = append(result, t)
+	}
+	return result, nil
+}
+func (m *mockStore) DeleteNodeJoinToken(ctx context.Context, id string) error {
+	for hash, t := range m.joinTokens {
+		if t.ID == id {
+			delete(m.joinTokens, hash)
+		}
+	}
+	return nil
+}
+func (m *mockStore) ConsumeNodeJoinToken(ctx context.Context, tokenHash, nodeID string, now time.Time) (*model.NodeJoinToken, error) {
+	t := m.joinTokens[tokenHash]
+	if t == nil || t.UseCount >= t.MaxUses || !t.ExpiresAt.After(now) {
+		return nil, nil
+	}
+	t.UseCount++
+	t.UsedAt, t.UsedByNode = &now, &nodeID
+	return t, nil
+}
+func (m *mockStore) CreateNodeJoinTokenUse(ctx context.Context, u *model.NodeJoinTokenUse) error {
+	m.joinUses = append(m.joinUses, u)
+	return nil
+}
+func (m *mockStore) ListNodeJoinTokenUses(ctx context.Context, tokenID string) ([]*model.NodeJoinTokenUse, error) {
+	var result []*model.NodeJoinTokenUse
+	for _, u := range m.joinUses {
+		if u.TokenID == tokenID {
+			result = append(result, u)
+		}
+	}
+	return result, nil
+}
+func (m *mockStore) UpsertNodeCredential(ctx context.Context, c *model.NodeCredential) error {
+	m.credentials[c.NodeID] = c
+	return nil
+}
+func (m *mockStore) GetNodeCredential(ctx context.Context, nodeID string) (*model.NodeCredential, error) {
+	return m.credentials[nodeID], nil
+}
+func (m *mockStore) GetNodeCredentialByTokenHash(ctx context.Context, tokenHash string) (*model.NodeCredential, error) {
+	for _, c := range m.credentials {
+		if c.TokenHash == tokenHash {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockStore) DeleteNodeCredential(ctx context.Context, nodeID string) error {
+	delete(m.credentials, nodeID)
+	return nil
+}
+
+func (m *mockStore) GetApprovalRequest(ctx context.Context, id string) (*model.ApprovalRequest, error) {
+	return m.approvals[id], nil
+}
+
+func (m *mockStore) CreateApprovalRequest(ctx context.Context, req *model.ApprovalRequest) error {
+	m.approvals[req.ID] = req
+	return nil
+}
+
+func (m *mockStore) UpdateApprovalRequestStatus(ctx context.Context, id string, status model.ApprovalStatus) error {
+	m.approvals[id].Status = status
+	return nil
+}
+
+func (m *mockStore) ListFeedbacks(ctx context.Context, runID string) ([]*model.HumanFeedback, error) {
+	return m.feedbacks[runID], nil
+}
+
+func (m *mockStore) MarkFeedbackProcessed(ctx context.Context, id string) error { return nil }
 
 func TestHandler_Heartbeat(t *testing.T) {
 	store := newMockStore()
@@ -255,6 +397,196 @@ func TestHandler_Heartbeat(t *testing.T) {
 	}
 }
 
+func TestHandler_HeartbeatDirectives(t *testing.T) {
+	store := newMockStore()
+	store.runs["node-1"] = []*model.Run{{ID: "run-active", Status: model.RunStatusRunning}}
+	store.runsByID["run-cancelled"] = &model.Run{ID: "run-cancelled", Status: model.RunStatusCancelled}
+	store.runsByID["run-timeout"] = &model.Run{ID: "run-timeout", Status: model.RunStatusTimeout}
+	h := NewHandler(store)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"node_id":      "node-1",
+		"running_runs": []string{"run-active", "run-cancelled", "run-timeout"},
+	})
+	w := httptest.NewRecorder()
+	h.Heartbeat(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+
+	var resp HeartbeatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Directives == nil {
+		t.Fatal("expected directives")
+	}
+	if len(resp.Directives.CancelRuns) != 1 || resp.Directives.CancelRuns[0] != "run-cancelled" {
+		t.Errorf("unexpected cancel_runs: %v", resp.Directives.CancelRuns)
+	}
+	if len(resp.Directives.TimeoutRuns) != 1 || resp.Directives.TimeoutRuns[0] != "run-timeout" {
+		t.Errorf("unexpected timeout_runs: %v", resp.Directives.TimeoutRuns)
+	}
+}
+
+func TestHandler_HeartbeatApprovals(t *testing.T) {
+	store := newMockStore()
+	past := time.Now().Add(-time.Minute)
+	store.approvals["apr-approved"] = &model.ApprovalRequest{ID: "apr-approved", Status: model.ApprovalStatusApproved}
+	store.approvals["apr-pending"] = &model.ApprovalRequest{ID: "apr-pending", Status: model.ApprovalStatusPending}
+	store.approvals["apr-stale"] = &model.ApprovalRequest{ID: "apr-stale", Status: model.ApprovalStatusPending, ExpiresAt: &past}
+	h := NewHandler(store)
+
+	body := `{"node_id": "node-1", "pending_approvals": ["apr-approved", "apr-pending", "apr-stale", "apr-unknown"]}`
+	w := httptest.NewRecorder()
+	h.Heartbeat(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader([]byte(body))))
+
+	var resp HeartbeatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Directives == nil || len(resp.Directives.Approvals) != 2 {
+		t.Fatalf("unexpected directives: %+v", resp.Directives)
+	}
+	got := map[string]model.ApprovalStatus{}
+	for _, a := range resp.Directives.Approvals {
+		got[a.ApprovalID] = a.Status
+	}
+	if got["apr-approved"] != model.ApprovalStatusApproved || got["apr-stale"] != model.ApprovalStatusExpired {
+		t.Errorf("unexpected approvals: %v", got)
+	}
+	if store.approvals["apr-stale"].Status != model.ApprovalStatusExpired {
+		t.Error("expired approval should be persisted as expired")
+	}
+}
+
+func TestHandler_HeartbeatFeedbacks(t *testing.T) {
+	store := newMockStore()
+	processed := time.Now()
+	store.runs["node-1"] = []*model.Run{{ID: "run-1", Status: model.RunStatusRunning}}
+	store.feedbacks["run-1"] = []*model.HumanFeedback{
+		{ID: "fb-done", RunID: "run-1", Type: model.FeedbackTypeGuidance, Content: "old", ProcessedAt: &processed},
+		{ID: "fb-new", RunID: "run-1", Type: model.FeedbackTypeCorrection, Content: "use the v2 API"},
+	}
+	h := NewHandler(store)
+
+	body := `{"node_id": "node-1", "running_runs": ["run-1"]}`
+	w := httptest.NewRecorder()
+	h.Heartbeat(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader([]byte(body))))
+
+	var resp HeartbeatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Directives == nil || len(resp.Directives.Feedbacks) != 1 {
+		t.Fatalf("unexpected directives: %+v", resp.Directives)
+	}
+	if fb := resp.Directives.Feedbacks[0]; fb.ID != "fb-new" || fb.RunID != "run-1" || fb.Content != "use the v2 API" {
+		t.Errorf("unexpected feedback: %+v", fb)
+	}
+	if len(resp.Directives.CancelRuns) != 0 {
+		t.Errorf("running run must not be cancelled: %v", resp.Directives.CancelRuns)
+	}
+}
+
+func TestHandler_HeartbeatPauseDirectives(t *testing.T) {
+	store := newMockStore()
+	store.runs["node-1"] = []*model.Run{
+		{ID: "run-pause", Status: model.RunStatusPaused},
+		{ID: "run-paused", Status: model.RunStatusPaused},
+		{ID: "run-resume", Status: model.RunStatusRunning},
+		{ID: "run-plain", Status: model.RunStatusRunning},
+	}
+	h := NewHandler(store)
+
+	body := `{"node_id": "node-1", "running_runs": ["run-pause", "run-paused", "run-resume", "run-plain"], "paused_runs": ["run-paused", "run-resume"]}`
+	w := httptest.NewRecorder()
+	h.Heartbeat(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader([]byte(body))))
+
+	var resp HeartbeatResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Directives == nil {
+		t.Fatal("expected directives")
+	}
+	if len(resp.Directives.PauseRuns) != 1 || resp.Directives.PauseRuns[0] != "run-pause" {
+		t.Errorf("unexpected pause_runs: %v", resp.Directives.PauseRuns)
+	}
+	if len(resp.Directives.ResumeRuns) != 1 || resp.Directives.ResumeRuns[0] != "run-resume" {
+		t.Errorf("unexpected resume_runs: %v", resp.Directives.ResumeRuns)
+	}
+	if len(resp.Directives.CancelRuns) != 0 {
+		t.Errorf("paused runs must not be cancelled: %v", resp.Directives.CancelRuns)
+	}
+
+	occ := h.occupancy.Snapshot(time.Now())
+	if len(occ) != 1 || occ[0].Used != 2 {
+		t.Errorf("paused runs must not occupy slots: %+v", occ)
+	}
+}
+
+// runObserverFunc 记录心跳上报的 running_runs
+type runObserverFunc func(nodeID string, runningRuns []string)
+
+func (f runObserverFunc) ObserveRunningRuns(_ context.Context, nodeID string, runningRuns []string, _ []*model.Run) {
+	f(nodeID, runningRuns)
+}
+
+func TestHandler_HeartbeatObservesRunningRuns(t *testing.T) {
+	h := NewHandler(newMockStore())
+	var observed [][]string
+	h.SetRunObserver(runObserverFunc(func(nodeID string, runningRuns []string) {
+		if nodeID != "node-1" {
+			t.Errorf("unexpected node: %s", nodeID)
+		}
+		observed = append(observed, runningRuns)
+	}))
+
+	for _, body := range []string{
+		`{"node_id": "node-1", "running_runs": ["run-1"]}`,
+		`{"node_id": "node-1", "running_runs": []}`,
+		`{"node_id": "node-1"}`,
+	} {
+		w := httptest.NewRecorder()
+		h.Heartbeat(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader([]byte(body))))
+		if w.Code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", w.Code)
+		}
+	}
+	// 空列表与未上报字段需要区分：前者参与孤儿检测，后者跳过
+	if len(observed) != 2 || len(observed[0]) != 1 || observed[1] == nil {
+		t.Errorf("unexpected observed running_runs: %#v", observed)
+	}
+}
+
+func TestHandler_GetRunsEventSeq(t *testing.T) {
+	store := newMockStore()
+	store.runs["node-1"] = []*model.Run{
+		{ID: "run-reassigned", Status: model.RunStatusAssigned},
+		{ID: "run-new", Status: model.RunStatusAssigned},
+		{ID: "run-running", Status: model.RunStatusRunning},
+	}
+	store.events = map[string]int{"run-reassigned": 6, "run-running": 12}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/nodes/node-1/runs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	var resp struct {
+		Runs []struct {
+			ID       string `json:"id"`
+			Status   string `json:"status"`
+			EventSeq int    `json:"event_seq"`
+		} `json:"runs"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	want := map[string]int{"run-reassigned": 6, "run-new": 0, "run-running": 0}
+	if len(resp.Runs) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(resp.Runs))
+	}
+	for _, r := range resp.Runs {
+		if r.EventSeq != want[r.ID] || r.Status == "" {
+			t.Errorf("%s: event_seq = %d, status = %q", r.ID, r.EventSeq, r.Status)
+		}
+	}
+}
+
 func TestHandler_List(t *testing.T) {
 	store := newMockStore()
 	now := time.Now()
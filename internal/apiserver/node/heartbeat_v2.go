@@ -0,0 +1,138 @@
+// Package node 心跳协议 v2
+//
+// v1 心跳每 10 秒上报完整的标签与容量并整行 upsert 节点记录，节点规模较大时写入压力集中在 nodes 表。v2 心跳：
+//   - 节点对主机名、IP、标签与容量（不含 available 与 metrics）计算内容哈希，只在哈希变化或距上次完整上报较久时
+//     携带完整内容；其余心跳（增量心跳）只携带哈希、running_runs 等运行状态与 metrics 采样
+//   - API Server 记住每个节点最近写入的内容哈希：增量心跳的哈希一致时不写节点记录，只按 heartbeatTouchInterval
+//     刷新 last_heartbeat；哈希未知（API Server 重启或由其他实例写入）时响应 resync，节点下次心跳携带完整内容
+//   - 响应 interval_seconds：节点空闲（无运行中的 Run、无控制指令）时心跳间隔逐次翻倍至 HeartbeatMaxInterval，
+//     有活动时恢复为 HeartbeatInterval
+//
+// 不带 protocol_version 的请求（旧版本节点、gRPC 心跳）按 v1 处理；节点只在响应表明服务端支持 v2 后才发送增量心跳。
+package node
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// HeartbeatProtocolVersion 服务端支持的心跳协议版本
+	HeartbeatProtocolVersion = 2
+
+	// HeartbeatInterval 节点有活动时的心跳间隔
+	HeartbeatInterval = 10 * time.Second
+
+	// HeartbeatMaxInterval 节点空闲时心跳间隔的上限
+	//
+	// 加上 heartbeatTouchInterval 后仍需小于 HeartbeatFreshWindow，避免空闲节点被判定离线。
+	HeartbeatMaxInterval = 25 * time.Second
+
+	// heartbeatTouchInterval 增量心跳刷新 last_heartbeat 的最小间隔
+	heartbeatTouchInterval = 15 * time.Second
+)
+
+// heartbeatTracker 记录 v2 心跳最近写入的内容哈希（进程内，API Server 重启后由节点 resync 重建）
+type heartbeatTracker struct {
+	mu    sync.Mutex
+	nodes map[string]*heartbeatEntry
+}
+
+// heartbeatEntry 节点最近一次完整心跳的写入记录
+type heartbeatEntry struct {
+	hash      string
+	node      *model.Node // 写入的节点记录（增量心跳据此计算槽位容量）
+	touchedAt time.Time   // 最近一次写入 last_heartbeat 的时间
+}
+
+func newHeartbeatTracker() *heartbeatTracker {
+	return &heartbeatTracker{nodes: make(map[string]*heartbeatEntry)}
+}
+
+// remember 记录完整心跳写入的节点记录与内容哈希
+func (t *heartbeatTracker) remember(node *model.Node, hash string, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.nodes[node.ID] = &heartbeatEntry{hash: hash, node: node, touchedAt: now}
+}
+
+// lookup 返回哈希一致时最近写入的节点记录
+func (t *heartbeatTracker) lookup(nodeID, hash string) (*model.Node, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.nodes[nodeID]
+	if !ok || e.hash != hash {
+		return nil, false
+	}
+	return e.node, true
+}
+
+// touch 距上次写入 last_heartbeat 超过 heartbeatTouchInterval 时返回 true 并记录本次写入
+func (t *heartbeatTracker) touch(nodeID string, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e, ok := t.nodes[nodeID]
+	if !ok {
+		return true
+	}
+	if now.Sub(e.touchedAt) < heartbeatTouchInterval {
+		return false
+	}
+	e.touchedAt = now
+	return true
+}
+
+// forget 删除节点的记录
+func (t *heartbeatTracker) forget(nodeID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.nodes, nodeID)
+}
+
+// isDelta 是否为 v2 增量心跳（携带内容哈希、不携带标签与容量）
+func (r *HeartbeatRequestExt) isDelta() bool {
+	return r.ProtocolVersion >= HeartbeatProtocolVersion && r.ContentHash != "" && r.Labels == nil && r.Capacity == nil
+}
+
+// processDeltaHeartbeat 处理增量心跳，返回用于计算槽位容量的节点记录
+//
+// 哈希与最近写入的一致时不写节点记录，只按间隔刷新 last_heartbeat；哈希未知时刷新 last_heartbeat 并要求 resync。
+func (h *Handler) processDeltaHeartbeat(ctx context.Context, req *HeartbeatRequestExt, now time.Time, resp *HeartbeatResponse) *model.Node {
+	node, known := h.heartbeats.lookup(req.NodeId, req.ContentHash)
+	if !known {
+		resp.Resync = true
+		slog.InfoContext(ctx, "node.heartbeat", "detail", "resync requested", "node", req.NodeId, "hash", req.ContentHash)
+		stored, err := h.store.GetNode(ctx, req.NodeId)
+		if err != nil {
+			slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to get node", "node", req.NodeId, "err", err)
+		}
+		node = stored
+		if node == nil {
+			node = &model.Node{ID: req.NodeId}
+		}
+	}
+
+	if !known || h.heartbeats.touch(req.NodeId, now) {
+		if err := h.store.TouchNodeHeartbeat(ctx, req.NodeId, now); err != nil {
+			slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to touch heartbeat", "node", req.NodeId, "err", err)
+		}
+	}
+	h.recordMetrics(ctx, req.NodeId, req.Metrics)
+	return node
+}
+
+// nextHeartbeatInterval 计算下次心跳间隔（秒）
+//
+// 节点有运行中的 Run、等待中的审批、待执行的控制指令或需要 resync 时为 HeartbeatInterval，
+// 否则在节点当前间隔的基础上翻倍，不超过 HeartbeatMaxInterval。
+func nextHeartbeatInterval(req *HeartbeatRequestExt, resp *HeartbeatResponse) int {
+	if len(req.RunningRuns) > 0 || len(req.Approvals) > 0 || resp.Directives != nil || resp.Resync {
+		return int(HeartbeatInterval / time.Second)
+	}
+	next := max(2*time.Duration(req.IntervalSeconds)*time.Second, HeartbeatInterval)
+	return int(min(next, HeartbeatMaxInterval) / time.Second)
+}
@@ -0,0 +1,115 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func postHeartbeat(t *testing.T, h *Handler, body map[string]interface{}) HeartbeatResponse {
+	t.Helper()
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	h.Heartbeat(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader(data)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("heartbeat status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp HeartbeatResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+// TestHandler_HeartbeatV2Delta 内容哈希未变化的增量心跳不写节点记录，未知哈希要求 resync
+func TestHandler_HeartbeatV2Delta(t *testing.T) {
+	store := newMockStore()
+	metrics := &memMetricsCache{samples: map[string][]*model.NodeMetrics{}}
+	h := NewHandler(store)
+	h.SetMetricsCache(metrics)
+
+	resp := postHeartbeat(t, h, map[string]interface{}{
+		"node_id":          "node-1",
+		"protocol_version": 2,
+		"content_hash":     "h1",
+		"interval_seconds": 10,
+		"labels":           map[string]string{"region": "cn-east"},
+		"capacity":         map[string]interface{}{"max_concurrent": 2},
+		"running_runs":     []string{},
+	})
+	if resp.ProtocolVersion != HeartbeatProtocolVersion || resp.Resync || resp.IntervalSeconds != 20 {
+		t.Fatalf("完整心跳响应 = %+v", resp)
+	}
+	if store.upserts != 1 {
+		t.Fatalf("upserts = %d, want 1", store.upserts)
+	}
+
+	// 哈希一致的增量心跳：不 upsert，间隔内不刷新 last_heartbeat，采样写入指标窗口
+	resp = postHeartbeat(t, h, map[string]interface{}{
+		"node_id":          "node-1",
+		"protocol_version": 2,
+		"content_hash":     "h1",
+		"interval_seconds": 20,
+		"running_runs":     []string{},
+		"metrics":          model.NodeMetrics{CPUPercent: 30, CPUs: 4},
+	})
+	if resp.Resync || resp.IntervalSeconds != int(HeartbeatMaxInterval/time.Second) {
+		t.Errorf("增量心跳响应 = %+v", resp)
+	}
+	if store.upserts != 1 || store.touches != 0 {
+		t.Errorf("upserts = %d touches = %d, want 1 / 0", store.upserts, store.touches)
+	}
+	if got := len(metrics.samples["node-1"]); got != 1 {
+		t.Errorf("samples = %d, want 1", got)
+	}
+
+	// 超过刷新间隔后只刷新 last_heartbeat
+	h.heartbeats.nodes["node-1"].touchedAt = time.Now().Add(-heartbeatTouchInterval)
+	postHeartbeat(t, h, map[string]interface{}{"node_id": "node-1", "protocol_version": 2, "content_hash": "h1"})
+	if store.upserts != 1 || store.touches != 1 {
+		t.Errorf("upserts = %d touches = %d, want 1 / 1", store.upserts, store.touches)
+	}
+
+	// 未知哈希：刷新心跳时间并要求 resync
+	resp = postHeartbeat(t, h, map[string]interface{}{"node_id": "node-1", "protocol_version": 2, "content_hash": "h2", "interval_seconds": 20})
+	if !resp.Resync || resp.IntervalSeconds != int(HeartbeatInterval/time.Second) {
+		t.Errorf("未知哈希响应 = %+v", resp)
+	}
+	if store.touches != 2 {
+		t.Errorf("touches = %d, want 2", store.touches)
+	}
+
+	// v1 心跳不返回 v2 字段
+	resp = postHeartbeat(t, h, map[string]interface{}{"node_id": "node-2"})
+	if resp.ProtocolVersion != 0 || resp.IntervalSeconds != 0 {
+		t.Errorf("v1 响应 = %+v", resp)
+	}
+}
+
+func TestNextHeartbeatInterval(t *testing.T) {
+	tests := []struct {
+		name string
+		req  HeartbeatRequestExt
+		resp HeartbeatResponse
+		want int
+	}{
+		{"首次心跳", HeartbeatRequestExt{}, HeartbeatResponse{}, 10},
+		{"空闲翻倍", HeartbeatRequestExt{IntervalSeconds: 10}, HeartbeatResponse{}, 20},
+		{"不超过上限", HeartbeatRequestExt{IntervalSeconds: 20}, HeartbeatResponse{}, 25},
+		{"有运行中的 Run", HeartbeatRequestExt{IntervalSeconds: 25, RunningRuns: []string{"run-1"}}, HeartbeatResponse{}, 10},
+		{"有控制指令", HeartbeatRequestExt{IntervalSeconds: 25}, HeartbeatResponse{Directives: &HeartbeatDirectives{}}, 10},
+		{"需要 resync", HeartbeatRequestExt{IntervalSeconds: 25}, HeartbeatResponse{Resync: true}, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := nextHeartbeatInterval(&tt.req, &tt.resp); got != tt.want {
+				t.Errorf("nextHeartbeatInterval() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
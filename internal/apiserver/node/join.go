@@ -0,0 +1,439 @@
+package node
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/tlsutil"
+)
+
+const (
+	defaultJoinTokenTTL   = time.Hour
+	maxJoinTokenTTL       = 24 * time.Hour
+	defaultNodeCertValid  = 365 * 24 * time.Hour
+	credentialCacheTTL    = 30 * time.Second
+	maxJoinNodeIDLength   = 128
+	maxJoinTokenUses      = 1000
+	joinTokenRandomLength = 32
+)
+
+// JoinConfig 节点自动注册配置
+type JoinConfig struct {
+	CAFile       string        // 签发节点客户端证书的 CA 证书（为空时只下发专属 Token）
+	CAKeyFile    string        // CA 私钥
+	CertValidity time.Duration // 节点客户端证书有效期（默认 1 年）
+}
+
+// joinState 节点自动注册状态：CA 懒加载 + 凭证校验缓存
+type joinState struct {
+	cfg JoinConfig
+
+	caMu sync.Mutex
+	ca   *tlsutil.CA
+
+	cacheMu sync.Mutex
+	cache   map[string]cachedCredential // key: token hash、"cert:<nodeID>:<serial>" 或 "labels:<nodeID>"
+}
+
+type cachedCredential struct {
+	nodeID  string
+	labels  map[string]string // 仅 "labels:<nodeID>" 条目使用
+	expires time.Time
+}
+
+// SetJoinConfig 设置节点自动注册配置（CA 在首次签发证书时加载）
+func (h *Handler) SetJoinConfig(cfg JoinConfig) {
+	if cfg.CertValidity <= 0 {
+		cfg.CertValidity = defaultNodeCertValid
+	}
+	h.join.cfg = cfg
+}
+
+// loadCA 懒加载 CA：API Server 自签名证书在路由注册之后才生成
+func (j *joinState) loadCA() *tlsutil.CA {
+	if j.cfg.CAFile == "" || j.cfg.CAKeyFile == "" {
+		return nil
+	}
+	j.caMu.Lock()
+	defer j.caMu.Unlock()
+	if j.ca == nil {
+		ca, err := tlsutil.LoadCA(j.cfg.CAFile, j.cfg.CAKeyFile)
+		if err != nil {
+			log.Printf("[node.join.ca.error] ca_file=%s err=%v", j.cfg.CAFile, err)
+			return nil
+		}
+		j.ca = ca
+	}
+	return j.ca
+}
+
+func (j *joinState) lookup(key string) (cachedCredential, bool) {
+	j.cacheMu.Lock()
+	defer j.cacheMu.Unlock()
+	c, ok := j.cache[key]
+	if !ok || time.Now().After(c.expires) {
+		return cachedCredential{}, false
+	}
+	return c, true
+}
+
+func (j *joinState) cached(key string) (string, bool) {
+	c, ok := j.lookup(key)
+	return c.nodeID, ok
+}
+
+func (j *joinState) store(key string, c cachedCredential) {
+	j.cacheMu.Lock()
+	defer j.cacheMu.Unlock()
+	if j.cache == nil {
+		j.cache = make(map[string]cachedCredential)
+	}
+	c.expires = time.Now().Add(credentialCacheTTL)
+	j.cache[key] = c
+}
+
+func (j *joinState) remember(key, nodeID string) {
+	j.store(key, cachedCredential{nodeID: nodeID})
+}
+
+// forget 撤销凭证时清除该节点的缓存
+func (j *joinState) forget(nodeID string) {
+	j.cacheMu.Lock()
+	defer j.cacheMu.Unlock()
+	for k, c := range j.cache {
+		if c.nodeID == nodeID {
+			delete(j.cache, k)
+		}
+	}
+}
+
+// ============================================================================
+// 凭证校验（实现 auth.NodeCredentialVerifier）
+// ============================================================================
+
+// VerifyNodeToken 校验节点专属 Token，返回对应节点 ID
+func (h *Handler) VerifyNodeToken(ctx context.Context, token string) (string, bool) {
+	hash := hashSecret(token)
+	if nodeID, ok := h.join.cached(hash); ok {
+		return nodeID, true
+	}
+	cred, err := h.store.GetNodeCredentialByTokenHash(ctx, hash)
+	if err != nil {
+		slog.ErrorContext(ctx, "node.credential.verify.error", "err", err)
+		return "", false
+	}
+	if cred == nil {
+		return "", false
+	}
+	h.join.remember(hash, cred.NodeID)
+	return cred.NodeID, true
+}
+
+// VerifyNodeCert 校验节点客户端证书是否为该节点当前有效的证书（重新加入或删除节点后旧证书失效）
+func (h *Handler) VerifyNodeCert(ctx context.Context, nodeID, serial string) bool {
+	key := "cert:" + nodeID + ":" + serial
+	if _, ok := h.join.cached(key); ok {
+		return true
+	}
+	cred, err := h.store.GetNodeCredential(ctx, nodeID)
+	if err != nil {
+		slog.ErrorContext(ctx, "node.credential.verify.error", "node_id", nodeID, "err", err)
+		return false
+	}
+	if cred == nil || cred.CertSerial != serial {
+		return false
+	}
+	h.join.remember(key, nodeID)
+	return true
+}
+
+// applyEnrolledLabels 以加入令牌继承的标签覆盖节点心跳上报的同名标签
+func (h *Handler) applyEnrolledLabels(ctx context.Context, nodeID string, reported map[string]string) map[string]string {
+	key := "labels:" + nodeID
+	c, ok := h.join.lookup(key)
+	if !ok {
+		cred, err := h.store.GetNodeCredential(ctx, nodeID)
+		if err != nil {
+			slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to get node credential", "error", err)
+			return reported
+		}
+		c = cachedCredential{nodeID: nodeID}
+		if cred != nil {
+			c.labels = cred.Labels
+		}
+		h.join.store(key, c)
+	}
+	if len(c.labels) == 0 {
+		return reported
+	}
+	labels := make(map[string]string, len(reported)+len(c.labels))
+	for k, v := range reported {
+		labels[k] = v
+	}
+	for k, v := range c.labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// ============================================================================
+// 加入令牌管理（管理员）
+// ============================================================================
+
+// CreateJoinTokenRequest 创建加入令牌请求
+type CreateJoinTokenRequest struct {
+	Description string            `json:"description,omitempty"`
+	TTL         string            `json:"ttl,omitempty"`      // 有效期（Go duration，默认 1h，最长 24h）
+	MaxUses     int               `json:"max_uses,omitempty"` // 最多可加入的节点数（默认 1）
+	Labels      map[string]string `json:"labels,omitempty"`   // 加入的节点继承的标签
+	Pool        string            `json:"pool,omitempty"`     // 加入的节点所属节点池
+}
+
+// CreateJoinTokenResponse 创建加入令牌响应（明文令牌只返回这一次）
+type CreateJoinTokenResponse struct {
+	*model.NodeJoinToken
+	Token string `json:"token"`
+}
+
+// requireTokenAdmin 加入令牌只能由管理员管理，节点凭证不能用来签发新令牌
+func requireTokenAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if !auth.IsAdmin(r.Context()) {
+		writeError(w, http.StatusForbidden, "admin access required")
+		return false
+	}
+	return true
+}
+
+// CreateJoinToken 创建节点加入令牌（默认一次性，可设置使用次数、预设标签与节点池）
+// POST /api/v1/nodes/join-tokens
+func (h *Handler) CreateJoinToken(w http.ResponseWriter, r *http.Request) {
+	if !requireTokenAdmin(w, r) {
+		return
+	}
+	var req CreateJoinTokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	ttl := defaultJoinTokenTTL
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 || d > maxJoinTokenTTL {
+			writeError(w, http.StatusBadRequest, "ttl must be a duration between 0 and 24h")
+			return
+		}
+		ttl = d
+	}
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.MaxUses < 0 || req.MaxUses > maxJoinTokenUses {
+		writeError(w, http.StatusBadRequest, "max_uses must be between 1 and 1000")
+		return
+	}
+	for k := range req.Labels {
+		if k == "" {
+			writeError(w, http.StatusBadRequest, "label key must not be empty")
+			return
+		}
+	}
+
+	plain := randomSecret()
+	now := time.Now()
+	t := &model.NodeJoinToken{
+		ID:          generateID("njt"),
+		TokenHash:   hashSecret(plain),
+		Description: req.Description,
+		MaxUses:     req.MaxUses,
+		Labels:      req.Labels,
+		Pool:        req.Pool,
+		ExpiresAt:   now.Add(ttl),
+		CreatedAt:   now,
+	}
+	if user := auth.GetAuthUser(r.Context()); user != nil {
+		t.CreatedBy = user.ID
+	}
+	if err := h.store.CreateNodeJoinToken(r.Context(), t); err != nil {
+		slog.ErrorContext(r.Context(), "node.join_token.create.error", "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to create join token")
+		return
+	}
+	slog.InfoContext(r.Context(), "node.join_token.create.success", "id", t.ID, "created_by", t.CreatedBy,
+		"max_uses", t.MaxUses, "pool", t.Pool, "expires_at", t.ExpiresAt.Format(time.RFC3339))
+	writeJSON(w, http.StatusCreated, CreateJoinTokenResponse{NodeJoinToken: t, Token: plain})
+}
+
+// ListJoinTokens 列出节点加入令牌（不含明文）
+// GET /api/v1/nodes/join-tokens
+func (h *Handler) ListJoinTokens(w http.ResponseWriter, r *http.Request) {
+	if !requireTokenAdmin(w, r) {
+		return
+	}
+	tokens, err := h.store.ListNodeJoinTokens(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list join tokens")
+		return
+	}
+	if tokens == nil {
+		tokens = []*model.NodeJoinToken{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tokens": tokens, "count": len(tokens)})
+}
+
+// ListJoinTokenUses 列出加入令牌的使用记录（令牌删除后仍保留）
+// GET /api/v1/nodes/join-tokens/{id}/uses
+func (h *Handler) ListJoinTokenUses(w http.ResponseWriter, r *http.Request) {
+	if !requireTokenAdmin(w, r) {
+		return
+	}
+	uses, err := h.store.ListNodeJoinTokenUses(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list join token uses")
+		return
+	}
+	if uses == nil {
+		uses = []*model.NodeJoinTokenUse{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"uses": uses, "count": len(uses)})
+}
+
+// DeleteJoinToken 撤销节点加入令牌
+// DELETE /api/v1/nodes/join-tokens/{id}
+func (h *Handler) DeleteJoinToken(w http.ResponseWriter, r *http.Request) {
+	if !requireTokenAdmin(w, r) {
+		return
+	}
+	if err := h.store.DeleteNodeJoinToken(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete join token")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ============================================================================
+// 节点加入
+// ============================================================================
+
+// JoinRequest 节点以加入令牌换取专属凭证
+type JoinRequest struct {
+	Token    string `json:"token"`
+	NodeID   string `json:"node_id"`
+	Hostname string `json:"hostname,omitempty"`
+	CSR      string `json:"csr,omitempty"` // 客户端证书请求（PEM，可选）
+}
+
+// JoinResponse 节点专属凭证
+type JoinResponse struct {
+	NodeID        string     `json:"node_id"`
+	NodeToken     string     `json:"node_token"`
+	Certificate   string     `json:"certificate,omitempty"`    // 节点客户端证书（PEM）
+	CACertificate string     `json:"ca_certificate,omitempty"` // 签发 CA（PEM，用于校验 API Server）
+	CertExpiresAt *time.Time `json:"cert_expires_at,omitempty"`
+
+	Labels map[string]string `json:"labels,omitempty"` // 从加入令牌继承的标签（由 API Server 在心跳时应用）
+}
+
+// Join 兑换一次性加入令牌，下发节点专属 Token 与客户端证书
+// POST /api/v1/nodes/join（公开路由，令牌即凭证）
+func (h *Handler) Join(w http.ResponseWriter, r *http.Request) {
+	var req JoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Token == "" || req.NodeID == "" || len(req.NodeID) > maxJoinNodeIDLength {
+		writeError(w, http.StatusBadRequest, "token and node_id are required")
+		return
+	}
+
+	resp := JoinResponse{NodeID: req.NodeID, NodeToken: randomSecret()}
+	cred := &model.NodeCredential{NodeID: req.NodeID, TokenHash: hashSecret(resp.NodeToken)}
+
+	// 先签发证书再兑换令牌：CSR 无效时不浪费令牌
+	if req.CSR != "" {
+		if ca := h.join.loadCA(); ca != nil {
+			certPEM, cert, err := ca.SignClientCSR([]byte(req.CSR), req.NodeID, h.join.cfg.CertValidity)
+			if err != nil {
+				writeError(w, http.StatusBadRequest, "invalid csr")
+				return
+			}
+			resp.Certificate = string(certPEM)
+			resp.CACertificate = string(ca.CertPEM())
+			resp.CertExpiresAt = &cert.NotAfter
+			cred.CertSerial = tlsutil.CertSerial(cert)
+			cred.CertExpiresAt = &cert.NotAfter
+		}
+	}
+
+	now := time.Now()
+	token, err := h.store.ConsumeNodeJoinToken(r.Context(), hashSecret(req.Token), req.NodeID, now)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "node.join.error", "node_id", req.NodeID, "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to consume join token")
+		return
+	}
+	if token == nil {
+		slog.WarnContext(r.Context(), "node.join.rejected", "node_id", req.NodeID, "reason", "invalid_or_used_token")
+		writeError(w, http.StatusUnauthorized, "invalid, expired or already used join token")
+		return
+	}
+
+	cred.JoinTokenID = token.ID
+	cred.Labels = token.EnrollLabels()
+	cred.CreatedAt = now
+	if err := h.store.UpsertNodeCredential(r.Context(), cred); err != nil {
+		slog.ErrorContext(r.Context(), "node.join.error", "node_id", req.NodeID, "err", err)
+		writeError(w, http.StatusInternalServerError, "failed to save node credential")
+		return
+	}
+	h.join.forget(req.NodeID)
+
+	use := &model.NodeJoinTokenUse{
+		ID:         generateID("nju"),
+		TokenID:    token.ID,
+		NodeID:     req.NodeID,
+		Hostname:   req.Hostname,
+		RemoteAddr: auth.RemoteHost(r.RemoteAddr),
+		UsedAt:     now,
+	}
+	if err := h.store.CreateNodeJoinTokenUse(r.Context(), use); err != nil {
+		slog.ErrorContext(r.Context(), "node.join.audit.error", "node_id", req.NodeID, "token_id", token.ID, "err", err)
+	}
+
+	slog.InfoContext(r.Context(), "node.join.success", "node_id", req.NodeID, "token_id", token.ID,
+		"use", token.UseCount, "max_uses", token.MaxUses, "cert", cred.CertSerial != "")
+	resp.Labels = cred.Labels
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// randomSecret 生成随机凭证（十六进制）
+func randomSecret() string {
+	b := make([]byte, joinTokenRandomLength)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// hashSecret 凭证只以 SHA-256 哈希落库
+func hashSecret(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// generateID 生成带前缀的随机 ID
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,228 @@
+package node
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/tlsutil"
+)
+
+func newJoinTestHandler(t *testing.T) (*Handler, *mockStore, *http.ServeMux) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := tlsutil.GenerateCerts(tlsutil.GenerateOptions{CertDir: dir}); err != nil {
+		t.Fatalf("GenerateCerts failed: %v", err)
+	}
+	files := tlsutil.DefaultCertFiles(dir)
+
+	store := newMockStore()
+	h := NewHandler(store)
+	h.SetJoinConfig(JoinConfig{CAFile: files.CAFile, CAKeyFile: files.CAKeyFile, CertValidity: time.Hour})
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, store, mux
+}
+
+func createJoinToken(t *testing.T, mux *http.ServeMux, body string) CreateJoinTokenResponse {
+	t.Helper()
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/api/v1/nodes/join-tokens", bytes.NewBufferString(body))
+	mux.ServeHTTP(w, req.WithContext(auth.WithAuthDisabled(req.Context()))) // 无认证模式
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create join token: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp CreateJoinTokenResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	return resp
+}
+
+func postJoin(mux *http.ServeMux, req JoinRequest) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/nodes/join", bytes.NewReader(body)))
+	return w
+}
+
+func TestHandler_JoinFlow(t *testing.T) {
+	h, store, mux := newJoinTestHandler(t)
+
+	created := createJoinToken(t, mux, `{"description":"rack-1","ttl":"30m"}`)
+	if created.Token == "" || created.ID == "" {
+		t.Fatalf("unexpected token response: %+v", created)
+	}
+	if d := time.Until(created.ExpiresAt); d > 30*time.Minute || d < 29*time.Minute {
+		t.Errorf("expires_at = %v, want ~30m from now", created.ExpiresAt)
+	}
+	if _, ok := store.joinTokens[created.Token]; ok {
+		t.Error("plaintext token must not be stored")
+	}
+
+	csr, _, err := tlsutil.NewClientCSR("node-1")
+	if err != nil {
+		t.Fatalf("NewClientCSR failed: %v", err)
+	}
+	w := postJoin(mux, JoinRequest{Token: created.Token, NodeID: "node-1", CSR: string(csr)})
+	if w.Code != http.StatusOK {
+		t.Fatalf("join: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp JoinResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.NodeToken == "" || resp.Certificate == "" || resp.CACertificate == "" {
+		t.Fatalf("incomplete credential: %+v", resp)
+	}
+
+	// 专属 Token 与证书都映射到该节点
+	if nodeID, ok := h.VerifyNodeToken(t.Context(), resp.NodeToken); !ok || nodeID != "node-1" {
+		t.Errorf("VerifyNodeToken = %q, %v", nodeID, ok)
+	}
+	block, _ := pem.Decode([]byte(resp.Certificate))
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	if cert.Subject.CommonName != "node-1" {
+		t.Errorf("cert CN = %q", cert.Subject.CommonName)
+	}
+	if !h.VerifyNodeCert(t.Context(), "node-1", tlsutil.CertSerial(cert)) {
+		t.Error("VerifyNodeCert should accept the issued cert")
+	}
+	if h.VerifyNodeCert(t.Context(), "node-2", tlsutil.CertSerial(cert)) {
+		t.Error("VerifyNodeCert should reject the cert for another node")
+	}
+
+	// 令牌只能使用一次
+	if w := postJoin(mux, JoinRequest{Token: created.Token, NodeID: "node-2"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("reused token: status = %d, want 401", w.Code)
+	}
+
+	// 删除节点撤销凭证
+	store.nodes["node-1"] = &model.Node{ID: "node-1"}
+	req := httptest.NewRequest("DELETE", "/api/v1/nodes/node-1", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete node: status = %d", w.Code)
+	}
+	if _, ok := h.VerifyNodeToken(t.Context(), resp.NodeToken); ok {
+		t.Error("credential should be revoked after node deletion")
+	}
+}
+
+func TestHandler_JoinRejectsInvalidRequests(t *testing.T) {
+	_, store, mux := newJoinTestHandler(t)
+	created := createJoinToken(t, mux, "")
+
+	expiredHash := hashSecret("expired")
+	store.joinTokens[expiredHash] = &model.NodeJoinToken{ID: "njt-old", TokenHash: expiredHash, MaxUses: 1, ExpiresAt: time.Now().Add(-time.Minute)}
+
+	tests := []struct {
+		name       string
+		req        JoinRequest
+		wantStatus int
+	}{
+		{"missing node_id", JoinRequest{Token: created.Token}, http.StatusBadRequest},
+		{"unknown token", JoinRequest{Token: "unknown", NodeID: "node-1"}, http.StatusUnauthorized},
+		{"expired token", JoinRequest{Token: "expired", NodeID: "node-1"}, http.StatusUnauthorized},
+		{"invalid csr", JoinRequest{Token: created.Token, NodeID: "node-1", CSR: "garbage"}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w := postJoin(mux, tt.req); w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d, body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	// CSR 无效时令牌不被消耗
+	if w := postJoin(mux, JoinRequest{Token: created.Token, NodeID: "node-1"}); w.Code != http.StatusOK {
+		t.Errorf("token should still be valid, status = %d", w.Code)
+	}
+}
+
+func TestHandler_JoinTokenRequiresAdmin(t *testing.T) {
+	_, _, mux := newJoinTestHandler(t)
+
+	tests := []struct {
+		name       string
+		ctx        func(r *http.Request) *http.Request
+		wantStatus int
+	}{
+		{"admin", func(r *http.Request) *http.Request {
+			return r.WithContext(auth.WithAuthUser(r.Context(), &auth.AuthUser{ID: "u1", Role: auth.UserRoleAdmin}))
+		}, http.StatusCreated},
+		{"regular user", func(r *http.Request) *http.Request {
+			return r.WithContext(auth.WithAuthUser(r.Context(), &auth.AuthUser{ID: "u2", Role: "user"}))
+		}, http.StatusForbidden},
+		{"node credential", func(r *http.Request) *http.Request {
+			return r.WithContext(auth.WithNodeIdentity(r.Context(), "node-1"))
+		}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, tt.ctx(httptest.NewRequest("POST", "/api/v1/nodes/join-tokens", nil)))
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestHandler_JoinTokenEnrollment(t *testing.T) {
+	_, store, mux := newJoinTestHandler(t)
+	created := createJoinToken(t, mux, `{"max_uses":2,"pool":"gpu","labels":{"zone":"a"}}`)
+	if created.MaxUses != 2 {
+		t.Fatalf("max_uses = %d, want 2", created.MaxUses)
+	}
+
+	for _, nodeID := range []string{"node-1", "node-2"} {
+		w := postJoin(mux, JoinRequest{Token: created.Token, NodeID: nodeID, Hostname: "host-" + nodeID})
+		if w.Code != http.StatusOK {
+			t.Fatalf("join %s: status = %d", nodeID, w.Code)
+		}
+	}
+	if w := postJoin(mux, JoinRequest{Token: created.Token, NodeID: "node-3"}); w.Code != http.StatusUnauthorized {
+		t.Errorf("exhausted token: status = %d, want 401", w.Code)
+	}
+
+	// 使用记录可审计
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/api/v1/nodes/join-tokens/"+created.ID+"/uses", nil)
+	mux.ServeHTTP(w, req.WithContext(auth.WithAuthDisabled(req.Context())))
+	var uses struct {
+		Uses []*model.NodeJoinTokenUse `json:"uses"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &uses)
+	if len(uses.Uses) != 2 || uses.Uses[1].Hostname != "host-node-2" {
+		t.Fatalf("unexpected uses: %s", w.Body.String())
+	}
+
+	// 心跳上报的标签被令牌预设的标签覆盖
+	body := `{"node_id":"node-1","labels":{"os":"linux","pool":"cpu"}}`
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("heartbeat: status = %d", w.Code)
+	}
+	var labels map[string]string
+	json.Unmarshal(store.nodes["node-1"].Labels, &labels)
+	want := map[string]string{"os": "linux", "pool": "gpu", "zone": "a"}
+	if len(labels) != len(want) {
+		t.Fatalf("labels = %v, want %v", labels, want)
+	}
+	for k, v := range want {
+		if labels[k] != v {
+			t.Errorf("labels[%s] = %q, want %q", k, labels[k], v)
+		}
+	}
+}
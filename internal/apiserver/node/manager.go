@@ -4,7 +4,8 @@ package node
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
+	"sync"
 	"time"
 
 	"agents-admin/internal/shared/model"
@@ -13,9 +14,10 @@ import (
 
 // Manager 节点管理器
 //
-// 负责管理节点的在线状态、容量信息和运行任务计数
+// 负责管理节点的在线状态、容量信息和运行任务计数（可被多个调度 worker 并发使用）
 type Manager struct {
 	store       storage.PersistentStore
+	mu          sync.Mutex     // 保护 nodeRunning
 	nodeRunning map[string]int // 节点当前运行的任务数（内存缓存）
 }
 
@@ -45,22 +47,35 @@ func (m *Manager) ListOnlineNodes(ctx context.Context) ([]*model.Node, error) {
 	return online, nil
 }
 
-// RefreshRunningCount 刷新节点运行任务计数
+// RefreshRunningCount 刷新节点运行任务计数（不含暂停中的 Run）
 func (m *Manager) RefreshRunningCount(ctx context.Context, nodes []*model.Node) {
-	m.nodeRunning = make(map[string]int)
+	running := make(map[string]int, len(nodes))
 
 	for _, node := range nodes {
 		runs, err := m.store.ListRunsByNode(ctx, node.ID)
 		if err != nil {
-			log.Printf("[node.manager] list runs for node %s failed: %v", node.ID, err)
+			slog.ErrorContext(ctx, "node.manager.list_runs.failed", "node_id", node.ID, "error", err)
 			continue
 		}
-		m.nodeRunning[node.ID] = len(runs)
+		// 暂停中的 Run 不占用执行槽位
+		count := 0
+		for _, r := range runs {
+			if r.Status != model.RunStatusPaused {
+				count++
+			}
+		}
+		running[node.ID] = count
 	}
+
+	m.mu.Lock()
+	m.nodeRunning = running
+	m.mu.Unlock()
 }
 
 // GetNodeRunning 获取节点运行任务计数
 func (m *Manager) GetNodeRunning() map[string]int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	result := make(map[string]int, len(m.nodeRunning))
 	for k, v := range m.nodeRunning {
 		result[k] = v
@@ -70,6 +85,8 @@ func (m *Manager) GetNodeRunning() map[string]int {
 
 // IncrementRunning 增加节点运行任务计数
 func (m *Manager) IncrementRunning(nodeID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
 	m.nodeRunning[nodeID]++
 }
 
@@ -81,7 +98,7 @@ func (m *Manager) ResolvePreferredNodeID(ctx context.Context, taskID string, sna
 	if instanceID == "" && taskID != "" {
 		task, err := m.store.GetTask(ctx, taskID)
 		if err != nil {
-			log.Printf("[node.manager] GetTask error: %v", err)
+			slog.ErrorContext(ctx, "node.manager", "op", "GetTask", "error", err)
 		} else if task != nil && task.AgentID != nil && *task.AgentID != "" {
 			instanceID = *task.AgentID
 		}
@@ -90,7 +107,7 @@ func (m *Manager) ResolvePreferredNodeID(ctx context.Context, taskID string, sna
 	if instanceID != "" {
 		inst, err := m.store.GetAgentInstance(ctx, instanceID)
 		if err != nil {
-			log.Printf("[node.manager] GetAgentInstance error: %v", err)
+			slog.ErrorContext(ctx, "node.manager", "op", "GetAgentInstance", "error", err)
 		} else if inst != nil && inst.NodeID != nil && *inst.NodeID != "" {
 			return *inst.NodeID
 		}
@@ -103,7 +120,7 @@ func (m *Manager) ResolvePreferredNodeID(ctx context.Context, taskID string, sna
 func (m *Manager) RequeueRunsAssignedToOfflineNodes(ctx context.Context, onlineIDs map[string]struct{}, threshold time.Duration) {
 	runs, err := m.store.ListRunningRuns(ctx, 200)
 	if err != nil {
-		log.Printf("[node.manager] ListRunningRuns error: %v", err)
+		slog.ErrorContext(ctx, "node.manager", "op", "ListRunningRuns", "error", err)
 		return
 	}
 
@@ -123,7 +140,7 @@ func (m *Manager) RequeueRunsAssignedToOfflineNodes(ctx context.Context, onlineI
 
 		cnt, err := m.store.CountEventsByRun(ctx, run.ID)
 		if err != nil {
-			log.Printf("[node.manager] CountEventsByRun error (run=%s): %v", run.ID, err)
+			slog.ErrorContext(ctx, "node.manager.requeue.failed", "run_id", run.ID, "op", "CountEventsByRun", "error", err)
 			continue
 		}
 		if cnt > 0 {
@@ -132,9 +149,9 @@ func (m *Manager) RequeueRunsAssignedToOfflineNodes(ctx context.Context, onlineI
 		}
 
 		if err := m.store.ResetRunToQueued(ctx, run.ID); err != nil {
-			log.Printf("[node.manager] ResetRunToQueued error (run=%s): %v", run.ID, err)
+			slog.ErrorContext(ctx, "node.manager.requeue.failed", "run_id", run.ID, "op", "ResetRunToQueued", "error", err)
 			continue
 		}
-		log.Printf("[node.manager] requeued run %s (offline node %s)", run.ID, *run.NodeID)
+		slog.InfoContext(ctx, "node.manager.requeued", "run_id", run.ID, "node_id", *run.NodeID)
 	}
 }
@@ -0,0 +1,91 @@
+// Package node 节点系统资源指标
+//
+// 节点心跳在 capacity.metrics（v2 增量心跳为 metrics）中上报 CPU、内存、磁盘与负载采样，API Server 将其追加到
+// Redis 中按节点保存的滚动窗口（node_metrics:{node_id}），供 least_loaded 调度策略与
+// GET /api/v1/nodes/{id}/metrics 使用。未配置 Redis 时只保留心跳中的最新一次采样。
+package node
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/shared/cache"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// MetricsRetention 节点指标滚动窗口的保留时长
+	MetricsRetention = 15 * time.Minute
+	// defaultMetricsWindow 查询指标时默认的统计窗口
+	defaultMetricsWindow = 5 * time.Minute
+)
+
+// MetricsResponse 节点指标查询结果
+type MetricsResponse struct {
+	NodeID      string               `json:"node_id"`
+	Window      string               `json:"window"`
+	Latest      *model.NodeMetrics   `json:"latest,omitempty"`  // 最近一次心跳上报的采样
+	Average     *model.NodeMetrics   `json:"average,omitempty"` // 窗口内采样的平均值
+	Utilization *float64             `json:"utilization,omitempty"`
+	Samples     []*model.NodeMetrics `json:"samples"`
+}
+
+// SetMetricsCache 设置节点指标滚动窗口的存储（nil 时不保存历史采样）
+func (h *Handler) SetMetricsCache(c cache.NodeMetricsCache) {
+	h.metrics = c
+}
+
+// recordMetrics 将心跳上报的采样追加到节点的指标窗口（sample 为 nil 时无操作）
+func (h *Handler) recordMetrics(ctx context.Context, nodeID string, sample *model.NodeMetrics) {
+	if h.metrics == nil || sample == nil {
+		return
+	}
+	if sample.CollectedAt.IsZero() {
+		sample.CollectedAt = time.Now()
+	}
+	if err := h.metrics.AppendNodeMetrics(ctx, nodeID, sample, MetricsRetention); err != nil {
+		slog.WarnContext(ctx, "node.heartbeat", "detail", "failed to append metrics", "node", nodeID, "err", err)
+	}
+}
+
+// GetMetrics 查询节点指标窗口
+// GET /api/v1/nodes/{id}/metrics?window=5m
+func (h *Handler) GetMetrics(w http.ResponseWriter, r *http.Request) {
+	window := defaultMetricsWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid window")
+			return
+		}
+		window = min(d, MetricsRetention)
+	}
+
+	n, ok := h.loadNode(w, r)
+	if !ok {
+		return
+	}
+
+	resp := MetricsResponse{NodeID: n.ID, Window: window.String(), Latest: GetNodeMetrics(n), Samples: []*model.NodeMetrics{}}
+	if h.metrics != nil {
+		samples, err := h.metrics.ListNodeMetrics(r.Context(), n.ID, time.Now().Add(-window))
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to list metrics")
+			return
+		}
+		if samples != nil {
+			resp.Samples = samples
+		}
+	}
+	resp.Average = model.AverageNodeMetrics(resp.Samples)
+	if resp.Average == nil {
+		resp.Average = resp.Latest
+	}
+	if resp.Average != nil {
+		u := resp.Average.Utilization()
+		resp.Utilization = &u
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
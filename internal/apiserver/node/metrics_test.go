@@ -0,0 +1,84 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memMetricsCache 内存中的节点指标窗口
+type memMetricsCache struct {
+	samples map[string][]*model.NodeMetrics
+}
+
+func (m *memMetricsCache) AppendNodeMetrics(_ context.Context, nodeID string, sample *model.NodeMetrics, _ time.Duration) error {
+	m.samples[nodeID] = append(m.samples[nodeID], sample)
+	return nil
+}
+
+func (m *memMetricsCache) ListNodeMetrics(_ context.Context, nodeID string, since time.Time) ([]*model.NodeMetrics, error) {
+	var out []*model.NodeMetrics
+	for _, s := range m.samples[nodeID] {
+		if !s.CollectedAt.Before(since) {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func TestHandler_HeartbeatMetrics(t *testing.T) {
+	metrics := &memMetricsCache{samples: map[string][]*model.NodeMetrics{}}
+	h := NewHandler(newMockStore())
+	h.SetMetricsCache(metrics)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	for _, cpu := range []float64{20, 60} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"node_id":  "node-1",
+			"status":   "online",
+			"capacity": map[string]interface{}{"max_concurrent": 2, "metrics": model.NodeMetrics{CPUPercent: cpu, MemoryPercent: 10, CPUs: 4}},
+		})
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", bytes.NewReader(body)))
+		if w.Code != http.StatusOK {
+			t.Fatalf("heartbeat status = %d", w.Code)
+		}
+	}
+	if got := len(metrics.samples["node-1"]); got != 2 {
+		t.Fatalf("samples = %d, want 2", got)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/nodes/node-1/metrics?window=1m", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("metrics status = %d: %s", w.Code, w.Body.String())
+	}
+	var resp MetricsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Samples) != 2 || resp.Latest == nil || resp.Latest.CPUPercent != 60 {
+		t.Errorf("samples=%d latest=%+v", len(resp.Samples), resp.Latest)
+	}
+	if resp.Average == nil || resp.Average.CPUPercent != 40 || resp.Utilization == nil || *resp.Utilization != 40 {
+		t.Errorf("average=%+v utilization=%v", resp.Average, resp.Utilization)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/nodes/node-1/metrics?window=abc": http.StatusBadRequest,
+		"/api/v1/nodes/node-x/metrics":            http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != want {
+			t.Errorf("%s: status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
@@ -0,0 +1,277 @@
+// Package node 节点执行槽位占用
+//
+// 每次心跳将节点上报的 running_runs 与 DB 中分配给该节点的活跃 Run 比对，
+// 在内存中维护每个节点的槽位占用（哪个 Run 占用哪个槽位、自何时起），
+// 供集群占用看板直接读取或通过 SSE 订阅变化，无需额外查询数据库。
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// 槽位中 Run 的状态
+const (
+	SlotStateRunning    = "running"    // 节点正在执行，DB 中为 running
+	SlotStateStarting   = "starting"   // 节点已领取，DB 中尚为 assigned（未上报首个事件）
+	SlotStateCancelling = "cancelling" // 节点仍在执行，但 DB 中已不再活跃（等待取消指令生效）
+	SlotStatePaused     = "paused"     // 已被用户暂停，不计入已占用槽位
+)
+
+// occupancyResyncInterval SSE 推送全量快照的间隔（同时刷新节点在线状态并保持连接）
+const occupancyResyncInterval = 30 * time.Second
+
+// SlotOccupancy 单个执行槽位的占用
+type SlotOccupancy struct {
+	Slot   int       `json:"slot"`              // 槽位序号（从 0 开始，Run 在节点上执行期间保持不变）
+	RunID  string    `json:"run_id"`            // 占用槽位的 Run
+	TaskID string    `json:"task_id,omitempty"` // 所属任务（DB 中已不再活跃的 Run 为空）
+	State  string    `json:"state"`             // running / starting / cancelling / paused
+	Since  time.Time `json:"since"`             // 占用开始时间（Run 的 started_at，未开始时为首次上报时间）
+}
+
+// NodeOccupancy 节点的槽位占用
+type NodeOccupancy struct {
+	NodeID     string          `json:"node_id"`
+	Online     bool            `json:"online"`               // 最近一次心跳是否在在线窗口内（快照时计算）
+	Capacity   int             `json:"capacity"`             // 槽位数（心跳 capacity.max_concurrent）
+	Used       int             `json:"used"`                 // 已占用槽位数（不含暂停中的 Run）
+	Slots      []SlotOccupancy `json:"slots"`                // 按槽位序号排列
+	Pending    []string        `json:"pending,omitempty"`    // 已分配给节点、尚未被节点领取的 Run
+	Unreported []string        `json:"unreported,omitempty"` // DB 中为 running / paused、但节点未上报的 Run（可能已丢失执行）
+	UpdatedAt  time.Time       `json:"updated_at"`           // 最近一次心跳时间
+}
+
+// OccupancyTracker 节点槽位占用（内存状态，随心跳更新；多个 API Server 实例各自维护）
+type OccupancyTracker struct {
+	mu    sync.RWMutex
+	nodes map[string]*NodeOccupancy
+	subs  map[chan NodeOccupancy]struct{}
+}
+
+// NewOccupancyTracker 创建槽位占用追踪器
+func NewOccupancyTracker() *OccupancyTracker {
+	return &OccupancyTracker{
+		nodes: make(map[string]*NodeOccupancy),
+		subs:  make(map[chan NodeOccupancy]struct{}),
+	}
+}
+
+// Update 根据一次心跳更新节点的槽位占用，占用有变化时通知订阅者
+//
+// reported 为节点上报的 running_runs，active 为 DB 中分配给该节点的 assigned/running/paused Run。
+// 已占用槽位的 Run 保持原槽位与开始时间，新上报的 Run 占用序号最小的空闲槽位。
+func (t *OccupancyTracker) Update(nodeID string, capacity int, reported []string, active []*model.Run, now time.Time) NodeOccupancy {
+	activeByID := make(map[string]*model.Run, len(active))
+	for _, r := range active {
+		activeByID[r.ID] = r
+	}
+	reportedSet := make(map[string]bool, len(reported))
+	for _, id := range reported {
+		reportedSet[id] = true
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	prev := make(map[string]SlotOccupancy)
+	if old := t.nodes[nodeID]; old != nil {
+		for _, s := range old.Slots {
+			prev[s.RunID] = s
+		}
+	}
+
+	ids := make([]string, 0, len(reportedSet))
+	for id := range reportedSet {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	used := make(map[int]bool)
+	slots := make([]SlotOccupancy, 0, len(ids))
+	var fresh []int // 需要分配槽位的下标
+	for _, id := range ids {
+		slot := SlotOccupancy{RunID: id, State: SlotStateCancelling, Since: now, Slot: -1}
+		if p, ok := prev[id]; ok {
+			slot.Slot, slot.Since = p.Slot, p.Since
+			used[p.Slot] = true
+		}
+		if r := activeByID[id]; r != nil {
+			slot.TaskID = r.TaskID
+			slot.State = SlotStateStarting
+			switch r.Status {
+			case model.RunStatusRunning:
+				slot.State = SlotStateRunning
+			case model.RunStatusPaused:
+				slot.State = SlotStatePaused
+			}
+			if r.StartedAt != nil {
+				slot.Since = *r.StartedAt
+			}
+		}
+		if slot.Slot < 0 {
+			fresh = append(fresh, len(slots))
+		}
+		slots = append(slots, slot)
+	}
+	next := 0
+	for _, i := range fresh {
+		for used[next] {
+			next++
+		}
+		slots[i].Slot = next
+		used[next] = true
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Slot < slots[j].Slot })
+
+	occ := &NodeOccupancy{NodeID: nodeID, Capacity: capacity, Slots: slots, UpdatedAt: now}
+	for _, s := range slots {
+		if s.State != SlotStatePaused {
+			occ.Used++
+		}
+	}
+	for _, r := range active {
+		if reportedSet[r.ID] {
+			continue
+		}
+		if r.Status == model.RunStatusRunning || r.Status == model.RunStatusPaused {
+			occ.Unreported = append(occ.Unreported, r.ID)
+		} else {
+			occ.Pending = append(occ.Pending, r.ID)
+		}
+	}
+
+	old := t.nodes[nodeID]
+	t.nodes[nodeID] = occ
+	snapshot := occ.withOnline(now)
+	if old == nil || !sameOccupancy(old, occ) {
+		for ch := range t.subs {
+			select {
+			case ch <- snapshot:
+			default:
+				// 订阅者处理过慢时丢弃增量，等待下一次全量快照
+			}
+		}
+	}
+	return snapshot
+}
+
+// Remove 移除节点（节点被删除时调用）
+func (t *OccupancyTracker) Remove(nodeID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.nodes, nodeID)
+}
+
+// Snapshot 返回全部节点的槽位占用（按节点 ID 排序）
+func (t *OccupancyTracker) Snapshot(now time.Time) []NodeOccupancy {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	result := make([]NodeOccupancy, 0, len(t.nodes))
+	for _, occ := range t.nodes {
+		result = append(result, occ.withOnline(now))
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].NodeID < result[j].NodeID })
+	return result
+}
+
+// Subscribe 订阅槽位占用变化，返回的函数用于取消订阅
+func (t *OccupancyTracker) Subscribe() (<-chan NodeOccupancy, func()) {
+	ch := make(chan NodeOccupancy, 16)
+	t.mu.Lock()
+	t.subs[ch] = struct{}{}
+	t.mu.Unlock()
+	return ch, func() {
+		t.mu.Lock()
+		delete(t.subs, ch)
+		t.mu.Unlock()
+	}
+}
+
+func (o *NodeOccupancy) withOnline(now time.Time) NodeOccupancy {
+	c := *o
+	c.Online = now.Sub(o.UpdatedAt) <= HeartbeatFreshWindow
+	return c
+}
+
+// sameOccupancy 比较占用内容（忽略心跳时间）
+func sameOccupancy(a, b *NodeOccupancy) bool {
+	return a.Capacity == b.Capacity &&
+		reflect.DeepEqual(a.Slots, b.Slots) &&
+		reflect.DeepEqual(a.Pending, b.Pending) &&
+		reflect.DeepEqual(a.Unreported, b.Unreported)
+}
+
+// ============================================================================
+// HTTP 处理函数
+// ============================================================================
+
+// GetOccupancy 获取全部节点的槽位占用
+// GET /api/v1/nodes/occupancy
+func (h *Handler) GetOccupancy(w http.ResponseWriter, r *http.Request) {
+	nodes := h.occupancy.Snapshot(time.Now())
+	writeJSON(w, http.StatusOK, map[string]interface{}{"nodes": nodes, "count": len(nodes)})
+}
+
+// StreamOccupancy 以 SSE 推送槽位占用
+// GET /api/v1/nodes/occupancy/stream
+//
+// 事件：
+//   - snapshot: 连接建立时及每 30 秒推送全量快照 {"nodes": [...]}
+//   - node: 单个节点的占用发生变化时推送该节点的 NodeOccupancy
+func (h *Handler) StreamOccupancy(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// 长连接不受服务端 WriteTimeout 限制
+	rc.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	updates, unsubscribe := h.occupancy.Subscribe()
+	defer unsubscribe()
+
+	send := func(event string, data interface{}) bool {
+		payload, _ := json.Marshal(data)
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+	sendSnapshot := func() bool {
+		return send("snapshot", map[string]interface{}{"nodes": h.occupancy.Snapshot(time.Now())})
+	}
+
+	if !sendSnapshot() {
+		slog.InfoContext(r.Context(), "node.occupancy.stream", "detail", "flush not supported")
+		return
+	}
+
+	ticker := time.NewTicker(occupancyResyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case occ := <-updates:
+			if !send("node", occ) {
+				return
+			}
+		case <-ticker.C:
+			if !sendSnapshot() {
+				return
+			}
+		}
+	}
+}
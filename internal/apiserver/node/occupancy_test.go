@@ -0,0 +1,162 @@
+package node
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestOccupancyTracker_Update(t *testing.T) {
+	tracker := NewOccupancyTracker()
+	updates, unsubscribe := tracker.Subscribe()
+	defer unsubscribe()
+
+	start := time.Now()
+	started := start.Add(-time.Minute)
+	active := []*model.Run{
+		{ID: "run-b", TaskID: "task-b", Status: model.RunStatusRunning, StartedAt: &started},
+		{ID: "run-c", TaskID: "task-c", Status: model.RunStatusAssigned},
+		{ID: "run-d", TaskID: "task-d", Status: model.RunStatusAssigned},
+		{ID: "run-e", TaskID: "task-e", Status: model.RunStatusRunning},
+	}
+	occ := tracker.Update("node-1", 3, []string{"run-b", "run-c", "run-x"}, active, start)
+
+	if occ.Capacity != 3 || occ.Used != 3 || !occ.Online {
+		t.Fatalf("unexpected occupancy: %+v", occ)
+	}
+	want := map[string]SlotOccupancy{
+		"run-b": {Slot: 0, RunID: "run-b", TaskID: "task-b", State: SlotStateRunning, Since: started},
+		"run-c": {Slot: 1, RunID: "run-c", TaskID: "task-c", State: SlotStateStarting, Since: start},
+		"run-x": {Slot: 2, RunID: "run-x", State: SlotStateCancelling, Since: start},
+	}
+	for _, s := range occ.Slots {
+		if w := want[s.RunID]; s != w {
+			t.Errorf("slot = %+v, 期望 %+v", s, w)
+		}
+	}
+	if len(occ.Pending) != 1 || occ.Pending[0] != "run-d" {
+		t.Errorf("pending = %v", occ.Pending)
+	}
+	if len(occ.Unreported) != 1 || occ.Unreported[0] != "run-e" {
+		t.Errorf("unreported = %v", occ.Unreported)
+	}
+	if len(updates) != 1 {
+		t.Fatalf("首次上报应推送 1 次, got %d", len(updates))
+	}
+	<-updates
+
+	// 占用不变时只刷新心跳时间，不推送
+	tracker.Update("node-1", 3, []string{"run-x", "run-c", "run-b"}, active, start.Add(10*time.Second))
+	if len(updates) != 0 {
+		t.Errorf("占用未变化时不应推送")
+	}
+
+	// run-b 结束后其槽位空出，新领取的 run-d 占用该槽位，其余 Run 保持原槽位
+	later := start.Add(20 * time.Second)
+	occ = tracker.Update("node-1", 3, []string{"run-c", "run-d", "run-x"}, active[1:], later)
+	got := make(map[string]int)
+	for _, s := range occ.Slots {
+		got[s.RunID] = s.Slot
+	}
+	if got["run-c"] != 1 || got["run-x"] != 2 || got["run-d"] != 0 {
+		t.Errorf("slots = %v", got)
+	}
+	if occ.Slots[0].RunID != "run-d" || !occ.Slots[0].Since.Equal(later) {
+		t.Errorf("新占用的槽位 = %+v", occ.Slots[0])
+	}
+	if len(updates) != 1 {
+		t.Errorf("占用变化时应推送")
+	}
+
+	// 心跳超过在线窗口后快照标记为离线；删除节点后不再出现
+	if snap := tracker.Snapshot(later.Add(HeartbeatFreshWindow + time.Second)); len(snap) != 1 || snap[0].Online {
+		t.Errorf("snapshot = %+v", snap)
+	}
+	tracker.Remove("node-1")
+	if snap := tracker.Snapshot(later); len(snap) != 0 {
+		t.Errorf("删除后 snapshot = %+v", snap)
+	}
+}
+
+func TestHandler_Occupancy(t *testing.T) {
+	store := newMockStore()
+	store.runs["node-1"] = []*model.Run{{ID: "run-1", TaskID: "task-1", Status: model.RunStatusRunning}}
+	h := NewHandler(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	heartbeat := func(body string) {
+		resp, err := http.Post(srv.URL+"/api/v1/nodes/heartbeat", "application/json", bytes.NewReader([]byte(body)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+	heartbeat(`{"node_id": "node-1", "capacity": {"max_concurrent": 2}, "running_runs": ["run-1"]}`)
+	// 未上报 running_runs 的节点无法得知占用，不出现在结果中
+	heartbeat(`{"node_id": "node-legacy"}`)
+
+	resp, err := http.Get(srv.URL + "/api/v1/nodes/occupancy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result struct {
+		Nodes []NodeOccupancy `json:"nodes"`
+		Count int             `json:"count"`
+	}
+	json.NewDecoder(resp.Body).Decode(&result)
+	resp.Body.Close()
+	if result.Count != 1 || result.Nodes[0].NodeID != "node-1" || result.Nodes[0].Capacity != 2 ||
+		len(result.Nodes[0].Slots) != 1 || result.Nodes[0].Slots[0].State != SlotStateRunning {
+		t.Fatalf("unexpected occupancy: %+v", result)
+	}
+
+	// SSE：连接时推送全量快照，占用变化时推送节点事件
+	stream, err := http.Get(srv.URL + "/api/v1/nodes/occupancy/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	if ct := stream.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %s", ct)
+	}
+	reader := bufio.NewReader(stream.Body)
+	readEvent := func() (string, string) {
+		var event, data string
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "":
+				return event, data
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				data = strings.TrimPrefix(line, "data: ")
+			}
+		}
+	}
+
+	if event, data := readEvent(); event != "snapshot" || !strings.Contains(data, `"run_id":"run-1"`) {
+		t.Fatalf("首个事件 = %s %s", event, data)
+	}
+	heartbeat(`{"node_id": "node-1", "capacity": {"max_concurrent": 2}, "running_runs": []}`)
+	event, data := readEvent()
+	var occ NodeOccupancy
+	json.Unmarshal([]byte(data), &occ)
+	if event != "node" || occ.NodeID != "node-1" || occ.Used != 0 || len(occ.Unreported) != 1 {
+		t.Errorf("节点事件 = %s %s", event, data)
+	}
+}
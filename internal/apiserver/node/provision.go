@@ -3,7 +3,7 @@ package node
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -90,13 +90,13 @@ func (p *Provisioner) execute(prov *model.NodeProvision, req ProvisionRequest) {
 		prov.ErrorMessage = errMsg
 		prov.UpdatedAt = time.Now()
 		if err := p.store.UpdateNodeProvision(ctx, prov); err != nil {
-			log.Printf("[provision] failed to update status: %v", err)
+			slog.ErrorContext(ctx, "provision", "detail", "failed to update status", "error", err)
 		}
 	}
 
 	// 1. SSH 连接
 	updateStatus(model.NodeProvisionStatusConnecting, "")
-	log.Printf("[provision] %s: connecting to %s@%s:%d", prov.ID, req.SSHUser, req.Host, req.Port)
+	slog.InfoContext(ctx, "provision.connecting", "provision_id", prov.ID, "user", req.SSHUser, "host", req.Host, "port", req.Port)
 
 	client, err := p.sshConnect(req)
 	if err != nil {
@@ -112,7 +112,7 @@ func (p *Provisioner) execute(prov *model.NodeProvision, req ProvisionRequest) {
 		return
 	}
 	arch = strings.TrimSpace(arch)
-	log.Printf("[provision] %s: detected arch=%s", prov.ID, arch)
+	slog.InfoContext(ctx, "provision.arch", "provision_id", prov.ID, "arch", arch)
 
 	// 3. 下载 deb 包
 	updateStatus(model.NodeProvisionStatusDownloading, "")
@@ -126,7 +126,7 @@ func (p *Provisioner) execute(prov *model.NodeProvision, req ProvisionRequest) {
 		updateStatus(model.NodeProvisionStatusFailed, fmt.Sprintf("download failed: %v", err))
 		return
 	}
-	log.Printf("[provision] %s: downloaded %s", prov.ID, debFile)
+	slog.InfoContext(ctx, "provision.downloaded", "provision_id", prov.ID, "file", debFile)
 
 	// 4. 安装 deb
 	updateStatus(model.NodeProvisionStatusInstalling, "")
@@ -138,7 +138,7 @@ func (p *Provisioner) execute(prov *model.NodeProvision, req ProvisionRequest) {
 		updateStatus(model.NodeProvisionStatusFailed, fmt.Sprintf("install failed: %v", err))
 		return
 	}
-	log.Printf("[provision] %s: installed deb", prov.ID)
+	slog.InfoContext(ctx, "provision.installed", "provision_id", prov.ID)
 
 	// 5. 写入配置文件
 	updateStatus(model.NodeProvisionStatusConfiguring, "")
@@ -187,12 +187,12 @@ tls:
 		updateStatus(model.NodeProvisionStatusFailed, fmt.Sprintf("service start failed: %v", err))
 		return
 	}
-	log.Printf("[provision] %s: service started", prov.ID)
+	slog.InfoContext(ctx, "provision.started", "provision_id", prov.ID)
 
 	// 7. 等待心跳验证
 	if p.waitForHeartbeat(ctx, prov.NodeID, 30*time.Second) {
 		updateStatus(model.NodeProvisionStatusCompleted, "")
-		log.Printf("[provision] %s: node %s is online!", prov.ID, prov.NodeID)
+		slog.InfoContext(ctx, "provision.online", "provision_id", prov.ID, "node_id", prov.NodeID)
 		// 设置节点显示名称
 		if prov.DisplayName != "" {
 			if node, err := p.nodeStore.GetNode(ctx, prov.NodeID); err == nil && node != nil {
@@ -203,7 +203,7 @@ tls:
 		}
 	} else {
 		updateStatus(model.NodeProvisionStatusCompleted, "service started but heartbeat not yet received")
-		log.Printf("[provision] %s: service started, heartbeat pending", prov.ID)
+		slog.WarnContext(ctx, "provision.heartbeat_pending", "provision_id", prov.ID)
 	}
 
 	// 清理下载文件
@@ -0,0 +1,89 @@
+// Package node 节点污点
+//
+// 管理员通过 PATCH /api/v1/nodes/{id}/taints 为节点设置污点（如 dedicated=ml:NoSchedule），
+// 为特定任务预留节点而不必借用标签。污点保存在节点记录中（心跳不覆盖），
+// 调度器只将声明了对应容忍（task.scheduling.tolerations）的任务调度到带污点的节点。
+package node
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"agents-admin/internal/shared/model"
+)
+
+// TaintsPatch 节点污点的增删
+//
+// 字段说明：
+//   - Add：添加的污点，与已有污点的键和效果相同时替换其值；效果为空时为 NoSchedule
+//   - Remove：移除的污点键（该键的所有效果）
+type TaintsPatch struct {
+	Add    []model.NodeTaint `json:"add,omitempty"`
+	Remove []string          `json:"remove,omitempty"`
+}
+
+// TaintsResponse 节点污点
+type TaintsResponse struct {
+	NodeID string            `json:"node_id"`
+	Taints []model.NodeTaint `json:"taints"`
+}
+
+// UpdateTaints 增删节点污点
+// PATCH /api/v1/nodes/{id}/taints
+func (h *Handler) UpdateTaints(w http.ResponseWriter, r *http.Request) {
+	n, ok := h.loadNode(w, r)
+	if !ok {
+		return
+	}
+	var patch TaintsPatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	taints, err := applyTaintsPatch(n.Taints, &patch)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.store.SetNodeTaints(r.Context(), n.ID, taints); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update taints")
+		return
+	}
+	slog.InfoContext(r.Context(), "node.taints.updated", "node_id", n.ID, "taints", len(taints))
+	writeJSON(w, http.StatusOK, TaintsResponse{NodeID: n.ID, Taints: taints})
+}
+
+// applyTaintsPatch 在已有污点的副本上应用增删并校验
+func applyTaintsPatch(current []model.NodeTaint, patch *TaintsPatch) ([]model.NodeTaint, error) {
+	removed := make(map[string]bool, len(patch.Remove))
+	for _, key := range patch.Remove {
+		removed[key] = true
+	}
+	taints := make([]model.NodeTaint, 0, len(current)+len(patch.Add))
+	for _, t := range current {
+		if !removed[t.Key] {
+			taints = append(taints, t)
+		}
+	}
+	for _, t := range patch.Add {
+		if t.Effect == "" {
+			t.Effect = model.TaintEffectNoSchedule
+		}
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		replaced := false
+		for i := range taints {
+			if taints[i].Key == t.Key && taints[i].Effect == t.Effect {
+				taints[i] = t
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			taints = append(taints, t)
+		}
+	}
+	return taints, nil
+}
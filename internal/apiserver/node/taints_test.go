@@ -0,0 +1,71 @@
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestHandler_UpdateTaints(t *testing.T) {
+	store := newMockStore()
+	store.nodes["node-1"] = &model.Node{ID: "node-1", Status: model.NodeStatusOnline}
+	h := NewHandler(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	patch := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/nodes/node-1/taints", strings.NewReader(body)))
+		return w
+	}
+
+	w := patch(`{"add":[{"key":"dedicated","value":"ml"},{"key":"gpu","effect":"NoSchedule"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("patch status = %d body=%s", w.Code, w.Body.String())
+	}
+	var resp TaintsResponse
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Taints) != 2 || resp.Taints[0].String() != "dedicated=ml:NoSchedule" {
+		t.Fatalf("taints = %+v", resp.Taints)
+	}
+
+	// 同键同效果替换值，remove 按键移除
+	if w := patch(`{"add":[{"key":"dedicated","value":"infra"}],"remove":["gpu"]}`); w.Code != http.StatusOK {
+		t.Fatalf("patch status = %d body=%s", w.Code, w.Body.String())
+	}
+	taints := store.nodes["node-1"].Taints
+	if len(taints) != 1 || taints[0].Value != "infra" {
+		t.Fatalf("taints = %+v", taints)
+	}
+
+	// 节点详情返回污点
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/nodes/node-1", nil))
+	if !strings.Contains(w.Body.String(), `"taints":[{"key":"dedicated","value":"infra","effect":"NoSchedule"}]`) {
+		t.Errorf("node response = %s", w.Body.String())
+	}
+
+	for _, body := range []string{
+		`{"add":[{"value":"ml"}]}`,
+		`{"add":[{"key":"dedicated","effect":"NoExecute"}]}`,
+		`not json`,
+	} {
+		if w := patch(body); w.Code != http.StatusBadRequest {
+			t.Errorf("patch %s status = %d, want 400", body, w.Code)
+		}
+	}
+
+	if w := patch(`{"remove":["dedicated"]}`); w.Code != http.StatusOK || len(store.nodes["node-1"].Taints) != 0 {
+		t.Errorf("remove status = %d taints = %+v", w.Code, store.nodes["node-1"].Taints)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/nodes/missing/taints", strings.NewReader(`{}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing node status = %d", w.Code)
+	}
+}
@@ -113,6 +113,22 @@ func GetNodeMaxConcurrent(node *model.Node) int {
 	return 1
 }
 
+// GetNodeAdapters 获取节点心跳上报的适配器版本与能力（capacity.adapters）
+//
+// 旧版本节点不上报适配器信息，此时 ok 为 false。
+func GetNodeAdapters(node *model.Node) (adapters []model.AdapterInfo, ok bool) {
+	if len(node.Capacity) == 0 {
+		return nil, false
+	}
+	var capacity struct {
+		Adapters *[]model.AdapterInfo `json:"adapters"`
+	}
+	if err := json.Unmarshal(node.Capacity, &capacity); err != nil || capacity.Adapters == nil {
+		return nil, false
+	}
+	return *capacity.Adapters, true
+}
+
 // ExtractAgentIDs 从 snapshot 中提取 agent ID
 func ExtractAgentIDs(snapshot json.RawMessage) (instanceID string, accountID string) {
 	if len(snapshot) == 0 {
@@ -142,3 +158,51 @@ func ExtractAgentIDs(snapshot json.RawMessage) (instanceID string, accountID str
 	}
 	return instanceID, accountID
 }
+
+// GetNodePools 获取节点心跳上报的预热实例池状态（capacity.pools），未配置实例池时为空
+func GetNodePools(node *model.Node) []model.InstancePoolStatus {
+	if len(node.Capacity) == 0 {
+		return nil
+	}
+	var capacity struct {
+		Pools []model.InstancePoolStatus `json:"pools"`
+	}
+	if err := json.Unmarshal(node.Capacity, &capacity); err != nil {
+		return nil
+	}
+	return capacity.Pools
+}
+
+// GetNodeMetrics 获取节点心跳上报的最新系统资源采样（capacity.metrics），旧版本节点不上报时为 nil
+func GetNodeMetrics(node *model.Node) *model.NodeMetrics {
+	if len(node.Capacity) == 0 {
+		return nil
+	}
+	var capacity struct {
+		Metrics *model.NodeMetrics `json:"metrics"`
+	}
+	if err := json.Unmarshal(node.Capacity, &capacity); err != nil {
+		return nil
+	}
+	return capacity.Metrics
+}
+
+// GetNodeConcurrency 获取节点心跳上报的生效并发配置（capacity.max_concurrent 等），未上报 max_concurrent 时为 nil
+func GetNodeConcurrency(node *model.Node) *model.NodeConcurrency {
+	if len(node.Capacity) == 0 {
+		return nil
+	}
+	var c model.NodeConcurrency
+	if err := json.Unmarshal(node.Capacity, &c); err != nil || c.MaxConcurrent <= 0 {
+		return nil
+	}
+	return &c
+}
+
+// GetNodeReservedSlots 获取节点为 high 优先级 Run 预留的槽位数（capacity.reserved_high_priority）
+func GetNodeReservedSlots(node *model.Node) int {
+	if c := GetNodeConcurrency(node); c != nil && c.ReservedHighPriority > 0 {
+		return c.ReservedHighPriority
+	}
+	return 0
+}
@@ -0,0 +1,258 @@
+// Package nodestate 节点期望状态同步接口
+//
+// API Server 按节点计算期望状态文档（Agent 实例、认证会话、终端会话），Node Manager 拉取后在本地
+// 对齐（创建/启动/停止/清理容器、启动/取消认证任务、启动/关闭终端），再上报观测到的状态。
+// 观测结果以节点行动时文档中的状态为前提做条件更新，节点处理期间被用户修改的记录不会被覆盖，
+// 下一轮同步按新的期望状态继续对齐。
+package nodestate
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Store 期望状态同步需要的存储接口
+type Store interface {
+	ListAgentInstancesByNode(ctx context.Context, nodeID string) ([]*model.Instance, error)
+	TransitionAgentInstance(ctx context.Context, id string, from, to model.InstanceStatus, containerName *string) (bool, error)
+	ListTerminalSessionsByNode(ctx context.Context, nodeID string) ([]*model.TerminalSession, error)
+	TransitionTerminalSession(ctx context.Context, id string, from, to model.TerminalSessionStatus, port *int, url *string) (bool, error)
+	ListActionsByNode(ctx context.Context, nodeID string, status string) ([]*model.Action, error)
+}
+
+// Handler 节点期望状态 HTTP 处理器
+type Handler struct {
+	store Store
+}
+
+// NewHandler 创建节点期望状态处理器
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes 注册节点期望状态相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/nodes/{id}/desired-state", h.GetDesiredState)
+	mux.HandleFunc("POST /api/v1/nodes/{id}/observed-state", h.ReportObservedState)
+}
+
+// GetDesiredState 获取节点期望状态文档（NodeManager 调用）
+// GET /api/v1/nodes/{id}/desired-state
+//
+// 响应: model.NodeDesiredState，revision 为文档内容摘要
+func (h *Handler) GetDesiredState(w http.ResponseWriter, r *http.Request) {
+	nodeID := r.PathValue("id")
+	doc, err := h.desiredState(r.Context(), nodeID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "nodestate.desired.failed", "node_id", nodeID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to build desired state")
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+// desiredState 计算节点期望状态
+func (h *Handler) desiredState(ctx context.Context, nodeID string) (*model.NodeDesiredState, error) {
+	doc := &model.NodeDesiredState{
+		NodeID:       nodeID,
+		Instances:    []model.DesiredInstance{},
+		AuthSessions: []*model.Action{},
+		Terminals:    []model.DesiredTerminal{},
+	}
+
+	instances, err := h.store.ListAgentInstancesByNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, inst := range instances {
+		d := model.DesiredInstance{
+			ID:          inst.ID,
+			Name:        inst.Name,
+			AccountID:   inst.AccountID,
+			AgentTypeID: inst.AgentTypeID,
+			Status:      inst.Status,
+			State:       model.DesiredInstanceState(inst.Status),
+		}
+		if inst.ContainerName != nil {
+			d.ContainerName = *inst.ContainerName
+		}
+		doc.Instances = append(doc.Instances, d)
+	}
+	sort.Slice(doc.Instances, func(i, j int) bool { return doc.Instances[i].ID < doc.Instances[j].ID })
+
+	for _, status := range []model.ActionStatus{model.ActionStatusAssigned, model.ActionStatusRunning, model.ActionStatusWaiting} {
+		actions, err := h.store.ListActionsByNode(ctx, nodeID, string(status))
+		if err != nil {
+			return nil, err
+		}
+		doc.AuthSessions = append(doc.AuthSessions, actions...)
+	}
+	sort.Slice(doc.AuthSessions, func(i, j int) bool { return doc.AuthSessions[i].ID < doc.AuthSessions[j].ID })
+
+	sessions, err := h.store.ListTerminalSessionsByNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range sessions {
+		switch s.Status {
+		case model.TerminalStatusPending, model.TerminalStatusStarting, model.TerminalStatusRunning:
+		default:
+			continue
+		}
+		doc.Terminals = append(doc.Terminals, model.DesiredTerminal{
+			ID:            s.ID,
+			InstanceID:    s.InstanceID,
+			ContainerName: s.ContainerName,
+			Status:        s.Status,
+			CreatedAt:     s.CreatedAt,
+		})
+	}
+	sort.Slice(doc.Terminals, func(i, j int) bool {
+		if !doc.Terminals[i].CreatedAt.Equal(doc.Terminals[j].CreatedAt) {
+			return doc.Terminals[i].CreatedAt.Before(doc.Terminals[j].CreatedAt)
+		}
+		return doc.Terminals[i].ID < doc.Terminals[j].ID
+	})
+
+	doc.Revision = revision(doc)
+	doc.GeneratedAt = time.Now()
+	return doc, nil
+}
+
+// revision 计算文档内容摘要（不含生成时间）
+func revision(doc *model.NodeDesiredState) string {
+	data, _ := json.Marshal(struct {
+		Instances    []model.DesiredInstance `json:"instances"`
+		AuthSessions []*model.Action         `json:"auth_sessions"`
+		Terminals    []model.DesiredTerminal `json:"terminals"`
+	}{doc.Instances, doc.AuthSessions, doc.Terminals})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ReportObservedState 上报节点观测状态（NodeManager 调用）
+// POST /api/v1/nodes/{id}/observed-state
+//
+// 请求: model.NodeObservedState
+// 响应: {"applied": 2, "skipped": 1}，skipped 为不属于该节点或已被修改（状态不再是节点行动时的状态）的记录
+func (h *Handler) ReportObservedState(w http.ResponseWriter, r *http.Request) {
+	nodeID := r.PathValue("id")
+	var req model.NodeObservedState
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	applied, skipped, err := h.applyObserved(r.Context(), nodeID, &req)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "nodestate.observed.failed", "node_id", nodeID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to apply observed state")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]int{"applied": applied, "skipped": skipped})
+}
+
+// applyObserved 以条件更新应用观测结果
+func (h *Handler) applyObserved(ctx context.Context, nodeID string, req *model.NodeObservedState) (applied, skipped int, err error) {
+	if len(req.Instances) > 0 {
+		instances, err := h.store.ListAgentInstancesByNode(ctx, nodeID)
+		if err != nil {
+			return 0, 0, err
+		}
+		owned := make(map[string]bool, len(instances))
+		for _, inst := range instances {
+			owned[inst.ID] = true
+		}
+		for _, obs := range req.Instances {
+			next, ok := model.ObservedInstanceStatus(obs.Status, obs.State)
+			if !ok {
+				continue
+			}
+			if !owned[obs.ID] {
+				skipped++
+				continue
+			}
+			var containerName *string
+			if obs.ContainerName != "" {
+				containerName = &obs.ContainerName
+			}
+			updated, err := h.store.TransitionAgentInstance(ctx, obs.ID, obs.Status, next, containerName)
+			if err != nil {
+				return applied, skipped, err
+			}
+			if !updated {
+				slog.InfoContext(ctx, "nodestate.instance.stale", "instance_id", obs.ID, "acted_on", obs.Status, "skipped", next)
+				skipped++
+				continue
+			}
+			if obs.Error != "" {
+				slog.InfoContext(ctx, "nodestate.instance.transition", "instance_id", obs.ID, "node_id", nodeID,
+					"from", obs.Status, "to", next, "detail", obs.Error)
+			} else {
+				slog.InfoContext(ctx, "nodestate.instance.transition", "instance_id", obs.ID, "node_id", nodeID, "from", obs.Status, "to", next)
+			}
+			applied++
+		}
+	}
+
+	if len(req.Terminals) > 0 {
+		sessions, err := h.store.ListTerminalSessionsByNode(ctx, nodeID)
+		if err != nil {
+			return applied, skipped, err
+		}
+		owned := make(map[string]bool, len(sessions))
+		for _, s := range sessions {
+			owned[s.ID] = true
+		}
+		for _, obs := range req.Terminals {
+			if obs.State == obs.Status {
+				continue
+			}
+			switch obs.State {
+			case model.TerminalStatusRunning, model.TerminalStatusClosed, model.TerminalStatusError:
+			default:
+				skipped++
+				continue
+			}
+			if !owned[obs.ID] {
+				skipped++
+				continue
+			}
+			url := obs.URL
+			if obs.State == model.TerminalStatusError && url == nil && obs.Error != "" {
+				// 与 PATCH 回调一致：错误信息写入 url 字段供前端展示
+				url = &obs.Error
+			}
+			updated, err := h.store.TransitionTerminalSession(ctx, obs.ID, obs.Status, obs.State, obs.Port, url)
+			if err != nil {
+				return applied, skipped, err
+			}
+			if !updated {
+				slog.InfoContext(ctx, "nodestate.terminal.stale", "session_id", obs.ID, "acted_on", obs.Status, "skipped", obs.State)
+				skipped++
+				continue
+			}
+			slog.InfoContext(ctx, "nodestate.terminal.transition", "session_id", obs.ID, "node_id", nodeID, "from", obs.Status, "to", obs.State)
+			applied++
+		}
+	}
+	return applied, skipped, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
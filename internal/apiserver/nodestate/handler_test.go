@@ -0,0 +1,228 @@
+package nodestate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+type mockStore struct {
+	instances map[string]*model.Instance
+	sessions  map[string]*model.TerminalSession
+	actions   map[string][]*model.Action // nodeID -> actions
+}
+
+func (m *mockStore) ListAgentInstancesByNode(_ context.Context, nodeID string) ([]*model.Instance, error) {
+	var out []*model.Instance
+	for _, inst := range m.instances {
+		if inst.NodeID != nil && *inst.NodeID == nodeID {
+			out = append(out, inst)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) TransitionAgentInstance(_ context.Context, id string, from, to model.InstanceStatus, containerName *string) (bool, error) {
+	inst := m.instances[id]
+	if inst == nil || inst.Status != from {
+		return false, nil
+	}
+	inst.Status = to
+	if containerName != nil {
+		inst.ContainerName = containerName
+	}
+	return true, nil
+}
+
+func (m *mockStore) ListTerminalSessionsByNode(_ context.Context, nodeID string) ([]*model.TerminalSession, error) {
+	var out []*model.TerminalSession
+	for _, s := range m.sessions {
+		if s.NodeID != nil && *s.NodeID == nodeID {
+			out = append(out, s)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) TransitionTerminalSession(_ context.Context, id string, from, to model.TerminalSessionStatus, port *int, url *string) (bool, error) {
+	s := m.sessions[id]
+	if s == nil || s.Status != from {
+		return false, nil
+	}
+	s.Status = to
+	if port != nil {
+		s.Port = port
+	}
+	if url != nil {
+		s.URL = url
+	}
+	return true, nil
+}
+
+func (m *mockStore) ListActionsByNode(_ context.Context, nodeID string, status string) ([]*model.Action, error) {
+	var out []*model.Action
+	for _, a := range m.actions[nodeID] {
+		if string(a.Status) == status {
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+func strPtr(s string) *string { return &s }
+
+func newTestStore() *mockStore {
+	now := time.Now()
+	return &mockStore{
+		instances: map[string]*model.Instance{
+			"inst-1": {ID: "inst-1", NodeID: strPtr("node-1"), Status: model.InstanceStatusPending},
+			"inst-2": {ID: "inst-2", NodeID: strPtr("node-1"), Status: model.InstanceStatusStopping, ContainerName: strPtr("c2")},
+			"inst-3": {ID: "inst-3", NodeID: strPtr("node-1"), Status: model.InstanceStatusError},
+			"inst-9": {ID: "inst-9", NodeID: strPtr("node-2"), Status: model.InstanceStatusPending},
+		},
+		sessions: map[string]*model.TerminalSession{
+			"term-new":  {ID: "term-new", NodeID: strPtr("node-1"), ContainerName: "c1", Status: model.TerminalStatusPending, CreatedAt: now},
+			"term-old":  {ID: "term-old", NodeID: strPtr("node-1"), ContainerName: "c1", Status: model.TerminalStatusRunning, CreatedAt: now.Add(-time.Minute)},
+			"term-gone": {ID: "term-gone", NodeID: strPtr("node-1"), ContainerName: "c1", Status: model.TerminalStatusClosed, CreatedAt: now.Add(-time.Hour)},
+		},
+		actions: map[string][]*model.Action{"node-1": {
+			{ID: "act-1", Status: model.ActionStatusAssigned},
+			{ID: "act-2", Status: model.ActionStatusSuccess},
+		}},
+	}
+}
+
+func TestGetDesiredState(t *testing.T) {
+	store := newTestStore()
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	get := func() model.NodeDesiredState {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/nodes/node-1/desired-state", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+		}
+		var doc model.NodeDesiredState
+		if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return doc
+	}
+
+	doc := get()
+	wantStates := map[string]string{"inst-1": model.DesiredRunning, "inst-2": model.DesiredStopped, "inst-3": ""}
+	if len(doc.Instances) != len(wantStates) {
+		t.Fatalf("instances = %d, want %d", len(doc.Instances), len(wantStates))
+	}
+	for _, d := range doc.Instances {
+		if d.State != wantStates[d.ID] {
+			t.Errorf("instance %s state = %q, want %q", d.ID, d.State, wantStates[d.ID])
+		}
+	}
+	if len(doc.AuthSessions) != 1 || doc.AuthSessions[0].ID != "act-1" {
+		t.Errorf("auth sessions = %+v, want only act-1", doc.AuthSessions)
+	}
+	if len(doc.Terminals) != 2 || doc.Terminals[0].ID != "term-old" || doc.Terminals[1].ID != "term-new" {
+		t.Errorf("terminals = %+v, want [term-old term-new]", doc.Terminals)
+	}
+	if doc.Revision == "" {
+		t.Fatal("revision is empty")
+	}
+
+	if again := get(); again.Revision != doc.Revision {
+		t.Errorf("revision changed without edits: %s -> %s", doc.Revision, again.Revision)
+	}
+	store.instances["inst-1"].Status = model.InstanceStatusStopping
+	if edited := get(); edited.Revision == doc.Revision {
+		t.Error("revision unchanged after instance edit")
+	}
+}
+
+func TestReportObservedState(t *testing.T) {
+	store := newTestStore()
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	// 节点行动期间用户停止了 inst-1（pending -> stopping），节点迟到的 running 上报不得覆盖
+	store.instances["inst-1"].Status = model.InstanceStatusStopping
+
+	port := 7681
+	report := model.NodeObservedState{
+		Instances: []model.ObservedInstance{
+			{ID: "inst-1", Status: model.InstanceStatusPending, State: model.ObservedRunning, ContainerName: "c1"},
+			{ID: "inst-2", Status: model.InstanceStatusStopping, State: model.ObservedStopped},
+			{ID: "inst-3", Status: model.InstanceStatusError, State: model.ObservedStopped},
+			{ID: "inst-9", Status: model.InstanceStatusPending, State: model.ObservedRunning},
+		},
+		Terminals: []model.ObservedTerminal{
+			{ID: "term-new", Status: model.TerminalStatusPending, State: model.TerminalStatusRunning, Port: &port, URL: strPtr("/terminal/")},
+			{ID: "term-old", Status: model.TerminalStatusRunning, State: model.TerminalStatusClosed},
+			{ID: "term-gone", Status: model.TerminalStatusRunning, State: model.TerminalStatusError, Error: "boom"},
+		},
+	}
+	body, _ := json.Marshal(report)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node-1/observed-state", bytes.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp map[string]int
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	// applied: inst-2、term-new、term-old；skipped: inst-1（已修改）、inst-9（其他节点）、term-gone（已关闭）
+	if resp["applied"] != 3 || resp["skipped"] != 3 {
+		t.Errorf("resp = %v, want applied=3 skipped=3", resp)
+	}
+
+	if got := store.instances["inst-1"].Status; got != model.InstanceStatusStopping {
+		t.Errorf("inst-1 = %s, want stopping (user edit kept)", got)
+	}
+	if got := store.instances["inst-2"].Status; got != model.InstanceStatusStopped {
+		t.Errorf("inst-2 = %s, want stopped", got)
+	}
+	if got := store.instances["inst-3"].Status; got != model.InstanceStatusError {
+		t.Errorf("inst-3 = %s, want error (no desired state)", got)
+	}
+	if got := store.instances["inst-9"].Status; got != model.InstanceStatusPending {
+		t.Errorf("inst-9 = %s, want pending (other node)", got)
+	}
+	if s := store.sessions["term-new"]; s.Status != model.TerminalStatusRunning || s.Port == nil || *s.Port != port {
+		t.Errorf("term-new = %s port=%v, want running on %d", s.Status, s.Port, port)
+	}
+	if got := store.sessions["term-old"].Status; got != model.TerminalStatusClosed {
+		t.Errorf("term-old = %s, want closed", got)
+	}
+	if got := store.sessions["term-gone"].Status; got != model.TerminalStatusClosed {
+		t.Errorf("term-gone = %s, want closed (never reopened)", got)
+	}
+}
+
+func TestObservedInstanceStatus(t *testing.T) {
+	tests := []struct {
+		current  model.InstanceStatus
+		observed string
+		want     model.InstanceStatus
+		ok       bool
+	}{
+		{model.InstanceStatusPending, model.ObservedRunning, model.InstanceStatusRunning, true},
+		{model.InstanceStatusCreating, model.ObservedError, model.InstanceStatusError, true},
+		{model.InstanceStatusRunning, model.ObservedRunning, model.InstanceStatusRunning, false},
+		{model.InstanceStatusPending, model.ObservedStopped, model.InstanceStatusPending, false},
+		{model.InstanceStatusStopping, model.ObservedStopped, model.InstanceStatusStopped, true},
+		{model.InstanceStatusStopping, model.ObservedRunning, model.InstanceStatusStopping, false},
+		{model.InstanceStatusError, model.ObservedRunning, model.InstanceStatusError, false},
+	}
+	for _, tt := range tests {
+		got, ok := model.ObservedInstanceStatus(tt.current, tt.observed)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("ObservedInstanceStatus(%s, %s) = %s, %v; want %s, %v", tt.current, tt.observed, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -2,7 +2,7 @@ package operation
 
 import (
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"agents-admin/internal/shared/model"
@@ -27,7 +27,7 @@ func (h *Handler) GetAction(w http.ResponseWriter, r *http.Request) {
 
 	action, err := h.store.GetActionWithOperation(ctx, id)
 	if err != nil {
-		log.Printf("[operation] GetActionWithOperation error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "GetActionWithOperation", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get action")
 		return
 	}
@@ -56,7 +56,7 @@ func (h *Handler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 	// 获取 Action（含 Operation）
 	action, err := h.store.GetActionWithOperation(ctx, id)
 	if err != nil {
-		log.Printf("[operation] GetActionWithOperation error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "GetActionWithOperation", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get action")
 		return
 	}
@@ -76,7 +76,7 @@ func (h *Handler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 
 	// 更新 Action
 	if err := h.store.UpdateActionStatus(ctx, id, newStatus, newPhase, req.Message, req.Progress, req.Result, req.Error); err != nil {
-		log.Printf("[operation] UpdateActionStatus error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "UpdateActionStatus", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to update action")
 		return
 	}
@@ -91,7 +91,7 @@ func (h *Handler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 			opStatus = model.OperationStatusFailed
 		}
 		if err := h.store.UpdateOperationStatus(ctx, action.OperationID, opStatus); err != nil {
-			log.Printf("[operation] UpdateOperationStatus error: %v", err)
+			slog.ErrorContext(ctx, "operation", "op", "UpdateOperationStatus", "error", err)
 		}
 
 		// 处理操作结果（成功时创建 Account 等）
@@ -102,12 +102,12 @@ func (h *Handler) UpdateAction(w http.ResponseWriter, r *http.Request) {
 		// 首次开始执行时更新 Operation 为 in_progress
 		if action.Operation != nil && action.Operation.Status == model.OperationStatusPending {
 			if err := h.store.UpdateOperationStatus(ctx, action.OperationID, model.OperationStatusInProgress); err != nil {
-				log.Printf("[operation] UpdateOperationStatus error: %v", err)
+				slog.ErrorContext(ctx, "operation", "op", "UpdateOperationStatus", "error", err)
 			}
 		}
 	}
 
-	log.Printf("[operation] Action %s updated: status=%s phase=%s (progress=%d)", id, req.Status, req.Phase, req.Progress)
+	slog.InfoContext(ctx, "operation.action.updated", "action_id", id, "status", req.Status, "phase", req.Phase, "progress", req.Progress)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"id":      id,
@@ -3,7 +3,7 @@ package auth
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -44,12 +44,12 @@ func (h *Handler) CreateAccount(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.CreateAccount(ctx, account); err != nil {
-		log.Printf("[auth] CreateAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "CreateAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create account")
 		return
 	}
 
-	log.Printf("[auth] Account created: %s", accountID)
+	slog.InfoContext(ctx, "account.created", "account_id", accountID)
 	writeJSON(w, http.StatusCreated, account)
 }
 
@@ -61,7 +61,7 @@ func (h *Handler) ListAccounts(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	accounts, err := h.store.ListAccounts(ctx)
 	if err != nil {
-		log.Printf("[auth] ListAccounts error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "ListAccounts", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list accounts")
 		return
 	}
@@ -90,7 +90,7 @@ func (h *Handler) GetAccount(w http.ResponseWriter, r *http.Request) {
 
 	account, err := h.store.GetAccount(ctx, id)
 	if err != nil {
-		log.Printf("[auth] GetAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "GetAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get account")
 		return
 	}
@@ -111,7 +111,7 @@ func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 
 	account, err := h.store.GetAccount(ctx, id)
 	if err != nil {
-		log.Printf("[auth] DeleteAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "DeleteAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get account")
 		return
 	}
@@ -121,7 +121,7 @@ func (h *Handler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.DeleteAccount(ctx, id); err != nil {
-		log.Printf("[auth] DeleteAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "DeleteAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to delete account")
 		return
 	}
@@ -1,17 +1,39 @@
 package auth
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"agents-admin/internal/shared/model"
+
+	"gopkg.in/yaml.v3"
 )
 
-// ListAgentTypes 列出所有预定义 Agent 类型
+// agentTypeIDPattern 自定义类型标识（同时用于 Adapter 名称与 Volume 名称）
+var agentTypeIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// ListAgentTypes 列出所有 Agent 类型（预定义类型在前，自定义类型按创建时间排列）
 //
 // GET /api/v1/agent-types
 func (h *Handler) ListAgentTypes(w http.ResponseWriter, r *http.Request) {
+	types := make([]*model.AgentTypeConfig, 0, len(model.PredefinedAgentTypes))
+	for i := range model.PredefinedAgentTypes {
+		types = append(types, &model.PredefinedAgentTypes[i])
+	}
+	custom, err := h.store.ListAgentTypes(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list agent types")
+		return
+	}
+	types = append(types, custom...)
 	writeJSON(w, http.StatusOK, map[string]interface{}{
-		"agent_types": model.PredefinedAgentTypes,
+		"agent_types": types,
 	})
 }
 
@@ -20,11 +42,169 @@ func (h *Handler) ListAgentTypes(w http.ResponseWriter, r *http.Request) {
 // GET /api/v1/agent-types/{id}
 func (h *Handler) GetAgentType(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
-	for _, at := range model.PredefinedAgentTypes {
-		if at.ID == id {
-			writeJSON(w, http.StatusOK, at)
-			return
-		}
+	if at := findAgentType(id); at != nil {
+		writeJSON(w, http.StatusOK, at)
+		return
+	}
+	at, err := h.store.GetAgentType(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get agent type")
+		return
+	}
+	if at == nil {
+		writeError(w, http.StatusNotFound, "agent type not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, at)
+}
+
+// CreateAgentType 创建自定义 Agent 类型（请求体为 JSON 或 YAML）
+//
+// POST /api/v1/agent-types
+func (h *Handler) CreateAgentType(w http.ResponseWriter, r *http.Request) {
+	var at model.AgentTypeConfig
+	if err := decodeAgentType(r, &at); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if !agentTypeIDPattern.MatchString(at.ID) {
+		writeError(w, http.StatusBadRequest, "id must be lowercase letters, digits and dashes")
+		return
+	}
+	if err := validateAgentType(&at); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if findAgentType(at.ID) != nil {
+		writeError(w, http.StatusConflict, "agent type is builtin")
+		return
+	}
+	existing, err := h.store.GetAgentType(r.Context(), at.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get agent type")
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "agent type already exists")
+		return
+	}
+
+	now := time.Now()
+	at.Builtin = false
+	at.CreatedAt = now
+	at.UpdatedAt = now
+	if err := h.store.CreateAgentType(r.Context(), &at); err != nil {
+		slog.ErrorContext(r.Context(), "agent_type.create.failed", "id", at.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create agent type")
+		return
+	}
+	slog.InfoContext(r.Context(), "agent_type.create.success", "id", at.ID, "command", at.Adapter.Command[0])
+	writeJSON(w, http.StatusCreated, &at)
+}
+
+// UpdateAgentType 整体替换自定义 Agent 类型（请求体为 JSON 或 YAML）
+//
+// PUT /api/v1/agent-types/{id}
+func (h *Handler) UpdateAgentType(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if findAgentType(id) != nil {
+		writeError(w, http.StatusForbidden, "builtin agent types cannot be modified")
+		return
+	}
+	existing, err := h.store.GetAgentType(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get agent type")
+		return
+	}
+	if existing == nil {
+		writeError(w, http.StatusNotFound, "agent type not found")
+		return
+	}
+
+	var at model.AgentTypeConfig
+	if err := decodeAgentType(r, &at); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body: "+err.Error())
+		return
+	}
+	if at.ID != "" && at.ID != id {
+		writeError(w, http.StatusBadRequest, "id cannot be changed")
+		return
+	}
+	if err := validateAgentType(&at); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	at.ID = id
+	at.Builtin = false
+	at.CreatedAt = existing.CreatedAt
+	at.UpdatedAt = time.Now()
+	if err := h.store.UpdateAgentType(r.Context(), &at); err != nil {
+		slog.ErrorContext(r.Context(), "agent_type.update.failed", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update agent type")
+		return
+	}
+	slog.InfoContext(r.Context(), "agent_type.update.success", "id", id)
+	writeJSON(w, http.StatusOK, &at)
+}
+
+// DeleteAgentType 删除自定义 Agent 类型
+//
+// DELETE /api/v1/agent-types/{id}
+func (h *Handler) DeleteAgentType(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if findAgentType(id) != nil {
+		writeError(w, http.StatusForbidden, "builtin agent types cannot be deleted")
+		return
+	}
+	existing, err := h.store.GetAgentType(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get agent type")
+		return
+	}
+	if existing == nil {
+		writeError(w, http.StatusNotFound, "agent type not found")
+		return
+	}
+	if err := h.store.DeleteAgentType(r.Context(), id); err != nil {
+		slog.ErrorContext(r.Context(), "agent_type.delete.failed", "id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete agent type")
+		return
+	}
+	slog.InfoContext(r.Context(), "agent_type.delete.success", "id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeAgentType 解析请求体；Content-Type 含 yaml 时按 YAML 解析（字段名与 JSON 相同）
+func decodeAgentType(r *http.Request, at *model.AgentTypeConfig) error {
+	if !strings.Contains(r.Header.Get("Content-Type"), "yaml") {
+		return json.NewDecoder(r.Body).Decode(at)
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return err
+	}
+	raw, err := json.Marshal(doc)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, at)
+}
+
+// validateAgentType 校验自定义类型的必填字段与适配器配置
+func validateAgentType(at *model.AgentTypeConfig) error {
+	if at.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if at.Image == "" {
+		return fmt.Errorf("image is required")
+	}
+	if at.Adapter == nil {
+		return fmt.Errorf("adapter is required")
 	}
-	writeError(w, http.StatusNotFound, "agent type not found")
+	return at.Adapter.Validate()
 }
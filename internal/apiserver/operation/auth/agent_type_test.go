@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	apiauth "agents-admin/internal/apiserver/auth"
 	"agents-admin/internal/shared/model"
 )
 
@@ -87,3 +89,118 @@ func TestAgentTypeSupportsMethod(t *testing.T) {
 		t.Error("qwen-code should not support device_code")
 	}
 }
+
+const aichatYAML = `id: aichat
+name: AIChat
+image: runners/aichat:latest
+adapter:
+  command: [aichat]
+  args: ["--model={{.Model}}", "{{.Prompt}}"]
+  rules:
+    - type: file_write
+      pattern: '^Wrote (?P<path>\S+)$'
+      payload:
+        path: ${path}
+  default_event: message
+`
+
+func doAgentType(h *Handler, method, path, contentType, body, role string) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if role != "" {
+		req = req.WithContext(apiauth.WithAuthUser(req.Context(), &apiauth.AuthUser{ID: "u1", Role: role}))
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestCustomAgentType_CRUD(t *testing.T) {
+	store := newMockStore()
+	h := NewHandler(store)
+
+	w := doAgentType(h, "POST", "/api/v1/agent-types", "application/yaml", aichatYAML, "admin")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: expected 201, got %d: %s", w.Code, w.Body.String())
+	}
+	created := store.agentTypes["aichat"]
+	if created == nil || created.Adapter == nil {
+		t.Fatal("expected agent type with adapter to be stored")
+	}
+	if created.Adapter.Rules[0].Payload["path"] != "${path}" {
+		t.Errorf("unexpected rule payload: %v", created.Adapter.Rules[0].Payload)
+	}
+
+	w = doAgentType(h, "POST", "/api/v1/agent-types", "application/yaml", aichatYAML, "admin")
+	if w.Code != http.StatusConflict {
+		t.Errorf("duplicate create: expected 409, got %d", w.Code)
+	}
+
+	w = doAgentType(h, "GET", "/api/v1/agent-types", "", "", "")
+	var resp struct {
+		AgentTypes []model.AgentTypeConfig `json:"agent_types"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.AgentTypes) != len(model.PredefinedAgentTypes)+1 {
+		t.Fatalf("expected %d agent types, got %d", len(model.PredefinedAgentTypes)+1, len(resp.AgentTypes))
+	}
+	if last := resp.AgentTypes[len(resp.AgentTypes)-1]; last.ID != "aichat" || last.Builtin {
+		t.Errorf("expected custom aichat last, got %+v", last)
+	}
+
+	w = doAgentType(h, "GET", "/api/v1/agent-types/aichat", "", "", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("get: expected 200, got %d", w.Code)
+	}
+
+	body := `{"name":"AIChat","image":"runners/aichat:v2","adapter":{"command":["aichat"],"output":"json",` +
+		`"rules":[{"type":"message","field":"type","value":"text","payload":{"content":"text"}}]}}`
+	w = doAgentType(h, "PUT", "/api/v1/agent-types/aichat", "application/json", body, "admin")
+	if w.Code != http.StatusOK {
+		t.Fatalf("update: expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if store.agentTypes["aichat"].Image != "runners/aichat:v2" {
+		t.Errorf("expected image updated, got %s", store.agentTypes["aichat"].Image)
+	}
+
+	w = doAgentType(h, "DELETE", "/api/v1/agent-types/aichat", "", "", "admin")
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("delete: expected 204, got %d", w.Code)
+	}
+	if _, ok := store.agentTypes["aichat"]; ok {
+		t.Error("expected agent type to be deleted")
+	}
+}
+
+func TestCustomAgentType_Rejected(t *testing.T) {
+	h := NewHandler(newMockStore())
+
+	cases := []struct {
+		name, method, path, body, role string
+		want                           int
+	}{
+		{"non-admin", "POST", "/api/v1/agent-types", `{}`, "user", http.StatusForbidden},
+		{"builtin id", "POST", "/api/v1/agent-types",
+			`{"id":"qwen-code","name":"x","image":"x","adapter":{"command":["x"],"default_event":"message"}}`, "admin", http.StatusConflict},
+		{"bad id", "POST", "/api/v1/agent-types",
+			`{"id":"My Agent","name":"x","image":"x","adapter":{"command":["x"],"default_event":"message"}}`, "admin", http.StatusBadRequest},
+		{"missing adapter", "POST", "/api/v1/agent-types", `{"id":"x","name":"x","image":"x"}`, "admin", http.StatusBadRequest},
+		{"bad pattern", "POST", "/api/v1/agent-types",
+			`{"id":"x","name":"x","image":"x","adapter":{"command":["x"],"rules":[{"type":"message","pattern":"("}]}}`, "admin", http.StatusBadRequest},
+		{"modify builtin", "PUT", "/api/v1/agent-types/qwen-code", `{}`, "admin", http.StatusForbidden},
+		{"delete builtin", "DELETE", "/api/v1/agent-types/qwen-code", ``, "admin", http.StatusForbidden},
+		{"delete missing", "DELETE", "/api/v1/agent-types/missing", ``, "admin", http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doAgentType(h, tc.method, tc.path, "application/json", tc.body, tc.role)
+			if w.Code != tc.want {
+				t.Errorf("expected %d, got %d: %s", tc.want, w.Code, w.Body.String())
+			}
+		})
+	}
+}
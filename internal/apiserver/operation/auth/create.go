@@ -3,7 +3,7 @@ package auth
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"time"
 
@@ -44,7 +44,7 @@ func (h *Handler) CreateAuthOperation(w http.ResponseWriter, r *http.Request, op
 	// 验证节点存在
 	node, err := h.store.GetNode(ctx, nodeID)
 	if err != nil {
-		log.Printf("[auth] GetNode error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "GetNode", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to check node")
 		return
 	}
@@ -77,18 +77,18 @@ func (h *Handler) CreateAuthOperation(w http.ResponseWriter, r *http.Request, op
 	}
 
 	if err := h.store.CreateOperation(ctx, op); err != nil {
-		log.Printf("[auth] CreateOperation error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "CreateOperation", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create operation")
 		return
 	}
 
 	if err := h.store.CreateAction(ctx, action); err != nil {
-		log.Printf("[auth] CreateAction error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "CreateAction", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create action")
 		return
 	}
 
-	log.Printf("[auth] Created %s operation: %s (action: %s, node: %s)", method, opID, actID, nodeID)
+	slog.InfoContext(ctx, "operation.created", "method", method, "operation_id", opID, "action_id", actID, "node_id", nodeID)
 
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
 		"operation_id": opID,
@@ -130,7 +130,7 @@ func (h *Handler) CreateAPIKeyOperation(w http.ResponseWriter, r *http.Request,
 	// 验证节点存在
 	node, err := h.store.GetNode(ctx, nodeID)
 	if err != nil {
-		log.Printf("[auth] GetNode error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "GetNode", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to check node")
 		return
 	}
@@ -181,22 +181,22 @@ func (h *Handler) CreateAPIKeyOperation(w http.ResponseWriter, r *http.Request,
 
 	// 持久化
 	if err := h.store.CreateOperation(ctx, op); err != nil {
-		log.Printf("[auth] CreateOperation error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "CreateOperation", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create operation")
 		return
 	}
 	if err := h.store.CreateAction(ctx, action); err != nil {
-		log.Printf("[auth] CreateAction error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "CreateAction", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create action")
 		return
 	}
 	if err := h.store.CreateAccount(ctx, account); err != nil {
-		log.Printf("[auth] CreateAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "CreateAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create account")
 		return
 	}
 
-	log.Printf("[auth] API Key operation completed: %s → account %s", opID, accountID)
+	slog.InfoContext(ctx, "operation.api_key.completed", "operation_id", opID, "account_id", accountID)
 
 	writeJSON(w, http.StatusCreated, map[string]interface{}{
 		"operation_id": opID,
@@ -3,6 +3,7 @@ package auth
 import (
 	"net/http"
 
+	apiauth "agents-admin/internal/apiserver/auth"
 	objstore "agents-admin/internal/shared/minio"
 	"agents-admin/internal/shared/storage"
 )
@@ -25,9 +26,12 @@ func (h *Handler) SetMinIOClient(mc *objstore.Client) {
 
 // RegisterRoutes 注册认证相关路由
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
-	// Agent 类型（只读）
+	// Agent 类型（预定义类型只读，自定义类型由管理员维护）
 	mux.HandleFunc("GET /api/v1/agent-types", h.ListAgentTypes)
 	mux.HandleFunc("GET /api/v1/agent-types/{id}", h.GetAgentType)
+	mux.HandleFunc("POST /api/v1/agent-types", apiauth.AdminOnly(h.CreateAgentType))
+	mux.HandleFunc("PUT /api/v1/agent-types/{id}", apiauth.AdminOnly(h.UpdateAgentType))
+	mux.HandleFunc("DELETE /api/v1/agent-types/{id}", apiauth.AdminOnly(h.DeleteAgentType))
 
 	// 账号
 	mux.HandleFunc("POST /api/v1/accounts", h.CreateAccount)
@@ -18,6 +18,7 @@ type mockStore struct {
 	actions    map[string]*model.Action
 	accounts   map[string]*model.Account
 	nodes      map[string]*model.Node
+	agentTypes map[string]*model.AgentTypeConfig
 }
 
 func newMockStore() *mockStore {
@@ -26,6 +27,7 @@ func newMockStore() *mockStore {
 		actions:    make(map[string]*model.Action),
 		accounts:   make(map[string]*model.Account),
 		nodes:      make(map[string]*model.Node),
+		agentTypes: make(map[string]*model.AgentTypeConfig),
 	}
 }
 
@@ -65,6 +67,13 @@ func (m *mockStore) UpdateOperationStatus(_ context.Context, id string, status m
 	return nil
 }
 
+func (m *mockStore) UpdateOperationConfig(_ context.Context, id string, config json.RawMessage) error {
+	if op, ok := m.operations[id]; ok {
+		op.Config = config
+	}
+	return nil
+}
+
 // --- ActionStore ---
 
 func (m *mockStore) CreateAction(_ context.Context, action *model.Action) error {
@@ -192,6 +201,19 @@ func (m *mockStore) DeleteNode(_ context.Context, id string) error            {
 func (m *mockStore) DeactivateStaleNodes(_ context.Context, _ string, _ string) error {
 	return nil
 }
+func (m *mockStore) GetNodeCapacityOverride(_ context.Context, _ string) (*model.NodeConcurrencyOverride, error) {
+	return nil, nil
+}
+func (m *mockStore) SetNodeCapacityOverride(_ context.Context, _ string, _ *model.NodeConcurrencyOverride) error {
+	return nil
+}
+func (m *mockStore) TouchNodeHeartbeat(_ context.Context, _ string, _ time.Time) error {
+	return nil
+}
+
+func (m *mockStore) SetNodeTaints(_ context.Context, _ string, _ []model.NodeTaint) error {
+	return nil
+}
 func (m *mockStore) CreateNodeProvision(_ context.Context, _ *model.NodeProvision) error {
 	return nil
 }
@@ -258,6 +280,15 @@ func (m *mockStore) CreateEvents(_ context.Context, _ []*model.Event) error { re
 func (m *mockStore) CountEventsByRun(_ context.Context, _ string) (int, error) {
 	return 0, nil
 }
+func (m *mockStore) AllocateEventSeqs(_ context.Context, _ string, _ int) (int, error) {
+	return 1, nil
+}
+func (m *mockStore) ListEventNodeSeqs(_ context.Context, _ string, _, _ int) ([]int, error) {
+	return nil, nil
+}
+func (m *mockStore) MaxEventSeqs(_ context.Context, _ string) (int, int, error) {
+	return 0, 0, nil
+}
 func (m *mockStore) GetEventsByRun(_ context.Context, _ string, _ int, _ int) ([]*model.Event, error) {
 	return nil, nil
 }
@@ -313,6 +344,9 @@ func (m *mockStore) ListPendingAgentInstances(_ context.Context, _ string) ([]*m
 func (m *mockStore) UpdateAgentInstance(_ context.Context, _ string, _ model.InstanceStatus, _ *string) error {
 	return nil
 }
+func (m *mockStore) TransitionAgentInstance(_ context.Context, _ string, _, _ model.InstanceStatus, _ *string) (bool, error) {
+	return false, nil
+}
 func (m *mockStore) DeleteAgentInstance(_ context.Context, _ string) error { return nil }
 
 // TerminalSessionStore
@@ -334,11 +368,18 @@ func (m *mockStore) ListPendingTerminalSessions(_ context.Context, _ string) ([]
 func (m *mockStore) UpdateTerminalSession(_ context.Context, _ string, _ model.TerminalSessionStatus, _ *int, _ *string) error {
 	return nil
 }
+func (m *mockStore) TransitionTerminalSession(_ context.Context, _ string, _, _ model.TerminalSessionStatus, _ *int, _ *string) (bool, error) {
+	return false, nil
+}
 func (m *mockStore) DeleteTerminalSession(_ context.Context, _ string) error { return nil }
 func (m *mockStore) CleanupExpiredTerminalSessions(_ context.Context) (int64, error) {
 	return 0, nil
 }
 
+func (m *mockStore) CloseExpiredTerminalSessions(_ context.Context) (int64, error) {
+	return 0, nil
+}
+
 // HITLStore
 func (m *mockStore) CreateApprovalRequest(_ context.Context, _ *model.ApprovalRequest) error {
 	return nil
@@ -388,7 +429,8 @@ func (m *mockStore) GetTaskTemplate(_ context.Context, _ string) (*model.TaskTem
 func (m *mockStore) ListTaskTemplates(_ context.Context, _ string) ([]*model.TaskTemplate, error) {
 	return nil, nil
 }
-func (m *mockStore) DeleteTaskTemplate(_ context.Context, _ string) error { return nil }
+func (m *mockStore) UpdateTaskTemplate(_ context.Context, _ *model.TaskTemplate) error { return nil }
+func (m *mockStore) DeleteTaskTemplate(_ context.Context, _ string) error              { return nil }
 func (m *mockStore) CreateAgentTemplate(_ context.Context, _ *model.AgentTemplate) error {
 	return nil
 }
@@ -401,10 +443,17 @@ func (m *mockStore) ListAgentTemplates(_ context.Context, _ string) ([]*model.Ag
 func (m *mockStore) DeleteAgentTemplate(_ context.Context, _ string) error { return nil }
 
 // SkillStore
-func (m *mockStore) CreateSkill(_ context.Context, _ *model.Skill) error            { return nil }
-func (m *mockStore) GetSkill(_ context.Context, _ string) (*model.Skill, error)     { return nil, nil }
-func (m *mockStore) ListSkills(_ context.Context, _ string) ([]*model.Skill, error) { return nil, nil }
-func (m *mockStore) DeleteSkill(_ context.Context, _ string) error                  { return nil }
+func (m *mockStore) CreateSkill(_ context.Context, _ *model.Skill) error             { return nil }
+func (m *mockStore) GetSkill(_ context.Context, _ string) (*model.Skill, error)      { return nil, nil }
+func (m *mockStore) ListSkills(_ context.Context, _ string) ([]*model.Skill, error)  { return nil, nil }
+func (m *mockStore) DeleteSkill(_ context.Context, _ string) error                   { return nil }
+func (m *mockStore) CreateSkillBundle(_ context.Context, _ *model.SkillBundle) error { return nil }
+func (m *mockStore) GetSkillBundle(_ context.Context, _, _ string) (*model.SkillBundle, error) {
+	return nil, nil
+}
+func (m *mockStore) ListSkillBundles(_ context.Context, _ string) ([]*model.SkillBundle, error) {
+	return nil, nil
+}
 
 // MCPServerStore
 func (m *mockStore) CreateMCPServer(_ context.Context, _ *model.MCPServer) error { return nil }
@@ -433,9 +482,314 @@ func (m *mockStore) CreateUser(_ context.Context, _ *model.User) error { return
 func (m *mockStore) GetUserByEmail(_ context.Context, _ string) (*model.User, error) {
 	return nil, nil
 }
-func (m *mockStore) GetUserByID(_ context.Context, _ string) (*model.User, error) { return nil, nil }
-func (m *mockStore) UpdateUserPassword(_ context.Context, _, _ string) error      { return nil }
-func (m *mockStore) ListUsers(_ context.Context) ([]*model.User, error)           { return nil, nil }
+func (m *mockStore) GetUserByID(_ context.Context, _ string) (*model.User, error)       { return nil, nil }
+func (m *mockStore) UpdateUserPassword(_ context.Context, _, _ string) error            { return nil }
+func (m *mockStore) UpdateUserRole(_ context.Context, _ string, _ model.UserRole) error { return nil }
+func (m *mockStore) ListUsers(_ context.Context) ([]*model.User, error)                 { return nil, nil }
+
+// UserSecurityStore
+func (m *mockStore) GetUserMFA(_ context.Context, _ string) (*model.UserMFA, error) { return nil, nil }
+func (m *mockStore) SaveUserMFA(_ context.Context, _ *model.UserMFA) error          { return nil }
+func (m *mockStore) DeleteUserMFA(_ context.Context, _ string) error                { return nil }
+func (m *mockStore) UseUserMFAStep(_ context.Context, _ string, _ int64) (bool, error) {
+	return false, nil
+}
+func (m *mockStore) ConsumeUserBackupCode(_ context.Context, _, _ string) (bool, error) {
+	return false, nil
+}
+func (m *mockStore) CreateUserSession(_ context.Context, _ *model.UserSession) error { return nil }
+func (m *mockStore) GetUserSession(_ context.Context, _ string) (*model.UserSession, error) {
+	return nil, nil
+}
+func (m *mockStore) ListUserSessions(_ context.Context, _ string) ([]*model.UserSession, error) {
+	return nil, nil
+}
+func (m *mockStore) TouchUserSession(_ context.Context, _ string, _ time.Time) error { return nil }
+func (m *mockStore) RevokeUserSession(_ context.Context, _, _ string) (bool, error) {
+	return false, nil
+}
+func (m *mockStore) RevokeUserSessions(_ context.Context, _, _ string) (int64, error) {
+	return 0, nil
+}
 
 // UpdateAgentTemplate
 func (m *mockStore) UpdateAgentTemplate(_ context.Context, _ *model.AgentTemplate) error { return nil }
+
+// ArtifactStore
+func (m *mockStore) CreateArtifact(_ context.Context, _ *model.Artifact) error { return nil }
+func (m *mockStore) ListArtifactsByRun(_ context.Context, _ string) ([]*model.Artifact, error) {
+	return nil, nil
+}
+
+// CredentialStore
+func (m *mockStore) CreateCredential(_ context.Context, _ *model.Credential) error { return nil }
+func (m *mockStore) GetCredential(_ context.Context, _ string) (*model.Credential, error) {
+	return nil, nil
+}
+func (m *mockStore) GetCredentialByName(_ context.Context, _ string) (*model.Credential, error) {
+	return nil, nil
+}
+func (m *mockStore) ListCredentials(_ context.Context) ([]*model.Credential, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateCredential(_ context.Context, _ *model.Credential) error { return nil }
+func (m *mockStore) DeleteCredential(_ context.Context, _ string) error            { return nil }
+
+// ProjectStore
+func (m *mockStore) CreateProject(_ context.Context, _ *model.Project) error { return nil }
+func (m *mockStore) GetProject(_ context.Context, _ string) (*model.Project, error) {
+	return nil, nil
+}
+func (m *mockStore) ListProjects(_ context.Context) ([]*model.Project, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateProject(_ context.Context, _ *model.Project) error { return nil }
+func (m *mockStore) DeleteProject(_ context.Context, _ string) error         { return nil }
+
+// RegionStore
+func (m *mockStore) CreateRegion(_ context.Context, _ *model.Region) error { return nil }
+func (m *mockStore) GetRegion(_ context.Context, _ string) (*model.Region, error) {
+	return nil, nil
+}
+func (m *mockStore) ListRegions(_ context.Context) ([]*model.Region, error) { return nil, nil }
+func (m *mockStore) UpdateRegion(_ context.Context, _ *model.Region) error  { return nil }
+func (m *mockStore) DeleteRegion(_ context.Context, _ string) error         { return nil }
+func (m *mockStore) CountQueuedRunsByRegion(_ context.Context) ([]model.RegionQueueDepth, error) {
+	return nil, nil
+}
+
+// SecretStore
+func (m *mockStore) CreateSecret(_ context.Context, _ *model.Secret) error { return nil }
+func (m *mockStore) GetSecret(_ context.Context, _ string) (*model.Secret, error) {
+	return nil, nil
+}
+func (m *mockStore) GetSecretByName(_ context.Context, _ string) (*model.Secret, error) {
+	return nil, nil
+}
+func (m *mockStore) ListSecrets(_ context.Context) ([]*model.Secret, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateSecret(_ context.Context, _ *model.Secret) error { return nil }
+func (m *mockStore) DeleteSecret(_ context.Context, _ string) error        { return nil }
+
+func (m *mockStore) SearchEvents(_ context.Context, _ storage.EventSearchFilter) ([]*model.EventSearchHit, int, error) {
+	return nil, 0, nil
+}
+
+func (m *mockStore) CountRunsByStatus(_ context.Context, _ time.Time) (map[model.RunStatus]int, error) {
+	return nil, nil
+}
+func (m *mockStore) CountPendingApprovals(_ context.Context) (int, error) { return 0, nil }
+
+func (m *mockStore) CreateWorkflow(_ context.Context, _ *model.Workflow) error { return nil }
+func (m *mockStore) GetWorkflow(_ context.Context, _ string) (*model.Workflow, error) {
+	return nil, nil
+}
+func (m *mockStore) ListWorkflows(_ context.Context, _ string, _, _ int) ([]*model.Workflow, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateWorkflow(_ context.Context, _ *model.Workflow) error { return nil }
+
+func (m *mockStore) CreateIntegration(_ context.Context, _ *model.Integration) error { return nil }
+func (m *mockStore) GetIntegration(_ context.Context, _ string) (*model.Integration, error) {
+	return nil, nil
+}
+func (m *mockStore) ListIntegrations(_ context.Context) ([]*model.Integration, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateIntegration(_ context.Context, _ *model.Integration) error { return nil }
+func (m *mockStore) DeleteIntegration(_ context.Context, _ string) error             { return nil }
+func (m *mockStore) CreateIssueLink(_ context.Context, _ *model.IssueLink) error     { return nil }
+func (m *mockStore) GetIssueLinkByTask(_ context.Context, _ string) (*model.IssueLink, error) {
+	return nil, nil
+}
+func (m *mockStore) GetIssueLinkByIssue(_ context.Context, _, _ string) (*model.IssueLink, error) {
+	return nil, nil
+}
+
+func (m *mockStore) MarkRunTimeout(_ context.Context, _ string, _ string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStore) CreateRunFlags(_ context.Context, _ []*model.RunFlag) error { return nil }
+func (m *mockStore) ListRunFlags(_ context.Context, _ string) ([]*model.RunFlag, error) {
+	return nil, nil
+}
+func (m *mockStore) CreateEventOriginals(_ context.Context, _ []*model.EventOriginal) error {
+	return nil
+}
+func (m *mockStore) GetEventOriginal(_ context.Context, _ string, _ int) (*model.EventOriginal, error) {
+	return nil, nil
+}
+
+func (m *mockStore) RecordSchedulingDecision(_ context.Context, _ *model.SchedulingDecision) error {
+	return nil
+}
+func (m *mockStore) ListSchedulingDecisions(_ context.Context, _ string, _ int) ([]*model.SchedulingDecision, error) {
+	return nil, nil
+}
+
+func (m *mockStore) ReclaimRun(_ context.Context, _, _ string, _ model.RunStatus, _ string) (bool, error) {
+	return false, nil
+}
+
+func (m *mockStore) CreateNodeJoinToken(_ context.Context, _ *model.NodeJoinToken) error { return nil }
+func (m *mockStore) ListNodeJoinTokens(_ context.Context) ([]*model.NodeJoinToken, error) {
+	return nil, nil
+}
+func (m *mockStore) DeleteNodeJoinToken(_ context.Context, _ string) error { return nil }
+func (m *mockStore) ConsumeNodeJoinToken(_ context.Context, _, _ string, _ time.Time) (*model.NodeJoinToken, error) {
+	return nil, nil
+}
+func (m *mockStore) UpsertNodeCredential(_ context.Context, _ *model.NodeCredential) error {
+	return nil
+}
+func (m *mockStore) GetNodeCredential(_ context.Context, _ string) (*model.NodeCredential, error) {
+	return nil, nil
+}
+func (m *mockStore) GetNodeCredentialByTokenHash(_ context.Context, _ string) (*model.NodeCredential, error) {
+	return nil, nil
+}
+func (m *mockStore) DeleteNodeCredential(_ context.Context, _ string) error { return nil }
+
+func (m *mockStore) CreateNodeJoinTokenUse(_ context.Context, _ *model.NodeJoinTokenUse) error {
+	return nil
+}
+func (m *mockStore) ListNodeJoinTokenUses(_ context.Context, _ string) ([]*model.NodeJoinTokenUse, error) {
+	return nil, nil
+}
+
+func (m *mockStore) CreateMaintenanceRun(_ context.Context, _ *model.MaintenanceRun) error {
+	return nil
+}
+func (m *mockStore) UpdateMaintenanceRun(_ context.Context, _ *model.MaintenanceRun) error {
+	return nil
+}
+func (m *mockStore) ListMaintenanceRuns(_ context.Context, _ int) ([]*model.MaintenanceRun, error) {
+	return nil, nil
+}
+
+func (m *mockStore) CreateRunArchive(_ context.Context, _ *model.RunArchive) error { return nil }
+func (m *mockStore) GetRunArchive(_ context.Context, _ string) (*model.RunArchive, error) {
+	return nil, nil
+}
+func (m *mockStore) UpdateRunArchive(_ context.Context, _ *model.RunArchive) error { return nil }
+func (m *mockStore) ListRunsForArchive(_ context.Context, _ storage.ArchiveRunFilter) ([]*model.Run, error) {
+	return nil, nil
+}
+func (m *mockStore) ListRunArchives(_ context.Context, _ model.RunDataTier, _ time.Time, _ int) ([]*model.RunArchive, error) {
+	return nil, nil
+}
+func (m *mockStore) DeleteEventsByRun(_ context.Context, _ string) (int64, error) { return 0, nil }
+func (m *mockStore) ClearRunSnapshot(_ context.Context, _ string) error           { return nil }
+func (m *mockStore) CountEvents(_ context.Context) (int64, error)                 { return 0, nil }
+func (m *mockStore) RunArchiveStats(_ context.Context) ([]model.RunTierStats, error) {
+	return nil, nil
+}
+
+func (m *mockStore) CreateWebhook(_ context.Context, _ *model.Webhook) error { return nil }
+func (m *mockStore) GetWebhook(_ context.Context, _ string) (*model.Webhook, error) {
+	return nil, nil
+}
+func (m *mockStore) ListWebhooks(_ context.Context) ([]*model.Webhook, error) { return nil, nil }
+func (m *mockStore) UpdateWebhook(_ context.Context, _ *model.Webhook) error  { return nil }
+func (m *mockStore) DeleteWebhook(_ context.Context, _ string) error          { return nil }
+func (m *mockStore) RecordWebhookDelivery(_ context.Context, _ string, _ time.Time, _ int, _ string) error {
+	return nil
+}
+
+// --- AgentTypeStore ---
+
+func (m *mockStore) CreateAgentType(_ context.Context, t *model.AgentTypeConfig) error {
+	m.agentTypes[t.ID] = t
+	return nil
+}
+
+func (m *mockStore) GetAgentType(_ context.Context, id string) (*model.AgentTypeConfig, error) {
+	return m.agentTypes[id], nil
+}
+
+func (m *mockStore) ListAgentTypes(_ context.Context) ([]*model.AgentTypeConfig, error) {
+	var result []*model.AgentTypeConfig
+	for _, t := range m.agentTypes {
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+func (m *mockStore) UpdateAgentType(_ context.Context, t *model.AgentTypeConfig) error {
+	m.agentTypes[t.ID] = t
+	return nil
+}
+
+func (m *mockStore) DeleteAgentType(_ context.Context, id string) error {
+	delete(m.agentTypes, id)
+	return nil
+}
+
+func (m *mockStore) ListRuns(_ context.Context, _ storage.RunFilter) ([]*model.Run, int, error) {
+	return nil, 0, nil
+}
+
+func (m *mockStore) UpsertRegistryItem(_ context.Context, _ *model.RegistryItem) error {
+	return nil
+}
+func (m *mockStore) GetRegistryItem(_ context.Context, _ string) (*model.RegistryItem, error) {
+	return nil, nil
+}
+func (m *mockStore) ListRegistryItems(_ context.Context) ([]*model.RegistryItem, error) {
+	return nil, nil
+}
+
+func (m *mockStore) AddRunUsage(_ context.Context, _ *model.RunUsage) error {
+	return nil
+}
+func (m *mockStore) GetRunUsage(_ context.Context, _ string) (*model.RunUsage, error) {
+	return nil, nil
+}
+func (m *mockStore) ListRunUsage(_ context.Context, _, _ string) ([]*model.RunUsage, error) {
+	return nil, nil
+}
+
+func (m *mockStore) CreateBudget(_ context.Context, _ *model.Budget) error { return nil }
+func (m *mockStore) GetBudget(_ context.Context, _ string) (*model.Budget, error) {
+	return nil, nil
+}
+func (m *mockStore) ListBudgets(_ context.Context) ([]*model.Budget, error) { return nil, nil }
+func (m *mockStore) UpdateBudget(_ context.Context, _ *model.Budget) error  { return nil }
+func (m *mockStore) DeleteBudget(_ context.Context, _ string) error         { return nil }
+
+func (m *mockStore) CreateAuditLog(_ context.Context, _ *model.AuditLog) error { return nil }
+func (m *mockStore) ListAuditLogs(_ context.Context, _ storage.AuditFilter) ([]*model.AuditLog, int, error) {
+	return nil, 0, nil
+}
+
+func (m *mockStore) ListExpiredRunArchives(_ context.Context, _ string, _ time.Time, _ int) ([]*model.RunArchive, error) {
+	return nil, nil
+}
+
+func (m *mockStore) CreateRunWithOutbox(ctx context.Context, run *model.Run, _ ...*model.OutboxMessage) error {
+	return m.CreateRun(ctx, run)
+}
+func (m *mockStore) ClaimOutboxMessages(_ context.Context, _ int, _ time.Duration) ([]*model.OutboxMessage, error) {
+	return nil, nil
+}
+func (m *mockStore) MarkOutboxPublished(_ context.Context, _ string) error { return nil }
+func (m *mockStore) MarkOutboxFailed(_ context.Context, _, _ string, _ time.Time) error {
+	return nil
+}
+func (m *mockStore) DeletePublishedOutbox(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+func (m *mockStore) ClaimIdempotencyKey(_ context.Context, _ *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	return nil, nil
+}
+func (m *mockStore) CompleteIdempotencyKey(_ context.Context, _, _, _ string, _ int, _ []byte) error {
+	return nil
+}
+func (m *mockStore) DeleteIdempotencyKey(_ context.Context, _, _ string) error {
+	return nil
+}
+func (m *mockStore) DeleteExpiredIdempotencyKeys(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
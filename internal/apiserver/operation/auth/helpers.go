@@ -1,7 +1,7 @@
 // Package auth 认证操作领域 - HTTP 处理
 //
 // 处理认证相关的系统操作：
-//   - Agent 类型查询
+//   - Agent 类型查询与自定义类型管理
 //   - 账号管理（只读 + 删除）
 //   - 认证操作创建（OAuth / API Key / Device Code）
 //   - 认证结果处理（创建 Account）
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"agents-admin/internal/shared/model"
@@ -17,7 +17,7 @@ func (h *Handler) HandleAuthSuccess(ctx context.Context, op *model.Operation, re
 	// 解析 Operation config
 	var config model.OAuthConfig
 	if err := json.Unmarshal(op.Config, &config); err != nil {
-		log.Printf("[auth] Failed to parse operation config: %v", err)
+		slog.ErrorContext(ctx, "auth", "detail", "Failed to parse operation config", "error", err)
 		return
 	}
 
@@ -25,7 +25,7 @@ func (h *Handler) HandleAuthSuccess(ctx context.Context, op *model.Operation, re
 	var result model.AuthActionResult
 	if resultJSON != nil {
 		if err := json.Unmarshal(resultJSON, &result); err != nil {
-			log.Printf("[auth] Failed to parse action result: %v", err)
+			slog.ErrorContext(ctx, "auth", "detail", "Failed to parse action result", "error", err)
 		}
 	}
 
@@ -49,27 +49,27 @@ func (h *Handler) HandleAuthSuccess(ctx context.Context, op *model.Operation, re
 	// 检查账号是否已存在
 	existing, err := h.store.GetAccount(ctx, accountID)
 	if err != nil {
-		log.Printf("[auth] GetAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "GetAccount", "error", err)
 		return
 	}
 
 	if existing != nil {
 		// 账号已存在，更新状态
 		if err := h.store.UpdateAccountStatus(ctx, accountID, model.AccountStatusAuthenticated); err != nil {
-			log.Printf("[auth] UpdateAccountStatus error: %v", err)
+			slog.ErrorContext(ctx, "auth", "op", "UpdateAccountStatus", "error", err)
 		}
 		if result.VolumeName != "" {
 			if err := h.store.UpdateAccountVolume(ctx, accountID, result.VolumeName); err != nil {
-				log.Printf("[auth] UpdateAccountVolume error: %v", err)
+				slog.ErrorContext(ctx, "auth", "op", "UpdateAccountVolume", "error", err)
 			}
 		}
-		log.Printf("[auth] Account %s updated to authenticated", accountID)
+		slog.InfoContext(ctx, "account.authenticated", "account_id", accountID, "created", false)
 	} else {
 		// 创建新账号
 		if err := h.store.CreateAccount(ctx, account); err != nil {
-			log.Printf("[auth] CreateAccount error: %v", err)
+			slog.ErrorContext(ctx, "auth", "op", "CreateAccount", "error", err)
 			return
 		}
-		log.Printf("[auth] Account %s created (authenticated)", accountID)
+		slog.InfoContext(ctx, "account.authenticated", "account_id", accountID, "created", true)
 	}
 }
@@ -3,8 +3,10 @@ package auth
 import (
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
+
+	"agents-admin/internal/apiserver/bodylimit"
 )
 
 // UploadVolumeArchive 上传 Volume 归档到 MinIO
@@ -23,7 +25,7 @@ func (h *Handler) UploadVolumeArchive(w http.ResponseWriter, r *http.Request) {
 	// 验证账号存在
 	account, err := h.store.GetAccount(ctx, id)
 	if err != nil {
-		log.Printf("[auth] GetAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "GetAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get account")
 		return
 	}
@@ -35,19 +37,23 @@ func (h *Handler) UploadVolumeArchive(w http.ResponseWriter, r *http.Request) {
 	// 上传到 MinIO
 	archiveKey := fmt.Sprintf("volumes/%s.tar.gz", id)
 	if err := h.minio.Upload(ctx, archiveKey, r.Body, r.ContentLength, "application/gzip"); err != nil {
-		log.Printf("[auth] Upload volume archive error: %v", err)
+		if bodylimit.IsTooLarge(err) {
+			bodylimit.WriteTooLarge(w, err)
+			return
+		}
+		slog.ErrorContext(ctx, "auth", "detail", "Upload volume archive error", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to upload volume archive")
 		return
 	}
 
 	// 更新账号记录
 	if err := h.store.UpdateAccountVolumeArchive(ctx, id, archiveKey); err != nil {
-		log.Printf("[auth] UpdateAccountVolumeArchive error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "UpdateAccountVolumeArchive", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to update account")
 		return
 	}
 
-	log.Printf("[auth] Volume archive uploaded: %s -> %s", id, archiveKey)
+	slog.InfoContext(ctx, "account.volume_archive.uploaded", "account_id", id, "archive_key", archiveKey)
 	writeJSON(w, http.StatusOK, map[string]string{
 		"archive_key": archiveKey,
 	})
@@ -68,7 +74,7 @@ func (h *Handler) DownloadVolumeArchive(w http.ResponseWriter, r *http.Request)
 	// 验证账号存在且有归档
 	account, err := h.store.GetAccount(ctx, id)
 	if err != nil {
-		log.Printf("[auth] GetAccount error: %v", err)
+		slog.ErrorContext(ctx, "auth", "op", "GetAccount", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get account")
 		return
 	}
@@ -84,7 +90,7 @@ func (h *Handler) DownloadVolumeArchive(w http.ResponseWriter, r *http.Request)
 	// 从 MinIO 下载
 	reader, err := h.minio.Download(ctx, *account.VolumeArchiveKey)
 	if err != nil {
-		log.Printf("[auth] Download volume archive error: %v", err)
+		slog.ErrorContext(ctx, "auth", "detail", "Download volume archive error", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to download volume archive")
 		return
 	}
@@ -93,6 +99,6 @@ func (h *Handler) DownloadVolumeArchive(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/gzip")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.tar.gz", id))
 	if _, err := io.Copy(w, reader); err != nil {
-		log.Printf("[auth] Stream volume archive error: %v", err)
+		slog.ErrorContext(ctx, "auth", "detail", "Stream volume archive error", "error", err)
 	}
 }
@@ -1,7 +1,7 @@
 package operation
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 
 	"agents-admin/internal/shared/model"
@@ -25,7 +25,7 @@ func (h *Handler) GetNodeActions(w http.ResponseWriter, r *http.Request) {
 
 	actions, err := h.store.ListActionsByNode(ctx, nodeID, status)
 	if err != nil {
-		log.Printf("[operation] ListActionsByNode error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "ListActionsByNode", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list actions")
 		return
 	}
@@ -1,7 +1,7 @@
 package operation
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 )
@@ -24,7 +24,7 @@ func (h *Handler) ListOperations(w http.ResponseWriter, r *http.Request) {
 
 	ops, err := h.store.ListOperations(ctx, opType, status, limit, offset)
 	if err != nil {
-		log.Printf("[operation] ListOperations error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "ListOperations", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list operations")
 		return
 	}
@@ -41,7 +41,7 @@ func (h *Handler) GetOperation(w http.ResponseWriter, r *http.Request) {
 
 	op, err := h.store.GetOperation(ctx, id)
 	if err != nil {
-		log.Printf("[operation] GetOperation error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "GetOperation", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get operation")
 		return
 	}
@@ -53,7 +53,7 @@ func (h *Handler) GetOperation(w http.ResponseWriter, r *http.Request) {
 	// 加载关联的 Actions
 	actions, err := h.store.ListActionsByOperation(ctx, id)
 	if err != nil {
-		log.Printf("[operation] ListActionsByOperation error: %v", err)
+		slog.ErrorContext(ctx, "operation", "op", "ListActionsByOperation", "error", err)
 	}
 	op.Actions = actions
 
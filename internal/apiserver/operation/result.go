@@ -3,7 +3,7 @@ package operation
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 
 	"agents-admin/internal/shared/model"
 )
@@ -24,6 +24,6 @@ func (h *Handler) handleActionSuccess(ctx context.Context, op *model.Operation,
 	// case model.OperationTypeRuntimeCreate, ...:
 	//     h.runtimeHandler.HandleRuntimeSuccess(ctx, op, resultJSON)
 	default:
-		log.Printf("[operation] Unhandled success for operation type: %s", op.Type)
+		slog.WarnContext(ctx, "operation.result.unhandled", "operation_id", op.ID, "type", op.Type)
 	}
 }
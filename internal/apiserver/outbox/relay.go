@@ -0,0 +1,193 @@
+// Package outbox 事务发件箱中继
+//
+// 业务数据与待发布的消息在同一数据库事务中写入（storage.OutboxStore），Relay 认领未投递的消息并按 Topic 投递：
+//   - 事务提交后调用 Notify 立即唤醒投递，正常情况下没有额外延迟
+//   - 进程在提交与投递之间崩溃时，消息留在发件箱中，由任一实例的下一轮轮询补投
+//   - 投递失败按尝试次数指数退避重试（1s、2s、4s…，最长 5 分钟）
+//
+// 投递语义为至少一次：租期内未确认的消息会被再次认领，消费方需幂等
+// （调度器取到不存在或非 queued 的 Run 时直接跳过）。
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// maxBatchesPerRound 单轮最多认领的批次数，避免积压时一轮占用过久
+	maxBatchesPerRound = 10
+
+	// maxRetryDelay 投递失败的最大重试间隔
+	maxRetryDelay = 5 * time.Minute
+
+	// cleanupInterval 清理已投递消息的间隔
+	cleanupInterval = time.Hour
+)
+
+// Store 发件箱中继需要的存储接口
+type Store interface {
+	ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error)
+	MarkOutboxPublished(ctx context.Context, id string) error
+	MarkOutboxFailed(ctx context.Context, id, errMsg string, retryAt time.Time) error
+	DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Publisher 投递一条消息（返回 nil 表示投递成功）
+type Publisher func(ctx context.Context, msg *model.OutboxMessage) error
+
+// Config 中继配置，零值字段使用默认值
+type Config struct {
+	Interval  time.Duration // 轮询间隔（默认 2s）
+	Lease     time.Duration // 认领租期，超时未确认的消息可被再次认领（默认 30s）
+	BatchSize int           // 每批认领的消息数（默认 100）
+	Retention time.Duration // 已投递消息的保留时长（默认 24h）
+}
+
+// Relay 发件箱中继
+type Relay struct {
+	store      Store
+	cfg        Config
+	publishers map[string]Publisher
+	wake       chan struct{}
+	cleanedAt  time.Time
+}
+
+// NewRelay 创建发件箱中继
+func NewRelay(store Store, cfg Config) *Relay {
+	if cfg.Interval <= 0 {
+		cfg.Interval = 2 * time.Second
+	}
+	if cfg.Lease <= 0 {
+		cfg.Lease = 30 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	return &Relay{store: store, cfg: cfg, publishers: map[string]Publisher{}, wake: make(chan struct{}, 1)}
+}
+
+// Handle 注册 Topic 的投递方式（需在 Start 之前调用）
+func (r *Relay) Handle(topic string, p Publisher) {
+	r.publishers[topic] = p
+}
+
+// Notify 唤醒中继立即投递（事务提交后调用，不阻塞）
+func (r *Relay) Notify() {
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Start 启动投递循环，直到 ctx 取消
+func (r *Relay) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "outbox.start", "interval", r.cfg.Interval, "lease", r.cfg.Lease, "batch", r.cfg.BatchSize)
+	ticker := time.NewTicker(r.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		r.RunOnce(ctx, time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.wake:
+		}
+	}
+}
+
+// RunOnce 认领并投递到期的消息，返回投递成功的条数
+func (r *Relay) RunOnce(ctx context.Context, now time.Time) int {
+	published := 0
+	for i := 0; i < maxBatchesPerRound; i++ {
+		msgs, err := r.store.ClaimOutboxMessages(ctx, r.cfg.BatchSize, r.cfg.Lease)
+		if err != nil {
+			slog.ErrorContext(ctx, "outbox.claim.failed", "error", err)
+		}
+		for _, msg := range msgs {
+			if r.publish(ctx, msg, now) {
+				published++
+			}
+		}
+		if err != nil || len(msgs) < r.cfg.BatchSize {
+			break
+		}
+	}
+
+	if now.Sub(r.cleanedAt) >= cleanupInterval {
+		r.cleanedAt = now
+		if n, err := r.store.DeletePublishedOutbox(ctx, now.Add(-r.cfg.Retention)); err != nil {
+			slog.ErrorContext(ctx, "outbox.cleanup.failed", "error", err)
+		} else if n > 0 {
+			slog.InfoContext(ctx, "outbox.cleanup.success", "deleted", n)
+		}
+	}
+	return published
+}
+
+// publish 投递一条已认领的消息并记录结果
+func (r *Relay) publish(ctx context.Context, msg *model.OutboxMessage, now time.Time) bool {
+	var err error
+	if p, ok := r.publishers[msg.Topic]; ok {
+		err = p(ctx, msg)
+	} else {
+		err = fmt.Errorf("no publisher for topic %q", msg.Topic)
+	}
+	if err != nil {
+		retryAt := now.Add(retryDelay(msg.Attempts))
+		slog.ErrorContext(ctx, "outbox.publish.failed", "id", msg.ID, "topic", msg.Topic, "attempts", msg.Attempts,
+			"retry_at", retryAt.Format(time.RFC3339), "error", err)
+		if err := r.store.MarkOutboxFailed(ctx, msg.ID, err.Error(), retryAt); err != nil {
+			slog.ErrorContext(ctx, "outbox.mark.failed", "id", msg.ID, "error", err)
+		}
+		return false
+	}
+	if err := r.store.MarkOutboxPublished(ctx, msg.ID); err != nil {
+		// 租期到期后会再次投递，消费方幂等
+		slog.ErrorContext(ctx, "outbox.mark.failed", "id", msg.ID, "error", err)
+	}
+	return true
+}
+
+// retryDelay 第 attempts 次投递失败后的重试间隔
+func retryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 9 {
+		return maxRetryDelay
+	}
+	return min(time.Second<<(attempts-1), maxRetryDelay)
+}
+
+// NewMessage 创建立即可投递的发件箱消息
+func NewMessage(topic string, payload interface{}) (*model.OutboxMessage, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	return &model.OutboxMessage{
+		ID:          generateID("outbox"),
+		Topic:       topic,
+		Payload:     data,
+		AvailableAt: now,
+		CreatedAt:   now,
+	}, nil
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
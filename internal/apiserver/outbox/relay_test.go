@@ -0,0 +1,131 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存发件箱：按可用时间与租期模拟认领
+type memStore struct {
+	msgs    map[string]*model.OutboxMessage
+	deleted int
+}
+
+func (m *memStore) ClaimOutboxMessages(_ context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	now := time.Now()
+	var claimed []*model.OutboxMessage
+	for _, msg := range m.msgs {
+		if len(claimed) == limit {
+			break
+		}
+		if msg.PublishedAt == nil && !msg.AvailableAt.After(now) {
+			msg.Attempts++
+			msg.AvailableAt = now.Add(lease)
+			c := *msg
+			claimed = append(claimed, &c)
+		}
+	}
+	return claimed, nil
+}
+
+func (m *memStore) MarkOutboxPublished(_ context.Context, id string) error {
+	now := time.Now()
+	m.msgs[id].PublishedAt = &now
+	return nil
+}
+
+func (m *memStore) MarkOutboxFailed(_ context.Context, id, errMsg string, retryAt time.Time) error {
+	m.msgs[id].LastError = errMsg
+	m.msgs[id].AvailableAt = retryAt
+	return nil
+}
+
+func (m *memStore) DeletePublishedOutbox(_ context.Context, before time.Time) (int64, error) {
+	var n int64
+	for id, msg := range m.msgs {
+		if msg.PublishedAt != nil && msg.PublishedAt.Before(before) {
+			delete(m.msgs, id)
+			n++
+		}
+	}
+	m.deleted += int(n)
+	return n, nil
+}
+
+func (m *memStore) add(t *testing.T, topic string) *model.OutboxMessage {
+	t.Helper()
+	msg, err := NewMessage(topic, model.ScheduleRunPayload{RunID: "run-1", TaskID: "task-1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.AvailableAt = msg.AvailableAt.Add(-time.Second)
+	m.msgs[msg.ID] = msg
+	return msg
+}
+
+func TestRelay_RunOnce(t *testing.T) {
+	store := &memStore{msgs: map[string]*model.OutboxMessage{}}
+	relay := NewRelay(store, Config{})
+
+	var delivered []string
+	fail := errors.New("redis unavailable")
+	relay.Handle(model.OutboxTopicScheduleRun, func(_ context.Context, msg *model.OutboxMessage) error {
+		if fail != nil {
+			return fail
+		}
+		delivered = append(delivered, msg.ID)
+		return nil
+	})
+
+	msg := store.add(t, model.OutboxTopicScheduleRun)
+	unknown := store.add(t, "unknown.topic")
+
+	now := time.Now()
+	if n := relay.RunOnce(context.Background(), now); n != 0 {
+		t.Fatalf("投递成功条数 = %d, 期望 0", n)
+	}
+	got := store.msgs[msg.ID]
+	if got.Attempts != 1 || got.LastError != fail.Error() {
+		t.Errorf("失败消息 attempts=%d last_error=%q", got.Attempts, got.LastError)
+	}
+	if !got.AvailableAt.Equal(now.Add(time.Second)) {
+		t.Errorf("首次失败后重试时间 = %s, 期望 1s 后", got.AvailableAt.Sub(now))
+	}
+	if store.msgs[unknown.ID].LastError == "" {
+		t.Error("未注册 Topic 的消息应记录失败原因")
+	}
+
+	// 未到重试时间不会再次认领
+	fail = nil
+	if n := relay.RunOnce(context.Background(), now); n != 0 {
+		t.Errorf("重试时间前投递条数 = %d, 期望 0", n)
+	}
+
+	got.AvailableAt = now.Add(-time.Millisecond)
+	if n := relay.RunOnce(context.Background(), now); n != 1 {
+		t.Fatalf("重试投递条数 = %d, 期望 1", n)
+	}
+	if len(delivered) != 1 || delivered[0] != msg.ID || got.PublishedAt == nil {
+		t.Errorf("delivered=%v published_at=%v", delivered, got.PublishedAt)
+	}
+
+	// 已投递消息超过保留时长后清理（每小时一次）
+	relay.RunOnce(context.Background(), now.Add(25*time.Hour))
+	if store.deleted != 1 {
+		t.Errorf("清理条数 = %d, 期望 1", store.deleted)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	for attempts, want := range map[int]time.Duration{
+		0: time.Second, 1: time.Second, 2: 2 * time.Second, 9: 256 * time.Second, 10: maxRetryDelay, 100: maxRetryDelay,
+	} {
+		if got := retryDelay(attempts); got != want {
+			t.Errorf("retryDelay(%d) = %s, 期望 %s", attempts, got, want)
+		}
+	}
+}
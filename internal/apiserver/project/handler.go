@@ -0,0 +1,197 @@
+// Package project 项目领域 - HTTP 处理
+//
+// 项目为其下任务提供默认 Agent 类型、账号池与安全配置，并定义任务能否覆盖这些默认值。
+// 项目的创建、修改、删除仅限管理员；无认证模式下（无用户会话）不做角色限制。
+package project
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// Store 定义项目 handler 需要的存储接口（用于测试 mock）
+type Store interface {
+	CreateProject(ctx context.Context, project *model.Project) error
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	ListProjects(ctx context.Context) ([]*model.Project, error)
+	UpdateProject(ctx context.Context, project *model.Project) error
+	DeleteProject(ctx context.Context, id string) error
+}
+
+// Handler 项目领域 HTTP 处理器
+type Handler struct {
+	store Store
+}
+
+// NewHandler 创建项目处理器
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes 注册项目相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/projects", h.List)
+	mux.HandleFunc("POST /api/v1/projects", auth.AdminOnly(h.Create))
+	mux.HandleFunc("GET /api/v1/projects/{id}", h.Get)
+	mux.HandleFunc("PUT /api/v1/projects/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/projects/{id}", auth.AdminOnly(h.Delete))
+}
+
+// List 获取项目列表
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	projects, err := h.store.ListProjects(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "project", "op", "ListProjects", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list projects")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"projects": projects})
+}
+
+// Create 创建项目
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var project model.Project
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	project.Name = strings.TrimSpace(project.Name)
+	if err := project.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if h.nameTaken(w, r, project.Name, "") {
+		return
+	}
+
+	now := time.Now()
+	project.ID = generateID("proj")
+	project.CreatedAt = now
+	project.UpdatedAt = now
+	if err := h.store.CreateProject(r.Context(), &project); err != nil {
+		slog.ErrorContext(r.Context(), "project", "op", "CreateProject", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create project")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "project.created", "project_id", project.ID, "name", project.Name)
+	writeJSON(w, http.StatusCreated, project)
+}
+
+// Get 获取项目详情
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	project, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+// Update 更新项目（整体替换可配置字段）
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	var project model.Project
+	if err := json.NewDecoder(r.Body).Decode(&project); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	project.Name = strings.TrimSpace(project.Name)
+	if project.Name == "" {
+		project.Name = existing.Name
+	}
+	if err := project.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if project.Name != existing.Name && h.nameTaken(w, r, project.Name, existing.ID) {
+		return
+	}
+
+	project.ID = existing.ID
+	project.CreatedAt = existing.CreatedAt
+	project.UpdatedAt = time.Now()
+	if err := h.store.UpdateProject(r.Context(), &project); err != nil {
+		slog.ErrorContext(r.Context(), "project", "op", "UpdateProject", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update project")
+		return
+	}
+	writeJSON(w, http.StatusOK, project)
+}
+
+// Delete 删除项目（已关联的任务保留，project_id 置空）
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.store.DeleteProject(r.Context(), id); err != nil {
+		slog.ErrorContext(r.Context(), "project", "op", "DeleteProject", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete project")
+		return
+	}
+	slog.InfoContext(r.Context(), "project.deleted", "project_id", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// load 按路径参数加载项目，不存在时写入 404
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) (*model.Project, bool) {
+	id := r.PathValue("id")
+	project, err := h.store.GetProject(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "project", "op", "GetProject", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get project")
+		return nil, false
+	}
+	if project == nil {
+		writeError(w, http.StatusNotFound, "project not found")
+		return nil, false
+	}
+	return project, true
+}
+
+// nameTaken 检查名称是否已被其他项目使用，已占用时写入 409
+func (h *Handler) nameTaken(w http.ResponseWriter, r *http.Request, name, selfID string) bool {
+	projects, err := h.store.ListProjects(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "project", "op", "ListProjects", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to check project name")
+		return true
+	}
+	for _, p := range projects {
+		if p.Name == name && p.ID != selfID {
+			writeError(w, http.StatusConflict, "project name already exists")
+			return true
+		}
+	}
+	return false
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,162 @@
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存项目存储
+type mockStore struct {
+	projects map[string]*model.Project
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{projects: make(map[string]*model.Project)}
+}
+
+func (m *mockStore) CreateProject(_ context.Context, p *model.Project) error {
+	m.projects[p.ID] = p
+	return nil
+}
+func (m *mockStore) GetProject(_ context.Context, id string) (*model.Project, error) {
+	return m.projects[id], nil
+}
+func (m *mockStore) ListProjects(_ context.Context) ([]*model.Project, error) {
+	var list []*model.Project
+	for _, p := range m.projects {
+		list = append(list, p)
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateProject(_ context.Context, p *model.Project) error {
+	m.projects[p.ID] = p
+	return nil
+}
+func (m *mockStore) DeleteProject(_ context.Context, id string) error {
+	delete(m.projects, id)
+	return nil
+}
+
+// newTestMux 注册路由，以无认证模式（未配置 JWT 密钥）处理请求
+func newTestMux(store Store) http.Handler {
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+	return auth.Middleware(auth.Config{})(mux)
+}
+
+func withUser(req *http.Request, role string) *http.Request {
+	return req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: role}))
+}
+
+func TestCreateProject(t *testing.T) {
+	store := newMockStore()
+	mux := newTestMux(store)
+
+	body := `{"name":"backend","default_agent_type":"qwen-code","allowed_agent_types":["qwen-code"],"account_pool":["acc-1"],"overrides":{"account":"deny"}}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, withUser(httptest.NewRequest("POST", "/api/v1/projects", strings.NewReader(body)), "admin"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created model.Project
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if created.ID == "" || created.Overrides.Account != model.OverrideDeny {
+		t.Errorf("created = %+v", created)
+	}
+
+	// 名称重复
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/projects", strings.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("重复名称 status = %d, 期望 409", w.Code)
+	}
+}
+
+func TestCreateProject_Validation(t *testing.T) {
+	mux := newTestMux(newMockStore())
+	cases := []string{
+		`{"name":""}`,
+		`{"name":"p","default_agent_type":"codex","allowed_agent_types":["qwen-code"]}`,
+		`{"name":"p","default_security":{"policy":"permissive"},"allowed_security_policies":["strict"]}`,
+		`{"name":"p","overrides":{"security":"maybe"}}`,
+		`{"name":"p","result_publishing":{"enabled":true,"mode":"email"}}`,
+		`{"name":"p","result_publishing":{"enabled":true,"comment_template":"{{.Status"}}`,
+		`{"name":"p","retention":{"hot_days":-1}}`,
+	}
+	for _, body := range cases {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/projects", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, 期望 400", body, w.Code)
+		}
+	}
+}
+
+func TestProjectWrites_AdminOnly(t *testing.T) {
+	store := newMockStore()
+	store.projects["proj-1"] = &model.Project{ID: "proj-1", Name: "backend"}
+	mux := newTestMux(store)
+
+	requests := []*http.Request{
+		httptest.NewRequest("POST", "/api/v1/projects", strings.NewReader(`{"name":"x"}`)),
+		httptest.NewRequest("PUT", "/api/v1/projects/proj-1", strings.NewReader(`{"name":"x"}`)),
+		httptest.NewRequest("DELETE", "/api/v1/projects/proj-1", nil),
+	}
+	for _, req := range requests {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, withUser(req, "user"))
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: status = %d, 期望 403", req.Method, req.URL.Path, w.Code)
+		}
+	}
+
+	// 节点凭证不能修改项目
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("PUT", "/api/v1/projects/proj-1", strings.NewReader(`{"name":"x"}`))
+	mux.ServeHTTP(w, req.WithContext(auth.WithNodeIdentity(req.Context(), "node-1")))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("node PUT status = %d, 期望 403", w.Code)
+	}
+
+	// 普通用户可读取
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, withUser(httptest.NewRequest("GET", "/api/v1/projects/proj-1", nil), "user"))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET status = %d", w.Code)
+	}
+}
+
+func TestUpdateProject(t *testing.T) {
+	store := newMockStore()
+	store.projects["proj-1"] = &model.Project{ID: "proj-1", Name: "backend"}
+	store.projects["proj-2"] = &model.Project{ID: "proj-2", Name: "frontend"}
+	mux := newTestMux(store)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/projects/proj-1", strings.NewReader(`{"default_agent_type":"claude"}`)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if p := store.projects["proj-1"]; p.Name != "backend" || p.DefaultAgentType != "claude" {
+		t.Errorf("updated = %+v", p)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/projects/proj-1", strings.NewReader(`{"name":"frontend"}`)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("重名 status = %d, 期望 409", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/projects/nope", strings.NewReader(`{}`)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, 期望 404", w.Code)
+	}
+}
@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// defaultHistorySize 每个代理保留的探测历史条数
+	defaultHistorySize = 200
+	// nodeHealthTTL 节点探测结果的有效期，超过后不计入健康汇总（节点下线或停止探测）
+	nodeHealthTTL = 10 * time.Minute
+)
+
+// HealthTracker 汇总各节点上报的代理探测结果
+//
+// 状态保存在内存中：每个代理按节点记录最新结果，并保留最近 defaultHistorySize 条探测历史。
+// API Server 重启后等待节点下一轮探测重新上报。
+type HealthTracker struct {
+	mu      sync.Mutex
+	nodes   map[string]map[string]*model.ProxyNodeHealth // proxy_id → node_id → 最新结果
+	history map[string][]model.ProxyProbeResult          // proxy_id → 探测历史（按上报顺序）
+	now     func() time.Time
+}
+
+// NewHealthTracker 创建代理健康跟踪器
+func NewHealthTracker() *HealthTracker {
+	return &HealthTracker{
+		nodes:   make(map[string]map[string]*model.ProxyNodeHealth),
+		history: make(map[string][]model.ProxyProbeResult),
+		now:     time.Now,
+	}
+}
+
+// Record 记录节点上报的一批探测结果
+func (t *HealthTracker) Record(nodeID string, results []model.ProxyProbeResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, r := range results {
+		r.NodeID = nodeID
+		if r.CheckedAt.IsZero() {
+			r.CheckedAt = t.now()
+		}
+		byNode := t.nodes[r.ProxyID]
+		if byNode == nil {
+			byNode = make(map[string]*model.ProxyNodeHealth)
+			t.nodes[r.ProxyID] = byNode
+		}
+		h := byNode[nodeID]
+		if h == nil {
+			h = &model.ProxyNodeHealth{NodeID: nodeID}
+			byNode[nodeID] = h
+		}
+		if r.Healthy {
+			h.ConsecutiveFailures = 0
+		} else {
+			h.ConsecutiveFailures++
+		}
+		h.Healthy, h.LatencyMs, h.Error, h.CheckedAt = r.Healthy, r.LatencyMs, r.Error, r.CheckedAt
+
+		history := append(t.history[r.ProxyID], r)
+		if over := len(history) - defaultHistorySize; over > 0 {
+			history = append(history[:0], history[over:]...)
+		}
+		t.history[r.ProxyID] = history
+	}
+}
+
+// Health 汇总代理在各节点上的健康状态（只统计有效期内的结果）
+func (t *HealthTracker) Health(proxyID string) *model.ProxyHealth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	health := &model.ProxyHealth{Status: model.ProxyHealthUnknown, Nodes: []model.ProxyNodeHealth{}}
+	cutoff := t.now().Add(-nodeHealthTTL)
+	var latencySum int64
+	for _, h := range t.nodes[proxyID] {
+		if h.CheckedAt.Before(cutoff) {
+			continue
+		}
+		health.Nodes = append(health.Nodes, *h)
+		health.TotalNodes++
+		if h.Healthy {
+			health.HealthyNodes++
+			latencySum += h.LatencyMs
+		}
+		if health.LastCheckedAt == nil || h.CheckedAt.After(*health.LastCheckedAt) {
+			checkedAt := h.CheckedAt
+			health.LastCheckedAt = &checkedAt
+		}
+	}
+	sort.Slice(health.Nodes, func(i, j int) bool { return health.Nodes[i].NodeID < health.Nodes[j].NodeID })
+
+	switch {
+	case health.TotalNodes == 0:
+	case health.HealthyNodes == health.TotalNodes:
+		health.Status = model.ProxyHealthHealthy
+	case health.HealthyNodes == 0:
+		health.Status = model.ProxyHealthUnhealthy
+	default:
+		health.Status = model.ProxyHealthDegraded
+	}
+	if health.HealthyNodes > 0 {
+		health.AvgLatencyMs = latencySum / int64(health.HealthyNodes)
+	}
+	return health
+}
+
+// History 返回代理最近的探测历史（新的在前），limit <= 0 时返回全部
+func (t *HealthTracker) History(proxyID string, limit int) []model.ProxyProbeResult {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	history := t.history[proxyID]
+	if limit <= 0 || limit > len(history) {
+		limit = len(history)
+	}
+	out := make([]model.ProxyProbeResult, 0, limit)
+	for i := len(history) - 1; i >= 0 && len(out) < limit; i-- {
+		out = append(out, history[i])
+	}
+	return out
+}
+
+// Forget 删除代理的健康记录（代理被删除时调用）
+func (t *HealthTracker) Forget(proxyID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.nodes, proxyID)
+	delete(t.history, proxyID)
+}
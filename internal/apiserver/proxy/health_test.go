@@ -0,0 +1,138 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// memProxyStore 只实现代理查询的存储
+type memProxyStore struct {
+	storage.PersistentStore
+	proxies []*model.Proxy
+}
+
+func (m *memProxyStore) ListProxies(context.Context) ([]*model.Proxy, error) {
+	out := make([]*model.Proxy, 0, len(m.proxies))
+	for _, p := range m.proxies {
+		cp := *p
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+func (m *memProxyStore) GetProxy(_ context.Context, id string) (*model.Proxy, error) {
+	for _, p := range m.proxies {
+		if p.ID == id {
+			cp := *p
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestHealthTracker_Summary(t *testing.T) {
+	tracker := NewHealthTracker()
+	now := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	assert.Equal(t, model.ProxyHealthUnknown, tracker.Health("proxy-1").Status)
+
+	tracker.Record("node-1", []model.ProxyProbeResult{{ProxyID: "proxy-1", Healthy: true, LatencyMs: 100, CheckedAt: now}})
+	tracker.Record("node-2", []model.ProxyProbeResult{{ProxyID: "proxy-1", Healthy: true, LatencyMs: 300, CheckedAt: now}})
+	health := tracker.Health("proxy-1")
+	assert.Equal(t, model.ProxyHealthHealthy, health.Status)
+	assert.Equal(t, int64(200), health.AvgLatencyMs)
+
+	tracker.Record("node-2", []model.ProxyProbeResult{{ProxyID: "proxy-1", Error: "connection refused", CheckedAt: now}})
+	tracker.Record("node-2", []model.ProxyProbeResult{{ProxyID: "proxy-1", Error: "connection refused", CheckedAt: now}})
+	health = tracker.Health("proxy-1")
+	assert.Equal(t, model.ProxyHealthDegraded, health.Status)
+	require.Len(t, health.Nodes, 2)
+	assert.Equal(t, 2, health.Nodes[1].ConsecutiveFailures)
+
+	// 过期的节点结果不计入汇总
+	now = now.Add(nodeHealthTTL + time.Minute)
+	tracker.Record("node-2", []model.ProxyProbeResult{{ProxyID: "proxy-1", Error: "timeout", CheckedAt: now}})
+	health = tracker.Health("proxy-1")
+	assert.Equal(t, model.ProxyHealthUnhealthy, health.Status)
+	assert.Equal(t, 1, health.TotalNodes)
+
+	history := tracker.History("proxy-1", 2)
+	require.Len(t, history, 2)
+	assert.Equal(t, "timeout", history[0].Error)
+	assert.Equal(t, "node-2", history[0].NodeID)
+	assert.Len(t, tracker.History("proxy-1", 0), 5)
+
+	tracker.Forget("proxy-1")
+	assert.Empty(t, tracker.History("proxy-1", 0))
+}
+
+func TestHandler_ProxyHealthRoutes(t *testing.T) {
+	user, pass := "u", "p"
+	store := &memProxyStore{proxies: []*model.Proxy{
+		{ID: "proxy-1", Name: "a", Type: model.ProxyTypeHTTP, Host: "10.0.0.1", Port: 3128, Username: &user, Password: &pass, IsDefault: true, Status: model.ProxyStatusActive},
+		{ID: "proxy-2", Name: "b", Type: model.ProxyTypeSOCKS5, Host: "10.0.0.2", Port: 1080, Status: model.ProxyStatusInactive},
+	}}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/nodes/node-1/proxies", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var nodeResp struct {
+		Proxies []model.NodeProxy `json:"proxies"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &nodeResp))
+	require.Len(t, nodeResp.Proxies, 1)
+	assert.Equal(t, "http://u:p@10.0.0.1:3128", nodeResp.Proxies[0].URL)
+	assert.Contains(t, nodeResp.Proxies[0].Env, "HTTPS_PROXY=http://u:p@10.0.0.1:3128")
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node-1/proxy-health",
+		strings.NewReader(`{"results": [{"healthy": true}]}`)))
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/nodes/node-1/proxy-health",
+		strings.NewReader(`{"results": [{"proxy_id": "proxy-1", "healthy": false, "error": "CONNECT: 407"}]}`)))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/proxies/proxy-1", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var detail struct {
+		ID       string             `json:"id"`
+		Password *string            `json:"password"`
+		Health   *model.ProxyHealth `json:"health"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &detail))
+	assert.Equal(t, "proxy-1", detail.ID)
+	assert.Nil(t, detail.Password)
+	require.NotNil(t, detail.Health)
+	assert.Equal(t, model.ProxyHealthUnhealthy, detail.Health.Status)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/proxies/proxy-1/health?limit=10", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	var healthResp struct {
+		History []model.ProxyProbeResult `json:"history"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &healthResp))
+	require.Len(t, healthResp.History, 1)
+	assert.Equal(t, "node-1", healthResp.History[0].NodeID)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/proxies/proxy-x/health", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
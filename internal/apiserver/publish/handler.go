@@ -0,0 +1,272 @@
+// Package publish Run 结果发布领域 - 发布到 PR/MR
+//
+// 由 PR/MR 触发的 Git 工作空间任务（workspace.git.pull_request 非空），在所属项目启用
+// result_publishing 时，Run 结束后将运行摘要、验证结果（post_run 钩子）、对比页面与产物链接
+// 通过 GitHub / GitLab API 发布到该 PR/MR：
+//   - comment：在 PR/MR 下发表评论，正文使用项目的 comment_template 渲染
+//   - status：为 PR 头部提交设置提交状态
+//
+// API Token 来自项目 result_publishing.credential_ref（为空时使用任务 git.credential_ref）。
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// publishTimeout 单次发布的超时时间
+const publishTimeout = 30 * time.Second
+
+// defaultStatusContext 默认提交状态名称
+const defaultStatusContext = "agents-admin"
+
+// Store 定义结果发布需要的存储接口（用于测试 mock）
+type Store interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	GetCredentialByName(ctx context.Context, name string) (*model.Credential, error)
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+	ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error)
+}
+
+// ineligibleError Run 不满足发布条件（非 PR 触发、项目未启用等），自动发布时静默跳过
+type ineligibleError struct{ reason string }
+
+func (e *ineligibleError) Error() string { return e.reason }
+
+// Handler 结果发布处理器
+type Handler struct {
+	store     Store
+	encrypted bool // 存储层是否加密凭据（fieldcrypt.WrapStore），未启用时无法解析发布凭据
+	client    *http.Client
+}
+
+// NewHandler 创建结果发布处理器，encrypted 表示 store 已由 fieldcrypt.WrapStore 包装
+func NewHandler(store Store, encrypted bool) *Handler {
+	return &Handler{store: store, encrypted: encrypted, client: &http.Client{Timeout: 15 * time.Second}}
+}
+
+// RegisterRoutes 注册结果发布相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/runs/{id}/publish", h.Publish)
+}
+
+// Result 一次发布的结果
+type Result struct {
+	RunID       string `json:"run_id"`
+	PullRequest int    `json:"pull_request"`
+	Comment     string `json:"comment,omitempty"`      // 渲染后的评论正文
+	Commented   bool   `json:"commented"`              // 已发表评论
+	StatusSHA   string `json:"status_sha,omitempty"`   // 已设置状态的提交
+	DryRun      bool   `json:"dry_run,omitempty"`      // 仅渲染，未调用平台 API
+	StatusError string `json:"status_error,omitempty"` // 设置提交状态失败的原因（评论仍可能成功）
+}
+
+// Publish 手动（重新）发布 Run 结果，可用于调试评论模板
+// POST /api/v1/runs/{id}/publish
+//
+// 请求体（可选）: {"dry_run": true} 仅返回渲染后的评论，不调用平台 API
+//
+// 错误响应:
+//   - 404 Not Found: Run 不存在
+//   - 409 Conflict: Run 未结束，或不满足发布条件（非 PR 触发、项目未启用发布）
+//   - 502 Bad Gateway: 平台 API 调用失败
+func (h *Handler) Publish(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		DryRun bool `json:"dry_run"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	run, err := h.store.GetRun(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	if !isRunFinished(run.Status) {
+		writeError(w, http.StatusConflict, "run has not finished")
+		return
+	}
+
+	result, err := h.publish(r.Context(), run, req.DryRun)
+	var ineligible *ineligibleError
+	switch {
+	case errors.As(err, &ineligible):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusBadGateway, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+// RunFinished Run 到达终态时发布结果（注册到 run.Handler.OnRunFinished）
+//
+// 异步执行，不阻塞状态上报；不满足发布条件的 Run 静默跳过。
+func (h *Handler) RunFinished(run *model.Run) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		defer cancel()
+		result, err := h.publish(ctx, run, false)
+		var ineligible *ineligibleError
+		switch {
+		case errors.As(err, &ineligible):
+		case err != nil:
+			slog.ErrorContext(ctx, "publish.failed", "run_id", run.ID, "error", err)
+		default:
+			slog.InfoContext(ctx, "publish.done", "run_id", run.ID, "pull_request", result.PullRequest,
+				"commented", result.Commented, "status_sha", result.StatusSHA)
+		}
+	}()
+}
+
+// publish 校验发布条件，渲染摘要并发布到 PR/MR
+func (h *Handler) publish(ctx context.Context, run *model.Run, dryRun bool) (*Result, error) {
+	task, err := h.store.GetTask(ctx, run.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil || task.Workspace == nil || task.Workspace.Git == nil || task.Workspace.Git.PullRequest == nil {
+		return nil, &ineligibleError{"task was not triggered from a pull request"}
+	}
+	if task.ProjectID == nil {
+		return nil, &ineligibleError{"task does not belong to a project"}
+	}
+	project, err := h.store.GetProject(ctx, *task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil || project.ResultPublishing == nil || !project.ResultPublishing.Enabled {
+		return nil, &ineligibleError{"result publishing is not enabled for the project"}
+	}
+	cfg := project.ResultPublishing
+	git := task.Workspace.Git
+	pr := git.PullRequest
+
+	repo, err := parseRepo(git.URL, cfg.Provider)
+	if err != nil {
+		return nil, err
+	}
+	summary, err := h.buildSummary(ctx, run, task, repo)
+	if err != nil {
+		return nil, fmt.Errorf("build summary: %w", err)
+	}
+	result := &Result{RunID: run.ID, PullRequest: pr.Number, DryRun: dryRun}
+	if cfg.Mode.Comments() {
+		if result.Comment, err = renderComment(cfg.CommentTemplate, summary); err != nil {
+			return nil, err
+		}
+	}
+	if dryRun {
+		return result, nil
+	}
+
+	token, err := h.resolveToken(ctx, cfg.CredentialRef, git.CredentialRef)
+	if err != nil {
+		return nil, err
+	}
+	c := &client{repo: repo, apiURL: repo.apiBase(cfg.APIURL), token: token, hc: h.client}
+
+	// 先设置提交状态：状态失败不影响评论
+	if cfg.Mode.Statuses() {
+		sha := pr.HeadSHA
+		if sha == "" {
+			sha = git.Commit
+		}
+		statusContext := cfg.StatusContext
+		if statusContext == "" {
+			statusContext = defaultStatusContext
+		}
+		switch {
+		case sha == "":
+			result.StatusError = "pull_request.head_sha is not set"
+		default:
+			targetURL := summary.ChangesURL
+			if targetURL == "" {
+				targetURL = summary.DiffURL
+			}
+			if err := c.setStatus(ctx, sha, run.Status, summary.statusDescription(), statusContext, targetURL); err != nil {
+				result.StatusError = err.Error()
+			} else {
+				result.StatusSHA = sha
+			}
+		}
+		if result.StatusError != "" && !cfg.Mode.Comments() {
+			return nil, fmt.Errorf("set commit status: %s", result.StatusError)
+		}
+	}
+	if cfg.Mode.Comments() {
+		if err := c.postComment(ctx, pr.Number, result.Comment); err != nil {
+			return nil, fmt.Errorf("post comment: %w", err)
+		}
+		result.Commented = true
+	}
+	return result, nil
+}
+
+// resolveToken 解密发布凭据（项目配置优先，其次任务的 git.credential_ref）
+func (h *Handler) resolveToken(ctx context.Context, refs ...string) (string, error) {
+	name := ""
+	for _, ref := range refs {
+		if ref != "" {
+			name = ref
+			break
+		}
+	}
+	if name == "" {
+		return "", errors.New("no credential configured: set result_publishing.credential_ref")
+	}
+	if !h.encrypted {
+		return "", errors.New("encryption not configured: set MASTER_KEY or encryption.provider")
+	}
+	cred, err := h.store.GetCredentialByName(ctx, name)
+	if err != nil {
+		return "", err
+	}
+	if cred == nil {
+		return "", fmt.Errorf("credential %q not found", name)
+	}
+	if cred.Type != model.CredentialTypeHTTPSToken {
+		return "", fmt.Errorf("credential %q must be of type https_token", name)
+	}
+	return cred.EncryptedSecret, nil
+}
+
+// isRunFinished Run 是否已到达终态
+func isRunFinished(status model.RunStatus) bool {
+	switch status {
+	case model.RunStatusDone, model.RunStatusFailed, model.RunStatusCancelled, model.RunStatusTimeout:
+		return true
+	}
+	return false
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
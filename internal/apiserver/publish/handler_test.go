@@ -0,0 +1,244 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存存储：Run、任务、项目、凭据、事件与产物
+type mockStore struct {
+	runs      map[string]*model.Run
+	tasks     map[string]*model.Task
+	projects  map[string]*model.Project
+	creds     map[string]*model.Credential
+	events    map[string][]*model.Event
+	artifacts map[string][]*model.Artifact
+}
+
+func (m *mockStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	return m.runs[id], nil
+}
+func (m *mockStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	return m.tasks[id], nil
+}
+func (m *mockStore) GetProject(_ context.Context, id string) (*model.Project, error) {
+	return m.projects[id], nil
+}
+func (m *mockStore) GetCredentialByName(_ context.Context, name string) (*model.Credential, error) {
+	return m.creds[name], nil
+}
+func (m *mockStore) GetEventsByRun(_ context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	var list []*model.Event
+	for _, e := range m.events[runID] {
+		if e.Seq > fromSeq && len(list) < limit {
+			list = append(list, e)
+		}
+	}
+	return list, nil
+}
+func (m *mockStore) ListArtifactsByRun(_ context.Context, runID string) ([]*model.Artifact, error) {
+	return m.artifacts[runID], nil
+}
+
+// recorder 记录发往平台 API 的请求
+type recorder struct {
+	mu       sync.Mutex
+	requests []recordedRequest
+}
+
+type recordedRequest struct {
+	Path   string
+	Header http.Header
+	Body   map[string]string
+}
+
+func (rec *recorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]string
+	json.NewDecoder(r.Body).Decode(&body)
+	rec.mu.Lock()
+	rec.requests = append(rec.requests, recordedRequest{Path: r.URL.Path, Header: r.Header, Body: body})
+	rec.mu.Unlock()
+	w.WriteHeader(http.StatusCreated)
+}
+
+func event(seq int, typ model.EventType, payload string) *model.Event {
+	return &model.Event{RunID: "run-1", Seq: seq, Type: string(typ), Payload: json.RawMessage(payload)}
+}
+
+// setup 构造由 PR #7 触发、post_run 验证与 Git 回写均已完成的 Run
+func setup(t *testing.T, repoURL string, cfg *model.ResultPublishConfig) (*mockStore, *http.ServeMux, *Handler) {
+	t.Helper()
+	projectID := "proj-1"
+	started := time.Now().Add(-90 * time.Second)
+	finished := time.Now()
+	store := &mockStore{
+		runs: map[string]*model.Run{"run-1": {
+			ID: "run-1", TaskID: "task-1", Status: model.RunStatusDone, StartedAt: &started, FinishedAt: &finished,
+		}},
+		tasks: map[string]*model.Task{"task-1": {
+			ID: "task-1", Name: "Fix flaky test", ProjectID: &projectID,
+			Workspace: &model.WorkspaceConfig{Type: model.WorkspaceTypeGit, Git: &model.GitConfig{
+				URL: repoURL, Branch: "feature/x", CredentialRef: "repo-token",
+				PullRequest: &model.PullRequestRef{Number: 7, HeadSHA: "abc123"},
+			}},
+		}},
+		projects: map[string]*model.Project{projectID: {ID: projectID, Name: "app", ResultPublishing: cfg}},
+		creds: map[string]*model.Credential{"repo-token": {
+			Name: "repo-token", Type: model.CredentialTypeHTTPSToken, EncryptedSecret: "tok-123",
+		}},
+		events: map[string][]*model.Event{"run-1": {
+			event(1, model.EventTypeHookCompleted, `{"phase":"pre_run","name":"setup","exit_code":0}`),
+			event(2, model.EventTypeHookCompleted, `{"phase":"post_run","name":"unit tests","exit_code":0,"duration_ms":1200}`),
+			event(3, model.EventTypeHookCompleted, `{"phase":"post_run","name":"lint","exit_code":2,"duration_ms":300}`),
+			event(4, model.EventTypeWorkspaceSynced, `{"branch":"agents/run-1","commit_sha":"def456"}`),
+		}},
+		artifacts: map[string][]*model.Artifact{"run-1": {{Name: "report.html", Path: "runs/run-1/report.html"}}},
+	}
+	h := NewHandler(store, true)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return store, mux, h
+}
+
+func TestPublish_GitHubComment(t *testing.T) {
+	rec := &recorder{}
+	api := httptest.NewServer(rec)
+	defer api.Close()
+
+	store, _, h := setup(t, "https://github.com/acme/app.git", &model.ResultPublishConfig{Enabled: true, APIURL: api.URL})
+	result, err := h.publish(context.Background(), store.runs["run-1"], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Commented || result.StatusSHA != "" {
+		t.Errorf("expected comment only, got %+v", result)
+	}
+	if len(rec.requests) != 1 || rec.requests[0].Path != "/repos/acme/app/issues/7/comments" {
+		t.Fatalf("unexpected requests: %+v", rec.requests)
+	}
+	if got := rec.requests[0].Header.Get("Authorization"); got != "Bearer tok-123" {
+		t.Errorf("expected bearer token, got %q", got)
+	}
+	body := rec.requests[0].Body["body"]
+	for _, want := range []string{
+		"run-1", "Fix flaky test", "1m30s",
+		"| unit tests | passed |", "| lint | failed (exit 2) |",
+		"https://github.com/acme/app/compare/feature/x...agents/run-1",
+		"report.html",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("comment missing %q:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, "setup") {
+		t.Error("pre_run hooks should not be listed as verifications")
+	}
+}
+
+func TestPublish_GitLabStatusAndComment(t *testing.T) {
+	rec := &recorder{}
+	api := httptest.NewServer(rec)
+	defer api.Close()
+
+	cfg := &model.ResultPublishConfig{Enabled: true, Mode: model.PublishBoth, APIURL: api.URL, StatusContext: "agents/verify"}
+	store, _, h := setup(t, "git@gitlab.example.com:group/app.git", cfg)
+	store.runs["run-1"].Status = model.RunStatusFailed
+
+	result, err := h.publish(context.Background(), store.runs["run-1"], false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Commented || result.StatusSHA != "abc123" {
+		t.Errorf("expected comment and status, got %+v", result)
+	}
+	if len(rec.requests) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(rec.requests))
+	}
+	status := rec.requests[0]
+	if status.Path != "/projects/group/app/statuses/abc123" || status.Body["state"] != "failed" || status.Body["name"] != "agents/verify" {
+		t.Errorf("unexpected status request: %+v", status)
+	}
+	if !strings.Contains(status.Body["description"], "1/2 checks passed") {
+		t.Errorf("unexpected status description: %q", status.Body["description"])
+	}
+	if status.Header.Get("PRIVATE-TOKEN") != "tok-123" {
+		t.Error("expected PRIVATE-TOKEN header for gitlab")
+	}
+	if rec.requests[1].Path != "/projects/group/app/merge_requests/7/notes" {
+		t.Errorf("unexpected comment path: %s", rec.requests[1].Path)
+	}
+}
+
+func TestPublish_DryRunWithCustomTemplate(t *testing.T) {
+	cfg := &model.ResultPublishConfig{Enabled: true, CommentTemplate: "{{.TaskName}}: {{.Status}} ({{len .Verifications}} checks)"}
+	_, mux, _ := setup(t, "https://github.com/acme/app", cfg)
+
+	req := httptest.NewRequest("POST", "/api/v1/runs/run-1/publish", strings.NewReader(`{"dry_run":true}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var result Result
+	json.Unmarshal(w.Body.Bytes(), &result)
+	if result.Comment != "Fix flaky test: done (2 checks)" || result.Commented {
+		t.Errorf("unexpected dry run result: %+v", result)
+	}
+}
+
+func TestPublish_Ineligible(t *testing.T) {
+	store, mux, _ := setup(t, "https://github.com/acme/app", &model.ResultPublishConfig{Enabled: false})
+
+	publish := func() int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/runs/run-1/publish", nil))
+		return w.Code
+	}
+	if code := publish(); code != http.StatusConflict {
+		t.Errorf("disabled project: expected 409, got %d", code)
+	}
+
+	store.projects["proj-1"].ResultPublishing.Enabled = true
+	store.tasks["task-1"].Workspace.Git.PullRequest = nil
+	if code := publish(); code != http.StatusConflict {
+		t.Errorf("task without pull request: expected 409, got %d", code)
+	}
+
+	store.runs["run-1"].Status = model.RunStatusRunning
+	if code := publish(); code != http.StatusConflict {
+		t.Errorf("unfinished run: expected 409, got %d", code)
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	tests := []struct {
+		url, provider         string
+		wantProvider, wantAPI string
+	}{
+		{"https://github.com/acme/app.git", "", "github", "https://api.github.com"},
+		{"git@github.example.com:acme/app.git", "", "github", "https://github.example.com/api/v3"},
+		{"https://gitlab.com/group/sub/app", "", "gitlab", "https://gitlab.com/api/v4"},
+		{"https://git.internal:8443/team/app.git", "gitlab", "gitlab", "https://git.internal:8443/api/v4"},
+	}
+	for _, tt := range tests {
+		ref, err := parseRepo(tt.url, tt.provider)
+		if err != nil {
+			t.Errorf("%s: %v", tt.url, err)
+			continue
+		}
+		if ref.Provider != tt.wantProvider || ref.apiBase("") != tt.wantAPI {
+			t.Errorf("%s: got provider=%s api=%s", tt.url, ref.Provider, ref.apiBase(""))
+		}
+	}
+	if _, err := parseRepo("https://git.internal/team/app.git", ""); err == nil {
+		t.Error("expected error when provider cannot be detected")
+	}
+}
@@ -0,0 +1,186 @@
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// repoRef 解析后的仓库地址与所在平台
+type repoRef struct {
+	Provider string // github / gitlab
+	Scheme   string // Web 与 API 使用的协议（SSH 地址按 https 处理）
+	Host     string
+	Path     string // owner/repo（无 .git 后缀）
+}
+
+// parseRepo 解析仓库地址（HTTPS 或 SCP 风格 SSH），provider 为空时根据主机名推断
+func parseRepo(rawURL, provider string) (*repoRef, error) {
+	rawURL = strings.TrimSpace(rawURL)
+	ref := &repoRef{Scheme: "https"}
+	if strings.Contains(rawURL, "://") {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid git url: %w", err)
+		}
+		if u.Scheme == "http" {
+			ref.Scheme = "http"
+		}
+		ref.Host = u.Hostname()
+		if u.Scheme != "ssh" && u.Port() != "" {
+			ref.Host = u.Host
+		}
+		ref.Path = u.Path
+	} else {
+		// git@github.com:owner/repo.git
+		at, colon := strings.Index(rawURL, "@"), strings.Index(rawURL, ":")
+		if colon <= at+1 {
+			return nil, fmt.Errorf("invalid git url: %s", rawURL)
+		}
+		ref.Host, ref.Path = rawURL[at+1:colon], rawURL[colon+1:]
+	}
+	ref.Path = strings.TrimSuffix(strings.Trim(ref.Path, "/"), ".git")
+	if ref.Host == "" || ref.Path == "" {
+		return nil, fmt.Errorf("invalid git url: %s", rawURL)
+	}
+
+	ref.Provider = strings.ToLower(provider)
+	if ref.Provider == "" {
+		host := strings.ToLower(ref.Host)
+		switch {
+		case strings.Contains(host, "github"):
+			ref.Provider = "github"
+		case strings.Contains(host, "gitlab"):
+			ref.Provider = "gitlab"
+		default:
+			return nil, fmt.Errorf("cannot detect provider for %s, set result_publishing.provider", ref.Host)
+		}
+	}
+	return ref, nil
+}
+
+// apiBase 计算 API 地址：显式配置优先；GitHub Enterprise 为 /api/v3，GitLab 为 /api/v4
+func (r *repoRef) apiBase(override string) string {
+	if override != "" {
+		return strings.TrimRight(override, "/")
+	}
+	if r.Provider == "gitlab" {
+		return r.Scheme + "://" + r.Host + "/api/v4"
+	}
+	if strings.EqualFold(r.Host, "github.com") {
+		return "https://api.github.com"
+	}
+	return r.Scheme + "://" + r.Host + "/api/v3"
+}
+
+// compareURL 回写分支相对基准分支的 Web 对比页面
+func (r *repoRef) compareURL(base, head string) string {
+	if base == "" || head == "" {
+		return ""
+	}
+	web := r.Scheme + "://" + r.Host + "/" + r.Path
+	if r.Provider == "gitlab" {
+		return web + "/-/compare/" + base + "..." + head
+	}
+	return web + "/compare/" + base + "..." + head
+}
+
+// client GitHub / GitLab 结果发布客户端
+type client struct {
+	repo   *repoRef
+	apiURL string
+	token  string
+	hc     *http.Client
+}
+
+// postComment 在 PR（GitHub Issue 评论）/ MR（GitLab Note）下发表评论
+func (c *client) postComment(ctx context.Context, number int, body string) error {
+	n := strconv.Itoa(number)
+	if c.repo.Provider == "gitlab" {
+		return c.do(ctx, "/projects/"+url.PathEscape(c.repo.Path)+"/merge_requests/"+n+"/notes", map[string]string{"body": body})
+	}
+	return c.do(ctx, "/repos/"+c.repo.Path+"/issues/"+n+"/comments", map[string]string{"body": body})
+}
+
+// setStatus 为提交设置状态
+func (c *client) setStatus(ctx context.Context, sha string, status model.RunStatus, description, statusContext, targetURL string) error {
+	if c.repo.Provider == "gitlab" {
+		payload := map[string]string{
+			"state":       gitlabState(status),
+			"name":        statusContext,
+			"description": description,
+		}
+		if targetURL != "" {
+			payload["target_url"] = targetURL
+		}
+		return c.do(ctx, "/projects/"+url.PathEscape(c.repo.Path)+"/statuses/"+sha, payload)
+	}
+	payload := map[string]string{
+		"state":       githubState(status),
+		"context":     statusContext,
+		"description": description,
+	}
+	if targetURL != "" {
+		payload["target_url"] = targetURL
+	}
+	return c.do(ctx, "/repos/"+c.repo.Path+"/statuses/"+sha, payload)
+}
+
+// githubState Run 状态映射为 GitHub 提交状态（success / failure / error）
+func githubState(status model.RunStatus) string {
+	switch status {
+	case model.RunStatusDone:
+		return "success"
+	case model.RunStatusFailed:
+		return "failure"
+	}
+	return "error"
+}
+
+// gitlabState Run 状态映射为 GitLab 提交状态（success / failed / canceled）
+func gitlabState(status model.RunStatus) string {
+	switch status {
+	case model.RunStatusDone:
+		return "success"
+	case model.RunStatusCancelled:
+		return "canceled"
+	}
+	return "failed"
+}
+
+func (c *client) do(ctx context.Context, path string, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.apiURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.repo.Provider == "gitlab" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	} else {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+		req.Header.Set("Accept", "application/vnd.github+json")
+	}
+
+	resp, err := c.hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s API returned %d: %s", c.repo.Provider, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
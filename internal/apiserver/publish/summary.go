@@ -0,0 +1,197 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// eventBatchSize 收集摘要时分批读取事件的批大小
+const eventBatchSize = 1000
+
+// maxVerificationOutput 评论中每个验证结果附带的输出长度上限
+const maxVerificationOutput = 2000
+
+// defaultCommentTemplate 内置评论模板（Markdown）
+const defaultCommentTemplate = `### agents-admin run ` + "`{{.RunID}}`" + `: {{.Status}}
+
+**Task:** {{.TaskName}}{{if .Duration}} · **Duration:** {{.Duration}}{{end}}
+{{- if .Error}}
+
+**Error:** {{.Error}}
+{{- end}}
+{{- if .Verifications}}
+
+| Verification | Result | Duration |
+|---|---|---|
+{{- range .Verifications}}
+| {{.Name}} | {{if .Passed}}passed{{else}}failed (exit {{.ExitCode}}){{end}} | {{.DurationMS}}ms |
+{{- end}}
+{{- end}}
+{{- if or .DiffURL .ChangesURL}}
+
+{{if .DiffURL}}[View diff]({{.DiffURL}}){{end}}{{if and .DiffURL .ChangesURL}} · {{end}}{{if .ChangesURL}}[Follow-up PR]({{.ChangesURL}}){{end}}
+{{- end}}
+{{- if .Artifacts}}
+
+**Artifacts:**
+{{- range .Artifacts}}
+- {{.Name}}: {{.Path}}
+{{- end}}
+{{- end}}
+`
+
+// Summary 评论模板的数据（comment_template 中以 {{.字段}} 引用）
+type Summary struct {
+	RunID     string `json:"run_id"`
+	TaskID    string `json:"task_id"`
+	TaskName  string `json:"task_name"`
+	Status    string `json:"status"`
+	Succeeded bool   `json:"succeeded"`
+	Duration  string `json:"duration,omitempty"`
+	Error     string `json:"error,omitempty"`
+
+	// Verifications post_run 钩子的执行结果
+	Verifications []Verification `json:"verifications,omitempty"`
+
+	// Branch / CommitSHA Git 结果回写推送的分支与提交
+	Branch    string `json:"branch,omitempty"`
+	CommitSHA string `json:"commit_sha,omitempty"`
+
+	// DiffURL 回写分支相对 PR 分支的对比页面
+	DiffURL string `json:"diff_url,omitempty"`
+
+	// ChangesURL 结果回写创建的 PR/MR 地址
+	ChangesURL string `json:"changes_url,omitempty"`
+
+	Artifacts []*model.Artifact `json:"artifacts,omitempty"`
+}
+
+// Verification 单个验证（post_run 钩子）结果
+type Verification struct {
+	Name       string `json:"name"`
+	Passed     bool   `json:"passed"`
+	ExitCode   int    `json:"exit_code"`
+	DurationMS int64  `json:"duration_ms"`
+	Output     string `json:"output,omitempty"`
+}
+
+// buildSummary 汇总 Run 状态、验证结果、回写分支与产物
+func (h *Handler) buildSummary(ctx context.Context, run *model.Run, task *model.Task, repo *repoRef) (*Summary, error) {
+	s := &Summary{
+		RunID:     run.ID,
+		TaskID:    task.ID,
+		TaskName:  task.Name,
+		Status:    string(run.Status),
+		Succeeded: run.Status == model.RunStatusDone,
+	}
+	if run.StartedAt != nil && run.FinishedAt != nil {
+		s.Duration = run.FinishedAt.Sub(*run.StartedAt).Round(time.Second).String()
+	}
+	if run.Error != nil {
+		s.Error = *run.Error
+	}
+
+	fromSeq := 0
+	for {
+		events, err := h.store.GetEventsByRun(ctx, run.ID, fromSeq, eventBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			s.applyEvent(e)
+			fromSeq = e.Seq
+		}
+		if len(events) < eventBatchSize {
+			break
+		}
+	}
+
+	if s.Branch != "" && repo != nil {
+		s.DiffURL = repo.compareURL(task.Workspace.Git.Branch, s.Branch)
+	}
+
+	artifacts, err := h.store.ListArtifactsByRun(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	s.Artifacts = artifacts
+	return s, nil
+}
+
+// applyEvent 从 hook_completed / workspace_synced 事件提取验证结果与回写信息
+func (s *Summary) applyEvent(e *model.Event) {
+	switch model.EventType(e.Type) {
+	case model.EventTypeHookCompleted:
+		var p struct {
+			Phase      string `json:"phase"`
+			Name       string `json:"name"`
+			ExitCode   int    `json:"exit_code"`
+			Output     string `json:"output"`
+			DurationMS int64  `json:"duration_ms"`
+			Error      string `json:"error"`
+		}
+		if json.Unmarshal(e.Payload, &p) != nil || p.Phase != string(model.HookPhasePostRun) {
+			return
+		}
+		output := p.Output
+		if len(output) > maxVerificationOutput {
+			output = output[len(output)-maxVerificationOutput:]
+		}
+		s.Verifications = append(s.Verifications, Verification{
+			Name:       p.Name,
+			Passed:     p.ExitCode == 0 && p.Error == "",
+			ExitCode:   p.ExitCode,
+			DurationMS: p.DurationMS,
+			Output:     output,
+		})
+	case model.EventTypeWorkspaceSynced:
+		var p struct {
+			Branch    string `json:"branch"`
+			CommitSHA string `json:"commit_sha"`
+			PRURL     string `json:"pr_url"`
+		}
+		if json.Unmarshal(e.Payload, &p) == nil {
+			s.Branch, s.CommitSHA, s.ChangesURL = p.Branch, p.CommitSHA, p.PRURL
+		}
+	}
+}
+
+// statusDescription 提交状态的简短描述（GitHub 限制 140 字符）
+func (s *Summary) statusDescription() string {
+	desc := fmt.Sprintf("Run %s %s", s.RunID, s.Status)
+	if n := len(s.Verifications); n > 0 {
+		passed := 0
+		for _, v := range s.Verifications {
+			if v.Passed {
+				passed++
+			}
+		}
+		desc += fmt.Sprintf(", %d/%d checks passed", passed, n)
+	}
+	if len(desc) > 140 {
+		desc = desc[:140]
+	}
+	return desc
+}
+
+// renderComment 使用项目模板（为空时使用内置模板）渲染评论正文
+func renderComment(tmpl string, s *Summary) (string, error) {
+	if strings.TrimSpace(tmpl) == "" {
+		tmpl = defaultCommentTemplate
+	}
+	t, err := template.New("comment").Parse(tmpl)
+	if err != nil {
+		return "", fmt.Errorf("invalid comment_template: %w", err)
+	}
+	var b strings.Builder
+	if err := t.Execute(&b, s); err != nil {
+		return "", fmt.Errorf("render comment_template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,281 @@
+// Package ratelimit API 请求限流与登录暴力破解防护
+//
+// 令牌桶与失败计数保存在 Redis，多个 API Server 副本共享：
+//   - 按客户端 IP：经过认证中间件的全部请求（已认证的节点请求与节点公开接口除外）
+//   - 按节点：未携带节点凭证的心跳与节点加入（POST /api/v1/nodes/heartbeat、/api/v1/nodes/join）
+//     按请求体中的 node_id 限流，NAT 之后的节点集群共用出口 IP 也不会互相挤占
+//   - 按用户：已认证用户的请求
+//   - 登录：POST /api/v1/auth/login 与 /api/v1/auth/login/2fa 按 IP 使用更严格的令牌桶；
+//     同一邮箱在计数窗口内连续登录失败达到上限后锁定一段时间，锁定期间即使密码正确也拒绝
+//
+// 超出限制返回 429，Retry-After 响应头为建议的重试间隔（秒）。Redis 不可用时放行，只记录日志。
+package ratelimit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"math"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+)
+
+// 限流范围（429 响应与指标的 scope 标签）
+const (
+	ScopeIP      = "ip"
+	ScopeNode    = "node"
+	ScopeUser    = "user"
+	ScopeLogin   = "login"
+	ScopeLockout = "lockout"
+)
+
+// maxLoginBody 登录请求体读取上限（读取邮箱用于失败计数）
+const maxLoginBody = 64 << 10
+
+// Store 限流需要的缓存接口（cache.RateLimitCache）
+type Store interface {
+	TakeRateToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+	RecordAuthFailure(ctx context.Context, key string, maxFailures int, window, lockout time.Duration) (time.Duration, error)
+	AuthLockout(ctx context.Context, key string) (time.Duration, error)
+	ResetAuthFailures(ctx context.Context, key string) error
+}
+
+// Bucket 令牌桶：每秒补充 Rate 个令牌，最多累积 Burst 个（Rate <= 0 时不限制）
+type Bucket struct {
+	Rate  float64
+	Burst int
+}
+
+func (b Bucket) enabled() bool {
+	return b.Rate > 0
+}
+
+// Config 限流配置
+type Config struct {
+	IP    Bucket // 每个客户端 IP；未携带节点凭证的心跳与节点加入按每个 node_id
+	User  Bucket // 每个已认证用户
+	Login Bucket // 登录接口，每个客户端 IP
+
+	MaxLoginFailures int           // 同一邮箱连续登录失败的上限（<= 0 时不锁定）
+	FailureWindow    time.Duration // 失败计数窗口
+	Lockout          time.Duration // 锁定时长
+
+	// TrustForwardedFor 以 X-Forwarded-For 的第一个地址作为客户端 IP（仅在可信反向代理之后开启）
+	TrustForwardedFor bool
+}
+
+// DefaultConfig 默认限流配置
+func DefaultConfig() Config {
+	return Config{
+		IP:               Bucket{Rate: 20, Burst: 100},
+		User:             Bucket{Rate: 10, Burst: 50},
+		Login:            Bucket{Rate: 0.2, Burst: 5},
+		MaxLoginFailures: 5,
+		FailureWindow:    15 * time.Minute,
+		Lockout:          15 * time.Minute,
+	}
+}
+
+// Stats 各范围被限流的请求数
+type Stats struct {
+	Throttled map[string]int64
+}
+
+// Limiter 请求限流器
+type Limiter struct {
+	store Store
+	cfg   Config
+
+	mu        sync.Mutex
+	throttled map[string]int64
+}
+
+// New 创建限流器
+func New(store Store, cfg Config) *Limiter {
+	if cfg.FailureWindow <= 0 {
+		cfg.FailureWindow = DefaultConfig().FailureWindow
+	}
+	if cfg.Lockout <= 0 {
+		cfg.Lockout = DefaultConfig().Lockout
+	}
+	return &Limiter{store: store, cfg: cfg, throttled: make(map[string]int64)}
+}
+
+// Stats 返回被限流的请求数
+func (l *Limiter) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	st := Stats{Throttled: make(map[string]int64, len(l.throttled))}
+	for scope, n := range l.throttled {
+		st.Throttled[scope] = n
+	}
+	return st
+}
+
+// Middleware 限流中间件（l 为 nil 时原样返回）
+//
+// 需放在认证中间件之内，以便按用户限流并跳过节点请求。
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	if l == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.GetNodeIdentity(r.Context()) != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		ip := l.clientIP(r)
+		if nodeID := publicNodeID(r); nodeID != "" {
+			if l.take(w, r, ScopeNode, "node:"+nodeID, l.cfg.IP) {
+				next.ServeHTTP(w, r)
+			}
+			return
+		}
+		if !l.take(w, r, ScopeIP, "ip:"+ip, l.cfg.IP) {
+			return
+		}
+		if user := auth.GetAuthUser(r.Context()); user != nil {
+			if !l.take(w, r, ScopeUser, "user:"+user.ID, l.cfg.User) {
+				return
+			}
+		}
+		if r.Method == http.MethodPost && (r.URL.Path == "/api/v1/auth/login" || r.URL.Path == "/api/v1/auth/login/2fa") {
+			if !l.take(w, r, ScopeLogin, "login:"+ip, l.cfg.Login) {
+				return
+			}
+			if r.URL.Path == "/api/v1/auth/login" && l.cfg.MaxLoginFailures > 0 {
+				l.guardLogin(w, r, next)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// publicNodeID 节点公开接口（心跳、节点加入）请求体中的 node_id，其他请求或缺少 node_id 时返回空
+//
+// 请求体大小已由外层的请求体限制中间件约束，读取后原样放回。
+func publicNodeID(r *http.Request) string {
+	if r.Method != http.MethodPost || (r.URL.Path != "/api/v1/nodes/heartbeat" && r.URL.Path != "/api/v1/nodes/join") || r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var req struct {
+		NodeID string `json:"node_id"`
+	}
+	_ = json.Unmarshal(body, &req)
+	return req.NodeID
+}
+
+// take 从令牌桶取令牌，不足时写入 429 并返回 false
+func (l *Limiter) take(w http.ResponseWriter, r *http.Request, scope, key string, b Bucket) bool {
+	if !b.enabled() {
+		return true
+	}
+	ok, wait, err := l.store.TakeRateToken(r.Context(), key, b.Rate, b.Burst)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "ratelimit.take.failed", "key", key, "error", err)
+		return true
+	}
+	if ok {
+		return true
+	}
+	l.reject(w, scope, wait, "rate limit exceeded")
+	return false
+}
+
+// guardLogin 登录失败计数：锁定中直接拒绝，失败（401）时累计，成功时清零
+func (l *Limiter) guardLogin(w http.ResponseWriter, r *http.Request, next http.Handler) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxLoginBody))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	var req struct {
+		Email string `json:"email"`
+	}
+	_ = json.Unmarshal(body, &req)
+	email := strings.ToLower(strings.TrimSpace(req.Email))
+	if email == "" {
+		next.ServeHTTP(w, r)
+		return
+	}
+
+	key := "login:" + email
+	if wait, err := l.store.AuthLockout(r.Context(), key); err != nil {
+		slog.ErrorContext(r.Context(), "ratelimit.lockout.failed", "key", key, "error", err)
+	} else if wait > 0 {
+		l.reject(w, ScopeLockout, wait, "too many failed login attempts, try again later")
+		return
+	}
+
+	rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	next.ServeHTTP(rec, r)
+
+	ctx := context.WithoutCancel(r.Context())
+	switch {
+	case rec.status == http.StatusUnauthorized:
+		locked, err := l.store.RecordAuthFailure(ctx, key, l.cfg.MaxLoginFailures, l.cfg.FailureWindow, l.cfg.Lockout)
+		if err != nil {
+			slog.ErrorContext(ctx, "ratelimit.failure.record_failed", "key", key, "error", err)
+		} else if locked > 0 {
+			slog.InfoContext(ctx, "ratelimit.lockout", "email", email, "ip", l.clientIP(r), "duration", locked)
+		}
+	case rec.status < 300:
+		if err := l.store.ResetAuthFailures(ctx, key); err != nil {
+			slog.ErrorContext(ctx, "ratelimit.failure.reset_failed", "key", key, "error", err)
+		}
+	}
+}
+
+// reject 写入 429 响应并计数
+func (l *Limiter) reject(w http.ResponseWriter, scope string, wait time.Duration, message string) {
+	l.mu.Lock()
+	l.throttled[scope]++
+	l.mu.Unlock()
+
+	w.Header().Set("Retry-After", fmt.Sprint(int64(math.Max(1, math.Ceil(wait.Seconds())))))
+	writeError(w, http.StatusTooManyRequests, message)
+}
+
+// clientIP 请求的客户端 IP
+func (l *Limiter) clientIP(r *http.Request) string {
+	if l.cfg.TrustForwardedFor {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			if ip := strings.TrimSpace(first); ip != "" {
+				return ip
+			}
+		}
+	}
+	return auth.RemoteHost(r.RemoteAddr)
+}
+
+// statusRecorder 记录响应状态码
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (rw *statusRecorder) WriteHeader(code int) {
+	rw.status = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,203 @@
+package ratelimit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+)
+
+// memStore 内存令牌桶（不补充令牌，便于断言）
+type memStore struct {
+	tokens   map[string]int
+	failures map[string]int
+	locked   map[string]time.Duration
+}
+
+func newMemStore() *memStore {
+	return &memStore{tokens: map[string]int{}, failures: map[string]int{}, locked: map[string]time.Duration{}}
+}
+
+func (m *memStore) TakeRateToken(_ context.Context, key string, _ float64, burst int) (bool, time.Duration, error) {
+	if m.tokens[key] >= burst {
+		return false, 1500 * time.Millisecond, nil
+	}
+	m.tokens[key]++
+	return true, 0, nil
+}
+
+func (m *memStore) RecordAuthFailure(_ context.Context, key string, maxFailures int, _, lockout time.Duration) (time.Duration, error) {
+	m.failures[key]++
+	if m.failures[key] >= maxFailures {
+		m.locked[key] = lockout
+		delete(m.failures, key)
+		return lockout, nil
+	}
+	return 0, nil
+}
+
+func (m *memStore) AuthLockout(_ context.Context, key string) (time.Duration, error) {
+	return m.locked[key], nil
+}
+
+func (m *memStore) ResetAuthFailures(_ context.Context, key string) error {
+	delete(m.failures, key)
+	return nil
+}
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestMiddleware_PerIPAndPerUser(t *testing.T) {
+	l := New(newMemStore(), Config{IP: Bucket{Rate: 1, Burst: 3}, User: Bucket{Rate: 1, Burst: 2}})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	req := func(ip string, user string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+		r.RemoteAddr = ip + ":5000"
+		if user != "" {
+			r = r.WithContext(auth.WithAuthUser(r.Context(), &auth.AuthUser{ID: user}))
+		}
+		return r
+	}
+
+	// 同一用户从不同 IP 请求，按用户的令牌桶限流
+	for i, ip := range []string{"10.0.0.1", "10.0.0.2"} {
+		if w := serve(h, req(ip, "usr-1")); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i, w.Code)
+		}
+	}
+	w := serve(h, req("10.0.0.3", "usr-1"))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "2" {
+		t.Fatalf("expected 429 with Retry-After 2, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	// 匿名请求按 IP 限流
+	for range 3 {
+		serve(h, req("10.0.0.9", ""))
+	}
+	if w := serve(h, req("10.0.0.9", "")); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for ip, got %d", w.Code)
+	}
+
+	// 节点请求不限流
+	nodeReq := req("10.0.0.9", "")
+	nodeReq = nodeReq.WithContext(auth.WithNodeIdentity(nodeReq.Context(), "node-1"))
+	if w := serve(h, nodeReq); w.Code != http.StatusOK {
+		t.Errorf("node request: expected 200, got %d", w.Code)
+	}
+
+	st := l.Stats()
+	if st.Throttled[ScopeUser] != 1 || st.Throttled[ScopeIP] != 1 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
+
+func TestMiddleware_NodeHeartbeatsBehindNAT(t *testing.T) {
+	l := New(newMemStore(), Config{IP: Bucket{Rate: 1, Burst: 3}})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			NodeID string `json:"node_id"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.Method == http.MethodPost && req.NodeID == "" {
+			w.WriteHeader(http.StatusBadRequest) // 请求体须原样传给处理器
+		}
+	}))
+	req := func(path, nodeID string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, path, strings.NewReader(`{"node_id":"`+nodeID+`"}`))
+		r.RemoteAddr = "198.51.100.1:5000"
+		return r
+	}
+
+	// 同一出口 IP 后的 50 个节点各发 3 次心跳，均不受 IP 令牌桶限制
+	for i := range 50 {
+		nodeID := fmt.Sprintf("node-%d", i)
+		for range 3 {
+			if w := serve(h, req("/api/v1/nodes/heartbeat", nodeID)); w.Code != http.StatusOK {
+				t.Fatalf("%s heartbeat: expected 200, got %d", nodeID, w.Code)
+			}
+		}
+	}
+	if w := serve(h, req("/api/v1/nodes/join", "node-new")); w.Code != http.StatusOK {
+		t.Errorf("join: expected 200, got %d", w.Code)
+	}
+
+	// 单个节点仍按 node_id 限流
+	if w := serve(h, req("/api/v1/nodes/heartbeat", "node-0")); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 for node-0, got %d", w.Code)
+	}
+	// 该 IP 的其他请求不受心跳影响
+	other := httptest.NewRequest(http.MethodGet, "/api/v1/tasks", nil)
+	other.RemoteAddr = "198.51.100.1:5000"
+	if w := serve(h, other); w.Code != http.StatusOK {
+		t.Errorf("other request: expected 200, got %d", w.Code)
+	}
+	if st := l.Stats(); st.Throttled[ScopeNode] != 1 || st.Throttled[ScopeIP] != 0 {
+		t.Errorf("unexpected stats: %+v", st)
+	}
+}
+
+func TestMiddleware_LoginLockout(t *testing.T) {
+	store := newMemStore()
+	l := New(store, Config{Login: Bucket{Rate: 1, Burst: 100}, MaxLoginFailures: 3, Lockout: time.Minute})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct{ Email, Password string }
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Password != "right" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	login := func(email, password string) *httptest.ResponseRecorder {
+		body := `{"email":"` + email + `","password":"` + password + `"}`
+		return serve(h, httptest.NewRequest(http.MethodPost, "/api/v1/auth/login", strings.NewReader(body)))
+	}
+
+	// 成功登录清零失败计数
+	login("alice@example.com", "wrong")
+	login("alice@example.com", "wrong")
+	if w := login("alice@example.com", "right"); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	for range 3 {
+		login("Alice@Example.com", "wrong")
+	}
+	w := login("alice@example.com", "right")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Fatalf("expected lockout 429 with Retry-After 60, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := login("bob@example.com", "right"); w.Code != http.StatusOK {
+		t.Errorf("other account should not be locked, got %d", w.Code)
+	}
+	if l.Stats().Throttled[ScopeLockout] != 1 {
+		t.Errorf("unexpected stats: %+v", l.Stats())
+	}
+}
+
+func TestMiddleware_LoginBucketAndForwardedFor(t *testing.T) {
+	l := New(newMemStore(), Config{Login: Bucket{Rate: 1, Burst: 1}, TrustForwardedFor: true})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := func(xff string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/api/v1/auth/login/2fa", strings.NewReader("{}"))
+		r.Header.Set("X-Forwarded-For", xff)
+		return r
+	}
+	if w := serve(h, req("203.0.113.1, 10.0.0.1")); w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if w := serve(h, req("203.0.113.1")); w.Code != http.StatusTooManyRequests {
+		t.Errorf("expected 429, got %d", w.Code)
+	}
+	if w := serve(h, req("203.0.113.2")); w.Code != http.StatusOK {
+		t.Errorf("other client: expected 200, got %d", w.Code)
+	}
+}
@@ -0,0 +1,250 @@
+// Package rawoffload Run 事件原始输出转存到对象存储
+//
+// 输出频繁的 Run 中原始输出行（events.raw）占事件表的大部分空间，而原始输出只在调试与导出回放输入时读取。
+// WrapStore 包装存储层：写入事件时把超过阈值的原始输出按批次合并为一个 zstd 压缩对象上传到 MinIO，
+// 数据库中只保留指针（Event.RawRef，格式为 对象键#seq）；读取事件时按对象批量下载并还原 Raw，调用方无感知。
+//
+//   - 上传失败时原始输出照常写入数据库，不影响事件上报
+//   - 下载失败时事件的 Raw 为空（保留 RawRef），不影响事件读取
+//   - 删除 Run 的事件（生命周期归档）时同时删除对应的对象
+package rawoffload
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+const (
+	// DefaultMinBytes 默认转存阈值：原始输出不小于该字节数时转存
+	DefaultMinBytes = 1024
+
+	// objectPrefix 转存对象的键前缀
+	objectPrefix = "events/raw/"
+	// contentType 转存对象的 Content-Type（zstd 压缩的 JSON：seq → 原始输出）
+	contentType = "application/zstd"
+	// pageSize 删除事件前分页读取对象引用的批大小
+	pageSize = 1000
+)
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// ObjectStore 转存对象存储（由 MinIO 客户端实现）
+type ObjectStore interface {
+	Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Config 转存配置
+type Config struct {
+	MinBytes int // 原始输出不小于该字节数时转存，<= 0 时使用 DefaultMinBytes
+}
+
+// offloadingStore 写入时转存原始输出、读取时还原的存储包装
+type offloadingStore struct {
+	storage.PersistentStore
+	objects  ObjectStore
+	minBytes int
+}
+
+// WrapStore 包装存储层，使超过阈值的 Run 事件原始输出转存到对象存储
+func WrapStore(store storage.PersistentStore, objects ObjectStore, cfg Config) storage.PersistentStore {
+	if cfg.MinBytes <= 0 {
+		cfg.MinBytes = DefaultMinBytes
+	}
+	return &offloadingStore{PersistentStore: store, objects: objects, minBytes: cfg.MinBytes}
+}
+
+// CreateEvents 转存超过阈值的原始输出后写入事件
+//
+// 同一批次同一个 Run 的原始输出合并为一个对象。写入数据库的是事件副本，调用方持有的事件不变
+// （外层包装仍可把完整事件镜像到 Kafka 或推送 Webhook）。
+func (s *offloadingStore) CreateEvents(ctx context.Context, events []*model.Event) error {
+	out := make([]*model.Event, len(events))
+	copy(out, events)
+
+	byRun := map[string][]int{}
+	var runs []string
+	for i, e := range events {
+		if e.Raw == nil || len(*e.Raw) < s.minBytes {
+			continue
+		}
+		if _, ok := byRun[e.RunID]; !ok {
+			runs = append(runs, e.RunID)
+		}
+		byRun[e.RunID] = append(byRun[e.RunID], i)
+	}
+	for _, runID := range runs {
+		idx := byRun[runID]
+		key, err := s.upload(ctx, runID, events, idx)
+		if err != nil {
+			slog.ErrorContext(ctx, "rawoffload.upload.failed", "run_id", runID, "events", len(idx), "error", err)
+			continue
+		}
+		for _, i := range idx {
+			e := *events[i]
+			e.Raw = nil
+			e.RawRef = key + "#" + strconv.Itoa(e.Seq)
+			out[i] = &e
+		}
+	}
+	return s.PersistentStore.CreateEvents(ctx, out)
+}
+
+// upload 上传一批原始输出，返回对象键
+func (s *offloadingStore) upload(ctx context.Context, runID string, events []*model.Event, idx []int) (string, error) {
+	lines := make(map[string]string, len(idx))
+	first, last := events[idx[0]].Seq, events[idx[0]].Seq
+	for _, i := range idx {
+		e := events[i]
+		lines[strconv.Itoa(e.Seq)] = *e.Raw
+		first, last = min(first, e.Seq), max(last, e.Seq)
+	}
+	data, err := json.Marshal(lines)
+	if err != nil {
+		return "", err
+	}
+	data = zstdEncoder.EncodeAll(data, nil)
+	key := fmt.Sprintf("%s%s/%d-%d.json.zst", objectPrefix, runID, first, last)
+	if err := s.objects.Upload(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// GetEventsByRun 读取事件并还原转存的原始输出（每个对象只下载一次）
+func (s *offloadingStore) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	events, err := s.PersistentStore.GetEventsByRun(ctx, runID, fromSeq, limit)
+	if err != nil {
+		return nil, err
+	}
+	objects := map[string]map[string]string{}
+	for _, e := range events {
+		if e.RawRef == "" {
+			continue
+		}
+		key, seq, ok := strings.Cut(e.RawRef, "#")
+		if !ok {
+			continue
+		}
+		lines, fetched := objects[key]
+		if !fetched {
+			if lines, err = s.download(ctx, key); err != nil {
+				slog.ErrorContext(ctx, "rawoffload.download.failed", "run_id", runID, "key", key, "error", err)
+			}
+			objects[key] = lines
+		}
+		if raw, ok := lines[seq]; ok {
+			e.Raw = &raw
+			e.RawRef = ""
+		}
+	}
+	return events, nil
+}
+
+// download 下载并解码一个转存对象
+func (s *offloadingStore) download(ctx context.Context, key string) (map[string]string, error) {
+	rc, err := s.objects.Download(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = zstdDecoder.DecodeAll(data, nil); err != nil {
+		return nil, err
+	}
+	var lines map[string]string
+	if err := json.Unmarshal(data, &lines); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// DeleteEventsByRun 删除 Run 的事件及其转存对象
+func (s *offloadingStore) DeleteEventsByRun(ctx context.Context, runID string) (int64, error) {
+	keys, err := s.objectKeys(ctx, runID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := s.PersistentStore.DeleteEventsByRun(ctx, runID)
+	if err != nil {
+		return n, err
+	}
+	for _, key := range keys {
+		if err := s.objects.Delete(ctx, key); err != nil {
+			slog.ErrorContext(ctx, "rawoffload.delete.failed", "run_id", runID, "key", key, "error", err)
+		}
+	}
+	return n, nil
+}
+
+// objectKeys 收集 Run 的事件引用的转存对象
+func (s *offloadingStore) objectKeys(ctx context.Context, runID string) ([]string, error) {
+	seen := map[string]bool{}
+	var keys []string
+	for fromSeq := 0; ; {
+		events, err := s.PersistentStore.GetEventsByRun(ctx, runID, fromSeq, pageSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			if key, _, ok := strings.Cut(e.RawRef, "#"); ok && !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+		if len(events) < pageSize {
+			return keys, nil
+		}
+		fromSeq = events[len(events)-1].Seq
+	}
+}
+
+// DatabaseSize 透传底层存储的空间统计（管理后台总览通过类型断言调用）
+func (s *offloadingStore) DatabaseSize(ctx context.Context) (int64, error) {
+	if sizer, ok := s.PersistentStore.(interface {
+		DatabaseSize(ctx context.Context) (int64, error)
+	}); ok {
+		return sizer.DatabaseSize(ctx)
+	}
+	return 0, errors.ErrUnsupported
+}
+
+// ReplicaStatus 透传底层存储的只读副本状态（管理后台总览通过类型断言调用）
+func (s *offloadingStore) ReplicaStatus() *storage.ReplicaStatus {
+	if r, ok := s.PersistentStore.(interface {
+		ReplicaStatus() *storage.ReplicaStatus
+	}); ok {
+		return r.ReplicaStatus()
+	}
+	return nil
+}
+
+// PoolStats 透传底层存储的连接池统计（指标导出通过类型断言调用）
+func (s *offloadingStore) PoolStats() map[string]sql.DBStats {
+	if p, ok := s.PersistentStore.(interface {
+		PoolStats() map[string]sql.DBStats
+	}); ok {
+		return p.PoolStats()
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package rawoffload
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// eventStore 内存事件存储（按写入顺序保存，忽略 fromSeq 之前的事件）
+type eventStore struct {
+	storage.PersistentStore
+	events []*model.Event
+}
+
+func (m *eventStore) CreateEvents(_ context.Context, events []*model.Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *eventStore) GetEventsByRun(_ context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	var out []*model.Event
+	for _, e := range m.events {
+		if e.RunID == runID && e.Seq > fromSeq && len(out) < limit {
+			copied := *e
+			out = append(out, &copied)
+		}
+	}
+	return out, nil
+}
+
+func (m *eventStore) DeleteEventsByRun(_ context.Context, runID string) (int64, error) {
+	var kept []*model.Event
+	for _, e := range m.events {
+		if e.RunID != runID {
+			kept = append(kept, e)
+		}
+	}
+	n := int64(len(m.events) - len(kept))
+	m.events = kept
+	return n, nil
+}
+
+// objectStore 内存对象存储，down 为 true 时上传与下载失败
+type objectStore struct {
+	mu      sync.Mutex
+	down    bool
+	objects map[string][]byte
+}
+
+func newObjectStore() *objectStore {
+	return &objectStore{objects: map[string][]byte{}}
+}
+
+func (o *objectStore) Upload(_ context.Context, key string, reader io.Reader, _ int64, _ string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.down {
+		return errors.New("minio down")
+	}
+	data, err := io.ReadAll(reader)
+	o.objects[key] = data
+	return err
+}
+
+func (o *objectStore) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	data, ok := o.objects[key]
+	if o.down || !ok {
+		return nil, errors.New("object not found")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (o *objectStore) Delete(_ context.Context, key string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.objects, key)
+	return nil
+}
+
+func rawEvent(runID string, seq int, raw string) *model.Event {
+	return &model.Event{RunID: runID, Seq: seq, Type: "command_output", Raw: &raw}
+}
+
+func TestWrapStore_OffloadsLargeRaw(t *testing.T) {
+	db, objects := &eventStore{}, newObjectStore()
+	store := WrapStore(db, objects, Config{MinBytes: 16})
+	ctx := context.Background()
+
+	large := strings.Repeat("npm WARN deprecated ", 50)
+	input := []*model.Event{rawEvent("run-1", 1, large), rawEvent("run-1", 2, "ok"), rawEvent("run-1", 3, large+"!")}
+	if err := store.CreateEvents(ctx, input); err != nil {
+		t.Fatal(err)
+	}
+
+	// 调用方的事件保持不变；数据库中大行只保留指针，同一批次合并为一个对象
+	if input[0].Raw == nil || input[0].RawRef != "" {
+		t.Errorf("调用方的事件不应被修改: %+v", input[0])
+	}
+	if len(objects.objects) != 1 {
+		t.Fatalf("objects = %d, 期望 1", len(objects.objects))
+	}
+	if db.events[0].Raw != nil || db.events[0].RawRef != "events/raw/run-1/1-3.json.zst#1" {
+		t.Errorf("stored event 1 = raw %v ref %q", db.events[0].Raw, db.events[0].RawRef)
+	}
+	if db.events[1].Raw == nil || db.events[1].RawRef != "" {
+		t.Errorf("未超过阈值的原始输出应写入数据库: %+v", db.events[1])
+	}
+
+	events, err := store.GetEventsByRun(ctx, "run-1", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{large, "ok", large + "!"} {
+		if events[i].Raw == nil || *events[i].Raw != want || events[i].RawRef != "" {
+			t.Errorf("event %d 未还原原始输出: %+v", i+1, events[i])
+		}
+	}
+
+	if n, err := store.DeleteEventsByRun(ctx, "run-1"); err != nil || n != 3 {
+		t.Fatalf("DeleteEventsByRun = %d, %v", n, err)
+	}
+	if len(objects.objects) != 0 {
+		t.Errorf("删除事件后应删除转存对象, objects = %d", len(objects.objects))
+	}
+}
+
+func TestWrapStore_ObjectStoreUnavailable(t *testing.T) {
+	db, objects := &eventStore{}, newObjectStore()
+	store := WrapStore(db, objects, Config{MinBytes: 4})
+	ctx := context.Background()
+
+	// 上传失败时原始输出写入数据库
+	objects.down = true
+	if err := store.CreateEvents(ctx, []*model.Event{rawEvent("run-1", 1, "inline output")}); err != nil {
+		t.Fatal(err)
+	}
+	if db.events[0].Raw == nil || db.events[0].RawRef != "" {
+		t.Errorf("上传失败时应保留原始输出: %+v", db.events[0])
+	}
+
+	// 下载失败时事件照常返回，保留指针
+	objects.down = false
+	if err := store.CreateEvents(ctx, []*model.Event{rawEvent("run-1", 2, "offloaded output")}); err != nil {
+		t.Fatal(err)
+	}
+	objects.down = true
+	events, err := store.GetEventsByRun(ctx, "run-1", 0, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 2 || events[1].Raw != nil || events[1].RawRef == "" {
+		t.Errorf("events = %+v", events)
+	}
+}
@@ -0,0 +1,261 @@
+// Package region 区域领域 - HTTP 处理
+//
+// 区域是按数据中心划分的节点组：节点以 region 标签归属区域，任务以 region 字段声明希望执行的区域，
+// 调度器的 region 策略优先在本区域分派，本区域不可用时按区域的回退策略处理。
+// 区域的创建、修改、删除仅限管理员；无认证模式下（无用户会话）不做角色限制。
+package region
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+)
+
+// Store 定义区域 handler 需要的存储接口（用于测试 mock）
+type Store interface {
+	CreateRegion(ctx context.Context, region *model.Region) error
+	GetRegion(ctx context.Context, id string) (*model.Region, error)
+	ListRegions(ctx context.Context) ([]*model.Region, error)
+	UpdateRegion(ctx context.Context, region *model.Region) error
+	DeleteRegion(ctx context.Context, id string) error
+	CountQueuedRunsByRegion(ctx context.Context) ([]model.RegionQueueDepth, error)
+	ListAllNodes(ctx context.Context) ([]*model.Node, error)
+	ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error)
+}
+
+// Handler 区域领域 HTTP 处理器
+type Handler struct {
+	store Store
+}
+
+// NewHandler 创建区域处理器
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes 注册区域相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/regions", h.List)
+	mux.HandleFunc("POST /api/v1/regions", auth.AdminOnly(h.Create))
+	mux.HandleFunc("GET /api/v1/regions/stats", h.Stats)
+	mux.HandleFunc("GET /api/v1/regions/{id}", h.Get)
+	mux.HandleFunc("PUT /api/v1/regions/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/regions/{id}", auth.AdminOnly(h.Delete))
+}
+
+// List 获取区域列表
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	regions, err := h.store.ListRegions(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "region.list.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list regions")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"regions": regions})
+}
+
+// Create 创建区域
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var region model.Region
+	if err := json.NewDecoder(r.Body).Decode(&region); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := region.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	existing, err := h.store.GetRegion(r.Context(), region.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "region.create.failed", "region", region.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create region")
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "region already exists")
+		return
+	}
+
+	now := time.Now()
+	region.CreatedAt = now
+	region.UpdatedAt = now
+	if err := h.store.CreateRegion(r.Context(), &region); err != nil {
+		slog.ErrorContext(r.Context(), "region.create.failed", "region", region.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create region")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "region.create.ok", "region", region.ID, "fallback", region.FallbackPolicy())
+	writeJSON(w, http.StatusCreated, region)
+}
+
+// Get 获取区域详情
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	region, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, region)
+}
+
+// Update 更新区域（整体替换可配置字段，ID 不可修改）
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	existing, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	var region model.Region
+	if err := json.NewDecoder(r.Body).Decode(&region); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	region.ID = existing.ID
+	if err := region.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	region.CreatedAt = existing.CreatedAt
+	region.UpdatedAt = time.Now()
+	if err := h.store.UpdateRegion(r.Context(), &region); err != nil {
+		slog.ErrorContext(r.Context(), "region.update.failed", "region", region.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update region")
+		return
+	}
+	writeJSON(w, http.StatusOK, region)
+}
+
+// Delete 删除区域
+//
+// 声明该区域的任务与带该区域标签的节点保留，调度时按默认回退策略（any）处理。
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	if err := h.store.DeleteRegion(r.Context(), id); err != nil {
+		slog.ErrorContext(r.Context(), "region.delete.failed", "region", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete region")
+		return
+	}
+	slog.InfoContext(r.Context(), "region.delete.ok", "region", id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Stats 获取各区域的排队深度与在线节点容量
+func (h *Handler) Stats(w http.ResponseWriter, r *http.Request) {
+	stats, err := CollectStats(r.Context(), h.store)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "region.stats.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to collect region stats")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"regions": stats})
+}
+
+// CollectStats 统计各区域的排队 Run 与在线节点
+//
+// 统计范围为已定义的区域、在线节点 region 标签中出现的区域以及任务声明的区域，
+// 未声明区域的任务与未设置 region 标签的节点不计入。按区域 ID 排序。
+func CollectStats(ctx context.Context, store Store) ([]model.RegionQueueStats, error) {
+	regions, err := store.ListRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	depths, err := store.CountQueuedRunsByRegion(ctx)
+	if err != nil {
+		return nil, err
+	}
+	nodes, err := store.ListAllNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byRegion := make(map[string]*model.RegionQueueStats)
+	get := func(id string) *model.RegionQueueStats {
+		s, ok := byRegion[id]
+		if !ok {
+			s = &model.RegionQueueStats{Region: id}
+			byRegion[id] = s
+		}
+		return s
+	}
+	for _, r := range regions {
+		get(r.ID)
+	}
+
+	now := time.Now()
+	for _, d := range depths {
+		if d.Region == "" {
+			continue
+		}
+		s := get(d.Region)
+		s.QueuedRuns = d.QueuedRuns
+		if d.OldestQueuedAt != nil {
+			s.OldestQueuedSeconds = now.Sub(*d.OldestQueuedAt).Seconds()
+		}
+	}
+
+	for _, n := range nodemgr.FilterNodesByFreshHeartbeat(nodes, nodemgr.HeartbeatFreshWindow) {
+		id := model.NodeRegion(n)
+		if id == "" || n.IsAdminStatus() {
+			continue
+		}
+		s := get(id)
+		s.OnlineNodes++
+		s.Capacity += nodemgr.GetNodeMaxConcurrent(n)
+		runs, err := store.ListRunsByNode(ctx, n.ID)
+		if err != nil {
+			slog.ErrorContext(ctx, "region.stats.runs_failed", "node_id", n.ID, "error", err)
+			continue
+		}
+		// 暂停中的 Run 不占用执行槽位（与调度器的计数一致）
+		for _, r := range runs {
+			if r.Status != model.RunStatusPaused {
+				s.Running++
+			}
+		}
+	}
+
+	stats := make([]model.RegionQueueStats, 0, len(byRegion))
+	for _, s := range byRegion {
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Region < stats[j].Region })
+	return stats, nil
+}
+
+// load 按路径参数加载区域，不存在时写入 404
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) (*model.Region, bool) {
+	id := r.PathValue("id")
+	region, err := h.store.GetRegion(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "region.get.failed", "region", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get region")
+		return nil, false
+	}
+	if region == nil {
+		writeError(w, http.StatusNotFound, "region not found")
+		return nil, false
+	}
+	return region, true
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
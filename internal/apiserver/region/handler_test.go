@@ -0,0 +1,202 @@
+package region
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存区域存储
+type mockStore struct {
+	regions map[string]*model.Region
+	depths  []model.RegionQueueDepth
+	nodes   []*model.Node
+	runs    map[string][]*model.Run
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{regions: make(map[string]*model.Region), runs: make(map[string][]*model.Run)}
+}
+
+func (m *mockStore) CreateRegion(_ context.Context, r *model.Region) error {
+	m.regions[r.ID] = r
+	return nil
+}
+func (m *mockStore) GetRegion(_ context.Context, id string) (*model.Region, error) {
+	return m.regions[id], nil
+}
+func (m *mockStore) ListRegions(_ context.Context) ([]*model.Region, error) {
+	var list []*model.Region
+	for _, r := range m.regions {
+		list = append(list, r)
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateRegion(_ context.Context, r *model.Region) error {
+	m.regions[r.ID] = r
+	return nil
+}
+func (m *mockStore) DeleteRegion(_ context.Context, id string) error {
+	delete(m.regions, id)
+	return nil
+}
+func (m *mockStore) CountQueuedRunsByRegion(_ context.Context) ([]model.RegionQueueDepth, error) {
+	return m.depths, nil
+}
+func (m *mockStore) ListAllNodes(_ context.Context) ([]*model.Node, error) { return m.nodes, nil }
+func (m *mockStore) ListRunsByNode(_ context.Context, nodeID string) ([]*model.Run, error) {
+	return m.runs[nodeID], nil
+}
+
+// newTestMux 注册路由，以无认证模式（未配置 JWT 密钥）处理请求
+func newTestMux(store Store) http.Handler {
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+	return auth.Middleware(auth.Config{})(mux)
+}
+
+func withUser(req *http.Request, role string) *http.Request {
+	return req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: role}))
+}
+
+func TestCreateRegion(t *testing.T) {
+	store := newMockStore()
+	mux := newTestMux(store)
+
+	body := `{"id":"cn-east","display_name":"华东","fallback":"ordered","fallback_regions":["cn-north"]}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, withUser(httptest.NewRequest("POST", "/api/v1/regions", strings.NewReader(body)), "admin"))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if r := store.regions["cn-east"]; r == nil || r.Fallback != model.RegionFallbackOrdered || r.CreatedAt.IsZero() {
+		t.Errorf("stored = %+v", r)
+	}
+
+	// 重复创建
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/regions", strings.NewReader(body)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("重复创建 status = %d, 期望 409", w.Code)
+	}
+}
+
+func TestCreateRegionValidation(t *testing.T) {
+	mux := newTestMux(newMockStore())
+	cases := map[string]string{
+		"非法 ID":  `{"id":"CN East"}`,
+		"非法回退策略": `{"id":"cn-east","fallback":"nearest"}`,
+		"回退到自身":  `{"id":"cn-east","fallback_regions":["cn-east"]}`,
+	}
+	for name, body := range cases {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/regions", strings.NewReader(body)))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, 期望 400", name, w.Code)
+		}
+	}
+}
+
+func TestRegionWritesRequireAdmin(t *testing.T) {
+	store := newMockStore()
+	store.regions["cn-east"] = &model.Region{ID: "cn-east"}
+	mux := newTestMux(store)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, withUser(httptest.NewRequest("DELETE", "/api/v1/regions/cn-east", nil), "user"))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("普通用户删除 status = %d, 期望 403", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	req := httptest.NewRequest("DELETE", "/api/v1/regions/cn-east", nil)
+	mux.ServeHTTP(w, req.WithContext(auth.WithNodeIdentity(req.Context(), "node-1")))
+	if w.Code != http.StatusForbidden || store.regions["cn-east"] == nil {
+		t.Errorf("节点凭证删除 status = %d, 期望 403", w.Code)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, withUser(httptest.NewRequest("GET", "/api/v1/regions/cn-east", nil), "user"))
+	if w.Code != http.StatusOK {
+		t.Errorf("普通用户读取 status = %d, 期望 200", w.Code)
+	}
+}
+
+func TestUpdateRegion(t *testing.T) {
+	store := newMockStore()
+	created := time.Now().Add(-time.Hour)
+	store.regions["cn-east"] = &model.Region{ID: "cn-east", CreatedAt: created}
+	mux := newTestMux(store)
+
+	body := `{"id":"ignored","fallback":"none"}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/regions/cn-east", strings.NewReader(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	r := store.regions["cn-east"]
+	if r.Fallback != model.RegionFallbackNone || !r.CreatedAt.Equal(created) {
+		t.Errorf("updated = %+v", r)
+	}
+	if _, ok := store.regions["ignored"]; ok {
+		t.Error("请求体中的 ID 不应生效")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/regions/missing", strings.NewReader(body)))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("不存在的区域 status = %d, 期望 404", w.Code)
+	}
+}
+
+func TestRegionStats(t *testing.T) {
+	store := newMockStore()
+	store.regions["cn-east"] = &model.Region{ID: "cn-east"}
+	store.regions["cn-north"] = &model.Region{ID: "cn-north"}
+	oldest := time.Now().Add(-2 * time.Minute)
+	store.depths = []model.RegionQueueDepth{
+		{Region: "", QueuedRuns: 5},
+		{Region: "cn-east", QueuedRuns: 3, OldestQueuedAt: &oldest},
+		{Region: "us-west", QueuedRuns: 1},
+	}
+	fresh := time.Now()
+	store.nodes = []*model.Node{
+		{ID: "n1", Status: model.NodeStatusOnline, Labels: json.RawMessage(`{"region":"cn-east"}`), Capacity: json.RawMessage(`{"max_concurrent":2}`), LastHeartbeat: &fresh},
+		{ID: "n2", Status: model.NodeStatusOnline, Labels: json.RawMessage(`{"region":"cn-east"}`), LastHeartbeat: &fresh},
+		{ID: "n3", Status: model.NodeStatusOnline, Labels: json.RawMessage(`{}`), LastHeartbeat: &fresh},
+	}
+	store.runs["n1"] = []*model.Run{{ID: "r1", Status: model.RunStatusRunning}, {ID: "r2", Status: model.RunStatusPaused}}
+
+	w := httptest.NewRecorder()
+	newTestMux(store).ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/regions/stats", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Regions []model.RegionQueueStats `json:"regions"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if len(resp.Regions) != 3 {
+		t.Fatalf("regions = %+v, 期望 cn-east、cn-north、us-west", resp.Regions)
+	}
+	east := resp.Regions[0]
+	if east.Region != "cn-east" || east.QueuedRuns != 3 || east.OnlineNodes != 2 || east.Capacity != 3 || east.Running != 1 {
+		t.Errorf("cn-east = %+v", east)
+	}
+	if east.OldestQueuedSeconds < 119 {
+		t.Errorf("oldest_queued_seconds = %v, 期望约 120", east.OldestQueuedSeconds)
+	}
+	if north := resp.Regions[1]; north.Region != "cn-north" || north.QueuedRuns != 0 || north.OnlineNodes != 0 {
+		t.Errorf("cn-north = %+v", north)
+	}
+	if west := resp.Regions[2]; west.Region != "us-west" || west.QueuedRuns != 1 {
+		t.Errorf("us-west = %+v", west)
+	}
+}
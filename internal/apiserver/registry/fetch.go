@@ -0,0 +1,250 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"agents-admin/internal/shared/model"
+)
+
+// maxDocumentSize 索引与条目文件的大小上限
+const maxDocumentSize = 4 << 20
+
+// Index 注册表索引文档（YAML 或 JSON）
+//
+//	items:
+//	  - kind: task_template          # task_template / agent_template / skill
+//	    id: tpl-code-review
+//	    version: "1.2.0"
+//	    file: task-templates/code-review.yaml   # 相对于索引的条目文件
+//	  - kind: skill
+//	    id: builtin-code-review
+//	    version: "1.0.1"
+//	    spec: {name: 代码审查, category: coding, instructions: "..."}  # 或内嵌内容
+//
+// 条目内容的字段名与对应 API 的 JSON 字段一致。
+type Index struct {
+	Items []IndexEntry `yaml:"items"`
+}
+
+// IndexEntry 索引中的条目
+type IndexEntry struct {
+	Kind    model.RegistryItemKind `yaml:"kind"`
+	ID      string                 `yaml:"id"`
+	Version string                 `yaml:"version"`
+	File    string                 `yaml:"file,omitempty"`
+	Spec    map[string]interface{} `yaml:"spec,omitempty"`
+}
+
+// resolvedEntry 读取了内容的条目（Spec 为规范化的 JSON）
+type resolvedEntry struct {
+	Kind    model.RegistryItemKind
+	ID      string
+	Version string
+	Spec    json.RawMessage
+}
+
+// fetcher 从注册表读取索引与条目文件
+type fetcher interface {
+	source() Source
+	// prepare 在读取索引前调用（git 注册表在此拉取仓库）
+	prepare(ctx context.Context) error
+	// read 读取索引（name 为空）或相对于索引的文件
+	read(ctx context.Context, name string) ([]byte, error)
+}
+
+func newFetcher(src Source, cacheDir string) (fetcher, error) {
+	switch src.Type {
+	case "", SourceTypeHTTP:
+		if _, err := url.Parse(src.URL); err != nil {
+			return nil, fmt.Errorf("registry %s: invalid url: %w", src.Name, err)
+		}
+		return &httpFetcher{src: src, client: http.DefaultClient}, nil
+	case SourceTypeGit:
+		if src.Index == "" {
+			src.Index = "index.yaml"
+		}
+		if cacheDir == "" {
+			cacheDir = filepath.Join(os.TempDir(), "agents-admin-registry")
+		}
+		return &gitFetcher{src: src, dir: filepath.Join(cacheDir, src.Name)}, nil
+	default:
+		return nil, fmt.Errorf("registry %s: unsupported type %q (expected http or git)", src.Name, src.Type)
+	}
+}
+
+// loadIndex 读取并解析索引，加载每个条目的内容
+func loadIndex(ctx context.Context, f fetcher) ([]resolvedEntry, error) {
+	if err := f.prepare(ctx); err != nil {
+		return nil, err
+	}
+	data, err := f.read(ctx, "")
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	var idx Index
+	if err := yaml.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse index: %w", err)
+	}
+
+	entries := make([]resolvedEntry, 0, len(idx.Items))
+	seen := make(map[string]bool)
+	for i, e := range idx.Items {
+		if !e.Kind.IsValid() || e.ID == "" || e.Version == "" {
+			return nil, fmt.Errorf("index item %d: kind, id and version are required", i)
+		}
+		id := model.RegistryItemID(e.Kind, e.ID)
+		if seen[id] {
+			return nil, fmt.Errorf("index item %d: duplicate %s", i, id)
+		}
+		seen[id] = true
+
+		spec := e.Spec
+		if e.File != "" {
+			spec = nil
+			data, err := f.read(ctx, e.File)
+			if err != nil {
+				return nil, fmt.Errorf("index item %d: read %s: %w", i, e.File, err)
+			}
+			if err := yaml.Unmarshal(data, &spec); err != nil {
+				return nil, fmt.Errorf("index item %d: parse %s: %w", i, e.File, err)
+			}
+		}
+		if len(spec) == 0 {
+			return nil, fmt.Errorf("index item %d: file or spec is required", i)
+		}
+		// json.Marshal 对 map 键排序，内容相同时摘要稳定
+		raw, err := json.Marshal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("index item %d: %w", i, err)
+		}
+		entries = append(entries, resolvedEntry{Kind: e.Kind, ID: e.ID, Version: e.Version, Spec: raw})
+	}
+	return entries, nil
+}
+
+// ============================================================================
+// HTTP 注册表
+// ============================================================================
+
+// httpFetcher 从 HTTP 索引读取，条目文件相对于索引 URL 解析
+type httpFetcher struct {
+	src    Source
+	client *http.Client
+}
+
+func (f *httpFetcher) source() Source                  { return f.src }
+func (f *httpFetcher) prepare(_ context.Context) error { return nil }
+
+func (f *httpFetcher) read(ctx context.Context, name string) ([]byte, error) {
+	u, err := url.Parse(f.src.URL)
+	if err != nil {
+		return nil, err
+	}
+	if name != "" {
+		ref, err := url.Parse(name)
+		if err != nil {
+			return nil, err
+		}
+		u = u.ResolveReference(ref)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: status %d", u, resp.StatusCode)
+	}
+	return readLimited(resp.Body)
+}
+
+// ============================================================================
+// git 注册表
+// ============================================================================
+
+// gitFetcher 将仓库浅克隆到本地缓存目录，索引与条目文件从工作区读取
+type gitFetcher struct {
+	src Source
+	dir string
+}
+
+func (f *gitFetcher) source() Source { return f.src }
+
+// prepare 首次克隆仓库，之后拉取并重置到远端最新提交
+func (f *gitFetcher) prepare(ctx context.Context) error {
+	if _, err := os.Stat(filepath.Join(f.dir, ".git")); err != nil {
+		if err := os.MkdirAll(filepath.Dir(f.dir), 0o755); err != nil {
+			return err
+		}
+		os.RemoveAll(f.dir)
+		args := []string{"clone", "--depth", "1"}
+		if f.src.Ref != "" {
+			args = append(args, "--branch", f.src.Ref)
+		}
+		return runGit(ctx, "", append(args, f.src.URL, f.dir)...)
+	}
+	ref := f.src.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	if err := runGit(ctx, f.dir, "fetch", "--depth", "1", "origin", ref); err != nil {
+		return err
+	}
+	return runGit(ctx, f.dir, "reset", "--hard", "FETCH_HEAD")
+}
+
+func (f *gitFetcher) read(_ context.Context, name string) ([]byte, error) {
+	rel := f.src.Index
+	if name != "" {
+		rel = path.Join(path.Dir(f.src.Index), name)
+	}
+	// 条目文件必须位于仓库内
+	rel = path.Clean("/" + rel)[1:]
+	file, err := os.Open(filepath.Join(f.dir, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return readLimited(file)
+}
+
+// runGit 执行 git 命令，失败时附带输出
+func runGit(ctx context.Context, dir string, args ...string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var out bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &out, &out
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s: %v: %s", args[0], err, strings.TrimSpace(out.String()))
+	}
+	return nil
+}
+
+func readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, fmt.Errorf("document exceeds %d bytes", maxDocumentSize)
+	}
+	return data, nil
+}
@@ -0,0 +1,146 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// Handler 模板注册表 HTTP 处理器
+type Handler struct {
+	syncer *Syncer
+}
+
+// NewHandler 创建模板注册表处理器
+func NewHandler(syncer *Syncer) *Handler {
+	return &Handler{syncer: syncer}
+}
+
+// RegisterRoutes 注册模板注册表相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/registry", h.GetStatus)
+	mux.HandleFunc("POST /api/v1/registry/sync", auth.AdminOnly(h.Sync))
+	mux.HandleFunc("GET /api/v1/registry/items", h.ListItems)
+	mux.HandleFunc("POST /api/v1/registry/items/{id}/update", auth.AdminOnly(h.UpdateItem))
+	mux.HandleFunc("POST /api/v1/registry/items/{id}/pin", auth.AdminOnly(h.PinItem))
+	mux.HandleFunc("DELETE /api/v1/registry/items/{id}/pin", auth.AdminOnly(h.UnpinItem))
+}
+
+// itemView 同步记录及是否有可用更新
+type itemView struct {
+	*model.RegistryItem
+	UpdateAvailable bool `json:"update_available"`
+}
+
+func newItemView(item *model.RegistryItem) itemView {
+	return itemView{RegistryItem: item, UpdateAvailable: item.UpdateAvailable()}
+}
+
+// GetStatus 注册表配置与最近一轮同步结果
+// GET /api/v1/registry
+//
+// 响应: {"enabled": true, "interval": "1h0m0s", "sources": [{"name": "official", "type": "git", "url": "..."}], "last_results": [...]}
+func (h *Handler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	cfg := h.syncer.Config()
+	sources := make([]map[string]string, 0, len(cfg.Sources))
+	for _, src := range cfg.Sources {
+		typ := src.Type
+		if typ == "" {
+			typ = SourceTypeHTTP
+		}
+		sources = append(sources, map[string]string{"name": src.Name, "type": typ, "url": src.URL, "ref": src.Ref})
+	}
+	results := h.syncer.LastResults()
+	if results == nil {
+		results = []SourceResult{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"enabled":      cfg.Enabled,
+		"interval":     cfg.Interval.String(),
+		"sources":      sources,
+		"last_results": results,
+	})
+}
+
+// Sync 立即同步所有注册表（仅限管理员）
+// POST /api/v1/registry/sync
+func (h *Handler) Sync(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Minute)
+	defer cancel()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"results": h.syncer.SyncAll(ctx)})
+}
+
+// ListItems 列出同步条目（含上游版本、已安装版本、固定状态与是否有可用更新）
+// GET /api/v1/registry/items?update_available=true
+func (h *Handler) ListItems(w http.ResponseWriter, r *http.Request) {
+	items, err := h.syncer.Items(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "registry", "op", "ListRegistryItems", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list registry items")
+		return
+	}
+	onlyUpdates := r.URL.Query().Get("update_available") == "true"
+	views := make([]itemView, 0, len(items))
+	for _, item := range items {
+		if onlyUpdates && !item.UpdateAvailable() {
+			continue
+		}
+		views = append(views, newItemView(item))
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"items": views})
+}
+
+// UpdateItem 安装条目的上游版本（仅限管理员，固定的条目更新后仍保持固定）
+// POST /api/v1/registry/items/{id}/update
+func (h *Handler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	item, err := h.syncer.Update(r.Context(), r.PathValue("id"))
+	h.writeItem(w, r, item, err)
+}
+
+// PinItem 固定条目在已安装版本（仅限管理员）
+// POST /api/v1/registry/items/{id}/pin
+func (h *Handler) PinItem(w http.ResponseWriter, r *http.Request) {
+	item, err := h.syncer.SetPinned(r.Context(), r.PathValue("id"), true)
+	h.writeItem(w, r, item, err)
+}
+
+// UnpinItem 取消固定（仅限管理员，下一次同步时更新到上游版本）
+// DELETE /api/v1/registry/items/{id}/pin
+func (h *Handler) UnpinItem(w http.ResponseWriter, r *http.Request) {
+	item, err := h.syncer.SetPinned(r.Context(), r.PathValue("id"), false)
+	h.writeItem(w, r, item, err)
+}
+
+func (h *Handler) writeItem(w http.ResponseWriter, r *http.Request, item *model.RegistryItem, err error) {
+	switch {
+	case errors.Is(err, ErrItemNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, ErrNotInstalled):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		slog.ErrorContext(r.Context(), "registry.item.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, err.Error())
+	default:
+		writeJSON(w, http.StatusOK, newItemView(item))
+	}
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
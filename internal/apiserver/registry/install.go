@@ -0,0 +1,137 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// localState 本地同 ID 模板/技能的状态
+type localState int
+
+const (
+	localAbsent  localState = iota // 不存在
+	localBuiltin                   // 内置条目（可由注册表接管）
+	localCustom                    // 用户自定义条目（不覆盖）
+)
+
+// localItem 查询本地同 ID 的模板或技能
+func (s *Syncer) localItem(ctx context.Context, kind model.RegistryItemKind, id string) (localState, error) {
+	var found, builtin bool
+	switch kind {
+	case model.RegistryKindTaskTemplate:
+		tmpl, err := s.store.GetTaskTemplate(ctx, id)
+		if err != nil {
+			return localAbsent, err
+		}
+		found, builtin = tmpl != nil, tmpl != nil && tmpl.IsBuiltin
+	case model.RegistryKindAgentTemplate:
+		tmpl, err := s.store.GetAgentTemplate(ctx, id)
+		if err != nil {
+			return localAbsent, err
+		}
+		found, builtin = tmpl != nil, tmpl != nil && tmpl.IsBuiltin
+	case model.RegistryKindSkill:
+		skill, err := s.store.GetSkill(ctx, id)
+		if err != nil {
+			return localAbsent, err
+		}
+		found, builtin = skill != nil, skill != nil && skill.IsBuiltinSkill()
+	}
+	switch {
+	case !found:
+		return localAbsent, nil
+	case builtin:
+		return localBuiltin, nil
+	default:
+		return localCustom, nil
+	}
+}
+
+// install 将条目的上游内容写入模板/技能存储，并记录已安装版本
+//
+// 注册表安装的条目标记为内置。任务模板与技能的存储没有更新接口，已存在时按 ID 删除后重建（保留创建时间）。
+func (s *Syncer) install(ctx context.Context, item *model.RegistryItem, now time.Time) error {
+	switch item.Kind {
+	case model.RegistryKindTaskTemplate:
+		var tmpl model.TaskTemplate
+		if err := json.Unmarshal(item.UpstreamSpec, &tmpl); err != nil {
+			return fmt.Errorf("invalid task template: %w", err)
+		}
+		if tmpl.Name == "" {
+			return fmt.Errorf("task template name is required")
+		}
+		tmpl.ID, tmpl.IsBuiltin, tmpl.CreatedAt, tmpl.UpdatedAt = item.ItemID, true, now, now
+		existing, err := s.store.GetTaskTemplate(ctx, item.ItemID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			tmpl.CreatedAt = existing.CreatedAt
+			if err := s.store.DeleteTaskTemplate(ctx, item.ItemID); err != nil {
+				return err
+			}
+		}
+		if err := s.store.CreateTaskTemplate(ctx, &tmpl); err != nil {
+			return err
+		}
+
+	case model.RegistryKindAgentTemplate:
+		var tmpl model.AgentTemplate
+		if err := json.Unmarshal(item.UpstreamSpec, &tmpl); err != nil {
+			return fmt.Errorf("invalid agent template: %w", err)
+		}
+		if tmpl.Name == "" {
+			return fmt.Errorf("agent template name is required")
+		}
+		tmpl.ID, tmpl.IsBuiltin, tmpl.CreatedAt, tmpl.UpdatedAt = item.ItemID, true, now, now
+		existing, err := s.store.GetAgentTemplate(ctx, item.ItemID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			tmpl.CreatedAt = existing.CreatedAt
+			err = s.store.UpdateAgentTemplate(ctx, &tmpl)
+		} else {
+			err = s.store.CreateAgentTemplate(ctx, &tmpl)
+		}
+		if err != nil {
+			return err
+		}
+
+	case model.RegistryKindSkill:
+		var skill model.Skill
+		if err := json.Unmarshal(item.UpstreamSpec, &skill); err != nil {
+			return fmt.Errorf("invalid skill: %w", err)
+		}
+		if skill.Name == "" {
+			return fmt.Errorf("skill name is required")
+		}
+		source := item.Source
+		skill.ID, skill.IsBuiltin, skill.CreatedAt, skill.UpdatedAt = item.ItemID, true, now, now
+		skill.Source, skill.RegistryID, skill.Version = model.SkillSourceBuiltin, &source, item.UpstreamVersion
+		existing, err := s.store.GetSkill(ctx, item.ItemID)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			skill.CreatedAt, skill.UseCount, skill.Rating = existing.CreatedAt, existing.UseCount, existing.Rating
+			if err := s.store.DeleteSkill(ctx, item.ItemID); err != nil {
+				return err
+			}
+		}
+		if err := s.store.CreateSkill(ctx, &skill); err != nil {
+			return err
+		}
+
+	default:
+		return fmt.Errorf("unsupported kind %q", item.Kind)
+	}
+
+	item.InstalledVersion = item.UpstreamVersion
+	item.InstalledAt = &now
+	return nil
+}
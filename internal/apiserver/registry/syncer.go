@@ -0,0 +1,327 @@
+// Package registry 模板注册表同步
+//
+// API Server 定时从远程注册表（HTTP 索引或 git 仓库）拉取任务模板、Agent 模板与技能，
+// 在 registry_items 中记录每个条目的上游版本与已安装版本：
+//   - 新条目直接安装
+//   - 未固定的条目在上游版本变化时自动更新
+//   - 固定（pinned）的条目保持已安装版本，上游版本不同时显示"有可用更新"，可手动更新
+//
+// 注册表只接管自己安装过的条目和内置条目；与本地自定义模板/技能 ID 冲突的条目跳过并在结果中报告。
+// 这样内置模板可以随注册表更新，无需发布新的二进制。
+package registry
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// 注册表类型
+const (
+	SourceTypeHTTP = "http"
+	SourceTypeGit  = "git"
+)
+
+// ErrItemNotFound 同步记录不存在
+var ErrItemNotFound = errors.New("registry item not found")
+
+// ErrNotInstalled 条目尚未安装（不能固定）
+var ErrNotInstalled = errors.New("registry item not installed")
+
+// Store 注册表同步需要的存储接口
+type Store interface {
+	UpsertRegistryItem(ctx context.Context, item *model.RegistryItem) error
+	GetRegistryItem(ctx context.Context, id string) (*model.RegistryItem, error)
+	ListRegistryItems(ctx context.Context) ([]*model.RegistryItem, error)
+
+	GetTaskTemplate(ctx context.Context, id string) (*model.TaskTemplate, error)
+	CreateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error
+	DeleteTaskTemplate(ctx context.Context, id string) error
+	GetAgentTemplate(ctx context.Context, id string) (*model.AgentTemplate, error)
+	CreateAgentTemplate(ctx context.Context, tmpl *model.AgentTemplate) error
+	UpdateAgentTemplate(ctx context.Context, tmpl *model.AgentTemplate) error
+	GetSkill(ctx context.Context, id string) (*model.Skill, error)
+	CreateSkill(ctx context.Context, skill *model.Skill) error
+	DeleteSkill(ctx context.Context, id string) error
+}
+
+// Source 远程注册表
+type Source struct {
+	Name  string // 名称（记录在同步条目的 source 中）
+	Type  string // http（默认）或 git
+	URL   string // http：索引文件 URL；git：仓库地址
+	Ref   string // git 分支或标签（为空时使用默认分支）
+	Index string // git 仓库内索引文件路径，默认 index.yaml
+}
+
+// Config 同步配置
+type Config struct {
+	Enabled  bool          // 是否启用定时同步（手动同步不受影响）
+	Interval time.Duration // 同步间隔
+	Timeout  time.Duration // 单个注册表的拉取超时
+	Sources  []Source      // 注册表列表（同一条目由先列出的注册表提供）
+	CacheDir string        // git 仓库的本地缓存目录
+}
+
+// SourceResult 一个注册表的同步结果
+type SourceResult struct {
+	Source          string    `json:"source"`
+	SyncedAt        time.Time `json:"synced_at"`
+	Items           int       `json:"items"`            // 索引中的条目数
+	Installed       int       `json:"installed"`        // 新安装
+	Updated         int       `json:"updated"`          // 自动更新
+	UpdateAvailable int       `json:"update_available"` // 固定条目有新版本
+	Conflicts       []string  `json:"conflicts,omitempty"`
+	Errors          []string  `json:"errors,omitempty"` // 单个条目的失败
+	Error           string    `json:"error,omitempty"`  // 拉取或解析索引失败
+}
+
+// Syncer 注册表同步器
+type Syncer struct {
+	store   Store
+	cfg     Config
+	sources []fetcher
+
+	mu   sync.Mutex // 串行化同步、手动更新与固定
+	last []SourceResult
+}
+
+// NewSyncer 创建注册表同步器
+func NewSyncer(store Store, cfg Config) (*Syncer, error) {
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Hour
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 2 * time.Minute
+	}
+	s := &Syncer{store: store, cfg: cfg}
+	seen := make(map[string]bool)
+	for _, src := range cfg.Sources {
+		if src.Name == "" || src.URL == "" {
+			return nil, fmt.Errorf("registry source requires name and url")
+		}
+		if seen[src.Name] {
+			return nil, fmt.Errorf("duplicate registry source %q", src.Name)
+		}
+		seen[src.Name] = true
+		f, err := newFetcher(src, cfg.CacheDir)
+		if err != nil {
+			return nil, err
+		}
+		s.sources = append(s.sources, f)
+	}
+	return s, nil
+}
+
+// Config 返回生效的配置
+func (s *Syncer) Config() Config {
+	return s.cfg
+}
+
+// Start 启动定时同步（阻塞直到 ctx 取消，未启用或没有注册表时直接返回）
+func (s *Syncer) Start(ctx context.Context) {
+	if !s.cfg.Enabled || len(s.sources) == 0 {
+		return
+	}
+	slog.InfoContext(ctx, "registry.start", "sources", len(s.sources), "interval", s.cfg.Interval)
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		s.SyncAll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// LastResults 最近一轮同步的结果
+func (s *Syncer) LastResults() []SourceResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]SourceResult(nil), s.last...)
+}
+
+// SyncAll 依次同步所有注册表
+func (s *Syncer) SyncAll(ctx context.Context) []SourceResult {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 同一条目由先列出的注册表提供，后面的注册表提供的同 ID 条目视为冲突
+	claimed := make(map[string]string)
+	results := make([]SourceResult, 0, len(s.sources))
+	for _, f := range s.sources {
+		res := s.syncSource(ctx, f, claimed)
+		level := slog.LevelInfo
+		if res.Error != "" {
+			level = slog.LevelError
+		}
+		slog.Log(ctx, level, "registry.sync", "source", res.Source, "items", res.Items, "installed", res.Installed, "updated", res.Updated,
+			"update_available", res.UpdateAvailable, "conflicts", len(res.Conflicts), "errors", len(res.Errors), "error", res.Error)
+		results = append(results, res)
+	}
+	s.last = results
+	return results
+}
+
+// syncSource 同步单个注册表
+func (s *Syncer) syncSource(ctx context.Context, f fetcher, claimed map[string]string) SourceResult {
+	name := f.source().Name
+	res := SourceResult{Source: name, SyncedAt: time.Now()}
+
+	fetchCtx, cancel := context.WithTimeout(ctx, s.cfg.Timeout)
+	entries, err := loadIndex(fetchCtx, f)
+	cancel()
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Items = len(entries)
+
+	for _, e := range entries {
+		id := model.RegistryItemID(e.Kind, e.ID)
+		if owner, ok := claimed[id]; ok && owner != name {
+			res.Conflicts = append(res.Conflicts, fmt.Sprintf("%s: provided by registry %s", id, owner))
+			continue
+		}
+		claimed[id] = name
+
+		outcome, err := s.syncEntry(ctx, name, e, res.SyncedAt)
+		if err != nil {
+			slog.ErrorContext(ctx, "registry.sync.item_failed", "source", name, "id", id, "error", err)
+			if errors.Is(err, errConflict) {
+				res.Conflicts = append(res.Conflicts, err.Error())
+			} else {
+				res.Errors = append(res.Errors, fmt.Sprintf("%s: %v", id, err))
+			}
+			continue
+		}
+		switch outcome {
+		case outcomeInstalled:
+			res.Installed++
+		case outcomeUpdated:
+			res.Updated++
+		case outcomePinned:
+			res.UpdateAvailable++
+		}
+	}
+	return res
+}
+
+type outcome int
+
+const (
+	outcomeUnchanged outcome = iota
+	outcomeInstalled
+	outcomeUpdated
+	outcomePinned
+)
+
+var errConflict = errors.New("conflicts with a local item")
+
+// syncEntry 刷新条目的上游信息，按固定状态决定是否安装
+func (s *Syncer) syncEntry(ctx context.Context, source string, e resolvedEntry, now time.Time) (outcome, error) {
+	id := model.RegistryItemID(e.Kind, e.ID)
+	item, err := s.store.GetRegistryItem(ctx, id)
+	if err != nil {
+		return outcomeUnchanged, err
+	}
+	if item == nil {
+		// 首次出现：只接管内置条目或本地不存在的条目
+		local, err := s.localItem(ctx, e.Kind, e.ID)
+		if err != nil {
+			return outcomeUnchanged, err
+		}
+		if local == localCustom {
+			return outcomeUnchanged, fmt.Errorf("%s: %w", id, errConflict)
+		}
+		item = &model.RegistryItem{ID: id, Kind: e.Kind, ItemID: e.ID}
+	} else if item.Source != source {
+		return outcomeUnchanged, fmt.Errorf("%s: managed by registry %s: %w", id, item.Source, errConflict)
+	}
+
+	item.Source = source
+	item.UpstreamVersion = e.Version
+	item.UpstreamDigest = digest(e.Spec)
+	item.UpstreamSpec = e.Spec
+	item.SyncedAt = now
+
+	result := outcomeUnchanged
+	switch {
+	case item.InstalledVersion == "":
+		result = outcomeInstalled
+	case !item.UpdateAvailable():
+	case item.Pinned:
+		result = outcomePinned
+	default:
+		result = outcomeUpdated
+	}
+	if result == outcomeInstalled || result == outcomeUpdated {
+		if err := s.install(ctx, item, now); err != nil {
+			return outcomeUnchanged, err
+		}
+	}
+	return result, s.store.UpsertRegistryItem(ctx, item)
+}
+
+// Items 列出同步记录
+func (s *Syncer) Items(ctx context.Context) ([]*model.RegistryItem, error) {
+	return s.store.ListRegistryItems(ctx)
+}
+
+// Update 安装条目的上游版本（固定的条目更新后仍保持固定）
+func (s *Syncer) Update(ctx context.Context, id string) (*model.RegistryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.store.GetRegistryItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	if item.InstalledVersion == item.UpstreamVersion {
+		return item, nil
+	}
+	if err := s.install(ctx, item, time.Now()); err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "registry.update", "id", item.ID, "version", item.InstalledVersion)
+	return item, s.store.UpsertRegistryItem(ctx, item)
+}
+
+// SetPinned 固定或取消固定条目（取消固定不会立即更新，下一次同步时生效）
+func (s *Syncer) SetPinned(ctx context.Context, id string, pinned bool) (*model.RegistryItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	item, err := s.store.GetRegistryItem(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil {
+		return nil, ErrItemNotFound
+	}
+	if pinned && item.InstalledVersion == "" {
+		return nil, ErrNotInstalled
+	}
+	item.Pinned = pinned
+	slog.InfoContext(ctx, "registry.pin", "id", item.ID, "pinned", pinned, "version", item.InstalledVersion)
+	return item, s.store.UpsertRegistryItem(ctx, item)
+}
+
+// digest 上游内容的 SHA-256
+func digest(spec json.RawMessage) string {
+	sum := sha256.Sum256(spec)
+	return hex.EncodeToString(sum[:])
+}
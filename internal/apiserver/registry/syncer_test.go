@@ -0,0 +1,311 @@
+package registry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存存储：同步记录、任务模板、Agent 模板与技能
+type mockStore struct {
+	items          map[string]*model.RegistryItem
+	taskTemplates  map[string]*model.TaskTemplate
+	agentTemplates map[string]*model.AgentTemplate
+	skills         map[string]*model.Skill
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		items:          map[string]*model.RegistryItem{},
+		taskTemplates:  map[string]*model.TaskTemplate{},
+		agentTemplates: map[string]*model.AgentTemplate{},
+		skills:         map[string]*model.Skill{},
+	}
+}
+
+func (m *mockStore) UpsertRegistryItem(_ context.Context, item *model.RegistryItem) error {
+	c := *item
+	m.items[item.ID] = &c
+	return nil
+}
+
+func (m *mockStore) GetRegistryItem(_ context.Context, id string) (*model.RegistryItem, error) {
+	if item, ok := m.items[id]; ok {
+		c := *item
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) ListRegistryItems(_ context.Context) ([]*model.RegistryItem, error) {
+	var items []*model.RegistryItem
+	for _, item := range m.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (m *mockStore) GetTaskTemplate(_ context.Context, id string) (*model.TaskTemplate, error) {
+	return m.taskTemplates[id], nil
+}
+
+func (m *mockStore) CreateTaskTemplate(_ context.Context, tmpl *model.TaskTemplate) error {
+	m.taskTemplates[tmpl.ID] = tmpl
+	return nil
+}
+
+func (m *mockStore) DeleteTaskTemplate(_ context.Context, id string) error {
+	delete(m.taskTemplates, id)
+	return nil
+}
+
+func (m *mockStore) GetAgentTemplate(_ context.Context, id string) (*model.AgentTemplate, error) {
+	return m.agentTemplates[id], nil
+}
+
+func (m *mockStore) CreateAgentTemplate(_ context.Context, tmpl *model.AgentTemplate) error {
+	m.agentTemplates[tmpl.ID] = tmpl
+	return nil
+}
+
+func (m *mockStore) UpdateAgentTemplate(_ context.Context, tmpl *model.AgentTemplate) error {
+	m.agentTemplates[tmpl.ID] = tmpl
+	return nil
+}
+
+func (m *mockStore) GetSkill(_ context.Context, id string) (*model.Skill, error) {
+	return m.skills[id], nil
+}
+
+func (m *mockStore) CreateSkill(_ context.Context, skill *model.Skill) error {
+	m.skills[skill.ID] = skill
+	return nil
+}
+
+func (m *mockStore) DeleteSkill(_ context.Context, id string) error {
+	delete(m.skills, id)
+	return nil
+}
+
+// registryServer 提供可修改的 HTTP 注册表（路径 -> 内容）
+type registryServer struct {
+	mu    sync.Mutex
+	files map[string]string
+}
+
+func (rs *registryServer) set(path, content string) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	rs.files[path] = content
+}
+
+func (rs *registryServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	content, ok := rs.files[r.URL.Path]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Write([]byte(content))
+}
+
+func indexYAML(templateVersion, skillVersion, skillInstructions string) string {
+	return `items:
+  - kind: task_template
+    id: tpl-review
+    version: "` + templateVersion + `"
+    file: templates/review.yaml
+  - kind: skill
+    id: skill-lint
+    version: "` + skillVersion + `"
+    spec:
+      name: lint
+      category: coding
+      instructions: "` + skillInstructions + `"
+`
+}
+
+func newTestSyncer(t *testing.T, store Store, sources ...Source) *Syncer {
+	t.Helper()
+	s, err := NewSyncer(store, Config{Sources: sources, CacheDir: t.TempDir()})
+	if err != nil {
+		t.Fatalf("NewSyncer: %v", err)
+	}
+	return s
+}
+
+// TestSyncAll_InstallUpdateAndPin 新条目安装、未固定条目自动更新、固定条目只提示有可用更新
+func TestSyncAll_InstallUpdateAndPin(t *testing.T) {
+	rs := &registryServer{files: map[string]string{
+		"/registry/index.yaml":            indexYAML("1.0.0", "1.0.0", "v1"),
+		"/registry/templates/review.yaml": "name: code review\ntype: development\ncategory: review\n",
+	}}
+	srv := httptest.NewServer(rs)
+	defer srv.Close()
+
+	ctx := context.Background()
+	store := newMockStore()
+	s := newTestSyncer(t, store, Source{Name: "official", URL: srv.URL + "/registry/index.yaml"})
+
+	res := s.SyncAll(ctx)
+	if len(res) != 1 || res[0].Error != "" || res[0].Installed != 2 {
+		t.Fatalf("首次同步结果 = %+v, 期望安装 2 个", res)
+	}
+	tmpl := store.taskTemplates["tpl-review"]
+	if tmpl == nil || tmpl.Name != "code review" || !tmpl.IsBuiltin || tmpl.Type != model.TaskTypeDevelopment {
+		t.Fatalf("任务模板 = %+v", tmpl)
+	}
+	if skill := store.skills["skill-lint"]; skill == nil || skill.Instructions != "v1" || skill.Version != "1.0.0" {
+		t.Fatalf("技能 = %+v", skill)
+	}
+
+	skillID := model.RegistryItemID(model.RegistryKindSkill, "skill-lint")
+	if _, err := s.SetPinned(ctx, skillID, true); err != nil {
+		t.Fatalf("SetPinned: %v", err)
+	}
+	rs.set("/registry/index.yaml", indexYAML("1.1.0", "2.0.0", "v2"))
+	rs.set("/registry/templates/review.yaml", "name: code review v2\ntype: development\n")
+
+	res = s.SyncAll(ctx)
+	if res[0].Updated != 1 || res[0].UpdateAvailable != 1 {
+		t.Fatalf("第二次同步结果 = %+v, 期望更新 1 个、固定 1 个", res[0])
+	}
+	if store.taskTemplates["tpl-review"].Name != "code review v2" {
+		t.Errorf("未固定的模板应自动更新")
+	}
+	item := store.items[skillID]
+	if store.skills["skill-lint"].Instructions != "v1" || item.InstalledVersion != "1.0.0" || !item.UpdateAvailable() {
+		t.Errorf("固定的技能不应更新: item=%+v", item)
+	}
+
+	item, err := s.Update(ctx, skillID)
+	if err != nil {
+		t.Fatalf("Update: %v", err)
+	}
+	if item.InstalledVersion != "2.0.0" || !item.Pinned || item.UpdateAvailable() || store.skills["skill-lint"].Instructions != "v2" {
+		t.Errorf("手动更新后 item=%+v skill=%+v", item, store.skills["skill-lint"])
+	}
+}
+
+// TestSyncAll_Conflicts 不覆盖本地自定义条目，可接管内置条目；后列出的注册表不能覆盖先列出的
+func TestSyncAll_Conflicts(t *testing.T) {
+	index := `items:
+  - kind: task_template
+    id: tpl-custom
+    version: "1"
+    spec: {name: upstream custom}
+  - kind: agent_template
+    id: builtin-agent
+    version: "1"
+    spec: {name: upstream agent, type: qwen-code}
+`
+	rs := &registryServer{files: map[string]string{"/a.yaml": index, "/b.yaml": index}}
+	srv := httptest.NewServer(rs)
+	defer srv.Close()
+
+	store := newMockStore()
+	store.taskTemplates["tpl-custom"] = &model.TaskTemplate{ID: "tpl-custom", Name: "mine"}
+	store.agentTemplates["builtin-agent"] = &model.AgentTemplate{ID: "builtin-agent", Name: "old", IsBuiltin: true}
+	s := newTestSyncer(t, store, Source{Name: "a", URL: srv.URL + "/a.yaml"}, Source{Name: "b", URL: srv.URL + "/b.yaml"})
+
+	res := s.SyncAll(context.Background())
+	if res[0].Installed != 1 || len(res[0].Conflicts) != 1 {
+		t.Errorf("注册表 a 结果 = %+v, 期望安装 1 个、冲突 1 个", res[0])
+	}
+	if len(res[1].Conflicts) != 2 || res[1].Installed != 0 {
+		t.Errorf("注册表 b 结果 = %+v, 期望全部冲突", res[1])
+	}
+	if store.taskTemplates["tpl-custom"].Name != "mine" {
+		t.Errorf("本地自定义模板被覆盖")
+	}
+	if store.agentTemplates["builtin-agent"].Name != "upstream agent" {
+		t.Errorf("内置 Agent 模板未被接管")
+	}
+}
+
+// TestSyncAll_InvalidIndex 索引无效时整个注册表跳过并记录错误
+func TestSyncAll_InvalidIndex(t *testing.T) {
+	tests := map[string]string{
+		"缺少版本":  "items:\n  - {kind: skill, id: s, spec: {name: s}}\n",
+		"未知类型":  "items:\n  - {kind: workflow, id: w, version: '1', spec: {name: w}}\n",
+		"缺少内容":  "items:\n  - {kind: skill, id: s, version: '1'}\n",
+		"重复条目":  "items:\n  - {kind: skill, id: s, version: '1', spec: {name: s}}\n  - {kind: skill, id: s, version: '2', spec: {name: s}}\n",
+		"文件不存在": "items:\n  - {kind: skill, id: s, version: '1', file: missing.yaml}\n",
+	}
+	for name, index := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(&registryServer{files: map[string]string{"/index.yaml": index}})
+			defer srv.Close()
+			store := newMockStore()
+			res := newTestSyncer(t, store, Source{Name: "r", URL: srv.URL + "/index.yaml"}).SyncAll(context.Background())
+			if res[0].Error == "" || len(store.items) != 0 {
+				t.Errorf("结果 = %+v, 期望索引错误且不写入条目", res[0])
+			}
+		})
+	}
+}
+
+// TestSyncAll_GitSource 从 git 仓库同步，索引中的文件相对于索引路径解析
+func TestSyncAll_GitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	repo := t.TempDir()
+	write := func(name, content string) {
+		path := filepath.Join(repo, name)
+		os.MkdirAll(filepath.Dir(path), 0o755)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	write("registry/index.yaml", "items:\n  - {kind: skill, id: skill-git, version: '1', file: skills/git.yaml}\n")
+	write("registry/skills/git.yaml", "name: from git\ncategory: coding\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-qm", "init")
+
+	store := newMockStore()
+	s := newTestSyncer(t, store, Source{Name: "git", Type: SourceTypeGit, URL: repo, Index: "registry/index.yaml"})
+	if res := s.SyncAll(context.Background()); res[0].Error != "" || res[0].Installed != 1 {
+		t.Fatalf("首次同步结果 = %+v", res[0])
+	}
+
+	write("registry/index.yaml", "items:\n  - {kind: skill, id: skill-git, version: '2', file: skills/git.yaml}\n")
+	write("registry/skills/git.yaml", "name: from git v2\ncategory: coding\n")
+	git("commit", "-qam", "v2")
+	if res := s.SyncAll(context.Background()); res[0].Error != "" || res[0].Updated != 1 {
+		t.Fatalf("第二次同步结果 = %+v", res[0])
+	}
+	if store.skills["skill-git"].Name != "from git v2" {
+		t.Errorf("技能 = %+v, 期望拉取到新提交", store.skills["skill-git"])
+	}
+}
+
+// TestSetPinned 未安装的条目不能固定
+func TestSetPinned(t *testing.T) {
+	store := newMockStore()
+	store.items["skill:s"] = &model.RegistryItem{ID: "skill:s", Kind: model.RegistryKindSkill, ItemID: "s", UpstreamVersion: "1"}
+	s := newTestSyncer(t, store)
+
+	if _, err := s.SetPinned(context.Background(), "skill:x", true); err != ErrItemNotFound {
+		t.Errorf("err = %v, 期望 ErrItemNotFound", err)
+	}
+	if _, err := s.SetPinned(context.Background(), "skill:s", true); err != ErrNotInstalled {
+		t.Errorf("err = %v, 期望 ErrNotInstalled", err)
+	}
+}
@@ -0,0 +1,380 @@
+// Package replay Run 回放（Adapter 开发用）
+//
+// POST /api/v1/runs/{id}/replay 把已存储 Run 的原始输出行重新送入 Adapter.ParseEvent，
+// 解析结果写入一个新的合成 Run（同一任务下，快照带 replay 标记），并与原 Run 逐行比对事件类型。
+// 可以指定其他适配器版本或通用适配器配置，用历史真实会话验证解析器改动，无需重新执行任务。
+//
+// 合成 Run 创建时即为终态（不进入调度），事件直接写入存储层，不经过上报流程：
+// 不累计用量、不触发审批与人工反馈。
+package replay
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/nodemanager/adapter/aider"
+	"agents-admin/internal/nodemanager/adapter/claude"
+	"agents-admin/internal/nodemanager/adapter/gemini"
+	"agents-admin/internal/nodemanager/adapter/generic"
+	"agents-admin/internal/nodemanager/adapter/qwencode"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+const (
+	// eventBatchSize 读取原 Run 事件与写入合成事件的批大小
+	eventBatchSize = 1000
+	// maxReportedIssues 响应中逐条列出的解析错误与比对差异上限（计数不受限制）
+	maxReportedIssues = 100
+	// maxRequestSize 请求体上限
+	maxRequestSize = 1 << 20
+)
+
+// Store 回放需要的存储接口
+type Store interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetAgentType(ctx context.Context, id string) (*model.AgentTypeConfig, error)
+	CreateRun(ctx context.Context, run *model.Run) error
+	CreateEvents(ctx context.Context, events []*model.Event) error
+	AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error)
+}
+
+// EventReader 原 Run 事件读取入口（启用数据分层时可读取已归档的事件）
+type EventReader interface {
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+}
+
+// Handler Run 回放处理器
+type Handler struct {
+	store    Store
+	events   EventReader
+	registry *adapter.Registry
+}
+
+// NewHandler 创建 Run 回放处理器（内置适配器与 NodeManager 一致）
+func NewHandler(store Store, events EventReader) *Handler {
+	registry := adapter.NewRegistry()
+	registry.Register(qwencode.New())
+	registry.Register(gemini.New())
+	registry.Register(claude.New())
+	registry.Register(aider.New())
+	return &Handler{store: store, events: events, registry: registry}
+}
+
+// RegisterRoutes 注册路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/runs/{id}/replay", h.Replay)
+}
+
+// Request 回放请求（请求体可省略）
+type Request struct {
+	// Adapter 适配器名称（如 claude-v1），为空时按原 Run 的 Agent 类型选择
+	Adapter string `json:"adapter,omitempty"`
+
+	// AdapterSpec 通用适配器配置，覆盖 Agent 类型上保存的配置（验证自定义类型的规则改动），与 Adapter 互斥
+	AdapterSpec *model.GenericAdapterSpec `json:"adapter_spec,omitempty"`
+}
+
+// LineError 原始输出行的解析错误
+type LineError struct {
+	Line  int    `json:"line"`  // 原始输出行号（从 1 开始）
+	Seq   int    `json:"seq"`   // 原 Run 中该行所属事件的序号
+	Error string `json:"error"` // 解析错误
+}
+
+// Diff 原 Run 与回放结果的事件类型差异（未产生事件时类型为空）
+type Diff struct {
+	Line     int    `json:"line"`
+	Seq      int    `json:"seq"`
+	Stored   string `json:"stored"`
+	Replayed string `json:"replayed"`
+}
+
+// Result 回放结果
+type Result struct {
+	Run         *model.Run  `json:"run"`           // 合成 Run
+	SourceRunID string      `json:"source_run_id"` // 原 Run
+	Adapter     string      `json:"adapter"`       // 使用的适配器
+	Lines       int         `json:"lines"`         // 回放的原始输出行数
+	Events      int         `json:"events"`        // 合成 Run 的事件数（含用量事件）
+	Ignored     int         `json:"ignored"`       // 未产生事件的行数
+	ErrorCount  int         `json:"error_count"`   // 解析失败的行数
+	Errors      []LineError `json:"errors,omitempty"`
+	DiffCount   int         `json:"diff_count"` // 事件类型与原 Run 不一致的行数
+	Diffs       []Diff      `json:"diffs,omitempty"`
+}
+
+// Replay 回放 Run 的原始输出
+//
+// 路由: POST /api/v1/runs/{id}/replay
+//
+// 路径参数:
+//   - id: 原 Run ID
+//
+// 请求体（可选）:
+//
+//	{"adapter": "claude-v1"} 或 {"adapter_spec": {...}}
+//
+// 响应:
+//   - 201 Created: 返回 Result；存在解析错误时合成 Run 为 failed
+//   - 400 Bad Request: 请求体格式错误、找不到适配器、原 Run 没有原始输出
+//   - 404 Not Found: Run 不存在
+//   - 410 Gone: 事件已按项目保留策略删除
+//   - 500 Internal Server Error: 服务器内部错误
+func (h *Handler) Replay(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	sourceID := r.PathValue("id")
+
+	var req Request
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+	if req.Adapter != "" && req.AdapterSpec != nil {
+		writeError(w, http.StatusBadRequest, "adapter and adapter_spec are mutually exclusive")
+		return
+	}
+
+	source, err := h.store.GetRun(ctx, sourceID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if source == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	a, err := h.selectAdapter(ctx, source, req)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	lines, err := h.rawLines(storage.WithReadReplica(ctx), sourceID)
+	if errors.Is(err, lifecycle.ErrEventsPurged) {
+		writeError(w, http.StatusGone, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get events")
+		return
+	}
+	if len(lines) == 0 {
+		writeError(w, http.StatusBadRequest, "run has no raw output to replay")
+		return
+	}
+
+	res, events := replay(a, lines)
+	res.SourceRunID = sourceID
+	res.Run = syntheticRun(source, res, time.Now())
+	for _, e := range events {
+		e.RunID = res.Run.ID
+	}
+
+	if err := h.store.CreateRun(ctx, res.Run); err != nil {
+		slog.ErrorContext(ctx, "replay.create.failed", "source_run_id", sourceID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create replay run")
+		return
+	}
+	// 合成事件的序号同样由存储层分配，合成 Run 的事件计数器与事件保持一致
+	first, err := h.store.AllocateEventSeqs(ctx, res.Run.ID, len(events))
+	if err != nil {
+		slog.ErrorContext(ctx, "replay.events.failed", "run_id", res.Run.ID, "source_run_id", sourceID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to store replay events")
+		return
+	}
+	for i, e := range events {
+		e.Seq = first + i
+	}
+	for start := 0; start < len(events); start += eventBatchSize {
+		if err := h.store.CreateEvents(ctx, events[start:min(start+eventBatchSize, len(events))]); err != nil {
+			slog.ErrorContext(ctx, "replay.events.failed", "run_id", res.Run.ID, "source_run_id", sourceID, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to store replay events")
+			return
+		}
+	}
+	slog.InfoContext(ctx, "replay.create.success", "run_id", res.Run.ID, "source_run_id", sourceID,
+		"adapter", res.Adapter, "lines", res.Lines, "events", res.Events, "errors", res.ErrorCount,
+		"diffs", res.DiffCount)
+	writeJSON(w, http.StatusCreated, res)
+}
+
+// selectAdapter 选择回放使用的适配器：adapter_spec → 指定名称 → 原 Run 的 Agent 类型（内置或自定义类型的通用适配器）
+func (h *Handler) selectAdapter(ctx context.Context, source *model.Run, req Request) (adapter.Adapter, error) {
+	agentType := runAgentType(source)
+	if req.AdapterSpec != nil {
+		a, err := generic.New(agentType, "", *req.AdapterSpec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid adapter_spec: %w", err)
+		}
+		return a, nil
+	}
+
+	name := req.Adapter
+	if name == "" {
+		if agentType == "" {
+			return nil, errors.New("run snapshot has no agent type, specify adapter")
+		}
+		name = model.AdapterNameForAgentType(agentType)
+	}
+	if a, ok := h.registry.Get(name); ok {
+		return a, nil
+	}
+	if req.Adapter == "" {
+		// 自定义 Agent 类型：按类型上保存的通用适配器配置回放
+		at, err := h.store.GetAgentType(ctx, agentType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get agent type %s: %w", agentType, err)
+		}
+		if at != nil && at.Adapter != nil {
+			return generic.New(at.ID, at.Image, *at.Adapter)
+		}
+	}
+	return nil, fmt.Errorf("adapter %q not found (available: %v)", name, h.registry.List())
+}
+
+// sourceLine 原 Run 中带原始输出的事件
+type sourceLine struct {
+	seq       int
+	eventType string
+	timestamp time.Time
+	raw       string
+}
+
+// rawLines 按 seq 顺序读取原 Run 的原始输出行
+func (h *Handler) rawLines(ctx context.Context, runID string) ([]sourceLine, error) {
+	var lines []sourceLine
+	fromSeq := 0
+	for {
+		events, err := h.events.GetEventsByRun(ctx, runID, fromSeq, eventBatchSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range events {
+			if e.Raw != nil && *e.Raw != "" {
+				lines = append(lines, sourceLine{seq: e.Seq, eventType: e.Type, timestamp: e.Timestamp, raw: *e.Raw})
+			}
+			fromSeq = e.Seq
+		}
+		if len(events) < eventBatchSize {
+			return lines, nil
+		}
+	}
+}
+
+// replay 逐行调用 ParseEvent，返回统计与合成事件（RunID 由调用方填充）
+//
+// 与 NodeManager.streamOutput 一致：每行最多产生一个事件，适配器提供用量时紧随其后追加 usage 事件；
+// 合成事件沿用原事件的时间戳并保留原始输出，合成 Run 可以再次回放。
+func replay(a adapter.Adapter, lines []sourceLine) (*Result, []*model.Event) {
+	res := &Result{Adapter: a.Name(), Lines: len(lines)}
+	usageReporter, _ := a.(adapter.UsageReporter)
+	var events []*model.Event
+	appendEvent := func(eventType string, payload map[string]interface{}, ts time.Time, raw *string) {
+		e := &model.Event{Seq: len(events) + 1, Type: eventType, Timestamp: ts, Raw: raw}
+		if payload != nil {
+			e.Payload, _ = json.Marshal(payload)
+		}
+		e.SchemaVersion, _ = model.MatchEventSchema(eventType, payload)
+		events = append(events, e)
+	}
+
+	for i, line := range lines {
+		n := i + 1
+		parsed, err := a.ParseEvent(line.raw)
+		replayed := ""
+		switch {
+		case err != nil:
+			res.ErrorCount++
+			if len(res.Errors) < maxReportedIssues {
+				res.Errors = append(res.Errors, LineError{Line: n, Seq: line.seq, Error: err.Error()})
+			}
+		case parsed == nil:
+			res.Ignored++
+		default:
+			replayed = string(parsed.Type)
+			raw := line.raw
+			appendEvent(replayed, parsed.Payload, line.timestamp, &raw)
+			if usageReporter != nil {
+				if usage := usageReporter.ExtractUsage(parsed); usage != nil {
+					appendEvent(string(adapter.EventUsage), usage.Payload(), line.timestamp, nil)
+				}
+			}
+		}
+		if replayed != line.eventType {
+			res.DiffCount++
+			if len(res.Diffs) < maxReportedIssues {
+				res.Diffs = append(res.Diffs, Diff{Line: n, Seq: line.seq, Stored: line.eventType, Replayed: replayed})
+			}
+		}
+	}
+	res.Events = len(events)
+	return res, events
+}
+
+// syntheticRun 构造合成 Run：与原 Run 同一任务，快照追加 replay 标记，创建即为终态
+func syntheticRun(source *model.Run, res *Result, now time.Time) *model.Run {
+	snapshot := map[string]interface{}{}
+	if len(source.Snapshot) > 0 {
+		json.Unmarshal(source.Snapshot, &snapshot)
+	}
+	snapshot["replay"] = map[string]interface{}{
+		"source_run_id": source.ID,
+		"adapter":       res.Adapter,
+	}
+	data, _ := json.Marshal(snapshot)
+
+	run := &model.Run{
+		ID:         generateID("run"),
+		TaskID:     source.TaskID,
+		Status:     model.RunStatusDone,
+		StartedAt:  &now,
+		FinishedAt: &now,
+		Snapshot:   data,
+		Priority:   source.Priority,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if res.ErrorCount > 0 {
+		msg := fmt.Sprintf("replay: %d of %d lines failed to parse", res.ErrorCount, res.Lines)
+		run.Status, run.Error = model.RunStatusFailed, &msg
+	}
+	return run
+}
+
+// runAgentType 从 Run 快照中读取 agent.type，缺失时返回空串
+func runAgentType(run *model.Run) string {
+	snapshot, err := model.ParseRunSnapshot(run.Snapshot)
+	if err != nil {
+		return ""
+	}
+	return snapshot.Agent.Type
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
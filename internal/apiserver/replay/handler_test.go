@@ -0,0 +1,162 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存实现的回放存储
+type memStore struct {
+	runs   map[string]*model.Run
+	events map[string][]*model.Event
+}
+
+func newMemStore() *memStore {
+	return &memStore{runs: map[string]*model.Run{}, events: map[string][]*model.Event{}}
+}
+
+func (m *memStore) GetRun(_ context.Context, id string) (*model.Run, error) { return m.runs[id], nil }
+
+func (m *memStore) GetAgentType(context.Context, string) (*model.AgentTypeConfig, error) {
+	return nil, nil
+}
+
+func (m *memStore) CreateRun(_ context.Context, run *model.Run) error {
+	m.runs[run.ID] = run
+	return nil
+}
+
+func (m *memStore) CreateEvents(_ context.Context, events []*model.Event) error {
+	for _, e := range events {
+		m.events[e.RunID] = append(m.events[e.RunID], e)
+	}
+	return nil
+}
+
+func (m *memStore) AllocateEventSeqs(_ context.Context, runID string, _ int) (int, error) {
+	return len(m.events[runID]) + 1, nil
+}
+
+func (m *memStore) GetEventsByRun(_ context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	var out []*model.Event
+	for _, e := range m.events[runID] {
+		if e.Seq > fromSeq && len(out) < limit {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+func seedRun(m *memStore, agentType string, lines map[int][2]string) {
+	m.runs["run-src"] = &model.Run{
+		ID:       "run-src",
+		TaskID:   "task-1",
+		Status:   model.RunStatusDone,
+		Snapshot: json.RawMessage(`{"name":"fix bug","agent":{"type":"` + agentType + `"}}`),
+	}
+	for seq := 1; seq <= len(lines); seq++ {
+		raw := lines[seq][1]
+		m.events["run-src"] = append(m.events["run-src"], &model.Event{
+			RunID: "run-src", Seq: seq, Type: lines[seq][0], Timestamp: time.Unix(int64(seq), 0), Raw: &raw,
+		})
+	}
+}
+
+func doReplay(t *testing.T, h *Handler, runID, body string) (*httptest.ResponseRecorder, Result) {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/runs/"+runID+"/replay", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var res Result
+	if w.Code == http.StatusCreated {
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+	}
+	return w, res
+}
+
+func TestReplay_CreatesSyntheticRun(t *testing.T) {
+	m := newMemStore()
+	seedRun(m, "claude", map[int][2]string{
+		1: {"message", `{"type":"assistant","message":{"content":[{"type":"text","text":"hi"}]}}`},
+		2: {"message", `{"type":"tool_use","name":"Bash"}`}, // 旧解析器的结果，与当前版本不一致
+		3: {"system", `not json`},
+	})
+	h := NewHandler(m, m)
+
+	w, res := doReplay(t, h, "run-src", "")
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	assert.Equal(t, "run-src", res.SourceRunID)
+	assert.Equal(t, "claude-v1", res.Adapter)
+	assert.Equal(t, 3, res.Lines)
+	assert.Equal(t, 2, res.Events)
+	assert.Equal(t, 1, res.Ignored)
+	assert.Equal(t, 2, res.DiffCount)
+	assert.Equal(t, []Diff{
+		{Line: 2, Seq: 2, Stored: "message", Replayed: "tool_use_start"},
+		{Line: 3, Seq: 3, Stored: "system", Replayed: ""},
+	}, res.Diffs)
+
+	run := m.runs[res.Run.ID]
+	require.NotNil(t, run)
+	assert.NotEqual(t, "run-src", run.ID)
+	assert.Equal(t, "task-1", run.TaskID)
+	assert.Equal(t, model.RunStatusDone, run.Status)
+	var snapshot map[string]interface{}
+	require.NoError(t, json.Unmarshal(run.Snapshot, &snapshot))
+	assert.Equal(t, "fix bug", snapshot["name"])
+	assert.Equal(t, map[string]interface{}{"source_run_id": "run-src", "adapter": "claude-v1"}, snapshot["replay"])
+
+	events := m.events[run.ID]
+	require.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Seq)
+	assert.Equal(t, "message", events[0].Type)
+	assert.Equal(t, time.Unix(1, 0), events[0].Timestamp)
+	require.NotNil(t, events[1].Raw)
+	assert.Equal(t, `{"type":"tool_use","name":"Bash"}`, *events[1].Raw)
+}
+
+func TestReplay_AdapterOverride(t *testing.T) {
+	m := newMemStore()
+	seedRun(m, "claude", map[int][2]string{1: {"message", `{"type":"assistant"}`}})
+	h := NewHandler(m, m)
+
+	w, res := doReplay(t, h, "run-src", `{"adapter":"gemini-v1"}`)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	assert.Equal(t, "gemini-v1", res.Adapter)
+
+	w, _ = doReplay(t, h, "run-src", `{"adapter":"missing-v9"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "missing-v9")
+}
+
+func TestReplay_Errors(t *testing.T) {
+	m := newMemStore()
+	h := NewHandler(m, m)
+
+	w, _ := doReplay(t, h, "run-missing", "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	seedRun(m, "claude", nil)
+	w, _ = doReplay(t, h, "run-src", "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "no raw output")
+
+	w, _ = doReplay(t, h, "run-src", `{"adapter":"claude-v1","adapter_spec":{}}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w, _ = doReplay(t, h, "run-src", `{`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
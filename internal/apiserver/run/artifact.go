@@ -0,0 +1,94 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ArtifactStore 定义产物接口需要的存储方法
+type ArtifactStore interface {
+	CreateArtifact(ctx context.Context, artifact *model.Artifact) error
+	ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error)
+}
+
+// CreateArtifactRequest 上报产物请求（由 NodeManager 调用）
+type CreateArtifactRequest struct {
+	Name        string `json:"name"`                   // 产物名称，如 "pull_request"
+	Path        string `json:"path"`                   // 存储路径或 URL
+	Size        *int64 `json:"size,omitempty"`         // 文件大小
+	ContentType string `json:"content_type,omitempty"` // MIME 类型
+}
+
+// ListArtifacts 列出 Run 的产物
+// GET /api/v1/runs/{id}/artifacts
+func (h *Handler) ListArtifacts(w http.ResponseWriter, r *http.Request) {
+	if h.artifacts == nil {
+		writeError(w, http.StatusNotImplemented, "artifact store not available")
+		return
+	}
+	id := r.PathValue("id")
+	artifacts, err := h.artifacts.ListArtifactsByRun(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list artifacts")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"artifacts": artifacts, "count": len(artifacts)})
+}
+
+// CreateArtifact 上报 Run 产物
+// POST /api/v1/runs/{id}/artifacts
+func (h *Handler) CreateArtifact(w http.ResponseWriter, r *http.Request) {
+	if h.artifacts == nil {
+		writeError(w, http.StatusNotImplemented, "artifact store not available")
+		return
+	}
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	var req CreateArtifactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	req.Path = strings.TrimSpace(req.Path)
+	if req.Name == "" || req.Path == "" {
+		writeError(w, http.StatusBadRequest, "name and path are required")
+		return
+	}
+
+	run, err := h.store.GetRun(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	artifact := &model.Artifact{
+		RunID:     id,
+		Name:      req.Name,
+		Path:      req.Path,
+		Size:      req.Size,
+		CreatedAt: time.Now(),
+	}
+	if req.ContentType != "" {
+		artifact.ContentType = &req.ContentType
+	}
+	if err := h.artifacts.CreateArtifact(ctx, artifact); err != nil {
+		slog.ErrorContext(ctx, "run.artifact.create.failed", "run_id", id, "name", req.Name, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create artifact")
+		return
+	}
+
+	slog.InfoContext(ctx, "run.artifact.created", "run_id", id, "name", req.Name, "path", req.Path)
+	writeJSON(w, http.StatusCreated, artifact)
+}
@@ -0,0 +1,176 @@
+// Package run 父子任务上下文传递
+//
+// 子任务创建时复制父任务已产出的上下文（task.context.inherited_context）。创建 Run 时再合并父任务当前产出的上下文
+// （派生子任务、工作流节点创建时父任务可能尚未产出），写入执行快照 inherited_context，NodeManager 将其写入工作空间的
+// .agent/context/ 供 Agent 读取。
+//
+// Run 成功结束后 NodeManager 收集 Agent 写入 .agent/context/produced/ 的文件，调用 POST /api/v1/runs/{id}/context
+// 上报，记录为所属任务的 produced_context（同类型同名的上下文项覆盖），供其子任务继承。
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// maxContextItems 单个任务产出的上下文项上限
+	maxContextItems = 50
+	// maxContextItemSize 单个上下文项内容上限（字节）
+	maxContextItemSize = 64 << 10
+	// maxContextBodySize 上报产出上下文的请求体上限
+	maxContextBodySize = 8 << 20
+)
+
+// contextItemTypes 支持的上下文项类型
+var contextItemTypes = map[string]bool{"file": true, "summary": true, "reference": true}
+
+// TaskContextStore 任务上下文存储接口
+type TaskContextStore interface {
+	UpdateTaskContext(ctx context.Context, id string, taskContext json.RawMessage) error
+}
+
+// ProduceContextRequest 上报 Run 产出的上下文
+type ProduceContextRequest struct {
+	Items []model.ContextItem `json:"items"`
+}
+
+// ProduceContext 记录 Run 产出的上下文（由 NodeManager 在 Run 成功结束后调用）
+// POST /api/v1/runs/{id}/context
+//
+// 请求体: {"items": [{"type": "file", "name": "api.md", "content": "..."}]}
+//
+// 上下文项按 type + name 合并到所属任务的 produced_context，source 为该任务；响应为更新后的任务上下文
+func (h *Handler) ProduceContext(w http.ResponseWriter, r *http.Request) {
+	if h.contexts == nil {
+		writeError(w, http.StatusNotImplemented, "task context not supported")
+		return
+	}
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	var req ProduceContextRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxContextBodySize)).Decode(&req); err != nil {
+		if bodylimit.IsTooLarge(err) {
+			bodylimit.WriteTooLarge(w, err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if err := validateContextItems(req.Items); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	run, err := h.store.GetRun(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	h.contextMu.Lock()
+	defer h.contextMu.Unlock()
+	task, err := h.store.GetTask(ctx, run.TaskID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get task")
+		return
+	}
+	if task == nil {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	tc := model.TaskContext{}
+	if task.Context != nil {
+		tc = *task.Context
+	}
+	for _, item := range req.Items {
+		item.Source = task.ID
+		tc.ProducedContext = mergeContextItem(tc.ProducedContext, item)
+	}
+	if len(tc.ProducedContext) > maxContextItems {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("task produced context exceeds %d items", maxContextItems))
+		return
+	}
+	data, _ := json.Marshal(tc)
+	if err := h.contexts.UpdateTaskContext(ctx, task.ID, data); err != nil {
+		slog.ErrorContext(ctx, "run.context.save_failed", "run_id", id, "task_id", task.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update task context")
+		return
+	}
+	slog.InfoContext(ctx, "run.context.produced", "run_id", id, "task_id", task.ID, "items", len(req.Items))
+	writeJSON(w, http.StatusOK, tc)
+}
+
+// validateContextItems 校验上报的上下文项
+func validateContextItems(items []model.ContextItem) error {
+	if len(items) > maxContextItems {
+		return fmt.Errorf("at most %d context items are allowed", maxContextItems)
+	}
+	for _, item := range items {
+		if !contextItemTypes[item.Type] {
+			return fmt.Errorf("invalid context item type %q", item.Type)
+		}
+		if item.Name == "" {
+			return fmt.Errorf("context item name is required")
+		}
+		if len(item.Content) > maxContextItemSize {
+			return fmt.Errorf("context item %s exceeds %d bytes", item.Name, maxContextItemSize)
+		}
+	}
+	return nil
+}
+
+// inheritedContext 子任务 Run 继承的上下文：创建时继承的上下文项中来自父任务的部分替换为父任务当前产出的上下文，
+// 读取父任务失败时保留创建时的副本
+func (h *Handler) inheritedContext(ctx context.Context, task *model.Task) []model.ContextItem {
+	var items []model.ContextItem
+	if task.Context != nil {
+		items = append(items, task.Context.InheritedContext...)
+	}
+	if task.ParentID == nil || *task.ParentID == "" {
+		return items
+	}
+	parent, err := h.store.GetTask(ctx, *task.ParentID)
+	if err != nil || parent == nil {
+		slog.ErrorContext(ctx, "run.context.parent_failed", "task_id", task.ID, "parent_id", *task.ParentID, "error", err)
+		return items
+	}
+	if parent.Context == nil || len(parent.Context.ProducedContext) == 0 {
+		return items
+	}
+	merged := make([]model.ContextItem, 0, len(items)+len(parent.Context.ProducedContext))
+	for _, item := range items {
+		if item.Source != parent.ID {
+			merged = append(merged, item)
+		}
+	}
+	for _, item := range parent.Context.ProducedContext {
+		if item.Source == "" {
+			item.Source = parent.ID
+		}
+		merged = mergeContextItem(merged, item)
+	}
+	return merged
+}
+
+// mergeContextItem 追加上下文项，同来源、同类型同名的项被替换
+func mergeContextItem(items []model.ContextItem, item model.ContextItem) []model.ContextItem {
+	for i, existing := range items {
+		if existing.Type == item.Type && existing.Name == item.Name && existing.Source == item.Source {
+			items[i] = item
+			return items
+		}
+	}
+	return append(items, item)
+}
@@ -0,0 +1,81 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestProduceContext(t *testing.T) {
+	store := &mockSessionStore{mockRunStore: newMockStore()}
+	store.tasks["task-1"] = &model.Task{ID: "task-1", Name: "t", Context: &model.TaskContext{
+		ProducedContext: []model.ContextItem{{Type: "summary", Name: "result", Content: "旧的结果", Source: "task-1"}},
+	}}
+	store.runs["run-1"] = &model.Run{ID: "run-1", TaskID: "task-1", Status: model.RunStatusRunning}
+	mux := http.NewServeMux()
+	NewHandlerWithInterfaces(store, nil).RegisterRoutes(mux)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/runs/run-1/context", strings.NewReader(body)))
+		return w
+	}
+	w := post(`{"items":[{"type":"summary","name":"result","content":"新的结果"},{"type":"file","name":"schema.sql","content":"CREATE TABLE t;"}]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	produced := store.tasks["task-1"].Context.ProducedContext
+	if len(produced) != 2 || produced[0].Content != "新的结果" || produced[1].Name != "schema.sql" || produced[1].Source != "task-1" {
+		t.Errorf("produced_context = %+v", produced)
+	}
+
+	for name, body := range map[string]string{
+		"非法类型": `{"items":[{"type":"image","name":"x"}]}`,
+		"缺少名称": `{"items":[{"type":"file","content":"x"}]}`,
+		"内容过大": `{"items":[{"type":"file","name":"x","content":"` + strings.Repeat("a", maxContextItemSize+1) + `"}]}`,
+	} {
+		if w := post(body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, 期望 400", name, w.Code)
+		}
+	}
+}
+
+func TestStartRun_InheritedContext(t *testing.T) {
+	store := newMockStore()
+	parentID := "task-parent"
+	store.tasks[parentID] = &model.Task{ID: parentID, Name: "p", Context: &model.TaskContext{
+		ProducedContext: []model.ContextItem{
+			{Type: "summary", Name: "plan", Content: "最新的计划", Source: parentID},
+			{Type: "file", Name: "api.md", Content: "GET /users"},
+		},
+	}}
+	// 创建时复制的父任务上下文已过期，另有用户指定的上下文
+	store.tasks["task-child"] = &model.Task{ID: "task-child", Name: "c", Type: "qwen-code", ParentID: &parentID,
+		Prompt: &model.Prompt{Content: "p"},
+		Context: &model.TaskContext{InheritedContext: []model.ContextItem{
+			{Type: "summary", Name: "plan", Content: "旧的计划", Source: parentID},
+			{Type: "reference", Name: "spec", Content: "https://example.com/spec"},
+		}},
+	}
+
+	run, err := NewHandlerWithInterfaces(store, nil).StartRun(context.Background(), "task-child")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot struct {
+		InheritedContext []model.ContextItem `json:"inherited_context"`
+	}
+	json.Unmarshal(run.Snapshot, &snapshot)
+	got := map[string]model.ContextItem{}
+	for _, item := range snapshot.InheritedContext {
+		got[item.Name] = item
+	}
+	if len(got) != 3 || got["plan"].Content != "最新的计划" || got["api.md"].Source != parentID || got["spec"].Type != "reference" {
+		t.Errorf("inherited_context = %+v", snapshot.InheritedContext)
+	}
+}
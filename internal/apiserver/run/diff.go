@@ -0,0 +1,183 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/shared/model"
+)
+
+// maxDiffBodySize 变更报告请求体上限（NodeManager 侧 patch 上限 4 MiB，JSON 转义后留足余量）
+const maxDiffBodySize = 16 << 20
+
+// DiffObjectStore 变更报告对象存储（由 MinIO 客户端实现）
+type DiffObjectStore interface {
+	Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+// SetObjectStore 设置变更报告的对象存储（未设置时变更报告接口返回 503）
+func (h *Handler) SetObjectStore(objects DiffObjectStore) {
+	h.objects = objects
+}
+
+// diffKey 变更报告的对象存储 Key
+func diffKey(runID string) string {
+	return "runs/" + runID + "/diff.json"
+}
+
+// UploadDiff 上传 Run 的代码变更报告（由 NodeManager 在 Git 工作空间的 Run 结束后调用）
+// POST /api/v1/runs/{id}/diff
+//
+// 报告存入对象存储，并作为名为 "diff" 的 Run 产物记录；重复上传覆盖之前的报告。
+func (h *Handler) UploadDiff(w http.ResponseWriter, r *http.Request) {
+	if h.objects == nil || h.artifacts == nil {
+		writeError(w, http.StatusServiceUnavailable, "object storage not configured")
+		return
+	}
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	var diff model.RunDiff
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxDiffBodySize)).Decode(&diff); err != nil {
+		if bodylimit.IsTooLarge(err) {
+			bodylimit.WriteTooLarge(w, err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if diff.Files == nil {
+		diff.Files = []model.RunDiffFile{}
+	}
+	for _, f := range diff.Files {
+		if f.Path == "" {
+			writeError(w, http.StatusBadRequest, "file path is required")
+			return
+		}
+	}
+
+	run, err := h.store.GetRun(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	diff.RunID = id
+	if diff.CreatedAt.IsZero() {
+		diff.CreatedAt = time.Now()
+	}
+	data, err := json.Marshal(&diff)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to encode diff")
+		return
+	}
+	key := diffKey(id)
+	if err := h.objects.Upload(ctx, key, bytes.NewReader(data), int64(len(data)), "application/json"); err != nil {
+		slog.ErrorContext(ctx, "run.diff.upload.failed", "run_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to store diff")
+		return
+	}
+
+	// 重复上传时对象已被覆盖，只需保留一条产物记录
+	artifacts, err := h.artifacts.ListArtifactsByRun(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list artifacts")
+		return
+	}
+	if findDiffArtifact(artifacts) == nil {
+		size := int64(len(data))
+		contentType := "application/json"
+		artifact := &model.Artifact{
+			RunID:       id,
+			Name:        model.RunDiffArtifactName,
+			Path:        key,
+			Size:        &size,
+			ContentType: &contentType,
+			CreatedAt:   time.Now(),
+		}
+		if err := h.artifacts.CreateArtifact(ctx, artifact); err != nil {
+			slog.ErrorContext(ctx, "run.diff.artifact.failed", "run_id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to create artifact")
+			return
+		}
+	}
+
+	slog.InfoContext(ctx, "run.diff.uploaded", "run_id", id, "files", diff.FilesChanged, "additions", diff.Additions,
+		"deletions", diff.Deletions, "truncated", diff.Truncated)
+	diff.Patch = ""
+	writeJSON(w, http.StatusCreated, &diff)
+}
+
+// GetDiff 获取 Run 的代码变更报告
+// GET /api/v1/runs/{id}/diff
+//
+// 默认返回 JSON 报告（逐文件增删行数与 unified diff）；
+// format=patch 时只返回 unified diff（text/x-diff），可直接用 git apply 应用。
+func (h *Handler) GetDiff(w http.ResponseWriter, r *http.Request) {
+	if h.objects == nil || h.artifacts == nil {
+		writeError(w, http.StatusServiceUnavailable, "object storage not configured")
+		return
+	}
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "patch" {
+		writeError(w, http.StatusBadRequest, "format must be json or patch")
+		return
+	}
+
+	artifacts, err := h.artifacts.ListArtifactsByRun(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list artifacts")
+		return
+	}
+	artifact := findDiffArtifact(artifacts)
+	if artifact == nil {
+		writeError(w, http.StatusNotFound, "diff not found")
+		return
+	}
+
+	rc, err := h.objects.Download(ctx, artifact.Path)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.diff.download.failed", "run_id", id, "key", artifact.Path, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to read diff")
+		return
+	}
+	defer rc.Close()
+	var diff model.RunDiff
+	if err := json.NewDecoder(rc).Decode(&diff); err != nil {
+		slog.ErrorContext(ctx, "run.diff.decode.failed", "run_id", id, "key", artifact.Path, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to read diff")
+		return
+	}
+
+	if format == "patch" {
+		w.Header().Set("Content-Type", "text/x-diff; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, diff.Patch)
+		return
+	}
+	writeJSON(w, http.StatusOK, &diff)
+}
+
+// findDiffArtifact 查找变更报告产物
+func findDiffArtifact(artifacts []*model.Artifact) *model.Artifact {
+	for _, a := range artifacts {
+		if a.Name == model.RunDiffArtifactName {
+			return a
+		}
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package run
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memObjectStore 内存对象存储
+type memObjectStore map[string][]byte
+
+func (m memObjectStore) Upload(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	m[key] = data
+	return err
+}
+
+func (m memObjectStore) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	data, ok := m[key]
+	if !ok {
+		return nil, errors.New("no such key")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func TestDiff_UploadAndGet(t *testing.T) {
+	store := newMockStore()
+	store.runs["run-1"] = &model.Run{ID: "run-1", TaskID: "task-001", Status: model.RunStatusDone}
+	objects := memObjectStore{}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	handler.SetObjectStore(objects)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	do := func(method, url, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, url, strings.NewReader(body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := do("GET", "/api/v1/runs/run-1/diff", ""); w.Code != http.StatusNotFound {
+		t.Errorf("未上传时 HTTP 状态码 = %d, 期望 404", w.Code)
+	}
+
+	report := `{"base_commit":"abc123","files":[{"path":"main.go","status":"modified","additions":3,"deletions":1},` +
+		`{"path":"new.txt","old_path":"old.txt","status":"renamed","additions":0,"deletions":0}],` +
+		`"files_changed":2,"additions":3,"deletions":1,"patch":"diff --git a/main.go b/main.go\n"}`
+	for i := 0; i < 2; i++ {
+		if w := do("POST", "/api/v1/runs/run-1/diff", report); w.Code != http.StatusCreated {
+			t.Fatalf("HTTP 状态码 = %d, 期望 201, 响应: %s", w.Code, w.Body.String())
+		}
+	}
+	// 重复上传只保留一条产物记录
+	if len(store.artifacts) != 1 || store.artifacts[0].Name != "diff" || store.artifacts[0].Path != "runs/run-1/diff.json" {
+		t.Errorf("产物 = %+v", store.artifacts)
+	}
+
+	w := do("GET", "/api/v1/runs/run-1/diff", "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("HTTP 状态码 = %d, 期望 200", w.Code)
+	}
+	var diff model.RunDiff
+	if err := json.Unmarshal(w.Body.Bytes(), &diff); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if diff.RunID != "run-1" || diff.FilesChanged != 2 || len(diff.Files) != 2 ||
+		diff.Files[1].OldPath != "old.txt" || diff.Files[1].Status != model.RunDiffFileRenamed || diff.CreatedAt.IsZero() {
+		t.Errorf("变更报告 = %+v", diff)
+	}
+
+	w = do("GET", "/api/v1/runs/run-1/diff?format=patch", "")
+	if w.Code != http.StatusOK || w.Body.String() != "diff --git a/main.go b/main.go\n" ||
+		!strings.HasPrefix(w.Header().Get("Content-Type"), "text/x-diff") {
+		t.Errorf("patch = %d %q", w.Code, w.Body.String())
+	}
+	if w := do("GET", "/api/v1/runs/run-1/diff?format=html", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("无效 format HTTP 状态码 = %d, 期望 400", w.Code)
+	}
+}
+
+func TestDiff_Validation(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	// 未配置对象存储
+	req := httptest.NewRequest("GET", "/api/v1/runs/run-x/diff", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("HTTP 状态码 = %d, 期望 503", w.Code)
+	}
+
+	handler.SetObjectStore(memObjectStore{})
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"invalid json", `{`, http.StatusBadRequest},
+		{"empty path", `{"files":[{"status":"added"}]}`, http.StatusBadRequest},
+		{"run not found", `{"files":[]}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/api/v1/runs/run-x/diff", strings.NewReader(tt.body))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("%s: HTTP 状态码 = %d, 期望 %d", tt.name, w.Code, tt.code)
+		}
+	}
+}
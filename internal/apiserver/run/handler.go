@@ -6,13 +6,21 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/apiserver/idempotency"
+	"agents-admin/internal/apiserver/outbox"
 	"agents-admin/internal/shared/model"
 	"agents-admin/internal/shared/queue"
+	"agents-admin/internal/shared/secpolicy"
 	"agents-admin/internal/shared/storage"
 )
 
@@ -22,7 +30,9 @@ type RunStore interface {
 	CreateRun(ctx context.Context, run *model.Run) error
 	GetRun(ctx context.Context, id string) (*model.Run, error)
 	ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run, error)
+	ListRuns(ctx context.Context, filter storage.RunFilter) ([]*model.Run, int, error)
 	UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error
+	UpdateRunError(ctx context.Context, id string, errMsg string) error
 	UpdateTaskStatus(ctx context.Context, id string, status model.TaskStatus) error
 }
 
@@ -32,10 +42,40 @@ type RunScheduler interface {
 	ScheduleRun(ctx context.Context, runID, taskID string) (string, error)
 }
 
+// OutboxStore 事务发件箱：Run 与调度消息在同一事务中写入
+type OutboxStore interface {
+	CreateRunWithOutbox(ctx context.Context, run *model.Run, msgs ...*model.OutboxMessage) error
+}
+
+// SchedulingDecisionStore 调度决策审计（Run 详情中返回最近的调度决策）
+type SchedulingDecisionStore interface {
+	ListSchedulingDecisions(ctx context.Context, runID string, limit int) ([]*model.SchedulingDecision, error)
+}
+
 // Handler 执行领域 HTTP 处理器
 type Handler struct {
-	store     RunStore
-	scheduler RunScheduler // 调度队列（用于将 Run 加入调度）
+	store      RunStore
+	artifacts  ArtifactStore          // 产物存储（可选，nil 时产物接口返回 501）
+	objects    DiffObjectStore        // 变更报告对象存储（可选，nil 时变更报告接口返回 503）
+	hooks      HookStore              // 钩子解析（可选，nil 时不继承模板钩子、不展开 Skill 引用）
+	skills     SkillBundleStore       // 技能包解析（可选，nil 时 Run 不安装技能包）
+	policies   secpolicy.Store        // 安全策略解析（可选，nil 时快照不含 security_policy）
+	accounts   AccountPoolStore       // 项目账号池（可选，nil 时不为项目任务分配账号）
+	watchdog   WatchdogStore          // 超时巡检（可选，nil 时 StartWatchdog 直接返回）
+	reconciler ReconcileStore         // 孤儿 Run 回收（可选，nil 时 StartReconciler 直接返回）
+	orphans    *orphanTracker         // 心跳中连续缺失的 running Run 计数
+	scheduler  RunScheduler           // 调度队列（用于将 Run 加入调度）
+	outbox     OutboxStore            // 事务发件箱（可选，设置后调度消息经发件箱中继入队）
+	relay      *outbox.Relay          // 发件箱中继（与 outbox 同时设置）
+	onFinish   []func(run *model.Run) // Run 到达终态时的回调（可选，如通知工作流编排器、回写 Issue 评论）
+	sessions   SessionStore           // 持久会话的对话记录（可选，nil 时追问接口返回 501）
+	contexts   TaskContextStore       // 任务产出上下文（可选，nil 时上报接口返回 501）
+	contextMu  sync.Mutex             // 串行化追问与任务上下文（对话记录、产出上下文）的读改写
+
+	idempotency *idempotency.Guard      // 创建接口的幂等保护（可选，nil 时忽略 Idempotency-Key 请求头）
+	decisions   SchedulingDecisionStore // 调度决策审计（可选，nil 时 Run 详情不含调度决策）
+
+	watchdogTimeout atomic.Int64 // 超时巡检的默认执行时限（纳秒，可在运行中调整）
 }
 
 // NewHandler 创建执行处理器
@@ -45,21 +85,103 @@ func NewHandler(store storage.PersistentStore, scheduler queue.SchedulerQueue) *
 	if scheduler != nil {
 		s = scheduler
 	}
-	return &Handler{store: store, scheduler: s}
+	return &Handler{store: store, artifacts: store, hooks: store, skills: store, policies: store, accounts: store, watchdog: store, reconciler: store,
+		decisions: store, sessions: store, contexts: store, orphans: newOrphanTracker(), scheduler: s}
 }
 
 // NewHandlerWithInterfaces 使用接口创建处理器（用于测试）
 func NewHandlerWithInterfaces(store RunStore, scheduler RunScheduler) *Handler {
-	return &Handler{store: store, scheduler: scheduler}
+	h := &Handler{store: store, scheduler: scheduler, orphans: newOrphanTracker()}
+	if as, ok := store.(ArtifactStore); ok {
+		h.artifacts = as
+	}
+	if hs, ok := store.(HookStore); ok {
+		h.hooks = hs
+	}
+	if ss, ok := store.(SkillBundleStore); ok {
+		h.skills = ss
+	}
+	if ps, ok := store.(secpolicy.Store); ok {
+		h.policies = ps
+	}
+	if ps, ok := store.(AccountPoolStore); ok {
+		h.accounts = ps
+	}
+	if ws, ok := store.(WatchdogStore); ok {
+		h.watchdog = ws
+	}
+	if rs, ok := store.(ReconcileStore); ok {
+		h.reconciler = rs
+	}
+	if ds, ok := store.(SchedulingDecisionStore); ok {
+		h.decisions = ds
+	}
+	if ss, ok := store.(SessionStore); ok {
+		h.sessions = ss
+	}
+	if cs, ok := store.(TaskContextStore); ok {
+		h.contexts = cs
+	}
+	return h
+}
+
+// SetOutbox 启用事务发件箱（需在处理请求之前调用）
+//
+// 启用后新建 Run 与其调度消息在同一事务中写入，提交后唤醒中继入队；
+// 写库后、入队前崩溃不再依赖保底轮询恢复。存储不支持发件箱时保持直接入队。
+func (h *Handler) SetOutbox(relay *outbox.Relay) {
+	store, ok := h.store.(OutboxStore)
+	if !ok || relay == nil {
+		return
+	}
+	h.outbox, h.relay = store, relay
+}
+
+// PublishScheduleRun 投递 run.schedule 发件箱消息：将 Run 加入调度队列
+func (h *Handler) PublishScheduleRun(ctx context.Context, msg *model.OutboxMessage) error {
+	if h.scheduler == nil {
+		return errors.New("scheduler queue not configured")
+	}
+	var p model.ScheduleRunPayload
+	if err := json.Unmarshal(msg.Payload, &p); err != nil {
+		return err
+	}
+	msgID, err := h.scheduleRun(ctx, &model.Run{ID: p.RunID, TaskID: p.TaskID, Priority: model.Priority(p.Priority)})
+	if err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "run.create.queue.success", "run_id", p.RunID, "task_id", p.TaskID, "msg_id", msgID, "via", "outbox")
+	return nil
+}
+
+// SetIdempotency 设置创建接口的幂等保护（需在 RegisterRoutes 之前调用）
+func (h *Handler) SetIdempotency(g *idempotency.Guard) {
+	h.idempotency = g
+}
+
+// OnRunFinished 注册 Run 到达终态时的回调（如通知工作流编排器推进下游节点）
+//
+// 回调在请求处理协程中同步调用，耗时操作应自行异步执行。
+func (h *Handler) OnRunFinished(fn func(run *model.Run)) {
+	h.onFinish = append(h.onFinish, fn)
 }
 
 // RegisterRoutes 注册执行相关路由
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
-	mux.HandleFunc("POST /api/v1/tasks/{id}/runs", h.Create)
+	mux.HandleFunc("POST /api/v1/tasks/{id}/runs", h.idempotency.Wrap(h.Create))
 	mux.HandleFunc("GET /api/v1/tasks/{id}/runs", h.ListByTask)
+	mux.HandleFunc("POST /api/v1/tasks/{id}/followup", h.idempotency.Wrap(h.FollowUp))
+	mux.HandleFunc("GET /api/v1/runs", h.List)
 	mux.HandleFunc("GET /api/v1/runs/{id}", h.Get)
 	mux.HandleFunc("PATCH /api/v1/runs/{id}", h.Update)
 	mux.HandleFunc("POST /api/v1/runs/{id}/cancel", h.Cancel)
+	mux.HandleFunc("POST /api/v1/runs/{id}/pause", h.Pause)
+	mux.HandleFunc("POST /api/v1/runs/{id}/resume", h.Resume)
+	mux.HandleFunc("GET /api/v1/runs/{id}/artifacts", h.ListArtifacts)
+	mux.HandleFunc("POST /api/v1/runs/{id}/artifacts", h.CreateArtifact)
+	mux.HandleFunc("GET /api/v1/runs/{id}/diff", h.GetDiff)
+	mux.HandleFunc("POST /api/v1/runs/{id}/diff", h.UploadDiff)
+	mux.HandleFunc("POST /api/v1/runs/{id}/context", h.ProduceContext)
 }
 
 // UpdateRequest 更新 Run 的请求体（使用 OpenAPI 生成的类型）
@@ -72,77 +194,231 @@ type UpdateRequest = openapi.UpdateRunRequest
 //  1. 写入 PostgreSQL（必须成功）
 //  2. 写入 Redis Streams（允许失败，有保底轮询）
 //  3. 更新 Task 状态
+//
+// 任务展开的执行快照不合法（如缺少 Agent 类型或提示词）时返回 422，见 writeStartError。
 func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	taskID := r.PathValue("id")
+	run, err := h.StartRun(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeStartError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, run)
+}
+
+// writeStartError 写入创建 Run 失败的响应
+//
+// 快照校验失败时返回 422，并在 fields 中列出不合法的字段：
+//
+//	{"error": "invalid run snapshot", "fields": [{"field": "prompt", "message": "is required"}]}
+func writeStartError(w http.ResponseWriter, err error) {
+	var se *startError
+	if !errors.As(err, &se) {
+		writeError(w, http.StatusInternalServerError, "failed to create run")
+		return
+	}
+	var snapshotErr *model.SnapshotError
+	if errors.As(se.err, &snapshotErr) {
+		writeJSON(w, se.status, map[string]interface{}{"error": se.message, "fields": snapshotErr.Fields})
+		return
+	}
+	writeError(w, se.status, se.message)
+}
+
+// startError 创建 Run 失败的原因（携带对应的 HTTP 状态码与对外错误信息）
+type startError struct {
+	status  int
+	message string
+	err     error
+}
+
+func (e *startError) Error() string {
+	if e.err != nil {
+		return e.message + ": " + e.err.Error()
+	}
+	return e.message
+}
+
+func (e *startError) Unwrap() error { return e.err }
+
+// ErrTaskNotFound 创建 Run 时任务不存在
+var ErrTaskNotFound = errors.New("task not found")
+
+// StartRun 为任务创建一次执行并加入调度队列
+//
+// 供 HTTP 接口与工作流编排器共用；任务不存在时返回的错误满足 errors.Is(err, ErrTaskNotFound)。
+func (h *Handler) StartRun(ctx context.Context, taskID string) (*model.Run, error) {
+	return h.startRun(ctx, taskID, nil)
+}
+
+// startRun 为任务创建 Run，f 非 nil 时为追问 Run（见 session.go）
+func (h *Handler) startRun(ctx context.Context, taskID string, f *followUp) (*model.Run, error) {
 	runID := generateID("run")
 
-	log.Printf("[run.create.start] run_id=%s task_id=%s", runID, taskID)
+	slog.InfoContext(ctx, "run.create.start", "run_id", runID, "task_id", taskID)
 
 	// 获取任务
 	task, err := h.store.GetTask(ctx, taskID)
 	if err != nil {
-		log.Printf("[run.create.task.failed] run_id=%s task_id=%s error=%v", runID, taskID, err)
-		writeError(w, http.StatusInternalServerError, "failed to get task")
-		return
+		slog.ErrorContext(ctx, "run.create.task.failed", "run_id", runID, "task_id", taskID, "error", err)
+		return nil, &startError{http.StatusInternalServerError, "failed to get task", err}
 	}
 	if task == nil {
-		log.Printf("[run.create.task.not_found] run_id=%s task_id=%s", runID, taskID)
-		writeError(w, http.StatusNotFound, "task not found")
-		return
+		slog.WarnContext(ctx, "run.create.task.not_found", "run_id", runID, "task_id", taskID)
+		return nil, &startError{http.StatusNotFound, "task not found", ErrTaskNotFound}
 	}
 
-	// 构建执行快照（包含 NodeManager 所需的扁平化字段）
+	// 构建执行快照（NodeManager 按 model.RunSnapshot 解析）
 	// agent.type = task.Type（Agent 类型，如 qwen-code）
 	// agent.instance_id = task.AgentID（实例 ID，前端选择的运行中实例）
 	// prompt = task.Prompt.Content（提示词纯文本）
-	agentSnapshot := map[string]interface{}{
-		"type": string(task.Type),
+	execSnapshot := &model.RunSnapshot{
+		TaskID:    task.ID,
+		Name:      task.Name,
+		Agent:     model.SnapshotAgent{Type: string(task.Type)},
+		Prompt:    task.GetPromptContent(),
+		Workspace: task.Workspace,
+		Labels:    task.Labels,
 	}
 	if task.AgentID != nil {
-		agentSnapshot["instance_id"] = *task.AgentID
+		execSnapshot.Agent.InstanceID = *task.AgentID
+	}
+
+	// 项目任务未绑定实例时，从项目账号池分配账号（NodeManager 按 account_id 查找容器），
+	// 并记录候选账号，执行时按账号负载重新租用；追问沿用上一轮会话的账号
+	var accountID string
+	var accountPool []string
+	if f != nil {
+		accountID = f.account
+	}
+	if accountID == "" {
+		accountID, accountPool, err = h.assignPoolAccount(ctx, task)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "run.create.account.failed", "run_id", runID, "task_id", taskID, "error", err)
+		if errors.Is(err, errNoPoolAccount) {
+			return nil, &startError{http.StatusConflict, err.Error(), err}
+		}
+		return nil, &startError{http.StatusInternalServerError, "failed to assign account", err}
+	}
+	execSnapshot.Agent.AccountID = accountID
+	execSnapshot.Agent.AccountPool = accountPool
+
+	if backend := task.Labels[model.LabelExecBackend]; backend != "" {
+		// 任务指定执行后端（调度已按同名标签约束到支持该后端的节点）
+		execSnapshot.Runtime = &model.SnapshotRuntime{Backend: backend}
+	}
+	if len(task.Secrets) > 0 {
+		// 仅记录密钥名称，明文由 NodeManager 执行时解析
+		execSnapshot.Secrets = task.Secrets
+	}
+	if task.Security != nil {
+		// 资源限制由 NodeManager 换算为容器运行时参数，超出节点容量时拒绝执行
+		execSnapshot.Limits = task.Security.Limits
+		// Agent 调用这些工具前 NodeManager 暂停执行，等待人工审批
+		execSnapshot.RequireApproval = task.Security.RequireApproval
+		// NodeManager 按规则与内置危险命令检测拦截 Agent 执行的命令（见 nodemanager/command_filter.go）
+		filter := &model.CommandFilter{Action: task.Security.CommandAction()}
+		if c := task.Security.Commands; c != nil {
+			filter.Allow, filter.Deny = c.Allow, c.Deny
+		}
+		execSnapshot.CommandFilter = filter
+	}
+	policy, err := h.resolveSecurityPolicy(ctx, task)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.create.security.failed", "run_id", runID, "task_id", taskID, "error", err)
+		return nil, err
+	}
+	if policy.Restricted() {
+		// NodeManager 由 Adapter 换算为 CLI 参数，Agent 调用被禁止的工具时上报 policy_denied（见 security.go）
+		execSnapshot.SecurityPolicy, _ = json.Marshal(policy)
+	}
+	if task.TimeoutSeconds > 0 {
+		// 超时巡检按快照中的执行时限判定，无需再读取任务
+		execSnapshot.TimeoutSeconds = task.TimeoutSeconds
 	}
 
-	execSnapshot := map[string]interface{}{
-		"task_id": task.ID,
-		"name":    task.Name,
-		"agent":   agentSnapshot,
-		"prompt":  task.GetPromptContent(),
+	// 生命周期钩子（Skill 引用在此展开为内联脚本）
+	hooks, err := h.resolveHooks(ctx, task)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.create.hooks.failed", "run_id", runID, "task_id", taskID, "error", err)
+		if errors.Is(err, errHookSkillNotFound) {
+			return nil, &startError{http.StatusBadRequest, err.Error(), err}
+		}
+		return nil, &startError{http.StatusInternalServerError, "failed to resolve hooks", err}
 	}
-	if task.Workspace != nil {
-		execSnapshot["workspace"] = task.Workspace
+	execSnapshot.Hooks = hooks
+	skills, err := h.resolveSkills(ctx, task)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.create.skills.failed", "run_id", runID, "task_id", taskID, "error", err)
+		if errors.Is(err, errSkillBundleNotFound) {
+			return nil, &startError{http.StatusBadRequest, err.Error(), err}
+		}
+		return nil, &startError{http.StatusInternalServerError, "failed to resolve skills", err}
 	}
-	if task.Labels != nil {
-		execSnapshot["labels"] = task.Labels
+	// Agent 模板引用的技能包由 NodeManager 在 Agent 启动前安装（见 skills.go）
+	execSnapshot.Skills = skills
+	// 继承的上下文由 NodeManager 写入工作空间的 .agent/context/（见 context.go）
+	execSnapshot.InheritedContext = h.inheritedContext(ctx, task)
+	if task.Session.IsEnabled() {
+		// 持久会话：NodeManager 在 Run 结束后保留执行容器，追问 Run 在其中继续
+		execSnapshot.Session = sessionSnapshot(task, f)
+		if f != nil {
+			execSnapshot.Prompt = f.prompt
+			if f.previous.NodeID != nil {
+				// 调度到上一轮会话的节点（direct 策略），节点不可用时由后续策略选择
+				execSnapshot.NodeID = *f.previous.NodeID
+			}
+		}
 	}
 	taskSnapshot, _ := json.Marshal(execSnapshot)
 
+	// 按扁平化结构校验快照：缺少 agent.type、prompt 等字段时节点无法执行，不创建 Run
+	if _, err := model.NormalizeSnapshot(taskSnapshot); err != nil {
+		slog.WarnContext(ctx, "run.create.snapshot.invalid", "run_id", runID, "task_id", taskID, "error", err)
+		return nil, &startError{http.StatusUnprocessableEntity, "invalid run snapshot", err}
+	}
+
 	now := time.Now()
 	run := &model.Run{
 		ID:        runID,
 		TaskID:    taskID,
 		Status:    model.RunStatusQueued,
 		Snapshot:  taskSnapshot,
+		Priority:  task.Priority.OrDefault(),
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
 
-	// Step 1: 写入 PostgreSQL（必须成功）
-	if err := h.store.CreateRun(ctx, run); err != nil {
-		log.Printf("[run.create.pg.failed] run_id=%s task_id=%s error=%v", runID, taskID, err)
-		writeError(w, http.StatusInternalServerError, "failed to create run")
-		return
+	// Step 1: 写入数据库（必须成功）；启用发件箱时调度消息在同一事务中写入
+	var msg *model.OutboxMessage
+	if h.outbox != nil && h.scheduler != nil {
+		msg, err = outbox.NewMessage(model.OutboxTopicScheduleRun, model.ScheduleRunPayload{
+			RunID: run.ID, TaskID: run.TaskID, Priority: string(run.Priority),
+		})
+		if err == nil {
+			err = h.outbox.CreateRunWithOutbox(ctx, run, msg)
+		}
+	} else {
+		err = h.store.CreateRun(ctx, run)
 	}
-	log.Printf("[run.create.pg.success] run_id=%s task_id=%s", runID, taskID)
-
-	// Step 2: 加入调度队列（允许失败，有保底轮询）
-	if h.scheduler != nil {
-		msgID, err := h.scheduler.ScheduleRun(ctx, runID, taskID)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.create.pg.failed", "run_id", runID, "task_id", taskID, "error", err)
+		return nil, &startError{http.StatusInternalServerError, "failed to create run", err}
+	}
+	slog.InfoContext(ctx, "run.create.pg.success", "run_id", runID, "task_id", taskID)
+
+	// Step 2: 加入调度队列
+	if msg != nil {
+		// 由发件箱中继入队，失败时中继重试
+		h.relay.Notify()
+	} else if h.scheduler != nil {
+		// 允许失败，有保底轮询
+		msgID, err := h.scheduleRun(ctx, run)
 		if err != nil {
 			// 队列写入失败不是致命错误，保底轮询会处理
-			log.Printf("[run.create.queue.failed] run_id=%s task_id=%s error=%v", runID, taskID, err)
+			slog.ErrorContext(ctx, "run.create.queue.failed", "run_id", runID, "task_id", taskID, "error", err)
 		} else {
-			log.Printf("[run.create.queue.success] run_id=%s task_id=%s msg_id=%s", runID, taskID, msgID)
+			slog.InfoContext(ctx, "run.create.queue.success", "run_id", runID, "task_id", taskID, "msg_id", msgID)
 		}
 	}
 
@@ -150,12 +426,31 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	// Task 状态应该在 NodeManager 真正开始执行并上报事件后才变更
 	// 参见 events.go PostEvents()
 
-	log.Printf("[run.create.complete] run_id=%s task_id=%s", runID, taskID)
-	writeJSON(w, http.StatusCreated, run)
+	slog.InfoContext(ctx, "run.create.complete", "run_id", runID, "task_id", taskID)
+	return run, nil
+}
+
+// scheduleRun 将 Run 加入调度队列，队列支持优先级时按 Run 优先级入队
+func (h *Handler) scheduleRun(ctx context.Context, run *model.Run) (string, error) {
+	if pq, ok := h.scheduler.(queue.PrioritySchedulerQueue); ok {
+		return pq.ScheduleRunWithPriority(ctx, run.ID, run.TaskID, string(run.Priority))
+	}
+	return h.scheduler.ScheduleRun(ctx, run.ID, run.TaskID)
+}
+
+// runSchedulingDecisionLimit Run 详情中返回的调度决策条数
+const runSchedulingDecisionLimit = 10
+
+// runDetail Run 详情（附带最近的调度决策）
+type runDetail struct {
+	*model.Run
+	SchedulingDecisions []*model.SchedulingDecision `json:"scheduling_decisions,omitempty"`
 }
 
 // Get 获取单个 Run 详情
 // GET /api/v1/runs/{id}
+//
+// 响应附带最近的调度决策（scheduling_decisions，按时间倒序），用于排查 Run 长时间排队的原因
 func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
 	run, err := h.store.GetRun(r.Context(), id)
@@ -167,7 +462,16 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "run not found")
 		return
 	}
-	writeJSON(w, http.StatusOK, run)
+
+	detail := runDetail{Run: run}
+	if h.decisions != nil {
+		decisions, err := h.decisions.ListSchedulingDecisions(r.Context(), id, runSchedulingDecisionLimit)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "run.get.decisions.failed", "run_id", id, "error", err)
+		}
+		detail.SchedulingDecisions = decisions
+	}
+	writeJSON(w, http.StatusOK, detail)
 }
 
 // ListByTask 列出任务的所有执行记录
@@ -182,7 +486,91 @@ func (h *Handler) ListByTask(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{"runs": runs, "count": len(runs)})
 }
 
-// Cancel 取消正在执行或排队中的 Run
+// List 列出 Run（按创建时间倒序）
+// GET /api/v1/runs
+//
+// 支持的查询参数：
+//   - status:        按状态筛选（逗号分隔表示任一状态）
+//   - task_id:       所属任务
+//   - node_id:       执行节点
+//   - labels:        所属任务的标签选择器（k=v,k!=v,k,!k）
+//   - since:         创建时间下限 (ISO8601)
+//   - until:         创建时间上限 (ISO8601)
+//   - limit:         每页条数 (默认 20, 最大 100)
+//   - offset:        偏移量（使用 cursor 时忽略）
+//   - cursor:        上一页返回的 next_cursor
+//   - include_total: 是否返回 total（偏移分页默认 true，游标分页默认 false）
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	filter := storage.RunFilter{
+		TaskID: q.Get("task_id"),
+		NodeID: q.Get("node_id"),
+		Limit:  limit + 1, // 多取一条判断是否还有下一页
+		Offset: offset,
+	}
+	for _, s := range strings.Split(q.Get("status"), ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			filter.Statuses = append(filter.Statuses, s)
+		}
+	}
+	if s := q.Get("since"); s != "" {
+		if t, err := time.Parse(time.RFC3339, s); err == nil {
+			filter.Since = t
+		}
+	}
+	if u := q.Get("until"); u != "" {
+		if t, err := time.Parse(time.RFC3339, u); err == nil {
+			filter.Until = t
+		}
+	}
+	var err error
+	if filter.Labels, err = storage.ParseLabelSelector(q.Get("labels")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if c := q.Get("cursor"); c != "" {
+		if filter.Cursor, err = storage.ParsePageCursor(c); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	includeTotal := filter.Cursor == nil
+	if v := q.Get("include_total"); v != "" {
+		includeTotal, _ = strconv.ParseBool(v)
+	}
+	filter.SkipTotal = !includeTotal
+
+	runs, total, err := h.store.ListRuns(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list runs")
+		return
+	}
+	hasMore := len(runs) > limit
+	if hasMore {
+		runs = runs[:limit]
+	}
+	resp := map[string]interface{}{
+		"runs":     runs,
+		"count":    len(runs),
+		"has_more": hasMore,
+	}
+	if hasMore {
+		last := runs[limit-1]
+		resp["next_cursor"] = storage.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if includeTotal {
+		resp["total"] = total
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// Cancel 取消正在执行、暂停或排队中的 Run
 // POST /api/v1/runs/{id}/cancel
 func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
@@ -191,7 +579,7 @@ func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "run not found")
 		return
 	}
-	if run.Status != model.RunStatusQueued && run.Status != model.RunStatusRunning {
+	if run.Status != model.RunStatusQueued && run.Status != model.RunStatusRunning && run.Status != model.RunStatusPaused {
 		writeError(w, http.StatusBadRequest, "run cannot be cancelled")
 		return
 	}
@@ -201,6 +589,121 @@ func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]string{"status": "cancelled"})
 }
 
+// Pause 暂停正在执行的 Run
+// POST /api/v1/runs/{id}/pause
+//
+// Run 标记为 paused 后，节点在下一次心跳的 pause_runs 指令中冻结执行目标；
+// 暂停中的 Run 不占用节点执行槽位，也不参与超时巡检。
+func (h *Handler) Pause(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "pause", model.RunStatusRunning, model.RunStatusPaused, "can only pause running runs")
+}
+
+// Resume 恢复被暂停的 Run
+// POST /api/v1/runs/{id}/resume
+//
+// Run 恢复为 running 后，节点在下一次心跳的 resume_runs 指令中恢复执行。
+func (h *Handler) Resume(w http.ResponseWriter, r *http.Request) {
+	h.transition(w, r, "resume", model.RunStatusPaused, model.RunStatusRunning, "can only resume paused runs")
+}
+
+// transition 将处于 from 状态的 Run 更新为 to 状态，否则返回 409
+func (h *Handler) transition(w http.ResponseWriter, r *http.Request, action string, from, to model.RunStatus, conflict string) {
+	id := r.PathValue("id")
+	run, err := h.store.GetRun(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	if run.Status != from {
+		writeError(w, http.StatusConflict, conflict)
+		return
+	}
+	if err := h.store.UpdateRunStatus(r.Context(), id, to, nil); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update run")
+		return
+	}
+	slog.InfoContext(r.Context(), "run."+action, "run_id", id)
+	writeJSON(w, http.StatusOK, map[string]string{"status": string(to)})
+}
+
+// CancelRun 取消仍在排队或执行中的 Run（已到达终态时不做处理），如 Agent 网关的客户端断开连接
+//
+// 节点在下一次心跳的 cancel_runs 指令中得知并终止执行。
+func (h *Handler) CancelRun(ctx context.Context, runID string) error {
+	run, err := h.store.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run == nil || run.IsTerminal() {
+		return nil
+	}
+	if err := h.store.UpdateRunStatus(ctx, runID, model.RunStatusCancelled, nil); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "run.cancel", "run_id", runID)
+	h.maybeUpdateTaskStatus(ctx, runID, model.RunStatusCancelled)
+	return nil
+}
+
+// CancelTask 取消任务仍在排队、执行或暂停中的全部 Run，返回被取消的 Run ID
+//
+// 任务不存在时返回的错误满足 errors.Is(err, ErrTaskNotFound)；没有活跃 Run 的待处理任务直接标记为 cancelled。
+func (h *Handler) CancelTask(ctx context.Context, taskID string) ([]string, error) {
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if task == nil {
+		return nil, ErrTaskNotFound
+	}
+	runs, err := h.store.ListRunsByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var cancelled []string
+	for _, run := range runs {
+		if run.IsTerminal() {
+			continue
+		}
+		if err := h.CancelRun(ctx, run.ID); err != nil {
+			return cancelled, err
+		}
+		cancelled = append(cancelled, run.ID)
+	}
+	if len(cancelled) == 0 && task.Status == model.TaskStatusPending {
+		if err := h.store.UpdateTaskStatus(ctx, taskID, model.TaskStatusCancelled); err != nil {
+			return nil, err
+		}
+	}
+	return cancelled, nil
+}
+
+// Abort 以 failed 终止仍在执行的 Run 并记录原因（如内容审核命中严重违规）
+//
+// 节点在下一次心跳的 cancel_runs 指令中得知并终止执行；已到达终态的 Run 不受影响。
+func (h *Handler) Abort(ctx context.Context, runID, reason string) error {
+	run, err := h.store.GetRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run == nil || run.IsTerminal() {
+		return nil
+	}
+	if err := h.store.UpdateRunStatus(ctx, runID, model.RunStatusFailed, nil); err != nil {
+		return err
+	}
+	if err := h.store.UpdateRunError(ctx, runID, reason); err != nil {
+		slog.ErrorContext(ctx, "run.abort.error_failed", "run_id", runID, "error", err)
+	}
+	slog.InfoContext(ctx, "run.abort", "run_id", runID, "reason", reason)
+	h.maybeUpdateTaskStatus(ctx, runID, model.RunStatusFailed)
+	return nil
+}
+
 // Update 更新 Run 状态
 // PATCH /api/v1/runs/{id}
 func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
@@ -217,17 +720,44 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	statusStr := string(*req.Status)
-	status := model.RunStatus(statusStr)
-	if err := h.store.UpdateRunStatus(ctx, id, status, nil); err != nil {
+	status, err := h.UpdateStatus(ctx, id, model.RunStatus(*req.Status), req.NodeId)
+	if errors.Is(err, ErrRunReassigned) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to update run")
 		return
 	}
+	writeJSON(w, http.StatusOK, map[string]string{"status": string(status)})
+}
+
+// ErrRunReassigned 上报状态的节点已不是 Run 当前分配的节点
+var ErrRunReassigned = errors.New("run is no longer assigned to this node")
+
+// UpdateStatus 更新 Run 状态并在到达终态时联动 Task（REST 与 gRPC 状态上报共用）
+//
+// nodeID 非 nil 时校验 Run 仍分配给该节点，否则返回 ErrRunReassigned。
+// 已到达终态的 Run 不更新，返回其当前状态。
+func (h *Handler) UpdateStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) (model.RunStatus, error) {
+	if current, err := h.store.GetRun(ctx, id); err == nil && current != nil {
+		// 已到达终态的 Run（如被超时巡检判定为 timeout、被内容审核终止）不再被节点随后上报的状态覆盖
+		if current.IsTerminal() {
+			return current.Status, nil
+		}
+		// 已被孤儿回收重新排队的 Run 不再接受原节点的上报
+		if nodeID != nil && *nodeID != derefString(current.NodeID) {
+			return "", ErrRunReassigned
+		}
+	}
+
+	if err := h.store.UpdateRunStatus(ctx, id, status, nil); err != nil {
+		return "", err
+	}
 
 	// Run 到达终态时，联动更新 Task 状态
 	h.maybeUpdateTaskStatus(ctx, id, status)
-
-	writeJSON(w, http.StatusOK, map[string]string{"status": statusStr})
+	return status, nil
 }
 
 // maybeUpdateTaskStatus 当 Run 到达终态时联动更新 Task 状态
@@ -236,6 +766,9 @@ func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
 //   - Run done → Task completed
 //   - Run failed → Task failed
 //   - Run cancelled → Task cancelled
+//   - Run timeout → Task failed
+//
+// 启用持久会话的 Run 将 Agent 的回复追加到对话记录，随后触发 onFinish 回调（如已设置）。
 func (h *Handler) maybeUpdateTaskStatus(ctx context.Context, runID string, runStatus model.RunStatus) {
 	var taskStatus model.TaskStatus
 	switch runStatus {
@@ -245,18 +778,24 @@ func (h *Handler) maybeUpdateTaskStatus(ctx context.Context, runID string, runSt
 		taskStatus = model.TaskStatusFailed
 	case model.RunStatusCancelled:
 		taskStatus = model.TaskStatusCancelled
+	case model.RunStatusTimeout:
+		taskStatus = model.TaskStatusFailed
 	default:
 		return
 	}
 
 	run, err := h.store.GetRun(ctx, runID)
 	if err != nil || run == nil {
-		log.Printf("[run.update.task_status] run_id=%s get_run_error=%v", runID, err)
+		slog.WarnContext(ctx, "run.update.task_status", "run_id", runID, "get_run_error", err)
 		return
 	}
 
 	if err := h.store.UpdateTaskStatus(ctx, run.TaskID, taskStatus); err != nil {
-		log.Printf("[run.update.task_status] run_id=%s task_id=%s error=%v", runID, run.TaskID, err)
+		slog.ErrorContext(ctx, "run.update.task_status", "run_id", runID, "task_id", run.TaskID, "error", err)
+	}
+	h.recordSessionReply(ctx, run)
+	for _, fn := range h.onFinish {
+		fn(run)
 	}
 }
 
@@ -10,10 +10,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"sort"
 	"strings"
 	"testing"
+	"time"
 
+	"agents-admin/internal/apiserver/outbox"
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
 )
 
 // ============================================================================
@@ -22,8 +27,9 @@ import (
 
 // mockRunStore 模拟存储（仅实现 RunStore 接口）
 type mockRunStore struct {
-	tasks map[string]*model.Task
-	runs  map[string]*model.Run
+	tasks     map[string]*model.Task
+	runs      map[string]*model.Run
+	artifacts []*model.Artifact
 
 	// 控制行为
 	createRunErr error
@@ -70,6 +76,33 @@ func (m *mockRunStore) ListRunsByTask(ctx context.Context, taskID string) ([]*mo
 	return result, nil
 }
 
+func (m *mockRunStore) ListRuns(ctx context.Context, filter storage.RunFilter) ([]*model.Run, int, error) {
+	var result []*model.Run
+	for _, r := range m.runs {
+		if filter.TaskID != "" && r.TaskID != filter.TaskID {
+			continue
+		}
+		if c := filter.Cursor; c != nil && !(r.CreatedAt.Before(c.CreatedAt) || r.CreatedAt.Equal(c.CreatedAt) && r.ID < c.ID) {
+			continue
+		}
+		result = append(result, r)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if !result[i].CreatedAt.Equal(result[j].CreatedAt) {
+			return result[i].CreatedAt.After(result[j].CreatedAt)
+		}
+		return result[i].ID > result[j].ID
+	})
+	total := -1
+	if !filter.SkipTotal {
+		total = len(result)
+	}
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
+	return result, total, nil
+}
+
 func (m *mockRunStore) UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error {
 	if r, ok := m.runs[id]; ok {
 		r.Status = status
@@ -80,6 +113,13 @@ func (m *mockRunStore) UpdateRunStatus(ctx context.Context, id string, status mo
 	return nil
 }
 
+func (m *mockRunStore) UpdateRunError(ctx context.Context, id string, errMsg string) error {
+	if r, ok := m.runs[id]; ok {
+		r.Error = &errMsg
+	}
+	return nil
+}
+
 func (m *mockRunStore) UpdateTaskStatus(ctx context.Context, id string, status model.TaskStatus) error {
 	if t, ok := m.tasks[id]; ok {
 		t.Status = status
@@ -87,6 +127,21 @@ func (m *mockRunStore) UpdateTaskStatus(ctx context.Context, id string, status m
 	return nil
 }
 
+func (m *mockRunStore) CreateArtifact(ctx context.Context, artifact *model.Artifact) error {
+	m.artifacts = append(m.artifacts, artifact)
+	return nil
+}
+
+func (m *mockRunStore) ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error) {
+	var result []*model.Artifact
+	for _, a := range m.artifacts {
+		if a.RunID == runID {
+			result = append(result, a)
+		}
+	}
+	return result, nil
+}
+
 // mockRunScheduler 模拟调度队列（仅实现 RunScheduler 接口）
 type mockRunScheduler struct {
 	scheduledRuns []string
@@ -244,7 +299,7 @@ func TestCreate_PostgresFailed(t *testing.T) {
 	queue := &mockRunScheduler{}
 
 	// 创建测试任务
-	task := &model.Task{ID: "task-test-002", Name: "test", Status: model.TaskStatusPending}
+	task := &model.Task{ID: "task-test-002", Name: "test", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, Status: model.TaskStatusPending}
 	store.tasks[task.ID] = task
 
 	handler := NewHandlerWithInterfaces(store, queue)
@@ -277,7 +332,7 @@ func TestCreate_RedisFailed(t *testing.T) {
 	queue := &mockRunScheduler{scheduleErr: errors.New("queue error")}
 
 	// 创建测试任务
-	task := &model.Task{ID: "task-test-003", Name: "test", Status: model.TaskStatusPending}
+	task := &model.Task{ID: "task-test-003", Name: "test", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, Status: model.TaskStatusPending}
 	store.tasks[task.ID] = task
 
 	handler := NewHandlerWithInterfaces(store, queue)
@@ -309,7 +364,7 @@ func TestCreate_NilRedis(t *testing.T) {
 	store := newMockStore()
 
 	// 创建测试任务
-	task := &model.Task{ID: "task-test-004", Name: "test", Status: model.TaskStatusPending}
+	task := &model.Task{ID: "task-test-004", Name: "test", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, Status: model.TaskStatusPending}
 	store.tasks[task.ID] = task
 
 	// Redis 为 nil
@@ -334,6 +389,188 @@ func TestCreate_NilRedis(t *testing.T) {
 	}
 }
 
+// TestCreate_InvalidSnapshot 任务展开的快照不合法时返回 422 与字段级错误，不创建 Run
+func TestCreate_InvalidSnapshot(t *testing.T) {
+	store := newMockStore()
+	store.tasks["task-invalid"] = &model.Task{ID: "task-invalid", Name: "test", Status: model.TaskStatusPending,
+		Workspace: &model.WorkspaceConfig{Type: model.WorkspaceTypeGit}}
+
+	mux := http.NewServeMux()
+	NewHandlerWithInterfaces(store, nil).RegisterRoutes(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/task-invalid/runs", nil))
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Fatalf("HTTP 状态码 = %d, 期望 422", w.Code)
+	}
+	var resp struct {
+		Error  string                     `json:"error"`
+		Fields []model.SnapshotFieldError `json:"fields"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	want := []model.SnapshotFieldError{
+		{Field: "prompt", Message: "must not be empty"},
+		{Field: "agent.type", Message: "must not be empty"},
+		{Field: "workspace.git.url", Message: "is required for git workspace"},
+	}
+	if resp.Error != "invalid run snapshot" || !reflect.DeepEqual(resp.Fields, want) {
+		t.Errorf("响应 = %s", w.Body.String())
+	}
+	if len(store.runs) != 0 {
+		t.Errorf("存储的 Run 数量 = %d, 期望 0", len(store.runs))
+	}
+}
+
+// mockPriorityScheduler 模拟支持优先级的调度队列
+type mockPriorityScheduler struct {
+	mockRunScheduler
+	priorities map[string]string
+}
+
+func (m *mockPriorityScheduler) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
+	m.priorities[runID] = priority
+	return "high:mock-msg-id", nil
+}
+
+// ============================================================================
+// TC-RUN-CREATE-008: Run 继承任务优先级并按优先级入队
+// ============================================================================
+
+func TestCreate_Priority(t *testing.T) {
+	store := newMockStore()
+	store.tasks["task-high"] = &model.Task{ID: "task-high", Name: "urgent", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, Status: model.TaskStatusPending, Priority: model.PriorityHigh}
+	store.tasks["task-default"] = &model.Task{ID: "task-default", Name: "normal", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, Status: model.TaskStatusPending}
+
+	sched := &mockPriorityScheduler{priorities: make(map[string]string)}
+	mux := http.NewServeMux()
+	NewHandlerWithInterfaces(store, sched).RegisterRoutes(mux)
+
+	want := map[string]model.Priority{"task-high": model.PriorityHigh, "task-default": model.PriorityNormal}
+	for taskID, priority := range want {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/"+taskID+"/runs", nil))
+		if w.Code != http.StatusCreated {
+			t.Fatalf("HTTP 状态码 = %d, 期望 201", w.Code)
+		}
+		var run model.Run
+		json.Unmarshal(w.Body.Bytes(), &run)
+		if run.Priority != priority {
+			t.Errorf("%s: run.priority = %q, 期望 %q", taskID, run.Priority, priority)
+		}
+		if sched.priorities[run.ID] != string(priority) {
+			t.Errorf("%s: 入队优先级 = %q, 期望 %q", taskID, sched.priorities[run.ID], priority)
+		}
+	}
+	if len(sched.scheduledRuns) != 0 {
+		t.Errorf("支持优先级的队列不应走 ScheduleRun, got %v", sched.scheduledRuns)
+	}
+}
+
+// mockOutboxStore 支持事务发件箱的存储（同时作为中继的消息来源）
+type mockOutboxStore struct {
+	*mockRunStore
+	pending   []*model.OutboxMessage
+	published map[string]bool
+}
+
+func (m *mockOutboxStore) CreateRunWithOutbox(ctx context.Context, run *model.Run, msgs ...*model.OutboxMessage) error {
+	if err := m.CreateRun(ctx, run); err != nil {
+		return err
+	}
+	m.pending = append(m.pending, msgs...)
+	return nil
+}
+
+func (m *mockOutboxStore) ClaimOutboxMessages(_ context.Context, _ int, _ time.Duration) ([]*model.OutboxMessage, error) {
+	claimed := m.pending
+	m.pending = nil
+	return claimed, nil
+}
+
+func (m *mockOutboxStore) MarkOutboxPublished(_ context.Context, id string) error {
+	m.published[id] = true
+	return nil
+}
+
+func (m *mockOutboxStore) MarkOutboxFailed(_ context.Context, _, _ string, _ time.Time) error {
+	return nil
+}
+
+func (m *mockOutboxStore) DeletePublishedOutbox(_ context.Context, _ time.Time) (int64, error) {
+	return 0, nil
+}
+
+// ============================================================================
+// TC-RUN-CREATE-009: 启用发件箱时 Run 与调度消息同事务写入，由中继入队
+// ============================================================================
+
+func TestCreate_Outbox(t *testing.T) {
+	store := &mockOutboxStore{mockRunStore: newMockStore(), published: map[string]bool{}}
+	store.tasks["task-high"] = &model.Task{ID: "task-high", Name: "urgent", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, Status: model.TaskStatusPending, Priority: model.PriorityHigh}
+
+	sched := &mockPriorityScheduler{priorities: make(map[string]string)}
+	h := NewHandlerWithInterfaces(store, sched)
+	relay := outbox.NewRelay(store, outbox.Config{})
+	relay.Handle(model.OutboxTopicScheduleRun, h.PublishScheduleRun)
+	h.SetOutbox(relay)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/task-high/runs", nil))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("HTTP 状态码 = %d, 期望 201", w.Code)
+	}
+	var run model.Run
+	json.Unmarshal(w.Body.Bytes(), &run)
+	if len(sched.priorities) != 0 {
+		t.Errorf("创建请求不应直接入队, got %v", sched.priorities)
+	}
+	if len(store.pending) != 1 || store.pending[0].Topic != model.OutboxTopicScheduleRun {
+		t.Fatalf("发件箱消息 = %+v, 期望 1 条 %s", store.pending, model.OutboxTopicScheduleRun)
+	}
+	msgID := store.pending[0].ID
+
+	if n := relay.RunOnce(context.Background(), time.Now()); n != 1 {
+		t.Errorf("中继投递条数 = %d, 期望 1", n)
+	}
+	if sched.priorities[run.ID] != string(model.PriorityHigh) {
+		t.Errorf("入队优先级 = %q, 期望 %q", sched.priorities[run.ID], model.PriorityHigh)
+	}
+	if !store.published[msgID] {
+		t.Error("投递成功后消息应标记为已发布")
+	}
+
+	// 写库失败时不产生发件箱消息
+	store.createRunErr = errors.New("db down")
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/task-high/runs", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("HTTP 状态码 = %d, 期望 500", w.Code)
+	}
+	if len(store.pending) != 0 {
+		t.Errorf("写库失败时不应有发件箱消息, got %d", len(store.pending))
+	}
+}
+
+func TestStartRun_SnapshotLimits(t *testing.T) {
+	store := newMockStore()
+	store.tasks["task-limits"] = &model.Task{ID: "task-limits", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"},
+		Security: &model.SecurityConfig{Limits: &model.ResourceLimits{MaxCPU: "2", MaxMemory: "4Gi"}}}
+
+	run, err := NewHandlerWithInterfaces(store, nil).StartRun(context.Background(), "task-limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var snapshot struct {
+		Limits *model.ResourceLimits `json:"limits"`
+	}
+	json.Unmarshal(run.Snapshot, &snapshot)
+	if snapshot.Limits == nil || snapshot.Limits.MaxCPU != "2" || snapshot.Limits.MaxMemory != "4Gi" {
+		t.Errorf("snapshot.limits = %+v, 期望与任务 security.limits 一致", snapshot.Limits)
+	}
+}
+
 // ============================================================================
 // TestGet: 获取 Run
 // ============================================================================
@@ -368,6 +605,70 @@ func TestGet_Basic(t *testing.T) {
 	}
 }
 
+// mockDecisionStore 附带调度决策的存储
+type mockDecisionStore struct {
+	*mockRunStore
+	decisions []*model.SchedulingDecision
+	limit     int
+}
+
+func (m *mockDecisionStore) ListSchedulingDecisions(_ context.Context, runID string, limit int) ([]*model.SchedulingDecision, error) {
+	m.limit = limit
+	var out []*model.SchedulingDecision
+	for _, d := range m.decisions {
+		if d.RunID == runID {
+			out = append(out, d)
+		}
+	}
+	return out, nil
+}
+
+func TestGet_SchedulingDecisions(t *testing.T) {
+	store := &mockDecisionStore{mockRunStore: newMockStore()}
+	store.runs["run-q"] = &model.Run{ID: "run-q", TaskID: "task-001", Status: model.RunStatusQueued}
+	store.decisions = []*model.SchedulingDecision{{
+		RunID:    "run-q",
+		Outcome:  model.SchedulingOutcomeNoMatch,
+		Reason:   "no_strategy_matched",
+		Attempts: 4,
+		Candidates: []model.SchedulingCandidate{
+			{NodeID: "node-1", Running: 2, MaxConcurrent: 2, Rejected: model.SchedulingRejectCapacity},
+			{NodeID: "node-2", MaxConcurrent: 2, Rejected: model.SchedulingRejectLabel},
+		},
+	}}
+
+	mux := http.NewServeMux()
+	NewHandlerWithInterfaces(store, nil).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/run-q", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("HTTP 状态码 = %d, 期望 200", w.Code)
+	}
+
+	var result struct {
+		ID                  string                      `json:"id"`
+		Status              model.RunStatus             `json:"status"`
+		SchedulingDecisions []*model.SchedulingDecision `json:"scheduling_decisions"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if result.ID != "run-q" || result.Status != model.RunStatusQueued {
+		t.Errorf("响应 id/status = %s/%s, 期望 run-q/queued", result.ID, result.Status)
+	}
+	if len(result.SchedulingDecisions) != 1 {
+		t.Fatalf("scheduling_decisions = %+v, 期望 1 条", result.SchedulingDecisions)
+	}
+	d := result.SchedulingDecisions[0]
+	if d.Outcome != model.SchedulingOutcomeNoMatch || d.Attempts != 4 || len(d.Candidates) != 2 || d.Candidates[1].Rejected != model.SchedulingRejectLabel {
+		t.Errorf("decision = %+v", d)
+	}
+	if store.limit != runSchedulingDecisionLimit {
+		t.Errorf("limit = %d, 期望 %d", store.limit, runSchedulingDecisionLimit)
+	}
+}
+
 func TestGet_NotFound(t *testing.T) {
 	store := newMockStore()
 	handler := NewHandlerWithInterfaces(store, nil)
@@ -477,6 +778,46 @@ func TestCancel_AlreadyDone(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// TestPauseResume: 暂停与恢复 Run
+// ============================================================================
+
+func TestPauseResume(t *testing.T) {
+	store := newMockStore()
+	store.runs["run-p1"] = &model.Run{ID: "run-p1", TaskID: "task-001", Status: model.RunStatusRunning}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+	post := func(path string) int {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("POST", path, nil))
+		return w.Code
+	}
+
+	if code := post("/api/v1/runs/run-p1/resume"); code != http.StatusConflict {
+		t.Errorf("恢复运行中的 Run: HTTP 状态码 = %d, 期望 409", code)
+	}
+	if code := post("/api/v1/runs/run-p1/pause"); code != http.StatusOK || store.runs["run-p1"].Status != model.RunStatusPaused {
+		t.Fatalf("暂停: HTTP 状态码 = %d, 状态 = %s", code, store.runs["run-p1"].Status)
+	}
+	if code := post("/api/v1/runs/run-p1/pause"); code != http.StatusConflict {
+		t.Errorf("重复暂停: HTTP 状态码 = %d, 期望 409", code)
+	}
+	if code := post("/api/v1/runs/run-p1/resume"); code != http.StatusOK || store.runs["run-p1"].Status != model.RunStatusRunning {
+		t.Fatalf("恢复: HTTP 状态码 = %d, 状态 = %s", code, store.runs["run-p1"].Status)
+	}
+	if code := post("/api/v1/runs/run-missing/pause"); code != http.StatusNotFound {
+		t.Errorf("不存在的 Run: HTTP 状态码 = %d, 期望 404", code)
+	}
+
+	// 暂停中的 Run 可以取消
+	post("/api/v1/runs/run-p1/pause")
+	if code := post("/api/v1/runs/run-p1/cancel"); code != http.StatusOK || store.runs["run-p1"].Status != model.RunStatusCancelled {
+		t.Errorf("取消暂停中的 Run: HTTP 状态码 = %d, 状态 = %s", code, store.runs["run-p1"].Status)
+	}
+}
+
 // ============================================================================
 // TestUpdate: 更新 Run 状态
 // ============================================================================
@@ -528,6 +869,8 @@ func TestUpdate_TaskStatusSync(t *testing.T) {
 	}
 
 	handler := NewHandlerWithInterfaces(store, nil)
+	finished := 0
+	handler.OnRunFinished(func(*model.Run) { finished++ })
 	mux := http.NewServeMux()
 	handler.RegisterRoutes(mux)
 
@@ -551,6 +894,11 @@ func TestUpdate_TaskStatusSync(t *testing.T) {
 	if store.tasks["task-sync-001"].Status != model.TaskStatusCompleted {
 		t.Errorf("Task 状态 = %s, 期望 completed", store.tasks["task-sync-001"].Status)
 	}
+
+	// 终态回调（通知工作流编排器）
+	if finished != 1 {
+		t.Errorf("OnRunFinished 回调次数 = %d, 期望 1", finished)
+	}
 }
 
 func TestUpdate_MissingStatus(t *testing.T) {
@@ -578,3 +926,170 @@ func TestUpdate_MissingStatus(t *testing.T) {
 		t.Errorf("HTTP 状态码 = %d, 期望 400", w.Code)
 	}
 }
+
+// ============================================================================
+// Run 产物上报（Git 结果回写 PR 地址）
+// ============================================================================
+
+func TestArtifacts_CreateAndList(t *testing.T) {
+	store := newMockStore()
+	store.runs["run-art-1"] = &model.Run{ID: "run-art-1", TaskID: "task-001", Status: model.RunStatusRunning}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	body := strings.NewReader(`{"name":"pull_request","path":"https://github.com/example/repo/pull/7","content_type":"text/uri-list"}`)
+	req := httptest.NewRequest("POST", "/api/v1/runs/run-art-1/artifacts", body)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("HTTP 状态码 = %d, 期望 201, 响应: %s", w.Code, w.Body.String())
+	}
+
+	req = httptest.NewRequest("GET", "/api/v1/runs/run-art-1/artifacts", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("HTTP 状态码 = %d, 期望 200", w.Code)
+	}
+	var resp struct {
+		Artifacts []model.Artifact `json:"artifacts"`
+		Count     int              `json:"count"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if resp.Count != 1 || resp.Artifacts[0].Path != "https://github.com/example/repo/pull/7" {
+		t.Errorf("产物列表 = %+v, 期望包含 PR 地址", resp)
+	}
+}
+
+func TestArtifacts_CreateValidation(t *testing.T) {
+	store := newMockStore()
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	// 缺少 path 应返回 400
+	req := httptest.NewRequest("POST", "/api/v1/runs/run-x/artifacts", strings.NewReader(`{"name":"pull_request"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("HTTP 状态码 = %d, 期望 400", w.Code)
+	}
+
+	// Run 不存在应返回 404
+	req = httptest.NewRequest("POST", "/api/v1/runs/run-x/artifacts", strings.NewReader(`{"name":"pull_request","path":"https://x"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("HTTP 状态码 = %d, 期望 404", w.Code)
+	}
+}
+
+// ============================================================================
+// GET /api/v1/runs 游标分页
+// ============================================================================
+
+func TestList_CursorPagination(t *testing.T) {
+	store := newMockStore()
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"run-a", "run-b", "run-c", "run-d", "run-e"} {
+		store.runs[id] = &model.Run{ID: id, TaskID: "task-1", Status: model.RunStatusDone, CreatedAt: base.Add(time.Duration(i/2) * time.Minute)}
+	}
+
+	mux := http.NewServeMux()
+	NewHandlerWithInterfaces(store, &mockRunScheduler{}).RegisterRoutes(mux)
+
+	type page struct {
+		Runs       []*model.Run `json:"runs"`
+		HasMore    bool         `json:"has_more"`
+		NextCursor string       `json:"next_cursor"`
+		Total      *int         `json:"total"`
+	}
+	get := func(url string) page {
+		t.Helper()
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", url, nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s 状态码 = %d, body = %s", url, w.Code, w.Body.String())
+		}
+		var p page
+		if err := json.Unmarshal(w.Body.Bytes(), &p); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+		return p
+	}
+
+	// 偏移模式默认返回总数
+	first := get("/api/v1/runs?task_id=task-1&limit=2")
+	if first.Total == nil || *first.Total != 5 {
+		t.Fatalf("total = %v, 期望 5", first.Total)
+	}
+
+	var ids []string
+	p := first
+	for {
+		for _, r := range p.Runs {
+			ids = append(ids, r.ID)
+		}
+		if !p.HasMore {
+			break
+		}
+		p = get("/api/v1/runs?task_id=task-1&limit=2&cursor=" + p.NextCursor)
+		// 游标模式默认不统计总数
+		if p.Total != nil {
+			t.Fatalf("游标分页不应返回 total")
+		}
+	}
+	want := "run-e,run-d,run-c,run-b,run-a"
+	if got := strings.Join(ids, ","); got != want {
+		t.Errorf("翻页顺序 = %s, 期望 %s", got, want)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs?cursor=bad!", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("无效游标状态码 = %d, 期望 400", w.Code)
+	}
+}
+
+// ============================================================================
+// CancelTask：取消任务的全部活跃 Run
+// ============================================================================
+
+func TestCancelTask(t *testing.T) {
+	store := newMockStore()
+	store.tasks["task-1"] = &model.Task{ID: "task-1", Status: model.TaskStatusInProgress}
+	store.tasks["task-2"] = &model.Task{ID: "task-2", Status: model.TaskStatusPending}
+	store.runs["run-1"] = &model.Run{ID: "run-1", TaskID: "task-1", Status: model.RunStatusRunning}
+	store.runs["run-2"] = &model.Run{ID: "run-2", TaskID: "task-1", Status: model.RunStatusDone}
+	h := NewHandlerWithInterfaces(store, &mockRunScheduler{})
+
+	cancelled, err := h.CancelTask(context.Background(), "task-1")
+	if err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+	if len(cancelled) != 1 || cancelled[0] != "run-1" {
+		t.Errorf("cancelled = %v, 期望 [run-1]", cancelled)
+	}
+	if store.runs["run-2"].Status != model.RunStatusDone {
+		t.Errorf("已结束的 Run 不应被修改")
+	}
+	if store.tasks["task-1"].Status != model.TaskStatusCancelled {
+		t.Errorf("task-1 状态 = %s, 期望 cancelled", store.tasks["task-1"].Status)
+	}
+
+	// 没有 Run 的待处理任务直接取消
+	if _, err := h.CancelTask(context.Background(), "task-2"); err != nil {
+		t.Fatalf("CancelTask: %v", err)
+	}
+	if store.tasks["task-2"].Status != model.TaskStatusCancelled {
+		t.Errorf("task-2 状态 = %s, 期望 cancelled", store.tasks["task-2"].Status)
+	}
+
+	if _, err := h.CancelTask(context.Background(), "task-x"); !errors.Is(err, ErrTaskNotFound) {
+		t.Errorf("err = %v, 期望 ErrTaskNotFound", err)
+	}
+}
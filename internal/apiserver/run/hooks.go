@@ -0,0 +1,77 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// HookStore 定义解析生命周期钩子需要的存储方法
+type HookStore interface {
+	GetTaskTemplate(ctx context.Context, id string) (*model.TaskTemplate, error)
+	GetSkill(ctx context.Context, id string) (*model.Skill, error)
+}
+
+// errHookSkillNotFound 钩子引用的 Skill 不存在（请求错误）
+var errHookSkillNotFound = errors.New("hook skill not found")
+
+// resolveHooks 计算本次 Run 使用的生命周期钩子
+//
+// 任务自身的 Hooks 优先，未配置时使用模板的 DefaultHooks；
+// 引用 Skill 的钩子展开为内联脚本写入快照，保证 Run 可复现（Skill 后续修改不影响已创建的 Run）。
+func (h *Handler) resolveHooks(ctx context.Context, task *model.Task) (*model.LifecycleHooks, error) {
+	hooks := task.Hooks
+	if hooks.IsEmpty() && task.TemplateID != nil && *task.TemplateID != "" && h.hooks != nil {
+		tmpl, err := h.hooks.GetTaskTemplate(ctx, *task.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		if tmpl != nil {
+			hooks = tmpl.DefaultHooks
+		}
+	}
+	if hooks.IsEmpty() {
+		return nil, nil
+	}
+
+	resolved := &model.LifecycleHooks{}
+	var err error
+	if resolved.PreRun, err = h.resolveHookScripts(ctx, hooks.PreRun); err != nil {
+		return nil, err
+	}
+	if resolved.PostRun, err = h.resolveHookScripts(ctx, hooks.PostRun); err != nil {
+		return nil, err
+	}
+	if resolved.OnFailure, err = h.resolveHookScripts(ctx, hooks.OnFailure); err != nil {
+		return nil, err
+	}
+	return resolved, nil
+}
+
+// resolveHookScripts 将 SkillID 引用展开为内联脚本
+func (h *Handler) resolveHookScripts(ctx context.Context, specs []model.HookSpec) ([]model.HookSpec, error) {
+	if len(specs) == 0 {
+		return nil, nil
+	}
+	out := make([]model.HookSpec, 0, len(specs))
+	for _, spec := range specs {
+		if spec.SkillID != "" && strings.TrimSpace(spec.Script) == "" {
+			if h.hooks == nil {
+				return nil, fmt.Errorf("%w: %s", errHookSkillNotFound, spec.SkillID)
+			}
+			skill, err := h.hooks.GetSkill(ctx, spec.SkillID)
+			if err != nil {
+				return nil, err
+			}
+			if skill == nil || strings.TrimSpace(skill.Instructions) == "" {
+				return nil, fmt.Errorf("%w: %s", errHookSkillNotFound, spec.SkillID)
+			}
+			spec.Script = skill.Instructions
+		}
+		out = append(out, spec)
+	}
+	return out, nil
+}
@@ -0,0 +1,118 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockHookStore 在 mockRunStore 基础上实现 HookStore
+type mockHookStore struct {
+	*mockRunStore
+	templates map[string]*model.TaskTemplate
+	skills    map[string]*model.Skill
+}
+
+func (m *mockHookStore) GetTaskTemplate(ctx context.Context, id string) (*model.TaskTemplate, error) {
+	return m.templates[id], nil
+}
+
+func (m *mockHookStore) GetSkill(ctx context.Context, id string) (*model.Skill, error) {
+	return m.skills[id], nil
+}
+
+func createRunForTask(t *testing.T, store RunStore, taskID string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	t.Helper()
+	mux := http.NewServeMux()
+	NewHandlerWithInterfaces(store, &mockRunScheduler{}).RegisterRoutes(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/"+taskID+"/runs", nil))
+	if w.Code != http.StatusCreated {
+		return w, nil
+	}
+	var run model.Run
+	json.Unmarshal(w.Body.Bytes(), &run)
+	var snapshot map[string]interface{}
+	json.Unmarshal(run.Snapshot, &snapshot)
+	return w, snapshot
+}
+
+func TestCreate_HooksFromTemplateWithSkill(t *testing.T) {
+	tmplID := "tmpl-1"
+	store := &mockHookStore{
+		mockRunStore: newMockStore(),
+		templates: map[string]*model.TaskTemplate{
+			tmplID: {ID: tmplID, DefaultHooks: &model.LifecycleHooks{
+				PreRun:    []model.HookSpec{{Name: "install", SkillID: "skill-install"}},
+				OnFailure: []model.HookSpec{{Name: "notify", Script: "echo failed"}},
+			}},
+		},
+		skills: map[string]*model.Skill{
+			"skill-install": {ID: "skill-install", Instructions: "npm ci"},
+		},
+	}
+	store.tasks["task-1"] = &model.Task{
+		ID: "task-1", Name: "t", Type: "qwen-code",
+		Prompt: &model.Prompt{Content: "p"}, TemplateID: &tmplID,
+	}
+
+	w, snapshot := createRunForTask(t, store, "task-1")
+	if snapshot == nil {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	hooks, ok := snapshot["hooks"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("snapshot.hooks 缺失: %v", snapshot)
+	}
+	pre := hooks["pre_run"].([]interface{})[0].(map[string]interface{})
+	if pre["script"] != "npm ci" {
+		t.Errorf("Skill 未展开为内联脚本: %v", pre)
+	}
+	if _, ok := hooks["on_failure"]; !ok {
+		t.Error("on_failure 钩子缺失")
+	}
+}
+
+func TestCreate_HooksTaskOverridesTemplate(t *testing.T) {
+	tmplID := "tmpl-1"
+	store := &mockHookStore{
+		mockRunStore: newMockStore(),
+		templates: map[string]*model.TaskTemplate{
+			tmplID: {ID: tmplID, DefaultHooks: &model.LifecycleHooks{
+				PreRun: []model.HookSpec{{Name: "from-template", Script: "true"}},
+			}},
+		},
+	}
+	store.tasks["task-1"] = &model.Task{
+		ID: "task-1", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, TemplateID: &tmplID,
+		Hooks: &model.LifecycleHooks{PostRun: []model.HookSpec{{Name: "from-task", Script: "make test"}}},
+	}
+
+	_, snapshot := createRunForTask(t, store, "task-1")
+	hooks := snapshot["hooks"].(map[string]interface{})
+	if _, ok := hooks["pre_run"]; ok {
+		t.Errorf("任务配置钩子时不应继承模板: %v", hooks)
+	}
+	if post := hooks["post_run"].([]interface{}); len(post) != 1 {
+		t.Errorf("post_run = %v", post)
+	}
+}
+
+func TestCreate_HookSkillNotFound(t *testing.T) {
+	store := &mockHookStore{mockRunStore: newMockStore()}
+	store.tasks["task-1"] = &model.Task{
+		ID: "task-1", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"},
+		Hooks: &model.LifecycleHooks{PreRun: []model.HookSpec{{Name: "x", SkillID: "missing"}}},
+	}
+	w, _ := createRunForTask(t, store, "task-1")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, 期望 400", w.Code)
+	}
+	if len(store.runs) != 0 {
+		t.Error("解析失败时不应创建 Run")
+	}
+}
@@ -0,0 +1,74 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"agents-admin/internal/shared/model"
+)
+
+// AccountPoolStore 定义从项目账号池分配账号需要的存储方法
+type AccountPoolStore interface {
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	GetAccount(ctx context.Context, id string) (*model.Account, error)
+}
+
+// errNoPoolAccount 项目账号池中没有可用账号（对应 409）
+var errNoPoolAccount = errors.New("no authenticated account available in project account pool")
+
+// assignPoolAccount 为未绑定 Agent 实例的项目任务从账号池选择账号
+//
+// 仅考虑已认证且 Agent 类型与任务一致的账号，优先选择最久未使用的账号。
+// 同时返回 Agent 类型一致的全部账号（写入快照 agent.account_pool）：NodeManager 执行时
+// 据此按账号负载重新租用账号，账号触发限流时切换到池中其他账号（见 accountpool 包）。
+// 任务已绑定实例、不属于项目或项目未配置账号池时返回空值。
+func (h *Handler) assignPoolAccount(ctx context.Context, task *model.Task) (string, []string, error) {
+	if h.accounts == nil || task.ProjectID == nil || *task.ProjectID == "" {
+		return "", nil, nil
+	}
+	if task.AgentID != nil && *task.AgentID != "" {
+		return "", nil, nil
+	}
+	project, err := h.accounts.GetProject(ctx, *task.ProjectID)
+	if err != nil {
+		return "", nil, err
+	}
+	if project == nil || len(project.AccountPool) == 0 {
+		return "", nil, nil
+	}
+
+	var picked *model.Account
+	var pool []string
+	for _, id := range project.AccountPool {
+		account, err := h.accounts.GetAccount(ctx, id)
+		if err != nil {
+			return "", nil, fmt.Errorf("get account %s: %w", id, err)
+		}
+		if account == nil {
+			continue
+		}
+		if account.AgentTypeID != "" && account.AgentTypeID != string(task.Type) {
+			continue
+		}
+		pool = append(pool, account.ID)
+		if account.Status != model.AccountStatusAuthenticated {
+			continue
+		}
+		if picked == nil || lessRecentlyUsed(account, picked) {
+			picked = account
+		}
+	}
+	if picked == nil {
+		return "", nil, errNoPoolAccount
+	}
+	return picked.ID, pool, nil
+}
+
+// lessRecentlyUsed 判断账号 a 是否比 b 更久未使用（从未使用的账号最优先）
+func lessRecentlyUsed(a, b *model.Account) bool {
+	if a.LastUsedAt == nil {
+		return b.LastUsedAt != nil
+	}
+	return b.LastUsedAt != nil && a.LastUsedAt.Before(*b.LastUsedAt)
+}
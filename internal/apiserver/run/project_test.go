@@ -0,0 +1,81 @@
+package run
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockPoolStore 在 mockRunStore 基础上实现 AccountPoolStore
+type mockPoolStore struct {
+	*mockRunStore
+	projects map[string]*model.Project
+	accounts map[string]*model.Account
+}
+
+func (m *mockPoolStore) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	return m.projects[id], nil
+}
+
+func (m *mockPoolStore) GetAccount(ctx context.Context, id string) (*model.Account, error) {
+	return m.accounts[id], nil
+}
+
+func TestCreate_AssignsLeastRecentlyUsedPoolAccount(t *testing.T) {
+	recent := time.Now()
+	older := recent.Add(-time.Hour)
+	projectID := "proj-1"
+	store := &mockPoolStore{
+		mockRunStore: newMockStore(),
+		projects: map[string]*model.Project{
+			projectID: {ID: projectID, AccountPool: []string{"acc-recent", "acc-older", "acc-expired", "acc-other-type"}},
+		},
+		accounts: map[string]*model.Account{
+			"acc-recent":     {ID: "acc-recent", AgentTypeID: "qwen-code", Status: model.AccountStatusAuthenticated, LastUsedAt: &recent},
+			"acc-older":      {ID: "acc-older", AgentTypeID: "qwen-code", Status: model.AccountStatusAuthenticated, LastUsedAt: &older},
+			"acc-expired":    {ID: "acc-expired", AgentTypeID: "qwen-code", Status: model.AccountStatusExpired},
+			"acc-other-type": {ID: "acc-other-type", AgentTypeID: "claude", Status: model.AccountStatusAuthenticated},
+		},
+	}
+	store.tasks["task-1"] = &model.Task{
+		ID: "task-1", Name: "t", Type: "qwen-code",
+		Prompt: &model.Prompt{Content: "p"}, ProjectID: &projectID,
+	}
+
+	w, snapshot := createRunForTask(t, store, "task-1")
+	if snapshot == nil {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	agent := snapshot["agent"].(map[string]interface{})
+	if agent["account_id"] != "acc-older" {
+		t.Errorf("account_id = %v, 期望 acc-older", agent["account_id"])
+	}
+	// 候选账号包含未认证的同类型账号（执行时重新检查状态），不含其他类型的账号
+	pool, _ := agent["account_pool"].([]interface{})
+	if len(pool) != 3 || pool[0] != "acc-recent" || pool[2] != "acc-expired" {
+		t.Errorf("account_pool = %v, 期望 [acc-recent acc-older acc-expired]", agent["account_pool"])
+	}
+}
+
+func TestCreate_PoolExhausted(t *testing.T) {
+	projectID := "proj-1"
+	store := &mockPoolStore{
+		mockRunStore: newMockStore(),
+		projects:     map[string]*model.Project{projectID: {ID: projectID, AccountPool: []string{"acc-expired"}}},
+		accounts: map[string]*model.Account{
+			"acc-expired": {ID: "acc-expired", Status: model.AccountStatusExpired},
+		},
+	}
+	store.tasks["task-1"] = &model.Task{
+		ID: "task-1", Name: "t", Type: "qwen-code",
+		Prompt: &model.Prompt{Content: "p"}, ProjectID: &projectID,
+	}
+
+	w, _ := createRunForTask(t, store, "task-1")
+	if w.Code != http.StatusConflict {
+		t.Errorf("status = %d, 期望 409", w.Code)
+	}
+}
@@ -0,0 +1,277 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ReconcileStore 定义孤儿 Run 回收需要的存储方法
+type ReconcileStore interface {
+	ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error)
+	GetNode(ctx context.Context, id string) (*model.Node, error)
+	CountEventsByRun(ctx context.Context, runID string) (int, error)
+	CreateEvents(ctx context.Context, events []*model.Event) error
+	AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error)
+	ReclaimRun(ctx context.Context, id, nodeID string, status model.RunStatus, errMsg string) (bool, error)
+}
+
+// ReconcileConfig 孤儿 Run 回收配置
+type ReconcileConfig struct {
+	HeartbeatInterval time.Duration // 节点心跳间隔
+	MissedHeartbeats  int           // 连续缺失多少次心跳后判定 Run 为孤儿
+	RequeueStarted    bool          // 已开始执行（已有事件）的孤儿 Run 是否重新排队，false 时判定为 failed
+}
+
+// orphanTracker 记录节点心跳中连续缺失的 running Run（仅内存，API Server 重启后重新计数）
+type orphanTracker struct {
+	mu      sync.Mutex
+	cfg     ReconcileConfig
+	enabled bool
+	missed  map[string]*missedRun // key: runID
+}
+
+type missedRun struct {
+	nodeID string
+	count  int
+}
+
+func newOrphanTracker() *orphanTracker {
+	return &orphanTracker{missed: make(map[string]*missedRun)}
+}
+
+func (t *orphanTracker) configure(cfg ReconcileConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.cfg = cfg
+	t.enabled = true
+}
+
+func (t *orphanTracker) config() (ReconcileConfig, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.cfg, t.enabled
+}
+
+// observe 记录一次心跳的比对结果，返回连续缺失次数达到阈值的 Run ID
+//
+// active 为 DB 中分配给该节点的 running Run，reported 为节点上报的 running_runs。
+func (t *orphanTracker) observe(nodeID string, active []string, reported map[string]bool, threshold int) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	activeSet := make(map[string]bool, len(active))
+	var orphans []string
+	for _, runID := range active {
+		activeSet[runID] = true
+		if reported[runID] {
+			delete(t.missed, runID)
+			continue
+		}
+		m := t.missed[runID]
+		if m == nil || m.nodeID != nodeID {
+			m = &missedRun{nodeID: nodeID}
+			t.missed[runID] = m
+		}
+		m.count++
+		if m.count >= threshold {
+			orphans = append(orphans, runID)
+			delete(t.missed, runID)
+		}
+	}
+	// 已结束或已迁移的 Run 不再计数
+	for runID, m := range t.missed {
+		if m.nodeID == nodeID && !activeSet[runID] {
+			delete(t.missed, runID)
+		}
+	}
+	return orphans
+}
+
+func (t *orphanTracker) forget(runID string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.missed, runID)
+}
+
+// StartReconciler 启动孤儿 Run 回收循环，直到 ctx 取消
+//
+// 两种情况下的 assigned/running Run 被判定为孤儿并回收：
+//   - 节点失联：最近一次心跳早于 HeartbeatInterval × MissedHeartbeats（由本循环巡检）
+//   - 节点在线但丢失了执行：running Run 连续 MissedHeartbeats 次未出现在节点心跳的 running_runs 中
+//     （由 ObserveRunningRuns 在心跳处理时比对）
+//
+// 尚未产生事件的 Run 重新排队；已开始执行的 Run 按 RequeueStarted 重新排队或判定为 failed。
+// 回收通过以节点为条件的原子更新完成，并写入 run_orphaned 事件说明原因。
+func (h *Handler) StartReconciler(ctx context.Context, interval time.Duration, cfg ReconcileConfig) {
+	if h.reconciler == nil {
+		slog.InfoContext(ctx, "run.reconcile.disabled", "reason", "store_not_supported")
+		return
+	}
+	h.orphans.configure(cfg)
+	slog.InfoContext(ctx, "run.reconcile.start", "interval", interval, "heartbeat_interval", cfg.HeartbeatInterval,
+		"missed_heartbeats", cfg.MissedHeartbeats, "requeue_started", cfg.RequeueStarted)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "run.reconcile.stop", "reason", "context_cancelled")
+			return
+		case <-ticker.C:
+			h.reconcileLostNodes(ctx, time.Now())
+		}
+	}
+}
+
+// reconcileLostNodes 执行一轮失联节点巡检，返回本轮回收的 Run 数量
+func (h *Handler) reconcileLostNodes(ctx context.Context, now time.Time) int {
+	cfg, _ := h.orphans.config()
+	deadline := cfg.HeartbeatInterval * time.Duration(cfg.MissedHeartbeats)
+
+	runs, err := h.reconciler.ListRunningRuns(ctx, watchdogBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.reconcile.list.failed", "error", err)
+		return 0
+	}
+
+	lost := make(map[string]bool) // 按节点缓存本轮判定结果
+	reclaimed := 0
+	for _, run := range runs {
+		nodeID := derefString(run.NodeID)
+		if nodeID == "" {
+			continue
+		}
+		isLost, checked := lost[nodeID]
+		if !checked {
+			isLost = h.nodeLost(ctx, nodeID, now, deadline)
+			lost[nodeID] = isLost
+		}
+		if !isLost {
+			continue
+		}
+		reason := fmt.Sprintf("node %s missed %d heartbeats", nodeID, cfg.MissedHeartbeats)
+		if h.reclaimOrphan(ctx, run, nodeID, reason, cfg) {
+			reclaimed++
+		}
+	}
+	return reclaimed
+}
+
+// nodeLost 判断节点是否失联（节点已删除或最近一次心跳早于 deadline），查询失败时不做判定
+func (h *Handler) nodeLost(ctx context.Context, nodeID string, now time.Time, deadline time.Duration) bool {
+	node, err := h.reconciler.GetNode(ctx, nodeID)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.reconcile.node.failed", "node_id", nodeID, "error", err)
+		return false
+	}
+	if node == nil || node.LastHeartbeat == nil {
+		return true
+	}
+	return now.Sub(*node.LastHeartbeat) > deadline
+}
+
+// ObserveRunningRuns 比对节点心跳上报的 running_runs 与 DB 中分配给该节点的 running Run
+//
+// 连续 MissedHeartbeats 次未被上报的 Run（如 Node Manager 进程重启丢失了执行）判定为孤儿并回收。
+// activeRuns 为 DB 中分配给该节点的 assigned/running Run（由心跳处理查询后传入）。
+// runningRuns 为 nil 表示节点未上报该字段（旧版本 Node Manager），不做比对；回收循环未启动时直接返回。
+func (h *Handler) ObserveRunningRuns(ctx context.Context, nodeID string, runningRuns []string, activeRuns []*model.Run) {
+	cfg, enabled := h.orphans.config()
+	if !enabled || h.reconciler == nil || runningRuns == nil {
+		return
+	}
+
+	reported := make(map[string]bool, len(runningRuns))
+	for _, id := range runningRuns {
+		reported[id] = true
+	}
+	byID := make(map[string]*model.Run, len(activeRuns))
+	var active []string
+	for _, run := range activeRuns {
+		// assigned 的 Run 由节点轮询领取，节点重启后会重新领取，不参与比对
+		if run.Status != model.RunStatusRunning {
+			continue
+		}
+		byID[run.ID] = run
+		active = append(active, run.ID)
+	}
+
+	for _, runID := range h.orphans.observe(nodeID, active, reported, cfg.MissedHeartbeats) {
+		reason := fmt.Sprintf("node %s did not report the run in %d consecutive heartbeats", nodeID, cfg.MissedHeartbeats)
+		h.reclaimOrphan(ctx, byID[runID], nodeID, reason, cfg)
+	}
+}
+
+// reclaimOrphan 回收孤儿 Run，返回是否由本次调用完成回收
+//
+// 回收以 Run 仍分配在 nodeID 为条件：多个 API Server 实例同时判定、或节点恰好上报终态时只有一方生效，
+// 不会重复入队；原节点恢复后，因 Run 已不再分配给它，会在心跳指令中收到取消。
+func (h *Handler) reclaimOrphan(ctx context.Context, run *model.Run, nodeID, reason string, cfg ReconcileConfig) bool {
+	events, err := h.reconciler.CountEventsByRun(ctx, run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.reconcile.count.failed", "run_id", run.ID, "error", err)
+		return false
+	}
+	status, action := model.RunStatusQueued, "requeued"
+	if events > 0 && !cfg.RequeueStarted {
+		status, action = model.RunStatusFailed, "failed"
+	}
+
+	ok, err := h.reconciler.ReclaimRun(ctx, run.ID, nodeID, status, "orphaned: "+reason)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.reconcile.reclaim.failed", "run_id", run.ID, "node_id", nodeID, "error", err)
+		return false
+	}
+	h.orphans.forget(run.ID)
+	if !ok {
+		// 已被其他实例回收，或节点已上报终态
+		return false
+	}
+	slog.WarnContext(ctx, "run.reconcile."+action, "run_id", run.ID, "task_id", run.TaskID, "node_id", nodeID,
+		"events", events, "reason", reason)
+
+	h.recordOrphanEvent(ctx, run.ID, nodeID, action, reason)
+
+	if status == model.RunStatusQueued {
+		if h.scheduler != nil {
+			if _, err := h.scheduleRun(ctx, run); err != nil {
+				// 入队失败由保底轮询处理
+				slog.ErrorContext(ctx, "run.reconcile.queue.failed", "run_id", run.ID, "error", err)
+			}
+		}
+		return true
+	}
+	h.maybeUpdateTaskStatus(ctx, run.ID, model.RunStatusFailed)
+	return true
+}
+
+// recordOrphanEvent 写入 run_orphaned 事件（序号由存储层分配，与节点上报的事件不冲突）
+func (h *Handler) recordOrphanEvent(ctx context.Context, runID, nodeID, action, reason string) {
+	seq, err := h.reconciler.AllocateEventSeqs(ctx, runID, 1)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.reconcile.event.failed", "run_id", runID, "error", err)
+		return
+	}
+	payload, _ := json.Marshal(map[string]interface{}{
+		"node_id": nodeID,
+		"action":  action,
+		"reason":  reason,
+	})
+	event := &model.Event{
+		RunID:     runID,
+		Seq:       seq,
+		Type:      string(model.EventTypeRunOrphaned),
+		Timestamp: time.Now(),
+		Payload:   payload,
+	}
+	if err := h.reconciler.CreateEvents(ctx, []*model.Event{event}); err != nil {
+		slog.ErrorContext(ctx, "run.reconcile.event.failed", "run_id", runID, "error", err)
+	}
+}
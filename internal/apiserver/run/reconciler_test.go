@@ -0,0 +1,240 @@
+package run
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// reconcileStore 在 mockRunStore 基础上实现 ReconcileStore
+type reconcileStore struct {
+	*mockRunStore
+	nodes  map[string]*model.Node
+	counts map[string]int // key: runID，已有事件数
+	events []*model.Event
+}
+
+func newReconcileStore() *reconcileStore {
+	return &reconcileStore{mockRunStore: newMockStore(), nodes: make(map[string]*model.Node), counts: make(map[string]int)}
+}
+
+func (m *reconcileStore) ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error) {
+	var result []*model.Run
+	for _, r := range m.runs {
+		if r.Status == model.RunStatusAssigned || r.Status == model.RunStatusRunning {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *reconcileStore) ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error) {
+	var result []*model.Run
+	for _, r := range m.runs {
+		if derefString(r.NodeID) == nodeID && (r.Status == model.RunStatusAssigned || r.Status == model.RunStatusRunning) {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *reconcileStore) GetNode(ctx context.Context, id string) (*model.Node, error) {
+	return m.nodes[id], nil
+}
+
+func (m *reconcileStore) CountEventsByRun(ctx context.Context, runID string) (int, error) {
+	return m.counts[runID], nil
+}
+
+func (m *reconcileStore) AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error) {
+	return m.counts[runID] + 1, nil
+}
+
+func (m *reconcileStore) CreateEvents(ctx context.Context, events []*model.Event) error {
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *reconcileStore) ReclaimRun(ctx context.Context, id, nodeID string, status model.RunStatus, errMsg string) (bool, error) {
+	r, ok := m.runs[id]
+	if !ok || derefString(r.NodeID) != nodeID || (r.Status != model.RunStatusAssigned && r.Status != model.RunStatusRunning) {
+		return false, nil
+	}
+	r.Status = status
+	if status == model.RunStatusQueued {
+		r.NodeID = nil
+		r.StartedAt = nil
+	} else {
+		r.Error = &errMsg
+	}
+	return true, nil
+}
+
+func strRef(s string) *string { return &s }
+
+func TestReconcileLostNodes(t *testing.T) {
+	store := newReconcileStore()
+	now := time.Now()
+	fresh, stale := now.Add(-5*time.Second), now.Add(-time.Minute)
+	store.nodes["node-live"] = &model.Node{ID: "node-live", LastHeartbeat: &fresh}
+	store.nodes["node-dead"] = &model.Node{ID: "node-dead", LastHeartbeat: &stale}
+	store.tasks["task-started"] = &model.Task{ID: "task-started", Status: model.TaskStatusInProgress}
+
+	store.runs["run-live"] = &model.Run{ID: "run-live", Status: model.RunStatusRunning, NodeID: strRef("node-live")}
+	store.runs["run-pending"] = &model.Run{ID: "run-pending", TaskID: "task-pending", Status: model.RunStatusAssigned, NodeID: strRef("node-dead")}
+	store.runs["run-started"] = &model.Run{ID: "run-started", TaskID: "task-started", Status: model.RunStatusRunning, NodeID: strRef("node-dead")}
+	store.runs["run-deleted"] = &model.Run{ID: "run-deleted", Status: model.RunStatusRunning, NodeID: strRef("node-gone")}
+	store.counts["run-started"] = 7
+
+	scheduler := &mockRunScheduler{}
+	handler := NewHandlerWithInterfaces(store, scheduler)
+	handler.orphans.configure(ReconcileConfig{HeartbeatInterval: 10 * time.Second, MissedHeartbeats: 3})
+
+	if n := handler.reconcileLostNodes(context.Background(), now); n != 3 {
+		t.Fatalf("reconcileLostNodes = %d, 期望 3", n)
+	}
+	for id, want := range map[string]model.RunStatus{
+		"run-live":    model.RunStatusRunning,
+		"run-pending": model.RunStatusQueued,
+		"run-started": model.RunStatusFailed,
+		"run-deleted": model.RunStatusQueued,
+	} {
+		if got := store.runs[id].Status; got != want {
+			t.Errorf("%s 状态 = %s, 期望 %s", id, got, want)
+		}
+	}
+	if len(scheduler.scheduledRuns) != 2 {
+		t.Errorf("重新入队 %v, 期望 run-pending 与 run-deleted", scheduler.scheduledRuns)
+	}
+	if store.tasks["task-started"].Status != model.TaskStatusFailed {
+		t.Errorf("已开始执行的孤儿 Run 的 Task 状态 = %s, 期望 failed", store.tasks["task-started"].Status)
+	}
+
+	if len(store.events) != 3 {
+		t.Fatalf("run_orphaned 事件 %d 个, 期望 3", len(store.events))
+	}
+	for _, e := range store.events {
+		if e.Type != string(model.EventTypeRunOrphaned) {
+			t.Errorf("事件类型 = %s", e.Type)
+		}
+		if want := store.counts[e.RunID] + 1; e.Seq != want {
+			t.Errorf("%s 事件序号 = %d, 期望 %d", e.RunID, e.Seq, want)
+		}
+		if e.RunID == "run-started" && !strings.Contains(string(e.Payload), `"action":"failed"`) {
+			t.Errorf("事件载荷 = %s", e.Payload)
+		}
+	}
+
+	// 幂等：再次巡检不会重复回收或入队
+	if n := handler.reconcileLostNodes(context.Background(), now); n != 0 {
+		t.Errorf("第二轮 reconcileLostNodes = %d, 期望 0", n)
+	}
+	if len(scheduler.scheduledRuns) != 2 || len(store.events) != 3 {
+		t.Errorf("重复回收: scheduled=%v events=%d", scheduler.scheduledRuns, len(store.events))
+	}
+}
+
+func TestReconcileLostNodes_RequeueStarted(t *testing.T) {
+	store := newReconcileStore()
+	now := time.Now()
+	stale := now.Add(-time.Minute)
+	store.nodes["node-dead"] = &model.Node{ID: "node-dead", LastHeartbeat: &stale}
+	store.runs["run-started"] = &model.Run{ID: "run-started", Status: model.RunStatusRunning, NodeID: strRef("node-dead")}
+	store.counts["run-started"] = 4
+
+	scheduler := &mockRunScheduler{}
+	handler := NewHandlerWithInterfaces(store, scheduler)
+	handler.orphans.configure(ReconcileConfig{HeartbeatInterval: 10 * time.Second, MissedHeartbeats: 3, RequeueStarted: true})
+
+	handler.reconcileLostNodes(context.Background(), now)
+	if store.runs["run-started"].Status != model.RunStatusQueued || len(scheduler.scheduledRuns) != 1 {
+		t.Errorf("RequeueStarted 时应重新排队, status = %s", store.runs["run-started"].Status)
+	}
+	if len(store.events) != 1 || store.events[0].Seq != 5 {
+		t.Errorf("run_orphaned 事件应接在已有 4 个事件之后: %+v", store.events)
+	}
+}
+
+func TestObserveRunningRuns(t *testing.T) {
+	store := newReconcileStore()
+	store.runs["run-lost"] = &model.Run{ID: "run-lost", Status: model.RunStatusRunning, NodeID: strRef("node-1")}
+	store.runs["run-ok"] = &model.Run{ID: "run-ok", Status: model.RunStatusRunning, NodeID: strRef("node-1")}
+	store.runs["run-assigned"] = &model.Run{ID: "run-assigned", Status: model.RunStatusAssigned, NodeID: strRef("node-1")}
+	store.runs["run-flaky"] = &model.Run{ID: "run-flaky", Status: model.RunStatusRunning, NodeID: strRef("node-1")}
+
+	scheduler := &mockRunScheduler{}
+	handler := NewHandlerWithInterfaces(store, scheduler)
+	ctx := context.Background()
+	observe := func(runningRuns []string) {
+		active, _ := store.ListRunsByNode(ctx, "node-1")
+		handler.ObserveRunningRuns(ctx, "node-1", runningRuns, active)
+	}
+
+	// 回收循环未启动时不做比对
+	for i := 0; i < 5; i++ {
+		observe([]string{})
+	}
+	if store.runs["run-lost"].Status != model.RunStatusRunning {
+		t.Fatal("未启用时不应回收")
+	}
+
+	handler.orphans.configure(ReconcileConfig{HeartbeatInterval: 10 * time.Second, MissedHeartbeats: 3})
+	observe([]string{"run-ok"})
+	observe(nil) // 未上报字段，不计数
+	observe([]string{"run-ok"})
+	if store.runs["run-lost"].Status != model.RunStatusRunning {
+		t.Fatalf("缺失 2 次不应回收, status = %s", store.runs["run-lost"].Status)
+	}
+	observe([]string{"run-ok", "run-flaky"}) // run-flaky 重新出现，计数清零
+	observe([]string{"run-ok"})
+	observe([]string{"run-ok"})
+
+	for id, want := range map[string]model.RunStatus{
+		"run-lost":     model.RunStatusQueued,
+		"run-ok":       model.RunStatusRunning,
+		"run-assigned": model.RunStatusAssigned,
+		"run-flaky":    model.RunStatusRunning,
+	} {
+		if got := store.runs[id].Status; got != want {
+			t.Errorf("%s 状态 = %s, 期望 %s", id, got, want)
+		}
+	}
+	if len(scheduler.scheduledRuns) != 1 || scheduler.scheduledRuns[0] != "run-lost" {
+		t.Errorf("重新入队 %v, 期望 [run-lost]", scheduler.scheduledRuns)
+	}
+	if len(store.events) != 1 || !strings.Contains(string(store.events[0].Payload), "consecutive heartbeats") {
+		t.Errorf("unexpected events: %+v", store.events)
+	}
+}
+
+func TestUpdate_StaleNodeRejected(t *testing.T) {
+	store := newMockStore()
+	store.runs["run-moved"] = &model.Run{ID: "run-moved", TaskID: "task-001", Status: model.RunStatusRunning, NodeID: strRef("node-new")}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	// 原节点在 Run 被回收并重新分配后的迟到上报被拒绝
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/runs/run-moved",
+		strings.NewReader(`{"status": "cancelled", "node_id": "node-old"}`)))
+	if w.Code != http.StatusConflict {
+		t.Fatalf("HTTP 状态码 = %d, 期望 409", w.Code)
+	}
+	if store.runs["run-moved"].Status != model.RunStatusRunning {
+		t.Errorf("Run 状态 = %s, 期望 running", store.runs["run-moved"].Status)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PATCH", "/api/v1/runs/run-moved",
+		strings.NewReader(`{"status": "done", "node_id": "node-new"}`)))
+	if w.Code != http.StatusOK || store.runs["run-moved"].Status != model.RunStatusDone {
+		t.Errorf("当前节点上报应生效: code=%d status=%s", w.Code, store.runs["run-moved"].Status)
+	}
+}
@@ -0,0 +1,33 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
+)
+
+// resolveSecurityPolicy 计算本次 Run 的生效安全策略（见 secpolicy 包）
+//
+// 任务创建后 Agent 模板的默认策略可能被修改，这里重新校验：需要的权限被禁止时返回 403，
+// 引用的策略不存在或权限未知时返回 400。返回的错误均为 *startError。
+func (h *Handler) resolveSecurityPolicy(ctx context.Context, task *model.Task) (*secpolicy.Policy, error) {
+	if h.policies == nil {
+		return nil, nil
+	}
+	policy, err := secpolicy.Resolve(ctx, h.policies, task)
+	if err == nil {
+		return policy, nil
+	}
+	var violation *secpolicy.ViolationError
+	switch {
+	case errors.As(err, &violation):
+		return nil, &startError{http.StatusForbidden, err.Error(), err}
+	case errors.Is(err, secpolicy.ErrUnknownPermission), errors.Is(err, secpolicy.ErrPolicyNotFound):
+		return nil, &startError{http.StatusBadRequest, err.Error(), err}
+	default:
+		return nil, &startError{http.StatusInternalServerError, "failed to resolve security policy", err}
+	}
+}
@@ -0,0 +1,87 @@
+package run
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockPolicyStore 在 mockSkillStore 基础上实现安全策略查询
+type mockPolicyStore struct {
+	*mockSkillStore
+	policies map[string]*model.SecurityPolicyEntity
+}
+
+func (m *mockPolicyStore) GetSecurityPolicy(ctx context.Context, id string) (*model.SecurityPolicyEntity, error) {
+	return m.policies[id], nil
+}
+
+func TestCreate_SecurityPolicySnapshot(t *testing.T) {
+	policyID := "sec-no-shell"
+	store := &mockPolicyStore{mockSkillStore: newSkillStore(), policies: map[string]*model.SecurityPolicyEntity{
+		policyID: {ID: policyID, ToolPermissions: []model.ToolPermission{{Tool: "command_execute", Permission: model.ToolPermissionDenied}}},
+	}}
+	store.templates["agent-tmpl-1"].DefaultSecurityPolicyID = &policyID
+	store.tasks["task-1"].Security = &model.SecurityConfig{Policy: model.SecurityPolicyStandard, RequireApproval: []string{"file_delete"}}
+
+	w, snapshot := createRunForTask(t, store, "task-1")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	policy, _ := snapshot["security_policy"].(map[string]interface{})
+	perms, _ := policy["permissions"].(map[string]interface{})
+	want := map[string]string{
+		"file_read":        "allowed",
+		"file_delete":      "approval_required",
+		"command_execute":  "denied",
+		"network_outbound": "allowed",
+	}
+	for perm, level := range want {
+		if perms[perm] != level {
+			t.Errorf("%s = %v, want %s", perm, perms[perm], level)
+		}
+	}
+	if sources, _ := policy["sources"].([]interface{}); len(sources) != 2 {
+		t.Errorf("sources = %v", policy["sources"])
+	}
+
+	// 模板策略在任务创建后收紧：需要的权限被禁止时拒绝创建 Run
+	store.tasks["task-1"].Security.Permissions = []string{"command_execute"}
+	if w, _ := createRunForTask(t, store, "task-1"); w.Code != http.StatusForbidden {
+		t.Errorf("violation: status = %d", w.Code)
+	}
+
+	// 没有受限权限时快照不含 security_policy
+	store.templates["agent-tmpl-1"].DefaultSecurityPolicyID = nil
+	store.tasks["task-1"].Security = &model.SecurityConfig{Policy: model.SecurityPolicyPermissive}
+	if _, snapshot := createRunForTask(t, store, "task-1"); snapshot["security_policy"] != nil {
+		t.Errorf("security_policy = %v", snapshot["security_policy"])
+	}
+}
+
+func TestCreate_CommandFilterSnapshot(t *testing.T) {
+	store := &mockPolicyStore{mockSkillStore: newSkillStore()}
+	store.tasks["task-1"].Security = &model.SecurityConfig{
+		Policy:   model.SecurityPolicyStrict,
+		Commands: &model.CommandFilter{Deny: []string{`\bgit push\b`}},
+	}
+
+	w, snapshot := createRunForTask(t, store, "task-1")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	filter, _ := snapshot["command_filter"].(map[string]interface{})
+	deny, _ := filter["deny"].([]interface{})
+	// strict 策略未指定处理方式时直接拦截
+	if filter["action"] != "block" || len(deny) != 1 || deny[0] != `\bgit push\b` {
+		t.Errorf("command_filter = %v", filter)
+	}
+
+	// 未设置安全配置时不下发规则，NodeManager 只做内置检测
+	store.tasks["task-1"].Security = nil
+	if _, snapshot := createRunForTask(t, store, "task-1"); snapshot["command_filter"] != nil {
+		t.Errorf("command_filter = %v", snapshot["command_filter"])
+	}
+}
@@ -0,0 +1,253 @@
+// Package run 持久 Agent 会话与追问
+//
+// 启用会话（task.session.enabled）的任务创建 Run 时在执行快照中写入 session（见 model.RunSession），
+// NodeManager 在 Run 结束后保留执行容器（工作空间与 CLI 会话历史）。
+//
+// 追问（POST /api/v1/tasks/{id}/followup）：
+//   - 任务最近一次 Run 结束后才能追问，执行中返回 409
+//   - 新 Run 沿用任务配置，prompt 为追问内容，session.resume 为 true 并携带之前的对话历史
+//   - 快照 node_id 指定上一轮 Run 的节点（direct 策略）并沿用同一账号；节点不可用时由后续策略选择节点，
+//     NodeManager 找不到保留的容器时将对话历史拼入提示词
+//
+// 对话记录：追问时将提示词追加到任务上下文的 conversation_history，会话 Run 结束时从事件中提取 Agent 的回复追加，
+// 通过 GET /api/v1/tasks/{id}/context 查看完整对话。非追问的 Run 开始新的会话，对话记录重置为本轮提示词与回复。
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// sessionEventBatch 提取 Agent 回复时每批读取的事件数
+const sessionEventBatch = 500
+
+// SessionStore 持久会话需要的存储接口
+type SessionStore interface {
+	TaskContextStore
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+}
+
+// followUp 追问 Run 的参数
+type followUp struct {
+	prompt   string
+	previous *model.Run      // 上一轮会话的 Run
+	account  string          // 上一轮会话使用的账号（为空时按任务配置分配）
+	history  []model.Message // 本轮提示词之前的对话历史
+}
+
+// FollowUpRequest 追问请求
+type FollowUpRequest struct {
+	Prompt string `json:"prompt"` // 追问内容（必填）
+}
+
+// FollowUp 在任务的持久会话中以新的提示词继续执行
+// POST /api/v1/tasks/{id}/followup
+//
+// 请求体: {"prompt": "..."}
+//
+// 响应: 新创建的 Run；任务未启用会话、没有已结束的 Run 或上一轮仍在执行时返回 409
+func (h *Handler) FollowUp(w http.ResponseWriter, r *http.Request) {
+	var req FollowUpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, "prompt is required")
+		return
+	}
+	if h.sessions == nil {
+		writeError(w, http.StatusNotImplemented, "sessions not supported")
+		return
+	}
+	run, err := h.StartFollowUp(r.Context(), r.PathValue("id"), req.Prompt)
+	if err != nil {
+		writeStartError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, run)
+}
+
+// StartFollowUp 为启用会话的任务创建追问 Run，并将追问内容追加到对话记录
+func (h *Handler) StartFollowUp(ctx context.Context, taskID, prompt string) (*model.Run, error) {
+	h.contextMu.Lock()
+	defer h.contextMu.Unlock()
+
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return nil, &startError{http.StatusInternalServerError, "failed to get task", err}
+	}
+	if task == nil {
+		return nil, &startError{http.StatusNotFound, "task not found", ErrTaskNotFound}
+	}
+	if !task.Session.IsEnabled() {
+		return nil, &startError{http.StatusConflict, "session is not enabled for this task", nil}
+	}
+	runs, err := h.store.ListRunsByTask(ctx, taskID)
+	if err != nil {
+		return nil, &startError{http.StatusInternalServerError, "failed to list runs", err}
+	}
+	previous := latestRun(runs)
+	if previous == nil {
+		return nil, &startError{http.StatusConflict, "task has no run to follow up", nil}
+	}
+	if !previous.IsTerminal() {
+		return nil, &startError{http.StatusConflict, "run " + previous.ID + " is still " + string(previous.Status), nil}
+	}
+
+	history := conversation(task)
+	run, err := h.startRun(ctx, taskID, &followUp{
+		prompt:   prompt,
+		previous: previous,
+		account:  h.sessionAccount(ctx, previous),
+		history:  history,
+	})
+	if err != nil {
+		return nil, err
+	}
+	slog.InfoContext(ctx, "run.followup.created", "run_id", run.ID, "task_id", taskID, "previous_run_id", previous.ID, "node_id", derefString(previous.NodeID))
+
+	h.saveConversation(ctx, task, append(history, model.Message{Role: "user", Content: prompt, Timestamp: run.CreatedAt}))
+	return run, nil
+}
+
+// sessionSnapshot 执行快照中的会话信息（f 为 nil 时开始新的会话）
+func sessionSnapshot(task *model.Task, f *followUp) *model.RunSession {
+	s := &model.RunSession{ID: task.ID, KeepAliveSeconds: task.Session.KeepAlive()}
+	if f != nil {
+		s.Resume = true
+		s.PreviousRunID = f.previous.ID
+		s.History = f.history
+	}
+	return s
+}
+
+// sessionAccount 上一轮会话实际使用的账号：NodeManager 从账号池重新租用时以 run_started 事件为准，否则取执行快照
+func (h *Handler) sessionAccount(ctx context.Context, previous *model.Run) string {
+	events, err := h.sessions.GetEventsByRun(ctx, previous.ID, 0, 10)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.followup.account_failed", "run_id", previous.ID, "error", err)
+	}
+	for _, e := range events {
+		if e.Type != string(model.EventTypeRunStarted) {
+			continue
+		}
+		var p struct {
+			AccountID string `json:"account_id"`
+		}
+		if json.Unmarshal(e.Payload, &p) == nil && p.AccountID != "" {
+			return p.AccountID
+		}
+	}
+	if snap, err := model.ParseRunSnapshot(previous.Snapshot); err == nil {
+		return snap.Agent.AccountID
+	}
+	return ""
+}
+
+// recordSessionReply 会话 Run 结束后将 Agent 的回复追加到对话记录（非追问的 Run 重置对话记录）
+func (h *Handler) recordSessionReply(ctx context.Context, run *model.Run) {
+	if h.sessions == nil || len(run.Snapshot) == 0 {
+		return
+	}
+	snap, err := model.ParseRunSnapshot(run.Snapshot)
+	if err != nil || snap.Session == nil {
+		return
+	}
+	reply, err := h.runReply(ctx, run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.session.reply_failed", "run_id", run.ID, "error", err)
+	}
+
+	h.contextMu.Lock()
+	defer h.contextMu.Unlock()
+	task, err := h.store.GetTask(ctx, run.TaskID)
+	if err != nil || task == nil {
+		slog.ErrorContext(ctx, "run.session.reply_failed", "run_id", run.ID, "task_id", run.TaskID, "error", err)
+		return
+	}
+	var history []model.Message
+	if snap.Session.Resume {
+		history = conversation(task)
+	} else {
+		history = []model.Message{{Role: "user", Content: snap.Prompt, Timestamp: run.CreatedAt}}
+	}
+	if reply != "" {
+		history = append(history, model.Message{Role: "assistant", Content: reply, Timestamp: time.Now()})
+	}
+	h.saveConversation(ctx, task, history)
+}
+
+// runReply 从 Run 事件中提取 Agent 的回复：全部消息文本，没有消息时取最终结果
+func (h *Handler) runReply(ctx context.Context, runID string) (string, error) {
+	var messages []string
+	var result string
+	for fromSeq := 0; ; {
+		events, err := h.sessions.GetEventsByRun(ctx, runID, fromSeq, sessionEventBatch)
+		if err != nil {
+			return "", err
+		}
+		for _, e := range events {
+			var p map[string]interface{}
+			json.Unmarshal(e.Payload, &p)
+			switch model.EventType(e.Type) {
+			case model.EventTypeMessage:
+				if text, _ := p["content"].(string); text != "" && p["type"] != "thinking" && p["type"] != "user" {
+					messages = append(messages, text)
+				}
+			case model.EventTypeRunCompleted:
+				if text, _ := p["result"].(string); text != "" {
+					result = text
+				}
+			}
+			fromSeq = e.Seq
+		}
+		if len(events) < sessionEventBatch {
+			break
+		}
+	}
+	if len(messages) == 0 {
+		return result, nil
+	}
+	return strings.Join(messages, "\n\n"), nil
+}
+
+// saveConversation 写入任务的对话记录（保留上下文的其他部分），失败只写日志
+func (h *Handler) saveConversation(ctx context.Context, task *model.Task, history []model.Message) {
+	tc := model.TaskContext{}
+	if task.Context != nil {
+		tc = *task.Context
+	}
+	tc.ConversationHistory = history
+	data, _ := json.Marshal(tc)
+	if err := h.sessions.UpdateTaskContext(ctx, task.ID, data); err != nil {
+		slog.ErrorContext(ctx, "run.session.save_failed", "task_id", task.ID, "error", err)
+		return
+	}
+	task.Context = &tc
+}
+
+// conversation 返回任务对话记录的副本
+func conversation(task *model.Task) []model.Message {
+	if task.Context == nil {
+		return nil
+	}
+	return append([]model.Message(nil), task.Context.ConversationHistory...)
+}
+
+// latestRun 返回最近创建的 Run（没有 Run 时返回 nil）
+func latestRun(runs []*model.Run) *model.Run {
+	var latest *model.Run
+	for _, r := range runs {
+		if latest == nil || r.CreatedAt.After(latest.CreatedAt) {
+			latest = r
+		}
+	}
+	return latest
+}
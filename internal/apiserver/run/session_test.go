@@ -0,0 +1,151 @@
+package run
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockSessionStore 在 mockRunStore 基础上实现 SessionStore
+type mockSessionStore struct {
+	*mockRunStore
+	events map[string][]*model.Event
+}
+
+func (m *mockSessionStore) UpdateTaskContext(ctx context.Context, id string, taskContext json.RawMessage) error {
+	var tc model.TaskContext
+	if err := json.Unmarshal(taskContext, &tc); err != nil {
+		return err
+	}
+	m.tasks[id].Context = &tc
+	return nil
+}
+
+func (m *mockSessionStore) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	var result []*model.Event
+	for _, e := range m.events[runID] {
+		if e.Seq > fromSeq && len(result) < limit {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func sessionEvent(seq int, typ model.EventType, payload string) *model.Event {
+	return &model.Event{Seq: seq, Type: string(typ), Payload: json.RawMessage(payload)}
+}
+
+func postFollowUp(mux *http.ServeMux, taskID, prompt string) *httptest.ResponseRecorder {
+	body, _ := json.Marshal(FollowUpRequest{Prompt: prompt})
+	req := httptest.NewRequest("POST", "/api/v1/tasks/"+taskID+"/followup", strings.NewReader(string(body)))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestFollowUp_Conversation(t *testing.T) {
+	store := &mockSessionStore{mockRunStore: newMockStore(), events: map[string][]*model.Event{}}
+	store.tasks["task-1"] = &model.Task{
+		ID: "task-1", Name: "t", Type: "qwen-code",
+		Prompt:  &model.Prompt{Content: "写一个函数"},
+		Session: &model.TaskSession{Enabled: true, KeepAliveSeconds: 600},
+	}
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	w, snapshot := createRunForTask(t, store, "task-1")
+	if snapshot == nil {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	session, _ := snapshot["session"].(map[string]interface{})
+	if session["id"] != "task-1" || session["keep_alive_seconds"] != float64(600) || session["resume"] == true {
+		t.Fatalf("snapshot.session = %v", snapshot["session"])
+	}
+	var first *model.Run
+	for _, r := range store.runs {
+		first = r
+	}
+
+	// 上一轮仍在执行
+	if w := postFollowUp(mux, "task-1", "再加上测试"); w.Code != http.StatusConflict {
+		t.Fatalf("执行中追问 status = %d, 期望 409", w.Code)
+	}
+
+	// 上一轮结束：记录 Agent 回复
+	store.events[first.ID] = []*model.Event{
+		sessionEvent(1, model.EventTypeRunStarted, `{"account_id":"acc-2"}`),
+		sessionEvent(2, model.EventTypeMessage, `{"type":"thinking","content":"思考中"}`),
+		sessionEvent(3, model.EventTypeMessage, `{"type":"assistant","content":"已完成"}`),
+		sessionEvent(4, model.EventTypeRunCompleted, `{"result":"已完成"}`),
+	}
+	first.NodeID = strRef("node-1")
+	req := httptest.NewRequest("PATCH", "/api/v1/runs/"+first.ID, strings.NewReader(`{"status":"done"}`))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PATCH status = %d, body = %s", w.Code, w.Body.String())
+	}
+	history := store.tasks["task-1"].Context.ConversationHistory
+	if len(history) != 2 || history[0].Content != "写一个函数" || history[1].Role != "assistant" || history[1].Content != "已完成" {
+		t.Fatalf("对话记录 = %+v", history)
+	}
+
+	w = postFollowUp(mux, "task-1", "再加上测试")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("追问 status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created model.Run
+	json.NewDecoder(w.Body).Decode(&created)
+	var snap map[string]interface{}
+	json.Unmarshal(store.runs[created.ID].Snapshot, &snap)
+	if snap["prompt"] != "再加上测试" || snap["node_id"] != "node-1" {
+		t.Errorf("追问快照 prompt = %v, node_id = %v", snap["prompt"], snap["node_id"])
+	}
+	agent := snap["agent"].(map[string]interface{})
+	if agent["account_id"] != "acc-2" || agent["account_pool"] != nil {
+		t.Errorf("追问应沿用上一轮账号: %v", agent)
+	}
+	session, _ = snap["session"].(map[string]interface{})
+	if session["resume"] != true || session["previous_run_id"] != first.ID {
+		t.Errorf("snapshot.session = %v", session)
+	}
+	if h, _ := session["history"].([]interface{}); len(h) != 2 {
+		t.Errorf("snapshot.session.history = %v", session["history"])
+	}
+	if history := store.tasks["task-1"].Context.ConversationHistory; len(history) != 3 || history[2].Content != "再加上测试" {
+		t.Errorf("追问后对话记录 = %+v", history)
+	}
+}
+
+func TestFollowUp_Rejected(t *testing.T) {
+	store := &mockSessionStore{mockRunStore: newMockStore(), events: map[string][]*model.Event{}}
+	store.tasks["task-1"] = &model.Task{ID: "task-1", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}}
+	store.tasks["task-2"] = &model.Task{
+		ID: "task-2", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"},
+		Session: &model.TaskSession{Enabled: true},
+	}
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	cases := []struct {
+		name, taskID, prompt string
+		want                 int
+	}{
+		{"缺少提示词", "task-1", " ", http.StatusBadRequest},
+		{"任务不存在", "task-x", "p", http.StatusNotFound},
+		{"未启用会话", "task-1", "p", http.StatusConflict},
+		{"没有 Run", "task-2", "p", http.StatusConflict},
+	}
+	for _, tc := range cases {
+		if w := postFollowUp(mux, tc.taskID, tc.prompt); w.Code != tc.want {
+			t.Errorf("%s: status = %d, 期望 %d", tc.name, w.Code, tc.want)
+		}
+	}
+}
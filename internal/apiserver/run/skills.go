@@ -0,0 +1,65 @@
+package run
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/skillbundle"
+)
+
+// SkillBundleStore 定义解析 Run 技能包需要的存储方法
+type SkillBundleStore interface {
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+	GetAgentTemplate(ctx context.Context, id string) (*model.AgentTemplate, error)
+	GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error)
+	ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error)
+}
+
+// errSkillBundleNotFound Agent 模板固定的技能包版本不存在（请求错误）
+var errSkillBundleNotFound = errors.New("skill bundle not found")
+
+// resolveSkills 解析本次 Run 安装的技能包
+//
+// 技能来自任务绑定实例的 Agent 模板：<skill_id>@<version> 固定版本（不存在时报错），
+// 未固定时使用最新上传的版本，没有技能包的技能（只有说明的技能）跳过。
+// 版本与摘要写入快照，技能包后续上传新版本不影响已创建的 Run。
+func (h *Handler) resolveSkills(ctx context.Context, task *model.Task) ([]model.SnapshotSkill, error) {
+	if h.skills == nil || task.AgentID == nil || *task.AgentID == "" {
+		return nil, nil
+	}
+	inst, err := h.skills.GetAgentInstance(ctx, *task.AgentID)
+	if err != nil || inst == nil || inst.TemplateID == nil || *inst.TemplateID == "" {
+		return nil, err
+	}
+	tmpl, err := h.skills.GetAgentTemplate(ctx, *inst.TemplateID)
+	if err != nil || tmpl == nil {
+		return nil, err
+	}
+
+	var skills []model.SnapshotSkill
+	for _, ref := range tmpl.Skills {
+		id, version := skillbundle.ParseRef(ref)
+		var bundle *model.SkillBundle
+		if version != "" {
+			if bundle, err = h.skills.GetSkillBundle(ctx, id, version); err != nil {
+				return nil, err
+			}
+			if bundle == nil {
+				return nil, fmt.Errorf("%w: %s@%s", errSkillBundleNotFound, id, version)
+			}
+		} else {
+			bundles, err := h.skills.ListSkillBundles(ctx, id)
+			if err != nil {
+				return nil, err
+			}
+			if len(bundles) == 0 {
+				continue
+			}
+			bundle = bundles[0]
+		}
+		skills = append(skills, model.SnapshotSkill{SkillID: id, Name: bundle.Name, Version: bundle.Version, Digest: bundle.Digest})
+	}
+	return skills, nil
+}
@@ -0,0 +1,88 @@
+package run
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockSkillStore 在 mockRunStore 基础上实现 SkillBundleStore
+type mockSkillStore struct {
+	*mockRunStore
+	instances map[string]*model.Instance
+	templates map[string]*model.AgentTemplate
+	bundles   map[string][]*model.SkillBundle // 按上传时间倒序
+}
+
+func (m *mockSkillStore) GetAgentInstance(ctx context.Context, id string) (*model.Instance, error) {
+	return m.instances[id], nil
+}
+
+func (m *mockSkillStore) GetAgentTemplate(ctx context.Context, id string) (*model.AgentTemplate, error) {
+	return m.templates[id], nil
+}
+
+func (m *mockSkillStore) GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error) {
+	for _, b := range m.bundles[skillID] {
+		if b.Version == version {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *mockSkillStore) ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error) {
+	return m.bundles[skillID], nil
+}
+
+func newSkillStore(skills ...string) *mockSkillStore {
+	tmplID, instID := "agent-tmpl-1", "inst-1"
+	store := &mockSkillStore{
+		mockRunStore: newMockStore(),
+		instances:    map[string]*model.Instance{instID: {ID: instID, TemplateID: &tmplID}},
+		templates:    map[string]*model.AgentTemplate{tmplID: {ID: tmplID, Skills: skills}},
+		bundles: map[string][]*model.SkillBundle{
+			"skill-review": {
+				{SkillID: "skill-review", Name: "review", Version: "1.1.0", Digest: "sha256:new"},
+				{SkillID: "skill-review", Name: "review", Version: "1.0.0", Digest: "sha256:old"},
+			},
+			"skill-lint": {{SkillID: "skill-lint", Name: "lint", Version: "0.1.0", Digest: "sha256:lint"}},
+		},
+	}
+	store.tasks["task-1"] = &model.Task{ID: "task-1", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, AgentID: &instID}
+	return store
+}
+
+func TestCreate_SkillsFromAgentTemplate(t *testing.T) {
+	// 固定版本、最新版本与没有技能包的技能
+	store := newSkillStore("skill-review@1.0.0", "skill-lint", "builtin-code-review")
+	w, snapshot := createRunForTask(t, store, "task-1")
+	if snapshot == nil {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	skills, _ := snapshot["skills"].([]interface{})
+	if len(skills) != 2 {
+		t.Fatalf("snapshot.skills = %v", snapshot["skills"])
+	}
+	review := skills[0].(map[string]interface{})
+	lint := skills[1].(map[string]interface{})
+	if review["version"] != "1.0.0" || review["digest"] != "sha256:old" || review["name"] != "review" {
+		t.Errorf("固定版本 = %v", review)
+	}
+	if lint["version"] != "0.1.0" || lint["skill_id"] != "skill-lint" {
+		t.Errorf("最新版本 = %v", lint)
+	}
+}
+
+func TestCreate_PinnedSkillBundleNotFound(t *testing.T) {
+	store := newSkillStore("skill-review@2.0.0")
+	w, _ := createRunForTask(t, store, "task-1")
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, 期望 400", w.Code)
+	}
+	if len(store.runs) != 0 {
+		t.Error("解析失败时不应创建 Run")
+	}
+}
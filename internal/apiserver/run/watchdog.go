@@ -0,0 +1,100 @@
+package run
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// watchdogBatchSize 每轮巡检读取的活跃 Run 上限（按 started_at 升序，最早开始的优先）
+const watchdogBatchSize = 500
+
+// WatchdogStore 定义超时巡检需要的存储方法
+type WatchdogStore interface {
+	ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error)
+	MarkRunTimeout(ctx context.Context, id string, errMsg string) (bool, error)
+}
+
+// StartWatchdog 启动超时巡检循环，直到 ctx 取消
+//
+// 每轮将超过执行时限的 running Run 标记为 timeout（执行时限取执行快照中的 timeout_seconds，
+// 未设置时使用 defaultTimeout），并联动更新 Task 状态、触发 onFinish 回调。
+// 节点通过心跳指令 timeout_runs 得知超时并终止容器内的执行进程。
+func (h *Handler) StartWatchdog(ctx context.Context, interval, defaultTimeout time.Duration) {
+	if h.watchdog == nil {
+		slog.InfoContext(ctx, "run.watchdog.disabled", "reason", "store_not_supported")
+		return
+	}
+	h.watchdogTimeout.CompareAndSwap(0, int64(defaultTimeout)) // 已由 SetWatchdogTimeout 设置时保持不变
+	slog.InfoContext(ctx, "run.watchdog.start", "interval", interval, "default_timeout", defaultTimeout)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "run.watchdog.stop", "reason", "context_cancelled")
+			return
+		case <-ticker.C:
+			h.checkTimeouts(ctx, time.Now(), time.Duration(h.watchdogTimeout.Load()))
+		}
+	}
+}
+
+// SetWatchdogTimeout 调整超时巡检的默认执行时限（下一轮巡检生效，可在运行中调用）
+func (h *Handler) SetWatchdogTimeout(d time.Duration) {
+	h.watchdogTimeout.Store(int64(d))
+}
+
+// checkTimeouts 执行一轮超时巡检，返回本轮标记为 timeout 的 Run 数量
+func (h *Handler) checkTimeouts(ctx context.Context, now time.Time, defaultTimeout time.Duration) int {
+	runs, err := h.watchdog.ListRunningRuns(ctx, watchdogBatchSize)
+	if err != nil {
+		slog.ErrorContext(ctx, "run.watchdog.list.failed", "error", err)
+		return 0
+	}
+
+	marked := 0
+	for _, run := range runs {
+		if run.Status != model.RunStatusRunning || run.StartedAt == nil {
+			continue
+		}
+		timeout := runTimeout(run, defaultTimeout)
+		if timeout <= 0 || now.Sub(*run.StartedAt) < timeout {
+			continue
+		}
+
+		errMsg := fmt.Sprintf("run exceeded timeout of %s", timeout)
+		ok, err := h.watchdog.MarkRunTimeout(ctx, run.ID, errMsg)
+		if err != nil {
+			slog.ErrorContext(ctx, "run.watchdog.mark.failed", "run_id", run.ID, "error", err)
+			continue
+		}
+		if !ok {
+			// 节点已在巡检期间上报终态
+			continue
+		}
+		slog.WarnContext(ctx, "run.watchdog.timeout", "run_id", run.ID, "task_id", run.TaskID, "node_id", derefString(run.NodeID), "timeout", timeout)
+		h.maybeUpdateTaskStatus(ctx, run.ID, model.RunStatusTimeout)
+		marked++
+	}
+	return marked
+}
+
+// runTimeout 从执行快照读取 Run 的执行时限，未设置时返回 defaultTimeout
+func runTimeout(run *model.Run, defaultTimeout time.Duration) time.Duration {
+	if snapshot, err := model.ParseRunSnapshot(run.Snapshot); err == nil && snapshot.TimeoutSeconds > 0 {
+		return time.Duration(snapshot.TimeoutSeconds) * time.Second
+	}
+	return defaultTimeout
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,123 @@
+package run
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// watchdogStore 在 mockRunStore 基础上实现 WatchdogStore
+type watchdogStore struct {
+	*mockRunStore
+}
+
+func (m *watchdogStore) ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error) {
+	var result []*model.Run
+	for _, r := range m.runs {
+		if r.Status == model.RunStatusAssigned || r.Status == model.RunStatusRunning {
+			result = append(result, r)
+		}
+	}
+	return result, nil
+}
+
+func (m *watchdogStore) MarkRunTimeout(ctx context.Context, id string, errMsg string) (bool, error) {
+	r, ok := m.runs[id]
+	if !ok || (r.Status != model.RunStatusAssigned && r.Status != model.RunStatusRunning) {
+		return false, nil
+	}
+	r.Status = model.RunStatusTimeout
+	r.Error = &errMsg
+	return true, nil
+}
+
+func TestCheckTimeouts(t *testing.T) {
+	store := &watchdogStore{newMockStore()}
+	now := time.Now()
+	started := func(ago time.Duration) *time.Time {
+		ts := now.Add(-ago)
+		return &ts
+	}
+	for _, id := range []string{"task-default", "task-short", "task-long", "task-assigned"} {
+		store.tasks[id] = &model.Task{ID: id, Status: model.TaskStatusInProgress}
+	}
+	store.runs["run-default"] = &model.Run{ID: "run-default", TaskID: "task-default", Status: model.RunStatusRunning,
+		StartedAt: started(3 * time.Hour), Snapshot: []byte(`{"task_id":"task-default"}`)}
+	store.runs["run-short"] = &model.Run{ID: "run-short", TaskID: "task-short", Status: model.RunStatusRunning,
+		StartedAt: started(5 * time.Minute), Snapshot: []byte(`{"timeout_seconds":60}`)}
+	store.runs["run-long"] = &model.Run{ID: "run-long", TaskID: "task-long", Status: model.RunStatusRunning,
+		StartedAt: started(5 * time.Minute), Snapshot: []byte(`{"timeout_seconds":600}`)}
+	store.runs["run-assigned"] = &model.Run{ID: "run-assigned", TaskID: "task-assigned", Status: model.RunStatusAssigned}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	var finished []string
+	handler.OnRunFinished(func(run *model.Run) { finished = append(finished, run.ID) })
+
+	if n := handler.checkTimeouts(context.Background(), now, 2*time.Hour); n != 2 {
+		t.Fatalf("checkTimeouts = %d, 期望 2", n)
+	}
+	for id, want := range map[string]model.RunStatus{
+		"run-default":  model.RunStatusTimeout,
+		"run-short":    model.RunStatusTimeout,
+		"run-long":     model.RunStatusRunning,
+		"run-assigned": model.RunStatusAssigned,
+	} {
+		if got := store.runs[id].Status; got != want {
+			t.Errorf("%s 状态 = %s, 期望 %s", id, got, want)
+		}
+	}
+	if store.tasks["task-short"].Status != model.TaskStatusFailed {
+		t.Errorf("超时 Run 的 Task 状态 = %s, 期望 failed", store.tasks["task-short"].Status)
+	}
+	if e := store.runs["run-short"].Error; e == nil || !strings.Contains(*e, "1m0s") {
+		t.Errorf("超时错误信息 = %v", e)
+	}
+	if len(finished) != 2 {
+		t.Errorf("OnRunFinished 回调 = %v, 期望 2 次", finished)
+	}
+
+	// 已标记的 Run 不会重复处理
+	if n := handler.checkTimeouts(context.Background(), now, 2*time.Hour); n != 0 {
+		t.Errorf("第二轮 checkTimeouts = %d, 期望 0", n)
+	}
+}
+
+func TestStartRun_SnapshotTimeout(t *testing.T) {
+	store := newMockStore()
+	store.tasks["task-timeout"] = &model.Task{ID: "task-timeout", Name: "t", Type: "qwen-code", Prompt: &model.Prompt{Content: "p"}, TimeoutSeconds: 90}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	run, err := handler.StartRun(context.Background(), "task-timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := runTimeout(run, time.Hour); got != 90*time.Second {
+		t.Errorf("runTimeout = %s, 期望 1m30s", got)
+	}
+}
+
+func TestUpdate_TimeoutNotOverwritten(t *testing.T) {
+	store := newMockStore()
+	store.runs["run-timeout"] = &model.Run{ID: "run-timeout", TaskID: "task-001", Status: model.RunStatusTimeout}
+
+	handler := NewHandlerWithInterfaces(store, nil)
+	mux := http.NewServeMux()
+	handler.RegisterRoutes(mux)
+
+	// 节点被终止后上报的 cancelled 不覆盖 timeout
+	req := httptest.NewRequest("PATCH", "/api/v1/runs/run-timeout", strings.NewReader(`{"status": "cancelled"}`))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("HTTP 状态码 = %d, 期望 200", w.Code)
+	}
+	if store.runs["run-timeout"].Status != model.RunStatusTimeout {
+		t.Errorf("Run 状态 = %s, 期望 timeout", store.runs["run-timeout"].Status)
+	}
+}
@@ -0,0 +1,23 @@
+// Package scheduler 适配器能力过滤
+package scheduler
+
+import (
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+)
+
+// filterByAdapter 保留适配器满足任务能力要求的节点
+//
+// 不同版本的 Agent CLI 输出格式不同，任务可通过 adapter-features / adapter-min-version
+// 标签声明所需能力；节点心跳上报各适配器的版本与能力（capacity.adapters）。
+// 未上报适配器信息的旧版本节点无法判断，保持可调度。
+func filterByAdapter(nodes []*model.Node, requirement *model.AdapterRequirement) []*model.Node {
+	matched := make([]*model.Node, 0, len(nodes))
+	for _, node := range nodes {
+		adapters, ok := nodemgr.GetNodeAdapters(node)
+		if !ok || requirement.SatisfiedBy(adapters) {
+			matched = append(matched, node)
+		}
+	}
+	return matched
+}
@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// createAdapterNode 创建上报适配器信息的测试节点
+func createAdapterNode(id string, adapters []model.AdapterInfo) *model.Node {
+	node := createTestNode(id, nil, 5)
+	node.Capacity, _ = json.Marshal(map[string]interface{}{"max_concurrent": 5, "adapters": adapters})
+	return node
+}
+
+func TestFilterByAdapter(t *testing.T) {
+	nodes := []*model.Node{
+		createAdapterNode("node-new", []model.AdapterInfo{
+			{Name: "claude-v1", Version: "1.0.33", Features: []string{"streaming", "tool_events", "usage"}},
+		}),
+		createAdapterNode("node-old", []model.AdapterInfo{
+			{Name: "claude-v1", Version: "0.2.9", Features: []string{"streaming", "tool_events", "usage"}},
+		}),
+		createAdapterNode("node-unknown", []model.AdapterInfo{
+			{Name: "claude-v1", Features: []string{"streaming"}},
+		}),
+		createAdapterNode("node-gemini", []model.AdapterInfo{
+			{Name: "gemini-v1", Version: "0.6.1", Features: []string{"tool_events", "usage"}},
+		}),
+		createTestNode("node-legacy", nil, 5), // 未上报适配器信息
+	}
+
+	tests := []struct {
+		name string
+		task *model.Task
+		want []string
+	}{
+		{
+			name: "要求能力",
+			task: &model.Task{Type: "claude", Labels: map[string]string{model.LabelAdapterFeatures: "usage, tool_events"}},
+			want: []string{"node-new", "node-old", "node-legacy"},
+		},
+		{
+			name: "要求最低版本_版本未知视为不满足",
+			task: &model.Task{Type: "claude", Labels: map[string]string{model.LabelAdapterMinVersion: "1.0.0"}},
+			want: []string{"node-new", "node-legacy"},
+		},
+		{
+			name: "适配器不匹配",
+			task: &model.Task{Type: "gemini", Labels: map[string]string{model.LabelAdapterFeatures: "streaming"}},
+			want: []string{"node-legacy"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requirement := model.AdapterRequirementFromTask(tt.task)
+			if requirement == nil {
+				t.Fatal("expected requirement")
+			}
+			var got []string
+			for _, n := range filterByAdapter(nodes, requirement) {
+				got = append(got, n.ID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+
+	if model.AdapterRequirementFromTask(&model.Task{Type: "claude", Labels: map[string]string{"env": "prod"}}) != nil {
+		t.Error("expected nil requirement without adapter labels")
+	}
+}
+
+// TestMatchLabelsIgnoresAdapterLabels 适配器能力标签不参与节点标签匹配
+func TestMatchLabelsIgnoresAdapterLabels(t *testing.T) {
+	node := createTestNode("node-1", map[string]string{"env": "prod"}, 5)
+	labels := map[string]string{"env": "prod", model.LabelAdapterFeatures: "usage", model.LabelAdapterMinVersion: "1.0.0"}
+	if !matchLabels(node, labels) {
+		t.Error("expected adapter labels to be ignored")
+	}
+}
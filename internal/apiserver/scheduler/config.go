@@ -19,20 +19,37 @@ type Config struct {
 
 	// Requeue 重新入队配置
 	Requeue RequeueConfig `yaml:"requeue"`
+
+	// Preemption 抢占配置
+	Preemption PreemptionConfig `yaml:"preemption"`
+
+	// Sharding 分片调度配置
+	Sharding ShardingConfig `yaml:"sharding"`
 }
 
 // StrategyConfig 调度策略配置
 type StrategyConfig struct {
 	// Default 默认策略名称
-	// 可选值: "label_match", "load_balance", "round_robin", "random"
+	// 可选值: "label_match", "load_balance", "least_loaded", "round_robin", "random"
+	// 任务间约束: "task_affinity", "anti_affinity", "spread"（任务未声明调度约束时不生效）
+	// 区域: "region"（任务未声明区域时不生效）
 	Default string `yaml:"default"`
 
 	// Chain 策略链（按优先级排序）
-	// 如果不配置，使用默认链：["affinity", "label_match"]
+	// 如果不配置，使用默认链：["affinity", "region", "task_affinity", "anti_affinity", "spread", "label_match"]
 	Chain []string `yaml:"chain"`
 
 	// LabelMatch 标签匹配策略配置
 	LabelMatch LabelMatchConfig `yaml:"label_match"`
+
+	// LeastLoaded 最低资源利用率策略配置
+	LeastLoaded LeastLoadedConfig `yaml:"least_loaded"`
+}
+
+// LeastLoadedConfig 最低资源利用率策略配置
+type LeastLoadedConfig struct {
+	// Window 节点指标的统计窗口（取窗口内采样的平均利用率）
+	Window time.Duration `yaml:"window"`
 }
 
 // LabelMatchConfig 标签匹配策略配置
@@ -65,16 +82,37 @@ type RequeueConfig struct {
 	OfflineThreshold time.Duration `yaml:"offline_threshold"`
 }
 
+// PreemptionConfig 抢占配置
+type PreemptionConfig struct {
+	// Enabled 是否允许 high 优先级 Run 抢占饱和节点上尚未开始执行的 low 优先级 Run
+	Enabled bool `yaml:"enabled"`
+}
+
+// DefaultHashSlots 默认哈希槽数量
+const DefaultHashSlots = 1024
+
+// ShardingConfig 分片调度配置
+type ShardingConfig struct {
+	// Workers 并行调度 worker 数，1 表示逐条串行调度
+	Workers int `yaml:"workers"`
+
+	// Slots 哈希槽数量，Run 按 ID 哈希到槽位，每个 worker 拥有 slot % Workers == 序号的槽位
+	Slots int `yaml:"slots"`
+}
+
 // DefaultConfig 返回默认配置
 func DefaultConfig() *Config {
 	return &Config{
 		NodeID: "scheduler-default",
 		Strategy: StrategyConfig{
 			Default: "label_match",
-			Chain:   []string{"affinity", "label_match"},
+			Chain:   []string{"affinity", "region", "task_affinity", "anti_affinity", "spread", "label_match"},
 			LabelMatch: LabelMatchConfig{
 				LoadBalance: true,
 			},
+			LeastLoaded: LeastLoadedConfig{
+				Window: DefaultLeastLoadedWindow,
+			},
 		},
 		Redis: RedisConfig{
 			ReadTimeout: 5 * time.Second,
@@ -87,6 +125,10 @@ func DefaultConfig() *Config {
 		Requeue: RequeueConfig{
 			OfflineThreshold: 30 * time.Second,
 		},
+		Sharding: ShardingConfig{
+			Workers: 1,
+			Slots:   DefaultHashSlots,
+		},
 	}
 }
 
@@ -99,7 +141,10 @@ func (c *Config) Validate() error {
 		c.Strategy.Default = "label_match"
 	}
 	if len(c.Strategy.Chain) == 0 {
-		c.Strategy.Chain = []string{"direct", "affinity", "label_match"}
+		c.Strategy.Chain = []string{"direct", "affinity", "region", "task_affinity", "anti_affinity", "spread", "label_match"}
+	}
+	if c.Strategy.LeastLoaded.Window <= 0 {
+		c.Strategy.LeastLoaded.Window = DefaultLeastLoadedWindow
 	}
 	if c.Redis.ReadTimeout == 0 {
 		c.Redis.ReadTimeout = 5 * time.Second
@@ -116,6 +161,15 @@ func (c *Config) Validate() error {
 	if c.Requeue.OfflineThreshold == 0 {
 		c.Requeue.OfflineThreshold = 30 * time.Second
 	}
+	if c.Sharding.Workers <= 0 {
+		c.Sharding.Workers = 1
+	}
+	if c.Sharding.Slots <= 0 {
+		c.Sharding.Slots = DefaultHashSlots
+	}
+	if c.Sharding.Slots < c.Sharding.Workers {
+		c.Sharding.Slots = c.Sharding.Workers
+	}
 	return nil
 }
 
@@ -129,10 +183,20 @@ func (c *Config) BuildStrategyChain() *StrategyChain {
 			chain.Add(NewDirectStrategy())
 		case "affinity":
 			chain.Add(NewAffinityStrategy())
+		case "region":
+			chain.Add(NewRegionStrategy())
+		case "task_affinity":
+			chain.Add(NewTaskAffinityStrategy())
+		case "anti_affinity":
+			chain.Add(NewAntiAffinityStrategy())
+		case "spread":
+			chain.Add(NewSpreadStrategy())
 		case "label_match":
 			chain.Add(NewLabelMatchStrategy(c.Strategy.LabelMatch.LoadBalance))
 		case "load_balance":
 			chain.Add(NewLoadBalanceStrategy())
+		case "least_loaded":
+			chain.Add(NewLeastLoadedStrategy(c.Strategy.LeastLoaded.Window))
 		case "round_robin":
 			chain.Add(NewRoundRobinStrategy())
 		case "random":
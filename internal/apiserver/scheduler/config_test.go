@@ -65,4 +65,13 @@ func TestConfig_Validate(t *testing.T) {
 	if cfg.Redis.ReadTimeout == 0 {
 		t.Error("expected Redis.ReadTimeout to be set")
 	}
+	if cfg.Sharding.Workers != 1 || cfg.Sharding.Slots != DefaultHashSlots {
+		t.Errorf("expected sharding defaults 1/%d, got %d/%d", DefaultHashSlots, cfg.Sharding.Workers, cfg.Sharding.Slots)
+	}
+
+	cfg = &Config{Sharding: ShardingConfig{Workers: 8, Slots: 4}}
+	cfg.Validate()
+	if cfg.Sharding.Slots != 8 {
+		t.Errorf("expected slots to be raised to workers, got %d", cfg.Sharding.Slots)
+	}
 }
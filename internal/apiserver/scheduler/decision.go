@@ -0,0 +1,68 @@
+// Package scheduler 调度决策审计
+//
+// 每轮调度为 Run 记录一条决策：结果、做出选择的策略、考察的候选节点及各自的淘汰原因
+// （适配器能力不满足、标签不匹配、污点未容忍、不在允许的区域、不满足必须的任务间约束、并发已满、策略链未选中）。Run 长时间停留在 queued 时，
+// 可通过 GET /api/v1/runs/{id} 返回的 scheduling_decisions 查看原因。
+//
+// 连续相同的决策由存储层合并为一条并累加次数，记录失败只写日志，不影响调度。
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+)
+
+// evaluateCandidates 逐个节点给出淘汰原因
+//
+// 按适配器能力 → 标签 → 污点 → 任务间约束 → 容量的顺序判断，满足全部约束的节点为 not_selected，
+// selectedID 对应的节点为 selected。constrained 为被约束型策略过滤的节点及策略名（见 StrategyChain.rejections）。
+func evaluateCandidates(nodes []*model.Node, requirement *model.AdapterRequirement, task *model.Task,
+	constrained map[string]string, running map[string]int, selectedID string) []model.SchedulingCandidate {
+	var taskLabels map[string]string
+	if task != nil {
+		taskLabels = task.Labels
+	}
+	candidates := make([]model.SchedulingCandidate, 0, len(nodes))
+	for _, node := range nodes {
+		c := model.SchedulingCandidate{
+			NodeID:        node.ID,
+			Running:       running[node.ID],
+			MaxConcurrent: nodemgr.GetNodeMaxConcurrent(node),
+		}
+		switch {
+		case node.ID == selectedID:
+			c.Rejected = model.SchedulingSelected
+		case requirement != nil && len(filterByAdapter([]*model.Node{node}, requirement)) == 0:
+			c.Rejected = model.SchedulingRejectAdapter
+		case !matchLabels(node, taskLabels):
+			c.Rejected = model.SchedulingRejectLabel
+		case !toleratesNode(node, task):
+			c.Rejected = model.SchedulingRejectTaint
+		case constrained[node.ID] != "":
+			c.Rejected = constrained[node.ID]
+		case c.Running >= c.MaxConcurrent:
+			c.Rejected = model.SchedulingRejectCapacity
+		default:
+			c.Rejected = model.SchedulingRejectStrategy
+		}
+		candidates = append(candidates, c)
+	}
+	return candidates
+}
+
+// recordDecision 记录调度决策，失败只写日志
+func (s *Scheduler) recordDecision(ctx context.Context, d *model.SchedulingDecision) {
+	now := time.Now()
+	d.CreatedAt = now
+	d.UpdatedAt = now
+	if d.Candidates == nil {
+		d.Candidates = []model.SchedulingCandidate{}
+	}
+	if err := s.store.RecordSchedulingDecision(ctx, d); err != nil {
+		slog.ErrorContext(ctx, "scheduler.decision.record_failed", "run_id", d.RunID, "outcome", d.Outcome, "error", err)
+	}
+}
@@ -0,0 +1,138 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestEvaluateCandidates(t *testing.T) {
+	gpu := createTestNode("node-gpu", map[string]string{"gpu": "true"}, 2)
+	full := createTestNode("node-full", map[string]string{"gpu": "true"}, 1)
+	cpu := createTestNode("node-cpu", nil, 2)
+	old := createAdapterNode("node-old", []model.AdapterInfo{{Name: "claude-v1", Version: "0.2.9"}})
+	idle := createTestNode("node-idle", map[string]string{"gpu": "true"}, 2)
+	tainted := createTestNode("node-tainted", map[string]string{"gpu": "true"}, 2)
+	tainted.Taints = []model.NodeTaint{{Key: "dedicated", Value: "ml", Effect: model.TaintEffectNoSchedule}}
+
+	requirement := &model.AdapterRequirement{Adapter: "claude-v1", MinVersion: "1.0.0"}
+	running := map[string]int{"node-full": 1}
+	task := createTestTask("task-1", map[string]string{"gpu": "true"})
+	got := evaluateCandidates([]*model.Node{gpu, full, cpu, old, idle, tainted}, requirement, task, nil, running, "node-gpu")
+
+	want := map[string]string{
+		"node-gpu":     model.SchedulingSelected,
+		"node-full":    model.SchedulingRejectCapacity,
+		"node-cpu":     model.SchedulingRejectLabel,
+		"node-old":     model.SchedulingRejectAdapter,
+		"node-idle":    model.SchedulingRejectStrategy,
+		"node-tainted": model.SchedulingRejectTaint,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("candidates = %+v, want %d", got, len(want))
+	}
+	for _, c := range got {
+		if c.Rejected != want[c.NodeID] {
+			t.Errorf("%s rejected = %q, want %q", c.NodeID, c.Rejected, want[c.NodeID])
+		}
+	}
+	if got[1].Running != 1 || got[1].MaxConcurrent != 1 {
+		t.Errorf("node-full = %+v, want running=1 max=1", got[1])
+	}
+}
+
+func TestScheduleRun_RecordsDecision(t *testing.T) {
+	t.Run("抢占分配", func(t *testing.T) {
+		s, store, _ := newPreemptFixture(true, 0)
+		if err := s.scheduleRun(context.Background(), store.runs["run-high"]); err != nil {
+			t.Fatalf("scheduleRun error: %v", err)
+		}
+		if len(store.decisions) != 1 {
+			t.Fatalf("decisions = %d, want 1", len(store.decisions))
+		}
+		d := store.decisions[0]
+		if d.Outcome != model.SchedulingOutcomeAssigned || d.Strategy != "preemption" || d.NodeID != "node-1" {
+			t.Errorf("decision = %+v, want assigned to node-1 by preemption", d)
+		}
+		if len(d.Candidates) != 1 || d.Candidates[0].Rejected != model.SchedulingSelected {
+			t.Errorf("candidates = %+v, want node-1 selected", d.Candidates)
+		}
+	})
+
+	t.Run("节点已满", func(t *testing.T) {
+		s, store, _ := newPreemptFixture(false, 0)
+		if err := s.scheduleRun(context.Background(), store.runs["run-high"]); err != nil {
+			t.Fatalf("scheduleRun error: %v", err)
+		}
+		if len(store.decisions) != 1 {
+			t.Fatalf("decisions = %d, want 1", len(store.decisions))
+		}
+		d := store.decisions[0]
+		if d.Outcome != model.SchedulingOutcomeNoMatch || d.Reason != "no_strategy_matched" {
+			t.Errorf("decision = %+v, want no_match", d)
+		}
+		if len(d.Candidates) != 1 || d.Candidates[0].Rejected != model.SchedulingRejectCapacity || d.Candidates[0].Running != 1 {
+			t.Errorf("candidates = %+v, want node-1 capacity_full", d.Candidates)
+		}
+	})
+
+	t.Run("预算耗尽", func(t *testing.T) {
+		s, store, _ := newPreemptFixture(false, 0)
+		s.SetBudgetGate(&denyBudget{})
+		if err := s.scheduleRun(context.Background(), store.runs["run-high"]); err != nil {
+			t.Fatalf("scheduleRun error: %v", err)
+		}
+		if len(store.decisions) != 1 || store.decisions[0].Outcome != model.SchedulingOutcomeBudgetHold {
+			t.Errorf("decisions = %+v, want budget_hold", store.decisions)
+		}
+	})
+
+	t.Run("快照不合法", func(t *testing.T) {
+		s, store, _ := newPreemptFixture(true, 0)
+		run := store.runs["run-high"]
+		run.Snapshot = json.RawMessage(`{"agent":{"type":"qwen-code"}}`)
+		if err := s.scheduleRun(context.Background(), run); err != nil {
+			t.Fatalf("scheduleRun error: %v", err)
+		}
+		if run.Status != model.RunStatusFailed || run.Error == nil || *run.Error != "invalid run snapshot: prompt: is required" {
+			t.Errorf("run = %+v, want failed with snapshot error", run)
+		}
+		if len(store.decisions) != 1 || store.decisions[0].Outcome != model.SchedulingOutcomeInvalid {
+			t.Errorf("decisions = %+v, want invalid", store.decisions)
+		}
+		if store.runs["run-low"].Status != model.RunStatusAssigned {
+			t.Error("快照不合法的 Run 不应抢占其他 Run")
+		}
+	})
+
+	t.Run("旧版本快照", func(t *testing.T) {
+		s, store, _ := newPreemptFixture(false, 0)
+		store.runs["run-low"].Status = model.RunStatusDone
+		run := store.runs["run-high"]
+		run.Snapshot = json.RawMessage(`{"type":"qwen-code","prompt":{"content":"p"}}`)
+		if err := s.scheduleRun(context.Background(), run); err != nil {
+			t.Fatalf("scheduleRun error: %v", err)
+		}
+		if run.Status != model.RunStatusAssigned {
+			t.Errorf("status = %s, want assigned", run.Status)
+		}
+	})
+}
+
+func TestSchedulingDecisionSignature(t *testing.T) {
+	a := &model.SchedulingDecision{Outcome: model.SchedulingOutcomeNoMatch, Candidates: []model.SchedulingCandidate{
+		{NodeID: "node-1", Running: 1, MaxConcurrent: 1, Rejected: model.SchedulingRejectCapacity},
+	}}
+	b := &model.SchedulingDecision{Outcome: model.SchedulingOutcomeNoMatch, Candidates: []model.SchedulingCandidate{
+		{NodeID: "node-1", Running: 3, MaxConcurrent: 2, Rejected: model.SchedulingRejectCapacity},
+	}}
+	if a.ComputeSignature() != b.ComputeSignature() {
+		t.Error("running counts should not change the signature")
+	}
+	b.Candidates[0].Rejected = model.SchedulingRejectLabel
+	if a.ComputeSignature() == b.ComputeSignature() {
+		t.Error("different rejection reasons should change the signature")
+	}
+}
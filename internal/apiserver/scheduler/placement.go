@@ -0,0 +1,151 @@
+// Package scheduler 任务间放置关系
+//
+// 任务声明调度约束（model.TaskScheduling）时，调度前从存储加载相关任务的 Run 分布，
+// 供 task_affinity、anti_affinity、spread 策略使用；任务声明区域时加载区域定义，供 region 策略使用。
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+)
+
+// maxPlacementTasks 加载放置关系时最多查询的相关任务数
+const maxPlacementTasks = 50
+
+// Placement 任务间放置关系
+//
+// 字段说明：
+//   - AffinityNodes：亲和任务的 Run 执行过的节点
+//   - TaskRuns：同一任务其他活跃 Run（assigned / running）在各节点的数量
+//   - GroupRuns：任务组（该任务及同一父任务下的任务）活跃 Run 在各节点的数量
+type Placement struct {
+	AffinityNodes map[string]bool
+	TaskRuns      map[string]int
+	GroupRuns     map[string]int
+}
+
+// loadPlacement 加载 Run 所属任务的放置关系，任务未声明调度约束时返回 nil
+//
+// 查询失败只写日志，按已加载的部分继续调度。
+func (s *Scheduler) loadPlacement(ctx context.Context, run *model.Run, task *model.Task) *Placement {
+	if task == nil || task.Scheduling == nil {
+		return nil
+	}
+	sc := task.Scheduling
+	p := &Placement{
+		AffinityNodes: make(map[string]bool),
+		TaskRuns:      make(map[string]int),
+		GroupRuns:     make(map[string]int),
+	}
+
+	// 同一任务的其他活跃 Run
+	if sc.AntiAffinity != nil || sc.Spread != nil {
+		for _, r := range s.listTaskRuns(ctx, task.ID) {
+			if r.ID == run.ID || !isActiveRun(r) {
+				continue
+			}
+			p.TaskRuns[*r.NodeID]++
+			p.GroupRuns[*r.NodeID]++
+		}
+	}
+
+	// 相关任务：亲和指定的任务与同一父任务下的任务
+	affinity := make(map[string]bool)
+	group := make(map[string]bool)
+	if sc.Affinity != nil {
+		for _, id := range sc.Affinity.TaskIDs {
+			affinity[id] = true
+		}
+	}
+	if task.ParentID != nil && ((sc.Affinity != nil && sc.Affinity.Siblings) || sc.Spread != nil) {
+		siblings, err := s.store.ListSubTasks(ctx, *task.ParentID)
+		if err != nil {
+			slog.ErrorContext(ctx, "scheduler.placement.load_failed", "task_id", task.ID, "parent_id", *task.ParentID, "error", err)
+		}
+		for _, sibling := range siblings {
+			if sibling.ID == task.ID {
+				continue
+			}
+			if sc.Affinity != nil && sc.Affinity.Siblings {
+				affinity[sibling.ID] = true
+			}
+			if sc.Spread != nil {
+				group[sibling.ID] = true
+			}
+		}
+	}
+	delete(affinity, task.ID)
+
+	queried := 0
+	for _, ids := range []map[string]bool{affinity, group} {
+		for id := range ids {
+			if queried >= maxPlacementTasks {
+				slog.InfoContext(ctx, "scheduler.placement.truncated", "task_id", task.ID, "limit", maxPlacementTasks)
+				return p
+			}
+			runs := s.listTaskRuns(ctx, id)
+			queried++
+			for _, r := range runs {
+				if r.NodeID == nil || *r.NodeID == "" {
+					continue
+				}
+				if affinity[id] {
+					p.AffinityNodes[*r.NodeID] = true
+				}
+				if group[id] && isActiveRun(r) {
+					p.GroupRuns[*r.NodeID]++
+				}
+			}
+			// 同时属于两类的任务只查询一次
+			delete(affinity, id)
+			delete(group, id)
+		}
+	}
+	return p
+}
+
+// listTaskRuns 列出任务的 Run，失败只写日志
+func (s *Scheduler) listTaskRuns(ctx context.Context, taskID string) []*model.Run {
+	runs, err := s.store.ListRunsByTask(ctx, taskID)
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler.placement.load_failed", "task_id", taskID, "error", err)
+		return nil
+	}
+	return runs
+}
+
+// isActiveRun Run 是否已分配节点且尚未结束
+func isActiveRun(r *model.Run) bool {
+	if r.NodeID == nil || *r.NodeID == "" {
+		return false
+	}
+	return r.Status == model.RunStatusAssigned || r.Status == model.RunStatusRunning
+}
+
+// eligibleNode 节点是否满足任务标签要求、容忍节点污点且有剩余容量
+func eligibleNode(req *ScheduleRequest, node *model.Node) bool {
+	if !matchLabels(node, getTaskLabelsFromRequest(req)) || !toleratesNode(node, req.Task) {
+		return false
+	}
+	return req.NodeRunning[node.ID] < nodemgr.GetNodeMaxConcurrent(node)
+}
+
+// loadRegion 加载任务声明的区域，任务未声明区域时返回 nil
+//
+// 区域未定义或查询失败时按默认回退策略（any）处理，查询失败只写日志。
+func (s *Scheduler) loadRegion(ctx context.Context, task *model.Task) *model.Region {
+	if task == nil || task.Region == "" {
+		return nil
+	}
+	region, err := s.store.GetRegion(ctx, task.Region)
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler.region.load_failed", "task_id", task.ID, "region", task.Region, "error", err)
+	}
+	if region == nil {
+		return &model.Region{ID: task.Region}
+	}
+	return region
+}
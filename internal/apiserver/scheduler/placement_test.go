@@ -0,0 +1,93 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// placementStore 提供任务与 Run 查询的最小存储
+type placementStore struct {
+	storage.PersistentStore
+	tasks []*model.Task
+	runs  []*model.Run
+}
+
+func (m *placementStore) ListSubTasks(ctx context.Context, parentID string) ([]*model.Task, error) {
+	var tasks []*model.Task
+	for _, t := range m.tasks {
+		if t.ParentID != nil && *t.ParentID == parentID {
+			tasks = append(tasks, t)
+		}
+	}
+	return tasks, nil
+}
+
+func (m *placementStore) ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run, error) {
+	var runs []*model.Run
+	for _, r := range m.runs {
+		if r.TaskID == taskID {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
+
+func placementRun(id, taskID, nodeID string, status model.RunStatus) *model.Run {
+	r := &model.Run{ID: id, TaskID: taskID, Status: status}
+	if nodeID != "" {
+		r.NodeID = &nodeID
+	}
+	return r
+}
+
+func TestLoadPlacement(t *testing.T) {
+	parent := "parent"
+	self := &model.Task{ID: "task-1", ParentID: &parent}
+	store := &placementStore{
+		tasks: []*model.Task{
+			self,
+			{ID: "task-2", ParentID: &parent},
+			{ID: "other"},
+		},
+		runs: []*model.Run{
+			placementRun("run-cur", "task-1", "", model.RunStatusQueued),
+			placementRun("run-1", "task-1", "node-1", model.RunStatusRunning),
+			placementRun("run-2", "task-2", "node-2", model.RunStatusDone),
+			placementRun("run-3", "task-2", "node-3", model.RunStatusAssigned),
+			placementRun("run-4", "other", "node-4", model.RunStatusRunning),
+		},
+	}
+	s := &Scheduler{store: store}
+	run := store.runs[0]
+
+	if p := s.loadPlacement(context.Background(), run, &model.Task{ID: "task-1"}); p != nil {
+		t.Errorf("placement = %+v, want nil without scheduling", p)
+	}
+
+	self.Scheduling = &model.TaskScheduling{
+		Affinity:     &model.TaskAffinity{Siblings: true, TaskIDs: []string{"other"}},
+		AntiAffinity: &model.TaskAntiAffinity{},
+		Spread:       &model.SpreadConstraint{LabelKey: "zone"},
+	}
+	p := s.loadPlacement(context.Background(), run, self)
+	if p == nil {
+		t.Fatal("placement = nil")
+	}
+	for _, id := range []string{"node-2", "node-3", "node-4"} {
+		if !p.AffinityNodes[id] {
+			t.Errorf("AffinityNodes missing %s: %v", id, p.AffinityNodes)
+		}
+	}
+	if p.AffinityNodes["node-1"] {
+		t.Errorf("AffinityNodes should not include own runs: %v", p.AffinityNodes)
+	}
+	if len(p.TaskRuns) != 1 || p.TaskRuns["node-1"] != 1 {
+		t.Errorf("TaskRuns = %v, want node-1:1", p.TaskRuns)
+	}
+	if len(p.GroupRuns) != 2 || p.GroupRuns["node-1"] != 1 || p.GroupRuns["node-3"] != 1 {
+		t.Errorf("GroupRuns = %v, want node-1:1 node-3:1", p.GroupRuns)
+	}
+}
@@ -0,0 +1,103 @@
+// Package scheduler 优先级抢占
+//
+// 当 high 优先级 Run 找不到空闲节点时（需开启 preemption.enabled），调度器在饱和节点上
+// 寻找已分配但尚未开始执行的 low 优先级 Run，将其退回 queued 并重新入队，
+// 把腾出的槽位分配给 high 优先级 Run。
+//
+// 约束：
+//   - 仅抢占 assigned 状态且没有任何事件的 Run（NodeManager 尚未开始执行）
+//   - 抢占后的节点仍需通过策略链（标签、亲和性等约束不会因抢占而放宽）
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+)
+
+// tryPreempt 尝试为 high 优先级 Run 抢占一个节点槽位
+//
+// 返回被选中的节点与原因；无可抢占的 Run 时返回 nil。
+func (s *Scheduler) tryPreempt(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	for _, node := range req.CandidateNodes {
+		if req.NodeRunning[node.ID] < nodemgr.GetNodeMaxConcurrent(node) {
+			continue // 节点未饱和，策略链未选中说明不满足约束
+		}
+
+		victim := s.findPreemptibleRun(ctx, node.ID)
+		if victim == nil {
+			continue
+		}
+
+		// 假设腾出一个槽位后，该节点是否满足策略链约束
+		running := make(map[string]int, len(req.NodeRunning))
+		for k, v := range req.NodeRunning {
+			running[k] = v
+		}
+		running[node.ID]--
+		selected, _ := s.strategyChain.SelectNode(ctx, &ScheduleRequest{
+			Run:            req.Run,
+			Task:           req.Task,
+			CandidateNodes: []*model.Node{node},
+			NodeRunning:    running,
+			PreferredNode:  req.PreferredNode,
+			Placement:      req.Placement,
+			Region:         req.Region,
+		})
+		if selected == nil {
+			continue
+		}
+
+		if err := s.store.ResetRunToQueued(ctx, victim.ID); err != nil {
+			slog.ErrorContext(ctx, "scheduler.preempt.failed", "run_id", req.Run.ID, "victim", victim.ID, "error", err)
+			continue
+		}
+		s.requeuePreempted(ctx, victim)
+		slog.InfoContext(ctx, "scheduler.run.preempted", "run_id", victim.ID, "node_id", node.ID, "preempted_by", req.Run.ID)
+		return selected, "preemption"
+	}
+	return nil, ""
+}
+
+// findPreemptibleRun 查找节点上可被抢占的 Run（最晚分配的 low 优先级且未开始执行的 Run）
+func (s *Scheduler) findPreemptibleRun(ctx context.Context, nodeID string) *model.Run {
+	runs, err := s.store.ListRunsByNode(ctx, nodeID)
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler.preempt.list.failed", "node_id", nodeID, "error", err)
+		return nil
+	}
+
+	// ListRunsByNode 按创建时间升序，倒序遍历优先抢占最新的 Run
+	for i := len(runs) - 1; i >= 0; i-- {
+		run := runs[i]
+		if run.Status != model.RunStatusAssigned || run.Priority != model.PriorityLow {
+			continue
+		}
+		cnt, err := s.store.CountEventsByRun(ctx, run.ID)
+		if err != nil || cnt > 0 {
+			// 已有事件说明 NodeManager 已经开始执行，不抢占
+			continue
+		}
+		return run
+	}
+	return nil
+}
+
+// requeuePreempted 将被抢占的 Run 重新加入调度队列（失败时由保底轮询处理）
+func (s *Scheduler) requeuePreempted(ctx context.Context, run *model.Run) {
+	if s.schedulerQueue == nil {
+		return
+	}
+	var err error
+	if pq, ok := s.schedulerQueue.(queue.PrioritySchedulerQueue); ok {
+		_, err = pq.ScheduleRunWithPriority(ctx, run.ID, run.TaskID, string(run.Priority))
+	} else {
+		_, err = s.schedulerQueue.ScheduleRun(ctx, run.ID, run.TaskID)
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "scheduler.preempt.requeue.failed", "run_id", run.ID, "error", err)
+	}
+}
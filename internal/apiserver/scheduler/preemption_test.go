@@ -0,0 +1,180 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+	"agents-admin/internal/shared/storage"
+)
+
+// preemptStore 内存存储（仅实现调度与抢占所需方法，其余方法未实现会 panic）
+type preemptStore struct {
+	storage.PersistentStore
+	nodes     []*model.Node
+	runs      map[string]*model.Run
+	events    map[string]int
+	decisions []*model.SchedulingDecision
+}
+
+func (m *preemptStore) ListAllNodes(ctx context.Context) ([]*model.Node, error) {
+	return m.nodes, nil
+}
+func (m *preemptStore) ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error) {
+	return nil, nil
+}
+func (m *preemptStore) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	return nil, nil
+}
+func (m *preemptStore) ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error) {
+	var runs []*model.Run
+	for _, r := range m.runs {
+		if r.NodeID != nil && *r.NodeID == nodeID && (r.Status == model.RunStatusAssigned || r.Status == model.RunStatusRunning) {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
+func (m *preemptStore) CountEventsByRun(ctx context.Context, runID string) (int, error) {
+	return m.events[runID], nil
+}
+func (m *preemptStore) ResetRunToQueued(ctx context.Context, id string) error {
+	m.runs[id].Status = model.RunStatusQueued
+	m.runs[id].NodeID = nil
+	return nil
+}
+func (m *preemptStore) UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error {
+	m.runs[id].Status = status
+	m.runs[id].NodeID = nodeID
+	return nil
+}
+func (m *preemptStore) UpdateRunError(ctx context.Context, id string, errMsg string) error {
+	m.runs[id].Error = &errMsg
+	return nil
+}
+func (m *preemptStore) UpdateTaskStatus(ctx context.Context, id string, status model.TaskStatus) error {
+	return nil
+}
+func (m *preemptStore) RecordSchedulingDecision(ctx context.Context, d *model.SchedulingDecision) error {
+	m.decisions = append(m.decisions, d)
+	return nil
+}
+
+// preemptQueue 记录重新入队的 Run 及其优先级
+type preemptQueue struct {
+	queue.NoOpQueue
+	requeued map[string]string
+}
+
+func (q *preemptQueue) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
+	q.requeued[runID] = priority
+	return "", nil
+}
+
+func newPreemptFixture(enabled bool, lowEvents int) (*Scheduler, *preemptStore, *preemptQueue) {
+	node := createTestNode("node-1", nil, 1)
+	now := time.Now()
+	node.LastHeartbeat = &now
+	nodeID := node.ID
+
+	store := &preemptStore{
+		nodes: []*model.Node{node},
+		runs: map[string]*model.Run{
+			"run-low":  {ID: "run-low", Status: model.RunStatusAssigned, NodeID: &nodeID, Priority: model.PriorityLow, Snapshot: testSnapshot},
+			"run-high": {ID: "run-high", Status: model.RunStatusQueued, Priority: model.PriorityHigh, Snapshot: testSnapshot},
+		},
+		events: map[string]int{"run-low": lowEvents},
+	}
+	q := &preemptQueue{requeued: make(map[string]string)}
+
+	config := DefaultConfig()
+	config.Preemption.Enabled = enabled
+	return NewSchedulerWithConfig(store, q, nil, config), store, q
+}
+
+func TestScheduleRun_PreemptsLowPriority(t *testing.T) {
+	s, store, q := newPreemptFixture(true, 0)
+	if err := s.scheduleRun(context.Background(), store.runs["run-high"]); err != nil {
+		t.Fatalf("scheduleRun error: %v", err)
+	}
+
+	high, low := store.runs["run-high"], store.runs["run-low"]
+	if high.Status != model.RunStatusAssigned || high.NodeID == nil || *high.NodeID != "node-1" {
+		t.Errorf("high 优先级 Run 应分配到 node-1: status=%s node=%v", high.Status, high.NodeID)
+	}
+	if low.Status != model.RunStatusQueued || low.NodeID != nil {
+		t.Errorf("low 优先级 Run 应被退回 queued: status=%s", low.Status)
+	}
+	if q.requeued["run-low"] != queue.PriorityLow {
+		t.Errorf("被抢占的 Run 应以 low 优先级重新入队, got %v", q.requeued)
+	}
+}
+
+func TestScheduleRun_NoPreemption(t *testing.T) {
+	tests := []struct {
+		name      string
+		enabled   bool
+		lowEvents int
+	}{
+		{"抢占未开启", false, 0},
+		{"low Run 已开始执行", true, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, store, q := newPreemptFixture(tt.enabled, tt.lowEvents)
+			if err := s.scheduleRun(context.Background(), store.runs["run-high"]); err != nil {
+				t.Fatalf("scheduleRun error: %v", err)
+			}
+			if store.runs["run-high"].Status != model.RunStatusQueued {
+				t.Errorf("high Run 不应被分配, status=%s", store.runs["run-high"].Status)
+			}
+			if store.runs["run-low"].Status != model.RunStatusAssigned || len(q.requeued) != 0 {
+				t.Errorf("low Run 不应被抢占, status=%s requeued=%v", store.runs["run-low"].Status, q.requeued)
+			}
+		})
+	}
+}
+
+// denyBudget 拒绝所有 Run 的预算检查
+type denyBudget struct{ checked []string }
+
+func (d *denyBudget) Admit(ctx context.Context, run *model.Run, task *model.Task) bool {
+	d.checked = append(d.checked, run.ID)
+	return false
+}
+
+func TestScheduleRun_BudgetHold(t *testing.T) {
+	s, store, _ := newPreemptFixture(false, 0)
+	store.runs["run-low"].Status = model.RunStatusDone
+	store.runs["run-low"].NodeID = nil
+	gate := &denyBudget{}
+	s.SetBudgetGate(gate)
+
+	if err := s.scheduleRun(context.Background(), store.runs["run-high"]); err != nil {
+		t.Fatalf("scheduleRun error: %v", err)
+	}
+	if store.runs["run-high"].Status != model.RunStatusQueued {
+		t.Errorf("预算耗尽时 Run 应保持 queued, status=%s", store.runs["run-high"].Status)
+	}
+	if len(gate.checked) != 1 || gate.checked[0] != "run-high" {
+		t.Errorf("应检查 run-high 的预算, got %v", gate.checked)
+	}
+}
+
+func TestReserveHighPrioritySlots(t *testing.T) {
+	reserved := createTestNode("node-1", nil, 3)
+	reserved.Capacity = []byte(`{"max_concurrent":3,"reserved_high_priority":1}`)
+	plain := createTestNode("node-2", nil, 3)
+	nodes := []*model.Node{reserved, plain}
+
+	got := reserveHighPrioritySlots(map[string]int{"node-1": 1}, nodes, model.PriorityNormal)
+	if got["node-1"] != 2 || got["node-2"] != 0 {
+		t.Errorf("normal run: running = %v, want reserved slot counted on node-1", got)
+	}
+	got = reserveHighPrioritySlots(map[string]int{"node-1": 1}, nodes, model.PriorityHigh)
+	if got["node-1"] != 1 {
+		t.Errorf("high run: running = %v, reserved slot should be available", got)
+	}
+}
@@ -9,7 +9,7 @@ package scheduler
 
 import (
 	"context"
-	"log"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -33,8 +33,12 @@ type Scheduler struct {
 	nodeQueue      queue.NodeRunQueue      // 节点队列（分配 Run 到节点）
 	nodeManager    *node.Manager
 	strategyChain  *StrategyChain
+	budgetGate     BudgetGate        // 预算检查（可选，nil 时不限制）
+	accountGate    AccountGate       // 账号池额度检查（可选，nil 时不限制）
+	nodeMetrics    NodeMetricsSource // 节点指标窗口（可选，least_loaded 策略使用）
+	selectMu       sync.Mutex        // 串行化节点选择与运行计数递增（分片调度时多个 worker 并发）
 
-	mu             sync.Mutex    // 保护 running 状态
+	mu             sync.Mutex    // 保护 running 状态与运行中可修改的配置（策略链、抢占开关）
 	running        bool          // 调度器运行状态
 	stopCh         chan struct{} // 停止信号通道
 	fallbackEvery  time.Duration
@@ -100,6 +104,69 @@ func (s *Scheduler) SetFallbackConfig(every time.Duration, staleThreshold time.D
 	}
 }
 
+// SetSharding 设置分片调度的 worker 数与哈希槽数量（<= 0 时使用默认值）
+func (s *Scheduler) SetSharding(workers, slots int) {
+	s.config.Sharding = ShardingConfig{Workers: workers, Slots: slots}
+	s.config.Validate()
+}
+
+// SetStrategy 设置调度策略配置并重建策略链
+//
+// 可在调度器运行中调用（配置热加载）：新策略链在节点选择的临界区之外替换，正在进行的选择使用旧策略链完成。
+func (s *Scheduler) SetStrategy(cfg StrategyConfig) {
+	s.selectMu.Lock()
+	defer s.selectMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config.Strategy = cfg
+	s.config.Validate()
+	s.strategyChain = s.config.BuildStrategyChain()
+	s.SetNodeMetrics(s.nodeMetrics)
+}
+
+// SetNodeMetrics 设置节点指标窗口（供 least_loaded 策略计算资源利用率）
+func (s *Scheduler) SetNodeMetrics(source NodeMetricsSource) {
+	s.nodeMetrics = source
+	for _, strategy := range s.strategyChain.Strategies() {
+		if ll, ok := strategy.(*LeastLoadedStrategy); ok {
+			ll.SetMetricsSource(source)
+		}
+	}
+}
+
+// SetPreemption 设置是否启用优先级抢占（可在调度器运行中调用）
+func (s *Scheduler) SetPreemption(enabled bool) {
+	s.selectMu.Lock()
+	defer s.selectMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.config.Preemption.Enabled = enabled
+}
+
+// BudgetGate 调度前的预算检查
+//
+// Admit 返回 false 时 Run 保持 queued，由保底轮询在预算恢复后重新调度。
+type BudgetGate interface {
+	Admit(ctx context.Context, run *model.Run, task *model.Task) bool
+}
+
+// SetBudgetGate 设置预算检查
+func (s *Scheduler) SetBudgetGate(gate BudgetGate) {
+	s.budgetGate = gate
+}
+
+// AccountGate 调度前的账号池额度检查
+//
+// Admit 返回 false 时 Run 保持 queued，由保底轮询在账号额度恢复后重新调度。
+type AccountGate interface {
+	Admit(ctx context.Context, run *model.Run, task *model.Task) bool
+}
+
+// SetAccountGate 设置账号池额度检查
+func (s *Scheduler) SetAccountGate(gate AccountGate) {
+	s.accountGate = gate
+}
+
 // SetStrategyChain 设置自定义策略链
 func (s *Scheduler) SetStrategyChain(chain *StrategyChain) {
 	s.strategyChain = chain
@@ -110,6 +177,26 @@ func (s *Scheduler) GetConfig() *Config {
 	return s.config
 }
 
+// Status 调度器运行状态快照
+type Status struct {
+	NodeID     string   `json:"node_id"`    // 调度器实例 ID（消费者 ID）
+	Running    bool     `json:"running"`    // 是否正在运行
+	Strategies []string `json:"strategies"` // 策略链
+	Preemption bool     `json:"preemption"` // 是否启用优先级抢占
+}
+
+// Status 返回调度器当前运行状态
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return Status{
+		NodeID:     s.config.NodeID,
+		Running:    s.running,
+		Strategies: s.config.Strategy.Chain,
+		Preemption: s.config.Preemption.Enabled,
+	}
+}
+
 // Start 启动调度器
 //
 // 调度器启动后会运行两个并行循环：
@@ -124,17 +211,17 @@ func (s *Scheduler) Start(ctx context.Context) {
 	s.running = true
 	s.mu.Unlock()
 
-	log.Printf("[scheduler.start] node_id=%s queue_enabled=%v strategies=%v",
-		s.config.NodeID, s.schedulerQueue != nil, s.config.Strategy.Chain)
+	slog.InfoContext(ctx, "scheduler.start", "node_id", s.config.NodeID, "queue_enabled", s.schedulerQueue != nil,
+		"strategies", s.config.Strategy.Chain, "workers", s.config.Sharding.Workers)
 
 	var wg sync.WaitGroup
 
 	// 主路径：队列消费
 	if s.schedulerQueue != nil {
 		if err := s.schedulerQueue.CreateSchedulerConsumerGroup(ctx); err != nil {
-			log.Printf("[scheduler.redis.group.failed] error=%v", err)
+			slog.ErrorContext(ctx, "scheduler.redis.group.failed", "error", err)
 		} else {
-			log.Printf("[scheduler.redis.group.created] group=schedulers")
+			slog.InfoContext(ctx, "scheduler.redis.group.created", "group", "schedulers")
 		}
 
 		wg.Add(1)
@@ -152,7 +239,7 @@ func (s *Scheduler) Start(ctx context.Context) {
 	}()
 
 	wg.Wait()
-	log.Printf("[scheduler.stopped] node_id=%s", s.config.NodeID)
+	slog.InfoContext(ctx, "scheduler.stopped", "node_id", s.config.NodeID)
 }
 
 // Stop 停止调度器
@@ -168,15 +255,15 @@ func (s *Scheduler) Stop() {
 
 // consumeRedisStream 消费 Redis Streams 中的任务事件
 func (s *Scheduler) consumeRedisStream(ctx context.Context) {
-	log.Printf("[scheduler.redis.start] consumer_id=%s", s.config.NodeID)
+	slog.InfoContext(ctx, "scheduler.redis.start", "consumer_id", s.config.NodeID)
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("[scheduler.redis.stop] reason=context_cancelled")
+			slog.InfoContext(ctx, "scheduler.redis.stop", "reason", "context_cancelled")
 			return
 		case <-s.stopCh:
-			log.Printf("[scheduler.redis.stop] reason=stop_signal")
+			slog.InfoContext(ctx, "scheduler.redis.stop", "reason", "stop_signal")
 			return
 		default:
 		}
@@ -184,7 +271,7 @@ func (s *Scheduler) consumeRedisStream(ctx context.Context) {
 		messages, err := s.schedulerQueue.ConsumeSchedulerRuns(ctx, s.config.NodeID,
 			int64(s.config.Redis.ReadCount), s.config.Redis.ReadTimeout)
 		if err != nil {
-			log.Printf("[scheduler.redis.consume.failed] error=%v", err)
+			slog.ErrorContext(ctx, "scheduler.redis.consume.failed", "error", err)
 			time.Sleep(1 * time.Second)
 			continue
 		}
@@ -193,27 +280,30 @@ func (s *Scheduler) consumeRedisStream(ctx context.Context) {
 			continue
 		}
 
-		log.Printf("[scheduler.redis.received] count=%d", len(messages))
+		slog.InfoContext(ctx, "scheduler.redis.received", "count", len(messages))
+
+		if s.config.Sharding.Workers > 1 {
+			s.dispatchBatch(ctx, messages)
+			continue
+		}
 
 		for _, msg := range messages {
 			startTime := time.Now()
-			log.Printf("[scheduler.run.start] run_id=%s task_id=%s msg_id=%s source=redis",
-				msg.RunID, msg.TaskID, msg.ID)
+			slog.InfoContext(ctx, "scheduler.run.start", "run_id", msg.RunID, "task_id", msg.TaskID, "msg_id", msg.ID, "source", "redis")
 
 			if err := s.scheduleRunByID(ctx, msg.RunID); err != nil {
-				log.Printf("[scheduler.run.failed] run_id=%s error=%v", msg.RunID, err)
+				slog.ErrorContext(ctx, "scheduler.run.failed", "run_id", msg.RunID, "error", err)
 				continue
 			}
 
 			if err := s.schedulerQueue.AckSchedulerRun(ctx, msg.ID); err != nil {
-				log.Printf("[scheduler.redis.ack.failed] run_id=%s msg_id=%s error=%v",
-					msg.RunID, msg.ID, err)
+				slog.ErrorContext(ctx, "scheduler.redis.ack.failed", "run_id", msg.RunID, "msg_id", msg.ID, "error", err)
 			}
 
 			delay := time.Since(msg.CreatedAt)
 			duration := time.Since(startTime)
-			log.Printf("[scheduler.run.success] run_id=%s msg_id=%s delay_ms=%d duration_ms=%d",
-				msg.RunID, msg.ID, delay.Milliseconds(), duration.Milliseconds())
+			slog.InfoContext(ctx, "scheduler.run.success", "run_id", msg.RunID, "msg_id", msg.ID,
+				"delay_ms", delay.Milliseconds(), "duration_ms", duration.Milliseconds())
 		}
 	}
 }
@@ -229,10 +319,10 @@ func (s *Scheduler) fallbackPolling(ctx context.Context) {
 	for {
 		select {
 		case <-ctx.Done():
-			log.Printf("[scheduler.fallback.stop] reason=context_cancelled")
+			slog.InfoContext(ctx, "scheduler.fallback.stop", "reason", "context_cancelled")
 			return
 		case <-s.stopCh:
-			log.Printf("[scheduler.fallback.stop] reason=stop_signal")
+			slog.InfoContext(ctx, "scheduler.fallback.stop", "reason", "stop_signal")
 			return
 		case <-ticker.C:
 			s.processFallbackRuns(ctx)
@@ -245,7 +335,7 @@ func (s *Scheduler) processFallbackRuns(ctx context.Context) {
 	// 查找状态是 queued 但超过阈值时间没被调度的 Run
 	runs, err := s.store.ListStaleQueuedRuns(ctx, s.staleThreshold)
 	if err != nil {
-		log.Printf("[scheduler.fallback.query.failed] error=%v", err)
+		slog.ErrorContext(ctx, "scheduler.fallback.query.failed", "error", err)
 		return
 	}
 
@@ -253,52 +343,80 @@ func (s *Scheduler) processFallbackRuns(ctx context.Context) {
 		return
 	}
 
-	log.Printf("[scheduler.fallback.found] count=%d threshold=%s", len(runs), s.staleThreshold)
+	slog.InfoContext(ctx, "scheduler.fallback.found", "count", len(runs), "threshold", s.staleThreshold)
 
 	for _, run := range runs {
-		log.Printf("[scheduler.fallback.processing] run_id=%s created_at=%s source=fallback",
-			run.ID, run.CreatedAt.Format(time.RFC3339))
+		slog.InfoContext(ctx, "scheduler.fallback.processing", "run_id", run.ID, "created_at", run.CreatedAt.Format(time.RFC3339), "source", "fallback")
 
 		if err := s.scheduleRunByID(ctx, run.ID); err != nil {
-			log.Printf("[scheduler.fallback.failed] run_id=%s error=%v", run.ID, err)
+			slog.ErrorContext(ctx, "scheduler.fallback.failed", "run_id", run.ID, "error", err)
 			continue
 		}
 
-		log.Printf("[scheduler.fallback.success] run_id=%s", run.ID)
+		slog.InfoContext(ctx, "scheduler.fallback.success", "run_id", run.ID)
 	}
 }
 
 // scheduleRunByID 根据 Run ID 执行调度
 func (s *Scheduler) scheduleRunByID(ctx context.Context, runID string) error {
+	run, err := s.loadQueuedRun(ctx, runID)
+	if err != nil || run == nil {
+		return err
+	}
+	return s.scheduleRun(ctx, run)
+}
+
+// loadQueuedRun 读取待调度的 Run，不存在或已不是 queued 状态时返回 nil
+func (s *Scheduler) loadQueuedRun(ctx context.Context, runID string) (*model.Run, error) {
 	run, err := s.store.GetRun(ctx, runID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if run == nil {
-		log.Printf("[scheduler.run.not_found] run_id=%s", runID)
-		return nil
+		slog.WarnContext(ctx, "scheduler.run.not_found", "run_id", runID)
+		return nil, nil
 	}
 
 	if run.Status != model.RunStatusQueued {
-		log.Printf("[scheduler.run.skip] run_id=%s status=%s reason=not_queued", runID, run.Status)
-		return nil
+		slog.InfoContext(ctx, "scheduler.run.skip", "run_id", runID, "status", run.Status, "reason", "not_queued")
+		return nil, nil
 	}
-
-	return s.scheduleRun(ctx, run)
+	return run, nil
 }
 
 // scheduleRun 执行单个 Run 的调度
 func (s *Scheduler) scheduleRun(ctx context.Context, run *model.Run) error {
-	// 获取在线节点
-	nodes, err := s.nodeManager.ListOnlineNodes(ctx)
+	nodes, err := s.prepareNodes(ctx)
 	if err != nil {
 		return err
 	}
 	if len(nodes) == 0 {
-		log.Printf("[scheduler.run.no_nodes] run_id=%s", run.ID)
+		slog.InfoContext(ctx, "scheduler.run.no_nodes", "run_id", run.ID)
+		s.recordDecision(ctx, &model.SchedulingDecision{RunID: run.ID, Outcome: model.SchedulingOutcomeNoNodes})
 		return nil
 	}
 
+	assignment, err := s.assignRun(ctx, run, nodes)
+	if err != nil || assignment == nil {
+		return err
+	}
+
+	// 通知节点管理器
+	s.publishTaskToNode(ctx, assignment.NodeID, assignment.RunID, assignment.TaskID)
+	return nil
+}
+
+// prepareNodes 获取在线节点，回收离线节点上的 Run 并刷新节点运行任务计数
+func (s *Scheduler) prepareNodes(ctx context.Context) ([]*model.Node, error) {
+	// 获取在线节点
+	nodes, err := s.nodeManager.ListOnlineNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(nodes) == 0 {
+		return nil, nil
+	}
+
 	// 构建在线节点 ID 集合
 	onlineIDs := make(map[string]struct{}, len(nodes))
 	for _, n := range nodes {
@@ -310,6 +428,20 @@ func (s *Scheduler) scheduleRun(ctx context.Context, run *model.Run) error {
 
 	// 刷新节点运行任务计数
 	s.nodeManager.RefreshRunningCount(ctx, nodes)
+	return nodes, nil
+}
+
+// assignRun 为 Run 选择节点并更新为 assigned
+//
+// 返回待通知节点的分派；预算不足、账号池额度耗尽或没有匹配节点时返回 nil（Run 保持 queued），
+// 快照不合法时返回 nil（Run 直接失败）。每种结果都记录调度决策（见 decision.go）。
+func (s *Scheduler) assignRun(ctx context.Context, run *model.Run, nodes []*model.Node) (*queue.NodeRunAssignment, error) {
+	// 快照不合法时节点无法执行，直接失败而不是分派到节点后才失败；旧版本格式升级后参与调度
+	snapshot, err := model.NormalizeSnapshot(run.Snapshot)
+	if err != nil {
+		return nil, s.failInvalidSnapshot(ctx, run, err)
+	}
+	run.Snapshot = snapshot
 
 	// 获取任务信息
 	var task *model.Task
@@ -317,39 +449,122 @@ func (s *Scheduler) scheduleRun(ctx context.Context, run *model.Run) error {
 		task, _ = s.store.GetTask(ctx, run.TaskID)
 	}
 
-	// 解析优先节点
+	// 预算耗尽时暂不分派
+	if s.budgetGate != nil && !s.budgetGate.Admit(ctx, run, task) {
+		slog.InfoContext(ctx, "scheduler.run.budget_hold", "run_id", run.ID)
+		s.recordDecision(ctx, &model.SchedulingDecision{RunID: run.ID, Outcome: model.SchedulingOutcomeBudgetHold})
+		return nil, nil
+	}
+
+	// 账号池中的账号均已达额度时暂不分派
+	if s.accountGate != nil && !s.accountGate.Admit(ctx, run, task) {
+		slog.InfoContext(ctx, "scheduler.run.account_hold", "run_id", run.ID)
+		s.recordDecision(ctx, &model.SchedulingDecision{RunID: run.ID, Outcome: model.SchedulingOutcomeAccountHold})
+		return nil, nil
+	}
+
+	// 按任务声明的适配器能力要求过滤候选节点
+	onlineNodes := nodes
+	requirement := model.AdapterRequirementFromTask(task)
+	if requirement != nil {
+		if nodes = filterByAdapter(nodes, requirement); len(nodes) == 0 {
+			slog.InfoContext(ctx, "scheduler.run.no_match", "run_id", run.ID, "reason", "adapter_capability",
+				"adapter", requirement.Adapter, "features", requirement.Features, "min_version", requirement.MinVersion)
+			s.recordDecision(ctx, &model.SchedulingDecision{
+				RunID:      run.ID,
+				Outcome:    model.SchedulingOutcomeNoMatch,
+				Reason:     model.SchedulingRejectAdapter,
+				Candidates: evaluateCandidates(onlineNodes, requirement, task, nil, s.nodeManager.GetNodeRunning(), ""),
+			})
+			return nil, nil
+		}
+	}
+
+	// 解析优先节点与任务间放置关系
 	preferredNode := s.nodeManager.ResolvePreferredNodeID(ctx, run.TaskID, run.Snapshot)
+	placement := s.loadPlacement(ctx, run, task)
+	region := s.loadRegion(ctx, task)
 
-	// 构建调度请求
+	// 选择节点与递增计数在同一临界区内完成，并行 worker 不会超出节点容量
+	s.selectMu.Lock()
 	req := &ScheduleRequest{
 		Run:            run,
 		Task:           task,
 		CandidateNodes: nodes,
-		NodeRunning:    s.nodeManager.GetNodeRunning(),
+		NodeRunning:    reserveHighPrioritySlots(s.nodeManager.GetNodeRunning(), nodes, run.Priority),
 		PreferredNode:  preferredNode,
+		Placement:      placement,
+		Region:         region,
+	}
+
+	// 使用策略链选择节点（策略链可能被热加载替换，本次调度始终使用同一条）
+	chain := s.strategyChain
+	node, strategy, reason := chain.Select(ctx, req)
+	if node == nil && s.config.Preemption.Enabled && run.Priority == model.PriorityHigh {
+		// 所有可选节点均已饱和时，尝试抢占 low 优先级 Run 的分配
+		if node, reason = s.tryPreempt(ctx, req); node != nil {
+			strategy = "preemption"
+		}
 	}
+	if node != nil {
+		s.nodeManager.IncrementRunning(node.ID)
+	}
+	s.selectMu.Unlock()
 
-	// 使用策略链选择节点
-	node, reason := s.strategyChain.SelectNode(ctx, req)
+	decision := &model.SchedulingDecision{RunID: run.ID, Strategy: strategy, Reason: reason}
 	if node == nil {
-		log.Printf("[scheduler.run.no_match] run_id=%s reason=%s", run.ID, reason)
-		return nil
+		slog.InfoContext(ctx, "scheduler.run.no_match", "run_id", run.ID, "reason", reason)
+		decision.Outcome = model.SchedulingOutcomeNoMatch
+		decision.Candidates = evaluateCandidates(onlineNodes, requirement, task, chain.rejections(req), req.NodeRunning, "")
+		s.recordDecision(ctx, decision)
+		return nil, nil
 	}
 
-	// 更新 Run 状态
+	// 更新 Run 状态（失败时多计的运行数在下一轮 prepareNodes 刷新时校正）
 	nodeID := node.ID
 	if err := s.store.UpdateRunStatus(ctx, run.ID, model.RunStatusAssigned, &nodeID); err != nil {
-		return err
+		return nil, err
 	}
 
-	// 通知节点管理器
-	s.publishTaskToNode(ctx, nodeID, run.ID, run.TaskID)
+	slog.InfoContext(ctx, "scheduler.run.assigned", "run_id", run.ID, "node_id", nodeID, "reason", reason)
+	decision.Outcome = model.SchedulingOutcomeAssigned
+	decision.NodeID = nodeID
+	decision.Candidates = evaluateCandidates(onlineNodes, requirement, task, chain.rejections(req), req.NodeRunning, nodeID)
+	s.recordDecision(ctx, decision)
+	return &queue.NodeRunAssignment{NodeID: nodeID, RunID: run.ID, TaskID: run.TaskID}, nil
+}
 
-	s.nodeManager.IncrementRunning(nodeID)
-	log.Printf("[scheduler.run.assigned] run_id=%s node_id=%s reason=%s", run.ID, nodeID, reason)
+// failInvalidSnapshot 以 failed 结束快照不合法的 Run（创建时已校验，只有历史数据或绕过 API 写入的 Run 会到达这里）
+func (s *Scheduler) failInvalidSnapshot(ctx context.Context, run *model.Run, snapshotErr error) error {
+	slog.ErrorContext(ctx, "scheduler.run.invalid", "run_id", run.ID, "error", snapshotErr)
+	if err := s.store.UpdateRunStatus(ctx, run.ID, model.RunStatusFailed, nil); err != nil {
+		return err
+	}
+	if err := s.store.UpdateRunError(ctx, run.ID, "invalid run snapshot: "+snapshotErr.Error()); err != nil {
+		slog.ErrorContext(ctx, "scheduler.run.invalid.error_failed", "run_id", run.ID, "error", err)
+	}
+	if run.TaskID != "" {
+		if err := s.store.UpdateTaskStatus(ctx, run.TaskID, model.TaskStatusFailed); err != nil {
+			slog.ErrorContext(ctx, "scheduler.run.invalid.task_failed", "run_id", run.ID, "task_id", run.TaskID, "error", err)
+		}
+	}
+	s.recordDecision(ctx, &model.SchedulingDecision{RunID: run.ID, Outcome: model.SchedulingOutcomeInvalid, Reason: snapshotErr.Error()})
 	return nil
 }
 
+// reserveHighPrioritySlots 非 high 优先级 Run 调度时，将节点预留给 high 优先级的槽位计为已占用
+func reserveHighPrioritySlots(running map[string]int, nodes []*model.Node, priority model.Priority) map[string]int {
+	if priority == model.PriorityHigh {
+		return running
+	}
+	for _, n := range nodes {
+		if reserved := node.GetNodeReservedSlots(n); reserved > 0 {
+			running[n.ID] += reserved
+		}
+	}
+	return running
+}
+
 // publishTaskToNode 发布任务到节点的 Redis Stream
 func (s *Scheduler) publishTaskToNode(ctx context.Context, nodeID, runID, taskID string) {
 	if s.nodeQueue == nil {
@@ -358,9 +573,9 @@ func (s *Scheduler) publishTaskToNode(ctx context.Context, nodeID, runID, taskID
 
 	msgID, err := s.nodeQueue.PublishRunToNode(ctx, nodeID, runID, taskID)
 	if err != nil {
-		log.Printf("[scheduler.notify.failed] node_id=%s run_id=%s error=%v", nodeID, runID, err)
+		slog.ErrorContext(ctx, "scheduler.notify.failed", "node_id", nodeID, "run_id", runID, "error", err)
 		return
 	}
 
-	log.Printf("[scheduler.notify.success] node_id=%s run_id=%s msg_id=%s", nodeID, runID, msgID)
+	slog.InfoContext(ctx, "scheduler.notify.success", "node_id", nodeID, "run_id", runID, "msg_id", msgID)
 }
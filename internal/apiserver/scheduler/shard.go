@@ -0,0 +1,126 @@
+// Package scheduler 分片调度
+//
+// 节点规模较大时，逐条串行调度（每条消息都重新查询节点、逐个 XADD）会成为瓶颈。
+// 开启分片调度（sharding.workers > 1）后，Run 按 ID 的 FNV-1a 哈希映射到固定数量的哈希槽，
+// 每个 worker 拥有 slot % workers == 序号的槽位。一批调度消息的处理流程：
+//  1. 整批共享一次节点快照（在线节点、离线节点回收、运行计数）
+//  2. 各 worker 并行处理自己槽位上的 Run；同一 Run 的重复消息落在同一 worker 内串行处理，不会重复分派
+//  3. 节点选择与运行计数递增在同一临界区内完成，并行 worker 不会超出节点容量
+//  4. 分派结果通过 queue.BatchNodeRunQueue 在一次 pipeline 中写入各节点队列，再逐条确认调度消息
+package scheduler
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/queue"
+)
+
+// hashSlot 计算 Run 所属的哈希槽
+func hashSlot(runID string, slots int) int {
+	h := fnv.New32a()
+	h.Write([]byte(runID))
+	return int(h.Sum32() % uint32(slots))
+}
+
+// slotOwner 返回拥有哈希槽的 worker 序号
+func slotOwner(slot, workers int) int {
+	return slot % workers
+}
+
+// dispatchBatch 按哈希槽将一批调度消息分发给并行 worker 处理
+func (s *Scheduler) dispatchBatch(ctx context.Context, messages []*queue.SchedulerMessage) {
+	startTime := time.Now()
+	workers := s.config.Sharding.Workers
+
+	nodes, err := s.prepareNodes(ctx)
+	if err != nil {
+		// 不确认消息，由保底轮询补偿
+		slog.ErrorContext(ctx, "scheduler.batch.failed", "count", len(messages), "error", err)
+		return
+	}
+	if len(nodes) == 0 {
+		slog.InfoContext(ctx, "scheduler.batch.no_nodes", "count", len(messages))
+	}
+
+	buckets := make([][]int, workers)
+	for i, msg := range messages {
+		w := slotOwner(hashSlot(msg.RunID, s.config.Sharding.Slots), workers)
+		buckets[w] = append(buckets[w], i)
+	}
+
+	assignments := make([]*queue.NodeRunAssignment, len(messages))
+	errs := make([]error, len(messages))
+	var wg sync.WaitGroup
+	for _, idx := range buckets {
+		if len(idx) == 0 || len(nodes) == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(idx []int) {
+			defer wg.Done()
+			for _, i := range idx {
+				run, err := s.loadQueuedRun(ctx, messages[i].RunID)
+				if err != nil || run == nil {
+					errs[i] = err
+					continue
+				}
+				assignments[i], errs[i] = s.assignRun(ctx, run, nodes)
+			}
+		}(idx)
+	}
+	wg.Wait()
+
+	var batch []queue.NodeRunAssignment
+	for _, a := range assignments {
+		if a != nil {
+			batch = append(batch, *a)
+		}
+	}
+	s.publishTasksToNodes(ctx, batch)
+
+	failed := 0
+	for i, msg := range messages {
+		if errs[i] != nil {
+			failed++
+			slog.ErrorContext(ctx, "scheduler.run.failed", "run_id", msg.RunID, "error", errs[i])
+			continue
+		}
+		if err := s.schedulerQueue.AckSchedulerRun(ctx, msg.ID); err != nil {
+			slog.ErrorContext(ctx, "scheduler.redis.ack.failed", "run_id", msg.RunID, "msg_id", msg.ID, "error", err)
+		}
+	}
+
+	slog.InfoContext(ctx, "scheduler.batch.success", "count", len(messages), "assigned", len(batch), "failed", failed,
+		"workers", workers, "duration_ms", time.Since(startTime).Milliseconds())
+}
+
+// publishTasksToNodes 批量发布分派到节点队列（节点队列不支持批量时逐条发布）
+func (s *Scheduler) publishTasksToNodes(ctx context.Context, runs []queue.NodeRunAssignment) {
+	if s.nodeQueue == nil || len(runs) == 0 {
+		return
+	}
+
+	batchQueue, ok := s.nodeQueue.(queue.BatchNodeRunQueue)
+	if !ok {
+		for _, r := range runs {
+			s.publishTaskToNode(ctx, r.NodeID, r.RunID, r.TaskID)
+		}
+		return
+	}
+
+	msgIDs, err := batchQueue.PublishRunsToNodes(ctx, runs)
+	if err != nil {
+		for i, r := range runs {
+			if i >= len(msgIDs) || msgIDs[i] == "" {
+				slog.ErrorContext(ctx, "scheduler.notify.failed", "node_id", r.NodeID, "run_id", r.RunID, "error", err)
+			}
+		}
+		return
+	}
+
+	slog.InfoContext(ctx, "scheduler.notify.success", "count", len(runs), "mode", "batch")
+}
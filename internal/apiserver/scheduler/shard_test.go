@@ -0,0 +1,263 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+	"agents-admin/internal/shared/storage"
+)
+
+// shardStore 并发安全的内存存储，每次调用模拟一次数据库往返延迟
+type shardStore struct {
+	storage.PersistentStore
+	latency time.Duration
+	mu      sync.Mutex
+	nodes   []*model.Node
+	runs    map[string]*model.Run
+	updates map[string]int
+}
+
+func newShardStore(latency time.Duration, nodeCount, capacity, runCount int) *shardStore {
+	now := time.Now()
+	store := &shardStore{latency: latency, runs: map[string]*model.Run{}, updates: map[string]int{}}
+	for i := 0; i < nodeCount; i++ {
+		node := createTestNode(fmt.Sprintf("node-%d", i), nil, capacity)
+		node.LastHeartbeat = &now
+		store.nodes = append(store.nodes, node)
+	}
+	for i := 0; i < runCount; i++ {
+		id := fmt.Sprintf("run-%d", i)
+		store.runs[id] = &model.Run{ID: id, Status: model.RunStatusQueued, Snapshot: testSnapshot}
+	}
+	return store
+}
+
+func (m *shardStore) wait() {
+	if m.latency > 0 {
+		time.Sleep(m.latency)
+	}
+}
+
+func (m *shardStore) ListAllNodes(ctx context.Context) ([]*model.Node, error) {
+	m.wait()
+	return m.nodes, nil
+}
+func (m *shardStore) ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error) {
+	m.wait()
+	return nil, nil
+}
+func (m *shardStore) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	m.wait()
+	return nil, nil
+}
+func (m *shardStore) ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error) {
+	m.wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var runs []*model.Run
+	for _, r := range m.runs {
+		if r.NodeID != nil && *r.NodeID == nodeID {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
+func (m *shardStore) GetRun(ctx context.Context, id string) (*model.Run, error) {
+	m.wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if r, ok := m.runs[id]; ok {
+		c := *r
+		return &c, nil
+	}
+	return nil, nil
+}
+func (m *shardStore) UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error {
+	m.wait()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[id].Status = status
+	m.runs[id].NodeID = nodeID
+	m.updates[id]++
+	return nil
+}
+func (m *shardStore) RecordSchedulingDecision(ctx context.Context, d *model.SchedulingDecision) error {
+	return nil
+}
+
+// shardQueue 记录确认的调度消息与节点分派，每次调用模拟一次 Redis 往返延迟
+type shardQueue struct {
+	queue.NoOpQueue
+	latency   time.Duration
+	mu        sync.Mutex
+	acked     int
+	batches   int
+	published []queue.NodeRunAssignment
+}
+
+func (q *shardQueue) AckSchedulerRun(ctx context.Context, messageID string) error {
+	q.mu.Lock()
+	q.acked++
+	q.mu.Unlock()
+	return nil
+}
+func (q *shardQueue) PublishRunToNode(ctx context.Context, nodeID, runID, taskID string) (string, error) {
+	time.Sleep(q.latency)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.published = append(q.published, queue.NodeRunAssignment{NodeID: nodeID, RunID: runID, TaskID: taskID})
+	return "1-0", nil
+}
+func (q *shardQueue) PublishRunsToNodes(ctx context.Context, runs []queue.NodeRunAssignment) ([]string, error) {
+	time.Sleep(q.latency)
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.batches++
+	q.published = append(q.published, runs...)
+	ids := make([]string, len(runs))
+	for i := range ids {
+		ids[i] = fmt.Sprintf("1-%d", i)
+	}
+	return ids, nil
+}
+
+func schedulerMessages(runCount int) []*queue.SchedulerMessage {
+	msgs := make([]*queue.SchedulerMessage, runCount)
+	for i := range msgs {
+		msgs[i] = &queue.SchedulerMessage{ID: fmt.Sprintf("msg-%d", i), RunID: fmt.Sprintf("run-%d", i)}
+	}
+	return msgs
+}
+
+func newShardedScheduler(store *shardStore, q *shardQueue, workers int) *Scheduler {
+	config := DefaultConfig()
+	config.Strategy.Chain = []string{"load_balance"}
+	config.Sharding.Workers = workers
+	return NewSchedulerWithConfig(store, q, q, config)
+}
+
+func TestHashSlot(t *testing.T) {
+	owners := map[int]int{}
+	for i := 0; i < 1000; i++ {
+		runID := fmt.Sprintf("run-%d", i)
+		slot := hashSlot(runID, DefaultHashSlots)
+		if slot < 0 || slot >= DefaultHashSlots {
+			t.Fatalf("hashSlot(%s) = %d, 超出槽位范围", runID, slot)
+		}
+		if slot != hashSlot(runID, DefaultHashSlots) {
+			t.Fatalf("hashSlot(%s) 结果不稳定", runID)
+		}
+		owners[slotOwner(slot, 8)]++
+	}
+	if len(owners) != 8 {
+		t.Errorf("1000 个 Run 应分布到全部 8 个 worker, got %v", owners)
+	}
+}
+
+func TestDispatchBatch(t *testing.T) {
+	store := newShardStore(0, 3, 2, 10)
+	q := &shardQueue{}
+	s := newShardedScheduler(store, q, 4)
+
+	// 同一 Run 的重复消息落在同一 worker，只分派一次
+	msgs := append(schedulerMessages(10), &queue.SchedulerMessage{ID: "msg-dup", RunID: "run-0"})
+	s.dispatchBatch(context.Background(), msgs)
+
+	if q.acked != len(msgs) {
+		t.Errorf("确认消息数 = %d, 期望 %d", q.acked, len(msgs))
+	}
+	if q.batches != 1 || len(q.published) != 6 {
+		t.Errorf("应以一次批量发布分派 6 个 Run（3 节点 × 容量 2）, batches=%d published=%d", q.batches, len(q.published))
+	}
+
+	perNode := map[string]int{}
+	for id, r := range store.runs {
+		if store.updates[id] > 1 {
+			t.Errorf("Run %s 被重复分派 %d 次", id, store.updates[id])
+		}
+		if r.Status == model.RunStatusAssigned {
+			perNode[*r.NodeID]++
+		}
+	}
+	for nodeID, n := range perNode {
+		if n > 2 {
+			t.Errorf("节点 %s 分派 %d 个 Run, 超出容量 2", nodeID, n)
+		}
+	}
+}
+
+// dispatchSequential 按串行路径逐条调度（与 consumeRedisStream 在 workers=1 时一致）
+func dispatchSequential(s *Scheduler, msgs []*queue.SchedulerMessage) {
+	ctx := context.Background()
+	for _, msg := range msgs {
+		if err := s.scheduleRunByID(ctx, msg.RunID); err == nil {
+			s.schedulerQueue.AckSchedulerRun(ctx, msg.ID)
+		}
+	}
+}
+
+// 模拟 1ms 存储与 Redis 往返延迟、8 个节点的调度吞吐
+const (
+	benchLatency = time.Millisecond
+	benchNodes   = 8
+	benchBatch   = 256
+	benchWorkers = 32
+)
+
+func BenchmarkDispatch(b *testing.B) {
+	b.Run("sequential", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			store := newShardStore(benchLatency, benchNodes, benchBatch, benchBatch)
+			s := newShardedScheduler(store, &shardQueue{latency: benchLatency}, 1)
+			b.StartTimer()
+			dispatchSequential(s, schedulerMessages(benchBatch))
+		}
+		b.ReportMetric(float64(b.N*benchBatch)/b.Elapsed().Seconds(), "runs/s")
+	})
+	b.Run("sharded", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			b.StopTimer()
+			store := newShardStore(benchLatency, benchNodes, benchBatch, benchBatch)
+			s := newShardedScheduler(store, &shardQueue{latency: benchLatency}, benchWorkers)
+			b.StartTimer()
+			s.dispatchBatch(context.Background(), schedulerMessages(benchBatch))
+		}
+		b.ReportMetric(float64(b.N*benchBatch)/b.Elapsed().Seconds(), "runs/s")
+	})
+}
+
+func TestDispatchBatch_Throughput(t *testing.T) {
+	if testing.Short() {
+		t.Skip("吞吐对比耗时较长")
+	}
+
+	// 串行路径每个 Run 都重新查询节点，取少量 Run 估算单条耗时
+	const sequentialRuns = 16
+	store := newShardStore(benchLatency, benchNodes, benchBatch, sequentialRuns)
+	s := newShardedScheduler(store, &shardQueue{latency: benchLatency}, 1)
+	start := time.Now()
+	dispatchSequential(s, schedulerMessages(sequentialRuns))
+	sequential := time.Since(start) / sequentialRuns
+
+	store = newShardStore(benchLatency, benchNodes, benchBatch, benchBatch)
+	q := &shardQueue{latency: benchLatency}
+	s = newShardedScheduler(store, q, benchWorkers)
+	start = time.Now()
+	s.dispatchBatch(context.Background(), schedulerMessages(benchBatch))
+	sharded := time.Since(start) / benchBatch
+
+	if len(q.published) != benchBatch {
+		t.Fatalf("分片调度分派 %d 个 Run, 期望 %d", len(q.published), benchBatch)
+	}
+	speedup := float64(sequential) / float64(sharded)
+	t.Logf("单条调度耗时: sequential=%s sharded=%s speedup=%.1fx", sequential, sharded, speedup)
+	if speedup < 10 {
+		t.Errorf("分片调度吞吐提升 %.1fx, 期望 > 10x", speedup)
+	}
+}
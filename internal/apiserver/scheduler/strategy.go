@@ -36,12 +36,23 @@ type ScheduleRequest struct {
 	CandidateNodes []*model.Node          // 候选节点列表（已过滤在线且有容量的节点）
 	NodeRunning    map[string]int         // 各节点当前运行任务数
 	PreferredNode  string                 // 优先节点 ID（由亲和性策略使用）
+	Placement      *Placement             // 任务间放置关系（任务未声明调度约束时为 nil）
+	Region         *model.Region          // 任务声明的区域（任务未声明区域时为 nil）
+}
+
+// Constraint 约束型策略
+//
+// 实现此接口的策略在策略链选择节点前先过滤候选节点（用于必须满足的调度约束），
+// 被过滤的节点不再交给链中任何策略。
+type Constraint interface {
+	// Filter 返回满足约束的候选节点
+	Filter(req *ScheduleRequest) []*model.Node
 }
 
 // StrategyChain 策略链
 //
 // 按优先级组织多个策略，依次尝试直到找到合适的节点。
-// 典型的策略链顺序：亲和性 → 标签匹配 → 负载均衡
+// 典型的策略链顺序：亲和性 → 区域 → 任务间约束 → 标签匹配 → 负载均衡
 type StrategyChain struct {
 	strategies []Strategy
 }
@@ -53,12 +64,74 @@ func NewStrategyChain(strategies ...Strategy) *StrategyChain {
 
 // SelectNode 按策略链顺序选择节点
 func (c *StrategyChain) SelectNode(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	node, _, reason := c.Select(ctx, req)
+	return node, reason
+}
+
+// Select 按策略链顺序选择节点，同时返回做出选择的策略名称（用于调度决策审计）
+//
+// 先应用链中的约束型策略，候选节点被全部过滤时返回 <策略名>_unsatisfied。
+func (c *StrategyChain) Select(ctx context.Context, req *ScheduleRequest) (*model.Node, string, string) {
+	req, rejected := c.constrain(req)
+	if len(req.CandidateNodes) == 0 && len(rejected) > 0 {
+		return nil, "", c.lastConstraint(rejected) + "_unsatisfied"
+	}
 	for _, strategy := range c.strategies {
 		if node, reason := strategy.SelectNode(ctx, req); node != nil {
-			return node, reason
+			return node, strategy.Name(), reason
+		}
+	}
+	return nil, "", "no_strategy_matched"
+}
+
+// constrain 依次应用约束型策略，返回收窄后的请求及被过滤节点对应的策略名
+func (c *StrategyChain) constrain(req *ScheduleRequest) (*ScheduleRequest, map[string]string) {
+	var rejected map[string]string
+	for _, strategy := range c.strategies {
+		constraint, ok := strategy.(Constraint)
+		if !ok {
+			continue
+		}
+		admitted := constraint.Filter(req)
+		if len(admitted) == len(req.CandidateNodes) {
+			continue
+		}
+		kept := make(map[string]bool, len(admitted))
+		for _, node := range admitted {
+			kept[node.ID] = true
+		}
+		if rejected == nil {
+			rejected = make(map[string]string)
+		}
+		for _, node := range req.CandidateNodes {
+			if !kept[node.ID] {
+				rejected[node.ID] = strategy.Name()
+			}
+		}
+		narrowed := *req
+		narrowed.CandidateNodes = admitted
+		req = &narrowed
+	}
+	return req, rejected
+}
+
+// rejections 返回被约束型策略过滤的节点及策略名（用于调度决策审计）
+func (c *StrategyChain) rejections(req *ScheduleRequest) map[string]string {
+	_, rejected := c.constrain(req)
+	return rejected
+}
+
+// lastConstraint 返回最后一个过滤节点的约束策略名称
+func (c *StrategyChain) lastConstraint(rejected map[string]string) string {
+	for i := len(c.strategies) - 1; i >= 0; i-- {
+		name := c.strategies[i].Name()
+		for _, r := range rejected {
+			if r == name {
+				return name
+			}
 		}
 	}
-	return nil, "no_strategy_matched"
+	return ""
 }
 
 // Add 添加策略到链尾
@@ -0,0 +1,65 @@
+// Package scheduler 任务反亲和调度策略
+package scheduler
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+)
+
+// AntiAffinityStrategy 任务反亲和调度策略
+//
+// 同一任务的多个 Run（重试、手动再次运行）不在同一节点并行执行，避免节点故障同时影响它们。
+//
+// mode=required 时排除正在执行该任务其他 Run 的节点；mode=preferred 时优先选择
+// 其他节点，都不可用则交给链中后续策略。
+type AntiAffinityStrategy struct{}
+
+// NewAntiAffinityStrategy 创建任务反亲和策略
+func NewAntiAffinityStrategy() *AntiAffinityStrategy {
+	return &AntiAffinityStrategy{}
+}
+
+// Name 返回策略名称
+func (s *AntiAffinityStrategy) Name() string {
+	return "anti_affinity"
+}
+
+// Filter 必须反亲和时排除执行同一任务其他 Run 的节点
+func (s *AntiAffinityStrategy) Filter(req *ScheduleRequest) []*model.Node {
+	if !s.active(req) || !req.Task.Scheduling.AntiAffinity.IsRequired() {
+		return req.CandidateNodes
+	}
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		if req.Placement.TaskRuns[node.ID] == 0 {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// SelectNode 在没有同一任务 Run 的可用节点中选择负载最低的节点
+func (s *AntiAffinityStrategy) SelectNode(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	if !s.active(req) {
+		return nil, ""
+	}
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		if req.Placement.TaskRuns[node.ID] == 0 && eligibleNode(req, node) {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, ""
+	}
+	return selectByLoadBalance(nodes, req.NodeRunning), "anti_affinity"
+}
+
+// active 任务声明了反亲和且同一任务有其他活跃 Run
+func (s *AntiAffinityStrategy) active(req *ScheduleRequest) bool {
+	if req.Task == nil || req.Task.Scheduling == nil || req.Task.Scheduling.AntiAffinity == nil {
+		return false
+	}
+	return req.Placement != nil && len(req.Placement.TaskRuns) > 0
+}
@@ -0,0 +1,83 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestAntiAffinityStrategy_SelectNode(t *testing.T) {
+	ctx := context.Background()
+	strategy := NewAntiAffinityStrategy()
+
+	tests := []struct {
+		name       string
+		anti       *model.TaskAntiAffinity
+		taskRuns   map[string]int
+		running    map[string]int
+		wantNode   string
+		wantFilter int
+	}{
+		{
+			name:       "避开执行同一任务的节点",
+			anti:       &model.TaskAntiAffinity{},
+			taskRuns:   map[string]int{"node-1": 1},
+			running:    map[string]int{"node-1": 1, "node-2": 1},
+			wantNode:   "node-2",
+			wantFilter: 2,
+		},
+		{
+			name:       "其他节点已满时交给后续策略",
+			anti:       &model.TaskAntiAffinity{},
+			taskRuns:   map[string]int{"node-1": 1},
+			running:    map[string]int{"node-1": 1, "node-2": 2},
+			wantFilter: 2,
+		},
+		{
+			name:       "必须反亲和时排除节点",
+			anti:       &model.TaskAntiAffinity{Mode: model.SchedulingModeRequired},
+			taskRuns:   map[string]int{"node-1": 1},
+			wantNode:   "node-2",
+			wantFilter: 1,
+		},
+		{
+			name:       "同一任务没有其他活跃 Run",
+			anti:       &model.TaskAntiAffinity{Mode: model.SchedulingModeRequired},
+			taskRuns:   map[string]int{},
+			wantFilter: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createTestTask("task-1", nil)
+			task.Scheduling = &model.TaskScheduling{AntiAffinity: tt.anti}
+			running := tt.running
+			if running == nil {
+				running = map[string]int{}
+			}
+			req := &ScheduleRequest{
+				Task: task,
+				CandidateNodes: []*model.Node{
+					createTestNode("node-1", nil, 2),
+					createTestNode("node-2", nil, 2),
+				},
+				NodeRunning: running,
+				Placement:   &Placement{TaskRuns: tt.taskRuns},
+			}
+
+			if got := len(strategy.Filter(req)); got != tt.wantFilter {
+				t.Errorf("Filter() = %d nodes, want %d", got, tt.wantFilter)
+			}
+			node, _ := strategy.SelectNode(ctx, req)
+			gotID := ""
+			if node != nil {
+				gotID = node.ID
+			}
+			if gotID != tt.wantNode {
+				t.Errorf("SelectNode() = %q, want %q", gotID, tt.wantNode)
+			}
+		})
+	}
+}
@@ -4,7 +4,7 @@ package scheduler
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 
 	nodemgr "agents-admin/internal/apiserver/node"
 	"agents-admin/internal/shared/model"
@@ -54,12 +54,12 @@ func (s *DirectStrategy) SelectNode(ctx context.Context, req *ScheduleRequest) (
 			if maxConcurrent-currentRunning > 0 {
 				return n, "direct"
 			}
-			log.Printf("[strategy.direct] node %s has no capacity", specifiedNodeID)
+			slog.InfoContext(ctx, "strategy.direct.no_capacity", "node_id", specifiedNodeID)
 			return nil, "direct_no_capacity"
 		}
 	}
 
-	log.Printf("[strategy.direct] specified node %s not found or offline", specifiedNodeID)
+	slog.WarnContext(ctx, "strategy.direct.unavailable", "node_id", specifiedNodeID)
 	return nil, "direct_node_unavailable"
 }
 
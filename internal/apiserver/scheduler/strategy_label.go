@@ -13,7 +13,7 @@ import (
 // LabelMatchStrategy 标签匹配调度策略
 //
 // 根据 Task 的标签要求，选择标签完全匹配的节点。
-// 匹配规则：Task labels 必须是 Node labels 的子集。
+// 匹配规则：Task labels 必须是 Node labels 的子集，且 Task 容忍节点的全部污点。
 //
 // 场景：
 //   - 任务要求 env=prod，只调度到生产环境节点
@@ -42,7 +42,7 @@ func (s *LabelMatchStrategy) SelectNode(ctx context.Context, req *ScheduleReques
 
 	var matchedNodes []*model.Node
 	for _, node := range req.CandidateNodes {
-		if matchLabels(node, taskLabels) {
+		if matchLabels(node, taskLabels) && toleratesNode(node, req.Task) {
 			// 检查容量
 			maxConcurrent := nodemgr.GetNodeMaxConcurrent(node)
 			currentRunning := req.NodeRunning[node.ID]
@@ -78,6 +78,18 @@ func getTaskLabelsFromRequest(req *ScheduleRequest) map[string]string {
 	return req.Task.Labels
 }
 
+// toleratesNode 检查任务是否容忍节点的全部污点（见 task.scheduling.tolerations）
+func toleratesNode(node *model.Node, task *model.Task) bool {
+	if len(node.Taints) == 0 {
+		return true
+	}
+	var tolerations []model.Toleration
+	if task != nil {
+		tolerations = task.Scheduling.GetTolerations()
+	}
+	return model.ToleratesTaints(node.Taints, tolerations)
+}
+
 // matchLabels 检查节点是否满足任务的标签要求
 func matchLabels(node *model.Node, taskLabels map[string]string) bool {
 	if len(taskLabels) == 0 {
@@ -93,8 +105,11 @@ func matchLabels(node *model.Node, taskLabels map[string]string) bool {
 		}
 	}
 
-	// 检查每个任务标签
+	// 检查每个任务标签（适配器能力要求由调度前的能力过滤处理）
 	for key, value := range taskLabels {
+		if key == model.LabelAdapterFeatures || key == model.LabelAdapterMinVersion {
+			continue
+		}
 		if nodeValue, ok := nodeLabels[key]; !ok || nodeValue != value {
 			return false
 		}
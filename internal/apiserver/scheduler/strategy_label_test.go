@@ -100,3 +100,45 @@ func TestLabelMatchStrategy_SelectNode(t *testing.T) {
 		})
 	}
 }
+
+func TestLabelMatchStrategy_Taints(t *testing.T) {
+	ctx := context.Background()
+	strategy := NewLabelMatchStrategy(false)
+
+	tainted := createTestNode("node-ml", map[string]string{"gpu": "true"}, 5)
+	tainted.Taints = []model.NodeTaint{{Key: "dedicated", Value: "ml", Effect: model.TaintEffectNoSchedule}}
+	nodes := []*model.Node{tainted, createTestNode("node-2", map[string]string{"gpu": "true"}, 5)}
+
+	tests := []struct {
+		name        string
+		tolerations []model.Toleration
+		wantNode    string
+	}{
+		{"未容忍时跳过带污点的节点", nil, "node-2"},
+		{"容忍后可调度到带污点的节点", []model.Toleration{{Key: "dedicated", Value: "ml"}}, "node-ml"},
+		{"值不同不容忍", []model.Toleration{{Key: "dedicated", Value: "web"}}, "node-2"},
+		{"Exists 容忍任意值", []model.Toleration{{Key: "dedicated", Operator: model.TolerationOpExists}}, "node-ml"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createTestTask("task-1", map[string]string{"gpu": "true"})
+			if tt.tolerations != nil {
+				task.Scheduling = &model.TaskScheduling{Tolerations: tt.tolerations}
+			}
+			node, _ := strategy.SelectNode(ctx, &ScheduleRequest{Task: task, CandidateNodes: nodes, NodeRunning: map[string]int{}})
+			if node == nil || node.ID != tt.wantNode {
+				t.Errorf("SelectNode() = %v, want %s", node, tt.wantNode)
+			}
+		})
+	}
+
+	// 只有带污点的节点时不选择
+	node, _ := strategy.SelectNode(ctx, &ScheduleRequest{
+		Task:           createTestTask("task-1", nil),
+		CandidateNodes: []*model.Node{tainted},
+		NodeRunning:    map[string]int{},
+	})
+	if node != nil {
+		t.Errorf("SelectNode() = %s, want nil", node.ID)
+	}
+}
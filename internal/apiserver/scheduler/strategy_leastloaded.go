@@ -0,0 +1,94 @@
+// Package scheduler 最低资源利用率调度策略
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+)
+
+// DefaultLeastLoadedWindow least_loaded 策略默认的指标统计窗口
+const DefaultLeastLoadedWindow = 5 * time.Minute
+
+// NodeMetricsSource 节点系统资源指标的滚动窗口（通常为 Redis 中的 node_metrics:{node_id}）
+type NodeMetricsSource interface {
+	ListNodeMetrics(ctx context.Context, nodeID string, since time.Time) ([]*model.NodeMetrics, error)
+}
+
+// LeastLoadedStrategy 最低资源利用率调度策略
+//
+// 按节点真实资源利用率（CPU、内存、每核负载中的最大值）选择最空闲的节点，
+// 而不是只比较运行中的 Run 数量。
+//
+// 利用率来源（依次回退）：
+//   - 指标窗口内采样的平均值
+//   - 最近一次心跳上报的采样（capacity.metrics）
+//   - 运行中 Run 数 / 最大并发数（旧版本节点不上报指标）
+//
+// 利用率相同时选择运行中 Run 更少的节点。
+type LeastLoadedStrategy struct {
+	metrics NodeMetricsSource
+	window  time.Duration
+}
+
+// NewLeastLoadedStrategy 创建最低资源利用率策略（window <= 0 时使用默认窗口）
+func NewLeastLoadedStrategy(window time.Duration) *LeastLoadedStrategy {
+	if window <= 0 {
+		window = DefaultLeastLoadedWindow
+	}
+	return &LeastLoadedStrategy{window: window}
+}
+
+// SetMetricsSource 设置节点指标窗口（nil 时只使用心跳中的最新采样）
+func (s *LeastLoadedStrategy) SetMetricsSource(source NodeMetricsSource) {
+	s.metrics = source
+}
+
+// Name 返回策略名称
+func (s *LeastLoadedStrategy) Name() string {
+	return "least_loaded"
+}
+
+// SelectNode 选择资源利用率最低且有空闲槽位的节点
+func (s *LeastLoadedStrategy) SelectNode(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	var bestNode *model.Node
+	var bestUtil float64
+	bestRunning := 0
+
+	for _, node := range req.CandidateNodes {
+		maxConcurrent := nodemgr.GetNodeMaxConcurrent(node)
+		running := req.NodeRunning[node.ID]
+		if running >= maxConcurrent {
+			continue
+		}
+
+		util := s.utilization(ctx, node, running, maxConcurrent)
+		if bestNode == nil || util < bestUtil || (util == bestUtil && running < bestRunning) {
+			bestNode, bestUtil, bestRunning = node, util, running
+		}
+	}
+
+	if bestNode == nil {
+		return nil, ""
+	}
+	return bestNode, "least_loaded"
+}
+
+// utilization 计算节点的资源利用率（0-100）
+func (s *LeastLoadedStrategy) utilization(ctx context.Context, node *model.Node, running, maxConcurrent int) float64 {
+	if s.metrics != nil {
+		samples, err := s.metrics.ListNodeMetrics(ctx, node.ID, time.Now().Add(-s.window))
+		if err != nil {
+			slog.ErrorContext(ctx, "scheduler.least_loaded.metrics_failed", "node_id", node.ID, "error", err)
+		} else if avg := model.AverageNodeMetrics(samples); avg != nil {
+			return avg.Utilization()
+		}
+	}
+	if latest := nodemgr.GetNodeMetrics(node); latest != nil {
+		return latest.Utilization()
+	}
+	return float64(running) / float64(maxConcurrent) * 100
+}
@@ -0,0 +1,146 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// fakeMetricsSource 按节点返回固定的指标采样
+type fakeMetricsSource map[string][]*model.NodeMetrics
+
+func (f fakeMetricsSource) ListNodeMetrics(_ context.Context, nodeID string, _ time.Time) ([]*model.NodeMetrics, error) {
+	return f[nodeID], nil
+}
+
+// createMetricsNode 创建心跳中带系统指标的测试节点
+func createMetricsNode(id string, maxConcurrent int, cpuPercent float64) *model.Node {
+	node := createTestNode(id, nil, maxConcurrent)
+	node.Capacity, _ = json.Marshal(map[string]interface{}{
+		"max_concurrent": maxConcurrent,
+		"metrics":        model.NodeMetrics{CPUPercent: cpuPercent, CPUs: 4},
+	})
+	return node
+}
+
+func TestLeastLoadedStrategy_SelectNode(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name        string
+		nodes       []*model.Node
+		nodeRunning map[string]int
+		metrics     fakeMetricsSource
+		wantNode    string
+	}{
+		{
+			name: "按心跳指标选择利用率最低的节点",
+			nodes: []*model.Node{
+				createMetricsNode("node-1", 10, 80),
+				createMetricsNode("node-2", 10, 20),
+				createMetricsNode("node-3", 10, 50),
+			},
+			nodeRunning: map[string]int{"node-1": 0, "node-2": 5, "node-3": 1},
+			wantNode:    "node-2",
+		},
+		{
+			name: "指标窗口平均值优先于心跳采样",
+			nodes: []*model.Node{
+				createMetricsNode("node-1", 10, 10),
+				createMetricsNode("node-2", 10, 30),
+			},
+			nodeRunning: map[string]int{},
+			metrics: fakeMetricsSource{
+				"node-1": {{CPUPercent: 90}, {CPUPercent: 70}},
+				"node-2": {{CPUPercent: 40}},
+			},
+			wantNode: "node-2",
+		},
+		{
+			name: "跳过没有空闲槽位的节点",
+			nodes: []*model.Node{
+				createMetricsNode("node-1", 2, 5),
+				createMetricsNode("node-2", 4, 60),
+			},
+			nodeRunning: map[string]int{"node-1": 2, "node-2": 1},
+			wantNode:    "node-2",
+		},
+		{
+			name: "无指标时按运行数占比",
+			nodes: []*model.Node{
+				createTestNode("node-1", nil, 4),
+				createTestNode("node-2", nil, 10),
+			},
+			nodeRunning: map[string]int{"node-1": 2, "node-2": 3},
+			wantNode:    "node-2",
+		},
+		{
+			name: "利用率相同时选择运行数更少的节点",
+			nodes: []*model.Node{
+				createMetricsNode("node-1", 10, 30),
+				createMetricsNode("node-2", 10, 30),
+			},
+			nodeRunning: map[string]int{"node-1": 3, "node-2": 1},
+			wantNode:    "node-2",
+		},
+		{
+			name: "所有节点已满",
+			nodes: []*model.Node{
+				createMetricsNode("node-1", 1, 10),
+			},
+			nodeRunning: map[string]int{"node-1": 1},
+			wantNode:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			strategy := NewLeastLoadedStrategy(0)
+			if tt.metrics != nil {
+				strategy.SetMetricsSource(tt.metrics)
+			}
+			node, reason := strategy.SelectNode(ctx, &ScheduleRequest{
+				CandidateNodes: tt.nodes,
+				NodeRunning:    tt.nodeRunning,
+			})
+
+			if tt.wantNode == "" {
+				if node != nil {
+					t.Errorf("expected nil node, got %s", node.ID)
+				}
+				return
+			}
+			if node == nil {
+				t.Fatalf("expected node %s, got nil", tt.wantNode)
+			}
+			if node.ID != tt.wantNode {
+				t.Errorf("expected node %s, got %s", tt.wantNode, node.ID)
+			}
+			if reason != "least_loaded" {
+				t.Errorf("reason = %s", reason)
+			}
+		})
+	}
+}
+
+func TestScheduler_SetStrategyInjectsNodeMetrics(t *testing.T) {
+	s := NewScheduler(nil, nil, nil, "test")
+	source := fakeMetricsSource{}
+	s.SetNodeMetrics(source)
+	s.SetStrategy(StrategyConfig{Chain: []string{"least_loaded"}})
+
+	strategies := s.strategyChain.Strategies()
+	if len(strategies) != 1 {
+		t.Fatalf("strategies = %d, want 1", len(strategies))
+	}
+	ll, ok := strategies[0].(*LeastLoadedStrategy)
+	if !ok {
+		t.Fatalf("strategy = %T", strategies[0])
+	}
+	if ll.metrics == nil || ll.window != DefaultLeastLoadedWindow {
+		t.Errorf("metrics=%v window=%v", ll.metrics, ll.window)
+	}
+}
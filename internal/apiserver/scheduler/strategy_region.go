@@ -0,0 +1,90 @@
+// Package scheduler 区域调度策略
+package scheduler
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+)
+
+// RegionStrategy 区域调度策略
+//
+// 任务声明 region 时优先选择本区域（region 标签相同）的节点，本区域没有可用节点时
+// 按区域的回退策略处理：
+//   - none：只允许本区域节点，没有可用节点时 Run 保持 queued
+//   - ordered：依次尝试 fallback_regions 中的区域，不使用列表以外的区域
+//   - any（默认）：依次尝试 fallback_regions 后交给链中后续策略，可调度到任意节点
+//
+// 区域未在 /api/v1/regions 中定义时按 any 处理。
+type RegionStrategy struct{}
+
+// NewRegionStrategy 创建区域策略
+func NewRegionStrategy() *RegionStrategy {
+	return &RegionStrategy{}
+}
+
+// Name 返回策略名称
+func (s *RegionStrategy) Name() string {
+	return "region"
+}
+
+// Filter 回退策略为 none / ordered 时只保留允许的区域的节点
+func (s *RegionStrategy) Filter(req *ScheduleRequest) []*model.Node {
+	region := requestRegion(req)
+	if region == nil || region.FallbackPolicy() == model.RegionFallbackAny {
+		return req.CandidateNodes
+	}
+	allowed := map[string]bool{region.ID: true}
+	if region.FallbackPolicy() == model.RegionFallbackOrdered {
+		for _, id := range region.FallbackRegions {
+			allowed[id] = true
+		}
+	}
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		if allowed[model.NodeRegion(node)] {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// SelectNode 依次在本区域与回退区域的可用节点中选择负载最低的节点
+func (s *RegionStrategy) SelectNode(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	region := requestRegion(req)
+	if region == nil {
+		return nil, ""
+	}
+	tiers := []string{region.ID}
+	if region.FallbackPolicy() != model.RegionFallbackNone {
+		tiers = append(tiers, region.FallbackRegions...)
+	}
+	for i, id := range tiers {
+		var nodes []*model.Node
+		for _, node := range req.CandidateNodes {
+			if model.NodeRegion(node) == id && eligibleNode(req, node) {
+				nodes = append(nodes, node)
+			}
+		}
+		if len(nodes) == 0 {
+			continue
+		}
+		reason := "region_local"
+		if i > 0 {
+			reason = "region_fallback"
+		}
+		return selectByLoadBalance(nodes, req.NodeRunning), reason
+	}
+	return nil, ""
+}
+
+// requestRegion 返回任务声明的区域（未声明时为 nil，未加载区域定义时按默认回退策略处理）
+func requestRegion(req *ScheduleRequest) *model.Region {
+	if req.Task == nil || req.Task.Region == "" {
+		return nil
+	}
+	if req.Region != nil && req.Region.ID == req.Task.Region {
+		return req.Region
+	}
+	return &model.Region{ID: req.Task.Region}
+}
@@ -0,0 +1,120 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestRegionStrategy_SelectNode(t *testing.T) {
+	ctx := context.Background()
+	strategy := NewRegionStrategy()
+
+	tests := []struct {
+		name       string
+		taskRegion string
+		region     *model.Region
+		running    map[string]int
+		wantNode   string
+		wantReason string
+		wantFilter int
+	}{
+		{
+			name:       "未声明区域",
+			wantFilter: 4,
+		},
+		{
+			name:       "优先本区域",
+			taskRegion: "cn-east",
+			region:     &model.Region{ID: "cn-east", Fallback: model.RegionFallbackNone},
+			wantNode:   "east-1",
+			wantReason: "region_local",
+			wantFilter: 1,
+		},
+		{
+			name:       "本区域已满时不回退",
+			taskRegion: "cn-east",
+			region:     &model.Region{ID: "cn-east", Fallback: model.RegionFallbackNone, FallbackRegions: []string{"cn-north"}},
+			running:    map[string]int{"east-1": 1},
+			wantFilter: 1,
+		},
+		{
+			name:       "按顺序回退",
+			taskRegion: "cn-east",
+			region:     &model.Region{ID: "cn-east", Fallback: model.RegionFallbackOrdered, FallbackRegions: []string{"us-west", "cn-north"}},
+			running:    map[string]int{"east-1": 1},
+			wantNode:   "west-1",
+			wantReason: "region_fallback",
+			wantFilter: 3,
+		},
+		{
+			name:       "回退区域均已满时交给后续策略",
+			taskRegion: "cn-east",
+			region:     &model.Region{ID: "cn-east", FallbackRegions: []string{"cn-north"}},
+			running:    map[string]int{"east-1": 1, "north-1": 1},
+			wantFilter: 4,
+		},
+		{
+			name:       "未定义的区域按 any 处理",
+			taskRegion: "cn-north",
+			wantNode:   "north-1",
+			wantReason: "region_local",
+			wantFilter: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createTestTask("task-1", nil)
+			task.Region = tt.taskRegion
+			running := tt.running
+			if running == nil {
+				running = map[string]int{}
+			}
+			req := &ScheduleRequest{
+				Task: task,
+				CandidateNodes: []*model.Node{
+					createTestNode("east-1", map[string]string{"region": "cn-east"}, 1),
+					createTestNode("north-1", map[string]string{"region": "cn-north"}, 1),
+					createTestNode("west-1", map[string]string{"region": "us-west"}, 1),
+					createTestNode("plain-1", nil, 1),
+				},
+				NodeRunning: running,
+				Region:      tt.region,
+			}
+
+			if got := len(strategy.Filter(req)); got != tt.wantFilter {
+				t.Errorf("Filter() = %d nodes, want %d", got, tt.wantFilter)
+			}
+			node, reason := strategy.SelectNode(ctx, req)
+			gotID := ""
+			if node != nil {
+				gotID = node.ID
+			}
+			if gotID != tt.wantNode || reason != tt.wantReason {
+				t.Errorf("SelectNode() = (%q, %q), want (%q, %q)", gotID, reason, tt.wantNode, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestStrategyChain_RegionUnsatisfied(t *testing.T) {
+	chain := NewStrategyChain(NewRegionStrategy(), NewLabelMatchStrategy(true))
+	task := createTestTask("task-1", nil)
+	task.Region = "cn-east"
+	req := &ScheduleRequest{
+		Task:           task,
+		CandidateNodes: []*model.Node{createTestNode("plain-1", nil, 1)},
+		NodeRunning:    map[string]int{},
+		Region:         &model.Region{ID: "cn-east", Fallback: model.RegionFallbackNone},
+	}
+
+	node, _, reason := chain.Select(context.Background(), req)
+	if node != nil || reason != "region_unsatisfied" {
+		t.Errorf("Select() = (%v, %q), want (nil, region_unsatisfied)", node, reason)
+	}
+	if got := chain.rejections(req)["plain-1"]; got != model.SchedulingRejectRegion {
+		t.Errorf("rejections = %q, want %q", got, model.SchedulingRejectRegion)
+	}
+}
@@ -0,0 +1,121 @@
+// Package scheduler 分散调度策略
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+
+	"agents-admin/internal/shared/model"
+)
+
+// SpreadStrategy 分散调度策略
+//
+// 按 task.scheduling.spread.label_key 的节点标签值划分拓扑域（如 zone），
+// 使任务组（该任务及同一父任务下的任务）的活跃 Run 在各域间均匀分布。
+//
+// 拓扑域取自满足标签要求且有剩余容量的候选节点。mode=required 时排除未设置该标签的节点，
+// 以及放入后与最少的域相差超过 max_skew 的域中的节点；mode=preferred 时选择活跃 Run 最少的域。
+type SpreadStrategy struct{}
+
+// NewSpreadStrategy 创建分散策略
+func NewSpreadStrategy() *SpreadStrategy {
+	return &SpreadStrategy{}
+}
+
+// Name 返回策略名称
+func (s *SpreadStrategy) Name() string {
+	return "spread"
+}
+
+// Filter 必须分散时只保留不超出最大差值的域中的节点
+func (s *SpreadStrategy) Filter(req *ScheduleRequest) []*model.Node {
+	spread := spreadConstraint(req)
+	if !spread.IsRequired() {
+		return req.CandidateNodes
+	}
+	counts, minCount := spreadDomains(req, spread.LabelKey)
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		domain, ok := nodeLabel(node, spread.LabelKey)
+		if !ok {
+			continue
+		}
+		if _, known := counts[domain]; known && counts[domain]+1-minCount > spread.Skew() {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+// SelectNode 在活跃 Run 最少的域中选择负载最低的节点
+func (s *SpreadStrategy) SelectNode(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	spread := spreadConstraint(req)
+	if spread == nil {
+		return nil, ""
+	}
+	counts, minCount := spreadDomains(req, spread.LabelKey)
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		domain, ok := nodeLabel(node, spread.LabelKey)
+		if ok && counts[domain] == minCount && eligibleNode(req, node) {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, ""
+	}
+	return selectByLoadBalance(nodes, req.NodeRunning), "spread"
+}
+
+// spreadDomains 统计各拓扑域的任务组活跃 Run 数，返回计数与最小值
+func spreadDomains(req *ScheduleRequest, labelKey string) (map[string]int, int) {
+	counts := make(map[string]int)
+	for _, node := range req.CandidateNodes {
+		if !eligibleNode(req, node) {
+			continue
+		}
+		if domain, ok := nodeLabel(node, labelKey); ok {
+			counts[domain] += 0
+		}
+	}
+	if req.Placement != nil {
+		for _, node := range req.CandidateNodes {
+			domain, ok := nodeLabel(node, labelKey)
+			if !ok {
+				continue
+			}
+			if _, known := counts[domain]; known {
+				counts[domain] += req.Placement.GroupRuns[node.ID]
+			}
+		}
+	}
+	minCount := -1
+	for _, c := range counts {
+		if minCount < 0 || c < minCount {
+			minCount = c
+		}
+	}
+	return counts, minCount
+}
+
+// spreadConstraint 返回任务的分散约束
+func spreadConstraint(req *ScheduleRequest) *model.SpreadConstraint {
+	if req.Task == nil || req.Task.Scheduling == nil {
+		return nil
+	}
+	return req.Task.Scheduling.Spread
+}
+
+// nodeLabel 读取节点标签值
+func nodeLabel(node *model.Node, key string) (string, bool) {
+	if len(node.Labels) == 0 {
+		return "", false
+	}
+	var labels map[string]string
+	if err := json.Unmarshal(node.Labels, &labels); err != nil {
+		return "", false
+	}
+	value, ok := labels[key]
+	return value, ok
+}
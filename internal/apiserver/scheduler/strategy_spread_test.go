@@ -0,0 +1,140 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestSpreadStrategy_SelectNode(t *testing.T) {
+	ctx := context.Background()
+	strategy := NewSpreadStrategy()
+
+	nodes := func() []*model.Node {
+		return []*model.Node{
+			createTestNode("a-1", map[string]string{"zone": "a"}, 4),
+			createTestNode("a-2", map[string]string{"zone": "a"}, 4),
+			createTestNode("b-1", map[string]string{"zone": "b"}, 4),
+			createTestNode("plain", nil, 4),
+		}
+	}
+
+	tests := []struct {
+		name       string
+		spread     *model.SpreadConstraint
+		groupRuns  map[string]int
+		running    map[string]int
+		wantNode   string
+		wantFilter []string
+	}{
+		{
+			name:       "选择活跃 Run 最少的域",
+			spread:     &model.SpreadConstraint{LabelKey: "zone"},
+			groupRuns:  map[string]int{"a-1": 1},
+			wantNode:   "b-1",
+			wantFilter: []string{"a-1", "a-2", "b-1", "plain"},
+		},
+		{
+			name:       "域内按负载选择",
+			spread:     &model.SpreadConstraint{LabelKey: "zone"},
+			groupRuns:  map[string]int{"b-1": 1},
+			running:    map[string]int{"a-1": 3},
+			wantNode:   "a-2",
+			wantFilter: []string{"a-1", "a-2", "b-1", "plain"},
+		},
+		{
+			name:       "必须分散时排除超出差值的域和无标签节点",
+			spread:     &model.SpreadConstraint{LabelKey: "zone", Mode: model.SchedulingModeRequired},
+			groupRuns:  map[string]int{"a-1": 1},
+			wantNode:   "b-1",
+			wantFilter: []string{"b-1"},
+		},
+		{
+			name:       "差值允许时保留多个域",
+			spread:     &model.SpreadConstraint{LabelKey: "zone", MaxSkew: 2, Mode: model.SchedulingModeRequired},
+			groupRuns:  map[string]int{"a-1": 1},
+			wantNode:   "b-1",
+			wantFilter: []string{"a-1", "a-2", "b-1"},
+		},
+		{
+			name:       "已满的域不参与计算",
+			spread:     &model.SpreadConstraint{LabelKey: "zone", Mode: model.SchedulingModeRequired},
+			groupRuns:  map[string]int{"a-1": 1},
+			running:    map[string]int{"a-1": 1, "b-1": 4},
+			wantNode:   "a-2",
+			wantFilter: []string{"a-1", "a-2", "b-1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createTestTask("task-1", nil)
+			task.Scheduling = &model.TaskScheduling{Spread: tt.spread}
+			running := tt.running
+			if running == nil {
+				running = map[string]int{}
+			}
+			req := &ScheduleRequest{
+				Task:           task,
+				CandidateNodes: nodes(),
+				NodeRunning:    running,
+				Placement:      &Placement{GroupRuns: tt.groupRuns},
+			}
+
+			filtered := strategy.Filter(req)
+			if len(filtered) != len(tt.wantFilter) {
+				t.Fatalf("Filter() = %d nodes, want %v", len(filtered), tt.wantFilter)
+			}
+			for i, node := range filtered {
+				if node.ID != tt.wantFilter[i] {
+					t.Errorf("Filter()[%d] = %s, want %s", i, node.ID, tt.wantFilter[i])
+				}
+			}
+			node, _ := strategy.SelectNode(ctx, req)
+			if node == nil || node.ID != tt.wantNode {
+				t.Errorf("SelectNode() = %v, want %s", node, tt.wantNode)
+			}
+		})
+	}
+}
+
+func TestStrategyChain_Constraints(t *testing.T) {
+	ctx := context.Background()
+	chain := NewStrategyChain(NewAntiAffinityStrategy(), NewSpreadStrategy(), NewLabelMatchStrategy(true))
+
+	task := createTestTask("task-1", nil)
+	task.Scheduling = &model.TaskScheduling{
+		AntiAffinity: &model.TaskAntiAffinity{Mode: model.SchedulingModeRequired},
+		Spread:       &model.SpreadConstraint{LabelKey: "zone", Mode: model.SchedulingModeRequired},
+	}
+	req := &ScheduleRequest{
+		Task: task,
+		CandidateNodes: []*model.Node{
+			createTestNode("a-1", map[string]string{"zone": "a"}, 2),
+			createTestNode("b-1", map[string]string{"zone": "b"}, 2),
+			createTestNode("plain", nil, 2),
+		},
+		NodeRunning: map[string]int{},
+		Placement: &Placement{
+			TaskRuns:  map[string]int{"b-1": 1},
+			GroupRuns: map[string]int{"b-1": 1},
+		},
+	}
+
+	node, strategy, _ := chain.Select(ctx, req)
+	if node == nil || node.ID != "a-1" || strategy != "anti_affinity" {
+		t.Fatalf("Select() = %v, %q, want a-1 by anti_affinity", node, strategy)
+	}
+	rejected := chain.rejections(req)
+	if rejected["b-1"] != model.SchedulingRejectAnti || rejected["plain"] != model.SchedulingRejectSpread || rejected["a-1"] != "" {
+		t.Errorf("rejections() = %v", rejected)
+	}
+
+	// 候选节点被全部过滤
+	req.Placement.TaskRuns["a-1"] = 1
+	node, _, reason := chain.Select(ctx, req)
+	if node != nil || reason != "spread_unsatisfied" {
+		t.Errorf("Select() = %v, %q, want spread_unsatisfied", node, reason)
+	}
+}
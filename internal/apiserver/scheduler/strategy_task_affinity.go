@@ -0,0 +1,71 @@
+// Package scheduler 任务亲和调度策略
+package scheduler
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+)
+
+// TaskAffinityStrategy 任务亲和调度策略
+//
+// 将 Run 调度到相关任务（task.scheduling.affinity 指定的任务或同一父任务下的任务）
+// 执行过的节点，复用节点上已有的工作空间与缓存。
+//
+// 相关任务尚未在任何节点执行过时不做限制；mode=required 时只允许亲和节点，
+// mode=preferred 时亲和节点均不可用则交给链中后续策略。
+type TaskAffinityStrategy struct{}
+
+// NewTaskAffinityStrategy 创建任务亲和策略
+func NewTaskAffinityStrategy() *TaskAffinityStrategy {
+	return &TaskAffinityStrategy{}
+}
+
+// Name 返回策略名称
+func (s *TaskAffinityStrategy) Name() string {
+	return "task_affinity"
+}
+
+// Filter 必须亲和时只保留亲和节点
+func (s *TaskAffinityStrategy) Filter(req *ScheduleRequest) []*model.Node {
+	if !s.active(req) || !taskAffinity(req).IsRequired() {
+		return req.CandidateNodes
+	}
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		if req.Placement.AffinityNodes[node.ID] {
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// SelectNode 在可用的亲和节点中选择负载最低的节点
+func (s *TaskAffinityStrategy) SelectNode(ctx context.Context, req *ScheduleRequest) (*model.Node, string) {
+	if !s.active(req) {
+		return nil, ""
+	}
+	var nodes []*model.Node
+	for _, node := range req.CandidateNodes {
+		if req.Placement.AffinityNodes[node.ID] && eligibleNode(req, node) {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		return nil, ""
+	}
+	return selectByLoadBalance(nodes, req.NodeRunning), "task_affinity"
+}
+
+// active 任务声明了亲和且相关任务已有执行节点
+func (s *TaskAffinityStrategy) active(req *ScheduleRequest) bool {
+	return taskAffinity(req) != nil && req.Placement != nil && len(req.Placement.AffinityNodes) > 0
+}
+
+// taskAffinity 返回任务的亲和约束
+func taskAffinity(req *ScheduleRequest) *model.TaskAffinity {
+	if req.Task == nil || req.Task.Scheduling == nil {
+		return nil
+	}
+	return req.Task.Scheduling.Affinity
+}
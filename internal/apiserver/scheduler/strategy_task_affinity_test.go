@@ -0,0 +1,89 @@
+package scheduler
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestTaskAffinityStrategy_SelectNode(t *testing.T) {
+	ctx := context.Background()
+	strategy := NewTaskAffinityStrategy()
+
+	tests := []struct {
+		name       string
+		affinity   *model.TaskAffinity
+		placement  *Placement
+		running    map[string]int
+		wantNode   string
+		wantFilter int
+	}{
+		{
+			name:       "选择亲和节点",
+			affinity:   &model.TaskAffinity{Siblings: true},
+			placement:  &Placement{AffinityNodes: map[string]bool{"node-2": true}},
+			wantNode:   "node-2",
+			wantFilter: 3,
+		},
+		{
+			name:       "亲和节点已满时交给后续策略",
+			affinity:   &model.TaskAffinity{Siblings: true},
+			placement:  &Placement{AffinityNodes: map[string]bool{"node-2": true}},
+			running:    map[string]int{"node-2": 2},
+			wantFilter: 3,
+		},
+		{
+			name:       "必须亲和时只保留亲和节点",
+			affinity:   &model.TaskAffinity{TaskIDs: []string{"task-a"}, Mode: model.SchedulingModeRequired},
+			placement:  &Placement{AffinityNodes: map[string]bool{"node-2": true, "node-3": true}},
+			running:    map[string]int{"node-2": 1},
+			wantNode:   "node-3",
+			wantFilter: 2,
+		},
+		{
+			name:       "相关任务尚未执行时不限制",
+			affinity:   &model.TaskAffinity{Siblings: true, Mode: model.SchedulingModeRequired},
+			placement:  &Placement{AffinityNodes: map[string]bool{}},
+			wantFilter: 3,
+		},
+		{
+			name:       "未声明亲和",
+			placement:  &Placement{AffinityNodes: map[string]bool{"node-2": true}},
+			wantFilter: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := createTestTask("task-1", nil)
+			task.Scheduling = &model.TaskScheduling{Affinity: tt.affinity}
+			running := tt.running
+			if running == nil {
+				running = map[string]int{}
+			}
+			req := &ScheduleRequest{
+				Task: task,
+				CandidateNodes: []*model.Node{
+					createTestNode("node-1", nil, 2),
+					createTestNode("node-2", nil, 2),
+					createTestNode("node-3", nil, 2),
+				},
+				NodeRunning: running,
+				Placement:   tt.placement,
+			}
+
+			if got := len(strategy.Filter(req)); got != tt.wantFilter {
+				t.Errorf("Filter() = %d nodes, want %d", got, tt.wantFilter)
+			}
+			node, _ := strategy.SelectNode(ctx, req)
+			gotID := ""
+			if node != nil {
+				gotID = node.ID
+			}
+			if gotID != tt.wantNode {
+				t.Errorf("SelectNode() = %q, want %q", gotID, tt.wantNode)
+			}
+		})
+	}
+}
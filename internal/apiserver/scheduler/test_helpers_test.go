@@ -25,3 +25,6 @@ func createTestTask(id string, labels map[string]string) *model.Task {
 		Labels: labels,
 	}
 }
+
+// testSnapshot 通过校验的最小 Run 快照
+var testSnapshot = json.RawMessage(`{"agent":{"type":"qwen-code"},"prompt":"p"}`)
@@ -0,0 +1,330 @@
+// Package secret 运行时密钥领域 - HTTP 处理
+//
+// 密钥值由存储层信封加密后落库（fieldcrypt.WrapStore），任何 GET 响应都不返回明文。
+// 密钥的管理接口仅限管理员。任务通过 secrets 字段按名称引用密钥，NodeManager 执行 Run 时通过
+// POST /api/v1/secrets/resolve 批量解析明文并以环境变量注入容器，
+// 该接口只接受节点专属凭证，且只返回分配给该节点的 Run 引用的密钥。
+package secret
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// Store 定义密钥 handler 需要的存储接口（用于测试 mock），读写的密钥值为明文，由存储层加密
+type Store interface {
+	CreateSecret(ctx context.Context, secret *model.Secret) error
+	GetSecret(ctx context.Context, id string) (*model.Secret, error)
+	GetSecretByName(ctx context.Context, name string) (*model.Secret, error)
+	ListSecrets(ctx context.Context) ([]*model.Secret, error)
+	UpdateSecret(ctx context.Context, secret *model.Secret) error
+	DeleteSecret(ctx context.Context, id string) error
+	ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error)
+}
+
+// maxResolveNames 单次解析的最大密钥数
+const maxResolveNames = 100
+
+// errEncryptionDisabled 未启用存储层加密时写入/解析接口返回的错误信息
+const errEncryptionDisabled = "encryption not configured: set MASTER_KEY or encryption.provider"
+
+// Handler 密钥领域 HTTP 处理器
+type Handler struct {
+	store     Store
+	encrypted bool // 存储层是否加密密钥值，未启用时写入/解析接口返回 503，避免明文落库
+}
+
+// NewHandler 创建密钥处理器，encrypted 表示 store 已由 fieldcrypt.WrapStore 包装
+func NewHandler(store Store, encrypted bool) *Handler {
+	if !encrypted {
+		log.Printf("[secret] encryption not configured (MASTER_KEY or encryption.provider), secret management disabled")
+	}
+	return &Handler{store: store, encrypted: encrypted}
+}
+
+// RegisterRoutes 注册密钥相关路由（管理接口仅限管理员，解析接口仅限节点）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/secrets", auth.AdminOnly(h.List))
+	mux.HandleFunc("POST /api/v1/secrets", auth.AdminOnly(h.Create))
+	mux.HandleFunc("POST /api/v1/secrets/resolve", h.Resolve)
+	mux.HandleFunc("GET /api/v1/secrets/{id}", auth.AdminOnly(h.Get))
+	mux.HandleFunc("PUT /api/v1/secrets/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/secrets/{id}", auth.AdminOnly(h.Delete))
+}
+
+// secretRequest 创建/更新密钥请求
+type secretRequest struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Value       string `json:"value,omitempty"` // 密钥明文（仅写入）
+}
+
+// List 获取密钥列表
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	secrets, err := h.store.ListSecrets(r.Context())
+	if err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "ListSecrets", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list secrets")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"secrets": secrets})
+}
+
+// Create 创建密钥
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	if !h.encrypted {
+		writeError(w, http.StatusServiceUnavailable, errEncryptionDisabled)
+		return
+	}
+	var req secretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if !model.IsValidSecretName(req.Name) {
+		writeError(w, http.StatusBadRequest, "invalid name: must be a valid environment variable name")
+		return
+	}
+	if req.Value == "" {
+		writeError(w, http.StatusBadRequest, "value is required")
+		return
+	}
+
+	existing, err := h.store.GetSecretByName(r.Context(), req.Name)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "GetSecretByName", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create secret")
+		return
+	}
+	if existing != nil {
+		writeError(w, http.StatusConflict, "secret name already exists")
+		return
+	}
+
+	now := time.Now()
+	secret := &model.Secret{
+		ID:             generateID("sec"),
+		Name:           req.Name,
+		Description:    req.Description,
+		EncryptedValue: req.Value,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := h.store.CreateSecret(r.Context(), secret); err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "CreateSecret", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create secret")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "secret.created", "name", secret.Name)
+	writeJSON(w, http.StatusCreated, secret)
+}
+
+// Get 获取密钥详情（不含明文）
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	secret, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, secret)
+}
+
+// Update 更新密钥，value 为空时保留原值
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	if !h.encrypted {
+		writeError(w, http.StatusServiceUnavailable, errEncryptionDisabled)
+		return
+	}
+	secret, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+
+	var req secretRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if name := strings.TrimSpace(req.Name); name != "" && name != secret.Name {
+		if !model.IsValidSecretName(name) {
+			writeError(w, http.StatusBadRequest, "invalid name: must be a valid environment variable name")
+			return
+		}
+		other, err := h.store.GetSecretByName(r.Context(), name)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "secret", "op", "GetSecretByName", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to update secret")
+			return
+		}
+		if other != nil {
+			writeError(w, http.StatusConflict, "secret name already exists")
+			return
+		}
+		secret.Name = name
+	}
+	secret.Description = req.Description
+	if req.Value != "" {
+		secret.EncryptedValue = req.Value
+	}
+	secret.UpdatedAt = time.Now()
+
+	if err := h.store.UpdateSecret(r.Context(), secret); err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "UpdateSecret", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update secret")
+		return
+	}
+	writeJSON(w, http.StatusOK, secret)
+}
+
+// Delete 删除密钥，不存在时返回 404
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	secret, ok := h.load(w, r)
+	if !ok {
+		return
+	}
+	if err := h.store.DeleteSecret(r.Context(), secret.ID); err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "DeleteSecret", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to delete secret")
+		return
+	}
+	slog.InfoContext(r.Context(), "secret.deleted", "id", secret.ID, "name", secret.Name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Resolve 按名称批量解析密钥明文（NodeManager 专用）
+// POST /api/v1/secrets/resolve
+//
+// 请求：{"names": ["A", "B"]}，响应：{"secrets": {"A": "...", "B": "..."}}。
+// 任一名称不存在时返回 404（Run 不应在缺少密钥的情况下启动）。
+// 只接受节点专属凭证（加入令牌换取的 Token 或节点客户端证书）：用户会话、共享密钥与无认证模式的请求返回 403；
+// 名称须被当前分配给该节点的 Run（assigned / running / paused）的快照 secrets 引用，否则返回 403。
+func (h *Handler) Resolve(w http.ResponseWriter, r *http.Request) {
+	node := auth.GetNodeIdentity(r.Context())
+	if node == nil || node.ID == "" {
+		writeError(w, http.StatusForbidden, "secret values are only available to node managers with a per-node credential")
+		return
+	}
+	if !h.encrypted {
+		writeError(w, http.StatusServiceUnavailable, errEncryptionDisabled)
+		return
+	}
+
+	var req struct {
+		Names []string `json:"names"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || len(req.Names) == 0 {
+		writeError(w, http.StatusBadRequest, "names is required")
+		return
+	}
+	if len(req.Names) > maxResolveNames {
+		writeError(w, http.StatusBadRequest, "too many names")
+		return
+	}
+	referenced, err := h.referencedSecrets(r.Context(), node.ID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "ListRunsByNode", "node_id", node.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to resolve secrets")
+		return
+	}
+	for _, name := range req.Names {
+		if !referenced[name] {
+			slog.WarnContext(r.Context(), "secret.resolve.denied", "node_id", node.ID, "name", name)
+			writeError(w, http.StatusForbidden, "secret is not referenced by a run assigned to this node: "+name)
+			return
+		}
+	}
+
+	values := make(map[string]string, len(req.Names))
+	for _, name := range req.Names {
+		secret, err := h.store.GetSecretByName(r.Context(), name)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "secret", "op", "GetSecretByName", "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to resolve secrets")
+			return
+		}
+		if secret == nil {
+			writeError(w, http.StatusNotFound, "secret not found: "+name)
+			return
+		}
+		values[name] = secret.EncryptedValue
+	}
+
+	slog.InfoContext(r.Context(), "secret.resolved", "names", req.Names)
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{"secrets": values})
+}
+
+// referencedSecrets 当前分配给节点的 Run 在快照中引用的密钥名称
+func (h *Handler) referencedSecrets(ctx context.Context, nodeID string) (map[string]bool, error) {
+	runs, err := h.store.ListRunsByNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool)
+	for _, run := range runs {
+		snapshot, err := parseSnapshot(run.Snapshot)
+		if err != nil {
+			continue // 快照不合法的 Run 无法执行，不引用任何密钥
+		}
+		for _, name := range snapshot.Secrets {
+			names[name] = true
+		}
+	}
+	return names, nil
+}
+
+// parseSnapshot 按节点收到的格式解析 Run 快照（旧版本格式先升级，见 model.NormalizeSnapshot）
+func parseSnapshot(raw json.RawMessage) (*model.RunSnapshot, error) {
+	normalized, err := model.NormalizeSnapshot(raw)
+	if err != nil {
+		return nil, err
+	}
+	return model.ParseRunSnapshot(normalized)
+}
+
+// load 按路径参数加载密钥，不存在时写入 404
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) (*model.Secret, bool) {
+	id := r.PathValue("id")
+	secret, err := h.store.GetSecret(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "secret", "op", "GetSecret", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get secret")
+		return nil, false
+	}
+	if secret == nil {
+		writeError(w, http.StatusNotFound, "secret not found")
+		return nil, false
+	}
+	return secret, true
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,265 @@
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/fieldcrypt"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// mockStore 内存密钥存储（保存的是落库内容，经 fieldcrypt.WrapStore 包装后为密文）
+type mockStore struct {
+	storage.PersistentStore // 嵌入接口，未实现的方法会 panic（测试中不应调用）
+
+	secrets   map[string]*model.Secret
+	runs      map[string][]*model.Run // node_id -> 分配给节点的 Run
+	lookupErr error                   // GetSecretByName 返回的错误
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{secrets: make(map[string]*model.Secret), runs: make(map[string][]*model.Run)}
+}
+
+func (m *mockStore) CreateSecret(_ context.Context, s *model.Secret) error {
+	m.secrets[s.ID] = s
+	return nil
+}
+func (m *mockStore) GetSecret(_ context.Context, id string) (*model.Secret, error) {
+	return m.secrets[id], nil
+}
+func (m *mockStore) GetSecretByName(_ context.Context, name string) (*model.Secret, error) {
+	if m.lookupErr != nil {
+		return nil, m.lookupErr
+	}
+	for _, s := range m.secrets {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, nil
+}
+func (m *mockStore) ListSecrets(_ context.Context) ([]*model.Secret, error) {
+	var list []*model.Secret
+	for _, s := range m.secrets {
+		list = append(list, s)
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateSecret(_ context.Context, s *model.Secret) error {
+	m.secrets[s.ID] = s
+	return nil
+}
+func (m *mockStore) DeleteSecret(_ context.Context, id string) error {
+	delete(m.secrets, id)
+	return nil
+}
+func (m *mockStore) ListRunsByNode(_ context.Context, nodeID string) ([]*model.Run, error) {
+	return m.runs[nodeID], nil
+}
+
+// assignRun 将引用 secrets 的 Run 分配给节点
+func (m *mockStore) assignRun(nodeID string, secrets ...string) {
+	snapshot, _ := json.Marshal(model.RunSnapshot{Agent: model.SnapshotAgent{Type: "qwen-code"}, Prompt: "p", Secrets: secrets})
+	m.runs[nodeID] = append(m.runs[nodeID], &model.Run{ID: "run-" + nodeID, NodeID: &nodeID, Status: model.RunStatusRunning, Snapshot: snapshot})
+}
+
+// newTestMux 注册路由，以无认证模式（未配置 JWT 密钥）处理请求；encrypted 时存储层以本地 KEK 加密
+func newTestMux(t *testing.T, store *mockStore, encrypted bool) http.Handler {
+	t.Helper()
+	var handlerStore Store = store
+	if encrypted {
+		kek, _ := fieldcrypt.NewLocalKEK("test-kek")
+		keys, err := fieldcrypt.NewKeyring(kek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handlerStore = fieldcrypt.WrapStore(store, keys)
+	}
+	mux := http.NewServeMux()
+	NewHandler(handlerStore, encrypted).RegisterRoutes(mux)
+	return auth.Middleware(auth.Config{})(mux)
+}
+
+// resolveAs 以节点身份解析密钥（nodeID 为空表示共享密钥）
+func resolveAs(mux http.Handler, nodeID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", "/api/v1/secrets/resolve", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req.WithContext(auth.WithNodeIdentity(req.Context(), nodeID)))
+	return w
+}
+
+func createSecret(t *testing.T, mux http.Handler, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/secrets", strings.NewReader(body)))
+	return w
+}
+
+func TestCreateSecret_ValueNeverReturned(t *testing.T) {
+	store := newMockStore()
+	mux := newTestMux(t, store, true)
+
+	w := createSecret(t, mux, `{"name":"OPENAI_API_KEY","value":"sk-topsecret"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "sk-topsecret") || strings.Contains(w.Body.String(), "encrypted_value") {
+		t.Errorf("创建响应泄露了密钥: %s", w.Body.String())
+	}
+
+	var created model.Secret
+	json.Unmarshal(w.Body.Bytes(), &created)
+	stored := store.secrets[created.ID]
+	if stored == nil || !fieldcrypt.IsSealed(stored.EncryptedValue) || strings.Contains(stored.EncryptedValue, "sk-topsecret") {
+		t.Fatalf("密钥未加密存储: %+v", stored)
+	}
+
+	for _, path := range []string{"/api/v1/secrets", "/api/v1/secrets/" + created.ID} {
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "sk-topsecret") || strings.Contains(w.Body.String(), stored.EncryptedValue) {
+			t.Errorf("GET %s 泄露了密钥: %d %s", path, w.Code, w.Body.String())
+		}
+	}
+
+	if w = createSecret(t, mux, `{"name":"OPENAI_API_KEY","value":"x"}`); w.Code != http.StatusConflict {
+		t.Errorf("重复名称 status = %d, 期望 409", w.Code)
+	}
+}
+
+func TestCreateSecret_Validation(t *testing.T) {
+	mux := newTestMux(t, newMockStore(), true)
+	for _, body := range []string{
+		`{"name":"bad-name","value":"x"}`,
+		`{"name":"1ST","value":"x"}`,
+		`{"name":"TOKEN"}`,
+	} {
+		if w := createSecret(t, mux, body); w.Code != http.StatusBadRequest {
+			t.Errorf("body %s: status = %d, 期望 400", body, w.Code)
+		}
+	}
+
+	if w := createSecret(t, newTestMux(t, newMockStore(), false), `{"name":"TOKEN","value":"x"}`); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("未配置主密钥 status = %d, 期望 503", w.Code)
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	store := newMockStore()
+	store.assignRun("node-1", "TOKEN_A", "TOKEN_B", "MISSING")
+	store.assignRun("node-2", "TOKEN_C")
+	mux := newTestMux(t, store, true)
+	createSecret(t, mux, `{"name":"TOKEN_A","value":"aaa"}`)
+	createSecret(t, mux, `{"name":"TOKEN_B","value":"bbb"}`)
+	createSecret(t, mux, `{"name":"TOKEN_C","value":"ccc"}`)
+
+	w := resolveAs(mux, "node-1", `{"names":["TOKEN_A","TOKEN_B"]}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("resolve status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var resp struct {
+		Secrets map[string]string `json:"secrets"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Secrets["TOKEN_A"] != "aaa" || resp.Secrets["TOKEN_B"] != "bbb" {
+		t.Errorf("resolved = %v", resp.Secrets)
+	}
+
+	// 分配给其他节点的 Run 引用的密钥
+	if w := resolveAs(mux, "node-1", `{"names":["TOKEN_A","TOKEN_C"]}`); w.Code != http.StatusForbidden || strings.Contains(w.Body.String(), "ccc") {
+		t.Errorf("未引用的密钥 status = %d, body = %s, 期望 403", w.Code, w.Body.String())
+	}
+	// 没有分配 Run 的节点
+	if w := resolveAs(mux, "node-3", `{"names":["TOKEN_A"]}`); w.Code != http.StatusForbidden {
+		t.Errorf("无 Run 的节点 status = %d, 期望 403", w.Code)
+	}
+	// 共享密钥没有节点身份
+	if w := resolveAs(mux, "", `{"names":["TOKEN_A"]}`); w.Code != http.StatusForbidden {
+		t.Errorf("共享密钥 status = %d, 期望 403", w.Code)
+	}
+
+	// 用户会话与无认证模式的匿名请求禁止解析
+	req := httptest.NewRequest("POST", "/api/v1/secrets/resolve", strings.NewReader(`{"names":["TOKEN_A"]}`))
+	req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: "admin"}))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("用户会话 resolve status = %d, 期望 403", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/secrets/resolve", strings.NewReader(`{"names":["TOKEN_A"]}`)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("匿名 resolve status = %d, 期望 403", w.Code)
+	}
+
+	// 任一名称不存在
+	if w := resolveAs(mux, "node-1", `{"names":["TOKEN_A","MISSING"]}`); w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, 期望 404", w.Code)
+	}
+}
+
+// TestSecretRoutes_AdminOnly 管理接口拒绝非管理员用户与节点凭证
+func TestSecretRoutes_AdminOnly(t *testing.T) {
+	store := newMockStore()
+	store.secrets["sec-1"] = &model.Secret{ID: "sec-1", Name: "TOKEN_A", EncryptedValue: "x"}
+	mux := newTestMux(t, store, true)
+
+	for _, tt := range []struct{ method, path, body string }{
+		{"GET", "/api/v1/secrets", ""},
+		{"POST", "/api/v1/secrets", `{"name":"TOKEN_B","value":"b"}`},
+		{"GET", "/api/v1/secrets/sec-1", ""},
+		{"PUT", "/api/v1/secrets/sec-1", `{"value":"b"}`},
+		{"DELETE", "/api/v1/secrets/sec-1", ""},
+	} {
+		for name, ctx := range map[string]func(context.Context) context.Context{
+			"user": func(ctx context.Context) context.Context {
+				return auth.WithAuthUser(ctx, &auth.AuthUser{ID: "u1", Role: "user"})
+			},
+			"node": func(ctx context.Context) context.Context { return auth.WithNodeIdentity(ctx, "node-1") },
+		} {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req.WithContext(ctx(req.Context())))
+			if w.Code != http.StatusForbidden {
+				t.Errorf("%s %s %s: status = %d, 期望 403", name, tt.method, tt.path, w.Code)
+			}
+		}
+	}
+	if s := store.secrets["sec-1"]; s == nil || s.EncryptedValue != "x" || len(store.secrets) != 1 {
+		t.Errorf("被拒绝的请求修改了密钥: %+v", store.secrets)
+	}
+}
+
+// TestUpdateDeleteSecret 改名时查询失败返回 500 而非跳过唯一性检查；删除不存在的密钥返回 404
+func TestUpdateDeleteSecret(t *testing.T) {
+	store := newMockStore()
+	store.secrets["sec-1"] = &model.Secret{ID: "sec-1", Name: "TOKEN_A", EncryptedValue: "x"}
+	mux := newTestMux(t, store, true)
+
+	store.lookupErr = errors.New("db down")
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("PUT", "/api/v1/secrets/sec-1", strings.NewReader(`{"name":"TOKEN_B"}`)))
+	if w.Code != http.StatusInternalServerError || store.secrets["sec-1"].Name != "TOKEN_A" {
+		t.Errorf("查询失败 status = %d, name = %s, 期望 500 且不改名", w.Code, store.secrets["sec-1"].Name)
+	}
+	store.lookupErr = nil
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/secrets/missing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("删除不存在的密钥 status = %d, 期望 404", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("DELETE", "/api/v1/secrets/sec-1", nil))
+	if w.Code != http.StatusNoContent || len(store.secrets) != 0 {
+		t.Errorf("删除 status = %d, secrets = %v", w.Code, store.secrets)
+	}
+}
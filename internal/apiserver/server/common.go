@@ -18,16 +18,40 @@
 package server
 
 import (
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
+	"agents-admin/internal/apiserver/accountpool"
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/apiserver/budget"
+	"agents-admin/internal/apiserver/confreload"
+	"agents-admin/internal/apiserver/eventsink"
+	"agents-admin/internal/apiserver/gateway"
+	"agents-admin/internal/apiserver/idempotency"
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/apiserver/maintenance"
+	"agents-admin/internal/apiserver/moderation"
+	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/apiserver/outbox"
+	"agents-admin/internal/apiserver/ratelimit"
+	"agents-admin/internal/apiserver/registry"
+	"agents-admin/internal/apiserver/run"
 	"agents-admin/internal/apiserver/scheduler"
+	"agents-admin/internal/apiserver/subtask"
+	"agents-admin/internal/apiserver/terminal"
+	"agents-admin/internal/apiserver/tunnel"
+	"agents-admin/internal/apiserver/webhook"
+	"agents-admin/internal/apiserver/workflow"
 	"agents-admin/internal/shared/cache"
 	"agents-admin/internal/shared/eventbus"
 	objstore "agents-admin/internal/shared/minio"
+	"agents-admin/internal/shared/model"
 	"agents-admin/internal/shared/queue"
 	"agents-admin/internal/shared/storage"
 )
@@ -66,16 +90,48 @@ type Handler struct {
 	// 认证配置
 	authConfig AuthConfigCompat
 
+	// 存储层是否已加密凭据与密钥（fieldcrypt.WrapStore），未启用时凭据与密钥的写入/解析接口返回 503
+	fieldEncryption bool
+
 	// 引导配置（Node Manager 零配置安装）
 	bootstrapConfig BootstrapConfig
 
 	// 对象存储
 	minioClient *objstore.Client // MinIO 客户端（volume archive）
 
+	// 事件上报单次请求的事件数上限（0 时使用 defaultMaxEventBatch）
+	maxEventBatch int
+
+	// 事件结构校验模式（model.EventSchemaLenient / model.EventSchemaStrict）
+	eventSchemaMode string
+
+	// Agent 派生子任务的深度与数量限制（零值使用默认值）
+	subtaskLimits subtask.Limits
+
 	// 内部组件
-	scheduler    *scheduler.Scheduler // 任务调度器
-	eventGateway *EventGateway        // WebSocket 事件网关
-	metrics      *Metrics             // Prometheus 指标
+	scheduler    *scheduler.Scheduler   // 任务调度器
+	budgets      *budget.Enforcer       // 预算检查（调度前检查账号/项目月度预算）
+	accountPool  *accountpool.Selector  // 账号池调度（调度前检查账号额度，执行时租用负载最低的账号）
+	runs         *run.Handler           // Run 处理器（HTTP 路由、工作流编排器与超时巡检共用）
+	nodes        *node.Handler          // 节点处理器（REST 路由与 gRPC 节点接口共用）
+	terminals    *terminal.Handler      // 终端会话处理器（路由与过期巡检共用）
+	tunnels      *tunnel.Hub            // 节点反向隧道（终端代理、文件获取与实时日志经其连接节点）
+	outbox       *outbox.Relay          // 事务发件箱中继（配置了调度队列时启用，Run 调度消息经其入队）
+	idempotency  *idempotency.Guard     // 任务与 Run 创建接口的幂等保护（Idempotency-Key 请求头）
+	rateLimiter  *ratelimit.Limiter     // 请求限流与登录暴力破解防护（可选，nil 时不限流）
+	bodyLimits   *bodylimit.Limiter     // 按路由组限制请求体大小
+	authHandler  *auth.Handler          // 认证处理器（Router 中创建，配置热加载调整令牌有效期）
+	confReloader *confreload.Reloader   // 配置热加载（可选，nil 时不注册 /api/v1/system/config 接口）
+	moderator    *moderation.Processor  // Agent 输出内容审核（可选，nil 时不审核）
+	maintenance  *maintenance.Service   // 存储维护（可选，nil 时不注册维护接口）
+	lifecycle    *lifecycle.Controller  // Run 数据分层（可选，nil 时事件只从数据库读取）
+	webhooks     *webhook.Dispatcher    // Webhook 事件分发（可选，nil 时不注册 Webhook 接口）
+	eventSink    *eventsink.Sink        // Run 事件镜像到 Kafka（可选，nil 时不镜像）
+	registry     *registry.Syncer       // 模板注册表同步（可选，nil 时不注册注册表接口）
+	gateway      *gateway.Handler       // Agent 网关（可选，nil 时不注册 /v1 接口）
+	orchestrator *workflow.Orchestrator // DAG 工作流编排器
+	eventGateway *EventGateway          // WebSocket 事件网关
+	metrics      *Metrics               // Prometheus 指标
 }
 
 // AuthConfigCompat 认证配置（避免直接依赖 config 包）
@@ -86,6 +142,11 @@ type AuthConfigCompat struct {
 	AdminEmail      string
 	AdminPassword   string
 	NodeToken       string // NodeManager 共享密钥（X-Node-Token 认证）
+	MasterKey       string // 两步验证密钥加密主密钥（AES-GCM）
+
+	DisableSharedNodeToken bool // 禁用共享密钥，节点只能使用加入令牌换取的专属凭证
+
+	OIDC auth.OIDCConfig // OIDC 单点登录（未配置 issuer/client_id 时不启用）
 }
 
 // NewHandler 创建 Handler 实例
@@ -114,8 +175,40 @@ func NewHandler(store storage.PersistentStore, redisStore storage.CacheStore) *H
 
 	// 创建调度器
 	h.scheduler = scheduler.NewScheduler(store, h.schedulerQueue, h.nodeQueue, "api-server")
+	h.budgets = budget.NewEnforcer(store)
+	h.scheduler.SetBudgetGate(h.budgets)
+	var accountUsage cache.AccountUsageCache
+	if redisStore != nil {
+		accountUsage = redisStore
+	}
+	h.accountPool = accountpool.NewSelector(store, accountUsage)
+	h.accountPool.SetQueue(h.schedulerQueue)
+	h.scheduler.SetAccountGate(h.accountPool)
+	h.runs = run.NewHandler(store, h.schedulerQueue)
+	h.nodes = node.NewHandler(store)
+	if redisStore != nil {
+		h.nodes.SetMetricsCache(redisStore)
+		h.scheduler.SetNodeMetrics(redisStore)
+	}
+	h.nodes.SetRunObserver(h.runs)
+	h.tunnels = tunnel.NewHub()
+	h.terminals = terminal.NewHandler(store)
+	h.terminals.SetNodeDialer(h.tunnels)
+	if h.schedulerQueue != nil {
+		h.outbox = outbox.NewRelay(store, outbox.Config{})
+		h.outbox.Handle(model.OutboxTopicScheduleRun, h.runs.PublishScheduleRun)
+		h.runs.SetOutbox(h.outbox)
+	}
+	h.idempotency = idempotency.NewGuard(store, idempotency.DefaultTTL)
+	h.bodyLimits, _ = bodylimit.New(nil)
+	h.runs.SetIdempotency(h.idempotency)
+	h.orchestrator = workflow.NewOrchestrator(store, h.runs)
 	h.eventGateway = NewEventGateway(store, h.runEventBus)
 	h.metrics = NewMetrics("api")
+	if p, ok := store.(dbPoolReporter); ok {
+		h.metrics.RegisterDBPool(p)
+	}
+	h.metrics.RegisterRegionQueue(store)
 	return h
 }
 
@@ -140,11 +233,187 @@ type BootstrapConfig struct {
 	TLSEnabled bool `json:"tls_enabled"`
 }
 
+// SetFieldEncryption 声明存储层已由 fieldcrypt.WrapStore 包装（需在 Router 之前调用）
+func (h *Handler) SetFieldEncryption(enabled bool) {
+	h.fieldEncryption = enabled
+}
+
 // SetMinIOClient 设置 MinIO 客户端（用于 volume archive 代理）
 func (h *Handler) SetMinIOClient(mc *objstore.Client) {
 	h.minioClient = mc
 }
 
+// SetSchedulerPreemption 设置调度器是否启用优先级抢占（需在 StartScheduler 之前调用）
+func (h *Handler) SetSchedulerPreemption(enabled bool) {
+	h.scheduler.SetPreemption(enabled)
+}
+
+// SetSchedulerStrategy 设置调度策略链与策略参数（可在调度器运行中调用）
+func (h *Handler) SetSchedulerStrategy(cfg scheduler.StrategyConfig) {
+	h.scheduler.SetStrategy(cfg)
+}
+
+// SetAccountPool 设置账号池的账号额度与冷却配置
+func (h *Handler) SetAccountPool(cfg accountpool.Config) {
+	h.accountPool.SetConfig(cfg)
+}
+
+// SetSchedulerSharding 设置调度器分片 worker 数与哈希槽数量（需在 StartScheduler 之前调用）
+func (h *Handler) SetSchedulerSharding(workers, slots int) {
+	h.scheduler.SetSharding(workers, slots)
+}
+
+// SetMaxEventBatch 设置事件上报单次请求的事件数上限（n <= 0 时使用默认值）
+func (h *Handler) SetMaxEventBatch(n int) {
+	h.maxEventBatch = n
+}
+
+// SetIdempotencyTTL 设置幂等键有效期（ttl <= 0 时使用默认值，需在 Router 之前调用）
+func (h *Handler) SetIdempotencyTTL(ttl time.Duration) {
+	h.idempotency = idempotency.NewGuard(h.store, ttl)
+	h.runs.SetIdempotency(h.idempotency)
+}
+
+// SetRateLimit 启用请求限流（令牌桶保存在 Redis，需在 Router 之前调用；没有 Redis 时不启用）
+func (h *Handler) SetRateLimit(cfg ratelimit.Config) {
+	if h.redisStore == nil {
+		return
+	}
+	h.rateLimiter = ratelimit.New(h.redisStore, cfg)
+	h.metrics.RegisterRateLimit(h.rateLimiter)
+}
+
+// SetBodyLimits 设置各路由组的请求体上限（字节，0 使用默认值，负数不限制；需在 Router 之前调用）
+func (h *Handler) SetBodyLimits(limits map[string]int64) error {
+	l, err := bodylimit.New(limits)
+	if err != nil {
+		return err
+	}
+	h.bodyLimits = l
+	return nil
+}
+
+// SetTokenTTL 调整新签发令牌的有效期（<= 0 的值保持不变，可在运行中调用）
+func (h *Handler) SetTokenTTL(access, refresh time.Duration) {
+	if h.authHandler != nil {
+		h.authHandler.SetTokenTTL(access, refresh)
+		return
+	}
+	if access > 0 {
+		h.authConfig.AccessTokenTTL = access
+	}
+	if refresh > 0 {
+		h.authConfig.RefreshTokenTTL = refresh
+	}
+}
+
+// SetRunWatchdogTimeout 调整超时巡检的默认执行时限（可在运行中调用）
+func (h *Handler) SetRunWatchdogTimeout(d time.Duration) {
+	h.runs.SetWatchdogTimeout(d)
+}
+
+// SetConfigReloader 启用配置热加载接口（需在 Router 之前调用）
+func (h *Handler) SetConfigReloader(r *confreload.Reloader) {
+	h.confReloader = r
+}
+
+// SetSubTaskLimits 设置 Agent 派生子任务的深度与数量限制（零值使用默认值，需在 Router 之前调用）
+func (h *Handler) SetSubTaskLimits(l subtask.Limits) {
+	h.subtaskLimits = l
+}
+
+// SetEventSchemaMode 设置事件结构校验模式（为空时为 lenient）
+func (h *Handler) SetEventSchemaMode(mode string) error {
+	switch mode {
+	case "", model.EventSchemaLenient, model.EventSchemaStrict:
+		h.eventSchemaMode = mode
+		return nil
+	}
+	return fmt.Errorf("unknown event schema mode %q (expected lenient or strict)", mode)
+}
+
+// SetModeration 启用 Agent 输出内容审核并注册命中指标（p 为 nil 时关闭）
+func (h *Handler) SetModeration(p *moderation.Processor) {
+	h.moderator = p
+	if p != nil {
+		h.metrics.RegisterModeration(p)
+	}
+}
+
+// SetMaintenance 设置存储维护服务（需在 Router 之前调用）
+func (h *Handler) SetMaintenance(svc *maintenance.Service) {
+	h.maintenance = svc
+}
+
+// SetWebhooks 设置 Webhook 事件分发器（需在 Router 之前调用）
+//
+// 事件来源由 webhook.WrapStore 包装的存储层提供，传入 NewHandler 的 store 应为包装后的存储。
+// 预算预警与耗尽通知也通过该分发器发送。
+func (h *Handler) SetWebhooks(d *webhook.Dispatcher) {
+	h.webhooks = d
+	if d != nil {
+		h.budgets.SetNotifier(d)
+	}
+}
+
+// SetEventSink 设置 Run 事件镜像投递器并注册滞后指标
+//
+// 事件来源由 eventsink.WrapStore 包装的存储层提供，传入 NewHandler 的 store 应为包装后的存储。
+func (h *Handler) SetEventSink(s *eventsink.Sink) {
+	h.eventSink = s
+	if s != nil {
+		h.metrics.RegisterEventSink(s)
+	}
+}
+
+// SetRegistry 设置模板注册表同步器（需在 Router 之前调用）
+func (h *Handler) SetRegistry(s *registry.Syncer) {
+	h.registry = s
+}
+
+// SetGateway 启用 Agent 网关（OpenAI 兼容接口，需在 Router 之前调用）
+func (h *Handler) SetGateway(cfg gateway.Config) {
+	h.gateway = gateway.NewHandler(h.store, h.runs, cfg)
+}
+
+// SetRunLifecycle 启用 Run 数据分层（需在 Router 之前调用）
+//
+// 启用后 HTTP 接口、WebSocket 回放与监控读取的事件在数据库中不存在时透明回退到归档。
+func (h *Handler) SetRunLifecycle(c *lifecycle.Controller) {
+	h.lifecycle = c
+	h.eventGateway.store = tieredEventStore{eventStore: h.store, events: c}
+	c.OnStats(h.metrics.RecordRunDataTiers)
+}
+
+// runEventReader Run 事件读取接口
+type runEventReader interface {
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+	CountEventsByRun(ctx context.Context, runID string) (int, error)
+}
+
+// eventReader 返回 Run 事件读取入口：启用数据分层时为分层控制器，否则为存储层
+func (h *Handler) eventReader() runEventReader {
+	if h.lifecycle != nil {
+		return h.lifecycle
+	}
+	return h.store
+}
+
+// tieredEventStore 事件从分层控制器读取、Run 从存储层读取的 eventStore
+type tieredEventStore struct {
+	eventStore
+	events runEventReader
+}
+
+func (s tieredEventStore) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	return s.events.GetEventsByRun(ctx, runID, fromSeq, limit)
+}
+
+// SetNodeJoinConfig 设置节点自动注册配置（签发节点客户端证书的 CA 等）
+func (h *Handler) SetNodeJoinConfig(cfg node.JoinConfig) {
+	h.nodes.SetJoinConfig(cfg)
+}
+
 // SetBootstrapConfig 设置引导配置
 func (h *Handler) SetBootstrapConfig(cfg BootstrapConfig) {
 	h.bootstrapConfig = cfg
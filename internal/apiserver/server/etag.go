@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// etagMiddleware 为 NodeManager 轮询的 GET 接口提供 ETag / If-None-Match 支持
+//
+// 对 match 命中的 GET 请求缓冲 200 响应体，以内容哈希作为强 ETag；
+// 请求携带的 If-None-Match 与之匹配时返回 304 且不发送响应体。
+// 响应内容仍需在服务端生成，节省的是网络传输与客户端解析。
+func etagMiddleware(next http.Handler, match func(r *http.Request) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet || !match(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &bufferedResponse{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		for k, v := range rec.header {
+			w.Header()[k] = v
+		}
+		if rec.status != http.StatusOK {
+			w.WriteHeader(rec.status)
+			w.Write(rec.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(rec.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write(rec.body.Bytes())
+	})
+}
+
+// etagMatches 判断 If-None-Match 是否匹配（支持逗号分隔列表、弱校验前缀 W/ 与 *）
+func etagMatches(ifNoneMatch, etag string) bool {
+	if ifNoneMatch == "" {
+		return false
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// versionETag 由资源的版本字段（如事件数、最大 seq、updated_at）计算弱 ETag
+//
+// 与 etagMiddleware 的内容哈希不同，版本字段可在加载/序列化完整响应前廉价获得，
+// 适用于前端轮询的事件列表与监控接口。
+func versionETag(parts ...interface{}) string {
+	hash := sha256.New()
+	for _, p := range parts {
+		if t, ok := p.(time.Time); ok {
+			p = t.UnixNano()
+		}
+		fmt.Fprintf(hash, "%v\x00", p)
+	}
+	return `W/"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// checkNotModified 写入 ETag / Last-Modified 校验头，并判断条件请求是否可返回 304
+//
+// If-None-Match 优先于 If-Modified-Since（RFC 9110）；lastModified 为零值时不返回 Last-Modified。
+// 返回 true 时已写入 304 响应，调用方应直接返回。
+func checkNotModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "no-cache") // 允许缓存但每次需重新校验
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+
+	notModified := false
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		notModified = etagMatches(inm, strings.TrimPrefix(etag, "W/"))
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		if t, err := http.ParseTime(ims); err == nil {
+			notModified = !lastModified.Truncate(time.Second).After(t)
+		}
+	}
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+	}
+	return notModified
+}
+
+// nodePolledGET 匹配 NodeManager 频繁轮询的资源接口
+//
+//   - GET /api/v1/runs/{id}
+//   - GET /api/v1/agents、/api/v1/agents/{id}
+//   - GET /api/v1/nodes/{id}/runs、/api/v1/nodes/{id}/agents
+func nodePolledGET(r *http.Request) bool {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 3 || parts[0] != "api" || parts[1] != "v1" {
+		return false
+	}
+	switch parts[2] {
+	case "runs":
+		return len(parts) == 4
+	case "agents":
+		return len(parts) <= 4
+	case "nodes":
+		return len(parts) == 5 && (parts[4] == "runs" || parts[4] == "agents")
+	}
+	return false
+}
+
+// bufferedResponse 缓冲响应（用于计算 ETag）
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header { return b.header }
+
+func (b *bufferedResponse) WriteHeader(status int) { b.status = status }
+
+func (b *bufferedResponse) Write(p []byte) (int, error) { return b.body.Write(p) }
@@ -0,0 +1,232 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestETagMiddleware_NotModified(t *testing.T) {
+	body := `{"id":"run-1","status":"running"}`
+	calls := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/runs/{id}", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		writeJSON(w, http.StatusOK, map[string]string{"id": r.PathValue("id")})
+	})
+	mux.HandleFunc("GET /api/v1/runs/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	})
+	h := etagMiddleware(mux, nodePolledGET)
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/run-1", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" || w.Body.Len() == 0 {
+		t.Fatalf("首次请求 status = %d, etag = %q", w.Code, etag)
+	}
+
+	// 内容未变化：304 且无响应体
+	req := httptest.NewRequest("GET", "/api/v1/runs/run-1", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("条件请求 status = %d, body = %q", w.Code, w.Body.String())
+	}
+
+	// 资源不同：ETag 不匹配，返回完整响应
+	req = httptest.NewRequest("GET", "/api/v1/runs/run-2", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("不同资源 status = %d, etag = %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// 未匹配的路由不加 ETag
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/run-1/events", nil))
+	if w.Header().Get("ETag") != "" {
+		t.Error("未声明的接口不应返回 ETag")
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d", calls)
+	}
+}
+
+func TestETagMiddleware_ErrorPassthrough(t *testing.T) {
+	h := etagMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, http.StatusNotFound, "run not found")
+	}), nodePolledGET)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/missing", nil))
+	if w.Code != http.StatusNotFound || w.Header().Get("ETag") != "" || w.Body.Len() == 0 {
+		t.Errorf("status = %d, etag = %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestNodePolledGET(t *testing.T) {
+	tests := map[string]bool{
+		"/api/v1/runs/run-1":          true,
+		"/api/v1/runs/run-1/events":   false,
+		"/api/v1/agents":              true,
+		"/api/v1/agents/inst-1":       true,
+		"/api/v1/nodes/node-1/runs":   true,
+		"/api/v1/nodes/node-1/agents": true,
+		"/api/v1/nodes/node-1":        false,
+		"/api/v1/tasks":               false,
+	}
+	for path, want := range tests {
+		if got := nodePolledGET(httptest.NewRequest("GET", path, nil)); got != want {
+			t.Errorf("nodePolledGET(%s) = %v, want %v", path, got, want)
+		}
+	}
+}
+
+// countingEventStore 统计事件加载次数（验证 304 时不加载事件）
+type countingEventStore struct {
+	*mockMonitorStore
+	loads int
+}
+
+func (c *countingEventStore) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
+	c.loads++
+	return c.mockMonitorStore.GetEventsByRun(ctx, runID, fromSeq, limit)
+}
+
+// TestGetEvents_ConditionalPolling 轮询未变化的事件列表时仅传输 304 头部
+func TestGetEvents_ConditionalPolling(t *testing.T) {
+	now := time.Now()
+	base := &mockMonitorStore{Events: map[string][]*model.Event{"run-1": {}}}
+	for i := 1; i <= 50; i++ {
+		base.Events["run-1"] = append(base.Events["run-1"], &model.Event{
+			ID: int64(i), RunID: "run-1", Seq: i, Type: "message", Timestamp: now,
+			Payload: []byte(`{"content":"line of agent output"}`),
+		})
+	}
+	store := &countingEventStore{mockMonitorStore: base}
+	h := newTestHandler(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/runs/{id}/events", h.GetEvents)
+
+	poll := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/api/v1/runs/run-1/events?limit=1000", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	// 不带条件请求：每次都下载完整列表
+	const polls = 10
+	plainBytes := 0
+	for i := 0; i < polls; i++ {
+		plainBytes += poll("").Body.Len()
+	}
+
+	// 带 If-None-Match：仅首次下载
+	store.loads = 0
+	first := poll("")
+	etag := first.Header().Get("ETag")
+	if first.Code != http.StatusOK || etag == "" {
+		t.Fatalf("首次请求 status = %d, etag = %q", first.Code, etag)
+	}
+	conditionalBytes := first.Body.Len()
+	for i := 1; i < polls; i++ {
+		w := poll(etag)
+		if w.Code != http.StatusNotModified {
+			t.Fatalf("第 %d 次轮询 status = %d, 期望 304", i, w.Code)
+		}
+		conditionalBytes += w.Body.Len()
+	}
+	if store.loads != 1 {
+		t.Errorf("304 响应不应加载事件, loads = %d", store.loads)
+	}
+	if conditionalBytes*polls > plainBytes*2 {
+		t.Errorf("条件请求未减少传输: %d vs %d 字节", conditionalBytes, plainBytes)
+	}
+	t.Logf("%d 次轮询传输: 无条件 %d 字节, 条件请求 %d 字节", polls, plainBytes, conditionalBytes)
+
+	// 新事件到达：ETag 变化，返回完整列表
+	base.Events["run-1"] = append(base.Events["run-1"], &model.Event{ID: 51, RunID: "run-1", Seq: 51, Type: "run_completed", Timestamp: now})
+	w := poll(etag)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("新事件后 status = %d, etag = %q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+// TestMonitorConditionalGET 监控接口支持 If-None-Match 与 If-Modified-Since
+func TestMonitorConditionalGET(t *testing.T) {
+	updated := time.Now().Add(-time.Minute).Truncate(time.Second)
+	run := &model.Run{ID: "run-1", TaskID: "task-1", Status: model.RunStatusRunning, CreatedAt: updated, UpdatedAt: updated}
+	store := &mockMonitorStore{
+		Tasks:   []*model.Task{{ID: "task-1"}},
+		Runs:    map[string][]*model.Run{"task-1": {run}},
+		RunByID: map[string]*model.Run{"run-1": run},
+		Events: map[string][]*model.Event{"run-1": {
+			{ID: 1, RunID: "run-1", Seq: 1, Type: "run_started", Timestamp: updated},
+		}},
+	}
+	h := newTestHandler(store)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/monitor/workflows", h.ListWorkflows)
+	mux.HandleFunc("GET /api/v1/monitor/workflows/{type}/{id}", h.GetWorkflow)
+	mux.HandleFunc("GET /api/v1/monitor/workflows/{type}/{id}/events", h.GetWorkflowEvents)
+	mux.HandleFunc("GET /api/v1/monitor/stats", h.GetMonitorStats)
+
+	for _, path := range []string{
+		"/api/v1/monitor/workflows?type=run",
+		"/api/v1/monitor/workflows/run/run-1",
+		"/api/v1/monitor/workflows/run/run-1/events",
+		"/api/v1/monitor/stats",
+	} {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+		etag := w.Header().Get("ETag")
+		if w.Code != http.StatusOK || etag == "" {
+			t.Fatalf("GET %s status = %d, etag = %q", path, w.Code, etag)
+		}
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("If-None-Match", etag)
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+			t.Errorf("GET %s 条件请求 status = %d, body = %d 字节", path, w.Code, w.Body.Len())
+		}
+	}
+
+	// Last-Modified / If-Modified-Since
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/monitor/workflows/run/run-1", nil))
+	lastModified := w.Header().Get("Last-Modified")
+	if lastModified != updated.UTC().Format(http.TimeFormat) {
+		t.Fatalf("Last-Modified = %q", lastModified)
+	}
+	req := httptest.NewRequest("GET", "/api/v1/monitor/workflows/run/run-1", nil)
+	req.Header.Set("If-Modified-Since", lastModified)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("If-Modified-Since status = %d, 期望 304", w.Code)
+	}
+
+	// 状态变化后 ETag 失效
+	etag := w.Header().Get("ETag")
+	run.Status = model.RunStatusDone
+	run.UpdatedAt = updated.Add(time.Minute)
+	req = httptest.NewRequest("GET", "/api/v1/monitor/workflows/run/run-1", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("变化后 status = %d, 期望 200", w.Code)
+	}
+}
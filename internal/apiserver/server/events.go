@@ -4,11 +4,23 @@ package server
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
 	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/apiserver/hitl"
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/apiserver/moderation"
+	"agents-admin/internal/apiserver/usage"
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
 )
 
 // ============================================================================
@@ -21,6 +33,15 @@ type PostEventsRequest = openapi.PostEventsRequest
 // EventInput 单个事件的输入结构（OpenAPI 生成）
 type EventInput = openapi.EventInput
 
+// PostEventsResponse 批量上报事件的响应体（OpenAPI 生成）
+type PostEventsResponse = openapi.PostEventsResponse
+
+// RejectedEvent 校验失败的事件（OpenAPI 生成）
+type RejectedEvent = openapi.RejectedEvent
+
+// defaultMaxEventBatch 事件上报单次请求的默认事件数上限
+const defaultMaxEventBatch = 1000
+
 // ============================================================================
 // Event 接口处理函数
 // ============================================================================
@@ -43,7 +64,16 @@ type EventInput = openapi.EventInput
 //	  "count": 10
 //	}
 //
+// 条件请求:
+//   - 响应携带 ETag（由事件数与查询参数计算，事件只追加不修改）
+//   - If-None-Match 匹配时返回 304 Not Modified，不加载事件列表
+//
+// 数据分层：
+//   - 事件已归档到对象存储的 Run 透明地从归档读取
+//   - 归档已按项目保留策略删除的 Run 返回 410 Gone
+//
 // 错误响应:
+//   - 410 Gone: 事件已按项目保留策略删除
 //   - 500 Internal Server Error: 服务器内部错误
 //
 // 使用场景：
@@ -57,7 +87,21 @@ func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
 		limit = 100
 	}
 
-	events, err := h.store.GetEventsByRun(r.Context(), runID, fromSeq, limit)
+	ctx := storage.WithReadReplica(r.Context())
+	count, err := h.eventReader().CountEventsByRun(ctx, runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get events")
+		return
+	}
+	if checkNotModified(w, r, versionETag("events", runID, fromSeq, limit, count), time.Time{}) {
+		return
+	}
+
+	events, err := h.eventReader().GetEventsByRun(ctx, runID, fromSeq, limit)
+	if errors.Is(err, lifecycle.ErrEventsPurged) {
+		writeError(w, http.StatusGone, err.Error())
+		return
+	}
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to get events")
 		return
@@ -65,6 +109,104 @@ func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{"events": events, "count": len(events)})
 }
 
+// GetEventSchemas 列出事件结构定义与当前校验模式
+//
+// 路由: GET /api/v1/event-schemas
+//
+// 响应:
+//
+//	{
+//	  "mode": "lenient",
+//	  "schemas": [{"type": "run_started", "version": 1, "fields": [{"name": "node_id", "kind": "string"}]}]
+//	}
+//
+// 使用场景：
+//   - UI 与分析按 (type, schema_version) 确定事件中可依赖的字段
+func (h *Handler) GetEventSchemas(w http.ResponseWriter, r *http.Request) {
+	mode := h.eventSchemaMode
+	if mode == "" {
+		mode = model.EventSchemaLenient
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"mode": mode, "schemas": model.EventSchemas()})
+}
+
+// rawExportBatchSize 原始输出导出时分批读取事件的批大小
+const rawExportBatchSize = 1000
+
+// ExportRawEvents 导出 Run 的原始输出行（回放输入）
+//
+// 路由: GET /api/v1/runs/{id}/events/raw
+//
+// 路径参数:
+//   - id: Run ID
+//
+// 响应:
+//   - 200 OK: text/plain，按 seq 顺序每行一条事件的原始输出（无原始输出的事件跳过）
+//   - 响应头 X-Agent-Type: Run 快照中的 Agent 类型（如 qwen-code），便于选择回放适配器
+//   - 404 Not Found: Run 不存在
+//   - 410 Gone: 事件已按项目保留策略删除
+//   - 500 Internal Server Error: 服务器内部错误
+//
+// 使用场景：
+//   - 作为 cmd/event-replay 的输入，用历史真实输出校验 Adapter 解析器改动
+func (h *Handler) ExportRawEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := storage.WithReadReplica(r.Context())
+	runID := r.PathValue("id")
+
+	run, err := h.store.GetRun(ctx, runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	// 先读完全部事件再写响应，避免中途出错时已写出部分内容
+	var lines []string
+	fromSeq := 0
+	for {
+		events, err := h.eventReader().GetEventsByRun(ctx, runID, fromSeq, rawExportBatchSize)
+		if errors.Is(err, lifecycle.ErrEventsPurged) {
+			writeError(w, http.StatusGone, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get events")
+			return
+		}
+		for _, e := range events {
+			if e.Raw != nil && *e.Raw != "" {
+				lines = append(lines, *e.Raw)
+			}
+			fromSeq = e.Seq
+		}
+		if len(events) < rawExportBatchSize {
+			break
+		}
+	}
+
+	if agentType := runAgentType(run); agentType != "" {
+		w.Header().Set("X-Agent-Type", agentType)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	for _, line := range lines {
+		io.WriteString(w, line)
+		io.WriteString(w, "\n")
+	}
+}
+
+// runAgentType 从 Run 快照中读取 agent.type，缺失时返回空串
+func runAgentType(run *model.Run) string {
+	snapshot, err := model.ParseRunSnapshot(run.Snapshot)
+	if err != nil {
+		return ""
+	}
+	return snapshot.Agent.Type
+}
+
 // PostEvents 批量上报事件
 //
 // 路由: POST /api/v1/runs/{id}/events
@@ -82,66 +224,329 @@ func (h *Handler) GetEvents(w http.ResponseWriter, r *http.Request) {
 //	}
 //
 // 响应:
-//   - 201 Created: 返回 {"created": 2}
-//   - 400 Bad Request: 请求体格式错误
+//   - 201 Created: 返回 {"created": 1, "stored": [2], "assigned": [5], "duplicates": [1], "rejected": []}
+//   - 400 Bad Request: 请求体格式错误，或全部事件校验失败
+//   - 413 Request Entity Too Large: 事件数超过单次上限（api_server.max_event_batch）
 //   - 500 Internal Server Error: 服务器内部错误
 //
+// 序号：请求中的 seq 为节点序号（入库为 node_seq），入库事件的 seq 由 API Server 按 Run 单调分配，
+// 与 API Server 自身写入的事件（budget_exceeded、run_orphaned 等）不冲突；assigned 与 stored 一一对应。
+//
+// 幂等：(run_id, node_seq) 已入库的事件计入 duplicates 而不重复写入，节点可以整批重试；
+// seq 不为正数或缺少 type 的事件计入 rejected，其余事件正常入库（部分成功）。
+//
+// 结构校验：Payload 按 model.EventSchema 校验，入库事件标注匹配的 schema_version（0 表示未定义结构或不匹配）；
+// 严格模式（api_server.event_schema_mode: strict）下未定义结构的类型与不匹配的事件计入 rejected。
+//
 // 使用场景：
 //   - Node Agent 批量上报执行过程中产生的事件
 //   - 支持 WebSocket 实时推送到前端
 //
 // 副作用：
 //   - 当收到第一个事件时，更新 Task 状态为 running（表示真正开始执行）
+//   - approval_required 事件创建对应的审批请求（ApprovalRequest）
+//   - feedback_consumed 事件将对应的人工反馈（HumanFeedback）标记为已处理
+//   - usage 事件的 Token 用量与费用累加到 Run 的用量记录（RunUsage）
+//   - 启用内容审核时命中内容被掩码（事件标记 redacted），启用 retain_originals 时另存原文
 func (h *Handler) PostEvents(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	runID := r.PathValue("id")
-	var req PostEventsRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+	events, err := decodeEventBatch(r.Body, h.eventBatchLimit())
+	switch {
+	case errors.Is(err, ErrEventBatchTooLarge):
+		writeEventBatchTooLarge(w, err, h.eventBatchLimit())
+		return
+	case bodylimit.IsTooLarge(err):
+		bodylimit.WriteTooLarge(w, err)
+		return
+	case err != nil:
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	events := make([]*model.Event, len(req.Events))
-	for i, e := range req.Events {
-		var payload []byte
+	resp, err := h.IngestEvents(r.Context(), r.PathValue("id"), events)
+	switch {
+	case errors.Is(err, ErrEventBatchTooLarge):
+		writeEventBatchTooLarge(w, err, h.eventBatchLimit())
+	case errors.Is(err, ErrInvalidEvents):
+		writeError(w, http.StatusBadRequest, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to create events")
+	default:
+		writeJSON(w, http.StatusCreated, resp)
+	}
+}
+
+var (
+	// ErrEventBatchTooLarge 单次上报的事件数超过上限
+	ErrEventBatchTooLarge = errors.New("too many events in one request")
+	// ErrInvalidEvents 上报的事件全部校验失败
+	ErrInvalidEvents = errors.New("invalid event")
+)
+
+// eventBatchLimit 单次上报的事件数上限
+func (h *Handler) eventBatchLimit() int {
+	if h.maxEventBatch <= 0 {
+		return defaultMaxEventBatch
+	}
+	return h.maxEventBatch
+}
+
+// decodeEventBatch 流式解码事件上报请求体（{"events": [...]}）
+//
+// 逐个解码事件，超过 max 个时立即停止读取并返回 ErrEventBatchTooLarge，不会把超大批次整体读入内存。
+func decodeEventBatch(body io.Reader, max int) ([]EventInput, error) {
+	dec := json.NewDecoder(body)
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, err
+	}
+	events := []EventInput{}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key, _ := tok.(string); key != "events" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		tok, err = dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if tok == nil { // "events": null
+			continue
+		}
+		if d, ok := tok.(json.Delim); !ok || d != '[' {
+			return nil, fmt.Errorf("events must be an array")
+		}
+		for dec.More() {
+			if len(events) >= max {
+				return nil, fmt.Errorf("%w (max %d)", ErrEventBatchTooLarge, max)
+			}
+			var e EventInput
+			if err := dec.Decode(&e); err != nil {
+				return nil, err
+			}
+			events = append(events, e)
+		}
+		if err := expectDelim(dec, ']'); err != nil {
+			return nil, err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// expectDelim 读取下一个 JSON 分隔符并确认为 want
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q", want)
+	}
+	return nil
+}
+
+// writeEventBatchTooLarge 事件数超过上限的 413 响应（NodeManager 收到后将批次对半拆分重新上报）
+func writeEventBatchTooLarge(w http.ResponseWriter, err error, max int) {
+	writeJSON(w, http.StatusRequestEntityTooLarge, map[string]interface{}{"error": err.Error(), "max_events": max})
+}
+
+// IngestEvents 校验、去重并写入一批事件，触发审核、HITL、用量统计与实时推送（REST 与 gRPC 上报共用）
+//
+// 返回 ErrEventBatchTooLarge / ErrInvalidEvents 表示请求无效，其他错误为存储失败。
+func (h *Handler) IngestEvents(ctx context.Context, runID string, inputs []EventInput) (*PostEventsResponse, error) {
+	maxBatch := h.eventBatchLimit()
+	if len(inputs) > maxBatch {
+		return nil, fmt.Errorf("%w (max %d)", ErrEventBatchTooLarge, maxBatch)
+	}
+
+	resp := PostEventsResponse{Stored: []int{}, Assigned: []int{}, Duplicates: []int{}, Rejected: []RejectedEvent{}}
+	events := make([]*model.Event, 0, len(inputs))
+	for _, e := range inputs {
+		if reason := validateEventInput(e); reason != "" {
+			resp.Rejected = append(resp.Rejected, RejectedEvent{Seq: e.Seq, Error: reason})
+			continue
+		}
+
+		var (
+			payload []byte
+			fields  map[string]interface{}
+		)
 		if e.Payload != nil {
-			payload, _ = json.Marshal(*e.Payload)
+			fields = *e.Payload
+			payload, _ = json.Marshal(fields)
 		}
 
-		events[i] = &model.Event{
-			RunID:     runID,
-			Seq:       e.Seq,
-			Type:      e.Type,
-			Timestamp: e.Timestamp,
-			Payload:   payload,
-			Raw:       e.Raw, // 直接使用 *string
+		version, schemaErr := model.MatchEventSchema(e.Type, fields)
+		if reason := h.schemaRejection(e.Type, schemaErr); reason != "" {
+			resp.Rejected = append(resp.Rejected, RejectedEvent{Seq: e.Seq, Error: reason})
+			continue
 		}
+		if schemaErr != nil {
+			slog.ErrorContext(ctx, "events.schema.mismatch", "run_id", runID, "seq", e.Seq, "error", schemaErr)
+		}
+
+		events = append(events, &model.Event{
+			RunID:         runID,
+			NodeSeq:       e.Seq,
+			Type:          e.Type,
+			Timestamp:     e.Timestamp,
+			Payload:       payload,
+			Raw:           e.Raw, // 直接使用 *string
+			SchemaVersion: version,
+		})
+	}
+	if len(events) == 0 && len(resp.Rejected) > 0 {
+		return nil, fmt.Errorf("%w seq=%d: %s", ErrInvalidEvents, resp.Rejected[0].Seq, resp.Rejected[0].Error)
+	}
+
+	// 节点重试或回放本地缓存时可能重复上报已入库的事件
+	events, resp.Duplicates = h.dropStoredEvents(ctx, runID, events)
+	if len(events) == 0 {
+		return &resp, nil
+	}
+
+	// 按节点序号顺序分配入库序号
+	slices.SortStableFunc(events, func(a, b *model.Event) int { return a.NodeSeq - b.NodeSeq })
+	first, err := h.store.AllocateEventSeqs(ctx, runID, len(events))
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range events {
+		e.Seq = first + i
+	}
+
+	// 内容审核：入库与推送前掩码命中内容
+	var moderated *moderation.Result
+	if h.moderator != nil {
+		moderated = h.moderator.Apply(runID, events)
 	}
 
 	if err := h.store.CreateEvents(ctx, events); err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to create events")
-		return
+		return nil, err
+	}
+	if moderated != nil && len(moderated.Originals) > 0 {
+		if err := h.store.CreateEventOriginals(ctx, moderated.Originals); err != nil {
+			slog.ErrorContext(ctx, "moderation.originals.failed", "run_id", runID, "count", len(moderated.Originals), "error", err)
+		}
 	}
 
 	// 检查是否需要更新 Task 状态为 running
 	// 当收到第一个事件（seq=1）或 run_started 事件时，表示任务真正开始执行
-	h.maybeUpdateTaskToRunning(ctx, runID, req.Events)
+	h.maybeUpdateTaskToRunning(ctx, runID, inputs)
+
+	// 节点暂停等待人工审批：创建审批请求
+	hitl.RecordApprovalEvents(ctx, h.store, runID, events)
+	// Agent 已读取人工反馈：标记反馈已处理
+	hitl.RecordFeedbackEvents(ctx, h.store, runID, events)
+	// Token 用量：累加到 Run 的用量记录
+	usage.RecordUsageEvents(ctx, h.store, runID, events)
+
+	if moderated != nil && len(moderated.Flags) > 0 {
+		h.recordModeration(ctx, runID, moderated)
+	}
 
 	// 写入 DB 后，立即广播到 WebSocket 客户端（实时推送）
-	for _, e := range req.Events {
-		var payload map[string]interface{}
-		if e.Payload != nil {
-			payload = *e.Payload
-		}
+	for _, e := range events {
 		h.eventGateway.Broadcast(runID, map[string]interface{}{
-			"seq":       e.Seq,
-			"type":      e.Type,
-			"timestamp": e.Timestamp,
-			"payload":   payload,
+			"seq":            e.Seq,
+			"type":           e.Type,
+			"timestamp":      e.Timestamp,
+			"payload":        e.Payload,
+			"schema_version": e.SchemaVersion,
+			"redacted":       e.Redacted,
 		})
 	}
 
-	writeJSON(w, http.StatusCreated, map[string]int{"created": len(events)})
+	for _, e := range events {
+		resp.Stored = append(resp.Stored, e.NodeSeq)
+		resp.Assigned = append(resp.Assigned, e.Seq)
+	}
+	resp.Created = len(events)
+	return &resp, nil
+}
+
+// validateEventInput 校验上报的单个事件，返回拒绝原因（通过时为空）
+func validateEventInput(e EventInput) string {
+	if e.Seq <= 0 {
+		return "seq must be positive"
+	}
+	if e.Type == "" {
+		return "type is required"
+	}
+	return ""
+}
+
+// schemaRejection 严格模式下返回事件结构校验的拒绝原因（宽松模式或校验通过时为空）
+func (h *Handler) schemaRejection(eventType string, schemaErr error) string {
+	if h.eventSchemaMode != model.EventSchemaStrict {
+		return ""
+	}
+	if !model.HasEventSchema(eventType) {
+		return "no schema defined for event type " + eventType
+	}
+	if schemaErr != nil {
+		return "payload does not match schema " + schemaErr.Error()
+	}
+	return ""
+}
+
+// dropStoredEvents 过滤该 Run 中节点序号已入库（或在同一请求中重复）的事件，返回新事件与被忽略的节点序号
+//
+// 查询失败时不过滤，由存储层忽略冲突的 (run_id, node_seq)。
+func (h *Handler) dropStoredEvents(ctx context.Context, runID string, events []*model.Event) ([]*model.Event, []int) {
+	duplicates := []int{}
+	if len(events) == 0 {
+		return events, duplicates
+	}
+	minSeq, maxSeq := events[0].NodeSeq, events[0].NodeSeq
+	for _, e := range events[1:] {
+		minSeq = min(minSeq, e.NodeSeq)
+		maxSeq = max(maxSeq, e.NodeSeq)
+	}
+	seen := make(map[int]bool, len(events))
+	stored, err := h.store.ListEventNodeSeqs(ctx, runID, minSeq, maxSeq)
+	if err == nil {
+		for _, seq := range stored {
+			seen[seq] = true
+		}
+	}
+	fresh := make([]*model.Event, 0, len(events))
+	for _, e := range events {
+		if seen[e.NodeSeq] {
+			duplicates = append(duplicates, e.NodeSeq)
+			continue
+		}
+		seen[e.NodeSeq] = true
+		fresh = append(fresh, e)
+	}
+	if len(duplicates) > 0 {
+		slog.InfoContext(ctx, "events.duplicate.dropped", "run_id", runID, "count", len(duplicates))
+	}
+	return fresh, duplicates
+}
+
+// recordModeration 记录内容审核标记，命中达到终止级别时终止 Run
+func (h *Handler) recordModeration(ctx context.Context, runID string, result *moderation.Result) {
+	if err := h.store.CreateRunFlags(ctx, result.Flags); err != nil {
+		slog.ErrorContext(ctx, "moderation.flags.failed", "run_id", runID, "error", err)
+	}
+	slog.InfoContext(ctx, "moderation.flagged", "run_id", runID, "flags", len(result.Flags), "aborted", result.Abort != nil)
+
+	if abort := result.Abort; abort != nil {
+		reason := fmt.Sprintf("aborted by moderation: %s content detected (severity %s) in event %d",
+			abort.Detector, abort.Severity, abort.EventSeq)
+		if err := h.runs.Abort(ctx, runID, reason); err != nil {
+			slog.ErrorContext(ctx, "moderation.abort.failed", "run_id", runID, "error", err)
+		}
+	}
 }
 
 // maybeUpdateToRunning 检查并更新 Run 和 Task 状态为 running
@@ -3,10 +3,13 @@
 // 本文件定义 HTTP API 路由，将请求分发到各领域独立包。
 // 仍保留在本包的模块：
 //   - events.go: 事件接口（依赖 EventGateway）
-//   - monitor.go / monitor_ws.go: 监控接口（依赖工作流缓存/事件总线）
+//   - search.go: 事件全文检索接口
+//   - monitor.go / monitor_ws.go / monitor_stream.go: 监控接口（依赖工作流缓存/事件总线）
 //   - websocket.go: WebSocket 事件网关
 //   - metrics.go: Prometheus 指标
-//   - runs.go: StartScheduler（调度器入口）
+//   - runs.go: StartScheduler / StartWorkflowOrchestrator / StartRunWatchdog / StartRunReconciler（调度器、工作流编排器、超时巡检与孤儿回收入口）
+//   - overview.go: 管理后台系统总览
+//   - openapi.go: OpenAPI 规范（/api/v1/openapi.json）
 package server
 
 import (
@@ -14,17 +17,36 @@ import (
 	"net/http"
 
 	"agents-admin/api"
+	"agents-admin/internal/apiserver/accountpool"
+	"agents-admin/internal/apiserver/apply"
+	"agents-admin/internal/apiserver/audit"
 	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/budget"
+	"agents-admin/internal/apiserver/credential"
 	"agents-admin/internal/apiserver/hitl"
 	"agents-admin/internal/apiserver/instance"
-	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/apiserver/integration"
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/apiserver/maintenance"
+	"agents-admin/internal/apiserver/moderation"
+	"agents-admin/internal/apiserver/nodestate"
 	"agents-admin/internal/apiserver/operation"
+	"agents-admin/internal/apiserver/project"
 	"agents-admin/internal/apiserver/proxy"
-	"agents-admin/internal/apiserver/run"
+	"agents-admin/internal/apiserver/publish"
+	"agents-admin/internal/apiserver/region"
+	"agents-admin/internal/apiserver/registry"
+	"agents-admin/internal/apiserver/replay"
+	"agents-admin/internal/apiserver/secret"
+	"agents-admin/internal/apiserver/subtask"
 	"agents-admin/internal/apiserver/sysconfig"
 	"agents-admin/internal/apiserver/task"
 	"agents-admin/internal/apiserver/template"
-	"agents-admin/internal/apiserver/terminal"
+	"agents-admin/internal/apiserver/tunnel"
+	"agents-admin/internal/apiserver/usage"
+	"agents-admin/internal/apiserver/webhook"
+	"agents-admin/internal/apiserver/workflow"
+	"agents-admin/internal/shared/model"
 )
 
 // Router 返回配置好的 HTTP 路由
@@ -34,22 +56,89 @@ import (
 // 健康检查:
 //   - GET /health - 服务健康检查
 //
+// 接口规范（公开）:
+//   - GET /api/v1/openapi.json - OpenAPI 3 规范（JSON，YAML 源文件见 /spec/）
+//
 // 任务管理 (Task):
 //   - GET    /api/v1/tasks           - 列出任务
 //   - POST   /api/v1/tasks           - 创建任务
+//   - POST   /api/v1/tasks/bulk      - 批量创建、取消、删除、重新执行任务（逐项返回结果）
+//   - POST   /api/v1/tasks/import    - 从 YAML 文档创建任务
 //   - GET    /api/v1/tasks/{id}      - 获取任务详情
 //   - DELETE /api/v1/tasks/{id}      - 删除任务
+//   - GET    /api/v1/tasks/{id}/export - 导出任务定义为 YAML 文档
+//   - GET    /api/v1/tasks/{id}/context - 获取任务上下文（含持久会话的完整对话）
+//   - PUT    /api/v1/tasks/{id}/context - 更新任务上下文
+//
+// 项目管理 (Project，写操作仅限管理员):
+//   - GET    /api/v1/projects        - 列出项目
+//   - POST   /api/v1/projects        - 创建项目
+//   - GET    /api/v1/projects/{id}   - 获取项目详情
+//   - PUT    /api/v1/projects/{id}   - 更新项目
+//   - DELETE /api/v1/projects/{id}   - 删除项目
 //
 // 执行管理 (Run):
 //   - POST   /api/v1/tasks/{id}/runs - 创建执行
 //   - GET    /api/v1/tasks/{id}/runs - 列出任务的执行记录
+//   - POST   /api/v1/tasks/{id}/followup - 追问：在任务的持久会话中以新的提示词继续执行
 //   - GET    /api/v1/runs/{id}       - 获取执行详情
 //   - PATCH  /api/v1/runs/{id}       - 更新执行状态
 //   - POST   /api/v1/runs/{id}/cancel - 取消执行
+//   - GET    /api/v1/runs/{id}/artifacts - 列出执行产物
+//   - POST   /api/v1/runs/{id}/artifacts - 上报执行产物（如 Git 回写的 PR 地址）
+//   - GET    /api/v1/runs/{id}/diff - 获取代码变更报告（逐文件统计与 unified diff）
+//   - POST   /api/v1/runs/{id}/diff - 上传代码变更报告（NodeManager 在 Git 工作空间的 Run 结束后调用）
+//   - POST   /api/v1/runs/{id}/context - 记录 Run 产出的上下文（NodeManager 在 Run 成功结束后调用，供子任务继承）
+//   - POST   /api/v1/runs/{id}/publish - 发布结果到触发任务的 PR/MR（dry_run 仅渲染评论）
+//   - GET    /api/v1/runs/{id}/flags - 列出内容审核标记（PII / 违规内容命中记录）
+//   - GET    /api/v1/runs/{id}/lifecycle - 查询 Run 数据层级（hot/warm/cold）与归档信息
+//   - GET    /api/v1/runs/{id}/usage - 查询 Run 累计的 Token 用量与费用
+//
+// 用量统计 (Usage):
+//   - GET    /api/v1/usage?group_by= - 按任务、项目、账号、Agent 类型或日期汇总 Token 用量与费用
+//
+// 预算 (Budget，增删改仅限管理员；预算耗尽时调度器暂停分派受约束的新 Run):
+//   - GET    /api/v1/budgets        - 列出预算及当月已用量
+//   - POST   /api/v1/budgets        - 创建账号或项目的月度预算
+//   - GET    /api/v1/budgets/{id}   - 获取预算及当月已用量
+//   - PATCH  /api/v1/budgets/{id}   - 更新预算上限、预警阈值或启用状态
+//   - DELETE /api/v1/budgets/{id}   - 删除预算
+//
+// 账号池 (Account Pool，项目任务执行时按账号负载租用账号，触发限流时切换账号):
+//   - GET    /api/v1/accounts/usage             - 列出账号的并发数、当日请求数、额度与冷却状态
+//   - POST   /api/v1/runs/{id}/account/lease    - 租用账号池中负载最低的可用账号（NodeManager 开始执行时调用）
+//   - POST   /api/v1/runs/{id}/account/failover - 账号进入冷却，Run 释放账号后重新排队（NodeManager 检测到限流时调用）
+//
+// 子任务派生 (Sub-task，Agent 执行中拆分任务，父任务状态按子任务汇总):
+//   - POST   /api/v1/runs/{id}/subtasks - 为执行中的 Run 派生子任务并创建其 Run（NodeManager 收到 spawn_subtask 事件时调用）
+//   - GET    /api/v1/runs/{id}/subtasks - 列出 Run 派生的子任务及汇总状态
+//
+// 工作流管理 (Workflow，子任务按 depends_on 依赖边编排执行):
+//   - POST   /api/v1/workflows        - 创建工作流（立即启动无依赖的节点）
+//   - GET    /api/v1/workflows        - 列出工作流
+//   - GET    /api/v1/workflows/{id}   - 获取工作流详情（节点状态与 Run ID）
+//   - POST   /api/v1/workflows/{id}/cancel - 取消工作流
+//
+// 外部 Issue 集成 (Integration，增删改仅限管理员):
+//   - GET    /api/v1/integrations              - 列出集成（GitHub 仓库 / Jira 项目）
+//   - POST   /api/v1/integrations              - 创建集成
+//   - GET    /api/v1/integrations/{id}         - 获取集成详情
+//   - PUT    /api/v1/integrations/{id}         - 更新集成
+//   - DELETE /api/v1/integrations/{id}         - 删除集成
+//   - GET    /api/v1/integrations/{id}/issues  - 列出可导入的未关闭 Issue
+//   - POST   /api/v1/integrations/import       - 将选中的 Issue 导入为任务
+//   - GET    /api/v1/tasks/{id}/issue-link     - 获取任务关联的源 Issue
 //
 // 事件管理 (Event):
 //   - GET    /api/v1/runs/{id}/events - 获取事件列表
+//   - GET    /api/v1/runs/{id}/events/raw - 导出原始输出行（事件回放输入）
+//   - GET    /api/v1/runs/{id}/transcript - 流式导出会话记录（format=jsonl|markdown|html）
+//   - POST   /api/v1/runs/{id}/replay - 用适配器重新解析原始输出，生成合成 Run 并比对事件类型
 //   - POST   /api/v1/runs/{id}/events - 批量上报事件
+//   - GET    /api/v1/runs/{id}/node-logs - 查询节点日志（Workspace 准备、容器定位、Agent 进程 stderr）
+//   - POST   /api/v1/runs/{id}/node-logs - 上报节点日志
+//   - GET    /api/v1/search/events?q= - 全文检索事件（payload 与原始输出，含高亮片段）
+//   - GET    /api/v1/event-schemas - 事件结构定义（版本化，入库时据此校验并标注 schema_version）
 //
 // 节点管理 (Node):
 //   - POST   /api/v1/nodes/heartbeat  - 节点心跳
@@ -58,6 +147,46 @@ import (
 //   - PATCH  /api/v1/nodes/{id}       - 更新节点
 //   - DELETE /api/v1/nodes/{id}       - 删除节点
 //   - GET    /api/v1/nodes/{id}/runs  - 获取节点的执行任务
+//   - GET    /api/v1/nodes/{id}/desired-state  - 节点期望状态（实例、认证会话、终端）
+//   - POST   /api/v1/nodes/{id}/observed-state - 上报节点观测状态（条件更新，不覆盖用户修改）
+//
+// 节点反向隧道 (Tunnel，节点主动建立，无需开放入站端口):
+//   - GET    /api/v1/nodes/{id}/tunnel - 节点建立隧道（WebSocket，节点凭证认证）
+//   - GET    /api/v1/tunnels           - 列出已建立隧道的节点（仅限管理员）
+//   - GET    /api/v1/runs/{id}/files?path= - 经隧道读取运行中 Run 工作目录下的文件
+//   - GET    /api/v1/runs/{id}/logs/live   - 经隧道推送运行中 Run 的实时输出
+//
+// 系统状态 (System):
+//   - GET    /api/v1/system/status    - 系统状态页（依赖健康与延迟、调度队列、节点在线情况、最近错误率）
+//   - GET    /api/v1/system/config    - 生效配置（凭据隐藏）与热加载状态（仅限管理员）
+//   - POST   /api/v1/system/config/reload - 立即重新加载配置文件（仅限管理员）
+//
+// 管理后台 (Admin，仅限管理员):
+//   - GET    /api/v1/admin/overview   - 系统总览（组件健康、调度、节点、吞吐、错误预算、存储、待审批）
+//   - GET    /api/v1/audit                      - 审计日志（变更类请求的操作者、路由、资源、请求内容与来源 IP）
+//   - GET    /api/v1/audit/export               - 导出审计日志（csv / jsonl）
+//   - GET    /api/v1/admin/maintenance/estimate - 存储维护可回收空间估算
+//   - GET    /api/v1/admin/maintenance/runs     - 存储维护历史
+//   - POST   /api/v1/admin/maintenance/runs     - 手动触发存储维护
+//   - GET    /api/v1/admin/lifecycle            - Run 数据分层策略与各层统计
+//   - POST   /api/v1/admin/lifecycle/run        - 手动触发一轮数据分层迁移
+//   - GET    /api/v1/webhooks                   - 列出 Webhook 订阅
+//   - POST   /api/v1/webhooks                   - 创建 Webhook 订阅（过滤条件：事件类型、项目、模板、节点、标签、状态迁移）
+//   - GET    /api/v1/webhooks/{id}              - 获取 Webhook 订阅
+//   - PATCH  /api/v1/webhooks/{id}              - 更新 Webhook 订阅
+//   - DELETE /api/v1/webhooks/{id}              - 删除 Webhook 订阅
+//   - POST   /api/v1/webhooks/{id}/test         - 同步投递 ping 事件
+//   - GET    /api/v1/registry                   - 模板注册表配置与最近一轮同步结果
+//   - POST   /api/v1/registry/sync              - 立即同步任务模板、Agent 模板与技能
+//   - GET    /api/v1/registry/items             - 同步条目（上游版本、已安装版本、是否有可用更新）
+//   - POST   /api/v1/registry/items/{id}/update - 安装条目的上游版本
+//   - POST   /api/v1/registry/items/{id}/pin    - 固定条目在已安装版本
+//   - DELETE /api/v1/registry/items/{id}/pin    - 取消固定
+//   - POST   /api/v1/apply                      - 按声明式期望状态同步项目、模板、Webhook 与代理（支持 dry_run）
+//
+// Agent 网关（OpenAI 兼容，启用 gateway 时注册）:
+//   - GET    /v1/models               - 列出网关模型
+//   - POST   /v1/chat/completions     - 以一次 Run 完成对话（支持 stream）
 //
 // WebSocket:
 //   - GET    /ws/runs/{id}/events     - 实时事件推送
@@ -75,21 +204,56 @@ func (h *Handler) Router() http.Handler {
 
 	// Task 接口（已迁移到 task 包）
 	taskHandler := task.NewHandler(h.store)
+	taskHandler.SetRuns(h.runs)
+	taskHandler.SetIdempotency(h.idempotency)
 	taskHandler.RegisterRoutes(mux)
 
 	// Run 接口（已迁移到 run 包）
 	// 传入调度队列支持事件驱动调度
-	runHandler := run.NewHandler(h.store, h.schedulerQueue)
+	runHandler := h.runs
+	runHandler.OnRunFinished(func(*model.Run) { h.orchestrator.Notify() })
+	if h.minioClient != nil {
+		runHandler.SetObjectStore(h.minioClient)
+	}
 	runHandler.RegisterRoutes(mux)
 
+	// 工作流接口（Run 到达终态时通知编排器推进下游节点）
+	workflowHandler := workflow.NewHandler(h.store, h.orchestrator)
+	workflowHandler.RegisterRoutes(mux)
+
+	// 外部 Issue 集成接口（导入 GitHub/Jira Issue 为任务，Run 结束后回写结果评论）
+	integrationHandler := integration.NewHandler(h.store, h.fieldEncryption)
+	runHandler.OnRunFinished(integrationHandler.RunFinished)
+	integrationHandler.RegisterRoutes(mux)
+
+	// 结果发布接口（PR/MR 触发的任务 Run 结束后发表评论/提交状态）
+	publishHandler := publish.NewHandler(h.store, h.fieldEncryption)
+	runHandler.OnRunFinished(publishHandler.RunFinished)
+	publishHandler.RegisterRoutes(mux)
+
+	// 内容审核标记查询接口（审核在事件上报时执行，见 PostEvents）
+	moderation.NewHandler(h.store).RegisterRoutes(mux)
+
 	// Event 接口
 	mux.HandleFunc("GET /api/v1/runs/{id}/events", h.GetEvents)
 	mux.HandleFunc("POST /api/v1/runs/{id}/events", h.PostEvents)
+	mux.HandleFunc("GET /api/v1/runs/{id}/events/raw", h.ExportRawEvents)
+	mux.HandleFunc("GET /api/v1/runs/{id}/transcript", h.ExportTranscript)
+	mux.HandleFunc("GET /api/v1/runs/{id}/node-logs", h.GetNodeLogs)
+	mux.HandleFunc("POST /api/v1/runs/{id}/node-logs", h.PostNodeLogs)
+	mux.HandleFunc("GET /api/v1/search/events", h.SearchEvents)
+	mux.HandleFunc("GET /api/v1/event-schemas", h.GetEventSchemas)
+
+	// Run 回放（验证适配器解析改动）
+	replay.NewHandler(h.store, h.eventReader()).RegisterRoutes(mux)
 
 	// Node 接口（已迁移到 node 包）
-	nodeHandler := node.NewHandler(h.store)
+	nodeHandler := h.nodes
 	nodeHandler.RegisterRoutes(mux)
 
+	// 节点期望状态同步（实例、认证会话、终端的声明式对齐）
+	nodestate.NewHandler(h.store).RegisterRoutes(mux)
+
 	// ========== 新架构 API ==========
 
 	// 系统操作（Operation/Action 统一模型）
@@ -103,24 +267,60 @@ func (h *Handler) Router() http.Handler {
 	proxyHandler := proxy.NewHandler(h.store)
 	proxyHandler.RegisterRoutes(mux)
 
+	// Git 凭据管理接口（存储层加密，明文仅 NodeManager 可解析）
+	credHandler := credential.NewHandler(h.store, h.fieldEncryption)
+	credHandler.RegisterRoutes(mux)
+
+	// 运行时密钥管理接口（存储层加密，执行时由 NodeManager 解析并注入容器环境变量）
+	secretHandler := secret.NewHandler(h.store, h.fieldEncryption)
+	secretHandler.RegisterRoutes(mux)
+
+	// 项目管理接口（任务默认 Agent 类型、账号池与安全配置）
+	projectHandler := project.NewHandler(h.store)
+	projectHandler.RegisterRoutes(mux)
+
+	// 区域管理接口（节点按 region 标签分组，任务按区域调度，含各区域排队统计）
+	region.NewHandler(h.store).RegisterRoutes(mux)
+
 	// Agent 实例管理接口（路由 /api/v1/agents）
 	instHandler := instance.NewHandler(h.store)
 	instHandler.RegisterRoutes(mux)
 	instHandler.RegisterNodeManagerRoutes(mux)
 
-	// 终端会话接口（已迁移到 terminal 包）
-	termHandler := terminal.NewHandler(h.store)
-	termHandler.RegisterRoutes(mux)
-	termHandler.RegisterNodeManagerRoutes(mux)
+	// 终端会话接口（已迁移到 terminal 包，含 Run 终端附加）
+	h.terminals.RegisterRoutes(mux)
+	h.terminals.RegisterNodeManagerRoutes(mux)
+
+	// 节点反向隧道：Run 文件获取、实时日志与隧道列表（节点建立隧道的 WebSocket 路由在 topMux 上）
+	tunnel.NewHandler(h.tunnels, h.store).RegisterRoutes(mux)
 
 	// 模板 API（已迁移到 template 包）
 	tmplHandler := template.NewHandler(h.store)
+	if h.minioClient != nil {
+		tmplHandler.SetObjectStore(h.minioClient)
+	}
 	tmplHandler.RegisterRoutes(mux)
 
 	// HITL 接口（已迁移到 hitl 包）
 	hitlHandler := hitl.NewHandler(h.store)
 	hitlHandler.RegisterRoutes(mux)
 
+	// 用量统计接口（usage 事件按 Run 累加的 Token 用量与费用）
+	usage.NewHandler(h.store).RegisterRoutes(mux)
+
+	// 预算接口（账号/项目月度 Token 与费用上限）
+	budget.NewHandler(h.store, h.budgets).RegisterRoutes(mux)
+
+	// 账号池接口（账号用量；NodeManager 租用账号与限流切换）
+	runHandler.OnRunFinished(h.accountPool.RunFinished)
+	accountpool.NewHandler(h.store, h.accountPool).RegisterRoutes(mux)
+
+	// 子任务派生接口（Run 到达终态时沿派生链汇总父任务状态）
+	subtaskHandler := subtask.NewHandler(h.store, h.runs, h.subtaskLimits)
+	subtaskHandler.SetIdempotency(h.idempotency)
+	runHandler.OnRunFinished(subtaskHandler.RunFinished)
+	subtaskHandler.RegisterRoutes(mux)
+
 	// 系统配置管理接口
 	sysconfigHandler := sysconfig.NewHandler()
 	sysconfigHandler.RegisterRoutes(mux)
@@ -130,35 +330,71 @@ func (h *Handler) Router() http.Handler {
 	mux.HandleFunc("GET /api/v1/monitor/workflows/{type}/{id}", h.GetWorkflow)
 	mux.HandleFunc("GET /api/v1/monitor/workflows/{type}/{id}/events", h.GetWorkflowEvents)
 	mux.HandleFunc("GET /api/v1/monitor/stats", h.GetMonitorStats)
+	mux.HandleFunc("GET /api/v1/monitor/stream", newMonitorFeed(h, monitorStreamInterval).HandleStream)
 
-	// Auth 路由
-	authCfg := auth.Config{
-		JWTSecret:       h.authConfig.JWTSecret,
-		AccessTokenTTL:  h.authConfig.AccessTokenTTL,
-		RefreshTokenTTL: h.authConfig.RefreshTokenTTL,
-		NodeToken:       h.authConfig.NodeToken,
+	// ========== 系统状态 API ==========
+	mux.HandleFunc("GET /api/v1/system/status", h.GetSystemStatus)
+	if h.confReloader != nil {
+		h.confReloader.RegisterRoutes(mux)
 	}
-	authHandler := auth.NewHandler(h.store, authCfg)
-	authHandler.RegisterRoutes(mux)
 
-	// 应用指标中间件到 REST API
-	apiHandler := h.metrics.MetricsMiddleware(mux)
+	// ========== 管理后台 API ==========
+	mux.HandleFunc("GET /api/v1/admin/overview", h.GetAdminOverview)
+	audit.NewHandler(h.store).RegisterRoutes(mux)
+	if h.maintenance != nil {
+		maintenance.NewHandler(h.maintenance).RegisterRoutes(mux)
+	}
+	if h.lifecycle != nil {
+		lifecycle.NewHandler(h.lifecycle).RegisterRoutes(mux)
+	}
+	if h.webhooks != nil {
+		webhook.NewHandler(h.store, h.webhooks).RegisterRoutes(mux)
+	}
+	// 声明式配置（Webhook 未启用时不需要重新加载订阅）
+	var webhookReloader apply.Reloader
+	if h.webhooks != nil {
+		webhookReloader = h.webhooks
+	}
+	apply.NewHandler(h.store, webhookReloader).RegisterRoutes(mux)
+	if h.registry != nil {
+		registry.NewHandler(h.registry).RegisterRoutes(mux)
+	}
 
-	// 应用认证中间件
-	authedHandler := auth.Middleware(authCfg)(apiHandler)
+	// ========== Agent 网关 ==========
+	if h.gateway != nil {
+		h.gateway.RegisterRoutes(mux)
+	}
 
-	// 应用 CORS 中间件
-	corsHandler := corsMiddleware(authedHandler)
+	// Auth 路由（配置了 OIDC 时同时提供 SSO 登录）
+	authCfg := h.nodeAuthConfig()
+	authCfg.OIDC = h.authConfig.OIDC
+	authCfg.MasterKey = h.authConfig.MasterKey
+	h.authHandler = auth.NewHandler(h.store, authCfg)
+	h.authHandler.RegisterRoutes(mux)
+	authCfg.Sessions = h.authHandler
+
+	// 应用指标中间件到 REST API（NodeManager 轮询的接口支持 ETag 条件请求，变更类请求写入审计日志，
+	// 节点专属凭证只能访问分配给该节点的 Run）
+	auditor := audit.NewRecorder(h.store)
+	apiHandler := h.metrics.MetricsMiddleware(etagMiddleware(auditor.Middleware(h.nodeRunScope(mux, auditor)), nodePolledGET))
+
+	// 应用认证中间件（限流在认证之后执行，按用户计数并跳过节点请求）
+	authedHandler := auth.Middleware(authCfg)(h.rateLimiter.Middleware(apiHandler))
+
+	// 应用 CORS 与请求体大小限制中间件（超限的请求体在认证前拒绝）
+	corsHandler := corsMiddleware(h.bodyLimits.Middleware(authedHandler))
 
 	// 创建顶层路由，WebSocket 绑过 metrics 中间件（避免 http.Hijacker 问题）
 	topMux := http.NewServeMux()
 	monitorWS := NewMonitorWSHandler(h)
 	topMux.HandleFunc("GET /ws/monitor", monitorWS.HandleWebSocket)
 	topMux.HandleFunc("/ws/runs/{id}/events", h.eventGateway.HandleWebSocket)
+	topMux.HandleFunc("GET /api/v1/nodes/{node_id}/tunnel", h.tunnels.ConnectHandler(authCfg))
 
 	// OpenAPI 规范静态文件（/spec/openapi.yaml 等）
 	specFS, _ := fs.Sub(api.OpenAPIFS, "openapi")
 	topMux.Handle("/spec/", http.StripPrefix("/spec/", http.FileServer(http.FS(specFS))))
+	topMux.HandleFunc("GET /api/v1/openapi.json", GetOpenAPISpec)
 
 	// Swagger UI 文档页面（/docs/）
 	docsFS, _ := fs.Sub(api.DocsFS, "docs")
@@ -166,7 +402,45 @@ func (h *Handler) Router() http.Handler {
 
 	topMux.Handle("/", corsHandler)
 
-	return topMux
+	// 请求 ID 与访问日志在最外层，WebSocket 与文档路由同样带上请求 ID
+	return requestLogMiddleware(topMux)
+}
+
+// nodeAuthConfig 主 API 与 gRPC 节点接口共用的认证配置（接受节点共享密钥与节点专属凭证）
+func (h *Handler) nodeAuthConfig() auth.Config {
+	cfg := auth.Config{
+		JWTSecret:       h.authConfig.JWTSecret,
+		AccessTokenTTL:  h.authConfig.AccessTokenTTL,
+		RefreshTokenTTL: h.authConfig.RefreshTokenTTL,
+		NodeToken:       h.authConfig.NodeToken,
+		NodeCredentials: h.nodes,
+	}
+	if h.authConfig.DisableSharedNodeToken {
+		cfg.NodeToken = ""
+	}
+	return cfg
+}
+
+// GatewayRouter 独立监听的 Agent 网关路由（只包含 /v1 接口）
+//
+// 只接受用户 JWT 认证，节点凭证不能访问网关；未调用 SetGateway 时返回 nil。
+func (h *Handler) GatewayRouter() http.Handler {
+	if h.gateway == nil {
+		return nil
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", h.Health)
+	h.gateway.RegisterRoutes(mux)
+
+	authCfg := auth.Config{
+		JWTSecret:       h.authConfig.JWTSecret,
+		AccessTokenTTL:  h.authConfig.AccessTokenTTL,
+		RefreshTokenTTL: h.authConfig.RefreshTokenTTL,
+	}
+	if h.authHandler != nil {
+		authCfg.Sessions = h.authHandler // Router 中创建，撤销的会话同样不能访问网关
+	}
+	return requestLogMiddleware(corsMiddleware(h.bodyLimits.Middleware(auth.Middleware(authCfg)(h.rateLimiter.Middleware(h.metrics.MetricsMiddleware(audit.NewRecorder(h.store).Middleware(mux)))))))
 }
 
 // corsMiddleware 添加 CORS 头支持跨域请求
@@ -174,7 +448,8 @@ func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, Idempotency-Key, X-Request-ID")
+		w.Header().Set("Access-Control-Expose-Headers", "X-Request-ID")
 
 		if r.Method == "OPTIONS" {
 			w.WriteHeader(http.StatusOK)
@@ -3,12 +3,23 @@ package server
 
 import (
 	"bufio"
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"strconv"
 	"time"
 
+	"agents-admin/internal/apiserver/eventsink"
+	"agents-admin/internal/apiserver/moderation"
+	"agents-admin/internal/apiserver/ratelimit"
+	"agents-admin/internal/apiserver/region"
+	"agents-admin/internal/shared/model"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -42,11 +53,23 @@ type Metrics struct {
 	// 数据库指标
 	DBQueryTotal    *prometheus.CounterVec
 	DBQueryDuration *prometheus.HistogramVec
+
+	// Run 数据分层指标
+	RunDataTierRuns   *prometheus.GaugeVec
+	RunDataTierEvents *prometheus.GaugeVec
+	RunDataTierBytes  *prometheus.GaugeVec
+
+	// 最近的请求数与 5xx 数（系统状态页的 API 错误率）
+	recent *requestWindow
+
+	namespace string
 }
 
 // NewMetrics 创建指标实例
 func NewMetrics(namespace string) *Metrics {
 	return &Metrics{
+		namespace: namespace,
+		recent:    &requestWindow{},
 		HTTPRequestsTotal: promauto.NewCounterVec(
 			prometheus.CounterOpts{
 				Namespace: namespace,
@@ -164,6 +187,30 @@ func NewMetrics(namespace string) *Metrics {
 			},
 			[]string{"operation", "table"},
 		),
+		RunDataTierRuns: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "run_data_tier_runs",
+				Help:      "Number of finished runs by data tier",
+			},
+			[]string{"tier"},
+		),
+		RunDataTierEvents: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "run_data_tier_events",
+				Help:      "Number of run events by data tier",
+			},
+			[]string{"tier"},
+		),
+		RunDataTierBytes: promauto.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Namespace: namespace,
+				Name:      "run_data_tier_archive_bytes",
+				Help:      "Compressed archive size in object storage by data tier",
+			},
+			[]string{"tier"},
+		),
 	}
 }
 
@@ -185,6 +232,7 @@ func (m *Metrics) MetricsMiddleware(next http.Handler) http.Handler {
 
 		m.HTTPRequestsTotal.WithLabelValues(r.Method, path, status).Inc()
 		m.HTTPRequestDuration.WithLabelValues(r.Method, path).Observe(duration)
+		m.recent.record(time.Now(), wrapped.statusCode)
 	})
 }
 
@@ -207,6 +255,11 @@ func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
 	return nil, nil, fmt.Errorf("response writer does not support hijacking")
 }
 
+// Unwrap 供 http.ResponseController 访问底层连接（SSE 需要 Flush 与清除写超时）
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // normalizePath 规范化路径，将 ID 替换为占位符
 func normalizePath(path string) string {
 	// 简单的路径规范化，避免高基数
@@ -269,6 +322,16 @@ func (m *Metrics) SetRunsCount(status, agentType string, count int) {
 	m.RunsTotal.WithLabelValues(status, agentType).Set(float64(count))
 }
 
+// RecordRunDataTiers 记录各数据层级的 Run 数、事件数与归档大小
+func (m *Metrics) RecordRunDataTiers(stats []model.RunTierStats) {
+	for _, st := range stats {
+		tier := string(st.Tier)
+		m.RunDataTierRuns.WithLabelValues(tier).Set(float64(st.Runs))
+		m.RunDataTierEvents.WithLabelValues(tier).Set(float64(st.Events))
+		m.RunDataTierBytes.WithLabelValues(tier).Set(float64(st.ArchiveBytes))
+	}
+}
+
 // WSConnectionOpened WebSocket 连接打开
 func (m *Metrics) WSConnectionOpened() {
 	m.WSConnectionsActive.Inc()
@@ -278,3 +341,274 @@ func (m *Metrics) WSConnectionOpened() {
 func (m *Metrics) WSConnectionClosed() {
 	m.WSConnectionsActive.Dec()
 }
+
+// dbPoolReporter 可提供连接池统计的存储（PostgreSQL / MySQL / SQLite）
+type dbPoolReporter interface {
+	PoolStats() map[string]sql.DBStats
+}
+
+// RegisterDBPool 注册数据库连接池指标（采集时读取 sql.DBStats，按 pool=primary/replica 区分）
+//
+// 关注 in_use 接近 max_open 且 wait_count 持续增长的情况：请求在排队等连接，需要调大连接池或数据库连接上限。
+func (m *Metrics) RegisterDBPool(src dbPoolReporter) {
+	err := prometheus.Register(newDBPoolCollector(m.namespace, src))
+	var are prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &are) {
+		log.Printf("[metrics.db_pool.register_failed] error=%v", err)
+	}
+}
+
+// dbPoolCollector 数据库连接池指标采集器
+type dbPoolCollector struct {
+	src dbPoolReporter
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	maxIdleClosed     *prometheus.Desc
+	maxIdleTimeClosed *prometheus.Desc
+	maxLifetimeClosed *prometheus.Desc
+}
+
+func newDBPoolCollector(namespace string, src dbPoolReporter) *dbPoolCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "db_pool", name), help, []string{"pool"}, nil)
+	}
+	return &dbPoolCollector{
+		src:               src,
+		maxOpen:           desc("max_open_connections", "Maximum number of open connections to the database"),
+		open:              desc("open_connections", "Number of established connections, both in use and idle"),
+		inUse:             desc("in_use_connections", "Number of connections currently in use"),
+		idle:              desc("idle_connections", "Number of idle connections"),
+		waitCount:         desc("wait_count_total", "Total number of connections waited for"),
+		waitDuration:      desc("wait_duration_seconds_total", "Total time blocked waiting for a new connection"),
+		maxIdleClosed:     desc("max_idle_closed_total", "Total number of connections closed due to max idle connections"),
+		maxIdleTimeClosed: desc("max_idle_time_closed_total", "Total number of connections closed due to max idle time"),
+		maxLifetimeClosed: desc("max_lifetime_closed_total", "Total number of connections closed due to max connection lifetime"),
+	}
+}
+
+func (c *dbPoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.maxOpen, c.open, c.inUse, c.idle, c.waitCount, c.waitDuration,
+		c.maxIdleClosed, c.maxIdleTimeClosed, c.maxLifetimeClosed} {
+		ch <- d
+	}
+}
+
+func (c *dbPoolCollector) Collect(ch chan<- prometheus.Metric) {
+	for pool, st := range c.src.PoolStats() {
+		ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(st.MaxOpenConnections), pool)
+		ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(st.OpenConnections), pool)
+		ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(st.InUse), pool)
+		ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(st.Idle), pool)
+		ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(st.WaitCount), pool)
+		ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, st.WaitDuration.Seconds(), pool)
+		ch <- prometheus.MustNewConstMetric(c.maxIdleClosed, prometheus.CounterValue, float64(st.MaxIdleClosed), pool)
+		ch <- prometheus.MustNewConstMetric(c.maxIdleTimeClosed, prometheus.CounterValue, float64(st.MaxIdleTimeClosed), pool)
+		ch <- prometheus.MustNewConstMetric(c.maxLifetimeClosed, prometheus.CounterValue, float64(st.MaxLifetimeClosed), pool)
+	}
+}
+
+// eventSinkReporter 可提供投递统计的事件镜像（eventsink.Sink）
+type eventSinkReporter interface {
+	Stats() eventsink.Stats
+}
+
+// RegisterEventSink 注册 Run 事件镜像指标（采集时读取 Sink 统计）
+//
+// lag_events 持续增长或 lag_seconds 超过数分钟说明 Kafka 不可用或吞吐不足，事件正在死信文件中积压。
+func (m *Metrics) RegisterEventSink(src eventSinkReporter) {
+	err := prometheus.Register(newEventSinkCollector(m.namespace, src))
+	var are prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &are) {
+		log.Printf("[metrics.event_sink.register_failed] error=%v", err)
+	}
+}
+
+// eventSinkCollector Run 事件镜像指标采集器
+type eventSinkCollector struct {
+	src eventSinkReporter
+
+	lagEvents      *prometheus.Desc
+	lagSeconds     *prometheus.Desc
+	delivered      *prometheus.Desc
+	deadLettered   *prometheus.Desc
+	deadLetterSize *prometheus.Desc
+}
+
+func newEventSinkCollector(namespace string, src eventSinkReporter) *eventSinkCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "event_sink", name), help, nil, nil)
+	}
+	return &eventSinkCollector{
+		src:            src,
+		lagEvents:      desc("lag_events", "Number of stored run events not yet delivered to the sink"),
+		lagSeconds:     desc("lag_seconds", "Age of the oldest run event not yet delivered to the sink"),
+		delivered:      desc("delivered_total", "Total number of run events delivered to the sink"),
+		deadLettered:   desc("dead_lettered_total", "Total number of run events written to the dead-letter file"),
+		deadLetterSize: desc("dead_letter_events", "Number of run events waiting in the dead-letter file for replay"),
+	}
+}
+
+func (c *eventSinkCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.lagEvents, c.lagSeconds, c.delivered, c.deadLettered, c.deadLetterSize} {
+		ch <- d
+	}
+}
+
+func (c *eventSinkCollector) Collect(ch chan<- prometheus.Metric) {
+	st := c.src.Stats()
+	ch <- prometheus.MustNewConstMetric(c.lagEvents, prometheus.GaugeValue, float64(st.LagEvents))
+	ch <- prometheus.MustNewConstMetric(c.lagSeconds, prometheus.GaugeValue, st.LagSeconds)
+	ch <- prometheus.MustNewConstMetric(c.delivered, prometheus.CounterValue, float64(st.Delivered))
+	ch <- prometheus.MustNewConstMetric(c.deadLettered, prometheus.CounterValue, float64(st.DeadLettered))
+	ch <- prometheus.MustNewConstMetric(c.deadLetterSize, prometheus.GaugeValue, float64(st.DeadLetterSize))
+}
+
+// moderationReporter 可提供命中统计的内容审核处理器（moderation.Processor）
+type moderationReporter interface {
+	Stats() moderation.Stats
+}
+
+// RegisterModeration 注册内容审核掩码指标（采集时读取处理器统计）
+func (m *Metrics) RegisterModeration(src moderationReporter) {
+	err := prometheus.Register(newModerationCollector(m.namespace, src))
+	var are prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &are) {
+		log.Printf("[metrics.moderation.register_failed] error=%v", err)
+	}
+}
+
+// moderationCollector 内容审核掩码指标采集器
+type moderationCollector struct {
+	src moderationReporter
+
+	redactions     *prometheus.Desc
+	redactedEvents *prometheus.Desc
+}
+
+func newModerationCollector(namespace string, src moderationReporter) *moderationCollector {
+	return &moderationCollector{
+		src: src,
+		redactions: prometheus.NewDesc(prometheus.BuildFQName(namespace, "moderation", "redactions_total"),
+			"Total number of redacted matches in run events by detector", []string{"detector"}, nil),
+		redactedEvents: prometheus.NewDesc(prometheus.BuildFQName(namespace, "moderation", "redacted_events_total"),
+			"Total number of run events stored with redacted content", nil, nil),
+	}
+}
+
+func (c *moderationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.redactions
+	ch <- c.redactedEvents
+}
+
+func (c *moderationCollector) Collect(ch chan<- prometheus.Metric) {
+	st := c.src.Stats()
+	for detector, n := range st.Hits {
+		ch <- prometheus.MustNewConstMetric(c.redactions, prometheus.CounterValue, float64(n), detector)
+	}
+	ch <- prometheus.MustNewConstMetric(c.redactedEvents, prometheus.CounterValue, float64(st.RedactedEvents))
+}
+
+// RegisterRateLimit 注册限流指标（采集时读取限流器统计）
+func (m *Metrics) RegisterRateLimit(src *ratelimit.Limiter) {
+	err := prometheus.Register(newRateLimitCollector(m.namespace, src))
+	var are prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &are) {
+		log.Printf("[metrics.ratelimit.register_failed] error=%v", err)
+	}
+}
+
+// rateLimitCollector 限流指标采集器
+type rateLimitCollector struct {
+	src *ratelimit.Limiter
+
+	throttled *prometheus.Desc
+}
+
+func newRateLimitCollector(namespace string, src *ratelimit.Limiter) *rateLimitCollector {
+	return &rateLimitCollector{
+		src: src,
+		throttled: prometheus.NewDesc(prometheus.BuildFQName(namespace, "ratelimit", "throttled_requests_total"),
+			"Total number of requests rejected with 429 by scope (ip, node, user, login, lockout)", []string{"scope"}, nil),
+	}
+}
+
+func (c *rateLimitCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.throttled
+}
+
+func (c *rateLimitCollector) Collect(ch chan<- prometheus.Metric) {
+	for scope, n := range c.src.Stats().Throttled {
+		ch <- prometheus.MustNewConstMetric(c.throttled, prometheus.CounterValue, float64(n), scope)
+	}
+}
+
+// regionQueueTimeout 采集区域统计的超时时间
+const regionQueueTimeout = 5 * time.Second
+
+// RegisterRegionQueue 注册区域排队指标（采集时统计各区域的排队 Run 与在线节点）
+//
+// 某区域 queued_runs 持续增长而 online_nodes 为 0 或 running 接近 capacity，说明该区域容量不足，
+// 需要扩容节点或调整区域的回退策略。
+func (m *Metrics) RegisterRegionQueue(store region.Store) {
+	collect := func(ctx context.Context) ([]model.RegionQueueStats, error) {
+		return region.CollectStats(ctx, store)
+	}
+	err := prometheus.Register(newRegionQueueCollector(m.namespace, collect))
+	var are prometheus.AlreadyRegisteredError
+	if err != nil && !errors.As(err, &are) {
+		log.Printf("[metrics.region_queue.register_failed] error=%v", err)
+	}
+}
+
+// regionQueueCollector 区域排队指标采集器
+type regionQueueCollector struct {
+	collect func(ctx context.Context) ([]model.RegionQueueStats, error)
+
+	queuedRuns   *prometheus.Desc
+	oldestQueued *prometheus.Desc
+	onlineNodes  *prometheus.Desc
+	capacity     *prometheus.Desc
+	running      *prometheus.Desc
+}
+
+func newRegionQueueCollector(namespace string, collect func(ctx context.Context) ([]model.RegionQueueStats, error)) *regionQueueCollector {
+	desc := func(name, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(namespace, "region", name), help, []string{"region"}, nil)
+	}
+	return &regionQueueCollector{
+		collect:      collect,
+		queuedRuns:   desc("queued_runs", "Number of queued runs whose task targets the region"),
+		oldestQueued: desc("oldest_queued_seconds", "Age of the oldest queued run whose task targets the region"),
+		onlineNodes:  desc("online_nodes", "Number of online nodes labelled with the region"),
+		capacity:     desc("capacity", "Sum of max concurrent runs of online nodes in the region"),
+		running:      desc("running", "Number of runs occupying slots on online nodes in the region"),
+	}
+}
+
+func (c *regionQueueCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range []*prometheus.Desc{c.queuedRuns, c.oldestQueued, c.onlineNodes, c.capacity, c.running} {
+		ch <- d
+	}
+}
+
+func (c *regionQueueCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), regionQueueTimeout)
+	defer cancel()
+	stats, err := c.collect(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "metrics.region_queue.collect_failed", "error", err)
+		return
+	}
+	for _, s := range stats {
+		ch <- prometheus.MustNewConstMetric(c.queuedRuns, prometheus.GaugeValue, float64(s.QueuedRuns), s.Region)
+		ch <- prometheus.MustNewConstMetric(c.oldestQueued, prometheus.GaugeValue, s.OldestQueuedSeconds, s.Region)
+		ch <- prometheus.MustNewConstMetric(c.onlineNodes, prometheus.GaugeValue, float64(s.OnlineNodes), s.Region)
+		ch <- prometheus.MustNewConstMetric(c.capacity, prometheus.GaugeValue, float64(s.Capacity), s.Region)
+		ch <- prometheus.MustNewConstMetric(c.running, prometheus.GaugeValue, float64(s.Running), s.Region)
+	}
+}
@@ -0,0 +1,87 @@
+package server
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/eventsink"
+	"agents-admin/internal/shared/model"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type poolStatsStub map[string]sql.DBStats
+
+func (p poolStatsStub) PoolStats() map[string]sql.DBStats { return p }
+
+func TestDBPoolCollector(t *testing.T) {
+	c := newDBPoolCollector("api", poolStatsStub{
+		"primary": {MaxOpenConnections: 25, OpenConnections: 10, InUse: 7, Idle: 3, WaitCount: 4, WaitDuration: 1500 * time.Millisecond},
+		"replica": {MaxOpenConnections: 25},
+	})
+	assert.Equal(t, 18, testutil.CollectAndCount(c))
+
+	err := testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP api_db_pool_in_use_connections Number of connections currently in use
+# TYPE api_db_pool_in_use_connections gauge
+api_db_pool_in_use_connections{pool="primary"} 7
+api_db_pool_in_use_connections{pool="replica"} 0
+# HELP api_db_pool_wait_duration_seconds_total Total time blocked waiting for a new connection
+# TYPE api_db_pool_wait_duration_seconds_total counter
+api_db_pool_wait_duration_seconds_total{pool="primary"} 1.5
+api_db_pool_wait_duration_seconds_total{pool="replica"} 0
+`), "api_db_pool_in_use_connections", "api_db_pool_wait_duration_seconds_total")
+	require.NoError(t, err)
+}
+
+type eventSinkStub eventsink.Stats
+
+func (s eventSinkStub) Stats() eventsink.Stats { return eventsink.Stats(s) }
+
+func TestEventSinkCollector(t *testing.T) {
+	c := newEventSinkCollector("api", eventSinkStub{LagEvents: 120, LagSeconds: 42.5, Delivered: 1000, DeadLettered: 100, DeadLetterSize: 100})
+	assert.Equal(t, 5, testutil.CollectAndCount(c))
+
+	err := testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP api_event_sink_lag_events Number of stored run events not yet delivered to the sink
+# TYPE api_event_sink_lag_events gauge
+api_event_sink_lag_events 120
+# HELP api_event_sink_lag_seconds Age of the oldest run event not yet delivered to the sink
+# TYPE api_event_sink_lag_seconds gauge
+api_event_sink_lag_seconds 42.5
+`), "api_event_sink_lag_events", "api_event_sink_lag_seconds")
+	require.NoError(t, err)
+}
+
+func TestRegionQueueCollector(t *testing.T) {
+	c := newRegionQueueCollector("api", func(context.Context) ([]model.RegionQueueStats, error) {
+		return []model.RegionQueueStats{
+			{Region: "cn-east", QueuedRuns: 4, OldestQueuedSeconds: 90, OnlineNodes: 2, Capacity: 6, Running: 6},
+			{Region: "us-west", OnlineNodes: 1, Capacity: 2},
+		}, nil
+	})
+	assert.Equal(t, 10, testutil.CollectAndCount(c))
+
+	err := testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP api_region_queued_runs Number of queued runs whose task targets the region
+# TYPE api_region_queued_runs gauge
+api_region_queued_runs{region="cn-east"} 4
+api_region_queued_runs{region="us-west"} 0
+# HELP api_region_oldest_queued_seconds Age of the oldest queued run whose task targets the region
+# TYPE api_region_oldest_queued_seconds gauge
+api_region_oldest_queued_seconds{region="cn-east"} 90
+api_region_oldest_queued_seconds{region="us-west"} 0
+`), "api_region_queued_runs", "api_region_oldest_queued_seconds")
+	require.NoError(t, err)
+
+	failing := newRegionQueueCollector("api", func(context.Context) ([]model.RegionQueueStats, error) {
+		return nil, errors.New("db down")
+	})
+	assert.Equal(t, 0, testutil.CollectAndCount(failing))
+}
@@ -8,10 +8,14 @@
 //   - GET /api/v1/monitor/workflows/{type}/{id}  - 获取工作流详情
 //   - GET /api/v1/monitor/workflows/{type}/{id}/events - 获取工作流事件
 //   - GET /api/v1/monitor/stats              - 获取监控统计
+//   - GET /api/v1/monitor/stream             - SSE 推送工作流与统计变化（见 monitor_stream.go）
+//
+// 除 stream 外所有端点支持条件请求（ETag / Last-Modified），供前端轮询时避免重复下载未变化的数据。
 package server
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"sort"
 	"time"
@@ -115,6 +119,12 @@ func (h *Handler) ListWorkflows(w http.ResponseWriter, r *http.Request) {
 		return workflows[i].UpdateTime.After(*workflows[j].UpdateTime)
 	})
 
+	// 列表整体的最后修改时间（分页前计算，避免翻页外的变化被忽略）
+	var lastModified time.Time
+	if len(workflows) > 0 && workflows[0].UpdateTime != nil {
+		lastModified = *workflows[0].UpdateTime
+	}
+
 	// 分页
 	total := len(workflows)
 	if offset < len(workflows) {
@@ -126,6 +136,11 @@ func (h *Handler) ListWorkflows(w http.ResponseWriter, r *http.Request) {
 		workflows = workflows[:limit]
 	}
 
+	parts := append([]interface{}{"workflows", workflowType, state, total, limit, offset}, workflowVersion(workflows...)...)
+	if checkNotModified(w, r, versionETag(parts...), lastModified) {
+		return
+	}
+
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"workflows": workflows,
 		"total":     total,
@@ -170,6 +185,15 @@ func (h *Handler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	lastModified := eventsLastModified(detail.Events)
+	if detail.UpdateTime != nil && detail.UpdateTime.After(lastModified) {
+		lastModified = *detail.UpdateTime
+	}
+	parts := append(workflowVersion(detail.WorkflowSummary), len(detail.Events), lastModified)
+	if checkNotModified(w, r, versionETag(parts...), lastModified) {
+		return
+	}
+
 	writeJSON(w, http.StatusOK, detail)
 }
 
@@ -179,6 +203,9 @@ func (h *Handler) GetWorkflow(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) GetMonitorStats(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	stats := h.calculateStats(ctx)
+	if checkNotModified(w, r, versionETag("stats", fmt.Sprintf("%+v", stats)), time.Time{}) {
+		return
+	}
 	writeJSON(w, http.StatusOK, stats)
 }
 
@@ -195,9 +222,47 @@ func (h *Handler) GetWorkflowEvents(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Run 事件只追加不修改：先以事件数校验，命中时无需加载事件
+	if workflowType == "run" {
+		count, err := h.eventReader().CountEventsByRun(ctx, workflowID)
+		if err == nil && checkNotModified(w, r, versionETag("run-events", workflowID, count), time.Time{}) {
+			return
+		}
+	}
+
 	events := h.getWorkflowEvents(ctx, workflowType, workflowID)
+	if workflowType != "run" {
+		lastModified := eventsLastModified(events)
+		if checkNotModified(w, r, versionETag("events", workflowType, workflowID, len(events), lastModified), lastModified) {
+			return
+		}
+	}
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"events": events,
 		"total":  len(events),
 	})
 }
+
+// workflowVersion 提取工作流摘要中参与 ETag 计算的版本字段
+func workflowVersion(workflows ...WorkflowSummary) []interface{} {
+	parts := make([]interface{}, 0, len(workflows)*5)
+	for _, wf := range workflows {
+		var updated time.Time
+		if wf.UpdateTime != nil {
+			updated = *wf.UpdateTime
+		}
+		parts = append(parts, wf.ID, wf.State, wf.Progress, wf.EventCount, updated)
+	}
+	return parts
+}
+
+// eventsLastModified 返回事件列表中最新的事件时间
+func eventsLastModified(events []WorkflowEventView) time.Time {
+	var latest time.Time
+	for _, e := range events {
+		if e.Timestamp.After(latest) {
+			latest = e.Timestamp
+		}
+	}
+	return latest
+}
@@ -9,6 +9,8 @@
 //   - getWorkflowEvents: 事件流查询
 //   - calculateStats: 统计指标计算
 //   - 辅助函数：状态映射、进度计算、事件级别判断
+//
+// 聚合查询只用于展示，均允许读取只读副本（storage.WithReadReplica）。
 package server
 
 import (
@@ -26,6 +28,7 @@ import (
 // ========== 数据聚合方法 ==========
 
 func (h *Handler) getAuthWorkflows(ctx context.Context, stateFilter string) []WorkflowSummary {
+	ctx = storage.WithReadReplica(ctx)
 	var workflows []WorkflowSummary
 
 	// 从 Redis 获取认证会话
@@ -80,6 +83,7 @@ func (h *Handler) getAuthWorkflows(ctx context.Context, stateFilter string) []Wo
 }
 
 func (h *Handler) getRunWorkflows(ctx context.Context, stateFilter string) []WorkflowSummary {
+	ctx = storage.WithReadReplica(ctx)
 	var workflows []WorkflowSummary
 
 	// 从 PostgreSQL 获取运行任务（获取所有任务的最近执行）
@@ -130,7 +134,7 @@ func (h *Handler) getRunWorkflows(ctx context.Context, stateFilter string) []Wor
 		}
 
 		// 获取事件数量
-		events, _ := h.store.GetEventsByRun(ctx, run.ID, 0, 1000)
+		events, _ := h.eventReader().GetEventsByRun(ctx, run.ID, 0, 1000)
 		summary.EventCount = len(events)
 
 		workflows = append(workflows, summary)
@@ -140,6 +144,7 @@ func (h *Handler) getRunWorkflows(ctx context.Context, stateFilter string) []Wor
 }
 
 func (h *Handler) getAuthWorkflowDetail(ctx context.Context, id string) (*WorkflowDetail, error) {
+	ctx = storage.WithReadReplica(ctx)
 	// 从 Redis 获取认证会话
 	session, err := h.redisStore.GetAuthSession(ctx, id)
 	if err != nil {
@@ -207,6 +212,7 @@ func (h *Handler) getAuthWorkflowDetail(ctx context.Context, id string) (*Workfl
 }
 
 func (h *Handler) getRunWorkflowDetail(ctx context.Context, id string) (*WorkflowDetail, error) {
+	ctx = storage.WithReadReplica(ctx)
 	run, err := h.store.GetRun(ctx, id)
 	if err != nil {
 		return nil, err
@@ -244,7 +250,7 @@ func (h *Handler) getRunWorkflowDetail(ctx context.Context, id string) (*Workflo
 	}
 
 	// 从 PostgreSQL 获取事件
-	events, _ := h.store.GetEventsByRun(ctx, id, 0, 1000)
+	events, _ := h.eventReader().GetEventsByRun(ctx, id, 0, 1000)
 	for _, evt := range events {
 		var data map[string]interface{}
 		if evt.Payload != nil {
@@ -265,6 +271,7 @@ func (h *Handler) getRunWorkflowDetail(ctx context.Context, id string) (*Workflo
 }
 
 func (h *Handler) getWorkflowEvents(ctx context.Context, workflowType, workflowID string) []WorkflowEventView {
+	ctx = storage.WithReadReplica(ctx)
 	var events []WorkflowEventView
 
 	switch workflowType {
@@ -284,7 +291,7 @@ func (h *Handler) getWorkflowEvents(ctx context.Context, workflowType, workflowI
 			}
 		}
 	case "run":
-		pgEvents, _ := h.store.GetEventsByRun(ctx, workflowID, 0, 1000)
+		pgEvents, _ := h.eventReader().GetEventsByRun(ctx, workflowID, 0, 1000)
 		for _, evt := range pgEvents {
 			var data map[string]interface{}
 			if evt.Payload != nil {
@@ -310,6 +317,7 @@ func (h *Handler) getWorkflowEvents(ctx context.Context, workflowType, workflowI
 }
 
 func (h *Handler) calculateStats(ctx context.Context) MonitorStats {
+	ctx = storage.WithReadReplica(ctx)
 	stats := MonitorStats{
 		WorkflowsByType:  make(map[string]int),
 		WorkflowsByState: make(map[string]int),
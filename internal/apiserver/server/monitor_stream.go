@@ -0,0 +1,262 @@
+// Package server 工作流监控 SSE 推送
+//
+// 本文件提供 GET /api/v1/monitor/stream：所有连接共享一个轮询协程（仅在有订阅者时运行），
+// 每个周期聚合一次工作流与统计，与上一周期比对后只推送变化部分，
+// 前端据此渲染实时看板，而不必每个页面各自每秒轮询数据库。
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// monitorStreamInterval 共享轮询周期（与 /ws/monitor 的广播周期一致）
+	monitorStreamInterval = 3 * time.Second
+	// monitorStreamKeepAlive 无变化时发送 SSE 注释保持连接的间隔
+	monitorStreamKeepAlive = 15 * time.Second
+)
+
+// WorkflowDelta 一个轮询周期内的工作流变化
+type WorkflowDelta struct {
+	Updated []WorkflowSummary `json:"updated,omitempty"` // 新增或状态变化的工作流
+	Removed []WorkflowRef     `json:"removed,omitempty"` // 已不存在的工作流
+}
+
+// WorkflowRef 工作流标识
+type WorkflowRef struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// monitorFeed 监控数据的共享轮询与变化计算
+type monitorFeed struct {
+	handler  *Handler
+	interval time.Duration
+
+	mu        sync.Mutex
+	subs      map[chan MonitorMessage]struct{}
+	workflows map[WorkflowRef]WorkflowSummary // 上一周期的工作流
+	stats     map[string]json.RawMessage      // 上一周期的统计（按字段）
+	cancel    context.CancelFunc              // 轮询协程，无订阅者时为 nil
+}
+
+func newMonitorFeed(h *Handler, interval time.Duration) *monitorFeed {
+	return &monitorFeed{
+		handler:  h,
+		interval: interval,
+		subs:     make(map[chan MonitorMessage]struct{}),
+	}
+}
+
+// subscribe 订阅变化，返回订阅时的全量快照；首个订阅者启动轮询协程
+func (f *monitorFeed) subscribe() (<-chan MonitorMessage, MonitorMessage, func()) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.cancel == nil {
+		workflows, stats := f.collect(context.Background())
+		f.workflows = indexWorkflows(workflows)
+		f.stats = statsFields(stats)
+		ctx, cancel := context.WithCancel(context.Background())
+		f.cancel = cancel
+		go f.run(ctx)
+	}
+
+	list := make([]WorkflowSummary, 0, len(f.workflows))
+	for _, wf := range f.workflows {
+		list = append(list, wf)
+	}
+	sortWorkflows(list)
+	snapshot := MonitorMessage{
+		Type:      "snapshot",
+		Data:      map[string]interface{}{"workflows": list, "stats": f.stats},
+		Timestamp: time.Now(),
+	}
+
+	ch := make(chan MonitorMessage, 16)
+	f.subs[ch] = struct{}{}
+	return ch, snapshot, func() {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		delete(f.subs, ch)
+		if len(f.subs) == 0 && f.cancel != nil {
+			f.cancel()
+			f.cancel = nil
+			f.workflows, f.stats = nil, nil
+		}
+	}
+}
+
+func (f *monitorFeed) run(ctx context.Context) {
+	ticker := time.NewTicker(f.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			f.poll(ctx)
+		}
+	}
+}
+
+// collect 聚合一次工作流与统计（与 ListWorkflows / GetMonitorStats 数据来源一致）
+func (f *monitorFeed) collect(ctx context.Context) ([]WorkflowSummary, MonitorStats) {
+	workflows := f.handler.getAuthWorkflows(ctx, "")
+	workflows = append(workflows, f.handler.getRunWorkflows(ctx, "")...)
+	return workflows, f.handler.calculateStats(ctx)
+}
+
+// poll 执行一个轮询周期，将变化推送给全部订阅者
+func (f *monitorFeed) poll(ctx context.Context) {
+	workflows, stats := f.collect(ctx)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if ctx.Err() != nil {
+		// 轮询期间订阅者已全部离开
+		return
+	}
+
+	now := time.Now()
+	current := indexWorkflows(workflows)
+	var delta WorkflowDelta
+	for ref, wf := range current {
+		prev, ok := f.workflows[ref]
+		if !ok || !reflect.DeepEqual(workflowVersion(prev), workflowVersion(wf)) || prev.Error != wf.Error {
+			delta.Updated = append(delta.Updated, wf)
+		}
+	}
+	for ref := range f.workflows {
+		if _, ok := current[ref]; !ok {
+			delta.Removed = append(delta.Removed, ref)
+		}
+	}
+	f.workflows = current
+	if len(delta.Updated) > 0 || len(delta.Removed) > 0 {
+		sortWorkflows(delta.Updated)
+		sort.Slice(delta.Removed, func(i, j int) bool {
+			if delta.Removed[i].Type != delta.Removed[j].Type {
+				return delta.Removed[i].Type < delta.Removed[j].Type
+			}
+			return delta.Removed[i].ID < delta.Removed[j].ID
+		})
+		f.publish(MonitorMessage{Type: "workflows", Data: delta, Timestamp: now})
+	}
+
+	fields := statsFields(stats)
+	changed := make(map[string]json.RawMessage)
+	for k, v := range fields {
+		if string(f.stats[k]) != string(v) {
+			changed[k] = v
+		}
+	}
+	f.stats = fields
+	if len(changed) > 0 {
+		f.publish(MonitorMessage{Type: "stats", Data: changed, Timestamp: now})
+	}
+}
+
+// publish 推送给全部订阅者（调用方持有 f.mu）；订阅者处理过慢时丢弃该消息
+func (f *monitorFeed) publish(msg MonitorMessage) {
+	for ch := range f.subs {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+// HandleStream 以 SSE 推送监控数据变化
+//
+// 路由: GET /api/v1/monitor/stream
+//
+// 事件（data 为 MonitorMessage）：
+//   - snapshot: 连接建立时推送，data.data = {"workflows": [...], "stats": {...}}
+//   - workflows: 工作流变化，data.data = {"updated": [...], "removed": [{"type", "id"}]}
+//   - stats: 统计变化，data.data 只包含变化的字段
+func (f *monitorFeed) HandleStream(w http.ResponseWriter, r *http.Request) {
+	rc := http.NewResponseController(w)
+	// 长连接不受服务端 WriteTimeout 限制
+	rc.SetWriteDeadline(time.Time{})
+
+	updates, snapshot, unsubscribe := f.subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(msg MonitorMessage) bool {
+		data, err := json.Marshal(msg)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "monitor.stream.marshal.failed", "type", msg.Type, "error", err)
+			return true
+		}
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", msg.Type, data); err != nil {
+			return false
+		}
+		return rc.Flush() == nil
+	}
+
+	if !send(snapshot) {
+		return
+	}
+
+	keepAlive := time.NewTicker(monitorStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg := <-updates:
+			if !send(msg) {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil || rc.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// indexWorkflows 按类型与 ID 索引工作流
+func indexWorkflows(workflows []WorkflowSummary) map[WorkflowRef]WorkflowSummary {
+	index := make(map[WorkflowRef]WorkflowSummary, len(workflows))
+	for _, wf := range workflows {
+		index[WorkflowRef{Type: wf.Type, ID: wf.ID}] = wf
+	}
+	return index
+}
+
+// statsFields 将统计按 JSON 字段拆分，便于逐字段比对
+func statsFields(stats MonitorStats) map[string]json.RawMessage {
+	data, _ := json.Marshal(stats)
+	fields := make(map[string]json.RawMessage)
+	json.Unmarshal(data, &fields)
+	return fields
+}
+
+// sortWorkflows 按更新时间倒序排列（与 ListWorkflows 一致）
+func sortWorkflows(workflows []WorkflowSummary) {
+	sort.SliceStable(workflows, func(i, j int) bool {
+		if workflows[i].UpdateTime == nil {
+			return false
+		}
+		if workflows[j].UpdateTime == nil {
+			return true
+		}
+		return workflows[i].UpdateTime.After(*workflows[j].UpdateTime)
+	})
+}
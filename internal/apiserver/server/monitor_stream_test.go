@@ -0,0 +1,136 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func newMonitorStreamStore() *mockMonitorStore {
+	now := time.Now()
+	return &mockMonitorStore{
+		Tasks: []*model.Task{{ID: "task-1"}},
+		Runs: map[string][]*model.Run{
+			"task-1": {
+				{ID: "run-1", TaskID: "task-1", Status: model.RunStatusRunning, CreatedAt: now, UpdatedAt: now},
+				{ID: "run-2", TaskID: "task-1", Status: model.RunStatusQueued, CreatedAt: now, UpdatedAt: now},
+			},
+		},
+		Events:    map[string][]*model.Event{},
+		AuthTasks: []*model.AuthTask{},
+	}
+}
+
+// TestMonitorFeed_Poll 验证轮询只推送变化部分
+func TestMonitorFeed_Poll(t *testing.T) {
+	store := newMonitorStreamStore()
+	feed := newMonitorFeed(newTestHandler(store), time.Hour)
+
+	updates, snapshot, unsubscribe := feed.subscribe()
+	data := snapshot.Data.(map[string]interface{})
+	if snapshot.Type != "snapshot" || len(data["workflows"].([]WorkflowSummary)) != 2 {
+		t.Fatalf("unexpected snapshot: %+v", snapshot)
+	}
+
+	// 无变化时不推送
+	feed.poll(context.Background())
+	if len(updates) != 0 {
+		t.Fatalf("无变化时不应推送, got %d", len(updates))
+	}
+
+	// run-1 完成、run-2 被删除
+	later := time.Now().Add(time.Second)
+	store.Runs["task-1"] = []*model.Run{
+		{ID: "run-1", TaskID: "task-1", Status: model.RunStatusDone, CreatedAt: later.Add(-time.Second), UpdatedAt: later},
+	}
+	feed.poll(context.Background())
+	if len(updates) != 2 {
+		t.Fatalf("期望 workflows 与 stats 两条消息, got %d", len(updates))
+	}
+
+	msg := <-updates
+	delta, ok := msg.Data.(WorkflowDelta)
+	if msg.Type != "workflows" || !ok {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if len(delta.Updated) != 1 || delta.Updated[0].ID != "run-1" || delta.Updated[0].State != "completed" {
+		t.Errorf("updated = %+v", delta.Updated)
+	}
+	if len(delta.Removed) != 1 || delta.Removed[0] != (WorkflowRef{Type: "run", ID: "run-2"}) {
+		t.Errorf("removed = %+v", delta.Removed)
+	}
+
+	msg = <-updates
+	changed, _ := msg.Data.(map[string]json.RawMessage)
+	if msg.Type != "stats" || string(changed["total_workflows"]) != "1" || string(changed["active_workflows"]) != "0" {
+		t.Errorf("stats delta = %+v", msg.Data)
+	}
+	if _, ok := changed["failed_today"]; ok {
+		t.Errorf("未变化的字段不应出现在 stats 增量中: %s", changed["failed_today"])
+	}
+
+	// 最后一个订阅者离开后停止轮询并清空缓存
+	unsubscribe()
+	if feed.cancel != nil || feed.workflows != nil {
+		t.Error("无订阅者时应停止轮询")
+	}
+}
+
+// TestMonitorFeed_HandleStream 验证 SSE 连接先收到快照，随后收到变化
+func TestMonitorFeed_HandleStream(t *testing.T) {
+	store := newMonitorStreamStore()
+	feed := newMonitorFeed(newTestHandler(store), time.Hour)
+	srv := httptest.NewServer(http.HandlerFunc(feed.HandleStream))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %s", ct)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	readEvent := func() (string, MonitorMessage) {
+		var event string
+		var msg MonitorMessage
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read stream: %v", err)
+			}
+			line = strings.TrimRight(line, "\n")
+			switch {
+			case line == "":
+				return event, msg
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: "):
+				json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &msg)
+			}
+		}
+	}
+
+	if event, msg := readEvent(); event != "snapshot" || msg.Type != "snapshot" {
+		t.Fatalf("首个事件 = %s %+v", event, msg)
+	}
+
+	store.Runs["task-1"] = store.Runs["task-1"][:1]
+	feed.poll(context.Background())
+	event, msg := readEvent()
+	data, _ := json.Marshal(msg.Data)
+	var delta WorkflowDelta
+	json.Unmarshal(data, &delta)
+	if event != "workflows" || len(delta.Updated) != 0 || len(delta.Removed) != 1 || delta.Removed[0].ID != "run-2" {
+		t.Errorf("变化事件 = %s %s", event, data)
+	}
+}
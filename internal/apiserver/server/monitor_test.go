@@ -62,7 +62,7 @@ import (
 //   - Tasks: ListTasks 返回的任务列表
 //   - Runs: 按 TaskID 索引的 Run 列表（ListRunsByTask 使用）
 //   - RunByID: 按 RunID 索引的 Run（GetRun 使用）
-//   - Events: 按 RunID 索引的事件列表（GetEventsByRun 使用）
+//   - Events: 按 RunID 索引的事件列表（GetEventsByRun、CountEventsByRun 使用）
 //   - AuthTasks: ListRecentAuthTasks 返回的认证任务列表
 type mockMonitorStore struct {
 	storage.PersistentStore // 嵌入接口，未实现的方法会 panic（测试中不应调用）
@@ -104,6 +104,18 @@ func (m *mockMonitorStore) GetEventsByRun(_ context.Context, runID string, fromS
 	return filtered, nil
 }
 
+func (m *mockMonitorStore) CountEventsByRun(_ context.Context, runID string) (int, error) {
+	return len(m.Events[runID]), nil
+}
+
+func (m *mockMonitorStore) MaxEventSeqs(_ context.Context, runID string) (int, int, error) {
+	var seq, nodeSeq int
+	for _, e := range m.Events[runID] {
+		seq, nodeSeq = max(seq, e.Seq), max(nodeSeq, e.NodeSeq)
+	}
+	return seq, nodeSeq, nil
+}
+
 func (m *mockMonitorStore) ListRecentAuthTasks(_ context.Context, _ int) ([]*model.AuthTask, error) {
 	return m.AuthTasks, nil
 }
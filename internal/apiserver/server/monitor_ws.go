@@ -7,6 +7,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"log/slog"
 	"net/http"
 	"sync"
 	"time"
@@ -53,7 +54,7 @@ func NewMonitorWSHandler(h *Handler) *MonitorWSHandler {
 func (m *MonitorWSHandler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	conn, err := monitorUpgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("[MonitorWS] Upgrade error: %v", err)
+		slog.WarnContext(r.Context(), "monitor.ws.upgrade.failed", "error", err)
 		return
 	}
 
@@ -61,7 +62,7 @@ func (m *MonitorWSHandler) HandleWebSocket(w http.ResponseWriter, r *http.Reques
 	m.clients[conn] = true
 	m.mu.Unlock()
 
-	log.Printf("[MonitorWS] Client connected, total: %d", len(m.clients))
+	slog.InfoContext(r.Context(), "monitor.ws.connected", "clients", len(m.clients))
 
 	// 发送初始数据
 	m.sendInitialData(conn)
@@ -188,7 +189,7 @@ func (m *MonitorWSHandler) broadcastLoop() {
 		for conn := range m.clients {
 			conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
 			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
-				log.Printf("[MonitorWS] Ping error: %v", err)
+				slog.DebugContext(ctx, "monitor.ws.ping.failed", "error", err)
 			}
 		}
 		m.mu.RUnlock()
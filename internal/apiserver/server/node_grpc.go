@@ -0,0 +1,419 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/apiserver/audit"
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/apiserver/run"
+	"agents-admin/internal/shared/model"
+)
+
+// nodeTokenMetadataKey gRPC 请求中携带节点 Token 的 metadata 键（对应 REST 的 X-Node-Token）
+const nodeTokenMetadataKey = "x-node-token"
+
+// defaultWatchInterval WatchAssignedRuns 检查新分配 Run 的默认间隔
+const defaultWatchInterval = time.Second
+
+// NodeGRPCServer 创建节点通信 gRPC 服务（与 REST 节点接口共用处理逻辑与认证配置）
+//
+// 参数：
+//   - tlsCfg: TLS 配置，为 nil 时使用明文连接
+//
+// 返回的服务器由调用方负责 Serve 与 GracefulStop。
+func (h *Handler) NodeGRPCServer(tlsCfg *tls.Config) *grpc.Server {
+	svc := &nodeService{h: h, auth: h.nodeAuthConfig(), auditor: audit.NewRecorder(h.store), watchInterval: defaultWatchInterval}
+	opts := []grpc.ServerOption{
+		grpc.UnaryInterceptor(svc.unaryAuth),
+		grpc.StreamInterceptor(svc.streamAuth),
+	}
+	if tlsCfg != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsCfg)))
+	}
+	srv := grpc.NewServer(opts...)
+	nodev1.RegisterNodeServiceServer(srv, svc)
+	return srv
+}
+
+// nodeService 节点通信 gRPC 服务实现
+type nodeService struct {
+	nodev1.UnimplementedNodeServiceServer
+	h             *Handler
+	auth          auth.Config
+	auditor       *audit.Recorder
+	watchInterval time.Duration
+}
+
+// ============================================================================
+// 认证拦截器
+// ============================================================================
+
+// authenticate 认证节点并注入节点身份（与 REST 认证中间件规则一致）
+//
+// Heartbeat 公开：凭证无效或缺失时匿名处理，认证通过时同样注入节点身份，供 authorizeNode 校验 node_id。
+func (s *nodeService) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	if !s.auth.Enabled() {
+		return ctx, nil
+	}
+	var token string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(nodeTokenMetadataKey); len(values) > 0 {
+			token = values[0]
+		}
+	}
+	var state *tls.ConnectionState
+	if p, ok := peer.FromContext(ctx); ok {
+		if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+			state = &info.State
+		}
+	}
+	nodeID, ok := auth.AuthenticateNode(ctx, s.auth, token, state)
+	if !ok {
+		if fullMethod == nodev1.NodeService_Heartbeat_FullMethodName {
+			return ctx, nil
+		}
+		return nil, status.Error(codes.Unauthenticated, "invalid node credentials")
+	}
+	return auth.WithNodeIdentity(ctx, nodeID), nil
+}
+
+func (s *nodeService) unaryAuth(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx, info.FullMethod)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *nodeService) streamAuth(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(ss.Context(), info.FullMethod)
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authedStream{ServerStream: ss, ctx: ctx})
+}
+
+// authedStream 替换流的 context 以携带节点身份
+type authedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authedStream) Context() context.Context { return s.ctx }
+
+// authorizeNode 节点凭证只能访问分配给该节点的 Run 与以自己为对象的调用（规则同 REST 的 nodeRunScope）：
+// 共享密钥与越权调用写入审计日志并返回 PermissionDenied，Run 不存在时返回 NotFound
+func (s *nodeService) authorizeNode(ctx context.Context, fullMethod, resource, id string) error {
+	node := auth.GetNodeIdentity(ctx)
+	if node == nil {
+		return nil
+	}
+	access, err := s.h.checkNodeAccess(ctx, node.ID, resource, id)
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	switch access {
+	case nodeAccessAllowed:
+		return nil
+	case nodeAccessNotFound:
+		return status.Error(codes.NotFound, errNodeRunNotFound)
+	}
+	slog.WarnContext(ctx, "node.scope.denied", "node_id", node.ID, "method", fullMethod, "resource", resource+"/"+id)
+	entry := &model.AuditLog{Method: "GRPC", Route: fullMethod, Path: fullMethod, ResourceType: resource, ResourceID: id}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		entry.SourceIP = auth.RemoteHost(p.Addr.String())
+	}
+	s.auditor.RecordNodeViolation(ctx, node.ID, entry)
+	return status.Error(codes.PermissionDenied, nodeForbiddenMessage(access, resource))
+}
+
+// ============================================================================
+// NodeService 接口实现
+// ============================================================================
+
+// Heartbeat 处理节点心跳（同 POST /api/v1/nodes/heartbeat）
+func (s *nodeService) Heartbeat(ctx context.Context, req *nodev1.HeartbeatRequest) (*nodev1.HeartbeatResponse, error) {
+	// 心跳对共享密钥与匿名调用公开，专属凭证只能为自己上报
+	if node := auth.GetNodeIdentity(ctx); node != nil && node.ID != "" {
+		if err := s.authorizeNode(ctx, nodev1.NodeService_Heartbeat_FullMethodName, "nodes", req.NodeId); err != nil {
+			return nil, err
+		}
+	}
+	resp, err := s.h.nodes.ProcessHeartbeat(ctx, heartbeatFromProto(req))
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return heartbeatToProto(resp), nil
+}
+
+// WatchAssignedRuns 推送分配给节点的待领取 Run（同 GET /api/v1/nodes/{id}/runs 中 assigned 状态的 Run）
+//
+// 每个 Run 只推送一次；Run 离开 assigned 状态后再次被分配（如孤儿回收后重新调度）时重新推送。
+func (s *nodeService) WatchAssignedRuns(req *nodev1.WatchAssignedRunsRequest, stream grpc.ServerStreamingServer[nodev1.AssignedRun]) error {
+	if req.NodeId == "" {
+		return status.Error(codes.InvalidArgument, node.ErrNodeIDRequired.Error())
+	}
+	ctx := stream.Context()
+	if err := s.authorizeNode(ctx, nodev1.NodeService_WatchAssignedRuns_FullMethodName, "nodes", req.NodeId); err != nil {
+		return err
+	}
+	ticker := time.NewTicker(s.watchInterval)
+	defer ticker.Stop()
+
+	sent := make(map[string]bool)
+	for {
+		runs, err := s.h.nodes.AssignedRuns(ctx, req.NodeId)
+		if err != nil {
+			slog.ErrorContext(ctx, "node.grpc.watch.error", "node_id", req.NodeId, "error", err)
+		} else {
+			current := make(map[string]bool, len(runs))
+			for _, r := range runs {
+				if r.Status != model.RunStatusAssigned {
+					continue
+				}
+				current[r.ID] = true
+				if sent[r.ID] {
+					continue
+				}
+				data, err := json.Marshal(r)
+				if err != nil {
+					slog.ErrorContext(ctx, "node.grpc.watch.error", "node_id", req.NodeId, "run_id", r.ID, "error", err)
+					continue
+				}
+				if err := stream.Send(&nodev1.AssignedRun{
+					RunId:    r.ID,
+					Status:   string(r.Status),
+					EventSeq: int32(r.EventSeq),
+					Run:      data,
+				}); err != nil {
+					return err
+				}
+				sent[r.ID] = true
+			}
+			for id := range sent {
+				if !current[id] {
+					delete(sent, id)
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// ReportEvents 流式上报事件（每条消息同 POST /api/v1/runs/{id}/events 的一次请求），返回汇总结果
+func (s *nodeService) ReportEvents(stream grpc.ClientStreamingServer[nodev1.ReportEventsRequest, nodev1.ReportEventsResponse]) error {
+	ctx := stream.Context()
+	resp := &nodev1.ReportEventsResponse{}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return stream.SendAndClose(resp)
+		}
+		if err != nil {
+			return err
+		}
+		if err := s.authorizeNode(ctx, nodev1.NodeService_ReportEvents_FullMethodName, "runs", req.RunId); err != nil {
+			return err
+		}
+		inputs, err := eventInputsFromProto(req.Events)
+		if err != nil {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		result, err := s.h.IngestEvents(ctx, req.RunId, inputs)
+		if err != nil {
+			return grpcError(err)
+		}
+		resp.Created += int32(result.Created)
+		for _, seq := range result.Stored {
+			resp.Stored = append(resp.Stored, int32(seq))
+		}
+		for _, seq := range result.Duplicates {
+			resp.Duplicates = append(resp.Duplicates, int32(seq))
+		}
+		for _, rej := range result.Rejected {
+			resp.Rejected = append(resp.Rejected, &nodev1.RejectedEvent{Seq: int32(rej.Seq), Error: rej.Error})
+		}
+	}
+}
+
+// UpdateRunStatus 上报 Run 状态（同 PATCH /api/v1/runs/{id}）
+func (s *nodeService) UpdateRunStatus(ctx context.Context, req *nodev1.UpdateRunStatusRequest) (*nodev1.UpdateRunStatusResponse, error) {
+	if req.Status == "" {
+		return nil, status.Error(codes.InvalidArgument, "status is required")
+	}
+	if err := s.authorizeNode(ctx, nodev1.NodeService_UpdateRunStatus_FullMethodName, "runs", req.RunId); err != nil {
+		return nil, err
+	}
+	var nodeID *string
+	if req.NodeId != "" {
+		nodeID = &req.NodeId
+	}
+	st, err := s.h.runs.UpdateStatus(ctx, req.RunId, model.RunStatus(req.Status), nodeID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &nodev1.UpdateRunStatusResponse{Status: string(st)}, nil
+}
+
+// ============================================================================
+// 类型转换
+// ============================================================================
+
+// grpcError 将处理逻辑的错误映射为 gRPC 状态码（与 REST 状态码对应）
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, node.ErrNodeIDRequired), errors.Is(err, ErrInvalidEvents):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, ErrEventBatchTooLarge):
+		return status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, run.ErrRunReassigned):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	default:
+		return status.Error(codes.Internal, err.Error())
+	}
+}
+
+func heartbeatFromProto(req *nodev1.HeartbeatRequest) *node.HeartbeatRequestExt {
+	ext := &node.HeartbeatRequestExt{
+		// 声明式状态协调以 running_runs 是否上报区分新旧节点，gRPC 客户端总是上报
+		RunningRuns: append([]string{}, req.RunningRuns...),
+		PausedRuns:  req.PausedRuns,
+		Approvals:   req.PendingApprovals,
+		Hostname:    req.Hostname,
+		IPs:         req.Ips,
+	}
+	ext.NodeId = req.NodeId
+	if req.Status != "" {
+		ext.Status = &req.Status
+	}
+	if req.Labels != nil {
+		ext.Labels = &req.Labels
+	}
+	if c := req.Capacity; c != nil {
+		capacity := map[string]interface{}{
+			"max_concurrent": c.MaxConcurrent,
+			"available":      c.Available,
+			"cpus":           c.Cpus,
+			"memory_bytes":   c.MemoryBytes,
+		}
+		if c.ReservedHighPriority > 0 {
+			capacity["reserved_high_priority"] = c.ReservedHighPriority
+		}
+		if len(c.AgentTypeLimits) > 0 {
+			capacity["agent_type_limits"] = c.AgentTypeLimits
+		}
+		if len(c.Adapters) > 0 {
+			adapters := make([]model.AdapterInfo, 0, len(c.Adapters))
+			for _, a := range c.Adapters {
+				adapters = append(adapters, model.AdapterInfo{Name: a.Name, Version: a.Version, Features: a.Features})
+			}
+			capacity["adapters"] = adapters
+		}
+		if len(c.Pools) > 0 {
+			pools := make([]model.InstancePoolStatus, 0, len(c.Pools))
+			for _, p := range c.Pools {
+				pools = append(pools, model.InstancePoolStatus{
+					AccountID: p.AccountId, AgentType: p.AgentType,
+					Size: int(p.Size), Idle: int(p.Idle), Leased: int(p.Leased), Warming: int(p.Warming),
+					Leases: p.Leases, Misses: p.Misses, Recycled: p.Recycled, Discarded: p.Discarded,
+					AvgWarmMS: p.AvgWarmMs, LastError: p.LastError,
+				})
+			}
+			capacity["pools"] = pools
+		}
+		if m := c.Metrics; m != nil {
+			capacity["metrics"] = &model.NodeMetrics{
+				CPUPercent: m.CpuPercent, MemoryUsedBytes: m.MemoryUsedBytes, MemoryTotalBytes: m.MemoryTotalBytes,
+				MemoryPercent: m.MemoryPercent, DiskUsedBytes: m.DiskUsedBytes, DiskTotalBytes: m.DiskTotalBytes,
+				DiskPercent: m.DiskPercent, Load1: m.Load1, Load5: m.Load5, Load15: m.Load15,
+				CPUs: int(m.Cpus), CollectedAt: time.UnixMilli(m.CollectedAtMs),
+			}
+		}
+		if sp := c.Spool; sp != nil {
+			capacity["spool"] = &model.NodeSpoolStatus{
+				Runs: int(sp.Runs), Records: int(sp.Records), Segments: int(sp.Segments),
+				Bytes: sp.Bytes, MaxBytes: sp.MaxBytes, Dropped: sp.Dropped,
+			}
+		}
+		ext.Capacity = &capacity
+	}
+	return ext
+}
+
+func heartbeatToProto(resp *node.HeartbeatResponse) *nodev1.HeartbeatResponse {
+	out := &nodev1.HeartbeatResponse{Status: resp.Status}
+	if o := resp.CapacityOverride; o != nil {
+		out.CapacityOverride = &nodev1.CapacityOverride{}
+		if o.MaxConcurrent != nil {
+			v := int32(*o.MaxConcurrent)
+			out.CapacityOverride.MaxConcurrent = &v
+		}
+		if o.ReservedHighPriority != nil {
+			v := int32(*o.ReservedHighPriority)
+			out.CapacityOverride.ReservedHighPriority = &v
+		}
+		if len(o.AgentTypeLimits) > 0 {
+			out.CapacityOverride.AgentTypeLimits = make(map[string]int32, len(o.AgentTypeLimits))
+			for k, v := range o.AgentTypeLimits {
+				out.CapacityOverride.AgentTypeLimits[k] = int32(v)
+			}
+		}
+	}
+	d := resp.Directives
+	if d == nil {
+		return out
+	}
+	out.Directives = &nodev1.Directives{
+		CancelRuns:  d.CancelRuns,
+		TimeoutRuns: d.TimeoutRuns,
+		PauseRuns:   d.PauseRuns,
+		ResumeRuns:  d.ResumeRuns,
+	}
+	for _, a := range d.Approvals {
+		out.Directives.Approvals = append(out.Directives.Approvals, &nodev1.ApprovalOutcome{ApprovalId: a.ApprovalID, Status: string(a.Status)})
+	}
+	for _, f := range d.Feedbacks {
+		out.Directives.Feedbacks = append(out.Directives.Feedbacks, &nodev1.Feedback{Id: f.ID, RunId: f.RunID, Type: string(f.Type), Content: f.Content})
+	}
+	return out
+}
+
+func eventInputsFromProto(events []*nodev1.Event) ([]EventInput, error) {
+	inputs := make([]EventInput, 0, len(events))
+	for _, e := range events {
+		input := EventInput{Seq: int(e.Seq), Type: e.Type, Raw: e.Raw}
+		if e.Timestamp != nil {
+			input.Timestamp = e.Timestamp.AsTime()
+		}
+		if len(e.Payload) > 0 {
+			var payload map[string]interface{}
+			if err := json.Unmarshal(e.Payload, &payload); err != nil {
+				return nil, fmt.Errorf("seq=%d: invalid payload: %w", e.Seq, err)
+			}
+			input.Payload = &payload
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/apiserver/run"
+	"agents-admin/internal/shared/model"
+)
+
+// nodeGRPCStore 在 moderationStore 基础上按节点列出 Run，并接受 "token-{节点 ID}" 形式的节点专属 Token
+type nodeGRPCStore struct {
+	*moderationStore
+}
+
+func (m *nodeGRPCStore) GetNodeCredentialByTokenHash(_ context.Context, tokenHash string) (*model.NodeCredential, error) {
+	for _, nodeID := range []string{"node-1", "node-2"} {
+		sum := sha256.Sum256([]byte("token-" + nodeID))
+		if hex.EncodeToString(sum[:]) == tokenHash {
+			return &model.NodeCredential{NodeID: nodeID}, nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *nodeGRPCStore) ListRunsByNode(_ context.Context, nodeID string) ([]*model.Run, error) {
+	var runs []*model.Run
+	for _, r := range m.RunByID {
+		if r.NodeID != nil && *r.NodeID == nodeID {
+			runs = append(runs, r)
+		}
+	}
+	return runs, nil
+}
+
+// startNodeGRPC 以内存连接启动节点 gRPC 服务，返回客户端
+func startNodeGRPC(t *testing.T, h *Handler) nodev1.NodeServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := h.NodeGRPCServer(nil)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return nodev1.NewNodeServiceClient(conn)
+}
+
+func newNodeGRPCHandler(runs map[string]*model.Run) (*Handler, *nodeGRPCStore) {
+	store := &nodeGRPCStore{moderationStore: &moderationStore{mockMonitorStore: &mockMonitorStore{
+		RunByID: runs,
+		Events:  map[string][]*model.Event{},
+	}}}
+	h := newTestHandler(store)
+	h.runs = run.NewHandler(store, nil)
+	h.nodes = node.NewHandler(store)
+	h.eventGateway = NewEventGateway(store, nil)
+	h.authConfig = AuthConfigCompat{JWTSecret: "test-secret", NodeToken: "node-secret"}
+	return h, store
+}
+
+// TestNodeGRPC_UpdateRunStatus 认证拦截与状态上报（与 PATCH /api/v1/runs/{id} 规则一致）
+func TestNodeGRPC_UpdateRunStatus(t *testing.T) {
+	nodeID := "node-1"
+	h, store := newNodeGRPCHandler(map[string]*model.Run{
+		"run-1": {ID: "run-1", TaskID: "task-1", NodeID: &nodeID, Status: model.RunStatusRunning},
+	})
+	client := startNodeGRPC(t, h)
+
+	req := &nodev1.UpdateRunStatusRequest{RunId: "run-1", Status: "done", NodeId: "node-2"}
+	if _, err := client.UpdateRunStatus(context.Background(), req); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("缺少节点 Token 时 err = %v, 期望 Unauthenticated", err)
+	}
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "x-node-token", "token-node-1")
+	if _, err := client.UpdateRunStatus(ctx, req); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("已重新分配的 Run err = %v, 期望 FailedPrecondition", err)
+	}
+
+	req.NodeId = nodeID
+	resp, err := client.UpdateRunStatus(ctx, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Status != "done" || store.RunByID["run-1"].Status != model.RunStatusDone {
+		t.Errorf("status = %s, stored = %s", resp.Status, store.RunByID["run-1"].Status)
+	}
+	if store.taskStatus != model.TaskStatusCompleted {
+		t.Errorf("task status = %s, 期望 completed", store.taskStatus)
+	}
+}
+
+// TestNodeGRPC_ReportEvents 客户端流逐条入库，汇总返回结果并忽略重复的 seq
+func TestNodeGRPC_ReportEvents(t *testing.T) {
+	h, store := newNodeGRPCHandler(map[string]*model.Run{
+		"run-1": {ID: "run-1", TaskID: "task-1", Status: model.RunStatusRunning},
+	})
+	h.authConfig = AuthConfigCompat{}
+	client := startNodeGRPC(t, h)
+
+	event := func(seq int32, eventType string) *nodev1.Event {
+		return &nodev1.Event{Seq: seq, Type: eventType, Timestamp: timestamppb.Now(), Payload: []byte(`{"content":"hi"}`)}
+	}
+	stream, err := client.ReportEvents(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, req := range []*nodev1.ReportEventsRequest{
+		{RunId: "run-1", Events: []*nodev1.Event{event(2, "message"), event(3, "message")}},
+		{RunId: "run-1", Events: []*nodev1.Event{event(3, "message"), event(4, "message"), event(5, "")}},
+	} {
+		if err := stream.Send(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Created != 3 || len(resp.Stored) != 3 || len(resp.Duplicates) != 1 || resp.Duplicates[0] != 3 {
+		t.Errorf("resp = %+v", resp)
+	}
+	if len(resp.Rejected) != 1 || resp.Rejected[0].Seq != 5 {
+		t.Errorf("rejected = %+v", resp.Rejected)
+	}
+	if got := store.Events["run-1"]; len(got) != 3 || string(got[0].Payload) != `{"content":"hi"}` {
+		t.Errorf("stored events = %+v", got)
+	}
+
+	// 整批无效：返回 InvalidArgument，Node Manager 不再重试
+	stream, _ = client.ReportEvents(context.Background())
+	stream.Send(&nodev1.ReportEventsRequest{RunId: "run-1", Events: []*nodev1.Event{event(0, "message")}})
+	if _, err := stream.CloseAndRecv(); status.Code(err) != codes.InvalidArgument {
+		t.Errorf("err = %v, 期望 InvalidArgument", err)
+	}
+}
+
+// TestNodeGRPC_WatchAssignedRuns 推送分配给节点的 assigned Run，格式与 REST 轮询相同
+func TestNodeGRPC_WatchAssignedRuns(t *testing.T) {
+	nodeID := "node-1"
+	h, _ := newNodeGRPCHandler(map[string]*model.Run{
+		"run-1": {ID: "run-1", NodeID: &nodeID, Status: model.RunStatusAssigned, Snapshot: json.RawMessage(`{"prompt":"hello"}`)},
+		"run-2": {ID: "run-2", NodeID: &nodeID, Status: model.RunStatusRunning},
+	})
+	client := startNodeGRPC(t, h)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	ctx = metadata.AppendToOutgoingContext(ctx, "x-node-token", "token-node-1")
+	stream, err := client.WatchAssignedRuns(ctx, &nodev1.WatchAssignedRunsRequest{NodeId: nodeID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.RunId != "run-1" || msg.Status != "assigned" {
+		t.Fatalf("msg = %+v", msg)
+	}
+	var runJSON map[string]interface{}
+	if err := json.Unmarshal(msg.Run, &runJSON); err != nil {
+		t.Fatal(err)
+	}
+	if runJSON["id"] != "run-1" || runJSON["snapshot"].(map[string]interface{})["prompt"] != "hello" {
+		t.Errorf("run = %s", msg.Run)
+	}
+}
+
+// TestHeartbeatFromProto 总是上报 running_runs（声明式状态协调），容量与 REST 字段同名
+func TestHeartbeatFromProto(t *testing.T) {
+	req := heartbeatFromProto(&nodev1.HeartbeatRequest{
+		NodeId:   "node-1",
+		Capacity: &nodev1.Capacity{MaxConcurrent: 2, Available: 1},
+	})
+	if req.RunningRuns == nil || len(req.RunningRuns) != 0 {
+		t.Errorf("RunningRuns = %#v, 期望空列表", req.RunningRuns)
+	}
+	if req.Status != nil || req.Capacity == nil || (*req.Capacity)["max_concurrent"] != int32(2) {
+		t.Errorf("req = %+v", req)
+	}
+	if _, ok := (*req.Capacity)["adapters"]; ok {
+		t.Errorf("未上报适配器时不应写入 adapters: %+v", *req.Capacity)
+	}
+
+	req = heartbeatFromProto(&nodev1.HeartbeatRequest{
+		NodeId: "node-1",
+		Capacity: &nodev1.Capacity{MaxConcurrent: 2, Adapters: []*nodev1.AdapterInfo{
+			{Name: "claude-v1", Version: "1.0.33", Features: []string{"streaming", "usage"}},
+		}},
+	})
+	adapters, _ := (*req.Capacity)["adapters"].([]model.AdapterInfo)
+	if len(adapters) != 1 || adapters[0].Name != "claude-v1" || adapters[0].Version != "1.0.33" || !adapters[0].HasFeature("usage") {
+		t.Errorf("adapters = %+v", (*req.Capacity)["adapters"])
+	}
+}
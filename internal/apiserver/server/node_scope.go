@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"agents-admin/internal/apiserver/audit"
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// 节点越权访问时返回的错误信息
+const (
+	errNodeRunForbidden    = "run is not assigned to this node"       // Run 未分配给该节点
+	errNodeForbidden       = "node credential does not match node id" // 访问其他节点的路由
+	errNodeSharedForbidden = "per-node credential required"           // 共享密钥访问受限资源
+	errNodeRunNotFound     = "run not found"
+)
+
+// heartbeatPath 节点心跳路由（公开；携带专属凭证时只能为自己上报）
+const heartbeatPath = "/api/v1/nodes/heartbeat"
+
+// nodeCollectionPaths /api/v1/nodes/ 下不是节点 ID 的路径段（节点集合上的路由，不按节点范围限制）
+var nodeCollectionPaths = map[string]bool{
+	"heartbeat":   true,
+	"join":        true,
+	"join-tokens": true,
+	"occupancy":   true,
+}
+
+// nodeResolvePaths 节点解析密钥与凭据明文的路由：须使用专属凭证，名称由处理器按分配给节点的 Run 校验
+var nodeResolvePaths = map[string]string{
+	"/api/v1/secrets/resolve":     "secrets",
+	"/api/v1/credentials/resolve": "credentials",
+}
+
+// nodeAccess 节点凭证访问受限资源的判定结果
+type nodeAccess int
+
+const (
+	nodeAccessAllowed  nodeAccess = iota
+	nodeAccessShared              // 共享密钥没有节点身份，不能访问受限资源
+	nodeAccessDenied              // 资源不属于该节点
+	nodeAccessNotFound            // Run 不存在
+)
+
+// nodeRunScope 限制节点凭证只能访问分配给该节点的 Run
+//
+// 作用于 /api/v1/runs/{id} 及其子路由（Run 详情、状态回写、事件与节点日志上报等）、/api/v1/nodes/{id} 的全部子路由
+// （Run 列表、期望状态与实际状态、代理与代理健康上报、节点动作等）与密钥、凭据的解析接口：
+// 只接受节点专属凭证（加入令牌换取的 Token 或节点客户端证书），共享密钥（NODE_TOKEN）没有节点身份，返回 403；
+// Run 当前未分配给该节点、或访问其他节点的路由时返回 403，Run 不存在时返回 404。
+// 心跳对共享密钥与匿名请求公开，以专属凭证认证时只能为自己上报（同 gRPC Heartbeat）。
+// 拒绝的请求以节点为操作者写入审计日志。用户请求与无认证模式的请求不做限制。
+func (h *Handler) nodeRunScope(mux *http.ServeMux, auditor *audit.Recorder) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		node := auth.GetNodeIdentity(r.Context())
+		if node == nil {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		resource, id := nodeScopedResource(r.URL.Path)
+		if node.ID != "" && r.Method == http.MethodPost && r.URL.Path == heartbeatPath {
+			resource, id = "nodes", heartbeatNodeID(r)
+		}
+		if resource == "" {
+			mux.ServeHTTP(w, r)
+			return
+		}
+		access, err := h.checkNodeAccess(r.Context(), node.ID, resource, id)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "node.scope.lookup_failed", "node_id", node.ID, "resource", resource+"/"+id, "error", err)
+			writeError(w, http.StatusInternalServerError, "failed to get run")
+			return
+		}
+		switch access {
+		case nodeAccessAllowed:
+			mux.ServeHTTP(w, r)
+			return
+		case nodeAccessNotFound:
+			writeError(w, http.StatusNotFound, errNodeRunNotFound)
+			return
+		}
+		slog.WarnContext(r.Context(), "node.scope.denied", "node_id", node.ID, "method", r.Method, "path", r.URL.Path)
+		_, pattern := mux.Handler(r)
+		auditor.RecordNodeViolation(r.Context(), node.ID, &model.AuditLog{
+			Method:       r.Method,
+			Route:        pattern,
+			Path:         r.URL.Path,
+			ResourceType: resource,
+			ResourceID:   id,
+			SourceIP:     auth.RemoteHost(r.RemoteAddr),
+		})
+		writeError(w, http.StatusForbidden, nodeForbiddenMessage(access, resource))
+	})
+}
+
+// nodeScopedResource 解析受节点范围限制的资源：/api/v1/runs/{id}/... 返回 ("runs", id)，
+// /api/v1/nodes/{id}/...（任意方法）返回 ("nodes", id)，密钥与凭据的解析接口返回 ("secrets" / "credentials", "")，
+// 其他路径（含 /api/v1/nodes/{id} 本身与节点集合上的路由）返回空
+func nodeScopedResource(path string) (resource, id string) {
+	if resource, ok := nodeResolvePaths[path]; ok {
+		return resource, ""
+	}
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "api" || parts[1] != "v1" || parts[3] == "" {
+		return "", ""
+	}
+	switch {
+	case parts[2] == "runs":
+		return "runs", parts[3]
+	case parts[2] == "nodes" && len(parts) >= 5 && !nodeCollectionPaths[parts[3]]:
+		return "nodes", parts[3]
+	}
+	return "", ""
+}
+
+// heartbeatNodeID 读取心跳请求体中的 node_id（请求体读取后还原，供处理器再次解析）
+func heartbeatNodeID(r *http.Request) string {
+	if r.Body == nil {
+		return ""
+	}
+	body, err := io.ReadAll(r.Body)
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	var req struct {
+		NodeID string `json:"node_id"`
+	}
+	json.Unmarshal(body, &req)
+	return req.NodeID
+}
+
+// checkNodeAccess 判断节点凭证能否访问资源（REST 与 gRPC 共用）
+//
+// 共享密钥（nodeID 为空）不能访问任何受限资源；节点路由须为该节点自己的，Run 须分配给该节点；
+// 密钥与凭据的解析接口只要求专属凭证，名称由处理器校验。
+func (h *Handler) checkNodeAccess(ctx context.Context, nodeID, resource, id string) (nodeAccess, error) {
+	if nodeID == "" {
+		return nodeAccessShared, nil
+	}
+	switch resource {
+	case "nodes":
+		// node_id 缺失的心跳交给处理器返回 400
+		if id != "" && id != nodeID {
+			return nodeAccessDenied, nil
+		}
+	case "runs":
+		run, err := h.store.GetRun(ctx, id)
+		if err != nil {
+			return nodeAccessDenied, err
+		}
+		if run == nil {
+			return nodeAccessNotFound, nil
+		}
+		if !runAssignedTo(run, nodeID) {
+			return nodeAccessDenied, nil
+		}
+	}
+	return nodeAccessAllowed, nil
+}
+
+// nodeForbiddenMessage 拒绝访问时返回的错误信息
+func nodeForbiddenMessage(access nodeAccess, resource string) string {
+	switch {
+	case access == nodeAccessShared:
+		return errNodeSharedForbidden
+	case resource == "runs":
+		return errNodeRunForbidden
+	}
+	return errNodeForbidden
+}
+
+// runAssignedTo 判断 Run 当前是否分配给节点
+func runAssignedTo(run *model.Run, nodeID string) bool {
+	return run.NodeID != nil && *run.NodeID == nodeID
+}
@@ -0,0 +1,185 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/apiserver/audit"
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// auditedNodeStore 在 nodeGRPCStore 基础上记录审计日志
+type auditedNodeStore struct {
+	*nodeGRPCStore
+	audits []*model.AuditLog
+}
+
+func (m *auditedNodeStore) CreateAuditLog(_ context.Context, e *model.AuditLog) error {
+	m.audits = append(m.audits, e)
+	return nil
+}
+
+func newNodeScopeHandler() (*Handler, *auditedNodeStore) {
+	nodeID := "node-1"
+	h, base := newNodeGRPCHandler(map[string]*model.Run{
+		"run-1": {ID: "run-1", TaskID: "task-1", NodeID: &nodeID, Status: model.RunStatusRunning},
+		"run-2": {ID: "run-2", TaskID: "task-2", Status: model.RunStatusQueued},
+	})
+	store := &auditedNodeStore{nodeGRPCStore: base}
+	h.store = store
+	return h, store
+}
+
+// TestNodeRunScope 节点专属凭证只能访问分配给该节点的 Run，共享密钥与越权请求返回 403 并写入审计日志，Run 不存在返回 404
+func TestNodeRunScope(t *testing.T) {
+	h, store := newNodeScopeHandler()
+	mux := http.NewServeMux()
+	ok := func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusOK) }
+	mux.HandleFunc("GET /api/v1/runs/{id}", ok)
+	mux.HandleFunc("PATCH /api/v1/runs/{id}", ok)
+	mux.HandleFunc("POST /api/v1/runs/{id}/events", ok)
+	mux.HandleFunc("GET /api/v1/nodes/{id}/runs", ok)
+	mux.HandleFunc("GET /api/v1/nodes/{id}/desired-state", ok)
+	mux.HandleFunc("POST /api/v1/nodes/{id}/observed-state", ok)
+	mux.HandleFunc("GET /api/v1/nodes/{id}/proxies", ok)
+	mux.HandleFunc("POST /api/v1/nodes/{id}/proxy-health", ok)
+	mux.HandleFunc("GET /api/v1/nodes/{id}/actions", ok)
+	mux.HandleFunc("GET /api/v1/nodes/occupancy/stream", ok)
+	mux.HandleFunc("POST /api/v1/nodes/heartbeat", ok)
+	mux.HandleFunc("POST /api/v1/secrets/resolve", ok)
+	mux.HandleFunc("POST /api/v1/credentials/resolve", ok)
+	mux.HandleFunc("GET /api/v1/tasks", ok)
+	handler := h.nodeRunScope(mux, audit.NewRecorder(store))
+
+	tests := []struct {
+		name   string
+		nodeID string // 空表示共享密钥认证，"-" 表示非节点请求
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"分配给本节点", "node-1", "PATCH", "/api/v1/runs/run-1", "", http.StatusOK},
+		{"分配给其他节点", "node-2", "POST", "/api/v1/runs/run-1/events", "", http.StatusForbidden},
+		{"未分配的 Run", "node-1", "GET", "/api/v1/runs/run-2", "", http.StatusForbidden},
+		{"Run 不存在", "node-1", "GET", "/api/v1/runs/run-x", "", http.StatusNotFound},
+		{"其他节点的 Run 列表", "node-2", "GET", "/api/v1/nodes/node-1/runs", "", http.StatusForbidden},
+		{"自己的 Run 列表", "node-1", "GET", "/api/v1/nodes/node-1/runs", "", http.StatusOK},
+		{"其他节点的期望状态", "node-2", "GET", "/api/v1/nodes/node-1/desired-state", "", http.StatusForbidden},
+		{"上报其他节点的实际状态", "node-2", "POST", "/api/v1/nodes/node-1/observed-state", "", http.StatusForbidden},
+		{"其他节点的代理", "node-2", "GET", "/api/v1/nodes/node-1/proxies", "", http.StatusForbidden},
+		{"上报其他节点的代理健康", "node-2", "POST", "/api/v1/nodes/node-1/proxy-health", "", http.StatusForbidden},
+		{"其他节点的动作", "node-2", "GET", "/api/v1/nodes/node-1/actions", "", http.StatusForbidden},
+		{"自己的期望状态", "node-1", "GET", "/api/v1/nodes/node-1/desired-state", "", http.StatusOK},
+		{"自己的动作", "node-1", "GET", "/api/v1/nodes/node-1/actions", "", http.StatusOK},
+		{"节点集合上的路由", "node-2", "GET", "/api/v1/nodes/occupancy/stream", "", http.StatusOK},
+		{"不受限制的路由", "node-2", "GET", "/api/v1/tasks", "", http.StatusOK},
+		{"共享密钥访问 Run", "", "POST", "/api/v1/runs/run-1/events", "", http.StatusForbidden},
+		{"共享密钥解析密钥", "", "POST", "/api/v1/secrets/resolve", "", http.StatusForbidden},
+		{"专属凭证解析凭据", "node-1", "POST", "/api/v1/credentials/resolve", "", http.StatusOK},
+		{"为其他节点上报心跳", "node-2", "POST", "/api/v1/nodes/heartbeat", `{"node_id":"node-1"}`, http.StatusForbidden},
+		{"为自己上报心跳", "node-1", "POST", "/api/v1/nodes/heartbeat", `{"node_id":"node-1"}`, http.StatusOK},
+		{"共享密钥上报心跳", "", "POST", "/api/v1/nodes/heartbeat", `{"node_id":"node-1"}`, http.StatusOK},
+		{"用户请求不限制", "-", "GET", "/api/v1/runs/run-2", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.nodeID != "-" {
+				req = req.WithContext(auth.WithNodeIdentity(req.Context(), tt.nodeID))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+
+	if len(store.audits) != 11 {
+		t.Fatalf("audits = %d, want 11", len(store.audits))
+	}
+	a := store.audits[0]
+	if a.ActorID != "node-2" || a.ActorRole != audit.ActorRoleNode || a.Status != http.StatusForbidden ||
+		a.Route != "POST /api/v1/runs/{id}/events" || a.ResourceType != "runs" || a.ResourceID != "run-1" {
+		t.Errorf("audit = %+v", a)
+	}
+	a = store.audits[4]
+	if a.Route != "POST /api/v1/nodes/{id}/observed-state" || a.ResourceType != "nodes" || a.ResourceID != "node-1" {
+		t.Errorf("audit = %+v", a)
+	}
+	a = store.audits[10]
+	if a.ActorID != "node-2" || a.Route != "POST /api/v1/nodes/heartbeat" || a.ResourceType != "nodes" || a.ResourceID != "node-1" {
+		t.Errorf("audit = %+v", a)
+	}
+}
+
+// TestNodeRunScope_HeartbeatBody 心跳请求体校验后还原，处理器仍能读取
+func TestNodeRunScope_HeartbeatBody(t *testing.T) {
+	h, store := newNodeScopeHandler()
+	mux := http.NewServeMux()
+	var body string
+	mux.HandleFunc("POST /api/v1/nodes/heartbeat", func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		body = string(b)
+	})
+	req := httptest.NewRequest("POST", "/api/v1/nodes/heartbeat", strings.NewReader(`{"node_id":"node-1"}`))
+	req = req.WithContext(auth.WithNodeIdentity(req.Context(), "node-1"))
+	h.nodeRunScope(mux, audit.NewRecorder(store)).ServeHTTP(httptest.NewRecorder(), req)
+	if body != `{"node_id":"node-1"}` {
+		t.Errorf("body = %q", body)
+	}
+}
+
+// TestNodeGRPC_Scope gRPC 状态与事件上报同样限制为分配给该节点的 Run
+func TestNodeGRPC_Scope(t *testing.T) {
+	h, store := newNodeScopeHandler()
+	svc := &nodeService{h: h, auditor: audit.NewRecorder(store)}
+	ctx := auth.WithNodeIdentity(context.Background(), "node-2")
+
+	_, err := svc.UpdateRunStatus(ctx, &nodev1.UpdateRunStatusRequest{RunId: "run-1", Status: "done", NodeId: "node-1"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("err = %v, 期望 PermissionDenied", err)
+	}
+	if store.RunByID["run-1"].Status != model.RunStatusRunning {
+		t.Error("越权上报不应修改 Run 状态")
+	}
+	if len(store.audits) != 1 || store.audits[0].Method != "GRPC" || store.audits[0].Route != nodev1.NodeService_UpdateRunStatus_FullMethodName {
+		t.Errorf("audits = %+v", store.audits)
+	}
+
+	if _, err := svc.UpdateRunStatus(auth.WithNodeIdentity(context.Background(), "node-1"),
+		&nodev1.UpdateRunStatusRequest{RunId: "run-1", Status: "done", NodeId: "node-1"}); err != nil {
+		t.Fatalf("分配给本节点的 Run err = %v", err)
+	}
+
+	_, err = svc.Heartbeat(ctx, &nodev1.HeartbeatRequest{NodeId: "node-1"})
+	if status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("其他节点的心跳 err = %v, 期望 PermissionDenied", err)
+	}
+	if len(store.audits) != 2 || store.audits[1].Route != nodev1.NodeService_Heartbeat_FullMethodName || store.audits[1].ResourceID != "node-1" {
+		t.Errorf("audits = %+v", store.audits)
+	}
+
+	// 共享密钥没有节点身份，不能访问 Run
+	_, err = svc.UpdateRunStatus(auth.WithNodeIdentity(context.Background(), ""),
+		&nodev1.UpdateRunStatusRequest{RunId: "run-1", Status: "failed", NodeId: "node-1"})
+	if status.Code(err) != codes.PermissionDenied || len(store.audits) != 3 {
+		t.Fatalf("共享密钥 err = %v, audits = %d", err, len(store.audits))
+	}
+
+	_, err = svc.UpdateRunStatus(auth.WithNodeIdentity(context.Background(), "node-1"),
+		&nodev1.UpdateRunStatusRequest{RunId: "run-x", Status: "done", NodeId: "node-1"})
+	if status.Code(err) != codes.NotFound {
+		t.Fatalf("不存在的 Run err = %v, 期望 NotFound", err)
+	}
+}
@@ -0,0 +1,239 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"agents-admin/internal/apiserver/bodylimit"
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+const (
+	// maxNodeLogBatch 单次上报的节点日志条数上限
+	maxNodeLogBatch = 500
+	// maxNodeLogMessage 单条节点日志的消息长度上限（字节），超出部分截断
+	maxNodeLogMessage = 8 << 10
+	// nodeLogScanBatch 查询节点日志时分批读取事件的批大小
+	nodeLogScanBatch = 1000
+)
+
+// nodeLogLevels 节点日志级别（按严重程度升序）
+var nodeLogLevels = map[string]int{"debug": 0, "info": 1, "warn": 2, "error": 3}
+
+// NodeLogEntry 一条节点日志
+type NodeLogEntry struct {
+	Seq       int       `json:"seq,omitempty"` // 入库事件序号（上报时忽略）
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`   // debug / info / warn / error
+	Stage     string    `json:"stage"`   // 所处阶段：workspace / container / docker / process 等
+	Message   string    `json:"message"` // 日志内容（节点已对 Run 引用的密钥脱敏）
+}
+
+// PostNodeLogs 上报 Run 的节点日志
+//
+// 路由: POST /api/v1/runs/{id}/node-logs
+//
+// 请求体:
+//
+//	{"logs": [{"timestamp": "...", "level": "error", "stage": "workspace", "message": "git clone failed: ..."}]}
+//
+// 响应:
+//   - 201 Created: 返回 {"created": 1}
+//   - 400 Bad Request: 请求体格式错误、条数超过上限或级别不合法
+//   - 404 Not Found: Run 不存在
+//   - 500 Internal Server Error: 服务器内部错误
+//
+// 节点日志是 NodeManager 侧的诊断信息（Workspace 准备、容器定位、Agent 进程 stderr），以 node_log 事件入库：
+// 序号由 API Server 分配（与 run_orphaned 等自身写入的事件相同，不携带节点序号），
+// 因此不参与事件上报的去重，也不触发任务状态变更等事件副作用。
+// 启用内容审核时与事件一样掩码命中内容，但不记录审核标记、不终止 Run。
+func (h *Handler) PostNodeLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	runID := r.PathValue("id")
+
+	var req struct {
+		Logs []NodeLogEntry `json:"logs"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		if bodylimit.IsTooLarge(err) {
+			bodylimit.WriteTooLarge(w, err)
+			return
+		}
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if len(req.Logs) == 0 {
+		writeJSON(w, http.StatusCreated, map[string]int{"created": 0})
+		return
+	}
+	if len(req.Logs) > maxNodeLogBatch {
+		writeError(w, http.StatusBadRequest, "too many logs in one request (max "+strconv.Itoa(maxNodeLogBatch)+")")
+		return
+	}
+
+	run, err := h.store.GetRun(ctx, runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	events := make([]*model.Event, 0, len(req.Logs))
+	for _, l := range req.Logs {
+		if _, ok := nodeLogLevels[l.Level]; !ok {
+			writeError(w, http.StatusBadRequest, "invalid level "+strconv.Quote(l.Level))
+			return
+		}
+		if l.Timestamp.IsZero() {
+			l.Timestamp = time.Now()
+		}
+		payload, _ := json.Marshal(map[string]string{
+			"level":   l.Level,
+			"stage":   l.Stage,
+			"message": truncateNodeLog(l.Message),
+		})
+		events = append(events, &model.Event{
+			RunID:         runID,
+			Type:          string(model.EventTypeNodeLog),
+			Timestamp:     l.Timestamp,
+			Payload:       payload,
+			SchemaVersion: 1,
+		})
+	}
+
+	first, err := h.store.AllocateEventSeqs(ctx, runID, len(events))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create node logs")
+		return
+	}
+	for i, e := range events {
+		e.Seq = first + i
+	}
+	if h.moderator != nil {
+		h.moderator.Apply(runID, events)
+	}
+	if err := h.store.CreateEvents(ctx, events); err != nil {
+		slog.ErrorContext(ctx, "events.node_log.failed", "run_id", runID, "count", len(events), "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create node logs")
+		return
+	}
+
+	for _, e := range events {
+		h.eventGateway.Broadcast(runID, map[string]interface{}{
+			"seq":            e.Seq,
+			"type":           e.Type,
+			"timestamp":      e.Timestamp,
+			"payload":        e.Payload,
+			"schema_version": e.SchemaVersion,
+			"redacted":       e.Redacted,
+		})
+	}
+	writeJSON(w, http.StatusCreated, map[string]int{"created": len(events)})
+}
+
+// GetNodeLogs 查询 Run 的节点日志
+//
+// 路由: GET /api/v1/runs/{id}/node-logs
+//
+// 路径参数:
+//   - id: Run ID
+//
+// 查询参数:
+//   - level: 最低级别（debug / info / warn / error），默认全部
+//   - stage: 只返回该阶段的日志
+//   - limit: 返回最近的条数，默认 200，最大 1000
+//
+// 响应:
+//
+//	{
+//	  "logs": [{"seq": 3, "timestamp": "...", "level": "error", "stage": "workspace", "message": "..."}],
+//	  "count": 1
+//	}
+//
+// 错误响应:
+//   - 400 Bad Request: 级别不合法
+//   - 404 Not Found: Run 不存在
+//   - 410 Gone: 事件已按项目保留策略删除
+//   - 500 Internal Server Error: 服务器内部错误
+//
+// 使用场景：
+//   - Run 在 Workspace 准备或容器阶段失败、没有 Agent 输出时，查看节点侧的诊断信息，无需登录节点查看系统日志
+func (h *Handler) GetNodeLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := storage.WithReadReplica(r.Context())
+	runID := r.PathValue("id")
+	q := r.URL.Query()
+
+	minLevel := 0
+	if level := q.Get("level"); level != "" {
+		var ok bool
+		if minLevel, ok = nodeLogLevels[level]; !ok {
+			writeError(w, http.StatusBadRequest, "invalid level "+strconv.Quote(level))
+			return
+		}
+	}
+	stage := q.Get("stage")
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 1000 {
+		limit = 200
+	}
+
+	run, err := h.store.GetRun(ctx, runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	logs := []NodeLogEntry{}
+	for fromSeq := 0; ; {
+		events, err := h.eventReader().GetEventsByRun(ctx, runID, fromSeq, nodeLogScanBatch)
+		if errors.Is(err, lifecycle.ErrEventsPurged) {
+			writeError(w, http.StatusGone, err.Error())
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "failed to get events")
+			return
+		}
+		for _, e := range events {
+			if e.Type != string(model.EventTypeNodeLog) {
+				continue
+			}
+			entry := NodeLogEntry{Seq: e.Seq, Timestamp: e.Timestamp}
+			json.Unmarshal(e.Payload, &entry)
+			if nodeLogLevels[entry.Level] < minLevel || (stage != "" && entry.Stage != stage) {
+				continue
+			}
+			logs = append(logs, entry)
+			if len(logs) > limit {
+				logs = logs[1:]
+			}
+		}
+		if len(events) < nodeLogScanBatch {
+			break
+		}
+		fromSeq = events[len(events)-1].Seq
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"logs": logs, "count": len(logs)})
+}
+
+// truncateNodeLog 截断超长的日志内容（按 UTF-8 字符边界）
+func truncateNodeLog(msg string) string {
+	if len(msg) <= maxNodeLogMessage {
+		return msg
+	}
+	return strings.ToValidUTF8(msg[:maxNodeLogMessage], "") + "…(truncated)"
+}
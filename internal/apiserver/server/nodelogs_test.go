@@ -0,0 +1,80 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// TestNodeLogs 节点日志以 node_log 事件入库（序号由 API Server 分配，不带节点序号），查询时按级别与阶段过滤
+func TestNodeLogs(t *testing.T) {
+	store := &moderationStore{mockMonitorStore: &mockMonitorStore{
+		RunByID: map[string]*model.Run{"run-1": {ID: "run-1", TaskID: "task-1", Status: model.RunStatusAssigned}},
+		Events: map[string][]*model.Event{"run-1": {
+			{RunID: "run-1", Seq: 1, NodeSeq: 1, Type: "run_started"},
+		}},
+	}}
+	h := newTestHandler(store)
+	h.eventGateway = NewEventGateway(store, nil)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/runs/{id}/node-logs", h.GetNodeLogs)
+	mux.HandleFunc("POST /api/v1/runs/{id}/node-logs", h.PostNodeLogs)
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return w
+	}
+
+	w := do("POST", "/api/v1/runs/run-1/node-logs", `{"logs":[
+		{"timestamp":"2026-01-01T00:00:00Z","level":"info","stage":"workspace","message":"clone https://example.com/repo.git"},
+		{"timestamp":"2026-01-01T00:00:01Z","level":"error","stage":"workspace","message":"`+strings.Repeat("x", maxNodeLogMessage+10)+`"},
+		{"timestamp":"2026-01-01T00:00:02Z","level":"warn","stage":"container","message":"instance inst-1 status stopped"}
+	]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	stored := store.Events["run-1"]
+	if len(stored) != 4 || stored[1].Seq != 2 || stored[1].NodeSeq != 0 || stored[1].Type != string(model.EventTypeNodeLog) {
+		t.Fatalf("stored = %+v", stored[1:])
+	}
+	if store.RunByID["run-1"].Status != model.RunStatusAssigned {
+		t.Error("节点日志不应更新 Run 状态")
+	}
+
+	if w := do("POST", "/api/v1/runs/run-1/node-logs", `{"logs":[{"level":"fatal","stage":"x","message":"y"}]}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid level status = %d", w.Code)
+	}
+	if w := do("POST", "/api/v1/runs/missing/node-logs", `{"logs":[{"level":"info","stage":"x","message":"y"}]}`); w.Code != http.StatusNotFound {
+		t.Errorf("missing run status = %d", w.Code)
+	}
+
+	get := func(query string) []NodeLogEntry {
+		t.Helper()
+		w := do("GET", "/api/v1/runs/run-1/node-logs"+query, "")
+		if w.Code != http.StatusOK {
+			t.Fatalf("GET %s status = %d, body = %s", query, w.Code, w.Body.String())
+		}
+		var resp struct {
+			Logs []NodeLogEntry `json:"logs"`
+		}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return resp.Logs
+	}
+	if logs := get(""); len(logs) != 3 || logs[0].Message != "clone https://example.com/repo.git" || logs[0].Seq != 2 {
+		t.Errorf("all logs = %+v", logs)
+	}
+	if logs := get("?level=warn"); len(logs) != 2 || logs[0].Level != "error" || !strings.HasSuffix(logs[0].Message, "…(truncated)") {
+		t.Errorf("level=warn logs = %d", len(logs))
+	}
+	if logs := get("?stage=workspace&limit=1"); len(logs) != 1 || logs[0].Level != "error" {
+		t.Errorf("stage=workspace&limit=1 logs = %+v", logs)
+	}
+	if w := do("GET", "/api/v1/runs/run-1/node-logs?level=verbose", ""); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid level status = %d", w.Code)
+	}
+}
@@ -0,0 +1,43 @@
+package server
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"agents-admin/api"
+)
+
+// openAPIBundlePath 内嵌的单文件 OpenAPI 规范（所有 $ref 已内联到 components）
+const openAPIBundlePath = "openapi/bundled.yaml"
+
+// loadOpenAPISpec 解析内嵌的 OpenAPI 规范并序列化为 JSON（进程内只解析一次）
+var loadOpenAPISpec = sync.OnceValues(func() ([]byte, error) {
+	data, err := api.OpenAPIFS.ReadFile(openAPIBundlePath)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+})
+
+// GetOpenAPISpec 返回 JSON 格式的 OpenAPI 3 规范（公开，供 SDK 生成与接口调试）
+// GET /api/v1/openapi.json
+//
+// YAML 源文件见 /spec/openapi.yaml；规范与路由的一致性由 openapi_test.go 校验。
+func GetOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	data, err := loadOpenAPISpec()
+	if err != nil {
+		slog.ErrorContext(r.Context(), "openapi.spec.load.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to load openapi spec")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -0,0 +1,241 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"agents-admin/api"
+	"agents-admin/internal/apiserver/confreload"
+	"agents-admin/internal/apiserver/node"
+	"agents-admin/internal/config"
+	"agents-admin/internal/shared/model"
+)
+
+// specCoveredRoute 需要与 OpenAPI 规范保持一致的路由（SDK 覆盖的任务、执行、事件、节点与监控接口）
+var specCoveredRoute = regexp.MustCompile(`^(GET|POST|PUT|PATCH|DELETE) (/api/v1/(tasks|runs|nodes|monitor)(/.*)?)$`)
+
+// pathParam 路径参数统一为 {}，忽略参数命名差异（{id} 与 {node_id}）
+var pathParam = regexp.MustCompile(`\{[^}]*\}`)
+
+func loadBundledSpec(t *testing.T) *openapi3.T {
+	t.Helper()
+	data, err := api.OpenAPIFS.ReadFile(openAPIBundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("解析 %s 失败: %v", openAPIBundlePath, err)
+	}
+	return doc
+}
+
+// routerRoutes 扫描 internal/apiserver 下注册的路由字面量（mux.HandleFunc / mux.Handle 的第一个参数）
+func routerRoutes(t *testing.T) map[string]string {
+	t.Helper()
+	routes := make(map[string]string)
+	fset := token.NewFileSet()
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return err
+		}
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "HandleFunc" && sel.Sel.Name != "Handle") {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				return true
+			}
+			pattern, _ := strconv.Unquote(lit.Value)
+			if m := specCoveredRoute.FindStringSubmatch(pattern); m != nil {
+				routes[m[1]+" "+pathParam.ReplaceAllString(m[2], "{}")] = fset.Position(lit.Pos()).String()
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return routes
+}
+
+// TestOpenAPISpec_Valid 内嵌规范符合 OpenAPI 3 规范
+func TestOpenAPISpec_Valid(t *testing.T) {
+	doc := loadBundledSpec(t)
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("规范校验失败: %v", err)
+	}
+}
+
+// TestOpenAPISpec_RouteCoverage 规范与路由双向一致：已注册的路由都有文档，文档中的操作都有路由
+func TestOpenAPISpec_RouteCoverage(t *testing.T) {
+	routes := routerRoutes(t)
+	if len(routes) == 0 {
+		t.Fatal("未扫描到路由")
+	}
+
+	documented := make(map[string]bool)
+	for path, item := range loadBundledSpec(t).Paths.Map() {
+		for method := range item.Operations() {
+			route := method + " " + path
+			if m := specCoveredRoute.FindStringSubmatch(route); m != nil {
+				documented[m[1]+" "+pathParam.ReplaceAllString(m[2], "{}")] = true
+			}
+		}
+	}
+
+	var missing, stale []string
+	for route, pos := range routes {
+		if !documented[route] {
+			missing = append(missing, route+" ("+pos+")")
+		}
+	}
+	for route := range documented {
+		if _, ok := routes[route]; !ok {
+			stale = append(stale, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("路由未写入 api/openapi（需同步更新 bundled.yaml）:\n  %s", strings.Join(missing, "\n  "))
+	}
+	if len(stale) > 0 {
+		t.Errorf("规范中的操作没有对应路由:\n  %s", strings.Join(stale, "\n  "))
+	}
+}
+
+// TestOpenAPISpec_SchemasMatchModels 接口返回的模型 JSON 符合规范中的同名 schema（字段类型、必填字段、枚举值）
+func TestOpenAPISpec_SchemasMatchModels(t *testing.T) {
+	doc := loadBundledSpec(t)
+	now := time.Now().UTC()
+	nodeID, errMsg, raw, templateID := "node-1", "exit 1", "line", "tpl-1"
+	duration := int64(1500)
+
+	samples := map[string]interface{}{
+		"Task": &model.Task{
+			ID: "task-1", Name: "demo", Status: model.TaskStatusInProgress, Type: model.TaskTypeDevelopment,
+			Prompt:    &model.Prompt{Content: "fix the bug", TemplateID: &templateID, Variables: map[string]interface{}{"lang": "go"}},
+			Labels:    map[string]string{"team": "infra"},
+			Secrets:   []string{"GITHUB_TOKEN"},
+			Priority:  model.PriorityHigh,
+			CreatedAt: now, UpdatedAt: now,
+		},
+		"Run": &model.Run{
+			ID: "run-1", TaskID: "task-1", Status: model.RunStatusFailed, NodeID: &nodeID,
+			StartedAt: &now, FinishedAt: &now, Snapshot: json.RawMessage(`{"prompt":"hi"}`),
+			Priority: model.PriorityNormal, Error: &errMsg, CreatedAt: now, UpdatedAt: now,
+		},
+		"Event": &model.Event{
+			ID: 42, RunID: "run-1", Seq: 3, Type: "message", Timestamp: now,
+			Payload: json.RawMessage(`{"content":"hi"}`), Raw: &raw,
+		},
+		"Node": node.Response{
+			ID: "node-1", DisplayName: "builder", Status: string(model.NodeStatusDraining), Hostname: "host-1",
+			Labels: map[string]string{"os": "linux"}, Capacity: map[string]interface{}{"max_concurrent": 2},
+			LastHeartbeat: &now, CreatedAt: now, UpdatedAt: now,
+		},
+		"Workflow": WorkflowSummary{
+			ID: "run-1", Type: "run", Name: "demo", State: "failed", Progress: 100, EventCount: 3,
+			StartTime: &now, UpdateTime: &now, Duration: &duration, NodeID: nodeID, Error: errMsg,
+		},
+		"WorkflowDetail": &WorkflowDetail{
+			WorkflowSummary: WorkflowSummary{ID: "run-1", Type: "run", State: "running", StartTime: &now, UpdateTime: &now},
+			Events:          []WorkflowEventView{{ID: "1", Type: "message", Seq: 1, ProducerID: nodeID, Timestamp: now, Level: "info"}},
+			RelatedIDs:      map[string]string{"task_id": "task-1"},
+		},
+		"MonitorStats": &MonitorStats{TotalWorkflows: 3, WorkflowsByType: map[string]int{"run": 3}, WorkflowsByState: map[string]int{"done": 3}},
+		"NodeOccupancy": &node.NodeOccupancy{
+			NodeID: nodeID, Online: true, Capacity: 2, Used: 1, UpdatedAt: now,
+			Slots: []node.SlotOccupancy{{Slot: 0, RunID: "run-1", TaskID: "task-1", State: "running", Since: now}},
+		},
+		"RunUsage":      &model.RunUsage{RunID: "run-1", TaskID: "task-1", InputTokens: 10, CostUSD: 0.01, Day: "2026-01-01", CreatedAt: now, UpdatedAt: now},
+		"Artifact":      &model.Artifact{ID: 1, RunID: "run-1", Name: "diff", Path: "runs/run-1/diff.patch", CreatedAt: now},
+		"RunFlag":       &model.RunFlag{ID: 1, RunID: "run-1", EventSeq: 3, Detector: "email", Severity: model.ModerationSeverityHigh, Count: 1, CreatedAt: now},
+		"IssueLink":     &model.IssueLink{TaskID: "task-1", IntegrationID: "int-1", IssueKey: "42", URL: "https://github.com/o/r/issues/42", CreatedAt: now},
+		"NodeJoinToken": &model.NodeJoinToken{ID: "jt-1", MaxUses: 1, ExpiresAt: now, CreatedAt: now},
+		"SystemStatus":  newTestHandler(newOverviewStore()).buildSystemStatus(context.Background()),
+		"SnapshotErrorResponse": map[string]interface{}{
+			"error":  "invalid run snapshot",
+			"fields": []model.SnapshotFieldError{{Field: "agent.type", Message: "is required"}},
+		},
+		"NodeLogList": map[string]interface{}{
+			"logs":  []NodeLogEntry{{Seq: 2, Timestamp: now, Level: "error", Stage: "workspace", Message: "git clone failed"}},
+			"count": 1,
+		},
+	}
+	effective := httptest.NewRecorder()
+	confreload.New(&config.Config{ConfigFilePath: "configs/dev.yaml"}, nil).GetConfig(effective, httptest.NewRequest(http.MethodGet, "/api/v1/system/config", nil))
+	samples["EffectiveConfig"] = json.RawMessage(effective.Body.Bytes())
+
+	for name, sample := range samples {
+		ref := doc.Components.Schemas[name]
+		if ref == nil {
+			t.Errorf("components.schemas 缺少 %s", name)
+			continue
+		}
+		data, err := json.Marshal(sample)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			t.Fatal(err)
+		}
+		if err := ref.Value.VisitJSON(value); err != nil {
+			t.Errorf("%s 与规范不一致: %v\n%s", name, err, data)
+		}
+	}
+}
+
+// TestGetOpenAPISpec 以 JSON 返回内嵌规范
+func TestGetOpenAPISpec(t *testing.T) {
+	w := httptest.NewRecorder()
+	GetOpenAPISpec(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	var spec struct {
+		OpenAPI string                     `json:"openapi"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &spec); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("openapi = %q", spec.OpenAPI)
+	}
+	if _, ok := spec.Paths["/api/v1/tasks/{id}"]; !ok {
+		t.Errorf("paths 缺少 /api/v1/tasks/{id}")
+	}
+}
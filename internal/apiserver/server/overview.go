@@ -0,0 +1,377 @@
+// Package server 管理后台系统总览接口
+//
+// 一次调用聚合运维首页所需的全部指标，避免前端分别请求十余个接口：
+//   - 组件健康（数据库 / 只读副本 / Redis / MinIO）
+//   - 调度器与调度队列
+//   - 节点集群概况
+//   - 最近 24 小时 Run 吞吐与错误预算
+//   - 存储用量
+//   - 待处理审批
+//
+// 各部分独立采集，单项失败只在该部分记录 error，不影响整体响应。
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	nodemgr "agents-admin/internal/apiserver/node"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// 总览统计参数
+const (
+	overviewWindow        = 24 * time.Hour   // 吞吐与错误预算的统计窗口
+	overviewPingTimeout   = 3 * time.Second  // 单个组件健康检查超时
+	overviewUsageTimeout  = 10 * time.Second // 存储用量统计超时
+	defaultRunSuccessSLO  = 0.95             // Run 成功率目标（错误预算基准）
+	componentStatusOK     = "ok"
+	componentStatusError  = "error"
+	componentStatusAbsent = "disabled"
+)
+
+// pinger 支持健康检查的组件（数据库、Redis、MinIO 均实现）
+type pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// databaseSizer 支持统计存储空间的数据库
+type databaseSizer interface {
+	DatabaseSize(ctx context.Context) (int64, error)
+}
+
+// replicaReporter 配置了只读副本的数据库
+type replicaReporter interface {
+	ReplicaStatus() *storage.ReplicaStatus
+}
+
+// ComponentHealth 组件健康状态
+type ComponentHealth struct {
+	Status    string `json:"status"` // ok / error / disabled
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// SchedulerOverview 调度器与队列概况
+type SchedulerOverview struct {
+	Leader       string   `json:"leader"` // 当前执行调度的实例（调度器内置于 API Server，即本实例）
+	Running      bool     `json:"running"`
+	Strategies   []string `json:"strategies,omitempty"`
+	Preemption   bool     `json:"preemption"`
+	QueueLength  int64    `json:"queue_length"`  // 调度队列中的消息数
+	QueuePending int64    `json:"queue_pending"` // 已读取未确认的消息数
+	QueuedRuns   int      `json:"queued_runs"`   // 数据库中 queued 状态的 Run
+	AssignedRuns int      `json:"assigned_runs"` // 已分配未开始执行的 Run
+	RunningRuns  int      `json:"running_runs"`
+	Error        string   `json:"error,omitempty"`
+}
+
+// NodeFleetOverview 节点集群概况
+type NodeFleetOverview struct {
+	Total       int            `json:"total"`
+	Online      int            `json:"online"` // 心跳新鲜且非行政状态（可调度）
+	Offline     int            `json:"offline"`
+	ByStatus    map[string]int `json:"by_status"`
+	Capacity    int            `json:"capacity"`    // 在线节点最大并发之和
+	ActiveRuns  int            `json:"active_runs"` // assigned + running
+	Utilization float64        `json:"utilization"` // active_runs / capacity
+	Error       string         `json:"error,omitempty"`
+}
+
+// ThroughputOverview 统计窗口内的 Run 吞吐
+type ThroughputOverview struct {
+	Window      string                  `json:"window"`
+	Created     int                     `json:"created"`
+	Finished    int                     `json:"finished"` // done + failed + timeout + cancelled
+	Succeeded   int                     `json:"succeeded"`
+	Failed      int                     `json:"failed"` // failed + timeout
+	Cancelled   int                     `json:"cancelled"`
+	SuccessRate float64                 `json:"success_rate"` // succeeded / (succeeded + failed)，无数据时为 1
+	ByStatus    map[model.RunStatus]int `json:"by_status"`
+	Error       string                  `json:"error,omitempty"`
+}
+
+// ErrorBudget Run 成功率错误预算
+type ErrorBudget struct {
+	SLOTarget       float64 `json:"slo_target"`
+	Window          string  `json:"window"`
+	Evaluated       int     `json:"evaluated"`        // 参与计算的 Run（成功 + 失败，不含取消）
+	Failures        int     `json:"failures"`         // 失败 + 超时
+	AllowedFailures float64 `json:"allowed_failures"` // 窗口内允许的失败数
+	Consumed        float64 `json:"consumed"`         // 已消耗比例（可超过 1）
+	Remaining       float64 `json:"remaining"`        // 剩余比例（0~1）
+	Exhausted       bool    `json:"exhausted"`
+}
+
+// StorageOverview 存储用量
+type StorageOverview struct {
+	DatabaseBytes *int64   `json:"database_bytes,omitempty"`
+	ObjectCount   *int64   `json:"object_count,omitempty"`
+	ObjectBytes   *int64   `json:"object_bytes,omitempty"`
+	Errors        []string `json:"errors,omitempty"`
+}
+
+// ApprovalsOverview 待处理审批
+type ApprovalsOverview struct {
+	Pending int    `json:"pending"` // 审批请求 + 确认请求
+	Error   string `json:"error,omitempty"`
+}
+
+// AdminOverview 系统总览
+type AdminOverview struct {
+	Status      string                     `json:"status"` // 所有已启用组件健康时为 ok，否则 degraded
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Components  map[string]ComponentHealth `json:"components"`
+	Scheduler   SchedulerOverview          `json:"scheduler"`
+	Nodes       NodeFleetOverview          `json:"nodes"`
+	Throughput  ThroughputOverview         `json:"throughput"`
+	ErrorBudget ErrorBudget                `json:"error_budget"`
+	Storage     StorageOverview            `json:"storage"`
+	Approvals   ApprovalsOverview          `json:"approvals"`
+}
+
+// GetAdminOverview 管理后台系统总览
+//
+// 路由: GET /api/v1/admin/overview（仅管理员）
+//
+// 响应: AdminOverview
+//
+// 错误响应:
+//   - 403 Forbidden: 非管理员用户
+func (h *Handler) GetAdminOverview(w http.ResponseWriter, r *http.Request) {
+	if user := auth.GetAuthUser(r.Context()); user != nil && user.Role != auth.UserRoleAdmin {
+		writeError(w, http.StatusForbidden, "admin access required")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.buildAdminOverview(r.Context()))
+}
+
+// buildAdminOverview 采集系统总览数据
+func (h *Handler) buildAdminOverview(ctx context.Context) *AdminOverview {
+	now := time.Now()
+	ov := &AdminOverview{
+		Status:      componentStatusOK,
+		GeneratedAt: now,
+		Components:  h.componentHealth(ctx),
+	}
+	for _, c := range ov.Components {
+		if c.Status == componentStatusError {
+			ov.Status = "degraded"
+		}
+	}
+
+	// 全部 Run 的状态分布（队列积压与节点负载），以及统计窗口内的分布（吞吐与错误预算）
+	current, err := h.store.CountRunsByStatus(ctx, time.Time{})
+	if err != nil {
+		slog.ErrorContext(ctx, "admin.overview", "op", "CountRunsByStatus", "error", err)
+	}
+	ov.Scheduler = h.schedulerOverview(ctx, current, err)
+	ov.Nodes = h.nodeFleetOverview(ctx, current)
+
+	recent, err := h.store.CountRunsByStatus(ctx, now.Add(-overviewWindow))
+	if err != nil {
+		slog.ErrorContext(ctx, "admin.overview", "op", "CountRunsByStatus(window)", "error", err)
+		ov.Throughput = ThroughputOverview{Window: overviewWindow.String(), Error: err.Error()}
+	} else {
+		ov.Throughput = runThroughput(recent)
+	}
+	ov.ErrorBudget = computeErrorBudget(ov.Throughput, defaultRunSuccessSLO)
+
+	ov.Storage = h.storageOverview(ctx)
+
+	pending, err := h.store.CountPendingApprovals(ctx)
+	ov.Approvals = ApprovalsOverview{Pending: pending}
+	if err != nil {
+		slog.ErrorContext(ctx, "admin.overview", "op", "CountPendingApprovals", "error", err)
+		ov.Approvals.Error = err.Error()
+	}
+	return ov
+}
+
+// componentHealth 检查数据库、Redis 与 MinIO 的连通性
+func (h *Handler) componentHealth(ctx context.Context) map[string]ComponentHealth {
+	components := map[string]ComponentHealth{
+		"database": checkComponent(ctx, h.store),
+		"redis":    {Status: componentStatusAbsent},
+		"minio":    {Status: componentStatusAbsent},
+	}
+	if h.redisStore != nil {
+		components["redis"] = checkComponent(ctx, h.redisStore)
+	}
+	if h.minioClient != nil {
+		components["minio"] = checkComponent(ctx, h.minioClient)
+	}
+	if r, ok := h.store.(replicaReporter); ok {
+		if st := r.ReplicaStatus(); st != nil {
+			components["database_replica"] = replicaHealth(st)
+		}
+	}
+	return components
+}
+
+// checkComponent 对实现 pinger 的组件做一次带超时的健康检查
+//
+// 未实现 pinger 的组件视为健康（无法探测，但能正常服务请求）。
+func checkComponent(ctx context.Context, component interface{}) ComponentHealth {
+	p, ok := component.(pinger)
+	if !ok {
+		return ComponentHealth{Status: componentStatusOK}
+	}
+	ctx, cancel := context.WithTimeout(ctx, overviewPingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := p.Ping(ctx)
+	health := ComponentHealth{Status: componentStatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		health.Status = componentStatusError
+		health.Error = err.Error()
+	}
+	return health
+}
+
+// replicaHealth 只读副本状态（后台定期检查的结果，不额外探测）：不可用或延迟超限时读请求已回退到主库
+func replicaHealth(st *storage.ReplicaStatus) ComponentHealth {
+	if st.Usable {
+		return ComponentHealth{Status: componentStatusOK}
+	}
+	health := ComponentHealth{Status: componentStatusError, Error: st.Error}
+	if health.Error == "" {
+		health.Error = fmt.Sprintf("replication lag %.1fs exceeds %s", st.LagSeconds, st.MaxLag)
+	}
+	return health
+}
+
+// schedulerOverview 调度器状态与队列积压
+func (h *Handler) schedulerOverview(ctx context.Context, current map[model.RunStatus]int, countErr error) SchedulerOverview {
+	ov := SchedulerOverview{
+		QueuedRuns:   current[model.RunStatusQueued],
+		AssignedRuns: current[model.RunStatusAssigned],
+		RunningRuns:  current[model.RunStatusRunning],
+	}
+	if countErr != nil {
+		ov.Error = countErr.Error()
+	}
+	if h.scheduler != nil {
+		st := h.scheduler.Status()
+		ov.Running = st.Running
+		ov.Strategies = st.Strategies
+		ov.Preemption = st.Preemption
+		if st.Running {
+			ov.Leader = st.NodeID
+		}
+	}
+	if h.schedulerQueue != nil {
+		var err error
+		if ov.QueueLength, err = h.schedulerQueue.GetSchedulerQueueLength(ctx); err != nil {
+			ov.Error = err.Error()
+		}
+		if ov.QueuePending, err = h.schedulerQueue.GetSchedulerPendingCount(ctx); err != nil {
+			ov.Error = err.Error()
+		}
+	}
+	return ov
+}
+
+// nodeFleetOverview 节点在线情况、容量与负载
+func (h *Handler) nodeFleetOverview(ctx context.Context, current map[model.RunStatus]int) NodeFleetOverview {
+	ov := NodeFleetOverview{
+		ByStatus:   make(map[string]int),
+		ActiveRuns: current[model.RunStatusAssigned] + current[model.RunStatusRunning],
+	}
+	nodes, err := h.store.ListAllNodes(ctx)
+	if err != nil {
+		slog.ErrorContext(ctx, "admin.overview", "op", "ListAllNodes", "error", err)
+		ov.Error = err.Error()
+		return ov
+	}
+
+	ov.Total = len(nodes)
+	for _, n := range nodes {
+		ov.ByStatus[string(n.Status)]++
+	}
+	for _, n := range nodemgr.FilterNodesByFreshHeartbeat(nodes, nodemgr.HeartbeatFreshWindow) {
+		if n.IsAdminStatus() {
+			continue
+		}
+		ov.Online++
+		ov.Capacity += nodemgr.GetNodeMaxConcurrent(n)
+	}
+	ov.Offline = ov.Total - ov.Online
+	if ov.Capacity > 0 {
+		ov.Utilization = float64(ov.ActiveRuns) / float64(ov.Capacity)
+	}
+	return ov
+}
+
+// storageOverview 数据库与对象存储用量
+func (h *Handler) storageOverview(ctx context.Context) StorageOverview {
+	ctx, cancel := context.WithTimeout(ctx, overviewUsageTimeout)
+	defer cancel()
+
+	var ov StorageOverview
+	if sizer, ok := h.store.(databaseSizer); ok {
+		if size, err := sizer.DatabaseSize(ctx); err != nil {
+			slog.ErrorContext(ctx, "admin.overview", "op", "DatabaseSize", "error", err)
+			ov.Errors = append(ov.Errors, "database: "+err.Error())
+		} else {
+			ov.DatabaseBytes = &size
+		}
+	}
+	if h.minioClient != nil {
+		if count, size, err := h.minioClient.BucketUsage(ctx); err != nil {
+			slog.ErrorContext(ctx, "admin.overview", "op", "BucketUsage", "error", err)
+			ov.Errors = append(ov.Errors, "minio: "+err.Error())
+		} else {
+			ov.ObjectCount, ov.ObjectBytes = &count, &size
+		}
+	}
+	return ov
+}
+
+// runThroughput 根据状态分布计算吞吐指标
+func runThroughput(counts map[model.RunStatus]int) ThroughputOverview {
+	t := ThroughputOverview{Window: overviewWindow.String(), ByStatus: counts}
+	for _, n := range counts {
+		t.Created += n
+	}
+	t.Succeeded = counts[model.RunStatusDone]
+	t.Failed = counts[model.RunStatusFailed] + counts[model.RunStatusTimeout]
+	t.Cancelled = counts[model.RunStatusCancelled]
+	t.Finished = t.Succeeded + t.Failed + t.Cancelled
+	t.SuccessRate = 1
+	if evaluated := t.Succeeded + t.Failed; evaluated > 0 {
+		t.SuccessRate = float64(t.Succeeded) / float64(evaluated)
+	}
+	return t
+}
+
+// computeErrorBudget 计算 Run 成功率错误预算
+//
+// 允许失败数 = (1 - 目标成功率) × 已结束 Run 数（不含取消）；
+// 消耗比例 = 实际失败数 / 允许失败数。窗口内无 Run 时预算完整。
+func computeErrorBudget(t ThroughputOverview, target float64) ErrorBudget {
+	b := ErrorBudget{
+		SLOTarget: target,
+		Window:    t.Window,
+		Evaluated: t.Succeeded + t.Failed,
+		Failures:  t.Failed,
+	}
+	b.AllowedFailures = (1 - target) * float64(b.Evaluated)
+	switch {
+	case b.AllowedFailures > 0:
+		b.Consumed = float64(b.Failures) / b.AllowedFailures
+	case b.Failures > 0:
+		b.Consumed = 1
+	}
+	if b.Consumed < 1 {
+		b.Remaining = 1 - b.Consumed
+	}
+	b.Exhausted = b.Consumed >= 1
+	return b
+}
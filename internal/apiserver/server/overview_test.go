@@ -0,0 +1,145 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// overviewStore 模拟 PersistentStore，只实现总览所需方法
+type overviewStore struct {
+	storage.PersistentStore
+
+	nodes   []*model.Node
+	all     map[model.RunStatus]int // 全部 Run 的状态分布
+	recent  map[model.RunStatus]int // 统计窗口内的状态分布
+	pending int
+	pingErr error
+}
+
+func (m *overviewStore) Ping(_ context.Context) error { return m.pingErr }
+func (m *overviewStore) DatabaseSize(_ context.Context) (int64, error) {
+	return 4096, nil
+}
+func (m *overviewStore) ListAllNodes(_ context.Context) ([]*model.Node, error) {
+	return m.nodes, nil
+}
+func (m *overviewStore) CountRunsByStatus(_ context.Context, since time.Time) (map[model.RunStatus]int, error) {
+	if since.IsZero() {
+		return m.all, nil
+	}
+	return m.recent, nil
+}
+func (m *overviewStore) CountPendingApprovals(_ context.Context) (int, error) {
+	return m.pending, nil
+}
+
+func newOverviewStore() *overviewStore {
+	now := time.Now()
+	stale := now.Add(-time.Hour)
+	capacity, _ := json.Marshal(map[string]int{"max_concurrent": 4})
+	return &overviewStore{
+		nodes: []*model.Node{
+			{ID: "n1", Status: model.NodeStatusOnline, LastHeartbeat: &now, Capacity: capacity},
+			{ID: "n2", Status: model.NodeStatusOnline, LastHeartbeat: &now, Capacity: capacity},
+			{ID: "n3", Status: model.NodeStatusOnline, LastHeartbeat: &stale, Capacity: capacity},
+		},
+		all: map[model.RunStatus]int{
+			model.RunStatusQueued: 3, model.RunStatusAssigned: 1, model.RunStatusRunning: 3, model.RunStatusDone: 50,
+		},
+		recent: map[model.RunStatus]int{
+			model.RunStatusDone: 36, model.RunStatusFailed: 3, model.RunStatusTimeout: 1,
+			model.RunStatusCancelled: 2, model.RunStatusRunning: 3,
+		},
+		pending: 2,
+	}
+}
+
+func TestGetAdminOverview(t *testing.T) {
+	h := newTestHandler(newOverviewStore())
+
+	w := httptest.NewRecorder()
+	h.GetAdminOverview(w, httptest.NewRequest("GET", "/api/v1/admin/overview", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	var ov AdminOverview
+	if err := json.Unmarshal(w.Body.Bytes(), &ov); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if ov.Status != "ok" || ov.Components["database"].Status != "ok" || ov.Components["minio"].Status != "disabled" {
+		t.Errorf("components = %+v, status = %s", ov.Components, ov.Status)
+	}
+	if ov.Nodes.Total != 3 || ov.Nodes.Online != 2 || ov.Nodes.Capacity != 8 || ov.Nodes.ActiveRuns != 4 || ov.Nodes.Utilization != 0.5 {
+		t.Errorf("nodes = %+v", ov.Nodes)
+	}
+	if ov.Scheduler.QueuedRuns != 3 || ov.Scheduler.RunningRuns != 3 {
+		t.Errorf("scheduler = %+v", ov.Scheduler)
+	}
+	if ov.Throughput.Created != 45 || ov.Throughput.Succeeded != 36 || ov.Throughput.Failed != 4 || ov.Throughput.Finished != 42 {
+		t.Errorf("throughput = %+v", ov.Throughput)
+	}
+	// 40 个已结束 Run，95% 目标允许 2 次失败，实际 4 次 → 预算耗尽
+	if ov.ErrorBudget.Evaluated != 40 || ov.ErrorBudget.Consumed < 1.99 || !ov.ErrorBudget.Exhausted || ov.ErrorBudget.Remaining != 0 {
+		t.Errorf("error_budget = %+v", ov.ErrorBudget)
+	}
+	if ov.Storage.DatabaseBytes == nil || *ov.Storage.DatabaseBytes != 4096 {
+		t.Errorf("storage = %+v", ov.Storage)
+	}
+	if ov.Approvals.Pending != 2 {
+		t.Errorf("approvals = %+v", ov.Approvals)
+	}
+}
+
+func TestGetAdminOverview_Degraded(t *testing.T) {
+	store := newOverviewStore()
+	store.pingErr = errors.New("connection refused")
+	h := newTestHandler(store)
+
+	w := httptest.NewRecorder()
+	h.GetAdminOverview(w, httptest.NewRequest("GET", "/api/v1/admin/overview", nil))
+	var ov AdminOverview
+	json.Unmarshal(w.Body.Bytes(), &ov)
+	if ov.Status != "degraded" || ov.Components["database"].Error == "" {
+		t.Errorf("数据库不可用时应为 degraded: %+v", ov)
+	}
+}
+
+func TestGetAdminOverview_AdminOnly(t *testing.T) {
+	h := newTestHandler(newOverviewStore())
+	req := httptest.NewRequest("GET", "/api/v1/admin/overview", nil)
+	req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: "user"}))
+
+	w := httptest.NewRecorder()
+	h.GetAdminOverview(w, req)
+	if w.Code != http.StatusForbidden {
+		t.Errorf("status = %d, 期望 403", w.Code)
+	}
+}
+
+func TestComputeErrorBudget(t *testing.T) {
+	tests := []struct {
+		succeeded, failed int
+		remaining         float64
+		exhausted         bool
+	}{
+		{0, 0, 1, false},    // 无数据：预算完整
+		{99, 1, 0.8, false}, // 允许 5 次失败，消耗 1 次
+		{0, 3, 0, true},
+	}
+	for _, tt := range tests {
+		b := computeErrorBudget(ThroughputOverview{Succeeded: tt.succeeded, Failed: tt.failed}, 0.95)
+		if diff := b.Remaining - tt.remaining; diff > 1e-9 || diff < -1e-9 || b.Exhausted != tt.exhausted {
+			t.Errorf("succeeded=%d failed=%d: budget = %+v", tt.succeeded, tt.failed, b)
+		}
+	}
+}
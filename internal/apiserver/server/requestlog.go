@@ -0,0 +1,72 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"agents-admin/internal/shared/logging"
+)
+
+// requestIDHeader 请求 ID 请求头与响应头
+const requestIDHeader = "X-Request-ID"
+
+// validRequestID 沿用客户端传入的请求 ID 时的格式限制（防止日志注入）
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// requestResource 从路径中识别关联的资源 ID
+var requestResource = regexp.MustCompile(`^/(?:api/v1|ws)/(runs|tasks|nodes)/([^/]+)`)
+
+var resourceKeys = map[string]logging.ContextKey{
+	"runs":  logging.RunIDKey,
+	"tasks": logging.TaskIDKey,
+	"nodes": logging.NodeIDKey,
+}
+
+// requestLogMiddleware 请求 ID 与访问日志
+//
+// 沿用客户端传入的 X-Request-ID（格式不合法时重新生成）并写回响应头。请求上下文带上请求 ID 与路径中的
+// Run / 任务 / 节点 ID，认证中间件再追加节点或用户身份，该请求期间以上下文输出的日志都带上这些字段。
+// 请求结束时输出一条 http.request 日志：5xx 为 error，4xx 为 info，其余为 debug（节点轮询频繁，默认级别不输出）。
+func requestLogMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+
+		ctx := logging.WithRequestID(r.Context(), id)
+		if m := requestResource.FindStringSubmatch(r.URL.Path); m != nil {
+			ctx = context.WithValue(ctx, resourceKeys[m[1]], m[2])
+		}
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(wrapped, r.WithContext(ctx))
+
+		level := slog.LevelDebug
+		switch {
+		case wrapped.statusCode >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case wrapped.statusCode >= http.StatusBadRequest:
+			level = slog.LevelInfo
+		}
+		slog.LogAttrs(ctx, level, "http.request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", wrapped.statusCode),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+		)
+	})
+}
+
+// newRequestID 生成请求 ID（16 位十六进制）
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,84 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"agents-admin/internal/apiserver/audit"
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/logging"
+)
+
+func TestRequestLogMiddleware(t *testing.T) {
+	var gotID, gotRun string
+	h := requestLogMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotID = logging.RequestID(r.Context())
+		gotRun, _ = r.Context().Value(logging.RunIDKey).(string)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	// 沿用客户端传入的请求 ID，并识别路径中的 Run ID
+	r := httptest.NewRequest("PATCH", "/api/v1/runs/run-1", nil)
+	r.Header.Set(requestIDHeader, "client-req-1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if gotID != "client-req-1" || w.Header().Get(requestIDHeader) != "client-req-1" || gotRun != "run-1" {
+		t.Errorf("propagated: id=%q header=%q run=%q", gotID, w.Header().Get(requestIDHeader), gotRun)
+	}
+
+	// 格式不合法时重新生成
+	r = httptest.NewRequest("GET", "/api/v1/tasks", nil)
+	r.Header.Set(requestIDHeader, "bad id\nforged=1")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	if len(gotID) != 16 || gotID == "bad id\nforged=1" || w.Header().Get(requestIDHeader) != gotID || gotRun != "" {
+		t.Errorf("generated: id=%q header=%q run=%q", gotID, w.Header().Get(requestIDHeader), gotRun)
+	}
+}
+
+// TestRequestLogMiddleware_HandlerLogs 处理器以请求上下文输出的日志带上请求 ID 与路径中的资源 ID
+func TestRequestLogMiddleware_HandlerLogs(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "api.log")
+	prev := slog.Default()
+	if err := logging.Setup(logging.Config{Format: "json", Output: out}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		slog.SetDefault(prev)
+		log.SetOutput(os.Stderr)
+		log.SetFlags(log.LstdFlags)
+	})
+
+	h, store := newNodeScopeHandler()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/runs/{id}/events", func(w http.ResponseWriter, r *http.Request) {})
+	handler := requestLogMiddleware(h.nodeRunScope(mux, audit.NewRecorder(store)))
+
+	r := httptest.NewRequest("POST", "/api/v1/runs/run-1/events", nil)
+	r.Header.Set(requestIDHeader, "req-denied")
+	r = r.WithContext(auth.WithNodeIdentity(r.Context(), "node-2"))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var denied map[string]interface{}
+	for sc := bufio.NewScanner(f); sc.Scan(); {
+		var rec map[string]interface{}
+		if json.Unmarshal(sc.Bytes(), &rec) == nil && rec["msg"] == "node.scope.denied" {
+			denied = rec
+		}
+	}
+	if denied == nil || denied["level"] != "WARN" || denied["request_id"] != "req-denied" || denied["run_id"] != "run-1" {
+		t.Errorf("node.scope.denied = %v", denied)
+	}
+}
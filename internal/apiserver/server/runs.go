@@ -1,11 +1,14 @@
 // Package handler 执行管理接口
 //
 // 注意：HTTP 处理函数已迁移到 internal/apiserver/run 包
-// 本文件只保留与 Handler 结构体相关的方法（如 StartScheduler）
+// 本文件只保留与 Handler 结构体相关的方法（如 StartScheduler、StartWorkflowOrchestrator、StartRunWatchdog、StartRunReconciler）
 package server
 
 import (
 	"context"
+	"time"
+
+	"agents-admin/internal/apiserver/run"
 )
 
 // StartScheduler 启动任务调度器
@@ -17,3 +20,132 @@ import (
 func (h *Handler) StartScheduler(ctx context.Context) {
 	h.scheduler.Start(ctx)
 }
+
+// StartWorkflowOrchestrator 启动 DAG 工作流编排器
+//
+// 编排器在 Run 到达终态时被即时通知，并定期巡检执行中的工作流，
+// 为依赖全部成功的节点创建 Run。
+//
+// 参数：
+//   - ctx: 上下文，用于控制编排器生命周期
+func (h *Handler) StartWorkflowOrchestrator(ctx context.Context) {
+	h.orchestrator.Start(ctx)
+}
+
+// StartRunWatchdog 启动 Run 超时巡检
+//
+// 定期将超过执行时限的 running Run 标记为 timeout，并联动更新 Task 状态；
+// 节点在下一次心跳的 timeout_runs 指令中得知超时并终止执行。
+//
+// 参数：
+//   - ctx: 上下文，用于控制巡检生命周期
+//   - interval: 巡检间隔
+//   - defaultTimeout: 任务未设置 timeout_seconds 时的默认执行时限
+func (h *Handler) StartRunWatchdog(ctx context.Context, interval, defaultTimeout time.Duration) {
+	h.runs.StartWatchdog(ctx, interval, defaultTimeout)
+}
+
+// StartRunReconciler 启动孤儿 Run 回收
+//
+// 节点失联（连续缺失心跳）或节点心跳中持续缺少 DB 中 running 的 Run 时，
+// 将其重新排队或判定为 failed，并写入 run_orphaned 事件说明原因。
+//
+// 参数：
+//   - ctx: 上下文，用于控制回收循环生命周期
+//   - interval: 失联节点巡检间隔
+//   - cfg: 心跳间隔、缺失次数阈值与已开始执行 Run 的处理方式
+func (h *Handler) StartRunReconciler(ctx context.Context, interval time.Duration, cfg run.ReconcileConfig) {
+	h.runs.StartReconciler(ctx, interval, cfg)
+}
+
+// StartMaintenance 启动定时存储维护
+//
+// 在配置的时间窗口内按间隔对 events、runs 等表执行驱动级维护（VACUUM/compact），
+// 未设置维护服务或未启用定时维护时立即返回。
+//
+// 参数：
+//   - ctx: 上下文，用于控制维护循环生命周期
+func (h *Handler) StartMaintenance(ctx context.Context) {
+	if h.maintenance != nil {
+		h.maintenance.Start(ctx)
+	}
+}
+
+// StartRunLifecycle 启动 Run 数据分层巡检
+//
+// 按配置的时间与容量策略将终态 Run 的事件归档到对象存储（hot → warm），
+// 并清理长期归档 Run 的快照（warm → cold）。未启用数据分层时立即返回。
+//
+// 参数：
+//   - ctx: 上下文，用于控制巡检循环生命周期
+func (h *Handler) StartRunLifecycle(ctx context.Context) {
+	if h.lifecycle != nil {
+		h.lifecycle.Start(ctx)
+	}
+}
+
+// StartTerminalExpiry 启动终端会话过期巡检
+//
+// 定期将已过期的活跃终端会话标记为 closed，NodeManager 检测到后停止终端容器。
+//
+// 参数：
+//   - ctx: 上下文，用于控制巡检循环生命周期
+func (h *Handler) StartTerminalExpiry(ctx context.Context) {
+	h.terminals.StartExpiry(ctx)
+}
+
+// StartOutbox 启动事务发件箱中继
+//
+// 投递与 Run 同事务写入的调度消息，并补投进程崩溃前未入队的消息。未启用发件箱时立即返回。
+//
+// 参数：
+//   - ctx: 上下文，用于控制投递循环生命周期
+func (h *Handler) StartOutbox(ctx context.Context) {
+	if h.outbox != nil {
+		h.outbox.Start(ctx)
+	}
+}
+
+// StartIdempotencyCleanup 定期清理过期的幂等键
+//
+// 参数：
+//   - ctx: 上下文，用于控制清理循环生命周期
+func (h *Handler) StartIdempotencyCleanup(ctx context.Context) {
+	h.idempotency.Start(ctx)
+}
+
+// StartWebhooks 启动 Webhook 事件投递
+//
+// 加载并编译订阅的过滤条件，启动投递协程并定期重新加载订阅。未设置分发器时立即返回。
+//
+// 参数：
+//   - ctx: 上下文，用于控制投递循环生命周期
+func (h *Handler) StartWebhooks(ctx context.Context) {
+	if h.webhooks != nil {
+		h.webhooks.Start(ctx)
+	}
+}
+
+// StartEventSink 启动 Run 事件镜像投递
+//
+// 批量投递缓冲中的事件并定时重放死信文件，ctx 取消后投递剩余事件再返回。未设置投递器时立即返回。
+//
+// 参数：
+//   - ctx: 上下文，用于控制投递循环生命周期
+func (h *Handler) StartEventSink(ctx context.Context) {
+	if h.eventSink != nil {
+		h.eventSink.Start(ctx)
+	}
+}
+
+// StartRegistry 启动模板注册表定时同步
+//
+// 按配置的间隔从远程注册表同步任务模板、Agent 模板与技能。未设置同步器或未启用定时同步时立即返回。
+//
+// 参数：
+//   - ctx: 上下文，用于控制同步循环生命周期
+func (h *Handler) StartRegistry(ctx context.Context) {
+	if h.registry != nil {
+		h.registry.Start(ctx)
+	}
+}
@@ -0,0 +1,110 @@
+// Package server 事件全文检索接口
+package server
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// 检索分页默认值
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchRunSummary 检索结果中按 Run 聚合的摘要
+type SearchRunSummary struct {
+	RunID    string          `json:"run_id"`
+	TaskID   string          `json:"task_id,omitempty"`
+	Status   model.RunStatus `json:"status,omitempty"`
+	HitCount int             `json:"hit_count"` // 当前页中该 Run 的命中事件数
+}
+
+// SearchEvents 全文检索 Run 输出事件
+//
+// 路由: GET /api/v1/search/events
+//
+// 查询参数:
+//   - q: 检索关键词（必填，空白分隔，多个关键词需同时命中）
+//   - run_id: 限定 Run（可选）
+//   - limit: 返回数量限制，默认 20，最大 100
+//   - offset: 偏移量，默认 0
+//
+// 响应:
+//
+//	{
+//	  "query": "ETIMEDOUT",
+//	  "total": 42,
+//	  "events": [{"event_id": 1, "run_id": "...", "seq": 3, "type": "message", "highlight": "... <mark>ETIMEDOUT</mark> ..."}],
+//	  "runs": [{"run_id": "...", "task_id": "...", "status": "failed", "hit_count": 2}]
+//	}
+//
+// 错误响应:
+//   - 400 Bad Request: 缺少 q 参数
+//   - 500 Internal Server Error: 服务器内部错误
+//
+// 使用场景：
+//   - 查找修改过某个文件的 Run（如 q=src/main.go）
+//   - 查找输出过某条错误信息的 Run
+func (h *Handler) SearchEvents(w http.ResponseWriter, r *http.Request) {
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		writeError(w, http.StatusBadRequest, "q is required")
+		return
+	}
+	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+	if limit <= 0 || limit > maxSearchLimit {
+		limit = defaultSearchLimit
+	}
+	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	hits, total, err := h.store.SearchEvents(r.Context(), storage.EventSearchFilter{
+		Query:  q,
+		RunID:  r.URL.Query().Get("run_id"),
+		Limit:  limit,
+		Offset: offset,
+	})
+	if err != nil {
+		slog.ErrorContext(r.Context(), "search", "op", "SearchEvents", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to search events")
+		return
+	}
+	if hits == nil {
+		hits = []*model.EventSearchHit{}
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"query":  q,
+		"total":  total,
+		"events": hits,
+		"runs":   h.summarizeSearchRuns(r, hits),
+	})
+}
+
+// summarizeSearchRuns 按 Run 聚合命中事件（保持首次出现的顺序），并补充任务与状态信息
+func (h *Handler) summarizeSearchRuns(r *http.Request, hits []*model.EventSearchHit) []*SearchRunSummary {
+	runs := []*SearchRunSummary{}
+	index := make(map[string]*SearchRunSummary)
+	for _, hit := range hits {
+		if s, ok := index[hit.RunID]; ok {
+			s.HitCount++
+			continue
+		}
+		s := &SearchRunSummary{RunID: hit.RunID, HitCount: 1}
+		if run, err := h.store.GetRun(r.Context(), hit.RunID); err == nil && run != nil {
+			s.TaskID = run.TaskID
+			s.Status = run.Status
+		}
+		index[hit.RunID] = s
+		runs = append(runs, s)
+	}
+	return runs
+}
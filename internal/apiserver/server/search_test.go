@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// mockSearchStore 模拟事件检索存储
+type mockSearchStore struct {
+	mockMonitorStore
+
+	hits       []*model.EventSearchHit
+	lastFilter storage.EventSearchFilter
+}
+
+func (m *mockSearchStore) SearchEvents(_ context.Context, f storage.EventSearchFilter) ([]*model.EventSearchHit, int, error) {
+	m.lastFilter = f
+	return m.hits, len(m.hits), nil
+}
+
+func TestSearchEvents_GroupsByRun(t *testing.T) {
+	store := &mockSearchStore{
+		mockMonitorStore: mockMonitorStore{RunByID: map[string]*model.Run{
+			"run-a": {ID: "run-a", TaskID: "task-1", Status: model.RunStatusFailed},
+		}},
+		hits: []*model.EventSearchHit{
+			{EventID: 1, RunID: "run-a", Seq: 3, Highlight: "<mark>ETIMEDOUT</mark>"},
+			{EventID: 7, RunID: "run-b", Seq: 1, Highlight: "<mark>ETIMEDOUT</mark>"},
+			{EventID: 2, RunID: "run-a", Seq: 9, Highlight: "<mark>ETIMEDOUT</mark>"},
+		},
+	}
+	h := newTestHandler(store)
+
+	w := httptest.NewRecorder()
+	h.SearchEvents(w, httptest.NewRequest("GET", "/api/v1/search/events?q=ETIMEDOUT&limit=500&run_id=", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if store.lastFilter.Query != "ETIMEDOUT" || store.lastFilter.Limit != defaultSearchLimit {
+		t.Errorf("filter = %+v", store.lastFilter)
+	}
+
+	var resp struct {
+		Total  int                     `json:"total"`
+		Events []*model.EventSearchHit `json:"events"`
+		Runs   []*SearchRunSummary     `json:"runs"`
+	}
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Total != 3 || len(resp.Events) != 3 {
+		t.Fatalf("resp = %+v", resp)
+	}
+	if len(resp.Runs) != 2 || resp.Runs[0].RunID != "run-a" || resp.Runs[0].HitCount != 2 ||
+		resp.Runs[0].TaskID != "task-1" || resp.Runs[0].Status != model.RunStatusFailed {
+		t.Errorf("runs[0] = %+v", resp.Runs[0])
+	}
+	if resp.Runs[1].RunID != "run-b" || resp.Runs[1].HitCount != 1 || resp.Runs[1].TaskID != "" {
+		t.Errorf("runs[1] = %+v", resp.Runs[1])
+	}
+}
+
+func TestSearchEvents_MissingQuery(t *testing.T) {
+	h := newTestHandler(&mockSearchStore{})
+	w := httptest.NewRecorder()
+	h.SearchEvents(w, httptest.NewRequest("GET", "/api/v1/search/events?q=%20", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, 期望 400", w.Code)
+	}
+}
@@ -0,0 +1,173 @@
+// Package server 系统状态页接口
+//
+// 汇总运维人员判断部署是否正常所需的信息，供内置状态页定时轮询：
+//   - 依赖组件健康与延迟（数据库 / 只读副本 / Redis / MinIO）
+//   - 调度队列深度与待确认消息数
+//   - 节点在线 / 离线数
+//   - 最近的 API 错误率（5xx）与 Run 失败率
+//
+// 与管理后台系统总览共用采集逻辑，但不统计存储用量等耗时项；所有登录用户可访问，非管理员不返回组件错误详情。
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// 状态页统计参数
+const (
+	statusRequestWindow = 5 * time.Minute  // API 错误率的统计窗口
+	statusRequestBucket = 10 * time.Second // 请求计数的分桶粒度
+	statusRunWindow     = time.Hour        // Run 失败率的统计窗口
+)
+
+// SystemStatus 系统状态
+type SystemStatus struct {
+	Status      string                     `json:"status"` // 所有已启用组件健康且有在线节点时为 ok，否则 degraded
+	GeneratedAt time.Time                  `json:"generated_at"`
+	Components  map[string]ComponentHealth `json:"components"`
+	Scheduler   SchedulerOverview          `json:"scheduler"`
+	Nodes       NodeFleetOverview          `json:"nodes"`
+	ErrorRates  ErrorRates                 `json:"error_rates"`
+}
+
+// ErrorRates 最近的错误率
+type ErrorRates struct {
+	RequestWindow    string  `json:"request_window"`
+	Requests         int64   `json:"requests"`           // 窗口内的 API 请求数
+	ServerErrors     int64   `json:"server_errors"`      // 其中 5xx 响应数
+	RequestErrorRate float64 `json:"request_error_rate"` // server_errors / requests，无请求时为 0
+	RunWindow        string  `json:"run_window"`
+	RunsFinished     int     `json:"runs_finished"`    // 窗口内结束的 Run（成功 + 失败，不含取消）
+	RunsFailed       int     `json:"runs_failed"`      // 失败 + 超时
+	RunFailureRate   float64 `json:"run_failure_rate"` // runs_failed / runs_finished，无数据时为 0
+	Error            string  `json:"error,omitempty"`
+}
+
+// GetSystemStatus 系统状态页
+//
+// 路由: GET /api/v1/system/status
+//
+// 响应: SystemStatus
+func (h *Handler) GetSystemStatus(w http.ResponseWriter, r *http.Request) {
+	st := h.buildSystemStatus(r.Context())
+	if user := auth.GetAuthUser(r.Context()); user != nil && user.Role != auth.UserRoleAdmin {
+		redactStatusErrors(st)
+	}
+	writeJSON(w, http.StatusOK, st)
+}
+
+// buildSystemStatus 采集系统状态
+func (h *Handler) buildSystemStatus(ctx context.Context) *SystemStatus {
+	now := time.Now()
+	st := &SystemStatus{
+		Status:      componentStatusOK,
+		GeneratedAt: now,
+		Components:  h.componentHealth(ctx),
+	}
+	for _, c := range st.Components {
+		if c.Status == componentStatusError {
+			st.Status = "degraded"
+		}
+	}
+
+	current, err := h.store.CountRunsByStatus(ctx, time.Time{})
+	if err != nil {
+		slog.ErrorContext(ctx, "system.status", "op", "CountRunsByStatus", "error", err)
+	}
+	st.Scheduler = h.schedulerOverview(ctx, current, err)
+	st.Nodes = h.nodeFleetOverview(ctx, current)
+	if st.Nodes.Total > 0 && st.Nodes.Online == 0 {
+		st.Status = "degraded"
+	}
+
+	st.ErrorRates = ErrorRates{RequestWindow: statusRequestWindow.String(), RunWindow: statusRunWindow.String()}
+	if h.metrics != nil && h.metrics.recent != nil {
+		st.ErrorRates.Requests, st.ErrorRates.ServerErrors = h.metrics.recent.counts(now)
+		if st.ErrorRates.Requests > 0 {
+			st.ErrorRates.RequestErrorRate = float64(st.ErrorRates.ServerErrors) / float64(st.ErrorRates.Requests)
+		}
+	}
+	recent, err := h.store.CountRunsByStatus(ctx, now.Add(-statusRunWindow))
+	if err != nil {
+		slog.ErrorContext(ctx, "system.status", "op", "CountRunsByStatus(window)", "error", err)
+		st.ErrorRates.Error = err.Error()
+	} else {
+		st.ErrorRates.RunsFailed = recent[model.RunStatusFailed] + recent[model.RunStatusTimeout]
+		st.ErrorRates.RunsFinished = recent[model.RunStatusDone] + st.ErrorRates.RunsFailed
+		if st.ErrorRates.RunsFinished > 0 {
+			st.ErrorRates.RunFailureRate = float64(st.ErrorRates.RunsFailed) / float64(st.ErrorRates.RunsFinished)
+		}
+	}
+	return st
+}
+
+// redactStatusErrors 去掉错误详情（可能包含内部地址），只保留状态
+func redactStatusErrors(st *SystemStatus) {
+	for name, c := range st.Components {
+		if c.Error != "" {
+			c.Error = "unavailable"
+			st.Components[name] = c
+		}
+	}
+	if st.Scheduler.Error != "" {
+		st.Scheduler.Error = "unavailable"
+	}
+	if st.Nodes.Error != "" {
+		st.Nodes.Error = "unavailable"
+	}
+	if st.ErrorRates.Error != "" {
+		st.ErrorRates.Error = "unavailable"
+	}
+}
+
+// requestWindow 最近一段时间的 API 请求数与 5xx 响应数（按时间分桶的环形缓冲）
+type requestWindow struct {
+	mu      sync.Mutex
+	buckets [int(statusRequestWindow / statusRequestBucket)]requestBucket
+}
+
+type requestBucket struct {
+	slot   int64 // 分桶序号（Unix 时间 / 分桶粒度）
+	total  int64
+	errors int64
+}
+
+func bucketSlot(t time.Time) int64 {
+	return t.UnixNano() / int64(statusRequestBucket)
+}
+
+// record 记录一次请求
+func (rw *requestWindow) record(now time.Time, status int) {
+	slot := bucketSlot(now)
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	b := &rw.buckets[slot%int64(len(rw.buckets))]
+	if b.slot != slot {
+		*b = requestBucket{slot: slot}
+	}
+	b.total++
+	if status >= http.StatusInternalServerError {
+		b.errors++
+	}
+}
+
+// counts 统计窗口内的请求数与 5xx 响应数
+func (rw *requestWindow) counts(now time.Time) (total, errors int64) {
+	oldest := bucketSlot(now) - int64(len(rw.buckets)) + 1
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+	for _, b := range rw.buckets {
+		if b.slot >= oldest {
+			total += b.total
+			errors += b.errors
+		}
+	}
+	return total, errors
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+func TestGetSystemStatus(t *testing.T) {
+	store := newOverviewStore()
+	store.recent = map[model.RunStatus]int{
+		model.RunStatusDone: 15, model.RunStatusFailed: 4, model.RunStatusTimeout: 1, model.RunStatusCancelled: 2,
+	}
+	h := newTestHandler(store)
+	h.metrics = &Metrics{recent: &requestWindow{}}
+	now := time.Now()
+	for i := range 8 {
+		h.metrics.recent.record(now, http.StatusOK+i%4*100) // 200 / 300 / 400 / 500 各 2 次
+	}
+
+	w := httptest.NewRecorder()
+	h.GetSystemStatus(w, httptest.NewRequest("GET", "/api/v1/system/status", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var st SystemStatus
+	if err := json.Unmarshal(w.Body.Bytes(), &st); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if st.Status != "ok" || st.Components["database"].Status != "ok" || st.Components["minio"].Status != "disabled" {
+		t.Errorf("components = %+v, status = %s", st.Components, st.Status)
+	}
+	if st.Nodes.Online != 2 || st.Nodes.Offline != 1 || st.Scheduler.QueuedRuns != 3 {
+		t.Errorf("nodes = %+v, scheduler = %+v", st.Nodes, st.Scheduler)
+	}
+	r := st.ErrorRates
+	if r.Requests != 8 || r.ServerErrors != 2 || r.RequestErrorRate != 0.25 {
+		t.Errorf("request error rate = %+v", r)
+	}
+	if r.RunsFinished != 20 || r.RunsFailed != 5 || r.RunFailureRate != 0.25 {
+		t.Errorf("run failure rate = %+v", r)
+	}
+}
+
+func TestGetSystemStatus_Degraded(t *testing.T) {
+	store := newOverviewStore()
+	store.pingErr = errors.New("dial tcp 10.0.0.5:5432: connection refused")
+	h := newTestHandler(store)
+
+	get := func(role string) SystemStatus {
+		req := httptest.NewRequest("GET", "/api/v1/system/status", nil)
+		req = req.WithContext(auth.WithAuthUser(req.Context(), &auth.AuthUser{ID: "u1", Role: role}))
+		w := httptest.NewRecorder()
+		h.GetSystemStatus(w, req)
+		var st SystemStatus
+		json.Unmarshal(w.Body.Bytes(), &st)
+		return st
+	}
+	if st := get(auth.UserRoleAdmin); st.Status != "degraded" || st.Components["database"].Error != store.pingErr.Error() {
+		t.Errorf("admin: %+v", st)
+	}
+	// 非管理员不返回错误详情
+	if st := get("user"); st.Status != "degraded" || st.Components["database"].Error != "unavailable" {
+		t.Errorf("user: %+v", st)
+	}
+
+	// 有节点但全部离线
+	store.pingErr = nil
+	for _, n := range store.nodes {
+		n.LastHeartbeat = nil
+	}
+	if st := get(auth.UserRoleAdmin); st.Status != "degraded" || st.Nodes.Online != 0 {
+		t.Errorf("no online nodes: status = %s, nodes = %+v", st.Status, st.Nodes)
+	}
+}
+
+func TestRequestWindow_Expires(t *testing.T) {
+	var rw requestWindow
+	now := time.Now()
+	rw.record(now.Add(-statusRequestWindow-time.Minute), http.StatusInternalServerError)
+	rw.record(now.Add(-time.Minute), http.StatusBadGateway)
+	rw.record(now, http.StatusOK)
+	if total, errs := rw.counts(now); total != 2 || errs != 1 {
+		t.Errorf("counts = %d/%d, 期望 2/1", total, errs)
+	}
+}
@@ -0,0 +1,115 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/lifecycle"
+	"agents-admin/internal/apiserver/transcript"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// transcriptBatchSize 导出会话记录时分批读取事件的批大小
+const transcriptBatchSize = 500
+
+// ExportTranscript 流式导出 Run 的会话记录
+//
+// 路由: GET /api/v1/runs/{id}/transcript
+//
+// 路径参数:
+//   - id: Run ID
+//
+// 查询参数:
+//   - format: jsonl（默认）/ markdown / html
+//
+// 响应:
+//   - 200 OK: 以附件形式流式输出，任务提示词、Agent 消息、工具调用与结果、命令及输出按事件顺序排列
+//   - 400 Bad Request: 不支持的格式
+//   - 404 Not Found: Run 不存在
+//   - 410 Gone: 事件已按项目保留策略删除
+//   - 500 Internal Server Error: 服务器内部错误
+//
+// 使用场景：
+//   - 事后复盘时把会话记录分享给没有管理后台权限的人（html 为无外部资源的单个页面）
+//
+// 事件分批读取、逐批写出，导出长 Run 不占用大量内存；第一批事件读取成功后才写出响应头，
+// 之后读取失败时记录日志并截断输出。
+func (h *Handler) ExportTranscript(w http.ResponseWriter, r *http.Request) {
+	ctx := storage.WithReadReplica(r.Context())
+	runID := r.PathValue("id")
+	format := r.URL.Query().Get("format")
+
+	tw, err := transcript.NewWriter(format, w)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	run, err := h.store.GetRun(ctx, runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+
+	events, err := h.eventReader().GetEventsByRun(ctx, runID, 0, transcriptBatchSize)
+	if errors.Is(err, lifecycle.ErrEventsPurged) {
+		writeError(w, http.StatusGone, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get events")
+		return
+	}
+
+	contentType, ext := transcript.ContentType(format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="run-%s-transcript.%s"`, runID, ext))
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	rc := http.NewResponseController(w)
+
+	if err := writeTranscript(tw, run, events, func(fromSeq int) ([]*model.Event, error) {
+		rc.Flush()
+		return h.eventReader().GetEventsByRun(ctx, runID, fromSeq, transcriptBatchSize)
+	}); err != nil {
+		slog.ErrorContext(ctx, "transcript.export.truncated", "run_id", runID, "format", format, "error", err)
+		return
+	}
+	rc.Flush()
+}
+
+// writeTranscript 写出会话记录：first 为已读取的第一批事件，next 读取 fromSeq 之后的下一批
+func writeTranscript(tw transcript.Writer, run *model.Run, first []*model.Event, next func(fromSeq int) ([]*model.Event, error)) error {
+	if err := tw.Begin(transcript.NewHeader(run, time.Now())); err != nil {
+		return err
+	}
+	if prompt := transcript.PromptEntry(run); prompt != nil {
+		if err := tw.Write(*prompt); err != nil {
+			return err
+		}
+	}
+	for events := first; ; {
+		for _, e := range events {
+			for _, entry := range transcript.Entries(e) {
+				if err := tw.Write(entry); err != nil {
+					return err
+				}
+			}
+		}
+		if len(events) < transcriptBatchSize {
+			break
+		}
+		var err error
+		if events, err = next(events[len(events)-1].Seq); err != nil {
+			return err
+		}
+	}
+	return tw.End()
+}
@@ -0,0 +1,77 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// TestExportTranscript 跨批次流式导出会话记录，支持 jsonl / markdown / html
+func TestExportTranscript(t *testing.T) {
+	snapshot, _ := json.Marshal(map[string]interface{}{"name": "fix flaky test", "prompt": "Fix TestFoo", "agent": map[string]interface{}{"type": "qwen-code"}})
+	var events []*model.Event
+	for seq := 1; seq <= transcriptBatchSize+3; seq++ {
+		events = append(events, &model.Event{RunID: "run-1", Seq: seq, Type: "message", Payload: json.RawMessage(`{"content":"step"}`)})
+	}
+	events = append(events,
+		&model.Event{RunID: "run-1", Seq: transcriptBatchSize + 4, Type: "heartbeat"},
+		&model.Event{RunID: "run-1", Seq: transcriptBatchSize + 5, Type: "command", Payload: json.RawMessage(`{"command":"go","args":["test","./..."]}`)},
+		&model.Event{RunID: "run-1", Seq: transcriptBatchSize + 6, Type: "message", Payload: json.RawMessage(`{"content":"<script>alert(1)</script>"}`)},
+	)
+	store := &mockMonitorStore{
+		RunByID: map[string]*model.Run{"run-1": {ID: "run-1", TaskID: "task-1", Status: model.RunStatusDone, Snapshot: snapshot}},
+		Events:  map[string][]*model.Event{"run-1": events},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/runs/{id}/transcript", newTestHandler(store).ExportTranscript)
+	get := func(query string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/run-1/transcript"+query, nil))
+		return w
+	}
+
+	w := get("")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Content-Disposition"); got != `attachment; filename="run-run-1-transcript.jsonl"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	lines := strings.Split(strings.TrimSuffix(w.Body.String(), "\n"), "\n")
+	// 头部 + 提示词 + 全部消息 + 命令（心跳省略）
+	if want := 2 + transcriptBatchSize + 3 + 2; len(lines) != want {
+		t.Fatalf("导出 %d 行, 期望 %d 行", len(lines), want)
+	}
+	var header map[string]interface{}
+	json.Unmarshal([]byte(lines[0]), &header)
+	if header["kind"] != "run" || header["task_name"] != "fix flaky test" || header["agent_type"] != "qwen-code" {
+		t.Errorf("header = %v", header)
+	}
+	if !strings.Contains(lines[1], `"kind":"prompt"`) || !strings.Contains(lines[len(lines)-2], `"title":"go test ./..."`) {
+		t.Errorf("prompt = %s, command = %s", lines[1], lines[len(lines)-2])
+	}
+
+	if body := get("?format=markdown").Body.String(); !strings.HasPrefix(body, "# Transcript: fix flaky test") || !strings.Contains(body, "## Prompt\n\nFix TestFoo") {
+		t.Errorf("markdown = %.200s", body)
+	}
+	w = get("?format=html")
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if body := w.Body.String(); strings.Contains(body, "<script>") || !strings.HasSuffix(body, "</html>\n") {
+		t.Errorf("html 未转义或不完整")
+	}
+
+	if w := get("?format=pdf"); w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, 期望 400", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/missing/transcript", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, 期望 404", w.Code)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"sync"
@@ -106,7 +107,7 @@ func (g *EventGateway) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 	conn, err := upgrader.Upgrade(w, r, nil)
 	if err != nil {
-		log.Printf("WebSocket upgrade error: %v", err)
+		slog.WarnContext(r.Context(), "ws.upgrade.failed", "error", err)
 		return
 	}
 	defer conn.Close()
@@ -114,7 +115,7 @@ func (g *EventGateway) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 	g.addClient(runID, conn)
 	defer g.removeClient(runID, conn)
 
-	log.Printf("WebSocket client connected for run %s", runID)
+	slog.InfoContext(r.Context(), "ws.connected", "run_id", runID)
 
 	ctx, cancel := context.WithCancel(r.Context())
 	defer cancel()
@@ -236,7 +237,7 @@ func (g *EventGateway) writePump(ctx context.Context, conn *websocket.Conn, runI
 		case <-ticker.C:
 			events, err := g.store.GetEventsByRun(ctx, runID, lastSeq, 100)
 			if err != nil {
-				log.Printf("Failed to get events: %v", err)
+				slog.ErrorContext(ctx, "ws.events.failed", "run_id", runID, "error", err)
 				continue
 			}
 
@@ -247,7 +248,7 @@ func (g *EventGateway) writePump(ctx context.Context, conn *websocket.Conn, runI
 					"data": event,
 				}
 				if err := conn.WriteJSON(msg); err != nil {
-					log.Printf("WebSocket write error: %v", err)
+					slog.DebugContext(ctx, "ws.write.failed", "run_id", runID, "error", err)
 					return
 				}
 				if event.Seq > lastSeq {
@@ -297,7 +298,7 @@ func (g *EventGateway) writePumpEventBus(ctx context.Context, conn *websocket.Co
 					"data": event,
 				}
 				if err := conn.WriteJSON(msg); err != nil {
-					log.Printf("WebSocket write error: %v", err)
+					slog.DebugContext(ctx, "ws.write.failed", "run_id", runID, "error", err)
 					return
 				}
 			}
@@ -307,13 +308,13 @@ func (g *EventGateway) writePumpEventBus(ctx context.Context, conn *websocket.Co
 	// 订阅事件总线
 	eventCh, err := g.runEventBus.SubscribeRunEvents(ctx, runID)
 	if err != nil {
-		log.Printf("Failed to subscribe to event bus: %v", err)
+		slog.WarnContext(ctx, "ws.subscribe.failed", "run_id", runID, "error", err)
 		// 降级到轮询模式
 		g.writePump(ctx, conn, runID, fromSeq)
 		return
 	}
 
-	log.Printf("WebSocket using Redis Streams for run %s", runID)
+	slog.DebugContext(ctx, "ws.streams", "run_id", runID)
 
 	for {
 		select {
@@ -354,7 +355,7 @@ func (g *EventGateway) writePumpEventBus(ctx context.Context, conn *websocket.Co
 				},
 			}
 			if err := conn.WriteJSON(msg); err != nil {
-				log.Printf("WebSocket write error: %v", err)
+				slog.DebugContext(ctx, "ws.write.failed", "run_id", runID, "error", err)
 				return
 			}
 
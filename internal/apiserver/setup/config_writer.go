@@ -167,6 +167,7 @@ func writeEnvConfig(dir string, cfg ValidateRequest, jwtSecret string) error {
 	b.WriteString(fmt.Sprintf("JWT_SECRET=%s\n", jwtSecret))
 	b.WriteString(fmt.Sprintf("ADMIN_EMAIL=%s\n", cfg.Auth.AdminEmail))
 	b.WriteString(fmt.Sprintf("ADMIN_PASSWORD=%s\n", cfg.Auth.AdminPassword))
+	b.WriteString(fmt.Sprintf("MASTER_KEY=%s\n", generateRandomString(32)))
 
 	path := filepath.Join(dir, config.EnvFileName())
 	return os.WriteFile(path, []byte(b.String()), 0600)
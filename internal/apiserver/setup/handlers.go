@@ -29,6 +29,14 @@ func (s *Server) handleInfo(w http.ResponseWriter, r *http.Request) {
 		IPs:      getLocalIPs(),
 		IsRoot:   sysinstall.IsRoot(),
 	}
+	if s.airGapped {
+		resp.AirGapped = true
+		if path := sysinstall.FindOfflineManifest(); path != "" {
+			if m, err := sysinstall.LoadOfflineManifest(path); err == nil {
+				resp.OfflineVersion = m.Version
+			}
+		}
+	}
 	jsonResp(w, http.StatusOK, resp)
 }
 
@@ -70,7 +78,14 @@ func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
 		allValid = false
 	}
 
-	// 3. Auth 验证
+	// 3. TLS（离线模式下 ACME 无法访问 Let's Encrypt）
+	tlsCheck := validateTLS(req.TLS, s.airGapped)
+	checks["tls"] = tlsCheck
+	if !tlsCheck.OK {
+		allValid = false
+	}
+
+	// 4. Auth 验证
 	authCheck := validateAuth(req.Auth)
 	checks["auth"] = authCheck
 	if !authCheck.OK {
@@ -114,6 +129,14 @@ func (s *Server) handleApply(w http.ResponseWriter, r *http.Request) {
 	if req.Server.Port == "" {
 		req.Server.Port = "8080"
 	}
+	// 离线模式：ACME 不可用，回退为自签名证书
+	if s.airGapped && req.TLS.Mode == "acme" {
+		log.Printf("Air-gapped mode: ACME is unavailable, falling back to auto_generate TLS")
+		req.TLS.Mode = "auto_generate"
+		if req.TLS.Hosts == "" {
+			req.TLS.Hosts = req.TLS.AcmeDomains
+		}
+	}
 
 	// 生成 JWT Secret
 	jwtSecret := generateRandomString(32)
@@ -549,3 +549,38 @@ func TestTokenAuthMiddleware(t *testing.T) {
 		t.Fatalf("expected 200 with header token, got %d", w.Code)
 	}
 }
+
+func TestValidateTLS(t *testing.T) {
+	tests := []struct {
+		name      string
+		cfg       TLSConfig
+		airGapped bool
+		wantOK    bool
+	}{
+		{"disabled", TLSConfig{Enabled: false}, true, true},
+		{"auto_generate", TLSConfig{Enabled: true, Mode: "auto_generate"}, true, true},
+		{"acme online", TLSConfig{Enabled: true, Mode: "acme", AcmeDomains: "a.example.com"}, false, true},
+		{"acme without domains", TLSConfig{Enabled: true, Mode: "acme"}, false, false},
+		{"acme air-gapped", TLSConfig{Enabled: true, Mode: "acme", AcmeDomains: "a.example.com"}, true, false},
+		{"unknown mode", TLSConfig{Enabled: true, Mode: "bogus"}, false, false},
+	}
+	for _, tt := range tests {
+		r := validateTLS(tt.cfg, tt.airGapped)
+		if r.OK != tt.wantOK {
+			t.Errorf("%s: validateTLS = %v, want %v: %s", tt.name, r.OK, tt.wantOK, r.Message)
+		}
+	}
+}
+
+func TestHandleInfoAirGapped(t *testing.T) {
+	srv := &Server{configDir: "/tmp/test", token: "test", airGapped: true}
+	req := httptest.NewRequest(http.MethodGet, "/setup/api/info", nil)
+	w := httptest.NewRecorder()
+	srv.handleInfo(w, req)
+
+	var resp InfoResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if !resp.AirGapped {
+		t.Error("expected air_gapped=true")
+	}
+}
@@ -204,6 +204,10 @@ func (s *Server) handleDeployInfra(w http.ResponseWriter, r *http.Request) {
 			args = append(args, "compose")
 		}
 		args = append(args, "-f", composeFile, "--env-file", envFile, "up", "-d")
+		if s.airGapped {
+			// 离线模式：镜像已由 bootstrap 脚本 docker load，禁止联网拉取
+			args = append(args, "--pull", "never")
+		}
 
 		log.Printf("Running: %s %s", dockerCompose, strings.Join(args, " "))
 		cmd := exec.Command(dockerCompose, args...)
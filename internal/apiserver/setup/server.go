@@ -18,6 +18,8 @@ import (
 	"strings"
 	"syscall"
 	"time"
+
+	"agents-admin/internal/shared/sysinstall"
 )
 
 //go:embed static
@@ -30,6 +32,7 @@ type Server struct {
 	port       int
 	token      string
 	infra      infraState // 基础设施部署状态
+	airGapped  bool       // 离线安装模式：跳过 ACME 及外网检查，镜像从本地加载
 }
 
 // NewServer 创建配置向导服务器
@@ -39,6 +42,7 @@ func NewServer(configDir, listenAddr string, port int) *Server {
 		listenAddr: listenAddr,
 		port:       port,
 		token:      generateToken(),
+		airGapped:  sysinstall.IsAirGapped(),
 	}
 }
 
@@ -123,6 +127,9 @@ func (s *Server) printBanner() {
 	log.Println()
 	log.Printf("  Token: %s", s.token)
 	log.Printf("  Timeout: 30 minutes")
+	if s.airGapped {
+		log.Println("  Mode: air-gapped (ACME and external checks disabled)")
+	}
 	log.Println("====================================================")
 }
 
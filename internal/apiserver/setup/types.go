@@ -9,6 +9,10 @@ type InfoResponse struct {
 	Hostname string   `json:"hostname"`
 	IPs      []string `json:"ips"`
 	IsRoot   bool     `json:"is_root"`
+
+	// AirGapped 离线安装模式（前端据此隐藏 ACME 选项、跳过外网检查）
+	AirGapped      bool   `json:"air_gapped"`
+	OfflineVersion string `json:"offline_version,omitempty"` // 离线安装包版本
 }
 
 // ValidateRequest /setup/api/validate 请求
@@ -139,6 +139,26 @@ func testMongoDB(cfg DatabaseConfig) CheckResult {
 	return CheckResult{OK: true, Message: fmt.Sprintf("Connected to %s", addr)}
 }
 
+// validateTLS 校验 TLS 模式（离线模式下不允许 ACME）
+func validateTLS(cfg TLSConfig, airGapped bool) CheckResult {
+	if !cfg.Enabled {
+		return CheckResult{OK: true, Message: "TLS disabled"}
+	}
+	switch cfg.Mode {
+	case "acme":
+		if airGapped {
+			return CheckResult{OK: false, Message: "ACME is unavailable in air-gapped mode, use auto_generate or manual certificates"}
+		}
+		if strings.TrimSpace(cfg.AcmeDomains) == "" {
+			return CheckResult{OK: false, Message: "ACME domains are required"}
+		}
+	case "", "auto_generate", "manual":
+	default:
+		return CheckResult{OK: false, Message: fmt.Sprintf("Unknown TLS mode: %s", cfg.Mode)}
+	}
+	return CheckResult{OK: true, Message: "Valid"}
+}
+
 func validateAuth(cfg AuthConfig) CheckResult {
 	if cfg.AdminEmail == "" {
 		return CheckResult{OK: false, Message: "Admin email is required"}
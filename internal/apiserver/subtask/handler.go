@@ -0,0 +1,338 @@
+// Package subtask Agent 执行中派生子任务
+//
+// Agent 在执行过程中决定拆分工作时输出 spawn_subtask 事件，NodeManager 调用
+// POST /api/v1/runs/{id}/subtasks：API Server 创建子任务（parent_id 为 Run 所属任务，
+// spawned_by_run_id 为该 Run，继承父任务的 Agent 类型、工作空间、安全与调度配置）并立即创建子任务的 Run。
+//
+// 限制（Limits）：
+//   - 派生深度：顶层任务深度为 0，子任务深度为父任务深度 + 1，超过 MaxDepth 时拒绝
+//   - 子任务数量：单个 Run 派生的子任务数超过 MaxChildren 时拒绝
+//
+// 状态汇总：子任务的 Run 或派生它的 Run 到达终态时（RunFinished），父任务的状态由其最近一次 Run
+// 与该 Run 派生的全部子任务共同决定，并沿派生链逐级向上汇总（见 rollup.go）。
+package subtask
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agents-admin/internal/apiserver/idempotency"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// DefaultMaxDepth 默认最大派生深度
+	DefaultMaxDepth = 3
+	// DefaultMaxChildren 默认单个 Run 最多派生的子任务数
+	DefaultMaxChildren = 10
+)
+
+// Store 子任务派生需要的存储接口
+type Store interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run, error)
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	CreateTask(ctx context.Context, task *model.Task) error
+	ListSubTasks(ctx context.Context, parentID string) ([]*model.Task, error)
+	UpdateTaskStatus(ctx context.Context, id string, status model.TaskStatus) error
+}
+
+// RunStarter 为任务创建 Run 并加入调度队列（由 run.Handler 实现）
+type RunStarter interface {
+	StartRun(ctx context.Context, taskID string) (*model.Run, error)
+}
+
+// Limits 子任务派生限制，零值使用默认值
+type Limits struct {
+	MaxDepth    int // 最大派生深度（默认 DefaultMaxDepth）
+	MaxChildren int // 单个 Run 最多派生的子任务数（默认 DefaultMaxChildren）
+}
+
+func (l Limits) withDefaults() Limits {
+	if l.MaxDepth <= 0 {
+		l.MaxDepth = DefaultMaxDepth
+	}
+	if l.MaxChildren <= 0 {
+		l.MaxChildren = DefaultMaxChildren
+	}
+	return l
+}
+
+// Handler 子任务派生 HTTP 处理器
+type Handler struct {
+	store  Store
+	runs   RunStarter
+	limits Limits
+
+	idempotency *idempotency.Guard // 派生接口的幂等保护（可选，NodeManager 以 run_id 与事件序号作为 Idempotency-Key）
+
+	// mu 串行化派生请求，避免并发派生超出子任务数量限制
+	mu sync.Mutex
+}
+
+// NewHandler 创建子任务派生处理器
+func NewHandler(store Store, runs RunStarter, limits Limits) *Handler {
+	return &Handler{store: store, runs: runs, limits: limits.withDefaults()}
+}
+
+// SetIdempotency 设置派生接口的幂等保护（需在 RegisterRoutes 之前调用）
+func (h *Handler) SetIdempotency(g *idempotency.Guard) {
+	h.idempotency = g
+}
+
+// RegisterRoutes 注册子任务派生路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/runs/{id}/subtasks", h.idempotency.Wrap(h.Spawn))
+	mux.HandleFunc("GET /api/v1/runs/{id}/subtasks", h.List)
+}
+
+// SpawnRequest 派生子任务请求
+type SpawnRequest struct {
+	Name        string            `json:"name,omitempty"`        // 子任务名称（为空时为 "<父任务名称> / subtask N"）
+	Prompt      string            `json:"prompt"`                // 子任务提示词（必填）
+	Description string            `json:"description,omitempty"` // 子任务描述
+	Labels      map[string]string `json:"labels,omitempty"`      // 追加到继承自父任务的标签
+}
+
+// SpawnResponse 派生子任务响应
+type SpawnResponse struct {
+	Task  *model.Task `json:"task"`
+	Run   *model.Run  `json:"run"`
+	Depth int         `json:"depth"` // 子任务的派生深度
+}
+
+// SubTask 派生的子任务及其最近一次 Run
+type SubTask struct {
+	Task      *model.Task      `json:"task"`
+	RunID     string           `json:"run_id,omitempty"`
+	RunStatus model.RunStatus  `json:"run_status,omitempty"`
+	Status    model.TaskStatus `json:"status"`
+}
+
+// Spawn 为执行中的 Run 派生子任务并创建子任务的 Run（NodeManager 收到 spawn_subtask 事件时调用）
+// POST /api/v1/runs/{id}/subtasks
+//
+// 请求体: {"name": "...", "prompt": "...", "description": "...", "labels": {...}}（prompt 必填）
+//
+// 响应: {"task": {...}, "run": {...}, "depth": 1}；Run 已结束或超出派生限制时返回 409
+func (h *Handler) Spawn(w http.ResponseWriter, r *http.Request) {
+	var req SpawnRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if strings.TrimSpace(req.Prompt) == "" {
+		writeError(w, http.StatusBadRequest, "prompt is required")
+		return
+	}
+	run := h.getRun(w, r)
+	if run == nil {
+		return
+	}
+	if run.IsTerminal() {
+		writeError(w, http.StatusConflict, "run is already "+string(run.Status))
+		return
+	}
+
+	ctx := r.Context()
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	parent, err := h.store.GetTask(ctx, run.TaskID)
+	if err != nil || parent == nil {
+		slog.ErrorContext(ctx, "subtask.spawn.parent.failed", "run_id", run.ID, "task_id", run.TaskID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get parent task")
+		return
+	}
+	depth, err := h.depth(ctx, parent)
+	if err != nil {
+		slog.ErrorContext(ctx, "subtask.spawn.depth.failed", "run_id", run.ID, "task_id", parent.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to resolve subtask depth")
+		return
+	}
+	depth++
+	if depth > h.limits.MaxDepth {
+		writeError(w, http.StatusConflict, "subtask depth limit exceeded (max_depth "+strconv.Itoa(h.limits.MaxDepth)+")")
+		return
+	}
+	children, err := h.spawnedBy(ctx, parent.ID, run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "subtask.spawn.children.failed", "run_id", run.ID, "task_id", parent.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list subtasks")
+		return
+	}
+	if len(children) >= h.limits.MaxChildren {
+		writeError(w, http.StatusConflict, "subtask limit exceeded (max_children "+strconv.Itoa(h.limits.MaxChildren)+")")
+		return
+	}
+
+	task := newChildTask(parent, run, &req, len(children)+1)
+	if err := h.store.CreateTask(ctx, task); err != nil {
+		slog.ErrorContext(ctx, "subtask.spawn.create.failed", "run_id", run.ID, "task_id", task.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create subtask")
+		return
+	}
+	child, err := h.runs.StartRun(ctx, task.ID)
+	if err != nil {
+		// 子任务已创建：标记失败（参与父任务的状态汇总），避免以待处理状态残留
+		slog.ErrorContext(ctx, "subtask.spawn.run.failed", "run_id", run.ID, "task_id", task.ID, "error", err)
+		if err := h.store.UpdateTaskStatus(ctx, task.ID, model.TaskStatusFailed); err != nil {
+			slog.ErrorContext(ctx, "subtask.spawn.status.failed", "task_id", task.ID, "error", err)
+		}
+		writeError(w, http.StatusInternalServerError, "failed to start subtask run")
+		return
+	}
+
+	slog.InfoContext(ctx, "subtask.spawn.success", "run_id", run.ID, "parent_task_id", parent.ID, "task_id", task.ID, "child_run_id", child.ID, "depth", depth)
+	writeJSON(w, http.StatusCreated, &SpawnResponse{Task: task, Run: child, Depth: depth})
+}
+
+// List 列出 Run 派生的子任务及其最近一次 Run 的状态
+// GET /api/v1/runs/{id}/subtasks
+//
+// 响应: {"subtasks": [{"task": {...}, "run_id": "run-...", "run_status": "running", "status": "in_progress"}], "total": 1, "status": "in_progress"}
+// 其中顶层 status 为 Run 与子任务汇总后的状态
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	run := h.getRun(w, r)
+	if run == nil {
+		return
+	}
+	ctx := r.Context()
+	children, err := h.spawnedBy(ctx, run.TaskID, run.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "subtask.list.failed", "run_id", run.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list subtasks")
+		return
+	}
+	list := make([]*SubTask, 0, len(children))
+	statuses := []model.TaskStatus{taskStatusOf(run.Status)}
+	for _, c := range children {
+		item := &SubTask{Task: c, Status: c.Status}
+		if latest, err := h.latestRun(ctx, c.ID); err == nil && latest != nil {
+			item.RunID, item.RunStatus = latest.ID, latest.Status
+		}
+		list = append(list, item)
+		statuses = append(statuses, c.Status)
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"subtasks": list,
+		"total":    len(list),
+		"status":   aggregate(statuses),
+	})
+}
+
+// depth 计算任务的派生深度（沿 spawned_by_run_id 向上查找，超过 MaxDepth 时提前结束）
+func (h *Handler) depth(ctx context.Context, task *model.Task) (int, error) {
+	depth := 0
+	for task.SpawnedByRunID != nil && task.ParentID != nil && depth <= h.limits.MaxDepth {
+		parent, err := h.store.GetTask(ctx, *task.ParentID)
+		if err != nil {
+			return 0, err
+		}
+		depth++
+		if parent == nil {
+			break
+		}
+		task = parent
+	}
+	return depth, nil
+}
+
+// spawnedBy 列出任务下由指定 Run 派生的子任务（同一父任务下工作流等其他方式创建的子任务不计入）
+func (h *Handler) spawnedBy(ctx context.Context, taskID, runID string) ([]*model.Task, error) {
+	subtasks, err := h.store.ListSubTasks(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var children []*model.Task
+	for _, t := range subtasks {
+		if t.SpawnedByRunID != nil && *t.SpawnedByRunID == runID {
+			children = append(children, t)
+		}
+	}
+	return children, nil
+}
+
+// newChildTask 由父任务与派生请求构造子任务（继承父任务的执行配置，不继承上下文）
+func newChildTask(parent *model.Task, run *model.Run, req *SpawnRequest, n int) *model.Task {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		name = parent.Name + " / subtask " + strconv.Itoa(n)
+	}
+	var labels map[string]string
+	if len(parent.Labels) > 0 || len(req.Labels) > 0 {
+		labels = make(map[string]string, len(parent.Labels)+len(req.Labels))
+		for k, v := range parent.Labels {
+			labels[k] = v
+		}
+		for k, v := range req.Labels {
+			labels[k] = v
+		}
+	}
+	parentID, runID := parent.ID, run.ID
+	now := time.Now()
+	return &model.Task{
+		ID:             generateID("task"),
+		Name:           name,
+		Description:    req.Description,
+		Status:         model.TaskStatusPending,
+		Type:           parent.Type,
+		Prompt:         &model.Prompt{Content: req.Prompt},
+		Workspace:      parent.Workspace,
+		Security:       parent.Security,
+		Labels:         labels,
+		Hooks:          parent.Hooks,
+		Secrets:        parent.Secrets,
+		Priority:       parent.Priority,
+		TimeoutSeconds: parent.TimeoutSeconds,
+		Scheduling:     parent.Scheduling,
+		Region:         parent.Region,
+		TemplateID:     parent.TemplateID,
+		AgentID:        parent.AgentID,
+		ProjectID:      parent.ProjectID,
+		ParentID:       &parentID,
+		SpawnedByRunID: &runID,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// getRun 读取路径中的 Run，失败时写入错误响应并返回 nil
+func (h *Handler) getRun(w http.ResponseWriter, r *http.Request) *model.Run {
+	id := r.PathValue("id")
+	run, err := h.store.GetRun(r.Context(), id)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "subtask.run.failed", "run_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return nil
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return nil
+	}
+	return run
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
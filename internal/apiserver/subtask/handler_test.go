@@ -0,0 +1,226 @@
+package subtask
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存任务与 Run 存储，同时实现 RunStarter
+type memStore struct {
+	tasks map[string]*model.Task
+	runs  map[string]*model.Run
+	n     int
+}
+
+func newMemStore() *memStore {
+	return &memStore{tasks: map[string]*model.Task{}, runs: map[string]*model.Run{}}
+}
+
+func (m *memStore) GetRun(_ context.Context, id string) (*model.Run, error) { return m.runs[id], nil }
+func (m *memStore) ListRunsByTask(_ context.Context, taskID string) ([]*model.Run, error) {
+	var list []*model.Run
+	for _, r := range m.runs {
+		if r.TaskID == taskID {
+			list = append(list, r)
+		}
+	}
+	return list, nil
+}
+func (m *memStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	if t, ok := m.tasks[id]; ok {
+		copied := *t
+		return &copied, nil
+	}
+	return nil, nil
+}
+func (m *memStore) CreateTask(_ context.Context, t *model.Task) error {
+	copied := *t
+	m.tasks[t.ID] = &copied
+	return nil
+}
+func (m *memStore) ListSubTasks(_ context.Context, parentID string) ([]*model.Task, error) {
+	var list []*model.Task
+	for _, t := range m.tasks {
+		if t.ParentID != nil && *t.ParentID == parentID {
+			copied := *t
+			list = append(list, &copied)
+		}
+	}
+	return list, nil
+}
+func (m *memStore) UpdateTaskStatus(_ context.Context, id string, status model.TaskStatus) error {
+	m.tasks[id].Status = status
+	return nil
+}
+func (m *memStore) StartRun(_ context.Context, taskID string) (*model.Run, error) {
+	m.n++
+	run := &model.Run{ID: "run-" + taskID, TaskID: taskID, Status: model.RunStatusQueued, CreatedAt: time.Now().Add(time.Duration(m.n))}
+	m.runs[run.ID] = run
+	return run, nil
+}
+
+// finish 模拟 run.Handler：写入 Run 终态与任务状态后调用 RunFinished
+func (m *memStore) finish(h *Handler, runID string, status model.RunStatus) {
+	run := m.runs[runID]
+	run.Status = status
+	m.tasks[run.TaskID].Status = taskStatusOf(status)
+	h.RunFinished(run)
+}
+
+func newTestHandler(limits Limits) (*Handler, *memStore, *http.ServeMux) {
+	store := newMemStore()
+	store.tasks["task-root"] = &model.Task{
+		ID: "task-root", Name: "root", Status: model.TaskStatusInProgress, Type: "qwen-code",
+		Labels: map[string]string{"team": "a"}, Region: "cn-east",
+	}
+	store.runs["run-root"] = &model.Run{ID: "run-root", TaskID: "task-root", Status: model.RunStatusRunning}
+	h := NewHandler(store, store, limits)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	return h, store, mux
+}
+
+func spawn(t *testing.T, mux *http.ServeMux, runID, body string) (*httptest.ResponseRecorder, *SpawnResponse) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/runs/"+runID+"/subtasks", strings.NewReader(body)))
+	var resp SpawnResponse
+	if w.Code == http.StatusCreated {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return w, &resp
+}
+
+func TestHandler_Spawn(t *testing.T) {
+	_, store, mux := newTestHandler(Limits{})
+	w, resp := spawn(t, mux, "run-root", `{"prompt": "write tests", "labels": {"kind": "test"}}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d: %s", w.Code, w.Body.String())
+	}
+	task := resp.Task
+	if resp.Depth != 1 || resp.Run == nil || resp.Run.TaskID != task.ID {
+		t.Fatalf("response = %+v", resp)
+	}
+	if task.ParentID == nil || *task.ParentID != "task-root" || task.SpawnedByRunID == nil || *task.SpawnedByRunID != "run-root" {
+		t.Errorf("links = parent %v spawned_by %v", task.ParentID, task.SpawnedByRunID)
+	}
+	if task.Name != "root / subtask 1" || task.Type != "qwen-code" || task.Region != "cn-east" || task.GetPromptContent() != "write tests" {
+		t.Errorf("task = %+v", task)
+	}
+	if task.Labels["team"] != "a" || task.Labels["kind"] != "test" {
+		t.Errorf("labels = %v", task.Labels)
+	}
+	if store.tasks["task-root"].Labels["kind"] != "" {
+		t.Error("父任务的标签不应被修改")
+	}
+
+	tests := []struct {
+		name  string
+		runID string
+		body  string
+		want  int
+	}{
+		{"缺少 prompt", "run-root", `{"name": "x"}`, http.StatusBadRequest},
+		{"Run 不存在", "run-missing", `{"prompt": "x"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if w, _ := spawn(t, mux, tt.runID, tt.body); w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+		})
+	}
+
+	store.runs["run-root"].Status = model.RunStatusDone
+	if w, _ := spawn(t, mux, "run-root", `{"prompt": "x"}`); w.Code != http.StatusConflict {
+		t.Errorf("已结束的 Run: status = %d, want 409", w.Code)
+	}
+}
+
+func TestHandler_SpawnLimits(t *testing.T) {
+	_, _, mux := newTestHandler(Limits{MaxDepth: 2, MaxChildren: 2})
+	_, first := spawn(t, mux, "run-root", `{"prompt": "a"}`)
+	if w, _ := spawn(t, mux, "run-root", `{"prompt": "b"}`); w.Code != http.StatusCreated {
+		t.Fatalf("第二个子任务 status = %d", w.Code)
+	}
+	if w, _ := spawn(t, mux, "run-root", `{"prompt": "c"}`); w.Code != http.StatusConflict {
+		t.Errorf("超出 max_children: status = %d, want 409", w.Code)
+	}
+
+	_, second := spawn(t, mux, first.Run.ID, `{"prompt": "a.1"}`)
+	if second.Depth != 2 {
+		t.Fatalf("depth = %d, want 2", second.Depth)
+	}
+	if w, _ := spawn(t, mux, second.Run.ID, `{"prompt": "a.1.1"}`); w.Code != http.StatusConflict {
+		t.Errorf("超出 max_depth: status = %d, want 409", w.Code)
+	}
+}
+
+func TestHandler_RunFinishedRollup(t *testing.T) {
+	h, store, mux := newTestHandler(Limits{})
+	_, a := spawn(t, mux, "run-root", `{"prompt": "a"}`)
+	_, b := spawn(t, mux, "run-root", `{"prompt": "b"}`)
+	_, a1 := spawn(t, mux, a.Run.ID, `{"prompt": "a.1"}`)
+
+	// 父任务的 Run 先结束：子任务仍在执行，父任务保持 in_progress
+	store.finish(h, "run-root", model.RunStatusDone)
+	if got := store.tasks["task-root"].Status; got != model.TaskStatusInProgress {
+		t.Fatalf("root status = %s, want in_progress", got)
+	}
+
+	store.finish(h, a.Run.ID, model.RunStatusDone)
+	store.finish(h, b.Run.ID, model.RunStatusDone)
+	if got := store.tasks[a.Task.ID].Status; got != model.TaskStatusInProgress {
+		t.Errorf("a status = %s, want in_progress（a.1 未结束）", got)
+	}
+	if got := store.tasks["task-root"].Status; got != model.TaskStatusInProgress {
+		t.Errorf("root status = %s, want in_progress", got)
+	}
+
+	// 孙任务失败：沿派生链汇总到顶层任务
+	store.finish(h, a1.Run.ID, model.RunStatusFailed)
+	if got := store.tasks[a.Task.ID].Status; got != model.TaskStatusFailed {
+		t.Errorf("a status = %s, want failed", got)
+	}
+	if got := store.tasks["task-root"].Status; got != model.TaskStatusFailed {
+		t.Errorf("root status = %s, want failed", got)
+	}
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/runs/run-root/subtasks", nil))
+	var list struct {
+		Total  int              `json:"total"`
+		Status model.TaskStatus `json:"status"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if w.Code != http.StatusOK || list.Total != 2 || list.Status != model.TaskStatusFailed {
+		t.Errorf("list = %d %+v", w.Code, list)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	tests := []struct {
+		statuses []model.TaskStatus
+		want     model.TaskStatus
+	}{
+		{[]model.TaskStatus{"completed", "completed"}, "completed"},
+		{[]model.TaskStatus{"completed", "pending"}, "in_progress"},
+		{[]model.TaskStatus{"failed", "in_progress"}, "in_progress"},
+		{[]model.TaskStatus{"cancelled", "failed"}, "failed"},
+		{[]model.TaskStatus{"completed", "cancelled"}, "cancelled"},
+	}
+	for _, tt := range tests {
+		if got := aggregate(tt.statuses); got != tt.want {
+			t.Errorf("aggregate(%v) = %s, want %s", tt.statuses, got, tt.want)
+		}
+	}
+}
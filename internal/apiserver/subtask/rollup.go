@@ -0,0 +1,125 @@
+package subtask
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// rollupTimeout 单次状态汇总的超时时间
+const rollupTimeout = 10 * time.Second
+
+// RunFinished Run 到达终态时汇总父任务状态（注册到 run.Handler.OnRunFinished）
+//
+// run.Handler 先按 Run 的终态写入其任务状态，随后调用本回调：任务的最近一次 Run 派生过子任务时，
+// 任务状态改为与子任务的汇总状态；任务本身是派生的子任务时，沿派生链逐级汇总父任务。
+// 同步执行，保证同一任务的多次汇总按 Run 结束的顺序写入。
+func (h *Handler) RunFinished(run *model.Run) {
+	ctx, cancel := context.WithTimeout(context.Background(), rollupTimeout)
+	defer cancel()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	task, err := h.store.GetTask(ctx, run.TaskID)
+	if err != nil {
+		slog.ErrorContext(ctx, "subtask.rollup.failed", "run_id", run.ID, "task_id", run.TaskID, "error", err)
+		return
+	}
+	// 派生链长度受 MaxDepth 限制，循环上限防止数据异常时无限向上查找
+	for i := 0; task != nil && i <= h.limits.MaxDepth; i++ {
+		if err := h.rollup(ctx, task); err != nil {
+			slog.ErrorContext(ctx, "subtask.rollup.failed", "run_id", run.ID, "task_id", task.ID, "error", err)
+			return
+		}
+		if task.SpawnedByRunID == nil || task.ParentID == nil {
+			return
+		}
+		parentID := *task.ParentID
+		if task, err = h.store.GetTask(ctx, parentID); err != nil {
+			slog.ErrorContext(ctx, "subtask.rollup.failed", "run_id", run.ID, "task_id", parentID, "error", err)
+			return
+		}
+	}
+}
+
+// rollup 按任务最近一次 Run 与其派生的子任务汇总任务状态（最近一次 Run 未派生子任务时保持不变）
+func (h *Handler) rollup(ctx context.Context, task *model.Task) error {
+	latest, err := h.latestRun(ctx, task.ID)
+	if err != nil || latest == nil {
+		return err
+	}
+	children, err := h.spawnedBy(ctx, task.ID, latest.ID)
+	if err != nil || len(children) == 0 {
+		return err
+	}
+	statuses := []model.TaskStatus{taskStatusOf(latest.Status)}
+	for _, c := range children {
+		statuses = append(statuses, c.Status)
+	}
+	status := aggregate(statuses)
+	if status == task.Status {
+		return nil
+	}
+	if err := h.store.UpdateTaskStatus(ctx, task.ID, status); err != nil {
+		return err
+	}
+	slog.InfoContext(ctx, "subtask.rollup.updated", "task_id", task.ID, "run_id", latest.ID, "children", len(children), "status", status)
+	task.Status = status
+	return nil
+}
+
+// latestRun 返回任务最近创建的 Run（没有 Run 时返回 nil）
+func (h *Handler) latestRun(ctx context.Context, taskID string) (*model.Run, error) {
+	runs, err := h.store.ListRunsByTask(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	var latest *model.Run
+	for _, r := range runs {
+		if latest == nil || r.CreatedAt.After(latest.CreatedAt) {
+			latest = r
+		}
+	}
+	return latest, nil
+}
+
+// taskStatusOf 将 Run 状态映射为任务状态（未结束的 Run 视为 in_progress）
+func taskStatusOf(status model.RunStatus) model.TaskStatus {
+	switch status {
+	case model.RunStatusDone:
+		return model.TaskStatusCompleted
+	case model.RunStatusFailed, model.RunStatusTimeout:
+		return model.TaskStatusFailed
+	case model.RunStatusCancelled:
+		return model.TaskStatusCancelled
+	default:
+		return model.TaskStatusInProgress
+	}
+}
+
+// aggregate 汇总状态：任一未结束为 in_progress，否则任一失败为 failed，否则任一取消为 cancelled，否则 completed
+func aggregate(statuses []model.TaskStatus) model.TaskStatus {
+	var failed, cancelled bool
+	for _, s := range statuses {
+		switch s {
+		case model.TaskStatusCompleted:
+		case model.TaskStatusFailed:
+			failed = true
+		case model.TaskStatusCancelled:
+			cancelled = true
+		default:
+			return model.TaskStatusInProgress
+		}
+	}
+	switch {
+	case failed:
+		return model.TaskStatusFailed
+	case cancelled:
+		return model.TaskStatusCancelled
+	default:
+		return model.TaskStatusCompleted
+	}
+}
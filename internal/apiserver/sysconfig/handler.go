@@ -6,7 +6,7 @@ package sysconfig
 import (
 	"encoding/json"
 	"io"
-	"log"
+	"log/slog"
 	"net/http"
 
 	"agents-admin/internal/config"
@@ -107,12 +107,12 @@ func (h *Handler) UpdateConfig(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	log.Printf("Config file updated: %s", path)
+	slog.InfoContext(r.Context(), "sysconfig.updated", "path", path)
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success":   true,
 		"file_path": path,
-		"message":   "Configuration saved. Restart the service to apply changes.",
+		"message":   "Configuration saved. Hot-reloadable settings apply automatically; restart the service to apply other changes.",
 	})
 }
@@ -0,0 +1,172 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/shared/model"
+)
+
+// MaxBulkItems 单次批量操作的任务数上限
+const MaxBulkItems = 500
+
+// 批量操作类型
+const (
+	BulkActionCreate = "create" // 创建任务（可选创建后立即启动 Run）
+	BulkActionCancel = "cancel" // 取消任务的活跃 Run
+	BulkActionDelete = "delete" // 删除任务及其 Run 记录
+	BulkActionRerun  = "rerun"  // 为任务启动一次新的 Run
+)
+
+// BulkRequest 批量操作的请求体（使用 OpenAPI 生成的类型）
+type BulkRequest = openapi.BulkTaskRequest
+
+// BulkResult 批量操作中单项的结果
+type BulkResult = openapi.BulkTaskResult
+
+// Runs 启动与取消任务的 Run（由 run.Handler 实现，未设置时批量 cancel / rerun 与 create 的 start 不可用）
+type Runs interface {
+	StartRun(ctx context.Context, taskID string) (*model.Run, error)
+	CancelTask(ctx context.Context, taskID string) ([]string, error)
+}
+
+// SetRuns 设置 Run 的启动与取消实现
+func (h *Handler) SetRuns(runs Runs) {
+	h.runs = runs
+}
+
+// Bulk 批量操作任务
+// POST /api/v1/tasks/bulk
+//
+// 每项独立执行并各自返回结果，单项失败不影响其他项；单项内部是原子的（如 create+start 启动失败时撤销已创建的任务）。
+func (h *Handler) Bulk(w http.ResponseWriter, r *http.Request) {
+	var req BulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	var n int
+	switch req.Action {
+	case BulkActionCreate:
+		if req.Tasks != nil {
+			n = len(*req.Tasks)
+		}
+	case BulkActionCancel, BulkActionDelete, BulkActionRerun:
+		if req.TaskIds != nil {
+			n = len(*req.TaskIds)
+		}
+	default:
+		writeError(w, http.StatusBadRequest, "action must be one of create, cancel, delete, rerun")
+		return
+	}
+	if n == 0 {
+		writeError(w, http.StatusBadRequest, "no tasks given")
+		return
+	}
+	if n > MaxBulkItems {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Sprintf("at most %d tasks per request", MaxBulkItems))
+		return
+	}
+	start := req.Start != nil && *req.Start
+	needRuns := req.Action == BulkActionCancel || req.Action == BulkActionRerun || (req.Action == BulkActionCreate && start)
+	if needRuns && h.runs == nil {
+		writeError(w, http.StatusNotImplemented, "runs not supported")
+		return
+	}
+
+	ctx := r.Context()
+	results := make([]BulkResult, n)
+	for i := range results {
+		var res BulkResult
+		if req.Action == BulkActionCreate {
+			res = h.bulkCreate(ctx, &(*req.Tasks)[i], start)
+		} else {
+			res = h.bulkApply(ctx, req.Action, (*req.TaskIds)[i])
+			res.TaskId = &(*req.TaskIds)[i]
+		}
+		res.Index = i
+		results[i] = res
+	}
+
+	resp := openapi.BulkTaskResponse{Action: req.Action, Results: results}
+	for _, res := range results {
+		if res.Status < 300 {
+			resp.Succeeded++
+		} else {
+			resp.Failed++
+		}
+	}
+	slog.InfoContext(ctx, "task.bulk", "action", req.Action, "total", n, "succeeded", resp.Succeeded, "failed", resp.Failed)
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// bulkCreate 创建单个任务，start 时立即启动 Run，启动失败则删除已创建的任务
+func (h *Handler) bulkCreate(ctx context.Context, req *CreateRequest, start bool) BulkResult {
+	task, err := h.createTask(ctx, req)
+	if err != nil {
+		ce := err.(*createError)
+		return bulkError(ce.status, ce.message)
+	}
+	res := BulkResult{Status: http.StatusCreated, TaskId: &task.ID}
+	if !start {
+		return res
+	}
+	run, err := h.runs.StartRun(ctx, task.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "task.bulk.start_failed", "task_id", task.ID, "error", err)
+		if err := h.store.DeleteTask(ctx, task.ID); err != nil {
+			slog.ErrorContext(ctx, "task.bulk.rollback_failed", "task_id", task.ID, "error", err)
+		}
+		return bulkError(http.StatusInternalServerError, "failed to start run")
+	}
+	res.RunId = &run.ID
+	return res
+}
+
+// bulkApply 对已有任务执行 cancel / delete / rerun（结果中的 task_id 由调用方填写）
+func (h *Handler) bulkApply(ctx context.Context, action, taskID string) BulkResult {
+	res := BulkResult{Status: http.StatusOK}
+	task, err := h.store.GetTask(ctx, taskID)
+	if err != nil {
+		return bulkError(http.StatusInternalServerError, "failed to get task")
+	}
+	if task == nil {
+		return bulkError(http.StatusNotFound, "task not found")
+	}
+
+	switch action {
+	case BulkActionCancel:
+		cancelled, err := h.runs.CancelTask(ctx, taskID)
+		if err != nil {
+			slog.ErrorContext(ctx, "task.bulk.cancel_failed", "task_id", taskID, "error", err)
+			return bulkError(http.StatusInternalServerError, "failed to cancel task")
+		}
+		if cancelled == nil {
+			cancelled = []string{}
+		}
+		res.CancelledRuns = &cancelled
+	case BulkActionDelete:
+		if err := h.store.DeleteTask(ctx, taskID); err != nil {
+			slog.ErrorContext(ctx, "task.bulk.delete_failed", "task_id", taskID, "error", err)
+			return bulkError(http.StatusInternalServerError, "failed to delete task")
+		}
+	case BulkActionRerun:
+		run, err := h.runs.StartRun(ctx, taskID)
+		if err != nil {
+			slog.ErrorContext(ctx, "task.bulk.rerun_failed", "task_id", taskID, "error", err)
+			return bulkError(http.StatusInternalServerError, "failed to start run")
+		}
+		res.Status = http.StatusCreated
+		res.RunId = &run.ID
+	}
+	return res
+}
+
+func bulkError(status int, message string) BulkResult {
+	return BulkResult{Status: status, Error: &message}
+}
@@ -0,0 +1,170 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// memTaskStore 内存任务存储（仅实现批量操作用到的方法）
+type memTaskStore struct {
+	storage.TaskStore
+	tasks map[string]*model.Task
+}
+
+func (m *memTaskStore) CreateTask(_ context.Context, task *model.Task) error {
+	m.tasks[task.ID] = task
+	return nil
+}
+
+func (m *memTaskStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	return m.tasks[id], nil
+}
+
+func (m *memTaskStore) DeleteTask(_ context.Context, id string) error {
+	delete(m.tasks, id)
+	return nil
+}
+
+// fakeRuns 记录启动与取消调用，failStart 时启动失败
+type fakeRuns struct {
+	started   []string
+	failStart bool
+}
+
+func (f *fakeRuns) StartRun(_ context.Context, taskID string) (*model.Run, error) {
+	if f.failStart {
+		return nil, errors.New("queue unavailable")
+	}
+	f.started = append(f.started, taskID)
+	return &model.Run{ID: "run-" + taskID, TaskID: taskID}, nil
+}
+
+func (f *fakeRuns) CancelTask(_ context.Context, taskID string) ([]string, error) {
+	return []string{"run-" + taskID}, nil
+}
+
+func postBulk(t *testing.T, h *Handler, body string) (int, openapi.BulkTaskResponse) {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/bulk", strings.NewReader(body)))
+	var resp openapi.BulkTaskResponse
+	if w.Code == http.StatusOK {
+		if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("解析响应失败: %v", err)
+		}
+	}
+	return w.Code, resp
+}
+
+// TestBulk_CreateAndStart 逐项创建，单项失败不影响其他项
+func TestBulk_CreateAndStart(t *testing.T) {
+	store := &memTaskStore{tasks: map[string]*model.Task{}}
+	runs := &fakeRuns{}
+	h := NewHandler(store)
+	h.SetRuns(runs)
+
+	code, resp := postBulk(t, h, `{"action":"create","start":true,"tasks":[
+		{"name":"review-1","prompt":"review"},
+		{"name":"","prompt":"missing name"},
+		{"name":"review-3","prompt":"review"}]}`)
+	if code != http.StatusOK {
+		t.Fatalf("状态码 = %d, 期望 200", code)
+	}
+	if resp.Succeeded != 2 || resp.Failed != 1 {
+		t.Fatalf("succeeded=%d failed=%d, 期望 2/1", resp.Succeeded, resp.Failed)
+	}
+	if r := resp.Results[1]; r.Index != 1 || r.Status != http.StatusBadRequest || r.Error == nil {
+		t.Errorf("第 2 项 = %+v, 期望 400", r)
+	}
+	for _, i := range []int{0, 2} {
+		r := resp.Results[i]
+		if r.Status != http.StatusCreated || r.TaskId == nil || r.RunId == nil {
+			t.Errorf("第 %d 项 = %+v, 期望创建并启动", i+1, r)
+		}
+	}
+	if len(store.tasks) != 2 || len(runs.started) != 2 {
+		t.Errorf("tasks=%d started=%d, 期望 2/2", len(store.tasks), len(runs.started))
+	}
+}
+
+// TestBulk_StartFailureRollsBack 启动 Run 失败时撤销该项已创建的任务
+func TestBulk_StartFailureRollsBack(t *testing.T) {
+	store := &memTaskStore{tasks: map[string]*model.Task{}}
+	h := NewHandler(store)
+	h.SetRuns(&fakeRuns{failStart: true})
+
+	_, resp := postBulk(t, h, `{"action":"create","start":true,"tasks":[{"name":"a","prompt":"p"}]}`)
+	if resp.Failed != 1 || resp.Results[0].Status != http.StatusInternalServerError {
+		t.Fatalf("结果 = %+v, 期望失败", resp.Results)
+	}
+	if len(store.tasks) != 0 {
+		t.Errorf("残留任务 %d 个, 期望 0", len(store.tasks))
+	}
+}
+
+// TestBulk_ApplyToExisting cancel / delete / rerun 对不存在的任务逐项返回 404
+func TestBulk_ApplyToExisting(t *testing.T) {
+	store := &memTaskStore{tasks: map[string]*model.Task{
+		"task-1": {ID: "task-1"},
+		"task-2": {ID: "task-2"},
+	}}
+	runs := &fakeRuns{}
+	h := NewHandler(store)
+	h.SetRuns(runs)
+
+	_, resp := postBulk(t, h, `{"action":"rerun","task_ids":["task-1","task-x"]}`)
+	if resp.Succeeded != 1 || *resp.Results[0].RunId != "run-task-1" || resp.Results[1].Status != http.StatusNotFound {
+		t.Errorf("rerun 结果 = %+v", resp.Results)
+	}
+	if *resp.Results[1].TaskId != "task-x" {
+		t.Errorf("失败项 task_id = %v, 期望 task-x", *resp.Results[1].TaskId)
+	}
+
+	_, resp = postBulk(t, h, `{"action":"cancel","task_ids":["task-2"]}`)
+	if r := resp.Results[0]; r.CancelledRuns == nil || len(*r.CancelledRuns) != 1 {
+		t.Errorf("cancel 结果 = %+v", r)
+	}
+
+	_, resp = postBulk(t, h, `{"action":"delete","task_ids":["task-1","task-2"]}`)
+	if resp.Succeeded != 2 || len(store.tasks) != 0 {
+		t.Errorf("delete succeeded=%d 剩余=%d", resp.Succeeded, len(store.tasks))
+	}
+}
+
+// TestBulk_Validation 请求级错误
+func TestBulk_Validation(t *testing.T) {
+	h := NewHandler(&memTaskStore{tasks: map[string]*model.Task{}})
+	ids := make([]string, MaxBulkItems+1)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("%q", fmt.Sprint("task-", i))
+	}
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"未知操作", `{"action":"archive","task_ids":["t"]}`, http.StatusBadRequest},
+		{"空列表", `{"action":"delete","task_ids":[]}`, http.StatusBadRequest},
+		{"超过上限", `{"action":"delete","task_ids":[` + strings.Join(ids, ",") + `]}`, http.StatusRequestEntityTooLarge},
+		{"未配置 Run", `{"action":"rerun","task_ids":["t"]}`, http.StatusNotImplemented},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if code, _ := postBulk(t, h, tt.body); code != tt.want {
+				t.Errorf("状态码 = %d, 期望 %d", code, tt.want)
+			}
+		})
+	}
+}
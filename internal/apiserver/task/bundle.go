@@ -0,0 +1,240 @@
+package task
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/shared/model"
+)
+
+// 任务导出文档的版本与类型标识
+const (
+	BundleAPIVersion = "agents-admin/v1"
+	BundleKind       = "Task"
+)
+
+// maxBundleSize 导入文档的大小上限
+const maxBundleSize = 1 << 20
+
+// Bundle 任务的可移植 YAML 文档
+//
+// 只包含任务定义（提示词、工作空间、安全、标签、钩子、调度约束、会话配置及模板/Agent/项目引用），
+// 不含 ID、状态、上下文、父任务和时间戳，便于纳入 git 管理并在不同实例间迁移。
+// 工作空间、安全、钩子与调度约束按 API 的 JSON 字段名展开。
+type Bundle struct {
+	APIVersion     string                 `yaml:"apiVersion"`
+	Kind           string                 `yaml:"kind"`
+	Name           string                 `yaml:"name"`
+	Description    string                 `yaml:"description,omitempty"`
+	Type           string                 `yaml:"type,omitempty"`
+	Labels         map[string]string      `yaml:"labels,omitempty"`
+	Prompt         BundlePrompt           `yaml:"prompt"`
+	Workspace      map[string]interface{} `yaml:"workspace,omitempty"`
+	Security       map[string]interface{} `yaml:"security,omitempty"`
+	Hooks          map[string]interface{} `yaml:"hooks,omitempty"`
+	Secrets        []string               `yaml:"secrets,omitempty"`
+	Priority       string                 `yaml:"priority,omitempty"`
+	TimeoutSeconds int                    `yaml:"timeout_seconds,omitempty"`
+	Scheduling     map[string]interface{} `yaml:"scheduling,omitempty"`
+	Region         string                 `yaml:"region,omitempty"`
+	Session        map[string]interface{} `yaml:"session,omitempty"`
+	TemplateID     string                 `yaml:"template_id,omitempty"`
+	AgentID        string                 `yaml:"agent_id,omitempty"`
+	ProjectID      string                 `yaml:"project_id,omitempty"`
+}
+
+// BundlePrompt 导出文档中的提示词
+type BundlePrompt struct {
+	Content     string `yaml:"content"`
+	Description string `yaml:"description,omitempty"`
+	TemplateID  string `yaml:"template_id,omitempty"`
+}
+
+// NewBundle 从任务生成导出文档
+func NewBundle(task *model.Task) *Bundle {
+	b := &Bundle{
+		APIVersion:     BundleAPIVersion,
+		Kind:           BundleKind,
+		Name:           task.Name,
+		Description:    task.Description,
+		Type:           string(task.Type),
+		Labels:         task.Labels,
+		Secrets:        task.Secrets,
+		Priority:       string(task.Priority),
+		TimeoutSeconds: task.TimeoutSeconds,
+		Region:         task.Region,
+		TemplateID:     deref(task.TemplateID),
+		AgentID:        deref(task.AgentID),
+		ProjectID:      deref(task.ProjectID),
+	}
+	if task.Prompt != nil {
+		b.Prompt = BundlePrompt{
+			Content:     task.Prompt.Content,
+			Description: task.Prompt.Description,
+			TemplateID:  deref(task.Prompt.TemplateID),
+		}
+	}
+	if task.Workspace != nil {
+		b.Workspace = *jsonBridgeConvert[map[string]interface{}](task.Workspace)
+	}
+	if task.Security != nil {
+		b.Security = *jsonBridgeConvert[map[string]interface{}](task.Security)
+	}
+	if task.Hooks != nil {
+		b.Hooks = *jsonBridgeConvert[map[string]interface{}](task.Hooks)
+	}
+	if task.Scheduling != nil {
+		b.Scheduling = *jsonBridgeConvert[map[string]interface{}](task.Scheduling)
+	}
+	if task.Session != nil {
+		b.Session = *jsonBridgeConvert[map[string]interface{}](task.Session)
+	}
+	return b
+}
+
+// ParseBundle 解析并校验导出文档
+func ParseBundle(data []byte) (*Bundle, error) {
+	var b Bundle
+	if err := yaml.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("invalid YAML: %w", err)
+	}
+	if b.APIVersion != BundleAPIVersion {
+		return nil, fmt.Errorf("unsupported apiVersion %q, expected %q", b.APIVersion, BundleAPIVersion)
+	}
+	if b.Kind != BundleKind {
+		return nil, fmt.Errorf("unsupported kind %q, expected %q", b.Kind, BundleKind)
+	}
+	return &b, nil
+}
+
+// CreateRequest 将导出文档转换为创建任务的请求（校验由 createTask 统一完成）
+func (b *Bundle) CreateRequest() *CreateRequest {
+	req := &CreateRequest{
+		Name:              b.Name,
+		Prompt:            b.Prompt.Content,
+		Description:       optional(b.Description),
+		Type:              optional(b.Type),
+		PromptDescription: optional(b.Prompt.Description),
+		PromptTemplateId:  optional(b.Prompt.TemplateID),
+		Priority:          optional(b.Priority),
+		TemplateId:        optional(b.TemplateID),
+		AgentId:           optional(b.AgentID),
+		ProjectId:         optional(b.ProjectID),
+	}
+	if len(b.Labels) > 0 {
+		req.Labels = &b.Labels
+	}
+	if len(b.Secrets) > 0 {
+		req.Secrets = &b.Secrets
+	}
+	if b.TimeoutSeconds != 0 {
+		req.TimeoutSeconds = &b.TimeoutSeconds
+	}
+	if len(b.Workspace) > 0 {
+		req.Workspace = jsonBridgeConvert[openapi.WorkspaceConfig](b.Workspace)
+	}
+	if len(b.Security) > 0 {
+		req.Security = jsonBridgeConvert[openapi.SecurityConfig](b.Security)
+	}
+	if len(b.Hooks) > 0 {
+		req.Hooks = jsonBridgeConvert[openapi.LifecycleHooks](b.Hooks)
+	}
+	if len(b.Scheduling) > 0 {
+		req.Scheduling = jsonBridgeConvert[openapi.TaskScheduling](b.Scheduling)
+	}
+	if b.Region != "" {
+		req.Region = &b.Region
+	}
+	if len(b.Session) > 0 {
+		req.Session = jsonBridgeConvert[openapi.TaskSession](b.Session)
+	}
+	return req
+}
+
+// Export 导出任务定义为 YAML
+// GET /api/v1/tasks/{id}/export
+func (h *Handler) Export(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+	task, err := h.store.GetTask(r.Context(), id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get task")
+		return
+	}
+	if task == nil {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+
+	data, err := yaml.Marshal(NewBundle(task))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "task.export.failed", "task_id", id, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to export task")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", bundleFilename(task)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(data)
+}
+
+// Import 从 YAML 文档创建任务
+// POST /api/v1/tasks/import
+func (h *Handler) Import(w http.ResponseWriter, r *http.Request) {
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBundleSize+1))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "failed to read request body")
+		return
+	}
+	if len(data) > maxBundleSize {
+		writeError(w, http.StatusRequestEntityTooLarge, "bundle too large")
+		return
+	}
+	bundle, err := ParseBundle(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	task, err := h.createTask(r.Context(), bundle.CreateRequest())
+	if err != nil {
+		ce := err.(*createError)
+		writeError(w, ce.status, ce.message)
+		return
+	}
+	slog.InfoContext(r.Context(), "task.import.created", "task_id", task.ID, "name", task.Name)
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// bundleFilename 导出文件名（任务名中的非安全字符替换为 -）
+func bundleFilename(task *model.Task) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.' {
+			return r
+		}
+		return '-'
+	}, task.Name)
+	if strings.Trim(name, "-") == "" {
+		name = task.ID
+	}
+	return name + ".task.yaml"
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
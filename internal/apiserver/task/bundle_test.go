@@ -0,0 +1,115 @@
+package task
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// TestBundle_ExportImportRoundTrip 导出的文档可在另一实例导入，定义字段保持一致
+func TestBundle_ExportImportRoundTrip(t *testing.T) {
+	templateID := "tpl-review"
+	src := &model.Task{
+		ID:          "task-src",
+		Name:        "code review",
+		Description: "review pull requests",
+		Status:      model.TaskStatusCompleted,
+		Type:        model.TaskTypeDevelopment,
+		Prompt:      &model.Prompt{Content: "review the diff", Description: "审查"},
+		Workspace: &model.WorkspaceConfig{
+			Type: model.WorkspaceTypeGit,
+			Git:  &model.GitConfig{URL: "https://example.com/repo.git", Branch: "main"},
+		},
+		Labels:         map[string]string{"team": "infra"},
+		Secrets:        []string{"GITHUB_TOKEN"},
+		Priority:       model.PriorityHigh,
+		TimeoutSeconds: 600,
+		TemplateID:     &templateID,
+		Scheduling: &model.TaskScheduling{
+			AntiAffinity: &model.TaskAntiAffinity{Mode: model.SchedulingModeRequired},
+			Spread:       &model.SpreadConstraint{LabelKey: "zone", MaxSkew: 2},
+		},
+		Region: "cn-east",
+	}
+	mux := http.NewServeMux()
+	NewHandler(&memTaskStore{tasks: map[string]*model.Task{src.ID: src}}).RegisterRoutes(mux)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/tasks/task-src/export", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("导出状态码 = %d, 期望 200", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/yaml" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	doc := w.Body.String()
+	for _, field := range []string{"task-src", "status", "created_at"} {
+		if strings.Contains(doc, field) {
+			t.Errorf("导出文档不应包含 %q:\n%s", field, doc)
+		}
+	}
+
+	dst := &memTaskStore{tasks: map[string]*model.Task{}}
+	mux = http.NewServeMux()
+	NewHandler(dst).RegisterRoutes(mux)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/import", strings.NewReader(doc)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("导入状态码 = %d, 期望 201: %s", w.Code, w.Body.String())
+	}
+	var got model.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatalf("解析响应失败: %v", err)
+	}
+	if got.ID == src.ID || got.Status != model.TaskStatusPending {
+		t.Errorf("导入任务 id=%s status=%s, 期望新 ID 且为 pending", got.ID, got.Status)
+	}
+	if got.Name != src.Name || got.Type != src.Type || got.GetPromptContent() != "review the diff" ||
+		got.Priority != model.PriorityHigh || got.TimeoutSeconds != 600 || got.Labels["team"] != "infra" ||
+		got.TemplateID == nil || *got.TemplateID != templateID || len(got.Secrets) != 1 {
+		t.Errorf("导入任务 = %+v", got)
+	}
+	if got.Workspace == nil || got.Workspace.Git == nil || got.Workspace.Git.URL != src.Workspace.Git.URL {
+		t.Errorf("工作空间 = %+v", got.Workspace)
+	}
+	if got.Scheduling == nil || !got.Scheduling.AntiAffinity.IsRequired() ||
+		got.Scheduling.Spread == nil || got.Scheduling.Spread.LabelKey != "zone" || got.Scheduling.Spread.MaxSkew != 2 {
+		t.Errorf("调度约束 = %+v", got.Scheduling)
+	}
+	if got.Region != "cn-east" {
+		t.Errorf("区域 = %q, 期望 cn-east", got.Region)
+	}
+}
+
+// TestBundle_ImportValidation 文档头不匹配或内容无效时拒绝导入
+func TestBundle_ImportValidation(t *testing.T) {
+	mux := http.NewServeMux()
+	NewHandler(&memTaskStore{tasks: map[string]*model.Task{}}).RegisterRoutes(mux)
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"非法 YAML", "name: [", http.StatusBadRequest},
+		{"缺少版本", "kind: Task\nname: a\nprompt:\n  content: p\n", http.StatusBadRequest},
+		{"类型不符", "apiVersion: agents-admin/v1\nkind: Workflow\nname: a\n", http.StatusBadRequest},
+		{"缺少提示词", "apiVersion: agents-admin/v1\nkind: Task\nname: a\n", http.StatusBadRequest},
+		{"非法优先级", "apiVersion: agents-admin/v1\nkind: Task\nname: a\nprompt:\n  content: p\npriority: urgent\n", http.StatusBadRequest},
+		{"非法调度约束", "apiVersion: agents-admin/v1\nkind: Task\nname: a\nprompt:\n  content: p\nscheduling:\n  spread:\n    mode: always\n", http.StatusBadRequest},
+		{"非法区域", "apiVersion: agents-admin/v1\nkind: Task\nname: a\nprompt:\n  content: p\nregion: CN East\n", http.StatusBadRequest},
+		{"文档过大", "apiVersion: agents-admin/v1\nkind: Task\nname: " + strings.Repeat("a", maxBundleSize), http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks/import", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("状态码 = %d, 期望 %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
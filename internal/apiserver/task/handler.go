@@ -2,38 +2,62 @@
 package task
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"time"
 
 	openapi "agents-admin/api/generated/go"
+	"agents-admin/internal/apiserver/idempotency"
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
 	"agents-admin/internal/shared/storage"
 )
 
 // Handler 任务领域 HTTP 处理器
 type Handler struct {
-	store storage.TaskStore // 使用接口类型
+	store    storage.TaskStore // 使用接口类型
+	projects ProjectStore      // 项目默认值（可选，nil 时忽略 project_id 约束）
+	policies secpolicy.Store   // 安全策略（可选，nil 时创建任务不校验权限）
+	runs     Runs              // Run 启动与取消（可选，批量操作使用）
+
+	idempotency *idempotency.Guard // 创建接口的幂等保护（可选，nil 时忽略 Idempotency-Key 请求头）
 }
 
 // NewHandler 创建任务处理器
 func NewHandler(store storage.TaskStore) *Handler {
-	return &Handler{store: store}
+	h := &Handler{store: store}
+	if ps, ok := store.(ProjectStore); ok {
+		h.projects = ps
+	}
+	if ps, ok := store.(secpolicy.Store); ok {
+		h.policies = ps
+	}
+	return h
 }
 
 // RegisterRoutes 注册任务相关路由
 func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/tasks", h.List)
-	mux.HandleFunc("POST /api/v1/tasks", h.Create)
+	mux.HandleFunc("POST /api/v1/tasks", h.idempotency.Wrap(h.Create))
+	mux.HandleFunc("POST /api/v1/tasks/bulk", h.Bulk)
+	mux.HandleFunc("POST /api/v1/tasks/import", h.Import)
 	mux.HandleFunc("GET /api/v1/tasks/{id}", h.Get)
 	mux.HandleFunc("DELETE /api/v1/tasks/{id}", h.Delete)
 	mux.HandleFunc("GET /api/v1/tasks/{id}/subtasks", h.ListSubTasks)
 	mux.HandleFunc("GET /api/v1/tasks/{id}/tree", h.GetTree)
+	mux.HandleFunc("GET /api/v1/tasks/{id}/export", h.Export)
+	mux.HandleFunc("GET /api/v1/tasks/{id}/context", h.GetContext)
 	mux.HandleFunc("PUT /api/v1/tasks/{id}/context", h.UpdateContext)
 }
 
+// SetIdempotency 设置创建接口的幂等保护（需在 RegisterRoutes 之前调用）
+func (h *Handler) SetIdempotency(g *idempotency.Guard) {
+	h.idempotency = g
+}
+
 // ============================================================================
 // 类型别名（方便外部包使用）
 // ============================================================================
@@ -53,13 +77,32 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
-	if req.Name == "" {
-		writeError(w, http.StatusBadRequest, "name is required")
+	task, err := h.createTask(r.Context(), &req)
+	if err != nil {
+		ce := err.(*createError)
+		writeError(w, ce.status, ce.message)
 		return
 	}
+	writeJSON(w, http.StatusCreated, task)
+}
+
+// createError 创建任务失败的原因，status 为对应的 HTTP 状态码
+type createError struct {
+	status  int
+	message string
+}
+
+func (e *createError) Error() string { return e.message }
+
+// createTask 校验请求、应用项目默认值与父任务上下文并保存任务（单个创建与批量创建共用）
+//
+// 返回的错误均为 *createError。
+func (h *Handler) createTask(ctx context.Context, req *CreateRequest) (*model.Task, error) {
+	if req.Name == "" {
+		return nil, &createError{http.StatusBadRequest, "name is required"}
+	}
 	if req.Prompt == "" {
-		writeError(w, http.StatusBadRequest, "prompt is required")
-		return
+		return nil, &createError{http.StatusBadRequest, "prompt is required"}
 	}
 
 	taskType := model.TaskTypeGeneral
@@ -109,6 +152,73 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	// 转换 Security（JSON 桥接）
 	if req.Security != nil {
 		task.Security = jsonBridgeConvert[model.SecurityConfig](req.Security)
+		if task.Security != nil {
+			if err := task.Security.Limits.Validate(); err != nil {
+				return nil, &createError{http.StatusBadRequest, err.Error()}
+			}
+			if err := task.Security.Commands.Validate(); err != nil {
+				return nil, &createError{http.StatusBadRequest, err.Error()}
+			}
+		}
+	}
+
+	// 转换 Hooks（JSON 桥接）
+	if req.Hooks != nil {
+		task.Hooks = jsonBridgeConvert[model.LifecycleHooks](req.Hooks)
+		if err := task.Hooks.Validate(); err != nil {
+			return nil, &createError{http.StatusBadRequest, err.Error()}
+		}
+	}
+
+	// 密钥引用（仅校验名称，是否存在在执行时解析）
+	if req.Secrets != nil && len(*req.Secrets) > 0 {
+		for _, name := range *req.Secrets {
+			if !model.IsValidSecretName(name) {
+				return nil, &createError{http.StatusBadRequest, "invalid secret name: " + name}
+			}
+		}
+		task.Secrets = *req.Secrets
+	}
+
+	// 调度优先级（未指定时为 normal）
+	if req.Priority != nil {
+		task.Priority = model.Priority(*req.Priority)
+		if !task.Priority.IsValid() {
+			return nil, &createError{http.StatusBadRequest, "invalid priority: must be high, normal or low"}
+		}
+	}
+	task.Priority = task.Priority.OrDefault()
+
+	// 执行时限（0 表示使用调度器默认时限）
+	if req.TimeoutSeconds != nil {
+		if *req.TimeoutSeconds < 0 {
+			return nil, &createError{http.StatusBadRequest, "timeout_seconds must not be negative"}
+		}
+		task.TimeoutSeconds = *req.TimeoutSeconds
+	}
+
+	// 调度约束（JSON 桥接）
+	if req.Scheduling != nil {
+		task.Scheduling = jsonBridgeConvert[model.TaskScheduling](req.Scheduling)
+		if err := task.Scheduling.Validate(); err != nil {
+			return nil, &createError{http.StatusBadRequest, err.Error()}
+		}
+	}
+
+	// 区域（节点 region 标签取值）
+	if req.Region != nil && *req.Region != "" {
+		if err := model.ValidateRegionID(*req.Region); err != nil {
+			return nil, &createError{http.StatusBadRequest, err.Error()}
+		}
+		task.Region = *req.Region
+	}
+
+	// 持久 Agent 会话（JSON 桥接）
+	if req.Session != nil {
+		task.Session = jsonBridgeConvert[model.TaskSession](req.Session)
+		if task.Session != nil && task.Session.KeepAliveSeconds < 0 {
+			return nil, &createError{http.StatusBadRequest, "session.keep_alive_seconds must not be negative"}
+		}
 	}
 
 	// 转换 Context（openapi -> model）
@@ -116,16 +226,30 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		task.Context = convertTaskContext(req.Context)
 	}
 
+	// 项目默认值与约束
+	if req.ProjectId != nil && *req.ProjectId != "" {
+		if h.projects == nil {
+			return nil, &createError{http.StatusNotImplemented, "projects not supported"}
+		}
+		if err := ApplyProject(ctx, h.projects, *req.ProjectId, task, req.Type != nil && *req.Type != ""); err != nil {
+			pe := err.(*ProjectError)
+			return nil, &createError{pe.Status, pe.Message}
+		}
+	}
+
+	// 安全策略：需要的权限不得被策略禁止（在项目默认安全配置填充之后）
+	if err := h.checkSecurity(ctx, task); err != nil {
+		return nil, err
+	}
+
 	// 继承父任务上下文
 	if req.ParentId != nil && *req.ParentId != "" {
-		parentTask, err := h.store.GetTask(r.Context(), *req.ParentId)
+		parentTask, err := h.store.GetTask(ctx, *req.ParentId)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to get parent task")
-			return
+			return nil, &createError{http.StatusInternalServerError, "failed to get parent task"}
 		}
 		if parentTask == nil {
-			writeError(w, http.StatusBadRequest, "parent task not found")
-			return
+			return nil, &createError{http.StatusBadRequest, "parent task not found"}
 		}
 		if parentTask.Context != nil && len(parentTask.Context.ProducedContext) > 0 {
 			if task.Context == nil {
@@ -135,12 +259,11 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	if err := h.store.CreateTask(r.Context(), task); err != nil {
-		log.Printf("[Task] Create error: %v", err)
-		writeError(w, http.StatusInternalServerError, "failed to create task")
-		return
+	if err := h.store.CreateTask(ctx, task); err != nil {
+		slog.ErrorContext(ctx, "task.create.failed", "error", err)
+		return nil, &createError{http.StatusInternalServerError, "failed to create task"}
 	}
-	writeJSON(w, http.StatusCreated, task)
+	return task, nil
 }
 
 // Get 获取任务详情
@@ -163,47 +286,83 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 // GET /api/v1/tasks
 //
 // 支持的查询参数：
-//   - status: 按状态筛选
-//   - search: 按名称模糊搜索
-//   - since:  创建时间下限 (ISO8601)
-//   - until:  创建时间上限 (ISO8601)
-//   - limit:  每页条数 (默认 20, 最大 100)
-//   - offset: 偏移量
+//   - status:        按状态筛选（逗号分隔表示任一状态）
+//   - search:        按名称模糊搜索
+//   - project_id:    按项目筛选
+//   - node_id:       有 Run 分配到该节点的任务
+//   - labels:        标签选择器（k=v,k!=v,k,!k）
+//   - since:         创建时间下限 (ISO8601)
+//   - until:         创建时间上限 (ISO8601)
+//   - limit:         每页条数 (默认 20, 最大 100)
+//   - offset:        偏移量（使用 cursor 时忽略）
+//   - cursor:        上一页返回的 next_cursor
+//   - include_total: 是否返回 total（偏移分页默认 true，游标分页默认 false）
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
-	limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
-	offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
 
 	filter := storage.TaskFilter{
-		Status: r.URL.Query().Get("status"),
-		Search: r.URL.Query().Get("search"),
-		Limit:  limit,
-		Offset: offset,
+		Statuses:  splitList(q.Get("status")),
+		Search:    q.Get("search"),
+		ProjectID: q.Get("project_id"),
+		NodeID:    q.Get("node_id"),
+		Limit:     limit + 1, // 多取一条判断是否还有下一页
+		Offset:    offset,
 	}
-	if s := r.URL.Query().Get("since"); s != "" {
+	if s := q.Get("since"); s != "" {
 		if t, err := time.Parse(time.RFC3339, s); err == nil {
 			filter.Since = t
 		}
 	}
-	if u := r.URL.Query().Get("until"); u != "" {
+	if u := q.Get("until"); u != "" {
 		if t, err := time.Parse(time.RFC3339, u); err == nil {
 			filter.Until = t
 		}
 	}
+	var err error
+	if filter.Labels, err = storage.ParseLabelSelector(q.Get("labels")); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if c := q.Get("cursor"); c != "" {
+		if filter.Cursor, err = storage.ParsePageCursor(c); err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+	// 偏移分页默认返回总数，游标分页默认不统计（大表 COUNT 代价高）
+	includeTotal := filter.Cursor == nil
+	if v := q.Get("include_total"); v != "" {
+		includeTotal, _ = strconv.ParseBool(v)
+	}
+	filter.SkipTotal = !includeTotal
 
-	tasks, total, err := h.store.ListTasksWithFilter(r.Context(), filter)
+	tasks, total, err := h.store.ListTasksWithFilter(storage.WithReadReplica(r.Context()), filter)
 	if err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to list tasks")
 		return
 	}
-	writeJSON(w, http.StatusOK, map[string]interface{}{
+	hasMore := len(tasks) > limit
+	if hasMore {
+		tasks = tasks[:limit]
+	}
+	resp := map[string]interface{}{
 		"tasks":    tasks,
 		"count":    len(tasks),
-		"total":    total,
-		"has_more": offset+len(tasks) < total,
-	})
+		"has_more": hasMore,
+	}
+	if hasMore {
+		last := tasks[limit-1]
+		resp["next_cursor"] = storage.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if includeTotal {
+		resp["total"] = total
+	}
+	writeJSON(w, http.StatusOK, resp)
 }
 
 // Delete 删除任务
@@ -241,6 +400,24 @@ func (h *Handler) GetTree(w http.ResponseWriter, r *http.Request) {
 	writeJSON(w, http.StatusOK, map[string]interface{}{"tasks": tasks, "count": len(tasks)})
 }
 
+// GetContext 获取任务上下文（启用持久会话的任务包含追问形成的完整对话）
+// GET /api/v1/tasks/{id}/context
+func (h *Handler) GetContext(w http.ResponseWriter, r *http.Request) {
+	task, err := h.store.GetTask(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get task")
+		return
+	}
+	if task == nil {
+		writeError(w, http.StatusNotFound, "task not found")
+		return
+	}
+	if task.Context == nil {
+		task.Context = &model.TaskContext{}
+	}
+	writeJSON(w, http.StatusOK, task.Context)
+}
+
 // UpdateContext 更新任务上下文
 // PUT /api/v1/tasks/{id}/context
 func (h *Handler) UpdateContext(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,104 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ProjectStore 项目默认值所需的存储接口（可选，未实现时忽略 project_id 约束）
+type ProjectStore interface {
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+}
+
+// ProjectError 项目不存在或任务违反项目约束，Status 为对应的 HTTP 状态码
+type ProjectError struct {
+	Status  int
+	Message string
+}
+
+func (e *ProjectError) Error() string { return e.Message }
+
+// ApplyProject 加载项目并使用其默认值填充任务、校验项目约束（任务创建与 Agent 网关共用）
+//
+// 返回的错误均为 *ProjectError。
+func ApplyProject(ctx context.Context, store ProjectStore, projectID string, task *model.Task, typeSet bool) error {
+	project, err := store.GetProject(ctx, projectID)
+	if err != nil {
+		slog.ErrorContext(ctx, "task.project.failed", "op", "GetProject", "error", err)
+		return &ProjectError{http.StatusInternalServerError, "failed to get project"}
+	}
+	if project == nil {
+		return &ProjectError{http.StatusBadRequest, "project not found"}
+	}
+
+	var instance *model.Instance
+	if task.AgentID != nil && *task.AgentID != "" && len(project.AccountPool) > 0 {
+		if instance, err = store.GetAgentInstance(ctx, *task.AgentID); err != nil {
+			slog.ErrorContext(ctx, "task.project.failed", "op", "GetAgentInstance", "error", err)
+			return &ProjectError{http.StatusInternalServerError, "failed to get agent"}
+		}
+		if instance == nil {
+			return &ProjectError{http.StatusBadRequest, "agent not found"}
+		}
+	}
+
+	if err := applyProjectDefaults(project, task, typeSet, instance); err != nil {
+		return &ProjectError{http.StatusForbidden, err.Error()}
+	}
+	return nil
+}
+
+// applyProjectDefaults 使用项目默认值填充任务，并校验项目约束
+//
+//   - Agent 类型（task.Type）：未指定时使用项目默认值；指定时需在白名单内，
+//     overrides.agent_type=deny 时不得与默认值不同
+//   - Agent 实例（task.AgentID）：overrides.account=deny 时不得指定，
+//     否则实例所用账号需在账号池内（instance 为 task.AgentID 对应的实例）
+//   - 安全配置：未指定时复制项目默认值；指定时需通过策略白名单，
+//     overrides.security=deny 时不得自带
+//
+// typeSet 表示请求是否显式指定了任务类型。返回的错误均为违反项目约束（对应 403）。
+func applyProjectDefaults(project *model.Project, task *model.Task, typeSet bool, instance *model.Instance) error {
+	task.ProjectID = &project.ID
+
+	if !typeSet {
+		if project.DefaultAgentType != "" {
+			task.Type = model.TaskType(project.DefaultAgentType)
+		}
+	} else if project.Overrides.AgentType == model.OverrideDeny &&
+		project.DefaultAgentType != "" && string(task.Type) != project.DefaultAgentType {
+		return fmt.Errorf("project %s does not allow overriding agent type %q", project.Name, project.DefaultAgentType)
+	}
+	if !project.AllowsAgentType(string(task.Type)) {
+		return fmt.Errorf("agent type %q is not allowed in project %s", task.Type, project.Name)
+	}
+
+	if task.AgentID != nil && *task.AgentID != "" {
+		if project.Overrides.Account == model.OverrideDeny {
+			return fmt.Errorf("project %s assigns accounts from its pool; agent_id is not allowed", project.Name)
+		}
+		if instance != nil && !project.AllowsAccount(instance.AccountID) {
+			return fmt.Errorf("account %s of agent %s is not in the account pool of project %s", instance.AccountID, instance.ID, project.Name)
+		}
+	}
+
+	if task.Security == nil {
+		if project.DefaultSecurity != nil {
+			sec := *project.DefaultSecurity
+			task.Security = &sec
+		}
+	} else {
+		if project.Overrides.Security == model.OverrideDeny {
+			return fmt.Errorf("project %s does not allow overriding security config", project.Name)
+		}
+		if !project.AllowsSecurityPolicy(task.Security.Policy) {
+			return fmt.Errorf("security policy %q is not allowed in project %s", task.Security.Policy, project.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package task
+
+import (
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func testProject() *model.Project {
+	return &model.Project{
+		ID:                      "proj-1",
+		Name:                    "backend",
+		DefaultAgentType:        "qwen-code",
+		AllowedAgentTypes:       []string{"qwen-code", "claude"},
+		AccountPool:             []string{"acc-1"},
+		DefaultSecurity:         &model.SecurityConfig{Policy: model.SecurityPolicyStandard},
+		AllowedSecurityPolicies: []model.SecurityPolicy{model.SecurityPolicyStrict, model.SecurityPolicyStandard},
+	}
+}
+
+// TestApplyProjectDefaults_FillsDefaults 未指定的字段从项目继承
+func TestApplyProjectDefaults_FillsDefaults(t *testing.T) {
+	project := testProject()
+	task := &model.Task{Type: model.TaskTypeGeneral}
+	if err := applyProjectDefaults(project, task, false, nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if task.Type != "qwen-code" {
+		t.Errorf("Type = %s, 期望 qwen-code", task.Type)
+	}
+	if task.ProjectID == nil || *task.ProjectID != "proj-1" {
+		t.Errorf("ProjectID = %v", task.ProjectID)
+	}
+	if task.Security == nil || task.Security.Policy != model.SecurityPolicyStandard {
+		t.Fatalf("Security = %+v", task.Security)
+	}
+	// 默认值为副本，修改任务不影响项目
+	task.Security.Policy = model.SecurityPolicyStrict
+	if project.DefaultSecurity.Policy != model.SecurityPolicyStandard {
+		t.Error("项目默认安全配置被修改")
+	}
+}
+
+// TestApplyProjectDefaults_Allowlists 显式值需通过白名单
+func TestApplyProjectDefaults_Allowlists(t *testing.T) {
+	agentID := "inst-1"
+	tests := []struct {
+		name     string
+		task     *model.Task
+		typeSet  bool
+		instance *model.Instance
+		wantErr  bool
+	}{
+		{"允许的类型", &model.Task{Type: "claude"}, true, nil, false},
+		{"不在白名单的类型", &model.Task{Type: "codex"}, true, nil, true},
+		{"账号池内的实例", &model.Task{Type: "claude", AgentID: &agentID}, true, &model.Instance{ID: agentID, AccountID: "acc-1"}, false},
+		{"账号池外的实例", &model.Task{Type: "claude", AgentID: &agentID}, true, &model.Instance{ID: agentID, AccountID: "acc-9"}, true},
+		{"不允许的安全策略", &model.Task{Type: "claude", Security: &model.SecurityConfig{Policy: model.SecurityPolicyPermissive}}, true, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyProjectDefaults(testProject(), tt.task, tt.typeSet, tt.instance)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr = %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestApplyProjectDefaults_DenyOverrides 覆盖规则为 deny 时拒绝显式值
+func TestApplyProjectDefaults_DenyOverrides(t *testing.T) {
+	project := testProject()
+	project.Overrides = model.ProjectOverrides{
+		AgentType: model.OverrideDeny,
+		Account:   model.OverrideDeny,
+		Security:  model.OverrideDeny,
+	}
+	agentID := "inst-1"
+
+	if err := applyProjectDefaults(project, &model.Task{Type: "qwen-code"}, true, nil); err != nil {
+		t.Errorf("与默认值相同的类型应允许: %v", err)
+	}
+	if err := applyProjectDefaults(project, &model.Task{Type: "claude"}, true, nil); err == nil {
+		t.Error("覆盖 Agent 类型应被拒绝")
+	}
+	if err := applyProjectDefaults(project, &model.Task{AgentID: &agentID}, false, nil); err == nil {
+		t.Error("指定 Agent 实例应被拒绝")
+	}
+	security := &model.SecurityConfig{Policy: model.SecurityPolicyStrict}
+	if err := applyProjectDefaults(project, &model.Task{Security: security}, false, nil); err == nil {
+		t.Error("自带安全配置应被拒绝")
+	}
+}
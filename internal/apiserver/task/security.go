@@ -0,0 +1,35 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
+)
+
+// checkSecurity 按安全策略校验任务的安全配置（见 secpolicy 包），返回的错误为 *createError
+//
+// 任务 security.permissions 中的权限被 Agent 模板默认策略或任务策略等级禁止时返回 403，
+// 引用未知权限、策略等级或安全策略时返回 400。
+func (h *Handler) checkSecurity(ctx context.Context, task *model.Task) error {
+	if h.policies == nil {
+		return nil
+	}
+	_, err := secpolicy.Resolve(ctx, h.policies, task)
+	if err == nil {
+		return nil
+	}
+	var violation *secpolicy.ViolationError
+	switch {
+	case errors.As(err, &violation):
+		return &createError{http.StatusForbidden, err.Error()}
+	case errors.Is(err, secpolicy.ErrUnknownPermission), errors.Is(err, secpolicy.ErrPolicyNotFound):
+		return &createError{http.StatusBadRequest, err.Error()}
+	default:
+		slog.ErrorContext(ctx, "task.security.failed", "error", err)
+		return &createError{http.StatusInternalServerError, "failed to resolve security policy"}
+	}
+}
@@ -0,0 +1,65 @@
+package task
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// policyTaskStore 带安全策略查询的内存任务存储
+type policyTaskStore struct {
+	memTaskStore
+	templates map[string]*model.AgentTemplate
+	instances map[string]*model.Instance
+}
+
+func (s *policyTaskStore) GetAgentInstance(_ context.Context, id string) (*model.Instance, error) {
+	return s.instances[id], nil
+}
+
+func (s *policyTaskStore) GetAgentTemplate(_ context.Context, id string) (*model.AgentTemplate, error) {
+	return s.templates[id], nil
+}
+
+func (s *policyTaskStore) GetSecurityPolicy(_ context.Context, _ string) (*model.SecurityPolicyEntity, error) {
+	return nil, nil
+}
+
+// TestCreate_SecurityPolicy 任务需要的权限按安全策略校验
+func TestCreate_SecurityPolicy(t *testing.T) {
+	tmplID, policyID := "tmpl-1", "builtin-strict"
+	store := &policyTaskStore{
+		memTaskStore: memTaskStore{tasks: map[string]*model.Task{}},
+		templates:    map[string]*model.AgentTemplate{tmplID: {ID: tmplID, DefaultSecurityPolicyID: &policyID}},
+		instances:    map[string]*model.Instance{"inst-1": {ID: "inst-1", TemplateID: &tmplID}},
+	}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"策略允许", `{"name":"t","prompt":"p","security":{"policy":"standard","permissions":["file_read","command_execute"]}}`, http.StatusCreated},
+		{"任务等级禁止", `{"name":"t","prompt":"p","security":{"policy":"strict","permissions":["command_execute"]}}`, http.StatusForbidden},
+		{"Agent 模板策略禁止", `{"name":"t","prompt":"p","agent_id":"inst-1","security":{"permissions":["network_outbound"]}}`, http.StatusForbidden},
+		{"未知权限", `{"name":"t","prompt":"p","security":{"denied_permissions":["sudo"]}}`, http.StatusBadRequest},
+		{"未知等级", `{"name":"t","prompt":"p","security":{"policy":"lenient"}}`, http.StatusBadRequest},
+		{"命令规则", `{"name":"t","prompt":"p","security":{"commands":{"deny":["\\bgit push\\b"],"action":"block"}}}`, http.StatusCreated},
+		{"无效的命令规则", `{"name":"t","prompt":"p","security":{"commands":{"deny":["("]}}}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/tasks", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("状态码 = %d, 期望 %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
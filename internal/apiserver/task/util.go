@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"net/http"
+	"strings"
 
 	openapi "agents-admin/api/generated/go"
 	"agents-admin/internal/shared/model"
@@ -22,6 +23,17 @@ func writeError(w http.ResponseWriter, status int, message string) {
 	writeJSON(w, status, map[string]string{"error": message})
 }
 
+// splitList 解析逗号分隔的查询参数（忽略空项）
+func splitList(s string) []string {
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
 // generateID 生成带前缀的随机 ID
 // 格式：prefix-xxxxxxxxxxxx（prefix + 12 字符 hex）
 func generateID(prefix string) string {
@@ -12,12 +12,14 @@ import (
 
 // Handler 模板领域 HTTP 处理器
 type Handler struct {
-	store storage.PersistentStore
+	store   storage.PersistentStore
+	bundles SkillBundleStore
+	objects SkillObjectStore
 }
 
 // NewHandler 创建模板处理器
 func NewHandler(store storage.PersistentStore) *Handler {
-	return &Handler{store: store}
+	return &Handler{store: store, bundles: store}
 }
 
 // RegisterRoutes 注册模板相关路由
@@ -40,6 +42,9 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/skills/{id}", h.GetSkill)
 	mux.HandleFunc("POST /api/v1/skills", h.CreateSkill)
 	mux.HandleFunc("DELETE /api/v1/skills/{id}", h.DeleteSkill)
+	mux.HandleFunc("GET /api/v1/skills/{id}/bundles", h.ListSkillBundles)
+	mux.HandleFunc("POST /api/v1/skills/{id}/bundles", h.UploadSkillBundle)
+	mux.HandleFunc("GET /api/v1/skills/{id}/bundles/{version}", h.DownloadSkillBundle)
 
 	// MCP Servers
 	mux.HandleFunc("GET /api/v1/mcp-servers", h.ListMCPServers)
@@ -288,6 +293,7 @@ func (h *Handler) CreateSkill(w http.ResponseWriter, r *http.Request) {
 
 func (h *Handler) DeleteSkill(w http.ResponseWriter, r *http.Request) {
 	id := r.PathValue("id")
+	h.deleteSkillBundles(r.Context(), id)
 	if err := h.store.DeleteSkill(r.Context(), id); err != nil {
 		writeError(w, http.StatusInternalServerError, "failed to delete skill")
 		return
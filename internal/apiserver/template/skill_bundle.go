@@ -0,0 +1,178 @@
+// Package template 技能包版本
+//
+// 技能包（清单 skill.yaml + scripts/、prompts/ 等文件的 tar.gz，格式见 skillbundle 包）按版本上传到对象存储
+// skills/<skill_id>/<version>.tar.gz，版本不可变。创建 Run 时按 Agent 模板引用的技能解析出包版本与摘要写入执行快照，
+// NodeManager 在 Agent 启动前下载、校验并安装到 Agent 的技能目录。
+package template
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/skillbundle"
+)
+
+// SkillObjectStore 技能包对象存储（由 MinIO 客户端实现）
+type SkillObjectStore interface {
+	Upload(ctx context.Context, key string, reader io.Reader, size int64, contentType string) error
+	Download(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// SkillBundleStore 技能包版本存储接口
+type SkillBundleStore interface {
+	GetSkill(ctx context.Context, id string) (*model.Skill, error)
+	CreateSkillBundle(ctx context.Context, bundle *model.SkillBundle) error
+	GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error)
+	ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error)
+}
+
+// SetObjectStore 设置技能包的对象存储（未设置时技能包接口返回 503）
+func (h *Handler) SetObjectStore(objects SkillObjectStore) {
+	h.objects = objects
+}
+
+// UploadSkillBundle 上传技能包的一个版本
+// POST /api/v1/skills/{id}/bundles
+//
+// 请求体为技能包 tar.gz（Content-Type: application/gzip），版本号取自清单。
+// 相同版本重复上传时内容必须一致（返回已有版本），否则返回 409。
+func (h *Handler) UploadSkillBundle(w http.ResponseWriter, r *http.Request) {
+	if h.objects == nil {
+		writeError(w, http.StatusServiceUnavailable, "object storage not configured")
+		return
+	}
+	ctx := r.Context()
+	id := r.PathValue("id")
+
+	data, err := io.ReadAll(http.MaxBytesReader(w, r.Body, skillbundle.MaxSize))
+	if err != nil {
+		writeError(w, http.StatusRequestEntityTooLarge, "bundle exceeds "+strconv.Itoa(skillbundle.MaxSize)+" bytes")
+		return
+	}
+	manifest, err := skillbundle.Inspect(data)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	skill, err := h.bundles.GetSkill(ctx, id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get skill")
+		return
+	}
+	if skill == nil {
+		writeError(w, http.StatusNotFound, "skill not found")
+		return
+	}
+
+	digest := skillbundle.Digest(data)
+	existing, err := h.bundles.GetSkillBundle(ctx, id, manifest.Version)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get skill bundle")
+		return
+	}
+	if existing != nil {
+		if existing.Digest != digest {
+			writeError(w, http.StatusConflict, "version "+manifest.Version+" already exists with different content")
+			return
+		}
+		writeJSON(w, http.StatusOK, existing)
+		return
+	}
+
+	bundle := &model.SkillBundle{
+		SkillID:     id,
+		Version:     manifest.Version,
+		Name:        manifest.Name,
+		Description: manifest.Description,
+		Digest:      digest,
+		Size:        int64(len(data)),
+		CreatedAt:   time.Now(),
+	}
+	if err := h.objects.Upload(ctx, bundle.ObjectKey(), bytes.NewReader(data), bundle.Size, "application/gzip"); err != nil {
+		slog.ErrorContext(ctx, "skill.bundle.upload_failed", "skill_id", id, "version", bundle.Version, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to store bundle")
+		return
+	}
+	if err := h.bundles.CreateSkillBundle(ctx, bundle); err != nil {
+		slog.ErrorContext(ctx, "skill.bundle.save_failed", "skill_id", id, "version", bundle.Version, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create skill bundle")
+		return
+	}
+	slog.InfoContext(ctx, "skill.bundle.uploaded", "skill_id", id, "version", bundle.Version, "digest", digest, "size", bundle.Size)
+	writeJSON(w, http.StatusCreated, bundle)
+}
+
+// ListSkillBundles 列出技能包的所有版本（按上传时间倒序，首个为最新版本）
+// GET /api/v1/skills/{id}/bundles
+func (h *Handler) ListSkillBundles(w http.ResponseWriter, r *http.Request) {
+	bundles, err := h.bundles.ListSkillBundles(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list skill bundles")
+		return
+	}
+	if bundles == nil {
+		bundles = []*model.SkillBundle{}
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"bundles": bundles, "count": len(bundles)})
+}
+
+// DownloadSkillBundle 下载技能包的指定版本（NodeManager 安装技能时调用）
+// GET /api/v1/skills/{id}/bundles/{version}
+//
+// 响应头 X-Skill-Digest 为包摘要，供下载方校验。
+func (h *Handler) DownloadSkillBundle(w http.ResponseWriter, r *http.Request) {
+	if h.objects == nil {
+		writeError(w, http.StatusServiceUnavailable, "object storage not configured")
+		return
+	}
+	ctx := r.Context()
+	id, version := r.PathValue("id"), r.PathValue("version")
+
+	bundle, err := h.bundles.GetSkillBundle(ctx, id, version)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get skill bundle")
+		return
+	}
+	if bundle == nil {
+		writeError(w, http.StatusNotFound, "skill bundle not found")
+		return
+	}
+	rc, err := h.objects.Download(ctx, bundle.ObjectKey())
+	if err != nil {
+		slog.ErrorContext(ctx, "skill.bundle.download_failed", "skill_id", id, "version", version, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to read bundle")
+		return
+	}
+	defer rc.Close()
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Length", strconv.FormatInt(bundle.Size, 10))
+	w.Header().Set("X-Skill-Digest", bundle.Digest)
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, rc)
+}
+
+// deleteSkillBundles 删除技能的所有技能包对象（删除技能时调用，版本记录随技能级联删除）
+func (h *Handler) deleteSkillBundles(ctx context.Context, skillID string) {
+	if h.objects == nil {
+		return
+	}
+	bundles, err := h.bundles.ListSkillBundles(ctx, skillID)
+	if err != nil {
+		slog.ErrorContext(ctx, "skill.bundle.list_failed", "skill_id", skillID, "error", err)
+		return
+	}
+	for _, b := range bundles {
+		if err := h.objects.Delete(ctx, b.ObjectKey()); err != nil {
+			slog.ErrorContext(ctx, "skill.bundle.delete_failed", "skill_id", skillID, "version", b.Version, "error", err)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package template
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/skillbundle"
+)
+
+type fakeBundleStore struct {
+	skills  map[string]*model.Skill
+	bundles []*model.SkillBundle
+}
+
+func (s *fakeBundleStore) GetSkill(_ context.Context, id string) (*model.Skill, error) {
+	return s.skills[id], nil
+}
+
+func (s *fakeBundleStore) CreateSkillBundle(_ context.Context, b *model.SkillBundle) error {
+	s.bundles = append([]*model.SkillBundle{b}, s.bundles...)
+	return nil
+}
+
+func (s *fakeBundleStore) GetSkillBundle(_ context.Context, skillID, version string) (*model.SkillBundle, error) {
+	for _, b := range s.bundles {
+		if b.SkillID == skillID && b.Version == version {
+			return b, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *fakeBundleStore) ListSkillBundles(_ context.Context, skillID string) ([]*model.SkillBundle, error) {
+	var out []*model.SkillBundle
+	for _, b := range s.bundles {
+		if b.SkillID == skillID {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+type memObjects map[string][]byte
+
+func (m memObjects) Upload(_ context.Context, key string, r io.Reader, _ int64, _ string) error {
+	data, err := io.ReadAll(r)
+	m[key] = data
+	return err
+}
+
+func (m memObjects) Download(_ context.Context, key string) (io.ReadCloser, error) {
+	return io.NopCloser(bytes.NewReader(m[key])), nil
+}
+
+func (m memObjects) Delete(_ context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func packBundle(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestSkillBundles(t *testing.T) {
+	store := &fakeBundleStore{skills: map[string]*model.Skill{"skill-1": {ID: "skill-1", Name: "review"}}}
+	objects := memObjects{}
+	h := &Handler{bundles: store}
+	h.SetObjectStore(objects)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	do := func(method, path string, body []byte) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(method, path, bytes.NewReader(body)))
+		return w
+	}
+
+	v1 := packBundle(t, map[string]string{"skill.yaml": "name: review\nversion: 1.0.0\n", "SKILL.md": "审查代码"})
+	if w := do("POST", "/api/v1/skills/skill-1/bundles", v1); w.Code != http.StatusCreated {
+		t.Fatalf("upload: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if _, ok := objects["skills/skill-1/1.0.0.tar.gz"]; !ok {
+		t.Fatalf("技能包应存入对象存储: %v", objects)
+	}
+	// 相同内容重复上传幂等，不同内容被拒绝
+	if w := do("POST", "/api/v1/skills/skill-1/bundles", v1); w.Code != http.StatusOK {
+		t.Errorf("re-upload: status = %d", w.Code)
+	}
+	changed := packBundle(t, map[string]string{"skill.yaml": "name: review\nversion: 1.0.0\n", "SKILL.md": "改了"})
+	if w := do("POST", "/api/v1/skills/skill-1/bundles", changed); w.Code != http.StatusConflict {
+		t.Errorf("conflicting upload: status = %d", w.Code)
+	}
+	if w := do("POST", "/api/v1/skills/skill-1/bundles", []byte("not gzip")); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid bundle: status = %d", w.Code)
+	}
+	if w := do("POST", "/api/v1/skills/missing/bundles", v1); w.Code != http.StatusNotFound {
+		t.Errorf("missing skill: status = %d", w.Code)
+	}
+
+	w := do("GET", "/api/v1/skills/skill-1/bundles/1.0.0", nil)
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), v1) {
+		t.Fatalf("download: status = %d", w.Code)
+	}
+	if got := w.Header().Get("X-Skill-Digest"); got != skillbundle.Digest(v1) {
+		t.Errorf("X-Skill-Digest = %s", got)
+	}
+	if w := do("GET", "/api/v1/skills/skill-1/bundles/9.9.9", nil); w.Code != http.StatusNotFound {
+		t.Errorf("missing version: status = %d", w.Code)
+	}
+}
@@ -2,18 +2,20 @@
 //
 // 本文件实现终端会话相关的 API 端点：
 //   - 终端会话 CRUD
-//   - WebSocket 反向代理
+//   - Run 终端附加（见 run.go）
+//   - WebSocket 反向代理（经节点反向隧道连接节点上的 ttyd）
 //   - NodeManager 回调接口
 package terminal
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"net/http/httputil"
@@ -21,18 +23,43 @@ import (
 	"strings"
 	"time"
 
+	"agents-admin/internal/apiserver/auth"
 	"agents-admin/internal/shared/model"
 	"agents-admin/internal/shared/storage"
+	"agents-admin/internal/shared/tunnel"
 )
 
+// terminalStore 终端会话领域所需的存储接口（接口隔离）
+type terminalStore interface {
+	storage.TerminalSessionStore
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+}
+
+// backendDialTimeout 经隧道连接节点 ttyd 的超时时间
+const backendDialTimeout = 10 * time.Second
+
+// NodeDialer 经节点反向隧道连接节点本地服务（由 apiserver/tunnel.Hub 实现）
+type NodeDialer interface {
+	DialNode(ctx context.Context, nodeID, kind string, params map[string]string) (net.Conn, error)
+}
+
 // Handler 终端会话领域 HTTP 处理器
 type Handler struct {
-	store storage.PersistentStore
+	store terminalStore
+	nodes NodeDialer // 为 nil 时终端代理不可用
+	now   func() time.Time
 }
 
 // NewHandler 创建终端会话处理器
-func NewHandler(store storage.PersistentStore) *Handler {
-	return &Handler{store: store}
+func NewHandler(store terminalStore) *Handler {
+	return &Handler{store: store, now: time.Now}
+}
+
+// SetNodeDialer 设置节点隧道（终端代理经隧道连接节点上的 ttyd）
+func (h *Handler) SetNodeDialer(d NodeDialer) {
+	h.nodes = d
 }
 
 // RegisterRoutes 注册终端会话相关路由
@@ -42,6 +69,7 @@ func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
 	mux.HandleFunc("GET /api/v1/terminal-sessions/{id}", h.Get)
 	mux.HandleFunc("PATCH /api/v1/terminal-sessions/{id}", h.UpdateStatus)
 	mux.HandleFunc("DELETE /api/v1/terminal-sessions/{id}", h.Delete)
+	mux.HandleFunc("POST /api/v1/runs/{id}/terminal", auth.AdminOnly(h.AttachRun))
 	mux.HandleFunc("/terminal/{id}/", h.Proxy)
 
 	// 兼容旧路径（将废弃）
@@ -78,7 +106,7 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	if req.InstanceID != "" {
 		instance, err := h.store.GetAgentInstance(r.Context(), req.InstanceID)
 		if err != nil {
-			log.Printf("[terminal] Failed to get instance %s: %v", req.InstanceID, err)
+			slog.ErrorContext(r.Context(), "terminal.create.failed", "instance_id", req.InstanceID, "op", "GetAgentInstance", "error", err)
 			writeError(w, http.StatusInternalServerError, "failed to get instance")
 			return
 		}
@@ -130,13 +158,12 @@ func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.CreateTerminalSession(r.Context(), session); err != nil {
-		log.Printf("[terminal] Failed to create session: %v", err)
+		slog.ErrorContext(r.Context(), "terminal", "detail", "Failed to create session", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to create session")
 		return
 	}
 
-	log.Printf("[terminal] Created session %s (container=%s, node=%s, status=pending)",
-		sessionID, containerName, nodeID)
+	slog.InfoContext(r.Context(), "terminal.created", "session_id", sessionID, "container", containerName, "node_id", nodeID)
 
 	writeJSON(w, http.StatusCreated, session)
 }
@@ -147,7 +174,7 @@ func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
 
 	session, err := h.store.GetTerminalSession(r.Context(), sessionID)
 	if err != nil {
-		log.Printf("[terminal] Failed to get session %s: %v", sessionID, err)
+		slog.ErrorContext(r.Context(), "terminal.get.failed", "session_id", sessionID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get session")
 		return
 	}
@@ -165,7 +192,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 
 	session, err := h.store.GetTerminalSession(r.Context(), sessionID)
 	if err != nil {
-		log.Printf("[terminal] Failed to get session %s: %v", sessionID, err)
+		slog.ErrorContext(r.Context(), "terminal.get.failed", "session_id", sessionID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get session")
 		return
 	}
@@ -175,12 +202,12 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := h.store.UpdateTerminalSession(r.Context(), sessionID, model.TerminalStatusClosed, nil, nil); err != nil {
-		log.Printf("[terminal] Failed to update session %s: %v", sessionID, err)
+		slog.ErrorContext(r.Context(), "terminal.update.failed", "session_id", sessionID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to close session")
 		return
 	}
 
-	log.Printf("[terminal] Session %s marked as closed", sessionID)
+	slog.InfoContext(r.Context(), "terminal.closed", "session_id", sessionID)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "session closed",
@@ -191,7 +218,7 @@ func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
 	sessions, err := h.store.ListTerminalSessions(r.Context())
 	if err != nil {
-		log.Printf("[terminal] Failed to list sessions: %v", err)
+		slog.ErrorContext(r.Context(), "terminal", "detail", "Failed to list sessions", "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list sessions")
 		return
 	}
@@ -208,7 +235,7 @@ func (h *Handler) Proxy(w http.ResponseWriter, r *http.Request) {
 
 	session, err := h.store.GetTerminalSession(r.Context(), sessionID)
 	if err != nil {
-		log.Printf("[terminal] Failed to get session %s: %v", sessionID, err)
+		slog.ErrorContext(r.Context(), "terminal.get.failed", "session_id", sessionID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to get session")
 		return
 	}
@@ -216,13 +243,30 @@ func (h *Handler) Proxy(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusNotFound, "session not found or not running")
 		return
 	}
+	if !canAccess(r.Context(), session) {
+		writeError(w, http.StatusForbidden, "terminal session belongs to another user")
+		return
+	}
+	if reason := h.endedReason(r.Context(), session); reason != "" {
+		h.close(r.Context(), session.ID, reason)
+		writeError(w, http.StatusGone, "terminal session "+reason)
+		return
+	}
 
 	if session.Port == nil {
 		writeError(w, http.StatusServiceUnavailable, "terminal not ready")
 		return
 	}
+	if h.nodes == nil || session.NodeID == nil || *session.NodeID == "" {
+		writeError(w, http.StatusServiceUnavailable, "terminal node is not reachable")
+		return
+	}
 
-	backendAddr := fmt.Sprintf("localhost:%d", *session.Port)
+	// 经节点反向隧道连接节点本地的 ttyd，不直连节点地址
+	nodeID := *session.NodeID
+	dial := func(ctx context.Context) (net.Conn, error) {
+		return h.nodes.DialNode(ctx, nodeID, tunnel.KindTerminal, map[string]string{"session_id": session.ID})
+	}
 
 	// 剥离前缀
 	r.URL.Path = strings.TrimPrefix(r.URL.Path, fmt.Sprintf("/terminal/%s", sessionID))
@@ -232,13 +276,16 @@ func (h *Handler) Proxy(w http.ResponseWriter, r *http.Request) {
 
 	// WebSocket 请求：使用 TCP 双向转发
 	if isWebSocketUpgrade(r) {
-		h.proxyWebSocket(w, r, backendAddr)
+		h.proxyWebSocket(w, r, dial, session.ExpiresAt)
 		return
 	}
 
-	// 普通 HTTP 请求：标准反向代理
-	target, _ := url.Parse(fmt.Sprintf("http://%s", backendAddr))
-	proxy := httputil.NewSingleHostReverseProxy(target)
+	// 普通 HTTP 请求：标准反向代理，每个请求打开一条隧道流
+	proxy := httputil.NewSingleHostReverseProxy(&url.URL{Scheme: "http", Host: "ttyd"})
+	proxy.Transport = &http.Transport{
+		DialContext:       func(ctx context.Context, _, _ string) (net.Conn, error) { return dial(ctx) },
+		DisableKeepAlives: true,
+	}
 	proxy.ServeHTTP(w, r)
 }
 
@@ -248,11 +295,15 @@ func isWebSocketUpgrade(r *http.Request) bool {
 }
 
 // proxyWebSocket 使用 TCP hijack 双向代理 WebSocket
-func (h *Handler) proxyWebSocket(w http.ResponseWriter, r *http.Request, backendAddr string) {
+//
+// dial 打开到节点 ttyd 的连接；expiresAt 非空时作为两端连接的截止时间，会话到期时连接自动断开。
+func (h *Handler) proxyWebSocket(w http.ResponseWriter, r *http.Request, dial func(context.Context) (net.Conn, error), expiresAt *time.Time) {
 	// 连接后端
-	backendConn, err := net.DialTimeout("tcp", backendAddr, 5*time.Second)
+	dialCtx, cancel := context.WithTimeout(r.Context(), backendDialTimeout)
+	backendConn, err := dial(dialCtx)
+	cancel()
 	if err != nil {
-		log.Printf("[terminal] WebSocket backend dial failed: %v", err)
+		slog.ErrorContext(r.Context(), "terminal", "detail", "WebSocket backend dial failed", "error", err)
 		writeError(w, http.StatusBadGateway, "backend unavailable")
 		return
 	}
@@ -266,14 +317,18 @@ func (h *Handler) proxyWebSocket(w http.ResponseWriter, r *http.Request, backend
 	}
 	clientConn, clientBuf, err := hj.Hijack()
 	if err != nil {
-		log.Printf("[terminal] Hijack failed: %v", err)
+		slog.ErrorContext(r.Context(), "terminal", "detail", "Hijack failed", "error", err)
 		return
 	}
 	defer clientConn.Close()
+	if expiresAt != nil {
+		clientConn.SetDeadline(*expiresAt)
+		backendConn.SetDeadline(*expiresAt)
+	}
 
 	// 将原始请求转发给后端
 	if err := r.Write(backendConn); err != nil {
-		log.Printf("[terminal] Failed to write request to backend: %v", err)
+		slog.ErrorContext(r.Context(), "terminal", "detail", "Failed to write request to backend", "error", err)
 		return
 	}
 
@@ -313,7 +368,7 @@ func (h *Handler) ListPending(w http.ResponseWriter, r *http.Request) {
 
 	sessions, err := h.store.ListPendingTerminalSessions(r.Context(), nodeID)
 	if err != nil {
-		log.Printf("[terminal] Failed to list pending sessions for node %s: %v", nodeID, err)
+		slog.ErrorContext(r.Context(), "terminal.list.failed", "node_id", nodeID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to list sessions")
 		return
 	}
@@ -363,13 +418,12 @@ func (h *Handler) UpdateStatus(w http.ResponseWriter, r *http.Request) {
 			writeError(w, http.StatusNotFound, "session not found")
 			return
 		}
-		log.Printf("[terminal] Failed to update session %s: %v", sessionID, err)
+		slog.ErrorContext(r.Context(), "terminal.update.failed", "session_id", sessionID, "error", err)
 		writeError(w, http.StatusInternalServerError, "failed to update session")
 		return
 	}
 
-	log.Printf("[terminal] Session %s updated (status=%s, port=%v, url=%v)",
-		sessionID, req.Status, req.Port, req.URL)
+	slog.InfoContext(r.Context(), "terminal.updated", "session_id", sessionID, "status", req.Status, "port", req.Port, "url", req.URL)
 
 	writeJSON(w, http.StatusOK, map[string]interface{}{
 		"message": "session updated",
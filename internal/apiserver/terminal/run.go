@@ -0,0 +1,210 @@
+// Package terminal Run 终端附加
+//
+// 为运行中的 Run 创建终端会话：会话指向 Run 所在节点的 Agent 容器，
+// 由 NodeManager 的终端工作线程启动 ttyd（docker exec 进入容器），
+// 用户经 API Server 的 /terminal/{id}/ 代理连接。
+//
+// 访问控制与过期：
+//   - 只有管理员可以为 Run 创建终端会话（任务没有所有者，无法按创建者授权）
+//   - 只有会话创建者与管理员可以连接（通过认证中间件解析的 JWT 用户判断）
+//   - 会话在 ExpiresAt 关闭：WebSocket 连接在到期时断开，过期巡检将会话标记为 closed，NodeManager 随后停止 ttyd
+//   - Run 结束后不再接受新连接
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// defaultRunTerminalTTL Run 终端会话默认有效期
+	defaultRunTerminalTTL = 15 * time.Minute
+
+	// maxRunTerminalTTL Run 终端会话最长有效期
+	maxRunTerminalTTL = time.Hour
+
+	// runStartedScanLimit 查找 run_started 事件时读取的事件数（run_started 总在 Run 最前面）
+	runStartedScanLimit = 100
+
+	// expiryInterval 过期会话巡检间隔
+	expiryInterval = time.Minute
+)
+
+// AttachRun 为运行中的 Run 创建终端会话
+// POST /api/v1/runs/{id}/terminal
+//
+// 请求体（可选）：{"ttl_seconds": 900}，默认 15 分钟，最长 1 小时。
+// 只支持 docker 执行后端（容器名取自 run_started 事件），process 后端的 Run 返回 409。
+// 仅管理员可调用（非管理员返回 403）。
+// 返回 pending 状态的会话，NodeManager 启动终端后会话变为 running，url 为代理地址。
+func (h *Handler) AttachRun(w http.ResponseWriter, r *http.Request) {
+	runID := r.PathValue("id")
+
+	var req struct {
+		TTLSeconds int `json:"ttl_seconds"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	ttl := defaultRunTerminalTTL
+	if req.TTLSeconds > 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	if ttl > maxRunTerminalTTL {
+		writeError(w, http.StatusBadRequest, "ttl_seconds must not exceed 3600")
+		return
+	}
+
+	run, err := h.store.GetRun(r.Context(), runID)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "terminal.run.failed", "run_id", runID, "op", "GetRun", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return
+	}
+	if run.Status != model.RunStatusRunning || run.NodeID == nil || *run.NodeID == "" {
+		writeError(w, http.StatusConflict, "run is not running")
+		return
+	}
+
+	container, status, msg := h.runContainer(r.Context(), runID)
+	if container == "" {
+		writeError(w, status, msg)
+		return
+	}
+
+	now := h.now()
+	expiresAt := now.Add(ttl)
+	session := &model.TerminalSession{
+		ID:            generateID("term"),
+		RunID:         &runID,
+		ContainerName: container,
+		NodeID:        run.NodeID,
+		Status:        model.TerminalStatusPending,
+		CreatedAt:     now,
+		ExpiresAt:     &expiresAt,
+	}
+	if user := auth.GetAuthUser(r.Context()); user != nil {
+		session.CreatedBy = &user.ID
+	}
+
+	if err := h.store.CreateTerminalSession(r.Context(), session); err != nil {
+		slog.ErrorContext(r.Context(), "terminal.run.failed", "run_id", runID, "op", "CreateTerminalSession", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create session")
+		return
+	}
+
+	slog.InfoContext(r.Context(), "terminal.run.created", "session_id", session.ID, "run_id", runID,
+		"container", container, "node_id", *run.NodeID, "expires_at", expiresAt.Format(time.RFC3339))
+
+	writeJSON(w, http.StatusCreated, session)
+}
+
+// runContainer 从 run_started 事件中取 Run 的执行容器，失败时返回空容器名与错误响应
+func (h *Handler) runContainer(ctx context.Context, runID string) (string, int, string) {
+	events, err := h.store.GetEventsByRun(ctx, runID, 0, runStartedScanLimit)
+	if err != nil {
+		slog.ErrorContext(ctx, "terminal.run.failed", "run_id", runID, "op", "GetEventsByRun", "error", err)
+		return "", http.StatusInternalServerError, "failed to get run events"
+	}
+
+	// 重新排队的 Run 可能有多个 run_started，取最后一个
+	var started *model.Event
+	for _, e := range events {
+		if e.Type == string(model.EventTypeRunStarted) {
+			started = e
+		}
+	}
+	if started == nil {
+		return "", http.StatusConflict, "run has not started yet"
+	}
+	var payload struct {
+		Backend   string `json:"backend"`
+		Container string `json:"container"`
+	}
+	json.Unmarshal(started.Payload, &payload)
+	if payload.Backend != "" && payload.Backend != model.ExecBackendDocker {
+		return "", http.StatusConflict, "terminal attach requires the docker execution backend"
+	}
+	container := payload.Container
+	if container == "" {
+		return "", http.StatusConflict, "run container is unknown"
+	}
+	return container, 0, ""
+}
+
+// canAccess 判断请求者能否连接会话
+//
+// 未记录创建者的会话（实例终端、未启用认证时创建的会话）不限制；
+// 节点凭证不能连接终端。
+func canAccess(ctx context.Context, session *model.TerminalSession) bool {
+	if auth.GetNodeIdentity(ctx) != nil {
+		return false
+	}
+	if session.CreatedBy == nil {
+		return true
+	}
+	user := auth.GetAuthUser(ctx)
+	if user == nil {
+		return true // 未启用认证
+	}
+	return user.ID == *session.CreatedBy || user.Role == auth.UserRoleAdmin
+}
+
+// endedReason 返回会话不再可用的原因（过期或 Run 已结束），可用时返回空
+func (h *Handler) endedReason(ctx context.Context, session *model.TerminalSession) string {
+	if session.IsExpired(h.now()) {
+		return "expired"
+	}
+	if session.RunID == nil {
+		return ""
+	}
+	run, err := h.store.GetRun(ctx, *session.RunID)
+	if err != nil {
+		return "" // 存储错误时不中断终端
+	}
+	if run == nil || run.Status != model.RunStatusRunning {
+		return "ended with run"
+	}
+	return ""
+}
+
+// close 将会话标记为 closed（NodeManager 检测到后停止 ttyd）
+func (h *Handler) close(ctx context.Context, sessionID, reason string) {
+	if err := h.store.UpdateTerminalSession(ctx, sessionID, model.TerminalStatusClosed, nil, nil); err != nil {
+		slog.ErrorContext(ctx, "terminal.close.failed", "session_id", sessionID, "error", err)
+		return
+	}
+	slog.InfoContext(ctx, "terminal.closed", "session_id", sessionID, "reason", reason)
+}
+
+// StartExpiry 定期关闭已过期的终端会话，ctx 取消时返回
+func (h *Handler) StartExpiry(ctx context.Context) {
+	ticker := time.NewTicker(expiryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := h.store.CloseExpiredTerminalSessions(ctx)
+			if err != nil {
+				slog.ErrorContext(ctx, "terminal", "detail", "Failed to close expired sessions", "error", err)
+			} else if n > 0 {
+				slog.InfoContext(ctx, "terminal.expired.closed", "count", n)
+			}
+		}
+	}
+}
@@ -0,0 +1,290 @@
+package terminal
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存实现的终端会话存储
+type memStore struct {
+	sessions map[string]*model.TerminalSession
+	runs     map[string]*model.Run
+	events   map[string][]*model.Event
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		sessions: map[string]*model.TerminalSession{},
+		runs:     map[string]*model.Run{},
+		events:   map[string][]*model.Event{},
+	}
+}
+
+func (m *memStore) CreateTerminalSession(_ context.Context, s *model.TerminalSession) error {
+	copied := *s
+	m.sessions[s.ID] = &copied
+	return nil
+}
+
+func (m *memStore) GetTerminalSession(_ context.Context, id string) (*model.TerminalSession, error) {
+	return m.sessions[id], nil
+}
+
+func (m *memStore) ListTerminalSessions(context.Context) ([]*model.TerminalSession, error) {
+	return nil, nil
+}
+
+func (m *memStore) ListTerminalSessionsByNode(context.Context, string) ([]*model.TerminalSession, error) {
+	return nil, nil
+}
+
+func (m *memStore) ListPendingTerminalSessions(context.Context, string) ([]*model.TerminalSession, error) {
+	return nil, nil
+}
+
+func (m *memStore) UpdateTerminalSession(_ context.Context, id string, status model.TerminalSessionStatus, port *int, url *string) error {
+	s := m.sessions[id]
+	s.Status, s.Port, s.URL = status, port, url
+	return nil
+}
+
+func (m *memStore) TransitionTerminalSession(_ context.Context, id string, from, to model.TerminalSessionStatus, port *int, url *string) (bool, error) {
+	s := m.sessions[id]
+	if s == nil || s.Status != from {
+		return false, nil
+	}
+	s.Status = to
+	return true, nil
+}
+
+func (m *memStore) DeleteTerminalSession(_ context.Context, id string) error {
+	delete(m.sessions, id)
+	return nil
+}
+
+func (m *memStore) CleanupExpiredTerminalSessions(context.Context) (int64, error) { return 0, nil }
+
+func (m *memStore) CloseExpiredTerminalSessions(context.Context) (int64, error) {
+	var n int64
+	for _, s := range m.sessions {
+		if s.IsExpired(time.Now()) && !s.IsTerminated() {
+			s.Status = model.TerminalStatusClosed
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (m *memStore) GetAgentInstance(context.Context, string) (*model.Instance, error) {
+	return nil, nil
+}
+
+func (m *memStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	return m.runs[id], nil
+}
+
+func (m *memStore) GetEventsByRun(_ context.Context, runID string, _ int, _ int) ([]*model.Event, error) {
+	return m.events[runID], nil
+}
+
+// addRunningRun 添加运行中的 Run 及其 run_started 事件
+func (m *memStore) addRunningRun(id, backend, container string) {
+	node := "node-1"
+	m.runs[id] = &model.Run{ID: id, Status: model.RunStatusRunning, NodeID: &node}
+	payload, _ := json.Marshal(map[string]interface{}{"node_id": node, "backend": backend, "container": container})
+	m.events[id] = []*model.Event{{RunID: id, Seq: 1, Type: string(model.EventTypeRunStarted), Payload: payload}}
+}
+
+func attach(h *Handler, runID, body string, user *auth.AuthUser) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/runs/"+runID+"/terminal", strings.NewReader(body))
+	if user != nil {
+		req = req.WithContext(auth.WithAuthUser(req.Context(), user))
+	} else {
+		req = req.WithContext(auth.WithAuthDisabled(req.Context()))
+	}
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	return w
+}
+
+func TestAttachRun(t *testing.T) {
+	store := newMemStore()
+	store.addRunningRun("run-1", model.ExecBackendDocker, "agent_acc1")
+	h := NewHandler(store)
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h.now = func() time.Time { return now }
+
+	w := attach(h, "run-1", `{"ttl_seconds": 300}`, &auth.AuthUser{ID: "user-1", Role: auth.UserRoleAdmin})
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var session model.TerminalSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &session))
+	assert.Equal(t, model.TerminalStatusPending, session.Status)
+	assert.Equal(t, "agent_acc1", session.ContainerName)
+	assert.Equal(t, "node-1", *session.NodeID)
+	assert.Equal(t, "run-1", *session.RunID)
+	assert.Equal(t, "user-1", *session.CreatedBy)
+	assert.True(t, session.ExpiresAt.Equal(now.Add(5*time.Minute)))
+	assert.Contains(t, store.sessions, session.ID)
+
+	// 未指定有效期时使用默认值
+	w = attach(h, "run-1", "", nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var anonymous model.TerminalSession
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &anonymous))
+	assert.True(t, anonymous.ExpiresAt.Equal(now.Add(defaultRunTerminalTTL)))
+	assert.Nil(t, anonymous.CreatedBy)
+}
+
+func TestAttachRun_Rejected(t *testing.T) {
+	store := newMemStore()
+	store.addRunningRun("run-proc", model.ExecBackendProcess, "")
+	store.addRunningRun("run-docker", model.ExecBackendDocker, "agent_acc1")
+	node := "node-1"
+	store.runs["run-done"] = &model.Run{ID: "run-done", Status: model.RunStatusDone, NodeID: &node}
+	store.runs["run-new"] = &model.Run{ID: "run-new", Status: model.RunStatusRunning, NodeID: &node}
+	h := NewHandler(store)
+
+	tests := []struct {
+		name string
+		run  string
+		body string
+		code int
+	}{
+		{"unknown run", "run-missing", "", http.StatusNotFound},
+		{"finished run", "run-done", "", http.StatusConflict},
+		{"no run_started yet", "run-new", "", http.StatusConflict},
+		{"process backend", "run-proc", "", http.StatusConflict},
+		{"ttl too long", "run-docker", `{"ttl_seconds": 7200}`, http.StatusBadRequest},
+		{"invalid body", "run-docker", `{`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := attach(h, tt.run, tt.body, nil)
+			assert.Equal(t, tt.code, w.Code, w.Body.String())
+		})
+	}
+	assert.Empty(t, store.sessions)
+}
+
+func TestAttachRun_AdminOnly(t *testing.T) {
+	store := newMemStore()
+	store.addRunningRun("run-1", model.ExecBackendDocker, "agent_acc1")
+	h := NewHandler(store)
+
+	w := attach(h, "run-1", "", &auth.AuthUser{ID: "user-1", Role: "user"})
+	assert.Equal(t, http.StatusForbidden, w.Code, w.Body.String())
+	assert.Empty(t, store.sessions)
+}
+
+func TestProxy_RunSessionAccess(t *testing.T) {
+	store := newMemStore()
+	store.addRunningRun("run-1", model.ExecBackendDocker, "agent_acc1")
+	h := NewHandler(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	runID, owner := "run-1", "user-1"
+	expires := time.Now().Add(time.Hour)
+	port := 1 // 访问检查先于连接后端
+	newSession := func(id string) {
+		store.sessions[id] = &model.TerminalSession{
+			ID: id, RunID: &runID, CreatedBy: &owner, Status: model.TerminalStatusRunning,
+			Port: &port, ExpiresAt: &expires,
+		}
+	}
+	proxy := func(id string, ctx context.Context) int {
+		req := httptest.NewRequest(http.MethodGet, "/terminal/"+id+"/", nil).WithContext(ctx)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	newSession("term-1")
+	other := auth.WithAuthUser(context.Background(), &auth.AuthUser{ID: "user-2", Role: "user"})
+	assert.Equal(t, http.StatusForbidden, proxy("term-1", other))
+	node := auth.WithNodeIdentity(context.Background(), "node-1")
+	assert.Equal(t, http.StatusForbidden, proxy("term-1", node))
+
+	// 过期会话被关闭
+	past := time.Now().Add(-time.Minute)
+	store.sessions["term-1"].ExpiresAt = &past
+	ownerCtx := auth.WithAuthUser(context.Background(), &auth.AuthUser{ID: owner, Role: "user"})
+	assert.Equal(t, http.StatusGone, proxy("term-1", ownerCtx))
+	assert.Equal(t, model.TerminalStatusClosed, store.sessions["term-1"].Status)
+
+	// Run 结束后会话被关闭（管理员同样适用）
+	newSession("term-2")
+	store.runs["run-1"].Status = model.RunStatusDone
+	admin := auth.WithAuthUser(context.Background(), &auth.AuthUser{ID: "admin", Role: auth.UserRoleAdmin})
+	assert.Equal(t, http.StatusGone, proxy("term-2", admin))
+	assert.Equal(t, model.TerminalStatusClosed, store.sessions["term-2"].Status)
+}
+
+// pipeDialer 以内存连接模拟节点隧道，另一端由 ttyd 处理
+type pipeDialer struct {
+	ttyd   http.Handler
+	params []map[string]string
+}
+
+func (d *pipeDialer) DialNode(_ context.Context, nodeID, kind string, params map[string]string) (net.Conn, error) {
+	d.params = append(d.params, params)
+	client, server := net.Pipe()
+	go http.Serve(&oneConnListener{conn: server}, d.ttyd)
+	return client, nil
+}
+
+// oneConnListener 只返回一个连接的 Listener
+type oneConnListener struct {
+	conn net.Conn
+	done bool
+}
+
+func (l *oneConnListener) Accept() (net.Conn, error) {
+	if l.done {
+		return nil, net.ErrClosed
+	}
+	l.done = true
+	return l.conn, nil
+}
+func (l *oneConnListener) Close() error   { return nil }
+func (l *oneConnListener) Addr() net.Addr { return l.conn.LocalAddr() }
+
+func TestProxy_ViaNodeTunnel(t *testing.T) {
+	store := newMemStore()
+	node, port := "node-1", 7681
+	store.sessions["term-1"] = &model.TerminalSession{ID: "term-1", NodeID: &node, Port: &port, Status: model.TerminalStatusRunning}
+	h := NewHandler(store)
+	mux := http.NewServeMux()
+	h.RegisterRoutes(mux)
+
+	// 未配置节点隧道时不可用（不再直连节点地址）
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/terminal/term-1/", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	dialer := &pipeDialer{ttyd: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ttyd " + r.URL.Path))
+	})}
+	h.SetNodeDialer(dialer)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/terminal/term-1/token", nil))
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, "ttyd /token", w.Body.String())
+	require.Len(t, dialer.params, 1)
+	assert.Equal(t, "term-1", dialer.params[0]["session_id"])
+}
@@ -0,0 +1,306 @@
+// Package transcript 从 Run 事件重建会话记录
+//
+// 会话记录按事件顺序还原一次执行的过程：任务提示词、Agent 消息、工具调用与结果、命令及输出、
+// 文件操作、审批与错误，省略心跳、用量等内部事件。记录以 JSON Lines、Markdown 或自包含的 HTML 输出，
+// 可以直接分享给没有管理后台权限的人做事后复盘。
+//
+// 事件逐条转换（Entries），写入器逐条输出（Writer），调用方分批读取事件即可流式导出任意长度的 Run。
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Kind 会话记录条目类型
+type Kind string
+
+const (
+	KindPrompt        Kind = "prompt"         // 任务提示词
+	KindMessage       Kind = "message"        // 消息（Role 区分 assistant / user）
+	KindThinking      Kind = "thinking"       // 思考过程
+	KindToolCall      Kind = "tool_call"      // 工具调用
+	KindToolResult    Kind = "tool_result"    // 工具结果
+	KindCommand       Kind = "command"        // 执行命令
+	KindCommandOutput Kind = "command_output" // 命令输出
+	KindFile          Kind = "file"           // 文件读写删除
+	KindApproval      Kind = "approval"       // 人工审批
+	KindStatus        Kind = "status"         // Run 开始、结束与钩子
+	KindError         Kind = "error"          // 错误与警告
+)
+
+// Entry 会话记录中的一条
+type Entry struct {
+	Seq       int        `json:"seq"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Kind      Kind       `json:"kind"`
+	Role      string     `json:"role,omitempty"`      // 消息角色：assistant / user
+	Tool      string     `json:"tool,omitempty"`      // 工具名称
+	Title     string     `json:"title,omitempty"`     // 简短描述：命令行、文件路径、状态等
+	Content   string     `json:"content,omitempty"`   // 正文：消息文本、工具输入输出、命令输出
+	ExitCode  *int       `json:"exit_code,omitempty"` // 命令与钩子的退出码
+	IsError   bool       `json:"is_error,omitempty"`  // 失败的工具结果、命令、错误
+}
+
+// Header 会话记录头部：Run 基本信息
+type Header struct {
+	RunID      string     `json:"run_id"`
+	TaskID     string     `json:"task_id"`
+	TaskName   string     `json:"task_name,omitempty"`
+	AgentType  string     `json:"agent_type,omitempty"`
+	Status     string     `json:"status"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	ExportedAt time.Time  `json:"exported_at"`
+}
+
+// NewHeader 从 Run 构造头部（任务名称与 Agent 类型取自 Run 快照）
+func NewHeader(run *model.Run, now time.Time) Header {
+	h := Header{
+		RunID:      run.ID,
+		TaskID:     run.TaskID,
+		Status:     string(run.Status),
+		StartedAt:  run.StartedAt,
+		FinishedAt: run.FinishedAt,
+		ExportedAt: now,
+	}
+	if run.Error != nil {
+		h.Error = *run.Error
+	}
+	snapshot := runSnapshot(run)
+	h.TaskName, h.AgentType = snapshot.Name, snapshot.Agent.Type
+	return h
+}
+
+// PromptEntry Run 快照中的任务提示词（作为会话的第一条），快照中没有提示词时返回 nil
+func PromptEntry(run *model.Run) *Entry {
+	prompt := strings.TrimSpace(runSnapshot(run).Prompt)
+	if prompt == "" {
+		return nil
+	}
+	return &Entry{Kind: KindPrompt, Role: "user", Content: prompt, Timestamp: run.StartedAt}
+}
+
+func runSnapshot(run *model.Run) *model.RunSnapshot {
+	var s model.RunSnapshot
+	if len(run.Snapshot) > 0 {
+		json.Unmarshal(run.Snapshot, &s)
+	}
+	return &s
+}
+
+// Entries 将一个事件转换为会话记录条目（内部事件返回空）
+//
+// message 事件同时支持统一格式 {"content": "..."} 与 Agent CLI 原始格式
+// {"type": "assistant", "message": {"content": [{"type": "text"}, {"type": "tool_use"}, ...]}}，
+// 原始格式中的一条消息可能产生多条记录（文本与工具调用）。
+func Entries(e *model.Event) []Entry {
+	var p map[string]interface{}
+	if len(e.Payload) > 0 {
+		json.Unmarshal(e.Payload, &p)
+	}
+	base := Entry{Seq: e.Seq}
+	if !e.Timestamp.IsZero() {
+		ts := e.Timestamp
+		base.Timestamp = &ts
+	}
+	with := func(fill func(*Entry)) []Entry {
+		entry := base
+		fill(&entry)
+		return []Entry{entry}
+	}
+
+	switch model.EventType(e.Type) {
+	case model.EventTypeMessage:
+		return messageEntries(base, p)
+	case model.EventTypeThinking:
+		if text := str(p, "content"); text != "" {
+			return with(func(en *Entry) { en.Kind, en.Content = KindThinking, text })
+		}
+	case model.EventTypeToolUseStart:
+		return with(func(en *Entry) { en.Kind, en.Tool, en.Content = KindToolCall, str(p, "tool"), format(p["input"]) })
+	case model.EventTypeToolResult:
+		return with(func(en *Entry) {
+			en.Kind, en.Tool, en.Content = KindToolResult, str(p, "tool"), format(p["output"])
+			if ok, present := p["success"].(bool); present && !ok {
+				en.IsError = true
+			}
+		})
+	case model.EventTypeCommand:
+		return with(func(en *Entry) { en.Kind, en.Title = KindCommand, commandLine(p) })
+	case model.EventTypeCommandOutput:
+		return with(func(en *Entry) {
+			en.Kind, en.Content = KindCommandOutput, joinNonEmpty("\n", str(p, "stdout"), str(p, "stderr"))
+			if en.Content == "" && e.Raw != nil {
+				en.Content = *e.Raw
+			}
+			en.ExitCode = intPtr(p, "exit_code")
+			en.IsError = en.ExitCode != nil && *en.ExitCode != 0
+		})
+	case model.EventTypeFileRead, model.EventTypeFileWrite, model.EventTypeFileDelete:
+		action := strings.TrimPrefix(e.Type, "file_")
+		return with(func(en *Entry) { en.Kind, en.Title = KindFile, action+" "+str(p, "path") })
+	case model.EventTypeApprovalRequest, model.EventTypeApprovalRequired:
+		return with(func(en *Entry) {
+			en.Kind = KindApproval
+			en.Title = "approval requested: " + joinNonEmpty(" ", str(p, "operation"), str(p, "action"), str(p, "target"))
+			en.Content = str(p, "reason")
+		})
+	case model.EventTypeApprovalResponse:
+		return with(func(en *Entry) {
+			en.Kind, en.Title, en.Content = KindApproval, "approval rejected", str(p, "comment")
+			if approved, _ := p["approved"].(bool); approved {
+				en.Title = "approval granted"
+			}
+		})
+	case model.EventTypeRunStarted:
+		return with(func(en *Entry) {
+			en.Kind, en.Title = KindStatus, joinNonEmpty(" on ", "run started", str(p, "node_id"))
+		})
+	case model.EventTypeRunCompleted, model.EventTypeRunFailed:
+		return with(func(en *Entry) {
+			en.Kind, en.Title = KindStatus, "run "+strings.TrimPrefix(e.Type, "run_")
+			if status := str(p, "status"); status != "" {
+				en.Title = "run " + status
+			}
+			if msg := str(p, "error"); msg != "" {
+				en.Content, en.IsError = msg, true
+			}
+		})
+	case model.EventTypeHookCompleted:
+		return with(func(en *Entry) {
+			en.Kind, en.Title, en.Content = KindStatus, joinNonEmpty(" ", str(p, "phase"), "hook", str(p, "name")), str(p, "output")
+			en.ExitCode = intPtr(p, "exit_code")
+			en.IsError = en.ExitCode != nil && *en.ExitCode != 0
+		})
+	case model.EventTypeResult:
+		if text := str(p, "result"); text != "" {
+			return with(func(en *Entry) { en.Kind, en.Role, en.Title, en.Content = KindMessage, "assistant", "result", text })
+		}
+	case model.EventTypeError, model.EventTypeWarning:
+		return with(func(en *Entry) {
+			en.Kind, en.Title, en.Content = KindError, e.Type, str(p, "message")
+			en.IsError = e.Type == string(model.EventTypeError)
+		})
+	}
+	return nil
+}
+
+// messageEntries 转换 message 事件
+func messageEntries(base Entry, p map[string]interface{}) []Entry {
+	if text, ok := p["content"].(string); ok {
+		if text == "" {
+			return nil
+		}
+		entry := base
+		entry.Kind, entry.Role, entry.Content = KindMessage, "assistant", text
+		switch p["type"] {
+		case "thinking":
+			entry.Kind, entry.Role = KindThinking, ""
+		case "user":
+			entry.Role = "user"
+		}
+		return []Entry{entry}
+	}
+
+	msg, _ := p["message"].(map[string]interface{})
+	role, _ := msg["role"].(string)
+	if role == "" {
+		role, _ = p["type"].(string)
+	}
+	if role != "user" {
+		role = "assistant"
+	}
+	blocks, _ := msg["content"].([]interface{})
+	var out []Entry
+	for _, b := range blocks {
+		block, _ := b.(map[string]interface{})
+		entry := base
+		switch block["type"] {
+		case "text":
+			entry.Kind, entry.Role, entry.Content = KindMessage, role, str(block, "text")
+		case "thinking":
+			entry.Kind, entry.Content = KindThinking, str(block, "thinking")
+		case "tool_use":
+			entry.Kind, entry.Tool, entry.Content = KindToolCall, str(block, "name"), format(block["input"])
+		case "tool_result":
+			entry.Kind, entry.Content = KindToolResult, format(block["content"])
+			entry.IsError, _ = block["is_error"].(bool)
+		default:
+			continue
+		}
+		if entry.Content != "" || entry.Kind == KindToolCall {
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+// commandLine 拼接命令与参数
+func commandLine(p map[string]interface{}) string {
+	parts := []string{str(p, "command")}
+	if args, ok := p["args"].([]interface{}); ok {
+		for _, a := range args {
+			parts = append(parts, fmt.Sprint(a))
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
+
+// format 工具输入输出的文本形式：字符串原样输出，结构化内容输出缩进的 JSON；
+// 工具结果常见的 [{"type": "text", "text": "..."}] 取出文本
+func format(v interface{}) string {
+	switch val := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return val
+	case []interface{}:
+		var texts []string
+		for _, item := range val {
+			block, ok := item.(map[string]interface{})
+			if !ok || block["type"] != "text" {
+				texts = nil
+				break
+			}
+			texts = append(texts, str(block, "text"))
+		}
+		if texts != nil {
+			return strings.Join(texts, "\n")
+		}
+	}
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func str(p map[string]interface{}, key string) string {
+	s, _ := p[key].(string)
+	return s
+}
+
+func intPtr(p map[string]interface{}, key string) *int {
+	f, ok := p[key].(float64)
+	if !ok {
+		return nil
+	}
+	n := int(f)
+	return &n
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, s := range parts {
+		if s != "" {
+			out = append(out, s)
+		}
+	}
+	return strings.Join(out, sep)
+}
@@ -0,0 +1,72 @@
+package transcript
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/model"
+)
+
+func event(seq int, typ, payload string) *model.Event {
+	return &model.Event{RunID: "run-1", Seq: seq, Type: typ, Payload: json.RawMessage(payload)}
+}
+
+func TestEntries(t *testing.T) {
+	// 统一格式
+	got := Entries(event(1, "message", `{"content":"Looking at the test"}`))
+	require.Len(t, got, 1)
+	assert.Equal(t, KindMessage, got[0].Kind)
+	assert.Equal(t, "assistant", got[0].Role)
+	assert.Equal(t, "Looking at the test", got[0].Content)
+
+	// CLI 原始格式：一条消息产生文本与工具调用两条记录
+	got = Entries(event(2, "message", `{"type":"assistant","message":{"role":"assistant","content":[
+		{"type":"text","text":"Running tests"},
+		{"type":"tool_use","name":"Bash","input":{"command":"go test ./..."}}]}}`))
+	require.Len(t, got, 2)
+	assert.Equal(t, KindToolCall, got[1].Kind)
+	assert.Equal(t, "Bash", got[1].Tool)
+	assert.Contains(t, got[1].Content, `"command": "go test ./..."`)
+
+	got = Entries(event(3, "message", `{"type":"user","message":{"role":"user","content":[
+		{"type":"tool_result","content":[{"type":"text","text":"FAIL TestFoo"}],"is_error":true}]}}`))
+	require.Len(t, got, 1)
+	assert.Equal(t, KindToolResult, got[0].Kind)
+	assert.Equal(t, "FAIL TestFoo", got[0].Content)
+	assert.True(t, got[0].IsError)
+
+	got = Entries(event(4, "command_output", `{"stdout":"ok","stderr":"warn","exit_code":1}`))
+	require.Len(t, got, 1)
+	assert.Equal(t, "ok\nwarn", got[0].Content)
+	require.NotNil(t, got[0].ExitCode)
+	assert.True(t, got[0].IsError)
+
+	assert.Equal(t, "write src/main.go", Entries(event(5, "file_write", `{"path":"src/main.go"}`))[0].Title)
+	assert.Equal(t, "approval granted", Entries(event(6, "approval_response", `{"approved":true}`))[0].Title)
+
+	// 内部事件省略
+	for _, typ := range []string{"heartbeat", "usage", "checkpoint", "progress"} {
+		assert.Empty(t, Entries(event(7, typ, `{}`)), typ)
+	}
+	assert.Empty(t, Entries(event(8, "message", `{"content":""}`)))
+}
+
+func TestMarkdownFenceEscapesBackticks(t *testing.T) {
+	var b strings.Builder
+	w, err := NewWriter(FormatMarkdown, &b)
+	require.NoError(t, err)
+	require.NoError(t, w.Begin(Header{RunID: "run-1", ExportedAt: time.Unix(0, 0)}))
+	require.NoError(t, w.Write(Entry{Kind: KindToolResult, Content: "```go\nfmt.Println()\n```"}))
+	assert.Contains(t, b.String(), "\n````\n```go\nfmt.Println()\n```\n````\n")
+	assert.Contains(t, b.String(), "# Transcript: run-1")
+}
+
+func TestNewWriterRejectsUnknownFormat(t *testing.T) {
+	_, err := NewWriter("pdf", &strings.Builder{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,270 @@
+package transcript
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+	"time"
+)
+
+// 导出格式
+const (
+	FormatJSONL    = "jsonl"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Writer 流式输出会话记录：Begin 一次、Write 每条记录、End 一次
+type Writer interface {
+	Begin(h Header) error
+	Write(e Entry) error
+	End() error
+}
+
+// NewWriter 创建指定格式的写入器（format 为空时使用 jsonl）
+func NewWriter(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case "", FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatMarkdown:
+		return &markdownWriter{w: w}, nil
+	case FormatHTML:
+		return &htmlWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported transcript format %q (want jsonl, markdown or html)", format)
+	}
+}
+
+// ContentType 格式对应的 Content-Type 与下载文件扩展名
+func ContentType(format string) (contentType, ext string) {
+	switch format {
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8", "md"
+	case FormatHTML:
+		return "text/html; charset=utf-8", "html"
+	default:
+		return "application/x-ndjson", "jsonl"
+	}
+}
+
+// ============================================================================
+// JSON Lines：第一行为头部（kind=run），之后每行一条记录
+// ============================================================================
+
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) Begin(h Header) error {
+	return j.enc.Encode(struct {
+		Kind string `json:"kind"`
+		Header
+	}{Kind: "run", Header: h})
+}
+
+func (j *jsonlWriter) Write(e Entry) error { return j.enc.Encode(e) }
+
+func (j *jsonlWriter) End() error { return nil }
+
+// ============================================================================
+// Markdown
+// ============================================================================
+
+type markdownWriter struct {
+	w io.Writer
+}
+
+func (m *markdownWriter) Begin(h Header) error {
+	var b strings.Builder
+	title := h.TaskName
+	if title == "" {
+		title = h.RunID
+	}
+	fmt.Fprintf(&b, "# Transcript: %s\n\n", title)
+	fmt.Fprintf(&b, "- **Run:** `%s`\n- **Task:** `%s`\n- **Status:** %s\n", h.RunID, h.TaskID, h.Status)
+	if h.AgentType != "" {
+		fmt.Fprintf(&b, "- **Agent:** %s\n", h.AgentType)
+	}
+	if h.StartedAt != nil {
+		fmt.Fprintf(&b, "- **Started:** %s\n", h.StartedAt.UTC().Format(time.RFC3339))
+	}
+	if h.FinishedAt != nil {
+		fmt.Fprintf(&b, "- **Finished:** %s\n", h.FinishedAt.UTC().Format(time.RFC3339))
+	}
+	if h.Error != "" {
+		fmt.Fprintf(&b, "- **Error:** %s\n", oneLine(h.Error))
+	}
+	fmt.Fprintf(&b, "- **Exported:** %s\n", h.ExportedAt.UTC().Format(time.RFC3339))
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownWriter) Write(e Entry) error {
+	var b strings.Builder
+	b.WriteString("\n")
+	switch e.Kind {
+	case KindPrompt:
+		b.WriteString("## Prompt\n\n" + e.Content + "\n")
+	case KindMessage:
+		heading := "Assistant"
+		if e.Role == "user" {
+			heading = "User"
+		}
+		if e.Title != "" {
+			heading += " (" + e.Title + ")"
+		}
+		b.WriteString("## " + heading + "\n\n" + e.Content + "\n")
+	case KindThinking:
+		b.WriteString("<details><summary>Thinking</summary>\n\n" + e.Content + "\n\n</details>\n")
+	case KindToolCall:
+		b.WriteString("**Tool call:** `" + e.Tool + "`\n")
+		writeFence(&b, e.Content, "json")
+	case KindToolResult:
+		label := "**Tool result**"
+		if e.Tool != "" {
+			label += " `" + e.Tool + "`"
+		}
+		if e.IsError {
+			label += " (error)"
+		}
+		b.WriteString(label + "\n")
+		writeFence(&b, e.Content, "")
+	case KindCommand:
+		b.WriteString("**Command:**\n")
+		writeFence(&b, "$ "+e.Title, "shell")
+	case KindCommandOutput:
+		label := "**Output**"
+		if e.ExitCode != nil {
+			label += fmt.Sprintf(" (exit %d)", *e.ExitCode)
+		}
+		b.WriteString(label + "\n")
+		writeFence(&b, e.Content, "")
+	default:
+		// 文件、审批、状态、错误：单行记录，附带正文时输出代码块
+		line := "> " + strings.ReplaceAll(string(e.Kind), "_", " ") + ": " + oneLine(e.Title)
+		if e.ExitCode != nil {
+			line += fmt.Sprintf(" (exit %d)", *e.ExitCode)
+		}
+		b.WriteString(line + "\n")
+		if e.Content != "" {
+			writeFence(&b, e.Content, "")
+		}
+	}
+	_, err := io.WriteString(m.w, b.String())
+	return err
+}
+
+func (m *markdownWriter) End() error { return nil }
+
+// writeFence 输出代码块，围栏长度大于正文中最长的连续反引号，避免正文提前结束代码块
+func writeFence(b *strings.Builder, content, lang string) {
+	if content == "" {
+		return
+	}
+	longest, run := 0, 0
+	for _, r := range content {
+		if r == '`' {
+			run++
+			longest = max(longest, run)
+		} else {
+			run = 0
+		}
+	}
+	fence := strings.Repeat("`", max(3, longest+1))
+	b.WriteString("\n" + fence + lang + "\n" + strings.TrimRight(content, "\n") + "\n" + fence + "\n")
+}
+
+func oneLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// ============================================================================
+// HTML：自包含页面（内联样式，无外部资源），所有内容转义输出
+// ============================================================================
+
+const htmlStyle = `body{font-family:-apple-system,Segoe UI,Helvetica,Arial,sans-serif;max-width:960px;margin:2rem auto;padding:0 1rem;color:#1f2328;line-height:1.5}
+header{border-bottom:1px solid #d0d7de;margin-bottom:1rem}dl{display:grid;grid-template-columns:max-content auto;gap:.2rem 1rem}dt{font-weight:600}dd{margin:0}
+.entry{margin:.75rem 0;padding:.5rem .75rem;border-left:4px solid #d0d7de}.entry .label{font-size:.85rem;font-weight:600;color:#57606a}
+.prompt,.user{border-color:#8250df}.assistant{border-color:#1a7f37}.thinking{border-color:#d0d7de;color:#57606a}
+.tool_call,.tool_result{border-color:#0969da}.command,.command_output{border-color:#6e7781}.error{border-color:#cf222e;background:#ffebe9}
+pre{background:#f6f8fa;padding:.5rem;overflow-x:auto;white-space:pre-wrap;word-break:break-word;margin:.25rem 0}.text{white-space:pre-wrap}`
+
+type htmlWriter struct {
+	w io.Writer
+}
+
+func (h *htmlWriter) Begin(hd Header) error {
+	var b strings.Builder
+	title := hd.TaskName
+	if title == "" {
+		title = hd.RunID
+	}
+	b.WriteString("<!DOCTYPE html>\n<html><head><meta charset=\"utf-8\"><title>Transcript: " + html.EscapeString(title) + "</title>\n")
+	b.WriteString("<style>" + htmlStyle + "</style></head>\n<body>\n<header><h1>Transcript: " + html.EscapeString(title) + "</h1><dl>")
+	field := func(name, value string) {
+		if value != "" {
+			b.WriteString("<dt>" + name + "</dt><dd>" + html.EscapeString(value) + "</dd>")
+		}
+	}
+	field("Run", hd.RunID)
+	field("Task", hd.TaskID)
+	field("Status", hd.Status)
+	field("Agent", hd.AgentType)
+	if hd.StartedAt != nil {
+		field("Started", hd.StartedAt.UTC().Format(time.RFC3339))
+	}
+	if hd.FinishedAt != nil {
+		field("Finished", hd.FinishedAt.UTC().Format(time.RFC3339))
+	}
+	field("Error", hd.Error)
+	field("Exported", hd.ExportedAt.UTC().Format(time.RFC3339))
+	b.WriteString("</dl></header>\n<main>\n")
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) Write(e Entry) error {
+	class := string(e.Kind)
+	if e.Kind == KindMessage {
+		class = e.Role
+	}
+	if e.IsError {
+		class += " error"
+	}
+	label := strings.ReplaceAll(string(e.Kind), "_", " ")
+	switch {
+	case e.Kind == KindMessage:
+		label = e.Role
+		if e.Title != "" {
+			label += " (" + e.Title + ")"
+		}
+	case e.Tool != "":
+		label += ": " + e.Tool
+	case e.Title != "":
+		label += ": " + e.Title
+	}
+	if e.ExitCode != nil {
+		label += fmt.Sprintf(" (exit %d)", *e.ExitCode)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<section class=\"entry %s\" id=\"seq-%d\"><div class=\"label\">%s</div>", class, e.Seq, html.EscapeString(label))
+	if e.Content != "" {
+		switch e.Kind {
+		case KindPrompt, KindMessage, KindThinking:
+			b.WriteString("<div class=\"text\">" + html.EscapeString(e.Content) + "</div>")
+		default:
+			b.WriteString("<pre>" + html.EscapeString(e.Content) + "</pre>")
+		}
+	}
+	b.WriteString("</section>\n")
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *htmlWriter) End() error {
+	_, err := io.WriteString(h.w, "</main>\n</body></html>\n")
+	return err
+}
@@ -0,0 +1,187 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"path"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	tunnelproto "agents-admin/internal/shared/tunnel"
+)
+
+// dialTimeout 打开隧道流的超时时间
+const dialTimeout = 15 * time.Second
+
+// runStore Handler 依赖的存储接口
+type runStore interface {
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+}
+
+// Handler 隧道相关 HTTP 处理器
+type Handler struct {
+	hub   *Hub
+	store runStore
+}
+
+// NewHandler 创建隧道处理器
+func NewHandler(hub *Hub, store runStore) *Handler {
+	return &Handler{hub: hub, store: store}
+}
+
+// RegisterRoutes 注册隧道相关路由（节点建立隧道的 WebSocket 路由见 Hub.ConnectHandler）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/tunnels", auth.AdminOnly(h.List))
+	mux.HandleFunc("GET /api/v1/runs/{id}/files", h.GetRunFile)
+	mux.HandleFunc("GET /api/v1/runs/{id}/logs/live", h.StreamRunLogs)
+}
+
+// List 列出已建立隧道的节点（仅限管理员）
+// GET /api/v1/tunnels
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	tunnels := h.hub.List()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tunnels": tunnels, "count": len(tunnels)})
+}
+
+// GetRunFile 经节点隧道读取运行中 Run 工作目录下的文件
+// GET /api/v1/runs/{id}/files?path=<相对路径>
+//
+// path 必须是工作目录内的相对路径；文件不存在返回 404，超过 10 MiB 返回 413，
+// 节点未建立隧道返回 503。
+func (h *Handler) GetRunFile(w http.ResponseWriter, r *http.Request) {
+	filePath, err := tunnelproto.CleanRunPath(r.URL.Query().Get("path"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	run, ok := h.runningRun(w, r)
+	if !ok {
+		return
+	}
+
+	conn, ok := h.dial(w, r, run, tunnelproto.KindFile, map[string]string{"run_id": run.ID, "path": filePath})
+	if !ok {
+		return
+	}
+	defer conn.Close()
+
+	// 大文件传输不受服务端 WriteTimeout 限制
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+	contentType := mime.TypeByExtension(path.Ext(filePath))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": path.Base(filePath)}))
+	w.WriteHeader(http.StatusOK)
+	if _, err := io.Copy(w, io.LimitReader(conn, tunnelproto.MaxFileSize)); err != nil {
+		slog.ErrorContext(r.Context(), "tunnel.file.failed", "run_id", run.ID, "path", filePath, "error", err)
+	}
+}
+
+// StreamRunLogs 经节点隧道推送运行中 Run 的实时输出
+// GET /api/v1/runs/{id}/logs/live
+//
+// 响应为 text/plain 分块传输，每行一条输出，stderr 行以 "[stderr] " 开头；
+// 只包含连接之后产生的输出（历史输出见事件接口），Run 结束时响应结束。
+// 输出已按 Run 的密钥脱敏。
+func (h *Handler) StreamRunLogs(w http.ResponseWriter, r *http.Request) {
+	run, ok := h.runningRun(w, r)
+	if !ok {
+		return
+	}
+	conn, ok := h.dial(w, r, run, tunnelproto.KindLogs, map[string]string{"run_id": run.ID})
+	if !ok {
+		return
+	}
+	defer conn.Close()
+	// 客户端断开时关闭流，结束节点侧订阅
+	stop := context.AfterFunc(r.Context(), func() { conn.Close() })
+	defer stop()
+
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	rc.Flush()
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := conn.Read(buf)
+		if n > 0 {
+			if _, werr := w.Write(buf[:n]); werr != nil {
+				return
+			}
+			rc.Flush()
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// runningRun 获取运行中的 Run，失败时写入错误响应
+func (h *Handler) runningRun(w http.ResponseWriter, r *http.Request) (*model.Run, bool) {
+	if auth.GetNodeIdentity(r.Context()) != nil {
+		writeError(w, http.StatusForbidden, "not allowed for node credentials")
+		return nil, false
+	}
+	runID := r.PathValue("id")
+	run, err := h.store.GetRun(r.Context(), runID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get run")
+		return nil, false
+	}
+	if run == nil {
+		writeError(w, http.StatusNotFound, "run not found")
+		return nil, false
+	}
+	if run.Status != model.RunStatusRunning || run.NodeID == nil || *run.NodeID == "" {
+		writeError(w, http.StatusConflict, "run is not running")
+		return nil, false
+	}
+	return run, true
+}
+
+// dial 打开到 Run 所在节点的流，失败时写入错误响应
+func (h *Handler) dial(w http.ResponseWriter, r *http.Request, run *model.Run, kind string, params map[string]string) (io.ReadWriteCloser, bool) {
+	ctx, cancel := context.WithTimeout(r.Context(), dialTimeout)
+	defer cancel()
+	conn, err := h.hub.DialNode(ctx, *run.NodeID, kind, params)
+	switch {
+	case err == nil:
+		return conn, true
+	case errors.Is(err, ErrNodeNotConnected):
+		writeError(w, http.StatusServiceUnavailable, "node tunnel not connected")
+	case errors.Is(err, tunnelproto.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, tunnelproto.ErrTooLarge):
+		writeError(w, http.StatusRequestEntityTooLarge, err.Error())
+	default:
+		slog.ErrorContext(ctx, "tunnel.dial.failed", "run_id", run.ID, "node_id", *run.NodeID, "kind", kind, "error", err)
+		writeError(w, http.StatusBadGateway, "failed to reach node: "+err.Error())
+	}
+	return nil, false
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
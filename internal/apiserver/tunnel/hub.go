@@ -0,0 +1,166 @@
+// Package tunnel 节点反向隧道
+//
+// NodeManager 启动后主动连接 GET /api/v1/nodes/{node_id}/tunnel 并保持 WebSocket 长连接，
+// API Server 经 Hub 在该连接上打开到节点的流（协议见 shared/tunnel）：
+//   - 终端：/terminal/{id}/ 代理经隧道连接节点本地的 ttyd（terminal 包）
+//   - 文件：GET /api/v1/runs/{id}/files 读取运行中 Run 工作目录下的文件
+//   - 实时日志：GET /api/v1/runs/{id}/logs/live 推送 Run 的 stdout/stderr
+//
+// 节点位于 NAT 之后也不需要开放入站端口，API Server 不再假设能直连节点地址。
+package tunnel
+
+import (
+	"context"
+	"errors"
+	"log"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"agents-admin/internal/apiserver/auth"
+	tunnelproto "agents-admin/internal/shared/tunnel"
+)
+
+// ErrNodeNotConnected 节点没有建立隧道
+var ErrNodeNotConnected = errors.New("node tunnel not connected")
+
+// NodeTunnel 已连接节点的隧道信息
+type NodeTunnel struct {
+	NodeID      string    `json:"node_id"`
+	RemoteAddr  string    `json:"remote_addr"`
+	ConnectedAt time.Time `json:"connected_at"`
+	Streams     int       `json:"streams"`
+}
+
+// Hub 维护节点 ID 到隧道会话的映射
+type Hub struct {
+	mu       sync.RWMutex
+	nodes    map[string]*nodeConn
+	upgrader websocket.Upgrader
+}
+
+// nodeConn 单个节点的隧道连接
+type nodeConn struct {
+	session     *tunnelproto.Session
+	remoteAddr  string
+	connectedAt time.Time
+}
+
+// NewHub 创建隧道 Hub
+func NewHub() *Hub {
+	return &Hub{
+		nodes: make(map[string]*nodeConn),
+		// 隧道只由 NodeManager 建立，不是浏览器请求，不校验 Origin
+		upgrader: websocket.Upgrader{CheckOrigin: func(*http.Request) bool { return true }},
+	}
+}
+
+// ConnectHandler 返回节点建立隧道的处理器
+// GET /api/v1/nodes/{node_id}/tunnel
+//
+// WebSocket 升级需要 Hijack，处理器注册在认证中间件之外，节点认证在处理器内完成：
+// 接受共享密钥、节点客户端证书与节点专属 Token，专属凭证只能为本节点建立隧道。
+// 同一节点重新连接时替换旧隧道。
+func (h *Hub) ConnectHandler(cfg auth.Config) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		nodeID := r.PathValue("node_id")
+		if cfg.Enabled() {
+			authedID, ok := auth.AuthenticateNode(r.Context(), cfg, r.Header.Get("X-Node-Token"), r.TLS)
+			if !ok {
+				http.Error(w, `{"error":"node authentication required"}`, http.StatusUnauthorized)
+				return
+			}
+			if authedID != "" && authedID != nodeID {
+				http.Error(w, `{"error":"node credential does not match node_id"}`, http.StatusForbidden)
+				return
+			}
+		}
+
+		ws, err := h.upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			slog.ErrorContext(r.Context(), "tunnel.connect.failed", "node_id", nodeID, "error", err)
+			return
+		}
+
+		conn := &nodeConn{
+			session:     tunnelproto.NewSession(ws, false, nil),
+			remoteAddr:  r.RemoteAddr,
+			connectedAt: time.Now(),
+		}
+		h.attach(nodeID, conn)
+		slog.InfoContext(r.Context(), "tunnel.connect.ok", "node_id", nodeID, "remote", r.RemoteAddr)
+
+		err = conn.session.Serve()
+		h.detach(nodeID, conn)
+		slog.ErrorContext(r.Context(), "tunnel.disconnect", "node_id", nodeID, "error", err)
+	}
+}
+
+// DialNode 在节点隧道上打开一条流，节点未连接时返回 ErrNodeNotConnected，
+// 节点拒绝时返回 *tunnelproto.RejectedError
+func (h *Hub) DialNode(ctx context.Context, nodeID, kind string, params map[string]string) (net.Conn, error) {
+	h.mu.RLock()
+	conn := h.nodes[nodeID]
+	h.mu.RUnlock()
+	if conn == nil {
+		return nil, ErrNodeNotConnected
+	}
+	stream, err := conn.session.Open(ctx, tunnelproto.OpenRequest{Kind: kind, Params: params})
+	if errors.Is(err, tunnelproto.ErrSessionClosed) {
+		return nil, ErrNodeNotConnected
+	}
+	if err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Connected 节点是否已建立隧道
+func (h *Hub) Connected(nodeID string) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.nodes[nodeID] != nil
+}
+
+// List 已连接节点的隧道（按节点 ID 排序）
+func (h *Hub) List() []NodeTunnel {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	tunnels := make([]NodeTunnel, 0, len(h.nodes))
+	for id, conn := range h.nodes {
+		tunnels = append(tunnels, NodeTunnel{
+			NodeID:      id,
+			RemoteAddr:  conn.remoteAddr,
+			ConnectedAt: conn.connectedAt,
+			Streams:     conn.session.NumStreams(),
+		})
+	}
+	sort.Slice(tunnels, func(i, j int) bool { return tunnels[i].NodeID < tunnels[j].NodeID })
+	return tunnels
+}
+
+// attach 登记节点隧道，关闭同一节点的旧隧道
+func (h *Hub) attach(nodeID string, conn *nodeConn) {
+	h.mu.Lock()
+	old := h.nodes[nodeID]
+	h.nodes[nodeID] = conn
+	h.mu.Unlock()
+	if old != nil {
+		log.Printf("[tunnel.connect.replaced] node_id=%s old_remote=%s", nodeID, old.remoteAddr)
+		old.session.Close()
+	}
+}
+
+// detach 移除节点隧道（已被新连接替换时保留新连接）
+func (h *Hub) detach(nodeID string, conn *nodeConn) {
+	h.mu.Lock()
+	if h.nodes[nodeID] == conn {
+		delete(h.nodes, nodeID)
+	}
+	h.mu.Unlock()
+}
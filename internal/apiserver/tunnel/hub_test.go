@@ -0,0 +1,194 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	tunnelproto "agents-admin/internal/shared/tunnel"
+)
+
+type runMap map[string]*model.Run
+
+func (m runMap) GetRun(_ context.Context, id string) (*model.Run, error) {
+	return m[id], nil
+}
+
+// connectNode 以节点身份建立隧道，handler 处理 API Server 打开的流
+func connectNode(t *testing.T, srv *httptest.Server, nodeID, token string, handler tunnelproto.Handler) (*tunnelproto.Session, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/api/v1/nodes/" + nodeID + "/tunnel"
+	header := http.Header{}
+	if token != "" {
+		header.Set("X-Node-Token", token)
+	}
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if err != nil {
+		return nil, resp, err
+	}
+	session := tunnelproto.NewSession(conn, true, handler)
+	go session.Serve()
+	t.Cleanup(func() { session.Close() })
+	return session, resp, nil
+}
+
+func newTunnelServer(t *testing.T, hub *Hub) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	cfg := auth.Config{JWTSecret: "jwt", NodeToken: "node-secret"}
+	mux.HandleFunc("GET /api/v1/nodes/{node_id}/tunnel", hub.ConnectHandler(cfg))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestHub_ConnectAuth(t *testing.T) {
+	hub := NewHub()
+	srv := newTunnelServer(t, hub)
+
+	_, resp, err := connectNode(t, srv, "node-1", "", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	_, resp, err = connectNode(t, srv, "node-1", "wrong", nil)
+	require.Error(t, err)
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+	assert.False(t, hub.Connected("node-1"))
+
+	_, _, err = connectNode(t, srv, "node-1", "node-secret", nil)
+	require.NoError(t, err)
+	assert.Eventually(t, func() bool { return hub.Connected("node-1") }, time.Second, 10*time.Millisecond)
+}
+
+func TestHub_ReconnectReplaces(t *testing.T) {
+	hub := NewHub()
+	srv := newTunnelServer(t, hub)
+
+	first, _, err := connectNode(t, srv, "node-1", "node-secret", nil)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return hub.Connected("node-1") }, time.Second, 10*time.Millisecond)
+
+	_, _, err = connectNode(t, srv, "node-1", "node-secret", func(s *tunnelproto.Stream, req tunnelproto.OpenRequest) error {
+		s.Accept()
+		return nil
+	})
+	require.NoError(t, err)
+
+	// 旧隧道被关闭，新隧道可用
+	select {
+	case <-first.Done():
+	case <-time.After(time.Second):
+		t.Fatal("old tunnel not closed")
+	}
+	require.Eventually(t, func() bool {
+		conn, err := hub.DialNode(context.Background(), "node-1", tunnelproto.KindLogs, nil)
+		if err != nil {
+			return false
+		}
+		conn.Close()
+		return true
+	}, time.Second, 10*time.Millisecond)
+	assert.Len(t, hub.List(), 1)
+
+	_, err = hub.DialNode(context.Background(), "node-2", tunnelproto.KindLogs, nil)
+	assert.ErrorIs(t, err, ErrNodeNotConnected)
+}
+
+func TestHandler_RunFile(t *testing.T) {
+	hub := NewHub()
+	srv := newTunnelServer(t, hub)
+	_, _, err := connectNode(t, srv, "node-1", "node-secret", func(s *tunnelproto.Stream, req tunnelproto.OpenRequest) error {
+		if req.Kind != tunnelproto.KindFile || req.Params["run_id"] != "run-1" {
+			return fmt.Errorf("unexpected request %+v", req)
+		}
+		switch req.Params["path"] {
+		case "out/result.json":
+			_, err := s.Write([]byte(`{"ok":true}`))
+			return err
+		case "big.bin":
+			return fmt.Errorf("%w: big.bin", tunnelproto.ErrTooLarge)
+		}
+		return fmt.Errorf("%w: %s", tunnelproto.ErrNotFound, req.Params["path"])
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return hub.Connected("node-1") }, time.Second, 10*time.Millisecond)
+
+	node1, node2 := "node-1", "node-2"
+	runs := runMap{
+		"run-1":   {ID: "run-1", Status: model.RunStatusRunning, NodeID: &node1},
+		"run-off": {ID: "run-off", Status: model.RunStatusRunning, NodeID: &node2},
+		"run-end": {ID: "run-end", Status: model.RunStatusDone, NodeID: &node1},
+	}
+	mux := http.NewServeMux()
+	NewHandler(hub, runs).RegisterRoutes(mux)
+	get := func(runID, path string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/runs/"+runID+"/files?path="+path, nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("run-1", "out/./result.json")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.Equal(t, `{"ok":true}`, w.Body.String())
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+	tests := []struct {
+		name string
+		run  string
+		path string
+		code int
+	}{
+		{"missing file", "run-1", "nope.txt", http.StatusNotFound},
+		{"too large", "run-1", "big.bin", http.StatusRequestEntityTooLarge},
+		{"escaping path", "run-1", "../etc/passwd", http.StatusBadRequest},
+		{"absolute path", "run-1", "/etc/passwd", http.StatusBadRequest},
+		{"unknown run", "run-x", "a.txt", http.StatusNotFound},
+		{"finished run", "run-end", "a.txt", http.StatusConflict},
+		{"node not connected", "run-off", "a.txt", http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, get(tt.run, tt.path).Code)
+		})
+	}
+}
+
+func TestHandler_RunLogs(t *testing.T) {
+	hub := NewHub()
+	srv := newTunnelServer(t, hub)
+	_, _, err := connectNode(t, srv, "node-1", "node-secret", func(s *tunnelproto.Stream, req tunnelproto.OpenRequest) error {
+		s.Accept()
+		s.Write([]byte("line 1\n"))
+		s.Write([]byte("[stderr] warn\n"))
+		return nil // Run 结束
+	})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return hub.Connected("node-1") }, time.Second, 10*time.Millisecond)
+
+	node := "node-1"
+	mux := http.NewServeMux()
+	NewHandler(hub, runMap{"run-1": {ID: "run-1", Status: model.RunStatusRunning, NodeID: &node}}).RegisterRoutes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/runs/run-1/logs/live", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "line 1\n[stderr] warn\n", w.Body.String())
+
+	// 节点凭证不能读取
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/runs/run-1/logs/live", nil)
+	req = req.WithContext(auth.WithNodeIdentity(req.Context(), "node-1"))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
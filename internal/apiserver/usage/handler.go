@@ -0,0 +1,99 @@
+package usage
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// Handler 用量统计 HTTP 处理器
+type Handler struct {
+	store Store
+}
+
+// NewHandler 创建用量统计处理器
+func NewHandler(store Store) *Handler {
+	return &Handler{store: store}
+}
+
+// RegisterRoutes 注册用量统计相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/usage", h.GetReport)
+	mux.HandleFunc("GET /api/v1/runs/{id}/usage", h.GetRunUsage)
+}
+
+// GetReport 按维度汇总 Token 用量与费用
+// GET /api/v1/usage?group_by=task|project|account|agent_type|day&since=2026-10-01&until=2026-10-31
+//
+// 可选过滤：task_id、project_id、account_id、agent_type。group_by 默认为 day，日期为 UTC 且包含边界。
+//
+// 响应: {"group_by": "day", "since": "...", "until": "...", "groups": [{"key": "2026-10-01", "runs": 3, "input_tokens": ..., "cost_usd": ...}], "total": {...}}
+func (h *Handler) GetReport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	groupBy := GroupBy(q.Get("group_by"))
+	if groupBy == "" {
+		groupBy = GroupByDay
+	}
+	if !groupBy.IsValid() {
+		writeError(w, http.StatusBadRequest, "invalid group_by (expected task, project, account, agent_type or day)")
+		return
+	}
+	since, until := q.Get("since"), q.Get("until")
+	for _, day := range []string{since, until} {
+		if day == "" {
+			continue
+		}
+		if _, err := time.Parse(model.UsageDayLayout, day); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid since/until (expected YYYY-MM-DD)")
+			return
+		}
+	}
+
+	usages, err := h.store.ListRunUsage(r.Context(), since, until)
+	if err != nil {
+		slog.ErrorContext(r.Context(), "usage", "op", "ListRunUsage", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to list usage")
+		return
+	}
+	report := BuildReport(usages, groupBy, Filter{
+		TaskID:    q.Get("task_id"),
+		ProjectID: q.Get("project_id"),
+		AccountID: q.Get("account_id"),
+		AgentType: q.Get("agent_type"),
+	})
+	report.Since, report.Until = since, until
+	writeJSON(w, http.StatusOK, report)
+}
+
+// GetRunUsage 获取单个 Run 累计的用量
+// GET /api/v1/runs/{id}/usage
+func (h *Handler) GetRunUsage(w http.ResponseWriter, r *http.Request) {
+	u, err := h.store.GetRunUsage(r.Context(), r.PathValue("id"))
+	if err != nil {
+		slog.ErrorContext(r.Context(), "usage", "op", "GetRunUsage", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to get usage")
+		return
+	}
+	if u == nil {
+		writeError(w, http.StatusNotFound, "no usage recorded for run")
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,133 @@
+// Package usage Run Token 用量与费用统计
+//
+// NodeManager 从实现 adapter.UsageReporter 的 Adapter 提取 Token 用量，上报 usage 事件；
+// API Server 在事件入库后将其累加到 run_usage（每个 Run 一条记录），并提供按任务、项目、
+// 账号、Agent 类型、日期汇总的用量报表，用于预算控制。
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// EventType 用量事件类型（与 adapter.EventUsage 一致）
+const EventType = "usage"
+
+// Store 用量统计需要的存储接口
+type Store interface {
+	AddRunUsage(ctx context.Context, usage *model.RunUsage) error
+	GetRunUsage(ctx context.Context, runID string) (*model.RunUsage, error)
+	ListRunUsage(ctx context.Context, since, until string) ([]*model.RunUsage, error)
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+}
+
+// eventPayload usage 事件的 Payload
+type eventPayload struct {
+	Model            string  `json:"model"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// RecordUsageEvents 将新入库的 usage 事件累加到 Run 的用量记录
+//
+// 调用方只传入本次新写入的事件（重复上报的事件已被过滤），因此同一事件不会重复计数。
+func RecordUsageEvents(ctx context.Context, store Store, runID string, events []*model.Event) {
+	var attrs *runAttrs
+	for _, e := range events {
+		if e.Type != EventType {
+			continue
+		}
+		var p eventPayload
+		if err := json.Unmarshal(e.Payload, &p); err != nil {
+			slog.ErrorContext(ctx, "usage.event.invalid", "run_id", runID, "seq", e.Seq, "error", err)
+			continue
+		}
+		if attrs == nil {
+			var err error
+			if attrs, err = loadRunAttrs(ctx, store, runID); err != nil {
+				slog.ErrorContext(ctx, "usage.run.failed", "run_id", runID, "error", err)
+				return
+			}
+		}
+		ts := e.Timestamp
+		if ts.IsZero() {
+			ts = time.Now()
+		}
+		u := &model.RunUsage{
+			RunID:            runID,
+			TaskID:           attrs.taskID,
+			ProjectID:        attrs.projectID,
+			AccountID:        attrs.accountID,
+			AgentType:        attrs.agentType,
+			Model:            p.Model,
+			InputTokens:      p.InputTokens,
+			OutputTokens:     p.OutputTokens,
+			CacheReadTokens:  p.CacheReadTokens,
+			CacheWriteTokens: p.CacheWriteTokens,
+			CostUSD:          p.CostUSD,
+			Day:              ts.UTC().Format(model.UsageDayLayout),
+			CreatedAt:        ts,
+			UpdatedAt:        ts,
+		}
+		if err := store.AddRunUsage(ctx, u); err != nil {
+			slog.ErrorContext(ctx, "usage.add.failed", "run_id", runID, "seq", e.Seq, "error", err)
+			continue
+		}
+		slog.InfoContext(ctx, "usage.added", "run_id", runID, "seq", e.Seq, "input", u.InputTokens, "output", u.OutputTokens, "cost_usd", u.CostUSD)
+	}
+}
+
+// runAttrs 用量记录的汇总维度
+type runAttrs struct {
+	taskID    string
+	projectID string
+	accountID string
+	agentType string
+}
+
+// loadRunAttrs 读取 Run 的任务、项目、Agent 类型与账号
+func loadRunAttrs(ctx context.Context, store Store, runID string) (*runAttrs, error) {
+	run, err := store.GetRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	attrs := &runAttrs{}
+	if run == nil {
+		return attrs, nil
+	}
+	attrs.taskID = run.TaskID
+	if task, err := store.GetTask(ctx, run.TaskID); err == nil && task != nil && task.ProjectID != nil {
+		attrs.projectID = *task.ProjectID
+	}
+	attrs.agentType, attrs.accountID = RunAgent(ctx, store, run)
+	return attrs, nil
+}
+
+// InstanceGetter 查询 Agent 实例
+type InstanceGetter interface {
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+}
+
+// RunAgent 从 Run 快照读取 Agent 类型与账号（快照未记录账号时从绑定的实例查找）
+func RunAgent(ctx context.Context, store InstanceGetter, run *model.Run) (agentType, accountID string) {
+	snapshot, err := model.ParseRunSnapshot(run.Snapshot)
+	if err != nil {
+		return "", ""
+	}
+	agentType, accountID = snapshot.Agent.Type, snapshot.Agent.AccountID
+	if accountID == "" && snapshot.Agent.InstanceID != "" {
+		if inst, err := store.GetAgentInstance(ctx, snapshot.Agent.InstanceID); err == nil && inst != nil {
+			accountID = inst.AccountID
+		}
+	}
+	return agentType, accountID
+}
@@ -0,0 +1,130 @@
+package usage
+
+import (
+	"sort"
+
+	"agents-admin/internal/shared/model"
+)
+
+// GroupBy 用量报表的汇总维度
+type GroupBy string
+
+const (
+	GroupByTask      GroupBy = "task"
+	GroupByProject   GroupBy = "project"
+	GroupByAccount   GroupBy = "account"
+	GroupByAgentType GroupBy = "agent_type"
+	GroupByDay       GroupBy = "day"
+)
+
+// IsValid 判断汇总维度是否有效
+func (g GroupBy) IsValid() bool {
+	switch g {
+	case GroupByTask, GroupByProject, GroupByAccount, GroupByAgentType, GroupByDay:
+		return true
+	}
+	return false
+}
+
+// key 用量记录在该维度下的分组键
+func (g GroupBy) key(u *model.RunUsage) string {
+	switch g {
+	case GroupByTask:
+		return u.TaskID
+	case GroupByProject:
+		return u.ProjectID
+	case GroupByAccount:
+		return u.AccountID
+	case GroupByAgentType:
+		return u.AgentType
+	default:
+		return u.Day
+	}
+}
+
+// Filter 用量报表的过滤条件（为空表示不限）
+type Filter struct {
+	TaskID    string
+	ProjectID string
+	AccountID string
+	AgentType string
+}
+
+func (f Filter) match(u *model.RunUsage) bool {
+	return (f.TaskID == "" || u.TaskID == f.TaskID) &&
+		(f.ProjectID == "" || u.ProjectID == f.ProjectID) &&
+		(f.AccountID == "" || u.AccountID == f.AccountID) &&
+		(f.AgentType == "" || u.AgentType == f.AgentType)
+}
+
+// Totals 一组 Run 的用量合计
+type Totals struct {
+	Runs             int     `json:"runs"`
+	InputTokens      int64   `json:"input_tokens"`
+	OutputTokens     int64   `json:"output_tokens"`
+	CacheReadTokens  int64   `json:"cache_read_tokens"`
+	CacheWriteTokens int64   `json:"cache_write_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+func (t *Totals) add(u *model.RunUsage) {
+	t.Runs++
+	t.InputTokens += u.InputTokens
+	t.OutputTokens += u.OutputTokens
+	t.CacheReadTokens += u.CacheReadTokens
+	t.CacheWriteTokens += u.CacheWriteTokens
+	t.TotalTokens += u.TotalTokens()
+	t.CostUSD += u.CostUSD
+}
+
+// Group 报表中的一个分组（Key 为任务 ID、项目 ID、账号 ID、Agent 类型或日期，未知时为空）
+type Group struct {
+	Key string `json:"key"`
+	Totals
+}
+
+// Report 用量报表
+type Report struct {
+	GroupBy GroupBy `json:"group_by"`
+	Since   string  `json:"since,omitempty"`
+	Until   string  `json:"until,omitempty"`
+	Groups  []Group `json:"groups"`
+	Total   Totals  `json:"total"`
+}
+
+// BuildReport 按维度汇总用量记录
+//
+// 按日期汇总时分组按日期升序排列，其余维度按费用降序（费用相同时按 Token 总量降序、分组键升序）。
+func BuildReport(usages []*model.RunUsage, groupBy GroupBy, filter Filter) *Report {
+	report := &Report{GroupBy: groupBy, Groups: []Group{}}
+	index := make(map[string]int)
+	for _, u := range usages {
+		if !filter.match(u) {
+			continue
+		}
+		key := groupBy.key(u)
+		i, ok := index[key]
+		if !ok {
+			i = len(report.Groups)
+			index[key] = i
+			report.Groups = append(report.Groups, Group{Key: key})
+		}
+		report.Groups[i].add(u)
+		report.Total.add(u)
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		a, b := report.Groups[i], report.Groups[j]
+		if groupBy != GroupByDay {
+			if a.CostUSD != b.CostUSD {
+				return a.CostUSD > b.CostUSD
+			}
+			if a.TotalTokens != b.TotalTokens {
+				return a.TotalTokens > b.TotalTokens
+			}
+		}
+		return a.Key < b.Key
+	})
+	return report
+}
@@ -0,0 +1,187 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/model"
+)
+
+// memStore 内存实现的用量存储
+type memStore struct {
+	usages    map[string]*model.RunUsage
+	runs      map[string]*model.Run
+	tasks     map[string]*model.Task
+	instances map[string]*model.Instance
+}
+
+func newMemStore() *memStore {
+	return &memStore{
+		usages:    map[string]*model.RunUsage{},
+		runs:      map[string]*model.Run{},
+		tasks:     map[string]*model.Task{},
+		instances: map[string]*model.Instance{},
+	}
+}
+
+func (m *memStore) AddRunUsage(_ context.Context, u *model.RunUsage) error {
+	existing, ok := m.usages[u.RunID]
+	if !ok {
+		copied := *u
+		m.usages[u.RunID] = &copied
+		return nil
+	}
+	existing.InputTokens += u.InputTokens
+	existing.OutputTokens += u.OutputTokens
+	existing.CacheReadTokens += u.CacheReadTokens
+	existing.CacheWriteTokens += u.CacheWriteTokens
+	existing.CostUSD += u.CostUSD
+	if u.Model != "" {
+		existing.Model = u.Model
+	}
+	existing.UpdatedAt = u.UpdatedAt
+	return nil
+}
+
+func (m *memStore) GetRunUsage(_ context.Context, runID string) (*model.RunUsage, error) {
+	return m.usages[runID], nil
+}
+
+func (m *memStore) ListRunUsage(_ context.Context, since, until string) ([]*model.RunUsage, error) {
+	var out []*model.RunUsage
+	for _, u := range m.usages {
+		if (since == "" || u.Day >= since) && (until == "" || u.Day <= until) {
+			out = append(out, u)
+		}
+	}
+	return out, nil
+}
+
+func (m *memStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	return m.runs[id], nil
+}
+
+func (m *memStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	return m.tasks[id], nil
+}
+
+func (m *memStore) GetAgentInstance(_ context.Context, id string) (*model.Instance, error) {
+	return m.instances[id], nil
+}
+
+func usageEvent(seq int, ts time.Time, payload string) *model.Event {
+	return &model.Event{Seq: seq, Type: EventType, Timestamp: ts, Payload: json.RawMessage(payload)}
+}
+
+func TestRecordUsageEvents(t *testing.T) {
+	store := newMemStore()
+	store.runs["run-1"] = &model.Run{ID: "run-1", TaskID: "task-1", Snapshot: json.RawMessage(`{"agent":{"type":"claude","instance_id":"inst-1"}}`)}
+	store.instances["inst-1"] = &model.Instance{ID: "inst-1", AccountID: "acc-1"}
+	project := "proj-1"
+	store.tasks["task-1"] = &model.Task{ID: "task-1", ProjectID: &project}
+	ts := time.Date(2026, 10, 16, 23, 30, 0, 0, time.FixedZone("CST", 8*3600))
+
+	RecordUsageEvents(context.Background(), store, "run-1", []*model.Event{
+		{Seq: 1, Type: "message", Payload: json.RawMessage(`{"content":"hi"}`)},
+		usageEvent(2, ts, `{"model":"sonnet","input_tokens":100,"output_tokens":20,"cost_usd":0.25}`),
+		usageEvent(3, ts, `{"input_tokens":50,"cache_read_tokens":1000,"cost_usd":0.5}`),
+		usageEvent(4, ts, `not json`),
+	})
+
+	u := store.usages["run-1"]
+	require.NotNil(t, u)
+	assert.Equal(t, "task-1", u.TaskID)
+	assert.Equal(t, "proj-1", u.ProjectID)
+	assert.Equal(t, "acc-1", u.AccountID)
+	assert.Equal(t, "claude", u.AgentType)
+	assert.Equal(t, "sonnet", u.Model)
+	assert.Equal(t, int64(150), u.InputTokens)
+	assert.Equal(t, int64(1000), u.CacheReadTokens)
+	assert.InDelta(t, 0.75, u.CostUSD, 1e-9)
+	assert.Equal(t, "2026-10-16", u.Day, "day is the UTC date")
+}
+
+func TestRecordUsageEvents_SnapshotAccount(t *testing.T) {
+	store := newMemStore()
+	store.runs["run-1"] = &model.Run{ID: "run-1", TaskID: "task-1", Snapshot: json.RawMessage(`{"agent":{"type":"qwen-code","account_id":"acc-pool"}}`)}
+
+	RecordUsageEvents(context.Background(), store, "run-1", []*model.Event{usageEvent(1, time.Now(), `{"input_tokens":1}`)})
+	require.NotNil(t, store.usages["run-1"])
+	assert.Equal(t, "acc-pool", store.usages["run-1"].AccountID)
+	assert.Equal(t, "qwen-code", store.usages["run-1"].AgentType)
+}
+
+func TestBuildReport(t *testing.T) {
+	usages := []*model.RunUsage{
+		{RunID: "r1", TaskID: "t1", ProjectID: "p1", AccountID: "a1", AgentType: "claude", InputTokens: 100, CostUSD: 1, Day: "2026-10-02"},
+		{RunID: "r2", TaskID: "t2", AccountID: "a1", AgentType: "gemini", InputTokens: 500, Day: "2026-10-01"},
+		{RunID: "r3", TaskID: "t1", AccountID: "a2", AgentType: "claude", OutputTokens: 10, CostUSD: 2, Day: "2026-10-01"},
+	}
+
+	byTask := BuildReport(usages, GroupByTask, Filter{})
+	require.Len(t, byTask.Groups, 2)
+	assert.Equal(t, "t1", byTask.Groups[0].Key, "highest cost first")
+	assert.Equal(t, 2, byTask.Groups[0].Runs)
+	assert.Equal(t, int64(110), byTask.Groups[0].TotalTokens)
+	assert.InDelta(t, 3.0, byTask.Total.CostUSD, 1e-9)
+	assert.Equal(t, 3, byTask.Total.Runs)
+
+	byDay := BuildReport(usages, GroupByDay, Filter{})
+	require.Len(t, byDay.Groups, 2)
+	assert.Equal(t, "2026-10-01", byDay.Groups[0].Key, "days ascending")
+
+	byProject := BuildReport(usages, GroupByProject, Filter{})
+	require.Len(t, byProject.Groups, 2)
+	assert.Equal(t, "p1", byProject.Groups[1].Key)
+	assert.Equal(t, "", byProject.Groups[0].Key, "runs without project are grouped under empty key")
+
+	filtered := BuildReport(usages, GroupByAgentType, Filter{AccountID: "a1"})
+	require.Len(t, filtered.Groups, 2)
+	assert.Equal(t, "claude", filtered.Groups[0].Key)
+	assert.Equal(t, "gemini", filtered.Groups[1].Key)
+	assert.Equal(t, 2, filtered.Total.Runs)
+}
+
+func TestHandler(t *testing.T) {
+	store := newMemStore()
+	store.usages["r1"] = &model.RunUsage{RunID: "r1", TaskID: "t1", AccountID: "a1", AgentType: "claude", InputTokens: 100, CostUSD: 1, Day: "2026-10-02"}
+	store.usages["r2"] = &model.RunUsage{RunID: "r2", TaskID: "t2", AccountID: "a2", AgentType: "claude", InputTokens: 50, CostUSD: 3, Day: "2026-09-30"}
+	mux := http.NewServeMux()
+	NewHandler(store).RegisterRoutes(mux)
+
+	do := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	w := do("/api/v1/usage?group_by=account&since=2026-10-01")
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report Report
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, GroupByAccount, report.GroupBy)
+	require.Len(t, report.Groups, 1)
+	assert.Equal(t, "a1", report.Groups[0].Key)
+	assert.Equal(t, "2026-10-01", report.Since)
+
+	w = do("/api/v1/usage")
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, GroupByDay, report.GroupBy)
+	assert.Len(t, report.Groups, 2)
+
+	assert.Equal(t, http.StatusBadRequest, do("/api/v1/usage?group_by=node").Code)
+	assert.Equal(t, http.StatusBadRequest, do("/api/v1/usage?since=10/01/2026").Code)
+
+	w = do("/api/v1/runs/r1/usage")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"input_tokens":100`)
+	assert.Equal(t, http.StatusNotFound, do("/api/v1/runs/missing/usage").Code)
+}
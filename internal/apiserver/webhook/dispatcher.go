@@ -0,0 +1,386 @@
+// Package webhook Webhook 订阅与事件投递
+//
+// 订阅的过滤条件（事件类型、项目、模板、节点、标签选择器、状态迁移）在加载时编译为按事件类型索引的匹配器：
+// 发布事件时只评估关注该事件类型的订阅，未被关注的事件类型直接丢弃，不查询数据库也不进入投递队列。
+//
+// 事件来源见 WrapStore：Run 创建、Run 状态迁移与 Agent 上报的事件在写入存储后发布。
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// SignatureHeader 请求体的 HMAC-SHA256 签名（sha256=<hex>），未设置密钥时不发送
+	SignatureHeader = "X-Webhook-Signature"
+	// EventHeader 事件类型
+	EventHeader = "X-Webhook-Event"
+	// DeliveryHeader 事件 ID（重试时不变，可用于去重）
+	DeliveryHeader = "X-Webhook-Delivery"
+
+	// recordInterval 投递持续成功时，最近投递结果的最小写入间隔（避免高频事件逐条写库）
+	recordInterval = 30 * time.Second
+
+	// contextTTL Run 所属任务信息的缓存时长
+	contextTTL = 5 * time.Minute
+	// contextCacheSize 任务信息缓存的最大条目数，超出时整体清空
+	contextCacheSize = 4096
+)
+
+// Store Webhook 投递需要的存储接口
+type Store interface {
+	ListWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	RecordWebhookDelivery(ctx context.Context, id string, at time.Time, status int, errMsg string) error
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+}
+
+// Config 投递配置
+type Config struct {
+	Workers        int           // 并发投递协程数
+	QueueSize      int           // 待投递队列长度，队列满时丢弃新事件
+	Timeout        time.Duration // 单次请求超时
+	MaxAttempts    int           // 最大尝试次数（网络错误、429 与 5xx 时重试）
+	ReloadInterval time.Duration // 定期重新加载订阅（多实例部署时同步其他实例的修改）
+}
+
+// delivery 待投递的事件
+type delivery struct {
+	hook  *model.Webhook
+	event *model.WebhookEvent
+}
+
+// runContext 事件匹配需要的 Run 所属任务信息
+type runContext struct {
+	taskID     string
+	nodeID     string
+	projectID  string
+	templateID string
+	labels     map[string]string
+	expires    time.Time
+}
+
+// Dispatcher 事件分发器：按已编译的订阅匹配事件并异步投递
+type Dispatcher struct {
+	store   Store
+	cfg     Config
+	client  *http.Client
+	index   atomic.Pointer[index]
+	queue   chan delivery
+	backoff func(attempt int) time.Duration
+
+	mu       sync.Mutex
+	contexts map[string]runContext  // runID → 任务信息
+	records  map[string]recordState // webhookID → 最近写入的投递结果
+}
+
+// recordState 最近一次写入存储的投递结果
+type recordState struct {
+	at time.Time
+	ok bool
+}
+
+// NewDispatcher 创建事件分发器（需调用 Start 加载订阅并启动投递）
+func NewDispatcher(store Store, cfg Config) *Dispatcher {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 1000
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	if cfg.ReloadInterval <= 0 {
+		cfg.ReloadInterval = time.Minute
+	}
+	d := &Dispatcher{
+		store:    store,
+		cfg:      cfg,
+		client:   &http.Client{Timeout: cfg.Timeout},
+		queue:    make(chan delivery, cfg.QueueSize),
+		backoff:  func(attempt int) time.Duration { return time.Duration(attempt) * time.Second },
+		contexts: map[string]runContext{},
+		records:  map[string]recordState{},
+	}
+	d.index.Store(&index{exact: map[string][]*subscription{}})
+	return d
+}
+
+// Reload 重新加载并编译订阅
+func (d *Dispatcher) Reload(ctx context.Context) error {
+	hooks, err := d.store.ListWebhooks(ctx)
+	if err != nil {
+		return err
+	}
+	ix, invalid := buildIndex(hooks)
+	for id, err := range invalid {
+		slog.ErrorContext(ctx, "webhook.filter.invalid", "webhook_id", id, "error", err)
+	}
+	d.index.Store(ix)
+	return nil
+}
+
+// Start 加载订阅并启动投递协程（阻塞直到 ctx 取消）
+func (d *Dispatcher) Start(ctx context.Context) {
+	if err := d.Reload(ctx); err != nil {
+		slog.ErrorContext(ctx, "webhook.reload.failed", "error", err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < d.cfg.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case del := <-d.queue:
+					d.deliver(ctx, del)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(d.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			return
+		case <-ticker.C:
+			if err := d.Reload(ctx); err != nil {
+				slog.ErrorContext(ctx, "webhook.reload.failed", "error", err)
+			}
+		}
+	}
+}
+
+// Interested 是否有启用的订阅关注该事件类型（事件源据此跳过准备事件的开销）
+func (d *Dispatcher) Interested(eventType string) bool {
+	return d.index.Load().interested(eventType)
+}
+
+// Publish 发布事件：匹配订阅并加入投递队列（不阻塞，队列满时丢弃）
+//
+// 只有存在需要项目、模板、标签或节点才能判断的订阅时，才补全事件的 Run 所属任务信息。
+func (d *Dispatcher) Publish(ctx context.Context, ev *model.WebhookEvent) {
+	if ev.RunID != "" && ev.TaskID != "" {
+		d.rememberRun(ev)
+	}
+	candidates := d.index.Load().candidates(ev.Type)
+	if len(candidates) == 0 {
+		return
+	}
+	if ev.ID == "" {
+		ev.ID = generateID("evt")
+	}
+	if ev.Timestamp.IsZero() {
+		ev.Timestamp = time.Now()
+	}
+
+	// 事件入队后由投递协程并发读取，补全必须在入队之前完成
+	for _, sub := range candidates {
+		if sub.matcher.needsContext() {
+			d.enrich(ctx, ev)
+			break
+		}
+	}
+	for _, sub := range candidates {
+		if !sub.matcher.match(ev) {
+			continue
+		}
+		select {
+		case d.queue <- delivery{hook: sub.hook, event: ev}:
+		default:
+			slog.InfoContext(ctx, "webhook.queue.full", "webhook_id", sub.hook.ID, "event_type", ev.Type, "event_id", ev.ID)
+		}
+	}
+}
+
+// Test 同步投递一个 ping 事件，返回 HTTP 状态码
+func (d *Dispatcher) Test(ctx context.Context, hook *model.Webhook) (int, error) {
+	ev := &model.WebhookEvent{ID: generateID("evt"), Type: model.WebhookEventPing, Timestamp: time.Now(),
+		Data: map[string]interface{}{"webhook_id": hook.ID}}
+	body, _ := json.Marshal(ev)
+	status, err := d.post(ctx, hook, ev, body)
+	if err == nil && status >= 300 {
+		err = fmt.Errorf("unexpected status %d", status)
+	}
+	d.record(ctx, hook.ID, status, err, true)
+	return status, err
+}
+
+// deliver 投递事件，网络错误、429 与 5xx 时按退避重试
+func (d *Dispatcher) deliver(ctx context.Context, del delivery) {
+	body, _ := json.Marshal(del.event)
+	var status int
+	var err error
+	for attempt := 1; attempt <= d.cfg.MaxAttempts; attempt++ {
+		status, err = d.post(ctx, del.hook, del.event, body)
+		if err == nil && status < 300 {
+			break
+		}
+		if err == nil {
+			err = fmt.Errorf("unexpected status %d", status)
+			if status != http.StatusTooManyRequests && status < 500 {
+				break
+			}
+		}
+		if attempt == d.cfg.MaxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(d.backoff(attempt)):
+		}
+	}
+	if err != nil {
+		slog.ErrorContext(ctx, "webhook.deliver.failed", "webhook_id", del.hook.ID, "event_type", del.event.Type,
+			"event_id", del.event.ID, "status", status, "error", err)
+	}
+	d.record(ctx, del.hook.ID, status, err, false)
+}
+
+// post 发送一次投递请求
+func (d *Dispatcher) post(ctx context.Context, hook *model.Webhook, ev *model.WebhookEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "agents-admin-webhook")
+	req.Header.Set(EventHeader, ev.Type)
+	req.Header.Set(DeliveryHeader, ev.ID)
+	if hook.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(hook.Secret, body))
+	}
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
+
+// record 写入最近一次投递结果
+//
+// 持续成功时每个订阅至多每 recordInterval 写入一次；失败、由失败恢复与手动测试总是写入。
+func (d *Dispatcher) record(ctx context.Context, webhookID string, status int, err error, force bool) {
+	now := time.Now()
+	ok := err == nil
+	d.mu.Lock()
+	last, seen := d.records[webhookID]
+	if !force && ok && seen && last.ok && now.Sub(last.at) < recordInterval {
+		d.mu.Unlock()
+		return
+	}
+	d.records[webhookID] = recordState{at: now, ok: ok}
+	d.mu.Unlock()
+
+	var errMsg string
+	if err != nil {
+		errMsg = err.Error()
+	}
+	if err := d.store.RecordWebhookDelivery(ctx, webhookID, now, status, errMsg); err != nil {
+		slog.ErrorContext(ctx, "webhook.record.failed", "webhook_id", webhookID, "error", err)
+	}
+}
+
+// rememberRun 缓存事件携带的 Run → 任务、节点映射（状态迁移时节点可能变化）
+func (d *Dispatcher) rememberRun(ev *model.WebhookEvent) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if rc, ok := d.contexts[ev.RunID]; ok && rc.taskID == ev.TaskID {
+		rc.nodeID = ev.NodeID
+		d.contexts[ev.RunID] = rc
+	}
+}
+
+// enrich 补全事件的任务、项目、模板、标签与节点
+func (d *Dispatcher) enrich(ctx context.Context, ev *model.WebhookEvent) {
+	if ev.RunID == "" {
+		return
+	}
+	now := time.Now()
+	d.mu.Lock()
+	rc, ok := d.contexts[ev.RunID]
+	d.mu.Unlock()
+
+	if !ok || now.After(rc.expires) {
+		rc = runContext{taskID: ev.TaskID, nodeID: ev.NodeID, expires: now.Add(contextTTL)}
+		if rc.taskID == "" || rc.nodeID == "" {
+			run, err := d.store.GetRun(ctx, ev.RunID)
+			if err != nil || run == nil {
+				return
+			}
+			rc.taskID = run.TaskID
+			if rc.nodeID == "" && run.NodeID != nil {
+				rc.nodeID = *run.NodeID
+			}
+		}
+		task, err := d.store.GetTask(ctx, rc.taskID)
+		if err != nil || task == nil {
+			return
+		}
+		rc.projectID = derefString(task.ProjectID)
+		rc.templateID = derefString(task.TemplateID)
+		rc.labels = task.Labels
+
+		d.mu.Lock()
+		if len(d.contexts) >= contextCacheSize {
+			d.contexts = map[string]runContext{}
+		}
+		d.contexts[ev.RunID] = rc
+		d.mu.Unlock()
+	}
+
+	if ev.TaskID == "" {
+		ev.TaskID = rc.taskID
+	}
+	if ev.NodeID == "" {
+		ev.NodeID = rc.nodeID
+	}
+	ev.ProjectID, ev.TemplateID, ev.Labels = rc.projectID, rc.templateID, rc.labels
+}
+
+// Sign 计算请求体签名（sha256=<hex>），接收方以同一密钥计算后比对
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
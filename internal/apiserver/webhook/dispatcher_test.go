@@ -0,0 +1,358 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// mockStore 内存存储（未实现的 PersistentStore 方法不会被调用）
+type mockStore struct {
+	storage.PersistentStore
+
+	mu       sync.Mutex
+	hooks    map[string]*model.Webhook
+	runs     map[string]*model.Run
+	tasks    map[string]*model.Task
+	records  []int
+	getRuns  int
+	getTasks int
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{hooks: map[string]*model.Webhook{}, runs: map[string]*model.Run{}, tasks: map[string]*model.Task{}}
+}
+
+func (m *mockStore) ListWebhooks(_ context.Context) ([]*model.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var result []*model.Webhook
+	for _, h := range m.hooks {
+		copied := *h
+		result = append(result, &copied)
+	}
+	return result, nil
+}
+
+func (m *mockStore) GetWebhook(_ context.Context, id string) (*model.Webhook, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if h, ok := m.hooks[id]; ok {
+		copied := *h
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) CreateWebhook(_ context.Context, w *model.Webhook) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks[w.ID] = w
+	return nil
+}
+
+func (m *mockStore) UpdateWebhook(ctx context.Context, w *model.Webhook) error {
+	return m.CreateWebhook(ctx, w)
+}
+
+func (m *mockStore) DeleteWebhook(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.hooks, id)
+	return nil
+}
+
+func (m *mockStore) RecordWebhookDelivery(_ context.Context, _ string, _ time.Time, status int, _ string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.records = append(m.records, status)
+	return nil
+}
+
+func (m *mockStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.getRuns++
+	if r, ok := m.runs[id]; ok {
+		copied := *r
+		return &copied, nil
+	}
+	return nil, nil
+}
+
+func (m *mockStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.getTasks++
+	return m.tasks[id], nil
+}
+
+func (m *mockStore) CreateRun(_ context.Context, run *model.Run) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[run.ID] = run
+	return nil
+}
+
+func (m *mockStore) UpdateRunStatus(_ context.Context, id string, status model.RunStatus, nodeID *string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[id].Status = status
+	if nodeID != nil {
+		m.runs[id].NodeID = nodeID
+	}
+	return nil
+}
+
+func (m *mockStore) CreateEvents(_ context.Context, _ []*model.Event) error { return nil }
+
+// receiver 记录收到的投递
+type receiver struct {
+	mu     sync.Mutex
+	events []*model.WebhookEvent
+	bodies [][]byte
+	sigs   []string
+	got    chan struct{}
+}
+
+func newReceiver(t *testing.T, status int) (*receiver, *httptest.Server) {
+	rc := &receiver{got: make(chan struct{}, 100)}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var ev model.WebhookEvent
+		json.Unmarshal(body, &ev)
+		rc.mu.Lock()
+		rc.events = append(rc.events, &ev)
+		rc.bodies = append(rc.bodies, body)
+		rc.sigs = append(rc.sigs, r.Header.Get(SignatureHeader))
+		rc.mu.Unlock()
+		w.WriteHeader(status)
+		rc.got <- struct{}{}
+	}))
+	t.Cleanup(srv.Close)
+	return rc, srv
+}
+
+func (rc *receiver) wait(t *testing.T, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-rc.got:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for delivery %d/%d", i+1, n)
+		}
+	}
+}
+
+func startDispatcher(t *testing.T, store *mockStore, cfg Config) *Dispatcher {
+	d := NewDispatcher(store, cfg)
+	d.backoff = func(int) time.Duration { return time.Millisecond }
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		d.Start(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	// Start 首先同步加载订阅
+	if err := d.Reload(ctx); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestDispatcher_StatusTransitionThroughStore(t *testing.T) {
+	rc, srv := newReceiver(t, http.StatusOK)
+	store := newMockStore()
+	proj := "proj-1"
+	store.tasks["task-1"] = &model.Task{ID: "task-1", ProjectID: &proj, Labels: map[string]string{"env": "prod"}}
+	store.hooks["wh-1"] = &model.Webhook{ID: "wh-1", URL: srv.URL, Secret: "s3cret", Enabled: true,
+		Filter: model.WebhookFilter{ProjectIDs: []string{"proj-1"}, LabelSelector: "env=prod", Transitions: []string{"*->failed"}}}
+	d := startDispatcher(t, store, Config{})
+	wrapped := WrapStore(store, d)
+	ctx := context.Background()
+
+	node := "node-1"
+	if err := wrapped.CreateRun(ctx, &model.Run{ID: "run-1", TaskID: "task-1", Status: model.RunStatusQueued}); err != nil {
+		t.Fatal(err)
+	}
+	wrapped.UpdateRunStatus(ctx, "run-1", model.RunStatusAssigned, &node)
+	wrapped.UpdateRunStatus(ctx, "run-1", model.RunStatusRunning, nil)
+	wrapped.UpdateRunStatus(ctx, "run-1", model.RunStatusFailed, nil)
+	rc.wait(t, 1)
+
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.events) != 1 {
+		t.Fatalf("deliveries = %d, want only the failed transition", len(rc.events))
+	}
+	ev := rc.events[0]
+	if ev.Type != model.WebhookEventRunStatusChanged || ev.FromStatus != model.RunStatusRunning || ev.ToStatus != model.RunStatusFailed {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+	if ev.ProjectID != "proj-1" || ev.NodeID != "node-1" || ev.Labels["env"] != "prod" {
+		t.Errorf("event not enriched: %+v", ev)
+	}
+	if rc.sigs[0] != Sign("s3cret", rc.bodies[0]) {
+		t.Errorf("signature = %q", rc.sigs[0])
+	}
+	store.mu.Lock()
+	if store.getTasks != 1 {
+		t.Errorf("GetTask calls = %d, task context should be cached", store.getTasks)
+	}
+	store.mu.Unlock()
+}
+
+func TestDispatcher_UnsubscribedEventsAreFree(t *testing.T) {
+	store := newMockStore()
+	store.hooks["wh-1"] = &model.Webhook{ID: "wh-1", URL: "http://127.0.0.1:0", Enabled: true,
+		Filter: model.WebhookFilter{EventTypes: []string{"run.event.error"}}}
+	d := startDispatcher(t, store, Config{})
+	wrapped := WrapStore(store, d)
+	ctx := context.Background()
+
+	wrapped.CreateRun(ctx, &model.Run{ID: "run-1", TaskID: "task-1", Status: model.RunStatusQueued})
+	wrapped.UpdateRunStatus(ctx, "run-1", model.RunStatusRunning, nil)
+	wrapped.CreateEvents(ctx, []*model.Event{{RunID: "run-1", Seq: 1, Type: "message"}})
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if store.getRuns != 0 || store.getTasks != 0 {
+		t.Errorf("unsubscribed events triggered lookups: runs=%d tasks=%d", store.getRuns, store.getTasks)
+	}
+	if len(d.queue) != 0 {
+		t.Errorf("queue length = %d, want 0", len(d.queue))
+	}
+}
+
+func TestDispatcher_RunEventsAndRetry(t *testing.T) {
+	var mu sync.Mutex
+	attempts := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		if attempts == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	store := newMockStore()
+	store.hooks["wh-1"] = &model.Webhook{ID: "wh-1", URL: srv.URL, Enabled: true,
+		Filter: model.WebhookFilter{EventTypes: []string{"run.event.*"}}}
+	d := startDispatcher(t, store, Config{})
+	WrapStore(store, d).CreateEvents(context.Background(), []*model.Event{
+		{RunID: "run-1", Seq: 1, Type: "tool_use", Payload: []byte(`{"tool":"bash"}`)},
+	})
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		store.mu.Lock()
+		n := len(store.records)
+		store.mu.Unlock()
+		if n > 0 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if attempts != 2 {
+		t.Errorf("attempts = %d, want retry after 503", attempts)
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.records) != 1 || store.records[0] != http.StatusNoContent {
+		t.Errorf("records = %v", store.records)
+	}
+}
+
+func TestHandler_CRUD(t *testing.T) {
+	rc, srv := newReceiver(t, http.StatusOK)
+	store := newMockStore()
+	d := NewDispatcher(store, Config{})
+	mux := http.NewServeMux()
+	NewHandler(store, d).RegisterRoutes(mux)
+
+	do := func(method, path, body string, user *auth.AuthUser) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, bytes.NewBufferString(body))
+		if user != nil {
+			req = req.WithContext(auth.WithAuthUser(req.Context(), user))
+		}
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		return w
+	}
+	admin := &auth.AuthUser{ID: "u-admin", Role: auth.UserRoleAdmin}
+
+	if w := do("POST", "/api/v1/webhooks", `{"name":"x","url":"`+srv.URL+`","filter":{"transitions":["oops"]}}`, admin); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid filter: status = %d, want 400", w.Code)
+	}
+	if w := do("POST", "/api/v1/webhooks", `{"name":"x","url":"ftp://example.com"}`, admin); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid url: status = %d, want 400", w.Code)
+	}
+	if w := do("GET", "/api/v1/webhooks", "", &auth.AuthUser{ID: "u1", Role: "user"}); w.Code != http.StatusForbidden {
+		t.Errorf("regular user: status = %d, want 403", w.Code)
+	}
+
+	w := do("POST", "/api/v1/webhooks", `{"name":"failures","url":"`+srv.URL+`","filter":{"transitions":["*->failed"]}}`, admin)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var created struct {
+		ID     string `json:"id"`
+		Secret string `json:"secret"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	if len(created.Secret) != 64 {
+		t.Errorf("generated secret = %q", created.Secret)
+	}
+	if !d.Interested(model.WebhookEventRunStatusChanged) {
+		t.Error("dispatcher should be reloaded after create")
+	}
+
+	w = do("GET", "/api/v1/webhooks/"+created.ID, "", admin)
+	if strings.Contains(w.Body.String(), created.Secret) {
+		t.Error("secret must not be returned after creation")
+	}
+
+	if w := do("PATCH", "/api/v1/webhooks/"+created.ID, `{"enabled":false}`, admin); w.Code != http.StatusOK {
+		t.Fatalf("update: status = %d", w.Code)
+	}
+	if d.Interested(model.WebhookEventRunStatusChanged) {
+		t.Error("disabled webhook should be removed from the index")
+	}
+
+	w = do("POST", "/api/v1/webhooks/"+created.ID+"/test", "", admin)
+	rc.wait(t, 1)
+	if !strings.Contains(w.Body.String(), `"delivered":true`) {
+		t.Errorf("test delivery: %s", w.Body.String())
+	}
+	if rc.events[0].Type != model.WebhookEventPing || rc.sigs[0] != Sign(created.Secret, rc.bodies[0]) {
+		t.Errorf("unexpected ping: %+v sig=%s", rc.events[0], rc.sigs[0])
+	}
+
+	if w := do("DELETE", "/api/v1/webhooks/"+created.ID, "", admin); w.Code != http.StatusNoContent {
+		t.Errorf("delete: status = %d", w.Code)
+	}
+	if w := do("GET", "/api/v1/webhooks/"+created.ID, "", admin); w.Code != http.StatusNotFound {
+		t.Errorf("get deleted: status = %d, want 404", w.Code)
+	}
+}
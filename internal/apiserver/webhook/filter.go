@@ -0,0 +1,234 @@
+package webhook
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// knownRunStatuses 状态迁移过滤允许的 Run 状态
+var knownRunStatuses = map[model.RunStatus]bool{
+	model.RunStatusQueued:    true,
+	model.RunStatusAssigned:  true,
+	model.RunStatusRunning:   true,
+	model.RunStatusPaused:    true,
+	model.RunStatusDone:      true,
+	model.RunStatusFailed:    true,
+	model.RunStatusCancelled: true,
+	model.RunStatusTimeout:   true,
+}
+
+// transition 状态迁移条件，空状态表示任意
+type transition struct {
+	from model.RunStatus
+	to   model.RunStatus
+}
+
+// parseTransition 解析 from->to 形式的状态迁移，任一侧可为 *
+func parseTransition(s string) (transition, error) {
+	from, to, ok := strings.Cut(s, "->")
+	if !ok {
+		return transition{}, fmt.Errorf("invalid transition %q: expected from->to", s)
+	}
+	var t transition
+	for _, side := range []struct {
+		raw string
+		dst *model.RunStatus
+	}{{from, &t.from}, {to, &t.to}} {
+		status := model.RunStatus(strings.TrimSpace(side.raw))
+		if status == "*" {
+			continue
+		}
+		if !knownRunStatuses[status] {
+			return transition{}, fmt.Errorf("invalid transition %q: unknown run status %q", s, status)
+		}
+		*side.dst = status
+	}
+	return t, nil
+}
+
+func (t transition) matches(from, to model.RunStatus) bool {
+	return (t.from == "" || t.from == from) && (t.to == "" || t.to == to)
+}
+
+// matcher 编译后的过滤条件（不含事件类型，事件类型由 index 负责）
+type matcher struct {
+	projects    map[string]bool
+	templates   map[string]bool
+	nodes       map[string]bool
+	labels      []storage.LabelRequirement
+	transitions []transition
+}
+
+// needsContext 是否需要 Run 所属任务的项目、模板、标签或执行节点才能判断
+func (m *matcher) needsContext() bool {
+	return m.projects != nil || m.templates != nil || m.nodes != nil || len(m.labels) > 0
+}
+
+func (m *matcher) match(ev *model.WebhookEvent) bool {
+	if m.projects != nil && !m.projects[ev.ProjectID] {
+		return false
+	}
+	if m.templates != nil && !m.templates[ev.TemplateID] {
+		return false
+	}
+	if m.nodes != nil && !m.nodes[ev.NodeID] {
+		return false
+	}
+	for _, req := range m.labels {
+		if !req.Matches(ev.Labels) {
+			return false
+		}
+	}
+	if len(m.transitions) > 0 {
+		if ev.Type != model.WebhookEventRunStatusChanged {
+			return false
+		}
+		for _, t := range m.transitions {
+			if t.matches(ev.FromStatus, ev.ToStatus) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// compileFilter 校验并编译过滤条件，返回事件类型（已去重）与其余条件的匹配器
+//
+// 只配置了状态迁移而未配置事件类型时，事件类型隐含为 run.status_changed。
+func compileFilter(f model.WebhookFilter) ([]string, *matcher, error) {
+	m := &matcher{projects: toSet(f.ProjectIDs), templates: toSet(f.TemplateIDs), nodes: toSet(f.NodeIDs)}
+	var err error
+	if m.labels, err = storage.ParseLabelSelector(f.LabelSelector); err != nil {
+		return nil, nil, err
+	}
+	for _, s := range f.Transitions {
+		t, err := parseTransition(s)
+		if err != nil {
+			return nil, nil, err
+		}
+		m.transitions = append(m.transitions, t)
+	}
+
+	seen := map[string]bool{}
+	var types []string
+	for _, t := range f.EventTypes {
+		t = strings.TrimSpace(t)
+		if t == "" || strings.Contains(strings.TrimSuffix(t, "*"), "*") {
+			return nil, nil, fmt.Errorf("invalid event type %q: only a trailing * wildcard is supported", t)
+		}
+		if !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 && len(m.transitions) > 0 {
+		types = []string{model.WebhookEventRunStatusChanged}
+	}
+	sort.Strings(types)
+	return types, m, nil
+}
+
+func toSet(values []string) map[string]bool {
+	if len(values) == 0 {
+		return nil
+	}
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
+
+// subscription 已编译的订阅
+type subscription struct {
+	hook    *model.Webhook
+	matcher *matcher
+}
+
+// prefixSubscriptions 前缀通配（如 run.event.*）的订阅
+type prefixSubscriptions struct {
+	prefix string
+	subs   []*subscription
+}
+
+// index 按事件类型索引的订阅
+//
+// 发布事件时只取出事件类型命中的订阅再逐个匹配，未被任何订阅关注的事件类型不做任何处理。
+type index struct {
+	exact    map[string][]*subscription
+	prefixes []prefixSubscriptions
+	any      []*subscription // 未限定事件类型
+}
+
+// buildIndex 编译启用的订阅并建立索引，过滤条件无效的订阅跳过并返回其错误
+func buildIndex(hooks []*model.Webhook) (*index, map[string]error) {
+	ix := &index{exact: map[string][]*subscription{}}
+	invalid := map[string]error{}
+	prefixes := map[string][]*subscription{}
+	for _, hook := range hooks {
+		if !hook.Enabled {
+			continue
+		}
+		types, m, err := compileFilter(hook.Filter)
+		if err != nil {
+			invalid[hook.ID] = err
+			continue
+		}
+		sub := &subscription{hook: hook, matcher: m}
+		if len(types) == 0 {
+			ix.any = append(ix.any, sub)
+		}
+		for _, t := range types {
+			if prefix, ok := strings.CutSuffix(t, "*"); ok {
+				prefixes[prefix] = append(prefixes[prefix], sub)
+			} else {
+				ix.exact[t] = append(ix.exact[t], sub)
+			}
+		}
+	}
+	for prefix, subs := range prefixes {
+		ix.prefixes = append(ix.prefixes, prefixSubscriptions{prefix: prefix, subs: subs})
+	}
+	return ix, invalid
+}
+
+// candidates 返回关注该事件类型的订阅（同一订阅只出现一次）
+func (ix *index) candidates(eventType string) []*subscription {
+	result := append([]*subscription(nil), ix.exact[eventType]...)
+	result = append(result, ix.any...)
+	for _, p := range ix.prefixes {
+		if strings.HasPrefix(eventType, p.prefix) {
+			result = append(result, p.subs...)
+		}
+	}
+	if len(ix.prefixes) == 0 {
+		return result
+	}
+	seen := make(map[*subscription]bool, len(result))
+	unique := result[:0]
+	for _, s := range result {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// interested 是否有订阅关注该事件类型
+func (ix *index) interested(eventType string) bool {
+	if len(ix.any) > 0 || len(ix.exact[eventType]) > 0 {
+		return true
+	}
+	for _, p := range ix.prefixes {
+		if strings.HasPrefix(eventType, p.prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestCompileFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  model.WebhookFilter
+		types   []string
+		wantErr bool
+	}{
+		{"empty", model.WebhookFilter{}, nil, false},
+		{"dedup and sort", model.WebhookFilter{EventTypes: []string{"run.event.*", "run.created", "run.created"}}, []string{"run.created", "run.event.*"}, false},
+		{"transition implies status_changed", model.WebhookFilter{Transitions: []string{"*->failed"}}, []string{model.WebhookEventRunStatusChanged}, false},
+		{"inner wildcard", model.WebhookFilter{EventTypes: []string{"run.*.tool_use"}}, nil, true},
+		{"bad transition", model.WebhookFilter{Transitions: []string{"running=>failed"}}, nil, true},
+		{"unknown status", model.WebhookFilter{Transitions: []string{"running->exploded"}}, nil, true},
+		{"bad selector", model.WebhookFilter{LabelSelector: "=prod"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			types, _, err := compileFilter(tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(types) != len(tt.types) {
+				t.Fatalf("types = %v, want %v", types, tt.types)
+			}
+			for i := range types {
+				if types[i] != tt.types[i] {
+					t.Errorf("types = %v, want %v", types, tt.types)
+				}
+			}
+		})
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	_, m, err := compileFilter(model.WebhookFilter{
+		ProjectIDs:    []string{"proj-1", "proj-2"},
+		NodeIDs:       []string{"node-1"},
+		LabelSelector: "env=prod, team!=infra, gpu, !canary",
+		Transitions:   []string{"running->failed", "*->timeout"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := func() *model.WebhookEvent {
+		return &model.WebhookEvent{
+			Type: model.WebhookEventRunStatusChanged, ProjectID: "proj-1", NodeID: "node-1",
+			Labels:     map[string]string{"env": "prod", "team": "ml", "gpu": "a100"},
+			FromStatus: model.RunStatusRunning, ToStatus: model.RunStatusFailed,
+		}
+	}
+	if !m.match(base()) {
+		t.Fatal("expected base event to match")
+	}
+	if !m.needsContext() {
+		t.Error("project/node/label filters need run context")
+	}
+
+	mutations := map[string]func(ev *model.WebhookEvent){
+		"other project":      func(ev *model.WebhookEvent) { ev.ProjectID = "proj-3" },
+		"other node":         func(ev *model.WebhookEvent) { ev.NodeID = "node-2" },
+		"label mismatch":     func(ev *model.WebhookEvent) { ev.Labels["env"] = "dev" },
+		"excluded label":     func(ev *model.WebhookEvent) { ev.Labels["team"] = "infra" },
+		"missing label":      func(ev *model.WebhookEvent) { delete(ev.Labels, "gpu") },
+		"forbidden label":    func(ev *model.WebhookEvent) { ev.Labels["canary"] = "true" },
+		"other transition":   func(ev *model.WebhookEvent) { ev.FromStatus = model.RunStatusQueued },
+		"not a status event": func(ev *model.WebhookEvent) { ev.Type = model.WebhookEventRunCreated },
+	}
+	for name, mutate := range mutations {
+		ev := base()
+		mutate(ev)
+		if m.match(ev) {
+			t.Errorf("%s: expected no match", name)
+		}
+	}
+
+	ev := base()
+	ev.FromStatus, ev.ToStatus = model.RunStatusAssigned, model.RunStatusTimeout
+	if !m.match(ev) {
+		t.Error("wildcard source transition should match")
+	}
+}
+
+func TestIndexCandidates(t *testing.T) {
+	hooks := []*model.Webhook{
+		{ID: "created", Enabled: true, Filter: model.WebhookFilter{EventTypes: []string{"run.created"}}},
+		{ID: "events", Enabled: true, Filter: model.WebhookFilter{EventTypes: []string{"run.event.*", "run.event.tool_use"}}},
+		{ID: "all", Enabled: true},
+		{ID: "disabled", Enabled: false, Filter: model.WebhookFilter{EventTypes: []string{"run.created"}}},
+		{ID: "invalid", Enabled: true, Filter: model.WebhookFilter{Transitions: []string{"bogus"}}},
+	}
+	ix, invalid := buildIndex(hooks)
+	if _, ok := invalid["invalid"]; !ok || len(invalid) != 1 {
+		t.Fatalf("invalid = %v", invalid)
+	}
+
+	ids := func(eventType string) map[string]bool {
+		result := map[string]bool{}
+		for _, s := range ix.candidates(eventType) {
+			if result[s.hook.ID] {
+				t.Errorf("%s: subscription %s returned twice", eventType, s.hook.ID)
+			}
+			result[s.hook.ID] = true
+		}
+		return result
+	}
+	if got := ids("run.created"); len(got) != 2 || !got["created"] || !got["all"] {
+		t.Errorf("run.created candidates = %v", got)
+	}
+	if got := ids("run.event.tool_use"); len(got) != 2 || !got["events"] || !got["all"] {
+		t.Errorf("run.event.tool_use candidates = %v", got)
+	}
+
+	ix, _ = buildIndex(hooks[:2])
+	if ix.interested(model.WebhookEventRunStatusChanged) {
+		t.Error("no subscription listens to run.status_changed")
+	}
+	if !ix.interested("run.event.message") {
+		t.Error("prefix subscription should be interested in run.event.message")
+	}
+}
@@ -0,0 +1,260 @@
+package webhook
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"time"
+
+	"agents-admin/internal/apiserver/auth"
+	"agents-admin/internal/shared/model"
+)
+
+// HandlerStore Webhook 管理接口需要的存储接口
+type HandlerStore interface {
+	CreateWebhook(ctx context.Context, w *model.Webhook) error
+	GetWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, w *model.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+// Handler Webhook 订阅管理 HTTP 处理器
+type Handler struct {
+	store HandlerStore
+	d     *Dispatcher
+}
+
+// NewHandler 创建 Webhook 处理器
+func NewHandler(store HandlerStore, d *Dispatcher) *Handler {
+	return &Handler{store: store, d: d}
+}
+
+// RegisterRoutes 注册 Webhook 相关路由（仅限管理员）
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /api/v1/webhooks", auth.AdminOnly(h.List))
+	mux.HandleFunc("POST /api/v1/webhooks", auth.AdminOnly(h.Create))
+	mux.HandleFunc("GET /api/v1/webhooks/{id}", auth.AdminOnly(h.Get))
+	mux.HandleFunc("PATCH /api/v1/webhooks/{id}", auth.AdminOnly(h.Update))
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", auth.AdminOnly(h.Delete))
+	mux.HandleFunc("POST /api/v1/webhooks/{id}/test", auth.AdminOnly(h.Test))
+}
+
+// CreateRequest 创建 Webhook 请求
+type CreateRequest struct {
+	Name    string              `json:"name"`
+	URL     string              `json:"url"`
+	Secret  string              `json:"secret,omitempty"`  // 为空时自动生成
+	Enabled *bool               `json:"enabled,omitempty"` // 默认启用
+	Filter  model.WebhookFilter `json:"filter"`
+}
+
+// UpdateRequest 更新 Webhook 请求（未提供的字段保持不变）
+type UpdateRequest struct {
+	Name    *string              `json:"name,omitempty"`
+	URL     *string              `json:"url,omitempty"`
+	Secret  *string              `json:"secret,omitempty"`
+	Enabled *bool                `json:"enabled,omitempty"`
+	Filter  *model.WebhookFilter `json:"filter,omitempty"`
+}
+
+// createdWebhook 创建响应（签名密钥只在创建时返回）
+type createdWebhook struct {
+	*model.Webhook
+	Secret string `json:"secret"`
+}
+
+// List 列出 Webhook 订阅
+// GET /api/v1/webhooks
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	hooks, err := h.store.ListWebhooks(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list webhooks")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"webhooks": hooks})
+}
+
+// Create 创建 Webhook 订阅
+// POST /api/v1/webhooks
+//
+// 请求体: {"name": "failures", "url": "https://...", "filter": {"event_types": ["run.status_changed"], "project_ids": ["proj-1"], "label_selector": "env=prod", "transitions": ["*->failed"]}}
+// 响应: 201 + 订阅（含签名密钥）；过滤条件无效时 400
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.Name == "" {
+		writeError(w, http.StatusBadRequest, "name is required")
+		return
+	}
+	now := time.Now()
+	hook := &model.Webhook{
+		ID:        generateID("wh"),
+		Name:      req.Name,
+		URL:       req.URL,
+		Secret:    req.Secret,
+		Enabled:   req.Enabled == nil || *req.Enabled,
+		Filter:    req.Filter,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if hook.Secret == "" {
+		hook.Secret = generateSecret()
+	}
+	if user := auth.GetAuthUser(r.Context()); user != nil {
+		hook.CreatedBy = user.ID
+	}
+	if err := Validate(hook); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := h.store.CreateWebhook(r.Context(), hook); err != nil {
+		slog.ErrorContext(r.Context(), "webhook.create.failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create webhook")
+		return
+	}
+	h.reload(r.Context())
+	slog.InfoContext(r.Context(), "webhook.created", "webhook_id", hook.ID, "url", hook.URL)
+	writeJSON(w, http.StatusCreated, createdWebhook{Webhook: hook, Secret: hook.Secret})
+}
+
+// Get 获取 Webhook 订阅
+// GET /api/v1/webhooks/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	hook := h.load(w, r)
+	if hook == nil {
+		return
+	}
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// Update 更新 Webhook 订阅
+// PATCH /api/v1/webhooks/{id}
+func (h *Handler) Update(w http.ResponseWriter, r *http.Request) {
+	var req UpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	hook := h.load(w, r)
+	if hook == nil {
+		return
+	}
+	if req.Name != nil {
+		hook.Name = *req.Name
+	}
+	if req.URL != nil {
+		hook.URL = *req.URL
+	}
+	if req.Secret != nil {
+		hook.Secret = *req.Secret
+	}
+	if req.Enabled != nil {
+		hook.Enabled = *req.Enabled
+	}
+	if req.Filter != nil {
+		hook.Filter = *req.Filter
+	}
+	if err := Validate(hook); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	hook.UpdatedAt = time.Now()
+	if err := h.store.UpdateWebhook(r.Context(), hook); err != nil {
+		slog.ErrorContext(r.Context(), "webhook.update.failed", "webhook_id", hook.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to update webhook")
+		return
+	}
+	h.reload(r.Context())
+	writeJSON(w, http.StatusOK, hook)
+}
+
+// Delete 删除 Webhook 订阅
+// DELETE /api/v1/webhooks/{id}
+func (h *Handler) Delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.store.DeleteWebhook(r.Context(), r.PathValue("id")); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete webhook")
+		return
+	}
+	h.reload(r.Context())
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Test 向订阅地址同步投递一个 ping 事件
+// POST /api/v1/webhooks/{id}/test
+//
+// 响应: {"delivered": true, "status": 200}，失败时附带 error
+func (h *Handler) Test(w http.ResponseWriter, r *http.Request) {
+	hook := h.load(w, r)
+	if hook == nil {
+		return
+	}
+	status, err := h.d.Test(r.Context(), hook)
+	resp := map[string]interface{}{"delivered": err == nil, "status": status}
+	if err != nil {
+		resp["error"] = err.Error()
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// load 读取路径中的 Webhook，不存在时写入 404 并返回 nil
+func (h *Handler) load(w http.ResponseWriter, r *http.Request) *model.Webhook {
+	hook, err := h.store.GetWebhook(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get webhook")
+		return nil
+	}
+	if hook == nil {
+		writeError(w, http.StatusNotFound, "webhook not found")
+		return nil
+	}
+	return hook
+}
+
+// reload 订阅变更后立即重新编译匹配器
+func (h *Handler) reload(ctx context.Context) {
+	if err := h.d.Reload(ctx); err != nil {
+		slog.ErrorContext(ctx, "webhook.reload.failed", "error", err)
+	}
+}
+
+// Validate 校验投递地址与过滤条件
+func Validate(hook *model.Webhook) error {
+	u, err := url.Parse(hook.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("url must be an absolute http(s) URL")
+	}
+	if _, _, err := compileFilter(hook.Filter); err != nil {
+		return err
+	}
+	return nil
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+// generateSecret 生成 256 位随机签名密钥
+func generateSecret() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
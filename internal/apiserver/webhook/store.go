@@ -0,0 +1,130 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// notifyingStore 在 Run 创建、状态迁移与事件写入成功后向 Dispatcher 发布通知事件
+//
+// 调度器、超时巡检、孤儿回收、HITL 与工作流编排器都通过存储层修改 Run 状态，
+// 在存储层统一发布可以覆盖所有状态迁移来源。
+type notifyingStore struct {
+	storage.PersistentStore
+	d *Dispatcher
+}
+
+// WrapStore 包装存储层，使 Run 创建、状态迁移与事件写入发布到 Dispatcher
+//
+// 没有订阅关注对应事件类型时不产生额外查询。
+func WrapStore(store storage.PersistentStore, d *Dispatcher) storage.PersistentStore {
+	return &notifyingStore{PersistentStore: store, d: d}
+}
+
+func (s *notifyingStore) CreateRun(ctx context.Context, run *model.Run) error {
+	if err := s.PersistentStore.CreateRun(ctx, run); err != nil {
+		return err
+	}
+	s.runCreated(ctx, run)
+	return nil
+}
+
+func (s *notifyingStore) CreateRunWithOutbox(ctx context.Context, run *model.Run, msgs ...*model.OutboxMessage) error {
+	if err := s.PersistentStore.CreateRunWithOutbox(ctx, run, msgs...); err != nil {
+		return err
+	}
+	s.runCreated(ctx, run)
+	return nil
+}
+
+func (s *notifyingStore) runCreated(ctx context.Context, run *model.Run) {
+	if s.d.Interested(model.WebhookEventRunCreated) {
+		s.d.Publish(ctx, &model.WebhookEvent{
+			Type:     model.WebhookEventRunCreated,
+			RunID:    run.ID,
+			TaskID:   run.TaskID,
+			NodeID:   derefString(run.NodeID),
+			ToStatus: run.Status,
+		})
+	}
+}
+
+func (s *notifyingStore) UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error {
+	// 迁移前的状态只在有订阅关注时读取
+	var prev *model.Run
+	if s.d.Interested(model.WebhookEventRunStatusChanged) {
+		prev, _ = s.PersistentStore.GetRun(ctx, id)
+	}
+	if err := s.PersistentStore.UpdateRunStatus(ctx, id, status, nodeID); err != nil {
+		return err
+	}
+	if prev == nil || prev.Status == status {
+		return nil
+	}
+	node := derefString(prev.NodeID)
+	if nodeID != nil {
+		node = *nodeID
+	}
+	s.d.Publish(ctx, &model.WebhookEvent{
+		Type:       model.WebhookEventRunStatusChanged,
+		RunID:      id,
+		TaskID:     prev.TaskID,
+		NodeID:     node,
+		FromStatus: prev.Status,
+		ToStatus:   status,
+	})
+	return nil
+}
+
+func (s *notifyingStore) CreateEvents(ctx context.Context, events []*model.Event) error {
+	if err := s.PersistentStore.CreateEvents(ctx, events); err != nil {
+		return err
+	}
+	for _, e := range events {
+		eventType := model.WebhookEventRunEventPrefix + e.Type
+		if !s.d.Interested(eventType) {
+			continue
+		}
+		data := map[string]interface{}{"seq": e.Seq, "event_type": e.Type}
+		if len(e.Payload) > 0 {
+			data["payload"] = json.RawMessage(e.Payload)
+		}
+		s.d.Publish(ctx, &model.WebhookEvent{Type: eventType, Timestamp: e.Timestamp, RunID: e.RunID, Data: data})
+	}
+	return nil
+}
+
+// DatabaseSize 透传底层存储的空间统计（管理后台总览通过类型断言调用）
+func (s *notifyingStore) DatabaseSize(ctx context.Context) (int64, error) {
+	if sizer, ok := s.PersistentStore.(interface {
+		DatabaseSize(ctx context.Context) (int64, error)
+	}); ok {
+		return sizer.DatabaseSize(ctx)
+	}
+	return 0, errors.ErrUnsupported
+}
+
+// ReplicaStatus 透传底层存储的只读副本状态（管理后台总览通过类型断言调用）
+func (s *notifyingStore) ReplicaStatus() *storage.ReplicaStatus {
+	if r, ok := s.PersistentStore.(interface {
+		ReplicaStatus() *storage.ReplicaStatus
+	}); ok {
+		return r.ReplicaStatus()
+	}
+	return nil
+}
+
+// PoolStats 透传底层存储的连接池统计（指标导出通过类型断言调用）
+func (s *notifyingStore) PoolStats() map[string]sql.DBStats {
+	if p, ok := s.PersistentStore.(interface {
+		PoolStats() map[string]sql.DBStats
+	}); ok {
+		return p.PoolStats()
+	}
+	return nil
+}
@@ -0,0 +1,250 @@
+// Package workflow DAG 工作流领域 - HTTP 处理与编排
+//
+// 工作流以父任务（root_task_id）的子任务为节点，节点通过 depends_on 声明依赖边。
+// 编排器（Orchestrator）只在节点的全部依赖成功完成后才为其创建 Run：
+//   - fail_fast（默认）：任一节点失败后跳过剩余节点，并取消执行中的 Run
+//   - continue_on_error：仅跳过失败节点的下游，互不依赖的分支继续执行
+package workflow
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// errAlreadyFinished 工作流已处于终态，无法取消
+var errAlreadyFinished = errors.New("workflow already finished")
+
+// Store 定义工作流 handler 与编排器需要的存储接口（用于测试 mock）
+type Store interface {
+	GetTask(ctx context.Context, id string) (*model.Task, error)
+	ListSubTasks(ctx context.Context, parentID string) ([]*model.Task, error)
+	UpdateTaskStatus(ctx context.Context, id string, status model.TaskStatus) error
+	GetRun(ctx context.Context, id string) (*model.Run, error)
+	UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error
+	CreateWorkflow(ctx context.Context, wf *model.Workflow) error
+	GetWorkflow(ctx context.Context, id string) (*model.Workflow, error)
+	ListWorkflows(ctx context.Context, status string, limit, offset int) ([]*model.Workflow, error)
+	UpdateWorkflow(ctx context.Context, wf *model.Workflow) error
+}
+
+// Handler 工作流领域 HTTP 处理器
+type Handler struct {
+	store        Store
+	orchestrator *Orchestrator
+}
+
+// NewHandler 创建工作流处理器
+func NewHandler(store Store, orchestrator *Orchestrator) *Handler {
+	return &Handler{store: store, orchestrator: orchestrator}
+}
+
+// RegisterRoutes 注册工作流相关路由
+func (h *Handler) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /api/v1/workflows", h.Create)
+	mux.HandleFunc("GET /api/v1/workflows", h.List)
+	mux.HandleFunc("GET /api/v1/workflows/{id}", h.Get)
+	mux.HandleFunc("POST /api/v1/workflows/{id}/cancel", h.Cancel)
+}
+
+// CreateRequest 创建工作流的请求体
+type CreateRequest struct {
+	// RootTaskID 根任务 ID，节点必须是其直接子任务
+	RootTaskID string `json:"root_task_id"`
+
+	// Name 工作流名称（为空时取根任务名称）
+	Name string `json:"name,omitempty"`
+
+	// Mode 失败处理模式：fail_fast（默认）/ continue_on_error
+	Mode model.WorkflowMode `json:"mode,omitempty"`
+
+	// Nodes 节点与依赖边
+	Nodes []struct {
+		TaskID    string   `json:"task_id"`
+		DependsOn []string `json:"depends_on,omitempty"`
+	} `json:"nodes"`
+}
+
+// Create 创建工作流并立即启动无依赖的节点
+// POST /api/v1/workflows
+//
+// 请求体:
+//
+//	{
+//	  "root_task_id": "task-xxx",
+//	  "mode": "fail_fast",
+//	  "nodes": [
+//	    {"task_id": "task-build"},
+//	    {"task_id": "task-test", "depends_on": ["task-build"]}
+//	  ]
+//	}
+//
+// 错误响应:
+//   - 400 Bad Request: 模式非法、节点重复/依赖未知/存在环、节点不是根任务的子任务
+//   - 404 Not Found: 根任务不存在
+func (h *Handler) Create(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	var req CreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.RootTaskID == "" {
+		writeError(w, http.StatusBadRequest, "root_task_id is required")
+		return
+	}
+	if !req.Mode.IsValid() {
+		writeError(w, http.StatusBadRequest, "invalid mode: must be fail_fast or continue_on_error")
+		return
+	}
+
+	root, err := h.store.GetTask(ctx, req.RootTaskID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get root task")
+		return
+	}
+	if root == nil {
+		writeError(w, http.StatusNotFound, "root task not found")
+		return
+	}
+
+	now := time.Now()
+	wf := &model.Workflow{
+		ID:         generateID("wf"),
+		Name:       req.Name,
+		RootTaskID: root.ID,
+		Mode:       req.Mode.OrDefault(),
+		Status:     model.WorkflowStatusRunning,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if wf.Name == "" {
+		wf.Name = root.Name
+	}
+	for _, n := range req.Nodes {
+		wf.Nodes = append(wf.Nodes, model.WorkflowNode{
+			TaskID:    n.TaskID,
+			DependsOn: n.DependsOn,
+			Status:    model.WorkflowNodePending,
+		})
+	}
+	if err := wf.Validate(); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// 节点必须是根任务的直接子任务
+	subtasks, err := h.store.ListSubTasks(ctx, root.ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list subtasks")
+		return
+	}
+	children := make(map[string]bool, len(subtasks))
+	for _, t := range subtasks {
+		children[t.ID] = true
+	}
+	for _, n := range wf.Nodes {
+		if !children[n.TaskID] {
+			writeError(w, http.StatusBadRequest, "task "+n.TaskID+" is not a subtask of root task")
+			return
+		}
+	}
+
+	if err := h.store.CreateWorkflow(ctx, wf); err != nil {
+		slog.ErrorContext(ctx, "workflow.create.failed", "workflow_id", wf.ID, "error", err)
+		writeError(w, http.StatusInternalServerError, "failed to create workflow")
+		return
+	}
+	h.store.UpdateTaskStatus(ctx, root.ID, model.TaskStatusInProgress)
+	slog.InfoContext(ctx, "workflow.created", "workflow_id", wf.ID, "root_task_id", root.ID, "mode", wf.Mode, "nodes", len(wf.Nodes))
+
+	// 立即启动无依赖的节点
+	if advanced, err := h.orchestrator.Advance(ctx, wf.ID); err != nil {
+		slog.ErrorContext(ctx, "workflow.create.advance.failed", "workflow_id", wf.ID, "error", err)
+	} else if advanced != nil {
+		wf = advanced
+	}
+	writeJSON(w, http.StatusCreated, wf)
+}
+
+// List 列出工作流
+// GET /api/v1/workflows?status=running&limit=50&offset=0
+func (h *Handler) List(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	if limit <= 0 || limit > 200 {
+		limit = 50
+	}
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if offset < 0 {
+		offset = 0
+	}
+
+	workflows, err := h.store.ListWorkflows(r.Context(), q.Get("status"), limit, offset)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to list workflows")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"workflows": workflows, "count": len(workflows)})
+}
+
+// Get 获取工作流详情（含节点状态与对应 Run ID）
+// GET /api/v1/workflows/{id}
+func (h *Handler) Get(w http.ResponseWriter, r *http.Request) {
+	wf, err := h.store.GetWorkflow(r.Context(), r.PathValue("id"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get workflow")
+		return
+	}
+	if wf == nil {
+		writeError(w, http.StatusNotFound, "workflow not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, wf)
+}
+
+// Cancel 取消工作流
+// POST /api/v1/workflows/{id}/cancel
+//
+// 待启动节点标记为 skipped，执行中节点的 Run 被取消；已结束的工作流返回 409。
+func (h *Handler) Cancel(w http.ResponseWriter, r *http.Request) {
+	wf, err := h.orchestrator.Cancel(r.Context(), r.PathValue("id"))
+	switch {
+	case errors.Is(err, errAlreadyFinished):
+		writeError(w, http.StatusConflict, err.Error())
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, "failed to cancel workflow")
+	case wf == nil:
+		writeError(w, http.StatusNotFound, "workflow not found")
+	default:
+		writeJSON(w, http.StatusOK, wf)
+	}
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+func writeJSON(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(data)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+func generateID(prefix string) string {
+	b := make([]byte, 6)
+	rand.Read(b)
+	return prefix + "-" + hex.EncodeToString(b)
+}
@@ -0,0 +1,229 @@
+package workflow
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// mockStore 内存存储：任务树、Run 与工作流
+type mockStore struct {
+	tasks     map[string]*model.Task
+	runs      map[string]*model.Run
+	workflows map[string]*model.Workflow
+}
+
+func newMockStore(root string, children ...string) *mockStore {
+	m := &mockStore{
+		tasks:     map[string]*model.Task{root: {ID: root, Name: "pipeline", Status: model.TaskStatusPending}},
+		runs:      map[string]*model.Run{},
+		workflows: map[string]*model.Workflow{},
+	}
+	for _, id := range children {
+		parent := root
+		m.tasks[id] = &model.Task{ID: id, Name: id, ParentID: &parent, Status: model.TaskStatusPending}
+	}
+	return m
+}
+
+func (m *mockStore) GetTask(_ context.Context, id string) (*model.Task, error) {
+	return m.tasks[id], nil
+}
+func (m *mockStore) ListSubTasks(_ context.Context, parentID string) ([]*model.Task, error) {
+	var list []*model.Task
+	for _, t := range m.tasks {
+		if t.ParentID != nil && *t.ParentID == parentID {
+			list = append(list, t)
+		}
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateTaskStatus(_ context.Context, id string, status model.TaskStatus) error {
+	if t, ok := m.tasks[id]; ok {
+		t.Status = status
+	}
+	return nil
+}
+func (m *mockStore) GetRun(_ context.Context, id string) (*model.Run, error) {
+	return m.runs[id], nil
+}
+func (m *mockStore) UpdateRunStatus(_ context.Context, id string, status model.RunStatus, _ *string) error {
+	if r, ok := m.runs[id]; ok {
+		r.Status = status
+	}
+	return nil
+}
+func (m *mockStore) CreateWorkflow(_ context.Context, wf *model.Workflow) error {
+	m.workflows[wf.ID] = cloneWorkflow(wf)
+	return nil
+}
+func (m *mockStore) GetWorkflow(_ context.Context, id string) (*model.Workflow, error) {
+	if wf, ok := m.workflows[id]; ok {
+		return cloneWorkflow(wf), nil
+	}
+	return nil, nil
+}
+func (m *mockStore) ListWorkflows(_ context.Context, status string, _, _ int) ([]*model.Workflow, error) {
+	var list []*model.Workflow
+	for _, wf := range m.workflows {
+		if status == "" || string(wf.Status) == status {
+			list = append(list, cloneWorkflow(wf))
+		}
+	}
+	return list, nil
+}
+func (m *mockStore) UpdateWorkflow(_ context.Context, wf *model.Workflow) error {
+	m.workflows[wf.ID] = cloneWorkflow(wf)
+	return nil
+}
+
+// cloneWorkflow 模拟数据库读写的值语义
+func cloneWorkflow(wf *model.Workflow) *model.Workflow {
+	c := *wf
+	c.Nodes = append([]model.WorkflowNode(nil), wf.Nodes...)
+	return &c
+}
+
+// mockStarter 为任务创建 queued 状态的 Run，failTasks 中的任务创建失败
+type mockStarter struct {
+	store     *mockStore
+	started   []string
+	failTasks map[string]bool
+}
+
+func (s *mockStarter) StartRun(_ context.Context, taskID string) (*model.Run, error) {
+	if s.failTasks[taskID] {
+		return nil, fmt.Errorf("no pool account")
+	}
+	s.started = append(s.started, taskID)
+	run := &model.Run{ID: "run-" + taskID, TaskID: taskID, Status: model.RunStatusQueued}
+	s.store.runs[run.ID] = run
+	return run, nil
+}
+
+func newTestMux(store *mockStore) (*http.ServeMux, *mockStarter) {
+	starter := &mockStarter{store: store}
+	mux := http.NewServeMux()
+	NewHandler(store, NewOrchestrator(store, starter)).RegisterRoutes(mux)
+	return mux, starter
+}
+
+func TestCreateWorkflow(t *testing.T) {
+	store := newMockStore("root", "a", "b", "c", "d")
+	mux, starter := newTestMux(store)
+
+	body := `{"root_task_id":"root","nodes":[
+		{"task_id":"a"},
+		{"task_id":"b","depends_on":["a"]},
+		{"task_id":"c","depends_on":["a"]},
+		{"task_id":"d","depends_on":["b","c"]}]}`
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/workflows", strings.NewReader(body)))
+	if w.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	var wf model.Workflow
+	json.Unmarshal(w.Body.Bytes(), &wf)
+	if wf.Mode != model.WorkflowModeFailFast || wf.Name != "pipeline" || wf.Status != model.WorkflowStatusRunning {
+		t.Errorf("wf = %+v", wf)
+	}
+	if a := wf.Node("a"); a == nil || a.Status != model.WorkflowNodeRunning || a.RunID != "run-a" {
+		t.Errorf("节点 a 应已启动: %+v", a)
+	}
+	if len(starter.started) != 1 {
+		t.Errorf("仅无依赖节点应启动, started = %v", starter.started)
+	}
+	if store.tasks["root"].Status != model.TaskStatusInProgress {
+		t.Errorf("根任务状态 = %s", store.tasks["root"].Status)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/workflows/"+wf.ID, nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("GET status = %d", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("GET", "/api/v1/workflows?status=running", nil))
+	var list struct {
+		Count int `json:"count"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &list)
+	if list.Count != 1 {
+		t.Errorf("list count = %d", list.Count)
+	}
+}
+
+func TestCreateWorkflow_Invalid(t *testing.T) {
+	store := newMockStore("root", "a", "b")
+	store.tasks["other"] = &model.Task{ID: "other"}
+	mux, starter := newTestMux(store)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"缺少根任务", `{"nodes":[{"task_id":"a"}]}`, http.StatusBadRequest},
+		{"根任务不存在", `{"root_task_id":"missing","nodes":[{"task_id":"a"}]}`, http.StatusNotFound},
+		{"模式非法", `{"root_task_id":"root","mode":"retry","nodes":[{"task_id":"a"}]}`, http.StatusBadRequest},
+		{"依赖成环", `{"root_task_id":"root","nodes":[{"task_id":"a","depends_on":["b"]},{"task_id":"b","depends_on":["a"]}]}`, http.StatusBadRequest},
+		{"非子任务", `{"root_task_id":"root","nodes":[{"task_id":"other"}]}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/workflows", strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, 期望 %d, body = %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+	if len(store.workflows) != 0 || len(starter.started) != 0 {
+		t.Errorf("校验失败不应创建工作流或 Run")
+	}
+}
+
+func TestCancelWorkflow(t *testing.T) {
+	store := newMockStore("root", "a", "b")
+	mux, _ := newTestMux(store)
+
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/workflows",
+		strings.NewReader(`{"root_task_id":"root","nodes":[{"task_id":"a"},{"task_id":"b","depends_on":["a"]}]}`)))
+	var wf model.Workflow
+	json.Unmarshal(w.Body.Bytes(), &wf)
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/workflows/"+wf.ID+"/cancel", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+	json.Unmarshal(w.Body.Bytes(), &wf)
+	if wf.Status != model.WorkflowStatusCancelled || wf.FinishedAt == nil {
+		t.Errorf("wf = %+v", wf)
+	}
+	if wf.Node("a").Status != model.WorkflowNodeCancelled || wf.Node("b").Status != model.WorkflowNodeSkipped {
+		t.Errorf("nodes = %+v", wf.Nodes)
+	}
+	if store.runs["run-a"].Status != model.RunStatusCancelled || store.tasks["root"].Status != model.TaskStatusCancelled {
+		t.Errorf("执行中的 Run 与根任务应被取消")
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/workflows/"+wf.ID+"/cancel", nil))
+	if w.Code != http.StatusConflict {
+		t.Errorf("重复取消 status = %d, 期望 409", w.Code)
+	}
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest("POST", "/api/v1/workflows/wf-missing/cancel", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("不存在 status = %d, 期望 404", w.Code)
+	}
+}
@@ -0,0 +1,321 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// defaultReconcileInterval 编排器保底巡检间隔
+//
+// Run 的终态可能由多条路径写入（NodeManager 上报、调度器超时回收等），
+// 除 Notify 触发的即时推进外，定期巡检保证所有执行中的工作流最终被推进。
+const defaultReconcileInterval = 10 * time.Second
+
+// RunStarter 为任务创建 Run 并加入调度队列（由 run.Handler 实现）
+type RunStarter interface {
+	StartRun(ctx context.Context, taskID string) (*model.Run, error)
+}
+
+// Orchestrator DAG 工作流编排器
+//
+// 职责：
+//   - 同步执行中节点的 Run 状态
+//   - 依赖全部成功的节点创建 Run；依赖未成功的节点跳过
+//   - fail_fast 模式下任一节点失败即跳过剩余节点并取消执行中的 Run
+//   - 全部节点结束后写入工作流终态，并联动更新根任务状态
+//
+// 所有推进操作串行执行（mu），推进前从存储重新加载工作流，避免同一节点重复创建 Run。
+type Orchestrator struct {
+	store    Store
+	runs     RunStarter
+	interval time.Duration
+
+	mu     sync.Mutex
+	notify chan struct{}
+}
+
+// NewOrchestrator 创建工作流编排器
+func NewOrchestrator(store Store, runs RunStarter) *Orchestrator {
+	return &Orchestrator{
+		store:    store,
+		runs:     runs,
+		interval: defaultReconcileInterval,
+		notify:   make(chan struct{}, 1),
+	}
+}
+
+// Start 启动编排循环（阻塞直到 ctx 取消）
+func (o *Orchestrator) Start(ctx context.Context) {
+	slog.InfoContext(ctx, "workflow.orchestrator.start", "interval", o.interval)
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			slog.InfoContext(ctx, "workflow.orchestrator.stopped")
+			return
+		case <-ticker.C:
+		case <-o.notify:
+		}
+		o.reconcile(ctx)
+	}
+}
+
+// Notify 通知编排器有 Run 到达终态，尽快推进工作流（非阻塞）
+func (o *Orchestrator) Notify() {
+	select {
+	case o.notify <- struct{}{}:
+	default:
+	}
+}
+
+// reconcile 推进所有执行中的工作流
+func (o *Orchestrator) reconcile(ctx context.Context) {
+	workflows, err := o.store.ListWorkflows(ctx, string(model.WorkflowStatusRunning), 0, 0)
+	if err != nil {
+		slog.ErrorContext(ctx, "workflow.reconcile.list.failed", "error", err)
+		return
+	}
+	for _, wf := range workflows {
+		if _, err := o.Advance(ctx, wf.ID); err != nil {
+			slog.ErrorContext(ctx, "workflow.reconcile.failed", "workflow_id", wf.ID, "error", err)
+		}
+	}
+}
+
+// Advance 推进单个工作流，返回推进后的工作流（不存在时返回 nil）
+func (o *Orchestrator) Advance(ctx context.Context, id string) (*model.Workflow, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	wf, err := o.store.GetWorkflow(ctx, id)
+	if err != nil || wf == nil || wf.Status.IsTerminal() {
+		return wf, err
+	}
+	if !o.advance(ctx, wf) {
+		return wf, nil
+	}
+	return wf, o.save(ctx, wf)
+}
+
+// Cancel 取消工作流：跳过待启动节点并取消执行中的 Run
+//
+// 返回 errAlreadyFinished 表示工作流已处于终态。
+func (o *Orchestrator) Cancel(ctx context.Context, id string) (*model.Workflow, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	wf, err := o.store.GetWorkflow(ctx, id)
+	if err != nil || wf == nil {
+		return wf, err
+	}
+	if wf.Status.IsTerminal() {
+		return wf, errAlreadyFinished
+	}
+
+	o.abort(ctx, wf, "workflow cancelled")
+	o.finish(ctx, wf, model.WorkflowStatusCancelled)
+	slog.InfoContext(ctx, "workflow.cancelled", "workflow_id", wf.ID)
+	return wf, o.save(ctx, wf)
+}
+
+// advance 推进工作流状态机，返回是否有变化
+func (o *Orchestrator) advance(ctx context.Context, wf *model.Workflow) bool {
+	changed := o.syncRunning(ctx, wf)
+
+	for {
+		if wf.Mode.OrDefault() == model.WorkflowModeFailFast && hasFailure(wf) {
+			if o.abort(ctx, wf, "workflow failed (fail_fast)") {
+				changed = true
+			}
+			break
+		}
+
+		progressed := false
+		for i := range wf.Nodes {
+			n := &wf.Nodes[i]
+			if n.Status != model.WorkflowNodePending {
+				continue
+			}
+			ready, blockedBy := dependencyState(wf, n)
+			switch {
+			case blockedBy != "":
+				n.Status = model.WorkflowNodeSkipped
+				n.Error = fmt.Sprintf("dependency %s did not succeed", blockedBy)
+				slog.InfoContext(ctx, "workflow.node.skipped", "workflow_id", wf.ID, "task_id", n.TaskID, "blocked_by", blockedBy)
+			case ready:
+				o.startNode(ctx, wf, n)
+			default:
+				continue
+			}
+			progressed = true
+			if n.Status == model.WorkflowNodeFailed {
+				// 启动失败可能触发 fail_fast，回到外层重新判断
+				break
+			}
+		}
+		if !progressed {
+			break
+		}
+		changed = true
+	}
+
+	if status, done := finalStatus(wf); done {
+		o.finish(ctx, wf, status)
+		slog.InfoContext(ctx, "workflow.finished", "workflow_id", wf.ID, "status", status)
+		changed = true
+	}
+	return changed
+}
+
+// syncRunning 将执行中节点的 Run 终态同步到节点状态
+func (o *Orchestrator) syncRunning(ctx context.Context, wf *model.Workflow) bool {
+	changed := false
+	for i := range wf.Nodes {
+		n := &wf.Nodes[i]
+		if n.Status != model.WorkflowNodeRunning {
+			continue
+		}
+		run, err := o.store.GetRun(ctx, n.RunID)
+		if err != nil {
+			slog.ErrorContext(ctx, "workflow.node.sync.failed", "workflow_id", wf.ID, "run_id", n.RunID, "error", err)
+			continue
+		}
+		switch {
+		case run == nil:
+			n.Status = model.WorkflowNodeFailed
+			n.Error = "run not found"
+		case run.Status == model.RunStatusDone:
+			n.Status = model.WorkflowNodeSucceeded
+		case isRunFinished(run.Status):
+			n.Status = model.WorkflowNodeFailed
+			n.Error = "run " + string(run.Status)
+		default:
+			continue
+		}
+		slog.InfoContext(ctx, "workflow.node.finished", "workflow_id", wf.ID, "task_id", n.TaskID, "run_id", n.RunID, "status", n.Status)
+		changed = true
+	}
+	return changed
+}
+
+// startNode 为节点的子任务创建 Run
+func (o *Orchestrator) startNode(ctx context.Context, wf *model.Workflow, n *model.WorkflowNode) {
+	run, err := o.runs.StartRun(ctx, n.TaskID)
+	if err != nil {
+		n.Status = model.WorkflowNodeFailed
+		n.Error = "failed to start run: " + err.Error()
+		slog.ErrorContext(ctx, "workflow.node.start.failed", "workflow_id", wf.ID, "task_id", n.TaskID, "error", err)
+		return
+	}
+	n.Status = model.WorkflowNodeRunning
+	n.RunID = run.ID
+	slog.InfoContext(ctx, "workflow.node.started", "workflow_id", wf.ID, "task_id", n.TaskID, "run_id", run.ID)
+}
+
+// abort 跳过所有待启动节点并取消执行中的 Run，返回是否有变化
+func (o *Orchestrator) abort(ctx context.Context, wf *model.Workflow, reason string) bool {
+	changed := false
+	for i := range wf.Nodes {
+		n := &wf.Nodes[i]
+		switch n.Status {
+		case model.WorkflowNodePending:
+			n.Status = model.WorkflowNodeSkipped
+		case model.WorkflowNodeRunning:
+			if err := o.store.UpdateRunStatus(ctx, n.RunID, model.RunStatusCancelled, nil); err != nil {
+				slog.ErrorContext(ctx, "workflow.node.cancel.failed", "workflow_id", wf.ID, "run_id", n.RunID, "error", err)
+			}
+			o.store.UpdateTaskStatus(ctx, n.TaskID, model.TaskStatusCancelled)
+			n.Status = model.WorkflowNodeCancelled
+		default:
+			continue
+		}
+		n.Error = reason
+		changed = true
+	}
+	return changed
+}
+
+// finish 写入工作流终态，并联动更新根任务状态
+func (o *Orchestrator) finish(ctx context.Context, wf *model.Workflow, status model.WorkflowStatus) {
+	now := time.Now()
+	wf.Status = status
+	wf.FinishedAt = &now
+
+	taskStatus := model.TaskStatusFailed
+	switch status {
+	case model.WorkflowStatusSucceeded:
+		taskStatus = model.TaskStatusCompleted
+	case model.WorkflowStatusCancelled:
+		taskStatus = model.TaskStatusCancelled
+	}
+	if err := o.store.UpdateTaskStatus(ctx, wf.RootTaskID, taskStatus); err != nil {
+		slog.ErrorContext(ctx, "workflow.root_task.update.failed", "workflow_id", wf.ID, "task_id", wf.RootTaskID, "error", err)
+	}
+}
+
+// save 持久化工作流状态
+func (o *Orchestrator) save(ctx context.Context, wf *model.Workflow) error {
+	wf.UpdatedAt = time.Now()
+	return o.store.UpdateWorkflow(ctx, wf)
+}
+
+// dependencyState 判断节点依赖状态
+//
+// 返回 ready=true 表示全部依赖成功；blockedBy 非空表示该依赖已结束但未成功，节点应跳过。
+func dependencyState(wf *model.Workflow, n *model.WorkflowNode) (ready bool, blockedBy string) {
+	ready = true
+	for _, dep := range n.DependsOn {
+		d := wf.Node(dep)
+		if d == nil {
+			return false, dep
+		}
+		switch d.Status {
+		case model.WorkflowNodeSucceeded:
+		case model.WorkflowNodePending, model.WorkflowNodeRunning:
+			ready = false
+		default:
+			return false, dep
+		}
+	}
+	return ready, ""
+}
+
+// hasFailure 是否存在失败或被取消的节点
+func hasFailure(wf *model.Workflow) bool {
+	for _, n := range wf.Nodes {
+		if n.Status == model.WorkflowNodeFailed || n.Status == model.WorkflowNodeCancelled {
+			return true
+		}
+	}
+	return false
+}
+
+// finalStatus 全部节点结束时返回工作流终态
+func finalStatus(wf *model.Workflow) (model.WorkflowStatus, bool) {
+	status := model.WorkflowStatusSucceeded
+	for _, n := range wf.Nodes {
+		if !n.Status.IsTerminal() {
+			return "", false
+		}
+		if n.Status != model.WorkflowNodeSucceeded {
+			status = model.WorkflowStatusFailed
+		}
+	}
+	return status, true
+}
+
+// isRunFinished Run 是否已到达终态
+func isRunFinished(status model.RunStatus) bool {
+	switch status {
+	case model.RunStatusDone, model.RunStatusFailed, model.RunStatusCancelled, model.RunStatusTimeout:
+		return true
+	}
+	return false
+}
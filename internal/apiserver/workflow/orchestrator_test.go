@@ -0,0 +1,149 @@
+package workflow
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// setupWorkflow 创建工作流并执行首次推进
+func setupWorkflow(t *testing.T, mode model.WorkflowMode, nodes ...model.WorkflowNode) (*mockStore, *mockStarter, *Orchestrator) {
+	t.Helper()
+	var ids []string
+	for i := range nodes {
+		nodes[i].Status = model.WorkflowNodePending
+		ids = append(ids, nodes[i].TaskID)
+	}
+	store := newMockStore("root", ids...)
+	starter := &mockStarter{store: store, failTasks: map[string]bool{}}
+	o := NewOrchestrator(store, starter)
+	store.CreateWorkflow(context.Background(), &model.Workflow{
+		ID: "wf-1", RootTaskID: "root", Mode: mode, Status: model.WorkflowStatusRunning, Nodes: nodes,
+	})
+	return store, starter, o
+}
+
+func node(id string, deps ...string) model.WorkflowNode {
+	return model.WorkflowNode{TaskID: id, DependsOn: deps}
+}
+
+// finishRun 模拟 Run 到达终态后推进工作流
+func finishRun(t *testing.T, store *mockStore, o *Orchestrator, taskID string, status model.RunStatus) *model.Workflow {
+	t.Helper()
+	store.runs["run-"+taskID].Status = status
+	wf, err := o.Advance(context.Background(), "wf-1")
+	if err != nil {
+		t.Fatalf("Advance: %v", err)
+	}
+	return wf
+}
+
+func assertNodes(t *testing.T, wf *model.Workflow, want map[string]model.WorkflowNodeStatus) {
+	t.Helper()
+	for id, status := range want {
+		if got := wf.Node(id).Status; got != status {
+			t.Errorf("节点 %s 状态 = %s, 期望 %s", id, got, status)
+		}
+	}
+}
+
+// TestOrchestrator_Diamond 依赖全部成功后才启动下游节点
+func TestOrchestrator_Diamond(t *testing.T) {
+	store, starter, o := setupWorkflow(t, "", node("a"), node("b", "a"), node("c", "a"), node("d", "b", "c"))
+	ctx := context.Background()
+
+	wf, _ := o.Advance(ctx, "wf-1")
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{"a": model.WorkflowNodeRunning, "b": model.WorkflowNodePending})
+
+	// 未到终态的 Run 不推进
+	store.runs["run-a"].Status = model.RunStatusRunning
+	wf, _ = o.Advance(ctx, "wf-1")
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{"a": model.WorkflowNodeRunning})
+
+	wf = finishRun(t, store, o, "a", model.RunStatusDone)
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{
+		"a": model.WorkflowNodeSucceeded, "b": model.WorkflowNodeRunning, "c": model.WorkflowNodeRunning, "d": model.WorkflowNodePending,
+	})
+
+	wf = finishRun(t, store, o, "b", model.RunStatusDone)
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{"d": model.WorkflowNodePending})
+
+	wf = finishRun(t, store, o, "c", model.RunStatusDone)
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{"d": model.WorkflowNodeRunning})
+
+	wf = finishRun(t, store, o, "d", model.RunStatusDone)
+	if wf.Status != model.WorkflowStatusSucceeded || wf.FinishedAt == nil {
+		t.Errorf("wf = %+v", wf)
+	}
+	if store.tasks["root"].Status != model.TaskStatusCompleted {
+		t.Errorf("根任务状态 = %s", store.tasks["root"].Status)
+	}
+	if len(starter.started) != 4 {
+		t.Errorf("每个节点只应启动一次, started = %v", starter.started)
+	}
+}
+
+// TestOrchestrator_FailFast 任一节点失败后跳过剩余节点并取消执行中的 Run
+func TestOrchestrator_FailFast(t *testing.T) {
+	store, _, o := setupWorkflow(t, model.WorkflowModeFailFast, node("a"), node("b", "a"), node("c"))
+	o.Advance(context.Background(), "wf-1")
+
+	wf := finishRun(t, store, o, "a", model.RunStatusFailed)
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{
+		"a": model.WorkflowNodeFailed, "b": model.WorkflowNodeSkipped, "c": model.WorkflowNodeCancelled,
+	})
+	if wf.Status != model.WorkflowStatusFailed {
+		t.Errorf("status = %s", wf.Status)
+	}
+	if store.runs["run-c"].Status != model.RunStatusCancelled {
+		t.Errorf("独立分支的 Run 应被取消, status = %s", store.runs["run-c"].Status)
+	}
+	if store.tasks["root"].Status != model.TaskStatusFailed {
+		t.Errorf("根任务状态 = %s", store.tasks["root"].Status)
+	}
+}
+
+// TestOrchestrator_ContinueOnError 只跳过失败节点的下游，独立分支继续执行
+func TestOrchestrator_ContinueOnError(t *testing.T) {
+	store, _, o := setupWorkflow(t, model.WorkflowModeContinueOnError,
+		node("a"), node("b", "a"), node("e", "b"), node("c"), node("d", "c"))
+	o.Advance(context.Background(), "wf-1")
+
+	wf := finishRun(t, store, o, "a", model.RunStatusTimeout)
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{
+		"a": model.WorkflowNodeFailed, "b": model.WorkflowNodeSkipped, "e": model.WorkflowNodeSkipped, "c": model.WorkflowNodeRunning,
+	})
+	if wf.Node("a").Error != "run timeout" || wf.Status != model.WorkflowStatusRunning {
+		t.Errorf("wf = %+v", wf)
+	}
+
+	wf = finishRun(t, store, o, "c", model.RunStatusDone)
+	assertNodes(t, wf, map[string]model.WorkflowNodeStatus{"d": model.WorkflowNodeRunning})
+
+	wf = finishRun(t, store, o, "d", model.RunStatusDone)
+	if wf.Status != model.WorkflowStatusFailed {
+		t.Errorf("存在失败节点时工作流应为 failed, status = %s", wf.Status)
+	}
+}
+
+// TestOrchestrator_StartFailure Run 创建失败视为节点失败
+func TestOrchestrator_StartFailure(t *testing.T) {
+	store, starter, o := setupWorkflow(t, model.WorkflowModeFailFast, node("a"), node("b"), node("c", "a"))
+	starter.failTasks["a"] = true
+
+	wf, err := o.Advance(context.Background(), "wf-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := wf.Node("a"); a.Status != model.WorkflowNodeFailed || a.Error == "" {
+		t.Errorf("节点 a = %+v", a)
+	}
+	// b 与 a 同批就绪：a 启动失败后 fail_fast 立即终止，b 不再启动
+	if wf.Node("b").Status != model.WorkflowNodeSkipped || wf.Node("c").Status != model.WorkflowNodeSkipped {
+		t.Errorf("nodes = %+v", wf.Nodes)
+	}
+	if wf.Status != model.WorkflowStatusFailed || len(store.runs) != 0 {
+		t.Errorf("wf = %+v, runs = %d", wf, len(store.runs))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -17,39 +18,71 @@ import (
 //  3. 环境变量覆盖 YAML 配置
 //  4. 构建最终 Config
 func Load() *Config {
+	cfg, _ := LoadChecked()
+	return cfg
+}
+
+// LoadChecked 加载配置，配置文件存在但无法解析时返回错误（配置热加载使用，解析失败时保留当前配置）
+//
+// 返回的 Config 与 Load 相同：解析失败时仍返回默认值与可解析部分合并后的配置。
+func LoadChecked() (*Config, error) {
 	env := parseEnv(getEnv("APP_ENV", "dev"))
 	loadEnvFiles(env)
 
 	yamlCfg := loadYAMLConfig(env)
 	dbPassword := applyEnvOverrides(yamlCfg)
+	applyLogEnv(&yamlCfg.Log)
 
 	// 构建数据库和 Redis URL（环境变量 DATABASE_URL/REDIS_URL 仍可覆盖）
 	databaseURL := os.Getenv("DATABASE_URL")
 	if databaseURL == "" {
 		databaseURL = buildDatabaseURL(yamlCfg.Database, dbPassword)
 	}
+	databaseReadURL := os.Getenv("DATABASE_READ_URL")
+	if databaseReadURL == "" {
+		databaseReadURL = buildReadReplicaURL(yamlCfg.Database, dbPassword)
+	}
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL == "" {
 		redisURL = buildRedisURL(yamlCfg.Redis)
 	}
 
+	queueDriver, queueURL := resolveQueue(yamlCfg.Queue, redisURL)
+
 	cfg := &Config{
-		Env:            env,
-		DatabaseDriver: detectDatabaseDriver(yamlCfg.Database.Driver, databaseURL),
-		DatabaseURL:    databaseURL,
-		DatabaseDBName: yamlCfg.Database.Name,
-		RedisURL:       redisURL,
-		APIPort:        yamlCfg.APIServer.Port,
-		Scheduler:      yamlCfg.Scheduler,
-		TLS:            yamlCfg.TLS,
-		Auth:           yamlCfg.Auth,
-		MinIO:          yamlCfg.MinIO,
-		APIServer:      yamlCfg.APIServer,
-		Node:           yamlCfg.Node,
-		ConfigFilePath: yamlCfg.loadedFrom,
+		Env:                   env,
+		DatabaseDriver:        detectDatabaseDriver(yamlCfg.Database.Driver, databaseURL),
+		DatabaseURL:           databaseURL,
+		DatabaseDBName:        yamlCfg.Database.Name,
+		DatabaseReadURL:       databaseReadURL,
+		DatabaseReplicaMaxLag: yamlCfg.Database.ReplicaMaxLag,
+		DatabasePool:          yamlCfg.Database.Pool,
+		DatabaseEvents:        yamlCfg.Database.Events,
+		RedisURL:              redisURL,
+		QueueDriver:           queueDriver,
+		QueueURL:              queueURL,
+		APIPort:               yamlCfg.APIServer.Port,
+		Scheduler:             yamlCfg.Scheduler,
+		TLS:                   yamlCfg.TLS,
+		Auth:                  yamlCfg.Auth,
+		MinIO:                 yamlCfg.MinIO,
+		APIServer:             yamlCfg.APIServer,
+		Node:                  yamlCfg.Node,
+		Moderation:            yamlCfg.Moderation,
+		Maintenance:           yamlCfg.Maintenance,
+		Lifecycle:             yamlCfg.Lifecycle,
+		Gateway:               yamlCfg.Gateway,
+		Registry:              yamlCfg.Registry,
+		EventSink:             yamlCfg.EventSink,
+		Encryption:            yamlCfg.Encryption,
+		Log:                   yamlCfg.Log,
+		ConfigFilePath:        yamlCfg.loadedFrom,
 	}
 	cfg.Scheduler.validate()
-	return cfg
+	if yamlCfg.parseErr != nil {
+		return cfg, fmt.Errorf("parse %s: %w", yamlCfg.loadedFrom, yamlCfg.parseErr)
+	}
+	return cfg, nil
 }
 
 // LoadNodeManager 加载 Node Manager 配置（供 cmd/nodemanager 使用）
@@ -59,6 +92,7 @@ func LoadNodeManager() *Config {
 	loadEnvFiles(env)
 
 	yamlCfg := loadYAMLConfig(env)
+	applyLogEnv(&yamlCfg.Log)
 
 	redisURL := os.Getenv("REDIS_URL")
 	if redisURL == "" {
@@ -71,6 +105,7 @@ func LoadNodeManager() *Config {
 		TLS:            yamlCfg.TLS,
 		APIServer:      yamlCfg.APIServer,
 		Node:           yamlCfg.Node,
+		Log:            yamlCfg.Log,
 		ConfigFilePath: yamlCfg.loadedFrom,
 	}
 }
@@ -87,6 +122,18 @@ func EnvFileName() string {
 	return fmt.Sprintf("%s.env", env)
 }
 
+// defaultRateLimitConfig 请求限流默认值（默认不启用）
+func defaultRateLimitConfig() RateLimitConfig {
+	return RateLimitConfig{
+		IP:               RateBucketConfig{Rate: 20, Burst: 100},
+		User:             RateBucketConfig{Rate: 10, Burst: 50},
+		Login:            RateBucketConfig{Rate: 0.2, Burst: 5},
+		MaxLoginFailures: 5,
+		FailureWindow:    15 * time.Minute,
+		Lockout:          15 * time.Minute,
+	}
+}
+
 // loadYAMLConfig 加载 YAML 配置文件
 //
 // 搜索候选名：{env}.yaml（如 dev.yaml、test.yaml、prod.yaml）
@@ -94,21 +141,39 @@ func EnvFileName() string {
 func loadYAMLConfig(env Environment) *yamlConfigInternal {
 	cfg := &yamlConfigInternal{
 		YAMLConfig: YAMLConfig{
-			APIServer: APIServerConfig{Port: "8080"},
+			APIServer: APIServerConfig{Port: "8080", RateLimit: defaultRateLimitConfig(), ConfigWatchInterval: 10 * time.Second},
 			Database:  DatabaseConfig{Driver: "mongodb", Host: "localhost", Port: 27017, Name: "agents_admin"},
 			Redis:     RedisConfig{Host: "localhost", Port: 6380, DB: 0},
+			Queue:     QueueConfig{Driver: "redis"},
 			MinIO:     MinIOConfig{Endpoint: "localhost:9000", AccessKey: "minioadmin", SecretKey: "minioadmin_dev_123", Bucket: "agents-admin"},
 			Node:      NodeConfig{WorkspaceDir: "/tmp/agents-workspaces"},
 			Scheduler: SchedulerConfig{
 				NodeID: "api-server",
 				Strategy: SchedulerStrategyConfig{
-					Default:    "label_match",
-					Chain:      []string{"direct", "affinity", "label_match"},
-					LabelMatch: SchedulerLabelMatchConfig{LoadBalance: true},
+					Default:     "label_match",
+					Chain:       []string{"direct", "affinity", "region", "task_affinity", "anti_affinity", "spread", "label_match"},
+					LabelMatch:  SchedulerLabelMatchConfig{LoadBalance: true},
+					LeastLoaded: SchedulerLeastLoadedConfig{Window: 5 * time.Minute},
 				},
-				Redis:    SchedulerRedisConfig{ReadTimeout: 5 * time.Second, ReadCount: 10},
-				Fallback: SchedulerFallbackConfig{Interval: 5 * time.Minute, StaleThreshold: 5 * time.Minute},
-				Requeue:  SchedulerRequeueConfig{OfflineThreshold: 30 * time.Second},
+				Redis:     SchedulerRedisConfig{ReadTimeout: 5 * time.Second, ReadCount: 10},
+				Fallback:  SchedulerFallbackConfig{Interval: 5 * time.Minute, StaleThreshold: 5 * time.Minute},
+				Requeue:   SchedulerRequeueConfig{OfflineThreshold: 30 * time.Second},
+				Sharding:  SchedulerShardingConfig{Workers: 1, Slots: 1024},
+				Watchdog:  SchedulerWatchdogConfig{Interval: 30 * time.Second, DefaultTimeout: 2 * time.Hour},
+				Reconcile: SchedulerReconcileConfig{Interval: 30 * time.Second, HeartbeatInterval: 10 * time.Second, MissedHeartbeats: 3},
+				Accounts:  SchedulerAccountsConfig{Cooldown: 15 * time.Minute, MaxFailovers: 3},
+			},
+			Maintenance: MaintenanceConfig{Window: "03:00-05:00", Interval: 24 * time.Hour, Tables: []string{"events", "runs"}},
+			Lifecycle:   LifecycleConfig{Interval: 10 * time.Minute, HotTTL: 7 * 24 * time.Hour, WarmTTL: 90 * 24 * time.Hour, MinHotAge: time.Hour, BatchSize: 50},
+			Gateway:     GatewayConfig{Timeout: 10 * time.Minute, PollInterval: 500 * time.Millisecond, MaxConcurrent: 4},
+			Registry:    RegistryConfig{Interval: time.Hour, Timeout: 2 * time.Minute},
+			EventSink: EventSinkConfig{
+				Kafka:          KafkaSinkConfig{Topic: "agents-admin.run-events", ClientID: "agents-admin"},
+				BufferSize:     10000,
+				BatchSize:      500,
+				FlushInterval:  time.Second,
+				DeadLetterPath: "data/event-sink-dead-letter.jsonl",
+				ReplayInterval: time.Minute,
 			},
 		},
 	}
@@ -118,7 +183,7 @@ func loadYAMLConfig(env Environment) *yamlConfigInternal {
 		for _, base := range effectiveConfigPaths() {
 			path := filepath.Join(base, name)
 			if data, err := os.ReadFile(path); err == nil {
-				yaml.Unmarshal(data, &cfg.YAMLConfig)
+				cfg.parseErr = yaml.Unmarshal(data, &cfg.YAMLConfig)
 				cfg.loadedFrom = path
 				return cfg
 			}
@@ -127,6 +192,16 @@ func loadYAMLConfig(env Environment) *yamlConfigInternal {
 	return cfg
 }
 
+// applyLogEnv 环境变量 LOG_LEVEL / LOG_FORMAT 覆盖日志配置（API Server 与 Node Manager 共用）
+func applyLogEnv(cfg *LogConfig) {
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		cfg.Level = v
+	}
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		cfg.Format = v
+	}
+}
+
 // applyEnvOverrides 从环境变量加载所有凭据（密码/密钥）
 //
 // 凭据只从环境变量读取，YAML 中不存储任何密码。
@@ -164,6 +239,20 @@ func applyEnvOverrides(yamlCfg *yamlConfigInternal) string {
 		}
 	}
 
+	// 消息队列后端：QUEUE_DRIVER / QUEUE_URL
+	if v := os.Getenv("QUEUE_DRIVER"); v != "" {
+		yamlCfg.Queue.Driver = v
+	}
+	if v := os.Getenv("QUEUE_URL"); v != "" {
+		yamlCfg.Queue.URL = v
+	}
+
+	// Kafka SASL 凭据：KAFKA_SASL_USERNAME / KAFKA_SASL_PASSWORD
+	if v := os.Getenv("KAFKA_SASL_USERNAME"); v != "" {
+		yamlCfg.EventSink.Kafka.SASL.Username = v
+	}
+	yamlCfg.EventSink.Kafka.SASL.Password = os.Getenv("KAFKA_SASL_PASSWORD")
+
 	// MinIO 凭据（兼容 Docker Compose 和直接配置两种变量名）
 	if v := os.Getenv("MINIO_ENDPOINT"); v != "" {
 		yamlCfg.MinIO.Endpoint = v
@@ -184,6 +273,20 @@ func applyEnvOverrides(yamlCfg *yamlConfigInternal) string {
 	yamlCfg.Auth.AdminEmail = os.Getenv("ADMIN_EMAIL")
 	yamlCfg.Auth.AdminPassword = os.Getenv("ADMIN_PASSWORD")
 	yamlCfg.Auth.NodeToken = os.Getenv("NODE_TOKEN")
+	yamlCfg.Auth.MasterKey = os.Getenv("MASTER_KEY")
+	yamlCfg.Auth.OIDC.ClientSecret = os.Getenv("OIDC_CLIENT_SECRET")
+
+	// 敏感字段加密 KEK：ENCRYPTION_KEK / ENCRYPTION_KEK_PREVIOUS（逗号分隔），Vault：VAULT_ADDR / VAULT_TOKEN
+	yamlCfg.Encryption.Key = os.Getenv("ENCRYPTION_KEK")
+	for _, k := range strings.Split(os.Getenv("ENCRYPTION_KEK_PREVIOUS"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			yamlCfg.Encryption.PreviousKeys = append(yamlCfg.Encryption.PreviousKeys, k)
+		}
+	}
+	if v := os.Getenv("VAULT_ADDR"); v != "" {
+		yamlCfg.Encryption.Vault.Address = v
+	}
+	yamlCfg.Encryption.Vault.Token = os.Getenv("VAULT_TOKEN")
 
 	return dbPassword
 }
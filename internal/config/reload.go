@@ -0,0 +1,152 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReloadableKeys 运行中可热加载的配置项（点分路径，前缀匹配其下全部子项）
+//
+// 其余配置项（端口、数据库、Redis、TLS、分片等）变更后需要重启才能生效。
+var ReloadableKeys = []string{
+	"scheduler.strategy",
+	"scheduler.preemption",
+	"scheduler.accounts",
+	"scheduler.watchdog.default_timeout",
+	"auth.access_token_ttl",
+	"auth.refresh_token_ttl",
+	"log.level",
+}
+
+// IsReloadable 配置项是否可热加载
+func IsReloadable(key string) bool {
+	for _, k := range ReloadableKeys {
+		if key == k || strings.HasPrefix(key, k+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithReloadable 返回 c 的副本，其中可热加载的配置项取自 next
+func (c *Config) WithReloadable(next *Config) *Config {
+	merged := *c
+	merged.Scheduler.Strategy = next.Scheduler.Strategy
+	merged.Scheduler.Preemption = next.Scheduler.Preemption
+	merged.Scheduler.Accounts = next.Scheduler.Accounts
+	merged.Scheduler.Watchdog.DefaultTimeout = next.Scheduler.Watchdog.DefaultTimeout
+	merged.Auth.AccessTokenTTL = next.Auth.AccessTokenTTL
+	merged.Auth.RefreshTokenTTL = next.Auth.RefreshTokenTTL
+	merged.Log.Level = next.Log.Level
+	return &merged
+}
+
+// Effective 返回生效配置（与 YAML 配置文件结构一致，连接串隐藏密码，凭据不输出）
+func (c *Config) Effective() map[string]interface{} {
+	view := YAMLConfig{
+		APIServer:   c.APIServer,
+		Node:        c.Node,
+		MinIO:       c.MinIO,
+		Scheduler:   c.Scheduler,
+		TLS:         c.TLS,
+		Auth:        c.Auth,
+		Moderation:  c.Moderation,
+		Maintenance: c.Maintenance,
+		Lifecycle:   c.Lifecycle,
+		Gateway:     c.Gateway,
+		Registry:    c.Registry,
+		EventSink:   c.EventSink,
+		Encryption:  c.Encryption,
+		Log:         c.Log,
+	}
+	out := make(map[string]interface{})
+	if data, err := yaml.Marshal(view); err == nil {
+		yaml.Unmarshal(data, &out)
+	}
+	delete(out, "redis") // 以下按实际连接串输出
+	delete(out, "queue")
+	out["database"] = map[string]interface{}{
+		"driver":   c.DatabaseDriver,
+		"url":      maskPassword(c.DatabaseURL),
+		"read_url": maskPassword(c.DatabaseReadURL),
+		"name":     c.DatabaseDBName,
+	}
+	out["redis"] = map[string]interface{}{"url": maskPassword(c.RedisURL)}
+	out["queue"] = map[string]interface{}{"driver": c.QueueDriver, "url": maskPassword(c.QueueURL)}
+	redactSecrets(out)
+	return out
+}
+
+// redactSecrets 隐藏名称像凭据的配置项（凭据应只来自环境变量，防止误写入配置文件后被输出）
+func redactSecrets(m map[string]interface{}) {
+	for k, v := range m {
+		switch vv := v.(type) {
+		case map[string]interface{}:
+			redactSecrets(vv)
+		case []interface{}:
+			for _, item := range vv {
+				if im, ok := item.(map[string]interface{}); ok {
+					redactSecrets(im)
+				}
+			}
+		default:
+			if isSecretKey(k) && v != nil && v != "" {
+				m[k] = "***"
+			}
+		}
+	}
+}
+
+func isSecretKey(key string) bool {
+	k := strings.ToLower(key)
+	if strings.HasSuffix(k, "_file") || strings.HasSuffix(k, "_dir") || strings.HasSuffix(k, "_ttl") {
+		return false
+	}
+	for _, s := range []string{"password", "secret", "token", "api_key", "access_key", "private_key"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// Diff 返回两份配置中值不同的配置项（按 Effective 视图逐项比较，键为点分路径，已排序）
+func Diff(old, next *Config) []string {
+	a, b := flatten("", old.Effective()), flatten("", next.Effective())
+	var keys []string
+	for k, v := range a {
+		if w, ok := b[k]; !ok || !reflect.DeepEqual(v, w) {
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// flatten 将嵌套配置展开为 点分路径 → 叶子值（列表整体作为一个值）
+func flatten(prefix string, m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{})
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]interface{}); ok && len(sub) > 0 {
+			for sk, sv := range flatten(key, sub) {
+				out[sk] = sv
+			}
+			continue
+		}
+		out[key] = fmt.Sprint(v)
+	}
+	return out
+}
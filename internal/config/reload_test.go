@@ -0,0 +1,95 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEffective_Redaction(t *testing.T) {
+	cfg := &Config{
+		DatabaseDriver: "postgres",
+		DatabaseURL:    "postgres://agents:s3cret@db:5432/agents_admin",
+		RedisURL:       "redis://:r3dis@redis:6379/0",
+		Auth:           AuthConfig{JWTSecret: "jwt-s3cret", AccessTokenTTL: "15m"},
+	}
+	out := cfg.Effective()
+
+	dump := flatten("", out)
+	for key, v := range dump {
+		s := v.(string)
+		for _, secret := range []string{"s3cret", "r3dis", "jwt-s3cret"} {
+			if strings.Contains(s, secret) {
+				t.Errorf("%s leaks secret: %s", key, s)
+			}
+		}
+	}
+	if dump["database.driver"] != "postgres" || dump["auth.access_token_ttl"] != "15m" {
+		t.Errorf("unexpected effective config: database.driver=%v auth.access_token_ttl=%v", dump["database.driver"], dump["auth.access_token_ttl"])
+	}
+
+	m := map[string]interface{}{
+		"webhook": map[string]interface{}{"api_key": "k", "token_file": "/run/token", "url": "http://x"},
+		"sources": []interface{}{map[string]interface{}{"password": "p"}},
+	}
+	redactSecrets(m)
+	webhook := m["webhook"].(map[string]interface{})
+	if webhook["api_key"] != "***" || webhook["token_file"] != "/run/token" || webhook["url"] != "http://x" {
+		t.Errorf("redactSecrets map = %v", webhook)
+	}
+	if m["sources"].([]interface{})[0].(map[string]interface{})["password"] != "***" {
+		t.Errorf("redactSecrets list = %v", m["sources"])
+	}
+}
+
+func TestDiff_Reloadable(t *testing.T) {
+	old := &Config{Scheduler: SchedulerConfig{Watchdog: SchedulerWatchdogConfig{DefaultTimeout: time.Hour}}, APIPort: "8080"}
+	old.APIServer.Port = "8080"
+	next := *old
+	next.Scheduler.Watchdog.DefaultTimeout = 2 * time.Hour
+	next.Scheduler.Strategy.Chain = []string{"label_match"}
+	next.APIServer.Port = "9090"
+
+	got := strings.Join(Diff(old, &next), ",")
+	want := "api_server.port,scheduler.strategy.chain,scheduler.watchdog.default_timeout"
+	if got != want {
+		t.Fatalf("Diff = %s, want %s", got, want)
+	}
+
+	for key, reloadable := range map[string]bool{
+		"scheduler.strategy.chain":           true,
+		"scheduler.watchdog.default_timeout": true,
+		"scheduler.watchdog.interval":        false,
+		"scheduler.strategy_x":               false,
+		"api_server.port":                    false,
+	} {
+		if IsReloadable(key) != reloadable {
+			t.Errorf("IsReloadable(%s) = %v, want %v", key, !reloadable, reloadable)
+		}
+	}
+
+	merged := old.WithReloadable(&next)
+	if merged.Scheduler.Watchdog.DefaultTimeout != 2*time.Hour || merged.APIServer.Port != "8080" {
+		t.Errorf("WithReloadable: default_timeout=%s port=%s", merged.Scheduler.Watchdog.DefaultTimeout, merged.APIServer.Port)
+	}
+}
+
+func TestLoadChecked_ParseError(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("APP_ENV", "test")
+	t.Setenv("CONFIG_DIR", dir)
+	path := filepath.Join(dir, "test.yaml")
+
+	os.WriteFile(path, []byte("scheduler:\n  preemption:\n    enabled: true\n"), 0644)
+	cfg, err := LoadChecked()
+	if err != nil || !cfg.Scheduler.Preemption.Enabled || cfg.ConfigFilePath != path {
+		t.Fatalf("LoadChecked valid: err=%v preemption=%v path=%s", err, cfg.Scheduler.Preemption.Enabled, cfg.ConfigFilePath)
+	}
+
+	os.WriteFile(path, []byte("scheduler: [unclosed\n"), 0644)
+	if _, err := LoadChecked(); err == nil || !strings.Contains(err.Error(), path) {
+		t.Errorf("LoadChecked invalid: err=%v", err)
+	}
+}
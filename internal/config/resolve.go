@@ -109,7 +109,23 @@ func WriteConfigFile(content []byte) (string, error) {
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return path, err
 	}
-	return path, os.WriteFile(path, content, 0640)
+	// 先写临时文件再重命名，监听配置变更的 API Server 不会读到写了一半的文件
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return path, err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return path, err
+	}
+	if err := tmp.Close(); err != nil {
+		return path, err
+	}
+	if err := os.Chmod(tmp.Name(), 0640); err != nil {
+		return path, err
+	}
+	return path, os.Rename(tmp.Name(), path)
 }
 
 // findConfigFile 在搜索路径中查找第一个存在的配置文件
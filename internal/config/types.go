@@ -41,14 +41,98 @@ const (
 // YAMLConfig 统一 YAML 配置文件结构
 // API Server 和 Node Manager 共用此格式，通过章节区分
 type YAMLConfig struct {
-	APIServer APIServerConfig `yaml:"api_server"` // API Server（端口 + URL）
-	Database  DatabaseConfig  `yaml:"database"`   // 数据库（API Server）
-	Redis     RedisConfig     `yaml:"redis"`      // Redis（共享）
-	MinIO     MinIOConfig     `yaml:"minio"`      // MinIO 对象存储
-	Node      NodeConfig      `yaml:"node"`       // 节点共性配置（Node Manager）
-	Scheduler SchedulerConfig `yaml:"scheduler"`  // 调度器（API Server）
-	TLS       TLSConfig       `yaml:"tls"`        // TLS（共享）
-	Auth      AuthConfig      `yaml:"auth"`       // 认证（API Server）
+	APIServer   APIServerConfig   `yaml:"api_server"`  // API Server（端口 + URL）
+	Database    DatabaseConfig    `yaml:"database"`    // 数据库（API Server）
+	Redis       RedisConfig       `yaml:"redis"`       // Redis（共享）
+	Queue       QueueConfig       `yaml:"queue"`       // 消息队列后端（API Server）
+	MinIO       MinIOConfig       `yaml:"minio"`       // MinIO 对象存储
+	Node        NodeConfig        `yaml:"node"`        // 节点共性配置（Node Manager）
+	Scheduler   SchedulerConfig   `yaml:"scheduler"`   // 调度器（API Server）
+	TLS         TLSConfig         `yaml:"tls"`         // TLS（共享）
+	Auth        AuthConfig        `yaml:"auth"`        // 认证（API Server）
+	Moderation  ModerationConfig  `yaml:"moderation"`  // Agent 输出内容审核（API Server）
+	Maintenance MaintenanceConfig `yaml:"maintenance"` // 存储维护任务（API Server）
+	Lifecycle   LifecycleConfig   `yaml:"lifecycle"`   // Run 数据分层（API Server）
+	Gateway     GatewayConfig     `yaml:"gateway"`     // Agent 网关（API Server）
+	Registry    RegistryConfig    `yaml:"registry"`    // 模板注册表同步（API Server）
+	EventSink   EventSinkConfig   `yaml:"event_sink"`  // Run 事件镜像到 Kafka（API Server）
+	Encryption  EncryptionConfig  `yaml:"encryption"`  // 敏感字段静态加密（API Server）
+	Log         LogConfig         `yaml:"log"`         // 日志（共享）
+}
+
+// LogConfig 日志配置（环境变量 LOG_LEVEL / LOG_FORMAT 与 --log-level 命令行参数优先）
+type LogConfig struct {
+	Level  string `yaml:"level"`  // debug / info（默认）/ warn / error
+	Format string `yaml:"format"` // text / json，为空时生产环境使用 json，其余环境使用 text
+	Output string `yaml:"output"` // stdout（默认）、stderr 或文件路径
+}
+
+// LogFormat 返回生效的日志格式（未配置时生产环境使用 json）
+func (c *Config) LogFormat() string {
+	if c.Log.Format != "" {
+		return c.Log.Format
+	}
+	if c.Env == EnvProduction {
+		return "json"
+	}
+	return "text"
+}
+
+// QueueConfig 消息队列后端配置（调度队列与节点队列）
+type QueueConfig struct {
+	Driver string `yaml:"driver"` // redis（默认，复用 redis 章节的连接）或 nats
+	URL    string `yaml:"url"`    // 后端连接串，如 nats://localhost:4222（redis 驱动为空时使用 redis 章节）
+}
+
+// EventSinkConfig Run 事件镜像配置
+//
+// 写入数据库的每条 Run 事件同时投递到 Kafka，至少一次语义，Kafka 不可用时写入死信文件并定时重放。
+type EventSinkConfig struct {
+	Enabled        bool            `yaml:"enabled"`          // 是否启用
+	Kafka          KafkaSinkConfig `yaml:"kafka"`            // Kafka 连接
+	BufferSize     int             `yaml:"buffer_size"`      // 内存缓冲的最大事件数，超出时直接写入死信文件
+	BatchSize      int             `yaml:"batch_size"`       // 单批投递的最大事件数
+	FlushInterval  time.Duration   `yaml:"flush_interval"`   // 未攒满一批时的最长等待
+	DeadLetterPath string          `yaml:"dead_letter_path"` // 死信文件路径（JSON Lines）
+	ReplayInterval time.Duration   `yaml:"replay_interval"`  // 重放死信文件的间隔
+}
+
+// KafkaSinkConfig Kafka 连接配置
+// 注意：SASL 密码只从 KAFKA_SASL_PASSWORD 环境变量读取，不存储在 YAML 中
+type KafkaSinkConfig struct {
+	Brokers  []string        `yaml:"brokers"`   // Broker 地址列表（host:port）
+	Topic    string          `yaml:"topic"`     // 目标 Topic
+	ClientID string          `yaml:"client_id"` // 客户端标识
+	TLS      bool            `yaml:"tls"`       // 是否使用 TLS 连接
+	SASL     KafkaSASLConfig `yaml:"sasl"`      // SASL 认证
+}
+
+// KafkaSASLConfig Kafka SASL 认证配置
+type KafkaSASLConfig struct {
+	Mechanism string `yaml:"mechanism"` // plain / scram-sha-256 / scram-sha-512，为空时不认证
+	Username  string `yaml:"username"`  // 用户名（KAFKA_SASL_USERNAME 环境变量可覆盖）
+	Password  string `yaml:"-"`         // 只从 KAFKA_SASL_PASSWORD 环境变量读取
+}
+
+// EncryptionConfig 敏感字段静态加密配置（代理密码、API Key 认证配置、Git 凭据与运行时密钥）
+//
+// 信封加密：每个字段使用独立的数据密钥加密，数据密钥由 KEK 包装。
+// 注意：本地 KEK 只从 ENCRYPTION_KEK / ENCRYPTION_KEK_PREVIOUS 环境变量读取，Vault 令牌只从 VAULT_TOKEN 读取
+type EncryptionConfig struct {
+	Provider string             `yaml:"provider"` // KEK 来源：为空时以 MASTER_KEY 作为本地 KEK（未设置时不加密）；local（ENCRYPTION_KEK）或 vault（Vault Transit）
+	Vault    VaultEncryptionKEK `yaml:"vault"`    // Vault Transit（provider 为 vault 时使用）
+
+	Key          string   `yaml:"-"` // 只从 ENCRYPTION_KEK 环境变量读取（local 的当前 KEK）
+	PreviousKeys []string `yaml:"-"` // 只从 ENCRYPTION_KEK_PREVIOUS 环境变量读取（逗号分隔，轮换前的本地 KEK，只用于解密）
+}
+
+// VaultEncryptionKEK Vault Transit KEK 配置
+type VaultEncryptionKEK struct {
+	Address string        `yaml:"address"`  // Vault 地址（VAULT_ADDR 环境变量可覆盖）
+	Mount   string        `yaml:"mount"`    // Transit 引擎挂载路径，默认 transit
+	KeyName string        `yaml:"key_name"` // Transit 密钥名称
+	Timeout time.Duration `yaml:"timeout"`  // 单次请求超时，默认 10s
+	Token   string        `yaml:"-"`        // 只从 VAULT_TOKEN 环境变量读取
 }
 
 // AuthConfig 认证配置
@@ -60,12 +144,163 @@ type AuthConfig struct {
 	AdminEmail      string `yaml:"-"`                 // 只从 ADMIN_EMAIL 环境变量读取
 	AdminPassword   string `yaml:"-"`                 // 只从 ADMIN_PASSWORD 环境变量读取
 	NodeToken       string `yaml:"-"`                 // 只从 NODE_TOKEN 环境变量读取（NodeManager 共享密钥）
+	MasterKey       string `yaml:"-"`                 // 只从 MASTER_KEY 环境变量读取（两步验证密钥加密主密钥；未配置 encryption.provider 时作为敏感字段加密的本地 KEK）
+
+	DisableSharedNodeToken bool   `yaml:"disable_shared_node_token"` // 禁用 NODE_TOKEN 共享密钥，节点只能通过加入令牌注册
+	NodeCertValidity       string `yaml:"node_cert_validity"`        // 节点客户端证书有效期，例如 "8760h"
+
+	OIDC OIDCConfig `yaml:"oidc"` // OIDC 单点登录
+}
+
+// OIDCConfig OIDC 单点登录配置（Okta、Azure AD、Google 等）
+// 注意：客户端密钥只从 OIDC_CLIENT_SECRET 环境变量读取，不存储在 YAML 中
+type OIDCConfig struct {
+	Issuer        string   `yaml:"issuer"`         // IdP 的 issuer URL，为空时不启用
+	ClientID      string   `yaml:"client_id"`      // 客户端 ID
+	ClientSecret  string   `yaml:"-"`              // 只从 OIDC_CLIENT_SECRET 环境变量读取
+	RedirectURL   string   `yaml:"redirect_url"`   // 回调地址，如 https://admin.example.com/api/v1/auth/oidc/callback
+	Scopes        []string `yaml:"scopes"`         // 为空时使用 openid email profile
+	GroupsClaim   string   `yaml:"groups_claim"`   // ID Token 中组列表的声明名称，默认 groups
+	AdminGroups   []string `yaml:"admin_groups"`   // 映射为 admin 角色的组，配置后每次登录按组同步角色
+	AllowedGroups []string `yaml:"allowed_groups"` // 允许登录的组，为空时不限制
+}
+
+// ModerationConfig Agent 输出内容审核配置
+type ModerationConfig struct {
+	Enabled       bool                   `yaml:"enabled"`
+	Detectors     []string               `yaml:"detectors"`      // 启用的内置检测器，为空时全部启用
+	Custom        []ModerationRuleConfig `yaml:"custom"`         // 自定义正则规则
+	AbortSeverity string                 `yaml:"abort_severity"` // 命中该级别及以上时终止 Run（low/medium/high/critical），为空不终止
+
+	EntropyMinLength int     `yaml:"entropy_min_length"` // secret 检测器的最小长度，为 0 时使用默认值 24
+	EntropyThreshold float64 `yaml:"entropy_threshold"`  // secret 检测器的熵阈值（比特/字符），为 0 时使用默认值 4.0
+	RetainOriginals  bool    `yaml:"retain_originals"`   // 另存被掩码事件的原文，管理员可查看
+}
+
+// MaintenanceConfig 存储维护任务配置
+//
+// 按驱动执行：PostgreSQL VACUUM (ANALYZE)、SQLite ANALYZE + incremental_vacuum、MongoDB compact。
+type MaintenanceConfig struct {
+	Enabled  bool          `yaml:"enabled"`  // 是否启用定时维护（手动触发不受影响）
+	Window   string        `yaml:"window"`   // 允许执行的本地时间窗口，例如 "03:00-05:00"（可跨零点），为空不限制
+	Interval time.Duration `yaml:"interval"` // 两次定时维护的最小间隔
+	Tables   []string      `yaml:"tables"`   // 维护的表（MongoDB 为集合）
+	DryRun   bool          `yaml:"dry_run"`  // 定时维护只估算可回收空间，不实际执行
+}
+
+// LifecycleConfig Run 数据分层配置
+//
+// hot：事件在数据库；warm：事件归档到 MinIO；cold：只保留摘要与归档位置。需要配置 MinIO。
+type LifecycleConfig struct {
+	Enabled      bool          `yaml:"enabled"`        // 是否启用自动迁移
+	Interval     time.Duration `yaml:"interval"`       // 巡检间隔
+	HotTTL       time.Duration `yaml:"hot_ttl"`        // Run 结束后保留在 hot 的时长
+	WarmTTL      time.Duration `yaml:"warm_ttl"`       // 进入 warm 后转入 cold 的时长，0 表示不转入 cold
+	MaxHotEvents int64         `yaml:"max_hot_events"` // 数据库事件总数上限，超出时提前归档，0 表示不限制
+	MinHotAge    time.Duration `yaml:"min_hot_age"`    // 按容量提前归档时 Run 至少已结束的时长
+	BatchSize    int           `yaml:"batch_size"`     // 每批处理的 Run 数
+}
+
+// GatewayConfig Agent 网关配置（OpenAI Chat Completions 兼容接口）
+type GatewayConfig struct {
+	Enabled       bool                 `yaml:"enabled"`        // 是否启用
+	Listen        string               `yaml:"listen"`         // 独立监听地址（如 ":8090"），为空时只挂载在 API Server 端口
+	Timeout       time.Duration        `yaml:"timeout"`        // 单次请求等待 Run 结束的最长时间
+	PollInterval  time.Duration        `yaml:"poll_interval"`  // 读取 Run 事件的间隔
+	MaxConcurrent int                  `yaml:"max_concurrent"` // 每个用户同时进行的请求数上限，0 表示不限制
+	Models        []GatewayModelConfig `yaml:"models"`         // 对外暴露的模型
+}
+
+// GatewayModelConfig 网关模型：请求中的 model 名称到任务执行配置的映射
+type GatewayModelConfig struct {
+	Name           string            `yaml:"name"`            // 请求中的 model
+	Description    string            `yaml:"description"`     // 说明（GET /v1/models 返回）
+	AgentType      string            `yaml:"agent_type"`      // Agent 类型（如 qwen-code），配置 project_id 时可使用项目默认值
+	AgentID        string            `yaml:"agent_id"`        // 固定使用的 Agent 实例（为空时由项目账号池分配账号）
+	ProjectID      string            `yaml:"project_id"`      // 所属项目（默认值、约束与账号池）
+	Labels         map[string]string `yaml:"labels"`          // 调度标签
+	TimeoutSeconds int               `yaml:"timeout_seconds"` // 单次执行时限（秒，0 使用调度器默认值）
+}
+
+// RegistryConfig 模板注册表同步配置
+//
+// 定时从远程注册表（HTTP 索引或 git 仓库）同步任务模板、Agent 模板与技能。
+type RegistryConfig struct {
+	Enabled  bool                   `yaml:"enabled"`  // 是否启用定时同步（手动同步不受影响）
+	Interval time.Duration          `yaml:"interval"` // 同步间隔
+	Timeout  time.Duration          `yaml:"timeout"`  // 单个注册表的拉取超时
+	Sources  []RegistrySourceConfig `yaml:"sources"`  // 注册表列表（同一条目由先列出的注册表提供）
+}
+
+// RegistrySourceConfig 远程注册表
+type RegistrySourceConfig struct {
+	Name  string `yaml:"name"`  // 名称（记录在同步条目的 source 中）
+	Type  string `yaml:"type"`  // http（默认）或 git
+	URL   string `yaml:"url"`   // http：索引文件 URL；git：仓库地址
+	Ref   string `yaml:"ref"`   // git 分支或标签（为空时使用默认分支）
+	Index string `yaml:"index"` // git 仓库内索引文件路径，默认 index.yaml
+}
+
+// ModerationRuleConfig 自定义内容审核规则
+type ModerationRuleConfig struct {
+	Name     string `yaml:"name"`
+	Pattern  string `yaml:"pattern"`  // Go 正则表达式
+	Severity string `yaml:"severity"` // low/medium/high/critical，默认 medium
 }
 
 // APIServerConfig API Server 配置
 type APIServerConfig struct {
-	Port string `yaml:"port"` // 监听端口
-	URL  string `yaml:"url"`  // API Server 完整 URL（Node Manager 连接用）
+	Port            string `yaml:"port"`              // 监听端口
+	URL             string `yaml:"url"`               // API Server 完整 URL（Node Manager 连接用）
+	MaxEventBatch   int    `yaml:"max_event_batch"`   // 事件上报单次请求的事件数上限（默认 1000）
+	GRPCListen      string `yaml:"grpc_listen"`       // 节点 gRPC 接口监听地址（如 :9090，为空时不启用）
+	EventSchemaMode string `yaml:"event_schema_mode"` // 事件结构校验模式：lenient（默认）/ strict
+
+	// IdempotencyTTL 创建任务与 Run 时 Idempotency-Key 的有效期（默认 24h）
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl"`
+
+	// SubTasks Agent 执行中派生子任务（spawn_subtask）的限制
+	SubTasks SubTaskConfig `yaml:"subtasks"`
+
+	// RateLimit 请求限流与登录暴力破解防护
+	RateLimit RateLimitConfig `yaml:"rate_limit"`
+
+	// BodyLimits 请求体大小限制
+	BodyLimits BodyLimitsConfig `yaml:"body_limits"`
+
+	// ConfigWatchInterval 检查配置文件变更并热加载的间隔（默认 10s，0 表示只响应 SIGHUP）
+	ConfigWatchInterval time.Duration `yaml:"config_watch_interval"`
+}
+
+// BodyLimitsConfig 请求体大小上限（MB，0 使用默认值，-1 表示不限制）
+type BodyLimitsConfig struct {
+	DefaultMB int64            `yaml:"default_mb"` // 未匹配路由组的请求（默认 2）
+	GroupsMB  map[string]int64 `yaml:"groups_mb"`  // 按路由组覆盖：events（默认 32）、uploads（默认 16）、archives（默认 1024）
+}
+
+// RateLimitConfig 请求限流配置（令牌桶保存在 Redis，多个 API Server 副本共享）
+type RateLimitConfig struct {
+	Enabled bool             `yaml:"enabled"` // 是否启用
+	IP      RateBucketConfig `yaml:"ip"`      // 每个客户端 IP
+	User    RateBucketConfig `yaml:"user"`    // 每个已认证用户
+	Login   RateBucketConfig `yaml:"login"`   // 登录接口，每个客户端 IP
+
+	MaxLoginFailures  int           `yaml:"max_login_failures"`  // 同一邮箱连续登录失败的上限，0 表示不锁定
+	FailureWindow     time.Duration `yaml:"failure_window"`      // 失败计数窗口
+	Lockout           time.Duration `yaml:"lockout"`             // 锁定时长
+	TrustForwardedFor bool          `yaml:"trust_forwarded_for"` // 以 X-Forwarded-For 识别客户端 IP（仅在可信反向代理之后开启）
+}
+
+// RateBucketConfig 令牌桶：每秒 rate 个请求，允许 burst 个突发请求（rate 为 0 表示不限制）
+type RateBucketConfig struct {
+	Rate  float64 `yaml:"rate"`
+	Burst int     `yaml:"burst"`
+}
+
+// SubTaskConfig Agent 派生子任务的限制，零值使用默认值
+type SubTaskConfig struct {
+	MaxDepth    int `yaml:"max_depth"`    // 最大派生深度（顶层任务为 0，默认 3）
+	MaxChildren int `yaml:"max_children"` // 单个 Run 最多派生的子任务数（默认 10）
 }
 
 // TLSConfig TLS/HTTPS 配置
@@ -74,6 +309,7 @@ type TLSConfig struct {
 	CertFile     string     `yaml:"cert_file"`     // 服务端证书
 	KeyFile      string     `yaml:"key_file"`      // 服务端私钥
 	CAFile       string     `yaml:"ca_file"`       // CA 证书（用于验证客户端/服务端）
+	CAKeyFile    string     `yaml:"ca_key_file"`   // CA 私钥（签发节点客户端证书，auto_generate 时默认 cert_dir/ca-key.pem）
 	CertDir      string     `yaml:"cert_dir"`      // 证书目录（auto_generate 时使用，默认 /etc/agents-admin/certs）
 	AutoGenerate bool       `yaml:"auto_generate"` // 启用时若证书不存在则自动生成自签名证书
 	Hosts        string     `yaml:"hosts"`         // 证书 SANs（逗号分隔的 IP/域名，自动包含 localhost）
@@ -89,7 +325,7 @@ type ACMEConfig struct {
 }
 
 type DatabaseConfig struct {
-	Driver   string `yaml:"driver"` // "postgres", "sqlite", or "mongodb"（默认 mongodb）
+	Driver   string `yaml:"driver"` // "postgres", "sqlite", "mysql", or "mongodb"（默认 mongodb）
 	Path     string `yaml:"path"`   // SQLite 文件路径
 	Host     string `yaml:"host"`
 	Port     int    `yaml:"port"`
@@ -98,6 +334,33 @@ type DatabaseConfig struct {
 	Name     string `yaml:"name"`
 	SSLMode  string `yaml:"sslmode"`
 	URI      string `yaml:"uri"` // MongoDB 连接 URI（优先于 host/port，如 mongodb://localhost:27017）
+
+	// 只读副本（PostgreSQL / MySQL，可选）：与主库共用用户、密码与库名
+	ReadHost      string        `yaml:"read_host"`       // 副本地址，为空时不启用
+	ReadPort      int           `yaml:"read_port"`       // 副本端口（默认与主库相同）
+	ReplicaMaxLag time.Duration `yaml:"replica_max_lag"` // 允许的最大复制延迟，超出时读请求回退主库（默认 10s）
+
+	Pool DatabasePoolConfig `yaml:"pool"` // 连接池（PostgreSQL / MySQL，主库与副本各一个池）
+
+	Events DatabaseEventsConfig `yaml:"events"` // Run 事件存储（压缩与原始输出转存）
+}
+
+// DatabaseEventsConfig Run 事件存储配置
+//
+// 压缩只作用于 PostgreSQL / MySQL / SQLite（MongoDB 依赖 WiredTiger 块压缩）。压缩或转存的内容不参与事件检索。
+type DatabaseEventsConfig struct {
+	CompressThreshold int  `yaml:"compress_threshold"` // payload 与原始输出合计超过该字节数时 zstd 压缩存储，0 表示不压缩
+	OffloadRaw        bool `yaml:"offload_raw"`        // 原始输出转存到 MinIO，数据库只保留指针（需要配置 MinIO）
+	OffloadThreshold  int  `yaml:"offload_threshold"`  // 原始输出不小于该字节数时转存（默认 1024）
+}
+
+// DatabasePoolConfig 数据库连接池配置，零值使用默认值
+type DatabasePoolConfig struct {
+	MaxOpenConns           int           `yaml:"max_open_conns"`           // 最大打开连接数（默认 25）
+	MaxIdleConns           int           `yaml:"max_idle_conns"`           // 最大空闲连接数（默认 5）
+	ConnMaxLifetime        time.Duration `yaml:"conn_max_lifetime"`        // 连接最长存活时间（默认 5m）
+	ConnMaxIdleTime        time.Duration `yaml:"conn_max_idle_time"`       // 连接最长空闲时间（默认不限制）
+	StatementCacheCapacity int           `yaml:"statement_cache_capacity"` // 预编译语句缓存容量，仅 PostgreSQL（默认 512，-1 禁用）
 }
 
 type RedisConfig struct {
@@ -119,9 +382,87 @@ type MinIOConfig struct {
 
 // NodeConfig 节点共性配置（Node Manager 使用）
 type NodeConfig struct {
-	ID           string            `yaml:"id"`
-	WorkspaceDir string            `yaml:"workspace_dir"`
-	Labels       map[string]string `yaml:"labels"`
+	ID            string               `yaml:"id"`
+	WorkspaceDir  string               `yaml:"workspace_dir"`
+	Labels        map[string]string    `yaml:"labels"`
+	CredentialDir string               `yaml:"credential_dir"` // 节点专属凭证目录（加入令牌换取的 Token 与客户端证书）
+	Exec          NodeExecConfig       `yaml:"exec"`           // Run 执行后端
+	Container     NodeContainerConfig  `yaml:"container"`      // 容器运行时
+	Events        NodeEventsConfig     `yaml:"events"`         // 事件批量上报
+	Handover      NodeHandoverConfig   `yaml:"handover"`       // 重启交接（运行中的 Run 在 Node Manager 重启后继续执行）
+	Pools         []NodePoolConfig     `yaml:"pools"`          // 预热实例池（docker 执行后端）
+	ProxyProbe    NodeProxyProbeConfig `yaml:"proxy_probe"`    // 代理健康探测
+	Transport     string               `yaml:"transport"`      // 与 API Server 的通信方式：rest（默认）/ grpc
+	GRPCAddr      string               `yaml:"grpc_addr"`      // gRPC 模式下 API Server 节点 gRPC 接口地址（如 api.example.com:9090）
+
+	// Concurrency 执行并发（控制面可通过 PATCH /api/v1/nodes/{id}/capacity 覆盖）
+	Concurrency NodeConcurrencyConfig `yaml:"concurrency"`
+
+	// CancelGraceSeconds 取消或超时终止 Run 时等待 Agent 自行退出的秒数（默认 10）
+	CancelGraceSeconds int `yaml:"cancel_grace_seconds"`
+}
+
+// NodeEventsConfig 事件批量上报配置（0 值使用默认值）
+type NodeEventsConfig struct {
+	BatchSize       int    `yaml:"batch_size"`        // 单次上报的最大事件数（默认 50）
+	FlushIntervalMS int    `yaml:"flush_interval_ms"` // 批次最长等待时间（毫秒，默认 200）
+	QueueSize       int    `yaml:"queue_size"`        // 每个 Run 的待上报队列上限（默认 1000）
+	MaxRetries      int    `yaml:"max_retries"`       // 失败重试次数，之后写入本地缓存（默认 5）
+	RetryIntervalMS int    `yaml:"retry_interval_ms"` // 首次重试间隔，指数增长（毫秒，默认 500）
+	SpoolDir        string `yaml:"spool_dir"`         // 本地缓存目录（默认 <workspace_dir>/.event-spool）
+	SpoolMaxMB      int    `yaml:"spool_max_mb"`      // 本地缓存总大小上限，超出时丢弃最早的事件（MB，默认 256，-1 表示不限制）
+}
+
+// NodeHandoverConfig 重启交接配置
+type NodeHandoverConfig struct {
+	Enabled  bool   `yaml:"enabled"`   // Agent 脱离 Node Manager 进程运行，重启后接管（默认关闭）
+	StateDir string `yaml:"state_dir"` // 交接状态目录（默认 <workspace_dir>/.run-state）
+}
+
+// NodeConcurrencyConfig 执行并发配置（0 值使用默认值）
+type NodeConcurrencyConfig struct {
+	MaxConcurrent        int            `yaml:"max_concurrent"`         // 最大并发 Run 数（默认 2）
+	ReservedHighPriority int            `yaml:"reserved_high_priority"` // 只允许 high 优先级 Run 使用的槽位数（包含在 max_concurrent 内）
+	AgentTypeLimits      map[string]int `yaml:"agent_type_limits"`      // Agent 类型 → 并发上限（未配置的类型不限制）
+}
+
+// NodeProxyProbeConfig 代理健康探测配置（0 值使用默认值）
+type NodeProxyProbeConfig struct {
+	IntervalSeconds int    `yaml:"interval_seconds"` // 探测间隔（秒，默认 60，负数关闭探测与故障切换）
+	TimeoutSeconds  int    `yaml:"timeout_seconds"`  // 单个代理的探测超时（秒，默认 10）
+	Target          string `yaml:"target"`           // CONNECT 探测目标 host:port（默认 www.google.com:443）
+}
+
+// NodePoolConfig 单个账号的预热实例池配置
+type NodePoolConfig struct {
+	AccountID string `yaml:"account_id"` // 账号 ID（Agent 类型、镜像与认证 Volume 取自账号）
+	Size      int    `yaml:"size"`       // 保持空闲的预热容器数
+}
+
+// NodeContainerConfig 容器运行时配置（实例容器、docker 执行后端、Volume 工作空间共用）
+type NodeContainerConfig struct {
+	Runtime   string `yaml:"runtime"`   // docker（默认）/ podman / containerd（通过 nerdctl）
+	Binary    string `yaml:"binary"`    // 命令行路径（为空时 docker、podman、nerdctl）
+	Namespace string `yaml:"namespace"` // containerd 命名空间（nerdctl --namespace）
+	Socket    string `yaml:"socket"`    // Docker 兼容 API socket（认证流程与 Web 终端使用，为空时使用运行时默认路径）
+}
+
+// NodeExecConfig Run 执行后端配置
+type NodeExecConfig struct {
+	Backend string            `yaml:"backend"` // 默认执行后端：docker（默认）/ process
+	Process NodeProcessConfig `yaml:"process"`
+}
+
+// NodeProcessConfig process 执行后端配置（在宿主机上直接运行 Agent 命令，适用于无法运行 Docker 的节点）
+type NodeProcessConfig struct {
+	Enabled    bool     `yaml:"enabled"`      // 默认后端为 docker 时是否允许任务指定 process 后端
+	User       string   `yaml:"user"`         // 沙箱用户（NodeManager 需以 root 运行）
+	PassEnv    []string `yaml:"pass_env"`     // 额外透传给 Agent 进程的环境变量名
+	CPUSeconds int64    `yaml:"cpu_seconds"`  // CPU 时间上限（秒，0 不限制）
+	MemoryMB   int64    `yaml:"memory_mb"`    // 地址空间上限（MB，0 不限制）
+	OpenFiles  int64    `yaml:"open_files"`   // 打开文件数上限（0 不限制）
+	Processes  int64    `yaml:"processes"`    // 沙箱用户进程数上限（0 不限制）
+	FileSizeMB int64    `yaml:"file_size_mb"` // 单个文件大小上限（MB，0 不限制）
 }
 
 // SchedulerConfig 调度器配置
@@ -131,12 +472,33 @@ type SchedulerConfig struct {
 	Redis    SchedulerRedisConfig    `yaml:"redis"`
 	Fallback SchedulerFallbackConfig `yaml:"fallback"`
 	Requeue  SchedulerRequeueConfig  `yaml:"requeue"`
+
+	// Preemption high 优先级 Run 抢占饱和节点上未开始执行的 low 优先级 Run（默认关闭）
+	Preemption SchedulerPreemptionConfig `yaml:"preemption"`
+
+	// Sharding 分片调度：Run 按哈希槽分配给并行 worker，节点分派批量写入 Redis
+	Sharding SchedulerShardingConfig `yaml:"sharding"`
+
+	// Watchdog 超时巡检：将超过执行时限的 Run 标记为 timeout 并通知节点终止执行
+	Watchdog SchedulerWatchdogConfig `yaml:"watchdog"`
+
+	// Reconcile 孤儿 Run 回收：节点失联或丢失执行时重新排队或判定失败
+	Reconcile SchedulerReconcileConfig `yaml:"reconcile"`
+
+	// Accounts 账号池调度：按账号并发数与每日请求额度选择最空闲的账号
+	Accounts SchedulerAccountsConfig `yaml:"accounts"`
 }
 
 type SchedulerStrategyConfig struct {
-	Default    string                    `yaml:"default"`
-	Chain      []string                  `yaml:"chain"`
-	LabelMatch SchedulerLabelMatchConfig `yaml:"label_match"`
+	Default     string                     `yaml:"default"`
+	Chain       []string                   `yaml:"chain"`
+	LabelMatch  SchedulerLabelMatchConfig  `yaml:"label_match"`
+	LeastLoaded SchedulerLeastLoadedConfig `yaml:"least_loaded"`
+}
+
+// SchedulerLeastLoadedConfig least_loaded 策略：按节点上报的 CPU/内存/负载选择利用率最低的节点
+type SchedulerLeastLoadedConfig struct {
+	Window time.Duration `yaml:"window"` // 指标统计窗口（默认 5m，最长 15m）
 }
 
 type SchedulerLabelMatchConfig struct {
@@ -157,25 +519,75 @@ type SchedulerRequeueConfig struct {
 	OfflineThreshold time.Duration `yaml:"offline_threshold"`
 }
 
+type SchedulerPreemptionConfig struct {
+	Enabled bool `yaml:"enabled"`
+}
+
+type SchedulerShardingConfig struct {
+	Workers int `yaml:"workers"` // 并行调度 worker 数（1 为串行调度）
+	Slots   int `yaml:"slots"`   // 哈希槽数量
+}
+
+type SchedulerWatchdogConfig struct {
+	Interval       time.Duration `yaml:"interval"`        // 巡检间隔
+	DefaultTimeout time.Duration `yaml:"default_timeout"` // 任务未设置 timeout_seconds 时的默认执行时限
+}
+
+type SchedulerReconcileConfig struct {
+	Interval          time.Duration `yaml:"interval"`           // 失联节点巡检间隔
+	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"` // Node Manager 心跳间隔
+	MissedHeartbeats  int           `yaml:"missed_heartbeats"`  // 连续缺失多少次心跳后判定 Run 为孤儿
+	RequeueStarted    bool          `yaml:"requeue_started"`    // 已开始执行的孤儿 Run 是否重新排队（默认判定为 failed）
+}
+
+type SchedulerAccountsConfig struct {
+	// Limits 账号额度，键为账号 ID 或 Agent 类型 ID（账号 ID 优先）
+	Limits map[string]SchedulerAccountLimitConfig `yaml:"limits"`
+	// Cooldown 未配置每日额度的账号触发限流后的冷却时间
+	Cooldown time.Duration `yaml:"cooldown"`
+	// MaxFailovers 单个 Run 因限流切换账号的次数上限，超过后 Run 按失败结束
+	MaxFailovers int `yaml:"max_failovers"`
+}
+
+type SchedulerAccountLimitConfig struct {
+	MaxConcurrent int `yaml:"max_concurrent"` // 同时执行的 Run 数上限（0 为不限制）
+	DailyRequests int `yaml:"daily_requests"` // 每日（UTC）派发的 Run 数上限（0 为不限制）
+}
+
 // Config 应用配置（最终使用的配置）
 type Config struct {
-	Env            Environment
-	DatabaseDriver string // "postgres", "sqlite", or "mongodb"
-	DatabaseURL    string
-	DatabaseDBName string // MongoDB 数据库名称
-	RedisURL       string
-	APIPort        string
-	Scheduler      SchedulerConfig
-	TLS            TLSConfig
-	Auth           AuthConfig
-	MinIO          MinIOConfig     // MinIO 对象存储配置
-	APIServer      APIServerConfig // API Server 配置（端口 + URL）
-	Node           NodeConfig      // 节点共性配置（Node Manager 使用）
-	ConfigFilePath string          // 实际加载的配置文件路径（用于配置管理 API）
+	Env                   Environment
+	DatabaseDriver        string // "postgres", "sqlite", "mysql", or "mongodb"
+	DatabaseURL           string
+	DatabaseDBName        string        // MongoDB 数据库名称
+	DatabaseReadURL       string        // 只读副本连接串（DATABASE_READ_URL 或 database.read_host 构建，为空时不启用）
+	DatabaseReplicaMaxLag time.Duration // 只读副本允许的最大复制延迟（0 表示使用默认值）
+	DatabasePool          DatabasePoolConfig
+	DatabaseEvents        DatabaseEventsConfig
+	RedisURL              string
+	QueueDriver           string // 消息队列后端："redis"（默认）或 "nats"
+	QueueURL              string // 消息队列连接串（redis 驱动时与 RedisURL 相同）
+	APIPort               string
+	Scheduler             SchedulerConfig
+	TLS                   TLSConfig
+	Auth                  AuthConfig
+	MinIO                 MinIOConfig       // MinIO 对象存储配置
+	APIServer             APIServerConfig   // API Server 配置（端口 + URL）
+	Node                  NodeConfig        // 节点共性配置（Node Manager 使用）
+	Moderation            ModerationConfig  // Agent 输出内容审核
+	Maintenance           MaintenanceConfig // 存储维护任务
+	Lifecycle             LifecycleConfig   // Run 数据分层
+	Gateway               GatewayConfig     // Agent 网关
+	Registry              RegistryConfig    // 模板注册表同步
+	EventSink             EventSinkConfig   // Run 事件镜像到 Kafka
+	Encryption            EncryptionConfig  // 敏感字段静态加密
+	Log                   LogConfig         // 日志
+	ConfigFilePath        string            // 实际加载的配置文件路径（用于配置管理 API）
 }
 
 // yamlConfigInternal 内部包装，记录配置文件来源（不参与 YAML 序列化）
 type yamlConfigInternal struct {
 	YAMLConfig `yaml:",inline"`
 	loadedFrom string
+	parseErr   error // 配置文件解析错误
 }
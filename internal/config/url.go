@@ -25,17 +25,35 @@ func buildDatabaseURL(db DatabaseConfig, password string) string {
 			return fmt.Sprintf("mongodb://%s:%s@%s:%d", db.User, password, db.Host, db.Port)
 		}
 		return fmt.Sprintf("mongodb://%s:%d", db.Host, db.Port)
+	case "mysql":
+		return fmt.Sprintf("mysql://%s:%s@%s:%d/%s", db.User, password, db.Host, db.Port, db.Name)
 	default: // postgres
 		return fmt.Sprintf("postgres://%s:%s@%s:%d/%s?sslmode=%s",
 			db.User, password, db.Host, db.Port, db.Name, db.SSLMode)
 	}
 }
 
+// buildReadReplicaURL 根据 read_host / read_port 构建只读副本连接字符串（仅 PostgreSQL / MySQL）
+func buildReadReplicaURL(db DatabaseConfig, password string) string {
+	if db.ReadHost == "" {
+		return ""
+	}
+	switch strings.ToLower(db.Driver) {
+	case "sqlite", "mongodb":
+		return ""
+	}
+	db.Host = db.ReadHost
+	if db.ReadPort > 0 {
+		db.Port = db.ReadPort
+	}
+	return buildDatabaseURL(db, password)
+}
+
 // detectDatabaseDriver 检测数据库驱动类型
 // 优先级：YAML driver 字段 > DATABASE_URL 前缀自动检测 > 默认 mongodb
 func detectDatabaseDriver(yamlDriver, databaseURL string) string {
 	// 1. YAML 显式指定
-	if d := strings.ToLower(yamlDriver); d == "sqlite" || d == "postgres" || d == "mongodb" {
+	if d := strings.ToLower(yamlDriver); d == "sqlite" || d == "postgres" || d == "mysql" || d == "mongodb" {
 		return d
 	}
 	// 2. 从 DATABASE_URL 前缀自动检测
@@ -45,6 +63,9 @@ func detectDatabaseDriver(yamlDriver, databaseURL string) string {
 	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
 		return "postgres"
 	}
+	if strings.HasPrefix(databaseURL, "mysql://") || strings.HasPrefix(databaseURL, "mariadb://") {
+		return "mysql"
+	}
 	if strings.HasPrefix(databaseURL, "mongodb://") || strings.HasPrefix(databaseURL, "mongodb+srv://") {
 		return "mongodb"
 	}
@@ -66,7 +87,7 @@ func buildRedisURL(redis RedisConfig) string {
 
 // maskPassword 隐藏密码
 func maskPassword(url string) string {
-	re := regexp.MustCompile(`(://[^:]+:)([^@]+)(@)`)
+	re := regexp.MustCompile(`(://[^:/@]*:)([^@]+)(@)`) // 用户名可为空（如 redis://:password@host）
 	return re.ReplaceAllString(url, "${1}***${3}")
 }
 
@@ -111,6 +132,20 @@ func (c *Config) String() string {
 		c.Env, c.DatabaseDriver, maskPassword(c.DatabaseURL), c.RedisURL)
 }
 
+// resolveQueue 解析消息队列后端与连接串
+// driver 为空时使用 redis；redis 驱动未配置 URL 时复用 Redis 连接串
+func resolveQueue(q QueueConfig, redisURL string) (string, string) {
+	driver := strings.ToLower(strings.TrimSpace(q.Driver))
+	if driver == "" {
+		driver = "redis"
+	}
+	url := q.URL
+	if driver == "redis" && url == "" {
+		url = redisURL
+	}
+	return driver, url
+}
+
 // validate 验证并填充调度器默认值
 func (s *SchedulerConfig) validate() {
 	if s.NodeID == "" {
@@ -120,7 +155,10 @@ func (s *SchedulerConfig) validate() {
 		s.Strategy.Default = "label_match"
 	}
 	if len(s.Strategy.Chain) == 0 {
-		s.Strategy.Chain = []string{"affinity", "label_match"}
+		s.Strategy.Chain = []string{"direct", "affinity", "region", "task_affinity", "anti_affinity", "spread", "label_match"}
+	}
+	if s.Strategy.LeastLoaded.Window <= 0 {
+		s.Strategy.LeastLoaded.Window = 5 * time.Minute
 	}
 	if s.Redis.ReadTimeout == 0 {
 		s.Redis.ReadTimeout = 5 * time.Second
@@ -137,4 +175,31 @@ func (s *SchedulerConfig) validate() {
 	if s.Requeue.OfflineThreshold == 0 {
 		s.Requeue.OfflineThreshold = 30 * time.Second
 	}
+	if s.Sharding.Workers <= 0 {
+		s.Sharding.Workers = 1
+	}
+	if s.Sharding.Slots <= 0 {
+		s.Sharding.Slots = 1024
+	}
+	if s.Watchdog.Interval == 0 {
+		s.Watchdog.Interval = 30 * time.Second
+	}
+	if s.Watchdog.DefaultTimeout == 0 {
+		s.Watchdog.DefaultTimeout = 2 * time.Hour
+	}
+	if s.Reconcile.Interval == 0 {
+		s.Reconcile.Interval = 30 * time.Second
+	}
+	if s.Reconcile.HeartbeatInterval == 0 {
+		s.Reconcile.HeartbeatInterval = 10 * time.Second
+	}
+	if s.Reconcile.MissedHeartbeats <= 0 {
+		s.Reconcile.MissedHeartbeats = 3
+	}
+	if s.Accounts.Cooldown == 0 {
+		s.Accounts.Cooldown = 15 * time.Minute
+	}
+	if s.Accounts.MaxFailovers <= 0 {
+		s.Accounts.MaxFailovers = 3
+	}
 }
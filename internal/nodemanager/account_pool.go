@@ -0,0 +1,145 @@
+// Package nodemanager 账号池的账号租用与限流切换
+//
+// 项目任务的快照 agent.account_pool 记录候选账号：docker 执行后端在准备容器前向 API Server
+// 租用负载最低的账号（POST /api/v1/runs/{id}/account/lease）。Agent 失败且输出命中限流/额度
+// 错误时上报 account_failover 警告并通知 API Server 切换账号（POST /api/v1/runs/{id}/account/failover），
+// 账号进入冷却，Run 重新排队由池中其他账号继续执行，本节点不再上报终态。
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// quotaOutputTail 限流检测保留的 stdout 末尾字节数
+const quotaOutputTail = 8 << 10
+
+var (
+	// quotaErrorPattern 限流/额度错误的特征（各 Agent CLI 的错误信息不统一，按关键字匹配）
+	quotaErrorPattern = regexp.MustCompile(`(?i)rate[ _-]?limit|quota|too many requests|usage limit|\b429\b`)
+	// retryAfterPattern 错误信息中的重试等待秒数（如 Retry-After: 600、"retry_after": 600）
+	retryAfterPattern = regexp.MustCompile(`(?i)retry[ _-]?after["':= ]+(\d+)`)
+
+	// errNoPoolAccount 账号池中没有可用账号
+	errNoPoolAccount = errors.New("账号池中没有可用账号")
+)
+
+// hasAccountPool 判断 Run 是否从账号池分配账号（绑定了实例的 Run 不参与）
+func hasAccountPool(agent *model.SnapshotAgent) bool {
+	return agent.InstanceID == "" && len(agent.AccountPool) > 0
+}
+
+// leasePoolAccount 向 API Server 租用账号池中负载最低的账号，成功时写入 agent.AccountID
+//
+// 返回是否租用成功；API Server 不支持账号池或请求失败时沿用快照中创建 Run 时分配的账号，
+// 账号池中没有可用账号时返回 errNoPoolAccount。
+func (nm *NodeManager) leasePoolAccount(ctx context.Context, runID string, agent *model.SnapshotAgent) (bool, error) {
+	req, _ := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/runs/"+runID+"/account/lease", nil)
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[AccountPool] 任务 %s 租用账号失败，沿用快照账号: %v", runID, err)
+		return false, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusConflict {
+		return false, errNoPoolAccount
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		log.Printf("[AccountPool] 任务 %s 租用账号失败，沿用快照账号: %d %s", runID, resp.StatusCode, strings.TrimSpace(string(msg)))
+		return false, nil
+	}
+	var lease struct {
+		AccountID string `json:"account_id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&lease); err != nil || lease.AccountID == "" {
+		log.Printf("[AccountPool] 任务 %s 租用账号响应无效，沿用快照账号: %v", runID, err)
+		return false, nil
+	}
+	agent.AccountID = lease.AccountID
+	log.Printf("[AccountPool] 任务 %s 租用账号 %s", runID, lease.AccountID)
+	return true, nil
+}
+
+// failoverAccount 通知 API Server 账号触发限流（或没有可用账号），返回 Run 是否已重新排队
+func (nm *NodeManager) failoverAccount(ctx context.Context, runID, reason string, retryAfter int) bool {
+	body, _ := json.Marshal(map[string]interface{}{
+		"reason":              reason,
+		"retry_after_seconds": retryAfter,
+	})
+	req, _ := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/runs/"+runID+"/account/failover",
+		bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[AccountPool] 任务 %s 切换账号失败: %v", runID, err)
+		return false
+	}
+	defer resp.Body.Close()
+	var result struct {
+		AccountID string `json:"account_id"`
+		Failovers int64  `json:"failovers"`
+		Requeued  bool   `json:"requeued"`
+	}
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&result) != nil {
+		log.Printf("[AccountPool] 任务 %s 切换账号失败: %d", runID, resp.StatusCode)
+		return false
+	}
+	log.Printf("[AccountPool] 任务 %s 账号 %s 进入冷却，累计切换 %d 次，重新排队: %t",
+		runID, result.AccountID, result.Failovers, result.Requeued)
+	return result.Requeued
+}
+
+// detectQuotaError 在 Agent 输出中查找限流/额度错误，返回命中的行（未命中时为空）与重试等待秒数
+func detectQuotaError(output string) (string, int) {
+	for _, line := range strings.Split(output, "\n") {
+		if !quotaErrorPattern.MatchString(line) {
+			continue
+		}
+		retryAfter := 0
+		if m := retryAfterPattern.FindStringSubmatch(line); m != nil {
+			retryAfter, _ = strconv.Atoi(m[1])
+		}
+		line = strings.TrimSpace(line)
+		if len(line) > 500 {
+			line = line[:500]
+		}
+		return line, retryAfter
+	}
+	return "", 0
+}
+
+// tailBuffer 只保留最后 max 字节的写入缓冲（写入始终成功）
+type tailBuffer struct {
+	buf []byte
+	max int
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.buf = append(b.buf, p...)
+	if over := len(b.buf) - b.max; over > 0 {
+		b.buf = append(b.buf[:0], b.buf[over:]...)
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string { return string(b.buf) }
+
+// quotaFailureReason 组合限流错误说明（用于 warning 事件）
+func quotaFailureReason(accountID, line string) string {
+	return fmt.Sprintf("账号 %s 触发限流或额度耗尽: %s", accountID, line)
+}
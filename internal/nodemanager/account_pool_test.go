@@ -0,0 +1,100 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestDetectQuotaError(t *testing.T) {
+	cases := []struct {
+		output     string
+		line       string
+		retryAfter int
+	}{
+		{"starting\nError: 429 Too Many Requests, retry-after: 600\n", "Error: 429 Too Many Requests, retry-after: 600", 600},
+		{`{"type":"error","message":"Free tier quota exceeded","retry_after": 3600}`, `{"type":"error","message":"Free tier quota exceeded","retry_after": 3600}`, 3600},
+		{"Claude usage limit reached|1760000000", "Claude usage limit reached|1760000000", 0},
+		{"API Error: Rate limit exceeded", "API Error: Rate limit exceeded", 0},
+		{"panic: file not found\nexit status 1", "", 0},
+		{"processed 4290 files", "", 0},
+	}
+	for _, c := range cases {
+		line, retryAfter := detectQuotaError(c.output)
+		if line != c.line || retryAfter != c.retryAfter {
+			t.Errorf("detectQuotaError(%q) = %q %d, want %q %d", c.output, line, retryAfter, c.line, c.retryAfter)
+		}
+	}
+}
+
+func TestTailBuffer(t *testing.T) {
+	b := &tailBuffer{max: 8}
+	b.Write([]byte("hello "))
+	b.Write([]byte("world"))
+	if got := b.String(); got != "lo world" {
+		t.Errorf("tail = %q, want %q", got, "lo world")
+	}
+}
+
+func TestHasAccountPool(t *testing.T) {
+	pool := []string{"acc-1", "acc-2"}
+	if !hasAccountPool(&model.SnapshotAgent{AccountID: "acc-1", AccountPool: pool}) {
+		t.Error("run with account_pool should lease")
+	}
+	if hasAccountPool(&model.SnapshotAgent{InstanceID: "inst-1", AccountPool: pool}) {
+		t.Error("run bound to instance should not lease")
+	}
+	if hasAccountPool(&model.SnapshotAgent{AccountID: "acc-1"}) {
+		t.Error("run without account_pool should not lease")
+	}
+}
+
+func TestLeasePoolAccount(t *testing.T) {
+	status := http.StatusOK
+	var failover map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/account/lease"):
+			w.WriteHeader(status)
+			w.Write([]byte(`{"run_id":"run-1","account_id":"acc-2"}`))
+		case strings.HasSuffix(r.URL.Path, "/account/failover"):
+			json.NewDecoder(r.Body).Decode(&failover)
+			w.Write([]byte(`{"run_id":"run-1","account_id":"acc-2","failovers":1,"requeued":true}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client()}
+	ctx := context.Background()
+
+	agent := &model.SnapshotAgent{AccountID: "acc-1"}
+	leased, err := nm.leasePoolAccount(ctx, "run-1", agent)
+	if err != nil || !leased || agent.AccountID != "acc-2" {
+		t.Fatalf("lease = %v %v, account_id = %v", leased, err, agent.AccountID)
+	}
+
+	// API Server 不支持账号池时沿用快照账号
+	status = http.StatusNotFound
+	agent = &model.SnapshotAgent{AccountID: "acc-1"}
+	if leased, err := nm.leasePoolAccount(ctx, "run-1", agent); err != nil || leased || agent.AccountID != "acc-1" {
+		t.Errorf("lease = %v %v, account_id = %v", leased, err, agent.AccountID)
+	}
+
+	status = http.StatusConflict
+	if _, err := nm.leasePoolAccount(ctx, "run-1", agent); err != errNoPoolAccount {
+		t.Errorf("err = %v, want errNoPoolAccount", err)
+	}
+
+	if !nm.failoverAccount(ctx, "run-1", "429 Too Many Requests", 600) {
+		t.Error("failover should report requeued")
+	}
+	if failover["reason"] != "429 Too Many Requests" || failover["retry_after_seconds"] != float64(600) {
+		t.Errorf("failover body = %v", failover)
+	}
+}
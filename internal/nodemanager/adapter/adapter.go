@@ -33,6 +33,7 @@
 //   - run.go: 运行时配置相关（RunConfig, MountConfig）
 //   - event.go: 事件和产物相关（CanonicalEvent, EventType, Artifacts）
 //   - adapter.go: Adapter 接口和注册表
+//   - capability.go: 版本探测与能力声明（Capabilities）
 package adapter
 
 import "context"
@@ -48,6 +49,7 @@ import "context"
 //  2. 将 TaskSpec + AgentConfig 转换为 CLI 启动命令（BuildCommand）
 //  3. 解析 CLI 输出为统一事件（ParseEvent）
 //  4. 收集执行产物（CollectArtifacts）
+//  5. 探测 CLI 版本与支持的能力（Detect）
 //
 // # Adapter 是无状态的，所有状态通过参数传递
 //
@@ -84,6 +86,11 @@ type Adapter interface {
 	// CollectArtifacts 收集运行产物
 	// 在 workspaceDir 中查找事件日志、diff 等产物
 	CollectArtifacts(ctx context.Context, workspaceDir string) (*Artifacts, error)
+
+	// Detect 探测节点上 CLI 的版本与支持的能力
+	// 不同版本 CLI 的输出格式不同，NodeManager 将结果随心跳上报，供调度器按能力过滤节点
+	// 探测失败时仍返回静态能力（Version 为空）与错误
+	Detect(ctx context.Context) (*Capabilities, error)
 }
 
 // Registry Adapter 注册表
@@ -251,3 +251,6 @@ func (m *mockAdapter) ParseEvent(line string) (*CanonicalEvent, error) {
 func (m *mockAdapter) CollectArtifacts(ctx context.Context, workDir string) (*Artifacts, error) {
 	return &Artifacts{}, nil
 }
+func (m *mockAdapter) Detect(ctx context.Context) (*Capabilities, error) {
+	return &Capabilities{Features: []string{FeatureStreaming}}, nil
+}
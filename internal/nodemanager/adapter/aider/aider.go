@@ -0,0 +1,205 @@
+// Package aider 实现 Aider Adapter
+//
+// Aider 没有结构化输出，Adapter 以 --no-pretty 纯文本模式运行并逐行解析：
+//   - "Applied edit to <path>" 映射为 file_write
+//   - "Tokens: ... Cost: $x message, $y session." 映射为 progress，并提取本次调用的用量
+//   - 其余非空行映射为 message
+package aider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/nodemanager/adapter"
+)
+
+var (
+	// appliedEditPattern 文件修改行
+	appliedEditPattern = regexp.MustCompile(`^Applied edit to (.+)$`)
+	// tokensPattern 用量行，如 "Tokens: 2.3k sent, 1.2k cache hit, 150 received. Cost: $0.01 message, $0.05 session."
+	tokensPattern = regexp.MustCompile(`^Tokens: (.+?)\.\s+Cost: \$([0-9.]+) message`)
+	// tokenCountPattern 用量行中的单项，如 "2.3k sent"
+	tokenCountPattern = regexp.MustCompile(`^([0-9.]+)([kKmM]?) (.+)$`)
+)
+
+// Adapter Aider 适配器
+type Adapter struct{}
+
+// New 创建 Aider Adapter
+func New() *Adapter {
+	return &Adapter{}
+}
+
+// Name 返回适配器名称
+func (a *Adapter) Name() string {
+	return "aider-v1"
+}
+
+// Validate 验证 AgentConfig
+func (a *Adapter) Validate(agent *adapter.AgentConfig) error {
+	if agent.Type != "aider" {
+		return fmt.Errorf("agent type mismatch: expected aider, got %s", agent.Type)
+	}
+	return nil
+}
+
+// BuildCommand 构建运行命令
+// ctx 用于超时控制（当前实现未使用，预留接口）
+func (a *Adapter) BuildCommand(ctx context.Context, spec *adapter.TaskSpec, agent *adapter.AgentConfig) (*adapter.RunConfig, error) {
+	args := []string{
+		"--message", spec.Prompt,
+		"--yes-always",
+		"--no-stream",
+		"--no-pretty",
+		"--no-check-update",
+	}
+
+	// 模型（任意 OpenAI 兼容模型，如 openai/deepseek-chat）
+	if agent.Model != "" {
+		args = append(args, "--model", agent.Model)
+	}
+
+	// 编辑格式（whole / diff / udiff 等）
+	if format, ok := agent.Parameters["edit_format"].(string); ok && format != "" {
+		args = append(args, "--edit-format", format)
+	}
+
+	// 默认不自动提交，由平台的工作空间流程处理提交
+	if autoCommits, ok := agent.Parameters["auto_commits"].(bool); !ok || !autoCommits {
+		args = append(args, "--no-auto-commits")
+	}
+
+	env := map[string]string{}
+	// OpenAI 兼容服务地址
+	if base, ok := agent.Parameters["api_base"].(string); ok && base != "" {
+		env["OPENAI_API_BASE"] = base
+	}
+
+	return &adapter.RunConfig{
+		Image:      "runners/aider:latest",
+		Command:    []string{"aider"},
+		Args:       args,
+		Env:        env,
+		WorkingDir: "/workspace",
+	}, nil
+}
+
+// ParseEvent 解析一行纯文本输出
+func (a *Adapter) ParseEvent(line string) (*adapter.CanonicalEvent, error) {
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return nil, nil
+	}
+
+	if m := appliedEditPattern.FindStringSubmatch(line); m != nil {
+		return &adapter.CanonicalEvent{
+			Type:    adapter.EventFileWrite,
+			Payload: map[string]interface{}{"path": m[1]},
+		}, nil
+	}
+
+	if m := tokensPattern.FindStringSubmatch(line); m != nil {
+		payload := map[string]interface{}{"message": line}
+		for _, part := range strings.Split(m[1], ", ") {
+			if key, n, ok := parseTokenCount(part); ok {
+				payload[key] = n
+			}
+		}
+		if cost, err := strconv.ParseFloat(m[2], 64); err == nil {
+			payload["cost_usd"] = cost
+		}
+		return &adapter.CanonicalEvent{Type: adapter.EventProgress, Payload: payload}, nil
+	}
+
+	return &adapter.CanonicalEvent{
+		Type:    adapter.EventMessage,
+		Payload: map[string]interface{}{"content": line},
+	}, nil
+}
+
+// parseTokenCount 解析用量单项（"2.3k sent" → input_tokens=2300）
+func parseTokenCount(part string) (string, float64, bool) {
+	m := tokenCountPattern.FindStringSubmatch(strings.TrimSpace(part))
+	if m == nil {
+		return "", 0, false
+	}
+	n, err := strconv.ParseFloat(m[1], 64)
+	if err != nil {
+		return "", 0, false
+	}
+	switch strings.ToLower(m[2]) {
+	case "k":
+		n *= 1000
+	case "m":
+		n *= 1000000
+	}
+
+	var key string
+	switch m[3] {
+	case "sent":
+		key = "input_tokens"
+	case "received":
+		key = "output_tokens"
+	case "cache hit":
+		key = "cache_read_tokens"
+	case "cache write":
+		key = "cache_write_tokens"
+	default:
+		return "", 0, false
+	}
+	return key, n, true
+}
+
+// ExtractUsage 从用量行提取本次调用的 Token 用量与费用
+//
+// Aider 的 "sent" 包含缓存命中部分，这里扣除后作为 InputTokens；
+// Token 数在 1k 以上时只保留一位小数，因此用量为近似值。
+func (a *Adapter) ExtractUsage(event *adapter.CanonicalEvent) *adapter.Usage {
+	if event.Type != adapter.EventProgress {
+		return nil
+	}
+	if _, ok := event.Payload["cost_usd"]; !ok {
+		return nil
+	}
+	usage := &adapter.Usage{
+		InputTokens:      int64(adapter.NumberField(event.Payload, "input_tokens")),
+		OutputTokens:     int64(adapter.NumberField(event.Payload, "output_tokens")),
+		CacheReadTokens:  int64(adapter.NumberField(event.Payload, "cache_read_tokens")),
+		CacheWriteTokens: int64(adapter.NumberField(event.Payload, "cache_write_tokens")),
+		CostUSD:          adapter.NumberField(event.Payload, "cost_usd"),
+	}
+	if usage.InputTokens >= usage.CacheReadTokens {
+		usage.InputTokens -= usage.CacheReadTokens
+	}
+	if usage.IsZero() {
+		return nil
+	}
+	return usage
+}
+
+// InterruptSignal Aider 将第一次 SIGINT 视为中断当前回复并提示再次按 Ctrl-C 退出，使用 SIGTERM 请求退出
+func (a *Adapter) InterruptSignal() string {
+	return "TERM"
+}
+
+// features 纯文本模式下的能力（逐行输出，但没有工具调用事件）
+var features = []adapter.VersionedFeature{
+	{Feature: adapter.FeatureStreaming},
+	{Feature: adapter.FeatureUsage},
+}
+
+// Detect 通过 aider --version 探测版本（输出如 "aider 0.86.1"）
+func (a *Adapter) Detect(ctx context.Context) (*adapter.Capabilities, error) {
+	return adapter.DetectVersioned(ctx, features, "aider", "--version")
+}
+
+// CollectArtifacts 收集产物
+func (a *Adapter) CollectArtifacts(ctx context.Context, workspaceDir string) (*adapter.Artifacts, error) {
+	return &adapter.Artifacts{
+		EventsFile: filepath.Join(workspaceDir, ".agent", "events.jsonl"),
+	}, nil
+}
@@ -0,0 +1,118 @@
+package aider
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/nodemanager/adapter"
+)
+
+func TestAiderAdapterName(t *testing.T) {
+	if New().Name() != "aider-v1" {
+		t.Errorf("Name() = %v, want aider-v1", New().Name())
+	}
+}
+
+func TestAiderAdapterValidate(t *testing.T) {
+	a := New()
+	if err := a.Validate(&adapter.AgentConfig{Type: "aider"}); err != nil {
+		t.Errorf("Validate(aider) error = %v", err)
+	}
+	if err := a.Validate(&adapter.AgentConfig{Type: "claude"}); err == nil {
+		t.Error("Validate(claude) expected error")
+	}
+}
+
+func TestAiderAdapterBuildCommand(t *testing.T) {
+	a := New()
+	spec := &adapter.TaskSpec{ID: "task-1", Prompt: "Fix the bug"}
+	agent := &adapter.AgentConfig{
+		Type:       "aider",
+		Model:      "openai/deepseek-chat",
+		Parameters: map[string]interface{}{"api_base": "https://llm.example.com/v1"},
+	}
+
+	cfg, err := a.BuildCommand(context.Background(), spec, agent)
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+	if cfg.Command[0] != "aider" {
+		t.Errorf("Command[0] = %v, want aider", cfg.Command[0])
+	}
+	if cfg.Args[0] != "--message" || cfg.Args[1] != "Fix the bug" {
+		t.Errorf("expected --message prompt first, got %v", cfg.Args)
+	}
+	want := map[string]bool{"--model": false, "openai/deepseek-chat": false, "--no-auto-commits": false, "--no-pretty": false}
+	for _, arg := range cfg.Args {
+		if _, ok := want[arg]; ok {
+			want[arg] = true
+		}
+	}
+	for arg, found := range want {
+		if !found {
+			t.Errorf("expected %s in args %v", arg, cfg.Args)
+		}
+	}
+	if cfg.Env["OPENAI_API_BASE"] != "https://llm.example.com/v1" {
+		t.Errorf("OPENAI_API_BASE = %q", cfg.Env["OPENAI_API_BASE"])
+	}
+}
+
+func TestAiderAdapterParseEvent(t *testing.T) {
+	a := New()
+
+	tests := []struct {
+		name     string
+		line     string
+		wantType adapter.EventType
+		wantNil  bool
+	}{
+		{name: "empty line", line: "   ", wantNil: true},
+		{name: "applied edit", line: "Applied edit to src/main.go", wantType: adapter.EventFileWrite},
+		{name: "tokens", line: "Tokens: 2.3k sent, 150 received. Cost: $0.01 message, $0.05 session.", wantType: adapter.EventProgress},
+		{name: "plain text", line: "I will update the handler.", wantType: adapter.EventMessage},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := a.ParseEvent(tt.line)
+			if err != nil {
+				t.Fatalf("ParseEvent() error = %v", err)
+			}
+			if tt.wantNil {
+				if event != nil {
+					t.Errorf("expected nil event, got %+v", event)
+				}
+				return
+			}
+			if event == nil || event.Type != tt.wantType {
+				t.Fatalf("ParseEvent() = %+v, want type %s", event, tt.wantType)
+			}
+		})
+	}
+
+	event, _ := a.ParseEvent("Applied edit to src/main.go")
+	if event.Payload["path"] != "src/main.go" {
+		t.Errorf("path = %v, want src/main.go", event.Payload["path"])
+	}
+}
+
+func TestAiderAdapterExtractUsage(t *testing.T) {
+	a := New()
+
+	event, _ := a.ParseEvent("Tokens: 12k sent, 1.5k cache write, 8k cache hit, 1.2k received. Cost: $0.03 message, $0.10 session.")
+	usage := a.ExtractUsage(event)
+	if usage == nil {
+		t.Fatal("expected usage")
+	}
+	if usage.InputTokens != 4000 || usage.OutputTokens != 1200 || usage.CacheReadTokens != 8000 || usage.CacheWriteTokens != 1500 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+	if usage.CostUSD != 0.03 {
+		t.Errorf("CostUSD = %v, want 0.03", usage.CostUSD)
+	}
+
+	message, _ := a.ParseEvent("hello")
+	if a.ExtractUsage(message) != nil {
+		t.Error("expected nil usage for message event")
+	}
+}
@@ -0,0 +1,113 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ============================================================================
+// CLI 版本探测与能力声明
+// ============================================================================
+
+// 适配器能力
+const (
+	// FeatureStreaming 执行过程中逐行输出事件（而非结束时一次性输出）
+	FeatureStreaming = "streaming"
+
+	// FeatureToolEvents 输出工具调用事件（tool_use_start / tool_result）
+	FeatureToolEvents = "tool_events"
+
+	// FeatureUsage 报告 Token 用量（实现 UsageReporter）
+	FeatureUsage = "usage"
+
+	// FeatureFeedback 通过 stdin 接收运行中人工反馈（实现 FeedbackReceiver）
+	FeatureFeedback = "feedback"
+)
+
+// probeTimeout 单次版本探测的超时时间
+const probeTimeout = 10 * time.Second
+
+// Capabilities Detect 的探测结果
+type Capabilities struct {
+	Version  string   // CLI 版本（探测失败时为空）
+	Features []string // 支持的能力
+}
+
+// Has 判断是否支持指定能力
+func (c *Capabilities) Has(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// VersionedFeature 从某个 CLI 版本起才支持的能力
+type VersionedFeature struct {
+	Feature    string
+	MinVersion string // 为空表示所有版本均支持
+}
+
+// ResolveFeatures 按 CLI 版本筛选支持的能力
+//
+// 版本未知时只保留不限版本的能力，避免把任务调度到输出格式不兼容的节点。
+func ResolveFeatures(version string, features []VersionedFeature) []string {
+	out := make([]string, 0, len(features))
+	for _, f := range features {
+		if f.MinVersion == "" || (version != "" && model.CompareVersions(version, f.MinVersion) >= 0) {
+			out = append(out, f.Feature)
+		}
+	}
+	return out
+}
+
+// CommandRunner 执行版本探测命令并返回输出
+type CommandRunner func(ctx context.Context, argv []string) ([]byte, error)
+
+type commandRunnerKey struct{}
+
+// WithCommandRunner 指定版本探测使用的命令执行器（默认在本机执行）
+//
+// NodeManager 可借此在 Agent 运行环境中执行探测，测试中用于模拟 CLI 输出。
+func WithCommandRunner(ctx context.Context, run CommandRunner) context.Context {
+	return context.WithValue(ctx, commandRunnerKey{}, run)
+}
+
+// ProbeVersion 执行 CLI 版本命令（如 claude --version）并提取版本号
+func ProbeVersion(ctx context.Context, argv ...string) (string, error) {
+	run, ok := ctx.Value(commandRunnerKey{}).(CommandRunner)
+	if !ok {
+		run = runLocal
+	}
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	out, err := run(ctx, argv)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", strings.Join(argv, " "), err)
+	}
+	version := model.ParseVersion(string(out))
+	if version == "" {
+		return "", fmt.Errorf("%s: no version in output %q", strings.Join(argv, " "), strings.TrimSpace(string(out)))
+	}
+	return version, nil
+}
+
+// DetectVersioned 探测版本并按版本筛选能力，供内置 Adapter 实现 Detect
+//
+// 探测失败时返回不限版本的能力与错误。
+func DetectVersioned(ctx context.Context, features []VersionedFeature, argv ...string) (*Capabilities, error) {
+	version, err := ProbeVersion(ctx, argv...)
+	return &Capabilities{Version: version, Features: ResolveFeatures(version, features)}, err
+}
+
+// runLocal 在本机执行命令（合并 stdout 与 stderr，部分 CLI 将版本输出到 stderr）
+func runLocal(ctx context.Context, argv []string) ([]byte, error) {
+	return exec.CommandContext(ctx, argv[0], argv[1:]...).CombinedOutput()
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 
 	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
 )
 
 // Adapter Claude Code CLI 适配器
@@ -117,6 +119,141 @@ func mapEventType(claudeType string) adapter.EventType {
 	return mapping[claudeType]
 }
 
+// ExtractUsage 从 result 事件提取本次调用的 Token 用量与费用
+//
+// result 事件在 CLI 退出前输出一次，包含整个会话的累计用量：
+//
+//	{"type": "result", "total_cost_usd": 0.012, "usage": {"input_tokens": 10, "output_tokens": 200,
+//	 "cache_read_input_tokens": 3000, "cache_creation_input_tokens": 500}, "modelUsage": {"claude-sonnet-4": {...}}}
+func (a *Adapter) ExtractUsage(event *adapter.CanonicalEvent) *adapter.Usage {
+	if event.Type != adapter.EventRunCompleted {
+		return nil
+	}
+	raw, _ := event.Payload["usage"].(map[string]interface{})
+	usage := &adapter.Usage{CostUSD: adapter.NumberField(event.Payload, "total_cost_usd", "cost_usd")}
+	if raw != nil {
+		usage.InputTokens = int64(adapter.NumberField(raw, "input_tokens"))
+		usage.OutputTokens = int64(adapter.NumberField(raw, "output_tokens"))
+		usage.CacheReadTokens = int64(adapter.NumberField(raw, "cache_read_input_tokens"))
+		usage.CacheWriteTokens = int64(adapter.NumberField(raw, "cache_creation_input_tokens"))
+	}
+	// 只使用了一个模型时记录模型名称
+	if models, ok := event.Payload["modelUsage"].(map[string]interface{}); ok && len(models) == 1 {
+		for name := range models {
+			usage.Model = name
+		}
+	}
+	if usage.IsZero() {
+		return nil
+	}
+	return usage
+}
+
+// ResumeArgs 追问时继续工作目录下最近一次会话（claude --continue）
+func (a *Adapter) ResumeArgs() []string {
+	return []string{"--continue"}
+}
+
+// SkillsDir Claude Code 从 ~/.claude/skills/<name>/SKILL.md 自动发现技能
+func (a *Adapter) SkillsDir() string {
+	return ".claude/skills"
+}
+
+// policyTools 平台权限对应的 Claude Code 工具（删除文件没有专门的工具，经 Bash 执行）
+var policyTools = map[string][]string{
+	secpolicy.PermissionFileRead:        {"Read", "Glob", "Grep", "LS", "NotebookRead"},
+	secpolicy.PermissionFileWrite:       {"Edit", "MultiEdit", "Write", "NotebookEdit"},
+	secpolicy.PermissionCommandExecute:  {"Bash"},
+	secpolicy.PermissionNetworkOutbound: {"WebFetch", "WebSearch"},
+}
+
+// PolicyArgs 被禁止的权限的工具加入 --disallowed-tools，允许与需审批的加入 --allowed-tools（审批由 NodeManager 执行）
+//
+// 禁止删除文件而允许执行命令时 CLI 无法限制（删除经 Bash 执行）。
+func (a *Adapter) PolicyArgs(policy *secpolicy.Policy) ([]string, []string) {
+	var args, unenforced []string
+	allowed := append(adapter.PolicyTools(policy, model.ToolPermissionAllowed, policyTools),
+		adapter.PolicyTools(policy, model.ToolPermissionApprovalRequired, policyTools)...)
+	if len(allowed) > 0 {
+		args = append(args, "--allowed-tools", strings.Join(allowed, ","))
+	}
+	if denied := adapter.PolicyTools(policy, model.ToolPermissionDenied, policyTools); len(denied) > 0 {
+		args = append(args, "--disallowed-tools", strings.Join(denied, ","))
+	}
+	if policy.Level(secpolicy.PermissionFileDelete) == model.ToolPermissionDenied &&
+		policy.Level(secpolicy.PermissionCommandExecute) != model.ToolPermissionDenied {
+		unenforced = append(unenforced, secpolicy.PermissionFileDelete)
+	}
+	return args, unenforced
+}
+
+// EventTool tool_use 事件中的工具名
+func (a *Adapter) EventTool(event *adapter.CanonicalEvent) string {
+	if event.Type != adapter.EventToolUseStart {
+		return ""
+	}
+	if name, ok := event.Payload["name"].(string); ok {
+		return name
+	}
+	name, _ := event.Payload["tool"].(string)
+	return name
+}
+
+// ToolPermission Claude Code 工具对应的平台权限
+func (a *Adapter) ToolPermission(tool string) string {
+	for perm, tools := range policyTools {
+		for _, t := range tools {
+			if t == tool {
+				return perm
+			}
+		}
+	}
+	return ""
+}
+
+// ToolCommand Bash 工具调用中的命令行
+//
+//	{"type": "tool_use", "name": "Bash", "input": {"command": "make test", "description": "..."}}
+func (a *Adapter) ToolCommand(event *adapter.CanonicalEvent) string {
+	if a.EventTool(event) != "Bash" {
+		return ""
+	}
+	input, _ := event.Payload["input"].(map[string]interface{})
+	command, _ := input["command"].(string)
+	return command
+}
+
+// DeniedTools result 事件中 CLI 拒绝执行的工具调用
+//
+//	{"type": "result", "permission_denials": [{"tool_name": "Bash", "tool_use_id": "...", "tool_input": {...}}]}
+func (a *Adapter) DeniedTools(event *adapter.CanonicalEvent) []string {
+	if event.Type != adapter.EventRunCompleted {
+		return nil
+	}
+	denials, _ := event.Payload["permission_denials"].([]interface{})
+	var tools []string
+	for _, d := range denials {
+		if m, ok := d.(map[string]interface{}); ok {
+			if name, _ := m["tool_name"].(string); name != "" {
+				tools = append(tools, name)
+			}
+		}
+	}
+	return tools
+}
+
+// features stream-json 模式下的能力（各版本一致）
+var features = []adapter.VersionedFeature{
+	{Feature: adapter.FeatureStreaming},
+	{Feature: adapter.FeatureToolEvents},
+	{Feature: adapter.FeatureUsage},
+}
+
+// Detect 通过 claude --version 探测版本（输出如 "1.0.33 (Claude Code)"）
+func (a *Adapter) Detect(ctx context.Context) (*adapter.Capabilities, error) {
+	return adapter.DetectVersioned(ctx, features, "claude", "--version")
+}
+
 // CollectArtifacts 收集产物
 func (a *Adapter) CollectArtifacts(ctx context.Context, workspaceDir string) (*adapter.Artifacts, error) {
 	return &adapter.Artifacts{
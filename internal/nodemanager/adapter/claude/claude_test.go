@@ -2,9 +2,12 @@ package claude
 
 import (
 	"context"
+	"slices"
 	"testing"
 
 	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
 )
 
 func TestClaudeAdapterName(t *testing.T) {
@@ -158,3 +161,55 @@ func TestClaudeAdapterCollectArtifacts(t *testing.T) {
 		t.Error("Expected non-nil artifacts")
 	}
 }
+
+func TestClaudeAdapterExtractUsage(t *testing.T) {
+	a := New()
+
+	event, _ := a.ParseEvent(`{"type":"result","subtype":"success","total_cost_usd":0.0125,"usage":{"input_tokens":12,"output_tokens":340,"cache_read_input_tokens":5000,"cache_creation_input_tokens":800},"modelUsage":{"claude-sonnet-4":{"inputTokens":12}}}`)
+	usage := a.ExtractUsage(event)
+	if usage == nil {
+		t.Fatal("ExtractUsage() = nil, want usage")
+	}
+	want := adapter.Usage{Model: "claude-sonnet-4", InputTokens: 12, OutputTokens: 340, CacheReadTokens: 5000, CacheWriteTokens: 800, CostUSD: 0.0125}
+	if *usage != want {
+		t.Errorf("ExtractUsage() = %+v, want %+v", *usage, want)
+	}
+
+	// 消息事件中的 usage 不计入，避免与 result 重复
+	event, _ = a.ParseEvent(`{"type":"assistant","message":{"usage":{"input_tokens":12,"output_tokens":34}}}`)
+	if usage := a.ExtractUsage(event); usage != nil {
+		t.Errorf("ExtractUsage(assistant) = %+v, want nil", usage)
+	}
+}
+
+func TestClaudeAdapterPolicyArgs(t *testing.T) {
+	a := New()
+	policy, _ := secpolicy.Evaluate(nil, secpolicy.Builtin("strict"))
+
+	args, unenforced := a.PolicyArgs(policy)
+	want := []string{
+		"--allowed-tools", "Read,Glob,Grep,LS,NotebookRead,Edit,MultiEdit,Write,NotebookEdit",
+		"--disallowed-tools", "Bash,WebFetch,WebSearch",
+	}
+	if !slices.Equal(args, want) || len(unenforced) != 0 {
+		t.Errorf("PolicyArgs() = %v, %v", args, unenforced)
+	}
+
+	// 允许执行命令时无法禁止删除文件
+	policy.Permissions[secpolicy.PermissionCommandExecute] = model.ToolPermissionAllowed
+	if _, unenforced := a.PolicyArgs(policy); !slices.Equal(unenforced, []string{secpolicy.PermissionFileDelete}) {
+		t.Errorf("unenforced = %v", unenforced)
+	}
+
+	event, _ := a.ParseEvent(`{"type":"tool_use","name":"Bash","input":{"command":"ls"}}`)
+	if tool := a.EventTool(event); tool != "Bash" || a.ToolPermission(tool) != secpolicy.PermissionCommandExecute {
+		t.Errorf("EventTool() = %q", tool)
+	}
+	if command := a.ToolCommand(event); command != "ls" {
+		t.Errorf("ToolCommand() = %q", command)
+	}
+	event, _ = a.ParseEvent(`{"type":"result","permission_denials":[{"tool_name":"WebFetch","tool_use_id":"t1"}]}`)
+	if denied := a.DeniedTools(event); !slices.Equal(denied, []string{"WebFetch"}) {
+		t.Errorf("DeniedTools() = %v", denied)
+	}
+}
@@ -126,10 +126,18 @@ const (
 	// Payload: {"action": "delete_file", "target": "...", "reason": "..."}
 	EventApprovalRequest EventType = "approval_request"
 
+	// EventApprovalRequired Agent 需要人工审批后才能继续（NodeManager 暂停执行并等待审批决定）
+	// Payload: {"operation": "...", "reason": "...", "type": "dangerous_operation", "timeout_seconds": 1800}
+	EventApprovalRequired EventType = "approval_required"
+
 	// EventApprovalResponse 人工审批响应
 	// Payload: {"approved": true, "comment": "..."}
 	EventApprovalResponse EventType = "approval_response"
 
+	// EventSpawnSubtask Agent 将部分工作拆分为子任务（NodeManager 请求 API Server 创建子任务及其 Run）
+	// Payload: {"prompt": "...", "name": "...", "description": "...", "labels": {...}}
+	EventSpawnSubtask EventType = "spawn_subtask"
+
 	// EventCheckpoint 检查点（可恢复）
 	// Payload: {"state": {...}, "resumable": true}
 	EventCheckpoint EventType = "checkpoint"
@@ -147,6 +155,10 @@ const (
 	// Payload: {"result": "...", "usage": {...}}
 	EventResult EventType = "result"
 
+	// EventUsage Token 用量与费用（增量，由 NodeManager 从实现 UsageReporter 的 Adapter 提取）
+	// Payload: {"model": "...", "input_tokens": 1200, "output_tokens": 300, "cache_read_tokens": 0, "cache_write_tokens": 0, "cost_usd": 0.012}
+	EventUsage EventType = "usage"
+
 	// === 错误事件 ===
 
 	// EventError 错误事件
@@ -0,0 +1,34 @@
+package adapter
+
+import "fmt"
+
+// ============================================================================
+// 运行中人工反馈的控制通道
+// ============================================================================
+
+// FeedbackFile 运行中人工反馈的 interrupt 文件（相对 Agent 工作目录）
+const FeedbackFile = ".agents-admin/FEEDBACK.md"
+
+// FeedbackPromptHint 追加到 Prompt 的 interrupt 文件说明（Adapter 未实现 FeedbackReceiver 时）
+const FeedbackPromptHint = "\n\n运行期间用户可能在 " + FeedbackFile + " 中追加补充指导。每完成一个步骤后检查该文件，如有新内容请优先遵循。"
+
+// Feedback 注入运行中 Agent 的人工反馈
+type Feedback struct {
+	ID      string
+	Type    string // guidance / correction / clarification
+	Content string
+}
+
+// FeedbackReceiver 可选接口：通过 stdin 管道接收运行中人工反馈的 Adapter
+//
+// 适用于以流式输入模式运行、持续读取 stdin 的 CLI。未实现时 NodeManager 将反馈
+// 追加到工作目录的 FeedbackFile，并在 Prompt 中提示 Agent 定期检查（FeedbackPromptHint）。
+type FeedbackReceiver interface {
+	// FormatFeedback 将人工反馈编码为写入 stdin 的内容（包含结尾换行）
+	FormatFeedback(f Feedback) []byte
+}
+
+// FormatFeedbackFile 人工反馈追加到 interrupt 文件中的内容
+func FormatFeedbackFile(f Feedback) []byte {
+	return []byte(fmt.Sprintf("\n## 用户反馈（%s，%s）\n\n%s\n", f.Type, f.ID, f.Content))
+}
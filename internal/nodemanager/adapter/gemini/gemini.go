@@ -7,8 +7,11 @@ import (
 	"fmt"
 	"path/filepath"
 	"strconv"
+	"strings"
 
 	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
 )
 
 // Adapter Gemini CLI 适配器
@@ -98,6 +101,119 @@ func mapEventType(geminiType string) adapter.EventType {
 	return mapping[geminiType]
 }
 
+// ExtractUsage 从 done 事件的统计信息提取本次调用的 Token 用量
+//
+//	{"type": "done", "model": "gemini-2.5-pro", "stats": {"input_tokens": 1200, "output_tokens": 300, "cached": 800}}
+//
+// Gemini CLI 不报告费用，CostUSD 为 0。
+func (a *Adapter) ExtractUsage(event *adapter.CanonicalEvent) *adapter.Usage {
+	if event.Type != adapter.EventRunCompleted {
+		return nil
+	}
+	stats, ok := event.Payload["stats"].(map[string]interface{})
+	if !ok {
+		if stats, ok = event.Payload["usage"].(map[string]interface{}); !ok {
+			return nil
+		}
+	}
+	usage := &adapter.Usage{
+		InputTokens:     int64(adapter.NumberField(stats, "input_tokens", "prompt_tokens")),
+		OutputTokens:    int64(adapter.NumberField(stats, "output_tokens", "candidates_tokens")),
+		CacheReadTokens: int64(adapter.NumberField(stats, "cached", "cached_tokens")),
+	}
+	usage.Model, _ = event.Payload["model"].(string)
+	if usage.IsZero() {
+		return nil
+	}
+	return usage
+}
+
+// policyTools 平台权限对应的 Gemini CLI 工具（删除文件没有专门的工具，经 run_shell_command 执行）
+var policyTools = map[string][]string{
+	secpolicy.PermissionFileRead:        {"read_file", "read_many_files", "glob", "search_file_content", "list_directory"},
+	secpolicy.PermissionFileWrite:       {"write_file", "replace"},
+	secpolicy.PermissionCommandExecute:  {"run_shell_command"},
+	secpolicy.PermissionNetworkOutbound: {"web_fetch", "google_web_search"},
+}
+
+// PolicyArgs 非交互模式下需要确认的工具（写文件、命令、网络）只有列入 --allowed-tools 才会执行：
+// 允许与需审批的权限的工具加入 --allowed-tools（审批由 NodeManager 执行），被禁止的不列入，
+// 并启用 --sandbox 隔离执行环境。
+//
+// 读文件工具无需确认，禁止读文件时 CLI 无法限制；禁止删除文件而允许执行命令时同样无法限制。
+func (a *Adapter) PolicyArgs(policy *secpolicy.Policy) ([]string, []string) {
+	var args, unenforced []string
+	allowed := append(adapter.PolicyTools(policy, model.ToolPermissionAllowed, policyTools),
+		adapter.PolicyTools(policy, model.ToolPermissionApprovalRequired, policyTools)...)
+	if len(allowed) > 0 {
+		args = append(args, "--allowed-tools", strings.Join(allowed, ","))
+	}
+	if len(policy.With(model.ToolPermissionDenied)) > 0 {
+		args = append(args, "--sandbox")
+	}
+	if policy.Level(secpolicy.PermissionFileRead) == model.ToolPermissionDenied {
+		unenforced = append(unenforced, secpolicy.PermissionFileRead)
+	}
+	if policy.Level(secpolicy.PermissionFileDelete) == model.ToolPermissionDenied &&
+		policy.Level(secpolicy.PermissionCommandExecute) != model.ToolPermissionDenied {
+		unenforced = append(unenforced, secpolicy.PermissionFileDelete)
+	}
+	return args, unenforced
+}
+
+// EventTool tool_call 事件中的工具名
+func (a *Adapter) EventTool(event *adapter.CanonicalEvent) string {
+	if event.Type != adapter.EventToolUseStart {
+		return ""
+	}
+	if name, ok := event.Payload["name"].(string); ok {
+		return name
+	}
+	name, _ := event.Payload["tool"].(string)
+	return name
+}
+
+// ToolPermission Gemini CLI 工具对应的平台权限
+func (a *Adapter) ToolPermission(tool string) string {
+	for perm, tools := range policyTools {
+		for _, t := range tools {
+			if t == tool {
+				return perm
+			}
+		}
+	}
+	return ""
+}
+
+// ToolCommand run_shell_command 工具调用中的命令行
+//
+//	{"type": "tool_call", "tool": "run_shell_command", "args": {"command": "make test"}}
+func (a *Adapter) ToolCommand(event *adapter.CanonicalEvent) string {
+	if a.EventTool(event) != "run_shell_command" {
+		return ""
+	}
+	args, ok := event.Payload["args"].(map[string]interface{})
+	if !ok {
+		args, _ = event.Payload["parameters"].(map[string]interface{})
+	}
+	command, _ := args["command"].(string)
+	return command
+}
+
+// jsonOutputVersion --output-format json 首次提供的版本，更早的版本只输出纯文本，无法解析出事件
+const jsonOutputVersion = "0.6.0"
+
+// features JSON 输出模式下的能力（结束时一次性输出，不支持流式）
+var features = []adapter.VersionedFeature{
+	{Feature: adapter.FeatureToolEvents, MinVersion: jsonOutputVersion},
+	{Feature: adapter.FeatureUsage, MinVersion: jsonOutputVersion},
+}
+
+// Detect 通过 gemini --version 探测版本
+func (a *Adapter) Detect(ctx context.Context) (*adapter.Capabilities, error) {
+	return adapter.DetectVersioned(ctx, features, "gemini", "--version")
+}
+
 // CollectArtifacts 收集产物
 func (a *Adapter) CollectArtifacts(ctx context.Context, workspaceDir string) (*adapter.Artifacts, error) {
 	return &adapter.Artifacts{
@@ -3,9 +3,13 @@ package gemini
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"slices"
 	"testing"
 
 	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
 )
 
 func TestGeminiAdapterName(t *testing.T) {
@@ -176,3 +180,78 @@ func TestGeminiEventMapping(t *testing.T) {
 		t.Errorf("content = %v, want 'I will help you'", payload["content"])
 	}
 }
+
+func TestGeminiAdapterExtractUsage(t *testing.T) {
+	a := New()
+
+	event, _ := a.ParseEvent(`{"type":"done","model":"gemini-2.5-pro","stats":{"input_tokens":1200,"output_tokens":300,"cached":800}}`)
+	usage := a.ExtractUsage(event)
+	if usage == nil {
+		t.Fatal("ExtractUsage() = nil, want usage")
+	}
+	want := adapter.Usage{Model: "gemini-2.5-pro", InputTokens: 1200, OutputTokens: 300, CacheReadTokens: 800}
+	if *usage != want {
+		t.Errorf("ExtractUsage() = %+v, want %+v", *usage, want)
+	}
+
+	event, _ = a.ParseEvent(`{"type":"done"}`)
+	if usage := a.ExtractUsage(event); usage != nil {
+		t.Errorf("ExtractUsage(no stats) = %+v, want nil", usage)
+	}
+}
+
+// TestGeminiAdapterDetect JSON 输出之前的版本不支持事件解析相关能力
+func TestGeminiAdapterDetect(t *testing.T) {
+	a := New()
+	for _, tt := range []struct {
+		output string
+		want   int
+	}{
+		{"0.6.1\n", 2},
+		{"0.5.5\n", 0},
+	} {
+		ctx := adapter.WithCommandRunner(context.Background(), func(ctx context.Context, argv []string) ([]byte, error) {
+			return []byte(tt.output), nil
+		})
+		caps, err := a.Detect(ctx)
+		if err != nil {
+			t.Fatalf("Detect(%q) error = %v", tt.output, err)
+		}
+		if len(caps.Features) != tt.want || caps.Has(adapter.FeatureStreaming) {
+			t.Errorf("Detect(%q) = %+v, want %d features", tt.output, caps, tt.want)
+		}
+	}
+
+	ctx := adapter.WithCommandRunner(context.Background(), func(ctx context.Context, argv []string) ([]byte, error) {
+		return nil, errors.New("executable file not found")
+	})
+	caps, err := a.Detect(ctx)
+	if err == nil || caps == nil || caps.Version != "" || len(caps.Features) != 0 {
+		t.Errorf("Detect(missing cli) = %+v, %v", caps, err)
+	}
+}
+
+func TestGeminiAdapterPolicyArgs(t *testing.T) {
+	a := New()
+	policy, _ := secpolicy.Evaluate(nil, secpolicy.Builtin("standard"))
+
+	// 标准策略：命令需审批（由 NodeManager 审批，CLI 放行），没有被禁止的权限时不启用沙箱
+	args, unenforced := a.PolicyArgs(policy)
+	want := []string{"--allowed-tools", "read_file,read_many_files,glob,search_file_content,list_directory,write_file,replace,web_fetch,google_web_search,run_shell_command"}
+	if !slices.Equal(args, want) || len(unenforced) != 0 {
+		t.Errorf("PolicyArgs() = %v, %v", args, unenforced)
+	}
+
+	policy, _ = secpolicy.Evaluate(&model.SecurityConfig{DeniedPermissions: []string{"file_read", "network_outbound"}})
+	args, unenforced = a.PolicyArgs(policy)
+	if !slices.Contains(args, "--sandbox") || !slices.Equal(unenforced, []string{secpolicy.PermissionFileRead}) {
+		t.Errorf("PolicyArgs() = %v, %v", args, unenforced)
+	}
+	if a.ToolPermission("run_shell_command") != secpolicy.PermissionCommandExecute {
+		t.Error("run_shell_command 应对应 command_execute")
+	}
+	event, _ := a.ParseEvent(`{"type":"tool_call","tool":"run_shell_command","args":{"command":"rm -rf /"}}`)
+	if command := a.ToolCommand(event); command != "rm -rf /" {
+		t.Errorf("ToolCommand() = %q", command)
+	}
+}
@@ -0,0 +1,265 @@
+// Package generic 实现由配置驱动的通用 Adapter
+//
+// 自定义 Agent 类型在 API Server 上以 YAML/JSON 声明启动命令模板与事件提取规则
+// （model.GenericAdapterSpec），NodeManager 找不到内置 Adapter 时据此构造通用 Adapter，
+// 无需编写 Go 代码即可接入任意 Agent CLI。
+package generic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+// Adapter 通用适配器
+type Adapter struct {
+	agentType string
+	image     string
+	spec      model.GenericAdapterSpec
+	args      []*template.Template
+	env       map[string]*template.Template
+	patterns  []*regexp.Regexp // 与 spec.Rules 一一对应（json 模式为 nil）
+}
+
+// templateData 参数与环境变量模板的渲染数据
+type templateData struct {
+	TaskID string
+	Prompt string
+	Model  string
+	Params map[string]string
+}
+
+// New 根据 Agent 类型配置创建通用 Adapter
+func New(agentType, image string, spec model.GenericAdapterSpec) (*Adapter, error) {
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	a := &Adapter{
+		agentType: agentType,
+		image:     image,
+		spec:      spec,
+		env:       make(map[string]*template.Template, len(spec.Env)),
+		patterns:  make([]*regexp.Regexp, len(spec.Rules)),
+	}
+	for _, arg := range spec.Args {
+		a.args = append(a.args, template.Must(template.New("arg").Option("missingkey=zero").Parse(arg)))
+	}
+	for k, v := range spec.Env {
+		a.env[k] = template.Must(template.New(k).Option("missingkey=zero").Parse(v))
+	}
+	if spec.OutputFormat() == model.GenericOutputText {
+		for i, rule := range spec.Rules {
+			a.patterns[i] = regexp.MustCompile(rule.Pattern)
+		}
+	}
+	return a, nil
+}
+
+// Name 返回适配器名称（与 NormalizeAdapterName 的默认规则一致）
+func (a *Adapter) Name() string {
+	return a.agentType + "-v1"
+}
+
+// Validate 验证 AgentConfig
+func (a *Adapter) Validate(agent *adapter.AgentConfig) error {
+	if agent.Type != a.agentType {
+		return fmt.Errorf("agent type mismatch: expected %s, got %s", a.agentType, agent.Type)
+	}
+	return nil
+}
+
+// BuildCommand 按模板渲染命令参数与环境变量，渲染结果为空的参数被丢弃
+func (a *Adapter) BuildCommand(ctx context.Context, spec *adapter.TaskSpec, agent *adapter.AgentConfig) (*adapter.RunConfig, error) {
+	data := templateData{
+		TaskID: spec.ID,
+		Prompt: spec.Prompt,
+		Model:  agent.Model,
+		Params: make(map[string]string, len(agent.Parameters)),
+	}
+	for k, v := range agent.Parameters {
+		data.Params[k] = fmt.Sprint(v)
+	}
+
+	args := make([]string, 0, len(a.args))
+	for i, tmpl := range a.args {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("render args[%d]: %w", i, err)
+		}
+		if buf.Len() > 0 {
+			args = append(args, buf.String())
+		}
+	}
+
+	env := make(map[string]string, len(a.env))
+	for k, tmpl := range a.env {
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, data); err != nil {
+			return nil, fmt.Errorf("render env %s: %w", k, err)
+		}
+		env[k] = buf.String()
+	}
+
+	workingDir := a.spec.WorkingDir
+	if workingDir == "" {
+		workingDir = "/workspace"
+	}
+
+	return &adapter.RunConfig{
+		Image:      a.image,
+		Command:    append([]string(nil), a.spec.Command...),
+		Args:       args,
+		Env:        env,
+		WorkingDir: workingDir,
+	}, nil
+}
+
+// ParseEvent 按规则把一行输出转换为统一事件，未命中规则且未配置默认事件时忽略
+func (a *Adapter) ParseEvent(line string) (*adapter.CanonicalEvent, error) {
+	line = strings.TrimRight(line, "\r\n")
+	if strings.TrimSpace(line) == "" {
+		return nil, nil
+	}
+	if a.spec.OutputFormat() == model.GenericOutputJSON {
+		return a.parseJSON(line), nil
+	}
+	return a.parseText(line), nil
+}
+
+// parseText text 模式：正则匹配，Payload 按捕获组展开
+func (a *Adapter) parseText(line string) *adapter.CanonicalEvent {
+	for i, rule := range a.spec.Rules {
+		re := a.patterns[i]
+		match := re.FindStringSubmatchIndex(line)
+		if match == nil {
+			continue
+		}
+		payload := make(map[string]interface{}, len(rule.Payload))
+		for key, tmpl := range rule.Payload {
+			payload[key] = string(re.ExpandString(nil, tmpl, line, match))
+		}
+		if len(payload) == 0 {
+			payload["content"] = line
+		}
+		return &adapter.CanonicalEvent{Type: adapter.EventType(rule.Type), Payload: payload}
+	}
+	if a.spec.DefaultEvent == "" {
+		return nil
+	}
+	return &adapter.CanonicalEvent{
+		Type:    adapter.EventType(a.spec.DefaultEvent),
+		Payload: map[string]interface{}{"content": line},
+	}
+}
+
+// parseJSON json 模式：字段匹配，Payload 按字段路径取值
+func (a *Adapter) parseJSON(line string) *adapter.CanonicalEvent {
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &raw); err != nil {
+		return nil // 非 JSON 行，忽略
+	}
+	for _, rule := range a.spec.Rules {
+		v, ok := lookup(raw, rule.Field)
+		if !ok || (rule.Value != "" && fmt.Sprint(v) != rule.Value) {
+			continue
+		}
+		if len(rule.Payload) == 0 {
+			return &adapter.CanonicalEvent{Type: adapter.EventType(rule.Type), Payload: raw}
+		}
+		payload := make(map[string]interface{}, len(rule.Payload))
+		for key, path := range rule.Payload {
+			if v, ok := lookup(raw, path); ok {
+				payload[key] = v
+			}
+		}
+		return &adapter.CanonicalEvent{Type: adapter.EventType(rule.Type), Payload: payload}
+	}
+	if a.spec.DefaultEvent == "" {
+		return nil
+	}
+	return &adapter.CanonicalEvent{Type: adapter.EventType(a.spec.DefaultEvent), Payload: raw}
+}
+
+// lookup 按点分路径读取 JSON 对象中的字段
+func lookup(m map[string]interface{}, path string) (interface{}, bool) {
+	var cur interface{} = m
+	for _, key := range strings.Split(path, ".") {
+		obj, ok := cur.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if cur, ok = obj[key]; !ok {
+			return nil, false
+		}
+	}
+	return cur, true
+}
+
+// ExtractUsage 从 run_completed 事件的 Payload 提取用量
+//
+// 规则把 input_tokens、output_tokens、cache_read_tokens、cache_write_tokens、cost_usd、model
+// 映射到 run_completed 事件的 Payload 时上报用量；text 模式捕获的字符串按数字解析。
+func (a *Adapter) ExtractUsage(event *adapter.CanonicalEvent) *adapter.Usage {
+	if event.Type != adapter.EventRunCompleted {
+		return nil
+	}
+	usage := &adapter.Usage{
+		InputTokens:      int64(number(event.Payload["input_tokens"])),
+		OutputTokens:     int64(number(event.Payload["output_tokens"])),
+		CacheReadTokens:  int64(number(event.Payload["cache_read_tokens"])),
+		CacheWriteTokens: int64(number(event.Payload["cache_write_tokens"])),
+		CostUSD:          number(event.Payload["cost_usd"]),
+	}
+	usage.Model, _ = event.Payload["model"].(string)
+	if usage.IsZero() {
+		return nil
+	}
+	return usage
+}
+
+// number 将 JSON 数值或数字字符串转换为 float64（无法解析时为 0）
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case string:
+		f, _ := strconv.ParseFloat(strings.ReplaceAll(n, ",", ""), 64)
+		return f
+	}
+	return 0
+}
+
+// Detect 按事件规则推断能力，不探测版本
+//
+// 自定义 CLI 的版本参数各不相同，贸然执行可能进入交互模式，因此版本始终为空。
+// 逐行解析总是流式的；规则产生工具事件时支持 tool_events，产生 run_completed 时支持 usage。
+func (a *Adapter) Detect(ctx context.Context) (*adapter.Capabilities, error) {
+	caps := &adapter.Capabilities{Features: []string{adapter.FeatureStreaming}}
+	types := map[string]bool{a.spec.DefaultEvent: true}
+	for _, rule := range a.spec.Rules {
+		types[rule.Type] = true
+	}
+	if types[string(adapter.EventToolUseStart)] || types[string(adapter.EventToolResult)] {
+		caps.Features = append(caps.Features, adapter.FeatureToolEvents)
+	}
+	if types[string(adapter.EventRunCompleted)] {
+		caps.Features = append(caps.Features, adapter.FeatureUsage)
+	}
+	return caps, nil
+}
+
+// CollectArtifacts 收集产物
+func (a *Adapter) CollectArtifacts(ctx context.Context, workspaceDir string) (*adapter.Artifacts, error) {
+	return &adapter.Artifacts{
+		EventsFile: filepath.Join(workspaceDir, ".agent", "events.jsonl"),
+	}, nil
+}
@@ -0,0 +1,134 @@
+package generic
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+func textSpec() model.GenericAdapterSpec {
+	return model.GenericAdapterSpec{
+		Command: []string{"aichat"},
+		Args:    []string{"--model={{.Model}}", "{{.Params.role}}", "{{.Prompt}}"},
+		Env:     map[string]string{"AICHAT_TASK": "{{.TaskID}}"},
+		Rules: []model.GenericEventRule{
+			{Type: "file_write", Pattern: `^Wrote (?P<path>\S+)$`, Payload: map[string]string{"path": "${path}"}},
+			{Type: "run_completed", Pattern: `^Done: (\d+) in, (\d+) out, \$([0-9.]+)$`,
+				Payload: map[string]string{"input_tokens": "$1", "output_tokens": "$2", "cost_usd": "$3"}},
+		},
+		DefaultEvent: "message",
+	}
+}
+
+func TestGenericAdapterNew(t *testing.T) {
+	a, err := New("aichat", "runners/aichat:latest", textSpec())
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if a.Name() != "aichat-v1" {
+		t.Errorf("Name() = %v, want aichat-v1", a.Name())
+	}
+	if err := a.Validate(&adapter.AgentConfig{Type: "aichat"}); err != nil {
+		t.Errorf("Validate(aichat) error = %v", err)
+	}
+	if err := a.Validate(&adapter.AgentConfig{Type: "claude"}); err == nil {
+		t.Error("Validate(claude) expected error")
+	}
+
+	if _, err := New("bad", "img", model.GenericAdapterSpec{Command: []string{"x"}, Rules: []model.GenericEventRule{{Type: "message", Pattern: "("}}}); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestGenericAdapterBuildCommand(t *testing.T) {
+	a, _ := New("aichat", "runners/aichat:latest", textSpec())
+
+	cfg, err := a.BuildCommand(context.Background(),
+		&adapter.TaskSpec{ID: "run-1", Prompt: "Fix the bug"},
+		&adapter.AgentConfig{Type: "aichat", Model: "deepseek-chat"})
+	if err != nil {
+		t.Fatalf("BuildCommand() error = %v", err)
+	}
+	if cfg.Image != "runners/aichat:latest" || cfg.WorkingDir != "/workspace" {
+		t.Errorf("unexpected run config: %+v", cfg)
+	}
+	// 未设置的参数渲染为空并被丢弃
+	want := []string{"--model=deepseek-chat", "Fix the bug"}
+	if !reflect.DeepEqual(cfg.Args, want) {
+		t.Errorf("Args = %v, want %v", cfg.Args, want)
+	}
+	if cfg.Env["AICHAT_TASK"] != "run-1" {
+		t.Errorf("Env = %v", cfg.Env)
+	}
+}
+
+func TestGenericAdapterParseText(t *testing.T) {
+	a, _ := New("aichat", "img", textSpec())
+
+	event, _ := a.ParseEvent("Wrote src/main.go")
+	if event == nil || event.Type != adapter.EventFileWrite || event.Payload["path"] != "src/main.go" {
+		t.Errorf("unexpected file_write event: %+v", event)
+	}
+
+	event, _ = a.ParseEvent("thinking about it")
+	if event == nil || event.Type != adapter.EventMessage || event.Payload["content"] != "thinking about it" {
+		t.Errorf("unexpected default event: %+v", event)
+	}
+
+	if event, _ := a.ParseEvent("  "); event != nil {
+		t.Errorf("expected nil for blank line, got %+v", event)
+	}
+
+	event, _ = a.ParseEvent("Done: 1200 in, 300 out, $0.02")
+	usage := a.ExtractUsage(event)
+	if usage == nil || usage.InputTokens != 1200 || usage.OutputTokens != 300 || usage.CostUSD != 0.02 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+}
+
+func TestGenericAdapterParseJSON(t *testing.T) {
+	spec := model.GenericAdapterSpec{
+		Command: []string{"agent"},
+		Output:  model.GenericOutputJSON,
+		Rules: []model.GenericEventRule{
+			{Type: "tool_use_start", Field: "event.kind", Value: "tool", Payload: map[string]string{"tool": "event.name"}},
+			{Type: "error", Field: "error"},
+		},
+	}
+	a, err := New("agent", "img", spec)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	event, _ := a.ParseEvent(`{"event":{"kind":"tool","name":"read_file"}}`)
+	if event == nil || event.Type != adapter.EventToolUseStart || event.Payload["tool"] != "read_file" {
+		t.Errorf("unexpected tool event: %+v", event)
+	}
+
+	event, _ = a.ParseEvent(`{"error":"boom","code":1}`)
+	if event == nil || event.Type != adapter.EventError || event.Payload["code"] != float64(1) {
+		t.Errorf("unexpected error event: %+v", event)
+	}
+
+	// 未命中规则且无默认事件、非 JSON 行均忽略
+	for _, line := range []string{`{"event":{"kind":"text"}}`, "plain text"} {
+		if event, _ := a.ParseEvent(line); event != nil {
+			t.Errorf("ParseEvent(%q) = %+v, want nil", line, event)
+		}
+	}
+}
+
+func TestGenericAdapterDetect(t *testing.T) {
+	a, _ := New("aichat", "img", textSpec())
+	caps, err := a.Detect(context.Background())
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	want := []string{adapter.FeatureStreaming, adapter.FeatureUsage}
+	if caps.Version != "" || !reflect.DeepEqual(caps.Features, want) {
+		t.Errorf("Detect() = %+v, want features %v", caps, want)
+	}
+}
@@ -0,0 +1,17 @@
+package adapter
+
+// ============================================================================
+// 优雅停止
+// ============================================================================
+
+// DefaultInterruptSignal Adapter 未实现 Interrupter 时请求 Agent 停止所发送的信号
+const DefaultInterruptSignal = "INT"
+
+// Interrupter 可选接口：以其他信号请求 Agent 停止的 Adapter
+//
+// Run 被取消或超时终止时，NodeManager 先向 Agent 发送停止信号，等待宽限期让 Agent 保存会话、
+// 输出最后的事件，宽限期内未退出时再强制终止（SIGKILL）。
+type Interrupter interface {
+	// InterruptSignal 停止信号名（不含 SIG 前缀，如 INT、TERM）
+	InterruptSignal() string
+}
@@ -0,0 +1,83 @@
+package adapter
+
+import (
+	"strings"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
+)
+
+// ============================================================================
+// 安全策略：平台权限换算为 CLI 参数
+// ============================================================================
+
+// PolicyEnforcer 可选接口：能将安全策略换算为 CLI 参数的 Adapter
+//
+// NodeManager 将 PolicyArgs 追加到启动参数：被禁止的权限由 CLI 拒绝执行；需审批的权限对应的工具
+// 由 CLI 放行，Agent 调用时 NodeManager 暂停执行等待人工审批。CLI 无法执行的禁止（unenforced）
+// 以及未实现该接口的 Adapter，由 NodeManager 在 Agent 调用对应工具时终止 Agent。
+type PolicyEnforcer interface {
+	// PolicyArgs 按权限级别生成的 CLI 参数，以及 CLI 无法执行的被禁止权限
+	PolicyArgs(policy *secpolicy.Policy) (args []string, unenforced []string)
+
+	// EventTool 工具调用事件中的工具名（不是工具调用时返回空）
+	EventTool(event *CanonicalEvent) string
+
+	// ToolPermission 工具对应的平台权限（与权限无关的工具返回空）
+	ToolPermission(tool string) string
+}
+
+// PolicyDenialReporter 可选接口：能从输出中识别 CLI 拒绝执行的工具调用的 Adapter
+type PolicyDenialReporter interface {
+	// DeniedTools 事件中 CLI 拒绝执行的工具名
+	DeniedTools(event *CanonicalEvent) []string
+}
+
+// EventPermission 按事件类型识别的平台权限（文件、命令事件；其他事件返回空）
+func EventPermission(event *CanonicalEvent) string {
+	switch event.Type {
+	case EventFileRead:
+		return secpolicy.PermissionFileRead
+	case EventFileWrite:
+		return secpolicy.PermissionFileWrite
+	case EventFileDelete:
+		return secpolicy.PermissionFileDelete
+	case EventCommand:
+		return secpolicy.PermissionCommandExecute
+	}
+	return ""
+}
+
+// PolicyTools 级别为 level 的权限对应的工具（按 Permissions 顺序，tools 为权限到工具名的映射）
+func PolicyTools(policy *secpolicy.Policy, level model.ToolPermissionLevel, tools map[string][]string) []string {
+	var out []string
+	for _, perm := range secpolicy.Permissions {
+		if policy.Level(perm) == level {
+			out = append(out, tools[perm]...)
+		}
+	}
+	return out
+}
+
+// CommandExtractor 可选接口：能从工具调用事件中取出 Agent 执行的命令行的 Adapter
+type CommandExtractor interface {
+	// ToolCommand 执行命令的工具调用中的完整命令行（不是执行命令时返回空）
+	ToolCommand(event *CanonicalEvent) string
+}
+
+// CommandLine command 事件中的完整命令行（{"command": "git", "args": ["status"]}；其他事件返回空）
+func CommandLine(event *CanonicalEvent) string {
+	if event.Type != EventCommand {
+		return ""
+	}
+	command, _ := event.Payload["command"].(string)
+	args, _ := event.Payload["args"].([]interface{})
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, command)
+	for _, arg := range args {
+		if s, ok := arg.(string); ok {
+			parts = append(parts, s)
+		}
+	}
+	return strings.TrimSpace(strings.Join(parts, " "))
+}
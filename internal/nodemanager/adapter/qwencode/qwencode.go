@@ -203,6 +203,9 @@ func extractPayload(eventType string, raw map[string]interface{}) map[string]int
 		if usage, ok := raw["usage"].(map[string]interface{}); ok {
 			payload["usage"] = usage
 		}
+		if cost, ok := raw["total_cost_usd"].(float64); ok {
+			payload["total_cost_usd"] = cost
+		}
 	case "tool_use", "tool_call":
 		payload["tool"] = raw["name"]
 		payload["input"] = raw["input"]
@@ -219,6 +222,49 @@ func extractPayload(eventType string, raw map[string]interface{}) map[string]int
 	return payload
 }
 
+// ExtractUsage 从 result 事件提取本次调用的 Token 用量
+//
+// usage 字段兼容 Anthropic（input_tokens/output_tokens）与 OpenAI（prompt_tokens/completion_tokens）两种命名：
+//
+//	{"type": "result", "usage": {"input_tokens": 1200, "output_tokens": 300, "cache_read_input_tokens": 800}, "total_cost_usd": 0}
+func (a *Adapter) ExtractUsage(event *adapter.CanonicalEvent) *adapter.Usage {
+	if event.Type != adapter.EventRunCompleted {
+		return nil
+	}
+	raw, ok := event.Payload["usage"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	usage := &adapter.Usage{
+		InputTokens:      int64(adapter.NumberField(raw, "input_tokens", "prompt_tokens")),
+		OutputTokens:     int64(adapter.NumberField(raw, "output_tokens", "completion_tokens")),
+		CacheReadTokens:  int64(adapter.NumberField(raw, "cache_read_input_tokens", "cached_tokens")),
+		CacheWriteTokens: int64(adapter.NumberField(raw, "cache_creation_input_tokens")),
+		CostUSD:          adapter.NumberField(event.Payload, "total_cost_usd"),
+	}
+	if usage.IsZero() {
+		return nil
+	}
+	return usage
+}
+
+// ResumeArgs 追问时继续工作目录下最近一次会话（qwen --continue）
+func (a *Adapter) ResumeArgs() []string {
+	return []string{"--continue"}
+}
+
+// features stream-json 模式下的能力
+var features = []adapter.VersionedFeature{
+	{Feature: adapter.FeatureStreaming},
+	{Feature: adapter.FeatureToolEvents},
+	{Feature: adapter.FeatureUsage},
+}
+
+// Detect 通过 qwen --version 探测版本
+func (a *Adapter) Detect(ctx context.Context) (*adapter.Capabilities, error) {
+	return adapter.DetectVersioned(ctx, features, "qwen", "--version")
+}
+
 // CollectArtifacts 收集产物
 func (a *Adapter) CollectArtifacts(ctx context.Context, workspaceDir string) (*adapter.Artifacts, error) {
 	return &adapter.Artifacts{
@@ -245,3 +245,28 @@ func TestAdapter_CollectArtifacts(t *testing.T) {
 		t.Errorf("EventsFile = %v, want %v", artifacts.EventsFile, expected)
 	}
 }
+
+func TestAdapter_ExtractUsage(t *testing.T) {
+	a := New()
+
+	event, _ := a.ParseEvent(`{"type":"result","subtype":"success","result":"ok","usage":{"input_tokens":1200,"output_tokens":300,"cache_read_input_tokens":800},"total_cost_usd":0.004}`)
+	usage := a.ExtractUsage(event)
+	if usage == nil {
+		t.Fatal("ExtractUsage() = nil, want usage")
+	}
+	want := adapter.Usage{InputTokens: 1200, OutputTokens: 300, CacheReadTokens: 800, CostUSD: 0.004}
+	if *usage != want {
+		t.Errorf("ExtractUsage() = %+v, want %+v", *usage, want)
+	}
+
+	// OpenAI 兼容命名
+	event, _ = a.ParseEvent(`{"type":"result","usage":{"prompt_tokens":10,"completion_tokens":20}}`)
+	if usage := a.ExtractUsage(event); usage == nil || usage.InputTokens != 10 || usage.OutputTokens != 20 {
+		t.Errorf("ExtractUsage(openai) = %+v, want input=10 output=20", usage)
+	}
+
+	event, _ = a.ParseEvent(`{"type":"assistant","message":{"content":[{"type":"text","text":"hi"}]}}`)
+	if usage := a.ExtractUsage(event); usage != nil {
+		t.Errorf("ExtractUsage(assistant) = %+v, want nil", usage)
+	}
+}
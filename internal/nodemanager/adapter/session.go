@@ -0,0 +1,35 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// 持久会话：追问 Run 继续上一轮 CLI 会话
+// ============================================================================
+
+// SessionResumer 可选接口：能在同一执行环境中继续最近一次 CLI 会话的 Adapter
+//
+// 追问 Run 复用上一轮保留的执行容器时，NodeManager 将 ResumeArgs 追加到启动参数，
+// CLI 从自身保存的会话历史继续。未实现或会话容器已失效时，NodeManager 用 FormatConversation
+// 将对话历史拼入提示词。
+type SessionResumer interface {
+	// ResumeArgs 继续工作目录下最近一次会话的 CLI 参数
+	ResumeArgs() []string
+}
+
+// FormatConversation 将之前的对话历史与本轮提示词拼接为一个提示词（CLI 无法继续会话时使用）
+func FormatConversation(history []ConversationMessage, prompt string) string {
+	if len(history) == 0 {
+		return prompt
+	}
+	var b strings.Builder
+	b.WriteString("以下是此前的对话记录：\n")
+	for _, m := range history {
+		fmt.Fprintf(&b, "\n[%s]\n%s\n", m.Role, strings.TrimSpace(m.Content))
+	}
+	b.WriteString("\n请在此基础上继续，回复用户的最新消息：\n\n")
+	b.WriteString(prompt)
+	return b.String()
+}
@@ -0,0 +1,28 @@
+package adapter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// 技能包：Run 启动前安装到 Agent 的技能目录
+// ============================================================================
+
+// DefaultSkillsDir 未实现 SkillsLoader 的 Adapter 的技能安装目录（相对 HOME）
+const DefaultSkillsDir = ".agents-admin/skills"
+
+// SkillsLoader 可选接口：CLI 自动从固定目录加载技能的 Adapter
+//
+// NodeManager 将技能包安装到 SkillsDir/<name>/。未实现时安装到 DefaultSkillsDir，
+// 并用 SkillsPromptHint 在提示词中说明技能的位置。
+type SkillsLoader interface {
+	// SkillsDir 技能目录（相对 HOME）
+	SkillsDir() string
+}
+
+// SkillsPromptHint 不能自动加载技能的 Agent 的提示词说明
+func SkillsPromptHint(names []string) string {
+	return fmt.Sprintf("\n\n可用的技能已安装在 ~/%s/ 下（%s），每个技能目录中的 SKILL.md 说明了用法，需要时请先阅读。",
+		DefaultSkillsDir, strings.Join(names, "、"))
+}
@@ -0,0 +1,57 @@
+package adapter
+
+// ============================================================================
+// Token 用量与费用
+// ============================================================================
+
+// Usage Agent CLI 报告的 Token 用量与费用
+//
+// 数值为增量：NodeManager 每提取到一次用量就上报一个 usage 事件，API Server 按 Run 累加。
+// 因此 Adapter 只应从不会重复计数的事件中提取（通常是一次调用结束时的 result 事件）。
+type Usage struct {
+	Model            string  // 模型名称（CLI 未报告时为空）
+	InputTokens      int64   // 输入 Token（不含缓存命中）
+	OutputTokens     int64   // 输出 Token
+	CacheReadTokens  int64   // 缓存读取 Token
+	CacheWriteTokens int64   // 缓存写入 Token
+	CostUSD          float64 // 费用（美元，CLI 未报告时为 0）
+}
+
+// IsZero 判断用量是否为空（各项均为 0）
+func (u *Usage) IsZero() bool {
+	return u.InputTokens == 0 && u.OutputTokens == 0 && u.CacheReadTokens == 0 &&
+		u.CacheWriteTokens == 0 && u.CostUSD == 0
+}
+
+// Payload usage 事件的 Payload
+func (u *Usage) Payload() map[string]interface{} {
+	payload := map[string]interface{}{
+		"input_tokens":       u.InputTokens,
+		"output_tokens":      u.OutputTokens,
+		"cache_read_tokens":  u.CacheReadTokens,
+		"cache_write_tokens": u.CacheWriteTokens,
+		"cost_usd":           u.CostUSD,
+	}
+	if u.Model != "" {
+		payload["model"] = u.Model
+	}
+	return payload
+}
+
+// UsageReporter 可选接口：能从输出事件中提取 Token 用量的 Adapter
+//
+// NodeManager 对每个解析出的事件调用 ExtractUsage，返回非 nil 时紧随其后上报一个 usage 事件。
+type UsageReporter interface {
+	// ExtractUsage 从事件中提取用量，事件不含用量时返回 nil
+	ExtractUsage(event *CanonicalEvent) *Usage
+}
+
+// NumberField 读取 JSON 对象中第一个存在的数值字段（均不存在时返回 0）
+func NumberField(m map[string]interface{}, keys ...string) float64 {
+	for _, key := range keys {
+		if v, ok := m[key].(float64); ok {
+			return v
+		}
+	}
+	return 0
+}
@@ -0,0 +1,111 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/nodemanager/adapter/generic"
+	"agents-admin/internal/shared/model"
+)
+
+// resolveAdapter 获取 Agent 类型对应的 Adapter
+//
+// 优先使用注册的内置 Adapter；找不到时从 API Server 获取自定义 Agent 类型，
+// 按其通用适配器配置构造 Adapter（响应经 ETag 缓存，配置未变化时不重复传输）。
+func (nm *NodeManager) resolveAdapter(ctx context.Context, agentType string) (adapter.Adapter, error) {
+	adapterName := NormalizeAdapterName(agentType)
+	if a, ok := nm.adapters.Get(adapterName); ok {
+		return a, nil
+	}
+
+	status, body, err := nm.getCached(ctx, nm.config.APIServerURL+"/api/v1/agent-types/"+url.PathEscape(agentType))
+	if err != nil {
+		return nil, fmt.Errorf("找不到适配器: %s (获取 Agent 类型失败: %v)", adapterName, err)
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("找不到适配器: %s (原始类型: %s)", adapterName, agentType)
+	}
+
+	var at model.AgentTypeConfig
+	if err := json.Unmarshal(body, &at); err != nil {
+		return nil, fmt.Errorf("解析 Agent 类型 %s 失败: %v", agentType, err)
+	}
+	if at.Adapter == nil {
+		return nil, fmt.Errorf("找不到适配器: %s (Agent 类型 %s 未配置通用适配器)", adapterName, agentType)
+	}
+	a, err := generic.New(agentType, at.Image, *at.Adapter)
+	if err != nil {
+		return nil, fmt.Errorf("Agent 类型 %s 的适配器配置无效: %v", agentType, err)
+	}
+	return a, nil
+}
+
+// adapterDetectInterval 重新探测适配器的间隔（节点上的 CLI 升级后无需重启 NodeManager）
+const adapterDetectInterval = 30 * time.Minute
+
+// adapterDetectLoop 定期重新探测适配器版本与能力
+func (nm *NodeManager) adapterDetectLoop(ctx context.Context) {
+	ticker := time.NewTicker(adapterDetectInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nm.detectAdapters(ctx)
+		}
+	}
+}
+
+// detectAdapters 探测已注册 Adapter 的 CLI 版本与能力，结果随心跳上报
+//
+// 探测在节点宿主机上执行；CLI 只安装在 Agent 镜像中时探测失败，
+// 此时只上报不限版本的静态能力，版本为空。
+// 自定义 Agent 类型的通用适配器按需构造，不在上报范围内。
+func (nm *NodeManager) detectAdapters(ctx context.Context) {
+	names := nm.adapters.List()
+	sort.Strings(names)
+
+	infos := make([]model.AdapterInfo, 0, len(names))
+	for _, name := range names {
+		a, _ := nm.adapters.Get(name)
+		info := model.AdapterInfo{Name: name}
+		caps, err := a.Detect(ctx)
+		if err != nil {
+			log.Printf("[nodemanager.adapter.detect.failed] adapter=%s error=%v", name, err)
+		}
+		if caps != nil {
+			info.Version, info.Features = caps.Version, caps.Features
+		}
+		log.Printf("[nodemanager.adapter.detected] adapter=%s version=%s features=%v", name, info.Version, info.Features)
+		infos = append(infos, info)
+	}
+
+	nm.mu.Lock()
+	nm.adapterInfo = infos
+	nm.mu.Unlock()
+}
+
+// adapterInfos 返回最近一次适配器探测结果
+func (nm *NodeManager) adapterInfos() []model.AdapterInfo {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.adapterInfo
+}
+
+// adaptersToProto 将适配器探测结果转换为 gRPC 心跳字段
+func adaptersToProto(infos []model.AdapterInfo) []*nodev1.AdapterInfo {
+	out := make([]*nodev1.AdapterInfo, 0, len(infos))
+	for _, info := range infos {
+		out = append(out, &nodev1.AdapterInfo{Name: info.Name, Version: info.Version, Features: info.Features})
+	}
+	return out
+}
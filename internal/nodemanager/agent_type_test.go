@@ -0,0 +1,79 @@
+package nodemanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/nodemanager/adapter/aider"
+)
+
+// TestResolveAdapter 内置 Adapter 优先，自定义类型按通用适配器配置构造
+func TestResolveAdapter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/agent-types/aichat":
+			w.Write([]byte(`{"id":"aichat","image":"runners/aichat:latest",` +
+				`"adapter":{"command":["aichat"],"args":["{{.Prompt}}"],"default_event":"message"}}`))
+		case "/api/v1/agent-types/plain":
+			w.Write([]byte(`{"id":"plain","image":"runners/plain:latest"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	registry := adapter.NewRegistry()
+	registry.Register(aider.New())
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client(), adapters: registry}
+	ctx := context.Background()
+
+	a, err := nm.resolveAdapter(ctx, "aider")
+	if err != nil || a.Name() != "aider-v1" {
+		t.Fatalf("aider: adapter = %v, err = %v", a, err)
+	}
+
+	a, err = nm.resolveAdapter(ctx, "aichat")
+	if err != nil {
+		t.Fatalf("aichat: err = %v", err)
+	}
+	cfg, err := a.BuildCommand(ctx, &adapter.TaskSpec{ID: "run-1", Prompt: "hi"}, &adapter.AgentConfig{Type: "aichat"})
+	if err != nil || cfg.Image != "runners/aichat:latest" || cfg.Command[0] != "aichat" || cfg.Args[0] != "hi" {
+		t.Errorf("aichat: cfg = %+v, err = %v", cfg, err)
+	}
+
+	for _, agentType := range []string{"plain", "unknown"} {
+		if _, err := nm.resolveAdapter(ctx, agentType); err == nil || !strings.Contains(err.Error(), "找不到适配器") {
+			t.Errorf("%s: err = %v", agentType, err)
+		}
+	}
+}
+
+// TestDetectAdapters 探测结果按名称排序并随心跳上报
+func TestDetectAdapters(t *testing.T) {
+	registry := adapter.NewRegistry()
+	registry.Register(aider.New())
+	registry.Register(&mockAdapter{name: "mock-v1"})
+	nm := &NodeManager{adapters: registry}
+
+	ctx := adapter.WithCommandRunner(context.Background(), func(ctx context.Context, argv []string) ([]byte, error) {
+		return []byte("aider 0.86.1\n"), nil
+	})
+	nm.detectAdapters(ctx)
+
+	infos := nm.adapterInfos()
+	if len(infos) != 2 || infos[0].Name != "aider-v1" || infos[1].Name != "mock-v1" {
+		t.Fatalf("adapterInfos() = %+v", infos)
+	}
+	if infos[0].Version != "0.86.1" || !infos[0].HasFeature(adapter.FeatureUsage) || infos[0].HasFeature(adapter.FeatureToolEvents) {
+		t.Errorf("aider = %+v", infos[0])
+	}
+
+	pb := adaptersToProto(infos)
+	if len(pb) != 2 || pb[1].Version != "1.0.0" || pb[1].Features[0] != adapter.FeatureStreaming {
+		t.Errorf("adaptersToProto() = %+v", pb)
+	}
+}
@@ -0,0 +1,95 @@
+// Package nodemanager API 资源客户端缓存
+//
+// NodeManager 在轮询模式下会反复获取 Run 详情、节点任务列表和 Agent 实例信息。
+// apiCache 按 URL 缓存最近一次 200 响应体及其 ETag，后续请求携带 If-None-Match，
+// 服务端返回 304 时直接复用缓存内容，避免重复传输与解析未变化的资源。
+package nodemanager
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// defaultAPICacheEntries 缓存的最大 URL 数
+const defaultAPICacheEntries = 256
+
+// apiCacheEntry 单个 URL 的缓存
+type apiCacheEntry struct {
+	etag string
+	body []byte
+}
+
+// apiCache 基于 ETag 的 GET 响应缓存（超过容量时淘汰最早写入的条目）
+type apiCache struct {
+	mu      sync.Mutex
+	max     int
+	entries map[string]*apiCacheEntry
+	order   []string // 写入顺序
+}
+
+// newAPICache 创建缓存
+func newAPICache(max int) *apiCache {
+	return &apiCache{max: max, entries: make(map[string]*apiCacheEntry)}
+}
+
+func (c *apiCache) get(url string) *apiCacheEntry {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.entries[url]
+}
+
+func (c *apiCache) put(url, etag string, body []byte) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.entries[url]; !exists {
+		c.order = append(c.order, url)
+		for len(c.order) > c.max {
+			delete(c.entries, c.order[0])
+			c.order = c.order[1:]
+		}
+	}
+	c.entries[url] = &apiCacheEntry{etag: etag, body: body}
+}
+
+// getCached 发送带 If-None-Match 的 GET 请求
+//
+// 返回状态码与响应体：服务端返回 304 时视为 200 并返回缓存内容；
+// 200 响应携带 ETag 时写入缓存。未初始化缓存时退化为普通 GET。
+func (nm *NodeManager) getCached(ctx context.Context, url string) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return 0, nil, err
+	}
+	cached := nm.apiCache.get(url)
+	if cached != nil {
+		req.Header.Set("If-None-Match", cached.etag)
+	}
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return http.StatusOK, cached.body, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		if etag := resp.Header.Get("ETag"); etag != "" {
+			nm.apiCache.put(url, etag, body)
+		}
+	}
+	return resp.StatusCode, body, nil
+}
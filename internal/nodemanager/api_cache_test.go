@@ -0,0 +1,54 @@
+package nodemanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestGetCached_ConditionalRequest 第二次请求携带 If-None-Match，304 时复用缓存
+func TestGetCached_ConditionalRequest(t *testing.T) {
+	var conditional, full int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			conditional++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		full++
+		w.Write([]byte(`{"id":"run-1","status":"queued"}`))
+	}))
+	defer srv.Close()
+
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client(), apiCache: newAPICache(8)}
+	for i := 0; i < 3; i++ {
+		run, err := nm.fetchRunByID(context.Background(), "run-1")
+		if err != nil || run == nil || run["status"] != "queued" {
+			t.Fatalf("第 %d 次: run = %v, err = %v", i, run, err)
+		}
+	}
+	if full != 1 || conditional != 2 {
+		t.Errorf("full = %d, conditional = %d", full, conditional)
+	}
+}
+
+func TestAPICache_Eviction(t *testing.T) {
+	c := newAPICache(2)
+	c.put("a", `"1"`, []byte("a"))
+	c.put("b", `"1"`, []byte("b"))
+	c.put("a", `"2"`, []byte("a2")) // 更新不改变淘汰顺序
+	c.put("c", `"1"`, []byte("c"))
+	if c.get("a") != nil {
+		t.Error("最早写入的条目应被淘汰")
+	}
+	if e := c.get("b"); e == nil || string(e.body) != "b" {
+		t.Errorf("b = %+v", e)
+	}
+	var nilCache *apiCache
+	nilCache.put("x", `"1"`, nil)
+	if nilCache.get("x") != nil {
+		t.Error("nil 缓存应始终未命中")
+	}
+}
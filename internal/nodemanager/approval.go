@@ -0,0 +1,187 @@
+// Package nodemanager Run 人工审批（HITL）
+//
+// Agent 需要人工审批时 NodeManager 暂停执行，等待 API Server 下发审批决定：
+//  1. 触发：Adapter 解析出 approval_required 事件，或 Agent 调用了任务 security.require_approval
+//     中列出的工具（按 tool_use_start 事件的工具名或事件类型匹配，如 run_shell_command、command），
+//     或调用了安全策略中需审批的权限对应的工具（见 security_policy.go），或执行了危险命令（见 command_filter.go）
+//  2. 暂停：docker 后端冻结实例容器，process 后端向进程组发送 SIGSTOP；暂停期间不再读取 Agent 输出，
+//     上报带 approval_id 的 approval_required 事件，API Server 据此创建 ApprovalRequest
+//  3. 下发：心跳携带等待中的审批 ID（pending_approvals），已处理的审批随心跳指令 approvals 返回
+//  4. 恢复：approved 时恢复执行；rejected / expired 时恢复后终止 Agent，Run 以 cancelled 结束
+//
+// 暂停通过 runPauser 与用户暂停（pause.go）共用：审批通过时如 Run 仍被用户暂停，执行目标保持冻结。
+// Run 在等待期间被取消或超时终止时同样先恢复执行目标，避免容器保持冻结。
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+// defaultApprovalTimeoutSeconds 审批请求的默认有效期（Adapter 未指定 timeout_seconds 时）
+const defaultApprovalTimeoutSeconds = 1800
+
+// ParseApprovalTools 从任务快照中解析需要人工审批的工具（snapshot.require_approval）
+func ParseApprovalTools(snapshot *model.RunSnapshot) []string {
+	if snapshot.RequireApproval == nil {
+		return nil
+	}
+	tools := make([]string, 0, len(snapshot.RequireApproval))
+	for _, name := range snapshot.RequireApproval {
+		if name != "" {
+			tools = append(tools, name)
+		}
+	}
+	return tools
+}
+
+// approvalGate 单个 Run 的审批闸门
+type approvalGate struct {
+	nm        *NodeManager
+	runID     string
+	pauser    *runPauser
+	tools     map[string]bool    // 需要审批的工具名或事件类型
+	kill      context.CancelFunc // 终止 Agent 命令（不影响事件上报）
+	rejection string             // 审批未通过时的 Run 结束原因
+	policy    *policyGuard       // 安全策略（可选，见 security_policy.go）
+	commands  *commandFilter     // 危险命令拦截（可选，见 command_filter.go）
+	violation string             // Agent 因调用被禁止的工具或执行被拦截的命令被终止时的 Run 失败原因
+	detached  func() bool        // 为 true 时 Run 已交接给下一次启动，等待中断后保持暂停（见 handover.go）
+}
+
+// newApprovalGate 创建审批闸门
+func newApprovalGate(nm *NodeManager, runID string, pauser *runPauser, tools []string, kill context.CancelFunc) *approvalGate {
+	g := &approvalGate{nm: nm, runID: runID, pauser: pauser, tools: make(map[string]bool, len(tools)), kill: kill}
+	for _, t := range tools {
+		g.tools[t] = true
+	}
+	return g
+}
+
+// requires 判断事件是否需要审批，需要时返回 approval_required 事件内容
+func (g *approvalGate) requires(event *adapter.CanonicalEvent) map[string]interface{} {
+	if event.Type == adapter.EventApprovalRequired {
+		payload := make(map[string]interface{}, len(event.Payload)+1)
+		for k, v := range event.Payload {
+			payload[k] = v
+		}
+		if _, ok := payload["type"]; !ok {
+			payload["type"] = string(model.ApprovalTypeDangerousOp)
+		}
+		return payload
+	}
+	if payload, matched := g.commands.approval(event); matched {
+		return payload
+	}
+	operation, _ := event.Payload["tool"].(string)
+	if !g.tools[operation] {
+		operation = string(event.Type)
+		if !g.tools[operation] {
+			return g.policy.approval(event)
+		}
+	}
+	return map[string]interface{}{
+		"type":      string(model.ApprovalTypeDangerousOp),
+		"operation": operation,
+		"reason":    "任务要求执行 " + operation + " 前人工审批",
+		"context":   event.Payload,
+	}
+}
+
+// wait 暂停执行目标并上报 approval_required 事件，阻塞到收到审批决定或 Run 被终止，返回下一个事件序号
+func (g *approvalGate) wait(ctx context.Context, seq int, payload map[string]interface{}) int {
+	approvalID := fmt.Sprintf("apr-%s-%d", g.runID, seq)
+	payload["approval_id"] = approvalID
+	if _, ok := payload["timeout_seconds"]; !ok {
+		payload["timeout_seconds"] = defaultApprovalTimeoutSeconds
+	}
+	decision := g.nm.registerApproval(approvalID)
+	defer g.nm.unregisterApproval(approvalID)
+
+	if err := g.pauser.hold(ctx, pauseByApproval); err != nil {
+		// 暂停失败时 Agent 仍会在输出管道写满后阻塞
+		log.Printf("[Approval] 任务 %s 暂停执行失败: %v", g.runID, err)
+	}
+	g.nm.reportEvent(ctx, g.runID, seq, "approval_required", payload)
+	seq++
+	log.Printf("[Approval] 任务 %s 等待审批 %s (%v)", g.runID, approvalID, payload["operation"])
+
+	var status string
+	select {
+	case status = <-decision:
+	case <-ctx.Done():
+		if g.detached != nil && g.detached() {
+			// 下一次启动重放该事件后继续等待同一审批
+			return seq
+		}
+	}
+	resumeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), limitRestoreTimeout)
+	defer cancel()
+	if err := g.pauser.release(resumeCtx, pauseByApproval); err != nil {
+		log.Printf("[Approval] 任务 %s 恢复执行失败: %v", g.runID, err)
+	}
+	if ctx.Err() != nil {
+		return seq
+	}
+
+	approved := status == string(model.ApprovalStatusApproved)
+	g.nm.reportEvent(ctx, g.runID, seq, "approval_response", map[string]interface{}{
+		"approval_id": approvalID,
+		"approved":    approved,
+		"status":      status,
+	})
+	seq++
+	log.Printf("[Approval] 任务 %s 审批 %s 结果: %s", g.runID, approvalID, status)
+	if !approved {
+		g.rejection = fmt.Sprintf("审批 %s 未通过（%s）", approvalID, status)
+		g.kill()
+	}
+	return seq
+}
+
+// registerApproval 登记等待中的审批，返回接收审批结果的 channel
+func (nm *NodeManager) registerApproval(approvalID string) <-chan string {
+	ch := make(chan string, 1)
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.approvals == nil {
+		nm.approvals = make(map[string]chan string)
+	}
+	nm.approvals[approvalID] = ch
+	return ch
+}
+
+func (nm *NodeManager) unregisterApproval(approvalID string) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	delete(nm.approvals, approvalID)
+}
+
+// ResolveApproval 投递审批结果（心跳指令 approvals），审批不在等待中时忽略
+func (nm *NodeManager) ResolveApproval(approvalID, status string) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if ch, ok := nm.approvals[approvalID]; ok {
+		select {
+		case ch <- status:
+		default:
+		}
+	}
+}
+
+// pendingApprovals 等待中的审批 ID（随心跳上报）
+func (nm *NodeManager) pendingApprovals() []string {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	ids := make([]string, 0, len(nm.approvals))
+	for id := range nm.approvals {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
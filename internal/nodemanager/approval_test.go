@@ -0,0 +1,145 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+// jsonLineAdapter 将每行 {"type":...,"payload":...} 解析为事件的测试 Adapter
+type jsonLineAdapter struct {
+	adapter.Adapter
+}
+
+func (jsonLineAdapter) ParseEvent(line string) (*adapter.CanonicalEvent, error) {
+	var e adapter.CanonicalEvent
+	if err := json.Unmarshal([]byte(line), &e); err != nil {
+		return nil, nil
+	}
+	return &e, nil
+}
+
+// pausingTarget 记录暂停与恢复调用的执行目标
+type pausingTarget struct {
+	execTarget
+	calls []string
+}
+
+func (t *pausingTarget) pause(context.Context, *exec.Cmd) error {
+	t.calls = append(t.calls, "pause")
+	return nil
+}
+
+func (t *pausingTarget) resume(context.Context, *exec.Cmd) error {
+	t.calls = append(t.calls, "resume")
+	return nil
+}
+
+// resolveWhenPending 等到审批登记后投递审批结果
+func resolveWhenPending(t *testing.T, nm *NodeManager, status string) {
+	t.Helper()
+	go func() {
+		for i := 0; i < 500; i++ {
+			if ids := nm.pendingApprovals(); len(ids) > 0 {
+				nm.ResolveApproval(ids[0], status)
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+}
+
+func eventTypes(events []map[string]interface{}) []string {
+	types := make([]string, 0, len(events))
+	for _, e := range events {
+		types = append(types, e["type"].(string))
+	}
+	return types
+}
+
+func TestParseApprovalTools(t *testing.T) {
+	if got := ParseApprovalTools(&model.RunSnapshot{}); got != nil {
+		t.Errorf("no require_approval: %v", got)
+	}
+	got := ParseApprovalTools(&model.RunSnapshot{RequireApproval: []string{"run_shell_command", "", "write_file"}})
+	if !slices.Equal(got, []string{"run_shell_command", "write_file"}) {
+		t.Errorf("tools = %v", got)
+	}
+}
+
+func TestApprovalGateRequires(t *testing.T) {
+	g := newApprovalGate(nil, "run-1", nil, []string{"run_shell_command", "command"}, func() {})
+
+	if got := g.requires(&adapter.CanonicalEvent{Type: adapter.EventToolUseStart, Payload: map[string]interface{}{"tool": "run_shell_command"}}); got == nil || got["operation"] != "run_shell_command" {
+		t.Errorf("tool match = %v", got)
+	}
+	if got := g.requires(&adapter.CanonicalEvent{Type: "command", Payload: map[string]interface{}{"command": "ls"}}); got == nil || got["operation"] != "command" {
+		t.Errorf("event type match = %v", got)
+	}
+	if got := g.requires(&adapter.CanonicalEvent{Type: adapter.EventToolUseStart, Payload: map[string]interface{}{"tool": "read_file"}}); got != nil {
+		t.Errorf("unlisted tool should not require approval: %v", got)
+	}
+	got := g.requires(&adapter.CanonicalEvent{Type: adapter.EventApprovalRequired, Payload: map[string]interface{}{"operation": "rm -rf"}})
+	if got == nil || got["operation"] != "rm -rf" || got["type"] != "dangerous_operation" {
+		t.Errorf("adapter approval = %v", got)
+	}
+}
+
+func TestStreamOutput_ApprovalApproved(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	target := &pausingTarget{}
+	killed := false
+	gate := newApprovalGate(nm, "run-1", newRunPauser(target, nil), []string{"run_shell_command"}, func() { killed = true })
+
+	resolveWhenPending(t, nm, "approved")
+	output := `{"type":"tool_use_start","payload":{"tool":"run_shell_command"}}` + "\n" + `{"type":"message","payload":{"content":"done"}}` + "\n"
+	seq := nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), jsonLineAdapter{}, gate, nil, 1)
+
+	got := eventTypes(events())
+	want := []string{"tool_use_start", "approval_required", "approval_response", "message"}
+	if !slices.Equal(got, want) || seq != 5 {
+		t.Fatalf("events = %v (seq %d), want %v", got, seq, want)
+	}
+	payload := events()[1]["payload"].(map[string]interface{})
+	if payload["approval_id"] != "apr-run-1-2" || payload["timeout_seconds"] != float64(defaultApprovalTimeoutSeconds) {
+		t.Errorf("approval_required payload = %v", payload)
+	}
+	if !slices.Equal(target.calls, []string{"pause", "resume"}) {
+		t.Errorf("target calls = %v", target.calls)
+	}
+	if killed || gate.rejection != "" || len(nm.pendingApprovals()) != 0 {
+		t.Errorf("approved run must continue: killed=%v rejection=%q", killed, gate.rejection)
+	}
+}
+
+func TestStreamOutput_ApprovalRejected(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	target := &pausingTarget{}
+	killed := false
+	gate := newApprovalGate(nm, "run-1", newRunPauser(target, nil), nil, func() { killed = true })
+
+	resolveWhenPending(t, nm, "rejected")
+	output := `{"type":"approval_required","payload":{"operation":"git push --force"}}` + "\n"
+	nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), jsonLineAdapter{}, gate, nil, 1)
+
+	got := eventTypes(events())
+	if !slices.Equal(got, []string{"approval_required", "approval_response"}) {
+		t.Fatalf("events = %v", got)
+	}
+	if resp := events()[1]["payload"].(map[string]interface{}); resp["approved"] != false || resp["status"] != "rejected" {
+		t.Errorf("approval_response payload = %v", resp)
+	}
+	if !killed || gate.rejection == "" {
+		t.Errorf("rejected approval must kill the agent: killed=%v rejection=%q", killed, gate.rejection)
+	}
+	if !slices.Equal(target.calls, []string{"pause", "resume"}) {
+		t.Errorf("target calls = %v", target.calls)
+	}
+}
@@ -52,6 +52,15 @@ func NewClient() (*Client, error) {
 	return &Client{cli: cli}, nil
 }
 
+// NewClientForSocket 创建访问指定 Unix socket 上 Docker 兼容 API 的客户端（如 podman.sock）
+func NewClientForSocket(socket string) (*Client, error) {
+	cli, err := client.New(client.FromEnv, client.WithHost("unix://"+socket))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create docker client: %w", err)
+	}
+	return &Client{cli: cli}, nil
+}
+
 // Close 关闭客户端
 func (c *Client) Close() error {
 	return c.cli.Close()
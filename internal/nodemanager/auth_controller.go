@@ -29,11 +29,16 @@ type AuthControllerV2 struct {
 	httpClient      *http.Client
 	containerClient *auth.Client
 	authRegistry    *auth.Registry
+	proxies         *proxyProber // 代理探测结果（为 nil 时不注入代理，认证器回退到宿主机代理变量）
 
 	mu             sync.Mutex
 	runningActions map[string]*runningAction
+	finished       map[string]time.Time // 最近结束的 Action（期望状态文档可能滞后于本地上报，避免重复执行或误判为中断）
 }
 
+// recentlyFinishedTTL 最近结束的 Action 保留时长
+const recentlyFinishedTTL = 2 * time.Minute
+
 // runningAction 运行中的 Action
 type runningAction struct {
 	actionID      string
@@ -63,7 +68,7 @@ type NodeOperation struct {
 
 // NewAuthControllerV2 创建认证控制器V2
 func NewAuthControllerV2(cfg Config) (*AuthControllerV2, error) {
-	containerClient, err := auth.NewClient()
+	containerClient, err := auth.NewClientForSocket(cfg.containers().APISocket())
 	if err != nil {
 		return nil, fmt.Errorf("failed to create container client: %w", err)
 	}
@@ -82,6 +87,7 @@ func NewAuthControllerV2(cfg Config) (*AuthControllerV2, error) {
 		containerClient: containerClient,
 		authRegistry:    registry,
 		runningActions:  make(map[string]*runningAction),
+		finished:        make(map[string]time.Time),
 	}, nil
 }
 
@@ -115,22 +121,77 @@ func (c *AuthControllerV2) pollAndExecuteActions(ctx context.Context) {
 	}
 
 	for _, action := range actions {
-		actionID := action.ID
+		c.startAction(ctx, action)
+	}
+}
+
+// startAction 启动 Action（已在执行中时跳过），返回是否启动
+func (c *AuthControllerV2) startAction(ctx context.Context, action *NodeAction) bool {
+	c.mu.Lock()
+	if _, exists := c.runningActions[action.ID]; exists {
+		c.mu.Unlock()
+		return false
+	}
 
+	actionCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
+	c.runningActions[action.ID] = &runningAction{
+		actionID: action.ID,
+		cancel:   cancel,
+	}
+	c.mu.Unlock()
+
+	go c.executeAction(actionCtx, action)
+	return true
+}
+
+// reconcileActions 按期望状态对齐认证任务
+//
+// 启动文档中 assigned 且本地未执行的 Action；取消本地执行中但已不在文档中的 Action（已被取消或超时）；
+// 文档中 running/waiting 但本地没有执行的 Action（节点重启后丢失）上报为 failed。
+func (c *AuthControllerV2) reconcileActions(ctx context.Context, actions []*NodeAction) {
+	desired := make(map[string]bool, len(actions))
+	var interrupted []string
+
+	c.mu.Lock()
+	for id, at := range c.finished {
+		if time.Since(at) > recentlyFinishedTTL {
+			delete(c.finished, id)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, action := range actions {
+		desired[action.ID] = true
 		c.mu.Lock()
-		if _, exists := c.runningActions[actionID]; exists {
-			c.mu.Unlock()
+		_, running := c.runningActions[action.ID]
+		_, finished := c.finished[action.ID]
+		c.mu.Unlock()
+		if running || finished {
+			continue
+		}
+		if model.ActionStatus(action.Status) == model.ActionStatusAssigned {
+			c.startAction(ctx, action)
 			continue
 		}
+		interrupted = append(interrupted, action.ID)
+	}
 
-		actionCtx, cancel := context.WithTimeout(ctx, 10*time.Minute)
-		c.runningActions[actionID] = &runningAction{
-			actionID: actionID,
-			cancel:   cancel,
+	var stale []string
+	c.mu.Lock()
+	for id := range c.runningActions {
+		if !desired[id] {
+			stale = append(stale, id)
 		}
-		c.mu.Unlock()
+	}
+	c.mu.Unlock()
 
-		go c.executeAction(actionCtx, action)
+	for _, id := range stale {
+		log.Printf("[AuthController] Action %s no longer desired, cancelling", id)
+		c.CancelAuthTask(id)
+	}
+	for _, id := range interrupted {
+		log.Printf("[AuthController] Action %s is not running on this node, marking failed", id)
+		c.reportActionStatus(id, "failed", "", "", 0, nil, "interrupted: auth session lost on node")
 	}
 }
 
@@ -167,6 +228,7 @@ func (c *AuthControllerV2) executeAction(ctx context.Context, action *NodeAction
 	defer func() {
 		c.mu.Lock()
 		delete(c.runningActions, actionID)
+		c.finished[actionID] = time.Now()
 		c.mu.Unlock()
 	}()
 
@@ -237,6 +299,7 @@ func (c *AuthControllerV2) executeAuthAction(ctx context.Context, action *NodeAc
 		AuthFile:   agentTypeCfg.AuthFile,
 		LoginCmd:   agentTypeCfg.LoginCmd,
 		VolumeName: volumeName,
+		ProxyEnvs:  c.proxies.envFor(config.ProxyID), // 指定代理或默认代理不可用时切换到健康代理
 	}
 
 	// 上报 launching_container phase
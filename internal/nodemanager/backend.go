@@ -0,0 +1,232 @@
+// Package nodemanager Run 执行后端
+//
+// 执行后端决定 Adapter 命令与生命周期钩子在何处运行：
+//   - docker：在 Agent 实例容器内执行（容器运行时 exec，见 container_runtime.go），Workspace 复制进容器
+//   - process：在宿主机上直接执行（见 backend_process.go），工作目录即准备好的 Workspace
+//
+// 节点通过 Config.ExecBackend 指定默认后端；任务可在执行快照 runtime.backend 中指定，
+// 指定的后端未在本节点启用时 Run 直接失败。
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// execTarget 单个 Run 的执行目标（Agent 命令与钩子都在其中执行）
+type execTarget interface {
+	// backend 后端名称
+	backend() string
+	// command 构建在目标中执行 argv 的命令；密钥值只通过进程环境传递，不出现在命令参数中
+	command(ctx context.Context, argv []string, env map[string]string) *exec.Cmd
+	// collect 将目标中的工作空间同步回宿主机目录（Git 结果回写前调用）
+	collect(ctx context.Context, hostPath string) error
+	// describe run_started 事件中的执行环境描述
+	describe() map[string]interface{}
+	// pause 暂停 Agent 命令的执行（人工审批或用户暂停，见 pause.go），resume 恢复
+	pause(ctx context.Context, cmd *exec.Cmd) error
+	resume(ctx context.Context, cmd *exec.Cmd) error
+	// signal 向 Agent 命令发送信号（sig 为不含 SIG 前缀的信号名，优雅取消见 cancellation.go）
+	signal(ctx context.Context, cmd *exec.Cmd, sig string) error
+	// applyLimits 应用 Run 资源限制（见 limits.go），返回未执行的限制与 Run 结束后调用的恢复函数
+	applyLimits(ctx context.Context, l *RunLimits) (unenforced []string, restore func(), err error)
+}
+
+// ParseExecBackend 从任务快照中解析指定的执行后端（runtime.backend），未指定时返回空
+func ParseExecBackend(snapshot *model.RunSnapshot) string {
+	if snapshot.Runtime == nil {
+		return ""
+	}
+	return snapshot.Runtime.Backend
+}
+
+// execBackendFor 确定 Run 使用的执行后端：任务指定优先，否则使用节点默认后端
+func (nm *NodeManager) execBackendFor(snapshot *model.RunSnapshot) (string, error) {
+	backend := ParseExecBackend(snapshot)
+	if backend == "" {
+		backend = nm.config.ExecBackend
+	}
+	switch backend {
+	case "", model.ExecBackendDocker:
+		return model.ExecBackendDocker, nil
+	case model.ExecBackendProcess:
+		if nm.process == nil {
+			return "", fmt.Errorf("节点未启用 process 执行后端")
+		}
+		return backend, nil
+	default:
+		return "", fmt.Errorf("未知的执行后端: %s", backend)
+	}
+}
+
+// ============================================================================
+// docker 后端
+// ============================================================================
+
+// dockerTarget 在 Agent 实例容器内执行（通过节点配置的容器运行时 exec）
+type dockerTarget struct {
+	rt         ContainerRuntime
+	container  string
+	workingDir string
+	secrets    map[string]string
+	capacity   nodeCapacity // 恢复资源限制时使用的节点容量
+	openFiles  int64        // 打开文件数限制（0 表示不限制）
+	stdin      bool         // Agent 通过 stdin 接收人工反馈（docker exec 需要 -i 才转发 stdin）
+	pidFile    string       // 容器内记录 Agent 进程号的文件（见 interruptibleArgv）
+	pooled     bool         // 容器租自预热实例池，Run 结束后归还
+	pool       *instancePool
+	session    *sessionLease // 持久会话：Run 结束后按会话保留容器（见 session.go）
+}
+
+// prepareDockerTarget 定位 Run 使用的容器（会话保留的容器优先，其次 instance_id、租用账号的预热容器，
+// 回退到 account_id 对应的实例容器），并将 Git Workspace 复制到容器的 /workspace
+//
+// warm 为追问 Run 取出的会话容器，其中保留了上一轮的 /workspace，不再复制 Workspace。
+func (nm *NodeManager) prepareDockerTarget(ctx context.Context, runID string, agent *model.SnapshotAgent, workspace *PreparedWorkspace, wsConfig *WorkspaceConfig, workingDir string, secrets map[string]string, warm *sessionContainer) (*dockerTarget, error) {
+	instanceID, accountID := agent.InstanceID, agent.AccountID
+
+	var containerName string
+	var pooled bool
+	var err error
+	if warm != nil {
+		containerName, pooled = warm.container, warm.pooled
+		if pooled && nm.pool != nil {
+			nm.pool.transfer(containerName, runID)
+		}
+	} else if instanceID == "" && accountID != "" && nm.pool != nil {
+		containerName, pooled = nm.pool.lease(ctx, accountID, runID)
+	}
+	if warm != nil {
+		nm.runLog(runID, nodeLogInfo, nodeLogStageContainer, "继续会话，复用容器 %s", containerName)
+	} else if pooled {
+		nm.runLog(runID, nodeLogInfo, nodeLogStageContainer, "租用预热容器 %s", containerName)
+	} else if instanceID != "" {
+		// 直接通过 instance_id 获取容器名
+		containerName, err = nm.getContainerForInstance(ctx, instanceID)
+		if err != nil {
+			nm.runLog(runID, nodeLogError, nodeLogStageContainer, "获取实例 %s 的容器失败: %v", instanceID, err)
+			return nil, fmt.Errorf("获取实例容器失败: %v", err)
+		}
+	} else if accountID != "" {
+		// 回退：通过 account_id 查找容器
+		containerName, err = nm.getContainerForAccount(ctx, accountID)
+		if err != nil {
+			nm.runLog(runID, nodeLogError, nodeLogStageContainer, "获取账号 %s 的容器失败: %v", accountID, err)
+			return nil, fmt.Errorf("获取容器失败: %v", err)
+		}
+	} else {
+		nm.runLog(runID, nodeLogError, nodeLogStageContainer, "任务缺少 instance_id 或 account_id 配置")
+		return nil, errors.New("任务缺少 instance_id 或 account_id 配置")
+	}
+
+	nm.runLog(runID, nodeLogInfo, nodeLogStageContainer, "将在容器 %s 中执行", containerName)
+	dt := &dockerTarget{rt: nm.config.containers(), container: containerName, workingDir: workingDir, secrets: secrets, capacity: nm.capacity}
+	if pooled {
+		dt.pooled, dt.pool = true, nm.pool
+	}
+
+	// 如果有 Workspace，复制到容器中
+	if warm == nil && workspace != nil && workspace.Path != "" && wsConfig.Type == "git" {
+		nm.runLog(runID, nodeLogInfo, nodeLogStageWorkspace, "复制文件到容器: %s -> %s:/workspace", workspace.Path, containerName)
+		if err := nm.copyToContainer(ctx, workspace.Path, containerName, "/workspace"); err != nil {
+			nm.runLog(runID, nodeLogError, nodeLogStageWorkspace, "复制 Workspace 到容器 %s 失败: %v", containerName, err)
+			dt.release()
+			return nil, fmt.Errorf("复制 Workspace 到容器失败: %v", err)
+		}
+	}
+
+	return dt, nil
+}
+
+func (t *dockerTarget) backend() string { return model.ExecBackendDocker }
+
+func (t *dockerTarget) command(ctx context.Context, argv []string, env map[string]string) *exec.Cmd {
+	if t.openFiles > 0 {
+		argv = ulimitArgv(t.openFiles, argv)
+	}
+	return t.rt.Exec(ctx, t.container, argv, ExecOptions{Env: env, Secrets: t.secrets, WorkingDir: t.workingDir, Stdin: t.stdin})
+}
+
+// collect 容器内的 /workspace 是克隆目录的副本，拷回宿主机
+func (t *dockerTarget) collect(ctx context.Context, hostPath string) error {
+	if err := t.rt.CopyFrom(ctx, t.container, "/workspace/.", hostPath); err != nil {
+		return fmt.Errorf("复制 Workspace 回宿主机失败: %v", err)
+	}
+	return nil
+}
+
+func (t *dockerTarget) describe() map[string]interface{} {
+	d := map[string]interface{}{"container": t.container}
+	if t.pooled {
+		d["pooled"] = true
+	}
+	if t.session != nil {
+		d["session"] = map[string]interface{}{"id": t.session.id, "resumed": t.session.resumed}
+	}
+	return d
+}
+
+// release Run 结束后将预热容器归还实例池（清理工作空间），非池容器无操作；
+// 属于持久会话时改为按会话保留容器
+//
+// Run 的 ctx 可能已取消，清理使用独立的超时。
+func (t *dockerTarget) release() {
+	if t.session != nil {
+		t.session.cache.park(t.session.id, t.container, t.pooled, t.session.keepAlive)
+		return
+	}
+	if !t.pooled {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	t.pool.release(ctx, t.container)
+}
+
+// ============================================================================
+// 工具函数
+// ============================================================================
+
+// targetHookRunner 返回在执行目标中运行钩子的 runner（<shell> -c <script>）
+func targetHookRunner(target execTarget) hookRunner {
+	return func(ctx context.Context, hook HookSpec, env map[string]string) (*hookResult, error) {
+		shell := hook.Shell
+		if shell == "" {
+			shell = defaultHookShell
+		}
+		cmd := target.command(ctx, []string{shell, "-c", hook.Script}, env)
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+		err := cmd.Run()
+
+		result := &hookResult{Output: out.String()}
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+		case errors.As(err, &exitErr) && ctx.Err() == nil:
+			result.ExitCode = exitErr.ExitCode()
+		default:
+			result.ExitCode = -1
+			return result, err
+		}
+		return result, nil
+	}
+}
+
+// sortedKeys 按名称排序的环境变量名，保证命令稳定
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,249 @@
+// Package nodemanager process 执行后端
+//
+// 面向无法运行 Docker 的节点：Adapter 命令直接在宿主机上、以准备好的 Workspace 为工作目录执行。
+// 隔离手段：
+//   - 沙箱用户：配置 User 时以该用户身份运行（NodeManager 需以 root 运行），
+//     NodeManager 克隆的 Workspace 在执行前移交给沙箱用户，回写前收回
+//   - 资源限制：通过 prlimit(1) 设置 CPU 时间、地址空间、打开文件数、进程数与文件大小上限
+//   - 最小环境：不继承 NodeManager 的环境变量（其中含节点凭证），只传递 PATH/HOME/LANG 与 PassEnv 白名单
+//   - 进程组：Run 取消时终止整个进程组，避免 Agent 派生的子进程残留
+package nodemanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+
+	"agents-admin/internal/shared/model"
+)
+
+// defaultProcessPath 节点未设置 PATH 时沙箱进程使用的 PATH
+const defaultProcessPath = "/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"
+
+// ProcessConfig process 执行后端配置
+type ProcessConfig struct {
+	Enabled bool          // 是否启用（节点默认后端为 process 时自动启用）
+	User    string        // 沙箱用户（为空时以 NodeManager 自身身份运行）
+	PassEnv []string      // 额外透传给 Agent 进程的 NodeManager 环境变量名
+	Limits  ProcessLimits // 资源限制（0 表示不限制）
+}
+
+// ProcessLimits 进程资源限制（对应 setrlimit，0 表示不限制）
+type ProcessLimits struct {
+	CPUSeconds    int64 // RLIMIT_CPU：CPU 时间（秒）
+	MemoryBytes   int64 // RLIMIT_AS：地址空间（字节）
+	OpenFiles     int64 // RLIMIT_NOFILE：打开文件数
+	Processes     int64 // RLIMIT_NPROC：沙箱用户的进程数
+	FileSizeBytes int64 // RLIMIT_FSIZE：单个文件大小（字节）
+}
+
+// prlimitArgs 生成 prlimit 参数（不含命令本身），未设置任何限制时返回 nil
+func (l ProcessLimits) prlimitArgs() []string {
+	var args []string
+	add := func(flag string, v int64) {
+		if v > 0 {
+			args = append(args, "--"+flag+"="+strconv.FormatInt(v, 10))
+		}
+	}
+	add("cpu", l.CPUSeconds)
+	add("as", l.MemoryBytes)
+	add("nofile", l.OpenFiles)
+	add("nproc", l.Processes)
+	add("fsize", l.FileSizeBytes)
+	return args
+}
+
+// sandboxUser 沙箱用户身份
+type sandboxUser struct {
+	name string
+	uid  uint32
+	gid  uint32
+	home string
+}
+
+// processBackend 节点级 process 后端（配置在启动时解析）
+type processBackend struct {
+	cfg     ProcessConfig
+	user    *sandboxUser // nil 表示以 NodeManager 自身身份运行
+	baseDir string       // 无 Workspace 时的临时工作目录根目录
+}
+
+// newProcessBackend 创建 process 后端，沙箱用户不存在或平台不支持时返回错误
+func newProcessBackend(cfg ProcessConfig, workspaceDir string) (*processBackend, error) {
+	if !processBackendSupported {
+		return nil, errors.New("process 执行后端仅支持 Linux")
+	}
+	b := &processBackend{cfg: cfg, baseDir: filepath.Join(workspaceDir, "process-runs")}
+	if cfg.User != "" {
+		u, err := lookupSandboxUser(cfg.User)
+		if err != nil {
+			return nil, err
+		}
+		if os.Geteuid() != 0 && int(u.uid) != os.Geteuid() {
+			log.Printf("[ExecBackend] 警告: NodeManager 未以 root 运行，无法切换到沙箱用户 %s", u.name)
+		}
+		b.user = u
+	}
+	if len(cfg.Limits.prlimitArgs()) > 0 {
+		if _, err := exec.LookPath("prlimit"); err != nil {
+			return nil, fmt.Errorf("配置了资源限制但找不到 prlimit: %w", err)
+		}
+	}
+	return b, nil
+}
+
+// lookupSandboxUser 解析沙箱用户（用户名或 UID）
+func lookupSandboxUser(name string) (*sandboxUser, error) {
+	u, err := user.Lookup(name)
+	if err != nil {
+		if u, err = user.LookupId(name); err != nil {
+			return nil, fmt.Errorf("沙箱用户 %s 不存在: %w", name, err)
+		}
+	}
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("沙箱用户 %s UID 无效: %s", name, u.Uid)
+	}
+	gid, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("沙箱用户 %s GID 无效: %s", name, u.Gid)
+	}
+	return &sandboxUser{name: u.Username, uid: uint32(uid), gid: uint32(gid), home: u.HomeDir}, nil
+}
+
+// prepare 为 Run 准备宿主机执行环境
+//
+// 有 Workspace 时以其宿主机路径为工作目录（NodeManager 克隆的 Git Workspace 移交给沙箱用户）；
+// 没有时在 baseDir 下创建临时目录，Run 结束后由 cleanup 删除。
+func (b *processBackend) prepare(runID string, workspace *PreparedWorkspace, wsConfig *WorkspaceConfig, secrets map[string]string) (*processTarget, error) {
+	t := &processTarget{proc: b, secrets: secrets, cleanup: func() {}}
+	switch {
+	case workspace == nil:
+		if err := os.MkdirAll(b.baseDir, 0o755); err != nil {
+			return nil, fmt.Errorf("创建工作目录失败: %w", err)
+		}
+		dir, err := os.MkdirTemp(b.baseDir, runID+"-")
+		if err != nil {
+			return nil, fmt.Errorf("创建工作目录失败: %w", err)
+		}
+		t.dir = dir
+		t.cleanup = func() { os.RemoveAll(dir) }
+		if err := b.chown(dir, true); err != nil {
+			t.cleanup()
+			return nil, err
+		}
+	case wsConfig.Type == "git":
+		t.dir = workspace.Path
+		t.owned = true
+		if err := b.chown(workspace.Path, true); err != nil {
+			return nil, err
+		}
+	case wsConfig.Type == "local":
+		// 本地目录属于节点管理员，只使用不改变属主
+		t.dir = workspace.Path
+	default:
+		return nil, fmt.Errorf("process 执行后端不支持 %s 类型 Workspace", wsConfig.Type)
+	}
+	return t, nil
+}
+
+// chown 将目录移交给沙箱用户（toSandbox=true）或收回给 NodeManager，未配置沙箱用户时不做处理
+func (b *processBackend) chown(root string, toSandbox bool) error {
+	if b.user == nil {
+		return nil
+	}
+	uid, gid := os.Getuid(), os.Getgid()
+	if toSandbox {
+		uid, gid = int(b.user.uid), int(b.user.gid)
+	}
+	err := filepath.WalkDir(root, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+	if err != nil {
+		return fmt.Errorf("设置工作目录属主失败: %w", err)
+	}
+	return nil
+}
+
+// environ 沙箱进程的基础环境变量（不继承 NodeManager 的完整环境）
+func (b *processBackend) environ(dir string) []string {
+	path := os.Getenv("PATH")
+	if path == "" {
+		path = defaultProcessPath
+	}
+	home := dir
+	if b.user != nil && b.user.home != "" {
+		home = b.user.home
+	}
+	env := []string{"PATH=" + path, "HOME=" + home}
+	if b.user != nil {
+		env = append(env, "USER="+b.user.name, "LOGNAME="+b.user.name)
+	}
+	for _, name := range append([]string{"LANG", "LC_ALL", "TZ"}, b.cfg.PassEnv...) {
+		if v, ok := os.LookupEnv(name); ok {
+			env = append(env, name+"="+v)
+		}
+	}
+	return env
+}
+
+// processTarget 在宿主机上执行
+type processTarget struct {
+	proc    *processBackend
+	dir     string
+	secrets map[string]string
+	owned   bool           // 工作目录是否由 NodeManager 克隆（回写前需收回属主）
+	cleanup func()         // 删除临时工作目录
+	limits  *ProcessLimits // 并入任务资源限制后的进程限制（为空时使用节点配置）
+}
+
+func (t *processTarget) backend() string { return model.ExecBackendProcess }
+
+func (t *processTarget) command(ctx context.Context, argv []string, env map[string]string) *exec.Cmd {
+	name, args := argv[0], argv[1:]
+	limits := t.proc.cfg.Limits
+	if t.limits != nil {
+		limits = *t.limits
+	}
+	if prlimit := limits.prlimitArgs(); len(prlimit) > 0 {
+		args = append(append(prlimit, "--"), argv...)
+		name = "prlimit"
+	}
+
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = t.dir
+	cmd.Env = t.proc.environ(t.dir)
+	for _, k := range sortedKeys(env) {
+		cmd.Env = append(cmd.Env, k+"="+env[k])
+	}
+	_, secretEnviron := secretEnvArgs(t.secrets)
+	cmd.Env = append(cmd.Env, secretEnviron...)
+	configureSandbox(cmd, t.proc.user)
+	return cmd
+}
+
+// collect 工作目录就在宿主机上，只需将属主收回给 NodeManager（git 拒绝操作其他用户的仓库）
+func (t *processTarget) collect(ctx context.Context, hostPath string) error {
+	if !t.owned {
+		return nil
+	}
+	return t.proc.chown(hostPath, false)
+}
+
+func (t *processTarget) describe() map[string]interface{} {
+	desc := map[string]interface{}{"working_dir": t.dir}
+	if t.proc.user != nil {
+		desc["user"] = t.proc.user.name
+	}
+	return desc
+}
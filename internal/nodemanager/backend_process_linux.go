@@ -0,0 +1,75 @@
+//go:build linux
+
+package nodemanager
+
+import (
+	"fmt"
+	"os/exec"
+	"syscall"
+)
+
+// processBackendSupported 沙箱用户与进程组依赖 Linux 的 SysProcAttr
+const processBackendSupported = true
+
+// configureSandbox 以沙箱用户身份、在独立进程组中运行命令，取消时终止整个进程组
+func configureSandbox(cmd *exec.Cmd, u *sandboxUser) {
+	attr := &syscall.SysProcAttr{Setpgid: true, Pdeathsig: syscall.SIGKILL}
+	if u != nil {
+		attr.Credential = &syscall.Credential{Uid: u.uid, Gid: u.gid}
+	}
+	cmd.SysProcAttr = attr
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
+
+// suspendGroup 暂停命令所在进程组（Agent 及其子进程）
+func suspendGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGSTOP)
+}
+
+// resumeGroup 恢复被 suspendGroup 暂停的进程组
+func resumeGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGCONT)
+}
+
+// killGroup 终止进程组（重启交接后 NodeManager 不再持有 Agent 命令，按记录的进程组终止）
+// 进程组已不存在时返回 nil
+func killGroup(pgid int) error {
+	if err := syscall.Kill(-pgid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
+
+// groupSignals signalGroup 支持的信号名
+var groupSignals = map[string]syscall.Signal{
+	"INT":  syscall.SIGINT,
+	"TERM": syscall.SIGTERM,
+	"HUP":  syscall.SIGHUP,
+	"QUIT": syscall.SIGQUIT,
+	"USR1": syscall.SIGUSR1,
+	"USR2": syscall.SIGUSR2,
+	"KILL": syscall.SIGKILL,
+}
+
+// signalGroup 向命令所在进程组发送信号（sig 为不含 SIG 前缀的信号名），进程组已不存在时返回 nil
+func signalGroup(cmd *exec.Cmd, sig string) error {
+	s, ok := groupSignals[sig]
+	if !ok {
+		return fmt.Errorf("不支持的信号: %s", sig)
+	}
+	if cmd.Process == nil {
+		return nil
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, s); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
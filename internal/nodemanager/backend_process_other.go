@@ -0,0 +1,23 @@
+//go:build !linux
+
+package nodemanager
+
+import (
+	"errors"
+	"os/exec"
+)
+
+// processBackendSupported 非 Linux 平台不支持 process 执行后端
+const processBackendSupported = false
+
+func configureSandbox(cmd *exec.Cmd, u *sandboxUser) {}
+
+func suspendGroup(cmd *exec.Cmd) error { return errors.New("process 执行后端仅支持 Linux") }
+
+func resumeGroup(cmd *exec.Cmd) error { return errors.New("process 执行后端仅支持 Linux") }
+
+func killGroup(pgid int) error { return errors.New("process 执行后端仅支持 Linux") }
+
+func signalGroup(cmd *exec.Cmd, sig string) error {
+	return errors.New("process 执行后端仅支持 Linux")
+}
@@ -0,0 +1,130 @@
+package nodemanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestExecBackendFor(t *testing.T) {
+	withBackend := func(backend string) *model.RunSnapshot {
+		return &model.RunSnapshot{Runtime: &model.SnapshotRuntime{Backend: backend}}
+	}
+	docker := &NodeManager{}
+	process := &NodeManager{config: Config{ExecBackend: model.ExecBackendProcess}, process: &processBackend{}}
+
+	tests := []struct {
+		name     string
+		nm       *NodeManager
+		snapshot *model.RunSnapshot
+		want     string
+		wantErr  bool
+	}{
+		{"node default docker", docker, &model.RunSnapshot{}, model.ExecBackendDocker, false},
+		{"node default process", process, &model.RunSnapshot{}, model.ExecBackendProcess, false},
+		{"task overrides node", process, withBackend(model.ExecBackendDocker), model.ExecBackendDocker, false},
+		{"process not enabled", docker, withBackend(model.ExecBackendProcess), "", true},
+		{"unknown backend", docker, withBackend("vm"), "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.nm.execBackendFor(tt.snapshot)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("backend = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDockerTargetCommand(t *testing.T) {
+	target := &dockerTarget{rt: defaultContainerRuntime, container: "agent-1", workingDir: "/workspace", secrets: map[string]string{"API_KEY": "s3cret"}}
+	cmd := target.command(context.Background(), []string{"qwen", "-p", "hi"}, map[string]string{"B": "2", "A": "1"})
+
+	want := []string{"docker", "exec", "-e", "A=1", "-e", "B=2", "-w", "/workspace", "-e", "API_KEY", "agent-1", "qwen", "-p", "hi"}
+	if !slices.Equal(cmd.Args, want) {
+		t.Errorf("args = %v, want %v", cmd.Args, want)
+	}
+	if !slices.Contains(cmd.Env, "API_KEY=s3cret") {
+		t.Error("secret value should be passed through the docker client environment")
+	}
+}
+
+func TestProcessLimitsPrlimitArgs(t *testing.T) {
+	if args := (ProcessLimits{}).prlimitArgs(); args != nil {
+		t.Errorf("no limits should produce no args, got %v", args)
+	}
+	args := ProcessLimits{CPUSeconds: 60, MemoryBytes: 1 << 30, Processes: 64}.prlimitArgs()
+	want := []string{"--cpu=60", "--as=1073741824", "--nproc=64"}
+	if !slices.Equal(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+
+	target := &processTarget{proc: &processBackend{cfg: ProcessConfig{Limits: ProcessLimits{OpenFiles: 256}}}, dir: t.TempDir()}
+	cmd := target.command(context.Background(), []string{"qwen", "-p", "hi"}, nil)
+	if want := []string{"prlimit", "--nofile=256", "--", "qwen", "-p", "hi"}; !slices.Equal(cmd.Args, want) {
+		t.Errorf("args = %v, want %v", cmd.Args, want)
+	}
+}
+
+func TestProcessTarget_Run(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process backend requires linux")
+	}
+	t.Setenv("NODE_TOKEN", "node-credential")
+	t.Setenv("EXTRA_VAR", "passed")
+
+	b, err := newProcessBackend(ProcessConfig{PassEnv: []string{"EXTRA_VAR"}}, t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	target, err := b.prepare("run-1", nil, nil, map[string]string{"API_KEY": "s3cret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := target.dir
+	if !strings.HasPrefix(filepath.Base(dir), "run-1-") {
+		t.Errorf("scratch dir = %s", dir)
+	}
+
+	run := targetHookRunner(target)
+	result, err := run(context.Background(), HookSpec{Script: `pwd; echo "$API_KEY $EXTRA_VAR $RUN_ENV [$NODE_TOKEN]"; exit 3`}, map[string]string{"RUN_ENV": "env"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != 3 {
+		t.Errorf("exit code = %d, want 3", result.ExitCode)
+	}
+	want := dir + "\ns3cret passed env []\n"
+	if result.Output != want {
+		t.Errorf("output = %q, want %q", result.Output, want)
+	}
+
+	target.cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("scratch dir should be removed, stat err = %v", err)
+	}
+}
+
+func TestProcessBackend_PrepareWorkspace(t *testing.T) {
+	b := &processBackend{}
+	dir := t.TempDir()
+	target, err := b.prepare("run-1", &PreparedWorkspace{Path: dir, WorkingDir: "/workspace"}, &WorkspaceConfig{Type: "git"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target.dir != dir || !target.owned {
+		t.Errorf("git workspace should be used in place, got dir=%s owned=%v", target.dir, target.owned)
+	}
+	if _, err := b.prepare("run-1", &PreparedWorkspace{Path: "vol"}, &WorkspaceConfig{Type: "volume"}, nil); err == nil {
+		t.Error("volume workspace is not supported by the process backend")
+	}
+}
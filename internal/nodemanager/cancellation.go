@@ -0,0 +1,243 @@
+// Package nodemanager Run 的优雅取消
+//
+// Run 被取消（CancelRun）或超时终止（TimeoutRun）时不直接杀死 Agent，而是分阶段停止：
+//  1. interrupt：恢复被暂停的执行目标后向 Agent 发送停止信号（默认 SIGINT，Adapter 可通过 adapter.Interrupter 指定），
+//     Agent 借此保存会话、输出最后的事件，期间 NodeManager 继续读取并上报 Agent 的输出
+//  2. kill：Agent 在宽限期（Config.CancelGrace，默认 10 秒）内未退出时强制终止（SIGKILL）；停止信号发送失败时立即强制终止
+//  3. exited：Agent 已退出，clean 表示 Agent 在宽限期内自行退出
+//
+// 各阶段在 Agent 退出后以 run_cancellation 事件上报（事件序号由 Run 的执行 goroutine 统一分配），随后上报终态；
+// Agent 收到停止信号后以退出码 0 结束时 Run 仍为 cancelled / timeout。
+//
+// 执行后端：
+//   - process：信号发送给 Agent 所在的进程组
+//   - docker：docker exec 客户端不向容器内的进程转发信号，Agent 经包装脚本将容器内进程号写入 agentPIDPrefix<run_id>，
+//     信号通过容器内的 kill 命令发送；强制终止时同时终止 docker exec 客户端
+//   - 重启交接（handover.go）：信号发送给记录的 Agent 进程号，宽限期内轮询 exit 文件。后台启动的命令忽略 SIGINT，
+//     停止信号为 INT 时改为发送 SIGTERM；停止信号之后 Agent 写出的输出不再读取
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+	"time"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// defaultCancelGrace 发送停止信号后等待 Agent 自行退出的默认宽限期
+	defaultCancelGrace = 10 * time.Second
+	// cancelCommandTimeout 在执行目标内发送信号的命令超时
+	cancelCommandTimeout = 30 * time.Second
+	// detachedExitPollInterval 重启交接模式下轮询 exit 文件的间隔
+	detachedExitPollInterval = 200 * time.Millisecond
+	// agentPIDPrefix docker 后端在容器内记录 Agent 进程号的文件前缀
+	agentPIDPrefix = "/tmp/agents-pid-"
+)
+
+// pidScript 将自身进程号写入 $0 后执行 Agent 命令（exec 后进程号不变）
+const pidScript = `echo $$ >"$0" && exec "$@"`
+
+// signalScript 向文件 $1 记录的进程发送信号 $2
+const signalScript = `p=$(cat "$1") && kill -s "$2" "$p"`
+
+// signalPIDScript 向输出目录 $1 记录的 Agent 进程发送信号 $2（重启交接）
+const signalPIDScript = `p=$(cat "$1/pid") && kill -s "$2" "$p"`
+
+// cancellation 单个 Run 的停止过程
+type cancellation struct {
+	runID  string
+	reason string // cancelled / timeout
+	signal string // 停止信号名（不含 SIG 前缀）
+	grace  time.Duration
+
+	err      error         // 发送停止信号失败的原因
+	killed   bool          // 宽限期内未退出，已强制终止
+	killedAt time.Duration // 强制终止时距发送停止信号的时间
+	exitedAt time.Duration // Agent 退出时距发送停止信号的时间
+}
+
+// newCancellation 按 Adapter 与节点配置确定停止信号与宽限期
+func (nm *NodeManager) newCancellation(runID string, a adapter.Adapter) *cancellation {
+	c := &cancellation{
+		runID:  runID,
+		reason: nm.interruptedStatus(runID),
+		signal: adapter.DefaultInterruptSignal,
+		grace:  nm.config.CancelGrace,
+	}
+	if i, ok := a.(adapter.Interrupter); ok && i.InterruptSignal() != "" {
+		c.signal = strings.TrimPrefix(strings.ToUpper(i.InterruptSignal()), "SIG")
+	}
+	if c.grace <= 0 {
+		c.grace = defaultCancelGrace
+	}
+	return c
+}
+
+// stop 发送停止信号并等待 exited 关闭，宽限期内未关闭时调用 kill 强制终止；返回时 Agent 已退出
+func (c *cancellation) stop(exited <-chan struct{}, interrupt func(sig string) error, kill func() error) {
+	start := time.Now()
+	log.Printf("[run.cancel.interrupt] run_id=%s reason=%s signal=SIG%s grace=%s", c.runID, c.reason, c.signal, c.grace)
+	grace := c.grace
+	if c.err = interrupt(c.signal); c.err != nil {
+		log.Printf("[run.cancel.interrupt.failed] run_id=%s error=%v", c.runID, c.err)
+		grace = 0
+	}
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case <-exited:
+	case <-timer.C:
+		c.killed, c.killedAt = true, time.Since(start)
+		log.Printf("[run.cancel.kill] run_id=%s elapsed=%s", c.runID, c.killedAt)
+		if err := kill(); err != nil {
+			log.Printf("[run.cancel.kill.failed] run_id=%s error=%v", c.runID, err)
+		}
+		<-exited
+	}
+	c.exitedAt = time.Since(start)
+	log.Printf("[run.cancel.exited] run_id=%s clean=%t elapsed=%s", c.runID, c.clean(), c.exitedAt)
+}
+
+// clean Agent 是否在宽限期内自行退出
+func (c *cancellation) clean() bool {
+	return c.err == nil && !c.killed
+}
+
+// reportCancellation 上报各停止阶段的 run_cancellation 事件，返回下一个事件序号
+func (nm *NodeManager) reportCancellation(ctx context.Context, c *cancellation, seq int) int {
+	interrupt := map[string]interface{}{
+		"phase":           "interrupt",
+		"reason":          c.reason,
+		"signal":          "SIG" + c.signal,
+		"grace_period_ms": c.grace.Milliseconds(),
+	}
+	if c.err != nil {
+		interrupt["error"] = c.err.Error()
+	}
+	phases := []map[string]interface{}{interrupt}
+	if c.killed {
+		phases = append(phases, map[string]interface{}{
+			"phase":      "kill",
+			"reason":     c.reason,
+			"signal":     "SIGKILL",
+			"elapsed_ms": c.killedAt.Milliseconds(),
+		})
+	}
+	phases = append(phases, map[string]interface{}{
+		"phase":      "exited",
+		"reason":     c.reason,
+		"clean":      c.clean(),
+		"elapsed_ms": c.exitedAt.Milliseconds(),
+	})
+	for _, payload := range phases {
+		nm.reportEvent(ctx, c.runID, seq, string(model.EventTypeRunCancellation), payload)
+		seq++
+	}
+	return seq
+}
+
+// ============================================================================
+// Agent 命令（子进程）
+// ============================================================================
+
+// interruptibleArgv docker 后端经包装脚本启动 Agent，在容器内记录其进程号以便发送停止信号
+func interruptibleArgv(target execTarget, runID string, argv []string) []string {
+	t, ok := target.(*dockerTarget)
+	if !ok {
+		return argv
+	}
+	t.pidFile = agentPIDPrefix + runID
+	return append([]string{"sh", "-c", pidScript, t.pidFile}, argv...)
+}
+
+// stopAgent 优雅停止 Agent 命令（exited 在 cmd.Wait 返回后关闭），Agent 已退出时返回 nil
+func (nm *NodeManager) stopAgent(runID string, a adapter.Adapter, target execTarget, cmd *exec.Cmd, killCmd func(), exited <-chan struct{}) *cancellation {
+	select {
+	case <-exited:
+		return nil
+	default:
+	}
+	c := nm.newCancellation(runID, a)
+	c.stop(exited, func(sig string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), cancelCommandTimeout)
+		defer cancel()
+		return target.signal(ctx, cmd, sig)
+	}, func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), cancelCommandTimeout)
+		defer cancel()
+		err := target.signal(ctx, cmd, "KILL")
+		killCmd()
+		return err
+	})
+	return c
+}
+
+// signal 通过容器内的 kill 命令向 Agent 发送信号（进程号由 interruptibleArgv 记录）
+func (t *dockerTarget) signal(ctx context.Context, _ *exec.Cmd, sig string) error {
+	if t.pidFile == "" {
+		return fmt.Errorf("未记录 Agent 进程号")
+	}
+	out, err := t.command(ctx, []string{"sh", "-c", signalScript, "sh", t.pidFile, sig}, nil).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// signal 向 Agent 进程组发送信号
+func (t *processTarget) signal(_ context.Context, cmd *exec.Cmd, sig string) error {
+	return signalGroup(cmd, sig)
+}
+
+// ============================================================================
+// 重启交接（脱离 NodeManager 运行的 Agent）
+// ============================================================================
+
+// stopDetached 优雅停止脱离运行的 Agent：向记录的进程号发送停止信号，宽限期内轮询 exit 文件，
+// 未退出时按 killDetached 强制终止
+func (nm *NodeManager) stopDetached(st *runState, out *runOutput, a adapter.Adapter) *cancellation {
+	c := nm.newCancellation(st.RunID, a)
+	if c.signal == "INT" {
+		c.signal = "TERM"
+	}
+
+	// 强制终止后不再等待 exit 文件（process 后端终止整个进程组，退出码不会写入）
+	pollCtx, stopPoll := context.WithTimeout(context.Background(), c.grace+cancelCommandTimeout)
+	defer stopPoll()
+	exited := make(chan struct{})
+	go func() {
+		defer close(exited)
+		for {
+			if _, ok := out.exitCode(pollCtx); ok {
+				return
+			}
+			select {
+			case <-pollCtx.Done():
+				return
+			case <-time.After(detachedExitPollInterval):
+			}
+		}
+	}()
+
+	c.stop(exited, func(sig string) error {
+		ctx, cancel := context.WithTimeout(context.Background(), cancelCommandTimeout)
+		defer cancel()
+		res, err := out.target.command(ctx, []string{"sh", "-c", signalPIDScript, "sh", st.OutputDir, sig}, nil).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(res)))
+		}
+		return nil
+	}, func() error {
+		nm.killDetached(out.target, st)
+		stopPoll()
+		return nil
+	})
+	return c
+}
@@ -0,0 +1,134 @@
+package nodemanager
+
+import (
+	"context"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func cancellationPhases(events []map[string]interface{}) ([]string, map[string]interface{}) {
+	var phases []string
+	var exited map[string]interface{}
+	for _, e := range events {
+		if e["type"] != "run_cancellation" {
+			continue
+		}
+		payload := e["payload"].(map[string]interface{})
+		phases = append(phases, payload["phase"].(string))
+		if payload["phase"] == "exited" {
+			exited = payload
+		}
+	}
+	return phases, exited
+}
+
+// TestStopAgent Agent 在宽限期内处理停止信号时 clean 退出，忽略停止信号时被强制终止
+func TestStopAgent(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process backend requires linux")
+	}
+	tests := []struct {
+		name       string
+		script     string
+		timedOut   bool
+		wantPhases []string
+		wantReason string
+		wantClean  bool
+	}{
+		{"处理 SIGINT", `trap 'exit 0' INT; while :; do sleep 0.05; done`, false, []string{"interrupt", "exited"}, "cancelled", true},
+		{"忽略 SIGINT", `trap '' INT; while :; do sleep 0.05; done`, true, []string{"interrupt", "kill", "exited"}, "timeout", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nm, events := newHookTestManager(t)
+			nm.config.CancelGrace = 500 * time.Millisecond
+			nm.timedOut = map[string]bool{"run-1": tt.timedOut}
+			var err error
+			if nm.process, err = newProcessBackend(ProcessConfig{}, t.TempDir()); err != nil {
+				t.Fatal(err)
+			}
+			target, err := nm.process.prepare("run-1", nil, nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer target.cleanup()
+
+			cmdCtx, killCmd := context.WithCancel(context.Background())
+			defer killCmd()
+			cmd := target.command(cmdCtx, interruptibleArgv(target, "run-1", []string{"sh", "-c", tt.script}), nil)
+			if err := cmd.Start(); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(100 * time.Millisecond)
+			exited := make(chan struct{})
+			var waitErr error
+			go func() {
+				waitErr = cmd.Wait()
+				close(exited)
+			}()
+
+			c := nm.stopAgent("run-1", jsonLineAdapter{}, target, cmd, killCmd, exited)
+			if c == nil || c.clean() != tt.wantClean || (waitErr == nil) != tt.wantClean {
+				t.Fatalf("cancellation = %+v, wait error = %v", c, waitErr)
+			}
+			if seq := nm.reportCancellation(context.Background(), c, 1); seq != 1+len(tt.wantPhases) {
+				t.Errorf("seq = %d", seq)
+			}
+			waitFor(t, func() bool { return len(events()) == len(tt.wantPhases) })
+			phases, payload := cancellationPhases(events())
+			if !slices.Equal(phases, tt.wantPhases) {
+				t.Errorf("phases = %v, want %v", phases, tt.wantPhases)
+			}
+			if payload["clean"] != tt.wantClean || payload["reason"] != tt.wantReason {
+				t.Errorf("exited payload = %v", payload)
+			}
+		})
+	}
+}
+
+func TestStopAgent_AlreadyExited(t *testing.T) {
+	nm, _ := newHookTestManager(t)
+	exited := make(chan struct{})
+	close(exited)
+	if c := nm.stopAgent("run-1", jsonLineAdapter{}, nil, nil, nil, exited); c != nil {
+		t.Errorf("cancellation = %+v, want nil", c)
+	}
+}
+
+// TestStopDetached 重启交接模式下停止信号 INT 改为 TERM，Agent 写入退出码后视为已退出
+func TestStopDetached(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process backend requires linux")
+	}
+	nm, _ := newHookTestManager(t)
+	var err error
+	if nm.handover, err = newHandover(HandoverConfig{StateDir: t.TempDir()}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if nm.process, err = newProcessBackend(ProcessConfig{}, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	target, err := nm.process.prepare("run-1", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.cleanup()
+
+	x := &runExecution{runID: "run-1", snapshot: &model.RunSnapshot{}, target: target}
+	st, err := nm.launchDetached(context.Background(), x, []string{"sh", "-c", `trap 'exit 0' TERM; while :; do sleep 0.05; done`}, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := &runOutput{target: target, dir: st.OutputDir}
+	c := nm.stopDetached(st, out, jsonLineAdapter{})
+	if c.signal != "TERM" || !c.clean() {
+		t.Fatalf("cancellation = %+v", c)
+	}
+	if code, ok := out.exitCode(context.Background()); !ok || code != 0 {
+		t.Errorf("exit code = %d, %v", code, ok)
+	}
+}
@@ -0,0 +1,171 @@
+// Package nodemanager 危险命令拦截
+//
+// Agent 执行命令（command 事件，或 Adapter 实现 CommandExtractor 时的 Shell 工具调用）时，
+// 审批闸门按执行快照 command_filter 中的任务规则与内置危险命令检测命令行：
+//   - 命中任务 deny 规则即拦截；命中 allow 规则的命令不再做内置检测
+//   - 内置检测：删除根目录或主目录、下载脚本直接交给 Shell 执行、格式化或覆写磁盘、fork 炸弹
+//   - action 为 block 时上报 error 事件（code 为 command_blocked）并终止 Agent，Run 以 failed 结束；
+//     为 approval 时暂停执行等待人工审批（见 approval.go）
+//
+// 快照中没有 command_filter 时（任务未设置安全配置）只做内置检测，命中时需要审批。
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"regexp"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+// errCodeCommandBlocked 命令被拦截时 error 事件的 code
+const errCodeCommandBlocked = "command_blocked"
+
+// dangerousCommand 内置危险命令
+type dangerousCommand struct {
+	name    string
+	pattern *regexp.Regexp
+	reason  string
+}
+
+// builtinDangerousCommands 内置危险命令检测（任务 allow 规则可豁免）
+var builtinDangerousCommands = []dangerousCommand{
+	{"rm_root", regexp.MustCompile(`\brm\s+(-\S+\s+)+(/|/\*|~/?|\$HOME/?)(\s|$|[;&|])`), "删除根目录或主目录"},
+	{"pipe_to_shell", regexp.MustCompile(`\b(curl|wget)\b[^;&|]*\|\s*(sudo\s+)?(ba|da|k|z)?sh\b`), "下载脚本直接交给 Shell 执行"},
+	{"mkfs", regexp.MustCompile(`\bmkfs(\.\w+)?\s`), "格式化磁盘"},
+	{"dd_device", regexp.MustCompile(`\bdd\b[^;&|]*\bof=/dev/(sd|hd|vd|xvd|nvme|mmcblk|disk)`), "覆写块设备"},
+	{"redirect_device", regexp.MustCompile(`>\s*/dev/(sd|hd|vd|xvd|nvme|mmcblk|disk)`), "覆写块设备"},
+	{"fork_bomb", regexp.MustCompile(`:\(\)\s*\{\s*:\s*\|\s*:\s*&\s*\}\s*;\s*:`), "fork 炸弹"},
+}
+
+// ParseCommandFilter 从任务快照中解析命令过滤规则（snapshot.command_filter）
+func ParseCommandFilter(snapshot *model.RunSnapshot) *model.CommandFilter {
+	return snapshot.CommandFilter
+}
+
+// commandFilter 单个 Run 的命令过滤
+type commandFilter struct {
+	allow     []*regexp.Regexp
+	deny      []*regexp.Regexp
+	action    model.CommandFilterAction
+	extractor adapter.CommandExtractor // nil 时只检测 command 事件
+}
+
+// commandMatch 命中的规则
+type commandMatch struct {
+	command string
+	rule    string // 任务规则的正则表达式，或内置检测名称
+	reason  string
+}
+
+// newCommandFilter 创建命令过滤（rules 为 nil 时只做内置检测，命中时需要审批）
+func newCommandFilter(rules *model.CommandFilter, a adapter.Adapter) *commandFilter {
+	f := &commandFilter{action: model.CommandFilterApproval}
+	f.extractor, _ = a.(adapter.CommandExtractor)
+	if rules == nil {
+		return f
+	}
+	if rules.Action != "" {
+		f.action = rules.Action
+	}
+	f.allow = compileCommandRules(rules.Allow)
+	f.deny = compileCommandRules(rules.Deny)
+	return f
+}
+
+// compileCommandRules 编译任务规则（API Server 已校验，无效的规则忽略）
+func compileCommandRules(patterns []string) []*regexp.Regexp {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			log.Printf("[Command] 忽略无效的命令规则 %q: %v", p, err)
+			continue
+		}
+		out = append(out, re)
+	}
+	return out
+}
+
+// command 事件中 Agent 执行的命令行（不是执行命令时返回空）
+func (f *commandFilter) command(event *adapter.CanonicalEvent) string {
+	if f.extractor != nil {
+		if command := f.extractor.ToolCommand(event); command != "" {
+			return command
+		}
+	}
+	return adapter.CommandLine(event)
+}
+
+// match 检测事件中执行的命令，命中规则时返回匹配结果
+func (f *commandFilter) match(event *adapter.CanonicalEvent) *commandMatch {
+	if f == nil {
+		return nil
+	}
+	command := f.command(event)
+	if command == "" {
+		return nil
+	}
+	for _, re := range f.deny {
+		if re.MatchString(command) {
+			return &commandMatch{command: command, rule: re.String(), reason: "命中任务禁止的命令规则"}
+		}
+	}
+	for _, re := range f.allow {
+		if re.MatchString(command) {
+			return nil
+		}
+	}
+	for _, d := range builtinDangerousCommands {
+		if d.pattern.MatchString(command) {
+			return &commandMatch{command: command, rule: d.name, reason: d.reason}
+		}
+	}
+	return nil
+}
+
+// approval 事件中的命令是否命中规则，需要审批时返回 approval_required 事件内容（拦截时为 nil）
+func (f *commandFilter) approval(event *adapter.CanonicalEvent) (map[string]interface{}, bool) {
+	m := f.match(event)
+	if m == nil {
+		return nil, false
+	}
+	if f.action == model.CommandFilterBlock {
+		// 由 blockCommand 拦截，不再等待审批
+		return nil, true
+	}
+	return map[string]interface{}{
+		"type":      string(model.ApprovalTypeDangerousOp),
+		"operation": "command",
+		"command":   m.command,
+		"rule":      m.rule,
+		"reason":    "危险命令：" + m.reason,
+		"context":   event.Payload,
+	}, true
+}
+
+// blockCommand 拦截命中规则的命令：上报 error 事件并终止 Agent，返回下一个事件序号
+func (g *approvalGate) blockCommand(ctx context.Context, event *adapter.CanonicalEvent, seq int) int {
+	if g.commands == nil || g.commands.action != model.CommandFilterBlock {
+		return seq
+	}
+	m := g.commands.match(event)
+	if m == nil {
+		return seq
+	}
+	g.nm.reportEvent(ctx, g.runID, seq, string(model.EventTypeError), map[string]interface{}{
+		"message":     fmt.Sprintf("命令被拦截（%s）: %s", m.reason, m.command),
+		"code":        errCodeCommandBlocked,
+		"recoverable": false,
+		"command":     m.command,
+		"rule":        m.rule,
+	})
+	if g.violation == "" {
+		g.violation = fmt.Sprintf("命令 %q 被拦截（%s），已终止 Agent", m.command, m.reason)
+		log.Printf("[Command] 任务 %s 执行了被拦截的命令 %q（%s），终止 Agent", g.runID, m.command, m.rule)
+		g.kill()
+	}
+	return seq + 1
+}
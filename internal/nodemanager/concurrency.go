@@ -0,0 +1,181 @@
+// Package nodemanager 执行并发控制
+//
+// 节点的最大并发数、为 high 优先级 Run 预留的槽位与各 Agent 类型的并发上限来自 nodemanager.yaml
+// node.concurrency，控制面通过心跳响应下发的覆盖（PATCH /api/v1/nodes/{id}/capacity）合并后生效。
+// 领取 Run 时检查槽位：普通 Run 不占用预留槽位，同类型 Agent 不超过上限。暂不能启动的 Run 保持 assigned，
+// 在本节点排队，有 Run 结束或配置变化时按 high 优先、先到先得重试（REST 模式下轮询也会重试）。
+package nodemanager
+
+import (
+	"context"
+	"log"
+	"reflect"
+	"sort"
+
+	"agents-admin/internal/shared/model"
+)
+
+// runSlot 运行中 Run 占用的执行槽位
+type runSlot struct {
+	agentType string
+	high      bool
+}
+
+// waitingRun 因并发限制暂未启动的 Run
+type waitingRun struct {
+	ctx    context.Context
+	run    map[string]interface{}
+	slot   runSlot
+	reason string
+	seq    int64
+}
+
+// runSlotOf 从分配的 Run 中解析优先级与 Agent 类型（snapshot.agent.type）
+func runSlotOf(run map[string]interface{}) runSlot {
+	var slot runSlot
+	if p, _ := run["priority"].(string); p == string(model.PriorityHigh) {
+		slot.high = true
+	}
+	if snapshot, err := parseRunSnapshot(run["snapshot"]); err == nil {
+		slot.agentType = snapshot.Agent.Type
+	}
+	return slot
+}
+
+// admitRun 判断新的 Run 能否启动，返回不能启动的原因（可以启动时为空）
+func admitRun(c model.NodeConcurrency, active []runSlot, next runSlot) string {
+	if len(active) >= c.MaxConcurrent {
+		return "max_concurrent"
+	}
+	if !next.high && len(active) >= c.MaxConcurrent-c.ReservedHighPriority {
+		return "reserved_high_priority"
+	}
+	if limit := c.AgentTypeLimits[next.agentType]; limit > 0 {
+		n := 0
+		for _, s := range active {
+			if s.agentType == next.agentType {
+				n++
+			}
+		}
+		if n >= limit {
+			return "agent_type_limit"
+		}
+	}
+	return ""
+}
+
+// concurrencyLocked 当前生效的并发配置（调用方持有 nm.mu）
+func (nm *NodeManager) concurrencyLocked() model.NodeConcurrency {
+	return nm.config.Concurrency.Apply(nm.capacityOverride).Normalize()
+}
+
+// concurrency 当前生效的并发配置
+func (nm *NodeManager) concurrency() model.NodeConcurrency {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.concurrencyLocked()
+}
+
+// activeSlotsLocked 占用执行槽位的 Run（暂停中的 Run 不占用），调用方持有 nm.mu
+func (nm *NodeManager) activeSlotsLocked(paused []string) []runSlot {
+	pausedSet := make(map[string]bool, len(paused))
+	for _, id := range paused {
+		pausedSet[id] = true
+	}
+	slots := make([]runSlot, 0, len(nm.running))
+	for runID := range nm.running {
+		if !pausedSet[runID] {
+			slots = append(slots, nm.slots[runID])
+		}
+	}
+	return slots
+}
+
+// admitLocked 检查 Run 能否占用槽位：可以时登记槽位，否则加入等待队列（调用方持有 nm.mu）
+func (nm *NodeManager) admitLocked(ctx context.Context, runID string, run map[string]interface{}, paused []string) bool {
+	slot := runSlotOf(run)
+	reason := admitRun(nm.concurrencyLocked(), nm.activeSlotsLocked(paused), slot)
+	if reason == "" {
+		delete(nm.waiting, runID)
+		if nm.slots == nil {
+			nm.slots = make(map[string]runSlot)
+		}
+		nm.slots[runID] = slot
+		return true
+	}
+
+	if nm.waiting == nil {
+		nm.waiting = make(map[string]*waitingRun)
+	}
+	w, exists := nm.waiting[runID]
+	if !exists {
+		nm.waitSeq++
+		w = &waitingRun{seq: nm.waitSeq}
+		nm.waiting[runID] = w
+	}
+	if w.reason != reason {
+		log.Printf("[nodemanager.run.deferred] run_id=%s agent_type=%s high=%v reason=%s", runID, slot.agentType, slot.high, reason)
+	}
+	w.ctx, w.run, w.slot, w.reason = ctx, run, slot, reason
+	return false
+}
+
+// startWaitingRuns 按 high 优先、先到先得重试等待中的 Run
+func (nm *NodeManager) startWaitingRuns() {
+	nm.mu.Lock()
+	waiting := make([]*waitingRun, 0, len(nm.waiting))
+	for _, w := range nm.waiting {
+		waiting = append(waiting, w)
+	}
+	nm.mu.Unlock()
+
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].slot.high != waiting[j].slot.high {
+			return waiting[i].slot.high
+		}
+		return waiting[i].seq < waiting[j].seq
+	})
+	for _, w := range waiting {
+		if w.ctx.Err() == nil {
+			nm.startAssignedRun(w.ctx, w.run)
+		}
+	}
+}
+
+// pruneWaitingRuns 移除已不再分配给本节点的等待 Run（REST 轮询得到的完整分配列表）
+func (nm *NodeManager) pruneWaitingRuns(assigned []map[string]interface{}) {
+	ids := make(map[string]bool, len(assigned))
+	for _, run := range assigned {
+		if id, _ := run["id"].(string); id != "" {
+			ids[id] = true
+		}
+	}
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	for id := range nm.waiting {
+		if !ids[id] {
+			delete(nm.waiting, id)
+		}
+	}
+}
+
+// setCapacityOverride 应用心跳响应下发的并发配置覆盖（o 为 nil 表示 API Server 未下发，保持当前配置）
+func (nm *NodeManager) setCapacityOverride(o *model.NodeConcurrencyOverride) {
+	if o == nil {
+		return
+	}
+	if o.IsEmpty() {
+		o = nil
+	}
+	nm.mu.Lock()
+	changed := !reflect.DeepEqual(nm.capacityOverride, o)
+	nm.capacityOverride = o
+	c := nm.concurrencyLocked()
+	nm.mu.Unlock()
+	if !changed {
+		return
+	}
+	log.Printf("[nodemanager.capacity.updated] max_concurrent=%d reserved_high_priority=%d agent_type_limits=%v",
+		c.MaxConcurrent, c.ReservedHighPriority, c.AgentTypeLimits)
+	nm.startWaitingRuns()
+}
@@ -0,0 +1,119 @@
+package nodemanager
+
+import (
+	"context"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestRunSlotOf(t *testing.T) {
+	slot := runSlotOf(map[string]interface{}{
+		"priority": "high",
+		"snapshot": map[string]interface{}{"agent": map[string]interface{}{"type": "qwen-code"}, "prompt": "p"},
+	})
+	if !slot.high || slot.agentType != "qwen-code" {
+		t.Errorf("slot = %+v", slot)
+	}
+	if slot := runSlotOf(map[string]interface{}{"priority": "normal"}); slot.high || slot.agentType != "" {
+		t.Errorf("slot without snapshot = %+v", slot)
+	}
+}
+
+func TestAdmitRun(t *testing.T) {
+	c := model.NodeConcurrency{MaxConcurrent: 3, ReservedHighPriority: 1, AgentTypeLimits: map[string]int{"gemini": 1}}
+	normal := runSlot{agentType: "qwen-code"}
+	high := runSlot{agentType: "qwen-code", high: true}
+
+	if got := admitRun(c, nil, normal); got != "" {
+		t.Errorf("empty node: %q", got)
+	}
+	if got := admitRun(c, []runSlot{normal, normal}, normal); got != "reserved_high_priority" {
+		t.Errorf("normal run into reserved slot: %q", got)
+	}
+	if got := admitRun(c, []runSlot{normal, normal}, high); got != "" {
+		t.Errorf("high run into reserved slot: %q", got)
+	}
+	if got := admitRun(c, []runSlot{normal, normal, high}, high); got != "max_concurrent" {
+		t.Errorf("full node: %q", got)
+	}
+	if got := admitRun(c, []runSlot{{agentType: "gemini"}}, runSlot{agentType: "gemini", high: true}); got != "agent_type_limit" {
+		t.Errorf("agent type limit: %q", got)
+	}
+}
+
+func TestNodeConcurrencyApply(t *testing.T) {
+	base := model.NodeConcurrency{MaxConcurrent: 4, AgentTypeLimits: map[string]int{"gemini": 1}}
+	maxConcurrent, reserved := 6, 8
+	got := base.Apply(&model.NodeConcurrencyOverride{
+		MaxConcurrent:        &maxConcurrent,
+		ReservedHighPriority: &reserved,
+		AgentTypeLimits:      map[string]int{"qwen-code": 2},
+	}).Normalize()
+
+	if got.MaxConcurrent != 6 || got.ReservedHighPriority != 5 {
+		t.Errorf("concurrency = %+v, want max 6 with reserved clamped to 5", got)
+	}
+	if got.AgentTypeLimits["gemini"] != 1 || got.AgentTypeLimits["qwen-code"] != 2 {
+		t.Errorf("agent type limits = %v", got.AgentTypeLimits)
+	}
+	if _, ok := base.AgentTypeLimits["qwen-code"]; ok {
+		t.Error("Apply must not modify the base limits")
+	}
+	if got := (model.NodeConcurrency{}).Apply(nil).Normalize(); got.MaxConcurrent != model.DefaultNodeMaxConcurrent {
+		t.Errorf("default max_concurrent = %d", got.MaxConcurrent)
+	}
+}
+
+func TestAdmitLocked_DefersAndOverrideClears(t *testing.T) {
+	nm := &NodeManager{
+		config:  Config{Concurrency: model.NodeConcurrency{MaxConcurrent: 1}},
+		running: map[string]context.CancelFunc{"run-1": func() {}},
+		slots:   map[string]runSlot{"run-1": {}},
+	}
+	ctx := context.Background()
+	run := map[string]interface{}{"id": "run-2"}
+
+	nm.mu.Lock()
+	admitted := nm.admitLocked(ctx, "run-2", run, nil)
+	nm.mu.Unlock()
+	if admitted {
+		t.Fatal("run-2 should be deferred on a full node")
+	}
+	if w := nm.waiting["run-2"]; w == nil || w.reason != "max_concurrent" {
+		t.Fatalf("waiting = %+v", nm.waiting)
+	}
+
+	// 暂停中的 Run 不占用槽位
+	nm.mu.Lock()
+	admitted = nm.admitLocked(ctx, "run-2", run, []string{"run-1"})
+	nm.mu.Unlock()
+	if !admitted {
+		t.Fatal("paused run should release its slot")
+	}
+	if _, ok := nm.waiting["run-2"]; ok || len(nm.slots) != 2 {
+		t.Errorf("waiting = %v slots = %v", nm.waiting, nm.slots)
+	}
+
+	maxConcurrent := 4
+	nm.setCapacityOverride(&model.NodeConcurrencyOverride{MaxConcurrent: &maxConcurrent})
+	if got := nm.concurrency().MaxConcurrent; got != 4 {
+		t.Errorf("override max_concurrent = %d", got)
+	}
+	nm.setCapacityOverride(nil)
+	if got := nm.concurrency().MaxConcurrent; got != 4 {
+		t.Errorf("nil override should keep current config, got %d", got)
+	}
+	nm.setCapacityOverride(&model.NodeConcurrencyOverride{})
+	if got := nm.concurrency().MaxConcurrent; got != 1 {
+		t.Errorf("empty override should restore local config, got %d", got)
+	}
+}
+
+func TestPruneWaitingRuns(t *testing.T) {
+	nm := &NodeManager{waiting: map[string]*waitingRun{"run-1": {}, "run-2": {}}}
+	nm.pruneWaitingRuns([]map[string]interface{}{{"id": "run-2"}})
+	if _, ok := nm.waiting["run-1"]; ok || len(nm.waiting) != 1 {
+		t.Errorf("waiting = %v", nm.waiting)
+	}
+}
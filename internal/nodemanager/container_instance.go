@@ -1,7 +1,7 @@
 // Package nodemanager Agent 工作线程
 //
-// P2-1 重构：将 Docker 操作从 API Server 下沉到 NodeManager
-// 负责轮询 API Server 获取待处理的 Agent，然后执行实际的 Docker 操作
+// P2-1 重构：将容器操作从 API Server 下沉到 NodeManager
+// 负责轮询 API Server 获取待处理的 Agent，然后通过容器运行时执行实际的容器操作
 package nodemanager
 
 import (
@@ -12,9 +12,10 @@ import (
 	"io"
 	"log"
 	"net/http"
-	"os/exec"
 	"strings"
 	"time"
+
+	"agents-admin/internal/shared/model"
 )
 
 // AgentWorker Instance 工作线程
@@ -188,40 +189,22 @@ func (w *AgentWorker) startOrCreateInstance(ctx context.Context, inst instanceIn
 
 // isContainerExists 检查容器是否存在
 func (w *AgentWorker) isContainerExists(ctx context.Context, containerName string) bool {
-	cmd := exec.CommandContext(ctx, "docker", "inspect", containerName)
-	return cmd.Run() == nil
+	_, err := w.config.containers().Inspect(ctx, containerName)
+	return err == nil
 }
 
 // isContainerRunning 检查容器是否运行中
 func (w *AgentWorker) isContainerRunning(ctx context.Context, containerName string) (bool, error) {
-	cmd := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", containerName)
-	out, err := cmd.Output()
+	info, err := w.config.containers().Inspect(ctx, containerName)
 	if err != nil {
 		return false, err
 	}
-	return strings.TrimSpace(string(out)) == "true", nil
+	return info.Running, nil
 }
 
 // listAllContainerNames 列出所有容器名称（包含 stopped）
 func (w *AgentWorker) listAllContainerNames(ctx context.Context) ([]string, error) {
-	cmd := exec.CommandContext(ctx, "docker", "ps", "-a", "--format", "{{.Names}}")
-	out, err := cmd.Output()
-	if err != nil {
-		return nil, err
-	}
-	raw := strings.TrimSpace(string(out))
-	if raw == "" {
-		return nil, nil
-	}
-	lines := strings.Split(raw, "\n")
-	var names []string
-	for _, line := range lines {
-		n := strings.TrimSpace(line)
-		if n != "" {
-			names = append(names, n)
-		}
-	}
-	return names, nil
+	return w.config.containers().List(ctx, true)
 }
 
 // resolveContainerName 解析实例对应的容器名：
@@ -273,23 +256,13 @@ func (w *AgentWorker) startExistingContainer(ctx context.Context, inst instanceI
 		return
 	}
 
-	// 幂等：已运行则直接回填 running（并修正 container_name）
-	if running, err := w.isContainerRunning(ctx, inst.ContainerName); err == nil && running {
-		if err := w.updateInstanceStatus(ctx, inst.ID, "running", &inst.ContainerName); err != nil {
-			log.Printf("[AgentWorker] 更新状态失败: %v", err)
-		}
-		return
-	}
-
-	cmd := exec.CommandContext(ctx, "docker", "start", inst.ContainerName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		log.Printf("[AgentWorker] 启动容器失败: %v, 输出: %s", err, string(output))
+	if err := w.startContainer(ctx, inst.ContainerName); err != nil {
+		log.Printf("[AgentWorker] 启动容器失败: %v", err)
 		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
 		return
 	}
 
-	// 更新状态为 running
+	// 更新状态为 running（并修正 container_name）
 	if err := w.updateInstanceStatus(ctx, inst.ID, "running", &inst.ContainerName); err != nil {
 		log.Printf("[AgentWorker] 更新状态失败: %v", err)
 		return
@@ -298,6 +271,14 @@ func (w *AgentWorker) startExistingContainer(ctx context.Context, inst instanceI
 	log.Printf("[AgentWorker] 容器 %s 启动成功", inst.ContainerName)
 }
 
+// startContainer 启动容器（幂等：已运行则直接返回）
+func (w *AgentWorker) startContainer(ctx context.Context, containerName string) error {
+	if running, err := w.isContainerRunning(ctx, containerName); err == nil && running {
+		return nil
+	}
+	return w.config.containers().Start(ctx, containerName)
+}
+
 // stopInstance 停止实例容器
 func (w *AgentWorker) stopInstance(ctx context.Context, inst instanceInfo) {
 	resolvedName, ok := w.resolveContainerName(ctx, inst)
@@ -318,10 +299,8 @@ func (w *AgentWorker) stopInstance(ctx context.Context, inst instanceInfo) {
 
 	log.Printf("[AgentWorker] 停止容器: %s", inst.ContainerName)
 
-	cmd := exec.CommandContext(ctx, "docker", "stop", "-t", "10", inst.ContainerName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		log.Printf("[AgentWorker] 停止容器失败: %v, 输出: %s", err, string(output))
+	if err := w.config.containers().Stop(ctx, inst.ContainerName, 10*time.Second); err != nil {
+		log.Printf("[AgentWorker] 停止容器失败: %v", err)
 		// 即使失败也尝试标记为 stopped（避免界面卡死）
 	}
 
@@ -355,69 +334,73 @@ func (w *AgentWorker) createInstance(ctx context.Context, inst instanceInfo) {
 		return
 	}
 
+	if err := w.buildInstanceContainer(ctx, inst, containerName); err != nil {
+		log.Printf("[AgentWorker] %v", err)
+		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
+		return
+	}
+
+	// 更新状态为 running，回填容器名称
+	if err := w.updateInstanceStatus(ctx, inst.ID, "running", &containerName); err != nil {
+		log.Printf("[AgentWorker] 更新状态失败: %v", err)
+		return
+	}
+
+	log.Printf("[AgentWorker] 实例 %s 创建成功，容器: %s", inst.ID, containerName)
+}
+
+// buildInstanceContainer 准备账号 Volume 并创建实例容器
+func (w *AgentWorker) buildInstanceContainer(ctx context.Context, inst instanceInfo, containerName string) error {
 	// 获取账号信息（包含 Volume 名称）
 	account, err := w.getAccount(ctx, inst.AccountID)
 	if err != nil {
-		log.Printf("[AgentWorker] 获取账号失败: %v", err)
-		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
-		return
+		return fmt.Errorf("获取账号失败: %w", err)
 	}
 
 	if account.VolumeName == "" {
-		log.Printf("[AgentWorker] 账号没有 Volume: %s", inst.AccountID)
-		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
-		return
+		return fmt.Errorf("账号没有 Volume: %s", inst.AccountID)
 	}
 
 	// 获取 Agent 类型配置（提前获取，ensureVolume 需要 authDir）
 	agentType, err := w.getAgentType(ctx, inst.AgentTypeID)
 	if err != nil {
-		log.Printf("[AgentWorker] 获取 Agent 类型失败: %v", err)
-		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
-		return
+		return fmt.Errorf("获取 Agent 类型失败: %w", err)
 	}
 
 	// 确保 volume 存在（本地有则跳过，否则从 MinIO 下载）
 	if err := w.ensureVolumeFromArchive(ctx, inst.AccountID, account.VolumeName, agentType.AuthDir); err != nil {
-		log.Printf("[AgentWorker] 确保 volume 可用失败: %v", err)
-		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
-		return
+		return fmt.Errorf("确保 volume 可用失败: %w", err)
 	}
 
-	// 创建 Docker 容器
-	// docker run -d --name <container> -v <volume>:<auth_dir> -t -i <image>
-	runArgs := []string{
-		"run", "-d",
-		"--name", containerName,
-		// 标记为系统管理容器（用于孤儿清理/审计）
-		"--label", "agents-admin.managed=true",
-		"--label", fmt.Sprintf("agents-admin.instance_id=%s", inst.ID),
-		"--label", fmt.Sprintf("agents-admin.account_id=%s", inst.AccountID),
-		"--label", fmt.Sprintf("agents-admin.node_id=%s", inst.NodeID),
-		"-v", fmt.Sprintf("%s:%s", account.VolumeName, agentType.AuthDir),
-		"--restart", "unless-stopped",
-		"-t",
-		"-i",
-		agentType.Image,
-	}
+	// 创建容器
+	// run -d --name <container> -v <volume>:<auth_dir> -t -i <image>
+	spec := instanceContainerSpec(inst, containerName, account.VolumeName, agentType.AuthDir, agentType.Image)
 
-	log.Printf("[AgentWorker] 执行: docker %v", runArgs)
+	log.Printf("[AgentWorker] 执行: %s %v", w.config.containers().Name(), createArgs(spec))
 
-	cmd := exec.CommandContext(ctx, "docker", runArgs...)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		log.Printf("[AgentWorker] 创建容器失败: %v, 输出: %s", err, string(output))
-		_ = w.updateInstanceStatus(ctx, inst.ID, "error", nil)
-		return
+	if _, err := w.config.containers().Create(ctx, spec); err != nil {
+		return fmt.Errorf("创建容器失败: %w", err)
 	}
+	return nil
+}
 
-	// 更新状态为 running，回填容器名称
-	if err := w.updateInstanceStatus(ctx, inst.ID, "running", &containerName); err != nil {
-		log.Printf("[AgentWorker] 更新状态失败: %v", err)
-		return
+// instanceContainerSpec 实例容器参数
+func instanceContainerSpec(inst instanceInfo, containerName, volumeName, authDir, image string) ContainerSpec {
+	return ContainerSpec{
+		Name:  containerName,
+		Image: image,
+		// 标记为系统管理容器（用于孤儿清理/审计）
+		Labels: map[string]string{
+			"agents-admin.managed":     "true",
+			"agents-admin.instance_id": inst.ID,
+			"agents-admin.account_id":  inst.AccountID,
+			"agents-admin.node_id":     inst.NodeID,
+		},
+		Volumes:     []string{fmt.Sprintf("%s:%s", volumeName, authDir)},
+		Restart:     "unless-stopped",
+		TTY:         true,
+		Interactive: true,
 	}
-
-	log.Printf("[AgentWorker] 实例 %s 创建成功，容器: %s", inst.ID, containerName)
 }
 
 // checkInstanceCreation 检查容器创建状态
@@ -439,6 +422,60 @@ func (w *AgentWorker) checkInstanceCreation(ctx context.Context, inst instanceIn
 	}
 }
 
+// applyDesired 按期望状态对齐实例容器，返回观测结果（期望状态为空时返回 nil）
+//
+// 期望运行：容器不存在则创建，已停止则启动；期望停止：容器运行中则停止，不存在视为已停止。
+func (w *AgentWorker) applyDesired(ctx context.Context, d model.DesiredInstance) *model.ObservedInstance {
+	if d.State != model.DesiredRunning && d.State != model.DesiredStopped {
+		return nil
+	}
+	inst := instanceInfo{
+		ID:            d.ID,
+		Name:          d.Name,
+		AccountID:     d.AccountID,
+		AgentTypeID:   d.AgentTypeID,
+		ContainerName: d.ContainerName,
+		NodeID:        w.config.NodeID,
+		Status:        string(d.Status),
+	}
+	obs := &model.ObservedInstance{ID: d.ID, Status: d.Status}
+	name, exists := w.resolveContainerName(ctx, inst)
+
+	if d.State == model.DesiredStopped {
+		obs.State = model.ObservedStopped
+		if !exists {
+			return obs
+		}
+		obs.ContainerName = name
+		if running, err := w.isContainerRunning(ctx, name); err == nil && running {
+			log.Printf("[AgentWorker] 停止容器: %s (实例 %s)", name, d.ID)
+			if err := w.config.containers().Stop(ctx, name, 10*time.Second); err != nil {
+				// 与 stopInstance 一致：停止失败也标记为 stopped，避免界面卡死
+				log.Printf("[AgentWorker] 停止容器失败: %v", err)
+			}
+		}
+		return obs
+	}
+
+	if exists {
+		if err := w.startContainer(ctx, name); err != nil {
+			log.Printf("[AgentWorker] 启动容器 %s 失败: %v", name, err)
+			obs.State, obs.Error = model.ObservedError, err.Error()
+			return obs
+		}
+	} else {
+		name = fmt.Sprintf("agent_%s", d.ID)
+		log.Printf("[AgentWorker] 创建实例容器: %s (%s)", d.ID, name)
+		if err := w.buildInstanceContainer(ctx, inst, name); err != nil {
+			log.Printf("[AgentWorker] 实例 %s: %v", d.ID, err)
+			obs.State, obs.Error = model.ObservedError, err.Error()
+			return obs
+		}
+	}
+	obs.State, obs.ContainerName = model.ObservedRunning, name
+	return obs
+}
+
 // reconcileInstances 对账：将“稳定状态实例”的 DB 状态与容器真实状态对齐
 func (w *AgentWorker) reconcileInstances(ctx context.Context) {
 	instances, err := w.fetchAllInstances(ctx)
@@ -512,27 +549,15 @@ type containerMeta struct {
 }
 
 func (w *AgentWorker) inspectContainerMeta(ctx context.Context, containerName string) (*containerMeta, error) {
-	// 输出格式：image|running|status|managedLabel
-	// managedLabel 可能为空
-	cmd := exec.CommandContext(
-		ctx,
-		"docker", "inspect",
-		"-f", `{{.Config.Image}}|{{.State.Running}}|{{.State.Status}}|{{index .Config.Labels "agents-admin.managed"}}`,
-		containerName,
-	)
-	out, err := cmd.Output()
+	info, err := w.config.containers().Inspect(ctx, containerName)
 	if err != nil {
 		return nil, err
 	}
-	parts := strings.Split(strings.TrimSpace(string(out)), "|")
-	if len(parts) < 4 {
-		return nil, fmt.Errorf("unexpected inspect output: %q", strings.TrimSpace(string(out)))
-	}
 	return &containerMeta{
-		image:   parts[0],
-		running: parts[1] == "true",
-		status:  parts[2],
-		managed: parts[3] == "true",
+		image:   info.Image,
+		running: info.Running,
+		status:  info.Status,
+		managed: info.Labels["agents-admin.managed"] == "true",
 	}, nil
 }
 
@@ -596,10 +621,8 @@ func (w *AgentWorker) cleanupOrphanInstanceContainers(ctx context.Context, insta
 		}
 
 		log.Printf("[AgentWorker] 清理孤儿容器: %s (image=%s, status=%s, running=%v, managed=%v)", name, meta.image, meta.status, meta.running, meta.managed)
-		rmCmd := exec.CommandContext(ctx, "docker", "rm", "-f", name)
-		out, err := rmCmd.CombinedOutput()
-		if err != nil {
-			log.Printf("[AgentWorker] 删除孤儿容器失败: %s: %v, 输出: %s", name, err, string(out))
+		if err := w.config.containers().Remove(ctx, name); err != nil {
+			log.Printf("[AgentWorker] 删除孤儿容器失败: %s: %v", name, err)
 			continue
 		}
 	}
@@ -607,9 +630,10 @@ func (w *AgentWorker) cleanupOrphanInstanceContainers(ctx context.Context, insta
 
 // ensureVolumeFromArchive 确保本地 volume 可用：本地已存在则跳过，否则从 API Server 下载
 func (w *AgentWorker) ensureVolumeFromArchive(ctx context.Context, accountID, volumeName, mountPath string) error {
+	rt := w.config.containers()
+
 	// 1. 检查 volume 是否已存在
-	cmd := exec.CommandContext(ctx, "docker", "volume", "inspect", volumeName)
-	if cmd.Run() == nil {
+	if rt.VolumeExists(ctx, volumeName) {
 		log.Printf("[AgentWorker] Volume %s 已存在，跳过下载", volumeName)
 		return nil
 	}
@@ -636,30 +660,13 @@ func (w *AgentWorker) ensureVolumeFromArchive(ctx context.Context, accountID, vo
 	}
 
 	// 3. 创建 volume
-	createCmd := exec.CommandContext(ctx, "docker", "volume", "create", volumeName)
-	if output, err := createCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("创建 volume 失败: %w, 输出: %s", err, string(output))
-	}
-
-	// 4. 通过临时容器导入 tar 数据到 volume
-	containerName := fmt.Sprintf("import_%s_%d", volumeName, time.Now().UnixNano())
-	runCmd := exec.CommandContext(ctx, "docker", "create",
-		"--name", containerName,
-		"-v", fmt.Sprintf("%s:%s", volumeName, mountPath),
-		"alpine:latest", "true")
-	if output, err := runCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("创建导入容器失败: %w, 输出: %s", err, string(output))
-	}
-	defer func() {
-		rmCmd := exec.CommandContext(ctx, "docker", "rm", "-f", containerName)
-		rmCmd.Run()
-	}()
-
-	// docker cp - (stdin) -> container:mountPath
-	cpCmd := exec.CommandContext(ctx, "docker", "cp", "-", fmt.Sprintf("%s:%s", containerName, mountPath))
-	cpCmd.Stdin = resp.Body
-	if output, err := cpCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("导入 volume 数据失败: %w, 输出: %s", err, string(output))
+	if err := rt.CreateVolume(ctx, volumeName); err != nil {
+		return fmt.Errorf("创建 volume 失败: %w", err)
+	}
+
+	// 4. 导入 tar 数据到 volume
+	if err := rt.RestoreVolume(ctx, volumeName, mountPath, resp.Body); err != nil {
+		return err
 	}
 
 	log.Printf("[AgentWorker] Volume %s 从归档恢复成功", volumeName)
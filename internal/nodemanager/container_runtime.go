@@ -0,0 +1,440 @@
+// Package nodemanager 容器运行时
+//
+// NodeManager 通过容器运行时的命令行管理 Agent 实例容器、执行 Run、复制 Workspace：
+//   - docker：docker CLI（默认）
+//   - podman：podman CLI（参数与 docker 兼容，适用于只提供 podman 的 RHEL 系主机）
+//   - containerd：nerdctl CLI（支持 --namespace），不提供 Docker 兼容 API
+//
+// 认证流程与 Web 终端通过 Docker 兼容 API 访问容器（见 APISocket），
+// containerd 没有该 API，对应功能不可用。
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 容器运行时名称
+const (
+	ContainerRuntimeDocker     = "docker"
+	ContainerRuntimePodman     = "podman"
+	ContainerRuntimeContainerd = "containerd"
+)
+
+// ContainerRuntime 容器运行时接口
+type ContainerRuntime interface {
+	// Name 运行时名称（docker/podman/containerd）
+	Name() string
+	// Create 创建并在后台启动容器，返回容器 ID
+	Create(ctx context.Context, spec ContainerSpec) (string, error)
+	// Start 启动已存在的容器
+	Start(ctx context.Context, name string) error
+	// Stop 停止容器，timeout 后强制终止
+	Stop(ctx context.Context, name string, timeout time.Duration) error
+	// Remove 强制删除容器
+	Remove(ctx context.Context, name string) error
+	// Pause 冻结容器内全部进程（等待人工审批时使用）
+	Pause(ctx context.Context, name string) error
+	// Unpause 解冻容器
+	Unpause(ctx context.Context, name string) error
+	// Exec 构建在容器内执行 argv 的命令（不启动）；密钥值只通过进程环境传递，不出现在命令参数中
+	Exec(ctx context.Context, name string, argv []string, opts ExecOptions) *exec.Cmd
+	// CopyTo 将宿主机目录的内容复制到容器内目录（目标目录不存在时创建）
+	CopyTo(ctx context.Context, hostDir, name, containerDir string) error
+	// CopyFrom 将容器内路径复制到宿主机
+	CopyFrom(ctx context.Context, name, containerPath, hostPath string) error
+	// List 列出容器名称，all=false 时只列出运行中的容器
+	List(ctx context.Context, all bool) ([]string, error)
+	// Inspect 获取容器信息，容器不存在时返回错误
+	Inspect(ctx context.Context, name string) (*ContainerInfo, error)
+	// Update 调整运行中容器的资源限制（只修改 res 中非零的字段）
+	Update(ctx context.Context, name string, res ContainerResources) error
+	// Logs 获取容器日志（stdout 与 stderr）
+	Logs(ctx context.Context, name string) ([]byte, error)
+	// VolumeExists 数据卷是否存在
+	VolumeExists(ctx context.Context, name string) bool
+	// CreateVolume 创建数据卷
+	CreateVolume(ctx context.Context, name string) error
+	// RestoreVolume 将 tar 归档导入数据卷（归档内路径相对于 mountPath）
+	RestoreVolume(ctx context.Context, volume, mountPath string, archive io.Reader) error
+	// APISocket Docker 兼容 API 的 Unix socket 路径，不提供时为空
+	APISocket() string
+}
+
+// ContainerSpec 创建容器的参数
+type ContainerSpec struct {
+	Name        string
+	Image       string
+	Entrypoint  string            // 覆盖镜像入口（为空时使用镜像默认）
+	Args        []string          // 镜像之后的命令参数
+	Labels      map[string]string // 容器标签
+	Volumes     []string          // 挂载（source:target[:ro]）
+	Ports       []string          // 端口映射（host:container）
+	Restart     string            // 重启策略（如 unless-stopped）
+	TTY         bool
+	Interactive bool
+}
+
+// ExecOptions 容器内执行命令的选项
+type ExecOptions struct {
+	Env        map[string]string // 环境变量（值出现在命令参数中）
+	Secrets    map[string]string // 密钥（只传名称，值通过运行时客户端进程环境传递）
+	WorkingDir string
+	Stdin      bool // 保持 stdin 打开（-i），命令从 stdin 读取输入时需要
+}
+
+// ContainerResources 容器资源限制（0 表示不修改，-1 表示不限制）
+type ContainerResources struct {
+	CPUs        float64 // --cpus
+	MemoryBytes int64   // --memory
+	MemorySwap  int64   // --memory-swap（内存与交换区合计，-1 不限制交换区）
+	PidsLimit   int64   // --pids-limit
+}
+
+// ContainerInfo 容器信息
+type ContainerInfo struct {
+	Image   string
+	Running bool
+	Status  string
+	Labels  map[string]string
+}
+
+// ContainerRuntimeConfig 容器运行时配置
+type ContainerRuntimeConfig struct {
+	Name      string // docker（默认）/ podman / containerd
+	Binary    string // 命令行路径（为空时 docker、podman、nerdctl）
+	Namespace string // containerd 命名空间（nerdctl --namespace，为空时使用 nerdctl 默认值）
+	Socket    string // Docker 兼容 API socket（为空时使用运行时默认路径）
+}
+
+// NewContainerRuntime 按配置创建容器运行时，显式配置的运行时命令行不存在时返回错误
+func NewContainerRuntime(cfg ContainerRuntimeConfig) (ContainerRuntime, error) {
+	r := &cliRuntime{name: cfg.Name, bin: cfg.Binary, socket: cfg.Socket}
+	switch cfg.Name {
+	case "", ContainerRuntimeDocker:
+		r.name = ContainerRuntimeDocker
+		r.bin = orDefault(r.bin, "docker")
+		r.socket = orDefault(r.socket, defaultDockerSocket())
+	case ContainerRuntimePodman:
+		r.bin = orDefault(r.bin, "podman")
+		r.socket = orDefault(r.socket, defaultPodmanSocket())
+	case ContainerRuntimeContainerd:
+		r.bin = orDefault(r.bin, "nerdctl")
+		if cfg.Namespace != "" {
+			r.global = []string{"--namespace", cfg.Namespace}
+		}
+		if cfg.Socket != "" {
+			return nil, errors.New("containerd 运行时不提供 Docker 兼容 API，不能配置 socket")
+		}
+	default:
+		return nil, fmt.Errorf("未知的容器运行时: %s", cfg.Name)
+	}
+	if _, err := exec.LookPath(r.bin); err != nil {
+		// 未显式配置时允许缺少 docker（如只使用 process 执行后端的节点）
+		if cfg.Name == "" {
+			log.Printf("[ContainerRuntime] 警告: 找不到 %s，容器相关功能不可用", r.bin)
+			return r, nil
+		}
+		return nil, fmt.Errorf("容器运行时 %s 的命令行 %s 不可用: %w", r.name, r.bin, err)
+	}
+	return r, nil
+}
+
+// defaultContainerRuntime 未配置容器运行时时使用 docker CLI
+var defaultContainerRuntime ContainerRuntime = &cliRuntime{name: ContainerRuntimeDocker, bin: "docker", socket: defaultDockerSocket()}
+
+// containers 返回配置的容器运行时，未配置时使用 docker CLI
+func (c Config) containers() ContainerRuntime {
+	if c.Containers != nil {
+		return c.Containers
+	}
+	return defaultContainerRuntime
+}
+
+// defaultDockerSocket Docker API socket：DOCKER_HOST 为 unix:// 地址时使用该路径
+func defaultDockerSocket() string {
+	if path, ok := strings.CutPrefix(os.Getenv("DOCKER_HOST"), "unix://"); ok && path != "" {
+		return path
+	}
+	return "/var/run/docker.sock"
+}
+
+// defaultPodmanSocket podman API socket：root 运行时为系统 socket，否则为当前用户的 rootless socket
+func defaultPodmanSocket() string {
+	if os.Geteuid() != 0 {
+		if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+			return filepath.Join(dir, "podman", "podman.sock")
+		}
+	}
+	return "/run/podman/podman.sock"
+}
+
+// cliRuntime 通过 docker 兼容命令行实现的容器运行时
+type cliRuntime struct {
+	name   string
+	bin    string
+	global []string // 每条命令前的全局参数（nerdctl --namespace）
+	socket string
+}
+
+func (r *cliRuntime) Name() string { return r.name }
+
+func (r *cliRuntime) APISocket() string { return r.socket }
+
+// command 构建运行时命令
+func (r *cliRuntime) command(ctx context.Context, args ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, r.bin, append(slices.Clone(r.global), args...)...)
+}
+
+// run 执行运行时命令，失败时错误中包含命令输出
+func (r *cliRuntime) run(ctx context.Context, args ...string) ([]byte, error) {
+	out, err := r.command(ctx, args...).CombinedOutput()
+	if err != nil {
+		return out, fmt.Errorf("%s %s 失败: %w, 输出: %s", r.bin, args[0], err, strings.TrimSpace(string(out)))
+	}
+	return out, nil
+}
+
+func (r *cliRuntime) Create(ctx context.Context, spec ContainerSpec) (string, error) {
+	out, err := r.run(ctx, createArgs(spec)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// createArgs 生成 run -d 参数（标签按名称排序，保证命令稳定）
+func createArgs(spec ContainerSpec) []string {
+	args := []string{"run", "-d", "--name", spec.Name}
+	for _, k := range sortedKeys(spec.Labels) {
+		args = append(args, "--label", k+"="+spec.Labels[k])
+	}
+	if spec.Entrypoint != "" {
+		args = append(args, "--entrypoint", spec.Entrypoint)
+	}
+	for _, p := range spec.Ports {
+		args = append(args, "-p", p)
+	}
+	for _, v := range spec.Volumes {
+		args = append(args, "-v", v)
+	}
+	if spec.Restart != "" {
+		args = append(args, "--restart", spec.Restart)
+	}
+	if spec.TTY {
+		args = append(args, "-t")
+	}
+	if spec.Interactive {
+		args = append(args, "-i")
+	}
+	args = append(args, spec.Image)
+	return append(args, spec.Args...)
+}
+
+func (r *cliRuntime) Start(ctx context.Context, name string) error {
+	_, err := r.run(ctx, "start", name)
+	return err
+}
+
+func (r *cliRuntime) Stop(ctx context.Context, name string, timeout time.Duration) error {
+	_, err := r.run(ctx, "stop", "-t", strconv.Itoa(int(timeout.Seconds())), name)
+	return err
+}
+
+func (r *cliRuntime) Remove(ctx context.Context, name string) error {
+	_, err := r.run(ctx, "rm", "-f", name)
+	return err
+}
+
+func (r *cliRuntime) Pause(ctx context.Context, name string) error {
+	_, err := r.run(ctx, "pause", name)
+	return err
+}
+
+func (r *cliRuntime) Unpause(ctx context.Context, name string) error {
+	_, err := r.run(ctx, "unpause", name)
+	return err
+}
+
+func (r *cliRuntime) Exec(ctx context.Context, name string, argv []string, opts ExecOptions) *exec.Cmd {
+	secretArgs, secretEnviron := secretEnvArgs(opts.Secrets)
+	args := []string{"exec"}
+	if opts.Stdin {
+		args = append(args, "-i")
+	}
+	for _, k := range sortedKeys(opts.Env) {
+		args = append(args, "-e", k+"="+opts.Env[k])
+	}
+	if opts.WorkingDir != "" {
+		args = append(args, "-w", opts.WorkingDir)
+	}
+	args = append(args, secretArgs...)
+	args = append(args, name)
+	args = append(args, argv...)
+
+	cmd := r.command(ctx, args...)
+	if len(secretEnviron) > 0 {
+		cmd.Env = append(os.Environ(), secretEnviron...)
+	}
+	return cmd
+}
+
+func (r *cliRuntime) CopyTo(ctx context.Context, hostDir, name, containerDir string) error {
+	if _, err := r.run(ctx, "exec", name, "mkdir", "-p", containerDir); err != nil {
+		return err
+	}
+	// <src>/. 表示复制目录内容而不是目录本身
+	_, err := r.run(ctx, "cp", hostDir+"/.", name+":"+containerDir)
+	return err
+}
+
+func (r *cliRuntime) CopyFrom(ctx context.Context, name, containerPath, hostPath string) error {
+	_, err := r.run(ctx, "cp", name+":"+containerPath, hostPath)
+	return err
+}
+
+func (r *cliRuntime) List(ctx context.Context, all bool) ([]string, error) {
+	args := []string{"ps", "--format", "{{.Names}}"}
+	if all {
+		args = append(args, "-a")
+	} else {
+		args = append(args, "--filter", "status=running")
+	}
+	out, err := r.command(ctx, args...).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s ps 失败: %w", r.bin, err)
+	}
+	var names []string
+	for _, line := range strings.Split(string(out), "\n") {
+		if n := strings.TrimSpace(line); n != "" {
+			names = append(names, n)
+		}
+	}
+	return names, nil
+}
+
+func (r *cliRuntime) Inspect(ctx context.Context, name string) (*ContainerInfo, error) {
+	out, err := r.command(ctx, "container", "inspect", name).Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s inspect %s 失败: %w", r.bin, name, err)
+	}
+	return parseInspect(out)
+}
+
+// parseInspect 解析 inspect 输出（docker、podman 与 nerdctl 兼容格式均为对象数组）
+func parseInspect(data []byte) (*ContainerInfo, error) {
+	var result []struct {
+		Image  string
+		Config struct {
+			Image  string
+			Labels map[string]string
+		}
+		State struct {
+			Running bool
+			Status  string
+		}
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return nil, fmt.Errorf("解析 inspect 输出失败: %w", err)
+	}
+	if len(result) == 0 {
+		return nil, errors.New("容器不存在")
+	}
+	c := result[0]
+	return &ContainerInfo{
+		Image:   orDefault(c.Config.Image, c.Image),
+		Running: c.State.Running,
+		Status:  c.State.Status,
+		Labels:  c.Config.Labels,
+	}, nil
+}
+
+func (r *cliRuntime) Update(ctx context.Context, name string, res ContainerResources) error {
+	args := updateArgs(res)
+	if len(args) == 1 {
+		return nil
+	}
+	_, err := r.run(ctx, append(args, name)...)
+	return err
+}
+
+// updateArgs 生成 update 参数（不含容器名）
+func updateArgs(res ContainerResources) []string {
+	args := []string{"update"}
+	if res.CPUs != 0 {
+		args = append(args, "--cpus", strconv.FormatFloat(res.CPUs, 'f', -1, 64))
+	}
+	if res.MemoryBytes != 0 {
+		args = append(args, "--memory", strconv.FormatInt(res.MemoryBytes, 10))
+	}
+	if res.MemorySwap != 0 {
+		args = append(args, "--memory-swap", strconv.FormatInt(res.MemorySwap, 10))
+	}
+	if res.PidsLimit != 0 {
+		args = append(args, "--pids-limit", strconv.FormatInt(res.PidsLimit, 10))
+	}
+	return args
+}
+
+func (r *cliRuntime) Logs(ctx context.Context, name string) ([]byte, error) {
+	return r.command(ctx, "logs", name).CombinedOutput()
+}
+
+func (r *cliRuntime) VolumeExists(ctx context.Context, name string) bool {
+	return r.command(ctx, "volume", "inspect", name).Run() == nil
+}
+
+func (r *cliRuntime) CreateVolume(ctx context.Context, name string) error {
+	_, err := r.run(ctx, "volume", "create", name)
+	return err
+}
+
+// volumeHelperImage 导入数据卷使用的临时容器镜像
+const volumeHelperImage = "alpine:latest"
+
+func (r *cliRuntime) RestoreVolume(ctx context.Context, volume, mountPath string, archive io.Reader) error {
+	mount := fmt.Sprintf("%s:%s", volume, mountPath)
+
+	// nerdctl cp 不支持从标准输入读取归档：在临时容器内解包
+	if r.name == ContainerRuntimeContainerd {
+		cmd := r.command(ctx, "run", "--rm", "-i", "-v", mount, volumeHelperImage, "tar", "-x", "-f", "-", "-C", mountPath)
+		cmd.Stdin = archive
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("导入 volume 数据失败: %w, 输出: %s", err, string(out))
+		}
+		return nil
+	}
+
+	// 通过临时容器导入：<bin> cp - (stdin) -> container:mountPath
+	containerName := fmt.Sprintf("import_%s_%d", volume, time.Now().UnixNano())
+	if _, err := r.run(ctx, "create", "--name", containerName, "-v", mount, volumeHelperImage, "true"); err != nil {
+		return fmt.Errorf("创建导入容器失败: %w", err)
+	}
+	defer r.command(ctx, "rm", "-f", containerName).Run()
+
+	cmd := r.command(ctx, "cp", "-", containerName+":"+mountPath)
+	cmd.Stdin = archive
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("导入 volume 数据失败: %w, 输出: %s", err, string(out))
+	}
+	return nil
+}
+
+// orDefault 返回 v，为空时返回 fallback
+func orDefault(v, fallback string) string {
+	if v != "" {
+		return v
+	}
+	return fallback
+}
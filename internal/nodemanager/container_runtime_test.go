@@ -0,0 +1,127 @@
+package nodemanager
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// fakeCLI 创建记录参数并输出固定内容的假命令行，返回命令路径与参数记录文件
+func fakeCLI(t *testing.T, output string) (string, string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake CLI requires a POSIX shell")
+	}
+	dir := t.TempDir()
+	argsFile := filepath.Join(dir, "args")
+	bin := filepath.Join(dir, "fakectl")
+	script := "#!/bin/sh\nprintf '%s\\n' \"$@\" > " + argsFile + "\ncat <<'EOF'\n" + output + "\nEOF\n"
+	if err := os.WriteFile(bin, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	return bin, argsFile
+}
+
+func readArgs(t *testing.T, argsFile string) []string {
+	t.Helper()
+	data, err := os.ReadFile(argsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestNewContainerRuntime(t *testing.T) {
+	bin, _ := fakeCLI(t, "")
+	for _, name := range []string{"", ContainerRuntimeDocker, ContainerRuntimePodman, ContainerRuntimeContainerd} {
+		rt, err := NewContainerRuntime(ContainerRuntimeConfig{Name: name, Binary: bin})
+		if err != nil {
+			t.Fatalf("%q: %v", name, err)
+		}
+		if hasAPI := rt.APISocket() != ""; hasAPI == (name == ContainerRuntimeContainerd) {
+			t.Errorf("%q: api socket = %q", name, rt.APISocket())
+		}
+	}
+	if _, err := NewContainerRuntime(ContainerRuntimeConfig{Name: "lxc"}); err == nil {
+		t.Error("unknown runtime should be rejected")
+	}
+	if _, err := NewContainerRuntime(ContainerRuntimeConfig{Name: ContainerRuntimePodman, Binary: "/nonexistent/podman"}); err == nil {
+		t.Error("explicitly configured runtime without CLI should be rejected")
+	}
+	if _, err := NewContainerRuntime(ContainerRuntimeConfig{Name: ContainerRuntimeContainerd, Binary: bin, Socket: "/run/x.sock"}); err == nil {
+		t.Error("containerd has no docker-compatible api socket")
+	}
+}
+
+func TestCLIRuntime_ExecAndNamespace(t *testing.T) {
+	bin, argsFile := fakeCLI(t, "")
+	rt, err := NewContainerRuntime(ContainerRuntimeConfig{Name: ContainerRuntimeContainerd, Binary: bin, Namespace: "agents"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := rt.Exec(context.Background(), "agent-1", []string{"qwen", "-p", "hi"}, ExecOptions{
+		Env: map[string]string{"B": "2", "A": "1"}, Secrets: map[string]string{"API_KEY": "s3cret"}, WorkingDir: "/workspace",
+	})
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"--namespace", "agents", "exec", "-e", "A=1", "-e", "B=2", "-w", "/workspace", "-e", "API_KEY", "agent-1", "qwen", "-p", "hi"}
+	if got := readArgs(t, argsFile); !slices.Equal(got, want) {
+		t.Errorf("args = %v, want %v", got, want)
+	}
+	if !slices.Contains(cmd.Env, "API_KEY=s3cret") {
+		t.Error("secret value should be passed through the CLI process environment")
+	}
+}
+
+func TestCLIRuntime_List(t *testing.T) {
+	bin, argsFile := fakeCLI(t, "agent_inst-1\n\nttyd_terminal")
+	rt := &cliRuntime{name: ContainerRuntimePodman, bin: bin}
+	names, err := rt.List(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"agent_inst-1", "ttyd_terminal"}) {
+		t.Errorf("names = %v", names)
+	}
+	if got := readArgs(t, argsFile); !slices.Contains(got, "status=running") {
+		t.Errorf("running-only list should filter by status, args = %v", got)
+	}
+}
+
+func TestParseInspect(t *testing.T) {
+	data := `[{"Image":"sha256:abc","Config":{"Image":"runners/qwen:latest","Labels":{"agents-admin.managed":"true"}},"State":{"Running":true,"Status":"running"}}]`
+	info, err := parseInspect([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Image != "runners/qwen:latest" || !info.Running || info.Status != "running" || info.Labels["agents-admin.managed"] != "true" {
+		t.Errorf("info = %+v", info)
+	}
+	if _, err := parseInspect([]byte("[]")); err == nil {
+		t.Error("empty inspect output means the container does not exist")
+	}
+}
+
+func TestInstanceContainerSpec(t *testing.T) {
+	inst := instanceInfo{ID: "inst-1", AccountID: "acc-1", NodeID: "node-1"}
+	args := createArgs(instanceContainerSpec(inst, "agent_inst-1", "vol-acc-1", "/home/node/.qwen", "runners/qwen:latest"))
+	want := []string{
+		"run", "-d", "--name", "agent_inst-1",
+		"--label", "agents-admin.account_id=acc-1",
+		"--label", "agents-admin.instance_id=inst-1",
+		"--label", "agents-admin.managed=true",
+		"--label", "agents-admin.node_id=node-1",
+		"-v", "vol-acc-1:/home/node/.qwen",
+		"--restart", "unless-stopped",
+		"-t", "-i",
+		"runners/qwen:latest",
+	}
+	if !slices.Equal(args, want) {
+		t.Errorf("args = %v, want %v", args, want)
+	}
+}
@@ -10,10 +10,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"os/exec"
 	"strings"
 	"sync"
 	"time"
+
+	"agents-admin/internal/shared/model"
 )
 
 const (
@@ -137,69 +138,129 @@ func (w *TerminalWorker) startTerminal(ctx context.Context, session terminalSess
 		return
 	}
 
-	// 检查目标容器是否运行中
-	if !w.isContainerRunning(ctx, session.ContainerName) {
-		log.Printf("[TerminalWorker] 目标容器未运行: %s", session.ContainerName)
-		w.updateSessionStatus(ctx, session.ID, "error", nil, strPtr("目标容器未运行"))
+	replaced, errMsg := w.launchTTYDUnlocked(ctx, session.ID, session.ContainerName)
+	if replaced != "" {
+		// 更新旧会话状态为 closed
+		w.updateSessionStatus(ctx, replaced, "closed", nil, nil)
+	}
+	if errMsg != "" {
+		w.updateSessionStatus(ctx, session.ID, "error", nil, &errMsg)
+		return
+	}
+
+	// 构建终端 URL（直接访问 ttyd 端口）
+	port := ttydPort
+	terminalURL := fmt.Sprintf("/terminal/%s/", session.ID)
+
+	// 更新状态为 running
+	if err := w.updateSessionStatus(ctx, session.ID, "running", &port, &terminalURL); err != nil {
+		log.Printf("[TerminalWorker] 更新状态失败: %v", err)
 		return
 	}
 
+	log.Printf("[TerminalWorker] 终端 %s 启动成功，端口: %d, URL: %s", session.ID, port, terminalURL)
+}
+
+// reconcileTerminals 按期望状态对齐 ttyd 容器，返回观测结果
+//
+// 同一时间只允许一个终端：保留最新的会话（terms 按创建时间升序），较早的会话上报为 closed；
+// 期望状态中没有会话时停止 ttyd 容器。ttyd 容器异常退出时重新启动。
+func (w *TerminalWorker) reconcileTerminals(ctx context.Context, terms []model.DesiredTerminal) []model.ObservedTerminal {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(terms) == 0 {
+		if w.activeSessionID != "" {
+			log.Printf("[TerminalWorker] 会话 %s 已关闭，停止 ttyd 容器", w.activeSessionID)
+			w.stopTTYDContainerUnlocked(ctx)
+			w.activeSessionID = ""
+			w.activeContainerID = ""
+		}
+		return nil
+	}
+
+	target := terms[len(terms)-1]
+	var observed []model.ObservedTerminal
+	for _, t := range terms[:len(terms)-1] {
+		observed = append(observed, model.ObservedTerminal{ID: t.ID, Status: t.Status, State: model.TerminalStatusClosed})
+	}
+
+	port := ttydPort
+	terminalURL := fmt.Sprintf("/terminal/%s/", target.ID)
+	running := model.ObservedTerminal{ID: target.ID, Status: target.Status, State: model.TerminalStatusRunning, Port: &port, URL: &terminalURL}
+
+	if w.activeSessionID == target.ID && w.isContainerRunning(ctx, ttydContainerName) {
+		if target.Status != model.TerminalStatusRunning {
+			observed = append(observed, running)
+		}
+		return observed
+	}
+
+	log.Printf("[TerminalWorker] 启动终端: %s (容器: %s)", target.ID, target.ContainerName)
+	if _, errMsg := w.launchTTYDUnlocked(ctx, target.ID, target.ContainerName); errMsg != "" {
+		return append(observed, model.ObservedTerminal{ID: target.ID, Status: target.Status, State: model.TerminalStatusError, Error: errMsg})
+	}
+	log.Printf("[TerminalWorker] 终端 %s 启动成功，端口: %d, URL: %s", target.ID, port, terminalURL)
+	return append(observed, running)
+}
+
+// launchTTYDUnlocked 为会话启动 ttyd 容器（调用方需持有锁）
+//
+// 返回被取代的旧会话 ID（没有时为空）与失败原因（成功时为空）。
+func (w *TerminalWorker) launchTTYDUnlocked(ctx context.Context, sessionID, containerName string) (replaced, errMsg string) {
+	// ttyd 容器内的 docker CLI 通过挂载的 Docker 兼容 API socket 访问目标容器
+	rt := w.config.containers()
+	if rt.APISocket() == "" {
+		log.Printf("[TerminalWorker] 容器运行时 %s 不提供 Docker 兼容 API，无法启动终端", rt.Name())
+		return "", "当前容器运行时不支持 Web 终端"
+	}
+
+	// 检查目标容器是否运行中
+	if !w.isContainerRunning(ctx, containerName) {
+		log.Printf("[TerminalWorker] 目标容器未运行: %s", containerName)
+		return "", "目标容器未运行"
+	}
+
 	// 如果已有活跃会话，先关闭旧的 ttyd 容器
 	if w.activeSessionID != "" {
 		log.Printf("[TerminalWorker] 关闭旧会话: %s", w.activeSessionID)
 		w.stopTTYDContainerUnlocked(ctx)
-		// 更新旧会话状态为 closed
-		w.updateSessionStatus(ctx, w.activeSessionID, "closed", nil, nil)
+		replaced = w.activeSessionID
+		w.activeSessionID = ""
+		w.activeContainerID = ""
 	}
 
 	// 启动 ttyd 容器
-	// docker run --rm -d --name ttyd_terminal -p 7681:7681 \
-	//   -v /var/run/docker.sock:/var/run/docker.sock \
+	// run -d --name ttyd_terminal -p 127.0.0.1:7681:7681 \
+	//   -v <api socket>:/var/run/docker.sock \
 	//   tools/ttyd:latest -W -p 7681 docker exec -it <container> <bash|sh>
-	targetShell := w.detectTargetShell(ctx, session.ContainerName)
-	args := buildTTYDDockerRunArgs(session.ContainerName, targetShell)
+	targetShell := w.detectTargetShell(ctx, containerName)
+	spec := ttydContainerSpec(rt.APISocket(), containerName, targetShell)
 
-	log.Printf("[TerminalWorker] 执行: docker %v", args)
+	log.Printf("[TerminalWorker] 执行: %s %v", rt.Name(), createArgs(spec))
 
-	cmd := exec.CommandContext(ctx, "docker", args...)
-	output, err := cmd.CombinedOutput()
+	containerID, err := rt.Create(ctx, spec)
 	if err != nil {
-		log.Printf("[TerminalWorker] 启动 ttyd 容器失败: %v, 输出: %s", err, string(output))
-		w.updateSessionStatus(ctx, session.ID, "error", nil, strPtr("启动终端失败: "+err.Error()))
-		return
+		log.Printf("[TerminalWorker] 启动 ttyd 容器失败: %v", err)
+		return replaced, "启动终端失败: " + err.Error()
 	}
-
-	containerID := strings.TrimSpace(string(output))
-	log.Printf("[TerminalWorker] ttyd 容器启动成功: %s", containerID[:12])
+	log.Printf("[TerminalWorker] ttyd 容器启动成功: %s", containerID[:min(12, len(containerID))])
 
 	// 记录活跃会话
-	w.activeSessionID = session.ID
+	w.activeSessionID = sessionID
 	w.activeContainerID = containerID
 
 	// 等待 ttyd 就绪
 	if err := w.waitForTTYDReady(ctx, time.Now().Add(12*time.Second)); err != nil {
 		log.Printf("[TerminalWorker] ttyd 未就绪: %v，查看容器日志...", err)
-		logsCmd := exec.CommandContext(ctx, "docker", "logs", ttydContainerName)
-		logs, _ := logsCmd.CombinedOutput()
+		logs, _ := rt.Logs(ctx, ttydContainerName)
 		log.Printf("[TerminalWorker] ttyd 容器日志: %s", string(logs))
-		w.updateSessionStatus(ctx, session.ID, "error", nil, strPtr("终端启动超时"))
 		w.stopTTYDContainerUnlocked(ctx)
 		w.activeSessionID = ""
 		w.activeContainerID = ""
-		return
-	}
-
-	// 构建终端 URL（直接访问 ttyd 端口）
-	port := ttydPort
-	terminalURL := fmt.Sprintf("/terminal/%s/", session.ID)
-
-	// 更新状态为 running
-	if err := w.updateSessionStatus(ctx, session.ID, "running", &port, &terminalURL); err != nil {
-		log.Printf("[TerminalWorker] 更新状态失败: %v", err)
-		return
+		return replaced, "终端启动超时"
 	}
-
-	log.Printf("[TerminalWorker] 终端 %s 启动成功，端口: %d, URL: %s", session.ID, port, terminalURL)
+	return replaced, ""
 }
 
 func (w *TerminalWorker) waitForTTYDReady(ctx context.Context, deadline time.Time) error {
@@ -210,8 +271,7 @@ func (w *TerminalWorker) waitForTTYDReady(ctx context.Context, deadline time.Tim
 
 		if w.isContainerRunning(ctx, ttydContainerName) {
 			// 不依赖 curl/wget/netcat 是否存在：直接从 ttyd 日志判断是否开始监听端口
-			logsCmd := exec.CommandContext(ctx, "docker", "logs", ttydContainerName)
-			logs, err := logsCmd.CombinedOutput()
+			logs, err := w.config.containers().Logs(ctx, ttydContainerName)
 			if err == nil && (strings.Contains(string(logs), "Listening on port") || strings.Contains(string(logs), "Listening on port:")) {
 				return nil
 			}
@@ -223,31 +283,41 @@ func (w *TerminalWorker) waitForTTYDReady(ctx context.Context, deadline time.Tim
 	return fmt.Errorf("timeout waiting for ttyd to be ready")
 }
 
-func buildTTYDDockerRunArgs(targetContainer, targetShell string) []string {
-	return []string{
-		"run", "-d",
-		"--name", ttydContainerName,
+// ttydContainerSpec ttyd 容器参数：apiSocket 挂载为容器内的 /var/run/docker.sock
+// （podman 的 Docker 兼容 API 同样可以由 docker CLI 访问）
+func ttydContainerSpec(apiSocket, targetContainer, targetShell string) ContainerSpec {
+	return ContainerSpec{
+		Name:  ttydContainerName,
+		Image: ttydImage,
 		// tools/ttyd:latest 可能带 tini 入口（如 deployments/Dockerfile.ttyd）
 		// 为保证参数一致，强制使用 ttyd 作为入口
-		"--entrypoint", "ttyd",
-		"-p", fmt.Sprintf("%d:%d", ttydPort, ttydPort),
-		"-v", "/var/run/docker.sock:/var/run/docker.sock",
-		ttydImage,
-		"-W", "-p", fmt.Sprintf("%d", ttydPort),
-		"docker", "exec", "-it", targetContainer, targetShell,
+		Entrypoint: "ttyd",
+		Ports:      []string{fmt.Sprintf("127.0.0.1:%d:%d", ttydPort, ttydPort)}, // 只监听回环地址，API Server 经节点反向隧道访问
+		Volumes:    []string{apiSocket + ":/var/run/docker.sock"},
+		Args: []string{
+			"-W", "-p", fmt.Sprintf("%d", ttydPort),
+			"docker", "exec", "-it", targetContainer, targetShell,
+		},
 	}
 }
 
 // detectTargetShell 检测目标容器是否有 bash；否则回退到 sh
 func (w *TerminalWorker) detectTargetShell(ctx context.Context, containerName string) string {
 	// 绝大多数镜像都有 /bin/sh；bash 可能不存在
-	cmd := exec.CommandContext(ctx, "docker", "exec", containerName, "sh", "-lc", "command -v bash >/dev/null 2>&1")
+	cmd := w.config.containers().Exec(ctx, containerName, []string{"sh", "-lc", "command -v bash >/dev/null 2>&1"}, ExecOptions{})
 	if err := cmd.Run(); err == nil {
 		return "bash"
 	}
 	return "sh"
 }
 
+// ActiveSession 当前活跃的终端会话 ID（没有时为空）
+func (w *TerminalWorker) ActiveSession() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.activeSessionID
+}
+
 // cleanupClosedSessions 清理已关闭的会话
 func (w *TerminalWorker) cleanupClosedSessions(ctx context.Context) {
 	w.mu.Lock()
@@ -309,25 +379,19 @@ func (w *TerminalWorker) stopTTYDContainer(ctx context.Context) {
 
 // stopTTYDContainerUnlocked 停止 ttyd 容器（不加锁版本，调用方需持有锁）
 func (w *TerminalWorker) stopTTYDContainerUnlocked(ctx context.Context) {
-	// 先尝试停止
-	stopCmd := exec.CommandContext(ctx, "docker", "stop", "-t", "1", ttydContainerName)
-	stopCmd.Run()
+	rt := w.config.containers()
 
-	// 再尝试删除（如果没有 --rm 的话）
-	rmCmd := exec.CommandContext(ctx, "docker", "rm", "-f", ttydContainerName)
-	rmCmd.Run()
+	// 先尝试停止，再删除（错误忽略：容器可能已不存在）
+	rt.Stop(ctx, ttydContainerName, time.Second)
+	rt.Remove(ctx, ttydContainerName)
 
 	log.Printf("[TerminalWorker] ttyd 容器已停止")
 }
 
 // isContainerRunning 检查容器是否运行中
 func (w *TerminalWorker) isContainerRunning(ctx context.Context, containerName string) bool {
-	cmd := exec.CommandContext(ctx, "docker", "inspect", "-f", "{{.State.Running}}", containerName)
-	output, err := cmd.Output()
-	if err != nil {
-		return false
-	}
-	return strings.TrimSpace(string(output)) == "true"
+	info, err := w.config.containers().Inspect(ctx, containerName)
+	return err == nil && info.Running
 }
 
 // updateSessionStatus 更新终端会话状态
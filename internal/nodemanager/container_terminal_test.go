@@ -2,17 +2,17 @@ package nodemanager
 
 import "testing"
 
-func TestBuildTTYDDockerRunArgs(t *testing.T) {
+func TestTTYDContainerSpec(t *testing.T) {
 	container := "agent_inst_qwen-code_freebuddy_at_gmail_com_1769760979"
 	shell := "bash"
 
-	args := buildTTYDDockerRunArgs(container, shell)
+	args := createArgs(ttydContainerSpec("/var/run/docker.sock", container, shell))
 
 	want := []string{
 		"run", "-d",
 		"--name", "ttyd_terminal",
 		"--entrypoint", "ttyd",
-		"-p", "7681:7681",
+		"-p", "127.0.0.1:7681:7681",
 		"-v", "/var/run/docker.sock:/var/run/docker.sock",
 		ttydImage,
 		"-W", "-p", "7681",
@@ -34,3 +34,10 @@ func TestBuildTTYDDockerRunArgs(t *testing.T) {
 		}
 	}
 }
+
+func TestTTYDContainerSpec_PodmanSocket(t *testing.T) {
+	spec := ttydContainerSpec("/run/podman/podman.sock", "c1", "sh")
+	if len(spec.Volumes) != 1 || spec.Volumes[0] != "/run/podman/podman.sock:/var/run/docker.sock" {
+		t.Fatalf("volumes = %v", spec.Volumes)
+	}
+}
@@ -0,0 +1,238 @@
+// Package nodemanager 期望状态同步
+//
+// 按 API Server 计算的节点期望状态文档（实例、认证会话、终端）对齐本地容器，并上报观测到的状态：
+//   - 实例：期望运行时确保容器存在且运行（不存在则创建），期望停止时停止容器；不在文档中的实例容器按孤儿清理
+//   - 认证会话：启动 assigned 的 Action，取消已不在文档中的 Action
+//   - 终端：保留最新的会话并保证 ttyd 容器运行，较早的会话上报为 closed
+//
+// API Server 以节点行动时文档中的状态做条件更新，处理期间被用户修改的记录不会被覆盖，下一轮同步按新的期望状态继续对齐。
+// 旧版 API Server 没有期望状态接口（404）时回退到各工作线程的轮询模式。
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	desiredStateInterval = 3 * time.Second
+	// orphanCleanupInterval 孤儿实例容器清理间隔
+	orphanCleanupInterval = 30 * time.Second
+)
+
+// errDesiredStateUnsupported API Server 不支持期望状态接口
+var errDesiredStateUnsupported = errors.New("desired state not supported by api server")
+
+// desiredStateLoop 期望状态同步循环
+//
+// 实例对齐（可能需要下载 Volume、创建容器）与终端对齐在各自的 goroutine 中执行，上一轮尚未完成时跳过该部分，
+// 慢的实例创建不会阻塞终端启动。
+func (nm *NodeManager) desiredStateLoop(ctx context.Context) {
+	log.Printf("[DesiredState] 启动期望状态同步，节点: %s", nm.config.NodeID)
+
+	// 启动时清理可能残留的 ttyd 容器
+	if nm.terminalWorker != nil {
+		nm.terminalWorker.stopTTYDContainer(ctx)
+	}
+
+	var (
+		wg            sync.WaitGroup
+		instancesBusy atomic.Bool
+		terminalsBusy atomic.Bool
+		lastCleanup   time.Time
+	)
+	apply := func(busy *atomic.Bool, fn func() model.NodeObservedState) {
+		if !busy.CompareAndSwap(false, true) {
+			return
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer busy.Store(false)
+			if obs := fn(); len(obs.Instances) > 0 || len(obs.Terminals) > 0 {
+				if err := nm.postObservedState(ctx, obs); err != nil && ctx.Err() == nil {
+					log.Printf("[DesiredState] 上报观测状态失败: %v", err)
+				}
+			}
+		}()
+	}
+
+	ticker := time.NewTicker(desiredStateInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			log.Println("[DesiredState] 同步停止，清理终端容器与认证任务...")
+			if nm.terminalWorker != nil {
+				nm.terminalWorker.stopTTYDContainer(context.Background())
+			}
+			if nm.authController != nil {
+				nm.authController.cleanupAllActions()
+			}
+			return
+		case <-ticker.C:
+		}
+
+		doc, err := nm.fetchDesiredState(ctx)
+		if errors.Is(err, errDesiredStateUnsupported) {
+			wg.Wait()
+			log.Println("[DesiredState] API Server 不支持期望状态接口，回退到轮询模式")
+			nm.startLegacyWorkers(ctx)
+			return
+		}
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("[DesiredState] 获取期望状态失败: %v", err)
+			}
+			continue
+		}
+
+		if nm.authController != nil {
+			nm.authController.reconcileActions(ctx, doc.AuthSessions)
+		}
+
+		if nm.agentWorker != nil {
+			cleanup := time.Since(lastCleanup) >= orphanCleanupInterval
+			if cleanup {
+				lastCleanup = time.Now()
+			}
+			apply(&instancesBusy, func() model.NodeObservedState {
+				return nm.reconcileInstances(ctx, doc, cleanup)
+			})
+		}
+
+		if nm.terminalWorker != nil {
+			apply(&terminalsBusy, func() model.NodeObservedState {
+				return model.NodeObservedState{
+					Revision:  doc.Revision,
+					Terminals: nm.terminalWorker.reconcileTerminals(ctx, doc.Terminals),
+				}
+			})
+		}
+	}
+}
+
+// reconcileInstances 按期望状态对齐本节点的实例容器
+func (nm *NodeManager) reconcileInstances(ctx context.Context, doc *desiredState, cleanup bool) model.NodeObservedState {
+	obs := model.NodeObservedState{Revision: doc.Revision}
+	if cleanup {
+		// 不在文档中的实例视为已删除，清理残留容器（沿用对账的安全防护）
+		instances := make([]instanceInfo, 0, len(doc.Instances))
+		for _, d := range doc.Instances {
+			instances = append(instances, instanceInfo{ID: d.ID, ContainerName: d.ContainerName, NodeID: nm.config.NodeID})
+		}
+		nm.agentWorker.cleanupOrphanInstanceContainers(ctx, instances)
+	}
+	for _, d := range doc.Instances {
+		if ctx.Err() != nil {
+			break
+		}
+		if o := nm.agentWorker.applyDesired(ctx, d); o != nil {
+			obs.Instances = append(obs.Instances, *o)
+		}
+	}
+	return obs
+}
+
+// startLegacyWorkers 以轮询模式运行各工作线程，直到 ctx 取消
+func (nm *NodeManager) startLegacyWorkers(ctx context.Context) {
+	var wg sync.WaitGroup
+	if nm.authController != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nm.authController.Start(ctx)
+		}()
+	}
+	if nm.agentWorker != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nm.agentWorker.Start(ctx)
+		}()
+	}
+	if nm.terminalWorker != nil {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nm.terminalWorker.Start(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// desiredState 节点期望状态文档（认证会话按节点侧的 Action 结构解析）
+type desiredState struct {
+	model.NodeDesiredState
+	AuthSessions []*NodeAction `json:"auth_sessions"`
+}
+
+// fetchDesiredState 获取本节点的期望状态文档，API Server 不支持时返回 errDesiredStateUnsupported
+func (nm *NodeManager) fetchDesiredState(ctx context.Context) (*desiredState, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET",
+		nm.config.APIServerURL+"/api/v1/nodes/"+nm.config.NodeID+"/desired-state", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusMethodNotAllowed:
+		return nil, errDesiredStateUnsupported
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("API 返回错误状态: %d", resp.StatusCode)
+	}
+
+	var doc desiredState
+	if err := json.NewDecoder(resp.Body).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %w", err)
+	}
+	return &doc, nil
+}
+
+// postObservedState 上报观测状态
+func (nm *NodeManager) postObservedState(ctx context.Context, obs model.NodeObservedState) error {
+	body, _ := json.Marshal(obs)
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/nodes/"+nm.config.NodeID+"/observed-state",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("API 返回错误状态: %d", resp.StatusCode)
+	}
+	var result struct {
+		Applied int `json:"applied"`
+		Skipped int `json:"skipped"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err == nil && result.Skipped > 0 {
+		log.Printf("[DesiredState] 观测状态已应用 %d 项，%d 项已被修改或不属于本节点，跳过", result.Applied, result.Skipped)
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// stateRuntime 内存中的容器运行时（在 poolRuntime 基础上支持启动/停止与 ttyd 日志）
+type stateRuntime struct {
+	*poolRuntime
+}
+
+func (r stateRuntime) Start(_ context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info, ok := r.containers[name]; ok {
+		info.Running = true
+	}
+	return nil
+}
+
+func (r stateRuntime) Stop(_ context.Context, name string, _ time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if info, ok := r.containers[name]; ok {
+		info.Running = false
+	}
+	return nil
+}
+
+func (r stateRuntime) Logs(context.Context, string) ([]byte, error) {
+	return []byte("Listening on port: 7681"), nil
+}
+
+func (r stateRuntime) Name() string                              { return "fake" }
+func (r stateRuntime) APISocket() string                         { return "/var/run/docker.sock" }
+func (r stateRuntime) VolumeExists(context.Context, string) bool { return true }
+
+func (r stateRuntime) running(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.containers[name]
+	return ok && info.Running
+}
+
+func TestAgentWorker_ApplyDesired(t *testing.T) {
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/accounts/acc-1":
+			json.NewEncoder(w).Encode(accountInfo{ID: "acc-1", VolumeName: "vol-acc-1"})
+		case "/api/v1/agent-types/qwen":
+			json.NewEncoder(w).Encode(agentTypeInfo{ID: "qwen", Image: "runners/qwen:latest", AuthDir: "/root/.qwen"})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer api.Close()
+
+	rt := stateRuntime{newPoolRuntime()}
+	rt.containers["agent_inst-2"] = &ContainerInfo{Image: "runners/qwen:latest"}
+	rt.containers["agent_inst-3"] = &ContainerInfo{Image: "runners/qwen:latest", Running: true}
+	w := NewAgentWorker(Config{NodeID: "node-1", APIServerURL: api.URL, Containers: rt})
+	ctx := context.Background()
+
+	tests := []struct {
+		desired   model.DesiredInstance
+		state     string
+		container string
+		running   bool
+	}{
+		// 容器不存在：创建
+		{model.DesiredInstance{ID: "inst-1", AccountID: "acc-1", AgentTypeID: "qwen", Status: model.InstanceStatusPending, State: model.DesiredRunning}, model.ObservedRunning, "agent_inst-1", true},
+		// 容器已停止：启动
+		{model.DesiredInstance{ID: "inst-2", Status: model.InstanceStatusRunning, State: model.DesiredRunning}, model.ObservedRunning, "agent_inst-2", true},
+		// 期望停止：停止运行中的容器
+		{model.DesiredInstance{ID: "inst-3", Status: model.InstanceStatusStopping, State: model.DesiredStopped}, model.ObservedStopped, "agent_inst-3", false},
+		// 期望停止且容器不存在：视为已停止
+		{model.DesiredInstance{ID: "inst-4", Status: model.InstanceStatusStopping, State: model.DesiredStopped}, model.ObservedStopped, "", false},
+		// 获取账号失败：上报 error
+		{model.DesiredInstance{ID: "inst-5", AccountID: "acc-missing", AgentTypeID: "qwen", Status: model.InstanceStatusPending, State: model.DesiredRunning}, model.ObservedError, "", false},
+	}
+	for _, tt := range tests {
+		obs := w.applyDesired(ctx, tt.desired)
+		if obs == nil {
+			t.Fatalf("%s: observation is nil", tt.desired.ID)
+		}
+		if obs.State != tt.state || obs.ContainerName != tt.container || obs.Status != tt.desired.Status {
+			t.Errorf("%s: got %+v, want state=%s container=%q", tt.desired.ID, obs, tt.state, tt.container)
+		}
+		if tt.container != "" && rt.running(tt.container) != tt.running {
+			t.Errorf("%s: container %s running=%v, want %v", tt.desired.ID, tt.container, !tt.running, tt.running)
+		}
+	}
+
+	// error 状态没有期望状态，不做处理
+	if obs := w.applyDesired(ctx, model.DesiredInstance{ID: "inst-6", Status: model.InstanceStatusError}); obs != nil {
+		t.Errorf("inst-6: got %+v, want nil", obs)
+	}
+}
+
+func TestTerminalWorker_ReconcileTerminals(t *testing.T) {
+	rt := stateRuntime{newPoolRuntime()}
+	rt.containers["agent_inst-1"] = &ContainerInfo{Running: true}
+	w := NewTerminalWorker(Config{NodeID: "node-1", Containers: rt})
+	ctx := context.Background()
+
+	now := time.Now()
+	terms := []model.DesiredTerminal{
+		{ID: "term-old", ContainerName: "agent_inst-1", Status: model.TerminalStatusRunning, CreatedAt: now.Add(-time.Minute)},
+		{ID: "term-new", ContainerName: "agent_inst-1", Status: model.TerminalStatusPending, CreatedAt: now},
+	}
+	obs := w.reconcileTerminals(ctx, terms)
+	if len(obs) != 2 {
+		t.Fatalf("observations = %+v, want 2", obs)
+	}
+	if obs[0].ID != "term-old" || obs[0].State != model.TerminalStatusClosed {
+		t.Errorf("obs[0] = %+v, want term-old closed", obs[0])
+	}
+	if obs[1].ID != "term-new" || obs[1].State != model.TerminalStatusRunning || obs[1].Port == nil || *obs[1].Port != ttydPort {
+		t.Errorf("obs[1] = %+v, want term-new running on %d", obs[1], ttydPort)
+	}
+	if w.ActiveSession() != "term-new" || !rt.running(ttydContainerName) {
+		t.Fatalf("active = %q, ttyd running = %v", w.ActiveSession(), rt.running(ttydContainerName))
+	}
+
+	// 已对齐：不再上报
+	if obs := w.reconcileTerminals(ctx, []model.DesiredTerminal{{ID: "term-new", ContainerName: "agent_inst-1", Status: model.TerminalStatusRunning}}); len(obs) != 0 {
+		t.Errorf("steady state observations = %+v, want none", obs)
+	}
+
+	// ttyd 异常退出：重新启动
+	rt.Stop(ctx, ttydContainerName, 0)
+	if obs := w.reconcileTerminals(ctx, []model.DesiredTerminal{{ID: "term-new", ContainerName: "agent_inst-1", Status: model.TerminalStatusRunning}}); len(obs) != 1 || obs[0].State != model.TerminalStatusRunning {
+		t.Errorf("relaunch observations = %+v, want term-new running", obs)
+	}
+	if !rt.running(ttydContainerName) {
+		t.Error("ttyd not relaunched")
+	}
+
+	// 会话已关闭：停止 ttyd
+	w.reconcileTerminals(ctx, nil)
+	if w.ActiveSession() != "" || rt.count() != 1 {
+		t.Errorf("active = %q, containers = %d; want ttyd removed", w.ActiveSession(), rt.count())
+	}
+
+	// 目标容器未运行：上报 error
+	obs = w.reconcileTerminals(ctx, []model.DesiredTerminal{{ID: "term-x", ContainerName: "agent_missing", Status: model.TerminalStatusPending}})
+	if len(obs) != 1 || obs[0].State != model.TerminalStatusError || obs[0].Error == "" {
+		t.Errorf("observations = %+v, want term-x error", obs)
+	}
+}
+
+func TestFetchDesiredState_Unsupported(t *testing.T) {
+	api := httptest.NewServer(http.NotFoundHandler())
+	defer api.Close()
+
+	nm := &NodeManager{config: Config{NodeID: "node-1", APIServerURL: api.URL}, httpClient: api.Client()}
+	if _, err := nm.fetchDesiredState(context.Background()); err != errDesiredStateUnsupported {
+		t.Fatalf("err = %v, want errDesiredStateUnsupported", err)
+	}
+}
@@ -0,0 +1,901 @@
+// Package nodemanager 事件批量上报
+//
+// Agent 输出的每一行都会解析为事件，逐条 POST 会给 API Server 带来大量请求。
+// eventReporter 为每个 Run 维护一个有界队列，由独立的 goroutine 按批次上报：
+//   - 批次达到 BatchSize 或等待超过 FlushInterval 时上报
+//   - 队列满时阻塞上报方（反压到 Agent 输出读取，Agent 的 stdout 随之阻塞）
+//   - 上报失败按指数退避重试，重试耗尽后按顺序写入本地缓存（SpoolDir 下每个 Run 一组 JSONL 分段文件）
+//   - 缓存中有事件时，新批次先回放缓存再上报；API Server 恢复后由后台循环继续回放
+//   - 缓存总大小有上限（SpoolMaxBytes），超出时丢弃最早写入的事件
+//
+// 更新 Run 状态与上报产物前先排空该 Run 的队列，保证终态事件先于状态入库；
+// 状态与产物上报失败（或缓存中还有该 Run 的记录）时同样写入缓存，与事件按写入顺序回放。
+// 上报的 seq 是节点序号：API Server 按 (run_id, node_seq) 忽略已入库的事件，重试与回放不会产生重复事件；
+// 入库事件的 seq 由 API Server 按 Run 单调分配，与 API Server 自身写入的事件不冲突。
+package nodemanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/shared/model"
+)
+
+// 事件上报默认参数
+const (
+	defaultEventBatchSize     = 50
+	defaultEventFlushInterval = 200 * time.Millisecond
+	defaultEventQueueSize     = 1000
+	defaultEventMaxRetries    = 5
+	defaultEventRetryInterval = 500 * time.Millisecond
+	maxEventRetryInterval     = 30 * time.Second // 重试与缓存回放的最长间隔
+	eventSpoolSuffix          = ".jsonl"
+
+	defaultEventSpoolMaxBytes     = 256 << 20 // 本地缓存总大小上限
+	defaultEventSpoolSegmentBytes = 4 << 20   // 单个分段文件写满后轮转
+)
+
+var (
+	// errEventsRejected API Server 拒绝了事件、状态或产物（请求错误、Run 不存在或已重新分配），重试无意义
+	errEventsRejected = errors.New("events rejected")
+	// errEventBatchTooLarge 批次超过 API Server 的单次上限（api_server.max_event_batch），拆分后重新上报
+	errEventBatchTooLarge = errors.New("event batch too large")
+)
+
+// EventReportConfig 事件上报配置（0 值使用默认值）
+type EventReportConfig struct {
+	BatchSize     int           // 单次上报的最大事件数（默认 50）
+	FlushInterval time.Duration // 批次最长等待时间（默认 200ms）
+	QueueSize     int           // 每个 Run 待上报事件的队列上限，队列满时阻塞 Agent 输出读取（默认 1000）
+	MaxRetries    int           // 上报失败的重试次数，之后写入本地缓存（默认 5，负数表示不重试）
+	RetryInterval time.Duration // 首次重试间隔，之后指数增长，最长 30s（默认 500ms）
+	SpoolDir      string        // 本地缓存目录（为空时使用 <WorkspaceDir>/.event-spool）
+	SpoolMaxBytes int64         // 本地缓存总大小上限，超出时丢弃最早写入的事件（默认 256MiB，负数表示不限制）
+}
+
+func (c EventReportConfig) withDefaults() EventReportConfig {
+	if c.BatchSize <= 0 {
+		c.BatchSize = defaultEventBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = defaultEventFlushInterval
+	}
+	if c.QueueSize <= 0 {
+		c.QueueSize = defaultEventQueueSize
+	}
+	if c.MaxRetries == 0 {
+		c.MaxRetries = defaultEventMaxRetries
+	} else if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = defaultEventRetryInterval
+	}
+	if c.SpoolMaxBytes == 0 {
+		c.SpoolMaxBytes = defaultEventSpoolMaxBytes
+	} else if c.SpoolMaxBytes < 0 {
+		c.SpoolMaxBytes = 0
+	}
+	return c
+}
+
+// eventReporter 事件批量上报器
+type eventReporter struct {
+	cfg   EventReportConfig
+	send  func(ctx context.Context, runID string, events []json.RawMessage) error
+	spool *eventSpool // 为 nil 时重试耗尽的事件直接丢弃
+	// sendRecord 上报缓存中的状态与产物记录（错误语义与 send 相同）
+	sendRecord func(ctx context.Context, runID string, rec spoolRecord) error
+	wake       chan struct{} // 唤醒回放循环（API Server 恢复连通时）
+
+	mu     sync.Mutex
+	queues map[string]*eventQueue
+}
+
+// eventQueue 单个 Run 的待上报事件队列
+type eventQueue struct {
+	mu     sync.RWMutex // 入队持读锁，关闭持写锁
+	closed bool
+	ch     chan json.RawMessage
+	done   chan struct{} // 上报 goroutine 退出后关闭
+}
+
+// newEventReporter 创建事件上报器，send 负责一次批量上报
+func newEventReporter(cfg EventReportConfig, send func(ctx context.Context, runID string, events []json.RawMessage) error) *eventReporter {
+	cfg = cfg.withDefaults()
+	r := &eventReporter{cfg: cfg, send: send, wake: make(chan struct{}, 1), queues: make(map[string]*eventQueue)}
+	if cfg.SpoolDir != "" {
+		r.spool = newEventSpool(cfg.SpoolDir, cfg.SpoolMaxBytes)
+	}
+	return r
+}
+
+// enqueue 将事件加入 Run 的上报队列，队列满时阻塞
+func (r *eventReporter) enqueue(runID string, event json.RawMessage) {
+	for {
+		q := r.queue(runID)
+		q.mu.RLock()
+		if q.closed {
+			// 队列正在排空（Run 状态更新），下一轮创建新队列
+			q.mu.RUnlock()
+			continue
+		}
+		q.ch <- event
+		q.mu.RUnlock()
+		return
+	}
+}
+
+// queue 获取 Run 的上报队列，不存在时创建并启动上报 goroutine
+func (r *eventReporter) queue(runID string) *eventQueue {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if q, ok := r.queues[runID]; ok {
+		return q
+	}
+	q := &eventQueue{ch: make(chan json.RawMessage, r.cfg.QueueSize), done: make(chan struct{})}
+	r.queues[runID] = q
+	go r.run(runID, q)
+	return q
+}
+
+// drain 上报 Run 队列中的全部事件（失败时写入本地缓存）并停止上报 goroutine
+func (r *eventReporter) drain(runID string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	q, ok := r.queues[runID]
+	delete(r.queues, runID)
+	r.mu.Unlock()
+	if !ok {
+		return
+	}
+	q.mu.Lock()
+	q.closed = true
+	close(q.ch)
+	q.mu.Unlock()
+	<-q.done
+}
+
+// close 排空全部 Run 的队列（NodeManager 停止时）
+func (r *eventReporter) close() {
+	r.mu.Lock()
+	runIDs := make([]string, 0, len(r.queues))
+	for id := range r.queues {
+		runIDs = append(runIDs, id)
+	}
+	r.mu.Unlock()
+	for _, id := range runIDs {
+		r.drain(id)
+	}
+}
+
+// run 按批次上报队列中的事件：达到 BatchSize、等待超过 FlushInterval 或队列关闭时上报
+func (r *eventReporter) run(runID string, q *eventQueue) {
+	defer close(q.done)
+	for {
+		event, ok := <-q.ch
+		if !ok {
+			return
+		}
+		batch := []json.RawMessage{event}
+		timer := time.NewTimer(r.cfg.FlushInterval)
+	collect:
+		for len(batch) < r.cfg.BatchSize {
+			select {
+			case e, ok := <-q.ch:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, e)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		r.deliver(runID, batch)
+	}
+}
+
+// deliver 上报一个批次；本地缓存中有更早的事件时先回放，保证事件按序号入库
+func (r *eventReporter) deliver(runID string, batch []json.RawMessage) {
+	if r.spool != nil {
+		sr := r.spool.run(runID)
+		sr.mu.Lock()
+		if r.spool.exists(runID) {
+			if err := r.replayLocked(runID, sr); err != nil {
+				r.spoolLocked(runID, batch)
+				sr.mu.Unlock()
+				return
+			}
+		}
+		sr.mu.Unlock()
+	}
+
+	err := r.sendWithRetry(runID, batch)
+	if err == nil {
+		return
+	}
+	if r.spool == nil {
+		log.Printf("[Events] 任务 %s 上报 %d 个事件失败，已丢弃: %v", runID, len(batch), err)
+		return
+	}
+	sr := r.spool.run(runID)
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	sr.deferRetry(r.cfg.RetryInterval)
+	r.spoolLocked(runID, batch)
+}
+
+// sendWithRetry 上报批次，失败时按指数退避重试 MaxRetries 次；被 API Server 拒绝的批次直接丢弃
+func (r *eventReporter) sendWithRetry(runID string, batch []json.RawMessage) error {
+	delay := r.cfg.RetryInterval
+	for attempt := 0; ; attempt++ {
+		err := r.sendOnce(runID, batch)
+		if err == nil {
+			return nil
+		}
+		if attempt >= r.cfg.MaxRetries {
+			return err
+		}
+		log.Printf("[Events] 任务 %s 上报事件失败，%s 后重试: %v", runID, delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, maxEventRetryInterval)
+	}
+}
+
+// sendOnce 上报一次批次，超过 API Server 上限时对半拆分；被拒绝的批次记录日志后视为已处理
+func (r *eventReporter) sendOnce(runID string, batch []json.RawMessage) error {
+	err := r.send(context.Background(), runID, batch)
+	if errors.Is(err, errEventBatchTooLarge) && len(batch) > 1 {
+		half := len(batch) / 2
+		if err := r.sendOnce(runID, batch[:half]); err != nil {
+			return err
+		}
+		return r.sendOnce(runID, batch[half:])
+	}
+	if errors.Is(err, errEventsRejected) || errors.Is(err, errEventBatchTooLarge) {
+		log.Printf("[Events] 任务 %s 的 %d 个事件被 API Server 拒绝，已丢弃: %v", runID, len(batch), err)
+		return nil
+	}
+	return err
+}
+
+// spoolLocked 将批次追加到本地缓存（调用方持有 spoolRun.mu）
+func (r *eventReporter) spoolLocked(runID string, batch []json.RawMessage) {
+	if err := r.spool.append(runID, batch); err != nil {
+		log.Printf("[Events] 任务 %s 写入本地缓存失败，%d 个事件已丢弃: %v", runID, len(batch), err)
+		return
+	}
+	log.Printf("[Events] API Server 不可达，任务 %s 的 %d 个事件已写入本地缓存", runID, len(batch))
+}
+
+// report 上报 Run 的状态、产物或节点日志记录
+//
+// 缓存中还有该 Run 的记录时先回放，回放失败则追加到缓存末尾；上报失败时写入缓存，
+// 由回放循环在 API Server 恢复后按顺序上报。未配置缓存时只上报一次。
+func (r *eventReporter) report(runID string, rec spoolRecord) {
+	if r.spool == nil {
+		if err := r.sendRecordOnce(runID, rec); err != nil {
+			log.Printf("[Events] 任务 %s 上报 %s 失败: %v", runID, rec.describe(), err)
+		}
+		return
+	}
+	sr := r.spool.run(runID)
+	sr.mu.Lock()
+	defer sr.mu.Unlock()
+	if r.spool.exists(runID) {
+		if err := r.replayLocked(runID, sr); err != nil {
+			r.spoolRecordLocked(runID, rec)
+			return
+		}
+	}
+	if err := r.sendRecordOnce(runID, rec); err != nil {
+		sr.deferRetry(r.cfg.RetryInterval)
+		r.spoolRecordLocked(runID, rec)
+	}
+}
+
+// sendRecordOnce 上报一条状态、产物或节点日志记录，被 API Server 拒绝的记录记录日志后视为已处理
+func (r *eventReporter) sendRecordOnce(runID string, rec spoolRecord) error {
+	if r.sendRecord == nil {
+		return nil
+	}
+	err := r.sendRecord(context.Background(), runID, rec)
+	if errors.Is(err, errEventsRejected) {
+		log.Printf("[Events] 任务 %s 的 %s 被 API Server 拒绝，已丢弃: %v", runID, rec.describe(), err)
+		return nil
+	}
+	return err
+}
+
+// spoolRecordLocked 将状态、产物或节点日志记录追加到本地缓存（调用方持有 spoolRun.mu）
+func (r *eventReporter) spoolRecordLocked(runID string, rec spoolRecord) {
+	line, _ := json.Marshal(rec)
+	if err := r.spool.append(runID, []json.RawMessage{line}); err != nil {
+		log.Printf("[Events] 任务 %s 写入本地缓存失败，%s 已丢弃: %v", runID, rec.describe(), err)
+		return
+	}
+	log.Printf("[Events] API Server 不可达，任务 %s 的 %s 已写入本地缓存", runID, rec.describe())
+}
+
+// replayLocked 按顺序回放 Run 的本地缓存（调用方持有 spoolRun.mu）
+//
+// 连续的事件按批次上报，状态与产物记录逐条上报。未到下一次回放时间时直接返回错误；
+// 回放失败时保留未上报的记录并推迟下一次回放。
+func (r *eventReporter) replayLocked(runID string, sr *spoolRun) error {
+	if wait := time.Until(sr.retryAt); wait > 0 {
+		return fmt.Errorf("回放推迟 %s", wait.Round(time.Millisecond))
+	}
+	lines, err := r.spool.read(runID)
+	if err != nil {
+		log.Printf("[Events] 任务 %s 读取本地缓存失败: %v", runID, err)
+		return err
+	}
+	for start := 0; start < len(lines); {
+		end := start + 1
+		if rec, ok := parseSpoolRecord(lines[start]); ok {
+			err = r.sendRecordOnce(runID, rec)
+		} else {
+			for end < len(lines) && end-start < r.cfg.BatchSize {
+				if _, ok := parseSpoolRecord(lines[end]); ok {
+					break
+				}
+				end++
+			}
+			err = r.sendOnce(runID, lines[start:end])
+		}
+		if err != nil {
+			sr.deferRetry(r.cfg.RetryInterval)
+			if start > 0 {
+				if werr := r.spool.rewrite(runID, lines[start:]); werr != nil {
+					log.Printf("[Events] 任务 %s 更新本地缓存失败: %v", runID, werr)
+				}
+			}
+			return err
+		}
+		start = end
+	}
+	sr.failures, sr.retryAt = 0, time.Time{}
+	if err := r.spool.remove(runID); err != nil {
+		log.Printf("[Events] 任务 %s 删除本地缓存失败: %v", runID, err)
+	}
+	log.Printf("[Events] 任务 %s 已回放本地缓存的 %d 条记录", runID, len(lines))
+	return nil
+}
+
+// replaySpool 回放本地缓存中全部 Run 的事件（包括上一次进程退出前未上报的事件）
+func (r *eventReporter) replaySpool() {
+	if r.spool == nil {
+		return
+	}
+	for _, runID := range r.spool.runIDs() {
+		sr := r.spool.run(runID)
+		sr.mu.Lock()
+		if r.spool.exists(runID) {
+			r.replayLocked(runID, sr)
+		}
+		sr.mu.Unlock()
+	}
+}
+
+// replayLoop 定期回放本地缓存，直到 ctx 取消
+func (r *eventReporter) replayLoop(ctx context.Context) {
+	if r.spool == nil {
+		return
+	}
+	r.replaySpool()
+	ticker := time.NewTicker(maxEventRetryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.replaySpool()
+		case <-r.wake:
+			r.replaySpool()
+		}
+	}
+}
+
+// connected API Server 可达（心跳成功）时唤醒回放循环，不必等到下一次定期回放
+func (r *eventReporter) connected() {
+	if r == nil || r.spool == nil || len(r.spool.runIDs()) == 0 {
+		return
+	}
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// spoolStatus 本地缓存深度，未配置缓存时返回 nil
+func (r *eventReporter) spoolStatus() *model.NodeSpoolStatus {
+	if r == nil || r.spool == nil {
+		return nil
+	}
+	return r.spool.status()
+}
+
+func spoolToProto(st *model.NodeSpoolStatus) *nodev1.SpoolStatus {
+	if st == nil {
+		return nil
+	}
+	return &nodev1.SpoolStatus{
+		Runs: int32(st.Runs), Records: int32(st.Records), Segments: int32(st.Segments),
+		Bytes: st.Bytes, MaxBytes: st.MaxBytes, Dropped: st.Dropped,
+	}
+}
+
+// ============================================================================
+// 本地缓存
+// ============================================================================
+
+// spoolRecord 本地缓存中与事件按写入顺序回放的非事件记录（Run 状态、产物与节点日志）
+//
+// 以 _spool 字段区分记录类型，事件 JSON 不包含该字段。
+type spoolRecord struct {
+	Kind     string          `json:"_spool"`             // status / artifact / node_log
+	Status   string          `json:"status,omitempty"`   // Run 状态
+	Artifact json.RawMessage `json:"artifact,omitempty"` // 产物上报请求体
+	Logs     json.RawMessage `json:"logs,omitempty"`     // 节点日志上报请求体
+}
+
+// 本地缓存记录类型
+const (
+	spoolKindStatus   = "status"
+	spoolKindArtifact = "artifact"
+	spoolKindNodeLog  = "node_log"
+)
+
+// describe 记录的日志描述
+func (rec spoolRecord) describe() string {
+	switch rec.Kind {
+	case spoolKindStatus:
+		return "状态 " + rec.Status
+	case spoolKindNodeLog:
+		return "节点日志"
+	}
+	return "产物"
+}
+
+// parseSpoolRecord 解析缓存中的一行，不是状态、产物或节点日志记录（即事件）时返回 false
+func parseSpoolRecord(line json.RawMessage) (spoolRecord, bool) {
+	var rec spoolRecord
+	if !bytes.Contains(line, []byte(`"_spool"`)) || json.Unmarshal(line, &rec) != nil {
+		return rec, false
+	}
+	return rec, rec.Kind != ""
+}
+
+// eventSpool API Server 不可达时的本地缓存
+//
+// 每个 Run 的事件、状态与产物记录按上报顺序追加到分段文件 <runID>.jsonl、<runID>.1.jsonl……，
+// 当前分段超过 segmentBytes 后轮转到下一个分段。全部分段超过 maxBytes 时，
+// 按创建时间从早到晚丢弃分段中的事件（状态与产物记录保留），保证磁盘占用有界。
+type eventSpool struct {
+	dir          string
+	maxBytes     int64 // 0 表示不限制
+	segmentBytes int64
+
+	mu       sync.Mutex
+	runs     map[string]*spoolRun
+	segments map[string][]*spoolSegment // Run ID → 按序号排列的分段（修改时同时持有 spoolRun.mu）
+	dropped  int64                      // 因超过上限丢弃的事件数（进程启动以来累计）
+}
+
+// spoolRun 单个 Run 缓存文件的互斥与回放退避状态
+type spoolRun struct {
+	mu       sync.Mutex
+	failures int       // 连续回放失败次数
+	retryAt  time.Time // 下一次回放时间
+}
+
+// spoolSegment 缓存分段文件
+type spoolSegment struct {
+	index   int
+	bytes   int64
+	records int       // 记录数（事件、状态与产物）
+	events  int       // 其中的事件数
+	created time.Time // 超过上限时先丢弃创建最早的分段
+}
+
+// newEventSpool 创建本地缓存，加载目录中已有的分段（上一次进程退出前未回放的记录）
+func newEventSpool(dir string, maxBytes int64) *eventSpool {
+	s := &eventSpool{
+		dir:          dir,
+		maxBytes:     maxBytes,
+		segmentBytes: defaultEventSpoolSegmentBytes,
+		runs:         make(map[string]*spoolRun),
+		segments:     make(map[string][]*spoolSegment),
+	}
+	if maxBytes > 0 {
+		s.segmentBytes = min(s.segmentBytes, max(maxBytes/8, 1))
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return s
+	}
+	for _, e := range entries {
+		runID, index, ok := parseSpoolSegmentName(e.Name())
+		if e.IsDir() || !ok {
+			continue
+		}
+		seg := &spoolSegment{index: index}
+		if info, err := e.Info(); err == nil {
+			seg.created = info.ModTime()
+		}
+		lines, err := readSpoolFile(s.segmentPath(runID, index))
+		if err != nil {
+			continue
+		}
+		seg.add(lines)
+		s.segments[runID] = append(s.segments[runID], seg)
+	}
+	for _, segs := range s.segments {
+		slices.SortFunc(segs, func(a, b *spoolSegment) int { return a.index - b.index })
+	}
+	return s
+}
+
+// parseSpoolSegmentName 解析分段文件名：<runID>.jsonl 为第 0 段，<runID>.<n>.jsonl 为第 n 段
+func parseSpoolSegmentName(name string) (runID string, index int, ok bool) {
+	base, ok := strings.CutSuffix(name, eventSpoolSuffix)
+	if !ok || base == "" {
+		return "", 0, false
+	}
+	if dot := strings.LastIndexByte(base, '.'); dot > 0 {
+		if n, err := strconv.Atoi(base[dot+1:]); err == nil && n > 0 {
+			return base[:dot], n, true
+		}
+	}
+	return base, 0, true
+}
+
+// add 记录追加到分段的行
+func (seg *spoolSegment) add(lines []json.RawMessage) {
+	for _, line := range lines {
+		seg.bytes += int64(len(line)) + 1
+		seg.records++
+		if _, ok := parseSpoolRecord(line); !ok {
+			seg.events++
+		}
+	}
+}
+
+// deferRetry 记录一次失败，按指数退避推迟下一次回放
+func (sr *spoolRun) deferRetry(base time.Duration) {
+	delay := base << min(sr.failures, 16)
+	sr.failures++
+	sr.retryAt = time.Now().Add(min(delay, maxEventRetryInterval))
+}
+
+func (s *eventSpool) run(runID string) *spoolRun {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sr, ok := s.runs[runID]
+	if !ok {
+		sr = &spoolRun{}
+		s.runs[runID] = sr
+	}
+	return sr
+}
+
+func (s *eventSpool) segmentPath(runID string, index int) string {
+	name := filepath.Base(runID)
+	if index > 0 {
+		name += "." + strconv.Itoa(index)
+	}
+	return filepath.Join(s.dir, name+eventSpoolSuffix)
+}
+
+func (s *eventSpool) exists(runID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.segments[runID]) > 0
+}
+
+// append 将记录逐行追加到 Run 的当前分段，分段写满时轮转（调用方持有 spoolRun.mu）
+func (s *eventSpool) append(runID string, lines []json.RawMessage) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	segs := s.segments[runID]
+	var seg *spoolSegment
+	if n := len(segs); n > 0 && segs[n-1].bytes < s.segmentBytes {
+		seg = segs[n-1]
+	} else {
+		seg = &spoolSegment{created: time.Now()}
+		if n > 0 {
+			seg.index = segs[n-1].index + 1
+		}
+	}
+	s.mu.Unlock()
+
+	f, err := os.OpenFile(s.segmentPath(runID, seg.index), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(joinEventLines(lines)); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	if n := len(s.segments[runID]); n == 0 || s.segments[runID][n-1] != seg {
+		s.segments[runID] = append(s.segments[runID], seg)
+	}
+	seg.add(lines)
+	s.mu.Unlock()
+	s.trim(runID, seg)
+	return nil
+}
+
+// trim 总大小超过 maxBytes 时丢弃最早创建的分段中的事件，直到回到上限以内
+//
+// keep 为刚写入的分段，不参与丢弃；其他 Run 正在回放或写入时跳过它的分段。
+func (s *eventSpool) trim(runID string, keep *spoolSegment) {
+	if s.maxBytes <= 0 {
+		return
+	}
+	skipped := make(map[string]bool)
+	for {
+		s.mu.Lock()
+		var total int64
+		var oldestRun string
+		var oldest *spoolSegment
+		for id, segs := range s.segments {
+			for _, seg := range segs {
+				total += seg.bytes
+				if seg == keep || seg.events == 0 || skipped[id] {
+					continue
+				}
+				if oldest == nil || seg.created.Before(oldest.created) {
+					oldestRun, oldest = id, seg
+				}
+			}
+		}
+		s.mu.Unlock()
+		if total <= s.maxBytes || oldest == nil {
+			return
+		}
+
+		// 调用方已持有本 Run 的锁，其他 Run 的锁只尝试获取，避免与其回放互相等待
+		var sr *spoolRun
+		if oldestRun != runID {
+			sr = s.run(oldestRun)
+			if !sr.mu.TryLock() {
+				skipped[oldestRun] = true
+				continue
+			}
+		}
+		dropped, err := s.dropEvents(oldestRun, oldest)
+		if sr != nil {
+			sr.mu.Unlock()
+		}
+		if errors.Is(err, os.ErrNotExist) {
+			continue // 分段已被回放删除
+		}
+		if err != nil {
+			log.Printf("[Events] 任务 %s 裁剪本地缓存失败: %v", oldestRun, err)
+			skipped[oldestRun] = true
+			continue
+		}
+		log.Printf("[Events] 本地缓存超过上限 %d 字节，已丢弃任务 %s 的 %d 个事件", s.maxBytes, oldestRun, dropped)
+	}
+}
+
+// dropEvents 删除分段中的事件，只保留状态与产物记录（调用方持有该 Run 的 spoolRun.mu）
+func (s *eventSpool) dropEvents(runID string, seg *spoolSegment) (int, error) {
+	path := s.segmentPath(runID, seg.index)
+	lines, err := readSpoolFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var kept []json.RawMessage
+	for _, line := range lines {
+		if _, ok := parseSpoolRecord(line); ok {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) == 0 {
+		err = os.Remove(path)
+	} else {
+		tmp := path + ".tmp"
+		if err = os.WriteFile(tmp, joinEventLines(kept), 0600); err == nil {
+			err = os.Rename(tmp, path)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dropped := len(lines) - len(kept)
+	s.dropped += int64(dropped)
+	if len(kept) > 0 {
+		*seg = spoolSegment{index: seg.index, created: seg.created}
+		seg.add(kept)
+		return dropped, nil
+	}
+	segs := slices.DeleteFunc(s.segments[runID], func(x *spoolSegment) bool { return x == seg })
+	if len(segs) == 0 {
+		delete(s.segments, runID)
+	} else {
+		s.segments[runID] = segs
+	}
+	return dropped, nil
+}
+
+// rewrite 以剩余记录替换 Run 的全部分段（部分回放成功后）
+func (s *eventSpool) rewrite(runID string, lines []json.RawMessage) error {
+	tmp := s.segmentPath(runID, 0) + ".tmp"
+	if err := os.WriteFile(tmp, joinEventLines(lines), 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, s.segmentPath(runID, 0)); err != nil {
+		return err
+	}
+	seg := &spoolSegment{created: time.Now()}
+	seg.add(lines)
+
+	s.mu.Lock()
+	old := s.segments[runID]
+	if len(old) > 0 {
+		seg.created = old[0].created
+	}
+	s.segments[runID] = []*spoolSegment{seg}
+	s.mu.Unlock()
+	for _, o := range old {
+		if o.index > 0 {
+			os.Remove(s.segmentPath(runID, o.index))
+		}
+	}
+	return nil
+}
+
+// read 按写入顺序读取 Run 全部分段中的记录
+func (s *eventSpool) read(runID string) ([]json.RawMessage, error) {
+	s.mu.Lock()
+	segs := slices.Clone(s.segments[runID])
+	s.mu.Unlock()
+	var lines []json.RawMessage
+	for _, seg := range segs {
+		l, err := readSpoolFile(s.segmentPath(runID, seg.index))
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, l...)
+	}
+	return lines, nil
+}
+
+// readSpoolFile 读取一个分段文件中的记录
+func readSpoolFile(path string) ([]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var lines []json.RawMessage
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 || !json.Valid(line) {
+			// 进程在写入中途退出时最后一行可能不完整
+			continue
+		}
+		lines = append(lines, json.RawMessage(bytes.Clone(line)))
+	}
+	return lines, scanner.Err()
+}
+
+// remove 删除 Run 的全部分段
+func (s *eventSpool) remove(runID string) error {
+	s.mu.Lock()
+	segs := s.segments[runID]
+	delete(s.segments, runID)
+	s.mu.Unlock()
+	var errs []error
+	for _, seg := range segs {
+		if err := os.Remove(s.segmentPath(runID, seg.index)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// runIDs 缓存中有记录的 Run
+func (s *eventSpool) runIDs() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ids := make([]string, 0, len(s.segments))
+	for id := range s.segments {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// status 缓存深度（心跳 capacity.spool 上报）
+func (s *eventSpool) status() *model.NodeSpoolStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st := &model.NodeSpoolStatus{Runs: len(s.segments), Dropped: s.dropped, MaxBytes: s.maxBytes}
+	for _, segs := range s.segments {
+		st.Segments += len(segs)
+		for _, seg := range segs {
+			st.Records += seg.records
+			st.Bytes += seg.bytes
+		}
+	}
+	return st
+}
+
+func joinEventLines(events []json.RawMessage) []byte {
+	var buf bytes.Buffer
+	for _, e := range events {
+		buf.Write(e)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// ============================================================================
+// 上报请求
+// ============================================================================
+
+// postEvents 批量上报事件到 API Server
+//
+// 400 / 403 / 404 返回 errEventsRejected（请求本身无效、Run 未分配给本节点或不存在），413 返回 errEventBatchTooLarge，
+// 其他非 2xx 响应与网络错误可以重试。API Server 忽略节点序号已入库的事件，整批重试不会重复写入。
+func (nm *NodeManager) postEvents(ctx context.Context, runID string, events []json.RawMessage) error {
+	if nm.grpc != nil {
+		return nm.grpc.reportEvents(ctx, runID, events)
+	}
+	body, _ := json.Marshal(map[string]interface{}{"events": events})
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/runs/"+runID+"/events",
+		bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode == http.StatusRequestEntityTooLarge:
+		return errEventBatchTooLarge
+	case resp.StatusCode == http.StatusBadRequest || resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: status %d", errEventsRejected, resp.StatusCode)
+	default:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
@@ -0,0 +1,340 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/chaos"
+)
+
+// fakeEventSink 记录每次批量上报的事件序号，fail 返回错误时上报失败
+type fakeEventSink struct {
+	mu      sync.Mutex
+	batches [][]int
+	fail    func(attempt int) error
+	calls   int
+}
+
+func (s *fakeEventSink) send(_ context.Context, _ string, events []json.RawMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	if s.fail != nil {
+		if err := s.fail(s.calls); err != nil {
+			return err
+		}
+	}
+	seqs := make([]int, 0, len(events))
+	for _, e := range events {
+		var ev struct {
+			Seq int `json:"seq"`
+		}
+		json.Unmarshal(e, &ev)
+		seqs = append(seqs, ev.Seq)
+	}
+	s.batches = append(s.batches, seqs)
+	return nil
+}
+
+func (s *fakeEventSink) delivered() [][]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.batches)
+}
+
+func enqueueSeqs(r *eventReporter, runID string, from, to int) {
+	for seq := from; seq <= to; seq++ {
+		r.enqueue(runID, json.RawMessage(fmt.Sprintf(`{"seq":%d,"type":"message"}`, seq)))
+	}
+}
+
+func flatten(batches [][]int) []int {
+	var all []int
+	for _, b := range batches {
+		all = append(all, b...)
+	}
+	return all
+}
+
+func TestEventReporter_BatchBySize(t *testing.T) {
+	sink := &fakeEventSink{}
+	r := newEventReporter(EventReportConfig{BatchSize: 3, FlushInterval: time.Hour}, sink.send)
+
+	enqueueSeqs(r, "run-1", 1, 7)
+	r.drain("run-1")
+
+	got := sink.delivered()
+	if len(got) != 3 || !slices.Equal(flatten(got), []int{1, 2, 3, 4, 5, 6, 7}) || len(got[0]) != 3 {
+		t.Errorf("batches = %v", got)
+	}
+}
+
+func TestEventReporter_FlushInterval(t *testing.T) {
+	sink := &fakeEventSink{}
+	r := newEventReporter(EventReportConfig{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, sink.send)
+	defer r.close()
+
+	enqueueSeqs(r, "run-1", 1, 2)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.delivered()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := sink.delivered(); len(got) != 1 || !slices.Equal(got[0], []int{1, 2}) {
+		t.Errorf("batches = %v", got)
+	}
+}
+
+func TestEventReporter_RetryWithBackoff(t *testing.T) {
+	sink := &fakeEventSink{fail: func(attempt int) error {
+		if attempt <= 2 {
+			return errors.New("connection refused")
+		}
+		return nil
+	}}
+	dir := t.TempDir()
+	r := newEventReporter(EventReportConfig{MaxRetries: 3, RetryInterval: time.Millisecond, SpoolDir: dir}, sink.send)
+
+	enqueueSeqs(r, "run-1", 1, 2)
+	r.drain("run-1")
+
+	if got := sink.delivered(); len(got) != 1 || !slices.Equal(got[0], []int{1, 2}) || sink.calls != 3 {
+		t.Errorf("batches = %v, calls = %d", got, sink.calls)
+	}
+	if r.spool.exists("run-1") {
+		t.Error("重试成功后不应写入本地缓存")
+	}
+}
+
+func TestEventReporter_SpoolAndReplay(t *testing.T) {
+	down := true
+	sink := &fakeEventSink{fail: func(int) error {
+		if down {
+			return errors.New("connection refused")
+		}
+		return nil
+	}}
+	dir := t.TempDir()
+	cfg := EventReportConfig{BatchSize: 2, MaxRetries: 1, RetryInterval: time.Millisecond, SpoolDir: dir}
+	r := newEventReporter(cfg, sink.send)
+
+	enqueueSeqs(r, "run-1", 1, 3)
+	r.drain("run-1")
+	if !r.spool.exists("run-1") || len(sink.delivered()) != 0 {
+		t.Fatalf("API Server 不可达时事件应写入本地缓存, delivered = %v", sink.delivered())
+	}
+
+	// API Server 恢复：新批次先回放缓存，保持序号顺序
+	sink.mu.Lock()
+	down = false
+	sink.mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	enqueueSeqs(r, "run-1", 4, 4)
+	r.drain("run-1")
+
+	if got := flatten(sink.delivered()); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("delivered = %v", got)
+	}
+	if r.spool.exists("run-1") {
+		t.Error("回放后应删除本地缓存")
+	}
+}
+
+func TestEventReporter_ReplaySpoolAfterRestart(t *testing.T) {
+	dir := t.TempDir()
+	failing := newEventReporter(EventReportConfig{MaxRetries: -1, SpoolDir: dir}, func(context.Context, string, []json.RawMessage) error {
+		return errors.New("connection refused")
+	})
+	enqueueSeqs(failing, "run-1", 1, 2)
+	enqueueSeqs(failing, "run-2", 1, 1)
+	failing.close()
+
+	sink := &fakeEventSink{}
+	r := newEventReporter(EventReportConfig{SpoolDir: dir}, sink.send)
+	r.replaySpool()
+
+	if got := sink.delivered(); len(got) != 2 || len(flatten(got)) != 3 {
+		t.Errorf("delivered = %v", got)
+	}
+	if ids := r.spool.runIDs(); len(ids) != 0 {
+		t.Errorf("remaining spool = %v", ids)
+	}
+}
+
+func TestEventReporter_RejectedNotSpooled(t *testing.T) {
+	sink := &fakeEventSink{fail: func(int) error {
+		return fmt.Errorf("%w: status 404", errEventsRejected)
+	}}
+	dir := t.TempDir()
+	r := newEventReporter(EventReportConfig{MaxRetries: 3, RetryInterval: time.Millisecond, SpoolDir: dir}, sink.send)
+
+	enqueueSeqs(r, "run-1", 1, 1)
+	r.drain("run-1")
+
+	if sink.calls != 1 || r.spool.exists("run-1") {
+		t.Errorf("被拒绝的事件不应重试或缓存: calls = %d", sink.calls)
+	}
+}
+
+func TestEventReporter_SplitTooLargeBatch(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+	r := newEventReporter(EventReportConfig{BatchSize: 8, FlushInterval: time.Hour}, func(_ context.Context, _ string, events []json.RawMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(events) > 3 {
+			return errEventBatchTooLarge
+		}
+		sizes = append(sizes, len(events))
+		return nil
+	})
+
+	enqueueSeqs(r, "run-1", 1, 8)
+	r.drain("run-1")
+
+	if !slices.Equal(sizes, []int{2, 2, 2, 2}) {
+		t.Errorf("split batches = %v", sizes)
+	}
+}
+
+func TestEventReporter_RetryUnderInjectedHTTPFailures(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		seqs []int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []struct {
+				Seq int `json:"seq"`
+			} `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		for _, e := range body.Events {
+			seqs = append(seqs, e.Seq)
+		}
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	// 约一半请求失败（先注入连接错误，之后改为 503），重试后所有事件按序送达且不重复
+	inj := chaos.New(chaos.Config{Seed: 7, HTTPError: 0.5})
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: chaos.WrapClient(nil, inj)}
+	r := newEventReporter(EventReportConfig{BatchSize: 2, FlushInterval: time.Hour, MaxRetries: 20, RetryInterval: time.Millisecond}, nm.postEvents)
+
+	enqueueSeqs(r, "run-1", 1, 5)
+	inj.Set(chaos.Config{HTTPError: 0.5, HTTPStatus: http.StatusServiceUnavailable})
+	enqueueSeqs(r, "run-1", 6, 10)
+	r.drain("run-1")
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(seqs, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}) {
+		t.Errorf("delivered seqs = %v", seqs)
+	}
+	if inj.Count(chaos.FaultHTTPError) == 0 {
+		t.Error("expected injected HTTP failures")
+	}
+}
+
+func TestEventReporter_SpoolStatusInOrder(t *testing.T) {
+	var (
+		mu   sync.Mutex
+		down = true
+		got  []string
+	)
+	send := func(_ context.Context, _ string, events []json.RawMessage) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			return errors.New("connection refused")
+		}
+		got = append(got, fmt.Sprintf("events:%d", len(events)))
+		return nil
+	}
+	r := newEventReporter(EventReportConfig{BatchSize: 10, MaxRetries: -1, RetryInterval: time.Millisecond, SpoolDir: t.TempDir()}, send)
+	r.sendRecord = func(_ context.Context, _ string, rec spoolRecord) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if down {
+			return errors.New("connection refused")
+		}
+		got = append(got, rec.Kind+":"+rec.Status+string(rec.Artifact))
+		return nil
+	}
+
+	enqueueSeqs(r, "run-1", 1, 3)
+	r.drain("run-1")
+	r.report("run-1", spoolRecord{Kind: spoolKindArtifact, Artifact: json.RawMessage(`{"name":"pr"}`)})
+	r.report("run-1", spoolRecord{Kind: spoolKindStatus, Status: "done"})
+	if st := r.spoolStatus(); st.Runs != 1 || st.Records != 5 {
+		t.Fatalf("spool status = %+v", st)
+	}
+
+	// API Server 恢复：事件、产物、状态按写入顺序回放
+	mu.Lock()
+	down = false
+	mu.Unlock()
+	time.Sleep(10 * time.Millisecond)
+	r.replaySpool()
+
+	want := []string{"events:3", `artifact:{"name":"pr"}`, "status:done"}
+	if !slices.Equal(got, want) {
+		t.Errorf("replayed = %v, want %v", got, want)
+	}
+	if st := r.spoolStatus(); st.Runs != 0 || st.Records != 0 || st.Bytes != 0 {
+		t.Errorf("spool status after replay = %+v", st)
+	}
+}
+
+func TestEventSpool_RotateAndBound(t *testing.T) {
+	dir := t.TempDir()
+	s := newEventSpool(dir, 400)
+	s.segmentBytes = 100
+
+	event := func(seq int) json.RawMessage {
+		return json.RawMessage(fmt.Sprintf(`{"seq":%d,"type":"message","payload":{"text":"xxxxxxxx"}}`, seq))
+	}
+	status, _ := json.Marshal(spoolRecord{Kind: spoolKindStatus, Status: "running"})
+	s.append("run-1", []json.RawMessage{status})
+	for seq := 1; seq <= 20; seq++ {
+		s.append("run-1", []json.RawMessage{event(seq)})
+	}
+
+	st := s.status()
+	if st.Segments < 2 || st.Bytes > 400 || st.Dropped == 0 {
+		t.Fatalf("spool status = %+v", st)
+	}
+	lines, err := s.read("run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rec, ok := parseSpoolRecord(lines[0]); !ok || rec.Status != "running" {
+		t.Errorf("状态记录应在裁剪后保留: first = %s", lines[0])
+	}
+	var last struct {
+		Seq int `json:"seq"`
+	}
+	json.Unmarshal(lines[len(lines)-1], &last)
+	if last.Seq != 20 || st.Records != len(lines) {
+		t.Errorf("last seq = %d, records = %d, lines = %d", last.Seq, st.Records, len(lines))
+	}
+
+	// 重启后从目录加载分段，记录数与顺序不变
+	reloaded := newEventSpool(dir, 400)
+	if rs := reloaded.status(); rs.Records != st.Records || rs.Segments != st.Segments {
+		t.Errorf("reloaded status = %+v, want %+v", rs, st)
+	}
+	again, _ := reloaded.read("run-1")
+	if len(again) != len(lines) || string(again[0]) != string(lines[0]) {
+		t.Errorf("reloaded lines = %d", len(again))
+	}
+}
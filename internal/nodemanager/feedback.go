@@ -0,0 +1,208 @@
+// Package nodemanager 运行中人工反馈注入
+//
+// 用户对运行中的 Run 提交的人工反馈随心跳指令 feedbacks 下发，注入到 Agent 的控制通道：
+//   - stdin：Adapter 实现 adapter.FeedbackReceiver 时，Agent 命令以 stdin 管道启动，
+//     反馈写入管道即视为被 Agent 读取，上报 feedback_consumed 事件
+//   - interrupt 文件：其他 Adapter 的 Prompt 中附带 adapter.FeedbackPromptHint，
+//     反馈追加到工作目录的 adapter.FeedbackFile 并上报 feedback_delivered 事件；
+//     Agent 之后读取该文件（工具调用、读文件或命令事件中出现该路径）时上报 feedback_consumed
+//
+// 反馈在 Agent 输出下一条事件时写入（与事件序号保持一致），Run 结束后删除 interrupt 文件，
+// 避免反馈内容被回写到 Git 仓库。API Server 收到 feedback_consumed 后不再下发该反馈。
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+	"sync"
+
+	"agents-admin/internal/nodemanager/adapter"
+)
+
+// feedbackChannel 单个 Run 的人工反馈控制通道
+type feedbackChannel struct {
+	nm     *NodeManager
+	runID  string
+	target execTarget
+	stdin  io.WriteCloser                               // 非 nil 时反馈写入 Agent 的 stdin
+	format func(adapter.Feedback) []byte                // stdin 写入内容的编码（Adapter 提供）
+	write  func(ctx context.Context, data []byte) error // 追加到 interrupt 文件（测试可替换）
+
+	mu         sync.Mutex
+	seen       map[string]bool    // 已排队或已注入的反馈 ID（心跳会重复下发未被读取的反馈）
+	queue      []adapter.Feedback // 等待注入的反馈
+	unconsumed []adapter.Feedback // 已写入 interrupt 文件、Agent 尚未读取的反馈
+	fileUsed   bool
+}
+
+// newFeedbackChannel 创建 Run 的人工反馈控制通道并登记（心跳指令按 Run ID 投递），
+// 需要在构建 Agent 命令之前调用
+func (nm *NodeManager) newFeedbackChannel(runID string, target execTarget, a adapter.Adapter) *feedbackChannel {
+	fc := &feedbackChannel{nm: nm, runID: runID, target: target, seen: make(map[string]bool)}
+	if r, ok := a.(adapter.FeedbackReceiver); ok {
+		fc.format = r.FormatFeedback
+		if dt, ok := target.(*dockerTarget); ok {
+			dt.stdin = true
+		}
+	}
+	fc.write = fc.appendFile
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.feedbacks == nil {
+		nm.feedbacks = make(map[string]*feedbackChannel)
+	}
+	nm.feedbacks[runID] = fc
+	return fc
+}
+
+// usesStdin 反馈是否通过 stdin 管道注入
+func (fc *feedbackChannel) usesStdin() bool {
+	return fc.format != nil
+}
+
+// QueueFeedback 排队注入人工反馈（心跳指令 feedbacks），Run 不在本节点执行或反馈已排队时忽略
+func (nm *NodeManager) QueueFeedback(runID string, f adapter.Feedback) {
+	nm.mu.Lock()
+	fc, ok := nm.feedbacks[runID]
+	nm.mu.Unlock()
+	if !ok {
+		return
+	}
+	fc.mu.Lock()
+	defer fc.mu.Unlock()
+	if fc.seen[f.ID] {
+		return
+	}
+	fc.seen[f.ID] = true
+	fc.queue = append(fc.queue, f)
+}
+
+// unregisterFeedbackChannel Run 结束时注销控制通道
+func (nm *NodeManager) unregisterFeedbackChannel(runID string) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	delete(nm.feedbacks, runID)
+}
+
+// flush 将排队的反馈注入 Agent，返回下一个事件序号
+func (fc *feedbackChannel) flush(ctx context.Context, seq int) int {
+	fc.mu.Lock()
+	queue := fc.queue
+	fc.queue = nil
+	fc.mu.Unlock()
+
+	for _, f := range queue {
+		if err := fc.inject(ctx, f); err != nil {
+			log.Printf("[Feedback] 任务 %s 注入反馈 %s 失败: %v", fc.runID, f.ID, err)
+			fc.nm.reportEvent(ctx, fc.runID, seq, "warning", map[string]interface{}{
+				"code":        "feedback_delivery_failed",
+				"message":     err.Error(),
+				"feedback_id": f.ID,
+			})
+			seq++
+			// 下一次心跳重新下发
+			fc.mu.Lock()
+			delete(fc.seen, f.ID)
+			fc.mu.Unlock()
+			continue
+		}
+		if fc.usesStdin() {
+			fc.nm.reportEvent(ctx, fc.runID, seq, "feedback_consumed", map[string]interface{}{
+				"feedback_id": f.ID,
+				"type":        f.Type,
+				"channel":     "stdin",
+			})
+		} else {
+			fc.nm.reportEvent(ctx, fc.runID, seq, "feedback_delivered", map[string]interface{}{
+				"feedback_id": f.ID,
+				"type":        f.Type,
+				"channel":     "file",
+				"path":        adapter.FeedbackFile,
+			})
+			fc.mu.Lock()
+			fc.unconsumed = append(fc.unconsumed, f)
+			fc.mu.Unlock()
+		}
+		seq++
+		log.Printf("[Feedback] 任务 %s 已注入反馈 %s", fc.runID, f.ID)
+	}
+	return seq
+}
+
+// inject 将单条反馈写入控制通道
+func (fc *feedbackChannel) inject(ctx context.Context, f adapter.Feedback) error {
+	if fc.usesStdin() {
+		if fc.stdin == nil {
+			return fmt.Errorf("Agent 的 stdin 不可用")
+		}
+		_, err := fc.stdin.Write(fc.format(f))
+		return err
+	}
+	if err := fc.write(ctx, adapter.FormatFeedbackFile(f)); err != nil {
+		return err
+	}
+	fc.fileUsed = true
+	return nil
+}
+
+// observe Agent 读取 interrupt 文件时上报已写入反馈的 feedback_consumed 事件，返回下一个事件序号
+func (fc *feedbackChannel) observe(ctx context.Context, event *adapter.CanonicalEvent, seq int) int {
+	switch event.Type {
+	case adapter.EventToolUseStart, adapter.EventFileRead, adapter.EventCommand:
+	default:
+		return seq
+	}
+	fc.mu.Lock()
+	pending := fc.unconsumed
+	if len(pending) == 0 {
+		fc.mu.Unlock()
+		return seq
+	}
+	data, _ := json.Marshal(event.Payload)
+	if !strings.Contains(string(data), adapter.FeedbackFile) {
+		fc.mu.Unlock()
+		return seq
+	}
+	fc.unconsumed = nil
+	fc.mu.Unlock()
+
+	for _, f := range pending {
+		fc.nm.reportEvent(ctx, fc.runID, seq, "feedback_consumed", map[string]interface{}{
+			"feedback_id": f.ID,
+			"type":        f.Type,
+			"channel":     "file",
+		})
+		seq++
+	}
+	return seq
+}
+
+// appendFile 在执行目标的工作目录中追加 interrupt 文件
+func (fc *feedbackChannel) appendFile(ctx context.Context, data []byte) error {
+	// 内容通过参数传递：docker exec 未保持 stdin 打开时不转发 stdin
+	script := `mkdir -p "$(dirname "$1")" && printf '%s' "$2" >> "$1"`
+	cmd := fc.target.command(ctx, []string{"sh", "-c", script, "sh", adapter.FeedbackFile, string(data)}, nil)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%v: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// cleanup 关闭 stdin 并删除 interrupt 文件（Agent 结束后调用）
+func (fc *feedbackChannel) cleanup(ctx context.Context) {
+	if fc.stdin != nil {
+		fc.stdin.Close()
+	}
+	if !fc.fileUsed {
+		return
+	}
+	script := `rm -f "$1"; rmdir "$(dirname "$1")" 2>/dev/null; true`
+	cmd := fc.target.command(ctx, []string{"sh", "-c", script, "sh", adapter.FeedbackFile}, nil)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("[Feedback] 任务 %s 删除 %s 失败: %v %s", fc.runID, adapter.FeedbackFile, err, strings.TrimSpace(string(out)))
+	}
+}
@@ -0,0 +1,95 @@
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/nodemanager/adapter"
+)
+
+// stdinAdapter 通过 stdin 接收人工反馈的测试 Adapter
+type stdinAdapter struct {
+	jsonLineAdapter
+}
+
+func (stdinAdapter) FormatFeedback(f adapter.Feedback) []byte {
+	return []byte(`{"type":"user","content":"` + f.Content + `"}` + "\n")
+}
+
+// dirTarget 在临时目录中直接执行命令的执行目标
+type dirTarget struct {
+	execTarget
+	dir string
+}
+
+func (t *dirTarget) command(ctx context.Context, argv []string, _ map[string]string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = t.dir
+	return cmd
+}
+
+type nopWriteCloser struct{ *bytes.Buffer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestFeedbackChannel_Stdin(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	fc := nm.newFeedbackChannel("run-1", &dirTarget{}, stdinAdapter{})
+	var stdin bytes.Buffer
+	fc.stdin = nopWriteCloser{&stdin}
+
+	nm.QueueFeedback("run-1", adapter.Feedback{ID: "fb-1", Type: "guidance", Content: "use tabs"})
+	nm.QueueFeedback("run-1", adapter.Feedback{ID: "fb-1", Type: "guidance", Content: "use tabs"}) // 心跳重复下发
+	nm.QueueFeedback("run-other", adapter.Feedback{ID: "fb-2", Content: "ignored"})
+
+	output := `{"type":"message","payload":{"content":"working"}}` + "\n"
+	seq := nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), stdinAdapter{}, nil, fc, 1)
+
+	if got := eventTypes(events()); !slices.Equal(got, []string{"message", "feedback_consumed"}) || seq != 3 {
+		t.Fatalf("events = %v (seq %d)", got, seq)
+	}
+	if stdin.String() != `{"type":"user","content":"use tabs"}`+"\n" {
+		t.Errorf("stdin = %q", stdin.String())
+	}
+	if p := events()[1]["payload"].(map[string]interface{}); p["feedback_id"] != "fb-1" || p["channel"] != "stdin" {
+		t.Errorf("feedback_consumed payload = %v", p)
+	}
+}
+
+func TestFeedbackChannel_InterruptFile(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	nm, events := newHookTestManager(t)
+	dir := t.TempDir()
+	fc := nm.newFeedbackChannel("run-1", &dirTarget{dir: dir}, jsonLineAdapter{})
+	nm.QueueFeedback("run-1", adapter.Feedback{ID: "fb-1", Type: "correction", Content: "don't touch the vendor dir"})
+
+	output := strings.Join([]string{
+		`{"type":"message","payload":{"content":"step 1"}}`,
+		`{"type":"tool_use_start","payload":{"tool":"read_file","path":"src/main.go"}}`,
+		`{"type":"tool_use_start","payload":{"tool":"read_file","path":"/workspace/.agents-admin/FEEDBACK.md"}}`,
+	}, "\n") + "\n"
+	nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), jsonLineAdapter{}, nil, fc, 1)
+
+	want := []string{"message", "feedback_delivered", "tool_use_start", "tool_use_start", "feedback_consumed"}
+	if got := eventTypes(events()); !slices.Equal(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, adapter.FeedbackFile))
+	if err != nil || !strings.Contains(string(data), "don't touch the vendor dir") {
+		t.Fatalf("interrupt file = %q, %v", data, err)
+	}
+
+	fc.cleanup(context.Background())
+	if _, err := os.Stat(filepath.Join(dir, ".agents-admin")); !os.IsNotExist(err) {
+		t.Errorf("interrupt file directory should be removed: %v", err)
+	}
+}
@@ -0,0 +1,229 @@
+package nodemanager
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/shared/model"
+)
+
+// 与 API Server 的通信方式
+const (
+	TransportREST = "rest" // REST 轮询（默认）
+	TransportGRPC = "grpc" // gRPC：心跳与状态上报为一元调用，任务领取与事件上报为流式调用
+)
+
+// grpcRetryInterval WatchAssignedRuns 断开后的重连间隔
+const grpcRetryInterval = 3 * time.Second
+
+// grpcClient 节点 gRPC 通信客户端（Config.Transport 为 grpc 时替代 REST 的心跳、任务领取、事件与状态上报）
+//
+// 其他接口（Run 详情、工作空间、认证任务等）仍通过 REST 访问。
+type grpcClient struct {
+	conn   *grpc.ClientConn
+	client nodev1.NodeServiceClient
+	token  string
+}
+
+// newGRPCClient 连接 API Server 的节点 gRPC 接口（tlsCfg 为 nil 时使用明文连接）
+func newGRPCClient(addr string, tlsCfg *tls.Config, token string) (*grpcClient, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("grpc transport requires grpc_addr")
+	}
+	creds := insecure.NewCredentials()
+	if tlsCfg != nil {
+		creds = credentials.NewTLS(tlsCfg)
+	}
+	conn, err := grpc.NewClient(addr, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, err
+	}
+	return &grpcClient{conn: conn, client: nodev1.NewNodeServiceClient(conn), token: token}, nil
+}
+
+// withToken 在请求 metadata 中携带节点 Token（对应 REST 的 X-Node-Token）
+func (c *grpcClient) withToken(ctx context.Context) context.Context {
+	if c.token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "x-node-token", c.token)
+}
+
+func (c *grpcClient) close() error {
+	return c.conn.Close()
+}
+
+// heartbeat 发送心跳，返回并发配置覆盖与控制指令
+func (c *grpcClient) heartbeat(ctx context.Context, req *nodev1.HeartbeatRequest) (*heartbeatResponse, error) {
+	resp, err := c.client.Heartbeat(c.withToken(ctx), req)
+	if err != nil {
+		return nil, err
+	}
+	out := &heartbeatResponse{Status: resp.GetStatus(), CapacityOverride: capacityOverrideFromProto(resp.GetCapacityOverride())}
+	d := resp.GetDirectives()
+	if d == nil {
+		return out, nil
+	}
+	directives := &heartbeatDirectives{
+		CancelRuns:  d.CancelRuns,
+		TimeoutRuns: d.TimeoutRuns,
+		PauseRuns:   d.PauseRuns,
+		ResumeRuns:  d.ResumeRuns,
+	}
+	for _, a := range d.Approvals {
+		directives.Approvals = append(directives.Approvals, approvalDirective{ApprovalID: a.ApprovalId, Status: a.Status})
+	}
+	for _, f := range d.Feedbacks {
+		directives.Feedbacks = append(directives.Feedbacks, feedbackDirective{ID: f.Id, RunID: f.RunId, Type: f.Type, Content: f.Content})
+	}
+	out.Directives = directives
+	return out, nil
+}
+
+// capacityOverrideFromProto 转换心跳响应中的并发配置覆盖（未下发时为 nil）
+func capacityOverrideFromProto(o *nodev1.CapacityOverride) *model.NodeConcurrencyOverride {
+	if o == nil {
+		return nil
+	}
+	out := &model.NodeConcurrencyOverride{}
+	if o.MaxConcurrent != nil {
+		v := int(*o.MaxConcurrent)
+		out.MaxConcurrent = &v
+	}
+	if o.ReservedHighPriority != nil {
+		v := int(*o.ReservedHighPriority)
+		out.ReservedHighPriority = &v
+	}
+	if len(o.AgentTypeLimits) > 0 {
+		out.AgentTypeLimits = make(map[string]int, len(o.AgentTypeLimits))
+		for k, v := range o.AgentTypeLimits {
+			out.AgentTypeLimits[k] = int(v)
+		}
+	}
+	return out
+}
+
+// agentTypeLimitsToProto 转换心跳上报的 Agent 类型并发上限
+func agentTypeLimitsToProto(limits map[string]int) map[string]int32 {
+	if len(limits) == 0 {
+		return nil
+	}
+	out := make(map[string]int32, len(limits))
+	for k, v := range limits {
+		out[k] = int32(v)
+	}
+	return out
+}
+
+// watchAssignedRuns 订阅分配给节点的 Run，逐个交给 handle（格式与 REST 轮询的 Run 相同），断开后自动重连
+func (c *grpcClient) watchAssignedRuns(ctx context.Context, nodeID string, handle func(run map[string]interface{})) {
+	for {
+		err := c.watchOnce(ctx, nodeID, handle)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("[nodemanager.grpc.watch.disconnected] error=%v retry_in=%s", err, grpcRetryInterval)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(grpcRetryInterval):
+		}
+	}
+}
+
+func (c *grpcClient) watchOnce(ctx context.Context, nodeID string, handle func(run map[string]interface{})) error {
+	stream, err := c.client.WatchAssignedRuns(c.withToken(ctx), &nodev1.WatchAssignedRunsRequest{NodeId: nodeID})
+	if err != nil {
+		return err
+	}
+	for {
+		msg, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		var run map[string]interface{}
+		if err := json.Unmarshal(msg.Run, &run); err != nil {
+			log.Printf("[nodemanager.grpc.watch.invalid] run_id=%s error=%v", msg.RunId, err)
+			continue
+		}
+		handle(run)
+	}
+}
+
+// reportEvents 以客户端流上报一批事件，错误语义与 REST 上报相同（见 postEvents）
+func (c *grpcClient) reportEvents(ctx context.Context, runID string, events []json.RawMessage) error {
+	req := &nodev1.ReportEventsRequest{RunId: runID, Events: make([]*nodev1.Event, 0, len(events))}
+	for _, data := range events {
+		e, err := eventToProto(data)
+		if err != nil {
+			return fmt.Errorf("%w: %v", errEventsRejected, err)
+		}
+		req.Events = append(req.Events, e)
+	}
+
+	stream, err := c.client.ReportEvents(c.withToken(ctx))
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(req); err != nil && err != io.EOF {
+		return err
+	}
+	// Send 返回 io.EOF 时服务端已结束流，真实错误由 CloseAndRecv 返回
+	_, err = stream.CloseAndRecv()
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.ResourceExhausted:
+		return errEventBatchTooLarge
+	case codes.InvalidArgument, codes.NotFound, codes.PermissionDenied:
+		return fmt.Errorf("%w: %v", errEventsRejected, err)
+	default:
+		return err
+	}
+}
+
+// updateRunStatus 上报 Run 状态，请求无效、Run 不存在或已分配给其他节点时返回 errEventsRejected
+func (c *grpcClient) updateRunStatus(ctx context.Context, runID, runStatus, nodeID string) error {
+	_, err := c.client.UpdateRunStatus(c.withToken(ctx), &nodev1.UpdateRunStatusRequest{RunId: runID, Status: runStatus, NodeId: nodeID})
+	switch status.Code(err) {
+	case codes.OK:
+		return nil
+	case codes.InvalidArgument, codes.NotFound, codes.FailedPrecondition, codes.PermissionDenied:
+		return fmt.Errorf("%w: %v", errEventsRejected, err)
+	default:
+		return err
+	}
+}
+
+// eventToProto 将批量上报队列中的事件 JSON（见 reportEventWithRaw）转换为 proto 消息
+func eventToProto(data json.RawMessage) (*nodev1.Event, error) {
+	var e struct {
+		Seq       int             `json:"seq"`
+		Type      string          `json:"type"`
+		Timestamp time.Time       `json:"timestamp"`
+		Payload   json.RawMessage `json:"payload"`
+		Raw       *string         `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	out := &nodev1.Event{Seq: int32(e.Seq), Type: e.Type, Timestamp: timestamppb.New(e.Timestamp), Raw: e.Raw}
+	if len(e.Payload) > 0 && string(e.Payload) != "null" {
+		out.Payload = e.Payload
+	}
+	return out, nil
+}
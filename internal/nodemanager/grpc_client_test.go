@@ -0,0 +1,128 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+)
+
+// fakeNodeService 记录上报的事件与节点 Token，reportErr 非 nil 时拒绝事件
+type fakeNodeService struct {
+	nodev1.UnimplementedNodeServiceServer
+	reportErr error
+	events    []*nodev1.Event
+	token     string
+}
+
+func (s *fakeNodeService) ReportEvents(stream grpc.ClientStreamingServer[nodev1.ReportEventsRequest, nodev1.ReportEventsResponse]) error {
+	if md, ok := metadata.FromIncomingContext(stream.Context()); ok && len(md.Get("x-node-token")) > 0 {
+		s.token = md.Get("x-node-token")[0]
+	}
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			if s.reportErr != nil {
+				return s.reportErr
+			}
+			return stream.SendAndClose(&nodev1.ReportEventsResponse{Created: int32(len(s.events))})
+		}
+		if err != nil {
+			return err
+		}
+		s.events = append(s.events, req.Events...)
+	}
+}
+
+func (s *fakeNodeService) WatchAssignedRuns(req *nodev1.WatchAssignedRunsRequest, stream grpc.ServerStreamingServer[nodev1.AssignedRun]) error {
+	return stream.Send(&nodev1.AssignedRun{RunId: "run-1", Status: "assigned", EventSeq: 4, Run: []byte(`{"id":"run-1","status":"assigned","event_seq":4}`)})
+}
+
+func newTestGRPCClient(t *testing.T, svc nodev1.NodeServiceServer) *grpcClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	nodev1.RegisterNodeServiceServer(srv, svc)
+	go srv.Serve(lis)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &grpcClient{conn: conn, client: nodev1.NewNodeServiceClient(conn), token: "node-secret"}
+	t.Cleanup(func() { c.close() })
+	return c
+}
+
+func TestGRPCClient_ReportEvents(t *testing.T) {
+	svc := &fakeNodeService{}
+	c := newTestGRPCClient(t, svc)
+
+	raw := "line"
+	e1, _ := json.Marshal(map[string]interface{}{"seq": 1, "type": "message", "timestamp": time.Now().Format(time.RFC3339Nano), "payload": map[string]interface{}{"content": "hi"}, "raw": raw})
+	e2, _ := json.Marshal(map[string]interface{}{"seq": 2, "type": "run_completed", "timestamp": time.Now().Format(time.RFC3339Nano), "payload": nil})
+	if err := c.reportEvents(context.Background(), "run-1", []json.RawMessage{e1, e2}); err != nil {
+		t.Fatal(err)
+	}
+	if svc.token != "node-secret" {
+		t.Errorf("token = %q, 应在 metadata 中携带节点 Token", svc.token)
+	}
+	if len(svc.events) != 2 || svc.events[0].Seq != 1 || string(svc.events[0].Payload) != `{"content":"hi"}` || svc.events[0].GetRaw() != raw {
+		t.Fatalf("events = %+v", svc.events)
+	}
+	if svc.events[1].Payload != nil || svc.events[1].Raw != nil || svc.events[1].Timestamp == nil {
+		t.Errorf("events[1] = %+v", svc.events[1])
+	}
+
+	// 错误语义与 REST 上报一致：超限拆分重试，请求无效不再重试
+	svc.reportErr = status.Error(codes.ResourceExhausted, "too many events")
+	if err := c.reportEvents(context.Background(), "run-1", []json.RawMessage{e1}); !errors.Is(err, errEventBatchTooLarge) {
+		t.Errorf("err = %v, 期望 errEventBatchTooLarge", err)
+	}
+	svc.reportErr = status.Error(codes.InvalidArgument, "invalid event")
+	if err := c.reportEvents(context.Background(), "run-1", []json.RawMessage{e1}); !errors.Is(err, errEventsRejected) {
+		t.Errorf("err = %v, 期望 errEventsRejected", err)
+	}
+	svc.reportErr = status.Error(codes.Internal, "db down")
+	if err := c.reportEvents(context.Background(), "run-1", []json.RawMessage{e1}); err == nil || errors.Is(err, errEventsRejected) {
+		t.Errorf("err = %v, 期望可重试的错误", err)
+	}
+}
+
+func TestGRPCClient_WatchAssignedRuns(t *testing.T) {
+	c := newTestGRPCClient(t, &fakeNodeService{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan map[string]interface{}, 1)
+	go c.watchAssignedRuns(ctx, "node-1", func(run map[string]interface{}) {
+		select {
+		case got <- run:
+		default:
+		}
+	})
+
+	select {
+	case run := <-got:
+		if run["id"] != "run-1" || run["event_seq"] != float64(4) {
+			t.Errorf("run = %+v", run)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("未收到推送的 Run")
+	}
+}
@@ -0,0 +1,749 @@
+// Package nodemanager 运行中 Run 的重启交接
+//
+// 默认情况下 Agent 命令是 NodeManager 的子进程（docker 后端为 docker exec 客户端），
+// NodeManager 重启时 Agent 随之终止，Run 被 API Server 的孤儿回收判定失败或重新排队。
+// 启用重启交接（Config.Handover）后：
+//  1. 启动：Agent 通过启动脚本在执行目标内后台运行（launchScript），stdout/stderr 写入输出目录，
+//     退出码写入 exit 文件，启动脚本随即退出，Agent 不再依赖 NodeManager 进程
+//  2. 读取：跟随脚本（followScript）从指定偏移量持续输出 stdout，Agent 退出后结束；
+//     每处理完一行记录检查点（stdout 偏移量与下一个事件序号），写入状态目录下的 <run_id>.json
+//  3. 停止：NodeManager 停止时不终止 Agent、不释放暂停、不清理工作空间与预热容器，只写入最终检查点
+//  4. 接管：下一次启动在首次心跳前读取状态文件并登记为运行中（心跳继续上报 running_runs），
+//     Run 仍由本节点执行时从检查点继续读取输出；执行容器已停止时从容器中复制输出目录读取，
+//     按记录的退出码完成 Run。检查点之后已上报的事件会以相同序号重新上报，由 API Server 去重
+//
+// 限制：
+//   - 通过 stdin 接收人工反馈的 Agent（adapter.FeedbackReceiver）仍以子进程方式执行，不交接
+//   - stderr 只在 Agent 退出后读取末尾记录日志，不推送实时输出
+//   - process 后端的 Agent 与 NodeManager 同属一个 systemd 服务，需要 KillMode=process，
+//     否则停止服务时 Agent 被一并终止
+//   - 执行容器在交接期间被重启或删除时 Agent 已丢失，Run 以失败结束
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// handoverWaitTimeout NodeManager 停止时等待运行中的 Run 写入交接检查点的最长时间
+	handoverWaitTimeout = 10 * time.Second
+	// handoverCheckpointInterval 两次写入检查点的最小间隔（交接时另外写入最终检查点）
+	handoverCheckpointInterval = time.Second
+	// handoverRetryInterval 接管时获取 Run 失败的重试间隔
+	handoverRetryInterval = 5 * time.Second
+	// handoverCommandTimeout 读取退出码、终止 Agent 与清理输出目录的超时
+	handoverCommandTimeout = 30 * time.Second
+	// detachedOutputPrefix docker 后端在容器内的输出目录前缀（process 后端位于状态目录下）
+	detachedOutputPrefix = "/tmp/agents-run-"
+)
+
+// launchScript 在执行目标内后台启动 Agent（$1 为输出目录，其后为 Agent 命令），输出 Agent 进程号后退出
+//
+// 外层子 shell 等待 Agent 并写入退出码；所有输出重定向到文件，启动命令结束时不遗留管道。
+const launchScript = `d="$1"; shift
+mkdir -p "$d" || exit 1
+rm -f "$d/pid" "$d/exit"
+: >"$d/stdout" && : >"$d/stderr" || exit 1
+(
+	"$@" </dev/null >>"$d/stdout" 2>>"$d/stderr" &
+	echo $! >"$d/pid.tmp" && mv "$d/pid.tmp" "$d/pid"
+	wait $!
+	echo $? >"$d/exit.tmp" && mv "$d/exit.tmp" "$d/exit"
+) </dev/null >/dev/null 2>&1 &
+i=0
+while [ ! -s "$d/pid" ]; do
+	i=$((i + 1))
+	[ "$i" -gt 200 ] && exit 1
+	sleep 0.05
+done
+cat "$d/pid"`
+
+// followScript 从偏移量 $2 起持续输出 $1/stdout，Agent 退出（exit 文件出现）并输出完毕后以 0 退出；
+// Agent 已不存在且没有退出码时以 3 退出
+const followScript = `d="$1"; off="$2"
+pid=$(cat "$d/pid") || exit 3
+while :; do
+	fin=0
+	[ -e "$d/exit" ] && fin=1
+	size=$(wc -c <"$d/stdout") || exit 3
+	if [ "$size" -gt "$off" ]; then
+		tail -c "+$((off + 1))" "$d/stdout" | head -c "$((size - off))" || exit 1
+		off=$size
+	fi
+	[ "$fin" = 1 ] && exit 0
+	if ! kill -0 "$pid" 2>/dev/null; then
+		sleep 1
+		[ -e "$d/exit" ] && continue
+		exit 3
+	fi
+	sleep 0.2
+done`
+
+// killScript 终止输出目录 $1 记录的 Agent 进程（docker 后端）
+const killScript = `p=$(cat "$1/pid" 2>/dev/null) && kill -KILL "$p" 2>/dev/null; true`
+
+// errAgentLost Agent 已不存在且没有记录退出码
+var errAgentLost = errors.New("agent process lost")
+
+// agentExitError 脱离 NodeManager 运行的 Agent 的非零退出码
+type agentExitError struct{ code int }
+
+func (e *agentExitError) Error() string { return fmt.Sprintf("exit status %d", e.code) }
+
+// ExitCode 记录的退出码（与 exec.ExitError 一致，资源限制据此判断是否被 SIGKILL 终止）
+func (e *agentExitError) ExitCode() int { return e.code }
+
+// HandoverConfig 重启交接配置
+type HandoverConfig struct {
+	Enabled  bool   // 是否启用（默认关闭：Agent 作为 NodeManager 子进程运行）
+	StateDir string // 状态目录（默认 <workspace_dir>/.run-state）
+}
+
+// runState 交接状态文件内容（每个运行中的 Run 一个文件）
+type runState struct {
+	RunID         string             `json:"run_id"`
+	Backend       string             `json:"backend"`
+	Container     string             `json:"container,omitempty"` // docker 后端：执行容器
+	Pooled        bool               `json:"pooled,omitempty"`    // 执行容器租自预热实例池
+	Dir           string             `json:"dir,omitempty"`       // 工作目录（docker 后端为容器内路径）
+	Owned         bool               `json:"owned,omitempty"`     // process 后端：工作目录由 NodeManager 克隆
+	TempDir       bool               `json:"temp_dir,omitempty"`  // process 后端：工作目录为临时目录，Run 结束后删除
+	OutputDir     string             `json:"output_dir"`          // Agent 输出目录（执行目标内路径）
+	PID           int                `json:"pid"`                 // Agent 进程号（执行目标内）
+	PGID          int                `json:"pgid,omitempty"`      // process 后端：Agent 所在进程组
+	AccountLeased bool               `json:"account_leased,omitempty"`
+	Workspace     *runStateWorkspace `json:"workspace,omitempty"`
+	Holds         []string           `json:"holds,omitempty"` // 交接时持有暂停的原因（执行目标保持冻结）
+	Offset        int64              `json:"offset"`          // 已处理的 stdout 字节数
+	Seq           int                `json:"seq"`             // 下一个事件序号
+	StartedAt     time.Time          `json:"started_at"`
+	UpdatedAt     time.Time          `json:"updated_at"`
+}
+
+// runStateWorkspace 交接后重建 PreparedWorkspace 所需的信息
+type runStateWorkspace struct {
+	Path       string `json:"path"`
+	BaseCommit string `json:"base_commit,omitempty"`
+	WorkingDir string `json:"working_dir,omitempty"`
+	Cleanup    bool   `json:"cleanup,omitempty"` // Run 结束后删除 Path（Git 克隆目录）
+}
+
+// prepared 重建 PreparedWorkspace
+func (w *runStateWorkspace) prepared() *PreparedWorkspace {
+	if w == nil {
+		return nil
+	}
+	ws := &PreparedWorkspace{Path: w.Path, BaseCommit: w.BaseCommit, WorkingDir: w.WorkingDir}
+	if w.Cleanup {
+		path := w.Path
+		ws.Cleanup = func() {
+			log.Printf("[Workspace] 清理工作目录: %s", path)
+			os.RemoveAll(path)
+		}
+	}
+	return ws
+}
+
+// handover 重启交接状态（状态目录与停止时的等待）
+type handover struct {
+	dir string
+	ctx context.Context // NodeManager.Start 的 ctx：结束即 NodeManager 停止，Run 交接给下一次启动
+	wg  sync.WaitGroup  // 运行中的 Run
+}
+
+// newHandover 创建重启交接状态目录
+func newHandover(cfg HandoverConfig, workspaceDir string) (*handover, error) {
+	dir := cfg.StateDir
+	if dir == "" {
+		if workspaceDir == "" {
+			return nil, errors.New("state_dir or workspace_dir is required")
+		}
+		dir = filepath.Join(workspaceDir, ".run-state")
+	}
+	// process 后端的沙箱用户需要进入状态目录访问自己的输出目录
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &handover{dir: dir}, nil
+}
+
+// stopping NodeManager 是否正在停止（未启用交接时为 false）
+func (h *handover) stopping() bool {
+	return h != nil && h.ctx != nil && h.ctx.Err() != nil
+}
+
+// track 登记启动的 Run，返回结束时调用的函数（未启用交接时为空操作）
+func (h *handover) track() func() {
+	if h == nil {
+		return func() {}
+	}
+	h.wg.Add(1)
+	return h.wg.Done
+}
+
+// wait 等待运行中的 Run 写入交接检查点，超时后返回
+func (h *handover) wait(timeout time.Duration) {
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(timeout):
+		log.Printf("[handover.wait.timeout] timeout=%s", timeout)
+	}
+}
+
+func (h *handover) statePath(runID string) string {
+	return filepath.Join(h.dir, runID+".json")
+}
+
+// outputDir process 后端的 Agent 输出目录
+func (h *handover) outputDir(runID string) string {
+	return filepath.Join(h.dir, runID)
+}
+
+// save 写入状态文件（先写临时文件再重命名，NodeManager 被强制终止时不会留下不完整的文件）
+func (h *handover) save(st *runState) error {
+	st.UpdatedAt = time.Now()
+	data, err := json.Marshal(st)
+	if err != nil {
+		return err
+	}
+	path := h.statePath(st.RunID)
+	if err := os.WriteFile(path+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
+
+// load 读取上次运行留下的状态文件，无法解析的文件删除
+func (h *handover) load() []*runState {
+	entries, err := os.ReadDir(h.dir)
+	if err != nil {
+		log.Printf("[handover.state.list.failed] dir=%s error=%v", h.dir, err)
+		return nil
+	}
+	var states []*runState
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		path := filepath.Join(h.dir, e.Name())
+		var st runState
+		data, err := os.ReadFile(path)
+		if err == nil {
+			err = json.Unmarshal(data, &st)
+		}
+		if err == nil && (st.RunID == "" || st.OutputDir == "" || st.PID <= 0) {
+			err = errors.New("incomplete state")
+		}
+		if err != nil {
+			log.Printf("[handover.state.invalid] file=%s error=%v", path, err)
+			os.Remove(path)
+			continue
+		}
+		states = append(states, &st)
+	}
+	return states
+}
+
+// discard Run 结束后删除输出目录与状态文件
+func (h *handover) discard(st *runState, target execTarget) {
+	if st.Backend == model.ExecBackendProcess {
+		os.RemoveAll(st.OutputDir)
+	} else if target != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), handoverCommandTimeout)
+		defer cancel()
+		// 执行容器可能已停止，清理失败时由容器内 /tmp 的生命周期兜底
+		target.command(ctx, []string{"rm", "-rf", st.OutputDir}, nil).Run()
+	}
+	if err := os.Remove(h.statePath(st.RunID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[handover.state.remove.failed] run_id=%s error=%v", st.RunID, err)
+	}
+}
+
+// ============================================================================
+// Agent 输出目录
+// ============================================================================
+
+// runOutput 读取 Agent 输出目录：通过执行目标内的命令，或从复制到宿主机的目录直接读取（执行容器已停止）
+type runOutput struct {
+	target execTarget
+	dir    string // 执行目标内的输出目录
+	local  string // 非空时从该宿主机目录读取
+}
+
+// follow 从 offset 起读取 stdout，返回的 wait 在读取结束后调用
+func (o *runOutput) follow(ctx context.Context, offset int64) (io.Reader, func() error, error) {
+	if o.local != "" {
+		f, err := os.Open(filepath.Join(o.local, "stdout"))
+		if err != nil {
+			return nil, nil, err
+		}
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+		return f, f.Close, nil
+	}
+	cmd := o.target.command(ctx, []string{"sh", "-c", followScript, "sh", o.dir, strconv.FormatInt(offset, 10)}, nil)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, err
+	}
+	return stdout, cmd.Wait, nil
+}
+
+// tail 读取输出文件末尾（最多 max 字节）
+func (o *runOutput) tail(ctx context.Context, name string, max int) ([]byte, error) {
+	if o.local != "" {
+		data, err := os.ReadFile(filepath.Join(o.local, name))
+		if len(data) > max {
+			data = data[len(data)-max:]
+		}
+		return data, err
+	}
+	return o.target.command(ctx, []string{"tail", "-c", strconv.Itoa(max), o.dir + "/" + name}, nil).Output()
+}
+
+// exitCode 读取 Agent 的退出码，未记录时返回 false
+func (o *runOutput) exitCode(ctx context.Context) (int, bool) {
+	data, err := o.tail(ctx, "exit", 64)
+	if err != nil {
+		return 0, false
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return code, err == nil
+}
+
+// ============================================================================
+// 启动与监督
+// ============================================================================
+
+// launchDetached 在执行目标内后台启动 Agent 并写入状态文件
+func (nm *NodeManager) launchDetached(ctx context.Context, x *runExecution, argv []string, env map[string]string, seq int) (*runState, error) {
+	st := &runState{
+		RunID:         x.runID,
+		Backend:       x.target.backend(),
+		AccountLeased: x.accountLeased,
+		Seq:           seq,
+		StartedAt:     time.Now(),
+	}
+	if ws := x.workspace; ws != nil {
+		st.Workspace = &runStateWorkspace{Path: ws.Path, BaseCommit: ws.BaseCommit, WorkingDir: ws.WorkingDir, Cleanup: ws.Cleanup != nil}
+	}
+	switch t := x.target.(type) {
+	case *dockerTarget:
+		st.Container, st.Pooled, st.Dir = t.container, t.pooled, t.workingDir
+		st.OutputDir = detachedOutputPrefix + x.runID
+	case *processTarget:
+		st.Dir, st.Owned, st.TempDir = t.dir, t.owned, x.workspace == nil
+		st.OutputDir = nm.handover.outputDir(x.runID)
+		if err := os.MkdirAll(st.OutputDir, 0o700); err != nil {
+			return nil, fmt.Errorf("创建输出目录失败: %w", err)
+		}
+		if err := t.proc.chown(st.OutputDir, true); err != nil {
+			return nil, err
+		}
+	}
+
+	// 打印 Agent 命令以便调试（密钥值只在进程环境中，不会出现在参数里）
+	log.Printf("执行命令（后台）: %v", argv)
+	cmd := x.target.command(ctx, append([]string{"sh", "-c", launchScript, "sh", st.OutputDir}, argv...), env)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, err
+	}
+	st.PID, err = strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 Agent 进程号: %q", out)
+	}
+	if st.Backend == model.ExecBackendProcess {
+		// 启动命令是进程组组长（configureSandbox），Agent 继承其进程组
+		st.PGID = cmd.Process.Pid
+	}
+	if err := nm.handover.save(st); err != nil {
+		log.Printf("[handover.state.save.failed] run_id=%s error=%v", x.runID, err)
+	}
+	log.Printf("[handover.run.launched] run_id=%s backend=%s pid=%d", x.runID, st.Backend, st.PID)
+	return st, nil
+}
+
+// superviseDetached 读取脱离运行的 Agent 的输出直到其退出，期间记录检查点；
+// NodeManager 停止时写入最终检查点并返回 handedOff
+func (nm *NodeManager) superviseDetached(ctx context.Context, x *runExecution, st *runState, out *runOutput, a adapter.Adapter, feedback *feedbackChannel) agentResult {
+	runID, h := x.runID, nm.handover
+
+	// process 后端按记录的进程组暂停（启动命令已退出，只用到进程号）
+	group := &exec.Cmd{}
+	if st.PGID > 0 {
+		group.Process = &os.Process{Pid: st.PGID}
+	}
+	pauser := newRunPauser(x.target, group)
+	for _, reason := range st.Holds {
+		pauser.holds[reason] = true
+	}
+	x.gate = newApprovalGate(nm, runID, pauser, ParseApprovalTools(x.snapshot), func() { nm.killDetached(x.target, st) })
+	x.gate.policy = newPolicyGuard(ParseSecurityPolicy(x.snapshot), a)
+	x.gate.commands = newCommandFilter(ParseCommandFilter(x.snapshot), a)
+	x.gate.detached = h.stopping
+
+	// 登记暂停控制；Run 被取消或超时终止时先恢复执行目标再停止 Agent，交接时保持冻结
+	nm.registerPauser(runID, pauser)
+	defer nm.unregisterPauser(runID)
+
+	r, wait, err := out.follow(ctx, st.Offset)
+	if err != nil {
+		return agentResult{err: err, reason: fmt.Sprintf("读取 Agent 输出失败: %v", err), seq: st.Seq}
+	}
+	base := st.Offset
+	var saved time.Time
+	seq := nm.streamOutputFrom(ctx, runID, r, a, x.gate, feedback, st.Seq, func(consumed int64, seq int) {
+		// 停止后处理的行不计入检查点，下一次启动重新处理
+		if ctx.Err() != nil {
+			return
+		}
+		st.Offset, st.Seq = base+consumed, seq
+		if time.Since(saved) >= handoverCheckpointInterval {
+			saved = time.Now()
+			if err := h.save(st); err != nil {
+				log.Printf("[handover.state.save.failed] run_id=%s error=%v", runID, err)
+			}
+		}
+	})
+	followErr := wait()
+
+	if ctx.Err() != nil {
+		if h.stopping() {
+			st.Holds = pauser.reasons()
+			if err := h.save(st); err != nil {
+				log.Printf("[handover.state.save.failed] run_id=%s error=%v", runID, err)
+			}
+			log.Printf("[handover.run.handed_off] run_id=%s offset=%d seq=%d", runID, st.Offset, st.Seq)
+			return agentResult{handedOff: true}
+		}
+		// Run 被取消或超时：Agent 不随读取命令退出，需要单独停止（冻结的执行目标收不到停止信号，先恢复）
+		releaseCtx, cancel := context.WithTimeout(context.Background(), limitRestoreTimeout)
+		if err := pauser.releaseAll(releaseCtx); err != nil {
+			log.Printf("[Pause] 任务 %s 终止前恢复执行失败: %v", runID, err)
+		}
+		cancel()
+		return agentResult{err: ctx.Err(), seq: seq, stop: nm.stopDetached(st, out, a)}
+	}
+	feedback.cleanup(context.WithoutCancel(ctx))
+
+	readCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), handoverCommandTimeout)
+	defer cancel()
+	res := agentResult{seq: seq}
+	if code, ok := out.exitCode(readCtx); !ok {
+		res.err = errAgentLost
+		res.reason = "Agent 进程已不存在且未记录退出码（执行环境可能已重启）"
+		if followErr != nil {
+			res.reason += fmt.Sprintf(": %v", followErr)
+		}
+	} else if code != 0 {
+		res.err = &agentExitError{code: code}
+	}
+	if stderr, _ := out.tail(readCtx, "stderr", quotaOutputTail); len(stderr) > 0 {
+		res.stderr = string(stderr)
+		nm.logAgentStderr(runID, x.target.backend(), res.stderr, res.err != nil && ctx.Err() == nil)
+	}
+	if x.accountLeased {
+		stdout, _ := out.tail(readCtx, "stdout", quotaOutputTail)
+		res.stdoutTail = string(stdout)
+	}
+	return res
+}
+
+// killDetached 终止脱离运行的 Agent（process 后端终止进程组，docker 后端按记录的进程号终止）
+func (nm *NodeManager) killDetached(target execTarget, st *runState) {
+	if st.PGID > 0 {
+		if err := killGroup(st.PGID); err != nil {
+			log.Printf("[handover.kill.failed] run_id=%s pgid=%d error=%v", st.RunID, st.PGID, err)
+		}
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), handoverCommandTimeout)
+	defer cancel()
+	if out, err := target.command(ctx, []string{"sh", "-c", killScript, "sh", st.OutputDir}, nil).CombinedOutput(); err != nil {
+		log.Printf("[handover.kill.failed] run_id=%s error=%v output=%s", st.RunID, err, strings.TrimSpace(string(out)))
+	}
+}
+
+// reasons 持有暂停的原因（交接时记录，下一次启动恢复）
+func (p *runPauser) reasons() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	reasons := make([]string, 0, len(p.holds))
+	for r := range p.holds {
+		reasons = append(reasons, r)
+	}
+	sort.Strings(reasons)
+	return reasons
+}
+
+// ============================================================================
+// 接管
+// ============================================================================
+
+// recoverRuns 接管上次运行留下的 Run：登记为运行中并预留预热容器（首次心跳与实例池接管之前调用）
+func (nm *NodeManager) recoverRuns(ctx context.Context) {
+	h := nm.handover
+	h.ctx = ctx
+	for _, st := range h.load() {
+		if st.Pooled && nm.pool != nil {
+			nm.pool.reserve(st.Container, st.RunID)
+		}
+		runCtx, cancel := context.WithCancel(ctx)
+		nm.mu.Lock()
+		nm.running[st.RunID] = cancel
+		nm.mu.Unlock()
+		log.Printf("[handover.resume.start] run_id=%s backend=%s pid=%d offset=%d seq=%d", st.RunID, st.Backend, st.PID, st.Offset, st.Seq)
+
+		done := h.track()
+		go func(st *runState) {
+			defer done()
+			nm.resumeRun(runCtx, st)
+		}(st)
+	}
+}
+
+// restoreTarget 按状态文件重建执行目标
+func (nm *NodeManager) restoreTarget(st *runState) (execTarget, error) {
+	switch st.Backend {
+	case model.ExecBackendProcess:
+		if nm.process == nil {
+			return nil, errors.New("节点未启用 process 执行后端")
+		}
+		t := &processTarget{proc: nm.process, dir: st.Dir, owned: st.Owned, cleanup: func() {}}
+		if st.TempDir {
+			dir := st.Dir
+			t.cleanup = func() { os.RemoveAll(dir) }
+		}
+		return t, nil
+	case model.ExecBackendDocker:
+		t := &dockerTarget{rt: nm.config.containers(), container: st.Container, workingDir: st.Dir, capacity: nm.capacity}
+		if st.Pooled && nm.pool != nil {
+			t.pooled, t.pool = true, nm.pool
+		}
+		return t, nil
+	default:
+		return nil, fmt.Errorf("未知的执行后端: %s", st.Backend)
+	}
+}
+
+// fetchResumedRun 获取接管的 Run，API Server 不可达时重试直到 ctx 结束
+func (nm *NodeManager) fetchResumedRun(ctx context.Context, runID string) (map[string]interface{}, error) {
+	for {
+		run, err := nm.fetchRunByID(ctx, runID)
+		if err == nil {
+			return run, nil
+		}
+		log.Printf("[handover.resume.fetch.failed] run_id=%s error=%v", runID, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(handoverRetryInterval):
+		}
+	}
+}
+
+// resumableReason Run 不再由本节点执行时返回原因（已结束、被重新调度或分配给其他节点）
+func (nm *NodeManager) resumableReason(run map[string]interface{}) string {
+	if run == nil {
+		return "not_found"
+	}
+	switch status, _ := run["status"].(string); status {
+	case "assigned", "running", "paused":
+	default:
+		return "status_" + status
+	}
+	if nodeID, _ := run["node_id"].(string); nodeID != "" && nodeID != nm.config.NodeID {
+		return "reassigned"
+	}
+	return ""
+}
+
+// resumeRun 接管上次运行留下的 Run：从检查点继续读取 Agent 输出并完成 Run
+func (nm *NodeManager) resumeRun(ctx context.Context, st *runState) {
+	runID := st.RunID
+	nm.logs.begin(runID)
+	defer nm.forgetRun(runID)
+
+	target, err := nm.restoreTarget(st)
+	if err != nil {
+		log.Printf("[handover.resume.failed] run_id=%s error=%v", runID, err)
+		nm.completeRun(ctx, runID, "failed", fmt.Sprintf("接管运行中的任务失败: %v", err), st.Seq)
+		nm.handover.discard(st, nil)
+		return
+	}
+
+	// 工作空间、执行目标与资源限制：交接给下一次启动时保留，否则随 Run 结束清理
+	handedOff := false
+	workspace := st.Workspace.prepared()
+	defer func() {
+		if handedOff {
+			return
+		}
+		if workspace != nil && workspace.Cleanup != nil {
+			workspace.Cleanup()
+		}
+		switch t := target.(type) {
+		case *dockerTarget:
+			t.release()
+		case *processTarget:
+			t.cleanup()
+		}
+	}()
+
+	run, err := nm.fetchResumedRun(ctx, runID)
+	if err != nil {
+		// 接管完成前 NodeManager 再次停止时保留状态文件，Run 被取消时终止 Agent
+		if handedOff = nm.handover.stopping(); !handedOff {
+			nm.killDetached(target, st)
+			nm.handover.discard(st, target)
+		}
+		return
+	}
+	if reason := nm.resumableReason(run); reason != "" {
+		log.Printf("[handover.resume.abandoned] run_id=%s reason=%s", runID, reason)
+		nm.killDetached(target, st)
+		nm.handover.discard(st, target)
+		return
+	}
+	nm.mu.Lock()
+	if nm.slots == nil {
+		nm.slots = make(map[string]runSlot)
+	}
+	nm.slots[runID] = runSlotOf(run)
+	nm.mu.Unlock()
+
+	abort := func(reason string) {
+		log.Printf("[handover.resume.failed] run_id=%s error=%s", runID, reason)
+		nm.killDetached(target, st)
+		nm.completeRun(ctx, runID, "failed", reason, st.Seq)
+		nm.handover.discard(st, target)
+	}
+	snapshot, err := parseRunSnapshot(run["snapshot"])
+	if err != nil {
+		abort(err.Error())
+		return
+	}
+	a, err := nm.resolveAdapter(ctx, snapshot.Agent.Type)
+	if err != nil {
+		abort(err.Error())
+		return
+	}
+	if names := ParseSecretNames(snapshot); len(names) > 0 {
+		secrets, err := nm.resolveSecrets(ctx, names)
+		if err != nil {
+			abort(err.Error())
+			return
+		}
+		nm.setSecretMasker(runID, newSecretMasker(secrets))
+		if dt, ok := target.(*dockerTarget); ok {
+			dt.secrets = secrets
+		}
+	}
+	limits, err := ParseRunLimits(snapshot)
+	if err != nil {
+		abort(err.Error())
+		return
+	}
+	if limits != nil {
+		if _, restore, err := target.applyLimits(ctx, limits); err != nil {
+			log.Printf("[handover.resume.limits.failed] run_id=%s error=%v", runID, err)
+		} else {
+			defer func() {
+				if !handedOff {
+					restore()
+				}
+			}()
+		}
+	}
+
+	out := &runOutput{target: target, dir: st.OutputDir}
+	if dt, ok := target.(*dockerTarget); ok {
+		info, err := dt.rt.Inspect(ctx, dt.container)
+		if err != nil {
+			abort(fmt.Sprintf("执行容器 %s 已不存在: %v", dt.container, err))
+			return
+		}
+		if !info.Running {
+			// 容器已停止：Agent 不再运行，复制输出目录后按记录的退出码完成
+			local, err := os.MkdirTemp("", "agents-run-")
+			if err != nil {
+				abort(err.Error())
+				return
+			}
+			defer os.RemoveAll(local)
+			if err := dt.rt.CopyFrom(ctx, dt.container, st.OutputDir+"/.", local); err != nil {
+				abort(fmt.Sprintf("执行容器 %s 已停止，读取 Agent 输出失败: %v", dt.container, err))
+				return
+			}
+			out.local = local
+			log.Printf("[handover.resume.container_stopped] run_id=%s container=%s", runID, dt.container)
+		}
+	}
+	if len(st.Holds) == 0 && out.local == "" {
+		// 交接时没有持有暂停，NodeManager 异常退出时执行目标仍可能被冻结
+		target.resume(ctx, &exec.Cmd{Process: &os.Process{Pid: st.PGID}})
+	}
+
+	nm.mu.Lock()
+	if nm.targets == nil {
+		nm.targets = make(map[string]execTarget)
+	}
+	nm.targets[runID] = target
+	nm.mu.Unlock()
+
+	wsConfig := ParseWorkspaceConfig(snapshot)
+	if wsConfig == nil {
+		workspace = nil
+	}
+	hooks := ParseLifecycleHooks(snapshot)
+	x := &runExecution{
+		runID:         runID,
+		snapshot:      snapshot,
+		accountLeased: st.AccountLeased,
+		limits:        limits,
+		target:        target,
+		workspace:     workspace,
+		wsConfig:      wsConfig,
+		hooks:         hooks,
+		hookRun:       targetHookRunner(target),
+		hookEnv:       map[string]string{"AGENTS_RUN_ID": runID},
+	}
+	feedback := nm.newFeedbackChannel(runID, target, a)
+	defer nm.unregisterFeedbackChannel(runID)
+
+	res := nm.superviseDetached(ctx, x, st, out, a, feedback)
+	if res.handedOff {
+		handedOff = true
+		return
+	}
+	log.Printf("[handover.resume.completed] run_id=%s", runID)
+	nm.concludeRun(ctx, x, res)
+	nm.handover.discard(st, target)
+}
@@ -0,0 +1,188 @@
+package nodemanager
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestHandoverState(t *testing.T) {
+	dir := t.TempDir()
+	h, err := newHandover(HandoverConfig{}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h.dir != filepath.Join(dir, ".run-state") {
+		t.Errorf("dir = %s", h.dir)
+	}
+
+	st := &runState{RunID: "run-1", Backend: "docker", Container: "agent_pool_a", Pooled: true, OutputDir: "/tmp/agents-run-run-1", PID: 42, Offset: 128, Seq: 7, Holds: []string{pauseByUser},
+		Workspace: &runStateWorkspace{Path: filepath.Join(dir, "ws"), Cleanup: true}}
+	if err := h.save(st); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(h.dir, "broken.json"), []byte("{"), 0o600)
+
+	states := h.load()
+	if len(states) != 1 {
+		t.Fatalf("states = %d, want 1", len(states))
+	}
+	got := states[0]
+	if got.RunID != "run-1" || got.Offset != 128 || got.Seq != 7 || !got.Pooled || !slices.Equal(got.Holds, []string{pauseByUser}) {
+		t.Errorf("state = %+v", got)
+	}
+	if ws := got.Workspace.prepared(); ws == nil || ws.Cleanup == nil || ws.Path != st.Workspace.Path {
+		t.Errorf("workspace = %+v", ws)
+	}
+	if _, err := os.Stat(filepath.Join(h.dir, "broken.json")); !os.IsNotExist(err) {
+		t.Error("invalid state file should be removed")
+	}
+
+	h.discard(got, nil)
+	if len(h.load()) != 0 {
+		t.Error("state file should be removed after discard")
+	}
+}
+
+func TestStreamOutputFrom_Progress(t *testing.T) {
+	nm, _ := newHookTestManager(t)
+	output := "not json\n" + `{"type":"message","payload":{"content":"a"}}` + "\n\n" + `{"type":"message","payload":{"content":"b"}}`
+
+	type mark struct {
+		consumed int64
+		seq      int
+	}
+	var marks []mark
+	seq := nm.streamOutputFrom(context.Background(), "run-1", strings.NewReader(output), jsonLineAdapter{}, nil, nil, 5, func(consumed int64, seq int) {
+		marks = append(marks, mark{consumed, seq})
+	})
+	want := []mark{{9, 5}, {54, 6}, {55, 6}, {int64(len(output)), 7}}
+	if seq != 7 || !slices.Equal(marks, want) {
+		t.Errorf("seq = %d, marks = %v, want %v", seq, marks, want)
+	}
+}
+
+func TestDetachedRun_HandoverAndResume(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process backend requires linux")
+	}
+	nm, events := newHookTestManager(t)
+	var err error
+	if nm.handover, err = newHandover(HandoverConfig{StateDir: t.TempDir()}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if nm.process, err = newProcessBackend(ProcessConfig{}, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	target, err := nm.process.prepare("run-1", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.cleanup()
+
+	// 第一行之后等待 release 文件，以便在 Agent 运行期间交接
+	script := `echo '{"type":"message","payload":{"n":1}}'
+while [ ! -e release ]; do sleep 0.05; done
+echo '{"type":"message","payload":{"n":2}}'
+echo oops >&2
+exit 3`
+	x := &runExecution{runID: "run-1", snapshot: &model.RunSnapshot{}, target: target}
+	nodeCtx, stopNode := context.WithCancel(context.Background())
+	nm.handover.ctx = nodeCtx
+	st, err := nm.launchDetached(nodeCtx, x, []string{"sh", "-c", script}, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.PID <= 0 || st.PGID <= 0 {
+		t.Fatalf("state = %+v", st)
+	}
+
+	done := make(chan agentResult, 1)
+	go func() {
+		done <- nm.superviseDetached(nodeCtx, x, st, &runOutput{target: target, dir: st.OutputDir}, jsonLineAdapter{}, nm.newFeedbackChannel("run-1", target, jsonLineAdapter{}))
+	}()
+	waitFor(t, func() bool { return len(events()) == 1 })
+	stopNode()
+	if res := <-done; !res.handedOff {
+		t.Fatalf("result = %+v, want handed off", res)
+	}
+
+	// 下一次启动：从检查点继续读取，Agent 在交接期间继续运行
+	states := nm.handover.load()
+	if len(states) != 1 || states[0].Offset == 0 || states[0].Seq != 2 {
+		t.Fatalf("states = %+v", states)
+	}
+	nm.handover = &handover{dir: nm.handover.dir, ctx: context.Background()}
+	os.WriteFile(filepath.Join(target.dir, "release"), nil, 0o644)
+	x = &runExecution{runID: "run-1", snapshot: &model.RunSnapshot{}, target: target}
+	res := nm.superviseDetached(context.Background(), x, states[0], &runOutput{target: target, dir: st.OutputDir}, jsonLineAdapter{}, nm.newFeedbackChannel("run-1", target, jsonLineAdapter{}))
+
+	var exitErr *agentExitError
+	if !errors.As(res.err, &exitErr) || exitErr.ExitCode() != 3 || res.seq != 3 || strings.TrimSpace(res.stderr) != "oops" {
+		t.Fatalf("result = %+v", res)
+	}
+	var ns []interface{}
+	for _, e := range events() {
+		ns = append(ns, e["payload"].(map[string]interface{})["n"])
+	}
+	if !slices.Equal(ns, []interface{}{1.0, 2.0}) {
+		t.Errorf("payloads = %v, want each line reported once", ns)
+	}
+
+	nm.handover.discard(states[0], target)
+	if _, err := os.Stat(st.OutputDir); !os.IsNotExist(err) || len(nm.handover.load()) != 0 {
+		t.Errorf("output dir and state should be removed, stat err = %v", err)
+	}
+}
+
+func TestDetachedRun_Lost(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("process backend requires linux")
+	}
+	nm, _ := newHookTestManager(t)
+	var err error
+	if nm.handover, err = newHandover(HandoverConfig{StateDir: t.TempDir()}, ""); err != nil {
+		t.Fatal(err)
+	}
+	if nm.process, err = newProcessBackend(ProcessConfig{}, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	target, err := nm.process.prepare("run-1", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer target.cleanup()
+
+	x := &runExecution{runID: "run-1", snapshot: &model.RunSnapshot{}, target: target}
+	st, err := nm.launchDetached(context.Background(), x, []string{"sleep", "30"}, nil, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 模拟执行环境重启：Agent 与等待其退出的 shell 一起消失，没有留下退出码
+	nm.killDetached(target, st)
+
+	res := nm.superviseDetached(context.Background(), x, st, &runOutput{target: target, dir: st.OutputDir}, jsonLineAdapter{}, nm.newFeedbackChannel("run-1", target, jsonLineAdapter{}))
+	if !errors.Is(res.err, errAgentLost) || res.reason == "" {
+		t.Errorf("result = %+v, want lost", res)
+	}
+}
+
+// waitFor 轮询直到条件成立（最多 5 秒）
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,70 @@
+// Package nodemanager 心跳协议 v2（增量心跳）
+//
+// 节点对主机名、IP、标签与容量（不含 available 与 metrics）计算内容哈希。API Server 在响应中表明支持 v2 后，
+// 哈希未变化的心跳只携带哈希、运行状态与 metrics 采样；哈希变化、服务端要求 resync 或距上次完整上报超过
+// heartbeatFullInterval 时携带完整内容。心跳间隔取响应中的 interval_seconds（空闲时由服务端逐步放大）。
+// gRPC 模式下心跳仍为完整上报，间隔固定为 defaultHeartbeatInterval。
+package nodemanager
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+const (
+	// heartbeatProtocolVersion 节点支持的心跳协议版本
+	heartbeatProtocolVersion = 2
+
+	// defaultHeartbeatInterval 默认心跳间隔（服务端未下发间隔时使用）
+	defaultHeartbeatInterval = 10 * time.Second
+
+	// heartbeatFullInterval 内容未变化时完整上报的最长间隔（刷新服务端保存的 available 与最新 metrics）
+	heartbeatFullInterval = 5 * time.Minute
+)
+
+// heartbeatState 增量心跳状态（仅由心跳循环访问）
+type heartbeatState struct {
+	serverV2  bool          // API Server 支持 v2 心跳（最近一次响应带 protocol_version >= 2）
+	ackedHash string        // 服务端已确认写入的内容哈希
+	fullAt    time.Time     // 最近一次完整上报被确认的时间
+	interval  time.Duration // 服务端下发的心跳间隔
+}
+
+// needFull 本次心跳是否需要携带完整内容
+func (s *heartbeatState) needFull(hash string, now time.Time) bool {
+	return !s.serverV2 || s.ackedHash != hash || now.Sub(s.fullAt) >= heartbeatFullInterval
+}
+
+// update 根据心跳响应更新状态（full 表示本次心跳携带了完整内容）
+func (s *heartbeatState) update(resp *heartbeatResponse, hash string, full bool, now time.Time) {
+	s.serverV2 = resp.ProtocolVersion >= heartbeatProtocolVersion
+	s.interval = time.Duration(resp.IntervalSeconds) * time.Second
+	switch {
+	case !s.serverV2 || resp.Resync:
+		s.ackedHash = ""
+	case full:
+		s.ackedHash, s.fullAt = hash, now
+	}
+}
+
+// nextInterval 返回下次心跳前的等待时间
+func (s *heartbeatState) nextInterval() time.Duration {
+	if s.interval <= 0 {
+		return defaultHeartbeatInterval
+	}
+	return s.interval
+}
+
+// heartbeatContentHash 计算心跳内容哈希（json.Marshal 对 map 按键排序，结果稳定）
+func heartbeatContentHash(hostname, ips string, labels map[string]string, capacity map[string]interface{}) string {
+	data, _ := json.Marshal(map[string]interface{}{
+		"hostname": hostname,
+		"ips":      ips,
+		"labels":   labels,
+		"capacity": capacity,
+	})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,100 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// TestSendHeartbeat_Delta 服务端支持 v2 后，内容未变化的心跳不携带标签与容量；resync 后恢复完整上报
+func TestSendHeartbeat_Delta(t *testing.T) {
+	var bodies []map[string]interface{}
+	resync := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		bodies = append(bodies, body)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"status": "ok", "protocol_version": 2, "interval_seconds": 20, "resync": resync,
+		})
+	}))
+	defer srv.Close()
+
+	nm := &NodeManager{
+		config:     Config{NodeID: "node-1", APIServerURL: srv.URL, Labels: map[string]string{"region": "cn-east"}},
+		httpClient: srv.Client(),
+		running:    map[string]context.CancelFunc{},
+	}
+	ctx := context.Background()
+
+	nm.sendHeartbeat(ctx)
+	nm.sendHeartbeat(ctx)
+	resync = true
+	nm.sendHeartbeat(ctx)
+	resync = false
+	nm.sendHeartbeat(ctx)
+
+	if len(bodies) != 4 {
+		t.Fatalf("heartbeats = %d, want 4", len(bodies))
+	}
+	wantFull := []bool{true, false, false, true}
+	for i, body := range bodies {
+		_, hasCapacity := body["capacity"]
+		_, hasLabels := body["labels"]
+		if hasCapacity != wantFull[i] || hasLabels != wantFull[i] {
+			t.Errorf("heartbeat %d: capacity=%v labels=%v, want full=%v", i, hasCapacity, hasLabels, wantFull[i])
+		}
+		if body["protocol_version"] != float64(2) || body["content_hash"] != bodies[0]["content_hash"] {
+			t.Errorf("heartbeat %d: protocol_version=%v content_hash=%v", i, body["protocol_version"], body["content_hash"])
+		}
+	}
+	if bodies[1]["interval_seconds"] != float64(20) {
+		t.Errorf("interval_seconds = %v, want 20", bodies[1]["interval_seconds"])
+	}
+	if got := nm.heartbeat.nextInterval(); got != 20*time.Second {
+		t.Errorf("nextInterval = %v, want 20s", got)
+	}
+}
+
+func TestHeartbeatState(t *testing.T) {
+	now := time.Now()
+	var s heartbeatState
+	if !s.needFull("h1", now) || s.nextInterval() != defaultHeartbeatInterval {
+		t.Fatal("服务端未确认 v2 前应完整上报并使用默认间隔")
+	}
+
+	// 旧版本 API Server：响应不带 protocol_version，始终完整上报
+	s.update(&heartbeatResponse{Status: "ok"}, "h1", true, now)
+	if !s.needFull("h1", now) {
+		t.Error("旧版本 API Server 应完整上报")
+	}
+
+	s.update(&heartbeatResponse{ProtocolVersion: 2, IntervalSeconds: 25}, "h1", true, now)
+	if s.needFull("h1", now.Add(time.Minute)) {
+		t.Error("内容未变化时应发送增量心跳")
+	}
+	if !s.needFull("h2", now) {
+		t.Error("内容变化时应完整上报")
+	}
+	if !s.needFull("h1", now.Add(heartbeatFullInterval)) {
+		t.Error("超过完整上报间隔时应完整上报")
+	}
+	if s.nextInterval() != 25*time.Second {
+		t.Errorf("nextInterval = %v, want 25s", s.nextInterval())
+	}
+}
+
+func TestHeartbeatContentHash(t *testing.T) {
+	capacity := map[string]interface{}{"max_concurrent": 2, "cpus": 4}
+	a := heartbeatContentHash("host", "10.0.0.1", map[string]string{"a": "1", "b": "2"}, capacity)
+	b := heartbeatContentHash("host", "10.0.0.1", map[string]string{"b": "2", "a": "1"}, capacity)
+	if a != b {
+		t.Errorf("哈希应与 map 顺序无关: %s != %s", a, b)
+	}
+	if c := heartbeatContentHash("host", "10.0.0.1", map[string]string{"a": "1"}, capacity); c == a {
+		t.Error("标签变化时哈希应变化")
+	}
+}
@@ -51,13 +51,14 @@ func (s *HeartbeatService) send(ctx context.Context) {
 		runningCount = s.getRunning()
 	}
 
+	maxConcurrent := s.config.Concurrency.Normalize().MaxConcurrent
 	payload := map[string]interface{}{
 		"node_id": s.config.NodeID,
 		"status":  "online",
 		"labels":  s.config.Labels,
 		"capacity": map[string]interface{}{
-			"max_concurrent": 2,
-			"available":      2 - runningCount,
+			"max_concurrent": maxConcurrent,
+			"available":      maxConcurrent - runningCount,
 		},
 	}
 
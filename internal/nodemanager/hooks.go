@@ -0,0 +1,126 @@
+// Package nodemanager Run 生命周期钩子
+//
+// 钩子脚本在 Run 的执行目标（Agent 容器或 process 后端的宿主机目录）中、工作目录下按顺序执行（<shell> -c <script>）：
+//   - pre_run：Agent 启动前执行，非零退出码中止 Run（Agent 不会启动）
+//   - post_run：Agent 成功结束后、Git 结果回写前执行，非零退出码将 Run 标记为失败
+//   - on_failure：Run 失败后执行，结果仅记录
+//
+// 每个钩子上报 hook_started / hook_completed 事件，输出截断后随事件上报。
+// continue_on_error 的钩子失败时不中止。
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// 钩子阶段（与 model.HookPhase 一致）
+const (
+	hookPhasePreRun    = "pre_run"
+	hookPhasePostRun   = "post_run"
+	hookPhaseOnFailure = "on_failure"
+)
+
+// 钩子默认值
+const (
+	defaultHookTimeout = 300 * time.Second
+	defaultHookShell   = "sh"
+	maxHookOutputBytes = 64 * 1024 // 单个钩子上报的最大输出
+)
+
+// HookSpec 钩子定义（快照中 Skill 引用已展开为内联脚本，SkillID 为空）
+type HookSpec = model.HookSpec
+
+// LifecycleHooks 生命周期钩子配置
+type LifecycleHooks = model.LifecycleHooks
+
+// hookResult 单个钩子执行结果
+type hookResult struct {
+	ExitCode int
+	Output   string
+}
+
+// hookRunner 执行单个钩子（便于测试替换实际的执行目标）
+type hookRunner func(ctx context.Context, hook HookSpec, env map[string]string) (*hookResult, error)
+
+// ParseLifecycleHooks 从任务快照中解析钩子配置，未配置时返回 nil
+func ParseLifecycleHooks(snapshot *model.RunSnapshot) *LifecycleHooks {
+	if snapshot.Hooks.IsEmpty() {
+		return nil
+	}
+	return snapshot.Hooks
+}
+
+// runHooks 按顺序执行某阶段的钩子，返回下一个事件序号
+//
+// 钩子以非零退出码结束（或无法执行、超时）且未设置 continue_on_error 时停止执行后续钩子并返回错误。
+func (nm *NodeManager) runHooks(ctx context.Context, runID, phase string, hooks []HookSpec, run hookRunner, env map[string]string, seq int) (int, error) {
+	for _, hook := range hooks {
+		nm.reportEvent(ctx, runID, seq, "hook_started", map[string]interface{}{
+			"phase": phase,
+			"name":  hook.Name,
+		})
+		seq++
+
+		timeout := defaultHookTimeout
+		if hook.TimeoutSeconds > 0 {
+			timeout = time.Duration(hook.TimeoutSeconds) * time.Second
+		}
+		hookEnv := map[string]string{"AGENTS_HOOK_PHASE": phase, "AGENTS_HOOK_NAME": hook.Name}
+		for k, v := range env {
+			hookEnv[k] = v
+		}
+
+		start := time.Now()
+		hookCtx, cancel := context.WithTimeout(ctx, timeout)
+		result, err := run(hookCtx, hook, hookEnv)
+		timedOut := hookCtx.Err() == context.DeadlineExceeded
+		cancel()
+		if result == nil {
+			result = &hookResult{ExitCode: -1}
+		}
+
+		failed := err != nil || result.ExitCode != 0
+		abort := failed && !hook.ContinueOnError
+		payload := map[string]interface{}{
+			"phase":       phase,
+			"name":        hook.Name,
+			"exit_code":   result.ExitCode,
+			"output":      truncateHookOutput(result.Output),
+			"duration_ms": time.Since(start).Milliseconds(),
+			"aborted":     abort,
+		}
+		if err != nil {
+			payload["error"] = err.Error()
+		}
+		if timedOut {
+			payload["error"] = fmt.Sprintf("timeout after %s", timeout)
+		}
+		nm.reportEvent(ctx, runID, seq, "hook_completed", payload)
+		seq++
+
+		log.Printf("[Hooks] 任务 %s %s 钩子 %s 结束: exit_code=%d", runID, phase, hook.Name, result.ExitCode)
+		if abort {
+			if timedOut {
+				return seq, fmt.Errorf("%s 钩子 %s 超时（%s）", phase, hook.Name, timeout)
+			}
+			if err != nil {
+				return seq, fmt.Errorf("%s 钩子 %s 执行失败: %v", phase, hook.Name, err)
+			}
+			return seq, fmt.Errorf("%s 钩子 %s 退出码 %d", phase, hook.Name, result.ExitCode)
+		}
+	}
+	return seq, nil
+}
+
+// truncateHookOutput 截断过长的钩子输出（保留末尾，通常包含错误信息）
+func truncateHookOutput(s string) string {
+	if len(s) <= maxHookOutputBytes {
+		return s
+	}
+	return "...(truncated)\n" + s[len(s)-maxHookOutputBytes:]
+}
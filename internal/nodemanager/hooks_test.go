@@ -0,0 +1,115 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// newHookTestManager 创建上报事件到 httptest 服务的 NodeManager，返回收集到的事件
+func newHookTestManager(t *testing.T) (*NodeManager, func() []map[string]interface{}) {
+	t.Helper()
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Events []map[string]interface{} `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		mu.Lock()
+		events = append(events, body.Events...)
+		mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+	}))
+	t.Cleanup(srv.Close)
+
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client()}
+	return nm, func() []map[string]interface{} {
+		mu.Lock()
+		defer mu.Unlock()
+		return events
+	}
+}
+
+func TestParseLifecycleHooks(t *testing.T) {
+	snapshot := &model.RunSnapshot{
+		Hooks: &model.LifecycleHooks{
+			PreRun:    []model.HookSpec{{Name: "install", Script: "npm ci", TimeoutSeconds: 60}},
+			OnFailure: []model.HookSpec{{Name: "notify", Script: "echo failed", ContinueOnError: true}},
+		},
+	}
+	hooks := ParseLifecycleHooks(snapshot)
+	if hooks == nil || len(hooks.PreRun) != 1 || len(hooks.OnFailure) != 1 {
+		t.Fatalf("hooks = %+v", hooks)
+	}
+	if hooks.PreRun[0].TimeoutSeconds != 60 || !hooks.OnFailure[0].ContinueOnError {
+		t.Errorf("字段解析错误: %+v", hooks)
+	}
+	if ParseLifecycleHooks(&model.RunSnapshot{Hooks: &model.LifecycleHooks{}}) != nil {
+		t.Error("未配置钩子时应返回 nil")
+	}
+}
+
+func TestRunHooks_AbortOnFailure(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	var executed []string
+	runner := func(_ context.Context, hook HookSpec, env map[string]string) (*hookResult, error) {
+		executed = append(executed, hook.Name)
+		if env["AGENTS_RUN_ID"] != "run-1" || env["AGENTS_HOOK_PHASE"] != hookPhasePreRun {
+			t.Errorf("env = %v", env)
+		}
+		if hook.Name == "lint" {
+			return &hookResult{ExitCode: 2, Output: "lint error"}, nil
+		}
+		return &hookResult{Output: "ok"}, nil
+	}
+
+	hooks := []HookSpec{{Name: "setup"}, {Name: "lint"}, {Name: "never"}}
+	seq, err := nm.runHooks(context.Background(), "run-1", hookPhasePreRun, hooks, runner,
+		map[string]string{"AGENTS_RUN_ID": "run-1"}, 2)
+	if err == nil || !strings.Contains(err.Error(), "退出码 2") {
+		t.Fatalf("err = %v", err)
+	}
+	if strings.Join(executed, ",") != "setup,lint" {
+		t.Errorf("失败后不应继续执行: %v", executed)
+	}
+	if seq != 6 {
+		t.Errorf("seq = %d, 期望 6", seq)
+	}
+
+	got := events()
+	if len(got) != 4 {
+		t.Fatalf("events = %d, 期望 4", len(got))
+	}
+	last := got[3]
+	payload := last["payload"].(map[string]interface{})
+	if last["type"] != "hook_completed" || payload["exit_code"].(float64) != 2 ||
+		payload["output"] != "lint error" || payload["aborted"] != true {
+		t.Errorf("hook_completed = %v", last)
+	}
+}
+
+func TestRunHooks_ContinueOnError(t *testing.T) {
+	nm, _ := newHookTestManager(t)
+	runner := func(_ context.Context, hook HookSpec, _ map[string]string) (*hookResult, error) {
+		return &hookResult{ExitCode: 1}, nil
+	}
+	hooks := []HookSpec{{Name: "optional", ContinueOnError: true}, {Name: "also-optional", ContinueOnError: true}}
+	if _, err := nm.runHooks(context.Background(), "run-1", hookPhasePostRun, hooks, runner, nil, 1); err != nil {
+		t.Errorf("continue_on_error 钩子不应中止: %v", err)
+	}
+}
+
+func TestTruncateHookOutput(t *testing.T) {
+	long := strings.Repeat("a", maxHookOutputBytes) + "tail"
+	out := truncateHookOutput(long)
+	if !strings.HasSuffix(out, "tail") || len(out) > maxHookOutputBytes+32 {
+		t.Errorf("截断结果错误: len=%d", len(out))
+	}
+}
@@ -0,0 +1,430 @@
+// Package nodemanager 预热实例池
+//
+// 按账号保持 N 个已启动的空闲容器（Config.Pools），docker 执行后端的 Run 未指定 instance_id 时
+// 优先租用预热容器，省去创建容器、恢复认证 Volume 的时间。Run 结束后清理容器内的 /workspace
+// 并放回池中；清理失败或容器已停止时删除，由补充循环重新创建。
+//
+// 池容器命名为 agent_pool_<随机后缀>（不含账号 ID，避免被按账号查找容器的回退逻辑选中），
+// 通过 agents-admin.pool_account 标签关联账号；Node Manager 重启后清理并接管已有的池容器，
+// 重启交接中仍在运行 Run 的容器（reserve 登记）保持租用，不清理工作空间。
+package nodemanager
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// poolContainerPrefix 池容器名前缀
+	poolContainerPrefix = "agent_pool_"
+	// poolAccountLabel 池容器所属账号的标签
+	poolAccountLabel = "agents-admin.pool_account"
+	// poolFillInterval 补充空闲容器的检查间隔（租用后立即触发一次）
+	poolFillInterval = 10 * time.Second
+	// poolCleanupScript 归还容器前清理 Run 的工作空间
+	poolCleanupScript = "rm -rf /workspace && mkdir -p /workspace"
+)
+
+// PoolConfig 单个账号的预热实例池配置
+type PoolConfig struct {
+	AccountID string // 账号 ID（Agent 类型、镜像与认证 Volume 取自账号）
+	Size      int    // 保持空闲的预热容器数
+}
+
+// poolTemplate 创建池容器所需的账号信息
+type poolTemplate struct {
+	agentType string
+	image     string
+	volume    string
+	authDir   string
+}
+
+// accountPool 单个账号的实例池
+type accountPool struct {
+	accountID string
+	agentType string
+	size      int
+	idle      []string          // 空闲容器（先进先出）
+	leased    map[string]string // 已租用容器 → run_id
+	warming   int
+
+	leases, misses, recycled, discarded int64
+	warmed                              int64         // 已创建的容器数（计算平均创建耗时）
+	warmTotal                           time.Duration // 累计创建耗时
+	lastError                           string
+}
+
+// instancePool 预热实例池管理器
+type instancePool struct {
+	rt      ContainerRuntime
+	nodeID  string
+	resolve func(ctx context.Context, accountID string) (*poolTemplate, error)
+
+	mu     sync.Mutex
+	pools  map[string]*accountPool // account_id → 实例池
+	order  []string                // 配置顺序（状态输出保持稳定）
+	byName map[string]string       // 池容器 → account_id
+	held   map[string]string       // 重启交接中仍被 Run 使用的池容器 → run_id（adopt 前登记）
+	wake   chan struct{}
+}
+
+// newInstancePool 创建实例池管理器，没有有效配置时返回 nil
+func newInstancePool(configs []PoolConfig, rt ContainerRuntime, nodeID string, resolve func(ctx context.Context, accountID string) (*poolTemplate, error)) *instancePool {
+	p := &instancePool{
+		rt:      rt,
+		nodeID:  nodeID,
+		resolve: resolve,
+		pools:   make(map[string]*accountPool),
+		byName:  make(map[string]string),
+		wake:    make(chan struct{}, 1),
+	}
+	for _, c := range configs {
+		if c.AccountID == "" || c.Size <= 0 {
+			continue
+		}
+		if ap, ok := p.pools[c.AccountID]; ok {
+			ap.size = c.Size
+			continue
+		}
+		p.pools[c.AccountID] = &accountPool{accountID: c.AccountID, size: c.Size, leased: make(map[string]string)}
+		p.order = append(p.order, c.AccountID)
+	}
+	if len(p.pools) == 0 {
+		return nil
+	}
+	return p
+}
+
+// run 接管已有的池容器后持续补充空闲容器，ctx 结束时返回（池容器保留，下次启动时接管）
+func (p *instancePool) run(ctx context.Context) {
+	log.Printf("[InstancePool] 启动预热实例池: %d 个账号", len(p.order))
+	p.adopt(ctx)
+
+	ticker := time.NewTicker(poolFillInterval)
+	defer ticker.Stop()
+	for {
+		p.fill(ctx)
+		select {
+		case <-ctx.Done():
+			log.Println("[InstancePool] 预热实例池停止")
+			return
+		case <-ticker.C:
+		case <-p.wake:
+		}
+	}
+}
+
+// adopt 接管上次运行留下的池容器：清理工作空间后放入空闲列表，不再需要的容器删除
+func (p *instancePool) adopt(ctx context.Context) {
+	names, err := p.rt.List(ctx, true)
+	if err != nil {
+		log.Printf("[InstancePool] 列出容器失败: %v", err)
+		return
+	}
+	for _, name := range names {
+		if !strings.HasPrefix(name, poolContainerPrefix) {
+			continue
+		}
+		info, err := p.rt.Inspect(ctx, name)
+		if err != nil {
+			continue
+		}
+		accountID := info.Labels[poolAccountLabel]
+		p.mu.Lock()
+		ap, ok := p.pools[accountID]
+		if runID, held := p.held[name]; held {
+			delete(p.held, name)
+			if ok && info.Running {
+				p.byName[name] = accountID
+				ap.leased[name] = runID
+			}
+			p.mu.Unlock()
+			log.Printf("[InstancePool] 池容器 %s 仍被任务 %s 使用，保持租用", name, runID)
+			continue
+		}
+		keep := ok && info.Running && info.Labels["agents-admin.node_id"] == p.nodeID && len(ap.idle) < ap.size
+		if keep {
+			p.byName[name] = accountID
+			ap.leased[name] = ""
+		}
+		p.mu.Unlock()
+		if keep {
+			p.release(ctx, name)
+			continue
+		}
+		log.Printf("[InstancePool] 删除不再需要的池容器: %s (account=%s, running=%v)", name, accountID, info.Running)
+		if err := p.rt.Remove(ctx, name); err != nil {
+			log.Printf("[InstancePool] 删除容器 %s 失败: %v", name, err)
+		}
+	}
+}
+
+// fill 为空闲容器不足的账号创建预热容器
+func (p *instancePool) fill(ctx context.Context) {
+	for _, accountID := range p.order {
+		p.mu.Lock()
+		ap := p.pools[accountID]
+		need := ap.size - len(ap.idle) - ap.warming
+		if need > 0 {
+			ap.warming += need
+		}
+		p.mu.Unlock()
+		if need <= 0 {
+			continue
+		}
+
+		tmpl, err := p.resolve(ctx, accountID)
+		if err != nil {
+			p.mu.Lock()
+			ap.warming -= need
+			ap.lastError = err.Error()
+			p.mu.Unlock()
+			log.Printf("[InstancePool] 账号 %s 获取容器配置失败: %v", accountID, err)
+			continue
+		}
+		for i := 0; i < need; i++ {
+			if err := p.warm(ctx, ap, tmpl); err != nil {
+				p.mu.Lock()
+				ap.warming -= need - i
+				ap.lastError = err.Error()
+				p.mu.Unlock()
+				log.Printf("[InstancePool] 账号 %s 创建预热容器失败: %v", accountID, err)
+				break
+			}
+		}
+	}
+}
+
+// warm 创建一个预热容器并放入空闲列表
+func (p *instancePool) warm(ctx context.Context, ap *accountPool, tmpl *poolTemplate) error {
+	suffix := make([]byte, 6)
+	rand.Read(suffix)
+	name := poolContainerPrefix + hex.EncodeToString(suffix)
+
+	start := time.Now()
+	if _, err := p.rt.Create(ctx, poolContainerSpec(name, ap.accountID, p.nodeID, tmpl)); err != nil {
+		return err
+	}
+	elapsed := time.Since(start)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ap.agentType = tmpl.agentType
+	ap.warming--
+	ap.warmed++
+	ap.warmTotal += elapsed
+	ap.lastError = ""
+	ap.idle = append(ap.idle, name)
+	p.byName[name] = ap.accountID
+	log.Printf("[InstancePool] 账号 %s 预热容器就绪: %s (%s)", ap.accountID, name, elapsed.Round(time.Millisecond))
+	return nil
+}
+
+// poolContainerSpec 池容器参数（与实例容器相同的镜像与认证 Volume 挂载）
+func poolContainerSpec(name, accountID, nodeID string, tmpl *poolTemplate) ContainerSpec {
+	return ContainerSpec{
+		Name:  name,
+		Image: tmpl.image,
+		Labels: map[string]string{
+			"agents-admin.managed":    "true",
+			"agents-admin.account_id": accountID,
+			"agents-admin.node_id":    nodeID,
+			poolAccountLabel:          accountID,
+		},
+		Volumes:     []string{fmt.Sprintf("%s:%s", tmpl.volume, tmpl.authDir)},
+		Restart:     "unless-stopped",
+		TTY:         true,
+		Interactive: true,
+	}
+}
+
+// lease 为 Run 租用账号的空闲容器；账号未配置实例池或没有空闲容器时返回 false
+func (p *instancePool) lease(ctx context.Context, accountID, runID string) (string, bool) {
+	for {
+		p.mu.Lock()
+		ap, ok := p.pools[accountID]
+		if !ok {
+			p.mu.Unlock()
+			return "", false
+		}
+		if len(ap.idle) == 0 {
+			ap.misses++
+			p.mu.Unlock()
+			p.notify()
+			return "", false
+		}
+		name := ap.idle[0]
+		ap.idle = ap.idle[1:]
+		p.mu.Unlock()
+
+		// 空闲期间容器可能被停止或删除
+		if info, err := p.rt.Inspect(ctx, name); err != nil || !info.Running {
+			p.discard(ctx, name)
+			continue
+		}
+
+		p.mu.Lock()
+		ap.leased[name] = runID
+		ap.leases++
+		p.mu.Unlock()
+		p.notify()
+		return name, true
+	}
+}
+
+// transfer 将保留的会话容器转租给追问 Run（见 session.go）
+func (p *instancePool) transfer(name, runID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if ap := p.pools[p.byName[name]]; ap != nil {
+		ap.leased[name] = runID
+	}
+}
+
+// reserve 登记重启交接中仍被 Run 使用的池容器（在 run 之前调用），adopt 时保持租用
+func (p *instancePool) reserve(name, runID string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.held == nil {
+		p.held = make(map[string]string)
+	}
+	p.held[name] = runID
+}
+
+// release 归还租用的容器：清理工作空间后放回空闲列表，失败或空闲容器已足够时删除
+func (p *instancePool) release(ctx context.Context, name string) {
+	p.mu.Lock()
+	// 接管的 Run 在 adopt 之前结束：取消登记，由 adopt 按常规清理接管
+	delete(p.held, name)
+	accountID, ok := p.byName[name]
+	ap := p.pools[accountID]
+	if !ok || ap == nil {
+		p.mu.Unlock()
+		return
+	}
+	delete(ap.leased, name)
+	full := len(ap.idle) >= ap.size
+	p.mu.Unlock()
+
+	if full {
+		p.discard(ctx, name)
+		return
+	}
+	cmd := p.rt.Exec(ctx, name, []string{"sh", "-c", poolCleanupScript}, ExecOptions{})
+	if out, err := cmd.CombinedOutput(); err != nil {
+		log.Printf("[InstancePool] 清理容器 %s 失败: %v, 输出: %s", name, err, strings.TrimSpace(string(out)))
+		p.discard(ctx, name)
+		return
+	}
+
+	p.mu.Lock()
+	ap.idle = append(ap.idle, name)
+	ap.recycled++
+	p.mu.Unlock()
+}
+
+// discard 删除池容器，由补充循环重新创建
+func (p *instancePool) discard(ctx context.Context, name string) {
+	p.mu.Lock()
+	if ap := p.pools[p.byName[name]]; ap != nil {
+		ap.discarded++
+	}
+	delete(p.byName, name)
+	p.mu.Unlock()
+
+	if err := p.rt.Remove(ctx, name); err != nil {
+		log.Printf("[InstancePool] 删除容器 %s 失败: %v", name, err)
+	}
+	p.notify()
+}
+
+// notify 触发一次补充（不阻塞）
+func (p *instancePool) notify() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+// status 各账号实例池的状态（随心跳上报）
+func (p *instancePool) status() []model.InstancePoolStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	out := make([]model.InstancePoolStatus, 0, len(p.order))
+	for _, accountID := range p.order {
+		ap := p.pools[accountID]
+		st := model.InstancePoolStatus{
+			AccountID: ap.accountID,
+			AgentType: ap.agentType,
+			Size:      ap.size,
+			Idle:      len(ap.idle),
+			Leased:    len(ap.leased),
+			Warming:   ap.warming,
+			Leases:    ap.leases,
+			Misses:    ap.misses,
+			Recycled:  ap.recycled,
+			Discarded: ap.discarded,
+			LastError: ap.lastError,
+		}
+		if ap.warmed > 0 {
+			st.AvgWarmMS = (ap.warmTotal / time.Duration(ap.warmed)).Milliseconds()
+		}
+		out = append(out, st)
+	}
+	return out
+}
+
+// poolTemplate 获取账号的镜像与认证 Volume（本地不存在时从归档恢复）
+func (w *AgentWorker) poolTemplate(ctx context.Context, accountID string) (*poolTemplate, error) {
+	account, err := w.getAccount(ctx, accountID)
+	if err != nil {
+		return nil, fmt.Errorf("获取账号失败: %w", err)
+	}
+	if account.VolumeName == "" {
+		return nil, fmt.Errorf("账号 %s 没有 Volume", accountID)
+	}
+	agentType, err := w.getAgentType(ctx, account.AgentTypeID)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Agent 类型失败: %w", err)
+	}
+	if err := w.ensureVolumeFromArchive(ctx, accountID, account.VolumeName, agentType.AuthDir); err != nil {
+		return nil, err
+	}
+	return &poolTemplate{
+		agentType: account.AgentTypeID,
+		image:     agentType.Image,
+		volume:    account.VolumeName,
+		authDir:   agentType.AuthDir,
+	}, nil
+}
+
+// poolStatus 预热实例池状态，未配置实例池时为 nil
+func (nm *NodeManager) poolStatus() []model.InstancePoolStatus {
+	if nm.pool == nil {
+		return nil
+	}
+	return nm.pool.status()
+}
+
+// poolsToProto 实例池状态转换为 gRPC 心跳格式
+func poolsToProto(pools []model.InstancePoolStatus) []*nodev1.InstancePool {
+	out := make([]*nodev1.InstancePool, 0, len(pools))
+	for _, p := range pools {
+		out = append(out, &nodev1.InstancePool{
+			AccountId: p.AccountID, AgentType: p.AgentType,
+			Size: int32(p.Size), Idle: int32(p.Idle), Leased: int32(p.Leased), Warming: int32(p.Warming),
+			Leases: p.Leases, Misses: p.Misses, Recycled: p.Recycled, Discarded: p.Discarded,
+			AvgWarmMs: p.AvgWarmMS, LastError: p.LastError,
+		})
+	}
+	return out
+}
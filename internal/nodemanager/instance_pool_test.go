@@ -0,0 +1,199 @@
+package nodemanager
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"sync"
+	"testing"
+)
+
+// poolRuntime 内存中的容器运行时（只实现实例池用到的方法）
+type poolRuntime struct {
+	ContainerRuntime
+	mu         sync.Mutex
+	containers map[string]*ContainerInfo
+	execs      []string
+	cleanupErr bool
+}
+
+func newPoolRuntime() *poolRuntime {
+	return &poolRuntime{containers: make(map[string]*ContainerInfo)}
+}
+
+func (r *poolRuntime) Create(_ context.Context, spec ContainerSpec) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.containers[spec.Name] = &ContainerInfo{Image: spec.Image, Running: true, Labels: spec.Labels}
+	return spec.Name, nil
+}
+
+func (r *poolRuntime) Remove(_ context.Context, name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.containers, name)
+	return nil
+}
+
+func (r *poolRuntime) List(context.Context, bool) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var names []string
+	for name := range r.containers {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (r *poolRuntime) Inspect(_ context.Context, name string) (*ContainerInfo, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	info, ok := r.containers[name]
+	if !ok {
+		return nil, errors.New("no such container")
+	}
+	copied := *info
+	return &copied, nil
+}
+
+func (r *poolRuntime) Exec(_ context.Context, name string, argv []string, _ ExecOptions) *exec.Cmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.execs = append(r.execs, name)
+	if r.cleanupErr {
+		return exec.Command("false")
+	}
+	return exec.Command("true")
+}
+
+func (r *poolRuntime) count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.containers)
+}
+
+func testPoolTemplate(context.Context, string) (*poolTemplate, error) {
+	return &poolTemplate{agentType: "qwen", image: "runners/qwen:latest", volume: "vol-acc-1", authDir: "/root/.qwen"}, nil
+}
+
+func TestInstancePool_LeaseAndRecycle(t *testing.T) {
+	ctx := context.Background()
+	rt := newPoolRuntime()
+	p := newInstancePool([]PoolConfig{{AccountID: "acc-1", Size: 2}, {AccountID: "", Size: 3}}, rt, "node-1", testPoolTemplate)
+	if p == nil {
+		t.Fatal("pool should be created")
+	}
+	p.fill(ctx)
+	if rt.count() != 2 {
+		t.Fatalf("containers = %d, want 2", rt.count())
+	}
+	for _, info := range rt.containers {
+		if info.Labels[poolAccountLabel] != "acc-1" || info.Labels["agents-admin.node_id"] != "node-1" {
+			t.Errorf("labels = %v", info.Labels)
+		}
+	}
+
+	if _, ok := p.lease(ctx, "acc-other", "run-0"); ok {
+		t.Error("account without pool should not lease")
+	}
+	c1, ok1 := p.lease(ctx, "acc-1", "run-1")
+	c2, ok2 := p.lease(ctx, "acc-1", "run-2")
+	if !ok1 || !ok2 || c1 == c2 {
+		t.Fatalf("lease = %q %v, %q %v", c1, ok1, c2, ok2)
+	}
+	if _, ok := p.lease(ctx, "acc-1", "run-3"); ok {
+		t.Error("empty pool should miss")
+	}
+
+	// 补充后空闲数恢复到目标值，归还时空闲已满的容器被删除
+	p.fill(ctx)
+	p.release(ctx, c1)
+	if rt.count() != 3 {
+		t.Errorf("containers = %d, want 3 (2 idle + 1 leased)", rt.count())
+	}
+
+	st := p.status()
+	if len(st) != 1 {
+		t.Fatalf("status = %+v", st)
+	}
+	s := st[0]
+	if s.AccountID != "acc-1" || s.AgentType != "qwen" || s.Size != 2 || s.Idle != 2 || s.Leased != 1 ||
+		s.Leases != 2 || s.Misses != 1 || s.Discarded != 1 {
+		t.Errorf("status = %+v", s)
+	}
+}
+
+func TestInstancePool_ReleaseCleansWorkspace(t *testing.T) {
+	ctx := context.Background()
+	rt := newPoolRuntime()
+	p := newInstancePool([]PoolConfig{{AccountID: "acc-1", Size: 1}}, rt, "node-1", testPoolTemplate)
+	p.fill(ctx)
+
+	name, _ := p.lease(ctx, "acc-1", "run-1")
+	p.release(ctx, name)
+	if st := p.status()[0]; st.Idle != 1 || st.Recycled != 1 || len(rt.execs) != 1 {
+		t.Errorf("status = %+v, execs = %v", st, rt.execs)
+	}
+
+	// 清理失败的容器删除而不是放回池中
+	name, _ = p.lease(ctx, "acc-1", "run-2")
+	rt.cleanupErr = true
+	p.release(ctx, name)
+	if st := p.status()[0]; st.Idle != 0 || st.Discarded != 1 || rt.count() != 0 {
+		t.Errorf("status = %+v, containers = %d", st, rt.count())
+	}
+
+	// 空闲期间停止的容器不会被租出
+	rt.cleanupErr = false
+	p.fill(ctx)
+	for _, info := range rt.containers {
+		info.Running = false
+	}
+	if _, ok := p.lease(ctx, "acc-1", "run-3"); ok {
+		t.Error("stopped container should not be leased")
+	}
+}
+
+func TestInstancePool_Adopt(t *testing.T) {
+	ctx := context.Background()
+	rt := newPoolRuntime()
+	labels := func(account, node string) map[string]string {
+		return map[string]string{poolAccountLabel: account, "agents-admin.node_id": node}
+	}
+	rt.containers["agent_pool_a"] = &ContainerInfo{Running: true, Labels: labels("acc-1", "node-1")}
+	rt.containers["agent_pool_b"] = &ContainerInfo{Running: true, Labels: labels("acc-1", "node-1")}
+	rt.containers["agent_pool_c"] = &ContainerInfo{Running: true, Labels: labels("acc-gone", "node-1")}
+	rt.containers["agent_inst-1"] = &ContainerInfo{Running: true}
+
+	p := newInstancePool([]PoolConfig{{AccountID: "acc-1", Size: 1}}, rt, "node-1", testPoolTemplate)
+	p.adopt(ctx)
+	if st := p.status()[0]; st.Idle != 1 || st.Leased != 0 {
+		t.Errorf("status = %+v", st)
+	}
+	if _, ok := rt.containers["agent_pool_c"]; ok {
+		t.Error("container of unconfigured account should be removed")
+	}
+	if _, ok := rt.containers["agent_inst-1"]; !ok || rt.count() != 2 {
+		t.Errorf("containers = %v", rt.containers)
+	}
+}
+
+func TestInstancePool_AdoptKeepsReserved(t *testing.T) {
+	ctx := context.Background()
+	rt := newPoolRuntime()
+	labels := map[string]string{poolAccountLabel: "acc-1", "agents-admin.node_id": "node-1"}
+	rt.containers["agent_pool_a"] = &ContainerInfo{Running: true, Labels: labels}
+
+	p := newInstancePool([]PoolConfig{{AccountID: "acc-1", Size: 1}}, rt, "node-1", testPoolTemplate)
+	p.reserve("agent_pool_a", "run-1")
+	p.adopt(ctx)
+	if st := p.status()[0]; st.Idle != 0 || st.Leased != 1 || len(rt.execs) != 0 {
+		t.Fatalf("reserved container should stay leased without cleanup: status = %+v, execs = %v", st, rt.execs)
+	}
+
+	// 接管的 Run 结束后按常规归还
+	p.release(ctx, "agent_pool_a")
+	if st := p.status()[0]; st.Idle != 1 || st.Leased != 0 || len(rt.execs) != 1 {
+		t.Errorf("status = %+v, execs = %v", st, rt.execs)
+	}
+}
@@ -0,0 +1,154 @@
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"agents-admin/internal/tlsutil"
+)
+
+// 凭证目录中的文件
+const (
+	credentialNodeIDFile = "node-id"
+	credentialTokenFile  = "node-token"
+	credentialCertFile   = "node.pem"
+	credentialKeyFile    = "node-key.pem"
+	credentialCAFile     = "ca.pem"
+)
+
+// NodeCredential 节点专属凭证（首次启动时以加入令牌换取，保存在凭证目录）
+type NodeCredential struct {
+	NodeID   string
+	Token    string // 专属 X-Node-Token
+	CertFile string // 客户端证书（API Server 未启用 CA 签发时为空）
+	KeyFile  string
+	CAFile   string // 签发 CA，可用于校验 API Server 证书
+}
+
+// DefaultCredentialDir 默认凭证目录（用户配置目录下的 agents-admin/node）
+func DefaultCredentialDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "/etc/agents-admin/node"
+	}
+	return filepath.Join(dir, "agents-admin", "node")
+}
+
+// LoadOrJoin 加载已保存的节点凭证，不存在时以加入令牌向 API Server 兑换
+//
+// 既没有已保存凭证也没有加入令牌时返回 nil（继续使用共享 NODE_TOKEN）。
+// 已保存的凭证属于其他 Node ID 时视为不存在。
+func LoadOrJoin(ctx context.Context, client *http.Client, apiServerURL, nodeID, joinToken, dir string) (*NodeCredential, error) {
+	if cred := loadCredential(dir, nodeID); cred != nil {
+		return cred, nil
+	}
+	if joinToken == "" {
+		return nil, nil
+	}
+	return join(ctx, client, apiServerURL, nodeID, joinToken, dir)
+}
+
+func loadCredential(dir, nodeID string) *NodeCredential {
+	id, err := os.ReadFile(filepath.Join(dir, credentialNodeIDFile))
+	if err != nil || strings.TrimSpace(string(id)) != nodeID {
+		return nil
+	}
+	token, err := os.ReadFile(filepath.Join(dir, credentialTokenFile))
+	if err != nil || len(bytes.TrimSpace(token)) == 0 {
+		return nil
+	}
+	cred := &NodeCredential{NodeID: nodeID, Token: strings.TrimSpace(string(token))}
+	certFile, keyFile := filepath.Join(dir, credentialCertFile), filepath.Join(dir, credentialKeyFile)
+	if fileExists(certFile) && fileExists(keyFile) {
+		cred.CertFile, cred.KeyFile = certFile, keyFile
+	}
+	if caFile := filepath.Join(dir, credentialCAFile); fileExists(caFile) {
+		cred.CAFile = caFile
+	}
+	return cred
+}
+
+// joinResponse POST /api/v1/nodes/join 响应
+type joinResponse struct {
+	NodeToken     string `json:"node_token"`
+	Certificate   string `json:"certificate"`
+	CACertificate string `json:"ca_certificate"`
+}
+
+func join(ctx context.Context, client *http.Client, apiServerURL, nodeID, joinToken, dir string) (*NodeCredential, error) {
+	csrPEM, keyPEM, err := tlsutil.NewClientCSR(nodeID)
+	if err != nil {
+		return nil, err
+	}
+	hostname, _ := os.Hostname()
+	body, _ := json.Marshal(map[string]string{"token": joinToken, "node_id": nodeID, "hostname": hostname, "csr": string(csrPEM)})
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(apiServerURL, "/")+"/api/v1/nodes/join", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("join request: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("join rejected: status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var jr joinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&jr); err != nil {
+		return nil, fmt.Errorf("decode join response: %w", err)
+	}
+	if jr.NodeToken == "" {
+		return nil, fmt.Errorf("join response missing node_token")
+	}
+
+	// 凭证等同于节点身份，目录与文件只允许本用户读取
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("create credential dir: %w", err)
+	}
+	files := map[string]string{credentialTokenFile: jr.NodeToken}
+	if jr.Certificate != "" {
+		files[credentialCertFile] = jr.Certificate
+		files[credentialKeyFile] = string(keyPEM)
+	}
+	if jr.CACertificate != "" {
+		files[credentialCAFile] = jr.CACertificate
+	}
+	for _, name := range []string{credentialNodeIDFile, credentialCertFile, credentialKeyFile, credentialCAFile} {
+		os.Remove(filepath.Join(dir, name)) // 清理上一次加入留下的凭证
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0600); err != nil {
+			return nil, fmt.Errorf("write %s: %w", name, err)
+		}
+	}
+	// node-id 最后写入：中途失败时不会留下不完整但可加载的凭证
+	if err := os.WriteFile(filepath.Join(dir, credentialNodeIDFile), []byte(nodeID), 0600); err != nil {
+		return nil, fmt.Errorf("write %s: %w", credentialNodeIDFile, err)
+	}
+
+	log.Printf("[node.join.success] node_id=%s dir=%s cert=%t", nodeID, dir, jr.Certificate != "")
+	return loadCredential(dir, nodeID), nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
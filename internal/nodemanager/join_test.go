@@ -0,0 +1,62 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadOrJoin(t *testing.T) {
+	var joins int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/nodes/join" {
+			http.NotFound(w, r)
+			return
+		}
+		var req map[string]string
+		json.NewDecoder(r.Body).Decode(&req)
+		if req["token"] != "join-token" || req["csr"] == "" {
+			http.Error(w, `{"error":"invalid"}`, http.StatusUnauthorized)
+			return
+		}
+		joins++
+		json.NewEncoder(w).Encode(map[string]string{
+			"node_id": req["node_id"], "node_token": "node-token-" + req["node_id"],
+			"certificate": "CERT", "ca_certificate": "CA",
+		})
+	}))
+	defer srv.Close()
+	dir := filepath.Join(t.TempDir(), "cred")
+	ctx := context.Background()
+
+	// 无已保存凭证、无加入令牌：继续使用共享密钥
+	if cred, err := LoadOrJoin(ctx, nil, srv.URL, "node-1", "", dir); err != nil || cred != nil {
+		t.Fatalf("expected nil credential, got %+v, %v", cred, err)
+	}
+
+	cred, err := LoadOrJoin(ctx, nil, srv.URL, "node-1", "join-token", dir)
+	if err != nil {
+		t.Fatalf("join failed: %v", err)
+	}
+	if cred.Token != "node-token-node-1" || cred.CertFile == "" || cred.KeyFile == "" || cred.CAFile == "" {
+		t.Fatalf("unexpected credential: %+v", cred)
+	}
+	if info, err := os.Stat(cred.KeyFile); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("key file mode = %v, %v", info.Mode(), err)
+	}
+
+	// 再次启动直接加载已保存凭证（加入令牌已失效也不影响）
+	cred, err = LoadOrJoin(ctx, nil, srv.URL, "node-1", "used-token", dir)
+	if err != nil || cred.Token != "node-token-node-1" || joins != 1 {
+		t.Fatalf("expected saved credential, got %+v, %v (joins=%d)", cred, err, joins)
+	}
+
+	// 凭证属于其他节点时重新加入
+	if _, err := LoadOrJoin(ctx, nil, srv.URL, "node-2", "bad-token", dir); err == nil {
+		t.Error("expected join error for rejected token")
+	}
+}
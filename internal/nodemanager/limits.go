@@ -0,0 +1,235 @@
+// Package nodemanager Run 资源限制
+//
+// 任务 security.limits 随执行快照下发（snapshot.limits），NodeManager 在执行前与节点容量比较，
+// CPU 核数或内存超出容量时拒绝执行并上报 resource_limit 事件（action=rejected）。
+//
+// 执行时按后端换算：
+//   - docker：Run 期间通过容器运行时 update 限制实例容器的 CPU、内存（不使用交换区）与进程数，
+//     Run 结束后恢复为节点容量；打开文件数通过 ulimit -n 作用于 exec 的命令
+//   - process：内存（地址空间）、进程数与打开文件数并入 prlimit，取与节点配置的较小值；CPU 核数无法限制
+//
+// 磁盘与网络带宽限制暂不执行，与后端无法执行的限制一起在 run_started 事件中列为 unenforced。
+// Agent 被 SIGKILL 终止且设置了内存限制时视为触及内存限制，上报 resource_limit 事件（action=exceeded）。
+package nodemanager
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// limitRestoreTimeout Run 结束后恢复容器资源限制的超时（Run 的 context 可能已取消）
+const limitRestoreTimeout = 30 * time.Second
+
+// RunLimits 解析后的 Run 资源限制（0 表示不限制）
+type RunLimits struct {
+	Spec        model.ResourceLimits // 任务中的原始配置（用于事件上报）
+	CPUs        float64
+	MemoryBytes int64
+	Processes   int64
+	OpenFiles   int64
+}
+
+// ParseRunLimits 从任务快照中解析资源限制（snapshot.limits），未配置时返回 nil
+func ParseRunLimits(snapshot *model.RunSnapshot) (*RunLimits, error) {
+	if snapshot.Limits == nil {
+		return nil, nil
+	}
+	spec := *snapshot.Limits
+	if err := spec.Validate(); err != nil {
+		return nil, fmt.Errorf("资源限制格式错误: %v", err)
+	}
+	if spec == (model.ResourceLimits{}) {
+		return nil, nil
+	}
+
+	l := &RunLimits{Spec: spec, Processes: int64(spec.MaxProcesses), OpenFiles: int64(spec.MaxOpenFiles)}
+	if spec.MaxCPU != "" {
+		l.CPUs, _ = model.ParseCPUQuantity(spec.MaxCPU)
+	}
+	if spec.MaxMemory != "" {
+		l.MemoryBytes, _ = model.ParseByteQuantity(spec.MaxMemory)
+	}
+	return l, nil
+}
+
+// nodeCapacity 节点容量（0 表示未知）
+type nodeCapacity struct {
+	CPUs        int
+	MemoryBytes int64
+}
+
+// detectNodeCapacity 检测节点 CPU 核数与物理内存（内存读取 /proc/meminfo，非 Linux 平台为未知）
+func detectNodeCapacity() nodeCapacity {
+	c := nodeCapacity{CPUs: runtime.NumCPU()}
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return c
+	}
+	defer f.Close()
+	c.MemoryBytes = parseMemTotal(bufio.NewScanner(f))
+	return c
+}
+
+// parseMemTotal 从 /proc/meminfo 内容中解析 MemTotal（单位 kB）
+func parseMemTotal(sc *bufio.Scanner) int64 {
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.ParseInt(fields[1], 10, 64)
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// rejection 检查资源限制是否超出节点容量，超出时返回 resource_limit 事件内容
+func (l *RunLimits) rejection(c nodeCapacity) map[string]interface{} {
+	if l == nil {
+		return nil
+	}
+	if c.CPUs > 0 && l.CPUs > float64(c.CPUs) {
+		return map[string]interface{}{"action": "rejected", "resource": "cpu", "limit": l.Spec.MaxCPU, "capacity": strconv.Itoa(c.CPUs)}
+	}
+	if c.MemoryBytes > 0 && l.MemoryBytes > c.MemoryBytes {
+		return map[string]interface{}{"action": "rejected", "resource": "memory", "limit": l.Spec.MaxMemory, "capacity": fmt.Sprintf("%dMi", c.MemoryBytes>>20)}
+	}
+	return nil
+}
+
+// exceeded 判断 Agent 是否因触及内存限制被终止（SIGKILL，docker exec 表现为退出码 137），
+// 是时返回 resource_limit 事件内容；Run 被取消或超时终止时由调用方排除
+func (l *RunLimits) exceeded(err error) map[string]interface{} {
+	if l == nil || l.MemoryBytes == 0 {
+		return nil
+	}
+	// 脱离 NodeManager 运行的 Agent 只有记录的退出码（见 handover.go）
+	var exited interface{ ExitCode() int }
+	if !errors.As(err, &exited) {
+		return nil
+	}
+	killed := exited.ExitCode() == 137
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		if ws, ok := exitErr.Sys().(syscall.WaitStatus); ok && ws.Signaled() && ws.Signal() == syscall.SIGKILL {
+			killed = true
+		}
+	}
+	if !killed {
+		return nil
+	}
+	return map[string]interface{}{"action": "exceeded", "resource": "memory", "limit": l.Spec.MaxMemory, "exit_code": exited.ExitCode()}
+}
+
+// describe run_started 事件中的资源限制描述
+func (l *RunLimits) describe(unenforced []string) map[string]interface{} {
+	desc := map[string]interface{}{}
+	data, _ := json.Marshal(l.Spec)
+	json.Unmarshal(data, &desc)
+	if len(unenforced) > 0 {
+		desc["unenforced"] = unenforced
+	}
+	return desc
+}
+
+// unsupported 所有后端都不执行的限制
+func (l *RunLimits) unsupported() []string {
+	var names []string
+	if l.Spec.MaxDisk != "" {
+		names = append(names, "max_disk")
+	}
+	if l.Spec.MaxNetwork != "" {
+		names = append(names, "max_network")
+	}
+	return names
+}
+
+// ============================================================================
+// docker 后端
+// ============================================================================
+
+// applyLimits Run 期间限制实例容器的 CPU、内存与进程数，返回的 restore 将其恢复为节点容量
+func (t *dockerTarget) applyLimits(ctx context.Context, l *RunLimits) ([]string, func(), error) {
+	unenforced := l.unsupported()
+	res := ContainerResources{CPUs: l.CPUs, PidsLimit: l.Processes}
+	reset := ContainerResources{}
+	if l.CPUs > 0 {
+		reset.CPUs = float64(t.capacity.CPUs)
+	}
+	if l.MemoryBytes > 0 {
+		if t.capacity.MemoryBytes > 0 {
+			// 不使用交换区，否则内存限制形同虚设
+			res.MemoryBytes, res.MemorySwap = l.MemoryBytes, l.MemoryBytes
+			reset.MemoryBytes, reset.MemorySwap = t.capacity.MemoryBytes, -1
+		} else {
+			// 节点内存未知时无法恢复，不限制
+			log.Printf("[Limits] 节点内存容量未知，不限制容器 %s 的内存", t.container)
+			unenforced = append(unenforced, "max_memory")
+		}
+	}
+	if l.Processes > 0 {
+		reset.PidsLimit = -1
+	}
+	t.openFiles = l.OpenFiles
+
+	if err := t.rt.Update(ctx, t.container, res); err != nil {
+		return nil, nil, err
+	}
+	restore := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), limitRestoreTimeout)
+		defer cancel()
+		if err := t.rt.Update(ctx, t.container, reset); err != nil {
+			log.Printf("[Limits] 恢复容器 %s 资源限制失败: %v", t.container, err)
+		}
+	}
+	return unenforced, restore, nil
+}
+
+// ulimitArgv 设置打开文件数后执行 argv（docker exec 不支持 --ulimit）
+func ulimitArgv(openFiles int64, argv []string) []string {
+	script := "ulimit -n " + strconv.FormatInt(openFiles, 10) + ` && exec "$@"`
+	return append([]string{"sh", "-c", script, "sh"}, argv...)
+}
+
+// ============================================================================
+// process 后端
+// ============================================================================
+
+// applyLimits 将内存、进程数与打开文件数并入 prlimit（取与节点配置的较小值）
+func (t *processTarget) applyLimits(ctx context.Context, l *RunLimits) ([]string, func(), error) {
+	unenforced := l.unsupported()
+	if l.CPUs > 0 {
+		unenforced = append(unenforced, "max_cpu")
+	}
+	limits := t.proc.cfg.Limits
+	limits.MemoryBytes = tightenLimit(limits.MemoryBytes, l.MemoryBytes)
+	limits.Processes = tightenLimit(limits.Processes, l.Processes)
+	limits.OpenFiles = tightenLimit(limits.OpenFiles, l.OpenFiles)
+	if len(limits.prlimitArgs()) > 0 {
+		if _, err := exec.LookPath("prlimit"); err != nil {
+			return nil, nil, fmt.Errorf("找不到 prlimit: %w", err)
+		}
+	}
+	t.limits = &limits
+	return unenforced, func() {}, nil
+}
+
+// tightenLimit 取两个限制中较严格的一个（0 表示不限制）
+func tightenLimit(current, v int64) int64 {
+	if v > 0 && (current == 0 || v < current) {
+		return v
+	}
+	return current
+}
@@ -0,0 +1,162 @@
+package nodemanager
+
+import (
+	"bufio"
+	"context"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+// recordingRuntime 记录 Update 调用的容器运行时
+type recordingRuntime struct {
+	ContainerRuntime
+	updates []ContainerResources
+}
+
+func (r *recordingRuntime) Update(_ context.Context, _ string, res ContainerResources) error {
+	r.updates = append(r.updates, res)
+	return nil
+}
+
+func TestParseRunLimits(t *testing.T) {
+	l, err := ParseRunLimits(&model.RunSnapshot{})
+	if err != nil || l != nil {
+		t.Fatalf("no limits: got %+v, %v", l, err)
+	}
+	if l, _ := ParseRunLimits(&model.RunSnapshot{Limits: &model.ResourceLimits{}}); l != nil {
+		t.Errorf("empty limits should be nil, got %+v", l)
+	}
+	if _, err := ParseRunLimits(&model.RunSnapshot{Limits: &model.ResourceLimits{MaxMemory: "lots"}}); err == nil {
+		t.Error("invalid quantity should be rejected")
+	}
+
+	l, err = ParseRunLimits(&model.RunSnapshot{Limits: &model.ResourceLimits{
+		MaxCPU: "1.5", MaxMemory: "2Gi", MaxProcesses: 128, MaxOpenFiles: 1024, MaxDisk: "10Gi",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.CPUs != 1.5 || l.MemoryBytes != 2<<30 || l.Processes != 128 || l.OpenFiles != 1024 {
+		t.Errorf("limits = %+v", l)
+	}
+	if got := l.unsupported(); !slices.Equal(got, []string{"max_disk"}) {
+		t.Errorf("unsupported = %v", got)
+	}
+}
+
+func TestRunLimitsRejection(t *testing.T) {
+	capacity := nodeCapacity{CPUs: 4, MemoryBytes: 8 << 30}
+	var none *RunLimits
+	if none.rejection(capacity) != nil {
+		t.Error("nil limits are never rejected")
+	}
+	if got := (&RunLimits{CPUs: 4, MemoryBytes: 8 << 30}).rejection(capacity); got != nil {
+		t.Errorf("limits equal to capacity should pass, got %v", got)
+	}
+	if got := (&RunLimits{CPUs: 8}).rejection(capacity); got["resource"] != "cpu" || got["capacity"] != "4" {
+		t.Errorf("cpu rejection = %v", got)
+	}
+	if got := (&RunLimits{MemoryBytes: 16 << 30}).rejection(capacity); got["resource"] != "memory" || got["capacity"] != "8192Mi" {
+		t.Errorf("memory rejection = %v", got)
+	}
+	if got := (&RunLimits{MemoryBytes: 16 << 30}).rejection(nodeCapacity{CPUs: 4}); got != nil {
+		t.Errorf("unknown memory capacity should not reject, got %v", got)
+	}
+}
+
+func TestParseMemTotal(t *testing.T) {
+	meminfo := "MemTotal:       16318460 kB\nMemFree:         1234 kB\n"
+	if got := parseMemTotal(bufio.NewScanner(strings.NewReader(meminfo))); got != 16318460*1024 {
+		t.Errorf("MemTotal = %d", got)
+	}
+}
+
+func TestDockerTargetApplyLimits(t *testing.T) {
+	rt := &recordingRuntime{ContainerRuntime: defaultContainerRuntime}
+	target := &dockerTarget{rt: rt, container: "agent-1", capacity: nodeCapacity{CPUs: 8, MemoryBytes: 16 << 30}}
+
+	unenforced, restore, err := target.applyLimits(context.Background(), &RunLimits{CPUs: 2, MemoryBytes: 4 << 30, Processes: 256, OpenFiles: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unenforced) != 0 {
+		t.Errorf("unenforced = %v", unenforced)
+	}
+	restore()
+
+	want := []ContainerResources{
+		{CPUs: 2, MemoryBytes: 4 << 30, MemorySwap: 4 << 30, PidsLimit: 256},
+		{CPUs: 8, MemoryBytes: 16 << 30, MemorySwap: -1, PidsLimit: -1},
+	}
+	if !slices.Equal(rt.updates, want) {
+		t.Errorf("updates = %+v, want %+v", rt.updates, want)
+	}
+	if got := updateArgs(want[0]); !slices.Equal(got, []string{"update", "--cpus", "2", "--memory", "4294967296", "--memory-swap", "4294967296", "--pids-limit", "256"}) {
+		t.Errorf("update args = %v", got)
+	}
+
+	cmd := target.command(context.Background(), []string{"qwen", "-p", "hi"}, nil)
+	want2 := []string{"docker", "exec", "agent-1", "sh", "-c", `ulimit -n 1024 && exec "$@"`, "sh", "qwen", "-p", "hi"}
+	if !slices.Equal(cmd.Args, want2) {
+		t.Errorf("args = %v, want %v", cmd.Args, want2)
+	}
+}
+
+func TestDockerTargetApplyLimits_UnknownMemory(t *testing.T) {
+	rt := &recordingRuntime{ContainerRuntime: defaultContainerRuntime}
+	target := &dockerTarget{rt: rt, container: "agent-1", capacity: nodeCapacity{CPUs: 8}}
+	unenforced, _, err := target.applyLimits(context.Background(), &RunLimits{MemoryBytes: 4 << 30})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(unenforced, []string{"max_memory"}) || rt.updates[0].MemoryBytes != 0 {
+		t.Errorf("memory limit must not be applied when it cannot be restored: unenforced=%v updates=%+v", unenforced, rt.updates)
+	}
+}
+
+func TestProcessTargetApplyLimits(t *testing.T) {
+	if _, err := exec.LookPath("prlimit"); err != nil {
+		t.Skip("prlimit not installed")
+	}
+	target := &processTarget{proc: &processBackend{cfg: ProcessConfig{Limits: ProcessLimits{OpenFiles: 256, Processes: 64}}}, dir: t.TempDir()}
+	unenforced, _, err := target.applyLimits(context.Background(), &RunLimits{CPUs: 1, MemoryBytes: 1 << 30, OpenFiles: 1024, Processes: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(unenforced, []string{"max_cpu"}) {
+		t.Errorf("unenforced = %v", unenforced)
+	}
+	want := ProcessLimits{MemoryBytes: 1 << 30, OpenFiles: 256, Processes: 32}
+	if *target.limits != want {
+		t.Errorf("limits = %+v, want %+v", *target.limits, want)
+	}
+}
+
+func TestRunLimitsExceeded(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	killed := exec.Command("sh", "-c", "kill -9 $$").Run()
+	failed := exec.Command("sh", "-c", "exit 1").Run()
+	oom := exec.Command("sh", "-c", "exit 137").Run()
+
+	l := &RunLimits{MemoryBytes: 1 << 30}
+	l.Spec.MaxMemory = "1Gi"
+	if got := l.exceeded(killed); got["resource"] != "memory" || got["limit"] != "1Gi" {
+		t.Errorf("SIGKILL: %v", got)
+	}
+	if got := l.exceeded(oom); got == nil || got["exit_code"] != 137 {
+		t.Errorf("exit 137: %v", got)
+	}
+	if got := l.exceeded(failed); got != nil {
+		t.Errorf("ordinary failure: %v", got)
+	}
+	if got := (&RunLimits{CPUs: 1}).exceeded(killed); got != nil {
+		t.Errorf("no memory limit: %v", got)
+	}
+}
@@ -7,7 +7,13 @@
 //   - container_instance.go:  Instance 容器管理
 //   - container_terminal.go:  Terminal 终端管理
 //   - workspace_manager.go:   工作空间管理
+//   - workspace_sync.go:      Git 结果回写（提交/推送/PR）
+//   - backend.go:             执行后端（docker / process）
+//   - backend_process.go:     process 执行后端（宿主机进程 + 沙箱用户 + rlimit）
+//   - handover.go:            运行中 Run 的重启交接（Agent 后台运行，重启后接管）
 //   - heartbeat_service.go:   心跳服务
+//   - heartbeat_delta.go:     心跳协议 v2（增量心跳与服务端下发的间隔）
+//   - grpc_client.go:         gRPC 通信客户端（transport: grpc）
 //   - metrics_prometheus.go:  Prometheus 指标
 //   - handler/:               Handler 插件框架
 //   - interface.go:         Handler 接口
@@ -21,6 +27,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -28,13 +35,15 @@ import (
 	"net"
 	"net/http"
 	"os"
-	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	nodev1 "agents-admin/api/generated/proto/nodev1"
 	"agents-admin/internal/nodemanager/adapter"
 	"agents-admin/internal/nodemanager/handler"
+	"agents-admin/internal/shared/model"
 )
 
 // Config 节点管理器配置
@@ -46,6 +55,21 @@ type Config struct {
 	Labels       map[string]string // 节点标签（用于调度匹配）
 	HTTPClient   *http.Client      // 自定义 HTTP 客户端（可选，用于 TLS）
 	NodeToken    string            // 共享密钥（X-Node-Token 认证）
+	GitToken     string            // Git 结果回写使用的 HTTPS token（可选，只从 GIT_TOKEN 环境变量读取）
+	ExecBackend  string            // 默认执行后端（docker/process，为空时为 docker）
+	Process      ProcessConfig     // process 执行后端配置
+	Containers   ContainerRuntime  // 容器运行时（为空时使用 docker CLI）
+	Events       EventReportConfig // 事件批量上报配置
+	Pools        []PoolConfig      // 预热实例池（docker 执行后端按账号租用预热容器）
+	ProxyProbe   ProxyProbeConfig  // 代理健康探测（认证流程与 Agent 执行据此切换代理）
+	Transport    string            // 与 API Server 的通信方式（rest/grpc，为空时为 rest）
+	GRPCAddr     string            // gRPC 模式下 API Server 节点 gRPC 接口地址（host:port）
+	GRPCTLS      *tls.Config       // gRPC 模式的 TLS 配置（为 nil 时使用明文连接）
+	Handover     HandoverConfig    // 重启交接（Agent 脱离 NodeManager 运行，重启后接管，见 handover.go）
+	CancelGrace  time.Duration     // 取消或超时终止 Run 时等待 Agent 自行退出的宽限期（见 cancellation.go，0 使用默认值）
+
+	// Concurrency 执行并发配置（控制面可通过心跳响应覆盖）
+	Concurrency model.NodeConcurrency
 }
 
 // NodeManager 节点管理器核心结构
@@ -57,12 +81,39 @@ type NodeManager struct {
 	config           Config                        // 配置
 	httpClient       *http.Client                  // HTTP 客户端
 	adapters         *adapter.Registry             // Adapter 注册表
-	mu               sync.Mutex                    // 保护 running map
+	adapterInfo      []model.AdapterInfo           // 适配器版本与能力探测结果（随心跳上报）
+	mu               sync.Mutex                    // 保护 running / maskers / timedOut / seqBase / approvals / feedbacks / pausers / targets map 与 adapterInfo
 	running          map[string]context.CancelFunc // 运行中的任务
+	maskers          map[string]*secretMasker      // 运行中任务的密钥脱敏器
+	timedOut         map[string]bool               // 被 API Server 判定超时而终止的任务
+	seqBase          map[string]int                // 任务已入库事件的最大节点序号（被回收后重新分配的任务从其后继续编号）
+	approvals        map[string]chan string        // 等待中的人工审批（approval_id → 审批结果）
+	feedbacks        map[string]*feedbackChannel   // 运行中任务的人工反馈控制通道
+	pausers          map[string]*runPauser         // 运行中任务的暂停控制（Agent 命令启动后登记）
+	targets          map[string]execTarget         // 运行中任务的执行目标（隧道文件获取使用）
+	logs             runLogHub                     // 运行中任务的实时输出订阅（隧道 logs 流）
+	nodeLogs         nodeLogBuffer                 // 待上报的节点日志（见 node_log.go）
 	authController   *AuthControllerV2             // 认证任务控制器
 	agentWorker      *AgentWorker                  // Agent 工作线程（P2-1）
+	pool             *instancePool                 // 预热实例池（未配置时为 nil）
+	sessions         *sessionCache                 // 持久会话保留的执行容器（见 session.go）
 	terminalWorker   *TerminalWorker               // Terminal 工作线程（P2-1）
 	workspaceManager *WorkspaceManager             // Workspace 管理器
+	apiCache         *apiCache                     // API 资源缓存（ETag 条件请求）
+	process          *processBackend               // process 执行后端（未启用时为 nil）
+	capacity         nodeCapacity                  // 节点容量（校验与恢复 Run 资源限制）
+	events           *eventReporter                // 事件批量上报（为 nil 时逐条同步上报）
+	grpc             *grpcClient                   // gRPC 通信客户端（REST 模式为 nil）
+	proxies          *proxyProber                  // 代理列表与本节点探测结果
+	metrics          *metricsCollector             // 系统资源指标采集（随心跳上报）
+	handover         *handover                     // 运行中 Run 的重启交接（未启用时为 nil）
+	heartbeat        heartbeatState                // 增量心跳状态（见 heartbeat_delta.go）
+
+	// 执行并发控制（由 mu 保护，见 concurrency.go）
+	slots            map[string]runSlot             // 运行中任务占用的执行槽位（优先级与 Agent 类型）
+	waiting          map[string]*waitingRun         // 因并发限制暂未启动的任务
+	waitSeq          int64                          // 等待队列的先后顺序
+	capacityOverride *model.NodeConcurrencyOverride // 控制面下发的并发配置覆盖
 
 	// 新架构：Handler 注册表
 	handlerRegistry *handler.Registry
@@ -90,22 +141,76 @@ func NewNodeManager(cfg Config) (*NodeManager, error) {
 		cfg.HTTPClient = httpClient
 	}
 
-	authController, err := NewAuthControllerV2(cfg)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create auth controller: %w", err)
+	// 认证流程通过 Docker 兼容 API 操作容器，运行时不提供时不启用
+	var authController *AuthControllerV2
+	if cfg.containers().APISocket() != "" {
+		var err error
+		authController, err = NewAuthControllerV2(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create auth controller: %w", err)
+		}
+	} else {
+		log.Printf("[ContainerRuntime] %s 不提供 Docker 兼容 API，账号认证任务不可用", cfg.containers().Name())
 	}
 
-	return &NodeManager{
+	nm := &NodeManager{
 		config:           cfg,
 		httpClient:       httpClient,
 		adapters:         adapter.NewRegistry(),
 		running:          make(map[string]context.CancelFunc),
+		apiCache:         newAPICache(defaultAPICacheEntries),
 		authController:   authController,
 		agentWorker:      NewAgentWorker(cfg),                   // P2-1: Agent 工作线程
 		terminalWorker:   NewTerminalWorker(cfg),                // P2-1: Terminal 工作线程
 		workspaceManager: NewWorkspaceManager(cfg.WorkspaceDir), // Workspace 管理器
 		handlerRegistry:  handler.NewRegistry(),                 // 新架构：Handler 注册表
-	}, nil
+		capacity:         detectNodeCapacity(),
+		proxies:          newProxyProber(cfg.ProxyProbe),
+		metrics:          newMetricsCollector(cfg.WorkspaceDir),
+	}
+	if authController != nil {
+		authController.proxies = nm.proxies // 认证容器按探测结果选择代理
+	}
+	nm.workspaceManager.SetCredentialResolver(nm.resolveCredential) // credential_ref 解析
+	nm.pool = newInstancePool(cfg.Pools, cfg.containers(), cfg.NodeID, nm.agentWorker.poolTemplate)
+	nm.sessions = newSessionCache(cfg.containers(), nm.pool)
+	nm.workspaceManager.SetContainerRuntime(cfg.containers())
+
+	// 事件批量上报：本地缓存默认位于工作空间目录下
+	eventsCfg := cfg.Events
+	if eventsCfg.SpoolDir == "" && cfg.WorkspaceDir != "" {
+		eventsCfg.SpoolDir = filepath.Join(cfg.WorkspaceDir, ".event-spool")
+	}
+	nm.events = newEventReporter(eventsCfg, nm.postEvents)
+	nm.events.sendRecord = nm.sendSpoolRecord
+
+	if cfg.Transport == TransportGRPC {
+		var err error
+		nm.grpc, err = newGRPCClient(cfg.GRPCAddr, cfg.GRPCTLS, cfg.NodeToken)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create grpc client: %w", err)
+		}
+		log.Printf("[nodemanager] grpc transport: %s", cfg.GRPCAddr)
+	}
+
+	if cfg.Process.Enabled || cfg.ExecBackend == model.ExecBackendProcess {
+		var err error
+		nm.process, err = newProcessBackend(cfg.Process, cfg.WorkspaceDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create process backend: %w", err)
+		}
+		log.Printf("[ExecBackend] process 执行后端已启用 (user=%s)", cfg.Process.User)
+	}
+
+	if cfg.Handover.Enabled {
+		var err error
+		nm.handover, err = newHandover(cfg.Handover, cfg.WorkspaceDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create run handover: %w", err)
+		}
+		log.Printf("[nodemanager] run handover enabled: %s", nm.handover.dir)
+	}
+	return nm, nil
 }
 
 // RegisterHandler 注册 Handler（新架构）
@@ -134,6 +239,14 @@ func (nm *NodeManager) Start(ctx context.Context) {
 		log.Printf("[nodemanager] registered handlers: %v", nm.handlerRegistry.List())
 	}
 
+	// 重启交接：首次心跳前接管上次运行留下的 Run，避免被 API Server 判定为孤儿
+	if nm.handover != nil {
+		nm.recoverRuns(ctx)
+	}
+
+	// 首次心跳前完成适配器探测，调度器据此按能力过滤节点
+	nm.detectAdapters(ctx)
+
 	var wg sync.WaitGroup
 
 	wg.Add(1)
@@ -142,60 +255,88 @@ func (nm *NodeManager) Start(ctx context.Context) {
 		nm.heartbeatLoop(ctx)
 	}()
 
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		nm.adapterDetectLoop(ctx)
+	}()
+
 	wg.Add(1)
 	go func() {
 		defer wg.Done()
 		nm.taskLoop(ctx)
 	}()
 
-	// 认证任务控制循环
-	if nm.authController != nil {
+	// 回放 API Server 不可达期间缓存到本地的事件
+	if nm.events != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			nm.authController.Start(ctx)
+			nm.events.replayLoop(ctx)
 		}()
 	}
 
-	// P2-1: Agent 工作线程（处理容器创建/启动/停止）
-	if nm.agentWorker != nil {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			nm.agentWorker.Start(ctx)
-		}()
-	}
+	// 代理健康探测
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		nm.proxyProbeLoop(ctx)
+	}()
+
+	// 期望状态同步：按 API Server 计算的期望状态对齐实例容器、认证任务与终端
+	// （旧版 API Server 不支持时回退到认证控制器、Agent 工作线程与 Terminal 工作线程各自轮询）
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		nm.desiredStateLoop(ctx)
+	}()
 
-	// P2-1: Terminal 工作线程（处理终端会话启动/关闭）
-	if nm.terminalWorker != nil {
+	// 预热实例池：保持空闲容器，docker 后端的 Run 启动时直接租用
+	if nm.pool != nil {
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			nm.terminalWorker.Start(ctx)
+			nm.pool.run(ctx)
 		}()
 	}
 
+	// 反向隧道：API Server 经其访问本节点的终端、Run 文件与实时输出
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		nm.tunnelLoop(ctx)
+	}()
+
 	// 新架构：启动所有注册的 Handler
 	if nm.handlerRegistry != nil {
 		nm.handlerRegistry.StartAll(ctx, &wg)
 	}
 
 	wg.Wait()
+	// 等待运行中的 Run 写入交接检查点并将已解析的事件加入上报队列
+	if nm.handover != nil {
+		nm.handover.wait(handoverWaitTimeout)
+	}
+	if nm.events != nil {
+		nm.events.close()
+	}
+	if nm.grpc != nil {
+		nm.grpc.close()
+	}
 	log.Println("[nodemanager] stopped")
 }
 
+// heartbeatLoop 心跳主循环（间隔由 API Server 在 v2 心跳响应中下发，见 heartbeat_delta.go）
 func (nm *NodeManager) heartbeatLoop(ctx context.Context) {
-	ticker := time.NewTicker(10 * time.Second)
-	defer ticker.Stop()
-
-	nm.sendHeartbeat(ctx)
-
 	for {
+		nm.sendHeartbeat(ctx)
+
+		timer := time.NewTimer(nm.heartbeat.nextInterval())
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return
-		case <-ticker.C:
-			nm.sendHeartbeat(ctx)
+		case <-timer.C:
 		}
 	}
 }
@@ -208,21 +349,102 @@ func (nm *NodeManager) sendHeartbeat(ctx context.Context) {
 		runningRuns = append(runningRuns, runID)
 	}
 	nm.mu.Unlock()
+	pausedRuns := nm.pausedRuns()
 
 	hostname, _ := os.Hostname()
 	ips := getLocalIPs()
+	concurrency := nm.concurrency()
+	available := concurrency.MaxConcurrent - len(runningRuns) + len(pausedRuns) // 暂停中的 Run 不占用执行槽位
+	var metrics *model.NodeMetrics
+	if nm.metrics != nil {
+		metrics = nm.metrics.collect()
+	}
+	spool := nm.events.spoolStatus()
+
+	if nm.grpc != nil {
+		hbResp, err := nm.grpc.heartbeat(ctx, &nodev1.HeartbeatRequest{
+			NodeId:      nm.config.NodeID,
+			Status:      "online",
+			Hostname:    hostname,
+			Ips:         strings.Join(ips, ","),
+			Labels:      nm.config.Labels,
+			RunningRuns: runningRuns,
+			Capacity: &nodev1.Capacity{
+				MaxConcurrent:        int32(concurrency.MaxConcurrent),
+				Available:            int32(available),
+				Cpus:                 int32(nm.capacity.CPUs),
+				MemoryBytes:          nm.capacity.MemoryBytes,
+				Adapters:             adaptersToProto(nm.adapterInfos()),
+				Pools:                poolsToProto(nm.poolStatus()),
+				Metrics:              metricsToProto(metrics),
+				ReservedHighPriority: int32(concurrency.ReservedHighPriority),
+				AgentTypeLimits:      agentTypeLimitsToProto(concurrency.AgentTypeLimits),
+				Spool:                spoolToProto(spool),
+			},
+			PausedRuns:       pausedRuns,
+			PendingApprovals: nm.pendingApprovals(),
+		})
+		if err != nil {
+			log.Printf("Heartbeat failed: %v", err)
+			return
+		}
+		nm.events.connected()
+		nm.setCapacityOverride(hbResp.CapacityOverride)
+		nm.applyDirectives(ctx, hbResp.Directives)
+		return
+	}
 
+	capacity := map[string]interface{}{
+		"max_concurrent": concurrency.MaxConcurrent,
+		"cpus":           nm.capacity.CPUs,
+		"memory_bytes":   nm.capacity.MemoryBytes,
+	}
+	if concurrency.ReservedHighPriority > 0 {
+		capacity["reserved_high_priority"] = concurrency.ReservedHighPriority
+	}
+	if len(concurrency.AgentTypeLimits) > 0 {
+		capacity["agent_type_limits"] = concurrency.AgentTypeLimits
+	}
+	if adapters := nm.adapterInfos(); len(adapters) > 0 {
+		capacity["adapters"] = adapters
+	}
+	if pools := nm.poolStatus(); len(pools) > 0 {
+		capacity["pools"] = pools
+	}
+	if spool != nil {
+		capacity["spool"] = spool
+	}
+
+	// 内容哈希不含 available 与 metrics；内容未变化且服务端支持 v2 时只发送增量心跳
+	ipList := strings.Join(ips, ",")
+	hash := heartbeatContentHash(hostname, ipList, nm.config.Labels, capacity)
+	now := time.Now()
+	full := nm.heartbeat.needFull(hash, now)
 	payload := map[string]interface{}{
-		"node_id":      nm.config.NodeID,
-		"status":       "online",
-		"hostname":     hostname,
-		"ips":          strings.Join(ips, ","),
-		"labels":       nm.config.Labels,
-		"running_runs": runningRuns,
-		"capacity": map[string]interface{}{
-			"max_concurrent": 2,
-			"available":      2 - len(runningRuns),
-		},
+		"node_id":          nm.config.NodeID,
+		"status":           "online",
+		"running_runs":     runningRuns,
+		"protocol_version": heartbeatProtocolVersion,
+		"content_hash":     hash,
+		"interval_seconds": int(nm.heartbeat.nextInterval() / time.Second),
+	}
+	if full {
+		capacity["available"] = available
+		if metrics != nil {
+			capacity["metrics"] = metrics
+		}
+		payload["hostname"] = hostname
+		payload["ips"] = ipList
+		payload["labels"] = nm.config.Labels
+		payload["capacity"] = capacity
+	} else if metrics != nil {
+		payload["metrics"] = metrics
+	}
+	if approvals := nm.pendingApprovals(); len(approvals) > 0 {
+		payload["pending_approvals"] = approvals
+	}
+	if len(pausedRuns) > 0 {
+		payload["paused_runs"] = pausedRuns
 	}
 
 	body, _ := json.Marshal(payload)
@@ -242,34 +464,95 @@ func (nm *NodeManager) sendHeartbeat(ctx context.Context) {
 		log.Printf("Heartbeat returned status: %d", resp.StatusCode)
 		return
 	}
+	nm.events.connected()
 
-	// 解析心跳响应中的控制指令
-	var hbResp struct {
-		Status     string `json:"status"`
-		Directives *struct {
-			CancelRuns []string `json:"cancel_runs,omitempty"`
-		} `json:"directives,omitempty"`
-	}
+	// 解析心跳响应中的并发配置覆盖与控制指令
+	var hbResp heartbeatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&hbResp); err != nil {
 		return
 	}
+	nm.heartbeat.update(&hbResp, hash, full, now)
+	nm.setCapacityOverride(hbResp.CapacityOverride)
+	nm.applyDirectives(ctx, hbResp.Directives)
+}
 
-	// 执行取消指令
-	if hbResp.Directives != nil && len(hbResp.Directives.CancelRuns) > 0 {
-		for _, runID := range hbResp.Directives.CancelRuns {
-			log.Printf("[nodemanager.directive] cancel run: %s", runID)
-			nm.CancelRun(runID)
-		}
+// heartbeatResponse 心跳响应
+type heartbeatResponse struct {
+	Status           string                         `json:"status"`
+	Directives       *heartbeatDirectives           `json:"directives,omitempty"`
+	CapacityOverride *model.NodeConcurrencyOverride `json:"capacity_override,omitempty"` // 旧版本 API Server 不返回
+	ProtocolVersion  int                            `json:"protocol_version,omitempty"`  // 服务端支持的心跳协议版本（旧版本不返回）
+	IntervalSeconds  int                            `json:"interval_seconds,omitempty"`  // 下次心跳间隔（秒）
+	Resync           bool                           `json:"resync,omitempty"`            // 下次心跳需携带完整内容
+}
+
+// heartbeatDirectives 心跳响应中的控制指令
+type heartbeatDirectives struct {
+	CancelRuns  []string            `json:"cancel_runs,omitempty"`
+	TimeoutRuns []string            `json:"timeout_runs,omitempty"`
+	PauseRuns   []string            `json:"pause_runs,omitempty"`
+	ResumeRuns  []string            `json:"resume_runs,omitempty"`
+	Approvals   []approvalDirective `json:"approvals,omitempty"`
+	Feedbacks   []feedbackDirective `json:"feedbacks,omitempty"`
+}
+
+type approvalDirective struct {
+	ApprovalID string `json:"approval_id"`
+	Status     string `json:"status"`
+}
+
+type feedbackDirective struct {
+	ID      string `json:"id"`
+	RunID   string `json:"run_id"`
+	Type    string `json:"type"`
+	Content string `json:"content"`
+}
+
+// applyDirectives 执行心跳响应中的控制指令（d 为 nil 时无操作）
+func (nm *NodeManager) applyDirectives(ctx context.Context, d *heartbeatDirectives) {
+	if d == nil {
+		return
+	}
+	// 执行取消 / 超时终止指令
+	for _, runID := range d.CancelRuns {
+		log.Printf("[nodemanager.directive] cancel run: %s", runID)
+		nm.CancelRun(runID)
+	}
+	for _, runID := range d.TimeoutRuns {
+		log.Printf("[nodemanager.directive] timeout run: %s", runID)
+		nm.TimeoutRun(runID)
+	}
+	for _, runID := range d.PauseRuns {
+		log.Printf("[nodemanager.directive] pause run: %s", runID)
+		nm.PauseRun(ctx, runID)
+	}
+	for _, runID := range d.ResumeRuns {
+		log.Printf("[nodemanager.directive] resume run: %s", runID)
+		nm.ResumeRun(ctx, runID)
+	}
+	for _, a := range d.Approvals {
+		log.Printf("[nodemanager.directive] approval %s: %s", a.ApprovalID, a.Status)
+		nm.ResolveApproval(a.ApprovalID, a.Status)
+	}
+	for _, f := range d.Feedbacks {
+		nm.QueueFeedback(f.RunID, adapter.Feedback{ID: f.ID, Type: f.Type, Content: f.Content})
 	}
 }
 
 // taskLoop 任务获取主循环（HTTP-Only 架构）
 //
-// 通过 HTTP 轮询 API Server 获取分配给本节点的任务。
+// 通过 HTTP 轮询 API Server 获取分配给本节点的任务；gRPC 模式下改为订阅 WatchAssignedRuns 推送。
 // 借鉴 K8s kubelet 模式：节点主动拉取，控制面不直连节点。
 func (nm *NodeManager) taskLoop(ctx context.Context) {
 	const pollInterval = 3 * time.Second
 
+	if nm.grpc != nil {
+		nm.grpc.watchAssignedRuns(ctx, nm.config.NodeID, func(run map[string]interface{}) {
+			nm.startAssignedRun(ctx, run)
+		})
+		return
+	}
+
 	// 启动时立即执行一次
 	nm.checkAndExecuteRuns(ctx)
 
@@ -307,6 +590,11 @@ func (nm *NodeManager) processTaskMessage(ctx context.Context, runID string) {
 		log.Printf("[nodemanager.run.not_found] run_id=%s", runID)
 		return
 	}
+	if status, _ := run["status"].(string); status != "" && status != "assigned" {
+		// 消息发出后 Run 已被抢占或重新调度
+		log.Printf("[nodemanager.run.skip] run_id=%s status=%s reason=not_assigned", runID, status)
+		return
+	}
 
 	// 启动执行
 	nm.mu.Lock()
@@ -314,30 +602,30 @@ func (nm *NodeManager) processTaskMessage(ctx context.Context, runID string) {
 	nm.running[runID] = cancel
 	nm.mu.Unlock()
 
-	go nm.executeRun(runCtx, run)
+	done := nm.handover.track()
+	go func() {
+		defer done()
+		nm.executeRun(runCtx, run)
+	}()
 }
 
 // fetchRunByID 根据 Run ID 获取 Run 详情
 func (nm *NodeManager) fetchRunByID(ctx context.Context, runID string) (map[string]interface{}, error) {
-	req, _ := http.NewRequestWithContext(ctx, "GET",
-		nm.config.APIServerURL+"/api/v1/runs/"+runID, nil)
-
-	resp, err := nm.httpClient.Do(req)
+	status, body, err := nm.getCached(ctx, nm.config.APIServerURL+"/api/v1/runs/"+runID)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
+	if status == http.StatusNotFound {
 		return nil, nil
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status: %d", status)
 	}
 
 	var result map[string]interface{}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return nil, err
 	}
 
@@ -351,41 +639,68 @@ func (nm *NodeManager) checkAndExecuteRuns(ctx context.Context) {
 		return
 	}
 
+	nm.pruneWaitingRuns(runs)
 	for _, run := range runs {
-		runID := run["id"].(string)
+		nm.startAssignedRun(ctx, run)
+	}
+}
 
-		nm.mu.Lock()
-		if _, exists := nm.running[runID]; exists {
-			nm.mu.Unlock()
-			continue
-		}
+// startAssignedRun 领取分配给本节点的 Run 并开始执行（已在执行或非 assigned 状态时跳过）
+func (nm *NodeManager) startAssignedRun(ctx context.Context, run map[string]interface{}) {
+	runID, _ := run["id"].(string)
+	if runID == "" {
+		return
+	}
+
+	// 只领取 assigned 的任务：不在本进程内存中的 running 任务说明执行已丢失（如进程重启），
+	// 由 API Server 的孤儿回收重新排队或判定失败，避免重复执行
+	if status, _ := run["status"].(string); status != "" && status != "assigned" {
+		return
+	}
 
-		runCtx, cancel := context.WithCancel(ctx)
-		nm.running[runID] = cancel
+	paused := nm.pausedRuns()
+	nm.mu.Lock()
+	if _, exists := nm.running[runID]; exists {
+		nm.mu.Unlock()
+		return
+	}
+	// 并发限制：暂不能启动时保持 assigned，槽位释放后重试
+	if !nm.admitLocked(ctx, runID, run, paused) {
 		nm.mu.Unlock()
+		return
+	}
 
-		go nm.executeRun(runCtx, run)
+	runCtx, cancel := context.WithCancel(ctx)
+	nm.running[runID] = cancel
+	if base, _ := run["event_seq"].(float64); base > 0 {
+		if nm.seqBase == nil {
+			nm.seqBase = make(map[string]int)
+		}
+		nm.seqBase[runID] = int(base)
 	}
+	nm.mu.Unlock()
+
+	done := nm.handover.track()
+	go func() {
+		defer done()
+		nm.executeRun(runCtx, run)
+	}()
 }
 
 func (nm *NodeManager) fetchAssignedRuns(ctx context.Context) ([]map[string]interface{}, error) {
-	req, _ := http.NewRequestWithContext(ctx, "GET",
-		nm.config.APIServerURL+"/api/v1/nodes/"+nm.config.NodeID+"/runs", nil)
-
-	resp, err := nm.httpClient.Do(req)
+	status, body, err := nm.getCached(ctx, nm.config.APIServerURL+"/api/v1/nodes/"+nm.config.NodeID+"/runs")
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode == http.StatusNotFound {
+	if status == http.StatusNotFound {
 		return nil, nil
 	}
 
 	var result struct {
 		Runs []map[string]interface{} `json:"runs"`
 	}
-	json.NewDecoder(resp.Body).Decode(&result)
+	json.Unmarshal(body, &result)
 	return result.Runs, nil
 }
 
@@ -393,49 +708,100 @@ func (nm *NodeManager) fetchAssignedRuns(ctx context.Context) ([]map[string]inte
 // 从 snapshot 中解析 TaskSpec，调用 Adapter 构建命令并执行
 func (nm *NodeManager) executeRun(ctx context.Context, run map[string]interface{}) {
 	runID := run["id"].(string)
-	defer func() {
-		nm.mu.Lock()
-		delete(nm.running, runID)
-		nm.mu.Unlock()
-	}()
+	nm.logs.begin(runID)
+	defer nm.forgetRun(runID)
+
+	// 交接给下一次启动的 Run 保留工作空间、执行目标与资源限制（见 handover.go）
+	handedOff := false
 
 	log.Printf("执行任务: %s", runID)
 
-	// 解析 snapshot 中的任务配置（带类型安全检查）
-	snapshot, ok := run["snapshot"].(map[string]interface{})
-	if !ok || snapshot == nil {
-		nm.reportError(ctx, runID, "任务快照 (snapshot) 缺失或格式错误")
+	// 解析 snapshot 中的任务配置（与 API Server 共用 model.RunSnapshot，见 run_snapshot.go）
+	snapshot, err := parseRunSnapshot(run["snapshot"])
+	if err != nil {
+		nm.reportError(ctx, runID, err.Error())
 		return
 	}
+	agentConfig := &snapshot.Agent
+	agentType := agentConfig.Type
 
-	agentConfig, ok := snapshot["agent"].(map[string]interface{})
-	if !ok || agentConfig == nil {
-		nm.reportError(ctx, runID, "Agent 配置 (snapshot.agent) 缺失或格式错误")
+	prompt := snapshot.Prompt
+	if prompt == "" {
+		nm.reportError(ctx, runID, "任务提示 (snapshot.prompt) 为空")
 		return
 	}
 
-	agentType, ok := agentConfig["type"].(string)
-	if !ok || agentType == "" {
-		nm.reportError(ctx, runID, "Agent 类型 (snapshot.agent.type) 缺失或格式错误")
+	// 选择执行后端：任务快照 runtime.backend 优先，否则使用节点默认后端
+	backend, err := nm.execBackendFor(snapshot)
+	if err != nil {
+		nm.reportError(ctx, runID, err.Error())
 		return
 	}
 
-	prompt, ok := snapshot["prompt"].(string)
-	if !ok || prompt == "" {
-		nm.reportError(ctx, runID, "任务提示 (snapshot.prompt) 缺失或格式错误")
+	// 资源限制：超出节点容量时拒绝执行
+	limits, err := ParseRunLimits(snapshot)
+	if err != nil {
+		nm.reportError(ctx, runID, err.Error())
+		return
+	}
+	if rejected := limits.rejection(nm.capacity); rejected != nil {
+		seq := nm.firstSeq(runID)
+		nm.reportEvent(ctx, runID, seq, "resource_limit", rejected)
+		nm.completeRun(ctx, runID, "failed", fmt.Sprintf("资源限制 %s=%v 超出节点容量 %v", rejected["resource"], rejected["limit"], rejected["capacity"]), seq+1)
 		return
 	}
 
 	// 获取对应的 Adapter
 	// Agent type 到 adapter name 的映射
 	// 支持多种格式：qwen-code -> qwencode-v1, qwencode -> qwencode-v1
-	adapterName := normalizeAdapterName(agentType)
-	a, adapterOk := nm.adapters.Get(adapterName)
-	if !adapterOk {
-		nm.reportError(ctx, runID, fmt.Sprintf("找不到适配器: %s (原始类型: %s)", adapterName, agentType))
+	// 没有内置 Adapter 的自定义类型使用通用适配器（见 agent_type.go）
+	a, err := nm.resolveAdapter(ctx, agentType)
+	if err != nil {
+		nm.reportError(ctx, runID, err.Error())
 		return
 	}
 
+	// 账号池：按账号负载租用账号，池中账号均已达额度时交还 API Server 重新排队
+	accountLeased := false
+	if backend != model.ExecBackendProcess && hasAccountPool(agentConfig) {
+		accountLeased, err = nm.leasePoolAccount(ctx, runID, agentConfig)
+		if err != nil {
+			if nm.failoverAccount(ctx, runID, err.Error(), 0) {
+				return
+			}
+			nm.reportError(ctx, runID, err.Error())
+			return
+		}
+	}
+
+	// 持久会话：追问 Run 优先在上一轮保留的容器中继续，否则将对话历史拼入提示词（见 session.go）
+	session := ParseRunSession(snapshot)
+	var warm *sessionContainer
+	var resumer adapter.SessionResumer
+	if session != nil && backend == model.ExecBackendDocker && nm.sessions != nil {
+		if session.Resume {
+			warm = nm.sessions.take(ctx, session.ID)
+		} else {
+			nm.sessions.drop(session.ID)
+		}
+	}
+	warmUsed := false
+	if warm != nil {
+		// 未能在会话容器中执行时放回，由保留到期释放
+		defer func() {
+			if !warmUsed {
+				nm.sessions.park(session.ID, warm.container, warm.pooled, sessionKeepAlive(session))
+			}
+		}()
+	}
+	if session != nil && session.Resume {
+		if r, ok := a.(adapter.SessionResumer); ok && warm != nil {
+			resumer = r
+		} else {
+			prompt = adapter.FormatConversation(sessionHistory(session), prompt)
+		}
+	}
+
 	// 构建 TaskSpec（任务描述）
 	spec := &adapter.TaskSpec{
 		ID:     runID,
@@ -443,18 +809,31 @@ func (nm *NodeManager) executeRun(ctx context.Context, run map[string]interface{
 	}
 
 	// 构建 AgentConfig（执行者配置）
-	// 从 snapshot 中提取模型和参数
-	model, _ := agentConfig["model"].(string)
-	parameters, _ := agentConfig["parameters"].(map[string]interface{})
-	if parameters == nil {
-		// 兼容旧格式：直接使用 agentConfig 作为参数
-		parameters = agentConfig
-	}
-
+	// 从 snapshot 中提取模型和参数（旧格式直接写在 agent 中的参数已由 model.UpgradeSnapshot 移入 parameters）
 	agent := &adapter.AgentConfig{
 		Type:       agentType,
-		Model:      model,
-		Parameters: parameters,
+		Model:      agentConfig.Model,
+		Parameters: agentConfig.Parameters,
+	}
+
+	// 不从 stdin 接收人工反馈的 Agent 通过 interrupt 文件接收（见 feedback.go）
+	if _, ok := a.(adapter.FeedbackReceiver); !ok {
+		spec.Prompt += adapter.FeedbackPromptHint
+	}
+
+	// 继承的上下文在 Agent 启动前写入工作目录（见 run_context.go）
+	inherited := ParseInheritedContext(snapshot)
+	if len(inherited) > 0 {
+		spec.Prompt += contextPromptHint
+	}
+
+	// 技能包在 Agent 启动前安装到 Adapter 的技能目录（见 skill_install.go）
+	skills := ParseRunSkills(snapshot)
+	skillsDir := adapter.DefaultSkillsDir
+	if loader, ok := a.(adapter.SkillsLoader); ok {
+		skillsDir = loader.SkillsDir()
+	} else if len(skills) > 0 {
+		spec.Prompt += adapter.SkillsPromptHint(skillNames(skills))
 	}
 
 	// 构建运行配置
@@ -463,61 +842,137 @@ func (nm *NodeManager) executeRun(ctx context.Context, run map[string]interface{
 		nm.reportError(ctx, runID, fmt.Sprintf("构建命令失败: %v", err))
 		return
 	}
+	if resumer != nil {
+		runConfig.Args = append(runConfig.Args, resumer.ResumeArgs()...)
+	}
+
+	// 安全策略换算为 CLI 参数，运行中由审批闸门执行（见 security_policy.go）
+	policy := newPolicyGuard(ParseSecurityPolicy(snapshot), a)
+	if policy != nil {
+		runConfig.Args = append(runConfig.Args, policy.args...)
+	}
+
+	// 代理：使用默认代理，本节点探测失败时切换到健康的备用代理（Adapter 已设置的变量不覆盖）
+	proxy := nm.proxies.resolve("")
+	if proxy != nil {
+		if runConfig.Env == nil {
+			runConfig.Env = make(map[string]string)
+		}
+		for _, kv := range proxy.Env {
+			if k, v, ok := strings.Cut(kv, "="); ok {
+				if _, set := runConfig.Env[k]; !set {
+					runConfig.Env[k] = v
+				}
+			}
+		}
+	}
 
 	// 准备 Workspace（如果配置了）
 	var workspace *PreparedWorkspace
 	wsConfig := ParseWorkspaceConfig(snapshot)
 	if wsConfig != nil {
-		log.Printf("任务 %s 需要准备 Workspace: type=%s", runID, wsConfig.Type)
+		nm.runLog(runID, nodeLogInfo, nodeLogStageWorkspace, "准备 Workspace: type=%s", wsConfig.Type)
+		prepareStart := time.Now()
 		workspace, err = nm.workspaceManager.Prepare(ctx, runID, wsConfig)
 		if err != nil {
+			nm.runLog(runID, nodeLogError, nodeLogStageWorkspace, "准备 Workspace 失败（耗时 %s）: %v", time.Since(prepareStart).Round(time.Millisecond), err)
 			nm.reportError(ctx, runID, fmt.Sprintf("准备 Workspace 失败: %v", err))
 			return
 		}
+		if workspace != nil {
+			nm.runLog(runID, nodeLogInfo, nodeLogStageWorkspace, "Workspace 准备完成（耗时 %s）: path=%s working_dir=%s",
+				time.Since(prepareStart).Round(time.Millisecond), workspace.Path, workspace.WorkingDir)
+		}
 		if workspace != nil && workspace.Cleanup != nil {
-			defer workspace.Cleanup()
+			defer func() {
+				if !handedOff {
+					workspace.Cleanup()
+				}
+			}()
 		}
 	}
 
-	// 优先使用 instance_id 获取容器，回退到 account_id
-	instanceID, _ := agentConfig["instance_id"].(string)
-	accountID, _ := agentConfig["account_id"].(string)
-
-	var containerName string
-	if instanceID != "" {
-		// 直接通过 instance_id 获取容器名
-		containerName, err = nm.getContainerForInstance(ctx, instanceID)
+	// 解析任务引用的密钥，之后该 Run 上报的事件均会脱敏
+	var secrets map[string]string
+	if names := ParseSecretNames(snapshot); len(names) > 0 {
+		secrets, err = nm.resolveSecrets(ctx, names)
 		if err != nil {
-			nm.reportError(ctx, runID, fmt.Sprintf("获取实例容器失败: %v", err))
+			nm.reportError(ctx, runID, err.Error())
 			return
 		}
-	} else if accountID != "" {
-		// 回退：通过 account_id 查找容器
-		containerName, err = nm.getContainerForAccount(ctx, accountID)
+		nm.setSecretMasker(runID, newSecretMasker(secrets))
+	}
+
+	// 准备执行目标（docker 容器或宿主机进程）
+	workingDir := runConfig.WorkingDir
+	if workspace != nil && workspace.WorkingDir != "" {
+		workingDir = workspace.WorkingDir
+	}
+	var target execTarget
+	if backend == model.ExecBackendProcess {
+		pt, err := nm.process.prepare(runID, workspace, wsConfig, secrets)
 		if err != nil {
-			nm.reportError(ctx, runID, fmt.Sprintf("获取容器失败: %v", err))
+			nm.runLog(runID, nodeLogError, model.ExecBackendProcess, "准备进程执行环境失败: %v", err)
+			nm.reportError(ctx, runID, fmt.Sprintf("准备进程执行环境失败: %v", err))
 			return
 		}
+		defer func() {
+			if !handedOff {
+				pt.cleanup()
+			}
+		}()
+		nm.runLog(runID, nodeLogInfo, model.ExecBackendProcess, "将在宿主机目录 %s 中执行", pt.dir)
+		target = pt
 	} else {
-		nm.reportError(ctx, runID, "任务缺少 instance_id 或 account_id 配置")
-		return
-	}
-
-	log.Printf("任务 %s 将在容器 %s 中执行", runID, containerName)
-
-	// 如果有 Workspace，复制到容器中
-	if workspace != nil && workspace.Path != "" && wsConfig.Type == "git" {
-		log.Printf("[Workspace] 复制文件到容器: %s -> %s:/workspace", workspace.Path, containerName)
-		if err := nm.copyToContainer(ctx, workspace.Path, containerName, "/workspace"); err != nil {
-			nm.reportError(ctx, runID, fmt.Sprintf("复制 Workspace 到容器失败: %v", err))
+		dt, err := nm.prepareDockerTarget(ctx, runID, agentConfig, workspace, wsConfig, workingDir, secrets, warm)
+		if err != nil {
+			nm.reportError(ctx, runID, err.Error())
 			return
 		}
+		warmUsed = true
+		if session != nil && nm.sessions != nil {
+			dt.session = &sessionLease{id: session.ID, keepAlive: sessionKeepAlive(session), resumed: warm != nil, cache: nm.sessions}
+		}
+		defer func() {
+			if !handedOff {
+				dt.release()
+			}
+		}()
+		target = dt
 	}
+	nm.mu.Lock()
+	if nm.targets == nil {
+		nm.targets = make(map[string]execTarget)
+	}
+	nm.targets[runID] = target
+	nm.mu.Unlock()
 
 	// 上报 run_started 事件
 	startPayload := map[string]interface{}{
-		"node_id":   nm.config.NodeID,
-		"container": containerName,
+		"node_id": nm.config.NodeID,
+		"backend": target.backend(),
+	}
+	for k, v := range target.describe() {
+		startPayload[k] = v
+	}
+	if accountLeased {
+		startPayload["account_id"] = agentConfig.AccountID
+	}
+	if proxy != nil {
+		startPayload["proxy_id"] = proxy.ID
+	}
+	if limits != nil {
+		unenforced, restore, err := target.applyLimits(ctx, limits)
+		if err != nil {
+			nm.reportError(ctx, runID, fmt.Sprintf("应用资源限制失败: %v", err))
+			return
+		}
+		defer func() {
+			if !handedOff {
+				restore()
+			}
+		}()
+		startPayload["limits"] = limits.describe(unenforced)
 	}
 	if workspace != nil {
 		startPayload["workspace"] = map[string]interface{}{
@@ -526,94 +981,389 @@ func (nm *NodeManager) executeRun(ctx context.Context, run map[string]interface{
 			"working_dir": workspace.WorkingDir,
 		}
 	}
-	nm.reportEvent(ctx, runID, 1, "run_started", startPayload)
-
-	// 构建 docker exec 命令
-	// docker exec <container> <command> <args...>
-	dockerArgs := []string{"exec"}
-
-	// 添加环境变量
-	for k, v := range runConfig.Env {
-		dockerArgs = append(dockerArgs, "-e", k+"="+v)
+	if policy != nil {
+		startPayload["security_policy"] = policy.describe()
 	}
-
-	// 设置工作目录（优先使用 Workspace 的工作目录）
-	workingDir := runConfig.WorkingDir
-	if workspace != nil && workspace.WorkingDir != "" {
-		workingDir = workspace.WorkingDir
+	if len(skills) > 0 {
+		installed, err := nm.installSkills(ctx, runID, target, skills, skillsDir)
+		if err != nil {
+			nm.reportError(ctx, runID, err.Error())
+			return
+		}
+		startPayload["skills"] = installed
 	}
-	if workingDir != "" {
-		dockerArgs = append(dockerArgs, "-w", workingDir)
+	seq := nm.firstSeq(runID)
+	nm.reportEvent(ctx, runID, seq, "run_started", startPayload)
+	seq++
+	if len(inherited) > 0 {
+		seq = nm.writeInheritedContext(ctx, runID, target, inherited, seq)
 	}
 
-	dockerArgs = append(dockerArgs, containerName)
-	dockerArgs = append(dockerArgs, runConfig.Command...)
-	dockerArgs = append(dockerArgs, runConfig.Args...)
+	// 生命周期钩子：pre_run 失败时不启动 Agent
+	hooks := ParseLifecycleHooks(snapshot)
+	hookRun := targetHookRunner(target)
+	hookEnv := map[string]string{"AGENTS_RUN_ID": runID}
+	if hooks != nil && len(hooks.PreRun) > 0 {
+		seq, err = nm.runHooks(ctx, runID, hookPhasePreRun, hooks.PreRun, hookRun, hookEnv, seq)
+		if err != nil {
+			status := "failed"
+			if ctx.Err() != nil {
+				status = nm.interruptedStatus(runID)
+			}
+			seq = nm.runFailureHooks(ctx, runID, status, hooks, hookRun, hookEnv, seq)
+			nm.completeRun(ctx, runID, status, err.Error(), seq)
+			return
+		}
+	}
 
-	cmd := exec.CommandContext(ctx, "docker", dockerArgs...)
-	cmd.Env = os.Environ()
+	// Agent 命令使用独立的 context：审批被拒绝时只终止命令，事件与状态仍可上报；
+	// Run 被取消或超时终止时由 stopAgent 分阶段停止（见 cancellation.go）
+	cmdCtx, killCmd := context.WithCancel(context.WithoutCancel(ctx))
+	defer killCmd()
+	argv := append(append([]string{}, runConfig.Command...), runConfig.Args...)
+	feedback := nm.newFeedbackChannel(runID, target, a)
+	defer nm.unregisterFeedbackChannel(runID)
+	x := &runExecution{
+		runID:         runID,
+		snapshot:      snapshot,
+		accountLeased: accountLeased,
+		limits:        limits,
+		target:        target,
+		workspace:     workspace,
+		wsConfig:      wsConfig,
+		hooks:         hooks,
+		hookRun:       hookRun,
+		hookEnv:       hookEnv,
+	}
+
+	// 重启交接：Agent 在执行目标内脱离 NodeManager 运行（stdin 反馈需要管道，不能交接）
+	if nm.handover != nil && !feedback.usesStdin() {
+		st, err := nm.launchDetached(ctx, x, argv, runConfig.Env, seq)
+		if err != nil {
+			nm.reportError(ctx, runID, fmt.Sprintf("启动失败: %v", err))
+			return
+		}
+		res := nm.superviseDetached(ctx, x, st, &runOutput{target: target, dir: st.OutputDir}, a, feedback)
+		if res.handedOff {
+			handedOff = true
+			return
+		}
+		nm.concludeRun(ctx, x, res)
+		nm.handover.discard(st, target)
+		return
+	}
+
+	cmd := target.command(cmdCtx, interruptibleArgv(target, runID, argv), runConfig.Env)
+	pauser := newRunPauser(target, cmd)
+	gate := newApprovalGate(nm, runID, pauser, ParseApprovalTools(snapshot), killCmd)
+	gate.policy = policy
+	gate.commands = newCommandFilter(ParseCommandFilter(snapshot), a)
+	x.gate = gate
 
-	// 打印完整命令以便调试
-	log.Printf("执行命令: docker %v", dockerArgs)
+	// 打印完整命令以便调试（密钥值只在进程环境中，不会出现在参数里）
+	log.Printf("执行命令: %v", cmd.Args)
 
 	stdout, _ := cmd.StdoutPipe()
 	stderr, _ := cmd.StderrPipe()
+	if feedback.usesStdin() {
+		feedback.stdin, _ = cmd.StdinPipe()
+	}
 
 	if err := cmd.Start(); err != nil {
 		nm.reportError(ctx, runID, fmt.Sprintf("启动失败: %v", err))
 		return
 	}
 
+	// 登记暂停控制；Run 被取消或超时终止时先恢复执行目标（冻结的执行目标收不到停止信号），再优雅停止 Agent
+	nm.registerPauser(runID, pauser)
+	defer nm.unregisterPauser(runID)
+	exited := make(chan struct{})
+	stopped := make(chan *cancellation, 1)
+	go func() {
+		select {
+		case <-exited:
+			stopped <- nil
+			return
+		case <-ctx.Done():
+		}
+		releaseCtx, cancel := context.WithTimeout(context.Background(), limitRestoreTimeout)
+		if err := pauser.releaseAll(releaseCtx); err != nil {
+			log.Printf("[Pause] 任务 %s 终止前恢复执行失败: %v", runID, err)
+		}
+		cancel()
+		stopped <- nm.stopAgent(runID, a, target, cmd, killCmd, exited)
+	}()
+
+	// 租用账号的 Run 保留 stdout 末尾用于限流检测
+	stdoutTail := &tailBuffer{max: quotaOutputTail}
+	if accountLeased {
+		stdout = io.NopCloser(io.TeeReader(stdout, stdoutTail))
+	}
+
 	// 异步读取 stderr 以便捕获错误信息
 	var stderrBuf bytes.Buffer
 	go func() {
-		io.Copy(&stderrBuf, stderr)
+		io.Copy(io.MultiWriter(&stderrBuf, &logLineWriter{nm: nm, runID: runID, prefix: "[stderr] "}), stderr)
 	}()
 
 	// 流式读取输出并解析事件
-	seq := 2
-	seq = nm.streamOutput(ctx, runID, stdout, a, seq)
+	seq = nm.streamOutput(ctx, runID, stdout, a, gate, feedback, seq)
 
 	// 等待命令完成
 	err = cmd.Wait()
+	close(exited)
+	stop := <-stopped
+	feedback.cleanup(context.WithoutCancel(ctx))
 
 	// 如果有 stderr 输出，记录日志
 	if stderrBuf.Len() > 0 {
-		log.Printf("任务 %s stderr 输出: %s", runID, stderrBuf.String())
+		nm.logAgentStderr(runID, target.backend(), stderrBuf.String(), err != nil && ctx.Err() == nil)
 	}
+	nm.concludeRun(ctx, x, agentResult{err: err, stderr: stderrBuf.String(), stdoutTail: stdoutTail.String(), seq: seq, stop: stop})
+}
+
+// runExecution Agent 命令结束后判定状态与收尾所需的 Run 上下文（正常执行与重启接管共用）
+type runExecution struct {
+	runID         string
+	snapshot      *model.RunSnapshot
+	accountLeased bool
+	limits        *RunLimits
+	target        execTarget
+	workspace     *PreparedWorkspace
+	wsConfig      *WorkspaceConfig
+	hooks         *LifecycleHooks
+	hookRun       hookRunner
+	hookEnv       map[string]string
+	gate          *approvalGate
+}
+
+// agentResult Agent 命令的执行结果
+type agentResult struct {
+	err        error  // 命令退出错误（nil 表示正常退出）
+	reason     string // 失败原因（为空时由退出状态推断）
+	stderr     string
+	stdoutTail string        // 租用账号的 Run 的 stdout 末尾（限流检测）
+	seq        int           // 下一个事件序号
+	handedOff  bool          // NodeManager 停止，Run 已交接给下一次启动
+	stop       *cancellation // Run 被取消或超时终止时 Agent 的停止过程（Agent 先于取消退出时为 nil）
+}
+
+// concludeRun 根据 Agent 命令的执行结果判定 Run 状态，执行 post_run 钩子、上下文收集、Git 回写与 on_failure 钩子后上报终态
+func (nm *NodeManager) concludeRun(ctx context.Context, x *runExecution, res agentResult) {
+	runID, seq := x.runID, res.seq
+	var err error
 	status := "done"
-	if err != nil {
+	failReason := ""
+	if res.stop != nil {
+		seq = nm.reportCancellation(ctx, res.stop, seq)
+		status = res.stop.reason
+	} else if res.err != nil {
 		if ctx.Err() != nil {
+			status = nm.interruptedStatus(runID)
+		} else if x.gate != nil && x.gate.violation != "" {
+			status = "failed"
+			failReason = x.gate.violation
+		} else if x.gate != nil && x.gate.rejection != "" {
 			status = "cancelled"
+			failReason = x.gate.rejection
 		} else {
 			status = "failed"
+			failReason = res.reason
+			if hit := x.limits.exceeded(res.err); hit != nil {
+				nm.reportEvent(ctx, runID, seq, "resource_limit", hit)
+				seq++
+				failReason = fmt.Sprintf("超出内存限制 %s", x.limits.Spec.MaxMemory)
+			}
+		}
+	}
+
+	// 账号触发限流：切换账号后 Run 已重新排队，终态由后续执行上报
+	if status == "failed" && failReason == "" && x.accountLeased {
+		if line, retryAfter := detectQuotaError(res.stderr + "\n" + res.stdoutTail); line != "" {
+			accountID := x.snapshot.Agent.AccountID
+			nm.reportEvent(ctx, runID, seq, "warning", map[string]interface{}{
+				"code":       "account_failover",
+				"account_id": accountID,
+				"message":    nm.secretMaskerFor(runID).mask(quotaFailureReason(accountID, line)),
+			})
+			seq++
+			if nm.failoverAccount(ctx, runID, line, retryAfter) {
+				return
+			}
+		}
+	}
+
+	// post_run 钩子：在结果回写前执行，失败时 Run 标记为失败
+	hooks, workspace, wsConfig := x.hooks, x.workspace, x.wsConfig
+	if status == "done" && hooks != nil && len(hooks.PostRun) > 0 {
+		x.hookEnv["AGENTS_RUN_STATUS"] = status
+		if seq, err = nm.runHooks(ctx, runID, hookPhasePostRun, hooks.PostRun, x.hookRun, x.hookEnv, seq); err != nil {
+			status = "failed"
+			failReason = err.Error()
+		}
+	}
+
+	// 收集产出的上下文并删除上下文目录（在 Git 回写之前）
+	seq = nm.concludeContext(ctx, runID, x.target, status == "done", seq)
+
+	// Git 工作空间：先将执行目标中的工作空间同步回宿主机并上报变更报告，
+	// 仅在成功完成时将变更提交推送回仓库
+	if (status == "done" || status == "failed") && workspace != nil && wsConfig.Type == "git" {
+		syncEnabled := status == "done" && wsConfig.Git != nil && wsConfig.Git.Sync != nil && wsConfig.Git.Sync.Enabled
+		if err := x.target.collect(ctx, workspace.Path); err != nil {
+			code := "workspace_collect_failed"
+			if syncEnabled {
+				code = "workspace_sync_failed"
+			}
+			nm.reportEvent(ctx, runID, seq, "warning", map[string]interface{}{
+				"code":    code,
+				"message": err.Error(),
+			})
+			seq++
+		} else {
+			nm.reportWorkspaceDiff(ctx, runID, workspace)
+			if syncEnabled {
+				seq = nm.syncWorkspaceResult(ctx, runID, workspace, wsConfig.Git, x.snapshot.Name, seq)
+			}
 		}
 	}
 
-	// 上报 run_completed 事件
-	nm.reportEvent(ctx, runID, seq, "run_completed", map[string]interface{}{
+	seq = nm.runFailureHooks(ctx, runID, status, hooks, x.hookRun, x.hookEnv, seq)
+	nm.completeRun(ctx, runID, status, failReason, seq)
+}
+
+// forgetRun Run 执行结束（或交接给下一次启动）后清除其登记信息、上报剩余的节点日志，并启动因并发限制等待中的任务
+func (nm *NodeManager) forgetRun(runID string) {
+	nm.mu.Lock()
+	delete(nm.running, runID)
+	delete(nm.maskers, runID)
+	delete(nm.timedOut, runID)
+	delete(nm.seqBase, runID)
+	delete(nm.targets, runID)
+	delete(nm.slots, runID)
+	nm.mu.Unlock()
+	nm.logs.end(runID)
+	nm.flushNodeLogs(context.Background(), runID)
+	nm.startWaitingRuns()
+}
+
+// runFailureHooks 执行 on_failure 钩子（结果仅记录，不影响 Run 状态），返回下一个事件序号
+func (nm *NodeManager) runFailureHooks(ctx context.Context, runID, status string, hooks *LifecycleHooks, run hookRunner, env map[string]string, seq int) int {
+	if status != "failed" || hooks == nil || len(hooks.OnFailure) == 0 {
+		return seq
+	}
+	env["AGENTS_RUN_STATUS"] = status
+	seq, err := nm.runHooks(ctx, runID, hookPhaseOnFailure, hooks.OnFailure, run, env, seq)
+	if err != nil {
+		log.Printf("[Hooks] 任务 %s on_failure 钩子失败: %v", runID, err)
+	}
+	return seq
+}
+
+// completeRun 上报 run_completed 事件并更新 Run 状态
+func (nm *NodeManager) completeRun(ctx context.Context, runID, status, reason string, seq int) {
+	payload := map[string]interface{}{
 		"status": status,
-	})
+	}
+	if reason != "" {
+		payload["error"] = reason
+	}
+	nm.reportEvent(ctx, runID, seq, "run_completed", payload)
 
 	nm.updateRunStatus(ctx, runID, status)
 	log.Printf("任务 %s 完成，状态: %s", runID, status)
 }
 
+// syncWorkspaceResult 将工作空间变更回写到 Git 仓库（调用方已将执行目标中的工作空间同步回宿主机）
+//
+// 回写失败不影响 Run 状态，仅上报 warning 事件；成功时上报 workspace_synced 事件，
+// 并将 PR/MR 地址作为 Run 产物上报。返回下一个事件序号。
+func (nm *NodeManager) syncWorkspaceResult(ctx context.Context, runID string, workspace *PreparedWorkspace, gitCfg *GitConfig, title string, seq int) int {
+	// 凭据：优先使用 credential_ref，否则回退到节点的 GIT_TOKEN
+	cred, err := nm.workspaceManager.ResolveCredential(ctx, gitCfg)
+	if err != nil {
+		nm.reportEvent(ctx, runID, seq, "warning", map[string]interface{}{
+			"code":    "workspace_sync_failed",
+			"message": err.Error(),
+		})
+		return seq + 1
+	}
+	if cred == nil && nm.config.GitToken != "" {
+		cred = &GitCredential{Type: credentialTypeHTTPSToken, Secret: nm.config.GitToken}
+	}
+
+	result, err := nm.workspaceManager.SyncGit(ctx, runID, workspace.Path, gitCfg, cred, title)
+	if err != nil {
+		log.Printf("[Workspace] 任务 %s 结果回写失败: %v", runID, err)
+		payload := map[string]interface{}{
+			"code":    "workspace_sync_failed",
+			"message": err.Error(),
+		}
+		if result != nil {
+			payload["branch"] = result.Branch
+			payload["commit_sha"] = result.CommitSHA
+		}
+		nm.reportEvent(ctx, runID, seq, "warning", payload)
+		return seq + 1
+	}
+
+	nm.reportEvent(ctx, runID, seq, "workspace_synced", map[string]interface{}{
+		"branch":     result.Branch,
+		"commit_sha": result.CommitSHA,
+		"pr_url":     result.PRURL,
+		"no_changes": result.NoChanges,
+	})
+	if result.PRURL != "" {
+		nm.reportArtifact(ctx, runID, "pull_request", result.PRURL, "text/uri-list")
+	}
+	return seq + 1
+}
+
+// reportArtifact 上报 Run 产物到 API Server
+//
+// 与 Run 状态一样先排空事件队列，上报失败时写入本地缓存按顺序回放。
+func (nm *NodeManager) reportArtifact(ctx context.Context, runID, name, path, contentType string) {
+	body, _ := json.Marshal(map[string]string{
+		"name":         name,
+		"path":         path,
+		"content_type": contentType,
+	})
+	nm.events.drain(runID)
+	nm.reportRecord(ctx, runID, spoolRecord{Kind: spoolKindArtifact, Artifact: body})
+}
+
 // streamOutput 流式读取命令输出并解析为事件
 // 每读取一行就调用 Adapter.ParseEvent 解析，然后上报到 API Server
-// 同时保存原始输出到 raw 字段，便于调试和回放
-func (nm *NodeManager) streamOutput(ctx context.Context, runID string, r io.Reader, a adapter.Adapter, startSeq int) int {
+// 同时保存原始输出到 raw 字段，便于调试和回放；需要人工审批的事件在审批决定前不再继续读取
+func (nm *NodeManager) streamOutput(ctx context.Context, runID string, r io.Reader, a adapter.Adapter, gate *approvalGate, feedback *feedbackChannel, startSeq int) int {
+	return nm.streamOutputFrom(ctx, runID, r, a, gate, feedback, startSeq, nil)
+}
+
+// streamOutputFrom 同 streamOutput，每处理完一行调用 progress（已处理的字节数与下一个事件序号），
+// 重启交接据此记录检查点（见 handover.go）
+func (nm *NodeManager) streamOutputFrom(ctx context.Context, runID string, r io.Reader, a adapter.Adapter, gate *approvalGate, feedback *feedbackChannel, startSeq int, progress func(consumed int64, seq int)) int {
 	scanner := bufio.NewScanner(r)
 	// 增大缓冲区以处理大行（如长 JSON）
 	buf := make([]byte, 0, 64*1024)
 	scanner.Buffer(buf, 1024*1024)
+	var consumed int64
+	scanner.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		consumed += int64(advance)
+		return advance, token, err
+	})
 
 	seq := startSeq
+	usageReporter, _ := a.(adapter.UsageReporter)
 
 	for scanner.Scan() {
 		line := scanner.Text()
+		nm.publishOutput(runID, line)
 		event, err := a.ParseEvent(line)
 		if err != nil || event == nil {
+			if progress != nil {
+				progress(consumed, seq)
+			}
 			continue
 		}
 
@@ -622,9 +1372,44 @@ func (nm *NodeManager) streamOutput(ctx context.Context, runID string, r io.Read
 		event.RunID = runID
 		event.Timestamp = time.Now()
 
-		// 上报事件，同时传递原始行数据
-		nm.reportEventWithRaw(ctx, runID, seq, string(event.Type), event.Payload, line)
-		seq++
+		// 子任务派生：先创建子任务，事件携带派生结果上报
+		if event.Type == adapter.EventSpawnSubtask {
+			event.Payload = nm.spawnSubtask(ctx, runID, seq, event.Payload)
+		}
+
+		var approval map[string]interface{}
+		if gate != nil {
+			approval = gate.requires(event)
+		}
+
+		// 上报事件，同时传递原始行数据（approval_required 由审批闸门补充 approval_id 后上报）
+		if event.Type != adapter.EventApprovalRequired || approval == nil {
+			nm.reportEventWithRaw(ctx, runID, seq, string(event.Type), event.Payload, line)
+			seq++
+		}
+		// Token 用量：API Server 按 Run 累加，用于费用统计
+		if usageReporter != nil {
+			if usage := usageReporter.ExtractUsage(event); usage != nil {
+				nm.reportEvent(ctx, runID, seq, string(adapter.EventUsage), usage.Payload())
+				seq++
+			}
+		}
+		if gate != nil {
+			seq = gate.enforcePolicy(ctx, event, seq)
+			seq = gate.blockCommand(ctx, event, seq)
+		}
+		if approval != nil {
+			seq = gate.wait(ctx, seq, approval)
+		}
+
+		// 人工反馈：先确认 Agent 是否读取了已写入的反馈，再注入新的反馈
+		if feedback != nil {
+			seq = feedback.observe(ctx, event, seq)
+			seq = feedback.flush(ctx, seq)
+		}
+		if progress != nil {
+			progress(consumed, seq)
+		}
 	}
 
 	return seq
@@ -636,7 +1421,15 @@ func (nm *NodeManager) reportEvent(ctx context.Context, runID string, seq int, e
 }
 
 // reportEventWithRaw 上报事件到 API Server（含原始数据）
+//
+// 事件加入批量上报队列（见 event_reporter.go），队列满时阻塞
 func (nm *NodeManager) reportEventWithRaw(ctx context.Context, runID string, seq int, eventType string, payload map[string]interface{}, raw string) {
+	// 注入了密钥的 Run：脱敏后再上报
+	if masker := nm.secretMaskerFor(runID); masker != nil {
+		payload = masker.maskPayload(payload)
+		raw = masker.mask(raw)
+	}
+
 	event := map[string]interface{}{
 		"seq":       seq,
 		"type":      eventType,
@@ -649,46 +1442,97 @@ func (nm *NodeManager) reportEventWithRaw(ctx context.Context, runID string, seq
 		event["raw"] = raw
 	}
 
-	events := []map[string]interface{}{event}
-
-	body, _ := json.Marshal(map[string]interface{}{"events": events})
-	req, _ := http.NewRequestWithContext(ctx, "POST",
-		nm.config.APIServerURL+"/api/v1/runs/"+runID+"/events",
-		bytes.NewReader(body))
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := nm.httpClient.Do(req)
-	if err != nil {
-		log.Printf("上报事件失败: %v", err)
+	data, _ := json.Marshal(event)
+	if nm.events != nil {
+		nm.events.enqueue(runID, data)
 		return
 	}
-	resp.Body.Close()
+	if err := nm.postEvents(ctx, runID, []json.RawMessage{data}); err != nil {
+		log.Printf("上报事件失败: %v", err)
+	}
 }
 
 // reportError 上报错误并更新状态为失败
 func (nm *NodeManager) reportError(ctx context.Context, runID, errMsg string) {
 	log.Printf("任务 %s 错误: %s", runID, errMsg)
-	nm.reportEvent(ctx, runID, 1, "error", map[string]interface{}{
+	nm.reportEvent(ctx, runID, nm.firstSeq(runID), "error", map[string]interface{}{
 		"code":    "execution_error",
 		"message": errMsg,
 	})
 	nm.updateRunStatus(ctx, runID, "failed")
 }
 
+// firstSeq 任务第一个事件的节点序号（重新分配的任务接在已入库的节点序号之后）
+func (nm *NodeManager) firstSeq(runID string) int {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.seqBase[runID] + 1
+}
+
 // updateRunStatus 更新 Run 状态
+//
+// 携带 node_id：Run 已被 API Server 回收并分配给其他节点时，本节点的迟到上报会被拒绝。
+// 更新前先排空该 Run 的事件队列并上报节点日志，保证二者先于状态入库；API Server 不可达时写入本地缓存。
 func (nm *NodeManager) updateRunStatus(ctx context.Context, runID, status string) {
-	body, _ := json.Marshal(map[string]string{"status": status})
-	req, _ := http.NewRequestWithContext(ctx, "PATCH",
-		nm.config.APIServerURL+"/api/v1/runs/"+runID,
-		bytes.NewReader(body))
+	nm.events.drain(runID)
+	nm.flushNodeLogs(ctx, runID)
+	nm.reportRecord(ctx, runID, spoolRecord{Kind: spoolKindStatus, Status: status})
+}
+
+// reportRecord 上报 Run 状态、产物或节点日志：配置了本地缓存时经事件上报器保证与事件的顺序，否则直接上报
+func (nm *NodeManager) reportRecord(ctx context.Context, runID string, rec spoolRecord) {
+	if nm.events != nil && nm.events.spool != nil {
+		nm.events.report(runID, rec)
+		return
+	}
+	if err := nm.sendSpoolRecord(ctx, runID, rec); err != nil {
+		log.Printf("上报 %s 失败: %v", rec.describe(), err)
+	}
+}
+
+// sendSpoolRecord 上报一条 Run 状态、产物或节点日志记录
+//
+// 错误语义与 postEvents 相同：请求无效、Run 不存在或已分配给其他节点时返回 errEventsRejected，
+// 其他错误可以重试。
+func (nm *NodeManager) sendSpoolRecord(ctx context.Context, runID string, rec spoolRecord) error {
+	var (
+		method = "PATCH"
+		url    = nm.config.APIServerURL + "/api/v1/runs/" + runID
+		body   []byte
+	)
+	switch rec.Kind {
+	case spoolKindStatus:
+		if nm.grpc != nil {
+			return nm.grpc.updateRunStatus(ctx, runID, rec.Status, nm.config.NodeID)
+		}
+		body, _ = json.Marshal(map[string]string{"status": rec.Status, "node_id": nm.config.NodeID})
+	case spoolKindArtifact:
+		method, url, body = "POST", url+"/artifacts", rec.Artifact
+	case spoolKindNodeLog:
+		method, url, body = "POST", url+"/node-logs", rec.Logs
+	default:
+		return fmt.Errorf("%w: unknown spool record %q", errEventsRejected, rec.Kind)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := nm.httpClient.Do(req)
 	if err != nil {
-		log.Printf("更新 Run 状态失败: %v", err)
-		return
+		return err
 	}
 	resp.Body.Close()
+	switch {
+	case resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode < 500 || resp.StatusCode == http.StatusNotImplemented:
+		return fmt.Errorf("%w: status %d", errEventsRejected, resp.StatusCode)
+	default:
+		return fmt.Errorf("status %d", resp.StatusCode)
+	}
 }
 
 // CancelRun 取消正在执行的任务
@@ -696,50 +1540,59 @@ func (nm *NodeManager) CancelRun(runID string) {
 	nm.mu.Lock()
 	defer nm.mu.Unlock()
 
+	delete(nm.waiting, runID)
 	if cancel, ok := nm.running[runID]; ok {
 		cancel()
 		log.Printf("已取消任务: %s", runID)
 	}
 }
 
-// normalizeDriverName 将 agent type 转换为 driver name
-// 支持多种格式的 agent type 名称
-// normalizeAdapterName 将 agent type 转换为 adapter name
-// 支持多种格式的 agent type 名称
-func normalizeAdapterName(agentType string) string {
-	// Agent type 到 adapter name 的映射
-	mapping := map[string]string{
-		"qwen-code": "qwencode-v1",
-		"qwencode":  "qwencode-v1",
-		"qwen":      "qwencode-v1",
-		"gemini":    "gemini-v1",
-		"claude":    "claude-v1",
+// TimeoutRun 终止被 API Server 判定为超时的任务
+//
+// 取消执行上下文，Agent 按优雅取消流程停止（见 cancellation.go），任务随后以 timeout 状态上报。
+func (nm *NodeManager) TimeoutRun(runID string) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	if cancel, ok := nm.running[runID]; ok {
+		if nm.timedOut == nil {
+			nm.timedOut = make(map[string]bool)
+		}
+		nm.timedOut[runID] = true
+		cancel()
+		log.Printf("已终止超时任务: %s", runID)
 	}
+}
 
-	if adapterName, ok := mapping[agentType]; ok {
-		return adapterName
+// interruptedStatus 执行上下文被取消后上报的状态：超时终止为 timeout，否则为 cancelled
+func (nm *NodeManager) interruptedStatus(runID string) string {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+
+	if nm.timedOut[runID] {
+		return "timeout"
 	}
+	return "cancelled"
+}
 
-	// 默认：agentType + "-v1"
-	return agentType + "-v1"
+// normalizeDriverName 将 agent type 转换为 driver name
+// 支持多种格式的 agent type 名称
+// NormalizeAdapterName 将 agent type 转换为 adapter name
+// 支持多种格式的 agent type 名称
+func NormalizeAdapterName(agentType string) string {
+	// 映射规则与 API Server 调度时一致（按适配器能力过滤节点）
+	return model.AdapterNameForAgentType(agentType)
 }
 
 // getContainerForInstance 通过 instance_id 获取容器名称
 func (nm *NodeManager) getContainerForInstance(ctx context.Context, instanceID string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET",
-		nm.config.APIServerURL+"/api/v1/agents/"+instanceID, nil)
-	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	resp, err := nm.httpClient.Do(req)
+	status, body, err := nm.getCached(ctx, nm.config.APIServerURL+"/api/v1/agents/"+instanceID)
 	if err != nil {
 		return "", fmt.Errorf("请求失败: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API 返回错误状态: %d", resp.StatusCode)
+	if status != http.StatusOK {
+		return "", fmt.Errorf("API 返回错误状态: %d", status)
 	}
 
 	var instance struct {
@@ -749,7 +1602,7 @@ func (nm *NodeManager) getContainerForInstance(ctx context.Context, instanceID s
 		Container string `json:"container"`
 		Status    string `json:"status"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&instance); err != nil {
+	if err := json.Unmarshal(body, &instance); err != nil {
 		return "", fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -789,20 +1642,13 @@ func (nm *NodeManager) getContainerForAccount(ctx context.Context, accountID str
 
 // getContainerFromAPI 从 API Server 获取实例信息
 func (nm *NodeManager) getContainerFromAPI(ctx context.Context, accountID string) (string, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET",
-		nm.config.APIServerURL+"/api/v1/agents?account_id="+accountID, nil)
-	if err != nil {
-		return "", fmt.Errorf("创建请求失败: %w", err)
-	}
-
-	resp, err := nm.httpClient.Do(req)
+	status, body, err := nm.getCached(ctx, nm.config.APIServerURL+"/api/v1/agents?account_id="+accountID)
 	if err != nil {
 		return "", fmt.Errorf("请求失败: %w", err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API 返回错误状态: %d", resp.StatusCode)
+	if status != http.StatusOK {
+		return "", fmt.Errorf("API 返回错误状态: %d", status)
 	}
 
 	var result struct {
@@ -814,7 +1660,7 @@ func (nm *NodeManager) getContainerFromAPI(ctx context.Context, accountID string
 			Status    string `json:"status"`
 		} `json:"agents"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	if err := json.Unmarshal(body, &result); err != nil {
 		return "", fmt.Errorf("解析响应失败: %w", err)
 	}
 
@@ -843,21 +1689,18 @@ func (nm *NodeManager) getContainerFromAPI(ctx context.Context, accountID string
 	return "", fmt.Errorf("没有找到实例")
 }
 
-// findContainerByAccountID 通过 Docker 直接查找容器
+// findContainerByAccountID 通过容器运行时直接查找容器
 // 容器命名规则：agent_inst_{sanitized_account_id}_{timestamp}
 func (nm *NodeManager) findContainerByAccountID(ctx context.Context, accountID string) (string, error) {
-	// 使用 docker ps 查找匹配的容器
 	// 容器名包含 account_id（已 sanitize）
 	sanitized := sanitizeAccountID(accountID)
 
-	cmd := exec.CommandContext(ctx, "docker", "ps", "--format", "{{.Names}}", "--filter", "status=running")
-	output, err := cmd.Output()
+	names, err := nm.config.containers().List(ctx, false)
 	if err != nil {
-		return "", fmt.Errorf("docker ps 失败: %w", err)
+		return "", err
 	}
 
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	for _, name := range lines {
+	for _, name := range names {
 		// 查找包含 account_id 的容器
 		if strings.Contains(name, sanitized) {
 			log.Printf("找到容器 %s (匹配 account_id: %s)", name, accountID)
@@ -878,34 +1721,18 @@ func sanitizeAccountID(accountID string) string {
 
 // copyToContainer 将本地目录复制到容器中
 func (nm *NodeManager) copyToContainer(ctx context.Context, srcPath, containerName, destPath string) error {
-	// 先在容器中创建目标目录
-	mkdirCmd := exec.CommandContext(ctx, "docker", "exec", containerName, "mkdir", "-p", destPath)
-	if output, err := mkdirCmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("创建目录失败: %w, 输出: %s", err, string(output))
+	if err := nm.config.containers().CopyTo(ctx, srcPath, containerName, destPath); err != nil {
+		return err
 	}
-
-	// 使用 docker cp 复制文件
-	// docker cp <src>/ <container>:<dest>
-	// 注意：srcPath 后面加 /. 表示复制目录内容而不是目录本身
-	cmd := exec.CommandContext(ctx, "docker", "cp", srcPath+"/.", containerName+":"+destPath)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("docker cp 失败: %w, 输出: %s", err, string(output))
-	}
-
 	log.Printf("[Workspace] 复制完成: %s -> %s:%s", srcPath, containerName, destPath)
 	return nil
 }
 
 // copyFromContainer 从容器中复制文件到本地
 func (nm *NodeManager) copyFromContainer(ctx context.Context, containerName, srcPath, destPath string) error {
-	// 使用 docker cp 复制文件
-	cmd := exec.CommandContext(ctx, "docker", "cp", containerName+":"+srcPath, destPath)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("docker cp 失败: %w, 输出: %s", err, string(output))
+	if err := nm.config.containers().CopyFrom(ctx, containerName, srcPath, destPath); err != nil {
+		return err
 	}
-
 	log.Printf("[Workspace] 复制完成: %s:%s -> %s", containerName, srcPath, destPath)
 	return nil
 }
@@ -3,6 +3,10 @@ package nodemanager
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
@@ -104,6 +108,86 @@ func TestCancelNonExistentRun(t *testing.T) {
 	executor.CancelRun("non-existent")
 }
 
+// TestTimeoutRun 测试超时终止：上下文被取消且上报状态为 timeout
+func TestTimeoutRun(t *testing.T) {
+	executor, err := NewNodeManager(Config{
+		NodeID:       "test-node",
+		APIServerURL: "http://localhost:8080",
+		WorkspaceDir: "/tmp/test-workspace",
+	})
+	if err != nil {
+		t.Skipf("Docker not available: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	executor.running["run-slow"] = cancel
+	executor.running["run-cancelled"] = func() {}
+
+	executor.TimeoutRun("run-slow")
+	executor.CancelRun("run-cancelled")
+
+	select {
+	case <-ctx.Done():
+	default:
+		t.Error("Expected context to be cancelled")
+	}
+	if got := executor.interruptedStatus("run-slow"); got != "timeout" {
+		t.Errorf("interruptedStatus(run-slow) = %q, want timeout", got)
+	}
+	if got := executor.interruptedStatus("run-cancelled"); got != "cancelled" {
+		t.Errorf("interruptedStatus(run-cancelled) = %q, want cancelled", got)
+	}
+
+	// 不存在的任务不记录超时
+	executor.TimeoutRun("non-existent")
+	if executor.timedOut["non-existent"] {
+		t.Error("non-existent run should not be marked as timed out")
+	}
+}
+
+// TestCheckAndExecuteRuns_SkipsLostRunning 不在内存中的 running 任务不重新执行（由 API Server 回收）
+func TestCheckAndExecuteRuns_SkipsLostRunning(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"runs": [{"id": "run-lost", "status": "running"}], "count": 1}`))
+	}))
+	defer srv.Close()
+
+	executor, err := NewNodeManager(Config{
+		NodeID:       "test-node",
+		APIServerURL: srv.URL,
+		WorkspaceDir: "/tmp/test-workspace",
+	})
+	if err != nil {
+		t.Skipf("Docker not available: %v", err)
+	}
+
+	executor.checkAndExecuteRuns(context.Background())
+	if _, ok := executor.running["run-lost"]; ok {
+		t.Error("running run not tracked in memory should not be re-executed")
+	}
+}
+
+// TestFirstSeq 重新分配的任务从已有事件之后继续编号
+func TestFirstSeq(t *testing.T) {
+	executor, err := NewNodeManager(Config{
+		NodeID:       "test-node",
+		APIServerURL: "http://localhost:8080",
+		WorkspaceDir: "/tmp/test-workspace",
+	})
+	if err != nil {
+		t.Skipf("Docker not available: %v", err)
+	}
+
+	executor.seqBase = map[string]int{"run-reassigned": 6}
+	if got := executor.firstSeq("run-reassigned"); got != 7 {
+		t.Errorf("firstSeq(run-reassigned) = %d, want 7", got)
+	}
+	if got := executor.firstSeq("run-new"); got != 1 {
+		t.Errorf("firstSeq(run-new) = %d, want 1", got)
+	}
+}
+
 // mockAdapter 用于测试的 Mock Adapter
 type mockAdapter struct {
 	name string
@@ -125,6 +209,9 @@ func (m *mockAdapter) ParseEvent(line string) (*adapter.CanonicalEvent, error) {
 func (m *mockAdapter) CollectArtifacts(ctx context.Context, workDir string) (*adapter.Artifacts, error) {
 	return &adapter.Artifacts{}, nil
 }
+func (m *mockAdapter) Detect(ctx context.Context) (*adapter.Capabilities, error) {
+	return &adapter.Capabilities{Version: "1.0.0", Features: []string{adapter.FeatureStreaming}}, nil
+}
 
 // TestConfigFields 测试配置字段
 func TestConfigFields(t *testing.T) {
@@ -168,3 +255,31 @@ func TestExecutorHTTPClientTimeout(t *testing.T) {
 		t.Errorf("HTTP client timeout = %v, want 30s", executor.httpClient.Timeout)
 	}
 }
+
+// usageAdapter 从 run_completed 事件报告用量的测试 Adapter
+type usageAdapter struct {
+	jsonLineAdapter
+}
+
+func (usageAdapter) ExtractUsage(event *adapter.CanonicalEvent) *adapter.Usage {
+	if event.Type != adapter.EventRunCompleted {
+		return nil
+	}
+	return &adapter.Usage{Model: "m1", InputTokens: 100, OutputTokens: 20, CostUSD: 0.5}
+}
+
+// TestStreamOutput_Usage 提取到用量时紧随原事件上报 usage 事件
+func TestStreamOutput_Usage(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	output := `{"type":"message","payload":{"content":"hi"}}` + "\n" + `{"type":"run_completed","payload":{}}` + "\n"
+	seq := nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), usageAdapter{}, nil, nil, 1)
+
+	got := eventTypes(events())
+	if want := []string{"message", "run_completed", "usage"}; !slices.Equal(got, want) || seq != 4 {
+		t.Fatalf("events = %v (seq %d), want %v", got, seq, want)
+	}
+	payload := events()[2]["payload"].(map[string]interface{})
+	if payload["model"] != "m1" || payload["input_tokens"] != float64(100) || payload["cost_usd"] != 0.5 {
+		t.Errorf("usage payload = %v", payload)
+	}
+}
@@ -0,0 +1,120 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 节点日志（Run 在 NodeManager 侧的诊断信息）
+//
+// Workspace 准备、容器定位、Agent 进程 stderr 等信息原先只写入节点的系统日志，Run 在 Agent 启动前失败时
+// 只能登录节点排查。这些日志在写入系统日志的同时按 Run 缓存，Run 上报状态前（见 updateRunStatus）
+// 与执行结束时（见 forgetRun）经事件上报器发送到 POST /api/v1/runs/{id}/node-logs，
+// 通过 GET /api/v1/runs/{id}/node-logs 查询。
+//
+// 节点日志不占用事件的节点序号：事件序号由执行流程逐个传递，Agent 启动前的诊断日志无法插入其中。
+
+// 节点日志级别
+const (
+	nodeLogInfo  = "info"
+	nodeLogWarn  = "warn"
+	nodeLogError = "error"
+)
+
+// 节点日志所处阶段
+const (
+	nodeLogStageWorkspace = "workspace" // Workspace 准备与复制
+	nodeLogStageContainer = "container" // 执行容器定位
+)
+
+const (
+	// maxNodeLogEntries 每个 Run 缓存的节点日志条数上限（超出时丢弃较早的日志）
+	maxNodeLogEntries = 200
+	// maxNodeLogMessage 单条节点日志的长度上限（字节，低于 API Server 的 8KB 截断上限），
+	// 超出时保留末尾（stderr 的末尾通常是错误原因）
+	maxNodeLogMessage = 4 << 10
+)
+
+// nodeLogEntry 一条节点日志（POST /api/v1/runs/{id}/node-logs 请求体中的元素）
+type nodeLogEntry struct {
+	Timestamp time.Time `json:"timestamp"`
+	Level     string    `json:"level"`
+	Stage     string    `json:"stage"`
+	Message   string    `json:"message"`
+}
+
+// nodeLogBuffer 按 Run 缓存待上报的节点日志
+type nodeLogBuffer struct {
+	mu      sync.Mutex
+	entries map[string][]nodeLogEntry
+	dropped map[string]int
+}
+
+// add 缓存一条日志，超出上限时丢弃最早的一条
+func (b *nodeLogBuffer) add(runID string, e nodeLogEntry) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.entries == nil {
+		b.entries = make(map[string][]nodeLogEntry)
+		b.dropped = make(map[string]int)
+	}
+	entries := append(b.entries[runID], e)
+	if len(entries) > maxNodeLogEntries {
+		entries = entries[1:]
+		b.dropped[runID]++
+	}
+	b.entries[runID] = entries
+}
+
+// take 取出 Run 缓存的日志；有丢弃时在开头补充一条说明
+func (b *nodeLogBuffer) take(runID string) []nodeLogEntry {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entries, dropped := b.entries[runID], b.dropped[runID]
+	delete(b.entries, runID)
+	delete(b.dropped, runID)
+	if dropped > 0 && len(entries) > 0 {
+		note := nodeLogEntry{Timestamp: entries[0].Timestamp, Level: nodeLogWarn, Stage: entries[0].Stage,
+			Message: fmt.Sprintf("超出缓存上限 %d 条，已丢弃 %d 条较早的节点日志", maxNodeLogEntries, dropped)}
+		entries = append([]nodeLogEntry{note}, entries...)
+	}
+	return entries
+}
+
+// runLog 记录 Run 的一条节点日志：写入系统日志，并缓存等待上报
+//
+// 内容按 Run 引用的密钥脱敏（脱敏器登记之前的日志不含密钥）。
+func (nm *NodeManager) runLog(runID, level, stage, format string, args ...interface{}) {
+	msg := nm.secretMaskerFor(runID).mask(fmt.Sprintf(format, args...))
+	log.Printf("[%s] 任务 %s %s", stage, runID, msg)
+	if len(msg) > maxNodeLogMessage {
+		msg = "…" + strings.ToValidUTF8(msg[len(msg)-maxNodeLogMessage:], "")
+	}
+	nm.nodeLogs.add(runID, nodeLogEntry{Timestamp: time.Now(), Level: level, Stage: stage, Message: msg})
+}
+
+// logAgentStderr 记录 Agent 进程的 stderr 输出（阶段为执行后端名称，Agent 异常退出时为 error 级别）
+func (nm *NodeManager) logAgentStderr(runID, backend, stderr string, failed bool) {
+	level := nodeLogInfo
+	if failed {
+		level = nodeLogError
+	}
+	nm.runLog(runID, level, backend, "stderr 输出: %s", stderr)
+}
+
+// flushNodeLogs 上报 Run 缓存的节点日志
+//
+// 与 Run 状态、产物一样经事件上报器发送，配置了本地缓存时 API Server 不可达的日志写入缓存按顺序回放。
+func (nm *NodeManager) flushNodeLogs(ctx context.Context, runID string) {
+	entries := nm.nodeLogs.take(runID)
+	if len(entries) == 0 {
+		return
+	}
+	body, _ := json.Marshal(map[string]interface{}{"logs": entries})
+	nm.reportRecord(ctx, runID, spoolRecord{Kind: spoolKindNodeLog, Logs: body})
+}
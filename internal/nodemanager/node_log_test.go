@@ -0,0 +1,58 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestNodeLogs_Flush 节点日志脱敏后按 Run 缓存，上报到 node-logs 接口后清空；超出条数上限时丢弃较早的日志
+func TestNodeLogs_Flush(t *testing.T) {
+	var paths []string
+	var posted []nodeLogEntry
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.Method+" "+r.URL.Path)
+		var body struct {
+			Logs []nodeLogEntry `json:"logs"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		posted = append(posted, body.Logs...)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client()}
+	nm.setSecretMasker("run-1", newSecretMasker(map[string]string{"GIT_TOKEN": "ghp-secret-123"}))
+	nm.runLog("run-1", nodeLogError, nodeLogStageWorkspace, "git clone https://x:%s@example.com/repo.git failed", "ghp-secret-123")
+	nm.logAgentStderr("run-1", "docker", strings.Repeat("a", maxNodeLogMessage)+"fatal: boom", true)
+	nm.runLog("run-2", nodeLogInfo, nodeLogStageContainer, "将在容器 %s 中执行", "c1")
+
+	nm.flushNodeLogs(context.Background(), "run-1")
+	nm.flushNodeLogs(context.Background(), "run-1") // 已清空，不再上报
+	if len(paths) != 1 || paths[0] != "POST /api/v1/runs/run-1/node-logs" {
+		t.Fatalf("requests = %v", paths)
+	}
+	if len(posted) != 2 {
+		t.Fatalf("posted = %+v", posted)
+	}
+	if strings.Contains(posted[0].Message, "ghp-secret-123") || posted[0].Level != "error" || posted[0].Stage != "workspace" {
+		t.Errorf("posted[0] = %+v", posted[0])
+	}
+	if !strings.HasSuffix(posted[1].Message, "fatal: boom") || len(posted[1].Message) > maxNodeLogMessage+len("…") || posted[1].Stage != "docker" {
+		t.Errorf("stderr 应保留末尾, len=%d stage=%s", len(posted[1].Message), posted[1].Stage)
+	}
+
+	for i := 0; i < maxNodeLogEntries+5; i++ {
+		nm.runLog("run-3", nodeLogInfo, nodeLogStageContainer, "line %d", i)
+	}
+	entries := nm.nodeLogs.take("run-3")
+	if len(entries) != maxNodeLogEntries+1 || !strings.Contains(entries[0].Message, "已丢弃 5 条") || entries[1].Message != "line 5" {
+		t.Errorf("entries = %d, first = %+v", len(entries), entries[:2])
+	}
+	if got := nm.nodeLogs.take("run-2"); len(got) != 1 {
+		t.Errorf("run-2 entries = %+v", got)
+	}
+}
@@ -0,0 +1,144 @@
+// Package nodemanager 节点系统资源指标采集
+//
+// 每次心跳采集 CPU 使用率（/proc/stat 两次采样的差值）、内存（/proc/meminfo 的 MemTotal 与 MemAvailable）、
+// 工作空间所在文件系统的磁盘用量与系统负载（/proc/loadavg），随心跳 capacity.metrics 上报。
+// API Server 将采样写入 Redis 滑动窗口，least_loaded 调度策略按窗口内的平均利用率选择节点。
+// 非 Linux 平台读取不到的指标为 0。
+package nodemanager
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	nodev1 "agents-admin/api/generated/proto/nodev1"
+	"agents-admin/internal/shared/model"
+)
+
+// metricsCollector 节点资源指标采集器（保存上一次的 CPU 计数用于计算使用率）
+type metricsCollector struct {
+	diskPath string
+	procDir  string
+
+	mu        sync.Mutex
+	prevIdle  uint64
+	prevTotal uint64
+}
+
+func newMetricsCollector(diskPath string) *metricsCollector {
+	if diskPath == "" {
+		diskPath = "/"
+	}
+	return &metricsCollector{diskPath: diskPath, procDir: "/proc"}
+}
+
+// collect 采集一次资源指标（首次采集时 CPU 使用率为自开机以来的平均值）
+func (c *metricsCollector) collect() *model.NodeMetrics {
+	m := &model.NodeMetrics{CPUs: runtime.NumCPU(), CollectedAt: time.Now()}
+
+	if f, err := os.Open(c.procDir + "/stat"); err == nil {
+		idle, total := parseCPUStat(f)
+		f.Close()
+		c.mu.Lock()
+		if dt := total - c.prevTotal; total > c.prevTotal {
+			m.CPUPercent = round2(float64(dt-(idle-c.prevIdle)) / float64(dt) * 100)
+		}
+		c.prevIdle, c.prevTotal = idle, total
+		c.mu.Unlock()
+	}
+
+	if f, err := os.Open(c.procDir + "/meminfo"); err == nil {
+		total, available := parseMemInfo(bufio.NewScanner(f))
+		f.Close()
+		if total > 0 {
+			m.MemoryTotalBytes, m.MemoryUsedBytes = total, total-available
+			m.MemoryPercent = round2(float64(m.MemoryUsedBytes) / float64(total) * 100)
+		}
+	}
+
+	if data, err := os.ReadFile(c.procDir + "/loadavg"); err == nil {
+		m.Load1, m.Load5, m.Load15 = parseLoadAvg(string(data))
+	}
+
+	if total, free, ok := diskUsage(c.diskPath); ok && total > 0 {
+		m.DiskTotalBytes, m.DiskUsedBytes = total, total-free
+		m.DiskPercent = round2(float64(m.DiskUsedBytes) / float64(total) * 100)
+	}
+	return m
+}
+
+// parseCPUStat 解析 /proc/stat 首行的累计 CPU 时间，返回空闲（idle + iowait）与总计
+func parseCPUStat(r io.Reader) (idle, total uint64) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() {
+		return 0, 0
+	}
+	fields := strings.Fields(sc.Text())
+	if len(fields) < 5 || fields[0] != "cpu" {
+		return 0, 0
+	}
+	for i, f := range fields[1:] {
+		v, _ := strconv.ParseUint(f, 10, 64)
+		// guest / guest_nice 已计入 user / nice
+		if i >= 8 {
+			break
+		}
+		total += v
+		if i == 3 || i == 4 {
+			idle += v
+		}
+	}
+	return idle, total
+}
+
+// parseMemInfo 从 /proc/meminfo 内容中解析 MemTotal 与 MemAvailable（字节）
+func parseMemInfo(sc *bufio.Scanner) (total, available int64) {
+	for sc.Scan() {
+		fields := strings.Fields(sc.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, _ := strconv.ParseInt(fields[1], 10, 64)
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available = kb * 1024
+		}
+	}
+	return total, available
+}
+
+// parseLoadAvg 解析 /proc/loadavg 的 1/5/15 分钟负载
+func parseLoadAvg(s string) (load1, load5, load15 float64) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 {
+		return 0, 0, 0
+	}
+	load1, _ = strconv.ParseFloat(fields[0], 64)
+	load5, _ = strconv.ParseFloat(fields[1], 64)
+	load15, _ = strconv.ParseFloat(fields[2], 64)
+	return load1, load5, load15
+}
+
+// metricsToProto 转换为 gRPC 心跳的指标消息（nil 时不上报）
+func metricsToProto(m *model.NodeMetrics) *nodev1.NodeMetrics {
+	if m == nil {
+		return nil
+	}
+	return &nodev1.NodeMetrics{
+		CpuPercent: m.CPUPercent, MemoryUsedBytes: m.MemoryUsedBytes, MemoryTotalBytes: m.MemoryTotalBytes,
+		MemoryPercent: m.MemoryPercent, DiskUsedBytes: m.DiskUsedBytes, DiskTotalBytes: m.DiskTotalBytes,
+		DiskPercent: m.DiskPercent, Load1: m.Load1, Load5: m.Load5, Load15: m.Load15,
+		Cpus: int32(m.CPUs), CollectedAtMs: m.CollectedAt.UnixMilli(),
+	}
+}
+
+func round2(v float64) float64 {
+	return float64(int64(v*100+0.5)) / 100
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package nodemanager
+
+// diskUsage 其他平台不采集磁盘用量
+func diskUsage(path string) (total, free int64, ok bool) {
+	return 0, 0, false
+}
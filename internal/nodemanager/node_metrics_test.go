@@ -0,0 +1,60 @@
+package nodemanager
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCPUStat(t *testing.T) {
+	idle, total := parseCPUStat(strings.NewReader("cpu  100 0 50 800 50 0 0 0 10 0\ncpu0 1 2 3 4\n"))
+	if idle != 850 || total != 1000 {
+		t.Errorf("idle=%d total=%d, want 850/1000", idle, total)
+	}
+	if idle, total := parseCPUStat(strings.NewReader("intr 1 2 3\n")); idle != 0 || total != 0 {
+		t.Errorf("non-cpu line: idle=%d total=%d", idle, total)
+	}
+}
+
+func TestParseLoadAvg(t *testing.T) {
+	l1, l5, l15 := parseLoadAvg("0.52 1.25 2.00 3/512 12345\n")
+	if l1 != 0.52 || l5 != 1.25 || l15 != 2 {
+		t.Errorf("load = %v %v %v", l1, l5, l15)
+	}
+}
+
+func TestMetricsCollector_Collect(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("stat", "cpu  100 0 100 800 0 0 0 0\n")
+	write("meminfo", "MemTotal:       4000 kB\nMemFree:         500 kB\nMemAvailable:   1000 kB\n")
+	write("loadavg", "1.50 1.00 0.50 1/100 42\n")
+
+	c := newMetricsCollector(dir)
+	c.procDir = dir
+	c.collect()
+
+	// 第二次采集按差值计算：总计 +200，空闲 +50 → 75%
+	write("stat", "cpu  200 0 150 850 0 0 0 0\n")
+	m := c.collect()
+	if m.CPUPercent != 75 {
+		t.Errorf("cpu = %v, want 75", m.CPUPercent)
+	}
+	if m.MemoryTotalBytes != 4000*1024 || m.MemoryUsedBytes != 3000*1024 || m.MemoryPercent != 75 {
+		t.Errorf("memory = %d/%d %v%%", m.MemoryUsedBytes, m.MemoryTotalBytes, m.MemoryPercent)
+	}
+	if m.Load1 != 1.5 || m.Load15 != 0.5 {
+		t.Errorf("load = %v %v", m.Load1, m.Load15)
+	}
+	if m.CPUs <= 0 || m.CollectedAt.IsZero() {
+		t.Errorf("cpus=%d collected_at=%v", m.CPUs, m.CollectedAt)
+	}
+	if pb := metricsToProto(m); pb.CpuPercent != 75 || pb.CollectedAtMs != m.CollectedAt.UnixMilli() {
+		t.Errorf("proto = %+v", pb)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build linux || darwin
+
+package nodemanager
+
+import "syscall"
+
+// diskUsage 返回 path 所在文件系统的总容量与非特权用户可用容量（字节）
+func diskUsage(path string) (total, free int64, ok bool) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return 0, 0, false
+	}
+	return int64(st.Blocks) * int64(st.Bsize), int64(st.Bavail) * int64(st.Bsize), true
+}
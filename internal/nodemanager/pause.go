@@ -0,0 +1,174 @@
+// Package nodemanager Run 暂停与恢复
+//
+// 用户通过 API 暂停 Run 时，API Server 将 Run 标记为 paused，并在节点心跳指令 pause_runs 中下发；
+// 节点冻结执行目标（docker 后端 pause 实例容器，process 后端向进程组发送 SIGSTOP），
+// 之后随心跳上报 paused_runs。用户恢复后 Run 回到 running，心跳指令 resume_runs 通知节点恢复执行。
+//
+// 人工审批（approval.go）与用户暂停共用 runPauser：任一方仍持有暂停时执行目标保持冻结。
+// Run 被取消或超时终止时先恢复执行目标，避免容器保持冻结。
+package nodemanager
+
+import (
+	"context"
+	"log"
+	"os/exec"
+	"sort"
+	"sync"
+)
+
+// 暂停原因
+const (
+	pauseByApproval = "approval" // 等待人工审批
+	pauseByUser     = "user"     // 用户暂停（心跳指令 pause_runs）
+)
+
+// runPauser 单个 Run 执行目标的暂停控制
+type runPauser struct {
+	target execTarget
+	cmd    *exec.Cmd // Agent 命令（process 后端按进程组暂停）
+
+	mu    sync.Mutex
+	holds map[string]bool // 持有暂停的原因
+}
+
+// newRunPauser 创建执行目标的暂停控制
+func newRunPauser(target execTarget, cmd *exec.Cmd) *runPauser {
+	return &runPauser{target: target, cmd: cmd, holds: make(map[string]bool)}
+}
+
+// hold 以指定原因暂停执行目标（已被其他原因暂停时只登记原因）
+func (p *runPauser) hold(ctx context.Context, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.holds[reason] {
+		return nil
+	}
+	if len(p.holds) == 0 {
+		if err := p.target.pause(ctx, p.cmd); err != nil {
+			return err
+		}
+	}
+	p.holds[reason] = true
+	return nil
+}
+
+// release 释放指定原因的暂停，没有其他原因持有时恢复执行目标
+func (p *runPauser) release(ctx context.Context, reason string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.holds[reason] {
+		return nil
+	}
+	delete(p.holds, reason)
+	if len(p.holds) > 0 {
+		return nil
+	}
+	return p.target.resume(ctx, p.cmd)
+}
+
+// releaseAll 释放全部暂停（Run 被终止时）
+func (p *runPauser) releaseAll(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if len(p.holds) == 0 {
+		return nil
+	}
+	p.holds = make(map[string]bool)
+	return p.target.resume(ctx, p.cmd)
+}
+
+// held 是否以指定原因暂停
+func (p *runPauser) held(reason string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.holds[reason]
+}
+
+// registerPauser 登记 Run 的暂停控制（Agent 命令启动后），心跳指令按 Run ID 暂停或恢复
+func (nm *NodeManager) registerPauser(runID string, p *runPauser) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if nm.pausers == nil {
+		nm.pausers = make(map[string]*runPauser)
+	}
+	nm.pausers[runID] = p
+}
+
+func (nm *NodeManager) unregisterPauser(runID string) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	delete(nm.pausers, runID)
+}
+
+func (nm *NodeManager) pauserFor(runID string) *runPauser {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.pausers[runID]
+}
+
+// PauseRun 暂停 Run 的执行（心跳指令 pause_runs），Agent 尚未启动时忽略（下一次心跳重新下发）
+func (nm *NodeManager) PauseRun(ctx context.Context, runID string) {
+	p := nm.pauserFor(runID)
+	if p == nil {
+		return
+	}
+	if err := p.hold(ctx, pauseByUser); err != nil {
+		log.Printf("[Pause] 任务 %s 暂停失败: %v", runID, err)
+		return
+	}
+	log.Printf("[Pause] 任务 %s 已暂停", runID)
+}
+
+// ResumeRun 恢复被用户暂停的 Run（心跳指令 resume_runs）
+func (nm *NodeManager) ResumeRun(ctx context.Context, runID string) {
+	p := nm.pauserFor(runID)
+	if p == nil {
+		return
+	}
+	if err := p.release(ctx, pauseByUser); err != nil {
+		log.Printf("[Pause] 任务 %s 恢复失败: %v", runID, err)
+		return
+	}
+	log.Printf("[Pause] 任务 %s 已恢复", runID)
+}
+
+// pausedRuns 被用户暂停的 Run ID（随心跳上报）
+func (nm *NodeManager) pausedRuns() []string {
+	nm.mu.Lock()
+	pausers := make(map[string]*runPauser, len(nm.pausers))
+	for id, p := range nm.pausers {
+		pausers[id] = p
+	}
+	nm.mu.Unlock()
+
+	var ids []string
+	for id, p := range pausers {
+		if p.held(pauseByUser) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// ============================================================================
+// 执行目标的暂停与恢复
+// ============================================================================
+
+// pause 冻结实例容器（容器内所有进程，包括 Agent）
+func (t *dockerTarget) pause(ctx context.Context, _ *exec.Cmd) error {
+	return t.rt.Pause(ctx, t.container)
+}
+
+func (t *dockerTarget) resume(ctx context.Context, _ *exec.Cmd) error {
+	return t.rt.Unpause(ctx, t.container)
+}
+
+// pause 向 Agent 进程组发送 SIGSTOP
+func (t *processTarget) pause(_ context.Context, cmd *exec.Cmd) error {
+	return suspendGroup(cmd)
+}
+
+func (t *processTarget) resume(_ context.Context, cmd *exec.Cmd) error {
+	return resumeGroup(cmd)
+}
@@ -0,0 +1,53 @@
+package nodemanager
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestRunPauser(t *testing.T) {
+	target := &pausingTarget{}
+	p := newRunPauser(target, nil)
+	ctx := context.Background()
+
+	// 用户暂停与人工审批同时持有时，只有全部释放后才恢复
+	p.hold(ctx, pauseByUser)
+	p.hold(ctx, pauseByApproval)
+	p.hold(ctx, pauseByUser)
+	p.release(ctx, pauseByApproval)
+	if !slices.Equal(target.calls, []string{"pause"}) {
+		t.Fatalf("calls = %v, want a single pause while the user still holds it", target.calls)
+	}
+	p.release(ctx, pauseByUser)
+	p.release(ctx, pauseByUser)
+	if !slices.Equal(target.calls, []string{"pause", "resume"}) {
+		t.Fatalf("calls = %v", target.calls)
+	}
+
+	p.hold(ctx, pauseByApproval)
+	p.releaseAll(ctx)
+	p.releaseAll(ctx)
+	if !slices.Equal(target.calls, []string{"pause", "resume", "pause", "resume"}) {
+		t.Errorf("calls = %v", target.calls)
+	}
+}
+
+func TestNodeManagerPauseDirectives(t *testing.T) {
+	nm := &NodeManager{}
+	target := &pausingTarget{}
+	nm.registerPauser("run-1", newRunPauser(target, nil))
+
+	nm.PauseRun(context.Background(), "run-unknown") // Agent 尚未启动
+	nm.PauseRun(context.Background(), "run-1")
+	if got := nm.pausedRuns(); !slices.Equal(got, []string{"run-1"}) {
+		t.Fatalf("paused runs = %v", got)
+	}
+	nm.ResumeRun(context.Background(), "run-1")
+	if got := nm.pausedRuns(); len(got) != 0 {
+		t.Errorf("paused runs after resume = %v", got)
+	}
+	if !slices.Equal(target.calls, []string{"pause", "resume"}) {
+		t.Errorf("calls = %v", target.calls)
+	}
+}
@@ -0,0 +1,341 @@
+// Package nodemanager 代理健康探测与故障切换
+//
+// 节点定期从 API Server 拉取可用代理（GET /api/v1/nodes/{id}/proxies），逐个建立 TCP 连接并完成
+// CONNECT 握手（HTTP 代理发送 CONNECT 请求，SOCKS5 代理完成协商与 CONNECT），记录延迟后上报
+// （POST /api/v1/nodes/{id}/proxy-health）。认证流程与 Agent 执行按首选代理（Operation 指定的代理或默认代理）
+// 注入代理环境变量，首选代理在本节点探测失败时切换到延迟最低的健康代理。
+package nodemanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	defaultProxyProbeInterval = time.Minute
+	defaultProxyProbeTimeout  = 10 * time.Second
+	defaultProxyProbeTarget   = "www.google.com:443"
+)
+
+// ProxyProbeConfig 代理健康探测配置
+type ProxyProbeConfig struct {
+	Interval time.Duration // 探测间隔（默认 1m，负数表示关闭探测与故障切换）
+	Timeout  time.Duration // 单个代理的探测超时（默认 10s）
+	Target   string        // CONNECT 探测目标 host:port（默认 www.google.com:443）
+}
+
+func (c ProxyProbeConfig) withDefaults() ProxyProbeConfig {
+	if c.Interval == 0 {
+		c.Interval = defaultProxyProbeInterval
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = defaultProxyProbeTimeout
+	}
+	if c.Target == "" {
+		c.Target = defaultProxyProbeTarget
+	}
+	return c
+}
+
+// proxyProber 节点本地的代理列表与探测结果
+type proxyProber struct {
+	cfg     ProxyProbeConfig
+	mu      sync.RWMutex
+	proxies []model.NodeProxy
+	results map[string]model.ProxyProbeResult // proxy_id → 最近一次探测结果
+}
+
+func newProxyProber(cfg ProxyProbeConfig) *proxyProber {
+	return &proxyProber{cfg: cfg.withDefaults(), results: make(map[string]model.ProxyProbeResult)}
+}
+
+// update 替换代理列表并记录探测结果（已删除代理的结果一并清理）
+func (p *proxyProber) update(proxies []model.NodeProxy, results []model.ProxyProbeResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.proxies = proxies
+	p.results = make(map[string]model.ProxyProbeResult, len(results))
+	for _, r := range results {
+		p.results[r.ProxyID] = r
+	}
+}
+
+// resolve 选择本次使用的代理：preferredID 为空或不可用时以默认代理为首选，
+// 首选代理探测失败时切换到延迟最低的健康代理，都不健康时仍使用首选代理。
+// 未指定代理且没有默认代理时返回 nil（不使用代理）。
+func (p *proxyProber) resolve(preferredID string) *model.NodeProxy {
+	if p == nil {
+		return nil
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var preferred *model.NodeProxy
+	for i := range p.proxies {
+		if preferredID != "" && p.proxies[i].ID == preferredID {
+			preferred = &p.proxies[i]
+			break
+		}
+		if p.proxies[i].IsDefault && preferred == nil {
+			preferred = &p.proxies[i]
+		}
+	}
+	if preferred == nil {
+		return nil
+	}
+	// 尚未探测的代理视为可用
+	if r, probed := p.results[preferred.ID]; !probed || r.Healthy {
+		return preferred
+	}
+
+	var fallback *model.NodeProxy
+	var fallbackLatency int64
+	for i := range p.proxies {
+		r, probed := p.results[p.proxies[i].ID]
+		if !probed || !r.Healthy || p.proxies[i].ID == preferred.ID {
+			continue
+		}
+		if fallback == nil || r.LatencyMs < fallbackLatency {
+			fallback, fallbackLatency = &p.proxies[i], r.LatencyMs
+		}
+	}
+	if fallback == nil {
+		log.Printf("[ProxyProbe] 代理 %s 不可用且没有健康的备用代理，继续使用", preferred.ID)
+		return preferred
+	}
+	log.Printf("[ProxyProbe] 代理 %s 不可用，切换到 %s (%dms)", preferred.ID, fallback.ID, fallbackLatency)
+	return fallback
+}
+
+// envFor 返回 resolve 选中代理的环境变量（没有可用代理时为 nil）
+func (p *proxyProber) envFor(preferredID string) []string {
+	if proxy := p.resolve(preferredID); proxy != nil {
+		return proxy.Env
+	}
+	return nil
+}
+
+// proxyProbeLoop 定期探测代理并上报结果
+func (nm *NodeManager) proxyProbeLoop(ctx context.Context) {
+	if nm.proxies == nil || nm.proxies.cfg.Interval < 0 {
+		return
+	}
+	ticker := time.NewTicker(nm.proxies.cfg.Interval)
+	defer ticker.Stop()
+
+	nm.probeProxies(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			nm.probeProxies(ctx)
+		}
+	}
+}
+
+// probeProxies 拉取代理列表，并发探测后更新本地结果并上报 API Server
+func (nm *NodeManager) probeProxies(ctx context.Context) {
+	proxies, err := nm.fetchNodeProxies(ctx)
+	if err != nil {
+		log.Printf("[ProxyProbe] 获取代理列表失败: %v", err)
+		return
+	}
+
+	results := make([]model.ProxyProbeResult, len(proxies))
+	var wg sync.WaitGroup
+	for i := range proxies {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = probeProxy(ctx, proxies[i], nm.proxies.cfg.Target, nm.proxies.cfg.Timeout)
+		}(i)
+	}
+	wg.Wait()
+	nm.proxies.update(proxies, results)
+	if len(results) == 0 {
+		return
+	}
+
+	body, _ := json.Marshal(map[string]interface{}{"results": results})
+	req, _ := http.NewRequestWithContext(ctx, "POST",
+		fmt.Sprintf("%s/api/v1/nodes/%s/proxy-health", nm.config.APIServerURL, nm.config.NodeID),
+		bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[ProxyProbe] 上报探测结果失败: %v", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		log.Printf("[ProxyProbe] 上报探测结果失败: %d", resp.StatusCode)
+	}
+}
+
+// fetchNodeProxies 获取本节点可用的代理（API Server 不支持时返回空列表）
+func (nm *NodeManager) fetchNodeProxies(ctx context.Context) ([]model.NodeProxy, error) {
+	req, _ := http.NewRequestWithContext(ctx, "GET",
+		fmt.Sprintf("%s/api/v1/nodes/%s/proxies", nm.config.APIServerURL, nm.config.NodeID), nil)
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	var result struct {
+		Proxies []model.NodeProxy `json:"proxies"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+	return result.Proxies, nil
+}
+
+// probeProxy 通过代理向 target 建立隧道，记录握手完成的耗时
+func probeProxy(ctx context.Context, proxy model.NodeProxy, target string, timeout time.Duration) model.ProxyProbeResult {
+	result := model.ProxyProbeResult{ProxyID: proxy.ID}
+	start := time.Now()
+	err := connectThroughProxy(ctx, proxy.URL, target, timeout)
+	result.LatencyMs = time.Since(start).Milliseconds()
+	result.CheckedAt = time.Now()
+	if err != nil {
+		result.Error = err.Error()
+	} else {
+		result.Healthy = true
+	}
+	return result
+}
+
+// connectThroughProxy 连接代理并完成到 target 的 CONNECT 握手
+func connectThroughProxy(ctx context.Context, proxyURL, target string, timeout time.Duration) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy url: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", u.Host)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+
+	if u.Scheme == "socks5" {
+		return socks5Connect(conn, u.User, target)
+	}
+	return httpConnect(conn, u.User, target)
+}
+
+// httpConnect 发送 HTTP CONNECT 请求，代理返回 200 即隧道建立成功
+func httpConnect(conn net.Conn, user *url.Userinfo, target string) error {
+	req := &http.Request{
+		Method: http.MethodConnect,
+		URL:    &url.URL{Opaque: target},
+		Host:   target,
+		Header: make(http.Header),
+	}
+	if user != nil {
+		password, _ := user.Password()
+		req.Header.Set("Proxy-Authorization",
+			"Basic "+base64.StdEncoding.EncodeToString([]byte(user.Username()+":"+password)))
+	}
+	if err := req.Write(conn); err != nil {
+		return err
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("CONNECT %s: %s", target, resp.Status)
+	}
+	return nil
+}
+
+// socks5Connect 完成 SOCKS5 协商（无认证或用户名密码认证）与 CONNECT 请求（RFC 1928/1929）
+func socks5Connect(conn net.Conn, user *url.Userinfo, target string) error {
+	host, portStr, err := net.SplitHostPort(target)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil || len(host) > 255 {
+		return fmt.Errorf("invalid target %q", target)
+	}
+
+	methods := []byte{0x00}
+	if user != nil {
+		methods = append(methods, 0x02)
+	}
+	if _, err := conn.Write(append([]byte{0x05, byte(len(methods))}, methods...)); err != nil {
+		return err
+	}
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return err
+	}
+	if reply[0] != 0x05 {
+		return errors.New("not a socks5 proxy")
+	}
+	switch reply[1] {
+	case 0x00:
+	case 0x02:
+		if user == nil {
+			return errors.New("socks5 proxy requires authentication")
+		}
+		password, _ := user.Password()
+		auth := append([]byte{0x01, byte(len(user.Username()))}, user.Username()...)
+		auth = append(append(auth, byte(len(password))), password...)
+		if _, err := conn.Write(auth); err != nil {
+			return err
+		}
+		if _, err := io.ReadFull(conn, reply); err != nil {
+			return err
+		}
+		if reply[1] != 0x00 {
+			return errors.New("socks5 authentication failed")
+		}
+	default:
+		return errors.New("socks5 proxy has no acceptable auth method")
+	}
+
+	req := append([]byte{0x05, 0x01, 0x00, 0x03, byte(len(host))}, host...)
+	req = append(req, byte(port>>8), byte(port))
+	if _, err := conn.Write(req); err != nil {
+		return err
+	}
+	head := make([]byte, 4)
+	if _, err := io.ReadFull(conn, head); err != nil {
+		return err
+	}
+	if head[1] != 0x00 {
+		return fmt.Errorf("socks5 CONNECT %s failed: code %d", target, head[1])
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package nodemanager
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// serveFake 在本地端口上运行单连接处理函数，返回监听地址
+func serveFake(t *testing.T, handle func(net.Conn)) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				handle(conn)
+			}()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestConnectThroughProxy_HTTP(t *testing.T) {
+	addr := serveFake(t, func(conn net.Conn) {
+		req, err := http.ReadRequest(bufio.NewReader(conn))
+		if err != nil {
+			return
+		}
+		if req.Method != http.MethodConnect || req.Host != "example.com:443" {
+			io.WriteString(conn, "HTTP/1.1 400 Bad Request\r\n\r\n")
+			return
+		}
+		if req.Header.Get("Proxy-Authorization") != "Basic dTpw" { // u:p
+			io.WriteString(conn, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+			return
+		}
+		io.WriteString(conn, "HTTP/1.1 200 Connection established\r\n\r\n")
+	})
+	ctx := context.Background()
+
+	if err := connectThroughProxy(ctx, "http://u:p@"+addr, "example.com:443", time.Second); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	err := connectThroughProxy(ctx, "http://"+addr, "example.com:443", time.Second)
+	if err == nil || !strings.Contains(err.Error(), "407") {
+		t.Errorf("err = %v, want 407", err)
+	}
+}
+
+func TestConnectThroughProxy_SOCKS5(t *testing.T) {
+	addr := serveFake(t, func(conn net.Conn) {
+		head := make([]byte, 2)
+		io.ReadFull(conn, head)
+		methods := make([]byte, head[1])
+		io.ReadFull(conn, methods)
+		conn.Write([]byte{0x05, 0x02})
+		// 用户名密码认证
+		ver := make([]byte, 2)
+		io.ReadFull(conn, ver)
+		user := make([]byte, ver[1])
+		io.ReadFull(conn, user)
+		plen := make([]byte, 1)
+		io.ReadFull(conn, plen)
+		pass := make([]byte, plen[0])
+		io.ReadFull(conn, pass)
+		if string(user) != "u" || string(pass) != "p" {
+			conn.Write([]byte{0x01, 0x01})
+			return
+		}
+		conn.Write([]byte{0x01, 0x00})
+		req := make([]byte, 5)
+		io.ReadFull(conn, req)
+		rest := make([]byte, int(req[4])+2)
+		io.ReadFull(conn, rest)
+		code := byte(0x00)
+		if string(rest[:req[4]]) != "example.com" || rest[len(rest)-1] != 0xbb {
+			code = 0x04
+		}
+		conn.Write([]byte{0x05, code, 0x00, 0x01, 0, 0, 0, 0, 0, 0})
+	})
+	ctx := context.Background()
+
+	if err := connectThroughProxy(ctx, "socks5://u:p@"+addr, "example.com:443", time.Second); err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	if err := connectThroughProxy(ctx, "socks5://u:x@"+addr, "example.com:443", time.Second); err == nil {
+		t.Error("wrong password should fail")
+	}
+	if err := connectThroughProxy(ctx, "socks5://"+addr, "example.com:443", time.Second); err == nil {
+		t.Error("missing credentials should fail")
+	}
+}
+
+func TestProxyProber_Resolve(t *testing.T) {
+	p := newProxyProber(ProxyProbeConfig{})
+	if p.resolve("") != nil {
+		t.Error("no proxies should resolve to nil")
+	}
+
+	p.update([]model.NodeProxy{
+		{ID: "proxy-1", IsDefault: true, Env: []string{"HTTP_PROXY=http://a:1"}},
+		{ID: "proxy-2"},
+		{ID: "proxy-3"},
+	}, nil)
+	if got := p.resolve(""); got == nil || got.ID != "proxy-1" {
+		t.Errorf("unprobed default = %v, want proxy-1", got)
+	}
+	if env := p.envFor("proxy-missing"); len(env) != 1 || env[0] != "HTTP_PROXY=http://a:1" {
+		t.Errorf("env = %v", env)
+	}
+
+	proxies := p.proxies
+	p.update(proxies, []model.ProxyProbeResult{
+		{ProxyID: "proxy-1", Error: "timeout"},
+		{ProxyID: "proxy-2", Healthy: true, LatencyMs: 300},
+		{ProxyID: "proxy-3", Healthy: true, LatencyMs: 80},
+	})
+	if got := p.resolve(""); got == nil || got.ID != "proxy-3" {
+		t.Errorf("failover = %v, want proxy-3", got)
+	}
+	if got := p.resolve("proxy-2"); got == nil || got.ID != "proxy-2" {
+		t.Errorf("healthy preferred = %v, want proxy-2", got)
+	}
+
+	p.update(proxies, []model.ProxyProbeResult{{ProxyID: "proxy-1", Error: "timeout"}, {ProxyID: "proxy-3", Error: "refused"}})
+	if got := p.resolve(""); got == nil || got.ID != "proxy-1" {
+		t.Errorf("no healthy fallback = %v, want proxy-1", got)
+	}
+
+	var nilProber *proxyProber
+	if nilProber.envFor("") != nil {
+		t.Error("nil prober should not inject proxy")
+	}
+}
+
+func TestProbeProxies_Report(t *testing.T) {
+	good := serveFake(t, func(conn net.Conn) {
+		if _, err := http.ReadRequest(bufio.NewReader(conn)); err == nil {
+			io.WriteString(conn, "HTTP/1.1 200 OK\r\n\r\n")
+		}
+	})
+	var reported []model.ProxyProbeResult
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/nodes/node-1/proxies":
+			json.NewEncoder(w).Encode(map[string]interface{}{"proxies": []model.NodeProxy{
+				{ID: "proxy-1", URL: "http://127.0.0.1:1", IsDefault: true},
+				{ID: "proxy-2", URL: "http://" + good},
+			}})
+		case "/api/v1/nodes/node-1/proxy-health":
+			var body struct {
+				Results []model.ProxyProbeResult `json:"results"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			reported = body.Results
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	nm := &NodeManager{
+		config:     Config{NodeID: "node-1", APIServerURL: srv.URL},
+		httpClient: srv.Client(),
+		proxies:    newProxyProber(ProxyProbeConfig{Timeout: time.Second}),
+	}
+	nm.probeProxies(context.Background())
+
+	if len(reported) != 2 || reported[0].Healthy || !reported[1].Healthy {
+		t.Fatalf("reported = %+v", reported)
+	}
+	if got := nm.proxies.resolve(""); got == nil || got.ID != "proxy-2" {
+		t.Errorf("resolve = %v, want proxy-2", got)
+	}
+}
@@ -0,0 +1,211 @@
+// Package nodemanager 父子任务上下文传递
+//
+// 执行快照 inherited_context 中的上下文项（父任务产出的文件、摘要与引用）在 Agent 启动前写入执行目标工作目录的
+// .agent/context/<type>/<name>，并生成说明文件 README.md；Prompt 中附带 contextPromptHint 提示 Agent 读取。
+//
+// Run 成功结束后收集 Agent 写入 .agent/context/produced/ 的文件，调用 POST /api/v1/runs/{id}/context 上报为任务的
+// 产出上下文（produced/summary/、produced/reference/ 下的文件为对应类型，其余为 file），并上报 context_produced 事件。
+// 无论成功与否，Run 结束时删除 .agent/context/，避免上下文被回写到 Git 仓库。
+package nodemanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+const (
+	// contextDir 继承的上下文目录（相对 Agent 工作目录）
+	contextDir = ".agent/context"
+	// producedContextDir Agent 产出上下文的目录
+	producedContextDir = contextDir + "/produced"
+	// maxProducedContextFiles 收集的产出文件数上限
+	maxProducedContextFiles = 50
+	// maxProducedContextSize 单个产出文件大小上限（与 API Server 的上下文项上限一致），超出时跳过
+	maxProducedContextSize = 64 << 10
+)
+
+// contextPromptHint 存在继承的上下文时追加到 Prompt 的说明
+const contextPromptHint = "\n\n上游任务传递的上下文位于 " + contextDir + "/（目录说明见其中的 README.md），开始前请先阅读。" +
+	"需要传递给后续任务的内容请写入 " + producedContextDir + "/。"
+
+// ParseInheritedContext 从任务快照中解析继承的上下文项
+func ParseInheritedContext(snapshot *model.RunSnapshot) []adapter.ContextItem {
+	if len(snapshot.InheritedContext) == 0 {
+		return nil
+	}
+	items := make([]adapter.ContextItem, 0, len(snapshot.InheritedContext))
+	for _, item := range snapshot.InheritedContext {
+		items = append(items, adapter.ContextItem{Type: item.Type, Name: item.Name, Content: item.Content, Source: item.Source})
+	}
+	return items
+}
+
+// contextItemPath 上下文项在工作目录中的路径：.agent/context/<type>/<name>（名称中的目录穿越被去除）
+func contextItemPath(item adapter.ContextItem) string {
+	name := strings.TrimPrefix(path.Clean("/"+item.Name), "/")
+	if name == "" {
+		name = "unnamed"
+	}
+	typ := item.Type
+	if typ == "" || strings.ContainsAny(typ, "/.") {
+		typ = "file"
+	}
+	return contextDir + "/" + typ + "/" + name
+}
+
+// contextReadme 继承上下文的目录说明
+func contextReadme(items []adapter.ContextItem) string {
+	var b strings.Builder
+	b.WriteString("# 继承的上下文\n\n| 类型 | 名称 | 来源任务 | 路径 |\n|------|------|----------|------|\n")
+	for _, item := range items {
+		fmt.Fprintf(&b, "| %s | %s | %s | %s |\n", item.Type, item.Name, item.Source, contextItemPath(item))
+	}
+	b.WriteString("\n需要传递给后续任务的内容写入 " + producedContextDir + "/：summary/ 与 reference/ 下的文件分别作为摘要与引用，其余作为文件。\n")
+	return b.String()
+}
+
+// writeInheritedContext 将继承的上下文写入执行目标的工作目录，返回下一个事件序号
+//
+// 写入失败只上报 warning 事件，不影响 Run 执行。
+func (nm *NodeManager) writeInheritedContext(ctx context.Context, runID string, target execTarget, items []adapter.ContextItem, seq int) int {
+	files := map[string]string{contextDir + "/README.md": contextReadme(items)}
+	for _, item := range items {
+		files[contextItemPath(item)] = item.Content
+	}
+	// 内容通过参数传递：docker exec 未保持 stdin 打开时不转发 stdin
+	script := `mkdir -p "$(dirname "$1")" && printf '%s' "$2" > "$1"`
+	for _, p := range sortedKeys(files) {
+		cmd := target.command(ctx, []string{"sh", "-c", script, "sh", p, files[p]}, nil)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			log.Printf("[Context] 任务 %s 写入上下文 %s 失败: %v", runID, p, err)
+			nm.reportEvent(ctx, runID, seq, "warning", map[string]interface{}{
+				"code":    "context_write_failed",
+				"message": fmt.Sprintf("写入 %s 失败: %v %s", p, err, strings.TrimSpace(string(out))),
+			})
+			seq++
+		}
+	}
+	log.Printf("[Context] 任务 %s 已写入 %d 项继承的上下文", runID, len(items))
+	return seq
+}
+
+// harvestScript 输出 produced 目录下的文件（每个文件为 路径行、大小行、内容；超出大小上限的文件大小为 -1 且不输出内容），
+// 随后删除上下文目录。$1 为 0 时只删除不输出
+const harvestScript = `
+if [ "$1" = 1 ] && [ -d "$2" ]; then
+  (cd "$2" && find . -type f | head -n "$3" | while IFS= read -r f; do
+    s=$(wc -c < "$f" | tr -d ' ')
+    if [ "$s" -gt "$4" ]; then printf '%s\n-1\n' "${f#./}"; continue; fi
+    printf '%s\n%s\n' "${f#./}" "$s"; cat "$f"
+  done)
+fi
+rm -rf "$5"; rmdir "$(dirname "$5")" 2>/dev/null; true`
+
+// concludeContext Run 结束时收集产出的上下文（仅成功结束时）并删除上下文目录，返回下一个事件序号
+func (nm *NodeManager) concludeContext(ctx context.Context, runID string, target execTarget, harvest bool, seq int) int {
+	flag := "0"
+	if harvest {
+		flag = "1"
+	}
+	cmd := target.command(context.WithoutCancel(ctx), []string{"sh", "-c", harvestScript, "sh", flag, producedContextDir,
+		strconv.Itoa(maxProducedContextFiles), strconv.Itoa(maxProducedContextSize), contextDir}, nil)
+	out, err := cmd.Output()
+	if err != nil {
+		log.Printf("[Context] 任务 %s 收集产出的上下文失败: %v", runID, err)
+		return seq
+	}
+	if !harvest {
+		return seq
+	}
+	items, skipped := parseProducedContext(out)
+	for _, name := range skipped {
+		log.Printf("[Context] 任务 %s 产出的上下文 %s 超过 %d 字节，已跳过", runID, name, maxProducedContextSize)
+	}
+	if len(items) == 0 {
+		return seq
+	}
+	if err := nm.postProducedContext(ctx, runID, items); err != nil {
+		log.Printf("[Context] 任务 %s 上报产出的上下文失败: %v", runID, err)
+		nm.reportEvent(ctx, runID, seq, "warning", map[string]interface{}{
+			"code":    "context_produce_failed",
+			"message": err.Error(),
+		})
+		return seq + 1
+	}
+	summary := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		summary = append(summary, map[string]interface{}{"type": item.Type, "name": item.Name, "size": len(item.Content)})
+	}
+	payload := map[string]interface{}{"items": summary}
+	if len(skipped) > 0 {
+		payload["skipped"] = skipped
+	}
+	nm.reportEvent(ctx, runID, seq, "context_produced", payload)
+	log.Printf("[Context] 任务 %s 上报 %d 项产出的上下文", runID, len(items))
+	return seq + 1
+}
+
+// parseProducedContext 解析 harvestScript 的输出，返回上下文项与因超出大小上限跳过的文件
+func parseProducedContext(out []byte) (items []adapter.ContextItem, skipped []string) {
+	r := bufio.NewReader(bytes.NewReader(out))
+	for {
+		name, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		sizeLine, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		name = strings.TrimSuffix(name, "\n")
+		size, err := strconv.Atoi(strings.TrimSpace(sizeLine))
+		if err != nil {
+			return
+		}
+		if size < 0 {
+			skipped = append(skipped, name)
+			continue
+		}
+		content := make([]byte, size)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return
+		}
+		item := adapter.ContextItem{Type: "file", Name: name, Content: string(content)}
+		if typ, rest, ok := strings.Cut(name, "/"); ok && (typ == "summary" || typ == "reference") {
+			item.Type, item.Name = typ, rest
+		}
+		items = append(items, item)
+	}
+}
+
+// postProducedContext 调用 API Server 记录 Run 产出的上下文
+func (nm *NodeManager) postProducedContext(ctx context.Context, runID string, items []adapter.ContextItem) error {
+	data, _ := json.Marshal(map[string]interface{}{"items": items})
+	req, err := http.NewRequestWithContext(ctx, "POST", nm.config.APIServerURL+"/api/v1/runs/"+runID+"/context", bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
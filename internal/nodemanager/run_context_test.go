@@ -0,0 +1,136 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+func TestParseInheritedContext(t *testing.T) {
+	items := ParseInheritedContext(&model.RunSnapshot{InheritedContext: []model.ContextItem{
+		{Type: "summary", Name: "design", Content: "用 REST", Source: "task-parent"},
+	}})
+	if len(items) != 1 || items[0].Type != "summary" || items[0].Source != "task-parent" {
+		t.Fatalf("items = %+v", items)
+	}
+	if ParseInheritedContext(&model.RunSnapshot{}) != nil {
+		t.Error("无继承上下文时应返回 nil")
+	}
+
+	for name, want := range map[string]string{
+		"api.md":           ".agent/context/file/api.md",
+		"../../etc/passwd": ".agent/context/file/etc/passwd",
+		"/abs/x.go":        ".agent/context/file/abs/x.go",
+		"":                 ".agent/context/file/unnamed",
+	} {
+		if got := contextItemPath(adapter.ContextItem{Type: "file", Name: name}); got != want {
+			t.Errorf("contextItemPath(%q) = %s, 期望 %s", name, got, want)
+		}
+	}
+	if got := contextItemPath(adapter.ContextItem{Type: "../x", Name: "a"}); got != ".agent/context/file/a" {
+		t.Errorf("非法类型路径 = %s", got)
+	}
+}
+
+func TestParseProducedContext(t *testing.T) {
+	out := "notes.md\n5\nhello" + "summary/result.md\n2\nok" + "big.bin\n-1\n" + "reference/links\n0\n"
+	items, skipped := parseProducedContext([]byte(out))
+	if len(items) != 3 || len(skipped) != 1 || skipped[0] != "big.bin" {
+		t.Fatalf("items = %+v, skipped = %v", items, skipped)
+	}
+	if items[0] != (adapter.ContextItem{Type: "file", Name: "notes.md", Content: "hello"}) ||
+		items[1] != (adapter.ContextItem{Type: "summary", Name: "result.md", Content: "ok"}) ||
+		items[2] != (adapter.ContextItem{Type: "reference", Name: "links"}) {
+		t.Errorf("items = %+v", items)
+	}
+}
+
+// TestRunContext_WriteAndHarvest 继承的上下文写入工作目录，Run 成功后收集 produced/ 并删除上下文目录
+func TestRunContext_WriteAndHarvest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	var produced []adapter.ContextItem
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/context") {
+			var body struct {
+				Items []adapter.ContextItem `json:"items"`
+			}
+			json.NewDecoder(r.Body).Decode(&body)
+			produced = body.Items
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		var body struct {
+			Events []map[string]interface{} `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		events = append(events, body.Events...)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client()}
+	ctx := context.Background()
+	dir := t.TempDir()
+	target := &dirTarget{dir: dir}
+
+	seq := nm.writeInheritedContext(ctx, "run-1", target, []adapter.ContextItem{
+		{Type: "file", Name: "docs/api.md", Content: "GET /users\n", Source: "task-parent"},
+		{Type: "summary", Name: "plan", Content: "先写接口 'quoted' $HOME", Source: "task-parent"},
+	}, 1)
+	if seq != 1 {
+		t.Errorf("写入不应上报事件: seq = %d", seq)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".agent/context/file/docs/api.md")); string(data) != "GET /users\n" {
+		t.Errorf("file = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".agent/context/summary/plan")); string(data) != "先写接口 'quoted' $HOME" {
+		t.Errorf("summary = %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".agent/context/README.md")); !strings.Contains(string(data), "| summary | plan | task-parent | .agent/context/summary/plan |") {
+		t.Errorf("README = %s", data)
+	}
+
+	// Agent 写入产出的上下文
+	producedDir := filepath.Join(dir, producedContextDir)
+	os.MkdirAll(filepath.Join(producedDir, "summary"), 0o755)
+	os.WriteFile(filepath.Join(producedDir, "summary", "result.md"), []byte("完成了接口\n"), 0o644)
+	os.WriteFile(filepath.Join(producedDir, "schema.sql"), []byte("CREATE TABLE users;"), 0o644)
+	os.WriteFile(filepath.Join(producedDir, "huge.log"), make([]byte, maxProducedContextSize+1), 0o644)
+
+	if seq = nm.concludeContext(ctx, "run-1", target, true, seq); seq != 2 {
+		t.Fatalf("seq = %d, 期望上报 context_produced", seq)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	got := map[string]string{}
+	for _, item := range produced {
+		got[item.Type+":"+item.Name] = item.Content
+	}
+	if len(got) != 2 || got["summary:result.md"] != "完成了接口\n" || got["file:schema.sql"] != "CREATE TABLE users;" {
+		t.Errorf("produced = %+v", produced)
+	}
+	if len(events) != 1 || events[0]["type"] != "context_produced" {
+		t.Fatalf("events = %v", events)
+	}
+	if skipped := events[0]["payload"].(map[string]interface{})["skipped"]; skipped == nil {
+		t.Errorf("超出大小上限的文件应记录在 skipped 中: %v", events[0]["payload"])
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".agent")); !os.IsNotExist(err) {
+		t.Errorf("上下文目录应已删除: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package nodemanager
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"agents-admin/internal/shared/model"
+)
+
+// parseRunSnapshot 解析分配的 Run 的执行快照（run["snapshot"]）
+//
+// 快照结构与 API Server 共用 model.RunSnapshot；旧版本格式先升级为当前格式，
+// 字段缺失或类型不符时返回与 API Server 校验一致的字段错误（见 model.NormalizeSnapshot）。
+func parseRunSnapshot(raw interface{}) (*model.RunSnapshot, error) {
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("任务快照格式错误: %v", err)
+	}
+	data, err = model.NormalizeSnapshot(data)
+	if err != nil {
+		return nil, fmt.Errorf("任务快照格式错误: %v", err)
+	}
+	return model.ParseRunSnapshot(data)
+}
@@ -0,0 +1,174 @@
+// Package nodemanager 运行时密钥注入
+//
+// 任务通过 secrets 按名称引用密钥，执行 Run 时：
+//  1. 通过 POST /api/v1/secrets/resolve 解析明文（节点专属凭证认证），任一密钥缺失则 Run 失败
+//  2. 以 docker exec -e NAME（不带值）注入 Agent 与钩子进程，值仅通过 docker 客户端进程的
+//     环境变量传递，不出现在命令行参数和执行日志中
+//  3. 该 Run 上报的事件（payload 与 raw）中出现的密钥值替换为 ***
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// secretMaskText 密钥值的替换文本
+const secretMaskText = "***"
+
+// ParseSecretNames 从 snapshot 中解析引用的密钥名称
+func ParseSecretNames(snapshot *model.RunSnapshot) []string {
+	if snapshot.Secrets == nil {
+		return nil
+	}
+	names := make([]string, 0, len(snapshot.Secrets))
+	for _, name := range snapshot.Secrets {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// resolveSecrets 通过 API Server 批量解析密钥明文
+// POST /api/v1/secrets/resolve（节点专属凭证认证，只能解析分配给本节点的 Run 引用的密钥）
+func (nm *NodeManager) resolveSecrets(ctx context.Context, names []string) (map[string]string, error) {
+	body, _ := json.Marshal(map[string][]string{"names": names})
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/secrets/resolve", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("解析密钥失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return nil, fmt.Errorf("解析密钥失败: HTTP %d %s", resp.StatusCode, e.Error)
+	}
+
+	var result struct {
+		Secrets map[string]string `json:"secrets"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("解析密钥响应失败: %w", err)
+	}
+	for _, name := range names {
+		if _, ok := result.Secrets[name]; !ok {
+			return nil, fmt.Errorf("解析密钥失败: 缺少 %s", name)
+		}
+	}
+	return result.Secrets, nil
+}
+
+// secretEnvArgs 生成 docker exec 的密钥环境变量参数
+//
+// args 只包含 -e NAME（docker 从客户端进程环境读取值），environ 为需追加到
+// docker 客户端进程的 NAME=value 列表。按名称排序，保证命令稳定。
+func secretEnvArgs(secrets map[string]string) (args []string, environ []string) {
+	names := make([]string, 0, len(secrets))
+	for name := range secrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		args = append(args, "-e", name)
+		environ = append(environ, name+"="+secrets[name])
+	}
+	return args, environ
+}
+
+// secretMasker 将文本中的密钥值替换为 ***（nil 时原样返回）
+type secretMasker struct {
+	values []string
+}
+
+// newSecretMasker 创建脱敏器，无有效密钥值时返回 nil
+func newSecretMasker(secrets map[string]string) *secretMasker {
+	var values []string
+	for _, v := range secrets {
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	// 先替换较长的值，避免一个密钥是另一个的子串时残留片段
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	return &secretMasker{values: values}
+}
+
+// mask 脱敏字符串
+func (m *secretMasker) mask(s string) string {
+	if m == nil || s == "" {
+		return s
+	}
+	for _, v := range m.values {
+		s = strings.ReplaceAll(s, v, secretMaskText)
+	}
+	return s
+}
+
+// maskPayload 递归脱敏事件 payload 中的字符串（返回新的 map，不修改原值）
+func (m *secretMasker) maskPayload(payload map[string]interface{}) map[string]interface{} {
+	if m == nil || payload == nil {
+		return payload
+	}
+	return m.maskValue(payload).(map[string]interface{})
+}
+
+func (m *secretMasker) maskValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return m.mask(val)
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			out[k] = m.maskValue(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			out[i] = m.maskValue(item)
+		}
+		return out
+	}
+	return v
+}
+
+// setSecretMasker 登记 Run 的脱敏器（masker 为 nil 时清除）
+func (nm *NodeManager) setSecretMasker(runID string, masker *secretMasker) {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	if masker == nil {
+		delete(nm.maskers, runID)
+		return
+	}
+	if nm.maskers == nil {
+		nm.maskers = make(map[string]*secretMasker)
+	}
+	nm.maskers[runID] = masker
+}
+
+// secretMaskerFor 获取 Run 的脱敏器，未注入密钥时返回 nil
+func (nm *NodeManager) secretMaskerFor(runID string) *secretMasker {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.maskers[runID]
+}
@@ -0,0 +1,121 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestParseSecretNames(t *testing.T) {
+	snapshot := &model.RunSnapshot{Secrets: []string{"API_TOKEN", "", "DB_PASSWORD"}}
+	names := ParseSecretNames(snapshot)
+	if len(names) != 2 || names[0] != "API_TOKEN" || names[1] != "DB_PASSWORD" {
+		t.Errorf("names = %v", names)
+	}
+	if ParseSecretNames(&model.RunSnapshot{}) != nil {
+		t.Error("未引用密钥时应返回 nil")
+	}
+}
+
+func TestSecretEnvArgs_ValuesNotInArgs(t *testing.T) {
+	args, environ := secretEnvArgs(map[string]string{"B_TOKEN": "bbb", "A_TOKEN": "aaa"})
+	if strings.Join(args, " ") != "-e A_TOKEN -e B_TOKEN" {
+		t.Errorf("args = %v", args)
+	}
+	if strings.Join(environ, " ") != "A_TOKEN=aaa B_TOKEN=bbb" {
+		t.Errorf("environ = %v", environ)
+	}
+	if args, environ := secretEnvArgs(nil); args != nil || environ != nil {
+		t.Errorf("无密钥时应为空: %v %v", args, environ)
+	}
+}
+
+func TestSecretMasker(t *testing.T) {
+	m := newSecretMasker(map[string]string{"SHORT": "abc", "LONG": "abcdef", "EMPTY": ""})
+	if got := m.mask("token=abcdef, other=abc"); got != "token=***, other=***" {
+		t.Errorf("mask = %q", got)
+	}
+
+	payload := map[string]interface{}{
+		"message": "using abcdef",
+		"nested":  map[string]interface{}{"items": []interface{}{"abc", 42}},
+	}
+	masked := m.maskPayload(payload)
+	if masked["message"] != "using ***" {
+		t.Errorf("message = %v", masked["message"])
+	}
+	items := masked["nested"].(map[string]interface{})["items"].([]interface{})
+	if items[0] != "***" || items[1] != 42 {
+		t.Errorf("items = %v", items)
+	}
+	if payload["message"] != "using abcdef" {
+		t.Error("原 payload 不应被修改")
+	}
+
+	var nilMasker *secretMasker
+	if nilMasker.mask("abc") != "abc" || newSecretMasker(map[string]string{"EMPTY": ""}) != nil {
+		t.Error("无密钥时不应脱敏")
+	}
+}
+
+// TestReportEvent_MasksSecrets 注入密钥的 Run 上报事件时脱敏 payload 与 raw
+func TestReportEvent_MasksSecrets(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	nm.setSecretMasker("run-1", newSecretMasker(map[string]string{"API_TOKEN": "sk-live-123"}))
+
+	nm.reportEventWithRaw(context.Background(), "run-1", 2, "message", map[string]interface{}{"content": "echo sk-live-123"}, `{"text":"sk-live-123"}`)
+	nm.reportEvent(context.Background(), "run-2", 2, "message", map[string]interface{}{"content": "sk-live-123"})
+
+	got := events()
+	if len(got) != 2 {
+		t.Fatalf("events = %v", got)
+	}
+	data, _ := json.Marshal(got[0])
+	if strings.Contains(string(data), "sk-live-123") {
+		t.Errorf("事件泄露了密钥: %s", data)
+	}
+	if got[0]["raw"] != `{"text":"***"}` {
+		t.Errorf("raw = %v", got[0]["raw"])
+	}
+	// 其他 Run 不受影响
+	if got[1]["payload"].(map[string]interface{})["content"] != "sk-live-123" {
+		t.Errorf("其他 Run 的事件不应脱敏: %v", got[1])
+	}
+}
+
+func TestResolveSecrets(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Names []string `json:"names"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if r.URL.Path != "/api/v1/secrets/resolve" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		values := map[string]string{}
+		for _, name := range req.Names {
+			if name == "MISSING" {
+				w.WriteHeader(http.StatusNotFound)
+				w.Write([]byte(`{"error":"secret not found: MISSING"}`))
+				return
+			}
+			values[name] = strings.ToLower(name)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"secrets": values})
+	}))
+	defer srv.Close()
+
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client()}
+	secrets, err := nm.resolveSecrets(context.Background(), []string{"API_TOKEN"})
+	if err != nil || secrets["API_TOKEN"] != "api_token" {
+		t.Fatalf("secrets = %v, err = %v", secrets, err)
+	}
+	if _, err := nm.resolveSecrets(context.Background(), []string{"API_TOKEN", "MISSING"}); err == nil || !strings.Contains(err.Error(), "MISSING") {
+		t.Errorf("缺失密钥应返回错误: %v", err)
+	}
+}
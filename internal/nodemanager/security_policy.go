@@ -0,0 +1,151 @@
+// Package nodemanager 安全策略执行
+//
+// 执行快照 security_policy（API Server 按安全策略与任务安全配置计算出的每项平台权限级别，见 secpolicy 包）
+// 在 Agent 启动时由 Adapter 的 PolicyEnforcer 换算为 CLI 参数；运行中由审批闸门执行：
+//   - Agent 调用需审批的权限对应的工具时，暂停执行等待人工审批（见 approval.go）
+//   - Agent 调用被禁止的工具，或 CLI 报告拒绝执行的工具调用时，上报 policy_denied 事件；
+//     CLI 无法执行该禁止（Adapter 未实现 PolicyEnforcer，或列为 unenforced）时终止 Agent，Run 以 failed 结束
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
+)
+
+// ParseSecurityPolicy 从任务快照中解析生效的安全策略（snapshot.security_policy）
+func ParseSecurityPolicy(snapshot *model.RunSnapshot) *secpolicy.Policy {
+	if len(snapshot.SecurityPolicy) == 0 {
+		return nil
+	}
+	var policy secpolicy.Policy
+	if err := json.Unmarshal(snapshot.SecurityPolicy, &policy); err != nil || len(policy.Permissions) == 0 {
+		return nil
+	}
+	return &policy
+}
+
+// policyGuard 单个 Run 的安全策略
+type policyGuard struct {
+	policy     *secpolicy.Policy
+	enforcer   adapter.PolicyEnforcer       // nil 时按事件类型识别权限，被禁止的权限均由 NodeManager 执行
+	denials    adapter.PolicyDenialReporter // CLI 拒绝执行的工具调用（可选）
+	args       []string                     // 追加到启动参数的 CLI 参数
+	unenforced map[string]bool              // CLI 无法执行的被禁止权限
+}
+
+// newPolicyGuard 按 Adapter 能力创建安全策略执行（policy 为 nil 时返回 nil）
+func newPolicyGuard(policy *secpolicy.Policy, a adapter.Adapter) *policyGuard {
+	if policy == nil {
+		return nil
+	}
+	g := &policyGuard{policy: policy, unenforced: make(map[string]bool)}
+	g.denials, _ = a.(adapter.PolicyDenialReporter)
+	unenforced := policy.With(model.ToolPermissionDenied)
+	if e, ok := a.(adapter.PolicyEnforcer); ok {
+		g.enforcer = e
+		g.args, unenforced = e.PolicyArgs(policy)
+	}
+	for _, perm := range unenforced {
+		g.unenforced[perm] = true
+	}
+	return g
+}
+
+// describe run_started 事件中的安全策略
+func (g *policyGuard) describe() map[string]interface{} {
+	d := map[string]interface{}{
+		"sources":     g.policy.Sources,
+		"permissions": g.policy.Permissions,
+	}
+	var unenforced []string
+	for _, perm := range secpolicy.Permissions {
+		if g.unenforced[perm] {
+			unenforced = append(unenforced, perm)
+		}
+	}
+	if len(unenforced) > 0 {
+		d["unenforced"] = unenforced
+	}
+	return d
+}
+
+// classify 事件对应的工具名与平台权限（与权限无关时 permission 为空）
+func (g *policyGuard) classify(event *adapter.CanonicalEvent) (tool, permission string) {
+	if g.enforcer != nil {
+		if tool = g.enforcer.EventTool(event); tool != "" {
+			return tool, g.enforcer.ToolPermission(tool)
+		}
+	} else {
+		tool, _ = event.Payload["tool"].(string)
+	}
+	return tool, adapter.EventPermission(event)
+}
+
+// approval 需审批的权限对应的工具调用的 approval_required 事件内容（不需要审批时返回 nil）
+func (g *policyGuard) approval(event *adapter.CanonicalEvent) map[string]interface{} {
+	if g == nil {
+		return nil
+	}
+	tool, perm := g.classify(event)
+	if perm == "" || g.policy.Level(perm) != model.ToolPermissionApprovalRequired {
+		return nil
+	}
+	operation := tool
+	if operation == "" {
+		operation = string(event.Type)
+	}
+	return map[string]interface{}{
+		"type":       string(model.ApprovalTypeDangerousOp),
+		"operation":  operation,
+		"permission": perm,
+		"reason":     "安全策略要求 " + perm + " 操作前人工审批",
+		"context":    event.Payload,
+	}
+}
+
+// enforcePolicy 上报 Agent 调用被禁止工具的 policy_denied 事件，CLI 无法执行该禁止时终止 Agent，返回下一个事件序号
+func (g *approvalGate) enforcePolicy(ctx context.Context, event *adapter.CanonicalEvent, seq int) int {
+	p := g.policy
+	if p == nil {
+		return seq
+	}
+	if tool, perm := p.classify(event); perm != "" && p.policy.Level(perm) == model.ToolPermissionDenied {
+		enforced := !p.unenforced[perm]
+		seq = g.reportDenied(ctx, seq, tool, perm, enforced)
+		if !enforced && g.violation == "" {
+			g.violation = fmt.Sprintf("安全策略禁止 %s（%s），已终止 Agent", perm, tool)
+			log.Printf("[Policy] 任务 %s 调用了被禁止的工具 %s（%s），终止 Agent", g.runID, tool, perm)
+			g.kill()
+		}
+	}
+	if p.denials != nil {
+		for _, tool := range p.denials.DeniedTools(event) {
+			var perm string
+			if p.enforcer != nil {
+				perm = p.enforcer.ToolPermission(tool)
+			}
+			seq = g.reportDenied(ctx, seq, tool, perm, true)
+		}
+	}
+	return seq
+}
+
+// reportDenied 上报 policy_denied 事件，返回下一个事件序号
+func (g *approvalGate) reportDenied(ctx context.Context, seq int, tool, perm string, enforced bool) int {
+	payload := map[string]interface{}{
+		"tool":     tool,
+		"sources":  g.policy.policy.Sources,
+		"enforced": enforced,
+	}
+	if perm != "" {
+		payload["permission"] = perm
+	}
+	g.nm.reportEvent(ctx, g.runID, seq, string(model.EventTypePolicyDenied), payload)
+	return seq + 1
+}
@@ -0,0 +1,121 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/secpolicy"
+)
+
+// enforcingAdapter 能将 command_execute 换算为 CLI 参数、并报告 CLI 拒绝的调用的测试 Adapter
+type enforcingAdapter struct {
+	jsonLineAdapter
+}
+
+func (enforcingAdapter) PolicyArgs(p *secpolicy.Policy) ([]string, []string) {
+	var unenforced []string
+	if p.Level(secpolicy.PermissionFileDelete) == "denied" {
+		unenforced = append(unenforced, secpolicy.PermissionFileDelete)
+	}
+	return []string{"--deny", strings.Join(p.With("denied"), ",")}, unenforced
+}
+
+func (enforcingAdapter) EventTool(e *adapter.CanonicalEvent) string {
+	tool, _ := e.Payload["tool"].(string)
+	return tool
+}
+
+func (enforcingAdapter) ToolPermission(tool string) string {
+	if tool == "shell" {
+		return secpolicy.PermissionCommandExecute
+	}
+	return ""
+}
+
+func (enforcingAdapter) DeniedTools(e *adapter.CanonicalEvent) []string {
+	if tool, _ := e.Payload["denied"].(string); tool != "" {
+		return []string{tool}
+	}
+	return nil
+}
+
+func testPolicy(levels map[string]interface{}) *model.RunSnapshot {
+	policy, _ := json.Marshal(map[string]interface{}{
+		"sources": []interface{}{"builtin-strict"}, "permissions": levels,
+	})
+	return &model.RunSnapshot{SecurityPolicy: policy}
+}
+
+func TestNewPolicyGuard(t *testing.T) {
+	if ParseSecurityPolicy(&model.RunSnapshot{}) != nil {
+		t.Error("没有 security_policy 时应返回 nil")
+	}
+	policy := ParseSecurityPolicy(testPolicy(map[string]interface{}{"command_execute": "denied", "file_delete": "denied"}))
+
+	g := newPolicyGuard(policy, enforcingAdapter{})
+	if !slices.Equal(g.args, []string{"--deny", "file_delete,command_execute"}) {
+		t.Errorf("args = %v", g.args)
+	}
+	if desc := g.describe(); !slices.Equal(desc["unenforced"].([]string), []string{"file_delete"}) {
+		t.Errorf("describe = %v", desc)
+	}
+
+	// 未实现 PolicyEnforcer：所有禁止都由 NodeManager 执行
+	g = newPolicyGuard(policy, jsonLineAdapter{})
+	if len(g.args) != 0 || !g.unenforced["command_execute"] || !g.unenforced["file_delete"] {
+		t.Errorf("guard = %+v", g)
+	}
+}
+
+func TestStreamOutput_PolicyDenied(t *testing.T) {
+	nm, events := newHookTestManager(t)
+	killed := false
+	policy := ParseSecurityPolicy(testPolicy(map[string]interface{}{"command_execute": "denied", "file_delete": "denied"}))
+	gate := newApprovalGate(nm, "run-1", newRunPauser(&pausingTarget{}, nil), nil, func() { killed = true })
+	gate.policy = newPolicyGuard(policy, enforcingAdapter{})
+
+	// CLI 执行的禁止只记录；CLI 报告拒绝的调用同样记录
+	output := `{"type":"tool_use_start","payload":{"tool":"shell"}}` + "\n" + `{"type":"run_completed","payload":{"denied":"shell"}}` + "\n"
+	nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), enforcingAdapter{}, gate, nil, 1)
+	got := eventTypes(events())
+	if !slices.Equal(got, []string{"tool_use_start", "policy_denied", "run_completed", "policy_denied"}) {
+		t.Fatalf("events = %v", got)
+	}
+	denied := events()[1]["payload"].(map[string]interface{})
+	if denied["permission"] != "command_execute" || denied["tool"] != "shell" || denied["enforced"] != true {
+		t.Errorf("policy_denied payload = %v", denied)
+	}
+	if killed || gate.violation != "" {
+		t.Errorf("CLI 已拒绝时不应终止 Agent: violation=%q", gate.violation)
+	}
+
+	// CLI 无法执行的禁止：终止 Agent
+	output = `{"type":"file_delete","payload":{"path":"/workspace/a"}}` + "\n"
+	nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), enforcingAdapter{}, gate, nil, 10)
+	last := events()[len(events())-1]
+	if last["type"] != "policy_denied" || last["payload"].(map[string]interface{})["enforced"] != false {
+		t.Errorf("last event = %v", last)
+	}
+	if !killed || gate.violation == "" {
+		t.Errorf("未执行的禁止应终止 Agent: killed=%v violation=%q", killed, gate.violation)
+	}
+}
+
+func TestApprovalGateRequires_Policy(t *testing.T) {
+	policy := ParseSecurityPolicy(testPolicy(map[string]interface{}{"command_execute": "approval_required"}))
+	g := newApprovalGate(nil, "run-1", nil, nil, func() {})
+	g.policy = newPolicyGuard(policy, jsonLineAdapter{})
+
+	got := g.requires(&adapter.CanonicalEvent{Type: adapter.EventCommand, Payload: map[string]interface{}{"command": "make"}})
+	if got == nil || got["permission"] != "command_execute" || got["operation"] != "command" {
+		t.Errorf("approval = %v", got)
+	}
+	if got := g.requires(&adapter.CanonicalEvent{Type: adapter.EventFileWrite, Payload: map[string]interface{}{}}); got != nil {
+		t.Errorf("允许的权限不需要审批: %v", got)
+	}
+}
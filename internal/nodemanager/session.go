@@ -0,0 +1,153 @@
+// Package nodemanager 持久 Agent 会话
+//
+// 启用会话的任务，执行快照带有 session（见 model.RunSession）。docker 执行后端的 Run 结束后，
+// 执行容器不归还实例池、不清理 /workspace，而是按会话 ID 保留 keep_alive_seconds 秒：
+//   - 追问 Run（session.resume）在保留的容器中执行，跳过 Workspace 复制，Adapter 实现 SessionResumer 时
+//     追加继续会话的 CLI 参数，否则将对话历史拼入提示词
+//   - 保留的容器已过期、已停止或 Run 被调度到其他节点时，同样将对话历史拼入提示词后在新容器中执行
+//   - 保留到期后预热容器按常规归还实例池（清理工作空间），实例容器无需处理
+//
+// 同一会话的非追问 Run 开始新的会话，之前保留的容器立即释放。process 执行后端不保留执行环境。
+package nodemanager
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+// ParseRunSession 从任务快照中解析会话信息，未启用会话时返回 nil
+func ParseRunSession(snapshot *model.RunSnapshot) *model.RunSession {
+	if snapshot.Session == nil || snapshot.Session.ID == "" {
+		return nil
+	}
+	return snapshot.Session
+}
+
+// sessionHistory 会话历史转换为 Adapter 的对话消息
+func sessionHistory(s *model.RunSession) []adapter.ConversationMessage {
+	out := make([]adapter.ConversationMessage, 0, len(s.History))
+	for _, m := range s.History {
+		out = append(out, adapter.ConversationMessage{Role: m.Role, Content: m.Content, Timestamp: m.Timestamp})
+	}
+	return out
+}
+
+// sessionKeepAlive 会话容器的保留时间
+func sessionKeepAlive(s *model.RunSession) time.Duration {
+	if s.KeepAliveSeconds <= 0 {
+		return model.DefaultSessionKeepAliveSeconds * time.Second
+	}
+	return time.Duration(s.KeepAliveSeconds) * time.Second
+}
+
+// sessionContainer 会话保留的执行容器
+type sessionContainer struct {
+	container string
+	pooled    bool // 租自预热实例池，保留到期后归还
+	timer     *time.Timer
+}
+
+// sessionLease docker 执行目标所属的会话
+type sessionLease struct {
+	id        string
+	keepAlive time.Duration
+	resumed   bool // 在上一轮保留的容器中继续
+	cache     *sessionCache
+}
+
+// sessionCache 按会话 ID 保留的执行容器
+type sessionCache struct {
+	rt   ContainerRuntime
+	pool *instancePool
+
+	mu    sync.Mutex
+	items map[string]*sessionContainer
+}
+
+// newSessionCache 创建会话容器缓存
+func newSessionCache(rt ContainerRuntime, pool *instancePool) *sessionCache {
+	return &sessionCache{rt: rt, pool: pool, items: make(map[string]*sessionContainer)}
+}
+
+// park Run 结束后保留会话容器，keepAlive 后释放；同一会话之前保留的容器先释放
+func (c *sessionCache) park(id, container string, pooled bool, keepAlive time.Duration) {
+	sc := &sessionContainer{container: container, pooled: pooled}
+	c.mu.Lock()
+	prev := c.items[id]
+	c.items[id] = sc
+	sc.timer = time.AfterFunc(keepAlive, func() { c.expire(id, sc) })
+	c.mu.Unlock()
+
+	if prev != nil && prev.container != container {
+		prev.timer.Stop()
+		c.release(prev)
+	}
+	log.Printf("[Session] 会话 %s 保留容器 %s（%s）", id, container, keepAlive)
+}
+
+// take 取出会话保留的容器（取出后不再到期释放），容器已停止时释放并返回 nil
+func (c *sessionCache) take(ctx context.Context, id string) *sessionContainer {
+	c.mu.Lock()
+	sc := c.items[id]
+	if sc != nil {
+		delete(c.items, id)
+		sc.timer.Stop()
+	}
+	c.mu.Unlock()
+	if sc == nil {
+		return nil
+	}
+	if info, err := c.rt.Inspect(ctx, sc.container); err != nil || !info.Running {
+		log.Printf("[Session] 会话 %s 的容器 %s 已不可用", id, sc.container)
+		c.release(sc)
+		return nil
+	}
+	return sc
+}
+
+// drop 开始新的会话时释放之前保留的容器
+func (c *sessionCache) drop(id string) {
+	c.mu.Lock()
+	sc := c.items[id]
+	delete(c.items, id)
+	c.mu.Unlock()
+	if sc != nil {
+		sc.timer.Stop()
+		c.release(sc)
+	}
+}
+
+// expire 保留到期释放容器（期间已被取出或替换时不处理）
+func (c *sessionCache) expire(id string, sc *sessionContainer) {
+	c.mu.Lock()
+	if c.items[id] != sc {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.items, id)
+	c.mu.Unlock()
+	log.Printf("[Session] 会话 %s 保留到期，释放容器 %s", id, sc.container)
+	c.release(sc)
+}
+
+// release 预热容器归还实例池，实例容器无需处理
+func (c *sessionCache) release(sc *sessionContainer) {
+	if !sc.pooled || c.pool == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	c.pool.release(ctx, sc.container)
+}
+
+// size 当前保留的会话数
+func (c *sessionCache) size() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.items)
+}
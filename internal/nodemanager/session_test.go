@@ -0,0 +1,97 @@
+package nodemanager
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"agents-admin/internal/nodemanager/adapter"
+	"agents-admin/internal/shared/model"
+)
+
+func TestParseRunSession(t *testing.T) {
+	if s := ParseRunSession(&model.RunSnapshot{Prompt: "x"}); s != nil {
+		t.Errorf("未启用会话: %+v", s)
+	}
+	s := ParseRunSession(&model.RunSnapshot{Session: &model.RunSession{
+		ID: "task-1", KeepAliveSeconds: 60, Resume: true, PreviousRunID: "run-1",
+		History: []model.Message{
+			{Role: "user", Content: "写一个函数"},
+			{Role: "assistant", Content: "已完成"},
+		},
+	}})
+	if s == nil || s.ID != "task-1" || !s.Resume || s.PreviousRunID != "run-1" || len(s.History) != 2 {
+		t.Fatalf("session = %+v", s)
+	}
+	if got := sessionKeepAlive(s); got != time.Minute {
+		t.Errorf("keep alive = %s", got)
+	}
+
+	prompt := adapter.FormatConversation(sessionHistory(s), "再加上测试")
+	if !strings.Contains(prompt, "[user]\n写一个函数") || !strings.Contains(prompt, "[assistant]\n已完成") || !strings.HasSuffix(prompt, "再加上测试") {
+		t.Errorf("prompt = %q", prompt)
+	}
+	if got := adapter.FormatConversation(nil, "只有提示词"); got != "只有提示词" {
+		t.Errorf("无历史时 prompt = %q", got)
+	}
+}
+
+func TestSessionCache_ParkAndResume(t *testing.T) {
+	ctx := context.Background()
+	rt := newPoolRuntime()
+	p := newInstancePool([]PoolConfig{{AccountID: "acc-1", Size: 1}}, rt, "node-1", testPoolTemplate)
+	p.fill(ctx)
+	c := newSessionCache(rt, p)
+
+	name, ok := p.lease(ctx, "acc-1", "run-1")
+	if !ok {
+		t.Fatal("lease failed")
+	}
+	dt := &dockerTarget{container: name, pooled: true, pool: p,
+		session: &sessionLease{id: "task-1", keepAlive: time.Hour, cache: c}}
+	dt.release()
+	if st := p.status()[0]; st.Leased != 1 || st.Idle != 0 || len(rt.execs) != 0 {
+		t.Fatalf("会话容器应保持租用且不清理工作空间: status=%+v execs=%v", st, rt.execs)
+	}
+
+	warm := c.take(ctx, "task-1")
+	if warm == nil || warm.container != name || !warm.pooled {
+		t.Fatalf("take = %+v", warm)
+	}
+	if c.take(ctx, "task-1") != nil {
+		t.Error("取出后不应再次取到")
+	}
+
+	// 追问结束后再次保留，到期归还实例池
+	c.park("task-1", name, true, 10*time.Millisecond)
+	deadline := time.Now().Add(2 * time.Second)
+	for c.size() > 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if st := p.status()[0]; c.size() != 0 || st.Idle != 1 || st.Leased != 0 || st.Recycled != 1 {
+		t.Errorf("到期后应归还实例池: size=%d status=%+v", c.size(), st)
+	}
+}
+
+func TestSessionCache_StoppedAndDropped(t *testing.T) {
+	ctx := context.Background()
+	rt := newPoolRuntime()
+	p := newInstancePool([]PoolConfig{{AccountID: "acc-1", Size: 2}}, rt, "node-1", testPoolTemplate)
+	p.fill(ctx)
+	c := newSessionCache(rt, p)
+
+	stopped, _ := p.lease(ctx, "acc-1", "run-1")
+	c.park("task-1", stopped, true, time.Hour)
+	rt.containers[stopped].Running = false
+	if warm := c.take(ctx, "task-1"); warm != nil {
+		t.Errorf("已停止的容器不应复用: %+v", warm)
+	}
+
+	name, _ := p.lease(ctx, "acc-1", "run-2")
+	c.park("task-2", name, true, time.Hour)
+	c.drop("task-2")
+	if c.size() != 0 || p.status()[0].Leased != 0 {
+		t.Errorf("开始新会话时应释放保留的容器: size=%d status=%+v", c.size(), p.status()[0])
+	}
+}
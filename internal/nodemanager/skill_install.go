@@ -0,0 +1,176 @@
+// Package nodemanager 技能包安装
+//
+// 执行快照 skills 中的技能包（API Server 按 Agent 模板解析出的版本与摘要）在 Agent 启动前安装到执行目标中
+// $HOME/<技能目录>/<name>/：技能目录由 Adapter 的 SkillsLoader 决定（如 Claude Code 的 ~/.claude/skills），
+// 未实现时为 ~/.agents-admin/skills 并在提示词中说明。
+//
+// 技能包通过 GET /api/v1/skills/{id}/bundles/{version} 下载，按摘要校验后缓存在 <WorkspaceDir>/.skill-cache，
+// 相同摘要的包不重复下载。包在宿主机解压后复制到执行目标（docker 容器经容器运行时复制），同名技能目录被替换。
+package nodemanager
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/skillbundle"
+)
+
+// RunSkill 执行快照中的技能包
+type RunSkill = model.SnapshotSkill
+
+// ParseRunSkills 从任务快照中解析需要安装的技能包
+func ParseRunSkills(snapshot *model.RunSnapshot) []RunSkill {
+	if len(snapshot.Skills) == 0 {
+		return nil
+	}
+	return snapshot.Skills
+}
+
+// skillNames 技能的安装目录名
+func skillNames(skills []RunSkill) []string {
+	names := make([]string, 0, len(skills))
+	for _, s := range skills {
+		names = append(names, s.Name)
+	}
+	return names
+}
+
+// skillInstallScript 将 $2 下的技能目录（$3...）复制到 $HOME/$1/，替换同名目录
+const skillInstallScript = `set -e
+dest="$HOME/$1"; src="$2"; shift 2
+mkdir -p "$dest"
+for n in "$@"; do rm -rf "$dest/$n"; cp -R "$src/$n" "$dest/$n"; done`
+
+// installSkills 下载技能包并安装到执行目标的技能目录，返回 run_started 事件中的技能描述
+func (nm *NodeManager) installSkills(ctx context.Context, runID string, target execTarget, skills []RunSkill, skillsDir string) ([]map[string]interface{}, error) {
+	stage, err := os.MkdirTemp("", "agents-skills-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(stage)
+
+	installed := make([]map[string]interface{}, 0, len(skills))
+	for _, s := range skills {
+		data, err := nm.fetchSkillBundle(ctx, s)
+		if err != nil {
+			return nil, fmt.Errorf("下载技能包 %s@%s 失败: %v", s.SkillID, s.Version, err)
+		}
+		if err := skillbundle.Extract(data, filepath.Join(stage, s.Name)); err != nil {
+			return nil, fmt.Errorf("解压技能包 %s@%s 失败: %v", s.SkillID, s.Version, err)
+		}
+		installed = append(installed, map[string]interface{}{
+			"skill_id": s.SkillID, "name": s.Name, "version": s.Version, "digest": s.Digest,
+		})
+	}
+
+	src, cleanup, err := stageSkills(ctx, runID, target, stage)
+	if err != nil {
+		return nil, fmt.Errorf("复制技能包到执行环境失败: %v", err)
+	}
+	defer cleanup()
+	argv := append([]string{"sh", "-c", skillInstallScript, "sh", skillsDir, src}, skillNames(skills)...)
+	if out, err := target.command(ctx, argv, nil).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("安装技能失败: %v %s", err, strings.TrimSpace(string(out)))
+	}
+	log.Printf("[Skill] 任务 %s 已安装 %d 个技能到 ~/%s", runID, len(skills), skillsDir)
+	return installed, nil
+}
+
+// stageSkills 使解压后的技能在执行目标中可读，返回目标中的路径与清理函数
+func stageSkills(ctx context.Context, runID string, target execTarget, stage string) (string, func(), error) {
+	switch t := target.(type) {
+	case *dockerTarget:
+		dir := "/tmp/agents-skills-" + runID
+		if err := t.rt.CopyTo(ctx, stage, t.container, dir); err != nil {
+			return "", nil, err
+		}
+		return dir, func() {
+			if err := t.command(context.WithoutCancel(ctx), []string{"rm", "-rf", dir}, nil).Run(); err != nil {
+				log.Printf("[Skill] 任务 %s 清理容器内的技能暂存目录失败: %v", runID, err)
+			}
+		}, nil
+	case *processTarget:
+		// 沙箱用户需要读取暂存目录
+		if err := t.proc.chown(stage, true); err != nil {
+			return "", nil, err
+		}
+		return stage, func() {}, nil
+	default:
+		return stage, func() {}, nil
+	}
+}
+
+// skillCacheDir 技能包缓存目录（未配置工作空间根目录时不缓存）
+func (nm *NodeManager) skillCacheDir() string {
+	if nm.config.WorkspaceDir == "" {
+		return ""
+	}
+	return filepath.Join(nm.config.WorkspaceDir, ".skill-cache")
+}
+
+// fetchSkillBundle 获取技能包（优先使用本地缓存），内容与快照中的摘要不一致时返回错误
+func (nm *NodeManager) fetchSkillBundle(ctx context.Context, s RunSkill) ([]byte, error) {
+	var cached string
+	if dir := nm.skillCacheDir(); dir != "" && strings.HasPrefix(s.Digest, "sha256:") {
+		cached = filepath.Join(dir, strings.TrimPrefix(s.Digest, "sha256:")+".tar.gz")
+		if data, err := os.ReadFile(cached); err == nil && skillbundle.Digest(data) == s.Digest {
+			return data, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", nm.config.APIServerURL+"/api/v1/skills/"+s.SkillID+"/bundles/"+s.Version, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("%d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, skillbundle.MaxSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if digest := skillbundle.Digest(data); digest != s.Digest {
+		return nil, fmt.Errorf("摘要不一致: 期望 %s，实际 %s", s.Digest, digest)
+	}
+
+	if cached != "" {
+		if err := writeFileAtomic(cached, data); err != nil {
+			log.Printf("[Skill] 缓存技能包 %s@%s 失败: %v", s.SkillID, s.Version, err)
+		}
+	}
+	return data, nil
+}
+
+// writeFileAtomic 先写临时文件再重命名，并发 Run 不会读到写了一半的缓存
+func writeFileAtomic(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
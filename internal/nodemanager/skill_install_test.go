@@ -0,0 +1,110 @@
+package nodemanager
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/skillbundle"
+)
+
+func packSkill(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, content := range files {
+		mode := int64(0o644)
+		if strings.HasSuffix(name, ".sh") {
+			mode = 0o755
+		}
+		tw.WriteHeader(&tar.Header{Name: name, Mode: mode, Size: int64(len(content)), Typeflag: tar.TypeReg})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestParseRunSkills(t *testing.T) {
+	skills := ParseRunSkills(&model.RunSnapshot{Skills: []model.SnapshotSkill{
+		{SkillID: "skill-1", Name: "review", Version: "1.0.0", Digest: "sha256:x"},
+	}})
+	if len(skills) != 1 || skills[0] != (RunSkill{SkillID: "skill-1", Name: "review", Version: "1.0.0", Digest: "sha256:x"}) {
+		t.Fatalf("skills = %+v", skills)
+	}
+	if ParseRunSkills(&model.RunSnapshot{}) != nil {
+		t.Error("无技能时应返回 nil")
+	}
+}
+
+// TestInstallSkills 技能包下载、校验后安装到 $HOME/<技能目录>/<name>，再次安装使用本地缓存并替换旧目录
+func TestInstallSkills(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	bundle := packSkill(t, map[string]string{
+		"skill.yaml":      "name: review\nversion: 1.0.0\n",
+		"SKILL.md":        "审查代码",
+		"scripts/lint.sh": "#!/bin/sh\necho ok\n",
+	})
+	var downloads atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/skills/skill-1/bundles/1.0.0" {
+			http.NotFound(w, r)
+			return
+		}
+		downloads.Add(1)
+		w.Write(bundle)
+	}))
+	defer srv.Close()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL, WorkspaceDir: t.TempDir()}, httpClient: srv.Client()}
+	target := &dirTarget{dir: t.TempDir()}
+	skills := []RunSkill{{SkillID: "skill-1", Name: "review", Version: "1.0.0", Digest: skillbundle.Digest(bundle)}}
+	ctx := context.Background()
+
+	installed, err := nm.installSkills(ctx, "run-1", target, skills, ".claude/skills")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(installed) != 1 || installed[0]["version"] != "1.0.0" {
+		t.Errorf("installed = %v", installed)
+	}
+	dir := filepath.Join(home, ".claude", "skills", "review")
+	if data, _ := os.ReadFile(filepath.Join(dir, "SKILL.md")); string(data) != "审查代码" {
+		t.Errorf("SKILL.md = %q", data)
+	}
+	if info, err := os.Stat(filepath.Join(dir, "scripts", "lint.sh")); err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("脚本应可执行: %v %v", info, err)
+	}
+
+	// 旧版本遗留的文件被替换，技能包来自缓存
+	os.WriteFile(filepath.Join(dir, "stale.md"), []byte("x"), 0o644)
+	if _, err := nm.installSkills(ctx, "run-2", target, skills, ".claude/skills"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "stale.md")); !os.IsNotExist(err) {
+		t.Error("同名技能目录应被替换")
+	}
+	if n := downloads.Load(); n != 1 {
+		t.Errorf("downloads = %d, 期望使用缓存", n)
+	}
+
+	// 摘要不一致时拒绝安装
+	skills[0].Digest = "sha256:0000"
+	if _, err := nm.installSkills(ctx, "run-3", target, skills, ".claude/skills"); err == nil || !strings.Contains(err.Error(), "摘要不一致") {
+		t.Errorf("err = %v", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package nodemanager Agent 派生子任务
+//
+// Adapter 解析出 spawn_subtask 事件时（如 generic Adapter 的规则将 Agent 输出映射为该事件），NodeManager 先调用
+// POST /api/v1/runs/{id}/subtasks 创建子任务及其 Run（Idempotency-Key 为 <run_id>-<seq>，请求重试不会重复创建），
+// 再在事件 payload 中补充 task_id、run_id 与 depth（失败时为 error）后上报。子任务由调度器独立分派，
+// 当前 Run 不等待子任务结束；父任务的状态由 API Server 按子任务汇总。
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// spawnSubtask 请求 API Server 为 Run 派生子任务，返回补充了派生结果的事件 payload
+func (nm *NodeManager) spawnSubtask(ctx context.Context, runID string, seq int, payload map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{}, len(payload)+3)
+	for k, v := range payload {
+		result[k] = v
+	}
+	prompt, _ := payload["prompt"].(string)
+	if strings.TrimSpace(prompt) == "" {
+		result["error"] = "prompt is required"
+		return result
+	}
+
+	body := map[string]interface{}{"prompt": prompt}
+	for _, key := range []string{"name", "description", "labels"} {
+		if v, ok := payload[key]; ok {
+			body[key] = v
+		}
+	}
+	data, _ := json.Marshal(body)
+	req, _ := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/runs/"+runID+"/subtasks", bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Idempotency-Key", fmt.Sprintf("%s-%d", runID, seq))
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[SubTask] 任务 %s 派生子任务失败: %v", runID, err)
+		result["error"] = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		var e struct {
+			Error string `json:"error"`
+		}
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if json.Unmarshal(msg, &e) != nil || e.Error == "" {
+			e.Error = fmt.Sprintf("%d %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+		}
+		log.Printf("[SubTask] 任务 %s 派生子任务被拒绝: %s", runID, e.Error)
+		result["error"] = e.Error
+		return result
+	}
+	var spawned struct {
+		Task struct {
+			ID string `json:"id"`
+		} `json:"task"`
+		Run struct {
+			ID string `json:"id"`
+		} `json:"run"`
+		Depth int `json:"depth"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&spawned); err != nil {
+		log.Printf("[SubTask] 任务 %s 派生子任务响应无效: %v", runID, err)
+		result["error"] = "invalid response: " + err.Error()
+		return result
+	}
+	result["task_id"] = spawned.Task.ID
+	result["run_id"] = spawned.Run.ID
+	result["depth"] = spawned.Depth
+	log.Printf("[SubTask] 任务 %s 派生子任务 %s（Run %s，深度 %d）", runID, spawned.Task.ID, spawned.Run.ID, spawned.Depth)
+	return result
+}
@@ -0,0 +1,73 @@
+package nodemanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// TestStreamOutput_SpawnSubtask spawn_subtask 事件先派生子任务，再携带派生结果上报
+func TestStreamOutput_SpawnSubtask(t *testing.T) {
+	var mu sync.Mutex
+	var events []map[string]interface{}
+	var keys []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if strings.HasSuffix(r.URL.Path, "/subtasks") {
+			var body map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&body)
+			keys = append(keys, r.Header.Get("Idempotency-Key"))
+			if body["prompt"] == "too many" {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(map[string]string{"error": "subtask limit exceeded (max_children 1)"})
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"task": map[string]string{"id": "task-child"}, "run": map[string]string{"id": "run-child"}, "depth": 1,
+			})
+			return
+		}
+		var body struct {
+			Events []map[string]interface{} `json:"events"`
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		events = append(events, body.Events...)
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	nm := &NodeManager{config: Config{APIServerURL: srv.URL}, httpClient: srv.Client()}
+
+	output := strings.Join([]string{
+		`{"type":"spawn_subtask","payload":{"prompt":"write tests","name":"tests"}}`,
+		`{"type":"spawn_subtask","payload":{"prompt":"too many"}}`,
+		`{"type":"spawn_subtask","payload":{"name":"no prompt"}}`,
+	}, "\n")
+	nm.streamOutput(context.Background(), "run-1", strings.NewReader(output), jsonLineAdapter{}, nil, nil, 1)
+	waitFor(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(events) == 3
+	})
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(keys) != 2 || keys[0] != "run-1-1" || keys[1] != "run-1-2" {
+		t.Errorf("Idempotency-Key = %v", keys)
+	}
+	first := events[0]["payload"].(map[string]interface{})
+	if first["task_id"] != "task-child" || first["run_id"] != "run-child" || first["depth"] != float64(1) || first["name"] != "tests" {
+		t.Errorf("spawned payload = %v", first)
+	}
+	if got := events[1]["payload"].(map[string]interface{})["error"]; got != "subtask limit exceeded (max_children 1)" {
+		t.Errorf("rejected error = %v", got)
+	}
+	if got := events[2]["payload"].(map[string]interface{})["error"]; got != "prompt is required" {
+		t.Errorf("missing prompt error = %v", got)
+	}
+}
@@ -0,0 +1,364 @@
+// Package nodemanager 节点反向隧道
+//
+// NodeManager 主动连接 API Server 的 /api/v1/nodes/{node_id}/tunnel 并保持 WebSocket 长连接，
+// 处理 API Server 在其上打开的流（协议见 shared/tunnel）：
+//   - terminal：连接本机的 ttyd（只监听 127.0.0.1，节点不对外开放端口）
+//   - file：读取运行中 Run 工作目录下的文件
+//   - logs：推送运行中 Run 的实时输出（已按 Run 的密钥脱敏）
+//
+// 连接断开后按指数退避重连，节点位于 NAT 之后也可被 API Server 访问。
+package nodemanager
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"agents-admin/internal/shared/tunnel"
+)
+
+const (
+	tunnelMinBackoff       = time.Second
+	tunnelMaxBackoff       = 30 * time.Second
+	tunnelHandshakeTimeout = 15 * time.Second
+
+	// tunnelFileTimeout 单次文件获取（检查与传输）的超时时间
+	tunnelFileTimeout = 2 * time.Minute
+
+	// logSubscriberBuffer 实时输出订阅者的缓冲行数，读取过慢时丢弃新行而不阻塞 Run
+	logSubscriberBuffer = 256
+
+	// maxLogLine stderr 中不含换行的超长输出按该长度切分
+	maxLogLine = 64 * 1024
+)
+
+// tunnelLoop 保持到 API Server 的反向隧道，断开后按指数退避重连，ctx 取消时返回
+func (nm *NodeManager) tunnelLoop(ctx context.Context) {
+	backoff := tunnelMinBackoff
+	for {
+		connectedAt := time.Now()
+		err := nm.runTunnel(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		// 稳定运行过一段时间的连接断开后从最小间隔重新开始
+		if time.Since(connectedAt) > tunnelMaxBackoff {
+			backoff = tunnelMinBackoff
+		}
+		log.Printf("[tunnel.disconnected] node_id=%s error=%v retry_in=%s", nm.config.NodeID, err, backoff)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, tunnelMaxBackoff)
+	}
+}
+
+// runTunnel 建立一次隧道并处理流，直到连接断开
+func (nm *NodeManager) runTunnel(ctx context.Context) error {
+	target, err := tunnelURL(nm.config.APIServerURL, nm.config.NodeID)
+	if err != nil {
+		return err
+	}
+	dialer := websocket.Dialer{
+		Proxy:            http.ProxyFromEnvironment,
+		HandshakeTimeout: tunnelHandshakeTimeout,
+		TLSClientConfig:  transportTLSConfig(nm.httpClient.Transport),
+	}
+	header := http.Header{}
+	if nm.config.NodeToken != "" {
+		header.Set("X-Node-Token", nm.config.NodeToken)
+	}
+	conn, resp, err := dialer.DialContext(ctx, target, header)
+	if err != nil {
+		if resp != nil {
+			return fmt.Errorf("%w (status %d)", err, resp.StatusCode)
+		}
+		return err
+	}
+
+	session := tunnel.NewSession(conn, true, nm.handleTunnelStream)
+	stop := context.AfterFunc(ctx, func() { session.Close() })
+	defer stop()
+	log.Printf("[tunnel.connected] node_id=%s url=%s", nm.config.NodeID, target)
+	return session.Serve()
+}
+
+// handleTunnelStream 处理 API Server 打开的流
+func (nm *NodeManager) handleTunnelStream(s *tunnel.Stream, req tunnel.OpenRequest) error {
+	switch req.Kind {
+	case tunnel.KindTerminal:
+		return nm.tunnelTerminal(s, req.Params["session_id"])
+	case tunnel.KindFile:
+		return nm.tunnelFile(s, req.Params["run_id"], req.Params["path"])
+	case tunnel.KindLogs:
+		return nm.tunnelLogs(s, req.Params["run_id"])
+	}
+	return fmt.Errorf("unsupported stream kind %q", req.Kind)
+}
+
+// tunnelTerminal 将流连接到本机 ttyd（只接受当前活跃的终端会话）
+func (nm *NodeManager) tunnelTerminal(s *tunnel.Stream, sessionID string) error {
+	if nm.terminalWorker == nil || sessionID == "" || nm.terminalWorker.ActiveSession() != sessionID {
+		return fmt.Errorf("%w: terminal session %s is not active on this node", tunnel.ErrNotFound, sessionID)
+	}
+	conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", ttydPort), 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("connect ttyd: %w", err)
+	}
+	defer conn.Close()
+	if err := s.Accept(); err != nil {
+		return err
+	}
+
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(conn, s)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(s, conn)
+		done <- struct{}{}
+	}()
+	<-done
+	return nil
+}
+
+// tunnelFile 读取运行中 Run 工作目录下的文件
+//
+// 文件在 Run 的执行目标中读取（docker 后端在容器内，process 后端在宿主机工作目录），
+// 路径解析符号链接后必须仍在工作目录内，超过 tunnel.MaxFileSize 的文件被拒绝。
+func (nm *NodeManager) tunnelFile(s *tunnel.Stream, runID, path string) error {
+	cleaned, err := tunnel.CleanRunPath(path)
+	if err != nil {
+		return err
+	}
+	target := nm.runTarget(runID)
+	if target == nil {
+		return fmt.Errorf("%w: run %s is not running on this node", tunnel.ErrNotFound, runID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tunnelFileTimeout)
+	defer cancel()
+
+	// 输出文件大小与解析后的路径；不是工作目录内的普通文件时失败
+	const resolve = `f=$(readlink -f -- "$1") && d=$(pwd -P) && case "$f" in "$d"/*) ;; *) exit 2 ;; esac && test -f "$f" && wc -c < "$f" && printf '%s\n' "$f"`
+	out, err := target.command(ctx, []string{"sh", "-c", resolve, "sh", cleaned}, nil).Output()
+	if err != nil {
+		return fmt.Errorf("%w: %s", tunnel.ErrNotFound, cleaned)
+	}
+	sizeLine, resolved, _ := strings.Cut(string(out), "\n")
+	resolved = strings.TrimSuffix(resolved, "\n")
+	size, err := strconv.ParseInt(strings.TrimSpace(sizeLine), 10, 64)
+	if err != nil || resolved == "" {
+		return fmt.Errorf("%w: %s", tunnel.ErrNotFound, cleaned)
+	}
+	if size > tunnel.MaxFileSize {
+		return fmt.Errorf("%w: %s is %d bytes (limit %d)", tunnel.ErrTooLarge, cleaned, size, tunnel.MaxFileSize)
+	}
+
+	if err := s.Accept(); err != nil {
+		return err
+	}
+	cmd := target.command(ctx, []string{"cat", "--", resolved}, nil)
+	cmd.Stdout = s
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("read %s: %w", cleaned, err)
+	}
+	return nil
+}
+
+// tunnelLogs 推送运行中 Run 的实时输出，Run 结束或对端关闭流时返回
+func (nm *NodeManager) tunnelLogs(s *tunnel.Stream, runID string) error {
+	lines, cancel, ok := nm.logs.subscribe(runID)
+	if !ok {
+		return fmt.Errorf("%w: run %s is not running on this node", tunnel.ErrNotFound, runID)
+	}
+	defer cancel()
+	if err := s.Accept(); err != nil {
+		return err
+	}
+
+	// API Server 的客户端断开时关闭流，Read 随之返回
+	closed := make(chan struct{})
+	go func() {
+		io.Copy(io.Discard, s)
+		close(closed)
+	}()
+	w := bufio.NewWriter(s)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				return w.Flush()
+			}
+			w.WriteString(line)
+			w.WriteByte('\n')
+			// 合并已到达的行后再发送，减少小帧
+			if len(lines) == 0 {
+				if err := w.Flush(); err != nil {
+					return err
+				}
+			}
+		case <-closed:
+			return nil
+		}
+	}
+}
+
+// runTarget 返回运行中 Run 的执行目标（执行目标准备完成前为 nil）
+func (nm *NodeManager) runTarget(runID string) execTarget {
+	nm.mu.Lock()
+	defer nm.mu.Unlock()
+	return nm.targets[runID]
+}
+
+// publishOutput 将 Run 的一行输出脱敏后发布给实时输出订阅者
+func (nm *NodeManager) publishOutput(runID, line string) {
+	if !nm.logs.watched(runID) {
+		return
+	}
+	nm.logs.publish(runID, nm.secretMaskerFor(runID).mask(line))
+}
+
+// tunnelURL 由 API Server 地址构建隧道的 WebSocket 地址
+func tunnelURL(apiServerURL, nodeID string) (string, error) {
+	u, err := url.Parse(apiServerURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid api server url: %w", err)
+	}
+	switch u.Scheme {
+	case "https":
+		u.Scheme = "wss"
+	case "http":
+		u.Scheme = "ws"
+	default:
+		return "", fmt.Errorf("unsupported api server url scheme %q", u.Scheme)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/api/v1/nodes/" + url.PathEscape(nodeID) + "/tunnel"
+	return u.String(), nil
+}
+
+// transportTLSConfig 取出 HTTP 客户端的 TLS 配置副本（含节点客户端证书），未自定义时返回 nil
+func transportTLSConfig(rt http.RoundTripper) *tls.Config {
+	if t, ok := rt.(*nodeTokenTransport); ok {
+		rt = t.base
+	}
+	if t, ok := rt.(*http.Transport); ok && t.TLSClientConfig != nil {
+		return t.TLSClientConfig.Clone()
+	}
+	return nil
+}
+
+// ============================================================================
+// 实时输出订阅
+// ============================================================================
+
+// runLogHub 运行中 Run 的实时输出订阅（零值可用）
+//
+// Run 开始执行时 begin、结束时 end（关闭所有订阅），只能订阅进行中的 Run。
+type runLogHub struct {
+	mu   sync.Mutex
+	runs map[string]map[chan string]struct{}
+}
+
+// begin 登记进行中的 Run
+func (h *runLogHub) begin(runID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.runs == nil {
+		h.runs = make(map[string]map[chan string]struct{})
+	}
+	if h.runs[runID] == nil {
+		h.runs[runID] = make(map[chan string]struct{})
+	}
+}
+
+// end Run 结束，关闭所有订阅
+func (h *runLogHub) end(runID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.runs[runID] {
+		close(ch)
+	}
+	delete(h.runs, runID)
+}
+
+// subscribe 订阅 Run 的输出，Run 未在进行中时返回 false
+func (h *runLogHub) subscribe(runID string) (<-chan string, func(), bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	subs, ok := h.runs[runID]
+	if !ok {
+		return nil, nil, false
+	}
+	ch := make(chan string, logSubscriberBuffer)
+	subs[ch] = struct{}{}
+	cancel := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		if _, ok := h.runs[runID][ch]; ok {
+			delete(h.runs[runID], ch)
+			close(ch)
+		}
+	}
+	return ch, cancel, true
+}
+
+// watched Run 是否有订阅者
+func (h *runLogHub) watched(runID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.runs[runID]) > 0
+}
+
+// publish 发布一行输出，订阅者缓冲已满时丢弃该行
+func (h *runLogHub) publish(runID, line string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.runs[runID] {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+}
+
+// logLineWriter 按行切分写入的数据并发布为 Run 的实时输出（用于 stderr）
+type logLineWriter struct {
+	nm     *NodeManager
+	runID  string
+	prefix string
+	buf    []byte
+}
+
+func (w *logLineWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.nm.publishOutput(w.runID, w.prefix+string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+	if len(w.buf) > maxLogLine {
+		w.nm.publishOutput(w.runID, w.prefix+string(w.buf))
+		w.buf = w.buf[:0]
+	}
+	return len(p), nil
+}
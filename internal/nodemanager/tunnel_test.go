@@ -0,0 +1,169 @@
+package nodemanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+
+	"agents-admin/internal/shared/tunnel"
+)
+
+// tunnelPair 建立 API Server 侧会话与由 nm 处理流的节点侧会话
+func tunnelPair(t *testing.T, nm *NodeManager) *tunnel.Session {
+	t.Helper()
+	accepted := make(chan *tunnel.Session, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s := tunnel.NewSession(conn, false, nil)
+		accepted <- s
+		s.Serve()
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node := tunnel.NewSession(conn, true, nm.handleTunnelStream)
+	go node.Serve()
+	server := <-accepted
+	t.Cleanup(func() {
+		node.Close()
+		server.Close()
+	})
+	return server
+}
+
+func TestTunnelURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com":          "wss://api.example.com/api/v1/nodes/node-1/tunnel",
+		"http://10.0.0.1:8080/":            "ws://10.0.0.1:8080/api/v1/nodes/node-1/tunnel",
+		"https://example.com/agents-admin": "wss://example.com/agents-admin/api/v1/nodes/node-1/tunnel",
+	}
+	for in, want := range tests {
+		got, err := tunnelURL(in, "node-1")
+		if err != nil || got != want {
+			t.Errorf("tunnelURL(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := tunnelURL("ftp://example.com", "node-1"); err == nil {
+		t.Error("expected error for unsupported scheme")
+	}
+}
+
+func TestTunnelFile(t *testing.T) {
+	work := t.TempDir()
+	outside := t.TempDir()
+	os.MkdirAll(filepath.Join(work, "out"), 0o755)
+	os.WriteFile(filepath.Join(work, "out", "result.json"), []byte(`{"ok":true}`), 0o644)
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("secret"), 0o644)
+	os.Symlink(filepath.Join(outside, "secret"), filepath.Join(work, "link"))
+	big, _ := os.Create(filepath.Join(work, "big.bin"))
+	big.Truncate(tunnel.MaxFileSize + 1)
+	big.Close()
+
+	nm := &NodeManager{targets: map[string]execTarget{"run-1": &dirTarget{dir: work}}}
+	server := tunnelPair(t, nm)
+	open := func(runID, path string) (string, error) {
+		s, err := server.Open(context.Background(), tunnel.OpenRequest{
+			Kind:   tunnel.KindFile,
+			Params: map[string]string{"run_id": runID, "path": path},
+		})
+		if err != nil {
+			return "", err
+		}
+		defer s.Close()
+		data, err := io.ReadAll(s)
+		return string(data), err
+	}
+
+	if got, err := open("run-1", "out/result.json"); err != nil || got != `{"ok":true}` {
+		t.Fatalf("file = %q, %v", got, err)
+	}
+
+	notFound := map[string]string{
+		"missing file":    "out/missing.json",
+		"directory":       "out",
+		"symlink outside": "link",
+		"unknown run":     "",
+	}
+	for name, path := range notFound {
+		runID := "run-1"
+		if path == "" {
+			runID, path = "run-2", "out/result.json"
+		}
+		if _, err := open(runID, path); !errors.Is(err, tunnel.ErrNotFound) {
+			t.Errorf("%s: err = %v, want not found", name, err)
+		}
+	}
+	if _, err := open("run-1", "../"+filepath.Base(outside)+"/secret"); err == nil || err.Error() != tunnel.ErrInvalidPath.Error() {
+		t.Errorf("escaping path: err = %v", err)
+	}
+	if _, err := open("run-1", "big.bin"); !errors.Is(err, tunnel.ErrTooLarge) {
+		t.Errorf("big file: err = %v, want too large", err)
+	}
+}
+
+func TestTunnelLogs(t *testing.T) {
+	nm := &NodeManager{}
+	nm.setSecretMasker("run-1", newSecretMasker(map[string]string{"API_KEY": "sk-123456"}))
+	server := tunnelPair(t, nm)
+
+	if _, err := server.Open(context.Background(), tunnel.OpenRequest{Kind: tunnel.KindLogs, Params: map[string]string{"run_id": "run-1"}}); !errors.Is(err, tunnel.ErrNotFound) {
+		t.Fatalf("run not started: err = %v", err)
+	}
+
+	nm.logs.begin("run-1")
+	s, err := server.Open(context.Background(), tunnel.OpenRequest{Kind: tunnel.KindLogs, Params: map[string]string{"run_id": "run-1"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	nm.publishOutput("run-1", "using key sk-123456")
+	stderr := &logLineWriter{nm: nm, runID: "run-1", prefix: "[stderr] "}
+	stderr.Write([]byte("warn: a"))
+	stderr.Write([]byte("b\npartial"))
+	nm.publishOutput("run-2", "other run")
+	nm.logs.end("run-1")
+
+	data, err := io.ReadAll(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "using key ***\n[stderr] warn: ab\n" {
+		t.Errorf("logs = %q", data)
+	}
+}
+
+func TestRunLogHub_Unsubscribe(t *testing.T) {
+	var h runLogHub
+	if _, _, ok := h.subscribe("run-1"); ok {
+		t.Fatal("subscribed to a run that has not started")
+	}
+	h.begin("run-1")
+	lines, cancel, ok := h.subscribe("run-1")
+	if !ok || !h.watched("run-1") {
+		t.Fatal("subscribe failed")
+	}
+	cancel()
+	if h.watched("run-1") {
+		t.Error("still watched after cancel")
+	}
+	if _, open := <-lines; open {
+		t.Error("channel not closed after cancel")
+	}
+	h.publish("run-1", "dropped")
+	h.end("run-1")
+	cancel() // Run 结束后重复取消是安全的
+}
@@ -0,0 +1,118 @@
+// Package nodemanager Git 凭据注入
+//
+// 工作空间通过 credential_ref 引用 API Server 中存储的凭据，
+// NodeManager 在克隆/推送时按需解析明文，仅注入到当次 git 命令：
+//   - https_token：写入命令行 URL（克隆后重置 origin，避免 token 落入 .git/config）
+//   - ssh_key：写入 0600 临时私钥文件，通过 GIT_SSH_COMMAND 指定，命令结束后删除
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// 凭据类型（与 model.CredentialType 一致）
+const (
+	credentialTypeSSHKey     = "ssh_key"
+	credentialTypeHTTPSToken = "https_token"
+)
+
+// GitCredential 解析后的 Git 凭据明文
+type GitCredential struct {
+	Name     string `json:"name"`
+	Type     string `json:"type"`               // ssh_key / https_token
+	Username string `json:"username,omitempty"` // HTTPS 用户名（可选）
+	Secret   string `json:"secret"`             // 私钥或 Token
+}
+
+// CredentialResolver 按名称解析凭据
+type CredentialResolver func(ctx context.Context, name string) (*GitCredential, error)
+
+// token 返回 HTTPS Token（SSH 凭据返回空）
+func (c *GitCredential) token() string {
+	if c == nil || c.Type != credentialTypeHTTPSToken {
+		return ""
+	}
+	return c.Secret
+}
+
+// gitAuth 为一次 git 远程操作准备认证参数
+//
+// 返回实际使用的远程 URL、额外环境变量和清理函数（删除临时私钥）。
+func gitAuth(rawURL, provider string, cred *GitCredential) (string, []string, func(), error) {
+	noop := func() {}
+	if cred == nil {
+		return rawURL, nil, noop, nil
+	}
+
+	switch cred.Type {
+	case credentialTypeHTTPSToken:
+		u, err := authenticatedURL(rawURL, cred.Secret, provider)
+		if err != nil {
+			return "", nil, noop, err
+		}
+		if cred.Username != "" && strings.HasPrefix(rawURL, "http") {
+			u = strings.Replace(u, "x-access-token:", cred.Username+":", 1)
+			u = strings.Replace(u, "oauth2:", cred.Username+":", 1)
+		}
+		return u, nil, noop, nil
+
+	case credentialTypeSSHKey:
+		f, err := os.CreateTemp("", "agents-git-key-*")
+		if err != nil {
+			return "", nil, noop, fmt.Errorf("创建临时私钥失败: %w", err)
+		}
+		cleanup := func() { os.Remove(f.Name()) }
+		key := cred.Secret
+		if !strings.HasSuffix(key, "\n") {
+			key += "\n" // OpenSSH 要求私钥以换行结尾
+		}
+		if _, err := f.WriteString(key); err != nil {
+			f.Close()
+			cleanup()
+			return "", nil, noop, fmt.Errorf("写入临时私钥失败: %w", err)
+		}
+		f.Close()
+		os.Chmod(f.Name(), 0600)
+		sshCmd := fmt.Sprintf("ssh -i %s -o IdentitiesOnly=yes -o StrictHostKeyChecking=accept-new", f.Name())
+		return rawURL, []string{"GIT_SSH_COMMAND=" + sshCmd}, cleanup, nil
+	}
+	return "", nil, noop, fmt.Errorf("不支持的凭据类型: %s", cred.Type)
+}
+
+// resolveCredential 通过 API Server 解析凭据明文
+// POST /api/v1/credentials/resolve（节点专属凭证认证，只能解析分配给本节点的 Run 引用的凭据）
+func (nm *NodeManager) resolveCredential(ctx context.Context, name string) (*GitCredential, error) {
+	body, _ := json.Marshal(map[string]string{"name": name})
+	req, err := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/credentials/resolve", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("解析凭据 %s 失败: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return nil, fmt.Errorf("解析凭据 %s 失败: HTTP %d %s", name, resp.StatusCode, e.Error)
+	}
+
+	var cred GitCredential
+	if err := json.NewDecoder(resp.Body).Decode(&cred); err != nil {
+		return nil, fmt.Errorf("解析凭据 %s 响应失败: %w", name, err)
+	}
+	return &cred, nil
+}
@@ -0,0 +1,231 @@
+// Package nodemanager Run 变更报告
+//
+// Git 工作空间的 Run 结束后，对比克隆时的提交与工作空间当前内容生成变更报告
+// （逐文件的增删行数与 unified diff），上传到 API Server（POST /api/v1/runs/{id}/diff），
+// 审阅变更不需要拉取分支。
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// maxDiffPatchSize 变更报告中 unified diff 的大小上限，超过时截断
+const maxDiffPatchSize = 4 << 20
+
+// reportWorkspaceDiff 生成工作空间的变更报告并上传，失败只记录日志，不影响 Run 状态
+func (nm *NodeManager) reportWorkspaceDiff(ctx context.Context, runID string, workspace *PreparedWorkspace) {
+	diff, err := gitDiff(ctx, workspace.Path, workspace.BaseCommit, maxDiffPatchSize)
+	if err != nil {
+		log.Printf("[Workspace] 任务 %s 生成变更报告失败: %v", runID, err)
+		return
+	}
+	diff.RunID = runID
+	diff.CreatedAt = time.Now()
+	// 与输出一致，按 Run 的密钥脱敏
+	diff.Patch = nm.secretMaskerFor(runID).mask(diff.Patch)
+
+	body, err := json.Marshal(diff)
+	if err != nil {
+		log.Printf("[Workspace] 任务 %s 序列化变更报告失败: %v", runID, err)
+		return
+	}
+	req, _ := http.NewRequestWithContext(ctx, "POST",
+		nm.config.APIServerURL+"/api/v1/runs/"+runID+"/diff",
+		bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := nm.httpClient.Do(req)
+	if err != nil {
+		log.Printf("[Workspace] 任务 %s 上传变更报告失败: %v", runID, err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		log.Printf("[Workspace] 任务 %s 上传变更报告失败: %d %s", runID, resp.StatusCode, strings.TrimSpace(string(msg)))
+		return
+	}
+	log.Printf("[Workspace] 任务 %s 已上传变更报告: %d 个文件 +%d -%d", runID, diff.FilesChanged, diff.Additions, diff.Deletions)
+}
+
+// gitDiff 生成工作空间相对 base 的变更报告
+//
+// 使用临时索引暂存全部变更（含 Agent 的提交与未跟踪文件），不修改工作空间自身的索引，
+// 不影响随后的结果回写。patch 超过 maxPatch 字节时截断。
+func gitDiff(ctx context.Context, dir, base string, maxPatch int) (*model.RunDiff, error) {
+	if base == "" {
+		return nil, fmt.Errorf("缺少对比基准提交")
+	}
+	tmp, err := os.MkdirTemp("", "agents-diff-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmp)
+	index := filepath.Join(tmp, "index")
+	// 复用工作空间索引中的文件状态缓存，避免重新计算全部文件的哈希
+	if data, err := os.ReadFile(filepath.Join(dir, ".git", "index")); err == nil {
+		if err := os.WriteFile(index, data, 0o600); err != nil {
+			return nil, err
+		}
+	}
+	env := []string{"GIT_INDEX_FILE=" + index}
+
+	if _, err := runGitEnv(ctx, dir, env, "add", "-A"); err != nil {
+		return nil, err
+	}
+	numstat, err := runGitEnv(ctx, dir, env, "diff", "--cached", "-M", "--numstat", "-z", base)
+	if err != nil {
+		return nil, err
+	}
+	nameStatus, err := runGitEnv(ctx, dir, env, "diff", "--cached", "-M", "--name-status", "-z", base)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &model.RunDiff{BaseCommit: base, Files: parseNameStatus(nameStatus)}
+	stats := parseNumstat(numstat)
+	for i := range diff.Files {
+		f := &diff.Files[i]
+		if st, ok := stats[f.Path]; ok {
+			f.Additions, f.Deletions, f.Binary = st.additions, st.deletions, st.binary
+		}
+		diff.Additions += f.Additions
+		diff.Deletions += f.Deletions
+	}
+	diff.FilesChanged = len(diff.Files)
+
+	// patch 可能很大，边读边截断，不在内存中保留完整输出
+	patch := &cappedBuffer{max: maxPatch}
+	cmd := exec.CommandContext(ctx, "git", "diff", "--cached", "-M", "--no-color", "--no-ext-diff", base)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	cmd.Stdout = patch
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("git diff 失败: %w, 输出: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	diff.Patch, diff.Truncated = patch.String(), patch.truncated
+	if diff.Truncated {
+		// 截断到完整的行
+		diff.Patch = diff.Patch[:strings.LastIndexByte(diff.Patch, '\n')+1]
+	}
+	return diff, nil
+}
+
+// diffStat 单个文件的增删行数
+type diffStat struct {
+	additions, deletions int
+	binary               bool
+}
+
+// parseNumstat 解析 git diff --numstat -z 的输出，按变更后的路径索引
+//
+// 普通记录为 "<增>\t<删>\t<路径>\0"，重命名/复制为 "<增>\t<删>\t\0<原路径>\0<新路径>\0"，
+// 二进制文件的增删为 "-"。
+func parseNumstat(out string) map[string]diffStat {
+	stats := make(map[string]diffStat)
+	tokens := strings.Split(out, "\x00")
+	for i := 0; i < len(tokens); i++ {
+		parts := strings.SplitN(tokens[i], "\t", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		path := parts[2]
+		if path == "" {
+			if i+2 >= len(tokens) {
+				break
+			}
+			path = tokens[i+2]
+			i += 2
+		}
+		var st diffStat
+		if parts[0] == "-" && parts[1] == "-" {
+			st.binary = true
+		} else {
+			fmt.Sscanf(parts[0], "%d", &st.additions)
+			fmt.Sscanf(parts[1], "%d", &st.deletions)
+		}
+		stats[path] = st
+	}
+	return stats
+}
+
+// parseNameStatus 解析 git diff --name-status -z 的输出
+//
+// 记录为 "<状态>\0<路径>\0"，重命名/复制为 "R<相似度>\0<原路径>\0<新路径>\0"。
+func parseNameStatus(out string) []model.RunDiffFile {
+	files := []model.RunDiffFile{}
+	tokens := strings.Split(out, "\x00")
+	for i := 0; i < len(tokens); {
+		code := tokens[i]
+		if code == "" {
+			i++
+			continue
+		}
+		var f model.RunDiffFile
+		switch code[0] {
+		case 'R', 'C':
+			if i+2 >= len(tokens) {
+				return files
+			}
+			f.OldPath, f.Path = tokens[i+1], tokens[i+2]
+			i += 3
+		default:
+			if i+1 >= len(tokens) {
+				return files
+			}
+			f.Path = tokens[i+1]
+			i += 2
+		}
+		switch code[0] {
+		case 'A':
+			f.Status = model.RunDiffFileAdded
+		case 'D':
+			f.Status = model.RunDiffFileDeleted
+		case 'R':
+			f.Status = model.RunDiffFileRenamed
+		case 'C':
+			f.Status = model.RunDiffFileCopied
+		case 'T':
+			f.Status = model.RunDiffFileTypeChanged
+		default:
+			f.Status = model.RunDiffFileModified
+		}
+		files = append(files, f)
+	}
+	return files
+}
+
+// cappedBuffer 只保留前 max 字节的写入缓冲，超出部分丢弃（写入始终成功，避免子进程因管道关闭而失败）
+type cappedBuffer struct {
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+}
+
+func (b *cappedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); len(p) > room {
+		b.truncated = true
+		if room > 0 {
+			b.buf.Write(p[:room])
+		}
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *cappedBuffer) String() string { return b.buf.String() }
@@ -0,0 +1,107 @@
+package nodemanager
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestGitDiff(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	ctx := context.Background()
+	work := t.TempDir()
+	mustGit := func(args ...string) string {
+		t.Helper()
+		out, err := runGit(ctx, work, args...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.TrimSpace(out)
+	}
+	mustGit("init", "-b", "main")
+	os.WriteFile(filepath.Join(work, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	os.WriteFile(filepath.Join(work, "old.txt"), []byte(strings.Repeat("line\n", 20)), 0644)
+	os.WriteFile(filepath.Join(work, "gone.txt"), []byte("bye\n"), 0644)
+	mustGit("add", "-A")
+	mustGit("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-m", "init")
+	base := mustGit("rev-parse", "HEAD")
+
+	// Agent 自行提交一部分变更，其余留在工作区（含未跟踪文件）
+	os.WriteFile(filepath.Join(work, "main.go"), []byte("package main\n\nimport \"fmt\"\n\nfunc main() { fmt.Println() }\n"), 0644)
+	mustGit("-c", "user.name=t", "-c", "user.email=t@t", "commit", "-am", "agent")
+	mustGit("mv", "old.txt", "new.txt")
+	os.Remove(filepath.Join(work, "gone.txt"))
+	os.WriteFile(filepath.Join(work, "notes.md"), []byte("a\nb\n"), 0644)
+	os.WriteFile(filepath.Join(work, "logo.bin"), []byte{0, 1, 2, 0}, 0644)
+	indexBefore, _ := os.ReadFile(filepath.Join(work, ".git", "index"))
+
+	diff, err := gitDiff(ctx, work, base, maxDiffPatchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]model.RunDiffFile{}
+	for _, f := range diff.Files {
+		got[f.Path] = f
+	}
+	want := map[string]model.RunDiffFile{
+		"main.go":  {Path: "main.go", Status: model.RunDiffFileModified, Additions: 3, Deletions: 1},
+		"new.txt":  {Path: "new.txt", OldPath: "old.txt", Status: model.RunDiffFileRenamed},
+		"gone.txt": {Path: "gone.txt", Status: model.RunDiffFileDeleted, Deletions: 1},
+		"notes.md": {Path: "notes.md", Status: model.RunDiffFileAdded, Additions: 2},
+		"logo.bin": {Path: "logo.bin", Status: model.RunDiffFileAdded, Binary: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("files = %+v", diff.Files)
+	}
+	for path, w := range want {
+		if got[path] != w {
+			t.Errorf("%s = %+v, want %+v", path, got[path], w)
+		}
+	}
+	if diff.FilesChanged != 5 || diff.Additions != 5 || diff.Deletions != 2 || diff.BaseCommit != base {
+		t.Errorf("totals = %d files +%d -%d base %s", diff.FilesChanged, diff.Additions, diff.Deletions, diff.BaseCommit)
+	}
+	if !strings.Contains(diff.Patch, "+import \"fmt\"") || diff.Truncated {
+		t.Errorf("patch = %q truncated=%v", diff.Patch, diff.Truncated)
+	}
+
+	// 不修改工作空间的索引
+	if indexAfter, _ := os.ReadFile(filepath.Join(work, ".git", "index")); string(indexAfter) != string(indexBefore) {
+		t.Error("workspace index modified")
+	}
+
+	truncated, err := gitDiff(ctx, work, base, 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !truncated.Truncated || len(truncated.Patch) > 64 || !strings.HasSuffix(truncated.Patch, "\n") {
+		t.Errorf("truncated patch = %q", truncated.Patch)
+	}
+	if truncated.FilesChanged != 5 {
+		t.Errorf("truncation should keep file stats, got %d files", truncated.FilesChanged)
+	}
+
+	if _, err := gitDiff(ctx, work, "", maxDiffPatchSize); err == nil {
+		t.Error("expected error without base commit")
+	}
+}
+
+func TestParseNumstat(t *testing.T) {
+	stats := parseNumstat("3\t1\tmain.go\x00-\t-\tlogo.bin\x000\t0\t\x00old name.txt\x00new name.txt\x00")
+	if stats["main.go"] != (diffStat{additions: 3, deletions: 1}) {
+		t.Errorf("main.go = %+v", stats["main.go"])
+	}
+	if !stats["logo.bin"].binary {
+		t.Errorf("logo.bin = %+v", stats["logo.bin"])
+	}
+	if _, ok := stats["new name.txt"]; !ok || len(stats) != 3 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
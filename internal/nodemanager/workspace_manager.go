@@ -3,7 +3,9 @@
 // 负责任务执行前的 Workspace 准备工作：
 //   - Git 类型：克隆仓库到指定目录
 //   - Local 类型：验证目录存在
-//   - Volume 类型：准备 Docker Volume
+//   - Volume 类型：准备容器运行时 Volume
+//
+// 执行完成后的 Git 结果回写见 workspace_sync.go
 package nodemanager
 
 import (
@@ -15,11 +17,15 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"agents-admin/internal/shared/model"
 )
 
 // WorkspaceManager Workspace 管理器
 type WorkspaceManager struct {
-	baseDir string // 工作空间基础目录
+	baseDir            string             // 工作空间基础目录
+	credentialResolver CredentialResolver // 凭据解析（credential_ref），可选
+	containers         ContainerRuntime   // 容器运行时（Volume 类型），为空时使用 docker CLI
 }
 
 // NewWorkspaceManager 创建 Workspace 管理器
@@ -35,6 +41,27 @@ func NewWorkspaceManager(baseDir string) *WorkspaceManager {
 	}
 }
 
+// SetCredentialResolver 设置凭据解析函数
+func (m *WorkspaceManager) SetCredentialResolver(r CredentialResolver) {
+	m.credentialResolver = r
+}
+
+// SetContainerRuntime 设置 Volume 类型工作空间使用的容器运行时
+func (m *WorkspaceManager) SetContainerRuntime(rt ContainerRuntime) {
+	m.containers = rt
+}
+
+// ResolveCredential 解析 Git 配置引用的凭据，未配置 credential_ref 时返回 nil
+func (m *WorkspaceManager) ResolveCredential(ctx context.Context, config *GitConfig) (*GitCredential, error) {
+	if config == nil || config.CredentialRef == "" {
+		return nil, nil
+	}
+	if m.credentialResolver == nil {
+		return nil, fmt.Errorf("未配置凭据解析，无法使用 credential_ref: %s", config.CredentialRef)
+	}
+	return m.credentialResolver(ctx, config.CredentialRef)
+}
+
 // WorkspaceConfig Workspace 配置（从 TaskSpec 解析）
 type WorkspaceConfig struct {
 	Type   string     `json:"type"`   // git, local, volume
@@ -45,10 +72,12 @@ type WorkspaceConfig struct {
 
 // GitConfig Git 仓库配置
 type GitConfig struct {
-	URL    string `json:"url"`    // 仓库地址
-	Branch string `json:"branch"` // 分支
-	Commit string `json:"commit"` // 指定 commit
-	Depth  int    `json:"depth"`  // 克隆深度
+	URL           string      `json:"url"`            // 仓库地址
+	Branch        string      `json:"branch"`         // 分支
+	Commit        string      `json:"commit"`         // 指定 commit
+	Depth         int         `json:"depth"`          // 克隆深度
+	CredentialRef string      `json:"credential_ref"` // 凭据名称引用（可选）
+	Sync          *GitSyncCfg `json:"sync"`           // 结果回写配置（可选）
 }
 
 // LocalCfg 本地目录配置
@@ -66,6 +95,7 @@ type VolumeCfg struct {
 // PreparedWorkspace 准备好的工作空间
 type PreparedWorkspace struct {
 	Path       string   // 工作空间路径
+	BaseCommit string   // Git 工作空间克隆（检出）后的提交，变更报告的对比基准
 	ReadOnly   bool     // 是否只读
 	MountArgs  []string // Docker 挂载参数
 	Cleanup    func()   // 清理函数
@@ -111,6 +141,23 @@ func (m *WorkspaceManager) prepareGit(ctx context.Context, runID string, config
 
 	log.Printf("[Workspace] 克隆 Git 仓库: %s -> %s", config.URL, workDir)
 
+	// 解析凭据（私有仓库）
+	cred, err := m.ResolveCredential(ctx, config)
+	if err != nil {
+		os.RemoveAll(workDir)
+		return nil, err
+	}
+	provider := ""
+	if config.Sync != nil {
+		provider = config.Sync.Provider
+	}
+	cloneURL, authEnv, cleanupAuth, err := gitAuth(config.URL, detectGitProvider(config.URL, provider), cred)
+	if err != nil {
+		os.RemoveAll(workDir)
+		return nil, err
+	}
+	defer cleanupAuth()
+
 	// 构建 git clone 命令
 	args := []string{"clone"}
 
@@ -124,16 +171,24 @@ func (m *WorkspaceManager) prepareGit(ctx context.Context, runID string, config
 		args = append(args, "-b", config.Branch)
 	}
 
-	args = append(args, config.URL, workDir)
+	args = append(args, cloneURL, workDir)
 
 	// 执行克隆
 	cmd := exec.CommandContext(ctx, "git", args...)
-	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), authEnv...)
 
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		os.RemoveAll(workDir)
-		return nil, fmt.Errorf("git clone 失败: %w, 输出: %s", err, string(output))
+		return nil, fmt.Errorf("git clone 失败: %w, 输出: %s", err, redactToken(string(output), cred.token()))
+	}
+
+	// 使用 token 克隆时重置 origin，避免凭据残留在 .git/config（工作空间会被复制进容器）
+	if cloneURL != config.URL {
+		if _, err := runGit(ctx, workDir, "remote", "set-url", "origin", config.URL); err != nil {
+			os.RemoveAll(workDir)
+			return nil, err
+		}
 	}
 
 	// 如果指定了 commit，切换到该 commit
@@ -146,6 +201,12 @@ func (m *WorkspaceManager) prepareGit(ctx context.Context, runID string, config
 		}
 	}
 
+	// 记录对比基准：Agent 自行提交后 HEAD 会移动
+	baseCommit := ""
+	if sha, err := runGit(ctx, workDir, "rev-parse", "HEAD"); err == nil {
+		baseCommit = strings.TrimSpace(sha)
+	}
+
 	log.Printf("[Workspace] Git 仓库准备完成: %s", workDir)
 
 	// 容器内工作目录
@@ -153,6 +214,7 @@ func (m *WorkspaceManager) prepareGit(ctx context.Context, runID string, config
 
 	return &PreparedWorkspace{
 		Path:       workDir,
+		BaseCommit: baseCommit,
 		ReadOnly:   false,
 		MountArgs:  []string{"-v", fmt.Sprintf("%s:%s", workDir, containerWorkDir)},
 		WorkingDir: containerWorkDir,
@@ -204,16 +266,17 @@ func (m *WorkspaceManager) prepareVolume(ctx context.Context, runID string, conf
 		return nil, fmt.Errorf("Volume 名称不能为空")
 	}
 
-	log.Printf("[Workspace] 使用 Docker Volume: %s", config.Name)
+	rt := m.containers
+	if rt == nil {
+		rt = defaultContainerRuntime
+	}
+	log.Printf("[Workspace] 使用 %s Volume: %s", rt.Name(), config.Name)
 
-	// 检查 Volume 是否存在
-	cmd := exec.CommandContext(ctx, "docker", "volume", "inspect", config.Name)
-	if err := cmd.Run(); err != nil {
-		// Volume 不存在，创建它
-		log.Printf("[Workspace] 创建 Docker Volume: %s", config.Name)
-		cmd = exec.CommandContext(ctx, "docker", "volume", "create", config.Name)
-		if output, err := cmd.CombinedOutput(); err != nil {
-			return nil, fmt.Errorf("创建 Volume 失败: %w, 输出: %s", err, string(output))
+	// Volume 不存在时创建
+	if !rt.VolumeExists(ctx, config.Name) {
+		log.Printf("[Workspace] 创建 %s Volume: %s", rt.Name(), config.Name)
+		if err := rt.CreateVolume(ctx, config.Name); err != nil {
+			return nil, fmt.Errorf("创建 Volume 失败: %w", err)
 		}
 	}
 
@@ -261,46 +324,49 @@ func (m *WorkspaceManager) CleanupOldWorkspaces(ctx context.Context, maxAge time
 }
 
 // ParseWorkspaceConfig 从任务快照中解析 Workspace 配置
-func ParseWorkspaceConfig(snapshot map[string]interface{}) *WorkspaceConfig {
-	wsRaw, ok := snapshot["workspace"]
-	if !ok || wsRaw == nil {
-		return nil
-	}
-
-	ws, ok := wsRaw.(map[string]interface{})
-	if !ok {
+func ParseWorkspaceConfig(snapshot *model.RunSnapshot) *WorkspaceConfig {
+	ws := snapshot.Workspace
+	if ws == nil || ws.Type == "" {
 		return nil
 	}
 
-	wsType, _ := ws["type"].(string)
-	if wsType == "" {
-		return nil
-	}
-
-	config := &WorkspaceConfig{Type: wsType}
+	config := &WorkspaceConfig{Type: string(ws.Type)}
 
-	switch wsType {
-	case "git":
-		if gitRaw, ok := ws["git"].(map[string]interface{}); ok {
+	switch ws.Type {
+	case model.WorkspaceTypeGit:
+		if git := ws.Git; git != nil {
 			config.Git = &GitConfig{
-				URL:    getStringField(gitRaw, "url"),
-				Branch: getStringField(gitRaw, "branch"),
-				Commit: getStringField(gitRaw, "commit"),
-				Depth:  getIntField(gitRaw, "depth"),
+				URL:    git.URL,
+				Branch: git.Branch,
+				Commit: git.Commit,
+				Depth:  git.Depth,
+
+				CredentialRef: git.CredentialRef,
+			}
+			if sync := git.Sync; sync != nil {
+				config.Git.Sync = &GitSyncCfg{
+					Enabled:       sync.Enabled,
+					BranchPrefix:  sync.BranchPrefix,
+					CommitMessage: sync.CommitMessage,
+					CreatePR:      sync.CreatePR,
+					Provider:      sync.Provider,
+					TargetBranch:  sync.TargetBranch,
+					PRTitle:       sync.PRTitle,
+				}
 			}
 		}
-	case "local":
-		if localRaw, ok := ws["local"].(map[string]interface{}); ok {
+	case model.WorkspaceTypeLocal:
+		if local := ws.Local; local != nil {
 			config.Local = &LocalCfg{
-				Path:     getStringField(localRaw, "path"),
-				ReadOnly: getBoolField(localRaw, "read_only"),
+				Path:     local.Path,
+				ReadOnly: local.ReadOnly,
 			}
 		}
-	case "volume":
-		if volumeRaw, ok := ws["volume"].(map[string]interface{}); ok {
+	case model.WorkspaceTypeVolume:
+		if volume := ws.Volume; volume != nil {
 			config.Volume = &VolumeCfg{
-				Name:    getStringField(volumeRaw, "name"),
-				SubPath: getStringField(volumeRaw, "sub_path"),
+				Name:    volume.Name,
+				SubPath: volume.SubPath,
 			}
 		}
 	}
@@ -308,31 +374,6 @@ func ParseWorkspaceConfig(snapshot map[string]interface{}) *WorkspaceConfig {
 	return config
 }
 
-// 辅助函数
-func getStringField(m map[string]interface{}, key string) string {
-	if v, ok := m[key].(string); ok {
-		return v
-	}
-	return ""
-}
-
-func getIntField(m map[string]interface{}, key string) int {
-	switch v := m[key].(type) {
-	case float64:
-		return int(v)
-	case int:
-		return v
-	}
-	return 0
-}
-
-func getBoolField(m map[string]interface{}, key string) bool {
-	if v, ok := m[key].(bool); ok {
-		return v
-	}
-	return false
-}
-
 // SanitizeWorkspacePath 清理工作空间路径中的不安全字符
 func SanitizeWorkspacePath(path string) string {
 	// 移除路径遍历字符
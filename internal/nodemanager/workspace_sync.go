@@ -0,0 +1,331 @@
+// Package nodemanager Git 结果回写
+//
+// Run 成功完成后，将 Agent 在工作空间中的变更同步回 Git 仓库：
+//  1. 在生成的分支上提交全部变更（{branch_prefix}{run_id}）
+//  2. 使用 credential_ref 引用的凭据（或节点本地配置的 GIT_TOKEN）推送到远端
+//  3. 可选：创建 GitHub Pull Request / GitLab Merge Request
+package nodemanager
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// 结果回写默认值
+const (
+	defaultSyncBranchPrefix = "agents/"
+	syncCommitAuthorName    = "agents-admin"
+	syncCommitAuthorEmail   = "agents-admin@localhost"
+)
+
+// GitSyncCfg 结果回写配置（对应 model.GitSyncConfig）
+type GitSyncCfg struct {
+	Enabled       bool   `json:"enabled"`        // 是否启用
+	BranchPrefix  string `json:"branch_prefix"`  // 生成分支前缀
+	CommitMessage string `json:"commit_message"` // 提交信息
+	CreatePR      bool   `json:"create_pr"`      // 是否创建 PR/MR
+	Provider      string `json:"provider"`       // github / gitlab，为空时根据 URL 推断
+	TargetBranch  string `json:"target_branch"`  // PR 目标分支
+	PRTitle       string `json:"pr_title"`       // PR 标题
+}
+
+// GitSyncResult 结果回写结果
+type GitSyncResult struct {
+	Branch    string `json:"branch"`           // 推送的分支
+	CommitSHA string `json:"commit_sha"`       // 提交 SHA
+	PRURL     string `json:"pr_url,omitempty"` // PR/MR 地址
+	NoChanges bool   `json:"no_changes"`       // 工作空间无变更，未提交
+}
+
+// gitRepoRef 解析后的仓库地址
+type gitRepoRef struct {
+	Scheme string // https / http / ssh
+	Host   string // 主机（可含端口）
+	Path   string // owner/repo（无 .git 后缀）
+}
+
+// SyncGit 将工作空间变更提交到新分支并推送，按需创建 PR
+//
+// cred 为推送与 API 调用所用凭据，为 nil 时依赖节点本地的 git 凭据配置（如 SSH agent）；
+// 创建 PR 需要 https_token 类型凭据。title 为默认提交信息/PR 标题（通常为任务名称）。
+func (m *WorkspaceManager) SyncGit(ctx context.Context, runID, workDir string, config *GitConfig, cred *GitCredential, title string) (*GitSyncResult, error) {
+	if config == nil || config.Sync == nil || !config.Sync.Enabled {
+		return nil, fmt.Errorf("未启用 Git 结果回写")
+	}
+	sync := config.Sync
+
+	// 检查是否有变更
+	status, err := runGit(ctx, workDir, "status", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(status) == "" {
+		log.Printf("[Workspace] 工作空间无变更，跳过回写: %s", runID)
+		return &GitSyncResult{NoChanges: true}, nil
+	}
+
+	prefix := sync.BranchPrefix
+	if prefix == "" {
+		prefix = defaultSyncBranchPrefix
+	}
+	branch := prefix + runID
+
+	message := sync.CommitMessage
+	if message == "" {
+		message = title
+	}
+	if message == "" {
+		message = "Agent run " + runID
+	}
+
+	if _, err := runGit(ctx, workDir, "checkout", "-b", branch); err != nil {
+		return nil, err
+	}
+	if _, err := runGit(ctx, workDir, "add", "-A"); err != nil {
+		return nil, err
+	}
+	if _, err := runGit(ctx, workDir,
+		"-c", "user.name="+syncCommitAuthorName,
+		"-c", "user.email="+syncCommitAuthorEmail,
+		"commit", "-m", message); err != nil {
+		return nil, err
+	}
+	sha, err := runGit(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	result := &GitSyncResult{Branch: branch, CommitSHA: strings.TrimSpace(sha)}
+
+	// 推送（凭据只出现在命令参数/临时文件中，不写入 .git/config，也不打印到日志）
+	token := cred.token()
+	pushURL, authEnv, cleanupAuth, err := gitAuth(config.URL, detectGitProvider(config.URL, sync.Provider), cred)
+	if err != nil {
+		return nil, err
+	}
+	defer cleanupAuth()
+	if _, err := runGitEnv(ctx, workDir, authEnv, "push", pushURL, branch+":"+branch); err != nil {
+		return nil, fmt.Errorf("git push 失败: %s", redactToken(err.Error(), token))
+	}
+	log.Printf("[Workspace] 已推送分支: %s (%s)", branch, result.CommitSHA)
+
+	if sync.CreatePR {
+		target := sync.TargetBranch
+		if target == "" {
+			target = config.Branch
+		}
+		if target == "" {
+			if target, err = remoteDefaultBranch(ctx, workDir); err != nil {
+				return result, err
+			}
+		}
+		prTitle := sync.PRTitle
+		if prTitle == "" {
+			prTitle = message
+		}
+		prURL, err := createPullRequest(ctx, config.URL, detectGitProvider(config.URL, sync.Provider), token, branch, target, prTitle)
+		if err != nil {
+			return result, fmt.Errorf("创建 PR 失败: %w", err)
+		}
+		result.PRURL = prURL
+		log.Printf("[Workspace] 已创建 PR: %s", prURL)
+	}
+
+	return result, nil
+}
+
+// runGit 在指定目录执行 git 命令，返回标准输出
+func runGit(ctx context.Context, dir string, args ...string) (string, error) {
+	return runGitEnv(ctx, dir, nil, args...)
+}
+
+// runGitEnv 同 runGit，附加环境变量（如 GIT_SSH_COMMAND）
+func runGitEnv(ctx context.Context, dir string, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), env...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s 失败: %w, 输出: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// remoteDefaultBranch 获取 origin 默认分支（用于未指定目标分支时）
+func remoteDefaultBranch(ctx context.Context, dir string) (string, error) {
+	out, err := runGit(ctx, dir, "symbolic-ref", "--short", "refs/remotes/origin/HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(strings.TrimSpace(out), "origin/"), nil
+}
+
+// parseGitURL 解析 HTTPS 或 SCP 风格（git@host:owner/repo.git）的仓库地址
+func parseGitURL(raw string) (*gitRepoRef, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, fmt.Errorf("Git URL 不能为空")
+	}
+
+	// SCP 风格：git@github.com:owner/repo.git
+	if !strings.Contains(raw, "://") {
+		at := strings.Index(raw, "@")
+		colon := strings.Index(raw, ":")
+		if colon <= at+1 {
+			return nil, fmt.Errorf("无法解析 Git URL: %s", raw)
+		}
+		return &gitRepoRef{
+			Scheme: "ssh",
+			Host:   raw[at+1 : colon],
+			Path:   strings.TrimSuffix(strings.Trim(raw[colon+1:], "/"), ".git"),
+		}, nil
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 Git URL: %w", err)
+	}
+	return &gitRepoRef{
+		Scheme: u.Scheme,
+		Host:   u.Host,
+		Path:   strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git"),
+	}, nil
+}
+
+// detectGitProvider 推断代码托管平台（显式配置优先）
+func detectGitProvider(rawURL, provider string) string {
+	if provider != "" {
+		return strings.ToLower(provider)
+	}
+	ref, err := parseGitURL(rawURL)
+	if err != nil {
+		return ""
+	}
+	host := strings.ToLower(ref.Host)
+	switch {
+	case strings.Contains(host, "github"):
+		return "github"
+	case strings.Contains(host, "gitlab"):
+		return "gitlab"
+	}
+	return ""
+}
+
+// authenticatedURL 将 token 注入 HTTPS 推送地址
+//
+// GitHub 使用 x-access-token 用户名，GitLab 使用 oauth2。
+// SSH 地址或 token 为空时原样返回，由节点本地凭据处理。
+func authenticatedURL(rawURL, token, provider string) (string, error) {
+	if token == "" || !strings.HasPrefix(rawURL, "http") {
+		return rawURL, nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("无法解析 Git URL: %w", err)
+	}
+	user := "x-access-token"
+	if provider == "gitlab" {
+		user = "oauth2"
+	}
+	u.User = url.UserPassword(user, token)
+	return u.String(), nil
+}
+
+// redactToken 从错误信息中移除凭据
+func redactToken(s, token string) string {
+	if token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, token, "***")
+}
+
+// createPullRequest 调用 GitHub / GitLab API 创建 PR/MR，返回 Web 地址
+func createPullRequest(ctx context.Context, rawURL, provider, token, head, base, title string) (string, error) {
+	if token == "" {
+		return "", fmt.Errorf("创建 PR 需要 HTTPS token 凭据")
+	}
+	ref, err := parseGitURL(rawURL)
+	if err != nil {
+		return "", err
+	}
+	apiURL, err := pullRequestEndpoint(ref, provider)
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]string{"title": title}
+	header := http.Header{}
+	urlField := "html_url"
+	switch provider {
+	case "github":
+		payload["head"] = head
+		payload["base"] = base
+		header.Set("Authorization", "Bearer "+token)
+		header.Set("Accept", "application/vnd.github+json")
+	case "gitlab":
+		payload["source_branch"] = head
+		payload["target_branch"] = base
+		header.Set("PRIVATE-TOKEN", token)
+		urlField = "web_url"
+	}
+	data, _ := json.Marshal(payload)
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+	req.Header.Set("Content-Type", "application/json")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s API 返回 %d: %s", provider, resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal(respBody, &result); err != nil {
+		return "", fmt.Errorf("解析 %s API 响应失败: %w", provider, err)
+	}
+	prURL, _ := result[urlField].(string)
+	if prURL == "" {
+		return "", fmt.Errorf("%s API 响应缺少 %s", provider, urlField)
+	}
+	return prURL, nil
+}
+
+// pullRequestEndpoint 计算创建 PR/MR 的 API 地址
+//
+//   - GitHub：api.github.com，GitHub Enterprise 使用 https://{host}/api/v3
+//   - GitLab：{scheme}://{host}/api/v4/projects/{url-encoded path}/merge_requests
+func pullRequestEndpoint(ref *gitRepoRef, provider string) (string, error) {
+	scheme := "https"
+	if ref.Scheme == "http" {
+		scheme = "http"
+	}
+	switch provider {
+	case "github":
+		if strings.EqualFold(ref.Host, "github.com") {
+			return "https://api.github.com/repos/" + ref.Path + "/pulls", nil
+		}
+		return scheme + "://" + ref.Host + "/api/v3/repos/" + ref.Path + "/pulls", nil
+	case "gitlab":
+		return scheme + "://" + ref.Host + "/api/v4/projects/" + url.PathEscape(ref.Path) + "/merge_requests", nil
+	}
+	return "", fmt.Errorf("不支持的代码托管平台: %q（请在 sync.provider 中指定 github 或 gitlab）", provider)
+}
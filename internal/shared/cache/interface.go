@@ -5,6 +5,9 @@ package cache
 
 import (
 	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
 )
 
 // ============================================================================
@@ -40,6 +43,44 @@ type NodeHeartbeatCache interface {
 	ListOnlineNodes(ctx context.Context) ([]string, error)
 }
 
+// AccountUsageCache 账号用量计数接口（账号池调度）
+//
+// 每个 Run 至多持有一个账号租约；maxConcurrent、dailyLimit 为 0 表示不限制。
+type AccountUsageCache interface {
+	// AcquireAccount 原子地检查并占用账号：并发数与当日请求数均未达上限时记录租约并返回 true。
+	// 同一 Run 重复获取同一账号是幂等的。
+	AcquireAccount(ctx context.Context, accountID, runID string, maxConcurrent, dailyLimit int) (bool, error)
+	// ReleaseAccount 释放 Run 的账号租约，返回被释放的账号 ID（没有租约时为空）
+	ReleaseAccount(ctx context.Context, runID string) (string, error)
+	GetAccountLease(ctx context.Context, runID string) (string, error)
+	// MarkAccountExhausted 标记账号额度耗尽，until 之前不再被选中
+	MarkAccountExhausted(ctx context.Context, accountID string, until time.Time) error
+	GetAccountUsage(ctx context.Context, accountID string) (*AccountUsage, error)
+	// IncrAccountFailovers 递增 Run 的账号切换次数并返回递增后的值
+	IncrAccountFailovers(ctx context.Context, runID string) (int64, error)
+}
+
+// NodeMetricsCache 节点资源指标滑动窗口接口（least_loaded 调度策略）
+type NodeMetricsCache interface {
+	// AppendNodeMetrics 追加一次采样，并清理采集时间早于 window 的采样
+	AppendNodeMetrics(ctx context.Context, nodeID string, sample *model.NodeMetrics, window time.Duration) error
+	// ListNodeMetrics 返回采集时间不早于 since 的采样（按采集时间升序）
+	ListNodeMetrics(ctx context.Context, nodeID string, since time.Time) ([]*model.NodeMetrics, error)
+}
+
+// RateLimitCache 限流令牌桶与认证失败计数接口（多个 API Server 副本共享）
+type RateLimitCache interface {
+	// TakeRateToken 从 key 的令牌桶取一个令牌（每秒补充 rate 个，容量 burst），
+	// 令牌不足时返回 false 与建议的重试等待时间
+	TakeRateToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error)
+	// RecordAuthFailure 记录一次认证失败：window 内累计 maxFailures 次后锁定 lockout，返回锁定时长（未锁定为 0）
+	RecordAuthFailure(ctx context.Context, key string, maxFailures int, window, lockout time.Duration) (time.Duration, error)
+	// AuthLockout 返回 key 剩余的锁定时长（未锁定为 0）
+	AuthLockout(ctx context.Context, key string) (time.Duration, error)
+	// ResetAuthFailures 清除失败计数（认证成功时调用）
+	ResetAuthFailures(ctx context.Context, key string) error
+}
+
 // ============================================================================
 // 组合接口
 // ============================================================================
@@ -49,5 +90,8 @@ type Cache interface {
 	AuthSessionCache
 	WorkflowStateCache
 	NodeHeartbeatCache
+	AccountUsageCache
+	NodeMetricsCache
+	RateLimitCache
 	Close() error
 }
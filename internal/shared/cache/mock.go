@@ -3,6 +3,9 @@ package cache
 
 import (
 	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
 )
 
 // ============================================================================
@@ -73,5 +76,50 @@ func (c *NoOpCache) ListOnlineNodes(ctx context.Context) ([]string, error) {
 	return []string{}, nil
 }
 
+// AccountUsageCache 方法
+
+func (c *NoOpCache) AcquireAccount(ctx context.Context, accountID, runID string, maxConcurrent, dailyLimit int) (bool, error) {
+	return true, nil
+}
+func (c *NoOpCache) ReleaseAccount(ctx context.Context, runID string) (string, error) {
+	return "", nil
+}
+func (c *NoOpCache) GetAccountLease(ctx context.Context, runID string) (string, error) {
+	return "", nil
+}
+func (c *NoOpCache) MarkAccountExhausted(ctx context.Context, accountID string, until time.Time) error {
+	return nil
+}
+func (c *NoOpCache) GetAccountUsage(ctx context.Context, accountID string) (*AccountUsage, error) {
+	return &AccountUsage{}, nil
+}
+func (c *NoOpCache) IncrAccountFailovers(ctx context.Context, runID string) (int64, error) {
+	return 0, nil
+}
+
+// NodeMetricsCache 方法
+
+func (c *NoOpCache) AppendNodeMetrics(ctx context.Context, nodeID string, sample *model.NodeMetrics, window time.Duration) error {
+	return nil
+}
+func (c *NoOpCache) ListNodeMetrics(ctx context.Context, nodeID string, since time.Time) ([]*model.NodeMetrics, error) {
+	return nil, nil
+}
+
+// RateLimitCache 方法（总是放行）
+
+func (c *NoOpCache) TakeRateToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	return true, 0, nil
+}
+func (c *NoOpCache) RecordAuthFailure(ctx context.Context, key string, maxFailures int, window, lockout time.Duration) (time.Duration, error) {
+	return 0, nil
+}
+func (c *NoOpCache) AuthLockout(ctx context.Context, key string) (time.Duration, error) {
+	return 0, nil
+}
+func (c *NoOpCache) ResetAuthFailures(ctx context.Context, key string) error {
+	return nil
+}
+
 // 确保 NoOpCache 实现了 Cache 接口
 var _ Cache = (*NoOpCache)(nil)
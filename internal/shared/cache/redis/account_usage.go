@@ -0,0 +1,137 @@
+// Package redis 账号用量计数操作
+package redis
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"agents-admin/internal/shared/cache"
+)
+
+// acquireAccountScript 原子地检查并占用账号
+//
+// KEYS: [1] active ZSET, [2] 当日请求计数, [3] 租约, [4] 冷却标记
+// ARGV: [1] run_id, [2] account_id, [3] 当前时间（秒）, [4] 并发上限, [5] 每日上限,
+// [6] 租约过期截止（秒）, [7] 计数 TTL（秒）, [8] 租约 TTL（秒）
+var acquireAccountScript = redis.NewScript(`
+local leased = redis.call('GET', KEYS[3])
+if leased == ARGV[2] then
+	return 1
+end
+if leased then
+	return 0
+end
+if redis.call('EXISTS', KEYS[4]) == 1 then
+	return 0
+end
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', ARGV[6])
+local maxConcurrent = tonumber(ARGV[4])
+if maxConcurrent > 0 and redis.call('ZCARD', KEYS[1]) >= maxConcurrent then
+	return 0
+end
+local daily = tonumber(ARGV[5])
+if daily > 0 and tonumber(redis.call('GET', KEYS[2]) or '0') >= daily then
+	return 0
+end
+redis.call('ZADD', KEYS[1], ARGV[3], ARGV[1])
+redis.call('INCR', KEYS[2])
+redis.call('EXPIRE', KEYS[2], ARGV[7])
+redis.call('SET', KEYS[3], ARGV[2], 'EX', ARGV[8])
+return 1
+`)
+
+func accountRequestsKey(accountID string, t time.Time) string {
+	return cache.KeyAccountRequests + accountID + ":" + t.UTC().Format("20060102")
+}
+
+// AcquireAccount 检查并占用账号
+func (s *Store) AcquireAccount(ctx context.Context, accountID, runID string, maxConcurrent, dailyLimit int) (bool, error) {
+	now := time.Now()
+	keys := []string{
+		cache.KeyAccountActive + accountID,
+		accountRequestsKey(accountID, now),
+		cache.KeyAccountLease + runID,
+		cache.KeyAccountExhausted + accountID,
+	}
+	res, err := acquireAccountScript.Run(ctx, s.client, keys,
+		runID, accountID, now.Unix(), maxConcurrent, dailyLimit,
+		now.Add(-cache.TTLAccountLease).Unix(),
+		int64(cache.TTLAccountRequests/time.Second), int64(cache.TTLAccountLease/time.Second),
+	).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
+
+// ReleaseAccount 释放 Run 的账号租约
+func (s *Store) ReleaseAccount(ctx context.Context, runID string) (string, error) {
+	accountID, err := s.GetAccountLease(ctx, runID)
+	if err != nil || accountID == "" {
+		return "", err
+	}
+	pipe := s.client.TxPipeline()
+	pipe.ZRem(ctx, cache.KeyAccountActive+accountID, runID)
+	pipe.Del(ctx, cache.KeyAccountLease+runID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return "", err
+	}
+	return accountID, nil
+}
+
+// GetAccountLease 获取 Run 持有的账号
+func (s *Store) GetAccountLease(ctx context.Context, runID string) (string, error) {
+	accountID, err := s.client.Get(ctx, cache.KeyAccountLease+runID).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return accountID, err
+}
+
+// MarkAccountExhausted 标记账号额度耗尽
+func (s *Store) MarkAccountExhausted(ctx context.Context, accountID string, until time.Time) error {
+	ttl := time.Until(until)
+	if ttl <= 0 {
+		return nil
+	}
+	return s.client.Set(ctx, cache.KeyAccountExhausted+accountID, until.UTC().Format(time.RFC3339), ttl).Err()
+}
+
+// GetAccountUsage 获取账号用量
+func (s *Store) GetAccountUsage(ctx context.Context, accountID string) (*cache.AccountUsage, error) {
+	now := time.Now()
+	activeKey := cache.KeyAccountActive + accountID
+	pipe := s.client.Pipeline()
+	active := pipe.ZCount(ctx, activeKey, strconv.FormatInt(now.Add(-cache.TTLAccountLease).Unix(), 10), "+inf")
+	requests := pipe.Get(ctx, accountRequestsKey(accountID, now))
+	exhausted := pipe.Get(ctx, cache.KeyAccountExhausted+accountID)
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return nil, err
+	}
+
+	usage := &cache.AccountUsage{ActiveRuns: int(active.Val())}
+	if n, err := requests.Int64(); err == nil {
+		usage.RequestsToday = n
+	}
+	if v, err := exhausted.Result(); err == nil {
+		if until, err := time.Parse(time.RFC3339, v); err == nil {
+			usage.ExhaustedUntil = &until
+		}
+	}
+	return usage, nil
+}
+
+// IncrAccountFailovers 递增 Run 的账号切换次数
+func (s *Store) IncrAccountFailovers(ctx context.Context, runID string) (int64, error) {
+	key := cache.KeyAccountFailovers + runID
+	pipe := s.client.TxPipeline()
+	n := pipe.Incr(ctx, key)
+	pipe.Expire(ctx, key, cache.TTLAccountLease)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return n.Val(), nil
+}
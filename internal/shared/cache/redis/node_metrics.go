@@ -0,0 +1,60 @@
+// Package redis 节点资源指标滑动窗口
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"agents-admin/internal/shared/cache"
+	"agents-admin/internal/shared/model"
+)
+
+// AppendNodeMetrics 追加节点指标采样，并清理窗口之外的采样
+//
+// 采样以 JSON 存入 ZSET（分数为采集时间毫秒数），key 的过期时间随窗口延长，节点下线后自动清理。
+func (s *Store) AppendNodeMetrics(ctx context.Context, nodeID string, sample *model.NodeMetrics, window time.Duration) error {
+	key := cache.KeyNodeMetrics + nodeID
+	if sample.CollectedAt.IsZero() {
+		sample.CollectedAt = time.Now()
+	}
+	data, err := json.Marshal(sample)
+	if err != nil {
+		return err
+	}
+	ttl := window
+	if ttl < cache.TTLNodeMetricsMin {
+		ttl = cache.TTLNodeMetricsMin
+	}
+	cutoff := sample.CollectedAt.Add(-window).UnixMilli()
+
+	pipe := s.client.TxPipeline()
+	pipe.ZAdd(ctx, key, redis.Z{Score: float64(sample.CollectedAt.UnixMilli()), Member: data})
+	pipe.ZRemRangeByScore(ctx, key, "-inf", "("+strconv.FormatInt(cutoff, 10))
+	pipe.Expire(ctx, key, ttl)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+// ListNodeMetrics 列出采集时间不早于 since 的节点指标采样（按采集时间升序）
+func (s *Store) ListNodeMetrics(ctx context.Context, nodeID string, since time.Time) ([]*model.NodeMetrics, error) {
+	members, err := s.client.ZRangeByScore(ctx, cache.KeyNodeMetrics+nodeID, &redis.ZRangeBy{
+		Min: strconv.FormatInt(since.UnixMilli(), 10),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+	samples := make([]*model.NodeMetrics, 0, len(members))
+	for _, m := range members {
+		var sample model.NodeMetrics
+		if err := json.Unmarshal([]byte(m), &sample); err != nil {
+			continue
+		}
+		samples = append(samples, &sample)
+	}
+	return samples, nil
+}
@@ -0,0 +1,93 @@
+// Package redis 限流令牌桶与认证失败计数
+package redis
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"agents-admin/internal/shared/cache"
+)
+
+// takeRateTokenScript 令牌桶取令牌
+//
+// KEYS: [1] 令牌桶
+// ARGV: [1] 每秒补充的令牌数, [2] 桶容量, [3] 当前时间（毫秒）
+// 返回 {是否取得令牌, 建议等待的毫秒数}
+var takeRateTokenScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(bucket[1]) or burst
+local ts = tonumber(bucket[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate / 1000)
+local allowed = 0
+local wait = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	wait = math.ceil((1 - tokens) * 1000 / rate)
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], math.ceil(burst * 1000 / rate) + 1000)
+return {allowed, wait}
+`)
+
+// recordAuthFailureScript 累计认证失败次数，达到上限后设置锁定标记并清零计数
+//
+// KEYS: [1] 失败计数, [2] 锁定标记
+// ARGV: [1] 失败上限, [2] 计数窗口（毫秒）, [3] 锁定时长（毫秒）
+// 返回锁定时长（毫秒，未锁定为 0）
+var recordAuthFailureScript = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+if n == 1 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+if n >= tonumber(ARGV[1]) then
+	redis.call('SET', KEYS[2], '1', 'PX', ARGV[3])
+	redis.call('DEL', KEYS[1])
+	return tonumber(ARGV[3])
+end
+return 0
+`)
+
+// TakeRateToken 从令牌桶取一个令牌
+func (s *Store) TakeRateToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	res, err := takeRateTokenScript.Run(ctx, s.client, []string{cache.KeyRateLimit + key},
+		rate, burst, time.Now().UnixMilli()).Int64Slice()
+	if err != nil {
+		return false, 0, err
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
+// RecordAuthFailure 记录一次认证失败，达到上限时锁定
+func (s *Store) RecordAuthFailure(ctx context.Context, key string, maxFailures int, window, lockout time.Duration) (time.Duration, error) {
+	ms, err := recordAuthFailureScript.Run(ctx, s.client,
+		[]string{cache.KeyAuthFailures + key, cache.KeyAuthLockout + key},
+		maxFailures, window.Milliseconds(), lockout.Milliseconds()).Int64()
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// AuthLockout 返回剩余的锁定时长
+func (s *Store) AuthLockout(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, cache.KeyAuthLockout+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	if ttl < 0 { // key 不存在或没有过期时间
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// ResetAuthFailures 清除失败计数
+func (s *Store) ResetAuthFailures(ctx context.Context, key string) error {
+	return s.client.Del(ctx, cache.KeyAuthFailures+key).Err()
+}
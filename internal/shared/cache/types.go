@@ -48,6 +48,13 @@ type NodeStatus struct {
 	UpdatedAt time.Time      `json:"updated_at"`
 }
 
+// AccountUsage 账号用量计数
+type AccountUsage struct {
+	ActiveRuns     int        `json:"active_runs"`
+	RequestsToday  int64      `json:"requests_today"`            // 当日（UTC）已派发的 Run 数
+	ExhaustedUntil *time.Time `json:"exhausted_until,omitempty"` // 额度耗尽的冷却截止时间
+}
+
 // ============================================================================
 // Key 前缀和 TTL 常量
 // ============================================================================
@@ -59,9 +66,25 @@ const (
 	KeyWorkflowState        = "workflow_state:"
 	KeyNodeHeartbeat        = "node_heartbeat:"
 	KeyOnlineNodes          = "online_nodes"
+	KeyAccountActive        = "account_active:"    // ZSET，成员为 Run ID，分数为占用时间
+	KeyAccountRequests      = "account_requests:"  // account_requests:<account_id>:<YYYYMMDD>
+	KeyAccountExhausted     = "account_exhausted:" // 值为冷却截止时间（RFC3339）
+	KeyAccountLease         = "account_lease:"     // account_lease:<run_id> -> account_id
+	KeyAccountFailovers     = "account_failovers:" // account_failovers:<run_id>，Run 的账号切换次数
+	KeyNodeMetrics          = "node_metrics:"      // ZSET，成员为采样 JSON，分数为采集时间（毫秒）
+	KeyRateLimit            = "ratelimit:"         // HASH，令牌桶剩余令牌数与更新时间（毫秒）
+	KeyAuthFailures         = "auth_failures:"     // 窗口内的认证失败次数
+	KeyAuthLockout          = "auth_lockout:"      // 锁定标记，过期即解除锁定
 
 	// TTL 常量
 	TTLAuthSession   = 10 * time.Minute
 	TTLWorkflowState = 1 * time.Hour
 	TTLNodeHeartbeat = 30 * time.Second
+
+	// TTLAccountLease 账号租约的最长保留时间，超过后视为泄漏的租约不再计入并发数
+	TTLAccountLease = 24 * time.Hour
+	// TTLAccountRequests 每日请求计数的保留时间
+	TTLAccountRequests = 48 * time.Hour
+	// TTLNodeMetricsMin 节点指标窗口 key 的最短保留时间（节点下线后自动清理）
+	TTLNodeMetricsMin = 10 * time.Minute
 )
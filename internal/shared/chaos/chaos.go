@@ -0,0 +1,220 @@
+// Package chaos 故障注入（集成测试与故障演练用）
+//
+// 包装消息队列、存储层与 HTTP 客户端，按概率丢弃 / 重复 / 延迟队列消息、让 ACK 与发布失败、
+// 返回瞬时数据库错误与 HTTP 错误，用于验证调度器的 ACK 逻辑、保底轮询与 NodeManager 的重试行为
+// 在部分失败下仍然正确。
+//
+// 集成测试直接使用 Wrap* 与 Transport；API Server 与 NodeManager 只在 chaos 构建标签下
+// 按 CHAOS_* 环境变量启用（见 ConfigFromEnv），生产构建不包含注入逻辑。
+package chaos
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrInjected 注入的瞬时故障（调用方应按可重试错误处理）
+var ErrInjected = errors.New("chaos: injected transient failure")
+
+// Fault 故障类型（用于统计注入次数）
+type Fault string
+
+const (
+	FaultQueueDrop         Fault = "queue_drop"          // 消费到的消息被丢弃（保持未确认）
+	FaultQueueDuplicate    Fault = "queue_duplicate"     // 消费到的消息重复投递
+	FaultQueueDelay        Fault = "queue_delay"         // 消费延迟
+	FaultQueueAckError     Fault = "queue_ack_error"     // ACK 失败（消息保持未确认）
+	FaultQueuePublishError Fault = "queue_publish_error" // 发布失败
+	FaultDBError           Fault = "db_error"            // 存储层返回瞬时错误
+	FaultHTTPError         Fault = "http_error"          // HTTP 请求失败（连接错误或错误状态码）
+	FaultHTTPDelay         Fault = "http_delay"          // HTTP 请求延迟
+)
+
+// Config 故障注入配置，概率取值 0~1，延迟为上限（实际延迟在 0~上限间均匀分布）
+type Config struct {
+	Seed int64 // 随机种子，0 时使用当前时间（固定种子可复现注入序列）
+
+	QueueDrop         float64
+	QueueDuplicate    float64
+	QueueDelay        time.Duration
+	QueueAckError     float64
+	QueuePublishError float64
+
+	DBError   float64
+	DBMethods []string // 只对这些存储方法注入，为空时对所有可注入方法生效
+
+	HTTPError  float64
+	HTTPStatus int // 注入的错误响应状态码，0 表示返回连接错误
+	HTTPDelay  time.Duration
+}
+
+// Enabled 是否配置了任何故障
+func (c Config) Enabled() bool {
+	return c.QueueDrop > 0 || c.QueueDuplicate > 0 || c.QueueDelay > 0 || c.QueueAckError > 0 ||
+		c.QueuePublishError > 0 || c.DBError > 0 || c.HTTPError > 0 || c.HTTPDelay > 0
+}
+
+// ConfigFromEnv 从 CHAOS_* 环境变量读取配置
+//
+//	CHAOS_SEED                    随机种子
+//	CHAOS_QUEUE_DROP              消息丢弃概率
+//	CHAOS_QUEUE_DUPLICATE         消息重复概率
+//	CHAOS_QUEUE_DELAY             消费延迟上限（如 500ms）
+//	CHAOS_QUEUE_ACK_ERROR         ACK 失败概率
+//	CHAOS_QUEUE_PUBLISH_ERROR     发布失败概率
+//	CHAOS_DB_ERROR                存储层错误概率
+//	CHAOS_DB_METHODS              只对这些存储方法注入（逗号分隔，如 GetRun,UpdateRunStatus）
+//	CHAOS_HTTP_ERROR              HTTP 请求失败概率
+//	CHAOS_HTTP_STATUS             HTTP 失败时返回的状态码（不设置时返回连接错误）
+//	CHAOS_HTTP_DELAY              HTTP 请求延迟上限
+func ConfigFromEnv() (Config, error) {
+	var (
+		c    Config
+		errs []error
+	)
+	rate := func(key string) float64 {
+		v := os.Getenv(key)
+		if v == "" {
+			return 0
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil || f < 0 || f > 1 {
+			errs = append(errs, fmt.Errorf("%s=%q: want a probability between 0 and 1", key, v))
+		}
+		return f
+	}
+	duration := func(key string) time.Duration {
+		v := os.Getenv(key)
+		if v == "" {
+			return 0
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s=%q: %w", key, v, err))
+		}
+		return d
+	}
+	integer := func(key string) int64 {
+		v := os.Getenv(key)
+		if v == "" {
+			return 0
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s=%q: %w", key, v, err))
+		}
+		return n
+	}
+
+	c.Seed = integer("CHAOS_SEED")
+	c.QueueDrop = rate("CHAOS_QUEUE_DROP")
+	c.QueueDuplicate = rate("CHAOS_QUEUE_DUPLICATE")
+	c.QueueDelay = duration("CHAOS_QUEUE_DELAY")
+	c.QueueAckError = rate("CHAOS_QUEUE_ACK_ERROR")
+	c.QueuePublishError = rate("CHAOS_QUEUE_PUBLISH_ERROR")
+	c.DBError = rate("CHAOS_DB_ERROR")
+	if v := os.Getenv("CHAOS_DB_METHODS"); v != "" {
+		for _, m := range strings.Split(v, ",") {
+			if m = strings.TrimSpace(m); m != "" {
+				c.DBMethods = append(c.DBMethods, m)
+			}
+		}
+	}
+	c.HTTPError = rate("CHAOS_HTTP_ERROR")
+	c.HTTPStatus = int(integer("CHAOS_HTTP_STATUS"))
+	c.HTTPDelay = duration("CHAOS_HTTP_DELAY")
+	return c, errors.Join(errs...)
+}
+
+// Injector 按配置掷骰决定是否注入故障，并统计各类故障的注入次数
+//
+// 配置可在运行中通过 Set 调整（测试在不同阶段开关故障）。
+type Injector struct {
+	mu     sync.Mutex
+	cfg    Config
+	rnd    *rand.Rand
+	counts map[Fault]int64
+}
+
+// New 创建注入器
+func New(cfg Config) *Injector {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &Injector{cfg: cfg, rnd: rand.New(rand.NewSource(seed)), counts: make(map[Fault]int64)}
+}
+
+// Config 当前配置
+func (i *Injector) Config() Config {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.cfg
+}
+
+// Set 替换配置（不重置随机序列与统计）
+func (i *Injector) Set(cfg Config) {
+	i.mu.Lock()
+	i.cfg = cfg
+	i.mu.Unlock()
+}
+
+// Count 某类故障的累计注入次数
+func (i *Injector) Count(f Fault) int64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.counts[f]
+}
+
+// roll 按 pick 选出的概率掷骰，命中时计入 f
+func (i *Injector) roll(f Fault, pick func(Config) float64) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	p := pick(i.cfg)
+	if p <= 0 || i.rnd.Float64() >= p {
+		return false
+	}
+	i.counts[f]++
+	return true
+}
+
+// delay 按 pick 选出的上限随机延迟，上限为 0 时返回 0
+func (i *Injector) delay(f Fault, pick func(Config) time.Duration) time.Duration {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	limit := pick(i.cfg)
+	if limit <= 0 {
+		return 0
+	}
+	i.counts[f]++
+	return time.Duration(i.rnd.Int63n(int64(limit) + 1))
+}
+
+// dbError 存储方法 method 是否注入错误
+func (i *Injector) dbError(method string) error {
+	hit := i.roll(FaultDBError, func(c Config) float64 {
+		if len(c.DBMethods) > 0 && !containsString(c.DBMethods, method) {
+			return 0
+		}
+		return c.DBError
+	})
+	if !hit {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrInjected, method)
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
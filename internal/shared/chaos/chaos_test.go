@@ -0,0 +1,193 @@
+package chaos
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+	"agents-admin/internal/shared/storage"
+)
+
+// fakeQueue 返回固定调度消息并记录 ACK 与发布
+type fakeQueue struct {
+	queue.NoOpQueue
+	msgs      []*queue.SchedulerMessage
+	acked     []string
+	published []string
+}
+
+func (q *fakeQueue) ConsumeSchedulerRuns(ctx context.Context, consumerID string, count int64, blockTimeout time.Duration) ([]*queue.SchedulerMessage, error) {
+	return q.msgs, nil
+}
+
+func (q *fakeQueue) AckSchedulerRun(ctx context.Context, messageID string) error {
+	q.acked = append(q.acked, messageID)
+	return nil
+}
+
+func (q *fakeQueue) PublishRunToNode(ctx context.Context, nodeID, runID, taskID string) (string, error) {
+	q.published = append(q.published, runID)
+	return "msg-" + runID, nil
+}
+
+func schedulerMessages(n int) []*queue.SchedulerMessage {
+	msgs := make([]*queue.SchedulerMessage, n)
+	for i := range msgs {
+		msgs[i] = &queue.SchedulerMessage{ID: string(rune('a' + i)), RunID: "run-" + string(rune('a'+i))}
+	}
+	return msgs
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("CHAOS_SEED", "42")
+	t.Setenv("CHAOS_QUEUE_DROP", "0.25")
+	t.Setenv("CHAOS_QUEUE_DELAY", "200ms")
+	t.Setenv("CHAOS_DB_ERROR", "1")
+	t.Setenv("CHAOS_DB_METHODS", "GetRun, UpdateRunStatus,")
+	t.Setenv("CHAOS_HTTP_STATUS", "503")
+
+	cfg, err := ConfigFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, int64(42), cfg.Seed)
+	assert.Equal(t, 0.25, cfg.QueueDrop)
+	assert.Equal(t, 200*time.Millisecond, cfg.QueueDelay)
+	assert.Equal(t, 1.0, cfg.DBError)
+	assert.Equal(t, []string{"GetRun", "UpdateRunStatus"}, cfg.DBMethods)
+	assert.Equal(t, 503, cfg.HTTPStatus)
+	assert.True(t, cfg.Enabled())
+
+	t.Setenv("CHAOS_QUEUE_DROP", "1.5")
+	t.Setenv("CHAOS_HTTP_DELAY", "soon")
+	_, err = ConfigFromEnv()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CHAOS_QUEUE_DROP")
+	assert.Contains(t, err.Error(), "CHAOS_HTTP_DELAY")
+
+	assert.False(t, Config{}.Enabled())
+}
+
+func TestWrapQueue_DropLeavesMessageUnacked(t *testing.T) {
+	inner := &fakeQueue{msgs: schedulerMessages(3)}
+	inj := New(Config{Seed: 1, QueueDrop: 1})
+	q := WrapQueue(inner, inj)
+
+	msgs, err := q.ConsumeSchedulerRuns(context.Background(), "c", 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, msgs)
+	assert.Empty(t, inner.acked)
+	assert.Equal(t, int64(3), inj.Count(FaultQueueDrop))
+}
+
+func TestWrapQueue_Duplicate(t *testing.T) {
+	inner := &fakeQueue{msgs: schedulerMessages(2)}
+	q := WrapQueue(inner, New(Config{Seed: 1, QueueDuplicate: 1}))
+
+	msgs, err := q.ConsumeSchedulerRuns(context.Background(), "c", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 4)
+	assert.Equal(t, []string{"a", "a", "b", "b"}, []string{msgs[0].ID, msgs[1].ID, msgs[2].ID, msgs[3].ID})
+}
+
+func TestWrapQueue_AckAndPublishErrors(t *testing.T) {
+	inner := &fakeQueue{}
+	inj := New(Config{Seed: 1, QueueAckError: 1, QueuePublishError: 1})
+	q := WrapQueue(inner, inj)
+	ctx := context.Background()
+
+	err := q.AckSchedulerRun(ctx, "a")
+	assert.ErrorIs(t, err, ErrInjected)
+	assert.Empty(t, inner.acked)
+
+	ids, err := q.(queue.BatchNodeRunQueue).PublishRunsToNodes(ctx, []queue.NodeRunAssignment{{NodeID: "n", RunID: "r1"}, {NodeID: "n", RunID: "r2"}})
+	assert.ErrorIs(t, err, ErrInjected)
+	assert.Equal(t, []string{"", ""}, ids)
+	assert.Empty(t, inner.published)
+
+	// 关闭故障后恢复透传
+	inj.Set(Config{})
+	require.NoError(t, q.AckSchedulerRun(ctx, "a"))
+	ids, err = q.(queue.BatchNodeRunQueue).PublishRunsToNodes(ctx, []queue.NodeRunAssignment{{NodeID: "n", RunID: "r1"}})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"msg-r1"}, ids)
+	assert.Equal(t, []string{"a"}, inner.acked)
+}
+
+func TestWrapQueue_Delay(t *testing.T) {
+	q := WrapQueue(&fakeQueue{}, New(Config{Seed: 1, QueueDelay: time.Hour}))
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := q.ConsumeSchedulerRuns(ctx, "c", 1, 0)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// fakeStore 只实现 GetRun 与 CountEventsByRun，其余方法由嵌入的 nil 接口兜底（测试不会调用）
+type fakeStore struct {
+	storage.PersistentStore
+	calls int
+}
+
+func (s *fakeStore) GetRun(ctx context.Context, id string) (*model.Run, error) {
+	s.calls++
+	return &model.Run{ID: id}, nil
+}
+
+func (s *fakeStore) CountEventsByRun(ctx context.Context, runID string) (int, error) {
+	s.calls++
+	return 7, nil
+}
+
+func TestWrapStore_MethodFilter(t *testing.T) {
+	inner := &fakeStore{}
+	store := WrapStore(inner, New(Config{Seed: 1, DBError: 1, DBMethods: []string{"GetRun"}}))
+	ctx := context.Background()
+
+	_, err := store.GetRun(ctx, "r1")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInjected))
+	assert.Contains(t, err.Error(), "GetRun")
+	assert.Zero(t, inner.calls, "injected errors must not reach the underlying store")
+
+	n, err := store.CountEventsByRun(ctx, "r1")
+	require.NoError(t, err)
+	assert.Equal(t, 7, n)
+}
+
+func TestTransport(t *testing.T) {
+	var hits int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	inj := New(Config{Seed: 1, HTTPError: 1})
+	client := WrapClient(&http.Client{Timeout: time.Second}, inj)
+	assert.Equal(t, time.Second, client.Timeout)
+
+	_, err := client.Post(srv.URL+"/api/v1/runs/r1/events", "application/json", strings.NewReader("{}"))
+	assert.ErrorIs(t, err, ErrInjected)
+
+	inj.Set(Config{HTTPError: 1, HTTPStatus: http.StatusServiceUnavailable})
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	assert.Zero(t, hits)
+	assert.Equal(t, int64(2), inj.Count(FaultHTTPError))
+
+	inj.Set(Config{})
+	resp, err = client.Get(srv.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNoContent, resp.StatusCode)
+	assert.Equal(t, 1, hits)
+}
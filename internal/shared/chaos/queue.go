@@ -0,0 +1,156 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"agents-admin/internal/shared/queue"
+)
+
+// faultyQueue 在消息队列上注入故障
+//
+//   - 消费：延迟返回；丢弃的消息不返回给调用方也不确认，留在 pending 中（模拟消费者崩溃）；
+//     重复的消息在同一批中返回两次（模拟重投递）
+//   - ACK：返回错误且不确认
+//   - 发布：返回错误且不写入
+type faultyQueue struct {
+	queue.Queue
+	inj *Injector
+}
+
+// WrapQueue 返回注入故障的队列
+//
+// 同时实现 PrioritySchedulerQueue 与 BatchNodeRunQueue：内层不支持优先级时退化为 ScheduleRun，
+// 批量分派逐条发布，以便对每条分派独立注入失败。
+func WrapQueue(q queue.Queue, inj *Injector) queue.Queue {
+	return &faultyQueue{Queue: q, inj: inj}
+}
+
+func (q *faultyQueue) publishError(op string) error {
+	if q.inj.roll(FaultQueuePublishError, func(c Config) float64 { return c.QueuePublishError }) {
+		return fmt.Errorf("%w: %s", ErrInjected, op)
+	}
+	return nil
+}
+
+func (q *faultyQueue) ackError(op string) error {
+	if q.inj.roll(FaultQueueAckError, func(c Config) float64 { return c.QueueAckError }) {
+		return fmt.Errorf("%w: %s", ErrInjected, op)
+	}
+	return nil
+}
+
+// consumeDelay 消费前的随机延迟，ctx 取消时提前返回
+func (q *faultyQueue) consumeDelay(ctx context.Context) error {
+	d := q.inj.delay(FaultQueueDelay, func(c Config) time.Duration { return c.QueueDelay })
+	if d == 0 {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(d):
+		return nil
+	}
+}
+
+// mangle 对一批消息逐条决定丢弃或重复
+func mangle[T any](inj *Injector, msgs []T) []T {
+	if len(msgs) == 0 {
+		return msgs
+	}
+	out := make([]T, 0, len(msgs))
+	for _, m := range msgs {
+		if inj.roll(FaultQueueDrop, func(c Config) float64 { return c.QueueDrop }) {
+			continue
+		}
+		out = append(out, m)
+		if inj.roll(FaultQueueDuplicate, func(c Config) float64 { return c.QueueDuplicate }) {
+			out = append(out, m)
+		}
+	}
+	return out
+}
+
+func (q *faultyQueue) ScheduleRun(ctx context.Context, runID, taskID string) (string, error) {
+	if err := q.publishError("ScheduleRun"); err != nil {
+		return "", err
+	}
+	return q.Queue.ScheduleRun(ctx, runID, taskID)
+}
+
+func (q *faultyQueue) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
+	if err := q.publishError("ScheduleRunWithPriority"); err != nil {
+		return "", err
+	}
+	if pq, ok := q.Queue.(queue.PrioritySchedulerQueue); ok {
+		return pq.ScheduleRunWithPriority(ctx, runID, taskID, priority)
+	}
+	return q.Queue.ScheduleRun(ctx, runID, taskID)
+}
+
+func (q *faultyQueue) ConsumeSchedulerRuns(ctx context.Context, consumerID string, count int64, blockTimeout time.Duration) ([]*queue.SchedulerMessage, error) {
+	if err := q.consumeDelay(ctx); err != nil {
+		return nil, err
+	}
+	msgs, err := q.Queue.ConsumeSchedulerRuns(ctx, consumerID, count, blockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return mangle(q.inj, msgs), nil
+}
+
+func (q *faultyQueue) AckSchedulerRun(ctx context.Context, messageID string) error {
+	if err := q.ackError("AckSchedulerRun"); err != nil {
+		return err
+	}
+	return q.Queue.AckSchedulerRun(ctx, messageID)
+}
+
+func (q *faultyQueue) PublishRunToNode(ctx context.Context, nodeID, runID, taskID string) (string, error) {
+	if err := q.publishError("PublishRunToNode"); err != nil {
+		return "", err
+	}
+	return q.Queue.PublishRunToNode(ctx, nodeID, runID, taskID)
+}
+
+func (q *faultyQueue) PublishRunsToNodes(ctx context.Context, runs []queue.NodeRunAssignment) ([]string, error) {
+	ids := make([]string, len(runs))
+	var firstErr error
+	for i, r := range runs {
+		id, err := q.PublishRunToNode(ctx, r.NodeID, r.RunID, r.TaskID)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		ids[i] = id
+	}
+	return ids, firstErr
+}
+
+func (q *faultyQueue) ConsumeNodeRuns(ctx context.Context, nodeID, consumerID string, count int64, blockTimeout time.Duration) ([]*queue.NodeRunMessage, error) {
+	if err := q.consumeDelay(ctx); err != nil {
+		return nil, err
+	}
+	msgs, err := q.Queue.ConsumeNodeRuns(ctx, nodeID, consumerID, count, blockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return mangle(q.inj, msgs), nil
+}
+
+func (q *faultyQueue) AckNodeRun(ctx context.Context, nodeID, messageID string) error {
+	if err := q.ackError("AckNodeRun"); err != nil {
+		return err
+	}
+	return q.Queue.AckNodeRun(ctx, nodeID, messageID)
+}
+
+var (
+	_ queue.Queue                  = (*faultyQueue)(nil)
+	_ queue.PrioritySchedulerQueue = (*faultyQueue)(nil)
+	_ queue.BatchNodeRunQueue      = (*faultyQueue)(nil)
+)
@@ -0,0 +1,125 @@
+package chaos
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+// faultyStore 在调度与执行链路用到的存储方法上返回瞬时错误
+//
+// 只覆盖调度器、节点心跳与事件上报依赖的方法；其余方法直接透传。
+// 错误在调用底层存储之前返回，不会产生部分写入。
+type faultyStore struct {
+	storage.PersistentStore
+	inj *Injector
+}
+
+// WrapStore 返回注入数据库错误的存储层（Config.DBMethods 可限定注入的方法）
+func WrapStore(store storage.PersistentStore, inj *Injector) storage.PersistentStore {
+	return &faultyStore{PersistentStore: store, inj: inj}
+}
+
+func (s *faultyStore) GetTask(ctx context.Context, id string) (*model.Task, error) {
+	if err := s.inj.dbError("GetTask"); err != nil {
+		return nil, err
+	}
+	return s.PersistentStore.GetTask(ctx, id)
+}
+
+func (s *faultyStore) CreateRun(ctx context.Context, run *model.Run) error {
+	if err := s.inj.dbError("CreateRun"); err != nil {
+		return err
+	}
+	return s.PersistentStore.CreateRun(ctx, run)
+}
+
+func (s *faultyStore) GetRun(ctx context.Context, id string) (*model.Run, error) {
+	if err := s.inj.dbError("GetRun"); err != nil {
+		return nil, err
+	}
+	return s.PersistentStore.GetRun(ctx, id)
+}
+
+func (s *faultyStore) ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error) {
+	if err := s.inj.dbError("ListRunsByNode"); err != nil {
+		return nil, err
+	}
+	return s.PersistentStore.ListRunsByNode(ctx, nodeID)
+}
+
+func (s *faultyStore) ListStaleQueuedRuns(ctx context.Context, threshold time.Duration) ([]*model.Run, error) {
+	if err := s.inj.dbError("ListStaleQueuedRuns"); err != nil {
+		return nil, err
+	}
+	return s.PersistentStore.ListStaleQueuedRuns(ctx, threshold)
+}
+
+func (s *faultyStore) ResetRunToQueued(ctx context.Context, id string) error {
+	if err := s.inj.dbError("ResetRunToQueued"); err != nil {
+		return err
+	}
+	return s.PersistentStore.ResetRunToQueued(ctx, id)
+}
+
+func (s *faultyStore) UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error {
+	if err := s.inj.dbError("UpdateRunStatus"); err != nil {
+		return err
+	}
+	return s.PersistentStore.UpdateRunStatus(ctx, id, status, nodeID)
+}
+
+func (s *faultyStore) CreateEvents(ctx context.Context, events []*model.Event) error {
+	if err := s.inj.dbError("CreateEvents"); err != nil {
+		return err
+	}
+	return s.PersistentStore.CreateEvents(ctx, events)
+}
+
+func (s *faultyStore) CountEventsByRun(ctx context.Context, runID string) (int, error) {
+	if err := s.inj.dbError("CountEventsByRun"); err != nil {
+		return 0, err
+	}
+	return s.PersistentStore.CountEventsByRun(ctx, runID)
+}
+
+func (s *faultyStore) UpsertNode(ctx context.Context, node *model.Node) error {
+	if err := s.inj.dbError("UpsertNode"); err != nil {
+		return err
+	}
+	return s.PersistentStore.UpsertNode(ctx, node)
+}
+
+// DatabaseSize 透传底层存储的空间统计（管理后台总览通过类型断言调用）
+func (s *faultyStore) DatabaseSize(ctx context.Context) (int64, error) {
+	if sizer, ok := s.PersistentStore.(interface {
+		DatabaseSize(ctx context.Context) (int64, error)
+	}); ok {
+		return sizer.DatabaseSize(ctx)
+	}
+	return 0, errors.ErrUnsupported
+}
+
+// ReplicaStatus 透传底层存储的只读副本状态（管理后台总览通过类型断言调用）
+func (s *faultyStore) ReplicaStatus() *storage.ReplicaStatus {
+	if r, ok := s.PersistentStore.(interface {
+		ReplicaStatus() *storage.ReplicaStatus
+	}); ok {
+		return r.ReplicaStatus()
+	}
+	return nil
+}
+
+// PoolStats 透传底层存储的连接池统计（指标导出通过类型断言调用）
+func (s *faultyStore) PoolStats() map[string]sql.DBStats {
+	if p, ok := s.PersistentStore.(interface {
+		PoolStats() map[string]sql.DBStats
+	}); ok {
+		return p.PoolStats()
+	}
+	return nil
+}
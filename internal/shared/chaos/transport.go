@@ -0,0 +1,69 @@
+package chaos
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Transport 注入延迟与请求失败的 http.RoundTripper（NodeManager 访问 API Server 用）
+//
+// 注入失败时请求不会发出：HTTPStatus 为 0 时返回连接错误，否则返回该状态码的合成响应。
+type Transport struct {
+	Base     http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+	Injector *Injector
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if d := t.Injector.delay(FaultHTTPDelay, func(c Config) time.Duration { return c.HTTPDelay }); d > 0 {
+		select {
+		case <-req.Context().Done():
+			closeBody(req)
+			return nil, req.Context().Err()
+		case <-time.After(d):
+		}
+	}
+	if t.Injector.roll(FaultHTTPError, func(c Config) float64 { return c.HTTPError }) {
+		closeBody(req)
+		status := t.Injector.Config().HTTPStatus
+		if status == 0 {
+			return nil, fmt.Errorf("%w: %s %s", ErrInjected, req.Method, req.URL.Path)
+		}
+		body := fmt.Sprintf(`{"error":"chaos: injected %d"}`, status)
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+			StatusCode:    status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        http.Header{"Content-Type": []string{"application/json"}},
+			Body:          io.NopCloser(strings.NewReader(body)),
+			ContentLength: int64(len(body)),
+			Request:       req,
+		}, nil
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
+
+// WrapClient 返回使用注入 Transport 的 HTTP 客户端副本（client 为 nil 时基于零值客户端）
+func WrapClient(client *http.Client, inj *Injector) *http.Client {
+	c := &http.Client{}
+	if client != nil {
+		*c = *client
+	}
+	c.Transport = &Transport{Base: c.Transport, Injector: inj}
+	return c
+}
+
+func closeBody(req *http.Request) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+}
@@ -13,6 +13,7 @@ import (
 	cacheredis "agents-admin/internal/shared/cache/redis"
 	"agents-admin/internal/shared/eventbus"
 	eventbusredis "agents-admin/internal/shared/eventbus/redis"
+	"agents-admin/internal/shared/model"
 	"agents-admin/internal/shared/queue"
 	queueredis "agents-admin/internal/shared/queue/redis"
 	"agents-admin/internal/shared/storage"
@@ -25,7 +26,7 @@ type RedisInfra struct {
 	// 组件（显式命名避免冲突）
 	cacheStore    *cacheredis.Store
 	eventBusStore *eventbusredis.Store
-	queueStore    *queueredis.Store
+	queueStore    queue.Queue // 默认为同一 Redis 连接上的 Streams，可通过 SetQueue 替换
 
 	// 底层连接
 	client *redis.Client
@@ -97,11 +98,23 @@ func (r *RedisInfra) Queue() queue.Queue {
 	return r.queueStore
 }
 
+// SetQueue 替换消息队列后端（如 NATS JetStream），缓存与事件总线仍使用 Redis
+//
+// 替换后的队列由调用方负责关闭。
+func (r *RedisInfra) SetQueue(q queue.Queue) {
+	r.queueStore = q
+}
+
 // Client 返回底层 Redis 客户端
 func (r *RedisInfra) Client() *redis.Client {
 	return r.client
 }
 
+// Ping 检查 Redis 连接
+func (r *RedisInfra) Ping(ctx context.Context) error {
+	return r.client.Ping(ctx).Err()
+}
+
 // Close 关闭 Redis 连接
 func (r *RedisInfra) Close() error {
 	return r.client.Close()
@@ -153,6 +166,42 @@ func (r *RedisInfra) DeleteNodeHeartbeat(ctx context.Context, nodeID string) err
 func (r *RedisInfra) ListOnlineNodes(ctx context.Context) ([]string, error) {
 	return r.cacheStore.ListOnlineNodes(ctx)
 }
+func (r *RedisInfra) AcquireAccount(ctx context.Context, accountID, runID string, maxConcurrent, dailyLimit int) (bool, error) {
+	return r.cacheStore.AcquireAccount(ctx, accountID, runID, maxConcurrent, dailyLimit)
+}
+func (r *RedisInfra) ReleaseAccount(ctx context.Context, runID string) (string, error) {
+	return r.cacheStore.ReleaseAccount(ctx, runID)
+}
+func (r *RedisInfra) GetAccountLease(ctx context.Context, runID string) (string, error) {
+	return r.cacheStore.GetAccountLease(ctx, runID)
+}
+func (r *RedisInfra) MarkAccountExhausted(ctx context.Context, accountID string, until time.Time) error {
+	return r.cacheStore.MarkAccountExhausted(ctx, accountID, until)
+}
+func (r *RedisInfra) GetAccountUsage(ctx context.Context, accountID string) (*cache.AccountUsage, error) {
+	return r.cacheStore.GetAccountUsage(ctx, accountID)
+}
+func (r *RedisInfra) IncrAccountFailovers(ctx context.Context, runID string) (int64, error) {
+	return r.cacheStore.IncrAccountFailovers(ctx, runID)
+}
+func (r *RedisInfra) AppendNodeMetrics(ctx context.Context, nodeID string, sample *model.NodeMetrics, window time.Duration) error {
+	return r.cacheStore.AppendNodeMetrics(ctx, nodeID, sample, window)
+}
+func (r *RedisInfra) ListNodeMetrics(ctx context.Context, nodeID string, since time.Time) ([]*model.NodeMetrics, error) {
+	return r.cacheStore.ListNodeMetrics(ctx, nodeID, since)
+}
+func (r *RedisInfra) TakeRateToken(ctx context.Context, key string, rate float64, burst int) (bool, time.Duration, error) {
+	return r.cacheStore.TakeRateToken(ctx, key, rate, burst)
+}
+func (r *RedisInfra) RecordAuthFailure(ctx context.Context, key string, maxFailures int, window, lockout time.Duration) (time.Duration, error) {
+	return r.cacheStore.RecordAuthFailure(ctx, key, maxFailures, window, lockout)
+}
+func (r *RedisInfra) AuthLockout(ctx context.Context, key string) (time.Duration, error) {
+	return r.cacheStore.AuthLockout(ctx, key)
+}
+func (r *RedisInfra) ResetAuthFailures(ctx context.Context, key string) error {
+	return r.cacheStore.ResetAuthFailures(ctx, key)
+}
 
 // ============================================================================
 // eventbus.EventBus 接口委托实现
@@ -196,6 +245,12 @@ func (r *RedisInfra) DeleteRunEvents(ctx context.Context, runID string) error {
 func (r *RedisInfra) ScheduleRun(ctx context.Context, runID, taskID string) (string, error) {
 	return r.queueStore.ScheduleRun(ctx, runID, taskID)
 }
+func (r *RedisInfra) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
+	if pq, ok := r.queueStore.(queue.PrioritySchedulerQueue); ok {
+		return pq.ScheduleRunWithPriority(ctx, runID, taskID, priority)
+	}
+	return r.queueStore.ScheduleRun(ctx, runID, taskID)
+}
 func (r *RedisInfra) CreateSchedulerConsumerGroup(ctx context.Context) error {
 	return r.queueStore.CreateSchedulerConsumerGroup(ctx)
 }
@@ -214,6 +269,21 @@ func (r *RedisInfra) GetSchedulerPendingCount(ctx context.Context) (int64, error
 func (r *RedisInfra) PublishRunToNode(ctx context.Context, nodeID, runID, taskID string) (string, error) {
 	return r.queueStore.PublishRunToNode(ctx, nodeID, runID, taskID)
 }
+func (r *RedisInfra) PublishRunsToNodes(ctx context.Context, runs []queue.NodeRunAssignment) ([]string, error) {
+	if bq, ok := r.queueStore.(queue.BatchNodeRunQueue); ok {
+		return bq.PublishRunsToNodes(ctx, runs)
+	}
+	ids := make([]string, len(runs))
+	var firstErr error
+	for i, run := range runs {
+		id, err := r.queueStore.PublishRunToNode(ctx, run.NodeID, run.RunID, run.TaskID)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		ids[i] = id
+	}
+	return ids, firstErr
+}
 func (r *RedisInfra) CreateNodeConsumerGroup(ctx context.Context, nodeID string) error {
 	return r.queueStore.CreateNodeConsumerGroup(ctx, nodeID)
 }
@@ -0,0 +1,153 @@
+// Package loadtest 压测指标统计与回归阈值
+//
+// cmd/loadgen（对运行中的 API Server 施压）与 tests/integration/35-load（进程内调度器 + 真实存储）共用：
+// 采集调度延迟、事件入库吞吐与调度队列未确认消息数，汇总为 Report，按 Thresholds 判定是否回归。
+package loadtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Latencies 并发安全的延迟采样
+type Latencies struct {
+	mu      sync.Mutex
+	samples []time.Duration
+}
+
+// Add 记录一个样本
+func (l *Latencies) Add(d time.Duration) {
+	l.mu.Lock()
+	l.samples = append(l.samples, d)
+	l.mu.Unlock()
+}
+
+// Count 样本数
+func (l *Latencies) Count() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.samples)
+}
+
+// Percentile 第 p 百分位（0 < p <= 100，最近秩法），没有样本时返回 0
+func (l *Latencies) Percentile(p float64) time.Duration {
+	l.mu.Lock()
+	sorted := append([]time.Duration(nil), l.samples...)
+	l.mu.Unlock()
+	if len(sorted) == 0 {
+		return 0
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	return sorted[min(max(rank, 1), len(sorted))-1]
+}
+
+// Gauge 周期采样的数值（如队列未确认消息数），记录首个值与最大值
+type Gauge struct {
+	mu      sync.Mutex
+	first   int64
+	maximum int64
+	sampled bool
+}
+
+// Observe 记录一次采样
+func (g *Gauge) Observe(v int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.sampled {
+		g.first, g.maximum, g.sampled = v, v, true
+		return
+	}
+	g.maximum = max(g.maximum, v)
+}
+
+// Growth 最大值相对首个采样的增长，未采样时 ok 为 false
+func (g *Gauge) Growth() (growth int64, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.maximum - g.first, g.sampled
+}
+
+// Report 一次压测的汇总（JSON 中的时长为纳秒）
+type Report struct {
+	Runs          int           `json:"runs"`           // 创建的 Run 数
+	Scheduled     int           `json:"scheduled"`      // 被调度到节点的 Run 数
+	Completed     int           `json:"completed"`      // 执行完成的 Run 数（仅 loadgen）
+	Errors        int           `json:"errors"`         // 请求或存储错误数
+	ScheduleP50   time.Duration `json:"schedule_p50"`   // 调度延迟中位数（创建 → assigned）
+	ScheduleP99   time.Duration `json:"schedule_p99"`   // 调度延迟 p99
+	Events        int           `json:"events"`         // 入库事件数
+	EventsPerSec  float64       `json:"events_per_sec"` // 事件入库吞吐
+	PendingGrowth int64         `json:"pending_growth"` // 调度队列未确认消息数的增长，-1 表示未采样
+	Duration      time.Duration `json:"duration"`       // 压测总耗时
+}
+
+// NewReport 从采样汇总报告；eventsElapsed 为事件上报阶段的耗时
+func NewReport(schedule *Latencies, events int, eventsElapsed time.Duration, pending *Gauge) Report {
+	r := Report{
+		Scheduled:     schedule.Count(),
+		ScheduleP50:   schedule.Percentile(50),
+		ScheduleP99:   schedule.Percentile(99),
+		Events:        events,
+		PendingGrowth: -1,
+	}
+	if eventsElapsed > 0 {
+		r.EventsPerSec = float64(events) / eventsElapsed.Seconds()
+	}
+	if growth, ok := pending.Growth(); ok {
+		r.PendingGrowth = growth
+	}
+	return r
+}
+
+// String 单行摘要
+func (r Report) String() string {
+	pending := "n/a"
+	if r.PendingGrowth >= 0 {
+		pending = fmt.Sprint(r.PendingGrowth)
+	}
+	return fmt.Sprintf("runs=%d scheduled=%d completed=%d errors=%d schedule_p50=%s schedule_p99=%s events=%d events_per_sec=%.0f pending_growth=%s duration=%s",
+		r.Runs, r.Scheduled, r.Completed, r.Errors, r.ScheduleP50, r.ScheduleP99, r.Events, r.EventsPerSec, pending, r.Duration.Round(time.Millisecond))
+}
+
+// Thresholds 回归阈值，数值项为零表示不检查
+type Thresholds struct {
+	MaxScheduleP50   time.Duration
+	MaxScheduleP99   time.Duration
+	MinEventsPerSec  float64
+	MaxPendingGrowth int64
+	Strict           bool // 所有 Run 都必须被调度且没有错误
+}
+
+// Check 返回超出阈值的项，为空表示通过
+func (t Thresholds) Check(r Report) []string {
+	var violations []string
+	if t.Strict && r.Scheduled < r.Runs {
+		violations = append(violations, fmt.Sprintf("scheduled %d of %d runs", r.Scheduled, r.Runs))
+	}
+	if t.Strict && r.Errors > 0 {
+		violations = append(violations, fmt.Sprintf("%d errors", r.Errors))
+	}
+	if t.MaxScheduleP50 > 0 && r.ScheduleP50 > t.MaxScheduleP50 {
+		violations = append(violations, fmt.Sprintf("schedule p50 %s > %s", r.ScheduleP50, t.MaxScheduleP50))
+	}
+	if t.MaxScheduleP99 > 0 && r.ScheduleP99 > t.MaxScheduleP99 {
+		violations = append(violations, fmt.Sprintf("schedule p99 %s > %s", r.ScheduleP99, t.MaxScheduleP99))
+	}
+	if t.MinEventsPerSec > 0 && r.EventsPerSec < t.MinEventsPerSec {
+		violations = append(violations, fmt.Sprintf("event ingest %.0f/s < %.0f/s", r.EventsPerSec, t.MinEventsPerSec))
+	}
+	if t.MaxPendingGrowth > 0 && r.PendingGrowth > t.MaxPendingGrowth {
+		violations = append(violations, fmt.Sprintf("queue pending growth %d > %d", r.PendingGrowth, t.MaxPendingGrowth))
+	}
+	return violations
+}
+
+// Summary 将超限项拼接为一行
+func Summary(violations []string) string {
+	return strings.Join(violations, "; ")
+}
@@ -0,0 +1,75 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatencies_Percentile(t *testing.T) {
+	var l Latencies
+	assert.Zero(t, l.Percentile(99))
+
+	for i := 100; i >= 1; i-- {
+		l.Add(time.Duration(i) * time.Millisecond)
+	}
+	assert.Equal(t, 100, l.Count())
+	assert.Equal(t, 50*time.Millisecond, l.Percentile(50))
+	assert.Equal(t, 99*time.Millisecond, l.Percentile(99))
+	assert.Equal(t, 100*time.Millisecond, l.Percentile(100))
+	assert.Equal(t, time.Millisecond, l.Percentile(0))
+}
+
+func TestGauge_Growth(t *testing.T) {
+	var g Gauge
+	_, ok := g.Growth()
+	assert.False(t, ok)
+
+	for _, v := range []int64{5, 3, 12, 7} {
+		g.Observe(v)
+	}
+	growth, ok := g.Growth()
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), growth)
+}
+
+func TestNewReport(t *testing.T) {
+	var l Latencies
+	l.Add(10 * time.Millisecond)
+	r := NewReport(&l, 500, 2*time.Second, &Gauge{})
+	assert.Equal(t, 1, r.Scheduled)
+	assert.Equal(t, 250.0, r.EventsPerSec)
+	assert.Equal(t, int64(-1), r.PendingGrowth)
+	assert.Contains(t, r.String(), "pending_growth=n/a")
+}
+
+func TestThresholds_Check(t *testing.T) {
+	r := Report{
+		Runs:          100,
+		Scheduled:     98,
+		Errors:        1,
+		ScheduleP50:   20 * time.Millisecond,
+		ScheduleP99:   2 * time.Second,
+		EventsPerSec:  800,
+		PendingGrowth: 40,
+	}
+
+	assert.Empty(t, Thresholds{}.Check(r))
+
+	violations := Thresholds{
+		MaxScheduleP50:   50 * time.Millisecond,
+		MaxScheduleP99:   time.Second,
+		MinEventsPerSec:  1000,
+		MaxPendingGrowth: 10,
+		Strict:           true,
+	}.Check(r)
+	assert.Equal(t, []string{
+		"scheduled 98 of 100 runs",
+		"1 errors",
+		"schedule p99 2s > 1s",
+		"event ingest 800/s < 1000/s",
+		"queue pending growth 40 > 10",
+	}, violations)
+	assert.Contains(t, Summary(violations), "; schedule p99")
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// RequestIDKey 请求 ID 上下文键（由 WithRequestID 设置）
+const RequestIDKey ContextKey = "request_id"
+
+// contextKeys 以上下文输出日志时自动附加的字段
+var contextKeys = []ContextKey{RequestIDKey, TraceIDKey, SpanIDKey, NodeIDKey, RunIDKey, TaskIDKey}
+
+type scopeKey struct{}
+
+// scope 请求范围内追加的关联字段（处理过程中才能确定，如认证后得到的节点 ID）
+type scope struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// WithRequestID 为请求上下文设置请求 ID，并开启可由 Annotate 追加字段的请求范围
+func WithRequestID(ctx context.Context, id string) context.Context {
+	ctx = context.WithValue(ctx, RequestIDKey, id)
+	return context.WithValue(ctx, scopeKey{}, &scope{})
+}
+
+// RequestID 返回上下文中的请求 ID，没有时为空
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// Annotate 为当前请求追加关联字段，之后以该请求上下文（含外层中间件）输出的日志都带上这些字段
+//
+// 上下文不在 WithRequestID 开启的请求范围内时不做处理；同名字段以最后一次为准。
+func Annotate(ctx context.Context, attrs ...slog.Attr) {
+	s, _ := ctx.Value(scopeKey{}).(*scope)
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, a := range attrs {
+		replaced := false
+		for i := range s.attrs {
+			if s.attrs[i].Key == a.Key {
+				s.attrs[i], replaced = a, true
+			}
+		}
+		if !replaced {
+			s.attrs = append(s.attrs, a)
+		}
+	}
+}
+
+// contextHandler 以上下文中的请求 ID、Run / 节点 / 任务 ID 与 Annotate 追加的字段补充日志记录
+//
+// 记录中已有的同名字段优先（如显式传入的 run_id）。
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx == nil {
+		return h.Handler.Handle(ctx, r)
+	}
+	present := make(map[string]bool, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		present[a.Key] = true
+		return true
+	})
+	add := func(a slog.Attr) {
+		if !present[a.Key] {
+			present[a.Key] = true
+			r.AddAttrs(a)
+		}
+	}
+	for _, key := range contextKeys {
+		if v, ok := ctx.Value(key).(string); ok && v != "" {
+			add(slog.String(string(key), v))
+		}
+	}
+	if s, _ := ctx.Value(scopeKey{}).(*scope); s != nil {
+		s.mu.Lock()
+		for _, a := range s.attrs {
+			add(a)
+		}
+		s.mu.Unlock()
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,109 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// legacyWriter 将标准库 log 的输出转为结构化记录
+//
+// 按仓库的日志约定 "[module.action] key=value ..." 解析：方括号内为消息，key=value 为字段，
+// 其余文本记为 detail；error / err 字段之后的内容整体作为错误信息（错误文本可能包含空格与等号）。
+// 不符合约定的行整行作为消息。级别按错误字段与关键字推断，见 legacyLevel。
+//
+// API Server 的请求处理路径直接使用 slog.*Context；这里只兼容尚未迁移的调用（Node Manager、启动与安装向导等），
+// 标准库 log 不传递上下文，这些记录不带请求 ID 等关联字段。
+type legacyWriter struct {
+	handler slog.Handler
+}
+
+func (w *legacyWriter) Write(p []byte) (int, error) {
+	lvl, msg, attrs := parseLegacy(string(p))
+	ctx := context.Background()
+	if !w.handler.Enabled(ctx, lvl) {
+		return len(p), nil
+	}
+	r := slog.NewRecord(time.Now(), lvl, msg, 0)
+	r.AddAttrs(attrs...)
+	return len(p), w.handler.Handle(ctx, r)
+}
+
+// legacyKey 匹配 " key=" 形式的字段起始位置
+var legacyKey = regexp.MustCompile(`(?:^|\s)([a-z][a-z0-9_]*)=`)
+
+// parseLegacy 解析一行标准库 log 输出
+func parseLegacy(line string) (slog.Level, string, []slog.Attr) {
+	line = strings.TrimRight(line, "\r\n")
+	tag, rest := "", line
+	if strings.HasPrefix(line, "[") {
+		if end := strings.Index(line, "]"); end > 1 && !strings.ContainsAny(line[1:end], " \t") {
+			tag, rest = line[1:end], strings.TrimSpace(line[end+1:])
+		}
+	}
+
+	var attrs []slog.Attr
+	detail := rest
+	matches := legacyKey.FindAllStringSubmatchIndex(rest, -1)
+	if len(matches) > 0 {
+		detail = strings.TrimSpace(rest[:matches[0][0]])
+	}
+	for i, m := range matches {
+		key := rest[m[2]:m[3]]
+		end := len(rest)
+		if i+1 < len(matches) && key != "error" && key != "err" {
+			end = matches[i+1][0]
+		}
+		attrs = append(attrs, slog.String(key, unquote(strings.TrimSpace(rest[m[1]:end]))))
+		if end == len(rest) {
+			break
+		}
+	}
+
+	msg := tag
+	switch {
+	case tag == "":
+		msg = detail
+	case detail != "":
+		attrs = append(attrs, slog.String("detail", detail))
+	}
+	if msg == "" {
+		msg = line
+	}
+	return legacyLevel(tag, detail, attrs), msg, attrs
+}
+
+// legacyLevel 推断级别：带非空错误字段或描述包含 error / panic / fatal 为 error，
+// 消息或描述包含 fail / warn 为 warn，其余为 info
+func legacyLevel(tag, detail string, attrs []slog.Attr) slog.Level {
+	for _, a := range attrs {
+		if (a.Key == "error" || a.Key == "err" || strings.HasSuffix(a.Key, "_error")) && a.Value.String() != "<nil>" && a.Value.String() != "" {
+			return slog.LevelError
+		}
+	}
+	text := strings.ToLower(tag + " " + detail)
+	for _, kw := range []string{"error", "panic", "fatal"} {
+		if strings.Contains(text, kw) {
+			return slog.LevelError
+		}
+	}
+	for _, kw := range []string{"fail", "warn"} {
+		if strings.Contains(text, kw) {
+			return slog.LevelWarn
+		}
+	}
+	return slog.LevelInfo
+}
+
+// unquote 还原 %q 输出的字段值
+func unquote(v string) string {
+	if len(v) >= 2 && v[0] == '"' && v[len(v)-1] == '"' {
+		if s, err := strconv.Unquote(v); err == nil {
+			return s
+		}
+	}
+	return v
+}
@@ -0,0 +1,111 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+)
+
+func TestParseLegacy(t *testing.T) {
+	cases := []struct {
+		line  string
+		level slog.Level
+		msg   string
+		attrs map[string]string
+	}{
+		{
+			line:  "[run.watchdog.start] interval=30s default_timeout=2h0m0s\n",
+			level: slog.LevelInfo, msg: "run.watchdog.start",
+			attrs: map[string]string{"interval": "30s", "default_timeout": "2h0m0s"},
+		},
+		{
+			line:  `[run.abort] run_id=run-1 reason="blocked: secret leak"`,
+			level: slog.LevelInfo, msg: "run.abort",
+			attrs: map[string]string{"run_id": "run-1", "reason": "blocked: secret leak"},
+		},
+		{
+			// error 之后的内容整体作为错误信息
+			line:  "[run.reconcile.node.failed] node_id=node-1 error=dial tcp 10.0.0.1:6379: i/o timeout retry=3",
+			level: slog.LevelError, msg: "run.reconcile.node.failed",
+			attrs: map[string]string{"node_id": "node-1", "error": "dial tcp 10.0.0.1:6379: i/o timeout retry=3"},
+		},
+		{
+			line:  "[run.update.task_status] run_id=run-1 get_run_error=<nil>",
+			level: slog.LevelInfo, msg: "run.update.task_status",
+			attrs: map[string]string{"run_id": "run-1", "get_run_error": "<nil>"},
+		},
+		{
+			line:  "[maintenance] ListMaintenanceRuns error: connection refused",
+			level: slog.LevelError, msg: "maintenance",
+			attrs: map[string]string{"detail": "ListMaintenanceRuns error: connection refused"},
+		},
+		{
+			line:  "[scheduler.leader.failed] lost lease",
+			level: slog.LevelWarn, msg: "scheduler.leader.failed",
+			attrs: map[string]string{"detail": "lost lease"},
+		},
+		{
+			line:  "Rate limiting enabled: ip=20/s user=10/s",
+			level: slog.LevelInfo, msg: "Rate limiting enabled:",
+			attrs: map[string]string{"ip": "20/s", "user": "10/s"},
+		},
+		{
+			line:  "[API] Server starting on :8080",
+			level: slog.LevelInfo, msg: "API",
+			attrs: map[string]string{"detail": "Server starting on :8080"},
+		},
+	}
+	for _, c := range cases {
+		level, msg, attrs := parseLegacy(c.line)
+		got := make(map[string]string, len(attrs))
+		for _, a := range attrs {
+			got[a.Key] = a.Value.String()
+		}
+		if level != c.level || msg != c.msg || len(got) != len(c.attrs) {
+			t.Errorf("%q: got %s %q %v, want %s %q %v", c.line, level, msg, got, c.level, c.msg, c.attrs)
+			continue
+		}
+		for k, v := range c.attrs {
+			if got[k] != v {
+				t.Errorf("%q: %s = %q, want %q", c.line, k, got[k], v)
+			}
+		}
+	}
+}
+
+func TestContextHandler(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(&contextHandler{Handler: slog.NewJSONHandler(&buf, nil)})
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	Annotate(ctx, slog.String("node_id", "node-1"))
+	Annotate(ctx, slog.String("node_id", "node-2"))
+	logger.InfoContext(context.WithValue(ctx, RunIDKey, "run-1"), "run.create.start", "run_id", "run-explicit")
+
+	var rec map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+		t.Fatalf("decode %s: %v", buf.String(), err)
+	}
+	if rec["request_id"] != "req-1" || rec["node_id"] != "node-2" || rec["run_id"] != "run-explicit" {
+		t.Errorf("record = %v", rec)
+	}
+
+	// 不在请求范围内：Annotate 不做处理
+	Annotate(context.Background(), slog.String("node_id", "node-3"))
+	if RequestID(context.Background()) != "" {
+		t.Error("unexpected request id")
+	}
+}
+
+func TestParseLevel(t *testing.T) {
+	for in, want := range map[string]slog.Level{"": slog.LevelInfo, "DEBUG": slog.LevelDebug, "warning": slog.LevelWarn, "error": slog.LevelError} {
+		if got, err := ParseLevel(in); err != nil || got != want {
+			t.Errorf("ParseLevel(%q) = %s, %v", in, got, err)
+		}
+	}
+	if _, err := ParseLevel("verbose"); err == nil {
+		t.Error("expected error for invalid level")
+	}
+}
@@ -4,7 +4,6 @@ package logging
 import (
 	"context"
 	"encoding/json"
-	"io"
 	"log/slog"
 	"os"
 	"runtime"
@@ -38,38 +37,12 @@ type Config struct {
 
 // New 创建新的日志器
 func New(cfg Config) *Logger {
-	var level slog.Level
-	switch cfg.Level {
-	case "debug":
-		level = slog.LevelDebug
-	case "info":
-		level = slog.LevelInfo
-	case "warn":
-		level = slog.LevelWarn
-	case "error":
-		level = slog.LevelError
-	default:
-		level = slog.LevelInfo
-	}
-
-	var output io.Writer
-	switch cfg.Output {
-	case "stdout", "":
-		output = os.Stdout
-	case "stderr":
-		output = os.Stderr
-	default:
-		f, err := os.OpenFile(cfg.Output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		if err != nil {
-			output = os.Stdout
-		} else {
-			output = f
-		}
-	}
+	lvl, _ := ParseLevel(cfg.Level) // 无效级别按 info 处理
+	output := openOutput(cfg.Output)
 
 	opts := &slog.HandlerOptions{
-		Level:     level,
-		AddSource: level == slog.LevelDebug,
+		Level:     lvl,
+		AddSource: lvl == slog.LevelDebug,
 	}
 
 	var handler slog.Handler
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+// level 进程级日志级别（Setup 安装的日志器共用，可在运行中调整）
+var level = new(slog.LevelVar)
+
+// ParseLevel 解析日志级别：debug / info / warn / error，空字符串为 info
+func ParseLevel(s string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "info", "":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("invalid log level %q (expected debug, info, warn or error)", s)
+}
+
+// SetLevel 调整进程级日志级别（配置热加载使用，立即生效）
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
+// Setup 安装进程级日志
+//
+// slog 默认日志器与标准库 log 都输出到 cfg 指定的位置与格式（text / json）。
+// 标准库 log 的输出按 "[module.action] key=value" 约定解析为结构化记录，见 legacyWriter。
+func Setup(cfg Config) error {
+	l, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return err
+	}
+	if cfg.Format != "" && cfg.Format != "text" && cfg.Format != "json" {
+		return fmt.Errorf("invalid log format %q (expected text or json)", cfg.Format)
+	}
+	level.Set(l)
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	if cfg.Format == "json" {
+		handler = slog.NewJSONHandler(openOutput(cfg.Output), opts)
+	} else {
+		handler = slog.NewTextHandler(openOutput(cfg.Output), opts)
+	}
+	handler = &contextHandler{Handler: handler}
+	if cfg.Component != "" {
+		handler = handler.WithAttrs([]slog.Attr{slog.String("component", cfg.Component)})
+	}
+
+	slog.SetDefault(slog.New(handler))
+	// 在 SetDefault 之后设置：SetDefault 会把标准库 log 的输出改为按原文记录
+	log.SetFlags(0)
+	log.SetOutput(&legacyWriter{handler: handler})
+	return nil
+}
+
+// openOutput 打开日志输出：stdout（默认）、stderr 或文件路径（打开失败时回退到 stdout）
+func openOutput(output string) io.Writer {
+	switch output {
+	case "stdout", "":
+		return os.Stdout
+	case "stderr":
+		return os.Stderr
+	}
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return os.Stdout
+	}
+	return f
+}
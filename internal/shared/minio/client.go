@@ -104,3 +104,27 @@ func (c *Client) Exists(ctx context.Context, key string) (bool, error) {
 func (c *Client) Delete(ctx context.Context, key string) error {
 	return c.mc.RemoveObject(ctx, c.bucket, key, minio.RemoveObjectOptions{})
 }
+
+// Ping 检查 MinIO 连接与 bucket 可用性
+func (c *Client) Ping(ctx context.Context) error {
+	exists, err := c.mc.BucketExists(ctx, c.bucket)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("bucket %s not found", c.bucket)
+	}
+	return nil
+}
+
+// BucketUsage 统计 bucket 中的对象数量与总大小（字节）
+func (c *Client) BucketUsage(ctx context.Context) (objects int64, size int64, err error) {
+	for obj := range c.mc.ListObjects(ctx, c.bucket, minio.ListObjectsOptions{Recursive: true}) {
+		if obj.Err != nil {
+			return 0, 0, obj.Err
+		}
+		objects++
+		size += obj.Size
+	}
+	return objects, size, nil
+}
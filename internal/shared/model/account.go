@@ -6,6 +6,7 @@
 //   - AuthTask：认证任务
 //   - AuthTaskStatus：认证任务状态枚举
 //   - AuthSession：认证会话（兼容旧 API）
+//   - AccountLimit / AccountUsage：账号池调度的账号额度与用量
 package model
 
 import "time"
@@ -50,6 +51,46 @@ type Account struct {
 	LastUsedAt       *time.Time    `json:"last_used_at,omitempty" bson:"last_used_at,omitempty" db:"last_used_at"`                   // 最后使用时间
 }
 
+// ============================================================================
+// AccountUsage - 账号池调度的额度与用量
+// ============================================================================
+
+// AccountLimit 账号额度（调度配置 scheduler.accounts.limits），0 表示不限制
+type AccountLimit struct {
+	MaxConcurrent int `json:"max_concurrent"` // 同时执行的 Run 数上限
+	DailyRequests int `json:"daily_requests"` // 每日（UTC）派发的 Run 数上限
+}
+
+// AccountUsage 账号的调度用量（GET /api/v1/accounts/usage）
+//
+// 计数只包含从账号池租用账号的 Run，直接绑定实例或账号的 Run 不计入。
+type AccountUsage struct {
+	AccountID      string        `json:"account_id"`
+	Name           string        `json:"name,omitempty"`
+	AgentType      string        `json:"agent_type,omitempty"`
+	Status         AccountStatus `json:"status"`
+	ActiveRuns     int           `json:"active_runs"`
+	RequestsToday  int64         `json:"requests_today"`
+	Limit          AccountLimit  `json:"limit"`
+	ExhaustedUntil *time.Time    `json:"exhausted_until,omitempty"` // 触发限流后的冷却截止时间
+	Available      bool          `json:"available"`                 // 当前能否被账号池选中
+}
+
+// AccountLease Run 从账号池租用的账号
+type AccountLease struct {
+	RunID     string `json:"run_id"`
+	AccountID string `json:"account_id"`
+}
+
+// AccountFailover 账号触发限流后的切换结果
+type AccountFailover struct {
+	RunID          string     `json:"run_id"`
+	AccountID      string     `json:"account_id,omitempty"`      // 进入冷却的账号（Run 未租用账号时为空）
+	ExhaustedUntil *time.Time `json:"exhausted_until,omitempty"` // 账号冷却截止时间
+	Failovers      int64      `json:"failovers"`                 // Run 累计切换次数
+	Requeued       bool       `json:"requeued"`                  // Run 是否已重新排队（超过切换次数上限时为 false）
+}
+
 // ============================================================================
 // AuthTaskStatus - 认证任务状态
 // ============================================================================
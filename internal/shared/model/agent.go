@@ -122,7 +122,7 @@ type AgentTemplate struct {
 
 	// === 能力配置 ===
 
-	// Skills 技能 ID 列表
+	// Skills 技能 ID 列表（<skill_id>@<version> 固定技能包版本，未固定时使用最新版本）
 	Skills []string `json:"skills,omitempty" bson:"skills,omitempty" db:"skills"`
 
 	// Tools 工具配置（允许/拒绝/需审批的工具列表）
@@ -520,15 +520,27 @@ var BuiltinAgentTemplates = []AgentTemplate{
 //   - 定义 Docker 镜像和启动命令
 //   - 定义认证文件位置
 //   - 定义支持的登录方式
+//
+// 预定义类型内置在代码中（Builtin），自定义类型由管理员通过 /api/v1/agent-types 创建并持久化，
+// 通过 Adapter 配置通用适配器，无需编写 Go 代码即可接入任意 Agent CLI。
 type AgentTypeConfig struct {
-	ID           string   `json:"id"`            // 类型标识，如 qwen-code, openai-codex
-	Name         string   `json:"name"`          // 显示名称
-	Image        string   `json:"image"`         // Docker 镜像
-	AuthDir      string   `json:"auth_dir"`      // 容器内认证目录
-	AuthFile     string   `json:"auth_file"`     // 认证文件名
-	LoginCmd     string   `json:"login_cmd"`     // 登录命令
-	LoginMethods []string `json:"login_methods"` // 支持的登录方式
-	Description  string   `json:"description"`   // 类型描述
+	ID           string   `json:"id" bson:"_id" db:"id"`                                 // 类型标识，如 qwen-code, openai-codex
+	Name         string   `json:"name" bson:"name" db:"name"`                            // 显示名称
+	Image        string   `json:"image" bson:"image" db:"image"`                         // Docker 镜像
+	AuthDir      string   `json:"auth_dir" bson:"auth_dir" db:"auth_dir"`                // 容器内认证目录
+	AuthFile     string   `json:"auth_file" bson:"auth_file" db:"auth_file"`             // 认证文件名
+	LoginCmd     string   `json:"login_cmd" bson:"login_cmd" db:"login_cmd"`             // 登录命令
+	LoginMethods []string `json:"login_methods" bson:"login_methods" db:"login_methods"` // 支持的登录方式
+	Description  string   `json:"description" bson:"description" db:"description"`       // 类型描述
+
+	// Adapter 通用适配器配置（自定义类型必填，预定义类型由内置 Adapter 处理）
+	Adapter *GenericAdapterSpec `json:"adapter,omitempty" bson:"adapter,omitempty" db:"adapter"`
+
+	// Builtin 是否为预定义类型（不可修改、删除）
+	Builtin bool `json:"builtin" bson:"-" db:"-"`
+
+	CreatedAt time.Time `json:"created_at,omitzero" bson:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at,omitzero" bson:"updated_at" db:"updated_at"`
 }
 
 // PredefinedAgentTypeConfigs 预定义的 Agent 类型配置
@@ -542,6 +554,7 @@ var PredefinedAgentTypeConfigs = []AgentTypeConfig{
 		LoginCmd:     "qwen",
 		LoginMethods: []string{"oauth", "api_key"},
 		Description:  "基于 Qwen 大模型的 AI 编程助手",
+		Builtin:      true,
 	},
 	{
 		ID:           "openai-codex",
@@ -552,6 +565,17 @@ var PredefinedAgentTypeConfigs = []AgentTypeConfig{
 		LoginCmd:     "codex login",
 		LoginMethods: []string{"device_code", "oauth", "api_key"},
 		Description:  "OpenAI 官方 AI 编程智能体",
+		Builtin:      true,
+	},
+	{
+		ID:           "aider",
+		Name:         "Aider",
+		Image:        "runners/aider:latest",
+		AuthDir:      "/home/aider",
+		AuthFile:     ".env",
+		LoginMethods: []string{"api_key"},
+		Description:  "终端中的 AI 结对编程工具，支持任意 OpenAI 兼容模型",
+		Builtin:      true,
 	},
 }
 
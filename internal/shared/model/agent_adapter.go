@@ -0,0 +1,284 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// ============================================================================
+// GenericAdapterSpec - 通用适配器配置
+// ============================================================================
+
+// 通用适配器的输出格式
+const (
+	GenericOutputText = "text" // 纯文本，按正则规则提取事件
+	GenericOutputJSON = "json" // 每行一个 JSON 对象，按字段规则提取事件
+)
+
+// GenericAdapterSpec 通用适配器配置
+//
+// 自定义 Agent 类型通过该配置描述如何启动 CLI 以及如何把输出转换为统一事件，
+// NodeManager 据此构造通用 Adapter，接入新的 CLI 不需要编写 Go 代码。
+//
+// 参数使用 text/template 渲染，可用变量：
+//   - {{.TaskID}}：Run ID
+//   - {{.Prompt}}：任务提示词
+//   - {{.Model}}：模型名称
+//   - {{.Params.name}}：Agent 参数（snapshot.agent.parameters，未设置时为空）
+//
+// 渲染结果为空的参数会被丢弃，因此可选参数应写成 "--model={{.Model}}" 的单参数形式。
+type GenericAdapterSpec struct {
+	// Command 启动命令（如 ["aichat"]）
+	Command []string `json:"command" bson:"command"`
+
+	// Args 命令参数模板
+	Args []string `json:"args,omitempty" bson:"args,omitempty"`
+
+	// Env 额外环境变量（值同样按模板渲染）
+	Env map[string]string `json:"env,omitempty" bson:"env,omitempty"`
+
+	// WorkingDir 容器内工作目录，默认 /workspace
+	WorkingDir string `json:"working_dir,omitempty" bson:"working_dir,omitempty"`
+
+	// Output 输出格式：text（默认）/ json
+	Output string `json:"output,omitempty" bson:"output,omitempty"`
+
+	// Rules 事件提取规则，按顺序匹配，第一条命中的规则生效
+	Rules []GenericEventRule `json:"rules,omitempty" bson:"rules,omitempty"`
+
+	// DefaultEvent 未命中任何规则的非空行产生的事件类型，为空时忽略该行
+	// text 模式下 Payload 为 {"content": 行内容}，json 模式下为整个对象
+	DefaultEvent string `json:"default_event,omitempty" bson:"default_event,omitempty"`
+}
+
+// GenericEventRule 事件提取规则
+//
+// text 模式使用 Pattern 匹配整行，Payload 的值为正则展开模板（如 "${file}"、"$1"）；
+// json 模式在 Field 指定的字段（点分路径，如 "event.type"）等于 Value 时命中
+// （Value 为空表示字段存在即命中），Payload 的值为字段路径，为空时 Payload 为整个对象。
+type GenericEventRule struct {
+	// Type 产生的事件类型（如 message、tool_use_start、file_write、run_completed）
+	Type string `json:"type" bson:"type"`
+
+	// Pattern text 模式的正则表达式
+	Pattern string `json:"pattern,omitempty" bson:"pattern,omitempty"`
+
+	// Field json 模式的匹配字段
+	Field string `json:"field,omitempty" bson:"field,omitempty"`
+
+	// Value json 模式的匹配值
+	Value string `json:"value,omitempty" bson:"value,omitempty"`
+
+	// Payload 事件 Payload 字段
+	Payload map[string]string `json:"payload,omitempty" bson:"payload,omitempty"`
+}
+
+// OutputFormat 返回输出格式（未设置时为 text）
+func (s *GenericAdapterSpec) OutputFormat() string {
+	if s.Output == "" {
+		return GenericOutputText
+	}
+	return s.Output
+}
+
+// Validate 校验命令、参数模板与事件规则
+func (s *GenericAdapterSpec) Validate() error {
+	if len(s.Command) == 0 || strings.TrimSpace(s.Command[0]) == "" {
+		return fmt.Errorf("adapter.command is required")
+	}
+	for i, arg := range s.Args {
+		if _, err := template.New("arg").Parse(arg); err != nil {
+			return fmt.Errorf("adapter.args[%d]: %v", i, err)
+		}
+	}
+	for k, v := range s.Env {
+		if _, err := template.New("env").Parse(v); err != nil {
+			return fmt.Errorf("adapter.env.%s: %v", k, err)
+		}
+	}
+
+	format := s.OutputFormat()
+	if format != GenericOutputText && format != GenericOutputJSON {
+		return fmt.Errorf("adapter.output must be text or json")
+	}
+	for i, rule := range s.Rules {
+		if rule.Type == "" {
+			return fmt.Errorf("adapter.rules[%d].type is required", i)
+		}
+		if format == GenericOutputText {
+			if rule.Pattern == "" {
+				return fmt.Errorf("adapter.rules[%d].pattern is required for text output", i)
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return fmt.Errorf("adapter.rules[%d].pattern: %v", i, err)
+			}
+		} else if rule.Field == "" {
+			return fmt.Errorf("adapter.rules[%d].field is required for json output", i)
+		}
+	}
+	if len(s.Rules) == 0 && s.DefaultEvent == "" {
+		return fmt.Errorf("adapter needs at least one rule or a default_event")
+	}
+	return nil
+}
+
+// ============================================================================
+// AdapterInfo - 节点上报的适配器能力
+// ============================================================================
+
+// AdapterInfo 节点心跳上报的适配器版本与能力（capacity.adapters）
+//
+// Features 取值见 NodeManager adapter 包的 Feature 常量（streaming、tool_events、usage、feedback）。
+type AdapterInfo struct {
+	// Name 适配器名称（如 claude-v1）
+	Name string `json:"name"`
+
+	// Version 探测到的 CLI 版本，探测失败时为空
+	Version string `json:"version,omitempty"`
+
+	// Features 支持的能力
+	Features []string `json:"features,omitempty"`
+}
+
+// HasFeature 判断是否支持指定能力
+func (a *AdapterInfo) HasFeature(feature string) bool {
+	for _, f := range a.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+// AdapterRequirement 任务对执行节点适配器能力的要求
+//
+// 由任务标签 adapter-features / adapter-min-version 声明，
+// 调度时只选择所需适配器满足要求的节点。
+type AdapterRequirement struct {
+	Adapter    string   // 适配器名称（由 Agent 类型映射）
+	Features   []string // 必须支持的能力
+	MinVersion string   // CLI 最低版本（为空表示不限制）
+}
+
+// AdapterRequirementFromTask 从任务类型与标签解析适配器能力要求（未声明时返回 nil）
+func AdapterRequirementFromTask(task *Task) *AdapterRequirement {
+	if task == nil || task.Labels == nil {
+		return nil
+	}
+	features := task.Labels[LabelAdapterFeatures]
+	minVersion := strings.TrimSpace(task.Labels[LabelAdapterMinVersion])
+	if strings.TrimSpace(features) == "" && minVersion == "" {
+		return nil
+	}
+	req := &AdapterRequirement{Adapter: AdapterNameForAgentType(string(task.Type)), MinVersion: minVersion}
+	for _, f := range strings.Split(features, ",") {
+		if f = strings.TrimSpace(f); f != "" {
+			req.Features = append(req.Features, f)
+		}
+	}
+	return req
+}
+
+// SatisfiedBy 判断节点上报的适配器是否满足要求
+//
+// 声明了最低版本时，版本未知的适配器视为不满足。
+func (r *AdapterRequirement) SatisfiedBy(adapters []AdapterInfo) bool {
+	for i := range adapters {
+		a := &adapters[i]
+		if a.Name != r.Adapter {
+			continue
+		}
+		for _, f := range r.Features {
+			if !a.HasFeature(f) {
+				return false
+			}
+		}
+		if r.MinVersion != "" && (a.Version == "" || CompareVersions(a.Version, r.MinVersion) < 0) {
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// AdapterNameForAgentType 返回 Agent 类型对应的适配器名称
+//
+// 内置别名（qwen-code / qwen → qwencode-v1）单独映射，其余类型为 agentType + "-v1"。
+func AdapterNameForAgentType(agentType string) string {
+	mapping := map[string]string{
+		"qwen-code": "qwencode-v1",
+		"qwencode":  "qwencode-v1",
+		"qwen":      "qwencode-v1",
+		"gemini":    "gemini-v1",
+		"claude":    "claude-v1",
+	}
+	if name, ok := mapping[agentType]; ok {
+		return name
+	}
+	return agentType + "-v1"
+}
+
+// versionPattern 版本号（如 1.0.33、v0.6.1、0.86.1-dev）
+var versionPattern = regexp.MustCompile(`v?(\d+(?:\.\d+){0,3})(?:-[0-9A-Za-z.]+)?`)
+
+// ParseVersion 从 CLI 版本输出中提取第一个版本号（如 "1.0.33 (Claude Code)" → "1.0.33"），找不到时返回空
+func ParseVersion(output string) string {
+	m := versionPattern.FindStringSubmatch(output)
+	if m == nil {
+		return ""
+	}
+	return strings.TrimPrefix(m[0], "v")
+}
+
+// CompareVersions 按数字段比较两个版本号，返回 -1 / 0 / 1
+//
+// 缺失的段按 0 处理（1.2 == 1.2.0）；预发布后缀（-beta 等）低于对应正式版本。
+func CompareVersions(a, b string) int {
+	coreA, preA := splitVersion(a)
+	coreB, preB := splitVersion(b)
+	for i := 0; i < len(coreA) || i < len(coreB); i++ {
+		var x, y int
+		if i < len(coreA) {
+			x = coreA[i]
+		}
+		if i < len(coreB) {
+			y = coreB[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	case preA < preB:
+		return -1
+	default:
+		return 1
+	}
+}
+
+// splitVersion 拆分版本号的数字段与预发布后缀（无法解析的段按 0 处理）
+func splitVersion(v string) ([]int, string) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	pre := ""
+	if i := strings.IndexByte(v, '-'); i >= 0 {
+		v, pre = v[:i], v[i+1:]
+	}
+	parts := strings.Split(v, ".")
+	nums := make([]int, len(parts))
+	for i, p := range parts {
+		nums[i], _ = strconv.Atoi(p)
+	}
+	return nums, pre
+}
@@ -0,0 +1,39 @@
+// Package model 定义核心数据模型
+//
+// agent_pool.go 包含预热实例池相关的数据模型定义：
+//   - InstancePoolStatus：节点心跳上报的单个账号实例池状态（capacity.pools）
+//   - InstancePoolSummary：/api/v1/agents/pools 汇总的全部节点实例池
+package model
+
+// InstancePoolStatus 单个账号的预热实例池状态（节点心跳 capacity.pools 的元素）
+//
+// Leases 与 Misses 为节点启动以来的累计值：Run 启动时取到预热容器计入 Leases，
+// 池中没有空闲容器（回退到实例容器）计入 Misses。
+type InstancePoolStatus struct {
+	AccountID  string `json:"account_id"`
+	AgentType  string `json:"agent_type,omitempty"`
+	Size       int    `json:"size"`    // 目标空闲容器数
+	Idle       int    `json:"idle"`    // 空闲（已预热）容器数
+	Leased     int    `json:"leased"`  // 已租给 Run 的容器数
+	Warming    int    `json:"warming"` // 创建中的容器数
+	Leases     int64  `json:"leases"`
+	Misses     int64  `json:"misses"`
+	Recycled   int64  `json:"recycled"`    // 清理工作空间后放回池中的次数
+	Discarded  int64  `json:"discarded"`   // 清理失败、已停止或超出目标数而删除的容器数
+	AvgWarmMS  int64  `json:"avg_warm_ms"` // 预热容器的平均创建耗时（毫秒）
+	LastError  string `json:"last_error,omitempty"`
+	NodeID     string `json:"node_id,omitempty"` // API Server 汇总时填充
+	NodeStatus string `json:"node_status,omitempty"`
+}
+
+// InstancePoolSummary 全部节点的实例池汇总
+type InstancePoolSummary struct {
+	Pools   []InstancePoolStatus `json:"pools"`
+	Idle    int                  `json:"idle"`
+	Leased  int                  `json:"leased"`
+	Warming int                  `json:"warming"`
+	Leases  int64                `json:"leases"`
+	Misses  int64                `json:"misses"`
+	// HitRate 租用命中率（Leases / (Leases + Misses)），没有租用记录时为 0
+	HitRate float64 `json:"hit_rate"`
+}
@@ -553,3 +553,16 @@ func TestBuiltinAgentTemplates(t *testing.T) {
 	require.NotNil(t, claudeTemplate)
 	assert.Contains(t, claudeTemplate.ID, "claude")
 }
+
+// TestCompareVersions 验证版本号比较与提取
+func TestCompareVersions(t *testing.T) {
+	assert.Equal(t, 0, CompareVersions("1.2", "1.2.0"))
+	assert.Equal(t, -1, CompareVersions("0.9.9", "0.10.0"))
+	assert.Equal(t, 1, CompareVersions("v1.0.33", "1.0.4"))
+	assert.Equal(t, -1, CompareVersions("1.0.0-beta", "1.0.0"))
+
+	assert.Equal(t, "1.0.33", ParseVersion("1.0.33 (Claude Code)"))
+	assert.Equal(t, "0.86.1", ParseVersion("aider 0.86.1\n"))
+	assert.Equal(t, "0.7.0-nightly", ParseVersion("v0.7.0-nightly"))
+	assert.Equal(t, "", ParseVersion("command not found"))
+}
@@ -0,0 +1,60 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ============================================================================
+// AuditLog - 变更操作审计日志
+// ============================================================================
+
+// AuditLog 一次变更类 API 请求（POST / PUT / PATCH / DELETE）的审计记录
+//
+// 由 API Server 路由中间件统一写入，只追加、不修改：
+//   - 操作者取自认证信息（未启用认证或公开接口时为空）
+//   - Request 为请求体（JSON），密码、密钥、Token 等敏感字段已脱敏
+//   - ResourceID 取自路由中的 {id}，创建类请求取自响应体的 id
+type AuditLog struct {
+	// ID 唯一标识
+	ID string `json:"id" bson:"_id" db:"id"`
+
+	// ActorID 操作者用户 ID
+	ActorID string `json:"actor_id,omitempty" bson:"actor_id,omitempty" db:"actor_id"`
+
+	// ActorEmail 操作者邮箱
+	ActorEmail string `json:"actor_email,omitempty" bson:"actor_email,omitempty" db:"actor_email"`
+
+	// ActorRole 操作者角色
+	ActorRole string `json:"actor_role,omitempty" bson:"actor_role,omitempty" db:"actor_role"`
+
+	// Method HTTP 方法
+	Method string `json:"method" bson:"method" db:"method"`
+
+	// Route 路由模式（如 POST /api/v1/tasks/{id}/cancel）
+	Route string `json:"route" bson:"route" db:"route"`
+
+	// Path 请求路径
+	Path string `json:"path" bson:"path" db:"path"`
+
+	// ResourceType 资源类型（路径 /api/v1/ 后的第一段，如 tasks）
+	ResourceType string `json:"resource_type" bson:"resource_type" db:"resource_type"`
+
+	// ResourceID 资源 ID
+	ResourceID string `json:"resource_id,omitempty" bson:"resource_id,omitempty" db:"resource_id"`
+
+	// Status 响应状态码
+	Status int `json:"status" bson:"status" db:"status"`
+
+	// SourceIP 来源 IP
+	SourceIP string `json:"source_ip" bson:"source_ip" db:"source_ip"`
+
+	// Request 请求内容（已脱敏）
+	Request json.RawMessage `json:"request,omitempty" bson:"request,omitempty" db:"request"`
+
+	// DurationMs 处理耗时（毫秒）
+	DurationMs int64 `json:"duration_ms" bson:"duration_ms" db:"duration_ms"`
+
+	// CreatedAt 请求时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+}
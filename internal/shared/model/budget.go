@@ -0,0 +1,105 @@
+package model
+
+import "time"
+
+// ============================================================================
+// Budget - 账号/项目月度预算
+// ============================================================================
+
+// BudgetScope 预算作用范围
+type BudgetScope string
+
+const (
+	// BudgetScopeAccount 按账号（Run 使用的账号）
+	BudgetScopeAccount BudgetScope = "account"
+
+	// BudgetScopeProject 按项目（Run 所属任务的项目）
+	BudgetScopeProject BudgetScope = "project"
+)
+
+// IsValid 判断预算作用范围是否有效
+func (s BudgetScope) IsValid() bool {
+	return s == BudgetScopeAccount || s == BudgetScopeProject
+}
+
+// BudgetPeriodLayout 预算周期格式（UTC 自然月）
+const BudgetPeriodLayout = "2006-01"
+
+// DefaultBudgetAlertThreshold 默认预警阈值（已用比例）
+const DefaultBudgetAlertThreshold = 0.8
+
+// Budget 账号或项目的月度用量上限
+//
+// 用量按 UTC 自然月统计（run_usage 中日期落在当月的记录）：
+//   - 已用比例达到 AlertThreshold 时发送一次预警通知（budget.alert）
+//   - Token 或费用任一项达到上限时预算耗尽：调度器不再分派受该预算约束的新 Run，
+//     Run 保持 queued 并记录 budget_exceeded 事件，同时发送一次通知（budget.exceeded）
+//   - 下个月或上限调高后，排队的 Run 由调度器保底轮询重新分派
+type Budget struct {
+	// ID 唯一标识
+	ID string `json:"id" bson:"_id" db:"id"`
+
+	// Name 名称
+	Name string `json:"name" bson:"name" db:"name"`
+
+	// Scope 作用范围（account / project）
+	Scope BudgetScope `json:"scope" bson:"scope" db:"scope"`
+
+	// ScopeID 账号 ID 或项目 ID
+	ScopeID string `json:"scope_id" bson:"scope_id" db:"scope_id"`
+
+	// LimitTokens 每月 Token 上限（0 表示不限）
+	LimitTokens int64 `json:"limit_tokens" bson:"limit_tokens" db:"limit_tokens"`
+
+	// LimitUSD 每月费用上限（美元，0 表示不限）
+	LimitUSD float64 `json:"limit_usd" bson:"limit_usd" db:"limit_usd"`
+
+	// AlertThreshold 预警阈值（已用比例，0~1）
+	AlertThreshold float64 `json:"alert_threshold" bson:"alert_threshold" db:"alert_threshold"`
+
+	// Enabled 是否启用
+	Enabled bool `json:"enabled" bson:"enabled" db:"enabled"`
+
+	// AlertedPeriod 最近一次发送预警的周期（YYYY-MM），同一周期只通知一次
+	AlertedPeriod string `json:"alerted_period,omitempty" bson:"alerted_period,omitempty" db:"alerted_period"`
+
+	// ExceededPeriod 最近一次预算耗尽的周期（YYYY-MM），同一周期只通知一次
+	ExceededPeriod string `json:"exceeded_period,omitempty" bson:"exceeded_period,omitempty" db:"exceeded_period"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+
+	// UpdatedAt 更新时间
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// BudgetSpend 预算周期内的已用量
+type BudgetSpend struct {
+	Period  string  `json:"period"` // YYYY-MM
+	Tokens  int64   `json:"tokens"`
+	CostUSD float64 `json:"cost_usd"`
+	Runs    int     `json:"runs"`
+}
+
+// BudgetPeriod 时间所在的预算周期（UTC 自然月）
+func BudgetPeriod(t time.Time) string {
+	return t.UTC().Format(BudgetPeriodLayout)
+}
+
+// Usage 已用比例（Token 与费用中较高的一项，未设置上限的项不计）
+func (b *Budget) Usage(spend BudgetSpend) float64 {
+	var ratio float64
+	if b.LimitTokens > 0 {
+		ratio = float64(spend.Tokens) / float64(b.LimitTokens)
+	}
+	if b.LimitUSD > 0 {
+		ratio = max(ratio, spend.CostUSD/b.LimitUSD)
+	}
+	return ratio
+}
+
+// Exhausted 判断预算是否耗尽（Token 或费用任一项达到上限）
+func (b *Budget) Exhausted(spend BudgetSpend) bool {
+	return (b.LimitTokens > 0 && spend.Tokens >= b.LimitTokens) ||
+		(b.LimitUSD > 0 && spend.CostUSD >= b.LimitUSD)
+}
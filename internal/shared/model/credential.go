@@ -0,0 +1,54 @@
+// Package model 定义核心数据模型
+//
+// credential.go 包含 Git 凭据相关的数据模型定义：
+//   - Credential：凭据（SSH 私钥 / HTTPS Token）
+//   - CredentialType：凭据类型枚举
+//
+// 设计理念：
+//   - 凭据按名称被 WorkspaceConfig.Git.CredentialRef 引用
+//   - 敏感内容由存储层信封加密后落库（EncryptedSecret，见 fieldcrypt.WrapStore），永不在 API 响应中返回
+//   - 仅 NodeManager 在准备工作空间时通过专用接口解析明文
+package model
+
+import "time"
+
+// ============================================================================
+// CredentialType - 凭据类型枚举
+// ============================================================================
+
+// CredentialType 凭据类型
+type CredentialType string
+
+const (
+	// CredentialTypeSSHKey SSH 私钥（用于 git@host:owner/repo.git）
+	CredentialTypeSSHKey CredentialType = "ssh_key"
+
+	// CredentialTypeHTTPSToken HTTPS Token（用于 https://host/owner/repo.git）
+	CredentialTypeHTTPSToken CredentialType = "https_token"
+)
+
+// IsValid 检查凭据类型是否合法
+func (t CredentialType) IsValid() bool {
+	return t == CredentialTypeSSHKey || t == CredentialTypeHTTPSToken
+}
+
+// ============================================================================
+// Credential - 凭据
+// ============================================================================
+
+// Credential Git 凭据
+//
+// 字段说明：
+//   - Name：全局唯一名称，被 GitConfig.CredentialRef 引用
+//   - Username：HTTPS 用户名（可选，GitHub 默认 x-access-token，GitLab 默认 oauth2）
+//   - EncryptedSecret：私钥或 Token，落库时为密文，经加密包装的存储读写的是明文；JSON 序列化时隐藏
+type Credential struct {
+	ID              string         `json:"id" bson:"_id" db:"id"`
+	Name            string         `json:"name" bson:"name" db:"name"`
+	Type            CredentialType `json:"type" bson:"type" db:"type"`
+	Username        string         `json:"username,omitempty" bson:"username,omitempty" db:"username"`
+	Description     string         `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+	EncryptedSecret string         `json:"-" bson:"encrypted_secret" db:"encrypted_secret"`
+	CreatedAt       time.Time      `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
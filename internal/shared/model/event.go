@@ -39,6 +39,38 @@ const (
 	// EventTypeRunFailed 执行失败
 	EventTypeRunFailed EventType = "run_failed"
 
+	// EventTypeRunOrphaned 执行节点失联，Run 被 API Server 回收（重新排队或判定失败）
+	// Payload: {"node_id": "...", "action": "requeued", "reason": "..."}
+	EventTypeRunOrphaned EventType = "run_orphaned"
+
+	// EventTypeWorkspaceSynced 工作空间变更已回写到 Git 仓库
+	// Payload: {"branch": "...", "commit_sha": "...", "pr_url": "...", "no_changes": false}
+	EventTypeWorkspaceSynced EventType = "workspace_synced"
+
+	// EventTypeHookStarted 生命周期钩子开始执行
+	// Payload: {"phase": "pre_run", "name": "..."}
+	EventTypeHookStarted EventType = "hook_started"
+
+	// EventTypeHookCompleted 生命周期钩子执行结束
+	// Payload: {"phase": "pre_run", "name": "...", "exit_code": 0, "output": "...", "duration_ms": 12, "aborted": false}
+	EventTypeHookCompleted EventType = "hook_completed"
+
+	// EventTypeResourceLimit 任务资源限制超出节点容量被拒绝，或执行中触及限制
+	// Payload: {"action": "rejected", "resource": "memory", "limit": "16Gi", "capacity": "8Gi"}
+	//          {"action": "exceeded", "resource": "memory", "limit": "4Gi", "exit_code": 137}
+	EventTypeResourceLimit EventType = "resource_limit"
+
+	// EventTypeRunCancellation Run 被取消或超时终止时 Agent 的停止阶段（interrupt → kill → exited）
+	// Payload: {"phase": "interrupt", "reason": "cancelled", "signal": "SIGINT", "grace_period_ms": 10000}
+	//          {"phase": "kill", "reason": "cancelled", "signal": "SIGKILL", "elapsed_ms": 10000}
+	//          {"phase": "exited", "reason": "cancelled", "clean": false, "elapsed_ms": 10020}
+	EventTypeRunCancellation EventType = "run_cancellation"
+
+	// EventTypeNodeLog NodeManager 侧的诊断日志（Workspace 准备、容器定位、Agent 进程 stderr 等），
+	// 由 POST /api/v1/runs/{id}/node-logs 写入，不经事件上报的节点序号
+	// Payload: {"level": "error", "stage": "workspace", "message": "..."}
+	EventTypeNodeLog EventType = "node_log"
+
 	// === 输出事件 ===
 
 	// EventTypeMessage Agent 输出的文本消息
@@ -88,10 +120,35 @@ const (
 	// Payload: {"action": "delete_file", "target": "...", "reason": "..."}
 	EventTypeApprovalRequest EventType = "approval_request"
 
+	// EventTypeApprovalRequired Agent 暂停等待人工审批（API Server 据此创建 ApprovalRequest）
+	// Payload: {"approval_id": "...", "type": "dangerous_operation", "operation": "...", "reason": "...", "context": {...}, "timeout_seconds": 1800}
+	EventTypeApprovalRequired EventType = "approval_required"
+
 	// EventTypeApprovalResponse 人工审批响应
 	// Payload: {"approved": true, "comment": "..."}
 	EventTypeApprovalResponse EventType = "approval_response"
 
+	// EventTypeFeedbackDelivered 人工反馈已写入 Agent 的 interrupt 文件，等待 Agent 读取
+	// Payload: {"feedback_id": "...", "type": "guidance", "channel": "file", "path": ".agents-admin/FEEDBACK.md"}
+	EventTypeFeedbackDelivered EventType = "feedback_delivered"
+
+	// EventTypeFeedbackConsumed Agent 已读取人工反馈（API Server 据此标记 HumanFeedback 已处理）
+	// Payload: {"feedback_id": "...", "channel": "stdin"}
+	EventTypeFeedbackConsumed EventType = "feedback_consumed"
+
+	// EventTypeSpawnSubtask Agent 拆分出子任务（NodeManager 调用 POST /api/v1/runs/{id}/subtasks 后补充结果上报）
+	// Payload: {"prompt": "...", "name": "...", "task_id": "task-...", "run_id": "run-...", "depth": 1}
+	//          {"prompt": "...", "error": "subtask limit exceeded (max_children 10)"}
+	EventTypeSpawnSubtask EventType = "spawn_subtask"
+
+	// EventTypeContextProduced Run 产出的上下文已记录到任务（NodeManager 调用 POST /api/v1/runs/{id}/context 后上报）
+	// Payload: {"items": [{"type": "summary", "name": "design.md", "size": 1024}], "skipped": ["huge.log"]}
+	EventTypeContextProduced EventType = "context_produced"
+
+	// EventTypePolicyDenied Agent 调用了安全策略禁止的工具（CLI 已拒绝，或 NodeManager 终止了 Agent）
+	// Payload: {"permission": "command_execute", "tool": "Bash", "sources": ["builtin-strict"], "enforced": true}
+	EventTypePolicyDenied EventType = "policy_denied"
+
 	// EventTypeCheckpoint 检查点（可恢复）
 	// Payload: {"state": {...}, "resumable": true}
 	EventTypeCheckpoint EventType = "checkpoint"
@@ -105,6 +162,10 @@ const (
 	// Payload: {"version": "...", "model": "...", "tools": [...]}
 	EventTypeSystemInfo EventType = "system_info"
 
+	// EventTypeUsage Token 用量（NodeManager 从 Adapter 提取，API Server 累加到 Run 的用量记录）
+	// Payload: {"input_tokens": 1200, "output_tokens": 300, "cost_usd": 0.01, "model": "..."}
+	EventTypeUsage EventType = "usage"
+
 	// EventTypeResult 执行结果（Agent 返回的最终结果，不触发状态变更）
 	// Payload: {"result": "...", "usage": {...}}
 	EventTypeResult EventType = "result"
@@ -139,14 +200,28 @@ const (
 //   - Timestamp：事件发生时间
 //   - Payload：事件数据（JSON）
 //   - Raw：原始输出（可选，用于调试）
+//   - SchemaVersion：Payload 匹配的结构版本（见 EventSchema，0 表示未定义结构或不匹配）
+//   - RawRef：原始输出转存到对象存储后的位置（见 apiserver/rawoffload）
+//   - Redacted：Payload 或 Raw 中的命中内容已被内容审核掩码（见 apiserver/moderation）
 type Event struct {
 	ID        int64           `json:"id" bson:"id" db:"id"`                                    // 事件 ID（SQL 自增；MongoDB 自动生成 _id）
 	RunID     string          `json:"run_id" bson:"run_id" db:"run_id"`                        // 所属 Run ID
-	Seq       int             `json:"seq" bson:"seq" db:"seq"`                                 // 事件序号
+	Seq       int             `json:"seq" bson:"seq" db:"seq"`                                 // 事件序号（API Server 按 Run 单调分配）
 	Type      string          `json:"type" bson:"type" db:"type"`                              // 事件类型
 	Timestamp time.Time       `json:"timestamp" bson:"timestamp" db:"timestamp"`               // 事件时间
 	Payload   json.RawMessage `json:"payload,omitempty" bson:"payload,omitempty" db:"payload"` // 事件数据
 	Raw       *string         `json:"raw,omitempty" bson:"raw,omitempty" db:"raw"`             // 原始输出
+
+	SchemaVersion int `json:"schema_version" bson:"schema_version" db:"schema_version"` // Payload 结构版本
+
+	// RawRef 原始输出转存到对象存储后的位置（对象键#seq），此时 Raw 为空，读取时由存储包装层还原
+	RawRef string `json:"raw_ref,omitempty" bson:"raw_ref,omitempty" db:"raw_ref"`
+
+	// NodeSeq 节点上报时携带的序号，用于识别重试上报的重复事件（API Server 自身写入的事件为 0）
+	NodeSeq int `json:"node_seq,omitempty" bson:"node_seq,omitempty" db:"node_seq"`
+
+	// Redacted 入库前命中内容已被掩码（保留原文时可由管理员查看，见 EventOriginal）
+	Redacted bool `json:"redacted,omitempty" bson:"redacted,omitempty" db:"redacted"`
 }
 
 // ============================================================================
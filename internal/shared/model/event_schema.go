@@ -0,0 +1,300 @@
+package model
+
+import (
+	"fmt"
+	"sort"
+)
+
+// ============================================================================
+// EventSchema - 事件 Payload 的版本化结构定义
+// ============================================================================
+
+// 事件结构校验模式
+const (
+	// EventSchemaLenient 宽松模式（默认）：不符合结构的事件照常入库，schema_version 记为 0
+	EventSchemaLenient = "lenient"
+
+	// EventSchemaStrict 严格模式：未定义结构的事件类型与不符合结构的事件被拒绝（计入 rejected）
+	EventSchemaStrict = "strict"
+)
+
+// 字段值类型
+const (
+	FieldString = "string"
+	FieldNumber = "number"
+	FieldBool   = "bool"
+	FieldObject = "object"
+	FieldArray  = "array"
+	FieldAny    = "any" // 任意类型（只检查 Required）
+)
+
+// EventField 事件 Payload 中的字段
+//
+// 字段存在时必须为声明的类型；Required 字段必须存在。
+// Adapter 透传的 CLI 原始字段不在结构中声明，不受校验。
+type EventField struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Required bool   `json:"required,omitempty"`
+}
+
+// EventSchema 某个事件类型的一个结构版本
+//
+// 结构只增加可选字段时不升级版本；字段改名、改类型或新增必填字段时增加新版本，
+// 旧版本保留用于识别历史事件。入库时从最新版本开始匹配，事件标注第一个匹配的版本号，
+// UI 与分析按 (type, schema_version) 读取稳定字段。
+type EventSchema struct {
+	Type    EventType    `json:"type"`
+	Version int          `json:"version"`
+	Fields  []EventField `json:"fields"`
+}
+
+// eventSchemas 各事件类型的结构定义（按版本升序）
+var eventSchemas = map[EventType][]EventSchema{
+	EventTypeRunStarted: {{Version: 1, Fields: []EventField{
+		{Name: "node_id", Kind: FieldString},
+		{Name: "backend", Kind: FieldString},
+		{Name: "workspace", Kind: FieldObject},
+		{Name: "limits", Kind: FieldAny},
+	}}},
+	EventTypeRunCompleted: {{Version: 1, Fields: []EventField{
+		{Name: "status", Kind: FieldString},
+		{Name: "error", Kind: FieldString},
+	}}},
+	EventTypeRunFailed: {{Version: 1, Fields: []EventField{
+		{Name: "error", Kind: FieldString},
+	}}},
+	EventTypeRunOrphaned: {{Version: 1, Fields: []EventField{
+		{Name: "node_id", Kind: FieldString, Required: true},
+		{Name: "action", Kind: FieldString, Required: true},
+		{Name: "reason", Kind: FieldString},
+	}}},
+	EventTypeNodeLog: {{Version: 1, Fields: []EventField{
+		{Name: "level", Kind: FieldString, Required: true},
+		{Name: "stage", Kind: FieldString, Required: true},
+		{Name: "message", Kind: FieldString, Required: true},
+	}}},
+	EventTypeWorkspaceSynced: {{Version: 1, Fields: []EventField{
+		{Name: "branch", Kind: FieldString},
+		{Name: "commit_sha", Kind: FieldString},
+		{Name: "pr_url", Kind: FieldString},
+		{Name: "no_changes", Kind: FieldBool},
+	}}},
+	EventTypeHookStarted: {{Version: 1, Fields: []EventField{
+		{Name: "phase", Kind: FieldString, Required: true},
+		{Name: "name", Kind: FieldString},
+	}}},
+	EventTypeHookCompleted: {{Version: 1, Fields: []EventField{
+		{Name: "phase", Kind: FieldString, Required: true},
+		{Name: "name", Kind: FieldString},
+		{Name: "exit_code", Kind: FieldNumber},
+		{Name: "output", Kind: FieldString},
+		{Name: "duration_ms", Kind: FieldNumber},
+		{Name: "aborted", Kind: FieldBool},
+	}}},
+	EventTypeResourceLimit: {{Version: 1, Fields: []EventField{
+		{Name: "action", Kind: FieldString, Required: true},
+		{Name: "resource", Kind: FieldString, Required: true},
+		{Name: "limit", Kind: FieldAny},
+		{Name: "capacity", Kind: FieldAny},
+		{Name: "exit_code", Kind: FieldNumber},
+	}}},
+	EventTypeRunCancellation: {{Version: 1, Fields: []EventField{
+		{Name: "phase", Kind: FieldString, Required: true},
+		{Name: "reason", Kind: FieldString, Required: true},
+		{Name: "signal", Kind: FieldString},
+		{Name: "grace_period_ms", Kind: FieldNumber},
+		{Name: "elapsed_ms", Kind: FieldNumber},
+		{Name: "clean", Kind: FieldBool},
+		{Name: "error", Kind: FieldString},
+	}}},
+	EventTypeMessage: {{Version: 1, Fields: []EventField{
+		{Name: "content", Kind: FieldString},
+	}}},
+	EventTypeThinking: {{Version: 1, Fields: []EventField{
+		{Name: "content", Kind: FieldString},
+	}}},
+	EventTypeProgress: {{Version: 1, Fields: []EventField{
+		{Name: "progress", Kind: FieldNumber},
+		{Name: "message", Kind: FieldString},
+	}}},
+	EventTypeToolUseStart: {{Version: 1, Fields: []EventField{
+		{Name: "tool", Kind: FieldString},
+		{Name: "input", Kind: FieldAny},
+	}}},
+	EventTypeToolResult: {{Version: 1, Fields: []EventField{
+		{Name: "tool", Kind: FieldString},
+		{Name: "output", Kind: FieldAny},
+		{Name: "success", Kind: FieldBool},
+	}}},
+	EventTypeFileRead:   {{Version: 1, Fields: []EventField{{Name: "path", Kind: FieldString}}}},
+	EventTypeFileWrite:  {{Version: 1, Fields: []EventField{{Name: "path", Kind: FieldString}}}},
+	EventTypeFileDelete: {{Version: 1, Fields: []EventField{{Name: "path", Kind: FieldString}}}},
+	EventTypeCommand: {{Version: 1, Fields: []EventField{
+		{Name: "command", Kind: FieldString},
+		{Name: "args", Kind: FieldArray},
+	}}},
+	EventTypeCommandOutput: {{Version: 1, Fields: []EventField{
+		{Name: "stdout", Kind: FieldString},
+		{Name: "stderr", Kind: FieldString},
+		{Name: "exit_code", Kind: FieldNumber},
+	}}},
+	EventTypeApprovalRequest: {{Version: 1, Fields: []EventField{
+		{Name: "action", Kind: FieldString},
+		{Name: "target", Kind: FieldString},
+		{Name: "reason", Kind: FieldString},
+	}}},
+	EventTypeApprovalRequired: {{Version: 1, Fields: []EventField{
+		{Name: "approval_id", Kind: FieldString, Required: true},
+		{Name: "type", Kind: FieldString},
+		{Name: "operation", Kind: FieldString},
+		{Name: "reason", Kind: FieldString},
+		{Name: "context", Kind: FieldObject},
+		{Name: "timeout_seconds", Kind: FieldNumber},
+	}}},
+	EventTypeApprovalResponse: {{Version: 1, Fields: []EventField{
+		{Name: "approved", Kind: FieldBool},
+		{Name: "comment", Kind: FieldString},
+	}}},
+	EventTypeFeedbackDelivered: {{Version: 1, Fields: []EventField{
+		{Name: "feedback_id", Kind: FieldString, Required: true},
+		{Name: "type", Kind: FieldString},
+		{Name: "channel", Kind: FieldString},
+		{Name: "path", Kind: FieldString},
+	}}},
+	EventTypeFeedbackConsumed: {{Version: 1, Fields: []EventField{
+		{Name: "feedback_id", Kind: FieldString, Required: true},
+		{Name: "channel", Kind: FieldString},
+	}}},
+	EventTypeSpawnSubtask: {{Version: 1, Fields: []EventField{
+		{Name: "prompt", Kind: FieldString, Required: true},
+		{Name: "name", Kind: FieldString},
+		{Name: "task_id", Kind: FieldString},
+		{Name: "run_id", Kind: FieldString},
+		{Name: "depth", Kind: FieldNumber},
+		{Name: "error", Kind: FieldString},
+	}}},
+	EventTypePolicyDenied: {{Version: 1, Fields: []EventField{
+		{Name: "permission", Kind: FieldString},
+		{Name: "tool", Kind: FieldString},
+		{Name: "sources", Kind: FieldArray},
+		{Name: "enforced", Kind: FieldBool, Required: true},
+	}}},
+	EventTypeCheckpoint: {{Version: 1, Fields: []EventField{
+		{Name: "state", Kind: FieldAny},
+		{Name: "resumable", Kind: FieldBool},
+	}}},
+	EventTypeHeartbeat: {{Version: 1}},
+	EventTypeSystemInfo: {{Version: 1, Fields: []EventField{
+		{Name: "version", Kind: FieldString},
+		{Name: "model", Kind: FieldString},
+		{Name: "tools", Kind: FieldArray},
+	}}},
+	EventTypeResult: {{Version: 1, Fields: []EventField{
+		{Name: "result", Kind: FieldAny},
+		{Name: "usage", Kind: FieldObject},
+	}}},
+	EventTypeError: {{Version: 1, Fields: []EventField{
+		{Name: "message", Kind: FieldString},
+		{Name: "code", Kind: FieldString},
+		{Name: "recoverable", Kind: FieldBool},
+	}}},
+	EventTypeWarning: {{Version: 1, Fields: []EventField{
+		{Name: "message", Kind: FieldString},
+		{Name: "code", Kind: FieldString},
+	}}},
+	EventTypeUsage: {{Version: 1, Fields: []EventField{
+		{Name: "input_tokens", Kind: FieldNumber, Required: true},
+		{Name: "output_tokens", Kind: FieldNumber, Required: true},
+		{Name: "cache_read_tokens", Kind: FieldNumber},
+		{Name: "cache_write_tokens", Kind: FieldNumber},
+		{Name: "cost_usd", Kind: FieldNumber},
+		{Name: "model", Kind: FieldString},
+	}}},
+}
+
+// EventSchemas 返回全部事件结构定义（按类型、版本排序）
+func EventSchemas() []EventSchema {
+	var out []EventSchema
+	for t, versions := range eventSchemas {
+		for _, s := range versions {
+			s.Type = t
+			out = append(out, s)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Type != out[j].Type {
+			return out[i].Type < out[j].Type
+		}
+		return out[i].Version < out[j].Version
+	})
+	return out
+}
+
+// MatchEventSchema 返回 Payload 匹配的最新结构版本
+//
+// 事件类型未定义结构时返回 (0, nil)；不匹配任何版本时返回 0 与最新版本的校验错误。
+func MatchEventSchema(eventType string, payload map[string]interface{}) (int, error) {
+	versions := eventSchemas[EventType(eventType)]
+	if len(versions) == 0 {
+		return 0, nil
+	}
+	var latestErr error
+	for i := len(versions) - 1; i >= 0; i-- {
+		err := versions[i].validate(payload)
+		if err == nil {
+			return versions[i].Version, nil
+		}
+		if latestErr == nil {
+			latestErr = fmt.Errorf("%s v%d: %v", eventType, versions[i].Version, err)
+		}
+	}
+	return 0, latestErr
+}
+
+// HasEventSchema 判断事件类型是否定义了结构
+func HasEventSchema(eventType string) bool {
+	return len(eventSchemas[EventType(eventType)]) > 0
+}
+
+// validate 校验 Payload 是否符合该版本结构
+func (s *EventSchema) validate(payload map[string]interface{}) error {
+	for _, f := range s.Fields {
+		v, ok := payload[f.Name]
+		if !ok || v == nil {
+			if f.Required {
+				return fmt.Errorf("field %s is required", f.Name)
+			}
+			continue
+		}
+		if !matchFieldKind(f.Kind, v) {
+			return fmt.Errorf("field %s must be %s", f.Name, f.Kind)
+		}
+	}
+	return nil
+}
+
+// matchFieldKind 判断 JSON 解码后的值是否为声明的类型
+func matchFieldKind(kind string, v interface{}) bool {
+	switch kind {
+	case FieldString:
+		_, ok := v.(string)
+		return ok
+	case FieldNumber:
+		switch v.(type) {
+		case float64, float32, int, int32, int64:
+			return true
+		}
+		return false
+	case FieldBool:
+		_, ok := v.(bool)
+		return ok
+	case FieldObject:
+		_, ok := v.(map[string]interface{})
+		return ok
+	case FieldArray:
+		_, ok := v.([]interface{})
+		return ok
+	}
+	return true
+}
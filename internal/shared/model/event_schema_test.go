@@ -0,0 +1,52 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchEventSchema 验证事件结构匹配与版本选择
+func TestMatchEventSchema(t *testing.T) {
+	v, err := MatchEventSchema("usage", map[string]interface{}{"input_tokens": float64(10), "output_tokens": float64(2), "model": "m"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = MatchEventSchema("usage", map[string]interface{}{"input_tokens": "10"})
+	assert.Equal(t, 0, v)
+	assert.ErrorContains(t, err, "usage v1: field input_tokens must be number")
+
+	// 未声明的 CLI 原始字段不受校验
+	v, err = MatchEventSchema("message", map[string]interface{}{"message": map[string]interface{}{"role": "assistant"}})
+	require.NoError(t, err)
+	assert.Equal(t, 1, v)
+
+	v, err = MatchEventSchema("custom_event", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, v)
+	assert.False(t, HasEventSchema("custom_event"))
+}
+
+// TestMatchEventSchema_Versions 新版本不匹配时回退到旧版本
+func TestMatchEventSchema_Versions(t *testing.T) {
+	saved := eventSchemas["test_event"]
+	defer func() { eventSchemas["test_event"] = saved }()
+	eventSchemas["test_event"] = []EventSchema{
+		{Version: 1, Fields: []EventField{{Name: "name", Kind: FieldString, Required: true}}},
+		{Version: 2, Fields: []EventField{{Name: "tool", Kind: FieldObject, Required: true}}},
+	}
+
+	v, _ := MatchEventSchema("test_event", map[string]interface{}{"tool": map[string]interface{}{}})
+	assert.Equal(t, 2, v)
+	v, _ = MatchEventSchema("test_event", map[string]interface{}{"name": "read_file"})
+	assert.Equal(t, 1, v)
+	_, err := MatchEventSchema("test_event", map[string]interface{}{})
+	assert.ErrorContains(t, err, "test_event v2")
+
+	schemas := EventSchemas()
+	for i := 1; i < len(schemas); i++ {
+		prev, cur := schemas[i-1], schemas[i]
+		assert.True(t, prev.Type < cur.Type || (prev.Type == cur.Type && prev.Version < cur.Version))
+	}
+}
@@ -0,0 +1,114 @@
+// Package model 定义核心数据模型
+//
+// event_search.go 包含事件全文检索相关的数据模型定义：
+//   - EventSearchHit：检索命中的事件（含高亮片段）
+//   - SearchTerms / HighlightSnippet：关键词拆分与高亮片段生成
+//
+// 设计理念：
+//   - 检索范围为事件 payload 与原始输出（raw），即 Agent 的运行输出日志
+//   - PostgreSQL 使用 tsvector 全文索引 + ts_headline 生成高亮；
+//     其他存储退化为子串匹配，高亮片段由 HighlightSnippet 生成，两者标记一致
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// 高亮标记（与 PostgreSQL ts_headline 的 StartSel/StopSel 配置一致）
+const (
+	SearchHighlightStart = "<mark>"
+	SearchHighlightStop  = "</mark>"
+)
+
+// searchSnippetWidth 高亮片段的默认长度（字符数）
+const searchSnippetWidth = 160
+
+// EventSearchHit 事件检索命中项
+type EventSearchHit struct {
+	EventID   int64     `json:"event_id"`       // 事件 ID
+	RunID     string    `json:"run_id"`         // 所属 Run ID
+	Seq       int       `json:"seq"`            // 事件序号
+	Type      string    `json:"type"`           // 事件类型
+	Timestamp time.Time `json:"timestamp"`      // 事件时间
+	Highlight string    `json:"highlight"`      // 命中片段，关键词以 <mark></mark> 包裹
+	Rank      float64   `json:"rank,omitempty"` // 相关度（仅全文索引提供）
+}
+
+// SearchTerms 将检索语句拆分为小写关键词（空白分隔，去重）
+func SearchTerms(query string) []string {
+	var terms []string
+	seen := make(map[string]bool)
+	for _, f := range strings.Fields(strings.ToLower(query)) {
+		if !seen[f] {
+			seen[f] = true
+			terms = append(terms, f)
+		}
+	}
+	return terms
+}
+
+// HighlightSnippet 截取 text 中首个命中关键词附近的片段，并以高亮标记包裹所有命中
+//
+// 匹配大小写不敏感；terms 应为 SearchTerms 的结果。未命中时返回开头片段。
+func HighlightSnippet(text string, terms []string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		lower = runes // 极少数字符小写后长度变化，退化为大小写敏感匹配
+	}
+	lowerTerms := make([][]rune, 0, len(terms))
+	for _, t := range terms {
+		if t != "" {
+			lowerTerms = append(lowerTerms, []rune(strings.ToLower(t)))
+		}
+	}
+
+	// matchAt 返回 i 处命中的关键词长度（取最长），未命中返回 0
+	matchAt := func(i int) int {
+		n := 0
+		for _, t := range lowerTerms {
+			if len(t) > n && i+len(t) <= len(lower) && string(lower[i:i+len(t)]) == string(t) {
+				n = len(t)
+			}
+		}
+		return n
+	}
+
+	first := -1
+	for i := range lower {
+		if matchAt(i) > 0 {
+			first = i
+			break
+		}
+	}
+
+	start := 0
+	if first > searchSnippetWidth/3 {
+		start = first - searchSnippetWidth/3
+	}
+	end := start + searchSnippetWidth
+	if end > len(runes) {
+		end = len(runes)
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	for i := start; i < end; {
+		if n := matchAt(i); n > 0 {
+			b.WriteString(SearchHighlightStart)
+			b.WriteString(string(runes[i : i+n]))
+			b.WriteString(SearchHighlightStop)
+			i += n
+			continue
+		}
+		b.WriteRune(runes[i])
+		i++
+	}
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
@@ -0,0 +1,126 @@
+// Package model 定义核心数据模型
+//
+// hook.go 包含 Run 生命周期钩子的数据模型定义：
+//   - HookPhase：钩子阶段枚举（pre_run / post_run / on_failure）
+//   - HookSpec：单个钩子脚本定义（内联脚本或引用 Skill）
+//   - LifecycleHooks：任务/模板上的钩子配置
+package model
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================================================
+// HookPhase - 钩子阶段枚举
+// ============================================================================
+
+// HookPhase 钩子执行阶段
+type HookPhase string
+
+const (
+	// HookPhasePreRun Agent 启动前执行，失败时中止 Run（Agent 不会启动）
+	HookPhasePreRun HookPhase = "pre_run"
+
+	// HookPhasePostRun Agent 成功结束后执行，失败时 Run 标记为失败
+	HookPhasePostRun HookPhase = "post_run"
+
+	// HookPhaseOnFailure Run 失败（含 pre_run/post_run 钩子失败）后执行，失败仅记录
+	HookPhaseOnFailure HookPhase = "on_failure"
+)
+
+// 钩子默认值
+const (
+	// DefaultHookTimeoutSeconds 单个钩子默认超时（秒）
+	DefaultHookTimeoutSeconds = 300
+
+	// DefaultHookShell 默认解释器
+	DefaultHookShell = "sh"
+)
+
+// ============================================================================
+// HookSpec - 钩子定义
+// ============================================================================
+
+// HookSpec 单个钩子脚本
+//
+// 脚本来源二选一：
+//   - Script：内联脚本
+//   - SkillID：引用 Skill，使用其 Instructions 作为脚本内容
+//
+// 创建 Run 时 API Server 会将 SkillID 解析为内联脚本写入快照，
+// NodeManager 只处理内联脚本。
+type HookSpec struct {
+	// Name 钩子名称（用于事件和日志展示）
+	Name string `json:"name"`
+
+	// Script 内联脚本内容
+	Script string `json:"script,omitempty"`
+
+	// SkillID 引用的 Skill ID（与 Script 二选一）
+	SkillID string `json:"skill_id,omitempty"`
+
+	// Shell 解释器，默认 sh
+	Shell string `json:"shell,omitempty"`
+
+	// TimeoutSeconds 超时时间（秒），默认 300
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+
+	// ContinueOnError 非零退出码时是否继续（不中止 Run）
+	ContinueOnError bool `json:"continue_on_error,omitempty"`
+}
+
+// ============================================================================
+// LifecycleHooks - 生命周期钩子配置
+// ============================================================================
+
+// LifecycleHooks Run 生命周期钩子配置
+//
+// 钩子在 Agent 所在容器内、工作目录下按顺序执行，
+// 输出以 hook_started / hook_completed 事件上报。
+type LifecycleHooks struct {
+	// PreRun Agent 启动前执行
+	PreRun []HookSpec `json:"pre_run,omitempty"`
+
+	// PostRun Agent 成功结束后执行
+	PostRun []HookSpec `json:"post_run,omitempty"`
+
+	// OnFailure Run 失败后执行
+	OnFailure []HookSpec `json:"on_failure,omitempty"`
+}
+
+// IsEmpty 判断是否未配置任何钩子
+func (h *LifecycleHooks) IsEmpty() bool {
+	return h == nil || (len(h.PreRun) == 0 && len(h.PostRun) == 0 && len(h.OnFailure) == 0)
+}
+
+// Phases 按阶段返回钩子列表
+func (h *LifecycleHooks) Phases() map[HookPhase][]HookSpec {
+	return map[HookPhase][]HookSpec{
+		HookPhasePreRun:    h.PreRun,
+		HookPhasePostRun:   h.PostRun,
+		HookPhaseOnFailure: h.OnFailure,
+	}
+}
+
+// Validate 校验钩子配置
+func (h *LifecycleHooks) Validate() error {
+	if h == nil {
+		return nil
+	}
+	for phase, hooks := range h.Phases() {
+		for i, hook := range hooks {
+			if strings.TrimSpace(hook.Name) == "" {
+				return fmt.Errorf("hooks.%s[%d]: name is required", phase, i)
+			}
+			hasScript := strings.TrimSpace(hook.Script) != ""
+			if hasScript == (hook.SkillID != "") {
+				return fmt.Errorf("hooks.%s[%d]: exactly one of script or skill_id is required", phase, i)
+			}
+			if hook.TimeoutSeconds < 0 {
+				return fmt.Errorf("hooks.%s[%d]: timeout_seconds must not be negative", phase, i)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ============================================================================
+// IdempotencyKey - 创建类请求的幂等键
+// ============================================================================
+
+// IdempotencyKey 携带 Idempotency-Key 请求头的创建请求记录
+//
+// 首次请求认领键（StatusCode 为 0 表示处理中），创建成功后记录创建的资源与原始响应；
+// 有效期内以相同键重试时直接返回原始响应，不再重复创建。处理失败时删除记录，客户端可以用同一个键重试。
+type IdempotencyKey struct {
+	// Scope 键的作用域（调用方与请求路径，如 user-1 POST /api/v1/tasks）
+	Scope string `json:"scope" bson:"scope" db:"scope"`
+
+	// Key 客户端提供的幂等键
+	Key string `json:"key" bson:"key" db:"idem_key"`
+
+	// RequestHash 请求体摘要，同一个键用于不同请求时拒绝
+	RequestHash string `json:"request_hash" bson:"request_hash" db:"request_hash"`
+
+	// ResourceID 创建的资源 ID（处理中时为空）
+	ResourceID string `json:"resource_id,omitempty" bson:"resource_id,omitempty" db:"resource_id"`
+
+	// StatusCode 原始响应状态码（0 表示处理中）
+	StatusCode int `json:"status_code" bson:"status_code" db:"status_code"`
+
+	// Response 原始响应体
+	Response json.RawMessage `json:"response,omitempty" bson:"response,omitempty" db:"response"`
+
+	// CreatedAt 认领时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+
+	// ExpiresAt 过期时间，过期后同一个键视为新请求
+	ExpiresAt time.Time `json:"expires_at" bson:"expires_at" db:"expires_at"`
+}
+
+// Completed 是否已记录原始响应
+func (k *IdempotencyKey) Completed() bool {
+	return k.StatusCode != 0
+}
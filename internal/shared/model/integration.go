@@ -0,0 +1,119 @@
+// Package model 定义核心数据模型
+//
+// integration.go 包含外部 Issue 集成相关的数据模型定义：
+//   - Integration：接入的 GitHub 仓库或 Jira 项目
+//   - IntegrationProvider：集成平台枚举
+//   - IssueLink：导入任务与源 Issue 的反向关联
+//
+// 选中的 Issue 通过 POST /api/v1/integrations/import 映射为 Task
+// （标题 → name，正文 → prompt，标签 → labels），并记录 IssueLink；
+// 开启 CommentResults 时，Run 结束后将结果以评论形式回写到源 Issue。
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// IntegrationProvider - 集成平台枚举
+// ============================================================================
+
+// IntegrationProvider 集成平台
+type IntegrationProvider string
+
+const (
+	// IntegrationGitHub GitHub（含 GitHub Enterprise）仓库 Issue
+	IntegrationGitHub IntegrationProvider = "github"
+
+	// IntegrationJira Jira 项目 Issue
+	IntegrationJira IntegrationProvider = "jira"
+)
+
+// IsValid 检查集成平台是否合法
+func (p IntegrationProvider) IsValid() bool {
+	return p == IntegrationGitHub || p == IntegrationJira
+}
+
+// ============================================================================
+// Integration - 集成
+// ============================================================================
+
+// githubRepoPattern GitHub 仓库格式 owner/repo
+var githubRepoPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+/[A-Za-z0-9_.-]+$`)
+
+// jiraProjectPattern Jira 项目 Key（大写字母开头）
+var jiraProjectPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+$`)
+
+// Integration 外部 Issue 集成
+//
+// 字段说明：
+//   - Provider=github：Repo 为 owner/repo，BaseURL 为空时使用 https://api.github.com
+//     （GitHub Enterprise 填写 https://{host}/api/v3）
+//   - Provider=jira：BaseURL 为站点地址（如 https://example.atlassian.net），ProjectKey 为项目 Key
+//   - CredentialRef：引用 /api/v1/credentials 中的 Token；
+//     Jira 凭据填写了 Username 时使用 Basic 认证（邮箱 + API Token），否则使用 Bearer（个人访问令牌）
+type Integration struct {
+	ID             string              `json:"id" bson:"_id" db:"id"`
+	Name           string              `json:"name" bson:"name" db:"name"`
+	Provider       IntegrationProvider `json:"provider" bson:"provider" db:"provider"`
+	BaseURL        string              `json:"base_url,omitempty" bson:"base_url,omitempty" db:"base_url"`
+	Repo           string              `json:"repo,omitempty" bson:"repo,omitempty" db:"repo"`
+	ProjectKey     string              `json:"project_key,omitempty" bson:"project_key,omitempty" db:"project_key"`
+	CredentialRef  string              `json:"credential_ref,omitempty" bson:"credential_ref,omitempty" db:"credential_ref"`
+	CommentResults bool                `json:"comment_results" bson:"comment_results" db:"comment_results"`
+	CreatedAt      time.Time           `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt      time.Time           `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// Validate 校验集成配置
+func (i *Integration) Validate() error {
+	if strings.TrimSpace(i.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch i.Provider {
+	case IntegrationGitHub:
+		if !githubRepoPattern.MatchString(i.Repo) {
+			return fmt.Errorf("repo must be in owner/repo format")
+		}
+	case IntegrationJira:
+		if i.BaseURL == "" {
+			return fmt.Errorf("base_url is required for jira")
+		}
+		if !jiraProjectPattern.MatchString(i.ProjectKey) {
+			return fmt.Errorf("invalid project_key: %q", i.ProjectKey)
+		}
+	default:
+		return fmt.Errorf("invalid provider: must be github or jira")
+	}
+	if i.BaseURL != "" && !strings.HasPrefix(i.BaseURL, "https://") && !strings.HasPrefix(i.BaseURL, "http://") {
+		return fmt.Errorf("base_url must start with http:// or https://")
+	}
+	return nil
+}
+
+// ============================================================================
+// IssueLink - 任务与源 Issue 的关联
+// ============================================================================
+
+// IssueLink 导入任务的反向关联
+//
+// 每个任务最多关联一个 Issue；同一集成下的同一 Issue 只能导入一次。
+type IssueLink struct {
+	// TaskID 导入生成的任务 ID
+	TaskID string `json:"task_id" bson:"_id" db:"task_id"`
+
+	// IntegrationID 来源集成 ID
+	IntegrationID string `json:"integration_id" bson:"integration_id" db:"integration_id"`
+
+	// IssueKey Issue 标识（GitHub 为编号，如 "42"；Jira 为 Key，如 "PROJ-12"）
+	IssueKey string `json:"issue_key" bson:"issue_key" db:"issue_key"`
+
+	// URL Issue 网页地址
+	URL string `json:"url" bson:"url" db:"url"`
+
+	// CreatedAt 导入时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+}
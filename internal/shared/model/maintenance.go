@@ -0,0 +1,53 @@
+// Package model 定义核心数据模型
+//
+// maintenance.go 包含存储维护相关的数据模型定义：
+//   - MaintenanceRun：一次存储维护（VACUUM / incremental_vacuum / compact）的执行记录
+//   - MaintenanceTable：单个表（集合）的估算与执行结果
+package model
+
+import "time"
+
+// MaintenanceStatus 存储维护执行状态
+type MaintenanceStatus string
+
+const (
+	MaintenanceStatusRunning MaintenanceStatus = "running"
+	MaintenanceStatusSuccess MaintenanceStatus = "success"
+	MaintenanceStatusPartial MaintenanceStatus = "partial" // 部分表失败
+	MaintenanceStatusFailed  MaintenanceStatus = "failed"
+)
+
+// MaintenanceTrigger 存储维护触发方式
+type MaintenanceTrigger string
+
+const (
+	MaintenanceTriggerScheduled MaintenanceTrigger = "scheduled"
+	MaintenanceTriggerManual    MaintenanceTrigger = "manual"
+)
+
+// MaintenanceTable 单个表（MongoDB 为集合）的维护结果
+type MaintenanceTable struct {
+	Table            string `json:"table" bson:"table"`
+	SizeBytes        int64  `json:"size_bytes" bson:"size_bytes"`                                 // 执行前占用（含索引）
+	ReclaimableBytes int64  `json:"reclaimable_bytes" bson:"reclaimable_bytes"`                   // 估算可回收空间
+	DeadRows         int64  `json:"dead_rows,omitempty" bson:"dead_rows,omitempty"`               // 死元组数（仅 PostgreSQL）
+	SizeAfterBytes   *int64 `json:"size_after_bytes,omitempty" bson:"size_after_bytes,omitempty"` // 执行后占用（dry-run 时为空）
+	DurationMS       int64  `json:"duration_ms,omitempty" bson:"duration_ms,omitempty"`
+	Error            string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// MaintenanceRun 存储维护执行记录
+type MaintenanceRun struct {
+	ID               string             `json:"id" bson:"_id" db:"id"`
+	Driver           string             `json:"driver" bson:"driver" db:"driver"`
+	Trigger          MaintenanceTrigger `json:"trigger" bson:"trigger" db:"trigger_type"`
+	DryRun           bool               `json:"dry_run" bson:"dry_run" db:"dry_run"`
+	Status           MaintenanceStatus  `json:"status" bson:"status" db:"status"`
+	Tables           []MaintenanceTable `json:"tables" bson:"tables" db:"tables"`
+	ReclaimableBytes int64              `json:"reclaimable_bytes" bson:"reclaimable_bytes" db:"reclaimable_bytes"` // 执行前估算可回收空间合计
+	ReclaimedBytes   int64              `json:"reclaimed_bytes" bson:"reclaimed_bytes" db:"reclaimed_bytes"`       // 实际回收空间合计（执行前后占用之差）
+	Error            string             `json:"error,omitempty" bson:"error,omitempty" db:"error"`                 // 驱动级错误（如驱动不支持）
+	CreatedBy        string             `json:"created_by,omitempty" bson:"created_by,omitempty" db:"created_by"`
+	StartedAt        time.Time          `json:"started_at" bson:"started_at" db:"started_at"`
+	FinishedAt       *time.Time         `json:"finished_at,omitempty" bson:"finished_at,omitempty" db:"finished_at"`
+}
@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -306,3 +307,26 @@ func TestAuthTaskJSONSerialization(t *testing.T) {
 		t.Errorf("UserCode = %v, want %v", *decoded.UserCode, *task.UserCode)
 	}
 }
+
+func TestHighlightSnippet(t *testing.T) {
+	terms := SearchTerms("  Timeout ERROR timeout ")
+	if len(terms) != 2 || terms[0] != "timeout" || terms[1] != "error" {
+		t.Fatalf("SearchTerms = %v", terms)
+	}
+
+	got := HighlightSnippet("npm install: Error: connect ETIMEDOUT, request timeout", terms)
+	want := "npm install: <mark>Error</mark>: connect ETIMEDOUT, request <mark>timeout</mark>"
+	if got != want {
+		t.Errorf("HighlightSnippet = %q, want %q", got, want)
+	}
+
+	// 长文本截取命中位置附近的片段
+	long := strings.Repeat("日志", 200) + "构建失败 error" + strings.Repeat("x", 400)
+	got = HighlightSnippet(long, []string{"error"})
+	if !strings.HasPrefix(got, "…") || !strings.HasSuffix(got, "…") || !strings.Contains(got, "<mark>error</mark>") {
+		t.Errorf("长文本片段 = %q", got)
+	}
+	if n := len([]rune(got)); n > searchSnippetWidth+len("<mark></mark>")+2 {
+		t.Errorf("片段过长: %d", n)
+	}
+}
@@ -0,0 +1,76 @@
+// Package model 定义核心数据模型
+//
+// moderation.go 包含 Agent 输出内容审核相关的数据模型定义：
+//   - ModerationSeverity：违规严重级别
+//   - RunFlag：审核命中后记录在 Run 上的标记
+//   - EventOriginal：被掩码事件的原文（启用保留原文时）
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ModerationSeverity 内容审核命中的严重级别
+type ModerationSeverity string
+
+const (
+	ModerationSeverityLow      ModerationSeverity = "low"
+	ModerationSeverityMedium   ModerationSeverity = "medium"
+	ModerationSeverityHigh     ModerationSeverity = "high"
+	ModerationSeverityCritical ModerationSeverity = "critical"
+)
+
+// Rank 严重级别的序号（low=1 … critical=4），非法值为 0
+func (s ModerationSeverity) Rank() int {
+	switch s {
+	case ModerationSeverityLow:
+		return 1
+	case ModerationSeverityMedium:
+		return 2
+	case ModerationSeverityHigh:
+		return 3
+	case ModerationSeverityCritical:
+		return 4
+	}
+	return 0
+}
+
+// IsValid 是否为合法的严重级别
+func (s ModerationSeverity) IsValid() bool {
+	return s.Rank() > 0
+}
+
+// RunFlag 内容审核在 Run 上留下的标记
+//
+// 每个事件中每个命中的检测器记录一条；事件中的命中内容已被掩码替换，原文只在启用保留原文时另存（见 EventOriginal）。
+//
+// 字段说明：
+//   - ID：自增主键
+//   - RunID：所属 Run ID
+//   - EventSeq：命中的事件序号
+//   - Detector：检测器名称（如 email、credit_card 或自定义规则名）
+//   - Severity：严重级别
+//   - Count：该事件中的命中次数
+//   - Aborted：是否因此终止了 Run
+type RunFlag struct {
+	ID        int64              `json:"id" bson:"_id" db:"id"`
+	RunID     string             `json:"run_id" bson:"run_id" db:"run_id"`
+	EventSeq  int                `json:"event_seq" bson:"event_seq" db:"event_seq"`
+	Detector  string             `json:"detector" bson:"detector" db:"detector"`
+	Severity  ModerationSeverity `json:"severity" bson:"severity" db:"severity"`
+	Count     int                `json:"count" bson:"count" db:"count"`
+	Aborted   bool               `json:"aborted,omitempty" bson:"aborted,omitempty" db:"aborted"`
+	CreatedAt time.Time          `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+// EventOriginal 被掩码事件掩码前的载荷与原始输出
+//
+// 仅在内容审核启用 retain_originals 时写入，只有管理员可以查看；随 Run 删除或事件归档一起删除。
+type EventOriginal struct {
+	RunID     string          `json:"run_id" bson:"run_id" db:"run_id"`
+	Seq       int             `json:"seq" bson:"seq" db:"seq"`
+	Payload   json.RawMessage `json:"payload,omitempty" bson:"payload,omitempty" db:"payload"`
+	Raw       *string         `json:"raw,omitempty" bson:"raw,omitempty" db:"raw"`
+	CreatedAt time.Time       `json:"created_at" bson:"created_at" db:"created_at"`
+}
@@ -3,10 +3,12 @@
 // node.go 包含计算节点相关的数据模型定义：
 //   - Node：执行任务的计算节点
 //   - NodeStatus：节点状态枚举
+//   - NodeTaint：节点污点
 package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"time"
 )
 
@@ -77,6 +79,7 @@ const (
 //   - Status：节点当前状态
 //   - Labels：节点标签（用于调度匹配，如 os=linux, gpu=true）
 //   - Capacity：节点容量（如 max_concurrent=4）
+//   - Taints：节点污点（管理员设置，心跳不覆盖）
 //   - LastHeartbeat：最后心跳时间（用于判断节点是否在线）
 type Node struct {
 	ID            string          `json:"id" bson:"_id" db:"id"`                                                        // 节点 ID
@@ -86,11 +89,48 @@ type Node struct {
 	IPs           string          `json:"ips,omitempty" bson:"ips,omitempty" db:"ips"`                                  // IP 地址列表（逗号分隔）
 	Labels        json.RawMessage `json:"labels" bson:"labels" db:"labels"`                                             // 节点标签
 	Capacity      json.RawMessage `json:"capacity" bson:"capacity" db:"capacity"`                                       // 节点容量
+	Taints        []NodeTaint     `json:"taints,omitempty" bson:"taints,omitempty" db:"taints"`                         // 节点污点
 	LastHeartbeat *time.Time      `json:"last_heartbeat,omitempty" bson:"last_heartbeat,omitempty" db:"last_heartbeat"` // 最后心跳
 	CreatedAt     time.Time       `json:"created_at" bson:"created_at" db:"created_at"`                                 // 创建时间
 	UpdatedAt     time.Time       `json:"updated_at" bson:"updated_at" db:"updated_at"`                                 // 更新时间
 }
 
+// ============================================================================
+// NodeTaint - 节点污点
+// ============================================================================
+
+// TaintEffectNoSchedule 不调度：不容忍该污点的任务不会被调度到节点（已分配的 Run 不受影响）
+const TaintEffectNoSchedule = "NoSchedule"
+
+// NodeTaint 节点污点
+//
+// 用于为特定任务预留节点（如 dedicated=ml:NoSchedule），只有声明了对应容忍
+// （TaskScheduling.Tolerations）的任务才会被调度到带污点的节点。
+type NodeTaint struct {
+	Key    string `json:"key" bson:"key"`
+	Value  string `json:"value,omitempty" bson:"value,omitempty"`
+	Effect string `json:"effect" bson:"effect"`
+}
+
+// Validate 校验污点
+func (t NodeTaint) Validate() error {
+	if t.Key == "" {
+		return fmt.Errorf("taint key is required")
+	}
+	if t.Effect != TaintEffectNoSchedule {
+		return fmt.Errorf("taint %s: effect must be %s", t.Key, TaintEffectNoSchedule)
+	}
+	return nil
+}
+
+// String 返回 key=value:effect 形式
+func (t NodeTaint) String() string {
+	if t.Value == "" {
+		return t.Key + ":" + t.Effect
+	}
+	return t.Key + "=" + t.Value + ":" + t.Effect
+}
+
 // ============================================================================
 // 辅助方法
 // ============================================================================
@@ -122,3 +162,34 @@ func (n *Node) IsAdminStatus() bool {
 		return false
 	}
 }
+
+// ============================================================================
+// 执行后端
+// ============================================================================
+
+// 执行后端名称
+const (
+	// ExecBackendDocker 在 Agent 实例容器内执行（docker exec，默认）
+	ExecBackendDocker = "docker"
+
+	// ExecBackendProcess 在节点宿主机上以沙箱用户直接执行（适用于无法运行 Docker 的节点）
+	ExecBackendProcess = "process"
+)
+
+// LabelExecBackend 执行后端标签
+//
+// 节点以该标签声明默认执行后端；任务设置该标签时既约束调度到同值节点，
+// 也写入执行快照 runtime.backend，指定 NodeManager 使用的后端。
+const LabelExecBackend = "exec-backend"
+
+// 适配器能力要求标签
+//
+// 任务设置后调度器只选择心跳上报的对应适配器满足要求的节点
+// （未上报适配器信息的旧版本节点不受约束）；这两个标签不参与节点标签匹配。
+const (
+	// LabelAdapterFeatures 必须支持的适配器能力（逗号分隔，如 "streaming,usage"）
+	LabelAdapterFeatures = "adapter-features"
+
+	// LabelAdapterMinVersion CLI 最低版本（如 "1.0.30"）
+	LabelAdapterMinVersion = "adapter-min-version"
+)
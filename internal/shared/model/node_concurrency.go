@@ -0,0 +1,66 @@
+package model
+
+// DefaultNodeMaxConcurrent 节点未配置时的最大并发 Run 数
+const DefaultNodeMaxConcurrent = 2
+
+// NodeConcurrency 节点执行并发配置（nodemanager.yaml node.concurrency 与控制面覆盖合并后的生效值）
+type NodeConcurrency struct {
+	MaxConcurrent        int            `json:"max_concurrent"`                   // 最大并发 Run 数
+	ReservedHighPriority int            `json:"reserved_high_priority,omitempty"` // 只允许 high 优先级 Run 使用的槽位数（包含在 max_concurrent 内）
+	AgentTypeLimits      map[string]int `json:"agent_type_limits,omitempty"`      // Agent 类型 → 并发上限
+}
+
+// NodeConcurrencyOverride 控制面对节点并发配置的覆盖（PATCH /api/v1/nodes/{id}/capacity）
+//
+// 未设置的字段沿用节点本地配置，AgentTypeLimits 按 Agent 类型逐项覆盖。
+type NodeConcurrencyOverride struct {
+	MaxConcurrent        *int           `json:"max_concurrent,omitempty" bson:"max_concurrent,omitempty"`
+	ReservedHighPriority *int           `json:"reserved_high_priority,omitempty" bson:"reserved_high_priority,omitempty"`
+	AgentTypeLimits      map[string]int `json:"agent_type_limits,omitempty" bson:"agent_type_limits,omitempty"`
+}
+
+// IsEmpty 是否没有任何覆盖项
+func (o *NodeConcurrencyOverride) IsEmpty() bool {
+	return o == nil || (o.MaxConcurrent == nil && o.ReservedHighPriority == nil && len(o.AgentTypeLimits) == 0)
+}
+
+// Apply 返回应用覆盖后的并发配置（o 为 nil 时原样返回）
+func (c NodeConcurrency) Apply(o *NodeConcurrencyOverride) NodeConcurrency {
+	out := c
+	if len(c.AgentTypeLimits) > 0 {
+		out.AgentTypeLimits = make(map[string]int, len(c.AgentTypeLimits))
+		for k, v := range c.AgentTypeLimits {
+			out.AgentTypeLimits[k] = v
+		}
+	}
+	if o == nil {
+		return out
+	}
+	if o.MaxConcurrent != nil {
+		out.MaxConcurrent = *o.MaxConcurrent
+	}
+	if o.ReservedHighPriority != nil {
+		out.ReservedHighPriority = *o.ReservedHighPriority
+	}
+	for k, v := range o.AgentTypeLimits {
+		if out.AgentTypeLimits == nil {
+			out.AgentTypeLimits = make(map[string]int, len(o.AgentTypeLimits))
+		}
+		out.AgentTypeLimits[k] = v
+	}
+	return out
+}
+
+// Normalize 填充默认值并修正越界的预留槽位（预留槽位至少为普通 Run 留出一个槽位）
+func (c NodeConcurrency) Normalize() NodeConcurrency {
+	if c.MaxConcurrent <= 0 {
+		c.MaxConcurrent = DefaultNodeMaxConcurrent
+	}
+	if c.ReservedHighPriority < 0 {
+		c.ReservedHighPriority = 0
+	}
+	if c.ReservedHighPriority >= c.MaxConcurrent {
+		c.ReservedHighPriority = c.MaxConcurrent - 1
+	}
+	return c
+}
@@ -0,0 +1,67 @@
+// Package model 定义核心数据模型
+//
+// node_join.go 包含节点自动注册相关的数据模型定义：
+//   - NodeJoinToken：管理员生成的加入（注册）令牌
+//   - NodeJoinTokenUse：加入令牌的使用记录（审计）
+//   - NodeCredential：节点兑换加入令牌后获得的专属凭证
+package model
+
+import "time"
+
+// NodeJoinToken 节点加入令牌（短期有效、限定使用次数，只保存哈希）
+//
+// 通过该令牌加入的节点继承令牌上预设的标签与节点池。
+type NodeJoinToken struct {
+	ID          string            `json:"id" bson:"_id" db:"id"`
+	TokenHash   string            `json:"-" bson:"token_hash" db:"token_hash"` // SHA-256(token) 十六进制
+	Description string            `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+	CreatedBy   string            `json:"created_by,omitempty" bson:"created_by,omitempty" db:"created_by"`
+	MaxUses     int               `json:"max_uses" bson:"max_uses" db:"max_uses"`    // 最多可加入的节点数（默认 1，即一次性）
+	UseCount    int               `json:"use_count" bson:"use_count" db:"use_count"` // 已使用次数
+	Labels      map[string]string `json:"labels,omitempty" bson:"labels,omitempty" db:"labels"`
+	Pool        string            `json:"pool,omitempty" bson:"pool,omitempty" db:"pool"` // 节点池（以标签 pool=<pool> 参与调度）
+	ExpiresAt   time.Time         `json:"expires_at" bson:"expires_at" db:"expires_at"`
+	UsedAt      *time.Time        `json:"used_at,omitempty" bson:"used_at,omitempty" db:"used_at"`                // 最近一次使用时间
+	UsedByNode  *string           `json:"used_by_node,omitempty" bson:"used_by_node,omitempty" db:"used_by_node"` // 最近一次使用的节点
+	CreatedAt   time.Time         `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+// NodePoolLabel 节点池对应的节点标签键
+const NodePoolLabel = "pool"
+
+// EnrollLabels 返回加入的节点应继承的标签（令牌标签 + 节点池）
+func (t *NodeJoinToken) EnrollLabels() map[string]string {
+	if len(t.Labels) == 0 && t.Pool == "" {
+		return nil
+	}
+	labels := make(map[string]string, len(t.Labels)+1)
+	for k, v := range t.Labels {
+		labels[k] = v
+	}
+	if t.Pool != "" {
+		labels[NodePoolLabel] = t.Pool
+	}
+	return labels
+}
+
+// NodeJoinTokenUse 加入令牌使用记录
+type NodeJoinTokenUse struct {
+	ID         string    `json:"id" bson:"_id" db:"id"`
+	TokenID    string    `json:"token_id" bson:"token_id" db:"token_id"`
+	NodeID     string    `json:"node_id" bson:"node_id" db:"node_id"`
+	Hostname   string    `json:"hostname,omitempty" bson:"hostname,omitempty" db:"hostname"`
+	RemoteAddr string    `json:"remote_addr,omitempty" bson:"remote_addr,omitempty" db:"remote_addr"`
+	UsedAt     time.Time `json:"used_at" bson:"used_at" db:"used_at"`
+}
+
+// NodeCredential 节点专属凭证（每个节点一条，重新加入时覆盖）
+type NodeCredential struct {
+	NodeID        string     `json:"node_id" bson:"_id" db:"node_id"`
+	TokenHash     string     `json:"-" bson:"token_hash" db:"token_hash"`                                 // SHA-256(token) 十六进制
+	CertSerial    string     `json:"cert_serial,omitempty" bson:"cert_serial,omitempty" db:"cert_serial"` // 客户端证书序列号（十六进制），未签发时为空
+	CertExpiresAt *time.Time `json:"cert_expires_at,omitempty" bson:"cert_expires_at,omitempty" db:"cert_expires_at"`
+	JoinTokenID   string     `json:"join_token_id,omitempty" bson:"join_token_id,omitempty" db:"join_token_id"`
+	// Labels 从加入令牌继承的标签，心跳时覆盖节点上报的同名标签
+	Labels    map[string]string `json:"labels,omitempty" bson:"labels,omitempty" db:"labels"`
+	CreatedAt time.Time         `json:"created_at" bson:"created_at" db:"created_at"`
+}
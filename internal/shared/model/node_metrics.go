@@ -0,0 +1,66 @@
+package model
+
+import "time"
+
+// NodeMetrics 节点系统资源指标（节点心跳 capacity.metrics 上报）
+type NodeMetrics struct {
+	CPUPercent       float64   `json:"cpu_percent"`       // 两次采集间的 CPU 使用率（0-100）
+	MemoryUsedBytes  int64     `json:"memory_used_bytes"` // 已用内存（MemTotal - MemAvailable）
+	MemoryTotalBytes int64     `json:"memory_total_bytes"`
+	MemoryPercent    float64   `json:"memory_percent"`
+	DiskUsedBytes    int64     `json:"disk_used_bytes"` // 工作空间所在文件系统
+	DiskTotalBytes   int64     `json:"disk_total_bytes"`
+	DiskPercent      float64   `json:"disk_percent"`
+	Load1            float64   `json:"load1"`
+	Load5            float64   `json:"load5"`
+	Load15           float64   `json:"load15"`
+	CPUs             int       `json:"cpus"`
+	CollectedAt      time.Time `json:"collected_at"`
+}
+
+// Utilization 节点综合资源利用率（0-100）：CPU、内存与每核 1 分钟负载中的最大值
+func (m *NodeMetrics) Utilization() float64 {
+	u := m.CPUPercent
+	if m.MemoryPercent > u {
+		u = m.MemoryPercent
+	}
+	if m.CPUs > 0 {
+		if load := m.Load1 / float64(m.CPUs) * 100; load > u {
+			u = load
+		}
+	}
+	if u > 100 {
+		u = 100
+	}
+	return u
+}
+
+// AverageNodeMetrics 计算一组采样的平均值（CollectedAt 取最新一次采样），为空时返回 nil
+func AverageNodeMetrics(samples []*NodeMetrics) *NodeMetrics {
+	if len(samples) == 0 {
+		return nil
+	}
+	avg := &NodeMetrics{}
+	for _, s := range samples {
+		avg.CPUPercent += s.CPUPercent
+		avg.MemoryPercent += s.MemoryPercent
+		avg.DiskPercent += s.DiskPercent
+		avg.Load1 += s.Load1
+		avg.Load5 += s.Load5
+		avg.Load15 += s.Load15
+		if s.CollectedAt.After(avg.CollectedAt) {
+			avg.CollectedAt = s.CollectedAt
+			avg.MemoryUsedBytes, avg.MemoryTotalBytes = s.MemoryUsedBytes, s.MemoryTotalBytes
+			avg.DiskUsedBytes, avg.DiskTotalBytes = s.DiskUsedBytes, s.DiskTotalBytes
+			avg.CPUs = s.CPUs
+		}
+	}
+	n := float64(len(samples))
+	avg.CPUPercent /= n
+	avg.MemoryPercent /= n
+	avg.DiskPercent /= n
+	avg.Load1 /= n
+	avg.Load5 /= n
+	avg.Load15 /= n
+	return avg
+}
@@ -0,0 +1,14 @@
+package model
+
+// NodeSpoolStatus 节点本地缓存深度（节点心跳 capacity.spool 上报）
+//
+// API Server 不可达时，节点将事件、Run 状态与产物写入本地缓存，恢复连通后按顺序回放；
+// 缓存持续不为空说明节点与 API Server 之间的上报受阻。
+type NodeSpoolStatus struct {
+	Runs     int   `json:"runs"`      // 有待回放记录的 Run 数
+	Records  int   `json:"records"`   // 待回放的记录数（事件、状态与产物）
+	Segments int   `json:"segments"`  // 分段文件数
+	Bytes    int64 `json:"bytes"`     // 缓存占用字节数
+	MaxBytes int64 `json:"max_bytes"` // 缓存总大小上限（0 表示不限制）
+	Dropped  int64 `json:"dropped"`   // 因超过上限丢弃的事件数（节点启动以来累计）
+}
@@ -0,0 +1,122 @@
+package model
+
+import "time"
+
+// ============================================================================
+// 节点期望状态（声明式同步）
+// ============================================================================
+//
+// API Server 按节点计算期望状态文档（实例、认证会话、终端），Node Manager 定期拉取并对齐本地容器，
+// 再上报观测到的状态。API Server 只在记录仍处于节点据以行动的状态时应用观测结果（条件更新），
+// 节点处理期间用户发起的启动/停止/关闭不会被节点的迟到上报覆盖。
+
+// 实例期望状态
+const (
+	DesiredRunning = "running" // 容器应存在且运行中（不存在时创建）
+	DesiredStopped = "stopped" // 容器应停止（不存在视为已停止）
+)
+
+// NodeDesiredState 节点期望状态文档
+type NodeDesiredState struct {
+	NodeID       string            `json:"node_id"`
+	Revision     string            `json:"revision"` // 文档内容摘要，内容不变时不变
+	Instances    []DesiredInstance `json:"instances"`
+	AuthSessions []*Action         `json:"auth_sessions"` // 分配给节点、尚未结束的认证 Action（含 Operation）
+	Terminals    []DesiredTerminal `json:"terminals"`     // 应处于打开状态的终端会话（按创建时间升序，节点只保留最新的一个）
+	GeneratedAt  time.Time         `json:"generated_at"`
+}
+
+// DesiredInstance 实例期望状态
+//
+// 节点上的全部实例都会列出（包括 error 状态），不在文档中的实例容器视为已删除。
+type DesiredInstance struct {
+	ID            string         `json:"id"`
+	Name          string         `json:"name"`
+	AccountID     string         `json:"account_id"`
+	AgentTypeID   string         `json:"agent_type_id"`
+	ContainerName string         `json:"container_name,omitempty"`
+	Status        InstanceStatus `json:"status"` // 当前记录的状态，观测结果据此条件更新
+	State         string         `json:"state"`  // running / stopped；为空表示保持现状（error 状态等待用户重新启动）
+}
+
+// DesiredTerminal 终端会话期望状态
+type DesiredTerminal struct {
+	ID            string                `json:"id"`
+	InstanceID    *string               `json:"instance_id,omitempty"`
+	ContainerName string                `json:"container_name"`
+	Status        TerminalSessionStatus `json:"status"`
+	CreatedAt     time.Time             `json:"created_at"`
+}
+
+// DesiredInstanceState 实例状态对应的期望状态
+func DesiredInstanceState(status InstanceStatus) string {
+	switch status {
+	case InstanceStatusPending, InstanceStatusCreating, InstanceStatusRunning:
+		return DesiredRunning
+	case InstanceStatusStopping, InstanceStatusStopped:
+		return DesiredStopped
+	default:
+		return ""
+	}
+}
+
+// 节点观测到的实例容器状态
+const (
+	ObservedRunning = "running"
+	ObservedStopped = "stopped" // 容器存在但未运行，或不存在
+	ObservedError   = "error"   // 创建/启动失败
+)
+
+// NodeObservedState 节点上报的观测状态
+type NodeObservedState struct {
+	Revision  string             `json:"revision"` // 节点据以行动的期望状态文档版本
+	Instances []ObservedInstance `json:"instances,omitempty"`
+	Terminals []ObservedTerminal `json:"terminals,omitempty"`
+}
+
+// ObservedInstance 实例观测结果
+type ObservedInstance struct {
+	ID            string         `json:"id"`
+	Status        InstanceStatus `json:"status"` // 节点行动时文档中的状态（条件更新的前提）
+	State         string         `json:"state"`  // running / stopped / error
+	ContainerName string         `json:"container_name,omitempty"`
+	Error         string         `json:"error,omitempty"`
+}
+
+// ObservedTerminal 终端会话观测结果
+type ObservedTerminal struct {
+	ID     string                `json:"id"`
+	Status TerminalSessionStatus `json:"status"` // 节点行动时文档中的状态
+	State  TerminalSessionStatus `json:"state"`  // running / closed（被更新的会话取代）/ error
+	Port   *int                  `json:"port,omitempty"`
+	URL    *string               `json:"url,omitempty"`
+	Error  string                `json:"error,omitempty"`
+}
+
+// ObservedInstanceStatus 根据实例当前状态与节点观测结果计算新的实例状态，无需变更时返回 false
+//
+// 期望运行：观测到运行中为 running，创建/启动失败为 error；期望停止：观测到未运行为 stopped。
+// 观测结果与期望不一致（节点尚未完成对齐）时保持当前状态。
+func ObservedInstanceStatus(current InstanceStatus, observed string) (InstanceStatus, bool) {
+	var next InstanceStatus
+	switch DesiredInstanceState(current) {
+	case DesiredRunning:
+		switch observed {
+		case ObservedRunning:
+			next = InstanceStatusRunning
+		case ObservedError:
+			next = InstanceStatusError
+		}
+	case DesiredStopped:
+		switch observed {
+		case ObservedStopped:
+			next = InstanceStatusStopped
+		case ObservedError:
+			next = InstanceStatusError
+		}
+	}
+	if next == "" || next == current {
+		return current, false
+	}
+	return next, true
+}
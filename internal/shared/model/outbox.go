@@ -0,0 +1,52 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ============================================================================
+// OutboxMessage - 事务发件箱
+// ============================================================================
+
+// OutboxTopicScheduleRun 将新建的 Run 加入 Redis 调度队列
+const OutboxTopicScheduleRun = "run.schedule"
+
+// OutboxMessage 待发布到消息队列的消息
+//
+// 与业务数据在同一事务中写入（如 Run 与其调度消息），由 API Server 的发件箱中继投递到 Redis：
+//   - 中继按 AvailableAt 认领消息，认领时 AvailableAt 推迟一个租期，多实例不会重复投递
+//   - 投递失败时记录 LastError 并按 Attempts 指数退避
+//   - 投递成功后设置 PublishedAt，保留一段时间后清理
+type OutboxMessage struct {
+	// ID 唯一标识
+	ID string `json:"id" bson:"_id" db:"id"`
+
+	// Topic 消息类型（如 run.schedule），中继按类型选择投递方式
+	Topic string `json:"topic" bson:"topic" db:"topic"`
+
+	// Payload 消息内容（JSON）
+	Payload json.RawMessage `json:"payload" bson:"payload" db:"payload"`
+
+	// Attempts 已尝试投递次数
+	Attempts int `json:"attempts" bson:"attempts" db:"attempts"`
+
+	// LastError 最近一次投递失败的原因
+	LastError string `json:"last_error,omitempty" bson:"last_error,omitempty" db:"last_error"`
+
+	// AvailableAt 可被认领的时间
+	AvailableAt time.Time `json:"available_at" bson:"available_at" db:"available_at"`
+
+	// PublishedAt 投递成功时间（未投递时为空）
+	PublishedAt *time.Time `json:"published_at,omitempty" bson:"published_at,omitempty" db:"published_at"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+// ScheduleRunPayload run.schedule 消息内容
+type ScheduleRunPayload struct {
+	RunID    string `json:"run_id"`
+	TaskID   string `json:"task_id"`
+	Priority string `json:"priority,omitempty"`
+}
@@ -0,0 +1,287 @@
+// Package model 定义核心数据模型
+//
+// project.go 包含项目相关的数据模型定义：
+//   - Project：项目（任务的默认 Agent 类型、账号池、安全策略）
+//   - OverrideRule：任务能否覆盖项目默认值的规则
+//   - ResultPublishConfig：Run 结果发布到触发任务的 PR/MR 的配置
+//   - RetentionPolicy：项目 Run 事件的保留策略（覆盖全局 lifecycle 配置）
+//
+// 创建任务时若指定 project_id，未填写的 Agent 类型/安全配置从项目继承，
+// 显式填写的值需通过项目白名单校验，并受管理员配置的覆盖规则约束。
+package model
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ============================================================================
+// OverrideRule - 覆盖规则
+// ============================================================================
+
+// OverrideRule 任务能否覆盖项目默认值
+type OverrideRule string
+
+const (
+	// OverrideAllow 允许覆盖（默认）
+	OverrideAllow OverrideRule = "allow"
+
+	// OverrideDeny 禁止覆盖，任务必须使用项目默认值
+	OverrideDeny OverrideRule = "deny"
+)
+
+// IsValid 检查覆盖规则是否有效（空值视为 allow）
+func (r OverrideRule) IsValid() bool {
+	return r == "" || r == OverrideAllow || r == OverrideDeny
+}
+
+// ProjectOverrides 项目默认值的覆盖规则
+type ProjectOverrides struct {
+	// AgentType 任务能否指定与默认不同的 Agent 类型
+	AgentType OverrideRule `json:"agent_type,omitempty"`
+
+	// Account 任务能否指定具体的 Agent 实例（deny 时只能从账号池分配）
+	Account OverrideRule `json:"account,omitempty"`
+
+	// Security 任务能否自带安全配置
+	Security OverrideRule `json:"security,omitempty"`
+}
+
+// ============================================================================
+// ResultPublishConfig - 结果发布配置
+// ============================================================================
+
+// PublishMode 结果发布方式
+type PublishMode string
+
+const (
+	// PublishComment 在 PR/MR 下发表评论
+	PublishComment PublishMode = "comment"
+
+	// PublishStatus 为 PR 头部提交设置提交状态
+	PublishStatus PublishMode = "status"
+
+	// PublishBoth 评论与提交状态同时发布
+	PublishBoth PublishMode = "both"
+)
+
+// IsValid 检查发布方式是否合法（空值视为 comment）
+func (m PublishMode) IsValid() bool {
+	return m == "" || m == PublishComment || m == PublishStatus || m == PublishBoth
+}
+
+// Comments 是否发表评论
+func (m PublishMode) Comments() bool {
+	return m == "" || m == PublishComment || m == PublishBoth
+}
+
+// Statuses 是否设置提交状态
+func (m PublishMode) Statuses() bool {
+	return m == PublishStatus || m == PublishBoth
+}
+
+// ResultPublishConfig 项目的 Run 结果发布配置
+//
+// 对工作空间 git.pull_request 非空的任务，Run 结束后将摘要、验证结果（post_run 钩子）
+// 与变更/产物链接发布到该 PR/MR。
+type ResultPublishConfig struct {
+	// Enabled 是否启用
+	Enabled bool `json:"enabled"`
+
+	// Mode 发布方式：comment（默认）/ status / both
+	Mode PublishMode `json:"mode,omitempty"`
+
+	// Provider 代码托管平台：github / gitlab，为空时根据仓库地址推断
+	Provider string `json:"provider,omitempty"`
+
+	// APIURL API 地址，为空时根据仓库地址推断（GitHub Enterprise 为 /api/v3，GitLab 为 /api/v4）
+	APIURL string `json:"api_url,omitempty"`
+
+	// CredentialRef 调用 API 使用的凭据名称（https_token 类型），为空时使用任务 git.credential_ref
+	CredentialRef string `json:"credential_ref,omitempty"`
+
+	// CommentTemplate 评论正文模板（Go text/template），为空时使用内置模板
+	CommentTemplate string `json:"comment_template,omitempty"`
+
+	// StatusContext 提交状态名称，默认 "agents-admin"
+	StatusContext string `json:"status_context,omitempty"`
+}
+
+// Validate 校验发布配置
+func (c *ResultPublishConfig) Validate() error {
+	if !c.Mode.IsValid() {
+		return fmt.Errorf("result_publishing.mode must be comment, status or both")
+	}
+	if c.Provider != "" && c.Provider != "github" && c.Provider != "gitlab" {
+		return fmt.Errorf("result_publishing.provider must be github or gitlab")
+	}
+	if c.APIURL != "" && !strings.HasPrefix(c.APIURL, "https://") && !strings.HasPrefix(c.APIURL, "http://") {
+		return fmt.Errorf("result_publishing.api_url must start with http:// or https://")
+	}
+	if c.CommentTemplate != "" {
+		if _, err := template.New("comment").Parse(c.CommentTemplate); err != nil {
+			return fmt.Errorf("result_publishing.comment_template: %v", err)
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// RetentionPolicy - 事件保留策略
+// ============================================================================
+
+// RetentionPolicy 项目的 Run 事件保留策略
+//
+// Run 结束 HotDays 天后事件归档到对象存储并从数据库删除，
+// 归档 ArchiveDays 天后删除归档对象，此后事件不可再读取（Run 与归档摘要保留）。
+type RetentionPolicy struct {
+	// HotDays 事件保留在数据库中的天数，0 表示使用全局 lifecycle.hot_ttl
+	HotDays int `json:"hot_days,omitempty"`
+
+	// ArchiveDays 归档保留天数（自归档时起），0 表示永久保留
+	ArchiveDays int `json:"archive_days,omitempty"`
+}
+
+// HotTTL 事件保留在数据库中的时长，0 表示使用全局配置
+func (p *RetentionPolicy) HotTTL() time.Duration {
+	return time.Duration(p.HotDays) * 24 * time.Hour
+}
+
+// ArchiveTTL 归档保留时长，0 表示永久保留
+func (p *RetentionPolicy) ArchiveTTL() time.Duration {
+	return time.Duration(p.ArchiveDays) * 24 * time.Hour
+}
+
+// Validate 校验保留策略
+func (p *RetentionPolicy) Validate() error {
+	if p.HotDays < 0 || p.ArchiveDays < 0 {
+		return fmt.Errorf("retention.hot_days and retention.archive_days must not be negative")
+	}
+	return nil
+}
+
+// ============================================================================
+// Project - 项目
+// ============================================================================
+
+// Project 项目
+//
+// 项目为其下的任务提供默认执行配置：
+//   - DefaultAgentType / AllowedAgentTypes：默认 Agent 类型与白名单
+//   - AccountPool：账号池，任务未绑定 Agent 实例时从中分配账号
+//   - DefaultSecurity / AllowedSecurityPolicies：默认安全配置与策略等级白名单
+//   - Overrides：任务覆盖默认值的规则（由管理员配置）
+//   - ResultPublishing：Run 结果发布到触发任务的 PR/MR
+//   - Retention：Run 事件在数据库与归档中的保留天数
+type Project struct {
+	// ID 唯一标识
+	ID string `json:"id" bson:"_id" db:"id"`
+
+	// Name 项目名称（唯一）
+	Name string `json:"name" bson:"name" db:"name"`
+
+	// Description 项目描述
+	Description string `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+
+	// DefaultAgentType 默认 Agent 类型（如 qwen-code）
+	DefaultAgentType string `json:"default_agent_type,omitempty" bson:"default_agent_type,omitempty" db:"default_agent_type"`
+
+	// AllowedAgentTypes Agent 类型白名单，为空时不限制
+	AllowedAgentTypes []string `json:"allowed_agent_types,omitempty" bson:"allowed_agent_types,omitempty" db:"allowed_agent_types"`
+
+	// AccountPool 账号池（Account ID 列表），为空时不限制
+	AccountPool []string `json:"account_pool,omitempty" bson:"account_pool,omitempty" db:"account_pool"`
+
+	// DefaultSecurity 默认安全配置
+	DefaultSecurity *SecurityConfig `json:"default_security,omitempty" bson:"default_security,omitempty" db:"default_security"`
+
+	// AllowedSecurityPolicies 安全策略等级白名单，为空时不限制
+	AllowedSecurityPolicies []SecurityPolicy `json:"allowed_security_policies,omitempty" bson:"allowed_security_policies,omitempty" db:"allowed_security_policies"`
+
+	// Overrides 覆盖规则
+	Overrides ProjectOverrides `json:"overrides" bson:"overrides" db:"overrides"`
+
+	// ResultPublishing Run 结果发布配置（可选）
+	ResultPublishing *ResultPublishConfig `json:"result_publishing,omitempty" bson:"result_publishing,omitempty" db:"result_publishing"`
+
+	// Retention Run 事件保留策略（可选，为空时使用全局 lifecycle 配置）
+	Retention *RetentionPolicy `json:"retention,omitempty" bson:"retention,omitempty" db:"retention"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+
+	// UpdatedAt 更新时间
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// ============================================================================
+// Project 辅助方法
+// ============================================================================
+
+// AllowsAgentType 检查 Agent 类型是否在白名单内
+func (p *Project) AllowsAgentType(agentType string) bool {
+	return len(p.AllowedAgentTypes) == 0 || containsString(p.AllowedAgentTypes, agentType)
+}
+
+// AllowsAccount 检查账号是否在账号池内
+func (p *Project) AllowsAccount(accountID string) bool {
+	return len(p.AccountPool) == 0 || containsString(p.AccountPool, accountID)
+}
+
+// AllowsSecurityPolicy 检查安全策略等级是否在白名单内
+func (p *Project) AllowsSecurityPolicy(policy SecurityPolicy) bool {
+	if len(p.AllowedSecurityPolicies) == 0 {
+		return true
+	}
+	for _, allowed := range p.AllowedSecurityPolicies {
+		if allowed == policy {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 校验项目配置
+func (p *Project) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if p.DefaultAgentType != "" && !p.AllowsAgentType(p.DefaultAgentType) {
+		return fmt.Errorf("default_agent_type %q is not in allowed_agent_types", p.DefaultAgentType)
+	}
+	if p.DefaultSecurity != nil && !p.AllowsSecurityPolicy(p.DefaultSecurity.Policy) {
+		return fmt.Errorf("default_security.policy %q is not in allowed_security_policies", p.DefaultSecurity.Policy)
+	}
+	for field, rule := range map[string]OverrideRule{
+		"agent_type": p.Overrides.AgentType,
+		"account":    p.Overrides.Account,
+		"security":   p.Overrides.Security,
+	} {
+		if !rule.IsValid() {
+			return fmt.Errorf("overrides.%s must be allow or deny", field)
+		}
+	}
+	if p.ResultPublishing != nil {
+		if err := p.ResultPublishing.Validate(); err != nil {
+			return err
+		}
+	}
+	if p.Retention != nil {
+		if err := p.Retention.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// containsString 判断切片是否包含指定字符串
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
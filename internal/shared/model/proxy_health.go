@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// ProxyHealthStatus 代理健康状态（汇总各节点的探测结果）
+type ProxyHealthStatus string
+
+const (
+	ProxyHealthHealthy   ProxyHealthStatus = "healthy"   // 所有节点探测成功
+	ProxyHealthDegraded  ProxyHealthStatus = "degraded"  // 部分节点探测失败
+	ProxyHealthUnhealthy ProxyHealthStatus = "unhealthy" // 所有节点探测失败
+	ProxyHealthUnknown   ProxyHealthStatus = "unknown"   // 近期没有节点上报探测结果
+)
+
+// ProxyProbeResult 节点对代理的一次探测结果（TCP 连接 + CONNECT 握手）
+type ProxyProbeResult struct {
+	ProxyID   string    `json:"proxy_id"`
+	NodeID    string    `json:"node_id,omitempty"`
+	Healthy   bool      `json:"healthy"`
+	LatencyMs int64     `json:"latency_ms"`
+	Error     string    `json:"error,omitempty"`
+	CheckedAt time.Time `json:"checked_at"`
+}
+
+// ProxyNodeHealth 代理在单个节点上的最新健康状态
+type ProxyNodeHealth struct {
+	NodeID              string    `json:"node_id"`
+	Healthy             bool      `json:"healthy"`
+	LatencyMs           int64     `json:"latency_ms"`
+	Error               string    `json:"error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	CheckedAt           time.Time `json:"checked_at"`
+}
+
+// ProxyHealth 代理健康汇总
+type ProxyHealth struct {
+	Status        ProxyHealthStatus `json:"status"`
+	HealthyNodes  int               `json:"healthy_nodes"`
+	TotalNodes    int               `json:"total_nodes"`
+	AvgLatencyMs  int64             `json:"avg_latency_ms,omitempty"` // 探测成功节点的平均延迟
+	LastCheckedAt *time.Time        `json:"last_checked_at,omitempty"`
+	Nodes         []ProxyNodeHealth `json:"nodes"`
+}
+
+// NodeProxy 下发给节点的代理（URL 与环境变量包含认证信息，仅节点可获取）
+type NodeProxy struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Type      ProxyType `json:"type"`
+	URL       string    `json:"url"`
+	IsDefault bool      `json:"is_default"`
+	Env       []string  `json:"env"`
+}
@@ -0,0 +1,128 @@
+// Package model 定义核心数据模型
+//
+// region.go 包含区域相关的数据模型定义：
+//   - Region：区域（按数据中心划分的节点组）及跨区域回退策略
+//   - RegionQueueStats：区域的排队与节点统计
+//
+// 节点通过 region 标签归属区域，任务通过 region 字段声明希望执行的区域，
+// 调度器的 region 策略优先选择本区域节点，本区域不可用时按区域的回退策略处理。
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// LabelRegion 区域标签
+//
+// 节点以该标签声明所属区域（取值为 Region.ID），可在 nodemanager.yaml 的 labels
+// 或加入令牌的 labels 中设置。
+const LabelRegion = "region"
+
+// 跨区域回退策略
+const (
+	// RegionFallbackNone 不回退：本区域没有可用节点时 Run 保持 queued
+	RegionFallbackNone = "none"
+
+	// RegionFallbackOrdered 按 FallbackRegions 顺序回退，不使用列表以外的区域
+	RegionFallbackOrdered = "ordered"
+
+	// RegionFallbackAny 先按 FallbackRegions 顺序回退，仍无可用节点时可调度到任意节点（默认）
+	RegionFallbackAny = "any"
+)
+
+var regionIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+// Region 区域（节点组）
+//
+// 字段说明：
+//   - ID：区域标识（与节点 region 标签取值一致，如 cn-east）
+//   - DisplayName：显示名称
+//   - Description：说明（如机房位置、就近的 git 远端与代理）
+//   - Fallback：跨区域回退策略（none / ordered / any，默认 any）
+//   - FallbackRegions：回退时依次尝试的区域
+type Region struct {
+	ID              string    `json:"id" bson:"_id" db:"id"`
+	DisplayName     string    `json:"display_name,omitempty" bson:"display_name,omitempty" db:"display_name"`
+	Description     string    `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+	Fallback        string    `json:"fallback,omitempty" bson:"fallback,omitempty" db:"fallback"`
+	FallbackRegions []string  `json:"fallback_regions,omitempty" bson:"fallback_regions,omitempty" db:"fallback_regions"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// FallbackPolicy 返回回退策略（未设置时为 any）
+func (r *Region) FallbackPolicy() string {
+	if r == nil || r.Fallback == "" {
+		return RegionFallbackAny
+	}
+	return r.Fallback
+}
+
+// Validate 校验区域
+func (r *Region) Validate() error {
+	if err := ValidateRegionID(r.ID); err != nil {
+		return err
+	}
+	switch r.Fallback {
+	case "", RegionFallbackNone, RegionFallbackOrdered, RegionFallbackAny:
+	default:
+		return fmt.Errorf("fallback must be none, ordered or any")
+	}
+	seen := map[string]bool{r.ID: true}
+	for _, id := range r.FallbackRegions {
+		if err := ValidateRegionID(id); err != nil {
+			return fmt.Errorf("fallback_regions: %w", err)
+		}
+		if seen[id] {
+			return fmt.Errorf("fallback_regions: duplicate or self region %q", id)
+		}
+		seen[id] = true
+	}
+	return nil
+}
+
+// ValidateRegionID 校验区域标识（小写字母、数字与连字符，最长 63 个字符）
+func ValidateRegionID(id string) error {
+	if !regionIDPattern.MatchString(id) {
+		return fmt.Errorf("invalid region %q: must be lowercase letters, digits and hyphens", id)
+	}
+	return nil
+}
+
+// NodeRegion 返回节点 region 标签的取值（未设置或标签无法解析时为空）
+func NodeRegion(node *Node) string {
+	if node == nil || len(node.Labels) == 0 {
+		return ""
+	}
+	var labels map[string]string
+	if err := json.Unmarshal(node.Labels, &labels); err != nil {
+		return ""
+	}
+	return labels[LabelRegion]
+}
+
+// RegionQueueDepth 区域的排队 Run 统计（按任务的 region 字段归属，未声明区域的任务 Region 为空）
+type RegionQueueDepth struct {
+	Region         string     `json:"region"`
+	QueuedRuns     int        `json:"queued_runs"`
+	OldestQueuedAt *time.Time `json:"oldest_queued_at,omitempty"`
+}
+
+// RegionQueueStats 区域的排队与节点统计
+//
+// 字段说明：
+//   - QueuedRuns：声明该区域的任务处于 queued 的 Run 数
+//   - OldestQueuedSeconds：其中最早排队的 Run 已等待的秒数
+//   - OnlineNodes：带该区域标签的在线节点数
+//   - Capacity / Running：在线节点的最大并发之和与当前运行数之和
+type RegionQueueStats struct {
+	Region              string  `json:"region"`
+	QueuedRuns          int     `json:"queued_runs"`
+	OldestQueuedSeconds float64 `json:"oldest_queued_seconds"`
+	OnlineNodes         int     `json:"online_nodes"`
+	Capacity            int     `json:"capacity"`
+	Running             int     `json:"running"`
+}
@@ -0,0 +1,84 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// ============================================================================
+// RegistryItem - 模板注册表同步记录
+// ============================================================================
+
+// RegistryItemKind 注册表条目类型
+type RegistryItemKind string
+
+const (
+	// RegistryKindTaskTemplate 任务模板
+	RegistryKindTaskTemplate RegistryItemKind = "task_template"
+
+	// RegistryKindAgentTemplate Agent 模板
+	RegistryKindAgentTemplate RegistryItemKind = "agent_template"
+
+	// RegistryKindSkill 技能
+	RegistryKindSkill RegistryItemKind = "skill"
+)
+
+// IsValid 判断条目类型是否有效
+func (k RegistryItemKind) IsValid() bool {
+	switch k {
+	case RegistryKindTaskTemplate, RegistryKindAgentTemplate, RegistryKindSkill:
+		return true
+	}
+	return false
+}
+
+// RegistryItem 从远程注册表同步的任务模板、Agent 模板或技能
+//
+// 记录上游版本与本地已安装版本：
+//   - 同步时总是刷新上游版本与内容（UpstreamVersion / UpstreamSpec）
+//   - 未固定（Pinned=false）的条目在上游版本变化时自动安装
+//   - 固定的条目保持已安装版本，上游版本不同时显示"有可用更新"，可手动更新
+type RegistryItem struct {
+	// ID 条目标识（kind:item_id，见 RegistryItemID）
+	ID string `json:"id" bson:"_id" db:"id"`
+
+	// Kind 条目类型
+	Kind RegistryItemKind `json:"kind" bson:"kind" db:"kind"`
+
+	// ItemID 安装后的模板或技能 ID
+	ItemID string `json:"item_id" bson:"item_id" db:"item_id"`
+
+	// Source 提供该条目的注册表名称
+	Source string `json:"source" bson:"source" db:"source"`
+
+	// UpstreamVersion 注册表中的最新版本
+	UpstreamVersion string `json:"upstream_version" bson:"upstream_version" db:"upstream_version"`
+
+	// UpstreamDigest 上游内容的 SHA-256
+	UpstreamDigest string `json:"upstream_digest" bson:"upstream_digest" db:"upstream_digest"`
+
+	// UpstreamSpec 上游内容（模板或技能的 JSON，手动更新固定条目时使用）
+	UpstreamSpec json.RawMessage `json:"-" bson:"upstream_spec" db:"upstream_spec"`
+
+	// InstalledVersion 本地已安装的版本（为空表示尚未安装）
+	InstalledVersion string `json:"installed_version,omitempty" bson:"installed_version,omitempty" db:"installed_version"`
+
+	// Pinned 是否固定在已安装版本（不随同步自动更新）
+	Pinned bool `json:"pinned" bson:"pinned" db:"pinned"`
+
+	// SyncedAt 最近一次从注册表同步的时间
+	SyncedAt time.Time `json:"synced_at" bson:"synced_at" db:"synced_at"`
+
+	// InstalledAt 最近一次安装的时间
+	InstalledAt *time.Time `json:"installed_at,omitempty" bson:"installed_at,omitempty" db:"installed_at"`
+}
+
+// RegistryItemID 由条目类型与模板/技能 ID 组成注册表条目标识
+func RegistryItemID(kind RegistryItemKind, itemID string) string {
+	return string(kind) + ":" + itemID
+}
+
+// UpdateAvailable 上游版本与已安装版本不同
+func (i *RegistryItem) UpdateAvailable() bool {
+	return i.UpstreamVersion != "" && i.UpstreamVersion != i.InstalledVersion
+}
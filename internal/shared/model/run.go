@@ -93,6 +93,7 @@ const (
 //   - StartedAt：实际开始执行时间
 //   - FinishedAt：执行结束时间
 //   - Snapshot：执行时的任务快照（用于审计）
+//   - Priority：调度优先级（high/normal/low）
 //   - Error：错误信息（失败时填充）
 type Run struct {
 	ID         string          `json:"id" bson:"_id" db:"id"`                             // 执行唯一标识
@@ -102,6 +103,7 @@ type Run struct {
 	StartedAt  *time.Time      `json:"started_at,omitempty" bson:"started_at,omitempty" db:"started_at"`   // 开始时间
 	FinishedAt *time.Time      `json:"finished_at,omitempty" bson:"finished_at,omitempty" db:"finished_at"` // 结束时间
 	Snapshot   json.RawMessage `json:"snapshot,omitempty" bson:"snapshot,omitempty" db:"snapshot"`       // 任务快照
+	Priority   Priority        `json:"priority,omitempty" bson:"priority,omitempty" db:"priority"`       // 调度优先级（创建时继承 Task.Priority）
 	Error      *string         `json:"error,omitempty" bson:"error,omitempty" db:"error"`             // 错误信息
 	CreatedAt  time.Time       `json:"created_at" bson:"created_at" db:"created_at"`             // 创建时间
 	UpdatedAt  time.Time       `json:"updated_at" bson:"updated_at" db:"updated_at"`             // 更新时间
@@ -0,0 +1,59 @@
+// Package model 定义核心数据模型
+//
+// run_archive.go 包含 Run 数据分层相关的数据模型定义：
+//   - RunDataTier：Run 数据所在层级（hot/warm/cold/purged）
+//   - RunArchive：Run 事件归档记录（归档位置、事件数、摘要）
+//   - RunSummary：归档时生成的 Run 摘要
+//   - RunTierStats：各层级的存储统计
+package model
+
+import "time"
+
+// RunDataTier Run 数据层级
+//
+//   - hot：事件完整保存在数据库中（没有归档记录的 Run 都属于 hot）
+//   - warm：事件已归档到对象存储并从数据库删除，Run 元数据保留在数据库
+//   - cold：在 warm 基础上清除任务快照，数据库中只保留 Run 基本字段、摘要与归档位置
+//   - purged：归档已按项目保留策略删除，事件不可再读取，只保留 Run 与摘要
+type RunDataTier string
+
+const (
+	RunDataTierHot    RunDataTier = "hot"
+	RunDataTierWarm   RunDataTier = "warm"
+	RunDataTierCold   RunDataTier = "cold"
+	RunDataTierPurged RunDataTier = "purged"
+)
+
+// RunSummary 归档时由事件流生成的 Run 摘要
+type RunSummary struct {
+	Status       RunStatus      `json:"status" bson:"status"`
+	NodeID       string         `json:"node_id,omitempty" bson:"node_id,omitempty"`
+	EventCount   int            `json:"event_count" bson:"event_count"`
+	EventTypes   map[string]int `json:"event_types,omitempty" bson:"event_types,omitempty"` // 按事件类型计数
+	FirstEventAt *time.Time     `json:"first_event_at,omitempty" bson:"first_event_at,omitempty"`
+	LastEventAt  *time.Time     `json:"last_event_at,omitempty" bson:"last_event_at,omitempty"`
+	DurationMS   int64          `json:"duration_ms,omitempty" bson:"duration_ms,omitempty"` // started_at 到 finished_at
+	Error        string         `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// RunArchive Run 事件归档记录
+type RunArchive struct {
+	RunID        string      `json:"run_id" bson:"_id" db:"run_id"`
+	ProjectID    string      `json:"project_id,omitempty" bson:"project_id,omitempty" db:"project_id"` // 归档时 Run 所属任务的项目（按项目保留策略删除归档）
+	Tier         RunDataTier `json:"tier" bson:"tier" db:"tier"`
+	ArchiveKey   string      `json:"archive_key" bson:"archive_key" db:"archive_key"` // 对象存储中的事件归档（gzip 压缩的 NDJSON）
+	EventCount   int         `json:"event_count" bson:"event_count" db:"event_count"`
+	ArchiveBytes int64       `json:"archive_bytes" bson:"archive_bytes" db:"archive_bytes"` // 归档对象大小（压缩后）
+	Summary      *RunSummary `json:"summary,omitempty" bson:"summary,omitempty" db:"summary"`
+	WarmAt       time.Time   `json:"warm_at" bson:"warm_at" db:"warm_at"` // 进入 warm 的时间
+	ColdAt       *time.Time  `json:"cold_at,omitempty" bson:"cold_at,omitempty" db:"cold_at"`
+	PurgedAt     *time.Time  `json:"purged_at,omitempty" bson:"purged_at,omitempty" db:"purged_at"`
+}
+
+// RunTierStats 单个层级的存储统计
+type RunTierStats struct {
+	Tier         RunDataTier `json:"tier"`
+	Runs         int64       `json:"runs"`
+	Events       int64       `json:"events"`        // hot 为数据库中的事件数，warm/cold 为归档中的事件数，purged 为已删除的事件数
+	ArchiveBytes int64       `json:"archive_bytes"` // 对象存储占用（hot 与 purged 为 0）
+}
@@ -0,0 +1,52 @@
+// Package model 定义核心数据模型
+//
+// run_diff.go 包含 Run 代码变更报告相关的数据模型定义：
+//   - RunDiff：Git 工作空间 Run 结束后生成的变更报告
+//   - RunDiffFile：单个文件的变更统计
+package model
+
+import "time"
+
+// RunDiffArtifactName 变更报告在 Run 产物中的名称
+const RunDiffArtifactName = "diff"
+
+// RunDiffFileStatus 文件变更类型
+type RunDiffFileStatus string
+
+const (
+	RunDiffFileAdded       RunDiffFileStatus = "added"
+	RunDiffFileModified    RunDiffFileStatus = "modified"
+	RunDiffFileDeleted     RunDiffFileStatus = "deleted"
+	RunDiffFileRenamed     RunDiffFileStatus = "renamed"
+	RunDiffFileCopied      RunDiffFileStatus = "copied"
+	RunDiffFileTypeChanged RunDiffFileStatus = "type_changed"
+)
+
+// RunDiffFile 单个文件的变更统计
+//
+// 二进制文件没有行数统计（Binary 为 true，Additions/Deletions 为 0）。
+type RunDiffFile struct {
+	Path      string            `json:"path"`               // 变更后的路径
+	OldPath   string            `json:"old_path,omitempty"` // 重命名/复制前的路径
+	Status    RunDiffFileStatus `json:"status"`
+	Additions int               `json:"additions"`
+	Deletions int               `json:"deletions"`
+	Binary    bool              `json:"binary,omitempty"`
+}
+
+// RunDiff Run 的代码变更报告
+//
+// 由 NodeManager 在 Git 工作空间的 Run 结束后对比克隆时的提交（BaseCommit）与工作空间
+// 当前内容生成（包含 Agent 自行提交的变更与未跟踪文件），上传后作为名为 "diff" 的 Run 产物保存。
+// Patch 为 unified diff，超过大小上限时截断并设置 Truncated。
+type RunDiff struct {
+	RunID        string        `json:"run_id"`
+	BaseCommit   string        `json:"base_commit"`
+	Files        []RunDiffFile `json:"files"`
+	FilesChanged int           `json:"files_changed"`
+	Additions    int           `json:"additions"`
+	Deletions    int           `json:"deletions"`
+	Patch        string        `json:"patch,omitempty"`
+	Truncated    bool          `json:"truncated,omitempty"`
+	CreatedAt    time.Time     `json:"created_at"`
+}
@@ -0,0 +1,121 @@
+// Package model 定义核心数据模型
+//
+// run_snapshot.go 包含 Run 执行快照的结构定义：
+//   - RunSnapshot：Run.Snapshot 的结构，API Server 创建 Run 时写入，NodeManager 执行时读取
+//   - SnapshotAgent / SnapshotRuntime / SnapshotSkill / SnapshotReplay：快照中的子结构
+package model
+
+import "encoding/json"
+
+// RunSnapshot Run 的执行快照
+//
+// 创建 Run 时由 API Server 从 Task 展开（见 run/handler.go startRun），NodeManager 按同一结构解析后执行，
+// 字段的名称与类型只在此处定义（快照校验的字段声明由 json 标签生成，见 snapshot.go fieldsOf）。可选字段未使用时不写入；旧版本格式先由 UpgradeSnapshot 升级（见 snapshot.go）。
+type RunSnapshot struct {
+	// TaskID 任务 ID
+	TaskID string `json:"task_id,omitempty"`
+
+	// Name 任务名称
+	Name string `json:"name,omitempty"`
+
+	// Agent 执行的 Agent
+	Agent SnapshotAgent `json:"agent"`
+
+	// Prompt 提示词纯文本（task.Prompt.Content，追问 Run 为追问内容）
+	Prompt string `json:"prompt"`
+
+	// Workspace 工作空间配置
+	Workspace *WorkspaceConfig `json:"workspace,omitempty"`
+
+	// Labels 任务标签
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Runtime 任务指定的执行后端（exec-backend 标签）
+	Runtime *SnapshotRuntime `json:"runtime,omitempty"`
+
+	// Secrets 引用的密钥名称（明文由 NodeManager 执行时解析）
+	Secrets []string `json:"secrets,omitempty"`
+
+	// Limits 资源限制（NodeManager 换算为容器运行时参数，超出节点容量时拒绝执行）
+	Limits *ResourceLimits `json:"limits,omitempty"`
+
+	// RequireApproval 调用前需要人工审批的工具
+	RequireApproval []string `json:"require_approval,omitempty"`
+
+	// CommandFilter 危险命令拦截规则
+	CommandFilter *CommandFilter `json:"command_filter,omitempty"`
+
+	// SecurityPolicy 生效的安全策略（secpolicy.Policy 的 JSON，secpolicy 依赖本包，此处不展开）
+	SecurityPolicy json.RawMessage `json:"security_policy,omitempty"`
+
+	// TimeoutSeconds 执行时限（秒），0 表示使用全局默认值
+	TimeoutSeconds int `json:"timeout_seconds,omitempty"`
+
+	// Hooks 生命周期钩子（Skill 引用已展开为内联脚本）
+	Hooks *LifecycleHooks `json:"hooks,omitempty"`
+
+	// Skills Agent 启动前安装的技能包
+	Skills []SnapshotSkill `json:"skills,omitempty"`
+
+	// InheritedContext 从父任务或上游节点继承的上下文
+	InheritedContext []ContextItem `json:"inherited_context,omitempty"`
+
+	// Session 持久会话信息（任务未启用会话时为空）
+	Session *RunSession `json:"session,omitempty"`
+
+	// NodeID 指定执行的节点（追问 Run 调度到上一轮会话的节点）
+	NodeID string `json:"node_id,omitempty"`
+
+	// Replay 回放生成的合成 Run 的来源（见 replay/handler.go）
+	Replay *SnapshotReplay `json:"replay,omitempty"`
+}
+
+// SnapshotAgent 快照中的 Agent 配置
+type SnapshotAgent struct {
+	// Type Agent 类型（task.Type，如 qwen-code）
+	Type string `json:"type"`
+
+	// InstanceID 任务绑定的 Agent 实例 ID
+	InstanceID string `json:"instance_id,omitempty"`
+
+	// AccountID 执行账号（项目账号池分配或追问沿用上一轮的账号）
+	AccountID string `json:"account_id,omitempty"`
+
+	// AccountPool 账号池中的候选账号，执行时由 NodeManager 按负载重新租用
+	AccountPool []string `json:"account_pool,omitempty"`
+
+	// Model 使用的模型
+	Model string `json:"model,omitempty"`
+
+	// Parameters Adapter 参数（如 yolo、max_turns）
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// SnapshotRuntime 快照中的执行后端
+type SnapshotRuntime struct {
+	// Backend 执行后端：docker / process
+	Backend string `json:"backend,omitempty"`
+}
+
+// SnapshotSkill 快照中的技能包（NodeManager 按 skill_id + version 下载，按 digest 校验后安装）
+type SnapshotSkill struct {
+	SkillID string `json:"skill_id"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Digest  string `json:"digest"`
+}
+
+// SnapshotReplay 回放生成的合成 Run 的来源
+type SnapshotReplay struct {
+	SourceRunID string `json:"source_run_id"`
+	Adapter     string `json:"adapter"`
+}
+
+// ParseRunSnapshot 解析 Run 快照（字段类型不符时返回 JSON 解码错误，不做格式升级）
+func ParseRunSnapshot(data []byte) (*RunSnapshot, error) {
+	var s RunSnapshot
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
@@ -0,0 +1,277 @@
+// Package model 定义核心数据模型
+//
+// scheduling.go 包含调度相关的数据模型定义：
+//   - SchedulingDecision：调度器对 Run 的一次调度决策
+//   - SchedulingCandidate：决策中考察的候选节点及其淘汰原因
+//   - TaskScheduling：任务声明的亲和、反亲和、分散约束与污点容忍
+package model
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 调度决策结果
+const (
+	SchedulingOutcomeAssigned    = "assigned"     // 已分配节点
+	SchedulingOutcomeNoNodes     = "no_nodes"     // 没有在线节点
+	SchedulingOutcomeBudgetHold  = "budget_hold"  // 预算耗尽，暂不分派
+	SchedulingOutcomeAccountHold = "account_hold" // 账号池额度耗尽，暂不分派
+	SchedulingOutcomeNoMatch     = "no_match"     // 没有满足条件的节点
+	SchedulingOutcomeInvalid     = "invalid"      // 快照不合法，Run 直接失败
+)
+
+// 候选节点淘汰原因
+const (
+	SchedulingRejectAdapter  = "adapter_capability" // 适配器版本或能力不满足任务要求
+	SchedulingRejectLabel    = "label_mismatch"     // 节点标签不满足任务标签要求
+	SchedulingRejectTaint    = "untolerated_taint"  // 节点带有任务未容忍的污点
+	SchedulingRejectCapacity = "capacity_full"      // 节点并发已满（含为 high 优先级预留的槽位）
+	SchedulingRejectRegion   = "region"             // 不在任务区域的回退策略允许的区域内
+	SchedulingRejectAffinity = "task_affinity"      // 不满足必须的任务亲和
+	SchedulingRejectAnti     = "anti_affinity"      // 不满足必须的任务反亲和
+	SchedulingRejectSpread   = "spread"             // 不满足必须的分散约束
+	SchedulingRejectStrategy = "not_selected"       // 满足约束但策略链未选中
+	SchedulingSelected       = "selected"           // 被选中的节点
+)
+
+// SchedulingCandidate 调度决策中考察的候选节点
+//
+// 字段说明：
+//   - NodeID：节点 ID
+//   - Running：决策时节点的运行任务数（含预留槽位）
+//   - MaxConcurrent：节点最大并发
+//   - Rejected：淘汰原因，被选中的节点为 selected
+type SchedulingCandidate struct {
+	NodeID        string `json:"node_id" bson:"node_id"`
+	Running       int    `json:"running" bson:"running"`
+	MaxConcurrent int    `json:"max_concurrent" bson:"max_concurrent"`
+	Rejected      string `json:"rejected" bson:"rejected"`
+}
+
+// SchedulingDecision 调度器对 Run 的一次调度决策
+//
+// Run 保持 queued 时调度器每轮都会重新决策，与上一条决策相同（结果、策略、原因、
+// 节点及各候选的淘汰原因均一致）时只累加 Attempts 并刷新 UpdatedAt，不重复记录。
+//
+// 字段说明：
+//   - ID：自增主键
+//   - RunID：所属 Run ID
+//   - Outcome：决策结果（assigned / no_nodes / budget_hold / account_hold / no_match）
+//   - Strategy：做出选择的策略（策略链或 preemption），未分配时为空
+//   - Reason：策略给出的原因或未分配的原因
+//   - NodeID：分配的节点，未分配时为空
+//   - Candidates：考察的候选节点
+//   - Attempts：相同决策的连续次数
+//   - CreatedAt / UpdatedAt：首次与最近一次做出该决策的时间
+type SchedulingDecision struct {
+	ID         int64                 `json:"id" bson:"_id" db:"id"`
+	RunID      string                `json:"run_id" bson:"run_id" db:"run_id"`
+	Outcome    string                `json:"outcome" bson:"outcome" db:"outcome"`
+	Strategy   string                `json:"strategy,omitempty" bson:"strategy,omitempty" db:"strategy"`
+	Reason     string                `json:"reason,omitempty" bson:"reason,omitempty" db:"reason"`
+	NodeID     string                `json:"node_id,omitempty" bson:"node_id,omitempty" db:"node_id"`
+	Candidates []SchedulingCandidate `json:"candidates" bson:"candidates" db:"-"`
+	Signature  string                `json:"-" bson:"signature" db:"signature"`
+	Attempts   int                   `json:"attempts" bson:"attempts" db:"attempts"`
+	CreatedAt  time.Time             `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt  time.Time             `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// ComputeSignature 计算决策签名（用于合并连续相同的决策）
+//
+// 运行任务数不参与计算，节点负载的小幅波动不会产生新的决策记录。
+func (d *SchedulingDecision) ComputeSignature() string {
+	var b strings.Builder
+	b.WriteString(d.Outcome)
+	b.WriteByte('|')
+	b.WriteString(d.Strategy)
+	b.WriteByte('|')
+	b.WriteString(d.Reason)
+	b.WriteByte('|')
+	b.WriteString(d.NodeID)
+	for _, c := range d.Candidates {
+		b.WriteByte('|')
+		b.WriteString(c.NodeID)
+		b.WriteByte('=')
+		b.WriteString(c.Rejected)
+	}
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// 任务调度约束的执行方式
+const (
+	SchedulingModeRequired  = "required"  // 必须满足，没有满足的节点时 Run 保持 queued
+	SchedulingModePreferred = "preferred" // 尽量满足，没有满足的节点时由策略链中后续策略选择
+)
+
+// TaskScheduling 任务调度约束
+//
+// 在标签匹配与固定节点之外声明任务间的放置关系，由调度器的 task_affinity、anti_affinity、spread 策略执行：
+//   - Affinity：调度到相关任务执行过的节点（数据局部性）
+//   - AntiAffinity：避开正在执行同一任务其他 Run 的节点
+//   - Spread：任务组（该任务及同一父任务下的任务）的活跃 Run 按节点标签值均匀分布
+//   - Tolerations：允许调度到带有对应污点（NodeTaint）的节点
+type TaskScheduling struct {
+	Affinity     *TaskAffinity     `json:"affinity,omitempty" bson:"affinity,omitempty"`
+	AntiAffinity *TaskAntiAffinity `json:"anti_affinity,omitempty" bson:"anti_affinity,omitempty"`
+	Spread       *SpreadConstraint `json:"spread,omitempty" bson:"spread,omitempty"`
+	Tolerations  []Toleration      `json:"tolerations,omitempty" bson:"tolerations,omitempty"`
+}
+
+// TaskAffinity 任务亲和
+//
+// 相关任务尚未在任何节点执行过时不限制节点（首个执行的任务决定位置）。
+type TaskAffinity struct {
+	// Siblings 同一父任务下的其他任务
+	Siblings bool `json:"siblings,omitempty" bson:"siblings,omitempty"`
+	// TaskIDs 指定的任务
+	TaskIDs []string `json:"task_ids,omitempty" bson:"task_ids,omitempty"`
+	// Mode required / preferred（默认）
+	Mode string `json:"mode,omitempty" bson:"mode,omitempty"`
+}
+
+// TaskAntiAffinity 任务反亲和（同一任务的 Run 不在同一节点并行执行）
+type TaskAntiAffinity struct {
+	// Mode required / preferred（默认）
+	Mode string `json:"mode,omitempty" bson:"mode,omitempty"`
+}
+
+// SpreadConstraint 分散约束
+type SpreadConstraint struct {
+	// LabelKey 划分拓扑域的节点标签（如 zone），未设置该标签的节点不属于任何域
+	LabelKey string `json:"label_key" bson:"label_key"`
+	// MaxSkew 各域活跃 Run 数的最大差值（默认 1）
+	MaxSkew int `json:"max_skew,omitempty" bson:"max_skew,omitempty"`
+	// Mode required / preferred（默认）
+	Mode string `json:"mode,omitempty" bson:"mode,omitempty"`
+}
+
+// 容忍的匹配方式
+const (
+	TolerationOpEqual  = "Equal"  // 键与值均相同（默认）
+	TolerationOpExists = "Exists" // 键相同即可，键为空时容忍所有污点
+)
+
+// Toleration 污点容忍
+type Toleration struct {
+	// Key 污点键
+	Key string `json:"key,omitempty" bson:"key,omitempty"`
+	// Operator Equal（默认）/ Exists
+	Operator string `json:"operator,omitempty" bson:"operator,omitempty"`
+	// Value 污点值（Operator 为 Equal 时比较）
+	Value string `json:"value,omitempty" bson:"value,omitempty"`
+	// Effect 容忍的效果，为空时容忍所有效果
+	Effect string `json:"effect,omitempty" bson:"effect,omitempty"`
+}
+
+// Tolerates 是否容忍污点
+func (t Toleration) Tolerates(taint NodeTaint) bool {
+	if t.Effect != "" && t.Effect != taint.Effect {
+		return false
+	}
+	if t.Operator == TolerationOpExists {
+		return t.Key == "" || t.Key == taint.Key
+	}
+	return t.Key == taint.Key && t.Value == taint.Value
+}
+
+// ToleratesTaints 是否容忍节点的全部污点
+func ToleratesTaints(taints []NodeTaint, tolerations []Toleration) bool {
+	for _, taint := range taints {
+		tolerated := false
+		for _, t := range tolerations {
+			if t.Tolerates(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return false
+		}
+	}
+	return true
+}
+
+// GetTolerations 返回任务的污点容忍（未声明时为 nil）
+func (s *TaskScheduling) GetTolerations() []Toleration {
+	if s == nil {
+		return nil
+	}
+	return s.Tolerations
+}
+
+// IsRequired 是否为必须满足的约束
+func (a *TaskAffinity) IsRequired() bool { return a != nil && a.Mode == SchedulingModeRequired }
+
+// IsRequired 是否为必须满足的约束
+func (a *TaskAntiAffinity) IsRequired() bool { return a != nil && a.Mode == SchedulingModeRequired }
+
+// IsRequired 是否为必须满足的约束
+func (c *SpreadConstraint) IsRequired() bool { return c != nil && c.Mode == SchedulingModeRequired }
+
+// Skew 允许的最大差值
+func (c *SpreadConstraint) Skew() int {
+	if c.MaxSkew <= 0 {
+		return 1
+	}
+	return c.MaxSkew
+}
+
+// Validate 校验调度约束
+func (s *TaskScheduling) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if a := s.Affinity; a != nil {
+		if err := validateSchedulingMode("affinity", a.Mode); err != nil {
+			return err
+		}
+		if !a.Siblings && len(a.TaskIDs) == 0 {
+			return fmt.Errorf("scheduling.affinity: siblings or task_ids is required")
+		}
+	}
+	if a := s.AntiAffinity; a != nil {
+		if err := validateSchedulingMode("anti_affinity", a.Mode); err != nil {
+			return err
+		}
+	}
+	if c := s.Spread; c != nil {
+		if err := validateSchedulingMode("spread", c.Mode); err != nil {
+			return err
+		}
+		if c.LabelKey == "" {
+			return fmt.Errorf("scheduling.spread: label_key is required")
+		}
+		if c.MaxSkew < 0 {
+			return fmt.Errorf("scheduling.spread: max_skew must not be negative")
+		}
+	}
+	for i, t := range s.Tolerations {
+		switch t.Operator {
+		case "", TolerationOpEqual:
+			if t.Key == "" {
+				return fmt.Errorf("scheduling.tolerations[%d]: key is required for operator Equal", i)
+			}
+		case TolerationOpExists:
+			if t.Value != "" {
+				return fmt.Errorf("scheduling.tolerations[%d]: value must be empty for operator Exists", i)
+			}
+		default:
+			return fmt.Errorf("scheduling.tolerations[%d]: operator must be Equal or Exists", i)
+		}
+	}
+	return nil
+}
+
+func validateSchedulingMode(field, mode string) error {
+	switch mode {
+	case "", SchedulingModeRequired, SchedulingModePreferred:
+		return nil
+	}
+	return fmt.Errorf("scheduling.%s: mode must be required or preferred", field)
+}
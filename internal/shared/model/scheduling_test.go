@@ -0,0 +1,56 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestToleratesTaints 验证污点容忍匹配
+func TestToleratesTaints(t *testing.T) {
+	ml := NodeTaint{Key: "dedicated", Value: "ml", Effect: TaintEffectNoSchedule}
+	gpu := NodeTaint{Key: "gpu", Effect: TaintEffectNoSchedule}
+
+	assert.True(t, ToleratesTaints(nil, nil), "无污点的节点不需要容忍")
+	assert.False(t, ToleratesTaints([]NodeTaint{ml}, nil))
+	assert.True(t, ToleratesTaints([]NodeTaint{ml}, []Toleration{{Key: "dedicated", Value: "ml"}}))
+	assert.False(t, ToleratesTaints([]NodeTaint{ml}, []Toleration{{Key: "dedicated", Value: "web"}}))
+	assert.True(t, ToleratesTaints([]NodeTaint{ml}, []Toleration{{Key: "dedicated", Operator: TolerationOpExists}}))
+	assert.False(t, ToleratesTaints([]NodeTaint{ml}, []Toleration{{Key: "dedicated", Value: "ml", Effect: "PreferNoSchedule"}}))
+	assert.False(t, ToleratesTaints([]NodeTaint{ml, gpu}, []Toleration{{Key: "dedicated", Value: "ml"}}), "需容忍全部污点")
+	assert.True(t, ToleratesTaints([]NodeTaint{ml, gpu}, []Toleration{{Operator: TolerationOpExists}}), "空键 Exists 容忍所有污点")
+}
+
+// TestTaskScheduling_Validate 验证调度约束校验
+func TestTaskScheduling_Validate(t *testing.T) {
+	valid := []*TaskScheduling{
+		nil,
+		{AntiAffinity: &TaskAntiAffinity{}},
+		{Spread: &SpreadConstraint{LabelKey: "zone", Mode: SchedulingModeRequired}},
+		{Tolerations: []Toleration{{Key: "dedicated", Value: "ml"}, {Operator: TolerationOpExists}}},
+	}
+	for _, s := range valid {
+		assert.NoError(t, s.Validate())
+	}
+
+	invalid := []*TaskScheduling{
+		{Affinity: &TaskAffinity{}},
+		{AntiAffinity: &TaskAntiAffinity{Mode: "always"}},
+		{Spread: &SpreadConstraint{}},
+		{Spread: &SpreadConstraint{LabelKey: "zone", MaxSkew: -1}},
+		{Tolerations: []Toleration{{Value: "ml"}}},
+		{Tolerations: []Toleration{{Key: "dedicated", Operator: TolerationOpExists, Value: "ml"}}},
+		{Tolerations: []Toleration{{Key: "dedicated", Operator: "In"}}},
+	}
+	for _, s := range invalid {
+		assert.Error(t, s.Validate())
+	}
+}
+
+// TestNodeTaint_Validate 验证污点校验
+func TestNodeTaint_Validate(t *testing.T) {
+	assert.NoError(t, NodeTaint{Key: "dedicated", Value: "ml", Effect: TaintEffectNoSchedule}.Validate())
+	assert.Error(t, NodeTaint{Effect: TaintEffectNoSchedule}.Validate())
+	assert.Error(t, NodeTaint{Key: "dedicated", Effect: "NoExecute"}.Validate())
+	assert.Equal(t, "dedicated=ml:NoSchedule", NodeTaint{Key: "dedicated", Value: "ml", Effect: TaintEffectNoSchedule}.String())
+}
@@ -0,0 +1,33 @@
+// Package model 定义核心数据模型
+//
+// secret.go 包含运行时密钥相关的数据模型定义：
+//   - Secret：密钥（如 API Token），运行时作为环境变量注入 Agent 容器
+//
+// 设计理念：
+//   - 密钥名称即环境变量名，被 Task.Secrets 按名称引用
+//   - 值由存储层信封加密后落库（EncryptedValue，见 fieldcrypt.WrapStore），永不在 API 响应中返回
+//   - 仅 NodeManager 在执行 Run 时通过专用接口解析明文，并在事件和日志中脱敏
+package model
+
+import (
+	"regexp"
+	"time"
+)
+
+// secretNamePattern 密钥名称规则（需为合法的环境变量名）
+var secretNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
+
+// IsValidSecretName 检查密钥名称是否为合法的环境变量名
+func IsValidSecretName(name string) bool {
+	return secretNamePattern.MatchString(name)
+}
+
+// Secret 运行时密钥
+type Secret struct {
+	ID             string    `json:"id" bson:"_id" db:"id"`
+	Name           string    `json:"name" bson:"name" db:"name"`
+	Description    string    `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+	EncryptedValue string    `json:"-" bson:"encrypted_value" db:"encrypted_value"`
+	CreatedAt      time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
@@ -0,0 +1,56 @@
+// Package model 定义核心数据模型
+//
+// session.go 包含持久 Agent 会话相关的数据模型定义：
+//   - TaskSession：任务的会话配置
+//   - RunSession：执行快照中的会话信息
+package model
+
+// DefaultSessionKeepAliveSeconds 会话容器在 Run 结束后默认保留的时间（秒）
+const DefaultSessionKeepAliveSeconds = 1800
+
+// TaskSession 任务的持久 Agent 会话配置
+//
+// 启用后任务的 Run 结束时 NodeManager 保留执行容器（工作空间与 CLI 会话历史）KeepAliveSeconds 秒，
+// 通过 POST /api/v1/tasks/{id}/followup 创建的 Run 调度到同一节点并在该容器中继续上一轮会话；
+// 会话已失效（容器过期或节点不可用）时 NodeManager 将对话历史拼入提示词后重新开始。
+type TaskSession struct {
+	// Enabled 是否启用持久会话
+	Enabled bool `json:"enabled" bson:"enabled"`
+
+	// KeepAliveSeconds Run 结束后保留会话容器的时间（秒，0 使用 DefaultSessionKeepAliveSeconds）
+	KeepAliveSeconds int `json:"keep_alive_seconds,omitempty" bson:"keep_alive_seconds,omitempty"`
+}
+
+// IsEnabled 是否启用持久会话（nil 安全）
+func (s *TaskSession) IsEnabled() bool {
+	return s != nil && s.Enabled
+}
+
+// KeepAlive 返回会话容器的保留时间（秒）
+func (s *TaskSession) KeepAlive() int {
+	if s == nil || s.KeepAliveSeconds <= 0 {
+		return DefaultSessionKeepAliveSeconds
+	}
+	return s.KeepAliveSeconds
+}
+
+// RunSession 执行快照中的会话信息（snapshot.session）
+//
+// 启用会话的任务创建 Run 时写入；Resume 为 true 表示追问 Run，
+// NodeManager 优先复用 ID 对应的保留容器继续 CLI 会话，否则将 History 拼入提示词。
+type RunSession struct {
+	// ID 会话 ID（即任务 ID，同一任务的追问 Run 共享会话）
+	ID string `json:"id"`
+
+	// KeepAliveSeconds Run 结束后保留会话容器的时间（秒）
+	KeepAliveSeconds int `json:"keep_alive_seconds"`
+
+	// Resume 是否继续上一轮会话（追问 Run）
+	Resume bool `json:"resume,omitempty"`
+
+	// PreviousRunID 上一轮会话的 Run ID
+	PreviousRunID string `json:"previous_run_id,omitempty"`
+
+	// History 本轮提示词之前的对话历史
+	History []Message `json:"history,omitempty"`
+}
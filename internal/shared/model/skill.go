@@ -5,6 +5,7 @@
 //   - SkillCategory：技能分类枚举
 //   - SkillLevel：技能等级枚举
 //   - SkillSource：技能来源枚举
+//   - SkillBundle：技能包版本（存于对象存储的 tar.gz）
 //   - SkillRegistry：技能市场
 //   - AgentSkill：Agent 与 Skill 的关联
 //
@@ -197,6 +198,44 @@ func (s *Skill) GetTools() []string {
 	return tools
 }
 
+// ============================================================================
+// SkillBundle - 技能包版本
+// ============================================================================
+
+// SkillBundle 技能包的一个版本
+//
+// 技能包（清单 skill.yaml + scripts/、prompts/ 等文件，格式见 skillbundle 包）存于对象存储，
+// 此处记录其元数据。同一技能的版本不可变：重新上传相同版本时内容必须一致。
+// Agent 模板的 skills 中以 <skill_id>@<version> 固定版本，未固定时使用最新上传的版本；
+// NodeManager 在 Run 启动前将其安装到 Agent 的技能目录。
+type SkillBundle struct {
+	// SkillID 所属技能
+	SkillID string `json:"skill_id" bson:"skill_id" db:"skill_id"`
+
+	// Version 版本号（语义化版本，来自清单）
+	Version string `json:"version" bson:"version" db:"version"`
+
+	// Name 安装目录名（来自清单）
+	Name string `json:"name" bson:"name" db:"name"`
+
+	// Description 说明（来自清单）
+	Description string `json:"description,omitempty" bson:"description,omitempty" db:"description"`
+
+	// Digest 包内容摘要（sha256:<hex>）
+	Digest string `json:"digest" bson:"digest" db:"digest"`
+
+	// Size 包大小（字节）
+	Size int64 `json:"size" bson:"size" db:"size"`
+
+	// CreatedAt 上传时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+}
+
+// ObjectKey 技能包在对象存储中的 Key
+func (b *SkillBundle) ObjectKey() string {
+	return "skills/" + b.SkillID + "/" + b.Version + ".tar.gz"
+}
+
 // ============================================================================
 // SkillRegistry - 技能市场
 // ============================================================================
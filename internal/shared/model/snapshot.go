@@ -0,0 +1,320 @@
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ============================================================================
+// Run 快照校验 - 创建与调度时按扁平化 Task 结构校验 Run.Snapshot
+// ============================================================================
+
+// Run 快照是 API Server 创建 Run 时由 Task 展开的扁平化执行配置（见 run/handler.go startRun），
+// NodeManager 只读取快照执行。缺少 agent.type、prompt 等字段的快照原先要到节点执行时才报错，
+// 现在创建 Run 时校验（不合法时返回 422 与字段级错误），调度时再次校验（历史数据不合法时直接失败，不再分派）。
+//
+// 旧版本写入的快照格式由 UpgradeSnapshot 升级为当前格式：
+//   - prompt 为对象 {"content": "..."}（Task.Prompt 的结构）：取 content
+//   - agent 为字符串：视为 agent.type
+//   - 没有 agent 对象、使用顶层 type / agent_type 与 agent_id：移入 agent.type 与 agent.instance_id
+//   - target_node：改名为 node_id
+//   - agent 中没有 parameters、Agent 参数直接写在 agent 中：未声明的字段移入 agent.parameters
+//
+// 校验与升级后的快照可按 RunSnapshot 解析（见 run_snapshot.go）。
+
+// snapshotFields 快照顶层字段的类型（未声明的字段不校验），由 RunSnapshot 的 json 标签生成
+var snapshotFields = fieldsOf(reflect.TypeOf(RunSnapshot{}))
+
+// snapshotAgentFields agent 对象中字段的类型，由 SnapshotAgent 的 json 标签生成
+var snapshotAgentFields = fieldsOf(reflect.TypeOf(SnapshotAgent{}))
+
+// fieldsOf 按结构体的 json 标签生成字段声明
+//
+// 标签中没有 omitempty 的字段为必填；类型按 JSON 编码后的形态推断：
+// 字符串、数值、布尔各自对应，切片为数组，结构体、map 与 json.RawMessage 为对象。
+func fieldsOf(t reflect.Type) []EventField {
+	fields := make([]EventField, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fields = append(fields, EventField{
+			Name:     name,
+			Kind:     fieldKindOf(f.Type),
+			Required: !strings.Contains(","+opts+",", ",omitempty,"),
+		})
+	}
+	return fields
+}
+
+// fieldKindOf Go 类型编码为 JSON 后的字段类型
+func fieldKindOf(t reflect.Type) string {
+	if t == reflect.TypeOf(json.RawMessage{}) {
+		return FieldObject
+	}
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return FieldString
+	case reflect.Int, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return FieldNumber
+	case reflect.Bool:
+		return FieldBool
+	case reflect.Slice, reflect.Array:
+		return FieldArray
+	case reflect.Struct, reflect.Map:
+		return FieldObject
+	}
+	return FieldAny
+}
+
+// SnapshotFieldError 快照中一个不合法的字段
+type SnapshotFieldError struct {
+	Field   string `json:"field"`   // 字段路径，如 agent.type、workspace.git.url
+	Message string `json:"message"` // 错误原因
+}
+
+// SnapshotError 快照校验失败，列出全部不合法的字段（错误信息为 "字段: 原因" 以分号连接）
+type SnapshotError struct {
+	Fields []SnapshotFieldError
+}
+
+func (e *SnapshotError) Error() string {
+	parts := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		parts[i] = f.Field + ": " + f.Message
+	}
+	return strings.Join(parts, "; ")
+}
+
+// snapshotValidator 收集字段错误
+type snapshotValidator struct {
+	errs []SnapshotFieldError
+}
+
+func (v *snapshotValidator) add(field, format string, args ...interface{}) {
+	v.errs = append(v.errs, SnapshotFieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// fields 按声明校验对象中字段的类型，返回类型正确的字段
+func (v *snapshotValidator) fields(prefix string, obj map[string]interface{}, fields []EventField) map[string]bool {
+	valid := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		val, ok := obj[f.Name]
+		if !ok || val == nil {
+			if f.Required {
+				v.add(prefix+f.Name, "is required")
+			}
+			continue
+		}
+		if !matchFieldKind(f.Kind, val) {
+			v.add(prefix+f.Name, "must be %s", f.Kind)
+			continue
+		}
+		if f.Required && f.Kind == FieldString && strings.TrimSpace(val.(string)) == "" {
+			v.add(prefix+f.Name, "must not be empty")
+			continue
+		}
+		valid[f.Name] = true
+	}
+	return valid
+}
+
+// stringElems 校验数组或对象的元素均为字符串
+func (v *snapshotValidator) stringElems(field string, val interface{}) {
+	switch c := val.(type) {
+	case []interface{}:
+		for i, e := range c {
+			if _, ok := e.(string); !ok {
+				v.add(fmt.Sprintf("%s[%d]", field, i), "must be string")
+			}
+		}
+	case map[string]interface{}:
+		keys := make([]string, 0, len(c))
+		for k := range c {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if _, ok := c[k].(string); !ok {
+				v.add(field+"."+k, "must be string")
+			}
+		}
+	}
+}
+
+// ValidateSnapshot 校验快照是否符合当前的扁平化结构
+//
+// 不合法时返回 *SnapshotError（字段按快照中的路径列出）。
+func ValidateSnapshot(snapshot map[string]interface{}) error {
+	v := &snapshotValidator{}
+	valid := v.fields("", snapshot, snapshotFields)
+
+	if valid["agent"] {
+		agent := snapshot["agent"].(map[string]interface{})
+		if v.fields("agent.", agent, snapshotAgentFields)["account_pool"] {
+			v.stringElems("agent.account_pool", agent["account_pool"])
+		}
+	}
+	if valid["labels"] {
+		v.stringElems("labels", snapshot["labels"])
+	}
+	if valid["secrets"] {
+		v.stringElems("secrets", snapshot["secrets"])
+	}
+	if valid["require_approval"] {
+		v.stringElems("require_approval", snapshot["require_approval"])
+	}
+	if valid["runtime"] {
+		switch backend := snapshot["runtime"].(map[string]interface{})["backend"]; backend {
+		case nil, ExecBackendDocker, ExecBackendProcess:
+		default:
+			v.add("runtime.backend", "must be %s or %s", ExecBackendDocker, ExecBackendProcess)
+		}
+	}
+	if valid["timeout_seconds"] {
+		if n, _ := snapshot["timeout_seconds"].(float64); n < 0 {
+			v.add("timeout_seconds", "must not be negative")
+		}
+	}
+	if valid["workspace"] {
+		validateSnapshotWorkspace(v, snapshot["workspace"].(map[string]interface{}))
+	}
+
+	if len(v.errs) > 0 {
+		return &SnapshotError{Fields: v.errs}
+	}
+	return nil
+}
+
+// validateSnapshotWorkspace 校验 workspace：类型合法，git 与 local 类型带有 NodeManager 准备工作空间所需的地址
+func validateSnapshotWorkspace(v *snapshotValidator, ws map[string]interface{}) {
+	wsType, _ := ws["type"].(string)
+	switch WorkspaceType(wsType) {
+	case WorkspaceTypeGit:
+		git, _ := ws["git"].(map[string]interface{})
+		if url, _ := git["url"].(string); strings.TrimSpace(url) == "" {
+			v.add("workspace.git.url", "is required for git workspace")
+		}
+	case WorkspaceTypeLocal:
+		local, _ := ws["local"].(map[string]interface{})
+		if path, _ := local["path"].(string); strings.TrimSpace(path) == "" {
+			v.add("workspace.local.path", "is required for local workspace")
+		}
+	case WorkspaceTypeRemote, WorkspaceTypeVolume:
+	case "":
+		v.add("workspace.type", "is required")
+	default:
+		v.add("workspace.type", "must be one of git, local, remote, volume")
+	}
+}
+
+// UpgradeSnapshot 将旧版本格式的快照就地升级为当前格式，返回是否有改动
+func UpgradeSnapshot(snapshot map[string]interface{}) bool {
+	upgraded := false
+
+	if p, ok := snapshot["prompt"].(map[string]interface{}); ok {
+		if content, ok := p["content"].(string); ok {
+			snapshot["prompt"] = content
+			upgraded = true
+		}
+	}
+
+	switch agent := snapshot["agent"].(type) {
+	case string:
+		snapshot["agent"] = map[string]interface{}{"type": agent}
+		upgraded = true
+	case nil:
+		agentType, _ := snapshot["agent_type"].(string)
+		if agentType == "" {
+			agentType, _ = snapshot["type"].(string)
+		}
+		if agentType == "" {
+			break
+		}
+		legacy := map[string]interface{}{"type": agentType}
+		if id, ok := snapshot["agent_id"].(string); ok && id != "" {
+			legacy["instance_id"] = id
+		}
+		for _, k := range []string{"agent_type", "type", "agent_id"} {
+			delete(snapshot, k)
+		}
+		snapshot["agent"] = legacy
+		upgraded = true
+	}
+
+	if agent, ok := snapshot["agent"].(map[string]interface{}); ok && agent["parameters"] == nil {
+		params := map[string]interface{}{}
+		for k, v := range agent {
+			if !isSnapshotAgentField(k) {
+				params[k] = v
+				delete(agent, k)
+			}
+		}
+		if len(params) > 0 {
+			agent["parameters"] = params
+			upgraded = true
+		}
+	}
+
+	if node, ok := snapshot["target_node"].(string); ok {
+		if _, exists := snapshot["node_id"]; !exists && node != "" {
+			snapshot["node_id"] = node
+		}
+		delete(snapshot, "target_node")
+		upgraded = true
+	}
+	return upgraded
+}
+
+// isSnapshotAgentField 判断是否为 agent 中声明的字段
+func isSnapshotAgentField(name string) bool {
+	for _, f := range snapshotAgentFields {
+		if f.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeSnapshot 解析快照 JSON，升级旧版本格式后校验
+//
+// 返回当前格式的快照（无需升级时原样返回）；不合法时返回 *SnapshotError。
+func NormalizeSnapshot(raw json.RawMessage) (json.RawMessage, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return raw, &SnapshotError{Fields: []SnapshotFieldError{{Field: "snapshot", Message: "is required"}}}
+	}
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(raw, &snapshot); err != nil {
+		return raw, &SnapshotError{Fields: []SnapshotFieldError{{Field: "snapshot", Message: "must be a JSON object"}}}
+	}
+	upgraded := UpgradeSnapshot(snapshot)
+	if err := ValidateSnapshot(snapshot); err != nil {
+		return raw, err
+	}
+	data := raw
+	if upgraded {
+		var err error
+		if data, err = json.Marshal(snapshot); err != nil {
+			return raw, err
+		}
+	}
+	// 嵌套字段（limits、hooks 等）按 RunSnapshot 的类型校验
+	if _, err := ParseRunSnapshot(data); err != nil {
+		field := SnapshotFieldError{Field: "snapshot", Message: err.Error()}
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			field = SnapshotFieldError{Field: typeErr.Field, Message: "must be " + typeErr.Type.String()}
+		}
+		return raw, &SnapshotError{Fields: []SnapshotFieldError{field}}
+	}
+	return data, nil
+}
@@ -0,0 +1,128 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidateSnapshot 校验快照字段，列出全部不合法的字段
+func TestValidateSnapshot(t *testing.T) {
+	valid := map[string]interface{}{
+		"task_id":         "task-1",
+		"agent":           map[string]interface{}{"type": "qwen-code", "account_pool": []interface{}{"acc-1"}},
+		"prompt":          "fix the bug",
+		"workspace":       map[string]interface{}{"type": "git", "git": map[string]interface{}{"url": "https://example.com/repo.git"}},
+		"labels":          map[string]interface{}{"exec-backend": "docker"},
+		"runtime":         map[string]interface{}{"backend": "docker"},
+		"timeout_seconds": float64(600),
+	}
+	require.NoError(t, ValidateSnapshot(valid))
+
+	err := ValidateSnapshot(map[string]interface{}{
+		"agent":           map[string]interface{}{"type": "", "account_pool": []interface{}{"acc-1", 2.0}},
+		"workspace":       map[string]interface{}{"type": "local", "local": map[string]interface{}{}},
+		"labels":          map[string]interface{}{"env": true},
+		"runtime":         map[string]interface{}{"backend": "vm"},
+		"secrets":         "GIT_TOKEN",
+		"timeout_seconds": float64(-1),
+	})
+	var se *SnapshotError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, []SnapshotFieldError{
+		{Field: "prompt", Message: "is required"},
+		{Field: "secrets", Message: "must be array"},
+		{Field: "agent.type", Message: "must not be empty"},
+		{Field: "agent.account_pool[1]", Message: "must be string"},
+		{Field: "labels.env", Message: "must be string"},
+		{Field: "runtime.backend", Message: "must be docker or process"},
+		{Field: "timeout_seconds", Message: "must not be negative"},
+		{Field: "workspace.local.path", Message: "is required for local workspace"},
+	}, se.Fields)
+	assert.Contains(t, err.Error(), "prompt: is required; secrets: must be array")
+}
+
+// TestSnapshotFields 校验用的字段声明由 RunSnapshot / SnapshotAgent 的 json 标签生成，与结构保持一致
+func TestSnapshotFields(t *testing.T) {
+	assert.Equal(t, []EventField{
+		{Name: "task_id", Kind: FieldString},
+		{Name: "name", Kind: FieldString},
+		{Name: "agent", Kind: FieldObject, Required: true},
+		{Name: "prompt", Kind: FieldString, Required: true},
+		{Name: "workspace", Kind: FieldObject},
+		{Name: "labels", Kind: FieldObject},
+		{Name: "runtime", Kind: FieldObject},
+		{Name: "secrets", Kind: FieldArray},
+		{Name: "limits", Kind: FieldObject},
+		{Name: "require_approval", Kind: FieldArray},
+		{Name: "command_filter", Kind: FieldObject},
+		{Name: "security_policy", Kind: FieldObject},
+		{Name: "timeout_seconds", Kind: FieldNumber},
+		{Name: "hooks", Kind: FieldObject},
+		{Name: "skills", Kind: FieldArray},
+		{Name: "inherited_context", Kind: FieldArray},
+		{Name: "session", Kind: FieldObject},
+		{Name: "node_id", Kind: FieldString},
+		{Name: "replay", Kind: FieldObject},
+	}, snapshotFields)
+	assert.Equal(t, []EventField{
+		{Name: "type", Kind: FieldString, Required: true},
+		{Name: "instance_id", Kind: FieldString},
+		{Name: "account_id", Kind: FieldString},
+		{Name: "account_pool", Kind: FieldArray},
+		{Name: "model", Kind: FieldString},
+		{Name: "parameters", Kind: FieldObject},
+	}, snapshotAgentFields)
+
+	// 新增字段后按新结构校验
+	err := ValidateSnapshot(map[string]interface{}{
+		"agent":  map[string]interface{}{"type": "qwen-code"},
+		"prompt": "replay",
+		"replay": "run-1",
+	})
+	var se *SnapshotError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, []SnapshotFieldError{{Field: "replay", Message: "must be object"}}, se.Fields)
+}
+
+// TestNormalizeSnapshot 旧版本格式升级为当前格式后校验，当前格式原样返回
+func TestNormalizeSnapshot(t *testing.T) {
+	current := json.RawMessage(`{"agent":{"type":"qwen-code"},"prompt":"hi"}`)
+	got, err := NormalizeSnapshot(current)
+	require.NoError(t, err)
+	assert.Equal(t, string(current), string(got))
+
+	got, err = NormalizeSnapshot(json.RawMessage(`{"type":"qwen-code","agent_id":"inst-1","prompt":{"content":"hi"},"target_node":"node-1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"agent":{"type":"qwen-code","instance_id":"inst-1"},"prompt":"hi","node_id":"node-1"}`, string(got))
+
+	got, err = NormalizeSnapshot(json.RawMessage(`{"agent":"gemini","prompt":"hi","node_id":"node-2","target_node":"node-1"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"agent":{"type":"gemini"},"prompt":"hi","node_id":"node-2"}`, string(got))
+
+	// Agent 参数直接写在 agent 中的旧格式
+	got, err = NormalizeSnapshot(json.RawMessage(`{"agent":{"type":"qwen-code","yolo":true,"max_turns":5},"prompt":"hi"}`))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"agent":{"type":"qwen-code","parameters":{"yolo":true,"max_turns":5}},"prompt":"hi"}`, string(got))
+
+	// 嵌套字段按 RunSnapshot 的类型校验
+	_, err = NormalizeSnapshot(json.RawMessage(`{"agent":{"type":"qwen-code"},"prompt":"hi","limits":{"max_processes":"many"}}`))
+	var se *SnapshotError
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, []SnapshotFieldError{{Field: "limits.max_processes", Message: "must be int"}}, se.Fields)
+
+	_, err = NormalizeSnapshot(json.RawMessage(`{"prompt":{"text":"hi"}}`))
+	require.ErrorAs(t, err, &se)
+	assert.Equal(t, []SnapshotFieldError{
+		{Field: "agent", Message: "is required"},
+		{Field: "prompt", Message: "must be string"},
+	}, se.Fields)
+
+	for _, raw := range []string{``, `null`, `[1]`} {
+		_, err = NormalizeSnapshot(json.RawMessage(raw))
+		require.ErrorAs(t, err, &se, raw)
+		assert.Equal(t, "snapshot", se.Fields[0].Field)
+	}
+}
@@ -8,10 +8,16 @@
 //   - WorkspaceConfig：工作空间配置
 //   - SecurityConfig：安全配置
 //
+// 生命周期钩子（LifecycleHooks）定义见 hook.go
+//
 // 注意：TaskContext/ContextItem/Message 已移至 context.go
 package model
 
 import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -70,6 +76,9 @@ type TaskTemplate struct {
 	// DefaultLabels 默认标签
 	DefaultLabels map[string]string `json:"default_labels,omitempty" bson:"default_labels,omitempty" db:"default_labels"`
 
+	// DefaultHooks 默认生命周期钩子
+	DefaultHooks *LifecycleHooks `json:"default_hooks,omitempty" bson:"default_hooks,omitempty" db:"default_hooks"`
+
 	// === 变量定义 ===
 
 	// Variables 模板变量定义（用于 PromptTemplate 中的变量）
@@ -123,6 +132,7 @@ func (t *TaskTemplate) CreateTask(name string, variables map[string]interface{})
 		Workspace:   t.DefaultWorkspace,
 		Security:    t.DefaultSecurity,
 		Labels:      t.DefaultLabels,
+		Hooks:       t.DefaultHooks,
 		TemplateID:  &t.ID,
 	}
 
@@ -202,6 +212,55 @@ const (
 	TaskStatusCancelled TaskStatus = "cancelled"
 )
 
+// ============================================================================
+// Priority - 调度优先级枚举
+// ============================================================================
+
+// Priority 任务/执行的调度优先级
+//
+// 调度器按 high → normal → low 的顺序消费待调度的 Run；
+// 启用抢占时，high 优先级 Run 可替换饱和节点上尚未开始执行的 low 优先级分配。
+type Priority string
+
+const (
+	// PriorityHigh 高优先级：优先调度，可抢占低优先级分配
+	PriorityHigh Priority = "high"
+
+	// PriorityNormal 普通优先级（默认）
+	PriorityNormal Priority = "normal"
+
+	// PriorityLow 低优先级：空闲时调度，可被抢占
+	PriorityLow Priority = "low"
+)
+
+// IsValid 检查优先级是否合法（空值视为合法，表示默认优先级）
+func (p Priority) IsValid() bool {
+	switch p {
+	case "", PriorityHigh, PriorityNormal, PriorityLow:
+		return true
+	}
+	return false
+}
+
+// OrDefault 返回优先级，空值时返回 PriorityNormal
+func (p Priority) OrDefault() Priority {
+	if p == "" {
+		return PriorityNormal
+	}
+	return p
+}
+
+// Rank 返回优先级排序值（越小越优先）
+func (p Priority) Rank() int {
+	switch p {
+	case PriorityHigh:
+		return 0
+	case PriorityLow:
+		return 2
+	}
+	return 1
+}
+
 // ============================================================================
 // WorkspaceType - 工作空间类型枚举
 // ============================================================================
@@ -282,6 +341,51 @@ type GitConfig struct {
 
 	// Depth 克隆深度（shallow clone），0 表示完整克隆
 	Depth int `json:"depth,omitempty"`
+
+	// CredentialRef 凭据名称引用（/api/v1/credentials），用于克隆私有仓库和结果回写
+	CredentialRef string `json:"credential_ref,omitempty"`
+
+	// Sync 结果回写配置（可选），Run 成功后将变更提交并推送回仓库
+	Sync *GitSyncConfig `json:"sync,omitempty"`
+
+	// PullRequest 触发任务的 PR/MR（可选），项目启用结果发布时 Run 结束后向其发表评论/提交状态
+	PullRequest *PullRequestRef `json:"pull_request,omitempty"`
+}
+
+// PullRequestRef 触发任务的 Pull Request / Merge Request
+type PullRequestRef struct {
+	// Number PR 编号（GitHub）或 MR IID（GitLab）
+	Number int `json:"number"`
+
+	// HeadSHA PR 头部提交 SHA，用于提交状态；为空时使用 GitConfig.Commit
+	HeadSHA string `json:"head_sha,omitempty"`
+}
+
+// GitSyncConfig Git 结果回写配置
+//
+// Run 成功完成后，NodeManager 在生成的分支上提交 Agent 的变更并推送，
+// 可选地创建 GitHub Pull Request / GitLab Merge Request，PR 地址作为 Run 产物上报。
+type GitSyncConfig struct {
+	// Enabled 是否启用结果回写
+	Enabled bool `json:"enabled"`
+
+	// BranchPrefix 生成分支前缀，默认 "agents/"，完整分支名为 {prefix}{run_id}
+	BranchPrefix string `json:"branch_prefix,omitempty"`
+
+	// CommitMessage 提交信息，默认使用任务名称
+	CommitMessage string `json:"commit_message,omitempty"`
+
+	// CreatePR 是否创建 Pull Request / Merge Request
+	CreatePR bool `json:"create_pr,omitempty"`
+
+	// Provider 代码托管平台：github / gitlab，为空时根据 URL 推断
+	Provider string `json:"provider,omitempty"`
+
+	// TargetBranch PR 目标分支，默认使用克隆时的分支
+	TargetBranch string `json:"target_branch,omitempty"`
+
+	// PRTitle PR 标题，默认同提交信息
+	PRTitle string `json:"pr_title,omitempty"`
 }
 
 // LocalConfig 本地目录配置
@@ -349,6 +453,67 @@ type SecurityConfig struct {
 	// RequireApproval 需要人工审批的操作列表
 	// 例如：["file_delete", "command_execute"]
 	RequireApproval []string `json:"require_approval,omitempty"`
+
+	// Commands 命令过滤规则（在内置危险命令检测基础上调整）
+	Commands *CommandFilter `json:"commands,omitempty"`
+}
+
+// CommandFilterAction Agent 执行命中规则的命令时的处理方式
+type CommandFilterAction string
+
+const (
+	// CommandFilterBlock 终止 Agent，Run 以 failed 结束
+	CommandFilterBlock CommandFilterAction = "block"
+	// CommandFilterApproval 暂停执行等待人工审批
+	CommandFilterApproval CommandFilterAction = "approval"
+)
+
+// CommandFilter 命令过滤规则
+//
+// 规则为正则表达式，匹配 Agent 执行的完整命令行：
+//   - Deny：命中即拦截
+//   - Allow：命中时不再按内置危险命令（如 rm -rf /、curl ... | sh）检测，不豁免 Deny
+type CommandFilter struct {
+	// Allow 放行的命令
+	Allow []string `json:"allow,omitempty"`
+
+	// Deny 禁止的命令
+	Deny []string `json:"deny,omitempty"`
+
+	// Action 处理方式，为空时 strict 策略为 block，其他为 approval
+	Action CommandFilterAction `json:"action,omitempty"`
+}
+
+// Validate 校验规则格式
+func (f *CommandFilter) Validate() error {
+	if f == nil {
+		return nil
+	}
+	if f.Action != "" && f.Action != CommandFilterBlock && f.Action != CommandFilterApproval {
+		return fmt.Errorf("commands.action: invalid action %q", f.Action)
+	}
+	for _, rules := range []struct {
+		field    string
+		patterns []string
+	}{{"allow", f.Allow}, {"deny", f.Deny}} {
+		for _, p := range rules.patterns {
+			if _, err := regexp.Compile(p); err != nil {
+				return fmt.Errorf("commands.%s: invalid pattern %q: %v", rules.field, p, err)
+			}
+		}
+	}
+	return nil
+}
+
+// CommandAction 命中命令过滤规则时的处理方式（未指定时按安全策略等级）
+func (c *SecurityConfig) CommandAction() CommandFilterAction {
+	if c != nil && c.Commands != nil && c.Commands.Action != "" {
+		return c.Commands.Action
+	}
+	if c != nil && c.Policy == SecurityPolicyStrict {
+		return CommandFilterBlock
+	}
+	return CommandFilterApproval
 }
 
 // NetworkPolicy 网络访问策略
@@ -387,6 +552,72 @@ type ResourceLimits struct {
 	MaxOpenFiles int `json:"max_open_files,omitempty"`
 }
 
+// byteUnits 内存数量单位（二进制单位与十进制单位）
+var byteUnits = []struct {
+	suffix string
+	factor int64
+}{
+	{"Ki", 1 << 10}, {"Mi", 1 << 20}, {"Gi", 1 << 30}, {"Ti", 1 << 40},
+	{"K", 1e3}, {"M", 1e6}, {"G", 1e9}, {"T", 1e12},
+}
+
+// ParseCPUQuantity 解析 CPU 核数（如 "2"、"1.5"、"500m"）
+func ParseCPUQuantity(s string) (float64, error) {
+	s = strings.TrimSpace(s)
+	scale := 1.0
+	if v, ok := strings.CutSuffix(s, "m"); ok {
+		s, scale = v, 0.001
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid cpu quantity %q", s)
+	}
+	return v * scale, nil
+}
+
+// ParseByteQuantity 解析字节数（如 "4Gi"、"512Mi"、"1G"、"1048576"）
+func ParseByteQuantity(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	factor := int64(1)
+	for _, u := range byteUnits {
+		if v, ok := strings.CutSuffix(s, u.suffix); ok {
+			s, factor = v, u.factor
+			break
+		}
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("invalid byte quantity %q", s)
+	}
+	return int64(v * float64(factor)), nil
+}
+
+// Validate 校验资源限制格式
+func (l *ResourceLimits) Validate() error {
+	if l == nil {
+		return nil
+	}
+	if l.MaxCPU != "" {
+		if _, err := ParseCPUQuantity(l.MaxCPU); err != nil {
+			return fmt.Errorf("limits.max_cpu: %w", err)
+		}
+	}
+	if l.MaxMemory != "" {
+		if _, err := ParseByteQuantity(l.MaxMemory); err != nil {
+			return fmt.Errorf("limits.max_memory: %w", err)
+		}
+	}
+	if l.MaxDisk != "" {
+		if _, err := ParseByteQuantity(l.MaxDisk); err != nil {
+			return fmt.Errorf("limits.max_disk: %w", err)
+		}
+	}
+	if l.MaxProcesses < 0 || l.MaxOpenFiles < 0 {
+		return fmt.Errorf("limits: max_processes and max_open_files must not be negative")
+	}
+	return nil
+}
+
 // ============================================================================
 // Task - 扁平化的任务结构（合并原 TaskSpec）
 // ============================================================================
@@ -410,8 +641,9 @@ type ResourceLimits struct {
 // 字段分组：
 //  1. 基础字段：ID, Name, Description, Status
 //  2. 核心内容：Prompt, Context
-//  3. 配置字段（可继承）：Workspace, Security, Labels
-//  4. 关联字段：TemplateID, AgentID, ParentID
+//  3. 配置字段（可继承）：Workspace, Security, Labels, Hooks
+//     运行时密钥引用：Secrets
+//  4. 关联字段：TemplateID, AgentID, ParentID, ProjectID
 //  5. 时间戳：CreatedAt, UpdatedAt
 type Task struct {
 	// === 基础字段 ===
@@ -453,6 +685,27 @@ type Task struct {
 	// Labels 任务标签（与模板的 DefaultLabels 合并）
 	Labels map[string]string `json:"labels,omitempty" bson:"labels,omitempty" db:"labels"`
 
+	// Hooks 生命周期钩子（未设置时使用模板的 DefaultHooks）
+	Hooks *LifecycleHooks `json:"hooks,omitempty" bson:"hooks,omitempty" db:"hooks"`
+
+	// Secrets 引用的密钥名称，执行时以同名环境变量注入 Agent 容器
+	Secrets []string `json:"secrets,omitempty" bson:"secrets,omitempty" db:"secrets"`
+
+	// Priority 调度优先级（high/normal/low，为空时视为 normal），创建 Run 时复制到 Run.Priority
+	Priority Priority `json:"priority,omitempty" bson:"priority,omitempty" db:"priority"`
+
+	// TimeoutSeconds 单次执行时限（秒，0 表示使用调度器 watchdog.default_timeout），创建 Run 时写入执行快照
+	TimeoutSeconds int `json:"timeout_seconds,omitempty" bson:"timeout_seconds,omitempty" db:"timeout_seconds"`
+
+	// Scheduling 调度约束（亲和、反亲和、分散与污点容忍，为空时仅按标签与节点亲和性调度）
+	Scheduling *TaskScheduling `json:"scheduling,omitempty" bson:"scheduling,omitempty" db:"scheduling"`
+
+	// Region 希望执行的区域（见 Region，为空时不限区域），调度器优先选择带相同 region 标签的节点
+	Region string `json:"region,omitempty" bson:"region,omitempty" db:"region"`
+
+	// Session 持久 Agent 会话（启用后 Run 结束时保留执行容器，可通过追问在同一会话中继续，见 TaskSession）
+	Session *TaskSession `json:"session,omitempty" bson:"session,omitempty" db:"session"`
+
 	// === 关联字段 ===
 
 	// TemplateID 关联的任务模板 ID（通过模板获取 Type 和默认配置）
@@ -464,6 +717,12 @@ type Task struct {
 	// ParentID 父任务 ID（顶层任务为空）
 	ParentID *string `json:"parent_id,omitempty" bson:"parent_id,omitempty" db:"parent_id"`
 
+	// SpawnedByRunID 派生该子任务的 Run ID（Agent 执行中通过 spawn_subtask 拆分任务时设置，ParentID 为该 Run 所属任务）
+	SpawnedByRunID *string `json:"spawned_by_run_id,omitempty" bson:"spawned_by_run_id,omitempty" db:"spawned_by_run_id"`
+
+	// ProjectID 所属项目 ID（从项目继承默认 Agent 类型、账号池和安全配置）
+	ProjectID *string `json:"project_id,omitempty" bson:"project_id,omitempty" db:"project_id"`
+
 	// === 时间戳 ===
 
 	// CreatedAt 创建时间
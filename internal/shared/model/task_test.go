@@ -790,3 +790,75 @@ func TestBuiltinTaskTemplates(t *testing.T) {
 		assert.Equal(t, "builtin", tpl.Source)
 	}
 }
+
+// TestLifecycleHooks_Validate 验证钩子配置校验
+func TestLifecycleHooks_Validate(t *testing.T) {
+	var empty *LifecycleHooks
+	assert.True(t, empty.IsEmpty())
+	assert.NoError(t, empty.Validate())
+
+	valid := &LifecycleHooks{
+		PreRun:    []HookSpec{{Name: "install", Script: "npm ci"}},
+		OnFailure: []HookSpec{{Name: "notify", SkillID: "skill-notify"}},
+	}
+	assert.False(t, valid.IsEmpty())
+	require.NoError(t, valid.Validate())
+
+	cases := []*LifecycleHooks{
+		{PreRun: []HookSpec{{Script: "true"}}},                                   // 缺少 name
+		{PostRun: []HookSpec{{Name: "x"}}},                                       // 缺少脚本
+		{PostRun: []HookSpec{{Name: "x", Script: "true", SkillID: "s"}}},         // 两者同时设置
+		{OnFailure: []HookSpec{{Name: "x", Script: "true", TimeoutSeconds: -1}}}, // 负超时
+	}
+	for _, c := range cases {
+		assert.Error(t, c.Validate())
+	}
+
+	// 模板默认钩子随 CreateTask 继承
+	tmpl := &TaskTemplate{ID: "tmpl-1", DefaultHooks: valid}
+	assert.Equal(t, valid, tmpl.CreateTask("t", nil).Hooks)
+}
+
+func TestResourceLimits_Quantities(t *testing.T) {
+	cpu, err := ParseCPUQuantity("500m")
+	require.NoError(t, err)
+	assert.InDelta(t, 0.5, cpu, 1e-9)
+	cpu, err = ParseCPUQuantity("2.0")
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, cpu)
+
+	bytes, err := ParseByteQuantity("4Gi")
+	require.NoError(t, err)
+	assert.Equal(t, int64(4<<30), bytes)
+	bytes, err = ParseByteQuantity("1.5M")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1500000), bytes)
+	bytes, err = ParseByteQuantity("1048576")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1<<20), bytes)
+
+	for _, s := range []string{"", "abc", "-1", "0", "4GB"} {
+		_, err := ParseByteQuantity(s)
+		assert.Error(t, err, s)
+	}
+
+	var none *ResourceLimits
+	assert.NoError(t, none.Validate())
+	assert.NoError(t, (&ResourceLimits{MaxCPU: "2", MaxMemory: "4Gi", MaxProcesses: 256}).Validate())
+	assert.Error(t, (&ResourceLimits{MaxCPU: "two"}).Validate())
+	assert.Error(t, (&ResourceLimits{MaxMemory: "lots"}).Validate())
+	assert.Error(t, (&ResourceLimits{MaxOpenFiles: -1}).Validate())
+}
+
+func TestCommandFilter_Validate(t *testing.T) {
+	var none *CommandFilter
+	assert.NoError(t, none.Validate())
+	assert.NoError(t, (&CommandFilter{Allow: []string{`^rm -rf /tmp/`}, Deny: []string{`\bgit push\b`}, Action: CommandFilterBlock}).Validate())
+	assert.Error(t, (&CommandFilter{Deny: []string{`(`}}).Validate())
+	assert.Error(t, (&CommandFilter{Action: "ignore"}).Validate())
+
+	// 未指定处理方式时 strict 策略直接拦截
+	assert.Equal(t, CommandFilterApproval, (*SecurityConfig)(nil).CommandAction())
+	assert.Equal(t, CommandFilterBlock, (&SecurityConfig{Policy: SecurityPolicyStrict}).CommandAction())
+	assert.Equal(t, CommandFilterApproval, (&SecurityConfig{Policy: SecurityPolicyStrict, Commands: &CommandFilter{Action: CommandFilterApproval}}).CommandAction())
+}
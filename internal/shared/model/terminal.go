@@ -39,9 +39,14 @@ const (
 //
 // 终端会话用于提供 Agent 容器的交互式终端访问。
 // 状态由 Executor 管理并上报。
+//
+// 附加到 Run 的会话（RunID 非空）只能由创建者或管理员连接，
+// Run 结束或到达 ExpiresAt 后会话关闭。
 type TerminalSession struct {
 	ID            string                `json:"id" bson:"_id" db:"id"`
 	InstanceID    *string               `json:"instance_id" bson:"instance_id" db:"instance_id"`       // 目标实例 ID（可选）
+	RunID         *string               `json:"run_id,omitempty" bson:"run_id,omitempty" db:"run_id"`         // 目标 Run ID（Run 终端附加）
+	CreatedBy     *string               `json:"created_by,omitempty" bson:"created_by,omitempty" db:"created_by"` // 创建者用户 ID（未启用认证时为空）
 	ContainerName string                `json:"container_name" bson:"container_name" db:"container_name"` // 目标容器名
 	NodeID        *string               `json:"node_id" bson:"node_id" db:"node_id"`               // 节点 ID
 	Port          *int                  `json:"port" bson:"port" db:"port"`                     // ttyd 端口（Executor 回填）
@@ -60,6 +65,11 @@ func (ts *TerminalSession) IsActive() bool {
 	return ts.Status == TerminalStatusRunning || ts.Status == TerminalStatusStarting
 }
 
+// IsExpired 判断终端会话是否已过期
+func (ts *TerminalSession) IsExpired(now time.Time) bool {
+	return ts.ExpiresAt != nil && !now.Before(*ts.ExpiresAt)
+}
+
 // IsTerminated 判断终端会话是否已终止
 func (ts *TerminalSession) IsTerminated() bool {
 	return ts.Status == TerminalStatusClosed || ts.Status == TerminalStatusError
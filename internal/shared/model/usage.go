@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// ============================================================================
+// RunUsage - Run Token 用量与费用
+// ============================================================================
+
+// UsageDayLayout 用量日期格式（UTC）
+const UsageDayLayout = "2006-01-02"
+
+// RunUsage 一次 Run 累计的 Token 用量与费用
+//
+// 由节点上报的 usage 事件按 Run 累加：
+//   - TaskID / ProjectID / AccountID / AgentType / Day 在首次写入时确定，用于按任务、项目、账号、Agent 类型、日期汇总
+//   - Day 为首次报告用量的 UTC 日期（YYYY-MM-DD）
+//   - Model 为最近一次报告的模型名称
+type RunUsage struct {
+	// RunID 所属 Run ID（主键）
+	RunID string `json:"run_id" bson:"_id" db:"run_id"`
+
+	// TaskID 所属任务 ID
+	TaskID string `json:"task_id" bson:"task_id" db:"task_id"`
+
+	// ProjectID 所属任务的项目 ID（非项目任务为空）
+	ProjectID string `json:"project_id,omitempty" bson:"project_id,omitempty" db:"project_id"`
+
+	// AccountID 执行使用的账号 ID（未绑定账号时为空）
+	AccountID string `json:"account_id,omitempty" bson:"account_id,omitempty" db:"account_id"`
+
+	// AgentType Agent 类型（如 claude、qwen-code）
+	AgentType string `json:"agent_type,omitempty" bson:"agent_type,omitempty" db:"agent_type"`
+
+	// Model 模型名称
+	Model string `json:"model,omitempty" bson:"model,omitempty" db:"model"`
+
+	// InputTokens 输入 Token（不含缓存命中）
+	InputTokens int64 `json:"input_tokens" bson:"input_tokens" db:"input_tokens"`
+
+	// OutputTokens 输出 Token
+	OutputTokens int64 `json:"output_tokens" bson:"output_tokens" db:"output_tokens"`
+
+	// CacheReadTokens 缓存读取 Token
+	CacheReadTokens int64 `json:"cache_read_tokens" bson:"cache_read_tokens" db:"cache_read_tokens"`
+
+	// CacheWriteTokens 缓存写入 Token
+	CacheWriteTokens int64 `json:"cache_write_tokens" bson:"cache_write_tokens" db:"cache_write_tokens"`
+
+	// CostUSD 费用（美元）
+	CostUSD float64 `json:"cost_usd" bson:"cost_usd" db:"cost_usd"`
+
+	// Day 用量日期（UTC，YYYY-MM-DD）
+	Day string `json:"day" bson:"day" db:"day"`
+
+	// CreatedAt 首次写入时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+
+	// UpdatedAt 最近一次累加时间
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// TotalTokens 输入、输出与缓存 Token 之和
+func (u *RunUsage) TotalTokens() int64 {
+	return u.InputTokens + u.OutputTokens + u.CacheReadTokens + u.CacheWriteTokens
+}
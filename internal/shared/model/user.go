@@ -29,3 +29,36 @@ type User struct {
 	CreatedAt    time.Time  `json:"created_at" bson:"created_at" db:"created_at"`
 	UpdatedAt    time.Time  `json:"updated_at" bson:"updated_at" db:"updated_at"`
 }
+
+// UserMFA 用户两步验证（TOTP）
+//
+// 启用前（Enabled 为 false）保存待确认的密钥；用户以验证器中的动态码确认后启用并生成备用码。
+type UserMFA struct {
+	UserID      string    `json:"user_id" bson:"_id" db:"user_id"`
+	Secret      string    `json:"-" bson:"secret" db:"secret"`             // TOTP 密钥（MASTER_KEY 加密）
+	Enabled     bool      `json:"enabled" bson:"enabled" db:"enabled"`     // 是否已确认启用
+	BackupCodes []string  `json:"-" bson:"backup_codes" db:"backup_codes"` // 未使用备用码的 SHA-256
+	LastStep    int64     `json:"-" bson:"last_step" db:"last_step"`       // 最近一次通过校验的 TOTP 时间步（防重放）
+	CreatedAt   time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// UserSession 用户登录会话
+//
+// 每次登录创建一个会话，刷新令牌与访问令牌携带会话 ID；会话撤销后刷新令牌与已签发的访问令牌均失效。
+type UserSession struct {
+	ID         string     `json:"id" bson:"_id" db:"id"`
+	UserID     string     `json:"user_id" bson:"user_id" db:"user_id"`
+	Method     string     `json:"method" bson:"method" db:"method"` // 登录方式：password / password+2fa / oidc / register
+	UserAgent  string     `json:"user_agent,omitempty" bson:"user_agent,omitempty" db:"user_agent"`
+	IP         string     `json:"ip,omitempty" bson:"ip,omitempty" db:"ip"`
+	CreatedAt  time.Time  `json:"created_at" bson:"created_at" db:"created_at"`
+	LastUsedAt time.Time  `json:"last_used_at" bson:"last_used_at" db:"last_used_at"` // 最近一次刷新令牌的时间
+	ExpiresAt  time.Time  `json:"expires_at" bson:"expires_at" db:"expires_at"`       // 刷新令牌过期时间
+	RevokedAt  *time.Time `json:"revoked_at,omitempty" bson:"revoked_at,omitempty" db:"revoked_at"`
+}
+
+// Active 会话是否仍有效（未撤销且未过期）
+func (s *UserSession) Active(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -0,0 +1,72 @@
+// Package model 定义核心数据模型
+//
+// webhook.go 包含 Webhook 通知相关的数据模型定义：
+//   - Webhook：Webhook 订阅（投递地址、签名密钥与过滤条件）
+//   - WebhookFilter：订阅过滤条件（事件类型、项目、模板、节点、标签选择器、状态迁移）
+//   - WebhookEvent：投递给订阅方的通知事件
+package model
+
+import "time"
+
+// 通知事件类型
+const (
+	WebhookEventRunCreated       = "run.created"        // Run 创建
+	WebhookEventRunStatusChanged = "run.status_changed" // Run 状态迁移（FromStatus → ToStatus）
+	WebhookEventRunEventPrefix   = "run.event."         // Agent 上报事件，完整类型为 run.event.<事件类型>，如 run.event.tool_use
+	WebhookEventPing             = "ping"               // 手动测试投递
+	WebhookEventBudgetAlert      = "budget.alert"       // 预算已用比例达到预警阈值（每个周期一次）
+	WebhookEventBudgetExceeded   = "budget.exceeded"    // 预算耗尽，新 Run 暂停分派（每个周期一次）
+)
+
+// WebhookFilter Webhook 订阅过滤条件
+//
+// 各条件之间为 AND，同一条件的多个值为 OR，空条件不限制。
+type WebhookFilter struct {
+	// EventTypes 事件类型，支持以 * 结尾的前缀通配（如 run.event.*）
+	EventTypes []string `json:"event_types,omitempty" bson:"event_types,omitempty"`
+	// ProjectIDs Run 所属任务的项目
+	ProjectIDs []string `json:"project_ids,omitempty" bson:"project_ids,omitempty"`
+	// TemplateIDs Run 所属任务的模板
+	TemplateIDs []string `json:"template_ids,omitempty" bson:"template_ids,omitempty"`
+	// NodeIDs 执行 Run 的节点
+	NodeIDs []string `json:"node_ids,omitempty" bson:"node_ids,omitempty"`
+	// LabelSelector 任务标签选择器，逗号分隔：env=prod、team!=infra、gpu（存在）、!canary（不存在）
+	LabelSelector string `json:"label_selector,omitempty" bson:"label_selector,omitempty"`
+	// Transitions 状态迁移（仅匹配 run.status_changed），格式 from->to，任一侧可为 *，如 running->failed、*->done
+	Transitions []string `json:"transitions,omitempty" bson:"transitions,omitempty"`
+}
+
+// Webhook Webhook 订阅
+type Webhook struct {
+	ID        string        `json:"id" bson:"_id" db:"id"`
+	Name      string        `json:"name" bson:"name" db:"name"`
+	URL       string        `json:"url" bson:"url" db:"url"`
+	Secret    string        `json:"-" bson:"secret" db:"secret"` // HMAC-SHA256 签名密钥，只在创建时返回一次
+	Enabled   bool          `json:"enabled" bson:"enabled" db:"enabled"`
+	Filter    WebhookFilter `json:"filter" bson:"filter" db:"filter"`
+	CreatedBy string        `json:"created_by,omitempty" bson:"created_by,omitempty" db:"created_by"`
+
+	// 最近一次投递结果
+	LastDeliveryAt *time.Time `json:"last_delivery_at,omitempty" bson:"last_delivery_at,omitempty" db:"last_delivery_at"`
+	LastStatus     int        `json:"last_status,omitempty" bson:"last_status,omitempty" db:"last_status"` // HTTP 状态码，0 表示请求未完成
+	LastError      string     `json:"last_error,omitempty" bson:"last_error,omitempty" db:"last_error"`
+
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+}
+
+// WebhookEvent 通知事件（投递请求体）
+type WebhookEvent struct {
+	ID         string                 `json:"id"`
+	Type       string                 `json:"type"`
+	Timestamp  time.Time              `json:"timestamp"`
+	RunID      string                 `json:"run_id,omitempty"`
+	TaskID     string                 `json:"task_id,omitempty"`
+	ProjectID  string                 `json:"project_id,omitempty"`
+	TemplateID string                 `json:"template_id,omitempty"`
+	NodeID     string                 `json:"node_id,omitempty"`
+	Labels     map[string]string      `json:"labels,omitempty"`
+	FromStatus RunStatus              `json:"from_status,omitempty"`
+	ToStatus   RunStatus              `json:"to_status,omitempty"`
+	Data       map[string]interface{} `json:"data,omitempty"`
+}
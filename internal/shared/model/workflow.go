@@ -0,0 +1,225 @@
+// Package model 定义核心数据模型
+//
+// workflow.go 包含 DAG 工作流相关的数据模型定义：
+//   - Workflow：工作流（以父任务为根，子任务为节点）
+//   - WorkflowNode：工作流节点（引用一个子任务，声明 depends_on 依赖边）
+//   - WorkflowMode：失败处理模式（fail_fast / continue_on_error）
+//   - WorkflowStatus / WorkflowNodeStatus：工作流与节点状态
+//
+// 编排器只在节点的全部依赖成功完成后才为其子任务创建 Run；
+// 依赖未成功的节点被跳过，fail_fast 模式下任一节点失败即终止整个工作流。
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+// ============================================================================
+// WorkflowMode - 失败处理模式
+// ============================================================================
+
+// WorkflowMode 工作流节点失败时的处理模式
+type WorkflowMode string
+
+const (
+	// WorkflowModeFailFast 快速失败（默认）：任一节点失败后不再启动新节点，并取消执行中的节点
+	WorkflowModeFailFast WorkflowMode = "fail_fast"
+
+	// WorkflowModeContinueOnError 出错继续：仅跳过失败节点的下游，互不依赖的分支继续执行
+	WorkflowModeContinueOnError WorkflowMode = "continue_on_error"
+)
+
+// IsValid 检查模式是否合法（空值视为合法，表示默认模式）
+func (m WorkflowMode) IsValid() bool {
+	return m == "" || m == WorkflowModeFailFast || m == WorkflowModeContinueOnError
+}
+
+// OrDefault 返回模式，空值时返回 WorkflowModeFailFast
+func (m WorkflowMode) OrDefault() WorkflowMode {
+	if m == "" {
+		return WorkflowModeFailFast
+	}
+	return m
+}
+
+// ============================================================================
+// WorkflowStatus - 工作流状态
+// ============================================================================
+
+// WorkflowStatus 工作流整体状态
+type WorkflowStatus string
+
+const (
+	// WorkflowStatusRunning 执行中：仍有待启动或执行中的节点
+	WorkflowStatusRunning WorkflowStatus = "running"
+
+	// WorkflowStatusSucceeded 已成功：全部节点成功完成
+	WorkflowStatusSucceeded WorkflowStatus = "succeeded"
+
+	// WorkflowStatusFailed 已失败：至少一个节点失败或被跳过
+	WorkflowStatusFailed WorkflowStatus = "failed"
+
+	// WorkflowStatusCancelled 已取消：用户主动取消
+	WorkflowStatusCancelled WorkflowStatus = "cancelled"
+)
+
+// IsTerminal 是否为终态
+func (s WorkflowStatus) IsTerminal() bool {
+	return s == WorkflowStatusSucceeded || s == WorkflowStatusFailed || s == WorkflowStatusCancelled
+}
+
+// WorkflowNodeStatus 工作流节点状态
+type WorkflowNodeStatus string
+
+const (
+	// WorkflowNodePending 等待依赖完成
+	WorkflowNodePending WorkflowNodeStatus = "pending"
+
+	// WorkflowNodeRunning 已创建 Run，等待其结束
+	WorkflowNodeRunning WorkflowNodeStatus = "running"
+
+	// WorkflowNodeSucceeded Run 成功完成
+	WorkflowNodeSucceeded WorkflowNodeStatus = "succeeded"
+
+	// WorkflowNodeFailed Run 失败、超时或被取消，或 Run 创建失败
+	WorkflowNodeFailed WorkflowNodeStatus = "failed"
+
+	// WorkflowNodeSkipped 依赖未成功，或 fail_fast 模式下工作流已失败，节点未执行
+	WorkflowNodeSkipped WorkflowNodeStatus = "skipped"
+
+	// WorkflowNodeCancelled 执行中被取消（工作流取消或 fail_fast 终止）
+	WorkflowNodeCancelled WorkflowNodeStatus = "cancelled"
+)
+
+// IsTerminal 是否为终态
+func (s WorkflowNodeStatus) IsTerminal() bool {
+	return s != WorkflowNodePending && s != WorkflowNodeRunning
+}
+
+// ============================================================================
+// Workflow - 工作流
+// ============================================================================
+
+// WorkflowNode 工作流节点
+//
+// 每个节点引用根任务下的一个子任务，DependsOn 为依赖的其他节点的 TaskID。
+type WorkflowNode struct {
+	// TaskID 子任务 ID（工作流内唯一）
+	TaskID string `json:"task_id" bson:"task_id"`
+
+	// DependsOn 依赖的子任务 ID 列表，全部成功后本节点才会启动
+	DependsOn []string `json:"depends_on,omitempty" bson:"depends_on,omitempty"`
+
+	// Status 节点状态
+	Status WorkflowNodeStatus `json:"status" bson:"status"`
+
+	// RunID 为本节点创建的 Run ID（未启动时为空）
+	RunID string `json:"run_id,omitempty" bson:"run_id,omitempty"`
+
+	// Error 节点失败或跳过的原因
+	Error string `json:"error,omitempty" bson:"error,omitempty"`
+}
+
+// Workflow DAG 工作流
+//
+// 以父任务（RootTaskID）的子任务为节点，节点间通过 depends_on 声明依赖边。
+// 节点列表整体存储，状态由编排器推进。
+type Workflow struct {
+	// ID 工作流唯一标识
+	ID string `json:"id" bson:"_id" db:"id"`
+
+	// Name 工作流名称（默认取根任务名称）
+	Name string `json:"name" bson:"name" db:"name"`
+
+	// RootTaskID 根任务 ID，节点必须是其直接子任务
+	RootTaskID string `json:"root_task_id" bson:"root_task_id" db:"root_task_id"`
+
+	// Mode 失败处理模式
+	Mode WorkflowMode `json:"mode" bson:"mode" db:"mode"`
+
+	// Status 工作流状态
+	Status WorkflowStatus `json:"status" bson:"status" db:"status"`
+
+	// Nodes 节点列表
+	Nodes []WorkflowNode `json:"nodes" bson:"nodes" db:"nodes"`
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time `json:"created_at" bson:"created_at" db:"created_at"`
+
+	// UpdatedAt 更新时间
+	UpdatedAt time.Time `json:"updated_at" bson:"updated_at" db:"updated_at"`
+
+	// FinishedAt 进入终态的时间
+	FinishedAt *time.Time `json:"finished_at,omitempty" bson:"finished_at,omitempty" db:"finished_at"`
+}
+
+// Node 按子任务 ID 查找节点，不存在时返回 nil
+func (w *Workflow) Node(taskID string) *WorkflowNode {
+	for i := range w.Nodes {
+		if w.Nodes[i].TaskID == taskID {
+			return &w.Nodes[i]
+		}
+	}
+	return nil
+}
+
+// Validate 校验节点定义：至少一个节点，TaskID 不重复，依赖均存在且不构成环
+func (w *Workflow) Validate() error {
+	if len(w.Nodes) == 0 {
+		return fmt.Errorf("workflow must have at least one node")
+	}
+	index := make(map[string]int, len(w.Nodes))
+	for i, n := range w.Nodes {
+		if n.TaskID == "" {
+			return fmt.Errorf("node %d: task_id is required", i)
+		}
+		if _, dup := index[n.TaskID]; dup {
+			return fmt.Errorf("duplicate node: %s", n.TaskID)
+		}
+		index[n.TaskID] = i
+	}
+	for _, n := range w.Nodes {
+		for _, dep := range n.DependsOn {
+			if dep == n.TaskID {
+				return fmt.Errorf("node %s depends on itself", n.TaskID)
+			}
+			if _, ok := index[dep]; !ok {
+				return fmt.Errorf("node %s depends on unknown node %s", n.TaskID, dep)
+			}
+		}
+	}
+
+	// 三色 DFS 检测环
+	const (
+		white = iota
+		grey
+		black
+	)
+	color := make([]int, len(w.Nodes))
+	var visit func(i int) error
+	visit = func(i int) error {
+		color[i] = grey
+		for _, dep := range w.Nodes[i].DependsOn {
+			j := index[dep]
+			switch color[j] {
+			case grey:
+				return fmt.Errorf("dependency cycle detected at node %s", dep)
+			case white:
+				if err := visit(j); err != nil {
+					return err
+				}
+			}
+		}
+		color[i] = black
+		return nil
+	}
+	for i := range w.Nodes {
+		if color[i] == white {
+			if err := visit(i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
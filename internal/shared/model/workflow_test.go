@@ -0,0 +1,47 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestWorkflow_Validate 校验节点唯一性、未知依赖、自依赖与环
+func TestWorkflow_Validate(t *testing.T) {
+	node := func(id string, deps ...string) WorkflowNode {
+		return WorkflowNode{TaskID: id, DependsOn: deps}
+	}
+	tests := []struct {
+		name    string
+		nodes   []WorkflowNode
+		wantErr string
+	}{
+		{"diamond", []WorkflowNode{node("a"), node("b", "a"), node("c", "a"), node("d", "b", "c")}, ""},
+		{"empty", nil, "at least one node"},
+		{"missing task_id", []WorkflowNode{node("")}, "task_id is required"},
+		{"duplicate", []WorkflowNode{node("a"), node("a")}, "duplicate node"},
+		{"unknown dep", []WorkflowNode{node("a", "x")}, "unknown node x"},
+		{"self dep", []WorkflowNode{node("a", "a")}, "depends on itself"},
+		{"cycle", []WorkflowNode{node("a", "c"), node("b", "a"), node("c", "b")}, "cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := (&Workflow{Nodes: tt.nodes}).Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestWorkflowMode 模式校验与默认值
+func TestWorkflowMode(t *testing.T) {
+	assert.True(t, WorkflowMode("").IsValid())
+	assert.True(t, WorkflowModeContinueOnError.IsValid())
+	assert.False(t, WorkflowMode("retry").IsValid())
+	assert.Equal(t, WorkflowModeFailFast, WorkflowMode("").OrDefault())
+	assert.True(t, WorkflowNodeSkipped.IsTerminal())
+	assert.False(t, WorkflowNodeRunning.IsTerminal())
+}
@@ -23,6 +23,15 @@ type SchedulerQueue interface {
 	GetSchedulerPendingCount(ctx context.Context) (int64, error)
 }
 
+// PrioritySchedulerQueue 支持优先级的调度队列（可选能力，通过类型断言使用）
+//
+// 实现方需保证 ConsumeSchedulerRuns 按 high → normal → low 的顺序返回消息，
+// AckSchedulerRun 能确认任意优先级队列中的消息。
+type PrioritySchedulerQueue interface {
+	// ScheduleRunWithPriority 将 Run 加入指定优先级的调度队列
+	ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error)
+}
+
 // NodeRunQueue 节点 Run 队列接口
 type NodeRunQueue interface {
 	// PublishRunToNode 将 Run 分配给指定节点
@@ -34,6 +43,21 @@ type NodeRunQueue interface {
 	GetNodeRunsPendingCount(ctx context.Context, nodeID string) (int64, error)
 }
 
+// NodeRunAssignment 一次 Run 到节点的分派
+type NodeRunAssignment struct {
+	NodeID string
+	RunID  string
+	TaskID string
+}
+
+// BatchNodeRunQueue 支持批量分派的节点队列（可选能力，通过类型断言使用）
+//
+// 实现方应在一次往返中写入所有分派（如 Redis pipeline），返回的消息 ID 与入参一一对应，
+// 写入失败的条目消息 ID 为空，error 为第一个失败原因。
+type BatchNodeRunQueue interface {
+	PublishRunsToNodes(ctx context.Context, runs []NodeRunAssignment) ([]string, error)
+}
+
 // NodeTaskQueue 别名，向后兼容
 // Deprecated: 使用 NodeRunQueue
 type NodeTaskQueue = NodeRunQueue
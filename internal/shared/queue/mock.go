@@ -28,6 +28,9 @@ func (q *NoOpQueue) Close() error {
 func (q *NoOpQueue) ScheduleRun(ctx context.Context, runID, taskID string) (string, error) {
 	return "", nil
 }
+func (q *NoOpQueue) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
+	return "", nil
+}
 func (q *NoOpQueue) CreateSchedulerConsumerGroup(ctx context.Context) error {
 	return nil
 }
@@ -49,6 +52,9 @@ func (q *NoOpQueue) GetSchedulerPendingCount(ctx context.Context) (int64, error)
 func (q *NoOpQueue) PublishRunToNode(ctx context.Context, nodeID, runID, taskID string) (string, error) {
 	return "", nil
 }
+func (q *NoOpQueue) PublishRunsToNodes(ctx context.Context, runs []NodeRunAssignment) ([]string, error) {
+	return make([]string, len(runs)), nil
+}
 func (q *NoOpQueue) CreateNodeConsumerGroup(ctx context.Context, nodeID string) error {
 	return nil
 }
@@ -67,3 +73,4 @@ func (q *NoOpQueue) GetNodeRunsPendingCount(ctx context.Context, nodeID string)
 
 // 确保 NoOpQueue 实现了 Queue 接口
 var _ Queue = (*NoOpQueue)(nil)
+var _ PrioritySchedulerQueue = (*NoOpQueue)(nil)
@@ -0,0 +1,146 @@
+// Package nats NodeRunQueue 操作
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"agents-admin/internal/shared/queue"
+)
+
+// nodePayload 节点分派消息内容
+type nodePayload struct {
+	RunID      string    `json:"run_id"`
+	TaskID     string    `json:"task_id"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+func nodeSubject(nodeID string) string {
+	return nodeSubjectPrefix + subjectToken(nodeID) + nodeSubjectSuffix
+}
+
+func nodeConsumer(nodeID string) string {
+	return queue.NodeManagerConsumerGroup + "-" + subjectToken(nodeID)
+}
+
+// PublishRunToNode 将 Run 分配给指定节点
+func (s *Store) PublishRunToNode(ctx context.Context, nodeID, runID, taskID string) (string, error) {
+	data, err := json.Marshal(nodePayload{RunID: runID, TaskID: taskID, AssignedAt: time.Now()})
+	if err != nil {
+		return "", err
+	}
+
+	ack, err := s.js.Publish(ctx, nodeSubject(nodeID), data)
+	if err != nil {
+		return "", fmt.Errorf("failed to publish run to node %s: %w", nodeID, err)
+	}
+
+	msgID := strconv.FormatUint(ack.Sequence, 10)
+	log.Printf("[NATS/Queue] Published run to node: node=%s run=%s task=%s seq=%s", nodeID, runID, taskID, msgID)
+	return msgID, nil
+}
+
+// PublishRunsToNodes 异步批量发布分派，等待全部 PubAck 后返回（一次往返的流水线写入）
+func (s *Store) PublishRunsToNodes(ctx context.Context, runs []queue.NodeRunAssignment) ([]string, error) {
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	assignedAt := time.Now()
+	ids := make([]string, len(runs))
+	futures := make([]jetstream.PubAckFuture, len(runs))
+	var firstErr error
+	for i, r := range runs {
+		data, err := json.Marshal(nodePayload{RunID: r.RunID, TaskID: r.TaskID, AssignedAt: assignedAt})
+		if err == nil {
+			futures[i], err = s.js.PublishAsync(nodeSubject(r.NodeID), data)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to publish run %s to node %s: %w", r.RunID, r.NodeID, err)
+		}
+	}
+
+	for i, f := range futures {
+		if f == nil {
+			continue
+		}
+		select {
+		case ack := <-f.Ok():
+			ids[i] = strconv.FormatUint(ack.Sequence, 10)
+		case err := <-f.Err():
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to publish run %s to node %s: %w", runs[i].RunID, runs[i].NodeID, err)
+			}
+		case <-ctx.Done():
+			return ids, ctx.Err()
+		}
+	}
+
+	log.Printf("[NATS/Queue] Published runs to nodes: count=%d", len(runs))
+	return ids, firstErr
+}
+
+// CreateNodeConsumerGroup 创建节点持久化消费者
+func (s *Store) CreateNodeConsumerGroup(ctx context.Context, nodeID string) error {
+	if err := s.createConsumer(ctx, nodeStream, nodeConsumer(nodeID), nodeSubject(nodeID)); err != nil {
+		return fmt.Errorf("failed to create consumer group for node %s: %w", nodeID, err)
+	}
+
+	log.Printf("[NATS/Queue] Created consumer for node: %s", nodeID)
+	return nil
+}
+
+// ConsumeNodeRuns 消费节点分配的 Run
+func (s *Store) ConsumeNodeRuns(ctx context.Context, nodeID, consumerID string, count int64, blockTimeout time.Duration) ([]*queue.NodeRunMessage, error) {
+	msgs, err := s.fetch(ctx, nodeStream, nodeConsumer(nodeID), count, blockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume node runs: %w", err)
+	}
+
+	messages := make([]*queue.NodeRunMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		var payload nodePayload
+		if err := json.Unmarshal(msg.Data(), &payload); err != nil {
+			log.Printf("[NATS/Queue] Drop malformed node message: subject=%s error=%v", msg.Subject(), err)
+			_ = msg.Ack()
+			continue
+		}
+		messages = append(messages, &queue.NodeRunMessage{
+			ID:         msg.Reply(),
+			RunID:      payload.RunID,
+			TaskID:     payload.TaskID,
+			AssignedAt: payload.AssignedAt,
+		})
+	}
+	return messages, nil
+}
+
+// AckNodeRun 确认节点 Run 消息已处理
+func (s *Store) AckNodeRun(ctx context.Context, nodeID, messageID string) error {
+	return s.ack(messageID)
+}
+
+// GetNodeRunsQueueLength 获取节点队列长度（含已投递未确认的消息）
+func (s *Store) GetNodeRunsQueueLength(ctx context.Context, nodeID string) (int64, error) {
+	stream, err := s.js.Stream(ctx, nodeStream)
+	if err != nil {
+		return 0, err
+	}
+	subject := nodeSubject(nodeID)
+	info, err := stream.Info(ctx, jetstream.WithSubjectFilter(subject))
+	if err != nil {
+		return 0, err
+	}
+	return int64(info.State.Subjects[subject]), nil
+}
+
+// GetNodeRunsPendingCount 获取节点未确认消息数量
+func (s *Store) GetNodeRunsPendingCount(ctx context.Context, nodeID string) (int64, error) {
+	return s.pendingCount(ctx, nodeStream, nodeConsumer(nodeID))
+}
@@ -0,0 +1,151 @@
+// Package nats SchedulerQueue 操作
+//
+// 调度队列按优先级拆分为三个 Subject，每个 Subject 一个持久化消费者，
+// 消费时依次读取 high → normal → low，保证高优先级 Run 先被调度。
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"strconv"
+	"time"
+
+	"agents-admin/internal/shared/queue"
+)
+
+// schedulerPayload 调度消息内容
+type schedulerPayload struct {
+	RunID     string    `json:"run_id"`
+	TaskID    string    `json:"task_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// schedulerSubject 返回优先级对应的 Subject（未知优先级归入 normal）
+func schedulerSubject(priority string) string {
+	switch priority {
+	case queue.PriorityHigh, queue.PriorityLow:
+		return schedulerSubjectPrefix + priority
+	}
+	return schedulerSubjectPrefix + queue.PriorityNormal
+}
+
+// schedulerConsumer 返回优先级对应的持久化消费者名称
+func schedulerConsumer(priority string) string {
+	return queue.SchedulerConsumerGroup + "-" + priority
+}
+
+// ScheduleRun 将 Run 加入调度队列（等待分配节点，normal 优先级）
+func (s *Store) ScheduleRun(ctx context.Context, runID, taskID string) (string, error) {
+	return s.ScheduleRunWithPriority(ctx, runID, taskID, queue.PriorityNormal)
+}
+
+// ScheduleRunWithPriority 将 Run 加入指定优先级的调度队列
+func (s *Store) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
+	data, err := json.Marshal(schedulerPayload{RunID: runID, TaskID: taskID, CreatedAt: time.Now()})
+	if err != nil {
+		return "", err
+	}
+
+	ack, err := s.js.Publish(ctx, schedulerSubject(priority), data)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatUint(ack.Sequence, 10), nil
+}
+
+// CreateSchedulerConsumerGroup 创建调度器持久化消费者（每个优先级各一个）
+func (s *Store) CreateSchedulerConsumerGroup(ctx context.Context) error {
+	for _, p := range queue.SchedulerPriorities {
+		if err := s.createConsumer(ctx, schedulerStream, schedulerConsumer(p), schedulerSubject(p)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConsumeSchedulerRuns 消费调度队列中的 Run
+//
+// 先依次非阻塞读取 high → normal → low 队列，任一队列有消息即返回；
+// 全部为空时以 pollInterval 为单位等待 normal 队列，直到 blockTimeout。
+func (s *Store) ConsumeSchedulerRuns(ctx context.Context, consumerID string, count int64, blockTimeout time.Duration) ([]*queue.SchedulerMessage, error) {
+	deadline := time.Now().Add(blockTimeout)
+	for {
+		for _, p := range queue.SchedulerPriorities {
+			messages, err := s.fetchScheduler(ctx, p, count, 0)
+			if err != nil || len(messages) > 0 {
+				return messages, err
+			}
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, nil
+		}
+		messages, err := s.fetchScheduler(ctx, queue.PriorityNormal, count, min(remaining, pollInterval))
+		if err != nil || len(messages) > 0 {
+			return messages, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// fetchScheduler 从指定优先级的消费者拉取消息
+func (s *Store) fetchScheduler(ctx context.Context, priority string, count int64, wait time.Duration) ([]*queue.SchedulerMessage, error) {
+	msgs, err := s.fetch(ctx, schedulerStream, schedulerConsumer(priority), count, wait)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*queue.SchedulerMessage, 0, len(msgs))
+	for _, msg := range msgs {
+		var payload schedulerPayload
+		if err := json.Unmarshal(msg.Data(), &payload); err != nil {
+			// 无法解析的消息直接确认丢弃，避免反复投递
+			log.Printf("[NATS/Queue] Drop malformed scheduler message: subject=%s error=%v", msg.Subject(), err)
+			_ = msg.Ack()
+			continue
+		}
+		messages = append(messages, &queue.SchedulerMessage{
+			ID:        msg.Reply(),
+			RunID:     payload.RunID,
+			TaskID:    payload.TaskID,
+			Priority:  priority,
+			CreatedAt: payload.CreatedAt,
+		})
+	}
+	return messages, nil
+}
+
+// AckSchedulerRun 确认 Run 调度消息已处理
+func (s *Store) AckSchedulerRun(ctx context.Context, messageID string) error {
+	return s.ack(messageID)
+}
+
+// GetSchedulerQueueLength 获取调度队列长度（所有优先级之和，含已投递未确认的消息）
+func (s *Store) GetSchedulerQueueLength(ctx context.Context) (int64, error) {
+	stream, err := s.js.Stream(ctx, schedulerStream)
+	if err != nil {
+		return 0, err
+	}
+	info, err := stream.Info(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int64(info.State.Msgs), nil
+}
+
+// GetSchedulerPendingCount 获取未确认消息数量（所有优先级之和）
+func (s *Store) GetSchedulerPendingCount(ctx context.Context) (int64, error) {
+	var total int64
+	for _, p := range queue.SchedulerPriorities {
+		n, err := s.pendingCount(ctx, schedulerStream, schedulerConsumer(p))
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
+}
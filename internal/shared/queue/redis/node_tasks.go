@@ -40,6 +40,47 @@ func (s *Store) PublishRunToNode(ctx context.Context, nodeID, runID, taskID stri
 	return msgID, nil
 }
 
+// PublishRunsToNodes 在一个 pipeline 中批量将 Run 分配给节点（一次往返写入所有 XADD）
+func (s *Store) PublishRunsToNodes(ctx context.Context, runs []queue.NodeRunAssignment) ([]string, error) {
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	assignedAt := time.Now().Format(time.RFC3339Nano)
+	pipe := s.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(runs))
+	for i, r := range runs {
+		cmds[i] = pipe.XAdd(ctx, &redis.XAddArgs{
+			Stream: nodeRunsKey(r.NodeID),
+			MaxLen: 1000,
+			Approx: true,
+			Values: map[string]interface{}{
+				"run_id":      r.RunID,
+				"task_id":     r.TaskID,
+				"assigned_at": assignedAt,
+			},
+		})
+	}
+	// 单条失败不影响其余条目，逐条读取结果
+	_, _ = pipe.Exec(ctx)
+
+	ids := make([]string, len(runs))
+	var firstErr error
+	for i, cmd := range cmds {
+		msgID, err := cmd.Result()
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to publish run %s to node %s: %w", runs[i].RunID, runs[i].NodeID, err)
+			}
+			continue
+		}
+		ids[i] = msgID
+	}
+
+	log.Printf("[Redis/Queue] Published runs to nodes: count=%d", len(runs))
+	return ids, firstErr
+}
+
 // CreateNodeConsumerGroup 创建节点消费者组
 func (s *Store) CreateNodeConsumerGroup(ctx context.Context, nodeID string) error {
 	key := nodeRunsKey(nodeID)
@@ -1,8 +1,13 @@
 // Package redis SchedulerQueue 操作
+//
+// 调度队列按优先级拆分为三个 Stream（scheduler:runs:high / scheduler:runs / scheduler:runs:low），
+// 消费时依次读取 high → normal → low，保证高优先级 Run 先被调度。
+// 非 normal 队列的消息 ID 带有 "{priority}:" 前缀，AckSchedulerRun 据此定位所属 Stream。
 package redis
 
 import (
 	"context"
+	"strings"
 	"time"
 
 	"github.com/redis/go-redis/v9"
@@ -10,10 +15,15 @@ import (
 	"agents-admin/internal/shared/queue"
 )
 
-// ScheduleRun 将 Run 加入调度队列（等待分配节点）
+// ScheduleRun 将 Run 加入调度队列（等待分配节点，normal 优先级）
 func (s *Store) ScheduleRun(ctx context.Context, runID, taskID string) (string, error) {
+	return s.ScheduleRunWithPriority(ctx, runID, taskID, queue.PriorityNormal)
+}
+
+// ScheduleRunWithPriority 将 Run 加入指定优先级的调度队列
+func (s *Store) ScheduleRunWithPriority(ctx context.Context, runID, taskID, priority string) (string, error) {
 	args := &redis.XAddArgs{
-		Stream: queue.KeySchedulerRuns,
+		Stream: queue.SchedulerStreamKey(priority),
 		MaxLen: 10000,
 		Approx: true,
 		Values: map[string]interface{}{
@@ -23,26 +33,57 @@ func (s *Store) ScheduleRun(ctx context.Context, runID, taskID string) (string,
 		},
 	}
 
-	return s.client.XAdd(ctx, args).Result()
+	id, err := s.client.XAdd(ctx, args).Result()
+	if err != nil {
+		return "", err
+	}
+	return schedulerMessageID(priority, id), nil
 }
 
-// CreateSchedulerConsumerGroup 创建调度器消费者组
+// CreateSchedulerConsumerGroup 创建调度器消费者组（每个优先级队列各一个）
 func (s *Store) CreateSchedulerConsumerGroup(ctx context.Context) error {
-	err := s.client.XGroupCreateMkStream(ctx, queue.KeySchedulerRuns, queue.SchedulerConsumerGroup, "0").Err()
-	if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
-		return err
+	for _, p := range queue.SchedulerPriorities {
+		err := s.client.XGroupCreateMkStream(ctx, queue.SchedulerStreamKey(p), queue.SchedulerConsumerGroup, "0").Err()
+		if err != nil && err.Error() != "BUSYGROUP Consumer Group name already exists" {
+			return err
+		}
 	}
 	return nil
 }
 
 // ConsumeSchedulerRuns 消费调度队列中的 Run
+//
+// 先依次非阻塞读取 high → normal → low 队列，任一队列有消息即返回；
+// 全部为空时阻塞等待任一队列的新消息。
 func (s *Store) ConsumeSchedulerRuns(ctx context.Context, consumerID string, count int64, blockTimeout time.Duration) ([]*queue.SchedulerMessage, error) {
+	for _, p := range queue.SchedulerPriorities {
+		messages, err := s.readSchedulerStreams(ctx, consumerID, count, -1, p)
+		if err != nil || len(messages) > 0 {
+			return messages, err
+		}
+	}
+	return s.readSchedulerStreams(ctx, consumerID, count, blockTimeout, queue.SchedulerPriorities...)
+}
+
+// readSchedulerStreams 从指定优先级队列读取新消息（block < 0 时不阻塞）
+func (s *Store) readSchedulerStreams(ctx context.Context, consumerID string, count int64, block time.Duration, priorities ...string) ([]*queue.SchedulerMessage, error) {
+	streamKeys := make([]string, 0, len(priorities)*2)
+	keyPriority := make(map[string]string, len(priorities))
+	for _, p := range priorities {
+		key := queue.SchedulerStreamKey(p)
+		streamKeys = append(streamKeys, key)
+		keyPriority[key] = p
+	}
+	for range priorities {
+		streamKeys = append(streamKeys, ">")
+	}
+
 	streams, err := s.client.XReadGroup(ctx, &redis.XReadGroupArgs{
 		Group:    queue.SchedulerConsumerGroup,
 		Consumer: consumerID,
-		Streams:  []string{queue.KeySchedulerRuns, ">"},
+		Streams:  streamKeys,
 		Count:    count,
-		Block:    blockTimeout,
+		Block:    block,
 	}).Result()
 
 	if err != nil {
@@ -54,9 +95,11 @@ func (s *Store) ConsumeSchedulerRuns(ctx context.Context, consumerID string, cou
 
 	var messages []*queue.SchedulerMessage
 	for _, stream := range streams {
+		priority := keyPriority[stream.Stream]
 		for _, msg := range stream.Messages {
 			m := &queue.SchedulerMessage{
-				ID: msg.ID,
+				ID:       schedulerMessageID(priority, msg.ID),
+				Priority: priority,
 			}
 			if runID, ok := msg.Values["run_id"].(string); ok {
 				m.RunID = runID
@@ -78,19 +121,56 @@ func (s *Store) ConsumeSchedulerRuns(ctx context.Context, consumerID string, cou
 
 // AckSchedulerRun 确认 Run 调度消息已处理
 func (s *Store) AckSchedulerRun(ctx context.Context, messageID string) error {
-	return s.client.XAck(ctx, queue.KeySchedulerRuns, queue.SchedulerConsumerGroup, messageID).Err()
+	key, id := parseSchedulerMessageID(messageID)
+	return s.client.XAck(ctx, key, queue.SchedulerConsumerGroup, id).Err()
 }
 
-// GetSchedulerQueueLength 获取调度队列长度
+// GetSchedulerQueueLength 获取调度队列长度（所有优先级之和）
 func (s *Store) GetSchedulerQueueLength(ctx context.Context) (int64, error) {
-	return s.client.XLen(ctx, queue.KeySchedulerRuns).Result()
+	var total int64
+	for _, p := range queue.SchedulerPriorities {
+		n, err := s.client.XLen(ctx, queue.SchedulerStreamKey(p)).Result()
+		if err != nil && err != redis.Nil {
+			return 0, err
+		}
+		total += n
+	}
+	return total, nil
 }
 
-// GetSchedulerPendingCount 获取未确认消息数量
+// GetSchedulerPendingCount 获取未确认消息数量（所有优先级之和）
 func (s *Store) GetSchedulerPendingCount(ctx context.Context) (int64, error) {
-	pending, err := s.client.XPending(ctx, queue.KeySchedulerRuns, queue.SchedulerConsumerGroup).Result()
-	if err != nil {
-		return 0, err
+	var total int64
+	for _, p := range queue.SchedulerPriorities {
+		pending, err := s.client.XPending(ctx, queue.SchedulerStreamKey(p), queue.SchedulerConsumerGroup).Result()
+		if err != nil {
+			if p != queue.PriorityNormal && isNoGroupError(err) {
+				continue // 旧部署尚未创建优先级队列
+			}
+			return 0, err
+		}
+		total += pending.Count
+	}
+	return total, nil
+}
+
+// schedulerMessageID 为非 normal 优先级的消息 ID 加上优先级前缀
+func schedulerMessageID(priority, id string) string {
+	if key := queue.SchedulerStreamKey(priority); key != queue.KeySchedulerRuns {
+		return priority + ":" + id
+	}
+	return id
+}
+
+// parseSchedulerMessageID 解析消息 ID，返回所属 Stream Key 与原始 ID
+func parseSchedulerMessageID(messageID string) (string, string) {
+	if priority, id, ok := strings.Cut(messageID, ":"); ok {
+		return queue.SchedulerStreamKey(priority), id
 	}
-	return pending.Count, nil
+	return queue.KeySchedulerRuns, messageID
+}
+
+// isNoGroupError 判断是否为 Stream 或消费者组不存在的错误
+func isNoGroupError(err error) bool {
+	return strings.HasPrefix(err.Error(), "NOGROUP")
 }
@@ -8,6 +8,8 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+
+	"agents-admin/internal/shared/queue"
 )
 
 // Store Redis 消息队列存储
@@ -68,3 +70,9 @@ func (s *Store) Close() error {
 func (s *Store) Client() *redis.Client {
 	return s.client
 }
+
+func init() {
+	queue.Register(queue.DriverRedis, func(url string) (queue.Queue, error) {
+		return NewStoreFromURL(url)
+	})
+}
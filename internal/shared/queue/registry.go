@@ -0,0 +1,61 @@
+// Package queue 消息队列后端注册
+//
+// 各后端在 init 中调用 Register 注册，使用方按 config.QueueDriver 调用 Open 创建队列，
+// 需要以空白导入引入后端包（如 _ "agents-admin/internal/shared/queue/nats"）。
+package queue
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// 队列后端名称
+const (
+	DriverRedis = "redis" // Redis Streams（默认）
+	DriverNATS  = "nats"  // NATS JetStream
+)
+
+// Factory 根据连接串创建队列后端
+type Factory func(url string) (Queue, error)
+
+var (
+	driversMu sync.RWMutex
+	drivers   = map[string]Factory{}
+)
+
+// Register 注册队列后端（重复注册同名后端会 panic）
+func Register(name string, factory Factory) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+	if factory == nil {
+		panic("queue: Register factory is nil")
+	}
+	if _, dup := drivers[name]; dup {
+		panic("queue: Register called twice for driver " + name)
+	}
+	drivers[name] = factory
+}
+
+// Open 使用已注册的后端创建队列
+func Open(name, url string) (Queue, error) {
+	driversMu.RLock()
+	factory, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("queue: unknown driver %q (registered: %v)", name, Drivers())
+	}
+	return factory(url)
+}
+
+// Drivers 返回已注册的后端名称（按字母排序）
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
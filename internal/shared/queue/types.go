@@ -14,6 +14,7 @@ type SchedulerMessage struct {
 	ID        string
 	RunID     string
 	TaskID    string
+	Priority  string // 调度优先级（high/normal/low），来自所在的优先级队列
 	CreatedAt time.Time
 }
 
@@ -34,9 +35,13 @@ type NodeTaskMessage = NodeRunMessage
 // ============================================================================
 
 const (
-	// 调度器队列 - 存放待调度的 Run
+	// 调度器队列 - 存放待调度的 Run（normal 优先级，兼容未区分优先级的旧消息）
 	KeySchedulerRuns = "scheduler:runs"
 
+	// 调度器优先级队列 - 分别存放 high / low 优先级的待调度 Run
+	KeySchedulerRunsHigh = "scheduler:runs:high"
+	KeySchedulerRunsLow  = "scheduler:runs:low"
+
 	// 节点队列 - 存放分配给节点的 Run
 	KeyNodeRuns       = "nodes:"
 	KeyNodeRunsSuffix = ":runs"
@@ -53,3 +58,24 @@ const (
 	SchedulerConsumerGroup   = "schedulers"
 	NodeManagerConsumerGroup = "node_managers"
 )
+
+// 调度优先级（与 model.Priority 取值一致）
+const (
+	PriorityHigh   = "high"
+	PriorityNormal = "normal"
+	PriorityLow    = "low"
+)
+
+// SchedulerPriorities 调度器消费优先级队列的顺序
+var SchedulerPriorities = []string{PriorityHigh, PriorityNormal, PriorityLow}
+
+// SchedulerStreamKey 返回优先级对应的调度队列 Key（未知优先级归入 normal）
+func SchedulerStreamKey(priority string) string {
+	switch priority {
+	case PriorityHigh:
+		return KeySchedulerRunsHigh
+	case PriorityLow:
+		return KeySchedulerRunsLow
+	}
+	return KeySchedulerRuns
+}
@@ -0,0 +1,249 @@
+// Package secpolicy 安全策略引擎
+//
+// 将安全策略（SecurityPolicyEntity 的工具权限）与任务的 SecurityConfig 合并为每项平台权限的生效级别：
+//   - 基线：任务绑定实例的 Agent 模板的默认安全策略（default_security_policy_id）与任务
+//     security.policy 等级对应的内置策略；同时存在时每项权限取更严格的级别
+//   - security.permissions：任务需要的权限，被基线禁止时拒绝创建任务
+//   - security.denied_permissions：禁止；security.require_approval 中的平台权限：需人工审批
+//
+// 生效策略写入执行快照 security_policy，由 NodeManager 通过 Adapter 换算为 CLI 参数
+// （如 Claude Code 的 --allowed-tools / --disallowed-tools，Gemini CLI 的 --sandbox），
+// Agent 调用被禁止的工具时上报 policy_denied 事件。
+package secpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"agents-admin/internal/shared/model"
+)
+
+// 平台权限
+const (
+	PermissionFileRead        = "file_read"
+	PermissionFileWrite       = "file_write"
+	PermissionFileDelete      = "file_delete"
+	PermissionCommandExecute  = "command_execute"
+	PermissionNetworkOutbound = "network_outbound"
+)
+
+// Permissions 所有平台权限（策略工具权限的 tool 可用通配符匹配，如 file_*、network_*）
+var Permissions = []string{
+	PermissionFileRead,
+	PermissionFileWrite,
+	PermissionFileDelete,
+	PermissionCommandExecute,
+	PermissionNetworkOutbound,
+}
+
+// IsPermission 判断是否为平台权限
+func IsPermission(name string) bool {
+	for _, p := range Permissions {
+		if p == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrUnknownPermission 任务安全配置引用了未知的平台权限（请求错误）
+var ErrUnknownPermission = errors.New("unknown permission")
+
+// ErrPolicyNotFound Agent 引用的安全策略不存在（请求错误）
+var ErrPolicyNotFound = errors.New("security policy not found")
+
+// ViolationError 任务需要的权限被安全策略禁止
+type ViolationError struct {
+	Permissions []string
+	Sources     []string
+}
+
+func (e *ViolationError) Error() string {
+	return fmt.Sprintf("permissions denied by security policy %s: %s",
+		strings.Join(e.Sources, ", "), strings.Join(e.Permissions, ", "))
+}
+
+// Policy 生效的安全策略（执行快照 security_policy）
+type Policy struct {
+	// Sources 参与计算的安全策略 ID（如 builtin-strict）
+	Sources []string `json:"sources,omitempty"`
+
+	// Permissions 每项平台权限的生效级别
+	Permissions map[string]model.ToolPermissionLevel `json:"permissions"`
+}
+
+// Level 权限的生效级别（未知权限视为允许）
+func (p *Policy) Level(permission string) model.ToolPermissionLevel {
+	if p == nil {
+		return model.ToolPermissionAllowed
+	}
+	if level, ok := p.Permissions[permission]; ok {
+		return level
+	}
+	return model.ToolPermissionAllowed
+}
+
+// With 生效级别为 level 的权限（按 Permissions 顺序）
+func (p *Policy) With(level model.ToolPermissionLevel) []string {
+	var out []string
+	for _, perm := range Permissions {
+		if p.Level(perm) == level {
+			out = append(out, perm)
+		}
+	}
+	return out
+}
+
+// Restricted 是否有被禁止或需要审批的权限（没有时无需执行）
+func (p *Policy) Restricted() bool {
+	return p != nil && len(p.With(model.ToolPermissionAllowed)) < len(Permissions)
+}
+
+// strictness 权限级别的严格程度
+func strictness(level model.ToolPermissionLevel) int {
+	switch level {
+	case model.ToolPermissionDenied:
+		return 2
+	case model.ToolPermissionApprovalRequired:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Builtin 查找内置策略（按 ID 如 builtin-strict，或等级如 strict），不存在时返回 nil
+func Builtin(ref string) *model.SecurityPolicyEntity {
+	for i := range model.BuiltinSecurityPolicies {
+		p := &model.BuiltinSecurityPolicies[i]
+		if p.ID == ref || p.ID == "builtin-"+ref {
+			return p
+		}
+	}
+	return nil
+}
+
+// entityLevel 策略对平台权限的级别：精确匹配优先，其次取最长的通配符模式，未配置时允许
+func entityLevel(entity *model.SecurityPolicyEntity, permission string) model.ToolPermissionLevel {
+	level, best := model.ToolPermissionAllowed, -1
+	for _, tp := range entity.ToolPermissions {
+		if tp.Tool == permission {
+			return tp.Permission
+		}
+		if ok, _ := path.Match(tp.Tool, permission); ok && len(tp.Tool) > best {
+			level, best = tp.Permission, len(tp.Tool)
+		}
+	}
+	return level
+}
+
+// Evaluate 按基线策略与任务安全配置计算生效策略（bases 中的 nil 忽略，sec 可为 nil）
+//
+// 任务需要的权限被基线禁止时返回 *ViolationError；引用未知平台权限时返回的错误满足
+// errors.Is(err, ErrUnknownPermission)。security.require_approval 中的其他名称是工具名，
+// 由 NodeManager 的审批闸门直接匹配，这里忽略。
+func Evaluate(sec *model.SecurityConfig, bases ...*model.SecurityPolicyEntity) (*Policy, error) {
+	p := &Policy{Permissions: make(map[string]model.ToolPermissionLevel, len(Permissions))}
+	for _, perm := range Permissions {
+		p.Permissions[perm] = model.ToolPermissionAllowed
+	}
+	for _, base := range bases {
+		if base == nil {
+			continue
+		}
+		p.Sources = append(p.Sources, base.ID)
+		for _, perm := range Permissions {
+			if level := entityLevel(base, perm); strictness(level) > strictness(p.Permissions[perm]) {
+				p.Permissions[perm] = level
+			}
+		}
+	}
+	if sec == nil {
+		return p, nil
+	}
+
+	var denied []string
+	for _, perm := range sec.Permissions {
+		if !IsPermission(perm) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, perm)
+		}
+		if p.Permissions[perm] == model.ToolPermissionDenied {
+			denied = append(denied, perm)
+		}
+	}
+	if len(denied) > 0 {
+		return nil, &ViolationError{Permissions: denied, Sources: p.Sources}
+	}
+	for _, perm := range sec.RequireApproval {
+		if IsPermission(perm) && p.Permissions[perm] == model.ToolPermissionAllowed {
+			p.Permissions[perm] = model.ToolPermissionApprovalRequired
+		}
+	}
+	for _, perm := range sec.DeniedPermissions {
+		if !IsPermission(perm) {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownPermission, perm)
+		}
+		p.Permissions[perm] = model.ToolPermissionDenied
+	}
+	return p, nil
+}
+
+// Store 解析任务安全策略需要的存储方法
+type Store interface {
+	GetAgentInstance(ctx context.Context, id string) (*model.Instance, error)
+	GetAgentTemplate(ctx context.Context, id string) (*model.AgentTemplate, error)
+	GetSecurityPolicy(ctx context.Context, id string) (*model.SecurityPolicyEntity, error)
+}
+
+// Resolve 计算任务的生效策略（任务创建时校验与创建 Run 时写入快照共用）
+//
+// 基线为任务绑定实例的 Agent 模板的默认安全策略与任务 security.policy 等级对应的内置策略。
+// 引用的策略不存在时返回的错误满足 errors.Is(err, ErrPolicyNotFound)，其余错误同 Evaluate。
+func Resolve(ctx context.Context, store Store, task *model.Task) (*Policy, error) {
+	var bases []*model.SecurityPolicyEntity
+	id, err := agentPolicyID(ctx, store, task)
+	if err != nil {
+		return nil, err
+	}
+	if id != "" {
+		entity := Builtin(id)
+		if entity == nil {
+			if entity, err = store.GetSecurityPolicy(ctx, id); err != nil {
+				return nil, err
+			}
+		}
+		if entity == nil {
+			return nil, fmt.Errorf("%w: %s", ErrPolicyNotFound, id)
+		}
+		bases = append(bases, entity)
+	}
+	if task.Security != nil && task.Security.Policy != "" {
+		entity := Builtin(string(task.Security.Policy))
+		if entity == nil {
+			return nil, fmt.Errorf("%w: %s", ErrPolicyNotFound, task.Security.Policy)
+		}
+		bases = append(bases, entity)
+	}
+	return Evaluate(task.Security, bases...)
+}
+
+// agentPolicyID 任务绑定实例的 Agent 模板的默认安全策略 ID（未绑定实例或模板未设置时为空）
+func agentPolicyID(ctx context.Context, store Store, task *model.Task) (string, error) {
+	if task.AgentID == nil || *task.AgentID == "" {
+		return "", nil
+	}
+	inst, err := store.GetAgentInstance(ctx, *task.AgentID)
+	if err != nil || inst == nil {
+		return "", err
+	}
+	if inst.TemplateID == nil || *inst.TemplateID == "" {
+		return "", nil
+	}
+	tmpl, err := store.GetAgentTemplate(ctx, *inst.TemplateID)
+	if err != nil || tmpl == nil || tmpl.DefaultSecurityPolicyID == nil {
+		return "", err
+	}
+	return *tmpl.DefaultSecurityPolicyID, nil
+}
@@ -0,0 +1,120 @@
+package secpolicy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"agents-admin/internal/shared/model"
+)
+
+func TestEvaluate(t *testing.T) {
+	// 标准策略：file_* 允许，命令需审批；严格策略：网络、命令禁止
+	p, err := Evaluate(nil, Builtin("standard"), Builtin("builtin-strict"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]model.ToolPermissionLevel{
+		PermissionFileRead:        model.ToolPermissionAllowed,
+		PermissionFileWrite:       model.ToolPermissionApprovalRequired,
+		PermissionFileDelete:      model.ToolPermissionDenied,
+		PermissionCommandExecute:  model.ToolPermissionDenied,
+		PermissionNetworkOutbound: model.ToolPermissionDenied,
+	}
+	for perm, level := range want {
+		if got := p.Level(perm); got != level {
+			t.Errorf("%s = %s, want %s", perm, got, level)
+		}
+	}
+	if len(p.Sources) != 2 || !p.Restricted() {
+		t.Errorf("policy = %+v", p)
+	}
+
+	// 任务调整：禁止网络，文件删除需审批，run_shell_command 是工具名不影响平台权限
+	p, err = Evaluate(&model.SecurityConfig{
+		Permissions:       []string{PermissionFileRead},
+		DeniedPermissions: []string{PermissionNetworkOutbound},
+		RequireApproval:   []string{PermissionFileDelete, "run_shell_command"},
+	}, Builtin("permissive"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := p.With(model.ToolPermissionDenied); len(got) != 1 || got[0] != PermissionNetworkOutbound {
+		t.Errorf("denied = %v", got)
+	}
+	if got := p.With(model.ToolPermissionApprovalRequired); len(got) != 1 || got[0] != PermissionFileDelete {
+		t.Errorf("approval_required = %v", got)
+	}
+
+	// 需要的权限被策略禁止
+	_, err = Evaluate(&model.SecurityConfig{Permissions: []string{PermissionFileRead, PermissionCommandExecute}}, Builtin("strict"))
+	var violation *ViolationError
+	if !errors.As(err, &violation) || len(violation.Permissions) != 1 || violation.Permissions[0] != PermissionCommandExecute {
+		t.Errorf("err = %v", err)
+	}
+	if _, err := Evaluate(&model.SecurityConfig{DeniedPermissions: []string{"rm"}}); !errors.Is(err, ErrUnknownPermission) {
+		t.Errorf("unknown permission: err = %v", err)
+	}
+	if p, _ := Evaluate(nil); p.Restricted() {
+		t.Errorf("没有策略时不应受限: %+v", p)
+	}
+}
+
+type fakeStore struct {
+	instances map[string]*model.Instance
+	templates map[string]*model.AgentTemplate
+	policies  map[string]*model.SecurityPolicyEntity
+}
+
+func (s *fakeStore) GetAgentInstance(_ context.Context, id string) (*model.Instance, error) {
+	return s.instances[id], nil
+}
+
+func (s *fakeStore) GetAgentTemplate(_ context.Context, id string) (*model.AgentTemplate, error) {
+	return s.templates[id], nil
+}
+
+func (s *fakeStore) GetSecurityPolicy(_ context.Context, id string) (*model.SecurityPolicyEntity, error) {
+	return s.policies[id], nil
+}
+
+func TestResolve(t *testing.T) {
+	tmplID, brokenID, policyID, missing := "tmpl-1", "tmpl-2", "sec-ro", "sec-missing"
+	store := &fakeStore{
+		instances: map[string]*model.Instance{
+			"inst-1": {ID: "inst-1", TemplateID: &tmplID},
+			"inst-2": {ID: "inst-2", TemplateID: &brokenID},
+		},
+		templates: map[string]*model.AgentTemplate{
+			tmplID:   {ID: tmplID, DefaultSecurityPolicyID: &policyID},
+			brokenID: {ID: brokenID, DefaultSecurityPolicyID: &missing},
+		},
+		policies: map[string]*model.SecurityPolicyEntity{policyID: {ID: policyID, ToolPermissions: []model.ToolPermission{
+			{Tool: "*", Permission: model.ToolPermissionDenied},
+			{Tool: "file_read", Permission: model.ToolPermissionAllowed},
+		}}},
+	}
+	ctx := context.Background()
+
+	inst := "inst-1"
+	p, err := Resolve(ctx, store, &model.Task{AgentID: &inst})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Level(PermissionFileRead) != model.ToolPermissionAllowed || p.Level(PermissionFileWrite) != model.ToolPermissionDenied {
+		t.Errorf("模板默认策略未生效: %+v", p)
+	}
+	_, err = Resolve(ctx, store, &model.Task{AgentID: &inst, Security: &model.SecurityConfig{Permissions: []string{PermissionFileWrite}}})
+	var violation *ViolationError
+	if !errors.As(err, &violation) {
+		t.Errorf("err = %v", err)
+	}
+
+	inst = "inst-2"
+	if _, err := Resolve(ctx, store, &model.Task{AgentID: &inst}); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("missing policy: err = %v", err)
+	}
+	if _, err := Resolve(ctx, store, &model.Task{Security: &model.SecurityConfig{Policy: "lenient"}}); !errors.Is(err, ErrPolicyNotFound) {
+		t.Errorf("unknown level: err = %v", err)
+	}
+}
@@ -0,0 +1,81 @@
+// Package secretbox 敏感数据加密（AES-256-GCM）
+//
+// 用于两步验证密钥等需要落库的敏感字段的静态加密（凭据与运行时密钥已迁移到 fieldcrypt 信封加密，
+// 迁移前写入的密文由 fieldcrypt.Keyring 以同一主密钥解密）：
+//   - 主密钥来自 MASTER_KEY 环境变量（任意长度，经 SHA-256 派生为 32 字节 AES 密钥）
+//   - 密文格式：base64(nonce || ciphertext || tag)，前缀 "v1:" 标识版本，便于后续轮换算法
+package secretbox
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// versionPrefix 密文版本前缀
+const versionPrefix = "v1:"
+
+// ErrNoMasterKey 未配置主密钥
+var ErrNoMasterKey = errors.New("master key not configured")
+
+// Box AES-GCM 加解密器
+type Box struct {
+	aead cipher.AEAD
+}
+
+// New 使用主密钥创建加解密器
+func New(masterKey string) (*Box, error) {
+	if masterKey == "" {
+		return nil, ErrNoMasterKey
+	}
+	key := sha256.Sum256([]byte(masterKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &Box{aead: aead}, nil
+}
+
+// Encrypt 加密明文，返回带版本前缀的 base64 密文
+func (b *Box) Encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := b.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return versionPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// IsCiphertext 值是否为 Encrypt 生成的密文（带版本前缀）
+func IsCiphertext(value string) bool {
+	return strings.HasPrefix(value, versionPrefix)
+}
+
+// Decrypt 解密 Encrypt 生成的密文
+func (b *Box) Decrypt(ciphertext string) (string, error) {
+	if !strings.HasPrefix(ciphertext, versionPrefix) {
+		return "", fmt.Errorf("unsupported ciphertext format")
+	}
+	data, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, versionPrefix))
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext: %w", err)
+	}
+	nonceSize := b.aead.NonceSize()
+	if len(data) < nonceSize {
+		return "", fmt.Errorf("invalid ciphertext: too short")
+	}
+	plain, err := b.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return "", fmt.Errorf("decrypt failed (wrong master key?): %w", err)
+	}
+	return string(plain), nil
+}
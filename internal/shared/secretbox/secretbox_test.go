@@ -0,0 +1,50 @@
+package secretbox
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	box, err := New("test-master-key")
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	ct, err := box.Encrypt("ghp_secret_token")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !strings.HasPrefix(ct, versionPrefix) || strings.Contains(ct, "ghp_secret_token") {
+		t.Errorf("密文格式错误: %s", ct)
+	}
+
+	// 相同明文每次加密结果不同（随机 nonce）
+	ct2, _ := box.Encrypt("ghp_secret_token")
+	if ct == ct2 {
+		t.Error("相同明文的密文不应相同")
+	}
+
+	pt, err := box.Decrypt(ct)
+	if err != nil || pt != "ghp_secret_token" {
+		t.Fatalf("Decrypt = %q, %v", pt, err)
+	}
+}
+
+func TestDecryptWrongKey(t *testing.T) {
+	a, _ := New("key-a")
+	b, _ := New("key-b")
+	ct, _ := a.Encrypt("secret")
+	if _, err := b.Decrypt(ct); err == nil {
+		t.Error("使用错误主密钥解密应失败")
+	}
+	if _, err := a.Decrypt("plain-text"); err == nil {
+		t.Error("无版本前缀的密文应失败")
+	}
+}
+
+func TestNewWithoutKey(t *testing.T) {
+	if _, err := New(""); err != ErrNoMasterKey {
+		t.Errorf("err = %v, want ErrNoMasterKey", err)
+	}
+}
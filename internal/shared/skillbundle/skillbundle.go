@@ -0,0 +1,190 @@
+// Package skillbundle 技能包格式
+//
+// 技能包是一个目录打成的 tar.gz（如 tar -czf code-review.tar.gz -C code-review .），结构为：
+//
+//	skill.yaml      清单：name、version（语义化版本）、description
+//	SKILL.md        技能说明（Agent 读取的入口，可选）
+//	scripts/        技能使用的脚本（可选）
+//	prompts/        提示词片段（可选）
+//
+// 包内只允许普通文件与目录，路径不得为绝对路径或包含 ..。API Server 上传时用 Inspect 校验并读取清单，
+// NodeManager 安装时用 Extract 解压（同样的校验），包的摘要（Digest）用于固定版本的完整性校验与本地缓存。
+package skillbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ManifestFile 清单文件名（位于包的根目录）
+	ManifestFile = "skill.yaml"
+	// MaxSize 技能包（压缩后）大小上限
+	MaxSize = 10 << 20
+	// MaxUnpackedSize 解压后的总大小上限
+	MaxUnpackedSize = 50 << 20
+	// MaxFiles 包内文件与目录数上限
+	MaxFiles = 1000
+)
+
+var (
+	namePattern    = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+	versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.-]+)?$`)
+)
+
+// Manifest 技能包清单（skill.yaml）
+type Manifest struct {
+	// Name 技能名称，也是安装目录名（小写字母、数字与 -）
+	Name string `yaml:"name" json:"name"`
+	// Version 语义化版本，如 1.2.0
+	Version string `yaml:"version" json:"version"`
+	// Description 说明
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+}
+
+// Validate 校验清单字段
+func (m *Manifest) Validate() error {
+	if !namePattern.MatchString(m.Name) {
+		return fmt.Errorf("%s: invalid name %q (lowercase letters, digits and -)", ManifestFile, m.Name)
+	}
+	if !ValidVersion(m.Version) {
+		return fmt.Errorf("%s: invalid version %q (semantic version such as 1.2.0)", ManifestFile, m.Version)
+	}
+	return nil
+}
+
+// ValidVersion 是否为合法的语义化版本
+func ValidVersion(version string) bool {
+	return versionPattern.MatchString(version)
+}
+
+// Digest 技能包摘要（sha256:<hex>）
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// ParseRef 解析 Agent 模板中的技能引用：<skill_id> 或 <skill_id>@<version>（固定版本）
+func ParseRef(ref string) (id, version string) {
+	id, version, _ = strings.Cut(strings.TrimSpace(ref), "@")
+	return id, version
+}
+
+// Inspect 校验技能包并返回其清单
+func Inspect(data []byte) (*Manifest, error) {
+	var manifest *Manifest
+	err := walk(data, func(name string, hdr *tar.Header, r io.Reader) error {
+		if name != ManifestFile {
+			return nil
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			return fmt.Errorf("%s must be a regular file", ManifestFile)
+		}
+		var m Manifest
+		if err := yaml.NewDecoder(r).Decode(&m); err != nil {
+			return fmt.Errorf("parse %s: %v", ManifestFile, err)
+		}
+		manifest = &m
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("%s not found in bundle root", ManifestFile)
+	}
+	if err := manifest.Validate(); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// Extract 将技能包解压到 dir（目录不存在时创建）
+func Extract(data []byte, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return walk(data, func(name string, hdr *tar.Header, r io.Reader) error {
+		dest := filepath.Join(dir, filepath.FromSlash(name))
+		if hdr.Typeflag == tar.TypeDir {
+			return os.MkdirAll(dest, 0o755)
+		}
+		if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+			return err
+		}
+		// 只保留可执行位（scripts/ 下的脚本）
+		mode := os.FileMode(0o644)
+		if hdr.Mode&0o111 != 0 {
+			mode = 0o755
+		}
+		f, err := os.OpenFile(dest, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	})
+}
+
+// walk 依次校验并处理包内的条目（name 为清理后的相对路径，根目录条目被跳过）
+func walk(data []byte, fn func(name string, hdr *tar.Header, r io.Reader) error) error {
+	if len(data) > MaxSize {
+		return fmt.Errorf("bundle exceeds %d bytes", MaxSize)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("bundle is not gzip compressed: %v", err)
+	}
+	defer gz.Close()
+	tr := tar.NewReader(gz)
+
+	var files int
+	var total int64
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read bundle: %v", err)
+		}
+		if path.IsAbs(hdr.Name) || strings.Contains(hdr.Name, "\\") {
+			return fmt.Errorf("invalid path %q in bundle", hdr.Name)
+		}
+		name := path.Clean(hdr.Name)
+		if name == "." {
+			continue
+		}
+		if name == ".." || strings.HasPrefix(name, "../") {
+			return fmt.Errorf("invalid path %q in bundle", hdr.Name)
+		}
+		if hdr.Typeflag != tar.TypeReg && hdr.Typeflag != tar.TypeDir {
+			return fmt.Errorf("unsupported entry %q in bundle (only regular files and directories)", hdr.Name)
+		}
+		if files++; files > MaxFiles {
+			return fmt.Errorf("bundle has more than %d entries", MaxFiles)
+		}
+		if total += hdr.Size; total > MaxUnpackedSize {
+			return fmt.Errorf("bundle unpacks to more than %d bytes", MaxUnpackedSize)
+		}
+		if err := fn(name, hdr, io.LimitReader(tr, hdr.Size)); err != nil {
+			return err
+		}
+	}
+}
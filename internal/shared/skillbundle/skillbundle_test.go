@@ -0,0 +1,107 @@
+package skillbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type entry struct {
+	name    string
+	content string
+	mode    int64
+	typ     byte
+}
+
+func pack(t *testing.T, entries ...entry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: e.mode, Size: int64(len(e.content)), Typeflag: e.typ}
+		if hdr.Typeflag == 0 {
+			hdr.Typeflag = tar.TypeReg
+		}
+		if hdr.Mode == 0 {
+			hdr.Mode = 0o644
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		tw.Write([]byte(e.content))
+	}
+	tw.Close()
+	gz.Close()
+	return buf.Bytes()
+}
+
+const manifest = "name: code-review\nversion: 1.2.0\ndescription: 审查代码\n"
+
+func TestInspect(t *testing.T) {
+	data := pack(t,
+		entry{name: "./", typ: tar.TypeDir},
+		entry{name: "./skill.yaml", content: manifest},
+		entry{name: "./scripts/", typ: tar.TypeDir},
+		entry{name: "./scripts/lint.sh", content: "#!/bin/sh\n", mode: 0o755},
+	)
+	m, err := Inspect(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Name != "code-review" || m.Version != "1.2.0" || m.Description != "审查代码" {
+		t.Errorf("manifest = %+v", m)
+	}
+
+	for name, data := range map[string][]byte{
+		"缺少清单":   pack(t, entry{name: "SKILL.md", content: "x"}),
+		"非法版本":   pack(t, entry{name: "skill.yaml", content: "name: a\nversion: latest\n"}),
+		"非法名称":   pack(t, entry{name: "skill.yaml", content: "name: A B\nversion: 1.0.0\n"}),
+		"目录穿越":   pack(t, entry{name: "skill.yaml", content: manifest}, entry{name: "../evil", content: "x"}),
+		"绝对路径":   pack(t, entry{name: "skill.yaml", content: manifest}, entry{name: "/etc/passwd", content: "x"}),
+		"符号链接":   pack(t, entry{name: "skill.yaml", content: manifest}, entry{name: "link", typ: tar.TypeSymlink}),
+		"非 gzip": []byte("not a bundle"),
+	} {
+		if _, err := Inspect(data); err == nil {
+			t.Errorf("%s: 期望校验失败", name)
+		}
+	}
+}
+
+func TestExtract(t *testing.T) {
+	data := pack(t,
+		entry{name: "skill.yaml", content: manifest},
+		entry{name: "prompts/review.md", content: "请审查"},
+		entry{name: "scripts/lint.sh", content: "#!/bin/sh\n", mode: 0o755},
+	)
+	dir := filepath.Join(t.TempDir(), "code-review")
+	if err := Extract(data, dir); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, "prompts", "review.md")); string(got) != "请审查" {
+		t.Errorf("prompts/review.md = %q", got)
+	}
+	info, err := os.Stat(filepath.Join(dir, "scripts", "lint.sh"))
+	if err != nil || info.Mode().Perm()&0o100 == 0 {
+		t.Errorf("脚本应保留可执行位: %v %v", info, err)
+	}
+}
+
+func TestParseRef(t *testing.T) {
+	if id, v := ParseRef("skill-1@1.2.0"); id != "skill-1" || v != "1.2.0" {
+		t.Errorf("ParseRef = %s, %s", id, v)
+	}
+	if id, v := ParseRef("skill-1"); id != "skill-1" || v != "" {
+		t.Errorf("ParseRef = %s, %s", id, v)
+	}
+	if d := Digest([]byte("x")); !strings.HasPrefix(d, "sha256:") || len(d) != 71 {
+		t.Errorf("Digest = %s", d)
+	}
+}
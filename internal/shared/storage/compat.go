@@ -5,7 +5,7 @@
 //
 // 多数据库支持：
 //   - 使用 NewPersistentStore(driverType, dsn) 创建支持多种数据库的存储
-//   - 或直接使用 postgres.NewStore() / NewSQLiteStore() 创建特定数据库存储
+//   - 或直接使用 postgres.NewStore() / NewSQLiteStore() / NewMySQLStore() 创建特定数据库存储
 //
 // 迁移指南：
 //  1. 将字段类型从 *storage.PostgresStore 改为 storage.PersistentStore（接口类型）
@@ -14,9 +14,13 @@
 package storage
 
 import (
+	"database/sql"
 	"fmt"
+	"time"
 
 	"agents-admin/internal/shared/storage/dbutil"
+	mysqldriver "agents-admin/internal/shared/storage/driver/mysql"
+	pgdriver "agents-admin/internal/shared/storage/driver/postgres"
 	sqlitedriver "agents-admin/internal/shared/storage/driver/sqlite"
 	"agents-admin/internal/shared/storage/etcd"
 	"agents-admin/internal/shared/storage/postgres"
@@ -105,17 +109,82 @@ func NewSQLiteStore(dsn string) (*RepositoryStore, error) {
 	return repository.NewStore(db, dialect), nil
 }
 
-// NewPersistentStoreFromDSN 根据驱动类型和 DSN 创建持久化存储
-// 支持的驱动类型：postgres, sqlite, mongodb
+// NewMySQLStore 创建 MySQL / MariaDB 存储（含自动建表）
+func NewMySQLStore(dsn string) (*RepositoryStore, error) {
+	return newMySQLStore(dsn, dbutil.PoolConfig{})
+}
+
+func newMySQLStore(dsn string, pool dbutil.PoolConfig) (*RepositoryStore, error) {
+	db, err := mysqldriver.OpenWithPool(dsn, pool)
+	if err != nil {
+		return nil, err
+	}
+	dialect := mysqldriver.NewDialect()
+	if err := dialect.AutoMigrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("mysql auto-migrate failed: %w", err)
+	}
+	return repository.NewStore(db, dialect), nil
+}
+
+// NewPersistentStoreFromDSN 根据驱动类型和 DSN 创建持久化存储（默认连接池配置）
+// 支持的驱动类型：postgres, sqlite, mysql, mongodb
 func NewPersistentStoreFromDSN(driver dbutil.DriverType, dsn string) (PersistentStore, error) {
+	return NewPersistentStoreWithPool(driver, dsn, dbutil.PoolConfig{})
+}
+
+// NewPersistentStoreWithPool 根据驱动类型、DSN 与连接池配置创建持久化存储
+// 连接池配置对 PostgreSQL / MySQL 生效，SQLite 忽略
+func NewPersistentStoreWithPool(driver dbutil.DriverType, dsn string, pool dbutil.PoolConfig) (PersistentStore, error) {
 	switch driver {
 	case dbutil.DriverPostgres:
-		return NewPostgresStore(dsn)
+		db, err := pgdriver.OpenWithPool(dsn, pool)
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewStoreFromDB(db), nil
 	case dbutil.DriverSQLite:
 		return NewSQLiteStore(dsn)
+	case dbutil.DriverMySQL:
+		return newMySQLStore(dsn, pool)
 	case dbutil.DriverMongoDB:
 		return nil, fmt.Errorf("mongodb driver requires NewMongoStore(uri, dbName); use NewPersistentStoreFromConfig instead")
 	default:
 		return nil, fmt.Errorf("unsupported database driver: %s", driver)
 	}
 }
+
+// SetReadReplicaFromDSN 为 PostgreSQL / MySQL 存储配置只读副本（见 repository.Store.SetReadReplica）
+// maxLag 为允许的最大复制延迟，<= 0 时使用默认值；副本连接池使用与主库相同的配置
+func SetReadReplicaFromDSN(store PersistentStore, driver dbutil.DriverType, dsn string, maxLag time.Duration, pool dbutil.PoolConfig) error {
+	rs, ok := store.(*RepositoryStore)
+	if !ok {
+		return fmt.Errorf("read replica not supported for driver %s", driver)
+	}
+	var db *sql.DB
+	var err error
+	switch driver {
+	case dbutil.DriverPostgres:
+		db, err = pgdriver.OpenWithPool(dsn, pool)
+	case dbutil.DriverMySQL:
+		db, err = mysqldriver.OpenWithPool(dsn, pool)
+	default:
+		return fmt.Errorf("read replica not supported for driver %s", driver)
+	}
+	if err != nil {
+		return err
+	}
+	rs.SetReadReplica(db, maxLag)
+	return nil
+}
+
+// SetEventCompression 为 PostgreSQL / MySQL / SQLite 存储设置事件压缩阈值（见 repository.Store.SetEventCompression）
+// MongoDB 由 WiredTiger 块压缩处理，不支持按事件压缩
+func SetEventCompression(store PersistentStore, threshold int) error {
+	rs, ok := store.(*RepositoryStore)
+	if !ok {
+		return fmt.Errorf("event compression not supported by this storage driver")
+	}
+	rs.SetEventCompression(threshold)
+	return nil
+}
@@ -0,0 +1,55 @@
+package dbutil
+
+import (
+	"database/sql"
+	"time"
+)
+
+// 连接池默认值
+const (
+	DefaultMaxOpenConns    = 25
+	DefaultMaxIdleConns    = 5
+	DefaultConnMaxLifetime = 5 * time.Minute
+)
+
+// PoolConfig 数据库连接池配置
+//
+// 零值字段使用默认值：最大连接 25、最大空闲 5、连接最长存活 5 分钟、不限制空闲时长。
+type PoolConfig struct {
+	MaxOpenConns    int           // 最大打开连接数
+	MaxIdleConns    int           // 最大空闲连接数
+	ConnMaxLifetime time.Duration // 连接最长存活时间
+	ConnMaxIdleTime time.Duration // 连接最长空闲时间（0 表示不限制）
+
+	// StatementCacheCapacity 每个连接的预编译语句缓存容量（仅 PostgreSQL）
+	// 0 使用驱动默认值（512），负数禁用缓存（如 PgBouncer 事务池模式）
+	StatementCacheCapacity int
+}
+
+// withDefaults 返回填充默认值后的配置
+func (c PoolConfig) withDefaults() PoolConfig {
+	if c.MaxOpenConns <= 0 {
+		c.MaxOpenConns = DefaultMaxOpenConns
+	}
+	if c.MaxIdleConns <= 0 {
+		c.MaxIdleConns = DefaultMaxIdleConns
+	}
+	if c.MaxIdleConns > c.MaxOpenConns {
+		c.MaxIdleConns = c.MaxOpenConns
+	}
+	if c.ConnMaxLifetime <= 0 {
+		c.ConnMaxLifetime = DefaultConnMaxLifetime
+	}
+	return c
+}
+
+// Apply 将连接池配置应用到 db
+func (c PoolConfig) Apply(db *sql.DB) {
+	c = c.withDefaults()
+	db.SetMaxOpenConns(c.MaxOpenConns)
+	db.SetMaxIdleConns(c.MaxIdleConns)
+	db.SetConnMaxLifetime(c.ConnMaxLifetime)
+	if c.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(c.ConnMaxIdleTime)
+	}
+}
@@ -6,7 +6,9 @@ package postgres
 import (
 	"database/sql"
 	"fmt"
-	"time"
+	"net/url"
+	"strconv"
+	"strings"
 
 	"agents-admin/internal/shared/storage/dbutil"
 
@@ -65,16 +67,23 @@ func (d *Dialect) AutoMigrate(db *sql.DB) error {
 	return nil
 }
 
-// Open 创建 PostgreSQL 数据库连接
+// Open 创建 PostgreSQL 数据库连接（默认连接池配置）
 func Open(databaseURL string) (*sql.DB, error) {
-	db, err := sql.Open("pgx", databaseURL)
+	return OpenWithPool(databaseURL, dbutil.PoolConfig{})
+}
+
+// OpenWithPool 按连接池配置创建 PostgreSQL 数据库连接
+func OpenWithPool(databaseURL string, pool dbutil.PoolConfig) (*sql.DB, error) {
+	dsn, err := withStatementCache(databaseURL, pool.StatementCacheCapacity)
+	if err != nil {
+		return nil, fmt.Errorf("invalid postgres url: %w", err)
+	}
+	db, err := sql.Open("pgx", dsn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open postgres: %w", err)
 	}
 
-	db.SetMaxOpenConns(25)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(5 * time.Minute)
+	pool.Apply(db)
 
 	if err := db.Ping(); err != nil {
 		return nil, fmt.Errorf("failed to ping postgres: %w", err)
@@ -83,6 +92,38 @@ func Open(databaseURL string) (*sql.DB, error) {
 	return db, nil
 }
 
+// withStatementCache 在连接串中设置 pgx 预编译语句缓存容量
+//
+// capacity 为 0 时不修改；负数时禁用语句缓存，改用 cache_describe 模式（只缓存结果描述，
+// 不在服务端保留命名语句，兼容 PgBouncer 事务池）。
+func withStatementCache(databaseURL string, capacity int) (string, error) {
+	if capacity == 0 {
+		return databaseURL, nil
+	}
+	params := [][2]string{{"statement_cache_capacity", strconv.Itoa(capacity)}}
+	if capacity < 0 {
+		params = [][2]string{{"statement_cache_capacity", "0"}, {"default_query_exec_mode", "cache_describe"}}
+	}
+
+	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+		u, err := url.Parse(databaseURL)
+		if err != nil {
+			return "", err
+		}
+		q := u.Query()
+		for _, p := range params {
+			q.Set(p[0], p[1])
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	// key=value 格式
+	for _, p := range params {
+		databaseURL = strings.TrimSpace(databaseURL + " " + p[0] + "=" + p[1])
+	}
+	return databaseURL, nil
+}
+
 // NewDialect 创建 PostgreSQL 方言
 func NewDialect() *Dialect {
 	return &Dialect{}
@@ -75,6 +75,7 @@ func Open(dsn string) (*sql.DB, error) {
 
 	// SQLite 优化设置
 	pragmas := []string{
+		"PRAGMA auto_vacuum=INCREMENTAL", // 仅对新建数据库生效，存量数据库由存储维护任务切换
 		"PRAGMA journal_mode=WAL",
 		"PRAGMA synchronous=NORMAL",
 		"PRAGMA foreign_keys=ON",
@@ -113,8 +114,17 @@ CREATE TABLE IF NOT EXISTS tasks (
     security TEXT,
     labels TEXT DEFAULT '{}',
     context TEXT,
+    hooks TEXT,
+    secrets TEXT,
     template_id VARCHAR(64),
     agent_id VARCHAR(64),
+    project_id VARCHAR(64),
+    priority VARCHAR(16) NOT NULL DEFAULT 'normal',
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    scheduling TEXT,
+    region VARCHAR(64) NOT NULL DEFAULT '',
+    spawned_by_run_id VARCHAR(64),
+    session TEXT,
     created_at DATETIME DEFAULT (datetime('now')),
     updated_at DATETIME DEFAULT (datetime('now'))
 );
@@ -128,7 +138,9 @@ CREATE TABLE IF NOT EXISTS runs (
     started_at DATETIME,
     finished_at DATETIME,
     snapshot TEXT,
+    priority VARCHAR(16) NOT NULL DEFAULT 'normal',
     error TEXT,
+    last_event_seq INTEGER NOT NULL DEFAULT 0,
     created_at DATETIME DEFAULT (datetime('now')),
     updated_at DATETIME DEFAULT (datetime('now'))
 );
@@ -141,8 +153,15 @@ CREATE TABLE IF NOT EXISTS events (
     type VARCHAR(64),
     timestamp DATETIME,
     payload TEXT,
-    raw TEXT
+    raw TEXT,
+    schema_version INTEGER NOT NULL DEFAULT 0,
+    body_zstd BLOB,
+    raw_ref TEXT,
+    node_seq INTEGER,
+    redacted BOOLEAN NOT NULL DEFAULT FALSE
 );
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_run_id_seq ON events(run_id, seq);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_events_run_id_node_seq ON events(run_id, node_seq);
 
 -- nodes
 CREATE TABLE IF NOT EXISTS nodes (
@@ -153,6 +172,8 @@ CREATE TABLE IF NOT EXISTS nodes (
     display_name VARCHAR(255) DEFAULT '',
     labels TEXT DEFAULT '{}',
     capacity TEXT DEFAULT '{}',
+    capacity_override TEXT,
+    taints TEXT,
     last_heartbeat DATETIME,
     created_at DATETIME DEFAULT (datetime('now')),
     updated_at DATETIME DEFAULT (datetime('now'))
@@ -247,6 +268,8 @@ CREATE TABLE IF NOT EXISTS agents (
 CREATE TABLE IF NOT EXISTS terminal_sessions (
     id VARCHAR(64) PRIMARY KEY,
     instance_id VARCHAR(64),
+    run_id VARCHAR(64),
+    created_by VARCHAR(64),
     container_name VARCHAR(200),
     node_id VARCHAR(64),
     port INTEGER,
@@ -327,6 +350,7 @@ CREATE TABLE IF NOT EXISTS task_templates (
     default_workspace TEXT,
     default_security TEXT,
     default_labels TEXT DEFAULT '{}',
+    default_hooks TEXT,
     variables TEXT DEFAULT '[]',
     is_builtin INTEGER DEFAULT 0,
     category VARCHAR(64),
@@ -376,6 +400,18 @@ CREATE TABLE IF NOT EXISTS skills (
     updated_at DATETIME DEFAULT (datetime('now'))
 );
 
+-- skill_bundles (技能包版本，包内容存于对象存储)
+CREATE TABLE IF NOT EXISTS skill_bundles (
+    skill_id VARCHAR(64) NOT NULL REFERENCES skills(id) ON DELETE CASCADE,
+    version VARCHAR(64) NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    digest VARCHAR(80) NOT NULL,
+    size INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT (datetime('now')),
+    PRIMARY KEY (skill_id, version)
+);
+
 -- mcp_servers
 CREATE TABLE IF NOT EXISTS mcp_servers (
     id VARCHAR(64) PRIMARY KEY,
@@ -412,13 +448,373 @@ CREATE TABLE IF NOT EXISTS security_policies (
     updated_at DATETIME DEFAULT (datetime('now'))
 );
 
--- artifacts (referenced by task delete cascade)
+-- artifacts
 CREATE TABLE IF NOT EXISTS artifacts (
     id INTEGER PRIMARY KEY AUTOINCREMENT,
     run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
-    name VARCHAR(200),
-    type VARCHAR(64),
-    data TEXT,
+    name VARCHAR(200) NOT NULL,
+    path VARCHAR(500) NOT NULL,
+    size BIGINT,
+    content_type VARCHAR(100),
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_artifacts_run ON artifacts(run_id);
+
+-- credentials (Git 凭据，secret 为 AES-GCM 密文)
+CREATE TABLE IF NOT EXISTS credentials (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE,
+    type VARCHAR(32) NOT NULL,
+    username VARCHAR(200) DEFAULT '',
+    description TEXT DEFAULT '',
+    encrypted_secret TEXT NOT NULL,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+
+-- projects (项目默认 Agent 类型、账号池、安全配置)
+CREATE TABLE IF NOT EXISTS projects (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE,
+    description TEXT DEFAULT '',
+    default_agent_type VARCHAR(64) DEFAULT '',
+    allowed_agent_types TEXT,
+    account_pool TEXT,
+    default_security TEXT,
+    allowed_security_policies TEXT,
+    overrides TEXT,
+    result_publishing TEXT,
+    retention TEXT,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
+
+-- secrets (运行时密钥，value 为 AES-GCM 密文)
+CREATE TABLE IF NOT EXISTS secrets (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(128) NOT NULL UNIQUE,
+    description TEXT DEFAULT '',
+    encrypted_value TEXT NOT NULL,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+
+-- workflows (DAG 工作流，nodes 为节点与依赖边 JSON)
+CREATE TABLE IF NOT EXISTS workflows (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL DEFAULT '',
+    root_task_id VARCHAR(64) NOT NULL,
+    mode VARCHAR(32) NOT NULL DEFAULT 'fail_fast',
+    status VARCHAR(32) NOT NULL DEFAULT 'running',
+    nodes TEXT NOT NULL DEFAULT '[]',
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now')),
+    finished_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_workflows_status ON workflows(status);
+CREATE INDEX IF NOT EXISTS idx_workflows_root_task ON workflows(root_task_id);
+
+-- integrations (外部 Issue 集成：GitHub / Jira)
+CREATE TABLE IF NOT EXISTS integrations (
+    id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(200) NOT NULL UNIQUE,
+    provider VARCHAR(32) NOT NULL,
+    base_url VARCHAR(500) NOT NULL DEFAULT '',
+    repo VARCHAR(200) NOT NULL DEFAULT '',
+    project_key VARCHAR(64) NOT NULL DEFAULT '',
+    credential_ref VARCHAR(100) NOT NULL DEFAULT '',
+    comment_results BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+
+-- issue_links (导入任务与源 Issue 的反向关联)
+CREATE TABLE IF NOT EXISTS issue_links (
+    task_id VARCHAR(64) PRIMARY KEY,
+    integration_id VARCHAR(64) NOT NULL,
+    issue_key VARCHAR(64) NOT NULL,
+    url VARCHAR(500) NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_issue_links_issue ON issue_links(integration_id, issue_key);
+
+-- run_flags (Agent 输出内容审核标记)
+CREATE TABLE IF NOT EXISTS run_flags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    event_seq INTEGER NOT NULL DEFAULT 0,
+    detector VARCHAR(100) NOT NULL,
+    severity VARCHAR(16) NOT NULL,
+    count INTEGER NOT NULL DEFAULT 1,
+    aborted BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_run_flags_run ON run_flags(run_id);
+
+-- event_originals (被掩码事件的原文，启用 moderation.retain_originals 时写入)
+CREATE TABLE IF NOT EXISTS event_originals (
+    run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    seq INTEGER NOT NULL,
+    payload TEXT,
+    raw TEXT,
+    created_at DATETIME DEFAULT (datetime('now')),
+    PRIMARY KEY (run_id, seq)
+);
+
+-- regions (区域，节点通过 region 标签归属)
+CREATE TABLE IF NOT EXISTS regions (
+    id VARCHAR(64) PRIMARY KEY,
+    display_name VARCHAR(200) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    fallback VARCHAR(16) NOT NULL DEFAULT '',
+    fallback_regions TEXT,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_tasks_region ON tasks(region);
+CREATE INDEX IF NOT EXISTS idx_tasks_spawned_by_run ON tasks(spawned_by_run_id);
+
+-- scheduling_decisions (调度决策审计，连续相同的决策合并计数)
+CREATE TABLE IF NOT EXISTS scheduling_decisions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    run_id VARCHAR(64) NOT NULL REFERENCES runs(id) ON DELETE CASCADE,
+    outcome VARCHAR(32) NOT NULL,
+    strategy VARCHAR(64) NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    node_id TEXT NOT NULL DEFAULT '',
+    candidates TEXT NOT NULL DEFAULT '[]',
+    signature VARCHAR(32) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_scheduling_decisions_run ON scheduling_decisions(run_id, id);
+
+-- node_join_tokens / node_credentials (节点自动注册)
+CREATE TABLE IF NOT EXISTS node_join_tokens (
+    id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    description TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    use_count INTEGER NOT NULL DEFAULT 0,
+    labels TEXT,
+    pool TEXT NOT NULL DEFAULT '',
+    expires_at DATETIME NOT NULL,
+    used_at DATETIME,
+    used_by_node TEXT,
     created_at DATETIME DEFAULT (datetime('now'))
 );
+CREATE TABLE IF NOT EXISTS node_credentials (
+    node_id TEXT PRIMARY KEY,
+    token_hash TEXT NOT NULL UNIQUE,
+    cert_serial TEXT NOT NULL DEFAULT '',
+    cert_expires_at DATETIME,
+    join_token_id TEXT NOT NULL DEFAULT '',
+    labels TEXT,
+    created_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE TABLE IF NOT EXISTS node_join_token_uses (
+    id TEXT PRIMARY KEY,
+    token_id TEXT NOT NULL,
+    node_id TEXT NOT NULL,
+    hostname TEXT NOT NULL DEFAULT '',
+    remote_addr TEXT NOT NULL DEFAULT '',
+    used_at DATETIME DEFAULT (datetime('now'))
+);
+CREATE INDEX IF NOT EXISTS idx_node_join_token_uses_token ON node_join_token_uses(token_id, used_at DESC);
+
+-- maintenance_runs (存储维护执行记录)
+CREATE TABLE IF NOT EXISTS maintenance_runs (
+    id TEXT PRIMARY KEY,
+    driver TEXT NOT NULL,
+    trigger_type TEXT NOT NULL,
+    dry_run BOOLEAN NOT NULL DEFAULT 0,
+    status TEXT NOT NULL,
+    tables TEXT,
+    reclaimable_bytes INTEGER NOT NULL DEFAULT 0,
+    reclaimed_bytes INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    started_at DATETIME DEFAULT (datetime('now')),
+    finished_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_maintenance_runs_started ON maintenance_runs(started_at DESC);
+
+-- run_archives (Run 数据分层：事件归档记录)
+CREATE TABLE IF NOT EXISTS run_archives (
+    run_id VARCHAR(64) PRIMARY KEY REFERENCES runs(id) ON DELETE CASCADE,
+    project_id VARCHAR(64) NOT NULL DEFAULT '',
+    tier VARCHAR(16) NOT NULL,
+    archive_key TEXT NOT NULL,
+    event_count INTEGER NOT NULL DEFAULT 0,
+    archive_bytes INTEGER NOT NULL DEFAULT 0,
+    summary TEXT,
+    warm_at DATETIME DEFAULT (datetime('now')),
+    cold_at DATETIME,
+    purged_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_run_archives_tier ON run_archives(tier, warm_at);
+CREATE INDEX IF NOT EXISTS idx_run_archives_project ON run_archives(project_id, warm_at);
+CREATE INDEX IF NOT EXISTS idx_runs_finished_at ON runs(finished_at);
+
+-- webhooks (Webhook 订阅)
+CREATE TABLE IF NOT EXISTS webhooks (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL DEFAULT '',
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    filter TEXT,
+    created_by TEXT NOT NULL DEFAULT '',
+    last_delivery_at DATETIME,
+    last_status INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+
+-- 列表游标分页 (created_at DESC, id DESC)
+CREATE INDEX IF NOT EXISTS idx_tasks_created_at_id ON tasks(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_runs_created_at_id ON runs(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_runs_node_id ON runs(node_id);
+
+-- registry_items (模板注册表同步记录)
+CREATE TABLE IF NOT EXISTS registry_items (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    item_id TEXT NOT NULL,
+    source TEXT NOT NULL,
+    upstream_version TEXT NOT NULL DEFAULT '',
+    upstream_digest TEXT NOT NULL DEFAULT '',
+    upstream_spec TEXT,
+    installed_version TEXT NOT NULL DEFAULT '',
+    pinned BOOLEAN NOT NULL DEFAULT 0,
+    synced_at DATETIME NOT NULL,
+    installed_at DATETIME
+);
+
+-- run_usage (Run Token 用量与费用)
+CREATE TABLE IF NOT EXISTS run_usage (
+    run_id TEXT PRIMARY KEY,
+    task_id TEXT NOT NULL,
+    project_id TEXT NOT NULL DEFAULT '',
+    account_id TEXT NOT NULL DEFAULT '',
+    agent_type TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL DEFAULT '',
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_read_tokens INTEGER NOT NULL DEFAULT 0,
+    cache_write_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0,
+    day TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_run_usage_day ON run_usage(day);
+
+-- budgets (账号/项目月度预算)
+CREATE TABLE IF NOT EXISTS budgets (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    scope TEXT NOT NULL,
+    scope_id TEXT NOT NULL,
+    limit_tokens INTEGER NOT NULL DEFAULT 0,
+    limit_usd REAL NOT NULL DEFAULT 0,
+    alert_threshold REAL NOT NULL DEFAULT 0.8,
+    enabled BOOLEAN NOT NULL DEFAULT 1,
+    alerted_period TEXT NOT NULL DEFAULT '',
+    exceeded_period TEXT NOT NULL DEFAULT '',
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+-- audit_logs (变更操作审计日志，只追加)
+CREATE TABLE IF NOT EXISTS audit_logs (
+    id TEXT PRIMARY KEY,
+    actor_id TEXT NOT NULL DEFAULT '',
+    actor_email TEXT NOT NULL DEFAULT '',
+    actor_role TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL,
+    route TEXT NOT NULL,
+    path TEXT NOT NULL,
+    resource_type TEXT NOT NULL DEFAULT '',
+    resource_id TEXT NOT NULL DEFAULT '',
+    status INTEGER NOT NULL,
+    source_ip TEXT NOT NULL DEFAULT '',
+    request TEXT,
+    duration_ms INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_created ON audit_logs(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_actor ON audit_logs(actor_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_logs_resource ON audit_logs(resource_type, resource_id);
+
+-- outbox_messages (事务发件箱，与 Run 同事务写入，由中继投递到 Redis)
+CREATE TABLE IF NOT EXISTS outbox_messages (
+    id TEXT PRIMARY KEY,
+    topic TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    available_at DATETIME NOT NULL,
+    published_at DATETIME,
+    created_at DATETIME NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_pending ON outbox_messages(available_at) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_published ON outbox_messages(published_at) WHERE published_at IS NOT NULL;
+
+-- idempotency_keys (创建类请求的幂等键，有效期内重试返回原始响应)
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope TEXT NOT NULL,
+    idem_key TEXT NOT NULL,
+    request_hash TEXT NOT NULL,
+    resource_id TEXT NOT NULL DEFAULT '',
+    status_code INTEGER NOT NULL DEFAULT 0,
+    response TEXT,
+    created_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    PRIMARY KEY (scope, idem_key)
+);
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires ON idempotency_keys(expires_at);
+
+-- agent_types (自定义 Agent 类型与通用适配器配置)
+CREATE TABLE IF NOT EXISTS agent_types (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    image TEXT NOT NULL DEFAULT '',
+    auth_dir TEXT NOT NULL DEFAULT '',
+    auth_file TEXT NOT NULL DEFAULT '',
+    login_cmd TEXT NOT NULL DEFAULT '',
+    login_methods TEXT,
+    description TEXT NOT NULL DEFAULT '',
+    adapter TEXT,
+    created_at DATETIME DEFAULT (datetime('now')),
+    updated_at DATETIME DEFAULT (datetime('now'))
+);
+
+-- user_mfa / user_sessions (两步验证与登录会话)
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id VARCHAR(36) PRIMARY KEY,
+    secret TEXT NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT 0,
+    backup_codes TEXT NOT NULL DEFAULT '[]',
+    last_step INTEGER NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL DEFAULT (datetime('now')),
+    updated_at DATETIME NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id VARCHAR(36) NOT NULL,
+    method VARCHAR(32) NOT NULL DEFAULT 'password',
+    user_agent TEXT NOT NULL DEFAULT '',
+    ip VARCHAR(64) NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT (datetime('now')),
+    last_used_at DATETIME DEFAULT (datetime('now')),
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user ON user_sessions(user_id, created_at DESC);
 `
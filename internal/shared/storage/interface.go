@@ -130,6 +130,42 @@ const (
 // TaskFilter 任务查询过滤条件（类型重导出，避免循环导入）
 type TaskFilter = storagetypes.TaskFilter
 
+// RunFilter Run 查询过滤条件（类型重导出，避免循环导入）
+type RunFilter = storagetypes.RunFilter
+
+// AuditFilter 审计日志查询过滤条件（类型重导出，避免循环导入）
+type AuditFilter = storagetypes.AuditFilter
+
+// ArchiveRunFilter 待归档 Run 的查询条件（类型重导出，避免循环导入）
+type ArchiveRunFilter = storagetypes.ArchiveRunFilter
+
+// PageCursor 游标分页位置（类型重导出，避免循环导入）
+type PageCursor = storagetypes.PageCursor
+
+// LabelRequirement 标签选择器条件（类型重导出，避免循环导入）
+type LabelRequirement = storagetypes.LabelRequirement
+
+// ParsePageCursor 解析游标字符串
+func ParsePageCursor(s string) (*PageCursor, error) {
+	return storagetypes.ParsePageCursor(s)
+}
+
+// ParseLabelSelector 解析标签选择器：逗号分隔的 k=v、k!=v、k（存在）、!k（不存在）
+func ParseLabelSelector(selector string) ([]LabelRequirement, error) {
+	return storagetypes.ParseLabelSelector(selector)
+}
+
+// WithReadReplica 标记查询可以读取只读副本（见 storagetypes.WithReadReplica）
+func WithReadReplica(ctx context.Context) context.Context {
+	return storagetypes.WithReadReplica(ctx)
+}
+
+// ReplicaStatus 只读副本状态（类型重导出，避免循环导入）
+type ReplicaStatus = storagetypes.ReplicaStatus
+
+// EventSearchFilter 事件全文检索条件（类型重导出，避免循环导入）
+type EventSearchFilter = storagetypes.EventSearchFilter
+
 // TaskStore 任务存储接口
 type TaskStore interface {
 	CreateTask(ctx context.Context, task *model.Task) error
@@ -148,6 +184,7 @@ type RunStore interface {
 	CreateRun(ctx context.Context, run *model.Run) error
 	GetRun(ctx context.Context, id string) (*model.Run, error)
 	ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run, error)
+	ListRuns(ctx context.Context, filter RunFilter) ([]*model.Run, int, error) // 按条件分页列出 Run（SkipTotal 时总数为 -1）
 	ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error)
 	ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, error)
 	ListQueuedRuns(ctx context.Context, limit int) ([]*model.Run, error)
@@ -155,31 +192,96 @@ type RunStore interface {
 	ResetRunToQueued(ctx context.Context, id string) error
 	UpdateRunStatus(ctx context.Context, id string, status model.RunStatus, nodeID *string) error
 	UpdateRunError(ctx context.Context, id string, errMsg string) error
+	MarkRunTimeout(ctx context.Context, id string, errMsg string) (bool, error)                             // 仍处于 assigned/running 时标记为 timeout，返回是否标记成功
+	ReclaimRun(ctx context.Context, id, nodeID string, status model.RunStatus, errMsg string) (bool, error) // 仍分配在 nodeID 且活跃时回收为 queued/failed，返回是否回收成功
 	DeleteRun(ctx context.Context, id string) error
+	CountRunsByStatus(ctx context.Context, since time.Time) (map[model.RunStatus]int, error) // 按状态统计 since 之后创建的 Run
 }
 
 // EventStore 事件存储接口（归档）
 type EventStore interface {
-	CreateEvents(ctx context.Context, events []*model.Event) error
+	CreateEvents(ctx context.Context, events []*model.Event) error // 已存在的 (run_id, seq) 或 (run_id, node_seq) 被忽略（节点重试上报）
 	CountEventsByRun(ctx context.Context, runID string) (int, error)
+	AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error)                        // 为 Run 预留 n 个连续的事件序号，返回第一个
+	ListEventNodeSeqs(ctx context.Context, runID string, fromNodeSeq, toNodeSeq int) ([]int, error) // [from, to] 区间内已入库的节点序号
+	MaxEventSeqs(ctx context.Context, runID string) (seq, nodeSeq int, err error)                   // Run 已入库事件的最大序号与最大节点序号
 	GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error)
+	SearchEvents(ctx context.Context, filter EventSearchFilter) ([]*model.EventSearchHit, int, error)
+}
+
+// RunArchiveStore Run 数据分层（事件归档）存储接口
+type RunArchiveStore interface {
+	CreateRunArchive(ctx context.Context, a *model.RunArchive) error
+	GetRunArchive(ctx context.Context, runID string) (*model.RunArchive, error)
+	UpdateRunArchive(ctx context.Context, a *model.RunArchive) error
+	// ListRunsForArchive 列出 finished_at 早于 filter.Before、尚未归档的终态 Run（按结束时间升序）
+	ListRunsForArchive(ctx context.Context, filter ArchiveRunFilter) ([]*model.Run, error)
+	// ListRunArchives 列出指定层级中进入该层早于 before 的归档记录（按进入时间升序）
+	ListRunArchives(ctx context.Context, tier model.RunDataTier, before time.Time, limit int) ([]*model.RunArchive, error)
+	// ListExpiredRunArchives 列出项目中归档早于 before、归档对象尚未删除（warm/cold）的记录（按归档时间升序）
+	ListExpiredRunArchives(ctx context.Context, projectID string, before time.Time, limit int) ([]*model.RunArchive, error)
+	DeleteEventsByRun(ctx context.Context, runID string) (int64, error) // 连同保留的事件原文一起删除
+	ClearRunSnapshot(ctx context.Context, runID string) error
+	CountEvents(ctx context.Context) (int64, error)                    // 数据库中的事件总数
+	RunArchiveStats(ctx context.Context) ([]model.RunTierStats, error) // warm/cold 层统计
+}
+
+// RunFlagStore 内容审核标记存储接口
+type RunFlagStore interface {
+	CreateRunFlags(ctx context.Context, flags []*model.RunFlag) error
+	ListRunFlags(ctx context.Context, runID string) ([]*model.RunFlag, error)
+	CreateEventOriginals(ctx context.Context, originals []*model.EventOriginal) error          // 已存在的 (run_id, seq) 被忽略
+	GetEventOriginal(ctx context.Context, runID string, seq int) (*model.EventOriginal, error) // 未保留原文时返回 nil
+}
+
+// SchedulingDecisionStore 调度决策审计存储接口
+type SchedulingDecisionStore interface {
+	// RecordSchedulingDecision 记录调度决策，与该 Run 最近一条决策相同时只累加次数
+	RecordSchedulingDecision(ctx context.Context, d *model.SchedulingDecision) error
+	// ListSchedulingDecisions 列出 Run 最近的调度决策（按时间倒序）
+	ListSchedulingDecisions(ctx context.Context, runID string, limit int) ([]*model.SchedulingDecision, error)
+}
+
+// ArtifactStore 执行产物存储接口
+type ArtifactStore interface {
+	CreateArtifact(ctx context.Context, artifact *model.Artifact) error
+	ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error)
 }
 
 // NodeStore 节点存储接口
 type NodeStore interface {
 	UpsertNode(ctx context.Context, node *model.Node) error
-	UpsertNodeHeartbeat(ctx context.Context, node *model.Node) error // 心跳专用，不覆盖管理员设置的 status
+	UpsertNodeHeartbeat(ctx context.Context, node *model.Node) error           // 心跳专用，不覆盖管理员设置的 status
+	TouchNodeHeartbeat(ctx context.Context, nodeID string, at time.Time) error // 只刷新 last_heartbeat（内容未变化的增量心跳）
 	GetNode(ctx context.Context, id string) (*model.Node, error)
 	ListAllNodes(ctx context.Context) ([]*model.Node, error)
 	ListOnlineNodes(ctx context.Context) ([]*model.Node, error)
 	DeactivateStaleNodes(ctx context.Context, activeNodeID string, hostname string) error
 	DeleteNode(ctx context.Context, id string) error
+	GetNodeCapacityOverride(ctx context.Context, nodeID string) (*model.NodeConcurrencyOverride, error)
+	SetNodeCapacityOverride(ctx context.Context, nodeID string, o *model.NodeConcurrencyOverride) error // o 为空时清除覆盖
+	SetNodeTaints(ctx context.Context, nodeID string, taints []model.NodeTaint) error                   // taints 为空时清除
 	CreateNodeProvision(ctx context.Context, p *model.NodeProvision) error
 	UpdateNodeProvision(ctx context.Context, p *model.NodeProvision) error
 	GetNodeProvision(ctx context.Context, id string) (*model.NodeProvision, error)
 	ListNodeProvisions(ctx context.Context) ([]*model.NodeProvision, error)
 }
 
+// NodeJoinStore 节点加入令牌与专属凭证存储接口
+type NodeJoinStore interface {
+	CreateNodeJoinToken(ctx context.Context, t *model.NodeJoinToken) error
+	ListNodeJoinTokens(ctx context.Context) ([]*model.NodeJoinToken, error)
+	DeleteNodeJoinToken(ctx context.Context, id string) error
+	// ConsumeNodeJoinToken 原子地为未用尽且未过期的令牌增加一次使用计数，令牌无效时返回 nil
+	ConsumeNodeJoinToken(ctx context.Context, tokenHash, nodeID string, now time.Time) (*model.NodeJoinToken, error)
+	CreateNodeJoinTokenUse(ctx context.Context, u *model.NodeJoinTokenUse) error
+	ListNodeJoinTokenUses(ctx context.Context, tokenID string) ([]*model.NodeJoinTokenUse, error)
+	UpsertNodeCredential(ctx context.Context, c *model.NodeCredential) error
+	GetNodeCredential(ctx context.Context, nodeID string) (*model.NodeCredential, error)
+	GetNodeCredentialByTokenHash(ctx context.Context, tokenHash string) (*model.NodeCredential, error)
+	DeleteNodeCredential(ctx context.Context, nodeID string) error
+}
+
 // AccountStore 账号存储接口
 type AccountStore interface {
 	CreateAccount(ctx context.Context, account *model.Account) error
@@ -209,6 +311,7 @@ type OperationStore interface {
 	GetOperation(ctx context.Context, id string) (*model.Operation, error)
 	ListOperations(ctx context.Context, opType string, status string, limit, offset int) ([]*model.Operation, error)
 	UpdateOperationStatus(ctx context.Context, id string, status model.OperationStatus) error
+	UpdateOperationConfig(ctx context.Context, id string, config json.RawMessage) error
 }
 
 // ActionStore Action 存储接口
@@ -233,6 +336,66 @@ type ProxyStore interface {
 	DeleteProxy(ctx context.Context, id string) error
 }
 
+// CredentialStore 凭据存储接口
+type CredentialStore interface {
+	CreateCredential(ctx context.Context, cred *model.Credential) error
+	GetCredential(ctx context.Context, id string) (*model.Credential, error)
+	GetCredentialByName(ctx context.Context, name string) (*model.Credential, error)
+	ListCredentials(ctx context.Context) ([]*model.Credential, error)
+	UpdateCredential(ctx context.Context, cred *model.Credential) error
+	DeleteCredential(ctx context.Context, id string) error
+}
+
+// SecretStore 运行时密钥存储接口
+type SecretStore interface {
+	CreateSecret(ctx context.Context, secret *model.Secret) error
+	GetSecret(ctx context.Context, id string) (*model.Secret, error)
+	GetSecretByName(ctx context.Context, name string) (*model.Secret, error)
+	ListSecrets(ctx context.Context) ([]*model.Secret, error)
+	UpdateSecret(ctx context.Context, secret *model.Secret) error
+	DeleteSecret(ctx context.Context, id string) error
+}
+
+// ProjectStore 项目存储接口
+type ProjectStore interface {
+	CreateProject(ctx context.Context, project *model.Project) error
+	GetProject(ctx context.Context, id string) (*model.Project, error)
+	ListProjects(ctx context.Context) ([]*model.Project, error)
+	UpdateProject(ctx context.Context, project *model.Project) error
+	DeleteProject(ctx context.Context, id string) error
+}
+
+// RegionStore 区域存储接口
+type RegionStore interface {
+	CreateRegion(ctx context.Context, region *model.Region) error
+	GetRegion(ctx context.Context, id string) (*model.Region, error)
+	ListRegions(ctx context.Context) ([]*model.Region, error)
+	UpdateRegion(ctx context.Context, region *model.Region) error
+	DeleteRegion(ctx context.Context, id string) error
+	// CountQueuedRunsByRegion 按任务的 region 统计 queued 的 Run 数与最早排队时间
+	CountQueuedRunsByRegion(ctx context.Context) ([]model.RegionQueueDepth, error)
+}
+
+// WorkflowStore DAG 工作流存储接口
+type WorkflowStore interface {
+	CreateWorkflow(ctx context.Context, wf *model.Workflow) error
+	GetWorkflow(ctx context.Context, id string) (*model.Workflow, error)
+	ListWorkflows(ctx context.Context, status string, limit, offset int) ([]*model.Workflow, error) // status 为空时不过滤，按创建时间倒序
+	UpdateWorkflow(ctx context.Context, wf *model.Workflow) error                                   // 更新状态、节点与结束时间
+}
+
+// IntegrationStore 外部 Issue 集成存储接口
+type IntegrationStore interface {
+	CreateIntegration(ctx context.Context, integration *model.Integration) error
+	GetIntegration(ctx context.Context, id string) (*model.Integration, error)
+	ListIntegrations(ctx context.Context) ([]*model.Integration, error)
+	UpdateIntegration(ctx context.Context, integration *model.Integration) error
+	DeleteIntegration(ctx context.Context, id string) error
+	CreateIssueLink(ctx context.Context, link *model.IssueLink) error
+	GetIssueLinkByTask(ctx context.Context, taskID string) (*model.IssueLink, error)
+	GetIssueLinkByIssue(ctx context.Context, integrationID, issueKey string) (*model.IssueLink, error)
+}
+
 // AgentInstanceStore Agent 实例存储接口（原 InstanceStore，已重命名对齐领域模型）
 type AgentInstanceStore interface {
 	CreateAgentInstance(ctx context.Context, instance *model.Instance) error
@@ -241,6 +404,8 @@ type AgentInstanceStore interface {
 	ListAgentInstancesByNode(ctx context.Context, nodeID string) ([]*model.Instance, error)
 	ListPendingAgentInstances(ctx context.Context, nodeID string) ([]*model.Instance, error)
 	UpdateAgentInstance(ctx context.Context, id string, status model.InstanceStatus, containerName *string) error
+	// TransitionAgentInstance 仍处于 from 状态时更新为 to（containerName 非 nil 时一并回填），返回是否更新成功
+	TransitionAgentInstance(ctx context.Context, id string, from, to model.InstanceStatus, containerName *string) (bool, error)
 	DeleteAgentInstance(ctx context.Context, id string) error
 }
 
@@ -256,8 +421,11 @@ type TerminalSessionStore interface {
 	ListTerminalSessionsByNode(ctx context.Context, nodeID string) ([]*model.TerminalSession, error)
 	ListPendingTerminalSessions(ctx context.Context, nodeID string) ([]*model.TerminalSession, error)
 	UpdateTerminalSession(ctx context.Context, id string, status model.TerminalSessionStatus, port *int, url *string) error
+	// TransitionTerminalSession 仍处于 from 状态时更新为 to（port/url 非 nil 时一并回填），返回是否更新成功
+	TransitionTerminalSession(ctx context.Context, id string, from, to model.TerminalSessionStatus, port *int, url *string) (bool, error)
 	DeleteTerminalSession(ctx context.Context, id string) error
 	CleanupExpiredTerminalSessions(ctx context.Context) (int64, error)
+	CloseExpiredTerminalSessions(ctx context.Context) (int64, error)
 }
 
 // HITLStore Human-in-the-Loop 存储接口
@@ -277,6 +445,7 @@ type HITLStore interface {
 	GetConfirmation(ctx context.Context, id string) (*model.Confirmation, error)
 	ListConfirmations(ctx context.Context, runID string, status string) ([]*model.Confirmation, error)
 	UpdateConfirmationStatus(ctx context.Context, id string, status model.ConfirmStatus, selectedOption *string) error
+	CountPendingApprovals(ctx context.Context) (int, error) // 全部待处理的审批与确认请求数
 }
 
 // TemplateStore 模板存储接口
@@ -284,6 +453,7 @@ type TemplateStore interface {
 	CreateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error
 	GetTaskTemplate(ctx context.Context, id string) (*model.TaskTemplate, error)
 	ListTaskTemplates(ctx context.Context, category string) ([]*model.TaskTemplate, error)
+	UpdateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error
 	DeleteTaskTemplate(ctx context.Context, id string) error
 	CreateAgentTemplate(ctx context.Context, tmpl *model.AgentTemplate) error
 	GetAgentTemplate(ctx context.Context, id string) (*model.AgentTemplate, error)
@@ -298,6 +468,11 @@ type SkillStore interface {
 	GetSkill(ctx context.Context, id string) (*model.Skill, error)
 	ListSkills(ctx context.Context, category string) ([]*model.Skill, error)
 	DeleteSkill(ctx context.Context, id string) error
+
+	// 技能包版本（同一技能的版本唯一；列表按上传时间倒序，首个为最新版本）
+	CreateSkillBundle(ctx context.Context, bundle *model.SkillBundle) error
+	GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error)
+	ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error)
 }
 
 // MCPServerStore MCP Server 存储接口
@@ -338,20 +513,149 @@ type UserStore interface {
 	GetUserByEmail(ctx context.Context, email string) (*model.User, error)
 	GetUserByID(ctx context.Context, id string) (*model.User, error)
 	UpdateUserPassword(ctx context.Context, id, passwordHash string) error
+	UpdateUserRole(ctx context.Context, id string, role model.UserRole) error
 	ListUsers(ctx context.Context) ([]*model.User, error)
 }
 
+// UserSecurityStore 用户两步验证与登录会话存储接口
+type UserSecurityStore interface {
+	GetUserMFA(ctx context.Context, userID string) (*model.UserMFA, error)
+	SaveUserMFA(ctx context.Context, mfa *model.UserMFA) error // 不存在时创建，存在时覆盖
+	DeleteUserMFA(ctx context.Context, userID string) error
+	// UseUserMFAStep 原子地记录通过校验的 TOTP 时间步，step 不大于已记录的时间步时返回 false（动态码已使用过）
+	UseUserMFAStep(ctx context.Context, userID string, step int64) (bool, error)
+	// ConsumeUserBackupCode 原子地移除一个备用码（codeHash 为 SHA-256），不存在时返回 false
+	ConsumeUserBackupCode(ctx context.Context, userID, codeHash string) (bool, error)
+
+	CreateUserSession(ctx context.Context, session *model.UserSession) error
+	GetUserSession(ctx context.Context, id string) (*model.UserSession, error)
+	ListUserSessions(ctx context.Context, userID string) ([]*model.UserSession, error) // 未撤销且未过期的会话，按创建时间倒序
+	TouchUserSession(ctx context.Context, id string, at time.Time) error
+	RevokeUserSession(ctx context.Context, userID, id string) (bool, error)
+	RevokeUserSessions(ctx context.Context, userID, exceptID string) (int64, error) // 撤销用户除 exceptID 外的全部会话
+}
+
+// MaintenanceStore 存储维护执行记录接口
+type MaintenanceStore interface {
+	CreateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error
+	UpdateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error
+	ListMaintenanceRuns(ctx context.Context, limit int) ([]*model.MaintenanceRun, error)
+}
+
+// WebhookStore Webhook 订阅存储接口
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, w *model.Webhook) error
+	GetWebhook(ctx context.Context, id string) (*model.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	UpdateWebhook(ctx context.Context, w *model.Webhook) error
+	DeleteWebhook(ctx context.Context, id string) error
+	// RecordWebhookDelivery 记录最近一次投递结果（status 为 HTTP 状态码，0 表示请求未完成）
+	RecordWebhookDelivery(ctx context.Context, id string, at time.Time, status int, errMsg string) error
+}
+
+// AgentTypeStore 自定义 Agent 类型存储接口（预定义类型不入库）
+type AgentTypeStore interface {
+	CreateAgentType(ctx context.Context, t *model.AgentTypeConfig) error
+	GetAgentType(ctx context.Context, id string) (*model.AgentTypeConfig, error)
+	ListAgentTypes(ctx context.Context) ([]*model.AgentTypeConfig, error)
+	// UpdateAgentType 更新名称、镜像、认证配置、描述与适配器配置
+	UpdateAgentType(ctx context.Context, t *model.AgentTypeConfig) error
+	DeleteAgentType(ctx context.Context, id string) error
+}
+
+// RegistryStore 模板注册表同步记录存储接口
+type RegistryStore interface {
+	// UpsertRegistryItem 按 ID 写入同步记录（已存在时整体覆盖）
+	UpsertRegistryItem(ctx context.Context, item *model.RegistryItem) error
+	GetRegistryItem(ctx context.Context, id string) (*model.RegistryItem, error)
+	ListRegistryItems(ctx context.Context) ([]*model.RegistryItem, error)
+}
+
+// UsageStore Run Token 用量存储接口
+type UsageStore interface {
+	// AddRunUsage 将 Token 用量与费用累加到 Run 的记录（不存在时创建，任务、账号、Agent 类型与日期取首次写入的值）
+	AddRunUsage(ctx context.Context, usage *model.RunUsage) error
+	GetRunUsage(ctx context.Context, runID string) (*model.RunUsage, error)
+	// ListRunUsage 列出日期在 [since, until] 内的用量记录（YYYY-MM-DD，为空表示不限）
+	ListRunUsage(ctx context.Context, since, until string) ([]*model.RunUsage, error)
+}
+
+// BudgetStore 账号/项目月度预算存储接口
+type BudgetStore interface {
+	CreateBudget(ctx context.Context, b *model.Budget) error
+	GetBudget(ctx context.Context, id string) (*model.Budget, error)
+	ListBudgets(ctx context.Context) ([]*model.Budget, error)
+	// UpdateBudget 更新预算的名称、上限、预警阈值、启用状态与通知周期
+	UpdateBudget(ctx context.Context, b *model.Budget) error
+	DeleteBudget(ctx context.Context, id string) error
+}
+
+// AuditStore 变更操作审计日志存储接口（只追加）
+type AuditStore interface {
+	CreateAuditLog(ctx context.Context, entry *model.AuditLog) error
+	// ListAuditLogs 按请求时间倒序列出审计日志（SkipTotal 时总数为 -1）
+	ListAuditLogs(ctx context.Context, filter AuditFilter) ([]*model.AuditLog, int, error)
+}
+
+// OutboxStore 事务发件箱存储接口
+type OutboxStore interface {
+	// CreateRunWithOutbox 在同一事务中写入 Run 与发件箱消息
+	CreateRunWithOutbox(ctx context.Context, run *model.Run, msgs ...*model.OutboxMessage) error
+	// ClaimOutboxMessages 认领最多 limit 条已到期的未投递消息（按可用时间升序），
+	// 认领的消息 Attempts 加一、可用时间推迟 lease，期间其他中继不会再次认领
+	ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error)
+	MarkOutboxPublished(ctx context.Context, id string) error
+	// MarkOutboxFailed 记录投递失败原因，消息在 retryAt 之后可再次认领
+	MarkOutboxFailed(ctx context.Context, id, errMsg string, retryAt time.Time) error
+	// DeletePublishedOutbox 删除投递时间早于 before 的消息，返回删除条数
+	DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error)
+}
+
+// IdempotencyStore 创建类请求幂等键存储接口
+type IdempotencyStore interface {
+	// ClaimIdempotencyKey 原子地认领幂等键：键不存在或已过期时写入 k 并返回 nil，
+	// 否则返回已有记录（处理中或已完成）
+	ClaimIdempotencyKey(ctx context.Context, k *model.IdempotencyKey) (*model.IdempotencyKey, error)
+	// CompleteIdempotencyKey 记录创建的资源与原始响应
+	CompleteIdempotencyKey(ctx context.Context, scope, key, resourceID string, statusCode int, response []byte) error
+	// DeleteIdempotencyKey 释放幂等键（请求处理失败时调用，客户端可用同一个键重试）
+	DeleteIdempotencyKey(ctx context.Context, scope, key string) error
+	// DeleteExpiredIdempotencyKeys 删除过期时间早于 before 的记录，返回删除条数
+	DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Maintainer 驱动级存储维护（PostgreSQL VACUUM、SQLite incremental_vacuum、MongoDB compact）
+//
+// 不属于 PersistentStore：由支持的存储实现，调用方通过类型断言判断是否可用。
+type Maintainer interface {
+	// EstimateMaintenance 估算各表的占用与可回收空间（dry-run，不修改数据）
+	EstimateMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error)
+	// RunMaintenance 对各表执行维护，返回执行前后的占用；单表失败记录在该表的 Error 中
+	RunMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error)
+}
+
 // PersistentStore 持久化存储组合接口
 type PersistentStore interface {
 	TaskStore
 	RunStore
 	EventStore
+	RunArchiveStore
+	ArtifactStore
+	RunFlagStore
+	SchedulingDecisionStore
 	NodeStore
+	NodeJoinStore
 	AccountStore
 	AuthTaskStore
 	OperationStore
 	ActionStore
 	ProxyStore
+	CredentialStore
+	SecretStore
+	ProjectStore
+	RegionStore
+	WorkflowStore
+	IntegrationStore
 	InstanceStore
 	TerminalSessionStore
 	HITLStore
@@ -360,6 +664,16 @@ type PersistentStore interface {
 	MCPServerStore
 	SecurityPolicyStore
 	UserStore
+	UserSecurityStore
+	MaintenanceStore
+	WebhookStore
+	AgentTypeStore
+	RegistryStore
+	UsageStore
+	BudgetStore
+	AuditStore
+	OutboxStore
+	IdempotencyStore
 	Close() error
 }
 
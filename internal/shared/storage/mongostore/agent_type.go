@@ -0,0 +1,45 @@
+package mongostore
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// AgentTypeStore
+// ============================================================================
+
+func (s *Store) CreateAgentType(ctx context.Context, t *model.AgentTypeConfig) error {
+	return insertOne(ctx, s.col(ColAgentTypes), t)
+}
+
+func (s *Store) GetAgentType(ctx context.Context, id string) (*model.AgentTypeConfig, error) {
+	return findOne[model.AgentTypeConfig](ctx, s.col(ColAgentTypes), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListAgentTypes(ctx context.Context) ([]*model.AgentTypeConfig, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	return findMany[model.AgentTypeConfig](ctx, s.col(ColAgentTypes), bson.D{}, opts)
+}
+
+func (s *Store) UpdateAgentType(ctx context.Context, t *model.AgentTypeConfig) error {
+	return updateFields(ctx, s.col(ColAgentTypes), t.ID, bson.D{
+		{Key: "name", Value: t.Name},
+		{Key: "image", Value: t.Image},
+		{Key: "auth_dir", Value: t.AuthDir},
+		{Key: "auth_file", Value: t.AuthFile},
+		{Key: "login_cmd", Value: t.LoginCmd},
+		{Key: "login_methods", Value: t.LoginMethods},
+		{Key: "description", Value: t.Description},
+		{Key: "adapter", Value: t.Adapter},
+		{Key: "updated_at", Value: t.UpdatedAt},
+	})
+}
+
+func (s *Store) DeleteAgentType(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColAgentTypes), id)
+}
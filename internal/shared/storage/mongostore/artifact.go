@@ -0,0 +1,28 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// ArtifactStore
+// ============================================================================
+
+func (s *Store) CreateArtifact(ctx context.Context, artifact *model.Artifact) error {
+	// MongoDB 无自增主键，使用纳秒时间戳作为 ID（与 SQL 实现的 BIGSERIAL 语义一致：单调递增）
+	if artifact.ID == 0 {
+		artifact.ID = time.Now().UnixNano()
+	}
+	return insertOne(ctx, s.col(ColArtifacts), artifact)
+}
+
+func (s *Store) ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	return findMany[model.Artifact](ctx, s.col(ColArtifacts), bson.D{{Key: "run_id", Value: runID}}, opts)
+}
@@ -0,0 +1,48 @@
+package mongostore
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ============================================================================
+// AuditStore
+// ============================================================================
+
+func (s *Store) CreateAuditLog(ctx context.Context, e *model.AuditLog) error {
+	return insertOne(ctx, s.col(ColAuditLogs), e)
+}
+
+func (s *Store) ListAuditLogs(ctx context.Context, af storagetypes.AuditFilter) ([]*model.AuditLog, int, error) {
+	filter := bson.D{}
+	for _, f := range []struct{ key, value string }{
+		{"actor_id", af.ActorID},
+		{"method", af.Method},
+		{"resource_type", af.ResourceType},
+		{"resource_id", af.ResourceID},
+	} {
+		if f.value != "" {
+			filter = append(filter, bson.E{Key: f.key, Value: f.value})
+		}
+	}
+	filter = createdRangeFilter(filter, af.Since, af.Until)
+
+	total := int64(-1)
+	if !af.SkipTotal {
+		var err error
+		if total, err = s.col(ColAuditLogs).CountDocuments(ctx, filter); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	filter = cursorFilter(filter, af.Cursor)
+	logs, err := findMany[model.AuditLog](ctx, s.col(ColAuditLogs), filter, pageOptions(af.Cursor, af.Limit, af.Offset))
+	if err != nil {
+		return nil, 0, err
+	}
+	return logs, int(total), nil
+}
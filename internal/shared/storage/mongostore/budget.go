@@ -0,0 +1,44 @@
+package mongostore
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// BudgetStore
+// ============================================================================
+
+func (s *Store) CreateBudget(ctx context.Context, b *model.Budget) error {
+	return insertOne(ctx, s.col(ColBudgets), b)
+}
+
+func (s *Store) GetBudget(ctx context.Context, id string) (*model.Budget, error) {
+	return findOne[model.Budget](ctx, s.col(ColBudgets), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListBudgets(ctx context.Context) ([]*model.Budget, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	return findMany[model.Budget](ctx, s.col(ColBudgets), bson.D{}, opts)
+}
+
+func (s *Store) UpdateBudget(ctx context.Context, b *model.Budget) error {
+	return updateFields(ctx, s.col(ColBudgets), b.ID, bson.D{
+		{Key: "name", Value: b.Name},
+		{Key: "limit_tokens", Value: b.LimitTokens},
+		{Key: "limit_usd", Value: b.LimitUSD},
+		{Key: "alert_threshold", Value: b.AlertThreshold},
+		{Key: "enabled", Value: b.Enabled},
+		{Key: "alerted_period", Value: b.AlertedPeriod},
+		{Key: "exceeded_period", Value: b.ExceededPeriod},
+		{Key: "updated_at", Value: b.UpdatedAt},
+	})
+}
+
+func (s *Store) DeleteBudget(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColBudgets), id)
+}
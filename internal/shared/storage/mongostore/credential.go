@@ -0,0 +1,47 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// CredentialStore
+// ============================================================================
+
+func (s *Store) CreateCredential(ctx context.Context, cred *model.Credential) error {
+	return insertOne(ctx, s.col(ColCredentials), cred)
+}
+
+func (s *Store) GetCredential(ctx context.Context, id string) (*model.Credential, error) {
+	return findOne[model.Credential](ctx, s.col(ColCredentials), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) GetCredentialByName(ctx context.Context, name string) (*model.Credential, error) {
+	return findOne[model.Credential](ctx, s.col(ColCredentials), bson.D{{Key: "name", Value: name}})
+}
+
+func (s *Store) ListCredentials(ctx context.Context) ([]*model.Credential, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	return findMany[model.Credential](ctx, s.col(ColCredentials), bson.D{}, opts)
+}
+
+func (s *Store) UpdateCredential(ctx context.Context, cred *model.Credential) error {
+	return updateFields(ctx, s.col(ColCredentials), cred.ID, bson.D{
+		{Key: "name", Value: cred.Name},
+		{Key: "type", Value: cred.Type},
+		{Key: "username", Value: cred.Username},
+		{Key: "description", Value: cred.Description},
+		{Key: "encrypted_secret", Value: cred.EncryptedSecret},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
+func (s *Store) DeleteCredential(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColCredentials), id)
+}
@@ -2,10 +2,15 @@ package mongostore
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"regexp"
 
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
@@ -13,15 +18,25 @@ import (
 // EventStore
 // ============================================================================
 
+// CreateEvents 批量创建事件，(run_id, seq) 或 (run_id, node_seq) 已存在的事件被忽略（upsert，只在插入时写入）
+//
+// 节点上报的事件按 run_id + node_seq 匹配，API Server 自身写入的事件（node_seq 为 0）按 run_id + seq 匹配。
 func (s *Store) CreateEvents(ctx context.Context, events []*model.Event) error {
 	if len(events) == 0 {
 		return nil
 	}
-	docs := make([]interface{}, len(events))
+	models := make([]mongo.WriteModel, len(events))
 	for i, e := range events {
-		docs[i] = e
+		filter := bson.D{{Key: "run_id", Value: e.RunID}, {Key: "seq", Value: e.Seq}}
+		if e.NodeSeq > 0 {
+			filter = bson.D{{Key: "run_id", Value: e.RunID}, {Key: "node_seq", Value: e.NodeSeq}}
+		}
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(filter).
+			SetUpdate(bson.D{{Key: "$setOnInsert", Value: e}}).
+			SetUpsert(true)
 	}
-	_, err := s.col(ColEvents).InsertMany(ctx, docs)
+	_, err := s.col(ColEvents).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
 	return wrapError(err)
 }
 
@@ -30,6 +45,87 @@ func (s *Store) CountEventsByRun(ctx context.Context, runID string) (int, error)
 	return int(count), err
 }
 
+// AllocateEventSeqs 为 Run 预留 n 个连续的事件序号，返回第一个
+//
+// 计数器为 runs 文档的 last_event_seq。升级前创建的 Run 没有该字段，首次分配时按已有事件的最大 seq 初始化
+// （条件更新只对仍缺少该字段的文档生效，并发初始化不会覆盖已分配的序号）。
+func (s *Store) AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid event seq count: %d", n)
+	}
+	missing := bson.D{{Key: "_id", Value: runID}, {Key: "last_event_seq", Value: bson.D{{Key: "$exists", Value: false}}}}
+	if cnt, err := s.col(ColRuns).CountDocuments(ctx, missing); err != nil {
+		return 0, wrapError(err)
+	} else if cnt > 0 {
+		maxSeq, _, err := s.MaxEventSeqs(ctx, runID)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := s.col(ColRuns).UpdateOne(ctx, missing, bson.D{{Key: "$set", Value: bson.D{{Key: "last_event_seq", Value: maxSeq}}}}); err != nil {
+			return 0, wrapError(err)
+		}
+	}
+
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).
+		SetProjection(bson.D{{Key: "last_event_seq", Value: 1}})
+	var doc struct {
+		LastEventSeq int `bson:"last_event_seq"`
+	}
+	err := s.col(ColRuns).FindOneAndUpdate(ctx, bson.D{{Key: "_id", Value: runID}},
+		bson.D{{Key: "$inc", Value: bson.D{{Key: "last_event_seq", Value: n}}}}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, fmt.Errorf("run %s not found", runID)
+	}
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return doc.LastEventSeq - n + 1, nil
+}
+
+// ListEventNodeSeqs 返回 Run 中节点序号在 [fromNodeSeq, toNodeSeq] 区间内已入库的事件的节点序号
+func (s *Store) ListEventNodeSeqs(ctx context.Context, runID string, fromNodeSeq, toNodeSeq int) ([]int, error) {
+	filter := bson.D{
+		{Key: "run_id", Value: runID},
+		{Key: "node_seq", Value: bson.D{{Key: "$gte", Value: fromNodeSeq}, {Key: "$lte", Value: toNodeSeq}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "node_seq", Value: 1}}).SetProjection(bson.D{{Key: "node_seq", Value: 1}})
+	events, err := findMany[model.Event](ctx, s.col(ColEvents), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+	seqs := make([]int, len(events))
+	for i, e := range events {
+		seqs[i] = e.NodeSeq
+	}
+	return seqs, nil
+}
+
+// MaxEventSeqs 返回 Run 已入库事件的最大序号与最大节点序号（没有事件时为 0）
+func (s *Store) MaxEventSeqs(ctx context.Context, runID string) (int, int, error) {
+	cur, err := s.col(ColEvents).Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{{Key: "run_id", Value: runID}}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: nil},
+			{Key: "seq", Value: bson.D{{Key: "$max", Value: "$seq"}}},
+			{Key: "node_seq", Value: bson.D{{Key: "$max", Value: "$node_seq"}}},
+		}}},
+	})
+	if err != nil {
+		return 0, 0, wrapError(err)
+	}
+	defer cur.Close(ctx)
+	var row struct {
+		Seq     int `bson:"seq"`
+		NodeSeq int `bson:"node_seq"`
+	}
+	if cur.Next(ctx) {
+		if err := cur.Decode(&row); err != nil {
+			return 0, 0, err
+		}
+	}
+	return row.Seq, row.NodeSeq, cur.Err()
+}
+
 func (s *Store) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
 	filter := bson.D{{Key: "run_id", Value: runID}}
 	if fromSeq > 0 {
@@ -41,3 +137,54 @@ func (s *Store) GetEventsByRun(ctx context.Context, runID string, fromSeq int, l
 	}
 	return findMany[model.Event](ctx, s.col(ColEvents), filter, opts)
 }
+
+// SearchEvents 按关键词检索事件原始输出
+//
+// MongoDB 中 payload 以二进制存储，仅对 raw 字段做大小写不敏感的正则匹配（多个关键词需同时命中）。
+func (s *Store) SearchEvents(ctx context.Context, sf storagetypes.EventSearchFilter) ([]*model.EventSearchHit, int, error) {
+	terms := model.SearchTerms(sf.Query)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+	conds := bson.A{}
+	for _, term := range terms {
+		conds = append(conds, bson.D{{Key: "raw", Value: bson.D{{Key: "$regex", Value: regexp.QuoteMeta(term)}, {Key: "$options", Value: "i"}}}})
+	}
+	filter := bson.D{{Key: "$and", Value: conds}}
+	if sf.RunID != "" {
+		filter = append(filter, bson.E{Key: "run_id", Value: sf.RunID})
+	}
+
+	total, err := s.col(ColEvents).CountDocuments(ctx, filter)
+	if err != nil {
+		return nil, 0, err
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "timestamp", Value: -1}})
+	if sf.Limit > 0 {
+		opts.SetLimit(int64(sf.Limit))
+	}
+	if sf.Offset > 0 {
+		opts.SetSkip(int64(sf.Offset))
+	}
+	events, err := findMany[model.Event](ctx, s.col(ColEvents), filter, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits := make([]*model.EventSearchHit, 0, len(events))
+	for _, e := range events {
+		raw := ""
+		if e.Raw != nil {
+			raw = *e.Raw
+		}
+		hits = append(hits, &model.EventSearchHit{
+			EventID:   e.ID,
+			RunID:     e.RunID,
+			Seq:       e.Seq,
+			Type:      e.Type,
+			Timestamp: e.Timestamp,
+			Highlight: model.HighlightSnippet(raw, terms),
+		})
+	}
+	return hits, int(total), nil
+}
@@ -113,3 +113,16 @@ func (s *Store) UpdateConfirmationStatus(ctx context.Context, id string, status
 	}
 	return updateFields(ctx, s.col(ColConfirmations), id, update)
 }
+
+// CountPendingApprovals 统计全部待处理的审批请求与确认请求数量
+func (s *Store) CountPendingApprovals(ctx context.Context) (int, error) {
+	approvals, err := s.col(ColApprovalRequests).CountDocuments(ctx, bson.D{{Key: "status", Value: model.ApprovalStatusPending}})
+	if err != nil {
+		return 0, err
+	}
+	confirmations, err := s.col(ColConfirmations).CountDocuments(ctx, bson.D{{Key: "status", Value: model.ConfirmStatusPending}})
+	if err != nil {
+		return 0, err
+	}
+	return int(approvals + confirmations), nil
+}
@@ -0,0 +1,69 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ============================================================================
+// IdempotencyStore
+// ============================================================================
+
+// ClaimIdempotencyKey 原子地认领幂等键（依赖 scope + key 唯一索引，写入冲突时返回已有记录）
+func (s *Store) ClaimIdempotencyKey(ctx context.Context, k *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	col := s.col(ColIdempotencyKeys)
+	if _, err := col.DeleteOne(ctx, bson.D{
+		{Key: "scope", Value: k.Scope},
+		{Key: "key", Value: k.Key},
+		{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: time.Now()}}},
+	}); err != nil {
+		return nil, wrapError(err)
+	}
+	err := insertOne(ctx, col, k)
+	if err == nil {
+		return nil, nil
+	}
+	if !errors.Is(err, storage.ErrDuplicate) {
+		return nil, err
+	}
+	existing, err := findOne[model.IdempotencyKey](ctx, col, idempotencyFilter(k.Scope, k.Key))
+	if err != nil || existing != nil {
+		return existing, err
+	}
+	// 已有记录在两次操作之间被释放，按已认领处理，由客户端重试
+	return &model.IdempotencyKey{Scope: k.Scope, Key: k.Key, RequestHash: k.RequestHash}, nil
+}
+
+func (s *Store) CompleteIdempotencyKey(ctx context.Context, scope, key, resourceID string, statusCode int, response []byte) error {
+	_, err := s.col(ColIdempotencyKeys).UpdateOne(ctx, idempotencyFilter(scope, key), bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "resource_id", Value: resourceID},
+			{Key: "status_code", Value: statusCode},
+			{Key: "response", Value: response},
+		}},
+	})
+	return wrapError(err)
+}
+
+func (s *Store) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	_, err := s.col(ColIdempotencyKeys).DeleteOne(ctx, idempotencyFilter(scope, key))
+	return wrapError(err)
+}
+
+func (s *Store) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.col(ColIdempotencyKeys).DeleteMany(ctx, bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: before}}}})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return res.DeletedCount, nil
+}
+
+func idempotencyFilter(scope, key string) bson.D {
+	return bson.D{{Key: "scope", Value: scope}, {Key: "key", Value: key}}
+}
@@ -51,6 +51,22 @@ func (s *Store) UpdateAgentInstance(ctx context.Context, id string, status model
 	return updateFields(ctx, s.col(ColAgents), id, update)
 }
 
+func (s *Store) TransitionAgentInstance(ctx context.Context, id string, from, to model.InstanceStatus, containerName *string) (bool, error) {
+	set := bson.D{
+		{Key: "status", Value: to},
+		{Key: "updated_at", Value: time.Now()},
+	}
+	if containerName != nil {
+		set = append(set, bson.E{Key: "container_name", Value: *containerName})
+	}
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "status", Value: from}}
+	res, err := s.col(ColAgents).UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
 func (s *Store) DeleteAgentInstance(ctx context.Context, id string) error {
 	return deleteByID(ctx, s.col(ColAgents), id)
 }
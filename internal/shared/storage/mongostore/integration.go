@@ -0,0 +1,63 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// IntegrationStore
+// ============================================================================
+
+func (s *Store) CreateIntegration(ctx context.Context, integration *model.Integration) error {
+	return insertOne(ctx, s.col(ColIntegrations), integration)
+}
+
+func (s *Store) GetIntegration(ctx context.Context, id string) (*model.Integration, error) {
+	return findOne[model.Integration](ctx, s.col(ColIntegrations), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListIntegrations(ctx context.Context) ([]*model.Integration, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	return findMany[model.Integration](ctx, s.col(ColIntegrations), bson.D{}, opts)
+}
+
+func (s *Store) UpdateIntegration(ctx context.Context, integration *model.Integration) error {
+	return updateFields(ctx, s.col(ColIntegrations), integration.ID, bson.D{
+		{Key: "name", Value: integration.Name},
+		{Key: "provider", Value: integration.Provider},
+		{Key: "base_url", Value: integration.BaseURL},
+		{Key: "repo", Value: integration.Repo},
+		{Key: "project_key", Value: integration.ProjectKey},
+		{Key: "credential_ref", Value: integration.CredentialRef},
+		{Key: "comment_results", Value: integration.CommentResults},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
+func (s *Store) DeleteIntegration(ctx context.Context, id string) error {
+	if _, err := s.col(ColIssueLinks).DeleteMany(ctx, bson.D{{Key: "integration_id", Value: id}}); err != nil {
+		return wrapError(err)
+	}
+	return deleteByID(ctx, s.col(ColIntegrations), id)
+}
+
+func (s *Store) CreateIssueLink(ctx context.Context, link *model.IssueLink) error {
+	return insertOne(ctx, s.col(ColIssueLinks), link)
+}
+
+func (s *Store) GetIssueLinkByTask(ctx context.Context, taskID string) (*model.IssueLink, error) {
+	return findOne[model.IssueLink](ctx, s.col(ColIssueLinks), bson.D{{Key: "_id", Value: taskID}})
+}
+
+func (s *Store) GetIssueLinkByIssue(ctx context.Context, integrationID, issueKey string) (*model.IssueLink, error) {
+	return findOne[model.IssueLink](ctx, s.col(ColIssueLinks), bson.D{
+		{Key: "integration_id", Value: integrationID},
+		{Key: "issue_key", Value: issueKey},
+	})
+}
@@ -6,3 +6,4 @@ import (
 
 // Compile-time interface check
 var _ storage.PersistentStore = (*Store)(nil)
+var _ storage.Maintainer = (*Store)(nil)
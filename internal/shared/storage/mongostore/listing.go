@@ -0,0 +1,90 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agents-admin/internal/shared/storagetypes"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// 列表查询辅助：状态集合、时间范围、标签选择器、游标分页
+// ============================================================================
+
+// inFilter 追加 field $in values 条件，values 为空时不追加
+func inFilter(filter bson.D, field string, values []string) bson.D {
+	if len(values) == 0 {
+		return filter
+	}
+	return append(filter, bson.E{Key: field, Value: bson.D{{Key: "$in", Value: values}}})
+}
+
+// createdRangeFilter 追加 created_at 时间范围条件（上下限合并为同一个字段条件）
+func createdRangeFilter(filter bson.D, since, until time.Time) bson.D {
+	rng := bson.D{}
+	if !since.IsZero() {
+		rng = append(rng, bson.E{Key: "$gte", Value: since})
+	}
+	if !until.IsZero() {
+		rng = append(rng, bson.E{Key: "$lte", Value: until})
+	}
+	if len(rng) == 0 {
+		return filter
+	}
+	return append(filter, bson.E{Key: "created_at", Value: rng})
+}
+
+// labelFilter 追加标签选择器条件（labels.<key>）
+func labelFilter(filter bson.D, reqs []storagetypes.LabelRequirement) bson.D {
+	for _, req := range reqs {
+		field := "labels." + req.Key
+		switch req.Op {
+		case storagetypes.LabelEquals:
+			filter = append(filter, bson.E{Key: field, Value: req.Value})
+		case storagetypes.LabelNotEquals:
+			filter = append(filter, bson.E{Key: field, Value: bson.D{{Key: "$ne", Value: req.Value}}})
+		case storagetypes.LabelExists:
+			filter = append(filter, bson.E{Key: field, Value: bson.D{{Key: "$exists", Value: true}}})
+		default:
+			filter = append(filter, bson.E{Key: field, Value: bson.D{{Key: "$exists", Value: false}}})
+		}
+	}
+	return filter
+}
+
+// cursorFilter 追加游标条件（列表按 created_at DESC, _id DESC 排序）
+func cursorFilter(filter bson.D, c *storagetypes.PageCursor) bson.D {
+	if c == nil {
+		return filter
+	}
+	return append(filter, bson.E{Key: "$or", Value: bson.A{
+		bson.D{{Key: "created_at", Value: bson.D{{Key: "$lt", Value: c.CreatedAt}}}},
+		bson.D{{Key: "created_at", Value: c.CreatedAt}, {Key: "_id", Value: bson.D{{Key: "$lt", Value: c.ID}}}},
+	}})
+}
+
+// pageOptions 列表查询的排序与分页选项（使用游标时不跳过）
+func pageOptions(cursor *storagetypes.PageCursor, limit, offset int) *options.FindOptionsBuilder {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if cursor == nil && offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+	return opts
+}
+
+// distinctStrings 查询满足条件的文档中 field 的去重值（无匹配时返回空切片，可直接用于 $in）
+func distinctStrings(ctx context.Context, col *mongo.Collection, field string, filter bson.D) ([]string, error) {
+	values := []string{}
+	if err := col.Distinct(ctx, field, filter).Decode(&values); err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	return values, nil
+}
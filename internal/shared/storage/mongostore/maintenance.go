@@ -0,0 +1,102 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// MaintenanceStore
+// ============================================================================
+
+func (s *Store) CreateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error {
+	return insertOne(ctx, s.col(ColMaintenanceRuns), r)
+}
+
+func (s *Store) UpdateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error {
+	return updateFields(ctx, s.col(ColMaintenanceRuns), r.ID, bson.D{
+		{Key: "status", Value: r.Status},
+		{Key: "tables", Value: r.Tables},
+		{Key: "reclaimable_bytes", Value: r.ReclaimableBytes},
+		{Key: "reclaimed_bytes", Value: r.ReclaimedBytes},
+		{Key: "error", Value: r.Error},
+		{Key: "finished_at", Value: r.FinishedAt},
+	})
+}
+
+func (s *Store) ListMaintenanceRuns(ctx context.Context, limit int) ([]*model.MaintenanceRun, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "started_at", Value: -1}}).SetLimit(int64(limit))
+	return findMany[model.MaintenanceRun](ctx, s.col(ColMaintenanceRuns), bson.D{}, opts)
+}
+
+// ============================================================================
+// storage.Maintainer
+// ============================================================================
+
+// collStorageStats $collStats 的 storageStats 部分
+type collStorageStats struct {
+	StorageSize     float64 `bson:"storageSize"`
+	TotalIndexSize  float64 `bson:"totalIndexSize"`
+	FreeStorageSize float64 `bson:"freeStorageSize"` // WiredTiger 可复用空间（4.4+）
+}
+
+// EstimateMaintenance 以 $collStats 估算各集合占用与可回收空间
+func (s *Store) EstimateMaintenance(ctx context.Context, collections []string) ([]model.MaintenanceTable, error) {
+	result := make([]model.MaintenanceTable, 0, len(collections))
+	for _, name := range collections {
+		result = append(result, s.estimateCollection(ctx, name))
+	}
+	return result, nil
+}
+
+// RunMaintenance 对各集合执行 compact，释放 WiredTiger 空闲空间
+func (s *Store) RunMaintenance(ctx context.Context, collections []string) ([]model.MaintenanceTable, error) {
+	result := make([]model.MaintenanceTable, 0, len(collections))
+	for _, name := range collections {
+		t := s.estimateCollection(ctx, name)
+		if t.Error == "" {
+			start := time.Now()
+			if err := s.db.RunCommand(ctx, bson.D{{Key: "compact", Value: name}}).Err(); err != nil {
+				t.Error = err.Error()
+			}
+			t.DurationMS = time.Since(start).Milliseconds()
+			if after := s.estimateCollection(ctx, name); after.Error == "" {
+				t.SizeAfterBytes = &after.SizeBytes
+			}
+		}
+		result = append(result, t)
+	}
+	return result, nil
+}
+
+func (s *Store) estimateCollection(ctx context.Context, name string) model.MaintenanceTable {
+	t := model.MaintenanceTable{Table: name}
+	pipeline := bson.A{bson.D{{Key: "$collStats", Value: bson.D{{Key: "storageStats", Value: bson.D{}}}}}}
+	cursor, err := s.col(name).Aggregate(ctx, pipeline)
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	defer cursor.Close(ctx)
+
+	var docs []struct {
+		StorageStats collStorageStats `bson:"storageStats"`
+	}
+	if err := cursor.All(ctx, &docs); err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	if len(docs) == 0 {
+		t.Error = "collection not found"
+		return t
+	}
+	stats := docs[0].StorageStats
+	t.SizeBytes = int64(stats.StorageSize + stats.TotalIndexSize)
+	t.ReclaimableBytes = int64(stats.FreeStorageSize)
+	return t
+}
@@ -2,11 +2,13 @@ package mongostore
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"agents-admin/internal/shared/model"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
@@ -90,6 +92,48 @@ func (s *Store) GetNode(ctx context.Context, id string) (*model.Node, error) {
 	return findOne[model.Node](ctx, s.col(ColNodes), bson.D{{Key: "_id", Value: id}})
 }
 
+func (s *Store) GetNodeCapacityOverride(ctx context.Context, nodeID string) (*model.NodeConcurrencyOverride, error) {
+	var doc struct {
+		CapacityOverride *model.NodeConcurrencyOverride `bson:"capacity_override"`
+	}
+	opts := options.FindOne().SetProjection(bson.D{{Key: "capacity_override", Value: 1}})
+	err := s.col(ColNodes).FindOne(ctx, bson.D{{Key: "_id", Value: nodeID}}, opts).Decode(&doc)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	if doc.CapacityOverride.IsEmpty() {
+		return nil, nil
+	}
+	return doc.CapacityOverride, nil
+}
+
+func (s *Store) SetNodeCapacityOverride(ctx context.Context, nodeID string, o *model.NodeConcurrencyOverride) error {
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "capacity_override", Value: ""}}}}
+	if !o.IsEmpty() {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "capacity_override", Value: o}, {Key: "updated_at", Value: time.Now()}}}}
+	}
+	_, err := s.col(ColNodes).UpdateOne(ctx, bson.D{{Key: "_id", Value: nodeID}}, update)
+	return wrapError(err)
+}
+
+func (s *Store) TouchNodeHeartbeat(ctx context.Context, nodeID string, at time.Time) error {
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "last_heartbeat", Value: at}}}}
+	_, err := s.col(ColNodes).UpdateOne(ctx, bson.D{{Key: "_id", Value: nodeID}}, update)
+	return wrapError(err)
+}
+
+func (s *Store) SetNodeTaints(ctx context.Context, nodeID string, taints []model.NodeTaint) error {
+	update := bson.D{{Key: "$unset", Value: bson.D{{Key: "taints", Value: ""}}}}
+	if len(taints) > 0 {
+		update = bson.D{{Key: "$set", Value: bson.D{{Key: "taints", Value: taints}, {Key: "updated_at", Value: time.Now()}}}}
+	}
+	_, err := s.col(ColNodes).UpdateOne(ctx, bson.D{{Key: "_id", Value: nodeID}}, update)
+	return wrapError(err)
+}
+
 func (s *Store) ListAllNodes(ctx context.Context) ([]*model.Node, error) {
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 	return findMany[model.Node](ctx, s.col(ColNodes), bson.D{}, opts)
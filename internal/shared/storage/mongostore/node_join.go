@@ -0,0 +1,83 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// NodeJoinStore
+// ============================================================================
+
+func (s *Store) CreateNodeJoinToken(ctx context.Context, t *model.NodeJoinToken) error {
+	return insertOne(ctx, s.col(ColNodeJoinTokens), t)
+}
+
+func (s *Store) ListNodeJoinTokens(ctx context.Context) ([]*model.NodeJoinToken, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return findMany[model.NodeJoinToken](ctx, s.col(ColNodeJoinTokens), bson.D{}, opts)
+}
+
+func (s *Store) DeleteNodeJoinToken(ctx context.Context, id string) error {
+	_, err := s.col(ColNodeJoinTokens).DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	return wrapError(err)
+}
+
+func (s *Store) ConsumeNodeJoinToken(ctx context.Context, tokenHash, nodeID string, now time.Time) (*model.NodeJoinToken, error) {
+	filter := bson.D{
+		{Key: "token_hash", Value: tokenHash},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: now}}},
+		{Key: "$expr", Value: bson.D{{Key: "$lt", Value: bson.A{"$use_count", "$max_uses"}}}},
+	}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "used_at", Value: now},
+			{Key: "used_by_node", Value: nodeID},
+		}},
+		{Key: "$inc", Value: bson.D{{Key: "use_count", Value: 1}}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	var t model.NodeJoinToken
+	if err := s.col(ColNodeJoinTokens).FindOneAndUpdate(ctx, filter, update, opts).Decode(&t); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, nil
+		}
+		return nil, wrapError(err)
+	}
+	return &t, nil
+}
+
+func (s *Store) CreateNodeJoinTokenUse(ctx context.Context, u *model.NodeJoinTokenUse) error {
+	return insertOne(ctx, s.col(ColNodeJoinTokenUses), u)
+}
+
+func (s *Store) ListNodeJoinTokenUses(ctx context.Context, tokenID string) ([]*model.NodeJoinTokenUse, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "used_at", Value: -1}})
+	return findMany[model.NodeJoinTokenUse](ctx, s.col(ColNodeJoinTokenUses), bson.D{{Key: "token_id", Value: tokenID}}, opts)
+}
+
+func (s *Store) UpsertNodeCredential(ctx context.Context, c *model.NodeCredential) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := s.col(ColNodeCredentials).ReplaceOne(ctx, bson.D{{Key: "_id", Value: c.NodeID}}, c, opts)
+	return wrapError(err)
+}
+
+func (s *Store) GetNodeCredential(ctx context.Context, nodeID string) (*model.NodeCredential, error) {
+	return findOne[model.NodeCredential](ctx, s.col(ColNodeCredentials), bson.D{{Key: "_id", Value: nodeID}})
+}
+
+func (s *Store) GetNodeCredentialByTokenHash(ctx context.Context, tokenHash string) (*model.NodeCredential, error) {
+	return findOne[model.NodeCredential](ctx, s.col(ColNodeCredentials), bson.D{{Key: "token_hash", Value: tokenHash}})
+}
+
+func (s *Store) DeleteNodeCredential(ctx context.Context, nodeID string) error {
+	_, err := s.col(ColNodeCredentials).DeleteOne(ctx, bson.D{{Key: "_id", Value: nodeID}})
+	return wrapError(err)
+}
@@ -48,6 +48,13 @@ func (s *Store) UpdateOperationStatus(ctx context.Context, id string, status mod
 	})
 }
 
+func (s *Store) UpdateOperationConfig(ctx context.Context, id string, config json.RawMessage) error {
+	return updateFields(ctx, s.col(ColOperations), id, bson.D{
+		{Key: "config", Value: config},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
 // ============================================================================
 // ActionStore
 // ============================================================================
@@ -0,0 +1,98 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// OutboxStore
+// ============================================================================
+
+// CreateRunWithOutbox 写入 Run 与发件箱消息
+//
+// 单节点 MongoDB 不支持多文档事务：先写发件箱再写 Run，Run 写入失败时删除已写入的消息。
+// 两次写入之间崩溃时残留的消息指向不存在的 Run，调度器取不到 Run 后直接丢弃。
+func (s *Store) CreateRunWithOutbox(ctx context.Context, run *model.Run, msgs ...*model.OutboxMessage) error {
+	ids := make([]string, 0, len(msgs))
+	for _, m := range msgs {
+		if err := insertOne(ctx, s.col(ColOutboxMessages), m); err != nil {
+			s.deleteOutbox(ctx, ids)
+			return err
+		}
+		ids = append(ids, m.ID)
+	}
+	if err := s.CreateRun(ctx, run); err != nil {
+		s.deleteOutbox(ctx, ids)
+		return err
+	}
+	return nil
+}
+
+func (s *Store) deleteOutbox(ctx context.Context, ids []string) {
+	if len(ids) > 0 {
+		s.col(ColOutboxMessages).DeleteMany(context.WithoutCancel(ctx), bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}})
+	}
+}
+
+func (s *Store) ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	now := time.Now()
+	filter := bson.D{
+		{Key: "published_at", Value: nil},
+		{Key: "available_at", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "available_at", Value: 1}}).SetLimit(int64(limit))
+	candidates, err := findMany[model.OutboxMessage](ctx, s.col(ColOutboxMessages), filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	leaseUntil := now.Add(lease)
+	claimed := make([]*model.OutboxMessage, 0, len(candidates))
+	for _, m := range candidates {
+		res, err := s.col(ColOutboxMessages).UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: m.ID}, {Key: "published_at", Value: nil}, {Key: "attempts", Value: m.Attempts}},
+			bson.D{
+				{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+				{Key: "$set", Value: bson.D{{Key: "available_at", Value: leaseUntil}}},
+			})
+		if err != nil {
+			return claimed, wrapError(err)
+		}
+		if res.ModifiedCount == 0 {
+			continue
+		}
+		m.Attempts++
+		m.AvailableAt = leaseUntil
+		claimed = append(claimed, m)
+	}
+	return claimed, nil
+}
+
+func (s *Store) MarkOutboxPublished(ctx context.Context, id string) error {
+	_, err := s.col(ColOutboxMessages).UpdateOne(ctx, bson.D{{Key: "_id", Value: id}}, bson.D{
+		{Key: "$set", Value: bson.D{{Key: "published_at", Value: time.Now()}}},
+		{Key: "$unset", Value: bson.D{{Key: "last_error", Value: ""}}},
+	})
+	return wrapError(err)
+}
+
+func (s *Store) MarkOutboxFailed(ctx context.Context, id, errMsg string, retryAt time.Time) error {
+	_, err := s.col(ColOutboxMessages).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "published_at", Value: nil}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "last_error", Value: errMsg}, {Key: "available_at", Value: retryAt}}}})
+	return wrapError(err)
+}
+
+func (s *Store) DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.col(ColOutboxMessages).DeleteMany(ctx, bson.D{{Key: "published_at", Value: bson.D{{Key: "$lt", Value: before}}}})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return res.DeletedCount, nil
+}
@@ -0,0 +1,48 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// ProjectStore
+// ============================================================================
+
+func (s *Store) CreateProject(ctx context.Context, project *model.Project) error {
+	return insertOne(ctx, s.col(ColProjects), project)
+}
+
+func (s *Store) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	return findOne[model.Project](ctx, s.col(ColProjects), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	return findMany[model.Project](ctx, s.col(ColProjects), bson.D{}, opts)
+}
+
+func (s *Store) UpdateProject(ctx context.Context, project *model.Project) error {
+	return updateFields(ctx, s.col(ColProjects), project.ID, bson.D{
+		{Key: "name", Value: project.Name},
+		{Key: "description", Value: project.Description},
+		{Key: "default_agent_type", Value: project.DefaultAgentType},
+		{Key: "allowed_agent_types", Value: project.AllowedAgentTypes},
+		{Key: "account_pool", Value: project.AccountPool},
+		{Key: "default_security", Value: project.DefaultSecurity},
+		{Key: "allowed_security_policies", Value: project.AllowedSecurityPolicies},
+		{Key: "overrides", Value: project.Overrides},
+		{Key: "result_publishing", Value: project.ResultPublishing},
+		{Key: "retention", Value: project.Retention},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
+func (s *Store) DeleteProject(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColProjects), id)
+}
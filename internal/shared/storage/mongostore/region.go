@@ -0,0 +1,86 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// RegionStore
+// ============================================================================
+
+func (s *Store) CreateRegion(ctx context.Context, region *model.Region) error {
+	return insertOne(ctx, s.col(ColRegions), region)
+}
+
+func (s *Store) GetRegion(ctx context.Context, id string) (*model.Region, error) {
+	return findOne[model.Region](ctx, s.col(ColRegions), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListRegions(ctx context.Context) ([]*model.Region, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	return findMany[model.Region](ctx, s.col(ColRegions), bson.D{}, opts)
+}
+
+func (s *Store) UpdateRegion(ctx context.Context, region *model.Region) error {
+	return updateFields(ctx, s.col(ColRegions), region.ID, bson.D{
+		{Key: "display_name", Value: region.DisplayName},
+		{Key: "description", Value: region.Description},
+		{Key: "fallback", Value: region.Fallback},
+		{Key: "fallback_regions", Value: region.FallbackRegions},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
+func (s *Store) DeleteRegion(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColRegions), id)
+}
+
+// CountQueuedRunsByRegion 按任务的 region 统计 queued 的 Run 数与最早排队时间
+func (s *Store) CountQueuedRunsByRegion(ctx context.Context) ([]model.RegionQueueDepth, error) {
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "status", Value: model.RunStatusQueued}}}},
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: ColTasks},
+			{Key: "localField", Value: "task_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "task"},
+		}}},
+		bson.D{{Key: "$unwind", Value: "$task"}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$ifNull", Value: bson.A{"$task.region", ""}}}},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "oldest", Value: bson.D{{Key: "$min", Value: "$created_at"}}},
+		}}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+	}
+	cur, err := s.col(ColRuns).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	depths := []model.RegionQueueDepth{}
+	for cur.Next(ctx) {
+		var row struct {
+			Region string    `bson:"_id"`
+			Count  int       `bson:"count"`
+			Oldest time.Time `bson:"oldest"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		d := model.RegionQueueDepth{Region: row.Region, QueuedRuns: row.Count}
+		if !row.Oldest.IsZero() {
+			oldest := row.Oldest
+			d.OldestQueuedAt = &oldest
+		}
+		depths = append(depths, d)
+	}
+	return depths, cur.Err()
+}
@@ -0,0 +1,29 @@
+package mongostore
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// RegistryStore
+// ============================================================================
+
+func (s *Store) UpsertRegistryItem(ctx context.Context, item *model.RegistryItem) error {
+	opts := options.Replace().SetUpsert(true)
+	_, err := s.col(ColRegistryItems).ReplaceOne(ctx, bson.D{{Key: "_id", Value: item.ID}}, item, opts)
+	return wrapError(err)
+}
+
+func (s *Store) GetRegistryItem(ctx context.Context, id string) (*model.RegistryItem, error) {
+	return findOne[model.RegistryItem](ctx, s.col(ColRegistryItems), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListRegistryItems(ctx context.Context) ([]*model.RegistryItem, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	return findMany[model.RegistryItem](ctx, s.col(ColRegistryItems), bson.D{}, opts)
+}
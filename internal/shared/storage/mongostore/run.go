@@ -2,9 +2,12 @@ package mongostore
 
 import (
 	"context"
+	"fmt"
+	"sort"
 	"time"
 
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo/options"
@@ -28,10 +31,43 @@ func (s *Store) ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run
 	return findMany[model.Run](ctx, s.col(ColRuns), filter, opts)
 }
 
+func (s *Store) ListRuns(ctx context.Context, rf storagetypes.RunFilter) ([]*model.Run, int, error) {
+	filter := inFilter(bson.D{}, "status", rf.Statuses)
+	if rf.TaskID != "" {
+		filter = append(filter, bson.E{Key: "task_id", Value: rf.TaskID})
+	}
+	if rf.NodeID != "" {
+		filter = append(filter, bson.E{Key: "node_id", Value: rf.NodeID})
+	}
+	if len(rf.Labels) > 0 {
+		taskIDs, err := distinctStrings(ctx, s.col(ColTasks), "_id", labelFilter(bson.D{}, rf.Labels))
+		if err != nil {
+			return nil, 0, err
+		}
+		filter = append(filter, bson.E{Key: "task_id", Value: bson.D{{Key: "$in", Value: taskIDs}}})
+	}
+	filter = createdRangeFilter(filter, rf.Since, rf.Until)
+
+	total := int64(-1)
+	if !rf.SkipTotal {
+		var err error
+		if total, err = s.col(ColRuns).CountDocuments(ctx, filter); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	filter = cursorFilter(filter, rf.Cursor)
+	runs, err := findMany[model.Run](ctx, s.col(ColRuns), filter, pageOptions(rf.Cursor, rf.Limit, rf.Offset))
+	if err != nil {
+		return nil, 0, err
+	}
+	return runs, int(total), nil
+}
+
 func (s *Store) ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error) {
 	filter := bson.D{
 		{Key: "node_id", Value: nodeID},
-		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"queued", "assigned", "running"}}}},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"queued", "assigned", "running", "paused"}}}},
 	}
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 	return findMany[model.Run](ctx, s.col(ColRuns), filter, opts)
@@ -52,7 +88,9 @@ func (s *Store) ListQueuedRuns(ctx context.Context, limit int) ([]*model.Run, er
 	if limit > 0 {
 		opts.SetLimit(int64(limit))
 	}
-	return findMany[model.Run](ctx, s.col(ColRuns), filter, opts)
+	runs, err := findMany[model.Run](ctx, s.col(ColRuns), filter, opts)
+	sortRunsByPriority(runs)
+	return runs, err
 }
 
 func (s *Store) ListStaleQueuedRuns(ctx context.Context, threshold time.Duration) ([]*model.Run, error) {
@@ -61,7 +99,17 @@ func (s *Store) ListStaleQueuedRuns(ctx context.Context, threshold time.Duration
 		{Key: "status", Value: "queued"},
 		{Key: "created_at", Value: bson.D{{Key: "$lt", Value: cutoff}}},
 	}
-	return findMany[model.Run](ctx, s.col(ColRuns), filter)
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	runs, err := findMany[model.Run](ctx, s.col(ColRuns), filter, opts)
+	sortRunsByPriority(runs)
+	return runs, err
+}
+
+// sortRunsByPriority 按调度优先级稳定排序（priority 为字符串，无法直接在查询中按 high → normal → low 排序）
+func sortRunsByPriority(runs []*model.Run) {
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].Priority.OrDefault().Rank() < runs[j].Priority.OrDefault().Rank()
+	})
 }
 
 func (s *Store) ResetRunToQueued(ctx context.Context, id string) error {
@@ -98,6 +146,87 @@ func (s *Store) UpdateRunError(ctx context.Context, id string, errMsg string) er
 	})
 }
 
+// MarkRunTimeout 将仍处于 assigned/running 的 Run 标记为 timeout 并记录错误信息
+func (s *Store) MarkRunTimeout(ctx context.Context, id string, errMsg string) (bool, error) {
+	now := time.Now()
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"assigned", "running"}}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{
+		{Key: "status", Value: model.RunStatusTimeout},
+		{Key: "error", Value: errMsg},
+		{Key: "finished_at", Value: now},
+		{Key: "updated_at", Value: now},
+	}}}
+	res, err := s.col(ColRuns).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+// ReclaimRun 回收孤儿 Run：仍分配在 nodeID 且处于 assigned/running 时重置为 queued 或标记为 failed
+func (s *Store) ReclaimRun(ctx context.Context, id, nodeID string, status model.RunStatus, errMsg string) (bool, error) {
+	now := time.Now()
+	filter := bson.D{
+		{Key: "_id", Value: id},
+		{Key: "node_id", Value: nodeID},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"assigned", "running"}}}},
+	}
+	var set bson.D
+	switch status {
+	case model.RunStatusQueued:
+		set = bson.D{
+			{Key: "status", Value: model.RunStatusQueued},
+			{Key: "node_id", Value: nil},
+			{Key: "started_at", Value: nil},
+			{Key: "error", Value: nil},
+			{Key: "updated_at", Value: now},
+		}
+	case model.RunStatusFailed:
+		set = bson.D{
+			{Key: "status", Value: model.RunStatusFailed},
+			{Key: "error", Value: errMsg},
+			{Key: "finished_at", Value: now},
+			{Key: "updated_at", Value: now},
+		}
+	default:
+		return false, fmt.Errorf("unsupported reclaim status: %s", status)
+	}
+	res, err := s.col(ColRuns).UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return false, err
+	}
+	return res.ModifiedCount > 0, nil
+}
+
 func (s *Store) DeleteRun(ctx context.Context, id string) error {
 	return deleteByID(ctx, s.col(ColRuns), id)
 }
+
+// CountRunsByStatus 按状态统计 created_at >= since 的 Run 数量（since 为零值时统计全部）
+func (s *Store) CountRunsByStatus(ctx context.Context, since time.Time) (map[model.RunStatus]int, error) {
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: bson.D{{Key: "created_at", Value: bson.D{{Key: "$gte", Value: since}}}}}},
+		bson.D{{Key: "$group", Value: bson.D{{Key: "_id", Value: "$status"}, {Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}}}}},
+	}
+	cur, err := s.col(ColRuns).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	defer cur.Close(ctx)
+
+	counts := make(map[model.RunStatus]int)
+	for cur.Next(ctx) {
+		var row struct {
+			Status model.RunStatus `bson:"_id"`
+			Count  int             `bson:"count"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		counts[row.Status] = row.Count
+	}
+	return counts, cur.Err()
+}
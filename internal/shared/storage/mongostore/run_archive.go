@@ -0,0 +1,169 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// RunArchiveStore
+// ============================================================================
+
+func (s *Store) CreateRunArchive(ctx context.Context, a *model.RunArchive) error {
+	return insertOne(ctx, s.col(ColRunArchives), a)
+}
+
+func (s *Store) GetRunArchive(ctx context.Context, runID string) (*model.RunArchive, error) {
+	return findOne[model.RunArchive](ctx, s.col(ColRunArchives), bson.D{{Key: "_id", Value: runID}})
+}
+
+func (s *Store) UpdateRunArchive(ctx context.Context, a *model.RunArchive) error {
+	return updateFields(ctx, s.col(ColRunArchives), a.RunID, bson.D{
+		{Key: "tier", Value: a.Tier},
+		{Key: "archive_bytes", Value: a.ArchiveBytes},
+		{Key: "summary", Value: a.Summary},
+		{Key: "cold_at", Value: a.ColdAt},
+		{Key: "purged_at", Value: a.PurgedAt},
+	})
+}
+
+func (s *Store) ListRunsForArchive(ctx context.Context, filter storagetypes.ArchiveRunFilter) ([]*model.Run, error) {
+	match := bson.D{
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"done", "failed", "cancelled", "timeout"}}}},
+		{Key: "finished_at", Value: bson.D{{Key: "$lt", Value: filter.Before}}},
+	}
+	// 按项目过滤时先查出项目下的任务
+	if filter.ProjectID != "" {
+		taskIDs, err := s.projectTaskIDs(ctx, bson.D{{Key: "project_id", Value: filter.ProjectID}})
+		if err != nil {
+			return nil, err
+		}
+		match = append(match, bson.E{Key: "task_id", Value: bson.D{{Key: "$in", Value: taskIDs}}})
+	}
+	if len(filter.ExcludeProjectIDs) > 0 {
+		taskIDs, err := s.projectTaskIDs(ctx, bson.D{{Key: "project_id", Value: bson.D{{Key: "$in", Value: filter.ExcludeProjectIDs}}}})
+		if err != nil {
+			return nil, err
+		}
+		match = append(match, bson.E{Key: "task_id", Value: bson.D{{Key: "$nin", Value: taskIDs}}})
+	}
+	pipeline := bson.A{
+		bson.D{{Key: "$match", Value: match}},
+		bson.D{{Key: "$sort", Value: bson.D{{Key: "finished_at", Value: 1}}}},
+		bson.D{{Key: "$lookup", Value: bson.D{
+			{Key: "from", Value: ColRunArchives},
+			{Key: "localField", Value: "_id"},
+			{Key: "foreignField", Value: "_id"},
+			{Key: "as", Value: "archive"},
+		}}},
+		bson.D{{Key: "$match", Value: bson.D{{Key: "archive", Value: bson.D{{Key: "$size", Value: 0}}}}}},
+		bson.D{{Key: "$limit", Value: filter.Limit}},
+		bson.D{{Key: "$project", Value: bson.D{{Key: "archive", Value: 0}}}},
+	}
+	cur, err := s.col(ColRuns).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var runs []*model.Run
+	if err := cur.All(ctx, &runs); err != nil {
+		return nil, wrapError(err)
+	}
+	return runs, nil
+}
+
+// projectTaskIDs 列出匹配 filter 的任务 ID
+func (s *Store) projectTaskIDs(ctx context.Context, filter bson.D) (bson.A, error) {
+	cur, err := s.col(ColTasks).Find(ctx, filter, options.Find().SetProjection(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var docs []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cur.All(ctx, &docs); err != nil {
+		return nil, wrapError(err)
+	}
+	ids := bson.A{}
+	for _, d := range docs {
+		ids = append(ids, d.ID)
+	}
+	return ids, nil
+}
+
+func (s *Store) ListRunArchives(ctx context.Context, tier model.RunDataTier, before time.Time, limit int) ([]*model.RunArchive, error) {
+	filter := bson.D{
+		{Key: "tier", Value: tier},
+		{Key: "warm_at", Value: bson.D{{Key: "$lt", Value: before}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "warm_at", Value: 1}}).SetLimit(int64(limit))
+	return findMany[model.RunArchive](ctx, s.col(ColRunArchives), filter, opts)
+}
+
+func (s *Store) ListExpiredRunArchives(ctx context.Context, projectID string, before time.Time, limit int) ([]*model.RunArchive, error) {
+	filter := bson.D{
+		{Key: "project_id", Value: projectID},
+		{Key: "tier", Value: bson.D{{Key: "$in", Value: bson.A{model.RunDataTierWarm, model.RunDataTierCold}}}},
+		{Key: "warm_at", Value: bson.D{{Key: "$lt", Value: before}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "warm_at", Value: 1}}).SetLimit(int64(limit))
+	return findMany[model.RunArchive](ctx, s.col(ColRunArchives), filter, opts)
+}
+
+func (s *Store) DeleteEventsByRun(ctx context.Context, runID string) (int64, error) {
+	if _, err := s.col(ColEventOriginals).DeleteMany(ctx, bson.D{{Key: "run_id", Value: runID}}); err != nil {
+		return 0, wrapError(err)
+	}
+	res, err := s.col(ColEvents).DeleteMany(ctx, bson.D{{Key: "run_id", Value: runID}})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return res.DeletedCount, nil
+}
+
+func (s *Store) ClearRunSnapshot(ctx context.Context, runID string) error {
+	_, err := s.col(ColRuns).UpdateOne(ctx, bson.D{{Key: "_id", Value: runID}},
+		bson.D{{Key: "$unset", Value: bson.D{{Key: "snapshot", Value: ""}}}})
+	return wrapError(err)
+}
+
+func (s *Store) CountEvents(ctx context.Context) (int64, error) {
+	n, err := s.col(ColEvents).EstimatedDocumentCount(ctx)
+	return n, wrapError(err)
+}
+
+func (s *Store) RunArchiveStats(ctx context.Context) ([]model.RunTierStats, error) {
+	pipeline := bson.A{
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$tier"},
+			{Key: "runs", Value: bson.D{{Key: "$sum", Value: 1}}},
+			{Key: "events", Value: bson.D{{Key: "$sum", Value: "$event_count"}}},
+			{Key: "archive_bytes", Value: bson.D{{Key: "$sum", Value: "$archive_bytes"}}},
+		}}},
+	}
+	cur, err := s.col(ColRunArchives).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	defer cur.Close(ctx)
+
+	var result []model.RunTierStats
+	for cur.Next(ctx) {
+		var row struct {
+			Tier         model.RunDataTier `bson:"_id"`
+			Runs         int64             `bson:"runs"`
+			Events       int64             `bson:"events"`
+			ArchiveBytes int64             `bson:"archive_bytes"`
+		}
+		if err := cur.Decode(&row); err != nil {
+			return nil, err
+		}
+		result = append(result, model.RunTierStats{Tier: row.Tier, Runs: row.Runs, Events: row.Events, ArchiveBytes: row.ArchiveBytes})
+	}
+	return result, cur.Err()
+}
@@ -0,0 +1,54 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// RunFlagStore
+// ============================================================================
+
+func (s *Store) CreateRunFlags(ctx context.Context, flags []*model.RunFlag) error {
+	for _, f := range flags {
+		// 与产物相同，使用纳秒时间戳作为单调递增 ID
+		if f.ID == 0 {
+			f.ID = time.Now().UnixNano()
+		}
+		if err := insertOne(ctx, s.col(ColRunFlags), f); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Store) ListRunFlags(ctx context.Context, runID string) ([]*model.RunFlag, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: 1}})
+	return findMany[model.RunFlag](ctx, s.col(ColRunFlags), bson.D{{Key: "run_id", Value: runID}}, opts)
+}
+
+// CreateEventOriginals 批量写入被掩码事件的原文，已存在的 (run_id, seq) 被忽略（upsert，只在插入时写入）
+func (s *Store) CreateEventOriginals(ctx context.Context, originals []*model.EventOriginal) error {
+	if len(originals) == 0 {
+		return nil
+	}
+	models := make([]mongo.WriteModel, len(originals))
+	for i, o := range originals {
+		models[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.D{{Key: "run_id", Value: o.RunID}, {Key: "seq", Value: o.Seq}}).
+			SetUpdate(bson.D{{Key: "$setOnInsert", Value: o}}).
+			SetUpsert(true)
+	}
+	_, err := s.col(ColEventOriginals).BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	return wrapError(err)
+}
+
+func (s *Store) GetEventOriginal(ctx context.Context, runID string, seq int) (*model.EventOriginal, error) {
+	return findOne[model.EventOriginal](ctx, s.col(ColEventOriginals), bson.D{{Key: "run_id", Value: runID}, {Key: "seq", Value: seq}})
+}
@@ -0,0 +1,62 @@
+package mongostore
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// SchedulingDecisionStore
+// ============================================================================
+
+func (s *Store) RecordSchedulingDecision(ctx context.Context, d *model.SchedulingDecision) error {
+	if d.Signature == "" {
+		d.Signature = d.ComputeSignature()
+	}
+
+	var last model.SchedulingDecision
+	opts := options.FindOne().SetSort(bson.D{{Key: "_id", Value: -1}})
+	err := s.col(ColSchedDecisions).FindOne(ctx, bson.D{{Key: "run_id", Value: d.RunID}}, opts).Decode(&last)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return wrapError(err)
+	}
+	if err == nil && last.Signature == d.Signature {
+		_, err := s.col(ColSchedDecisions).UpdateOne(ctx, bson.D{{Key: "_id", Value: last.ID}}, bson.D{
+			{Key: "$inc", Value: bson.D{{Key: "attempts", Value: 1}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: d.UpdatedAt}}},
+		})
+		if err != nil {
+			return wrapError(err)
+		}
+		d.ID = last.ID
+		d.Attempts = last.Attempts + 1
+		return nil
+	}
+
+	// 与内容审核标记相同，使用纳秒时间戳作为单调递增 ID
+	if d.ID == 0 {
+		d.ID = time.Now().UnixNano()
+	}
+	if d.Attempts == 0 {
+		d.Attempts = 1
+	}
+	if d.Candidates == nil {
+		d.Candidates = []model.SchedulingCandidate{}
+	}
+	return insertOne(ctx, s.col(ColSchedDecisions), d)
+}
+
+func (s *Store) ListSchedulingDecisions(ctx context.Context, runID string, limit int) ([]*model.SchedulingDecision, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	return findMany[model.SchedulingDecision](ctx, s.col(ColSchedDecisions), bson.D{{Key: "run_id", Value: runID}}, opts)
+}
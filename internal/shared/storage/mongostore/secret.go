@@ -0,0 +1,45 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// SecretStore
+// ============================================================================
+
+func (s *Store) CreateSecret(ctx context.Context, secret *model.Secret) error {
+	return insertOne(ctx, s.col(ColSecrets), secret)
+}
+
+func (s *Store) GetSecret(ctx context.Context, id string) (*model.Secret, error) {
+	return findOne[model.Secret](ctx, s.col(ColSecrets), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) GetSecretByName(ctx context.Context, name string) (*model.Secret, error) {
+	return findOne[model.Secret](ctx, s.col(ColSecrets), bson.D{{Key: "name", Value: name}})
+}
+
+func (s *Store) ListSecrets(ctx context.Context) ([]*model.Secret, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+	return findMany[model.Secret](ctx, s.col(ColSecrets), bson.D{}, opts)
+}
+
+func (s *Store) UpdateSecret(ctx context.Context, secret *model.Secret) error {
+	return updateFields(ctx, s.col(ColSecrets), secret.ID, bson.D{
+		{Key: "name", Value: secret.Name},
+		{Key: "description", Value: secret.Description},
+		{Key: "encrypted_value", Value: secret.EncryptedValue},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
+func (s *Store) DeleteSecret(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColSecrets), id)
+}
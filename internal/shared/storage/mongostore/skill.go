@@ -31,5 +31,24 @@ func (s *Store) ListSkills(ctx context.Context, category string) ([]*model.Skill
 }
 
 func (s *Store) DeleteSkill(ctx context.Context, id string) error {
+	if _, err := s.col(ColSkillBundles).DeleteMany(ctx, bson.D{{Key: "skill_id", Value: id}}); err != nil {
+		return wrapError(err)
+	}
 	return deleteByID(ctx, s.col(ColSkills), id)
 }
+
+func (s *Store) CreateSkillBundle(ctx context.Context, bundle *model.SkillBundle) error {
+	return insertOne(ctx, s.col(ColSkillBundles), bundle)
+}
+
+func (s *Store) GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error) {
+	return findOne[model.SkillBundle](ctx, s.col(ColSkillBundles), bson.D{
+		{Key: "skill_id", Value: skillID},
+		{Key: "version", Value: version},
+	})
+}
+
+func (s *Store) ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return findMany[model.SkillBundle](ctx, s.col(ColSkillBundles), bson.D{{Key: "skill_id", Value: skillID}}, opts)
+}
@@ -21,8 +21,20 @@ const (
 	ColTaskTemplates     = "task_templates"
 	ColRuns              = "runs"
 	ColEvents            = "events"
+	ColRunArchives       = "run_archives"
 	ColNodes             = "nodes"
 	ColNodeProvisions    = "node_provisions"
+	ColNodeJoinTokens    = "node_join_tokens"
+	ColNodeCredentials   = "node_credentials"
+	ColNodeJoinTokenUses = "node_join_token_uses"
+	ColMaintenanceRuns   = "maintenance_runs"
+	ColWebhooks          = "webhooks"
+	ColRegistryItems     = "registry_items"
+	ColRunUsage          = "run_usage"
+	ColBudgets           = "budgets"
+	ColAuditLogs         = "audit_logs"
+	ColOutboxMessages    = "outbox_messages"
+	ColIdempotencyKeys   = "idempotency_keys"
 	ColAccounts          = "accounts"
 	ColAuthTasks         = "auth_tasks"
 	ColOperations        = "operations"
@@ -37,12 +49,26 @@ const (
 	ColConfirmations     = "confirmations"
 	ColAgentTemplates    = "agent_templates"
 	ColSkills            = "skills"
+	ColSkillBundles      = "skill_bundles"
 	ColMCPServers        = "mcp_servers"
 	ColSecurityPolicies  = "security_policies"
 	ColUsers             = "users"
+	ColUserMFA           = "user_mfa"
+	ColUserSessions      = "user_sessions"
 	ColPromptTemplates   = "prompt_templates"
 	ColArtifacts         = "artifacts"
 	ColMemories          = "memories"
+	ColCredentials       = "credentials"
+	ColSecrets           = "secrets"
+	ColProjects          = "projects"
+	ColWorkflows         = "workflows"
+	ColIntegrations      = "integrations"
+	ColIssueLinks        = "issue_links"
+	ColRunFlags          = "run_flags"
+	ColEventOriginals    = "event_originals"
+	ColSchedDecisions    = "scheduling_decisions"
+	ColAgentTypes        = "agent_types"
+	ColRegions           = "regions"
 )
 
 // Store 实现 storage.PersistentStore 接口的 MongoDB 驱动
@@ -87,6 +113,23 @@ func (s *Store) Close() error {
 	return s.client.Disconnect(ctx)
 }
 
+// Ping 检查 MongoDB 连接
+func (s *Store) Ping(ctx context.Context) error {
+	return s.client.Ping(ctx, nil)
+}
+
+// DatabaseSize 返回数据库占用的存储空间（字节，含索引）
+func (s *Store) DatabaseSize(ctx context.Context) (int64, error) {
+	var stats struct {
+		StorageSize float64 `bson:"storageSize"`
+		IndexSize   float64 `bson:"indexSize"`
+	}
+	if err := s.db.RunCommand(ctx, bson.D{{Key: "dbStats", Value: 1}}).Decode(&stats); err != nil {
+		return 0, err
+	}
+	return int64(stats.StorageSize + stats.IndexSize), nil
+}
+
 // col 获取指定 Collection
 func (s *Store) col(name string) *mongo.Collection {
 	return s.db.Collection(name)
@@ -105,19 +148,58 @@ func (s *Store) ensureIndexes(ctx context.Context) error {
 		{ColTasks, bson.D{{Key: "status", Value: 1}}, false},
 		{ColTasks, bson.D{{Key: "parent_id", Value: 1}}, false},
 		{ColTasks, bson.D{{Key: "template_id", Value: 1}}, false},
+		{ColTasks, bson.D{{Key: "region", Value: 1}}, false},
 		{ColTasks, bson.D{{Key: "created_at", Value: -1}}, false},
+		{ColTasks, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, false},
 
 		// runs
 		{ColRuns, bson.D{{Key: "task_id", Value: 1}}, false},
 		{ColRuns, bson.D{{Key: "node_id", Value: 1}}, false},
 		{ColRuns, bson.D{{Key: "status", Value: 1}}, false},
 		{ColRuns, bson.D{{Key: "created_at", Value: -1}}, false},
+		{ColRuns, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, false},
+		{ColRuns, bson.D{{Key: "finished_at", Value: 1}}, false},
 
 		// events
 		{ColEvents, bson.D{{Key: "run_id", Value: 1}, {Key: "seq", Value: 1}}, false},
+		{ColEvents, bson.D{{Key: "run_id", Value: 1}, {Key: "node_seq", Value: 1}}, false},
+
+		// run_archives
+		{ColRunArchives, bson.D{{Key: "tier", Value: 1}, {Key: "warm_at", Value: 1}}, false},
+		{ColRunArchives, bson.D{{Key: "project_id", Value: 1}, {Key: "warm_at", Value: 1}}, false},
+
+		// artifacts
+		{ColArtifacts, bson.D{{Key: "run_id", Value: 1}}, false},
+		{ColRunFlags, bson.D{{Key: "run_id", Value: 1}}, false},
+		{ColEventOriginals, bson.D{{Key: "run_id", Value: 1}, {Key: "seq", Value: 1}}, true},
+		{ColSchedDecisions, bson.D{{Key: "run_id", Value: 1}, {Key: "_id", Value: -1}}, false},
+
+		// skill_bundles
+		{ColSkillBundles, bson.D{{Key: "skill_id", Value: 1}, {Key: "version", Value: 1}}, true},
 
 		// nodes
 		{ColNodes, bson.D{{Key: "status", Value: 1}}, false},
+		{ColNodeJoinTokens, bson.D{{Key: "token_hash", Value: 1}}, true},
+		{ColNodeCredentials, bson.D{{Key: "token_hash", Value: 1}}, true},
+		{ColNodeJoinTokenUses, bson.D{{Key: "token_id", Value: 1}, {Key: "used_at", Value: -1}}, false},
+
+		// maintenance_runs
+		{ColMaintenanceRuns, bson.D{{Key: "started_at", Value: -1}}, false},
+
+		// run_usage
+		{ColRunUsage, bson.D{{Key: "day", Value: 1}}, false},
+
+		// audit_logs
+		{ColAuditLogs, bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}, false},
+		{ColAuditLogs, bson.D{{Key: "actor_id", Value: 1}, {Key: "created_at", Value: -1}}, false},
+		{ColAuditLogs, bson.D{{Key: "resource_type", Value: 1}, {Key: "resource_id", Value: 1}}, false},
+
+		// outbox_messages
+		{ColOutboxMessages, bson.D{{Key: "published_at", Value: 1}, {Key: "available_at", Value: 1}}, false},
+
+		// idempotency_keys
+		{ColIdempotencyKeys, bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}}, true},
+		{ColIdempotencyKeys, bson.D{{Key: "expires_at", Value: 1}}, false},
 
 		// accounts
 		{ColAccounts, bson.D{{Key: "node_id", Value: 1}}, false},
@@ -138,6 +220,22 @@ func (s *Store) ensureIndexes(ctx context.Context) error {
 		// proxies
 		{ColProxies, bson.D{{Key: "is_default", Value: 1}}, false},
 
+		// credentials
+		{ColCredentials, bson.D{{Key: "name", Value: 1}}, true},
+
+		// secrets
+		{ColSecrets, bson.D{{Key: "name", Value: 1}}, true},
+
+		// projects
+		{ColProjects, bson.D{{Key: "name", Value: 1}}, true},
+
+		// workflows
+		{ColWorkflows, bson.D{{Key: "status", Value: 1}}, false},
+
+		// integrations / issue_links
+		{ColIntegrations, bson.D{{Key: "name", Value: 1}}, true},
+		{ColIssueLinks, bson.D{{Key: "integration_id", Value: 1}, {Key: "issue_key", Value: 1}}, true},
+
 		// agents
 		{ColAgents, bson.D{{Key: "node_id", Value: 1}}, false},
 
@@ -159,6 +257,7 @@ func (s *Store) ensureIndexes(ctx context.Context) error {
 
 		// users
 		{ColUsers, bson.D{{Key: "email", Value: 1}}, true},
+		{ColUserSessions, bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}, false},
 	}
 
 	for _, i := range indexes {
@@ -39,35 +39,34 @@ func (s *Store) ListTasks(ctx context.Context, status string, limit, offset int)
 }
 
 func (s *Store) ListTasksWithFilter(ctx context.Context, tf storagetypes.TaskFilter) ([]*model.Task, int, error) {
-	filter := bson.D{}
-	if tf.Status != "" {
-		filter = append(filter, bson.E{Key: "status", Value: tf.Status})
+	filter := inFilter(bson.D{}, "status", tf.StatusSet())
+	if tf.ProjectID != "" {
+		filter = append(filter, bson.E{Key: "project_id", Value: tf.ProjectID})
 	}
 	if tf.Search != "" {
 		filter = append(filter, bson.E{Key: "name", Value: bson.D{{Key: "$regex", Value: tf.Search}, {Key: "$options", Value: "i"}}})
 	}
-	if !tf.Since.IsZero() {
-		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$gte", Value: tf.Since}}})
-	}
-	if !tf.Until.IsZero() {
-		filter = append(filter, bson.E{Key: "created_at", Value: bson.D{{Key: "$lte", Value: tf.Until}}})
+	if tf.NodeID != "" {
+		taskIDs, err := distinctStrings(ctx, s.col(ColRuns), "task_id", bson.D{{Key: "node_id", Value: tf.NodeID}})
+		if err != nil {
+			return nil, 0, err
+		}
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$in", Value: taskIDs}}})
 	}
+	filter = labelFilter(filter, tf.Labels)
+	filter = createdRangeFilter(filter, tf.Since, tf.Until)
 
 	// Count total
-	total, err := s.col(ColTasks).CountDocuments(ctx, filter)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-	if tf.Limit > 0 {
-		opts.SetLimit(int64(tf.Limit))
-	}
-	if tf.Offset > 0 {
-		opts.SetSkip(int64(tf.Offset))
+	total := int64(-1)
+	if !tf.SkipTotal {
+		var err error
+		if total, err = s.col(ColTasks).CountDocuments(ctx, filter); err != nil {
+			return nil, 0, err
+		}
 	}
 
-	tasks, err := findMany[model.Task](ctx, s.col(ColTasks), filter, opts)
+	filter = cursorFilter(filter, tf.Cursor)
+	tasks, err := findMany[model.Task](ctx, s.col(ColTasks), filter, pageOptions(tf.Cursor, tf.Limit, tf.Offset))
 	if err != nil {
 		return nil, 0, err
 	}
@@ -31,6 +31,20 @@ func (s *Store) ListTaskTemplates(ctx context.Context, category string) ([]*mode
 	return findMany[model.TaskTemplate](ctx, s.col(ColTaskTemplates), filter, opts)
 }
 
+func (s *Store) UpdateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error {
+	tmpl.UpdatedAt = time.Now()
+	filter := bson.D{{Key: "_id", Value: tmpl.ID}}
+	update := bson.D{{Key: "$set", Value: tmpl}}
+	res, err := s.col(ColTaskTemplates).UpdateOne(ctx, filter, update)
+	if err != nil {
+		return wrapError(err)
+	}
+	if res.MatchedCount == 0 {
+		return wrapError(nil)
+	}
+	return nil
+}
+
 func (s *Store) DeleteTaskTemplate(ctx context.Context, id string) error {
 	return deleteByID(ctx, s.col(ColTaskTemplates), id)
 }
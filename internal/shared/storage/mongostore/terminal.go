@@ -51,6 +51,22 @@ func (s *Store) UpdateTerminalSession(ctx context.Context, id string, status mod
 	return updateFields(ctx, s.col(ColTerminalSessions), id, update)
 }
 
+func (s *Store) TransitionTerminalSession(ctx context.Context, id string, from, to model.TerminalSessionStatus, port *int, url *string) (bool, error) {
+	set := bson.D{{Key: "status", Value: to}}
+	if port != nil {
+		set = append(set, bson.E{Key: "port", Value: *port})
+	}
+	if url != nil {
+		set = append(set, bson.E{Key: "url", Value: *url})
+	}
+	filter := bson.D{{Key: "_id", Value: id}, {Key: "status", Value: from}}
+	res, err := s.col(ColTerminalSessions).UpdateOne(ctx, filter, bson.D{{Key: "$set", Value: set}})
+	if err != nil {
+		return false, err
+	}
+	return res.MatchedCount > 0, nil
+}
+
 func (s *Store) DeleteTerminalSession(ctx context.Context, id string) error {
 	return deleteByID(ctx, s.col(ColTerminalSessions), id)
 }
@@ -63,3 +79,16 @@ func (s *Store) CleanupExpiredTerminalSessions(ctx context.Context) (int64, erro
 	}
 	return res.DeletedCount, nil
 }
+
+func (s *Store) CloseExpiredTerminalSessions(ctx context.Context) (int64, error) {
+	filter := bson.D{
+		{Key: "expires_at", Value: bson.D{{Key: "$lt", Value: time.Now()}}},
+		{Key: "status", Value: bson.D{{Key: "$in", Value: bson.A{"pending", "starting", "running"}}}},
+	}
+	update := bson.D{{Key: "$set", Value: bson.D{{Key: "status", Value: model.TerminalStatusClosed}}}}
+	res, err := s.col(ColTerminalSessions).UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, err
+	}
+	return res.ModifiedCount, nil
+}
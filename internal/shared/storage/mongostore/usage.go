@@ -0,0 +1,61 @@
+package mongostore
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// UsageStore
+// ============================================================================
+
+func (s *Store) AddRunUsage(ctx context.Context, usage *model.RunUsage) error {
+	set := bson.D{{Key: "updated_at", Value: usage.UpdatedAt}}
+	if usage.Model != "" {
+		set = append(set, bson.E{Key: "model", Value: usage.Model})
+	}
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{
+			{Key: "input_tokens", Value: usage.InputTokens},
+			{Key: "output_tokens", Value: usage.OutputTokens},
+			{Key: "cache_read_tokens", Value: usage.CacheReadTokens},
+			{Key: "cache_write_tokens", Value: usage.CacheWriteTokens},
+			{Key: "cost_usd", Value: usage.CostUSD},
+		}},
+		{Key: "$set", Value: set},
+		{Key: "$setOnInsert", Value: bson.D{
+			{Key: "task_id", Value: usage.TaskID},
+			{Key: "project_id", Value: usage.ProjectID},
+			{Key: "account_id", Value: usage.AccountID},
+			{Key: "agent_type", Value: usage.AgentType},
+			{Key: "day", Value: usage.Day},
+			{Key: "created_at", Value: usage.CreatedAt},
+		}},
+	}
+	_, err := s.col(ColRunUsage).UpdateOne(ctx, bson.D{{Key: "_id", Value: usage.RunID}}, update, options.UpdateOne().SetUpsert(true))
+	return wrapError(err)
+}
+
+func (s *Store) GetRunUsage(ctx context.Context, runID string) (*model.RunUsage, error) {
+	return findOne[model.RunUsage](ctx, s.col(ColRunUsage), bson.D{{Key: "_id", Value: runID}})
+}
+
+func (s *Store) ListRunUsage(ctx context.Context, since, until string) ([]*model.RunUsage, error) {
+	day := bson.D{}
+	if since != "" {
+		day = append(day, bson.E{Key: "$gte", Value: since})
+	}
+	if until != "" {
+		day = append(day, bson.E{Key: "$lte", Value: until})
+	}
+	filter := bson.D{}
+	if len(day) > 0 {
+		filter = append(filter, bson.E{Key: "day", Value: day})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "day", Value: 1}, {Key: "_id", Value: 1}})
+	return findMany[model.RunUsage](ctx, s.col(ColRunUsage), filter, opts)
+}
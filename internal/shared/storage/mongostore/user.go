@@ -33,6 +33,13 @@ func (s *Store) UpdateUserPassword(ctx context.Context, id, passwordHash string)
 	})
 }
 
+func (s *Store) UpdateUserRole(ctx context.Context, id string, role model.UserRole) error {
+	return updateFields(ctx, s.col(ColUsers), id, bson.D{
+		{Key: "role", Value: role},
+		{Key: "updated_at", Value: time.Now()},
+	})
+}
+
 func (s *Store) ListUsers(ctx context.Context) ([]*model.User, error) {
 	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
 	return findMany[model.User](ctx, s.col(ColUsers), bson.D{}, opts)
@@ -0,0 +1,103 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// UserSecurityStore
+// ============================================================================
+
+func (s *Store) GetUserMFA(ctx context.Context, userID string) (*model.UserMFA, error) {
+	return findOne[model.UserMFA](ctx, s.col(ColUserMFA), bson.D{{Key: "_id", Value: userID}})
+}
+
+func (s *Store) SaveUserMFA(ctx context.Context, mfa *model.UserMFA) error {
+	if mfa.BackupCodes == nil {
+		mfa.BackupCodes = []string{}
+	}
+	_, err := s.col(ColUserMFA).ReplaceOne(ctx, bson.D{{Key: "_id", Value: mfa.UserID}}, mfa, options.Replace().SetUpsert(true))
+	return wrapError(err)
+}
+
+func (s *Store) DeleteUserMFA(ctx context.Context, userID string) error {
+	_, err := s.col(ColUserMFA).DeleteOne(ctx, bson.D{{Key: "_id", Value: userID}})
+	return wrapError(err)
+}
+
+func (s *Store) UseUserMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := s.col(ColUserMFA).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: userID}, {Key: "last_step", Value: bson.D{{Key: "$lt", Value: step}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "last_step", Value: step}}}})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (s *Store) ConsumeUserBackupCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	res, err := s.col(ColUserMFA).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: userID}, {Key: "backup_codes", Value: codeHash}},
+		bson.D{
+			{Key: "$pull", Value: bson.D{{Key: "backup_codes", Value: codeHash}}},
+			{Key: "$set", Value: bson.D{{Key: "updated_at", Value: time.Now()}}},
+		})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (s *Store) CreateUserSession(ctx context.Context, session *model.UserSession) error {
+	return insertOne(ctx, s.col(ColUserSessions), session)
+}
+
+func (s *Store) GetUserSession(ctx context.Context, id string) (*model.UserSession, error) {
+	return findOne[model.UserSession](ctx, s.col(ColUserSessions), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListUserSessions(ctx context.Context, userID string) ([]*model.UserSession, error) {
+	filter := bson.D{
+		{Key: "user_id", Value: userID},
+		{Key: "revoked_at", Value: bson.D{{Key: "$exists", Value: false}}},
+		{Key: "expires_at", Value: bson.D{{Key: "$gt", Value: time.Now()}}},
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	return findMany[model.UserSession](ctx, s.col(ColUserSessions), filter, opts)
+}
+
+func (s *Store) TouchUserSession(ctx context.Context, id string, at time.Time) error {
+	_, err := s.col(ColUserSessions).UpdateOne(ctx, bson.D{{Key: "_id", Value: id}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "last_used_at", Value: at}}}})
+	return wrapError(err)
+}
+
+func (s *Store) RevokeUserSession(ctx context.Context, userID, id string) (bool, error) {
+	res, err := s.col(ColUserSessions).UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "user_id", Value: userID}, {Key: "revoked_at", Value: bson.D{{Key: "$exists", Value: false}}}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: time.Now()}}}})
+	if err != nil {
+		return false, wrapError(err)
+	}
+	return res.ModifiedCount > 0, nil
+}
+
+func (s *Store) RevokeUserSessions(ctx context.Context, userID, exceptID string) (int64, error) {
+	res, err := s.col(ColUserSessions).UpdateMany(ctx,
+		bson.D{
+			{Key: "user_id", Value: userID},
+			{Key: "_id", Value: bson.D{{Key: "$ne", Value: exceptID}}},
+			{Key: "revoked_at", Value: bson.D{{Key: "$exists", Value: false}}},
+		},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "revoked_at", Value: time.Now()}}}})
+	if err != nil {
+		return 0, wrapError(err)
+	}
+	return res.ModifiedCount, nil
+}
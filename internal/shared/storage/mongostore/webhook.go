@@ -0,0 +1,51 @@
+package mongostore
+
+import (
+	"context"
+	"time"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// WebhookStore
+// ============================================================================
+
+func (s *Store) CreateWebhook(ctx context.Context, w *model.Webhook) error {
+	return insertOne(ctx, s.col(ColWebhooks), w)
+}
+
+func (s *Store) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	return findOne[model.Webhook](ctx, s.col(ColWebhooks), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}})
+	return findMany[model.Webhook](ctx, s.col(ColWebhooks), bson.D{}, opts)
+}
+
+func (s *Store) UpdateWebhook(ctx context.Context, w *model.Webhook) error {
+	return updateFields(ctx, s.col(ColWebhooks), w.ID, bson.D{
+		{Key: "name", Value: w.Name},
+		{Key: "url", Value: w.URL},
+		{Key: "secret", Value: w.Secret},
+		{Key: "enabled", Value: w.Enabled},
+		{Key: "filter", Value: w.Filter},
+		{Key: "updated_at", Value: w.UpdatedAt},
+	})
+}
+
+func (s *Store) DeleteWebhook(ctx context.Context, id string) error {
+	return deleteByID(ctx, s.col(ColWebhooks), id)
+}
+
+func (s *Store) RecordWebhookDelivery(ctx context.Context, id string, at time.Time, status int, errMsg string) error {
+	return updateFields(ctx, s.col(ColWebhooks), id, bson.D{
+		{Key: "last_delivery_at", Value: at},
+		{Key: "last_status", Value: status},
+		{Key: "last_error", Value: errMsg},
+	})
+}
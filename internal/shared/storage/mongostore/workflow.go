@@ -0,0 +1,46 @@
+package mongostore
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ============================================================================
+// WorkflowStore
+// ============================================================================
+
+func (s *Store) CreateWorkflow(ctx context.Context, wf *model.Workflow) error {
+	return insertOne(ctx, s.col(ColWorkflows), wf)
+}
+
+func (s *Store) GetWorkflow(ctx context.Context, id string) (*model.Workflow, error) {
+	return findOne[model.Workflow](ctx, s.col(ColWorkflows), bson.D{{Key: "_id", Value: id}})
+}
+
+func (s *Store) ListWorkflows(ctx context.Context, status string, limit, offset int) ([]*model.Workflow, error) {
+	filter := bson.D{}
+	if status != "" {
+		filter = append(filter, bson.E{Key: "status", Value: status})
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+	return findMany[model.Workflow](ctx, s.col(ColWorkflows), filter, opts)
+}
+
+func (s *Store) UpdateWorkflow(ctx context.Context, wf *model.Workflow) error {
+	return updateFields(ctx, s.col(ColWorkflows), wf.ID, bson.D{
+		{Key: "status", Value: wf.Status},
+		{Key: "nodes", Value: wf.Nodes},
+		{Key: "updated_at", Value: wf.UpdatedAt},
+		{Key: "finished_at", Value: wf.FinishedAt},
+	})
+}
@@ -143,7 +143,7 @@ func (s *Store) GetAuthTaskByAccountID(ctx context.Context, accountID string) (*
 func (s *Store) ListRecentAuthTasks(ctx context.Context, limit int) ([]*model.AuthTask, error) {
 	query := s.rebind(`SELECT id, account_id, method, node_id, status, terminal_port, terminal_url, container_name, message, created_at, updated_at, expires_at 
 			  FROM auth_tasks ORDER BY created_at DESC LIMIT $1`)
-	rows, err := s.db.QueryContext(ctx, query, limit)
+	rows, err := s.reader(ctx).QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"agents-admin/internal/shared/model"
+)
+
+const agentTypeColumns = `id, name, image, auth_dir, auth_file, login_cmd, login_methods, description, adapter,
+	created_at, updated_at`
+
+// CreateAgentType 创建自定义 Agent 类型
+func (s *Store) CreateAgentType(ctx context.Context, t *model.AgentTypeConfig) error {
+	methods, _ := json.Marshal(t.LoginMethods)
+	adapter, _ := json.Marshal(t.Adapter)
+	query := s.rebind(`INSERT INTO agent_types (id, name, image, auth_dir, auth_file, login_cmd, login_methods, description,
+		adapter, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`)
+	_, err := s.db.ExecContext(ctx, query, t.ID, t.Name, t.Image, t.AuthDir, t.AuthFile, t.LoginCmd, methods,
+		t.Description, adapter, t.CreatedAt, t.UpdatedAt)
+	return err
+}
+
+// GetAgentType 获取自定义 Agent 类型
+func (s *Store) GetAgentType(ctx context.Context, id string) (*model.AgentTypeConfig, error) {
+	query := s.rebind(`SELECT ` + agentTypeColumns + ` FROM agent_types WHERE id = $1`)
+	t, err := scanAgentType(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// ListAgentTypes 列出所有自定义 Agent 类型（按创建时间升序）
+func (s *Store) ListAgentTypes(ctx context.Context) ([]*model.AgentTypeConfig, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+agentTypeColumns+` FROM agent_types ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	types := []*model.AgentTypeConfig{}
+	for rows.Next() {
+		t, err := scanAgentType(rows)
+		if err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	return types, rows.Err()
+}
+
+// UpdateAgentType 更新自定义 Agent 类型的名称、镜像、认证配置、描述与适配器配置
+func (s *Store) UpdateAgentType(ctx context.Context, t *model.AgentTypeConfig) error {
+	methods, _ := json.Marshal(t.LoginMethods)
+	adapter, _ := json.Marshal(t.Adapter)
+	query := s.rebind(`UPDATE agent_types SET name = $1, image = $2, auth_dir = $3, auth_file = $4, login_cmd = $5,
+		login_methods = $6, description = $7, adapter = $8, updated_at = $9 WHERE id = $10`)
+	_, err := s.db.ExecContext(ctx, query, t.Name, t.Image, t.AuthDir, t.AuthFile, t.LoginCmd, methods, t.Description,
+		adapter, t.UpdatedAt, t.ID)
+	return err
+}
+
+// DeleteAgentType 删除自定义 Agent 类型
+func (s *Store) DeleteAgentType(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM agent_types WHERE id = $1`), id)
+	return err
+}
+
+// scanAgentType 辅助函数：从数据库行扫描 Agent 类型
+func scanAgentType(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.AgentTypeConfig, error) {
+	t := &model.AgentTypeConfig{}
+	var methods, adapter []byte
+	if err := scanner.Scan(&t.ID, &t.Name, &t.Image, &t.AuthDir, &t.AuthFile, &t.LoginCmd, &methods, &t.Description,
+		&adapter, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(methods) > 0 && string(methods) != "null" {
+		json.Unmarshal(methods, &t.LoginMethods)
+	}
+	if len(adapter) > 0 && string(adapter) != "null" {
+		json.Unmarshal(adapter, &t.Adapter)
+	}
+	return t, nil
+}
@@ -0,0 +1,41 @@
+// Package repository Artifact 相关的存储操作
+package repository
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+)
+
+// CreateArtifact 创建执行产物记录
+func (s *Store) CreateArtifact(ctx context.Context, artifact *model.Artifact) error {
+	query := s.rebind(`
+		INSERT INTO artifacts (run_id, name, path, size, content_type, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		artifact.RunID, artifact.Name, artifact.Path,
+		artifact.Size, artifact.ContentType, artifact.CreatedAt)
+	return err
+}
+
+// ListArtifactsByRun 列出 Run 的所有产物
+func (s *Store) ListArtifactsByRun(ctx context.Context, runID string) ([]*model.Artifact, error) {
+	query := s.rebind(`SELECT id, run_id, name, path, size, content_type, created_at
+			  FROM artifacts WHERE run_id = $1 ORDER BY id ASC`)
+	rows, err := s.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	artifacts := []*model.Artifact{}
+	for rows.Next() {
+		a := &model.Artifact{}
+		if err := rows.Scan(&a.ID, &a.RunID, &a.Name, &a.Path, &a.Size, &a.ContentType, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		artifacts = append(artifacts, a)
+	}
+	return artifacts, rows.Err()
+}
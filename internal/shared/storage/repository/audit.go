@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
+)
+
+const auditLogColumns = `id, actor_id, actor_email, actor_role, method, route, path, resource_type, resource_id,
+	status, source_ip, request, duration_ms, created_at`
+
+// CreateAuditLog 追加审计日志
+func (s *Store) CreateAuditLog(ctx context.Context, e *model.AuditLog) error {
+	query := s.rebind(`INSERT INTO audit_logs (` + auditLogColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`)
+	var request interface{}
+	if len(e.Request) > 0 {
+		request = []byte(e.Request)
+	}
+	_, err := s.db.ExecContext(ctx, query, e.ID, e.ActorID, e.ActorEmail, e.ActorRole, e.Method, e.Route, e.Path,
+		e.ResourceType, e.ResourceID, e.Status, e.SourceIP, request, e.DurationMs, e.CreatedAt)
+	return err
+}
+
+// ListAuditLogs 带过滤条件列出审计日志（按 created_at DESC, id DESC 排序，支持游标分页）
+func (s *Store) ListAuditLogs(ctx context.Context, filter storagetypes.AuditFilter) ([]*model.AuditLog, int, error) {
+	var q listQuery
+	if filter.ActorID != "" {
+		q.add("actor_id = ?", filter.ActorID)
+	}
+	if filter.Method != "" {
+		q.add("method = ?", filter.Method)
+	}
+	if filter.ResourceType != "" {
+		q.add("resource_type = ?", filter.ResourceType)
+	}
+	if filter.ResourceID != "" {
+		q.add("resource_id = ?", filter.ResourceID)
+	}
+	if !filter.Since.IsZero() {
+		q.add("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q.add("created_at <= ?", filter.Until)
+	}
+
+	total := -1
+	if !filter.SkipTotal {
+		countQuery := s.rebind(render("SELECT COUNT(*) FROM audit_logs" + q.where()))
+		if err := s.db.QueryRowContext(ctx, countQuery, q.args...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	q.cursor(filter.Cursor)
+	page, args := pageArgs(q, filter.Cursor, filter.Limit, filter.Offset)
+	query := s.rebind(render(`SELECT ` + auditLogColumns + ` FROM audit_logs` + q.where() + " ORDER BY created_at DESC, id DESC" + page))
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	logs := []*model.AuditLog{}
+	for rows.Next() {
+		e, err := scanAuditLog(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		logs = append(logs, e)
+	}
+	return logs, total, rows.Err()
+}
+
+func scanAuditLog(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.AuditLog, error) {
+	e := &model.AuditLog{}
+	var raw []byte
+	if err := scanner.Scan(&e.ID, &e.ActorID, &e.ActorEmail, &e.ActorRole, &e.Method, &e.Route, &e.Path,
+		&e.ResourceType, &e.ResourceID, &e.Status, &e.SourceIP, &raw, &e.DurationMs, &e.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(raw) > 0 {
+		e.Request = raw
+	}
+	return e, nil
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agents-admin/internal/shared/model"
+)
+
+const budgetColumns = `id, name, scope, scope_id, limit_tokens, limit_usd, alert_threshold, enabled,
+	alerted_period, exceeded_period, created_at, updated_at`
+
+// CreateBudget 创建预算
+func (s *Store) CreateBudget(ctx context.Context, b *model.Budget) error {
+	query := s.rebind(`INSERT INTO budgets (` + budgetColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)`)
+	_, err := s.db.ExecContext(ctx, query, b.ID, b.Name, b.Scope, b.ScopeID, b.LimitTokens, b.LimitUSD,
+		b.AlertThreshold, b.Enabled, b.AlertedPeriod, b.ExceededPeriod, b.CreatedAt, b.UpdatedAt)
+	return err
+}
+
+// GetBudget 获取预算
+func (s *Store) GetBudget(ctx context.Context, id string) (*model.Budget, error) {
+	query := s.rebind(`SELECT ` + budgetColumns + ` FROM budgets WHERE id = $1`)
+	b, err := scanBudget(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return b, err
+}
+
+// ListBudgets 列出所有预算（按创建时间升序）
+func (s *Store) ListBudgets(ctx context.Context) ([]*model.Budget, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+budgetColumns+` FROM budgets ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	budgets := []*model.Budget{}
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, b)
+	}
+	return budgets, rows.Err()
+}
+
+// UpdateBudget 更新预算的名称、上限、预警阈值、启用状态与通知周期
+func (s *Store) UpdateBudget(ctx context.Context, b *model.Budget) error {
+	query := s.rebind(`UPDATE budgets SET name = $1, limit_tokens = $2, limit_usd = $3, alert_threshold = $4, enabled = $5,
+		alerted_period = $6, exceeded_period = $7, updated_at = $8 WHERE id = $9`)
+	_, err := s.db.ExecContext(ctx, query, b.Name, b.LimitTokens, b.LimitUSD, b.AlertThreshold, b.Enabled,
+		b.AlertedPeriod, b.ExceededPeriod, b.UpdatedAt, b.ID)
+	return err
+}
+
+// DeleteBudget 删除预算
+func (s *Store) DeleteBudget(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM budgets WHERE id = $1`), id)
+	return err
+}
+
+// scanBudget 辅助函数：从数据库行扫描预算
+func scanBudget(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.Budget, error) {
+	b := &model.Budget{}
+	if err := scanner.Scan(&b.ID, &b.Name, &b.Scope, &b.ScopeID, &b.LimitTokens, &b.LimitUSD, &b.AlertThreshold,
+		&b.Enabled, &b.AlertedPeriod, &b.ExceededPeriod, &b.CreatedAt, &b.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
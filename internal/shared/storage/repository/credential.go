@@ -0,0 +1,85 @@
+// Package repository Credential 相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agents-admin/internal/shared/model"
+)
+
+const credentialColumns = `id, name, type, username, description, encrypted_secret, created_at, updated_at`
+
+// CreateCredential 创建凭据
+func (s *Store) CreateCredential(ctx context.Context, cred *model.Credential) error {
+	query := s.rebind(`
+		INSERT INTO credentials (` + credentialColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		cred.ID, cred.Name, cred.Type, cred.Username, cred.Description,
+		cred.EncryptedSecret, cred.CreatedAt, cred.UpdatedAt)
+	return err
+}
+
+// GetCredential 按 ID 获取凭据
+func (s *Store) GetCredential(ctx context.Context, id string) (*model.Credential, error) {
+	query := s.rebind(`SELECT ` + credentialColumns + ` FROM credentials WHERE id = $1`)
+	return s.scanCredential(s.db.QueryRowContext(ctx, query, id))
+}
+
+// GetCredentialByName 按名称获取凭据（CredentialRef 解析）
+func (s *Store) GetCredentialByName(ctx context.Context, name string) (*model.Credential, error) {
+	query := s.rebind(`SELECT ` + credentialColumns + ` FROM credentials WHERE name = $1`)
+	return s.scanCredential(s.db.QueryRowContext(ctx, query, name))
+}
+
+// ListCredentials 列出所有凭据
+func (s *Store) ListCredentials(ctx context.Context) ([]*model.Credential, error) {
+	query := `SELECT ` + credentialColumns + ` FROM credentials ORDER BY name ASC`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	creds := []*model.Credential{}
+	for rows.Next() {
+		c := &model.Credential{}
+		if err := rows.Scan(&c.ID, &c.Name, &c.Type, &c.Username, &c.Description,
+			&c.EncryptedSecret, &c.CreatedAt, &c.UpdatedAt); err != nil {
+			return nil, err
+		}
+		creds = append(creds, c)
+	}
+	return creds, rows.Err()
+}
+
+// UpdateCredential 更新凭据
+func (s *Store) UpdateCredential(ctx context.Context, cred *model.Credential) error {
+	query := s.rebind(`UPDATE credentials SET name = $1, type = $2, username = $3, description = $4,
+			  encrypted_secret = $5, updated_at = $6 WHERE id = $7`)
+	_, err := s.db.ExecContext(ctx, query,
+		cred.Name, cred.Type, cred.Username, cred.Description,
+		cred.EncryptedSecret, cred.UpdatedAt, cred.ID)
+	return err
+}
+
+// DeleteCredential 删除凭据
+func (s *Store) DeleteCredential(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM credentials WHERE id = $1`), id)
+	return err
+}
+
+func (s *Store) scanCredential(row *sql.Row) (*model.Credential, error) {
+	c := &model.Credential{}
+	err := row.Scan(&c.ID, &c.Name, &c.Type, &c.Username, &c.Description,
+		&c.EncryptedSecret, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
@@ -3,11 +3,17 @@ package repository
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
 
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage/dbutil"
+	"agents-admin/internal/shared/storagetypes"
 )
 
-// CreateEvents 批量创建事件
+// CreateEvents 批量创建事件，(run_id, seq) 或 (run_id, node_seq) 已存在的事件被忽略
 func (s *Store) CreateEvents(ctx context.Context, events []*model.Event) error {
 	if len(events) == 0 {
 		return nil
@@ -20,14 +26,17 @@ func (s *Store) CreateEvents(ctx context.Context, events []*model.Event) error {
 	defer tx.Rollback()
 
 	stmt, err := tx.PrepareContext(ctx,
-		s.rebind(`INSERT INTO events (run_id, seq, type, timestamp, payload, raw) VALUES ($1, $2, $3, $4, $5, $6)`))
+		s.rebind(`INSERT INTO events (run_id, seq, type, timestamp, payload, raw, schema_version, body_zstd, raw_ref, node_seq, redacted)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			ON CONFLICT DO NOTHING`))
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
 	for _, e := range events {
-		_, err := stmt.ExecContext(ctx, e.RunID, e.Seq, e.Type, e.Timestamp, e.Payload, e.Raw)
+		payload, raw, body := s.encodeEventBody(e)
+		_, err := stmt.ExecContext(ctx, e.RunID, e.Seq, e.Type, e.Timestamp, payload, raw, e.SchemaVersion, body, nullString(e.RawRef), nullInt(e.NodeSeq), e.Redacted)
 		if err != nil {
 			return err
 		}
@@ -40,17 +49,79 @@ func (s *Store) CreateEvents(ctx context.Context, events []*model.Event) error {
 func (s *Store) CountEventsByRun(ctx context.Context, runID string) (int, error) {
 	query := s.rebind(`SELECT COUNT(1) FROM events WHERE run_id = $1`)
 	var cnt int
-	if err := s.db.QueryRowContext(ctx, query, runID).Scan(&cnt); err != nil {
+	if err := s.reader(ctx).QueryRowContext(ctx, query, runID).Scan(&cnt); err != nil {
 		return 0, err
 	}
 	return cnt, nil
 }
 
+// AllocateEventSeqs 为 Run 预留 n 个连续的事件序号，返回第一个
+//
+// 计数器为 runs.last_event_seq（迁移 058 按已有事件的最大 seq 初始化），更新行锁保证并发上报分配的序号不重叠。
+func (s *Store) AllocateEventSeqs(ctx context.Context, runID string, n int) (int, error) {
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid event seq count: %d", n)
+	}
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, s.rebind(`UPDATE runs SET last_event_seq = last_event_seq + $1 WHERE id = $2`), n, runID)
+	if err != nil {
+		return 0, err
+	}
+	if affected, err := res.RowsAffected(); err != nil {
+		return 0, err
+	} else if affected == 0 {
+		return 0, fmt.Errorf("run %s not found", runID)
+	}
+	var last int
+	if err := tx.QueryRowContext(ctx, s.rebind(`SELECT last_event_seq FROM runs WHERE id = $1`), runID).Scan(&last); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return last - n + 1, nil
+}
+
+// ListEventNodeSeqs 返回 Run 中节点序号在 [fromNodeSeq, toNodeSeq] 区间内已入库的事件的节点序号
+func (s *Store) ListEventNodeSeqs(ctx context.Context, runID string, fromNodeSeq, toNodeSeq int) ([]int, error) {
+	rows, err := s.db.QueryContext(ctx,
+		s.rebind(`SELECT node_seq FROM events WHERE run_id = $1 AND node_seq >= $2 AND node_seq <= $3 ORDER BY node_seq`),
+		runID, fromNodeSeq, toNodeSeq)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var seqs []int
+	for rows.Next() {
+		var seq int
+		if err := rows.Scan(&seq); err != nil {
+			return nil, err
+		}
+		seqs = append(seqs, seq)
+	}
+	return seqs, rows.Err()
+}
+
+// MaxEventSeqs 返回 Run 已入库事件的最大序号与最大节点序号（没有事件时为 0）
+func (s *Store) MaxEventSeqs(ctx context.Context, runID string) (int, int, error) {
+	var seq, nodeSeq int
+	err := s.db.QueryRowContext(ctx,
+		s.rebind(`SELECT COALESCE(MAX(seq), 0), COALESCE(MAX(node_seq), 0) FROM events WHERE run_id = $1`), runID).
+		Scan(&seq, &nodeSeq)
+	return seq, nodeSeq, err
+}
+
 // GetEventsByRun 获取 Run 的事件
 func (s *Store) GetEventsByRun(ctx context.Context, runID string, fromSeq int, limit int) ([]*model.Event, error) {
-	query := s.rebind(`SELECT id, run_id, seq, type, timestamp, payload, raw 
+	query := s.rebind(`SELECT id, run_id, seq, type, timestamp, payload, raw, schema_version, body_zstd, raw_ref, node_seq, redacted
 			  FROM events WHERE run_id = $1 AND seq > $2 ORDER BY seq ASC LIMIT $3`)
-	rows, err := s.db.QueryContext(ctx, query, runID, fromSeq, limit)
+	rows, err := s.reader(ctx).QueryContext(ctx, query, runID, fromSeq, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -59,14 +130,135 @@ func (s *Store) GetEventsByRun(ctx context.Context, runID string, fromSeq int, l
 	var events []*model.Event
 	for rows.Next() {
 		e := &model.Event{}
-		var payload *[]byte
-		if err := rows.Scan(&e.ID, &e.RunID, &e.Seq, &e.Type, &e.Timestamp, &payload, &e.Raw); err != nil {
+		var payload, body *[]byte
+		var rawRef sql.NullString
+		var nodeSeq sql.NullInt64
+		if err := rows.Scan(&e.ID, &e.RunID, &e.Seq, &e.Type, &e.Timestamp, &payload, &e.Raw, &e.SchemaVersion, &body, &rawRef, &nodeSeq, &e.Redacted); err != nil {
 			return nil, err
 		}
 		if payload != nil {
 			e.Payload = *payload
 		}
+		if body != nil {
+			if err := decodeEventBody(e, *body); err != nil {
+				return nil, fmt.Errorf("decode event run=%s seq=%d: %w", e.RunID, e.Seq, err)
+			}
+		}
+		e.RawRef = rawRef.String
+		e.NodeSeq = int(nodeSeq.Int64)
 		events = append(events, e)
 	}
 	return events, rows.Err()
 }
+
+// SearchEvents 全文检索事件的 payload 与原始输出，返回命中事件及总数
+//
+// PostgreSQL 使用 events.search_vector（GIN 索引，见迁移 029）与 ts_headline 生成高亮；
+// 其他数据库退化为逐关键词 LIKE 匹配，高亮片段由 model.HighlightSnippet 生成。
+func (s *Store) SearchEvents(ctx context.Context, filter storagetypes.EventSearchFilter) ([]*model.EventSearchHit, int, error) {
+	terms := model.SearchTerms(filter.Query)
+	if len(terms) == 0 {
+		return nil, 0, nil
+	}
+	if s.dialect.DriverType() == dbutil.DriverPostgres {
+		return s.searchEventsFullText(ctx, filter)
+	}
+	return s.searchEventsLike(ctx, filter, terms)
+}
+
+// eventSearchDocument 参与检索的事件文本（与迁移 029 中 search_vector 的定义一致）
+const eventSearchDocument = `coalesce(payload::text, '') || ' ' || coalesce(raw, '')`
+
+// searchEventsFullText PostgreSQL 全文检索（按相关度排序）
+func (s *Store) searchEventsFullText(ctx context.Context, filter storagetypes.EventSearchFilter) ([]*model.EventSearchHit, int, error) {
+	where := ` FROM events, plainto_tsquery('simple', $1) q WHERE search_vector @@ q`
+	args := []interface{}{filter.Query}
+	if filter.RunID != "" {
+		where += ` AND run_id = $2`
+		args = append(args, filter.RunID)
+	}
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, s.rebind(`SELECT COUNT(*)`+where), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	n := len(args)
+	query := `SELECT id, run_id, seq, type, timestamp,
+			  ts_headline('simple', ` + eventSearchDocument + `, q,
+			    'StartSel=` + model.SearchHighlightStart + `, StopSel=` + model.SearchHighlightStop + `, MaxWords=35, MinWords=15, MaxFragments=2'),
+			  ts_rank(search_vector, q) AS rank` + where +
+		` ORDER BY rank DESC, timestamp DESC LIMIT $` + strconv.Itoa(n+1) + ` OFFSET $` + strconv.Itoa(n+2)
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var hits []*model.EventSearchHit
+	for rows.Next() {
+		h := &model.EventSearchHit{}
+		if err := rows.Scan(&h.EventID, &h.RunID, &h.Seq, &h.Type, &h.Timestamp, &h.Highlight, &h.Rank); err != nil {
+			return nil, 0, err
+		}
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+// searchEventsLike 子串匹配检索（SQLite/MySQL，按时间倒序）
+func (s *Store) searchEventsLike(ctx context.Context, filter storagetypes.EventSearchFilter, terms []string) ([]*model.EventSearchHit, int, error) {
+	conditions := []string{}
+	args := []interface{}{}
+	argIdx := 1
+	for _, term := range terms {
+		pattern := "%" + escapeLike(term) + "%"
+		conditions = append(conditions, "(LOWER(payload) LIKE $"+strconv.Itoa(argIdx)+` ESCAPE '\' OR LOWER(raw) LIKE $`+strconv.Itoa(argIdx+1)+` ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+		argIdx += 2
+	}
+	if filter.RunID != "" {
+		conditions = append(conditions, "run_id = $"+strconv.Itoa(argIdx))
+		args = append(args, filter.RunID)
+		argIdx++
+	}
+	where := " FROM events WHERE " + strings.Join(conditions, " AND ")
+
+	var total int
+	if err := s.db.QueryRowContext(ctx, s.rebind("SELECT COUNT(*)"+where), args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := s.rebind("SELECT id, run_id, seq, type, timestamp, payload, raw" + where +
+		" ORDER BY timestamp DESC, id DESC LIMIT $" + strconv.Itoa(argIdx) + " OFFSET $" + strconv.Itoa(argIdx+1))
+	rows, err := s.db.QueryContext(ctx, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var hits []*model.EventSearchHit
+	for rows.Next() {
+		h := &model.EventSearchHit{}
+		var payload *[]byte
+		var raw *string
+		if err := rows.Scan(&h.EventID, &h.RunID, &h.Seq, &h.Type, &h.Timestamp, &payload, &raw); err != nil {
+			return nil, 0, err
+		}
+		doc := ""
+		if payload != nil {
+			doc = string(*payload)
+		}
+		if raw != nil {
+			doc += " " + *raw
+		}
+		h.Highlight = model.HighlightSnippet(doc, terms)
+		hits = append(hits, h)
+	}
+	return hits, total, rows.Err()
+}
+
+// escapeLike 转义 LIKE 通配符（配合 ESCAPE '\' 使用）
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
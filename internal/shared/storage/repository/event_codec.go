@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"errors"
+
+	"agents-admin/internal/shared/model"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// 事件压缩存储
+//
+// 节点上报的原始输出行（raw）与解析出的 payload 内容高度重复，输出频繁的 Run 会让 events 表迅速膨胀。
+// 两者合计超过阈值时合并为一个 zstd 帧写入 body_zstd 列、payload 与 raw 列置空：同一帧内的重复内容
+// 只存一份，读取时透明解压，调用方无感知。
+//
+// 压缩后的内容不参与数据库检索（PostgreSQL search_vector 与 LIKE 匹配只覆盖未压缩的事件）。
+
+// bodyHasRaw 压缩帧头部标记：事件带有原始输出
+const bodyHasRaw byte = 1
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+// errInvalidEventBody 压缩帧格式错误
+var errInvalidEventBody = errors.New("invalid compressed event body")
+
+// SetEventCompression 设置事件压缩阈值（字节），payload 与原始输出合计超过阈值时压缩存储，<= 0 关闭压缩
+//
+// 只影响之后写入的事件，已压缩的事件始终可以读取。
+func (s *Store) SetEventCompression(threshold int) {
+	s.compressThreshold = max(threshold, 0)
+}
+
+// encodeEventBody 返回写入 payload / raw / body_zstd 三列的值：未超过阈值时原样写入，否则压缩为 body_zstd
+func (s *Store) encodeEventBody(e *model.Event) (payload, raw, body interface{}) {
+	size := len(e.Payload)
+	if e.Raw != nil {
+		size += len(*e.Raw)
+	}
+	if s.compressThreshold == 0 || size < s.compressThreshold {
+		return e.Payload, e.Raw, nil
+	}
+
+	// 帧内容：标记(1B) + payload 长度(uvarint) + payload + raw
+	plain := make([]byte, 0, size+binary.MaxVarintLen64+1)
+	var flags byte
+	if e.Raw != nil {
+		flags |= bodyHasRaw
+	}
+	plain = append(plain, flags)
+	plain = binary.AppendUvarint(plain, uint64(len(e.Payload)))
+	plain = append(plain, e.Payload...)
+	if e.Raw != nil {
+		plain = append(plain, *e.Raw...)
+	}
+	return nil, nil, zstdEncoder.EncodeAll(plain, make([]byte, 0, len(plain)/4))
+}
+
+// decodeEventBody 解压 body_zstd，还原事件的 payload 与原始输出
+func decodeEventBody(e *model.Event, body []byte) error {
+	plain, err := zstdDecoder.DecodeAll(body, nil)
+	if err != nil {
+		return err
+	}
+	if len(plain) == 0 {
+		return errInvalidEventBody
+	}
+	flags := plain[0]
+	n, read := binary.Uvarint(plain[1:])
+	if read <= 0 || uint64(len(plain)-1-read) < n {
+		return errInvalidEventBody
+	}
+	rest := plain[1+read:]
+	if n > 0 {
+		e.Payload = rest[:n]
+	}
+	if flags&bodyHasRaw != 0 {
+		raw := string(rest[n:])
+		e.Raw = &raw
+	}
+	return nil
+}
+
+// nullString 空字符串写入 NULL
+func nullString(v string) sql.NullString {
+	return sql.NullString{String: v, Valid: v != ""}
+}
+
+// nullInt 0 写入 NULL
+func nullInt(v int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(v), Valid: v != 0}
+}
@@ -279,3 +279,13 @@ func (s *Store) UpdateConfirmationStatus(ctx context.Context, id string, status
 	_, err := s.db.ExecContext(ctx, query, status, selectedOption, id)
 	return err
 }
+
+// CountPendingApprovals 统计全部待处理的审批请求与确认请求数量
+func (s *Store) CountPendingApprovals(ctx context.Context) (int, error) {
+	query := s.rebind(`SELECT
+			(SELECT COUNT(*) FROM approval_requests WHERE status = $1) +
+			(SELECT COUNT(*) FROM confirmations WHERE status = $2)`)
+	var n int
+	err := s.db.QueryRowContext(ctx, query, model.ApprovalStatusPending, model.ConfirmStatusPending).Scan(&n)
+	return n, err
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// ClaimIdempotencyKey 原子地认领幂等键
+//
+// 先删除同一个键的过期记录，再以 ON CONFLICT DO NOTHING 写入：写入行数为 0 说明键已被认领，返回已有记录。
+func (s *Store) ClaimIdempotencyKey(ctx context.Context, k *model.IdempotencyKey) (*model.IdempotencyKey, error) {
+	if _, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM idempotency_keys WHERE scope = $1 AND idem_key = $2 AND expires_at <= $3`),
+		k.Scope, k.Key, time.Now()); err != nil {
+		return nil, err
+	}
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO idempotency_keys (scope, idem_key, request_hash, resource_id, status_code, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (scope, idem_key) DO NOTHING`),
+		k.Scope, k.Key, k.RequestHash, k.ResourceID, k.StatusCode, k.CreatedAt, k.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 1 {
+		return nil, err
+	}
+
+	existing := &model.IdempotencyKey{}
+	var response []byte
+	err = s.db.QueryRowContext(ctx, s.rebind(`
+		SELECT scope, idem_key, request_hash, resource_id, status_code, response, created_at, expires_at
+		FROM idempotency_keys WHERE scope = $1 AND idem_key = $2`), k.Scope, k.Key).Scan(
+		&existing.Scope, &existing.Key, &existing.RequestHash, &existing.ResourceID, &existing.StatusCode,
+		&response, &existing.CreatedAt, &existing.ExpiresAt)
+	if err == sql.ErrNoRows {
+		// 已有记录在两次查询之间被释放，按已认领处理，由客户端重试
+		return &model.IdempotencyKey{Scope: k.Scope, Key: k.Key, RequestHash: k.RequestHash}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	existing.Response = response
+	return existing, nil
+}
+
+// CompleteIdempotencyKey 记录创建的资源与原始响应
+func (s *Store) CompleteIdempotencyKey(ctx context.Context, scope, key, resourceID string, statusCode int, response []byte) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE idempotency_keys SET resource_id = $1, status_code = $2, response = $3
+		WHERE scope = $4 AND idem_key = $5`), resourceID, statusCode, response, scope, key)
+	return err
+}
+
+// DeleteIdempotencyKey 释放幂等键
+func (s *Store) DeleteIdempotencyKey(ctx context.Context, scope, key string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM idempotency_keys WHERE scope = $1 AND idem_key = $2`), scope, key)
+	return err
+}
+
+// DeleteExpiredIdempotencyKeys 删除过期的幂等键
+func (s *Store) DeleteExpiredIdempotencyKeys(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM idempotency_keys WHERE expires_at < $1`), before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -96,6 +96,19 @@ func (s *Store) UpdateAgentInstance(ctx context.Context, id string, status model
 	return nil
 }
 
+// TransitionAgentInstance 仍处于 from 状态时更新为 to，返回是否更新成功
+//
+// 条件更新保证节点上报的观测状态不会覆盖期间用户发起的启动/停止。
+func (s *Store) TransitionAgentInstance(ctx context.Context, id string, from, to model.InstanceStatus, containerName *string) (bool, error) {
+	query := s.rebind(`UPDATE agents SET status = $1, container_name = COALESCE($2, container_name) WHERE id = $3 AND status = $4`)
+	res, err := s.db.ExecContext(ctx, query, to, containerName, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
 // DeleteAgentInstance 删除 Agent 实例
 func (s *Store) DeleteAgentInstance(ctx context.Context, id string) error {
 	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM agents WHERE id = $1`), id)
@@ -0,0 +1,116 @@
+// Package repository Integration 相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agents-admin/internal/shared/model"
+)
+
+const integrationColumns = `id, name, provider, base_url, repo, project_key, credential_ref, comment_results, created_at, updated_at`
+
+// CreateIntegration 创建集成
+func (s *Store) CreateIntegration(ctx context.Context, i *model.Integration) error {
+	query := s.rebind(`
+		INSERT INTO integrations (` + integrationColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		i.ID, i.Name, i.Provider, i.BaseURL, i.Repo, i.ProjectKey, i.CredentialRef, i.CommentResults,
+		i.CreatedAt, i.UpdatedAt)
+	return err
+}
+
+// GetIntegration 获取集成
+func (s *Store) GetIntegration(ctx context.Context, id string) (*model.Integration, error) {
+	query := s.rebind(`SELECT ` + integrationColumns + ` FROM integrations WHERE id = $1`)
+	i, err := scanIntegration(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return i, err
+}
+
+// ListIntegrations 列出所有集成
+func (s *Store) ListIntegrations(ctx context.Context) ([]*model.Integration, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+integrationColumns+` FROM integrations ORDER BY name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	integrations := []*model.Integration{}
+	for rows.Next() {
+		i, err := scanIntegration(rows)
+		if err != nil {
+			return nil, err
+		}
+		integrations = append(integrations, i)
+	}
+	return integrations, rows.Err()
+}
+
+// UpdateIntegration 更新集成
+func (s *Store) UpdateIntegration(ctx context.Context, i *model.Integration) error {
+	query := s.rebind(`UPDATE integrations SET name = $1, provider = $2, base_url = $3, repo = $4,
+			  project_key = $5, credential_ref = $6, comment_results = $7, updated_at = $8 WHERE id = $9`)
+	_, err := s.db.ExecContext(ctx, query,
+		i.Name, i.Provider, i.BaseURL, i.Repo, i.ProjectKey, i.CredentialRef, i.CommentResults, i.UpdatedAt, i.ID)
+	return err
+}
+
+// DeleteIntegration 删除集成（已导入任务的关联一并删除）
+func (s *Store) DeleteIntegration(ctx context.Context, id string) error {
+	if _, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM issue_links WHERE integration_id = $1`), id); err != nil {
+		return err
+	}
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM integrations WHERE id = $1`), id)
+	return err
+}
+
+// CreateIssueLink 记录任务与源 Issue 的关联
+func (s *Store) CreateIssueLink(ctx context.Context, link *model.IssueLink) error {
+	query := s.rebind(`INSERT INTO issue_links (task_id, integration_id, issue_key, url, created_at)
+			  VALUES ($1, $2, $3, $4, $5)`)
+	_, err := s.db.ExecContext(ctx, query, link.TaskID, link.IntegrationID, link.IssueKey, link.URL, link.CreatedAt)
+	return err
+}
+
+// GetIssueLinkByTask 按任务获取源 Issue 关联
+func (s *Store) GetIssueLinkByTask(ctx context.Context, taskID string) (*model.IssueLink, error) {
+	query := s.rebind(`SELECT task_id, integration_id, issue_key, url, created_at FROM issue_links WHERE task_id = $1`)
+	return scanIssueLink(s.db.QueryRowContext(ctx, query, taskID))
+}
+
+// GetIssueLinkByIssue 按集成与 Issue 标识获取关联（用于避免重复导入）
+func (s *Store) GetIssueLinkByIssue(ctx context.Context, integrationID, issueKey string) (*model.IssueLink, error) {
+	query := s.rebind(`SELECT task_id, integration_id, issue_key, url, created_at FROM issue_links
+			  WHERE integration_id = $1 AND issue_key = $2`)
+	return scanIssueLink(s.db.QueryRowContext(ctx, query, integrationID, issueKey))
+}
+
+// scanIntegration 辅助函数：从数据库行扫描 Integration
+func scanIntegration(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.Integration, error) {
+	i := &model.Integration{}
+	if err := scanner.Scan(&i.ID, &i.Name, &i.Provider, &i.BaseURL, &i.Repo, &i.ProjectKey, &i.CredentialRef,
+		&i.CommentResults, &i.CreatedAt, &i.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// scanIssueLink 辅助函数：扫描 IssueLink，不存在时返回 nil
+func scanIssueLink(row *sql.Row) (*model.IssueLink, error) {
+	link := &model.IssueLink{}
+	err := row.Scan(&link.TaskID, &link.IntegrationID, &link.IssueKey, &link.URL, &link.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return link, nil
+}
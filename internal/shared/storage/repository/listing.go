@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"strconv"
+	"strings"
+
+	"agents-admin/internal/shared/storage/dbutil"
+	"agents-admin/internal/shared/storagetypes"
+)
+
+// listQuery 列表查询的 WHERE 条件
+//
+// 条件中的参数以 ? 书写，按追加顺序收集参数；render 时统一编号为 $N 再交给 rebind。
+type listQuery struct {
+	conds []string
+	args  []interface{}
+}
+
+// add 追加条件及其参数
+func (q *listQuery) add(cond string, args ...interface{}) {
+	q.conds = append(q.conds, cond)
+	q.args = append(q.args, args...)
+}
+
+// in 追加 column IN (...) 条件，values 为空时不追加
+func (q *listQuery) in(column string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	q.add(column+" IN ("+strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")+")", args...)
+}
+
+// cursor 追加游标条件（列表按 created_at DESC, id DESC 排序）
+func (q *listQuery) cursor(c *storagetypes.PageCursor) {
+	if c == nil {
+		return
+	}
+	q.add("(created_at < ? OR (created_at = ? AND id < ?))", c.CreatedAt, c.CreatedAt, c.ID)
+}
+
+// and 合并另一组条件
+func (q *listQuery) and(other listQuery) {
+	q.conds = append(q.conds, other.conds...)
+	q.args = append(q.args, other.args...)
+}
+
+// subquery 追加 column IN (SELECT ... WHERE inner) 条件，inner 无条件时不追加
+func (q *listQuery) subquery(column, selectFrom string, inner listQuery) {
+	if len(inner.conds) == 0 {
+		return
+	}
+	q.add(column+" IN ("+selectFrom+" WHERE "+strings.Join(inner.conds, " AND ")+")", inner.args...)
+}
+
+// where 返回 WHERE 子句（无条件时为空）
+func (q *listQuery) where() string {
+	if len(q.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(q.conds, " AND ")
+}
+
+// render 将查询中的 ? 依次编号为 $1, $2, ...
+func render(query string) string {
+	var b strings.Builder
+	n := 0
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// labelConditions 将标签选择器转换为 JSON 列上的条件（PostgreSQL JSONB ->>，SQLite json_extract，MySQL JSON_EXTRACT）
+func (s *Store) labelConditions(column string, reqs []storagetypes.LabelRequirement) listQuery {
+	var q listQuery
+	for _, req := range reqs {
+		field, key := column+"->>?", interface{}(req.Key)
+		switch s.dialect.DriverType() {
+		case dbutil.DriverSQLite:
+			field, key = "json_extract("+column+", ?)", `$."`+req.Key+`"`
+		case dbutil.DriverMySQL:
+			field, key = "JSON_UNQUOTE(JSON_EXTRACT("+column+", ?))", `$."`+req.Key+`"`
+		}
+		switch req.Op {
+		case storagetypes.LabelEquals:
+			q.add(field+" = ?", key, req.Value)
+		case storagetypes.LabelNotEquals:
+			q.add("("+field+" IS NULL OR "+field+" <> ?)", key, key, req.Value)
+		case storagetypes.LabelExists:
+			q.add(field+" IS NOT NULL", key)
+		default:
+			q.add(field+" IS NULL", key)
+		}
+	}
+	return q
+}
+
+// pageArgs 列表查询的 LIMIT / OFFSET 子句与参数（limit <= 0 时不分页，使用游标时不带 OFFSET）
+func pageArgs(q listQuery, cursor *storagetypes.PageCursor, limit, offset int) (string, []interface{}) {
+	args := append([]interface{}{}, q.args...)
+	if limit <= 0 {
+		return "", args
+	}
+	clause := " LIMIT ?"
+	args = append(args, limit)
+	if cursor == nil && offset > 0 {
+		clause += " OFFSET ?"
+		args = append(args, offset)
+	}
+	return clause, args
+}
@@ -0,0 +1,276 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage/dbutil"
+)
+
+// sqliteDatabaseEntry SQLite 空闲页属于整个数据库文件，以该伪表名单独报告
+const sqliteDatabaseEntry = "(database)"
+
+// tableNameRe 维护语句无法参数化表名，只接受普通标识符
+var tableNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CreateMaintenanceRun 创建存储维护执行记录
+func (s *Store) CreateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error {
+	tables, _ := json.Marshal(r.Tables)
+	query := s.rebind(`
+		INSERT INTO maintenance_runs (id, driver, trigger_type, dry_run, status, tables, reclaimable_bytes, reclaimed_bytes,
+			error, created_by, started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+	`)
+	_, err := s.db.ExecContext(ctx, query, r.ID, r.Driver, r.Trigger, r.DryRun, r.Status, tables, r.ReclaimableBytes,
+		r.ReclaimedBytes, r.Error, r.CreatedBy, r.StartedAt, r.FinishedAt)
+	return err
+}
+
+// UpdateMaintenanceRun 更新存储维护执行结果
+func (s *Store) UpdateMaintenanceRun(ctx context.Context, r *model.MaintenanceRun) error {
+	tables, _ := json.Marshal(r.Tables)
+	query := s.rebind(`
+		UPDATE maintenance_runs SET status = $1, tables = $2, reclaimable_bytes = $3, reclaimed_bytes = $4, error = $5,
+			finished_at = $6
+		WHERE id = $7
+	`)
+	_, err := s.db.ExecContext(ctx, query, r.Status, tables, r.ReclaimableBytes, r.ReclaimedBytes, r.Error, r.FinishedAt, r.ID)
+	return err
+}
+
+// ListMaintenanceRuns 列出存储维护执行记录（按开始时间倒序）
+func (s *Store) ListMaintenanceRuns(ctx context.Context, limit int) ([]*model.MaintenanceRun, error) {
+	query := s.rebind(`
+		SELECT id, driver, trigger_type, dry_run, status, tables, reclaimable_bytes, reclaimed_bytes, error, created_by,
+			started_at, finished_at
+		FROM maintenance_runs ORDER BY started_at DESC LIMIT $1
+	`)
+	rows, err := s.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list maintenance runs: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.MaintenanceRun
+	for rows.Next() {
+		r := &model.MaintenanceRun{}
+		var tablesJSON []byte
+		if err := rows.Scan(&r.ID, &r.Driver, &r.Trigger, &r.DryRun, &r.Status, &tablesJSON, &r.ReclaimableBytes,
+			&r.ReclaimedBytes, &r.Error, &r.CreatedBy, &r.StartedAt, &r.FinishedAt); err != nil {
+			return nil, err
+		}
+		if len(tablesJSON) > 0 && string(tablesJSON) != "null" {
+			json.Unmarshal(tablesJSON, &r.Tables)
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// ============================================================================
+// storage.Maintainer
+// ============================================================================
+
+// EstimateMaintenance 估算各表的占用与可回收空间
+//
+//   - PostgreSQL：按 pg_stat_user_tables 的死元组比例估算表（含索引）可回收空间
+//   - SQLite：表占用来自 dbstat（不可用时为 0），空闲页以 "(database)" 一行报告
+//   - MySQL：按 information_schema.tables 的 data_free 估算可回收空间
+func (s *Store) EstimateMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	switch s.dialect.DriverType() {
+	case dbutil.DriverPostgres:
+		result := make([]model.MaintenanceTable, 0, len(tables))
+		for _, table := range tables {
+			result = append(result, s.estimatePostgresTable(ctx, table))
+		}
+		return result, nil
+	case dbutil.DriverSQLite:
+		return s.estimateSQLite(ctx, tables)
+	case dbutil.DriverMySQL:
+		result := make([]model.MaintenanceTable, 0, len(tables))
+		for _, table := range tables {
+			result = append(result, s.estimateMySQLTable(ctx, table))
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("storage maintenance not supported for driver %s", s.dialect.DriverType())
+	}
+}
+
+// RunMaintenance 执行存储维护
+//
+//   - PostgreSQL：逐表 VACUUM (ANALYZE)
+//   - SQLite：逐表 ANALYZE，再对整个数据库执行 incremental_vacuum
+//     （数据库不是 auto_vacuum=INCREMENTAL 时先切换并执行一次完整 VACUUM）
+//   - MySQL：逐表 OPTIMIZE TABLE（InnoDB 重建表并更新统计信息）
+func (s *Store) RunMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	switch s.dialect.DriverType() {
+	case dbutil.DriverPostgres:
+		result := make([]model.MaintenanceTable, 0, len(tables))
+		for _, table := range tables {
+			t := s.estimatePostgresTable(ctx, table)
+			if t.Error == "" {
+				start := time.Now()
+				if _, err := s.db.ExecContext(ctx, "VACUUM (ANALYZE) "+quoteIdent(table)); err != nil {
+					t.Error = err.Error()
+				}
+				t.DurationMS = time.Since(start).Milliseconds()
+				if after := s.estimatePostgresTable(ctx, table); after.Error == "" {
+					t.SizeAfterBytes = &after.SizeBytes
+				}
+			}
+			result = append(result, t)
+		}
+		return result, nil
+	case dbutil.DriverSQLite:
+		return s.runSQLiteMaintenance(ctx, tables)
+	case dbutil.DriverMySQL:
+		result := make([]model.MaintenanceTable, 0, len(tables))
+		for _, table := range tables {
+			t := s.estimateMySQLTable(ctx, table)
+			if t.Error == "" {
+				start := time.Now()
+				if _, err := s.db.ExecContext(ctx, "OPTIMIZE TABLE `"+table+"`"); err != nil {
+					t.Error = err.Error()
+				}
+				t.DurationMS = time.Since(start).Milliseconds()
+				if after := s.estimateMySQLTable(ctx, table); after.Error == "" {
+					t.SizeAfterBytes = &after.SizeBytes
+				}
+			}
+			result = append(result, t)
+		}
+		return result, nil
+	default:
+		return nil, fmt.Errorf("storage maintenance not supported for driver %s", s.dialect.DriverType())
+	}
+}
+
+func (s *Store) estimatePostgresTable(ctx context.Context, table string) model.MaintenanceTable {
+	t := model.MaintenanceTable{Table: table}
+	if !tableNameRe.MatchString(table) {
+		t.Error = "invalid table name"
+		return t
+	}
+	var live int64
+	err := s.db.QueryRowContext(ctx, `
+		SELECT pg_total_relation_size(relid), n_dead_tup, n_live_tup
+		FROM pg_stat_user_tables WHERE relname = $1
+	`, table).Scan(&t.SizeBytes, &t.DeadRows, &live)
+	if err != nil {
+		t.Error = err.Error()
+		return t
+	}
+	if total := live + t.DeadRows; total > 0 {
+		t.ReclaimableBytes = t.SizeBytes * t.DeadRows / total
+	}
+	return t
+}
+
+func (s *Store) estimateMySQLTable(ctx context.Context, table string) model.MaintenanceTable {
+	t := model.MaintenanceTable{Table: table}
+	if !tableNameRe.MatchString(table) {
+		t.Error = "invalid table name"
+		return t
+	}
+	err := s.db.QueryRowContext(ctx, `
+		SELECT COALESCE(data_length + index_length, 0), COALESCE(data_free, 0)
+		FROM information_schema.tables WHERE table_schema = DATABASE() AND table_name = ?
+	`, table).Scan(&t.SizeBytes, &t.ReclaimableBytes)
+	if err != nil {
+		t.Error = err.Error()
+	}
+	return t
+}
+
+func (s *Store) estimateSQLite(ctx context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	result := make([]model.MaintenanceTable, 0, len(tables)+1)
+	for _, table := range tables {
+		t := model.MaintenanceTable{Table: table}
+		if !tableNameRe.MatchString(table) {
+			t.Error = "invalid table name"
+		} else {
+			// dbstat 需要编译时启用，不可用时只报告数据库级别的空闲页
+			var size *int64
+			if err := s.db.QueryRowContext(ctx, `SELECT SUM(pgsize) FROM dbstat WHERE name = ?`, table).Scan(&size); err == nil && size != nil {
+				t.SizeBytes = *size
+			}
+		}
+		result = append(result, t)
+	}
+
+	db := model.MaintenanceTable{Table: sqliteDatabaseEntry}
+	var pageSize, pageCount, freePages int64
+	if err := s.db.QueryRowContext(ctx, `
+		SELECT page_size, page_count, freelist_count FROM pragma_page_size(), pragma_page_count(), pragma_freelist_count()
+	`).Scan(&pageSize, &pageCount, &freePages); err != nil {
+		return nil, err
+	}
+	db.SizeBytes = pageSize * pageCount
+	db.ReclaimableBytes = pageSize * freePages
+	return append(result, db), nil
+}
+
+func (s *Store) runSQLiteMaintenance(ctx context.Context, tables []string) ([]model.MaintenanceTable, error) {
+	result, err := s.estimateSQLite(ctx, tables)
+	if err != nil {
+		return nil, err
+	}
+	for i := range result[:len(result)-1] {
+		t := &result[i]
+		if t.Error != "" {
+			continue
+		}
+		start := time.Now()
+		if _, err := s.db.ExecContext(ctx, "ANALYZE "+quoteIdent(t.Table)); err != nil {
+			t.Error = err.Error()
+		}
+		t.DurationMS = time.Since(start).Milliseconds()
+	}
+
+	db := &result[len(result)-1]
+	start := time.Now()
+	if err := s.sqliteVacuum(ctx); err != nil {
+		db.Error = err.Error()
+	}
+	db.DurationMS = time.Since(start).Milliseconds()
+	if after, err := s.estimateSQLite(ctx, nil); err == nil {
+		db.SizeAfterBytes = &after[0].SizeBytes
+	}
+	return result, nil
+}
+
+// sqliteVacuum 回收 SQLite 空闲页
+func (s *Store) sqliteVacuum(ctx context.Context) error {
+	// 单连接执行，保证 PRAGMA 与 VACUUM 作用于同一连接
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var mode int
+	if err := conn.QueryRowContext(ctx, `PRAGMA auto_vacuum`).Scan(&mode); err != nil {
+		return err
+	}
+	const autoVacuumIncremental = 2
+	if mode != autoVacuumIncremental {
+		// auto_vacuum 模式只有在 VACUUM 重建数据库后才生效，之后的维护只需增量回收
+		if _, err := conn.ExecContext(ctx, `PRAGMA auto_vacuum = INCREMENTAL`); err != nil {
+			return err
+		}
+		_, err := conn.ExecContext(ctx, `VACUUM`)
+		return err
+	}
+	_, err = conn.ExecContext(ctx, `PRAGMA incremental_vacuum`)
+	return err
+}
+
+// quoteIdent 引用 SQL 标识符（调用前已校验为普通标识符）
+func quoteIdent(name string) string {
+	return `"` + name + `"`
+}
@@ -4,7 +4,9 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	"agents-admin/internal/shared/model"
 )
@@ -53,22 +55,83 @@ func (s *Store) UpsertNodeHeartbeat(ctx context.Context, node *model.Node) error
 	return err
 }
 
+// TouchNodeHeartbeat 只刷新节点的 last_heartbeat（节点不存在时无操作）
+func (s *Store) TouchNodeHeartbeat(ctx context.Context, nodeID string, at time.Time) error {
+	query := s.rebind(`UPDATE nodes SET last_heartbeat = $1 WHERE id = $2`)
+	_, err := s.db.ExecContext(ctx, query, at, nodeID)
+	return err
+}
+
 // GetNode 获取节点
 func (s *Store) GetNode(ctx context.Context, id string) (*model.Node, error) {
-	query := s.rebind(`SELECT id, COALESCE(display_name, ''), status, COALESCE(hostname, ''), COALESCE(ips, ''), COALESCE(labels, '{}'), COALESCE(capacity, '{}'), last_heartbeat, created_at, updated_at FROM nodes WHERE id = $1`)
+	query := s.rebind(`SELECT id, COALESCE(display_name, ''), status, COALESCE(hostname, ''), COALESCE(ips, ''), COALESCE(labels, '{}'), COALESCE(capacity, '{}'), taints, last_heartbeat, created_at, updated_at FROM nodes WHERE id = $1`)
 	node := &model.Node{}
+	var taints []byte
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&node.ID, &node.DisplayName, &node.Status, &node.Hostname, &node.IPs, &node.Labels, &node.Capacity,
-		&node.LastHeartbeat, &node.CreatedAt, &node.UpdatedAt)
+		&taints, &node.LastHeartbeat, &node.CreatedAt, &node.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
-	return node, err
+	if err != nil {
+		return nil, err
+	}
+	return node, unmarshalNodeTaints(node, taints)
+}
+
+// GetNodeCapacityOverride 获取控制面对节点并发配置的覆盖（节点不存在或未设置时返回 nil）
+func (s *Store) GetNodeCapacityOverride(ctx context.Context, nodeID string) (*model.NodeConcurrencyOverride, error) {
+	var raw []byte
+	err := s.db.QueryRowContext(ctx, s.rebind(`SELECT capacity_override FROM nodes WHERE id = $1`), nodeID).Scan(&raw)
+	if err == sql.ErrNoRows || (err == nil && len(raw) == 0) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var o model.NodeConcurrencyOverride
+	if err := json.Unmarshal(raw, &o); err != nil {
+		return nil, err
+	}
+	if o.IsEmpty() {
+		return nil, nil
+	}
+	return &o, nil
+}
+
+// SetNodeCapacityOverride 保存节点并发配置的覆盖（o 为空时清除）
+func (s *Store) SetNodeCapacityOverride(ctx context.Context, nodeID string, o *model.NodeConcurrencyOverride) error {
+	var raw interface{}
+	if !o.IsEmpty() {
+		data, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		raw = data
+	}
+	query := s.rebind(`UPDATE nodes SET capacity_override = $1, updated_at = ` + s.dialect.CurrentTimestamp() + ` WHERE id = $2`)
+	_, err := s.db.ExecContext(ctx, query, raw, nodeID)
+	return err
+}
+
+// SetNodeTaints 保存节点污点（taints 为空时清除）
+func (s *Store) SetNodeTaints(ctx context.Context, nodeID string, taints []model.NodeTaint) error {
+	var raw interface{}
+	if len(taints) > 0 {
+		data, err := json.Marshal(taints)
+		if err != nil {
+			return err
+		}
+		raw = data
+	}
+	query := s.rebind(`UPDATE nodes SET taints = $1, updated_at = ` + s.dialect.CurrentTimestamp() + ` WHERE id = $2`)
+	_, err := s.db.ExecContext(ctx, query, raw, nodeID)
+	return err
 }
 
 // ListAllNodes 列出所有节点
 func (s *Store) ListAllNodes(ctx context.Context) ([]*model.Node, error) {
-	query := `SELECT id, COALESCE(display_name, ''), status, COALESCE(hostname, ''), COALESCE(ips, ''), COALESCE(labels, '{}'), COALESCE(capacity, '{}'), last_heartbeat, created_at, updated_at 
+	query := `SELECT id, COALESCE(display_name, ''), status, COALESCE(hostname, ''), COALESCE(ips, ''), COALESCE(labels, '{}'), COALESCE(capacity, '{}'), taints, last_heartbeat, created_at, updated_at 
 			  FROM nodes ORDER BY created_at DESC`
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -80,7 +143,7 @@ func (s *Store) ListAllNodes(ctx context.Context) ([]*model.Node, error) {
 
 // ListOnlineNodes 列出在线节点
 func (s *Store) ListOnlineNodes(ctx context.Context) ([]*model.Node, error) {
-	query := `SELECT id, COALESCE(display_name, ''), status, COALESCE(hostname, ''), COALESCE(ips, ''), COALESCE(labels, '{}'), COALESCE(capacity, '{}'), last_heartbeat, created_at, updated_at 
+	query := `SELECT id, COALESCE(display_name, ''), status, COALESCE(hostname, ''), COALESCE(ips, ''), COALESCE(labels, '{}'), COALESCE(capacity, '{}'), taints, last_heartbeat, created_at, updated_at 
 			  FROM nodes WHERE status = 'online' ORDER BY last_heartbeat DESC`
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -112,11 +175,23 @@ func scanNodes(rows *sql.Rows) ([]*model.Node, error) {
 	var nodes []*model.Node
 	for rows.Next() {
 		node := &model.Node{}
+		var taints []byte
 		if err := rows.Scan(&node.ID, &node.DisplayName, &node.Status, &node.Hostname, &node.IPs, &node.Labels, &node.Capacity,
-			&node.LastHeartbeat, &node.CreatedAt, &node.UpdatedAt); err != nil {
+			&taints, &node.LastHeartbeat, &node.CreatedAt, &node.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := unmarshalNodeTaints(node, taints); err != nil {
 			return nil, err
 		}
 		nodes = append(nodes, node)
 	}
 	return nodes, rows.Err()
 }
+
+// unmarshalNodeTaints 解析节点污点列（未设置时为 NULL）
+func unmarshalNodeTaints(node *model.Node, raw []byte) error {
+	if len(raw) == 0 {
+		return nil
+	}
+	return json.Unmarshal(raw, &node.Taints)
+}
@@ -0,0 +1,183 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const nodeJoinTokenColumns = `id, token_hash, description, created_by, max_uses, use_count, labels, pool,
+	expires_at, used_at, used_by_node, created_at`
+
+// CreateNodeJoinToken 创建节点加入令牌
+func (s *Store) CreateNodeJoinToken(ctx context.Context, t *model.NodeJoinToken) error {
+	labels, _ := json.Marshal(t.Labels)
+	query := s.rebind(`
+		INSERT INTO node_join_tokens (id, token_hash, description, created_by, max_uses, use_count, labels, pool, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`)
+	_, err := s.db.ExecContext(ctx, query, t.ID, t.TokenHash, t.Description, t.CreatedBy, t.MaxUses, t.UseCount,
+		labels, t.Pool, t.ExpiresAt, t.CreatedAt)
+	return err
+}
+
+// ListNodeJoinTokens 列出节点加入令牌（按创建时间倒序）
+func (s *Store) ListNodeJoinTokens(ctx context.Context) ([]*model.NodeJoinToken, error) {
+	query := `SELECT ` + nodeJoinTokenColumns + ` FROM node_join_tokens ORDER BY created_at DESC`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("list node join tokens: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.NodeJoinToken
+	for rows.Next() {
+		t, err := scanNodeJoinToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
+
+// scanNodeJoinToken 辅助函数：从数据库行扫描 NodeJoinToken
+func scanNodeJoinToken(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.NodeJoinToken, error) {
+	t := &model.NodeJoinToken{}
+	var labelsJSON []byte
+	if err := scanner.Scan(&t.ID, &t.TokenHash, &t.Description, &t.CreatedBy, &t.MaxUses, &t.UseCount, &labelsJSON,
+		&t.Pool, &t.ExpiresAt, &t.UsedAt, &t.UsedByNode, &t.CreatedAt); err != nil {
+		return nil, err
+	}
+	if len(labelsJSON) > 0 && string(labelsJSON) != "null" {
+		json.Unmarshal(labelsJSON, &t.Labels)
+	}
+	return t, nil
+}
+
+// DeleteNodeJoinToken 删除（撤销）节点加入令牌
+func (s *Store) DeleteNodeJoinToken(ctx context.Context, id string) error {
+	query := s.rebind(`DELETE FROM node_join_tokens WHERE id = $1`)
+	_, err := s.db.ExecContext(ctx, query, id)
+	return err
+}
+
+// ConsumeNodeJoinToken 原子地为未用尽且未过期的令牌增加一次使用计数，令牌无效时返回 nil
+//
+// 以 use_count < max_uses 为条件更新，并发兑换时不会超过使用次数上限。
+func (s *Store) ConsumeNodeJoinToken(ctx context.Context, tokenHash, nodeID string, now time.Time) (*model.NodeJoinToken, error) {
+	query := s.rebind(`
+		UPDATE node_join_tokens SET used_at = $1, used_by_node = $2, use_count = use_count + 1
+		WHERE token_hash = $3 AND use_count < max_uses AND expires_at > $4
+	`)
+	res, err := s.db.ExecContext(ctx, query, now, nodeID, tokenHash, now)
+	if err != nil {
+		return nil, err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return nil, err
+	}
+
+	query = s.rebind(`SELECT ` + nodeJoinTokenColumns + ` FROM node_join_tokens WHERE token_hash = $1`)
+	t, err := scanNodeJoinToken(s.db.QueryRowContext(ctx, query, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+// CreateNodeJoinTokenUse 记录一次加入令牌使用
+func (s *Store) CreateNodeJoinTokenUse(ctx context.Context, u *model.NodeJoinTokenUse) error {
+	query := s.rebind(`
+		INSERT INTO node_join_token_uses (id, token_id, node_id, hostname, remote_addr, used_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`)
+	_, err := s.db.ExecContext(ctx, query, u.ID, u.TokenID, u.NodeID, u.Hostname, u.RemoteAddr, u.UsedAt)
+	return err
+}
+
+// ListNodeJoinTokenUses 列出加入令牌的使用记录（按使用时间倒序）
+func (s *Store) ListNodeJoinTokenUses(ctx context.Context, tokenID string) ([]*model.NodeJoinTokenUse, error) {
+	query := s.rebind(`
+		SELECT id, token_id, node_id, hostname, remote_addr, used_at
+		FROM node_join_token_uses WHERE token_id = $1 ORDER BY used_at DESC
+	`)
+	rows, err := s.db.QueryContext(ctx, query, tokenID)
+	if err != nil {
+		return nil, fmt.Errorf("list node join token uses: %w", err)
+	}
+	defer rows.Close()
+
+	var result []*model.NodeJoinTokenUse
+	for rows.Next() {
+		u := &model.NodeJoinTokenUse{}
+		if err := rows.Scan(&u.ID, &u.TokenID, &u.NodeID, &u.Hostname, &u.RemoteAddr, &u.UsedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, u)
+	}
+	return result, rows.Err()
+}
+
+// UpsertNodeCredential 写入节点专属凭证（节点重新加入时覆盖旧凭证）
+func (s *Store) UpsertNodeCredential(ctx context.Context, c *model.NodeCredential) error {
+	labels, _ := json.Marshal(c.Labels)
+	conflict := s.dialect.UpsertConflict("node_id", []string{
+		"token_hash = EXCLUDED.token_hash",
+		"cert_serial = EXCLUDED.cert_serial",
+		"cert_expires_at = EXCLUDED.cert_expires_at",
+		"join_token_id = EXCLUDED.join_token_id",
+		"labels = EXCLUDED.labels",
+		"created_at = EXCLUDED.created_at",
+	})
+	query := s.rebind(fmt.Sprintf(`
+		INSERT INTO node_credentials (node_id, token_hash, cert_serial, cert_expires_at, join_token_id, labels, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		%s
+	`, conflict))
+	_, err := s.db.ExecContext(ctx, query, c.NodeID, c.TokenHash, c.CertSerial, c.CertExpiresAt, c.JoinTokenID, labels, c.CreatedAt)
+	return err
+}
+
+// GetNodeCredential 获取节点专属凭证
+func (s *Store) GetNodeCredential(ctx context.Context, nodeID string) (*model.NodeCredential, error) {
+	return s.getNodeCredential(ctx, `node_id = $1`, nodeID)
+}
+
+// GetNodeCredentialByTokenHash 按 Token 哈希获取节点专属凭证
+func (s *Store) GetNodeCredentialByTokenHash(ctx context.Context, tokenHash string) (*model.NodeCredential, error) {
+	return s.getNodeCredential(ctx, `token_hash = $1`, tokenHash)
+}
+
+func (s *Store) getNodeCredential(ctx context.Context, where string, arg string) (*model.NodeCredential, error) {
+	query := s.rebind(`
+		SELECT node_id, token_hash, cert_serial, cert_expires_at, join_token_id, labels, created_at
+		FROM node_credentials WHERE ` + where)
+	c := &model.NodeCredential{}
+	var labelsJSON []byte
+	err := s.db.QueryRowContext(ctx, query, arg).Scan(&c.NodeID, &c.TokenHash, &c.CertSerial,
+		&c.CertExpiresAt, &c.JoinTokenID, &labelsJSON, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(labelsJSON) > 0 && string(labelsJSON) != "null" {
+		json.Unmarshal(labelsJSON, &c.Labels)
+	}
+	return c, nil
+}
+
+// DeleteNodeCredential 撤销节点专属凭证
+func (s *Store) DeleteNodeCredential(ctx context.Context, nodeID string) error {
+	query := s.rebind(`DELETE FROM node_credentials WHERE node_id = $1`)
+	_, err := s.db.ExecContext(ctx, query, nodeID)
+	return err
+}
@@ -111,6 +111,12 @@ func (s *Store) UpdateOperationStatus(ctx context.Context, id string, status mod
 	return err
 }
 
+// UpdateOperationConfig 更新 Operation 配置（敏感字段重新加密）
+func (s *Store) UpdateOperationConfig(ctx context.Context, id string, config json.RawMessage) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE operations SET config = $1, updated_at = $2 WHERE id = $3`), config, time.Now(), id)
+	return err
+}
+
 // === Action 操作 ===
 
 // CreateAction 创建 Action
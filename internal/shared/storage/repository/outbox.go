@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// CreateRunWithOutbox 在同一事务中写入 Run 与发件箱消息
+func (s *Store) CreateRunWithOutbox(ctx context.Context, run *model.Run, msgs ...*model.OutboxMessage) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := s.insertRun(ctx, tx, run); err != nil {
+		return err
+	}
+	query := s.rebind(`INSERT INTO outbox_messages (id, topic, payload, attempts, available_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`)
+	for _, m := range msgs {
+		if _, err := tx.ExecContext(ctx, query, m.ID, m.Topic, []byte(m.Payload), m.Attempts, m.AvailableAt, m.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ClaimOutboxMessages 认领已到期的未投递消息
+//
+// 逐条以 attempts 作为乐观锁推迟可用时间：其他实例已认领的消息更新行数为 0，跳过。
+func (s *Store) ClaimOutboxMessages(ctx context.Context, limit int, lease time.Duration) ([]*model.OutboxMessage, error) {
+	now := time.Now()
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT id, topic, payload, attempts, last_error, available_at, published_at, created_at
+		FROM outbox_messages
+		WHERE published_at IS NULL AND available_at <= $1
+		ORDER BY available_at ASC
+		LIMIT $2`), now, limit)
+	if err != nil {
+		return nil, err
+	}
+	var candidates []*model.OutboxMessage
+	for rows.Next() {
+		m := &model.OutboxMessage{}
+		var payload []byte
+		var lastError sql.NullString
+		if err := rows.Scan(&m.ID, &m.Topic, &payload, &m.Attempts, &lastError, &m.AvailableAt, &m.PublishedAt, &m.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		m.Payload = payload
+		m.LastError = lastError.String
+		candidates = append(candidates, m)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	claim := s.rebind(`UPDATE outbox_messages SET attempts = attempts + 1, available_at = $1
+		WHERE id = $2 AND published_at IS NULL AND attempts = $3`)
+	leaseUntil := now.Add(lease)
+	claimed := make([]*model.OutboxMessage, 0, len(candidates))
+	for _, m := range candidates {
+		res, err := s.db.ExecContext(ctx, claim, leaseUntil, m.ID, m.Attempts)
+		if err != nil {
+			return claimed, err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		m.Attempts++
+		m.AvailableAt = leaseUntil
+		claimed = append(claimed, m)
+	}
+	return claimed, nil
+}
+
+// MarkOutboxPublished 标记消息投递成功
+func (s *Store) MarkOutboxPublished(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE outbox_messages SET published_at = $1, last_error = NULL WHERE id = $2`), time.Now(), id)
+	return err
+}
+
+// MarkOutboxFailed 记录投递失败，retryAt 之后可再次认领
+func (s *Store) MarkOutboxFailed(ctx context.Context, id, errMsg string, retryAt time.Time) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE outbox_messages SET last_error = $1, available_at = $2
+		WHERE id = $3 AND published_at IS NULL`), errMsg, retryAt, id)
+	return err
+}
+
+// DeletePublishedOutbox 删除投递时间早于 before 的消息
+func (s *Store) DeletePublishedOutbox(ctx context.Context, before time.Time) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM outbox_messages WHERE published_at IS NOT NULL AND published_at < $1`), before)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,120 @@
+// Package repository Project 相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"agents-admin/internal/shared/model"
+)
+
+const projectColumns = `id, name, description, default_agent_type, allowed_agent_types, account_pool,
+	default_security, allowed_security_policies, overrides, result_publishing, retention, created_at, updated_at`
+
+// CreateProject 创建项目
+func (s *Store) CreateProject(ctx context.Context, p *model.Project) error {
+	agentTypesJSON, poolJSON, securityJSON, policiesJSON, overridesJSON, publishJSON, retentionJSON := marshalProjectFields(p)
+	query := s.rebind(`
+		INSERT INTO projects (` + projectColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		p.ID, p.Name, p.Description, p.DefaultAgentType, agentTypesJSON, poolJSON,
+		securityJSON, policiesJSON, overridesJSON, publishJSON, retentionJSON, p.CreatedAt, p.UpdatedAt)
+	return err
+}
+
+// GetProject 获取项目
+func (s *Store) GetProject(ctx context.Context, id string) (*model.Project, error) {
+	query := s.rebind(`SELECT ` + projectColumns + ` FROM projects WHERE id = $1`)
+	p, err := scanProject(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return p, err
+}
+
+// ListProjects 列出所有项目
+func (s *Store) ListProjects(ctx context.Context) ([]*model.Project, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+projectColumns+` FROM projects ORDER BY name ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	projects := []*model.Project{}
+	for rows.Next() {
+		p, err := scanProject(rows)
+		if err != nil {
+			return nil, err
+		}
+		projects = append(projects, p)
+	}
+	return projects, rows.Err()
+}
+
+// UpdateProject 更新项目
+func (s *Store) UpdateProject(ctx context.Context, p *model.Project) error {
+	agentTypesJSON, poolJSON, securityJSON, policiesJSON, overridesJSON, publishJSON, retentionJSON := marshalProjectFields(p)
+	query := s.rebind(`UPDATE projects SET name = $1, description = $2, default_agent_type = $3,
+			  allowed_agent_types = $4, account_pool = $5, default_security = $6,
+			  allowed_security_policies = $7, overrides = $8, result_publishing = $9,
+			  retention = $10, updated_at = $11 WHERE id = $12`)
+	_, err := s.db.ExecContext(ctx, query,
+		p.Name, p.Description, p.DefaultAgentType, agentTypesJSON, poolJSON,
+		securityJSON, policiesJSON, overridesJSON, publishJSON, retentionJSON, p.UpdatedAt, p.ID)
+	return err
+}
+
+// DeleteProject 删除项目
+func (s *Store) DeleteProject(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM projects WHERE id = $1`), id)
+	return err
+}
+
+// marshalProjectFields 序列化项目的 JSON 字段
+func marshalProjectFields(p *model.Project) (agentTypes, pool, security, policies, overrides, publish, retention []byte) {
+	agentTypes, _ = json.Marshal(p.AllowedAgentTypes)
+	pool, _ = json.Marshal(p.AccountPool)
+	security, _ = json.Marshal(p.DefaultSecurity)
+	policies, _ = json.Marshal(p.AllowedSecurityPolicies)
+	overrides, _ = json.Marshal(p.Overrides)
+	publish, _ = json.Marshal(p.ResultPublishing)
+	retention, _ = json.Marshal(p.Retention)
+	return
+}
+
+// scanProject 辅助函数：从数据库行扫描 Project
+func scanProject(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.Project, error) {
+	p := &model.Project{}
+	var agentTypesJSON, poolJSON, securityJSON, policiesJSON, overridesJSON, publishJSON, retentionJSON []byte
+	if err := scanner.Scan(&p.ID, &p.Name, &p.Description, &p.DefaultAgentType, &agentTypesJSON, &poolJSON,
+		&securityJSON, &policiesJSON, &overridesJSON, &publishJSON, &retentionJSON, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(agentTypesJSON) > 0 && string(agentTypesJSON) != "null" {
+		json.Unmarshal(agentTypesJSON, &p.AllowedAgentTypes)
+	}
+	if len(poolJSON) > 0 && string(poolJSON) != "null" {
+		json.Unmarshal(poolJSON, &p.AccountPool)
+	}
+	if len(securityJSON) > 0 && string(securityJSON) != "null" {
+		json.Unmarshal(securityJSON, &p.DefaultSecurity)
+	}
+	if len(policiesJSON) > 0 && string(policiesJSON) != "null" {
+		json.Unmarshal(policiesJSON, &p.AllowedSecurityPolicies)
+	}
+	if len(overridesJSON) > 0 && string(overridesJSON) != "null" {
+		json.Unmarshal(overridesJSON, &p.Overrides)
+	}
+	if len(publishJSON) > 0 && string(publishJSON) != "null" {
+		json.Unmarshal(publishJSON, &p.ResultPublishing)
+	}
+	if len(retentionJSON) > 0 && string(retentionJSON) != "null" {
+		json.Unmarshal(retentionJSON, &p.Retention)
+	}
+	return p, nil
+}
@@ -0,0 +1,138 @@
+// Package repository Region 相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const regionColumns = `id, display_name, description, fallback, fallback_regions, created_at, updated_at`
+
+// CreateRegion 创建区域
+func (s *Store) CreateRegion(ctx context.Context, r *model.Region) error {
+	fallbackJSON := marshalFallbackRegions(r)
+	query := s.rebind(`
+		INSERT INTO regions (` + regionColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		r.ID, r.DisplayName, r.Description, r.Fallback, fallbackJSON, r.CreatedAt, r.UpdatedAt)
+	return err
+}
+
+// GetRegion 获取区域
+func (s *Store) GetRegion(ctx context.Context, id string) (*model.Region, error) {
+	query := s.rebind(`SELECT ` + regionColumns + ` FROM regions WHERE id = $1`)
+	r, err := scanRegion(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return r, err
+}
+
+// ListRegions 列出所有区域
+func (s *Store) ListRegions(ctx context.Context) ([]*model.Region, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+regionColumns+` FROM regions ORDER BY id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	regions := []*model.Region{}
+	for rows.Next() {
+		r, err := scanRegion(rows)
+		if err != nil {
+			return nil, err
+		}
+		regions = append(regions, r)
+	}
+	return regions, rows.Err()
+}
+
+// UpdateRegion 更新区域
+func (s *Store) UpdateRegion(ctx context.Context, r *model.Region) error {
+	fallbackJSON := marshalFallbackRegions(r)
+	query := s.rebind(`UPDATE regions SET display_name = $1, description = $2, fallback = $3,
+			  fallback_regions = $4, updated_at = $5 WHERE id = $6`)
+	_, err := s.db.ExecContext(ctx, query,
+		r.DisplayName, r.Description, r.Fallback, fallbackJSON, r.UpdatedAt, r.ID)
+	return err
+}
+
+// DeleteRegion 删除区域
+func (s *Store) DeleteRegion(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM regions WHERE id = $1`), id)
+	return err
+}
+
+// CountQueuedRunsByRegion 按任务的 region 统计 queued 的 Run 数与最早排队时间
+//
+// 最早排队时间按区域单独查询（区域数量有限），避免聚合函数在不同驱动下返回的时间类型不一致。
+func (s *Store) CountQueuedRunsByRegion(ctx context.Context) ([]model.RegionQueueDepth, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT t.region, COUNT(*) FROM runs r JOIN tasks t ON t.id = r.task_id
+		WHERE r.status = $1 GROUP BY t.region ORDER BY t.region ASC
+	`), model.RunStatusQueued)
+	if err != nil {
+		return nil, err
+	}
+	depths := []model.RegionQueueDepth{}
+	for rows.Next() {
+		var d model.RegionQueueDepth
+		if err := rows.Scan(&d.Region, &d.QueuedRuns); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		depths = append(depths, d)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	oldestQuery := s.rebind(`
+		SELECT r.created_at FROM runs r JOIN tasks t ON t.id = r.task_id
+		WHERE r.status = $1 AND t.region = $2 ORDER BY r.created_at ASC LIMIT 1
+	`)
+	for i := range depths {
+		var oldest time.Time
+		err := s.db.QueryRowContext(ctx, oldestQuery, model.RunStatusQueued, depths[i].Region).Scan(&oldest)
+		if err == sql.ErrNoRows {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		depths[i].OldestQueuedAt = &oldest
+	}
+	return depths, nil
+}
+
+// marshalFallbackRegions 序列化区域的回退列表
+func marshalFallbackRegions(r *model.Region) []byte {
+	if r.FallbackRegions == nil {
+		return []byte("[]")
+	}
+	data, _ := json.Marshal(r.FallbackRegions)
+	return data
+}
+
+// scanRegion 辅助函数：从数据库行扫描 Region
+func scanRegion(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.Region, error) {
+	r := &model.Region{}
+	var fallbackJSON []byte
+	if err := scanner.Scan(&r.ID, &r.DisplayName, &r.Description, &r.Fallback, &fallbackJSON,
+		&r.CreatedAt, &r.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(fallbackJSON) > 0 && string(fallbackJSON) != "null" {
+		json.Unmarshal(fallbackJSON, &r.FallbackRegions)
+	}
+	return r, nil
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"agents-admin/internal/shared/model"
+)
+
+const registryItemColumns = `id, kind, item_id, source, upstream_version, upstream_digest, upstream_spec,
+	installed_version, pinned, synced_at, installed_at`
+
+// UpsertRegistryItem 写入注册表同步记录（已存在时整体覆盖）
+func (s *Store) UpsertRegistryItem(ctx context.Context, item *model.RegistryItem) error {
+	conflict := s.dialect.UpsertConflict("id", []string{
+		"kind = EXCLUDED.kind",
+		"item_id = EXCLUDED.item_id",
+		"source = EXCLUDED.source",
+		"upstream_version = EXCLUDED.upstream_version",
+		"upstream_digest = EXCLUDED.upstream_digest",
+		"upstream_spec = EXCLUDED.upstream_spec",
+		"installed_version = EXCLUDED.installed_version",
+		"pinned = EXCLUDED.pinned",
+		"synced_at = EXCLUDED.synced_at",
+		"installed_at = EXCLUDED.installed_at",
+	})
+	query := s.rebind(fmt.Sprintf(`
+		INSERT INTO registry_items (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		%s
+	`, registryItemColumns, conflict))
+	var spec []byte
+	if len(item.UpstreamSpec) > 0 {
+		spec = item.UpstreamSpec
+	}
+	_, err := s.db.ExecContext(ctx, query, item.ID, item.Kind, item.ItemID, item.Source, item.UpstreamVersion,
+		item.UpstreamDigest, spec, item.InstalledVersion, item.Pinned, item.SyncedAt, item.InstalledAt)
+	return err
+}
+
+// GetRegistryItem 获取注册表同步记录
+func (s *Store) GetRegistryItem(ctx context.Context, id string) (*model.RegistryItem, error) {
+	query := s.rebind(`SELECT ` + registryItemColumns + ` FROM registry_items WHERE id = $1`)
+	item, err := scanRegistryItem(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return item, err
+}
+
+// ListRegistryItems 列出注册表同步记录（按 ID 排序）
+func (s *Store) ListRegistryItems(ctx context.Context) ([]*model.RegistryItem, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+registryItemColumns+` FROM registry_items ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*model.RegistryItem{}
+	for rows.Next() {
+		item, err := scanRegistryItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
+
+// scanRegistryItem 辅助函数：从数据库行扫描注册表同步记录
+func scanRegistryItem(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.RegistryItem, error) {
+	item := &model.RegistryItem{}
+	var spec []byte
+	if err := scanner.Scan(&item.ID, &item.Kind, &item.ItemID, &item.Source, &item.UpstreamVersion,
+		&item.UpstreamDigest, &spec, &item.InstalledVersion, &item.Pinned, &item.SyncedAt, &item.InstalledAt); err != nil {
+		return nil, err
+	}
+	if len(spec) > 0 {
+		item.UpstreamSpec = spec
+	}
+	return item, nil
+}
@@ -0,0 +1,158 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"sync"
+	"time"
+
+	"agents-admin/internal/shared/storage/dbutil"
+	"agents-admin/internal/shared/storagetypes"
+)
+
+const (
+	// DefaultReplicaMaxLag 只读副本允许的默认最大复制延迟
+	DefaultReplicaMaxLag = 10 * time.Second
+
+	// replicaCheckInterval 副本健康与延迟检查间隔
+	replicaCheckInterval = 5 * time.Second
+
+	// replicaCheckTimeout 单次检查超时
+	replicaCheckTimeout = 2 * time.Second
+)
+
+// pgReplicaLagQuery PostgreSQL 副本复制延迟（秒）
+//
+// 已回放到接收位置时视为无延迟（主库空闲时 pg_last_xact_replay_timestamp 会持续变旧）；
+// 连接的是主库（不在恢复模式）时同样为 0。
+const pgReplicaLagQuery = `
+	SELECT CASE
+		WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+		ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0)
+	END`
+
+// replica 只读副本连接池及其健康状态
+type replica struct {
+	db      *sql.DB
+	dialect dbutil.Dialect
+	maxLag  time.Duration
+
+	mu     sync.RWMutex
+	status storagetypes.ReplicaStatus
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// SetReadReplica 配置只读副本
+//
+// 标记了 storagetypes.WithReadReplica 的读请求（任务列表、事件读取、监控聚合）路由到副本；
+// 副本不可达或复制延迟超过 maxLag（<= 0 时使用 DefaultReplicaMaxLag）时回退到主库，
+// 后台每 5 秒重新检查一次。副本连接随 Close 一起关闭。
+func (s *Store) SetReadReplica(db *sql.DB, maxLag time.Duration) {
+	if maxLag <= 0 {
+		maxLag = DefaultReplicaMaxLag
+	}
+	r := &replica{
+		db:      db,
+		dialect: s.dialect,
+		maxLag:  maxLag,
+		status:  storagetypes.ReplicaStatus{MaxLag: maxLag.String()},
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	r.check(context.Background())
+	go r.run()
+	s.replica = r
+}
+
+// ReplicaStatus 返回只读副本状态，未配置副本时返回 nil
+func (s *Store) ReplicaStatus() *storagetypes.ReplicaStatus {
+	if s.replica == nil {
+		return nil
+	}
+	s.replica.mu.RLock()
+	defer s.replica.mu.RUnlock()
+	status := s.replica.status
+	return &status
+}
+
+// reader 返回读查询使用的连接池：请求允许且副本可用时为副本，否则为主库
+func (s *Store) reader(ctx context.Context) *sql.DB {
+	if s.replica == nil || !storagetypes.ReadReplicaAllowed(ctx) {
+		return s.db
+	}
+	s.replica.mu.RLock()
+	usable := s.replica.status.Usable
+	s.replica.mu.RUnlock()
+	if !usable {
+		return s.db
+	}
+	return s.replica.db
+}
+
+func (r *replica) run() {
+	defer close(r.done)
+	ticker := time.NewTicker(replicaCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.stop:
+			return
+		case <-ticker.C:
+			r.check(context.Background())
+		}
+	}
+}
+
+// check 检查副本连通性与复制延迟，状态变化时记录日志
+func (r *replica) check(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, replicaCheckTimeout)
+	defer cancel()
+
+	lag, err := r.lag(ctx)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	prev := r.status
+	r.status.CheckedAt = time.Now()
+	r.status.LagSeconds = lag.Seconds()
+	r.status.Error = ""
+	switch {
+	case err != nil:
+		r.status.Usable = false
+		r.status.Error = err.Error()
+		if prev.Error == "" {
+			log.Printf("[storage.replica.unavailable] error=%v", err)
+		}
+	case lag > r.maxLag:
+		r.status.Usable = false
+		if prev.Usable || prev.CheckedAt.IsZero() {
+			log.Printf("[storage.replica.lagging] lag=%s max_lag=%s", lag.Round(time.Millisecond), r.maxLag)
+		}
+	default:
+		r.status.Usable = true
+		if !prev.Usable {
+			log.Printf("[storage.replica.usable] lag=%s", lag.Round(time.Millisecond))
+		}
+	}
+}
+
+// lag 查询复制延迟；PostgreSQL 以外的数据库只检查连通性
+func (r *replica) lag(ctx context.Context) (time.Duration, error) {
+	if r.dialect.DriverType() != dbutil.DriverPostgres {
+		return 0, r.db.PingContext(ctx)
+	}
+	var seconds float64
+	if err := r.db.QueryRowContext(ctx, pgReplicaLagQuery).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// close 停止后台检查并关闭副本连接
+func (r *replica) close() error {
+	close(r.stop)
+	<-r.done
+	return r.db.Close()
+}
@@ -4,26 +4,35 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"time"
 
 	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
 )
 
 // CreateRun 创建 Run
 func (s *Store) CreateRun(ctx context.Context, run *model.Run) error {
+	return s.insertRun(ctx, s.db, run)
+}
+
+// insertRun 写入 Run（db 可为事务）
+func (s *Store) insertRun(ctx context.Context, db interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}, run *model.Run) error {
 	query := s.rebind(`
-		INSERT INTO runs (id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO runs (id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`)
-	_, err := s.db.ExecContext(ctx, query,
+	_, err := db.ExecContext(ctx, query,
 		run.ID, run.TaskID, run.Status, run.NodeID, run.StartedAt, run.FinishedAt,
-		run.Snapshot, run.Error, run.CreatedAt, run.UpdatedAt)
+		run.Snapshot, run.Priority.OrDefault(), run.Error, run.CreatedAt, run.UpdatedAt)
 	return err
 }
 
 // GetRun 获取 Run
 func (s *Store) GetRun(ctx context.Context, id string) (*model.Run, error) {
-	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at 
+	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at 
 			  FROM runs WHERE id = $1`)
 	row := s.db.QueryRowContext(ctx, query, id)
 	run, err := scanRun(row)
@@ -41,7 +50,7 @@ func scanRun(scanner interface {
 	var snapshot *[]byte
 	err := scanner.Scan(
 		&run.ID, &run.TaskID, &run.Status, &run.NodeID, &run.StartedAt,
-		&run.FinishedAt, &snapshot, &run.Error, &run.CreatedAt, &run.UpdatedAt)
+		&run.FinishedAt, &snapshot, &run.Priority, &run.Error, &run.CreatedAt, &run.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
@@ -66,9 +75,9 @@ func scanRuns(rows *sql.Rows) ([]*model.Run, error) {
 
 // ListRunsByTask 列出任务的所有 Run
 func (s *Store) ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run, error) {
-	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at 
+	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at 
 			  FROM runs WHERE task_id = $1 ORDER BY created_at DESC`)
-	rows, err := s.db.QueryContext(ctx, query, taskID)
+	rows, err := s.reader(ctx).QueryContext(ctx, query, taskID)
 	if err != nil {
 		return nil, err
 	}
@@ -76,10 +85,49 @@ func (s *Store) ListRunsByTask(ctx context.Context, taskID string) ([]*model.Run
 	return scanRuns(rows)
 }
 
+// ListRuns 带过滤条件列出 Run（按 created_at DESC, id DESC 排序，支持游标分页）
+func (s *Store) ListRuns(ctx context.Context, filter storagetypes.RunFilter) ([]*model.Run, int, error) {
+	var q listQuery
+	q.in("status", filter.Statuses)
+	if filter.TaskID != "" {
+		q.add("task_id = ?", filter.TaskID)
+	}
+	if filter.NodeID != "" {
+		q.add("node_id = ?", filter.NodeID)
+	}
+	q.subquery("task_id", "SELECT id FROM tasks", s.labelConditions("labels", filter.Labels))
+	if !filter.Since.IsZero() {
+		q.add("created_at >= ?", filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		q.add("created_at <= ?", filter.Until)
+	}
+
+	total := -1
+	if !filter.SkipTotal {
+		countQuery := s.rebind(render("SELECT COUNT(*) FROM runs" + q.where()))
+		if err := s.db.QueryRowContext(ctx, countQuery, q.args...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
+	}
+
+	q.cursor(filter.Cursor)
+	page, args := pageArgs(q, filter.Cursor, filter.Limit, filter.Offset)
+	query := s.rebind(render(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at
+			  FROM runs` + q.where() + " ORDER BY created_at DESC, id DESC" + page))
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+	runs, err := scanRuns(rows)
+	return runs, total, err
+}
+
 // ListRunsByNode 列出分配给节点的活跃 Run
 func (s *Store) ListRunsByNode(ctx context.Context, nodeID string) ([]*model.Run, error) {
-	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at 
-			  FROM runs WHERE node_id = $1 AND status IN ('assigned', 'running') ORDER BY created_at ASC`)
+	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at 
+			  FROM runs WHERE node_id = $1 AND status IN ('assigned', 'running', 'paused') ORDER BY created_at ASC`)
 	rows, err := s.db.QueryContext(ctx, query, nodeID)
 	if err != nil {
 		return nil, err
@@ -95,11 +143,11 @@ func (s *Store) ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, e
 	}
 	var query string
 	if s.dialect.SupportsNullsLast() {
-		query = s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at
+		query = s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at
 			  FROM runs WHERE status IN ('assigned', 'running') ORDER BY started_at ASC ` + s.dialect.NullsLastClause() + `, created_at ASC LIMIT $1`)
 	} else {
 		// SQLite/MySQL: 用 CASE 模拟 NULLS LAST
-		query = s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at
+		query = s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at
 			  FROM runs WHERE status IN ('assigned', 'running') ORDER BY CASE WHEN started_at IS NULL THEN 1 ELSE 0 END, started_at ASC, created_at ASC LIMIT $1`)
 	}
 	rows, err := s.db.QueryContext(ctx, query, limit)
@@ -110,10 +158,13 @@ func (s *Store) ListRunningRuns(ctx context.Context, limit int) ([]*model.Run, e
 	return scanRuns(rows)
 }
 
-// ListQueuedRuns 列出待执行的 Run
+// priorityOrder 按调度优先级排序的 ORDER BY 表达式（high → normal → low）
+const priorityOrder = `CASE priority WHEN 'high' THEN 0 WHEN 'low' THEN 2 ELSE 1 END`
+
+// ListQueuedRuns 列出待执行的 Run（按优先级、创建时间排序）
 func (s *Store) ListQueuedRuns(ctx context.Context, limit int) ([]*model.Run, error) {
-	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at 
-			  FROM runs WHERE status = 'queued' ORDER BY created_at ASC LIMIT $1`)
+	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at 
+			  FROM runs WHERE status = 'queued' ORDER BY ` + priorityOrder + `, created_at ASC LIMIT $1`)
 	rows, err := s.db.QueryContext(ctx, query, limit)
 	if err != nil {
 		return nil, err
@@ -122,13 +173,13 @@ func (s *Store) ListQueuedRuns(ctx context.Context, limit int) ([]*model.Run, er
 	return scanRuns(rows)
 }
 
-// ListStaleQueuedRuns 列出"过期"的 queued 状态 Run
+// ListStaleQueuedRuns 列出"过期"的 queued 状态 Run（按优先级、创建时间排序）
 func (s *Store) ListStaleQueuedRuns(ctx context.Context, threshold time.Duration) ([]*model.Run, error) {
 	cutoff := time.Now().Add(-threshold)
-	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, error, created_at, updated_at 
+	query := s.rebind(`SELECT id, task_id, status, node_id, started_at, finished_at, snapshot, priority, error, created_at, updated_at 
 			  FROM runs 
 			  WHERE status = 'queued' AND created_at < $1 
-			  ORDER BY created_at ASC 
+			  ORDER BY ` + priorityOrder + `, created_at ASC 
 			  LIMIT 100`)
 	rows, err := s.db.QueryContext(ctx, query, cutoff)
 	if err != nil {
@@ -220,9 +271,75 @@ func (s *Store) UpdateRunError(ctx context.Context, id string, errMsg string) er
 	return err
 }
 
+// MarkRunTimeout 将仍处于 assigned/running 的 Run 标记为 timeout 并记录错误信息
+//
+// 条件更新避免覆盖节点已上报的终态；返回 false 表示 Run 已不再活跃。
+// 关联 Task 状态由调用方更新。
+func (s *Store) MarkRunTimeout(ctx context.Context, id string, errMsg string) (bool, error) {
+	now := time.Now()
+	query := s.rebind(`UPDATE runs SET status = 'timeout', error = $1, finished_at = $2, updated_at = $3
+			  WHERE id = $4 AND status IN ('assigned', 'running')`)
+	res, err := s.db.ExecContext(ctx, query, errMsg, now, now, id)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ReclaimRun 回收孤儿 Run：仍分配在 nodeID 且处于 assigned/running 时，
+// 重置为 queued（清空节点、开始时间与错误信息）或标记为 failed 并记录错误信息 errMsg
+//
+// 条件更新保证多个回收方（多个 API Server 实例、节点恢复上报）之间只有一方成功；
+// 返回 false 表示 Run 已不再由该节点执行。关联 Task 状态由调用方更新。
+func (s *Store) ReclaimRun(ctx context.Context, id, nodeID string, status model.RunStatus, errMsg string) (bool, error) {
+	now := time.Now()
+	var query string
+	var args []interface{}
+	switch status {
+	case model.RunStatusQueued:
+		query = s.rebind(`UPDATE runs SET status = 'queued', node_id = NULL, started_at = NULL, error = NULL, updated_at = $1
+			  WHERE id = $2 AND node_id = $3 AND status IN ('assigned', 'running')`)
+		args = []interface{}{now, id, nodeID}
+	case model.RunStatusFailed:
+		query = s.rebind(`UPDATE runs SET status = 'failed', error = $1, finished_at = $2, updated_at = $3
+			  WHERE id = $4 AND node_id = $5 AND status IN ('assigned', 'running')`)
+		args = []interface{}{errMsg, now, now, id, nodeID}
+	default:
+		return false, fmt.Errorf("unsupported reclaim status: %s", status)
+	}
+	res, err := s.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
 // DeleteRun 删除 Run
 func (s *Store) DeleteRun(ctx context.Context, id string) error {
 	query := s.rebind(`DELETE FROM runs WHERE id = $1`)
 	_, err := s.db.ExecContext(ctx, query, id)
 	return err
 }
+
+// CountRunsByStatus 按状态统计 created_at >= since 的 Run 数量（since 为零值时统计全部）
+func (s *Store) CountRunsByStatus(ctx context.Context, since time.Time) (map[model.RunStatus]int, error) {
+	query := s.rebind(`SELECT status, COUNT(*) FROM runs WHERE created_at >= $1 GROUP BY status`)
+	rows, err := s.db.QueryContext(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make(map[model.RunStatus]int)
+	for rows.Next() {
+		var status model.RunStatus
+		var n int
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, err
+		}
+		counts[status] = n
+	}
+	return counts, rows.Err()
+}
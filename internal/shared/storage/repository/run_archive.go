@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storagetypes"
+)
+
+const runArchiveColumns = `run_id, project_id, tier, archive_key, event_count, archive_bytes, summary, warm_at, cold_at, purged_at`
+
+// CreateRunArchive 创建 Run 归档记录
+func (s *Store) CreateRunArchive(ctx context.Context, a *model.RunArchive) error {
+	summary, _ := json.Marshal(a.Summary)
+	query := s.rebind(`
+		INSERT INTO run_archives (` + runArchiveColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`)
+	_, err := s.db.ExecContext(ctx, query, a.RunID, a.ProjectID, a.Tier, a.ArchiveKey, a.EventCount, a.ArchiveBytes,
+		summary, a.WarmAt, a.ColdAt, a.PurgedAt)
+	return err
+}
+
+// GetRunArchive 获取 Run 归档记录，未归档（hot）时返回 nil
+func (s *Store) GetRunArchive(ctx context.Context, runID string) (*model.RunArchive, error) {
+	query := s.rebind(`SELECT ` + runArchiveColumns + ` FROM run_archives WHERE run_id = $1`)
+	a, err := scanRunArchive(s.db.QueryRowContext(ctx, query, runID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+// UpdateRunArchive 更新 Run 归档记录的层级、大小与摘要
+func (s *Store) UpdateRunArchive(ctx context.Context, a *model.RunArchive) error {
+	summary, _ := json.Marshal(a.Summary)
+	query := s.rebind(`UPDATE run_archives SET tier = $1, archive_bytes = $2, summary = $3, cold_at = $4, purged_at = $5
+		WHERE run_id = $6`)
+	_, err := s.db.ExecContext(ctx, query, a.Tier, a.ArchiveBytes, summary, a.ColdAt, a.PurgedAt, a.RunID)
+	return err
+}
+
+// ListRunsForArchive 列出 finished_at 早于 filter.Before、尚未归档的终态 Run（按结束时间升序）
+func (s *Store) ListRunsForArchive(ctx context.Context, filter storagetypes.ArchiveRunFilter) ([]*model.Run, error) {
+	var q listQuery
+	q.add("r.status IN ('done', 'failed', 'cancelled', 'timeout')")
+	q.add("r.finished_at < ?", filter.Before)
+	q.add("NOT EXISTS (SELECT 1 FROM run_archives a WHERE a.run_id = r.id)")
+	if filter.ProjectID != "" {
+		var project listQuery
+		project.add("project_id = ?", filter.ProjectID)
+		q.subquery("r.task_id", "SELECT id FROM tasks", project)
+	}
+	if len(filter.ExcludeProjectIDs) > 0 {
+		args := make([]interface{}, len(filter.ExcludeProjectIDs))
+		for i, id := range filter.ExcludeProjectIDs {
+			args[i] = id
+		}
+		q.add("r.task_id NOT IN (SELECT id FROM tasks WHERE project_id IN ("+
+			strings.TrimSuffix(strings.Repeat("?, ", len(args)), ", ")+"))", args...)
+	}
+	query := s.rebind(render(`
+		SELECT r.id, r.task_id, r.status, r.node_id, r.started_at, r.finished_at, r.snapshot, r.priority, r.error,
+			r.created_at, r.updated_at
+		FROM runs r` + q.where() + `
+		ORDER BY r.finished_at ASC LIMIT ?`))
+	rows, err := s.db.QueryContext(ctx, query, append(q.args, filter.Limit)...)
+	if err != nil {
+		return nil, fmt.Errorf("list runs for archive: %w", err)
+	}
+	defer rows.Close()
+	return scanRuns(rows)
+}
+
+// ListRunArchives 列出指定层级中进入该层早于 before 的归档记录（按进入时间升序）
+func (s *Store) ListRunArchives(ctx context.Context, tier model.RunDataTier, before time.Time, limit int) ([]*model.RunArchive, error) {
+	query := s.rebind(`SELECT ` + runArchiveColumns + `
+		FROM run_archives WHERE tier = $1 AND warm_at < $2 ORDER BY warm_at ASC LIMIT $3`)
+	rows, err := s.db.QueryContext(ctx, query, tier, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list run archives: %w", err)
+	}
+	defer rows.Close()
+	return scanRunArchives(rows)
+}
+
+// ListExpiredRunArchives 列出项目中归档早于 before、归档对象尚未删除（warm/cold）的记录（按归档时间升序）
+func (s *Store) ListExpiredRunArchives(ctx context.Context, projectID string, before time.Time, limit int) ([]*model.RunArchive, error) {
+	query := s.rebind(`SELECT ` + runArchiveColumns + `
+		FROM run_archives WHERE project_id = $1 AND tier IN ('warm', 'cold') AND warm_at < $2
+		ORDER BY warm_at ASC LIMIT $3`)
+	rows, err := s.db.QueryContext(ctx, query, projectID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("list expired run archives: %w", err)
+	}
+	defer rows.Close()
+	return scanRunArchives(rows)
+}
+
+func scanRunArchives(rows *sql.Rows) ([]*model.RunArchive, error) {
+	var result []*model.RunArchive
+	for rows.Next() {
+		a, err := scanRunArchive(rows)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
+
+// DeleteEventsByRun 删除 Run 在数据库中的全部事件（连同保留的原文），返回删除的事件数量
+func (s *Store) DeleteEventsByRun(ctx context.Context, runID string) (int64, error) {
+	if _, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM event_originals WHERE run_id = $1`), runID); err != nil {
+		return 0, err
+	}
+	res, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM events WHERE run_id = $1`), runID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// ClearRunSnapshot 清除 Run 的任务快照（转入 cold 层时释放空间）
+func (s *Store) ClearRunSnapshot(ctx context.Context, runID string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE runs SET snapshot = NULL WHERE id = $1`), runID)
+	return err
+}
+
+// CountEvents 统计数据库中的事件总数
+func (s *Store) CountEvents(ctx context.Context) (int64, error) {
+	var n int64
+	err := s.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM events`).Scan(&n)
+	return n, err
+}
+
+// RunArchiveStats 按层级统计已归档的 Run
+func (s *Store) RunArchiveStats(ctx context.Context) ([]model.RunTierStats, error) {
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT tier, COUNT(*), COALESCE(SUM(event_count), 0), COALESCE(SUM(archive_bytes), 0)
+		FROM run_archives GROUP BY tier
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []model.RunTierStats
+	for rows.Next() {
+		var st model.RunTierStats
+		if err := rows.Scan(&st.Tier, &st.Runs, &st.Events, &st.ArchiveBytes); err != nil {
+			return nil, err
+		}
+		result = append(result, st)
+	}
+	return result, rows.Err()
+}
+
+func scanRunArchive(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.RunArchive, error) {
+	a := &model.RunArchive{}
+	var summary []byte
+	if err := scanner.Scan(&a.RunID, &a.ProjectID, &a.Tier, &a.ArchiveKey, &a.EventCount, &a.ArchiveBytes,
+		&summary, &a.WarmAt, &a.ColdAt, &a.PurgedAt); err != nil {
+		return nil, err
+	}
+	if len(summary) > 0 && string(summary) != "null" {
+		json.Unmarshal(summary, &a.Summary)
+	}
+	return a, nil
+}
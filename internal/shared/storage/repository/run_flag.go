@@ -0,0 +1,78 @@
+// Package repository 内容审核标记相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agents-admin/internal/shared/model"
+)
+
+// CreateRunFlags 批量写入内容审核标记
+func (s *Store) CreateRunFlags(ctx context.Context, flags []*model.RunFlag) error {
+	query := s.rebind(`
+		INSERT INTO run_flags (run_id, event_seq, detector, severity, count, aborted, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`)
+	for _, f := range flags {
+		if _, err := s.db.ExecContext(ctx, query,
+			f.RunID, f.EventSeq, f.Detector, f.Severity, f.Count, f.Aborted, f.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListRunFlags 列出 Run 的内容审核标记
+func (s *Store) ListRunFlags(ctx context.Context, runID string) ([]*model.RunFlag, error) {
+	query := s.rebind(`SELECT id, run_id, event_seq, detector, severity, count, aborted, created_at
+			  FROM run_flags WHERE run_id = $1 ORDER BY id ASC`)
+	rows, err := s.db.QueryContext(ctx, query, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	flags := []*model.RunFlag{}
+	for rows.Next() {
+		f := &model.RunFlag{}
+		if err := rows.Scan(&f.ID, &f.RunID, &f.EventSeq, &f.Detector, &f.Severity, &f.Count, &f.Aborted, &f.CreatedAt); err != nil {
+			return nil, err
+		}
+		flags = append(flags, f)
+	}
+	return flags, rows.Err()
+}
+
+// CreateEventOriginals 批量写入被掩码事件的原文，已存在的 (run_id, seq) 被忽略
+func (s *Store) CreateEventOriginals(ctx context.Context, originals []*model.EventOriginal) error {
+	query := s.rebind(`
+		INSERT INTO event_originals (run_id, seq, payload, raw, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`)
+	for _, o := range originals {
+		if _, err := s.db.ExecContext(ctx, query, o.RunID, o.Seq, o.Payload, o.Raw, o.CreatedAt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetEventOriginal 获取被掩码事件的原文，未保留时返回 nil
+func (s *Store) GetEventOriginal(ctx context.Context, runID string, seq int) (*model.EventOriginal, error) {
+	query := s.rebind(`SELECT run_id, seq, payload, raw, created_at FROM event_originals WHERE run_id = $1 AND seq = $2`)
+	o := &model.EventOriginal{}
+	var payload *[]byte
+	err := s.db.QueryRowContext(ctx, query, runID, seq).Scan(&o.RunID, &o.Seq, &payload, &o.Raw, &o.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if payload != nil {
+		o.Payload = *payload
+	}
+	return o, nil
+}
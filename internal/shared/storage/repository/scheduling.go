@@ -0,0 +1,79 @@
+// Package repository 调度决策审计相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"agents-admin/internal/shared/model"
+)
+
+// RecordSchedulingDecision 记录调度决策
+//
+// 与该 Run 最近一条决策的签名相同时只累加次数并刷新更新时间，否则新增一条记录。
+func (s *Store) RecordSchedulingDecision(ctx context.Context, d *model.SchedulingDecision) error {
+	if d.Signature == "" {
+		d.Signature = d.ComputeSignature()
+	}
+
+	var lastID int64
+	var lastSignature string
+	var attempts int
+	err := s.db.QueryRowContext(ctx, s.rebind(`
+		SELECT id, signature, attempts FROM scheduling_decisions WHERE run_id = $1 ORDER BY id DESC LIMIT 1
+	`), d.RunID).Scan(&lastID, &lastSignature, &attempts)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	if err == nil && lastSignature == d.Signature {
+		_, err := s.db.ExecContext(ctx, s.rebind(`
+			UPDATE scheduling_decisions SET attempts = attempts + 1, updated_at = $1 WHERE id = $2
+		`), d.UpdatedAt, lastID)
+		if err == nil {
+			d.ID = lastID
+			d.Attempts = attempts + 1
+		}
+		return err
+	}
+
+	candidates, _ := json.Marshal(d.Candidates)
+	if d.Attempts == 0 {
+		d.Attempts = 1
+	}
+	_, err = s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO scheduling_decisions (run_id, outcome, strategy, reason, node_id, candidates, signature, attempts,
+			created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`), d.RunID, d.Outcome, d.Strategy, d.Reason, d.NodeID, candidates, d.Signature, d.Attempts, d.CreatedAt, d.UpdatedAt)
+	return err
+}
+
+// ListSchedulingDecisions 列出 Run 最近的调度决策（按时间倒序）
+func (s *Store) ListSchedulingDecisions(ctx context.Context, runID string, limit int) ([]*model.SchedulingDecision, error) {
+	query := s.rebind(`
+		SELECT id, run_id, outcome, strategy, reason, node_id, candidates, signature, attempts, created_at, updated_at
+		FROM scheduling_decisions WHERE run_id = $1 ORDER BY id DESC LIMIT $2
+	`)
+	rows, err := s.db.QueryContext(ctx, query, runID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	decisions := []*model.SchedulingDecision{}
+	for rows.Next() {
+		d := &model.SchedulingDecision{}
+		var candidates []byte
+		if err := rows.Scan(&d.ID, &d.RunID, &d.Outcome, &d.Strategy, &d.Reason, &d.NodeID, &candidates,
+			&d.Signature, &d.Attempts, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if len(candidates) > 0 && string(candidates) != "null" {
+			json.Unmarshal(candidates, &d.Candidates)
+		}
+		decisions = append(decisions, d)
+	}
+	return decisions, rows.Err()
+}
@@ -0,0 +1,84 @@
+// Package repository Secret 相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"agents-admin/internal/shared/model"
+)
+
+const secretColumns = `id, name, description, encrypted_value, created_at, updated_at`
+
+// CreateSecret 创建密钥
+func (s *Store) CreateSecret(ctx context.Context, secret *model.Secret) error {
+	query := s.rebind(`
+		INSERT INTO secrets (` + secretColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		secret.ID, secret.Name, secret.Description,
+		secret.EncryptedValue, secret.CreatedAt, secret.UpdatedAt)
+	return err
+}
+
+// GetSecret 按 ID 获取密钥
+func (s *Store) GetSecret(ctx context.Context, id string) (*model.Secret, error) {
+	query := s.rebind(`SELECT ` + secretColumns + ` FROM secrets WHERE id = $1`)
+	return s.scanSecret(s.db.QueryRowContext(ctx, query, id))
+}
+
+// GetSecretByName 按名称获取密钥（Task.Secrets 解析）
+func (s *Store) GetSecretByName(ctx context.Context, name string) (*model.Secret, error) {
+	query := s.rebind(`SELECT ` + secretColumns + ` FROM secrets WHERE name = $1`)
+	return s.scanSecret(s.db.QueryRowContext(ctx, query, name))
+}
+
+// ListSecrets 列出所有密钥
+func (s *Store) ListSecrets(ctx context.Context) ([]*model.Secret, error) {
+	query := `SELECT ` + secretColumns + ` FROM secrets ORDER BY name ASC`
+	rows, err := s.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	secrets := []*model.Secret{}
+	for rows.Next() {
+		sec := &model.Secret{}
+		if err := rows.Scan(&sec.ID, &sec.Name, &sec.Description,
+			&sec.EncryptedValue, &sec.CreatedAt, &sec.UpdatedAt); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, sec)
+	}
+	return secrets, rows.Err()
+}
+
+// UpdateSecret 更新密钥
+func (s *Store) UpdateSecret(ctx context.Context, secret *model.Secret) error {
+	query := s.rebind(`UPDATE secrets SET name = $1, description = $2, encrypted_value = $3,
+			  updated_at = $4 WHERE id = $5`)
+	_, err := s.db.ExecContext(ctx, query,
+		secret.Name, secret.Description, secret.EncryptedValue, secret.UpdatedAt, secret.ID)
+	return err
+}
+
+// DeleteSecret 删除密钥
+func (s *Store) DeleteSecret(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM secrets WHERE id = $1`), id)
+	return err
+}
+
+func (s *Store) scanSecret(row *sql.Row) (*model.Secret, error) {
+	sec := &model.Secret{}
+	err := row.Scan(&sec.ID, &sec.Name, &sec.Description,
+		&sec.EncryptedValue, &sec.CreatedAt, &sec.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sec, nil
+}
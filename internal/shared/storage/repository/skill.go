@@ -106,3 +106,52 @@ func (s *Store) DeleteSkill(ctx context.Context, id string) error {
 	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM skills WHERE id = $1`), id)
 	return err
 }
+
+// CreateSkillBundle 记录技能包版本
+func (s *Store) CreateSkillBundle(ctx context.Context, bundle *model.SkillBundle) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO skill_bundles (skill_id, version, name, description, digest, size, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`), bundle.SkillID, bundle.Version, bundle.Name, bundle.Description, bundle.Digest, bundle.Size, bundle.CreatedAt)
+	return err
+}
+
+// GetSkillBundle 获取技能包的指定版本
+func (s *Store) GetSkillBundle(ctx context.Context, skillID, version string) (*model.SkillBundle, error) {
+	query := s.rebind(`SELECT skill_id, version, name, description, digest, size, created_at
+			  FROM skill_bundles WHERE skill_id = $1 AND version = $2`)
+	b := &model.SkillBundle{}
+	var description sql.NullString
+	err := s.db.QueryRowContext(ctx, query, skillID, version).Scan(
+		&b.SkillID, &b.Version, &b.Name, &description, &b.Digest, &b.Size, &b.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	b.Description = description.String
+	return b, nil
+}
+
+// ListSkillBundles 列出技能包的所有版本（按上传时间倒序）
+func (s *Store) ListSkillBundles(ctx context.Context, skillID string) ([]*model.SkillBundle, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`SELECT skill_id, version, name, description, digest, size, created_at
+			  FROM skill_bundles WHERE skill_id = $1 ORDER BY created_at DESC`), skillID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bundles []*model.SkillBundle
+	for rows.Next() {
+		b := &model.SkillBundle{}
+		var description sql.NullString
+		if err := rows.Scan(&b.SkillID, &b.Version, &b.Name, &description, &b.Digest, &b.Size, &b.CreatedAt); err != nil {
+			return nil, err
+		}
+		b.Description = description.String
+		bundles = append(bundles, b)
+	}
+	return bundles, rows.Err()
+}
@@ -5,8 +5,10 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 
 	"agents-admin/internal/shared/storage/dbutil"
 )
@@ -16,6 +18,10 @@ import (
 type Store struct {
 	db      *sql.DB
 	dialect dbutil.Dialect
+	replica *replica // 只读副本（可选，见 SetReadReplica）
+
+	// compressThreshold 事件 payload 与原始输出合计超过该字节数时压缩存储（0 表示不压缩，见 SetEventCompression）
+	compressThreshold int
 }
 
 // NewStore 创建通用存储
@@ -23,16 +29,51 @@ func NewStore(db *sql.DB, dialect dbutil.Dialect) *Store {
 	return &Store{db: db, dialect: dialect}
 }
 
-// Close 关闭数据库连接
+// Close 关闭数据库连接（含只读副本）
 func (s *Store) Close() error {
+	if s.replica != nil {
+		s.replica.close()
+	}
 	return s.db.Close()
 }
 
+// PoolStats 返回连接池统计，键为 primary / replica（未配置副本时只有 primary）
+func (s *Store) PoolStats() map[string]sql.DBStats {
+	stats := map[string]sql.DBStats{"primary": s.db.Stats()}
+	if s.replica != nil {
+		stats["replica"] = s.replica.db.Stats()
+	}
+	return stats
+}
+
 // DB 返回底层数据库连接（仅用于测试）
 func (s *Store) DB() *sql.DB {
 	return s.db
 }
 
+// Ping 检查数据库连接
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// DatabaseSize 返回数据库占用的存储空间（字节）
+func (s *Store) DatabaseSize(ctx context.Context) (int64, error) {
+	var query string
+	switch s.dialect.DriverType() {
+	case dbutil.DriverPostgres:
+		query = `SELECT pg_database_size(current_database())`
+	case dbutil.DriverSQLite:
+		query = `SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()`
+	case dbutil.DriverMySQL:
+		query = `SELECT COALESCE(SUM(data_length + index_length), 0) FROM information_schema.tables WHERE table_schema = DATABASE()`
+	default:
+		return 0, fmt.Errorf("database size not supported for driver %s", s.dialect.DriverType())
+	}
+	var size int64
+	err := s.db.QueryRowContext(ctx, query).Scan(&size)
+	return size, err
+}
+
 // Dialect 返回当前方言
 func (s *Store) Dialect() dbutil.Dialect {
 	return s.dialect
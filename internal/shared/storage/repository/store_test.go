@@ -8,12 +8,16 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"agents-admin/internal/shared/model"
 	"agents-admin/internal/shared/storage/dbutil"
+	mysqldriver "agents-admin/internal/shared/storage/driver/mysql"
 	sqlitedriver "agents-admin/internal/shared/storage/driver/sqlite"
+	"agents-admin/internal/shared/storagetypes"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -54,6 +58,86 @@ func TestRebind(t *testing.T) {
 		d.Rebind("UPDATE t SET status = $1::varchar WHERE id = $2"))
 }
 
+func TestMySQLDialect(t *testing.T) {
+	d := mysqldriver.NewDialect()
+	assert.Equal(t, dbutil.DriverMySQL, d.DriverType())
+	assert.True(t, d.SupportsRecursiveCTE())
+	assert.Equal(t, "SELECT id FROM tasks WHERE name LIKE ? AND status = ?",
+		d.Rebind("SELECT id FROM tasks WHERE name ILIKE $1 AND status = $2::varchar"))
+	// ON CONFLICT DO NOTHING 改写为 INSERT IGNORE
+	assert.Equal(t, "INSERT IGNORE INTO events (run_id, seq) VALUES (?, ?)",
+		d.Rebind(`INSERT INTO events (run_id, seq) VALUES ($1, $2)
+			ON CONFLICT (run_id, seq) DO NOTHING`))
+	assert.Equal(t, "INSERT IGNORE INTO events (run_id, seq, node_seq) VALUES (?, ?, ?)",
+		d.Rebind(`INSERT INTO events (run_id, seq, node_seq) VALUES ($1, $2, $3)
+			ON CONFLICT DO NOTHING`))
+	// EXCLUDED.col 改写为 VALUES(col)
+	assert.Equal(t, "ON DUPLICATE KEY UPDATE status = VALUES(status), cost_usd = run_usage.cost_usd + VALUES(cost_usd)",
+		d.UpsertConflict("id", []string{"status = EXCLUDED.status", "cost_usd = run_usage.cost_usd + EXCLUDED.cost_usd"}))
+}
+
+func TestPingAndDatabaseSize(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	require.NoError(t, s.Ping(ctx))
+
+	size, err := s.DatabaseSize(ctx)
+	require.NoError(t, err)
+	assert.Greater(t, size, int64(0))
+}
+
+func TestReadReplica(t *testing.T) {
+	s := newTestStore(t)
+	replica := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-primary", Name: "primary", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, replica.CreateTask(ctx, &model.Task{ID: "task-replica", Name: "replica", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+
+	assert.Nil(t, s.ReplicaStatus())
+	s.SetReadReplica(replica.DB(), 0)
+	status := s.ReplicaStatus()
+	require.NotNil(t, status)
+	assert.True(t, status.Usable)
+	assert.Equal(t, DefaultReplicaMaxLag.String(), status.MaxLag)
+
+	listIDs := func(ctx context.Context) []string {
+		tasks, err := s.ListTasks(ctx, "", 10, 0)
+		require.NoError(t, err)
+		var ids []string
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		return ids
+	}
+	assert.Equal(t, []string{"task-primary"}, listIDs(ctx), "reads go to the primary unless the caller opts in")
+	assert.Equal(t, []string{"task-replica"}, listIDs(storagetypes.WithReadReplica(ctx)))
+
+	// 副本不可用时回退主库
+	replica.DB().Close()
+	s.replica.check(ctx)
+	status = s.ReplicaStatus()
+	assert.False(t, status.Usable)
+	assert.NotEmpty(t, status.Error)
+	assert.Equal(t, []string{"task-primary"}, listIDs(storagetypes.WithReadReplica(ctx)))
+}
+
+func TestPoolStats(t *testing.T) {
+	s := newTestStore(t)
+	dbutil.PoolConfig{MaxOpenConns: 8, MaxIdleConns: 20}.Apply(s.DB())
+
+	stats := s.PoolStats()
+	require.Len(t, stats, 1)
+	assert.Equal(t, 8, stats["primary"].MaxOpenConnections)
+
+	replica := newTestStore(t)
+	dbutil.PoolConfig{}.Apply(replica.DB())
+	s.SetReadReplica(replica.DB(), 0)
+	stats = s.PoolStats()
+	require.Contains(t, stats, "replica")
+	assert.Equal(t, dbutil.DefaultMaxOpenConns, stats["replica"].MaxOpenConnections)
+}
+
 // ============================================================================
 // Task 测试
 // ============================================================================
@@ -124,7 +208,7 @@ func TestTaskTree(t *testing.T) {
 
 	parent := &model.Task{ID: "root", Name: "Root", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}
 	child1 := &model.Task{ID: "child-1", ParentID: strPtr("root"), Name: "Child 1", Status: model.TaskStatusPending, Type: "general", CreatedAt: now.Add(time.Second), UpdatedAt: now}
-	child2 := &model.Task{ID: "child-2", ParentID: strPtr("root"), Name: "Child 2", Status: model.TaskStatusPending, Type: "general", CreatedAt: now.Add(2 * time.Second), UpdatedAt: now}
+	child2 := &model.Task{ID: "child-2", ParentID: strPtr("root"), SpawnedByRunID: strPtr("run-root"), Name: "Child 2", Status: model.TaskStatusPending, Type: "general", CreatedAt: now.Add(2 * time.Second), UpdatedAt: now}
 
 	require.NoError(t, s.CreateTask(ctx, parent))
 	require.NoError(t, s.CreateTask(ctx, child1))
@@ -134,6 +218,9 @@ func TestTaskTree(t *testing.T) {
 	subs, err := s.ListSubTasks(ctx, "root")
 	require.NoError(t, err)
 	assert.Len(t, subs, 2)
+	assert.Nil(t, subs[0].SpawnedByRunID)
+	require.NotNil(t, subs[1].SpawnedByRunID)
+	assert.Equal(t, "run-root", *subs[1].SpawnedByRunID)
 
 	// GetTaskTree (recursive CTE)
 	tree, err := s.GetTaskTree(ctx, "root")
@@ -142,6 +229,52 @@ func TestTaskTree(t *testing.T) {
 	assert.Equal(t, "root", tree[0].ID)
 }
 
+func TestTaskHooksRoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	hooks := &model.LifecycleHooks{
+		PreRun:    []model.HookSpec{{Name: "install", Script: "npm ci", TimeoutSeconds: 60}},
+		OnFailure: []model.HookSpec{{Name: "notify", SkillID: "skill-1", ContinueOnError: true}},
+	}
+	task := &model.Task{ID: "task-hooks", Name: "Hooks", Status: model.TaskStatusPending, Type: "general", Hooks: hooks, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+
+	got, err := s.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hooks, got.Hooks)
+
+	tmpl := &model.TaskTemplate{ID: "tmpl-hooks", Name: "Hooks", Type: "general", DefaultHooks: hooks, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTaskTemplate(ctx, tmpl))
+	gotTmpl, err := s.GetTaskTemplate(ctx, tmpl.ID)
+	require.NoError(t, err)
+	assert.Equal(t, hooks, gotTmpl.DefaultHooks)
+}
+
+func TestTaskSchedulingRoundTrip(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	scheduling := &model.TaskScheduling{
+		Affinity:     &model.TaskAffinity{Siblings: true, Mode: model.SchedulingModeRequired},
+		AntiAffinity: &model.TaskAntiAffinity{},
+		Spread:       &model.SpreadConstraint{LabelKey: "zone", MaxSkew: 2},
+	}
+	task := &model.Task{ID: "task-sched", Name: "Sched", Status: model.TaskStatusPending, Type: "general", Scheduling: scheduling, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-plain", Name: "Plain", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+
+	got, err := s.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, scheduling, got.Scheduling)
+
+	plain, err := s.GetTask(ctx, "task-plain")
+	require.NoError(t, err)
+	assert.Nil(t, plain.Scheduling)
+}
+
 // ============================================================================
 // Run 测试
 // ============================================================================
@@ -219,6 +352,425 @@ func TestRunCRUD(t *testing.T) {
 	assert.Nil(t, got)
 }
 
+func TestOutbox(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-ob", Name: "t", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+
+	msg := &model.OutboxMessage{ID: "outbox-1", Topic: model.OutboxTopicScheduleRun, Payload: json.RawMessage(`{"run_id":"run-ob-1"}`),
+		AvailableAt: now.Add(-time.Second), CreatedAt: now}
+	run := &model.Run{ID: "run-ob-1", TaskID: "task-ob", Status: model.RunStatusQueued, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateRunWithOutbox(ctx, run, msg))
+
+	// Run 写入失败时消息一并回滚
+	dup := &model.OutboxMessage{ID: "outbox-2", Topic: model.OutboxTopicScheduleRun, Payload: json.RawMessage(`{}`), AvailableAt: now, CreatedAt: now}
+	require.Error(t, s.CreateRunWithOutbox(ctx, run, dup))
+
+	claimed, err := s.ClaimOutboxMessages(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, "outbox-1", claimed[0].ID)
+	assert.Equal(t, 1, claimed[0].Attempts)
+	assert.JSONEq(t, `{"run_id":"run-ob-1"}`, string(claimed[0].Payload))
+
+	// 租期内不会被再次认领
+	claimed, err = s.ClaimOutboxMessages(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	assert.Empty(t, claimed)
+
+	require.NoError(t, s.MarkOutboxFailed(ctx, "outbox-1", "redis down", time.Now().Add(-time.Second)))
+	claimed, err = s.ClaimOutboxMessages(ctx, 10, time.Minute)
+	require.NoError(t, err)
+	require.Len(t, claimed, 1)
+	assert.Equal(t, 2, claimed[0].Attempts)
+	assert.Equal(t, "redis down", claimed[0].LastError)
+
+	require.NoError(t, s.MarkOutboxPublished(ctx, "outbox-1"))
+	require.NoError(t, s.MarkOutboxFailed(ctx, "outbox-1", "late", time.Now().Add(-time.Second)))
+	claimed, err = s.ClaimOutboxMessages(ctx, 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, claimed, "published messages are never claimed again")
+
+	n, err := s.DeletePublishedOutbox(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, n)
+	n, err = s.DeletePublishedOutbox(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestIdempotencyKeys(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now()
+	claim := &model.IdempotencyKey{Scope: "user-1 POST /api/v1/tasks", Key: "ci-42", RequestHash: "h1", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+
+	existing, err := s.ClaimIdempotencyKey(ctx, claim)
+	require.NoError(t, err)
+	assert.Nil(t, existing, "first claim wins")
+
+	existing, err = s.ClaimIdempotencyKey(ctx, claim)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.False(t, existing.Completed(), "claimed key is in progress until completed")
+
+	require.NoError(t, s.CompleteIdempotencyKey(ctx, claim.Scope, claim.Key, "task-1", 201, []byte(`{"id":"task-1"}`)))
+	existing, err = s.ClaimIdempotencyKey(ctx, claim)
+	require.NoError(t, err)
+	require.NotNil(t, existing)
+	assert.Equal(t, "task-1", existing.ResourceID)
+	assert.Equal(t, 201, existing.StatusCode)
+	assert.Equal(t, "h1", existing.RequestHash)
+	assert.JSONEq(t, `{"id":"task-1"}`, string(existing.Response))
+
+	// 其他作用域的同名键互不影响
+	other := *claim
+	other.Scope = "user-2 POST /api/v1/tasks"
+	existing, err = s.ClaimIdempotencyKey(ctx, &other)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// 释放后可以重新认领
+	require.NoError(t, s.DeleteIdempotencyKey(ctx, other.Scope, other.Key))
+	existing, err = s.ClaimIdempotencyKey(ctx, &other)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	// 过期的键视为新请求
+	expired := &model.IdempotencyKey{Scope: claim.Scope, Key: "old", RequestHash: "h0", CreatedAt: now.Add(-2 * time.Hour), ExpiresAt: now.Add(-time.Hour)}
+	_, err = s.ClaimIdempotencyKey(ctx, expired)
+	require.NoError(t, err)
+	renewed := &model.IdempotencyKey{Scope: claim.Scope, Key: "old", RequestHash: "h2", CreatedAt: now, ExpiresAt: now.Add(time.Hour)}
+	existing, err = s.ClaimIdempotencyKey(ctx, renewed)
+	require.NoError(t, err)
+	assert.Nil(t, existing)
+
+	n, err := s.DeleteExpiredIdempotencyKeys(ctx, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+}
+
+func TestListTasksAndRunsWithFilter(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	base := time.Now().Truncate(time.Second)
+
+	// 5 个任务，两两共享 created_at，验证 (created_at, id) 游标不丢不重
+	for i, id := range []string{"task-a", "task-b", "task-c", "task-d", "task-e"} {
+		created := base.Add(time.Duration(i/2) * time.Minute)
+		labels := map[string]string{"env": "prod"}
+		status := model.TaskStatusCompleted
+		if i%2 == 1 {
+			labels = map[string]string{"env": "dev", "team": "infra"}
+			status = model.TaskStatusInProgress
+		}
+		require.NoError(t, s.CreateTask(ctx, &model.Task{ID: id, Name: id, Status: status, Type: "general", Labels: labels, CreatedAt: created, UpdatedAt: created}))
+		run := &model.Run{ID: "run-" + id[5:], TaskID: id, Status: model.RunStatusDone, CreatedAt: created, UpdatedAt: created}
+		if i < 2 {
+			run.NodeID = strPtr("node-1")
+		}
+		require.NoError(t, s.CreateRun(ctx, run))
+	}
+
+	// 游标翻页
+	var ids []string
+	filter := storagetypes.TaskFilter{Limit: 2, SkipTotal: true}
+	for {
+		tasks, total, err := s.ListTasksWithFilter(ctx, filter)
+		require.NoError(t, err)
+		assert.Equal(t, -1, total)
+		for _, task := range tasks {
+			ids = append(ids, task.ID)
+		}
+		if len(tasks) < filter.Limit {
+			break
+		}
+		last := tasks[len(tasks)-1]
+		filter.Cursor = &storagetypes.PageCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	assert.Equal(t, []string{"task-e", "task-d", "task-c", "task-b", "task-a"}, ids)
+
+	// 游标字符串往返
+	cursor, err := storagetypes.ParsePageCursor(storagetypes.PageCursor{CreatedAt: base, ID: "task-b"}.Encode())
+	require.NoError(t, err)
+	tasks, _, err := s.ListTasksWithFilter(ctx, storagetypes.TaskFilter{Cursor: cursor, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "task-a", tasks[0].ID)
+
+	// 状态集合 + 标签选择器
+	labels, err := storagetypes.ParseLabelSelector("env!=prod,team")
+	require.NoError(t, err)
+	tasks, total, err := s.ListTasksWithFilter(ctx, storagetypes.TaskFilter{Statuses: []string{"in_progress", "failed"}, Labels: labels, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, "task-d", tasks[0].ID)
+
+	// 节点
+	tasks, total, err = s.ListTasksWithFilter(ctx, storagetypes.TaskFilter{NodeID: "node-1", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, tasks, 2)
+
+	// Run：按任务标签 + 时间范围
+	labels, _ = storagetypes.ParseLabelSelector("env=prod")
+	runs, total, err := s.ListRuns(ctx, storagetypes.RunFilter{Labels: labels, Since: base.Add(time.Minute), Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "run-e", runs[0].ID)
+	assert.Equal(t, "run-c", runs[1].ID)
+
+	// Run：节点 + 状态 + 游标
+	runs, _, err = s.ListRuns(ctx, storagetypes.RunFilter{NodeID: "node-1", Statuses: []string{"done"}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "run-b", runs[0].ID)
+	runs, _, err = s.ListRuns(ctx, storagetypes.RunFilter{NodeID: "node-1", Cursor: &storagetypes.PageCursor{CreatedAt: runs[0].CreatedAt, ID: runs[0].ID}, Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "run-a", runs[0].ID)
+}
+
+func TestCountRunsByStatus(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	task := &model.Task{ID: "task-c1", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	runs := []*model.Run{
+		{ID: "run-c1", Status: model.RunStatusDone, CreatedAt: now.Add(-48 * time.Hour)},
+		{ID: "run-c2", Status: model.RunStatusDone, CreatedAt: now.Add(-time.Hour)},
+		{ID: "run-c3", Status: model.RunStatusFailed, CreatedAt: now.Add(-time.Hour)},
+		{ID: "run-c4", Status: model.RunStatusQueued, CreatedAt: now},
+	}
+	for _, r := range runs {
+		r.TaskID, r.UpdatedAt = "task-c1", now
+		require.NoError(t, s.CreateRun(ctx, r))
+	}
+
+	all, err := s.CountRunsByStatus(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, map[model.RunStatus]int{model.RunStatusDone: 2, model.RunStatusFailed: 1, model.RunStatusQueued: 1}, all)
+
+	recent, err := s.CountRunsByStatus(ctx, now.Add(-24*time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, 1, recent[model.RunStatusDone])
+	assert.Equal(t, 1, recent[model.RunStatusFailed])
+}
+
+func TestMarkRunTimeout(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	task := &model.Task{ID: "task-to", Name: "T", Status: model.TaskStatusInProgress, Type: "general", TimeoutSeconds: 600, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	gotTask, err := s.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 600, gotTask.TimeoutSeconds)
+
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-to1", TaskID: "task-to", Status: model.RunStatusRunning, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-to2", TaskID: "task-to", Status: model.RunStatusDone, CreatedAt: now, UpdatedAt: now}))
+
+	ok, err := s.MarkRunTimeout(ctx, "run-to1", "run exceeded timeout of 10m0s")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, _ := s.GetRun(ctx, "run-to1")
+	assert.Equal(t, model.RunStatusTimeout, got.Status)
+	require.NotNil(t, got.Error)
+	assert.Equal(t, "run exceeded timeout of 10m0s", *got.Error)
+	assert.NotNil(t, got.FinishedAt)
+
+	// 已到达终态的 Run 不会被覆盖
+	ok, err = s.MarkRunTimeout(ctx, "run-to2", "late")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	got, _ = s.GetRun(ctx, "run-to2")
+	assert.Equal(t, model.RunStatusDone, got.Status)
+}
+
+func TestRunFlags(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-rf", Name: "T", Status: model.TaskStatusInProgress, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-rf", TaskID: "task-rf", Status: model.RunStatusRunning, CreatedAt: now, UpdatedAt: now}))
+
+	require.NoError(t, s.CreateRunFlags(ctx, []*model.RunFlag{
+		{RunID: "run-rf", EventSeq: 2, Detector: "email", Severity: model.ModerationSeverityLow, Count: 2, CreatedAt: now},
+		{RunID: "run-rf", EventSeq: 5, Detector: "api_key", Severity: model.ModerationSeverityCritical, Count: 1, Aborted: true, CreatedAt: now},
+	}))
+	require.NoError(t, s.CreateRunFlags(ctx, nil))
+
+	flags, err := s.ListRunFlags(ctx, "run-rf")
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	assert.Equal(t, "email", flags[0].Detector)
+	assert.Equal(t, 2, flags[0].Count)
+	assert.False(t, flags[0].Aborted)
+	assert.Equal(t, model.ModerationSeverityCritical, flags[1].Severity)
+	assert.True(t, flags[1].Aborted)
+	assert.NotZero(t, flags[1].ID)
+
+	flags, err = s.ListRunFlags(ctx, "run-none")
+	require.NoError(t, err)
+	assert.Empty(t, flags)
+
+	// 被掩码事件的原文：重复写入不覆盖，随事件一起删除
+	raw := "TOKEN=Zx9Qw2Er7Ty4Ui1Op6As3Df8Gh"
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{
+		{RunID: "run-rf", Seq: 5, Type: "message", Timestamp: now, Payload: json.RawMessage(`{"content":"[REDACTED:secret]"}`), Redacted: true},
+	}))
+	require.NoError(t, s.CreateEventOriginals(ctx, []*model.EventOriginal{
+		{RunID: "run-rf", Seq: 5, Payload: json.RawMessage(`{"content":"Zx9Qw2Er7Ty4Ui1Op6As3Df8Gh"}`), Raw: &raw, CreatedAt: now},
+	}))
+	require.NoError(t, s.CreateEventOriginals(ctx, []*model.EventOriginal{{RunID: "run-rf", Seq: 5, CreatedAt: now}}))
+
+	events, err := s.GetEventsByRun(ctx, "run-rf", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.True(t, events[0].Redacted)
+
+	original, err := s.GetEventOriginal(ctx, "run-rf", 5)
+	require.NoError(t, err)
+	require.NotNil(t, original)
+	assert.JSONEq(t, `{"content":"Zx9Qw2Er7Ty4Ui1Op6As3Df8Gh"}`, string(original.Payload))
+	require.NotNil(t, original.Raw)
+	assert.Equal(t, raw, *original.Raw)
+
+	original, err = s.GetEventOriginal(ctx, "run-rf", 6)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+
+	_, err = s.DeleteEventsByRun(ctx, "run-rf")
+	require.NoError(t, err)
+	original, err = s.GetEventOriginal(ctx, "run-rf", 5)
+	require.NoError(t, err)
+	assert.Nil(t, original)
+}
+
+func TestSkillBundles(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.CreateSkill(ctx, &model.Skill{ID: "skill-1", Name: "review", Category: model.SkillCategoryCoding, Source: model.SkillSourceUser, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateSkillBundle(ctx, &model.SkillBundle{SkillID: "skill-1", Version: "1.0.0", Name: "review", Digest: "sha256:a", Size: 10, CreatedAt: now.Add(-time.Hour)}))
+	require.NoError(t, s.CreateSkillBundle(ctx, &model.SkillBundle{SkillID: "skill-1", Version: "1.1.0", Name: "review", Description: "新版", Digest: "sha256:b", Size: 20, CreatedAt: now}))
+	assert.Error(t, s.CreateSkillBundle(ctx, &model.SkillBundle{SkillID: "skill-1", Version: "1.1.0", Name: "review", Digest: "sha256:c", CreatedAt: now}))
+
+	b, err := s.GetSkillBundle(ctx, "skill-1", "1.1.0")
+	require.NoError(t, err)
+	require.NotNil(t, b)
+	assert.Equal(t, "sha256:b", b.Digest)
+	assert.Equal(t, "新版", b.Description)
+	assert.Equal(t, int64(20), b.Size)
+
+	b, err = s.GetSkillBundle(ctx, "skill-1", "2.0.0")
+	require.NoError(t, err)
+	assert.Nil(t, b)
+
+	bundles, err := s.ListSkillBundles(ctx, "skill-1")
+	require.NoError(t, err)
+	require.Len(t, bundles, 2)
+	assert.Equal(t, "1.1.0", bundles[0].Version)
+}
+
+func TestSchedulingDecisions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-sd", Name: "T", Status: model.TaskStatusInProgress, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-sd", TaskID: "task-sd", Status: model.RunStatusQueued, CreatedAt: now, UpdatedAt: now}))
+
+	full := func(running int) *model.SchedulingDecision {
+		return &model.SchedulingDecision{
+			RunID: "run-sd", Outcome: model.SchedulingOutcomeNoMatch, Reason: "no_strategy_matched",
+			Candidates: []model.SchedulingCandidate{{NodeID: "node-1", Running: running, MaxConcurrent: 1, Rejected: model.SchedulingRejectCapacity}},
+			CreatedAt:  now, UpdatedAt: now,
+		}
+	}
+	require.NoError(t, s.RecordSchedulingDecision(ctx, full(1)))
+	// 相同决策（运行数不同）合并计数
+	later := full(2)
+	later.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, s.RecordSchedulingDecision(ctx, later))
+	assert.Equal(t, 2, later.Attempts)
+
+	require.NoError(t, s.RecordSchedulingDecision(ctx, &model.SchedulingDecision{
+		RunID: "run-sd", Outcome: model.SchedulingOutcomeAssigned, Strategy: "load_balance", NodeID: "node-1",
+		Candidates: []model.SchedulingCandidate{{NodeID: "node-1", MaxConcurrent: 1, Rejected: model.SchedulingSelected}},
+		CreatedAt:  now.Add(2 * time.Minute), UpdatedAt: now.Add(2 * time.Minute),
+	}))
+
+	decisions, err := s.ListSchedulingDecisions(ctx, "run-sd", 10)
+	require.NoError(t, err)
+	require.Len(t, decisions, 2)
+	assert.Equal(t, model.SchedulingOutcomeAssigned, decisions[0].Outcome)
+	assert.Equal(t, "load_balance", decisions[0].Strategy)
+	assert.Equal(t, 1, decisions[0].Attempts)
+	assert.Equal(t, model.SchedulingOutcomeNoMatch, decisions[1].Outcome)
+	assert.Equal(t, 2, decisions[1].Attempts)
+	assert.True(t, decisions[1].UpdatedAt.After(decisions[1].CreatedAt))
+	require.Len(t, decisions[1].Candidates, 1)
+	assert.Equal(t, model.SchedulingRejectCapacity, decisions[1].Candidates[0].Rejected)
+
+	decisions, err = s.ListSchedulingDecisions(ctx, "run-sd", 1)
+	require.NoError(t, err)
+	assert.Len(t, decisions, 1)
+
+	decisions, err = s.ListSchedulingDecisions(ctx, "run-none", 10)
+	require.NoError(t, err)
+	assert.Empty(t, decisions)
+}
+
+func TestReclaimRun(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	node := "node-dead"
+
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-rc", Name: "T", Status: model.TaskStatusInProgress, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	for _, id := range []string{"run-rc1", "run-rc2"} {
+		require.NoError(t, s.CreateRun(ctx, &model.Run{ID: id, TaskID: "task-rc", Status: model.RunStatusRunning, NodeID: &node, StartedAt: &now, CreatedAt: now, UpdatedAt: now}))
+	}
+
+	ok, err := s.ReclaimRun(ctx, "run-rc1", node, model.RunStatusQueued, "orphaned")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, _ := s.GetRun(ctx, "run-rc1")
+	assert.Equal(t, model.RunStatusQueued, got.Status)
+	assert.Nil(t, got.NodeID)
+	assert.Nil(t, got.StartedAt)
+	assert.Nil(t, got.Error)
+
+	// 已回收（不再分配给该节点）时条件更新不生效
+	ok, err = s.ReclaimRun(ctx, "run-rc1", node, model.RunStatusQueued, "orphaned")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.ReclaimRun(ctx, "run-rc2", "node-other", model.RunStatusFailed, "orphaned")
+	require.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = s.ReclaimRun(ctx, "run-rc2", node, model.RunStatusFailed, "orphaned: node node-dead missed 3 heartbeats")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	got, _ = s.GetRun(ctx, "run-rc2")
+	assert.Equal(t, model.RunStatusFailed, got.Status)
+	require.NotNil(t, got.Error)
+	assert.Equal(t, "orphaned: node node-dead missed 3 heartbeats", *got.Error)
+	assert.NotNil(t, got.FinishedAt)
+
+	_, err = s.ReclaimRun(ctx, "run-rc2", node, model.RunStatusDone, "")
+	assert.Error(t, err)
+}
+
 // ============================================================================
 // Event 测试
 // ============================================================================
@@ -235,7 +787,7 @@ func TestEventCRUD(t *testing.T) {
 
 	events := []*model.Event{
 		{RunID: "run-e1", Seq: 1, Type: "action", Timestamp: now},
-		{RunID: "run-e1", Seq: 2, Type: "observation", Timestamp: now},
+		{RunID: "run-e1", Seq: 2, Type: "message", Timestamp: now, SchemaVersion: 1},
 	}
 	require.NoError(t, s.CreateEvents(ctx, events))
 
@@ -245,13 +797,301 @@ func TestEventCRUD(t *testing.T) {
 
 	evts, err := s.GetEventsByRun(ctx, "run-e1", 0, 10)
 	require.NoError(t, err)
-	assert.Len(t, evts, 2)
+	require.Len(t, evts, 2)
+	assert.Equal(t, 0, evts[0].SchemaVersion)
+	assert.Equal(t, 1, evts[1].SchemaVersion)
 
 	evts, err = s.GetEventsByRun(ctx, "run-e1", 1, 10)
 	require.NoError(t, err)
 	assert.Len(t, evts, 1)
 }
 
+// TestEventSeqAllocation 事件序号按 Run 单调分配，节点序号重复的事件被忽略
+func TestEventSeqAllocation(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-s1", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-s1", TaskID: "task-s1", Status: model.RunStatusRunning, CreatedAt: now, UpdatedAt: now}))
+
+	first, err := s.AllocateEventSeqs(ctx, "run-s1", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, first)
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{
+		{RunID: "run-s1", Seq: 1, NodeSeq: 1, Type: "run_started", Timestamp: now},
+		{RunID: "run-s1", Seq: 2, NodeSeq: 2, Type: "message", Timestamp: now},
+	}))
+
+	// API Server 自身写入的事件没有节点序号
+	first, err = s.AllocateEventSeqs(ctx, "run-s1", 1)
+	require.NoError(t, err)
+	assert.Equal(t, 3, first)
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{{RunID: "run-s1", Seq: 3, Type: "budget_exceeded", Timestamp: now}}))
+
+	// 节点重试：节点序号 2 已入库，被忽略
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{
+		{RunID: "run-s1", Seq: 4, NodeSeq: 2, Type: "message", Timestamp: now},
+		{RunID: "run-s1", Seq: 5, NodeSeq: 3, Type: "message", Timestamp: now},
+	}))
+
+	nodeSeqs, err := s.ListEventNodeSeqs(ctx, "run-s1", 2, 10)
+	require.NoError(t, err)
+	assert.Equal(t, []int{2, 3}, nodeSeqs)
+
+	seq, nodeSeq, err := s.MaxEventSeqs(ctx, "run-s1")
+	require.NoError(t, err)
+	assert.Equal(t, 5, seq)
+	assert.Equal(t, 3, nodeSeq)
+
+	evts, err := s.GetEventsByRun(ctx, "run-s1", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, evts, 4)
+	assert.Equal(t, 0, evts[2].NodeSeq)
+	assert.Equal(t, 3, evts[3].NodeSeq)
+
+	_, err = s.AllocateEventSeqs(ctx, "run-missing", 1)
+	assert.Error(t, err)
+}
+
+func TestEventCompression(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	s.SetEventCompression(256)
+
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-z1", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-z1", TaskID: "task-z1", Status: model.RunStatusRunning, CreatedAt: now, UpdatedAt: now}))
+
+	content := strings.Repeat("compiling module agents-admin ", 40)
+	payload := json.RawMessage(`{"content":"` + content + `"}`)
+	raw := `{"type":"assistant","message":{"content":"` + content + `"}}`
+	small := "ok"
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{
+		{RunID: "run-z1", Seq: 1, Type: "message", Timestamp: now, Payload: payload, Raw: &raw},
+		{RunID: "run-z1", Seq: 2, Type: "message", Timestamp: now, Payload: json.RawMessage(`{"content":"ok"}`), Raw: &small},
+		{RunID: "run-z1", Seq: 3, Type: "command_output", Timestamp: now, Raw: &content},
+		{RunID: "run-z1", Seq: 4, Type: "message", Timestamp: now, Payload: payload, RawRef: "events/raw/run-z1/4-4.zst#4"},
+	}))
+
+	// 超过阈值的事件只写入 body_zstd，且压缩后远小于原始内容
+	var compressed, plain int
+	require.NoError(t, s.db.QueryRowContext(ctx, `SELECT COUNT(body_zstd), COUNT(payload) FROM events WHERE run_id = 'run-z1'`).Scan(&compressed, &plain))
+	assert.Equal(t, 3, compressed)
+	assert.Equal(t, 1, plain)
+	var bodySize int
+	require.NoError(t, s.db.QueryRowContext(ctx, `SELECT LENGTH(body_zstd) FROM events WHERE run_id = 'run-z1' AND seq = 1`).Scan(&bodySize))
+	assert.Less(t, bodySize, (len(payload)+len(raw))/10)
+
+	evts, err := s.GetEventsByRun(ctx, "run-z1", 0, 10)
+	require.NoError(t, err)
+	require.Len(t, evts, 4)
+	assert.JSONEq(t, string(payload), string(evts[0].Payload))
+	require.NotNil(t, evts[0].Raw)
+	assert.Equal(t, raw, *evts[0].Raw)
+	assert.Equal(t, "ok", *evts[1].Raw)
+	assert.Nil(t, evts[2].Payload)
+	assert.Equal(t, content, *evts[2].Raw)
+	assert.Nil(t, evts[3].Raw)
+	assert.Equal(t, "events/raw/run-z1/4-4.zst#4", evts[3].RawRef)
+
+	// 关闭压缩后已压缩的事件仍可读取
+	s.SetEventCompression(0)
+	evts, err = s.GetEventsByRun(ctx, "run-z1", 0, 1)
+	require.NoError(t, err)
+	assert.Equal(t, raw, *evts[0].Raw)
+}
+
+func TestSearchEvents(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	task := &model.Task{ID: "task-s1", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	for _, id := range []string{"run-s1", "run-s2"} {
+		require.NoError(t, s.CreateRun(ctx, &model.Run{ID: id, TaskID: "task-s1", Status: model.RunStatusDone, CreatedAt: now, UpdatedAt: now}))
+	}
+
+	raw := "npm ERR! connect ETIMEDOUT registry.npmjs.org"
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{
+		{RunID: "run-s1", Seq: 1, Type: "file_write", Timestamp: now, Payload: json.RawMessage(`{"path":"src/main_test.go"}`)},
+		{RunID: "run-s1", Seq: 2, Type: "command_output", Timestamp: now.Add(time.Second), Raw: &raw},
+		{RunID: "run-s2", Seq: 1, Type: "command_output", Timestamp: now, Raw: &raw},
+		{RunID: "run-s2", Seq: 2, Type: "message", Timestamp: now, Payload: json.RawMessage(`{"content":"done"}`)},
+	}))
+
+	// 大小写不敏感，多个关键词需同时命中
+	hits, total, err := s.SearchEvents(ctx, storagetypes.EventSearchFilter{Query: "etimedout NPMJS", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, hits, 2)
+	assert.Equal(t, "run-s1", hits[0].RunID) // 按时间倒序
+	assert.Contains(t, hits[0].Highlight, "<mark>ETIMEDOUT</mark>")
+
+	// 检索 payload；LIKE 通配符按字面匹配
+	hits, total, err = s.SearchEvents(ctx, storagetypes.EventSearchFilter{Query: "main_test.go", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "file_write", hits[0].Type)
+	hits, _, err = s.SearchEvents(ctx, storagetypes.EventSearchFilter{Query: "main%go", Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, hits)
+
+	// 限定 Run 与分页
+	hits, total, err = s.SearchEvents(ctx, storagetypes.EventSearchFilter{Query: "etimedout", RunID: "run-s2", Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, hits, 1)
+	assert.Equal(t, "run-s2", hits[0].RunID)
+	hits, total, err = s.SearchEvents(ctx, storagetypes.EventSearchFilter{Query: "etimedout", Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	assert.Len(t, hits, 1)
+
+	hits, total, err = s.SearchEvents(ctx, storagetypes.EventSearchFilter{Query: "  ", Limit: 10})
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Empty(t, hits)
+}
+
+func TestArtifactCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	task := &model.Task{ID: "task-a1", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	run := &model.Run{ID: "run-a1", TaskID: "task-a1", Status: model.RunStatusDone, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateRun(ctx, run))
+
+	ct := "text/uri-list"
+	require.NoError(t, s.CreateArtifact(ctx, &model.Artifact{
+		RunID: "run-a1", Name: "pull_request", Path: "https://github.com/example/repo/pull/1",
+		ContentType: &ct, CreatedAt: now,
+	}))
+
+	list, err := s.ListArtifactsByRun(ctx, "run-a1")
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "pull_request", list[0].Name)
+	assert.Equal(t, "https://github.com/example/repo/pull/1", list[0].Path)
+	require.NotNil(t, list[0].ContentType)
+	assert.Equal(t, ct, *list[0].ContentType)
+
+	list, err = s.ListArtifactsByRun(ctx, "run-none")
+	require.NoError(t, err)
+	assert.Empty(t, list)
+}
+
+func TestRunArchives(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	task := &model.Task{ID: "task-a1", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	runs := []*model.Run{
+		{ID: "run-a1", Status: model.RunStatusDone, FinishedAt: timePtr(now.Add(-48 * time.Hour))},
+		{ID: "run-a2", Status: model.RunStatusFailed, FinishedAt: timePtr(now.Add(-24 * time.Hour))},
+		{ID: "run-a3", Status: model.RunStatusDone, FinishedAt: timePtr(now)},
+		{ID: "run-a4", Status: model.RunStatusRunning},
+	}
+	for _, r := range runs {
+		r.TaskID, r.Snapshot, r.CreatedAt, r.UpdatedAt = "task-a1", json.RawMessage(`{"prompt":"x"}`), now, now
+		require.NoError(t, s.CreateRun(ctx, r))
+	}
+	require.NoError(t, s.CreateEvents(ctx, []*model.Event{
+		{RunID: "run-a1", Seq: 1, Type: "message", Timestamp: now},
+		{RunID: "run-a1", Seq: 2, Type: "message", Timestamp: now},
+		{RunID: "run-a2", Seq: 1, Type: "message", Timestamp: now},
+	}))
+
+	// 只列出结束早于 before 且未归档的终态 Run
+	candidates, err := s.ListRunsForArchive(ctx, storagetypes.ArchiveRunFilter{Before: now.Add(-time.Hour), Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "run-a1", candidates[0].ID)
+
+	// 按项目过滤
+	projectID := "proj-a"
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-a2", Name: "P", Status: model.TaskStatusPending, Type: "general",
+		ProjectID: &projectID, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-a5", TaskID: "task-a2", Status: model.RunStatusDone,
+		FinishedAt: timePtr(now.Add(-72 * time.Hour)), CreatedAt: now, UpdatedAt: now}))
+	candidates, err = s.ListRunsForArchive(ctx, storagetypes.ArchiveRunFilter{Before: now.Add(-time.Hour), ProjectID: projectID, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "run-a5", candidates[0].ID)
+	candidates, err = s.ListRunsForArchive(ctx, storagetypes.ArchiveRunFilter{Before: now.Add(-time.Hour), ExcludeProjectIDs: []string{projectID}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, candidates, 2)
+	assert.Equal(t, "run-a1", candidates[0].ID)
+
+	archive := &model.RunArchive{
+		RunID: "run-a1", Tier: model.RunDataTierWarm, ArchiveKey: "run-archives/run-a1/events.ndjson.gz",
+		EventCount: 2, ArchiveBytes: 128, WarmAt: now.Add(-time.Hour),
+		Summary: &model.RunSummary{Status: model.RunStatusDone, EventCount: 2, EventTypes: map[string]int{"message": 2}},
+	}
+	require.NoError(t, s.CreateRunArchive(ctx, archive))
+	deleted, err := s.DeleteEventsByRun(ctx, "run-a1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), deleted)
+
+	candidates, err = s.ListRunsForArchive(ctx, storagetypes.ArchiveRunFilter{Before: now.Add(-time.Hour), ExcludeProjectIDs: []string{projectID}, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, "run-a2", candidates[0].ID)
+
+	total, err := s.CountEvents(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), total)
+
+	got, err := s.GetRunArchive(ctx, "run-a1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, archive.Summary, got.Summary)
+	missing, err := s.GetRunArchive(ctx, "run-a2")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	// warm → cold：清除快照
+	warm, err := s.ListRunArchives(ctx, model.RunDataTierWarm, now, 10)
+	require.NoError(t, err)
+	require.Len(t, warm, 1)
+	got.Tier, got.ColdAt = model.RunDataTierCold, timePtr(now)
+	require.NoError(t, s.UpdateRunArchive(ctx, got))
+	require.NoError(t, s.ClearRunSnapshot(ctx, "run-a1"))
+	run, err := s.GetRun(ctx, "run-a1")
+	require.NoError(t, err)
+	assert.Empty(t, run.Snapshot)
+
+	stats, err := s.RunArchiveStats(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []model.RunTierStats{{Tier: model.RunDataTierCold, Runs: 1, Events: 2, ArchiveBytes: 128}}, stats)
+
+	// 按项目保留策略删除到期归档
+	require.NoError(t, s.CreateRunArchive(ctx, &model.RunArchive{
+		RunID: "run-a5", ProjectID: projectID, Tier: model.RunDataTierWarm, ArchiveKey: "run-archives/run-a5/events.ndjson.gz",
+		ArchiveBytes: 64, WarmAt: now.Add(-48 * time.Hour),
+	}))
+	expired, err := s.ListExpiredRunArchives(ctx, projectID, now.Add(-24*time.Hour), 10)
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "run-a5", expired[0].RunID)
+	assert.Equal(t, projectID, expired[0].ProjectID)
+	expired[0].Tier, expired[0].ArchiveBytes, expired[0].PurgedAt = model.RunDataTierPurged, 0, timePtr(now)
+	require.NoError(t, s.UpdateRunArchive(ctx, expired[0]))
+	expired, err = s.ListExpiredRunArchives(ctx, projectID, now, 10)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	got, err = s.GetRunArchive(ctx, "run-a5")
+	require.NoError(t, err)
+	assert.Equal(t, model.RunDataTierPurged, got.Tier)
+	require.NotNil(t, got.PurgedAt)
+}
+
 // ============================================================================
 // Node 测试
 // ============================================================================
@@ -294,12 +1134,95 @@ func TestNodeCRUD(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, onlineNodes, 1)
 
+	// 并发配置覆盖（心跳不覆盖）
+	override, err := s.GetNodeCapacityOverride(ctx, "node-001")
+	require.NoError(t, err)
+	assert.Nil(t, override)
+	maxConcurrent := 8
+	require.NoError(t, s.SetNodeCapacityOverride(ctx, "node-001", &model.NodeConcurrencyOverride{
+		MaxConcurrent: &maxConcurrent, AgentTypeLimits: map[string]int{"claude": 2},
+	}))
+	require.NoError(t, s.UpsertNodeHeartbeat(ctx, node))
+	override, err = s.GetNodeCapacityOverride(ctx, "node-001")
+	require.NoError(t, err)
+	require.NotNil(t, override)
+	assert.Equal(t, 8, *override.MaxConcurrent)
+	assert.Equal(t, 2, override.AgentTypeLimits["claude"])
+	require.NoError(t, s.SetNodeCapacityOverride(ctx, "node-001", nil))
+	override, _ = s.GetNodeCapacityOverride(ctx, "node-001")
+	assert.Nil(t, override)
+
+	// 污点（心跳不覆盖）
+	taint := model.NodeTaint{Key: "dedicated", Value: "ml", Effect: model.TaintEffectNoSchedule}
+	require.NoError(t, s.SetNodeTaints(ctx, "node-001", []model.NodeTaint{taint}))
+	require.NoError(t, s.UpsertNodeHeartbeat(ctx, node))
+	got, _ = s.GetNode(ctx, "node-001")
+	assert.Equal(t, []model.NodeTaint{taint}, got.Taints)
+	nodes, _ = s.ListAllNodes(ctx)
+	assert.Equal(t, []model.NodeTaint{taint}, nodes[0].Taints)
+	require.NoError(t, s.SetNodeTaints(ctx, "node-001", nil))
+	got, _ = s.GetNode(ctx, "node-001")
+	assert.Empty(t, got.Taints)
+
 	// Delete
 	require.NoError(t, s.DeleteNode(ctx, "node-001"))
 	got, _ = s.GetNode(ctx, "node-001")
 	assert.Nil(t, got)
 }
 
+func TestNodeJoinTokens(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	tok := &model.NodeJoinToken{
+		ID: "njt-1", TokenHash: "hash-1", MaxUses: 2, Pool: "gpu",
+		Labels: map[string]string{"zone": "a"}, ExpiresAt: now.Add(time.Hour), CreatedAt: now,
+	}
+	require.NoError(t, s.CreateNodeJoinToken(ctx, tok))
+
+	// 使用次数达到上限后失效
+	for _, node := range []string{"node-1", "node-2"} {
+		got, err := s.ConsumeNodeJoinToken(ctx, "hash-1", node, now)
+		require.NoError(t, err)
+		require.NotNil(t, got)
+		assert.Equal(t, map[string]string{"zone": "a"}, got.Labels)
+		assert.Equal(t, node, *got.UsedByNode)
+	}
+	got, err := s.ConsumeNodeJoinToken(ctx, "hash-1", "node-3", now)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	tokens, err := s.ListNodeJoinTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, 2, tokens[0].UseCount)
+	assert.Equal(t, "gpu", tokens[0].Pool)
+
+	// 过期令牌不可使用
+	require.NoError(t, s.CreateNodeJoinToken(ctx, &model.NodeJoinToken{
+		ID: "njt-2", TokenHash: "hash-2", MaxUses: 1, ExpiresAt: now.Add(-time.Minute), CreatedAt: now,
+	}))
+	got, err = s.ConsumeNodeJoinToken(ctx, "hash-2", "node-1", now)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+
+	require.NoError(t, s.CreateNodeJoinTokenUse(ctx, &model.NodeJoinTokenUse{ID: "nju-1", TokenID: "njt-1", NodeID: "node-1", UsedAt: now}))
+	require.NoError(t, s.CreateNodeJoinTokenUse(ctx, &model.NodeJoinTokenUse{ID: "nju-2", TokenID: "njt-1", NodeID: "node-2", Hostname: "h2", UsedAt: now.Add(time.Second)}))
+	uses, err := s.ListNodeJoinTokenUses(ctx, "njt-1")
+	require.NoError(t, err)
+	require.Len(t, uses, 2)
+	assert.Equal(t, "node-2", uses[0].NodeID)
+
+	require.NoError(t, s.UpsertNodeCredential(ctx, &model.NodeCredential{
+		NodeID: "node-1", TokenHash: "cred-1", JoinTokenID: "njt-1", Labels: tok.EnrollLabels(), CreatedAt: now,
+	}))
+	cred, err := s.GetNodeCredentialByTokenHash(ctx, "cred-1")
+	require.NoError(t, err)
+	require.NotNil(t, cred)
+	assert.Equal(t, map[string]string{"zone": "a", "pool": "gpu"}, cred.Labels)
+}
+
 // ============================================================================
 // Account + AuthTask 测试
 // ============================================================================
@@ -396,109 +1319,470 @@ func TestOperationCRUD(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, model.OperationType("deploy"), got.Type)
 
-	ops, err := s.ListOperations(ctx, "deploy", "", 10, 0)
+	ops, err := s.ListOperations(ctx, "deploy", "", 10, 0)
+	require.NoError(t, err)
+	assert.Len(t, ops, 1)
+
+	require.NoError(t, s.UpdateOperationStatus(ctx, "op-001", model.OperationStatusCompleted))
+	got, _ = s.GetOperation(ctx, "op-001")
+	assert.Equal(t, model.OperationStatusCompleted, got.Status)
+}
+
+func TestActionCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	op := &model.Operation{ID: "op-act", Type: "deploy", Status: model.OperationStatusInProgress, NodeID: "node-1", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateOperation(ctx, op))
+
+	action := &model.Action{
+		ID:          "act-001",
+		OperationID: "op-act",
+		Status:      model.ActionStatusAssigned,
+		CreatedAt:   now,
+	}
+	require.NoError(t, s.CreateAction(ctx, action))
+
+	got, err := s.GetAction(ctx, "act-001")
+	require.NoError(t, err)
+	assert.Equal(t, model.ActionStatusAssigned, got.Status)
+
+	// GetActionWithOperation
+	got, err = s.GetActionWithOperation(ctx, "act-001")
+	require.NoError(t, err)
+	require.NotNil(t, got.Operation)
+	assert.Equal(t, model.OperationType("deploy"), got.Operation.Type)
+
+	// ListActionsByOperation
+	actions, err := s.ListActionsByOperation(ctx, "op-act")
+	require.NoError(t, err)
+	assert.Len(t, actions, 1)
+
+	// ListActionsByNode
+	actions, err = s.ListActionsByNode(ctx, "node-1", "")
+	require.NoError(t, err)
+	assert.Len(t, actions, 1)
+
+	// UpdateActionStatus
+	require.NoError(t, s.UpdateActionStatus(ctx, "act-001", model.ActionStatusRunning, "executing", "Running", 50, nil, ""))
+	got, _ = s.GetAction(ctx, "act-001")
+	assert.Equal(t, model.ActionStatusRunning, got.Status)
+}
+
+// ============================================================================
+// Proxy 测试
+// ============================================================================
+
+func TestProxyCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	proxy := &model.Proxy{
+		ID:        "proxy-001",
+		Name:      "Test Proxy",
+		Type:      "http",
+		Host:      "127.0.0.1",
+		Port:      8080,
+		IsDefault: false,
+		Status:    "active",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	require.NoError(t, s.CreateProxy(ctx, proxy))
+
+	got, err := s.GetProxy(ctx, "proxy-001")
+	require.NoError(t, err)
+	assert.Equal(t, "Test Proxy", got.Name)
+
+	proxies, err := s.ListProxies(ctx)
+	require.NoError(t, err)
+	assert.Len(t, proxies, 1)
+
+	// SetDefault
+	require.NoError(t, s.SetDefaultProxy(ctx, "proxy-001"))
+	def, err := s.GetDefaultProxy(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, def)
+	assert.Equal(t, "proxy-001", def.ID)
+
+	// ClearDefault
+	require.NoError(t, s.ClearDefaultProxy(ctx))
+	def, _ = s.GetDefaultProxy(ctx)
+	assert.Nil(t, def)
+
+	// Update
+	proxy.Name = "Updated Proxy"
+	require.NoError(t, s.UpdateProxy(ctx, proxy))
+	got, _ = s.GetProxy(ctx, "proxy-001")
+	assert.Equal(t, "Updated Proxy", got.Name)
+
+	// Delete
+	require.NoError(t, s.DeleteProxy(ctx, "proxy-001"))
+	got, _ = s.GetProxy(ctx, "proxy-001")
+	assert.Nil(t, got)
+}
+
+// ============================================================================
+// Credential 测试
+// ============================================================================
+
+func TestCredentialCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	cred := &model.Credential{
+		ID:              "cred-001",
+		Name:            "github-bot",
+		Type:            model.CredentialTypeHTTPSToken,
+		EncryptedSecret: "v1:ciphertext",
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	require.NoError(t, s.CreateCredential(ctx, cred))
+
+	// 名称唯一
+	dup := *cred
+	dup.ID = "cred-002"
+	assert.Error(t, s.CreateCredential(ctx, &dup))
+
+	got, err := s.GetCredentialByName(ctx, "github-bot")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "cred-001", got.ID)
+	assert.Equal(t, "v1:ciphertext", got.EncryptedSecret)
+
+	cred.Description = "CI bot"
+	require.NoError(t, s.UpdateCredential(ctx, cred))
+	got, _ = s.GetCredential(ctx, "cred-001")
+	assert.Equal(t, "CI bot", got.Description)
+
+	list, err := s.ListCredentials(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, s.DeleteCredential(ctx, "cred-001"))
+	got, err = s.GetCredential(ctx, "cred-001")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+// ============================================================================
+// Secret 测试
+// ============================================================================
+
+func TestSecretCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	secret := &model.Secret{
+		ID:             "sec-001",
+		Name:           "OPENAI_API_KEY",
+		EncryptedValue: "v1:ciphertext",
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	require.NoError(t, s.CreateSecret(ctx, secret))
+
+	// 名称唯一
+	dup := *secret
+	dup.ID = "sec-002"
+	assert.Error(t, s.CreateSecret(ctx, &dup))
+
+	got, err := s.GetSecretByName(ctx, "OPENAI_API_KEY")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "sec-001", got.ID)
+	assert.Equal(t, "v1:ciphertext", got.EncryptedValue)
+
+	secret.Description = "LLM token"
+	secret.EncryptedValue = "v1:rotated"
+	require.NoError(t, s.UpdateSecret(ctx, secret))
+	got, _ = s.GetSecret(ctx, "sec-001")
+	assert.Equal(t, "LLM token", got.Description)
+	assert.Equal(t, "v1:rotated", got.EncryptedValue)
+
+	// 任务引用密钥名称
+	task := &model.Task{ID: "task-sec", Name: "S", Status: model.TaskStatusPending, Type: "general", Secrets: []string{"OPENAI_API_KEY"}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	gotTask, err := s.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"OPENAI_API_KEY"}, gotTask.Secrets)
+
+	list, err := s.ListSecrets(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, s.DeleteSecret(ctx, "sec-001"))
+	got, err = s.GetSecret(ctx, "sec-001")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+// ============================================================================
+// Project 测试
+// ============================================================================
+
+func TestProjectCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	project := &model.Project{
+		ID:                      "proj-001",
+		Name:                    "backend",
+		DefaultAgentType:        "qwen-code",
+		AllowedAgentTypes:       []string{"qwen-code", "claude"},
+		AccountPool:             []string{"acc-1", "acc-2"},
+		DefaultSecurity:         &model.SecurityConfig{Policy: model.SecurityPolicyStandard},
+		AllowedSecurityPolicies: []model.SecurityPolicy{model.SecurityPolicyStrict, model.SecurityPolicyStandard},
+		Overrides:               model.ProjectOverrides{Account: model.OverrideDeny},
+		CreatedAt:               now,
+		UpdatedAt:               now,
+	}
+	require.NoError(t, s.CreateProject(ctx, project))
+
+	// 名称唯一
+	dup := *project
+	dup.ID = "proj-002"
+	assert.Error(t, s.CreateProject(ctx, &dup))
+
+	got, err := s.GetProject(ctx, "proj-001")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, project.AllowedAgentTypes, got.AllowedAgentTypes)
+	assert.Equal(t, project.AccountPool, got.AccountPool)
+	assert.Equal(t, model.SecurityPolicyStandard, got.DefaultSecurity.Policy)
+	assert.Equal(t, project.AllowedSecurityPolicies, got.AllowedSecurityPolicies)
+	assert.Equal(t, model.OverrideDeny, got.Overrides.Account)
+
+	assert.Nil(t, got.ResultPublishing)
+
+	project.Description = "Backend services"
+	project.AccountPool = []string{"acc-3"}
+	project.ResultPublishing = &model.ResultPublishConfig{Enabled: true, Mode: model.PublishBoth, CredentialRef: "gh-bot"}
+	project.Retention = &model.RetentionPolicy{HotDays: 3, ArchiveDays: 30}
+	require.NoError(t, s.UpdateProject(ctx, project))
+	got, _ = s.GetProject(ctx, "proj-001")
+	assert.Equal(t, "Backend services", got.Description)
+	assert.Equal(t, []string{"acc-3"}, got.AccountPool)
+	require.NotNil(t, got.ResultPublishing)
+	assert.Equal(t, model.PublishBoth, got.ResultPublishing.Mode)
+	assert.Equal(t, "gh-bot", got.ResultPublishing.CredentialRef)
+	assert.Equal(t, &model.RetentionPolicy{HotDays: 3, ArchiveDays: 30}, got.Retention)
+
+	// 按项目过滤任务
+	projectID := "proj-001"
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-p1", Name: "P", Status: model.TaskStatusPending, Type: "general", ProjectID: &projectID, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-p2", Name: "Q", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	tasks, total, err := s.ListTasksWithFilter(ctx, storagetypes.TaskFilter{ProjectID: projectID, Limit: 10})
+	require.NoError(t, err)
+	assert.Equal(t, 1, total)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "task-p1", tasks[0].ID)
+	assert.Equal(t, projectID, *tasks[0].ProjectID)
+
+	list, err := s.ListProjects(ctx)
+	require.NoError(t, err)
+	assert.Len(t, list, 1)
+
+	require.NoError(t, s.DeleteProject(ctx, "proj-001"))
+	got, err = s.GetProject(ctx, "proj-001")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestRegionCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	region := &model.Region{
+		ID:              "cn-east",
+		DisplayName:     "华东",
+		Fallback:        model.RegionFallbackOrdered,
+		FallbackRegions: []string{"cn-north"},
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+	require.NoError(t, s.CreateRegion(ctx, region))
+	require.NoError(t, s.CreateRegion(ctx, &model.Region{ID: "cn-north", CreatedAt: now, UpdatedAt: now}))
+	assert.Error(t, s.CreateRegion(ctx, region))
+
+	got, err := s.GetRegion(ctx, "cn-east")
 	require.NoError(t, err)
-	assert.Len(t, ops, 1)
+	require.NotNil(t, got)
+	assert.Equal(t, "华东", got.DisplayName)
+	assert.Equal(t, []string{"cn-north"}, got.FallbackRegions)
+
+	region.Fallback = model.RegionFallbackNone
+	region.FallbackRegions = nil
+	region.Description = "Shanghai"
+	require.NoError(t, s.UpdateRegion(ctx, region))
+	got, _ = s.GetRegion(ctx, "cn-east")
+	assert.Equal(t, model.RegionFallbackNone, got.Fallback)
+	assert.Empty(t, got.FallbackRegions)
+	assert.Equal(t, "Shanghai", got.Description)
+
+	regions, err := s.ListRegions(ctx)
+	require.NoError(t, err)
+	require.Len(t, regions, 2)
+	assert.Equal(t, "cn-east", regions[0].ID)
 
-	require.NoError(t, s.UpdateOperationStatus(ctx, "op-001", model.OperationStatusCompleted))
-	got, _ = s.GetOperation(ctx, "op-001")
-	assert.Equal(t, model.OperationStatusCompleted, got.Status)
+	require.NoError(t, s.DeleteRegion(ctx, "cn-north"))
+	missing, err := s.GetRegion(ctx, "cn-north")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
 }
 
-func TestActionCRUD(t *testing.T) {
+func TestCountQueuedRunsByRegion(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
 	now := time.Now().Truncate(time.Second)
 
-	op := &model.Operation{ID: "op-act", Type: "deploy", Status: model.OperationStatusInProgress, NodeID: "node-1", CreatedAt: now, UpdatedAt: now}
-	require.NoError(t, s.CreateOperation(ctx, op))
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-east", Name: "E", Status: model.TaskStatusPending, Type: "general", Region: "cn-east", CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateTask(ctx, &model.Task{ID: "task-any", Name: "A", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}))
+	got, err := s.GetTask(ctx, "task-east")
+	require.NoError(t, err)
+	assert.Equal(t, "cn-east", got.Region)
 
-	action := &model.Action{
-		ID:          "act-001",
-		OperationID: "op-act",
-		Status:      model.ActionStatusAssigned,
-		CreatedAt:   now,
-	}
-	require.NoError(t, s.CreateAction(ctx, action))
+	older := now.Add(-time.Minute)
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-e1", TaskID: "task-east", Status: model.RunStatusQueued, CreatedAt: older, UpdatedAt: older}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-e2", TaskID: "task-east", Status: model.RunStatusQueued, CreatedAt: now, UpdatedAt: now}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-e3", TaskID: "task-east", Status: model.RunStatusRunning, CreatedAt: older, UpdatedAt: older}))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-a1", TaskID: "task-any", Status: model.RunStatusQueued, CreatedAt: now, UpdatedAt: now}))
 
-	got, err := s.GetAction(ctx, "act-001")
+	depths, err := s.CountQueuedRunsByRegion(ctx)
 	require.NoError(t, err)
-	assert.Equal(t, model.ActionStatusAssigned, got.Status)
+	require.Len(t, depths, 2)
+	assert.Equal(t, "", depths[0].Region)
+	assert.Equal(t, 1, depths[0].QueuedRuns)
+	assert.Equal(t, "cn-east", depths[1].Region)
+	assert.Equal(t, 2, depths[1].QueuedRuns)
+	require.NotNil(t, depths[1].OldestQueuedAt)
+	assert.True(t, depths[1].OldestQueuedAt.Equal(older))
+}
 
-	// GetActionWithOperation
-	got, err = s.GetActionWithOperation(ctx, "act-001")
-	require.NoError(t, err)
-	require.NotNil(t, got.Operation)
-	assert.Equal(t, model.OperationType("deploy"), got.Operation.Type)
+func TestWorkflowCRUD(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
 
-	// ListActionsByOperation
-	actions, err := s.ListActionsByOperation(ctx, "op-act")
-	require.NoError(t, err)
-	assert.Len(t, actions, 1)
+	for i, id := range []string{"wf-root-1", "wf-root-2"} {
+		wf := &model.Workflow{
+			ID:         "wf-" + strconv.Itoa(i+1),
+			Name:       "pipeline",
+			RootTaskID: id,
+			Mode:       model.WorkflowModeContinueOnError,
+			Status:     model.WorkflowStatusRunning,
+			Nodes: []model.WorkflowNode{
+				{TaskID: "build", Status: model.WorkflowNodePending},
+				{TaskID: "test", DependsOn: []string{"build"}, Status: model.WorkflowNodePending},
+			},
+			CreatedAt: now.Add(time.Duration(i) * time.Second),
+			UpdatedAt: now,
+		}
+		require.NoError(t, s.CreateWorkflow(ctx, wf))
+	}
 
-	// ListActionsByNode
-	actions, err = s.ListActionsByNode(ctx, "node-1", "")
+	got, err := s.GetWorkflow(ctx, "wf-1")
 	require.NoError(t, err)
-	assert.Len(t, actions, 1)
+	require.NotNil(t, got)
+	assert.Equal(t, model.WorkflowModeContinueOnError, got.Mode)
+	require.Len(t, got.Nodes, 2)
+	assert.Equal(t, []string{"build"}, got.Nodes[1].DependsOn)
+	assert.Nil(t, got.FinishedAt)
+
+	finished := now.Add(time.Minute)
+	got.Status = model.WorkflowStatusSucceeded
+	got.Nodes[0].Status = model.WorkflowNodeSucceeded
+	got.Nodes[0].RunID = "run-1"
+	got.FinishedAt = &finished
+	got.UpdatedAt = finished
+	require.NoError(t, s.UpdateWorkflow(ctx, got))
+
+	got, _ = s.GetWorkflow(ctx, "wf-1")
+	assert.Equal(t, model.WorkflowStatusSucceeded, got.Status)
+	assert.Equal(t, "run-1", got.Nodes[0].RunID)
+	require.NotNil(t, got.FinishedAt)
+
+	running, err := s.ListWorkflows(ctx, string(model.WorkflowStatusRunning), 0, 0)
+	require.NoError(t, err)
+	require.Len(t, running, 1)
+	assert.Equal(t, "wf-2", running[0].ID)
 
-	// UpdateActionStatus
-	require.NoError(t, s.UpdateActionStatus(ctx, "act-001", model.ActionStatusRunning, "executing", "Running", 50, nil, ""))
-	got, _ = s.GetAction(ctx, "act-001")
-	assert.Equal(t, model.ActionStatusRunning, got.Status)
-}
+	all, _ := s.ListWorkflows(ctx, "", 1, 1)
+	require.Len(t, all, 1)
+	assert.Equal(t, "wf-1", all[0].ID, "按创建时间倒序，第二页为较早的工作流")
 
-// ============================================================================
-// Proxy 测试
-// ============================================================================
+	missing, err := s.GetWorkflow(ctx, "wf-missing")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
 
-func TestProxyCRUD(t *testing.T) {
+func TestIntegrationAndIssueLink(t *testing.T) {
 	s := newTestStore(t)
 	ctx := context.Background()
 	now := time.Now().Truncate(time.Second)
 
-	proxy := &model.Proxy{
-		ID:        "proxy-001",
-		Name:      "Test Proxy",
-		Type:      "http",
-		Host:      "127.0.0.1",
-		Port:      8080,
-		IsDefault: false,
-		Status:    "active",
-		CreatedAt: now,
-		UpdatedAt: now,
+	integration := &model.Integration{
+		ID:             "int-001",
+		Name:           "backend-issues",
+		Provider:       model.IntegrationGitHub,
+		Repo:           "acme/backend",
+		CredentialRef:  "github-token",
+		CommentResults: true,
+		CreatedAt:      now,
+		UpdatedAt:      now,
 	}
+	require.NoError(t, s.CreateIntegration(ctx, integration))
 
-	require.NoError(t, s.CreateProxy(ctx, proxy))
-
-	got, err := s.GetProxy(ctx, "proxy-001")
+	got, err := s.GetIntegration(ctx, "int-001")
 	require.NoError(t, err)
-	assert.Equal(t, "Test Proxy", got.Name)
+	require.NotNil(t, got)
+	assert.Equal(t, "acme/backend", got.Repo)
+	assert.True(t, got.CommentResults)
 
-	proxies, err := s.ListProxies(ctx)
+	integration.CommentResults = false
+	integration.Repo = "acme/api"
+	require.NoError(t, s.UpdateIntegration(ctx, integration))
+	got, _ = s.GetIntegration(ctx, "int-001")
+	assert.False(t, got.CommentResults)
+	assert.Equal(t, "acme/api", got.Repo)
+
+	list, err := s.ListIntegrations(ctx)
 	require.NoError(t, err)
-	assert.Len(t, proxies, 1)
+	assert.Len(t, list, 1)
 
-	// SetDefault
-	require.NoError(t, s.SetDefaultProxy(ctx, "proxy-001"))
-	def, err := s.GetDefaultProxy(ctx)
+	link := &model.IssueLink{TaskID: "task-1", IntegrationID: "int-001", IssueKey: "42",
+		URL: "https://github.com/acme/api/issues/42", CreatedAt: now}
+	require.NoError(t, s.CreateIssueLink(ctx, link))
+	dup := *link
+	dup.TaskID = "task-2"
+	assert.Error(t, s.CreateIssueLink(ctx, &dup), "同一 Issue 不可重复导入")
+
+	byTask, err := s.GetIssueLinkByTask(ctx, "task-1")
 	require.NoError(t, err)
-	require.NotNil(t, def)
-	assert.Equal(t, "proxy-001", def.ID)
+	require.NotNil(t, byTask)
+	assert.Equal(t, "42", byTask.IssueKey)
 
-	// ClearDefault
-	require.NoError(t, s.ClearDefaultProxy(ctx))
-	def, _ = s.GetDefaultProxy(ctx)
-	assert.Nil(t, def)
+	byIssue, err := s.GetIssueLinkByIssue(ctx, "int-001", "42")
+	require.NoError(t, err)
+	require.NotNil(t, byIssue)
+	assert.Equal(t, "task-1", byIssue.TaskID)
 
-	// Update
-	proxy.Name = "Updated Proxy"
-	require.NoError(t, s.UpdateProxy(ctx, proxy))
-	got, _ = s.GetProxy(ctx, "proxy-001")
-	assert.Equal(t, "Updated Proxy", got.Name)
+	missing, err := s.GetIssueLinkByIssue(ctx, "int-001", "43")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
 
-	// Delete
-	require.NoError(t, s.DeleteProxy(ctx, "proxy-001"))
-	got, _ = s.GetProxy(ctx, "proxy-001")
+	require.NoError(t, s.DeleteIntegration(ctx, "int-001"))
+	byTask, _ = s.GetIssueLinkByTask(ctx, "task-1")
+	assert.Nil(t, byTask)
+	got, _ = s.GetIntegration(ctx, "int-001")
 	assert.Nil(t, got)
 }
 
@@ -592,6 +1876,34 @@ func TestTerminalSessionCRUD(t *testing.T) {
 	url := "http://localhost:8080"
 	require.NoError(t, s.UpdateTerminalSession(ctx, "term-001", model.TerminalStatusRunning, &port, &url))
 
+	// Run 终端：run_id / created_by 往返，过期后被关闭
+	runSession := &model.TerminalSession{
+		ID:        "term-run",
+		RunID:     strPtr("run-1"),
+		CreatedBy: strPtr("user-1"),
+		NodeID:    strPtr("node-1"),
+		Status:    model.TerminalStatusRunning,
+		CreatedAt: now,
+		ExpiresAt: timePtr(now.Add(-time.Minute)),
+	}
+	require.NoError(t, s.CreateTerminalSession(ctx, runSession))
+	got, err = s.GetTerminalSession(ctx, "term-run")
+	require.NoError(t, err)
+	require.NotNil(t, got.RunID)
+	assert.Equal(t, "run-1", *got.RunID)
+	require.NotNil(t, got.CreatedBy)
+	assert.Equal(t, "user-1", *got.CreatedBy)
+
+	closed, err := s.CloseExpiredTerminalSessions(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), closed)
+	got, err = s.GetTerminalSession(ctx, "term-run")
+	require.NoError(t, err)
+	assert.Equal(t, model.TerminalStatusClosed, got.Status)
+	got, err = s.GetTerminalSession(ctx, "term-001")
+	require.NoError(t, err)
+	assert.Equal(t, model.TerminalStatusRunning, got.Status)
+
 	// Delete
 	require.NoError(t, s.DeleteTerminalSession(ctx, "term-001"))
 }
@@ -737,6 +2049,29 @@ func TestHITLConfirmation(t *testing.T) {
 	assert.Equal(t, model.ConfirmStatusConfirmed, got.Status)
 }
 
+func TestCountPendingApprovals(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	task := &model.Task{ID: "task-pa", Name: "T", Status: model.TaskStatusPending, Type: "general", CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.CreateTask(ctx, task))
+	require.NoError(t, s.CreateRun(ctx, &model.Run{ID: "run-pa", TaskID: "task-pa", Status: model.RunStatusRunning, CreatedAt: now, UpdatedAt: now}))
+
+	for i, status := range []model.ApprovalStatus{model.ApprovalStatusPending, model.ApprovalStatusPending, model.ApprovalStatusApproved} {
+		require.NoError(t, s.CreateApprovalRequest(ctx, &model.ApprovalRequest{
+			ID: "apr-pa-" + strconv.Itoa(i), RunID: "run-pa", Type: "dangerous_operation", Status: status, CreatedAt: now,
+		}))
+	}
+	require.NoError(t, s.CreateConfirmation(ctx, &model.Confirmation{
+		ID: "cf-pa", RunID: "run-pa", Type: "deployment", Message: "ok?", Status: model.ConfirmStatusPending, CreatedAt: now,
+	}))
+
+	n, err := s.CountPendingApprovals(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
 // ============================================================================
 // Template 测试
 // ============================================================================
@@ -903,6 +2238,117 @@ func TestSecurityPolicyCRUD(t *testing.T) {
 	require.NoError(t, s.DeleteSecurityPolicy(ctx, "sp-001"))
 }
 
+// ============================================================================
+// Maintenance 测试
+// ============================================================================
+
+func TestMaintenanceRuns(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	run := &model.MaintenanceRun{
+		ID: "mnt-1", Driver: "sqlite", Trigger: model.MaintenanceTriggerManual, DryRun: true,
+		Status: model.MaintenanceStatusRunning, CreatedBy: "admin", StartedAt: now,
+	}
+	require.NoError(t, s.CreateMaintenanceRun(ctx, run))
+	require.NoError(t, s.CreateMaintenanceRun(ctx, &model.MaintenanceRun{
+		ID: "mnt-0", Driver: "sqlite", Trigger: model.MaintenanceTriggerScheduled,
+		Status: model.MaintenanceStatusSuccess, StartedAt: now.Add(-time.Hour),
+	}))
+
+	run.Status = model.MaintenanceStatusSuccess
+	run.Tables = []model.MaintenanceTable{{Table: "events", SizeBytes: 4096, ReclaimableBytes: 1024}}
+	run.ReclaimableBytes = 1024
+	run.FinishedAt = timePtr(now.Add(time.Second))
+	require.NoError(t, s.UpdateMaintenanceRun(ctx, run))
+
+	runs, err := s.ListMaintenanceRuns(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, "mnt-1", runs[0].ID)
+	assert.Equal(t, model.MaintenanceStatusSuccess, runs[0].Status)
+	assert.Equal(t, model.MaintenanceTriggerManual, runs[0].Trigger)
+	assert.True(t, runs[0].DryRun)
+	assert.Equal(t, run.Tables, runs[0].Tables)
+	require.NotNil(t, runs[0].FinishedAt)
+	assert.Nil(t, runs[1].Tables)
+
+	runs, err = s.ListMaintenanceRuns(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, runs, 1)
+}
+
+func TestSQLiteMaintenance(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+
+	estimate, err := s.EstimateMaintenance(ctx, []string{"events", "bad-name"})
+	require.NoError(t, err)
+	require.Len(t, estimate, 3)
+	assert.Empty(t, estimate[0].Error)
+	assert.NotEmpty(t, estimate[1].Error)
+	assert.Equal(t, sqliteDatabaseEntry, estimate[2].Table)
+	assert.Greater(t, estimate[2].SizeBytes, int64(0))
+
+	result, err := s.RunMaintenance(ctx, []string{"events"})
+	require.NoError(t, err)
+	require.Len(t, result, 2)
+	assert.Empty(t, result[0].Error)
+	assert.Empty(t, result[1].Error)
+	require.NotNil(t, result[1].SizeAfterBytes)
+}
+
+// ============================================================================
+// Webhook 测试
+// ============================================================================
+
+func TestWebhooks(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	w := &model.Webhook{
+		ID: "wh-1", Name: "failures", URL: "https://example.com/hook", Secret: "s3cret", Enabled: true,
+		Filter: model.WebhookFilter{
+			EventTypes:    []string{model.WebhookEventRunStatusChanged},
+			ProjectIDs:    []string{"proj-1"},
+			LabelSelector: "env=prod",
+			Transitions:   []string{"*->failed"},
+		},
+		CreatedBy: "admin", CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, s.CreateWebhook(ctx, w))
+
+	got, err := s.GetWebhook(ctx, "wh-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "s3cret", got.Secret)
+	assert.True(t, got.Enabled)
+	assert.Equal(t, w.Filter, got.Filter)
+	assert.Nil(t, got.LastDeliveryAt)
+
+	w.Enabled = false
+	w.Filter.NodeIDs = []string{"node-1"}
+	w.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, s.UpdateWebhook(ctx, w))
+	require.NoError(t, s.RecordWebhookDelivery(ctx, "wh-1", now, 500, "server error"))
+
+	list, err := s.ListWebhooks(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.False(t, list[0].Enabled)
+	assert.Equal(t, []string{"node-1"}, list[0].Filter.NodeIDs)
+	require.NotNil(t, list[0].LastDeliveryAt)
+	assert.Equal(t, 500, list[0].LastStatus)
+	assert.Equal(t, "server error", list[0].LastError)
+
+	require.NoError(t, s.DeleteWebhook(ctx, "wh-1"))
+	got, err = s.GetWebhook(ctx, "wh-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
 // ============================================================================
 // 工厂函数测试
 // ============================================================================
@@ -941,3 +2387,308 @@ func ptrStr(s *string) string {
 }
 
 func timePtr(t time.Time) *time.Time { return &t }
+
+func TestRegistryItems(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	item := &model.RegistryItem{
+		ID: model.RegistryItemID(model.RegistryKindSkill, "skill-lint"), Kind: model.RegistryKindSkill, ItemID: "skill-lint",
+		Source: "official", UpstreamVersion: "1.0.0", UpstreamDigest: "abc", UpstreamSpec: json.RawMessage(`{"name":"lint"}`),
+		SyncedAt: now,
+	}
+	require.NoError(t, s.UpsertRegistryItem(ctx, item))
+
+	got, err := s.GetRegistryItem(ctx, "skill:skill-lint")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "official", got.Source)
+	assert.JSONEq(t, `{"name":"lint"}`, string(got.UpstreamSpec))
+	assert.Nil(t, got.InstalledAt)
+	assert.True(t, got.UpdateAvailable())
+
+	item.InstalledVersion, item.InstalledAt, item.Pinned = "1.0.0", &now, true
+	require.NoError(t, s.UpsertRegistryItem(ctx, item))
+	require.NoError(t, s.UpsertRegistryItem(ctx, &model.RegistryItem{
+		ID: "task_template:tpl-a", Kind: model.RegistryKindTaskTemplate, ItemID: "tpl-a", Source: "official", SyncedAt: now,
+	}))
+
+	list, err := s.ListRegistryItems(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "skill:skill-lint", list[0].ID)
+	assert.True(t, list[0].Pinned)
+	assert.False(t, list[0].UpdateAvailable())
+	require.NotNil(t, list[0].InstalledAt)
+	assert.Nil(t, list[1].UpstreamSpec)
+
+	missing, err := s.GetRegistryItem(ctx, "skill:missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestRunUsage(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	first := &model.RunUsage{
+		RunID: "run-1", TaskID: "task-1", ProjectID: "proj-1", AccountID: "acc-1", AgentType: "claude", Model: "sonnet",
+		InputTokens: 100, OutputTokens: 20, CacheReadTokens: 1000, CostUSD: 0.25,
+		Day: "2026-10-16", CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, s.AddRunUsage(ctx, first))
+	// 再次累加：数值相加，任务、账号、日期保持首次写入的值，未报告模型时保留原模型
+	require.NoError(t, s.AddRunUsage(ctx, &model.RunUsage{
+		RunID: "run-1", TaskID: "task-1", AgentType: "claude",
+		InputTokens: 50, OutputTokens: 5, CacheWriteTokens: 10, CostUSD: 0.5,
+		Day: "2026-10-17", CreatedAt: now.Add(time.Hour), UpdatedAt: now.Add(time.Hour),
+	}))
+
+	got, err := s.GetRunUsage(ctx, "run-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, int64(150), got.InputTokens)
+	assert.Equal(t, int64(25), got.OutputTokens)
+	assert.Equal(t, int64(1000), got.CacheReadTokens)
+	assert.Equal(t, int64(10), got.CacheWriteTokens)
+	assert.InDelta(t, 0.75, got.CostUSD, 1e-9)
+	assert.Equal(t, "sonnet", got.Model)
+	assert.Equal(t, "acc-1", got.AccountID)
+	assert.Equal(t, "proj-1", got.ProjectID)
+	assert.Equal(t, "2026-10-16", got.Day)
+	assert.Equal(t, int64(1185), got.TotalTokens())
+
+	require.NoError(t, s.AddRunUsage(ctx, &model.RunUsage{
+		RunID: "run-2", TaskID: "task-2", AgentType: "gemini", InputTokens: 7, Day: "2026-10-18", CreatedAt: now, UpdatedAt: now,
+	}))
+
+	all, err := s.ListRunUsage(ctx, "", "")
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "run-1", all[0].RunID)
+
+	ranged, err := s.ListRunUsage(ctx, "2026-10-17", "2026-10-18")
+	require.NoError(t, err)
+	require.Len(t, ranged, 1)
+	assert.Equal(t, "run-2", ranged[0].RunID)
+
+	missing, err := s.GetRunUsage(ctx, "run-missing")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestBudgets(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	b := &model.Budget{
+		ID: "budget-1", Name: "team-a", Scope: model.BudgetScopeProject, ScopeID: "proj-1",
+		LimitUSD: 100, AlertThreshold: 0.8, Enabled: true, CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, s.CreateBudget(ctx, b))
+
+	got, err := s.GetBudget(ctx, "budget-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, model.BudgetScopeProject, got.Scope)
+	assert.InDelta(t, 100.0, got.LimitUSD, 1e-9)
+	assert.True(t, got.Enabled)
+	assert.Empty(t, got.ExceededPeriod)
+
+	b.LimitTokens = 1000000
+	b.AlertedPeriod = "2026-10"
+	b.ExceededPeriod = "2026-10"
+	b.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, s.UpdateBudget(ctx, b))
+
+	list, err := s.ListBudgets(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, int64(1000000), list[0].LimitTokens)
+	assert.Equal(t, "2026-10", list[0].AlertedPeriod)
+	assert.Equal(t, "2026-10", list[0].ExceededPeriod)
+
+	require.NoError(t, s.DeleteBudget(ctx, "budget-1"))
+	got, err = s.GetBudget(ctx, "budget-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestAuditLogs(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	base := time.Now().UTC().Truncate(time.Second)
+
+	for i, actor := range []string{"u-1", "u-2", "u-1"} {
+		require.NoError(t, s.CreateAuditLog(ctx, &model.AuditLog{
+			ID: "audit-" + strconv.Itoa(i), ActorID: actor, ActorEmail: actor + "@example.com", Method: "POST",
+			Route: "POST /api/v1/tasks", Path: "/api/v1/tasks", ResourceType: "tasks", ResourceID: "task-" + strconv.Itoa(i),
+			Status: 201, SourceIP: "10.0.0.1", Request: json.RawMessage(`{"name":"t"}`), DurationMs: 3,
+			CreatedAt: base.Add(time.Duration(i) * time.Minute),
+		}))
+	}
+	require.NoError(t, s.CreateAuditLog(ctx, &model.AuditLog{
+		ID: "audit-del", Method: "DELETE", Route: "DELETE /api/v1/webhooks/{id}", Path: "/api/v1/webhooks/wh-1",
+		ResourceType: "webhooks", ResourceID: "wh-1", Status: 204, CreatedAt: base.Add(time.Hour),
+	}))
+
+	all, total, err := s.ListAuditLogs(ctx, storagetypes.AuditFilter{})
+	require.NoError(t, err)
+	assert.Equal(t, 4, total)
+	require.Len(t, all, 4)
+	assert.Equal(t, "audit-del", all[0].ID, "newest first")
+	assert.Nil(t, all[0].Request)
+	assert.JSONEq(t, `{"name":"t"}`, string(all[1].Request))
+	assert.Equal(t, "u-1@example.com", all[1].ActorEmail)
+
+	byActor, total, err := s.ListAuditLogs(ctx, storagetypes.AuditFilter{ActorID: "u-1", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, total)
+	require.Len(t, byActor, 1)
+	assert.Equal(t, "audit-2", byActor[0].ID)
+
+	next, _, err := s.ListAuditLogs(ctx, storagetypes.AuditFilter{
+		ActorID: "u-1", SkipTotal: true,
+		Cursor: &storagetypes.PageCursor{CreatedAt: byActor[0].CreatedAt, ID: byActor[0].ID},
+	})
+	require.NoError(t, err)
+	require.Len(t, next, 1)
+	assert.Equal(t, "audit-0", next[0].ID)
+
+	byResource, _, err := s.ListAuditLogs(ctx, storagetypes.AuditFilter{ResourceType: "webhooks", ResourceID: "wh-1", Method: "DELETE"})
+	require.NoError(t, err)
+	require.Len(t, byResource, 1)
+
+	ranged, _, err := s.ListAuditLogs(ctx, storagetypes.AuditFilter{Since: base.Add(30 * time.Second), Until: base.Add(90 * time.Second)})
+	require.NoError(t, err)
+	require.Len(t, ranged, 1)
+	assert.Equal(t, "audit-1", ranged[0].ID)
+}
+
+// ============================================================================
+// Agent 类型测试
+// ============================================================================
+
+func TestAgentTypes(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	at := &model.AgentTypeConfig{
+		ID: "aichat", Name: "AIChat", Image: "runners/aichat:latest", LoginMethods: []string{"api_key"},
+		Adapter: &model.GenericAdapterSpec{
+			Command: []string{"aichat"},
+			Args:    []string{"{{.Prompt}}"},
+			Rules:   []model.GenericEventRule{{Type: "message", Pattern: `^(?P<text>.+)$`, Payload: map[string]string{"content": "${text}"}}},
+		},
+		CreatedAt: now, UpdatedAt: now,
+	}
+	require.NoError(t, s.CreateAgentType(ctx, at))
+
+	got, err := s.GetAgentType(ctx, "aichat")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, []string{"api_key"}, got.LoginMethods)
+	assert.Equal(t, at.Adapter, got.Adapter)
+
+	at.Image = "runners/aichat:v2"
+	at.Adapter.Output = model.GenericOutputJSON
+	at.UpdatedAt = now.Add(time.Minute)
+	require.NoError(t, s.UpdateAgentType(ctx, at))
+
+	list, err := s.ListAgentTypes(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 1)
+	assert.Equal(t, "runners/aichat:v2", list[0].Image)
+	assert.Equal(t, model.GenericOutputJSON, list[0].Adapter.Output)
+
+	require.NoError(t, s.DeleteAgentType(ctx, "aichat"))
+	got, err = s.GetAgentType(ctx, "aichat")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+// ============================================================================
+// 两步验证与登录会话测试
+// ============================================================================
+
+func TestUserMFA(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	mfa := &model.UserMFA{UserID: "u-1", Secret: "enc-secret", BackupCodes: []string{"h1", "h2"}, CreatedAt: now, UpdatedAt: now}
+	require.NoError(t, s.SaveUserMFA(ctx, mfa))
+	mfa.Enabled = true
+	require.NoError(t, s.SaveUserMFA(ctx, mfa))
+
+	got, err := s.GetUserMFA(ctx, "u-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, got.Enabled)
+	assert.Equal(t, []string{"h1", "h2"}, got.BackupCodes)
+
+	// 同一时间步只能使用一次
+	ok, err := s.UseUserMFAStep(ctx, "u-1", 100)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.UseUserMFAStep(ctx, "u-1", 100)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	// 备用码只能使用一次
+	ok, err = s.ConsumeUserBackupCode(ctx, "u-1", "h1")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.ConsumeUserBackupCode(ctx, "u-1", "h1")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, s.DeleteUserMFA(ctx, "u-1"))
+	got, err = s.GetUserMFA(ctx, "u-1")
+	require.NoError(t, err)
+	assert.Nil(t, got)
+}
+
+func TestUserSessions(t *testing.T) {
+	s := newTestStore(t)
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+
+	for i, id := range []string{"sess-1", "sess-2", "sess-3"} {
+		created := now.Add(time.Duration(i) * time.Minute)
+		require.NoError(t, s.CreateUserSession(ctx, &model.UserSession{
+			ID: id, UserID: "u-1", Method: "password", UserAgent: "curl", IP: "10.0.0.1",
+			CreatedAt: created, LastUsedAt: created, ExpiresAt: now.Add(time.Hour),
+		}))
+	}
+
+	got, err := s.GetUserSession(ctx, "sess-1")
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.Equal(t, "10.0.0.1", got.IP)
+	assert.Nil(t, got.RevokedAt)
+
+	require.NoError(t, s.TouchUserSession(ctx, "sess-1", now.Add(30*time.Minute)))
+	ok, err := s.RevokeUserSession(ctx, "u-1", "sess-2")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = s.RevokeUserSession(ctx, "u-2", "sess-1")
+	require.NoError(t, err)
+	assert.False(t, ok, "不能撤销其他用户的会话")
+
+	list, err := s.ListUserSessions(ctx, "u-1")
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+	assert.Equal(t, "sess-3", list[0].ID)
+
+	n, err := s.RevokeUserSessions(ctx, "u-1", "sess-1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	got, err = s.GetUserSession(ctx, "sess-3")
+	require.NoError(t, err)
+	assert.NotNil(t, got.RevokedAt)
+}
@@ -6,8 +6,6 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"strconv"
-	"strings"
 	"time"
 
 	"agents-admin/internal/shared/model"
@@ -21,6 +19,10 @@ func (s *Store) CreateTask(ctx context.Context, task *model.Task) error {
 	securityJSON, _ := json.Marshal(task.Security)
 	labelsJSON, _ := json.Marshal(task.Labels)
 	contextJSON, _ := json.Marshal(task.Context)
+	hooksJSON, _ := json.Marshal(task.Hooks)
+	secretsJSON, _ := json.Marshal(task.Secrets)
+	schedulingJSON, _ := json.Marshal(task.Scheduling)
+	sessionJSON, _ := json.Marshal(task.Session)
 
 	spec := map[string]interface{}{
 		"prompt": task.Prompt,
@@ -38,32 +40,32 @@ func (s *Store) CreateTask(ctx context.Context, task *model.Task) error {
 	specJSON, _ := json.Marshal(spec)
 
 	query := s.rebind(`
-		INSERT INTO tasks (id, parent_id, name, status, spec, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+		INSERT INTO tasks (id, parent_id, name, status, spec, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24)
 	`)
 	_, err := s.db.ExecContext(ctx, query,
 		task.ID, task.ParentID, task.Name, task.Status, specJSON, task.Type, promptJSON,
-		workspaceJSON, securityJSON, labelsJSON, contextJSON,
-		task.TemplateID, task.AgentID, task.CreatedAt, task.UpdatedAt)
+		workspaceJSON, securityJSON, labelsJSON, contextJSON, hooksJSON, secretsJSON,
+		task.TemplateID, task.AgentID, task.ProjectID, task.Priority.OrDefault(), task.TimeoutSeconds, schedulingJSON, task.Region, task.SpawnedByRunID, sessionJSON, task.CreatedAt, task.UpdatedAt)
 	return err
 }
 
 // GetTask 获取任务
 func (s *Store) GetTask(ctx context.Context, id string) (*model.Task, error) {
-	query := s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at FROM tasks WHERE id = $1`)
+	query := s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at FROM tasks WHERE id = $1`)
 	task := &model.Task{}
-	var promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON []byte
+	var promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON, hooksJSON, secretsJSON, schedulingJSON, sessionJSON []byte
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&task.ID, &task.ParentID, &task.Name, &task.Status, &task.Type, &promptJSON,
-		&workspaceJSON, &securityJSON, &labelsJSON, &contextJSON,
-		&task.TemplateID, &task.AgentID, &task.CreatedAt, &task.UpdatedAt)
+		&workspaceJSON, &securityJSON, &labelsJSON, &contextJSON, &hooksJSON, &secretsJSON,
+		&task.TemplateID, &task.AgentID, &task.ProjectID, &task.Priority, &task.TimeoutSeconds, &schedulingJSON, &task.Region, &task.SpawnedByRunID, &sessionJSON, &task.CreatedAt, &task.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
 	if err != nil {
 		return nil, err
 	}
-	unmarshalJSONFields(task, promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON)
+	unmarshalJSONFields(task, promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON, hooksJSON, secretsJSON, schedulingJSON, sessionJSON)
 	return task, nil
 }
 
@@ -72,20 +74,20 @@ func scanTask(scanner interface {
 	Scan(dest ...interface{}) error
 }) (*model.Task, error) {
 	task := &model.Task{}
-	var promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON []byte
+	var promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON, hooksJSON, secretsJSON, schedulingJSON, sessionJSON []byte
 	err := scanner.Scan(
 		&task.ID, &task.ParentID, &task.Name, &task.Status, &task.Type, &promptJSON,
-		&workspaceJSON, &securityJSON, &labelsJSON, &contextJSON,
-		&task.TemplateID, &task.AgentID, &task.CreatedAt, &task.UpdatedAt)
+		&workspaceJSON, &securityJSON, &labelsJSON, &contextJSON, &hooksJSON, &secretsJSON,
+		&task.TemplateID, &task.AgentID, &task.ProjectID, &task.Priority, &task.TimeoutSeconds, &schedulingJSON, &task.Region, &task.SpawnedByRunID, &sessionJSON, &task.CreatedAt, &task.UpdatedAt)
 	if err != nil {
 		return nil, err
 	}
-	unmarshalJSONFields(task, promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON)
+	unmarshalJSONFields(task, promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON, hooksJSON, secretsJSON, schedulingJSON, sessionJSON)
 	return task, nil
 }
 
 // unmarshalJSONFields 反序列化 Task 的 JSON 字段
-func unmarshalJSONFields(task *model.Task, promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON []byte) {
+func unmarshalJSONFields(task *model.Task, promptJSON, workspaceJSON, securityJSON, labelsJSON, contextJSON, hooksJSON, secretsJSON, schedulingJSON, sessionJSON []byte) {
 	if len(promptJSON) > 0 && string(promptJSON) != "null" {
 		json.Unmarshal(promptJSON, &task.Prompt)
 	}
@@ -101,6 +103,18 @@ func unmarshalJSONFields(task *model.Task, promptJSON, workspaceJSON, securityJS
 	if len(contextJSON) > 0 && string(contextJSON) != "null" {
 		json.Unmarshal(contextJSON, &task.Context)
 	}
+	if len(hooksJSON) > 0 && string(hooksJSON) != "null" {
+		json.Unmarshal(hooksJSON, &task.Hooks)
+	}
+	if len(secretsJSON) > 0 && string(secretsJSON) != "null" {
+		json.Unmarshal(secretsJSON, &task.Secrets)
+	}
+	if len(schedulingJSON) > 0 && string(schedulingJSON) != "null" {
+		json.Unmarshal(schedulingJSON, &task.Scheduling)
+	}
+	if len(sessionJSON) > 0 && string(sessionJSON) != "null" {
+		json.Unmarshal(sessionJSON, &task.Session)
+	}
 }
 
 // ListTasks 列出任务
@@ -109,17 +123,17 @@ func (s *Store) ListTasks(ctx context.Context, status string, limit, offset int)
 	var args []interface{}
 
 	if status != "" {
-		query = s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at 
+		query = s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at 
 				 FROM tasks WHERE status = $1 
 				 ORDER BY created_at DESC LIMIT $2 OFFSET $3`)
 		args = []interface{}{status, limit, offset}
 	} else {
-		query = s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at 
+		query = s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at 
 				 FROM tasks ORDER BY created_at DESC LIMIT $1 OFFSET $2`)
 		args = []interface{}{limit, offset}
 	}
 
-	rows, err := s.db.QueryContext(ctx, query, args...)
+	rows, err := s.reader(ctx).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -136,53 +150,44 @@ func (s *Store) ListTasks(ctx context.Context, status string, limit, offset int)
 	return tasks, rows.Err()
 }
 
-// ListTasksWithFilter 带过滤条件列出任务（支持搜索、时间范围、状态筛选）
+// ListTasksWithFilter 带过滤条件列出任务（支持搜索、时间范围、状态集合、标签、节点筛选与游标分页）
 func (s *Store) ListTasksWithFilter(ctx context.Context, filter storagetypes.TaskFilter) ([]*model.Task, int, error) {
 	// 构建 WHERE 条件
-	conditions := []string{}
-	args := []interface{}{}
-	argIdx := 1
-
-	if filter.Status != "" {
-		conditions = append(conditions, "status = $"+strconv.Itoa(argIdx))
-		args = append(args, filter.Status)
-		argIdx++
-	}
+	var q listQuery
+	q.in("status", filter.StatusSet())
 	if filter.Search != "" {
-		conditions = append(conditions, "name ILIKE $"+strconv.Itoa(argIdx))
-		args = append(args, "%"+filter.Search+"%")
-		argIdx++
+		q.add("name ILIKE ?", "%"+filter.Search+"%")
+	}
+	if filter.ProjectID != "" {
+		q.add("project_id = ?", filter.ProjectID)
 	}
+	if filter.NodeID != "" {
+		q.add("id IN (SELECT task_id FROM runs WHERE node_id = ?)", filter.NodeID)
+	}
+	q.and(s.labelConditions("labels", filter.Labels))
 	if !filter.Since.IsZero() {
-		conditions = append(conditions, "created_at >= $"+strconv.Itoa(argIdx))
-		args = append(args, filter.Since)
-		argIdx++
+		q.add("created_at >= ?", filter.Since)
 	}
 	if !filter.Until.IsZero() {
-		conditions = append(conditions, "created_at <= $"+strconv.Itoa(argIdx))
-		args = append(args, filter.Until)
-		argIdx++
-	}
-
-	where := ""
-	if len(conditions) > 0 {
-		where = " WHERE " + strings.Join(conditions, " AND ")
+		q.add("created_at <= ?", filter.Until)
 	}
 
-	// 查询总数
-	countQuery := s.rebind("SELECT COUNT(*) FROM tasks" + where)
-	var total int
-	if err := s.db.QueryRowContext(ctx, countQuery, args...).Scan(&total); err != nil {
-		return nil, 0, err
+	// 查询总数（游标翻页可跳过）
+	total := -1
+	if !filter.SkipTotal {
+		countQuery := s.rebind(render("SELECT COUNT(*) FROM tasks" + q.where()))
+		if err := s.reader(ctx).QueryRowContext(ctx, countQuery, q.args...).Scan(&total); err != nil {
+			return nil, 0, err
+		}
 	}
 
 	// 查询数据
-	selectCols := "id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at"
-	dataQuery := s.rebind("SELECT " + selectCols + " FROM tasks" + where +
-		" ORDER BY created_at DESC LIMIT $" + strconv.Itoa(argIdx) + " OFFSET $" + strconv.Itoa(argIdx+1))
-	dataArgs := append(args, filter.Limit, filter.Offset)
+	q.cursor(filter.Cursor)
+	page, dataArgs := pageArgs(q, filter.Cursor, filter.Limit, filter.Offset)
+	selectCols := "id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at"
+	dataQuery := s.rebind(render("SELECT " + selectCols + " FROM tasks" + q.where() + " ORDER BY created_at DESC, id DESC" + page))
 
-	rows, err := s.db.QueryContext(ctx, dataQuery, dataArgs...)
+	rows, err := s.reader(ctx).QueryContext(ctx, dataQuery, dataArgs...)
 	if err != nil {
 		return nil, 0, err
 	}
@@ -246,7 +251,7 @@ func (s *Store) UpdateTaskContext(ctx context.Context, id string, taskContext js
 
 // ListSubTasks 列出子任务
 func (s *Store) ListSubTasks(ctx context.Context, parentID string) ([]*model.Task, error) {
-	query := s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at 
+	query := s.rebind(`SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at 
 			  FROM tasks WHERE parent_id = $1 ORDER BY created_at ASC`)
 	rows, err := s.db.QueryContext(ctx, query, parentID)
 	if err != nil {
@@ -273,14 +278,14 @@ func (s *Store) GetTaskTree(ctx context.Context, rootID string) ([]*model.Task,
 
 	query := s.rebind(`
 		WITH RECURSIVE task_tree AS (
-			SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at, 0 as depth
+			SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at, 0 as depth
 			FROM tasks WHERE id = $1
 			UNION ALL
-			SELECT t.id, t.parent_id, t.name, t.status, t.type, t.prompt, t.workspace, t.security, t.labels, t.context, t.template_id, t.agent_id, t.created_at, t.updated_at, tt.depth + 1
+			SELECT t.id, t.parent_id, t.name, t.status, t.type, t.prompt, t.workspace, t.security, t.labels, t.context, t.hooks, t.secrets, t.template_id, t.agent_id, t.project_id, t.priority, t.timeout_seconds, t.scheduling, t.region, t.spawned_by_run_id, t.session, t.created_at, t.updated_at, tt.depth + 1
 			FROM tasks t
 			INNER JOIN task_tree tt ON t.parent_id = tt.id
 		)
-		SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, template_id, agent_id, created_at, updated_at
+		SELECT id, parent_id, name, status, type, prompt, workspace, security, labels, context, hooks, secrets, template_id, agent_id, project_id, priority, timeout_seconds, scheduling, region, spawned_by_run_id, session, created_at, updated_at
 		FROM task_tree ORDER BY depth, created_at ASC
 	`)
 	rows, err := s.db.QueryContext(ctx, query, rootID)
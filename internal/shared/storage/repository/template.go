@@ -19,27 +19,28 @@ func (s *Store) CreateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate
 	workspaceJSON, _ := json.Marshal(tmpl.DefaultWorkspace)
 	securityJSON, _ := json.Marshal(tmpl.DefaultSecurity)
 	labelsJSON, _ := json.Marshal(tmpl.DefaultLabels)
+	hooksJSON, _ := json.Marshal(tmpl.DefaultHooks)
 	varsJSON, _ := json.Marshal(tmpl.Variables)
 
 	query := s.rebind(`
-		INSERT INTO task_templates (id, name, type, description, prompt_template, default_workspace, default_security, default_labels, variables, is_builtin, category, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO task_templates (id, name, type, description, prompt_template, default_workspace, default_security, default_labels, default_hooks, variables, is_builtin, category, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 	`)
 	_, err := s.db.ExecContext(ctx, query,
 		tmpl.ID, tmpl.Name, tmpl.Type, tmpl.Description, promptJSON, workspaceJSON,
-		securityJSON, labelsJSON, varsJSON, tmpl.IsBuiltin, tmpl.Category, tmpl.CreatedAt, tmpl.UpdatedAt)
+		securityJSON, labelsJSON, hooksJSON, varsJSON, tmpl.IsBuiltin, tmpl.Category, tmpl.CreatedAt, tmpl.UpdatedAt)
 	return err
 }
 
 // GetTaskTemplate 获取任务模板
 func (s *Store) GetTaskTemplate(ctx context.Context, id string) (*model.TaskTemplate, error) {
-	query := s.rebind(`SELECT id, name, type, description, prompt_template, default_workspace, default_security, default_labels, variables, is_builtin, category, created_at, updated_at
+	query := s.rebind(`SELECT id, name, type, description, prompt_template, default_workspace, default_security, default_labels, default_hooks, variables, is_builtin, category, created_at, updated_at
 			  FROM task_templates WHERE id = $1`)
 	tmpl := &model.TaskTemplate{}
-	var promptJSON, workspaceJSON, securityJSON, labelsJSON, varsJSON []byte
+	var promptJSON, workspaceJSON, securityJSON, labelsJSON, hooksJSON, varsJSON []byte
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
 		&tmpl.ID, &tmpl.Name, &tmpl.Type, &tmpl.Description, &promptJSON, &workspaceJSON,
-		&securityJSON, &labelsJSON, &varsJSON, &tmpl.IsBuiltin, &tmpl.Category, &tmpl.CreatedAt, &tmpl.UpdatedAt)
+		&securityJSON, &labelsJSON, &hooksJSON, &varsJSON, &tmpl.IsBuiltin, &tmpl.Category, &tmpl.CreatedAt, &tmpl.UpdatedAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -58,6 +59,9 @@ func (s *Store) GetTaskTemplate(ctx context.Context, id string) (*model.TaskTemp
 	if len(labelsJSON) > 0 {
 		json.Unmarshal(labelsJSON, &tmpl.DefaultLabels)
 	}
+	if len(hooksJSON) > 0 && string(hooksJSON) != "null" {
+		json.Unmarshal(hooksJSON, &tmpl.DefaultHooks)
+	}
 	if len(varsJSON) > 0 {
 		json.Unmarshal(varsJSON, &tmpl.Variables)
 	}
@@ -70,11 +74,11 @@ func (s *Store) ListTaskTemplates(ctx context.Context, category string) ([]*mode
 	var args []interface{}
 
 	if category != "" {
-		query = s.rebind(`SELECT id, name, type, description, prompt_template, default_workspace, default_security, default_labels, variables, is_builtin, category, created_at, updated_at
+		query = s.rebind(`SELECT id, name, type, description, prompt_template, default_workspace, default_security, default_labels, default_hooks, variables, is_builtin, category, created_at, updated_at
 				 FROM task_templates WHERE category = $1 ORDER BY name`)
 		args = []interface{}{category}
 	} else {
-		query = `SELECT id, name, type, description, prompt_template, default_workspace, default_security, default_labels, variables, is_builtin, category, created_at, updated_at
+		query = `SELECT id, name, type, description, prompt_template, default_workspace, default_security, default_labels, default_hooks, variables, is_builtin, category, created_at, updated_at
 				 FROM task_templates ORDER BY name`
 	}
 
@@ -87,9 +91,9 @@ func (s *Store) ListTaskTemplates(ctx context.Context, category string) ([]*mode
 	var templates []*model.TaskTemplate
 	for rows.Next() {
 		tmpl := &model.TaskTemplate{}
-		var promptJSON, workspaceJSON, securityJSON, labelsJSON, varsJSON []byte
+		var promptJSON, workspaceJSON, securityJSON, labelsJSON, hooksJSON, varsJSON []byte
 		if err := rows.Scan(&tmpl.ID, &tmpl.Name, &tmpl.Type, &tmpl.Description, &promptJSON, &workspaceJSON,
-			&securityJSON, &labelsJSON, &varsJSON, &tmpl.IsBuiltin, &tmpl.Category, &tmpl.CreatedAt, &tmpl.UpdatedAt); err != nil {
+			&securityJSON, &labelsJSON, &hooksJSON, &varsJSON, &tmpl.IsBuiltin, &tmpl.Category, &tmpl.CreatedAt, &tmpl.UpdatedAt); err != nil {
 			return nil, err
 		}
 		if len(promptJSON) > 0 {
@@ -104,6 +108,9 @@ func (s *Store) ListTaskTemplates(ctx context.Context, category string) ([]*mode
 		if len(labelsJSON) > 0 {
 			json.Unmarshal(labelsJSON, &tmpl.DefaultLabels)
 		}
+		if len(hooksJSON) > 0 && string(hooksJSON) != "null" {
+			json.Unmarshal(hooksJSON, &tmpl.DefaultHooks)
+		}
 		if len(varsJSON) > 0 {
 			json.Unmarshal(varsJSON, &tmpl.Variables)
 		}
@@ -112,6 +119,28 @@ func (s *Store) ListTaskTemplates(ctx context.Context, category string) ([]*mode
 	return templates, rows.Err()
 }
 
+// UpdateTaskTemplate 更新任务模板
+func (s *Store) UpdateTaskTemplate(ctx context.Context, tmpl *model.TaskTemplate) error {
+	promptJSON, _ := json.Marshal(tmpl.PromptTemplate)
+	workspaceJSON, _ := json.Marshal(tmpl.DefaultWorkspace)
+	securityJSON, _ := json.Marshal(tmpl.DefaultSecurity)
+	labelsJSON, _ := json.Marshal(tmpl.DefaultLabels)
+	hooksJSON, _ := json.Marshal(tmpl.DefaultHooks)
+	varsJSON, _ := json.Marshal(tmpl.Variables)
+
+	query := s.rebind(`
+		UPDATE task_templates
+		SET name = $1, type = $2, description = $3, prompt_template = $4, default_workspace = $5,
+		    default_security = $6, default_labels = $7, default_hooks = $8, variables = $9,
+		    category = $10, updated_at = $11
+		WHERE id = $12
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		tmpl.Name, tmpl.Type, tmpl.Description, promptJSON, workspaceJSON,
+		securityJSON, labelsJSON, hooksJSON, varsJSON, tmpl.Category, tmpl.UpdatedAt, tmpl.ID)
+	return err
+}
+
 // DeleteTaskTemplate 删除任务模板
 func (s *Store) DeleteTaskTemplate(ctx context.Context, id string) error {
 	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM task_templates WHERE id = $1`), id)
@@ -11,22 +11,22 @@ import (
 // CreateTerminalSession 创建终端会话
 func (s *Store) CreateTerminalSession(ctx context.Context, session *model.TerminalSession) error {
 	query := s.rebind(`
-		INSERT INTO terminal_sessions (id, instance_id, container_name, node_id, port, url, status, created_at, expires_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO terminal_sessions (id, instance_id, run_id, created_by, container_name, node_id, port, url, status, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 	`)
 	_, err := s.db.ExecContext(ctx, query,
-		session.ID, session.InstanceID, session.ContainerName, session.NodeID,
+		session.ID, session.InstanceID, session.RunID, session.CreatedBy, session.ContainerName, session.NodeID,
 		session.Port, session.URL, session.Status, session.CreatedAt, session.ExpiresAt)
 	return err
 }
 
 // GetTerminalSession 获取终端会话
 func (s *Store) GetTerminalSession(ctx context.Context, id string) (*model.TerminalSession, error) {
-	query := s.rebind(`SELECT id, instance_id, container_name, node_id, port, url, status, created_at, expires_at 
+	query := s.rebind(`SELECT id, instance_id, run_id, created_by, container_name, node_id, port, url, status, created_at, expires_at 
 			  FROM terminal_sessions WHERE id = $1`)
 	session := &model.TerminalSession{}
 	err := s.db.QueryRowContext(ctx, query, id).Scan(
-		&session.ID, &session.InstanceID, &session.ContainerName, &session.NodeID,
+		&session.ID, &session.InstanceID, &session.RunID, &session.CreatedBy, &session.ContainerName, &session.NodeID,
 		&session.Port, &session.URL, &session.Status, &session.CreatedAt, &session.ExpiresAt)
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -36,7 +36,7 @@ func (s *Store) GetTerminalSession(ctx context.Context, id string) (*model.Termi
 
 // ListTerminalSessions 列出所有终端会话
 func (s *Store) ListTerminalSessions(ctx context.Context) ([]*model.TerminalSession, error) {
-	query := `SELECT id, instance_id, container_name, node_id, port, url, status, created_at, expires_at 
+	query := `SELECT id, instance_id, run_id, created_by, container_name, node_id, port, url, status, created_at, expires_at 
 			  FROM terminal_sessions ORDER BY created_at DESC`
 	rows, err := s.db.QueryContext(ctx, query)
 	if err != nil {
@@ -48,7 +48,7 @@ func (s *Store) ListTerminalSessions(ctx context.Context) ([]*model.TerminalSess
 
 // ListTerminalSessionsByNode 列出指定节点的终端会话
 func (s *Store) ListTerminalSessionsByNode(ctx context.Context, nodeID string) ([]*model.TerminalSession, error) {
-	query := s.rebind(`SELECT id, instance_id, container_name, node_id, port, url, status, created_at, expires_at 
+	query := s.rebind(`SELECT id, instance_id, run_id, created_by, container_name, node_id, port, url, status, created_at, expires_at 
 			  FROM terminal_sessions WHERE node_id = $1 ORDER BY created_at DESC`)
 	rows, err := s.db.QueryContext(ctx, query, nodeID)
 	if err != nil {
@@ -60,7 +60,7 @@ func (s *Store) ListTerminalSessionsByNode(ctx context.Context, nodeID string) (
 
 // ListPendingTerminalSessions 列出待处理的终端会话
 func (s *Store) ListPendingTerminalSessions(ctx context.Context, nodeID string) ([]*model.TerminalSession, error) {
-	query := s.rebind(`SELECT id, instance_id, container_name, node_id, port, url, status, created_at, expires_at 
+	query := s.rebind(`SELECT id, instance_id, run_id, created_by, container_name, node_id, port, url, status, created_at, expires_at 
 			  FROM terminal_sessions WHERE node_id = $1 AND status IN ('pending', 'starting') ORDER BY created_at ASC`)
 	rows, err := s.db.QueryContext(ctx, query, nodeID)
 	if err != nil {
@@ -83,6 +83,17 @@ func (s *Store) UpdateTerminalSession(ctx context.Context, id string, status mod
 	return nil
 }
 
+// TransitionTerminalSession 仍处于 from 状态时更新为 to，返回是否更新成功（已关闭的会话不会被节点上报重新打开）
+func (s *Store) TransitionTerminalSession(ctx context.Context, id string, from, to model.TerminalSessionStatus, port *int, url *string) (bool, error) {
+	query := s.rebind(`UPDATE terminal_sessions SET status = $1, port = COALESCE($2, port), url = COALESCE($3, url) WHERE id = $4 AND status = $5`)
+	res, err := s.db.ExecContext(ctx, query, to, port, url, id, from)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
 // DeleteTerminalSession 删除终端会话
 func (s *Store) DeleteTerminalSession(ctx context.Context, id string) error {
 	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM terminal_sessions WHERE id = $1`), id)
@@ -99,11 +110,22 @@ func (s *Store) CleanupExpiredTerminalSessions(ctx context.Context) (int64, erro
 	return result.RowsAffected()
 }
 
+// CloseExpiredTerminalSessions 将已过期的活跃终端会话标记为 closed（NodeManager 随后停止终端）
+func (s *Store) CloseExpiredTerminalSessions(ctx context.Context) (int64, error) {
+	nowExpr := s.now()
+	result, err := s.db.ExecContext(ctx, `UPDATE terminal_sessions SET status = 'closed' WHERE expires_at < `+nowExpr+
+		` AND status IN ('pending', 'starting', 'running')`)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func scanTerminalSessions(rows *sql.Rows) ([]*model.TerminalSession, error) {
 	var sessions []*model.TerminalSession
 	for rows.Next() {
 		session := &model.TerminalSession{}
-		if err := rows.Scan(&session.ID, &session.InstanceID, &session.ContainerName, &session.NodeID,
+		if err := rows.Scan(&session.ID, &session.InstanceID, &session.RunID, &session.CreatedBy, &session.ContainerName, &session.NodeID,
 			&session.Port, &session.URL, &session.Status, &session.CreatedAt, &session.ExpiresAt); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"agents-admin/internal/shared/model"
+)
+
+const runUsageColumns = `run_id, task_id, project_id, account_id, agent_type, model, input_tokens, output_tokens,
+	cache_read_tokens, cache_write_tokens, cost_usd, day, created_at, updated_at`
+
+// AddRunUsage 累加 Run 的 Token 用量与费用（不存在时创建）
+func (s *Store) AddRunUsage(ctx context.Context, usage *model.RunUsage) error {
+	conflict := s.dialect.UpsertConflict("run_id", []string{
+		"input_tokens = run_usage.input_tokens + EXCLUDED.input_tokens",
+		"output_tokens = run_usage.output_tokens + EXCLUDED.output_tokens",
+		"cache_read_tokens = run_usage.cache_read_tokens + EXCLUDED.cache_read_tokens",
+		"cache_write_tokens = run_usage.cache_write_tokens + EXCLUDED.cache_write_tokens",
+		"cost_usd = run_usage.cost_usd + EXCLUDED.cost_usd",
+		"model = CASE WHEN EXCLUDED.model <> '' THEN EXCLUDED.model ELSE run_usage.model END",
+		"updated_at = EXCLUDED.updated_at",
+	})
+	query := s.rebind(fmt.Sprintf(`
+		INSERT INTO run_usage (%s)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		%s
+	`, runUsageColumns, conflict))
+	_, err := s.db.ExecContext(ctx, query, usage.RunID, usage.TaskID, usage.ProjectID, usage.AccountID, usage.AgentType, usage.Model,
+		usage.InputTokens, usage.OutputTokens, usage.CacheReadTokens, usage.CacheWriteTokens, usage.CostUSD,
+		usage.Day, usage.CreatedAt, usage.UpdatedAt)
+	return err
+}
+
+// GetRunUsage 获取 Run 的用量记录
+func (s *Store) GetRunUsage(ctx context.Context, runID string) (*model.RunUsage, error) {
+	query := s.rebind(`SELECT ` + runUsageColumns + ` FROM run_usage WHERE run_id = $1`)
+	usage, err := scanRunUsage(s.db.QueryRowContext(ctx, query, runID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return usage, err
+}
+
+// ListRunUsage 列出日期在 [since, until] 内的用量记录（按日期、Run ID 排序）
+func (s *Store) ListRunUsage(ctx context.Context, since, until string) ([]*model.RunUsage, error) {
+	query := `SELECT ` + runUsageColumns + ` FROM run_usage WHERE 1=1`
+	var args []interface{}
+	if since != "" {
+		args = append(args, since)
+		query += fmt.Sprintf(" AND day >= $%d", len(args))
+	}
+	if until != "" {
+		args = append(args, until)
+		query += fmt.Sprintf(" AND day <= $%d", len(args))
+	}
+	query += " ORDER BY day, run_id"
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usages := []*model.RunUsage{}
+	for rows.Next() {
+		usage, err := scanRunUsage(rows)
+		if err != nil {
+			return nil, err
+		}
+		usages = append(usages, usage)
+	}
+	return usages, rows.Err()
+}
+
+// scanRunUsage 辅助函数：从数据库行扫描 Run 用量记录
+func scanRunUsage(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.RunUsage, error) {
+	u := &model.RunUsage{}
+	if err := scanner.Scan(&u.RunID, &u.TaskID, &u.ProjectID, &u.AccountID, &u.AgentType, &u.Model, &u.InputTokens, &u.OutputTokens,
+		&u.CacheReadTokens, &u.CacheWriteTokens, &u.CostUSD, &u.Day, &u.CreatedAt, &u.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
@@ -55,6 +55,15 @@ func (r *Store) UpdateUserPassword(ctx context.Context, id, passwordHash string)
 	return err
 }
 
+// UpdateUserRole 更新用户角色
+func (r *Store) UpdateUserRole(ctx context.Context, id string, role model.UserRole) error {
+	_, err := r.db.ExecContext(ctx,
+		`UPDATE users SET role = $1, updated_at = NOW() WHERE id = $2`,
+		role, id,
+	)
+	return err
+}
+
 // ListUsers 列出所有用户
 func (r *Store) ListUsers(ctx context.Context) ([]*model.User, error) {
 	rows, err := r.db.QueryContext(ctx,
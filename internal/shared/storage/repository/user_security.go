@@ -0,0 +1,190 @@
+// Package repository 用户两步验证与登录会话相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+// GetUserMFA 获取用户两步验证配置，不存在时返回 nil
+func (s *Store) GetUserMFA(ctx context.Context, userID string) (*model.UserMFA, error) {
+	mfa, _, err := s.getUserMFA(ctx, userID)
+	return mfa, err
+}
+
+// getUserMFA 同时返回备用码列原文（供 ConsumeUserBackupCode 做比较交换）
+func (s *Store) getUserMFA(ctx context.Context, userID string) (*model.UserMFA, string, error) {
+	mfa := &model.UserMFA{}
+	var codes string
+	err := s.db.QueryRowContext(ctx, s.rebind(`
+		SELECT user_id, secret, enabled, backup_codes, last_step, created_at, updated_at
+		FROM user_mfa WHERE user_id = $1`), userID,
+	).Scan(&mfa.UserID, &mfa.Secret, &mfa.Enabled, &codes, &mfa.LastStep, &mfa.CreatedAt, &mfa.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, "", nil
+	}
+	if err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal([]byte(codes), &mfa.BackupCodes); err != nil {
+		return nil, "", err
+	}
+	return mfa, codes, nil
+}
+
+// SaveUserMFA 创建或覆盖用户两步验证配置
+func (s *Store) SaveUserMFA(ctx context.Context, mfa *model.UserMFA) error {
+	codes, err := json.Marshal(mfa.BackupCodes)
+	if err != nil {
+		return err
+	}
+	if mfa.BackupCodes == nil {
+		codes = []byte("[]")
+	}
+	conflict := s.dialect.UpsertConflict("user_id", []string{
+		"secret = EXCLUDED.secret",
+		"enabled = EXCLUDED.enabled",
+		"backup_codes = EXCLUDED.backup_codes",
+		"last_step = EXCLUDED.last_step",
+		"updated_at = EXCLUDED.updated_at",
+	})
+	_, err = s.db.ExecContext(ctx, s.rebind(fmt.Sprintf(`
+		INSERT INTO user_mfa (user_id, secret, enabled, backup_codes, last_step, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		%s`, conflict)),
+		mfa.UserID, mfa.Secret, mfa.Enabled, string(codes), mfa.LastStep, mfa.CreatedAt, mfa.UpdatedAt)
+	return err
+}
+
+// DeleteUserMFA 删除用户两步验证配置
+func (s *Store) DeleteUserMFA(ctx context.Context, userID string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM user_mfa WHERE user_id = $1`), userID)
+	return err
+}
+
+// UseUserMFAStep 记录通过校验的 TOTP 时间步，step 不大于已记录的时间步时返回 false
+func (s *Store) UseUserMFAStep(ctx context.Context, userID string, step int64) (bool, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		UPDATE user_mfa SET last_step = $1 WHERE user_id = $2 AND last_step < $3`), step, userID, step)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// ConsumeUserBackupCode 移除一个备用码，并发使用同一备用码时只有一个成功
+func (s *Store) ConsumeUserBackupCode(ctx context.Context, userID, codeHash string) (bool, error) {
+	mfa, old, err := s.getUserMFA(ctx, userID)
+	if err != nil || mfa == nil {
+		return false, err
+	}
+	i := slices.Index(mfa.BackupCodes, codeHash)
+	if i < 0 {
+		return false, nil
+	}
+	codes, err := json.Marshal(slices.Delete(mfa.BackupCodes, i, i+1))
+	if err != nil {
+		return false, err
+	}
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		UPDATE user_mfa SET backup_codes = $1, updated_at = $2 WHERE user_id = $3 AND backup_codes = $4`),
+		string(codes), time.Now(), userID, old)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// CreateUserSession 创建登录会话
+func (s *Store) CreateUserSession(ctx context.Context, session *model.UserSession) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`
+		INSERT INTO user_sessions (id, user_id, method, user_agent, ip, created_at, last_used_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`),
+		session.ID, session.UserID, session.Method, session.UserAgent, session.IP,
+		session.CreatedAt, session.LastUsedAt, session.ExpiresAt)
+	return err
+}
+
+const userSessionColumns = `id, user_id, method, user_agent, ip, created_at, last_used_at, expires_at, revoked_at`
+
+func scanUserSession(row interface{ Scan(...interface{}) error }) (*model.UserSession, error) {
+	session := &model.UserSession{}
+	var revokedAt sql.NullTime
+	if err := row.Scan(&session.ID, &session.UserID, &session.Method, &session.UserAgent, &session.IP,
+		&session.CreatedAt, &session.LastUsedAt, &session.ExpiresAt, &revokedAt); err != nil {
+		return nil, err
+	}
+	if revokedAt.Valid {
+		session.RevokedAt = &revokedAt.Time
+	}
+	return session, nil
+}
+
+// GetUserSession 获取登录会话，不存在时返回 nil
+func (s *Store) GetUserSession(ctx context.Context, id string) (*model.UserSession, error) {
+	session, err := scanUserSession(s.db.QueryRowContext(ctx,
+		s.rebind(`SELECT `+userSessionColumns+` FROM user_sessions WHERE id = $1`), id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return session, err
+}
+
+// ListUserSessions 列出用户未撤销且未过期的会话
+func (s *Store) ListUserSessions(ctx context.Context, userID string) ([]*model.UserSession, error) {
+	rows, err := s.db.QueryContext(ctx, s.rebind(`
+		SELECT `+userSessionColumns+` FROM user_sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY created_at DESC`), userID, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*model.UserSession{}
+	for rows.Next() {
+		session, err := scanUserSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// TouchUserSession 更新会话最近使用时间
+func (s *Store) TouchUserSession(ctx context.Context, id string, at time.Time) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`UPDATE user_sessions SET last_used_at = $1 WHERE id = $2`), at, id)
+	return err
+}
+
+// RevokeUserSession 撤销用户的一个会话，会话不存在或已撤销时返回 false
+func (s *Store) RevokeUserSession(ctx context.Context, userID, id string) (bool, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		UPDATE user_sessions SET revoked_at = $1 WHERE id = $2 AND user_id = $3 AND revoked_at IS NULL`),
+		time.Now(), id, userID)
+	if err != nil {
+		return false, err
+	}
+	n, err := res.RowsAffected()
+	return n > 0, err
+}
+
+// RevokeUserSessions 撤销用户除 exceptID 外的全部会话，返回撤销数量
+func (s *Store) RevokeUserSessions(ctx context.Context, userID, exceptID string) (int64, error) {
+	res, err := s.db.ExecContext(ctx, s.rebind(`
+		UPDATE user_sessions SET revoked_at = $1 WHERE user_id = $2 AND id <> $3 AND revoked_at IS NULL`),
+		time.Now(), userID, exceptID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"agents-admin/internal/shared/model"
+)
+
+const webhookColumns = `id, name, url, secret, enabled, filter, created_by, last_delivery_at, last_status, last_error,
+	created_at, updated_at`
+
+// CreateWebhook 创建 Webhook 订阅
+func (s *Store) CreateWebhook(ctx context.Context, w *model.Webhook) error {
+	filter, _ := json.Marshal(w.Filter)
+	query := s.rebind(`INSERT INTO webhooks (id, name, url, secret, enabled, filter, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`)
+	_, err := s.db.ExecContext(ctx, query, w.ID, w.Name, w.URL, w.Secret, w.Enabled, filter, w.CreatedBy, w.CreatedAt, w.UpdatedAt)
+	return err
+}
+
+// GetWebhook 获取 Webhook 订阅
+func (s *Store) GetWebhook(ctx context.Context, id string) (*model.Webhook, error) {
+	query := s.rebind(`SELECT ` + webhookColumns + ` FROM webhooks WHERE id = $1`)
+	w, err := scanWebhook(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return w, err
+}
+
+// ListWebhooks 列出所有 Webhook 订阅（按创建时间升序）
+func (s *Store) ListWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY created_at ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	webhooks := []*model.Webhook{}
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, w)
+	}
+	return webhooks, rows.Err()
+}
+
+// UpdateWebhook 更新 Webhook 订阅的名称、地址、密钥、启用状态与过滤条件
+func (s *Store) UpdateWebhook(ctx context.Context, w *model.Webhook) error {
+	filter, _ := json.Marshal(w.Filter)
+	query := s.rebind(`UPDATE webhooks SET name = $1, url = $2, secret = $3, enabled = $4, filter = $5, updated_at = $6
+		WHERE id = $7`)
+	_, err := s.db.ExecContext(ctx, query, w.Name, w.URL, w.Secret, w.Enabled, filter, w.UpdatedAt, w.ID)
+	return err
+}
+
+// DeleteWebhook 删除 Webhook 订阅
+func (s *Store) DeleteWebhook(ctx context.Context, id string) error {
+	_, err := s.db.ExecContext(ctx, s.rebind(`DELETE FROM webhooks WHERE id = $1`), id)
+	return err
+}
+
+// RecordWebhookDelivery 记录最近一次投递结果
+func (s *Store) RecordWebhookDelivery(ctx context.Context, id string, at time.Time, status int, errMsg string) error {
+	query := s.rebind(`UPDATE webhooks SET last_delivery_at = $1, last_status = $2, last_error = $3 WHERE id = $4`)
+	_, err := s.db.ExecContext(ctx, query, at, status, errMsg, id)
+	return err
+}
+
+// scanWebhook 辅助函数：从数据库行扫描 Webhook
+func scanWebhook(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.Webhook, error) {
+	w := &model.Webhook{}
+	var filter []byte
+	if err := scanner.Scan(&w.ID, &w.Name, &w.URL, &w.Secret, &w.Enabled, &filter, &w.CreatedBy, &w.LastDeliveryAt,
+		&w.LastStatus, &w.LastError, &w.CreatedAt, &w.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if len(filter) > 0 && string(filter) != "null" {
+		json.Unmarshal(filter, &w.Filter)
+	}
+	return w, nil
+}
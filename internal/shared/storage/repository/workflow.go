@@ -0,0 +1,101 @@
+// Package repository Workflow 相关的存储操作
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strconv"
+
+	"agents-admin/internal/shared/model"
+)
+
+const workflowColumns = `id, name, root_task_id, mode, status, nodes, created_at, updated_at, finished_at`
+
+// CreateWorkflow 创建工作流
+func (s *Store) CreateWorkflow(ctx context.Context, wf *model.Workflow) error {
+	nodesJSON, _ := json.Marshal(wf.Nodes)
+	query := s.rebind(`
+		INSERT INTO workflows (` + workflowColumns + `)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`)
+	_, err := s.db.ExecContext(ctx, query,
+		wf.ID, wf.Name, wf.RootTaskID, wf.Mode, wf.Status, nodesJSON,
+		wf.CreatedAt, wf.UpdatedAt, wf.FinishedAt)
+	return err
+}
+
+// GetWorkflow 获取工作流
+func (s *Store) GetWorkflow(ctx context.Context, id string) (*model.Workflow, error) {
+	query := s.rebind(`SELECT ` + workflowColumns + ` FROM workflows WHERE id = $1`)
+	wf, err := scanWorkflow(s.db.QueryRowContext(ctx, query, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return wf, err
+}
+
+// ListWorkflows 列出工作流（支持状态过滤，按创建时间倒序）
+func (s *Store) ListWorkflows(ctx context.Context, status string, limit, offset int) ([]*model.Workflow, error) {
+	query := `SELECT ` + workflowColumns + ` FROM workflows WHERE 1=1`
+	args := []interface{}{}
+	argIdx := 1
+
+	if status != "" {
+		query += ` AND status = $` + strconv.Itoa(argIdx)
+		args = append(args, status)
+		argIdx++
+	}
+
+	query += ` ORDER BY created_at DESC`
+
+	if limit > 0 {
+		query += ` LIMIT $` + strconv.Itoa(argIdx)
+		args = append(args, limit)
+		argIdx++
+		if offset > 0 {
+			query += ` OFFSET $` + strconv.Itoa(argIdx)
+			args = append(args, offset)
+		}
+	}
+
+	rows, err := s.db.QueryContext(ctx, s.rebind(query), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	workflows := []*model.Workflow{}
+	for rows.Next() {
+		wf, err := scanWorkflow(rows)
+		if err != nil {
+			return nil, err
+		}
+		workflows = append(workflows, wf)
+	}
+	return workflows, rows.Err()
+}
+
+// UpdateWorkflow 更新工作流状态、节点与结束时间
+func (s *Store) UpdateWorkflow(ctx context.Context, wf *model.Workflow) error {
+	nodesJSON, _ := json.Marshal(wf.Nodes)
+	query := s.rebind(`UPDATE workflows SET status = $1, nodes = $2, updated_at = $3, finished_at = $4 WHERE id = $5`)
+	_, err := s.db.ExecContext(ctx, query, wf.Status, nodesJSON, wf.UpdatedAt, wf.FinishedAt, wf.ID)
+	return err
+}
+
+// scanWorkflow 辅助函数：从数据库行扫描 Workflow
+func scanWorkflow(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*model.Workflow, error) {
+	wf := &model.Workflow{}
+	var nodesJSON []byte
+	if err := scanner.Scan(&wf.ID, &wf.Name, &wf.RootTaskID, &wf.Mode, &wf.Status, &nodesJSON,
+		&wf.CreatedAt, &wf.UpdatedAt, &wf.FinishedAt); err != nil {
+		return nil, err
+	}
+	if len(nodesJSON) > 0 && string(nodesJSON) != "null" {
+		json.Unmarshal(nodesJSON, &wf.Nodes)
+	}
+	return wf, nil
+}
@@ -0,0 +1,140 @@
+package storagetypes
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================================================================
+// 列表查询：游标分页与标签选择器
+// ============================================================================
+
+// PageCursor 游标分页位置：上一页最后一条记录的 (created_at, id)
+//
+// 列表按 created_at DESC, id DESC 排序，下一页从严格小于该位置的记录开始，
+// 翻页代价与页码无关（OFFSET 需要扫描并丢弃之前的全部行）。
+type PageCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Encode 编码为不透明的游标字符串（base64url）
+func (c PageCursor) Encode() string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// ParsePageCursor 解析 Encode 生成的游标字符串
+func ParsePageCursor(s string) (*PageCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	ts, id, ok := strings.Cut(string(raw), ":")
+	nanos, err := strconv.ParseInt(ts, 10, 64)
+	if !ok || err != nil || id == "" {
+		return nil, errors.New("invalid cursor")
+	}
+	return &PageCursor{CreatedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
+
+// LabelOp 标签选择器操作符
+type LabelOp int
+
+const (
+	LabelEquals    LabelOp = iota // k=v
+	LabelNotEquals                // k!=v（不存在该标签也匹配）
+	LabelExists                   // k
+	LabelNotExists                // !k
+)
+
+// LabelRequirement 标签选择器中的一个条件
+type LabelRequirement struct {
+	Key   string
+	Op    LabelOp
+	Value string
+}
+
+// Matches 标签集合是否满足该条件
+func (r LabelRequirement) Matches(labels map[string]string) bool {
+	v, ok := labels[r.Key]
+	switch r.Op {
+	case LabelEquals:
+		return ok && v == r.Value
+	case LabelNotEquals:
+		return !ok || v != r.Value
+	case LabelExists:
+		return ok
+	default:
+		return !ok
+	}
+}
+
+// ParseLabelSelector 解析标签选择器：逗号分隔的 k=v、k!=v、k（存在）、!k（不存在），条件之间为 AND
+func ParseLabelSelector(selector string) ([]LabelRequirement, error) {
+	var reqs []LabelRequirement
+	for _, part := range strings.Split(selector, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var req LabelRequirement
+		switch {
+		case strings.Contains(part, "!="):
+			k, v, _ := strings.Cut(part, "!=")
+			req = LabelRequirement{Key: strings.TrimSpace(k), Op: LabelNotEquals, Value: strings.TrimSpace(v)}
+		case strings.Contains(part, "="):
+			k, v, _ := strings.Cut(part, "=")
+			req = LabelRequirement{Key: strings.TrimSpace(k), Op: LabelEquals, Value: strings.TrimSpace(v)}
+		case strings.HasPrefix(part, "!"):
+			req = LabelRequirement{Key: strings.TrimSpace(part[1:]), Op: LabelNotExists}
+		default:
+			req = LabelRequirement{Key: part, Op: LabelExists}
+		}
+		if req.Key == "" || strings.ContainsAny(req.Key, "=! ") {
+			return nil, fmt.Errorf("invalid label selector %q", part)
+		}
+		reqs = append(reqs, req)
+	}
+	return reqs, nil
+}
+
+// RunFilter Run 查询过滤条件
+type RunFilter struct {
+	Statuses  []string           // 状态集合（任一匹配）
+	TaskID    string             // 所属任务
+	NodeID    string             // 执行节点
+	Labels    []LabelRequirement // 所属任务的标签选择器
+	Since     time.Time          // 创建时间下限
+	Until     time.Time          // 创建时间上限
+	Cursor    *PageCursor        // 游标（非 nil 时忽略 Offset）
+	SkipTotal bool               // 不统计总数（返回 -1），避免大表 COUNT
+	Limit     int
+	Offset    int
+}
+
+// AuditFilter 审计日志查询过滤条件
+type AuditFilter struct {
+	ActorID      string      // 操作者用户 ID
+	Method       string      // HTTP 方法
+	ResourceType string      // 资源类型
+	ResourceID   string      // 资源 ID
+	Since        time.Time   // 请求时间下限
+	Until        time.Time   // 请求时间上限
+	Cursor       *PageCursor // 游标（非 nil 时忽略 Offset）
+	SkipTotal    bool        // 不统计总数（返回 -1）
+	Limit        int
+	Offset       int
+}
+
+// ArchiveRunFilter 待归档 Run 的查询条件（finished_at 早于 Before、尚未归档的终态 Run）
+type ArchiveRunFilter struct {
+	Before            time.Time // 结束时间上限
+	ProjectID         string    // 只列出该项目的 Run
+	ExcludeProjectIDs []string  // 排除这些项目的 Run（由项目保留策略单独处理）
+	Limit             int
+}
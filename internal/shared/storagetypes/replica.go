@@ -0,0 +1,32 @@
+package storagetypes
+
+import (
+	"context"
+	"time"
+)
+
+// readReplicaKey 上下文标记：允许读取只读副本
+type readReplicaKey struct{}
+
+// WithReadReplica 标记查询可以读取只读副本（容忍秒级复制延迟）
+//
+// 仅用于列表、事件回放、监控聚合等展示类读请求；读后写的场景（如计算下一个事件序号）必须读主库，不要使用。
+// 未配置副本或副本不可用、延迟超限时仍读主库。
+func WithReadReplica(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readReplicaKey{}, true)
+}
+
+// ReadReplicaAllowed 查询是否允许读取只读副本
+func ReadReplicaAllowed(ctx context.Context) bool {
+	allowed, _ := ctx.Value(readReplicaKey{}).(bool)
+	return allowed
+}
+
+// ReplicaStatus 只读副本状态
+type ReplicaStatus struct {
+	Usable     bool      `json:"usable"`          // 当前是否路由读请求到副本
+	LagSeconds float64   `json:"lag_seconds"`     // 最近一次检查的复制延迟
+	MaxLag     string    `json:"max_lag"`         // 允许的最大延迟
+	CheckedAt  time.Time `json:"checked_at"`      // 最近一次检查时间
+	Error      string    `json:"error,omitempty"` // 最近一次检查失败原因
+}
@@ -167,10 +167,32 @@ const (
 
 // TaskFilter 任务查询过滤条件
 type TaskFilter struct {
-	Status string    // 状态筛选
-	Search string    // 名称模糊搜索
-	Since  time.Time // 创建时间下限
-	Until  time.Time // 创建时间上限
+	Status    string             // 状态筛选
+	Statuses  []string           // 状态集合（任一匹配，与 Status 合并）
+	Search    string             // 名称模糊搜索
+	ProjectID string             // 项目筛选
+	NodeID    string             // 有 Run 分配到该节点的任务
+	Labels    []LabelRequirement // 标签选择器
+	Since     time.Time          // 创建时间下限
+	Until     time.Time          // 创建时间上限
+	Cursor    *PageCursor        // 游标（非 nil 时忽略 Offset）
+	SkipTotal bool               // 不统计总数（返回 -1），避免大表 COUNT
+	Limit     int
+	Offset    int
+}
+
+// StatusSet 合并 Status 与 Statuses 后的状态集合
+func (f TaskFilter) StatusSet() []string {
+	if f.Status == "" {
+		return f.Statuses
+	}
+	return append([]string{f.Status}, f.Statuses...)
+}
+
+// EventSearchFilter 事件全文检索条件
+type EventSearchFilter struct {
+	Query  string // 检索关键词（空白分隔，多个关键词需同时命中）
+	RunID  string // 限定 Run（可选）
 	Limit  int
 	Offset int
 }
@@ -146,3 +146,27 @@ func TestGenerateServiceFileFormat(t *testing.T) {
 		t.Error("service file should contain [Install] section")
 	}
 }
+
+func TestIsAirGappedEnv(t *testing.T) {
+	t.Setenv(EnvAirGapped, "1")
+	if !IsAirGapped() {
+		t.Error("IsAirGapped() should be true when AGENTS_ADMIN_AIRGAPPED=1")
+	}
+	t.Setenv(EnvAirGapped, "false")
+	if IsAirGapped() {
+		t.Error("IsAirGapped() should be false when AGENTS_ADMIN_AIRGAPPED=false")
+	}
+}
+
+func TestLoadOfflineManifest(t *testing.T) {
+	path := t.TempDir() + "/" + OfflineManifestFile
+	os.WriteFile(path, []byte(`{"version":"1.2.0","arch":"amd64","images":["mongo:7","redis:7-alpine"]}`), 0644)
+
+	m, err := LoadOfflineManifest(path)
+	if err != nil {
+		t.Fatalf("LoadOfflineManifest: %v", err)
+	}
+	if m.Version != "1.2.0" || len(m.Images) != 2 {
+		t.Errorf("unexpected manifest: %+v", m)
+	}
+}
@@ -0,0 +1,67 @@
+package sysinstall
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// OfflineManifestFile 离线安装包清单文件名
+	// 由 scripts/build-offline-bundle.sh 生成，bootstrap 脚本安装时复制到 ConfigDir
+	OfflineManifestFile = "offline-bundle.json"
+
+	// EnvAirGapped 强制启用/禁用离线模式的环境变量（"1"/"true" 启用，"0"/"false" 禁用）
+	EnvAirGapped = "AGENTS_ADMIN_AIRGAPPED"
+)
+
+// OfflineManifest 离线安装包清单
+type OfflineManifest struct {
+	Version   string    `json:"version"`
+	Arch      string    `json:"arch"`
+	CreatedAt time.Time `json:"created_at"`
+	Images    []string  `json:"images"` // 已打包的容器镜像（docker save tarball）
+}
+
+// IsAirGapped 检测是否处于离线（air-gapped）安装模式
+//
+// 判定顺序：
+//  1. AGENTS_ADMIN_AIRGAPPED 环境变量（显式开关）
+//  2. ConfigDir 下存在离线清单文件
+//  3. 二进制所在目录存在离线清单文件（解压后直接运行）
+func IsAirGapped() bool {
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv(EnvAirGapped))); v != "" {
+		return v == "1" || v == "true" || v == "yes"
+	}
+	return FindOfflineManifest() != ""
+}
+
+// FindOfflineManifest 查找离线清单文件路径，不存在时返回空字符串
+func FindOfflineManifest() string {
+	candidates := []string{filepath.Join(ConfigDir, OfflineManifestFile)}
+	if exe := GetExecutablePath(); exe != "" {
+		candidates = append(candidates, filepath.Join(filepath.Dir(exe), OfflineManifestFile))
+	}
+	for _, c := range candidates {
+		if _, err := os.Stat(c); err == nil {
+			return c
+		}
+	}
+	return ""
+}
+
+// LoadOfflineManifest 读取离线清单
+func LoadOfflineManifest(path string) (*OfflineManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m OfflineManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("invalid offline manifest %s: %w", path, err)
+	}
+	return &m, nil
+}
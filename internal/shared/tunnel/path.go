@@ -0,0 +1,25 @@
+package tunnel
+
+import (
+	"errors"
+	"path"
+	"strings"
+)
+
+// MaxFileSize 文件获取的单个文件大小上限，超出时节点以 ErrTooLarge 拒绝
+const MaxFileSize = 10 * 1024 * 1024
+
+// ErrInvalidPath 文件路径不是工作目录内的相对路径
+var ErrInvalidPath = errors.New("path must be relative to the run working directory")
+
+// CleanRunPath 规范化文件获取路径：只允许工作目录内的相对路径（不能是绝对路径或以 .. 跳出）
+func CleanRunPath(p string) (string, error) {
+	if p == "" || strings.HasPrefix(p, "/") || strings.ContainsRune(p, 0) {
+		return "", ErrInvalidPath
+	}
+	cleaned := path.Clean(p)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", ErrInvalidPath
+	}
+	return cleaned, nil
+}
@@ -0,0 +1,269 @@
+package tunnel
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"time"
+)
+
+// errStreamOverflow 对端写入过快、本端未及时读取
+var errStreamOverflow = errors.New("stream buffer overflow")
+
+// Stream 隧道上的一条双向流，实现 net.Conn
+type Stream struct {
+	id      uint32
+	session *Session
+
+	ack        chan string // 本端打开的流：接收对端的打开结果
+	accepted   bool        // 已接受（对端打开的流由处理函数接受）
+	acceptOnce sync.Once
+
+	mu            sync.Mutex
+	buf           bytes.Buffer
+	readable      chan struct{} // 有新数据、关闭或读超时变更时通知阻塞的 Read
+	readErr       error         // 对端关闭后 Read 返回的错误
+	remoteClosed  bool
+	localClosed   bool
+	readDeadline  time.Time
+	writeDeadline time.Time
+}
+
+func newStream(s *Session, id uint32) *Stream {
+	return &Stream{id: id, session: s, readable: make(chan struct{}, 1)}
+}
+
+// Accept 接受对端打开的流，对本端打开的流无效果
+func (st *Stream) Accept() error {
+	if st.ack != nil {
+		return nil
+	}
+	var err error
+	st.acceptOnce.Do(func() {
+		st.mu.Lock()
+		st.accepted = true
+		st.mu.Unlock()
+		err = st.session.writeFrame(frameAck, st.id, nil)
+	})
+	return err
+}
+
+// Read 读取对端写入的数据
+func (st *Stream) Read(p []byte) (int, error) {
+	for {
+		st.mu.Lock()
+		if st.buf.Len() > 0 {
+			n, _ := st.buf.Read(p)
+			st.mu.Unlock()
+			return n, nil
+		}
+		if st.localClosed {
+			st.mu.Unlock()
+			return 0, net.ErrClosed
+		}
+		if st.remoteClosed {
+			err := st.readErr
+			st.mu.Unlock()
+			return 0, err
+		}
+		deadline := st.readDeadline
+		st.mu.Unlock()
+
+		if deadline.IsZero() {
+			<-st.readable
+			continue
+		}
+		d := time.Until(deadline)
+		if d <= 0 {
+			return 0, os.ErrDeadlineExceeded
+		}
+		timer := time.NewTimer(d)
+		select {
+		case <-st.readable:
+			timer.Stop()
+		case <-timer.C:
+			return 0, os.ErrDeadlineExceeded
+		}
+	}
+}
+
+// Write 向对端写入数据，对端打开的流在首次写入时自动接受
+func (st *Stream) Write(p []byte) (int, error) {
+	if err := st.Accept(); err != nil {
+		return 0, err
+	}
+	written := 0
+	for len(p) > 0 {
+		st.mu.Lock()
+		closed, remoteClosed, deadline := st.localClosed, st.remoteClosed, st.writeDeadline
+		st.mu.Unlock()
+		switch {
+		case closed:
+			return written, net.ErrClosed
+		case remoteClosed:
+			return written, io.ErrClosedPipe
+		case !deadline.IsZero() && !time.Now().Before(deadline):
+			return written, os.ErrDeadlineExceeded
+		}
+
+		n := len(p)
+		if n > maxFrameData {
+			n = maxFrameData
+		}
+		if err := st.session.writeFrame(frameData, st.id, p[:n]); err != nil {
+			return written, err
+		}
+		written += n
+		p = p[n:]
+	}
+	return written, nil
+}
+
+// Close 关闭流
+func (st *Stream) Close() error {
+	return st.CloseWithError(nil)
+}
+
+// CloseWithError 关闭流，err 非 nil 时作为对端 Read 返回的错误
+func (st *Stream) CloseWithError(err error) error {
+	st.mu.Lock()
+	if st.localClosed {
+		st.mu.Unlock()
+		return nil
+	}
+	st.localClosed = true
+	notify := !st.remoteClosed
+	st.mu.Unlock()
+	st.signal()
+	st.session.remove(st.id)
+
+	if !notify {
+		return nil
+	}
+	var msg []byte
+	if err != nil {
+		msg = []byte(err.Error())
+	}
+	return st.session.writeFrame(frameClose, st.id, msg)
+}
+
+// ID 流 ID
+func (st *Stream) ID() uint32 { return st.id }
+
+// LocalAddr 实现 net.Conn
+func (st *Stream) LocalAddr() net.Addr { return streamAddr(st.id) }
+
+// RemoteAddr 实现 net.Conn
+func (st *Stream) RemoteAddr() net.Addr { return streamAddr(st.id) }
+
+// SetDeadline 实现 net.Conn
+func (st *Stream) SetDeadline(t time.Time) error {
+	st.SetReadDeadline(t)
+	return st.SetWriteDeadline(t)
+}
+
+// SetReadDeadline 实现 net.Conn
+func (st *Stream) SetReadDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.readDeadline = t
+	st.mu.Unlock()
+	st.signal()
+	return nil
+}
+
+// SetWriteDeadline 实现 net.Conn（在写入每个数据帧前检查）
+func (st *Stream) SetWriteDeadline(t time.Time) error {
+	st.mu.Lock()
+	st.writeDeadline = t
+	st.mu.Unlock()
+	return nil
+}
+
+// push 追加对端数据，超出缓冲上限时重置流
+func (st *Stream) push(p []byte) {
+	st.mu.Lock()
+	if st.localClosed || st.remoteClosed {
+		st.mu.Unlock()
+		return
+	}
+	if st.buf.Len()+len(p) > maxStreamBuffer {
+		st.buf.Reset()
+		st.remoteClosed = true
+		st.readErr = errStreamOverflow
+		st.mu.Unlock()
+		st.signal()
+		st.session.remove(st.id)
+		st.session.writeFrame(frameClose, st.id, []byte(errStreamOverflow.Error()))
+		return
+	}
+	st.buf.Write(p)
+	st.mu.Unlock()
+	st.signal()
+}
+
+// remoteClose 对端关闭流
+func (st *Stream) remoteClose(msg string) {
+	var err error = io.EOF
+	if msg != "" {
+		err = fmt.Errorf("%s", msg)
+	}
+	st.terminate(err)
+	st.mu.Lock()
+	done := st.localClosed
+	st.mu.Unlock()
+	if done {
+		st.session.remove(st.id)
+	}
+}
+
+// terminate 标记对端不再发送数据，缓冲中的数据读完后 Read 返回 err
+func (st *Stream) terminate(err error) {
+	st.mu.Lock()
+	if !st.remoteClosed {
+		st.remoteClosed = true
+		st.readErr = err
+	}
+	st.mu.Unlock()
+	st.signal()
+}
+
+// finish 处理函数返回：未接受时拒绝，否则关闭流
+func (st *Stream) finish(err error) {
+	st.mu.Lock()
+	accepted := st.accepted
+	st.mu.Unlock()
+	if accepted {
+		st.CloseWithError(err)
+		return
+	}
+
+	// 未接受的流不会再被使用，acceptOnce 防止处理函数遗留的写入再发送 ack
+	st.acceptOnce.Do(func() {})
+	reason := "stream rejected"
+	if err != nil {
+		reason = err.Error()
+	}
+	st.mu.Lock()
+	st.localClosed = true
+	st.mu.Unlock()
+	st.session.remove(st.id)
+	st.session.writeFrame(frameAck, st.id, []byte(reason))
+}
+
+// signal 唤醒阻塞的 Read
+func (st *Stream) signal() {
+	select {
+	case st.readable <- struct{}{}:
+	default:
+	}
+}
+
+// streamAddr 流地址
+type streamAddr uint32
+
+func (a streamAddr) Network() string { return "tunnel" }
+func (a streamAddr) String() string  { return fmt.Sprintf("tunnel:%d", uint32(a)) }
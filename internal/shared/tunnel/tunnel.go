@@ -0,0 +1,338 @@
+// Package tunnel 节点反向隧道协议
+//
+// NodeManager 主动连接 API Server 建立一条 WebSocket 长连接，API Server 在其上按需打开
+// 多路双向流（终端、文件获取、实时日志），节点位于 NAT 之后也不需要开放任何入站端口。
+//
+// 帧格式（WebSocket 二进制消息）：
+//
+//	[1 字节帧类型][4 字节流 ID，大端][载荷]
+//
+// 帧类型：
+//   - open：打开流，载荷为 OpenRequest JSON
+//   - ack：打开结果，载荷为空表示接受，否则为拒绝原因
+//   - data：流数据
+//   - close：关闭流，载荷非空时为错误信息
+//
+// 流实现 net.Conn，可直接用于 HTTP Transport 的 DialContext 与 TCP 双向转发。
+// 每个流的未读数据有上限，读取过慢的流被重置，不会阻塞同一隧道上的其他流。
+package tunnel
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// 流类型
+const (
+	KindTerminal = "terminal" // 节点本地 Web 终端（ttyd）的 TCP 连接，参数 session_id
+	KindFile     = "file"     // 读取运行中 Run 工作目录下的文件，参数 run_id、path
+	KindLogs     = "logs"     // 运行中 Run 的实时输出，参数 run_id
+)
+
+// 帧类型
+const (
+	frameOpen  byte = 1
+	frameAck   byte = 2
+	frameData  byte = 3
+	frameClose byte = 4
+)
+
+const (
+	headerSize      = 5
+	maxFrameData    = 32 * 1024       // 单个 data 帧的最大载荷
+	maxStreamBuffer = 4 * 1024 * 1024 // 单个流的未读数据上限，超出时重置该流
+	openTimeout     = 15 * time.Second
+	pingInterval    = 30 * time.Second
+	readTimeout     = 75 * time.Second // 超过该时间未收到任何消息（含 pong）视为连接断开
+	writeTimeout    = 10 * time.Second
+)
+
+// ErrSessionClosed 隧道已断开
+var ErrSessionClosed = errors.New("tunnel closed")
+
+// 节点拒绝打开流的常见原因，处理函数以 fmt.Errorf("%w: ...", ErrNotFound) 返回，
+// 打开方可用 errors.Is 判断（API Server 据此映射 HTTP 状态码）
+var (
+	ErrNotFound = errors.New("not found")
+	ErrTooLarge = errors.New("too large")
+)
+
+// RejectedError 对端拒绝打开流
+type RejectedError struct {
+	Reason string
+}
+
+func (e *RejectedError) Error() string { return e.Reason }
+
+// Is 按拒绝原因前缀匹配 ErrNotFound 等错误
+func (e *RejectedError) Is(target error) bool {
+	prefix := target.Error()
+	return e.Reason == prefix || strings.HasPrefix(e.Reason, prefix+":")
+}
+
+// OpenRequest 打开流的请求
+type OpenRequest struct {
+	Kind   string            `json:"kind"`
+	Params map[string]string `json:"params,omitempty"`
+}
+
+// Handler 处理对端打开的流
+//
+// 处理函数调用 Stream.Accept（或直接写入数据）表示接受；返回前未接受时以返回的错误拒绝，
+// 对端 Open 返回该错误。已接受的流在处理函数返回后关闭，返回的错误作为关闭原因。
+type Handler func(s *Stream, req OpenRequest) error
+
+// Session 一条隧道连接上的多路复用会话
+type Session struct {
+	conn    *websocket.Conn
+	handler Handler
+
+	writeMu sync.Mutex // 串行化 WebSocket 数据帧写入
+
+	mu      sync.Mutex
+	streams map[uint32]*Stream
+	nextID  uint32
+
+	done      chan struct{}
+	closeOnce sync.Once
+	err       error
+}
+
+// NewSession 在已建立的 WebSocket 连接上创建会话
+//
+// client 为 true 时本端打开的流使用奇数 ID，否则使用偶数 ID，双方可同时打开流。
+// handler 为 nil 时拒绝对端打开的所有流。调用 Serve 开始收发。
+func NewSession(conn *websocket.Conn, client bool, handler Handler) *Session {
+	s := &Session{
+		conn:    conn,
+		handler: handler,
+		streams: make(map[uint32]*Stream),
+		nextID:  2,
+		done:    make(chan struct{}),
+	}
+	if client {
+		s.nextID = 1
+	}
+	return s
+}
+
+// Serve 读取并分发帧，直到连接断开或调用 Close，返回断开原因
+func (s *Session) Serve() error {
+	go s.keepalive()
+
+	s.conn.SetReadDeadline(time.Now().Add(readTimeout))
+	s.conn.SetPongHandler(func(string) error {
+		return s.conn.SetReadDeadline(time.Now().Add(readTimeout))
+	})
+	for {
+		msgType, msg, err := s.conn.ReadMessage()
+		if err != nil {
+			s.shutdown(err)
+			return s.Err()
+		}
+		s.conn.SetReadDeadline(time.Now().Add(readTimeout))
+		if msgType != websocket.BinaryMessage || len(msg) < headerSize {
+			continue
+		}
+		s.dispatch(msg[0], binary.BigEndian.Uint32(msg[1:headerSize]), msg[headerSize:])
+	}
+}
+
+// Open 打开到对端的流，对端拒绝时返回拒绝原因
+func (s *Session) Open(ctx context.Context, req OpenRequest) (*Stream, error) {
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.isClosed() {
+		s.mu.Unlock()
+		return nil, ErrSessionClosed
+	}
+	id := s.nextID
+	s.nextID += 2
+	st := newStream(s, id)
+	st.ack = make(chan string, 1)
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	if err := s.writeFrame(frameOpen, id, payload); err != nil {
+		s.remove(id)
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, openTimeout)
+	defer cancel()
+	select {
+	case reason := <-st.ack:
+		if reason != "" {
+			s.remove(id)
+			return nil, &RejectedError{Reason: reason}
+		}
+		st.accepted = true
+		return st, nil
+	case <-ctx.Done():
+		st.Close()
+		return nil, ctx.Err()
+	case <-s.done:
+		return nil, ErrSessionClosed
+	}
+}
+
+// Close 断开隧道，所有流以 ErrSessionClosed 结束
+func (s *Session) Close() error {
+	s.shutdown(ErrSessionClosed)
+	return nil
+}
+
+// Done 隧道断开时关闭
+func (s *Session) Done() <-chan struct{} {
+	return s.done
+}
+
+// Err 隧道断开原因（未断开时为 nil）
+func (s *Session) Err() error {
+	select {
+	case <-s.done:
+		return s.err
+	default:
+		return nil
+	}
+}
+
+// NumStreams 当前打开的流数量
+func (s *Session) NumStreams() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.streams)
+}
+
+// dispatch 分发收到的帧
+func (s *Session) dispatch(typ byte, id uint32, payload []byte) {
+	if typ == frameOpen {
+		s.accept(id, payload)
+		return
+	}
+
+	s.mu.Lock()
+	st := s.streams[id]
+	s.mu.Unlock()
+	if st == nil {
+		return // 已关闭的流的残留帧
+	}
+	switch typ {
+	case frameAck:
+		if st.ack != nil {
+			select {
+			case st.ack <- string(payload):
+			default:
+			}
+		}
+	case frameData:
+		st.push(payload)
+	case frameClose:
+		st.remoteClose(string(payload))
+	}
+}
+
+// accept 处理对端打开的流
+func (s *Session) accept(id uint32, payload []byte) {
+	var req OpenRequest
+	if err := json.Unmarshal(payload, &req); err != nil {
+		s.writeFrame(frameAck, id, []byte("invalid open request"))
+		return
+	}
+	if s.handler == nil {
+		s.writeFrame(frameAck, id, []byte("no handler for "+req.Kind))
+		return
+	}
+
+	st := newStream(s, id)
+	s.mu.Lock()
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	go func() {
+		err := s.handler(st, req)
+		st.finish(err)
+	}()
+}
+
+// keepalive 定期发送 ping，对端的 pong 延长读超时
+func (s *Session) keepalive() {
+	ticker := time.NewTicker(pingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			if err := s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeTimeout)); err != nil {
+				s.shutdown(err)
+				return
+			}
+		}
+	}
+}
+
+// writeFrame 写入一帧
+func (s *Session) writeFrame(typ byte, id uint32, payload []byte) error {
+	frame := make([]byte, headerSize+len(payload))
+	frame[0] = typ
+	binary.BigEndian.PutUint32(frame[1:headerSize], id)
+	copy(frame[headerSize:], payload)
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if s.isClosed() {
+		return ErrSessionClosed
+	}
+	s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+	if err := s.conn.WriteMessage(websocket.BinaryMessage, frame); err != nil {
+		go s.shutdown(err)
+		return err
+	}
+	return nil
+}
+
+// remove 从会话中移除流
+func (s *Session) remove(id uint32) {
+	s.mu.Lock()
+	delete(s.streams, id)
+	s.mu.Unlock()
+}
+
+// isClosed 隧道是否已断开
+func (s *Session) isClosed() bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// shutdown 断开隧道并结束所有流
+func (s *Session) shutdown(err error) {
+	s.closeOnce.Do(func() {
+		s.err = err
+		close(s.done)
+		s.conn.Close()
+
+		s.mu.Lock()
+		streams := s.streams
+		s.streams = make(map[uint32]*Stream)
+		s.mu.Unlock()
+		for _, st := range streams {
+			st.terminate(ErrSessionClosed)
+		}
+	})
+}
@@ -0,0 +1,165 @@
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// pair 建立一对隧道会话：server 端由测试打开流，client 端用 handler 处理
+func pair(t *testing.T, handler Handler) (*Session, *Session) {
+	t.Helper()
+	accepted := make(chan *Session, 1)
+	upgrader := websocket.Upgrader{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		s := NewSession(conn, false, nil)
+		accepted <- s
+		s.Serve()
+	}))
+	t.Cleanup(srv.Close)
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	require.NoError(t, err)
+	client := NewSession(conn, true, handler)
+	go client.Serve()
+	server := <-accepted
+	t.Cleanup(func() {
+		client.Close()
+		server.Close()
+	})
+	return server, client
+}
+
+func TestSession_OpenEcho(t *testing.T) {
+	server, _ := pair(t, func(s *Stream, req OpenRequest) error {
+		if req.Kind != "echo" {
+			return errors.New("unknown kind " + req.Kind)
+		}
+		s.Accept()
+		_, err := io.Copy(s, s)
+		return err
+	})
+
+	st, err := server.Open(context.Background(), OpenRequest{Kind: "echo"})
+	require.NoError(t, err)
+
+	// 超过单帧大小的数据被分片发送并按序重组
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 10*1024)
+	go st.Write(payload)
+	got := make([]byte, len(payload))
+	_, err = io.ReadFull(st, got)
+	require.NoError(t, err)
+	assert.Equal(t, payload, got)
+
+	require.NoError(t, st.Close())
+	assert.Eventually(t, func() bool { return server.NumStreams() == 0 }, time.Second, 10*time.Millisecond)
+}
+
+func TestSession_Rejected(t *testing.T) {
+	server, _ := pair(t, func(s *Stream, req OpenRequest) error {
+		return errors.New("session not active")
+	})
+
+	_, err := server.Open(context.Background(), OpenRequest{Kind: KindTerminal})
+	require.Error(t, err)
+	assert.Equal(t, "session not active", err.Error())
+	assert.Equal(t, 0, server.NumStreams())
+}
+
+func TestRejectedError_Is(t *testing.T) {
+	server, _ := pair(t, func(s *Stream, req OpenRequest) error {
+		return fmt.Errorf("%w: %s", ErrNotFound, req.Params["path"])
+	})
+
+	_, err := server.Open(context.Background(), OpenRequest{Kind: KindFile, Params: map[string]string{"path": "out.txt"}})
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NotErrorIs(t, err, ErrTooLarge)
+	assert.Equal(t, "not found: out.txt", err.Error())
+}
+
+func TestSession_HandlerCloseError(t *testing.T) {
+	server, _ := pair(t, func(s *Stream, req OpenRequest) error {
+		s.Write([]byte("partial"))
+		return errors.New("file changed")
+	})
+
+	st, err := server.Open(context.Background(), OpenRequest{Kind: KindFile})
+	require.NoError(t, err)
+	data, err := io.ReadAll(st)
+	assert.Equal(t, "partial", string(data))
+	require.Error(t, err)
+	assert.Equal(t, "file changed", err.Error())
+}
+
+func TestSession_ReadDeadline(t *testing.T) {
+	release := make(chan struct{})
+	server, _ := pair(t, func(s *Stream, req OpenRequest) error {
+		s.Accept()
+		<-release
+		return nil
+	})
+	defer close(release)
+
+	st, err := server.Open(context.Background(), OpenRequest{Kind: KindLogs})
+	require.NoError(t, err)
+	st.SetReadDeadline(time.Now().Add(50 * time.Millisecond))
+	_, err = st.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, os.ErrDeadlineExceeded)
+}
+
+func TestSession_CloseEndsStreams(t *testing.T) {
+	started := make(chan struct{})
+	server, client := pair(t, func(s *Stream, req OpenRequest) error {
+		s.Accept()
+		close(started)
+		_, err := s.Read(make([]byte, 1))
+		return err
+	})
+
+	st, err := server.Open(context.Background(), OpenRequest{Kind: KindTerminal})
+	require.NoError(t, err)
+	<-started
+
+	client.Close()
+	_, err = st.Read(make([]byte, 1))
+	assert.ErrorIs(t, err, ErrSessionClosed)
+	<-server.Done()
+
+	_, err = server.Open(context.Background(), OpenRequest{Kind: KindTerminal})
+	assert.ErrorIs(t, err, ErrSessionClosed)
+}
+
+func TestCleanRunPath(t *testing.T) {
+	valid := map[string]string{
+		"out.txt":            "out.txt",
+		"src/./main.go":      "src/main.go",
+		"a/b/../c.txt":       "a/c.txt",
+		".agent/result.json": ".agent/result.json",
+	}
+	for in, want := range valid {
+		got, err := CleanRunPath(in)
+		require.NoError(t, err, in)
+		assert.Equal(t, want, got)
+	}
+
+	for _, in := range []string{"", "/etc/passwd", "..", "../secret", "a/../../b", ".", "a\x00b"} {
+		_, err := CleanRunPath(in)
+		assert.ErrorIs(t, err, ErrInvalidPath, in)
+	}
+}
@@ -0,0 +1,51 @@
+package tlsutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"testing"
+	"time"
+)
+
+func TestCA_SignClientCSR(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := GenerateCerts(GenerateOptions{CertDir: tmpDir, Organization: "Test Org"}); err != nil {
+		t.Fatalf("GenerateCerts failed: %v", err)
+	}
+	files := DefaultCertFiles(tmpDir)
+	ca, err := LoadCA(files.CAFile, files.CAKeyFile)
+	if err != nil {
+		t.Fatalf("LoadCA failed: %v", err)
+	}
+
+	// CSR 中的主题由 CA 覆盖，节点无法自选身份
+	csrPEM, keyPEM, err := NewClientCSR("spoofed-node")
+	if err != nil {
+		t.Fatalf("NewClientCSR failed: %v", err)
+	}
+	certPEM, cert, err := ca.SignClientCSR(csrPEM, "node-1", 24*time.Hour)
+	if err != nil {
+		t.Fatalf("SignClientCSR failed: %v", err)
+	}
+	if cert.Subject.CommonName != "node-1" || len(cert.Subject.OrganizationalUnit) != 1 || cert.Subject.OrganizationalUnit[0] != NodeCertOU {
+		t.Errorf("unexpected subject: %+v", cert.Subject)
+	}
+	if CertSerial(cert) == "" {
+		t.Error("expected serial")
+	}
+
+	// 签发的证书与本地私钥配对，并能以客户端身份通过 CA 校验
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatalf("X509KeyPair failed: %v", err)
+	}
+	if _, err := cert.Verify(x509.VerifyOptions{
+		Roots:     ca.Pool(),
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}); err != nil {
+		t.Errorf("verify client cert: %v", err)
+	}
+
+	if _, _, err := ca.SignClientCSR([]byte("not a csr"), "node-1", time.Hour); err == nil {
+		t.Error("expected error for invalid CSR")
+	}
+}
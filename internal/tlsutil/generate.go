@@ -23,9 +23,10 @@ import (
 
 // CertFiles 证书文件路径
 type CertFiles struct {
-	CAFile   string // CA 证书
-	CertFile string // 服务端证书
-	KeyFile  string // 服务端私钥
+	CAFile    string // CA 证书
+	CAKeyFile string // CA 私钥（用于签发节点客户端证书）
+	CertFile  string // 服务端证书
+	KeyFile   string // 服务端私钥
 }
 
 // DefaultCertDir 默认证书目录
@@ -37,13 +38,14 @@ func DefaultCertFiles(dir string) CertFiles {
 		dir = DefaultCertDir
 	}
 	return CertFiles{
-		CAFile:   filepath.Join(dir, "ca.pem"),
-		CertFile: filepath.Join(dir, "server.pem"),
-		KeyFile:  filepath.Join(dir, "server-key.pem"),
+		CAFile:    filepath.Join(dir, "ca.pem"),
+		CAKeyFile: filepath.Join(dir, "ca-key.pem"),
+		CertFile:  filepath.Join(dir, "server.pem"),
+		KeyFile:   filepath.Join(dir, "server-key.pem"),
 	}
 }
 
-// CertsExist 检查证书文件是否全部存在
+// CertsExist 检查证书文件是否全部存在（CA 私钥为可选，旧版本生成的证书目录中没有）
 func (c CertFiles) CertsExist() bool {
 	for _, f := range []string{c.CAFile, c.CertFile, c.KeyFile} {
 		if _, err := os.Stat(f); os.IsNotExist(err) {
@@ -203,6 +205,15 @@ func GenerateCerts(opts GenerateOptions) error {
 		return fmt.Errorf("write CA cert: %w", err)
 	}
 
+	// CA 私钥（敏感，600）
+	caKeyBytes, err := x509.MarshalECPrivateKey(caKey)
+	if err != nil {
+		return fmt.Errorf("marshal CA key: %w", err)
+	}
+	if err := writePEM(files.CAKeyFile, "EC PRIVATE KEY", caKeyBytes, 0600); err != nil {
+		return fmt.Errorf("write CA key: %w", err)
+	}
+
 	// 服务端证书（公开，644）
 	if err := writePEM(files.CertFile, "CERTIFICATE", serverCertDER, 0644); err != nil {
 		return fmt.Errorf("write server cert: %w", err)
@@ -219,6 +230,7 @@ func GenerateCerts(opts GenerateOptions) error {
 
 	log.Printf("[tls] Generated files:")
 	log.Printf("[tls]   CA cert:     %s", files.CAFile)
+	log.Printf("[tls]   CA key:      %s", files.CAKeyFile)
 	log.Printf("[tls]   Server cert: %s (SANs: %s)", files.CertFile, strings.Join(hosts, ", "))
 	log.Printf("[tls]   Server key:  %s", files.KeyFile)
 	log.Printf("[tls]   Valid for:   %s", opts.ValidFor)
@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListAccounts 列出认证账号（params 为 nil 时返回全部）
+// GET /api/v1/accounts
+func (c *Client) ListAccounts(ctx context.Context, params *ListAccountsParams) ([]Account, error) {
+	var out AccountsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/accounts", encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Accounts, nil
+}
+
+// GetAccount 获取账号详情
+// GET /api/v1/accounts/{id}
+func (c *Client) GetAccount(ctx context.Context, id string) (*Account, error) {
+	var out Account
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/accounts/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAccount 创建账号（创建后为 pending，需在节点上完成认证）
+// POST /api/v1/accounts
+func (c *Client) CreateAccount(ctx context.Context, req CreateAccountRequest) (*Account, error) {
+	var out Account
+	if err := c.do(ctx, http.MethodPost, "/api/v1/accounts", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAccount 删除账号
+// DELETE /api/v1/accounts/{id}
+func (c *Client) DeleteAccount(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, pathf("/api/v1/accounts/%s", id), nil, nil, nil)
+}
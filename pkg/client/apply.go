@@ -0,0 +1,25 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Apply 按声明式期望状态同步项目、模板、Webhook 与代理
+// POST /api/v1/apply
+//
+// bundle 的键为资源类型（projects、task_templates、agent_templates、webhooks、proxies），
+// 未出现的类型不做管理。dryRun 为 true 时只返回变更计划。
+func (c *Client) Apply(ctx context.Context, bundle map[string]interface{}, dryRun bool) (*ApplyResult, error) {
+	body := make(map[string]interface{}, len(bundle)+1)
+	for k, v := range bundle {
+		body[k] = v
+	}
+	body["dry_run"] = dryRun
+
+	var out ApplyResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/apply", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,17 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// Login 以邮箱密码登录，返回访问令牌与刷新令牌（公开接口，无需认证）
+// POST /api/v1/auth/login
+func (c *Client) Login(ctx context.Context, email, password string) (*AuthResponse, error) {
+	var out AuthResponse
+	body := map[string]string{"email": email, "password": password}
+	if err := c.do(ctx, http.MethodPost, "/api/v1/auth/login", nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
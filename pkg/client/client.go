@@ -0,0 +1,213 @@
+// Package client agents-admin REST API 的 Go 客户端
+//
+// 覆盖任务、执行、事件、节点、监控、模板与账号接口，请求/响应类型来自 api/openapi 生成的模型
+// （见 types.go），接口定义以 GET /api/v1/openapi.json 为准。
+//
+// 用法：
+//
+//	c := client.New("https://admin.example.com", client.WithToken(accessToken))
+//	task, err := c.CreateTask(ctx, client.CreateTaskRequest{Name: "demo", Prompt: "fix the bug"})
+//	run, err := c.CreateRun(ctx, task.Id)
+//	events, err := c.GetEvents(ctx, run.Id, nil)
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultTimeout 未指定 HTTP 客户端时的请求超时
+const defaultTimeout = 30 * time.Second
+
+// Client agents-admin API 客户端（并发安全）
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	token      string // 用户 JWT（Authorization: Bearer）
+	nodeToken  string // 节点凭证（X-Node-Token，Node Manager 使用）
+	userAgent  string
+}
+
+// Option 客户端选项
+type Option func(*Client)
+
+// WithHTTPClient 使用自定义 HTTP 客户端（如自定义 TLS、超时、代理）
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken 以用户 JWT 认证（登录或刷新接口返回的 access_token）
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// WithNodeToken 以节点凭证认证（共享密钥或加入时下发的节点专属 Token）
+func WithNodeToken(token string) Option {
+	return func(c *Client) { c.nodeToken = token }
+}
+
+// WithUserAgent 设置请求的 User-Agent
+func WithUserAgent(ua string) Option {
+	return func(c *Client) { c.userAgent = ua }
+}
+
+// New 创建客户端
+//
+// 参数：
+//   - baseURL: API Server 地址（如 https://localhost:8080），不含 /api/v1
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: defaultTimeout},
+		userAgent:  "agents-admin-go-client",
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError 接口返回的非 2xx 响应
+type APIError struct {
+	StatusCode int    // HTTP 状态码
+	Message    string // 响应体中的 error 字段（非 JSON 响应时为响应体原文）
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("agents-admin api: %d %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound 判断错误是否为 404（资源不存在）
+func IsNotFound(err error) bool {
+	return statusCode(err) == http.StatusNotFound
+}
+
+// IsConflict 判断错误是否为 409（状态冲突，如暂停非运行中的 Run）
+func IsConflict(err error) bool {
+	return statusCode(err) == http.StatusConflict
+}
+
+func statusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// do 发送请求并解码 JSON 响应（out 为 nil 时丢弃响应体）
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+	resp, err := c.send(ctx, method, path, query, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		io.Copy(io.Discard, resp.Body)
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s %s response: %w", method, path, err)
+	}
+	return nil
+}
+
+// send 发送请求，非 2xx 响应转换为 *APIError（调用方负责关闭成功响应的 Body）
+func (c *Client) send(ctx context.Context, method, path string, query url.Values, body interface{}) (*http.Response, error) {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode %s %s request: %w", method, path, err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", c.userAgent)
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if c.nodeToken != "" {
+		req.Header.Set("X-Node-Token", c.nodeToken)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(data))}
+	var errResp struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(data, &errResp) == nil && errResp.Error != "" {
+		apiErr.Message = errResp.Error
+	}
+	return nil, apiErr
+}
+
+// pathf 拼接路径，路径参数做转义
+func pathf(format string, ids ...string) string {
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		args[i] = url.PathEscape(id)
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// encodeQuery 按 form 标签将生成的查询参数结构体（字段均为指针）编码为查询串，nil 字段忽略
+func encodeQuery(params interface{}) url.Values {
+	q := url.Values{}
+	v := reflect.ValueOf(params)
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return q
+	}
+	v = reflect.Indirect(v)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("form"), ",")
+		field := v.Field(i)
+		if name == "" || (field.Kind() == reflect.Pointer && field.IsNil()) {
+			continue
+		}
+		field = reflect.Indirect(field)
+		switch val := field.Interface().(type) {
+		case time.Time:
+			q.Set(name, val.Format(time.RFC3339))
+		case string:
+			q.Set(name, val)
+		case bool:
+			q.Set(name, strconv.FormatBool(val))
+		case int:
+			q.Set(name, strconv.Itoa(val))
+		default:
+			q.Set(name, fmt.Sprint(val))
+		}
+	}
+	return q
+}
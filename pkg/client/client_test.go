@@ -0,0 +1,206 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestServer(t *testing.T, handler http.HandlerFunc) (*Client, *httptest.Server) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return New(srv.URL+"/", WithToken("user-jwt"), WithNodeToken("node-secret")), srv
+}
+
+func TestClient_AuthHeadersAndBody(t *testing.T) {
+	c, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/tasks" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer user-jwt" {
+			t.Errorf("Authorization = %q", got)
+		}
+		if got := r.Header.Get("X-Node-Token"); got != "node-secret" {
+			t.Errorf("X-Node-Token = %q", got)
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		var req CreateTaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatal(err)
+		}
+		if req.Name != "demo" || req.Prompt != "fix the bug" {
+			t.Errorf("body = %+v", req)
+		}
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"id":"task-1","name":"demo","status":"pending","type":"general",
+			"prompt":{"content":"fix the bug","variables":{"lang":"go"}},
+			"created_at":"2026-01-01T00:00:00Z","updated_at":"2026-01-01T00:00:00Z"}`)
+	})
+
+	task, err := c.CreateTask(context.Background(), CreateTaskRequest{Name: "demo", Prompt: "fix the bug"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if task.Id != "task-1" || task.Prompt == nil || task.Prompt.Content != "fix the bug" {
+		t.Errorf("task = %+v", task)
+	}
+}
+
+func TestClient_QueryAndListDecoding(t *testing.T) {
+	c, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.EscapedPath(); got != "/api/v1/runs/run%2F1/events" {
+			t.Errorf("path = %s, 路径参数应转义", got)
+		}
+		if got := r.URL.Query().Get("from_seq"); got != "5" {
+			t.Errorf("from_seq = %q", got)
+		}
+		if got := r.URL.Query().Get("limit"); got != "" {
+			t.Errorf("limit = %q, nil 字段不应编码", got)
+		}
+		io.WriteString(w, `{"events":[{"id":42,"run_id":"run/1","seq":6,"type":"message",
+			"timestamp":"2026-01-01T00:00:00Z","payload":{"content":"hi"}}],"count":1}`)
+	})
+
+	fromSeq := 5
+	events, err := c.GetEvents(context.Background(), "run/1", &GetEventsParams{FromSeq: &fromSeq})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Id != 42 || events[0].Seq != 6 {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestClient_APIError(t *testing.T) {
+	c, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/runs/missing":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"error":"run not found"}`)
+		default:
+			w.WriteHeader(http.StatusConflict)
+			io.WriteString(w, "run is not running")
+		}
+	})
+
+	_, err := c.GetRun(context.Background(), "missing")
+	if !IsNotFound(err) {
+		t.Fatalf("err = %v, want 404", err)
+	}
+	if apiErr := err.(*APIError); apiErr.Message != "run not found" {
+		t.Errorf("message = %q", apiErr.Message)
+	}
+
+	err = c.PauseRun(context.Background(), "run-1")
+	if !IsConflict(err) || IsNotFound(err) {
+		t.Fatalf("err = %v, want 409", err)
+	}
+	if apiErr := err.(*APIError); apiErr.Message != "run is not running" {
+		t.Errorf("非 JSON 响应应保留原文, message = %q", apiErr.Message)
+	}
+}
+
+func TestClient_NoContent(t *testing.T) {
+	c, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodDelete || r.URL.Path != "/api/v1/nodes/node-1" {
+			t.Errorf("request = %s %s", r.Method, r.URL.Path)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	if err := c.DeleteNode(context.Background(), "node-1"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestClient_ExportRawEvents(t *testing.T) {
+	c, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("X-Agent-Type", "claude")
+		io.WriteString(w, "line1\nline2\n")
+	})
+
+	raw, agentType, err := c.ExportRawEvents(context.Background(), "run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(raw) != "line1\nline2\n" || agentType != "claude" {
+		t.Errorf("raw = %q, agentType = %q", raw, agentType)
+	}
+}
+
+func TestEncodeQuery(t *testing.T) {
+	since := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	status, limit := "running", 20
+	q := encodeQuery(&ListRunsParams{Status: &status, Limit: &limit, Since: &since})
+
+	if got := q.Get("status"); got != "running" {
+		t.Errorf("status = %q", got)
+	}
+	if got := q.Get("limit"); got != "20" {
+		t.Errorf("limit = %q", got)
+	}
+	if got := q.Get("since"); got != "2026-01-02T03:04:05Z" {
+		t.Errorf("since = %q", got)
+	}
+	if len(encodeQuery((*ListRunsParams)(nil))) != 0 {
+		t.Error("nil 参数应返回空查询串")
+	}
+}
+
+func TestClient_ListWrappers(t *testing.T) {
+	c, _ := newTestServer(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/accounts":
+			if got := r.URL.Query().Get("agent_type"); got != "claude" {
+				t.Errorf("agent_type = %q", got)
+			}
+			io.WriteString(w, `{"accounts":[{"id":"claude_dev","name":"dev","agent_type":"claude","status":"authenticated"}]}`)
+		case "/api/v1/agent-templates":
+			io.WriteString(w, `{"templates":[{"id":"tpl-1","name":"reviewer","type":"claude"}],"count":1}`)
+		case "/api/v1/auth/login":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			if body["email"] != "admin@example.com" || body["password"] != "secret" {
+				t.Errorf("login body = %v", body)
+			}
+			io.WriteString(w, `{"access_token":"jwt","refresh_token":"rt"}`)
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+	ctx := context.Background()
+
+	agentType := "claude"
+	accounts, err := c.ListAccounts(ctx, &ListAccountsParams{AgentType: &agentType})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0].Id != "claude_dev" || accounts[0].AgentType != "claude" {
+		t.Errorf("accounts = %+v", accounts)
+	}
+
+	templates, err := c.ListAgentTemplates(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(templates) != 1 || templates[0].Name != "reviewer" {
+		t.Errorf("templates = %+v", templates)
+	}
+
+	auth, err := c.Login(ctx, "admin@example.com", "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.AccessToken == nil || *auth.AccessToken != "jwt" {
+		t.Errorf("auth = %+v", auth)
+	}
+}
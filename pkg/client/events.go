@@ -0,0 +1,44 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// GetEvents 获取执行事件（params.FromSeq 用于增量拉取）
+// GET /api/v1/runs/{id}/events
+func (c *Client) GetEvents(ctx context.Context, runID string, params *GetEventsParams) ([]Event, error) {
+	var out EventsResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/runs/%s/events", runID), encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Events, nil
+}
+
+// PostEvents 上报执行事件（Node Manager 使用）
+// POST /api/v1/runs/{id}/events
+func (c *Client) PostEvents(ctx context.Context, runID string, req PostEventsRequest) (*PostEventsResponse, error) {
+	var out PostEventsResponse
+	if err := c.do(ctx, http.MethodPost, pathf("/api/v1/runs/%s/events", runID), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ExportRawEvents 导出 Agent 原始输出，同时返回 Agent 类型（事件已归档时返回 410）
+// GET /api/v1/runs/{id}/events/raw
+func (c *Client) ExportRawEvents(ctx context.Context, runID string) (raw []byte, agentType string, err error) {
+	path := pathf("/api/v1/runs/%s/events/raw", runID)
+	resp, err := c.send(ctx, http.MethodGet, path, nil, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	raw, err = io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s response: %w", path, err)
+	}
+	return raw, resp.Header.Get("X-Agent-Type"), nil
+}
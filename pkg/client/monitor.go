@@ -0,0 +1,46 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListWorkflows 列出监控中的工作流（按类型、状态过滤，分页）
+// GET /api/v1/monitor/workflows
+func (c *Client) ListWorkflows(ctx context.Context, params *ListWorkflowsParams) (*WorkflowsResponse, error) {
+	var out WorkflowsResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/monitor/workflows", encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflow 获取工作流详情（含最近事件与关联 ID）
+// GET /api/v1/monitor/workflows/{type}/{id}
+func (c *Client) GetWorkflow(ctx context.Context, wfType, id string) (*WorkflowDetail, error) {
+	var out WorkflowDetail
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/monitor/workflows/%s/%s", wfType, id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetWorkflowEvents 获取工作流事件
+// GET /api/v1/monitor/workflows/{type}/{id}/events
+func (c *Client) GetWorkflowEvents(ctx context.Context, wfType, id string) ([]WorkflowEvent, error) {
+	var out WorkflowEventsResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/monitor/workflows/%s/%s/events", wfType, id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Events, nil
+}
+
+// GetMonitorStats 获取监控统计
+// GET /api/v1/monitor/stats
+func (c *Client) GetMonitorStats(ctx context.Context) (*MonitorStats, error) {
+	var out MonitorStats
+	if err := c.do(ctx, http.MethodGet, "/api/v1/monitor/stats", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListNodes 列出节点
+// GET /api/v1/nodes
+func (c *Client) ListNodes(ctx context.Context) ([]Node, error) {
+	var out NodesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/nodes", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Nodes, nil
+}
+
+// GetNode 获取节点详情
+// GET /api/v1/nodes/{id}
+func (c *Client) GetNode(ctx context.Context, id string) (*Node, error) {
+	var out Node
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/nodes/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateNode 更新节点（显示名、标签、状态等）
+// PATCH /api/v1/nodes/{id}
+func (c *Client) UpdateNode(ctx context.Context, id string, req UpdateNodeRequest) (*Node, error) {
+	var out Node
+	if err := c.do(ctx, http.MethodPatch, pathf("/api/v1/nodes/%s", id), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteNode 删除节点
+// DELETE /api/v1/nodes/{id}
+func (c *Client) DeleteNode(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, pathf("/api/v1/nodes/%s", id), nil, nil, nil)
+}
+
+// ListNodeRuns 列出分配到节点的执行
+// GET /api/v1/nodes/{id}/runs
+func (c *Client) ListNodeRuns(ctx context.Context, id string) ([]Run, error) {
+	var out RunsResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/nodes/%s/runs", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Runs, nil
+}
+
+// Heartbeat 上报节点心跳（Node Manager 使用）
+// POST /api/v1/nodes/heartbeat
+func (c *Client) Heartbeat(ctx context.Context, req HeartbeatRequest) (*HeartbeatResponse, error) {
+	var out HeartbeatResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/nodes/heartbeat", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListNodeOccupancy 获取各节点的槽位占用
+// GET /api/v1/nodes/occupancy
+func (c *Client) ListNodeOccupancy(ctx context.Context) ([]NodeOccupancy, error) {
+	var out NodeOccupancyResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/nodes/occupancy", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Nodes, nil
+}
+
+// CreateJoinToken 创建节点加入令牌（仅管理员，明文只在此处返回一次）
+// POST /api/v1/nodes/join-tokens
+func (c *Client) CreateJoinToken(ctx context.Context, req CreateJoinTokenRequest) (*CreateJoinTokenResponse, error) {
+	var out CreateJoinTokenResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/nodes/join-tokens", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListJoinTokens 列出节点加入令牌（仅管理员）
+// GET /api/v1/nodes/join-tokens
+func (c *Client) ListJoinTokens(ctx context.Context) ([]NodeJoinToken, error) {
+	var out JoinTokensResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/nodes/join-tokens", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Tokens, nil
+}
+
+// DeleteJoinToken 吊销节点加入令牌（仅管理员）
+// DELETE /api/v1/nodes/join-tokens/{id}
+func (c *Client) DeleteJoinToken(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, pathf("/api/v1/nodes/join-tokens/%s", id), nil, nil, nil)
+}
+
+// ListJoinTokenUses 获取加入令牌的使用记录（仅管理员）
+// GET /api/v1/nodes/join-tokens/{id}/uses
+func (c *Client) ListJoinTokenUses(ctx context.Context, id string) ([]NodeJoinTokenUse, error) {
+	var out JoinTokenUsesResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/nodes/join-tokens/%s/uses", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Uses, nil
+}
+
+// Join 以加入令牌换取节点专属 Token（公开接口，无需认证）
+// POST /api/v1/nodes/join
+func (c *Client) Join(ctx context.Context, req JoinRequest) (*JoinResponse, error) {
+	var out JoinResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/nodes/join", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,145 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// CreateRun 为任务创建一次执行
+// POST /api/v1/tasks/{id}/runs
+func (c *Client) CreateRun(ctx context.Context, taskID string) (*Run, error) {
+	var out Run
+	if err := c.do(ctx, http.MethodPost, pathf("/api/v1/tasks/%s/runs", taskID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListTaskRuns 列出任务的执行记录
+// GET /api/v1/tasks/{id}/runs
+func (c *Client) ListTaskRuns(ctx context.Context, taskID string, params *ListTaskRunsParams) ([]Run, error) {
+	var out RunsResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/tasks/%s/runs", taskID), encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Runs, nil
+}
+
+// ListRuns 跨任务列出执行记录（params 为 nil 时使用服务端默认分页）
+// GET /api/v1/runs
+func (c *Client) ListRuns(ctx context.Context, params *ListRunsParams) (*RunList, error) {
+	var out RunList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/runs", encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRun 获取执行详情
+// GET /api/v1/runs/{id}
+func (c *Client) GetRun(ctx context.Context, id string) (*Run, error) {
+	var out Run
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/runs/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateRun 更新执行状态（Node Manager 上报），返回变更后的状态
+// PATCH /api/v1/runs/{id}
+func (c *Client) UpdateRun(ctx context.Context, id string, req UpdateRunRequest) (string, error) {
+	return c.runAction(ctx, http.MethodPatch, pathf("/api/v1/runs/%s", id), req)
+}
+
+// CancelRun 取消执行
+// POST /api/v1/runs/{id}/cancel
+func (c *Client) CancelRun(ctx context.Context, id string) error {
+	_, err := c.runAction(ctx, http.MethodPost, pathf("/api/v1/runs/%s/cancel", id), nil)
+	return err
+}
+
+// PauseRun 暂停运行中的执行（Run 不在运行中时返回 409，见 IsConflict）
+// POST /api/v1/runs/{id}/pause
+func (c *Client) PauseRun(ctx context.Context, id string) error {
+	_, err := c.runAction(ctx, http.MethodPost, pathf("/api/v1/runs/%s/pause", id), nil)
+	return err
+}
+
+// ResumeRun 恢复已暂停的执行
+// POST /api/v1/runs/{id}/resume
+func (c *Client) ResumeRun(ctx context.Context, id string) error {
+	_, err := c.runAction(ctx, http.MethodPost, pathf("/api/v1/runs/%s/resume", id), nil)
+	return err
+}
+
+// runAction 调用返回 {"status": ...} 的执行状态变更接口
+func (c *Client) runAction(ctx context.Context, method, path string, body interface{}) (string, error) {
+	var out struct {
+		Status string `json:"status"`
+	}
+	if err := c.do(ctx, method, path, nil, body, &out); err != nil {
+		return "", err
+	}
+	return out.Status, nil
+}
+
+// ListArtifacts 列出执行产物
+// GET /api/v1/runs/{id}/artifacts
+func (c *Client) ListArtifacts(ctx context.Context, runID string) ([]Artifact, error) {
+	var out ArtifactsResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/runs/%s/artifacts", runID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Artifacts, nil
+}
+
+// CreateArtifact 登记执行产物
+// POST /api/v1/runs/{id}/artifacts
+func (c *Client) CreateArtifact(ctx context.Context, runID string, req CreateArtifactRequest) (*Artifact, error) {
+	var out Artifact
+	if err := c.do(ctx, http.MethodPost, pathf("/api/v1/runs/%s/artifacts", runID), nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRunFlags 列出执行的内容审核标记
+// GET /api/v1/runs/{id}/flags
+func (c *Client) ListRunFlags(ctx context.Context, runID string) (*RunFlagsResponse, error) {
+	var out RunFlagsResponse
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/runs/%s/flags", runID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRunLifecycle 查询执行数据所在层级与归档信息
+// GET /api/v1/runs/{id}/lifecycle
+func (c *Client) GetRunLifecycle(ctx context.Context, runID string) (*RunLifecycle, error) {
+	var out RunLifecycle
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/runs/%s/lifecycle", runID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetRunUsage 查询执行累计的 Token 用量与费用
+// GET /api/v1/runs/{id}/usage
+func (c *Client) GetRunUsage(ctx context.Context, runID string) (*RunUsage, error) {
+	var out RunUsage
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/runs/%s/usage", runID), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PublishRun 将执行结果发布到触发任务的 PR/MR（dryRun 时只返回渲染后的评论）
+// POST /api/v1/runs/{id}/publish
+func (c *Client) PublishRun(ctx context.Context, runID string, dryRun bool) (*PublishResult, error) {
+	var out PublishResult
+	body := map[string]bool{"dry_run": dryRun}
+	if err := c.do(ctx, http.MethodPost, pathf("/api/v1/runs/%s/publish", runID), nil, body, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListTasks 列出任务（params 为 nil 时使用服务端默认分页）
+// GET /api/v1/tasks
+func (c *Client) ListTasks(ctx context.Context, params *ListTasksParams) (*TaskList, error) {
+	var out TaskList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/tasks", encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTask 创建任务
+// POST /api/v1/tasks
+func (c *Client) CreateTask(ctx context.Context, req CreateTaskRequest) (*Task, error) {
+	var out Task
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tasks", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetTask 获取任务详情
+// GET /api/v1/tasks/{id}
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	var out Task
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/tasks/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTask 删除任务
+// DELETE /api/v1/tasks/{id}
+func (c *Client) DeleteTask(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, pathf("/api/v1/tasks/%s", id), nil, nil, nil)
+}
+
+// BulkTasks 批量创建、取消、删除或重新执行任务（逐项返回结果）
+// POST /api/v1/tasks/bulk
+func (c *Client) BulkTasks(ctx context.Context, req BulkTaskRequest) (*BulkTaskResponse, error) {
+	var out BulkTaskResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/tasks/bulk", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// UpdateTaskContext 更新任务上下文
+// PUT /api/v1/tasks/{id}/context
+func (c *Client) UpdateTaskContext(ctx context.Context, id string, taskCtx TaskContext) error {
+	return c.do(ctx, http.MethodPut, pathf("/api/v1/tasks/%s/context", id), nil, taskCtx, nil)
+}
+
+// GetTaskIssueLink 获取任务关联的外部 Issue（非 Issue 导入的任务返回 404）
+// GET /api/v1/tasks/{id}/issue-link
+func (c *Client) GetTaskIssueLink(ctx context.Context, id string) (*IssueLink, error) {
+	var out IssueLink
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/tasks/%s/issue-link", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
@@ -0,0 +1,78 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// ListTaskTemplates 列出任务模板（params 为 nil 时返回全部）
+// GET /api/v1/task-templates
+func (c *Client) ListTaskTemplates(ctx context.Context, params *ListTaskTemplatesParams) ([]TaskTemplate, error) {
+	var out TaskTemplatesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/task-templates", encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Templates, nil
+}
+
+// GetTaskTemplate 获取任务模板详情
+// GET /api/v1/task-templates/{id}
+func (c *Client) GetTaskTemplate(ctx context.Context, id string) (*TaskTemplate, error) {
+	var out TaskTemplate
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/task-templates/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateTaskTemplate 创建任务模板
+// POST /api/v1/task-templates
+func (c *Client) CreateTaskTemplate(ctx context.Context, req CreateTaskTemplateRequest) (*TaskTemplate, error) {
+	var out TaskTemplate
+	if err := c.do(ctx, http.MethodPost, "/api/v1/task-templates", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteTaskTemplate 删除任务模板（内置模板不可删除）
+// DELETE /api/v1/task-templates/{id}
+func (c *Client) DeleteTaskTemplate(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, pathf("/api/v1/task-templates/%s", id), nil, nil, nil)
+}
+
+// ListAgentTemplates 列出 Agent 模板（params 为 nil 时返回全部）
+// GET /api/v1/agent-templates
+func (c *Client) ListAgentTemplates(ctx context.Context, params *ListAgentTemplatesParams) ([]AgentTemplate, error) {
+	var out AgentTemplatesResponse
+	if err := c.do(ctx, http.MethodGet, "/api/v1/agent-templates", encodeQuery(params), nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Templates, nil
+}
+
+// GetAgentTemplate 获取 Agent 模板详情
+// GET /api/v1/agent-templates/{id}
+func (c *Client) GetAgentTemplate(ctx context.Context, id string) (*AgentTemplate, error) {
+	var out AgentTemplate
+	if err := c.do(ctx, http.MethodGet, pathf("/api/v1/agent-templates/%s", id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateAgentTemplate 创建 Agent 模板
+// POST /api/v1/agent-templates
+func (c *Client) CreateAgentTemplate(ctx context.Context, req CreateAgentTemplateRequest) (*AgentTemplate, error) {
+	var out AgentTemplate
+	if err := c.do(ctx, http.MethodPost, "/api/v1/agent-templates", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// DeleteAgentTemplate 删除 Agent 模板（内置模板不可删除）
+// DELETE /api/v1/agent-templates/{id}
+func (c *Client) DeleteAgentTemplate(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, pathf("/api/v1/agent-templates/%s", id), nil, nil, nil)
+}
@@ -0,0 +1,167 @@
+package client
+
+import (
+	openapi "agents-admin/api/generated/go"
+)
+
+// 请求/响应模型（由 api/openapi 生成，字段与 JSON 定义见 api/generated/go/models.gen.go）
+type (
+	Task              = openapi.Task
+	TaskList          = openapi.TaskList
+	CreateTaskRequest = openapi.CreateTaskRequest
+	BulkTaskRequest   = openapi.BulkTaskRequest
+	BulkTaskResponse  = openapi.BulkTaskResponse
+	TaskContext       = openapi.TaskContext
+	IssueLink         = openapi.IssueLink
+	ListTasksParams   = openapi.ListTasksParams
+
+	Run              = openapi.Run
+	RunList          = openapi.RunList
+	UpdateRunRequest = openapi.UpdateRunRequest
+	Artifact         = openapi.Artifact
+	RunFlag          = openapi.RunFlag
+	RunLifecycle     = openapi.RunLifecycle
+	RunUsage         = openapi.RunUsage
+	PublishResult    = openapi.PublishResult
+	ListRunsParams   = openapi.ListRunsParams
+
+	ListTaskRunsParams = openapi.ListTaskRunsParams
+
+	CreateArtifactRequest = openapi.CreateArtifactRequest
+
+	Event              = openapi.Event
+	EventInput         = openapi.EventInput
+	PostEventsRequest  = openapi.PostEventsRequest
+	PostEventsResponse = openapi.PostEventsResponse
+	GetEventsParams    = openapi.GetEventsParams
+
+	Node                    = openapi.Node
+	UpdateNodeRequest       = openapi.UpdateNodeRequest
+	HeartbeatRequest        = openapi.HeartbeatRequest
+	HeartbeatResponse       = openapi.HeartbeatResponse
+	NodeOccupancy           = openapi.NodeOccupancy
+	NodeJoinToken           = openapi.NodeJoinToken
+	NodeJoinTokenUse        = openapi.NodeJoinTokenUse
+	CreateJoinTokenRequest  = openapi.CreateJoinTokenRequest
+	CreateJoinTokenResponse = openapi.CreateJoinTokenResponse
+	JoinRequest             = openapi.JoinRequest
+	JoinResponse            = openapi.JoinResponse
+
+	Workflow            = openapi.Workflow
+	WorkflowDetail      = openapi.WorkflowDetail
+	WorkflowEvent       = openapi.WorkflowEvent
+	MonitorStats        = openapi.MonitorStats
+	ListWorkflowsParams = openapi.ListWorkflowsParams
+
+	AuthResponse = openapi.AuthResponse
+
+	Account              = openapi.Account
+	CreateAccountRequest = openapi.CreateAccountRequest
+	ListAccountsParams   = openapi.ListAccountsParams
+
+	TaskTemplate               = openapi.TaskTemplate
+	CreateTaskTemplateRequest  = openapi.CreateTaskTemplateRequest
+	ListTaskTemplatesParams    = openapi.ListTaskTemplatesParams
+	AgentTemplate              = openapi.AgentTemplate
+	CreateAgentTemplateRequest = openapi.CreateAgentTemplateRequest
+	ListAgentTemplatesParams   = openapi.ListAgentTemplatesParams
+)
+
+// 列表响应（规范中以内联对象定义，未生成具名类型）
+
+// RunsResponse GET /api/v1/tasks/{id}/runs 与 GET /api/v1/nodes/{id}/runs 的响应
+type RunsResponse struct {
+	Runs  []Run `json:"runs"`
+	Count int   `json:"count"`
+}
+
+// EventsResponse GET /api/v1/runs/{id}/events 的响应
+type EventsResponse struct {
+	Events []Event `json:"events"`
+	Count  int     `json:"count"`
+}
+
+// NodesResponse GET /api/v1/nodes 的响应
+type NodesResponse struct {
+	Nodes []Node `json:"nodes"`
+	Count int    `json:"count"`
+}
+
+// NodeOccupancyResponse GET /api/v1/nodes/occupancy 的响应
+type NodeOccupancyResponse struct {
+	Nodes []NodeOccupancy `json:"nodes"`
+	Count int             `json:"count"`
+}
+
+// JoinTokensResponse GET /api/v1/nodes/join-tokens 的响应
+type JoinTokensResponse struct {
+	Tokens []NodeJoinToken `json:"tokens"`
+	Count  int             `json:"count"`
+}
+
+// JoinTokenUsesResponse GET /api/v1/nodes/join-tokens/{id}/uses 的响应
+type JoinTokenUsesResponse struct {
+	Uses  []NodeJoinTokenUse `json:"uses"`
+	Count int                `json:"count"`
+}
+
+// ArtifactsResponse GET /api/v1/runs/{id}/artifacts 的响应
+type ArtifactsResponse struct {
+	Artifacts []Artifact `json:"artifacts"`
+	Count     int        `json:"count"`
+}
+
+// RunFlagsResponse GET /api/v1/runs/{id}/flags 的响应
+type RunFlagsResponse struct {
+	Flags   []RunFlag `json:"flags"`
+	Count   int       `json:"count"`
+	Aborted bool      `json:"aborted"` // Run 是否因审核命中被终止
+}
+
+// WorkflowsResponse GET /api/v1/monitor/workflows 的响应
+type WorkflowsResponse struct {
+	Workflows []Workflow `json:"workflows"`
+	Total     int        `json:"total"`
+	Limit     int        `json:"limit"`
+	Offset    int        `json:"offset"`
+}
+
+// WorkflowEventsResponse GET /api/v1/monitor/workflows/{type}/{id}/events 的响应
+type WorkflowEventsResponse struct {
+	Events []WorkflowEvent `json:"events"`
+	Total  int             `json:"total"`
+}
+
+// AccountsResponse GET /api/v1/accounts 的响应
+type AccountsResponse struct {
+	Accounts []Account `json:"accounts"`
+}
+
+// TaskTemplatesResponse GET /api/v1/task-templates 的响应
+type TaskTemplatesResponse struct {
+	Templates []TaskTemplate `json:"templates"`
+	Count     int            `json:"count"`
+}
+
+// AgentTemplatesResponse GET /api/v1/agent-templates 的响应
+type AgentTemplatesResponse struct {
+	Templates []AgentTemplate `json:"templates"`
+	Count     int             `json:"count"`
+}
+
+// ApplyChange 声明式同步中单个资源的变更
+type ApplyChange struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	ID     string `json:"id,omitempty"`
+	Action string `json:"action"` // create / update / delete / unchanged
+	Error  string `json:"error,omitempty"`
+}
+
+// ApplyResult POST /api/v1/apply 的响应
+type ApplyResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Changes []ApplyChange  `json:"changes"`
+	Summary map[string]int `json:"summary"`
+	Error   string         `json:"error,omitempty"`
+}
@@ -0,0 +1,105 @@
+#!/bin/bash
+# build-offline-bundle.sh — 生成离线（air-gapped）安装包
+#
+# 产物（dist/agents-admin-offline-<version>-<arch>.tar.gz）：
+#   bin/                 api-server、nodemanager（前端已嵌入）
+#   images/*.tar         基础设施与 Runner 镜像（docker save）
+#   offline-bundle.json  清单（版本、镜像列表），Setup 向导据此识别离线模式
+#   install.sh           离线引导脚本（docker load + 安装二进制，无需联网）
+#
+# 用法:
+#   ./scripts/build-offline-bundle.sh [--version 1.0.0] [--arch amd64] [--skip-build]
+#
+# 额外镜像可通过 EXTRA_IMAGES 环境变量追加（空格分隔）。
+set -euo pipefail
+
+SCRIPT_DIR="$(cd "$(dirname "$0")" && pwd)"
+PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
+
+VERSION="${VERSION:-0.9.0}"
+ARCH="${ARCH:-amd64}"
+SKIP_BUILD=0
+
+while [[ $# -gt 0 ]]; do
+  case "$1" in
+    --version)    VERSION="$2"; shift 2 ;;
+    --arch)       ARCH="$2";    shift 2 ;;
+    --skip-build) SKIP_BUILD=1; shift ;;
+    *)            echo "Unknown option: $1"; exit 1 ;;
+  esac
+done
+
+BUNDLE_NAME="agents-admin-offline-${VERSION}-${ARCH}"
+DIST_DIR="${PROJECT_ROOT}/dist"
+BUNDLE_DIR="${DIST_DIR}/${BUNDLE_NAME}"
+
+rm -rf "$BUNDLE_DIR"
+mkdir -p "$BUNDLE_DIR/bin" "$BUNDLE_DIR/images"
+
+# ──────────────────────────────────────────────────────
+# Step 1: 构建二进制（前端嵌入）
+# ──────────────────────────────────────────────────────
+if [ "$SKIP_BUILD" -eq 0 ]; then
+    echo "Step 1: Building frontend and binaries..."
+    (cd "$PROJECT_ROOT/web" && STATIC_EXPORT=true npm run build)
+    (cd "$PROJECT_ROOT" && CGO_ENABLED=0 GOOS=linux GOARCH="$ARCH" go build -ldflags="-s -w" -o "$BUNDLE_DIR/bin/api-server" ./cmd/api-server)
+    (cd "$PROJECT_ROOT" && CGO_ENABLED=0 GOOS=linux GOARCH="$ARCH" go build -ldflags="-s -w" -o "$BUNDLE_DIR/bin/nodemanager" ./cmd/nodemanager)
+else
+    echo "Step 1: Using existing binaries in bin/"
+    cp "$PROJECT_ROOT/bin/api-server-linux-${ARCH}" "$BUNDLE_DIR/bin/api-server" 2>/dev/null || cp "$PROJECT_ROOT/bin/api-server" "$BUNDLE_DIR/bin/api-server"
+    cp "$PROJECT_ROOT/bin/nodemanager-linux-${ARCH}" "$BUNDLE_DIR/bin/nodemanager" 2>/dev/null || cp "$PROJECT_ROOT/bin/nodemanager" "$BUNDLE_DIR/bin/nodemanager"
+fi
+
+# ──────────────────────────────────────────────────────
+# Step 2: 导出容器镜像
+# ──────────────────────────────────────────────────────
+echo "Step 2: Saving container images..."
+
+# 基础设施镜像（不含 tools profile 的调试工具）
+INFRA_IMAGES=$(awk '/profiles:/{skip=1} /^  [a-z]/{if(img!="" && !skip) print img; img=""; skip=0} /image:/{img=$2} END{if(img!="" && !skip) print img}' \
+    "$PROJECT_ROOT/deployments/docker-compose.infra.yml")
+
+# Runner / 终端镜像（本地构建）
+docker build -f "$PROJECT_ROOT/deployments/Dockerfile.runner-qwencode" -t runners/qwencode:latest "$PROJECT_ROOT/deployments"
+docker build -f "$PROJECT_ROOT/deployments/Dockerfile.ttyd" -t tools/ttyd:latest "$PROJECT_ROOT/deployments"
+
+IMAGES="$INFRA_IMAGES runners/qwencode:latest tools/ttyd:latest alpine:latest ${EXTRA_IMAGES:-}"
+
+IMAGE_JSON=""
+for img in $IMAGES; do
+    if ! docker image inspect "$img" &>/dev/null; then
+        docker pull --platform "linux/${ARCH}" "$img"
+    fi
+    file="$(echo "$img" | tr '/:' '__').tar"
+    echo "  - $img -> images/$file"
+    docker save -o "$BUNDLE_DIR/images/$file" "$img"
+    IMAGE_JSON="${IMAGE_JSON:+$IMAGE_JSON, }\"$img\""
+done
+
+# ──────────────────────────────────────────────────────
+# Step 3: 清单、Compose 模板与引导脚本
+# ──────────────────────────────────────────────────────
+echo "Step 3: Writing manifest and bootstrap script..."
+cat > "$BUNDLE_DIR/offline-bundle.json" <<JSON
+{
+  "version": "${VERSION}",
+  "arch": "${ARCH}",
+  "created_at": "$(date -u +%Y-%m-%dT%H:%M:%SZ)",
+  "images": [${IMAGE_JSON}]
+}
+JSON
+cp "$PROJECT_ROOT/deployments/docker-compose.infra.yml" "$BUNDLE_DIR/"
+cp "$PROJECT_ROOT/deployments/deb/systemd/"*.service "$BUNDLE_DIR/"
+cp "$PROJECT_ROOT/deployments/offline/install.sh" "$BUNDLE_DIR/install.sh"
+chmod 755 "$BUNDLE_DIR/install.sh"
+
+# ──────────────────────────────────────────────────────
+# Step 4: 打包
+# ──────────────────────────────────────────────────────
+echo "Step 4: Packaging..."
+tar -C "$DIST_DIR" -czf "${DIST_DIR}/${BUNDLE_NAME}.tar.gz" "$BUNDLE_NAME"
+(cd "$DIST_DIR" && sha256sum "${BUNDLE_NAME}.tar.gz" > "${BUNDLE_NAME}.tar.gz.sha256")
+rm -rf "$BUNDLE_DIR"
+
+echo ""
+echo "Done! ${DIST_DIR}/${BUNDLE_NAME}.tar.gz"
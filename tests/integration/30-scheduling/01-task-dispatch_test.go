@@ -10,6 +10,10 @@
 //   - TC-SCHEDULE-005: 负载均衡
 //   - TC-SCHEDULE-008: 幂等性
 //   - TC-SCHEDULE-009: Redis 消息确认
+//
+// 队列后端由 QUEUE_DRIVER 选择（redis 默认 / nats），同一组用例对两种后端各运行一次：
+//
+//	QUEUE_DRIVER=nats QUEUE_URL=nats://localhost:4222 go test ./tests/integration/30-scheduling/...
 package integration
 
 import (
@@ -31,12 +35,14 @@ import (
 	"agents-admin/internal/shared/infra"
 	"agents-admin/internal/shared/model"
 	"agents-admin/internal/shared/queue"
+	natsqueue "agents-admin/internal/shared/queue/nats"
 	"agents-admin/internal/shared/storage"
 )
 
 var (
 	testStore *storage.PostgresStore
 	testRedis *infra.RedisInfra
+	testNATS  *natsqueue.Store // QUEUE_DRIVER=nats 时替换 testRedis 的队列后端
 	idSeq     uint32
 )
 
@@ -189,6 +195,29 @@ func resetRedis(t *testing.T, ctx context.Context) {
 	if err := testRedis.Client().FlushDB(ctx).Err(); err != nil {
 		t.Fatalf("redis FlushDB failed: %v", err)
 	}
+	if testNATS != nil {
+		if err := testNATS.Purge(ctx); err != nil {
+			t.Fatalf("nats purge failed: %v", err)
+		}
+	}
+}
+
+// schedulerMessagePending 调度消息是否已被读取但尚未确认
+//
+// Redis 按消息 ID 精确查询 pending 列表；NATS 发布返回的是 Stream 序号，按未确认消息数判断（用例前已清空队列）。
+func schedulerMessagePending(ctx context.Context, msgID string) (bool, error) {
+	if testNATS != nil {
+		n, err := testNATS.GetSchedulerPendingCount(ctx)
+		return n > 0, err
+	}
+	pending, err := testRedis.Client().XPendingExt(ctx, &redis.XPendingExtArgs{
+		Stream: queue.KeySchedulerRuns,       // Stream 名称：调度队列
+		Group:  queue.SchedulerConsumerGroup, // 消费者组：调度器消费者组
+		Start:  msgID,                        // 查询起始消息ID
+		End:    msgID,                        // 查询结束消息ID（只查这一条）
+		Count:  1,                            // 最多返回1条
+	}).Result()
+	return len(pending) == 1, err
 }
 
 func resetDBNodes(t *testing.T, ctx context.Context) {
@@ -222,12 +251,24 @@ func TestMain(m *testing.M) {
 		testRedis = nil
 	}
 
+	// 队列后端：QUEUE_DRIVER=nats 时调度队列与节点队列改用 NATS JetStream
+	if testRedis != nil && cfg.QueueDriver == queue.DriverNATS {
+		testNATS, err = natsqueue.NewStoreFromURL(cfg.QueueURL)
+		if err != nil {
+			panic("Failed to connect to NATS: " + err.Error())
+		}
+		testRedis.SetQueue(testNATS)
+	}
+
 	code := m.Run()
 
 	testStore.Close()
 	if testRedis != nil {
 		testRedis.Close()
 	}
+	if testNATS != nil {
+		testNATS.Close()
+	}
 
 	os.Exit(code)
 }
@@ -559,15 +600,9 @@ func TestSchedule_MessageAck(t *testing.T) {
 	deadline := time.Now().Add(2 * time.Second)
 	seenPending := false
 	for time.Now().Before(deadline) {
-		pending, err := testRedis.Client().XPendingExt(ctx, &redis.XPendingExtArgs{
-			Stream: queue.KeySchedulerRuns,       // Stream 名称：调度队列
-			Group:  queue.SchedulerConsumerGroup, // 消费者组：调度器消费者组
-			Start:  msgID,                        // 查询起始消息ID
-			End:    msgID,                        // 查询结束消息ID（只查这一条）
-			Count:  1,                            // 最多返回1条
-		}).Result()
-		// 如果查询成功且返回1条，说明消息在 pending 列表中
-		if err == nil && len(pending) == 1 {
+		pending, err := schedulerMessagePending(ctx, msgID)
+		// 如果查询成功且消息在 pending 列表中
+		if err == nil && pending {
 			seenPending = true
 			break // 验证成功，退出循环
 		}
@@ -594,15 +629,9 @@ func TestSchedule_MessageAck(t *testing.T) {
 	// 如果 pending 列表还有消息，说明 ACK 失败（测试失败）
 	deadline = time.Now().Add(2 * time.Second)
 	for time.Now().Before(deadline) {
-		pending, err := testRedis.Client().XPendingExt(ctx, &redis.XPendingExtArgs{
-			Stream: queue.KeySchedulerRuns,
-			Group:  queue.SchedulerConsumerGroup,
-			Start:  msgID,
-			End:    msgID,
-			Count:  1,
-		}).Result()
-		// 如果查询成功且返回0条，说明消息已从 pending 列表移除（ACK成功）
-		if err == nil && len(pending) == 0 {
+		pending, err := schedulerMessagePending(ctx, msgID)
+		// 如果查询成功且消息不在 pending 列表中，说明已移除（ACK成功）
+		if err == nil && !pending {
 			return // 测试通过！ACK 机制工作正常
 		}
 		time.Sleep(20 * time.Millisecond)
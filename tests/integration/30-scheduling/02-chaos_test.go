@@ -0,0 +1,232 @@
+// 调度链路故障注入集成测试
+//
+// 测试范围：消息队列丢弃 / 重复 / ACK 失败与数据库瞬时错误下，调度器的 ACK 逻辑与保底轮询仍然正确
+//
+// 测试用例：
+//   - TC-CHAOS-001: 消息丢弃 → 消息保持未确认，由保底轮询完成调度
+//   - TC-CHAOS-002: 消息重复 → 只分配一次、只通知节点一次
+//   - TC-CHAOS-003: 数据库瞬时错误 → Run 保持 queued、消息不确认，故障恢复后保底轮询完成调度
+//   - TC-CHAOS-004: ACK 失败 → Run 正常分配，消息保持未确认
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/scheduler"
+	"agents-admin/internal/shared/chaos"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/queue"
+	"agents-admin/internal/shared/storage"
+)
+
+// chaosStaleThreshold 故障用例的保底轮询阈值：新建的 Run 需等待该时间后才会被保底轮询接管
+const chaosStaleThreshold = 600 * time.Millisecond
+
+// setupChaosRun 创建 Task、queued Run 与一个在线节点，返回 runID、taskID 与 nodeID
+func setupChaosRun(t *testing.T, ctx context.Context, prefix string) (string, string, string) {
+	t.Helper()
+	now := time.Now()
+	taskID := uniqueID("task-" + prefix)
+	runID := uniqueID("run-" + prefix)
+	nodeID := uniqueID("node-" + prefix)
+
+	task := &model.Task{ID: taskID, Name: "Chaos Task", Status: model.TaskStatusPending, Type: model.TaskTypeGeneral, Prompt: &model.Prompt{Content: "test"}, CreatedAt: now, UpdatedAt: now}
+	if err := testStore.CreateTask(ctx, task); err != nil {
+		t.Fatalf("Failed to create task: %v", err)
+	}
+	t.Cleanup(func() { testStore.DeleteTask(context.Background(), taskID) })
+
+	run := &model.Run{ID: runID, TaskID: taskID, Status: model.RunStatusQueued, Snapshot: json.RawMessage(`{"prompt":"test"}`), CreatedAt: now, UpdatedAt: now}
+	if err := testStore.CreateRun(ctx, run); err != nil {
+		t.Fatalf("Failed to create run: %v", err)
+	}
+	t.Cleanup(func() { testStore.DeleteRun(context.Background(), runID) })
+
+	node := &model.Node{ID: nodeID, Status: model.NodeStatusOnline, Labels: json.RawMessage(`{}`), Capacity: json.RawMessage(`{"max_concurrent": 5}`), LastHeartbeat: &now, CreatedAt: now, UpdatedAt: now}
+	if err := testStore.UpsertNode(ctx, node); err != nil {
+		t.Fatalf("Failed to create node: %v", err)
+	}
+	t.Cleanup(func() { testStore.DeleteNode(context.Background(), nodeID) })
+	if err := testRedis.CreateNodeConsumerGroup(ctx, nodeID); err != nil {
+		t.Logf("CreateNodeConsumerGroup: %v (may already exist)", err)
+	}
+	return runID, taskID, nodeID
+}
+
+// newChaosScheduler 使用注入故障的调度队列与存储层创建调度器（节点队列不注入故障，便于断言通知次数）
+func newChaosScheduler(store storage.PersistentStore, schedulerQueue queue.SchedulerQueue) *scheduler.Scheduler {
+	sched := newTestScheduler(store, schedulerQueue, testRedis)
+	sched.SetFallbackConfig(150*time.Millisecond, chaosStaleThreshold)
+	return sched
+}
+
+// waitPending 等待调度消息进入 pending 状态
+func waitPending(t *testing.T, ctx context.Context, msgID string, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if pending, err := schedulerMessagePending(ctx, msgID); err == nil && pending {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("Expected scheduler pending contains msg_id=%s", msgID)
+}
+
+// TC-CHAOS-001: 消息丢弃
+func TestChaos_DroppedMessageRecoveredByFallback(t *testing.T) {
+	if testRedis == nil {
+		t.Skip("Redis not available")
+	}
+	ctx := context.Background()
+	resetState(t, ctx)
+
+	runID, taskID, nodeID := setupChaosRun(t, ctx, "drop")
+	msgID, err := testRedis.ScheduleRun(ctx, runID, taskID)
+	if err != nil {
+		t.Fatalf("Failed to schedule run: %v", err)
+	}
+
+	inj := chaos.New(chaos.Config{Seed: 1, QueueDrop: 1})
+	sched := newChaosScheduler(testStore, chaos.WrapQueue(testRedis, inj))
+	schedCancel, done := startScheduler(ctx, sched, 5*time.Second)
+	defer stopScheduler(t, schedCancel, done)
+
+	// 消费者读取后丢弃：消息留在 pending 中，Run 在超过保底阈值前保持 queued
+	waitPending(t, ctx, msgID, 2*time.Second)
+	if r, _ := testStore.GetRun(ctx, runID); r == nil || r.Status != model.RunStatusQueued {
+		t.Fatalf("Run should stay queued before the fallback threshold, got %+v", r)
+	}
+
+	updated := waitRun(t, ctx, runID, 3*time.Second, func(r *model.Run) bool { return r.Status == model.RunStatusAssigned })
+	if updated.Status != model.RunStatusAssigned {
+		t.Fatalf("Run status = %s, want assigned by fallback", updated.Status)
+	}
+	if updated.NodeID == nil || *updated.NodeID != nodeID {
+		t.Errorf("Run node_id = %v, want %s", updated.NodeID, nodeID)
+	}
+	if inj.Count(chaos.FaultQueueDrop) == 0 {
+		t.Errorf("Expected at least one dropped message")
+	}
+	if pending, err := schedulerMessagePending(ctx, msgID); err != nil || !pending {
+		t.Errorf("Dropped message should remain pending (pending=%v err=%v)", pending, err)
+	}
+}
+
+// TC-CHAOS-002: 消息重复
+func TestChaos_DuplicateMessageAssignsOnce(t *testing.T) {
+	if testRedis == nil {
+		t.Skip("Redis not available")
+	}
+	ctx := context.Background()
+	resetState(t, ctx)
+
+	runID, taskID, nodeID := setupChaosRun(t, ctx, "dup")
+	msgID, err := testRedis.ScheduleRun(ctx, runID, taskID)
+	if err != nil {
+		t.Fatalf("Failed to schedule run: %v", err)
+	}
+
+	inj := chaos.New(chaos.Config{Seed: 1, QueueDuplicate: 1})
+	sched := newChaosScheduler(testStore, chaos.WrapQueue(testRedis, inj))
+	schedCancel, done := startScheduler(ctx, sched, 3*time.Second)
+	defer stopScheduler(t, schedCancel, done)
+
+	updated := waitRun(t, ctx, runID, 2*time.Second, func(r *model.Run) bool { return r.Status == model.RunStatusAssigned })
+	if updated.Status != model.RunStatusAssigned {
+		t.Fatalf("Run status = %s, want assigned", updated.Status)
+	}
+	// 等待重复消息处理完
+	time.Sleep(500 * time.Millisecond)
+
+	if inj.Count(chaos.FaultQueueDuplicate) == 0 {
+		t.Fatalf("Expected at least one duplicated message")
+	}
+	messages, err := testRedis.ConsumeNodeRuns(ctx, nodeID, uniqueID("consumer"), 10, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to consume node runs: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("Node received %d messages, want exactly 1", len(messages))
+	}
+	if pending, err := schedulerMessagePending(ctx, msgID); err != nil || pending {
+		t.Errorf("Duplicated message should be acked (pending=%v err=%v)", pending, err)
+	}
+}
+
+// TC-CHAOS-003: 数据库瞬时错误
+func TestChaos_TransientDBErrorKeepsMessageUnacked(t *testing.T) {
+	if testRedis == nil {
+		t.Skip("Redis not available")
+	}
+	ctx := context.Background()
+	resetState(t, ctx)
+
+	runID, taskID, _ := setupChaosRun(t, ctx, "dberr")
+	msgID, err := testRedis.ScheduleRun(ctx, runID, taskID)
+	if err != nil {
+		t.Fatalf("Failed to schedule run: %v", err)
+	}
+
+	inj := chaos.New(chaos.Config{Seed: 1, DBError: 1, DBMethods: []string{"GetRun"}})
+	sched := newChaosScheduler(chaos.WrapStore(testStore, inj), testRedis)
+	schedCancel, done := startScheduler(ctx, sched, 6*time.Second)
+	defer stopScheduler(t, schedCancel, done)
+
+	// 故障期间：消息被读取但不确认，保底轮询同样失败，Run 保持 queued
+	waitPending(t, ctx, msgID, 2*time.Second)
+	time.Sleep(2 * chaosStaleThreshold)
+	if r, _ := testStore.GetRun(ctx, runID); r == nil || r.Status != model.RunStatusQueued {
+		t.Fatalf("Run should stay queued while the database fails, got %+v", r)
+	}
+	if pending, err := schedulerMessagePending(ctx, msgID); err != nil || !pending {
+		t.Fatalf("Message must not be acked after a failed schedule (pending=%v err=%v)", pending, err)
+	}
+	if inj.Count(chaos.FaultDBError) < 2 {
+		t.Fatalf("Expected both the consumer and the fallback poller to hit injected errors, got %d", inj.Count(chaos.FaultDBError))
+	}
+
+	// 故障恢复：保底轮询接管
+	inj.Set(chaos.Config{})
+	updated := waitRun(t, ctx, runID, 3*time.Second, func(r *model.Run) bool { return r.Status == model.RunStatusAssigned })
+	if updated.Status != model.RunStatusAssigned {
+		t.Fatalf("Run status = %s, want assigned after recovery", updated.Status)
+	}
+}
+
+// TC-CHAOS-004: ACK 失败
+func TestChaos_AckFailureLeavesMessagePending(t *testing.T) {
+	if testRedis == nil {
+		t.Skip("Redis not available")
+	}
+	ctx := context.Background()
+	resetState(t, ctx)
+
+	runID, taskID, nodeID := setupChaosRun(t, ctx, "ackerr")
+	msgID, err := testRedis.ScheduleRun(ctx, runID, taskID)
+	if err != nil {
+		t.Fatalf("Failed to schedule run: %v", err)
+	}
+
+	inj := chaos.New(chaos.Config{Seed: 1, QueueAckError: 1})
+	sched := newChaosScheduler(testStore, chaos.WrapQueue(testRedis, inj))
+	schedCancel, done := startScheduler(ctx, sched, 3*time.Second)
+	defer stopScheduler(t, schedCancel, done)
+
+	updated := waitRun(t, ctx, runID, 2*time.Second, func(r *model.Run) bool { return r.Status == model.RunStatusAssigned })
+	if updated.Status != model.RunStatusAssigned {
+		t.Fatalf("Run status = %s, want assigned", updated.Status)
+	}
+	if updated.NodeID == nil || *updated.NodeID != nodeID {
+		t.Errorf("Run node_id = %v, want %s", updated.NodeID, nodeID)
+	}
+	if inj.Count(chaos.FaultQueueAckError) == 0 {
+		t.Fatalf("Expected an injected ack failure")
+	}
+	if pending, err := schedulerMessagePending(ctx, msgID); err != nil || !pending {
+		t.Errorf("Message should remain pending after a failed ack (pending=%v err=%v)", pending, err)
+	}
+}
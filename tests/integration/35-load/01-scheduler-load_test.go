@@ -0,0 +1,360 @@
+// Package integration 调度与事件管道压测（基准式集成测试）
+//
+// 测试范围：大批 queued Run 与模拟节点下的调度延迟、调度队列未确认消息增长，以及事件批量入库吞吐。
+// 超出阈值时用例失败，作为 CI 回归门禁（make test-load）。
+//
+// 测试用例：
+//   - TC-LOAD-001: 调度延迟（创建 → assigned 的 p50/p99）与调度队列未确认消息增长
+//   - TC-LOAD-002: 事件入库吞吐
+//
+// 压测耗时较长，默认跳过，设置 LOADTEST=1 启用。规模与阈值通过环境变量调整：
+//
+//	LOADTEST=1 LOADTEST_RUNS=5000 LOADTEST_MAX_P99=5s go test -v ./tests/integration/35-load/...
+//
+// 对运行中的 API Server 端到端施压使用 cmd/loadgen。
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"agents-admin/internal/apiserver/scheduler"
+	"agents-admin/internal/config"
+	"agents-admin/internal/shared/infra"
+	"agents-admin/internal/shared/loadtest"
+	"agents-admin/internal/shared/model"
+	"agents-admin/internal/shared/storage"
+)
+
+var (
+	testStore *storage.PostgresStore
+	testRedis *infra.RedisInfra
+)
+
+// loadConfig 压测规模与阈值（环境变量覆盖默认值）
+type loadConfig struct {
+	runs        int
+	nodes       int
+	events      int // 每个 Run 的事件数
+	eventBatch  int
+	concurrency int // 创建 Run 与写入事件的并发数
+	thresholds  loadtest.Thresholds
+}
+
+func loadConfigFromEnv(t *testing.T) loadConfig {
+	return loadConfig{
+		runs:        envInt(t, "LOADTEST_RUNS", 2000),
+		nodes:       envInt(t, "LOADTEST_NODES", 50),
+		events:      envInt(t, "LOADTEST_EVENTS", 20),
+		eventBatch:  envInt(t, "LOADTEST_EVENT_BATCH", 20),
+		concurrency: envInt(t, "LOADTEST_CONCURRENCY", 16),
+		thresholds: loadtest.Thresholds{
+			MaxScheduleP50:   envDuration(t, "LOADTEST_MAX_P50", 2*time.Second),
+			MaxScheduleP99:   envDuration(t, "LOADTEST_MAX_P99", 10*time.Second),
+			MinEventsPerSec:  float64(envInt(t, "LOADTEST_MIN_EVENTS_PER_SEC", 1000)),
+			MaxPendingGrowth: int64(envInt(t, "LOADTEST_MAX_PENDING_GROWTH", 500)),
+			Strict:           true,
+		},
+	}
+}
+
+func envInt(t *testing.T, key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		t.Fatalf("invalid %s=%q: %v", key, v, err)
+	}
+	return n
+}
+
+func envDuration(t *testing.T, key string, def time.Duration) time.Duration {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		t.Fatalf("invalid %s=%q: %v", key, v, err)
+	}
+	return d
+}
+
+func skipUnlessLoadTest(t *testing.T) {
+	if os.Getenv("LOADTEST") == "" {
+		t.Skip("load test disabled, set LOADTEST=1")
+	}
+	if testing.Short() {
+		t.Skip("load test skipped in -short mode")
+	}
+}
+
+// parallel 以 n 个协程执行 fn(0..count-1)，返回出错次数
+func parallel(n, count int, fn func(i int) error) int {
+	jobs := make(chan int)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		failed int
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := fn(i); err != nil {
+					mu.Lock()
+					failed++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	for i := range count {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	return failed
+}
+
+// createLoadRuns 创建 count 个任务及其 Run（status 指定初始状态），返回 Run ID 与清理函数
+func createLoadRuns(t *testing.T, ctx context.Context, cfg loadConfig, tag string, status model.RunStatus) ([]string, func()) {
+	t.Helper()
+	taskIDs := make([]string, cfg.runs)
+	runIDs := make([]string, cfg.runs)
+	failed := parallel(cfg.concurrency, cfg.runs, func(i int) error {
+		now := time.Now()
+		taskIDs[i] = fmt.Sprintf("%s-task-%05d", tag, i)
+		runIDs[i] = fmt.Sprintf("%s-run-%05d", tag, i)
+		task := &model.Task{
+			ID:        taskIDs[i],
+			Name:      "Load Test Task",
+			Status:    model.TaskStatusPending,
+			Type:      model.TaskTypeGeneral,
+			Prompt:    &model.Prompt{Content: "load test"},
+			Labels:    map[string]string{"loadtest": tag},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		if err := testStore.CreateTask(ctx, task); err != nil {
+			return err
+		}
+		return testStore.CreateRun(ctx, &model.Run{
+			ID:        runIDs[i],
+			TaskID:    taskIDs[i],
+			Status:    status,
+			Snapshot:  json.RawMessage(`{"prompt": "load test"}`),
+			CreatedAt: now,
+			UpdatedAt: now,
+		})
+	})
+	cleanup := func() {
+		parallel(cfg.concurrency, cfg.runs, func(i int) error {
+			testStore.DeleteEventsByRun(ctx, runIDs[i])
+			testStore.DeleteRun(ctx, runIDs[i])
+			return testStore.DeleteTask(ctx, taskIDs[i])
+		})
+	}
+	if failed > 0 {
+		cleanup()
+		t.Fatalf("failed to create %d of %d runs", failed, cfg.runs)
+	}
+	return runIDs, cleanup
+}
+
+// TC-LOAD-001: 调度延迟与调度队列未确认消息增长
+func TestLoad_Scheduling(t *testing.T) {
+	skipUnlessLoadTest(t)
+	if testRedis == nil {
+		t.Skip("Redis not available")
+	}
+
+	ctx := context.Background()
+	cfg := loadConfigFromEnv(t)
+	tag := fmt.Sprintf("load-%s", time.Now().Format("150405"))
+	if err := testRedis.Client().FlushDB(ctx).Err(); err != nil {
+		t.Fatalf("redis FlushDB failed: %v", err)
+	}
+
+	// 1. 模拟节点：容量足以容纳全部 Run，延迟只反映调度本身
+	perNode := cfg.runs/cfg.nodes + 1
+	nodeIDs := make([]string, cfg.nodes)
+	now := time.Now()
+	for i := range nodeIDs {
+		nodeIDs[i] = fmt.Sprintf("%s-node-%03d", tag, i)
+		node := &model.Node{
+			ID:            nodeIDs[i],
+			Status:        model.NodeStatusOnline,
+			Labels:        json.RawMessage(fmt.Sprintf(`{"loadtest": %q}`, tag)),
+			Capacity:      json.RawMessage(fmt.Sprintf(`{"max_concurrent": %d}`, perNode)),
+			LastHeartbeat: &now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		if err := testStore.UpsertNode(ctx, node); err != nil {
+			t.Fatalf("Failed to create node: %v", err)
+		}
+		defer testStore.DeleteNode(ctx, nodeIDs[i])
+		if err := testRedis.CreateNodeConsumerGroup(ctx, nodeIDs[i]); err != nil {
+			t.Logf("CreateNodeConsumerGroup: %v (may already exist)", err)
+		}
+	}
+
+	// 2. 大批 queued Run 入队
+	runIDs, cleanup := createLoadRuns(t, ctx, cfg, tag, model.RunStatusQueued)
+	defer cleanup()
+
+	cfgSched := scheduler.DefaultConfig()
+	cfgSched.NodeID = tag + "-scheduler"
+	cfgSched.Fallback.StaleThreshold = 24 * time.Hour
+	sched := scheduler.NewSchedulerWithConfig(testStore, testRedis, testRedis, cfgSched)
+
+	var pending loadtest.Gauge
+	if n, err := testRedis.GetSchedulerPendingCount(ctx); err == nil {
+		pending.Observe(n)
+	}
+	start := time.Now()
+	scheduleErrors := parallel(cfg.concurrency, len(runIDs), func(i int) error {
+		_, err := testRedis.ScheduleRun(ctx, runIDs[i], fmt.Sprintf("%s-task-%05d", tag, i))
+		return err
+	})
+
+	// 3. 启动调度器，采样未确认消息数直到全部分配
+	schedCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		sched.Start(schedCtx)
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	timeout := envDuration(t, "LOADTEST_TIMEOUT", 5*time.Minute)
+	deadline := time.Now().Add(timeout)
+	var assigned []*model.Run
+	for time.Now().Before(deadline) {
+		if n, err := testRedis.GetSchedulerPendingCount(ctx); err == nil {
+			pending.Observe(n)
+		}
+		assigned = assigned[:0]
+		for _, nodeID := range nodeIDs {
+			runs, err := testStore.ListRunsByNode(ctx, nodeID)
+			if err != nil {
+				t.Fatalf("ListRunsByNode failed: %v", err)
+			}
+			assigned = append(assigned, runs...)
+		}
+		if len(assigned) >= len(runIDs) {
+			break
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+
+	// 4. 报告与阈值判定（assigned 时 updated_at 即分配时间）
+	var latencies loadtest.Latencies
+	for _, r := range assigned {
+		if r.Status == model.RunStatusAssigned {
+			latencies.Add(r.UpdatedAt.Sub(r.CreatedAt))
+		}
+	}
+	report := loadtest.NewReport(&latencies, 0, 0, &pending)
+	report.Runs = len(runIDs)
+	report.Errors = scheduleErrors
+	report.Duration = time.Since(start)
+	t.Logf("TC-LOAD-001: %s", report)
+
+	th := cfg.thresholds
+	th.MinEventsPerSec = 0
+	if violations := th.Check(report); len(violations) > 0 {
+		t.Fatalf("TC-LOAD-001: regression: %s", loadtest.Summary(violations))
+	}
+}
+
+// TC-LOAD-002: 事件入库吞吐
+func TestLoad_EventIngest(t *testing.T) {
+	skipUnlessLoadTest(t)
+
+	ctx := context.Background()
+	cfg := loadConfigFromEnv(t)
+	tag := fmt.Sprintf("loadev-%s", time.Now().Format("150405"))
+
+	runIDs, cleanup := createLoadRuns(t, ctx, cfg, tag, model.RunStatusRunning)
+	defer cleanup()
+
+	// 每个 Run 按批写入事件，模拟节点上报（payload 与原始输出均为真实大小的 JSON）
+	payload := json.RawMessage(`{"type":"assistant","message":{"content":[{"type":"text","text":"Running the test suite now, then I will summarize the results."}]}}`)
+	raw := string(payload)
+	start := time.Now()
+	failed := parallel(cfg.concurrency, len(runIDs), func(i int) error {
+		for from := 0; from < cfg.events; from += cfg.eventBatch {
+			batch := make([]*model.Event, 0, cfg.eventBatch)
+			for seq := from + 1; seq <= min(from+cfg.eventBatch, cfg.events); seq++ {
+				batch = append(batch, &model.Event{
+					RunID:     runIDs[i],
+					Seq:       seq,
+					Type:      string(model.EventTypeMessage),
+					Timestamp: time.Now(),
+					Payload:   payload,
+					Raw:       &raw,
+				})
+			}
+			if err := testStore.CreateEvents(ctx, batch); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	elapsed := time.Since(start)
+
+	report := loadtest.NewReport(&loadtest.Latencies{}, (len(runIDs)-failed)*cfg.events, elapsed, &loadtest.Gauge{})
+	report.Runs = len(runIDs)
+	report.Scheduled = len(runIDs) // 不经过调度
+	report.Errors = failed
+	report.Duration = elapsed
+	t.Logf("TC-LOAD-002: %s", report)
+
+	th := loadtest.Thresholds{MinEventsPerSec: cfg.thresholds.MinEventsPerSec, Strict: true}
+	if violations := th.Check(report); len(violations) > 0 {
+		t.Fatalf("TC-LOAD-002: regression: %s", loadtest.Summary(violations))
+	}
+}
+
+func TestMain(m *testing.M) {
+	// 强制使用测试环境
+	os.Setenv("APP_ENV", "test")
+	if os.Getenv("LOADTEST") == "" {
+		// 未启用压测时不连接数据库，避免在普通集成测试中失败
+		os.Exit(m.Run())
+	}
+	cfg := config.Load()
+
+	var err error
+	testStore, err = storage.NewPostgresStore(cfg.DatabaseURL)
+	if err != nil {
+		panic("Failed to connect to PostgreSQL: " + err.Error())
+	}
+
+	testRedis, err = infra.NewRedisInfra(cfg.RedisURL)
+	if err != nil {
+		testRedis = nil
+	}
+
+	code := m.Run()
+
+	testStore.Close()
+	if testRedis != nil {
+		testRedis.Close()
+	}
+	os.Exit(code)
+}
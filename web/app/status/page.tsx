@@ -0,0 +1,246 @@
+'use client'
+
+import { useEffect, useState, useCallback } from 'react'
+import { RefreshCw, CheckCircle, XCircle, MinusCircle, Database, Layers, Network, AlertTriangle } from 'lucide-react'
+import { AdminLayout } from '@/components/layout'
+import { useTranslation } from 'react-i18next'
+import { useFormatDate } from '@/i18n/useFormatDate'
+
+interface ComponentHealth {
+  status: 'ok' | 'error' | 'disabled'
+  latency_ms: number
+  error?: string
+}
+
+interface SystemStatus {
+  status: 'ok' | 'degraded'
+  generated_at: string
+  components: Record<string, ComponentHealth>
+  scheduler: {
+    running: boolean
+    leader?: string
+    queue_length: number
+    queue_pending: number
+    queued_runs: number
+    assigned_runs: number
+    running_runs: number
+    error?: string
+  }
+  nodes: {
+    total: number
+    online: number
+    offline: number
+    capacity: number
+    active_runs: number
+    utilization: number
+    error?: string
+  }
+  error_rates: {
+    request_window: string
+    requests: number
+    server_errors: number
+    request_error_rate: number
+    run_window: string
+    runs_finished: number
+    runs_failed: number
+    run_failure_rate: number
+    error?: string
+  }
+}
+
+// 组件展示顺序
+const componentOrder = ['database', 'database_replica', 'redis', 'minio']
+
+const componentStatusConfig = {
+  ok:       { icon: CheckCircle, color: 'text-green-600', bg: 'bg-green-50 border-green-200' },
+  error:    { icon: XCircle,     color: 'text-red-600',   bg: 'bg-red-50 border-red-200' },
+  disabled: { icon: MinusCircle, color: 'text-gray-400',  bg: 'bg-gray-50 border-gray-200' },
+}
+
+const percent = (v: number) => `${(v * 100).toFixed(1)}%`
+
+// 错误率着色：>= 5% 红色，>= 1% 黄色
+const rateColor = (v: number) => (v >= 0.05 ? 'text-red-600' : v >= 0.01 ? 'text-yellow-600' : 'text-green-600')
+
+function Stat({ label, value, color = 'text-gray-900' }: { label: string; value: React.ReactNode; color?: string }) {
+  return (
+    <div className="bg-white rounded-xl border p-4 text-center">
+      <p className={`text-2xl font-bold ${color}`}>{value}</p>
+      <p className="text-xs text-gray-500 mt-1">{label}</p>
+    </div>
+  )
+}
+
+function Section({ title, icon: Icon, error, children }: {
+  title: string
+  icon: React.ComponentType<{ className?: string }>
+  error?: string
+  children: React.ReactNode
+}) {
+  return (
+    <section className="mb-6">
+      <div className="flex items-center gap-2 mb-3">
+        <Icon className="w-4 h-4 text-gray-500" />
+        <h2 className="text-sm font-semibold text-gray-700">{title}</h2>
+        {error && <span className="text-xs text-red-600 truncate">{error}</span>}
+      </div>
+      {children}
+    </section>
+  )
+}
+
+export default function StatusPage() {
+  const { t } = useTranslation('status')
+  const { formatRelative } = useFormatDate()
+  const [status, setStatus] = useState<SystemStatus | null>(null)
+  const [loading, setLoading] = useState(true)
+  const [failed, setFailed] = useState(false)
+
+  const fetchStatus = useCallback(async () => {
+    try {
+      const res = await fetch('/api/v1/system/status')
+      if (res.ok) {
+        setStatus(await res.json())
+        setFailed(false)
+      } else {
+        setFailed(true)
+      }
+    } catch (err) {
+      console.error('Failed to fetch system status:', err)
+      setFailed(true)
+    } finally {
+      setLoading(false)
+    }
+  }, [])
+
+  useEffect(() => {
+    fetchStatus()
+    const interval = setInterval(fetchStatus, 15000)
+    return () => clearInterval(interval)
+  }, [fetchStatus])
+
+  const components = status
+    ? Object.keys(status.components).sort((a, b) => {
+        const ia = componentOrder.indexOf(a), ib = componentOrder.indexOf(b)
+        return (ia < 0 ? componentOrder.length : ia) - (ib < 0 ? componentOrder.length : ib)
+      })
+    : []
+
+  return (
+    <AdminLayout title={t('title')} onRefresh={fetchStatus} loading={loading}>
+      {loading && !status ? (
+        <div className="flex items-center justify-center h-64">
+          <RefreshCw className="w-8 h-8 text-blue-500 animate-spin" />
+        </div>
+      ) : !status ? (
+        <div className="bg-white rounded-xl border p-8 text-center">
+          <AlertTriangle className="w-12 h-12 mx-auto text-red-400 mb-4" />
+          <h3 className="text-lg font-medium mb-2">{t('loadFailed')}</h3>
+        </div>
+      ) : (
+        <>
+          {/* 总体状态 */}
+          <div
+            className={`flex items-center justify-between rounded-xl border p-4 mb-6 ${
+              status.status === 'ok' ? 'bg-green-50 border-green-200' : 'bg-yellow-50 border-yellow-200'
+            }`}
+          >
+            <div className="flex items-center gap-3">
+              {status.status === 'ok'
+                ? <CheckCircle className="w-6 h-6 text-green-600" />
+                : <AlertTriangle className="w-6 h-6 text-yellow-600" />}
+              <span className="font-semibold text-gray-900">{t(`overall.${status.status}`)}</span>
+            </div>
+            <span className={`text-xs ${failed ? 'text-red-600' : 'text-gray-500'}`}>
+              {failed ? t('stale') : t('updatedAt', { time: formatRelative(status.generated_at) })}
+            </span>
+          </div>
+
+          {/* 依赖组件 */}
+          <Section title={t('components.title')} icon={Database}>
+            <div className="grid grid-cols-1 sm:grid-cols-2 lg:grid-cols-4 gap-3">
+              {components.map(name => {
+                const c = status.components[name]
+                const cfg = componentStatusConfig[c.status] || componentStatusConfig.disabled
+                const Icon = cfg.icon
+                return (
+                  <div key={name} className={`rounded-xl border p-4 ${cfg.bg}`}>
+                    <div className="flex items-center justify-between">
+                      <span className="font-medium text-gray-900">{t(`components.${name}`, { defaultValue: name })}</span>
+                      <Icon className={`w-5 h-5 ${cfg.color}`} />
+                    </div>
+                    <p className={`text-xs mt-2 ${cfg.color}`}>
+                      {c.status === 'disabled'
+                        ? t('components.disabled')
+                        : c.status === 'ok'
+                          ? t('components.latency', { ms: c.latency_ms })
+                          : c.error || t('components.error')}
+                    </p>
+                  </div>
+                )
+              })}
+            </div>
+          </Section>
+
+          {/* 调度队列 */}
+          <Section title={t('scheduler.title')} icon={Layers} error={status.scheduler.error}>
+            <div className="grid grid-cols-2 sm:grid-cols-5 gap-3">
+              <Stat label={t('scheduler.queueLength')} value={status.scheduler.queue_length} />
+              <Stat label={t('scheduler.queuePending')} value={status.scheduler.queue_pending} />
+              <Stat label={t('scheduler.queuedRuns')} value={status.scheduler.queued_runs} />
+              <Stat label={t('scheduler.assignedRuns')} value={status.scheduler.assigned_runs} />
+              <Stat label={t('scheduler.runningRuns')} value={status.scheduler.running_runs} />
+            </div>
+            {!status.scheduler.running && (
+              <p className="text-xs text-yellow-600 mt-2">{t('scheduler.notRunning')}</p>
+            )}
+          </Section>
+
+          {/* 节点 */}
+          <Section title={t('nodes.title')} icon={Network} error={status.nodes.error}>
+            <div className="grid grid-cols-2 sm:grid-cols-4 gap-3">
+              <Stat label={t('nodes.online')} value={status.nodes.online} color="text-green-600" />
+              <Stat
+                label={t('nodes.offline')}
+                value={status.nodes.offline}
+                color={status.nodes.offline > 0 ? 'text-red-600' : 'text-gray-900'}
+              />
+              <Stat label={t('nodes.activeRuns')} value={`${status.nodes.active_runs} / ${status.nodes.capacity}`} />
+              <Stat label={t('nodes.utilization')} value={percent(status.nodes.utilization)} />
+            </div>
+          </Section>
+
+          {/* 错误率 */}
+          <Section title={t('errorRates.title')} icon={AlertTriangle} error={status.error_rates.error}>
+            <div className="grid grid-cols-1 sm:grid-cols-2 gap-3">
+              <div className="bg-white rounded-xl border p-4">
+                <p className="text-xs text-gray-500">{t('errorRates.requests', { window: status.error_rates.request_window })}</p>
+                <p className={`text-2xl font-bold mt-1 ${rateColor(status.error_rates.request_error_rate)}`}>
+                  {percent(status.error_rates.request_error_rate)}
+                </p>
+                <p className="text-xs text-gray-500 mt-1">
+                  {t('errorRates.requestsDetail', {
+                    errors: status.error_rates.server_errors,
+                    total: status.error_rates.requests,
+                  })}
+                </p>
+              </div>
+              <div className="bg-white rounded-xl border p-4">
+                <p className="text-xs text-gray-500">{t('errorRates.runs', { window: status.error_rates.run_window })}</p>
+                <p className={`text-2xl font-bold mt-1 ${rateColor(status.error_rates.run_failure_rate)}`}>
+                  {percent(status.error_rates.run_failure_rate)}
+                </p>
+                <p className="text-xs text-gray-500 mt-1">
+                  {t('errorRates.runsDetail', {
+                    failed: status.error_rates.runs_failed,
+                    total: status.error_rates.runs_finished,
+                  })}
+                </p>
+              </div>
+            </div>
+          </Section>
+        </>
+      )}
+    </AdminLayout>
+  )
+}
@@ -15,6 +15,7 @@ import {
   Bot,
   X,
   LogOut,
+  HeartPulse,
 } from 'lucide-react'
 import { useEffect } from 'react'
 import { useAuth } from '@/lib/auth'
@@ -30,6 +31,7 @@ interface NavItem {
 const navigationItems = [
   { key: 'nav.taskBoard', href: '/', icon: LayoutDashboard },
   { key: 'nav.monitor', href: '/monitor', icon: Activity },
+  { key: 'nav.status', href: '/status', icon: HeartPulse },
   { key: 'nav.agents', href: '/agents', icon: Bot },
   { key: 'nav.accounts', href: '/accounts', icon: Users },
   { key: 'nav.nodes', href: '/nodes', icon: Network },
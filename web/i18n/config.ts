@@ -13,6 +13,7 @@ import zhNodes from './locales/zh/nodes.json'
 import zhProxies from './locales/zh/proxies.json'
 import zhSettings from './locales/zh/settings.json'
 import zhAuth from './locales/zh/auth.json'
+import zhStatus from './locales/zh/status.json'
 
 import enCommon from './locales/en/common.json'
 import enTasks from './locales/en/tasks.json'
@@ -25,6 +26,7 @@ import enNodes from './locales/en/nodes.json'
 import enProxies from './locales/en/proxies.json'
 import enSettings from './locales/en/settings.json'
 import enAuth from './locales/en/auth.json'
+import enStatus from './locales/en/status.json'
 
 export const supportedLngs = ['zh', 'en'] as const
 export type SupportedLng = (typeof supportedLngs)[number]
@@ -33,7 +35,7 @@ export const LANG_STORAGE_KEY = 'i18n-lang'
 
 export const namespaces = [
   'common', 'tasks', 'agents', 'accounts', 'instances',
-  'monitor', 'runners', 'nodes', 'proxies', 'settings', 'auth',
+  'monitor', 'runners', 'nodes', 'proxies', 'settings', 'auth', 'status',
 ] as const
 
 // 初始化时使用固定语言（defaultLng），避免 SSR / 客户端 hydration 不一致。
@@ -58,6 +60,7 @@ i18n
         proxies: zhProxies,
         settings: zhSettings,
         auth: zhAuth,
+        status: zhStatus,
       },
       en: {
         common: enCommon,
@@ -71,6 +74,7 @@ i18n
         proxies: enProxies,
         settings: enSettings,
         auth: enAuth,
+        status: enStatus,
       },
     },
     fallbackLng: defaultLng,